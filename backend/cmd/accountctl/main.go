@@ -0,0 +1,217 @@
+// Command accountctl imports and exports accounts in bulk through the admin API.
+//
+//	accountctl import -file accounts.csv
+//	accountctl export -format csv -redact -out accounts.csv
+//
+// The server URL and admin API key default to SUB2API_SERVER and SUB2API_ADMIN_API_KEY.
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+const accountDataPath = "/api/v1/admin/accounts/data"
+
+type apiResponse struct {
+	Code    int             `json:"code"`
+	Message string          `json:"message"`
+	Data    json.RawMessage `json:"data"`
+}
+
+type client struct {
+	server string
+	apiKey string
+	http   *http.Client
+}
+
+func main() {
+	log.SetFlags(0)
+	if len(os.Args) < 2 {
+		usage()
+	}
+
+	switch os.Args[1] {
+	case "import":
+		runImport(os.Args[2:])
+	case "export":
+		runExport(os.Args[2:])
+	default:
+		usage()
+	}
+}
+
+func usage() {
+	fmt.Fprintln(os.Stderr, "usage: accountctl <import|export> [flags]")
+	os.Exit(2)
+}
+
+func newFlagSet(name string) (*flag.FlagSet, *string, *string) {
+	fs := flag.NewFlagSet(name, flag.ExitOnError)
+	server := fs.String("server", envOr("SUB2API_SERVER", "http://localhost:8080"), "Server base URL")
+	apiKey := fs.String("api-key", os.Getenv("SUB2API_ADMIN_API_KEY"), "Admin API key")
+	return fs, server, apiKey
+}
+
+func newClient(server, apiKey string) *client {
+	if strings.TrimSpace(apiKey) == "" {
+		log.Fatal("admin API key is required (-api-key or SUB2API_ADMIN_API_KEY)")
+	}
+	return &client{
+		server: strings.TrimRight(server, "/"),
+		apiKey: apiKey,
+		http:   &http.Client{Timeout: 5 * time.Minute},
+	}
+}
+
+func runImport(args []string) {
+	fs, server, apiKey := newFlagSet("import")
+	file := fs.String("file", "", "JSON or CSV file to import (format detected from extension)")
+	bindDefaultGroup := fs.Bool("bind-default-group", false, "Bind imported accounts without groups to the platform default group")
+	_ = fs.Parse(args)
+	if *file == "" {
+		log.Fatal("-file is required")
+	}
+
+	raw, err := os.ReadFile(*file)
+	if err != nil {
+		log.Fatalf("failed to read %s: %v", *file, err)
+	}
+
+	c := newClient(*server, *apiKey)
+	var data json.RawMessage
+	if strings.EqualFold(filepath.Ext(*file), ".csv") {
+		query := url.Values{"skip_default_group_bind": {fmt.Sprint(!*bindDefaultGroup)}}
+		data, err = c.do(http.MethodPost, accountDataPath+"/csv?"+query.Encode(), "text/csv", bytes.NewReader(raw))
+	} else {
+		var body []byte
+		body, err = json.Marshal(map[string]any{
+			"data":                    json.RawMessage(raw),
+			"skip_default_group_bind": !*bindDefaultGroup,
+		})
+		if err != nil {
+			log.Fatalf("invalid JSON in %s: %v", *file, err)
+		}
+		data, err = c.do(http.MethodPost, accountDataPath, "application/json", bytes.NewReader(body))
+	}
+	if err != nil {
+		log.Fatalf("import failed: %v", err)
+	}
+
+	var out bytes.Buffer
+	if err := json.Indent(&out, data, "", "  "); err != nil {
+		log.Fatalf("unexpected response: %v", err)
+	}
+	fmt.Println(out.String())
+}
+
+func runExport(args []string) {
+	fs, server, apiKey := newFlagSet("export")
+	format := fs.String("format", "json", "Export format: json or csv")
+	redact := fs.Bool("redact", false, "Redact credentials and proxy passwords")
+	ids := fs.String("ids", "", "Comma separated account IDs (default: all accounts)")
+	platform := fs.String("platform", "", "Filter by platform")
+	output := fs.String("out", "", "Output file (default: stdout)")
+	_ = fs.Parse(args)
+
+	query := url.Values{
+		"format":         {*format},
+		"redact_secrets": {fmt.Sprint(*redact)},
+	}
+	if *ids != "" {
+		query.Set("ids", *ids)
+	}
+	if *platform != "" {
+		query.Set("platform", *platform)
+	}
+
+	c := newClient(*server, *apiKey)
+	var body []byte
+	var err error
+	if *format == "csv" {
+		body, err = c.raw(http.MethodGet, accountDataPath+"?"+query.Encode())
+	} else {
+		var data json.RawMessage
+		data, err = c.do(http.MethodGet, accountDataPath+"?"+query.Encode(), "", nil)
+		if err == nil {
+			var out bytes.Buffer
+			err = json.Indent(&out, data, "", "  ")
+			body = append(out.Bytes(), '\n')
+		}
+	}
+	if err != nil {
+		log.Fatalf("export failed: %v", err)
+	}
+
+	if *output == "" {
+		_, _ = os.Stdout.Write(body)
+		return
+	}
+	if err := os.WriteFile(*output, body, 0o600); err != nil {
+		log.Fatalf("failed to write %s: %v", *output, err)
+	}
+}
+
+func (c *client) send(method, path, contentType string, body io.Reader) (*http.Response, error) {
+	req, err := http.NewRequest(method, c.server+path, body)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("x-api-key", c.apiKey)
+	if contentType != "" {
+		req.Header.Set("Content-Type", contentType)
+	}
+	return c.http.Do(req)
+}
+
+// do 发送请求并解析标准响应包装，返回 data 字段
+func (c *client) do(method, path, contentType string, body io.Reader) (json.RawMessage, error) {
+	resp, err := c.send(method, path, contentType, body)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	var parsed apiResponse
+	if err := json.NewDecoder(resp.Body).Decode(&parsed); err != nil {
+		return nil, fmt.Errorf("HTTP %d: %w", resp.StatusCode, err)
+	}
+	if resp.StatusCode != http.StatusOK || parsed.Code != 0 {
+		return nil, fmt.Errorf("HTTP %d: %s", resp.StatusCode, parsed.Message)
+	}
+	return parsed.Data, nil
+}
+
+func (c *client) raw(method, path string) ([]byte, error) {
+	resp, err := c.send(method, path, "", nil)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("HTTP %d: %s", resp.StatusCode, strings.TrimSpace(string(body)))
+	}
+	return body, nil
+}
+
+func envOr(key, fallback string) string {
+	if v := strings.TrimSpace(os.Getenv(key)); v != "" {
+		return v
+	}
+	return fallback
+}
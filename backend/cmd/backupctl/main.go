@@ -0,0 +1,211 @@
+// Command backupctl creates and restores encrypted backups of proxies, groups,
+// users, accounts, API keys and settings through the admin API.
+//
+//	backupctl backup -out sub2api.s2abk
+//	backupctl restore -file sub2api.s2abk -dry-run
+//	backupctl restore -file sub2api.s2abk -sections accounts,settings -overwrite
+//
+// The server URL and admin API key default to SUB2API_SERVER and SUB2API_ADMIN_API_KEY.
+// The passphrase is read from SUB2API_BACKUP_PASSPHRASE or -passphrase-file.
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"mime"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+const (
+	backupPath             = "/api/v1/admin/backup"
+	backupRestorePath      = "/api/v1/admin/backup/restore"
+	backupPassphraseHeader = "X-Backup-Passphrase"
+)
+
+type apiResponse struct {
+	Code    int             `json:"code"`
+	Message string          `json:"message"`
+	Data    json.RawMessage `json:"data"`
+}
+
+type client struct {
+	server     string
+	apiKey     string
+	passphrase string
+	http       *http.Client
+}
+
+func main() {
+	log.SetFlags(0)
+	if len(os.Args) < 2 {
+		usage()
+	}
+
+	switch os.Args[1] {
+	case "backup":
+		runBackup(os.Args[2:])
+	case "restore":
+		runRestore(os.Args[2:])
+	default:
+		usage()
+	}
+}
+
+func usage() {
+	fmt.Fprintln(os.Stderr, "usage: backupctl <backup|restore> [flags]")
+	os.Exit(2)
+}
+
+func newFlagSet(name string) (*flag.FlagSet, *string, *string, *string) {
+	fs := flag.NewFlagSet(name, flag.ExitOnError)
+	server := fs.String("server", envOr("SUB2API_SERVER", "http://localhost:8080"), "Server base URL")
+	apiKey := fs.String("api-key", os.Getenv("SUB2API_ADMIN_API_KEY"), "Admin API key")
+	passphraseFile := fs.String("passphrase-file", "", "File containing the backup passphrase (default: SUB2API_BACKUP_PASSPHRASE)")
+	return fs, server, apiKey, passphraseFile
+}
+
+func newClient(server, apiKey, passphraseFile string) *client {
+	if strings.TrimSpace(apiKey) == "" {
+		log.Fatal("admin API key is required (-api-key or SUB2API_ADMIN_API_KEY)")
+	}
+	passphrase := os.Getenv("SUB2API_BACKUP_PASSPHRASE")
+	if passphraseFile != "" {
+		raw, err := os.ReadFile(passphraseFile)
+		if err != nil {
+			log.Fatalf("failed to read %s: %v", passphraseFile, err)
+		}
+		passphrase = strings.TrimRight(string(raw), "\r\n")
+	}
+	if passphrase == "" {
+		log.Fatal("backup passphrase is required (SUB2API_BACKUP_PASSPHRASE or -passphrase-file)")
+	}
+	return &client{
+		server:     strings.TrimRight(server, "/"),
+		apiKey:     apiKey,
+		passphrase: passphrase,
+		http:       &http.Client{Timeout: 10 * time.Minute},
+	}
+}
+
+func runBackup(args []string) {
+	fs, server, apiKey, passphraseFile := newFlagSet("backup")
+	sections := fs.String("sections", "", "Comma separated sections: proxies,groups,users,accounts,api_keys,settings (default: all)")
+	output := fs.String("out", "", "Output file (default: sub2api-backup-<time>.s2abk from the server)")
+	_ = fs.Parse(args)
+
+	query := url.Values{}
+	if *sections != "" {
+		query.Set("sections", *sections)
+	}
+
+	c := newClient(*server, *apiKey, *passphraseFile)
+	resp, err := c.send(http.MethodPost, backupPath+"?"+query.Encode(), "", nil)
+	if err != nil {
+		log.Fatalf("backup failed: %v", err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+	if resp.StatusCode != http.StatusOK {
+		log.Fatalf("backup failed: %v", decodeError(resp))
+	}
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		log.Fatalf("backup failed: %v", err)
+	}
+
+	file := *output
+	if file == "" {
+		file = "sub2api-backup.s2abk"
+		if _, params, err := mime.ParseMediaType(resp.Header.Get("Content-Disposition")); err == nil && params["filename"] != "" {
+			file = filepath.Base(params["filename"])
+		}
+	}
+	if err := os.WriteFile(file, body, 0o600); err != nil {
+		log.Fatalf("failed to write %s: %v", file, err)
+	}
+	fmt.Printf("backup written to %s (%d bytes)\n", file, len(body))
+}
+
+func runRestore(args []string) {
+	fs, server, apiKey, passphraseFile := newFlagSet("restore")
+	file := fs.String("file", "", "Backup file to restore")
+	sections := fs.String("sections", "", "Comma separated sections to restore (default: all sections in the backup)")
+	dryRun := fs.Bool("dry-run", false, "Only report what would change")
+	overwrite := fs.Bool("overwrite", false, "Overwrite existing records that differ from the backup")
+	_ = fs.Parse(args)
+	if *file == "" {
+		log.Fatal("-file is required")
+	}
+
+	raw, err := os.ReadFile(*file)
+	if err != nil {
+		log.Fatalf("failed to read %s: %v", *file, err)
+	}
+
+	query := url.Values{
+		"dry_run":   {fmt.Sprint(*dryRun)},
+		"overwrite": {fmt.Sprint(*overwrite)},
+	}
+	if *sections != "" {
+		query.Set("sections", *sections)
+	}
+
+	c := newClient(*server, *apiKey, *passphraseFile)
+	resp, err := c.send(http.MethodPost, backupRestorePath+"?"+query.Encode(), "application/octet-stream", bytes.NewReader(raw))
+	if err != nil {
+		log.Fatalf("restore failed: %v", err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	var parsed apiResponse
+	if err := json.NewDecoder(resp.Body).Decode(&parsed); err != nil {
+		log.Fatalf("restore failed: HTTP %d: %v", resp.StatusCode, err)
+	}
+	if resp.StatusCode != http.StatusOK || parsed.Code != 0 {
+		log.Fatalf("restore failed: HTTP %d: %s", resp.StatusCode, parsed.Message)
+	}
+
+	var out bytes.Buffer
+	if err := json.Indent(&out, parsed.Data, "", "  "); err != nil {
+		log.Fatalf("unexpected response: %v", err)
+	}
+	fmt.Println(out.String())
+}
+
+func (c *client) send(method, path, contentType string, body io.Reader) (*http.Response, error) {
+	req, err := http.NewRequest(method, c.server+path, body)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("x-api-key", c.apiKey)
+	req.Header.Set(backupPassphraseHeader, c.passphrase)
+	if contentType != "" {
+		req.Header.Set("Content-Type", contentType)
+	}
+	return c.http.Do(req)
+}
+
+// decodeError 解析错误响应的 message 字段，非 JSON 响应返回原始内容
+func decodeError(resp *http.Response) error {
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, 64<<10))
+	var parsed apiResponse
+	if err := json.Unmarshal(body, &parsed); err == nil && parsed.Message != "" {
+		return fmt.Errorf("HTTP %d: %s", resp.StatusCode, parsed.Message)
+	}
+	return fmt.Errorf("HTTP %d: %s", resp.StatusCode, strings.TrimSpace(string(body)))
+}
+
+func envOr(key, fallback string) string {
+	if v := strings.TrimSpace(os.Getenv(key)); v != "" {
+		return v
+	}
+	return fallback
+}
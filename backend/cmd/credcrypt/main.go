@@ -0,0 +1,52 @@
+// Command credcrypt encrypts plaintext account credentials at rest and re-encrypts
+// credentials sealed with a rotated key using the current credential_encryption.key.
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log"
+
+	_ "github.com/ShaohongDong/sub2api/ent/runtime"
+	"github.com/ShaohongDong/sub2api/internal/config"
+	"github.com/ShaohongDong/sub2api/internal/repository"
+)
+
+func main() {
+	dryRun := flag.Bool("dry-run", false, "Only report how many accounts need to be (re-)encrypted")
+	flag.Parse()
+
+	cfg, err := config.LoadForBootstrap()
+	if err != nil {
+		log.Fatalf("failed to load config: %v", err)
+	}
+
+	cipher, err := repository.NewCredentialCipher(cfg)
+	if err != nil {
+		log.Fatalf("invalid credential encryption config: %v", err)
+	}
+	if cipher == nil {
+		log.Fatal("credential_encryption.key (or key_file) is not configured")
+	}
+
+	client, sqlDB, err := repository.InitEnt(cfg)
+	if err != nil {
+		log.Fatalf("failed to init db: %v", err)
+	}
+	defer func() {
+		if err := client.Close(); err != nil {
+			log.Printf("failed to close db: %v", err)
+		}
+	}()
+
+	result, err := repository.ReencryptAccountCredentials(context.Background(), client, sqlDB, cipher, *dryRun)
+	if err != nil {
+		log.Fatalf("re-encryption failed after %d accounts: %v", result.Scanned, err)
+	}
+
+	fmt.Printf("SCANNED=%d\nUPDATED=%d\nCONFLICTS=%d\nFAILED=%d\n", result.Scanned, result.Updated, result.Conflicts, len(result.Failed))
+	if len(result.Failed) > 0 {
+		log.Fatalf("accounts with undecryptable credentials (missing previous_keys?): %v", result.Failed)
+	}
+}
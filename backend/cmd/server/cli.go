@@ -0,0 +1,509 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/ShaohongDong/sub2api/internal/config"
+)
+
+// 运维子命令通过管理 API 操作网关，便于脚本与 Ansible 无界面调用：
+//
+//	sub2api account add -name main -platform anthropic -type apikey -credentials-file cred.json
+//	sub2api account list -platform anthropic
+//	sub2api account test -id 12
+//	sub2api key create -user-id 3 -name ci
+//	sub2api key revoke -id 8
+//	sub2api usage -period week
+//
+// 服务地址默认取 SUB2API_SERVER；未设置时从本机配置文件（server.host/server.port）推导，
+// 管理 API Key 取 SUB2API_ADMIN_API_KEY。
+
+type cliResponse struct {
+	Code    int             `json:"code"`
+	Message string          `json:"message"`
+	Data    json.RawMessage `json:"data"`
+}
+
+type cliClient struct {
+	server string
+	apiKey string
+	http   *http.Client
+	out    io.Writer
+}
+
+// cliFlagSet 创建带 -server / -api-key 公共参数的子命令参数集
+func cliFlagSet(name string) (*flag.FlagSet, *string, *string) {
+	fs := flag.NewFlagSet(name, flag.ExitOnError)
+	server := fs.String("server", defaultCLIServer(), "Server base URL (default: SUB2API_SERVER or the local config address)")
+	apiKey := fs.String("api-key", os.Getenv("SUB2API_ADMIN_API_KEY"), "Admin API key")
+	return fs, server, apiKey
+}
+
+// defaultCLIServer 返回默认服务地址；监听全部地址时改用回环地址访问本机服务
+func defaultCLIServer() string {
+	if v := strings.TrimSpace(os.Getenv("SUB2API_SERVER")); v != "" {
+		return v
+	}
+	host, port, err := net.SplitHostPort(config.GetServerAddress())
+	if err != nil {
+		return "http://localhost:8080"
+	}
+	if host == "" || host == "0.0.0.0" || host == "::" {
+		host = "127.0.0.1"
+	}
+	return "http://" + net.JoinHostPort(host, port)
+}
+
+func newCLIClient(server, apiKey string) (*cliClient, error) {
+	if strings.TrimSpace(apiKey) == "" {
+		return nil, fmt.Errorf("admin API key is required (-api-key or SUB2API_ADMIN_API_KEY)")
+	}
+	return &cliClient{
+		server: strings.TrimRight(server, "/"),
+		apiKey: apiKey,
+		http:   &http.Client{Timeout: 5 * time.Minute},
+		out:    os.Stdout,
+	}, nil
+}
+
+func (c *cliClient) send(method, path string, body any) (*http.Response, error) {
+	var reader io.Reader
+	if body != nil {
+		raw, err := json.Marshal(body)
+		if err != nil {
+			return nil, err
+		}
+		reader = bytes.NewReader(raw)
+	}
+	req, err := http.NewRequest(method, c.server+path, reader)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("x-api-key", c.apiKey)
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	return c.http.Do(req)
+}
+
+// do 发送请求并解析标准响应包装，返回 data 字段
+func (c *cliClient) do(method, path string, body any) (json.RawMessage, error) {
+	resp, err := c.send(method, path, body)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	var parsed cliResponse
+	if err := json.NewDecoder(resp.Body).Decode(&parsed); err != nil {
+		return nil, fmt.Errorf("HTTP %d: %w", resp.StatusCode, err)
+	}
+	if resp.StatusCode/100 != 2 || parsed.Code != 0 {
+		return nil, fmt.Errorf("HTTP %d: %s", resp.StatusCode, parsed.Message)
+	}
+	return parsed.Data, nil
+}
+
+func (c *cliClient) printJSON(data json.RawMessage) error {
+	var out bytes.Buffer
+	if err := json.Indent(&out, data, "", "  "); err != nil {
+		return fmt.Errorf("unexpected response: %w", err)
+	}
+	out.WriteByte('\n')
+	_, err := c.out.Write(out.Bytes())
+	return err
+}
+
+func runAccountCommand(args []string) int {
+	if len(args) == 0 {
+		log.Print("usage: sub2api account <add|list|test> [flags]")
+		return 2
+	}
+	var err error
+	switch args[0] {
+	case "add":
+		err = runAccountAdd(args[1:])
+	case "list":
+		err = runAccountList(args[1:])
+	case "test":
+		err = runAccountTest(args[1:])
+	default:
+		log.Printf("Unknown account command %q (available: add, list, test)", args[0])
+		return 2
+	}
+	if err != nil {
+		log.Printf("account %s failed: %v", args[0], err)
+		return 1
+	}
+	return 0
+}
+
+func runAccountAdd(args []string) error {
+	fs, server, apiKey := cliFlagSet("account add")
+	name := fs.String("name", "", "Account name")
+	platform := fs.String("platform", "", "Platform: anthropic, openai, gemini, antigravity, sora")
+	accountType := fs.String("type", "apikey", "Account type: oauth, setup-token, apikey, upstream")
+	credentials := fs.String("credentials", "", "Credentials as a JSON object")
+	credentialsFile := fs.String("credentials-file", "", "File containing the credentials JSON object")
+	notes := fs.String("notes", "", "Notes")
+	groupIDs := fs.String("group-ids", "", "Comma separated group IDs")
+	proxyID := fs.Int64("proxy-id", 0, "Proxy ID (0 = direct)")
+	concurrency := fs.Int("concurrency", 0, "Max concurrency (0 = server default)")
+	priority := fs.Int("priority", 0, "Scheduling priority")
+	_ = fs.Parse(args)
+	if *name == "" || *platform == "" {
+		return fmt.Errorf("-name and -platform are required")
+	}
+
+	raw := []byte(*credentials)
+	if *credentialsFile != "" {
+		var err error
+		if raw, err = os.ReadFile(*credentialsFile); err != nil {
+			return err
+		}
+	}
+	var creds map[string]any
+	if err := json.Unmarshal(raw, &creds); err != nil || len(creds) == 0 {
+		return fmt.Errorf("-credentials or -credentials-file must contain a non-empty JSON object")
+	}
+
+	body := map[string]any{
+		"name":        *name,
+		"platform":    *platform,
+		"type":        *accountType,
+		"credentials": creds,
+		"concurrency": *concurrency,
+		"priority":    *priority,
+	}
+	if *notes != "" {
+		body["notes"] = *notes
+	}
+	if *proxyID > 0 {
+		body["proxy_id"] = *proxyID
+	}
+	if *groupIDs != "" {
+		ids, err := parseIDList(*groupIDs)
+		if err != nil {
+			return fmt.Errorf("invalid -group-ids: %w", err)
+		}
+		body["group_ids"] = ids
+	}
+
+	c, err := newCLIClient(*server, *apiKey)
+	if err != nil {
+		return err
+	}
+	data, err := c.do(http.MethodPost, "/api/v1/admin/accounts", body)
+	if err != nil {
+		return err
+	}
+	var created struct {
+		ID   int64  `json:"id"`
+		Name string `json:"name"`
+	}
+	if err := json.Unmarshal(data, &created); err != nil {
+		return fmt.Errorf("unexpected response: %w", err)
+	}
+	_, err = fmt.Fprintf(c.out, "Created account %d (%s)\n", created.ID, created.Name)
+	return err
+}
+
+func runAccountList(args []string) error {
+	fs, server, apiKey := cliFlagSet("account list")
+	platform := fs.String("platform", "", "Filter by platform")
+	accountType := fs.String("type", "", "Filter by account type")
+	status := fs.String("status", "", "Filter by status")
+	group := fs.String("group", "", "Filter by group ID")
+	search := fs.String("search", "", "Search by name")
+	page := fs.Int("page", 1, "Page number")
+	pageSize := fs.Int("page-size", 100, "Items per page")
+	asJSON := fs.Bool("json", false, "Print the raw JSON page instead of a table")
+	_ = fs.Parse(args)
+
+	query := url.Values{
+		"page":      {strconv.Itoa(*page)},
+		"page_size": {strconv.Itoa(*pageSize)},
+		"lite":      {"true"},
+	}
+	for key, value := range map[string]string{"platform": *platform, "type": *accountType, "status": *status, "group": *group, "search": *search} {
+		if value != "" {
+			query.Set(key, value)
+		}
+	}
+
+	c, err := newCLIClient(*server, *apiKey)
+	if err != nil {
+		return err
+	}
+	data, err := c.do(http.MethodGet, "/api/v1/admin/accounts?"+query.Encode(), nil)
+	if err != nil {
+		return err
+	}
+	if *asJSON {
+		return c.printJSON(data)
+	}
+
+	var result struct {
+		Items []struct {
+			ID          int64  `json:"id"`
+			Name        string `json:"name"`
+			Platform    string `json:"platform"`
+			Type        string `json:"type"`
+			Status      string `json:"status"`
+			Schedulable bool   `json:"schedulable"`
+			Priority    int    `json:"priority"`
+			Concurrency int    `json:"concurrency"`
+		} `json:"items"`
+		Total int64 `json:"total"`
+		Page  int   `json:"page"`
+		Pages int   `json:"pages"`
+	}
+	if err := json.Unmarshal(data, &result); err != nil {
+		return fmt.Errorf("unexpected response: %w", err)
+	}
+	tw := tabwriter.NewWriter(c.out, 0, 4, 2, ' ', 0)
+	_, _ = fmt.Fprintln(tw, "ID\tNAME\tPLATFORM\tTYPE\tSTATUS\tSCHEDULABLE\tPRIORITY\tCONCURRENCY")
+	for _, a := range result.Items {
+		_, _ = fmt.Fprintf(tw, "%d\t%s\t%s\t%s\t%s\t%t\t%d\t%d\n", a.ID, a.Name, a.Platform, a.Type, a.Status, a.Schedulable, a.Priority, a.Concurrency)
+	}
+	if err := tw.Flush(); err != nil {
+		return err
+	}
+	_, err = fmt.Fprintf(c.out, "Page %d/%d, %d account(s) total\n", result.Page, result.Pages, result.Total)
+	return err
+}
+
+// runAccountTest 发起账号连通性测试并逐行输出 SSE 事件，测试未成功完成时返回错误
+func runAccountTest(args []string) error {
+	fs, server, apiKey := cliFlagSet("account test")
+	id := fs.Int64("id", 0, "Account ID")
+	model := fs.String("model", "", "Model to test (default: platform default test model)")
+	_ = fs.Parse(args)
+	if *id <= 0 {
+		return fmt.Errorf("-id is required")
+	}
+
+	c, err := newCLIClient(*server, *apiKey)
+	if err != nil {
+		return err
+	}
+	resp, err := c.send(http.MethodPost, fmt.Sprintf("/api/v1/admin/accounts/%d/test", *id), map[string]string{"model_id": *model})
+	if err != nil {
+		return err
+	}
+	defer func() { _ = resp.Body.Close() }()
+	if resp.StatusCode != http.StatusOK {
+		var parsed cliResponse
+		_ = json.NewDecoder(resp.Body).Decode(&parsed)
+		return fmt.Errorf("HTTP %d: %s", resp.StatusCode, parsed.Message)
+	}
+	return consumeAccountTestEvents(resp.Body, c.out)
+}
+
+func consumeAccountTestEvents(r io.Reader, out io.Writer) error {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		payload, ok := strings.CutPrefix(scanner.Text(), "data: ")
+		if !ok {
+			continue
+		}
+		var event struct {
+			Type    string `json:"type"`
+			Text    string `json:"text"`
+			Model   string `json:"model"`
+			Success bool   `json:"success"`
+			Error   string `json:"error"`
+		}
+		if err := json.Unmarshal([]byte(payload), &event); err != nil {
+			continue
+		}
+		switch event.Type {
+		case "test_start":
+			_, _ = fmt.Fprintf(out, "Testing with model %s...\n", event.Model)
+		case "content":
+			_, _ = fmt.Fprint(out, event.Text)
+		case "error":
+			_, _ = fmt.Fprintln(out)
+			return fmt.Errorf("%s", event.Error)
+		case "test_complete":
+			_, _ = fmt.Fprintln(out)
+			if !event.Success {
+				return fmt.Errorf("test did not succeed")
+			}
+			_, _ = fmt.Fprintln(out, "OK")
+			return nil
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return err
+	}
+	return fmt.Errorf("stream ended without a result")
+}
+
+func runKeyCommand(args []string) int {
+	if len(args) == 0 {
+		log.Print("usage: sub2api key <create|revoke> [flags]")
+		return 2
+	}
+	var err error
+	switch args[0] {
+	case "create":
+		err = runKeyCreate(args[1:])
+	case "revoke":
+		err = runKeyRevoke(args[1:])
+	default:
+		log.Printf("Unknown key command %q (available: create, revoke)", args[0])
+		return 2
+	}
+	if err != nil {
+		log.Printf("key %s failed: %v", args[0], err)
+		return 1
+	}
+	return 0
+}
+
+func runKeyCreate(args []string) error {
+	fs, server, apiKey := cliFlagSet("key create")
+	userID := fs.Int64("user-id", 0, "Owner user ID")
+	name := fs.String("name", "", "Key name")
+	groupID := fs.Int64("group-id", 0, "Group ID (0 = none)")
+	models := fs.String("models", "", "Comma separated allowed models (supports trailing * wildcard)")
+	quota := fs.Float64("quota", 0, "Quota in USD (0 = unlimited)")
+	expiresInDays := fs.Int("expires-in-days", 0, "Expire after N days (0 = never)")
+	rpm := fs.Int("rpm-limit", 0, "Requests per minute limit (0 = unlimited)")
+	_ = fs.Parse(args)
+	if *userID <= 0 || *name == "" {
+		return fmt.Errorf("-user-id and -name are required")
+	}
+
+	body := map[string]any{
+		"user_id":   *userID,
+		"name":      *name,
+		"quota":     *quota,
+		"rpm_limit": *rpm,
+	}
+	if *groupID > 0 {
+		body["group_id"] = *groupID
+	}
+	if *models != "" {
+		body["allowed_models"] = splitCSV(*models)
+	}
+	if *expiresInDays > 0 {
+		body["expires_in_days"] = *expiresInDays
+	}
+
+	c, err := newCLIClient(*server, *apiKey)
+	if err != nil {
+		return err
+	}
+	data, err := c.do(http.MethodPost, "/api/v1/admin/api-keys", body)
+	if err != nil {
+		return err
+	}
+	var created struct {
+		ID  int64  `json:"id"`
+		Key string `json:"key"`
+	}
+	if err := json.Unmarshal(data, &created); err != nil {
+		return fmt.Errorf("unexpected response: %w", err)
+	}
+	// 密钥单独输出到 stdout 最后一行，便于脚本直接捕获
+	log.Printf("Created API key %d", created.ID)
+	_, err = fmt.Fprintln(c.out, created.Key)
+	return err
+}
+
+func runKeyRevoke(args []string) error {
+	fs, server, apiKey := cliFlagSet("key revoke")
+	id := fs.Int64("id", 0, "API key ID")
+	_ = fs.Parse(args)
+	if *id <= 0 {
+		return fmt.Errorf("-id is required")
+	}
+
+	c, err := newCLIClient(*server, *apiKey)
+	if err != nil {
+		return err
+	}
+	if _, err := c.do(http.MethodPost, fmt.Sprintf("/api/v1/admin/api-keys/%d/revoke", *id), nil); err != nil {
+		return err
+	}
+	_, err = fmt.Fprintf(c.out, "Revoked API key %d\n", *id)
+	return err
+}
+
+// runUsageCommand 输出用量统计（与管理后台用量页相同口径）
+func runUsageCommand(args []string) int {
+	fs, server, apiKey := cliFlagSet("usage")
+	period := fs.String("period", "today", "Period when no dates are given: today, week, month")
+	startDate := fs.String("start-date", "", "Start date (YYYY-MM-DD)")
+	endDate := fs.String("end-date", "", "End date (YYYY-MM-DD)")
+	timezone := fs.String("timezone", "", "Timezone for date boundaries (default: server timezone)")
+	userID := fs.String("user-id", "", "Filter by user ID")
+	apiKeyID := fs.String("api-key-id", "", "Filter by API key ID")
+	accountID := fs.String("account-id", "", "Filter by account ID")
+	groupID := fs.String("group-id", "", "Filter by group ID")
+	model := fs.String("model", "", "Filter by model")
+	_ = fs.Parse(args)
+
+	query := url.Values{}
+	for key, value := range map[string]string{
+		"period": *period, "start_date": *startDate, "end_date": *endDate, "timezone": *timezone,
+		"user_id": *userID, "api_key_id": *apiKeyID, "account_id": *accountID, "group_id": *groupID, "model": *model,
+	} {
+		if value != "" {
+			query.Set(key, value)
+		}
+	}
+
+	c, err := newCLIClient(*server, *apiKey)
+	if err == nil {
+		var data json.RawMessage
+		if data, err = c.do(http.MethodGet, "/api/v1/admin/usage/stats?"+query.Encode(), nil); err == nil {
+			err = c.printJSON(data)
+		}
+	}
+	if err != nil {
+		log.Printf("usage failed: %v", err)
+		return 1
+	}
+	return 0
+}
+
+func parseIDList(raw string) ([]int64, error) {
+	parts := splitCSV(raw)
+	ids := make([]int64, 0, len(parts))
+	for _, part := range parts {
+		id, err := strconv.ParseInt(part, 10, 64)
+		if err != nil || id <= 0 {
+			return nil, fmt.Errorf("invalid ID %q", part)
+		}
+		ids = append(ids, id)
+	}
+	return ids, nil
+}
+
+func splitCSV(raw string) []string {
+	var out []string
+	for _, part := range strings.Split(raw, ",") {
+		if part = strings.TrimSpace(part); part != "" {
+			out = append(out, part)
+		}
+	}
+	return out
+}
@@ -0,0 +1,78 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestConsumeAccountTestEvents(t *testing.T) {
+	var out bytes.Buffer
+	stream := "data: {\"type\":\"test_start\",\"model\":\"claude-sonnet-4\"}\n\n" +
+		"data: {\"type\":\"content\",\"text\":\"hello\"}\n\n" +
+		"data: {\"type\":\"test_complete\",\"success\":true}\n\n"
+	require.NoError(t, consumeAccountTestEvents(strings.NewReader(stream), &out))
+	require.Contains(t, out.String(), "claude-sonnet-4")
+	require.Contains(t, out.String(), "hello")
+
+	err := consumeAccountTestEvents(strings.NewReader("data: {\"type\":\"error\",\"error\":\"invalid token\"}\n\n"), &out)
+	require.EqualError(t, err, "invalid token")
+
+	err = consumeAccountTestEvents(strings.NewReader("data: {\"type\":\"test_start\"}\n\n"), &out)
+	require.Error(t, err)
+}
+
+func TestCLIClientDo(t *testing.T) {
+	var gotKey string
+	var gotBody map[string]any
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotKey = r.Header.Get("x-api-key")
+		_ = json.NewDecoder(r.Body).Decode(&gotBody)
+		if r.URL.Path == "/api/v1/admin/api-keys/9/revoke" {
+			w.WriteHeader(http.StatusNotFound)
+			_, _ = w.Write([]byte(`{"code":404,"message":"api key not found"}`))
+			return
+		}
+		w.WriteHeader(http.StatusCreated)
+		_, _ = w.Write([]byte(`{"code":0,"message":"success","data":{"id":5,"key":"sk-test"}}`))
+	}))
+	defer srv.Close()
+
+	c, err := newCLIClient(srv.URL+"/", "admin-key")
+	require.NoError(t, err)
+	data, err := c.do(http.MethodPost, "/api/v1/admin/api-keys", map[string]any{"user_id": 3, "name": "ci"})
+	require.NoError(t, err)
+	require.JSONEq(t, `{"id":5,"key":"sk-test"}`, string(data))
+	require.Equal(t, "admin-key", gotKey)
+	require.Equal(t, "ci", gotBody["name"])
+
+	_, err = c.do(http.MethodPost, "/api/v1/admin/api-keys/9/revoke", nil)
+	require.EqualError(t, err, "HTTP 404: api key not found")
+
+	_, err = newCLIClient(srv.URL, " ")
+	require.Error(t, err)
+}
+
+func TestParseIDList(t *testing.T) {
+	ids, err := parseIDList("1, 2,,3")
+	require.NoError(t, err)
+	require.Equal(t, []int64{1, 2, 3}, ids)
+
+	_, err = parseIDList("1,x")
+	require.Error(t, err)
+}
+
+func TestDefaultCLIServer(t *testing.T) {
+	t.Setenv("SUB2API_SERVER", "https://gw.example.com")
+	require.Equal(t, "https://gw.example.com", defaultCLIServer())
+
+	t.Setenv("SUB2API_SERVER", "")
+	t.Setenv("SERVER_HOST", "0.0.0.0")
+	t.Setenv("SERVER_PORT", "9090")
+	require.Equal(t, "http://127.0.0.1:9090", defaultCLIServer())
+}
@@ -19,6 +19,10 @@ import (
 	"github.com/ShaohongDong/sub2api/internal/config"
 	"github.com/ShaohongDong/sub2api/internal/handler"
 	"github.com/ShaohongDong/sub2api/internal/pkg/logger"
+	"github.com/ShaohongDong/sub2api/internal/pkg/openai"
+	"github.com/ShaohongDong/sub2api/internal/pkg/tracing"
+	"github.com/ShaohongDong/sub2api/internal/repository"
+	"github.com/ShaohongDong/sub2api/internal/server"
 	"github.com/ShaohongDong/sub2api/internal/server/middleware"
 	"github.com/ShaohongDong/sub2api/internal/setup"
 	"github.com/ShaohongDong/sub2api/internal/web"
@@ -61,8 +65,15 @@ func main() {
 	// Parse command line flags
 	setupMode := flag.Bool("setup", false, "Run setup wizard in CLI mode")
 	showVersion := flag.Bool("version", false, "Show version information")
+	migrateOnly := flag.Bool("migrate-only", false, "Apply pending database migrations and exit")
+	migrateRollback := flag.Int("migrate-rollback", 0, "Roll back the last N applied database migrations and exit")
 	flag.Parse()
 
+	// 子命令：sub2api serve | config validate | account | key | usage
+	if flag.NArg() > 0 && flag.Arg(0) != "serve" {
+		os.Exit(runCommand(flag.Args()))
+	}
+
 	if *showVersion {
 		log.Printf("Sub2API %s (commit: %s, built: %s)\n", Version, Commit, Date)
 		return
@@ -76,6 +87,12 @@ func main() {
 		return
 	}
 
+	// 迁移模式：执行或回滚数据库迁移后退出，不启动服务
+	if *migrateOnly || *migrateRollback > 0 {
+		runMigrations(*migrateRollback)
+		return
+	}
+
 	// Check if setup is needed
 	if setup.NeedsSetup() {
 		// Check if auto-setup is enabled (for Docker deployment)
@@ -128,6 +145,72 @@ func runSetupServer() {
 	}
 }
 
+func runMigrations(rollbackSteps int) {
+	cfg, err := config.LoadForBootstrap()
+	if err != nil {
+		log.Fatalf("Failed to load config: %v", err)
+	}
+
+	if rollbackSteps > 0 {
+		rolledBack, err := repository.RunMigrationRollback(cfg, rollbackSteps)
+		for _, name := range rolledBack {
+			log.Printf("Rolled back migration %s", name)
+		}
+		if err != nil {
+			log.Fatalf("Migration rollback failed: %v", err)
+		}
+		return
+	}
+
+	if err := repository.RunMigrations(cfg); err != nil {
+		log.Fatalf("Migration failed: %v", err)
+	}
+	log.Println("Database migrations are up to date")
+}
+
+func runCommand(args []string) int {
+	switch {
+	case len(args) >= 2 && args[0] == "config" && args[1] == "validate":
+		return runConfigValidate(args[2:])
+	case args[0] == "account":
+		return runAccountCommand(args[1:])
+	case args[0] == "key":
+		return runKeyCommand(args[1:])
+	case args[0] == "usage":
+		return runUsageCommand(args[1:])
+	}
+	log.Printf("Unknown command %q (available: serve, config validate, account, key, usage)", strings.Join(args, " "))
+	return 2
+}
+
+// runConfigValidate 校验配置文件：列出未知配置项与无法转换的值（含行号），并执行完整的语义校验。
+// 存在任何问题时返回非零退出码，便于在部署前的 CI 中使用。
+func runConfigValidate(args []string) int {
+	fs := flag.NewFlagSet("config validate", flag.ExitOnError)
+	path := fs.String("config", "", "Config file to validate (default: search the same paths as the server)")
+	_ = fs.Parse(args)
+
+	file, issues, err := config.ValidateConfigFile(*path)
+	for _, issue := range issues {
+		log.Printf("%s: %s", file, issue)
+	}
+	if err != nil {
+		log.Printf("%v", err)
+		return 1
+	}
+	if len(issues) > 0 {
+		log.Printf("%s: %d problem(s) found", file, len(issues))
+		return 1
+	}
+	log.Printf("%s: OK", file)
+	return 0
+}
+
+// applyRuntimeConfig 应用支持热更新的配置项（启动时及配置文件变更时调用）。
+func applyRuntimeConfig(cfg *config.Config) {
+	openai.ConfigureCodexCLIUserAgentPrefixes(cfg.Gateway.CodexCLIUserAgentPrefixes, cfg.Gateway.CodexCLIUserAgentPrefixesOverride)
+}
+
 func runMainServer() {
 	cfg, err := config.LoadForBootstrap()
 	if err != nil {
@@ -136,6 +219,17 @@ func runMainServer() {
 	if err := logger.Init(logger.OptionsFromConfig(cfg.Log)); err != nil {
 		log.Fatalf("Failed to initialize logger: %v", err)
 	}
+	tracingOpts := tracing.OptionsFromConfig(cfg.Tracing)
+	tracingOpts.ServiceVersion = Version
+	if err := tracing.Init(tracingOpts); err != nil {
+		log.Fatalf("Failed to initialize tracing: %v", err)
+	}
+	// 最后执行：Cleanup 之后导出剩余 span
+	defer shutdownTracing()
+	applyRuntimeConfig(cfg)
+	if config.OnConfigFileChange(applyRuntimeConfig) {
+		log.Println("Config file hot reload enabled")
+	}
 	if cfg.RunMode == config.RunModeSimple {
 		log.Println("⚠️  WARNING: Running in SIMPLE mode - billing and quota checks are DISABLED")
 	}
@@ -151,14 +245,35 @@ func runMainServer() {
 	}
 	defer app.Cleanup()
 
-	// 启动服务器
+	// 启动服务器（TCP、Unix socket 或 systemd socket activation）
+	ln, err := server.Listen(cfg.Server)
+	if err != nil {
+		log.Fatalf("Failed to listen: %v", err)
+	}
 	go func() {
-		if err := app.Server.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		if err := app.TLS.Serve(app.Server, ln); err != nil && !errors.Is(err, http.ErrServerClosed) {
 			log.Fatalf("Failed to start server: %v", err)
 		}
 	}()
 
-	log.Printf("Server started on %s", app.Server.Addr)
+	if app.TLS.Enabled() {
+		log.Printf("Server started on %s://%s (HTTPS)", ln.Addr().Network(), ln.Addr())
+	} else {
+		log.Printf("Server started on %s://%s", ln.Addr().Network(), ln.Addr())
+	}
+
+	// SIGHUP 触发配置热重载（不中断进行中的请求）
+	hup := make(chan os.Signal, 1)
+	signal.Notify(hup, syscall.SIGHUP)
+	go func() {
+		for range hup {
+			if _, err := app.ConfigReload.Reload(context.Background()); err != nil {
+				log.Printf("Config reload on SIGHUP failed: %v", err)
+				continue
+			}
+			log.Println("Config reloaded on SIGHUP")
+		}
+	}()
 
 	// 等待中断信号
 	quit := make(chan os.Signal, 1)
@@ -166,13 +281,56 @@ func runMainServer() {
 	<-quit
 
 	log.Println("Shutting down server...")
+	shutdownServer(app, time.Duration(cfg.Server.ShutdownUnreadySeconds)*time.Second, time.Duration(cfg.Server.ShutdownDrainSeconds)*time.Second)
+	// 返回后执行 deferred Cleanup：停止后台任务并落库排队中的使用记录
+	log.Println("Server exited")
+}
 
+// shutdownTracing 导出队列中剩余的 span，导出端不可用时最多等待 5 秒
+func shutdownTracing() {
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 	defer cancel()
+	if err := tracing.Shutdown(ctx); err != nil {
+		log.Printf("Tracing shutdown error: %v", err)
+	}
+}
+
+// shutdownForceCloseGrace 排空超时强制断开连接后，等待处理函数退出（提交部分用量）的时间
+const shutdownForceCloseGrace = 5 * time.Second
 
-	if err := app.Server.Shutdown(ctx); err != nil {
-		log.Fatalf("Server forced to shutdown: %v", err)
+// shutdownServer 先让 /readyz 报告未就绪并在 unreadyDelay 内照常服务，便于负载均衡摘除实例；
+// 随后停止接受新请求，在 drainTimeout 内等待在途请求（含流式响应与 WebSocket 会话）完成，
+// 超时后强制关闭剩余连接。不调用 log.Fatalf，以保证后续 Cleanup 执行。
+func shutdownServer(app *Application, unreadyDelay, drainTimeout time.Duration) {
+	app.Health.MarkDraining()
+	if unreadyDelay > 0 {
+		log.Printf("Reporting not-ready for %s before draining", unreadyDelay)
+		time.Sleep(unreadyDelay)
 	}
+	app.Drainer.BeginDrain()
+	log.Printf("Draining %d in-flight request(s), deadline %s", app.Drainer.InFlight(), drainTimeout)
 
-	log.Println("Server exited")
+	ctx, cancel := context.WithTimeout(context.Background(), drainTimeout)
+	defer cancel()
+
+	if err := app.TLS.Shutdown(ctx); err != nil {
+		log.Printf("ACME challenge listener shutdown error: %v", err)
+	}
+	shutdownErr := make(chan error, 1)
+	go func() { shutdownErr <- app.Server.Shutdown(ctx) }()
+	drainErr := app.Drainer.Wait(ctx)
+	err := <-shutdownErr
+	if drainErr == nil && err == nil {
+		return
+	}
+
+	log.Printf("Drain deadline exceeded with %d in-flight request(s), forcing close", app.Drainer.InFlight())
+	if err := app.Server.Close(); err != nil {
+		log.Printf("Server close error: %v", err)
+	}
+	graceCtx, graceCancel := context.WithTimeout(context.Background(), shutdownForceCloseGrace)
+	defer graceCancel()
+	if err := app.Drainer.Wait(graceCtx); err != nil {
+		log.Printf("%d request handler(s) still running after force close", app.Drainer.InFlight())
+	}
 }
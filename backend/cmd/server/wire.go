@@ -23,8 +23,12 @@ import (
 )
 
 type Application struct {
-	Server  *http.Server
-	Cleanup func()
+	Server       *http.Server
+	Drainer      *server.ShutdownDrainer
+	TLS          *server.TLSManager
+	Health       *service.HealthService
+	ConfigReload *service.ConfigReloadService
+	Cleanup      func()
 }
 
 func initializeApplication(buildInfo handler.BuildInfo) (*Application, error) {
@@ -48,7 +52,7 @@ func initializeApplication(buildInfo handler.BuildInfo) (*Application, error) {
 		provideCleanup,
 
 		// Application struct
-		wire.Struct(new(Application), "Server", "Cleanup"),
+		wire.Struct(new(Application), "Server", "Drainer", "TLS", "Health", "ConfigReload", "Cleanup"),
 	)
 	return nil, nil
 }
@@ -75,6 +79,9 @@ func provideCleanup(
 	accountExpiry *service.AccountExpiryService,
 	subscriptionExpiry *service.SubscriptionExpiryService,
 	usageCleanup *service.UsageCleanupService,
+	batch *service.BatchService,
+	backgroundResponse *service.BackgroundResponseService,
+	userFile *service.UserFileService,
 	idempotencyCleanup *service.IdempotencyCleanupService,
 	pricing *service.PricingService,
 	emailQueue *service.EmailQueueService,
@@ -87,6 +94,17 @@ func provideCleanup(
 	antigravityOAuth *service.AntigravityOAuthService,
 	openAIGateway *service.OpenAIGatewayService,
 	scheduledTestRunner *service.ScheduledTestRunnerService,
+	accountHealth *service.AccountHealthService,
+	accountWarmup *service.AccountWarmupService,
+	codexModelCatalog *service.CodexModelCatalogService,
+	codexVersionTracker *service.CodexVersionTracker,
+	sharedState *service.SharedStateService,
+	leaderElection *service.LeaderElectionService,
+	runtimeSettings *service.RuntimeSettingsService,
+	deadLetter *service.DeadLetterService,
+	contentLog *service.ContentLogService,
+	maintenance *service.MaintenanceService,
+	usageWebhook *service.UsageWebhookService,
 ) func() {
 	return func() {
 		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
@@ -153,6 +171,24 @@ func provideCleanup(
 				}
 				return nil
 			}},
+			{"BatchService", func() error {
+				if batch != nil {
+					batch.Stop()
+				}
+				return nil
+			}},
+			{"BackgroundResponseService", func() error {
+				if backgroundResponse != nil {
+					backgroundResponse.Stop()
+				}
+				return nil
+			}},
+			{"UserFileService", func() error {
+				if userFile != nil {
+					userFile.Stop()
+				}
+				return nil
+			}},
 			{"IdempotencyCleanupService", func() error {
 				if idempotencyCleanup != nil {
 					idempotencyCleanup.Stop()
@@ -223,9 +259,74 @@ func provideCleanup(
 				}
 				return nil
 			}},
+			{"AccountHealthService", func() error {
+				if accountHealth != nil {
+					accountHealth.Stop()
+				}
+				return nil
+			}},
+			{"AccountWarmupService", func() error {
+				if accountWarmup != nil {
+					accountWarmup.Stop()
+				}
+				return nil
+			}},
+			{"CodexModelCatalogService", func() error {
+				if codexModelCatalog != nil {
+					codexModelCatalog.Stop()
+				}
+				return nil
+			}},
+			{"CodexVersionTracker", func() error {
+				if codexVersionTracker != nil {
+					codexVersionTracker.Stop()
+				}
+				return nil
+			}},
+			{"SharedStateService", func() error {
+				if sharedState != nil {
+					sharedState.Stop()
+				}
+				return nil
+			}},
+			{"RuntimeSettingsService", func() error {
+				if runtimeSettings != nil {
+					runtimeSettings.Stop()
+				}
+				return nil
+			}},
+			{"DeadLetterService", func() error {
+				if deadLetter != nil {
+					deadLetter.Stop()
+				}
+				return nil
+			}},
+			{"ContentLogService", func() error {
+				if contentLog != nil {
+					contentLog.Stop()
+				}
+				return nil
+			}},
+			{"MaintenanceService", func() error {
+				if maintenance != nil {
+					maintenance.Stop()
+				}
+				return nil
+			}},
+			{"UsageWebhookService", func() error {
+				usageWebhook.Stop()
+				return nil
+			}},
 		}
 
 		infraSteps := []cleanupStep{
+			// 后台任务全部停止后再释放领导者租约，且须在关闭 Redis 之前
+			{"LeaderElectionService", func() error {
+				if leaderElection != nil {
+					leaderElection.Stop()
+				}
+				return nil
+			}},
 			{"Redis", func() error {
 				if rdb == nil {
 					return nil
@@ -64,6 +64,13 @@ func initializeApplication(buildInfo handler.BuildInfo) (*Application, error) {
 	apiKeyCache := repository.NewAPIKeyCache(redisClient)
 	apiKeyService := service.NewAPIKeyService(apiKeyRepository, userRepository, groupRepository, userSubscriptionRepository, userGroupRateRepository, apiKeyCache, configConfig)
 	apiKeyService.SetRateLimitCacheInvalidator(billingCache)
+	apiKeyRequestLimitCache := repository.NewAPIKeyRequestLimitCache(redisClient)
+	apiKeyService.SetRequestLimitCache(apiKeyRequestLimitCache)
+	apiKeyBudgetNotifier := repository.NewAPIKeyBudgetWebhook(configConfig)
+	apiKeyService.SetBudgetNotifier(apiKeyBudgetNotifier)
+	alertNotifier := repository.NewAlertWebhookNotifier(configConfig)
+	alertService := service.NewAlertService(configConfig, alertNotifier)
+	apiKeyService.SetAlertService(alertService)
 	apiKeyAuthCacheInvalidator := service.ProvideAPIKeyAuthCacheInvalidator(apiKeyService)
 	promoService := service.NewPromoService(promoCodeRepository, userRepository, billingCacheService, client, apiKeyAuthCacheInvalidator)
 	subscriptionService := service.NewSubscriptionService(groupRepository, userSubscriptionRepository, billingCacheService, client, configConfig)
@@ -99,7 +106,11 @@ func initializeApplication(buildInfo handler.BuildInfo) (*Application, error) {
 	dashboardAggregationService := service.ProvideDashboardAggregationService(dashboardAggregationRepository, timingWheelService, configConfig)
 	dashboardHandler := admin.NewDashboardHandler(dashboardService, dashboardAggregationService)
 	schedulerCache := repository.NewSchedulerCache(redisClient)
-	accountRepository := repository.NewAccountRepository(client, db, schedulerCache)
+	credentialCipher, err := repository.NewCredentialCipher(configConfig)
+	if err != nil {
+		return nil, err
+	}
+	accountRepository := repository.NewAccountRepository(client, db, schedulerCache, credentialCipher)
 	soraAccountRepository := repository.NewSoraAccountRepository(db)
 	proxyRepository := repository.NewProxyRepository(client, db)
 	proxyExitInfoProber := repository.NewProxyExitInfoProber(configConfig)
@@ -123,8 +134,12 @@ func initializeApplication(buildInfo handler.BuildInfo) (*Application, error) {
 	timeoutCounterCache := repository.NewTimeoutCounterCache(redisClient)
 	geminiTokenCache := repository.NewGeminiTokenCache(redisClient)
 	compositeTokenCacheInvalidator := service.NewCompositeTokenCacheInvalidator(geminiTokenCache)
-	rateLimitService := service.ProvideRateLimitService(accountRepository, usageLogRepository, configConfig, geminiQuotaService, tempUnschedCache, timeoutCounterCache, settingService, compositeTokenCacheInvalidator)
-	httpUpstream := repository.NewHTTPUpstream(configConfig)
+	rateLimitService := service.ProvideRateLimitService(accountRepository, usageLogRepository, configConfig, geminiQuotaService, tempUnschedCache, timeoutCounterCache, settingService, compositeTokenCacheInvalidator, alertService)
+	runner, err := repository.ProvideGatewayHookRunner(configConfig)
+	if err != nil {
+		return nil, err
+	}
+	httpUpstream := repository.ProvideHTTPUpstream(configConfig, runner)
 	claudeUsageFetcher := repository.NewClaudeUsageFetcher(httpUpstream)
 	antigravityQuotaFetcher := service.NewAntigravityQuotaFetcher(proxyRepository)
 	usageCache := service.NewUsageCache()
@@ -157,14 +172,16 @@ func initializeApplication(buildInfo handler.BuildInfo) (*Application, error) {
 	if err != nil {
 		return nil, err
 	}
-	billingService := service.NewBillingService(configConfig, pricingService)
+	billingService := service.ProvideBillingService(configConfig, pricingService, settingService)
 	identityService := service.NewIdentityService(identityCache)
 	deferredService := service.ProvideDeferredService(accountRepository, timingWheelService)
 	claudeTokenProvider := service.NewClaudeTokenProvider(accountRepository, geminiTokenCache, oAuthService)
 	digestSessionStore := service.NewDigestSessionStore()
 	gatewayService := service.NewGatewayService(accountRepository, groupRepository, usageLogRepository, userRepository, userSubscriptionRepository, userGroupRateRepository, gatewayCache, configConfig, schedulerSnapshotService, concurrencyService, billingService, rateLimitService, billingCacheService, identityService, httpUpstream, deferredService, claudeTokenProvider, sessionLimitCache, rpmCache, digestSessionStore, settingService)
 	openAITokenProvider := service.NewOpenAITokenProvider(accountRepository, geminiTokenCache, openAIOAuthService)
-	openAIGatewayService := service.NewOpenAIGatewayService(accountRepository, usageLogRepository, userRepository, userSubscriptionRepository, userGroupRateRepository, gatewayCache, configConfig, schedulerSnapshotService, concurrencyService, billingService, rateLimitService, billingCacheService, httpUpstream, deferredService, openAITokenProvider)
+	openAIGatewayService := service.NewOpenAIGatewayService(accountRepository, usageLogRepository, userRepository, userSubscriptionRepository, userGroupRateRepository, gatewayCache, configConfig, schedulerSnapshotService, concurrencyService, billingService, rateLimitService, billingCacheService, httpUpstream, deferredService, openAITokenProvider, settingService)
+	usageWebhookSender := repository.NewUsageWebhookSender(configConfig)
+	usageWebhookService := service.ProvideUsageWebhookService(usageWebhookSender, gatewayService, openAIGatewayService, configConfig)
 	geminiMessagesCompatService := service.NewGeminiMessagesCompatService(accountRepository, groupRepository, gatewayCache, schedulerSnapshotService, geminiTokenProvider, rateLimitService, httpUpstream, antigravityGatewayService, configConfig)
 	opsSystemLogSink := service.ProvideOpsSystemLogSink(opsRepository)
 	opsService := service.NewOpsService(opsRepository, settingRepository, configConfig, accountRepository, userRepository, concurrencyService, gatewayService, openAIGatewayService, geminiMessagesCompatService, antigravityGatewayService, opsSystemLogSink)
@@ -181,7 +198,8 @@ func initializeApplication(buildInfo handler.BuildInfo) (*Application, error) {
 	updateService := service.ProvideUpdateService(updateCache, gitHubReleaseClient, serviceBuildInfo)
 	idempotencyRepository := repository.NewIdempotencyRepository(client, db)
 	systemOperationLockService := service.ProvideSystemOperationLockService(idempotencyRepository, configConfig)
-	systemHandler := handler.ProvideSystemHandler(updateService, systemOperationLockService)
+	configReloadService := service.NewConfigReloadService(billingService, settingService)
+	systemHandler := handler.ProvideSystemHandler(updateService, systemOperationLockService, configReloadService)
 	adminSubscriptionHandler := admin.NewSubscriptionHandler(subscriptionService)
 	usageCleanupRepository := repository.NewUsageCleanupRepository(client, db)
 	usageCleanupService := service.ProvideUsageCleanupService(usageCleanupRepository, timingWheelService, dashboardAggregationService, configConfig)
@@ -194,17 +212,47 @@ func initializeApplication(buildInfo handler.BuildInfo) (*Application, error) {
 	errorPassthroughCache := repository.NewErrorPassthroughCache(redisClient)
 	errorPassthroughService := service.NewErrorPassthroughService(errorPassthroughRepository, errorPassthroughCache)
 	errorPassthroughHandler := admin.NewErrorPassthroughHandler(errorPassthroughService)
-	adminAPIKeyHandler := admin.NewAdminAPIKeyHandler(adminService)
+	adminAPIKeyHandler := admin.NewAdminAPIKeyHandler(adminService, apiKeyService)
 	scheduledTestPlanRepository := repository.NewScheduledTestPlanRepository(db)
 	scheduledTestResultRepository := repository.NewScheduledTestResultRepository(db)
 	scheduledTestService := service.ProvideScheduledTestService(scheduledTestPlanRepository, scheduledTestResultRepository)
 	scheduledTestHandler := admin.NewScheduledTestHandler(scheduledTestService)
-	adminHandlers := handler.ProvideAdminHandlers(dashboardHandler, adminUserHandler, groupHandler, accountHandler, adminAnnouncementHandler, dataManagementHandler, oAuthHandler, openAIOAuthHandler, geminiOAuthHandler, antigravityOAuthHandler, proxyHandler, adminRedeemHandler, promoHandler, settingHandler, opsHandler, systemHandler, adminSubscriptionHandler, adminUsageHandler, userAttributeHandler, errorPassthroughHandler, adminAPIKeyHandler, scheduledTestHandler)
+	leaderLockCache := repository.NewLeaderLockCache(redisClient)
+	leaderElectionService := service.ProvideLeaderElectionService(leaderLockCache, configConfig)
+	accountHealthService := service.ProvideAccountHealthService(accountRepository, accountTestService, rateLimitService, tempUnschedCache, leaderElectionService, configConfig)
+	accountHealthHandler := admin.NewAccountHealthHandler(accountHealthService)
+	accountValidationService := service.NewAccountValidationService(accountRepository, accountTestService, accountUsageService)
+	accountValidationHandler := admin.NewAccountValidationHandler(accountValidationService)
+	accountWarmupService := service.ProvideAccountWarmupService(accountRepository, accountTestService, accountHealthService, leaderElectionService, configConfig)
+	codexModelCatalogService := service.ProvideCodexModelCatalogService(accountRepository, openAITokenProvider, leaderElectionService, configConfig)
+	codexVersionTracker := service.ProvideCodexVersionTracker(gitHubReleaseClient, configConfig)
+	accountUsageWindowHandler := admin.NewAccountUsageWindowHandler(adminService, openAIGatewayService)
+	tenantRepository := repository.NewTenantRepository(client, db)
+	tenantService := service.NewTenantService(tenantRepository, usageLogRepository, apiKeyAuthCacheInvalidator)
+	tenantHandler := admin.NewTenantHandler(tenantService)
+	adminAuditRepository := repository.NewAdminAuditRepository(db)
+	adminAuditService := service.NewAdminAuditService(adminAuditRepository)
+	auditLogHandler := admin.NewAuditLogHandler(adminAuditService)
+	backupService := service.NewBackupService(proxyRepository, groupRepository, userRepository, accountRepository, apiKeyRepository, settingRepository, settingService, apiKeyAuthCacheInvalidator)
+	backupHandler := admin.NewBackupHandler(backupService)
+	runtimeSettingsService := service.ProvideRuntimeSettingsService(settingRepository, opsService, configConfig)
+	runtimeSettingsHandler := admin.NewRuntimeSettingsHandler(runtimeSettingsService)
+	deadLetterRepository := repository.NewDeadLetterRepository(db)
+	deadLetterService := service.ProvideDeadLetterService(deadLetterRepository, opsService, configConfig)
+	adminDeadLetterHandler := admin.NewDeadLetterHandler(deadLetterService)
+	contentLogRepository := repository.NewContentLogRepository(db)
+	contentLogService := service.ProvideContentLogService(contentLogRepository, configConfig)
+	adminContentLogHandler := admin.NewContentLogHandler(contentLogService)
+	maintenanceService := service.ProvideMaintenanceService(settingRepository)
+	adminMaintenanceHandler := admin.NewMaintenanceHandler(maintenanceService)
+	adminHandlers := handler.ProvideAdminHandlers(dashboardHandler, adminUserHandler, groupHandler, accountHandler, adminAnnouncementHandler, dataManagementHandler, oAuthHandler, openAIOAuthHandler, geminiOAuthHandler, antigravityOAuthHandler, proxyHandler, adminRedeemHandler, promoHandler, settingHandler, opsHandler, systemHandler, adminSubscriptionHandler, adminUsageHandler, userAttributeHandler, errorPassthroughHandler, adminAPIKeyHandler, scheduledTestHandler, accountHealthHandler, accountValidationHandler, accountUsageWindowHandler, tenantHandler, auditLogHandler, backupHandler, runtimeSettingsHandler, adminDeadLetterHandler, adminContentLogHandler, adminMaintenanceHandler)
 	usageRecordWorkerPool := service.NewUsageRecordWorkerPool(configConfig)
 	userMsgQueueCache := repository.NewUserMsgQueueCache(redisClient)
 	userMessageQueueService := service.ProvideUserMessageQueueService(userMsgQueueCache, rpmCache, configConfig)
 	gatewayHandler := handler.NewGatewayHandler(gatewayService, geminiMessagesCompatService, antigravityGatewayService, userService, concurrencyService, billingCacheService, usageService, apiKeyService, usageRecordWorkerPool, errorPassthroughService, userMessageQueueService, configConfig, settingService)
-	openAIGatewayHandler := handler.NewOpenAIGatewayHandler(openAIGatewayService, concurrencyService, billingCacheService, apiKeyService, usageRecordWorkerPool, errorPassthroughService, configConfig)
+	userFileRepository := repository.NewUserFileRepository(db)
+	userFileService := service.ProvideUserFileService(userFileRepository, timingWheelService, configConfig)
+	openAIGatewayHandler := handler.NewOpenAIGatewayHandler(openAIGatewayService, concurrencyService, billingCacheService, apiKeyService, usageRecordWorkerPool, errorPassthroughService, settingService, userFileService, configConfig)
 	soraSDKClient := service.ProvideSoraSDKClient(configConfig, httpUpstream, openAITokenProvider, accountRepository, soraAccountRepository)
 	soraMediaStorage := service.ProvideSoraMediaStorage(configConfig)
 	soraGatewayService := service.NewSoraGatewayService(soraSDKClient, rateLimitService, httpUpstream, configConfig)
@@ -212,28 +260,69 @@ func initializeApplication(buildInfo handler.BuildInfo) (*Application, error) {
 	soraGatewayHandler := handler.NewSoraGatewayHandler(gatewayService, soraGatewayService, concurrencyService, billingCacheService, usageRecordWorkerPool, configConfig)
 	handlerSettingHandler := handler.ProvideSettingHandler(settingService, buildInfo)
 	totpHandler := handler.NewTotpHandler(totpService)
+	batchJobRepository := repository.NewBatchJobRepository(db)
+	batchService := service.ProvideBatchService(batchJobRepository, apiKeyRepository, timingWheelService, configConfig)
+	batchHandler := handler.NewBatchHandler(batchService, userFileService)
+	fileHandler := handler.NewFileHandler(userFileService)
+	backgroundResponseRepository := repository.NewBackgroundResponseRepository(db)
+	backgroundResponseService := service.ProvideBackgroundResponseService(backgroundResponseRepository, apiKeyRepository, timingWheelService, configConfig)
+	backgroundResponseHandler := handler.NewBackgroundResponseHandler(backgroundResponseService)
+	sseReplayService := service.NewSSEReplayService(configConfig)
+	sseReplayHandler := handler.NewSSEReplayHandler(sseReplayService)
+	responseCacheStore := repository.NewResponseCacheStore(redisClient)
+	responseCacheService := service.NewResponseCacheService(configConfig, responseCacheStore)
+	responseCacheHandler := handler.NewResponseCacheHandler(responseCacheService)
+	conversationStore := repository.NewConversationStore(redisClient)
+	conversationStoreService := service.ProvideConversationStoreService(configConfig, conversationStore, openAIGatewayService)
+	conversationStoreHandler := handler.NewConversationStoreHandler(conversationStoreService)
+	paramSanitizeService := service.NewParamSanitizeService(configConfig, pricingService)
+	paramSanitizeHandler := handler.NewParamSanitizeHandler(paramSanitizeService)
+	contextOverflowService := service.NewContextOverflowService(configConfig, pricingService)
+	contextOverflowHandler := handler.NewContextOverflowHandler(contextOverflowService)
+	chatMultiChoiceService := service.NewChatMultiChoiceService(configConfig)
+	chatMultiChoiceHandler := handler.NewChatMultiChoiceHandler(chatMultiChoiceService)
+	deadLetterHandler := handler.NewDeadLetterHandler(deadLetterService)
+	contentLogHandler := handler.NewContentLogHandler(contentLogService)
+	maintenanceHandler := handler.NewMaintenanceHandler(maintenanceService)
+	gatewayHooksHandler := handler.NewGatewayHooksHandler(runner)
+	metricsService := service.NewMetricsService(configConfig, accountRepository)
+	metricsHandler := handler.NewMetricsHandler(metricsService)
+	healthService := service.NewHealthService(configConfig, db, redisClient, accountRepository, accountHealthService)
+	healthHandler := handler.NewHealthHandler(healthService)
 	idempotencyCoordinator := service.ProvideIdempotencyCoordinator(idempotencyRepository, configConfig)
+	gatewayIdempotencyHandler := handler.NewGatewayIdempotencyHandler(idempotencyCoordinator, configConfig)
 	idempotencyCleanupService := service.ProvideIdempotencyCleanupService(idempotencyRepository, configConfig)
-	handlers := handler.ProvideHandlers(authHandler, userHandler, apiKeyHandler, usageHandler, redeemHandler, subscriptionHandler, announcementHandler, adminHandlers, gatewayHandler, openAIGatewayHandler, soraGatewayHandler, soraClientHandler, handlerSettingHandler, totpHandler, idempotencyCoordinator, idempotencyCleanupService)
+	handlers := handler.ProvideHandlers(authHandler, userHandler, apiKeyHandler, usageHandler, redeemHandler, subscriptionHandler, announcementHandler, adminHandlers, gatewayHandler, openAIGatewayHandler, soraGatewayHandler, soraClientHandler, handlerSettingHandler, totpHandler, batchHandler, fileHandler, backgroundResponseHandler, sseReplayHandler, responseCacheHandler, conversationStoreHandler, paramSanitizeHandler, contextOverflowHandler, chatMultiChoiceHandler, deadLetterHandler, contentLogHandler, gatewayIdempotencyHandler, maintenanceHandler, gatewayHooksHandler, metricsHandler, healthHandler, idempotencyCoordinator, idempotencyCleanupService)
 	jwtAuthMiddleware := middleware.NewJWTAuthMiddleware(authService, userService)
 	adminAuthMiddleware := middleware.NewAdminAuthMiddleware(authService, userService, settingService)
 	apiKeyAuthMiddleware := middleware.NewAPIKeyAuthMiddleware(apiKeyService, subscriptionService, configConfig)
-	engine := server.ProvideRouter(configConfig, handlers, jwtAuthMiddleware, adminAuthMiddleware, apiKeyAuthMiddleware, apiKeyService, subscriptionService, opsService, settingService, redisClient)
-	httpServer := server.ProvideHTTPServer(configConfig, engine)
+	engine := server.ProvideRouter(configConfig, handlers, jwtAuthMiddleware, adminAuthMiddleware, apiKeyAuthMiddleware, apiKeyService, subscriptionService, opsService, settingService, batchService, backgroundResponseService, contextOverflowService, chatMultiChoiceService, redisClient)
+	shutdownDrainer := server.ProvideShutdownDrainer()
+	tlsManager, err := server.ProvideTLSManager(configConfig)
+	if err != nil {
+		return nil, err
+	}
+	httpServer := server.ProvideHTTPServer(configConfig, engine, shutdownDrainer, tlsManager)
 	opsMetricsCollector := service.ProvideOpsMetricsCollector(opsRepository, settingRepository, accountRepository, concurrencyService, db, redisClient, configConfig)
 	opsAggregationService := service.ProvideOpsAggregationService(opsRepository, settingRepository, db, redisClient, configConfig)
-	opsAlertEvaluatorService := service.ProvideOpsAlertEvaluatorService(opsService, opsRepository, emailService, redisClient, configConfig)
+	opsAlertEvaluatorService := service.ProvideOpsAlertEvaluatorService(opsService, opsRepository, emailService, redisClient, configConfig, alertService)
 	opsCleanupService := service.ProvideOpsCleanupService(opsRepository, db, redisClient, configConfig)
 	opsScheduledReportService := service.ProvideOpsScheduledReportService(opsService, userService, emailService, redisClient, configConfig)
 	soraMediaCleanupService := service.ProvideSoraMediaCleanupService(soraMediaStorage, configConfig)
-	tokenRefreshService := service.ProvideTokenRefreshService(accountRepository, soraAccountRepository, oAuthService, openAIOAuthService, geminiOAuthService, antigravityOAuthService, compositeTokenCacheInvalidator, schedulerCache, configConfig, tempUnschedCache)
+	tokenRefreshService := service.ProvideTokenRefreshService(accountRepository, soraAccountRepository, oAuthService, openAIOAuthService, geminiOAuthService, antigravityOAuthService, compositeTokenCacheInvalidator, schedulerCache, configConfig, tempUnschedCache, alertService, leaderElectionService)
 	accountExpiryService := service.ProvideAccountExpiryService(accountRepository)
 	subscriptionExpiryService := service.ProvideSubscriptionExpiryService(userSubscriptionRepository)
 	scheduledTestRunnerService := service.ProvideScheduledTestRunnerService(scheduledTestPlanRepository, scheduledTestService, accountTestService, configConfig)
-	v := provideCleanup(client, redisClient, opsMetricsCollector, opsAggregationService, opsAlertEvaluatorService, opsCleanupService, opsScheduledReportService, opsSystemLogSink, soraMediaCleanupService, schedulerSnapshotService, tokenRefreshService, accountExpiryService, subscriptionExpiryService, usageCleanupService, idempotencyCleanupService, pricingService, emailQueueService, billingCacheService, usageRecordWorkerPool, subscriptionService, oAuthService, openAIOAuthService, geminiOAuthService, antigravityOAuthService, openAIGatewayService, scheduledTestRunnerService)
+	sharedStateCache := repository.NewSharedStateCache(redisClient)
+	sharedStateService := service.ProvideSharedStateService(sharedStateCache, openAIGatewayService, configConfig)
+	v := provideCleanup(client, redisClient, opsMetricsCollector, opsAggregationService, opsAlertEvaluatorService, opsCleanupService, opsScheduledReportService, opsSystemLogSink, soraMediaCleanupService, schedulerSnapshotService, tokenRefreshService, accountExpiryService, subscriptionExpiryService, usageCleanupService, batchService, backgroundResponseService, userFileService, idempotencyCleanupService, pricingService, emailQueueService, billingCacheService, usageRecordWorkerPool, subscriptionService, oAuthService, openAIOAuthService, geminiOAuthService, antigravityOAuthService, openAIGatewayService, scheduledTestRunnerService, accountHealthService, accountWarmupService, codexModelCatalogService, codexVersionTracker, sharedStateService, leaderElectionService, runtimeSettingsService, deadLetterService, contentLogService, maintenanceService, usageWebhookService)
 	application := &Application{
-		Server:  httpServer,
-		Cleanup: v,
+		Server:       httpServer,
+		Drainer:      shutdownDrainer,
+		TLS:          tlsManager,
+		Health:       healthService,
+		ConfigReload: configReloadService,
+		Cleanup:      v,
 	}
 	return application, nil
 }
@@ -241,8 +330,12 @@ func initializeApplication(buildInfo handler.BuildInfo) (*Application, error) {
 // wire.go:
 
 type Application struct {
-	Server  *http.Server
-	Cleanup func()
+	Server       *http.Server
+	Drainer      *server.ShutdownDrainer
+	TLS          *server.TLSManager
+	Health       *service.HealthService
+	ConfigReload *service.ConfigReloadService
+	Cleanup      func()
 }
 
 func provideServiceBuildInfo(buildInfo handler.BuildInfo) service.BuildInfo {
@@ -267,6 +360,9 @@ func provideCleanup(
 	accountExpiry *service.AccountExpiryService,
 	subscriptionExpiry *service.SubscriptionExpiryService,
 	usageCleanup *service.UsageCleanupService,
+	batch *service.BatchService,
+	backgroundResponse *service.BackgroundResponseService,
+	userFile *service.UserFileService,
 	idempotencyCleanup *service.IdempotencyCleanupService,
 	pricing *service.PricingService,
 	emailQueue *service.EmailQueueService,
@@ -279,6 +375,17 @@ func provideCleanup(
 	antigravityOAuth *service.AntigravityOAuthService,
 	openAIGateway *service.OpenAIGatewayService,
 	scheduledTestRunner *service.ScheduledTestRunnerService,
+	accountHealth *service.AccountHealthService,
+	accountWarmup *service.AccountWarmupService,
+	codexModelCatalog *service.CodexModelCatalogService,
+	codexVersionTracker *service.CodexVersionTracker,
+	sharedState *service.SharedStateService,
+	leaderElection *service.LeaderElectionService,
+	runtimeSettings *service.RuntimeSettingsService,
+	deadLetter *service.DeadLetterService,
+	contentLog *service.ContentLogService,
+	maintenance *service.MaintenanceService,
+	usageWebhook *service.UsageWebhookService,
 ) func() {
 	return func() {
 		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
@@ -344,6 +451,24 @@ func provideCleanup(
 				}
 				return nil
 			}},
+			{"BatchService", func() error {
+				if batch != nil {
+					batch.Stop()
+				}
+				return nil
+			}},
+			{"BackgroundResponseService", func() error {
+				if backgroundResponse != nil {
+					backgroundResponse.Stop()
+				}
+				return nil
+			}},
+			{"UserFileService", func() error {
+				if userFile != nil {
+					userFile.Stop()
+				}
+				return nil
+			}},
 			{"IdempotencyCleanupService", func() error {
 				if idempotencyCleanup != nil {
 					idempotencyCleanup.Stop()
@@ -414,9 +539,74 @@ func provideCleanup(
 				}
 				return nil
 			}},
+			{"AccountHealthService", func() error {
+				if accountHealth != nil {
+					accountHealth.Stop()
+				}
+				return nil
+			}},
+			{"AccountWarmupService", func() error {
+				if accountWarmup != nil {
+					accountWarmup.Stop()
+				}
+				return nil
+			}},
+			{"CodexModelCatalogService", func() error {
+				if codexModelCatalog != nil {
+					codexModelCatalog.Stop()
+				}
+				return nil
+			}},
+			{"CodexVersionTracker", func() error {
+				if codexVersionTracker != nil {
+					codexVersionTracker.Stop()
+				}
+				return nil
+			}},
+			{"SharedStateService", func() error {
+				if sharedState != nil {
+					sharedState.Stop()
+				}
+				return nil
+			}},
+			{"RuntimeSettingsService", func() error {
+				if runtimeSettings != nil {
+					runtimeSettings.Stop()
+				}
+				return nil
+			}},
+			{"DeadLetterService", func() error {
+				if deadLetter != nil {
+					deadLetter.Stop()
+				}
+				return nil
+			}},
+			{"ContentLogService", func() error {
+				if contentLog != nil {
+					contentLog.Stop()
+				}
+				return nil
+			}},
+			{"MaintenanceService", func() error {
+				if maintenance != nil {
+					maintenance.Stop()
+				}
+				return nil
+			}},
+			{"UsageWebhookService", func() error {
+				usageWebhook.Stop()
+				return nil
+			}},
 		}
 
 		infraSteps := []cleanupStep{
+			// 后台任务全部停止后再释放领导者租约，且须在关闭 Redis 之前
+			{"LeaderElectionService", func() error {
+				if leaderElection != nil {
+					leaderElection.Stop()
+				}
+				return nil
+			}},
 			{"Redis", func() error {
 				if rdb == nil {
 					return nil
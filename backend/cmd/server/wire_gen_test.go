@@ -47,6 +47,12 @@ func TestProvideCleanup_WithMinimalDependencies_NoPanic(t *testing.T) {
 	idempotencyCleanupSvc := service.NewIdempotencyCleanupService(nil, cfg)
 	schedulerSnapshotSvc := service.NewSchedulerSnapshotService(nil, nil, nil, nil, cfg)
 	opsSystemLogSinkSvc := service.NewOpsSystemLogSink(nil)
+	accountHealthSvc := service.NewAccountHealthService(nil, nil, nil, nil, cfg)
+	accountWarmupSvc := service.NewAccountWarmupService(nil, nil, accountHealthSvc, cfg)
+	codexModelCatalogSvc := service.NewCodexModelCatalogService(nil, nil, cfg)
+	codexVersionTracker := service.NewCodexVersionTracker(nil, cfg)
+	sharedStateSvc := service.NewSharedStateService(nil, cfg)
+	leaderElectionSvc := service.NewLeaderElectionService(nil, cfg)
 
 	cleanup := provideCleanup(
 		nil, // entClient
@@ -63,6 +69,9 @@ func TestProvideCleanup_WithMinimalDependencies_NoPanic(t *testing.T) {
 		accountExpirySvc,
 		subscriptionExpirySvc,
 		&service.UsageCleanupService{},
+		&service.BatchService{},
+		&service.BackgroundResponseService{},
+		&service.UserFileService{},
 		idempotencyCleanupSvc,
 		pricingSvc,
 		emailQueueSvc,
@@ -75,6 +84,17 @@ func TestProvideCleanup_WithMinimalDependencies_NoPanic(t *testing.T) {
 		antigravityOAuthSvc,
 		nil, // openAIGateway
 		nil, // scheduledTestRunner
+		accountHealthSvc,
+		accountWarmupSvc,
+		codexModelCatalogSvc,
+		codexVersionTracker,
+		sharedStateSvc,
+		leaderElectionSvc,
+		service.NewRuntimeSettingsService(nil, nil, cfg),
+		service.NewDeadLetterService(nil, nil, cfg),
+		service.NewContentLogService(nil, cfg),
+		service.NewMaintenanceService(nil),
+		service.NewUsageWebhookService(cfg, nil),
 	)
 
 	require.NotPanics(t, func() {
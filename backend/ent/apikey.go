@@ -32,6 +32,8 @@ type APIKey struct {
 	Key string `json:"key,omitempty"`
 	// Name holds the value of the "name" field.
 	Name string `json:"name,omitempty"`
+	// Free-form note describing what this API key is used for
+	Description string `json:"description,omitempty"`
 	// GroupID holds the value of the "group_id" field.
 	GroupID *int64 `json:"group_id,omitempty"`
 	// Status holds the value of the "status" field.
@@ -42,10 +44,36 @@ type APIKey struct {
 	IPWhitelist []string `json:"ip_whitelist,omitempty"`
 	// Blocked IPs/CIDRs
 	IPBlacklist []string `json:"ip_blacklist,omitempty"`
+	// Allowed endpoint scopes, e.g. ["messages", "responses"] (empty = all endpoints)
+	Scopes []string `json:"scopes,omitempty"`
+	// Allowed model patterns, trailing * wildcard supported, e.g. ["claude-haiku-*"] (empty = all models)
+	AllowedModels []string `json:"allowed_models,omitempty"`
+	// Allowed client class: codex, claude_code, coding_agent or api (empty = any client)
+	ClientRestriction string `json:"client_restriction,omitempty"`
+	// Admin-assigned tags matched by routing rules, e.g. ["pro"]
+	Tags []string `json:"tags,omitempty"`
+	// Model served when every account for the requested model is unavailable ('' = return 503)
+	DegradedFallbackModel string `json:"degraded_fallback_model,omitempty"`
+	// System prompt injected into every request made with this key ('' = none)
+	SystemPrompt string `json:"system_prompt,omitempty"`
+	// How system_prompt is applied: prepend, append or replace ('' = prepend)
+	SystemPromptMode string `json:"system_prompt_mode,omitempty"`
+	// Prompt moderation pre-filter: off, flag or block ('' = follow gateway.moderation.pre_filter)
+	ModerationMode string `json:"moderation_mode,omitempty"`
+	// Persist full prompts and responses of this key's requests for debugging (purged after content_log.retention_days)
+	LogContent bool `json:"log_content,omitempty"`
+	// Dry-run mode: requests are answered by the built-in mock upstream and never reach real accounts
+	Simulate bool `json:"simulate,omitempty"`
 	// Quota limit in USD for this API key (0 = unlimited)
 	Quota float64 `json:"quota,omitempty"`
 	// Used quota amount in USD
 	QuotaUsed float64 `json:"quota_used,omitempty"`
+	// Max number of images this API key may generate (0 = unlimited)
+	ImageQuota int `json:"image_quota,omitempty"`
+	// Number of images generated with this API key
+	ImageQuotaUsed int `json:"image_quota_used,omitempty"`
+	// Drop reasoning/thinking output for clients other than Codex and Claude Code
+	SuppressReasoning bool `json:"suppress_reasoning,omitempty"`
 	// Expiration time for this API key (null = never expires)
 	ExpiresAt *time.Time `json:"expires_at,omitempty"`
 	// Rate limit in USD per 5 hours (0 = unlimited)
@@ -66,6 +94,36 @@ type APIKey struct {
 	Window1dStart *time.Time `json:"window_1d_start,omitempty"`
 	// Start time of the current 7d rate limit window
 	Window7dStart *time.Time `json:"window_7d_start,omitempty"`
+	// Max requests per minute (0 = unlimited)
+	RpmLimit int `json:"rpm_limit,omitempty"`
+	// Max tokens per minute (0 = unlimited)
+	TpmLimit int `json:"tpm_limit,omitempty"`
+	// Max requests per calendar day (0 = unlimited)
+	DailyRequestLimit int `json:"daily_request_limit,omitempty"`
+	// Max tokens per calendar day (0 = unlimited)
+	DailyTokenLimit int64 `json:"daily_token_limit,omitempty"`
+	// Max requests per calendar month (0 = unlimited)
+	MonthlyRequestLimit int `json:"monthly_request_limit,omitempty"`
+	// Max tokens per calendar month (0 = unlimited)
+	MonthlyTokenLimit int64 `json:"monthly_token_limit,omitempty"`
+	// Spend budget in USD per period (0 = no budget)
+	BudgetAmount float64 `json:"budget_amount,omitempty"`
+	// Budget reset period: day, week or month
+	BudgetPeriod string `json:"budget_period,omitempty"`
+	// Action once the budget is exceeded: disable or downgrade
+	BudgetAction string `json:"budget_action,omitempty"`
+	// Model requests are switched to when budget_action is downgrade
+	BudgetFallbackModel string `json:"budget_fallback_model,omitempty"`
+	// Spent amount in USD for the current budget period
+	BudgetUsed float64 `json:"budget_used,omitempty"`
+	// Start time of the current budget period
+	BudgetPeriodStart *time.Time `json:"budget_period_start,omitempty"`
+	// When the budget was exceeded in the current period (null = within budget)
+	BudgetExceededAt *time.Time `json:"budget_exceeded_at,omitempty"`
+	// Secret replaced by the last rotation (valid during the grace window)
+	PreviousKey *string `json:"previous_key,omitempty"`
+	// End of the grace window for previous_key
+	PreviousKeyExpiresAt *time.Time `json:"previous_key_expires_at,omitempty"`
 	// Edges holds the relations/edges for other nodes in the graph.
 	// The values are being populated by the APIKeyQuery when eager-loading is set.
 	Edges        APIKeyEdges `json:"edges"`
@@ -121,15 +179,17 @@ func (*APIKey) scanValues(columns []string) ([]any, error) {
 	values := make([]any, len(columns))
 	for i := range columns {
 		switch columns[i] {
-		case apikey.FieldIPWhitelist, apikey.FieldIPBlacklist:
+		case apikey.FieldIPWhitelist, apikey.FieldIPBlacklist, apikey.FieldScopes, apikey.FieldAllowedModels, apikey.FieldTags:
 			values[i] = new([]byte)
-		case apikey.FieldQuota, apikey.FieldQuotaUsed, apikey.FieldRateLimit5h, apikey.FieldRateLimit1d, apikey.FieldRateLimit7d, apikey.FieldUsage5h, apikey.FieldUsage1d, apikey.FieldUsage7d:
+		case apikey.FieldLogContent, apikey.FieldSimulate, apikey.FieldSuppressReasoning:
+			values[i] = new(sql.NullBool)
+		case apikey.FieldQuota, apikey.FieldQuotaUsed, apikey.FieldRateLimit5h, apikey.FieldRateLimit1d, apikey.FieldRateLimit7d, apikey.FieldUsage5h, apikey.FieldUsage1d, apikey.FieldUsage7d, apikey.FieldBudgetAmount, apikey.FieldBudgetUsed:
 			values[i] = new(sql.NullFloat64)
-		case apikey.FieldID, apikey.FieldUserID, apikey.FieldGroupID:
+		case apikey.FieldID, apikey.FieldUserID, apikey.FieldGroupID, apikey.FieldImageQuota, apikey.FieldImageQuotaUsed, apikey.FieldRpmLimit, apikey.FieldTpmLimit, apikey.FieldDailyRequestLimit, apikey.FieldDailyTokenLimit, apikey.FieldMonthlyRequestLimit, apikey.FieldMonthlyTokenLimit:
 			values[i] = new(sql.NullInt64)
-		case apikey.FieldKey, apikey.FieldName, apikey.FieldStatus:
+		case apikey.FieldKey, apikey.FieldName, apikey.FieldDescription, apikey.FieldStatus, apikey.FieldClientRestriction, apikey.FieldDegradedFallbackModel, apikey.FieldSystemPrompt, apikey.FieldSystemPromptMode, apikey.FieldModerationMode, apikey.FieldBudgetPeriod, apikey.FieldBudgetAction, apikey.FieldBudgetFallbackModel, apikey.FieldPreviousKey:
 			values[i] = new(sql.NullString)
-		case apikey.FieldCreatedAt, apikey.FieldUpdatedAt, apikey.FieldDeletedAt, apikey.FieldLastUsedAt, apikey.FieldExpiresAt, apikey.FieldWindow5hStart, apikey.FieldWindow1dStart, apikey.FieldWindow7dStart:
+		case apikey.FieldCreatedAt, apikey.FieldUpdatedAt, apikey.FieldDeletedAt, apikey.FieldLastUsedAt, apikey.FieldExpiresAt, apikey.FieldWindow5hStart, apikey.FieldWindow1dStart, apikey.FieldWindow7dStart, apikey.FieldBudgetPeriodStart, apikey.FieldBudgetExceededAt, apikey.FieldPreviousKeyExpiresAt:
 			values[i] = new(sql.NullTime)
 		default:
 			values[i] = new(sql.UnknownType)
@@ -189,6 +249,12 @@ func (_m *APIKey) assignValues(columns []string, values []any) error {
 			} else if value.Valid {
 				_m.Name = value.String
 			}
+		case apikey.FieldDescription:
+			if value, ok := values[i].(*sql.NullString); !ok {
+				return fmt.Errorf("unexpected type %T for field description", values[i])
+			} else if value.Valid {
+				_m.Description = value.String
+			}
 		case apikey.FieldGroupID:
 			if value, ok := values[i].(*sql.NullInt64); !ok {
 				return fmt.Errorf("unexpected type %T for field group_id", values[i])
@@ -225,6 +291,72 @@ func (_m *APIKey) assignValues(columns []string, values []any) error {
 					return fmt.Errorf("unmarshal field ip_blacklist: %w", err)
 				}
 			}
+		case apikey.FieldScopes:
+			if value, ok := values[i].(*[]byte); !ok {
+				return fmt.Errorf("unexpected type %T for field scopes", values[i])
+			} else if value != nil && len(*value) > 0 {
+				if err := json.Unmarshal(*value, &_m.Scopes); err != nil {
+					return fmt.Errorf("unmarshal field scopes: %w", err)
+				}
+			}
+		case apikey.FieldAllowedModels:
+			if value, ok := values[i].(*[]byte); !ok {
+				return fmt.Errorf("unexpected type %T for field allowed_models", values[i])
+			} else if value != nil && len(*value) > 0 {
+				if err := json.Unmarshal(*value, &_m.AllowedModels); err != nil {
+					return fmt.Errorf("unmarshal field allowed_models: %w", err)
+				}
+			}
+		case apikey.FieldClientRestriction:
+			if value, ok := values[i].(*sql.NullString); !ok {
+				return fmt.Errorf("unexpected type %T for field client_restriction", values[i])
+			} else if value.Valid {
+				_m.ClientRestriction = value.String
+			}
+		case apikey.FieldTags:
+			if value, ok := values[i].(*[]byte); !ok {
+				return fmt.Errorf("unexpected type %T for field tags", values[i])
+			} else if value != nil && len(*value) > 0 {
+				if err := json.Unmarshal(*value, &_m.Tags); err != nil {
+					return fmt.Errorf("unmarshal field tags: %w", err)
+				}
+			}
+		case apikey.FieldDegradedFallbackModel:
+			if value, ok := values[i].(*sql.NullString); !ok {
+				return fmt.Errorf("unexpected type %T for field degraded_fallback_model", values[i])
+			} else if value.Valid {
+				_m.DegradedFallbackModel = value.String
+			}
+		case apikey.FieldSystemPrompt:
+			if value, ok := values[i].(*sql.NullString); !ok {
+				return fmt.Errorf("unexpected type %T for field system_prompt", values[i])
+			} else if value.Valid {
+				_m.SystemPrompt = value.String
+			}
+		case apikey.FieldSystemPromptMode:
+			if value, ok := values[i].(*sql.NullString); !ok {
+				return fmt.Errorf("unexpected type %T for field system_prompt_mode", values[i])
+			} else if value.Valid {
+				_m.SystemPromptMode = value.String
+			}
+		case apikey.FieldModerationMode:
+			if value, ok := values[i].(*sql.NullString); !ok {
+				return fmt.Errorf("unexpected type %T for field moderation_mode", values[i])
+			} else if value.Valid {
+				_m.ModerationMode = value.String
+			}
+		case apikey.FieldLogContent:
+			if value, ok := values[i].(*sql.NullBool); !ok {
+				return fmt.Errorf("unexpected type %T for field log_content", values[i])
+			} else if value.Valid {
+				_m.LogContent = value.Bool
+			}
+		case apikey.FieldSimulate:
+			if value, ok := values[i].(*sql.NullBool); !ok {
+				return fmt.Errorf("unexpected type %T for field simulate", values[i])
+			} else if value.Valid {
+				_m.Simulate = value.Bool
+			}
 		case apikey.FieldQuota:
 			if value, ok := values[i].(*sql.NullFloat64); !ok {
 				return fmt.Errorf("unexpected type %T for field quota", values[i])
@@ -237,6 +369,24 @@ func (_m *APIKey) assignValues(columns []string, values []any) error {
 			} else if value.Valid {
 				_m.QuotaUsed = value.Float64
 			}
+		case apikey.FieldImageQuota:
+			if value, ok := values[i].(*sql.NullInt64); !ok {
+				return fmt.Errorf("unexpected type %T for field image_quota", values[i])
+			} else if value.Valid {
+				_m.ImageQuota = int(value.Int64)
+			}
+		case apikey.FieldImageQuotaUsed:
+			if value, ok := values[i].(*sql.NullInt64); !ok {
+				return fmt.Errorf("unexpected type %T for field image_quota_used", values[i])
+			} else if value.Valid {
+				_m.ImageQuotaUsed = int(value.Int64)
+			}
+		case apikey.FieldSuppressReasoning:
+			if value, ok := values[i].(*sql.NullBool); !ok {
+				return fmt.Errorf("unexpected type %T for field suppress_reasoning", values[i])
+			} else if value.Valid {
+				_m.SuppressReasoning = value.Bool
+			}
 		case apikey.FieldExpiresAt:
 			if value, ok := values[i].(*sql.NullTime); !ok {
 				return fmt.Errorf("unexpected type %T for field expires_at", values[i])
@@ -301,6 +451,100 @@ func (_m *APIKey) assignValues(columns []string, values []any) error {
 				_m.Window7dStart = new(time.Time)
 				*_m.Window7dStart = value.Time
 			}
+		case apikey.FieldRpmLimit:
+			if value, ok := values[i].(*sql.NullInt64); !ok {
+				return fmt.Errorf("unexpected type %T for field rpm_limit", values[i])
+			} else if value.Valid {
+				_m.RpmLimit = int(value.Int64)
+			}
+		case apikey.FieldTpmLimit:
+			if value, ok := values[i].(*sql.NullInt64); !ok {
+				return fmt.Errorf("unexpected type %T for field tpm_limit", values[i])
+			} else if value.Valid {
+				_m.TpmLimit = int(value.Int64)
+			}
+		case apikey.FieldDailyRequestLimit:
+			if value, ok := values[i].(*sql.NullInt64); !ok {
+				return fmt.Errorf("unexpected type %T for field daily_request_limit", values[i])
+			} else if value.Valid {
+				_m.DailyRequestLimit = int(value.Int64)
+			}
+		case apikey.FieldDailyTokenLimit:
+			if value, ok := values[i].(*sql.NullInt64); !ok {
+				return fmt.Errorf("unexpected type %T for field daily_token_limit", values[i])
+			} else if value.Valid {
+				_m.DailyTokenLimit = value.Int64
+			}
+		case apikey.FieldMonthlyRequestLimit:
+			if value, ok := values[i].(*sql.NullInt64); !ok {
+				return fmt.Errorf("unexpected type %T for field monthly_request_limit", values[i])
+			} else if value.Valid {
+				_m.MonthlyRequestLimit = int(value.Int64)
+			}
+		case apikey.FieldMonthlyTokenLimit:
+			if value, ok := values[i].(*sql.NullInt64); !ok {
+				return fmt.Errorf("unexpected type %T for field monthly_token_limit", values[i])
+			} else if value.Valid {
+				_m.MonthlyTokenLimit = value.Int64
+			}
+		case apikey.FieldBudgetAmount:
+			if value, ok := values[i].(*sql.NullFloat64); !ok {
+				return fmt.Errorf("unexpected type %T for field budget_amount", values[i])
+			} else if value.Valid {
+				_m.BudgetAmount = value.Float64
+			}
+		case apikey.FieldBudgetPeriod:
+			if value, ok := values[i].(*sql.NullString); !ok {
+				return fmt.Errorf("unexpected type %T for field budget_period", values[i])
+			} else if value.Valid {
+				_m.BudgetPeriod = value.String
+			}
+		case apikey.FieldBudgetAction:
+			if value, ok := values[i].(*sql.NullString); !ok {
+				return fmt.Errorf("unexpected type %T for field budget_action", values[i])
+			} else if value.Valid {
+				_m.BudgetAction = value.String
+			}
+		case apikey.FieldBudgetFallbackModel:
+			if value, ok := values[i].(*sql.NullString); !ok {
+				return fmt.Errorf("unexpected type %T for field budget_fallback_model", values[i])
+			} else if value.Valid {
+				_m.BudgetFallbackModel = value.String
+			}
+		case apikey.FieldBudgetUsed:
+			if value, ok := values[i].(*sql.NullFloat64); !ok {
+				return fmt.Errorf("unexpected type %T for field budget_used", values[i])
+			} else if value.Valid {
+				_m.BudgetUsed = value.Float64
+			}
+		case apikey.FieldBudgetPeriodStart:
+			if value, ok := values[i].(*sql.NullTime); !ok {
+				return fmt.Errorf("unexpected type %T for field budget_period_start", values[i])
+			} else if value.Valid {
+				_m.BudgetPeriodStart = new(time.Time)
+				*_m.BudgetPeriodStart = value.Time
+			}
+		case apikey.FieldBudgetExceededAt:
+			if value, ok := values[i].(*sql.NullTime); !ok {
+				return fmt.Errorf("unexpected type %T for field budget_exceeded_at", values[i])
+			} else if value.Valid {
+				_m.BudgetExceededAt = new(time.Time)
+				*_m.BudgetExceededAt = value.Time
+			}
+		case apikey.FieldPreviousKey:
+			if value, ok := values[i].(*sql.NullString); !ok {
+				return fmt.Errorf("unexpected type %T for field previous_key", values[i])
+			} else if value.Valid {
+				_m.PreviousKey = new(string)
+				*_m.PreviousKey = value.String
+			}
+		case apikey.FieldPreviousKeyExpiresAt:
+			if value, ok := values[i].(*sql.NullTime); !ok {
+				return fmt.Errorf("unexpected type %T for field previous_key_expires_at", values[i])
+			} else if value.Valid {
+				_m.PreviousKeyExpiresAt = new(time.Time)
+				*_m.PreviousKeyExpiresAt = value.Time
+			}
 		default:
 			_m.selectValues.Set(columns[i], values[i])
 		}
@@ -372,6 +616,9 @@ func (_m *APIKey) String() string {
 	builder.WriteString("name=")
 	builder.WriteString(_m.Name)
 	builder.WriteString(", ")
+	builder.WriteString("description=")
+	builder.WriteString(_m.Description)
+	builder.WriteString(", ")
 	if v := _m.GroupID; v != nil {
 		builder.WriteString("group_id=")
 		builder.WriteString(fmt.Sprintf("%v", *v))
@@ -391,12 +638,51 @@ func (_m *APIKey) String() string {
 	builder.WriteString("ip_blacklist=")
 	builder.WriteString(fmt.Sprintf("%v", _m.IPBlacklist))
 	builder.WriteString(", ")
+	builder.WriteString("scopes=")
+	builder.WriteString(fmt.Sprintf("%v", _m.Scopes))
+	builder.WriteString(", ")
+	builder.WriteString("allowed_models=")
+	builder.WriteString(fmt.Sprintf("%v", _m.AllowedModels))
+	builder.WriteString(", ")
+	builder.WriteString("client_restriction=")
+	builder.WriteString(_m.ClientRestriction)
+	builder.WriteString(", ")
+	builder.WriteString("tags=")
+	builder.WriteString(fmt.Sprintf("%v", _m.Tags))
+	builder.WriteString(", ")
+	builder.WriteString("degraded_fallback_model=")
+	builder.WriteString(_m.DegradedFallbackModel)
+	builder.WriteString(", ")
+	builder.WriteString("system_prompt=")
+	builder.WriteString(_m.SystemPrompt)
+	builder.WriteString(", ")
+	builder.WriteString("system_prompt_mode=")
+	builder.WriteString(_m.SystemPromptMode)
+	builder.WriteString(", ")
+	builder.WriteString("moderation_mode=")
+	builder.WriteString(_m.ModerationMode)
+	builder.WriteString(", ")
+	builder.WriteString("log_content=")
+	builder.WriteString(fmt.Sprintf("%v", _m.LogContent))
+	builder.WriteString(", ")
+	builder.WriteString("simulate=")
+	builder.WriteString(fmt.Sprintf("%v", _m.Simulate))
+	builder.WriteString(", ")
 	builder.WriteString("quota=")
 	builder.WriteString(fmt.Sprintf("%v", _m.Quota))
 	builder.WriteString(", ")
 	builder.WriteString("quota_used=")
 	builder.WriteString(fmt.Sprintf("%v", _m.QuotaUsed))
 	builder.WriteString(", ")
+	builder.WriteString("image_quota=")
+	builder.WriteString(fmt.Sprintf("%v", _m.ImageQuota))
+	builder.WriteString(", ")
+	builder.WriteString("image_quota_used=")
+	builder.WriteString(fmt.Sprintf("%v", _m.ImageQuotaUsed))
+	builder.WriteString(", ")
+	builder.WriteString("suppress_reasoning=")
+	builder.WriteString(fmt.Sprintf("%v", _m.SuppressReasoning))
+	builder.WriteString(", ")
 	if v := _m.ExpiresAt; v != nil {
 		builder.WriteString("expires_at=")
 		builder.WriteString(v.Format(time.ANSIC))
@@ -434,6 +720,59 @@ func (_m *APIKey) String() string {
 		builder.WriteString("window_7d_start=")
 		builder.WriteString(v.Format(time.ANSIC))
 	}
+	builder.WriteString(", ")
+	builder.WriteString("rpm_limit=")
+	builder.WriteString(fmt.Sprintf("%v", _m.RpmLimit))
+	builder.WriteString(", ")
+	builder.WriteString("tpm_limit=")
+	builder.WriteString(fmt.Sprintf("%v", _m.TpmLimit))
+	builder.WriteString(", ")
+	builder.WriteString("daily_request_limit=")
+	builder.WriteString(fmt.Sprintf("%v", _m.DailyRequestLimit))
+	builder.WriteString(", ")
+	builder.WriteString("daily_token_limit=")
+	builder.WriteString(fmt.Sprintf("%v", _m.DailyTokenLimit))
+	builder.WriteString(", ")
+	builder.WriteString("monthly_request_limit=")
+	builder.WriteString(fmt.Sprintf("%v", _m.MonthlyRequestLimit))
+	builder.WriteString(", ")
+	builder.WriteString("monthly_token_limit=")
+	builder.WriteString(fmt.Sprintf("%v", _m.MonthlyTokenLimit))
+	builder.WriteString(", ")
+	builder.WriteString("budget_amount=")
+	builder.WriteString(fmt.Sprintf("%v", _m.BudgetAmount))
+	builder.WriteString(", ")
+	builder.WriteString("budget_period=")
+	builder.WriteString(_m.BudgetPeriod)
+	builder.WriteString(", ")
+	builder.WriteString("budget_action=")
+	builder.WriteString(_m.BudgetAction)
+	builder.WriteString(", ")
+	builder.WriteString("budget_fallback_model=")
+	builder.WriteString(_m.BudgetFallbackModel)
+	builder.WriteString(", ")
+	builder.WriteString("budget_used=")
+	builder.WriteString(fmt.Sprintf("%v", _m.BudgetUsed))
+	builder.WriteString(", ")
+	if v := _m.BudgetPeriodStart; v != nil {
+		builder.WriteString("budget_period_start=")
+		builder.WriteString(v.Format(time.ANSIC))
+	}
+	builder.WriteString(", ")
+	if v := _m.BudgetExceededAt; v != nil {
+		builder.WriteString("budget_exceeded_at=")
+		builder.WriteString(v.Format(time.ANSIC))
+	}
+	builder.WriteString(", ")
+	if v := _m.PreviousKey; v != nil {
+		builder.WriteString("previous_key=")
+		builder.WriteString(*v)
+	}
+	builder.WriteString(", ")
+	if v := _m.PreviousKeyExpiresAt; v != nil {
+		builder.WriteString("previous_key_expires_at=")
+		builder.WriteString(v.Format(time.ANSIC))
+	}
 	builder.WriteByte(')')
 	return builder.String()
 }
@@ -27,6 +27,8 @@ const (
 	FieldKey = "key"
 	// FieldName holds the string denoting the name field in the database.
 	FieldName = "name"
+	// FieldDescription holds the string denoting the description field in the database.
+	FieldDescription = "description"
 	// FieldGroupID holds the string denoting the group_id field in the database.
 	FieldGroupID = "group_id"
 	// FieldStatus holds the string denoting the status field in the database.
@@ -37,10 +39,36 @@ const (
 	FieldIPWhitelist = "ip_whitelist"
 	// FieldIPBlacklist holds the string denoting the ip_blacklist field in the database.
 	FieldIPBlacklist = "ip_blacklist"
+	// FieldScopes holds the string denoting the scopes field in the database.
+	FieldScopes = "scopes"
+	// FieldAllowedModels holds the string denoting the allowed_models field in the database.
+	FieldAllowedModels = "allowed_models"
+	// FieldClientRestriction holds the string denoting the client_restriction field in the database.
+	FieldClientRestriction = "client_restriction"
+	// FieldTags holds the string denoting the tags field in the database.
+	FieldTags = "tags"
+	// FieldDegradedFallbackModel holds the string denoting the degraded_fallback_model field in the database.
+	FieldDegradedFallbackModel = "degraded_fallback_model"
+	// FieldSystemPrompt holds the string denoting the system_prompt field in the database.
+	FieldSystemPrompt = "system_prompt"
+	// FieldSystemPromptMode holds the string denoting the system_prompt_mode field in the database.
+	FieldSystemPromptMode = "system_prompt_mode"
+	// FieldModerationMode holds the string denoting the moderation_mode field in the database.
+	FieldModerationMode = "moderation_mode"
+	// FieldLogContent holds the string denoting the log_content field in the database.
+	FieldLogContent = "log_content"
+	// FieldSimulate holds the string denoting the simulate field in the database.
+	FieldSimulate = "simulate"
 	// FieldQuota holds the string denoting the quota field in the database.
 	FieldQuota = "quota"
 	// FieldQuotaUsed holds the string denoting the quota_used field in the database.
 	FieldQuotaUsed = "quota_used"
+	// FieldImageQuota holds the string denoting the image_quota field in the database.
+	FieldImageQuota = "image_quota"
+	// FieldImageQuotaUsed holds the string denoting the image_quota_used field in the database.
+	FieldImageQuotaUsed = "image_quota_used"
+	// FieldSuppressReasoning holds the string denoting the suppress_reasoning field in the database.
+	FieldSuppressReasoning = "suppress_reasoning"
 	// FieldExpiresAt holds the string denoting the expires_at field in the database.
 	FieldExpiresAt = "expires_at"
 	// FieldRateLimit5h holds the string denoting the rate_limit_5h field in the database.
@@ -61,6 +89,36 @@ const (
 	FieldWindow1dStart = "window_1d_start"
 	// FieldWindow7dStart holds the string denoting the window_7d_start field in the database.
 	FieldWindow7dStart = "window_7d_start"
+	// FieldRpmLimit holds the string denoting the rpm_limit field in the database.
+	FieldRpmLimit = "rpm_limit"
+	// FieldTpmLimit holds the string denoting the tpm_limit field in the database.
+	FieldTpmLimit = "tpm_limit"
+	// FieldDailyRequestLimit holds the string denoting the daily_request_limit field in the database.
+	FieldDailyRequestLimit = "daily_request_limit"
+	// FieldDailyTokenLimit holds the string denoting the daily_token_limit field in the database.
+	FieldDailyTokenLimit = "daily_token_limit"
+	// FieldMonthlyRequestLimit holds the string denoting the monthly_request_limit field in the database.
+	FieldMonthlyRequestLimit = "monthly_request_limit"
+	// FieldMonthlyTokenLimit holds the string denoting the monthly_token_limit field in the database.
+	FieldMonthlyTokenLimit = "monthly_token_limit"
+	// FieldBudgetAmount holds the string denoting the budget_amount field in the database.
+	FieldBudgetAmount = "budget_amount"
+	// FieldBudgetPeriod holds the string denoting the budget_period field in the database.
+	FieldBudgetPeriod = "budget_period"
+	// FieldBudgetAction holds the string denoting the budget_action field in the database.
+	FieldBudgetAction = "budget_action"
+	// FieldBudgetFallbackModel holds the string denoting the budget_fallback_model field in the database.
+	FieldBudgetFallbackModel = "budget_fallback_model"
+	// FieldBudgetUsed holds the string denoting the budget_used field in the database.
+	FieldBudgetUsed = "budget_used"
+	// FieldBudgetPeriodStart holds the string denoting the budget_period_start field in the database.
+	FieldBudgetPeriodStart = "budget_period_start"
+	// FieldBudgetExceededAt holds the string denoting the budget_exceeded_at field in the database.
+	FieldBudgetExceededAt = "budget_exceeded_at"
+	// FieldPreviousKey holds the string denoting the previous_key field in the database.
+	FieldPreviousKey = "previous_key"
+	// FieldPreviousKeyExpiresAt holds the string denoting the previous_key_expires_at field in the database.
+	FieldPreviousKeyExpiresAt = "previous_key_expires_at"
 	// EdgeUser holds the string denoting the user edge name in mutations.
 	EdgeUser = "user"
 	// EdgeGroup holds the string denoting the group edge name in mutations.
@@ -101,13 +159,27 @@ var Columns = []string{
 	FieldUserID,
 	FieldKey,
 	FieldName,
+	FieldDescription,
 	FieldGroupID,
 	FieldStatus,
 	FieldLastUsedAt,
 	FieldIPWhitelist,
 	FieldIPBlacklist,
+	FieldScopes,
+	FieldAllowedModels,
+	FieldClientRestriction,
+	FieldTags,
+	FieldDegradedFallbackModel,
+	FieldSystemPrompt,
+	FieldSystemPromptMode,
+	FieldModerationMode,
+	FieldLogContent,
+	FieldSimulate,
 	FieldQuota,
 	FieldQuotaUsed,
+	FieldImageQuota,
+	FieldImageQuotaUsed,
+	FieldSuppressReasoning,
 	FieldExpiresAt,
 	FieldRateLimit5h,
 	FieldRateLimit1d,
@@ -118,6 +190,21 @@ var Columns = []string{
 	FieldWindow5hStart,
 	FieldWindow1dStart,
 	FieldWindow7dStart,
+	FieldRpmLimit,
+	FieldTpmLimit,
+	FieldDailyRequestLimit,
+	FieldDailyTokenLimit,
+	FieldMonthlyRequestLimit,
+	FieldMonthlyTokenLimit,
+	FieldBudgetAmount,
+	FieldBudgetPeriod,
+	FieldBudgetAction,
+	FieldBudgetFallbackModel,
+	FieldBudgetUsed,
+	FieldBudgetPeriodStart,
+	FieldBudgetExceededAt,
+	FieldPreviousKey,
+	FieldPreviousKeyExpiresAt,
 }
 
 // ValidColumn reports if the column name is valid (part of the table columns).
@@ -148,14 +235,46 @@ var (
 	KeyValidator func(string) error
 	// NameValidator is a validator for the "name" field. It is called by the builders before save.
 	NameValidator func(string) error
+	// DefaultDescription holds the default value on creation for the "description" field.
+	DefaultDescription string
+	// DescriptionValidator is a validator for the "description" field. It is called by the builders before save.
+	DescriptionValidator func(string) error
 	// DefaultStatus holds the default value on creation for the "status" field.
 	DefaultStatus string
 	// StatusValidator is a validator for the "status" field. It is called by the builders before save.
 	StatusValidator func(string) error
+	// DefaultClientRestriction holds the default value on creation for the "client_restriction" field.
+	DefaultClientRestriction string
+	// ClientRestrictionValidator is a validator for the "client_restriction" field. It is called by the builders before save.
+	ClientRestrictionValidator func(string) error
+	// DefaultDegradedFallbackModel holds the default value on creation for the "degraded_fallback_model" field.
+	DefaultDegradedFallbackModel string
+	// DegradedFallbackModelValidator is a validator for the "degraded_fallback_model" field. It is called by the builders before save.
+	DegradedFallbackModelValidator func(string) error
+	// DefaultSystemPrompt holds the default value on creation for the "system_prompt" field.
+	DefaultSystemPrompt string
+	// DefaultSystemPromptMode holds the default value on creation for the "system_prompt_mode" field.
+	DefaultSystemPromptMode string
+	// SystemPromptModeValidator is a validator for the "system_prompt_mode" field. It is called by the builders before save.
+	SystemPromptModeValidator func(string) error
+	// DefaultModerationMode holds the default value on creation for the "moderation_mode" field.
+	DefaultModerationMode string
+	// ModerationModeValidator is a validator for the "moderation_mode" field. It is called by the builders before save.
+	ModerationModeValidator func(string) error
+	// DefaultLogContent holds the default value on creation for the "log_content" field.
+	DefaultLogContent bool
+	// DefaultSimulate holds the default value on creation for the "simulate" field.
+	DefaultSimulate bool
 	// DefaultQuota holds the default value on creation for the "quota" field.
 	DefaultQuota float64
 	// DefaultQuotaUsed holds the default value on creation for the "quota_used" field.
 	DefaultQuotaUsed float64
+	// DefaultImageQuota holds the default value on creation for the "image_quota" field.
+	DefaultImageQuota int
+	// DefaultImageQuotaUsed holds the default value on creation for the "image_quota_used" field.
+	DefaultImageQuotaUsed int
+	// DefaultSuppressReasoning holds the default value on creation for the "suppress_reasoning" field.
+	DefaultSuppressReasoning bool
 	// DefaultRateLimit5h holds the default value on creation for the "rate_limit_5h" field.
 	DefaultRateLimit5h float64
 	// DefaultRateLimit1d holds the default value on creation for the "rate_limit_1d" field.
@@ -168,6 +287,36 @@ var (
 	DefaultUsage1d float64
 	// DefaultUsage7d holds the default value on creation for the "usage_7d" field.
 	DefaultUsage7d float64
+	// DefaultRpmLimit holds the default value on creation for the "rpm_limit" field.
+	DefaultRpmLimit int
+	// DefaultTpmLimit holds the default value on creation for the "tpm_limit" field.
+	DefaultTpmLimit int
+	// DefaultDailyRequestLimit holds the default value on creation for the "daily_request_limit" field.
+	DefaultDailyRequestLimit int
+	// DefaultDailyTokenLimit holds the default value on creation for the "daily_token_limit" field.
+	DefaultDailyTokenLimit int64
+	// DefaultMonthlyRequestLimit holds the default value on creation for the "monthly_request_limit" field.
+	DefaultMonthlyRequestLimit int
+	// DefaultMonthlyTokenLimit holds the default value on creation for the "monthly_token_limit" field.
+	DefaultMonthlyTokenLimit int64
+	// DefaultBudgetAmount holds the default value on creation for the "budget_amount" field.
+	DefaultBudgetAmount float64
+	// DefaultBudgetPeriod holds the default value on creation for the "budget_period" field.
+	DefaultBudgetPeriod string
+	// BudgetPeriodValidator is a validator for the "budget_period" field. It is called by the builders before save.
+	BudgetPeriodValidator func(string) error
+	// DefaultBudgetAction holds the default value on creation for the "budget_action" field.
+	DefaultBudgetAction string
+	// BudgetActionValidator is a validator for the "budget_action" field. It is called by the builders before save.
+	BudgetActionValidator func(string) error
+	// DefaultBudgetFallbackModel holds the default value on creation for the "budget_fallback_model" field.
+	DefaultBudgetFallbackModel string
+	// BudgetFallbackModelValidator is a validator for the "budget_fallback_model" field. It is called by the builders before save.
+	BudgetFallbackModelValidator func(string) error
+	// DefaultBudgetUsed holds the default value on creation for the "budget_used" field.
+	DefaultBudgetUsed float64
+	// PreviousKeyValidator is a validator for the "previous_key" field. It is called by the builders before save.
+	PreviousKeyValidator func(string) error
 )
 
 // OrderOption defines the ordering options for the APIKey queries.
@@ -208,6 +357,11 @@ func ByName(opts ...sql.OrderTermOption) OrderOption {
 	return sql.OrderByField(FieldName, opts...).ToFunc()
 }
 
+// ByDescription orders the results by the description field.
+func ByDescription(opts ...sql.OrderTermOption) OrderOption {
+	return sql.OrderByField(FieldDescription, opts...).ToFunc()
+}
+
 // ByGroupID orders the results by the group_id field.
 func ByGroupID(opts ...sql.OrderTermOption) OrderOption {
 	return sql.OrderByField(FieldGroupID, opts...).ToFunc()
@@ -223,6 +377,41 @@ func ByLastUsedAt(opts ...sql.OrderTermOption) OrderOption {
 	return sql.OrderByField(FieldLastUsedAt, opts...).ToFunc()
 }
 
+// ByClientRestriction orders the results by the client_restriction field.
+func ByClientRestriction(opts ...sql.OrderTermOption) OrderOption {
+	return sql.OrderByField(FieldClientRestriction, opts...).ToFunc()
+}
+
+// ByDegradedFallbackModel orders the results by the degraded_fallback_model field.
+func ByDegradedFallbackModel(opts ...sql.OrderTermOption) OrderOption {
+	return sql.OrderByField(FieldDegradedFallbackModel, opts...).ToFunc()
+}
+
+// BySystemPrompt orders the results by the system_prompt field.
+func BySystemPrompt(opts ...sql.OrderTermOption) OrderOption {
+	return sql.OrderByField(FieldSystemPrompt, opts...).ToFunc()
+}
+
+// BySystemPromptMode orders the results by the system_prompt_mode field.
+func BySystemPromptMode(opts ...sql.OrderTermOption) OrderOption {
+	return sql.OrderByField(FieldSystemPromptMode, opts...).ToFunc()
+}
+
+// ByModerationMode orders the results by the moderation_mode field.
+func ByModerationMode(opts ...sql.OrderTermOption) OrderOption {
+	return sql.OrderByField(FieldModerationMode, opts...).ToFunc()
+}
+
+// ByLogContent orders the results by the log_content field.
+func ByLogContent(opts ...sql.OrderTermOption) OrderOption {
+	return sql.OrderByField(FieldLogContent, opts...).ToFunc()
+}
+
+// BySimulate orders the results by the simulate field.
+func BySimulate(opts ...sql.OrderTermOption) OrderOption {
+	return sql.OrderByField(FieldSimulate, opts...).ToFunc()
+}
+
 // ByQuota orders the results by the quota field.
 func ByQuota(opts ...sql.OrderTermOption) OrderOption {
 	return sql.OrderByField(FieldQuota, opts...).ToFunc()
@@ -233,6 +422,21 @@ func ByQuotaUsed(opts ...sql.OrderTermOption) OrderOption {
 	return sql.OrderByField(FieldQuotaUsed, opts...).ToFunc()
 }
 
+// ByImageQuota orders the results by the image_quota field.
+func ByImageQuota(opts ...sql.OrderTermOption) OrderOption {
+	return sql.OrderByField(FieldImageQuota, opts...).ToFunc()
+}
+
+// ByImageQuotaUsed orders the results by the image_quota_used field.
+func ByImageQuotaUsed(opts ...sql.OrderTermOption) OrderOption {
+	return sql.OrderByField(FieldImageQuotaUsed, opts...).ToFunc()
+}
+
+// BySuppressReasoning orders the results by the suppress_reasoning field.
+func BySuppressReasoning(opts ...sql.OrderTermOption) OrderOption {
+	return sql.OrderByField(FieldSuppressReasoning, opts...).ToFunc()
+}
+
 // ByExpiresAt orders the results by the expires_at field.
 func ByExpiresAt(opts ...sql.OrderTermOption) OrderOption {
 	return sql.OrderByField(FieldExpiresAt, opts...).ToFunc()
@@ -283,6 +487,81 @@ func ByWindow7dStart(opts ...sql.OrderTermOption) OrderOption {
 	return sql.OrderByField(FieldWindow7dStart, opts...).ToFunc()
 }
 
+// ByRpmLimit orders the results by the rpm_limit field.
+func ByRpmLimit(opts ...sql.OrderTermOption) OrderOption {
+	return sql.OrderByField(FieldRpmLimit, opts...).ToFunc()
+}
+
+// ByTpmLimit orders the results by the tpm_limit field.
+func ByTpmLimit(opts ...sql.OrderTermOption) OrderOption {
+	return sql.OrderByField(FieldTpmLimit, opts...).ToFunc()
+}
+
+// ByDailyRequestLimit orders the results by the daily_request_limit field.
+func ByDailyRequestLimit(opts ...sql.OrderTermOption) OrderOption {
+	return sql.OrderByField(FieldDailyRequestLimit, opts...).ToFunc()
+}
+
+// ByDailyTokenLimit orders the results by the daily_token_limit field.
+func ByDailyTokenLimit(opts ...sql.OrderTermOption) OrderOption {
+	return sql.OrderByField(FieldDailyTokenLimit, opts...).ToFunc()
+}
+
+// ByMonthlyRequestLimit orders the results by the monthly_request_limit field.
+func ByMonthlyRequestLimit(opts ...sql.OrderTermOption) OrderOption {
+	return sql.OrderByField(FieldMonthlyRequestLimit, opts...).ToFunc()
+}
+
+// ByMonthlyTokenLimit orders the results by the monthly_token_limit field.
+func ByMonthlyTokenLimit(opts ...sql.OrderTermOption) OrderOption {
+	return sql.OrderByField(FieldMonthlyTokenLimit, opts...).ToFunc()
+}
+
+// ByBudgetAmount orders the results by the budget_amount field.
+func ByBudgetAmount(opts ...sql.OrderTermOption) OrderOption {
+	return sql.OrderByField(FieldBudgetAmount, opts...).ToFunc()
+}
+
+// ByBudgetPeriod orders the results by the budget_period field.
+func ByBudgetPeriod(opts ...sql.OrderTermOption) OrderOption {
+	return sql.OrderByField(FieldBudgetPeriod, opts...).ToFunc()
+}
+
+// ByBudgetAction orders the results by the budget_action field.
+func ByBudgetAction(opts ...sql.OrderTermOption) OrderOption {
+	return sql.OrderByField(FieldBudgetAction, opts...).ToFunc()
+}
+
+// ByBudgetFallbackModel orders the results by the budget_fallback_model field.
+func ByBudgetFallbackModel(opts ...sql.OrderTermOption) OrderOption {
+	return sql.OrderByField(FieldBudgetFallbackModel, opts...).ToFunc()
+}
+
+// ByBudgetUsed orders the results by the budget_used field.
+func ByBudgetUsed(opts ...sql.OrderTermOption) OrderOption {
+	return sql.OrderByField(FieldBudgetUsed, opts...).ToFunc()
+}
+
+// ByBudgetPeriodStart orders the results by the budget_period_start field.
+func ByBudgetPeriodStart(opts ...sql.OrderTermOption) OrderOption {
+	return sql.OrderByField(FieldBudgetPeriodStart, opts...).ToFunc()
+}
+
+// ByBudgetExceededAt orders the results by the budget_exceeded_at field.
+func ByBudgetExceededAt(opts ...sql.OrderTermOption) OrderOption {
+	return sql.OrderByField(FieldBudgetExceededAt, opts...).ToFunc()
+}
+
+// ByPreviousKey orders the results by the previous_key field.
+func ByPreviousKey(opts ...sql.OrderTermOption) OrderOption {
+	return sql.OrderByField(FieldPreviousKey, opts...).ToFunc()
+}
+
+// ByPreviousKeyExpiresAt orders the results by the previous_key_expires_at field.
+func ByPreviousKeyExpiresAt(opts ...sql.OrderTermOption) OrderOption {
+	return sql.OrderByField(FieldPreviousKeyExpiresAt, opts...).ToFunc()
+}
+
 // ByUserField orders the results by user field.
 func ByUserField(field string, opts ...sql.OrderTermOption) OrderOption {
 	return func(s *sql.Selector) {
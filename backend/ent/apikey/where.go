@@ -85,6 +85,11 @@ func Name(v string) predicate.APIKey {
 	return predicate.APIKey(sql.FieldEQ(FieldName, v))
 }
 
+// Description applies equality check predicate on the "description" field. It's identical to DescriptionEQ.
+func Description(v string) predicate.APIKey {
+	return predicate.APIKey(sql.FieldEQ(FieldDescription, v))
+}
+
 // GroupID applies equality check predicate on the "group_id" field. It's identical to GroupIDEQ.
 func GroupID(v int64) predicate.APIKey {
 	return predicate.APIKey(sql.FieldEQ(FieldGroupID, v))
@@ -100,6 +105,41 @@ func LastUsedAt(v time.Time) predicate.APIKey {
 	return predicate.APIKey(sql.FieldEQ(FieldLastUsedAt, v))
 }
 
+// ClientRestriction applies equality check predicate on the "client_restriction" field. It's identical to ClientRestrictionEQ.
+func ClientRestriction(v string) predicate.APIKey {
+	return predicate.APIKey(sql.FieldEQ(FieldClientRestriction, v))
+}
+
+// DegradedFallbackModel applies equality check predicate on the "degraded_fallback_model" field. It's identical to DegradedFallbackModelEQ.
+func DegradedFallbackModel(v string) predicate.APIKey {
+	return predicate.APIKey(sql.FieldEQ(FieldDegradedFallbackModel, v))
+}
+
+// SystemPrompt applies equality check predicate on the "system_prompt" field. It's identical to SystemPromptEQ.
+func SystemPrompt(v string) predicate.APIKey {
+	return predicate.APIKey(sql.FieldEQ(FieldSystemPrompt, v))
+}
+
+// SystemPromptMode applies equality check predicate on the "system_prompt_mode" field. It's identical to SystemPromptModeEQ.
+func SystemPromptMode(v string) predicate.APIKey {
+	return predicate.APIKey(sql.FieldEQ(FieldSystemPromptMode, v))
+}
+
+// ModerationMode applies equality check predicate on the "moderation_mode" field. It's identical to ModerationModeEQ.
+func ModerationMode(v string) predicate.APIKey {
+	return predicate.APIKey(sql.FieldEQ(FieldModerationMode, v))
+}
+
+// LogContent applies equality check predicate on the "log_content" field. It's identical to LogContentEQ.
+func LogContent(v bool) predicate.APIKey {
+	return predicate.APIKey(sql.FieldEQ(FieldLogContent, v))
+}
+
+// Simulate applies equality check predicate on the "simulate" field. It's identical to SimulateEQ.
+func Simulate(v bool) predicate.APIKey {
+	return predicate.APIKey(sql.FieldEQ(FieldSimulate, v))
+}
+
 // Quota applies equality check predicate on the "quota" field. It's identical to QuotaEQ.
 func Quota(v float64) predicate.APIKey {
 	return predicate.APIKey(sql.FieldEQ(FieldQuota, v))
@@ -110,6 +150,21 @@ func QuotaUsed(v float64) predicate.APIKey {
 	return predicate.APIKey(sql.FieldEQ(FieldQuotaUsed, v))
 }
 
+// ImageQuota applies equality check predicate on the "image_quota" field. It's identical to ImageQuotaEQ.
+func ImageQuota(v int) predicate.APIKey {
+	return predicate.APIKey(sql.FieldEQ(FieldImageQuota, v))
+}
+
+// ImageQuotaUsed applies equality check predicate on the "image_quota_used" field. It's identical to ImageQuotaUsedEQ.
+func ImageQuotaUsed(v int) predicate.APIKey {
+	return predicate.APIKey(sql.FieldEQ(FieldImageQuotaUsed, v))
+}
+
+// SuppressReasoning applies equality check predicate on the "suppress_reasoning" field. It's identical to SuppressReasoningEQ.
+func SuppressReasoning(v bool) predicate.APIKey {
+	return predicate.APIKey(sql.FieldEQ(FieldSuppressReasoning, v))
+}
+
 // ExpiresAt applies equality check predicate on the "expires_at" field. It's identical to ExpiresAtEQ.
 func ExpiresAt(v time.Time) predicate.APIKey {
 	return predicate.APIKey(sql.FieldEQ(FieldExpiresAt, v))
@@ -160,6 +215,81 @@ func Window7dStart(v time.Time) predicate.APIKey {
 	return predicate.APIKey(sql.FieldEQ(FieldWindow7dStart, v))
 }
 
+// RpmLimit applies equality check predicate on the "rpm_limit" field. It's identical to RpmLimitEQ.
+func RpmLimit(v int) predicate.APIKey {
+	return predicate.APIKey(sql.FieldEQ(FieldRpmLimit, v))
+}
+
+// TpmLimit applies equality check predicate on the "tpm_limit" field. It's identical to TpmLimitEQ.
+func TpmLimit(v int) predicate.APIKey {
+	return predicate.APIKey(sql.FieldEQ(FieldTpmLimit, v))
+}
+
+// DailyRequestLimit applies equality check predicate on the "daily_request_limit" field. It's identical to DailyRequestLimitEQ.
+func DailyRequestLimit(v int) predicate.APIKey {
+	return predicate.APIKey(sql.FieldEQ(FieldDailyRequestLimit, v))
+}
+
+// DailyTokenLimit applies equality check predicate on the "daily_token_limit" field. It's identical to DailyTokenLimitEQ.
+func DailyTokenLimit(v int64) predicate.APIKey {
+	return predicate.APIKey(sql.FieldEQ(FieldDailyTokenLimit, v))
+}
+
+// MonthlyRequestLimit applies equality check predicate on the "monthly_request_limit" field. It's identical to MonthlyRequestLimitEQ.
+func MonthlyRequestLimit(v int) predicate.APIKey {
+	return predicate.APIKey(sql.FieldEQ(FieldMonthlyRequestLimit, v))
+}
+
+// MonthlyTokenLimit applies equality check predicate on the "monthly_token_limit" field. It's identical to MonthlyTokenLimitEQ.
+func MonthlyTokenLimit(v int64) predicate.APIKey {
+	return predicate.APIKey(sql.FieldEQ(FieldMonthlyTokenLimit, v))
+}
+
+// BudgetAmount applies equality check predicate on the "budget_amount" field. It's identical to BudgetAmountEQ.
+func BudgetAmount(v float64) predicate.APIKey {
+	return predicate.APIKey(sql.FieldEQ(FieldBudgetAmount, v))
+}
+
+// BudgetPeriod applies equality check predicate on the "budget_period" field. It's identical to BudgetPeriodEQ.
+func BudgetPeriod(v string) predicate.APIKey {
+	return predicate.APIKey(sql.FieldEQ(FieldBudgetPeriod, v))
+}
+
+// BudgetAction applies equality check predicate on the "budget_action" field. It's identical to BudgetActionEQ.
+func BudgetAction(v string) predicate.APIKey {
+	return predicate.APIKey(sql.FieldEQ(FieldBudgetAction, v))
+}
+
+// BudgetFallbackModel applies equality check predicate on the "budget_fallback_model" field. It's identical to BudgetFallbackModelEQ.
+func BudgetFallbackModel(v string) predicate.APIKey {
+	return predicate.APIKey(sql.FieldEQ(FieldBudgetFallbackModel, v))
+}
+
+// BudgetUsed applies equality check predicate on the "budget_used" field. It's identical to BudgetUsedEQ.
+func BudgetUsed(v float64) predicate.APIKey {
+	return predicate.APIKey(sql.FieldEQ(FieldBudgetUsed, v))
+}
+
+// BudgetPeriodStart applies equality check predicate on the "budget_period_start" field. It's identical to BudgetPeriodStartEQ.
+func BudgetPeriodStart(v time.Time) predicate.APIKey {
+	return predicate.APIKey(sql.FieldEQ(FieldBudgetPeriodStart, v))
+}
+
+// BudgetExceededAt applies equality check predicate on the "budget_exceeded_at" field. It's identical to BudgetExceededAtEQ.
+func BudgetExceededAt(v time.Time) predicate.APIKey {
+	return predicate.APIKey(sql.FieldEQ(FieldBudgetExceededAt, v))
+}
+
+// PreviousKey applies equality check predicate on the "previous_key" field. It's identical to PreviousKeyEQ.
+func PreviousKey(v string) predicate.APIKey {
+	return predicate.APIKey(sql.FieldEQ(FieldPreviousKey, v))
+}
+
+// PreviousKeyExpiresAt applies equality check predicate on the "previous_key_expires_at" field. It's identical to PreviousKeyExpiresAtEQ.
+func PreviousKeyExpiresAt(v time.Time) predicate.APIKey {
+	return predicate.APIKey(sql.FieldEQ(FieldPreviousKeyExpiresAt, v))
+}
+
 // CreatedAtEQ applies the EQ predicate on the "created_at" field.
 func CreatedAtEQ(v time.Time) predicate.APIKey {
 	return predicate.APIKey(sql.FieldEQ(FieldCreatedAt, v))
@@ -440,6 +570,71 @@ func NameContainsFold(v string) predicate.APIKey {
 	return predicate.APIKey(sql.FieldContainsFold(FieldName, v))
 }
 
+// DescriptionEQ applies the EQ predicate on the "description" field.
+func DescriptionEQ(v string) predicate.APIKey {
+	return predicate.APIKey(sql.FieldEQ(FieldDescription, v))
+}
+
+// DescriptionNEQ applies the NEQ predicate on the "description" field.
+func DescriptionNEQ(v string) predicate.APIKey {
+	return predicate.APIKey(sql.FieldNEQ(FieldDescription, v))
+}
+
+// DescriptionIn applies the In predicate on the "description" field.
+func DescriptionIn(vs ...string) predicate.APIKey {
+	return predicate.APIKey(sql.FieldIn(FieldDescription, vs...))
+}
+
+// DescriptionNotIn applies the NotIn predicate on the "description" field.
+func DescriptionNotIn(vs ...string) predicate.APIKey {
+	return predicate.APIKey(sql.FieldNotIn(FieldDescription, vs...))
+}
+
+// DescriptionGT applies the GT predicate on the "description" field.
+func DescriptionGT(v string) predicate.APIKey {
+	return predicate.APIKey(sql.FieldGT(FieldDescription, v))
+}
+
+// DescriptionGTE applies the GTE predicate on the "description" field.
+func DescriptionGTE(v string) predicate.APIKey {
+	return predicate.APIKey(sql.FieldGTE(FieldDescription, v))
+}
+
+// DescriptionLT applies the LT predicate on the "description" field.
+func DescriptionLT(v string) predicate.APIKey {
+	return predicate.APIKey(sql.FieldLT(FieldDescription, v))
+}
+
+// DescriptionLTE applies the LTE predicate on the "description" field.
+func DescriptionLTE(v string) predicate.APIKey {
+	return predicate.APIKey(sql.FieldLTE(FieldDescription, v))
+}
+
+// DescriptionContains applies the Contains predicate on the "description" field.
+func DescriptionContains(v string) predicate.APIKey {
+	return predicate.APIKey(sql.FieldContains(FieldDescription, v))
+}
+
+// DescriptionHasPrefix applies the HasPrefix predicate on the "description" field.
+func DescriptionHasPrefix(v string) predicate.APIKey {
+	return predicate.APIKey(sql.FieldHasPrefix(FieldDescription, v))
+}
+
+// DescriptionHasSuffix applies the HasSuffix predicate on the "description" field.
+func DescriptionHasSuffix(v string) predicate.APIKey {
+	return predicate.APIKey(sql.FieldHasSuffix(FieldDescription, v))
+}
+
+// DescriptionEqualFold applies the EqualFold predicate on the "description" field.
+func DescriptionEqualFold(v string) predicate.APIKey {
+	return predicate.APIKey(sql.FieldEqualFold(FieldDescription, v))
+}
+
+// DescriptionContainsFold applies the ContainsFold predicate on the "description" field.
+func DescriptionContainsFold(v string) predicate.APIKey {
+	return predicate.APIKey(sql.FieldContainsFold(FieldDescription, v))
+}
+
 // GroupIDEQ applies the EQ predicate on the "group_id" field.
 func GroupIDEQ(v int64) predicate.APIKey {
 	return predicate.APIKey(sql.FieldEQ(FieldGroupID, v))
@@ -605,524 +800,1729 @@ func IPBlacklistNotNil() predicate.APIKey {
 	return predicate.APIKey(sql.FieldNotNull(FieldIPBlacklist))
 }
 
-// QuotaEQ applies the EQ predicate on the "quota" field.
-func QuotaEQ(v float64) predicate.APIKey {
-	return predicate.APIKey(sql.FieldEQ(FieldQuota, v))
+// ScopesIsNil applies the IsNil predicate on the "scopes" field.
+func ScopesIsNil() predicate.APIKey {
+	return predicate.APIKey(sql.FieldIsNull(FieldScopes))
 }
 
-// QuotaNEQ applies the NEQ predicate on the "quota" field.
-func QuotaNEQ(v float64) predicate.APIKey {
-	return predicate.APIKey(sql.FieldNEQ(FieldQuota, v))
+// ScopesNotNil applies the NotNil predicate on the "scopes" field.
+func ScopesNotNil() predicate.APIKey {
+	return predicate.APIKey(sql.FieldNotNull(FieldScopes))
 }
 
-// QuotaIn applies the In predicate on the "quota" field.
-func QuotaIn(vs ...float64) predicate.APIKey {
-	return predicate.APIKey(sql.FieldIn(FieldQuota, vs...))
+// AllowedModelsIsNil applies the IsNil predicate on the "allowed_models" field.
+func AllowedModelsIsNil() predicate.APIKey {
+	return predicate.APIKey(sql.FieldIsNull(FieldAllowedModels))
 }
 
-// QuotaNotIn applies the NotIn predicate on the "quota" field.
-func QuotaNotIn(vs ...float64) predicate.APIKey {
-	return predicate.APIKey(sql.FieldNotIn(FieldQuota, vs...))
+// AllowedModelsNotNil applies the NotNil predicate on the "allowed_models" field.
+func AllowedModelsNotNil() predicate.APIKey {
+	return predicate.APIKey(sql.FieldNotNull(FieldAllowedModels))
 }
 
-// QuotaGT applies the GT predicate on the "quota" field.
-func QuotaGT(v float64) predicate.APIKey {
-	return predicate.APIKey(sql.FieldGT(FieldQuota, v))
+// ClientRestrictionEQ applies the EQ predicate on the "client_restriction" field.
+func ClientRestrictionEQ(v string) predicate.APIKey {
+	return predicate.APIKey(sql.FieldEQ(FieldClientRestriction, v))
 }
 
-// QuotaGTE applies the GTE predicate on the "quota" field.
-func QuotaGTE(v float64) predicate.APIKey {
-	return predicate.APIKey(sql.FieldGTE(FieldQuota, v))
+// ClientRestrictionNEQ applies the NEQ predicate on the "client_restriction" field.
+func ClientRestrictionNEQ(v string) predicate.APIKey {
+	return predicate.APIKey(sql.FieldNEQ(FieldClientRestriction, v))
 }
 
-// QuotaLT applies the LT predicate on the "quota" field.
-func QuotaLT(v float64) predicate.APIKey {
-	return predicate.APIKey(sql.FieldLT(FieldQuota, v))
+// ClientRestrictionIn applies the In predicate on the "client_restriction" field.
+func ClientRestrictionIn(vs ...string) predicate.APIKey {
+	return predicate.APIKey(sql.FieldIn(FieldClientRestriction, vs...))
 }
 
-// QuotaLTE applies the LTE predicate on the "quota" field.
-func QuotaLTE(v float64) predicate.APIKey {
-	return predicate.APIKey(sql.FieldLTE(FieldQuota, v))
+// ClientRestrictionNotIn applies the NotIn predicate on the "client_restriction" field.
+func ClientRestrictionNotIn(vs ...string) predicate.APIKey {
+	return predicate.APIKey(sql.FieldNotIn(FieldClientRestriction, vs...))
 }
 
-// QuotaUsedEQ applies the EQ predicate on the "quota_used" field.
-func QuotaUsedEQ(v float64) predicate.APIKey {
-	return predicate.APIKey(sql.FieldEQ(FieldQuotaUsed, v))
+// ClientRestrictionGT applies the GT predicate on the "client_restriction" field.
+func ClientRestrictionGT(v string) predicate.APIKey {
+	return predicate.APIKey(sql.FieldGT(FieldClientRestriction, v))
 }
 
-// QuotaUsedNEQ applies the NEQ predicate on the "quota_used" field.
-func QuotaUsedNEQ(v float64) predicate.APIKey {
-	return predicate.APIKey(sql.FieldNEQ(FieldQuotaUsed, v))
+// ClientRestrictionGTE applies the GTE predicate on the "client_restriction" field.
+func ClientRestrictionGTE(v string) predicate.APIKey {
+	return predicate.APIKey(sql.FieldGTE(FieldClientRestriction, v))
 }
 
-// QuotaUsedIn applies the In predicate on the "quota_used" field.
-func QuotaUsedIn(vs ...float64) predicate.APIKey {
-	return predicate.APIKey(sql.FieldIn(FieldQuotaUsed, vs...))
+// ClientRestrictionLT applies the LT predicate on the "client_restriction" field.
+func ClientRestrictionLT(v string) predicate.APIKey {
+	return predicate.APIKey(sql.FieldLT(FieldClientRestriction, v))
 }
 
-// QuotaUsedNotIn applies the NotIn predicate on the "quota_used" field.
-func QuotaUsedNotIn(vs ...float64) predicate.APIKey {
-	return predicate.APIKey(sql.FieldNotIn(FieldQuotaUsed, vs...))
+// ClientRestrictionLTE applies the LTE predicate on the "client_restriction" field.
+func ClientRestrictionLTE(v string) predicate.APIKey {
+	return predicate.APIKey(sql.FieldLTE(FieldClientRestriction, v))
 }
 
-// QuotaUsedGT applies the GT predicate on the "quota_used" field.
-func QuotaUsedGT(v float64) predicate.APIKey {
-	return predicate.APIKey(sql.FieldGT(FieldQuotaUsed, v))
+// ClientRestrictionContains applies the Contains predicate on the "client_restriction" field.
+func ClientRestrictionContains(v string) predicate.APIKey {
+	return predicate.APIKey(sql.FieldContains(FieldClientRestriction, v))
 }
 
-// QuotaUsedGTE applies the GTE predicate on the "quota_used" field.
-func QuotaUsedGTE(v float64) predicate.APIKey {
-	return predicate.APIKey(sql.FieldGTE(FieldQuotaUsed, v))
+// ClientRestrictionHasPrefix applies the HasPrefix predicate on the "client_restriction" field.
+func ClientRestrictionHasPrefix(v string) predicate.APIKey {
+	return predicate.APIKey(sql.FieldHasPrefix(FieldClientRestriction, v))
 }
 
-// QuotaUsedLT applies the LT predicate on the "quota_used" field.
-func QuotaUsedLT(v float64) predicate.APIKey {
-	return predicate.APIKey(sql.FieldLT(FieldQuotaUsed, v))
+// ClientRestrictionHasSuffix applies the HasSuffix predicate on the "client_restriction" field.
+func ClientRestrictionHasSuffix(v string) predicate.APIKey {
+	return predicate.APIKey(sql.FieldHasSuffix(FieldClientRestriction, v))
 }
 
-// QuotaUsedLTE applies the LTE predicate on the "quota_used" field.
-func QuotaUsedLTE(v float64) predicate.APIKey {
-	return predicate.APIKey(sql.FieldLTE(FieldQuotaUsed, v))
+// ClientRestrictionEqualFold applies the EqualFold predicate on the "client_restriction" field.
+func ClientRestrictionEqualFold(v string) predicate.APIKey {
+	return predicate.APIKey(sql.FieldEqualFold(FieldClientRestriction, v))
 }
 
-// ExpiresAtEQ applies the EQ predicate on the "expires_at" field.
-func ExpiresAtEQ(v time.Time) predicate.APIKey {
-	return predicate.APIKey(sql.FieldEQ(FieldExpiresAt, v))
+// ClientRestrictionContainsFold applies the ContainsFold predicate on the "client_restriction" field.
+func ClientRestrictionContainsFold(v string) predicate.APIKey {
+	return predicate.APIKey(sql.FieldContainsFold(FieldClientRestriction, v))
 }
 
-// ExpiresAtNEQ applies the NEQ predicate on the "expires_at" field.
-func ExpiresAtNEQ(v time.Time) predicate.APIKey {
-	return predicate.APIKey(sql.FieldNEQ(FieldExpiresAt, v))
+// TagsIsNil applies the IsNil predicate on the "tags" field.
+func TagsIsNil() predicate.APIKey {
+	return predicate.APIKey(sql.FieldIsNull(FieldTags))
 }
 
-// ExpiresAtIn applies the In predicate on the "expires_at" field.
-func ExpiresAtIn(vs ...time.Time) predicate.APIKey {
-	return predicate.APIKey(sql.FieldIn(FieldExpiresAt, vs...))
+// TagsNotNil applies the NotNil predicate on the "tags" field.
+func TagsNotNil() predicate.APIKey {
+	return predicate.APIKey(sql.FieldNotNull(FieldTags))
 }
 
-// ExpiresAtNotIn applies the NotIn predicate on the "expires_at" field.
-func ExpiresAtNotIn(vs ...time.Time) predicate.APIKey {
-	return predicate.APIKey(sql.FieldNotIn(FieldExpiresAt, vs...))
+// DegradedFallbackModelEQ applies the EQ predicate on the "degraded_fallback_model" field.
+func DegradedFallbackModelEQ(v string) predicate.APIKey {
+	return predicate.APIKey(sql.FieldEQ(FieldDegradedFallbackModel, v))
 }
 
-// ExpiresAtGT applies the GT predicate on the "expires_at" field.
-func ExpiresAtGT(v time.Time) predicate.APIKey {
-	return predicate.APIKey(sql.FieldGT(FieldExpiresAt, v))
+// DegradedFallbackModelNEQ applies the NEQ predicate on the "degraded_fallback_model" field.
+func DegradedFallbackModelNEQ(v string) predicate.APIKey {
+	return predicate.APIKey(sql.FieldNEQ(FieldDegradedFallbackModel, v))
 }
 
-// ExpiresAtGTE applies the GTE predicate on the "expires_at" field.
-func ExpiresAtGTE(v time.Time) predicate.APIKey {
-	return predicate.APIKey(sql.FieldGTE(FieldExpiresAt, v))
+// DegradedFallbackModelIn applies the In predicate on the "degraded_fallback_model" field.
+func DegradedFallbackModelIn(vs ...string) predicate.APIKey {
+	return predicate.APIKey(sql.FieldIn(FieldDegradedFallbackModel, vs...))
 }
 
-// ExpiresAtLT applies the LT predicate on the "expires_at" field.
-func ExpiresAtLT(v time.Time) predicate.APIKey {
-	return predicate.APIKey(sql.FieldLT(FieldExpiresAt, v))
+// DegradedFallbackModelNotIn applies the NotIn predicate on the "degraded_fallback_model" field.
+func DegradedFallbackModelNotIn(vs ...string) predicate.APIKey {
+	return predicate.APIKey(sql.FieldNotIn(FieldDegradedFallbackModel, vs...))
 }
 
-// ExpiresAtLTE applies the LTE predicate on the "expires_at" field.
-func ExpiresAtLTE(v time.Time) predicate.APIKey {
-	return predicate.APIKey(sql.FieldLTE(FieldExpiresAt, v))
+// DegradedFallbackModelGT applies the GT predicate on the "degraded_fallback_model" field.
+func DegradedFallbackModelGT(v string) predicate.APIKey {
+	return predicate.APIKey(sql.FieldGT(FieldDegradedFallbackModel, v))
 }
 
-// ExpiresAtIsNil applies the IsNil predicate on the "expires_at" field.
-func ExpiresAtIsNil() predicate.APIKey {
-	return predicate.APIKey(sql.FieldIsNull(FieldExpiresAt))
+// DegradedFallbackModelGTE applies the GTE predicate on the "degraded_fallback_model" field.
+func DegradedFallbackModelGTE(v string) predicate.APIKey {
+	return predicate.APIKey(sql.FieldGTE(FieldDegradedFallbackModel, v))
 }
 
-// ExpiresAtNotNil applies the NotNil predicate on the "expires_at" field.
-func ExpiresAtNotNil() predicate.APIKey {
-	return predicate.APIKey(sql.FieldNotNull(FieldExpiresAt))
+// DegradedFallbackModelLT applies the LT predicate on the "degraded_fallback_model" field.
+func DegradedFallbackModelLT(v string) predicate.APIKey {
+	return predicate.APIKey(sql.FieldLT(FieldDegradedFallbackModel, v))
 }
 
-// RateLimit5hEQ applies the EQ predicate on the "rate_limit_5h" field.
-func RateLimit5hEQ(v float64) predicate.APIKey {
-	return predicate.APIKey(sql.FieldEQ(FieldRateLimit5h, v))
+// DegradedFallbackModelLTE applies the LTE predicate on the "degraded_fallback_model" field.
+func DegradedFallbackModelLTE(v string) predicate.APIKey {
+	return predicate.APIKey(sql.FieldLTE(FieldDegradedFallbackModel, v))
 }
 
-// RateLimit5hNEQ applies the NEQ predicate on the "rate_limit_5h" field.
-func RateLimit5hNEQ(v float64) predicate.APIKey {
-	return predicate.APIKey(sql.FieldNEQ(FieldRateLimit5h, v))
+// DegradedFallbackModelContains applies the Contains predicate on the "degraded_fallback_model" field.
+func DegradedFallbackModelContains(v string) predicate.APIKey {
+	return predicate.APIKey(sql.FieldContains(FieldDegradedFallbackModel, v))
 }
 
-// RateLimit5hIn applies the In predicate on the "rate_limit_5h" field.
-func RateLimit5hIn(vs ...float64) predicate.APIKey {
-	return predicate.APIKey(sql.FieldIn(FieldRateLimit5h, vs...))
+// DegradedFallbackModelHasPrefix applies the HasPrefix predicate on the "degraded_fallback_model" field.
+func DegradedFallbackModelHasPrefix(v string) predicate.APIKey {
+	return predicate.APIKey(sql.FieldHasPrefix(FieldDegradedFallbackModel, v))
 }
 
-// RateLimit5hNotIn applies the NotIn predicate on the "rate_limit_5h" field.
-func RateLimit5hNotIn(vs ...float64) predicate.APIKey {
-	return predicate.APIKey(sql.FieldNotIn(FieldRateLimit5h, vs...))
+// DegradedFallbackModelHasSuffix applies the HasSuffix predicate on the "degraded_fallback_model" field.
+func DegradedFallbackModelHasSuffix(v string) predicate.APIKey {
+	return predicate.APIKey(sql.FieldHasSuffix(FieldDegradedFallbackModel, v))
 }
 
-// RateLimit5hGT applies the GT predicate on the "rate_limit_5h" field.
-func RateLimit5hGT(v float64) predicate.APIKey {
-	return predicate.APIKey(sql.FieldGT(FieldRateLimit5h, v))
+// DegradedFallbackModelEqualFold applies the EqualFold predicate on the "degraded_fallback_model" field.
+func DegradedFallbackModelEqualFold(v string) predicate.APIKey {
+	return predicate.APIKey(sql.FieldEqualFold(FieldDegradedFallbackModel, v))
 }
 
-// RateLimit5hGTE applies the GTE predicate on the "rate_limit_5h" field.
-func RateLimit5hGTE(v float64) predicate.APIKey {
-	return predicate.APIKey(sql.FieldGTE(FieldRateLimit5h, v))
+// DegradedFallbackModelContainsFold applies the ContainsFold predicate on the "degraded_fallback_model" field.
+func DegradedFallbackModelContainsFold(v string) predicate.APIKey {
+	return predicate.APIKey(sql.FieldContainsFold(FieldDegradedFallbackModel, v))
 }
 
-// RateLimit5hLT applies the LT predicate on the "rate_limit_5h" field.
-func RateLimit5hLT(v float64) predicate.APIKey {
-	return predicate.APIKey(sql.FieldLT(FieldRateLimit5h, v))
+// SystemPromptEQ applies the EQ predicate on the "system_prompt" field.
+func SystemPromptEQ(v string) predicate.APIKey {
+	return predicate.APIKey(sql.FieldEQ(FieldSystemPrompt, v))
 }
 
-// RateLimit5hLTE applies the LTE predicate on the "rate_limit_5h" field.
-func RateLimit5hLTE(v float64) predicate.APIKey {
-	return predicate.APIKey(sql.FieldLTE(FieldRateLimit5h, v))
+// SystemPromptNEQ applies the NEQ predicate on the "system_prompt" field.
+func SystemPromptNEQ(v string) predicate.APIKey {
+	return predicate.APIKey(sql.FieldNEQ(FieldSystemPrompt, v))
 }
 
-// RateLimit1dEQ applies the EQ predicate on the "rate_limit_1d" field.
-func RateLimit1dEQ(v float64) predicate.APIKey {
-	return predicate.APIKey(sql.FieldEQ(FieldRateLimit1d, v))
+// SystemPromptIn applies the In predicate on the "system_prompt" field.
+func SystemPromptIn(vs ...string) predicate.APIKey {
+	return predicate.APIKey(sql.FieldIn(FieldSystemPrompt, vs...))
 }
 
-// RateLimit1dNEQ applies the NEQ predicate on the "rate_limit_1d" field.
-func RateLimit1dNEQ(v float64) predicate.APIKey {
-	return predicate.APIKey(sql.FieldNEQ(FieldRateLimit1d, v))
+// SystemPromptNotIn applies the NotIn predicate on the "system_prompt" field.
+func SystemPromptNotIn(vs ...string) predicate.APIKey {
+	return predicate.APIKey(sql.FieldNotIn(FieldSystemPrompt, vs...))
 }
 
-// RateLimit1dIn applies the In predicate on the "rate_limit_1d" field.
-func RateLimit1dIn(vs ...float64) predicate.APIKey {
-	return predicate.APIKey(sql.FieldIn(FieldRateLimit1d, vs...))
+// SystemPromptGT applies the GT predicate on the "system_prompt" field.
+func SystemPromptGT(v string) predicate.APIKey {
+	return predicate.APIKey(sql.FieldGT(FieldSystemPrompt, v))
 }
 
-// RateLimit1dNotIn applies the NotIn predicate on the "rate_limit_1d" field.
-func RateLimit1dNotIn(vs ...float64) predicate.APIKey {
-	return predicate.APIKey(sql.FieldNotIn(FieldRateLimit1d, vs...))
+// SystemPromptGTE applies the GTE predicate on the "system_prompt" field.
+func SystemPromptGTE(v string) predicate.APIKey {
+	return predicate.APIKey(sql.FieldGTE(FieldSystemPrompt, v))
 }
 
-// RateLimit1dGT applies the GT predicate on the "rate_limit_1d" field.
-func RateLimit1dGT(v float64) predicate.APIKey {
-	return predicate.APIKey(sql.FieldGT(FieldRateLimit1d, v))
+// SystemPromptLT applies the LT predicate on the "system_prompt" field.
+func SystemPromptLT(v string) predicate.APIKey {
+	return predicate.APIKey(sql.FieldLT(FieldSystemPrompt, v))
 }
 
-// RateLimit1dGTE applies the GTE predicate on the "rate_limit_1d" field.
-func RateLimit1dGTE(v float64) predicate.APIKey {
-	return predicate.APIKey(sql.FieldGTE(FieldRateLimit1d, v))
+// SystemPromptLTE applies the LTE predicate on the "system_prompt" field.
+func SystemPromptLTE(v string) predicate.APIKey {
+	return predicate.APIKey(sql.FieldLTE(FieldSystemPrompt, v))
 }
 
-// RateLimit1dLT applies the LT predicate on the "rate_limit_1d" field.
-func RateLimit1dLT(v float64) predicate.APIKey {
-	return predicate.APIKey(sql.FieldLT(FieldRateLimit1d, v))
+// SystemPromptContains applies the Contains predicate on the "system_prompt" field.
+func SystemPromptContains(v string) predicate.APIKey {
+	return predicate.APIKey(sql.FieldContains(FieldSystemPrompt, v))
 }
 
-// RateLimit1dLTE applies the LTE predicate on the "rate_limit_1d" field.
-func RateLimit1dLTE(v float64) predicate.APIKey {
-	return predicate.APIKey(sql.FieldLTE(FieldRateLimit1d, v))
+// SystemPromptHasPrefix applies the HasPrefix predicate on the "system_prompt" field.
+func SystemPromptHasPrefix(v string) predicate.APIKey {
+	return predicate.APIKey(sql.FieldHasPrefix(FieldSystemPrompt, v))
 }
 
-// RateLimit7dEQ applies the EQ predicate on the "rate_limit_7d" field.
-func RateLimit7dEQ(v float64) predicate.APIKey {
-	return predicate.APIKey(sql.FieldEQ(FieldRateLimit7d, v))
+// SystemPromptHasSuffix applies the HasSuffix predicate on the "system_prompt" field.
+func SystemPromptHasSuffix(v string) predicate.APIKey {
+	return predicate.APIKey(sql.FieldHasSuffix(FieldSystemPrompt, v))
 }
 
-// RateLimit7dNEQ applies the NEQ predicate on the "rate_limit_7d" field.
-func RateLimit7dNEQ(v float64) predicate.APIKey {
-	return predicate.APIKey(sql.FieldNEQ(FieldRateLimit7d, v))
+// SystemPromptEqualFold applies the EqualFold predicate on the "system_prompt" field.
+func SystemPromptEqualFold(v string) predicate.APIKey {
+	return predicate.APIKey(sql.FieldEqualFold(FieldSystemPrompt, v))
 }
 
-// RateLimit7dIn applies the In predicate on the "rate_limit_7d" field.
-func RateLimit7dIn(vs ...float64) predicate.APIKey {
-	return predicate.APIKey(sql.FieldIn(FieldRateLimit7d, vs...))
+// SystemPromptContainsFold applies the ContainsFold predicate on the "system_prompt" field.
+func SystemPromptContainsFold(v string) predicate.APIKey {
+	return predicate.APIKey(sql.FieldContainsFold(FieldSystemPrompt, v))
 }
 
-// RateLimit7dNotIn applies the NotIn predicate on the "rate_limit_7d" field.
-func RateLimit7dNotIn(vs ...float64) predicate.APIKey {
-	return predicate.APIKey(sql.FieldNotIn(FieldRateLimit7d, vs...))
+// SystemPromptModeEQ applies the EQ predicate on the "system_prompt_mode" field.
+func SystemPromptModeEQ(v string) predicate.APIKey {
+	return predicate.APIKey(sql.FieldEQ(FieldSystemPromptMode, v))
 }
 
-// RateLimit7dGT applies the GT predicate on the "rate_limit_7d" field.
-func RateLimit7dGT(v float64) predicate.APIKey {
-	return predicate.APIKey(sql.FieldGT(FieldRateLimit7d, v))
+// SystemPromptModeNEQ applies the NEQ predicate on the "system_prompt_mode" field.
+func SystemPromptModeNEQ(v string) predicate.APIKey {
+	return predicate.APIKey(sql.FieldNEQ(FieldSystemPromptMode, v))
 }
 
-// RateLimit7dGTE applies the GTE predicate on the "rate_limit_7d" field.
-func RateLimit7dGTE(v float64) predicate.APIKey {
-	return predicate.APIKey(sql.FieldGTE(FieldRateLimit7d, v))
+// SystemPromptModeIn applies the In predicate on the "system_prompt_mode" field.
+func SystemPromptModeIn(vs ...string) predicate.APIKey {
+	return predicate.APIKey(sql.FieldIn(FieldSystemPromptMode, vs...))
 }
 
-// RateLimit7dLT applies the LT predicate on the "rate_limit_7d" field.
-func RateLimit7dLT(v float64) predicate.APIKey {
-	return predicate.APIKey(sql.FieldLT(FieldRateLimit7d, v))
+// SystemPromptModeNotIn applies the NotIn predicate on the "system_prompt_mode" field.
+func SystemPromptModeNotIn(vs ...string) predicate.APIKey {
+	return predicate.APIKey(sql.FieldNotIn(FieldSystemPromptMode, vs...))
 }
 
-// RateLimit7dLTE applies the LTE predicate on the "rate_limit_7d" field.
-func RateLimit7dLTE(v float64) predicate.APIKey {
-	return predicate.APIKey(sql.FieldLTE(FieldRateLimit7d, v))
+// SystemPromptModeGT applies the GT predicate on the "system_prompt_mode" field.
+func SystemPromptModeGT(v string) predicate.APIKey {
+	return predicate.APIKey(sql.FieldGT(FieldSystemPromptMode, v))
 }
 
-// Usage5hEQ applies the EQ predicate on the "usage_5h" field.
-func Usage5hEQ(v float64) predicate.APIKey {
-	return predicate.APIKey(sql.FieldEQ(FieldUsage5h, v))
+// SystemPromptModeGTE applies the GTE predicate on the "system_prompt_mode" field.
+func SystemPromptModeGTE(v string) predicate.APIKey {
+	return predicate.APIKey(sql.FieldGTE(FieldSystemPromptMode, v))
 }
 
-// Usage5hNEQ applies the NEQ predicate on the "usage_5h" field.
-func Usage5hNEQ(v float64) predicate.APIKey {
-	return predicate.APIKey(sql.FieldNEQ(FieldUsage5h, v))
+// SystemPromptModeLT applies the LT predicate on the "system_prompt_mode" field.
+func SystemPromptModeLT(v string) predicate.APIKey {
+	return predicate.APIKey(sql.FieldLT(FieldSystemPromptMode, v))
 }
 
-// Usage5hIn applies the In predicate on the "usage_5h" field.
-func Usage5hIn(vs ...float64) predicate.APIKey {
-	return predicate.APIKey(sql.FieldIn(FieldUsage5h, vs...))
+// SystemPromptModeLTE applies the LTE predicate on the "system_prompt_mode" field.
+func SystemPromptModeLTE(v string) predicate.APIKey {
+	return predicate.APIKey(sql.FieldLTE(FieldSystemPromptMode, v))
 }
 
-// Usage5hNotIn applies the NotIn predicate on the "usage_5h" field.
-func Usage5hNotIn(vs ...float64) predicate.APIKey {
-	return predicate.APIKey(sql.FieldNotIn(FieldUsage5h, vs...))
+// SystemPromptModeContains applies the Contains predicate on the "system_prompt_mode" field.
+func SystemPromptModeContains(v string) predicate.APIKey {
+	return predicate.APIKey(sql.FieldContains(FieldSystemPromptMode, v))
 }
 
-// Usage5hGT applies the GT predicate on the "usage_5h" field.
-func Usage5hGT(v float64) predicate.APIKey {
-	return predicate.APIKey(sql.FieldGT(FieldUsage5h, v))
+// SystemPromptModeHasPrefix applies the HasPrefix predicate on the "system_prompt_mode" field.
+func SystemPromptModeHasPrefix(v string) predicate.APIKey {
+	return predicate.APIKey(sql.FieldHasPrefix(FieldSystemPromptMode, v))
 }
 
-// Usage5hGTE applies the GTE predicate on the "usage_5h" field.
-func Usage5hGTE(v float64) predicate.APIKey {
-	return predicate.APIKey(sql.FieldGTE(FieldUsage5h, v))
+// SystemPromptModeHasSuffix applies the HasSuffix predicate on the "system_prompt_mode" field.
+func SystemPromptModeHasSuffix(v string) predicate.APIKey {
+	return predicate.APIKey(sql.FieldHasSuffix(FieldSystemPromptMode, v))
 }
 
-// Usage5hLT applies the LT predicate on the "usage_5h" field.
-func Usage5hLT(v float64) predicate.APIKey {
-	return predicate.APIKey(sql.FieldLT(FieldUsage5h, v))
+// SystemPromptModeEqualFold applies the EqualFold predicate on the "system_prompt_mode" field.
+func SystemPromptModeEqualFold(v string) predicate.APIKey {
+	return predicate.APIKey(sql.FieldEqualFold(FieldSystemPromptMode, v))
 }
 
-// Usage5hLTE applies the LTE predicate on the "usage_5h" field.
-func Usage5hLTE(v float64) predicate.APIKey {
-	return predicate.APIKey(sql.FieldLTE(FieldUsage5h, v))
+// SystemPromptModeContainsFold applies the ContainsFold predicate on the "system_prompt_mode" field.
+func SystemPromptModeContainsFold(v string) predicate.APIKey {
+	return predicate.APIKey(sql.FieldContainsFold(FieldSystemPromptMode, v))
 }
 
-// Usage1dEQ applies the EQ predicate on the "usage_1d" field.
-func Usage1dEQ(v float64) predicate.APIKey {
-	return predicate.APIKey(sql.FieldEQ(FieldUsage1d, v))
+// ModerationModeEQ applies the EQ predicate on the "moderation_mode" field.
+func ModerationModeEQ(v string) predicate.APIKey {
+	return predicate.APIKey(sql.FieldEQ(FieldModerationMode, v))
 }
 
-// Usage1dNEQ applies the NEQ predicate on the "usage_1d" field.
+// ModerationModeNEQ applies the NEQ predicate on the "moderation_mode" field.
+func ModerationModeNEQ(v string) predicate.APIKey {
+	return predicate.APIKey(sql.FieldNEQ(FieldModerationMode, v))
+}
+
+// ModerationModeIn applies the In predicate on the "moderation_mode" field.
+func ModerationModeIn(vs ...string) predicate.APIKey {
+	return predicate.APIKey(sql.FieldIn(FieldModerationMode, vs...))
+}
+
+// ModerationModeNotIn applies the NotIn predicate on the "moderation_mode" field.
+func ModerationModeNotIn(vs ...string) predicate.APIKey {
+	return predicate.APIKey(sql.FieldNotIn(FieldModerationMode, vs...))
+}
+
+// ModerationModeGT applies the GT predicate on the "moderation_mode" field.
+func ModerationModeGT(v string) predicate.APIKey {
+	return predicate.APIKey(sql.FieldGT(FieldModerationMode, v))
+}
+
+// ModerationModeGTE applies the GTE predicate on the "moderation_mode" field.
+func ModerationModeGTE(v string) predicate.APIKey {
+	return predicate.APIKey(sql.FieldGTE(FieldModerationMode, v))
+}
+
+// ModerationModeLT applies the LT predicate on the "moderation_mode" field.
+func ModerationModeLT(v string) predicate.APIKey {
+	return predicate.APIKey(sql.FieldLT(FieldModerationMode, v))
+}
+
+// ModerationModeLTE applies the LTE predicate on the "moderation_mode" field.
+func ModerationModeLTE(v string) predicate.APIKey {
+	return predicate.APIKey(sql.FieldLTE(FieldModerationMode, v))
+}
+
+// ModerationModeContains applies the Contains predicate on the "moderation_mode" field.
+func ModerationModeContains(v string) predicate.APIKey {
+	return predicate.APIKey(sql.FieldContains(FieldModerationMode, v))
+}
+
+// ModerationModeHasPrefix applies the HasPrefix predicate on the "moderation_mode" field.
+func ModerationModeHasPrefix(v string) predicate.APIKey {
+	return predicate.APIKey(sql.FieldHasPrefix(FieldModerationMode, v))
+}
+
+// ModerationModeHasSuffix applies the HasSuffix predicate on the "moderation_mode" field.
+func ModerationModeHasSuffix(v string) predicate.APIKey {
+	return predicate.APIKey(sql.FieldHasSuffix(FieldModerationMode, v))
+}
+
+// ModerationModeEqualFold applies the EqualFold predicate on the "moderation_mode" field.
+func ModerationModeEqualFold(v string) predicate.APIKey {
+	return predicate.APIKey(sql.FieldEqualFold(FieldModerationMode, v))
+}
+
+// ModerationModeContainsFold applies the ContainsFold predicate on the "moderation_mode" field.
+func ModerationModeContainsFold(v string) predicate.APIKey {
+	return predicate.APIKey(sql.FieldContainsFold(FieldModerationMode, v))
+}
+
+// LogContentEQ applies the EQ predicate on the "log_content" field.
+func LogContentEQ(v bool) predicate.APIKey {
+	return predicate.APIKey(sql.FieldEQ(FieldLogContent, v))
+}
+
+// LogContentNEQ applies the NEQ predicate on the "log_content" field.
+func LogContentNEQ(v bool) predicate.APIKey {
+	return predicate.APIKey(sql.FieldNEQ(FieldLogContent, v))
+}
+
+// SimulateEQ applies the EQ predicate on the "simulate" field.
+func SimulateEQ(v bool) predicate.APIKey {
+	return predicate.APIKey(sql.FieldEQ(FieldSimulate, v))
+}
+
+// SimulateNEQ applies the NEQ predicate on the "simulate" field.
+func SimulateNEQ(v bool) predicate.APIKey {
+	return predicate.APIKey(sql.FieldNEQ(FieldSimulate, v))
+}
+
+// QuotaEQ applies the EQ predicate on the "quota" field.
+func QuotaEQ(v float64) predicate.APIKey {
+	return predicate.APIKey(sql.FieldEQ(FieldQuota, v))
+}
+
+// QuotaNEQ applies the NEQ predicate on the "quota" field.
+func QuotaNEQ(v float64) predicate.APIKey {
+	return predicate.APIKey(sql.FieldNEQ(FieldQuota, v))
+}
+
+// QuotaIn applies the In predicate on the "quota" field.
+func QuotaIn(vs ...float64) predicate.APIKey {
+	return predicate.APIKey(sql.FieldIn(FieldQuota, vs...))
+}
+
+// QuotaNotIn applies the NotIn predicate on the "quota" field.
+func QuotaNotIn(vs ...float64) predicate.APIKey {
+	return predicate.APIKey(sql.FieldNotIn(FieldQuota, vs...))
+}
+
+// QuotaGT applies the GT predicate on the "quota" field.
+func QuotaGT(v float64) predicate.APIKey {
+	return predicate.APIKey(sql.FieldGT(FieldQuota, v))
+}
+
+// QuotaGTE applies the GTE predicate on the "quota" field.
+func QuotaGTE(v float64) predicate.APIKey {
+	return predicate.APIKey(sql.FieldGTE(FieldQuota, v))
+}
+
+// QuotaLT applies the LT predicate on the "quota" field.
+func QuotaLT(v float64) predicate.APIKey {
+	return predicate.APIKey(sql.FieldLT(FieldQuota, v))
+}
+
+// QuotaLTE applies the LTE predicate on the "quota" field.
+func QuotaLTE(v float64) predicate.APIKey {
+	return predicate.APIKey(sql.FieldLTE(FieldQuota, v))
+}
+
+// QuotaUsedEQ applies the EQ predicate on the "quota_used" field.
+func QuotaUsedEQ(v float64) predicate.APIKey {
+	return predicate.APIKey(sql.FieldEQ(FieldQuotaUsed, v))
+}
+
+// QuotaUsedNEQ applies the NEQ predicate on the "quota_used" field.
+func QuotaUsedNEQ(v float64) predicate.APIKey {
+	return predicate.APIKey(sql.FieldNEQ(FieldQuotaUsed, v))
+}
+
+// QuotaUsedIn applies the In predicate on the "quota_used" field.
+func QuotaUsedIn(vs ...float64) predicate.APIKey {
+	return predicate.APIKey(sql.FieldIn(FieldQuotaUsed, vs...))
+}
+
+// QuotaUsedNotIn applies the NotIn predicate on the "quota_used" field.
+func QuotaUsedNotIn(vs ...float64) predicate.APIKey {
+	return predicate.APIKey(sql.FieldNotIn(FieldQuotaUsed, vs...))
+}
+
+// QuotaUsedGT applies the GT predicate on the "quota_used" field.
+func QuotaUsedGT(v float64) predicate.APIKey {
+	return predicate.APIKey(sql.FieldGT(FieldQuotaUsed, v))
+}
+
+// QuotaUsedGTE applies the GTE predicate on the "quota_used" field.
+func QuotaUsedGTE(v float64) predicate.APIKey {
+	return predicate.APIKey(sql.FieldGTE(FieldQuotaUsed, v))
+}
+
+// QuotaUsedLT applies the LT predicate on the "quota_used" field.
+func QuotaUsedLT(v float64) predicate.APIKey {
+	return predicate.APIKey(sql.FieldLT(FieldQuotaUsed, v))
+}
+
+// QuotaUsedLTE applies the LTE predicate on the "quota_used" field.
+func QuotaUsedLTE(v float64) predicate.APIKey {
+	return predicate.APIKey(sql.FieldLTE(FieldQuotaUsed, v))
+}
+
+// ImageQuotaEQ applies the EQ predicate on the "image_quota" field.
+func ImageQuotaEQ(v int) predicate.APIKey {
+	return predicate.APIKey(sql.FieldEQ(FieldImageQuota, v))
+}
+
+// ImageQuotaNEQ applies the NEQ predicate on the "image_quota" field.
+func ImageQuotaNEQ(v int) predicate.APIKey {
+	return predicate.APIKey(sql.FieldNEQ(FieldImageQuota, v))
+}
+
+// ImageQuotaIn applies the In predicate on the "image_quota" field.
+func ImageQuotaIn(vs ...int) predicate.APIKey {
+	return predicate.APIKey(sql.FieldIn(FieldImageQuota, vs...))
+}
+
+// ImageQuotaNotIn applies the NotIn predicate on the "image_quota" field.
+func ImageQuotaNotIn(vs ...int) predicate.APIKey {
+	return predicate.APIKey(sql.FieldNotIn(FieldImageQuota, vs...))
+}
+
+// ImageQuotaGT applies the GT predicate on the "image_quota" field.
+func ImageQuotaGT(v int) predicate.APIKey {
+	return predicate.APIKey(sql.FieldGT(FieldImageQuota, v))
+}
+
+// ImageQuotaGTE applies the GTE predicate on the "image_quota" field.
+func ImageQuotaGTE(v int) predicate.APIKey {
+	return predicate.APIKey(sql.FieldGTE(FieldImageQuota, v))
+}
+
+// ImageQuotaLT applies the LT predicate on the "image_quota" field.
+func ImageQuotaLT(v int) predicate.APIKey {
+	return predicate.APIKey(sql.FieldLT(FieldImageQuota, v))
+}
+
+// ImageQuotaLTE applies the LTE predicate on the "image_quota" field.
+func ImageQuotaLTE(v int) predicate.APIKey {
+	return predicate.APIKey(sql.FieldLTE(FieldImageQuota, v))
+}
+
+// ImageQuotaUsedEQ applies the EQ predicate on the "image_quota_used" field.
+func ImageQuotaUsedEQ(v int) predicate.APIKey {
+	return predicate.APIKey(sql.FieldEQ(FieldImageQuotaUsed, v))
+}
+
+// ImageQuotaUsedNEQ applies the NEQ predicate on the "image_quota_used" field.
+func ImageQuotaUsedNEQ(v int) predicate.APIKey {
+	return predicate.APIKey(sql.FieldNEQ(FieldImageQuotaUsed, v))
+}
+
+// ImageQuotaUsedIn applies the In predicate on the "image_quota_used" field.
+func ImageQuotaUsedIn(vs ...int) predicate.APIKey {
+	return predicate.APIKey(sql.FieldIn(FieldImageQuotaUsed, vs...))
+}
+
+// ImageQuotaUsedNotIn applies the NotIn predicate on the "image_quota_used" field.
+func ImageQuotaUsedNotIn(vs ...int) predicate.APIKey {
+	return predicate.APIKey(sql.FieldNotIn(FieldImageQuotaUsed, vs...))
+}
+
+// ImageQuotaUsedGT applies the GT predicate on the "image_quota_used" field.
+func ImageQuotaUsedGT(v int) predicate.APIKey {
+	return predicate.APIKey(sql.FieldGT(FieldImageQuotaUsed, v))
+}
+
+// ImageQuotaUsedGTE applies the GTE predicate on the "image_quota_used" field.
+func ImageQuotaUsedGTE(v int) predicate.APIKey {
+	return predicate.APIKey(sql.FieldGTE(FieldImageQuotaUsed, v))
+}
+
+// ImageQuotaUsedLT applies the LT predicate on the "image_quota_used" field.
+func ImageQuotaUsedLT(v int) predicate.APIKey {
+	return predicate.APIKey(sql.FieldLT(FieldImageQuotaUsed, v))
+}
+
+// ImageQuotaUsedLTE applies the LTE predicate on the "image_quota_used" field.
+func ImageQuotaUsedLTE(v int) predicate.APIKey {
+	return predicate.APIKey(sql.FieldLTE(FieldImageQuotaUsed, v))
+}
+
+// SuppressReasoningEQ applies the EQ predicate on the "suppress_reasoning" field.
+func SuppressReasoningEQ(v bool) predicate.APIKey {
+	return predicate.APIKey(sql.FieldEQ(FieldSuppressReasoning, v))
+}
+
+// SuppressReasoningNEQ applies the NEQ predicate on the "suppress_reasoning" field.
+func SuppressReasoningNEQ(v bool) predicate.APIKey {
+	return predicate.APIKey(sql.FieldNEQ(FieldSuppressReasoning, v))
+}
+
+// ExpiresAtEQ applies the EQ predicate on the "expires_at" field.
+func ExpiresAtEQ(v time.Time) predicate.APIKey {
+	return predicate.APIKey(sql.FieldEQ(FieldExpiresAt, v))
+}
+
+// ExpiresAtNEQ applies the NEQ predicate on the "expires_at" field.
+func ExpiresAtNEQ(v time.Time) predicate.APIKey {
+	return predicate.APIKey(sql.FieldNEQ(FieldExpiresAt, v))
+}
+
+// ExpiresAtIn applies the In predicate on the "expires_at" field.
+func ExpiresAtIn(vs ...time.Time) predicate.APIKey {
+	return predicate.APIKey(sql.FieldIn(FieldExpiresAt, vs...))
+}
+
+// ExpiresAtNotIn applies the NotIn predicate on the "expires_at" field.
+func ExpiresAtNotIn(vs ...time.Time) predicate.APIKey {
+	return predicate.APIKey(sql.FieldNotIn(FieldExpiresAt, vs...))
+}
+
+// ExpiresAtGT applies the GT predicate on the "expires_at" field.
+func ExpiresAtGT(v time.Time) predicate.APIKey {
+	return predicate.APIKey(sql.FieldGT(FieldExpiresAt, v))
+}
+
+// ExpiresAtGTE applies the GTE predicate on the "expires_at" field.
+func ExpiresAtGTE(v time.Time) predicate.APIKey {
+	return predicate.APIKey(sql.FieldGTE(FieldExpiresAt, v))
+}
+
+// ExpiresAtLT applies the LT predicate on the "expires_at" field.
+func ExpiresAtLT(v time.Time) predicate.APIKey {
+	return predicate.APIKey(sql.FieldLT(FieldExpiresAt, v))
+}
+
+// ExpiresAtLTE applies the LTE predicate on the "expires_at" field.
+func ExpiresAtLTE(v time.Time) predicate.APIKey {
+	return predicate.APIKey(sql.FieldLTE(FieldExpiresAt, v))
+}
+
+// ExpiresAtIsNil applies the IsNil predicate on the "expires_at" field.
+func ExpiresAtIsNil() predicate.APIKey {
+	return predicate.APIKey(sql.FieldIsNull(FieldExpiresAt))
+}
+
+// ExpiresAtNotNil applies the NotNil predicate on the "expires_at" field.
+func ExpiresAtNotNil() predicate.APIKey {
+	return predicate.APIKey(sql.FieldNotNull(FieldExpiresAt))
+}
+
+// RateLimit5hEQ applies the EQ predicate on the "rate_limit_5h" field.
+func RateLimit5hEQ(v float64) predicate.APIKey {
+	return predicate.APIKey(sql.FieldEQ(FieldRateLimit5h, v))
+}
+
+// RateLimit5hNEQ applies the NEQ predicate on the "rate_limit_5h" field.
+func RateLimit5hNEQ(v float64) predicate.APIKey {
+	return predicate.APIKey(sql.FieldNEQ(FieldRateLimit5h, v))
+}
+
+// RateLimit5hIn applies the In predicate on the "rate_limit_5h" field.
+func RateLimit5hIn(vs ...float64) predicate.APIKey {
+	return predicate.APIKey(sql.FieldIn(FieldRateLimit5h, vs...))
+}
+
+// RateLimit5hNotIn applies the NotIn predicate on the "rate_limit_5h" field.
+func RateLimit5hNotIn(vs ...float64) predicate.APIKey {
+	return predicate.APIKey(sql.FieldNotIn(FieldRateLimit5h, vs...))
+}
+
+// RateLimit5hGT applies the GT predicate on the "rate_limit_5h" field.
+func RateLimit5hGT(v float64) predicate.APIKey {
+	return predicate.APIKey(sql.FieldGT(FieldRateLimit5h, v))
+}
+
+// RateLimit5hGTE applies the GTE predicate on the "rate_limit_5h" field.
+func RateLimit5hGTE(v float64) predicate.APIKey {
+	return predicate.APIKey(sql.FieldGTE(FieldRateLimit5h, v))
+}
+
+// RateLimit5hLT applies the LT predicate on the "rate_limit_5h" field.
+func RateLimit5hLT(v float64) predicate.APIKey {
+	return predicate.APIKey(sql.FieldLT(FieldRateLimit5h, v))
+}
+
+// RateLimit5hLTE applies the LTE predicate on the "rate_limit_5h" field.
+func RateLimit5hLTE(v float64) predicate.APIKey {
+	return predicate.APIKey(sql.FieldLTE(FieldRateLimit5h, v))
+}
+
+// RateLimit1dEQ applies the EQ predicate on the "rate_limit_1d" field.
+func RateLimit1dEQ(v float64) predicate.APIKey {
+	return predicate.APIKey(sql.FieldEQ(FieldRateLimit1d, v))
+}
+
+// RateLimit1dNEQ applies the NEQ predicate on the "rate_limit_1d" field.
+func RateLimit1dNEQ(v float64) predicate.APIKey {
+	return predicate.APIKey(sql.FieldNEQ(FieldRateLimit1d, v))
+}
+
+// RateLimit1dIn applies the In predicate on the "rate_limit_1d" field.
+func RateLimit1dIn(vs ...float64) predicate.APIKey {
+	return predicate.APIKey(sql.FieldIn(FieldRateLimit1d, vs...))
+}
+
+// RateLimit1dNotIn applies the NotIn predicate on the "rate_limit_1d" field.
+func RateLimit1dNotIn(vs ...float64) predicate.APIKey {
+	return predicate.APIKey(sql.FieldNotIn(FieldRateLimit1d, vs...))
+}
+
+// RateLimit1dGT applies the GT predicate on the "rate_limit_1d" field.
+func RateLimit1dGT(v float64) predicate.APIKey {
+	return predicate.APIKey(sql.FieldGT(FieldRateLimit1d, v))
+}
+
+// RateLimit1dGTE applies the GTE predicate on the "rate_limit_1d" field.
+func RateLimit1dGTE(v float64) predicate.APIKey {
+	return predicate.APIKey(sql.FieldGTE(FieldRateLimit1d, v))
+}
+
+// RateLimit1dLT applies the LT predicate on the "rate_limit_1d" field.
+func RateLimit1dLT(v float64) predicate.APIKey {
+	return predicate.APIKey(sql.FieldLT(FieldRateLimit1d, v))
+}
+
+// RateLimit1dLTE applies the LTE predicate on the "rate_limit_1d" field.
+func RateLimit1dLTE(v float64) predicate.APIKey {
+	return predicate.APIKey(sql.FieldLTE(FieldRateLimit1d, v))
+}
+
+// RateLimit7dEQ applies the EQ predicate on the "rate_limit_7d" field.
+func RateLimit7dEQ(v float64) predicate.APIKey {
+	return predicate.APIKey(sql.FieldEQ(FieldRateLimit7d, v))
+}
+
+// RateLimit7dNEQ applies the NEQ predicate on the "rate_limit_7d" field.
+func RateLimit7dNEQ(v float64) predicate.APIKey {
+	return predicate.APIKey(sql.FieldNEQ(FieldRateLimit7d, v))
+}
+
+// RateLimit7dIn applies the In predicate on the "rate_limit_7d" field.
+func RateLimit7dIn(vs ...float64) predicate.APIKey {
+	return predicate.APIKey(sql.FieldIn(FieldRateLimit7d, vs...))
+}
+
+// RateLimit7dNotIn applies the NotIn predicate on the "rate_limit_7d" field.
+func RateLimit7dNotIn(vs ...float64) predicate.APIKey {
+	return predicate.APIKey(sql.FieldNotIn(FieldRateLimit7d, vs...))
+}
+
+// RateLimit7dGT applies the GT predicate on the "rate_limit_7d" field.
+func RateLimit7dGT(v float64) predicate.APIKey {
+	return predicate.APIKey(sql.FieldGT(FieldRateLimit7d, v))
+}
+
+// RateLimit7dGTE applies the GTE predicate on the "rate_limit_7d" field.
+func RateLimit7dGTE(v float64) predicate.APIKey {
+	return predicate.APIKey(sql.FieldGTE(FieldRateLimit7d, v))
+}
+
+// RateLimit7dLT applies the LT predicate on the "rate_limit_7d" field.
+func RateLimit7dLT(v float64) predicate.APIKey {
+	return predicate.APIKey(sql.FieldLT(FieldRateLimit7d, v))
+}
+
+// RateLimit7dLTE applies the LTE predicate on the "rate_limit_7d" field.
+func RateLimit7dLTE(v float64) predicate.APIKey {
+	return predicate.APIKey(sql.FieldLTE(FieldRateLimit7d, v))
+}
+
+// Usage5hEQ applies the EQ predicate on the "usage_5h" field.
+func Usage5hEQ(v float64) predicate.APIKey {
+	return predicate.APIKey(sql.FieldEQ(FieldUsage5h, v))
+}
+
+// Usage5hNEQ applies the NEQ predicate on the "usage_5h" field.
+func Usage5hNEQ(v float64) predicate.APIKey {
+	return predicate.APIKey(sql.FieldNEQ(FieldUsage5h, v))
+}
+
+// Usage5hIn applies the In predicate on the "usage_5h" field.
+func Usage5hIn(vs ...float64) predicate.APIKey {
+	return predicate.APIKey(sql.FieldIn(FieldUsage5h, vs...))
+}
+
+// Usage5hNotIn applies the NotIn predicate on the "usage_5h" field.
+func Usage5hNotIn(vs ...float64) predicate.APIKey {
+	return predicate.APIKey(sql.FieldNotIn(FieldUsage5h, vs...))
+}
+
+// Usage5hGT applies the GT predicate on the "usage_5h" field.
+func Usage5hGT(v float64) predicate.APIKey {
+	return predicate.APIKey(sql.FieldGT(FieldUsage5h, v))
+}
+
+// Usage5hGTE applies the GTE predicate on the "usage_5h" field.
+func Usage5hGTE(v float64) predicate.APIKey {
+	return predicate.APIKey(sql.FieldGTE(FieldUsage5h, v))
+}
+
+// Usage5hLT applies the LT predicate on the "usage_5h" field.
+func Usage5hLT(v float64) predicate.APIKey {
+	return predicate.APIKey(sql.FieldLT(FieldUsage5h, v))
+}
+
+// Usage5hLTE applies the LTE predicate on the "usage_5h" field.
+func Usage5hLTE(v float64) predicate.APIKey {
+	return predicate.APIKey(sql.FieldLTE(FieldUsage5h, v))
+}
+
+// Usage1dEQ applies the EQ predicate on the "usage_1d" field.
+func Usage1dEQ(v float64) predicate.APIKey {
+	return predicate.APIKey(sql.FieldEQ(FieldUsage1d, v))
+}
+
+// Usage1dNEQ applies the NEQ predicate on the "usage_1d" field.
 func Usage1dNEQ(v float64) predicate.APIKey {
 	return predicate.APIKey(sql.FieldNEQ(FieldUsage1d, v))
 }
 
-// Usage1dIn applies the In predicate on the "usage_1d" field.
-func Usage1dIn(vs ...float64) predicate.APIKey {
-	return predicate.APIKey(sql.FieldIn(FieldUsage1d, vs...))
+// Usage1dIn applies the In predicate on the "usage_1d" field.
+func Usage1dIn(vs ...float64) predicate.APIKey {
+	return predicate.APIKey(sql.FieldIn(FieldUsage1d, vs...))
+}
+
+// Usage1dNotIn applies the NotIn predicate on the "usage_1d" field.
+func Usage1dNotIn(vs ...float64) predicate.APIKey {
+	return predicate.APIKey(sql.FieldNotIn(FieldUsage1d, vs...))
+}
+
+// Usage1dGT applies the GT predicate on the "usage_1d" field.
+func Usage1dGT(v float64) predicate.APIKey {
+	return predicate.APIKey(sql.FieldGT(FieldUsage1d, v))
+}
+
+// Usage1dGTE applies the GTE predicate on the "usage_1d" field.
+func Usage1dGTE(v float64) predicate.APIKey {
+	return predicate.APIKey(sql.FieldGTE(FieldUsage1d, v))
+}
+
+// Usage1dLT applies the LT predicate on the "usage_1d" field.
+func Usage1dLT(v float64) predicate.APIKey {
+	return predicate.APIKey(sql.FieldLT(FieldUsage1d, v))
+}
+
+// Usage1dLTE applies the LTE predicate on the "usage_1d" field.
+func Usage1dLTE(v float64) predicate.APIKey {
+	return predicate.APIKey(sql.FieldLTE(FieldUsage1d, v))
+}
+
+// Usage7dEQ applies the EQ predicate on the "usage_7d" field.
+func Usage7dEQ(v float64) predicate.APIKey {
+	return predicate.APIKey(sql.FieldEQ(FieldUsage7d, v))
+}
+
+// Usage7dNEQ applies the NEQ predicate on the "usage_7d" field.
+func Usage7dNEQ(v float64) predicate.APIKey {
+	return predicate.APIKey(sql.FieldNEQ(FieldUsage7d, v))
+}
+
+// Usage7dIn applies the In predicate on the "usage_7d" field.
+func Usage7dIn(vs ...float64) predicate.APIKey {
+	return predicate.APIKey(sql.FieldIn(FieldUsage7d, vs...))
+}
+
+// Usage7dNotIn applies the NotIn predicate on the "usage_7d" field.
+func Usage7dNotIn(vs ...float64) predicate.APIKey {
+	return predicate.APIKey(sql.FieldNotIn(FieldUsage7d, vs...))
+}
+
+// Usage7dGT applies the GT predicate on the "usage_7d" field.
+func Usage7dGT(v float64) predicate.APIKey {
+	return predicate.APIKey(sql.FieldGT(FieldUsage7d, v))
+}
+
+// Usage7dGTE applies the GTE predicate on the "usage_7d" field.
+func Usage7dGTE(v float64) predicate.APIKey {
+	return predicate.APIKey(sql.FieldGTE(FieldUsage7d, v))
+}
+
+// Usage7dLT applies the LT predicate on the "usage_7d" field.
+func Usage7dLT(v float64) predicate.APIKey {
+	return predicate.APIKey(sql.FieldLT(FieldUsage7d, v))
+}
+
+// Usage7dLTE applies the LTE predicate on the "usage_7d" field.
+func Usage7dLTE(v float64) predicate.APIKey {
+	return predicate.APIKey(sql.FieldLTE(FieldUsage7d, v))
+}
+
+// Window5hStartEQ applies the EQ predicate on the "window_5h_start" field.
+func Window5hStartEQ(v time.Time) predicate.APIKey {
+	return predicate.APIKey(sql.FieldEQ(FieldWindow5hStart, v))
+}
+
+// Window5hStartNEQ applies the NEQ predicate on the "window_5h_start" field.
+func Window5hStartNEQ(v time.Time) predicate.APIKey {
+	return predicate.APIKey(sql.FieldNEQ(FieldWindow5hStart, v))
+}
+
+// Window5hStartIn applies the In predicate on the "window_5h_start" field.
+func Window5hStartIn(vs ...time.Time) predicate.APIKey {
+	return predicate.APIKey(sql.FieldIn(FieldWindow5hStart, vs...))
+}
+
+// Window5hStartNotIn applies the NotIn predicate on the "window_5h_start" field.
+func Window5hStartNotIn(vs ...time.Time) predicate.APIKey {
+	return predicate.APIKey(sql.FieldNotIn(FieldWindow5hStart, vs...))
+}
+
+// Window5hStartGT applies the GT predicate on the "window_5h_start" field.
+func Window5hStartGT(v time.Time) predicate.APIKey {
+	return predicate.APIKey(sql.FieldGT(FieldWindow5hStart, v))
+}
+
+// Window5hStartGTE applies the GTE predicate on the "window_5h_start" field.
+func Window5hStartGTE(v time.Time) predicate.APIKey {
+	return predicate.APIKey(sql.FieldGTE(FieldWindow5hStart, v))
+}
+
+// Window5hStartLT applies the LT predicate on the "window_5h_start" field.
+func Window5hStartLT(v time.Time) predicate.APIKey {
+	return predicate.APIKey(sql.FieldLT(FieldWindow5hStart, v))
+}
+
+// Window5hStartLTE applies the LTE predicate on the "window_5h_start" field.
+func Window5hStartLTE(v time.Time) predicate.APIKey {
+	return predicate.APIKey(sql.FieldLTE(FieldWindow5hStart, v))
+}
+
+// Window5hStartIsNil applies the IsNil predicate on the "window_5h_start" field.
+func Window5hStartIsNil() predicate.APIKey {
+	return predicate.APIKey(sql.FieldIsNull(FieldWindow5hStart))
+}
+
+// Window5hStartNotNil applies the NotNil predicate on the "window_5h_start" field.
+func Window5hStartNotNil() predicate.APIKey {
+	return predicate.APIKey(sql.FieldNotNull(FieldWindow5hStart))
+}
+
+// Window1dStartEQ applies the EQ predicate on the "window_1d_start" field.
+func Window1dStartEQ(v time.Time) predicate.APIKey {
+	return predicate.APIKey(sql.FieldEQ(FieldWindow1dStart, v))
+}
+
+// Window1dStartNEQ applies the NEQ predicate on the "window_1d_start" field.
+func Window1dStartNEQ(v time.Time) predicate.APIKey {
+	return predicate.APIKey(sql.FieldNEQ(FieldWindow1dStart, v))
+}
+
+// Window1dStartIn applies the In predicate on the "window_1d_start" field.
+func Window1dStartIn(vs ...time.Time) predicate.APIKey {
+	return predicate.APIKey(sql.FieldIn(FieldWindow1dStart, vs...))
+}
+
+// Window1dStartNotIn applies the NotIn predicate on the "window_1d_start" field.
+func Window1dStartNotIn(vs ...time.Time) predicate.APIKey {
+	return predicate.APIKey(sql.FieldNotIn(FieldWindow1dStart, vs...))
+}
+
+// Window1dStartGT applies the GT predicate on the "window_1d_start" field.
+func Window1dStartGT(v time.Time) predicate.APIKey {
+	return predicate.APIKey(sql.FieldGT(FieldWindow1dStart, v))
+}
+
+// Window1dStartGTE applies the GTE predicate on the "window_1d_start" field.
+func Window1dStartGTE(v time.Time) predicate.APIKey {
+	return predicate.APIKey(sql.FieldGTE(FieldWindow1dStart, v))
+}
+
+// Window1dStartLT applies the LT predicate on the "window_1d_start" field.
+func Window1dStartLT(v time.Time) predicate.APIKey {
+	return predicate.APIKey(sql.FieldLT(FieldWindow1dStart, v))
+}
+
+// Window1dStartLTE applies the LTE predicate on the "window_1d_start" field.
+func Window1dStartLTE(v time.Time) predicate.APIKey {
+	return predicate.APIKey(sql.FieldLTE(FieldWindow1dStart, v))
+}
+
+// Window1dStartIsNil applies the IsNil predicate on the "window_1d_start" field.
+func Window1dStartIsNil() predicate.APIKey {
+	return predicate.APIKey(sql.FieldIsNull(FieldWindow1dStart))
+}
+
+// Window1dStartNotNil applies the NotNil predicate on the "window_1d_start" field.
+func Window1dStartNotNil() predicate.APIKey {
+	return predicate.APIKey(sql.FieldNotNull(FieldWindow1dStart))
+}
+
+// Window7dStartEQ applies the EQ predicate on the "window_7d_start" field.
+func Window7dStartEQ(v time.Time) predicate.APIKey {
+	return predicate.APIKey(sql.FieldEQ(FieldWindow7dStart, v))
+}
+
+// Window7dStartNEQ applies the NEQ predicate on the "window_7d_start" field.
+func Window7dStartNEQ(v time.Time) predicate.APIKey {
+	return predicate.APIKey(sql.FieldNEQ(FieldWindow7dStart, v))
+}
+
+// Window7dStartIn applies the In predicate on the "window_7d_start" field.
+func Window7dStartIn(vs ...time.Time) predicate.APIKey {
+	return predicate.APIKey(sql.FieldIn(FieldWindow7dStart, vs...))
+}
+
+// Window7dStartNotIn applies the NotIn predicate on the "window_7d_start" field.
+func Window7dStartNotIn(vs ...time.Time) predicate.APIKey {
+	return predicate.APIKey(sql.FieldNotIn(FieldWindow7dStart, vs...))
+}
+
+// Window7dStartGT applies the GT predicate on the "window_7d_start" field.
+func Window7dStartGT(v time.Time) predicate.APIKey {
+	return predicate.APIKey(sql.FieldGT(FieldWindow7dStart, v))
+}
+
+// Window7dStartGTE applies the GTE predicate on the "window_7d_start" field.
+func Window7dStartGTE(v time.Time) predicate.APIKey {
+	return predicate.APIKey(sql.FieldGTE(FieldWindow7dStart, v))
+}
+
+// Window7dStartLT applies the LT predicate on the "window_7d_start" field.
+func Window7dStartLT(v time.Time) predicate.APIKey {
+	return predicate.APIKey(sql.FieldLT(FieldWindow7dStart, v))
+}
+
+// Window7dStartLTE applies the LTE predicate on the "window_7d_start" field.
+func Window7dStartLTE(v time.Time) predicate.APIKey {
+	return predicate.APIKey(sql.FieldLTE(FieldWindow7dStart, v))
+}
+
+// Window7dStartIsNil applies the IsNil predicate on the "window_7d_start" field.
+func Window7dStartIsNil() predicate.APIKey {
+	return predicate.APIKey(sql.FieldIsNull(FieldWindow7dStart))
+}
+
+// Window7dStartNotNil applies the NotNil predicate on the "window_7d_start" field.
+func Window7dStartNotNil() predicate.APIKey {
+	return predicate.APIKey(sql.FieldNotNull(FieldWindow7dStart))
+}
+
+// RpmLimitEQ applies the EQ predicate on the "rpm_limit" field.
+func RpmLimitEQ(v int) predicate.APIKey {
+	return predicate.APIKey(sql.FieldEQ(FieldRpmLimit, v))
+}
+
+// RpmLimitNEQ applies the NEQ predicate on the "rpm_limit" field.
+func RpmLimitNEQ(v int) predicate.APIKey {
+	return predicate.APIKey(sql.FieldNEQ(FieldRpmLimit, v))
+}
+
+// RpmLimitIn applies the In predicate on the "rpm_limit" field.
+func RpmLimitIn(vs ...int) predicate.APIKey {
+	return predicate.APIKey(sql.FieldIn(FieldRpmLimit, vs...))
+}
+
+// RpmLimitNotIn applies the NotIn predicate on the "rpm_limit" field.
+func RpmLimitNotIn(vs ...int) predicate.APIKey {
+	return predicate.APIKey(sql.FieldNotIn(FieldRpmLimit, vs...))
+}
+
+// RpmLimitGT applies the GT predicate on the "rpm_limit" field.
+func RpmLimitGT(v int) predicate.APIKey {
+	return predicate.APIKey(sql.FieldGT(FieldRpmLimit, v))
+}
+
+// RpmLimitGTE applies the GTE predicate on the "rpm_limit" field.
+func RpmLimitGTE(v int) predicate.APIKey {
+	return predicate.APIKey(sql.FieldGTE(FieldRpmLimit, v))
+}
+
+// RpmLimitLT applies the LT predicate on the "rpm_limit" field.
+func RpmLimitLT(v int) predicate.APIKey {
+	return predicate.APIKey(sql.FieldLT(FieldRpmLimit, v))
+}
+
+// RpmLimitLTE applies the LTE predicate on the "rpm_limit" field.
+func RpmLimitLTE(v int) predicate.APIKey {
+	return predicate.APIKey(sql.FieldLTE(FieldRpmLimit, v))
+}
+
+// TpmLimitEQ applies the EQ predicate on the "tpm_limit" field.
+func TpmLimitEQ(v int) predicate.APIKey {
+	return predicate.APIKey(sql.FieldEQ(FieldTpmLimit, v))
+}
+
+// TpmLimitNEQ applies the NEQ predicate on the "tpm_limit" field.
+func TpmLimitNEQ(v int) predicate.APIKey {
+	return predicate.APIKey(sql.FieldNEQ(FieldTpmLimit, v))
+}
+
+// TpmLimitIn applies the In predicate on the "tpm_limit" field.
+func TpmLimitIn(vs ...int) predicate.APIKey {
+	return predicate.APIKey(sql.FieldIn(FieldTpmLimit, vs...))
+}
+
+// TpmLimitNotIn applies the NotIn predicate on the "tpm_limit" field.
+func TpmLimitNotIn(vs ...int) predicate.APIKey {
+	return predicate.APIKey(sql.FieldNotIn(FieldTpmLimit, vs...))
+}
+
+// TpmLimitGT applies the GT predicate on the "tpm_limit" field.
+func TpmLimitGT(v int) predicate.APIKey {
+	return predicate.APIKey(sql.FieldGT(FieldTpmLimit, v))
+}
+
+// TpmLimitGTE applies the GTE predicate on the "tpm_limit" field.
+func TpmLimitGTE(v int) predicate.APIKey {
+	return predicate.APIKey(sql.FieldGTE(FieldTpmLimit, v))
+}
+
+// TpmLimitLT applies the LT predicate on the "tpm_limit" field.
+func TpmLimitLT(v int) predicate.APIKey {
+	return predicate.APIKey(sql.FieldLT(FieldTpmLimit, v))
+}
+
+// TpmLimitLTE applies the LTE predicate on the "tpm_limit" field.
+func TpmLimitLTE(v int) predicate.APIKey {
+	return predicate.APIKey(sql.FieldLTE(FieldTpmLimit, v))
+}
+
+// DailyRequestLimitEQ applies the EQ predicate on the "daily_request_limit" field.
+func DailyRequestLimitEQ(v int) predicate.APIKey {
+	return predicate.APIKey(sql.FieldEQ(FieldDailyRequestLimit, v))
+}
+
+// DailyRequestLimitNEQ applies the NEQ predicate on the "daily_request_limit" field.
+func DailyRequestLimitNEQ(v int) predicate.APIKey {
+	return predicate.APIKey(sql.FieldNEQ(FieldDailyRequestLimit, v))
+}
+
+// DailyRequestLimitIn applies the In predicate on the "daily_request_limit" field.
+func DailyRequestLimitIn(vs ...int) predicate.APIKey {
+	return predicate.APIKey(sql.FieldIn(FieldDailyRequestLimit, vs...))
+}
+
+// DailyRequestLimitNotIn applies the NotIn predicate on the "daily_request_limit" field.
+func DailyRequestLimitNotIn(vs ...int) predicate.APIKey {
+	return predicate.APIKey(sql.FieldNotIn(FieldDailyRequestLimit, vs...))
+}
+
+// DailyRequestLimitGT applies the GT predicate on the "daily_request_limit" field.
+func DailyRequestLimitGT(v int) predicate.APIKey {
+	return predicate.APIKey(sql.FieldGT(FieldDailyRequestLimit, v))
+}
+
+// DailyRequestLimitGTE applies the GTE predicate on the "daily_request_limit" field.
+func DailyRequestLimitGTE(v int) predicate.APIKey {
+	return predicate.APIKey(sql.FieldGTE(FieldDailyRequestLimit, v))
+}
+
+// DailyRequestLimitLT applies the LT predicate on the "daily_request_limit" field.
+func DailyRequestLimitLT(v int) predicate.APIKey {
+	return predicate.APIKey(sql.FieldLT(FieldDailyRequestLimit, v))
+}
+
+// DailyRequestLimitLTE applies the LTE predicate on the "daily_request_limit" field.
+func DailyRequestLimitLTE(v int) predicate.APIKey {
+	return predicate.APIKey(sql.FieldLTE(FieldDailyRequestLimit, v))
+}
+
+// DailyTokenLimitEQ applies the EQ predicate on the "daily_token_limit" field.
+func DailyTokenLimitEQ(v int64) predicate.APIKey {
+	return predicate.APIKey(sql.FieldEQ(FieldDailyTokenLimit, v))
+}
+
+// DailyTokenLimitNEQ applies the NEQ predicate on the "daily_token_limit" field.
+func DailyTokenLimitNEQ(v int64) predicate.APIKey {
+	return predicate.APIKey(sql.FieldNEQ(FieldDailyTokenLimit, v))
+}
+
+// DailyTokenLimitIn applies the In predicate on the "daily_token_limit" field.
+func DailyTokenLimitIn(vs ...int64) predicate.APIKey {
+	return predicate.APIKey(sql.FieldIn(FieldDailyTokenLimit, vs...))
+}
+
+// DailyTokenLimitNotIn applies the NotIn predicate on the "daily_token_limit" field.
+func DailyTokenLimitNotIn(vs ...int64) predicate.APIKey {
+	return predicate.APIKey(sql.FieldNotIn(FieldDailyTokenLimit, vs...))
+}
+
+// DailyTokenLimitGT applies the GT predicate on the "daily_token_limit" field.
+func DailyTokenLimitGT(v int64) predicate.APIKey {
+	return predicate.APIKey(sql.FieldGT(FieldDailyTokenLimit, v))
+}
+
+// DailyTokenLimitGTE applies the GTE predicate on the "daily_token_limit" field.
+func DailyTokenLimitGTE(v int64) predicate.APIKey {
+	return predicate.APIKey(sql.FieldGTE(FieldDailyTokenLimit, v))
+}
+
+// DailyTokenLimitLT applies the LT predicate on the "daily_token_limit" field.
+func DailyTokenLimitLT(v int64) predicate.APIKey {
+	return predicate.APIKey(sql.FieldLT(FieldDailyTokenLimit, v))
+}
+
+// DailyTokenLimitLTE applies the LTE predicate on the "daily_token_limit" field.
+func DailyTokenLimitLTE(v int64) predicate.APIKey {
+	return predicate.APIKey(sql.FieldLTE(FieldDailyTokenLimit, v))
+}
+
+// MonthlyRequestLimitEQ applies the EQ predicate on the "monthly_request_limit" field.
+func MonthlyRequestLimitEQ(v int) predicate.APIKey {
+	return predicate.APIKey(sql.FieldEQ(FieldMonthlyRequestLimit, v))
+}
+
+// MonthlyRequestLimitNEQ applies the NEQ predicate on the "monthly_request_limit" field.
+func MonthlyRequestLimitNEQ(v int) predicate.APIKey {
+	return predicate.APIKey(sql.FieldNEQ(FieldMonthlyRequestLimit, v))
+}
+
+// MonthlyRequestLimitIn applies the In predicate on the "monthly_request_limit" field.
+func MonthlyRequestLimitIn(vs ...int) predicate.APIKey {
+	return predicate.APIKey(sql.FieldIn(FieldMonthlyRequestLimit, vs...))
+}
+
+// MonthlyRequestLimitNotIn applies the NotIn predicate on the "monthly_request_limit" field.
+func MonthlyRequestLimitNotIn(vs ...int) predicate.APIKey {
+	return predicate.APIKey(sql.FieldNotIn(FieldMonthlyRequestLimit, vs...))
+}
+
+// MonthlyRequestLimitGT applies the GT predicate on the "monthly_request_limit" field.
+func MonthlyRequestLimitGT(v int) predicate.APIKey {
+	return predicate.APIKey(sql.FieldGT(FieldMonthlyRequestLimit, v))
+}
+
+// MonthlyRequestLimitGTE applies the GTE predicate on the "monthly_request_limit" field.
+func MonthlyRequestLimitGTE(v int) predicate.APIKey {
+	return predicate.APIKey(sql.FieldGTE(FieldMonthlyRequestLimit, v))
+}
+
+// MonthlyRequestLimitLT applies the LT predicate on the "monthly_request_limit" field.
+func MonthlyRequestLimitLT(v int) predicate.APIKey {
+	return predicate.APIKey(sql.FieldLT(FieldMonthlyRequestLimit, v))
+}
+
+// MonthlyRequestLimitLTE applies the LTE predicate on the "monthly_request_limit" field.
+func MonthlyRequestLimitLTE(v int) predicate.APIKey {
+	return predicate.APIKey(sql.FieldLTE(FieldMonthlyRequestLimit, v))
+}
+
+// MonthlyTokenLimitEQ applies the EQ predicate on the "monthly_token_limit" field.
+func MonthlyTokenLimitEQ(v int64) predicate.APIKey {
+	return predicate.APIKey(sql.FieldEQ(FieldMonthlyTokenLimit, v))
+}
+
+// MonthlyTokenLimitNEQ applies the NEQ predicate on the "monthly_token_limit" field.
+func MonthlyTokenLimitNEQ(v int64) predicate.APIKey {
+	return predicate.APIKey(sql.FieldNEQ(FieldMonthlyTokenLimit, v))
+}
+
+// MonthlyTokenLimitIn applies the In predicate on the "monthly_token_limit" field.
+func MonthlyTokenLimitIn(vs ...int64) predicate.APIKey {
+	return predicate.APIKey(sql.FieldIn(FieldMonthlyTokenLimit, vs...))
 }
 
-// Usage1dNotIn applies the NotIn predicate on the "usage_1d" field.
-func Usage1dNotIn(vs ...float64) predicate.APIKey {
-	return predicate.APIKey(sql.FieldNotIn(FieldUsage1d, vs...))
+// MonthlyTokenLimitNotIn applies the NotIn predicate on the "monthly_token_limit" field.
+func MonthlyTokenLimitNotIn(vs ...int64) predicate.APIKey {
+	return predicate.APIKey(sql.FieldNotIn(FieldMonthlyTokenLimit, vs...))
 }
 
-// Usage1dGT applies the GT predicate on the "usage_1d" field.
-func Usage1dGT(v float64) predicate.APIKey {
-	return predicate.APIKey(sql.FieldGT(FieldUsage1d, v))
+// MonthlyTokenLimitGT applies the GT predicate on the "monthly_token_limit" field.
+func MonthlyTokenLimitGT(v int64) predicate.APIKey {
+	return predicate.APIKey(sql.FieldGT(FieldMonthlyTokenLimit, v))
 }
 
-// Usage1dGTE applies the GTE predicate on the "usage_1d" field.
-func Usage1dGTE(v float64) predicate.APIKey {
-	return predicate.APIKey(sql.FieldGTE(FieldUsage1d, v))
+// MonthlyTokenLimitGTE applies the GTE predicate on the "monthly_token_limit" field.
+func MonthlyTokenLimitGTE(v int64) predicate.APIKey {
+	return predicate.APIKey(sql.FieldGTE(FieldMonthlyTokenLimit, v))
 }
 
-// Usage1dLT applies the LT predicate on the "usage_1d" field.
-func Usage1dLT(v float64) predicate.APIKey {
-	return predicate.APIKey(sql.FieldLT(FieldUsage1d, v))
+// MonthlyTokenLimitLT applies the LT predicate on the "monthly_token_limit" field.
+func MonthlyTokenLimitLT(v int64) predicate.APIKey {
+	return predicate.APIKey(sql.FieldLT(FieldMonthlyTokenLimit, v))
 }
 
-// Usage1dLTE applies the LTE predicate on the "usage_1d" field.
-func Usage1dLTE(v float64) predicate.APIKey {
-	return predicate.APIKey(sql.FieldLTE(FieldUsage1d, v))
+// MonthlyTokenLimitLTE applies the LTE predicate on the "monthly_token_limit" field.
+func MonthlyTokenLimitLTE(v int64) predicate.APIKey {
+	return predicate.APIKey(sql.FieldLTE(FieldMonthlyTokenLimit, v))
 }
 
-// Usage7dEQ applies the EQ predicate on the "usage_7d" field.
-func Usage7dEQ(v float64) predicate.APIKey {
-	return predicate.APIKey(sql.FieldEQ(FieldUsage7d, v))
+// BudgetAmountEQ applies the EQ predicate on the "budget_amount" field.
+func BudgetAmountEQ(v float64) predicate.APIKey {
+	return predicate.APIKey(sql.FieldEQ(FieldBudgetAmount, v))
 }
 
-// Usage7dNEQ applies the NEQ predicate on the "usage_7d" field.
-func Usage7dNEQ(v float64) predicate.APIKey {
-	return predicate.APIKey(sql.FieldNEQ(FieldUsage7d, v))
+// BudgetAmountNEQ applies the NEQ predicate on the "budget_amount" field.
+func BudgetAmountNEQ(v float64) predicate.APIKey {
+	return predicate.APIKey(sql.FieldNEQ(FieldBudgetAmount, v))
 }
 
-// Usage7dIn applies the In predicate on the "usage_7d" field.
-func Usage7dIn(vs ...float64) predicate.APIKey {
-	return predicate.APIKey(sql.FieldIn(FieldUsage7d, vs...))
+// BudgetAmountIn applies the In predicate on the "budget_amount" field.
+func BudgetAmountIn(vs ...float64) predicate.APIKey {
+	return predicate.APIKey(sql.FieldIn(FieldBudgetAmount, vs...))
 }
 
-// Usage7dNotIn applies the NotIn predicate on the "usage_7d" field.
-func Usage7dNotIn(vs ...float64) predicate.APIKey {
-	return predicate.APIKey(sql.FieldNotIn(FieldUsage7d, vs...))
+// BudgetAmountNotIn applies the NotIn predicate on the "budget_amount" field.
+func BudgetAmountNotIn(vs ...float64) predicate.APIKey {
+	return predicate.APIKey(sql.FieldNotIn(FieldBudgetAmount, vs...))
 }
 
-// Usage7dGT applies the GT predicate on the "usage_7d" field.
-func Usage7dGT(v float64) predicate.APIKey {
-	return predicate.APIKey(sql.FieldGT(FieldUsage7d, v))
+// BudgetAmountGT applies the GT predicate on the "budget_amount" field.
+func BudgetAmountGT(v float64) predicate.APIKey {
+	return predicate.APIKey(sql.FieldGT(FieldBudgetAmount, v))
 }
 
-// Usage7dGTE applies the GTE predicate on the "usage_7d" field.
-func Usage7dGTE(v float64) predicate.APIKey {
-	return predicate.APIKey(sql.FieldGTE(FieldUsage7d, v))
+// BudgetAmountGTE applies the GTE predicate on the "budget_amount" field.
+func BudgetAmountGTE(v float64) predicate.APIKey {
+	return predicate.APIKey(sql.FieldGTE(FieldBudgetAmount, v))
 }
 
-// Usage7dLT applies the LT predicate on the "usage_7d" field.
-func Usage7dLT(v float64) predicate.APIKey {
-	return predicate.APIKey(sql.FieldLT(FieldUsage7d, v))
+// BudgetAmountLT applies the LT predicate on the "budget_amount" field.
+func BudgetAmountLT(v float64) predicate.APIKey {
+	return predicate.APIKey(sql.FieldLT(FieldBudgetAmount, v))
 }
 
-// Usage7dLTE applies the LTE predicate on the "usage_7d" field.
-func Usage7dLTE(v float64) predicate.APIKey {
-	return predicate.APIKey(sql.FieldLTE(FieldUsage7d, v))
+// BudgetAmountLTE applies the LTE predicate on the "budget_amount" field.
+func BudgetAmountLTE(v float64) predicate.APIKey {
+	return predicate.APIKey(sql.FieldLTE(FieldBudgetAmount, v))
 }
 
-// Window5hStartEQ applies the EQ predicate on the "window_5h_start" field.
-func Window5hStartEQ(v time.Time) predicate.APIKey {
-	return predicate.APIKey(sql.FieldEQ(FieldWindow5hStart, v))
+// BudgetPeriodEQ applies the EQ predicate on the "budget_period" field.
+func BudgetPeriodEQ(v string) predicate.APIKey {
+	return predicate.APIKey(sql.FieldEQ(FieldBudgetPeriod, v))
 }
 
-// Window5hStartNEQ applies the NEQ predicate on the "window_5h_start" field.
-func Window5hStartNEQ(v time.Time) predicate.APIKey {
-	return predicate.APIKey(sql.FieldNEQ(FieldWindow5hStart, v))
+// BudgetPeriodNEQ applies the NEQ predicate on the "budget_period" field.
+func BudgetPeriodNEQ(v string) predicate.APIKey {
+	return predicate.APIKey(sql.FieldNEQ(FieldBudgetPeriod, v))
 }
 
-// Window5hStartIn applies the In predicate on the "window_5h_start" field.
-func Window5hStartIn(vs ...time.Time) predicate.APIKey {
-	return predicate.APIKey(sql.FieldIn(FieldWindow5hStart, vs...))
+// BudgetPeriodIn applies the In predicate on the "budget_period" field.
+func BudgetPeriodIn(vs ...string) predicate.APIKey {
+	return predicate.APIKey(sql.FieldIn(FieldBudgetPeriod, vs...))
 }
 
-// Window5hStartNotIn applies the NotIn predicate on the "window_5h_start" field.
-func Window5hStartNotIn(vs ...time.Time) predicate.APIKey {
-	return predicate.APIKey(sql.FieldNotIn(FieldWindow5hStart, vs...))
+// BudgetPeriodNotIn applies the NotIn predicate on the "budget_period" field.
+func BudgetPeriodNotIn(vs ...string) predicate.APIKey {
+	return predicate.APIKey(sql.FieldNotIn(FieldBudgetPeriod, vs...))
 }
 
-// Window5hStartGT applies the GT predicate on the "window_5h_start" field.
-func Window5hStartGT(v time.Time) predicate.APIKey {
-	return predicate.APIKey(sql.FieldGT(FieldWindow5hStart, v))
+// BudgetPeriodGT applies the GT predicate on the "budget_period" field.
+func BudgetPeriodGT(v string) predicate.APIKey {
+	return predicate.APIKey(sql.FieldGT(FieldBudgetPeriod, v))
 }
 
-// Window5hStartGTE applies the GTE predicate on the "window_5h_start" field.
-func Window5hStartGTE(v time.Time) predicate.APIKey {
-	return predicate.APIKey(sql.FieldGTE(FieldWindow5hStart, v))
+// BudgetPeriodGTE applies the GTE predicate on the "budget_period" field.
+func BudgetPeriodGTE(v string) predicate.APIKey {
+	return predicate.APIKey(sql.FieldGTE(FieldBudgetPeriod, v))
 }
 
-// Window5hStartLT applies the LT predicate on the "window_5h_start" field.
-func Window5hStartLT(v time.Time) predicate.APIKey {
-	return predicate.APIKey(sql.FieldLT(FieldWindow5hStart, v))
+// BudgetPeriodLT applies the LT predicate on the "budget_period" field.
+func BudgetPeriodLT(v string) predicate.APIKey {
+	return predicate.APIKey(sql.FieldLT(FieldBudgetPeriod, v))
 }
 
-// Window5hStartLTE applies the LTE predicate on the "window_5h_start" field.
-func Window5hStartLTE(v time.Time) predicate.APIKey {
-	return predicate.APIKey(sql.FieldLTE(FieldWindow5hStart, v))
+// BudgetPeriodLTE applies the LTE predicate on the "budget_period" field.
+func BudgetPeriodLTE(v string) predicate.APIKey {
+	return predicate.APIKey(sql.FieldLTE(FieldBudgetPeriod, v))
 }
 
-// Window5hStartIsNil applies the IsNil predicate on the "window_5h_start" field.
-func Window5hStartIsNil() predicate.APIKey {
-	return predicate.APIKey(sql.FieldIsNull(FieldWindow5hStart))
+// BudgetPeriodContains applies the Contains predicate on the "budget_period" field.
+func BudgetPeriodContains(v string) predicate.APIKey {
+	return predicate.APIKey(sql.FieldContains(FieldBudgetPeriod, v))
 }
 
-// Window5hStartNotNil applies the NotNil predicate on the "window_5h_start" field.
-func Window5hStartNotNil() predicate.APIKey {
-	return predicate.APIKey(sql.FieldNotNull(FieldWindow5hStart))
+// BudgetPeriodHasPrefix applies the HasPrefix predicate on the "budget_period" field.
+func BudgetPeriodHasPrefix(v string) predicate.APIKey {
+	return predicate.APIKey(sql.FieldHasPrefix(FieldBudgetPeriod, v))
 }
 
-// Window1dStartEQ applies the EQ predicate on the "window_1d_start" field.
-func Window1dStartEQ(v time.Time) predicate.APIKey {
-	return predicate.APIKey(sql.FieldEQ(FieldWindow1dStart, v))
+// BudgetPeriodHasSuffix applies the HasSuffix predicate on the "budget_period" field.
+func BudgetPeriodHasSuffix(v string) predicate.APIKey {
+	return predicate.APIKey(sql.FieldHasSuffix(FieldBudgetPeriod, v))
 }
 
-// Window1dStartNEQ applies the NEQ predicate on the "window_1d_start" field.
-func Window1dStartNEQ(v time.Time) predicate.APIKey {
-	return predicate.APIKey(sql.FieldNEQ(FieldWindow1dStart, v))
+// BudgetPeriodEqualFold applies the EqualFold predicate on the "budget_period" field.
+func BudgetPeriodEqualFold(v string) predicate.APIKey {
+	return predicate.APIKey(sql.FieldEqualFold(FieldBudgetPeriod, v))
 }
 
-// Window1dStartIn applies the In predicate on the "window_1d_start" field.
-func Window1dStartIn(vs ...time.Time) predicate.APIKey {
-	return predicate.APIKey(sql.FieldIn(FieldWindow1dStart, vs...))
+// BudgetPeriodContainsFold applies the ContainsFold predicate on the "budget_period" field.
+func BudgetPeriodContainsFold(v string) predicate.APIKey {
+	return predicate.APIKey(sql.FieldContainsFold(FieldBudgetPeriod, v))
 }
 
-// Window1dStartNotIn applies the NotIn predicate on the "window_1d_start" field.
-func Window1dStartNotIn(vs ...time.Time) predicate.APIKey {
-	return predicate.APIKey(sql.FieldNotIn(FieldWindow1dStart, vs...))
+// BudgetActionEQ applies the EQ predicate on the "budget_action" field.
+func BudgetActionEQ(v string) predicate.APIKey {
+	return predicate.APIKey(sql.FieldEQ(FieldBudgetAction, v))
 }
 
-// Window1dStartGT applies the GT predicate on the "window_1d_start" field.
-func Window1dStartGT(v time.Time) predicate.APIKey {
-	return predicate.APIKey(sql.FieldGT(FieldWindow1dStart, v))
+// BudgetActionNEQ applies the NEQ predicate on the "budget_action" field.
+func BudgetActionNEQ(v string) predicate.APIKey {
+	return predicate.APIKey(sql.FieldNEQ(FieldBudgetAction, v))
 }
 
-// Window1dStartGTE applies the GTE predicate on the "window_1d_start" field.
-func Window1dStartGTE(v time.Time) predicate.APIKey {
-	return predicate.APIKey(sql.FieldGTE(FieldWindow1dStart, v))
+// BudgetActionIn applies the In predicate on the "budget_action" field.
+func BudgetActionIn(vs ...string) predicate.APIKey {
+	return predicate.APIKey(sql.FieldIn(FieldBudgetAction, vs...))
 }
 
-// Window1dStartLT applies the LT predicate on the "window_1d_start" field.
-func Window1dStartLT(v time.Time) predicate.APIKey {
-	return predicate.APIKey(sql.FieldLT(FieldWindow1dStart, v))
+// BudgetActionNotIn applies the NotIn predicate on the "budget_action" field.
+func BudgetActionNotIn(vs ...string) predicate.APIKey {
+	return predicate.APIKey(sql.FieldNotIn(FieldBudgetAction, vs...))
 }
 
-// Window1dStartLTE applies the LTE predicate on the "window_1d_start" field.
-func Window1dStartLTE(v time.Time) predicate.APIKey {
-	return predicate.APIKey(sql.FieldLTE(FieldWindow1dStart, v))
+// BudgetActionGT applies the GT predicate on the "budget_action" field.
+func BudgetActionGT(v string) predicate.APIKey {
+	return predicate.APIKey(sql.FieldGT(FieldBudgetAction, v))
 }
 
-// Window1dStartIsNil applies the IsNil predicate on the "window_1d_start" field.
-func Window1dStartIsNil() predicate.APIKey {
-	return predicate.APIKey(sql.FieldIsNull(FieldWindow1dStart))
+// BudgetActionGTE applies the GTE predicate on the "budget_action" field.
+func BudgetActionGTE(v string) predicate.APIKey {
+	return predicate.APIKey(sql.FieldGTE(FieldBudgetAction, v))
 }
 
-// Window1dStartNotNil applies the NotNil predicate on the "window_1d_start" field.
-func Window1dStartNotNil() predicate.APIKey {
-	return predicate.APIKey(sql.FieldNotNull(FieldWindow1dStart))
+// BudgetActionLT applies the LT predicate on the "budget_action" field.
+func BudgetActionLT(v string) predicate.APIKey {
+	return predicate.APIKey(sql.FieldLT(FieldBudgetAction, v))
 }
 
-// Window7dStartEQ applies the EQ predicate on the "window_7d_start" field.
-func Window7dStartEQ(v time.Time) predicate.APIKey {
-	return predicate.APIKey(sql.FieldEQ(FieldWindow7dStart, v))
+// BudgetActionLTE applies the LTE predicate on the "budget_action" field.
+func BudgetActionLTE(v string) predicate.APIKey {
+	return predicate.APIKey(sql.FieldLTE(FieldBudgetAction, v))
 }
 
-// Window7dStartNEQ applies the NEQ predicate on the "window_7d_start" field.
-func Window7dStartNEQ(v time.Time) predicate.APIKey {
-	return predicate.APIKey(sql.FieldNEQ(FieldWindow7dStart, v))
+// BudgetActionContains applies the Contains predicate on the "budget_action" field.
+func BudgetActionContains(v string) predicate.APIKey {
+	return predicate.APIKey(sql.FieldContains(FieldBudgetAction, v))
 }
 
-// Window7dStartIn applies the In predicate on the "window_7d_start" field.
-func Window7dStartIn(vs ...time.Time) predicate.APIKey {
-	return predicate.APIKey(sql.FieldIn(FieldWindow7dStart, vs...))
+// BudgetActionHasPrefix applies the HasPrefix predicate on the "budget_action" field.
+func BudgetActionHasPrefix(v string) predicate.APIKey {
+	return predicate.APIKey(sql.FieldHasPrefix(FieldBudgetAction, v))
 }
 
-// Window7dStartNotIn applies the NotIn predicate on the "window_7d_start" field.
-func Window7dStartNotIn(vs ...time.Time) predicate.APIKey {
-	return predicate.APIKey(sql.FieldNotIn(FieldWindow7dStart, vs...))
+// BudgetActionHasSuffix applies the HasSuffix predicate on the "budget_action" field.
+func BudgetActionHasSuffix(v string) predicate.APIKey {
+	return predicate.APIKey(sql.FieldHasSuffix(FieldBudgetAction, v))
 }
 
-// Window7dStartGT applies the GT predicate on the "window_7d_start" field.
-func Window7dStartGT(v time.Time) predicate.APIKey {
-	return predicate.APIKey(sql.FieldGT(FieldWindow7dStart, v))
+// BudgetActionEqualFold applies the EqualFold predicate on the "budget_action" field.
+func BudgetActionEqualFold(v string) predicate.APIKey {
+	return predicate.APIKey(sql.FieldEqualFold(FieldBudgetAction, v))
 }
 
-// Window7dStartGTE applies the GTE predicate on the "window_7d_start" field.
-func Window7dStartGTE(v time.Time) predicate.APIKey {
-	return predicate.APIKey(sql.FieldGTE(FieldWindow7dStart, v))
+// BudgetActionContainsFold applies the ContainsFold predicate on the "budget_action" field.
+func BudgetActionContainsFold(v string) predicate.APIKey {
+	return predicate.APIKey(sql.FieldContainsFold(FieldBudgetAction, v))
 }
 
-// Window7dStartLT applies the LT predicate on the "window_7d_start" field.
-func Window7dStartLT(v time.Time) predicate.APIKey {
-	return predicate.APIKey(sql.FieldLT(FieldWindow7dStart, v))
+// BudgetFallbackModelEQ applies the EQ predicate on the "budget_fallback_model" field.
+func BudgetFallbackModelEQ(v string) predicate.APIKey {
+	return predicate.APIKey(sql.FieldEQ(FieldBudgetFallbackModel, v))
 }
 
-// Window7dStartLTE applies the LTE predicate on the "window_7d_start" field.
-func Window7dStartLTE(v time.Time) predicate.APIKey {
-	return predicate.APIKey(sql.FieldLTE(FieldWindow7dStart, v))
+// BudgetFallbackModelNEQ applies the NEQ predicate on the "budget_fallback_model" field.
+func BudgetFallbackModelNEQ(v string) predicate.APIKey {
+	return predicate.APIKey(sql.FieldNEQ(FieldBudgetFallbackModel, v))
 }
 
-// Window7dStartIsNil applies the IsNil predicate on the "window_7d_start" field.
-func Window7dStartIsNil() predicate.APIKey {
-	return predicate.APIKey(sql.FieldIsNull(FieldWindow7dStart))
+// BudgetFallbackModelIn applies the In predicate on the "budget_fallback_model" field.
+func BudgetFallbackModelIn(vs ...string) predicate.APIKey {
+	return predicate.APIKey(sql.FieldIn(FieldBudgetFallbackModel, vs...))
 }
 
-// Window7dStartNotNil applies the NotNil predicate on the "window_7d_start" field.
-func Window7dStartNotNil() predicate.APIKey {
-	return predicate.APIKey(sql.FieldNotNull(FieldWindow7dStart))
+// BudgetFallbackModelNotIn applies the NotIn predicate on the "budget_fallback_model" field.
+func BudgetFallbackModelNotIn(vs ...string) predicate.APIKey {
+	return predicate.APIKey(sql.FieldNotIn(FieldBudgetFallbackModel, vs...))
+}
+
+// BudgetFallbackModelGT applies the GT predicate on the "budget_fallback_model" field.
+func BudgetFallbackModelGT(v string) predicate.APIKey {
+	return predicate.APIKey(sql.FieldGT(FieldBudgetFallbackModel, v))
+}
+
+// BudgetFallbackModelGTE applies the GTE predicate on the "budget_fallback_model" field.
+func BudgetFallbackModelGTE(v string) predicate.APIKey {
+	return predicate.APIKey(sql.FieldGTE(FieldBudgetFallbackModel, v))
+}
+
+// BudgetFallbackModelLT applies the LT predicate on the "budget_fallback_model" field.
+func BudgetFallbackModelLT(v string) predicate.APIKey {
+	return predicate.APIKey(sql.FieldLT(FieldBudgetFallbackModel, v))
+}
+
+// BudgetFallbackModelLTE applies the LTE predicate on the "budget_fallback_model" field.
+func BudgetFallbackModelLTE(v string) predicate.APIKey {
+	return predicate.APIKey(sql.FieldLTE(FieldBudgetFallbackModel, v))
+}
+
+// BudgetFallbackModelContains applies the Contains predicate on the "budget_fallback_model" field.
+func BudgetFallbackModelContains(v string) predicate.APIKey {
+	return predicate.APIKey(sql.FieldContains(FieldBudgetFallbackModel, v))
+}
+
+// BudgetFallbackModelHasPrefix applies the HasPrefix predicate on the "budget_fallback_model" field.
+func BudgetFallbackModelHasPrefix(v string) predicate.APIKey {
+	return predicate.APIKey(sql.FieldHasPrefix(FieldBudgetFallbackModel, v))
+}
+
+// BudgetFallbackModelHasSuffix applies the HasSuffix predicate on the "budget_fallback_model" field.
+func BudgetFallbackModelHasSuffix(v string) predicate.APIKey {
+	return predicate.APIKey(sql.FieldHasSuffix(FieldBudgetFallbackModel, v))
+}
+
+// BudgetFallbackModelEqualFold applies the EqualFold predicate on the "budget_fallback_model" field.
+func BudgetFallbackModelEqualFold(v string) predicate.APIKey {
+	return predicate.APIKey(sql.FieldEqualFold(FieldBudgetFallbackModel, v))
+}
+
+// BudgetFallbackModelContainsFold applies the ContainsFold predicate on the "budget_fallback_model" field.
+func BudgetFallbackModelContainsFold(v string) predicate.APIKey {
+	return predicate.APIKey(sql.FieldContainsFold(FieldBudgetFallbackModel, v))
+}
+
+// BudgetUsedEQ applies the EQ predicate on the "budget_used" field.
+func BudgetUsedEQ(v float64) predicate.APIKey {
+	return predicate.APIKey(sql.FieldEQ(FieldBudgetUsed, v))
+}
+
+// BudgetUsedNEQ applies the NEQ predicate on the "budget_used" field.
+func BudgetUsedNEQ(v float64) predicate.APIKey {
+	return predicate.APIKey(sql.FieldNEQ(FieldBudgetUsed, v))
+}
+
+// BudgetUsedIn applies the In predicate on the "budget_used" field.
+func BudgetUsedIn(vs ...float64) predicate.APIKey {
+	return predicate.APIKey(sql.FieldIn(FieldBudgetUsed, vs...))
+}
+
+// BudgetUsedNotIn applies the NotIn predicate on the "budget_used" field.
+func BudgetUsedNotIn(vs ...float64) predicate.APIKey {
+	return predicate.APIKey(sql.FieldNotIn(FieldBudgetUsed, vs...))
+}
+
+// BudgetUsedGT applies the GT predicate on the "budget_used" field.
+func BudgetUsedGT(v float64) predicate.APIKey {
+	return predicate.APIKey(sql.FieldGT(FieldBudgetUsed, v))
+}
+
+// BudgetUsedGTE applies the GTE predicate on the "budget_used" field.
+func BudgetUsedGTE(v float64) predicate.APIKey {
+	return predicate.APIKey(sql.FieldGTE(FieldBudgetUsed, v))
+}
+
+// BudgetUsedLT applies the LT predicate on the "budget_used" field.
+func BudgetUsedLT(v float64) predicate.APIKey {
+	return predicate.APIKey(sql.FieldLT(FieldBudgetUsed, v))
+}
+
+// BudgetUsedLTE applies the LTE predicate on the "budget_used" field.
+func BudgetUsedLTE(v float64) predicate.APIKey {
+	return predicate.APIKey(sql.FieldLTE(FieldBudgetUsed, v))
+}
+
+// BudgetPeriodStartEQ applies the EQ predicate on the "budget_period_start" field.
+func BudgetPeriodStartEQ(v time.Time) predicate.APIKey {
+	return predicate.APIKey(sql.FieldEQ(FieldBudgetPeriodStart, v))
+}
+
+// BudgetPeriodStartNEQ applies the NEQ predicate on the "budget_period_start" field.
+func BudgetPeriodStartNEQ(v time.Time) predicate.APIKey {
+	return predicate.APIKey(sql.FieldNEQ(FieldBudgetPeriodStart, v))
+}
+
+// BudgetPeriodStartIn applies the In predicate on the "budget_period_start" field.
+func BudgetPeriodStartIn(vs ...time.Time) predicate.APIKey {
+	return predicate.APIKey(sql.FieldIn(FieldBudgetPeriodStart, vs...))
+}
+
+// BudgetPeriodStartNotIn applies the NotIn predicate on the "budget_period_start" field.
+func BudgetPeriodStartNotIn(vs ...time.Time) predicate.APIKey {
+	return predicate.APIKey(sql.FieldNotIn(FieldBudgetPeriodStart, vs...))
+}
+
+// BudgetPeriodStartGT applies the GT predicate on the "budget_period_start" field.
+func BudgetPeriodStartGT(v time.Time) predicate.APIKey {
+	return predicate.APIKey(sql.FieldGT(FieldBudgetPeriodStart, v))
+}
+
+// BudgetPeriodStartGTE applies the GTE predicate on the "budget_period_start" field.
+func BudgetPeriodStartGTE(v time.Time) predicate.APIKey {
+	return predicate.APIKey(sql.FieldGTE(FieldBudgetPeriodStart, v))
+}
+
+// BudgetPeriodStartLT applies the LT predicate on the "budget_period_start" field.
+func BudgetPeriodStartLT(v time.Time) predicate.APIKey {
+	return predicate.APIKey(sql.FieldLT(FieldBudgetPeriodStart, v))
+}
+
+// BudgetPeriodStartLTE applies the LTE predicate on the "budget_period_start" field.
+func BudgetPeriodStartLTE(v time.Time) predicate.APIKey {
+	return predicate.APIKey(sql.FieldLTE(FieldBudgetPeriodStart, v))
+}
+
+// BudgetPeriodStartIsNil applies the IsNil predicate on the "budget_period_start" field.
+func BudgetPeriodStartIsNil() predicate.APIKey {
+	return predicate.APIKey(sql.FieldIsNull(FieldBudgetPeriodStart))
+}
+
+// BudgetPeriodStartNotNil applies the NotNil predicate on the "budget_period_start" field.
+func BudgetPeriodStartNotNil() predicate.APIKey {
+	return predicate.APIKey(sql.FieldNotNull(FieldBudgetPeriodStart))
+}
+
+// BudgetExceededAtEQ applies the EQ predicate on the "budget_exceeded_at" field.
+func BudgetExceededAtEQ(v time.Time) predicate.APIKey {
+	return predicate.APIKey(sql.FieldEQ(FieldBudgetExceededAt, v))
+}
+
+// BudgetExceededAtNEQ applies the NEQ predicate on the "budget_exceeded_at" field.
+func BudgetExceededAtNEQ(v time.Time) predicate.APIKey {
+	return predicate.APIKey(sql.FieldNEQ(FieldBudgetExceededAt, v))
+}
+
+// BudgetExceededAtIn applies the In predicate on the "budget_exceeded_at" field.
+func BudgetExceededAtIn(vs ...time.Time) predicate.APIKey {
+	return predicate.APIKey(sql.FieldIn(FieldBudgetExceededAt, vs...))
+}
+
+// BudgetExceededAtNotIn applies the NotIn predicate on the "budget_exceeded_at" field.
+func BudgetExceededAtNotIn(vs ...time.Time) predicate.APIKey {
+	return predicate.APIKey(sql.FieldNotIn(FieldBudgetExceededAt, vs...))
+}
+
+// BudgetExceededAtGT applies the GT predicate on the "budget_exceeded_at" field.
+func BudgetExceededAtGT(v time.Time) predicate.APIKey {
+	return predicate.APIKey(sql.FieldGT(FieldBudgetExceededAt, v))
+}
+
+// BudgetExceededAtGTE applies the GTE predicate on the "budget_exceeded_at" field.
+func BudgetExceededAtGTE(v time.Time) predicate.APIKey {
+	return predicate.APIKey(sql.FieldGTE(FieldBudgetExceededAt, v))
+}
+
+// BudgetExceededAtLT applies the LT predicate on the "budget_exceeded_at" field.
+func BudgetExceededAtLT(v time.Time) predicate.APIKey {
+	return predicate.APIKey(sql.FieldLT(FieldBudgetExceededAt, v))
+}
+
+// BudgetExceededAtLTE applies the LTE predicate on the "budget_exceeded_at" field.
+func BudgetExceededAtLTE(v time.Time) predicate.APIKey {
+	return predicate.APIKey(sql.FieldLTE(FieldBudgetExceededAt, v))
+}
+
+// BudgetExceededAtIsNil applies the IsNil predicate on the "budget_exceeded_at" field.
+func BudgetExceededAtIsNil() predicate.APIKey {
+	return predicate.APIKey(sql.FieldIsNull(FieldBudgetExceededAt))
+}
+
+// BudgetExceededAtNotNil applies the NotNil predicate on the "budget_exceeded_at" field.
+func BudgetExceededAtNotNil() predicate.APIKey {
+	return predicate.APIKey(sql.FieldNotNull(FieldBudgetExceededAt))
+}
+
+// PreviousKeyEQ applies the EQ predicate on the "previous_key" field.
+func PreviousKeyEQ(v string) predicate.APIKey {
+	return predicate.APIKey(sql.FieldEQ(FieldPreviousKey, v))
+}
+
+// PreviousKeyNEQ applies the NEQ predicate on the "previous_key" field.
+func PreviousKeyNEQ(v string) predicate.APIKey {
+	return predicate.APIKey(sql.FieldNEQ(FieldPreviousKey, v))
+}
+
+// PreviousKeyIn applies the In predicate on the "previous_key" field.
+func PreviousKeyIn(vs ...string) predicate.APIKey {
+	return predicate.APIKey(sql.FieldIn(FieldPreviousKey, vs...))
+}
+
+// PreviousKeyNotIn applies the NotIn predicate on the "previous_key" field.
+func PreviousKeyNotIn(vs ...string) predicate.APIKey {
+	return predicate.APIKey(sql.FieldNotIn(FieldPreviousKey, vs...))
+}
+
+// PreviousKeyGT applies the GT predicate on the "previous_key" field.
+func PreviousKeyGT(v string) predicate.APIKey {
+	return predicate.APIKey(sql.FieldGT(FieldPreviousKey, v))
+}
+
+// PreviousKeyGTE applies the GTE predicate on the "previous_key" field.
+func PreviousKeyGTE(v string) predicate.APIKey {
+	return predicate.APIKey(sql.FieldGTE(FieldPreviousKey, v))
+}
+
+// PreviousKeyLT applies the LT predicate on the "previous_key" field.
+func PreviousKeyLT(v string) predicate.APIKey {
+	return predicate.APIKey(sql.FieldLT(FieldPreviousKey, v))
+}
+
+// PreviousKeyLTE applies the LTE predicate on the "previous_key" field.
+func PreviousKeyLTE(v string) predicate.APIKey {
+	return predicate.APIKey(sql.FieldLTE(FieldPreviousKey, v))
+}
+
+// PreviousKeyContains applies the Contains predicate on the "previous_key" field.
+func PreviousKeyContains(v string) predicate.APIKey {
+	return predicate.APIKey(sql.FieldContains(FieldPreviousKey, v))
+}
+
+// PreviousKeyHasPrefix applies the HasPrefix predicate on the "previous_key" field.
+func PreviousKeyHasPrefix(v string) predicate.APIKey {
+	return predicate.APIKey(sql.FieldHasPrefix(FieldPreviousKey, v))
+}
+
+// PreviousKeyHasSuffix applies the HasSuffix predicate on the "previous_key" field.
+func PreviousKeyHasSuffix(v string) predicate.APIKey {
+	return predicate.APIKey(sql.FieldHasSuffix(FieldPreviousKey, v))
+}
+
+// PreviousKeyIsNil applies the IsNil predicate on the "previous_key" field.
+func PreviousKeyIsNil() predicate.APIKey {
+	return predicate.APIKey(sql.FieldIsNull(FieldPreviousKey))
+}
+
+// PreviousKeyNotNil applies the NotNil predicate on the "previous_key" field.
+func PreviousKeyNotNil() predicate.APIKey {
+	return predicate.APIKey(sql.FieldNotNull(FieldPreviousKey))
+}
+
+// PreviousKeyEqualFold applies the EqualFold predicate on the "previous_key" field.
+func PreviousKeyEqualFold(v string) predicate.APIKey {
+	return predicate.APIKey(sql.FieldEqualFold(FieldPreviousKey, v))
+}
+
+// PreviousKeyContainsFold applies the ContainsFold predicate on the "previous_key" field.
+func PreviousKeyContainsFold(v string) predicate.APIKey {
+	return predicate.APIKey(sql.FieldContainsFold(FieldPreviousKey, v))
+}
+
+// PreviousKeyExpiresAtEQ applies the EQ predicate on the "previous_key_expires_at" field.
+func PreviousKeyExpiresAtEQ(v time.Time) predicate.APIKey {
+	return predicate.APIKey(sql.FieldEQ(FieldPreviousKeyExpiresAt, v))
+}
+
+// PreviousKeyExpiresAtNEQ applies the NEQ predicate on the "previous_key_expires_at" field.
+func PreviousKeyExpiresAtNEQ(v time.Time) predicate.APIKey {
+	return predicate.APIKey(sql.FieldNEQ(FieldPreviousKeyExpiresAt, v))
+}
+
+// PreviousKeyExpiresAtIn applies the In predicate on the "previous_key_expires_at" field.
+func PreviousKeyExpiresAtIn(vs ...time.Time) predicate.APIKey {
+	return predicate.APIKey(sql.FieldIn(FieldPreviousKeyExpiresAt, vs...))
+}
+
+// PreviousKeyExpiresAtNotIn applies the NotIn predicate on the "previous_key_expires_at" field.
+func PreviousKeyExpiresAtNotIn(vs ...time.Time) predicate.APIKey {
+	return predicate.APIKey(sql.FieldNotIn(FieldPreviousKeyExpiresAt, vs...))
+}
+
+// PreviousKeyExpiresAtGT applies the GT predicate on the "previous_key_expires_at" field.
+func PreviousKeyExpiresAtGT(v time.Time) predicate.APIKey {
+	return predicate.APIKey(sql.FieldGT(FieldPreviousKeyExpiresAt, v))
+}
+
+// PreviousKeyExpiresAtGTE applies the GTE predicate on the "previous_key_expires_at" field.
+func PreviousKeyExpiresAtGTE(v time.Time) predicate.APIKey {
+	return predicate.APIKey(sql.FieldGTE(FieldPreviousKeyExpiresAt, v))
+}
+
+// PreviousKeyExpiresAtLT applies the LT predicate on the "previous_key_expires_at" field.
+func PreviousKeyExpiresAtLT(v time.Time) predicate.APIKey {
+	return predicate.APIKey(sql.FieldLT(FieldPreviousKeyExpiresAt, v))
+}
+
+// PreviousKeyExpiresAtLTE applies the LTE predicate on the "previous_key_expires_at" field.
+func PreviousKeyExpiresAtLTE(v time.Time) predicate.APIKey {
+	return predicate.APIKey(sql.FieldLTE(FieldPreviousKeyExpiresAt, v))
+}
+
+// PreviousKeyExpiresAtIsNil applies the IsNil predicate on the "previous_key_expires_at" field.
+func PreviousKeyExpiresAtIsNil() predicate.APIKey {
+	return predicate.APIKey(sql.FieldIsNull(FieldPreviousKeyExpiresAt))
+}
+
+// PreviousKeyExpiresAtNotNil applies the NotNil predicate on the "previous_key_expires_at" field.
+func PreviousKeyExpiresAtNotNil() predicate.APIKey {
+	return predicate.APIKey(sql.FieldNotNull(FieldPreviousKeyExpiresAt))
 }
 
 // HasUser applies the HasEdge predicate on the "user" edge.
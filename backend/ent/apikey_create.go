@@ -85,6 +85,20 @@ func (_c *APIKeyCreate) SetName(v string) *APIKeyCreate {
 	return _c
 }
 
+// SetDescription sets the "description" field.
+func (_c *APIKeyCreate) SetDescription(v string) *APIKeyCreate {
+	_c.mutation.SetDescription(v)
+	return _c
+}
+
+// SetNillableDescription sets the "description" field if the given value is not nil.
+func (_c *APIKeyCreate) SetNillableDescription(v *string) *APIKeyCreate {
+	if v != nil {
+		_c.SetDescription(*v)
+	}
+	return _c
+}
+
 // SetGroupID sets the "group_id" field.
 func (_c *APIKeyCreate) SetGroupID(v int64) *APIKeyCreate {
 	_c.mutation.SetGroupID(v)
@@ -139,6 +153,122 @@ func (_c *APIKeyCreate) SetIPBlacklist(v []string) *APIKeyCreate {
 	return _c
 }
 
+// SetScopes sets the "scopes" field.
+func (_c *APIKeyCreate) SetScopes(v []string) *APIKeyCreate {
+	_c.mutation.SetScopes(v)
+	return _c
+}
+
+// SetAllowedModels sets the "allowed_models" field.
+func (_c *APIKeyCreate) SetAllowedModels(v []string) *APIKeyCreate {
+	_c.mutation.SetAllowedModels(v)
+	return _c
+}
+
+// SetClientRestriction sets the "client_restriction" field.
+func (_c *APIKeyCreate) SetClientRestriction(v string) *APIKeyCreate {
+	_c.mutation.SetClientRestriction(v)
+	return _c
+}
+
+// SetNillableClientRestriction sets the "client_restriction" field if the given value is not nil.
+func (_c *APIKeyCreate) SetNillableClientRestriction(v *string) *APIKeyCreate {
+	if v != nil {
+		_c.SetClientRestriction(*v)
+	}
+	return _c
+}
+
+// SetTags sets the "tags" field.
+func (_c *APIKeyCreate) SetTags(v []string) *APIKeyCreate {
+	_c.mutation.SetTags(v)
+	return _c
+}
+
+// SetDegradedFallbackModel sets the "degraded_fallback_model" field.
+func (_c *APIKeyCreate) SetDegradedFallbackModel(v string) *APIKeyCreate {
+	_c.mutation.SetDegradedFallbackModel(v)
+	return _c
+}
+
+// SetNillableDegradedFallbackModel sets the "degraded_fallback_model" field if the given value is not nil.
+func (_c *APIKeyCreate) SetNillableDegradedFallbackModel(v *string) *APIKeyCreate {
+	if v != nil {
+		_c.SetDegradedFallbackModel(*v)
+	}
+	return _c
+}
+
+// SetSystemPrompt sets the "system_prompt" field.
+func (_c *APIKeyCreate) SetSystemPrompt(v string) *APIKeyCreate {
+	_c.mutation.SetSystemPrompt(v)
+	return _c
+}
+
+// SetNillableSystemPrompt sets the "system_prompt" field if the given value is not nil.
+func (_c *APIKeyCreate) SetNillableSystemPrompt(v *string) *APIKeyCreate {
+	if v != nil {
+		_c.SetSystemPrompt(*v)
+	}
+	return _c
+}
+
+// SetSystemPromptMode sets the "system_prompt_mode" field.
+func (_c *APIKeyCreate) SetSystemPromptMode(v string) *APIKeyCreate {
+	_c.mutation.SetSystemPromptMode(v)
+	return _c
+}
+
+// SetNillableSystemPromptMode sets the "system_prompt_mode" field if the given value is not nil.
+func (_c *APIKeyCreate) SetNillableSystemPromptMode(v *string) *APIKeyCreate {
+	if v != nil {
+		_c.SetSystemPromptMode(*v)
+	}
+	return _c
+}
+
+// SetModerationMode sets the "moderation_mode" field.
+func (_c *APIKeyCreate) SetModerationMode(v string) *APIKeyCreate {
+	_c.mutation.SetModerationMode(v)
+	return _c
+}
+
+// SetNillableModerationMode sets the "moderation_mode" field if the given value is not nil.
+func (_c *APIKeyCreate) SetNillableModerationMode(v *string) *APIKeyCreate {
+	if v != nil {
+		_c.SetModerationMode(*v)
+	}
+	return _c
+}
+
+// SetLogContent sets the "log_content" field.
+func (_c *APIKeyCreate) SetLogContent(v bool) *APIKeyCreate {
+	_c.mutation.SetLogContent(v)
+	return _c
+}
+
+// SetNillableLogContent sets the "log_content" field if the given value is not nil.
+func (_c *APIKeyCreate) SetNillableLogContent(v *bool) *APIKeyCreate {
+	if v != nil {
+		_c.SetLogContent(*v)
+	}
+	return _c
+}
+
+// SetSimulate sets the "simulate" field.
+func (_c *APIKeyCreate) SetSimulate(v bool) *APIKeyCreate {
+	_c.mutation.SetSimulate(v)
+	return _c
+}
+
+// SetNillableSimulate sets the "simulate" field if the given value is not nil.
+func (_c *APIKeyCreate) SetNillableSimulate(v *bool) *APIKeyCreate {
+	if v != nil {
+		_c.SetSimulate(*v)
+	}
+	return _c
+}
+
 // SetQuota sets the "quota" field.
 func (_c *APIKeyCreate) SetQuota(v float64) *APIKeyCreate {
 	_c.mutation.SetQuota(v)
@@ -167,6 +297,48 @@ func (_c *APIKeyCreate) SetNillableQuotaUsed(v *float64) *APIKeyCreate {
 	return _c
 }
 
+// SetImageQuota sets the "image_quota" field.
+func (_c *APIKeyCreate) SetImageQuota(v int) *APIKeyCreate {
+	_c.mutation.SetImageQuota(v)
+	return _c
+}
+
+// SetNillableImageQuota sets the "image_quota" field if the given value is not nil.
+func (_c *APIKeyCreate) SetNillableImageQuota(v *int) *APIKeyCreate {
+	if v != nil {
+		_c.SetImageQuota(*v)
+	}
+	return _c
+}
+
+// SetImageQuotaUsed sets the "image_quota_used" field.
+func (_c *APIKeyCreate) SetImageQuotaUsed(v int) *APIKeyCreate {
+	_c.mutation.SetImageQuotaUsed(v)
+	return _c
+}
+
+// SetNillableImageQuotaUsed sets the "image_quota_used" field if the given value is not nil.
+func (_c *APIKeyCreate) SetNillableImageQuotaUsed(v *int) *APIKeyCreate {
+	if v != nil {
+		_c.SetImageQuotaUsed(*v)
+	}
+	return _c
+}
+
+// SetSuppressReasoning sets the "suppress_reasoning" field.
+func (_c *APIKeyCreate) SetSuppressReasoning(v bool) *APIKeyCreate {
+	_c.mutation.SetSuppressReasoning(v)
+	return _c
+}
+
+// SetNillableSuppressReasoning sets the "suppress_reasoning" field if the given value is not nil.
+func (_c *APIKeyCreate) SetNillableSuppressReasoning(v *bool) *APIKeyCreate {
+	if v != nil {
+		_c.SetSuppressReasoning(*v)
+	}
+	return _c
+}
+
 // SetExpiresAt sets the "expires_at" field.
 func (_c *APIKeyCreate) SetExpiresAt(v time.Time) *APIKeyCreate {
 	_c.mutation.SetExpiresAt(v)
@@ -307,6 +479,216 @@ func (_c *APIKeyCreate) SetNillableWindow7dStart(v *time.Time) *APIKeyCreate {
 	return _c
 }
 
+// SetRpmLimit sets the "rpm_limit" field.
+func (_c *APIKeyCreate) SetRpmLimit(v int) *APIKeyCreate {
+	_c.mutation.SetRpmLimit(v)
+	return _c
+}
+
+// SetNillableRpmLimit sets the "rpm_limit" field if the given value is not nil.
+func (_c *APIKeyCreate) SetNillableRpmLimit(v *int) *APIKeyCreate {
+	if v != nil {
+		_c.SetRpmLimit(*v)
+	}
+	return _c
+}
+
+// SetTpmLimit sets the "tpm_limit" field.
+func (_c *APIKeyCreate) SetTpmLimit(v int) *APIKeyCreate {
+	_c.mutation.SetTpmLimit(v)
+	return _c
+}
+
+// SetNillableTpmLimit sets the "tpm_limit" field if the given value is not nil.
+func (_c *APIKeyCreate) SetNillableTpmLimit(v *int) *APIKeyCreate {
+	if v != nil {
+		_c.SetTpmLimit(*v)
+	}
+	return _c
+}
+
+// SetDailyRequestLimit sets the "daily_request_limit" field.
+func (_c *APIKeyCreate) SetDailyRequestLimit(v int) *APIKeyCreate {
+	_c.mutation.SetDailyRequestLimit(v)
+	return _c
+}
+
+// SetNillableDailyRequestLimit sets the "daily_request_limit" field if the given value is not nil.
+func (_c *APIKeyCreate) SetNillableDailyRequestLimit(v *int) *APIKeyCreate {
+	if v != nil {
+		_c.SetDailyRequestLimit(*v)
+	}
+	return _c
+}
+
+// SetDailyTokenLimit sets the "daily_token_limit" field.
+func (_c *APIKeyCreate) SetDailyTokenLimit(v int64) *APIKeyCreate {
+	_c.mutation.SetDailyTokenLimit(v)
+	return _c
+}
+
+// SetNillableDailyTokenLimit sets the "daily_token_limit" field if the given value is not nil.
+func (_c *APIKeyCreate) SetNillableDailyTokenLimit(v *int64) *APIKeyCreate {
+	if v != nil {
+		_c.SetDailyTokenLimit(*v)
+	}
+	return _c
+}
+
+// SetMonthlyRequestLimit sets the "monthly_request_limit" field.
+func (_c *APIKeyCreate) SetMonthlyRequestLimit(v int) *APIKeyCreate {
+	_c.mutation.SetMonthlyRequestLimit(v)
+	return _c
+}
+
+// SetNillableMonthlyRequestLimit sets the "monthly_request_limit" field if the given value is not nil.
+func (_c *APIKeyCreate) SetNillableMonthlyRequestLimit(v *int) *APIKeyCreate {
+	if v != nil {
+		_c.SetMonthlyRequestLimit(*v)
+	}
+	return _c
+}
+
+// SetMonthlyTokenLimit sets the "monthly_token_limit" field.
+func (_c *APIKeyCreate) SetMonthlyTokenLimit(v int64) *APIKeyCreate {
+	_c.mutation.SetMonthlyTokenLimit(v)
+	return _c
+}
+
+// SetNillableMonthlyTokenLimit sets the "monthly_token_limit" field if the given value is not nil.
+func (_c *APIKeyCreate) SetNillableMonthlyTokenLimit(v *int64) *APIKeyCreate {
+	if v != nil {
+		_c.SetMonthlyTokenLimit(*v)
+	}
+	return _c
+}
+
+// SetBudgetAmount sets the "budget_amount" field.
+func (_c *APIKeyCreate) SetBudgetAmount(v float64) *APIKeyCreate {
+	_c.mutation.SetBudgetAmount(v)
+	return _c
+}
+
+// SetNillableBudgetAmount sets the "budget_amount" field if the given value is not nil.
+func (_c *APIKeyCreate) SetNillableBudgetAmount(v *float64) *APIKeyCreate {
+	if v != nil {
+		_c.SetBudgetAmount(*v)
+	}
+	return _c
+}
+
+// SetBudgetPeriod sets the "budget_period" field.
+func (_c *APIKeyCreate) SetBudgetPeriod(v string) *APIKeyCreate {
+	_c.mutation.SetBudgetPeriod(v)
+	return _c
+}
+
+// SetNillableBudgetPeriod sets the "budget_period" field if the given value is not nil.
+func (_c *APIKeyCreate) SetNillableBudgetPeriod(v *string) *APIKeyCreate {
+	if v != nil {
+		_c.SetBudgetPeriod(*v)
+	}
+	return _c
+}
+
+// SetBudgetAction sets the "budget_action" field.
+func (_c *APIKeyCreate) SetBudgetAction(v string) *APIKeyCreate {
+	_c.mutation.SetBudgetAction(v)
+	return _c
+}
+
+// SetNillableBudgetAction sets the "budget_action" field if the given value is not nil.
+func (_c *APIKeyCreate) SetNillableBudgetAction(v *string) *APIKeyCreate {
+	if v != nil {
+		_c.SetBudgetAction(*v)
+	}
+	return _c
+}
+
+// SetBudgetFallbackModel sets the "budget_fallback_model" field.
+func (_c *APIKeyCreate) SetBudgetFallbackModel(v string) *APIKeyCreate {
+	_c.mutation.SetBudgetFallbackModel(v)
+	return _c
+}
+
+// SetNillableBudgetFallbackModel sets the "budget_fallback_model" field if the given value is not nil.
+func (_c *APIKeyCreate) SetNillableBudgetFallbackModel(v *string) *APIKeyCreate {
+	if v != nil {
+		_c.SetBudgetFallbackModel(*v)
+	}
+	return _c
+}
+
+// SetBudgetUsed sets the "budget_used" field.
+func (_c *APIKeyCreate) SetBudgetUsed(v float64) *APIKeyCreate {
+	_c.mutation.SetBudgetUsed(v)
+	return _c
+}
+
+// SetNillableBudgetUsed sets the "budget_used" field if the given value is not nil.
+func (_c *APIKeyCreate) SetNillableBudgetUsed(v *float64) *APIKeyCreate {
+	if v != nil {
+		_c.SetBudgetUsed(*v)
+	}
+	return _c
+}
+
+// SetBudgetPeriodStart sets the "budget_period_start" field.
+func (_c *APIKeyCreate) SetBudgetPeriodStart(v time.Time) *APIKeyCreate {
+	_c.mutation.SetBudgetPeriodStart(v)
+	return _c
+}
+
+// SetNillableBudgetPeriodStart sets the "budget_period_start" field if the given value is not nil.
+func (_c *APIKeyCreate) SetNillableBudgetPeriodStart(v *time.Time) *APIKeyCreate {
+	if v != nil {
+		_c.SetBudgetPeriodStart(*v)
+	}
+	return _c
+}
+
+// SetBudgetExceededAt sets the "budget_exceeded_at" field.
+func (_c *APIKeyCreate) SetBudgetExceededAt(v time.Time) *APIKeyCreate {
+	_c.mutation.SetBudgetExceededAt(v)
+	return _c
+}
+
+// SetNillableBudgetExceededAt sets the "budget_exceeded_at" field if the given value is not nil.
+func (_c *APIKeyCreate) SetNillableBudgetExceededAt(v *time.Time) *APIKeyCreate {
+	if v != nil {
+		_c.SetBudgetExceededAt(*v)
+	}
+	return _c
+}
+
+// SetPreviousKey sets the "previous_key" field.
+func (_c *APIKeyCreate) SetPreviousKey(v string) *APIKeyCreate {
+	_c.mutation.SetPreviousKey(v)
+	return _c
+}
+
+// SetNillablePreviousKey sets the "previous_key" field if the given value is not nil.
+func (_c *APIKeyCreate) SetNillablePreviousKey(v *string) *APIKeyCreate {
+	if v != nil {
+		_c.SetPreviousKey(*v)
+	}
+	return _c
+}
+
+// SetPreviousKeyExpiresAt sets the "previous_key_expires_at" field.
+func (_c *APIKeyCreate) SetPreviousKeyExpiresAt(v time.Time) *APIKeyCreate {
+	_c.mutation.SetPreviousKeyExpiresAt(v)
+	return _c
+}
+
+// SetNillablePreviousKeyExpiresAt sets the "previous_key_expires_at" field if the given value is not nil.
+func (_c *APIKeyCreate) SetNillablePreviousKeyExpiresAt(v *time.Time) *APIKeyCreate {
+	if v != nil {
+		_c.SetPreviousKeyExpiresAt(*v)
+	}
+	return _c
+}
+
 // SetUser sets the "user" edge to the User entity.
 func (_c *APIKeyCreate) SetUser(v *User) *APIKeyCreate {
 	return _c.SetUserID(v.ID)
@@ -383,10 +765,42 @@ func (_c *APIKeyCreate) defaults() error {
 		v := apikey.DefaultUpdatedAt()
 		_c.mutation.SetUpdatedAt(v)
 	}
+	if _, ok := _c.mutation.Description(); !ok {
+		v := apikey.DefaultDescription
+		_c.mutation.SetDescription(v)
+	}
 	if _, ok := _c.mutation.Status(); !ok {
 		v := apikey.DefaultStatus
 		_c.mutation.SetStatus(v)
 	}
+	if _, ok := _c.mutation.ClientRestriction(); !ok {
+		v := apikey.DefaultClientRestriction
+		_c.mutation.SetClientRestriction(v)
+	}
+	if _, ok := _c.mutation.DegradedFallbackModel(); !ok {
+		v := apikey.DefaultDegradedFallbackModel
+		_c.mutation.SetDegradedFallbackModel(v)
+	}
+	if _, ok := _c.mutation.SystemPrompt(); !ok {
+		v := apikey.DefaultSystemPrompt
+		_c.mutation.SetSystemPrompt(v)
+	}
+	if _, ok := _c.mutation.SystemPromptMode(); !ok {
+		v := apikey.DefaultSystemPromptMode
+		_c.mutation.SetSystemPromptMode(v)
+	}
+	if _, ok := _c.mutation.ModerationMode(); !ok {
+		v := apikey.DefaultModerationMode
+		_c.mutation.SetModerationMode(v)
+	}
+	if _, ok := _c.mutation.LogContent(); !ok {
+		v := apikey.DefaultLogContent
+		_c.mutation.SetLogContent(v)
+	}
+	if _, ok := _c.mutation.Simulate(); !ok {
+		v := apikey.DefaultSimulate
+		_c.mutation.SetSimulate(v)
+	}
 	if _, ok := _c.mutation.Quota(); !ok {
 		v := apikey.DefaultQuota
 		_c.mutation.SetQuota(v)
@@ -395,6 +809,18 @@ func (_c *APIKeyCreate) defaults() error {
 		v := apikey.DefaultQuotaUsed
 		_c.mutation.SetQuotaUsed(v)
 	}
+	if _, ok := _c.mutation.ImageQuota(); !ok {
+		v := apikey.DefaultImageQuota
+		_c.mutation.SetImageQuota(v)
+	}
+	if _, ok := _c.mutation.ImageQuotaUsed(); !ok {
+		v := apikey.DefaultImageQuotaUsed
+		_c.mutation.SetImageQuotaUsed(v)
+	}
+	if _, ok := _c.mutation.SuppressReasoning(); !ok {
+		v := apikey.DefaultSuppressReasoning
+		_c.mutation.SetSuppressReasoning(v)
+	}
 	if _, ok := _c.mutation.RateLimit5h(); !ok {
 		v := apikey.DefaultRateLimit5h
 		_c.mutation.SetRateLimit5h(v)
@@ -419,6 +845,50 @@ func (_c *APIKeyCreate) defaults() error {
 		v := apikey.DefaultUsage7d
 		_c.mutation.SetUsage7d(v)
 	}
+	if _, ok := _c.mutation.RpmLimit(); !ok {
+		v := apikey.DefaultRpmLimit
+		_c.mutation.SetRpmLimit(v)
+	}
+	if _, ok := _c.mutation.TpmLimit(); !ok {
+		v := apikey.DefaultTpmLimit
+		_c.mutation.SetTpmLimit(v)
+	}
+	if _, ok := _c.mutation.DailyRequestLimit(); !ok {
+		v := apikey.DefaultDailyRequestLimit
+		_c.mutation.SetDailyRequestLimit(v)
+	}
+	if _, ok := _c.mutation.DailyTokenLimit(); !ok {
+		v := apikey.DefaultDailyTokenLimit
+		_c.mutation.SetDailyTokenLimit(v)
+	}
+	if _, ok := _c.mutation.MonthlyRequestLimit(); !ok {
+		v := apikey.DefaultMonthlyRequestLimit
+		_c.mutation.SetMonthlyRequestLimit(v)
+	}
+	if _, ok := _c.mutation.MonthlyTokenLimit(); !ok {
+		v := apikey.DefaultMonthlyTokenLimit
+		_c.mutation.SetMonthlyTokenLimit(v)
+	}
+	if _, ok := _c.mutation.BudgetAmount(); !ok {
+		v := apikey.DefaultBudgetAmount
+		_c.mutation.SetBudgetAmount(v)
+	}
+	if _, ok := _c.mutation.BudgetPeriod(); !ok {
+		v := apikey.DefaultBudgetPeriod
+		_c.mutation.SetBudgetPeriod(v)
+	}
+	if _, ok := _c.mutation.BudgetAction(); !ok {
+		v := apikey.DefaultBudgetAction
+		_c.mutation.SetBudgetAction(v)
+	}
+	if _, ok := _c.mutation.BudgetFallbackModel(); !ok {
+		v := apikey.DefaultBudgetFallbackModel
+		_c.mutation.SetBudgetFallbackModel(v)
+	}
+	if _, ok := _c.mutation.BudgetUsed(); !ok {
+		v := apikey.DefaultBudgetUsed
+		_c.mutation.SetBudgetUsed(v)
+	}
 	return nil
 }
 
@@ -449,6 +919,14 @@ func (_c *APIKeyCreate) check() error {
 			return &ValidationError{Name: "name", err: fmt.Errorf(`ent: validator failed for field "APIKey.name": %w`, err)}
 		}
 	}
+	if _, ok := _c.mutation.Description(); !ok {
+		return &ValidationError{Name: "description", err: errors.New(`ent: missing required field "APIKey.description"`)}
+	}
+	if v, ok := _c.mutation.Description(); ok {
+		if err := apikey.DescriptionValidator(v); err != nil {
+			return &ValidationError{Name: "description", err: fmt.Errorf(`ent: validator failed for field "APIKey.description": %w`, err)}
+		}
+	}
 	if _, ok := _c.mutation.Status(); !ok {
 		return &ValidationError{Name: "status", err: errors.New(`ent: missing required field "APIKey.status"`)}
 	}
@@ -457,23 +935,73 @@ func (_c *APIKeyCreate) check() error {
 			return &ValidationError{Name: "status", err: fmt.Errorf(`ent: validator failed for field "APIKey.status": %w`, err)}
 		}
 	}
-	if _, ok := _c.mutation.Quota(); !ok {
-		return &ValidationError{Name: "quota", err: errors.New(`ent: missing required field "APIKey.quota"`)}
+	if _, ok := _c.mutation.ClientRestriction(); !ok {
+		return &ValidationError{Name: "client_restriction", err: errors.New(`ent: missing required field "APIKey.client_restriction"`)}
 	}
-	if _, ok := _c.mutation.QuotaUsed(); !ok {
-		return &ValidationError{Name: "quota_used", err: errors.New(`ent: missing required field "APIKey.quota_used"`)}
+	if v, ok := _c.mutation.ClientRestriction(); ok {
+		if err := apikey.ClientRestrictionValidator(v); err != nil {
+			return &ValidationError{Name: "client_restriction", err: fmt.Errorf(`ent: validator failed for field "APIKey.client_restriction": %w`, err)}
+		}
 	}
-	if _, ok := _c.mutation.RateLimit5h(); !ok {
-		return &ValidationError{Name: "rate_limit_5h", err: errors.New(`ent: missing required field "APIKey.rate_limit_5h"`)}
+	if _, ok := _c.mutation.DegradedFallbackModel(); !ok {
+		return &ValidationError{Name: "degraded_fallback_model", err: errors.New(`ent: missing required field "APIKey.degraded_fallback_model"`)}
 	}
-	if _, ok := _c.mutation.RateLimit1d(); !ok {
-		return &ValidationError{Name: "rate_limit_1d", err: errors.New(`ent: missing required field "APIKey.rate_limit_1d"`)}
+	if v, ok := _c.mutation.DegradedFallbackModel(); ok {
+		if err := apikey.DegradedFallbackModelValidator(v); err != nil {
+			return &ValidationError{Name: "degraded_fallback_model", err: fmt.Errorf(`ent: validator failed for field "APIKey.degraded_fallback_model": %w`, err)}
+		}
 	}
-	if _, ok := _c.mutation.RateLimit7d(); !ok {
-		return &ValidationError{Name: "rate_limit_7d", err: errors.New(`ent: missing required field "APIKey.rate_limit_7d"`)}
+	if _, ok := _c.mutation.SystemPrompt(); !ok {
+		return &ValidationError{Name: "system_prompt", err: errors.New(`ent: missing required field "APIKey.system_prompt"`)}
 	}
-	if _, ok := _c.mutation.Usage5h(); !ok {
-		return &ValidationError{Name: "usage_5h", err: errors.New(`ent: missing required field "APIKey.usage_5h"`)}
+	if _, ok := _c.mutation.SystemPromptMode(); !ok {
+		return &ValidationError{Name: "system_prompt_mode", err: errors.New(`ent: missing required field "APIKey.system_prompt_mode"`)}
+	}
+	if v, ok := _c.mutation.SystemPromptMode(); ok {
+		if err := apikey.SystemPromptModeValidator(v); err != nil {
+			return &ValidationError{Name: "system_prompt_mode", err: fmt.Errorf(`ent: validator failed for field "APIKey.system_prompt_mode": %w`, err)}
+		}
+	}
+	if _, ok := _c.mutation.ModerationMode(); !ok {
+		return &ValidationError{Name: "moderation_mode", err: errors.New(`ent: missing required field "APIKey.moderation_mode"`)}
+	}
+	if v, ok := _c.mutation.ModerationMode(); ok {
+		if err := apikey.ModerationModeValidator(v); err != nil {
+			return &ValidationError{Name: "moderation_mode", err: fmt.Errorf(`ent: validator failed for field "APIKey.moderation_mode": %w`, err)}
+		}
+	}
+	if _, ok := _c.mutation.LogContent(); !ok {
+		return &ValidationError{Name: "log_content", err: errors.New(`ent: missing required field "APIKey.log_content"`)}
+	}
+	if _, ok := _c.mutation.Simulate(); !ok {
+		return &ValidationError{Name: "simulate", err: errors.New(`ent: missing required field "APIKey.simulate"`)}
+	}
+	if _, ok := _c.mutation.Quota(); !ok {
+		return &ValidationError{Name: "quota", err: errors.New(`ent: missing required field "APIKey.quota"`)}
+	}
+	if _, ok := _c.mutation.QuotaUsed(); !ok {
+		return &ValidationError{Name: "quota_used", err: errors.New(`ent: missing required field "APIKey.quota_used"`)}
+	}
+	if _, ok := _c.mutation.ImageQuota(); !ok {
+		return &ValidationError{Name: "image_quota", err: errors.New(`ent: missing required field "APIKey.image_quota"`)}
+	}
+	if _, ok := _c.mutation.ImageQuotaUsed(); !ok {
+		return &ValidationError{Name: "image_quota_used", err: errors.New(`ent: missing required field "APIKey.image_quota_used"`)}
+	}
+	if _, ok := _c.mutation.SuppressReasoning(); !ok {
+		return &ValidationError{Name: "suppress_reasoning", err: errors.New(`ent: missing required field "APIKey.suppress_reasoning"`)}
+	}
+	if _, ok := _c.mutation.RateLimit5h(); !ok {
+		return &ValidationError{Name: "rate_limit_5h", err: errors.New(`ent: missing required field "APIKey.rate_limit_5h"`)}
+	}
+	if _, ok := _c.mutation.RateLimit1d(); !ok {
+		return &ValidationError{Name: "rate_limit_1d", err: errors.New(`ent: missing required field "APIKey.rate_limit_1d"`)}
+	}
+	if _, ok := _c.mutation.RateLimit7d(); !ok {
+		return &ValidationError{Name: "rate_limit_7d", err: errors.New(`ent: missing required field "APIKey.rate_limit_7d"`)}
+	}
+	if _, ok := _c.mutation.Usage5h(); !ok {
+		return &ValidationError{Name: "usage_5h", err: errors.New(`ent: missing required field "APIKey.usage_5h"`)}
 	}
 	if _, ok := _c.mutation.Usage1d(); !ok {
 		return &ValidationError{Name: "usage_1d", err: errors.New(`ent: missing required field "APIKey.usage_1d"`)}
@@ -481,6 +1009,59 @@ func (_c *APIKeyCreate) check() error {
 	if _, ok := _c.mutation.Usage7d(); !ok {
 		return &ValidationError{Name: "usage_7d", err: errors.New(`ent: missing required field "APIKey.usage_7d"`)}
 	}
+	if _, ok := _c.mutation.RpmLimit(); !ok {
+		return &ValidationError{Name: "rpm_limit", err: errors.New(`ent: missing required field "APIKey.rpm_limit"`)}
+	}
+	if _, ok := _c.mutation.TpmLimit(); !ok {
+		return &ValidationError{Name: "tpm_limit", err: errors.New(`ent: missing required field "APIKey.tpm_limit"`)}
+	}
+	if _, ok := _c.mutation.DailyRequestLimit(); !ok {
+		return &ValidationError{Name: "daily_request_limit", err: errors.New(`ent: missing required field "APIKey.daily_request_limit"`)}
+	}
+	if _, ok := _c.mutation.DailyTokenLimit(); !ok {
+		return &ValidationError{Name: "daily_token_limit", err: errors.New(`ent: missing required field "APIKey.daily_token_limit"`)}
+	}
+	if _, ok := _c.mutation.MonthlyRequestLimit(); !ok {
+		return &ValidationError{Name: "monthly_request_limit", err: errors.New(`ent: missing required field "APIKey.monthly_request_limit"`)}
+	}
+	if _, ok := _c.mutation.MonthlyTokenLimit(); !ok {
+		return &ValidationError{Name: "monthly_token_limit", err: errors.New(`ent: missing required field "APIKey.monthly_token_limit"`)}
+	}
+	if _, ok := _c.mutation.BudgetAmount(); !ok {
+		return &ValidationError{Name: "budget_amount", err: errors.New(`ent: missing required field "APIKey.budget_amount"`)}
+	}
+	if _, ok := _c.mutation.BudgetPeriod(); !ok {
+		return &ValidationError{Name: "budget_period", err: errors.New(`ent: missing required field "APIKey.budget_period"`)}
+	}
+	if v, ok := _c.mutation.BudgetPeriod(); ok {
+		if err := apikey.BudgetPeriodValidator(v); err != nil {
+			return &ValidationError{Name: "budget_period", err: fmt.Errorf(`ent: validator failed for field "APIKey.budget_period": %w`, err)}
+		}
+	}
+	if _, ok := _c.mutation.BudgetAction(); !ok {
+		return &ValidationError{Name: "budget_action", err: errors.New(`ent: missing required field "APIKey.budget_action"`)}
+	}
+	if v, ok := _c.mutation.BudgetAction(); ok {
+		if err := apikey.BudgetActionValidator(v); err != nil {
+			return &ValidationError{Name: "budget_action", err: fmt.Errorf(`ent: validator failed for field "APIKey.budget_action": %w`, err)}
+		}
+	}
+	if _, ok := _c.mutation.BudgetFallbackModel(); !ok {
+		return &ValidationError{Name: "budget_fallback_model", err: errors.New(`ent: missing required field "APIKey.budget_fallback_model"`)}
+	}
+	if v, ok := _c.mutation.BudgetFallbackModel(); ok {
+		if err := apikey.BudgetFallbackModelValidator(v); err != nil {
+			return &ValidationError{Name: "budget_fallback_model", err: fmt.Errorf(`ent: validator failed for field "APIKey.budget_fallback_model": %w`, err)}
+		}
+	}
+	if _, ok := _c.mutation.BudgetUsed(); !ok {
+		return &ValidationError{Name: "budget_used", err: errors.New(`ent: missing required field "APIKey.budget_used"`)}
+	}
+	if v, ok := _c.mutation.PreviousKey(); ok {
+		if err := apikey.PreviousKeyValidator(v); err != nil {
+			return &ValidationError{Name: "previous_key", err: fmt.Errorf(`ent: validator failed for field "APIKey.previous_key": %w`, err)}
+		}
+	}
 	if len(_c.mutation.UserIDs()) == 0 {
 		return &ValidationError{Name: "user", err: errors.New(`ent: missing required edge "APIKey.user"`)}
 	}
@@ -531,6 +1112,10 @@ func (_c *APIKeyCreate) createSpec() (*APIKey, *sqlgraph.CreateSpec) {
 		_spec.SetField(apikey.FieldName, field.TypeString, value)
 		_node.Name = value
 	}
+	if value, ok := _c.mutation.Description(); ok {
+		_spec.SetField(apikey.FieldDescription, field.TypeString, value)
+		_node.Description = value
+	}
 	if value, ok := _c.mutation.Status(); ok {
 		_spec.SetField(apikey.FieldStatus, field.TypeString, value)
 		_node.Status = value
@@ -547,6 +1132,46 @@ func (_c *APIKeyCreate) createSpec() (*APIKey, *sqlgraph.CreateSpec) {
 		_spec.SetField(apikey.FieldIPBlacklist, field.TypeJSON, value)
 		_node.IPBlacklist = value
 	}
+	if value, ok := _c.mutation.Scopes(); ok {
+		_spec.SetField(apikey.FieldScopes, field.TypeJSON, value)
+		_node.Scopes = value
+	}
+	if value, ok := _c.mutation.AllowedModels(); ok {
+		_spec.SetField(apikey.FieldAllowedModels, field.TypeJSON, value)
+		_node.AllowedModels = value
+	}
+	if value, ok := _c.mutation.ClientRestriction(); ok {
+		_spec.SetField(apikey.FieldClientRestriction, field.TypeString, value)
+		_node.ClientRestriction = value
+	}
+	if value, ok := _c.mutation.Tags(); ok {
+		_spec.SetField(apikey.FieldTags, field.TypeJSON, value)
+		_node.Tags = value
+	}
+	if value, ok := _c.mutation.DegradedFallbackModel(); ok {
+		_spec.SetField(apikey.FieldDegradedFallbackModel, field.TypeString, value)
+		_node.DegradedFallbackModel = value
+	}
+	if value, ok := _c.mutation.SystemPrompt(); ok {
+		_spec.SetField(apikey.FieldSystemPrompt, field.TypeString, value)
+		_node.SystemPrompt = value
+	}
+	if value, ok := _c.mutation.SystemPromptMode(); ok {
+		_spec.SetField(apikey.FieldSystemPromptMode, field.TypeString, value)
+		_node.SystemPromptMode = value
+	}
+	if value, ok := _c.mutation.ModerationMode(); ok {
+		_spec.SetField(apikey.FieldModerationMode, field.TypeString, value)
+		_node.ModerationMode = value
+	}
+	if value, ok := _c.mutation.LogContent(); ok {
+		_spec.SetField(apikey.FieldLogContent, field.TypeBool, value)
+		_node.LogContent = value
+	}
+	if value, ok := _c.mutation.Simulate(); ok {
+		_spec.SetField(apikey.FieldSimulate, field.TypeBool, value)
+		_node.Simulate = value
+	}
 	if value, ok := _c.mutation.Quota(); ok {
 		_spec.SetField(apikey.FieldQuota, field.TypeFloat64, value)
 		_node.Quota = value
@@ -555,6 +1180,18 @@ func (_c *APIKeyCreate) createSpec() (*APIKey, *sqlgraph.CreateSpec) {
 		_spec.SetField(apikey.FieldQuotaUsed, field.TypeFloat64, value)
 		_node.QuotaUsed = value
 	}
+	if value, ok := _c.mutation.ImageQuota(); ok {
+		_spec.SetField(apikey.FieldImageQuota, field.TypeInt, value)
+		_node.ImageQuota = value
+	}
+	if value, ok := _c.mutation.ImageQuotaUsed(); ok {
+		_spec.SetField(apikey.FieldImageQuotaUsed, field.TypeInt, value)
+		_node.ImageQuotaUsed = value
+	}
+	if value, ok := _c.mutation.SuppressReasoning(); ok {
+		_spec.SetField(apikey.FieldSuppressReasoning, field.TypeBool, value)
+		_node.SuppressReasoning = value
+	}
 	if value, ok := _c.mutation.ExpiresAt(); ok {
 		_spec.SetField(apikey.FieldExpiresAt, field.TypeTime, value)
 		_node.ExpiresAt = &value
@@ -595,6 +1232,66 @@ func (_c *APIKeyCreate) createSpec() (*APIKey, *sqlgraph.CreateSpec) {
 		_spec.SetField(apikey.FieldWindow7dStart, field.TypeTime, value)
 		_node.Window7dStart = &value
 	}
+	if value, ok := _c.mutation.RpmLimit(); ok {
+		_spec.SetField(apikey.FieldRpmLimit, field.TypeInt, value)
+		_node.RpmLimit = value
+	}
+	if value, ok := _c.mutation.TpmLimit(); ok {
+		_spec.SetField(apikey.FieldTpmLimit, field.TypeInt, value)
+		_node.TpmLimit = value
+	}
+	if value, ok := _c.mutation.DailyRequestLimit(); ok {
+		_spec.SetField(apikey.FieldDailyRequestLimit, field.TypeInt, value)
+		_node.DailyRequestLimit = value
+	}
+	if value, ok := _c.mutation.DailyTokenLimit(); ok {
+		_spec.SetField(apikey.FieldDailyTokenLimit, field.TypeInt64, value)
+		_node.DailyTokenLimit = value
+	}
+	if value, ok := _c.mutation.MonthlyRequestLimit(); ok {
+		_spec.SetField(apikey.FieldMonthlyRequestLimit, field.TypeInt, value)
+		_node.MonthlyRequestLimit = value
+	}
+	if value, ok := _c.mutation.MonthlyTokenLimit(); ok {
+		_spec.SetField(apikey.FieldMonthlyTokenLimit, field.TypeInt64, value)
+		_node.MonthlyTokenLimit = value
+	}
+	if value, ok := _c.mutation.BudgetAmount(); ok {
+		_spec.SetField(apikey.FieldBudgetAmount, field.TypeFloat64, value)
+		_node.BudgetAmount = value
+	}
+	if value, ok := _c.mutation.BudgetPeriod(); ok {
+		_spec.SetField(apikey.FieldBudgetPeriod, field.TypeString, value)
+		_node.BudgetPeriod = value
+	}
+	if value, ok := _c.mutation.BudgetAction(); ok {
+		_spec.SetField(apikey.FieldBudgetAction, field.TypeString, value)
+		_node.BudgetAction = value
+	}
+	if value, ok := _c.mutation.BudgetFallbackModel(); ok {
+		_spec.SetField(apikey.FieldBudgetFallbackModel, field.TypeString, value)
+		_node.BudgetFallbackModel = value
+	}
+	if value, ok := _c.mutation.BudgetUsed(); ok {
+		_spec.SetField(apikey.FieldBudgetUsed, field.TypeFloat64, value)
+		_node.BudgetUsed = value
+	}
+	if value, ok := _c.mutation.BudgetPeriodStart(); ok {
+		_spec.SetField(apikey.FieldBudgetPeriodStart, field.TypeTime, value)
+		_node.BudgetPeriodStart = &value
+	}
+	if value, ok := _c.mutation.BudgetExceededAt(); ok {
+		_spec.SetField(apikey.FieldBudgetExceededAt, field.TypeTime, value)
+		_node.BudgetExceededAt = &value
+	}
+	if value, ok := _c.mutation.PreviousKey(); ok {
+		_spec.SetField(apikey.FieldPreviousKey, field.TypeString, value)
+		_node.PreviousKey = &value
+	}
+	if value, ok := _c.mutation.PreviousKeyExpiresAt(); ok {
+		_spec.SetField(apikey.FieldPreviousKeyExpiresAt, field.TypeTime, value)
+		_node.PreviousKeyExpiresAt = &value
+	}
 	if nodes := _c.mutation.UserIDs(); len(nodes) > 0 {
 		edge := &sqlgraph.EdgeSpec{
 			Rel:     sqlgraph.M2O,
@@ -763,6 +1460,18 @@ func (u *APIKeyUpsert) UpdateName() *APIKeyUpsert {
 	return u
 }
 
+// SetDescription sets the "description" field.
+func (u *APIKeyUpsert) SetDescription(v string) *APIKeyUpsert {
+	u.Set(apikey.FieldDescription, v)
+	return u
+}
+
+// UpdateDescription sets the "description" field to the value that was provided on create.
+func (u *APIKeyUpsert) UpdateDescription() *APIKeyUpsert {
+	u.SetExcluded(apikey.FieldDescription)
+	return u
+}
+
 // SetGroupID sets the "group_id" field.
 func (u *APIKeyUpsert) SetGroupID(v int64) *APIKeyUpsert {
 	u.Set(apikey.FieldGroupID, v)
@@ -847,6 +1556,144 @@ func (u *APIKeyUpsert) ClearIPBlacklist() *APIKeyUpsert {
 	return u
 }
 
+// SetScopes sets the "scopes" field.
+func (u *APIKeyUpsert) SetScopes(v []string) *APIKeyUpsert {
+	u.Set(apikey.FieldScopes, v)
+	return u
+}
+
+// UpdateScopes sets the "scopes" field to the value that was provided on create.
+func (u *APIKeyUpsert) UpdateScopes() *APIKeyUpsert {
+	u.SetExcluded(apikey.FieldScopes)
+	return u
+}
+
+// ClearScopes clears the value of the "scopes" field.
+func (u *APIKeyUpsert) ClearScopes() *APIKeyUpsert {
+	u.SetNull(apikey.FieldScopes)
+	return u
+}
+
+// SetAllowedModels sets the "allowed_models" field.
+func (u *APIKeyUpsert) SetAllowedModels(v []string) *APIKeyUpsert {
+	u.Set(apikey.FieldAllowedModels, v)
+	return u
+}
+
+// UpdateAllowedModels sets the "allowed_models" field to the value that was provided on create.
+func (u *APIKeyUpsert) UpdateAllowedModels() *APIKeyUpsert {
+	u.SetExcluded(apikey.FieldAllowedModels)
+	return u
+}
+
+// ClearAllowedModels clears the value of the "allowed_models" field.
+func (u *APIKeyUpsert) ClearAllowedModels() *APIKeyUpsert {
+	u.SetNull(apikey.FieldAllowedModels)
+	return u
+}
+
+// SetClientRestriction sets the "client_restriction" field.
+func (u *APIKeyUpsert) SetClientRestriction(v string) *APIKeyUpsert {
+	u.Set(apikey.FieldClientRestriction, v)
+	return u
+}
+
+// UpdateClientRestriction sets the "client_restriction" field to the value that was provided on create.
+func (u *APIKeyUpsert) UpdateClientRestriction() *APIKeyUpsert {
+	u.SetExcluded(apikey.FieldClientRestriction)
+	return u
+}
+
+// SetTags sets the "tags" field.
+func (u *APIKeyUpsert) SetTags(v []string) *APIKeyUpsert {
+	u.Set(apikey.FieldTags, v)
+	return u
+}
+
+// UpdateTags sets the "tags" field to the value that was provided on create.
+func (u *APIKeyUpsert) UpdateTags() *APIKeyUpsert {
+	u.SetExcluded(apikey.FieldTags)
+	return u
+}
+
+// ClearTags clears the value of the "tags" field.
+func (u *APIKeyUpsert) ClearTags() *APIKeyUpsert {
+	u.SetNull(apikey.FieldTags)
+	return u
+}
+
+// SetDegradedFallbackModel sets the "degraded_fallback_model" field.
+func (u *APIKeyUpsert) SetDegradedFallbackModel(v string) *APIKeyUpsert {
+	u.Set(apikey.FieldDegradedFallbackModel, v)
+	return u
+}
+
+// UpdateDegradedFallbackModel sets the "degraded_fallback_model" field to the value that was provided on create.
+func (u *APIKeyUpsert) UpdateDegradedFallbackModel() *APIKeyUpsert {
+	u.SetExcluded(apikey.FieldDegradedFallbackModel)
+	return u
+}
+
+// SetSystemPrompt sets the "system_prompt" field.
+func (u *APIKeyUpsert) SetSystemPrompt(v string) *APIKeyUpsert {
+	u.Set(apikey.FieldSystemPrompt, v)
+	return u
+}
+
+// UpdateSystemPrompt sets the "system_prompt" field to the value that was provided on create.
+func (u *APIKeyUpsert) UpdateSystemPrompt() *APIKeyUpsert {
+	u.SetExcluded(apikey.FieldSystemPrompt)
+	return u
+}
+
+// SetSystemPromptMode sets the "system_prompt_mode" field.
+func (u *APIKeyUpsert) SetSystemPromptMode(v string) *APIKeyUpsert {
+	u.Set(apikey.FieldSystemPromptMode, v)
+	return u
+}
+
+// UpdateSystemPromptMode sets the "system_prompt_mode" field to the value that was provided on create.
+func (u *APIKeyUpsert) UpdateSystemPromptMode() *APIKeyUpsert {
+	u.SetExcluded(apikey.FieldSystemPromptMode)
+	return u
+}
+
+// SetModerationMode sets the "moderation_mode" field.
+func (u *APIKeyUpsert) SetModerationMode(v string) *APIKeyUpsert {
+	u.Set(apikey.FieldModerationMode, v)
+	return u
+}
+
+// UpdateModerationMode sets the "moderation_mode" field to the value that was provided on create.
+func (u *APIKeyUpsert) UpdateModerationMode() *APIKeyUpsert {
+	u.SetExcluded(apikey.FieldModerationMode)
+	return u
+}
+
+// SetLogContent sets the "log_content" field.
+func (u *APIKeyUpsert) SetLogContent(v bool) *APIKeyUpsert {
+	u.Set(apikey.FieldLogContent, v)
+	return u
+}
+
+// UpdateLogContent sets the "log_content" field to the value that was provided on create.
+func (u *APIKeyUpsert) UpdateLogContent() *APIKeyUpsert {
+	u.SetExcluded(apikey.FieldLogContent)
+	return u
+}
+
+// SetSimulate sets the "simulate" field.
+func (u *APIKeyUpsert) SetSimulate(v bool) *APIKeyUpsert {
+	u.Set(apikey.FieldSimulate, v)
+	return u
+}
+
+// UpdateSimulate sets the "simulate" field to the value that was provided on create.
+func (u *APIKeyUpsert) UpdateSimulate() *APIKeyUpsert {
+	u.SetExcluded(apikey.FieldSimulate)
+	return u
+}
+
 // SetQuota sets the "quota" field.
 func (u *APIKeyUpsert) SetQuota(v float64) *APIKeyUpsert {
 	u.Set(apikey.FieldQuota, v)
@@ -883,6 +1730,54 @@ func (u *APIKeyUpsert) AddQuotaUsed(v float64) *APIKeyUpsert {
 	return u
 }
 
+// SetImageQuota sets the "image_quota" field.
+func (u *APIKeyUpsert) SetImageQuota(v int) *APIKeyUpsert {
+	u.Set(apikey.FieldImageQuota, v)
+	return u
+}
+
+// UpdateImageQuota sets the "image_quota" field to the value that was provided on create.
+func (u *APIKeyUpsert) UpdateImageQuota() *APIKeyUpsert {
+	u.SetExcluded(apikey.FieldImageQuota)
+	return u
+}
+
+// AddImageQuota adds v to the "image_quota" field.
+func (u *APIKeyUpsert) AddImageQuota(v int) *APIKeyUpsert {
+	u.Add(apikey.FieldImageQuota, v)
+	return u
+}
+
+// SetImageQuotaUsed sets the "image_quota_used" field.
+func (u *APIKeyUpsert) SetImageQuotaUsed(v int) *APIKeyUpsert {
+	u.Set(apikey.FieldImageQuotaUsed, v)
+	return u
+}
+
+// UpdateImageQuotaUsed sets the "image_quota_used" field to the value that was provided on create.
+func (u *APIKeyUpsert) UpdateImageQuotaUsed() *APIKeyUpsert {
+	u.SetExcluded(apikey.FieldImageQuotaUsed)
+	return u
+}
+
+// AddImageQuotaUsed adds v to the "image_quota_used" field.
+func (u *APIKeyUpsert) AddImageQuotaUsed(v int) *APIKeyUpsert {
+	u.Add(apikey.FieldImageQuotaUsed, v)
+	return u
+}
+
+// SetSuppressReasoning sets the "suppress_reasoning" field.
+func (u *APIKeyUpsert) SetSuppressReasoning(v bool) *APIKeyUpsert {
+	u.Set(apikey.FieldSuppressReasoning, v)
+	return u
+}
+
+// UpdateSuppressReasoning sets the "suppress_reasoning" field to the value that was provided on create.
+func (u *APIKeyUpsert) UpdateSuppressReasoning() *APIKeyUpsert {
+	u.SetExcluded(apikey.FieldSuppressReasoning)
+	return u
+}
+
 // SetExpiresAt sets the "expires_at" field.
 func (u *APIKeyUpsert) SetExpiresAt(v time.Time) *APIKeyUpsert {
 	u.Set(apikey.FieldExpiresAt, v)
@@ -1063,105 +1958,357 @@ func (u *APIKeyUpsert) ClearWindow7dStart() *APIKeyUpsert {
 	return u
 }
 
-// UpdateNewValues updates the mutable fields using the new values that were set on create.
-// Using this option is equivalent to using:
-//
-//	client.APIKey.Create().
-//		OnConflict(
-//			sql.ResolveWithNewValues(),
-//		).
-//		Exec(ctx)
-func (u *APIKeyUpsertOne) UpdateNewValues() *APIKeyUpsertOne {
-	u.create.conflict = append(u.create.conflict, sql.ResolveWithNewValues())
-	u.create.conflict = append(u.create.conflict, sql.ResolveWith(func(s *sql.UpdateSet) {
-		if _, exists := u.create.mutation.CreatedAt(); exists {
-			s.SetIgnore(apikey.FieldCreatedAt)
-		}
-	}))
+// SetRpmLimit sets the "rpm_limit" field.
+func (u *APIKeyUpsert) SetRpmLimit(v int) *APIKeyUpsert {
+	u.Set(apikey.FieldRpmLimit, v)
 	return u
 }
 
-// Ignore sets each column to itself in case of conflict.
-// Using this option is equivalent to using:
-//
-//	client.APIKey.Create().
-//	    OnConflict(sql.ResolveWithIgnore()).
-//	    Exec(ctx)
-func (u *APIKeyUpsertOne) Ignore() *APIKeyUpsertOne {
-	u.create.conflict = append(u.create.conflict, sql.ResolveWithIgnore())
+// UpdateRpmLimit sets the "rpm_limit" field to the value that was provided on create.
+func (u *APIKeyUpsert) UpdateRpmLimit() *APIKeyUpsert {
+	u.SetExcluded(apikey.FieldRpmLimit)
 	return u
 }
 
-// DoNothing configures the conflict_action to `DO NOTHING`.
-// Supported only by SQLite and PostgreSQL.
-func (u *APIKeyUpsertOne) DoNothing() *APIKeyUpsertOne {
-	u.create.conflict = append(u.create.conflict, sql.DoNothing())
+// AddRpmLimit adds v to the "rpm_limit" field.
+func (u *APIKeyUpsert) AddRpmLimit(v int) *APIKeyUpsert {
+	u.Add(apikey.FieldRpmLimit, v)
 	return u
 }
 
-// Update allows overriding fields `UPDATE` values. See the APIKeyCreate.OnConflict
-// documentation for more info.
-func (u *APIKeyUpsertOne) Update(set func(*APIKeyUpsert)) *APIKeyUpsertOne {
-	u.create.conflict = append(u.create.conflict, sql.ResolveWith(func(update *sql.UpdateSet) {
-		set(&APIKeyUpsert{UpdateSet: update})
-	}))
+// SetTpmLimit sets the "tpm_limit" field.
+func (u *APIKeyUpsert) SetTpmLimit(v int) *APIKeyUpsert {
+	u.Set(apikey.FieldTpmLimit, v)
 	return u
 }
 
-// SetUpdatedAt sets the "updated_at" field.
-func (u *APIKeyUpsertOne) SetUpdatedAt(v time.Time) *APIKeyUpsertOne {
-	return u.Update(func(s *APIKeyUpsert) {
-		s.SetUpdatedAt(v)
-	})
+// UpdateTpmLimit sets the "tpm_limit" field to the value that was provided on create.
+func (u *APIKeyUpsert) UpdateTpmLimit() *APIKeyUpsert {
+	u.SetExcluded(apikey.FieldTpmLimit)
+	return u
 }
 
-// UpdateUpdatedAt sets the "updated_at" field to the value that was provided on create.
-func (u *APIKeyUpsertOne) UpdateUpdatedAt() *APIKeyUpsertOne {
-	return u.Update(func(s *APIKeyUpsert) {
-		s.UpdateUpdatedAt()
-	})
+// AddTpmLimit adds v to the "tpm_limit" field.
+func (u *APIKeyUpsert) AddTpmLimit(v int) *APIKeyUpsert {
+	u.Add(apikey.FieldTpmLimit, v)
+	return u
 }
 
-// SetDeletedAt sets the "deleted_at" field.
-func (u *APIKeyUpsertOne) SetDeletedAt(v time.Time) *APIKeyUpsertOne {
-	return u.Update(func(s *APIKeyUpsert) {
-		s.SetDeletedAt(v)
-	})
+// SetDailyRequestLimit sets the "daily_request_limit" field.
+func (u *APIKeyUpsert) SetDailyRequestLimit(v int) *APIKeyUpsert {
+	u.Set(apikey.FieldDailyRequestLimit, v)
+	return u
 }
 
-// UpdateDeletedAt sets the "deleted_at" field to the value that was provided on create.
-func (u *APIKeyUpsertOne) UpdateDeletedAt() *APIKeyUpsertOne {
-	return u.Update(func(s *APIKeyUpsert) {
-		s.UpdateDeletedAt()
-	})
+// UpdateDailyRequestLimit sets the "daily_request_limit" field to the value that was provided on create.
+func (u *APIKeyUpsert) UpdateDailyRequestLimit() *APIKeyUpsert {
+	u.SetExcluded(apikey.FieldDailyRequestLimit)
+	return u
 }
 
-// ClearDeletedAt clears the value of the "deleted_at" field.
-func (u *APIKeyUpsertOne) ClearDeletedAt() *APIKeyUpsertOne {
-	return u.Update(func(s *APIKeyUpsert) {
-		s.ClearDeletedAt()
-	})
+// AddDailyRequestLimit adds v to the "daily_request_limit" field.
+func (u *APIKeyUpsert) AddDailyRequestLimit(v int) *APIKeyUpsert {
+	u.Add(apikey.FieldDailyRequestLimit, v)
+	return u
 }
 
-// SetUserID sets the "user_id" field.
-func (u *APIKeyUpsertOne) SetUserID(v int64) *APIKeyUpsertOne {
-	return u.Update(func(s *APIKeyUpsert) {
-		s.SetUserID(v)
-	})
+// SetDailyTokenLimit sets the "daily_token_limit" field.
+func (u *APIKeyUpsert) SetDailyTokenLimit(v int64) *APIKeyUpsert {
+	u.Set(apikey.FieldDailyTokenLimit, v)
+	return u
 }
 
-// UpdateUserID sets the "user_id" field to the value that was provided on create.
-func (u *APIKeyUpsertOne) UpdateUserID() *APIKeyUpsertOne {
-	return u.Update(func(s *APIKeyUpsert) {
-		s.UpdateUserID()
-	})
+// UpdateDailyTokenLimit sets the "daily_token_limit" field to the value that was provided on create.
+func (u *APIKeyUpsert) UpdateDailyTokenLimit() *APIKeyUpsert {
+	u.SetExcluded(apikey.FieldDailyTokenLimit)
+	return u
 }
 
-// SetKey sets the "key" field.
-func (u *APIKeyUpsertOne) SetKey(v string) *APIKeyUpsertOne {
-	return u.Update(func(s *APIKeyUpsert) {
-		s.SetKey(v)
-	})
+// AddDailyTokenLimit adds v to the "daily_token_limit" field.
+func (u *APIKeyUpsert) AddDailyTokenLimit(v int64) *APIKeyUpsert {
+	u.Add(apikey.FieldDailyTokenLimit, v)
+	return u
+}
+
+// SetMonthlyRequestLimit sets the "monthly_request_limit" field.
+func (u *APIKeyUpsert) SetMonthlyRequestLimit(v int) *APIKeyUpsert {
+	u.Set(apikey.FieldMonthlyRequestLimit, v)
+	return u
+}
+
+// UpdateMonthlyRequestLimit sets the "monthly_request_limit" field to the value that was provided on create.
+func (u *APIKeyUpsert) UpdateMonthlyRequestLimit() *APIKeyUpsert {
+	u.SetExcluded(apikey.FieldMonthlyRequestLimit)
+	return u
+}
+
+// AddMonthlyRequestLimit adds v to the "monthly_request_limit" field.
+func (u *APIKeyUpsert) AddMonthlyRequestLimit(v int) *APIKeyUpsert {
+	u.Add(apikey.FieldMonthlyRequestLimit, v)
+	return u
+}
+
+// SetMonthlyTokenLimit sets the "monthly_token_limit" field.
+func (u *APIKeyUpsert) SetMonthlyTokenLimit(v int64) *APIKeyUpsert {
+	u.Set(apikey.FieldMonthlyTokenLimit, v)
+	return u
+}
+
+// UpdateMonthlyTokenLimit sets the "monthly_token_limit" field to the value that was provided on create.
+func (u *APIKeyUpsert) UpdateMonthlyTokenLimit() *APIKeyUpsert {
+	u.SetExcluded(apikey.FieldMonthlyTokenLimit)
+	return u
+}
+
+// AddMonthlyTokenLimit adds v to the "monthly_token_limit" field.
+func (u *APIKeyUpsert) AddMonthlyTokenLimit(v int64) *APIKeyUpsert {
+	u.Add(apikey.FieldMonthlyTokenLimit, v)
+	return u
+}
+
+// SetBudgetAmount sets the "budget_amount" field.
+func (u *APIKeyUpsert) SetBudgetAmount(v float64) *APIKeyUpsert {
+	u.Set(apikey.FieldBudgetAmount, v)
+	return u
+}
+
+// UpdateBudgetAmount sets the "budget_amount" field to the value that was provided on create.
+func (u *APIKeyUpsert) UpdateBudgetAmount() *APIKeyUpsert {
+	u.SetExcluded(apikey.FieldBudgetAmount)
+	return u
+}
+
+// AddBudgetAmount adds v to the "budget_amount" field.
+func (u *APIKeyUpsert) AddBudgetAmount(v float64) *APIKeyUpsert {
+	u.Add(apikey.FieldBudgetAmount, v)
+	return u
+}
+
+// SetBudgetPeriod sets the "budget_period" field.
+func (u *APIKeyUpsert) SetBudgetPeriod(v string) *APIKeyUpsert {
+	u.Set(apikey.FieldBudgetPeriod, v)
+	return u
+}
+
+// UpdateBudgetPeriod sets the "budget_period" field to the value that was provided on create.
+func (u *APIKeyUpsert) UpdateBudgetPeriod() *APIKeyUpsert {
+	u.SetExcluded(apikey.FieldBudgetPeriod)
+	return u
+}
+
+// SetBudgetAction sets the "budget_action" field.
+func (u *APIKeyUpsert) SetBudgetAction(v string) *APIKeyUpsert {
+	u.Set(apikey.FieldBudgetAction, v)
+	return u
+}
+
+// UpdateBudgetAction sets the "budget_action" field to the value that was provided on create.
+func (u *APIKeyUpsert) UpdateBudgetAction() *APIKeyUpsert {
+	u.SetExcluded(apikey.FieldBudgetAction)
+	return u
+}
+
+// SetBudgetFallbackModel sets the "budget_fallback_model" field.
+func (u *APIKeyUpsert) SetBudgetFallbackModel(v string) *APIKeyUpsert {
+	u.Set(apikey.FieldBudgetFallbackModel, v)
+	return u
+}
+
+// UpdateBudgetFallbackModel sets the "budget_fallback_model" field to the value that was provided on create.
+func (u *APIKeyUpsert) UpdateBudgetFallbackModel() *APIKeyUpsert {
+	u.SetExcluded(apikey.FieldBudgetFallbackModel)
+	return u
+}
+
+// SetBudgetUsed sets the "budget_used" field.
+func (u *APIKeyUpsert) SetBudgetUsed(v float64) *APIKeyUpsert {
+	u.Set(apikey.FieldBudgetUsed, v)
+	return u
+}
+
+// UpdateBudgetUsed sets the "budget_used" field to the value that was provided on create.
+func (u *APIKeyUpsert) UpdateBudgetUsed() *APIKeyUpsert {
+	u.SetExcluded(apikey.FieldBudgetUsed)
+	return u
+}
+
+// AddBudgetUsed adds v to the "budget_used" field.
+func (u *APIKeyUpsert) AddBudgetUsed(v float64) *APIKeyUpsert {
+	u.Add(apikey.FieldBudgetUsed, v)
+	return u
+}
+
+// SetBudgetPeriodStart sets the "budget_period_start" field.
+func (u *APIKeyUpsert) SetBudgetPeriodStart(v time.Time) *APIKeyUpsert {
+	u.Set(apikey.FieldBudgetPeriodStart, v)
+	return u
+}
+
+// UpdateBudgetPeriodStart sets the "budget_period_start" field to the value that was provided on create.
+func (u *APIKeyUpsert) UpdateBudgetPeriodStart() *APIKeyUpsert {
+	u.SetExcluded(apikey.FieldBudgetPeriodStart)
+	return u
+}
+
+// ClearBudgetPeriodStart clears the value of the "budget_period_start" field.
+func (u *APIKeyUpsert) ClearBudgetPeriodStart() *APIKeyUpsert {
+	u.SetNull(apikey.FieldBudgetPeriodStart)
+	return u
+}
+
+// SetBudgetExceededAt sets the "budget_exceeded_at" field.
+func (u *APIKeyUpsert) SetBudgetExceededAt(v time.Time) *APIKeyUpsert {
+	u.Set(apikey.FieldBudgetExceededAt, v)
+	return u
+}
+
+// UpdateBudgetExceededAt sets the "budget_exceeded_at" field to the value that was provided on create.
+func (u *APIKeyUpsert) UpdateBudgetExceededAt() *APIKeyUpsert {
+	u.SetExcluded(apikey.FieldBudgetExceededAt)
+	return u
+}
+
+// ClearBudgetExceededAt clears the value of the "budget_exceeded_at" field.
+func (u *APIKeyUpsert) ClearBudgetExceededAt() *APIKeyUpsert {
+	u.SetNull(apikey.FieldBudgetExceededAt)
+	return u
+}
+
+// SetPreviousKey sets the "previous_key" field.
+func (u *APIKeyUpsert) SetPreviousKey(v string) *APIKeyUpsert {
+	u.Set(apikey.FieldPreviousKey, v)
+	return u
+}
+
+// UpdatePreviousKey sets the "previous_key" field to the value that was provided on create.
+func (u *APIKeyUpsert) UpdatePreviousKey() *APIKeyUpsert {
+	u.SetExcluded(apikey.FieldPreviousKey)
+	return u
+}
+
+// ClearPreviousKey clears the value of the "previous_key" field.
+func (u *APIKeyUpsert) ClearPreviousKey() *APIKeyUpsert {
+	u.SetNull(apikey.FieldPreviousKey)
+	return u
+}
+
+// SetPreviousKeyExpiresAt sets the "previous_key_expires_at" field.
+func (u *APIKeyUpsert) SetPreviousKeyExpiresAt(v time.Time) *APIKeyUpsert {
+	u.Set(apikey.FieldPreviousKeyExpiresAt, v)
+	return u
+}
+
+// UpdatePreviousKeyExpiresAt sets the "previous_key_expires_at" field to the value that was provided on create.
+func (u *APIKeyUpsert) UpdatePreviousKeyExpiresAt() *APIKeyUpsert {
+	u.SetExcluded(apikey.FieldPreviousKeyExpiresAt)
+	return u
+}
+
+// ClearPreviousKeyExpiresAt clears the value of the "previous_key_expires_at" field.
+func (u *APIKeyUpsert) ClearPreviousKeyExpiresAt() *APIKeyUpsert {
+	u.SetNull(apikey.FieldPreviousKeyExpiresAt)
+	return u
+}
+
+// UpdateNewValues updates the mutable fields using the new values that were set on create.
+// Using this option is equivalent to using:
+//
+//	client.APIKey.Create().
+//		OnConflict(
+//			sql.ResolveWithNewValues(),
+//		).
+//		Exec(ctx)
+func (u *APIKeyUpsertOne) UpdateNewValues() *APIKeyUpsertOne {
+	u.create.conflict = append(u.create.conflict, sql.ResolveWithNewValues())
+	u.create.conflict = append(u.create.conflict, sql.ResolveWith(func(s *sql.UpdateSet) {
+		if _, exists := u.create.mutation.CreatedAt(); exists {
+			s.SetIgnore(apikey.FieldCreatedAt)
+		}
+	}))
+	return u
+}
+
+// Ignore sets each column to itself in case of conflict.
+// Using this option is equivalent to using:
+//
+//	client.APIKey.Create().
+//	    OnConflict(sql.ResolveWithIgnore()).
+//	    Exec(ctx)
+func (u *APIKeyUpsertOne) Ignore() *APIKeyUpsertOne {
+	u.create.conflict = append(u.create.conflict, sql.ResolveWithIgnore())
+	return u
+}
+
+// DoNothing configures the conflict_action to `DO NOTHING`.
+// Supported only by SQLite and PostgreSQL.
+func (u *APIKeyUpsertOne) DoNothing() *APIKeyUpsertOne {
+	u.create.conflict = append(u.create.conflict, sql.DoNothing())
+	return u
+}
+
+// Update allows overriding fields `UPDATE` values. See the APIKeyCreate.OnConflict
+// documentation for more info.
+func (u *APIKeyUpsertOne) Update(set func(*APIKeyUpsert)) *APIKeyUpsertOne {
+	u.create.conflict = append(u.create.conflict, sql.ResolveWith(func(update *sql.UpdateSet) {
+		set(&APIKeyUpsert{UpdateSet: update})
+	}))
+	return u
+}
+
+// SetUpdatedAt sets the "updated_at" field.
+func (u *APIKeyUpsertOne) SetUpdatedAt(v time.Time) *APIKeyUpsertOne {
+	return u.Update(func(s *APIKeyUpsert) {
+		s.SetUpdatedAt(v)
+	})
+}
+
+// UpdateUpdatedAt sets the "updated_at" field to the value that was provided on create.
+func (u *APIKeyUpsertOne) UpdateUpdatedAt() *APIKeyUpsertOne {
+	return u.Update(func(s *APIKeyUpsert) {
+		s.UpdateUpdatedAt()
+	})
+}
+
+// SetDeletedAt sets the "deleted_at" field.
+func (u *APIKeyUpsertOne) SetDeletedAt(v time.Time) *APIKeyUpsertOne {
+	return u.Update(func(s *APIKeyUpsert) {
+		s.SetDeletedAt(v)
+	})
+}
+
+// UpdateDeletedAt sets the "deleted_at" field to the value that was provided on create.
+func (u *APIKeyUpsertOne) UpdateDeletedAt() *APIKeyUpsertOne {
+	return u.Update(func(s *APIKeyUpsert) {
+		s.UpdateDeletedAt()
+	})
+}
+
+// ClearDeletedAt clears the value of the "deleted_at" field.
+func (u *APIKeyUpsertOne) ClearDeletedAt() *APIKeyUpsertOne {
+	return u.Update(func(s *APIKeyUpsert) {
+		s.ClearDeletedAt()
+	})
+}
+
+// SetUserID sets the "user_id" field.
+func (u *APIKeyUpsertOne) SetUserID(v int64) *APIKeyUpsertOne {
+	return u.Update(func(s *APIKeyUpsert) {
+		s.SetUserID(v)
+	})
+}
+
+// UpdateUserID sets the "user_id" field to the value that was provided on create.
+func (u *APIKeyUpsertOne) UpdateUserID() *APIKeyUpsertOne {
+	return u.Update(func(s *APIKeyUpsert) {
+		s.UpdateUserID()
+	})
+}
+
+// SetKey sets the "key" field.
+func (u *APIKeyUpsertOne) SetKey(v string) *APIKeyUpsertOne {
+	return u.Update(func(s *APIKeyUpsert) {
+		s.SetKey(v)
+	})
 }
 
 // UpdateKey sets the "key" field to the value that was provided on create.
@@ -1185,6 +2332,20 @@ func (u *APIKeyUpsertOne) UpdateName() *APIKeyUpsertOne {
 	})
 }
 
+// SetDescription sets the "description" field.
+func (u *APIKeyUpsertOne) SetDescription(v string) *APIKeyUpsertOne {
+	return u.Update(func(s *APIKeyUpsert) {
+		s.SetDescription(v)
+	})
+}
+
+// UpdateDescription sets the "description" field to the value that was provided on create.
+func (u *APIKeyUpsertOne) UpdateDescription() *APIKeyUpsertOne {
+	return u.Update(func(s *APIKeyUpsert) {
+		s.UpdateDescription()
+	})
+}
+
 // SetGroupID sets the "group_id" field.
 func (u *APIKeyUpsertOne) SetGroupID(v int64) *APIKeyUpsertOne {
 	return u.Update(func(s *APIKeyUpsert) {
@@ -1283,255 +2444,766 @@ func (u *APIKeyUpsertOne) ClearIPBlacklist() *APIKeyUpsertOne {
 	})
 }
 
-// SetQuota sets the "quota" field.
-func (u *APIKeyUpsertOne) SetQuota(v float64) *APIKeyUpsertOne {
+// SetScopes sets the "scopes" field.
+func (u *APIKeyUpsertOne) SetScopes(v []string) *APIKeyUpsertOne {
 	return u.Update(func(s *APIKeyUpsert) {
-		s.SetQuota(v)
+		s.SetScopes(v)
 	})
 }
 
-// AddQuota adds v to the "quota" field.
-func (u *APIKeyUpsertOne) AddQuota(v float64) *APIKeyUpsertOne {
+// UpdateScopes sets the "scopes" field to the value that was provided on create.
+func (u *APIKeyUpsertOne) UpdateScopes() *APIKeyUpsertOne {
 	return u.Update(func(s *APIKeyUpsert) {
-		s.AddQuota(v)
+		s.UpdateScopes()
+	})
+}
+
+// ClearScopes clears the value of the "scopes" field.
+func (u *APIKeyUpsertOne) ClearScopes() *APIKeyUpsertOne {
+	return u.Update(func(s *APIKeyUpsert) {
+		s.ClearScopes()
+	})
+}
+
+// SetAllowedModels sets the "allowed_models" field.
+func (u *APIKeyUpsertOne) SetAllowedModels(v []string) *APIKeyUpsertOne {
+	return u.Update(func(s *APIKeyUpsert) {
+		s.SetAllowedModels(v)
+	})
+}
+
+// UpdateAllowedModels sets the "allowed_models" field to the value that was provided on create.
+func (u *APIKeyUpsertOne) UpdateAllowedModels() *APIKeyUpsertOne {
+	return u.Update(func(s *APIKeyUpsert) {
+		s.UpdateAllowedModels()
+	})
+}
+
+// ClearAllowedModels clears the value of the "allowed_models" field.
+func (u *APIKeyUpsertOne) ClearAllowedModels() *APIKeyUpsertOne {
+	return u.Update(func(s *APIKeyUpsert) {
+		s.ClearAllowedModels()
+	})
+}
+
+// SetClientRestriction sets the "client_restriction" field.
+func (u *APIKeyUpsertOne) SetClientRestriction(v string) *APIKeyUpsertOne {
+	return u.Update(func(s *APIKeyUpsert) {
+		s.SetClientRestriction(v)
+	})
+}
+
+// UpdateClientRestriction sets the "client_restriction" field to the value that was provided on create.
+func (u *APIKeyUpsertOne) UpdateClientRestriction() *APIKeyUpsertOne {
+	return u.Update(func(s *APIKeyUpsert) {
+		s.UpdateClientRestriction()
+	})
+}
+
+// SetTags sets the "tags" field.
+func (u *APIKeyUpsertOne) SetTags(v []string) *APIKeyUpsertOne {
+	return u.Update(func(s *APIKeyUpsert) {
+		s.SetTags(v)
+	})
+}
+
+// UpdateTags sets the "tags" field to the value that was provided on create.
+func (u *APIKeyUpsertOne) UpdateTags() *APIKeyUpsertOne {
+	return u.Update(func(s *APIKeyUpsert) {
+		s.UpdateTags()
+	})
+}
+
+// ClearTags clears the value of the "tags" field.
+func (u *APIKeyUpsertOne) ClearTags() *APIKeyUpsertOne {
+	return u.Update(func(s *APIKeyUpsert) {
+		s.ClearTags()
+	})
+}
+
+// SetDegradedFallbackModel sets the "degraded_fallback_model" field.
+func (u *APIKeyUpsertOne) SetDegradedFallbackModel(v string) *APIKeyUpsertOne {
+	return u.Update(func(s *APIKeyUpsert) {
+		s.SetDegradedFallbackModel(v)
+	})
+}
+
+// UpdateDegradedFallbackModel sets the "degraded_fallback_model" field to the value that was provided on create.
+func (u *APIKeyUpsertOne) UpdateDegradedFallbackModel() *APIKeyUpsertOne {
+	return u.Update(func(s *APIKeyUpsert) {
+		s.UpdateDegradedFallbackModel()
+	})
+}
+
+// SetSystemPrompt sets the "system_prompt" field.
+func (u *APIKeyUpsertOne) SetSystemPrompt(v string) *APIKeyUpsertOne {
+	return u.Update(func(s *APIKeyUpsert) {
+		s.SetSystemPrompt(v)
+	})
+}
+
+// UpdateSystemPrompt sets the "system_prompt" field to the value that was provided on create.
+func (u *APIKeyUpsertOne) UpdateSystemPrompt() *APIKeyUpsertOne {
+	return u.Update(func(s *APIKeyUpsert) {
+		s.UpdateSystemPrompt()
+	})
+}
+
+// SetSystemPromptMode sets the "system_prompt_mode" field.
+func (u *APIKeyUpsertOne) SetSystemPromptMode(v string) *APIKeyUpsertOne {
+	return u.Update(func(s *APIKeyUpsert) {
+		s.SetSystemPromptMode(v)
+	})
+}
+
+// UpdateSystemPromptMode sets the "system_prompt_mode" field to the value that was provided on create.
+func (u *APIKeyUpsertOne) UpdateSystemPromptMode() *APIKeyUpsertOne {
+	return u.Update(func(s *APIKeyUpsert) {
+		s.UpdateSystemPromptMode()
+	})
+}
+
+// SetModerationMode sets the "moderation_mode" field.
+func (u *APIKeyUpsertOne) SetModerationMode(v string) *APIKeyUpsertOne {
+	return u.Update(func(s *APIKeyUpsert) {
+		s.SetModerationMode(v)
+	})
+}
+
+// UpdateModerationMode sets the "moderation_mode" field to the value that was provided on create.
+func (u *APIKeyUpsertOne) UpdateModerationMode() *APIKeyUpsertOne {
+	return u.Update(func(s *APIKeyUpsert) {
+		s.UpdateModerationMode()
+	})
+}
+
+// SetLogContent sets the "log_content" field.
+func (u *APIKeyUpsertOne) SetLogContent(v bool) *APIKeyUpsertOne {
+	return u.Update(func(s *APIKeyUpsert) {
+		s.SetLogContent(v)
+	})
+}
+
+// UpdateLogContent sets the "log_content" field to the value that was provided on create.
+func (u *APIKeyUpsertOne) UpdateLogContent() *APIKeyUpsertOne {
+	return u.Update(func(s *APIKeyUpsert) {
+		s.UpdateLogContent()
+	})
+}
+
+// SetSimulate sets the "simulate" field.
+func (u *APIKeyUpsertOne) SetSimulate(v bool) *APIKeyUpsertOne {
+	return u.Update(func(s *APIKeyUpsert) {
+		s.SetSimulate(v)
+	})
+}
+
+// UpdateSimulate sets the "simulate" field to the value that was provided on create.
+func (u *APIKeyUpsertOne) UpdateSimulate() *APIKeyUpsertOne {
+	return u.Update(func(s *APIKeyUpsert) {
+		s.UpdateSimulate()
+	})
+}
+
+// SetQuota sets the "quota" field.
+func (u *APIKeyUpsertOne) SetQuota(v float64) *APIKeyUpsertOne {
+	return u.Update(func(s *APIKeyUpsert) {
+		s.SetQuota(v)
+	})
+}
+
+// AddQuota adds v to the "quota" field.
+func (u *APIKeyUpsertOne) AddQuota(v float64) *APIKeyUpsertOne {
+	return u.Update(func(s *APIKeyUpsert) {
+		s.AddQuota(v)
+	})
+}
+
+// UpdateQuota sets the "quota" field to the value that was provided on create.
+func (u *APIKeyUpsertOne) UpdateQuota() *APIKeyUpsertOne {
+	return u.Update(func(s *APIKeyUpsert) {
+		s.UpdateQuota()
+	})
+}
+
+// SetQuotaUsed sets the "quota_used" field.
+func (u *APIKeyUpsertOne) SetQuotaUsed(v float64) *APIKeyUpsertOne {
+	return u.Update(func(s *APIKeyUpsert) {
+		s.SetQuotaUsed(v)
+	})
+}
+
+// AddQuotaUsed adds v to the "quota_used" field.
+func (u *APIKeyUpsertOne) AddQuotaUsed(v float64) *APIKeyUpsertOne {
+	return u.Update(func(s *APIKeyUpsert) {
+		s.AddQuotaUsed(v)
+	})
+}
+
+// UpdateQuotaUsed sets the "quota_used" field to the value that was provided on create.
+func (u *APIKeyUpsertOne) UpdateQuotaUsed() *APIKeyUpsertOne {
+	return u.Update(func(s *APIKeyUpsert) {
+		s.UpdateQuotaUsed()
+	})
+}
+
+// SetImageQuota sets the "image_quota" field.
+func (u *APIKeyUpsertOne) SetImageQuota(v int) *APIKeyUpsertOne {
+	return u.Update(func(s *APIKeyUpsert) {
+		s.SetImageQuota(v)
+	})
+}
+
+// AddImageQuota adds v to the "image_quota" field.
+func (u *APIKeyUpsertOne) AddImageQuota(v int) *APIKeyUpsertOne {
+	return u.Update(func(s *APIKeyUpsert) {
+		s.AddImageQuota(v)
+	})
+}
+
+// UpdateImageQuota sets the "image_quota" field to the value that was provided on create.
+func (u *APIKeyUpsertOne) UpdateImageQuota() *APIKeyUpsertOne {
+	return u.Update(func(s *APIKeyUpsert) {
+		s.UpdateImageQuota()
+	})
+}
+
+// SetImageQuotaUsed sets the "image_quota_used" field.
+func (u *APIKeyUpsertOne) SetImageQuotaUsed(v int) *APIKeyUpsertOne {
+	return u.Update(func(s *APIKeyUpsert) {
+		s.SetImageQuotaUsed(v)
+	})
+}
+
+// AddImageQuotaUsed adds v to the "image_quota_used" field.
+func (u *APIKeyUpsertOne) AddImageQuotaUsed(v int) *APIKeyUpsertOne {
+	return u.Update(func(s *APIKeyUpsert) {
+		s.AddImageQuotaUsed(v)
+	})
+}
+
+// UpdateImageQuotaUsed sets the "image_quota_used" field to the value that was provided on create.
+func (u *APIKeyUpsertOne) UpdateImageQuotaUsed() *APIKeyUpsertOne {
+	return u.Update(func(s *APIKeyUpsert) {
+		s.UpdateImageQuotaUsed()
+	})
+}
+
+// SetSuppressReasoning sets the "suppress_reasoning" field.
+func (u *APIKeyUpsertOne) SetSuppressReasoning(v bool) *APIKeyUpsertOne {
+	return u.Update(func(s *APIKeyUpsert) {
+		s.SetSuppressReasoning(v)
+	})
+}
+
+// UpdateSuppressReasoning sets the "suppress_reasoning" field to the value that was provided on create.
+func (u *APIKeyUpsertOne) UpdateSuppressReasoning() *APIKeyUpsertOne {
+	return u.Update(func(s *APIKeyUpsert) {
+		s.UpdateSuppressReasoning()
+	})
+}
+
+// SetExpiresAt sets the "expires_at" field.
+func (u *APIKeyUpsertOne) SetExpiresAt(v time.Time) *APIKeyUpsertOne {
+	return u.Update(func(s *APIKeyUpsert) {
+		s.SetExpiresAt(v)
+	})
+}
+
+// UpdateExpiresAt sets the "expires_at" field to the value that was provided on create.
+func (u *APIKeyUpsertOne) UpdateExpiresAt() *APIKeyUpsertOne {
+	return u.Update(func(s *APIKeyUpsert) {
+		s.UpdateExpiresAt()
+	})
+}
+
+// ClearExpiresAt clears the value of the "expires_at" field.
+func (u *APIKeyUpsertOne) ClearExpiresAt() *APIKeyUpsertOne {
+	return u.Update(func(s *APIKeyUpsert) {
+		s.ClearExpiresAt()
+	})
+}
+
+// SetRateLimit5h sets the "rate_limit_5h" field.
+func (u *APIKeyUpsertOne) SetRateLimit5h(v float64) *APIKeyUpsertOne {
+	return u.Update(func(s *APIKeyUpsert) {
+		s.SetRateLimit5h(v)
+	})
+}
+
+// AddRateLimit5h adds v to the "rate_limit_5h" field.
+func (u *APIKeyUpsertOne) AddRateLimit5h(v float64) *APIKeyUpsertOne {
+	return u.Update(func(s *APIKeyUpsert) {
+		s.AddRateLimit5h(v)
+	})
+}
+
+// UpdateRateLimit5h sets the "rate_limit_5h" field to the value that was provided on create.
+func (u *APIKeyUpsertOne) UpdateRateLimit5h() *APIKeyUpsertOne {
+	return u.Update(func(s *APIKeyUpsert) {
+		s.UpdateRateLimit5h()
+	})
+}
+
+// SetRateLimit1d sets the "rate_limit_1d" field.
+func (u *APIKeyUpsertOne) SetRateLimit1d(v float64) *APIKeyUpsertOne {
+	return u.Update(func(s *APIKeyUpsert) {
+		s.SetRateLimit1d(v)
+	})
+}
+
+// AddRateLimit1d adds v to the "rate_limit_1d" field.
+func (u *APIKeyUpsertOne) AddRateLimit1d(v float64) *APIKeyUpsertOne {
+	return u.Update(func(s *APIKeyUpsert) {
+		s.AddRateLimit1d(v)
+	})
+}
+
+// UpdateRateLimit1d sets the "rate_limit_1d" field to the value that was provided on create.
+func (u *APIKeyUpsertOne) UpdateRateLimit1d() *APIKeyUpsertOne {
+	return u.Update(func(s *APIKeyUpsert) {
+		s.UpdateRateLimit1d()
+	})
+}
+
+// SetRateLimit7d sets the "rate_limit_7d" field.
+func (u *APIKeyUpsertOne) SetRateLimit7d(v float64) *APIKeyUpsertOne {
+	return u.Update(func(s *APIKeyUpsert) {
+		s.SetRateLimit7d(v)
+	})
+}
+
+// AddRateLimit7d adds v to the "rate_limit_7d" field.
+func (u *APIKeyUpsertOne) AddRateLimit7d(v float64) *APIKeyUpsertOne {
+	return u.Update(func(s *APIKeyUpsert) {
+		s.AddRateLimit7d(v)
+	})
+}
+
+// UpdateRateLimit7d sets the "rate_limit_7d" field to the value that was provided on create.
+func (u *APIKeyUpsertOne) UpdateRateLimit7d() *APIKeyUpsertOne {
+	return u.Update(func(s *APIKeyUpsert) {
+		s.UpdateRateLimit7d()
+	})
+}
+
+// SetUsage5h sets the "usage_5h" field.
+func (u *APIKeyUpsertOne) SetUsage5h(v float64) *APIKeyUpsertOne {
+	return u.Update(func(s *APIKeyUpsert) {
+		s.SetUsage5h(v)
+	})
+}
+
+// AddUsage5h adds v to the "usage_5h" field.
+func (u *APIKeyUpsertOne) AddUsage5h(v float64) *APIKeyUpsertOne {
+	return u.Update(func(s *APIKeyUpsert) {
+		s.AddUsage5h(v)
+	})
+}
+
+// UpdateUsage5h sets the "usage_5h" field to the value that was provided on create.
+func (u *APIKeyUpsertOne) UpdateUsage5h() *APIKeyUpsertOne {
+	return u.Update(func(s *APIKeyUpsert) {
+		s.UpdateUsage5h()
+	})
+}
+
+// SetUsage1d sets the "usage_1d" field.
+func (u *APIKeyUpsertOne) SetUsage1d(v float64) *APIKeyUpsertOne {
+	return u.Update(func(s *APIKeyUpsert) {
+		s.SetUsage1d(v)
+	})
+}
+
+// AddUsage1d adds v to the "usage_1d" field.
+func (u *APIKeyUpsertOne) AddUsage1d(v float64) *APIKeyUpsertOne {
+	return u.Update(func(s *APIKeyUpsert) {
+		s.AddUsage1d(v)
+	})
+}
+
+// UpdateUsage1d sets the "usage_1d" field to the value that was provided on create.
+func (u *APIKeyUpsertOne) UpdateUsage1d() *APIKeyUpsertOne {
+	return u.Update(func(s *APIKeyUpsert) {
+		s.UpdateUsage1d()
+	})
+}
+
+// SetUsage7d sets the "usage_7d" field.
+func (u *APIKeyUpsertOne) SetUsage7d(v float64) *APIKeyUpsertOne {
+	return u.Update(func(s *APIKeyUpsert) {
+		s.SetUsage7d(v)
+	})
+}
+
+// AddUsage7d adds v to the "usage_7d" field.
+func (u *APIKeyUpsertOne) AddUsage7d(v float64) *APIKeyUpsertOne {
+	return u.Update(func(s *APIKeyUpsert) {
+		s.AddUsage7d(v)
+	})
+}
+
+// UpdateUsage7d sets the "usage_7d" field to the value that was provided on create.
+func (u *APIKeyUpsertOne) UpdateUsage7d() *APIKeyUpsertOne {
+	return u.Update(func(s *APIKeyUpsert) {
+		s.UpdateUsage7d()
+	})
+}
+
+// SetWindow5hStart sets the "window_5h_start" field.
+func (u *APIKeyUpsertOne) SetWindow5hStart(v time.Time) *APIKeyUpsertOne {
+	return u.Update(func(s *APIKeyUpsert) {
+		s.SetWindow5hStart(v)
+	})
+}
+
+// UpdateWindow5hStart sets the "window_5h_start" field to the value that was provided on create.
+func (u *APIKeyUpsertOne) UpdateWindow5hStart() *APIKeyUpsertOne {
+	return u.Update(func(s *APIKeyUpsert) {
+		s.UpdateWindow5hStart()
+	})
+}
+
+// ClearWindow5hStart clears the value of the "window_5h_start" field.
+func (u *APIKeyUpsertOne) ClearWindow5hStart() *APIKeyUpsertOne {
+	return u.Update(func(s *APIKeyUpsert) {
+		s.ClearWindow5hStart()
+	})
+}
+
+// SetWindow1dStart sets the "window_1d_start" field.
+func (u *APIKeyUpsertOne) SetWindow1dStart(v time.Time) *APIKeyUpsertOne {
+	return u.Update(func(s *APIKeyUpsert) {
+		s.SetWindow1dStart(v)
+	})
+}
+
+// UpdateWindow1dStart sets the "window_1d_start" field to the value that was provided on create.
+func (u *APIKeyUpsertOne) UpdateWindow1dStart() *APIKeyUpsertOne {
+	return u.Update(func(s *APIKeyUpsert) {
+		s.UpdateWindow1dStart()
+	})
+}
+
+// ClearWindow1dStart clears the value of the "window_1d_start" field.
+func (u *APIKeyUpsertOne) ClearWindow1dStart() *APIKeyUpsertOne {
+	return u.Update(func(s *APIKeyUpsert) {
+		s.ClearWindow1dStart()
+	})
+}
+
+// SetWindow7dStart sets the "window_7d_start" field.
+func (u *APIKeyUpsertOne) SetWindow7dStart(v time.Time) *APIKeyUpsertOne {
+	return u.Update(func(s *APIKeyUpsert) {
+		s.SetWindow7dStart(v)
+	})
+}
+
+// UpdateWindow7dStart sets the "window_7d_start" field to the value that was provided on create.
+func (u *APIKeyUpsertOne) UpdateWindow7dStart() *APIKeyUpsertOne {
+	return u.Update(func(s *APIKeyUpsert) {
+		s.UpdateWindow7dStart()
+	})
+}
+
+// ClearWindow7dStart clears the value of the "window_7d_start" field.
+func (u *APIKeyUpsertOne) ClearWindow7dStart() *APIKeyUpsertOne {
+	return u.Update(func(s *APIKeyUpsert) {
+		s.ClearWindow7dStart()
+	})
+}
+
+// SetRpmLimit sets the "rpm_limit" field.
+func (u *APIKeyUpsertOne) SetRpmLimit(v int) *APIKeyUpsertOne {
+	return u.Update(func(s *APIKeyUpsert) {
+		s.SetRpmLimit(v)
+	})
+}
+
+// AddRpmLimit adds v to the "rpm_limit" field.
+func (u *APIKeyUpsertOne) AddRpmLimit(v int) *APIKeyUpsertOne {
+	return u.Update(func(s *APIKeyUpsert) {
+		s.AddRpmLimit(v)
+	})
+}
+
+// UpdateRpmLimit sets the "rpm_limit" field to the value that was provided on create.
+func (u *APIKeyUpsertOne) UpdateRpmLimit() *APIKeyUpsertOne {
+	return u.Update(func(s *APIKeyUpsert) {
+		s.UpdateRpmLimit()
+	})
+}
+
+// SetTpmLimit sets the "tpm_limit" field.
+func (u *APIKeyUpsertOne) SetTpmLimit(v int) *APIKeyUpsertOne {
+	return u.Update(func(s *APIKeyUpsert) {
+		s.SetTpmLimit(v)
+	})
+}
+
+// AddTpmLimit adds v to the "tpm_limit" field.
+func (u *APIKeyUpsertOne) AddTpmLimit(v int) *APIKeyUpsertOne {
+	return u.Update(func(s *APIKeyUpsert) {
+		s.AddTpmLimit(v)
+	})
+}
+
+// UpdateTpmLimit sets the "tpm_limit" field to the value that was provided on create.
+func (u *APIKeyUpsertOne) UpdateTpmLimit() *APIKeyUpsertOne {
+	return u.Update(func(s *APIKeyUpsert) {
+		s.UpdateTpmLimit()
+	})
+}
+
+// SetDailyRequestLimit sets the "daily_request_limit" field.
+func (u *APIKeyUpsertOne) SetDailyRequestLimit(v int) *APIKeyUpsertOne {
+	return u.Update(func(s *APIKeyUpsert) {
+		s.SetDailyRequestLimit(v)
+	})
+}
+
+// AddDailyRequestLimit adds v to the "daily_request_limit" field.
+func (u *APIKeyUpsertOne) AddDailyRequestLimit(v int) *APIKeyUpsertOne {
+	return u.Update(func(s *APIKeyUpsert) {
+		s.AddDailyRequestLimit(v)
 	})
 }
 
-// UpdateQuota sets the "quota" field to the value that was provided on create.
-func (u *APIKeyUpsertOne) UpdateQuota() *APIKeyUpsertOne {
+// UpdateDailyRequestLimit sets the "daily_request_limit" field to the value that was provided on create.
+func (u *APIKeyUpsertOne) UpdateDailyRequestLimit() *APIKeyUpsertOne {
 	return u.Update(func(s *APIKeyUpsert) {
-		s.UpdateQuota()
+		s.UpdateDailyRequestLimit()
 	})
 }
 
-// SetQuotaUsed sets the "quota_used" field.
-func (u *APIKeyUpsertOne) SetQuotaUsed(v float64) *APIKeyUpsertOne {
+// SetDailyTokenLimit sets the "daily_token_limit" field.
+func (u *APIKeyUpsertOne) SetDailyTokenLimit(v int64) *APIKeyUpsertOne {
 	return u.Update(func(s *APIKeyUpsert) {
-		s.SetQuotaUsed(v)
+		s.SetDailyTokenLimit(v)
 	})
 }
 
-// AddQuotaUsed adds v to the "quota_used" field.
-func (u *APIKeyUpsertOne) AddQuotaUsed(v float64) *APIKeyUpsertOne {
+// AddDailyTokenLimit adds v to the "daily_token_limit" field.
+func (u *APIKeyUpsertOne) AddDailyTokenLimit(v int64) *APIKeyUpsertOne {
 	return u.Update(func(s *APIKeyUpsert) {
-		s.AddQuotaUsed(v)
+		s.AddDailyTokenLimit(v)
 	})
 }
 
-// UpdateQuotaUsed sets the "quota_used" field to the value that was provided on create.
-func (u *APIKeyUpsertOne) UpdateQuotaUsed() *APIKeyUpsertOne {
+// UpdateDailyTokenLimit sets the "daily_token_limit" field to the value that was provided on create.
+func (u *APIKeyUpsertOne) UpdateDailyTokenLimit() *APIKeyUpsertOne {
 	return u.Update(func(s *APIKeyUpsert) {
-		s.UpdateQuotaUsed()
+		s.UpdateDailyTokenLimit()
 	})
 }
 
-// SetExpiresAt sets the "expires_at" field.
-func (u *APIKeyUpsertOne) SetExpiresAt(v time.Time) *APIKeyUpsertOne {
+// SetMonthlyRequestLimit sets the "monthly_request_limit" field.
+func (u *APIKeyUpsertOne) SetMonthlyRequestLimit(v int) *APIKeyUpsertOne {
 	return u.Update(func(s *APIKeyUpsert) {
-		s.SetExpiresAt(v)
+		s.SetMonthlyRequestLimit(v)
 	})
 }
 
-// UpdateExpiresAt sets the "expires_at" field to the value that was provided on create.
-func (u *APIKeyUpsertOne) UpdateExpiresAt() *APIKeyUpsertOne {
+// AddMonthlyRequestLimit adds v to the "monthly_request_limit" field.
+func (u *APIKeyUpsertOne) AddMonthlyRequestLimit(v int) *APIKeyUpsertOne {
 	return u.Update(func(s *APIKeyUpsert) {
-		s.UpdateExpiresAt()
+		s.AddMonthlyRequestLimit(v)
 	})
 }
 
-// ClearExpiresAt clears the value of the "expires_at" field.
-func (u *APIKeyUpsertOne) ClearExpiresAt() *APIKeyUpsertOne {
+// UpdateMonthlyRequestLimit sets the "monthly_request_limit" field to the value that was provided on create.
+func (u *APIKeyUpsertOne) UpdateMonthlyRequestLimit() *APIKeyUpsertOne {
 	return u.Update(func(s *APIKeyUpsert) {
-		s.ClearExpiresAt()
+		s.UpdateMonthlyRequestLimit()
 	})
 }
 
-// SetRateLimit5h sets the "rate_limit_5h" field.
-func (u *APIKeyUpsertOne) SetRateLimit5h(v float64) *APIKeyUpsertOne {
+// SetMonthlyTokenLimit sets the "monthly_token_limit" field.
+func (u *APIKeyUpsertOne) SetMonthlyTokenLimit(v int64) *APIKeyUpsertOne {
 	return u.Update(func(s *APIKeyUpsert) {
-		s.SetRateLimit5h(v)
+		s.SetMonthlyTokenLimit(v)
 	})
 }
 
-// AddRateLimit5h adds v to the "rate_limit_5h" field.
-func (u *APIKeyUpsertOne) AddRateLimit5h(v float64) *APIKeyUpsertOne {
+// AddMonthlyTokenLimit adds v to the "monthly_token_limit" field.
+func (u *APIKeyUpsertOne) AddMonthlyTokenLimit(v int64) *APIKeyUpsertOne {
 	return u.Update(func(s *APIKeyUpsert) {
-		s.AddRateLimit5h(v)
+		s.AddMonthlyTokenLimit(v)
 	})
 }
 
-// UpdateRateLimit5h sets the "rate_limit_5h" field to the value that was provided on create.
-func (u *APIKeyUpsertOne) UpdateRateLimit5h() *APIKeyUpsertOne {
+// UpdateMonthlyTokenLimit sets the "monthly_token_limit" field to the value that was provided on create.
+func (u *APIKeyUpsertOne) UpdateMonthlyTokenLimit() *APIKeyUpsertOne {
 	return u.Update(func(s *APIKeyUpsert) {
-		s.UpdateRateLimit5h()
+		s.UpdateMonthlyTokenLimit()
 	})
 }
 
-// SetRateLimit1d sets the "rate_limit_1d" field.
-func (u *APIKeyUpsertOne) SetRateLimit1d(v float64) *APIKeyUpsertOne {
+// SetBudgetAmount sets the "budget_amount" field.
+func (u *APIKeyUpsertOne) SetBudgetAmount(v float64) *APIKeyUpsertOne {
 	return u.Update(func(s *APIKeyUpsert) {
-		s.SetRateLimit1d(v)
+		s.SetBudgetAmount(v)
 	})
 }
 
-// AddRateLimit1d adds v to the "rate_limit_1d" field.
-func (u *APIKeyUpsertOne) AddRateLimit1d(v float64) *APIKeyUpsertOne {
+// AddBudgetAmount adds v to the "budget_amount" field.
+func (u *APIKeyUpsertOne) AddBudgetAmount(v float64) *APIKeyUpsertOne {
 	return u.Update(func(s *APIKeyUpsert) {
-		s.AddRateLimit1d(v)
+		s.AddBudgetAmount(v)
 	})
 }
 
-// UpdateRateLimit1d sets the "rate_limit_1d" field to the value that was provided on create.
-func (u *APIKeyUpsertOne) UpdateRateLimit1d() *APIKeyUpsertOne {
+// UpdateBudgetAmount sets the "budget_amount" field to the value that was provided on create.
+func (u *APIKeyUpsertOne) UpdateBudgetAmount() *APIKeyUpsertOne {
 	return u.Update(func(s *APIKeyUpsert) {
-		s.UpdateRateLimit1d()
+		s.UpdateBudgetAmount()
 	})
 }
 
-// SetRateLimit7d sets the "rate_limit_7d" field.
-func (u *APIKeyUpsertOne) SetRateLimit7d(v float64) *APIKeyUpsertOne {
+// SetBudgetPeriod sets the "budget_period" field.
+func (u *APIKeyUpsertOne) SetBudgetPeriod(v string) *APIKeyUpsertOne {
 	return u.Update(func(s *APIKeyUpsert) {
-		s.SetRateLimit7d(v)
+		s.SetBudgetPeriod(v)
 	})
 }
 
-// AddRateLimit7d adds v to the "rate_limit_7d" field.
-func (u *APIKeyUpsertOne) AddRateLimit7d(v float64) *APIKeyUpsertOne {
+// UpdateBudgetPeriod sets the "budget_period" field to the value that was provided on create.
+func (u *APIKeyUpsertOne) UpdateBudgetPeriod() *APIKeyUpsertOne {
 	return u.Update(func(s *APIKeyUpsert) {
-		s.AddRateLimit7d(v)
+		s.UpdateBudgetPeriod()
 	})
 }
 
-// UpdateRateLimit7d sets the "rate_limit_7d" field to the value that was provided on create.
-func (u *APIKeyUpsertOne) UpdateRateLimit7d() *APIKeyUpsertOne {
+// SetBudgetAction sets the "budget_action" field.
+func (u *APIKeyUpsertOne) SetBudgetAction(v string) *APIKeyUpsertOne {
 	return u.Update(func(s *APIKeyUpsert) {
-		s.UpdateRateLimit7d()
+		s.SetBudgetAction(v)
 	})
 }
 
-// SetUsage5h sets the "usage_5h" field.
-func (u *APIKeyUpsertOne) SetUsage5h(v float64) *APIKeyUpsertOne {
+// UpdateBudgetAction sets the "budget_action" field to the value that was provided on create.
+func (u *APIKeyUpsertOne) UpdateBudgetAction() *APIKeyUpsertOne {
 	return u.Update(func(s *APIKeyUpsert) {
-		s.SetUsage5h(v)
+		s.UpdateBudgetAction()
 	})
 }
 
-// AddUsage5h adds v to the "usage_5h" field.
-func (u *APIKeyUpsertOne) AddUsage5h(v float64) *APIKeyUpsertOne {
+// SetBudgetFallbackModel sets the "budget_fallback_model" field.
+func (u *APIKeyUpsertOne) SetBudgetFallbackModel(v string) *APIKeyUpsertOne {
 	return u.Update(func(s *APIKeyUpsert) {
-		s.AddUsage5h(v)
+		s.SetBudgetFallbackModel(v)
 	})
 }
 
-// UpdateUsage5h sets the "usage_5h" field to the value that was provided on create.
-func (u *APIKeyUpsertOne) UpdateUsage5h() *APIKeyUpsertOne {
+// UpdateBudgetFallbackModel sets the "budget_fallback_model" field to the value that was provided on create.
+func (u *APIKeyUpsertOne) UpdateBudgetFallbackModel() *APIKeyUpsertOne {
 	return u.Update(func(s *APIKeyUpsert) {
-		s.UpdateUsage5h()
+		s.UpdateBudgetFallbackModel()
 	})
 }
 
-// SetUsage1d sets the "usage_1d" field.
-func (u *APIKeyUpsertOne) SetUsage1d(v float64) *APIKeyUpsertOne {
+// SetBudgetUsed sets the "budget_used" field.
+func (u *APIKeyUpsertOne) SetBudgetUsed(v float64) *APIKeyUpsertOne {
 	return u.Update(func(s *APIKeyUpsert) {
-		s.SetUsage1d(v)
+		s.SetBudgetUsed(v)
 	})
 }
 
-// AddUsage1d adds v to the "usage_1d" field.
-func (u *APIKeyUpsertOne) AddUsage1d(v float64) *APIKeyUpsertOne {
+// AddBudgetUsed adds v to the "budget_used" field.
+func (u *APIKeyUpsertOne) AddBudgetUsed(v float64) *APIKeyUpsertOne {
 	return u.Update(func(s *APIKeyUpsert) {
-		s.AddUsage1d(v)
+		s.AddBudgetUsed(v)
 	})
 }
 
-// UpdateUsage1d sets the "usage_1d" field to the value that was provided on create.
-func (u *APIKeyUpsertOne) UpdateUsage1d() *APIKeyUpsertOne {
+// UpdateBudgetUsed sets the "budget_used" field to the value that was provided on create.
+func (u *APIKeyUpsertOne) UpdateBudgetUsed() *APIKeyUpsertOne {
 	return u.Update(func(s *APIKeyUpsert) {
-		s.UpdateUsage1d()
+		s.UpdateBudgetUsed()
 	})
 }
 
-// SetUsage7d sets the "usage_7d" field.
-func (u *APIKeyUpsertOne) SetUsage7d(v float64) *APIKeyUpsertOne {
+// SetBudgetPeriodStart sets the "budget_period_start" field.
+func (u *APIKeyUpsertOne) SetBudgetPeriodStart(v time.Time) *APIKeyUpsertOne {
 	return u.Update(func(s *APIKeyUpsert) {
-		s.SetUsage7d(v)
+		s.SetBudgetPeriodStart(v)
 	})
 }
 
-// AddUsage7d adds v to the "usage_7d" field.
-func (u *APIKeyUpsertOne) AddUsage7d(v float64) *APIKeyUpsertOne {
+// UpdateBudgetPeriodStart sets the "budget_period_start" field to the value that was provided on create.
+func (u *APIKeyUpsertOne) UpdateBudgetPeriodStart() *APIKeyUpsertOne {
 	return u.Update(func(s *APIKeyUpsert) {
-		s.AddUsage7d(v)
+		s.UpdateBudgetPeriodStart()
 	})
 }
 
-// UpdateUsage7d sets the "usage_7d" field to the value that was provided on create.
-func (u *APIKeyUpsertOne) UpdateUsage7d() *APIKeyUpsertOne {
+// ClearBudgetPeriodStart clears the value of the "budget_period_start" field.
+func (u *APIKeyUpsertOne) ClearBudgetPeriodStart() *APIKeyUpsertOne {
 	return u.Update(func(s *APIKeyUpsert) {
-		s.UpdateUsage7d()
+		s.ClearBudgetPeriodStart()
 	})
 }
 
-// SetWindow5hStart sets the "window_5h_start" field.
-func (u *APIKeyUpsertOne) SetWindow5hStart(v time.Time) *APIKeyUpsertOne {
+// SetBudgetExceededAt sets the "budget_exceeded_at" field.
+func (u *APIKeyUpsertOne) SetBudgetExceededAt(v time.Time) *APIKeyUpsertOne {
 	return u.Update(func(s *APIKeyUpsert) {
-		s.SetWindow5hStart(v)
+		s.SetBudgetExceededAt(v)
 	})
 }
 
-// UpdateWindow5hStart sets the "window_5h_start" field to the value that was provided on create.
-func (u *APIKeyUpsertOne) UpdateWindow5hStart() *APIKeyUpsertOne {
+// UpdateBudgetExceededAt sets the "budget_exceeded_at" field to the value that was provided on create.
+func (u *APIKeyUpsertOne) UpdateBudgetExceededAt() *APIKeyUpsertOne {
 	return u.Update(func(s *APIKeyUpsert) {
-		s.UpdateWindow5hStart()
+		s.UpdateBudgetExceededAt()
 	})
 }
 
-// ClearWindow5hStart clears the value of the "window_5h_start" field.
-func (u *APIKeyUpsertOne) ClearWindow5hStart() *APIKeyUpsertOne {
+// ClearBudgetExceededAt clears the value of the "budget_exceeded_at" field.
+func (u *APIKeyUpsertOne) ClearBudgetExceededAt() *APIKeyUpsertOne {
 	return u.Update(func(s *APIKeyUpsert) {
-		s.ClearWindow5hStart()
+		s.ClearBudgetExceededAt()
 	})
 }
 
-// SetWindow1dStart sets the "window_1d_start" field.
-func (u *APIKeyUpsertOne) SetWindow1dStart(v time.Time) *APIKeyUpsertOne {
+// SetPreviousKey sets the "previous_key" field.
+func (u *APIKeyUpsertOne) SetPreviousKey(v string) *APIKeyUpsertOne {
 	return u.Update(func(s *APIKeyUpsert) {
-		s.SetWindow1dStart(v)
+		s.SetPreviousKey(v)
 	})
 }
 
-// UpdateWindow1dStart sets the "window_1d_start" field to the value that was provided on create.
-func (u *APIKeyUpsertOne) UpdateWindow1dStart() *APIKeyUpsertOne {
+// UpdatePreviousKey sets the "previous_key" field to the value that was provided on create.
+func (u *APIKeyUpsertOne) UpdatePreviousKey() *APIKeyUpsertOne {
 	return u.Update(func(s *APIKeyUpsert) {
-		s.UpdateWindow1dStart()
+		s.UpdatePreviousKey()
 	})
 }
 
-// ClearWindow1dStart clears the value of the "window_1d_start" field.
-func (u *APIKeyUpsertOne) ClearWindow1dStart() *APIKeyUpsertOne {
+// ClearPreviousKey clears the value of the "previous_key" field.
+func (u *APIKeyUpsertOne) ClearPreviousKey() *APIKeyUpsertOne {
 	return u.Update(func(s *APIKeyUpsert) {
-		s.ClearWindow1dStart()
+		s.ClearPreviousKey()
 	})
 }
 
-// SetWindow7dStart sets the "window_7d_start" field.
-func (u *APIKeyUpsertOne) SetWindow7dStart(v time.Time) *APIKeyUpsertOne {
+// SetPreviousKeyExpiresAt sets the "previous_key_expires_at" field.
+func (u *APIKeyUpsertOne) SetPreviousKeyExpiresAt(v time.Time) *APIKeyUpsertOne {
 	return u.Update(func(s *APIKeyUpsert) {
-		s.SetWindow7dStart(v)
+		s.SetPreviousKeyExpiresAt(v)
 	})
 }
 
-// UpdateWindow7dStart sets the "window_7d_start" field to the value that was provided on create.
-func (u *APIKeyUpsertOne) UpdateWindow7dStart() *APIKeyUpsertOne {
+// UpdatePreviousKeyExpiresAt sets the "previous_key_expires_at" field to the value that was provided on create.
+func (u *APIKeyUpsertOne) UpdatePreviousKeyExpiresAt() *APIKeyUpsertOne {
 	return u.Update(func(s *APIKeyUpsert) {
-		s.UpdateWindow7dStart()
+		s.UpdatePreviousKeyExpiresAt()
 	})
 }
 
-// ClearWindow7dStart clears the value of the "window_7d_start" field.
-func (u *APIKeyUpsertOne) ClearWindow7dStart() *APIKeyUpsertOne {
+// ClearPreviousKeyExpiresAt clears the value of the "previous_key_expires_at" field.
+func (u *APIKeyUpsertOne) ClearPreviousKeyExpiresAt() *APIKeyUpsertOne {
 	return u.Update(func(s *APIKeyUpsert) {
-		s.ClearWindow7dStart()
+		s.ClearPreviousKeyExpiresAt()
 	})
 }
 
@@ -1791,133 +3463,308 @@ func (u *APIKeyUpsertBulk) SetUserID(v int64) *APIKeyUpsertBulk {
 // UpdateUserID sets the "user_id" field to the value that was provided on create.
 func (u *APIKeyUpsertBulk) UpdateUserID() *APIKeyUpsertBulk {
 	return u.Update(func(s *APIKeyUpsert) {
-		s.UpdateUserID()
+		s.UpdateUserID()
+	})
+}
+
+// SetKey sets the "key" field.
+func (u *APIKeyUpsertBulk) SetKey(v string) *APIKeyUpsertBulk {
+	return u.Update(func(s *APIKeyUpsert) {
+		s.SetKey(v)
+	})
+}
+
+// UpdateKey sets the "key" field to the value that was provided on create.
+func (u *APIKeyUpsertBulk) UpdateKey() *APIKeyUpsertBulk {
+	return u.Update(func(s *APIKeyUpsert) {
+		s.UpdateKey()
+	})
+}
+
+// SetName sets the "name" field.
+func (u *APIKeyUpsertBulk) SetName(v string) *APIKeyUpsertBulk {
+	return u.Update(func(s *APIKeyUpsert) {
+		s.SetName(v)
+	})
+}
+
+// UpdateName sets the "name" field to the value that was provided on create.
+func (u *APIKeyUpsertBulk) UpdateName() *APIKeyUpsertBulk {
+	return u.Update(func(s *APIKeyUpsert) {
+		s.UpdateName()
+	})
+}
+
+// SetDescription sets the "description" field.
+func (u *APIKeyUpsertBulk) SetDescription(v string) *APIKeyUpsertBulk {
+	return u.Update(func(s *APIKeyUpsert) {
+		s.SetDescription(v)
+	})
+}
+
+// UpdateDescription sets the "description" field to the value that was provided on create.
+func (u *APIKeyUpsertBulk) UpdateDescription() *APIKeyUpsertBulk {
+	return u.Update(func(s *APIKeyUpsert) {
+		s.UpdateDescription()
+	})
+}
+
+// SetGroupID sets the "group_id" field.
+func (u *APIKeyUpsertBulk) SetGroupID(v int64) *APIKeyUpsertBulk {
+	return u.Update(func(s *APIKeyUpsert) {
+		s.SetGroupID(v)
+	})
+}
+
+// UpdateGroupID sets the "group_id" field to the value that was provided on create.
+func (u *APIKeyUpsertBulk) UpdateGroupID() *APIKeyUpsertBulk {
+	return u.Update(func(s *APIKeyUpsert) {
+		s.UpdateGroupID()
+	})
+}
+
+// ClearGroupID clears the value of the "group_id" field.
+func (u *APIKeyUpsertBulk) ClearGroupID() *APIKeyUpsertBulk {
+	return u.Update(func(s *APIKeyUpsert) {
+		s.ClearGroupID()
+	})
+}
+
+// SetStatus sets the "status" field.
+func (u *APIKeyUpsertBulk) SetStatus(v string) *APIKeyUpsertBulk {
+	return u.Update(func(s *APIKeyUpsert) {
+		s.SetStatus(v)
+	})
+}
+
+// UpdateStatus sets the "status" field to the value that was provided on create.
+func (u *APIKeyUpsertBulk) UpdateStatus() *APIKeyUpsertBulk {
+	return u.Update(func(s *APIKeyUpsert) {
+		s.UpdateStatus()
+	})
+}
+
+// SetLastUsedAt sets the "last_used_at" field.
+func (u *APIKeyUpsertBulk) SetLastUsedAt(v time.Time) *APIKeyUpsertBulk {
+	return u.Update(func(s *APIKeyUpsert) {
+		s.SetLastUsedAt(v)
+	})
+}
+
+// UpdateLastUsedAt sets the "last_used_at" field to the value that was provided on create.
+func (u *APIKeyUpsertBulk) UpdateLastUsedAt() *APIKeyUpsertBulk {
+	return u.Update(func(s *APIKeyUpsert) {
+		s.UpdateLastUsedAt()
+	})
+}
+
+// ClearLastUsedAt clears the value of the "last_used_at" field.
+func (u *APIKeyUpsertBulk) ClearLastUsedAt() *APIKeyUpsertBulk {
+	return u.Update(func(s *APIKeyUpsert) {
+		s.ClearLastUsedAt()
+	})
+}
+
+// SetIPWhitelist sets the "ip_whitelist" field.
+func (u *APIKeyUpsertBulk) SetIPWhitelist(v []string) *APIKeyUpsertBulk {
+	return u.Update(func(s *APIKeyUpsert) {
+		s.SetIPWhitelist(v)
+	})
+}
+
+// UpdateIPWhitelist sets the "ip_whitelist" field to the value that was provided on create.
+func (u *APIKeyUpsertBulk) UpdateIPWhitelist() *APIKeyUpsertBulk {
+	return u.Update(func(s *APIKeyUpsert) {
+		s.UpdateIPWhitelist()
+	})
+}
+
+// ClearIPWhitelist clears the value of the "ip_whitelist" field.
+func (u *APIKeyUpsertBulk) ClearIPWhitelist() *APIKeyUpsertBulk {
+	return u.Update(func(s *APIKeyUpsert) {
+		s.ClearIPWhitelist()
+	})
+}
+
+// SetIPBlacklist sets the "ip_blacklist" field.
+func (u *APIKeyUpsertBulk) SetIPBlacklist(v []string) *APIKeyUpsertBulk {
+	return u.Update(func(s *APIKeyUpsert) {
+		s.SetIPBlacklist(v)
+	})
+}
+
+// UpdateIPBlacklist sets the "ip_blacklist" field to the value that was provided on create.
+func (u *APIKeyUpsertBulk) UpdateIPBlacklist() *APIKeyUpsertBulk {
+	return u.Update(func(s *APIKeyUpsert) {
+		s.UpdateIPBlacklist()
+	})
+}
+
+// ClearIPBlacklist clears the value of the "ip_blacklist" field.
+func (u *APIKeyUpsertBulk) ClearIPBlacklist() *APIKeyUpsertBulk {
+	return u.Update(func(s *APIKeyUpsert) {
+		s.ClearIPBlacklist()
+	})
+}
+
+// SetScopes sets the "scopes" field.
+func (u *APIKeyUpsertBulk) SetScopes(v []string) *APIKeyUpsertBulk {
+	return u.Update(func(s *APIKeyUpsert) {
+		s.SetScopes(v)
+	})
+}
+
+// UpdateScopes sets the "scopes" field to the value that was provided on create.
+func (u *APIKeyUpsertBulk) UpdateScopes() *APIKeyUpsertBulk {
+	return u.Update(func(s *APIKeyUpsert) {
+		s.UpdateScopes()
+	})
+}
+
+// ClearScopes clears the value of the "scopes" field.
+func (u *APIKeyUpsertBulk) ClearScopes() *APIKeyUpsertBulk {
+	return u.Update(func(s *APIKeyUpsert) {
+		s.ClearScopes()
+	})
+}
+
+// SetAllowedModels sets the "allowed_models" field.
+func (u *APIKeyUpsertBulk) SetAllowedModels(v []string) *APIKeyUpsertBulk {
+	return u.Update(func(s *APIKeyUpsert) {
+		s.SetAllowedModels(v)
+	})
+}
+
+// UpdateAllowedModels sets the "allowed_models" field to the value that was provided on create.
+func (u *APIKeyUpsertBulk) UpdateAllowedModels() *APIKeyUpsertBulk {
+	return u.Update(func(s *APIKeyUpsert) {
+		s.UpdateAllowedModels()
 	})
 }
 
-// SetKey sets the "key" field.
-func (u *APIKeyUpsertBulk) SetKey(v string) *APIKeyUpsertBulk {
+// ClearAllowedModels clears the value of the "allowed_models" field.
+func (u *APIKeyUpsertBulk) ClearAllowedModels() *APIKeyUpsertBulk {
 	return u.Update(func(s *APIKeyUpsert) {
-		s.SetKey(v)
+		s.ClearAllowedModels()
 	})
 }
 
-// UpdateKey sets the "key" field to the value that was provided on create.
-func (u *APIKeyUpsertBulk) UpdateKey() *APIKeyUpsertBulk {
+// SetClientRestriction sets the "client_restriction" field.
+func (u *APIKeyUpsertBulk) SetClientRestriction(v string) *APIKeyUpsertBulk {
 	return u.Update(func(s *APIKeyUpsert) {
-		s.UpdateKey()
+		s.SetClientRestriction(v)
 	})
 }
 
-// SetName sets the "name" field.
-func (u *APIKeyUpsertBulk) SetName(v string) *APIKeyUpsertBulk {
+// UpdateClientRestriction sets the "client_restriction" field to the value that was provided on create.
+func (u *APIKeyUpsertBulk) UpdateClientRestriction() *APIKeyUpsertBulk {
 	return u.Update(func(s *APIKeyUpsert) {
-		s.SetName(v)
+		s.UpdateClientRestriction()
 	})
 }
 
-// UpdateName sets the "name" field to the value that was provided on create.
-func (u *APIKeyUpsertBulk) UpdateName() *APIKeyUpsertBulk {
+// SetTags sets the "tags" field.
+func (u *APIKeyUpsertBulk) SetTags(v []string) *APIKeyUpsertBulk {
 	return u.Update(func(s *APIKeyUpsert) {
-		s.UpdateName()
+		s.SetTags(v)
 	})
 }
 
-// SetGroupID sets the "group_id" field.
-func (u *APIKeyUpsertBulk) SetGroupID(v int64) *APIKeyUpsertBulk {
+// UpdateTags sets the "tags" field to the value that was provided on create.
+func (u *APIKeyUpsertBulk) UpdateTags() *APIKeyUpsertBulk {
 	return u.Update(func(s *APIKeyUpsert) {
-		s.SetGroupID(v)
+		s.UpdateTags()
 	})
 }
 
-// UpdateGroupID sets the "group_id" field to the value that was provided on create.
-func (u *APIKeyUpsertBulk) UpdateGroupID() *APIKeyUpsertBulk {
+// ClearTags clears the value of the "tags" field.
+func (u *APIKeyUpsertBulk) ClearTags() *APIKeyUpsertBulk {
 	return u.Update(func(s *APIKeyUpsert) {
-		s.UpdateGroupID()
+		s.ClearTags()
 	})
 }
 
-// ClearGroupID clears the value of the "group_id" field.
-func (u *APIKeyUpsertBulk) ClearGroupID() *APIKeyUpsertBulk {
+// SetDegradedFallbackModel sets the "degraded_fallback_model" field.
+func (u *APIKeyUpsertBulk) SetDegradedFallbackModel(v string) *APIKeyUpsertBulk {
 	return u.Update(func(s *APIKeyUpsert) {
-		s.ClearGroupID()
+		s.SetDegradedFallbackModel(v)
 	})
 }
 
-// SetStatus sets the "status" field.
-func (u *APIKeyUpsertBulk) SetStatus(v string) *APIKeyUpsertBulk {
+// UpdateDegradedFallbackModel sets the "degraded_fallback_model" field to the value that was provided on create.
+func (u *APIKeyUpsertBulk) UpdateDegradedFallbackModel() *APIKeyUpsertBulk {
 	return u.Update(func(s *APIKeyUpsert) {
-		s.SetStatus(v)
+		s.UpdateDegradedFallbackModel()
 	})
 }
 
-// UpdateStatus sets the "status" field to the value that was provided on create.
-func (u *APIKeyUpsertBulk) UpdateStatus() *APIKeyUpsertBulk {
+// SetSystemPrompt sets the "system_prompt" field.
+func (u *APIKeyUpsertBulk) SetSystemPrompt(v string) *APIKeyUpsertBulk {
 	return u.Update(func(s *APIKeyUpsert) {
-		s.UpdateStatus()
+		s.SetSystemPrompt(v)
 	})
 }
 
-// SetLastUsedAt sets the "last_used_at" field.
-func (u *APIKeyUpsertBulk) SetLastUsedAt(v time.Time) *APIKeyUpsertBulk {
+// UpdateSystemPrompt sets the "system_prompt" field to the value that was provided on create.
+func (u *APIKeyUpsertBulk) UpdateSystemPrompt() *APIKeyUpsertBulk {
 	return u.Update(func(s *APIKeyUpsert) {
-		s.SetLastUsedAt(v)
+		s.UpdateSystemPrompt()
 	})
 }
 
-// UpdateLastUsedAt sets the "last_used_at" field to the value that was provided on create.
-func (u *APIKeyUpsertBulk) UpdateLastUsedAt() *APIKeyUpsertBulk {
+// SetSystemPromptMode sets the "system_prompt_mode" field.
+func (u *APIKeyUpsertBulk) SetSystemPromptMode(v string) *APIKeyUpsertBulk {
 	return u.Update(func(s *APIKeyUpsert) {
-		s.UpdateLastUsedAt()
+		s.SetSystemPromptMode(v)
 	})
 }
 
-// ClearLastUsedAt clears the value of the "last_used_at" field.
-func (u *APIKeyUpsertBulk) ClearLastUsedAt() *APIKeyUpsertBulk {
+// UpdateSystemPromptMode sets the "system_prompt_mode" field to the value that was provided on create.
+func (u *APIKeyUpsertBulk) UpdateSystemPromptMode() *APIKeyUpsertBulk {
 	return u.Update(func(s *APIKeyUpsert) {
-		s.ClearLastUsedAt()
+		s.UpdateSystemPromptMode()
 	})
 }
 
-// SetIPWhitelist sets the "ip_whitelist" field.
-func (u *APIKeyUpsertBulk) SetIPWhitelist(v []string) *APIKeyUpsertBulk {
+// SetModerationMode sets the "moderation_mode" field.
+func (u *APIKeyUpsertBulk) SetModerationMode(v string) *APIKeyUpsertBulk {
 	return u.Update(func(s *APIKeyUpsert) {
-		s.SetIPWhitelist(v)
+		s.SetModerationMode(v)
 	})
 }
 
-// UpdateIPWhitelist sets the "ip_whitelist" field to the value that was provided on create.
-func (u *APIKeyUpsertBulk) UpdateIPWhitelist() *APIKeyUpsertBulk {
+// UpdateModerationMode sets the "moderation_mode" field to the value that was provided on create.
+func (u *APIKeyUpsertBulk) UpdateModerationMode() *APIKeyUpsertBulk {
 	return u.Update(func(s *APIKeyUpsert) {
-		s.UpdateIPWhitelist()
+		s.UpdateModerationMode()
 	})
 }
 
-// ClearIPWhitelist clears the value of the "ip_whitelist" field.
-func (u *APIKeyUpsertBulk) ClearIPWhitelist() *APIKeyUpsertBulk {
+// SetLogContent sets the "log_content" field.
+func (u *APIKeyUpsertBulk) SetLogContent(v bool) *APIKeyUpsertBulk {
 	return u.Update(func(s *APIKeyUpsert) {
-		s.ClearIPWhitelist()
+		s.SetLogContent(v)
 	})
 }
 
-// SetIPBlacklist sets the "ip_blacklist" field.
-func (u *APIKeyUpsertBulk) SetIPBlacklist(v []string) *APIKeyUpsertBulk {
+// UpdateLogContent sets the "log_content" field to the value that was provided on create.
+func (u *APIKeyUpsertBulk) UpdateLogContent() *APIKeyUpsertBulk {
 	return u.Update(func(s *APIKeyUpsert) {
-		s.SetIPBlacklist(v)
+		s.UpdateLogContent()
 	})
 }
 
-// UpdateIPBlacklist sets the "ip_blacklist" field to the value that was provided on create.
-func (u *APIKeyUpsertBulk) UpdateIPBlacklist() *APIKeyUpsertBulk {
+// SetSimulate sets the "simulate" field.
+func (u *APIKeyUpsertBulk) SetSimulate(v bool) *APIKeyUpsertBulk {
 	return u.Update(func(s *APIKeyUpsert) {
-		s.UpdateIPBlacklist()
+		s.SetSimulate(v)
 	})
 }
 
-// ClearIPBlacklist clears the value of the "ip_blacklist" field.
-func (u *APIKeyUpsertBulk) ClearIPBlacklist() *APIKeyUpsertBulk {
+// UpdateSimulate sets the "simulate" field to the value that was provided on create.
+func (u *APIKeyUpsertBulk) UpdateSimulate() *APIKeyUpsertBulk {
 	return u.Update(func(s *APIKeyUpsert) {
-		s.ClearIPBlacklist()
+		s.UpdateSimulate()
 	})
 }
 
@@ -1963,6 +3810,62 @@ func (u *APIKeyUpsertBulk) UpdateQuotaUsed() *APIKeyUpsertBulk {
 	})
 }
 
+// SetImageQuota sets the "image_quota" field.
+func (u *APIKeyUpsertBulk) SetImageQuota(v int) *APIKeyUpsertBulk {
+	return u.Update(func(s *APIKeyUpsert) {
+		s.SetImageQuota(v)
+	})
+}
+
+// AddImageQuota adds v to the "image_quota" field.
+func (u *APIKeyUpsertBulk) AddImageQuota(v int) *APIKeyUpsertBulk {
+	return u.Update(func(s *APIKeyUpsert) {
+		s.AddImageQuota(v)
+	})
+}
+
+// UpdateImageQuota sets the "image_quota" field to the value that was provided on create.
+func (u *APIKeyUpsertBulk) UpdateImageQuota() *APIKeyUpsertBulk {
+	return u.Update(func(s *APIKeyUpsert) {
+		s.UpdateImageQuota()
+	})
+}
+
+// SetImageQuotaUsed sets the "image_quota_used" field.
+func (u *APIKeyUpsertBulk) SetImageQuotaUsed(v int) *APIKeyUpsertBulk {
+	return u.Update(func(s *APIKeyUpsert) {
+		s.SetImageQuotaUsed(v)
+	})
+}
+
+// AddImageQuotaUsed adds v to the "image_quota_used" field.
+func (u *APIKeyUpsertBulk) AddImageQuotaUsed(v int) *APIKeyUpsertBulk {
+	return u.Update(func(s *APIKeyUpsert) {
+		s.AddImageQuotaUsed(v)
+	})
+}
+
+// UpdateImageQuotaUsed sets the "image_quota_used" field to the value that was provided on create.
+func (u *APIKeyUpsertBulk) UpdateImageQuotaUsed() *APIKeyUpsertBulk {
+	return u.Update(func(s *APIKeyUpsert) {
+		s.UpdateImageQuotaUsed()
+	})
+}
+
+// SetSuppressReasoning sets the "suppress_reasoning" field.
+func (u *APIKeyUpsertBulk) SetSuppressReasoning(v bool) *APIKeyUpsertBulk {
+	return u.Update(func(s *APIKeyUpsert) {
+		s.SetSuppressReasoning(v)
+	})
+}
+
+// UpdateSuppressReasoning sets the "suppress_reasoning" field to the value that was provided on create.
+func (u *APIKeyUpsertBulk) UpdateSuppressReasoning() *APIKeyUpsertBulk {
+	return u.Update(func(s *APIKeyUpsert) {
+		s.UpdateSuppressReasoning()
+	})
+}
+
 // SetExpiresAt sets the "expires_at" field.
 func (u *APIKeyUpsertBulk) SetExpiresAt(v time.Time) *APIKeyUpsertBulk {
 	return u.Update(func(s *APIKeyUpsert) {
@@ -2173,6 +4076,300 @@ func (u *APIKeyUpsertBulk) ClearWindow7dStart() *APIKeyUpsertBulk {
 	})
 }
 
+// SetRpmLimit sets the "rpm_limit" field.
+func (u *APIKeyUpsertBulk) SetRpmLimit(v int) *APIKeyUpsertBulk {
+	return u.Update(func(s *APIKeyUpsert) {
+		s.SetRpmLimit(v)
+	})
+}
+
+// AddRpmLimit adds v to the "rpm_limit" field.
+func (u *APIKeyUpsertBulk) AddRpmLimit(v int) *APIKeyUpsertBulk {
+	return u.Update(func(s *APIKeyUpsert) {
+		s.AddRpmLimit(v)
+	})
+}
+
+// UpdateRpmLimit sets the "rpm_limit" field to the value that was provided on create.
+func (u *APIKeyUpsertBulk) UpdateRpmLimit() *APIKeyUpsertBulk {
+	return u.Update(func(s *APIKeyUpsert) {
+		s.UpdateRpmLimit()
+	})
+}
+
+// SetTpmLimit sets the "tpm_limit" field.
+func (u *APIKeyUpsertBulk) SetTpmLimit(v int) *APIKeyUpsertBulk {
+	return u.Update(func(s *APIKeyUpsert) {
+		s.SetTpmLimit(v)
+	})
+}
+
+// AddTpmLimit adds v to the "tpm_limit" field.
+func (u *APIKeyUpsertBulk) AddTpmLimit(v int) *APIKeyUpsertBulk {
+	return u.Update(func(s *APIKeyUpsert) {
+		s.AddTpmLimit(v)
+	})
+}
+
+// UpdateTpmLimit sets the "tpm_limit" field to the value that was provided on create.
+func (u *APIKeyUpsertBulk) UpdateTpmLimit() *APIKeyUpsertBulk {
+	return u.Update(func(s *APIKeyUpsert) {
+		s.UpdateTpmLimit()
+	})
+}
+
+// SetDailyRequestLimit sets the "daily_request_limit" field.
+func (u *APIKeyUpsertBulk) SetDailyRequestLimit(v int) *APIKeyUpsertBulk {
+	return u.Update(func(s *APIKeyUpsert) {
+		s.SetDailyRequestLimit(v)
+	})
+}
+
+// AddDailyRequestLimit adds v to the "daily_request_limit" field.
+func (u *APIKeyUpsertBulk) AddDailyRequestLimit(v int) *APIKeyUpsertBulk {
+	return u.Update(func(s *APIKeyUpsert) {
+		s.AddDailyRequestLimit(v)
+	})
+}
+
+// UpdateDailyRequestLimit sets the "daily_request_limit" field to the value that was provided on create.
+func (u *APIKeyUpsertBulk) UpdateDailyRequestLimit() *APIKeyUpsertBulk {
+	return u.Update(func(s *APIKeyUpsert) {
+		s.UpdateDailyRequestLimit()
+	})
+}
+
+// SetDailyTokenLimit sets the "daily_token_limit" field.
+func (u *APIKeyUpsertBulk) SetDailyTokenLimit(v int64) *APIKeyUpsertBulk {
+	return u.Update(func(s *APIKeyUpsert) {
+		s.SetDailyTokenLimit(v)
+	})
+}
+
+// AddDailyTokenLimit adds v to the "daily_token_limit" field.
+func (u *APIKeyUpsertBulk) AddDailyTokenLimit(v int64) *APIKeyUpsertBulk {
+	return u.Update(func(s *APIKeyUpsert) {
+		s.AddDailyTokenLimit(v)
+	})
+}
+
+// UpdateDailyTokenLimit sets the "daily_token_limit" field to the value that was provided on create.
+func (u *APIKeyUpsertBulk) UpdateDailyTokenLimit() *APIKeyUpsertBulk {
+	return u.Update(func(s *APIKeyUpsert) {
+		s.UpdateDailyTokenLimit()
+	})
+}
+
+// SetMonthlyRequestLimit sets the "monthly_request_limit" field.
+func (u *APIKeyUpsertBulk) SetMonthlyRequestLimit(v int) *APIKeyUpsertBulk {
+	return u.Update(func(s *APIKeyUpsert) {
+		s.SetMonthlyRequestLimit(v)
+	})
+}
+
+// AddMonthlyRequestLimit adds v to the "monthly_request_limit" field.
+func (u *APIKeyUpsertBulk) AddMonthlyRequestLimit(v int) *APIKeyUpsertBulk {
+	return u.Update(func(s *APIKeyUpsert) {
+		s.AddMonthlyRequestLimit(v)
+	})
+}
+
+// UpdateMonthlyRequestLimit sets the "monthly_request_limit" field to the value that was provided on create.
+func (u *APIKeyUpsertBulk) UpdateMonthlyRequestLimit() *APIKeyUpsertBulk {
+	return u.Update(func(s *APIKeyUpsert) {
+		s.UpdateMonthlyRequestLimit()
+	})
+}
+
+// SetMonthlyTokenLimit sets the "monthly_token_limit" field.
+func (u *APIKeyUpsertBulk) SetMonthlyTokenLimit(v int64) *APIKeyUpsertBulk {
+	return u.Update(func(s *APIKeyUpsert) {
+		s.SetMonthlyTokenLimit(v)
+	})
+}
+
+// AddMonthlyTokenLimit adds v to the "monthly_token_limit" field.
+func (u *APIKeyUpsertBulk) AddMonthlyTokenLimit(v int64) *APIKeyUpsertBulk {
+	return u.Update(func(s *APIKeyUpsert) {
+		s.AddMonthlyTokenLimit(v)
+	})
+}
+
+// UpdateMonthlyTokenLimit sets the "monthly_token_limit" field to the value that was provided on create.
+func (u *APIKeyUpsertBulk) UpdateMonthlyTokenLimit() *APIKeyUpsertBulk {
+	return u.Update(func(s *APIKeyUpsert) {
+		s.UpdateMonthlyTokenLimit()
+	})
+}
+
+// SetBudgetAmount sets the "budget_amount" field.
+func (u *APIKeyUpsertBulk) SetBudgetAmount(v float64) *APIKeyUpsertBulk {
+	return u.Update(func(s *APIKeyUpsert) {
+		s.SetBudgetAmount(v)
+	})
+}
+
+// AddBudgetAmount adds v to the "budget_amount" field.
+func (u *APIKeyUpsertBulk) AddBudgetAmount(v float64) *APIKeyUpsertBulk {
+	return u.Update(func(s *APIKeyUpsert) {
+		s.AddBudgetAmount(v)
+	})
+}
+
+// UpdateBudgetAmount sets the "budget_amount" field to the value that was provided on create.
+func (u *APIKeyUpsertBulk) UpdateBudgetAmount() *APIKeyUpsertBulk {
+	return u.Update(func(s *APIKeyUpsert) {
+		s.UpdateBudgetAmount()
+	})
+}
+
+// SetBudgetPeriod sets the "budget_period" field.
+func (u *APIKeyUpsertBulk) SetBudgetPeriod(v string) *APIKeyUpsertBulk {
+	return u.Update(func(s *APIKeyUpsert) {
+		s.SetBudgetPeriod(v)
+	})
+}
+
+// UpdateBudgetPeriod sets the "budget_period" field to the value that was provided on create.
+func (u *APIKeyUpsertBulk) UpdateBudgetPeriod() *APIKeyUpsertBulk {
+	return u.Update(func(s *APIKeyUpsert) {
+		s.UpdateBudgetPeriod()
+	})
+}
+
+// SetBudgetAction sets the "budget_action" field.
+func (u *APIKeyUpsertBulk) SetBudgetAction(v string) *APIKeyUpsertBulk {
+	return u.Update(func(s *APIKeyUpsert) {
+		s.SetBudgetAction(v)
+	})
+}
+
+// UpdateBudgetAction sets the "budget_action" field to the value that was provided on create.
+func (u *APIKeyUpsertBulk) UpdateBudgetAction() *APIKeyUpsertBulk {
+	return u.Update(func(s *APIKeyUpsert) {
+		s.UpdateBudgetAction()
+	})
+}
+
+// SetBudgetFallbackModel sets the "budget_fallback_model" field.
+func (u *APIKeyUpsertBulk) SetBudgetFallbackModel(v string) *APIKeyUpsertBulk {
+	return u.Update(func(s *APIKeyUpsert) {
+		s.SetBudgetFallbackModel(v)
+	})
+}
+
+// UpdateBudgetFallbackModel sets the "budget_fallback_model" field to the value that was provided on create.
+func (u *APIKeyUpsertBulk) UpdateBudgetFallbackModel() *APIKeyUpsertBulk {
+	return u.Update(func(s *APIKeyUpsert) {
+		s.UpdateBudgetFallbackModel()
+	})
+}
+
+// SetBudgetUsed sets the "budget_used" field.
+func (u *APIKeyUpsertBulk) SetBudgetUsed(v float64) *APIKeyUpsertBulk {
+	return u.Update(func(s *APIKeyUpsert) {
+		s.SetBudgetUsed(v)
+	})
+}
+
+// AddBudgetUsed adds v to the "budget_used" field.
+func (u *APIKeyUpsertBulk) AddBudgetUsed(v float64) *APIKeyUpsertBulk {
+	return u.Update(func(s *APIKeyUpsert) {
+		s.AddBudgetUsed(v)
+	})
+}
+
+// UpdateBudgetUsed sets the "budget_used" field to the value that was provided on create.
+func (u *APIKeyUpsertBulk) UpdateBudgetUsed() *APIKeyUpsertBulk {
+	return u.Update(func(s *APIKeyUpsert) {
+		s.UpdateBudgetUsed()
+	})
+}
+
+// SetBudgetPeriodStart sets the "budget_period_start" field.
+func (u *APIKeyUpsertBulk) SetBudgetPeriodStart(v time.Time) *APIKeyUpsertBulk {
+	return u.Update(func(s *APIKeyUpsert) {
+		s.SetBudgetPeriodStart(v)
+	})
+}
+
+// UpdateBudgetPeriodStart sets the "budget_period_start" field to the value that was provided on create.
+func (u *APIKeyUpsertBulk) UpdateBudgetPeriodStart() *APIKeyUpsertBulk {
+	return u.Update(func(s *APIKeyUpsert) {
+		s.UpdateBudgetPeriodStart()
+	})
+}
+
+// ClearBudgetPeriodStart clears the value of the "budget_period_start" field.
+func (u *APIKeyUpsertBulk) ClearBudgetPeriodStart() *APIKeyUpsertBulk {
+	return u.Update(func(s *APIKeyUpsert) {
+		s.ClearBudgetPeriodStart()
+	})
+}
+
+// SetBudgetExceededAt sets the "budget_exceeded_at" field.
+func (u *APIKeyUpsertBulk) SetBudgetExceededAt(v time.Time) *APIKeyUpsertBulk {
+	return u.Update(func(s *APIKeyUpsert) {
+		s.SetBudgetExceededAt(v)
+	})
+}
+
+// UpdateBudgetExceededAt sets the "budget_exceeded_at" field to the value that was provided on create.
+func (u *APIKeyUpsertBulk) UpdateBudgetExceededAt() *APIKeyUpsertBulk {
+	return u.Update(func(s *APIKeyUpsert) {
+		s.UpdateBudgetExceededAt()
+	})
+}
+
+// ClearBudgetExceededAt clears the value of the "budget_exceeded_at" field.
+func (u *APIKeyUpsertBulk) ClearBudgetExceededAt() *APIKeyUpsertBulk {
+	return u.Update(func(s *APIKeyUpsert) {
+		s.ClearBudgetExceededAt()
+	})
+}
+
+// SetPreviousKey sets the "previous_key" field.
+func (u *APIKeyUpsertBulk) SetPreviousKey(v string) *APIKeyUpsertBulk {
+	return u.Update(func(s *APIKeyUpsert) {
+		s.SetPreviousKey(v)
+	})
+}
+
+// UpdatePreviousKey sets the "previous_key" field to the value that was provided on create.
+func (u *APIKeyUpsertBulk) UpdatePreviousKey() *APIKeyUpsertBulk {
+	return u.Update(func(s *APIKeyUpsert) {
+		s.UpdatePreviousKey()
+	})
+}
+
+// ClearPreviousKey clears the value of the "previous_key" field.
+func (u *APIKeyUpsertBulk) ClearPreviousKey() *APIKeyUpsertBulk {
+	return u.Update(func(s *APIKeyUpsert) {
+		s.ClearPreviousKey()
+	})
+}
+
+// SetPreviousKeyExpiresAt sets the "previous_key_expires_at" field.
+func (u *APIKeyUpsertBulk) SetPreviousKeyExpiresAt(v time.Time) *APIKeyUpsertBulk {
+	return u.Update(func(s *APIKeyUpsert) {
+		s.SetPreviousKeyExpiresAt(v)
+	})
+}
+
+// UpdatePreviousKeyExpiresAt sets the "previous_key_expires_at" field to the value that was provided on create.
+func (u *APIKeyUpsertBulk) UpdatePreviousKeyExpiresAt() *APIKeyUpsertBulk {
+	return u.Update(func(s *APIKeyUpsert) {
+		s.UpdatePreviousKeyExpiresAt()
+	})
+}
+
+// ClearPreviousKeyExpiresAt clears the value of the "previous_key_expires_at" field.
+func (u *APIKeyUpsertBulk) ClearPreviousKeyExpiresAt() *APIKeyUpsertBulk {
+	return u.Update(func(s *APIKeyUpsert) {
+		s.ClearPreviousKeyExpiresAt()
+	})
+}
+
 // Exec executes the query.
 func (u *APIKeyUpsertBulk) Exec(ctx context.Context) error {
 	if u.create.err != nil {
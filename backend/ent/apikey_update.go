@@ -100,6 +100,20 @@ func (_u *APIKeyUpdate) SetNillableName(v *string) *APIKeyUpdate {
 	return _u
 }
 
+// SetDescription sets the "description" field.
+func (_u *APIKeyUpdate) SetDescription(v string) *APIKeyUpdate {
+	_u.mutation.SetDescription(v)
+	return _u
+}
+
+// SetNillableDescription sets the "description" field if the given value is not nil.
+func (_u *APIKeyUpdate) SetNillableDescription(v *string) *APIKeyUpdate {
+	if v != nil {
+		_u.SetDescription(*v)
+	}
+	return _u
+}
+
 // SetGroupID sets the "group_id" field.
 func (_u *APIKeyUpdate) SetGroupID(v int64) *APIKeyUpdate {
 	_u.mutation.SetGroupID(v)
@@ -190,6 +204,158 @@ func (_u *APIKeyUpdate) ClearIPBlacklist() *APIKeyUpdate {
 	return _u
 }
 
+// SetScopes sets the "scopes" field.
+func (_u *APIKeyUpdate) SetScopes(v []string) *APIKeyUpdate {
+	_u.mutation.SetScopes(v)
+	return _u
+}
+
+// AppendScopes appends value to the "scopes" field.
+func (_u *APIKeyUpdate) AppendScopes(v []string) *APIKeyUpdate {
+	_u.mutation.AppendScopes(v)
+	return _u
+}
+
+// ClearScopes clears the value of the "scopes" field.
+func (_u *APIKeyUpdate) ClearScopes() *APIKeyUpdate {
+	_u.mutation.ClearScopes()
+	return _u
+}
+
+// SetAllowedModels sets the "allowed_models" field.
+func (_u *APIKeyUpdate) SetAllowedModels(v []string) *APIKeyUpdate {
+	_u.mutation.SetAllowedModels(v)
+	return _u
+}
+
+// AppendAllowedModels appends value to the "allowed_models" field.
+func (_u *APIKeyUpdate) AppendAllowedModels(v []string) *APIKeyUpdate {
+	_u.mutation.AppendAllowedModels(v)
+	return _u
+}
+
+// ClearAllowedModels clears the value of the "allowed_models" field.
+func (_u *APIKeyUpdate) ClearAllowedModels() *APIKeyUpdate {
+	_u.mutation.ClearAllowedModels()
+	return _u
+}
+
+// SetClientRestriction sets the "client_restriction" field.
+func (_u *APIKeyUpdate) SetClientRestriction(v string) *APIKeyUpdate {
+	_u.mutation.SetClientRestriction(v)
+	return _u
+}
+
+// SetNillableClientRestriction sets the "client_restriction" field if the given value is not nil.
+func (_u *APIKeyUpdate) SetNillableClientRestriction(v *string) *APIKeyUpdate {
+	if v != nil {
+		_u.SetClientRestriction(*v)
+	}
+	return _u
+}
+
+// SetTags sets the "tags" field.
+func (_u *APIKeyUpdate) SetTags(v []string) *APIKeyUpdate {
+	_u.mutation.SetTags(v)
+	return _u
+}
+
+// AppendTags appends value to the "tags" field.
+func (_u *APIKeyUpdate) AppendTags(v []string) *APIKeyUpdate {
+	_u.mutation.AppendTags(v)
+	return _u
+}
+
+// ClearTags clears the value of the "tags" field.
+func (_u *APIKeyUpdate) ClearTags() *APIKeyUpdate {
+	_u.mutation.ClearTags()
+	return _u
+}
+
+// SetDegradedFallbackModel sets the "degraded_fallback_model" field.
+func (_u *APIKeyUpdate) SetDegradedFallbackModel(v string) *APIKeyUpdate {
+	_u.mutation.SetDegradedFallbackModel(v)
+	return _u
+}
+
+// SetNillableDegradedFallbackModel sets the "degraded_fallback_model" field if the given value is not nil.
+func (_u *APIKeyUpdate) SetNillableDegradedFallbackModel(v *string) *APIKeyUpdate {
+	if v != nil {
+		_u.SetDegradedFallbackModel(*v)
+	}
+	return _u
+}
+
+// SetSystemPrompt sets the "system_prompt" field.
+func (_u *APIKeyUpdate) SetSystemPrompt(v string) *APIKeyUpdate {
+	_u.mutation.SetSystemPrompt(v)
+	return _u
+}
+
+// SetNillableSystemPrompt sets the "system_prompt" field if the given value is not nil.
+func (_u *APIKeyUpdate) SetNillableSystemPrompt(v *string) *APIKeyUpdate {
+	if v != nil {
+		_u.SetSystemPrompt(*v)
+	}
+	return _u
+}
+
+// SetSystemPromptMode sets the "system_prompt_mode" field.
+func (_u *APIKeyUpdate) SetSystemPromptMode(v string) *APIKeyUpdate {
+	_u.mutation.SetSystemPromptMode(v)
+	return _u
+}
+
+// SetNillableSystemPromptMode sets the "system_prompt_mode" field if the given value is not nil.
+func (_u *APIKeyUpdate) SetNillableSystemPromptMode(v *string) *APIKeyUpdate {
+	if v != nil {
+		_u.SetSystemPromptMode(*v)
+	}
+	return _u
+}
+
+// SetModerationMode sets the "moderation_mode" field.
+func (_u *APIKeyUpdate) SetModerationMode(v string) *APIKeyUpdate {
+	_u.mutation.SetModerationMode(v)
+	return _u
+}
+
+// SetNillableModerationMode sets the "moderation_mode" field if the given value is not nil.
+func (_u *APIKeyUpdate) SetNillableModerationMode(v *string) *APIKeyUpdate {
+	if v != nil {
+		_u.SetModerationMode(*v)
+	}
+	return _u
+}
+
+// SetLogContent sets the "log_content" field.
+func (_u *APIKeyUpdate) SetLogContent(v bool) *APIKeyUpdate {
+	_u.mutation.SetLogContent(v)
+	return _u
+}
+
+// SetNillableLogContent sets the "log_content" field if the given value is not nil.
+func (_u *APIKeyUpdate) SetNillableLogContent(v *bool) *APIKeyUpdate {
+	if v != nil {
+		_u.SetLogContent(*v)
+	}
+	return _u
+}
+
+// SetSimulate sets the "simulate" field.
+func (_u *APIKeyUpdate) SetSimulate(v bool) *APIKeyUpdate {
+	_u.mutation.SetSimulate(v)
+	return _u
+}
+
+// SetNillableSimulate sets the "simulate" field if the given value is not nil.
+func (_u *APIKeyUpdate) SetNillableSimulate(v *bool) *APIKeyUpdate {
+	if v != nil {
+		_u.SetSimulate(*v)
+	}
+	return _u
+}
+
 // SetQuota sets the "quota" field.
 func (_u *APIKeyUpdate) SetQuota(v float64) *APIKeyUpdate {
 	_u.mutation.ResetQuota()
@@ -232,6 +398,62 @@ func (_u *APIKeyUpdate) AddQuotaUsed(v float64) *APIKeyUpdate {
 	return _u
 }
 
+// SetImageQuota sets the "image_quota" field.
+func (_u *APIKeyUpdate) SetImageQuota(v int) *APIKeyUpdate {
+	_u.mutation.ResetImageQuota()
+	_u.mutation.SetImageQuota(v)
+	return _u
+}
+
+// SetNillableImageQuota sets the "image_quota" field if the given value is not nil.
+func (_u *APIKeyUpdate) SetNillableImageQuota(v *int) *APIKeyUpdate {
+	if v != nil {
+		_u.SetImageQuota(*v)
+	}
+	return _u
+}
+
+// AddImageQuota adds value to the "image_quota" field.
+func (_u *APIKeyUpdate) AddImageQuota(v int) *APIKeyUpdate {
+	_u.mutation.AddImageQuota(v)
+	return _u
+}
+
+// SetImageQuotaUsed sets the "image_quota_used" field.
+func (_u *APIKeyUpdate) SetImageQuotaUsed(v int) *APIKeyUpdate {
+	_u.mutation.ResetImageQuotaUsed()
+	_u.mutation.SetImageQuotaUsed(v)
+	return _u
+}
+
+// SetNillableImageQuotaUsed sets the "image_quota_used" field if the given value is not nil.
+func (_u *APIKeyUpdate) SetNillableImageQuotaUsed(v *int) *APIKeyUpdate {
+	if v != nil {
+		_u.SetImageQuotaUsed(*v)
+	}
+	return _u
+}
+
+// AddImageQuotaUsed adds value to the "image_quota_used" field.
+func (_u *APIKeyUpdate) AddImageQuotaUsed(v int) *APIKeyUpdate {
+	_u.mutation.AddImageQuotaUsed(v)
+	return _u
+}
+
+// SetSuppressReasoning sets the "suppress_reasoning" field.
+func (_u *APIKeyUpdate) SetSuppressReasoning(v bool) *APIKeyUpdate {
+	_u.mutation.SetSuppressReasoning(v)
+	return _u
+}
+
+// SetNillableSuppressReasoning sets the "suppress_reasoning" field if the given value is not nil.
+func (_u *APIKeyUpdate) SetNillableSuppressReasoning(v *bool) *APIKeyUpdate {
+	if v != nil {
+		_u.SetSuppressReasoning(*v)
+	}
+	return _u
+}
+
 // SetExpiresAt sets the "expires_at" field.
 func (_u *APIKeyUpdate) SetExpiresAt(v time.Time) *APIKeyUpdate {
 	_u.mutation.SetExpiresAt(v)
@@ -438,6 +660,296 @@ func (_u *APIKeyUpdate) ClearWindow7dStart() *APIKeyUpdate {
 	return _u
 }
 
+// SetRpmLimit sets the "rpm_limit" field.
+func (_u *APIKeyUpdate) SetRpmLimit(v int) *APIKeyUpdate {
+	_u.mutation.ResetRpmLimit()
+	_u.mutation.SetRpmLimit(v)
+	return _u
+}
+
+// SetNillableRpmLimit sets the "rpm_limit" field if the given value is not nil.
+func (_u *APIKeyUpdate) SetNillableRpmLimit(v *int) *APIKeyUpdate {
+	if v != nil {
+		_u.SetRpmLimit(*v)
+	}
+	return _u
+}
+
+// AddRpmLimit adds value to the "rpm_limit" field.
+func (_u *APIKeyUpdate) AddRpmLimit(v int) *APIKeyUpdate {
+	_u.mutation.AddRpmLimit(v)
+	return _u
+}
+
+// SetTpmLimit sets the "tpm_limit" field.
+func (_u *APIKeyUpdate) SetTpmLimit(v int) *APIKeyUpdate {
+	_u.mutation.ResetTpmLimit()
+	_u.mutation.SetTpmLimit(v)
+	return _u
+}
+
+// SetNillableTpmLimit sets the "tpm_limit" field if the given value is not nil.
+func (_u *APIKeyUpdate) SetNillableTpmLimit(v *int) *APIKeyUpdate {
+	if v != nil {
+		_u.SetTpmLimit(*v)
+	}
+	return _u
+}
+
+// AddTpmLimit adds value to the "tpm_limit" field.
+func (_u *APIKeyUpdate) AddTpmLimit(v int) *APIKeyUpdate {
+	_u.mutation.AddTpmLimit(v)
+	return _u
+}
+
+// SetDailyRequestLimit sets the "daily_request_limit" field.
+func (_u *APIKeyUpdate) SetDailyRequestLimit(v int) *APIKeyUpdate {
+	_u.mutation.ResetDailyRequestLimit()
+	_u.mutation.SetDailyRequestLimit(v)
+	return _u
+}
+
+// SetNillableDailyRequestLimit sets the "daily_request_limit" field if the given value is not nil.
+func (_u *APIKeyUpdate) SetNillableDailyRequestLimit(v *int) *APIKeyUpdate {
+	if v != nil {
+		_u.SetDailyRequestLimit(*v)
+	}
+	return _u
+}
+
+// AddDailyRequestLimit adds value to the "daily_request_limit" field.
+func (_u *APIKeyUpdate) AddDailyRequestLimit(v int) *APIKeyUpdate {
+	_u.mutation.AddDailyRequestLimit(v)
+	return _u
+}
+
+// SetDailyTokenLimit sets the "daily_token_limit" field.
+func (_u *APIKeyUpdate) SetDailyTokenLimit(v int64) *APIKeyUpdate {
+	_u.mutation.ResetDailyTokenLimit()
+	_u.mutation.SetDailyTokenLimit(v)
+	return _u
+}
+
+// SetNillableDailyTokenLimit sets the "daily_token_limit" field if the given value is not nil.
+func (_u *APIKeyUpdate) SetNillableDailyTokenLimit(v *int64) *APIKeyUpdate {
+	if v != nil {
+		_u.SetDailyTokenLimit(*v)
+	}
+	return _u
+}
+
+// AddDailyTokenLimit adds value to the "daily_token_limit" field.
+func (_u *APIKeyUpdate) AddDailyTokenLimit(v int64) *APIKeyUpdate {
+	_u.mutation.AddDailyTokenLimit(v)
+	return _u
+}
+
+// SetMonthlyRequestLimit sets the "monthly_request_limit" field.
+func (_u *APIKeyUpdate) SetMonthlyRequestLimit(v int) *APIKeyUpdate {
+	_u.mutation.ResetMonthlyRequestLimit()
+	_u.mutation.SetMonthlyRequestLimit(v)
+	return _u
+}
+
+// SetNillableMonthlyRequestLimit sets the "monthly_request_limit" field if the given value is not nil.
+func (_u *APIKeyUpdate) SetNillableMonthlyRequestLimit(v *int) *APIKeyUpdate {
+	if v != nil {
+		_u.SetMonthlyRequestLimit(*v)
+	}
+	return _u
+}
+
+// AddMonthlyRequestLimit adds value to the "monthly_request_limit" field.
+func (_u *APIKeyUpdate) AddMonthlyRequestLimit(v int) *APIKeyUpdate {
+	_u.mutation.AddMonthlyRequestLimit(v)
+	return _u
+}
+
+// SetMonthlyTokenLimit sets the "monthly_token_limit" field.
+func (_u *APIKeyUpdate) SetMonthlyTokenLimit(v int64) *APIKeyUpdate {
+	_u.mutation.ResetMonthlyTokenLimit()
+	_u.mutation.SetMonthlyTokenLimit(v)
+	return _u
+}
+
+// SetNillableMonthlyTokenLimit sets the "monthly_token_limit" field if the given value is not nil.
+func (_u *APIKeyUpdate) SetNillableMonthlyTokenLimit(v *int64) *APIKeyUpdate {
+	if v != nil {
+		_u.SetMonthlyTokenLimit(*v)
+	}
+	return _u
+}
+
+// AddMonthlyTokenLimit adds value to the "monthly_token_limit" field.
+func (_u *APIKeyUpdate) AddMonthlyTokenLimit(v int64) *APIKeyUpdate {
+	_u.mutation.AddMonthlyTokenLimit(v)
+	return _u
+}
+
+// SetBudgetAmount sets the "budget_amount" field.
+func (_u *APIKeyUpdate) SetBudgetAmount(v float64) *APIKeyUpdate {
+	_u.mutation.ResetBudgetAmount()
+	_u.mutation.SetBudgetAmount(v)
+	return _u
+}
+
+// SetNillableBudgetAmount sets the "budget_amount" field if the given value is not nil.
+func (_u *APIKeyUpdate) SetNillableBudgetAmount(v *float64) *APIKeyUpdate {
+	if v != nil {
+		_u.SetBudgetAmount(*v)
+	}
+	return _u
+}
+
+// AddBudgetAmount adds value to the "budget_amount" field.
+func (_u *APIKeyUpdate) AddBudgetAmount(v float64) *APIKeyUpdate {
+	_u.mutation.AddBudgetAmount(v)
+	return _u
+}
+
+// SetBudgetPeriod sets the "budget_period" field.
+func (_u *APIKeyUpdate) SetBudgetPeriod(v string) *APIKeyUpdate {
+	_u.mutation.SetBudgetPeriod(v)
+	return _u
+}
+
+// SetNillableBudgetPeriod sets the "budget_period" field if the given value is not nil.
+func (_u *APIKeyUpdate) SetNillableBudgetPeriod(v *string) *APIKeyUpdate {
+	if v != nil {
+		_u.SetBudgetPeriod(*v)
+	}
+	return _u
+}
+
+// SetBudgetAction sets the "budget_action" field.
+func (_u *APIKeyUpdate) SetBudgetAction(v string) *APIKeyUpdate {
+	_u.mutation.SetBudgetAction(v)
+	return _u
+}
+
+// SetNillableBudgetAction sets the "budget_action" field if the given value is not nil.
+func (_u *APIKeyUpdate) SetNillableBudgetAction(v *string) *APIKeyUpdate {
+	if v != nil {
+		_u.SetBudgetAction(*v)
+	}
+	return _u
+}
+
+// SetBudgetFallbackModel sets the "budget_fallback_model" field.
+func (_u *APIKeyUpdate) SetBudgetFallbackModel(v string) *APIKeyUpdate {
+	_u.mutation.SetBudgetFallbackModel(v)
+	return _u
+}
+
+// SetNillableBudgetFallbackModel sets the "budget_fallback_model" field if the given value is not nil.
+func (_u *APIKeyUpdate) SetNillableBudgetFallbackModel(v *string) *APIKeyUpdate {
+	if v != nil {
+		_u.SetBudgetFallbackModel(*v)
+	}
+	return _u
+}
+
+// SetBudgetUsed sets the "budget_used" field.
+func (_u *APIKeyUpdate) SetBudgetUsed(v float64) *APIKeyUpdate {
+	_u.mutation.ResetBudgetUsed()
+	_u.mutation.SetBudgetUsed(v)
+	return _u
+}
+
+// SetNillableBudgetUsed sets the "budget_used" field if the given value is not nil.
+func (_u *APIKeyUpdate) SetNillableBudgetUsed(v *float64) *APIKeyUpdate {
+	if v != nil {
+		_u.SetBudgetUsed(*v)
+	}
+	return _u
+}
+
+// AddBudgetUsed adds value to the "budget_used" field.
+func (_u *APIKeyUpdate) AddBudgetUsed(v float64) *APIKeyUpdate {
+	_u.mutation.AddBudgetUsed(v)
+	return _u
+}
+
+// SetBudgetPeriodStart sets the "budget_period_start" field.
+func (_u *APIKeyUpdate) SetBudgetPeriodStart(v time.Time) *APIKeyUpdate {
+	_u.mutation.SetBudgetPeriodStart(v)
+	return _u
+}
+
+// SetNillableBudgetPeriodStart sets the "budget_period_start" field if the given value is not nil.
+func (_u *APIKeyUpdate) SetNillableBudgetPeriodStart(v *time.Time) *APIKeyUpdate {
+	if v != nil {
+		_u.SetBudgetPeriodStart(*v)
+	}
+	return _u
+}
+
+// ClearBudgetPeriodStart clears the value of the "budget_period_start" field.
+func (_u *APIKeyUpdate) ClearBudgetPeriodStart() *APIKeyUpdate {
+	_u.mutation.ClearBudgetPeriodStart()
+	return _u
+}
+
+// SetBudgetExceededAt sets the "budget_exceeded_at" field.
+func (_u *APIKeyUpdate) SetBudgetExceededAt(v time.Time) *APIKeyUpdate {
+	_u.mutation.SetBudgetExceededAt(v)
+	return _u
+}
+
+// SetNillableBudgetExceededAt sets the "budget_exceeded_at" field if the given value is not nil.
+func (_u *APIKeyUpdate) SetNillableBudgetExceededAt(v *time.Time) *APIKeyUpdate {
+	if v != nil {
+		_u.SetBudgetExceededAt(*v)
+	}
+	return _u
+}
+
+// ClearBudgetExceededAt clears the value of the "budget_exceeded_at" field.
+func (_u *APIKeyUpdate) ClearBudgetExceededAt() *APIKeyUpdate {
+	_u.mutation.ClearBudgetExceededAt()
+	return _u
+}
+
+// SetPreviousKey sets the "previous_key" field.
+func (_u *APIKeyUpdate) SetPreviousKey(v string) *APIKeyUpdate {
+	_u.mutation.SetPreviousKey(v)
+	return _u
+}
+
+// SetNillablePreviousKey sets the "previous_key" field if the given value is not nil.
+func (_u *APIKeyUpdate) SetNillablePreviousKey(v *string) *APIKeyUpdate {
+	if v != nil {
+		_u.SetPreviousKey(*v)
+	}
+	return _u
+}
+
+// ClearPreviousKey clears the value of the "previous_key" field.
+func (_u *APIKeyUpdate) ClearPreviousKey() *APIKeyUpdate {
+	_u.mutation.ClearPreviousKey()
+	return _u
+}
+
+// SetPreviousKeyExpiresAt sets the "previous_key_expires_at" field.
+func (_u *APIKeyUpdate) SetPreviousKeyExpiresAt(v time.Time) *APIKeyUpdate {
+	_u.mutation.SetPreviousKeyExpiresAt(v)
+	return _u
+}
+
+// SetNillablePreviousKeyExpiresAt sets the "previous_key_expires_at" field if the given value is not nil.
+func (_u *APIKeyUpdate) SetNillablePreviousKeyExpiresAt(v *time.Time) *APIKeyUpdate {
+	if v != nil {
+		_u.SetPreviousKeyExpiresAt(*v)
+	}
+	return _u
+}
+
+// ClearPreviousKeyExpiresAt clears the value of the "previous_key_expires_at" field.
+func (_u *APIKeyUpdate) ClearPreviousKeyExpiresAt() *APIKeyUpdate {
+	_u.mutation.ClearPreviousKeyExpiresAt()
+	return _u
+}
+
 // SetUser sets the "user" edge to the User entity.
 func (_u *APIKeyUpdate) SetUser(v *User) *APIKeyUpdate {
 	return _u.SetUserID(v.ID)
@@ -555,11 +1067,56 @@ func (_u *APIKeyUpdate) check() error {
 			return &ValidationError{Name: "name", err: fmt.Errorf(`ent: validator failed for field "APIKey.name": %w`, err)}
 		}
 	}
+	if v, ok := _u.mutation.Description(); ok {
+		if err := apikey.DescriptionValidator(v); err != nil {
+			return &ValidationError{Name: "description", err: fmt.Errorf(`ent: validator failed for field "APIKey.description": %w`, err)}
+		}
+	}
 	if v, ok := _u.mutation.Status(); ok {
 		if err := apikey.StatusValidator(v); err != nil {
 			return &ValidationError{Name: "status", err: fmt.Errorf(`ent: validator failed for field "APIKey.status": %w`, err)}
 		}
 	}
+	if v, ok := _u.mutation.ClientRestriction(); ok {
+		if err := apikey.ClientRestrictionValidator(v); err != nil {
+			return &ValidationError{Name: "client_restriction", err: fmt.Errorf(`ent: validator failed for field "APIKey.client_restriction": %w`, err)}
+		}
+	}
+	if v, ok := _u.mutation.DegradedFallbackModel(); ok {
+		if err := apikey.DegradedFallbackModelValidator(v); err != nil {
+			return &ValidationError{Name: "degraded_fallback_model", err: fmt.Errorf(`ent: validator failed for field "APIKey.degraded_fallback_model": %w`, err)}
+		}
+	}
+	if v, ok := _u.mutation.SystemPromptMode(); ok {
+		if err := apikey.SystemPromptModeValidator(v); err != nil {
+			return &ValidationError{Name: "system_prompt_mode", err: fmt.Errorf(`ent: validator failed for field "APIKey.system_prompt_mode": %w`, err)}
+		}
+	}
+	if v, ok := _u.mutation.ModerationMode(); ok {
+		if err := apikey.ModerationModeValidator(v); err != nil {
+			return &ValidationError{Name: "moderation_mode", err: fmt.Errorf(`ent: validator failed for field "APIKey.moderation_mode": %w`, err)}
+		}
+	}
+	if v, ok := _u.mutation.BudgetPeriod(); ok {
+		if err := apikey.BudgetPeriodValidator(v); err != nil {
+			return &ValidationError{Name: "budget_period", err: fmt.Errorf(`ent: validator failed for field "APIKey.budget_period": %w`, err)}
+		}
+	}
+	if v, ok := _u.mutation.BudgetAction(); ok {
+		if err := apikey.BudgetActionValidator(v); err != nil {
+			return &ValidationError{Name: "budget_action", err: fmt.Errorf(`ent: validator failed for field "APIKey.budget_action": %w`, err)}
+		}
+	}
+	if v, ok := _u.mutation.BudgetFallbackModel(); ok {
+		if err := apikey.BudgetFallbackModelValidator(v); err != nil {
+			return &ValidationError{Name: "budget_fallback_model", err: fmt.Errorf(`ent: validator failed for field "APIKey.budget_fallback_model": %w`, err)}
+		}
+	}
+	if v, ok := _u.mutation.PreviousKey(); ok {
+		if err := apikey.PreviousKeyValidator(v); err != nil {
+			return &ValidationError{Name: "previous_key", err: fmt.Errorf(`ent: validator failed for field "APIKey.previous_key": %w`, err)}
+		}
+	}
 	if _u.mutation.UserCleared() && len(_u.mutation.UserIDs()) > 0 {
 		return errors.New(`ent: clearing a required unique edge "APIKey.user"`)
 	}
@@ -593,6 +1150,9 @@ func (_u *APIKeyUpdate) sqlSave(ctx context.Context) (_node int, err error) {
 	if value, ok := _u.mutation.Name(); ok {
 		_spec.SetField(apikey.FieldName, field.TypeString, value)
 	}
+	if value, ok := _u.mutation.Description(); ok {
+		_spec.SetField(apikey.FieldDescription, field.TypeString, value)
+	}
 	if value, ok := _u.mutation.Status(); ok {
 		_spec.SetField(apikey.FieldStatus, field.TypeString, value)
 	}
@@ -624,6 +1184,60 @@ func (_u *APIKeyUpdate) sqlSave(ctx context.Context) (_node int, err error) {
 	if _u.mutation.IPBlacklistCleared() {
 		_spec.ClearField(apikey.FieldIPBlacklist, field.TypeJSON)
 	}
+	if value, ok := _u.mutation.Scopes(); ok {
+		_spec.SetField(apikey.FieldScopes, field.TypeJSON, value)
+	}
+	if value, ok := _u.mutation.AppendedScopes(); ok {
+		_spec.AddModifier(func(u *sql.UpdateBuilder) {
+			sqljson.Append(u, apikey.FieldScopes, value)
+		})
+	}
+	if _u.mutation.ScopesCleared() {
+		_spec.ClearField(apikey.FieldScopes, field.TypeJSON)
+	}
+	if value, ok := _u.mutation.AllowedModels(); ok {
+		_spec.SetField(apikey.FieldAllowedModels, field.TypeJSON, value)
+	}
+	if value, ok := _u.mutation.AppendedAllowedModels(); ok {
+		_spec.AddModifier(func(u *sql.UpdateBuilder) {
+			sqljson.Append(u, apikey.FieldAllowedModels, value)
+		})
+	}
+	if _u.mutation.AllowedModelsCleared() {
+		_spec.ClearField(apikey.FieldAllowedModels, field.TypeJSON)
+	}
+	if value, ok := _u.mutation.ClientRestriction(); ok {
+		_spec.SetField(apikey.FieldClientRestriction, field.TypeString, value)
+	}
+	if value, ok := _u.mutation.Tags(); ok {
+		_spec.SetField(apikey.FieldTags, field.TypeJSON, value)
+	}
+	if value, ok := _u.mutation.AppendedTags(); ok {
+		_spec.AddModifier(func(u *sql.UpdateBuilder) {
+			sqljson.Append(u, apikey.FieldTags, value)
+		})
+	}
+	if _u.mutation.TagsCleared() {
+		_spec.ClearField(apikey.FieldTags, field.TypeJSON)
+	}
+	if value, ok := _u.mutation.DegradedFallbackModel(); ok {
+		_spec.SetField(apikey.FieldDegradedFallbackModel, field.TypeString, value)
+	}
+	if value, ok := _u.mutation.SystemPrompt(); ok {
+		_spec.SetField(apikey.FieldSystemPrompt, field.TypeString, value)
+	}
+	if value, ok := _u.mutation.SystemPromptMode(); ok {
+		_spec.SetField(apikey.FieldSystemPromptMode, field.TypeString, value)
+	}
+	if value, ok := _u.mutation.ModerationMode(); ok {
+		_spec.SetField(apikey.FieldModerationMode, field.TypeString, value)
+	}
+	if value, ok := _u.mutation.LogContent(); ok {
+		_spec.SetField(apikey.FieldLogContent, field.TypeBool, value)
+	}
+	if value, ok := _u.mutation.Simulate(); ok {
+		_spec.SetField(apikey.FieldSimulate, field.TypeBool, value)
+	}
 	if value, ok := _u.mutation.Quota(); ok {
 		_spec.SetField(apikey.FieldQuota, field.TypeFloat64, value)
 	}
@@ -636,6 +1250,21 @@ func (_u *APIKeyUpdate) sqlSave(ctx context.Context) (_node int, err error) {
 	if value, ok := _u.mutation.AddedQuotaUsed(); ok {
 		_spec.AddField(apikey.FieldQuotaUsed, field.TypeFloat64, value)
 	}
+	if value, ok := _u.mutation.ImageQuota(); ok {
+		_spec.SetField(apikey.FieldImageQuota, field.TypeInt, value)
+	}
+	if value, ok := _u.mutation.AddedImageQuota(); ok {
+		_spec.AddField(apikey.FieldImageQuota, field.TypeInt, value)
+	}
+	if value, ok := _u.mutation.ImageQuotaUsed(); ok {
+		_spec.SetField(apikey.FieldImageQuotaUsed, field.TypeInt, value)
+	}
+	if value, ok := _u.mutation.AddedImageQuotaUsed(); ok {
+		_spec.AddField(apikey.FieldImageQuotaUsed, field.TypeInt, value)
+	}
+	if value, ok := _u.mutation.SuppressReasoning(); ok {
+		_spec.SetField(apikey.FieldSuppressReasoning, field.TypeBool, value)
+	}
 	if value, ok := _u.mutation.ExpiresAt(); ok {
 		_spec.SetField(apikey.FieldExpiresAt, field.TypeTime, value)
 	}
@@ -696,6 +1325,87 @@ func (_u *APIKeyUpdate) sqlSave(ctx context.Context) (_node int, err error) {
 	if _u.mutation.Window7dStartCleared() {
 		_spec.ClearField(apikey.FieldWindow7dStart, field.TypeTime)
 	}
+	if value, ok := _u.mutation.RpmLimit(); ok {
+		_spec.SetField(apikey.FieldRpmLimit, field.TypeInt, value)
+	}
+	if value, ok := _u.mutation.AddedRpmLimit(); ok {
+		_spec.AddField(apikey.FieldRpmLimit, field.TypeInt, value)
+	}
+	if value, ok := _u.mutation.TpmLimit(); ok {
+		_spec.SetField(apikey.FieldTpmLimit, field.TypeInt, value)
+	}
+	if value, ok := _u.mutation.AddedTpmLimit(); ok {
+		_spec.AddField(apikey.FieldTpmLimit, field.TypeInt, value)
+	}
+	if value, ok := _u.mutation.DailyRequestLimit(); ok {
+		_spec.SetField(apikey.FieldDailyRequestLimit, field.TypeInt, value)
+	}
+	if value, ok := _u.mutation.AddedDailyRequestLimit(); ok {
+		_spec.AddField(apikey.FieldDailyRequestLimit, field.TypeInt, value)
+	}
+	if value, ok := _u.mutation.DailyTokenLimit(); ok {
+		_spec.SetField(apikey.FieldDailyTokenLimit, field.TypeInt64, value)
+	}
+	if value, ok := _u.mutation.AddedDailyTokenLimit(); ok {
+		_spec.AddField(apikey.FieldDailyTokenLimit, field.TypeInt64, value)
+	}
+	if value, ok := _u.mutation.MonthlyRequestLimit(); ok {
+		_spec.SetField(apikey.FieldMonthlyRequestLimit, field.TypeInt, value)
+	}
+	if value, ok := _u.mutation.AddedMonthlyRequestLimit(); ok {
+		_spec.AddField(apikey.FieldMonthlyRequestLimit, field.TypeInt, value)
+	}
+	if value, ok := _u.mutation.MonthlyTokenLimit(); ok {
+		_spec.SetField(apikey.FieldMonthlyTokenLimit, field.TypeInt64, value)
+	}
+	if value, ok := _u.mutation.AddedMonthlyTokenLimit(); ok {
+		_spec.AddField(apikey.FieldMonthlyTokenLimit, field.TypeInt64, value)
+	}
+	if value, ok := _u.mutation.BudgetAmount(); ok {
+		_spec.SetField(apikey.FieldBudgetAmount, field.TypeFloat64, value)
+	}
+	if value, ok := _u.mutation.AddedBudgetAmount(); ok {
+		_spec.AddField(apikey.FieldBudgetAmount, field.TypeFloat64, value)
+	}
+	if value, ok := _u.mutation.BudgetPeriod(); ok {
+		_spec.SetField(apikey.FieldBudgetPeriod, field.TypeString, value)
+	}
+	if value, ok := _u.mutation.BudgetAction(); ok {
+		_spec.SetField(apikey.FieldBudgetAction, field.TypeString, value)
+	}
+	if value, ok := _u.mutation.BudgetFallbackModel(); ok {
+		_spec.SetField(apikey.FieldBudgetFallbackModel, field.TypeString, value)
+	}
+	if value, ok := _u.mutation.BudgetUsed(); ok {
+		_spec.SetField(apikey.FieldBudgetUsed, field.TypeFloat64, value)
+	}
+	if value, ok := _u.mutation.AddedBudgetUsed(); ok {
+		_spec.AddField(apikey.FieldBudgetUsed, field.TypeFloat64, value)
+	}
+	if value, ok := _u.mutation.BudgetPeriodStart(); ok {
+		_spec.SetField(apikey.FieldBudgetPeriodStart, field.TypeTime, value)
+	}
+	if _u.mutation.BudgetPeriodStartCleared() {
+		_spec.ClearField(apikey.FieldBudgetPeriodStart, field.TypeTime)
+	}
+	if value, ok := _u.mutation.BudgetExceededAt(); ok {
+		_spec.SetField(apikey.FieldBudgetExceededAt, field.TypeTime, value)
+	}
+	if _u.mutation.BudgetExceededAtCleared() {
+		_spec.ClearField(apikey.FieldBudgetExceededAt, field.TypeTime)
+	}
+	if value, ok := _u.mutation.PreviousKey(); ok {
+		_spec.SetField(apikey.FieldPreviousKey, field.TypeString, value)
+	}
+	if _u.mutation.PreviousKeyCleared() {
+		_spec.ClearField(apikey.FieldPreviousKey, field.TypeString)
+	}
+	if value, ok := _u.mutation.PreviousKeyExpiresAt(); ok {
+		_spec.SetField(apikey.FieldPreviousKeyExpiresAt, field.TypeTime, value)
+	}
+	if _u.mutation.PreviousKeyExpiresAtCleared() {
+		_spec.ClearField(apikey.FieldPreviousKeyExpiresAt, field.TypeTime)
+	}
 	if _u.mutation.UserCleared() {
 		edge := &sqlgraph.EdgeSpec{
 			Rel:     sqlgraph.M2O,
@@ -887,6 +1597,20 @@ func (_u *APIKeyUpdateOne) SetNillableName(v *string) *APIKeyUpdateOne {
 	return _u
 }
 
+// SetDescription sets the "description" field.
+func (_u *APIKeyUpdateOne) SetDescription(v string) *APIKeyUpdateOne {
+	_u.mutation.SetDescription(v)
+	return _u
+}
+
+// SetNillableDescription sets the "description" field if the given value is not nil.
+func (_u *APIKeyUpdateOne) SetNillableDescription(v *string) *APIKeyUpdateOne {
+	if v != nil {
+		_u.SetDescription(*v)
+	}
+	return _u
+}
+
 // SetGroupID sets the "group_id" field.
 func (_u *APIKeyUpdateOne) SetGroupID(v int64) *APIKeyUpdateOne {
 	_u.mutation.SetGroupID(v)
@@ -971,54 +1695,262 @@ func (_u *APIKeyUpdateOne) AppendIPBlacklist(v []string) *APIKeyUpdateOne {
 	return _u
 }
 
-// ClearIPBlacklist clears the value of the "ip_blacklist" field.
-func (_u *APIKeyUpdateOne) ClearIPBlacklist() *APIKeyUpdateOne {
-	_u.mutation.ClearIPBlacklist()
+// ClearIPBlacklist clears the value of the "ip_blacklist" field.
+func (_u *APIKeyUpdateOne) ClearIPBlacklist() *APIKeyUpdateOne {
+	_u.mutation.ClearIPBlacklist()
+	return _u
+}
+
+// SetScopes sets the "scopes" field.
+func (_u *APIKeyUpdateOne) SetScopes(v []string) *APIKeyUpdateOne {
+	_u.mutation.SetScopes(v)
+	return _u
+}
+
+// AppendScopes appends value to the "scopes" field.
+func (_u *APIKeyUpdateOne) AppendScopes(v []string) *APIKeyUpdateOne {
+	_u.mutation.AppendScopes(v)
+	return _u
+}
+
+// ClearScopes clears the value of the "scopes" field.
+func (_u *APIKeyUpdateOne) ClearScopes() *APIKeyUpdateOne {
+	_u.mutation.ClearScopes()
+	return _u
+}
+
+// SetAllowedModels sets the "allowed_models" field.
+func (_u *APIKeyUpdateOne) SetAllowedModels(v []string) *APIKeyUpdateOne {
+	_u.mutation.SetAllowedModels(v)
+	return _u
+}
+
+// AppendAllowedModels appends value to the "allowed_models" field.
+func (_u *APIKeyUpdateOne) AppendAllowedModels(v []string) *APIKeyUpdateOne {
+	_u.mutation.AppendAllowedModels(v)
+	return _u
+}
+
+// ClearAllowedModels clears the value of the "allowed_models" field.
+func (_u *APIKeyUpdateOne) ClearAllowedModels() *APIKeyUpdateOne {
+	_u.mutation.ClearAllowedModels()
+	return _u
+}
+
+// SetClientRestriction sets the "client_restriction" field.
+func (_u *APIKeyUpdateOne) SetClientRestriction(v string) *APIKeyUpdateOne {
+	_u.mutation.SetClientRestriction(v)
+	return _u
+}
+
+// SetNillableClientRestriction sets the "client_restriction" field if the given value is not nil.
+func (_u *APIKeyUpdateOne) SetNillableClientRestriction(v *string) *APIKeyUpdateOne {
+	if v != nil {
+		_u.SetClientRestriction(*v)
+	}
+	return _u
+}
+
+// SetTags sets the "tags" field.
+func (_u *APIKeyUpdateOne) SetTags(v []string) *APIKeyUpdateOne {
+	_u.mutation.SetTags(v)
+	return _u
+}
+
+// AppendTags appends value to the "tags" field.
+func (_u *APIKeyUpdateOne) AppendTags(v []string) *APIKeyUpdateOne {
+	_u.mutation.AppendTags(v)
+	return _u
+}
+
+// ClearTags clears the value of the "tags" field.
+func (_u *APIKeyUpdateOne) ClearTags() *APIKeyUpdateOne {
+	_u.mutation.ClearTags()
+	return _u
+}
+
+// SetDegradedFallbackModel sets the "degraded_fallback_model" field.
+func (_u *APIKeyUpdateOne) SetDegradedFallbackModel(v string) *APIKeyUpdateOne {
+	_u.mutation.SetDegradedFallbackModel(v)
+	return _u
+}
+
+// SetNillableDegradedFallbackModel sets the "degraded_fallback_model" field if the given value is not nil.
+func (_u *APIKeyUpdateOne) SetNillableDegradedFallbackModel(v *string) *APIKeyUpdateOne {
+	if v != nil {
+		_u.SetDegradedFallbackModel(*v)
+	}
+	return _u
+}
+
+// SetSystemPrompt sets the "system_prompt" field.
+func (_u *APIKeyUpdateOne) SetSystemPrompt(v string) *APIKeyUpdateOne {
+	_u.mutation.SetSystemPrompt(v)
+	return _u
+}
+
+// SetNillableSystemPrompt sets the "system_prompt" field if the given value is not nil.
+func (_u *APIKeyUpdateOne) SetNillableSystemPrompt(v *string) *APIKeyUpdateOne {
+	if v != nil {
+		_u.SetSystemPrompt(*v)
+	}
+	return _u
+}
+
+// SetSystemPromptMode sets the "system_prompt_mode" field.
+func (_u *APIKeyUpdateOne) SetSystemPromptMode(v string) *APIKeyUpdateOne {
+	_u.mutation.SetSystemPromptMode(v)
+	return _u
+}
+
+// SetNillableSystemPromptMode sets the "system_prompt_mode" field if the given value is not nil.
+func (_u *APIKeyUpdateOne) SetNillableSystemPromptMode(v *string) *APIKeyUpdateOne {
+	if v != nil {
+		_u.SetSystemPromptMode(*v)
+	}
+	return _u
+}
+
+// SetModerationMode sets the "moderation_mode" field.
+func (_u *APIKeyUpdateOne) SetModerationMode(v string) *APIKeyUpdateOne {
+	_u.mutation.SetModerationMode(v)
+	return _u
+}
+
+// SetNillableModerationMode sets the "moderation_mode" field if the given value is not nil.
+func (_u *APIKeyUpdateOne) SetNillableModerationMode(v *string) *APIKeyUpdateOne {
+	if v != nil {
+		_u.SetModerationMode(*v)
+	}
+	return _u
+}
+
+// SetLogContent sets the "log_content" field.
+func (_u *APIKeyUpdateOne) SetLogContent(v bool) *APIKeyUpdateOne {
+	_u.mutation.SetLogContent(v)
+	return _u
+}
+
+// SetNillableLogContent sets the "log_content" field if the given value is not nil.
+func (_u *APIKeyUpdateOne) SetNillableLogContent(v *bool) *APIKeyUpdateOne {
+	if v != nil {
+		_u.SetLogContent(*v)
+	}
+	return _u
+}
+
+// SetSimulate sets the "simulate" field.
+func (_u *APIKeyUpdateOne) SetSimulate(v bool) *APIKeyUpdateOne {
+	_u.mutation.SetSimulate(v)
+	return _u
+}
+
+// SetNillableSimulate sets the "simulate" field if the given value is not nil.
+func (_u *APIKeyUpdateOne) SetNillableSimulate(v *bool) *APIKeyUpdateOne {
+	if v != nil {
+		_u.SetSimulate(*v)
+	}
+	return _u
+}
+
+// SetQuota sets the "quota" field.
+func (_u *APIKeyUpdateOne) SetQuota(v float64) *APIKeyUpdateOne {
+	_u.mutation.ResetQuota()
+	_u.mutation.SetQuota(v)
+	return _u
+}
+
+// SetNillableQuota sets the "quota" field if the given value is not nil.
+func (_u *APIKeyUpdateOne) SetNillableQuota(v *float64) *APIKeyUpdateOne {
+	if v != nil {
+		_u.SetQuota(*v)
+	}
+	return _u
+}
+
+// AddQuota adds value to the "quota" field.
+func (_u *APIKeyUpdateOne) AddQuota(v float64) *APIKeyUpdateOne {
+	_u.mutation.AddQuota(v)
+	return _u
+}
+
+// SetQuotaUsed sets the "quota_used" field.
+func (_u *APIKeyUpdateOne) SetQuotaUsed(v float64) *APIKeyUpdateOne {
+	_u.mutation.ResetQuotaUsed()
+	_u.mutation.SetQuotaUsed(v)
+	return _u
+}
+
+// SetNillableQuotaUsed sets the "quota_used" field if the given value is not nil.
+func (_u *APIKeyUpdateOne) SetNillableQuotaUsed(v *float64) *APIKeyUpdateOne {
+	if v != nil {
+		_u.SetQuotaUsed(*v)
+	}
+	return _u
+}
+
+// AddQuotaUsed adds value to the "quota_used" field.
+func (_u *APIKeyUpdateOne) AddQuotaUsed(v float64) *APIKeyUpdateOne {
+	_u.mutation.AddQuotaUsed(v)
+	return _u
+}
+
+// SetImageQuota sets the "image_quota" field.
+func (_u *APIKeyUpdateOne) SetImageQuota(v int) *APIKeyUpdateOne {
+	_u.mutation.ResetImageQuota()
+	_u.mutation.SetImageQuota(v)
+	return _u
+}
+
+// SetNillableImageQuota sets the "image_quota" field if the given value is not nil.
+func (_u *APIKeyUpdateOne) SetNillableImageQuota(v *int) *APIKeyUpdateOne {
+	if v != nil {
+		_u.SetImageQuota(*v)
+	}
+	return _u
+}
+
+// AddImageQuota adds value to the "image_quota" field.
+func (_u *APIKeyUpdateOne) AddImageQuota(v int) *APIKeyUpdateOne {
+	_u.mutation.AddImageQuota(v)
 	return _u
 }
 
-// SetQuota sets the "quota" field.
-func (_u *APIKeyUpdateOne) SetQuota(v float64) *APIKeyUpdateOne {
-	_u.mutation.ResetQuota()
-	_u.mutation.SetQuota(v)
+// SetImageQuotaUsed sets the "image_quota_used" field.
+func (_u *APIKeyUpdateOne) SetImageQuotaUsed(v int) *APIKeyUpdateOne {
+	_u.mutation.ResetImageQuotaUsed()
+	_u.mutation.SetImageQuotaUsed(v)
 	return _u
 }
 
-// SetNillableQuota sets the "quota" field if the given value is not nil.
-func (_u *APIKeyUpdateOne) SetNillableQuota(v *float64) *APIKeyUpdateOne {
+// SetNillableImageQuotaUsed sets the "image_quota_used" field if the given value is not nil.
+func (_u *APIKeyUpdateOne) SetNillableImageQuotaUsed(v *int) *APIKeyUpdateOne {
 	if v != nil {
-		_u.SetQuota(*v)
+		_u.SetImageQuotaUsed(*v)
 	}
 	return _u
 }
 
-// AddQuota adds value to the "quota" field.
-func (_u *APIKeyUpdateOne) AddQuota(v float64) *APIKeyUpdateOne {
-	_u.mutation.AddQuota(v)
+// AddImageQuotaUsed adds value to the "image_quota_used" field.
+func (_u *APIKeyUpdateOne) AddImageQuotaUsed(v int) *APIKeyUpdateOne {
+	_u.mutation.AddImageQuotaUsed(v)
 	return _u
 }
 
-// SetQuotaUsed sets the "quota_used" field.
-func (_u *APIKeyUpdateOne) SetQuotaUsed(v float64) *APIKeyUpdateOne {
-	_u.mutation.ResetQuotaUsed()
-	_u.mutation.SetQuotaUsed(v)
+// SetSuppressReasoning sets the "suppress_reasoning" field.
+func (_u *APIKeyUpdateOne) SetSuppressReasoning(v bool) *APIKeyUpdateOne {
+	_u.mutation.SetSuppressReasoning(v)
 	return _u
 }
 
-// SetNillableQuotaUsed sets the "quota_used" field if the given value is not nil.
-func (_u *APIKeyUpdateOne) SetNillableQuotaUsed(v *float64) *APIKeyUpdateOne {
+// SetNillableSuppressReasoning sets the "suppress_reasoning" field if the given value is not nil.
+func (_u *APIKeyUpdateOne) SetNillableSuppressReasoning(v *bool) *APIKeyUpdateOne {
 	if v != nil {
-		_u.SetQuotaUsed(*v)
+		_u.SetSuppressReasoning(*v)
 	}
 	return _u
 }
 
-// AddQuotaUsed adds value to the "quota_used" field.
-func (_u *APIKeyUpdateOne) AddQuotaUsed(v float64) *APIKeyUpdateOne {
-	_u.mutation.AddQuotaUsed(v)
-	return _u
-}
-
 // SetExpiresAt sets the "expires_at" field.
 func (_u *APIKeyUpdateOne) SetExpiresAt(v time.Time) *APIKeyUpdateOne {
 	_u.mutation.SetExpiresAt(v)
@@ -1225,6 +2157,296 @@ func (_u *APIKeyUpdateOne) ClearWindow7dStart() *APIKeyUpdateOne {
 	return _u
 }
 
+// SetRpmLimit sets the "rpm_limit" field.
+func (_u *APIKeyUpdateOne) SetRpmLimit(v int) *APIKeyUpdateOne {
+	_u.mutation.ResetRpmLimit()
+	_u.mutation.SetRpmLimit(v)
+	return _u
+}
+
+// SetNillableRpmLimit sets the "rpm_limit" field if the given value is not nil.
+func (_u *APIKeyUpdateOne) SetNillableRpmLimit(v *int) *APIKeyUpdateOne {
+	if v != nil {
+		_u.SetRpmLimit(*v)
+	}
+	return _u
+}
+
+// AddRpmLimit adds value to the "rpm_limit" field.
+func (_u *APIKeyUpdateOne) AddRpmLimit(v int) *APIKeyUpdateOne {
+	_u.mutation.AddRpmLimit(v)
+	return _u
+}
+
+// SetTpmLimit sets the "tpm_limit" field.
+func (_u *APIKeyUpdateOne) SetTpmLimit(v int) *APIKeyUpdateOne {
+	_u.mutation.ResetTpmLimit()
+	_u.mutation.SetTpmLimit(v)
+	return _u
+}
+
+// SetNillableTpmLimit sets the "tpm_limit" field if the given value is not nil.
+func (_u *APIKeyUpdateOne) SetNillableTpmLimit(v *int) *APIKeyUpdateOne {
+	if v != nil {
+		_u.SetTpmLimit(*v)
+	}
+	return _u
+}
+
+// AddTpmLimit adds value to the "tpm_limit" field.
+func (_u *APIKeyUpdateOne) AddTpmLimit(v int) *APIKeyUpdateOne {
+	_u.mutation.AddTpmLimit(v)
+	return _u
+}
+
+// SetDailyRequestLimit sets the "daily_request_limit" field.
+func (_u *APIKeyUpdateOne) SetDailyRequestLimit(v int) *APIKeyUpdateOne {
+	_u.mutation.ResetDailyRequestLimit()
+	_u.mutation.SetDailyRequestLimit(v)
+	return _u
+}
+
+// SetNillableDailyRequestLimit sets the "daily_request_limit" field if the given value is not nil.
+func (_u *APIKeyUpdateOne) SetNillableDailyRequestLimit(v *int) *APIKeyUpdateOne {
+	if v != nil {
+		_u.SetDailyRequestLimit(*v)
+	}
+	return _u
+}
+
+// AddDailyRequestLimit adds value to the "daily_request_limit" field.
+func (_u *APIKeyUpdateOne) AddDailyRequestLimit(v int) *APIKeyUpdateOne {
+	_u.mutation.AddDailyRequestLimit(v)
+	return _u
+}
+
+// SetDailyTokenLimit sets the "daily_token_limit" field.
+func (_u *APIKeyUpdateOne) SetDailyTokenLimit(v int64) *APIKeyUpdateOne {
+	_u.mutation.ResetDailyTokenLimit()
+	_u.mutation.SetDailyTokenLimit(v)
+	return _u
+}
+
+// SetNillableDailyTokenLimit sets the "daily_token_limit" field if the given value is not nil.
+func (_u *APIKeyUpdateOne) SetNillableDailyTokenLimit(v *int64) *APIKeyUpdateOne {
+	if v != nil {
+		_u.SetDailyTokenLimit(*v)
+	}
+	return _u
+}
+
+// AddDailyTokenLimit adds value to the "daily_token_limit" field.
+func (_u *APIKeyUpdateOne) AddDailyTokenLimit(v int64) *APIKeyUpdateOne {
+	_u.mutation.AddDailyTokenLimit(v)
+	return _u
+}
+
+// SetMonthlyRequestLimit sets the "monthly_request_limit" field.
+func (_u *APIKeyUpdateOne) SetMonthlyRequestLimit(v int) *APIKeyUpdateOne {
+	_u.mutation.ResetMonthlyRequestLimit()
+	_u.mutation.SetMonthlyRequestLimit(v)
+	return _u
+}
+
+// SetNillableMonthlyRequestLimit sets the "monthly_request_limit" field if the given value is not nil.
+func (_u *APIKeyUpdateOne) SetNillableMonthlyRequestLimit(v *int) *APIKeyUpdateOne {
+	if v != nil {
+		_u.SetMonthlyRequestLimit(*v)
+	}
+	return _u
+}
+
+// AddMonthlyRequestLimit adds value to the "monthly_request_limit" field.
+func (_u *APIKeyUpdateOne) AddMonthlyRequestLimit(v int) *APIKeyUpdateOne {
+	_u.mutation.AddMonthlyRequestLimit(v)
+	return _u
+}
+
+// SetMonthlyTokenLimit sets the "monthly_token_limit" field.
+func (_u *APIKeyUpdateOne) SetMonthlyTokenLimit(v int64) *APIKeyUpdateOne {
+	_u.mutation.ResetMonthlyTokenLimit()
+	_u.mutation.SetMonthlyTokenLimit(v)
+	return _u
+}
+
+// SetNillableMonthlyTokenLimit sets the "monthly_token_limit" field if the given value is not nil.
+func (_u *APIKeyUpdateOne) SetNillableMonthlyTokenLimit(v *int64) *APIKeyUpdateOne {
+	if v != nil {
+		_u.SetMonthlyTokenLimit(*v)
+	}
+	return _u
+}
+
+// AddMonthlyTokenLimit adds value to the "monthly_token_limit" field.
+func (_u *APIKeyUpdateOne) AddMonthlyTokenLimit(v int64) *APIKeyUpdateOne {
+	_u.mutation.AddMonthlyTokenLimit(v)
+	return _u
+}
+
+// SetBudgetAmount sets the "budget_amount" field.
+func (_u *APIKeyUpdateOne) SetBudgetAmount(v float64) *APIKeyUpdateOne {
+	_u.mutation.ResetBudgetAmount()
+	_u.mutation.SetBudgetAmount(v)
+	return _u
+}
+
+// SetNillableBudgetAmount sets the "budget_amount" field if the given value is not nil.
+func (_u *APIKeyUpdateOne) SetNillableBudgetAmount(v *float64) *APIKeyUpdateOne {
+	if v != nil {
+		_u.SetBudgetAmount(*v)
+	}
+	return _u
+}
+
+// AddBudgetAmount adds value to the "budget_amount" field.
+func (_u *APIKeyUpdateOne) AddBudgetAmount(v float64) *APIKeyUpdateOne {
+	_u.mutation.AddBudgetAmount(v)
+	return _u
+}
+
+// SetBudgetPeriod sets the "budget_period" field.
+func (_u *APIKeyUpdateOne) SetBudgetPeriod(v string) *APIKeyUpdateOne {
+	_u.mutation.SetBudgetPeriod(v)
+	return _u
+}
+
+// SetNillableBudgetPeriod sets the "budget_period" field if the given value is not nil.
+func (_u *APIKeyUpdateOne) SetNillableBudgetPeriod(v *string) *APIKeyUpdateOne {
+	if v != nil {
+		_u.SetBudgetPeriod(*v)
+	}
+	return _u
+}
+
+// SetBudgetAction sets the "budget_action" field.
+func (_u *APIKeyUpdateOne) SetBudgetAction(v string) *APIKeyUpdateOne {
+	_u.mutation.SetBudgetAction(v)
+	return _u
+}
+
+// SetNillableBudgetAction sets the "budget_action" field if the given value is not nil.
+func (_u *APIKeyUpdateOne) SetNillableBudgetAction(v *string) *APIKeyUpdateOne {
+	if v != nil {
+		_u.SetBudgetAction(*v)
+	}
+	return _u
+}
+
+// SetBudgetFallbackModel sets the "budget_fallback_model" field.
+func (_u *APIKeyUpdateOne) SetBudgetFallbackModel(v string) *APIKeyUpdateOne {
+	_u.mutation.SetBudgetFallbackModel(v)
+	return _u
+}
+
+// SetNillableBudgetFallbackModel sets the "budget_fallback_model" field if the given value is not nil.
+func (_u *APIKeyUpdateOne) SetNillableBudgetFallbackModel(v *string) *APIKeyUpdateOne {
+	if v != nil {
+		_u.SetBudgetFallbackModel(*v)
+	}
+	return _u
+}
+
+// SetBudgetUsed sets the "budget_used" field.
+func (_u *APIKeyUpdateOne) SetBudgetUsed(v float64) *APIKeyUpdateOne {
+	_u.mutation.ResetBudgetUsed()
+	_u.mutation.SetBudgetUsed(v)
+	return _u
+}
+
+// SetNillableBudgetUsed sets the "budget_used" field if the given value is not nil.
+func (_u *APIKeyUpdateOne) SetNillableBudgetUsed(v *float64) *APIKeyUpdateOne {
+	if v != nil {
+		_u.SetBudgetUsed(*v)
+	}
+	return _u
+}
+
+// AddBudgetUsed adds value to the "budget_used" field.
+func (_u *APIKeyUpdateOne) AddBudgetUsed(v float64) *APIKeyUpdateOne {
+	_u.mutation.AddBudgetUsed(v)
+	return _u
+}
+
+// SetBudgetPeriodStart sets the "budget_period_start" field.
+func (_u *APIKeyUpdateOne) SetBudgetPeriodStart(v time.Time) *APIKeyUpdateOne {
+	_u.mutation.SetBudgetPeriodStart(v)
+	return _u
+}
+
+// SetNillableBudgetPeriodStart sets the "budget_period_start" field if the given value is not nil.
+func (_u *APIKeyUpdateOne) SetNillableBudgetPeriodStart(v *time.Time) *APIKeyUpdateOne {
+	if v != nil {
+		_u.SetBudgetPeriodStart(*v)
+	}
+	return _u
+}
+
+// ClearBudgetPeriodStart clears the value of the "budget_period_start" field.
+func (_u *APIKeyUpdateOne) ClearBudgetPeriodStart() *APIKeyUpdateOne {
+	_u.mutation.ClearBudgetPeriodStart()
+	return _u
+}
+
+// SetBudgetExceededAt sets the "budget_exceeded_at" field.
+func (_u *APIKeyUpdateOne) SetBudgetExceededAt(v time.Time) *APIKeyUpdateOne {
+	_u.mutation.SetBudgetExceededAt(v)
+	return _u
+}
+
+// SetNillableBudgetExceededAt sets the "budget_exceeded_at" field if the given value is not nil.
+func (_u *APIKeyUpdateOne) SetNillableBudgetExceededAt(v *time.Time) *APIKeyUpdateOne {
+	if v != nil {
+		_u.SetBudgetExceededAt(*v)
+	}
+	return _u
+}
+
+// ClearBudgetExceededAt clears the value of the "budget_exceeded_at" field.
+func (_u *APIKeyUpdateOne) ClearBudgetExceededAt() *APIKeyUpdateOne {
+	_u.mutation.ClearBudgetExceededAt()
+	return _u
+}
+
+// SetPreviousKey sets the "previous_key" field.
+func (_u *APIKeyUpdateOne) SetPreviousKey(v string) *APIKeyUpdateOne {
+	_u.mutation.SetPreviousKey(v)
+	return _u
+}
+
+// SetNillablePreviousKey sets the "previous_key" field if the given value is not nil.
+func (_u *APIKeyUpdateOne) SetNillablePreviousKey(v *string) *APIKeyUpdateOne {
+	if v != nil {
+		_u.SetPreviousKey(*v)
+	}
+	return _u
+}
+
+// ClearPreviousKey clears the value of the "previous_key" field.
+func (_u *APIKeyUpdateOne) ClearPreviousKey() *APIKeyUpdateOne {
+	_u.mutation.ClearPreviousKey()
+	return _u
+}
+
+// SetPreviousKeyExpiresAt sets the "previous_key_expires_at" field.
+func (_u *APIKeyUpdateOne) SetPreviousKeyExpiresAt(v time.Time) *APIKeyUpdateOne {
+	_u.mutation.SetPreviousKeyExpiresAt(v)
+	return _u
+}
+
+// SetNillablePreviousKeyExpiresAt sets the "previous_key_expires_at" field if the given value is not nil.
+func (_u *APIKeyUpdateOne) SetNillablePreviousKeyExpiresAt(v *time.Time) *APIKeyUpdateOne {
+	if v != nil {
+		_u.SetPreviousKeyExpiresAt(*v)
+	}
+	return _u
+}
+
+// ClearPreviousKeyExpiresAt clears the value of the "previous_key_expires_at" field.
+func (_u *APIKeyUpdateOne) ClearPreviousKeyExpiresAt() *APIKeyUpdateOne {
+	_u.mutation.ClearPreviousKeyExpiresAt()
+	return _u
+}
+
 // SetUser sets the "user" edge to the User entity.
 func (_u *APIKeyUpdateOne) SetUser(v *User) *APIKeyUpdateOne {
 	return _u.SetUserID(v.ID)
@@ -1355,11 +2577,56 @@ func (_u *APIKeyUpdateOne) check() error {
 			return &ValidationError{Name: "name", err: fmt.Errorf(`ent: validator failed for field "APIKey.name": %w`, err)}
 		}
 	}
+	if v, ok := _u.mutation.Description(); ok {
+		if err := apikey.DescriptionValidator(v); err != nil {
+			return &ValidationError{Name: "description", err: fmt.Errorf(`ent: validator failed for field "APIKey.description": %w`, err)}
+		}
+	}
 	if v, ok := _u.mutation.Status(); ok {
 		if err := apikey.StatusValidator(v); err != nil {
 			return &ValidationError{Name: "status", err: fmt.Errorf(`ent: validator failed for field "APIKey.status": %w`, err)}
 		}
 	}
+	if v, ok := _u.mutation.ClientRestriction(); ok {
+		if err := apikey.ClientRestrictionValidator(v); err != nil {
+			return &ValidationError{Name: "client_restriction", err: fmt.Errorf(`ent: validator failed for field "APIKey.client_restriction": %w`, err)}
+		}
+	}
+	if v, ok := _u.mutation.DegradedFallbackModel(); ok {
+		if err := apikey.DegradedFallbackModelValidator(v); err != nil {
+			return &ValidationError{Name: "degraded_fallback_model", err: fmt.Errorf(`ent: validator failed for field "APIKey.degraded_fallback_model": %w`, err)}
+		}
+	}
+	if v, ok := _u.mutation.SystemPromptMode(); ok {
+		if err := apikey.SystemPromptModeValidator(v); err != nil {
+			return &ValidationError{Name: "system_prompt_mode", err: fmt.Errorf(`ent: validator failed for field "APIKey.system_prompt_mode": %w`, err)}
+		}
+	}
+	if v, ok := _u.mutation.ModerationMode(); ok {
+		if err := apikey.ModerationModeValidator(v); err != nil {
+			return &ValidationError{Name: "moderation_mode", err: fmt.Errorf(`ent: validator failed for field "APIKey.moderation_mode": %w`, err)}
+		}
+	}
+	if v, ok := _u.mutation.BudgetPeriod(); ok {
+		if err := apikey.BudgetPeriodValidator(v); err != nil {
+			return &ValidationError{Name: "budget_period", err: fmt.Errorf(`ent: validator failed for field "APIKey.budget_period": %w`, err)}
+		}
+	}
+	if v, ok := _u.mutation.BudgetAction(); ok {
+		if err := apikey.BudgetActionValidator(v); err != nil {
+			return &ValidationError{Name: "budget_action", err: fmt.Errorf(`ent: validator failed for field "APIKey.budget_action": %w`, err)}
+		}
+	}
+	if v, ok := _u.mutation.BudgetFallbackModel(); ok {
+		if err := apikey.BudgetFallbackModelValidator(v); err != nil {
+			return &ValidationError{Name: "budget_fallback_model", err: fmt.Errorf(`ent: validator failed for field "APIKey.budget_fallback_model": %w`, err)}
+		}
+	}
+	if v, ok := _u.mutation.PreviousKey(); ok {
+		if err := apikey.PreviousKeyValidator(v); err != nil {
+			return &ValidationError{Name: "previous_key", err: fmt.Errorf(`ent: validator failed for field "APIKey.previous_key": %w`, err)}
+		}
+	}
 	if _u.mutation.UserCleared() && len(_u.mutation.UserIDs()) > 0 {
 		return errors.New(`ent: clearing a required unique edge "APIKey.user"`)
 	}
@@ -1410,6 +2677,9 @@ func (_u *APIKeyUpdateOne) sqlSave(ctx context.Context) (_node *APIKey, err erro
 	if value, ok := _u.mutation.Name(); ok {
 		_spec.SetField(apikey.FieldName, field.TypeString, value)
 	}
+	if value, ok := _u.mutation.Description(); ok {
+		_spec.SetField(apikey.FieldDescription, field.TypeString, value)
+	}
 	if value, ok := _u.mutation.Status(); ok {
 		_spec.SetField(apikey.FieldStatus, field.TypeString, value)
 	}
@@ -1441,6 +2711,60 @@ func (_u *APIKeyUpdateOne) sqlSave(ctx context.Context) (_node *APIKey, err erro
 	if _u.mutation.IPBlacklistCleared() {
 		_spec.ClearField(apikey.FieldIPBlacklist, field.TypeJSON)
 	}
+	if value, ok := _u.mutation.Scopes(); ok {
+		_spec.SetField(apikey.FieldScopes, field.TypeJSON, value)
+	}
+	if value, ok := _u.mutation.AppendedScopes(); ok {
+		_spec.AddModifier(func(u *sql.UpdateBuilder) {
+			sqljson.Append(u, apikey.FieldScopes, value)
+		})
+	}
+	if _u.mutation.ScopesCleared() {
+		_spec.ClearField(apikey.FieldScopes, field.TypeJSON)
+	}
+	if value, ok := _u.mutation.AllowedModels(); ok {
+		_spec.SetField(apikey.FieldAllowedModels, field.TypeJSON, value)
+	}
+	if value, ok := _u.mutation.AppendedAllowedModels(); ok {
+		_spec.AddModifier(func(u *sql.UpdateBuilder) {
+			sqljson.Append(u, apikey.FieldAllowedModels, value)
+		})
+	}
+	if _u.mutation.AllowedModelsCleared() {
+		_spec.ClearField(apikey.FieldAllowedModels, field.TypeJSON)
+	}
+	if value, ok := _u.mutation.ClientRestriction(); ok {
+		_spec.SetField(apikey.FieldClientRestriction, field.TypeString, value)
+	}
+	if value, ok := _u.mutation.Tags(); ok {
+		_spec.SetField(apikey.FieldTags, field.TypeJSON, value)
+	}
+	if value, ok := _u.mutation.AppendedTags(); ok {
+		_spec.AddModifier(func(u *sql.UpdateBuilder) {
+			sqljson.Append(u, apikey.FieldTags, value)
+		})
+	}
+	if _u.mutation.TagsCleared() {
+		_spec.ClearField(apikey.FieldTags, field.TypeJSON)
+	}
+	if value, ok := _u.mutation.DegradedFallbackModel(); ok {
+		_spec.SetField(apikey.FieldDegradedFallbackModel, field.TypeString, value)
+	}
+	if value, ok := _u.mutation.SystemPrompt(); ok {
+		_spec.SetField(apikey.FieldSystemPrompt, field.TypeString, value)
+	}
+	if value, ok := _u.mutation.SystemPromptMode(); ok {
+		_spec.SetField(apikey.FieldSystemPromptMode, field.TypeString, value)
+	}
+	if value, ok := _u.mutation.ModerationMode(); ok {
+		_spec.SetField(apikey.FieldModerationMode, field.TypeString, value)
+	}
+	if value, ok := _u.mutation.LogContent(); ok {
+		_spec.SetField(apikey.FieldLogContent, field.TypeBool, value)
+	}
+	if value, ok := _u.mutation.Simulate(); ok {
+		_spec.SetField(apikey.FieldSimulate, field.TypeBool, value)
+	}
 	if value, ok := _u.mutation.Quota(); ok {
 		_spec.SetField(apikey.FieldQuota, field.TypeFloat64, value)
 	}
@@ -1453,6 +2777,21 @@ func (_u *APIKeyUpdateOne) sqlSave(ctx context.Context) (_node *APIKey, err erro
 	if value, ok := _u.mutation.AddedQuotaUsed(); ok {
 		_spec.AddField(apikey.FieldQuotaUsed, field.TypeFloat64, value)
 	}
+	if value, ok := _u.mutation.ImageQuota(); ok {
+		_spec.SetField(apikey.FieldImageQuota, field.TypeInt, value)
+	}
+	if value, ok := _u.mutation.AddedImageQuota(); ok {
+		_spec.AddField(apikey.FieldImageQuota, field.TypeInt, value)
+	}
+	if value, ok := _u.mutation.ImageQuotaUsed(); ok {
+		_spec.SetField(apikey.FieldImageQuotaUsed, field.TypeInt, value)
+	}
+	if value, ok := _u.mutation.AddedImageQuotaUsed(); ok {
+		_spec.AddField(apikey.FieldImageQuotaUsed, field.TypeInt, value)
+	}
+	if value, ok := _u.mutation.SuppressReasoning(); ok {
+		_spec.SetField(apikey.FieldSuppressReasoning, field.TypeBool, value)
+	}
 	if value, ok := _u.mutation.ExpiresAt(); ok {
 		_spec.SetField(apikey.FieldExpiresAt, field.TypeTime, value)
 	}
@@ -1513,6 +2852,87 @@ func (_u *APIKeyUpdateOne) sqlSave(ctx context.Context) (_node *APIKey, err erro
 	if _u.mutation.Window7dStartCleared() {
 		_spec.ClearField(apikey.FieldWindow7dStart, field.TypeTime)
 	}
+	if value, ok := _u.mutation.RpmLimit(); ok {
+		_spec.SetField(apikey.FieldRpmLimit, field.TypeInt, value)
+	}
+	if value, ok := _u.mutation.AddedRpmLimit(); ok {
+		_spec.AddField(apikey.FieldRpmLimit, field.TypeInt, value)
+	}
+	if value, ok := _u.mutation.TpmLimit(); ok {
+		_spec.SetField(apikey.FieldTpmLimit, field.TypeInt, value)
+	}
+	if value, ok := _u.mutation.AddedTpmLimit(); ok {
+		_spec.AddField(apikey.FieldTpmLimit, field.TypeInt, value)
+	}
+	if value, ok := _u.mutation.DailyRequestLimit(); ok {
+		_spec.SetField(apikey.FieldDailyRequestLimit, field.TypeInt, value)
+	}
+	if value, ok := _u.mutation.AddedDailyRequestLimit(); ok {
+		_spec.AddField(apikey.FieldDailyRequestLimit, field.TypeInt, value)
+	}
+	if value, ok := _u.mutation.DailyTokenLimit(); ok {
+		_spec.SetField(apikey.FieldDailyTokenLimit, field.TypeInt64, value)
+	}
+	if value, ok := _u.mutation.AddedDailyTokenLimit(); ok {
+		_spec.AddField(apikey.FieldDailyTokenLimit, field.TypeInt64, value)
+	}
+	if value, ok := _u.mutation.MonthlyRequestLimit(); ok {
+		_spec.SetField(apikey.FieldMonthlyRequestLimit, field.TypeInt, value)
+	}
+	if value, ok := _u.mutation.AddedMonthlyRequestLimit(); ok {
+		_spec.AddField(apikey.FieldMonthlyRequestLimit, field.TypeInt, value)
+	}
+	if value, ok := _u.mutation.MonthlyTokenLimit(); ok {
+		_spec.SetField(apikey.FieldMonthlyTokenLimit, field.TypeInt64, value)
+	}
+	if value, ok := _u.mutation.AddedMonthlyTokenLimit(); ok {
+		_spec.AddField(apikey.FieldMonthlyTokenLimit, field.TypeInt64, value)
+	}
+	if value, ok := _u.mutation.BudgetAmount(); ok {
+		_spec.SetField(apikey.FieldBudgetAmount, field.TypeFloat64, value)
+	}
+	if value, ok := _u.mutation.AddedBudgetAmount(); ok {
+		_spec.AddField(apikey.FieldBudgetAmount, field.TypeFloat64, value)
+	}
+	if value, ok := _u.mutation.BudgetPeriod(); ok {
+		_spec.SetField(apikey.FieldBudgetPeriod, field.TypeString, value)
+	}
+	if value, ok := _u.mutation.BudgetAction(); ok {
+		_spec.SetField(apikey.FieldBudgetAction, field.TypeString, value)
+	}
+	if value, ok := _u.mutation.BudgetFallbackModel(); ok {
+		_spec.SetField(apikey.FieldBudgetFallbackModel, field.TypeString, value)
+	}
+	if value, ok := _u.mutation.BudgetUsed(); ok {
+		_spec.SetField(apikey.FieldBudgetUsed, field.TypeFloat64, value)
+	}
+	if value, ok := _u.mutation.AddedBudgetUsed(); ok {
+		_spec.AddField(apikey.FieldBudgetUsed, field.TypeFloat64, value)
+	}
+	if value, ok := _u.mutation.BudgetPeriodStart(); ok {
+		_spec.SetField(apikey.FieldBudgetPeriodStart, field.TypeTime, value)
+	}
+	if _u.mutation.BudgetPeriodStartCleared() {
+		_spec.ClearField(apikey.FieldBudgetPeriodStart, field.TypeTime)
+	}
+	if value, ok := _u.mutation.BudgetExceededAt(); ok {
+		_spec.SetField(apikey.FieldBudgetExceededAt, field.TypeTime, value)
+	}
+	if _u.mutation.BudgetExceededAtCleared() {
+		_spec.ClearField(apikey.FieldBudgetExceededAt, field.TypeTime)
+	}
+	if value, ok := _u.mutation.PreviousKey(); ok {
+		_spec.SetField(apikey.FieldPreviousKey, field.TypeString, value)
+	}
+	if _u.mutation.PreviousKeyCleared() {
+		_spec.ClearField(apikey.FieldPreviousKey, field.TypeString)
+	}
+	if value, ok := _u.mutation.PreviousKeyExpiresAt(); ok {
+		_spec.SetField(apikey.FieldPreviousKeyExpiresAt, field.TypeTime, value)
+	}
+	if _u.mutation.PreviousKeyExpiresAtCleared() {
+		_spec.ClearField(apikey.FieldPreviousKeyExpiresAt, field.TypeTime)
+	}
 	if _u.mutation.UserCleared() {
 		edge := &sqlgraph.EdgeSpec{
 			Rel:     sqlgraph.M2O,
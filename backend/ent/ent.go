@@ -26,6 +26,7 @@ import (
 	"github.com/ShaohongDong/sub2api/ent/redeemcode"
 	"github.com/ShaohongDong/sub2api/ent/securitysecret"
 	"github.com/ShaohongDong/sub2api/ent/setting"
+	"github.com/ShaohongDong/sub2api/ent/tenant"
 	"github.com/ShaohongDong/sub2api/ent/usagecleanuptask"
 	"github.com/ShaohongDong/sub2api/ent/usagelog"
 	"github.com/ShaohongDong/sub2api/ent/user"
@@ -107,6 +108,7 @@ func checkColumn(t, c string) error {
 			redeemcode.Table:              redeemcode.ValidColumn,
 			securitysecret.Table:          securitysecret.ValidColumn,
 			setting.Table:                 setting.ValidColumn,
+			tenant.Table:                  tenant.ValidColumn,
 			usagecleanuptask.Table:        usagecleanuptask.ValidColumn,
 			usagelog.Table:                usagelog.ValidColumn,
 			user.Table:                    user.ValidColumn,
@@ -452,6 +452,46 @@ func (_c *GroupCreate) SetNillableDefaultMappedModel(v *string) *GroupCreate {
 	return _c
 }
 
+// SetModelAliases sets the "model_aliases" field.
+func (_c *GroupCreate) SetModelAliases(v map[string]string) *GroupCreate {
+	_c.mutation.SetModelAliases(v)
+	return _c
+}
+
+// SetFallbackChain sets the "fallback_chain" field.
+func (_c *GroupCreate) SetFallbackChain(v []int64) *GroupCreate {
+	_c.mutation.SetFallbackChain(v)
+	return _c
+}
+
+// SetSchedulingStrategy sets the "scheduling_strategy" field.
+func (_c *GroupCreate) SetSchedulingStrategy(v string) *GroupCreate {
+	_c.mutation.SetSchedulingStrategy(v)
+	return _c
+}
+
+// SetNillableSchedulingStrategy sets the "scheduling_strategy" field if the given value is not nil.
+func (_c *GroupCreate) SetNillableSchedulingStrategy(v *string) *GroupCreate {
+	if v != nil {
+		_c.SetSchedulingStrategy(*v)
+	}
+	return _c
+}
+
+// SetTenantID sets the "tenant_id" field.
+func (_c *GroupCreate) SetTenantID(v int64) *GroupCreate {
+	_c.mutation.SetTenantID(v)
+	return _c
+}
+
+// SetNillableTenantID sets the "tenant_id" field if the given value is not nil.
+func (_c *GroupCreate) SetNillableTenantID(v *int64) *GroupCreate {
+	if v != nil {
+		_c.SetTenantID(*v)
+	}
+	return _c
+}
+
 // AddAPIKeyIDs adds the "api_keys" edge to the APIKey entity by IDs.
 func (_c *GroupCreate) AddAPIKeyIDs(ids ...int64) *GroupCreate {
 	_c.mutation.AddAPIKeyIDs(ids...)
@@ -649,6 +689,10 @@ func (_c *GroupCreate) defaults() error {
 		v := group.DefaultDefaultMappedModel
 		_c.mutation.SetDefaultMappedModel(v)
 	}
+	if _, ok := _c.mutation.SchedulingStrategy(); !ok {
+		v := group.DefaultSchedulingStrategy
+		_c.mutation.SetSchedulingStrategy(v)
+	}
 	return nil
 }
 
@@ -730,6 +774,14 @@ func (_c *GroupCreate) check() error {
 			return &ValidationError{Name: "default_mapped_model", err: fmt.Errorf(`ent: validator failed for field "Group.default_mapped_model": %w`, err)}
 		}
 	}
+	if _, ok := _c.mutation.SchedulingStrategy(); !ok {
+		return &ValidationError{Name: "scheduling_strategy", err: errors.New(`ent: missing required field "Group.scheduling_strategy"`)}
+	}
+	if v, ok := _c.mutation.SchedulingStrategy(); ok {
+		if err := group.SchedulingStrategyValidator(v); err != nil {
+			return &ValidationError{Name: "scheduling_strategy", err: fmt.Errorf(`ent: validator failed for field "Group.scheduling_strategy": %w`, err)}
+		}
+	}
 	return nil
 }
 
@@ -885,6 +937,22 @@ func (_c *GroupCreate) createSpec() (*Group, *sqlgraph.CreateSpec) {
 		_spec.SetField(group.FieldDefaultMappedModel, field.TypeString, value)
 		_node.DefaultMappedModel = value
 	}
+	if value, ok := _c.mutation.ModelAliases(); ok {
+		_spec.SetField(group.FieldModelAliases, field.TypeJSON, value)
+		_node.ModelAliases = value
+	}
+	if value, ok := _c.mutation.FallbackChain(); ok {
+		_spec.SetField(group.FieldFallbackChain, field.TypeJSON, value)
+		_node.FallbackChain = value
+	}
+	if value, ok := _c.mutation.SchedulingStrategy(); ok {
+		_spec.SetField(group.FieldSchedulingStrategy, field.TypeString, value)
+		_node.SchedulingStrategy = value
+	}
+	if value, ok := _c.mutation.TenantID(); ok {
+		_spec.SetField(group.FieldTenantID, field.TypeInt64, value)
+		_node.TenantID = &value
+	}
 	if nodes := _c.mutation.APIKeysIDs(); len(nodes) > 0 {
 		edge := &sqlgraph.EdgeSpec{
 			Rel:     sqlgraph.O2M,
@@ -1599,6 +1667,78 @@ func (u *GroupUpsert) UpdateDefaultMappedModel() *GroupUpsert {
 	return u
 }
 
+// SetModelAliases sets the "model_aliases" field.
+func (u *GroupUpsert) SetModelAliases(v map[string]string) *GroupUpsert {
+	u.Set(group.FieldModelAliases, v)
+	return u
+}
+
+// UpdateModelAliases sets the "model_aliases" field to the value that was provided on create.
+func (u *GroupUpsert) UpdateModelAliases() *GroupUpsert {
+	u.SetExcluded(group.FieldModelAliases)
+	return u
+}
+
+// ClearModelAliases clears the value of the "model_aliases" field.
+func (u *GroupUpsert) ClearModelAliases() *GroupUpsert {
+	u.SetNull(group.FieldModelAliases)
+	return u
+}
+
+// SetFallbackChain sets the "fallback_chain" field.
+func (u *GroupUpsert) SetFallbackChain(v []int64) *GroupUpsert {
+	u.Set(group.FieldFallbackChain, v)
+	return u
+}
+
+// UpdateFallbackChain sets the "fallback_chain" field to the value that was provided on create.
+func (u *GroupUpsert) UpdateFallbackChain() *GroupUpsert {
+	u.SetExcluded(group.FieldFallbackChain)
+	return u
+}
+
+// ClearFallbackChain clears the value of the "fallback_chain" field.
+func (u *GroupUpsert) ClearFallbackChain() *GroupUpsert {
+	u.SetNull(group.FieldFallbackChain)
+	return u
+}
+
+// SetSchedulingStrategy sets the "scheduling_strategy" field.
+func (u *GroupUpsert) SetSchedulingStrategy(v string) *GroupUpsert {
+	u.Set(group.FieldSchedulingStrategy, v)
+	return u
+}
+
+// UpdateSchedulingStrategy sets the "scheduling_strategy" field to the value that was provided on create.
+func (u *GroupUpsert) UpdateSchedulingStrategy() *GroupUpsert {
+	u.SetExcluded(group.FieldSchedulingStrategy)
+	return u
+}
+
+// SetTenantID sets the "tenant_id" field.
+func (u *GroupUpsert) SetTenantID(v int64) *GroupUpsert {
+	u.Set(group.FieldTenantID, v)
+	return u
+}
+
+// UpdateTenantID sets the "tenant_id" field to the value that was provided on create.
+func (u *GroupUpsert) UpdateTenantID() *GroupUpsert {
+	u.SetExcluded(group.FieldTenantID)
+	return u
+}
+
+// AddTenantID adds v to the "tenant_id" field.
+func (u *GroupUpsert) AddTenantID(v int64) *GroupUpsert {
+	u.Add(group.FieldTenantID, v)
+	return u
+}
+
+// ClearTenantID clears the value of the "tenant_id" field.
+func (u *GroupUpsert) ClearTenantID() *GroupUpsert {
+	u.SetNull(group.FieldTenantID)
+	return u
+}
+
 // UpdateNewValues updates the mutable fields using the new values that were set on create.
 // Using this option is equivalent to using:
 //
@@ -2295,6 +2435,90 @@ func (u *GroupUpsertOne) UpdateDefaultMappedModel() *GroupUpsertOne {
 	})
 }
 
+// SetModelAliases sets the "model_aliases" field.
+func (u *GroupUpsertOne) SetModelAliases(v map[string]string) *GroupUpsertOne {
+	return u.Update(func(s *GroupUpsert) {
+		s.SetModelAliases(v)
+	})
+}
+
+// UpdateModelAliases sets the "model_aliases" field to the value that was provided on create.
+func (u *GroupUpsertOne) UpdateModelAliases() *GroupUpsertOne {
+	return u.Update(func(s *GroupUpsert) {
+		s.UpdateModelAliases()
+	})
+}
+
+// ClearModelAliases clears the value of the "model_aliases" field.
+func (u *GroupUpsertOne) ClearModelAliases() *GroupUpsertOne {
+	return u.Update(func(s *GroupUpsert) {
+		s.ClearModelAliases()
+	})
+}
+
+// SetFallbackChain sets the "fallback_chain" field.
+func (u *GroupUpsertOne) SetFallbackChain(v []int64) *GroupUpsertOne {
+	return u.Update(func(s *GroupUpsert) {
+		s.SetFallbackChain(v)
+	})
+}
+
+// UpdateFallbackChain sets the "fallback_chain" field to the value that was provided on create.
+func (u *GroupUpsertOne) UpdateFallbackChain() *GroupUpsertOne {
+	return u.Update(func(s *GroupUpsert) {
+		s.UpdateFallbackChain()
+	})
+}
+
+// ClearFallbackChain clears the value of the "fallback_chain" field.
+func (u *GroupUpsertOne) ClearFallbackChain() *GroupUpsertOne {
+	return u.Update(func(s *GroupUpsert) {
+		s.ClearFallbackChain()
+	})
+}
+
+// SetSchedulingStrategy sets the "scheduling_strategy" field.
+func (u *GroupUpsertOne) SetSchedulingStrategy(v string) *GroupUpsertOne {
+	return u.Update(func(s *GroupUpsert) {
+		s.SetSchedulingStrategy(v)
+	})
+}
+
+// UpdateSchedulingStrategy sets the "scheduling_strategy" field to the value that was provided on create.
+func (u *GroupUpsertOne) UpdateSchedulingStrategy() *GroupUpsertOne {
+	return u.Update(func(s *GroupUpsert) {
+		s.UpdateSchedulingStrategy()
+	})
+}
+
+// SetTenantID sets the "tenant_id" field.
+func (u *GroupUpsertOne) SetTenantID(v int64) *GroupUpsertOne {
+	return u.Update(func(s *GroupUpsert) {
+		s.SetTenantID(v)
+	})
+}
+
+// AddTenantID adds v to the "tenant_id" field.
+func (u *GroupUpsertOne) AddTenantID(v int64) *GroupUpsertOne {
+	return u.Update(func(s *GroupUpsert) {
+		s.AddTenantID(v)
+	})
+}
+
+// UpdateTenantID sets the "tenant_id" field to the value that was provided on create.
+func (u *GroupUpsertOne) UpdateTenantID() *GroupUpsertOne {
+	return u.Update(func(s *GroupUpsert) {
+		s.UpdateTenantID()
+	})
+}
+
+// ClearTenantID clears the value of the "tenant_id" field.
+func (u *GroupUpsertOne) ClearTenantID() *GroupUpsertOne {
+	return u.Update(func(s *GroupUpsert) {
+		s.ClearTenantID()
+	})
+}
+
 // Exec executes the query.
 func (u *GroupUpsertOne) Exec(ctx context.Context) error {
 	if len(u.create.conflict) == 0 {
@@ -3157,6 +3381,90 @@ func (u *GroupUpsertBulk) UpdateDefaultMappedModel() *GroupUpsertBulk {
 	})
 }
 
+// SetModelAliases sets the "model_aliases" field.
+func (u *GroupUpsertBulk) SetModelAliases(v map[string]string) *GroupUpsertBulk {
+	return u.Update(func(s *GroupUpsert) {
+		s.SetModelAliases(v)
+	})
+}
+
+// UpdateModelAliases sets the "model_aliases" field to the value that was provided on create.
+func (u *GroupUpsertBulk) UpdateModelAliases() *GroupUpsertBulk {
+	return u.Update(func(s *GroupUpsert) {
+		s.UpdateModelAliases()
+	})
+}
+
+// ClearModelAliases clears the value of the "model_aliases" field.
+func (u *GroupUpsertBulk) ClearModelAliases() *GroupUpsertBulk {
+	return u.Update(func(s *GroupUpsert) {
+		s.ClearModelAliases()
+	})
+}
+
+// SetFallbackChain sets the "fallback_chain" field.
+func (u *GroupUpsertBulk) SetFallbackChain(v []int64) *GroupUpsertBulk {
+	return u.Update(func(s *GroupUpsert) {
+		s.SetFallbackChain(v)
+	})
+}
+
+// UpdateFallbackChain sets the "fallback_chain" field to the value that was provided on create.
+func (u *GroupUpsertBulk) UpdateFallbackChain() *GroupUpsertBulk {
+	return u.Update(func(s *GroupUpsert) {
+		s.UpdateFallbackChain()
+	})
+}
+
+// ClearFallbackChain clears the value of the "fallback_chain" field.
+func (u *GroupUpsertBulk) ClearFallbackChain() *GroupUpsertBulk {
+	return u.Update(func(s *GroupUpsert) {
+		s.ClearFallbackChain()
+	})
+}
+
+// SetSchedulingStrategy sets the "scheduling_strategy" field.
+func (u *GroupUpsertBulk) SetSchedulingStrategy(v string) *GroupUpsertBulk {
+	return u.Update(func(s *GroupUpsert) {
+		s.SetSchedulingStrategy(v)
+	})
+}
+
+// UpdateSchedulingStrategy sets the "scheduling_strategy" field to the value that was provided on create.
+func (u *GroupUpsertBulk) UpdateSchedulingStrategy() *GroupUpsertBulk {
+	return u.Update(func(s *GroupUpsert) {
+		s.UpdateSchedulingStrategy()
+	})
+}
+
+// SetTenantID sets the "tenant_id" field.
+func (u *GroupUpsertBulk) SetTenantID(v int64) *GroupUpsertBulk {
+	return u.Update(func(s *GroupUpsert) {
+		s.SetTenantID(v)
+	})
+}
+
+// AddTenantID adds v to the "tenant_id" field.
+func (u *GroupUpsertBulk) AddTenantID(v int64) *GroupUpsertBulk {
+	return u.Update(func(s *GroupUpsert) {
+		s.AddTenantID(v)
+	})
+}
+
+// UpdateTenantID sets the "tenant_id" field to the value that was provided on create.
+func (u *GroupUpsertBulk) UpdateTenantID() *GroupUpsertBulk {
+	return u.Update(func(s *GroupUpsert) {
+		s.UpdateTenantID()
+	})
+}
+
+// ClearTenantID clears the value of the "tenant_id" field.
+func (u *GroupUpsertBulk) ClearTenantID() *GroupUpsertBulk {
+	return u.Update(func(s *GroupUpsert) {
+		s.ClearTenantID()
+	})
+}
+
 // Exec executes the query.
 func (u *GroupUpsertBulk) Exec(ctx context.Context) error {
 	if u.create.err != nil {
@@ -653,6 +653,77 @@ func (_u *GroupUpdate) SetNillableDefaultMappedModel(v *string) *GroupUpdate {
 	return _u
 }
 
+// SetModelAliases sets the "model_aliases" field.
+func (_u *GroupUpdate) SetModelAliases(v map[string]string) *GroupUpdate {
+	_u.mutation.SetModelAliases(v)
+	return _u
+}
+
+// ClearModelAliases clears the value of the "model_aliases" field.
+func (_u *GroupUpdate) ClearModelAliases() *GroupUpdate {
+	_u.mutation.ClearModelAliases()
+	return _u
+}
+
+// SetFallbackChain sets the "fallback_chain" field.
+func (_u *GroupUpdate) SetFallbackChain(v []int64) *GroupUpdate {
+	_u.mutation.SetFallbackChain(v)
+	return _u
+}
+
+// AppendFallbackChain appends value to the "fallback_chain" field.
+func (_u *GroupUpdate) AppendFallbackChain(v []int64) *GroupUpdate {
+	_u.mutation.AppendFallbackChain(v)
+	return _u
+}
+
+// ClearFallbackChain clears the value of the "fallback_chain" field.
+func (_u *GroupUpdate) ClearFallbackChain() *GroupUpdate {
+	_u.mutation.ClearFallbackChain()
+	return _u
+}
+
+// SetSchedulingStrategy sets the "scheduling_strategy" field.
+func (_u *GroupUpdate) SetSchedulingStrategy(v string) *GroupUpdate {
+	_u.mutation.SetSchedulingStrategy(v)
+	return _u
+}
+
+// SetNillableSchedulingStrategy sets the "scheduling_strategy" field if the given value is not nil.
+func (_u *GroupUpdate) SetNillableSchedulingStrategy(v *string) *GroupUpdate {
+	if v != nil {
+		_u.SetSchedulingStrategy(*v)
+	}
+	return _u
+}
+
+// SetTenantID sets the "tenant_id" field.
+func (_u *GroupUpdate) SetTenantID(v int64) *GroupUpdate {
+	_u.mutation.ResetTenantID()
+	_u.mutation.SetTenantID(v)
+	return _u
+}
+
+// SetNillableTenantID sets the "tenant_id" field if the given value is not nil.
+func (_u *GroupUpdate) SetNillableTenantID(v *int64) *GroupUpdate {
+	if v != nil {
+		_u.SetTenantID(*v)
+	}
+	return _u
+}
+
+// AddTenantID adds value to the "tenant_id" field.
+func (_u *GroupUpdate) AddTenantID(v int64) *GroupUpdate {
+	_u.mutation.AddTenantID(v)
+	return _u
+}
+
+// ClearTenantID clears the value of the "tenant_id" field.
+func (_u *GroupUpdate) ClearTenantID() *GroupUpdate {
+	_u.mutation.ClearTenantID()
+	return _u
+}
+
 // AddAPIKeyIDs adds the "api_keys" edge to the APIKey entity by IDs.
 func (_u *GroupUpdate) AddAPIKeyIDs(ids ...int64) *GroupUpdate {
 	_u.mutation.AddAPIKeyIDs(ids...)
@@ -943,6 +1014,11 @@ func (_u *GroupUpdate) check() error {
 			return &ValidationError{Name: "default_mapped_model", err: fmt.Errorf(`ent: validator failed for field "Group.default_mapped_model": %w`, err)}
 		}
 	}
+	if v, ok := _u.mutation.SchedulingStrategy(); ok {
+		if err := group.SchedulingStrategyValidator(v); err != nil {
+			return &ValidationError{Name: "scheduling_strategy", err: fmt.Errorf(`ent: validator failed for field "Group.scheduling_strategy": %w`, err)}
+		}
+	}
 	return nil
 }
 
@@ -1149,6 +1225,35 @@ func (_u *GroupUpdate) sqlSave(ctx context.Context) (_node int, err error) {
 	if value, ok := _u.mutation.DefaultMappedModel(); ok {
 		_spec.SetField(group.FieldDefaultMappedModel, field.TypeString, value)
 	}
+	if value, ok := _u.mutation.ModelAliases(); ok {
+		_spec.SetField(group.FieldModelAliases, field.TypeJSON, value)
+	}
+	if _u.mutation.ModelAliasesCleared() {
+		_spec.ClearField(group.FieldModelAliases, field.TypeJSON)
+	}
+	if value, ok := _u.mutation.FallbackChain(); ok {
+		_spec.SetField(group.FieldFallbackChain, field.TypeJSON, value)
+	}
+	if value, ok := _u.mutation.AppendedFallbackChain(); ok {
+		_spec.AddModifier(func(u *sql.UpdateBuilder) {
+			sqljson.Append(u, group.FieldFallbackChain, value)
+		})
+	}
+	if _u.mutation.FallbackChainCleared() {
+		_spec.ClearField(group.FieldFallbackChain, field.TypeJSON)
+	}
+	if value, ok := _u.mutation.SchedulingStrategy(); ok {
+		_spec.SetField(group.FieldSchedulingStrategy, field.TypeString, value)
+	}
+	if value, ok := _u.mutation.TenantID(); ok {
+		_spec.SetField(group.FieldTenantID, field.TypeInt64, value)
+	}
+	if value, ok := _u.mutation.AddedTenantID(); ok {
+		_spec.AddField(group.FieldTenantID, field.TypeInt64, value)
+	}
+	if _u.mutation.TenantIDCleared() {
+		_spec.ClearField(group.FieldTenantID, field.TypeInt64)
+	}
 	if _u.mutation.APIKeysCleared() {
 		edge := &sqlgraph.EdgeSpec{
 			Rel:     sqlgraph.O2M,
@@ -2081,6 +2186,77 @@ func (_u *GroupUpdateOne) SetNillableDefaultMappedModel(v *string) *GroupUpdateO
 	return _u
 }
 
+// SetModelAliases sets the "model_aliases" field.
+func (_u *GroupUpdateOne) SetModelAliases(v map[string]string) *GroupUpdateOne {
+	_u.mutation.SetModelAliases(v)
+	return _u
+}
+
+// ClearModelAliases clears the value of the "model_aliases" field.
+func (_u *GroupUpdateOne) ClearModelAliases() *GroupUpdateOne {
+	_u.mutation.ClearModelAliases()
+	return _u
+}
+
+// SetFallbackChain sets the "fallback_chain" field.
+func (_u *GroupUpdateOne) SetFallbackChain(v []int64) *GroupUpdateOne {
+	_u.mutation.SetFallbackChain(v)
+	return _u
+}
+
+// AppendFallbackChain appends value to the "fallback_chain" field.
+func (_u *GroupUpdateOne) AppendFallbackChain(v []int64) *GroupUpdateOne {
+	_u.mutation.AppendFallbackChain(v)
+	return _u
+}
+
+// ClearFallbackChain clears the value of the "fallback_chain" field.
+func (_u *GroupUpdateOne) ClearFallbackChain() *GroupUpdateOne {
+	_u.mutation.ClearFallbackChain()
+	return _u
+}
+
+// SetSchedulingStrategy sets the "scheduling_strategy" field.
+func (_u *GroupUpdateOne) SetSchedulingStrategy(v string) *GroupUpdateOne {
+	_u.mutation.SetSchedulingStrategy(v)
+	return _u
+}
+
+// SetNillableSchedulingStrategy sets the "scheduling_strategy" field if the given value is not nil.
+func (_u *GroupUpdateOne) SetNillableSchedulingStrategy(v *string) *GroupUpdateOne {
+	if v != nil {
+		_u.SetSchedulingStrategy(*v)
+	}
+	return _u
+}
+
+// SetTenantID sets the "tenant_id" field.
+func (_u *GroupUpdateOne) SetTenantID(v int64) *GroupUpdateOne {
+	_u.mutation.ResetTenantID()
+	_u.mutation.SetTenantID(v)
+	return _u
+}
+
+// SetNillableTenantID sets the "tenant_id" field if the given value is not nil.
+func (_u *GroupUpdateOne) SetNillableTenantID(v *int64) *GroupUpdateOne {
+	if v != nil {
+		_u.SetTenantID(*v)
+	}
+	return _u
+}
+
+// AddTenantID adds value to the "tenant_id" field.
+func (_u *GroupUpdateOne) AddTenantID(v int64) *GroupUpdateOne {
+	_u.mutation.AddTenantID(v)
+	return _u
+}
+
+// ClearTenantID clears the value of the "tenant_id" field.
+func (_u *GroupUpdateOne) ClearTenantID() *GroupUpdateOne {
+	_u.mutation.ClearTenantID()
+	return _u
+}
+
 // AddAPIKeyIDs adds the "api_keys" edge to the APIKey entity by IDs.
 func (_u *GroupUpdateOne) AddAPIKeyIDs(ids ...int64) *GroupUpdateOne {
 	_u.mutation.AddAPIKeyIDs(ids...)
@@ -2384,6 +2560,11 @@ func (_u *GroupUpdateOne) check() error {
 			return &ValidationError{Name: "default_mapped_model", err: fmt.Errorf(`ent: validator failed for field "Group.default_mapped_model": %w`, err)}
 		}
 	}
+	if v, ok := _u.mutation.SchedulingStrategy(); ok {
+		if err := group.SchedulingStrategyValidator(v); err != nil {
+			return &ValidationError{Name: "scheduling_strategy", err: fmt.Errorf(`ent: validator failed for field "Group.scheduling_strategy": %w`, err)}
+		}
+	}
 	return nil
 }
 
@@ -2607,6 +2788,35 @@ func (_u *GroupUpdateOne) sqlSave(ctx context.Context) (_node *Group, err error)
 	if value, ok := _u.mutation.DefaultMappedModel(); ok {
 		_spec.SetField(group.FieldDefaultMappedModel, field.TypeString, value)
 	}
+	if value, ok := _u.mutation.ModelAliases(); ok {
+		_spec.SetField(group.FieldModelAliases, field.TypeJSON, value)
+	}
+	if _u.mutation.ModelAliasesCleared() {
+		_spec.ClearField(group.FieldModelAliases, field.TypeJSON)
+	}
+	if value, ok := _u.mutation.FallbackChain(); ok {
+		_spec.SetField(group.FieldFallbackChain, field.TypeJSON, value)
+	}
+	if value, ok := _u.mutation.AppendedFallbackChain(); ok {
+		_spec.AddModifier(func(u *sql.UpdateBuilder) {
+			sqljson.Append(u, group.FieldFallbackChain, value)
+		})
+	}
+	if _u.mutation.FallbackChainCleared() {
+		_spec.ClearField(group.FieldFallbackChain, field.TypeJSON)
+	}
+	if value, ok := _u.mutation.SchedulingStrategy(); ok {
+		_spec.SetField(group.FieldSchedulingStrategy, field.TypeString, value)
+	}
+	if value, ok := _u.mutation.TenantID(); ok {
+		_spec.SetField(group.FieldTenantID, field.TypeInt64, value)
+	}
+	if value, ok := _u.mutation.AddedTenantID(); ok {
+		_spec.AddField(group.FieldTenantID, field.TypeInt64, value)
+	}
+	if _u.mutation.TenantIDCleared() {
+		_spec.ClearField(group.FieldTenantID, field.TypeInt64)
+	}
 	if _u.mutation.APIKeysCleared() {
 		edge := &sqlgraph.EdgeSpec{
 			Rel:     sqlgraph.O2M,
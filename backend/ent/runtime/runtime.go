@@ -20,6 +20,7 @@ import (
 	"github.com/ShaohongDong/sub2api/ent/schema"
 	"github.com/ShaohongDong/sub2api/ent/securitysecret"
 	"github.com/ShaohongDong/sub2api/ent/setting"
+	"github.com/ShaohongDong/sub2api/ent/tenant"
 	"github.com/ShaohongDong/sub2api/ent/usagecleanuptask"
 	"github.com/ShaohongDong/sub2api/ent/usagelog"
 	"github.com/ShaohongDong/sub2api/ent/user"
@@ -88,44 +89,152 @@ func init() {
 			return nil
 		}
 	}()
+	// apikeyDescDescription is the schema descriptor for description field.
+	apikeyDescDescription := apikeyFields[3].Descriptor()
+	// apikey.DefaultDescription holds the default value on creation for the description field.
+	apikey.DefaultDescription = apikeyDescDescription.Default.(string)
+	// apikey.DescriptionValidator is a validator for the "description" field. It is called by the builders before save.
+	apikey.DescriptionValidator = apikeyDescDescription.Validators[0].(func(string) error)
 	// apikeyDescStatus is the schema descriptor for status field.
-	apikeyDescStatus := apikeyFields[4].Descriptor()
+	apikeyDescStatus := apikeyFields[5].Descriptor()
 	// apikey.DefaultStatus holds the default value on creation for the status field.
 	apikey.DefaultStatus = apikeyDescStatus.Default.(string)
 	// apikey.StatusValidator is a validator for the "status" field. It is called by the builders before save.
 	apikey.StatusValidator = apikeyDescStatus.Validators[0].(func(string) error)
+	// apikeyDescClientRestriction is the schema descriptor for client_restriction field.
+	apikeyDescClientRestriction := apikeyFields[11].Descriptor()
+	// apikey.DefaultClientRestriction holds the default value on creation for the client_restriction field.
+	apikey.DefaultClientRestriction = apikeyDescClientRestriction.Default.(string)
+	// apikey.ClientRestrictionValidator is a validator for the "client_restriction" field. It is called by the builders before save.
+	apikey.ClientRestrictionValidator = apikeyDescClientRestriction.Validators[0].(func(string) error)
+	// apikeyDescDegradedFallbackModel is the schema descriptor for degraded_fallback_model field.
+	apikeyDescDegradedFallbackModel := apikeyFields[13].Descriptor()
+	// apikey.DefaultDegradedFallbackModel holds the default value on creation for the degraded_fallback_model field.
+	apikey.DefaultDegradedFallbackModel = apikeyDescDegradedFallbackModel.Default.(string)
+	// apikey.DegradedFallbackModelValidator is a validator for the "degraded_fallback_model" field. It is called by the builders before save.
+	apikey.DegradedFallbackModelValidator = apikeyDescDegradedFallbackModel.Validators[0].(func(string) error)
+	// apikeyDescSystemPrompt is the schema descriptor for system_prompt field.
+	apikeyDescSystemPrompt := apikeyFields[14].Descriptor()
+	// apikey.DefaultSystemPrompt holds the default value on creation for the system_prompt field.
+	apikey.DefaultSystemPrompt = apikeyDescSystemPrompt.Default.(string)
+	// apikeyDescSystemPromptMode is the schema descriptor for system_prompt_mode field.
+	apikeyDescSystemPromptMode := apikeyFields[15].Descriptor()
+	// apikey.DefaultSystemPromptMode holds the default value on creation for the system_prompt_mode field.
+	apikey.DefaultSystemPromptMode = apikeyDescSystemPromptMode.Default.(string)
+	// apikey.SystemPromptModeValidator is a validator for the "system_prompt_mode" field. It is called by the builders before save.
+	apikey.SystemPromptModeValidator = apikeyDescSystemPromptMode.Validators[0].(func(string) error)
+	// apikeyDescModerationMode is the schema descriptor for moderation_mode field.
+	apikeyDescModerationMode := apikeyFields[16].Descriptor()
+	// apikey.DefaultModerationMode holds the default value on creation for the moderation_mode field.
+	apikey.DefaultModerationMode = apikeyDescModerationMode.Default.(string)
+	// apikey.ModerationModeValidator is a validator for the "moderation_mode" field. It is called by the builders before save.
+	apikey.ModerationModeValidator = apikeyDescModerationMode.Validators[0].(func(string) error)
+	// apikeyDescLogContent is the schema descriptor for log_content field.
+	apikeyDescLogContent := apikeyFields[17].Descriptor()
+	// apikey.DefaultLogContent holds the default value on creation for the log_content field.
+	apikey.DefaultLogContent = apikeyDescLogContent.Default.(bool)
+	// apikeyDescSimulate is the schema descriptor for simulate field.
+	apikeyDescSimulate := apikeyFields[18].Descriptor()
+	// apikey.DefaultSimulate holds the default value on creation for the simulate field.
+	apikey.DefaultSimulate = apikeyDescSimulate.Default.(bool)
 	// apikeyDescQuota is the schema descriptor for quota field.
-	apikeyDescQuota := apikeyFields[8].Descriptor()
+	apikeyDescQuota := apikeyFields[19].Descriptor()
 	// apikey.DefaultQuota holds the default value on creation for the quota field.
 	apikey.DefaultQuota = apikeyDescQuota.Default.(float64)
 	// apikeyDescQuotaUsed is the schema descriptor for quota_used field.
-	apikeyDescQuotaUsed := apikeyFields[9].Descriptor()
+	apikeyDescQuotaUsed := apikeyFields[20].Descriptor()
 	// apikey.DefaultQuotaUsed holds the default value on creation for the quota_used field.
 	apikey.DefaultQuotaUsed = apikeyDescQuotaUsed.Default.(float64)
+	// apikeyDescImageQuota is the schema descriptor for image_quota field.
+	apikeyDescImageQuota := apikeyFields[21].Descriptor()
+	// apikey.DefaultImageQuota holds the default value on creation for the image_quota field.
+	apikey.DefaultImageQuota = apikeyDescImageQuota.Default.(int)
+	// apikeyDescImageQuotaUsed is the schema descriptor for image_quota_used field.
+	apikeyDescImageQuotaUsed := apikeyFields[22].Descriptor()
+	// apikey.DefaultImageQuotaUsed holds the default value on creation for the image_quota_used field.
+	apikey.DefaultImageQuotaUsed = apikeyDescImageQuotaUsed.Default.(int)
+	// apikeyDescSuppressReasoning is the schema descriptor for suppress_reasoning field.
+	apikeyDescSuppressReasoning := apikeyFields[23].Descriptor()
+	// apikey.DefaultSuppressReasoning holds the default value on creation for the suppress_reasoning field.
+	apikey.DefaultSuppressReasoning = apikeyDescSuppressReasoning.Default.(bool)
 	// apikeyDescRateLimit5h is the schema descriptor for rate_limit_5h field.
-	apikeyDescRateLimit5h := apikeyFields[11].Descriptor()
+	apikeyDescRateLimit5h := apikeyFields[25].Descriptor()
 	// apikey.DefaultRateLimit5h holds the default value on creation for the rate_limit_5h field.
 	apikey.DefaultRateLimit5h = apikeyDescRateLimit5h.Default.(float64)
 	// apikeyDescRateLimit1d is the schema descriptor for rate_limit_1d field.
-	apikeyDescRateLimit1d := apikeyFields[12].Descriptor()
+	apikeyDescRateLimit1d := apikeyFields[26].Descriptor()
 	// apikey.DefaultRateLimit1d holds the default value on creation for the rate_limit_1d field.
 	apikey.DefaultRateLimit1d = apikeyDescRateLimit1d.Default.(float64)
 	// apikeyDescRateLimit7d is the schema descriptor for rate_limit_7d field.
-	apikeyDescRateLimit7d := apikeyFields[13].Descriptor()
+	apikeyDescRateLimit7d := apikeyFields[27].Descriptor()
 	// apikey.DefaultRateLimit7d holds the default value on creation for the rate_limit_7d field.
 	apikey.DefaultRateLimit7d = apikeyDescRateLimit7d.Default.(float64)
 	// apikeyDescUsage5h is the schema descriptor for usage_5h field.
-	apikeyDescUsage5h := apikeyFields[14].Descriptor()
+	apikeyDescUsage5h := apikeyFields[28].Descriptor()
 	// apikey.DefaultUsage5h holds the default value on creation for the usage_5h field.
 	apikey.DefaultUsage5h = apikeyDescUsage5h.Default.(float64)
 	// apikeyDescUsage1d is the schema descriptor for usage_1d field.
-	apikeyDescUsage1d := apikeyFields[15].Descriptor()
+	apikeyDescUsage1d := apikeyFields[29].Descriptor()
 	// apikey.DefaultUsage1d holds the default value on creation for the usage_1d field.
 	apikey.DefaultUsage1d = apikeyDescUsage1d.Default.(float64)
 	// apikeyDescUsage7d is the schema descriptor for usage_7d field.
-	apikeyDescUsage7d := apikeyFields[16].Descriptor()
+	apikeyDescUsage7d := apikeyFields[30].Descriptor()
 	// apikey.DefaultUsage7d holds the default value on creation for the usage_7d field.
 	apikey.DefaultUsage7d = apikeyDescUsage7d.Default.(float64)
+	// apikeyDescRpmLimit is the schema descriptor for rpm_limit field.
+	apikeyDescRpmLimit := apikeyFields[34].Descriptor()
+	// apikey.DefaultRpmLimit holds the default value on creation for the rpm_limit field.
+	apikey.DefaultRpmLimit = apikeyDescRpmLimit.Default.(int)
+	// apikeyDescTpmLimit is the schema descriptor for tpm_limit field.
+	apikeyDescTpmLimit := apikeyFields[35].Descriptor()
+	// apikey.DefaultTpmLimit holds the default value on creation for the tpm_limit field.
+	apikey.DefaultTpmLimit = apikeyDescTpmLimit.Default.(int)
+	// apikeyDescDailyRequestLimit is the schema descriptor for daily_request_limit field.
+	apikeyDescDailyRequestLimit := apikeyFields[36].Descriptor()
+	// apikey.DefaultDailyRequestLimit holds the default value on creation for the daily_request_limit field.
+	apikey.DefaultDailyRequestLimit = apikeyDescDailyRequestLimit.Default.(int)
+	// apikeyDescDailyTokenLimit is the schema descriptor for daily_token_limit field.
+	apikeyDescDailyTokenLimit := apikeyFields[37].Descriptor()
+	// apikey.DefaultDailyTokenLimit holds the default value on creation for the daily_token_limit field.
+	apikey.DefaultDailyTokenLimit = apikeyDescDailyTokenLimit.Default.(int64)
+	// apikeyDescMonthlyRequestLimit is the schema descriptor for monthly_request_limit field.
+	apikeyDescMonthlyRequestLimit := apikeyFields[38].Descriptor()
+	// apikey.DefaultMonthlyRequestLimit holds the default value on creation for the monthly_request_limit field.
+	apikey.DefaultMonthlyRequestLimit = apikeyDescMonthlyRequestLimit.Default.(int)
+	// apikeyDescMonthlyTokenLimit is the schema descriptor for monthly_token_limit field.
+	apikeyDescMonthlyTokenLimit := apikeyFields[39].Descriptor()
+	// apikey.DefaultMonthlyTokenLimit holds the default value on creation for the monthly_token_limit field.
+	apikey.DefaultMonthlyTokenLimit = apikeyDescMonthlyTokenLimit.Default.(int64)
+	// apikeyDescBudgetAmount is the schema descriptor for budget_amount field.
+	apikeyDescBudgetAmount := apikeyFields[40].Descriptor()
+	// apikey.DefaultBudgetAmount holds the default value on creation for the budget_amount field.
+	apikey.DefaultBudgetAmount = apikeyDescBudgetAmount.Default.(float64)
+	// apikeyDescBudgetPeriod is the schema descriptor for budget_period field.
+	apikeyDescBudgetPeriod := apikeyFields[41].Descriptor()
+	// apikey.DefaultBudgetPeriod holds the default value on creation for the budget_period field.
+	apikey.DefaultBudgetPeriod = apikeyDescBudgetPeriod.Default.(string)
+	// apikey.BudgetPeriodValidator is a validator for the "budget_period" field. It is called by the builders before save.
+	apikey.BudgetPeriodValidator = apikeyDescBudgetPeriod.Validators[0].(func(string) error)
+	// apikeyDescBudgetAction is the schema descriptor for budget_action field.
+	apikeyDescBudgetAction := apikeyFields[42].Descriptor()
+	// apikey.DefaultBudgetAction holds the default value on creation for the budget_action field.
+	apikey.DefaultBudgetAction = apikeyDescBudgetAction.Default.(string)
+	// apikey.BudgetActionValidator is a validator for the "budget_action" field. It is called by the builders before save.
+	apikey.BudgetActionValidator = apikeyDescBudgetAction.Validators[0].(func(string) error)
+	// apikeyDescBudgetFallbackModel is the schema descriptor for budget_fallback_model field.
+	apikeyDescBudgetFallbackModel := apikeyFields[43].Descriptor()
+	// apikey.DefaultBudgetFallbackModel holds the default value on creation for the budget_fallback_model field.
+	apikey.DefaultBudgetFallbackModel = apikeyDescBudgetFallbackModel.Default.(string)
+	// apikey.BudgetFallbackModelValidator is a validator for the "budget_fallback_model" field. It is called by the builders before save.
+	apikey.BudgetFallbackModelValidator = apikeyDescBudgetFallbackModel.Validators[0].(func(string) error)
+	// apikeyDescBudgetUsed is the schema descriptor for budget_used field.
+	apikeyDescBudgetUsed := apikeyFields[44].Descriptor()
+	// apikey.DefaultBudgetUsed holds the default value on creation for the budget_used field.
+	apikey.DefaultBudgetUsed = apikeyDescBudgetUsed.Default.(float64)
+	// apikeyDescPreviousKey is the schema descriptor for previous_key field.
+	apikeyDescPreviousKey := apikeyFields[47].Descriptor()
+	// apikey.PreviousKeyValidator is a validator for the "previous_key" field. It is called by the builders before save.
+	apikey.PreviousKeyValidator = apikeyDescPreviousKey.Validators[0].(func(string) error)
 	accountMixin := schema.Account{}.Mixin()
 	accountMixinHooks1 := accountMixin[1].Hooks()
 	account.Hooks[0] = accountMixinHooks1[0]
@@ -463,6 +572,12 @@ func init() {
 	group.DefaultDefaultMappedModel = groupDescDefaultMappedModel.Default.(string)
 	// group.DefaultMappedModelValidator is a validator for the "default_mapped_model" field. It is called by the builders before save.
 	group.DefaultMappedModelValidator = groupDescDefaultMappedModel.Validators[0].(func(string) error)
+	// groupDescSchedulingStrategy is the schema descriptor for scheduling_strategy field.
+	groupDescSchedulingStrategy := groupFields[31].Descriptor()
+	// group.DefaultSchedulingStrategy holds the default value on creation for the scheduling_strategy field.
+	group.DefaultSchedulingStrategy = groupDescSchedulingStrategy.Default.(string)
+	// group.SchedulingStrategyValidator is a validator for the "scheduling_strategy" field. It is called by the builders before save.
+	group.SchedulingStrategyValidator = groupDescSchedulingStrategy.Validators[0].(func(string) error)
 	idempotencyrecordMixin := schema.IdempotencyRecord{}.Mixin()
 	idempotencyrecordMixinFields0 := idempotencyrecordMixin[0].Fields()
 	_ = idempotencyrecordMixinFields0
@@ -746,6 +861,51 @@ func init() {
 	setting.DefaultUpdatedAt = settingDescUpdatedAt.Default.(func() time.Time)
 	// setting.UpdateDefaultUpdatedAt holds the default value on update for the updated_at field.
 	setting.UpdateDefaultUpdatedAt = settingDescUpdatedAt.UpdateDefault.(func() time.Time)
+	tenantMixin := schema.Tenant{}.Mixin()
+	tenantMixinFields0 := tenantMixin[0].Fields()
+	_ = tenantMixinFields0
+	tenantFields := schema.Tenant{}.Fields()
+	_ = tenantFields
+	// tenantDescCreatedAt is the schema descriptor for created_at field.
+	tenantDescCreatedAt := tenantMixinFields0[0].Descriptor()
+	// tenant.DefaultCreatedAt holds the default value on creation for the created_at field.
+	tenant.DefaultCreatedAt = tenantDescCreatedAt.Default.(func() time.Time)
+	// tenantDescUpdatedAt is the schema descriptor for updated_at field.
+	tenantDescUpdatedAt := tenantMixinFields0[1].Descriptor()
+	// tenant.DefaultUpdatedAt holds the default value on creation for the updated_at field.
+	tenant.DefaultUpdatedAt = tenantDescUpdatedAt.Default.(func() time.Time)
+	// tenant.UpdateDefaultUpdatedAt holds the default value on update for the updated_at field.
+	tenant.UpdateDefaultUpdatedAt = tenantDescUpdatedAt.UpdateDefault.(func() time.Time)
+	// tenantDescName is the schema descriptor for name field.
+	tenantDescName := tenantFields[0].Descriptor()
+	// tenant.NameValidator is a validator for the "name" field. It is called by the builders before save.
+	tenant.NameValidator = func() func(string) error {
+		validators := tenantDescName.Validators
+		fns := [...]func(string) error{
+			validators[0].(func(string) error),
+			validators[1].(func(string) error),
+		}
+		return func(name string) error {
+			for _, fn := range fns {
+				if err := fn(name); err != nil {
+					return err
+				}
+			}
+			return nil
+		}
+	}()
+	// tenantDescDescription is the schema descriptor for description field.
+	tenantDescDescription := tenantFields[1].Descriptor()
+	// tenant.DefaultDescription holds the default value on creation for the description field.
+	tenant.DefaultDescription = tenantDescDescription.Default.(string)
+	// tenant.DescriptionValidator is a validator for the "description" field. It is called by the builders before save.
+	tenant.DescriptionValidator = tenantDescDescription.Validators[0].(func(string) error)
+	// tenantDescStatus is the schema descriptor for status field.
+	tenantDescStatus := tenantFields[2].Descriptor()
+	// tenant.DefaultStatus holds the default value on creation for the status field.
+	tenant.DefaultStatus = tenantDescStatus.Default.(string)
+	// tenant.StatusValidator is a validator for the "status" field. It is called by the builders before save.
+	tenant.StatusValidator = tenantDescStatus.Validators[0].(func(string) error)
 	usagecleanuptaskMixin := schema.UsageCleanupTask{}.Mixin()
 	usagecleanuptaskMixinFields0 := usagecleanuptaskMixin[0].Fields()
 	_ = usagecleanuptaskMixinFields0
@@ -803,8 +963,12 @@ func init() {
 			return nil
 		}
 	}()
+	// usagelogDescGatewayRequestID is the schema descriptor for gateway_request_id field.
+	usagelogDescGatewayRequestID := usagelogFields[4].Descriptor()
+	// usagelog.GatewayRequestIDValidator is a validator for the "gateway_request_id" field. It is called by the builders before save.
+	usagelog.GatewayRequestIDValidator = usagelogDescGatewayRequestID.Validators[0].(func(string) error)
 	// usagelogDescModel is the schema descriptor for model field.
-	usagelogDescModel := usagelogFields[4].Descriptor()
+	usagelogDescModel := usagelogFields[5].Descriptor()
 	// usagelog.ModelValidator is a validator for the "model" field. It is called by the builders before save.
 	usagelog.ModelValidator = func() func(string) error {
 		validators := usagelogDescModel.Validators
@@ -822,91 +986,99 @@ func init() {
 		}
 	}()
 	// usagelogDescInputTokens is the schema descriptor for input_tokens field.
-	usagelogDescInputTokens := usagelogFields[7].Descriptor()
+	usagelogDescInputTokens := usagelogFields[8].Descriptor()
 	// usagelog.DefaultInputTokens holds the default value on creation for the input_tokens field.
 	usagelog.DefaultInputTokens = usagelogDescInputTokens.Default.(int)
 	// usagelogDescOutputTokens is the schema descriptor for output_tokens field.
-	usagelogDescOutputTokens := usagelogFields[8].Descriptor()
+	usagelogDescOutputTokens := usagelogFields[9].Descriptor()
 	// usagelog.DefaultOutputTokens holds the default value on creation for the output_tokens field.
 	usagelog.DefaultOutputTokens = usagelogDescOutputTokens.Default.(int)
 	// usagelogDescCacheCreationTokens is the schema descriptor for cache_creation_tokens field.
-	usagelogDescCacheCreationTokens := usagelogFields[9].Descriptor()
+	usagelogDescCacheCreationTokens := usagelogFields[10].Descriptor()
 	// usagelog.DefaultCacheCreationTokens holds the default value on creation for the cache_creation_tokens field.
 	usagelog.DefaultCacheCreationTokens = usagelogDescCacheCreationTokens.Default.(int)
 	// usagelogDescCacheReadTokens is the schema descriptor for cache_read_tokens field.
-	usagelogDescCacheReadTokens := usagelogFields[10].Descriptor()
+	usagelogDescCacheReadTokens := usagelogFields[11].Descriptor()
 	// usagelog.DefaultCacheReadTokens holds the default value on creation for the cache_read_tokens field.
 	usagelog.DefaultCacheReadTokens = usagelogDescCacheReadTokens.Default.(int)
 	// usagelogDescCacheCreation5mTokens is the schema descriptor for cache_creation_5m_tokens field.
-	usagelogDescCacheCreation5mTokens := usagelogFields[11].Descriptor()
+	usagelogDescCacheCreation5mTokens := usagelogFields[12].Descriptor()
 	// usagelog.DefaultCacheCreation5mTokens holds the default value on creation for the cache_creation_5m_tokens field.
 	usagelog.DefaultCacheCreation5mTokens = usagelogDescCacheCreation5mTokens.Default.(int)
 	// usagelogDescCacheCreation1hTokens is the schema descriptor for cache_creation_1h_tokens field.
-	usagelogDescCacheCreation1hTokens := usagelogFields[12].Descriptor()
+	usagelogDescCacheCreation1hTokens := usagelogFields[13].Descriptor()
 	// usagelog.DefaultCacheCreation1hTokens holds the default value on creation for the cache_creation_1h_tokens field.
 	usagelog.DefaultCacheCreation1hTokens = usagelogDescCacheCreation1hTokens.Default.(int)
+	// usagelogDescReasoningTokens is the schema descriptor for reasoning_tokens field.
+	usagelogDescReasoningTokens := usagelogFields[14].Descriptor()
+	// usagelog.DefaultReasoningTokens holds the default value on creation for the reasoning_tokens field.
+	usagelog.DefaultReasoningTokens = usagelogDescReasoningTokens.Default.(int)
 	// usagelogDescInputCost is the schema descriptor for input_cost field.
-	usagelogDescInputCost := usagelogFields[13].Descriptor()
+	usagelogDescInputCost := usagelogFields[15].Descriptor()
 	// usagelog.DefaultInputCost holds the default value on creation for the input_cost field.
 	usagelog.DefaultInputCost = usagelogDescInputCost.Default.(float64)
 	// usagelogDescOutputCost is the schema descriptor for output_cost field.
-	usagelogDescOutputCost := usagelogFields[14].Descriptor()
+	usagelogDescOutputCost := usagelogFields[16].Descriptor()
 	// usagelog.DefaultOutputCost holds the default value on creation for the output_cost field.
 	usagelog.DefaultOutputCost = usagelogDescOutputCost.Default.(float64)
 	// usagelogDescCacheCreationCost is the schema descriptor for cache_creation_cost field.
-	usagelogDescCacheCreationCost := usagelogFields[15].Descriptor()
+	usagelogDescCacheCreationCost := usagelogFields[17].Descriptor()
 	// usagelog.DefaultCacheCreationCost holds the default value on creation for the cache_creation_cost field.
 	usagelog.DefaultCacheCreationCost = usagelogDescCacheCreationCost.Default.(float64)
 	// usagelogDescCacheReadCost is the schema descriptor for cache_read_cost field.
-	usagelogDescCacheReadCost := usagelogFields[16].Descriptor()
+	usagelogDescCacheReadCost := usagelogFields[18].Descriptor()
 	// usagelog.DefaultCacheReadCost holds the default value on creation for the cache_read_cost field.
 	usagelog.DefaultCacheReadCost = usagelogDescCacheReadCost.Default.(float64)
 	// usagelogDescTotalCost is the schema descriptor for total_cost field.
-	usagelogDescTotalCost := usagelogFields[17].Descriptor()
+	usagelogDescTotalCost := usagelogFields[19].Descriptor()
 	// usagelog.DefaultTotalCost holds the default value on creation for the total_cost field.
 	usagelog.DefaultTotalCost = usagelogDescTotalCost.Default.(float64)
 	// usagelogDescActualCost is the schema descriptor for actual_cost field.
-	usagelogDescActualCost := usagelogFields[18].Descriptor()
+	usagelogDescActualCost := usagelogFields[20].Descriptor()
 	// usagelog.DefaultActualCost holds the default value on creation for the actual_cost field.
 	usagelog.DefaultActualCost = usagelogDescActualCost.Default.(float64)
 	// usagelogDescRateMultiplier is the schema descriptor for rate_multiplier field.
-	usagelogDescRateMultiplier := usagelogFields[19].Descriptor()
+	usagelogDescRateMultiplier := usagelogFields[21].Descriptor()
 	// usagelog.DefaultRateMultiplier holds the default value on creation for the rate_multiplier field.
 	usagelog.DefaultRateMultiplier = usagelogDescRateMultiplier.Default.(float64)
 	// usagelogDescBillingType is the schema descriptor for billing_type field.
-	usagelogDescBillingType := usagelogFields[21].Descriptor()
+	usagelogDescBillingType := usagelogFields[23].Descriptor()
 	// usagelog.DefaultBillingType holds the default value on creation for the billing_type field.
 	usagelog.DefaultBillingType = usagelogDescBillingType.Default.(int8)
 	// usagelogDescStream is the schema descriptor for stream field.
-	usagelogDescStream := usagelogFields[22].Descriptor()
+	usagelogDescStream := usagelogFields[24].Descriptor()
 	// usagelog.DefaultStream holds the default value on creation for the stream field.
 	usagelog.DefaultStream = usagelogDescStream.Default.(bool)
 	// usagelogDescUserAgent is the schema descriptor for user_agent field.
-	usagelogDescUserAgent := usagelogFields[25].Descriptor()
+	usagelogDescUserAgent := usagelogFields[27].Descriptor()
 	// usagelog.UserAgentValidator is a validator for the "user_agent" field. It is called by the builders before save.
 	usagelog.UserAgentValidator = usagelogDescUserAgent.Validators[0].(func(string) error)
 	// usagelogDescIPAddress is the schema descriptor for ip_address field.
-	usagelogDescIPAddress := usagelogFields[26].Descriptor()
+	usagelogDescIPAddress := usagelogFields[28].Descriptor()
 	// usagelog.IPAddressValidator is a validator for the "ip_address" field. It is called by the builders before save.
 	usagelog.IPAddressValidator = usagelogDescIPAddress.Validators[0].(func(string) error)
+	// usagelogDescClientType is the schema descriptor for client_type field.
+	usagelogDescClientType := usagelogFields[29].Descriptor()
+	// usagelog.ClientTypeValidator is a validator for the "client_type" field. It is called by the builders before save.
+	usagelog.ClientTypeValidator = usagelogDescClientType.Validators[0].(func(string) error)
 	// usagelogDescImageCount is the schema descriptor for image_count field.
-	usagelogDescImageCount := usagelogFields[27].Descriptor()
+	usagelogDescImageCount := usagelogFields[30].Descriptor()
 	// usagelog.DefaultImageCount holds the default value on creation for the image_count field.
 	usagelog.DefaultImageCount = usagelogDescImageCount.Default.(int)
 	// usagelogDescImageSize is the schema descriptor for image_size field.
-	usagelogDescImageSize := usagelogFields[28].Descriptor()
+	usagelogDescImageSize := usagelogFields[31].Descriptor()
 	// usagelog.ImageSizeValidator is a validator for the "image_size" field. It is called by the builders before save.
 	usagelog.ImageSizeValidator = usagelogDescImageSize.Validators[0].(func(string) error)
 	// usagelogDescMediaType is the schema descriptor for media_type field.
-	usagelogDescMediaType := usagelogFields[29].Descriptor()
+	usagelogDescMediaType := usagelogFields[32].Descriptor()
 	// usagelog.MediaTypeValidator is a validator for the "media_type" field. It is called by the builders before save.
 	usagelog.MediaTypeValidator = usagelogDescMediaType.Validators[0].(func(string) error)
 	// usagelogDescCacheTTLOverridden is the schema descriptor for cache_ttl_overridden field.
-	usagelogDescCacheTTLOverridden := usagelogFields[30].Descriptor()
+	usagelogDescCacheTTLOverridden := usagelogFields[33].Descriptor()
 	// usagelog.DefaultCacheTTLOverridden holds the default value on creation for the cache_ttl_overridden field.
 	usagelog.DefaultCacheTTLOverridden = usagelogDescCacheTTLOverridden.Default.(bool)
 	// usagelogDescCreatedAt is the schema descriptor for created_at field.
-	usagelogDescCreatedAt := usagelogFields[31].Descriptor()
+	usagelogDescCreatedAt := usagelogFields[34].Descriptor()
 	// usagelog.DefaultCreatedAt holds the default value on creation for the created_at field.
 	usagelog.DefaultCreatedAt = usagelogDescCreatedAt.Default.(func() time.Time)
 	userMixin := schema.User{}.Mixin()
@@ -41,6 +41,10 @@ func (APIKey) Fields() []ent.Field {
 		field.String("name").
 			MaxLen(100).
 			NotEmpty(),
+		field.String("description").
+			MaxLen(500).
+			Default("").
+			Comment("Free-form note describing what this API key is used for"),
 		field.Int64("group_id").
 			Optional().
 			Nillable(),
@@ -57,6 +61,40 @@ func (APIKey) Fields() []ent.Field {
 		field.JSON("ip_blacklist", []string{}).
 			Optional().
 			Comment("Blocked IPs/CIDRs"),
+		field.JSON("scopes", []string{}).
+			Optional().
+			Comment("Allowed endpoint scopes, e.g. [\"messages\", \"responses\"] (empty = all endpoints)"),
+		field.JSON("allowed_models", []string{}).
+			Optional().
+			Comment("Allowed model patterns, trailing * wildcard supported, e.g. [\"claude-haiku-*\"] (empty = all models)"),
+		field.String("client_restriction").
+			MaxLen(20).
+			Default("").
+			Comment("Allowed client class: codex, claude_code, coding_agent or api (empty = any client)"),
+		field.JSON("tags", []string{}).
+			Optional().
+			Comment("Admin-assigned tags matched by routing rules, e.g. [\"pro\"]"),
+		field.String("degraded_fallback_model").
+			MaxLen(100).
+			Default("").
+			Comment("Model served when every account for the requested model is unavailable ('' = return 503)"),
+		field.Text("system_prompt").
+			Default("").
+			Comment("System prompt injected into every request made with this key ('' = none)"),
+		field.String("system_prompt_mode").
+			MaxLen(20).
+			Default("").
+			Comment("How system_prompt is applied: prepend, append or replace ('' = prepend)"),
+		field.String("moderation_mode").
+			MaxLen(20).
+			Default("").
+			Comment("Prompt moderation pre-filter: off, flag or block ('' = follow gateway.moderation.pre_filter)"),
+		field.Bool("log_content").
+			Default(false).
+			Comment("Persist full prompts and responses of this key's requests for debugging (purged after content_log.retention_days)"),
+		field.Bool("simulate").
+			Default(false).
+			Comment("Dry-run mode: requests are answered by the built-in mock upstream and never reach real accounts"),
 
 		// ========== Quota fields ==========
 		// Quota limit in USD (0 = unlimited)
@@ -69,6 +107,17 @@ func (APIKey) Fields() []ent.Field {
 			SchemaType(map[string]string{dialect.Postgres: "decimal(20,8)"}).
 			Default(0).
 			Comment("Used quota amount in USD"),
+		// Image quota (count of generated images, 0 = unlimited)
+		field.Int("image_quota").
+			Default(0).
+			Comment("Max number of images this API key may generate (0 = unlimited)"),
+		field.Int("image_quota_used").
+			Default(0).
+			Comment("Number of images generated with this API key"),
+		// Reasoning output for plain API clients (Codex / Claude Code always receive it)
+		field.Bool("suppress_reasoning").
+			Default(false).
+			Comment("Drop reasoning/thinking output for clients other than Codex and Claude Code"),
 		// Expiration time (nil = never expires)
 		field.Time("expires_at").
 			Optional().
@@ -115,6 +164,70 @@ func (APIKey) Fields() []ent.Field {
 			Optional().
 			Nillable().
 			Comment("Start time of the current 7d rate limit window"),
+
+		// ========== Request / token limits ==========
+		// Per-minute throughput and calendar day/month quotas (0 = unlimited), counted in Redis
+		field.Int("rpm_limit").
+			Default(0).
+			Comment("Max requests per minute (0 = unlimited)"),
+		field.Int("tpm_limit").
+			Default(0).
+			Comment("Max tokens per minute (0 = unlimited)"),
+		field.Int("daily_request_limit").
+			Default(0).
+			Comment("Max requests per calendar day (0 = unlimited)"),
+		field.Int64("daily_token_limit").
+			Default(0).
+			Comment("Max tokens per calendar day (0 = unlimited)"),
+		field.Int("monthly_request_limit").
+			Default(0).
+			Comment("Max requests per calendar month (0 = unlimited)"),
+		field.Int64("monthly_token_limit").
+			Default(0).
+			Comment("Max tokens per calendar month (0 = unlimited)"),
+
+		// ========== Spend budget ==========
+		// Monetary budget per calendar period; crossing it disables the key or switches it to a fallback model
+		field.Float("budget_amount").
+			SchemaType(map[string]string{dialect.Postgres: "decimal(20,8)"}).
+			Default(0).
+			Comment("Spend budget in USD per period (0 = no budget)"),
+		field.String("budget_period").
+			MaxLen(10).
+			Default("month").
+			Comment("Budget reset period: day, week or month"),
+		field.String("budget_action").
+			MaxLen(20).
+			Default("disable").
+			Comment("Action once the budget is exceeded: disable or downgrade"),
+		field.String("budget_fallback_model").
+			MaxLen(100).
+			Default("").
+			Comment("Model requests are switched to when budget_action is downgrade"),
+		field.Float("budget_used").
+			SchemaType(map[string]string{dialect.Postgres: "decimal(20,8)"}).
+			Default(0).
+			Comment("Spent amount in USD for the current budget period"),
+		field.Time("budget_period_start").
+			Optional().
+			Nillable().
+			Comment("Start time of the current budget period"),
+		field.Time("budget_exceeded_at").
+			Optional().
+			Nillable().
+			Comment("When the budget was exceeded in the current period (null = within budget)"),
+
+		// ========== Key rotation ==========
+		// The secret replaced by the last rotation keeps authenticating until previous_key_expires_at
+		field.String("previous_key").
+			MaxLen(128).
+			Optional().
+			Nillable().
+			Comment("Secret replaced by the last rotation (valid during the grace window)"),
+		field.Time("previous_key_expires_at").
+			Optional().
+			Nillable().
+			Comment("End of the grace window for previous_key"),
 	}
 }
 
@@ -144,5 +257,6 @@ func (APIKey) Indexes() []ent.Index {
 		// Index for quota queries
 		index.Fields("quota", "quota_used"),
 		index.Fields("expires_at"),
+		index.Fields("previous_key"),
 	}
 }
@@ -157,6 +157,29 @@ func (Group) Fields() []ent.Field {
 			MaxLen(100).
 			Default("").
 			Comment("默认映射模型 ID，当账号级映射找不到时使用此值"),
+
+		// 模型别名（覆盖全局别名表）
+		field.JSON("model_aliases", map[string]string{}).
+			Optional().
+			SchemaType(map[string]string{dialect.Postgres: "jsonb"}).
+			Comment("模型别名：请求模型（支持末尾 *）-> 上游模型，优先于全局别名表"),
+
+		// 跨平台降级链
+		field.JSON("fallback_chain", []int64{}).
+			Optional().
+			SchemaType(map[string]string{dialect.Postgres: "jsonb"}).
+			Comment("跨平台降级链：本分组限流或上游故障时依次尝试的分组 ID"),
+
+		// 账号池调度策略 (added by migration 081)
+		field.String("scheduling_strategy").
+			MaxLen(32).
+			Default("").
+			Comment("账号调度策略：空=综合评分, round_robin, weighted, least_in_flight, least_recent_error"),
+
+		// 所属租户 (added by migration 086)，null 表示不属于任何租户
+		field.Int64("tenant_id").
+			Optional().
+			Nillable(),
 	}
 }
 
@@ -186,5 +209,6 @@ func (Group) Indexes() []ent.Index {
 		index.Fields("is_exclusive"),
 		index.Fields("deleted_at"),
 		index.Fields("sort_order"),
+		index.Fields("tenant_id"),
 	}
 }
@@ -0,0 +1,57 @@
+package schema
+
+import (
+	"github.com/ShaohongDong/sub2api/ent/schema/mixins"
+	"github.com/ShaohongDong/sub2api/internal/domain"
+
+	"entgo.io/ent"
+	"entgo.io/ent/dialect/entsql"
+	"entgo.io/ent/schema"
+	"entgo.io/ent/schema/field"
+	"entgo.io/ent/schema/index"
+)
+
+// Tenant holds the schema definition for the Tenant entity.
+//
+// 租户：同一部署内隔离的团队。用户与分组通过 tenant_id 归属租户，
+// API Key、订阅与用量随用户归属；账号通过所属分组归入租户的账号池。
+//
+// 删除策略：硬删除（仍有用户或分组归属时禁止删除）
+type Tenant struct {
+	ent.Schema
+}
+
+func (Tenant) Annotations() []schema.Annotation {
+	return []schema.Annotation{
+		entsql.Annotation{Table: "tenants"},
+	}
+}
+
+func (Tenant) Mixin() []ent.Mixin {
+	return []ent.Mixin{
+		mixins.TimeMixin{},
+	}
+}
+
+func (Tenant) Fields() []ent.Field {
+	return []ent.Field{
+		field.String("name").
+			MaxLen(100).
+			NotEmpty().
+			Unique(),
+		field.String("description").
+			MaxLen(500).
+			Default(""),
+		field.String("status").
+			MaxLen(20).
+			Default(domain.StatusActive).
+			Comment("状态: active, disabled"),
+	}
+}
+
+func (Tenant) Indexes() []ent.Index {
+	return []ent.Index{
+		// name 字段已在 Fields() 中声明 Unique()，无需重复索引
+		index.Fields("status"),
+	}
+}
@@ -38,6 +38,11 @@ func (UsageLog) Fields() []ent.Field {
 		field.String("request_id").
 			MaxLen(64).
 			NotEmpty(),
+		// gateway_request_id: 网关为本次请求分配的 X-Request-ID（request_id 为上游请求 ID，用于去重）
+		field.String("gateway_request_id").
+			MaxLen(128).
+			Optional().
+			Nillable(),
 		field.String("model").
 			MaxLen(100).
 			NotEmpty(),
@@ -61,6 +66,9 @@ func (UsageLog) Fields() []ent.Field {
 			Default(0),
 		field.Int("cache_creation_1h_tokens").
 			Default(0),
+		// reasoning_tokens: 推理 token 数（已包含在 output_tokens 中，仅用于统计）
+		field.Int("reasoning_tokens").
+			Default(0),
 
 		// 成本字段
 		field.Float("input_cost").
@@ -110,6 +118,11 @@ func (UsageLog) Fields() []ent.Field {
 			MaxLen(45). // 支持 IPv6
 			Optional().
 			Nillable(),
+		// client_type: 请求处理时由 clientdetect 综合 UA、originator 与 x-stainless-* 头识别的客户端类型
+		field.String("client_type").
+			MaxLen(32).
+			Optional().
+			Nillable(),
 
 		// 图片生成字段（仅 gemini-3-pro-image 等图片模型使用）
 		field.Int("image_count").
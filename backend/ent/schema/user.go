@@ -78,6 +78,12 @@ func (User) Fields() []ent.Field {
 			Default(0),
 		field.Int64("sora_storage_used_bytes").
 			Default(0),
+
+		// 所属租户 (added by migration 086)，null 表示不属于任何租户
+		// 注意：tenant_id 直接作为字段使用，不定义 edge
+		field.Int64("tenant_id").
+			Optional().
+			Nillable(),
 	}
 }
 
@@ -101,5 +107,6 @@ func (User) Indexes() []ent.Index {
 		// email 字段已在 Fields() 中声明 Unique()，无需重复索引
 		index.Fields("status"),
 		index.Fields("deleted_at"),
+		index.Fields("tenant_id"),
 	}
 }
@@ -51,6 +51,20 @@ func (_c *UsageLogCreate) SetRequestID(v string) *UsageLogCreate {
 	return _c
 }
 
+// SetGatewayRequestID sets the "gateway_request_id" field.
+func (_c *UsageLogCreate) SetGatewayRequestID(v string) *UsageLogCreate {
+	_c.mutation.SetGatewayRequestID(v)
+	return _c
+}
+
+// SetNillableGatewayRequestID sets the "gateway_request_id" field if the given value is not nil.
+func (_c *UsageLogCreate) SetNillableGatewayRequestID(v *string) *UsageLogCreate {
+	if v != nil {
+		_c.SetGatewayRequestID(*v)
+	}
+	return _c
+}
+
 // SetModel sets the "model" field.
 func (_c *UsageLogCreate) SetModel(v string) *UsageLogCreate {
 	_c.mutation.SetModel(v)
@@ -169,6 +183,20 @@ func (_c *UsageLogCreate) SetNillableCacheCreation1hTokens(v *int) *UsageLogCrea
 	return _c
 }
 
+// SetReasoningTokens sets the "reasoning_tokens" field.
+func (_c *UsageLogCreate) SetReasoningTokens(v int) *UsageLogCreate {
+	_c.mutation.SetReasoningTokens(v)
+	return _c
+}
+
+// SetNillableReasoningTokens sets the "reasoning_tokens" field if the given value is not nil.
+func (_c *UsageLogCreate) SetNillableReasoningTokens(v *int) *UsageLogCreate {
+	if v != nil {
+		_c.SetReasoningTokens(*v)
+	}
+	return _c
+}
+
 // SetInputCost sets the "input_cost" field.
 func (_c *UsageLogCreate) SetInputCost(v float64) *UsageLogCreate {
 	_c.mutation.SetInputCost(v)
@@ -365,6 +393,20 @@ func (_c *UsageLogCreate) SetNillableIPAddress(v *string) *UsageLogCreate {
 	return _c
 }
 
+// SetClientType sets the "client_type" field.
+func (_c *UsageLogCreate) SetClientType(v string) *UsageLogCreate {
+	_c.mutation.SetClientType(v)
+	return _c
+}
+
+// SetNillableClientType sets the "client_type" field if the given value is not nil.
+func (_c *UsageLogCreate) SetNillableClientType(v *string) *UsageLogCreate {
+	if v != nil {
+		_c.SetClientType(*v)
+	}
+	return _c
+}
+
 // SetImageCount sets the "image_count" field.
 func (_c *UsageLogCreate) SetImageCount(v int) *UsageLogCreate {
 	_c.mutation.SetImageCount(v)
@@ -519,6 +561,10 @@ func (_c *UsageLogCreate) defaults() {
 		v := usagelog.DefaultCacheCreation1hTokens
 		_c.mutation.SetCacheCreation1hTokens(v)
 	}
+	if _, ok := _c.mutation.ReasoningTokens(); !ok {
+		v := usagelog.DefaultReasoningTokens
+		_c.mutation.SetReasoningTokens(v)
+	}
 	if _, ok := _c.mutation.InputCost(); !ok {
 		v := usagelog.DefaultInputCost
 		_c.mutation.SetInputCost(v)
@@ -588,6 +634,11 @@ func (_c *UsageLogCreate) check() error {
 			return &ValidationError{Name: "request_id", err: fmt.Errorf(`ent: validator failed for field "UsageLog.request_id": %w`, err)}
 		}
 	}
+	if v, ok := _c.mutation.GatewayRequestID(); ok {
+		if err := usagelog.GatewayRequestIDValidator(v); err != nil {
+			return &ValidationError{Name: "gateway_request_id", err: fmt.Errorf(`ent: validator failed for field "UsageLog.gateway_request_id": %w`, err)}
+		}
+	}
 	if _, ok := _c.mutation.Model(); !ok {
 		return &ValidationError{Name: "model", err: errors.New(`ent: missing required field "UsageLog.model"`)}
 	}
@@ -614,6 +665,9 @@ func (_c *UsageLogCreate) check() error {
 	if _, ok := _c.mutation.CacheCreation1hTokens(); !ok {
 		return &ValidationError{Name: "cache_creation_1h_tokens", err: errors.New(`ent: missing required field "UsageLog.cache_creation_1h_tokens"`)}
 	}
+	if _, ok := _c.mutation.ReasoningTokens(); !ok {
+		return &ValidationError{Name: "reasoning_tokens", err: errors.New(`ent: missing required field "UsageLog.reasoning_tokens"`)}
+	}
 	if _, ok := _c.mutation.InputCost(); !ok {
 		return &ValidationError{Name: "input_cost", err: errors.New(`ent: missing required field "UsageLog.input_cost"`)}
 	}
@@ -651,6 +705,11 @@ func (_c *UsageLogCreate) check() error {
 			return &ValidationError{Name: "ip_address", err: fmt.Errorf(`ent: validator failed for field "UsageLog.ip_address": %w`, err)}
 		}
 	}
+	if v, ok := _c.mutation.ClientType(); ok {
+		if err := usagelog.ClientTypeValidator(v); err != nil {
+			return &ValidationError{Name: "client_type", err: fmt.Errorf(`ent: validator failed for field "UsageLog.client_type": %w`, err)}
+		}
+	}
 	if _, ok := _c.mutation.ImageCount(); !ok {
 		return &ValidationError{Name: "image_count", err: errors.New(`ent: missing required field "UsageLog.image_count"`)}
 	}
@@ -710,6 +769,10 @@ func (_c *UsageLogCreate) createSpec() (*UsageLog, *sqlgraph.CreateSpec) {
 		_spec.SetField(usagelog.FieldRequestID, field.TypeString, value)
 		_node.RequestID = value
 	}
+	if value, ok := _c.mutation.GatewayRequestID(); ok {
+		_spec.SetField(usagelog.FieldGatewayRequestID, field.TypeString, value)
+		_node.GatewayRequestID = &value
+	}
 	if value, ok := _c.mutation.Model(); ok {
 		_spec.SetField(usagelog.FieldModel, field.TypeString, value)
 		_node.Model = value
@@ -738,6 +801,10 @@ func (_c *UsageLogCreate) createSpec() (*UsageLog, *sqlgraph.CreateSpec) {
 		_spec.SetField(usagelog.FieldCacheCreation1hTokens, field.TypeInt, value)
 		_node.CacheCreation1hTokens = value
 	}
+	if value, ok := _c.mutation.ReasoningTokens(); ok {
+		_spec.SetField(usagelog.FieldReasoningTokens, field.TypeInt, value)
+		_node.ReasoningTokens = value
+	}
 	if value, ok := _c.mutation.InputCost(); ok {
 		_spec.SetField(usagelog.FieldInputCost, field.TypeFloat64, value)
 		_node.InputCost = value
@@ -794,6 +861,10 @@ func (_c *UsageLogCreate) createSpec() (*UsageLog, *sqlgraph.CreateSpec) {
 		_spec.SetField(usagelog.FieldIPAddress, field.TypeString, value)
 		_node.IPAddress = &value
 	}
+	if value, ok := _c.mutation.ClientType(); ok {
+		_spec.SetField(usagelog.FieldClientType, field.TypeString, value)
+		_node.ClientType = &value
+	}
 	if value, ok := _c.mutation.ImageCount(); ok {
 		_spec.SetField(usagelog.FieldImageCount, field.TypeInt, value)
 		_node.ImageCount = value
@@ -999,6 +1070,24 @@ func (u *UsageLogUpsert) UpdateRequestID() *UsageLogUpsert {
 	return u
 }
 
+// SetGatewayRequestID sets the "gateway_request_id" field.
+func (u *UsageLogUpsert) SetGatewayRequestID(v string) *UsageLogUpsert {
+	u.Set(usagelog.FieldGatewayRequestID, v)
+	return u
+}
+
+// UpdateGatewayRequestID sets the "gateway_request_id" field to the value that was provided on create.
+func (u *UsageLogUpsert) UpdateGatewayRequestID() *UsageLogUpsert {
+	u.SetExcluded(usagelog.FieldGatewayRequestID)
+	return u
+}
+
+// ClearGatewayRequestID clears the value of the "gateway_request_id" field.
+func (u *UsageLogUpsert) ClearGatewayRequestID() *UsageLogUpsert {
+	u.SetNull(usagelog.FieldGatewayRequestID)
+	return u
+}
+
 // SetModel sets the "model" field.
 func (u *UsageLogUpsert) SetModel(v string) *UsageLogUpsert {
 	u.Set(usagelog.FieldModel, v)
@@ -1155,6 +1244,24 @@ func (u *UsageLogUpsert) AddCacheCreation1hTokens(v int) *UsageLogUpsert {
 	return u
 }
 
+// SetReasoningTokens sets the "reasoning_tokens" field.
+func (u *UsageLogUpsert) SetReasoningTokens(v int) *UsageLogUpsert {
+	u.Set(usagelog.FieldReasoningTokens, v)
+	return u
+}
+
+// UpdateReasoningTokens sets the "reasoning_tokens" field to the value that was provided on create.
+func (u *UsageLogUpsert) UpdateReasoningTokens() *UsageLogUpsert {
+	u.SetExcluded(usagelog.FieldReasoningTokens)
+	return u
+}
+
+// AddReasoningTokens adds v to the "reasoning_tokens" field.
+func (u *UsageLogUpsert) AddReasoningTokens(v int) *UsageLogUpsert {
+	u.Add(usagelog.FieldReasoningTokens, v)
+	return u
+}
+
 // SetInputCost sets the "input_cost" field.
 func (u *UsageLogUpsert) SetInputCost(v float64) *UsageLogUpsert {
 	u.Set(usagelog.FieldInputCost, v)
@@ -1419,6 +1526,24 @@ func (u *UsageLogUpsert) ClearIPAddress() *UsageLogUpsert {
 	return u
 }
 
+// SetClientType sets the "client_type" field.
+func (u *UsageLogUpsert) SetClientType(v string) *UsageLogUpsert {
+	u.Set(usagelog.FieldClientType, v)
+	return u
+}
+
+// UpdateClientType sets the "client_type" field to the value that was provided on create.
+func (u *UsageLogUpsert) UpdateClientType() *UsageLogUpsert {
+	u.SetExcluded(usagelog.FieldClientType)
+	return u
+}
+
+// ClearClientType clears the value of the "client_type" field.
+func (u *UsageLogUpsert) ClearClientType() *UsageLogUpsert {
+	u.SetNull(usagelog.FieldClientType)
+	return u
+}
+
 // SetImageCount sets the "image_count" field.
 func (u *UsageLogUpsert) SetImageCount(v int) *UsageLogUpsert {
 	u.Set(usagelog.FieldImageCount, v)
@@ -1586,6 +1711,27 @@ func (u *UsageLogUpsertOne) UpdateRequestID() *UsageLogUpsertOne {
 	})
 }
 
+// SetGatewayRequestID sets the "gateway_request_id" field.
+func (u *UsageLogUpsertOne) SetGatewayRequestID(v string) *UsageLogUpsertOne {
+	return u.Update(func(s *UsageLogUpsert) {
+		s.SetGatewayRequestID(v)
+	})
+}
+
+// UpdateGatewayRequestID sets the "gateway_request_id" field to the value that was provided on create.
+func (u *UsageLogUpsertOne) UpdateGatewayRequestID() *UsageLogUpsertOne {
+	return u.Update(func(s *UsageLogUpsert) {
+		s.UpdateGatewayRequestID()
+	})
+}
+
+// ClearGatewayRequestID clears the value of the "gateway_request_id" field.
+func (u *UsageLogUpsertOne) ClearGatewayRequestID() *UsageLogUpsertOne {
+	return u.Update(func(s *UsageLogUpsert) {
+		s.ClearGatewayRequestID()
+	})
+}
+
 // SetModel sets the "model" field.
 func (u *UsageLogUpsertOne) SetModel(v string) *UsageLogUpsertOne {
 	return u.Update(func(s *UsageLogUpsert) {
@@ -1768,6 +1914,27 @@ func (u *UsageLogUpsertOne) UpdateCacheCreation1hTokens() *UsageLogUpsertOne {
 	})
 }
 
+// SetReasoningTokens sets the "reasoning_tokens" field.
+func (u *UsageLogUpsertOne) SetReasoningTokens(v int) *UsageLogUpsertOne {
+	return u.Update(func(s *UsageLogUpsert) {
+		s.SetReasoningTokens(v)
+	})
+}
+
+// AddReasoningTokens adds v to the "reasoning_tokens" field.
+func (u *UsageLogUpsertOne) AddReasoningTokens(v int) *UsageLogUpsertOne {
+	return u.Update(func(s *UsageLogUpsert) {
+		s.AddReasoningTokens(v)
+	})
+}
+
+// UpdateReasoningTokens sets the "reasoning_tokens" field to the value that was provided on create.
+func (u *UsageLogUpsertOne) UpdateReasoningTokens() *UsageLogUpsertOne {
+	return u.Update(func(s *UsageLogUpsert) {
+		s.UpdateReasoningTokens()
+	})
+}
+
 // SetInputCost sets the "input_cost" field.
 func (u *UsageLogUpsertOne) SetInputCost(v float64) *UsageLogUpsertOne {
 	return u.Update(func(s *UsageLogUpsert) {
@@ -2076,6 +2243,27 @@ func (u *UsageLogUpsertOne) ClearIPAddress() *UsageLogUpsertOne {
 	})
 }
 
+// SetClientType sets the "client_type" field.
+func (u *UsageLogUpsertOne) SetClientType(v string) *UsageLogUpsertOne {
+	return u.Update(func(s *UsageLogUpsert) {
+		s.SetClientType(v)
+	})
+}
+
+// UpdateClientType sets the "client_type" field to the value that was provided on create.
+func (u *UsageLogUpsertOne) UpdateClientType() *UsageLogUpsertOne {
+	return u.Update(func(s *UsageLogUpsert) {
+		s.UpdateClientType()
+	})
+}
+
+// ClearClientType clears the value of the "client_type" field.
+func (u *UsageLogUpsertOne) ClearClientType() *UsageLogUpsertOne {
+	return u.Update(func(s *UsageLogUpsert) {
+		s.ClearClientType()
+	})
+}
+
 // SetImageCount sets the "image_count" field.
 func (u *UsageLogUpsertOne) SetImageCount(v int) *UsageLogUpsertOne {
 	return u.Update(func(s *UsageLogUpsert) {
@@ -2420,6 +2608,27 @@ func (u *UsageLogUpsertBulk) UpdateRequestID() *UsageLogUpsertBulk {
 	})
 }
 
+// SetGatewayRequestID sets the "gateway_request_id" field.
+func (u *UsageLogUpsertBulk) SetGatewayRequestID(v string) *UsageLogUpsertBulk {
+	return u.Update(func(s *UsageLogUpsert) {
+		s.SetGatewayRequestID(v)
+	})
+}
+
+// UpdateGatewayRequestID sets the "gateway_request_id" field to the value that was provided on create.
+func (u *UsageLogUpsertBulk) UpdateGatewayRequestID() *UsageLogUpsertBulk {
+	return u.Update(func(s *UsageLogUpsert) {
+		s.UpdateGatewayRequestID()
+	})
+}
+
+// ClearGatewayRequestID clears the value of the "gateway_request_id" field.
+func (u *UsageLogUpsertBulk) ClearGatewayRequestID() *UsageLogUpsertBulk {
+	return u.Update(func(s *UsageLogUpsert) {
+		s.ClearGatewayRequestID()
+	})
+}
+
 // SetModel sets the "model" field.
 func (u *UsageLogUpsertBulk) SetModel(v string) *UsageLogUpsertBulk {
 	return u.Update(func(s *UsageLogUpsert) {
@@ -2602,6 +2811,27 @@ func (u *UsageLogUpsertBulk) UpdateCacheCreation1hTokens() *UsageLogUpsertBulk {
 	})
 }
 
+// SetReasoningTokens sets the "reasoning_tokens" field.
+func (u *UsageLogUpsertBulk) SetReasoningTokens(v int) *UsageLogUpsertBulk {
+	return u.Update(func(s *UsageLogUpsert) {
+		s.SetReasoningTokens(v)
+	})
+}
+
+// AddReasoningTokens adds v to the "reasoning_tokens" field.
+func (u *UsageLogUpsertBulk) AddReasoningTokens(v int) *UsageLogUpsertBulk {
+	return u.Update(func(s *UsageLogUpsert) {
+		s.AddReasoningTokens(v)
+	})
+}
+
+// UpdateReasoningTokens sets the "reasoning_tokens" field to the value that was provided on create.
+func (u *UsageLogUpsertBulk) UpdateReasoningTokens() *UsageLogUpsertBulk {
+	return u.Update(func(s *UsageLogUpsert) {
+		s.UpdateReasoningTokens()
+	})
+}
+
 // SetInputCost sets the "input_cost" field.
 func (u *UsageLogUpsertBulk) SetInputCost(v float64) *UsageLogUpsertBulk {
 	return u.Update(func(s *UsageLogUpsert) {
@@ -2910,6 +3140,27 @@ func (u *UsageLogUpsertBulk) ClearIPAddress() *UsageLogUpsertBulk {
 	})
 }
 
+// SetClientType sets the "client_type" field.
+func (u *UsageLogUpsertBulk) SetClientType(v string) *UsageLogUpsertBulk {
+	return u.Update(func(s *UsageLogUpsert) {
+		s.SetClientType(v)
+	})
+}
+
+// UpdateClientType sets the "client_type" field to the value that was provided on create.
+func (u *UsageLogUpsertBulk) UpdateClientType() *UsageLogUpsertBulk {
+	return u.Update(func(s *UsageLogUpsert) {
+		s.UpdateClientType()
+	})
+}
+
+// ClearClientType clears the value of the "client_type" field.
+func (u *UsageLogUpsertBulk) ClearClientType() *UsageLogUpsertBulk {
+	return u.Update(func(s *UsageLogUpsert) {
+		s.ClearClientType()
+	})
+}
+
 // SetImageCount sets the "image_count" field.
 func (u *UsageLogUpsertBulk) SetImageCount(v int) *UsageLogUpsertBulk {
 	return u.Update(func(s *UsageLogUpsert) {
@@ -88,6 +88,26 @@ func (_u *UsageLogUpdate) SetNillableRequestID(v *string) *UsageLogUpdate {
 	return _u
 }
 
+// SetGatewayRequestID sets the "gateway_request_id" field.
+func (_u *UsageLogUpdate) SetGatewayRequestID(v string) *UsageLogUpdate {
+	_u.mutation.SetGatewayRequestID(v)
+	return _u
+}
+
+// SetNillableGatewayRequestID sets the "gateway_request_id" field if the given value is not nil.
+func (_u *UsageLogUpdate) SetNillableGatewayRequestID(v *string) *UsageLogUpdate {
+	if v != nil {
+		_u.SetGatewayRequestID(*v)
+	}
+	return _u
+}
+
+// ClearGatewayRequestID clears the value of the "gateway_request_id" field.
+func (_u *UsageLogUpdate) ClearGatewayRequestID() *UsageLogUpdate {
+	_u.mutation.ClearGatewayRequestID()
+	return _u
+}
+
 // SetModel sets the "model" field.
 func (_u *UsageLogUpdate) SetModel(v string) *UsageLogUpdate {
 	_u.mutation.SetModel(v)
@@ -268,6 +288,27 @@ func (_u *UsageLogUpdate) AddCacheCreation1hTokens(v int) *UsageLogUpdate {
 	return _u
 }
 
+// SetReasoningTokens sets the "reasoning_tokens" field.
+func (_u *UsageLogUpdate) SetReasoningTokens(v int) *UsageLogUpdate {
+	_u.mutation.ResetReasoningTokens()
+	_u.mutation.SetReasoningTokens(v)
+	return _u
+}
+
+// SetNillableReasoningTokens sets the "reasoning_tokens" field if the given value is not nil.
+func (_u *UsageLogUpdate) SetNillableReasoningTokens(v *int) *UsageLogUpdate {
+	if v != nil {
+		_u.SetReasoningTokens(*v)
+	}
+	return _u
+}
+
+// AddReasoningTokens adds value to the "reasoning_tokens" field.
+func (_u *UsageLogUpdate) AddReasoningTokens(v int) *UsageLogUpdate {
+	_u.mutation.AddReasoningTokens(v)
+	return _u
+}
+
 // SetInputCost sets the "input_cost" field.
 func (_u *UsageLogUpdate) SetInputCost(v float64) *UsageLogUpdate {
 	_u.mutation.ResetInputCost()
@@ -571,6 +612,26 @@ func (_u *UsageLogUpdate) ClearIPAddress() *UsageLogUpdate {
 	return _u
 }
 
+// SetClientType sets the "client_type" field.
+func (_u *UsageLogUpdate) SetClientType(v string) *UsageLogUpdate {
+	_u.mutation.SetClientType(v)
+	return _u
+}
+
+// SetNillableClientType sets the "client_type" field if the given value is not nil.
+func (_u *UsageLogUpdate) SetNillableClientType(v *string) *UsageLogUpdate {
+	if v != nil {
+		_u.SetClientType(*v)
+	}
+	return _u
+}
+
+// ClearClientType clears the value of the "client_type" field.
+func (_u *UsageLogUpdate) ClearClientType() *UsageLogUpdate {
+	_u.mutation.ClearClientType()
+	return _u
+}
+
 // SetImageCount sets the "image_count" field.
 func (_u *UsageLogUpdate) SetImageCount(v int) *UsageLogUpdate {
 	_u.mutation.ResetImageCount()
@@ -740,6 +801,11 @@ func (_u *UsageLogUpdate) check() error {
 			return &ValidationError{Name: "request_id", err: fmt.Errorf(`ent: validator failed for field "UsageLog.request_id": %w`, err)}
 		}
 	}
+	if v, ok := _u.mutation.GatewayRequestID(); ok {
+		if err := usagelog.GatewayRequestIDValidator(v); err != nil {
+			return &ValidationError{Name: "gateway_request_id", err: fmt.Errorf(`ent: validator failed for field "UsageLog.gateway_request_id": %w`, err)}
+		}
+	}
 	if v, ok := _u.mutation.Model(); ok {
 		if err := usagelog.ModelValidator(v); err != nil {
 			return &ValidationError{Name: "model", err: fmt.Errorf(`ent: validator failed for field "UsageLog.model": %w`, err)}
@@ -755,6 +821,11 @@ func (_u *UsageLogUpdate) check() error {
 			return &ValidationError{Name: "ip_address", err: fmt.Errorf(`ent: validator failed for field "UsageLog.ip_address": %w`, err)}
 		}
 	}
+	if v, ok := _u.mutation.ClientType(); ok {
+		if err := usagelog.ClientTypeValidator(v); err != nil {
+			return &ValidationError{Name: "client_type", err: fmt.Errorf(`ent: validator failed for field "UsageLog.client_type": %w`, err)}
+		}
+	}
 	if v, ok := _u.mutation.ImageSize(); ok {
 		if err := usagelog.ImageSizeValidator(v); err != nil {
 			return &ValidationError{Name: "image_size", err: fmt.Errorf(`ent: validator failed for field "UsageLog.image_size": %w`, err)}
@@ -792,6 +863,12 @@ func (_u *UsageLogUpdate) sqlSave(ctx context.Context) (_node int, err error) {
 	if value, ok := _u.mutation.RequestID(); ok {
 		_spec.SetField(usagelog.FieldRequestID, field.TypeString, value)
 	}
+	if value, ok := _u.mutation.GatewayRequestID(); ok {
+		_spec.SetField(usagelog.FieldGatewayRequestID, field.TypeString, value)
+	}
+	if _u.mutation.GatewayRequestIDCleared() {
+		_spec.ClearField(usagelog.FieldGatewayRequestID, field.TypeString)
+	}
 	if value, ok := _u.mutation.Model(); ok {
 		_spec.SetField(usagelog.FieldModel, field.TypeString, value)
 	}
@@ -831,6 +908,12 @@ func (_u *UsageLogUpdate) sqlSave(ctx context.Context) (_node int, err error) {
 	if value, ok := _u.mutation.AddedCacheCreation1hTokens(); ok {
 		_spec.AddField(usagelog.FieldCacheCreation1hTokens, field.TypeInt, value)
 	}
+	if value, ok := _u.mutation.ReasoningTokens(); ok {
+		_spec.SetField(usagelog.FieldReasoningTokens, field.TypeInt, value)
+	}
+	if value, ok := _u.mutation.AddedReasoningTokens(); ok {
+		_spec.AddField(usagelog.FieldReasoningTokens, field.TypeInt, value)
+	}
 	if value, ok := _u.mutation.InputCost(); ok {
 		_spec.SetField(usagelog.FieldInputCost, field.TypeFloat64, value)
 	}
@@ -921,6 +1004,12 @@ func (_u *UsageLogUpdate) sqlSave(ctx context.Context) (_node int, err error) {
 	if _u.mutation.IPAddressCleared() {
 		_spec.ClearField(usagelog.FieldIPAddress, field.TypeString)
 	}
+	if value, ok := _u.mutation.ClientType(); ok {
+		_spec.SetField(usagelog.FieldClientType, field.TypeString, value)
+	}
+	if _u.mutation.ClientTypeCleared() {
+		_spec.ClearField(usagelog.FieldClientType, field.TypeString)
+	}
 	if value, ok := _u.mutation.ImageCount(); ok {
 		_spec.SetField(usagelog.FieldImageCount, field.TypeInt, value)
 	}
@@ -1163,6 +1252,26 @@ func (_u *UsageLogUpdateOne) SetNillableRequestID(v *string) *UsageLogUpdateOne
 	return _u
 }
 
+// SetGatewayRequestID sets the "gateway_request_id" field.
+func (_u *UsageLogUpdateOne) SetGatewayRequestID(v string) *UsageLogUpdateOne {
+	_u.mutation.SetGatewayRequestID(v)
+	return _u
+}
+
+// SetNillableGatewayRequestID sets the "gateway_request_id" field if the given value is not nil.
+func (_u *UsageLogUpdateOne) SetNillableGatewayRequestID(v *string) *UsageLogUpdateOne {
+	if v != nil {
+		_u.SetGatewayRequestID(*v)
+	}
+	return _u
+}
+
+// ClearGatewayRequestID clears the value of the "gateway_request_id" field.
+func (_u *UsageLogUpdateOne) ClearGatewayRequestID() *UsageLogUpdateOne {
+	_u.mutation.ClearGatewayRequestID()
+	return _u
+}
+
 // SetModel sets the "model" field.
 func (_u *UsageLogUpdateOne) SetModel(v string) *UsageLogUpdateOne {
 	_u.mutation.SetModel(v)
@@ -1343,6 +1452,27 @@ func (_u *UsageLogUpdateOne) AddCacheCreation1hTokens(v int) *UsageLogUpdateOne
 	return _u
 }
 
+// SetReasoningTokens sets the "reasoning_tokens" field.
+func (_u *UsageLogUpdateOne) SetReasoningTokens(v int) *UsageLogUpdateOne {
+	_u.mutation.ResetReasoningTokens()
+	_u.mutation.SetReasoningTokens(v)
+	return _u
+}
+
+// SetNillableReasoningTokens sets the "reasoning_tokens" field if the given value is not nil.
+func (_u *UsageLogUpdateOne) SetNillableReasoningTokens(v *int) *UsageLogUpdateOne {
+	if v != nil {
+		_u.SetReasoningTokens(*v)
+	}
+	return _u
+}
+
+// AddReasoningTokens adds value to the "reasoning_tokens" field.
+func (_u *UsageLogUpdateOne) AddReasoningTokens(v int) *UsageLogUpdateOne {
+	_u.mutation.AddReasoningTokens(v)
+	return _u
+}
+
 // SetInputCost sets the "input_cost" field.
 func (_u *UsageLogUpdateOne) SetInputCost(v float64) *UsageLogUpdateOne {
 	_u.mutation.ResetInputCost()
@@ -1646,6 +1776,26 @@ func (_u *UsageLogUpdateOne) ClearIPAddress() *UsageLogUpdateOne {
 	return _u
 }
 
+// SetClientType sets the "client_type" field.
+func (_u *UsageLogUpdateOne) SetClientType(v string) *UsageLogUpdateOne {
+	_u.mutation.SetClientType(v)
+	return _u
+}
+
+// SetNillableClientType sets the "client_type" field if the given value is not nil.
+func (_u *UsageLogUpdateOne) SetNillableClientType(v *string) *UsageLogUpdateOne {
+	if v != nil {
+		_u.SetClientType(*v)
+	}
+	return _u
+}
+
+// ClearClientType clears the value of the "client_type" field.
+func (_u *UsageLogUpdateOne) ClearClientType() *UsageLogUpdateOne {
+	_u.mutation.ClearClientType()
+	return _u
+}
+
 // SetImageCount sets the "image_count" field.
 func (_u *UsageLogUpdateOne) SetImageCount(v int) *UsageLogUpdateOne {
 	_u.mutation.ResetImageCount()
@@ -1828,6 +1978,11 @@ func (_u *UsageLogUpdateOne) check() error {
 			return &ValidationError{Name: "request_id", err: fmt.Errorf(`ent: validator failed for field "UsageLog.request_id": %w`, err)}
 		}
 	}
+	if v, ok := _u.mutation.GatewayRequestID(); ok {
+		if err := usagelog.GatewayRequestIDValidator(v); err != nil {
+			return &ValidationError{Name: "gateway_request_id", err: fmt.Errorf(`ent: validator failed for field "UsageLog.gateway_request_id": %w`, err)}
+		}
+	}
 	if v, ok := _u.mutation.Model(); ok {
 		if err := usagelog.ModelValidator(v); err != nil {
 			return &ValidationError{Name: "model", err: fmt.Errorf(`ent: validator failed for field "UsageLog.model": %w`, err)}
@@ -1843,6 +1998,11 @@ func (_u *UsageLogUpdateOne) check() error {
 			return &ValidationError{Name: "ip_address", err: fmt.Errorf(`ent: validator failed for field "UsageLog.ip_address": %w`, err)}
 		}
 	}
+	if v, ok := _u.mutation.ClientType(); ok {
+		if err := usagelog.ClientTypeValidator(v); err != nil {
+			return &ValidationError{Name: "client_type", err: fmt.Errorf(`ent: validator failed for field "UsageLog.client_type": %w`, err)}
+		}
+	}
 	if v, ok := _u.mutation.ImageSize(); ok {
 		if err := usagelog.ImageSizeValidator(v); err != nil {
 			return &ValidationError{Name: "image_size", err: fmt.Errorf(`ent: validator failed for field "UsageLog.image_size": %w`, err)}
@@ -1897,6 +2057,12 @@ func (_u *UsageLogUpdateOne) sqlSave(ctx context.Context) (_node *UsageLog, err
 	if value, ok := _u.mutation.RequestID(); ok {
 		_spec.SetField(usagelog.FieldRequestID, field.TypeString, value)
 	}
+	if value, ok := _u.mutation.GatewayRequestID(); ok {
+		_spec.SetField(usagelog.FieldGatewayRequestID, field.TypeString, value)
+	}
+	if _u.mutation.GatewayRequestIDCleared() {
+		_spec.ClearField(usagelog.FieldGatewayRequestID, field.TypeString)
+	}
 	if value, ok := _u.mutation.Model(); ok {
 		_spec.SetField(usagelog.FieldModel, field.TypeString, value)
 	}
@@ -1936,6 +2102,12 @@ func (_u *UsageLogUpdateOne) sqlSave(ctx context.Context) (_node *UsageLog, err
 	if value, ok := _u.mutation.AddedCacheCreation1hTokens(); ok {
 		_spec.AddField(usagelog.FieldCacheCreation1hTokens, field.TypeInt, value)
 	}
+	if value, ok := _u.mutation.ReasoningTokens(); ok {
+		_spec.SetField(usagelog.FieldReasoningTokens, field.TypeInt, value)
+	}
+	if value, ok := _u.mutation.AddedReasoningTokens(); ok {
+		_spec.AddField(usagelog.FieldReasoningTokens, field.TypeInt, value)
+	}
 	if value, ok := _u.mutation.InputCost(); ok {
 		_spec.SetField(usagelog.FieldInputCost, field.TypeFloat64, value)
 	}
@@ -2026,6 +2198,12 @@ func (_u *UsageLogUpdateOne) sqlSave(ctx context.Context) (_node *UsageLog, err
 	if _u.mutation.IPAddressCleared() {
 		_spec.ClearField(usagelog.FieldIPAddress, field.TypeString)
 	}
+	if value, ok := _u.mutation.ClientType(); ok {
+		_spec.SetField(usagelog.FieldClientType, field.TypeString, value)
+	}
+	if _u.mutation.ClientTypeCleared() {
+		_spec.ClearField(usagelog.FieldClientType, field.TypeString)
+	}
 	if value, ok := _u.mutation.ImageCount(); ok {
 		_spec.SetField(usagelog.FieldImageCount, field.TypeInt, value)
 	}
@@ -4,11 +4,15 @@ package config
 import (
 	"crypto/rand"
 	"encoding/hex"
+	"encoding/json"
 	"fmt"
 	"log/slog"
 	"net/url"
 	"os"
+	"regexp"
+	"strconv"
 	"strings"
+	"text/template"
 	"time"
 
 	"github.com/spf13/viper"
@@ -62,21 +66,42 @@ type Config struct {
 	Database                DatabaseConfig                `mapstructure:"database"`
 	Redis                   RedisConfig                   `mapstructure:"redis"`
 	Ops                     OpsConfig                     `mapstructure:"ops"`
+	Metrics                 MetricsConfig                 `mapstructure:"metrics"`
+	Health                  HealthConfig                  `mapstructure:"health"`
+	Tracing                 TracingConfig                 `mapstructure:"tracing"`
 	JWT                     JWTConfig                     `mapstructure:"jwt"`
 	Totp                    TotpConfig                    `mapstructure:"totp"`
+	CredentialEncryption    CredentialEncryptionConfig    `mapstructure:"credential_encryption"`
 	LinuxDo                 LinuxDoConnectConfig          `mapstructure:"linuxdo_connect"`
+	OIDC                    OIDCConfig                    `mapstructure:"oidc"`
 	Default                 DefaultConfig                 `mapstructure:"default"`
 	RateLimit               RateLimitConfig               `mapstructure:"rate_limit"`
 	Pricing                 PricingConfig                 `mapstructure:"pricing"`
 	Gateway                 GatewayConfig                 `mapstructure:"gateway"`
 	APIKeyAuth              APIKeyAuthCacheConfig         `mapstructure:"api_key_auth_cache"`
+	APIKeyBudget            APIKeyBudgetConfig            `mapstructure:"api_key_budget"`
+	AlertWebhooks           AlertWebhooksConfig           `mapstructure:"alert_webhooks"`
+	UsageWebhook            UsageWebhookConfig            `mapstructure:"usage_webhook"`
 	SubscriptionCache       SubscriptionCacheConfig       `mapstructure:"subscription_cache"`
 	SubscriptionMaintenance SubscriptionMaintenanceConfig `mapstructure:"subscription_maintenance"`
 	Dashboard               DashboardCacheConfig          `mapstructure:"dashboard_cache"`
 	DashboardAgg            DashboardAggregationConfig    `mapstructure:"dashboard_aggregation"`
 	UsageCleanup            UsageCleanupConfig            `mapstructure:"usage_cleanup"`
+	Batch                   BatchConfig                   `mapstructure:"batch"`
+	BackgroundResponses     BackgroundResponsesConfig     `mapstructure:"background_responses"`
+	SSEReplay               SSEReplayConfig               `mapstructure:"sse_replay"`
+	ResponseCache           ResponseCacheConfig           `mapstructure:"response_cache"`
+	ConversationStore       ConversationStoreConfig       `mapstructure:"conversation_store"`
+	SharedState             SharedStateConfig             `mapstructure:"shared_state"`
+	Cluster                 ClusterConfig                 `mapstructure:"cluster"`
+	Files                   FilesConfig                   `mapstructure:"files"`
+	DeadLetter              DeadLetterConfig              `mapstructure:"dead_letter"`
+	ContentLog              ContentLogConfig              `mapstructure:"content_log"`
 	Concurrency             ConcurrencyConfig             `mapstructure:"concurrency"`
 	TokenRefresh            TokenRefreshConfig            `mapstructure:"token_refresh"`
+	AccountHealth           AccountHealthConfig           `mapstructure:"account_health"`
+	AccountWarmup           AccountWarmupConfig           `mapstructure:"account_warmup"`
+	CodexModelSync          CodexModelSyncConfig          `mapstructure:"codex_model_sync"`
 	Sora                    SoraConfig                    `mapstructure:"sora"`
 	RunMode                 string                        `mapstructure:"run_mode" yaml:"run_mode"`
 	Timezone                string                        `mapstructure:"timezone"` // e.g. "Asia/Shanghai", "UTC"
@@ -95,6 +120,7 @@ type LogConfig struct {
 	Output          LogOutputConfig   `mapstructure:"output"`
 	Rotation        LogRotationConfig `mapstructure:"rotation"`
 	Sampling        LogSamplingConfig `mapstructure:"sampling"`
+	Access          AccessLogConfig   `mapstructure:"access"`
 }
 
 type LogOutputConfig struct {
@@ -117,6 +143,21 @@ type LogSamplingConfig struct {
 	Thereafter int  `mapstructure:"thereafter"`
 }
 
+// AccessLogConfig 访问日志（component=http.access）的附加字段与脱敏策略。
+// Authorization / x-api-key / Cookie 等凭证头以及 ?key= 等凭证查询参数始终脱敏，不可关闭。
+type AccessLogConfig struct {
+	// Headers 记录请求头（凭证头替换为 ***）
+	Headers bool `mapstructure:"headers"`
+	// RedactHeaders 除内置凭证头外额外脱敏的请求头名称（不区分大小写）
+	RedactHeaders []string `mapstructure:"redact_headers"`
+	// HashPrompts 记录请求体的 SHA-256（prompt_sha256），用于比对重复请求而不落盘原文
+	HashPrompts bool `mapstructure:"hash_prompts"`
+	// BodyAPIKeyIDs 仅对这些 API Key 记录截断后的请求体（经 logredact 脱敏），用于排查单个 Key 的问题
+	BodyAPIKeyIDs []int64 `mapstructure:"body_api_key_ids"`
+	// BodyMaxBytes 请求体记录的最大字节数
+	BodyMaxBytes int `mapstructure:"body_max_bytes"`
+}
+
 type GeminiConfig struct {
 	OAuth GeminiOAuthConfig `mapstructure:"oauth"`
 	Quota GeminiQuotaConfig `mapstructure:"quota"`
@@ -163,6 +204,18 @@ type IdempotencyConfig struct {
 	CleanupIntervalSeconds int `mapstructure:"cleanup_interval_seconds"`
 	// CleanupBatchSize 每次清理的最大记录数。
 	CleanupBatchSize int `mapstructure:"cleanup_batch_size"`
+	// Gateway 网关推理请求的 Idempotency-Key 支持
+	Gateway GatewayIdempotencyConfig `mapstructure:"gateway"`
+}
+
+// GatewayIdempotencyConfig 网关推理请求的幂等配置：携带 Idempotency-Key 的非流式请求在 TTL 内重复提交时
+// 直接返回首次的成功响应（不再转发上游、不计费），并发的重复请求返回 409。
+type GatewayIdempotencyConfig struct {
+	Enabled bool `mapstructure:"enabled"`
+	// TTLSeconds 成功响应的保留时间（秒），窗口内的重试直接重放
+	TTLSeconds int `mapstructure:"ttl_seconds"`
+	// MaxResponseBytes 可保存的最大响应体（字节）；超过时响应照常返回但不保存，之后的重试会重新执行
+	MaxResponseBytes int `mapstructure:"max_response_bytes"`
 }
 
 type LinuxDoConnectConfig struct {
@@ -185,6 +238,36 @@ type LinuxDoConnectConfig struct {
 	UserInfoUsernamePath string `mapstructure:"userinfo_username_path"`
 }
 
+// OIDCConfig 管理后台 OIDC/SSO 登录（Google / Authentik / Keycloak 等）
+type OIDCConfig struct {
+	Enabled             bool   `mapstructure:"enabled"`
+	ProviderName        string `mapstructure:"provider_name"` // 登录按钮上显示的名称
+	IssuerURL           string `mapstructure:"issuer_url"`    // 通过 {issuer}/.well-known/openid-configuration 自动发现端点
+	ClientID            string `mapstructure:"client_id"`
+	ClientSecret        string `mapstructure:"client_secret"`
+	Scopes              string `mapstructure:"scopes"`
+	RedirectURL         string `mapstructure:"redirect_url"`          // 后端回调地址（需在提供方后台登记）
+	FrontendRedirectURL string `mapstructure:"frontend_redirect_url"` // 前端接收 token 的路由（默认：/auth/oidc/callback）
+	TokenAuthMethod     string `mapstructure:"token_auth_method"`     // client_secret_basic / client_secret_post / none
+	UsePKCE             bool   `mapstructure:"use_pkce"`
+
+	// GroupsClaim 用于角色映射的声明路径（gjson 语法），如 groups、realm_access.roles
+	GroupsClaim string `mapstructure:"groups_claim"`
+	// RoleMappings 组到角色（viewer / operator / admin）的映射，命中多个时取最高角色
+	RoleMappings []OIDCRoleMapping `mapstructure:"role_mappings"`
+	// DefaultRole 未命中任何映射时授予的角色；为空表示拒绝登录
+	DefaultRole string `mapstructure:"default_role"`
+	// AllowedEmailDomains 非空时仅允许这些域名的邮箱登录
+	AllowedEmailDomains []string `mapstructure:"allowed_email_domains"`
+	// AllowUnverifiedEmail 是否接受 email_verified=false 的身份（默认拒绝，防止借用他人邮箱）
+	AllowUnverifiedEmail bool `mapstructure:"allow_unverified_email"`
+}
+
+type OIDCRoleMapping struct {
+	Group string `mapstructure:"group"`
+	Role  string `mapstructure:"role"`
+}
+
 // TokenRefreshConfig OAuth token自动刷新配置
 type TokenRefreshConfig struct {
 	// 是否启用自动刷新
@@ -197,6 +280,8 @@ type TokenRefreshConfig struct {
 	MaxRetries int `mapstructure:"max_retries"`
 	// 重试退避基础时间（秒）
 	RetryBackoffSeconds int `mapstructure:"retry_backoff_seconds"`
+	// 提前刷新随机抖动（秒），每个账号的提前刷新时间额外增加 [0, jitter) 秒，避免同时过期的账号集中刷新
+	JitterSeconds int `mapstructure:"jitter_seconds"`
 	// 是否允许 OpenAI 刷新器同步覆盖关联的 Sora 账号 token（默认关闭）
 	SyncLinkedSoraAccounts bool `mapstructure:"sync_linked_sora_accounts"`
 }
@@ -214,6 +299,20 @@ type PricingConfig struct {
 	UpdateIntervalHours int `mapstructure:"update_interval_hours"`
 	// 哈希校验间隔（分钟）
 	HashCheckIntervalMinutes int `mapstructure:"hash_check_interval_minutes"`
+	// 自定义模型价格，优先于远程价格数据与内置回退价格（管理后台配置的价格优先于此处）
+	Overrides []PricingOverrideConfig `mapstructure:"overrides"`
+}
+
+// PricingOverrideConfig 单个模型的自定义价格（USD / 百万 token）。
+// 未设置的字段沿用远程价格数据或内置回退价格中的对应值。
+type PricingOverrideConfig struct {
+	// Model 模型名（不区分大小写），支持末尾 * 通配符
+	Model                  string   `mapstructure:"model"`
+	InputPerMTok           *float64 `mapstructure:"input_per_mtok"`
+	OutputPerMTok          *float64 `mapstructure:"output_per_mtok"`
+	CacheCreationPerMTok   *float64 `mapstructure:"cache_creation_per_mtok"`
+	CacheCreation1hPerMTok *float64 `mapstructure:"cache_creation_1h_per_mtok"`
+	CacheReadPerMTok       *float64 `mapstructure:"cache_read_per_mtok"`
 }
 
 type ServerConfig struct {
@@ -226,6 +325,18 @@ type ServerConfig struct {
 	TrustedProxies     []string  `mapstructure:"trusted_proxies"`       // 可信代理列表（CIDR/IP）
 	MaxRequestBodySize int64     `mapstructure:"max_request_body_size"` // 全局最大请求体限制
 	H2C                H2CConfig `mapstructure:"h2c"`                   // HTTP/2 Cleartext 配置
+	TLS                TLSConfig `mapstructure:"tls"`                   // 内置 TLS 终止配置
+	// UnixSocket 监听的 Unix domain socket 路径；设置后不再监听 TCP host:port，适合不对外暴露网络的 sidecar 部署
+	UnixSocket string `mapstructure:"unix_socket"`
+	// UnixSocketMode Unix socket 文件权限（八进制，如 "0660"），用于限制可访问网关的本机用户/组
+	UnixSocketMode string `mapstructure:"unix_socket_mode"`
+	// SystemdSocketActivation 使用 systemd 传入的监听 socket（LISTEN_FDS），优先于 unix_socket 与 TCP
+	SystemdSocketActivation bool `mapstructure:"systemd_socket_activation"`
+	// ShutdownDrainSeconds 收到 SIGTERM 后等待在途请求（含流式响应）完成的最长时间（秒），超时后强制断开
+	ShutdownDrainSeconds int `mapstructure:"shutdown_drain_seconds"`
+	// ShutdownUnreadySeconds 收到 SIGTERM 后先让 /readyz 返回 503 并照常服务的秒数，
+	// 给负载均衡摘除实例留出时间，之后才开始拒绝新请求；0 表示立即进入排空
+	ShutdownUnreadySeconds int `mapstructure:"shutdown_unready_seconds"`
 }
 
 // H2CConfig HTTP/2 Cleartext 配置
@@ -238,9 +349,42 @@ type H2CConfig struct {
 	MaxUploadBufferPerStream     int    `mapstructure:"max_upload_buffer_per_stream"`     // 每个流的上传缓冲区（字节）
 }
 
+// TLSConfig 内置 TLS 终止配置。证书来源二选一：
+// 证书文件（文件变更后自动热加载，适合外部续期工具），或 ACME 自动签发与续期（Let's Encrypt）。
+type TLSConfig struct {
+	Enabled  bool   `mapstructure:"enabled"`
+	CertFile string `mapstructure:"cert_file"` // PEM 证书链文件
+	KeyFile  string `mapstructure:"key_file"`  // PEM 私钥文件
+	// ReloadCheckSeconds 证书文件变更检查间隔（秒），在 TLS 握手时按间隔检查修改时间
+	ReloadCheckSeconds int        `mapstructure:"reload_check_seconds"`
+	ACME               ACMEConfig `mapstructure:"acme"`
+}
+
+// ACMEConfig ACME 自动证书配置，同时支持 HTTP-01 与 TLS-ALPN-01 质询
+type ACMEConfig struct {
+	Enabled bool     `mapstructure:"enabled"`
+	Domains []string `mapstructure:"domains"` // 允许签发证书的域名白名单
+	Email   string   `mapstructure:"email"`   // 证书到期与账号通知邮箱
+	// CacheDir 证书与 ACME 账号密钥的缓存目录，多实例部署需各自独立或共享持久卷
+	CacheDir string `mapstructure:"cache_dir"`
+	// DirectoryURL ACME 目录地址，留空使用 Let's Encrypt 生产环境；测试时可指向 staging
+	DirectoryURL string `mapstructure:"directory_url"`
+	// HTTPChallengeAddr HTTP-01 质询监听地址（通常为 :80），其余 HTTP 请求重定向到 HTTPS；
+	// 留空则不监听 HTTP，仅使用 TLS-ALPN-01 质询（要求服务端口对外为 443）
+	HTTPChallengeAddr string `mapstructure:"http_challenge_addr"`
+}
+
 type CORSConfig struct {
+	// AllowedOrigins: 允许的来源，"*" 表示任意来源，"https://*.example.com" 匹配其任意子域名
 	AllowedOrigins   []string `mapstructure:"allowed_origins"`
 	AllowCredentials bool     `mapstructure:"allow_credentials"`
+	// AllowedHeaders: 在内置列表（含 anthropic-version、x-goog-api-key 等网关请求头）之外额外允许的请求头；
+	// "*" 表示放行预检请求声明的任意请求头
+	AllowedHeaders []string `mapstructure:"allowed_headers"`
+	// ExposedHeaders: 在内置列表（X-Request-ID、Retry-After 等）之外额外暴露给浏览器的响应头
+	ExposedHeaders []string `mapstructure:"exposed_headers"`
+	// MaxAgeSeconds: 预检结果缓存时间（秒）
+	MaxAgeSeconds int `mapstructure:"max_age_seconds"`
 }
 
 type SecurityConfig struct {
@@ -356,6 +500,39 @@ type SoraStorageCleanupConfig struct {
 	RetentionDays int    `mapstructure:"retention_days"`
 }
 
+// GatewayRouteTimeoutConfig 单个路由前缀的超时与刷新配置
+type GatewayRouteTimeoutConfig struct {
+	// TTFBTimeoutSeconds: 客户端收到首个字节前的最长等待（秒），0表示不限制
+	// 非流式请求的上游响应头仍受 response_header_timeout 约束
+	TTFBTimeoutSeconds int `mapstructure:"ttfb_timeout_seconds"`
+	// TotalTimeoutSeconds: 请求（含流式输出）总时长上限（秒），0表示不限制
+	TotalTimeoutSeconds int `mapstructure:"total_timeout_seconds"`
+	// FlushIntervalMs: 合并刷新间隔（毫秒），0表示每次写出后立即刷新
+	FlushIntervalMs int `mapstructure:"flush_interval_ms"`
+	// WriteBufferBytes: 写出缓冲大小（字节），0表示不缓冲
+	WriteBufferBytes int `mapstructure:"write_buffer_bytes"`
+}
+
+// GatewayCompressionConfig 响应压缩配置（按客户端 Accept-Encoding 协商 br / gzip）
+type GatewayCompressionConfig struct {
+	// Enabled: 压缩非流式 JSON 响应
+	Enabled bool `mapstructure:"enabled"`
+	// Stream: 同时压缩 SSE 流，每次刷新时同步刷出已压缩数据（部分客户端 / 代理不支持压缩的事件流，默认关闭）
+	Stream bool `mapstructure:"stream"`
+	// MinBytes: 小于该字节数的非流式响应不压缩
+	MinBytes int `mapstructure:"min_bytes"`
+	// Algorithms: 按优先级排列的压缩算法（br、gzip）
+	Algorithms []string `mapstructure:"algorithms"`
+	// Routes: 按请求路径前缀覆盖 enabled / stream，最长前缀优先
+	Routes map[string]GatewayCompressionRouteConfig `mapstructure:"routes"`
+}
+
+// GatewayCompressionRouteConfig 单个路由前缀的压缩开关
+type GatewayCompressionRouteConfig struct {
+	Enabled bool `mapstructure:"enabled"`
+	Stream  bool `mapstructure:"stream"`
+}
+
 // GatewayConfig API网关相关配置
 type GatewayConfig struct {
 	// 等待上游响应头的超时时间（秒），0表示无超时
@@ -363,22 +540,60 @@ type GatewayConfig struct {
 	ResponseHeaderTimeout int `mapstructure:"response_header_timeout"`
 	// 请求体最大字节数，用于网关请求体大小限制
 	MaxBodySize int64 `mapstructure:"max_body_size"`
+	// AudioMaxBodySize: /v1/audio/* 请求体最大字节数（0 表示使用 max_body_size）
+	AudioMaxBodySize int64 `mapstructure:"audio_max_body_size"`
+	// MultipartMaxMemory: multipart 表单（/v1/files 等文件上传）在内存中缓冲的最大字节数，超出部分写入临时文件
+	MultipartMaxMemory int64 `mapstructure:"multipart_max_memory"`
 	// 非流式上游响应体读取上限（字节），用于防止无界读取导致内存放大
 	UpstreamResponseReadMaxBytes int64 `mapstructure:"upstream_response_read_max_bytes"`
 	// 代理探测响应体读取上限（字节）
 	ProxyProbeResponseReadMaxBytes int64 `mapstructure:"proxy_probe_response_read_max_bytes"`
 	// Gemini 上游响应头调试日志开关（默认关闭，避免高频日志开销）
 	GeminiDebugResponseHeaders bool `mapstructure:"gemini_debug_response_headers"`
+	// UpstreamRequestIDHeader: 转发上游时携带网关请求 ID 的请求头（默认 X-Request-Id，留空表示不携带）
+	UpstreamRequestIDHeader string `mapstructure:"upstream_request_id_header"`
 	// ConnectionPoolIsolation: 上游连接池隔离策略（proxy/account/account_proxy）
 	ConnectionPoolIsolation string `mapstructure:"connection_pool_isolation"`
 	// ForceCodexCLI: 强制将 OpenAI `/v1/responses` 请求按 Codex CLI 处理。
 	// 用于网关未透传/改写 User-Agent 时的兼容兜底（默认关闭，避免影响其他客户端）。
 	ForceCodexCLI bool `mapstructure:"force_codex_cli"`
+	// CodexCLIUserAgentPrefixes: 额外识别为 Codex CLI 的 User-Agent 前缀（如内部 fork 或新版客户端）。
+	// 环境变量使用逗号分隔，例如 GATEWAY_CODEX_CLI_USER_AGENT_PREFIXES="acme_codex/,codex_next/"。
+	// 修改配置文件后自动热更新，无需重启。
+	CodexCLIUserAgentPrefixes []string `mapstructure:"codex_cli_user_agent_prefixes"`
+	// CodexCLIUserAgentPrefixesOverride: 为 true 时以 CodexCLIUserAgentPrefixes 完全替换内置前缀列表（默认追加）。
+	CodexCLIUserAgentPrefixesOverride bool `mapstructure:"codex_cli_user_agent_prefixes_override"`
+	// UpstreamUserAgent: OpenAI 上游 User-Agent 改写（按模板统一为 Codex CLI UA）
+	UpstreamUserAgent GatewayUpstreamUserAgentConfig `mapstructure:"upstream_user_agent"`
+	// CodexInstructions: 发往 OpenAI OAuth（ChatGPT Codex）上游的 instructions 处理
+	CodexInstructions GatewayCodexInstructionsConfig `mapstructure:"codex_instructions"`
+	// ReasoningDefaults: 按模型 / 客户端 / API Key 设置推理强度默认值或强制值（优先级见 GatewayReasoningDefaultRule）
+	ReasoningDefaults []GatewayReasoningDefaultRule `mapstructure:"reasoning_defaults"`
+	// SystemPrompts: 按路由（入口路径）/ 模型注入系统提示词，在 API Key 级系统提示词之后应用
+	SystemPrompts []GatewaySystemPromptRule `mapstructure:"system_prompts"`
+	// ParamSanitize: 按模型清理请求参数（钳制最大输出 token 数、移除上游不支持的参数、修正已知客户端兼容问题）
+	ParamSanitize GatewayParamSanitizeConfig `mapstructure:"param_sanitize"`
+	// ContextOverflow: 请求超出模型上下文窗口时的处理策略（拒绝 / 丢弃最早的轮次 / 压缩摘要）
+	ContextOverflow GatewayContextOverflowConfig `mapstructure:"context_overflow"`
+	// ChatMultiChoice: Chat Completions 的 n > 1 模拟（并行发起 n 个上游请求并合并为多个 choices）
+	ChatMultiChoice GatewayChatMultiChoiceConfig `mapstructure:"chat_multi_choice"`
+	// OpenAIPlanRestrictions: 按 ChatGPT 订阅计划限制 OAuth 账号可承接的模型 / 推理强度（在内置限制之外追加）
+	OpenAIPlanRestrictions []GatewayOpenAIPlanRestriction `mapstructure:"openai_plan_restrictions"`
+	// OpenAISessionHeaders: 按账号派生并定期轮换上游 session_id / conversation_id，避免故障转移时把同一会话标识带到其他账号
+	OpenAISessionHeaders GatewayOpenAISessionHeadersConfig `mapstructure:"openai_session_headers"`
+	// UpstreamRetry: 上游请求的统一重试（连接重置、502/503、可选的短 Retry-After 429），带指数退避、抖动与全局重试预算
+	UpstreamRetry GatewayUpstreamRetryConfig `mapstructure:"upstream_retry"`
+	// AccountCircuitBreaker: 按错误率与慢调用率为每个上游账号熔断（closed / open / half-open）
+	AccountCircuitBreaker GatewayAccountCircuitBreakerConfig `mapstructure:"account_circuit_breaker"`
+	// PriorityQueue: 分组级有界请求队列（按 Key 标签划分优先级），队列满或等待超时返回 429 + Retry-After
+	PriorityQueue GatewayPriorityQueueConfig `mapstructure:"priority_queue"`
 	// OpenAIPassthroughAllowTimeoutHeaders: OpenAI 透传模式是否放行客户端超时头
 	// 关闭（默认）可避免 x-stainless-timeout 等头导致上游提前断流。
 	OpenAIPassthroughAllowTimeoutHeaders bool `mapstructure:"openai_passthrough_allow_timeout_headers"`
 	// OpenAIWS: OpenAI Responses WebSocket 配置（默认开启，可按需回滚到 HTTP）
 	OpenAIWS GatewayOpenAIWSConfig `mapstructure:"openai_ws"`
+	// OpenAIUsageWindow: Codex 5 小时 / 7 天用量窗口的本地跟踪与预测
+	OpenAIUsageWindow GatewayOpenAIUsageWindowConfig `mapstructure:"openai_usage_window"`
 
 	// HTTP 上游连接池配置（性能优化：支持高并发场景调优）
 	// MaxIdleConns: 所有主机的最大空闲连接总数
@@ -398,6 +613,14 @@ type GatewayConfig struct {
 	// 超过此时间未使用的客户端会被标记为可回收
 	// 建议值：根据用户访问频率设置，一般 10-30 分钟
 	ClientIdleTTLSeconds int `mapstructure:"client_idle_ttl_seconds"`
+	// DialTimeoutSeconds: 上游 TCP 建连超时（秒）
+	DialTimeoutSeconds int `mapstructure:"dial_timeout_seconds"`
+	// TLSHandshakeTimeoutSeconds: 上游 TLS 握手超时（秒，TLS 指纹连接不适用）
+	TLSHandshakeTimeoutSeconds int `mapstructure:"tls_handshake_timeout_seconds"`
+	// TLSSessionCacheSize: 每个上游客户端的 TLS 会话缓存容量，用于新建连接时恢复会话、省去完整握手；0 表示关闭
+	TLSSessionCacheSize int `mapstructure:"tls_session_cache_size"`
+	// DisableUpstreamHTTP2: 关闭上游 HTTP/2（默认开启，经 SOCKS5 代理时同样尝试 HTTP/2）
+	DisableUpstreamHTTP2 bool `mapstructure:"disable_upstream_http2"`
 	// ConcurrencySlotTTLMinutes: 并发槽位过期时间（分钟）
 	// 应大于最长 LLM 请求时间，防止请求完成前槽位过期
 	ConcurrencySlotTTLMinutes int `mapstructure:"concurrency_slot_ttl_minutes"`
@@ -409,7 +632,19 @@ type GatewayConfig struct {
 	// StreamDataIntervalTimeout: 流数据间隔超时（秒），0表示禁用
 	StreamDataIntervalTimeout int `mapstructure:"stream_data_interval_timeout"`
 	// StreamKeepaliveInterval: 流式 keepalive 间隔（秒），0表示禁用
+	// 下游连续该时长无输出时发送心跳（含 Chat Completions/Gemini 等转换流），避免长时间推理被空闲超时断开
 	StreamKeepaliveInterval int `mapstructure:"stream_keepalive_interval"`
+	// StreamResumeMaxAttempts: 流式输出中途上游断开时换号续传的最大次数，0表示禁用（仅 Chat Completions 转换流）
+	StreamResumeMaxAttempts int `mapstructure:"stream_resume_max_attempts"`
+	// SessionAffinityHeader: 客户端显式指定会话亲和的请求头，同一 API Key 下取值相同的请求绑定到同一账号
+	// （优先于 session_id / metadata.user_id 等会话信号），空字符串表示禁用
+	SessionAffinityHeader string `mapstructure:"session_affinity_header"`
+	// StickySessionTTLSeconds: 粘性会话绑定 TTL（秒），命中时续期；OpenAI 平台使用 openai_ws.sticky_session_ttl_seconds
+	StickySessionTTLSeconds int `mapstructure:"sticky_session_ttl_seconds"`
+	// RouteTimeouts: 按请求路径前缀（POST）配置首字节超时、总超时与写出刷新策略，最长前缀优先
+	RouteTimeouts map[string]GatewayRouteTimeoutConfig `mapstructure:"route_timeouts"`
+	// Compression: 响应压缩（非流式 JSON 与可选的 SSE），可按路由前缀覆盖
+	Compression GatewayCompressionConfig `mapstructure:"compression"`
 	// MaxLineSize: 上游 SSE 单行最大字节数（0使用默认值）
 	MaxLineSize int `mapstructure:"max_line_size"`
 
@@ -424,6 +659,27 @@ type GatewayConfig struct {
 	// 是否允许对部分 400 错误触发 failover（默认关闭以避免改变语义）
 	FailoverOn400 bool `mapstructure:"failover_on_400"`
 
+	// StructuredOutputMaxRetries: 非流式结构化输出（json_schema / json_object）返回的 JSON
+	// 无效或不符合 schema 时的最大重试次数（0 表示不重试，仅记录日志）
+	StructuredOutputMaxRetries int `mapstructure:"structured_output_max_retries"`
+
+	// AzureDeploymentModels: Azure OpenAI 风格路由（/openai/deployments/{deployment}/...）的
+	// deployment 名 → 模型名映射（键大小写不敏感）；未配置的 deployment 直接作为模型名使用
+	AzureDeploymentModels map[string]string `mapstructure:"azure_deployment_models"`
+
+	// ImageDownscale: 协议转换路径上对内联图片进行服务端缩放/重压缩，降低上游图片 token 开销
+	ImageDownscale GatewayImageDownscaleConfig `mapstructure:"image_downscale"`
+
+	// Moderation: /v1/moderations 服务方式与推理请求的本地审核前置过滤
+	Moderation GatewayModerationConfig `mapstructure:"moderation"`
+
+	// Hooks: 请求扩展点（pre_auth / pre_translation / pre_upstream / post_response），
+	// 由 Go 插件、Webhook 或外部脚本检查并改写请求，无需修改网关代码即可接入自定义策略
+	Hooks GatewayHooksConfig `mapstructure:"hooks"`
+
+	// Simulation: 演练模式，请求由内置模拟上游应答（不消耗真实账号额度），用于压测路由、配额与仪表盘
+	Simulation GatewaySimulationConfig `mapstructure:"simulation"`
+
 	// Sora 专用配置
 	// SoraMaxBodySize: Sora 请求体最大字节数（0 表示使用 gateway.max_body_size）
 	SoraMaxBodySize int64 `mapstructure:"sora_max_body_size"`
@@ -469,6 +725,118 @@ type GatewayConfig struct {
 	UserMessageQueue UserMessageQueueConfig `mapstructure:"user_message_queue"`
 }
 
+// GatewayImageDownscaleConfig 内联图片缩放配置
+// 仅作用于经协议转换（Chat Completions / Responses / Anthropic / Gemini 互转）的请求
+type GatewayImageDownscaleConfig struct {
+	// Enabled: 是否启用（默认关闭）
+	Enabled bool `mapstructure:"enabled"`
+	// MaxDimension: 长边最大像素，超过时等比缩小
+	MaxDimension int `mapstructure:"max_dimension"`
+	// JPEGQuality: 不透明图片重压缩为 JPEG 的质量（1-100）
+	JPEGQuality int `mapstructure:"jpeg_quality"`
+	// MinBytes: 小于该字节数且尺寸未超限的图片不处理
+	MinBytes int `mapstructure:"min_bytes"`
+}
+
+// Moderation 模式
+const (
+	// ModerationModeUpstream: /v1/moderations 透传到 OpenAI 分组的 API Key 账号
+	ModerationModeUpstream = "upstream"
+	// ModerationModeLocal: /v1/moderations 由网关本地规则分类器直接应答（所有分组可用）
+	ModerationModeLocal = "local"
+)
+
+// Moderation 前置过滤的审核来源
+const (
+	// ModerationSourceLocal: 使用本地规则分类器（gateway.moderation.categories）
+	ModerationSourceLocal = "local"
+	// ModerationSourceEndpoint: 调用 OpenAI 兼容的 /v1/moderations 接口（gateway.moderation.endpoint）
+	ModerationSourceEndpoint = "endpoint"
+)
+
+// GatewayModerationConfig 内容审核配置
+type GatewayModerationConfig struct {
+	// Mode: /v1/moderations 服务方式（upstream/local，默认 upstream）
+	Mode string `mapstructure:"mode"`
+	// PreFilter: 是否在推理请求转发前审核提示词，命中时直接拒绝；
+	// 这是未单独设置审核方式（API Key 的 moderation_mode）的 Key 的默认行为
+	PreFilter bool `mapstructure:"pre_filter"`
+	// PreFilterSource: 前置过滤的审核来源（local/endpoint，默认 local）
+	PreFilterSource string `mapstructure:"pre_filter_source"`
+	// Endpoint: pre_filter_source 为 endpoint 时调用的审核接口
+	Endpoint GatewayModerationEndpointConfig `mapstructure:"endpoint"`
+	// BlockCategories: 命中后拒绝请求的类别（为空表示除 flag_categories 外的所有类别；"violence" 同时匹配 "violence/graphic"）
+	BlockCategories []string `mapstructure:"block_categories"`
+	// FlagCategories: 命中后仅标记（记录日志并在响应头 X-Sub2API-Moderation-Flagged 标注）不拒绝的类别
+	FlagCategories []string `mapstructure:"flag_categories"`
+	// Categories: 本地分类器规则，类别名 → 正则列表（大小写不敏感，任一命中即标记该类别）
+	Categories map[string][]string `mapstructure:"categories"`
+}
+
+// GatewayModerationEndpointConfig OpenAI 兼容的审核接口配置
+type GatewayModerationEndpointConfig struct {
+	// URL: 审核接口地址（如 https://api.openai.com/v1/moderations）
+	URL string `mapstructure:"url"`
+	// APIKey: 以 Bearer 方式携带的接口密钥
+	APIKey string `mapstructure:"api_key"`
+	// Model: 审核模型（默认 omni-moderation-latest）
+	Model string `mapstructure:"model"`
+	// TimeoutSeconds: 单次审核超时（秒）
+	TimeoutSeconds int `mapstructure:"timeout_seconds"`
+	// FailOpen: 审核接口不可用时是否放行请求（默认 true；false 时返回 503）
+	FailOpen bool `mapstructure:"fail_open"`
+}
+
+// Hook 处理器类型
+const (
+	// GatewayHookTypeGo: 通过 hooks.Register 注册的 Go 扩展（可选从 plugin_path 加载 Go 插件）
+	GatewayHookTypeGo = "go"
+	// GatewayHookTypeWebhook: POST JSON 请求描述到 url，响应体为处理决定
+	GatewayHookTypeWebhook = "webhook"
+	// GatewayHookTypeScript: 执行 command，标准输入为 JSON 请求描述，标准输出为处理决定
+	GatewayHookTypeScript = "script"
+)
+
+// GatewayHooksConfig 请求扩展点配置
+type GatewayHooksConfig struct {
+	// Enabled: 是否启用扩展点（默认关闭）
+	Enabled bool `mapstructure:"enabled"`
+	// Handlers: 处理器列表，同一阶段内按配置顺序依次执行，前一个处理器的改写对后一个可见
+	Handlers []GatewayHookHandlerConfig `mapstructure:"handlers"`
+}
+
+// GatewayHookHandlerConfig 单个扩展处理器
+type GatewayHookHandlerConfig struct {
+	// Name: 处理器名称（日志与错误信息中使用，需唯一）
+	Name string `mapstructure:"name"`
+	// Type: go / webhook / script
+	Type string `mapstructure:"type"`
+	// Stages: 生效阶段 pre_auth / pre_translation / pre_upstream / post_response
+	Stages []string `mapstructure:"stages"`
+	// Paths: 仅对这些入站路径前缀生效，为空表示所有网关路径
+	Paths []string `mapstructure:"paths"`
+	// TimeoutMS: 单次调用超时（毫秒）
+	TimeoutMS int `mapstructure:"timeout_ms"`
+	// FailOpen: 处理器出错或超时时放行请求（默认 false：以 503 拒绝）
+	FailOpen bool `mapstructure:"fail_open"`
+
+	// Plugin: type=go 时使用的已注册扩展名
+	Plugin string `mapstructure:"plugin"`
+	// PluginPath: type=go 时可选的 Go 插件（.so）路径，加载时由插件 init 注册扩展（需 CGO 构建）
+	PluginPath string `mapstructure:"plugin_path"`
+	// Options: 传给 Go 扩展工厂函数的参数
+	Options map[string]any `mapstructure:"options"`
+
+	// URL: type=webhook 时的地址
+	URL string `mapstructure:"url"`
+	// Headers: type=webhook 时附加的请求头（如鉴权）
+	Headers map[string]string `mapstructure:"headers"`
+
+	// Command / Args: type=script 时执行的命令与参数
+	Command string   `mapstructure:"command"`
+	Args    []string `mapstructure:"args"`
+}
+
 // UserMessageQueueConfig 用户消息串行队列配置
 // 用于 Anthropic OAuth/SetupToken 账号的用户消息串行化发送
 type UserMessageQueueConfig struct {
@@ -511,6 +879,253 @@ func (c *UserMessageQueueConfig) GetEffectiveMode() string {
 	return ""
 }
 
+// GatewayCodexInstructionsConfig OpenAI OAuth（ChatGPT Codex）上游的 instructions 处理。
+// Codex CLI 自带的 instructions 原样保留在最前；Default 仅作用于非 Codex 客户端，Append 对所有请求生效。
+type GatewayCodexInstructionsConfig struct {
+	// Default: 非 Codex 客户端使用的 instructions；设置后客户端自己的 instructions 改为 input 开头的 developer 消息。
+	// 留空时保留客户端 instructions，缺失时填充内置的通用提示词（上游要求 instructions 必填）。
+	Default string `mapstructure:"default"`
+	// Append: 运营方追加的说明，以空行分隔附加在 instructions 之后（已包含时不重复追加）
+	Append string `mapstructure:"append"`
+}
+
+// GatewayUpstreamUserAgentConfig OpenAI 上游 User-Agent 改写配置。
+//
+// 模板支持占位符：
+//   - {version}：Version 配置的版本号
+//   - {client_version}：下游客户端版本（无法识别时回退为 Version）
+//
+// 账号凭证中的 user_agent 同样支持上述占位符，且优先于全局模板。
+type GatewayUpstreamUserAgentConfig struct {
+	// Enabled: 为 true 时所有未配置自定义 UA 的账号统一改写为模板 UA（默认关闭，仅用于 ForceCodexCLI/OAuth 兜底）
+	Enabled bool `mapstructure:"enabled"`
+	// Template: UA 模板，例如 "codex_cli_rs/{version}"
+	Template string `mapstructure:"template"`
+	// Version: 填充 {version} 的版本号
+	Version string `mapstructure:"version"`
+	// AutoUpdate: 定期从 GitHub 获取 Codex CLI 最新发布版本，高于 Version 时改用最新版本
+	// （上游会降低声明过旧客户端版本的请求的优先级）
+	AutoUpdate bool `mapstructure:"auto_update"`
+	// AutoUpdateIntervalMinutes: 获取最新版本的间隔（分钟）
+	AutoUpdateIntervalMinutes int `mapstructure:"auto_update_interval_minutes"`
+	// ReleaseRepo: Codex CLI 的 GitHub 仓库（owner/name）
+	ReleaseRepo string `mapstructure:"release_repo"`
+}
+
+// GatewaySimulationConfig 演练模式配置。
+// 演练请求照常经过认证、路由、账号调度、配额与计费，只在发往上游时由内置模拟上游按目标平台格式
+// （Anthropic Messages、OpenAI Responses / Chat Completions、Gemini generateContent）返回固定文本或回显内容，
+// 流式响应按配置的延迟分块输出，usage 按实际文本估算，因此用量记录与仪表盘数据与真实请求一致。
+// Enabled 作用于全部网关请求；单个 Key 可由管理员开启 simulate 单独演练，不受 Enabled 影响。
+type GatewaySimulationConfig struct {
+	// Enabled: 全局演练（默认关闭），开启后所有网关请求都不会到达真实账号
+	Enabled bool `mapstructure:"enabled"`
+	// ResponseMode: 响应内容，canned 返回 CannedText，echo 回显最后一条用户消息
+	ResponseMode string `mapstructure:"response_mode"`
+	// CannedText: canned 模式的响应文本
+	CannedText string `mapstructure:"canned_text"`
+	// OutputTokens: 目标输出 token 数，响应文本重复至该长度（受请求 max_tokens 限制）；0 表示按原文本输出
+	OutputTokens int `mapstructure:"output_tokens"`
+	// FirstTokenLatencyMS: 模拟首 token 延迟（毫秒），非流式请求同样等待
+	FirstTokenLatencyMS int `mapstructure:"first_token_latency_ms"`
+	// ChunkIntervalMS: 流式响应相邻分块的间隔（毫秒）
+	ChunkIntervalMS int `mapstructure:"chunk_interval_ms"`
+}
+
+// GatewayUpstreamRetryConfig 上游请求统一重试配置。
+// 重试发生在上游响应返回给网关服务之前，因此不会在已向客户端输出内容后重试；
+// 每次重试都会重新发送完整请求体，并消耗全局重试预算（按请求量的 BudgetRatio 累积，另有每秒保底额度），
+// 预算耗尽时直接返回本次结果，避免上游故障时重试放大流量。
+type GatewayUpstreamRetryConfig struct {
+	// Enabled: 是否启用（默认关闭，账号切换与各平台自身的重试逻辑不受影响）
+	Enabled bool `mapstructure:"enabled"`
+	// MaxAttempts: 单次上游调用的最大尝试次数（含首次请求）
+	MaxAttempts int `mapstructure:"max_attempts"`
+	// BaseDelayMS / MaxDelayMS: 指数退避的初始与最大等待（毫秒），实际等待在 [delay/2, delay] 内随机抖动
+	BaseDelayMS int `mapstructure:"base_delay_ms"`
+	MaxDelayMS  int `mapstructure:"max_delay_ms"`
+	// RetryStatusCodes: 触发重试的上游状态码（默认 502、503）
+	RetryStatusCodes []int `mapstructure:"retry_status_codes"`
+	// Retry429MaxRetryAfterSeconds: 上游 429 携带的 Retry-After 不超过该秒数时按 Retry-After 等待后重试；0 表示不重试 429
+	Retry429MaxRetryAfterSeconds int `mapstructure:"retry_429_max_retry_after_seconds"`
+	// BudgetRatio: 每个上游请求为重试预算累积的额度（0.1 表示重试量最多约为请求量的 10%）
+	BudgetRatio float64 `mapstructure:"budget_ratio"`
+	// BudgetMinPerSecond: 每秒保底的重试额度（低流量时也能重试）
+	BudgetMinPerSecond int `mapstructure:"budget_min_per_second"`
+}
+
+// GatewayAccountCircuitBreakerConfig 账号级熔断配置（进程内，按实例独立统计）。
+// 统计窗口内的上游调用（连接失败或 5xx 记为失败，响应头耗时超过 SlowCallMS 记为慢调用），
+// 请求数达到 MinRequests 且失败率或慢调用率超过阈值时熔断：账号在 OpenSeconds 内不参与调度，
+// 随后进入 half-open 重新接收流量，连续 HalfOpenSuccesses 次正常调用后恢复，期间任一失败立即再次熔断。
+// 与账号健康检查互补：熔断在健康检查的探测间隔内即可生效，且只影响本实例的调度。
+type GatewayAccountCircuitBreakerConfig struct {
+	// Enabled: 是否启用（默认关闭）
+	Enabled bool `mapstructure:"enabled"`
+	// WindowSeconds: 统计窗口（秒）
+	WindowSeconds int `mapstructure:"window_seconds"`
+	// MinRequests: 窗口内触发熔断判定所需的最少调用数
+	MinRequests int `mapstructure:"min_requests"`
+	// ErrorRateThreshold: 失败率阈值（0-1）
+	ErrorRateThreshold float64 `mapstructure:"error_rate_threshold"`
+	// SlowCallMS: 慢调用阈值（毫秒，按上游响应头耗时）；0 表示不按延迟熔断
+	SlowCallMS int `mapstructure:"slow_call_ms"`
+	// SlowCallRateThreshold: 慢调用率阈值（0-1）
+	SlowCallRateThreshold float64 `mapstructure:"slow_call_rate_threshold"`
+	// OpenSeconds: 熔断持续时间（秒），到期后进入 half-open
+	OpenSeconds int `mapstructure:"open_seconds"`
+	// HalfOpenSuccesses: half-open 状态下恢复所需的连续正常调用数
+	HalfOpenSuccesses int `mapstructure:"half_open_successes"`
+}
+
+// GatewayPriorityQueueConfig 分组级优先级请求队列（进程内，按实例独立）。
+// 每个分组同时处理的推理请求不超过 MaxInFlightPerGroup，超出的请求按优先级排队（同优先级先到先得），
+// 队列已满或等待超过 MaxWaitSeconds 时直接返回 429 并附 Retry-After，而不是继续堆积到已满载的账号上。
+type GatewayPriorityQueueConfig struct {
+	// Enabled: 是否启用（默认关闭）
+	Enabled bool `mapstructure:"enabled"`
+	// MaxInFlightPerGroup: 每个分组同时处理的请求上限
+	MaxInFlightPerGroup int `mapstructure:"max_in_flight_per_group"`
+	// MaxQueuePerGroup: 每个分组的排队上限；0 表示不排队，达到并发上限即返回 429
+	MaxQueuePerGroup int `mapstructure:"max_queue_per_group"`
+	// MaxWaitSeconds: 排队最长等待时间（秒）
+	MaxWaitSeconds int `mapstructure:"max_wait_seconds"`
+	// RetryAfterSeconds: 429 响应的 Retry-After（秒）
+	RetryAfterSeconds int `mapstructure:"retry_after_seconds"`
+	// Classes: 优先级类别，Key 命中多个类别时取最高优先级；未命中任何类别的 Key 优先级为 0
+	Classes []GatewayPriorityClass `mapstructure:"classes"`
+}
+
+// GatewayPriorityClass 优先级类别：带任一 KeyTags 标签的 API Key 按 Priority 排队（越大越优先）
+type GatewayPriorityClass struct {
+	Name     string   `mapstructure:"name"`
+	KeyTags  []string `mapstructure:"key_tags"`
+	Priority int      `mapstructure:"priority"`
+}
+
+// GatewayReasoningDefaultRule 推理强度默认值规则，作用于 Messages / Responses / Chat Completions 入口。
+// 推理强度统一使用 Responses 取值（low / medium / high / xhigh），按入口格式写入：
+// reasoning.effort、reasoning_effort，或 Anthropic 的 thinking.budget_tokens（及已有的 output_config.effort）。
+//
+// 优先级（从高到低）：
+//  1. 首条命中的 force 规则：覆盖客户端显式设置的推理强度
+//  2. 客户端显式设置（reasoning.effort / reasoning_effort / thinking / output_config.effort）
+//  3. 首条命中的非 force 规则：仅在客户端未设置时补充
+//  4. 上游模型默认值
+type GatewayReasoningDefaultRule struct {
+	// Models: 请求模型（模型别名与路由改写之后，支持末尾 * 通配符），为空表示所有模型
+	Models []string `mapstructure:"models"`
+	// Clients: 客户端类型 codex / claude_code / coding_agent / api（同 API Key 客户端限制），为空表示所有客户端
+	Clients []string `mapstructure:"clients"`
+	// APIKeyIDs: 仅作用于这些 API Key，为空表示所有 Key
+	APIKeyIDs []int64 `mapstructure:"api_key_ids"`
+	// Effort: 推理强度 low / medium / high / xhigh
+	Effort string `mapstructure:"effort"`
+	// Force: 为 true 时覆盖客户端显式设置的推理强度
+	Force bool `mapstructure:"force"`
+}
+
+// GatewaySystemPromptRule 按路由注入的系统提示词规则，按顺序匹配，首条命中的规则生效
+type GatewaySystemPromptRule struct {
+	// Paths: 入口请求路径前缀（如 /v1/messages、/v1beta/），为空表示所有推理端点
+	Paths []string `mapstructure:"paths"`
+	// Models: 请求模型（模型别名与路由改写之后，支持末尾 * 通配符），为空表示所有模型
+	Models []string `mapstructure:"models"`
+	// Mode: prepend（置于客户端系统提示词之前，默认）/ append（之后）/ replace（替换）
+	Mode string `mapstructure:"mode"`
+	// Prompt: 注入的系统提示词
+	Prompt string `mapstructure:"prompt"`
+}
+
+// GatewayParamSanitizeConfig 请求参数清理配置
+type GatewayParamSanitizeConfig struct {
+	// Enabled: 是否启用（默认 true）
+	Enabled bool `mapstructure:"enabled"`
+	// UsePricingLimits: 命中的规则未设置 max_output_tokens 时，按价格数据（LiteLLM）中模型的 max_output_tokens 钳制（默认 true）
+	UsePricingLimits bool `mapstructure:"use_pricing_limits"`
+	// Rules: 按模型覆盖，按顺序匹配，首条命中的规则生效
+	Rules []GatewayParamSanitizeRule `mapstructure:"rules"`
+}
+
+// GatewayParamSanitizeRule 按模型的参数清理规则
+type GatewayParamSanitizeRule struct {
+	// Models: 请求模型（模型别名与路由改写之后，支持末尾 * 通配符），为空表示所有模型
+	Models []string `mapstructure:"models"`
+	// MaxOutputTokens: 最大输出 token 数上限（max_tokens / max_completion_tokens / max_output_tokens），0 表示不设置
+	MaxOutputTokens int `mapstructure:"max_output_tokens"`
+	// DropParams: 转发前移除的请求体字段（支持 a.b 形式的嵌套路径）
+	DropParams []string `mapstructure:"drop_params"`
+}
+
+// 上下文窗口超限处理策略
+const (
+	ContextOverflowStrategyOff      = "off"      // 不检查，按原样转发
+	ContextOverflowStrategyReject   = "reject"   // 直接返回 400，说明估算的输入 token 数与模型上限
+	ContextOverflowStrategyTruncate = "truncate" // 丢弃最早的对话轮次直至放得下
+	ContextOverflowStrategyCompact  = "compact"  // 将最早的轮次交由模型摘要后替换，失败时退化为 truncate
+)
+
+// GatewayContextOverflowConfig 请求超出模型上下文窗口时的处理配置。
+// 输入 token 数按本地 tokenizer 估算；可用预算为上下文窗口减去请求的最大输出 token 数，再留出安全余量。
+type GatewayContextOverflowConfig struct {
+	// Strategy: 默认策略（off / reject / truncate / compact，默认 off）
+	Strategy string `mapstructure:"strategy"`
+	// UsePricingLimits: 规则未设置 context_window 时，按价格数据（LiteLLM）中模型的 max_input_tokens 作为上下文窗口（默认 true）
+	UsePricingLimits bool `mapstructure:"use_pricing_limits"`
+	// SafetyMarginPercent: 本地估算的安全余量（百分比，0-50），预算按窗口的 (100 - margin)% 计算
+	SafetyMarginPercent int `mapstructure:"safety_margin_percent"`
+	// CompactModel: compact 策略生成摘要使用的模型，留空使用请求模型
+	CompactModel string `mapstructure:"compact_model"`
+	// CompactMaxTokens: 摘要的最大输出 token 数
+	CompactMaxTokens int `mapstructure:"compact_max_tokens"`
+	// Rules: 按模型覆盖上下文窗口与策略，按顺序匹配，首条命中的规则生效
+	Rules []GatewayContextOverflowRule `mapstructure:"rules"`
+}
+
+// GatewayContextOverflowRule 按模型的上下文窗口规则
+type GatewayContextOverflowRule struct {
+	// Models: 请求模型（模型别名与路由改写之后，支持末尾 * 通配符），为空表示所有模型
+	Models []string `mapstructure:"models"`
+	// ContextWindow: 上下文窗口（token），0 表示使用价格数据
+	ContextWindow int `mapstructure:"context_window"`
+	// Strategy: 覆盖默认策略，留空沿用默认策略
+	Strategy string `mapstructure:"strategy"`
+}
+
+// GatewayChatMultiChoiceConfig Chat Completions 的 n > 1 模拟配置。
+// 上游（Responses / Messages）每次只返回一个结果，n > 1 时并行发起 n 个 n=1 的子请求，
+// 子请求经由网关路由在进程内执行，与普通请求一样调度账号、占用账号并发槽位并分别计费。
+type GatewayChatMultiChoiceConfig struct {
+	// Enabled: 是否启用（默认 false，关闭时 n 按上游行为处理，通常只返回一个 choice）
+	Enabled bool `mapstructure:"enabled"`
+	// MaxN: 允许的最大 n，超出时返回 400（默认 8）
+	MaxN int `mapstructure:"max_n"`
+	// MaxParallel: 单个请求同时进行的子请求数上限（默认 4）
+	MaxParallel int `mapstructure:"max_parallel"`
+}
+
+// GatewayOpenAIPlanRestriction 订阅计划限制规则：命中的请求只调度到计划在 Plans 中的 OpenAI OAuth 账号。
+// 计划取自 id_token 的 chatgpt_plan_type，上游返回的计划（如 usage_limit_reached 错误体）优先；
+// API Key 账号不受限制。
+type GatewayOpenAIPlanRestriction struct {
+	// Models: 上游模型（账号模型映射之后，支持末尾 * 通配符）
+	Models []string `mapstructure:"models"`
+	// ReasoningEfforts: 仅限制这些推理强度（low / medium / high / xhigh），为空表示该模型的所有请求
+	ReasoningEfforts []string `mapstructure:"reasoning_efforts"`
+	// Plans: 允许的计划，如 plus / pro / team / business / enterprise / edu
+	Plans []string `mapstructure:"plans"`
+}
+
+// GatewayOpenAISessionHeadersConfig OpenAI 上游会话标识头（session_id / conversation_id）配置。
+// 启用后发往上游的值由「账号 + 客户端会话标识 + 轮换周期」派生：同一粘性会话在同一账号上保持稳定，
+// 切换到其他账号时得到不同的值，不同账号之间无法据此关联。
+type GatewayOpenAISessionHeadersConfig struct {
+	// Enabled: 是否按账号派生会话标识头（关闭时原样透传客户端的值）
+	Enabled bool `mapstructure:"enabled"`
+	// RotationHours: 轮换周期（小时），到期后同一会话换用新的标识；0 表示不轮换
+	RotationHours int `mapstructure:"rotation_hours"`
+}
+
 // GatewayOpenAIWSConfig OpenAI Responses WebSocket 配置。
 // 注意：默认全局开启；如需回滚可使用 force_http 或关闭 enabled。
 type GatewayOpenAIWSConfig struct {
@@ -604,6 +1219,7 @@ type GatewayOpenAIWSSchedulerScoreWeights struct {
 	Queue     float64 `mapstructure:"queue"`
 	ErrorRate float64 `mapstructure:"error_rate"`
 	TTFT      float64 `mapstructure:"ttft"`
+	Headroom  float64 `mapstructure:"headroom"`
 }
 
 // GatewayUsageRecordConfig 使用量记录异步队列配置
@@ -685,6 +1301,16 @@ type GatewaySchedulingConfig struct {
 	// 负载计算
 	LoadBatchEnabled bool `mapstructure:"load_batch_enabled"`
 
+	// 优先级溢出：最高优先级层的最低负载率达到该百分比时，下一优先级层也参与选择（0 表示仅满载或失败时才溢出）
+	PriorityOverflowLoadPercent int `mapstructure:"priority_overflow_load_percent"`
+	// 溢出恢复：最高优先级层的最低负载率降到该百分比以下时停止溢出（滞回，0 表示与溢出阈值相同）
+	PriorityRecoverLoadPercent int `mapstructure:"priority_recover_load_percent"`
+
+	// 延迟感知调度：同优先级、同负载率的候选账号按滚动 p50 延迟加权随机选择（越快的账号权重越高）
+	LatencyAwareEnabled bool `mapstructure:"latency_aware_enabled"`
+	// 参与延迟加权所需的最少样本数，样本不足的账号按中性权重参与
+	LatencyAwareMinSamples int `mapstructure:"latency_aware_min_samples"`
+
 	// 过期槽位清理周期（0 表示禁用）
 	SlotCleanupInterval time.Duration `mapstructure:"slot_cleanup_interval"`
 
@@ -716,6 +1342,19 @@ func (s *ServerConfig) Address() string {
 	return fmt.Sprintf("%s:%d", s.Host, s.Port)
 }
 
+// UnixSocketFileMode 解析 unix_socket_mode（八进制），留空时为 0660
+func (s *ServerConfig) UnixSocketFileMode() (os.FileMode, error) {
+	raw := strings.TrimSpace(s.UnixSocketMode)
+	if raw == "" {
+		return 0o660, nil
+	}
+	mode, err := strconv.ParseUint(raw, 8, 32)
+	if err != nil || mode > 0o777 {
+		return 0, fmt.Errorf("server.unix_socket_mode must be an octal permission such as 0660, got %q", s.UnixSocketMode)
+	}
+	return os.FileMode(mode), nil
+}
+
 // DatabaseConfig 数据库连接配置
 // 性能优化：新增连接池参数，避免频繁创建/销毁连接
 type DatabaseConfig struct {
@@ -874,6 +1513,83 @@ type DefaultConfig struct {
 type RateLimitConfig struct {
 	OverloadCooldownMinutes int `mapstructure:"overload_cooldown_minutes"`  // 529过载冷却时间(分钟)
 	OAuth401CooldownMinutes int `mapstructure:"oauth_401_cooldown_minutes"` // OAuth 401临时不可调度冷却(分钟)
+	// 429 无重置时间且无 Retry-After 时的冷却：首次 base 秒，连续触发翻倍，封顶 max 秒
+	CooldownBackoffBaseSeconds int `mapstructure:"cooldown_backoff_base_seconds"`
+	CooldownBackoffMaxSeconds  int `mapstructure:"cooldown_backoff_max_seconds"`
+	// 进程内保留的 429 冷却历史条数
+	CooldownHistorySize int `mapstructure:"cooldown_history_size"`
+}
+
+// AccountHealthConfig 账号健康检查配置
+type AccountHealthConfig struct {
+	// Enabled: 是否启用健康检查（连续失败自动移出调度、探测成功自动恢复）
+	Enabled bool `mapstructure:"enabled"`
+	// FailureThreshold: 窗口内 401/403/5xx 失败次数达到该值即标记不健康
+	FailureThreshold int `mapstructure:"failure_threshold"`
+	// FailureWindowSeconds: 失败计数窗口（秒）
+	FailureWindowSeconds int `mapstructure:"failure_window_seconds"`
+	// ProbeIntervalSeconds: 检查到期探测的轮询间隔（秒）
+	ProbeIntervalSeconds int `mapstructure:"probe_interval_seconds"`
+	// ProbeBackoffBaseSeconds: 首次探测延迟（秒），每次探测失败翻倍
+	ProbeBackoffBaseSeconds int `mapstructure:"probe_backoff_base_seconds"`
+	// ProbeBackoffMaxSeconds: 探测间隔上限（秒）
+	ProbeBackoffMaxSeconds int `mapstructure:"probe_backoff_max_seconds"`
+	// ProbeModel: 探测使用的模型，留空使用各平台默认测试模型
+	ProbeModel string `mapstructure:"probe_model"`
+}
+
+// AccountWarmupConfig 空闲账号定时预热配置
+type AccountWarmupConfig struct {
+	// Enabled: 是否启用预热（向空闲账号发送极小的测试请求，保持会话活跃并提前发现失效凭证）
+	Enabled bool `mapstructure:"enabled"`
+	// IntervalHours: 同一账号两次预热的最小间隔（小时）；超过该时长未被使用的账号视为空闲
+	IntervalHours int `mapstructure:"interval_hours"`
+	// CheckIntervalMinutes: 扫描待预热账号的间隔（分钟）
+	CheckIntervalMinutes int `mapstructure:"check_interval_minutes"`
+	// MaxConcurrency: 同时进行的预热请求数
+	MaxConcurrency int `mapstructure:"max_concurrency"`
+	// IncludeAPIKey: 是否同时预热 API Key 账号（默认仅预热 OAuth / Setup Token 等会话类账号）
+	IncludeAPIKey bool `mapstructure:"include_api_key"`
+	// Model: 预热使用的模型，留空使用各平台默认测试模型
+	Model string `mapstructure:"model"`
+}
+
+// CodexModelSyncConfig Codex 模型目录同步配置
+type CodexModelSyncConfig struct {
+	// Enabled: 是否定时从上游拉取各 OpenAI OAuth 账号可用的 Codex 模型，
+	// 新模型自动加入模型归一化表与 /v1/models，无需等待版本发布
+	Enabled bool `mapstructure:"enabled"`
+	// IntervalMinutes: 同步间隔（分钟）
+	IntervalMinutes int `mapstructure:"interval_minutes"`
+	// MaxConcurrency: 同时进行的拉取请求数
+	MaxConcurrency int `mapstructure:"max_concurrency"`
+}
+
+// GatewayOpenAIUsageWindowConfig Codex 用量窗口本地跟踪配置
+type GatewayOpenAIUsageWindowConfig struct {
+	// Enabled: 预测在 lookahead 内会超出窗口限额的 OpenAI OAuth 账号暂不调度
+	Enabled bool `mapstructure:"enabled"`
+	// LookaheadSeconds: 按最近消耗速率向前预测的时长（秒）
+	LookaheadSeconds int `mapstructure:"lookahead_seconds"`
+	// StopThresholdPercent: 预测用量达到该百分比即停止调度
+	StopThresholdPercent float64 `mapstructure:"stop_threshold_percent"`
+	// FiveHourTokenLimit / WeeklyTokenLimit: 已知的窗口 token 上限；0 表示根据上游用量百分比自动估算
+	FiveHourTokenLimit int64 `mapstructure:"five_hour_token_limit"`
+	WeeklyTokenLimit   int64 `mapstructure:"weekly_token_limit"`
+}
+
+// CredentialEncryptionConfig 账号凭证静态加密配置（AES-256-GCM，按字段加密）
+type CredentialEncryptionConfig struct {
+	// Key: 主密钥（32 字节 hex 编码）；Key 与 KeyFile 均为空时不加密新写入的凭证
+	Key string `mapstructure:"key"`
+	// KeyFile: 从文件读取主密钥（如 KMS / Secret Manager 挂载的密钥文件），Key 非空时忽略
+	KeyFile string `mapstructure:"key_file"`
+	// KeyID: 主密钥标识，写入密文前缀，用于密钥轮换
+	KeyID string `mapstructure:"key_id"`
+	// PreviousKeys: 轮换前的旧密钥，仅用于解密，格式 "id:hex,id:hex"
+	PreviousKeys string `mapstructure:"previous_keys"`
+	// Fields: 需要加密的凭证字段
+	Fields []string `mapstructure:"fields"`
 }
 
 // APIKeyAuthCacheConfig API Key 认证缓存配置
@@ -886,6 +1602,82 @@ type APIKeyAuthCacheConfig struct {
 	Singleflight       bool `mapstructure:"singleflight"`
 }
 
+// APIKeyBudgetConfig API Key 花费预算告警配置
+type APIKeyBudgetConfig struct {
+	// WebhookURL 预算超限时 POST 通知的地址（空表示不发送）；仅由管理员在配置文件中设置
+	WebhookURL string `mapstructure:"webhook_url"`
+	// WebhookSecret 非空时以 HMAC-SHA256 签名请求体，以 sha256=<hex> 写入 X-Sub2API-Signature 头
+	WebhookSecret         string `mapstructure:"webhook_secret"`
+	WebhookTimeoutSeconds int    `mapstructure:"webhook_timeout_seconds"`
+}
+
+// 告警 webhook 目标类型
+const (
+	AlertWebhookTypeSlack    = "slack"
+	AlertWebhookTypeDiscord  = "discord"
+	AlertWebhookTypeTelegram = "telegram"
+	AlertWebhookTypeGeneric  = "generic"
+)
+
+// AlertWebhookEvents 可订阅的运维事件类型
+var AlertWebhookEvents = []string{
+	"account_disabled",
+	"token_refresh_failed",
+	"quota_threshold",
+	"error_rate_spike",
+	"ops_alert",
+}
+
+// AlertWebhooksConfig 运维事件告警 webhook 配置
+type AlertWebhooksConfig struct {
+	Enabled        bool `mapstructure:"enabled"`
+	TimeoutSeconds int  `mapstructure:"timeout_seconds"`
+	// CooldownSeconds 同一事件（相同类型与对象）在冷却期内只通知一次；0 表示不去重
+	CooldownSeconds int `mapstructure:"cooldown_seconds"`
+	// QuotaThresholdPercent API Key 额度消耗首次达到该百分比时触发 quota_threshold
+	QuotaThresholdPercent float64              `mapstructure:"quota_threshold_percent"`
+	Targets               []AlertWebhookTarget `mapstructure:"targets"`
+}
+
+// AlertWebhookTarget 单个通知目标；地址只能由管理员在配置文件中设置，允许指向内网服务
+type AlertWebhookTarget struct {
+	Name string `mapstructure:"name"`
+	// Type slack / discord / telegram / generic
+	Type string `mapstructure:"type"`
+	// URL Slack / Discord incoming webhook 或通用 JSON 接收地址；telegram 类型可留空（默认 https://api.telegram.org）
+	URL string `mapstructure:"url"`
+	// Events 订阅的事件类型，为空表示全部
+	Events []string `mapstructure:"events"`
+	// Template Go text/template 消息模板，为空使用默认格式；generic 类型渲染结果作为完整请求体
+	Template string `mapstructure:"template"`
+	// Templates 按事件类型覆盖 Template
+	Templates map[string]string `mapstructure:"templates"`
+	// Secret generic 类型非空时以 HMAC-SHA256 签名请求体，写入 X-Sub2API-Signature 头
+	Secret           string `mapstructure:"secret"`
+	TelegramBotToken string `mapstructure:"telegram_bot_token"`
+	TelegramChatID   string `mapstructure:"telegram_chat_id"`
+}
+
+// UsageWebhookConfig 逐请求用量推送 webhook 配置：每个请求完成后将用量记录批量 POST 到外部计费系统
+type UsageWebhookConfig struct {
+	Enabled bool `mapstructure:"enabled"`
+	// URL 接收地址；只能由管理员在配置文件中设置，允许指向内网服务
+	URL string `mapstructure:"url"`
+	// Secret 非空时以 HMAC-SHA256 签名请求体，写入 X-Sub2API-Signature 头（与 api_key_budget 相同）
+	Secret         string `mapstructure:"secret"`
+	TimeoutSeconds int    `mapstructure:"timeout_seconds"`
+	// BatchSize 单次推送的最大记录数
+	BatchSize int `mapstructure:"batch_size"`
+	// FlushIntervalMS 未攒满一批时的最长等待时间（毫秒）
+	FlushIntervalMS int `mapstructure:"flush_interval_ms"`
+	// QueueSize 待推送记录的内存队列容量，队列满时丢弃新记录并记录日志
+	QueueSize int `mapstructure:"queue_size"`
+	// MaxRetries 单批推送失败后的最大重试次数；0 表示不重试
+	MaxRetries int `mapstructure:"max_retries"`
+	// RetryBackoffMS 首次重试前的等待时间（毫秒），之后每次翻倍
+	RetryBackoffMS int `mapstructure:"retry_backoff_ms"`
+}
+
 // SubscriptionCacheConfig 订阅认证 L1 缓存配置
 type SubscriptionCacheConfig struct {
 	L1Size        int `mapstructure:"l1_size"`
@@ -953,6 +1745,190 @@ type UsageCleanupConfig struct {
 	TaskTimeoutSeconds int `mapstructure:"task_timeout_seconds"`
 }
 
+// BatchConfig /v1/batches 批处理任务配置
+type BatchConfig struct {
+	// Enabled: 是否启用批处理接口与后台执行器
+	Enabled bool `mapstructure:"enabled"`
+	// WorkerIntervalSeconds: 后台执行器轮询间隔（秒）
+	WorkerIntervalSeconds int `mapstructure:"worker_interval_seconds"`
+	// WorkerConcurrency: 全局并发执行的请求数
+	WorkerConcurrency int `mapstructure:"worker_concurrency"`
+	// PerJobConcurrency: 单个任务并发执行的请求数
+	PerJobConcurrency int `mapstructure:"per_job_concurrency"`
+	// MaxRequestsPerBatch: 单个任务允许的最大请求行数
+	MaxRequestsPerBatch int `mapstructure:"max_requests_per_batch"`
+	// MaxFileSize: 上传 JSONL 的最大字节数
+	MaxFileSize int64 `mapstructure:"max_file_size"`
+	// MaxActiveJobsPerKey: 单个 API Key 同时进行中的任务上限（0 表示不限制）
+	MaxActiveJobsPerKey int `mapstructure:"max_active_jobs_per_key"`
+	// MaxAttempts: 单条请求遇到限流/过载时的最大尝试次数
+	MaxAttempts int `mapstructure:"max_attempts"`
+	// RequestTimeoutSeconds: 单条请求最大执行时长（秒）
+	RequestTimeoutSeconds int `mapstructure:"request_timeout_seconds"`
+	// RetentionHours: 已结束任务及结果的保留时长（小时）
+	RetentionHours int `mapstructure:"retention_hours"`
+}
+
+// BackgroundResponsesConfig Responses API background 模式配置
+type BackgroundResponsesConfig struct {
+	// Enabled: 是否接管 background=true 的请求（关闭时原样转发给上游）
+	Enabled bool `mapstructure:"enabled"`
+	// WorkerIntervalSeconds: 后台执行器轮询间隔（秒）
+	WorkerIntervalSeconds int `mapstructure:"worker_interval_seconds"`
+	// WorkerConcurrency: 全局并发执行的请求数
+	WorkerConcurrency int `mapstructure:"worker_concurrency"`
+	// MaxActivePerKey: 单个 API Key 同时排队/执行中的请求上限（0 表示不限制）
+	MaxActivePerKey int `mapstructure:"max_active_per_key"`
+	// MaxAttempts: 遇到限流/过载时的最大尝试次数
+	MaxAttempts int `mapstructure:"max_attempts"`
+	// RequestTimeoutSeconds: 单个请求最大执行时长（秒）
+	RequestTimeoutSeconds int `mapstructure:"request_timeout_seconds"`
+	// RetentionHours: 已结束响应的保留时长（小时）
+	RetentionHours int `mapstructure:"retention_hours"`
+}
+
+// MetricsConfig Prometheus 指标导出配置（GET /metrics）
+type MetricsConfig struct {
+	// Enabled: 是否开放 /metrics（默认关闭）
+	Enabled bool `mapstructure:"enabled"`
+	// AuthToken: 抓取时需携带的 Bearer 令牌，为空时不校验（应仅在内网暴露）
+	AuthToken string `mapstructure:"auth_token"`
+	// APIKeyLabels: 请求与用量指标是否携带 api_key_id 标签；Key 数量很大时可关闭以控制序列基数
+	APIKeyLabels bool `mapstructure:"api_key_labels"`
+}
+
+// HealthConfig 健康检查配置（GET /health 与 GET /health/details）
+type HealthConfig struct {
+	// DetailsEnabled: 是否开放 /health/details（含账号、队列等详细状态，默认关闭）
+	DetailsEnabled bool `mapstructure:"details_enabled"`
+	// AuthToken: 访问 /health/details 需携带的 Bearer 令牌，为空时不校验（应仅在内网暴露）
+	AuthToken string `mapstructure:"auth_token"`
+	// CacheSeconds: 检查结果缓存秒数，避免负载均衡高频探测反复访问数据库与 Redis；0 表示不缓存
+	CacheSeconds int `mapstructure:"cache_seconds"`
+	// CheckTimeoutSeconds: 单项依赖检查的超时时间
+	CheckTimeoutSeconds int `mapstructure:"check_timeout_seconds"`
+	// ReadinessRequireAccount: /readyz 是否要求至少一个健康可调度账号（首次部署尚无账号时可关闭）
+	ReadinessRequireAccount bool `mapstructure:"readiness_require_account"`
+}
+
+// TracingConfig OpenTelemetry 链路追踪配置（OTLP/HTTP JSON 导出）
+type TracingConfig struct {
+	// Enabled: 是否开启链路追踪（默认关闭）
+	Enabled bool `mapstructure:"enabled"`
+	// Endpoint: OTLP/HTTP traces 接收地址（如 http://localhost:4318/v1/traces）
+	Endpoint string `mapstructure:"endpoint"`
+	// Headers: 导出请求附加的请求头（如后端鉴权）
+	Headers map[string]string `mapstructure:"headers"`
+	// ServiceName: 上报的 service.name
+	ServiceName string `mapstructure:"service_name"`
+	// SampleRatio: 根 span 采样比例（0-1）；携带 traceparent 的请求沿用上游的采样决定
+	SampleRatio float64 `mapstructure:"sample_ratio"`
+	// PropagateUpstream: 是否向上游请求注入 traceparent/tracestate 头
+	PropagateUpstream bool `mapstructure:"propagate_upstream"`
+	// QueueSize: 待导出 span 队列长度，满时丢弃新 span（不阻塞请求）
+	QueueSize int `mapstructure:"queue_size"`
+	// BatchSize: 单次导出的最大 span 数
+	BatchSize int `mapstructure:"batch_size"`
+	// ExportTimeoutSeconds: 单次导出超时（秒）
+	ExportTimeoutSeconds int `mapstructure:"export_timeout_seconds"`
+}
+
+// SSEReplayConfig 流式响应断线续传配置：按 Last-Event-ID 重放进程内缓冲的 SSE 事件
+type SSEReplayConfig struct {
+	// Enabled: 是否为流式响应分配事件 id 并缓冲最近事件
+	Enabled bool `mapstructure:"enabled"`
+	// BufferEvents: 每个流保留的最近事件数（环形缓冲）
+	BufferEvents int `mapstructure:"buffer_events"`
+	// RetentionSeconds: 流结束后缓冲保留时长（秒），供结束前断线的客户端取回剩余事件
+	RetentionSeconds int `mapstructure:"retention_seconds"`
+	// ReconnectGraceSeconds: 客户端断开后上游继续生成、等待重连的时长（秒），超时无人重连则取消上游请求
+	ReconnectGraceSeconds int `mapstructure:"reconnect_grace_seconds"`
+}
+
+// ResponseCacheConfig 确定性非流式请求（temperature=0）的响应缓存配置
+type ResponseCacheConfig struct {
+	// Enabled: 是否启用响应缓存（默认关闭）
+	Enabled bool `mapstructure:"enabled"`
+	// Backend: 缓存存储（memory / redis），redis 可跨实例共享
+	Backend string `mapstructure:"backend"`
+	// TTLSeconds: 缓存有效期（秒）
+	TTLSeconds int `mapstructure:"ttl_seconds"`
+	// MaxEntries: memory 存储的最大条目数，超出后淘汰最早写入的条目
+	MaxEntries int `mapstructure:"max_entries"`
+	// MaxBodyBytes: 可缓存的最大响应体字节数，超出则不缓存
+	MaxBodyBytes int `mapstructure:"max_body_bytes"`
+}
+
+// ConversationStoreConfig Responses API 会话存储配置：本地保存每轮的输入与输出，
+// 使 previous_response_id 在续链请求落到不同上游账号或上游未存储（store=false）时仍可用
+type ConversationStoreConfig struct {
+	// Enabled: 是否启用会话存储（默认关闭）
+	Enabled bool `mapstructure:"enabled"`
+	// Backend: 存储（memory / redis），多实例部署需使用 redis
+	Backend string `mapstructure:"backend"`
+	// TTLSeconds: 每轮会话上下文的保留时长（秒）
+	TTLSeconds int `mapstructure:"ttl_seconds"`
+	// MaxEntries: memory 存储的最大条目数，超出后淘汰最早写入的条目
+	MaxEntries int `mapstructure:"max_entries"`
+	// MaxBytes: 单个响应体与单条会话上下文的最大字节数，超出则该轮不保存
+	MaxBytes int `mapstructure:"max_bytes"`
+}
+
+// SharedStateConfig 多实例部署时通过 Redis 共享的账号运行时状态（熔断冷却、Codex 用量窗口）。
+// 限流计数、粘性会话与临时不可调度状态本身已存储在 Redis 中，不受此开关影响。
+type SharedStateConfig struct {
+	// Enabled: 是否在实例间共享账号熔断与用量窗口状态（默认关闭，单实例部署无需开启）
+	Enabled bool `mapstructure:"enabled"`
+	// SyncIntervalSeconds: 从 Redis 拉取其他实例状态的间隔（秒）
+	SyncIntervalSeconds int `mapstructure:"sync_interval_seconds"`
+}
+
+// ClusterConfig 多实例集群模式：通过 Redis 租约选举领导者，
+// Token 刷新、账号健康探测与空闲预热只在领导者上运行，所有实例照常处理请求。
+type ClusterConfig struct {
+	// Enabled: 是否启用领导者选举（默认关闭，单实例部署无需开启）
+	Enabled bool `mapstructure:"enabled"`
+	// LeaderLeaseSeconds: 领导者租约时长（秒），每 1/3 租约续期一次；领导者宕机后最长经过该时长完成切换
+	LeaderLeaseSeconds int `mapstructure:"leader_lease_seconds"`
+}
+
+// FilesConfig /v1/files 文件存储配置
+type FilesConfig struct {
+	// Enabled: 是否启用文件接口及 file_id 引用解析
+	Enabled bool `mapstructure:"enabled"`
+	// MaxFileSize: 单个文件的最大字节数
+	MaxFileSize int64 `mapstructure:"max_file_size"`
+	// MaxFilesPerUser: 单个用户可保存的文件数上限（0 表示不限制）
+	MaxFilesPerUser int `mapstructure:"max_files_per_user"`
+	// RetentionDays: 文件默认保留天数（0 表示永久保留，直到用户删除）
+	RetentionDays int `mapstructure:"retention_days"`
+	// CleanupIntervalMinutes: 过期文件清理间隔（分钟）
+	CleanupIntervalMinutes int `mapstructure:"cleanup_interval_minutes"`
+}
+
+// DeadLetterConfig 死信记录配置：重试与账号切换均失败的网关请求连同完整请求体落库，账号恢复后可由管理员重放
+type DeadLetterConfig struct {
+	// Enabled: 是否记录死信（默认开启）
+	Enabled bool `mapstructure:"enabled"`
+	// MaxBodyBytes: 保存请求体的最大字节数，超出时只记录失败信息（无法重放）
+	MaxBodyBytes int `mapstructure:"max_body_bytes"`
+	// RetentionDays: 死信保留天数，过期记录定期清理
+	RetentionDays int `mapstructure:"retention_days"`
+	// ReplayConcurrency: 同时执行的重放请求数
+	ReplayConcurrency int `mapstructure:"replay_concurrency"`
+}
+
+// ContentLogConfig 请求内容记录配置：开启了 log_content 的 API Key 的完整提示词与响应单独落库，用于排查问题；
+// 与常驻的用量 / 运维元数据日志分开存放，过期自动清理
+type ContentLogConfig struct {
+	// Enabled: 是否允许记录请求内容（关闭时忽略所有 Key 的 log_content 设置）
+	Enabled bool `mapstructure:"enabled"`
+	// MaxBodyBytes: 请求体与响应体各自保存的最大字节数，超出部分截断
+	MaxBodyBytes int `mapstructure:"max_body_bytes"`
+	// RetentionDays: 保留天数，过期记录每小时清理一次
+	RetentionDays int `mapstructure:"retention_days"`
+}
+
 func NormalizeRunMode(value string) string {
 	normalized := strings.ToLower(strings.TrimSpace(value))
 	switch normalized {
@@ -976,6 +1952,45 @@ func LoadForBootstrap() (*Config, error) {
 }
 
 func load(allowMissingJWTSecret bool) (*Config, error) {
+	configureViper()
+
+	if err := viper.ReadInConfig(); err != nil {
+		if _, ok := err.(viper.ConfigFileNotFoundError); !ok {
+			return nil, fmt.Errorf("read config error: %w", err)
+		}
+		// 配置文件不存在时使用默认值
+	}
+
+	return decodeConfig(allowMissingJWTSecret)
+}
+
+// ValidateConfigFile 校验配置文件（供 `sub2api config validate` 使用），path 为空时按启动时的搜索路径查找。
+// 返回实际使用的文件、全部结构问题（含未知配置项）以及解析或语义校验错误；
+// 存在无法转换的值时不再做语义校验。
+func ValidateConfigFile(path string) (string, []ConfigIssue, error) {
+	configureViper()
+	if path != "" {
+		viper.SetConfigFile(path)
+	}
+	if err := viper.ReadInConfig(); err != nil {
+		return "", nil, fmt.Errorf("read config error: %w", err)
+	}
+	file := viper.ConfigFileUsed()
+	issues, err := CheckConfigFile(file)
+	if err != nil {
+		return file, nil, err
+	}
+	for _, issue := range issues {
+		if !issue.Unknown {
+			return file, issues, nil
+		}
+	}
+	_, err = unmarshalConfig(file, true)
+	return file, issues, err
+}
+
+// configureViper 设置配置文件搜索路径、环境变量映射与默认值
+func configureViper() {
 	viper.SetConfigName("config")
 	viper.SetConfigType("yaml")
 
@@ -999,18 +2014,42 @@ func load(allowMissingJWTSecret bool) (*Config, error) {
 
 	// 默认值
 	setDefaults()
+}
 
-	if err := viper.ReadInConfig(); err != nil {
-		if _, ok := err.(viper.ConfigFileNotFoundError); !ok {
+// decodeConfig 将 viper 当前状态解析为 Config 并完成规范化与校验。
+// 启动加载与配置文件热更新共用该流程，保证两者语义一致。
+// 使用配置文件时先按 Config 结构校验：未知配置项（多为拼写错误）记录警告，无法转换的值直接拒绝。
+func decodeConfig(allowMissingJWTSecret bool) (*Config, error) {
+	file := viper.ConfigFileUsed()
+	if file != "" {
+		issues, err := CheckConfigFile(file)
+		if err != nil {
 			return nil, fmt.Errorf("read config error: %w", err)
 		}
-		// 配置文件不存在时使用默认值
+		var invalid []ConfigIssue
+		for _, issue := range issues {
+			if issue.Unknown {
+				slog.Warn("unknown config key ignored", "file", file, "line", issue.Line, "key", issue.Key, "detail", issue.Message)
+				continue
+			}
+			invalid = append(invalid, issue)
+		}
+		if len(invalid) > 0 {
+			return nil, &ConfigFileError{File: file, Issues: invalid}
+		}
 	}
+	return unmarshalConfig(file, allowMissingJWTSecret)
+}
 
+// unmarshalConfig 解析、规范化并校验配置；file 非空时为校验错误补充配置文件行号
+func unmarshalConfig(file string, allowMissingJWTSecret bool) (*Config, error) {
 	var cfg Config
 	if err := viper.Unmarshal(&cfg); err != nil {
 		return nil, fmt.Errorf("unmarshal config error: %w", err)
 	}
+	if err := resolveSecretRefs(&cfg); err != nil {
+		return nil, fmt.Errorf("resolve secret error: %w", err)
+	}
 
 	cfg.RunMode = NormalizeRunMode(cfg.RunMode)
 	cfg.Server.Mode = strings.ToLower(strings.TrimSpace(cfg.Server.Mode))
@@ -1031,8 +2070,25 @@ func load(allowMissingJWTSecret bool) (*Config, error) {
 	cfg.LinuxDo.UserInfoEmailPath = strings.TrimSpace(cfg.LinuxDo.UserInfoEmailPath)
 	cfg.LinuxDo.UserInfoIDPath = strings.TrimSpace(cfg.LinuxDo.UserInfoIDPath)
 	cfg.LinuxDo.UserInfoUsernamePath = strings.TrimSpace(cfg.LinuxDo.UserInfoUsernamePath)
+	cfg.OIDC.ProviderName = strings.TrimSpace(cfg.OIDC.ProviderName)
+	cfg.OIDC.IssuerURL = strings.TrimRight(strings.TrimSpace(cfg.OIDC.IssuerURL), "/")
+	cfg.OIDC.ClientID = strings.TrimSpace(cfg.OIDC.ClientID)
+	cfg.OIDC.ClientSecret = strings.TrimSpace(cfg.OIDC.ClientSecret)
+	cfg.OIDC.Scopes = strings.TrimSpace(cfg.OIDC.Scopes)
+	cfg.OIDC.RedirectURL = strings.TrimSpace(cfg.OIDC.RedirectURL)
+	cfg.OIDC.FrontendRedirectURL = strings.TrimSpace(cfg.OIDC.FrontendRedirectURL)
+	cfg.OIDC.TokenAuthMethod = strings.ToLower(strings.TrimSpace(cfg.OIDC.TokenAuthMethod))
+	cfg.OIDC.GroupsClaim = strings.TrimSpace(cfg.OIDC.GroupsClaim)
+	cfg.OIDC.DefaultRole = strings.ToLower(strings.TrimSpace(cfg.OIDC.DefaultRole))
+	for i := range cfg.OIDC.RoleMappings {
+		cfg.OIDC.RoleMappings[i].Group = strings.TrimSpace(cfg.OIDC.RoleMappings[i].Group)
+		cfg.OIDC.RoleMappings[i].Role = strings.ToLower(strings.TrimSpace(cfg.OIDC.RoleMappings[i].Role))
+	}
+	cfg.OIDC.AllowedEmailDomains = normalizeStringSlice(cfg.OIDC.AllowedEmailDomains)
 	cfg.Dashboard.KeyPrefix = strings.TrimSpace(cfg.Dashboard.KeyPrefix)
 	cfg.CORS.AllowedOrigins = normalizeStringSlice(cfg.CORS.AllowedOrigins)
+	cfg.CORS.AllowedHeaders = normalizeStringSlice(cfg.CORS.AllowedHeaders)
+	cfg.CORS.ExposedHeaders = normalizeStringSlice(cfg.CORS.ExposedHeaders)
 	cfg.Security.ResponseHeaders.AdditionalAllowed = normalizeStringSlice(cfg.Security.ResponseHeaders.AdditionalAllowed)
 	cfg.Security.ResponseHeaders.ForceRemove = normalizeStringSlice(cfg.Security.ResponseHeaders.ForceRemove)
 	cfg.Security.CSP.Policy = strings.TrimSpace(cfg.Security.CSP.Policy)
@@ -1042,6 +2098,72 @@ func load(allowMissingJWTSecret bool) (*Config, error) {
 	cfg.Log.Environment = strings.TrimSpace(cfg.Log.Environment)
 	cfg.Log.StacktraceLevel = strings.ToLower(strings.TrimSpace(cfg.Log.StacktraceLevel))
 	cfg.Log.Output.FilePath = strings.TrimSpace(cfg.Log.Output.FilePath)
+	cfg.Log.Access.RedactHeaders = normalizeStringSlice(cfg.Log.Access.RedactHeaders)
+	cfg.Gateway.CodexCLIUserAgentPrefixes = normalizeStringSlice(cfg.Gateway.CodexCLIUserAgentPrefixes)
+	for i := range cfg.Gateway.ReasoningDefaults {
+		rule := &cfg.Gateway.ReasoningDefaults[i]
+		rule.Models = normalizeStringSlice(rule.Models)
+		rule.Clients = normalizeStringSlice(rule.Clients)
+		for j := range rule.Clients {
+			rule.Clients[j] = strings.ToLower(rule.Clients[j])
+		}
+		rule.Effort = strings.ToLower(strings.TrimSpace(rule.Effort))
+	}
+	for i := range cfg.Gateway.ParamSanitize.Rules {
+		rule := &cfg.Gateway.ParamSanitize.Rules[i]
+		rule.Models = normalizeStringSlice(rule.Models)
+		rule.DropParams = normalizeStringSlice(rule.DropParams)
+	}
+	for i := range cfg.Gateway.OpenAIPlanRestrictions {
+		rule := &cfg.Gateway.OpenAIPlanRestrictions[i]
+		rule.Models = normalizeStringSlice(rule.Models)
+		rule.ReasoningEfforts = normalizeStringSlice(rule.ReasoningEfforts)
+		for j := range rule.ReasoningEfforts {
+			rule.ReasoningEfforts[j] = strings.ToLower(rule.ReasoningEfforts[j])
+		}
+		rule.Plans = normalizeStringSlice(rule.Plans)
+		for j := range rule.Plans {
+			rule.Plans[j] = strings.ToLower(rule.Plans[j])
+		}
+	}
+	cfg.Gateway.ContextOverflow.Strategy = strings.ToLower(strings.TrimSpace(cfg.Gateway.ContextOverflow.Strategy))
+	cfg.Gateway.ContextOverflow.CompactModel = strings.TrimSpace(cfg.Gateway.ContextOverflow.CompactModel)
+	for i := range cfg.Gateway.ContextOverflow.Rules {
+		rule := &cfg.Gateway.ContextOverflow.Rules[i]
+		rule.Models = normalizeStringSlice(rule.Models)
+		rule.Strategy = strings.ToLower(strings.TrimSpace(rule.Strategy))
+	}
+	cfg.Gateway.Moderation.PreFilterSource = strings.ToLower(strings.TrimSpace(cfg.Gateway.Moderation.PreFilterSource))
+	cfg.Gateway.Moderation.Endpoint.URL = strings.TrimSpace(cfg.Gateway.Moderation.Endpoint.URL)
+	cfg.Gateway.Moderation.BlockCategories = normalizeStringSlice(cfg.Gateway.Moderation.BlockCategories)
+	cfg.Gateway.Moderation.FlagCategories = normalizeStringSlice(cfg.Gateway.Moderation.FlagCategories)
+	for i := range cfg.Gateway.SystemPrompts {
+		rule := &cfg.Gateway.SystemPrompts[i]
+		rule.Paths = normalizeStringSlice(rule.Paths)
+		rule.Models = normalizeStringSlice(rule.Models)
+		rule.Mode = strings.ToLower(strings.TrimSpace(rule.Mode))
+		if rule.Mode == "" {
+			rule.Mode = "prepend"
+		}
+	}
+
+	for i := range cfg.Gateway.Hooks.Handlers {
+		hook := &cfg.Gateway.Hooks.Handlers[i]
+		hook.Name = strings.TrimSpace(hook.Name)
+		hook.Type = strings.ToLower(strings.TrimSpace(hook.Type))
+		hook.Stages = normalizeStringSlice(hook.Stages)
+		for j := range hook.Stages {
+			hook.Stages[j] = strings.ToLower(hook.Stages[j])
+		}
+		hook.Paths = normalizeStringSlice(hook.Paths)
+		hook.Plugin = strings.TrimSpace(hook.Plugin)
+		hook.PluginPath = strings.TrimSpace(hook.PluginPath)
+		hook.URL = strings.TrimSpace(hook.URL)
+		hook.Command = strings.TrimSpace(hook.Command)
+		if hook.TimeoutMS <= 0 {
+			hook.TimeoutMS = 2000
+		}
+	}
 
 	// 兼容旧键 gateway.openai_ws.sticky_previous_response_ttl_seconds。
 	// 新键未配置（<=0）时回退旧键；新键优先。
@@ -1078,6 +2200,9 @@ func load(allowMissingJWTSecret bool) (*Config, error) {
 	}
 
 	if err := cfg.Validate(); err != nil {
+		if file != "" {
+			err = locateConfigError(file, err)
+		}
 		return nil, fmt.Errorf("validate config error: %w", err)
 	}
 
@@ -1117,6 +2242,8 @@ func setDefaults() {
 	viper.SetDefault("server.idle_timeout", 120)       // 120秒空闲超时
 	viper.SetDefault("server.trusted_proxies", []string{})
 	viper.SetDefault("server.max_request_body_size", int64(256*1024*1024))
+	viper.SetDefault("server.shutdown_drain_seconds", 60)
+	viper.SetDefault("server.shutdown_unready_seconds", 0)
 	// H2C 默认配置
 	viper.SetDefault("server.h2c.enabled", false)
 	viper.SetDefault("server.h2c.max_concurrent_streams", uint32(50))      // 50 个并发流
@@ -1124,6 +2251,19 @@ func setDefaults() {
 	viper.SetDefault("server.h2c.max_read_frame_size", 1<<20)              // 1MB（够用）
 	viper.SetDefault("server.h2c.max_upload_buffer_per_connection", 2<<20) // 2MB
 	viper.SetDefault("server.h2c.max_upload_buffer_per_stream", 512<<10)   // 512KB
+	viper.SetDefault("server.unix_socket", "")
+	viper.SetDefault("server.unix_socket_mode", "0660")
+	viper.SetDefault("server.systemd_socket_activation", false)
+	viper.SetDefault("server.tls.enabled", false)
+	viper.SetDefault("server.tls.cert_file", "")
+	viper.SetDefault("server.tls.key_file", "")
+	viper.SetDefault("server.tls.reload_check_seconds", 60)
+	viper.SetDefault("server.tls.acme.enabled", false)
+	viper.SetDefault("server.tls.acme.domains", []string{})
+	viper.SetDefault("server.tls.acme.email", "")
+	viper.SetDefault("server.tls.acme.cache_dir", "./data/acme")
+	viper.SetDefault("server.tls.acme.directory_url", "")
+	viper.SetDefault("server.tls.acme.http_challenge_addr", ":80")
 
 	// Log
 	viper.SetDefault("log.level", "info")
@@ -1143,10 +2283,18 @@ func setDefaults() {
 	viper.SetDefault("log.sampling.enabled", false)
 	viper.SetDefault("log.sampling.initial", 100)
 	viper.SetDefault("log.sampling.thereafter", 100)
+	viper.SetDefault("log.access.headers", false)
+	viper.SetDefault("log.access.redact_headers", []string{})
+	viper.SetDefault("log.access.hash_prompts", false)
+	viper.SetDefault("log.access.body_api_key_ids", []int64{})
+	viper.SetDefault("log.access.body_max_bytes", 2048)
 
 	// CORS
 	viper.SetDefault("cors.allowed_origins", []string{})
 	viper.SetDefault("cors.allow_credentials", true)
+	viper.SetDefault("cors.allowed_headers", []string{})
+	viper.SetDefault("cors.exposed_headers", []string{})
+	viper.SetDefault("cors.max_age_seconds", 86400)
 
 	// Security
 	viper.SetDefault("security.url_allowlist.enabled", false)
@@ -1201,6 +2349,21 @@ func setDefaults() {
 	viper.SetDefault("linuxdo_connect.userinfo_id_path", "")
 	viper.SetDefault("linuxdo_connect.userinfo_username_path", "")
 
+	// 管理后台 OIDC/SSO 登录
+	viper.SetDefault("oidc.enabled", false)
+	viper.SetDefault("oidc.provider_name", "SSO")
+	viper.SetDefault("oidc.issuer_url", "")
+	viper.SetDefault("oidc.client_id", "")
+	viper.SetDefault("oidc.client_secret", "")
+	viper.SetDefault("oidc.scopes", "openid email profile")
+	viper.SetDefault("oidc.redirect_url", "")
+	viper.SetDefault("oidc.frontend_redirect_url", "/auth/oidc/callback")
+	viper.SetDefault("oidc.token_auth_method", "client_secret_basic")
+	viper.SetDefault("oidc.use_pkce", true)
+	viper.SetDefault("oidc.groups_claim", "groups")
+	viper.SetDefault("oidc.default_role", "")
+	viper.SetDefault("oidc.allow_unverified_email", false)
+
 	// Database
 	viper.SetDefault("database.host", "localhost")
 	viper.SetDefault("database.port", 5432)
@@ -1249,6 +2412,13 @@ func setDefaults() {
 	// TOTP
 	viper.SetDefault("totp.encryption_key", "")
 
+	// Credential encryption at rest
+	viper.SetDefault("credential_encryption.key", "")
+	viper.SetDefault("credential_encryption.key_file", "")
+	viper.SetDefault("credential_encryption.key_id", "k1")
+	viper.SetDefault("credential_encryption.previous_keys", "")
+	viper.SetDefault("credential_encryption.fields", []string{"access_token", "refresh_token", "id_token", "session_token", "session_key", "cookie", "cookies", "api_key"})
+
 	// Default
 	// Admin credentials are created via the setup flow (web wizard / CLI / AUTO_SETUP).
 	// Do not ship fixed defaults here to avoid insecure "known credentials" in production.
@@ -1262,6 +2432,31 @@ func setDefaults() {
 	// RateLimit
 	viper.SetDefault("rate_limit.overload_cooldown_minutes", 10)
 	viper.SetDefault("rate_limit.oauth_401_cooldown_minutes", 10)
+	viper.SetDefault("rate_limit.cooldown_backoff_base_seconds", 300)
+	viper.SetDefault("rate_limit.cooldown_backoff_max_seconds", 3600)
+	viper.SetDefault("rate_limit.cooldown_history_size", 500)
+
+	// Account health
+	viper.SetDefault("account_health.enabled", true)
+	viper.SetDefault("account_health.failure_threshold", 3)
+	viper.SetDefault("account_health.failure_window_seconds", 300)
+	viper.SetDefault("account_health.probe_interval_seconds", 30)
+	viper.SetDefault("account_health.probe_backoff_base_seconds", 60)
+	viper.SetDefault("account_health.probe_backoff_max_seconds", 1800)
+	viper.SetDefault("account_health.probe_model", "")
+
+	// Account Warmup
+	viper.SetDefault("account_warmup.enabled", false)
+	viper.SetDefault("account_warmup.interval_hours", 6)
+	viper.SetDefault("account_warmup.check_interval_minutes", 10)
+	viper.SetDefault("account_warmup.max_concurrency", 2)
+	viper.SetDefault("account_warmup.include_api_key", false)
+	viper.SetDefault("account_warmup.model", "")
+
+	// Codex 模型目录同步
+	viper.SetDefault("codex_model_sync.enabled", true)
+	viper.SetDefault("codex_model_sync.interval_minutes", 360)
+	viper.SetDefault("codex_model_sync.max_concurrency", 2)
 
 	// Pricing - 从 model-price-repo 同步模型定价和上下文窗口数据（固定到 commit，避免分支漂移）
 	viper.SetDefault("pricing.remote_url", "https://raw.githubusercontent.com/ShaohongDong/model-price-repo/c7947e9871687e664180bc971d4837f1fc2784a9/model_prices_and_context_window.json")
@@ -1282,6 +2477,11 @@ func setDefaults() {
 	viper.SetDefault("api_key_auth_cache.jitter_percent", 10)
 	viper.SetDefault("api_key_auth_cache.singleflight", true)
 
+	// API Key budget alerts
+	viper.SetDefault("api_key_budget.webhook_url", "")
+	viper.SetDefault("api_key_budget.webhook_secret", "")
+	viper.SetDefault("api_key_budget.webhook_timeout_seconds", 10)
+
 	// Subscription auth L1 cache
 	viper.SetDefault("subscription_cache.l1_size", 16384)
 	viper.SetDefault("subscription_cache.l1_ttl_seconds", 10)
@@ -1312,6 +2512,112 @@ func setDefaults() {
 	viper.SetDefault("usage_cleanup.worker_interval_seconds", 10)
 	viper.SetDefault("usage_cleanup.task_timeout_seconds", 1800)
 
+	// Batch API
+	viper.SetDefault("batch.enabled", true)
+	viper.SetDefault("batch.worker_interval_seconds", 5)
+	viper.SetDefault("batch.worker_concurrency", 8)
+	viper.SetDefault("batch.per_job_concurrency", 4)
+	viper.SetDefault("batch.max_requests_per_batch", 50000)
+	viper.SetDefault("batch.max_file_size", int64(100*1024*1024))
+	viper.SetDefault("batch.max_active_jobs_per_key", 10)
+	viper.SetDefault("batch.max_attempts", 5)
+	viper.SetDefault("batch.request_timeout_seconds", 600)
+	viper.SetDefault("batch.retention_hours", 168)
+
+	// Responses API background 模式
+	viper.SetDefault("background_responses.enabled", true)
+	viper.SetDefault("background_responses.worker_interval_seconds", 5)
+	viper.SetDefault("background_responses.worker_concurrency", 16)
+	viper.SetDefault("background_responses.max_active_per_key", 20)
+	viper.SetDefault("background_responses.max_attempts", 5)
+	viper.SetDefault("background_responses.request_timeout_seconds", 1800)
+	viper.SetDefault("background_responses.retention_hours", 72)
+
+	// Metrics
+	viper.SetDefault("metrics.enabled", false)
+	viper.SetDefault("metrics.auth_token", "")
+	viper.SetDefault("metrics.api_key_labels", true)
+
+	// Alert webhooks
+	viper.SetDefault("alert_webhooks.enabled", false)
+	viper.SetDefault("alert_webhooks.timeout_seconds", 10)
+	viper.SetDefault("alert_webhooks.cooldown_seconds", 600)
+	viper.SetDefault("alert_webhooks.quota_threshold_percent", 80)
+
+	// Usage webhook
+	viper.SetDefault("usage_webhook.enabled", false)
+	viper.SetDefault("usage_webhook.url", "")
+	viper.SetDefault("usage_webhook.secret", "")
+	viper.SetDefault("usage_webhook.timeout_seconds", 10)
+	viper.SetDefault("usage_webhook.batch_size", 100)
+	viper.SetDefault("usage_webhook.flush_interval_ms", 1000)
+	viper.SetDefault("usage_webhook.queue_size", 10000)
+	viper.SetDefault("usage_webhook.max_retries", 3)
+	viper.SetDefault("usage_webhook.retry_backoff_ms", 500)
+
+	// Shared state
+	viper.SetDefault("shared_state.enabled", false)
+	viper.SetDefault("shared_state.sync_interval_seconds", 2)
+
+	// Cluster
+	viper.SetDefault("cluster.enabled", false)
+	viper.SetDefault("cluster.leader_lease_seconds", 15)
+
+	// Health
+	viper.SetDefault("health.details_enabled", false)
+	viper.SetDefault("health.auth_token", "")
+	viper.SetDefault("health.cache_seconds", 5)
+	viper.SetDefault("health.check_timeout_seconds", 2)
+	viper.SetDefault("health.readiness_require_account", true)
+
+	// Tracing
+	viper.SetDefault("tracing.enabled", false)
+	viper.SetDefault("tracing.endpoint", "http://localhost:4318/v1/traces")
+	viper.SetDefault("tracing.service_name", "sub2api")
+	viper.SetDefault("tracing.sample_ratio", 1.0)
+	viper.SetDefault("tracing.propagate_upstream", true)
+	viper.SetDefault("tracing.queue_size", 2048)
+	viper.SetDefault("tracing.batch_size", 512)
+	viper.SetDefault("tracing.export_timeout_seconds", 10)
+
+	// SSE replay
+	viper.SetDefault("sse_replay.enabled", true)
+	viper.SetDefault("sse_replay.buffer_events", 2048)
+	viper.SetDefault("sse_replay.retention_seconds", 120)
+	viper.SetDefault("sse_replay.reconnect_grace_seconds", 60)
+
+	// Response cache
+	viper.SetDefault("response_cache.enabled", false)
+	viper.SetDefault("response_cache.backend", "memory")
+	viper.SetDefault("response_cache.ttl_seconds", 300)
+	viper.SetDefault("response_cache.max_entries", 10000)
+	viper.SetDefault("response_cache.max_body_bytes", 1<<20)
+
+	// Conversation store (previous_response_id)
+	viper.SetDefault("conversation_store.enabled", false)
+	viper.SetDefault("conversation_store.backend", "memory")
+	viper.SetDefault("conversation_store.ttl_seconds", 3600)
+	viper.SetDefault("conversation_store.max_entries", 10000)
+	viper.SetDefault("conversation_store.max_bytes", 4<<20)
+
+	// Files API
+	viper.SetDefault("files.enabled", true)
+	viper.SetDefault("files.max_file_size", int64(32*1024*1024))
+	viper.SetDefault("files.max_files_per_user", 1000)
+	viper.SetDefault("files.retention_days", 30)
+	viper.SetDefault("files.cleanup_interval_minutes", 60)
+
+	// Dead letter
+	viper.SetDefault("dead_letter.enabled", true)
+	viper.SetDefault("dead_letter.max_body_bytes", 1<<20)
+	viper.SetDefault("dead_letter.retention_days", 7)
+	viper.SetDefault("dead_letter.replay_concurrency", 4)
+
+	// Content log
+	viper.SetDefault("content_log.enabled", true)
+	viper.SetDefault("content_log.max_body_bytes", 256<<10)
+	viper.SetDefault("content_log.retention_days", 3)
+
 	// Idempotency
 	viper.SetDefault("idempotency.observe_only", true)
 	viper.SetDefault("idempotency.default_ttl_seconds", 86400)
@@ -1321,6 +2627,9 @@ func setDefaults() {
 	viper.SetDefault("idempotency.max_stored_response_len", 64*1024)
 	viper.SetDefault("idempotency.cleanup_interval_seconds", 60)
 	viper.SetDefault("idempotency.cleanup_batch_size", 500)
+	viper.SetDefault("idempotency.gateway.enabled", true)
+	viper.SetDefault("idempotency.gateway.ttl_seconds", 600)
+	viper.SetDefault("idempotency.gateway.max_response_bytes", 1<<20)
 
 	// Gateway
 	viper.SetDefault("gateway.response_header_timeout", 600) // 600秒(10分钟)等待上游响应头，LLM高负载时可能排队较久
@@ -1328,9 +2637,43 @@ func setDefaults() {
 	viper.SetDefault("gateway.log_upstream_error_body_max_bytes", 2048)
 	viper.SetDefault("gateway.inject_beta_for_apikey", false)
 	viper.SetDefault("gateway.failover_on_400", false)
+	viper.SetDefault("gateway.structured_output_max_retries", 0)
+	viper.SetDefault("gateway.image_downscale.enabled", false)
+	viper.SetDefault("gateway.image_downscale.max_dimension", 1568)
+	viper.SetDefault("gateway.image_downscale.jpeg_quality", 85)
+	viper.SetDefault("gateway.image_downscale.min_bytes", 262144)
+	viper.SetDefault("gateway.moderation.mode", ModerationModeUpstream)
+	viper.SetDefault("gateway.moderation.pre_filter", false)
+	viper.SetDefault("gateway.moderation.pre_filter_source", ModerationSourceLocal)
+	viper.SetDefault("gateway.moderation.endpoint.model", "omni-moderation-latest")
+	viper.SetDefault("gateway.moderation.endpoint.timeout_seconds", 5)
+	viper.SetDefault("gateway.moderation.endpoint.fail_open", true)
+	viper.SetDefault("gateway.hooks.enabled", false)
+	viper.SetDefault("gateway.param_sanitize.enabled", true)
+	viper.SetDefault("gateway.param_sanitize.use_pricing_limits", true)
+	viper.SetDefault("gateway.context_overflow.strategy", ContextOverflowStrategyOff)
+	viper.SetDefault("gateway.context_overflow.use_pricing_limits", true)
+	viper.SetDefault("gateway.context_overflow.safety_margin_percent", 5)
+	viper.SetDefault("gateway.context_overflow.compact_model", "")
+	viper.SetDefault("gateway.context_overflow.compact_max_tokens", 2048)
+	viper.SetDefault("gateway.chat_multi_choice.enabled", false)
+	viper.SetDefault("gateway.chat_multi_choice.max_n", 8)
+	viper.SetDefault("gateway.chat_multi_choice.max_parallel", 4)
+	viper.SetDefault("gateway.openai_session_headers.enabled", false)
+	viper.SetDefault("gateway.openai_session_headers.rotation_hours", 24)
 	viper.SetDefault("gateway.max_account_switches", 10)
 	viper.SetDefault("gateway.max_account_switches_gemini", 3)
 	viper.SetDefault("gateway.force_codex_cli", false)
+	viper.SetDefault("gateway.codex_cli_user_agent_prefixes", []string{})
+	viper.SetDefault("gateway.codex_cli_user_agent_prefixes_override", false)
+	viper.SetDefault("gateway.upstream_user_agent.enabled", false)
+	viper.SetDefault("gateway.upstream_user_agent.template", "codex_cli_rs/{version}")
+	viper.SetDefault("gateway.codex_instructions.default", "")
+	viper.SetDefault("gateway.codex_instructions.append", "")
+	viper.SetDefault("gateway.upstream_user_agent.version", "0.104.0")
+	viper.SetDefault("gateway.upstream_user_agent.auto_update", false)
+	viper.SetDefault("gateway.upstream_user_agent.auto_update_interval_minutes", 360)
+	viper.SetDefault("gateway.upstream_user_agent.release_repo", "openai/codex")
 	viper.SetDefault("gateway.openai_passthrough_allow_timeout_headers", false)
 	// OpenAI Responses WebSocket（默认开启；可通过 force_http 紧急回滚）
 	viper.SetDefault("gateway.openai_ws.enabled", true)
@@ -1378,12 +2721,25 @@ func setDefaults() {
 	viper.SetDefault("gateway.openai_ws.scheduler_score_weights.queue", 0.7)
 	viper.SetDefault("gateway.openai_ws.scheduler_score_weights.error_rate", 0.8)
 	viper.SetDefault("gateway.openai_ws.scheduler_score_weights.ttft", 0.5)
+	viper.SetDefault("gateway.openai_ws.scheduler_score_weights.headroom", 1.0)
+	viper.SetDefault("gateway.openai_usage_window.enabled", true)
+	viper.SetDefault("gateway.openai_usage_window.lookahead_seconds", 600)
+	viper.SetDefault("gateway.openai_usage_window.stop_threshold_percent", 100.0)
+	viper.SetDefault("gateway.openai_usage_window.five_hour_token_limit", 0)
+	viper.SetDefault("gateway.openai_usage_window.weekly_token_limit", 0)
 	viper.SetDefault("gateway.antigravity_fallback_cooldown_minutes", 1)
 	viper.SetDefault("gateway.antigravity_extra_retries", 10)
 	viper.SetDefault("gateway.max_body_size", int64(256*1024*1024))
+	viper.SetDefault("gateway.audio_max_body_size", int64(25*1024*1024))
+	viper.SetDefault("gateway.multipart_max_memory", int64(8*1024*1024))
 	viper.SetDefault("gateway.upstream_response_read_max_bytes", int64(8*1024*1024))
 	viper.SetDefault("gateway.proxy_probe_response_read_max_bytes", int64(1024*1024))
 	viper.SetDefault("gateway.gemini_debug_response_headers", false)
+	viper.SetDefault("gateway.upstream_request_id_header", "X-Request-Id")
+	viper.SetDefault("gateway.compression.enabled", true)
+	viper.SetDefault("gateway.compression.stream", false)
+	viper.SetDefault("gateway.compression.min_bytes", 1024)
+	viper.SetDefault("gateway.compression.algorithms", []string{"br", "gzip"})
 	viper.SetDefault("gateway.sora_max_body_size", int64(256*1024*1024))
 	viper.SetDefault("gateway.sora_stream_timeout_seconds", 900)
 	viper.SetDefault("gateway.sora_request_timeout_seconds", 180)
@@ -1399,16 +2755,54 @@ func setDefaults() {
 	viper.SetDefault("gateway.idle_conn_timeout_seconds", 90) // 空闲连接超时（秒）
 	viper.SetDefault("gateway.max_upstream_clients", 5000)
 	viper.SetDefault("gateway.client_idle_ttl_seconds", 900)
+	viper.SetDefault("gateway.dial_timeout_seconds", 10)
+	viper.SetDefault("gateway.tls_handshake_timeout_seconds", 10)
+	viper.SetDefault("gateway.tls_session_cache_size", 64)
+	viper.SetDefault("gateway.disable_upstream_http2", false)
 	viper.SetDefault("gateway.concurrency_slot_ttl_minutes", 30) // 并发槽位过期时间（支持超长请求）
 	viper.SetDefault("gateway.stream_data_interval_timeout", 180)
 	viper.SetDefault("gateway.stream_keepalive_interval", 10)
+	viper.SetDefault("gateway.stream_resume_max_attempts", 1)
+	viper.SetDefault("gateway.session_affinity_header", "X-Session-Affinity")
+	viper.SetDefault("gateway.sticky_session_ttl_seconds", 3600)
 	viper.SetDefault("gateway.max_line_size", 500*1024*1024)
+	viper.SetDefault("gateway.simulation.enabled", false)
+	viper.SetDefault("gateway.simulation.response_mode", "canned")
+	viper.SetDefault("gateway.simulation.canned_text", "This is a simulated response from the sub2api dry-run upstream.")
+	viper.SetDefault("gateway.simulation.output_tokens", 0)
+	viper.SetDefault("gateway.simulation.first_token_latency_ms", 300)
+	viper.SetDefault("gateway.simulation.chunk_interval_ms", 30)
+	viper.SetDefault("gateway.upstream_retry.enabled", false)
+	viper.SetDefault("gateway.upstream_retry.max_attempts", 3)
+	viper.SetDefault("gateway.upstream_retry.base_delay_ms", 200)
+	viper.SetDefault("gateway.upstream_retry.max_delay_ms", 2000)
+	viper.SetDefault("gateway.upstream_retry.retry_status_codes", []int{502, 503})
+	viper.SetDefault("gateway.upstream_retry.retry_429_max_retry_after_seconds", 0)
+	viper.SetDefault("gateway.upstream_retry.budget_ratio", 0.1)
+	viper.SetDefault("gateway.upstream_retry.budget_min_per_second", 5)
+	viper.SetDefault("gateway.account_circuit_breaker.enabled", false)
+	viper.SetDefault("gateway.account_circuit_breaker.window_seconds", 60)
+	viper.SetDefault("gateway.account_circuit_breaker.min_requests", 20)
+	viper.SetDefault("gateway.account_circuit_breaker.error_rate_threshold", 0.5)
+	viper.SetDefault("gateway.account_circuit_breaker.slow_call_ms", 0)
+	viper.SetDefault("gateway.account_circuit_breaker.slow_call_rate_threshold", 0.8)
+	viper.SetDefault("gateway.account_circuit_breaker.open_seconds", 30)
+	viper.SetDefault("gateway.account_circuit_breaker.half_open_successes", 3)
+	viper.SetDefault("gateway.priority_queue.enabled", false)
+	viper.SetDefault("gateway.priority_queue.max_in_flight_per_group", 64)
+	viper.SetDefault("gateway.priority_queue.max_queue_per_group", 256)
+	viper.SetDefault("gateway.priority_queue.max_wait_seconds", 30)
+	viper.SetDefault("gateway.priority_queue.retry_after_seconds", 5)
 	viper.SetDefault("gateway.scheduling.sticky_session_max_waiting", 3)
 	viper.SetDefault("gateway.scheduling.sticky_session_wait_timeout", 120*time.Second)
 	viper.SetDefault("gateway.scheduling.fallback_wait_timeout", 30*time.Second)
 	viper.SetDefault("gateway.scheduling.fallback_max_waiting", 100)
 	viper.SetDefault("gateway.scheduling.fallback_selection_mode", "last_used")
 	viper.SetDefault("gateway.scheduling.load_batch_enabled", true)
+	viper.SetDefault("gateway.scheduling.priority_overflow_load_percent", 0)
+	viper.SetDefault("gateway.scheduling.latency_aware_enabled", false)
+	viper.SetDefault("gateway.scheduling.latency_aware_min_samples", 20)
+	viper.SetDefault("gateway.scheduling.priority_recover_load_percent", 0)
 	viper.SetDefault("gateway.scheduling.slot_cleanup_interval", 30*time.Second)
 	viper.SetDefault("gateway.scheduling.db_fallback_enabled", true)
 	viper.SetDefault("gateway.scheduling.db_fallback_timeout_seconds", 0)
@@ -1485,6 +2879,7 @@ func setDefaults() {
 	viper.SetDefault("token_refresh.refresh_before_expiry_hours", 0.5) // 提前30分钟刷新（适配Google 1小时token）
 	viper.SetDefault("token_refresh.max_retries", 3)                   // 最多重试3次
 	viper.SetDefault("token_refresh.retry_backoff_seconds", 2)         // 重试退避基础2秒
+	viper.SetDefault("token_refresh.jitter_seconds", 120)              // 提前刷新抖动最多2分钟
 	viper.SetDefault("token_refresh.sync_linked_sora_accounts", false) // 默认不跨平台覆盖 Sora token
 
 	// Gemini OAuth - configure via environment variables or config file
@@ -1511,6 +2906,17 @@ func (c *Config) Validate() error {
 	if len([]byte(jwtSecret)) < 32 {
 		return fmt.Errorf("jwt.secret must be at least 32 bytes")
 	}
+	for _, origin := range c.CORS.AllowedOrigins {
+		if origin == "*" || !strings.Contains(origin, "*") {
+			continue
+		}
+		if _, host, ok := strings.Cut(origin, "://*."); !ok || host == "" || strings.Contains(host, "*") {
+			return fmt.Errorf("cors.allowed_origins %q: wildcard is only supported as scheme://*.domain", origin)
+		}
+	}
+	if c.CORS.MaxAgeSeconds < 0 {
+		return fmt.Errorf("cors.max_age_seconds must be non-negative")
+	}
 	switch c.Log.Level {
 	case "debug", "info", "warn", "error":
 	case "":
@@ -1559,6 +2965,23 @@ func (c *Config) Validate() error {
 			return fmt.Errorf("log.sampling.thereafter must be non-negative")
 		}
 	}
+	if c.Log.Access.BodyMaxBytes < 0 {
+		return fmt.Errorf("log.access.body_max_bytes must be non-negative")
+	}
+	if len(c.Log.Access.BodyAPIKeyIDs) > 0 && c.Log.Access.BodyMaxBytes <= 0 {
+		return fmt.Errorf("log.access.body_max_bytes must be positive when log.access.body_api_key_ids is set")
+	}
+	for i, override := range c.Pricing.Overrides {
+		model := strings.TrimSpace(override.Model)
+		if model == "" || strings.Contains(strings.TrimSuffix(model, "*"), "*") {
+			return fmt.Errorf("pricing.overrides[%d].model must be a model name with an optional trailing *", i)
+		}
+		for _, price := range []*float64{override.InputPerMTok, override.OutputPerMTok, override.CacheCreationPerMTok, override.CacheCreation1hPerMTok, override.CacheReadPerMTok} {
+			if price != nil && *price < 0 {
+				return fmt.Errorf("pricing.overrides[%d] prices must be non-negative", i)
+			}
+		}
+	}
 
 	if c.SubscriptionMaintenance.WorkerCount < 0 {
 		return fmt.Errorf("subscription_maintenance.worker_count must be non-negative")
@@ -1591,6 +3014,42 @@ func (c *Config) Validate() error {
 		}
 		warnIfInsecureURL("server.frontend_url", c.Server.FrontendURL)
 	}
+	if c.Server.ShutdownDrainSeconds <= 0 {
+		return fmt.Errorf("server.shutdown_drain_seconds must be positive")
+	}
+	if c.Server.ShutdownUnreadySeconds < 0 {
+		return fmt.Errorf("server.shutdown_unready_seconds must be non-negative")
+	}
+	if strings.TrimSpace(c.Server.UnixSocket) != "" {
+		if _, err := c.Server.UnixSocketFileMode(); err != nil {
+			return err
+		}
+	}
+	if tlsCfg := c.Server.TLS; tlsCfg.Enabled {
+		if tlsCfg.ACME.Enabled {
+			if strings.TrimSpace(tlsCfg.CertFile) != "" || strings.TrimSpace(tlsCfg.KeyFile) != "" {
+				return fmt.Errorf("server.tls.cert_file/key_file cannot be combined with server.tls.acme")
+			}
+			if len(tlsCfg.ACME.Domains) == 0 {
+				return fmt.Errorf("server.tls.acme.domains is required when acme is enabled")
+			}
+			for _, domain := range tlsCfg.ACME.Domains {
+				if strings.TrimSpace(domain) == "" || strings.ContainsAny(domain, "/:* ") {
+					return fmt.Errorf("server.tls.acme.domains contains invalid domain %q", domain)
+				}
+			}
+			if strings.TrimSpace(tlsCfg.ACME.CacheDir) == "" {
+				return fmt.Errorf("server.tls.acme.cache_dir is required when acme is enabled")
+			}
+		} else {
+			if strings.TrimSpace(tlsCfg.CertFile) == "" || strings.TrimSpace(tlsCfg.KeyFile) == "" {
+				return fmt.Errorf("server.tls.cert_file and server.tls.key_file are required unless server.tls.acme is enabled")
+			}
+			if tlsCfg.ReloadCheckSeconds < 0 {
+				return fmt.Errorf("server.tls.reload_check_seconds must be non-negative")
+			}
+		}
+	}
 	if c.JWT.ExpireHour <= 0 {
 		return fmt.Errorf("jwt.expire_hour must be positive")
 	}
@@ -1674,6 +3133,85 @@ func (c *Config) Validate() error {
 		warnIfInsecureURL("linuxdo_connect.redirect_url", c.LinuxDo.RedirectURL)
 		warnIfInsecureURL("linuxdo_connect.frontend_redirect_url", c.LinuxDo.FrontendRedirectURL)
 	}
+	if c.OIDC.Enabled {
+		if strings.TrimSpace(c.OIDC.IssuerURL) == "" {
+			return fmt.Errorf("oidc.issuer_url is required when oidc.enabled=true")
+		}
+		if strings.TrimSpace(c.OIDC.ClientID) == "" {
+			return fmt.Errorf("oidc.client_id is required when oidc.enabled=true")
+		}
+		if strings.TrimSpace(c.OIDC.RedirectURL) == "" {
+			return fmt.Errorf("oidc.redirect_url is required when oidc.enabled=true")
+		}
+		if strings.TrimSpace(c.OIDC.FrontendRedirectURL) == "" {
+			return fmt.Errorf("oidc.frontend_redirect_url is required when oidc.enabled=true")
+		}
+		method := strings.ToLower(strings.TrimSpace(c.OIDC.TokenAuthMethod))
+		switch method {
+		case "", "client_secret_post", "client_secret_basic", "none":
+		default:
+			return fmt.Errorf("oidc.token_auth_method must be one of: client_secret_basic/client_secret_post/none")
+		}
+		if method == "none" && !c.OIDC.UsePKCE {
+			return fmt.Errorf("oidc.use_pkce must be true when oidc.token_auth_method=none")
+		}
+		if method != "none" && strings.TrimSpace(c.OIDC.ClientSecret) == "" {
+			return fmt.Errorf("oidc.client_secret is required when oidc.token_auth_method is client_secret_basic/client_secret_post")
+		}
+		if len(c.OIDC.RoleMappings) == 0 && strings.TrimSpace(c.OIDC.DefaultRole) == "" {
+			return fmt.Errorf("oidc.role_mappings or oidc.default_role is required when oidc.enabled=true")
+		}
+		for i, m := range c.OIDC.RoleMappings {
+			if strings.TrimSpace(m.Group) == "" {
+				return fmt.Errorf("oidc.role_mappings[%d].group is required", i)
+			}
+			if !isOIDCAdminRole(m.Role) {
+				return fmt.Errorf("oidc.role_mappings[%d].role must be one of: viewer/operator/admin", i)
+			}
+		}
+		if c.OIDC.DefaultRole != "" && !isOIDCAdminRole(c.OIDC.DefaultRole) {
+			return fmt.Errorf("oidc.default_role must be empty or one of: viewer/operator/admin")
+		}
+
+		if err := ValidateAbsoluteHTTPURL(c.OIDC.IssuerURL); err != nil {
+			return fmt.Errorf("oidc.issuer_url invalid: %w", err)
+		}
+		if err := ValidateAbsoluteHTTPURL(c.OIDC.RedirectURL); err != nil {
+			return fmt.Errorf("oidc.redirect_url invalid: %w", err)
+		}
+		if err := ValidateFrontendRedirectURL(c.OIDC.FrontendRedirectURL); err != nil {
+			return fmt.Errorf("oidc.frontend_redirect_url invalid: %w", err)
+		}
+
+		warnIfInsecureURL("oidc.issuer_url", c.OIDC.IssuerURL)
+		warnIfInsecureURL("oidc.redirect_url", c.OIDC.RedirectURL)
+		warnIfInsecureURL("oidc.frontend_redirect_url", c.OIDC.FrontendRedirectURL)
+	}
+	if strings.TrimSpace(c.APIKeyBudget.WebhookURL) != "" {
+		if err := ValidateAbsoluteHTTPURL(c.APIKeyBudget.WebhookURL); err != nil {
+			return fmt.Errorf("api_key_budget.webhook_url invalid: %w", err)
+		}
+		if c.APIKeyBudget.WebhookTimeoutSeconds <= 0 {
+			return fmt.Errorf("api_key_budget.webhook_timeout_seconds must be positive")
+		}
+		warnIfInsecureURL("api_key_budget.webhook_url", c.APIKeyBudget.WebhookURL)
+	}
+	if c.AlertWebhooks.Enabled {
+		if err := validateAlertWebhooks(&c.AlertWebhooks); err != nil {
+			return err
+		}
+	}
+	if c.UsageWebhook.Enabled {
+		if err := validateUsageWebhook(&c.UsageWebhook); err != nil {
+			return err
+		}
+	}
+	if c.SharedState.Enabled && c.SharedState.SyncIntervalSeconds <= 0 {
+		return fmt.Errorf("shared_state.sync_interval_seconds must be positive")
+	}
+	if c.Cluster.Enabled && c.Cluster.LeaderLeaseSeconds < 3 {
+		return fmt.Errorf("cluster.leader_lease_seconds must be at least 3")
+	}
 	if c.Billing.CircuitBreaker.Enabled {
 		if c.Billing.CircuitBreaker.FailureThreshold <= 0 {
 			return fmt.Errorf("billing.circuit_breaker.failure_threshold must be positive")
@@ -1817,6 +3355,187 @@ func (c *Config) Validate() error {
 			return fmt.Errorf("usage_cleanup.task_timeout_seconds must be non-negative")
 		}
 	}
+	if c.Batch.Enabled {
+		if c.Batch.WorkerIntervalSeconds <= 0 {
+			return fmt.Errorf("batch.worker_interval_seconds must be positive")
+		}
+		if c.Batch.WorkerConcurrency <= 0 {
+			return fmt.Errorf("batch.worker_concurrency must be positive")
+		}
+		if c.Batch.PerJobConcurrency <= 0 {
+			return fmt.Errorf("batch.per_job_concurrency must be positive")
+		}
+		if c.Batch.MaxRequestsPerBatch <= 0 {
+			return fmt.Errorf("batch.max_requests_per_batch must be positive")
+		}
+		if c.Batch.MaxFileSize <= 0 {
+			return fmt.Errorf("batch.max_file_size must be positive")
+		}
+		if c.Batch.MaxAttempts <= 0 {
+			return fmt.Errorf("batch.max_attempts must be positive")
+		}
+		if c.Batch.RequestTimeoutSeconds <= 0 {
+			return fmt.Errorf("batch.request_timeout_seconds must be positive")
+		}
+		if c.Batch.RetentionHours <= 0 {
+			return fmt.Errorf("batch.retention_hours must be positive")
+		}
+	}
+	if c.Batch.MaxActiveJobsPerKey < 0 {
+		return fmt.Errorf("batch.max_active_jobs_per_key must be non-negative")
+	}
+	if c.AccountHealth.Enabled {
+		if c.AccountHealth.FailureThreshold <= 0 {
+			return fmt.Errorf("account_health.failure_threshold must be positive")
+		}
+		if c.AccountHealth.ProbeIntervalSeconds <= 0 {
+			return fmt.Errorf("account_health.probe_interval_seconds must be positive")
+		}
+		if c.AccountHealth.ProbeBackoffBaseSeconds <= 0 {
+			return fmt.Errorf("account_health.probe_backoff_base_seconds must be positive")
+		}
+		if c.AccountHealth.ProbeBackoffMaxSeconds < c.AccountHealth.ProbeBackoffBaseSeconds {
+			return fmt.Errorf("account_health.probe_backoff_max_seconds must be >= probe_backoff_base_seconds")
+		}
+	}
+	if c.AccountWarmup.Enabled {
+		if c.AccountWarmup.IntervalHours <= 0 {
+			return fmt.Errorf("account_warmup.interval_hours must be positive")
+		}
+		if c.AccountWarmup.CheckIntervalMinutes <= 0 {
+			return fmt.Errorf("account_warmup.check_interval_minutes must be positive")
+		}
+		if c.AccountWarmup.MaxConcurrency <= 0 {
+			return fmt.Errorf("account_warmup.max_concurrency must be positive")
+		}
+	}
+	if c.CodexModelSync.Enabled {
+		if c.CodexModelSync.IntervalMinutes <= 0 {
+			return fmt.Errorf("codex_model_sync.interval_minutes must be positive")
+		}
+		if c.CodexModelSync.MaxConcurrency <= 0 {
+			return fmt.Errorf("codex_model_sync.max_concurrency must be positive")
+		}
+	}
+	if c.BackgroundResponses.Enabled {
+		if c.BackgroundResponses.WorkerIntervalSeconds <= 0 {
+			return fmt.Errorf("background_responses.worker_interval_seconds must be positive")
+		}
+		if c.BackgroundResponses.WorkerConcurrency <= 0 {
+			return fmt.Errorf("background_responses.worker_concurrency must be positive")
+		}
+		if c.BackgroundResponses.MaxAttempts <= 0 {
+			return fmt.Errorf("background_responses.max_attempts must be positive")
+		}
+		if c.BackgroundResponses.RequestTimeoutSeconds <= 0 {
+			return fmt.Errorf("background_responses.request_timeout_seconds must be positive")
+		}
+		if c.BackgroundResponses.RetentionHours <= 0 {
+			return fmt.Errorf("background_responses.retention_hours must be positive")
+		}
+	}
+	if c.BackgroundResponses.MaxActivePerKey < 0 {
+		return fmt.Errorf("background_responses.max_active_per_key must be non-negative")
+	}
+	if c.Health.CacheSeconds < 0 {
+		return fmt.Errorf("health.cache_seconds must be non-negative")
+	}
+	if c.Health.CheckTimeoutSeconds <= 0 {
+		return fmt.Errorf("health.check_timeout_seconds must be positive")
+	}
+	if c.Tracing.Enabled {
+		if u, err := url.Parse(strings.TrimSpace(c.Tracing.Endpoint)); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return fmt.Errorf("tracing.endpoint must be an absolute http(s) URL")
+		}
+		if c.Tracing.SampleRatio < 0 || c.Tracing.SampleRatio > 1 {
+			return fmt.Errorf("tracing.sample_ratio must be between 0 and 1")
+		}
+		if c.Tracing.QueueSize <= 0 {
+			return fmt.Errorf("tracing.queue_size must be positive")
+		}
+		if c.Tracing.BatchSize <= 0 {
+			return fmt.Errorf("tracing.batch_size must be positive")
+		}
+		if c.Tracing.ExportTimeoutSeconds <= 0 {
+			return fmt.Errorf("tracing.export_timeout_seconds must be positive")
+		}
+	}
+	if c.SSEReplay.Enabled {
+		if c.SSEReplay.BufferEvents <= 0 {
+			return fmt.Errorf("sse_replay.buffer_events must be positive")
+		}
+		if c.SSEReplay.RetentionSeconds <= 0 {
+			return fmt.Errorf("sse_replay.retention_seconds must be positive")
+		}
+		if c.SSEReplay.ReconnectGraceSeconds <= 0 {
+			return fmt.Errorf("sse_replay.reconnect_grace_seconds must be positive")
+		}
+	}
+	if c.ResponseCache.Enabled {
+		switch c.ResponseCache.Backend {
+		case "memory", "redis":
+		default:
+			return fmt.Errorf("response_cache.backend must be one of: memory/redis")
+		}
+		if c.ResponseCache.TTLSeconds <= 0 {
+			return fmt.Errorf("response_cache.ttl_seconds must be positive")
+		}
+		if c.ResponseCache.Backend == "memory" && c.ResponseCache.MaxEntries <= 0 {
+			return fmt.Errorf("response_cache.max_entries must be positive")
+		}
+		if c.ResponseCache.MaxBodyBytes <= 0 {
+			return fmt.Errorf("response_cache.max_body_bytes must be positive")
+		}
+	}
+	if c.ConversationStore.Enabled {
+		switch c.ConversationStore.Backend {
+		case "memory", "redis":
+		default:
+			return fmt.Errorf("conversation_store.backend must be one of: memory/redis")
+		}
+		if c.ConversationStore.TTLSeconds <= 0 {
+			return fmt.Errorf("conversation_store.ttl_seconds must be positive")
+		}
+		if c.ConversationStore.Backend == "memory" && c.ConversationStore.MaxEntries <= 0 {
+			return fmt.Errorf("conversation_store.max_entries must be positive")
+		}
+		if c.ConversationStore.MaxBytes <= 0 {
+			return fmt.Errorf("conversation_store.max_bytes must be positive")
+		}
+	}
+	if c.DeadLetter.Enabled {
+		if c.DeadLetter.MaxBodyBytes <= 0 {
+			return fmt.Errorf("dead_letter.max_body_bytes must be positive")
+		}
+		if c.DeadLetter.RetentionDays <= 0 {
+			return fmt.Errorf("dead_letter.retention_days must be positive")
+		}
+		if c.DeadLetter.ReplayConcurrency <= 0 {
+			return fmt.Errorf("dead_letter.replay_concurrency must be positive")
+		}
+	}
+	if c.ContentLog.Enabled {
+		if c.ContentLog.MaxBodyBytes <= 0 {
+			return fmt.Errorf("content_log.max_body_bytes must be positive")
+		}
+		if c.ContentLog.RetentionDays <= 0 {
+			return fmt.Errorf("content_log.retention_days must be positive")
+		}
+	}
+	if c.Files.Enabled {
+		if c.Files.MaxFileSize <= 0 {
+			return fmt.Errorf("files.max_file_size must be positive")
+		}
+		if c.Files.CleanupIntervalMinutes <= 0 {
+			return fmt.Errorf("files.cleanup_interval_minutes must be positive")
+		}
+	}
+	if c.Files.MaxFilesPerUser < 0 {
+		return fmt.Errorf("files.max_files_per_user must be non-negative")
+	}
+	if c.Files.RetentionDays < 0 {
+		return fmt.Errorf("files.retention_days must be non-negative")
+	}
 	if c.Idempotency.DefaultTTLSeconds <= 0 {
 		return fmt.Errorf("idempotency.default_ttl_seconds must be positive")
 	}
@@ -1838,9 +3557,26 @@ func (c *Config) Validate() error {
 	if c.Idempotency.CleanupBatchSize <= 0 {
 		return fmt.Errorf("idempotency.cleanup_batch_size must be positive")
 	}
+	if c.Idempotency.Gateway.Enabled {
+		if c.Idempotency.Gateway.TTLSeconds <= 0 {
+			return fmt.Errorf("idempotency.gateway.ttl_seconds must be positive")
+		}
+		if c.Idempotency.Gateway.MaxResponseBytes <= 0 {
+			return fmt.Errorf("idempotency.gateway.max_response_bytes must be positive")
+		}
+	}
 	if c.Gateway.MaxBodySize <= 0 {
 		return fmt.Errorf("gateway.max_body_size must be positive")
 	}
+	if strings.ContainsAny(c.Gateway.UpstreamRequestIDHeader, " \t\r\n:") {
+		return fmt.Errorf("gateway.upstream_request_id_header must be a valid header name")
+	}
+	if c.Gateway.AudioMaxBodySize < 0 {
+		return fmt.Errorf("gateway.audio_max_body_size must be non-negative")
+	}
+	if c.Gateway.MultipartMaxMemory <= 0 {
+		return fmt.Errorf("gateway.multipart_max_memory must be positive")
+	}
 	if c.Gateway.UpstreamResponseReadMaxBytes <= 0 {
 		return fmt.Errorf("gateway.upstream_response_read_max_bytes must be positive")
 	}
@@ -1935,6 +3671,209 @@ func (c *Config) Validate() error {
 				ConnectionPoolIsolationProxy, ConnectionPoolIsolationAccount, ConnectionPoolIsolationAccountProxy)
 		}
 	}
+	if c.Gateway.CodexCLIUserAgentPrefixesOverride && len(c.Gateway.CodexCLIUserAgentPrefixes) == 0 {
+		return fmt.Errorf("gateway.codex_cli_user_agent_prefixes must not be empty when gateway.codex_cli_user_agent_prefixes_override is true")
+	}
+	if c.Gateway.UpstreamUserAgent.Enabled && strings.TrimSpace(c.Gateway.UpstreamUserAgent.Template) == "" {
+		return fmt.Errorf("gateway.upstream_user_agent.template must not be empty when gateway.upstream_user_agent.enabled is true")
+	}
+	if strings.Contains(c.Gateway.UpstreamUserAgent.Template, "{version}") && strings.TrimSpace(c.Gateway.UpstreamUserAgent.Version) == "" {
+		return fmt.Errorf("gateway.upstream_user_agent.version must not be empty when template uses {version}")
+	}
+	if c.Gateway.UpstreamUserAgent.AutoUpdate {
+		if c.Gateway.UpstreamUserAgent.AutoUpdateIntervalMinutes <= 0 {
+			return fmt.Errorf("gateway.upstream_user_agent.auto_update_interval_minutes must be positive")
+		}
+		if strings.Count(strings.TrimSpace(c.Gateway.UpstreamUserAgent.ReleaseRepo), "/") != 1 {
+			return fmt.Errorf("gateway.upstream_user_agent.release_repo must be in owner/name form")
+		}
+	}
+	for i, rule := range c.Gateway.ReasoningDefaults {
+		switch rule.Effort {
+		case "low", "medium", "high", "xhigh":
+		default:
+			return fmt.Errorf("gateway.reasoning_defaults[%d].effort must be one of: low/medium/high/xhigh", i)
+		}
+		for _, client := range rule.Clients {
+			switch client {
+			case "codex", "claude_code", "coding_agent", "api":
+			default:
+				return fmt.Errorf("gateway.reasoning_defaults[%d].clients must be codex/claude_code/coding_agent/api, got %q", i, client)
+			}
+		}
+		for _, model := range rule.Models {
+			if strings.Contains(strings.TrimSuffix(model, "*"), "*") {
+				return fmt.Errorf("gateway.reasoning_defaults[%d].models: only a trailing * wildcard is supported, got %q", i, model)
+			}
+		}
+	}
+	for i, rule := range c.Gateway.ParamSanitize.Rules {
+		if rule.MaxOutputTokens < 0 {
+			return fmt.Errorf("gateway.param_sanitize.rules[%d].max_output_tokens must be non-negative", i)
+		}
+		for _, model := range rule.Models {
+			if strings.Contains(strings.TrimSuffix(model, "*"), "*") {
+				return fmt.Errorf("gateway.param_sanitize.rules[%d].models: only a trailing * wildcard is supported, got %q", i, model)
+			}
+		}
+		for _, param := range rule.DropParams {
+			switch param {
+			case "model", "messages", "input", "contents", "stream":
+				return fmt.Errorf("gateway.param_sanitize.rules[%d].drop_params must not include %q", i, param)
+			}
+		}
+	}
+	if err := validateContextOverflowStrategy(c.Gateway.ContextOverflow.Strategy, false); err != nil {
+		return fmt.Errorf("gateway.context_overflow.strategy %w", err)
+	}
+	if c.Gateway.ContextOverflow.SafetyMarginPercent < 0 || c.Gateway.ContextOverflow.SafetyMarginPercent > 50 {
+		return fmt.Errorf("gateway.context_overflow.safety_margin_percent must be between 0 and 50")
+	}
+	if c.Gateway.ContextOverflow.CompactMaxTokens <= 0 {
+		return fmt.Errorf("gateway.context_overflow.compact_max_tokens must be positive")
+	}
+	for i, rule := range c.Gateway.ContextOverflow.Rules {
+		if rule.ContextWindow < 0 {
+			return fmt.Errorf("gateway.context_overflow.rules[%d].context_window must be non-negative", i)
+		}
+		if err := validateContextOverflowStrategy(rule.Strategy, true); err != nil {
+			return fmt.Errorf("gateway.context_overflow.rules[%d].strategy %w", i, err)
+		}
+		for _, model := range rule.Models {
+			if strings.Contains(strings.TrimSuffix(model, "*"), "*") {
+				return fmt.Errorf("gateway.context_overflow.rules[%d].models: only a trailing * wildcard is supported, got %q", i, model)
+			}
+		}
+	}
+	if c.Gateway.ChatMultiChoice.Enabled {
+		if c.Gateway.ChatMultiChoice.MaxN < 2 || c.Gateway.ChatMultiChoice.MaxN > 128 {
+			return fmt.Errorf("gateway.chat_multi_choice.max_n must be between 2 and 128")
+		}
+		if c.Gateway.ChatMultiChoice.MaxParallel <= 0 {
+			return fmt.Errorf("gateway.chat_multi_choice.max_parallel must be positive")
+		}
+	}
+	if c.Gateway.OpenAISessionHeaders.RotationHours < 0 {
+		return fmt.Errorf("gateway.openai_session_headers.rotation_hours must be non-negative")
+	}
+	for i, rule := range c.Gateway.OpenAIPlanRestrictions {
+		if len(rule.Models) == 0 {
+			return fmt.Errorf("gateway.openai_plan_restrictions[%d].models must not be empty", i)
+		}
+		if len(rule.Plans) == 0 {
+			return fmt.Errorf("gateway.openai_plan_restrictions[%d].plans must not be empty", i)
+		}
+		for _, model := range rule.Models {
+			if strings.Contains(strings.TrimSuffix(model, "*"), "*") {
+				return fmt.Errorf("gateway.openai_plan_restrictions[%d].models: only a trailing * wildcard is supported, got %q", i, model)
+			}
+		}
+		for _, effort := range rule.ReasoningEfforts {
+			switch effort {
+			case "low", "medium", "high", "xhigh":
+			default:
+				return fmt.Errorf("gateway.openai_plan_restrictions[%d].reasoning_efforts must be low/medium/high/xhigh, got %q", i, effort)
+			}
+		}
+	}
+	for i, rule := range c.Gateway.SystemPrompts {
+		switch rule.Mode {
+		case "prepend", "append", "replace":
+		default:
+			return fmt.Errorf("gateway.system_prompts[%d].mode must be one of: prepend/append/replace", i)
+		}
+		if strings.TrimSpace(rule.Prompt) == "" {
+			return fmt.Errorf("gateway.system_prompts[%d].prompt must not be empty", i)
+		}
+		for _, path := range rule.Paths {
+			if !strings.HasPrefix(path, "/") {
+				return fmt.Errorf("gateway.system_prompts[%d].paths must start with /, got %q", i, path)
+			}
+		}
+		for _, model := range rule.Models {
+			if strings.Contains(strings.TrimSuffix(model, "*"), "*") {
+				return fmt.Errorf("gateway.system_prompts[%d].models: only a trailing * wildcard is supported, got %q", i, model)
+			}
+		}
+	}
+	switch c.Gateway.Simulation.ResponseMode {
+	case "", "canned", "echo":
+	default:
+		return fmt.Errorf("gateway.simulation.response_mode must be one of: canned/echo")
+	}
+	if sim := c.Gateway.Simulation; sim.OutputTokens < 0 || sim.FirstTokenLatencyMS < 0 || sim.ChunkIntervalMS < 0 {
+		return fmt.Errorf("gateway.simulation.output_tokens, first_token_latency_ms and chunk_interval_ms must be non-negative")
+	}
+	if retry := c.Gateway.UpstreamRetry; retry.Enabled {
+		if retry.MaxAttempts < 1 || retry.MaxAttempts > 10 {
+			return fmt.Errorf("gateway.upstream_retry.max_attempts must be between 1-10")
+		}
+		if retry.BaseDelayMS <= 0 {
+			return fmt.Errorf("gateway.upstream_retry.base_delay_ms must be positive")
+		}
+		if retry.MaxDelayMS < retry.BaseDelayMS {
+			return fmt.Errorf("gateway.upstream_retry.max_delay_ms must be >= base_delay_ms")
+		}
+		for _, code := range retry.RetryStatusCodes {
+			if code < 500 || code > 599 {
+				return fmt.Errorf("gateway.upstream_retry.retry_status_codes must be 5xx status codes, got %d", code)
+			}
+		}
+		if retry.Retry429MaxRetryAfterSeconds < 0 {
+			return fmt.Errorf("gateway.upstream_retry.retry_429_max_retry_after_seconds must be non-negative")
+		}
+		if retry.BudgetRatio < 0 || retry.BudgetRatio > 1 {
+			return fmt.Errorf("gateway.upstream_retry.budget_ratio must be within [0,1]")
+		}
+		if retry.BudgetMinPerSecond < 0 {
+			return fmt.Errorf("gateway.upstream_retry.budget_min_per_second must be non-negative")
+		}
+	}
+	if breaker := c.Gateway.AccountCircuitBreaker; breaker.Enabled {
+		if breaker.WindowSeconds <= 0 {
+			return fmt.Errorf("gateway.account_circuit_breaker.window_seconds must be positive")
+		}
+		if breaker.MinRequests <= 0 {
+			return fmt.Errorf("gateway.account_circuit_breaker.min_requests must be positive")
+		}
+		if breaker.ErrorRateThreshold <= 0 || breaker.ErrorRateThreshold > 1 {
+			return fmt.Errorf("gateway.account_circuit_breaker.error_rate_threshold must be within (0,1]")
+		}
+		if breaker.SlowCallMS < 0 {
+			return fmt.Errorf("gateway.account_circuit_breaker.slow_call_ms must be non-negative")
+		}
+		if breaker.SlowCallMS > 0 && (breaker.SlowCallRateThreshold <= 0 || breaker.SlowCallRateThreshold > 1) {
+			return fmt.Errorf("gateway.account_circuit_breaker.slow_call_rate_threshold must be within (0,1]")
+		}
+		if breaker.OpenSeconds <= 0 {
+			return fmt.Errorf("gateway.account_circuit_breaker.open_seconds must be positive")
+		}
+		if breaker.HalfOpenSuccesses <= 0 {
+			return fmt.Errorf("gateway.account_circuit_breaker.half_open_successes must be positive")
+		}
+	}
+	if queue := c.Gateway.PriorityQueue; queue.Enabled {
+		if queue.MaxInFlightPerGroup <= 0 {
+			return fmt.Errorf("gateway.priority_queue.max_in_flight_per_group must be positive")
+		}
+		if queue.MaxQueuePerGroup < 0 {
+			return fmt.Errorf("gateway.priority_queue.max_queue_per_group must be non-negative")
+		}
+		if queue.MaxWaitSeconds <= 0 {
+			return fmt.Errorf("gateway.priority_queue.max_wait_seconds must be positive")
+		}
+		if queue.RetryAfterSeconds <= 0 {
+			return fmt.Errorf("gateway.priority_queue.retry_after_seconds must be positive")
+		}
+		for i, class := range queue.Classes {
+			if strings.TrimSpace(class.Name) == "" {
+				return fmt.Errorf("gateway.priority_queue.classes[%d].name is required", i)
+			}
+			if len(class.KeyTags) == 0 {
+				return fmt.Errorf("gateway.priority_queue.classes[%d].key_tags is required", i)
+			}
+		}
+	}
 	if c.Gateway.MaxIdleConns <= 0 {
 		return fmt.Errorf("gateway.max_idle_conns must be positive")
 	}
@@ -1956,6 +3895,15 @@ func (c *Config) Validate() error {
 	if c.Gateway.ClientIdleTTLSeconds <= 0 {
 		return fmt.Errorf("gateway.client_idle_ttl_seconds must be positive")
 	}
+	if c.Gateway.DialTimeoutSeconds <= 0 {
+		return fmt.Errorf("gateway.dial_timeout_seconds must be positive")
+	}
+	if c.Gateway.TLSHandshakeTimeoutSeconds <= 0 {
+		return fmt.Errorf("gateway.tls_handshake_timeout_seconds must be positive")
+	}
+	if c.Gateway.TLSSessionCacheSize < 0 {
+		return fmt.Errorf("gateway.tls_session_cache_size must be non-negative")
+	}
 	if c.Gateway.ConcurrencySlotTTLMinutes <= 0 {
 		return fmt.Errorf("gateway.concurrency_slot_ttl_minutes must be positive")
 	}
@@ -1973,6 +3921,39 @@ func (c *Config) Validate() error {
 		(c.Gateway.StreamKeepaliveInterval < 5 || c.Gateway.StreamKeepaliveInterval > 30) {
 		return fmt.Errorf("gateway.stream_keepalive_interval must be 0 or between 5-30 seconds")
 	}
+	if c.Gateway.StreamResumeMaxAttempts < 0 {
+		return fmt.Errorf("gateway.stream_resume_max_attempts must be non-negative")
+	}
+	if c.Gateway.StickySessionTTLSeconds < 0 {
+		return fmt.Errorf("gateway.sticky_session_ttl_seconds must be non-negative")
+	}
+	for prefix, rt := range c.Gateway.RouteTimeouts {
+		if !strings.HasPrefix(prefix, "/") {
+			return fmt.Errorf("gateway.route_timeouts key %q must be a path prefix starting with /", prefix)
+		}
+		if rt.TTFBTimeoutSeconds < 0 || rt.TotalTimeoutSeconds < 0 || rt.FlushIntervalMs < 0 || rt.WriteBufferBytes < 0 {
+			return fmt.Errorf("gateway.route_timeouts[%s] values must be non-negative", prefix)
+		}
+		if rt.TotalTimeoutSeconds > 0 && rt.TTFBTimeoutSeconds > rt.TotalTimeoutSeconds {
+			return fmt.Errorf("gateway.route_timeouts[%s].ttfb_timeout_seconds must not exceed total_timeout_seconds", prefix)
+		}
+		if rt.WriteBufferBytes > 1<<20 {
+			return fmt.Errorf("gateway.route_timeouts[%s].write_buffer_bytes must be at most 1MB", prefix)
+		}
+	}
+	if c.Gateway.Compression.MinBytes < 0 {
+		return fmt.Errorf("gateway.compression.min_bytes must be non-negative")
+	}
+	for _, algorithm := range c.Gateway.Compression.Algorithms {
+		if algorithm != "br" && algorithm != "gzip" {
+			return fmt.Errorf("gateway.compression.algorithms: unsupported algorithm %q (use br or gzip)", algorithm)
+		}
+	}
+	for prefix := range c.Gateway.Compression.Routes {
+		if !strings.HasPrefix(prefix, "/") {
+			return fmt.Errorf("gateway.compression.routes key %q must be a path prefix starting with /", prefix)
+		}
+	}
 	// 兼容旧键 sticky_previous_response_ttl_seconds
 	if c.Gateway.OpenAIWS.StickyResponseIDTTLSeconds <= 0 && c.Gateway.OpenAIWS.StickyPreviousResponseTTLSeconds > 0 {
 		c.Gateway.OpenAIWS.StickyResponseIDTTLSeconds = c.Gateway.OpenAIWS.StickyPreviousResponseTTLSeconds
@@ -2076,20 +4057,112 @@ func (c *Config) Validate() error {
 		c.Gateway.OpenAIWS.SchedulerScoreWeights.Load < 0 ||
 		c.Gateway.OpenAIWS.SchedulerScoreWeights.Queue < 0 ||
 		c.Gateway.OpenAIWS.SchedulerScoreWeights.ErrorRate < 0 ||
-		c.Gateway.OpenAIWS.SchedulerScoreWeights.TTFT < 0 {
+		c.Gateway.OpenAIWS.SchedulerScoreWeights.TTFT < 0 ||
+		c.Gateway.OpenAIWS.SchedulerScoreWeights.Headroom < 0 {
 		return fmt.Errorf("gateway.openai_ws.scheduler_score_weights.* must be non-negative")
 	}
 	weightSum := c.Gateway.OpenAIWS.SchedulerScoreWeights.Priority +
 		c.Gateway.OpenAIWS.SchedulerScoreWeights.Load +
 		c.Gateway.OpenAIWS.SchedulerScoreWeights.Queue +
 		c.Gateway.OpenAIWS.SchedulerScoreWeights.ErrorRate +
-		c.Gateway.OpenAIWS.SchedulerScoreWeights.TTFT
+		c.Gateway.OpenAIWS.SchedulerScoreWeights.TTFT +
+		c.Gateway.OpenAIWS.SchedulerScoreWeights.Headroom
 	if weightSum <= 0 {
 		return fmt.Errorf("gateway.openai_ws.scheduler_score_weights must not all be zero")
 	}
+	if c.Gateway.OpenAIUsageWindow.Enabled {
+		if c.Gateway.OpenAIUsageWindow.LookaheadSeconds < 0 {
+			return fmt.Errorf("gateway.openai_usage_window.lookahead_seconds must be non-negative")
+		}
+		if c.Gateway.OpenAIUsageWindow.StopThresholdPercent <= 0 {
+			return fmt.Errorf("gateway.openai_usage_window.stop_threshold_percent must be positive")
+		}
+		if c.Gateway.OpenAIUsageWindow.FiveHourTokenLimit < 0 || c.Gateway.OpenAIUsageWindow.WeeklyTokenLimit < 0 {
+			return fmt.Errorf("gateway.openai_usage_window token limits must be non-negative")
+		}
+	}
 	if c.Gateway.MaxLineSize < 0 {
 		return fmt.Errorf("gateway.max_line_size must be non-negative")
 	}
+	if c.Gateway.StructuredOutputMaxRetries < 0 || c.Gateway.StructuredOutputMaxRetries > 5 {
+		return fmt.Errorf("gateway.structured_output_max_retries must be between 0 and 5")
+	}
+	if c.Gateway.ImageDownscale.Enabled {
+		if c.Gateway.ImageDownscale.MaxDimension < 64 {
+			return fmt.Errorf("gateway.image_downscale.max_dimension must be at least 64")
+		}
+		if c.Gateway.ImageDownscale.JPEGQuality < 1 || c.Gateway.ImageDownscale.JPEGQuality > 100 {
+			return fmt.Errorf("gateway.image_downscale.jpeg_quality must be between 1-100")
+		}
+		if c.Gateway.ImageDownscale.MinBytes < 0 {
+			return fmt.Errorf("gateway.image_downscale.min_bytes must be non-negative")
+		}
+	}
+	switch c.Gateway.Moderation.Mode {
+	case "", ModerationModeUpstream, ModerationModeLocal:
+	default:
+		return fmt.Errorf("gateway.moderation.mode must be one of: %s/%s", ModerationModeUpstream, ModerationModeLocal)
+	}
+	for category, patterns := range c.Gateway.Moderation.Categories {
+		for _, pattern := range patterns {
+			if _, err := regexp.Compile("(?i)" + pattern); err != nil {
+				return fmt.Errorf("gateway.moderation.categories.%s: invalid pattern %q: %w", category, pattern, err)
+			}
+		}
+	}
+	switch c.Gateway.Moderation.PreFilterSource {
+	case "", ModerationSourceLocal:
+	case ModerationSourceEndpoint:
+		u, err := url.Parse(c.Gateway.Moderation.Endpoint.URL)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return fmt.Errorf("gateway.moderation.endpoint.url must be an absolute http(s) URL when pre_filter_source is endpoint")
+		}
+		if c.Gateway.Moderation.Endpoint.TimeoutSeconds <= 0 {
+			return fmt.Errorf("gateway.moderation.endpoint.timeout_seconds must be positive")
+		}
+	default:
+		return fmt.Errorf("gateway.moderation.pre_filter_source must be one of: %s/%s", ModerationSourceLocal, ModerationSourceEndpoint)
+	}
+	hookNames := make(map[string]struct{}, len(c.Gateway.Hooks.Handlers))
+	for i, hook := range c.Gateway.Hooks.Handlers {
+		if hook.Name == "" {
+			return fmt.Errorf("gateway.hooks.handlers[%d].name is required", i)
+		}
+		if _, dup := hookNames[hook.Name]; dup {
+			return fmt.Errorf("gateway.hooks.handlers[%d].name %q is duplicated", i, hook.Name)
+		}
+		hookNames[hook.Name] = struct{}{}
+		if len(hook.Stages) == 0 {
+			return fmt.Errorf("gateway.hooks.handlers[%d].stages must not be empty", i)
+		}
+		for _, stage := range hook.Stages {
+			switch stage {
+			case "pre_auth", "pre_translation", "pre_upstream", "post_response":
+			default:
+				return fmt.Errorf("gateway.hooks.handlers[%d].stages must be pre_auth/pre_translation/pre_upstream/post_response, got %q", i, stage)
+			}
+		}
+		if hook.TimeoutMS > 60000 {
+			return fmt.Errorf("gateway.hooks.handlers[%d].timeout_ms must be at most 60000", i)
+		}
+		switch hook.Type {
+		case GatewayHookTypeGo:
+			if hook.Plugin == "" {
+				return fmt.Errorf("gateway.hooks.handlers[%d].plugin is required for type go", i)
+			}
+		case GatewayHookTypeWebhook:
+			u, err := url.Parse(hook.URL)
+			if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+				return fmt.Errorf("gateway.hooks.handlers[%d].url must be an absolute http(s) URL", i)
+			}
+		case GatewayHookTypeScript:
+			if hook.Command == "" {
+				return fmt.Errorf("gateway.hooks.handlers[%d].command is required for type script", i)
+			}
+		default:
+			return fmt.Errorf("gateway.hooks.handlers[%d].type must be one of: %s/%s/%s", i, GatewayHookTypeGo, GatewayHookTypeWebhook, GatewayHookTypeScript)
+		}
+	}
 	if c.Gateway.MaxLineSize != 0 && c.Gateway.MaxLineSize < 1024*1024 {
 		return fmt.Errorf("gateway.max_line_size must be at least 1MB")
 	}
@@ -2169,6 +4242,15 @@ func (c *Config) Validate() error {
 	if c.Gateway.Scheduling.FallbackMaxWaiting <= 0 {
 		return fmt.Errorf("gateway.scheduling.fallback_max_waiting must be positive")
 	}
+	if c.Gateway.Scheduling.PriorityOverflowLoadPercent < 0 || c.Gateway.Scheduling.PriorityOverflowLoadPercent > 100 {
+		return fmt.Errorf("gateway.scheduling.priority_overflow_load_percent must be between 0-100")
+	}
+	if c.Gateway.Scheduling.PriorityRecoverLoadPercent < 0 || c.Gateway.Scheduling.PriorityRecoverLoadPercent > c.Gateway.Scheduling.PriorityOverflowLoadPercent {
+		return fmt.Errorf("gateway.scheduling.priority_recover_load_percent must be between 0 and priority_overflow_load_percent")
+	}
+	if c.Gateway.Scheduling.LatencyAwareMinSamples < 1 {
+		return fmt.Errorf("gateway.scheduling.latency_aware_min_samples must be positive")
+	}
 	if c.Gateway.Scheduling.SlotCleanupInterval < 0 {
 		return fmt.Errorf("gateway.scheduling.slot_cleanup_interval must be non-negative")
 	}
@@ -2222,6 +4304,96 @@ func (c *Config) Validate() error {
 	return nil
 }
 
+// ParseAlertWebhookTemplate 解析告警消息模板；模板内可用 json 函数输出 JSON 编码的值
+func ParseAlertWebhookTemplate(text string) (*template.Template, error) {
+	return template.New("alert").Funcs(template.FuncMap{
+		"json": func(v any) (string, error) {
+			raw, err := json.Marshal(v)
+			return string(raw), err
+		},
+	}).Parse(text)
+}
+
+func validateUsageWebhook(c *UsageWebhookConfig) error {
+	if err := ValidateAbsoluteHTTPURL(c.URL); err != nil {
+		return fmt.Errorf("usage_webhook.url invalid: %w", err)
+	}
+	if c.TimeoutSeconds <= 0 {
+		return fmt.Errorf("usage_webhook.timeout_seconds must be positive")
+	}
+	if c.BatchSize <= 0 {
+		return fmt.Errorf("usage_webhook.batch_size must be positive")
+	}
+	if c.FlushIntervalMS <= 0 {
+		return fmt.Errorf("usage_webhook.flush_interval_ms must be positive")
+	}
+	if c.QueueSize < c.BatchSize {
+		return fmt.Errorf("usage_webhook.queue_size must be at least batch_size")
+	}
+	if c.MaxRetries < 0 {
+		return fmt.Errorf("usage_webhook.max_retries must be non-negative")
+	}
+	if c.RetryBackoffMS < 0 {
+		return fmt.Errorf("usage_webhook.retry_backoff_ms must be non-negative")
+	}
+	warnIfInsecureURL("usage_webhook.url", c.URL)
+	return nil
+}
+
+func validateAlertWebhooks(c *AlertWebhooksConfig) error {
+	if c.TimeoutSeconds <= 0 {
+		return fmt.Errorf("alert_webhooks.timeout_seconds must be positive")
+	}
+	if c.CooldownSeconds < 0 {
+		return fmt.Errorf("alert_webhooks.cooldown_seconds must be non-negative")
+	}
+	if c.QuotaThresholdPercent <= 0 || c.QuotaThresholdPercent >= 100 {
+		return fmt.Errorf("alert_webhooks.quota_threshold_percent must be between 0 and 100")
+	}
+	known := make(map[string]bool, len(AlertWebhookEvents))
+	for _, event := range AlertWebhookEvents {
+		known[event] = true
+	}
+	for i, target := range c.Targets {
+		field := fmt.Sprintf("alert_webhooks.targets[%d]", i)
+		switch target.Type {
+		case AlertWebhookTypeSlack, AlertWebhookTypeDiscord, AlertWebhookTypeGeneric:
+			if err := ValidateAbsoluteHTTPURL(target.URL); err != nil {
+				return fmt.Errorf("%s.url invalid: %w", field, err)
+			}
+			warnIfInsecureURL(field+".url", target.URL)
+		case AlertWebhookTypeTelegram:
+			if strings.TrimSpace(target.TelegramBotToken) == "" || strings.TrimSpace(target.TelegramChatID) == "" {
+				return fmt.Errorf("%s requires telegram_bot_token and telegram_chat_id", field)
+			}
+			if strings.TrimSpace(target.URL) != "" {
+				if err := ValidateAbsoluteHTTPURL(target.URL); err != nil {
+					return fmt.Errorf("%s.url invalid: %w", field, err)
+				}
+			}
+		default:
+			return fmt.Errorf("%s.type must be one of: slack, discord, telegram, generic", field)
+		}
+		for _, event := range target.Events {
+			if !known[event] {
+				return fmt.Errorf("%s.events: unknown event %q", field, event)
+			}
+		}
+		if _, err := ParseAlertWebhookTemplate(target.Template); err != nil {
+			return fmt.Errorf("%s.template invalid: %w", field, err)
+		}
+		for event, tmpl := range target.Templates {
+			if !known[event] {
+				return fmt.Errorf("%s.templates: unknown event %q", field, event)
+			}
+			if _, err := ParseAlertWebhookTemplate(tmpl); err != nil {
+				return fmt.Errorf("%s.templates.%s invalid: %w", field, event, err)
+			}
+		}
+	}
+	return nil
+}
+
 func normalizeStringSlice(values []string) []string {
 	if len(values) == 0 {
 		return values
@@ -2293,6 +4465,15 @@ func GetServerAddress() string {
 	return fmt.Sprintf("%s:%d", host, port)
 }
 
+func isOIDCAdminRole(role string) bool {
+	switch strings.ToLower(strings.TrimSpace(role)) {
+	case "viewer", "operator", "admin":
+		return true
+	default:
+		return false
+	}
+}
+
 // ValidateAbsoluteHTTPURL 验证是否为有效的绝对 HTTP(S) URL
 func ValidateAbsoluteHTTPURL(raw string) error {
 	raw = strings.TrimSpace(raw)
@@ -2366,3 +4547,16 @@ func warnIfInsecureURL(field, raw string) {
 		slog.Warn("url uses http scheme; use https in production to avoid token leakage", "field", field)
 	}
 }
+
+// validateContextOverflowStrategy 校验上下文窗口超限策略；allowEmpty 为 true 时允许留空（沿用默认策略）
+func validateContextOverflowStrategy(strategy string, allowEmpty bool) error {
+	switch strategy {
+	case ContextOverflowStrategyOff, ContextOverflowStrategyReject, ContextOverflowStrategyTruncate, ContextOverflowStrategyCompact:
+		return nil
+	case "":
+		if allowEmpty {
+			return nil
+		}
+	}
+	return fmt.Errorf("must be one of: off/reject/truncate/compact")
+}
@@ -1,6 +1,7 @@
 package config
 
 import (
+	"os"
 	"strings"
 	"testing"
 	"time"
@@ -351,6 +352,77 @@ func TestValidateLinuxDoPKCERequiredForPublicClient(t *testing.T) {
 	}
 }
 
+func TestValidateOIDCConfig(t *testing.T) {
+	resetViperWithJWTSecret(t)
+
+	cfg, err := Load()
+	if err != nil {
+		t.Fatalf("Load() error: %v", err)
+	}
+	if cfg.OIDC.TokenAuthMethod != "client_secret_basic" || !cfg.OIDC.UsePKCE || cfg.OIDC.GroupsClaim != "groups" {
+		t.Fatalf("unexpected oidc defaults: %+v", cfg.OIDC)
+	}
+
+	cfg.OIDC.Enabled = true
+	cfg.OIDC.IssuerURL = "https://keycloak.example.com/realms/ops"
+	cfg.OIDC.ClientID = "sub2api"
+	cfg.OIDC.ClientSecret = "secret"
+	cfg.OIDC.RedirectURL = "https://example.com/api/v1/auth/oauth/oidc/callback"
+
+	err = cfg.Validate()
+	if err == nil || !strings.Contains(err.Error(), "oidc.role_mappings or oidc.default_role") {
+		t.Fatalf("Validate() expected role mapping error, got: %v", err)
+	}
+
+	cfg.OIDC.RoleMappings = []OIDCRoleMapping{{Group: "gateway-ops", Role: "user"}}
+	err = cfg.Validate()
+	if err == nil || !strings.Contains(err.Error(), "oidc.role_mappings[0].role") {
+		t.Fatalf("Validate() expected role error, got: %v", err)
+	}
+
+	cfg.OIDC.RoleMappings[0].Role = "operator"
+	if err := cfg.Validate(); err != nil {
+		t.Fatalf("Validate() unexpected error: %v", err)
+	}
+
+	cfg.OIDC.TokenAuthMethod = "none"
+	cfg.OIDC.UsePKCE = false
+	err = cfg.Validate()
+	if err == nil || !strings.Contains(err.Error(), "oidc.use_pkce") {
+		t.Fatalf("Validate() expected use_pkce error, got: %v", err)
+	}
+}
+
+func TestValidateAPIKeyBudgetWebhook(t *testing.T) {
+	resetViperWithJWTSecret(t)
+
+	cfg, err := Load()
+	if err != nil {
+		t.Fatalf("Load() error: %v", err)
+	}
+	if cfg.APIKeyBudget.WebhookURL != "" || cfg.APIKeyBudget.WebhookTimeoutSeconds != 10 {
+		t.Fatalf("unexpected api_key_budget defaults: %+v", cfg.APIKeyBudget)
+	}
+
+	cfg.APIKeyBudget.WebhookURL = "hooks.example.com/budget"
+	err = cfg.Validate()
+	if err == nil || !strings.Contains(err.Error(), "api_key_budget.webhook_url") {
+		t.Fatalf("Validate() expected webhook_url error, got: %v", err)
+	}
+
+	cfg.APIKeyBudget.WebhookURL = "https://hooks.example.com/budget"
+	cfg.APIKeyBudget.WebhookTimeoutSeconds = 0
+	err = cfg.Validate()
+	if err == nil || !strings.Contains(err.Error(), "api_key_budget.webhook_timeout_seconds") {
+		t.Fatalf("Validate() expected webhook_timeout_seconds error, got: %v", err)
+	}
+
+	cfg.APIKeyBudget.WebhookTimeoutSeconds = 5
+	if err := cfg.Validate(); err != nil {
+		t.Fatalf("Validate() unexpected error: %v", err)
+	}
+}
+
 func TestLoadDefaultDashboardCacheConfig(t *testing.T) {
 	resetViperWithJWTSecret(t)
 
@@ -947,6 +1019,22 @@ func TestValidateConfigErrors(t *testing.T) {
 			mutate:  func(c *Config) { c.Billing.CircuitBreaker.HalfOpenRequests = 0 },
 			wantErr: "billing.circuit_breaker.half_open_requests",
 		},
+		{
+			name: "shared state sync interval",
+			mutate: func(c *Config) {
+				c.SharedState.Enabled = true
+				c.SharedState.SyncIntervalSeconds = 0
+			},
+			wantErr: "shared_state.sync_interval_seconds",
+		},
+		{
+			name: "cluster leader lease",
+			mutate: func(c *Config) {
+				c.Cluster.Enabled = true
+				c.Cluster.LeaderLeaseSeconds = 2
+			},
+			wantErr: "cluster.leader_lease_seconds",
+		},
 		{
 			name:    "database max open conns",
 			mutate:  func(c *Config) { c.Database.MaxOpenConns = 0 },
@@ -1423,6 +1511,7 @@ func TestValidateConfig_OpenAIWSRules(t *testing.T) {
 				c.Gateway.OpenAIWS.SchedulerScoreWeights.Queue = 0
 				c.Gateway.OpenAIWS.SchedulerScoreWeights.ErrorRate = 0
 				c.Gateway.OpenAIWS.SchedulerScoreWeights.TTFT = 0
+				c.Gateway.OpenAIWS.SchedulerScoreWeights.Headroom = 0
 			},
 			wantErr: "gateway.openai_ws.scheduler_score_weights must not all be zero",
 		},
@@ -1517,6 +1606,15 @@ func TestValidateConfig_LogRequiredAndRotationBounds(t *testing.T) {
 			},
 			wantErr: "log.sampling.thereafter must be non-negative",
 		},
+		{
+			name: "route timeout ttfb exceeds total",
+			mutate: func(c *Config) {
+				c.Gateway.RouteTimeouts = map[string]GatewayRouteTimeoutConfig{
+					"/v1/responses": {TTFBTimeoutSeconds: 120, TotalTimeoutSeconds: 60},
+				}
+			},
+			wantErr: "gateway.route_timeouts[/v1/responses].ttfb_timeout_seconds must not exceed total_timeout_seconds",
+		},
 	}
 
 	for _, tt := range cases {
@@ -1671,3 +1769,760 @@ func TestLoad_DefaultGatewayUsageRecordConfig(t *testing.T) {
 		t.Fatalf("auto_scale_cooldown_seconds = %d, want 10", cfg.Gateway.UsageRecord.AutoScaleCooldownSeconds)
 	}
 }
+
+func TestLoadCodexCLIUserAgentPrefixesFromEnv(t *testing.T) {
+	resetViperWithJWTSecret(t)
+	t.Setenv("GATEWAY_CODEX_CLI_USER_AGENT_PREFIXES", "acme_codex/, codex_next/ ,")
+
+	cfg, err := Load()
+	require.NoError(t, err)
+	require.Equal(t, []string{"acme_codex/", "codex_next/"}, cfg.Gateway.CodexCLIUserAgentPrefixes)
+	require.False(t, cfg.Gateway.CodexCLIUserAgentPrefixesOverride)
+}
+
+func TestValidateCodexCLIUserAgentPrefixesOverrideRequiresPrefixes(t *testing.T) {
+	resetViperWithJWTSecret(t)
+	t.Setenv("GATEWAY_CODEX_CLI_USER_AGENT_PREFIXES_OVERRIDE", "true")
+
+	_, err := Load()
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "gateway.codex_cli_user_agent_prefixes")
+}
+
+func TestLoadUpstreamUserAgentDefaultsAndValidation(t *testing.T) {
+	resetViperWithJWTSecret(t)
+
+	cfg, err := Load()
+	require.NoError(t, err)
+	require.False(t, cfg.Gateway.UpstreamUserAgent.Enabled)
+	require.Equal(t, "codex_cli_rs/{version}", cfg.Gateway.UpstreamUserAgent.Template)
+	require.Equal(t, "0.104.0", cfg.Gateway.UpstreamUserAgent.Version)
+
+	resetViperWithJWTSecret(t)
+	t.Setenv("GATEWAY_UPSTREAM_USER_AGENT_ENABLED", "true")
+	t.Setenv("GATEWAY_UPSTREAM_USER_AGENT_TEMPLATE", " ")
+
+	_, err = Load()
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "gateway.upstream_user_agent.template")
+}
+
+func TestValidateReasoningDefaults(t *testing.T) {
+	resetViperWithJWTSecret(t)
+
+	cfg, err := Load()
+	require.NoError(t, err)
+	require.Empty(t, cfg.Gateway.ReasoningDefaults)
+
+	cfg.Gateway.ReasoningDefaults = []GatewayReasoningDefaultRule{{Models: []string{"gpt-5-codex*"}, Clients: []string{"codex"}, Effort: "high", Force: true}}
+	require.NoError(t, cfg.Validate())
+
+	cfg.Gateway.ReasoningDefaults = []GatewayReasoningDefaultRule{{Effort: "extreme"}}
+	require.ErrorContains(t, cfg.Validate(), "gateway.reasoning_defaults[0].effort")
+
+	cfg.Gateway.ReasoningDefaults = []GatewayReasoningDefaultRule{{Effort: "low", Clients: []string{"cursor"}}}
+	require.ErrorContains(t, cfg.Validate(), "gateway.reasoning_defaults[0].clients")
+
+	cfg.Gateway.ReasoningDefaults = []GatewayReasoningDefaultRule{{Effort: "low", Models: []string{"*-codex"}}}
+	require.ErrorContains(t, cfg.Validate(), "gateway.reasoning_defaults[0].models")
+}
+
+func TestValidateUpstreamRetry(t *testing.T) {
+	resetViperWithJWTSecret(t)
+
+	cfg, err := Load()
+	require.NoError(t, err)
+	require.False(t, cfg.Gateway.UpstreamRetry.Enabled)
+	require.Equal(t, []int{502, 503}, cfg.Gateway.UpstreamRetry.RetryStatusCodes)
+
+	cfg.Gateway.UpstreamRetry.Enabled = true
+	require.NoError(t, cfg.Validate())
+
+	cfg.Gateway.UpstreamRetry.MaxAttempts = 0
+	require.ErrorContains(t, cfg.Validate(), "gateway.upstream_retry.max_attempts")
+	cfg.Gateway.UpstreamRetry.MaxAttempts = 3
+
+	cfg.Gateway.UpstreamRetry.MaxDelayMS = cfg.Gateway.UpstreamRetry.BaseDelayMS - 1
+	require.ErrorContains(t, cfg.Validate(), "gateway.upstream_retry.max_delay_ms")
+	cfg.Gateway.UpstreamRetry.MaxDelayMS = 2000
+
+	cfg.Gateway.UpstreamRetry.RetryStatusCodes = []int{429}
+	require.ErrorContains(t, cfg.Validate(), "gateway.upstream_retry.retry_status_codes")
+	cfg.Gateway.UpstreamRetry.RetryStatusCodes = []int{502}
+
+	cfg.Gateway.UpstreamRetry.BudgetRatio = 1.5
+	require.ErrorContains(t, cfg.Validate(), "gateway.upstream_retry.budget_ratio")
+}
+
+func TestValidateAccountCircuitBreaker(t *testing.T) {
+	resetViperWithJWTSecret(t)
+
+	cfg, err := Load()
+	require.NoError(t, err)
+	require.False(t, cfg.Gateway.AccountCircuitBreaker.Enabled)
+	require.Equal(t, 20, cfg.Gateway.AccountCircuitBreaker.MinRequests)
+
+	cfg.Gateway.AccountCircuitBreaker.Enabled = true
+	require.NoError(t, cfg.Validate())
+
+	cfg.Gateway.AccountCircuitBreaker.ErrorRateThreshold = 0
+	require.ErrorContains(t, cfg.Validate(), "gateway.account_circuit_breaker.error_rate_threshold")
+	cfg.Gateway.AccountCircuitBreaker.ErrorRateThreshold = 0.5
+
+	cfg.Gateway.AccountCircuitBreaker.SlowCallMS = 5000
+	cfg.Gateway.AccountCircuitBreaker.SlowCallRateThreshold = 1.2
+	require.ErrorContains(t, cfg.Validate(), "gateway.account_circuit_breaker.slow_call_rate_threshold")
+	cfg.Gateway.AccountCircuitBreaker.SlowCallRateThreshold = 0.8
+
+	cfg.Gateway.AccountCircuitBreaker.OpenSeconds = 0
+	require.ErrorContains(t, cfg.Validate(), "gateway.account_circuit_breaker.open_seconds")
+}
+
+func TestValidateUpstreamTransportTuning(t *testing.T) {
+	resetViperWithJWTSecret(t)
+
+	cfg, err := Load()
+	require.NoError(t, err)
+	require.Equal(t, 10, cfg.Gateway.DialTimeoutSeconds)
+	require.Equal(t, 64, cfg.Gateway.TLSSessionCacheSize)
+	require.False(t, cfg.Gateway.DisableUpstreamHTTP2)
+
+	cfg.Gateway.TLSSessionCacheSize = -1
+	require.ErrorContains(t, cfg.Validate(), "gateway.tls_session_cache_size")
+	cfg.Gateway.TLSSessionCacheSize = 0
+	require.NoError(t, cfg.Validate())
+
+	cfg.Gateway.DialTimeoutSeconds = 0
+	require.ErrorContains(t, cfg.Validate(), "gateway.dial_timeout_seconds")
+}
+
+func TestValidateResponseCache(t *testing.T) {
+	resetViperWithJWTSecret(t)
+
+	cfg, err := Load()
+	require.NoError(t, err)
+	require.False(t, cfg.ResponseCache.Enabled)
+	require.Equal(t, "memory", cfg.ResponseCache.Backend)
+
+	cfg.ResponseCache.Enabled = true
+	require.NoError(t, cfg.Validate())
+
+	cfg.ResponseCache.Backend = "disk"
+	require.ErrorContains(t, cfg.Validate(), "response_cache.backend")
+	cfg.ResponseCache.Backend = "redis"
+	cfg.ResponseCache.MaxEntries = 0
+	require.NoError(t, cfg.Validate())
+
+	cfg.ResponseCache.TTLSeconds = 0
+	require.ErrorContains(t, cfg.Validate(), "response_cache.ttl_seconds")
+}
+
+func TestValidateConversationStore(t *testing.T) {
+	resetViperWithJWTSecret(t)
+
+	cfg, err := Load()
+	require.NoError(t, err)
+	require.False(t, cfg.ConversationStore.Enabled)
+	require.Equal(t, "memory", cfg.ConversationStore.Backend)
+
+	cfg.ConversationStore.Enabled = true
+	require.NoError(t, cfg.Validate())
+
+	cfg.ConversationStore.Backend = "disk"
+	require.ErrorContains(t, cfg.Validate(), "conversation_store.backend")
+	cfg.ConversationStore.Backend = "redis"
+	cfg.ConversationStore.MaxEntries = 0
+	require.NoError(t, cfg.Validate())
+
+	cfg.ConversationStore.MaxBytes = 0
+	require.ErrorContains(t, cfg.Validate(), "conversation_store.max_bytes")
+}
+
+func TestValidatePriorityQueue(t *testing.T) {
+	resetViperWithJWTSecret(t)
+
+	cfg, err := Load()
+	require.NoError(t, err)
+	require.False(t, cfg.Gateway.PriorityQueue.Enabled)
+
+	cfg.Gateway.PriorityQueue.Enabled = true
+	cfg.Gateway.PriorityQueue.Classes = []GatewayPriorityClass{{Name: "premium", KeyTags: []string{"premium"}, Priority: 10}}
+	require.NoError(t, cfg.Validate())
+
+	cfg.Gateway.PriorityQueue.Classes[0].KeyTags = nil
+	require.ErrorContains(t, cfg.Validate(), "gateway.priority_queue.classes[0].key_tags")
+	cfg.Gateway.PriorityQueue.Classes = nil
+
+	cfg.Gateway.PriorityQueue.MaxInFlightPerGroup = 0
+	require.ErrorContains(t, cfg.Validate(), "gateway.priority_queue.max_in_flight_per_group")
+}
+
+func TestValidateShutdownDrain(t *testing.T) {
+	resetViperWithJWTSecret(t)
+
+	cfg, err := Load()
+	require.NoError(t, err)
+	require.Equal(t, 60, cfg.Server.ShutdownDrainSeconds)
+
+	cfg.Server.ShutdownDrainSeconds = 0
+	require.ErrorContains(t, cfg.Validate(), "server.shutdown_drain_seconds")
+
+	cfg.Server.ShutdownDrainSeconds = 60
+	require.Zero(t, cfg.Server.ShutdownUnreadySeconds)
+	require.True(t, cfg.Health.ReadinessRequireAccount)
+	cfg.Server.ShutdownUnreadySeconds = -1
+	require.ErrorContains(t, cfg.Validate(), "server.shutdown_unready_seconds")
+}
+
+func TestValidateServerTLS(t *testing.T) {
+	resetViperWithJWTSecret(t)
+
+	cfg, err := Load()
+	require.NoError(t, err)
+	require.False(t, cfg.Server.TLS.Enabled)
+	require.Equal(t, "./data/acme", cfg.Server.TLS.ACME.CacheDir)
+	require.Equal(t, ":80", cfg.Server.TLS.ACME.HTTPChallengeAddr)
+
+	cfg.Server.TLS.Enabled = true
+	require.ErrorContains(t, cfg.Validate(), "server.tls.cert_file")
+
+	cfg.Server.TLS.CertFile = "/etc/sub2api/tls.crt"
+	cfg.Server.TLS.KeyFile = "/etc/sub2api/tls.key"
+	require.NoError(t, cfg.Validate())
+
+	cfg.Server.TLS.ACME.Enabled = true
+	require.ErrorContains(t, cfg.Validate(), "cannot be combined")
+
+	cfg.Server.TLS.CertFile = ""
+	cfg.Server.TLS.KeyFile = ""
+	require.ErrorContains(t, cfg.Validate(), "server.tls.acme.domains")
+
+	cfg.Server.TLS.ACME.Domains = []string{"https://api.example.com"}
+	require.ErrorContains(t, cfg.Validate(), "invalid domain")
+
+	cfg.Server.TLS.ACME.Domains = []string{"api.example.com"}
+	require.NoError(t, cfg.Validate())
+}
+
+func TestValidateServerUnixSocketMode(t *testing.T) {
+	resetViperWithJWTSecret(t)
+
+	cfg, err := Load()
+	require.NoError(t, err)
+	mode, err := cfg.Server.UnixSocketFileMode()
+	require.NoError(t, err)
+	require.Equal(t, os.FileMode(0o660), mode)
+
+	cfg.Server.UnixSocket = "/run/sub2api/gateway.sock"
+	cfg.Server.UnixSocketMode = "0600"
+	require.NoError(t, cfg.Validate())
+
+	cfg.Server.UnixSocketMode = "rw-rw----"
+	require.ErrorContains(t, cfg.Validate(), "server.unix_socket_mode")
+	cfg.Server.UnixSocketMode = "1777"
+	require.ErrorContains(t, cfg.Validate(), "server.unix_socket_mode")
+}
+
+func TestValidateMultipartMaxMemory(t *testing.T) {
+	resetViperWithJWTSecret(t)
+
+	cfg, err := Load()
+	require.NoError(t, err)
+	require.Equal(t, int64(8*1024*1024), cfg.Gateway.MultipartMaxMemory)
+
+	cfg.Gateway.MultipartMaxMemory = 0
+	require.ErrorContains(t, cfg.Validate(), "gateway.multipart_max_memory")
+}
+
+func TestValidateTracingConfig(t *testing.T) {
+	resetViperWithJWTSecret(t)
+
+	cfg, err := Load()
+	require.NoError(t, err)
+	require.False(t, cfg.Tracing.Enabled)
+	require.Equal(t, "http://localhost:4318/v1/traces", cfg.Tracing.Endpoint)
+	require.Equal(t, 1.0, cfg.Tracing.SampleRatio)
+	require.True(t, cfg.Tracing.PropagateUpstream)
+
+	cfg.Tracing.Enabled = true
+	require.NoError(t, cfg.Validate())
+
+	cfg.Tracing.Endpoint = "localhost:4318"
+	require.ErrorContains(t, cfg.Validate(), "tracing.endpoint")
+
+	cfg.Tracing.Endpoint = "https://otel.example.com/v1/traces"
+	cfg.Tracing.SampleRatio = 1.5
+	require.ErrorContains(t, cfg.Validate(), "tracing.sample_ratio")
+
+	cfg.Tracing.SampleRatio = 0.1
+	cfg.Tracing.BatchSize = 0
+	require.ErrorContains(t, cfg.Validate(), "tracing.batch_size")
+}
+
+func TestValidateAccessLogConfig(t *testing.T) {
+	resetViperWithJWTSecret(t)
+
+	cfg, err := Load()
+	require.NoError(t, err)
+	require.False(t, cfg.Log.Access.Headers)
+	require.False(t, cfg.Log.Access.HashPrompts)
+	require.Empty(t, cfg.Log.Access.BodyAPIKeyIDs)
+	require.Equal(t, 2048, cfg.Log.Access.BodyMaxBytes)
+
+	cfg.Log.Access.BodyAPIKeyIDs = []int64{42}
+	require.NoError(t, cfg.Validate())
+
+	cfg.Log.Access.BodyMaxBytes = 0
+	require.ErrorContains(t, cfg.Validate(), "log.access.body_max_bytes")
+
+	cfg.Log.Access.BodyAPIKeyIDs = nil
+	cfg.Log.Access.BodyMaxBytes = -1
+	require.ErrorContains(t, cfg.Validate(), "log.access.body_max_bytes")
+}
+
+func TestValidatePricingOverrides(t *testing.T) {
+	resetViperWithJWTSecret(t)
+
+	cfg, err := Load()
+	require.NoError(t, err)
+	require.Empty(t, cfg.Pricing.Overrides)
+
+	price := 3.0
+	cfg.Pricing.Overrides = []PricingOverrideConfig{{Model: "claude-sonnet-4*", InputPerMTok: &price}}
+	require.NoError(t, cfg.Validate())
+
+	cfg.Pricing.Overrides = []PricingOverrideConfig{{Model: "claude-*-4"}}
+	require.ErrorContains(t, cfg.Validate(), "pricing.overrides[0].model")
+
+	negative := -1.0
+	cfg.Pricing.Overrides = []PricingOverrideConfig{{Model: "gpt-5", OutputPerMTok: &negative}}
+	require.ErrorContains(t, cfg.Validate(), "pricing.overrides[0] prices")
+}
+
+func TestValidateHealthConfig(t *testing.T) {
+	resetViperWithJWTSecret(t)
+
+	cfg, err := Load()
+	require.NoError(t, err)
+	require.False(t, cfg.Health.DetailsEnabled)
+	require.Equal(t, 5, cfg.Health.CacheSeconds)
+	require.Equal(t, 2, cfg.Health.CheckTimeoutSeconds)
+
+	cfg.Health.CacheSeconds = 0
+	require.NoError(t, cfg.Validate())
+
+	cfg.Health.CacheSeconds = -1
+	require.ErrorContains(t, cfg.Validate(), "health.cache_seconds")
+
+	cfg.Health.CacheSeconds = 5
+	cfg.Health.CheckTimeoutSeconds = 0
+	require.ErrorContains(t, cfg.Validate(), "health.check_timeout_seconds")
+}
+
+func TestValidateAlertWebhooksConfig(t *testing.T) {
+	resetViperWithJWTSecret(t)
+
+	cfg, err := Load()
+	require.NoError(t, err)
+	require.False(t, cfg.AlertWebhooks.Enabled)
+	require.Equal(t, 10, cfg.AlertWebhooks.TimeoutSeconds)
+	require.Equal(t, 600, cfg.AlertWebhooks.CooldownSeconds)
+	require.Equal(t, 80.0, cfg.AlertWebhooks.QuotaThresholdPercent)
+
+	cfg.AlertWebhooks.Enabled = true
+	cfg.AlertWebhooks.Targets = []AlertWebhookTarget{
+		{Type: AlertWebhookTypeSlack, URL: "https://hooks.slack.com/services/x", Events: []string{"account_disabled"}},
+		{Type: AlertWebhookTypeTelegram, TelegramBotToken: "123:abc", TelegramChatID: "-100"},
+		{Type: AlertWebhookTypeGeneric, URL: "http://10.0.0.1/hook", Template: `{"text":{{printf "%q" .Title}}}`},
+	}
+	require.NoError(t, cfg.Validate())
+
+	cfg.AlertWebhooks.Targets[0].Events = []string{"account.disabled"}
+	require.ErrorContains(t, cfg.Validate(), "unknown event")
+
+	cfg.AlertWebhooks.Targets[0].Events = nil
+	cfg.AlertWebhooks.Targets[1].TelegramChatID = ""
+	require.ErrorContains(t, cfg.Validate(), "telegram_chat_id")
+
+	cfg.AlertWebhooks.Targets[1].TelegramChatID = "-100"
+	cfg.AlertWebhooks.Targets[2].Template = "{{.Title"
+	require.ErrorContains(t, cfg.Validate(), "targets[2].template")
+
+	cfg.AlertWebhooks.Targets[2].Template = ""
+	cfg.AlertWebhooks.Targets[0].Type = "email"
+	require.ErrorContains(t, cfg.Validate(), "targets[0].type")
+
+	cfg.AlertWebhooks.Targets[0].Type = AlertWebhookTypeDiscord
+	cfg.AlertWebhooks.QuotaThresholdPercent = 100
+	require.ErrorContains(t, cfg.Validate(), "quota_threshold_percent")
+}
+
+func TestValidateUsageWebhookConfig(t *testing.T) {
+	resetViperWithJWTSecret(t)
+
+	cfg, err := Load()
+	require.NoError(t, err)
+	require.False(t, cfg.UsageWebhook.Enabled)
+	require.Equal(t, 100, cfg.UsageWebhook.BatchSize)
+	require.Equal(t, 1000, cfg.UsageWebhook.FlushIntervalMS)
+	require.Equal(t, 3, cfg.UsageWebhook.MaxRetries)
+
+	cfg.UsageWebhook.Enabled = true
+	require.ErrorContains(t, cfg.Validate(), "usage_webhook.url")
+
+	cfg.UsageWebhook.URL = "http://billing.internal/usage"
+	require.NoError(t, cfg.Validate())
+
+	cfg.UsageWebhook.BatchSize = 0
+	require.ErrorContains(t, cfg.Validate(), "usage_webhook.batch_size")
+
+	cfg.UsageWebhook.BatchSize = 100
+	cfg.UsageWebhook.QueueSize = 10
+	require.ErrorContains(t, cfg.Validate(), "usage_webhook.queue_size")
+
+	cfg.UsageWebhook.QueueSize = 1000
+	cfg.UsageWebhook.MaxRetries = -1
+	require.ErrorContains(t, cfg.Validate(), "usage_webhook.max_retries")
+}
+
+func TestValidateGatewayHooks(t *testing.T) {
+	resetViperWithJWTSecret(t)
+
+	cfg, err := Load()
+	if err != nil {
+		t.Fatalf("Load() error: %v", err)
+	}
+	if cfg.Gateway.Hooks.Enabled {
+		t.Fatalf("Gateway.Hooks.Enabled = true, want false")
+	}
+
+	valid := GatewayHookHandlerConfig{Name: "audit", Type: GatewayHookTypeWebhook, Stages: []string{"pre_auth"}, URL: "https://hooks.example.com/check", TimeoutMS: 2000}
+	tests := []struct {
+		name    string
+		mutate  func(h *GatewayHookHandlerConfig)
+		wantErr string
+	}{
+		{name: "valid", mutate: func(h *GatewayHookHandlerConfig) {}},
+		{name: "missing name", mutate: func(h *GatewayHookHandlerConfig) { h.Name = "" }, wantErr: "name is required"},
+		{name: "bad stage", mutate: func(h *GatewayHookHandlerConfig) { h.Stages = []string{"pre_response"} }, wantErr: "stages must be"},
+		{name: "bad type", mutate: func(h *GatewayHookHandlerConfig) { h.Type = "lua" }, wantErr: "type must be one of"},
+		{name: "webhook url", mutate: func(h *GatewayHookHandlerConfig) { h.URL = "hooks.example.com" }, wantErr: "url must be an absolute"},
+		{name: "go plugin", mutate: func(h *GatewayHookHandlerConfig) { h.Type = GatewayHookTypeGo }, wantErr: "plugin is required"},
+		{name: "script command", mutate: func(h *GatewayHookHandlerConfig) { h.Type = GatewayHookTypeScript }, wantErr: "command is required"},
+		{name: "timeout", mutate: func(h *GatewayHookHandlerConfig) { h.TimeoutMS = 120000 }, wantErr: "timeout_ms"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := valid
+			tt.mutate(&h)
+			cfg.Gateway.Hooks.Handlers = []GatewayHookHandlerConfig{h}
+			err := cfg.Validate()
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Fatalf("Validate() unexpected error: %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), "gateway.hooks.handlers[0]."+strings.Fields(tt.wantErr)[0]) {
+				t.Fatalf("Validate() expected %q error, got: %v", tt.wantErr, err)
+			}
+		})
+	}
+
+	cfg.Gateway.Hooks.Handlers = []GatewayHookHandlerConfig{valid, valid}
+	if err := cfg.Validate(); err == nil || !strings.Contains(err.Error(), "duplicated") {
+		t.Fatalf("Validate() expected duplicate name error, got: %v", err)
+	}
+}
+
+func TestValidateGatewaySystemPrompts(t *testing.T) {
+	resetViperWithJWTSecret(t)
+	viper.Set("gateway.system_prompts", []map[string]any{{"paths": []string{" /v1/messages "}, "prompt": "Be safe."}})
+
+	cfg, err := Load()
+	require.NoError(t, err)
+	require.Len(t, cfg.Gateway.SystemPrompts, 1)
+	require.Equal(t, "prepend", cfg.Gateway.SystemPrompts[0].Mode)
+	require.Equal(t, []string{"/v1/messages"}, cfg.Gateway.SystemPrompts[0].Paths)
+
+	cfg.Gateway.SystemPrompts[0].Mode = "override"
+	require.ErrorContains(t, cfg.Validate(), "gateway.system_prompts[0].mode")
+	cfg.Gateway.SystemPrompts[0].Mode = "append"
+	cfg.Gateway.SystemPrompts[0].Prompt = " "
+	require.ErrorContains(t, cfg.Validate(), "gateway.system_prompts[0].prompt")
+	cfg.Gateway.SystemPrompts[0].Prompt = "Be safe."
+	cfg.Gateway.SystemPrompts[0].Paths = []string{"v1/messages"}
+	require.ErrorContains(t, cfg.Validate(), "gateway.system_prompts[0].paths")
+	cfg.Gateway.SystemPrompts[0].Paths = nil
+	cfg.Gateway.SystemPrompts[0].Models = []string{"*-sonnet"}
+	require.ErrorContains(t, cfg.Validate(), "gateway.system_prompts[0].models")
+	cfg.Gateway.SystemPrompts[0].Models = []string{"claude-*"}
+	require.NoError(t, cfg.Validate())
+}
+
+func TestValidateGatewayParamSanitize(t *testing.T) {
+	resetViperWithJWTSecret(t)
+	viper.Set("gateway.param_sanitize.rules", []map[string]any{{"models": []string{" glm-* "}, "max_output_tokens": 8192, "drop_params": []string{" seed "}}})
+
+	cfg, err := Load()
+	require.NoError(t, err)
+	require.True(t, cfg.Gateway.ParamSanitize.Enabled)
+	require.True(t, cfg.Gateway.ParamSanitize.UsePricingLimits)
+	require.Len(t, cfg.Gateway.ParamSanitize.Rules, 1)
+	require.Equal(t, []string{"glm-*"}, cfg.Gateway.ParamSanitize.Rules[0].Models)
+	require.Equal(t, []string{"seed"}, cfg.Gateway.ParamSanitize.Rules[0].DropParams)
+	require.NoError(t, cfg.Validate())
+
+	cfg.Gateway.ParamSanitize.Rules[0].MaxOutputTokens = -1
+	require.ErrorContains(t, cfg.Validate(), "gateway.param_sanitize.rules[0].max_output_tokens")
+	cfg.Gateway.ParamSanitize.Rules[0].MaxOutputTokens = 0
+	cfg.Gateway.ParamSanitize.Rules[0].Models = []string{"*-flash"}
+	require.ErrorContains(t, cfg.Validate(), "gateway.param_sanitize.rules[0].models")
+	cfg.Gateway.ParamSanitize.Rules[0].Models = nil
+	cfg.Gateway.ParamSanitize.Rules[0].DropParams = []string{"messages"}
+	require.ErrorContains(t, cfg.Validate(), "gateway.param_sanitize.rules[0].drop_params")
+}
+
+func TestValidateGatewayContextOverflow(t *testing.T) {
+	resetViperWithJWTSecret(t)
+	viper.Set("gateway.context_overflow.strategy", " Truncate ")
+	viper.Set("gateway.context_overflow.rules", []map[string]any{{"models": []string{" deepseek-* "}, "context_window": 65536, "strategy": "Compact"}})
+
+	cfg, err := Load()
+	require.NoError(t, err)
+	require.Equal(t, ContextOverflowStrategyTruncate, cfg.Gateway.ContextOverflow.Strategy)
+	require.True(t, cfg.Gateway.ContextOverflow.UsePricingLimits)
+	require.Equal(t, 5, cfg.Gateway.ContextOverflow.SafetyMarginPercent)
+	require.Equal(t, []string{"deepseek-*"}, cfg.Gateway.ContextOverflow.Rules[0].Models)
+	require.Equal(t, ContextOverflowStrategyCompact, cfg.Gateway.ContextOverflow.Rules[0].Strategy)
+	require.NoError(t, cfg.Validate())
+
+	cfg.Gateway.ContextOverflow.Rules[0].Strategy = "summarize"
+	require.ErrorContains(t, cfg.Validate(), "gateway.context_overflow.rules[0].strategy")
+	cfg.Gateway.ContextOverflow.Rules[0].Strategy = ""
+	cfg.Gateway.ContextOverflow.Rules[0].ContextWindow = -1
+	require.ErrorContains(t, cfg.Validate(), "gateway.context_overflow.rules[0].context_window")
+	cfg.Gateway.ContextOverflow.Rules[0].ContextWindow = 0
+	cfg.Gateway.ContextOverflow.Strategy = ""
+	require.ErrorContains(t, cfg.Validate(), "gateway.context_overflow.strategy")
+	cfg.Gateway.ContextOverflow.Strategy = ContextOverflowStrategyReject
+	cfg.Gateway.ContextOverflow.SafetyMarginPercent = 80
+	require.ErrorContains(t, cfg.Validate(), "gateway.context_overflow.safety_margin_percent")
+}
+
+func TestValidateGatewayChatMultiChoice(t *testing.T) {
+	resetViperWithJWTSecret(t)
+
+	cfg, err := Load()
+	require.NoError(t, err)
+	require.False(t, cfg.Gateway.ChatMultiChoice.Enabled)
+	require.Equal(t, 8, cfg.Gateway.ChatMultiChoice.MaxN)
+	require.Equal(t, 4, cfg.Gateway.ChatMultiChoice.MaxParallel)
+	require.NoError(t, cfg.Validate())
+
+	cfg.Gateway.ChatMultiChoice.Enabled = true
+	cfg.Gateway.ChatMultiChoice.MaxN = 1
+	require.ErrorContains(t, cfg.Validate(), "gateway.chat_multi_choice.max_n")
+	cfg.Gateway.ChatMultiChoice.MaxN = 8
+	cfg.Gateway.ChatMultiChoice.MaxParallel = 0
+	require.ErrorContains(t, cfg.Validate(), "gateway.chat_multi_choice.max_parallel")
+}
+
+func TestValidateGatewayOpenAIPlanRestrictions(t *testing.T) {
+	resetViperWithJWTSecret(t)
+	viper.Set("gateway.openai_plan_restrictions", []map[string]any{
+		{"models": []string{" gpt-5-pro* "}, "reasoning_efforts": []string{" XHigh "}, "plans": []string{" Pro "}},
+	})
+
+	cfg, err := Load()
+	require.NoError(t, err)
+	rule := cfg.Gateway.OpenAIPlanRestrictions[0]
+	require.Equal(t, []string{"gpt-5-pro*"}, rule.Models)
+	require.Equal(t, []string{"xhigh"}, rule.ReasoningEfforts)
+	require.Equal(t, []string{"pro"}, rule.Plans)
+	require.NoError(t, cfg.Validate())
+
+	cfg.Gateway.OpenAIPlanRestrictions[0].ReasoningEfforts = []string{"max"}
+	require.ErrorContains(t, cfg.Validate(), "gateway.openai_plan_restrictions[0].reasoning_efforts")
+	cfg.Gateway.OpenAIPlanRestrictions[0].ReasoningEfforts = nil
+	cfg.Gateway.OpenAIPlanRestrictions[0].Plans = nil
+	require.ErrorContains(t, cfg.Validate(), "gateway.openai_plan_restrictions[0].plans")
+}
+
+func TestValidateCodexModelSync(t *testing.T) {
+	resetViperWithJWTSecret(t)
+
+	cfg, err := Load()
+	require.NoError(t, err)
+	require.True(t, cfg.CodexModelSync.Enabled)
+	require.Equal(t, 360, cfg.CodexModelSync.IntervalMinutes)
+	require.NoError(t, cfg.Validate())
+
+	cfg.CodexModelSync.IntervalMinutes = 0
+	require.ErrorContains(t, cfg.Validate(), "codex_model_sync.interval_minutes")
+	cfg.CodexModelSync.Enabled = false
+	require.NoError(t, cfg.Validate())
+}
+
+func TestValidateGatewayOpenAISessionHeaders(t *testing.T) {
+	resetViperWithJWTSecret(t)
+
+	cfg, err := Load()
+	require.NoError(t, err)
+	require.False(t, cfg.Gateway.OpenAISessionHeaders.Enabled)
+	require.Equal(t, 24, cfg.Gateway.OpenAISessionHeaders.RotationHours)
+
+	cfg.Gateway.OpenAISessionHeaders.RotationHours = -1
+	require.ErrorContains(t, cfg.Validate(), "gateway.openai_session_headers.rotation_hours")
+}
+
+func TestValidateGatewayUpstreamUserAgentAutoUpdate(t *testing.T) {
+	resetViperWithJWTSecret(t)
+	viper.Set("gateway.upstream_user_agent.auto_update", true)
+
+	cfg, err := Load()
+	require.NoError(t, err)
+	require.Equal(t, 360, cfg.Gateway.UpstreamUserAgent.AutoUpdateIntervalMinutes)
+	require.Equal(t, "openai/codex", cfg.Gateway.UpstreamUserAgent.ReleaseRepo)
+	require.NoError(t, cfg.Validate())
+
+	cfg.Gateway.UpstreamUserAgent.ReleaseRepo = "codex"
+	require.ErrorContains(t, cfg.Validate(), "gateway.upstream_user_agent.release_repo")
+	cfg.Gateway.UpstreamUserAgent.ReleaseRepo = "openai/codex"
+	cfg.Gateway.UpstreamUserAgent.AutoUpdateIntervalMinutes = 0
+	require.ErrorContains(t, cfg.Validate(), "gateway.upstream_user_agent.auto_update_interval_minutes")
+}
+
+func TestValidateGatewayModerationPreFilterSource(t *testing.T) {
+	resetViperWithJWTSecret(t)
+	viper.Set("gateway.moderation.pre_filter_source", " Endpoint ")
+	viper.Set("gateway.moderation.endpoint.url", "https://api.openai.com/v1/moderations")
+	viper.Set("gateway.moderation.flag_categories", []string{" violence "})
+
+	cfg, err := Load()
+	require.NoError(t, err)
+	require.Equal(t, ModerationSourceEndpoint, cfg.Gateway.Moderation.PreFilterSource)
+	require.Equal(t, "omni-moderation-latest", cfg.Gateway.Moderation.Endpoint.Model)
+	require.True(t, cfg.Gateway.Moderation.Endpoint.FailOpen)
+	require.Equal(t, []string{"violence"}, cfg.Gateway.Moderation.FlagCategories)
+
+	cfg.Gateway.Moderation.Endpoint.URL = "api.openai.com/v1/moderations"
+	require.ErrorContains(t, cfg.Validate(), "gateway.moderation.endpoint.url")
+	cfg.Gateway.Moderation.Endpoint.URL = "https://api.openai.com/v1/moderations"
+	cfg.Gateway.Moderation.Endpoint.TimeoutSeconds = 0
+	require.ErrorContains(t, cfg.Validate(), "gateway.moderation.endpoint.timeout_seconds")
+	cfg.Gateway.Moderation.PreFilterSource = "remote"
+	require.ErrorContains(t, cfg.Validate(), "gateway.moderation.pre_filter_source")
+}
+
+func TestValidateContentLog(t *testing.T) {
+	resetViperWithJWTSecret(t)
+
+	cfg, err := Load()
+	require.NoError(t, err)
+	require.True(t, cfg.ContentLog.Enabled)
+	require.Equal(t, 256<<10, cfg.ContentLog.MaxBodyBytes)
+	require.Equal(t, 3, cfg.ContentLog.RetentionDays)
+	require.NoError(t, cfg.Validate())
+
+	cfg.ContentLog.RetentionDays = 0
+	require.ErrorContains(t, cfg.Validate(), "content_log.retention_days")
+	cfg.ContentLog.RetentionDays = 3
+	cfg.ContentLog.MaxBodyBytes = -1
+	require.ErrorContains(t, cfg.Validate(), "content_log.max_body_bytes")
+
+	// 关闭后不再校验
+	cfg.ContentLog.Enabled = false
+	require.NoError(t, cfg.Validate())
+}
+
+func TestValidateIdempotencyGateway(t *testing.T) {
+	resetViperWithJWTSecret(t)
+
+	cfg, err := Load()
+	require.NoError(t, err)
+	require.True(t, cfg.Idempotency.Gateway.Enabled)
+	require.Equal(t, 600, cfg.Idempotency.Gateway.TTLSeconds)
+	require.Equal(t, 1<<20, cfg.Idempotency.Gateway.MaxResponseBytes)
+	require.NoError(t, cfg.Validate())
+
+	cfg.Idempotency.Gateway.TTLSeconds = 0
+	require.ErrorContains(t, cfg.Validate(), "idempotency.gateway.ttl_seconds")
+	cfg.Idempotency.Gateway.TTLSeconds = 600
+	cfg.Idempotency.Gateway.MaxResponseBytes = 0
+	require.ErrorContains(t, cfg.Validate(), "idempotency.gateway.max_response_bytes")
+
+	cfg.Idempotency.Gateway.Enabled = false
+	require.NoError(t, cfg.Validate())
+}
+
+func TestValidateGatewayUpstreamRequestIDHeader(t *testing.T) {
+	resetViperWithJWTSecret(t)
+
+	cfg, err := Load()
+	require.NoError(t, err)
+	require.Equal(t, "X-Request-Id", cfg.Gateway.UpstreamRequestIDHeader)
+	require.NoError(t, cfg.Validate())
+
+	cfg.Gateway.UpstreamRequestIDHeader = ""
+	require.NoError(t, cfg.Validate())
+	cfg.Gateway.UpstreamRequestIDHeader = "X-Request Id"
+	require.ErrorContains(t, cfg.Validate(), "gateway.upstream_request_id_header")
+}
+
+func TestValidateGatewayCompression(t *testing.T) {
+	resetViperWithJWTSecret(t)
+
+	cfg, err := Load()
+	require.NoError(t, err)
+	require.True(t, cfg.Gateway.Compression.Enabled)
+	require.False(t, cfg.Gateway.Compression.Stream)
+	require.Equal(t, 1024, cfg.Gateway.Compression.MinBytes)
+	require.Equal(t, []string{"br", "gzip"}, cfg.Gateway.Compression.Algorithms)
+	require.NoError(t, cfg.Validate())
+
+	cfg.Gateway.Compression.Algorithms = []string{"zstd"}
+	require.ErrorContains(t, cfg.Validate(), "gateway.compression.algorithms")
+	cfg.Gateway.Compression.Algorithms = []string{"gzip"}
+	cfg.Gateway.Compression.Routes = map[string]GatewayCompressionRouteConfig{"v1": {Enabled: true}}
+	require.ErrorContains(t, cfg.Validate(), "gateway.compression.routes")
+	cfg.Gateway.Compression.Routes = nil
+	cfg.Gateway.Compression.MinBytes = -1
+	require.ErrorContains(t, cfg.Validate(), "gateway.compression.min_bytes")
+}
+
+func TestValidateCORSConfig(t *testing.T) {
+	resetViperWithJWTSecret(t)
+
+	cfg, err := Load()
+	require.NoError(t, err)
+	require.Equal(t, 86400, cfg.CORS.MaxAgeSeconds)
+
+	cfg.CORS.AllowedOrigins = []string{"*", "https://app.example.com", "https://*.example.com"}
+	require.NoError(t, cfg.Validate())
+	cfg.CORS.AllowedOrigins = []string{"https://app.*.com"}
+	require.ErrorContains(t, cfg.Validate(), "cors.allowed_origins")
+	cfg.CORS.AllowedOrigins = nil
+	cfg.CORS.MaxAgeSeconds = -1
+	require.ErrorContains(t, cfg.Validate(), "cors.max_age_seconds")
+}
+
+func TestValidateGatewaySimulation(t *testing.T) {
+	resetViperWithJWTSecret(t)
+
+	cfg, err := Load()
+	require.NoError(t, err)
+	require.False(t, cfg.Gateway.Simulation.Enabled)
+	require.Equal(t, "canned", cfg.Gateway.Simulation.ResponseMode)
+	require.NotEmpty(t, cfg.Gateway.Simulation.CannedText)
+
+	cfg.Gateway.Simulation.ResponseMode = "echo"
+	require.NoError(t, cfg.Validate())
+
+	cfg.Gateway.Simulation.ResponseMode = "random"
+	require.ErrorContains(t, cfg.Validate(), "gateway.simulation.response_mode")
+
+	cfg.Gateway.Simulation.ResponseMode = "canned"
+	cfg.Gateway.Simulation.ChunkIntervalMS = -1
+	require.ErrorContains(t, cfg.Validate(), "gateway.simulation")
+}
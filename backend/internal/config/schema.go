@@ -0,0 +1,303 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"reflect"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// ConfigIssue 配置文件中的单个问题（未知配置项或值类型不匹配），附带 YAML 行列号
+type ConfigIssue struct {
+	Line    int
+	Column  int
+	Key     string
+	Message string
+	// Unknown 为 true 表示配置项不存在（启动时仅警告），否则为值无法转换（拒绝加载）
+	Unknown bool
+}
+
+func (i ConfigIssue) String() string {
+	return fmt.Sprintf("line %d:%d: %s: %s", i.Line, i.Column, i.Key, i.Message)
+}
+
+// ConfigFileError 配置文件未通过校验，列出全部问题
+type ConfigFileError struct {
+	File   string
+	Issues []ConfigIssue
+}
+
+func (e *ConfigFileError) Error() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "config file %s has %d problem(s):", e.File, len(e.Issues))
+	for _, issue := range e.Issues {
+		b.WriteString("\n  ")
+		b.WriteString(issue.String())
+	}
+	return b.String()
+}
+
+var durationType = reflect.TypeOf(time.Duration(0))
+
+// CheckConfigFile 按 Config 结构体校验配置文件：报告拼写错误等未知配置项，
+// 以及无法转换为目标类型的值（如 enabled: yes-please、port: "80a"）。
+// 文件无法读取或不是合法 YAML 时返回 error。
+func CheckConfigFile(path string) ([]ConfigIssue, error) {
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var doc yaml.Node
+	if err := yaml.Unmarshal(raw, &doc); err != nil {
+		return nil, fmt.Errorf("parse %s: %w", path, err)
+	}
+	if len(doc.Content) == 0 {
+		return nil, nil
+	}
+	var issues []ConfigIssue
+	checkConfigNode(doc.Content[0], reflect.TypeOf(Config{}), "", &issues)
+	sort.SliceStable(issues, func(i, j int) bool {
+		if issues[i].Line != issues[j].Line {
+			return issues[i].Line < issues[j].Line
+		}
+		return issues[i].Column < issues[j].Column
+	})
+	return issues, nil
+}
+
+func checkConfigNode(node *yaml.Node, typ reflect.Type, path string, issues *[]ConfigIssue) {
+	if node.Kind == yaml.AliasNode && node.Alias != nil {
+		node = node.Alias
+	}
+	if node.Kind == yaml.ScalarNode && node.Tag == "!!null" {
+		return
+	}
+	if typ.Kind() == reflect.Pointer {
+		typ = typ.Elem()
+	}
+	addIssue := func(msg string) {
+		*issues = append(*issues, ConfigIssue{Line: node.Line, Column: node.Column, Key: path, Message: msg})
+	}
+
+	if typ == durationType {
+		if node.Kind != yaml.ScalarNode || !isDurationScalar(node.Value) {
+			addIssue(fmt.Sprintf("expected duration (e.g. 30s, 5m), got %s", describeNode(node)))
+		}
+		return
+	}
+
+	switch typ.Kind() {
+	case reflect.Struct:
+		if node.Kind != yaml.MappingNode {
+			addIssue(fmt.Sprintf("expected mapping, got %s", describeNode(node)))
+			return
+		}
+		fields := configStructFields(typ)
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			keyNode, valueNode := node.Content[i], node.Content[i+1]
+			if keyNode.Value == "<<" {
+				checkConfigNode(valueNode, typ, path, issues)
+				continue
+			}
+			childPath := joinConfigPath(path, keyNode.Value)
+			field, ok := fields[strings.ToLower(keyNode.Value)]
+			if !ok {
+				msg := "unknown config key"
+				if suggestion := suggestConfigKey(keyNode.Value, fields); suggestion != "" {
+					msg += fmt.Sprintf(" (did you mean %q?)", suggestion)
+				}
+				*issues = append(*issues, ConfigIssue{Line: keyNode.Line, Column: keyNode.Column, Key: childPath, Message: msg, Unknown: true})
+				continue
+			}
+			checkConfigNode(valueNode, field.Type, childPath, issues)
+		}
+	case reflect.Map:
+		if node.Kind != yaml.MappingNode {
+			addIssue(fmt.Sprintf("expected mapping, got %s", describeNode(node)))
+			return
+		}
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			checkConfigNode(node.Content[i+1], typ.Elem(), joinConfigPath(path, node.Content[i].Value), issues)
+		}
+	case reflect.Slice, reflect.Array:
+		if node.Kind == yaml.ScalarNode && typ.Elem().Kind() == reflect.String {
+			// viper 允许以逗号/空格分隔的字符串表示字符串列表（便于环境变量配置）
+			return
+		}
+		if node.Kind != yaml.SequenceNode {
+			addIssue(fmt.Sprintf("expected list, got %s", describeNode(node)))
+			return
+		}
+		for i, item := range node.Content {
+			checkConfigNode(item, typ.Elem(), fmt.Sprintf("%s[%d]", path, i), issues)
+		}
+	case reflect.Interface:
+		return
+	default:
+		if node.Kind != yaml.ScalarNode {
+			addIssue(fmt.Sprintf("expected %s, got %s", typ.Kind(), describeNode(node)))
+			return
+		}
+		if msg := checkConfigScalar(node.Value, typ.Kind()); msg != "" {
+			addIssue(msg)
+		}
+	}
+}
+
+// checkConfigScalar 按 viper 弱类型转换规则检查标量能否解析为目标类型
+func checkConfigScalar(value string, kind reflect.Kind) string {
+	value = strings.TrimSpace(value)
+	switch kind {
+	case reflect.Bool:
+		if _, err := strconv.ParseBool(value); err != nil {
+			return fmt.Sprintf("expected boolean (true/false), got %q", value)
+		}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		if _, err := strconv.ParseInt(value, 0, 64); err != nil {
+			return fmt.Sprintf("expected integer, got %q", value)
+		}
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		if _, err := strconv.ParseUint(value, 0, 64); err != nil {
+			return fmt.Sprintf("expected non-negative integer, got %q", value)
+		}
+	case reflect.Float32, reflect.Float64:
+		if _, err := strconv.ParseFloat(value, 64); err != nil {
+			return fmt.Sprintf("expected number, got %q", value)
+		}
+	}
+	return ""
+}
+
+func isDurationScalar(value string) bool {
+	value = strings.TrimSpace(value)
+	if _, err := strconv.ParseInt(value, 10, 64); err == nil {
+		return true
+	}
+	_, err := time.ParseDuration(value)
+	return err == nil
+}
+
+// configStructFields 返回结构体的配置键（小写）到字段的映射，与 mapstructure 的匹配规则一致
+func configStructFields(typ reflect.Type) map[string]reflect.StructField {
+	fields := make(map[string]reflect.StructField, typ.NumField())
+	for i := 0; i < typ.NumField(); i++ {
+		field := typ.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+		tag := field.Tag.Get("mapstructure")
+		name, opts, _ := strings.Cut(tag, ",")
+		if name == "-" {
+			continue
+		}
+		if field.Anonymous && strings.Contains(opts, "squash") {
+			for key, inner := range configStructFields(field.Type) {
+				fields[key] = inner
+			}
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+		fields[strings.ToLower(name)] = field
+	}
+	return fields
+}
+
+// suggestConfigKey 在同级配置项中查找编辑距离最近的键，用于提示拼写错误
+func suggestConfigKey(key string, fields map[string]reflect.StructField) string {
+	key = strings.ToLower(key)
+	best, bestDist := "", 3
+	for candidate := range fields {
+		d := levenshtein(key, candidate)
+		if d < bestDist || (d == bestDist && best != "" && candidate < best) {
+			best, bestDist = candidate, d
+		}
+	}
+	if best == "" || bestDist > len(key)/2 {
+		return ""
+	}
+	return best
+}
+
+func levenshtein(a, b string) int {
+	prev := make([]int, len(b)+1)
+	curr := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		curr[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			curr[j] = min(prev[j]+1, curr[j-1]+1, prev[j-1]+cost)
+		}
+		prev, curr = curr, prev
+	}
+	return prev[len(b)]
+}
+
+func describeNode(node *yaml.Node) string {
+	switch node.Kind {
+	case yaml.MappingNode:
+		return "mapping"
+	case yaml.SequenceNode:
+		return "list"
+	default:
+		return strconv.Quote(node.Value)
+	}
+}
+
+func joinConfigPath(parent, key string) string {
+	if parent == "" {
+		return key
+	}
+	return parent + "." + key
+}
+
+// configKeyPattern 匹配校验错误信息开头的配置键（如 "account_health.failure_threshold must be positive"）
+var configKeyPattern = regexp.MustCompile(`^([a-z0-9_]+(?:\.[a-z0-9_]+)+)\b`)
+
+// locateConfigError 为 Validate 返回的错误补充配置文件中的行号；找不到对应配置项时原样返回
+func locateConfigError(path string, err error) error {
+	match := configKeyPattern.FindStringSubmatch(err.Error())
+	if match == nil {
+		return err
+	}
+	raw, readErr := os.ReadFile(path)
+	if readErr != nil {
+		return err
+	}
+	var doc yaml.Node
+	if yaml.Unmarshal(raw, &doc) != nil || len(doc.Content) == 0 {
+		return err
+	}
+	node := doc.Content[0]
+	var keyNode *yaml.Node
+	for _, part := range strings.Split(match[1], ".") {
+		if node.Kind != yaml.MappingNode {
+			return err
+		}
+		var next *yaml.Node
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			if strings.EqualFold(node.Content[i].Value, part) {
+				keyNode, next = node.Content[i], node.Content[i+1]
+				break
+			}
+		}
+		if next == nil {
+			return err
+		}
+		node = next
+	}
+	return fmt.Errorf("%w (%s line %d)", err, path, keyNode.Line)
+}
@@ -0,0 +1,96 @@
+package config
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func writeTestConfigFile(t *testing.T, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	require.NoError(t, os.WriteFile(path, []byte(content), 0o600))
+	return path
+}
+
+func TestCheckConfigFile_ReportsUnknownKeysAndInvalidValues(t *testing.T) {
+	path := writeTestConfigFile(t, `server:
+  hots: 0.0.0.0
+  port: 80a
+account_health:
+  enabled: maybe
+gateway:
+  route_timeouts:
+    messages:
+      ttfb_timeout_seconds: 30
+      bogus: 1
+cors:
+  allowed_origins: https://a.example.com
+pricing:
+  overrides:
+    - model: gpt-5
+      input_per_mtok: cheap
+`)
+
+	issues, err := CheckConfigFile(path)
+	require.NoError(t, err)
+	require.Equal(t, []ConfigIssue{
+		{Line: 2, Column: 3, Key: "server.hots", Message: `unknown config key (did you mean "host"?)`, Unknown: true},
+		{Line: 3, Column: 9, Key: "server.port", Message: `expected integer, got "80a"`},
+		{Line: 5, Column: 12, Key: "account_health.enabled", Message: `expected boolean (true/false), got "maybe"`},
+		{Line: 10, Column: 7, Key: "gateway.route_timeouts.messages.bogus", Message: "unknown config key", Unknown: true},
+		{Line: 16, Column: 23, Key: "pricing.overrides[0].input_per_mtok", Message: `expected number, got "cheap"`},
+	}, issues)
+}
+
+func TestCheckConfigFile_ExampleConfigIsClean(t *testing.T) {
+	issues, err := CheckConfigFile(filepath.Join("..", "..", "..", "deploy", "config.example.yaml"))
+	require.NoError(t, err)
+	require.Empty(t, issues)
+}
+
+func TestLoad_RejectsInvalidValuesAndWarnsUnknownKeys(t *testing.T) {
+	resetViperWithJWTSecret(t)
+	dir := t.TempDir()
+	t.Setenv("DATA_DIR", dir)
+	path := filepath.Join(dir, "config.yaml")
+
+	require.NoError(t, os.WriteFile(path, []byte("server:\n  prot: 9000\n"), 0o600))
+	cfg, err := Load()
+	require.NoError(t, err, "unknown keys only warn")
+	require.Equal(t, 8080, cfg.Server.Port)
+
+	resetViperWithJWTSecret(t)
+	require.NoError(t, os.WriteFile(path, []byte("server:\n  port: eighty\n"), 0o600))
+	_, err = Load()
+	var fileErr *ConfigFileError
+	require.True(t, errors.As(err, &fileErr))
+	require.Len(t, fileErr.Issues, 1)
+	require.Contains(t, err.Error(), "line 2:9: server.port")
+}
+
+func TestLoad_ValidationErrorIncludesLine(t *testing.T) {
+	resetViperWithJWTSecret(t)
+	dir := t.TempDir()
+	t.Setenv("DATA_DIR", dir)
+	path := filepath.Join(dir, "config.yaml")
+	require.NoError(t, os.WriteFile(path, []byte("cluster:\n  enabled: true\n  leader_lease_seconds: 1\n"), 0o600))
+
+	_, err := Load()
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "cluster.leader_lease_seconds must be at least 3 ("+path+" line 3)")
+}
+
+func TestValidateConfigFile(t *testing.T) {
+	resetViperWithJWTSecret(t)
+	path := writeTestConfigFile(t, "server:\n  prot: 9000\n")
+
+	file, issues, err := ValidateConfigFile(path)
+	require.NoError(t, err)
+	require.Equal(t, path, file)
+	require.Len(t, issues, 1)
+	require.True(t, issues[0].Unknown)
+}
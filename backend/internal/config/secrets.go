@@ -0,0 +1,190 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"reflect"
+	"strings"
+	"time"
+)
+
+const (
+	secretEnvScheme   = "env://"
+	secretVaultScheme = "vault://"
+)
+
+// secretVaultTimeout 单次读取 Vault 的超时
+const secretVaultTimeout = 10 * time.Second
+
+// resolveSecretRefs 将配置中形如 env://NAME 或 vault://<path>#<field> 的字符串值替换为实际内容，
+// 使数据库密码、JWT 密钥、OAuth client secret 等凭证无需明文写入配置文件。
+//
+//   - env://DB_PASSWORD：读取环境变量，未设置时报错
+//   - vault://secret/data/sub2api#db_password：通过 Vault HTTP API 读取 <path> 下的 <field>，
+//     兼容 KV v2（data.data）与 KV v1（data）；地址与令牌取自 VAULT_ADDR / VAULT_TOKEN，可选 VAULT_NAMESPACE
+//
+// 作用于所有字符串配置项（含字符串 map 的值与结构体列表），同一 Vault 路径在一次加载中只读取一次。
+func resolveSecretRefs(cfg *Config) error {
+	r := &secretResolver{vault: make(map[string]map[string]any)}
+	return r.walk(reflect.ValueOf(cfg).Elem(), "")
+}
+
+type secretResolver struct {
+	vault  map[string]map[string]any
+	client *http.Client
+}
+
+func (r *secretResolver) walk(v reflect.Value, path string) error {
+	switch v.Kind() {
+	case reflect.String:
+		resolved, ok, err := r.resolve(v.String())
+		if err != nil {
+			return fmt.Errorf("%s: %w", path, err)
+		}
+		if ok && v.CanSet() {
+			v.SetString(resolved)
+		}
+	case reflect.Pointer:
+		if !v.IsNil() {
+			return r.walk(v.Elem(), path)
+		}
+	case reflect.Struct:
+		t := v.Type()
+		for i := 0; i < t.NumField(); i++ {
+			field := t.Field(i)
+			if !field.IsExported() {
+				continue
+			}
+			name, _, _ := strings.Cut(field.Tag.Get("mapstructure"), ",")
+			if name == "-" {
+				continue
+			}
+			if name == "" {
+				name = strings.ToLower(field.Name)
+			}
+			if err := r.walk(v.Field(i), joinConfigPath(path, name)); err != nil {
+				return err
+			}
+		}
+	case reflect.Slice:
+		for i := 0; i < v.Len(); i++ {
+			if err := r.walk(v.Index(i), fmt.Sprintf("%s[%d]", path, i)); err != nil {
+				return err
+			}
+		}
+	case reflect.Map:
+		if v.Type().Elem().Kind() != reflect.String {
+			return nil
+		}
+		for _, key := range v.MapKeys() {
+			resolved, ok, err := r.resolve(v.MapIndex(key).String())
+			if err != nil {
+				return fmt.Errorf("%s: %w", joinConfigPath(path, fmt.Sprint(key.Interface())), err)
+			}
+			if ok {
+				v.SetMapIndex(key, reflect.ValueOf(resolved).Convert(v.Type().Elem()))
+			}
+		}
+	}
+	return nil
+}
+
+// resolve 解析单个值；非引用值返回 ok=false
+func (r *secretResolver) resolve(value string) (string, bool, error) {
+	ref := strings.TrimSpace(value)
+	switch {
+	case strings.HasPrefix(ref, secretEnvScheme):
+		name := strings.TrimPrefix(ref, secretEnvScheme)
+		if name == "" {
+			return "", false, fmt.Errorf("empty environment variable name in %q", ref)
+		}
+		secret, ok := os.LookupEnv(name)
+		if !ok {
+			return "", false, fmt.Errorf("environment variable %s is not set", name)
+		}
+		return secret, true, nil
+	case strings.HasPrefix(ref, secretVaultScheme):
+		secret, err := r.resolveVault(strings.TrimPrefix(ref, secretVaultScheme))
+		if err != nil {
+			return "", false, err
+		}
+		return secret, true, nil
+	default:
+		return value, false, nil
+	}
+}
+
+func (r *secretResolver) resolveVault(ref string) (string, error) {
+	path, field, ok := strings.Cut(ref, "#")
+	path = strings.Trim(path, "/")
+	if !ok || path == "" || field == "" {
+		return "", fmt.Errorf("invalid vault reference %q (expected vault://<path>#<field>)", secretVaultScheme+ref)
+	}
+
+	data, cached := r.vault[path]
+	if !cached {
+		var err error
+		if data, err = r.readVault(path); err != nil {
+			return "", err
+		}
+		r.vault[path] = data
+	}
+	value, ok := data[field]
+	if !ok {
+		return "", fmt.Errorf("vault secret %s has no field %q", path, field)
+	}
+	secret, ok := value.(string)
+	if !ok {
+		return "", fmt.Errorf("vault secret %s field %q is not a string", path, field)
+	}
+	return secret, nil
+}
+
+// readVault 读取 Vault 路径下的全部字段
+func (r *secretResolver) readVault(path string) (map[string]any, error) {
+	addr := strings.TrimRight(strings.TrimSpace(os.Getenv("VAULT_ADDR")), "/")
+	token := strings.TrimSpace(os.Getenv("VAULT_TOKEN"))
+	if addr == "" || token == "" {
+		return nil, fmt.Errorf("VAULT_ADDR and VAULT_TOKEN are required to resolve vault://%s", path)
+	}
+	if _, err := url.ParseRequestURI(addr); err != nil {
+		return nil, fmt.Errorf("invalid VAULT_ADDR: %w", err)
+	}
+	if r.client == nil {
+		r.client = &http.Client{Timeout: secretVaultTimeout}
+	}
+
+	req, err := http.NewRequest(http.MethodGet, addr+"/v1/"+path, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("X-Vault-Token", token)
+	if ns := strings.TrimSpace(os.Getenv("VAULT_NAMESPACE")); ns != "" {
+		req.Header.Set("X-Vault-Namespace", ns)
+	}
+	resp, err := r.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("read vault secret %s: %w", path, err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("read vault secret %s: HTTP %d", path, resp.StatusCode)
+	}
+
+	var body struct {
+		Data map[string]any `json:"data"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		return nil, fmt.Errorf("decode vault secret %s: %w", path, err)
+	}
+	// KV v2 的字段位于 data.data，KV v1 直接位于 data
+	if nested, ok := body.Data["data"].(map[string]any); ok {
+		if _, hasMeta := body.Data["metadata"]; hasMeta {
+			return nested, nil
+		}
+	}
+	return body.Data, nil
+}
@@ -0,0 +1,89 @@
+package config
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestResolveSecretRefs_Env(t *testing.T) {
+	t.Setenv("SUB2API_TEST_DB_PASSWORD", "s3cret")
+	cfg := &Config{
+		Database: DatabaseConfig{Password: "env://SUB2API_TEST_DB_PASSWORD"},
+		Redis:    RedisConfig{Password: "plain"},
+		Pricing:  PricingConfig{Overrides: []PricingOverrideConfig{{Model: "gpt-5"}}},
+		Sora:     SoraConfig{Client: SoraClientConfig{Headers: map[string]string{"Authorization": "env://SUB2API_TEST_DB_PASSWORD"}}},
+	}
+
+	require.NoError(t, resolveSecretRefs(cfg))
+	require.Equal(t, "s3cret", cfg.Database.Password)
+	require.Equal(t, "plain", cfg.Redis.Password)
+	require.Equal(t, "s3cret", cfg.Sora.Client.Headers["Authorization"])
+
+	cfg = &Config{JWT: JWTConfig{Secret: "env://SUB2API_TEST_MISSING"}}
+	err := resolveSecretRefs(cfg)
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "jwt.secret: environment variable SUB2API_TEST_MISSING is not set")
+}
+
+func TestResolveSecretRefs_Vault(t *testing.T) {
+	var requests atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		if r.Header.Get("X-Vault-Token") != "root" {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+		switch r.URL.Path {
+		case "/v1/secret/data/sub2api":
+			_, _ = w.Write([]byte(`{"data":{"data":{"db_password":"from-kv2","jwt_secret":"jwt-from-kv2"},"metadata":{"version":3}}}`))
+		case "/v1/kv/sub2api":
+			_, _ = w.Write([]byte(`{"data":{"redis_password":"from-kv1"}}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer srv.Close()
+	t.Setenv("VAULT_ADDR", srv.URL)
+	t.Setenv("VAULT_TOKEN", "root")
+
+	cfg := &Config{
+		Database: DatabaseConfig{Password: "vault://secret/data/sub2api#db_password"},
+		JWT:      JWTConfig{Secret: "vault://secret/data/sub2api#jwt_secret"},
+		Redis:    RedisConfig{Password: "vault://kv/sub2api#redis_password"},
+	}
+	require.NoError(t, resolveSecretRefs(cfg))
+	require.Equal(t, "from-kv2", cfg.Database.Password)
+	require.Equal(t, "jwt-from-kv2", cfg.JWT.Secret)
+	require.Equal(t, "from-kv1", cfg.Redis.Password)
+	require.Equal(t, int32(2), requests.Load(), "each vault path is read once")
+
+	err := resolveSecretRefs(&Config{Database: DatabaseConfig{Password: "vault://secret/data/sub2api#missing"}})
+	require.ErrorContains(t, err, `database.password: vault secret secret/data/sub2api has no field "missing"`)
+
+	err = resolveSecretRefs(&Config{Database: DatabaseConfig{Password: "vault://secret/data/sub2api"}})
+	require.ErrorContains(t, err, "expected vault://<path>#<field>")
+
+	err = resolveSecretRefs(&Config{Database: DatabaseConfig{Password: "vault://secret/data/other#x"}})
+	require.ErrorContains(t, err, "HTTP 404")
+}
+
+func TestLoad_ResolvesSecretRefsFromConfigFile(t *testing.T) {
+	resetViperWithJWTSecret(t)
+	dir := t.TempDir()
+	t.Setenv("DATA_DIR", dir)
+	t.Setenv("SUB2API_TEST_JWT", strings.Repeat("k", 40))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "config.yaml"), []byte("jwt:\n  secret: env://SUB2API_TEST_JWT\n"), 0o600))
+	// 环境变量 JWT_SECRET 优先于配置文件，此处清空以使用文件中的引用
+	t.Setenv("JWT_SECRET", "")
+
+	cfg, err := Load()
+	require.NoError(t, err)
+	require.Equal(t, strings.Repeat("k", 40), cfg.JWT.Secret)
+}
@@ -0,0 +1,93 @@
+package config
+
+import (
+	"fmt"
+	"log/slog"
+	"sync"
+
+	"github.com/fsnotify/fsnotify"
+	"github.com/spf13/viper"
+)
+
+var (
+	watchOnce      sync.Once
+	watchMu        sync.Mutex
+	watchListeners []func(*Config)
+	// reloadMu 串行化文件监听与手动重载（SIGHUP / 管理接口），避免并发读取配置文件
+	reloadMu sync.Mutex
+)
+
+// OnConfigReload 注册配置重载回调（配置文件变更、SIGHUP 或管理接口触发的重载）。
+//
+// 重载时会重新解析并校验完整配置，校验通过才回调；校验失败时保留旧配置。
+// 回调只应读取支持热更新的配置项（如 gateway.codex_cli_user_agent_prefixes、pricing.overrides），
+// 其余配置项（监听地址、数据库连接等）仍需重启生效。
+func OnConfigReload(listener func(*Config)) {
+	if listener == nil {
+		return
+	}
+	watchMu.Lock()
+	watchListeners = append(watchListeners, listener)
+	watchMu.Unlock()
+}
+
+// OnConfigFileChange 注册配置重载回调，并监听配置文件变更自动重载。
+// 未使用配置文件（仅环境变量/默认值）时返回 false，不会启动监听。
+func OnConfigFileChange(listener func(*Config)) bool {
+	if listener == nil || viper.ConfigFileUsed() == "" {
+		return false
+	}
+	OnConfigReload(listener)
+
+	watchOnce.Do(func() {
+		viper.OnConfigChange(func(e fsnotify.Event) {
+			if e.Op&(fsnotify.Write|fsnotify.Create) == 0 {
+				return
+			}
+			reloadMu.Lock()
+			defer reloadMu.Unlock()
+			cfg, err := decodeConfig(true)
+			if err != nil {
+				slog.Warn("config file reload rejected, keeping previous config", "file", e.Name, "error", err)
+				return
+			}
+			slog.Info("config file reloaded", "file", e.Name)
+			notifyConfigReload(cfg)
+		})
+		viper.WatchConfig()
+	})
+	return true
+}
+
+// Reload 重新读取配置文件并回调所有已注册的重载回调，返回新配置。
+// 配置文件解析或校验失败时返回错误，不回调、保留旧配置。
+func Reload() (*Config, error) {
+	reloadMu.Lock()
+	defer reloadMu.Unlock()
+	if file := viper.ConfigFileUsed(); file != "" {
+		if err := viper.ReadInConfig(); err != nil {
+			return nil, fmt.Errorf("read config file: %w", err)
+		}
+	}
+	cfg, err := decodeConfig(true)
+	if err != nil {
+		return nil, err
+	}
+	slog.Info("config reloaded", "file", viper.ConfigFileUsed())
+	notifyConfigReload(cfg)
+	return cfg, nil
+}
+
+// ConfigFileUsed 返回当前使用的配置文件路径，未使用配置文件时为空
+func ConfigFileUsed() string {
+	return viper.ConfigFileUsed()
+}
+
+func notifyConfigReload(cfg *Config) {
+	watchMu.Lock()
+	listeners := append([]func(*Config){}, watchListeners...)
+	watchMu.Unlock()
+	for _, fn := range listeners {
+		fn(cfg)
+	}
+}
@@ -0,0 +1,47 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestReloadNotifiesListenersAndRejectsInvalidConfig(t *testing.T) {
+	resetViperWithJWTSecret(t)
+	dir := t.TempDir()
+	t.Setenv("DATA_DIR", dir)
+	path := filepath.Join(dir, "config.yaml")
+	require.NoError(t, os.WriteFile(path, []byte("pricing:\n  overrides:\n    - model: gpt-5\n      input_per_mtok: 1\n"), 0o600))
+
+	_, err := Load()
+	require.NoError(t, err)
+	require.Equal(t, path, ConfigFileUsed())
+
+	watchMu.Lock()
+	saved := watchListeners
+	watchListeners = nil
+	watchMu.Unlock()
+	t.Cleanup(func() {
+		watchMu.Lock()
+		watchListeners = saved
+		watchMu.Unlock()
+	})
+
+	var got []*Config
+	OnConfigReload(func(cfg *Config) { got = append(got, cfg) })
+
+	require.NoError(t, os.WriteFile(path, []byte("pricing:\n  overrides:\n    - model: gpt-5\n      input_per_mtok: 2\n"), 0o600))
+	cfg, err := Reload()
+	require.NoError(t, err)
+	require.Len(t, got, 1)
+	require.Same(t, cfg, got[0])
+	require.Equal(t, 2.0, *cfg.Pricing.Overrides[0].InputPerMTok)
+
+	// 校验失败时不回调
+	require.NoError(t, os.WriteFile(path, []byte("pricing:\n  overrides:\n    - model: \"\"\n"), 0o600))
+	_, err = Reload()
+	require.Error(t, err)
+	require.Len(t, got, 1)
+}
@@ -8,12 +8,18 @@ const (
 	StatusUnused   = "unused"
 	StatusUsed     = "used"
 	StatusExpired  = "expired"
+	// StatusArchived 已归档（账号专用）：不参与调度与后台任务，保留凭证与用量统计，可恢复
+	StatusArchived = "archived"
 )
 
 // Role constants
 const (
 	RoleAdmin = "admin"
 	RoleUser  = "user"
+	// RoleOperator 运维角色：可访问管理后台并执行日常运营操作，不可修改系统设置与角色
+	RoleOperator = "operator"
+	// RoleViewer 只读角色：仅可查看管理后台数据
+	RoleViewer = "viewer"
 )
 
 // Platform constants
@@ -52,6 +52,8 @@ type DataAccount struct {
 	Concurrency        int            `json:"concurrency"`
 	Priority           int            `json:"priority"`
 	RateMultiplier     *float64       `json:"rate_multiplier,omitempty"`
+	LoadFactor         *int           `json:"load_factor,omitempty"`
+	Groups             []string       `json:"groups,omitempty"`
 	ExpiresAt          *int64         `json:"expires_at,omitempty"`
 	AutoPauseOnExpired *bool          `json:"auto_pause_on_expired,omitempty"`
 }
@@ -102,6 +104,17 @@ func (h *AccountHandler) ExportData(c *gin.Context) {
 		return
 	}
 
+	redactSecrets, err := parseBoolQuery(c, "redact_secrets", false)
+	if err != nil {
+		response.BadRequest(c, err.Error())
+		return
+	}
+	format := strings.TrimSpace(strings.ToLower(c.Query("format")))
+	if format != "" && format != "json" && format != "csv" {
+		response.BadRequest(c, "invalid format value: "+format)
+		return
+	}
+
 	var proxies []service.Proxy
 	if includeProxies {
 		proxies, err = h.resolveExportProxies(ctx, accounts)
@@ -117,7 +130,11 @@ func (h *AccountHandler) ExportData(c *gin.Context) {
 	dataProxies := make([]DataProxy, 0, len(proxies))
 	for i := range proxies {
 		p := proxies[i]
-		key := buildProxyKey(p.Protocol, p.Host, p.Port, p.Username, p.Password)
+		password := p.Password
+		if redactSecrets && password != "" {
+			password = redactedSecretValue
+		}
+		key := buildProxyKey(p.Protocol, p.Host, p.Port, p.Username, password)
 		proxyKeyByID[p.ID] = key
 		dataProxies = append(dataProxies, DataProxy{
 			ProxyKey: key,
@@ -126,7 +143,7 @@ func (h *AccountHandler) ExportData(c *gin.Context) {
 			Host:     p.Host,
 			Port:     p.Port,
 			Username: p.Username,
-			Password: p.Password,
+			Password: password,
 			Status:   p.Status,
 		})
 	}
@@ -145,17 +162,23 @@ func (h *AccountHandler) ExportData(c *gin.Context) {
 			v := acc.ExpiresAt.Unix()
 			expiresAt = &v
 		}
+		credentials := acc.Credentials
+		if redactSecrets {
+			credentials = redactCredentials(credentials)
+		}
 		dataAccounts = append(dataAccounts, DataAccount{
 			Name:               acc.Name,
 			Notes:              acc.Notes,
 			Platform:           acc.Platform,
 			Type:               acc.Type,
-			Credentials:        acc.Credentials,
+			Credentials:        credentials,
 			Extra:              acc.Extra,
 			ProxyKey:           proxyKey,
 			Concurrency:        acc.Concurrency,
 			Priority:           acc.Priority,
 			RateMultiplier:     acc.RateMultiplier,
+			LoadFactor:         acc.LoadFactor,
+			Groups:             accountGroupNames(acc),
 			ExpiresAt:          expiresAt,
 			AutoPauseOnExpired: &acc.AutoPauseOnExpired,
 		})
@@ -167,6 +190,10 @@ func (h *AccountHandler) ExportData(c *gin.Context) {
 		Accounts:   dataAccounts,
 	}
 
+	if format == "csv" {
+		writeAccountDataCSV(c, payload)
+		return
+	}
 	response.Success(c, payload)
 }
 
@@ -266,6 +293,11 @@ func (h *AccountHandler) importData(ctx context.Context, req DataImportRequest)
 		}
 	}
 
+	groupIDByName, err := h.resolveImportGroups(ctx, dataPayload.Accounts)
+	if err != nil {
+		return result, err
+	}
+
 	for i := range dataPayload.Accounts {
 		item := dataPayload.Accounts[i]
 		if err := validateDataAccount(item); err != nil {
@@ -294,6 +326,17 @@ func (h *AccountHandler) importData(ctx context.Context, req DataImportRequest)
 			}
 		}
 
+		groupIDs, missingGroup := mapGroupNames(item, groupIDByName)
+		if missingGroup != "" {
+			result.AccountFailed++
+			result.Errors = append(result.Errors, DataImportError{
+				Kind:    "account",
+				Name:    item.Name,
+				Message: "group not found: " + missingGroup,
+			})
+			continue
+		}
+
 		enrichCredentialsFromIDToken(&item)
 
 		accountInput := &service.CreateAccountInput{
@@ -307,7 +350,8 @@ func (h *AccountHandler) importData(ctx context.Context, req DataImportRequest)
 			Concurrency:          item.Concurrency,
 			Priority:             item.Priority,
 			RateMultiplier:       item.RateMultiplier,
-			GroupIDs:             nil,
+			LoadFactor:           item.LoadFactor,
+			GroupIDs:             groupIDs,
 			ExpiresAt:            item.ExpiresAt,
 			AutoPauseOnExpired:   item.AutoPauseOnExpired,
 			SkipDefaultGroupBind: skipDefaultGroupBind,
@@ -448,9 +492,13 @@ func parseAccountIDs(c *gin.Context) ([]int64, error) {
 }
 
 func parseIncludeProxies(c *gin.Context) (bool, error) {
-	raw := strings.TrimSpace(strings.ToLower(c.Query("include_proxies")))
+	return parseBoolQuery(c, "include_proxies", true)
+}
+
+func parseBoolQuery(c *gin.Context, name string, defaultValue bool) (bool, error) {
+	raw := strings.TrimSpace(strings.ToLower(c.Query(name)))
 	if raw == "" {
-		return true, nil
+		return defaultValue, nil
 	}
 	switch raw {
 	case "1", "true", "yes", "on":
@@ -458,7 +506,7 @@ func parseIncludeProxies(c *gin.Context) (bool, error) {
 	case "0", "false", "no", "off":
 		return false, nil
 	default:
-		return true, fmt.Errorf("invalid include_proxies value: %s", raw)
+		return defaultValue, fmt.Errorf("invalid %s value: %s", name, raw)
 	}
 }
 
@@ -529,6 +577,12 @@ func validateDataAccount(item DataAccount) error {
 	if item.Priority < 0 {
 		return errors.New("priority must be >= 0")
 	}
+	if item.LoadFactor != nil && *item.LoadFactor < 0 {
+		return errors.New("load_factor must be >= 0")
+	}
+	if credentialsRedacted(item.Credentials) {
+		return errors.New("account credentials are redacted")
+	}
 	return nil
 }
 
@@ -603,3 +657,104 @@ func normalizeProxyStatus(status string) string {
 		return normalized
 	}
 }
+
+// redactedSecretValue 导出时替换敏感字段的占位符；包含占位符的数据不能再次导入
+const redactedSecretValue = "__REDACTED__"
+
+func isSecretCredentialKey(key string) bool {
+	key = strings.ToLower(strings.TrimSpace(key))
+	switch key {
+	case "token", "cookie", "session_key", "client_secret", "password":
+		return true
+	}
+	return strings.HasSuffix(key, "_token") || strings.HasSuffix(key, "_key") || strings.HasSuffix(key, "_secret")
+}
+
+// redactCredentials 返回脱敏后的凭证副本，原 map 不会被修改
+func redactCredentials(credentials map[string]any) map[string]any {
+	if credentials == nil {
+		return nil
+	}
+	out := make(map[string]any, len(credentials))
+	for key, value := range credentials {
+		if isSecretCredentialKey(key) {
+			if s, ok := value.(string); !ok || s != "" {
+				value = redactedSecretValue
+			}
+		}
+		out[key] = value
+	}
+	return out
+}
+
+func credentialsRedacted(credentials map[string]any) bool {
+	for _, value := range credentials {
+		if s, ok := value.(string); ok && s == redactedSecretValue {
+			return true
+		}
+	}
+	return false
+}
+
+func accountGroupNames(acc service.Account) []string {
+	if len(acc.Groups) == 0 {
+		return nil
+	}
+	names := make([]string, 0, len(acc.Groups))
+	for _, group := range acc.Groups {
+		if group != nil && group.Name != "" {
+			names = append(names, group.Name)
+		}
+	}
+	return names
+}
+
+// resolveImportGroups 按名称索引现有分组；仅在导入数据引用了分组时查询
+func (h *AccountHandler) resolveImportGroups(ctx context.Context, accounts []DataAccount) (map[string][]service.Group, error) {
+	needed := false
+	for i := range accounts {
+		if len(accounts[i].Groups) > 0 {
+			needed = true
+			break
+		}
+	}
+	if !needed {
+		return nil, nil
+	}
+	groups, err := h.adminService.GetAllGroups(ctx)
+	if err != nil {
+		return nil, err
+	}
+	byName := make(map[string][]service.Group, len(groups))
+	for _, group := range groups {
+		byName[group.Name] = append(byName[group.Name], group)
+	}
+	return byName, nil
+}
+
+// mapGroupNames 将分组名转换为 ID（同名时优先选择与账号平台一致的分组），返回第一个不存在的分组名
+func mapGroupNames(item DataAccount, byName map[string][]service.Group) ([]int64, string) {
+	if len(item.Groups) == 0 {
+		return nil, ""
+	}
+	ids := make([]int64, 0, len(item.Groups))
+	for _, raw := range item.Groups {
+		name := strings.TrimSpace(raw)
+		if name == "" {
+			continue
+		}
+		candidates := byName[name]
+		if len(candidates) == 0 {
+			return nil, name
+		}
+		picked := candidates[0]
+		for _, group := range candidates {
+			if group.Platform == item.Platform {
+				picked = group
+				break
+			}
+		}
+		ids = append(ids, picked.ID)
+	}
+	return ids, ""
+}
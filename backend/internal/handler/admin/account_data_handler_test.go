@@ -70,6 +70,7 @@ func setupAccountDataRouter() (*gin.Engine, *stubAdminService) {
 
 	router.GET("/api/v1/admin/accounts/data", h.ExportData)
 	router.POST("/api/v1/admin/accounts/data", h.ImportData)
+	router.POST("/api/v1/admin/accounts/data/csv", h.ImportDataCSV)
 	return router, adminSvc
 }
 
@@ -130,6 +131,39 @@ func TestExportDataIncludesSecrets(t *testing.T) {
 	require.Equal(t, "secret", resp.Data.Accounts[0].Credentials["token"])
 }
 
+func TestExportDataRedactSecrets(t *testing.T) {
+	router, adminSvc := setupAccountDataRouter()
+
+	proxyID := int64(11)
+	adminSvc.proxies = []service.Proxy{
+		{ID: proxyID, Name: "proxy", Protocol: "http", Host: "127.0.0.1", Port: 8080, Username: "user", Password: "pass", Status: service.StatusActive},
+	}
+	adminSvc.accounts = []service.Account{
+		{
+			ID:          21,
+			Name:        "account",
+			Platform:    service.PlatformOpenAI,
+			Type:        service.AccountTypeOAuth,
+			Credentials: map[string]any{"refresh_token": "secret", "email": "a@example.com"},
+			ProxyID:     &proxyID,
+		},
+	}
+
+	rec := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/admin/accounts/data?redact_secrets=true", nil)
+	router.ServeHTTP(rec, req)
+	require.Equal(t, http.StatusOK, rec.Code)
+
+	var resp dataResponse
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &resp))
+	require.Equal(t, redactedSecretValue, resp.Data.Proxies[0].Password)
+	require.Equal(t, redactedSecretValue, resp.Data.Accounts[0].Credentials["refresh_token"])
+	require.Equal(t, "a@example.com", resp.Data.Accounts[0].Credentials["email"])
+	require.Equal(t, resp.Data.Proxies[0].ProxyKey, *resp.Data.Accounts[0].ProxyKey)
+	// 原始数据不被修改
+	require.Equal(t, "secret", adminSvc.accounts[0].Credentials["refresh_token"])
+}
+
 func TestExportDataWithoutProxies(t *testing.T) {
 	router, adminSvc := setupAccountDataRouter()
 
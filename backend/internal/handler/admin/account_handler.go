@@ -141,6 +141,7 @@ type BulkUpdateAccountsRequest struct {
 	Status                  string         `json:"status" binding:"omitempty,oneof=active inactive error"`
 	Schedulable             *bool          `json:"schedulable"`
 	GroupIDs                *[]int64       `json:"group_ids"`
+	GroupIDsMode            string         `json:"group_ids_mode" binding:"omitempty,oneof=replace add remove"` // 分组绑定方式，默认 replace；add/remove 用于批量打标签
 	Credentials             map[string]any `json:"credentials"`
 	Extra                   map[string]any `json:"extra"`
 	ConfirmMixedChannelRisk *bool          `json:"confirm_mixed_channel_risk"` // 用户确认混合渠道风险
@@ -324,6 +325,7 @@ func (h *AccountHandler) List(c *gin.Context) {
 			Account:            dto.AccountFromService(acc),
 			CurrentConcurrency: concurrencyCounts[acc.ID],
 		}
+		redactAccountSecrets(c, item.Account)
 
 		// 添加窗口费用（仅当启用时）
 		if windowCosts != nil {
@@ -432,7 +434,9 @@ func (h *AccountHandler) GetByID(c *gin.Context) {
 		return
 	}
 
-	response.Success(c, h.buildAccountResponseWithRuntime(c.Request.Context(), account))
+	item := h.buildAccountResponseWithRuntime(c.Request.Context(), account)
+	redactAccountSecrets(c, item.Account)
+	response.Success(c, item)
 }
 
 // CheckMixedChannel handles checking mixed channel risk for account-group binding.
@@ -518,6 +522,7 @@ func (h *AccountHandler) Create(c *gin.Context) {
 		if execErr != nil {
 			return nil, execErr
 		}
+		setAuditSnapshot(c, nil, dto.AccountFromService(account))
 		return h.buildAccountResponseWithRuntime(ctx, account), nil
 	})
 	if err != nil {
@@ -569,6 +574,9 @@ func (h *AccountHandler) Update(c *gin.Context) {
 	// 确定是否跳过混合渠道检查
 	skipCheck := req.ConfirmMixedChannelRisk != nil && *req.ConfirmMixedChannelRisk
 
+	// 审计快照：变更前状态，获取失败时仅记录请求体
+	before, _ := h.adminService.GetAccount(c.Request.Context(), accountID)
+
 	account, err := h.adminService.UpdateAccount(c.Request.Context(), accountID, &service.UpdateAccountInput{
 		Name:                  req.Name,
 		Notes:                 req.Notes,
@@ -602,6 +610,9 @@ func (h *AccountHandler) Update(c *gin.Context) {
 		return
 	}
 
+	if before != nil {
+		setAuditSnapshot(c, dto.AccountFromService(before), dto.AccountFromService(account))
+	}
 	response.Success(c, h.buildAccountResponseWithRuntime(c.Request.Context(), account))
 }
 
@@ -614,11 +625,15 @@ func (h *AccountHandler) Delete(c *gin.Context) {
 		return
 	}
 
+	before, _ := h.adminService.GetAccount(c.Request.Context(), accountID)
 	err = h.adminService.DeleteAccount(c.Request.Context(), accountID)
 	if err != nil {
 		response.ErrorFrom(c, err)
 		return
 	}
+	if before != nil {
+		setAuditSnapshot(c, dto.AccountFromService(before), nil)
+	}
 
 	response.Success(c, gin.H{"message": "Account deleted successfully"})
 }
@@ -894,6 +909,53 @@ func (h *AccountHandler) GetStats(c *gin.Context) {
 	response.Success(c, stats)
 }
 
+// GetUsageStats handles getting bucketed usage statistics for an account
+// GET /api/v1/admin/accounts/:id/usage-stats?granularity=hour|day&days=N
+// granularity=hour 默认最近 2 天（最多 7 天），granularity=day 默认最近 30 天（最多 90 天）
+func (h *AccountHandler) GetUsageStats(c *gin.Context) {
+	accountID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		response.BadRequest(c, "Invalid account ID")
+		return
+	}
+
+	granularity := c.DefaultQuery("granularity", "day")
+	defaultDays, maxDays := 30, 90
+	switch granularity {
+	case "day":
+	case "hour":
+		defaultDays, maxDays = 2, 7
+	default:
+		response.BadRequest(c, "Invalid granularity, must be hour or day")
+		return
+	}
+
+	days := defaultDays
+	if daysStr := c.Query("days"); daysStr != "" {
+		if d, err := strconv.Atoi(daysStr); err == nil && d > 0 && d <= maxDays {
+			days = d
+		}
+	}
+
+	now := timezone.Now()
+	var startTime, endTime time.Time
+	if granularity == "hour" {
+		endTime = now.Truncate(time.Hour).Add(time.Hour)
+		startTime = endTime.Add(-time.Duration(days*24) * time.Hour)
+	} else {
+		endTime = timezone.StartOfDay(now.AddDate(0, 0, 1))
+		startTime = timezone.StartOfDay(now.AddDate(0, 0, -days+1))
+	}
+
+	stats, err := h.accountUsageService.GetAccountUsageBuckets(c.Request.Context(), accountID, startTime, endTime, granularity)
+	if err != nil {
+		response.ErrorFrom(c, err)
+		return
+	}
+
+	response.Success(c, stats)
+}
+
 // ClearError handles clearing account error
 // POST /api/v1/admin/accounts/:id/clear-error
 func (h *AccountHandler) ClearError(c *gin.Context) {
@@ -1129,6 +1191,7 @@ func (h *AccountHandler) BulkUpdate(c *gin.Context) {
 		Status:                req.Status,
 		Schedulable:           req.Schedulable,
 		GroupIDs:              req.GroupIDs,
+		GroupIDsMode:          req.GroupIDsMode,
 		Credentials:           req.Credentials,
 		Extra:                 req.Extra,
 		SkipMixedChannelCheck: skipCheck,
@@ -1318,6 +1381,24 @@ func (h *AccountHandler) GetUsage(c *gin.Context) {
 	response.Success(c, usage)
 }
 
+// GetUpstreamLimits queries upstream for the account's current rate-limit windows and plan status
+// GET /api/v1/admin/accounts/:id/upstream-limits
+func (h *AccountHandler) GetUpstreamLimits(c *gin.Context) {
+	accountID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		response.BadRequest(c, "Invalid account ID")
+		return
+	}
+
+	limits, err := h.accountUsageService.QueryUpstreamLimits(c.Request.Context(), accountID)
+	if err != nil {
+		response.ErrorFrom(c, err)
+		return
+	}
+
+	response.Success(c, limits)
+}
+
 // ClearRateLimit handles clearing account rate limit status
 // POST /api/v1/admin/accounts/:id/clear-rate-limit
 func (h *AccountHandler) ClearRateLimit(c *gin.Context) {
@@ -1391,6 +1472,31 @@ func (h *AccountHandler) GetTempUnschedulable(c *gin.Context) {
 	})
 }
 
+// ListCooldowns returns recent 429 cooldown records across all accounts
+// GET /api/v1/admin/accounts/cooldowns
+func (h *AccountHandler) ListCooldowns(c *gin.Context) {
+	limit := 100
+	if l, err := strconv.Atoi(c.Query("limit")); err == nil && l > 0 {
+		limit = l
+	}
+	response.Success(c, h.rateLimitService.ListCooldownHistory(0, limit))
+}
+
+// GetCooldowns returns recent 429 cooldown records for one account
+// GET /api/v1/admin/accounts/:id/cooldowns
+func (h *AccountHandler) GetCooldowns(c *gin.Context) {
+	accountID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		response.BadRequest(c, "Invalid account ID")
+		return
+	}
+	limit := 50
+	if l, err := strconv.Atoi(c.Query("limit")); err == nil && l > 0 {
+		limit = l
+	}
+	response.Success(c, h.rateLimitService.ListCooldownHistory(accountID, limit))
+}
+
 // ClearTempUnschedulable handles clearing temporary unschedulable status
 // DELETE /api/v1/admin/accounts/:id/temp-unschedulable
 func (h *AccountHandler) ClearTempUnschedulable(c *gin.Context) {
@@ -1506,6 +1612,42 @@ func (h *AccountHandler) SetSchedulable(c *gin.Context) {
 	response.Success(c, h.buildAccountResponseWithRuntime(c.Request.Context(), account))
 }
 
+// Archive handles archiving an account
+// POST /api/v1/admin/accounts/:id/archive
+func (h *AccountHandler) Archive(c *gin.Context) {
+	accountID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		response.BadRequest(c, "Invalid account ID")
+		return
+	}
+
+	account, err := h.adminService.ArchiveAccount(c.Request.Context(), accountID)
+	if err != nil {
+		response.ErrorFrom(c, err)
+		return
+	}
+
+	response.Success(c, h.buildAccountResponseWithRuntime(c.Request.Context(), account))
+}
+
+// Restore handles restoring an archived account
+// POST /api/v1/admin/accounts/:id/restore
+func (h *AccountHandler) Restore(c *gin.Context) {
+	accountID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		response.BadRequest(c, "Invalid account ID")
+		return
+	}
+
+	account, err := h.adminService.RestoreAccount(c.Request.Context(), accountID)
+	if err != nil {
+		response.ErrorFrom(c, err)
+		return
+	}
+
+	response.Success(c, h.buildAccountResponseWithRuntime(c.Request.Context(), account))
+}
+
 // GetAvailableModels handles getting available models for an account
 // GET /api/v1/admin/accounts/:id/models
 func (h *AccountHandler) GetAvailableModels(c *gin.Context) {
@@ -0,0 +1,70 @@
+package admin
+
+import (
+	"strconv"
+
+	"github.com/ShaohongDong/sub2api/internal/pkg/response"
+	"github.com/ShaohongDong/sub2api/internal/service"
+	"github.com/gin-gonic/gin"
+)
+
+// AccountHealthHandler exposes account health-check state to admins.
+type AccountHealthHandler struct {
+	healthService *service.AccountHealthService
+}
+
+// NewAccountHealthHandler creates a new AccountHealthHandler.
+func NewAccountHealthHandler(healthService *service.AccountHealthService) *AccountHealthHandler {
+	return &AccountHealthHandler{healthService: healthService}
+}
+
+// List returns health state for every tracked account
+// GET /api/v1/admin/accounts/health
+func (h *AccountHealthHandler) List(c *gin.Context) {
+	states := h.healthService.ListStates()
+	unhealthy := 0
+	for _, st := range states {
+		if !st.Healthy {
+			unhealthy++
+		}
+	}
+	response.Success(c, gin.H{
+		"items":     states,
+		"total":     len(states),
+		"unhealthy": unhealthy,
+	})
+}
+
+// Get returns health state for one account
+// GET /api/v1/admin/accounts/:id/health
+func (h *AccountHealthHandler) Get(c *gin.Context) {
+	accountID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		response.BadRequest(c, "Invalid account ID")
+		return
+	}
+
+	state := h.healthService.GetState(accountID)
+	if state == nil {
+		response.Success(c, gin.H{"tracked": false, "healthy": true})
+		return
+	}
+	response.Success(c, gin.H{
+		"tracked": true,
+		"healthy": state.Healthy,
+		"state":   state,
+	})
+}
+
+// Reset drops the tracked health state for one account
+// DELETE /api/v1/admin/accounts/:id/health
+func (h *AccountHealthHandler) Reset(c *gin.Context) {
+	accountID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		response.BadRequest(c, "Invalid account ID")
+		return
+	}
+
+	h.healthService.Reset(accountID)
+	response.Success(c, gin.H{"message": "Account health state reset"})
+}
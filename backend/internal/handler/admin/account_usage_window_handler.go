@@ -0,0 +1,55 @@
+package admin
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/ShaohongDong/sub2api/internal/pkg/response"
+	"github.com/ShaohongDong/sub2api/internal/service"
+	"github.com/gin-gonic/gin"
+)
+
+// AccountUsageWindowHandler exposes locally tracked Codex usage windows to admins.
+type AccountUsageWindowHandler struct {
+	adminService         service.AdminService
+	openAIGatewayService *service.OpenAIGatewayService
+}
+
+// NewAccountUsageWindowHandler creates a new AccountUsageWindowHandler.
+func NewAccountUsageWindowHandler(adminService service.AdminService, openAIGatewayService *service.OpenAIGatewayService) *AccountUsageWindowHandler {
+	return &AccountUsageWindowHandler{
+		adminService:         adminService,
+		openAIGatewayService: openAIGatewayService,
+	}
+}
+
+// Get returns 5h / 7d usage and predicted headroom for one account
+// GET /api/v1/admin/accounts/:id/usage-windows
+func (h *AccountUsageWindowHandler) Get(c *gin.Context) {
+	accountID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		response.BadRequest(c, "Invalid account ID")
+		return
+	}
+
+	account, err := h.adminService.GetAccount(c.Request.Context(), accountID)
+	if err != nil {
+		response.ErrorFrom(c, err)
+		return
+	}
+	if !account.IsOpenAIOAuth() {
+		response.BadRequest(c, "Usage windows are only tracked for OpenAI OAuth accounts")
+		return
+	}
+
+	windows := h.openAIGatewayService.OpenAIUsageWindowStatuses(account, time.Now())
+	willExceed := false
+	for _, w := range windows {
+		willExceed = willExceed || w.WillExceed
+	}
+	response.Success(c, gin.H{
+		"account_id":  account.ID,
+		"windows":     windows,
+		"will_exceed": willExceed,
+	})
+}
@@ -0,0 +1,49 @@
+package admin
+
+import (
+	"github.com/ShaohongDong/sub2api/internal/pkg/response"
+	"github.com/ShaohongDong/sub2api/internal/service"
+	"github.com/gin-gonic/gin"
+)
+
+// AccountValidationHandler runs bulk account validation for admins.
+type AccountValidationHandler struct {
+	validationService *service.AccountValidationService
+}
+
+// NewAccountValidationHandler creates a new AccountValidationHandler.
+func NewAccountValidationHandler(validationService *service.AccountValidationService) *AccountValidationHandler {
+	return &AccountValidationHandler{validationService: validationService}
+}
+
+// ValidateAccountsRequest 批量验证请求；account_ids 为空时验证全部账号（可按 platform 过滤）
+type ValidateAccountsRequest struct {
+	AccountIDs  []int64 `json:"account_ids"`
+	Platform    string  `json:"platform"`
+	Model       string  `json:"model"`
+	Concurrency int     `json:"concurrency" binding:"omitempty,min=1,max=20"`
+}
+
+// Validate tests the selected (or all) accounts concurrently and returns per-account results
+// POST /api/v1/admin/accounts/validate
+func (h *AccountValidationHandler) Validate(c *gin.Context) {
+	var req ValidateAccountsRequest
+	if c.Request.ContentLength != 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			response.BadRequest(c, "Invalid request: "+err.Error())
+			return
+		}
+	}
+
+	report, err := h.validationService.Validate(c.Request.Context(), service.AccountValidationRequest{
+		AccountIDs:  req.AccountIDs,
+		Platform:    req.Platform,
+		Model:       req.Model,
+		Concurrency: req.Concurrency,
+	})
+	if err != nil {
+		response.ErrorFrom(c, err)
+		return
+	}
+	response.Success(c, report)
+}
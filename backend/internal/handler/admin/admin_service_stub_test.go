@@ -235,6 +235,16 @@ func (s *stubAdminService) SetAccountSchedulable(ctx context.Context, id int64,
 	return &account, nil
 }
 
+func (s *stubAdminService) ArchiveAccount(ctx context.Context, id int64) (*service.Account, error) {
+	account := service.Account{ID: id, Name: "account", Status: service.StatusArchived}
+	return &account, nil
+}
+
+func (s *stubAdminService) RestoreAccount(ctx context.Context, id int64) (*service.Account, error) {
+	account := service.Account{ID: id, Name: "account", Status: service.StatusActive, Schedulable: true}
+	return &account, nil
+}
+
 func (s *stubAdminService) BulkUpdateAccounts(ctx context.Context, input *service.BulkUpdateAccountsInput) (*service.BulkUpdateAccountsResult, error) {
 	if s.bulkUpdateAccountErr != nil {
 		return nil, s.bulkUpdateAccountErr
@@ -1,7 +1,10 @@
 package admin
 
 import (
+	"errors"
+	"io"
 	"strconv"
+	"time"
 
 	"github.com/ShaohongDong/sub2api/internal/handler/dto"
 	"github.com/ShaohongDong/sub2api/internal/pkg/response"
@@ -12,16 +15,323 @@ import (
 
 // AdminAPIKeyHandler handles admin API key management
 type AdminAPIKeyHandler struct {
-	adminService service.AdminService
+	adminService  service.AdminService
+	apiKeyService *service.APIKeyService
 }
 
 // NewAdminAPIKeyHandler creates a new admin API key handler
-func NewAdminAPIKeyHandler(adminService service.AdminService) *AdminAPIKeyHandler {
+func NewAdminAPIKeyHandler(adminService service.AdminService, apiKeyService *service.APIKeyService) *AdminAPIKeyHandler {
 	return &AdminAPIKeyHandler{
-		adminService: adminService,
+		adminService:  adminService,
+		apiKeyService: apiKeyService,
 	}
 }
 
+// AdminCreateAPIKeyRequest represents the request to create an API key on behalf of a user
+type AdminCreateAPIKeyRequest struct {
+	UserID            int64    `json:"user_id" binding:"required,gt=0"`
+	Name              string   `json:"name" binding:"required"`
+	Description       string   `json:"description" binding:"max=500"`
+	GroupID           *int64   `json:"group_id"`
+	Scopes            []string `json:"scopes"`             // 可访问的端点类别，空表示不限制
+	AllowedModels     []string `json:"allowed_models"`     // 可使用的模型（支持末尾 * 通配符），空表示不限制
+	ClientRestriction string   `json:"client_restriction"` // 允许的客户端类别，空表示不限制
+	Tags              []string `json:"tags"`               // 路由标签，供路由规则匹配
+	IPWhitelist       []string `json:"ip_whitelist"`       // IP 白名单
+	IPBlacklist       []string `json:"ip_blacklist"`       // IP 黑名单
+	Quota             float64  `json:"quota"`              // 配额限制 (USD)，0=无限制
+	ExpiresInDays     *int     `json:"expires_in_days"`    // 过期天数，nil=永不过期
+
+	// Request / token limits (0 = unlimited)
+	RPMLimit            int   `json:"rpm_limit" binding:"min=0"`
+	TPMLimit            int   `json:"tpm_limit" binding:"min=0"`
+	DailyRequestLimit   int   `json:"daily_request_limit" binding:"min=0"`
+	DailyTokenLimit     int64 `json:"daily_token_limit" binding:"min=0"`
+	MonthlyRequestLimit int   `json:"monthly_request_limit" binding:"min=0"`
+	MonthlyTokenLimit   int64 `json:"monthly_token_limit" binding:"min=0"`
+
+	// Spend budget (0 = no budget)
+	BudgetAmount        float64 `json:"budget_amount" binding:"min=0"`
+	BudgetPeriod        string  `json:"budget_period" binding:"omitempty,oneof=day week month"`
+	BudgetAction        string  `json:"budget_action" binding:"omitempty,oneof=disable downgrade"`
+	BudgetFallbackModel string  `json:"budget_fallback_model" binding:"max=100"`
+
+	// Degraded mode: model served when every account for the requested model is unavailable ('' = return 503)
+	DegradedFallbackModel string `json:"degraded_fallback_model" binding:"max=100"`
+
+	// System prompt injected into every request ('' = none)
+	SystemPrompt     string `json:"system_prompt"`
+	SystemPromptMode string `json:"system_prompt_mode" binding:"omitempty,oneof=prepend append replace"`
+
+	// Prompt moderation pre-filter: off / flag / block ('' = follow gateway.moderation.pre_filter)
+	ModerationMode string `json:"moderation_mode" binding:"omitempty,oneof=off flag block"`
+}
+
+// Create handles creating an API key for a user
+// POST /api/v1/admin/api-keys
+func (h *AdminAPIKeyHandler) Create(c *gin.Context) {
+	var req AdminCreateAPIKeyRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "Invalid request: "+err.Error())
+		return
+	}
+
+	key, err := h.apiKeyService.Create(c.Request.Context(), req.UserID, service.CreateAPIKeyRequest{
+		Name:              req.Name,
+		Description:       req.Description,
+		GroupID:           req.GroupID,
+		Scopes:            req.Scopes,
+		AllowedModels:     req.AllowedModels,
+		ClientRestriction: req.ClientRestriction,
+		Tags:              req.Tags,
+		IPWhitelist:       req.IPWhitelist,
+		IPBlacklist:       req.IPBlacklist,
+		Quota:             req.Quota,
+		ExpiresInDays:     req.ExpiresInDays,
+
+		RPMLimit:            req.RPMLimit,
+		TPMLimit:            req.TPMLimit,
+		DailyRequestLimit:   req.DailyRequestLimit,
+		DailyTokenLimit:     req.DailyTokenLimit,
+		MonthlyRequestLimit: req.MonthlyRequestLimit,
+		MonthlyTokenLimit:   req.MonthlyTokenLimit,
+
+		BudgetAmount:        req.BudgetAmount,
+		BudgetPeriod:        req.BudgetPeriod,
+		BudgetAction:        req.BudgetAction,
+		BudgetFallbackModel: req.BudgetFallbackModel,
+
+		DegradedFallbackModel: req.DegradedFallbackModel,
+
+		SystemPrompt:     req.SystemPrompt,
+		SystemPromptMode: req.SystemPromptMode,
+
+		ModerationMode: req.ModerationMode,
+	})
+	if err != nil {
+		response.ErrorFrom(c, err)
+		return
+	}
+
+	setAuditSnapshot(c, nil, dto.APIKeyFromService(key))
+	response.Created(c, dto.APIKeyFromService(key))
+}
+
+// Revoke handles revoking (soft-deleting) an API key
+// POST /api/v1/admin/api-keys/:id/revoke
+func (h *AdminAPIKeyHandler) Revoke(c *gin.Context) {
+	keyID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		response.BadRequest(c, "Invalid API key ID")
+		return
+	}
+
+	before := h.auditBefore(c, keyID)
+	if err := h.apiKeyService.Revoke(c.Request.Context(), keyID); err != nil {
+		response.ErrorFrom(c, err)
+		return
+	}
+	if before != nil {
+		setAuditSnapshot(c, before, nil)
+	}
+
+	response.Success(c, gin.H{"message": "API key revoked successfully"})
+}
+
+// AdminRotateAPIKeyRequest represents the request to rotate an API key's secret
+type AdminRotateAPIKeyRequest struct {
+	GracePeriodMinutes int `json:"grace_period_minutes" binding:"min=0,max=10080"` // 旧密钥继续可用的分钟数，0=立即失效
+}
+
+// Rotate handles issuing a new secret for an API key while keeping its settings and usage history
+// POST /api/v1/admin/api-keys/:id/rotate
+func (h *AdminAPIKeyHandler) Rotate(c *gin.Context) {
+	keyID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		response.BadRequest(c, "Invalid API key ID")
+		return
+	}
+
+	var req AdminRotateAPIKeyRequest
+	if err := c.ShouldBindJSON(&req); err != nil && !errors.Is(err, io.EOF) {
+		response.BadRequest(c, "Invalid request: "+err.Error())
+		return
+	}
+
+	before := h.auditBefore(c, keyID)
+	key, err := h.apiKeyService.Rotate(c.Request.Context(), keyID, time.Duration(req.GracePeriodMinutes)*time.Minute)
+	if err != nil {
+		response.ErrorFrom(c, err)
+		return
+	}
+	h.auditAfter(c, before, key)
+
+	response.Success(c, dto.APIKeyFromService(key))
+}
+
+// AdminUpdateAPIKeyTagsRequest represents the request to replace an API key's routing tags
+type AdminUpdateAPIKeyTagsRequest struct {
+	Tags []string `json:"tags"` // 空数组清空标签
+}
+
+// UpdateTags handles replacing an API key's routing tags
+// PUT /api/v1/admin/api-keys/:id/tags
+func (h *AdminAPIKeyHandler) UpdateTags(c *gin.Context) {
+	keyID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		response.BadRequest(c, "Invalid API key ID")
+		return
+	}
+
+	var req AdminUpdateAPIKeyTagsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "Invalid request: "+err.Error())
+		return
+	}
+
+	before := h.auditBefore(c, keyID)
+	key, err := h.apiKeyService.SetTags(c.Request.Context(), keyID, req.Tags)
+	if err != nil {
+		response.ErrorFrom(c, err)
+		return
+	}
+	h.auditAfter(c, before, key)
+
+	response.Success(c, dto.APIKeyFromService(key))
+}
+
+// AdminUpdateAPIKeySystemPromptRequest represents the request to set an API key's system prompt
+type AdminUpdateAPIKeySystemPromptRequest struct {
+	SystemPrompt     string `json:"system_prompt"` // 空字符串清除
+	SystemPromptMode string `json:"system_prompt_mode" binding:"omitempty,oneof=prepend append replace"`
+}
+
+// UpdateSystemPrompt handles setting the system prompt injected into every request made with an API key
+// PUT /api/v1/admin/api-keys/:id/system-prompt
+func (h *AdminAPIKeyHandler) UpdateSystemPrompt(c *gin.Context) {
+	keyID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		response.BadRequest(c, "Invalid API key ID")
+		return
+	}
+
+	var req AdminUpdateAPIKeySystemPromptRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "Invalid request: "+err.Error())
+		return
+	}
+
+	before := h.auditBefore(c, keyID)
+	key, err := h.apiKeyService.SetSystemPrompt(c.Request.Context(), keyID, req.SystemPrompt, req.SystemPromptMode)
+	if err != nil {
+		response.ErrorFrom(c, err)
+		return
+	}
+	h.auditAfter(c, before, key)
+
+	response.Success(c, dto.APIKeyFromService(key))
+}
+
+// AdminUpdateAPIKeyModerationRequest represents the request to set an API key's moderation pre-filter mode
+type AdminUpdateAPIKeyModerationRequest struct {
+	ModerationMode string `json:"moderation_mode" binding:"omitempty,oneof=off flag block"` // 空字符串沿用全局配置
+}
+
+// UpdateModeration handles setting how an API key's prompts are checked by the moderation pre-filter
+// PUT /api/v1/admin/api-keys/:id/moderation
+func (h *AdminAPIKeyHandler) UpdateModeration(c *gin.Context) {
+	keyID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		response.BadRequest(c, "Invalid API key ID")
+		return
+	}
+
+	var req AdminUpdateAPIKeyModerationRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "Invalid request: "+err.Error())
+		return
+	}
+
+	before := h.auditBefore(c, keyID)
+	key, err := h.apiKeyService.SetModerationMode(c.Request.Context(), keyID, req.ModerationMode)
+	if err != nil {
+		response.ErrorFrom(c, err)
+		return
+	}
+	h.auditAfter(c, before, key)
+
+	response.Success(c, dto.APIKeyFromService(key))
+}
+
+// AdminUpdateAPIKeyContentLoggingRequest represents the request to toggle prompt/response logging for an API key
+type AdminUpdateAPIKeyContentLoggingRequest struct {
+	LogContent *bool `json:"log_content" binding:"required"`
+}
+
+// UpdateContentLogging handles enabling or disabling full prompt/response logging for an API key
+// PUT /api/v1/admin/api-keys/:id/content-logging
+func (h *AdminAPIKeyHandler) UpdateContentLogging(c *gin.Context) {
+	keyID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		response.BadRequest(c, "Invalid API key ID")
+		return
+	}
+
+	var req AdminUpdateAPIKeyContentLoggingRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "Invalid request: "+err.Error())
+		return
+	}
+
+	before := h.auditBefore(c, keyID)
+	key, err := h.apiKeyService.SetContentLogging(c.Request.Context(), keyID, *req.LogContent)
+	if err != nil {
+		response.ErrorFrom(c, err)
+		return
+	}
+	h.auditAfter(c, before, key)
+
+	response.Success(c, dto.APIKeyFromService(key))
+}
+
+// AdminUpdateAPIKeySimulationRequest represents the request to toggle dry-run mode for an API key
+type AdminUpdateAPIKeySimulationRequest struct {
+	Simulate *bool `json:"simulate" binding:"required"`
+}
+
+// UpdateSimulation handles enabling or disabling dry-run mode for an API key: its requests are answered by
+// the built-in mock upstream and never reach real accounts
+// PUT /api/v1/admin/api-keys/:id/simulation
+func (h *AdminAPIKeyHandler) UpdateSimulation(c *gin.Context) {
+	keyID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		response.BadRequest(c, "Invalid API key ID")
+		return
+	}
+
+	var req AdminUpdateAPIKeySimulationRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "Invalid request: "+err.Error())
+		return
+	}
+
+	before := h.auditBefore(c, keyID)
+	key, err := h.apiKeyService.SetSimulation(c.Request.Context(), keyID, *req.Simulate)
+	if err != nil {
+		response.ErrorFrom(c, err)
+		return
+	}
+	h.auditAfter(c, before, key)
+
+	response.Success(c, dto.APIKeyFromService(key))
+}
+
+// ListScopes returns the endpoint scopes an API key can be restricted to
+// GET /api/v1/admin/api-keys/scopes
+func (h *AdminAPIKeyHandler) ListScopes(c *gin.Context) {
+	response.Success(c, service.APIKeyScopes)
+}
+
 // AdminUpdateAPIKeyGroupRequest represents the request to update an API key's group
 type AdminUpdateAPIKeyGroupRequest struct {
 	GroupID *int64 `json:"group_id"` // nil=不修改, 0=解绑, >0=绑定到目标分组
@@ -42,11 +352,13 @@ func (h *AdminAPIKeyHandler) UpdateGroup(c *gin.Context) {
 		return
 	}
 
+	before := h.auditBefore(c, keyID)
 	result, err := h.adminService.AdminUpdateAPIKeyGroupID(c.Request.Context(), keyID, req.GroupID)
 	if err != nil {
 		response.ErrorFrom(c, err)
 		return
 	}
+	h.auditAfter(c, before, result.APIKey)
 
 	resp := struct {
 		APIKey                 *dto.APIKey `json:"api_key"`
@@ -61,3 +373,21 @@ func (h *AdminAPIKeyHandler) UpdateGroup(c *gin.Context) {
 	}
 	response.Success(c, resp)
 }
+
+// auditBefore 读取变更前的 API Key 作为审计快照；读取失败时审计仅保留请求体
+func (h *AdminAPIKeyHandler) auditBefore(c *gin.Context, keyID int64) *dto.APIKey {
+	if h.apiKeyService == nil {
+		return nil
+	}
+	key, err := h.apiKeyService.GetByID(c.Request.Context(), keyID)
+	if err != nil {
+		return nil
+	}
+	return dto.APIKeyFromService(key)
+}
+
+func (h *AdminAPIKeyHandler) auditAfter(c *gin.Context, before *dto.APIKey, after *service.APIKey) {
+	if before != nil {
+		setAuditSnapshot(c, before, dto.APIKeyFromService(after))
+	}
+}
@@ -0,0 +1,228 @@
+package admin
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ShaohongDong/sub2api/internal/pkg/ctxkey"
+	"github.com/ShaohongDong/sub2api/internal/pkg/response"
+	"github.com/ShaohongDong/sub2api/internal/server/middleware"
+	"github.com/ShaohongDong/sub2api/internal/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	// auditBodyMaxBytes 记录请求体的上限；更大的请求体（如数据导入）只记录字节数
+	auditBodyMaxBytes = 64 << 10
+	// auditBodyStoredBytes 脱敏后请求体的存储上限
+	auditBodyStoredBytes = 4096
+
+	auditSnapshotKey = "admin_audit_snapshot"
+)
+
+type auditSnapshot struct {
+	before any
+	after  any
+}
+
+// setAuditSnapshot 由写操作处理器登记变更前后的资源快照，审计中间件据此计算字段级 diff。
+// before 为 nil 表示新建，after 为 nil 表示删除。
+func setAuditSnapshot(c *gin.Context, before, after any) {
+	c.Set(auditSnapshotKey, auditSnapshot{before: before, after: after})
+}
+
+// AuditLogHandler records admin write requests and serves the audit log.
+type AuditLogHandler struct {
+	auditService *service.AdminAuditService
+}
+
+// NewAuditLogHandler creates a new AuditLogHandler
+func NewAuditLogHandler(auditService *service.AdminAuditService) *AuditLogHandler {
+	return &AuditLogHandler{auditService: auditService}
+}
+
+// Middleware records every admin write request (including rejected ones) with
+// the actor, target resource, redacted request body and, when the handler
+// registered snapshots via setAuditSnapshot, the before/after diff.
+// Must run after AdminAuth so the actor is known.
+func (h *AuditLogHandler) Middleware(c *gin.Context) {
+	switch c.Request.Method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions:
+		c.Next()
+		return
+	}
+	if h == nil || h.auditService == nil {
+		c.Next()
+		return
+	}
+
+	body := captureAuditRequestBody(c)
+	c.Next()
+
+	subject, _ := middleware.GetAuthSubjectFromContext(c)
+	role, _ := middleware.GetUserRoleFromContext(c)
+	authMethod := c.GetString("auth_method")
+	requestID, _ := c.Request.Context().Value(ctxkey.RequestID).(string)
+	route := c.FullPath()
+	entry := &service.AdminAuditLog{
+		ActorUserID:  subject.UserID,
+		ActorRole:    role,
+		AuthMethod:   authMethod,
+		Method:       c.Request.Method,
+		Route:        route,
+		Path:         c.Request.URL.Path,
+		ResourceType: service.AdminAuditResourceType(route),
+		ResourceID:   c.Param("id"),
+		StatusCode:   c.Writer.Status(),
+		ClientIP:     c.ClientIP(),
+		RequestID:    requestID,
+		RequestBody:  body,
+	}
+	if v, ok := c.Get(auditSnapshotKey); ok {
+		if snapshot, ok := v.(auditSnapshot); ok {
+			entry.Changes = service.DiffAdminAuditSnapshots(snapshot.before, snapshot.after)
+			if entry.ResourceID == "" {
+				if change, ok := entry.Changes["id"]; ok && change.After != nil {
+					entry.ResourceID = fmt.Sprint(change.After)
+				}
+			}
+		}
+	}
+	h.auditService.Record(c.Request.Context(), entry)
+}
+
+// captureAuditRequestBody 读取并还原请求体，返回脱敏后的 JSON；非 JSON 或过大的请求体只记录摘要
+func captureAuditRequestBody(c *gin.Context) string {
+	if c.Request.Body == nil || c.Request.Body == http.NoBody {
+		return ""
+	}
+	if c.Request.ContentLength > auditBodyMaxBytes {
+		return fmt.Sprintf("<%d bytes omitted>", c.Request.ContentLength)
+	}
+	if !strings.Contains(strings.ToLower(c.GetHeader("Content-Type")), "json") && c.Request.ContentLength != 0 {
+		return fmt.Sprintf("<%s body omitted>", c.ContentType())
+	}
+	raw, err := io.ReadAll(io.LimitReader(c.Request.Body, auditBodyMaxBytes+1))
+	c.Request.Body = io.NopCloser(io.MultiReader(bytes.NewReader(raw), c.Request.Body))
+	if err != nil || len(raw) == 0 {
+		return ""
+	}
+	if len(raw) > auditBodyMaxBytes {
+		return "<body omitted: too large>"
+	}
+	var value any
+	if err := json.Unmarshal(raw, &value); err != nil {
+		return "<non-json body omitted>"
+	}
+	redacted, err := json.Marshal(service.RedactAdminAuditValue(value))
+	if err != nil {
+		return ""
+	}
+	if len(redacted) > auditBodyStoredBytes {
+		return string(redacted[:auditBodyStoredBytes]) + "...(truncated)"
+	}
+	return string(redacted)
+}
+
+// List handles querying the admin audit log
+// GET /api/v1/admin/audit-logs
+// Query params: start_time, end_time (RFC3339), actor_user_id, method, resource_type, resource_id,
+// page, page_size, format (json/csv; csv exports up to 10000 rows of the current filter)
+func (h *AuditLogHandler) List(c *gin.Context) {
+	page, pageSize := response.ParsePagination(c)
+	filter := &service.AdminAuditFilter{
+		Method:       c.Query("method"),
+		ResourceType: c.Query("resource_type"),
+		ResourceID:   c.Query("resource_id"),
+		Page:         page,
+		PageSize:     pageSize,
+	}
+	for name, target := range map[string]**time.Time{
+		"start_time": &filter.StartTime,
+		"end_time":   &filter.EndTime,
+	} {
+		if raw := strings.TrimSpace(c.Query(name)); raw != "" {
+			t, err := time.Parse(time.RFC3339, raw)
+			if err != nil {
+				response.BadRequest(c, "Invalid "+name+", use RFC3339")
+				return
+			}
+			*target = &t
+		}
+	}
+	if raw := c.Query("actor_user_id"); raw != "" {
+		id, err := strconv.ParseInt(raw, 10, 64)
+		if err != nil || id <= 0 {
+			response.BadRequest(c, "Invalid actor_user_id")
+			return
+		}
+		filter.ActorUserID = id
+	}
+	format := strings.ToLower(strings.TrimSpace(c.DefaultQuery("format", "json")))
+	if format != "json" && format != "csv" {
+		response.BadRequest(c, "Invalid format, use json or csv")
+		return
+	}
+	if format == "csv" {
+		filter.Page, filter.PageSize = 1, service.AdminAuditMaxExportRows
+	}
+
+	result, err := h.auditService.List(c.Request.Context(), filter)
+	if err != nil {
+		response.ErrorFrom(c, err)
+		return
+	}
+	if format == "csv" {
+		writeAuditLogsCSV(c, result.Items)
+		return
+	}
+	response.Paginated(c, result.Items, result.Total, result.Page, result.PageSize)
+}
+
+func writeAuditLogsCSV(c *gin.Context, items []*service.AdminAuditLog) {
+	var buf bytes.Buffer
+	writer := csv.NewWriter(&buf)
+	_ = writer.Write([]string{"id", "created_at", "actor_user_id", "actor_email", "actor_role", "auth_method",
+		"method", "path", "resource_type", "resource_id", "status_code", "client_ip", "request_id", "changes", "request_body"})
+	for _, item := range items {
+		changes := ""
+		if len(item.Changes) > 0 {
+			raw, _ := json.Marshal(item.Changes)
+			changes = string(raw)
+		}
+		_ = writer.Write([]string{
+			strconv.FormatInt(item.ID, 10),
+			item.CreatedAt.UTC().Format(time.RFC3339),
+			strconv.FormatInt(item.ActorUserID, 10),
+			item.ActorEmail,
+			item.ActorRole,
+			item.AuthMethod,
+			item.Method,
+			item.Path,
+			item.ResourceType,
+			item.ResourceID,
+			strconv.Itoa(item.StatusCode),
+			item.ClientIP,
+			item.RequestID,
+			changes,
+			item.RequestBody,
+		})
+	}
+	writer.Flush()
+	if err := writer.Error(); err != nil {
+		response.InternalError(c, "Failed to export audit logs: "+err.Error())
+		return
+	}
+
+	c.Header("Content-Type", "text/csv")
+	c.Header("Content-Disposition", "attachment; filename=admin_audit_logs.csv")
+	c.Data(http.StatusOK, "text/csv", buf.Bytes())
+}
@@ -0,0 +1,110 @@
+package admin
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/ShaohongDong/sub2api/internal/server/middleware"
+	"github.com/ShaohongDong/sub2api/internal/service"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/require"
+)
+
+type auditRepoCapture struct {
+	entries []*service.AdminAuditLog
+	filter  *service.AdminAuditFilter
+}
+
+func (r *auditRepoCapture) Insert(_ context.Context, entry *service.AdminAuditLog) error {
+	r.entries = append(r.entries, entry)
+	return nil
+}
+
+func (r *auditRepoCapture) List(_ context.Context, filter *service.AdminAuditFilter) (*service.AdminAuditLogList, error) {
+	r.filter = filter
+	return &service.AdminAuditLogList{Items: r.entries, Total: int64(len(r.entries)), Page: filter.Page, PageSize: filter.PageSize}, nil
+}
+
+func newAuditTestRouter(repo *auditRepoCapture) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	h := NewAuditLogHandler(service.NewAdminAuditService(repo))
+	r := gin.New()
+	r.Use(func(c *gin.Context) {
+		c.Set(string(middleware.ContextKeyUser), middleware.AuthSubject{UserID: 1})
+		c.Set(string(middleware.ContextKeyUserRole), service.RoleAdmin)
+		c.Set("auth_method", "jwt")
+		c.Next()
+	})
+	r.Use(h.Middleware)
+	r.GET("/api/v1/admin/audit-logs", h.List)
+	r.GET("/api/v1/admin/accounts/:id", func(c *gin.Context) { c.Status(http.StatusOK) })
+	r.PUT("/api/v1/admin/accounts/:id", func(c *gin.Context) {
+		body, _ := io.ReadAll(c.Request.Body)
+		var req map[string]any
+		_ = json.Unmarshal(body, &req)
+		setAuditSnapshot(c, map[string]any{"id": 9, "name": "old"}, map[string]any{"id": 9, "name": req["name"]})
+		c.Status(http.StatusOK)
+	})
+	return r
+}
+
+func TestAuditMiddlewareRecordsWritesWithDiff(t *testing.T) {
+	repo := &auditRepoCapture{}
+	router := newAuditTestRouter(repo)
+
+	router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/api/v1/admin/accounts/9", nil))
+	require.Empty(t, repo.entries)
+
+	req := httptest.NewRequest(http.MethodPut, "/api/v1/admin/accounts/9",
+		strings.NewReader(`{"name":"new","credentials":{"api_key":"sk-secret"}}`))
+	req.Header.Set("Content-Type", "application/json")
+	router.ServeHTTP(httptest.NewRecorder(), req)
+
+	require.Len(t, repo.entries, 1)
+	entry := repo.entries[0]
+	require.Equal(t, int64(1), entry.ActorUserID)
+	require.Equal(t, service.RoleAdmin, entry.ActorRole)
+	require.Equal(t, "jwt", entry.AuthMethod)
+	require.Equal(t, "/api/v1/admin/accounts/:id", entry.Route)
+	require.Equal(t, "accounts", entry.ResourceType)
+	require.Equal(t, "9", entry.ResourceID)
+	require.Equal(t, http.StatusOK, entry.StatusCode)
+	// 处理器仍能读到完整请求体，审计记录中的凭证已脱敏
+	require.Equal(t, service.AdminAuditChange{Before: "old", After: "new"}, entry.Changes["name"])
+	require.NotContains(t, entry.RequestBody, "sk-secret")
+	require.Contains(t, entry.RequestBody, `"credentials":"***"`)
+}
+
+func TestAuditLogListParsesFiltersAndExportsCSV(t *testing.T) {
+	repo := &auditRepoCapture{entries: []*service.AdminAuditLog{{
+		ID: 1, ActorUserID: 1, Method: "POST", Path: "/api/v1/admin/api-keys/3/revoke", ResourceType: "api-keys", ResourceID: "3",
+		Changes: map[string]service.AdminAuditChange{"status": {Before: "active", After: nil}},
+	}}}
+	router := newAuditTestRouter(repo)
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet,
+		"/api/v1/admin/audit-logs?actor_user_id=1&resource_type=api-keys&method=post&start_time=2026-01-01T00:00:00Z&page=2&page_size=10", nil))
+	require.Equal(t, http.StatusOK, w.Code)
+	require.Equal(t, int64(1), repo.filter.ActorUserID)
+	require.Equal(t, "api-keys", repo.filter.ResourceType)
+	require.Equal(t, "POST", repo.filter.Method)
+	require.NotNil(t, repo.filter.StartTime)
+	require.Equal(t, 2, repo.filter.Page)
+
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/v1/admin/audit-logs?format=csv", nil))
+	require.Equal(t, http.StatusOK, w.Code)
+	require.Equal(t, "text/csv", w.Header().Get("Content-Type"))
+	require.Contains(t, w.Body.String(), "/api/v1/admin/api-keys/3/revoke")
+	require.Equal(t, service.AdminAuditMaxExportRows, repo.filter.PageSize)
+
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/v1/admin/audit-logs?start_time=yesterday", nil))
+	require.Equal(t, http.StatusBadRequest, w.Code)
+}
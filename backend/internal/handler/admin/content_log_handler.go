@@ -0,0 +1,94 @@
+package admin
+
+import (
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ShaohongDong/sub2api/internal/pkg/response"
+	"github.com/ShaohongDong/sub2api/internal/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ContentLogHandler searches the opt-in prompt/response logs of API keys with log_content enabled.
+type ContentLogHandler struct {
+	contentLogService *service.ContentLogService
+}
+
+// NewContentLogHandler creates a new admin ContentLogHandler
+func NewContentLogHandler(contentLogService *service.ContentLogService) *ContentLogHandler {
+	return &ContentLogHandler{contentLogService: contentLogService}
+}
+
+// List handles searching content logs (without request/response bodies)
+// GET /api/v1/admin/content-logs
+// Query params: start_time, end_time (RFC3339), user_id, api_key_id, model, request_id, status_code,
+// q (case-insensitive text in the request or response body), page, page_size
+func (h *ContentLogHandler) List(c *gin.Context) {
+	page, pageSize := response.ParsePagination(c)
+	filter := &service.ContentLogFilter{
+		Model:     c.Query("model"),
+		RequestID: c.Query("request_id"),
+		Query:     c.Query("q"),
+		Page:      page,
+		PageSize:  pageSize,
+	}
+	for name, target := range map[string]**time.Time{
+		"start_time": &filter.StartTime,
+		"end_time":   &filter.EndTime,
+	} {
+		if raw := strings.TrimSpace(c.Query(name)); raw != "" {
+			t, err := time.Parse(time.RFC3339, raw)
+			if err != nil {
+				response.BadRequest(c, "Invalid "+name+", use RFC3339")
+				return
+			}
+			*target = &t
+		}
+	}
+	for name, target := range map[string]*int64{
+		"user_id":    &filter.UserID,
+		"api_key_id": &filter.APIKeyID,
+	} {
+		if raw := c.Query(name); raw != "" {
+			id, err := strconv.ParseInt(raw, 10, 64)
+			if err != nil || id <= 0 {
+				response.BadRequest(c, "Invalid "+name)
+				return
+			}
+			*target = id
+		}
+	}
+	if raw := c.Query("status_code"); raw != "" {
+		code, err := strconv.Atoi(raw)
+		if err != nil || code < 100 || code > 599 {
+			response.BadRequest(c, "Invalid status_code")
+			return
+		}
+		filter.StatusCode = code
+	}
+
+	result, err := h.contentLogService.List(c.Request.Context(), filter)
+	if err != nil {
+		response.ErrorFrom(c, err)
+		return
+	}
+	response.Paginated(c, result.Items, result.Total, result.Page, result.PageSize)
+}
+
+// Get handles getting a content log with its full request and response bodies
+// GET /api/v1/admin/content-logs/:id
+func (h *ContentLogHandler) Get(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil || id <= 0 {
+		response.BadRequest(c, "Invalid content log ID")
+		return
+	}
+	entry, err := h.contentLogService.Get(c.Request.Context(), id)
+	if err != nil {
+		response.ErrorFrom(c, err)
+		return
+	}
+	response.Success(c, entry)
+}
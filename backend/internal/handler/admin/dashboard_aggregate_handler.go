@@ -0,0 +1,166 @@
+package admin
+
+import (
+	"bytes"
+	"encoding/csv"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ShaohongDong/sub2api/internal/pkg/response"
+	"github.com/ShaohongDong/sub2api/internal/pkg/usagestats"
+
+	"github.com/gin-gonic/gin"
+)
+
+// GetUsageAggregates handles usage aggregation grouped by key/model/account/user/group and time bucket
+// GET /api/v1/admin/dashboard/aggregates
+// Query params: start_date, end_date (YYYY-MM-DD), timezone, group_by (comma-separated: api_key,model,account,user,group),
+// granularity (hour/day/week/month, empty = no bucket), user_id, api_key_id, account_id, group_id, model, limit, format (json/csv)
+func (h *DashboardHandler) GetUsageAggregates(c *gin.Context) {
+	startTime, endTime := parseTimeRange(c)
+
+	query := usagestats.UsageAggregateQuery{
+		StartTime:   startTime,
+		EndTime:     endTime,
+		Granularity: strings.ToLower(strings.TrimSpace(c.Query("granularity"))),
+		Model:       strings.TrimSpace(c.Query("model")),
+	}
+	seenDims := make(map[string]struct{})
+	for _, dim := range strings.Split(c.Query("group_by"), ",") {
+		dim = strings.ToLower(strings.TrimSpace(dim))
+		if _, ok := seenDims[dim]; ok || dim == "" {
+			continue
+		}
+		seenDims[dim] = struct{}{}
+		query.GroupBy = append(query.GroupBy, dim)
+	}
+	for name, target := range map[string]*int64{
+		"user_id":    &query.UserID,
+		"api_key_id": &query.APIKeyID,
+		"account_id": &query.AccountID,
+		"group_id":   &query.GroupID,
+	} {
+		if raw := c.Query(name); raw != "" {
+			id, err := strconv.ParseInt(raw, 10, 64)
+			if err != nil || id <= 0 {
+				response.BadRequest(c, "Invalid "+name)
+				return
+			}
+			*target = id
+		}
+	}
+	if raw := c.Query("limit"); raw != "" {
+		limit, err := strconv.Atoi(raw)
+		if err != nil || limit <= 0 {
+			response.BadRequest(c, "Invalid limit")
+			return
+		}
+		query.Limit = limit
+	}
+	format := strings.ToLower(strings.TrimSpace(c.DefaultQuery("format", "json")))
+	if format != "json" && format != "csv" {
+		response.BadRequest(c, "Invalid format, use json or csv")
+		return
+	}
+
+	aggregates, err := h.dashboardService.GetUsageAggregates(c.Request.Context(), query)
+	if err != nil {
+		response.ErrorFrom(c, err)
+		return
+	}
+
+	if format == "csv" {
+		writeUsageAggregatesCSV(c, query, aggregates)
+		return
+	}
+	response.Success(c, gin.H{
+		"items":       aggregates,
+		"group_by":    query.GroupBy,
+		"granularity": query.Granularity,
+		"start_date":  startTime.Format("2006-01-02"),
+		"end_date":    endTime.Add(-24 * time.Hour).Format("2006-01-02"),
+	})
+}
+
+// writeUsageAggregatesCSV 导出聚合结果；仅输出参与分组的维度列，便于直接用于分摊报表
+func writeUsageAggregatesCSV(c *gin.Context, query usagestats.UsageAggregateQuery, aggregates []usagestats.UsageAggregate) {
+	var buf bytes.Buffer
+	writer := csv.NewWriter(&buf)
+	if err := writer.Write(usageAggregateCSVHeader(query)); err != nil {
+		response.InternalError(c, "Failed to export usage aggregates: "+err.Error())
+		return
+	}
+	for i := range aggregates {
+		if err := writer.Write(usageAggregateCSVRecord(query, &aggregates[i])); err != nil {
+			response.InternalError(c, "Failed to export usage aggregates: "+err.Error())
+			return
+		}
+	}
+	writer.Flush()
+	if err := writer.Error(); err != nil {
+		response.InternalError(c, "Failed to export usage aggregates: "+err.Error())
+		return
+	}
+
+	c.Header("Content-Type", "text/csv")
+	c.Header("Content-Disposition", "attachment; filename=usage_aggregates.csv")
+	c.Data(200, "text/csv", buf.Bytes())
+}
+
+// usageAggregateCSVHeader 聚合 CSV 表头：时间桶（如有）+ 参与分组的维度列 + 用量与成本列
+func usageAggregateCSVHeader(query usagestats.UsageAggregateQuery) []string {
+	header := make([]string, 0, 16)
+	if query.Granularity != "" {
+		header = append(header, "bucket")
+	}
+	for _, dim := range query.GroupBy {
+		switch dim {
+		case usagestats.UsageAggregateByAPIKey:
+			header = append(header, "api_key_id", "api_key_name")
+		case usagestats.UsageAggregateByModel:
+			header = append(header, "model")
+		case usagestats.UsageAggregateByAccount:
+			header = append(header, "account_id", "account_name")
+		case usagestats.UsageAggregateByUser:
+			header = append(header, "user_id", "user_email")
+		case usagestats.UsageAggregateByGroup:
+			header = append(header, "group_id", "group_name")
+		}
+	}
+	return append(header, "requests", "input_tokens", "output_tokens", "reasoning_tokens",
+		"cache_creation_tokens", "cache_read_tokens", "total_tokens", "cost", "actual_cost", "account_cost")
+}
+
+func usageAggregateCSVRecord(query usagestats.UsageAggregateQuery, row *usagestats.UsageAggregate) []string {
+	record := make([]string, 0, 16)
+	if query.Granularity != "" {
+		record = append(record, row.Bucket)
+	}
+	for _, dim := range query.GroupBy {
+		switch dim {
+		case usagestats.UsageAggregateByAPIKey:
+			record = append(record, strconv.FormatInt(row.APIKeyID, 10), row.APIKeyName)
+		case usagestats.UsageAggregateByModel:
+			record = append(record, row.Model)
+		case usagestats.UsageAggregateByAccount:
+			record = append(record, strconv.FormatInt(row.AccountID, 10), row.AccountName)
+		case usagestats.UsageAggregateByUser:
+			record = append(record, strconv.FormatInt(row.UserID, 10), row.UserEmail)
+		case usagestats.UsageAggregateByGroup:
+			record = append(record, strconv.FormatInt(row.GroupID, 10), row.GroupName)
+		}
+	}
+	return append(record,
+		strconv.FormatInt(row.Requests, 10),
+		strconv.FormatInt(row.InputTokens, 10),
+		strconv.FormatInt(row.OutputTokens, 10),
+		strconv.FormatInt(row.ReasoningTokens, 10),
+		strconv.FormatInt(row.CacheCreationTokens, 10),
+		strconv.FormatInt(row.CacheReadTokens, 10),
+		strconv.FormatInt(row.TotalTokens, 10),
+		strconv.FormatFloat(row.Cost, 'f', 6, 64),
+		strconv.FormatFloat(row.ActualCost, 'f', 6, 64),
+		strconv.FormatFloat(row.AccountCost, 'f', 6, 64),
+	)
+}
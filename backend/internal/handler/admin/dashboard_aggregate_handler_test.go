@@ -0,0 +1,99 @@
+package admin
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/ShaohongDong/sub2api/internal/pkg/usagestats"
+	"github.com/ShaohongDong/sub2api/internal/service"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/require"
+)
+
+type dashboardAggregateRepoCapture struct {
+	service.UsageLogRepository
+	query *usagestats.UsageAggregateQuery
+	rows  []usagestats.UsageAggregate
+}
+
+func (s *dashboardAggregateRepoCapture) GetUsageAggregates(ctx context.Context, query usagestats.UsageAggregateQuery) ([]usagestats.UsageAggregate, error) {
+	s.query = &query
+	return s.rows, nil
+}
+
+func newDashboardAggregateTestRouter(repo *dashboardAggregateRepoCapture) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	handler := NewDashboardHandler(service.NewDashboardService(repo, nil, nil, nil), nil)
+	router := gin.New()
+	router.GET("/admin/dashboard/aggregates", handler.GetUsageAggregates)
+	return router
+}
+
+func TestDashboardAggregatesParsesQuery(t *testing.T) {
+	repo := &dashboardAggregateRepoCapture{rows: []usagestats.UsageAggregate{
+		{Bucket: "2026-01-02", APIKeyID: 3, APIKeyName: "team-a", Model: "gpt-5", Requests: 2, ReasoningTokens: 7, TotalTokens: 40},
+	}}
+	router := newDashboardAggregateTestRouter(repo)
+
+	req := httptest.NewRequest(http.MethodGet,
+		"/admin/dashboard/aggregates?start_date=2026-01-01&end_date=2026-01-31&group_by=api_key,%20Model,api_key&granularity=day&account_id=9", nil)
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+
+	require.Equal(t, http.StatusOK, rec.Code)
+	require.NotNil(t, repo.query)
+	require.Equal(t, []string{"api_key", "model"}, repo.query.GroupBy)
+	require.Equal(t, "day", repo.query.Granularity)
+	require.Equal(t, int64(9), repo.query.AccountID)
+	require.Equal(t, 1000, repo.query.Limit)
+
+	var body struct {
+		Data struct {
+			Items []map[string]any `json:"items"`
+		} `json:"data"`
+	}
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &body))
+	require.Len(t, body.Data.Items, 1)
+	require.Equal(t, "team-a", body.Data.Items[0]["api_key_name"])
+	require.EqualValues(t, 7, body.Data.Items[0]["reasoning_tokens"])
+	require.NotContains(t, body.Data.Items[0], "account_id")
+}
+
+func TestDashboardAggregatesCSV(t *testing.T) {
+	repo := &dashboardAggregateRepoCapture{rows: []usagestats.UsageAggregate{
+		{AccountID: 5, AccountName: "acc", Model: "claude", Requests: 1, InputTokens: 10, OutputTokens: 20, TotalTokens: 30, Cost: 0.5, ActualCost: 0.5, AccountCost: 0.25},
+	}}
+	router := newDashboardAggregateTestRouter(repo)
+
+	req := httptest.NewRequest(http.MethodGet, "/admin/dashboard/aggregates?group_by=account,model&format=csv", nil)
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+
+	require.Equal(t, http.StatusOK, rec.Code)
+	require.Equal(t, "text/csv", rec.Header().Get("Content-Type"))
+	lines := strings.Split(strings.TrimSpace(rec.Body.String()), "\n")
+	require.Len(t, lines, 2)
+	require.True(t, strings.HasPrefix(lines[0], "account_id,account_name,model,requests,"))
+	require.True(t, strings.HasPrefix(lines[1], "5,acc,claude,1,10,20,0,"))
+}
+
+func TestDashboardAggregatesRejectsInvalidInput(t *testing.T) {
+	router := newDashboardAggregateTestRouter(&dashboardAggregateRepoCapture{})
+
+	for _, query := range []string{
+		"group_by=provider",
+		"granularity=minute",
+		"limit=0",
+		"api_key_id=abc",
+		"format=xml",
+	} {
+		req := httptest.NewRequest(http.MethodGet, "/admin/dashboard/aggregates?"+query, nil)
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, req)
+		require.Equal(t, http.StatusBadRequest, rec.Code, query)
+	}
+}
@@ -3,6 +3,7 @@ package admin
 import (
 	"encoding/json"
 	"errors"
+	"net/http"
 	"strconv"
 	"strings"
 	"time"
@@ -404,6 +405,72 @@ func (h *DashboardHandler) GetGroupStats(c *gin.Context) {
 	})
 }
 
+// GetClientStats handles getting usage breakdown by detected client type
+// GET /api/v1/admin/stats/clients
+// Query params: start_date, end_date (YYYY-MM-DD), timezone
+func (h *DashboardHandler) GetClientStats(c *gin.Context) {
+	startTime, endTime := parseTimeRange(c)
+
+	stats, err := h.dashboardService.GetClientTypeStats(c.Request.Context(), startTime, endTime)
+	if err != nil {
+		response.Error(c, 500, "Failed to get client statistics")
+		return
+	}
+
+	response.Success(c, gin.H{
+		"clients":    stats,
+		"start_date": startTime.Format("2006-01-02"),
+		"end_date":   endTime.Add(-24 * time.Hour).Format("2006-01-02"),
+	})
+}
+
+var dashboardOverviewCache = newSnapshotCache(30 * time.Second)
+
+// GetOverview handles getting today's pre-aggregated summary (requests, tokens, cost,
+// error rate, active accounts, top models and top API keys) in a single call.
+// GET /api/v1/admin/stats/overview
+// Query params: top (number of top models/keys, default 5, max 20)
+func (h *DashboardHandler) GetOverview(c *gin.Context) {
+	top := service.DefaultDashboardOverviewTopN
+	if raw := strings.TrimSpace(c.Query("top")); raw != "" {
+		parsed, err := strconv.Atoi(raw)
+		if err != nil || parsed <= 0 || parsed > service.MaxDashboardOverviewTopN {
+			response.BadRequest(c, "Invalid top parameter")
+			return
+		}
+		top = parsed
+	}
+
+	cacheKey := "top=" + strconv.Itoa(top)
+	if cached, ok := dashboardOverviewCache.Get(cacheKey); ok {
+		if cached.ETag != "" {
+			c.Header("ETag", cached.ETag)
+			c.Header("Vary", "If-None-Match")
+			if ifNoneMatchMatched(c.GetHeader("If-None-Match"), cached.ETag) {
+				c.Status(http.StatusNotModified)
+				return
+			}
+		}
+		c.Header("X-Snapshot-Cache", "hit")
+		response.Success(c, cached.Payload)
+		return
+	}
+
+	overview, err := h.dashboardService.GetOverview(c.Request.Context(), top)
+	if err != nil {
+		response.Error(c, 500, "Failed to get dashboard overview")
+		return
+	}
+
+	cached := dashboardOverviewCache.Set(cacheKey, overview)
+	if cached.ETag != "" {
+		c.Header("ETag", cached.ETag)
+		c.Header("Vary", "If-None-Match")
+	}
+	c.Header("X-Snapshot-Cache", "miss")
+	response.Success(c, overview)
+}
+
 // GetAPIKeyUsageTrend handles getting API key usage trend data
 // GET /api/v1/admin/dashboard/api-keys-trend
 // Query params: start_date, end_date (YYYY-MM-DD), granularity (day/hour), limit (default 5)
@@ -0,0 +1,95 @@
+package admin
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ShaohongDong/sub2api/internal/pkg/response"
+	"github.com/ShaohongDong/sub2api/internal/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+// liveEventsHeartbeatInterval SSE 心跳间隔，防止反向代理因空闲断开连接，同时回报丢弃的事件数
+var liveEventsHeartbeatInterval = 15 * time.Second
+
+// StreamLiveEvents streams real-time request events of this instance via SSE
+// GET /api/v1/admin/dashboard/live
+// Query params: api_key_id, account_id, model, errors_only (true/false)
+func (h *DashboardHandler) StreamLiveEvents(c *gin.Context) {
+	var filter service.LiveRequestEventFilter
+	for name, target := range map[string]*int64{
+		"api_key_id": &filter.APIKeyID,
+		"account_id": &filter.AccountID,
+	} {
+		if raw := c.Query(name); raw != "" {
+			id, err := strconv.ParseInt(raw, 10, 64)
+			if err != nil || id <= 0 {
+				response.BadRequest(c, "Invalid "+name)
+				return
+			}
+			*target = id
+		}
+	}
+	filter.Model = strings.TrimSpace(c.Query("model"))
+	if raw := c.Query("errors_only"); raw != "" {
+		errorsOnly, err := strconv.ParseBool(raw)
+		if err != nil {
+			response.BadRequest(c, "Invalid errors_only")
+			return
+		}
+		filter.ErrorsOnly = errorsOnly
+	}
+
+	sub, err := service.SubscribeLiveRequestEvents(filter)
+	if err != nil {
+		response.ErrorFrom(c, err)
+		return
+	}
+	defer sub.Close()
+
+	c.Writer.Header().Set("Content-Type", "text/event-stream")
+	c.Writer.Header().Set("Cache-Control", "no-cache")
+	c.Writer.Header().Set("Connection", "keep-alive")
+	c.Writer.Header().Set("X-Accel-Buffering", "no")
+	c.Status(http.StatusOK)
+	if !writeLiveSSE(c, "ready", gin.H{"heartbeat_seconds": int(liveEventsHeartbeatInterval.Seconds())}) {
+		return
+	}
+
+	heartbeat := time.NewTicker(liveEventsHeartbeatInterval)
+	defer heartbeat.Stop()
+
+	ctx := c.Request.Context()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case event, ok := <-sub.Events():
+			if !ok || !writeLiveSSE(c, "request", event) {
+				return
+			}
+		case <-heartbeat.C:
+			if !writeLiveSSE(c, "ping", gin.H{"dropped": sub.Dropped()}) {
+				return
+			}
+		}
+	}
+}
+
+// writeLiveSSE 写出一条 SSE 事件并立即刷新；返回 false 表示连接已不可写
+func writeLiveSSE(c *gin.Context, event string, payload any) bool {
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return false
+	}
+	if _, err := fmt.Fprintf(c.Writer, "event: %s\ndata: %s\n\n", event, data); err != nil {
+		return false
+	}
+	c.Writer.Flush()
+	return true
+}
@@ -0,0 +1,82 @@
+package admin
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ShaohongDong/sub2api/internal/service"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/require"
+)
+
+func newDashboardLiveTestServer(t *testing.T) *httptest.Server {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+	handler := NewDashboardHandler(nil, nil)
+	router := gin.New()
+	router.GET("/admin/dashboard/live", handler.StreamLiveEvents)
+	srv := httptest.NewServer(router)
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func TestDashboardLiveRejectsInvalidFilter(t *testing.T) {
+	srv := newDashboardLiveTestServer(t)
+
+	resp, err := http.Get(srv.URL + "/admin/dashboard/live?account_id=abc")
+	require.NoError(t, err)
+	defer func() { _ = resp.Body.Close() }()
+	require.Equal(t, http.StatusBadRequest, resp.StatusCode)
+}
+
+func TestDashboardLiveStreamsFilteredEvents(t *testing.T) {
+	srv := newDashboardLiveTestServer(t)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, srv.URL+"/admin/dashboard/live?api_key_id=41", nil)
+	require.NoError(t, err)
+	resp, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	defer func() { _ = resp.Body.Close() }()
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	require.Equal(t, "text/event-stream", resp.Header.Get("Content-Type"))
+
+	reader := bufio.NewReader(resp.Body)
+	readEvent := func() (string, string) {
+		var name, data string
+		for {
+			line, err := reader.ReadString('\n')
+			require.NoError(t, err)
+			line = strings.TrimRight(line, "\n")
+			switch {
+			case strings.HasPrefix(line, "event: "):
+				name = strings.TrimPrefix(line, "event: ")
+			case strings.HasPrefix(line, "data: "):
+				data = strings.TrimPrefix(line, "data: ")
+			case line == "":
+				return name, data
+			}
+		}
+	}
+
+	name, _ := readEvent()
+	require.Equal(t, "ready", name)
+
+	service.PublishLiveRequestEvent(service.LiveRequestEvent{APIKeyID: 40, Model: "other", Status: 200})
+	service.PublishLiveRequestEvent(service.LiveRequestEvent{APIKeyID: 41, Model: "gpt-5", Status: 502, LatencyMs: 30})
+
+	name, data := readEvent()
+	require.Equal(t, "request", name)
+	var event service.LiveRequestEvent
+	require.NoError(t, json.Unmarshal([]byte(data), &event))
+	require.Equal(t, int64(41), event.APIKeyID)
+	require.Equal(t, 502, event.Status)
+	require.Equal(t, int64(30), event.LatencyMs)
+}
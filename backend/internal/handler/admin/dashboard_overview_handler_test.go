@@ -0,0 +1,71 @@
+package admin
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/ShaohongDong/sub2api/internal/pkg/usagestats"
+	"github.com/ShaohongDong/sub2api/internal/service"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/require"
+)
+
+type dashboardOverviewRepoStub struct {
+	service.UsageLogRepository
+	statsCalls int32
+}
+
+func (s *dashboardOverviewRepoStub) GetDashboardStats(ctx context.Context) (*usagestats.DashboardStats, error) {
+	atomic.AddInt32(&s.statsCalls, 1)
+	return &usagestats.DashboardStats{TodayRequests: 9, NormalAccounts: 2}, nil
+}
+
+func (s *dashboardOverviewRepoStub) CountErrorRequests(ctx context.Context, startTime, endTime time.Time) (int64, error) {
+	return 1, nil
+}
+
+func (s *dashboardOverviewRepoStub) GetUsageAggregates(ctx context.Context, query usagestats.UsageAggregateQuery) ([]usagestats.UsageAggregate, error) {
+	return []usagestats.UsageAggregate{{Model: "claude-sonnet-4", APIKeyID: 3, Requests: 9}}, nil
+}
+
+func TestDashboardHandlerGetOverview(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	dashboardOverviewCache = newSnapshotCache(30 * time.Second)
+	repo := &dashboardOverviewRepoStub{}
+	handler := NewDashboardHandler(service.NewDashboardService(repo, nil, nil, nil), nil)
+	router := gin.New()
+	router.GET("/admin/stats/overview", handler.GetOverview)
+
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/admin/stats/overview", nil))
+	require.Equal(t, http.StatusOK, rec.Code)
+	require.Equal(t, "miss", rec.Header().Get("X-Snapshot-Cache"))
+
+	var resp struct {
+		Data usagestats.DashboardOverview `json:"data"`
+	}
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &resp))
+	require.Equal(t, int64(9), resp.Data.Requests)
+	require.InDelta(t, 0.1, resp.Data.ErrorRate, 1e-9)
+	require.Equal(t, int64(2), resp.Data.ActiveAccounts)
+	require.Len(t, resp.Data.TopModels, 1)
+	require.Len(t, resp.Data.TopAPIKeys, 1)
+
+	etag := rec.Header().Get("ETag")
+	require.NotEmpty(t, etag)
+	req := httptest.NewRequest(http.MethodGet, "/admin/stats/overview", nil)
+	req.Header.Set("If-None-Match", etag)
+	rec = httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+	require.Equal(t, http.StatusNotModified, rec.Code)
+	require.Equal(t, int32(1), atomic.LoadInt32(&repo.statsCalls))
+
+	rec = httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/admin/stats/overview?top=50", nil))
+	require.Equal(t, http.StatusBadRequest, rec.Code)
+}
@@ -0,0 +1,130 @@
+package admin
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ShaohongDong/sub2api/internal/pkg/response"
+	"github.com/ShaohongDong/sub2api/internal/server/middleware"
+	"github.com/ShaohongDong/sub2api/internal/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+// DeadLetterHandler browses and replays gateway requests that exhausted all retries.
+type DeadLetterHandler struct {
+	deadLetterService *service.DeadLetterService
+}
+
+// NewDeadLetterHandler creates a new admin DeadLetterHandler
+func NewDeadLetterHandler(deadLetterService *service.DeadLetterService) *DeadLetterHandler {
+	return &DeadLetterHandler{deadLetterService: deadLetterService}
+}
+
+// DeadLetterBatchRequest selects dead letters to replay or discard
+type DeadLetterBatchRequest struct {
+	IDs []int64 `json:"ids" binding:"required"`
+}
+
+// List handles listing dead letters (without request/response bodies)
+// GET /api/v1/admin/dead-letters
+// Query params: start_time, end_time (RFC3339), status, user_id, api_key_id, group_id, account_id, model, page, page_size
+func (h *DeadLetterHandler) List(c *gin.Context) {
+	page, pageSize := response.ParsePagination(c)
+	filter := &service.DeadLetterFilter{
+		Status:   c.Query("status"),
+		Model:    c.Query("model"),
+		Page:     page,
+		PageSize: pageSize,
+	}
+	for name, target := range map[string]**time.Time{
+		"start_time": &filter.StartTime,
+		"end_time":   &filter.EndTime,
+	} {
+		if raw := strings.TrimSpace(c.Query(name)); raw != "" {
+			t, err := time.Parse(time.RFC3339, raw)
+			if err != nil {
+				response.BadRequest(c, "Invalid "+name+", use RFC3339")
+				return
+			}
+			*target = &t
+		}
+	}
+	for name, target := range map[string]*int64{
+		"user_id":    &filter.UserID,
+		"api_key_id": &filter.APIKeyID,
+		"group_id":   &filter.GroupID,
+		"account_id": &filter.AccountID,
+	} {
+		if raw := c.Query(name); raw != "" {
+			id, err := strconv.ParseInt(raw, 10, 64)
+			if err != nil || id <= 0 {
+				response.BadRequest(c, "Invalid "+name)
+				return
+			}
+			*target = id
+		}
+	}
+
+	result, err := h.deadLetterService.List(c.Request.Context(), filter)
+	if err != nil {
+		response.ErrorFrom(c, err)
+		return
+	}
+	response.Paginated(c, result.Items, result.Total, result.Page, result.PageSize)
+}
+
+// Get handles getting a dead letter with its request body and last replay response
+// GET /api/v1/admin/dead-letters/:id
+func (h *DeadLetterHandler) Get(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil || id <= 0 {
+		response.BadRequest(c, "Invalid dead letter ID")
+		return
+	}
+	entry, err := h.deadLetterService.Get(c.Request.Context(), id)
+	if err != nil {
+		response.ErrorFrom(c, err)
+		return
+	}
+	response.Success(c, entry)
+}
+
+// Replay handles re-running selected dead letters in the background
+// POST /api/v1/admin/dead-letters/replay
+func (h *DeadLetterHandler) Replay(c *gin.Context) {
+	var req DeadLetterBatchRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "Invalid request: "+err.Error())
+		return
+	}
+	subject, ok := middleware.GetAuthSubjectFromContext(c)
+	if !ok || subject.UserID <= 0 {
+		response.Error(c, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+	result, err := h.deadLetterService.Replay(c.Request.Context(), req.IDs, subject.UserID)
+	if err != nil {
+		response.ErrorFrom(c, err)
+		return
+	}
+	response.Success(c, result)
+}
+
+// Discard handles marking selected dead letters as discarded
+// POST /api/v1/admin/dead-letters/discard
+func (h *DeadLetterHandler) Discard(c *gin.Context) {
+	var req DeadLetterBatchRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "Invalid request: "+err.Error())
+		return
+	}
+	discarded, err := h.deadLetterService.Discard(c.Request.Context(), req.IDs)
+	if err != nil {
+		response.ErrorFrom(c, err)
+		return
+	}
+	response.Success(c, gin.H{"discarded": discarded})
+}
@@ -56,6 +56,11 @@ type CreateGroupRequest struct {
 	// OpenAI Messages 调度配置（仅 openai 平台使用）
 	AllowMessagesDispatch bool   `json:"allow_messages_dispatch"`
 	DefaultMappedModel    string `json:"default_mapped_model"`
+	SchedulingStrategy    string `json:"scheduling_strategy"`
+	// 模型别名：请求模型（支持末尾 *）-> 上游模型，优先于全局别名表
+	ModelAliases map[string]string `json:"model_aliases"`
+	// 跨平台降级链：本分组限流或上游故障时依次尝试的分组 ID
+	FallbackChain []int64 `json:"fallback_chain"`
 	// 从指定分组复制账号（创建后自动绑定）
 	CopyAccountsFromGroupIDs []int64 `json:"copy_accounts_from_group_ids"`
 }
@@ -94,6 +99,11 @@ type UpdateGroupRequest struct {
 	// OpenAI Messages 调度配置（仅 openai 平台使用）
 	AllowMessagesDispatch *bool   `json:"allow_messages_dispatch"`
 	DefaultMappedModel    *string `json:"default_mapped_model"`
+	SchedulingStrategy    *string `json:"scheduling_strategy"`
+	// 模型别名（不传表示不修改，传 {} 表示清除）
+	ModelAliases map[string]string `json:"model_aliases"`
+	// 跨平台降级链（不传表示不修改，传 [] 表示清除）
+	FallbackChain []int64 `json:"fallback_chain"`
 	// 从指定分组复制账号（同步操作：先清空当前分组的账号绑定，再绑定源分组的账号）
 	CopyAccountsFromGroupIDs []int64 `json:"copy_accounts_from_group_ids"`
 }
@@ -211,6 +221,9 @@ func (h *GroupHandler) Create(c *gin.Context) {
 		SoraStorageQuotaBytes:           req.SoraStorageQuotaBytes,
 		AllowMessagesDispatch:           req.AllowMessagesDispatch,
 		DefaultMappedModel:              req.DefaultMappedModel,
+		ModelAliases:                    req.ModelAliases,
+		FallbackChain:                   req.FallbackChain,
+		SchedulingStrategy:              req.SchedulingStrategy,
 		CopyAccountsFromGroupIDs:        req.CopyAccountsFromGroupIDs,
 	})
 	if err != nil {
@@ -264,6 +277,9 @@ func (h *GroupHandler) Update(c *gin.Context) {
 		SoraStorageQuotaBytes:           req.SoraStorageQuotaBytes,
 		AllowMessagesDispatch:           req.AllowMessagesDispatch,
 		DefaultMappedModel:              req.DefaultMappedModel,
+		ModelAliases:                    req.ModelAliases,
+		FallbackChain:                   req.FallbackChain,
+		SchedulingStrategy:              req.SchedulingStrategy,
 		CopyAccountsFromGroupIDs:        req.CopyAccountsFromGroupIDs,
 	})
 	if err != nil {
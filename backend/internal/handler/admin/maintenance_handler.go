@@ -0,0 +1,56 @@
+package admin
+
+import (
+	"net/http"
+
+	"github.com/ShaohongDong/sub2api/internal/pkg/response"
+	"github.com/ShaohongDong/sub2api/internal/server/middleware"
+	"github.com/ShaohongDong/sub2api/internal/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+// MaintenanceHandler toggles the gateway maintenance mode.
+type MaintenanceHandler struct {
+	maintenanceService *service.MaintenanceService
+}
+
+// NewMaintenanceHandler creates a new admin MaintenanceHandler
+func NewMaintenanceHandler(maintenanceService *service.MaintenanceService) *MaintenanceHandler {
+	return &MaintenanceHandler{maintenanceService: maintenanceService}
+}
+
+// Get returns the current maintenance state
+// GET /api/v1/admin/settings/maintenance
+func (h *MaintenanceHandler) Get(c *gin.Context) {
+	st, err := h.maintenanceService.Get(c.Request.Context())
+	if err != nil {
+		response.ErrorFrom(c, err)
+		return
+	}
+	response.Success(c, st)
+}
+
+// Update turns maintenance mode on or off and sets the client message and Retry-After
+// PUT /api/v1/admin/settings/maintenance
+func (h *MaintenanceHandler) Update(c *gin.Context) {
+	var req service.MaintenanceState
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "Invalid request: "+err.Error())
+		return
+	}
+	subject, ok := middleware.GetAuthSubjectFromContext(c)
+	if !ok || subject.UserID <= 0 {
+		response.Error(c, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	before, _ := h.maintenanceService.Get(c.Request.Context())
+	updated, err := h.maintenanceService.Update(c.Request.Context(), &req, subject.UserID)
+	if err != nil {
+		response.ErrorFrom(c, err)
+		return
+	}
+	setAuditSnapshot(c, before, updated)
+	response.Success(c, updated)
+}
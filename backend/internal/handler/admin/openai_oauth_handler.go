@@ -265,13 +265,37 @@ func (h *OpenAIOAuthHandler) CreateAccountFromOAuth(c *gin.Context) {
 		return
 	}
 
+	account, err := h.newOAuthAccount(c, tokenInfo, oauthPlatformFromPath(c), &oauthAccountOptions{
+		Name:        req.Name,
+		ProxyID:     req.ProxyID,
+		Concurrency: req.Concurrency,
+		Priority:    req.Priority,
+		GroupIDs:    req.GroupIDs,
+	})
+	if err != nil {
+		response.ErrorFrom(c, err)
+		return
+	}
+
+	response.Success(c, dto.AccountFromService(account))
+}
+
+// oauthAccountOptions holds the account settings submitted alongside an OAuth login
+type oauthAccountOptions struct {
+	Name        string
+	ProxyID     *int64
+	Concurrency int
+	Priority    int
+	GroupIDs    []int64
+}
+
+// newOAuthAccount creates an OAuth account from exchanged tokens
+func (h *OpenAIOAuthHandler) newOAuthAccount(c *gin.Context, tokenInfo *service.OpenAITokenInfo, platform string, opts *oauthAccountOptions) (*service.Account, error) {
 	// Build credentials from token info
 	credentials := h.openaiOAuthService.BuildAccountCredentials(tokenInfo)
 
-	platform := oauthPlatformFromPath(c)
-
 	// Use email as default name if not provided
-	name := req.Name
+	name := opts.Name
 	if name == "" && tokenInfo.Email != "" {
 		name = tokenInfo.Email
 	}
@@ -284,11 +308,81 @@ func (h *OpenAIOAuthHandler) CreateAccountFromOAuth(c *gin.Context) {
 	}
 
 	// Create account
-	account, err := h.adminService.CreateAccount(c.Request.Context(), &service.CreateAccountInput{
+	return h.adminService.CreateAccount(c.Request.Context(), &service.CreateAccountInput{
 		Name:        name,
 		Platform:    platform,
 		Type:        "oauth",
 		Credentials: credentials,
+		ProxyID:     opts.ProxyID,
+		Concurrency: opts.Concurrency,
+		Priority:    opts.Priority,
+		GroupIDs:    opts.GroupIDs,
+	})
+}
+
+// OpenAIStartDeviceLoginRequest represents the request for starting a device code login
+type OpenAIStartDeviceLoginRequest struct {
+	ProxyID *int64 `json:"proxy_id"`
+}
+
+// StartDeviceLogin starts a Codex device code login and returns the
+// verification URL and user code to show to the admin
+// POST /api/v1/admin/openai/device-login/start
+func (h *OpenAIOAuthHandler) StartDeviceLogin(c *gin.Context) {
+	var req OpenAIStartDeviceLoginRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		// Allow empty body
+		req = OpenAIStartDeviceLoginRequest{}
+	}
+
+	result, err := h.openaiOAuthService.StartDeviceLogin(c.Request.Context(), req.ProxyID)
+	if err != nil {
+		response.ErrorFrom(c, err)
+		return
+	}
+
+	response.Success(c, result)
+}
+
+// OpenAIPollDeviceLoginRequest represents the request for polling a device code login
+type OpenAIPollDeviceLoginRequest struct {
+	SessionID   string  `json:"session_id" binding:"required"`
+	ProxyID     *int64  `json:"proxy_id"`
+	Name        string  `json:"name"`
+	Concurrency int     `json:"concurrency"`
+	Priority    int     `json:"priority"`
+	GroupIDs    []int64 `json:"group_ids"`
+}
+
+// OpenAIPollDeviceLoginResponse is the poll result; Account is set once the login completes
+type OpenAIPollDeviceLoginResponse struct {
+	Status  string       `json:"status"`
+	Account *dto.Account `json:"account,omitempty"`
+}
+
+// PollDeviceLogin polls a device code login. While the user has not entered
+// the code the status is "pending"; once approved the tokens are stored as a
+// new OpenAI OAuth account and the status is "completed".
+// POST /api/v1/admin/openai/device-login/poll
+func (h *OpenAIOAuthHandler) PollDeviceLogin(c *gin.Context) {
+	var req OpenAIPollDeviceLoginRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "Invalid request: "+err.Error())
+		return
+	}
+
+	tokenInfo, err := h.openaiOAuthService.PollDeviceLogin(c.Request.Context(), req.SessionID)
+	if err != nil {
+		response.ErrorFrom(c, err)
+		return
+	}
+	if tokenInfo == nil {
+		response.Success(c, OpenAIPollDeviceLoginResponse{Status: "pending"})
+		return
+	}
+
+	account, err := h.newOAuthAccount(c, tokenInfo, service.PlatformOpenAI, &oauthAccountOptions{
+		Name:        req.Name,
 		ProxyID:     req.ProxyID,
 		Concurrency: req.Concurrency,
 		Priority:    req.Priority,
@@ -299,5 +393,5 @@ func (h *OpenAIOAuthHandler) CreateAccountFromOAuth(c *gin.Context) {
 		return
 	}
 
-	response.Success(c, dto.AccountFromService(account))
+	response.Success(c, OpenAIPollDeviceLoginResponse{Status: "completed", Account: dto.AccountFromService(account)})
 }
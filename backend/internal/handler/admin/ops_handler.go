@@ -769,6 +769,48 @@ func (h *OpsHandler) RetryErrorRequest(c *gin.Context) {
 	response.Success(c, result)
 }
 
+type opsReplayRequest struct {
+	AccountID int64 `json:"account_id"`
+}
+
+// ReplayError replays a stored failed request against a chosen account and returns verbose upstream traces.
+// POST /api/v1/admin/ops/errors/:id/replay
+func (h *OpsHandler) ReplayError(c *gin.Context) {
+	if h.opsService == nil {
+		response.Error(c, http.StatusServiceUnavailable, "Ops service not available")
+		return
+	}
+	if err := h.opsService.RequireMonitoringEnabled(c.Request.Context()); err != nil {
+		response.ErrorFrom(c, err)
+		return
+	}
+
+	idStr := strings.TrimSpace(c.Param("id"))
+	id, err := strconv.ParseInt(idStr, 10, 64)
+	if err != nil || id <= 0 {
+		response.BadRequest(c, "Invalid error id")
+		return
+	}
+
+	var req opsReplayRequest
+	if err := c.ShouldBindJSON(&req); err != nil && !errors.Is(err, io.EOF) {
+		response.BadRequest(c, "Invalid request: "+err.Error())
+		return
+	}
+	if req.AccountID < 0 {
+		response.BadRequest(c, "Invalid account_id")
+		return
+	}
+
+	result, err := h.opsService.ReplayError(c.Request.Context(), id, req.AccountID)
+	if err != nil {
+		response.ErrorFrom(c, err)
+		return
+	}
+
+	response.Success(c, result)
+}
+
 // ListRetryAttempts lists retry attempts for an error log.
 // GET /api/v1/admin/ops/errors/:id/retries
 func (h *OpsHandler) ListRetryAttempts(c *gin.Context) {
@@ -11,6 +11,42 @@ import (
 	"github.com/gin-gonic/gin"
 )
 
+// GetAccountLatency returns this instance's rolling p50/p95 upstream latency per account
+// (time to first token for streams, total duration otherwise), used by latency-aware scheduling.
+// GET /api/v1/admin/ops/account-latency
+func (h *OpsHandler) GetAccountLatency(c *gin.Context) {
+	if h.opsService == nil {
+		response.Error(c, http.StatusServiceUnavailable, "Ops service not available")
+		return
+	}
+	if err := h.opsService.RequireMonitoringEnabled(c.Request.Context()); err != nil {
+		response.ErrorFrom(c, err)
+		return
+	}
+	response.Success(c, gin.H{
+		"accounts":  service.SnapshotAccountLatency(),
+		"timestamp": time.Now().UTC(),
+	})
+}
+
+// GetAccountCircuitBreakers returns this instance's per-account circuit breaker state
+// (open accounts are skipped by scheduling until they go half-open).
+// GET /api/v1/admin/ops/account-circuit-breakers
+func (h *OpsHandler) GetAccountCircuitBreakers(c *gin.Context) {
+	if h.opsService == nil {
+		response.Error(c, http.StatusServiceUnavailable, "Ops service not available")
+		return
+	}
+	if err := h.opsService.RequireMonitoringEnabled(c.Request.Context()); err != nil {
+		response.ErrorFrom(c, err)
+		return
+	}
+	response.Success(c, gin.H{
+		"accounts":  service.SnapshotAccountCircuits(),
+		"timestamp": time.Now().UTC(),
+	})
+}
+
 // GetConcurrencyStats returns real-time concurrency usage aggregated by platform/group/account.
 // GET /api/v1/admin/ops/concurrency
 func (h *OpsHandler) GetConcurrencyStats(c *gin.Context) {
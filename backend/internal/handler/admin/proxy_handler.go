@@ -66,7 +66,9 @@ func (h *ProxyHandler) List(c *gin.Context) {
 
 	out := make([]dto.AdminProxyWithAccountCount, 0, len(proxies))
 	for i := range proxies {
-		out = append(out, *dto.ProxyWithAccountCountFromServiceAdmin(&proxies[i]))
+		item := dto.ProxyWithAccountCountFromServiceAdmin(&proxies[i])
+		redactProxySecrets(c, &item.AdminProxy)
+		out = append(out, *item)
 	}
 	response.Paginated(c, out, total, page, pageSize)
 }
@@ -85,7 +87,9 @@ func (h *ProxyHandler) GetAll(c *gin.Context) {
 		}
 		out := make([]dto.AdminProxyWithAccountCount, 0, len(proxies))
 		for i := range proxies {
-			out = append(out, *dto.ProxyWithAccountCountFromServiceAdmin(&proxies[i]))
+			item := dto.ProxyWithAccountCountFromServiceAdmin(&proxies[i])
+			redactProxySecrets(c, &item.AdminProxy)
+			out = append(out, *item)
 		}
 		response.Success(c, out)
 		return
@@ -99,7 +103,9 @@ func (h *ProxyHandler) GetAll(c *gin.Context) {
 
 	out := make([]dto.AdminProxy, 0, len(proxies))
 	for i := range proxies {
-		out = append(out, *dto.ProxyFromServiceAdmin(&proxies[i]))
+		item := dto.ProxyFromServiceAdmin(&proxies[i])
+		redactProxySecrets(c, item)
+		out = append(out, *item)
 	}
 	response.Success(c, out)
 }
@@ -119,7 +125,9 @@ func (h *ProxyHandler) GetByID(c *gin.Context) {
 		return
 	}
 
-	response.Success(c, dto.ProxyFromServiceAdmin(proxy))
+	out := dto.ProxyFromServiceAdmin(proxy)
+	redactProxySecrets(c, out)
+	response.Success(c, out)
 }
 
 // Create handles creating a new proxy
@@ -0,0 +1,49 @@
+package admin
+
+import (
+	"github.com/ShaohongDong/sub2api/internal/handler/dto"
+	"github.com/ShaohongDong/sub2api/internal/pkg/response"
+	middleware2 "github.com/ShaohongDong/sub2api/internal/server/middleware"
+	"github.com/ShaohongDong/sub2api/internal/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+// canViewSecrets 只读角色（viewer）不返回账号凭证、代理密码等敏感字段。
+// 上下文中没有角色时（未经 AdminAuth 的内部调用）不做脱敏。
+func canViewSecrets(c *gin.Context) bool {
+	role, ok := middleware2.GetUserRoleFromContext(c)
+	return !ok || service.AdminRoleAtLeast(role, service.RoleOperator)
+}
+
+func redactAccountSecrets(c *gin.Context, account *dto.Account) {
+	if account == nil || canViewSecrets(c) {
+		return
+	}
+	account.Credentials = nil
+}
+
+func redactProxySecrets(c *gin.Context, proxy *dto.AdminProxy) {
+	if proxy == nil || canViewSecrets(c) {
+		return
+	}
+	proxy.Password = ""
+}
+
+// ensureCanManageUser 非 admin 角色不可修改或删除拥有管理后台角色的用户（防止 operator 通过改密越权）
+func ensureCanManageUser(c *gin.Context, adminService service.AdminService, userID int64) bool {
+	role, ok := middleware2.GetUserRoleFromContext(c)
+	if !ok || role == service.RoleAdmin {
+		return true
+	}
+	target, err := adminService.GetUser(c.Request.Context(), userID)
+	if err != nil {
+		response.ErrorFrom(c, err)
+		return false
+	}
+	if target.HasAdminAccess() {
+		response.ErrorFrom(c, service.ErrInsufficientPerms)
+		return false
+	}
+	return true
+}
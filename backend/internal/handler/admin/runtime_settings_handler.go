@@ -0,0 +1,75 @@
+package admin
+
+import (
+	"net/http"
+
+	"github.com/ShaohongDong/sub2api/internal/pkg/response"
+	"github.com/ShaohongDong/sub2api/internal/server/middleware"
+	"github.com/ShaohongDong/sub2api/internal/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+// RuntimeSettingsHandler exposes gateway tunables that apply without restart.
+type RuntimeSettingsHandler struct {
+	runtimeSettingsService *service.RuntimeSettingsService
+}
+
+// NewRuntimeSettingsHandler creates a new RuntimeSettingsHandler
+func NewRuntimeSettingsHandler(runtimeSettingsService *service.RuntimeSettingsService) *RuntimeSettingsHandler {
+	return &RuntimeSettingsHandler{runtimeSettingsService: runtimeSettingsService}
+}
+
+// Get returns the effective runtime settings
+// GET /api/v1/admin/settings/runtime
+func (h *RuntimeSettingsHandler) Get(c *gin.Context) {
+	settings, err := h.runtimeSettingsService.Get(c.Request.Context())
+	if err != nil {
+		response.ErrorFrom(c, err)
+		return
+	}
+	response.Success(c, settings)
+}
+
+// Update validates, persists and applies runtime settings immediately
+// PUT /api/v1/admin/settings/runtime
+func (h *RuntimeSettingsHandler) Update(c *gin.Context) {
+	var req service.RuntimeSettings
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "Invalid request: "+err.Error())
+		return
+	}
+	subject, ok := middleware.GetAuthSubjectFromContext(c)
+	if !ok || subject.UserID <= 0 {
+		response.Error(c, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	before, _ := h.runtimeSettingsService.Get(c.Request.Context())
+	updated, err := h.runtimeSettingsService.Update(c.Request.Context(), &req, subject.UserID)
+	if err != nil {
+		response.ErrorFrom(c, err)
+		return
+	}
+	setAuditSnapshot(c, before, updated)
+	response.Success(c, updated)
+}
+
+// Reset removes the runtime overrides and falls back to the config file baseline
+// POST /api/v1/admin/settings/runtime/reset
+func (h *RuntimeSettingsHandler) Reset(c *gin.Context) {
+	subject, ok := middleware.GetAuthSubjectFromContext(c)
+	if !ok || subject.UserID <= 0 {
+		response.Error(c, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	before, _ := h.runtimeSettingsService.Get(c.Request.Context())
+	reset, err := h.runtimeSettingsService.Reset(c.Request.Context(), subject.UserID)
+	if err != nil {
+		response.ErrorFrom(c, err)
+		return
+	}
+	setAuditSnapshot(c, before, reset)
+	response.Success(c, reset)
+}
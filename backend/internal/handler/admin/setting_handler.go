@@ -125,6 +125,7 @@ func (h *SettingHandler) GetSettings(c *gin.Context) {
 		OpsMetricsIntervalSeconds:            settings.OpsMetricsIntervalSeconds,
 		MinClaudeCodeVersion:                 settings.MinClaudeCodeVersion,
 		MaxClaudeCodeVersion:                 settings.MaxClaudeCodeVersion,
+		MinCodexCLIVersion:                   settings.MinCodexCLIVersion,
 		AllowUngroupedKeyScheduling:          settings.AllowUngroupedKeyScheduling,
 	})
 }
@@ -199,6 +200,8 @@ type UpdateSettingsRequest struct {
 	MinClaudeCodeVersion string `json:"min_claude_code_version"`
 	MaxClaudeCodeVersion string `json:"max_claude_code_version"`
 
+	MinCodexCLIVersion string `json:"min_codex_cli_version"`
+
 	// 分组隔离
 	AllowUngroupedKeyScheduling bool `json:"allow_ungrouped_key_scheduling"`
 }
@@ -437,6 +440,14 @@ func (h *SettingHandler) UpdateSettings(c *gin.Context) {
 		}
 	}
 
+	// 验证 Codex CLI 最低版本号格式（空字符串=禁用，或合法 semver）
+	if req.MinCodexCLIVersion != "" {
+		if !semverPattern.MatchString(req.MinCodexCLIVersion) {
+			response.Error(c, http.StatusBadRequest, "min_codex_cli_version must be empty or a valid semver (e.g. 0.40.0)")
+			return
+		}
+	}
+
 	// 交叉验证：如果同时设置了最低和最高版本号，最高版本号必须 >= 最低版本号
 	if req.MinClaudeCodeVersion != "" && req.MaxClaudeCodeVersion != "" {
 		if service.CompareVersions(req.MaxClaudeCodeVersion, req.MinClaudeCodeVersion) < 0 {
@@ -491,6 +502,7 @@ func (h *SettingHandler) UpdateSettings(c *gin.Context) {
 		IdentityPatchPrompt:              req.IdentityPatchPrompt,
 		MinClaudeCodeVersion:             req.MinClaudeCodeVersion,
 		MaxClaudeCodeVersion:             req.MaxClaudeCodeVersion,
+		MinCodexCLIVersion:               req.MinCodexCLIVersion,
 		AllowUngroupedKeyScheduling:      req.AllowUngroupedKeyScheduling,
 		OpsMonitoringEnabled: func() bool {
 			if req.OpsMonitoringEnabled != nil {
@@ -531,6 +543,7 @@ func (h *SettingHandler) UpdateSettings(c *gin.Context) {
 		response.ErrorFrom(c, err)
 		return
 	}
+	setAuditSnapshot(c, previousSettings, updatedSettings)
 	updatedDefaultSubscriptions := make([]dto.DefaultSubscriptionSetting, 0, len(updatedSettings.DefaultSubscriptions))
 	for _, sub := range updatedSettings.DefaultSubscriptions {
 		updatedDefaultSubscriptions = append(updatedDefaultSubscriptions, dto.DefaultSubscriptionSetting{
@@ -590,6 +603,7 @@ func (h *SettingHandler) UpdateSettings(c *gin.Context) {
 		OpsMetricsIntervalSeconds:            updatedSettings.OpsMetricsIntervalSeconds,
 		MinClaudeCodeVersion:                 updatedSettings.MinClaudeCodeVersion,
 		MaxClaudeCodeVersion:                 updatedSettings.MaxClaudeCodeVersion,
+		MinCodexCLIVersion:                   updatedSettings.MinCodexCLIVersion,
 		AllowUngroupedKeyScheduling:          updatedSettings.AllowUngroupedKeyScheduling,
 	})
 }
@@ -745,6 +759,9 @@ func diffSettings(before *service.SystemSettings, after *service.SystemSettings,
 	if before.MaxClaudeCodeVersion != after.MaxClaudeCodeVersion {
 		changed = append(changed, "max_claude_code_version")
 	}
+	if before.MinCodexCLIVersion != after.MinCodexCLIVersion {
+		changed = append(changed, "min_codex_cli_version")
+	}
 	if before.AllowUngroupedKeyScheduling != after.AllowUngroupedKeyScheduling {
 		changed = append(changed, "allow_ungrouped_key_scheduling")
 	}
@@ -1483,6 +1500,322 @@ func (h *SettingHandler) UpdateBetaPolicySettings(c *gin.Context) {
 	response.Success(c, dto.BetaPolicySettings{Rules: outRules})
 }
 
+// GetClientRoutingSettings 获取客户端路由策略配置
+// GET /api/v1/admin/settings/client-routing
+func (h *SettingHandler) GetClientRoutingSettings(c *gin.Context) {
+	settings, err := h.settingService.GetClientRoutingSettings(c.Request.Context())
+	if err != nil {
+		response.ErrorFrom(c, err)
+		return
+	}
+	response.Success(c, clientRoutingSettingsToDTO(settings))
+}
+
+// UpdateClientRoutingSettingsRequest 更新客户端路由策略配置请求
+type UpdateClientRoutingSettingsRequest struct {
+	Enabled bool                    `json:"enabled"`
+	Rules   []dto.ClientRoutingRule `json:"rules"`
+}
+
+// UpdateClientRoutingSettings 更新客户端路由策略配置
+// PUT /api/v1/admin/settings/client-routing
+func (h *SettingHandler) UpdateClientRoutingSettings(c *gin.Context) {
+	var req UpdateClientRoutingSettingsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "Invalid request: "+err.Error())
+		return
+	}
+
+	rules := make([]service.ClientRoutingRule, len(req.Rules))
+	for i, r := range req.Rules {
+		rules[i] = service.ClientRoutingRule(r)
+	}
+
+	settings := &service.ClientRoutingSettings{Enabled: req.Enabled, Rules: rules}
+	if err := h.settingService.SetClientRoutingSettings(c.Request.Context(), settings); err != nil {
+		response.BadRequest(c, err.Error())
+		return
+	}
+
+	// Re-fetch to return updated settings
+	updated, err := h.settingService.GetClientRoutingSettings(c.Request.Context())
+	if err != nil {
+		response.ErrorFrom(c, err)
+		return
+	}
+	response.Success(c, clientRoutingSettingsToDTO(updated))
+}
+
+func clientRoutingSettingsToDTO(settings *service.ClientRoutingSettings) dto.ClientRoutingSettings {
+	rules := make([]dto.ClientRoutingRule, len(settings.Rules))
+	for i, r := range settings.Rules {
+		rules[i] = dto.ClientRoutingRule(r)
+	}
+	return dto.ClientRoutingSettings{Enabled: settings.Enabled, Rules: rules}
+}
+
+// GetRoutingRuleSettings 获取路由规则配置
+// GET /api/v1/admin/settings/routing-rules
+func (h *SettingHandler) GetRoutingRuleSettings(c *gin.Context) {
+	settings, err := h.settingService.GetRoutingRuleSettings(c.Request.Context())
+	if err != nil {
+		response.ErrorFrom(c, err)
+		return
+	}
+	response.Success(c, routingRuleSettingsToDTO(settings))
+}
+
+// UpdateRoutingRuleSettings 更新路由规则配置
+// PUT /api/v1/admin/settings/routing-rules
+func (h *SettingHandler) UpdateRoutingRuleSettings(c *gin.Context) {
+	var req dto.RoutingRuleSettings
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "Invalid request: "+err.Error())
+		return
+	}
+
+	rules := make([]service.RoutingRule, len(req.Rules))
+	for i, r := range req.Rules {
+		rules[i] = service.RoutingRule{
+			Name:            r.Name,
+			Enabled:         r.Enabled,
+			Priority:        r.Priority,
+			Models:          r.Models,
+			ClientTypes:     r.ClientTypes,
+			KeyTags:         r.KeyTags,
+			SourceGroupIDs:  r.SourceGroupIDs,
+			MinRequestBytes: r.MinRequestBytes,
+			MaxRequestBytes: r.MaxRequestBytes,
+			TargetGroupID:   r.TargetGroupID,
+		}
+		for _, hm := range r.Headers {
+			rules[i].Headers = append(rules[i].Headers, service.RoutingRuleHeaderMatch(hm))
+		}
+	}
+
+	settings := &service.RoutingRuleSettings{Enabled: req.Enabled, Rules: rules}
+	if err := h.settingService.SetRoutingRuleSettings(c.Request.Context(), settings); err != nil {
+		response.BadRequest(c, err.Error())
+		return
+	}
+
+	updated, err := h.settingService.GetRoutingRuleSettings(c.Request.Context())
+	if err != nil {
+		response.ErrorFrom(c, err)
+		return
+	}
+	response.Success(c, routingRuleSettingsToDTO(updated))
+}
+
+func routingRuleSettingsToDTO(settings *service.RoutingRuleSettings) dto.RoutingRuleSettings {
+	rules := make([]dto.RoutingRule, len(settings.Rules))
+	for i, r := range settings.Rules {
+		rules[i] = dto.RoutingRule{
+			Name:            r.Name,
+			Enabled:         r.Enabled,
+			Priority:        r.Priority,
+			Models:          r.Models,
+			ClientTypes:     r.ClientTypes,
+			KeyTags:         r.KeyTags,
+			SourceGroupIDs:  r.SourceGroupIDs,
+			MinRequestBytes: r.MinRequestBytes,
+			MaxRequestBytes: r.MaxRequestBytes,
+			TargetGroupID:   r.TargetGroupID,
+		}
+		for _, hm := range r.Headers {
+			rules[i].Headers = append(rules[i].Headers, dto.RoutingRuleHeaderMatch(hm))
+		}
+	}
+	return dto.RoutingRuleSettings{Enabled: settings.Enabled, Rules: rules}
+}
+
+// GetCanarySettings 获取金丝雀分流配置
+// GET /api/v1/admin/settings/canary-splits
+func (h *SettingHandler) GetCanarySettings(c *gin.Context) {
+	settings, err := h.settingService.GetCanarySettings(c.Request.Context())
+	if err != nil {
+		response.ErrorFrom(c, err)
+		return
+	}
+	response.Success(c, canarySettingsToDTO(settings))
+}
+
+// UpdateCanarySettings 更新金丝雀分流配置（保存后本实例的分流指标清零）
+// PUT /api/v1/admin/settings/canary-splits
+func (h *SettingHandler) UpdateCanarySettings(c *gin.Context) {
+	var req dto.CanarySettings
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "Invalid request: "+err.Error())
+		return
+	}
+
+	splits := make([]service.CanarySplit, len(req.Splits))
+	for i, sp := range req.Splits {
+		splits[i] = service.CanarySplit(sp)
+	}
+
+	settings := &service.CanarySettings{Enabled: req.Enabled, Splits: splits}
+	if err := h.settingService.SetCanarySettings(c.Request.Context(), settings); err != nil {
+		response.BadRequest(c, err.Error())
+		return
+	}
+
+	updated, err := h.settingService.GetCanarySettings(c.Request.Context())
+	if err != nil {
+		response.ErrorFrom(c, err)
+		return
+	}
+	response.Success(c, canarySettingsToDTO(updated))
+}
+
+// GetCanaryMetrics 获取本实例的金丝雀分流指标（control / canary 两侧的请求数、错误数与耗时）
+// GET /api/v1/admin/settings/canary-splits/metrics
+func (h *SettingHandler) GetCanaryMetrics(c *gin.Context) {
+	response.Success(c, service.GetCanaryMetricsSnapshot())
+}
+
+func canarySettingsToDTO(settings *service.CanarySettings) dto.CanarySettings {
+	splits := make([]dto.CanarySplit, len(settings.Splits))
+	for i, sp := range settings.Splits {
+		splits[i] = dto.CanarySplit(sp)
+	}
+	return dto.CanarySettings{Enabled: settings.Enabled, Splits: splits}
+}
+
+// GetShadowMirrorSettings 获取影子流量配置
+// GET /api/v1/admin/settings/shadow-mirror
+func (h *SettingHandler) GetShadowMirrorSettings(c *gin.Context) {
+	settings, err := h.settingService.GetShadowMirrorSettings(c.Request.Context())
+	if err != nil {
+		response.ErrorFrom(c, err)
+		return
+	}
+	response.Success(c, shadowMirrorSettingsToDTO(settings))
+}
+
+// UpdateShadowMirrorSettings 更新影子流量配置（保存后本实例的对比指标与样本清空）
+// PUT /api/v1/admin/settings/shadow-mirror
+func (h *SettingHandler) UpdateShadowMirrorSettings(c *gin.Context) {
+	var req dto.ShadowMirrorSettings
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "Invalid request: "+err.Error())
+		return
+	}
+
+	rules := make([]service.ShadowMirrorRule, len(req.Rules))
+	for i, r := range req.Rules {
+		rules[i] = service.ShadowMirrorRule(r)
+	}
+
+	settings := &service.ShadowMirrorSettings{Enabled: req.Enabled, Rules: rules}
+	if err := h.settingService.SetShadowMirrorSettings(c.Request.Context(), settings); err != nil {
+		response.BadRequest(c, err.Error())
+		return
+	}
+
+	updated, err := h.settingService.GetShadowMirrorSettings(c.Request.Context())
+	if err != nil {
+		response.ErrorFrom(c, err)
+		return
+	}
+	response.Success(c, shadowMirrorSettingsToDTO(updated))
+}
+
+// GetShadowMirrorSamples 获取本实例的影子流量指标与最近对比样本
+// GET /api/v1/admin/settings/shadow-mirror/samples
+func (h *SettingHandler) GetShadowMirrorSamples(c *gin.Context) {
+	response.Success(c, service.GetShadowMirrorSnapshot())
+}
+
+func shadowMirrorSettingsToDTO(settings *service.ShadowMirrorSettings) dto.ShadowMirrorSettings {
+	rules := make([]dto.ShadowMirrorRule, len(settings.Rules))
+	for i, r := range settings.Rules {
+		rules[i] = dto.ShadowMirrorRule(r)
+	}
+	return dto.ShadowMirrorSettings{Enabled: settings.Enabled, Rules: rules}
+}
+
+// GetHeaderPolicySettings 获取请求/响应头策略配置
+// GET /api/v1/admin/settings/header-policy
+func (h *SettingHandler) GetHeaderPolicySettings(c *gin.Context) {
+	settings, err := h.settingService.GetHeaderPolicySettings(c.Request.Context())
+	if err != nil {
+		response.ErrorFrom(c, err)
+		return
+	}
+	response.Success(c, headerPolicySettingsToDTO(settings))
+}
+
+// UpdateHeaderPolicySettings 更新请求/响应头策略配置
+// PUT /api/v1/admin/settings/header-policy
+func (h *SettingHandler) UpdateHeaderPolicySettings(c *gin.Context) {
+	var req dto.HeaderPolicySettings
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "Invalid request: "+err.Error())
+		return
+	}
+
+	rules := make([]service.HeaderPolicyRule, len(req.Rules))
+	for i, r := range req.Rules {
+		rules[i] = service.HeaderPolicyRule(r)
+	}
+
+	settings := &service.HeaderPolicySettings{Enabled: req.Enabled, Rules: rules}
+	if err := h.settingService.SetHeaderPolicySettings(c.Request.Context(), settings); err != nil {
+		response.BadRequest(c, err.Error())
+		return
+	}
+
+	updated, err := h.settingService.GetHeaderPolicySettings(c.Request.Context())
+	if err != nil {
+		response.ErrorFrom(c, err)
+		return
+	}
+	response.Success(c, headerPolicySettingsToDTO(updated))
+}
+
+func headerPolicySettingsToDTO(settings *service.HeaderPolicySettings) dto.HeaderPolicySettings {
+	rules := make([]dto.HeaderPolicyRule, len(settings.Rules))
+	for i, r := range settings.Rules {
+		rules[i] = dto.HeaderPolicyRule(r)
+	}
+	return dto.HeaderPolicySettings{Enabled: settings.Enabled, Rules: rules}
+}
+
+// GetModelAliasSettings 获取全局模型别名表
+// GET /api/v1/admin/settings/model-aliases
+func (h *SettingHandler) GetModelAliasSettings(c *gin.Context) {
+	settings, err := h.settingService.GetModelAliasSettings(c.Request.Context())
+	if err != nil {
+		response.ErrorFrom(c, err)
+		return
+	}
+	response.Success(c, dto.ModelAliasSettings(*settings))
+}
+
+// UpdateModelAliasSettings 更新全局模型别名表
+// PUT /api/v1/admin/settings/model-aliases
+func (h *SettingHandler) UpdateModelAliasSettings(c *gin.Context) {
+	var req dto.ModelAliasSettings
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "Invalid request: "+err.Error())
+		return
+	}
+
+	settings := service.ModelAliasSettings(req)
+	if err := h.settingService.SetModelAliasSettings(c.Request.Context(), &settings); err != nil {
+		response.ErrorFrom(c, err)
+		return
+	}
+
+	updated, err := h.settingService.GetModelAliasSettings(c.Request.Context())
+	if err != nil {
+		response.ErrorFrom(c, err)
+		return
+	}
+	response.Success(c, dto.ModelAliasSettings(*updated))
+}
+
 // UpdateStreamTimeoutSettingsRequest 更新流超时配置请求
 type UpdateStreamTimeoutSettingsRequest struct {
 	Enabled                bool   `json:"enabled"`
@@ -1529,3 +1862,48 @@ func (h *SettingHandler) UpdateStreamTimeoutSettings(c *gin.Context) {
 		ThresholdWindowMinutes: updatedSettings.ThresholdWindowMinutes,
 	})
 }
+
+// GetPricingOverrideSettings 获取模型价格表
+// GET /api/v1/admin/settings/pricing-overrides
+func (h *SettingHandler) GetPricingOverrideSettings(c *gin.Context) {
+	settings, err := h.settingService.GetPricingOverrideSettings(c.Request.Context())
+	if err != nil {
+		response.ErrorFrom(c, err)
+		return
+	}
+	response.Success(c, pricingOverrideSettingsToDTO(settings))
+}
+
+// UpdatePricingOverrideSettings 更新模型价格表
+// PUT /api/v1/admin/settings/pricing-overrides
+func (h *SettingHandler) UpdatePricingOverrideSettings(c *gin.Context) {
+	var req dto.PricingOverrideSettings
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "Invalid request: "+err.Error())
+		return
+	}
+
+	overrides := make([]service.ModelPriceOverride, len(req.Overrides))
+	for i, o := range req.Overrides {
+		overrides[i] = service.ModelPriceOverride(o)
+	}
+	if err := h.settingService.SetPricingOverrideSettings(c.Request.Context(), &service.PricingOverrideSettings{Overrides: overrides}); err != nil {
+		response.ErrorFrom(c, err)
+		return
+	}
+
+	updated, err := h.settingService.GetPricingOverrideSettings(c.Request.Context())
+	if err != nil {
+		response.ErrorFrom(c, err)
+		return
+	}
+	response.Success(c, pricingOverrideSettingsToDTO(updated))
+}
+
+func pricingOverrideSettingsToDTO(settings *service.PricingOverrideSettings) dto.PricingOverrideSettings {
+	overrides := make([]dto.ModelPriceOverride, len(settings.Overrides))
+	for i, o := range settings.Overrides {
+		overrides[i] = dto.ModelPriceOverride(o)
+	}
+	return dto.PricingOverrideSettings{Overrides: overrides}
+}
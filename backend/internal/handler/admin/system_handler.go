@@ -19,16 +19,30 @@ import (
 type SystemHandler struct {
 	updateSvc *service.UpdateService
 	lockSvc   *service.SystemOperationLockService
+	reloadSvc *service.ConfigReloadService
 }
 
 // NewSystemHandler creates a new SystemHandler
-func NewSystemHandler(updateSvc *service.UpdateService, lockSvc *service.SystemOperationLockService) *SystemHandler {
+func NewSystemHandler(updateSvc *service.UpdateService, lockSvc *service.SystemOperationLockService, reloadSvc *service.ConfigReloadService) *SystemHandler {
 	return &SystemHandler{
 		updateSvc: updateSvc,
 		lockSvc:   lockSvc,
+		reloadSvc: reloadSvc,
 	}
 }
 
+// ReloadConfig reloads hot-reloadable config (routing rules, model aliases, pricing tables, UA prefixes)
+// without restarting; in-flight requests and streams are not interrupted.
+// POST /api/v1/admin/reload
+func (h *SystemHandler) ReloadConfig(c *gin.Context) {
+	result, err := h.reloadSvc.Reload(c.Request.Context())
+	if err != nil {
+		response.ErrorFrom(c, err)
+		return
+	}
+	response.Success(c, result)
+}
+
 // GetVersion returns the current version
 // GET /api/v1/admin/system/version
 func (h *SystemHandler) GetVersion(c *gin.Context) {
@@ -0,0 +1,245 @@
+package admin
+
+import (
+	"context"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ShaohongDong/sub2api/internal/handler/dto"
+	"github.com/ShaohongDong/sub2api/internal/pkg/pagination"
+	"github.com/ShaohongDong/sub2api/internal/pkg/response"
+	"github.com/ShaohongDong/sub2api/internal/pkg/timezone"
+	"github.com/ShaohongDong/sub2api/internal/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+// TenantHandler handles admin tenant management
+type TenantHandler struct {
+	tenantService *service.TenantService
+}
+
+// NewTenantHandler creates a new admin tenant handler
+func NewTenantHandler(tenantService *service.TenantService) *TenantHandler {
+	return &TenantHandler{
+		tenantService: tenantService,
+	}
+}
+
+type CreateTenantRequest struct {
+	Name        string `json:"name" binding:"required,max=100"`
+	Description string `json:"description" binding:"max=500"`
+	Status      string `json:"status" binding:"omitempty,oneof=active disabled"`
+}
+
+type UpdateTenantRequest struct {
+	Name        *string `json:"name" binding:"omitempty,max=100"`
+	Description *string `json:"description" binding:"omitempty,max=500"`
+	Status      *string `json:"status" binding:"omitempty,oneof=active disabled"`
+}
+
+// TenantMembersRequest lists the user or group IDs to add to / remove from a tenant
+type TenantMembersRequest struct {
+	IDs []int64 `json:"ids" binding:"required,min=1"`
+}
+
+// List handles listing tenants
+// GET /api/v1/admin/tenants
+func (h *TenantHandler) List(c *gin.Context) {
+	page, pageSize := response.ParsePagination(c)
+	search := strings.TrimSpace(c.Query("search"))
+	if len(search) > 100 {
+		search = search[:100]
+	}
+
+	params := pagination.PaginationParams{
+		Page:     page,
+		PageSize: pageSize,
+	}
+	items, paginationResult, err := h.tenantService.List(
+		c.Request.Context(),
+		params,
+		service.TenantListFilters{Status: strings.TrimSpace(c.Query("status")), Search: search},
+	)
+	if err != nil {
+		response.ErrorFrom(c, err)
+		return
+	}
+
+	out := make([]dto.Tenant, 0, len(items))
+	for i := range items {
+		out = append(out, *dto.TenantFromService(&items[i]))
+	}
+	response.Paginated(c, out, paginationResult.Total, page, pageSize)
+}
+
+// GetByID handles getting a tenant by ID
+// GET /api/v1/admin/tenants/:id
+func (h *TenantHandler) GetByID(c *gin.Context) {
+	tenantID, ok := parseTenantID(c)
+	if !ok {
+		return
+	}
+
+	item, err := h.tenantService.GetByID(c.Request.Context(), tenantID)
+	if err != nil {
+		response.ErrorFrom(c, err)
+		return
+	}
+	response.Success(c, dto.TenantFromService(item))
+}
+
+// Create handles creating a tenant
+// POST /api/v1/admin/tenants
+func (h *TenantHandler) Create(c *gin.Context) {
+	var req CreateTenantRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "Invalid request: "+err.Error())
+		return
+	}
+
+	created, err := h.tenantService.Create(c.Request.Context(), &service.CreateTenantInput{
+		Name:        req.Name,
+		Description: req.Description,
+		Status:      req.Status,
+	})
+	if err != nil {
+		response.ErrorFrom(c, err)
+		return
+	}
+	response.Success(c, dto.TenantFromService(created))
+}
+
+// Update handles updating a tenant
+// PUT /api/v1/admin/tenants/:id
+func (h *TenantHandler) Update(c *gin.Context) {
+	tenantID, ok := parseTenantID(c)
+	if !ok {
+		return
+	}
+
+	var req UpdateTenantRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "Invalid request: "+err.Error())
+		return
+	}
+
+	updated, err := h.tenantService.Update(c.Request.Context(), tenantID, &service.UpdateTenantInput{
+		Name:        req.Name,
+		Description: req.Description,
+		Status:      req.Status,
+	})
+	if err != nil {
+		response.ErrorFrom(c, err)
+		return
+	}
+	response.Success(c, dto.TenantFromService(updated))
+}
+
+// Delete handles deleting a tenant (only allowed once it has no users or groups)
+// DELETE /api/v1/admin/tenants/:id
+func (h *TenantHandler) Delete(c *gin.Context) {
+	tenantID, ok := parseTenantID(c)
+	if !ok {
+		return
+	}
+
+	if err := h.tenantService.Delete(c.Request.Context(), tenantID); err != nil {
+		response.ErrorFrom(c, err)
+		return
+	}
+	response.Success(c, gin.H{"message": "Tenant deleted successfully"})
+}
+
+// AssignUsers handles moving users into a tenant
+// POST /api/v1/admin/tenants/:id/users
+func (h *TenantHandler) AssignUsers(c *gin.Context) {
+	h.updateMembers(c, h.tenantService.AssignUsers)
+}
+
+// RemoveUsers handles removing users from a tenant
+// POST /api/v1/admin/tenants/:id/users/remove
+func (h *TenantHandler) RemoveUsers(c *gin.Context) {
+	h.updateMembers(c, h.tenantService.RemoveUsers)
+}
+
+// AssignGroups handles moving groups (and their account pools) into a tenant
+// POST /api/v1/admin/tenants/:id/groups
+func (h *TenantHandler) AssignGroups(c *gin.Context) {
+	h.updateMembers(c, h.tenantService.AssignGroups)
+}
+
+// RemoveGroups handles removing groups from a tenant
+// POST /api/v1/admin/tenants/:id/groups/remove
+func (h *TenantHandler) RemoveGroups(c *gin.Context) {
+	h.updateMembers(c, h.tenantService.RemoveGroups)
+}
+
+// GetStats handles getting member counts and usage totals for a tenant
+// GET /api/v1/admin/tenants/:id/stats
+// Query params:
+//   - start_date / end_date: optional date range (YYYY-MM-DD), defaults to all time
+//   - timezone: timezone used to interpret the date range
+func (h *TenantHandler) GetStats(c *gin.Context) {
+	tenantID, ok := parseTenantID(c)
+	if !ok {
+		return
+	}
+
+	userTZ := c.Query("timezone")
+	var startTime, endTime *time.Time
+	if startDateStr := c.Query("start_date"); startDateStr != "" {
+		t, err := timezone.ParseInUserLocation("2006-01-02", startDateStr, userTZ)
+		if err != nil {
+			response.BadRequest(c, "Invalid start_date format, use YYYY-MM-DD")
+			return
+		}
+		startTime = &t
+	}
+	if endDateStr := c.Query("end_date"); endDateStr != "" {
+		t, err := timezone.ParseInUserLocation("2006-01-02", endDateStr, userTZ)
+		if err != nil {
+			response.BadRequest(c, "Invalid end_date format, use YYYY-MM-DD")
+			return
+		}
+		t = t.Add(24*time.Hour - time.Nanosecond)
+		endTime = &t
+	}
+
+	stats, err := h.tenantService.GetStats(c.Request.Context(), tenantID, startTime, endTime)
+	if err != nil {
+		response.ErrorFrom(c, err)
+		return
+	}
+	response.Success(c, stats)
+}
+
+func (h *TenantHandler) updateMembers(c *gin.Context, update func(ctx context.Context, tenantID int64, ids []int64) (int64, error)) {
+	tenantID, ok := parseTenantID(c)
+	if !ok {
+		return
+	}
+
+	var req TenantMembersRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "Invalid request: "+err.Error())
+		return
+	}
+
+	affected, err := update(c.Request.Context(), tenantID, req.IDs)
+	if err != nil {
+		response.ErrorFrom(c, err)
+		return
+	}
+	response.Success(c, gin.H{"affected": affected})
+}
+
+func parseTenantID(c *gin.Context) (int64, bool) {
+	tenantID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil || tenantID <= 0 {
+		response.BadRequest(c, "Invalid tenant ID")
+		return 0, false
+	}
+	return tenantID, true
+}
@@ -0,0 +1,255 @@
+package admin
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ShaohongDong/sub2api/internal/pkg/logger"
+	"github.com/ShaohongDong/sub2api/internal/pkg/response"
+	"github.com/ShaohongDong/sub2api/internal/pkg/timezone"
+	"github.com/ShaohongDong/sub2api/internal/pkg/usagestats"
+	"github.com/ShaohongDong/sub2api/internal/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+// usageExportFlushRows 每写出多少行刷新一次响应，避免大报表长时间无输出
+const usageExportFlushRows = 500
+
+// usageExportDetailHeader 明细导出的 CSV 表头
+var usageExportDetailHeader = []string{
+	"id", "created_at", "request_id", "user_id", "user_email", "api_key_id", "api_key_name",
+	"account_id", "account_name", "group_id", "group_name", "model", "stream",
+	"input_tokens", "output_tokens", "reasoning_tokens", "cache_creation_tokens", "cache_read_tokens", "total_tokens",
+	"cost", "actual_cost", "account_cost", "duration_ms",
+}
+
+// Export streams a usage and cost report for chargeback
+// GET /api/v1/admin/usage/export
+// Query params: from, to (YYYY-MM-DD inclusive in timezone, or RFC3339), timezone,
+// group_by (comma-separated: api_key,model,account,user,group; empty together with granularity = one row per request),
+// granularity (hour/day/week/month), user_id, api_key_id, account_id, group_id, model, format (csv/json, default csv)
+func (h *UsageHandler) Export(c *gin.Context) {
+	userTZ := c.Query("timezone")
+	from := strings.TrimSpace(c.Query("from"))
+	if from == "" {
+		response.BadRequest(c, "from is required")
+		return
+	}
+	startTime, err := parseUsageExportTime(from, userTZ, false)
+	if err != nil {
+		response.BadRequest(c, "Invalid from, use YYYY-MM-DD or RFC3339")
+		return
+	}
+	endTime := timezone.NowInUserLocation(userTZ)
+	if to := strings.TrimSpace(c.Query("to")); to != "" {
+		if endTime, err = parseUsageExportTime(to, userTZ, true); err != nil {
+			response.BadRequest(c, "Invalid to, use YYYY-MM-DD or RFC3339")
+			return
+		}
+	}
+
+	query := usagestats.UsageAggregateQuery{
+		StartTime:   startTime,
+		EndTime:     endTime,
+		Granularity: strings.ToLower(strings.TrimSpace(c.Query("granularity"))),
+		Model:       strings.TrimSpace(c.Query("model")),
+	}
+	seenDims := make(map[string]struct{})
+	for _, dim := range strings.Split(c.Query("group_by"), ",") {
+		dim = strings.ToLower(strings.TrimSpace(dim))
+		if _, ok := seenDims[dim]; ok || dim == "" {
+			continue
+		}
+		seenDims[dim] = struct{}{}
+		query.GroupBy = append(query.GroupBy, dim)
+	}
+	for name, target := range map[string]*int64{
+		"user_id":    &query.UserID,
+		"api_key_id": &query.APIKeyID,
+		"account_id": &query.AccountID,
+		"group_id":   &query.GroupID,
+	} {
+		if raw := c.Query(name); raw != "" {
+			id, err := strconv.ParseInt(raw, 10, 64)
+			if err != nil || id <= 0 {
+				response.BadRequest(c, "Invalid "+name)
+				return
+			}
+			*target = id
+		}
+	}
+	format := strings.ToLower(strings.TrimSpace(c.DefaultQuery("format", "csv")))
+	if format != "json" && format != "csv" {
+		response.BadRequest(c, "Invalid format, use json or csv")
+		return
+	}
+
+	stream := &usageExportStream{c: c, format: format, query: query}
+	err = h.usageService.ExportUsage(c.Request.Context(), query,
+		func(row *usagestats.UsageAggregate) error {
+			return stream.write(func() []string { return usageAggregateCSVRecord(stream.query, row) }, row)
+		},
+		func(row *usagestats.UsageExportRow) error {
+			return stream.write(func() []string { return usageExportDetailRecord(row) }, row)
+		},
+	)
+	stream.finish(err)
+}
+
+// parseUsageExportTime 解析导出时间：日期按用户时区解释（to 含当天），否则按 RFC3339 精确时间
+func parseUsageExportTime(value, userTZ string, inclusiveEnd bool) (time.Time, error) {
+	if len(value) == len("2006-01-02") {
+		t, err := timezone.ParseInUserLocation("2006-01-02", value, userTZ)
+		if err != nil {
+			return time.Time{}, err
+		}
+		if inclusiveEnd {
+			t = t.AddDate(0, 0, 1)
+		}
+		return t, nil
+	}
+	return time.Parse(time.RFC3339, value)
+}
+
+func usageExportDetailRecord(row *usagestats.UsageExportRow) []string {
+	return []string{
+		strconv.FormatInt(row.ID, 10),
+		row.CreatedAt.UTC().Format(time.RFC3339),
+		row.RequestID,
+		strconv.FormatInt(row.UserID, 10),
+		row.UserEmail,
+		strconv.FormatInt(row.APIKeyID, 10),
+		row.APIKeyName,
+		strconv.FormatInt(row.AccountID, 10),
+		row.AccountName,
+		strconv.FormatInt(row.GroupID, 10),
+		row.GroupName,
+		row.Model,
+		strconv.FormatBool(row.Stream),
+		strconv.FormatInt(row.InputTokens, 10),
+		strconv.FormatInt(row.OutputTokens, 10),
+		strconv.FormatInt(row.ReasoningTokens, 10),
+		strconv.FormatInt(row.CacheCreationTokens, 10),
+		strconv.FormatInt(row.CacheReadTokens, 10),
+		strconv.FormatInt(row.TotalTokens, 10),
+		strconv.FormatFloat(row.Cost, 'f', 6, 64),
+		strconv.FormatFloat(row.ActualCost, 'f', 6, 64),
+		strconv.FormatFloat(row.AccountCost, 'f', 6, 64),
+		strconv.FormatInt(row.DurationMs, 10),
+	}
+}
+
+// usageExportStream 边查询边写出报表。
+// 响应头延迟到首行数据（或导出结束）时才写出：此前的错误（参数校验、查询失败）仍按普通错误响应返回；
+// 开始输出后发生的错误无法再修改状态码，JSON 在 data.error 中标注，CSV 只能截断并记录日志。
+type usageExportStream struct {
+	c       *gin.Context
+	format  string
+	query   usagestats.UsageAggregateQuery
+	csv     *csv.Writer
+	started bool
+	count   int
+}
+
+func (s *usageExportStream) begin() error {
+	if s.started {
+		return nil
+	}
+	s.started = true
+
+	filename := fmt.Sprintf("usage_report_%s_%s.%s",
+		s.query.StartTime.Format("20060102"), s.query.EndTime.Format("20060102"), s.format)
+	s.c.Header("Content-Disposition", "attachment; filename="+filename)
+	s.c.Header("Cache-Control", "no-cache")
+	if s.format == "csv" {
+		s.c.Header("Content-Type", "text/csv; charset=utf-8")
+		s.c.Status(200)
+		s.csv = csv.NewWriter(s.c.Writer)
+		header := usageExportDetailHeader
+		if !service.IsUsageExportDetail(s.query) {
+			header = usageAggregateCSVHeader(s.query)
+		}
+		return s.csv.Write(header)
+	}
+
+	s.c.Header("Content-Type", "application/json; charset=utf-8")
+	s.c.Status(200)
+	groupBy := s.query.GroupBy
+	if groupBy == nil {
+		groupBy = []string{}
+	}
+	meta, err := json.Marshal(map[string]any{
+		"from":        s.query.StartTime,
+		"to":          s.query.EndTime,
+		"group_by":    groupBy,
+		"granularity": s.query.Granularity,
+	})
+	if err != nil {
+		return err
+	}
+	// 以统一响应格式输出：{"code":0,"message":"success","data":{...,"items":[...],"count":N}}
+	_, err = io.WriteString(s.c.Writer, `{"code":0,"message":"success","data":`+string(meta[:len(meta)-1])+`,"items":[`)
+	return err
+}
+
+func (s *usageExportStream) write(record func() []string, item any) error {
+	if err := s.begin(); err != nil {
+		return err
+	}
+	if s.format == "csv" {
+		if err := s.csv.Write(record()); err != nil {
+			return err
+		}
+	} else {
+		encoded, err := json.Marshal(item)
+		if err != nil {
+			return err
+		}
+		if s.count > 0 {
+			encoded = append([]byte{','}, encoded...)
+		}
+		if _, err := s.c.Writer.Write(encoded); err != nil {
+			return err
+		}
+	}
+	s.count++
+	if s.count%usageExportFlushRows == 0 {
+		s.flush()
+	}
+	return nil
+}
+
+func (s *usageExportStream) flush() {
+	if s.csv != nil {
+		s.csv.Flush()
+	}
+	s.c.Writer.Flush()
+}
+
+func (s *usageExportStream) finish(exportErr error) {
+	if !s.started && exportErr != nil {
+		response.ErrorFrom(s.c, exportErr)
+		return
+	}
+	if err := s.begin(); err != nil {
+		exportErr = err
+	}
+	if exportErr != nil {
+		logger.LegacyPrintf("handler.admin.usage", "[UsageExport] 导出中断: rows=%d err=%v", s.count, exportErr)
+	}
+	if s.format == "json" {
+		tail := `],"count":` + strconv.Itoa(s.count)
+		if exportErr != nil {
+			msg, _ := json.Marshal(exportErr.Error())
+			tail += `,"error":` + string(msg)
+		}
+		_, _ = io.WriteString(s.c.Writer, tail+"}}")
+	}
+	s.flush()
+}
@@ -0,0 +1,145 @@
+package admin
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ShaohongDong/sub2api/internal/pkg/usagestats"
+	"github.com/ShaohongDong/sub2api/internal/service"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/require"
+)
+
+type usageExportRepoStub struct {
+	service.UsageLogRepository
+	query      *usagestats.UsageAggregateQuery
+	aggregates []usagestats.UsageAggregate
+	rows       []usagestats.UsageExportRow
+	failAfter  int
+}
+
+var errUsageExportStub = errors.New("connection reset")
+
+func (s *usageExportRepoStub) ForEachUsageAggregate(_ context.Context, query usagestats.UsageAggregateQuery, fn func(*usagestats.UsageAggregate) error) error {
+	s.query = &query
+	for i := range s.aggregates {
+		if s.failAfter > 0 && i == s.failAfter {
+			return errUsageExportStub
+		}
+		if err := fn(&s.aggregates[i]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *usageExportRepoStub) ForEachUsageExportRow(_ context.Context, query usagestats.UsageAggregateQuery, fn func(*usagestats.UsageExportRow) error) error {
+	s.query = &query
+	if s.failAfter < 0 {
+		return errUsageExportStub
+	}
+	for i := range s.rows {
+		if err := fn(&s.rows[i]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func serveUsageExport(t *testing.T, repo *usageExportRepoStub, rawQuery string) *httptest.ResponseRecorder {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+	handler := NewUsageHandler(service.NewUsageService(repo, nil, nil, nil), nil, nil, nil)
+	router := gin.New()
+	router.GET("/admin/usage/export", handler.Export)
+
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/admin/usage/export?"+rawQuery, nil))
+	return rec
+}
+
+func TestUsageExport_DetailCSV(t *testing.T) {
+	createdAt := time.Date(2026, 3, 1, 8, 30, 0, 0, time.UTC)
+	repo := &usageExportRepoStub{rows: []usagestats.UsageExportRow{
+		{ID: 11, CreatedAt: createdAt, RequestID: "req-1", UserID: 2, UserEmail: "a@example.com", APIKeyID: 3, APIKeyName: "team, a",
+			Model: "gpt-5", Stream: true, InputTokens: 10, OutputTokens: 5, TotalTokens: 15, Cost: 0.25, ActualCost: 0.2, AccountCost: 0.1, DurationMs: 900},
+	}}
+
+	rec := serveUsageExport(t, repo, "from=2026-03-01&to=2026-03-31&user_id=2")
+	require.Equal(t, http.StatusOK, rec.Code)
+	require.Equal(t, "text/csv; charset=utf-8", rec.Header().Get("Content-Type"))
+	require.Contains(t, rec.Header().Get("Content-Disposition"), "usage_report_20260301_20260401.csv")
+	require.Equal(t, int64(2), repo.query.UserID)
+	require.Equal(t, time.Date(2026, 4, 1, 0, 0, 0, 0, time.UTC), repo.query.EndTime.UTC())
+
+	records, err := csv.NewReader(strings.NewReader(rec.Body.String())).ReadAll()
+	require.NoError(t, err)
+	require.Len(t, records, 2)
+	require.Equal(t, usageExportDetailHeader, records[0])
+	require.Equal(t, []string{"11", "2026-03-01T08:30:00Z", "req-1", "2", "a@example.com", "3", "team, a", "0", "", "0", "", "gpt-5", "true",
+		"10", "5", "0", "0", "0", "15", "0.250000", "0.200000", "0.100000", "900"}, records[1])
+}
+
+func TestUsageExport_AggregateJSON(t *testing.T) {
+	repo := &usageExportRepoStub{aggregates: []usagestats.UsageAggregate{
+		{Bucket: "2026-03-01", UserID: 2, UserEmail: "a@example.com", Requests: 4, Cost: 1},
+		{Bucket: "2026-03-02", UserID: 2, UserEmail: "a@example.com", Requests: 1, Cost: 0.5},
+	}}
+
+	rec := serveUsageExport(t, repo, "from=2026-03-01&to=2026-03-02&group_by=user,user&granularity=day&format=json")
+	require.Equal(t, http.StatusOK, rec.Code)
+	require.Equal(t, []string{"user"}, repo.query.GroupBy)
+	require.Zero(t, repo.query.Limit, "export is not capped like dashboard aggregates")
+
+	var body struct {
+		Code int `json:"code"`
+		Data struct {
+			GroupBy     []string                    `json:"group_by"`
+			Granularity string                      `json:"granularity"`
+			Items       []usagestats.UsageAggregate `json:"items"`
+			Count       int                         `json:"count"`
+			Error       string                      `json:"error"`
+		} `json:"data"`
+	}
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &body))
+	require.Equal(t, 0, body.Code)
+	require.Equal(t, []string{"user"}, body.Data.GroupBy)
+	require.Equal(t, "day", body.Data.Granularity)
+	require.Equal(t, repo.aggregates, body.Data.Items)
+	require.Equal(t, 2, body.Data.Count)
+	require.Empty(t, body.Data.Error)
+}
+
+func TestUsageExport_Errors(t *testing.T) {
+	rec := serveUsageExport(t, &usageExportRepoStub{}, "to=2026-03-02")
+	require.Equal(t, http.StatusBadRequest, rec.Code)
+
+	rec = serveUsageExport(t, &usageExportRepoStub{}, "from=2026-03-01&group_by=tenant")
+	require.Equal(t, http.StatusBadRequest, rec.Code)
+	require.Contains(t, rec.Body.String(), "USAGE_AGGREGATE_INVALID_GROUP_BY")
+
+	// 尚未输出任何数据时失败：按普通错误响应返回
+	rec = serveUsageExport(t, &usageExportRepoStub{failAfter: -1}, "from=2026-03-01&to=2026-03-02")
+	require.Equal(t, http.StatusInternalServerError, rec.Code)
+
+	// 输出过程中失败：JSON 保持合法并在 data.error 中标注
+	repo := &usageExportRepoStub{failAfter: 1, aggregates: []usagestats.UsageAggregate{{Model: "gpt-5"}, {Model: "o3"}}}
+	rec = serveUsageExport(t, repo, "from=2026-03-01&to=2026-03-02&group_by=model&format=json")
+	require.Equal(t, http.StatusOK, rec.Code)
+	var body struct {
+		Data struct {
+			Count int    `json:"count"`
+			Error string `json:"error"`
+		} `json:"data"`
+	}
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &body))
+	require.Equal(t, 1, body.Data.Count)
+	require.Contains(t, body.Data.Error, "connection reset")
+}
@@ -72,7 +72,7 @@ func (h *UsageHandler) List(c *gin.Context) {
 	}
 
 	// Parse filters
-	var userID, apiKeyID, accountID, groupID int64
+	var userID, apiKeyID, accountID, groupID, tenantID int64
 	if userIDStr := c.Query("user_id"); userIDStr != "" {
 		id, err := strconv.ParseInt(userIDStr, 10, 64)
 		if err != nil {
@@ -109,6 +109,15 @@ func (h *UsageHandler) List(c *gin.Context) {
 		groupID = id
 	}
 
+	if tenantIDStr := c.Query("tenant_id"); tenantIDStr != "" {
+		id, err := strconv.ParseInt(tenantIDStr, 10, 64)
+		if err != nil {
+			response.BadRequest(c, "Invalid tenant_id")
+			return
+		}
+		tenantID = id
+	}
+
 	model := c.Query("model")
 
 	var requestType *int16
@@ -170,6 +179,7 @@ func (h *UsageHandler) List(c *gin.Context) {
 		APIKeyID:    apiKeyID,
 		AccountID:   accountID,
 		GroupID:     groupID,
+		TenantID:    tenantID,
 		Model:       model,
 		RequestType: requestType,
 		Stream:      stream,
@@ -196,7 +206,7 @@ func (h *UsageHandler) List(c *gin.Context) {
 // GET /api/v1/admin/usage/stats
 func (h *UsageHandler) Stats(c *gin.Context) {
 	// Parse filters - same as List endpoint
-	var userID, apiKeyID, accountID, groupID int64
+	var userID, apiKeyID, accountID, groupID, tenantID int64
 	if userIDStr := c.Query("user_id"); userIDStr != "" {
 		id, err := strconv.ParseInt(userIDStr, 10, 64)
 		if err != nil {
@@ -233,6 +243,15 @@ func (h *UsageHandler) Stats(c *gin.Context) {
 		groupID = id
 	}
 
+	if tenantIDStr := c.Query("tenant_id"); tenantIDStr != "" {
+		id, err := strconv.ParseInt(tenantIDStr, 10, 64)
+		if err != nil {
+			response.BadRequest(c, "Invalid tenant_id")
+			return
+		}
+		tenantID = id
+	}
+
 	model := c.Query("model")
 
 	var requestType *int16
@@ -307,6 +326,7 @@ func (h *UsageHandler) Stats(c *gin.Context) {
 		APIKeyID:    apiKeyID,
 		AccountID:   accountID,
 		GroupID:     groupID,
+		TenantID:    tenantID,
 		Model:       model,
 		RequestType: requestType,
 		Stream:      stream,
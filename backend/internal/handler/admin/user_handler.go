@@ -61,6 +61,11 @@ type UpdateUserRequest struct {
 	SoraStorageQuotaBytes *int64             `json:"sora_storage_quota_bytes"`
 }
 
+// UpdateUserRoleRequest represents a user role change request
+type UpdateUserRoleRequest struct {
+	Role string `json:"role" binding:"required,oneof=user viewer operator"`
+}
+
 // UpdateBalanceRequest represents balance update request
 type UpdateBalanceRequest struct {
 	Balance   float64 `json:"balance" binding:"required,gt=0"`
@@ -74,6 +79,7 @@ type UpdateBalanceRequest struct {
 //   - status: filter by user status
 //   - role: filter by user role
 //   - search: search in email, username
+//   - tenant_id: filter by tenant
 //   - attr[{id}]: filter by custom attribute value, e.g. attr[1]=company
 func (h *UserHandler) List(c *gin.Context) {
 	page, pageSize := response.ParsePagination(c)
@@ -91,6 +97,14 @@ func (h *UserHandler) List(c *gin.Context) {
 		Search:     search,
 		Attributes: parseAttributeFilters(c),
 	}
+	if tenantIDStr := c.Query("tenant_id"); tenantIDStr != "" {
+		tenantID, err := strconv.ParseInt(tenantIDStr, 10, 64)
+		if err != nil {
+			response.BadRequest(c, "Invalid tenant_id")
+			return
+		}
+		filters.TenantID = tenantID
+	}
 	if raw, ok := c.GetQuery("include_subscriptions"); ok {
 		includeSubscriptions := parseBoolQueryWithDefault(raw, true)
 		filters.IncludeSubscriptions = &includeSubscriptions
@@ -211,6 +225,9 @@ func (h *UserHandler) Update(c *gin.Context) {
 		response.BadRequest(c, "Invalid request: "+err.Error())
 		return
 	}
+	if !ensureCanManageUser(c, h.adminService, userID) {
+		return
+	}
 
 	// 使用指针类型直接传递，nil 表示未提供该字段
 	user, err := h.adminService.UpdateUser(c.Request.Context(), userID, &service.UpdateUserInput{
@@ -233,6 +250,30 @@ func (h *UserHandler) Update(c *gin.Context) {
 	response.Success(c, dto.UserFromServiceAdmin(user))
 }
 
+// UpdateRole handles changing a user's role (user / viewer / operator)
+// PUT /api/v1/admin/users/:id/role
+func (h *UserHandler) UpdateRole(c *gin.Context) {
+	userID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		response.BadRequest(c, "Invalid user ID")
+		return
+	}
+
+	var req UpdateUserRoleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "Invalid request: "+err.Error())
+		return
+	}
+
+	user, err := h.adminService.UpdateUser(c.Request.Context(), userID, &service.UpdateUserInput{Role: &req.Role})
+	if err != nil {
+		response.ErrorFrom(c, err)
+		return
+	}
+
+	response.Success(c, dto.UserFromServiceAdmin(user))
+}
+
 // Delete handles deleting a user
 // DELETE /api/v1/admin/users/:id
 func (h *UserHandler) Delete(c *gin.Context) {
@@ -242,6 +283,10 @@ func (h *UserHandler) Delete(c *gin.Context) {
 		return
 	}
 
+	if !ensureCanManageUser(c, h.adminService, userID) {
+		return
+	}
+
 	err = h.adminService.DeleteUser(c.Request.Context(), userID)
 	if err != nil {
 		response.ErrorFrom(c, err)
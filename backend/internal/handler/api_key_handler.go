@@ -30,36 +30,87 @@ func NewAPIKeyHandler(apiKeyService *service.APIKeyService) *APIKeyHandler {
 
 // CreateAPIKeyRequest represents the create API key request payload
 type CreateAPIKeyRequest struct {
-	Name          string   `json:"name" binding:"required"`
-	GroupID       *int64   `json:"group_id"`        // nullable
-	CustomKey     *string  `json:"custom_key"`      // 可选的自定义key
-	IPWhitelist   []string `json:"ip_whitelist"`    // IP 白名单
-	IPBlacklist   []string `json:"ip_blacklist"`    // IP 黑名单
-	Quota         *float64 `json:"quota"`           // 配额限制 (USD)
-	ExpiresInDays *int     `json:"expires_in_days"` // 过期天数
+	Name              string   `json:"name" binding:"required"`
+	Description       string   `json:"description" binding:"max=500"`
+	GroupID           *int64   `json:"group_id"`           // nullable
+	CustomKey         *string  `json:"custom_key"`         // 可选的自定义key
+	IPWhitelist       []string `json:"ip_whitelist"`       // IP 白名单
+	IPBlacklist       []string `json:"ip_blacklist"`       // IP 黑名单
+	Scopes            []string `json:"scopes"`             // 可访问的端点类别，空表示不限制
+	AllowedModels     []string `json:"allowed_models"`     // 可使用的模型（支持末尾 * 通配符），空表示不限制
+	ClientRestriction string   `json:"client_restriction"` // 允许的客户端类别（codex/claude_code/coding_agent/api），空表示不限制
+	Quota             *float64 `json:"quota"`              // 配额限制 (USD)
+	ImageQuota        *int     `json:"image_quota"`        // 图片生成数量限制，0=无限制
+	SuppressReasoning *bool    `json:"suppress_reasoning"` // 对非 Codex/Claude Code 客户端隐藏推理内容
+	ExpiresInDays     *int     `json:"expires_in_days"`    // 过期天数
 
 	// Rate limit fields (0 = unlimited)
 	RateLimit5h *float64 `json:"rate_limit_5h"`
 	RateLimit1d *float64 `json:"rate_limit_1d"`
 	RateLimit7d *float64 `json:"rate_limit_7d"`
+
+	// Request / token limits (0 = unlimited)
+	RPMLimit            *int   `json:"rpm_limit" binding:"omitempty,min=0"`
+	TPMLimit            *int   `json:"tpm_limit" binding:"omitempty,min=0"`
+	DailyRequestLimit   *int   `json:"daily_request_limit" binding:"omitempty,min=0"`
+	DailyTokenLimit     *int64 `json:"daily_token_limit" binding:"omitempty,min=0"`
+	MonthlyRequestLimit *int   `json:"monthly_request_limit" binding:"omitempty,min=0"`
+	MonthlyTokenLimit   *int64 `json:"monthly_token_limit" binding:"omitempty,min=0"`
+
+	// Spend budget (0 = no budget)
+	BudgetAmount        *float64 `json:"budget_amount" binding:"omitempty,min=0"`
+	BudgetPeriod        string   `json:"budget_period" binding:"omitempty,oneof=day week month"`
+	BudgetAction        string   `json:"budget_action" binding:"omitempty,oneof=disable downgrade"`
+	BudgetFallbackModel string   `json:"budget_fallback_model" binding:"max=100"`
+
+	// Degraded mode: model served when every account for the requested model is unavailable ('' = return 503)
+	DegradedFallbackModel string `json:"degraded_fallback_model" binding:"max=100"`
 }
 
 // UpdateAPIKeyRequest represents the update API key request payload
 type UpdateAPIKeyRequest struct {
-	Name        string   `json:"name"`
-	GroupID     *int64   `json:"group_id"`
-	Status      string   `json:"status" binding:"omitempty,oneof=active inactive"`
-	IPWhitelist []string `json:"ip_whitelist"` // IP 白名单
-	IPBlacklist []string `json:"ip_blacklist"` // IP 黑名单
-	Quota       *float64 `json:"quota"`        // 配额限制 (USD), 0=无限制
-	ExpiresAt   *string  `json:"expires_at"`   // 过期时间 (ISO 8601)
-	ResetQuota  *bool    `json:"reset_quota"`  // 重置已用配额
+	Name              string   `json:"name"`
+	Description       *string  `json:"description" binding:"omitempty,max=500"`
+	GroupID           *int64   `json:"group_id"`
+	Status            string   `json:"status" binding:"omitempty,oneof=active inactive"`
+	IPWhitelist       []string `json:"ip_whitelist"`       // IP 白名单
+	IPBlacklist       []string `json:"ip_blacklist"`       // IP 黑名单
+	Scopes            []string `json:"scopes"`             // 可访问的端点类别（不传不修改，空数组清空）
+	AllowedModels     []string `json:"allowed_models"`     // 可使用的模型（不传不修改，空数组清空）
+	ClientRestriction *string  `json:"client_restriction"` // 允许的客户端类别（不传不修改，空字符串清除）
+	Quota             *float64 `json:"quota"`              // 配额限制 (USD), 0=无限制
+	ExpiresAt         *string  `json:"expires_at"`         // 过期时间 (ISO 8601)
+	ResetQuota        *bool    `json:"reset_quota"`        // 重置已用配额
+
+	// Image quota fields (nil = no change, 0 = unlimited)
+	ImageQuota      *int  `json:"image_quota"`
+	ResetImageQuota *bool `json:"reset_image_quota"` // 重置已生成图片计数
+
+	SuppressReasoning *bool `json:"suppress_reasoning"` // 对非 Codex/Claude Code 客户端隐藏推理内容
 
 	// Rate limit fields (nil = no change, 0 = unlimited)
 	RateLimit5h         *float64 `json:"rate_limit_5h"`
 	RateLimit1d         *float64 `json:"rate_limit_1d"`
 	RateLimit7d         *float64 `json:"rate_limit_7d"`
 	ResetRateLimitUsage *bool    `json:"reset_rate_limit_usage"` // 重置限速用量
+
+	// Request / token limits (nil = no change, 0 = unlimited)
+	RPMLimit            *int   `json:"rpm_limit" binding:"omitempty,min=0"`
+	TPMLimit            *int   `json:"tpm_limit" binding:"omitempty,min=0"`
+	DailyRequestLimit   *int   `json:"daily_request_limit" binding:"omitempty,min=0"`
+	DailyTokenLimit     *int64 `json:"daily_token_limit" binding:"omitempty,min=0"`
+	MonthlyRequestLimit *int   `json:"monthly_request_limit" binding:"omitempty,min=0"`
+	MonthlyTokenLimit   *int64 `json:"monthly_token_limit" binding:"omitempty,min=0"`
+
+	// Spend budget (nil = no change, 0 = no budget)
+	BudgetAmount        *float64 `json:"budget_amount" binding:"omitempty,min=0"`
+	BudgetPeriod        *string  `json:"budget_period" binding:"omitempty,oneof=day week month"`
+	BudgetAction        *string  `json:"budget_action" binding:"omitempty,oneof=disable downgrade"`
+	BudgetFallbackModel *string  `json:"budget_fallback_model" binding:"omitempty,max=100"`
+	ResetBudgetUsage    *bool    `json:"reset_budget_usage"` // 重置本周期预算花费
+
+	// Degraded mode fallback model (nil = no change, '' = clear)
+	DegradedFallbackModel *string `json:"degraded_fallback_model" binding:"omitempty,max=100"`
 }
 
 // List handles listing user's API keys with pagination
@@ -149,16 +200,26 @@ func (h *APIKeyHandler) Create(c *gin.Context) {
 	}
 
 	svcReq := service.CreateAPIKeyRequest{
-		Name:          req.Name,
-		GroupID:       req.GroupID,
-		CustomKey:     req.CustomKey,
-		IPWhitelist:   req.IPWhitelist,
-		IPBlacklist:   req.IPBlacklist,
-		ExpiresInDays: req.ExpiresInDays,
+		Name:              req.Name,
+		Description:       req.Description,
+		GroupID:           req.GroupID,
+		CustomKey:         req.CustomKey,
+		IPWhitelist:       req.IPWhitelist,
+		IPBlacklist:       req.IPBlacklist,
+		Scopes:            req.Scopes,
+		AllowedModels:     req.AllowedModels,
+		ClientRestriction: req.ClientRestriction,
+		ExpiresInDays:     req.ExpiresInDays,
 	}
 	if req.Quota != nil {
 		svcReq.Quota = *req.Quota
 	}
+	if req.ImageQuota != nil {
+		svcReq.ImageQuota = *req.ImageQuota
+	}
+	if req.SuppressReasoning != nil {
+		svcReq.SuppressReasoning = *req.SuppressReasoning
+	}
 	if req.RateLimit5h != nil {
 		svcReq.RateLimit5h = *req.RateLimit5h
 	}
@@ -168,6 +229,31 @@ func (h *APIKeyHandler) Create(c *gin.Context) {
 	if req.RateLimit7d != nil {
 		svcReq.RateLimit7d = *req.RateLimit7d
 	}
+	if req.RPMLimit != nil {
+		svcReq.RPMLimit = *req.RPMLimit
+	}
+	if req.TPMLimit != nil {
+		svcReq.TPMLimit = *req.TPMLimit
+	}
+	if req.DailyRequestLimit != nil {
+		svcReq.DailyRequestLimit = *req.DailyRequestLimit
+	}
+	if req.DailyTokenLimit != nil {
+		svcReq.DailyTokenLimit = *req.DailyTokenLimit
+	}
+	if req.MonthlyRequestLimit != nil {
+		svcReq.MonthlyRequestLimit = *req.MonthlyRequestLimit
+	}
+	if req.MonthlyTokenLimit != nil {
+		svcReq.MonthlyTokenLimit = *req.MonthlyTokenLimit
+	}
+	if req.BudgetAmount != nil {
+		svcReq.BudgetAmount = *req.BudgetAmount
+	}
+	svcReq.BudgetPeriod = req.BudgetPeriod
+	svcReq.BudgetAction = req.BudgetAction
+	svcReq.BudgetFallbackModel = req.BudgetFallbackModel
+	svcReq.DegradedFallbackModel = req.DegradedFallbackModel
 
 	executeUserIdempotentJSON(c, "user.api_keys.create", req, service.DefaultWriteIdempotencyTTL(), func(ctx context.Context) (any, error) {
 		key, err := h.apiKeyService.Create(ctx, subject.UserID, svcReq)
@@ -200,14 +286,34 @@ func (h *APIKeyHandler) Update(c *gin.Context) {
 	}
 
 	svcReq := service.UpdateAPIKeyRequest{
+		Description:         req.Description,
 		IPWhitelist:         req.IPWhitelist,
 		IPBlacklist:         req.IPBlacklist,
+		Scopes:              req.Scopes,
+		AllowedModels:       req.AllowedModels,
+		ClientRestriction:   req.ClientRestriction,
 		Quota:               req.Quota,
 		ResetQuota:          req.ResetQuota,
+		ImageQuota:          req.ImageQuota,
+		ResetImageQuota:     req.ResetImageQuota,
+		SuppressReasoning:   req.SuppressReasoning,
 		RateLimit5h:         req.RateLimit5h,
 		RateLimit1d:         req.RateLimit1d,
 		RateLimit7d:         req.RateLimit7d,
 		ResetRateLimitUsage: req.ResetRateLimitUsage,
+		RPMLimit:            req.RPMLimit,
+		TPMLimit:            req.TPMLimit,
+		DailyRequestLimit:   req.DailyRequestLimit,
+		DailyTokenLimit:     req.DailyTokenLimit,
+		MonthlyRequestLimit: req.MonthlyRequestLimit,
+		MonthlyTokenLimit:   req.MonthlyTokenLimit,
+		BudgetAmount:        req.BudgetAmount,
+		BudgetPeriod:        req.BudgetPeriod,
+		BudgetAction:        req.BudgetAction,
+		BudgetFallbackModel: req.BudgetFallbackModel,
+		ResetBudgetUsage:    req.ResetBudgetUsage,
+
+		DegradedFallbackModel: req.DegradedFallbackModel,
 	}
 	if req.Name != "" {
 		svcReq.Name = &req.Name
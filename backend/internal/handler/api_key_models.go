@@ -0,0 +1,83 @@
+package handler
+
+import (
+	"fmt"
+
+	"github.com/ShaohongDong/sub2api/internal/pkg/gemini"
+	"github.com/ShaohongDong/sub2api/internal/pkg/openai"
+	"github.com/ShaohongDong/sub2api/internal/service"
+
+	"github.com/gin-gonic/gin"
+	"github.com/tidwall/gjson"
+	"github.com/tidwall/sjson"
+)
+
+// apiKeyModelNotAllowedMessage 请求模型不在 Key 模型白名单内时返回给客户端的错误信息
+func apiKeyModelNotAllowedMessage(model string) string {
+	return fmt.Sprintf("Model %q is not allowed for this API key", model)
+}
+
+// clientRequestedModel 返回客户端请求的模型名：请求经模型别名改写时取改写前的名称，
+// 使 Key 模型白名单与错误信息都以客户端看到的模型名为准
+func clientRequestedModel(c *gin.Context, model string) string {
+	if requested, ok := service.RequestedModelFromContext(c.Request.Context()); ok {
+		return requested
+	}
+	return model
+}
+
+// filterModelsForAPIKey 按 Key 的模型白名单过滤模型列表条目；未配置白名单时原样返回
+func filterModelsForAPIKey[T any](apiKey *service.APIKey, models []T, modelID func(T) string) []T {
+	if apiKey == nil || len(apiKey.AllowedModels) == 0 {
+		return models
+	}
+	out := make([]T, 0, len(models))
+	for _, m := range models {
+		if apiKey.AllowsModel(modelID(m)) {
+			out = append(out, m)
+		}
+	}
+	return out
+}
+
+// filterGeminiModelsBody 按 Key 的模型白名单过滤上游 Gemini 模型列表响应（{"models":[{"name":"models/..."}]}），
+// 保留其余字段（如 nextPageToken）；响应无法解析时原样返回
+func filterGeminiModelsBody(apiKey *service.APIKey, body []byte) []byte {
+	if apiKey == nil || len(apiKey.AllowedModels) == 0 {
+		return body
+	}
+	models := gjson.GetBytes(body, "models")
+	if !models.IsArray() {
+		return body
+	}
+	raw := make([]byte, 0, len(models.Raw))
+	raw = append(raw, '[')
+	first := true
+	models.ForEach(func(_, m gjson.Result) bool {
+		if !apiKey.AllowsModel(m.Get("name").String()) {
+			return true
+		}
+		if !first {
+			raw = append(raw, ',')
+		}
+		raw = append(raw, m.Raw...)
+		first = false
+		return true
+	})
+	raw = append(raw, ']')
+	filtered, err := sjson.SetRawBytes(body, "models", raw)
+	if err != nil {
+		return body
+	}
+	return filtered
+}
+
+// openAIModelID 提取 OpenAI 模型条目的 ID，供 filterModelsForAPIKey 使用
+func openAIModelID(m openai.Model) string { return m.ID }
+
+// filterGeminiFallbackModels 返回按 Key 模型白名单过滤后的 Gemini 静态模型列表
+func filterGeminiFallbackModels(apiKey *service.APIKey) gemini.ModelsListResponse {
+	list := gemini.FallbackModelsList()
+	list.Models = filterModelsForAPIKey(apiKey, list.Models, func(m gemini.Model) string { return m.Name })
+	return list
+}
@@ -0,0 +1,46 @@
+package handler
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/ShaohongDong/sub2api/internal/pkg/gemini"
+	"github.com/ShaohongDong/sub2api/internal/pkg/openai"
+	"github.com/ShaohongDong/sub2api/internal/service"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFilterModelsForAPIKey(t *testing.T) {
+	models := []openai.Model{{ID: "gpt-5"}, {ID: "gpt-5-mini"}, {ID: "o3-pro"}}
+
+	require.Len(t, filterModelsForAPIKey(nil, models, openAIModelID), 3)
+	require.Len(t, filterModelsForAPIKey(&service.APIKey{}, models, openAIModelID), 3)
+
+	apiKey := &service.APIKey{AllowedModels: []string{"gpt-5-mini", "o3*"}}
+	filtered := filterModelsForAPIKey(apiKey, models, openAIModelID)
+	require.Equal(t, []openai.Model{{ID: "gpt-5-mini"}, {ID: "o3-pro"}}, filtered)
+}
+
+func TestFilterGeminiModelsBody(t *testing.T) {
+	body := []byte(`{"models":[{"name":"models/gemini-2.5-pro"},{"name":"models/gemini-2.5-flash"}],"nextPageToken":"abc"}`)
+
+	require.Equal(t, body, filterGeminiModelsBody(&service.APIKey{}, body))
+
+	apiKey := &service.APIKey{AllowedModels: []string{"gemini-2.5-flash*"}}
+	var out struct {
+		Models        []gemini.Model `json:"models"`
+		NextPageToken string         `json:"nextPageToken"`
+	}
+	require.NoError(t, json.Unmarshal(filterGeminiModelsBody(apiKey, body), &out))
+	require.Len(t, out.Models, 1)
+	require.Equal(t, "models/gemini-2.5-flash", out.Models[0].Name)
+	require.Equal(t, "abc", out.NextPageToken)
+
+	require.Equal(t, []byte("not json"), filterGeminiModelsBody(apiKey, []byte("not json")))
+}
+
+func TestFilterGeminiFallbackModels(t *testing.T) {
+	list := filterGeminiFallbackModels(&service.APIKey{AllowedModels: []string{"gemini-2.0-flash"}})
+	require.Len(t, list.Models, 1)
+	require.Equal(t, "models/gemini-2.0-flash", list.Models[0].Name)
+}
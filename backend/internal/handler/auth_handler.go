@@ -3,10 +3,12 @@ package handler
 import (
 	"log/slog"
 	"strings"
+	"sync"
 
 	"github.com/ShaohongDong/sub2api/internal/config"
 	"github.com/ShaohongDong/sub2api/internal/handler/dto"
 	"github.com/ShaohongDong/sub2api/internal/pkg/ip"
+	"github.com/ShaohongDong/sub2api/internal/pkg/oidc"
 	"github.com/ShaohongDong/sub2api/internal/pkg/response"
 	middleware2 "github.com/ShaohongDong/sub2api/internal/server/middleware"
 	"github.com/ShaohongDong/sub2api/internal/service"
@@ -23,6 +25,9 @@ type AuthHandler struct {
 	promoService  *service.PromoService
 	redeemService *service.RedeemService
 	totpService   *service.TotpService
+
+	oidcMu       sync.Mutex
+	oidcProvider *oidc.Provider
 }
 
 // NewAuthHandler creates a new AuthHandler
@@ -598,10 +598,18 @@ func readCookieDecoded(c *gin.Context, name string) (string, error) {
 }
 
 func setCookie(c *gin.Context, name string, value string, maxAgeSec int, secure bool) {
+	setCookieAtPath(c, linuxDoOAuthCookiePath, name, value, maxAgeSec, secure)
+}
+
+func clearCookie(c *gin.Context, name string, secure bool) {
+	clearCookieAtPath(c, linuxDoOAuthCookiePath, name, secure)
+}
+
+func setCookieAtPath(c *gin.Context, path string, name string, value string, maxAgeSec int, secure bool) {
 	http.SetCookie(c.Writer, &http.Cookie{
 		Name:     name,
 		Value:    value,
-		Path:     linuxDoOAuthCookiePath,
+		Path:     path,
 		MaxAge:   maxAgeSec,
 		HttpOnly: true,
 		Secure:   secure,
@@ -609,11 +617,11 @@ func setCookie(c *gin.Context, name string, value string, maxAgeSec int, secure
 	})
 }
 
-func clearCookie(c *gin.Context, name string, secure bool) {
+func clearCookieAtPath(c *gin.Context, path string, name string, secure bool) {
 	http.SetCookie(c.Writer, &http.Cookie{
 		Name:     name,
 		Value:    "",
-		Path:     linuxDoOAuthCookiePath,
+		Path:     path,
 		MaxAge:   -1,
 		HttpOnly: true,
 		Secure:   secure,
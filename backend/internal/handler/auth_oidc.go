@@ -0,0 +1,251 @@
+package handler
+
+import (
+	"fmt"
+	"log"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/ShaohongDong/sub2api/internal/config"
+	infraerrors "github.com/ShaohongDong/sub2api/internal/pkg/errors"
+	"github.com/ShaohongDong/sub2api/internal/pkg/oauth"
+	"github.com/ShaohongDong/sub2api/internal/pkg/oidc"
+	"github.com/ShaohongDong/sub2api/internal/pkg/response"
+	"github.com/ShaohongDong/sub2api/internal/service"
+
+	"github.com/gin-gonic/gin"
+	"github.com/tidwall/gjson"
+)
+
+const (
+	oidcCookiePath        = "/api/v1/auth/oauth/oidc"
+	oidcStateCookieName   = "oidc_state"
+	oidcNonceCookieName   = "oidc_nonce"
+	oidcVerifierCookie    = "oidc_verifier"
+	oidcRedirectCookie    = "oidc_redirect"
+	oidcCookieMaxAgeSec   = 10 * 60 // 10 minutes
+	oidcDefaultRedirectTo = "/admin/dashboard"
+	oidcDefaultFrontendCB = "/auth/oidc/callback"
+)
+
+// OIDCStart 启动管理后台 OIDC 单点登录流程。
+// GET /api/v1/auth/oauth/oidc/start?redirect=/admin/dashboard
+func (h *AuthHandler) OIDCStart(c *gin.Context) {
+	cfg, provider, err := h.getOIDCProvider()
+	if err != nil {
+		response.ErrorFrom(c, err)
+		return
+	}
+
+	state, err := oauth.GenerateState()
+	if err != nil {
+		response.ErrorFrom(c, infraerrors.InternalServer("OAUTH_STATE_GEN_FAILED", "failed to generate oauth state").WithCause(err))
+		return
+	}
+	nonce, err := oauth.GenerateState()
+	if err != nil {
+		response.ErrorFrom(c, infraerrors.InternalServer("OAUTH_STATE_GEN_FAILED", "failed to generate oidc nonce").WithCause(err))
+		return
+	}
+
+	redirectTo := sanitizeFrontendRedirectPath(c.Query("redirect"))
+	if redirectTo == "" {
+		redirectTo = oidcDefaultRedirectTo
+	}
+
+	secureCookie := isRequestHTTPS(c)
+	setCookieAtPath(c, oidcCookiePath, oidcStateCookieName, encodeCookieValue(state), oidcCookieMaxAgeSec, secureCookie)
+	setCookieAtPath(c, oidcCookiePath, oidcNonceCookieName, encodeCookieValue(nonce), oidcCookieMaxAgeSec, secureCookie)
+	setCookieAtPath(c, oidcCookiePath, oidcRedirectCookie, encodeCookieValue(redirectTo), oidcCookieMaxAgeSec, secureCookie)
+
+	codeChallenge := ""
+	if cfg.UsePKCE {
+		verifier, err := oauth.GenerateCodeVerifier()
+		if err != nil {
+			response.ErrorFrom(c, infraerrors.InternalServer("OAUTH_PKCE_GEN_FAILED", "failed to generate pkce verifier").WithCause(err))
+			return
+		}
+		codeChallenge = oauth.GenerateCodeChallenge(verifier)
+		setCookieAtPath(c, oidcCookiePath, oidcVerifierCookie, encodeCookieValue(verifier), oidcCookieMaxAgeSec, secureCookie)
+	}
+
+	authURL, err := provider.AuthCodeURL(c.Request.Context(), cfg.ClientID, cfg.RedirectURL, cfg.Scopes, state, nonce, codeChallenge)
+	if err != nil {
+		log.Printf("[OIDC] build authorization url failed: %v", err)
+		response.ErrorFrom(c, infraerrors.ServiceUnavailable("OIDC_PROVIDER_UNAVAILABLE", "identity provider unavailable").WithCause(err))
+		return
+	}
+
+	c.Redirect(http.StatusFound, authURL)
+}
+
+// OIDCCallback 处理 OIDC 回调：校验 ID Token、映射角色、登录/创建管理账号，然后重定向到前端。
+// GET /api/v1/auth/oauth/oidc/callback?code=...&state=...
+func (h *AuthHandler) OIDCCallback(c *gin.Context) {
+	cfg, provider, cfgErr := h.getOIDCProvider()
+	if cfgErr != nil {
+		response.ErrorFrom(c, cfgErr)
+		return
+	}
+
+	frontendCallback := strings.TrimSpace(cfg.FrontendRedirectURL)
+	if frontendCallback == "" {
+		frontendCallback = oidcDefaultFrontendCB
+	}
+
+	if providerErr := strings.TrimSpace(c.Query("error")); providerErr != "" {
+		redirectOAuthError(c, frontendCallback, "provider_error", providerErr, c.Query("error_description"))
+		return
+	}
+
+	code := strings.TrimSpace(c.Query("code"))
+	state := strings.TrimSpace(c.Query("state"))
+	if code == "" || state == "" {
+		redirectOAuthError(c, frontendCallback, "missing_params", "missing code/state", "")
+		return
+	}
+
+	secureCookie := isRequestHTTPS(c)
+	defer func() {
+		clearCookieAtPath(c, oidcCookiePath, oidcStateCookieName, secureCookie)
+		clearCookieAtPath(c, oidcCookiePath, oidcNonceCookieName, secureCookie)
+		clearCookieAtPath(c, oidcCookiePath, oidcVerifierCookie, secureCookie)
+		clearCookieAtPath(c, oidcCookiePath, oidcRedirectCookie, secureCookie)
+	}()
+
+	expectedState, err := readCookieDecoded(c, oidcStateCookieName)
+	if err != nil || expectedState == "" || state != expectedState {
+		redirectOAuthError(c, frontendCallback, "invalid_state", "invalid oauth state", "")
+		return
+	}
+	nonce, _ := readCookieDecoded(c, oidcNonceCookieName)
+	if nonce == "" {
+		redirectOAuthError(c, frontendCallback, "invalid_state", "missing oidc nonce", "")
+		return
+	}
+
+	redirectTo, _ := readCookieDecoded(c, oidcRedirectCookie)
+	redirectTo = sanitizeFrontendRedirectPath(redirectTo)
+	if redirectTo == "" {
+		redirectTo = oidcDefaultRedirectTo
+	}
+
+	codeVerifier := ""
+	if cfg.UsePKCE {
+		codeVerifier, _ = readCookieDecoded(c, oidcVerifierCookie)
+		if codeVerifier == "" {
+			redirectOAuthError(c, frontendCallback, "missing_verifier", "missing pkce verifier", "")
+			return
+		}
+	}
+
+	ctx := c.Request.Context()
+	tokenResp, err := provider.Exchange(ctx, oidc.ExchangeParams{
+		ClientID:     cfg.ClientID,
+		ClientSecret: cfg.ClientSecret,
+		AuthMethod:   cfg.TokenAuthMethod,
+		Code:         code,
+		RedirectURI:  cfg.RedirectURL,
+		CodeVerifier: codeVerifier,
+	})
+	if err != nil {
+		log.Printf("[OIDC] token exchange failed: %v", err)
+		redirectOAuthError(c, frontendCallback, "token_exchange_failed", "failed to exchange oauth code", singleLine(err.Error()))
+		return
+	}
+
+	idToken, err := provider.VerifyIDToken(ctx, tokenResp.IDToken, cfg.ClientID, nonce)
+	if err != nil {
+		log.Printf("[OIDC] id_token verification failed: %v", err)
+		redirectOAuthError(c, frontendCallback, "invalid_id_token", "failed to verify id token", "")
+		return
+	}
+
+	groups := oidc.ClaimStrings(idToken.Claims, cfg.GroupsClaim)
+	email, emailVerified := idToken.Email, idToken.EmailVerified
+	if (len(groups) == 0 || email == "") && tokenResp.AccessToken != "" {
+		// 部分提供方（如 Authentik 默认配置）只在 userinfo 中返回 groups / email
+		if info, err := provider.UserInfo(ctx, tokenResp.AccessToken); err != nil {
+			log.Printf("[OIDC] userinfo fetch failed: %v", err)
+		} else if gjson.GetBytes(info, "sub").String() == idToken.Subject {
+			if len(groups) == 0 {
+				groups = oidc.ClaimStrings(info, cfg.GroupsClaim)
+			}
+			if email == "" {
+				email = strings.TrimSpace(gjson.GetBytes(info, "email").String())
+				emailVerified = oidc.ClaimBool(gjson.GetBytes(info, "email_verified"))
+			}
+		}
+	}
+
+	if email == "" {
+		redirectOAuthError(c, frontendCallback, "email_missing", "identity provider did not return an email", "")
+		return
+	}
+	if !emailVerified && !cfg.AllowUnverifiedEmail {
+		redirectOAuthError(c, frontendCallback, "email_not_verified", "email is not verified by the identity provider", "")
+		return
+	}
+	if !oidcEmailDomainAllowed(email, cfg.AllowedEmailDomains) {
+		redirectOAuthError(c, frontendCallback, "email_domain_not_allowed", "email domain is not allowed", "")
+		return
+	}
+
+	role := service.ResolveOIDCRole(groups, cfg.RoleMappings, cfg.DefaultRole)
+	if role == "" {
+		log.Printf("[OIDC] no role mapped: sub=%s groups=%v", idToken.Subject, groups)
+		redirectOAuthError(c, frontendCallback, "role_not_granted", infraerrors.Message(service.ErrSSORoleNotGranted), "")
+		return
+	}
+
+	username := firstNonEmpty(idToken.PreferredUsername, idToken.Name)
+	tokenPair, _, err := h.authService.LoginOrProvisionSSOUser(ctx, email, username, role)
+	if err != nil {
+		redirectOAuthError(c, frontendCallback, "login_failed", infraerrors.Reason(err), infraerrors.Message(err))
+		return
+	}
+
+	fragment := url.Values{}
+	fragment.Set("access_token", tokenPair.AccessToken)
+	fragment.Set("refresh_token", tokenPair.RefreshToken)
+	fragment.Set("expires_in", fmt.Sprintf("%d", tokenPair.ExpiresIn))
+	fragment.Set("token_type", "Bearer")
+	fragment.Set("redirect", redirectTo)
+	redirectWithFragment(c, frontendCallback, fragment)
+}
+
+// getOIDCProvider 返回 OIDC 配置及（惰性创建的）提供方客户端，发现文档与 JWKS 在其内部缓存。
+func (h *AuthHandler) getOIDCProvider() (config.OIDCConfig, *oidc.Provider, error) {
+	if h == nil || h.cfg == nil {
+		return config.OIDCConfig{}, nil, infraerrors.ServiceUnavailable("CONFIG_NOT_READY", "config not loaded")
+	}
+	cfg := h.cfg.OIDC
+	if !cfg.Enabled {
+		return config.OIDCConfig{}, nil, infraerrors.NotFound("OIDC_DISABLED", "oidc login is disabled")
+	}
+
+	h.oidcMu.Lock()
+	defer h.oidcMu.Unlock()
+	if h.oidcProvider == nil {
+		h.oidcProvider = oidc.NewProvider(cfg.IssuerURL, nil)
+	}
+	return cfg, h.oidcProvider, nil
+}
+
+func oidcEmailDomainAllowed(email string, allowed []string) bool {
+	if len(allowed) == 0 {
+		return true
+	}
+	at := strings.LastIndex(email, "@")
+	if at < 0 {
+		return false
+	}
+	domain := strings.ToLower(email[at+1:])
+	for _, d := range allowed {
+		if strings.EqualFold(strings.TrimPrefix(strings.TrimSpace(d), "@"), domain) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,206 @@
+package handler
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/ShaohongDong/sub2api/internal/config"
+	"github.com/gin-gonic/gin"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/require"
+)
+
+func TestOIDCEmailDomainAllowed(t *testing.T) {
+	require.True(t, oidcEmailDomainAllowed("a@example.com", nil))
+	require.True(t, oidcEmailDomainAllowed("a@Example.COM", []string{"example.com"}))
+	require.True(t, oidcEmailDomainAllowed("a@example.com", []string{"@example.com"}))
+	require.False(t, oidcEmailDomainAllowed("a@evil-example.com", []string{"example.com"}))
+	require.False(t, oidcEmailDomainAllowed("no-at-sign", []string{"example.com"}))
+}
+
+type oidcTestIssuer struct {
+	*httptest.Server
+	// nonce 由测试在 start 之后回填，token 端点把它签进 ID Token
+	nonce string
+}
+
+// newOIDCTestIssuer 启动一个最小 IdP：discovery、JWKS 与返回指定声明的 token 端点。
+func newOIDCTestIssuer(t *testing.T, claims func(issuer string) jwt.MapClaims) *oidcTestIssuer {
+	t.Helper()
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+
+	mux := http.NewServeMux()
+	srv := &oidcTestIssuer{Server: httptest.NewServer(mux)}
+	t.Cleanup(srv.Close)
+
+	mux.HandleFunc("/.well-known/openid-configuration", func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewEncoder(w).Encode(map[string]string{
+			"issuer":                 srv.URL,
+			"authorization_endpoint": srv.URL + "/authorize",
+			"token_endpoint":         srv.URL + "/token",
+			"jwks_uri":               srv.URL + "/jwks",
+		})
+	})
+	mux.HandleFunc("/jwks", func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewEncoder(w).Encode(map[string]any{"keys": []map[string]string{{
+			"kty": "RSA",
+			"kid": "k1",
+			"n":   base64.RawURLEncoding.EncodeToString(key.PublicKey.N.Bytes()),
+			"e":   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(key.PublicKey.E)).Bytes()),
+		}}})
+	})
+	mux.HandleFunc("/token", func(w http.ResponseWriter, r *http.Request) {
+		c := claims(srv.URL)
+		c["nonce"] = srv.nonce
+		tok := jwt.NewWithClaims(jwt.SigningMethodRS256, c)
+		tok.Header["kid"] = "k1"
+		raw, err := tok.SignedString(key)
+		require.NoError(t, err)
+		_ = json.NewEncoder(w).Encode(map[string]string{"id_token": raw, "token_type": "Bearer"})
+	})
+	return srv
+}
+
+func runOIDCFlow(t *testing.T, issuer *oidcTestIssuer, mutate func(*config.OIDCConfig)) url.Values {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+
+	cfg := &config.Config{OIDC: config.OIDCConfig{
+		Enabled:             true,
+		IssuerURL:           issuer.URL,
+		ClientID:            "sub2api",
+		ClientSecret:        "secret",
+		Scopes:              "openid email",
+		RedirectURL:         "https://gw.example.com/api/v1/auth/oauth/oidc/callback",
+		FrontendRedirectURL: "/auth/oidc/callback",
+		UsePKCE:             true,
+		GroupsClaim:         "groups",
+		RoleMappings:        []config.OIDCRoleMapping{{Group: "gateway-ops", Role: "operator"}},
+	}}
+	if mutate != nil {
+		mutate(&cfg.OIDC)
+	}
+	h := &AuthHandler{cfg: cfg}
+
+	router := gin.New()
+	router.GET("/api/v1/auth/oauth/oidc/start", h.OIDCStart)
+	router.GET("/api/v1/auth/oauth/oidc/callback", h.OIDCCallback)
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/v1/auth/oauth/oidc/start", nil))
+	require.Equal(t, http.StatusFound, w.Code)
+	authURL, err := url.Parse(w.Header().Get("Location"))
+	require.NoError(t, err)
+	require.Equal(t, "S256", authURL.Query().Get("code_challenge_method"))
+	issuer.nonce = authURL.Query().Get("nonce")
+
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/auth/oauth/oidc/callback?code=c&state="+url.QueryEscape(authURL.Query().Get("state")), nil)
+	for _, ck := range w.Result().Cookies() {
+		req.AddCookie(ck)
+	}
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	require.Equal(t, http.StatusFound, w.Code)
+
+	loc, err := url.Parse(w.Header().Get("Location"))
+	require.NoError(t, err)
+	require.Equal(t, "/auth/oidc/callback", loc.Path)
+	fragment, err := url.ParseQuery(loc.Fragment)
+	require.NoError(t, err)
+	return fragment
+}
+
+func oidcTestClaims(email string, verified bool, groups ...string) func(issuer string) jwt.MapClaims {
+	return func(issuer string) jwt.MapClaims {
+		return jwt.MapClaims{
+			"iss":            issuer,
+			"sub":            "u1",
+			"aud":            "sub2api",
+			"exp":            time.Now().Add(time.Hour).Unix(),
+			"email":          email,
+			"email_verified": verified,
+			"groups":         groups,
+		}
+	}
+}
+
+func TestOIDCCallbackRejectsUnmappedGroups(t *testing.T) {
+	srv := newOIDCTestIssuer(t, oidcTestClaims("ops@example.com", true, "gateway-viewers"))
+	fragment := runOIDCFlow(t, srv, nil)
+	require.Equal(t, "role_not_granted", fragment.Get("error"))
+	require.Empty(t, fragment.Get("access_token"))
+}
+
+func TestOIDCCallbackEmailChecks(t *testing.T) {
+	srv := newOIDCTestIssuer(t, oidcTestClaims("ops@example.com", false, "gateway-ops"))
+	require.Equal(t, "email_not_verified", runOIDCFlow(t, srv, nil).Get("error"))
+
+	srv = newOIDCTestIssuer(t, oidcTestClaims("ops@other.com", true, "gateway-ops"))
+	fragment := runOIDCFlow(t, srv, func(c *config.OIDCConfig) { c.AllowedEmailDomains = []string{"example.com"} })
+	require.Equal(t, "email_domain_not_allowed", fragment.Get("error"))
+}
+
+func TestOIDCCallbackRejectsNonceMismatch(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	srv := newOIDCTestIssuer(t, oidcTestClaims("ops@example.com", true, "gateway-ops"))
+	srv.nonce = "stale"
+
+	h := &AuthHandler{cfg: &config.Config{OIDC: config.OIDCConfig{
+		Enabled:             true,
+		IssuerURL:           srv.URL,
+		ClientID:            "sub2api",
+		ClientSecret:        "secret",
+		RedirectURL:         "https://gw.example.com/api/v1/auth/oauth/oidc/callback",
+		FrontendRedirectURL: "/auth/oidc/callback",
+	}}}
+	router := gin.New()
+	router.GET("/cb", h.OIDCCallback)
+
+	req := httptest.NewRequest(http.MethodGet, "/cb?code=c&state=s1", nil)
+	req.AddCookie(&http.Cookie{Name: oidcStateCookieName, Value: encodeCookieValue("s1")})
+	req.AddCookie(&http.Cookie{Name: oidcNonceCookieName, Value: encodeCookieValue("fresh")})
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	loc, err := url.Parse(w.Header().Get("Location"))
+	require.NoError(t, err)
+	fragment, err := url.ParseQuery(loc.Fragment)
+	require.NoError(t, err)
+	require.Equal(t, "invalid_id_token", fragment.Get("error"))
+}
+
+func TestOIDCCallbackRejectsInvalidState(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	h := &AuthHandler{cfg: &config.Config{OIDC: config.OIDCConfig{Enabled: true, IssuerURL: "https://idp.example.com"}}}
+	router := gin.New()
+	router.GET("/cb", h.OIDCCallback)
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/cb?code=c&state=forged", nil))
+	require.Equal(t, http.StatusFound, w.Code)
+	loc, err := url.Parse(w.Header().Get("Location"))
+	require.NoError(t, err)
+	fragment, err := url.ParseQuery(loc.Fragment)
+	require.NoError(t, err)
+	require.Equal(t, "invalid_state", fragment.Get("error"))
+}
+
+func TestOIDCStartDisabled(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	h := &AuthHandler{cfg: &config.Config{}}
+	router := gin.New()
+	router.GET("/start", h.OIDCStart)
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/start", nil))
+	require.Equal(t, http.StatusNotFound, w.Code)
+}
@@ -0,0 +1,88 @@
+package handler
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/ShaohongDong/sub2api/internal/config"
+	pkghttputil "github.com/ShaohongDong/sub2api/internal/pkg/httputil"
+	"github.com/gin-gonic/gin"
+	"github.com/tidwall/gjson"
+	"github.com/tidwall/sjson"
+)
+
+// AzureDeploymentMiddleware 适配 Azure OpenAI 风格路由 /openai/deployments/{deployment}/...：
+//   - deployment 经 gateway.azure_deployment_models 映射为模型名（未配置时按原名使用），写入请求体 model 字段
+//   - 入站端点归一化为对应的 /v1/... 路径，便于用量统计与上游端点推导
+//
+// api-version 查询参数仅为兼容而接受，不参与路由。
+func AzureDeploymentMiddleware(cfg *config.Config) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		deployment := strings.TrimSpace(c.Param("deployment"))
+		if deployment == "" {
+			abortAzureError(c, http.StatusNotFound, "DeploymentNotFound", "Deployment name is required")
+			return
+		}
+		c.Set(inboundEndpointContextKey, azureInboundEndpoint(c))
+
+		body, err := pkghttputil.ReadRequestBodyWithPrealloc(c.Request)
+		if err != nil {
+			if maxErr, ok := extractMaxBytesError(err); ok {
+				abortAzureError(c, http.StatusRequestEntityTooLarge, "invalid_request_error", buildBodyTooLargeMessage(maxErr.Limit))
+				return
+			}
+			abortAzureError(c, http.StatusBadRequest, "invalid_request_error", "Failed to read request body")
+			return
+		}
+		if len(body) > 0 && gjson.ValidBytes(body) {
+			body, err = sjson.SetBytes(body, "model", resolveAzureDeploymentModel(cfg, deployment))
+			if err != nil {
+				abortAzureError(c, http.StatusBadRequest, "invalid_request_error", "Failed to parse request body")
+				return
+			}
+		}
+		c.Request.Body = io.NopCloser(bytes.NewReader(body))
+		c.Request.ContentLength = int64(len(body))
+		c.Next()
+	}
+}
+
+// resolveAzureDeploymentModel 按配置将 Azure deployment 名映射为模型名（大小写不敏感）。
+func resolveAzureDeploymentModel(cfg *config.Config, deployment string) string {
+	if cfg != nil {
+		for name, model := range cfg.Gateway.AzureDeploymentModels {
+			if strings.EqualFold(name, deployment) && strings.TrimSpace(model) != "" {
+				return strings.TrimSpace(model)
+			}
+		}
+	}
+	return deployment
+}
+
+// azureInboundEndpoint 将 /openai/deployments/{deployment}/chat/completions 归一化为 /v1/chat/completions。
+func azureInboundEndpoint(c *gin.Context) string {
+	fullPath := c.FullPath()
+	if fullPath == "" {
+		fullPath = c.Request.URL.Path
+	}
+	if idx := strings.Index(fullPath, "/deployments/"); idx >= 0 {
+		rest := fullPath[idx+len("/deployments/"):]
+		if slash := strings.Index(rest, "/"); slash >= 0 {
+			return "/v1" + rest[slash:]
+		}
+	}
+	return fullPath
+}
+
+// abortAzureError 以 OpenAI/Azure 错误格式终止请求。
+func abortAzureError(c *gin.Context, status int, code, message string) {
+	c.AbortWithStatusJSON(status, gin.H{
+		"error": gin.H{
+			"code":    code,
+			"type":    "invalid_request_error",
+			"message": message,
+		},
+	})
+}
@@ -0,0 +1,53 @@
+package handler
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/ShaohongDong/sub2api/internal/config"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/require"
+	"github.com/tidwall/gjson"
+)
+
+func TestAzureDeploymentMiddleware(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	cfg := &config.Config{Gateway: config.GatewayConfig{
+		AzureDeploymentModels: map[string]string{"prod-gpt4o": "gpt-5"},
+	}}
+	router := gin.New()
+	var gotBody, gotEndpoint string
+	router.POST("/openai/deployments/:deployment/chat/completions", AzureDeploymentMiddleware(cfg), func(c *gin.Context) {
+		b, _ := io.ReadAll(c.Request.Body)
+		gotBody = string(b)
+		gotEndpoint = GetInboundEndpoint(c)
+		c.Status(http.StatusOK)
+	})
+
+	tests := []struct {
+		name       string
+		deployment string
+		body       string
+		wantModel  string
+	}{
+		{name: "mapped (case-insensitive)", deployment: "Prod-GPT4o", body: `{"messages":[]}`, wantModel: "gpt-5"},
+		{name: "unmapped uses deployment name", deployment: "gpt-5-mini", body: `{"messages":[]}`, wantModel: "gpt-5-mini"},
+		{name: "deployment overrides body model", deployment: "prod-gpt4o", body: `{"model":"gpt-4o","messages":[]}`, wantModel: "gpt-5"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, "/openai/deployments/"+tt.deployment+"/chat/completions?api-version=2024-10-21", strings.NewReader(tt.body))
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			require.Equal(t, http.StatusOK, w.Code)
+			require.Equal(t, tt.wantModel, gjson.Get(gotBody, "model").String())
+			require.True(t, gjson.Get(gotBody, "messages").IsArray())
+			require.Equal(t, "/v1/chat/completions", gotEndpoint)
+		})
+	}
+}
@@ -0,0 +1,174 @@
+package handler
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+
+	pkghttputil "github.com/ShaohongDong/sub2api/internal/pkg/httputil"
+	"github.com/ShaohongDong/sub2api/internal/pkg/ip"
+	middleware2 "github.com/ShaohongDong/sub2api/internal/server/middleware"
+	"github.com/ShaohongDong/sub2api/internal/service"
+
+	"github.com/gin-gonic/gin"
+	"github.com/tidwall/gjson"
+	"github.com/tidwall/sjson"
+)
+
+// BackgroundResponseHandler handles Responses API background mode:
+// POST /v1/responses with background=true, GET/DELETE /v1/responses/:id and
+// POST /v1/responses/:id/cancel.
+type BackgroundResponseHandler struct {
+	service *service.BackgroundResponseService
+}
+
+// NewBackgroundResponseHandler creates a new BackgroundResponseHandler
+func NewBackgroundResponseHandler(svc *service.BackgroundResponseService) *BackgroundResponseHandler {
+	return &BackgroundResponseHandler{service: svc}
+}
+
+// Intercept is a middleware for POST /v1/responses.
+//
+// Requests with background=true are persisted and answered immediately with a
+// queued response object; every other request continues down the chain.
+func (h *BackgroundResponseHandler) Intercept(c *gin.Context) {
+	if h == nil || !h.service.Enabled() {
+		c.Next()
+		return
+	}
+	body, err := pkghttputil.ReadRequestBodyWithPrealloc(c.Request)
+	if err != nil {
+		if maxErr, ok := extractMaxBytesError(err); ok {
+			openAIAPIErrorResponse(c, http.StatusRequestEntityTooLarge, "invalid_request_error", buildBodyTooLargeMessage(maxErr.Limit))
+		} else {
+			openAIAPIErrorResponse(c, http.StatusBadRequest, "invalid_request_error", "Failed to read request body")
+		}
+		c.Abort()
+		return
+	}
+	c.Request.Body = io.NopCloser(bytes.NewReader(body))
+	c.Request.ContentLength = int64(len(body))
+	if !gjson.GetBytes(body, "background").Bool() {
+		c.Next()
+		return
+	}
+	defer c.Abort()
+
+	apiKey, ok := middleware2.GetAPIKeyFromContext(c)
+	if !ok {
+		openAIAPIErrorResponse(c, http.StatusUnauthorized, "authentication_error", "Invalid API key")
+		return
+	}
+	resp, err := h.service.Create(c.Request.Context(), apiKey, body, ip.GetClientIP(c))
+	if err != nil {
+		openAIServiceError(c, err)
+		return
+	}
+	c.Data(http.StatusOK, "application/json", toBackgroundResponseObject(resp))
+}
+
+// Get handles GET /v1/responses/:id
+//
+// Returns a placeholder response object while the request is queued or in
+// progress, and the stored upstream response once it has finished.
+func (h *BackgroundResponseHandler) Get(c *gin.Context) {
+	apiKey, ok := middleware2.GetAPIKeyFromContext(c)
+	if !ok {
+		openAIAPIErrorResponse(c, http.StatusUnauthorized, "authentication_error", "Invalid API key")
+		return
+	}
+	resp, err := h.service.Get(c.Request.Context(), apiKey.ID, c.Param("id"))
+	if err != nil {
+		openAIServiceError(c, err)
+		return
+	}
+	c.Data(http.StatusOK, "application/json", toBackgroundResponseObject(resp))
+}
+
+// Delete handles DELETE /v1/responses/:id
+func (h *BackgroundResponseHandler) Delete(c *gin.Context) {
+	apiKey, ok := middleware2.GetAPIKeyFromContext(c)
+	if !ok {
+		openAIAPIErrorResponse(c, http.StatusUnauthorized, "authentication_error", "Invalid API key")
+		return
+	}
+	id := c.Param("id")
+	if err := h.service.Delete(c.Request.Context(), apiKey.ID, id); err != nil {
+		openAIServiceError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"id": id, "object": "response", "deleted": true})
+}
+
+// Cancel handles POST /v1/responses/:id/cancel
+//
+// The route is served by the /v1/responses/*subpath catch-all, so the id is
+// taken from the subpath when no :id parameter is present.
+func (h *BackgroundResponseHandler) Cancel(c *gin.Context) {
+	apiKey, ok := middleware2.GetAPIKeyFromContext(c)
+	if !ok {
+		openAIAPIErrorResponse(c, http.StatusUnauthorized, "authentication_error", "Invalid API key")
+		return
+	}
+	id := c.Param("id")
+	if id == "" {
+		id, _ = BackgroundResponseCancelID(c.Param("subpath"))
+	}
+	resp, err := h.service.Cancel(c.Request.Context(), apiKey.ID, id)
+	if err != nil {
+		openAIServiceError(c, err)
+		return
+	}
+	c.Data(http.StatusOK, "application/json", toBackgroundResponseObject(resp))
+}
+
+// BackgroundResponseCancelID extracts the response id from a "/{id}/cancel" subpath.
+func BackgroundResponseCancelID(subpath string) (string, bool) {
+	id, ok := strings.CutSuffix(strings.TrimPrefix(subpath, "/"), "/cancel")
+	if !ok || id == "" || strings.Contains(id, "/") {
+		return "", false
+	}
+	return id, true
+}
+
+// toBackgroundResponseObject 渲染 Responses API 的 response 对象：
+// 成功完成时返回上游响应体（id 替换为本地 id），否则返回带状态的占位对象。
+func toBackgroundResponseObject(resp *service.BackgroundResponse) []byte {
+	if resp.Status == service.BackgroundResponseStatusCompleted && gjson.ValidBytes(resp.ResponseBody) && gjson.ParseBytes(resp.ResponseBody).IsObject() {
+		out, err := sjson.SetBytes(resp.ResponseBody, "id", resp.ID)
+		if err == nil {
+			out, err = sjson.SetBytes(out, "background", true)
+		}
+		if err == nil {
+			return out
+		}
+	}
+
+	obj := map[string]any{
+		"id":                 resp.ID,
+		"object":             "response",
+		"created_at":         resp.CreatedAt.Unix(),
+		"status":             resp.Status,
+		"background":         true,
+		"model":              resp.Model,
+		"output":             []any{},
+		"error":              nil,
+		"incomplete_details": nil,
+		"usage":              nil,
+	}
+	if metadata := gjson.GetBytes(resp.RequestBody, "metadata"); metadata.IsObject() {
+		obj["metadata"] = json.RawMessage(metadata.Raw)
+	}
+	if resp.Status == service.BackgroundResponseStatusFailed {
+		code := "server_error"
+		if resp.ResponseStatus > 0 {
+			code = "http_" + strconv.Itoa(resp.ResponseStatus)
+		}
+		obj["error"] = gin.H{"code": code, "message": resp.ErrorMessage}
+	}
+	out, _ := json.Marshal(obj)
+	return out
+}
@@ -0,0 +1,55 @@
+package handler
+
+import (
+	"testing"
+	"time"
+
+	"github.com/ShaohongDong/sub2api/internal/service"
+	"github.com/stretchr/testify/require"
+	"github.com/tidwall/gjson"
+)
+
+func TestBackgroundResponseCancelID(t *testing.T) {
+	id, ok := BackgroundResponseCancelID("/resp_abc/cancel")
+	require.True(t, ok)
+	require.Equal(t, "resp_abc", id)
+
+	for _, subpath := range []string{"/compact", "/cancel", "/resp_abc", "/a/b/cancel"} {
+		_, ok := BackgroundResponseCancelID(subpath)
+		require.False(t, ok, subpath)
+	}
+}
+
+func TestToBackgroundResponseObject(t *testing.T) {
+	createdAt := time.Unix(1700000000, 0)
+
+	queued := toBackgroundResponseObject(&service.BackgroundResponse{
+		ID:          "resp_local",
+		Model:       "gpt-5",
+		Status:      service.BackgroundResponseStatusQueued,
+		RequestBody: []byte(`{"model":"gpt-5","metadata":{"k":"v"}}`),
+		CreatedAt:   createdAt,
+	})
+	require.Equal(t, "queued", gjson.GetBytes(queued, "status").String())
+	require.True(t, gjson.GetBytes(queued, "background").Bool())
+	require.Equal(t, int64(1700000000), gjson.GetBytes(queued, "created_at").Int())
+	require.Equal(t, "v", gjson.GetBytes(queued, "metadata.k").String())
+
+	completed := toBackgroundResponseObject(&service.BackgroundResponse{
+		ID:           "resp_local",
+		Status:       service.BackgroundResponseStatusCompleted,
+		ResponseBody: []byte(`{"id":"resp_upstream","object":"response","status":"completed","output":[]}`),
+	})
+	require.Equal(t, "resp_local", gjson.GetBytes(completed, "id").String())
+	require.Equal(t, "completed", gjson.GetBytes(completed, "status").String())
+	require.True(t, gjson.GetBytes(completed, "background").Bool())
+
+	failed := toBackgroundResponseObject(&service.BackgroundResponse{
+		ID:             "resp_local",
+		Status:         service.BackgroundResponseStatusFailed,
+		ResponseStatus: 400,
+		ErrorMessage:   "invalid model",
+	})
+	require.Equal(t, "http_400", gjson.GetBytes(failed, "error.code").String())
+	require.Equal(t, "invalid model", gjson.GetBytes(failed, "error.message").String())
+}
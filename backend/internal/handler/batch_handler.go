@@ -0,0 +1,346 @@
+package handler
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	infraerrors "github.com/ShaohongDong/sub2api/internal/pkg/errors"
+	"github.com/ShaohongDong/sub2api/internal/pkg/ip"
+	"github.com/ShaohongDong/sub2api/internal/pkg/logger"
+	middleware2 "github.com/ShaohongDong/sub2api/internal/server/middleware"
+	"github.com/ShaohongDong/sub2api/internal/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+// BatchHandler handles the OpenAI-compatible /v1/batches endpoints.
+type BatchHandler struct {
+	batchService    *service.BatchService
+	userFileService *service.UserFileService
+}
+
+// NewBatchHandler creates a new BatchHandler
+func NewBatchHandler(batchService *service.BatchService, userFileService *service.UserFileService) *BatchHandler {
+	return &BatchHandler{batchService: batchService, userFileService: userFileService}
+}
+
+// batchCreateJSONRequest JSON 形式的创建请求：input 为请求行数组（与 JSONL 行结构一致）。
+type batchCreateJSONRequest struct {
+	Endpoint         string            `json:"endpoint"`
+	CompletionWindow string            `json:"completion_window"`
+	Metadata         map[string]string `json:"metadata"`
+	InputFileID      string            `json:"input_file_id"`
+	Input            []json.RawMessage `json:"input"`
+}
+
+type batchRequestCounts struct {
+	Total     int `json:"total"`
+	Completed int `json:"completed"`
+	Failed    int `json:"failed"`
+}
+
+// batchObject 与 OpenAI Batch 对象字段保持一致；文件相关字段以结果下载地址代替。
+type batchObject struct {
+	ID               string             `json:"id"`
+	Object           string             `json:"object"`
+	Endpoint         string             `json:"endpoint"`
+	Errors           any                `json:"errors"`
+	InputFileID      *string            `json:"input_file_id"`
+	CompletionWindow string             `json:"completion_window"`
+	Status           string             `json:"status"`
+	OutputFileID     *string            `json:"output_file_id"`
+	ErrorFileID      *string            `json:"error_file_id"`
+	OutputURL        string             `json:"output_url"`
+	CreatedAt        int64              `json:"created_at"`
+	InProgressAt     *int64             `json:"in_progress_at"`
+	ExpiresAt        int64              `json:"expires_at"`
+	FinalizingAt     *int64             `json:"finalizing_at"`
+	CompletedAt      *int64             `json:"completed_at"`
+	FailedAt         *int64             `json:"failed_at"`
+	ExpiredAt        *int64             `json:"expired_at"`
+	CancellingAt     *int64             `json:"cancelling_at"`
+	CancelledAt      *int64             `json:"cancelled_at"`
+	RequestCounts    batchRequestCounts `json:"request_counts"`
+	Metadata         map[string]string  `json:"metadata"`
+}
+
+// Create handles POST /v1/batches
+//
+// The JSONL input is accepted as a multipart "file" field, as a raw
+// application/jsonl (or text/plain) body, or as a JSON object carrying either
+// an input_file_id (uploaded via /v1/files with purpose "batch") or an "input"
+// array of request lines. endpoint and completion_window may be given as form
+// fields, query parameters or JSON fields.
+func (h *BatchHandler) Create(c *gin.Context) {
+	apiKey, ok := middleware2.GetAPIKeyFromContext(c)
+	if !ok {
+		openAIAPIErrorResponse(c, http.StatusUnauthorized, "authentication_error", "Invalid API key")
+		return
+	}
+
+	in, inputFileID, msg := readBatchCreateInput(c)
+	if msg != "" {
+		openAIAPIErrorResponse(c, http.StatusBadRequest, "invalid_request_error", msg)
+		return
+	}
+	if inputFileID != "" {
+		file, content, err := h.userFileService.GetContent(c.Request.Context(), apiKey.UserID, inputFileID)
+		if err != nil {
+			openAIServiceError(c, err)
+			return
+		}
+		if file.Purpose != service.UserFilePurposeBatch {
+			openAIAPIErrorResponse(c, http.StatusBadRequest, "invalid_request_error", "input_file_id must reference a file uploaded with purpose \"batch\"")
+			return
+		}
+		in.Input = content
+	}
+	if len(bytes.TrimSpace(in.Input)) == 0 {
+		openAIAPIErrorResponse(c, http.StatusBadRequest, "invalid_request_error", "input is empty")
+		return
+	}
+	in.ClientIP = ip.GetClientIP(c)
+
+	job, err := h.batchService.CreateBatch(c.Request.Context(), apiKey, in)
+	if err != nil {
+		openAIServiceError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, toBatchObject(job))
+}
+
+// List handles GET /v1/batches
+func (h *BatchHandler) List(c *gin.Context) {
+	apiKey, ok := middleware2.GetAPIKeyFromContext(c)
+	if !ok {
+		openAIAPIErrorResponse(c, http.StatusUnauthorized, "authentication_error", "Invalid API key")
+		return
+	}
+	limit, _ := strconv.Atoi(c.Query("limit"))
+	after := c.Query("after")
+
+	jobs, err := h.batchService.ListBatches(c.Request.Context(), apiKey.ID, after, limit)
+	if err != nil {
+		openAIServiceError(c, err)
+		return
+	}
+	data := make([]batchObject, 0, len(jobs))
+	for _, job := range jobs {
+		data = append(data, toBatchObject(job))
+	}
+	resp := gin.H{
+		"object":   "list",
+		"data":     data,
+		"first_id": nil,
+		"last_id":  nil,
+		"has_more": limit > 0 && len(jobs) >= limit,
+	}
+	if len(data) > 0 {
+		resp["first_id"] = data[0].ID
+		resp["last_id"] = data[len(data)-1].ID
+	}
+	c.JSON(http.StatusOK, resp)
+}
+
+// Get handles GET /v1/batches/:id
+func (h *BatchHandler) Get(c *gin.Context) {
+	apiKey, ok := middleware2.GetAPIKeyFromContext(c)
+	if !ok {
+		openAIAPIErrorResponse(c, http.StatusUnauthorized, "authentication_error", "Invalid API key")
+		return
+	}
+	job, err := h.batchService.GetBatch(c.Request.Context(), apiKey.ID, c.Param("id"))
+	if err != nil {
+		openAIServiceError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, toBatchObject(job))
+}
+
+// Cancel handles POST /v1/batches/:id/cancel
+func (h *BatchHandler) Cancel(c *gin.Context) {
+	apiKey, ok := middleware2.GetAPIKeyFromContext(c)
+	if !ok {
+		openAIAPIErrorResponse(c, http.StatusUnauthorized, "authentication_error", "Invalid API key")
+		return
+	}
+	job, err := h.batchService.CancelBatch(c.Request.Context(), apiKey.ID, c.Param("id"))
+	if err != nil {
+		openAIServiceError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, toBatchObject(job))
+}
+
+// Output handles GET /v1/batches/:id/output
+//
+// Streams the results of all finished requests as JSONL in input order.
+// Requests that are still pending are omitted, so the output can be polled
+// while the batch is in progress.
+func (h *BatchHandler) Output(c *gin.Context) {
+	apiKey, ok := middleware2.GetAPIKeyFromContext(c)
+	if !ok {
+		openAIAPIErrorResponse(c, http.StatusUnauthorized, "authentication_error", "Invalid API key")
+		return
+	}
+	job, err := h.batchService.GetBatch(c.Request.Context(), apiKey.ID, c.Param("id"))
+	if err != nil {
+		openAIServiceError(c, err)
+		return
+	}
+
+	c.Header("Content-Type", "application/jsonl")
+	c.Header("Content-Disposition", `attachment; filename="`+job.ID+`_output.jsonl"`)
+	c.Status(http.StatusOK)
+	if err := h.batchService.WriteBatchOutput(c.Request.Context(), job, c.Writer); err != nil {
+		logger.LegacyPrintf("handler.batch", "[Batch] write output failed: batch=%s err=%v", job.ID, err)
+	}
+}
+
+func readBatchCreateInput(c *gin.Context) (service.CreateBatchInput, string, string) {
+	in := service.CreateBatchInput{
+		Endpoint:         c.Query("endpoint"),
+		CompletionWindow: c.Query("completion_window"),
+	}
+	contentType := c.ContentType()
+
+	switch {
+	case strings.HasPrefix(contentType, "multipart/form-data"):
+		fileHeader, err := c.FormFile("file")
+		if err != nil {
+			return in, "", "file is required"
+		}
+		f, err := fileHeader.Open()
+		if err != nil {
+			return in, "", "failed to read file"
+		}
+		defer func() { _ = f.Close() }()
+		data, err := io.ReadAll(f)
+		if err != nil {
+			return in, "", "failed to read file"
+		}
+		in.Input = data
+		if v := c.PostForm("endpoint"); v != "" {
+			in.Endpoint = v
+		}
+		if v := c.PostForm("completion_window"); v != "" {
+			in.CompletionWindow = v
+		}
+		if v := c.PostForm("metadata"); v != "" {
+			if err := json.Unmarshal([]byte(v), &in.Metadata); err != nil {
+				return in, "", "metadata must be a JSON object of strings"
+			}
+		}
+	case contentType == "application/json":
+		body, err := io.ReadAll(c.Request.Body)
+		if err != nil {
+			return in, "", "Failed to read request body"
+		}
+		var req batchCreateJSONRequest
+		if err := json.Unmarshal(body, &req); err != nil {
+			return in, "", "Failed to parse request body"
+		}
+		if req.Endpoint != "" {
+			in.Endpoint = req.Endpoint
+		}
+		if req.CompletionWindow != "" {
+			in.CompletionWindow = req.CompletionWindow
+		}
+		in.Metadata = req.Metadata
+		if req.InputFileID != "" {
+			return in, req.InputFileID, ""
+		}
+		var buf bytes.Buffer
+		for _, line := range req.Input {
+			buf.Write(line)
+			buf.WriteByte('\n')
+		}
+		in.Input = buf.Bytes()
+	default:
+		body, err := io.ReadAll(c.Request.Body)
+		if err != nil {
+			return in, "", "Failed to read request body"
+		}
+		in.Input = body
+	}
+	return in, "", ""
+}
+
+func toBatchObject(job *service.BatchJob) batchObject {
+	obj := batchObject{
+		ID:               job.ID,
+		Object:           "batch",
+		Endpoint:         job.Endpoint,
+		CompletionWindow: job.CompletionWindow,
+		Status:           job.Status,
+		OutputURL:        "/v1/batches/" + job.ID + "/output",
+		CreatedAt:        job.CreatedAt.Unix(),
+		InProgressAt:     unixPtr(job.InProgressAt),
+		ExpiresAt:        job.ExpiresAt.Unix(),
+		CancellingAt:     unixPtr(job.CancellingAt),
+		CancelledAt:      unixPtr(job.CancelledAt),
+		ExpiredAt:        unixPtr(job.ExpiredAt),
+		RequestCounts: batchRequestCounts{
+			Total:     job.TotalCount,
+			Completed: job.CompletedCount,
+			Failed:    job.FailedCount,
+		},
+		Metadata: job.Metadata,
+	}
+	if job.Status == service.BatchStatusFailed {
+		obj.FailedAt = unixPtr(job.CompletedAt)
+		obj.Errors = gin.H{
+			"object": "list",
+			"data":   []gin.H{{"code": "batch_failed", "message": job.ErrorMessage}},
+		}
+	} else {
+		obj.CompletedAt = unixPtr(job.CompletedAt)
+	}
+	return obj
+}
+
+func unixPtr(t *time.Time) *int64 {
+	if t == nil {
+		return nil
+	}
+	v := t.Unix()
+	return &v
+}
+
+// batchServiceError 将业务错误映射为 OpenAI 格式错误响应。
+func openAIServiceError(c *gin.Context, err error) {
+	status := infraerrors.Code(err)
+	switch status {
+	case http.StatusBadRequest:
+		openAIAPIErrorResponse(c, status, "invalid_request_error", infraerrors.Message(err))
+	case http.StatusUnauthorized:
+		openAIAPIErrorResponse(c, status, "authentication_error", infraerrors.Message(err))
+	case http.StatusNotFound:
+		openAIAPIErrorResponse(c, status, "not_found_error", infraerrors.Message(err))
+	case http.StatusRequestEntityTooLarge:
+		openAIAPIErrorResponse(c, status, "invalid_request_error", infraerrors.Message(err))
+	case http.StatusConflict:
+		openAIAPIErrorResponse(c, status, "invalid_request_error", infraerrors.Message(err))
+	case http.StatusTooManyRequests:
+		openAIAPIErrorResponse(c, status, "rate_limit_error", infraerrors.Message(err))
+	case http.StatusServiceUnavailable:
+		openAIAPIErrorResponse(c, status, "api_error", infraerrors.Message(err))
+	default:
+		logger.LegacyPrintf("handler.openai_api", "request failed: path=%s err=%v", c.FullPath(), err)
+		openAIAPIErrorResponse(c, http.StatusInternalServerError, "api_error", "Internal server error")
+	}
+}
+
+func openAIAPIErrorResponse(c *gin.Context, status int, errType, message string) {
+	c.JSON(status, gin.H{
+		"error": gin.H{
+			"type":    errType,
+			"message": message,
+		},
+	})
+}
@@ -0,0 +1,184 @@
+package handler
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/ShaohongDong/sub2api/internal/pkg/bedrock"
+	pkghttputil "github.com/ShaohongDong/sub2api/internal/pkg/httputil"
+	"github.com/gin-gonic/gin"
+	"github.com/tidwall/gjson"
+)
+
+// BedrockInvokeMiddleware 适配 AWS Bedrock Runtime 风格路由 /model/{modelId}/invoke 与
+// /model/{modelId}/invoke-with-response-stream（Anthropic on Bedrock 请求体）：
+//   - 请求：modelId 转换为 Anthropic 模型名写入 model，移除 anthropic_version，
+//     请求体中的 anthropic_beta 合并到 anthropic-beta 请求头，stream 由 action 决定
+//   - 响应：流式 SSE 事件转码为 application/vnd.amazon.eventstream 的 chunk 事件；
+//     错误响应改写为 Bedrock 格式（{"message": ...} + x-amzn-ErrorType）
+//
+// 后续处理复用 /v1/messages 的分组路由与转发逻辑。
+func BedrockInvokeMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		modelID, action, ok := bedrock.ParseModelAction(c.Param("modelAction"))
+		if !ok {
+			abortBedrockError(c, http.StatusNotFound, "Unsupported Bedrock operation")
+			return
+		}
+		stream := action == bedrock.ActionInvokeStream
+
+		body, err := pkghttputil.ReadRequestBodyWithPrealloc(c.Request)
+		if err != nil {
+			if maxErr, ok := extractMaxBytesError(err); ok {
+				abortBedrockError(c, http.StatusRequestEntityTooLarge, buildBodyTooLargeMessage(maxErr.Limit))
+				return
+			}
+			abortBedrockError(c, http.StatusBadRequest, "Failed to read request body")
+			return
+		}
+		body, betas, err := bedrock.ToAnthropicRequest(body, bedrock.ToAnthropicModelID(modelID), stream)
+		if err != nil {
+			abortBedrockError(c, http.StatusBadRequest, "Malformed input request: "+err.Error())
+			return
+		}
+		if len(betas) > 0 {
+			if existing := strings.TrimSpace(c.GetHeader("anthropic-beta")); existing != "" {
+				betas = append([]string{existing}, betas...)
+			}
+			c.Request.Header.Set("anthropic-beta", strings.Join(betas, ","))
+		}
+		c.Request.Body = io.NopCloser(bytes.NewReader(body))
+		c.Request.ContentLength = int64(len(body))
+		c.Set(inboundEndpointContextKey, "/v1/messages")
+
+		w := &bedrockResponseWriter{ResponseWriter: c.Writer, stream: stream}
+		w.events = bedrock.NewEventStreamWriter(w.ResponseWriter)
+		c.Writer = w
+		c.Next()
+		w.finish()
+		c.Writer = w.ResponseWriter
+	}
+}
+
+// abortBedrockError 以 Bedrock 错误格式终止请求。
+func abortBedrockError(c *gin.Context, status int, message string) {
+	c.Header("x-amzn-ErrorType", bedrock.ErrorTypeForStatus(status))
+	c.AbortWithStatusJSON(status, gin.H{"message": message})
+}
+
+// bedrockResponseWriter 将 Anthropic 格式响应改写为 Bedrock 格式。
+// 错误响应（>=400）先整体缓存，结束时改写；流式成功响应逐个 SSE 事件转码。
+type bedrockResponseWriter struct {
+	gin.ResponseWriter
+	stream  bool
+	events  *bedrock.EventStreamWriter
+	line    []byte // 未完成的 SSE 行
+	data    []byte // 当前事件的 data
+	errBody bytes.Buffer
+}
+
+func (w *bedrockResponseWriter) WriteHeader(code int) {
+	if code >= http.StatusBadRequest {
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("x-amzn-ErrorType", bedrock.ErrorTypeForStatus(code))
+	} else if w.stream {
+		w.Header().Set("Content-Type", bedrock.EventStreamContentType)
+	}
+	w.ResponseWriter.WriteHeader(code)
+}
+
+func (w *bedrockResponseWriter) Write(b []byte) (int, error) {
+	if w.Status() >= http.StatusBadRequest {
+		return w.errBody.Write(b)
+	}
+	if !w.stream {
+		return w.ResponseWriter.Write(b)
+	}
+	w.ensureStreamHeader()
+	if err := w.transcode(b); err != nil {
+		return 0, err
+	}
+	return len(b), nil
+}
+
+func (w *bedrockResponseWriter) WriteString(s string) (int, error) {
+	return w.Write([]byte(s))
+}
+
+func (w *bedrockResponseWriter) Flush() {
+	if w.Status() >= http.StatusBadRequest {
+		return
+	}
+	if w.stream {
+		w.ensureStreamHeader()
+	}
+	w.ResponseWriter.Flush()
+}
+
+// ensureStreamHeader 覆盖上游处理器设置的 text/event-stream（WriteHeader 可能早于 Content-Type 设置）。
+func (w *bedrockResponseWriter) ensureStreamHeader() {
+	if !w.Written() {
+		w.Header().Set("Content-Type", bedrock.EventStreamContentType)
+	}
+}
+
+// transcode 按行解析 SSE，空行结束一个事件。
+func (w *bedrockResponseWriter) transcode(b []byte) error {
+	w.line = append(w.line, b...)
+	for {
+		idx := bytes.IndexByte(w.line, '\n')
+		if idx < 0 {
+			return nil
+		}
+		line := bytes.TrimRight(w.line[:idx], "\r")
+		w.line = w.line[idx+1:]
+		switch {
+		case len(line) == 0:
+			if err := w.emit(); err != nil {
+				return err
+			}
+		case bytes.HasPrefix(line, []byte("data:")):
+			w.data = append(w.data, bytes.TrimSpace(line[len("data:"):])...)
+		}
+	}
+}
+
+func (w *bedrockResponseWriter) emit() error {
+	data := w.data
+	w.data = nil
+	if len(data) == 0 || string(data) == "[DONE]" {
+		return nil
+	}
+	if gjson.GetBytes(data, "type").String() == "error" {
+		return w.events.WriteException(
+			bedrock.StreamExceptionType(gjson.GetBytes(data, "error.type").String()),
+			gjson.GetBytes(data, "error.message").String(),
+		)
+	}
+	return w.events.WriteChunk(data)
+}
+
+// finish 输出剩余的流式事件，或将缓存的错误响应改写为 {"message": ...}。
+func (w *bedrockResponseWriter) finish() {
+	if w.errBody.Len() > 0 {
+		message := gjson.GetBytes(w.errBody.Bytes(), "error.message").String()
+		if message == "" {
+			message = gjson.GetBytes(w.errBody.Bytes(), "message").String()
+		}
+		if message == "" {
+			message = strings.TrimSpace(w.errBody.String())
+		}
+		body, _ := json.Marshal(gin.H{"message": message})
+		_, _ = w.ResponseWriter.Write(body)
+		return
+	}
+	if w.stream {
+		if len(w.line) > 0 {
+			_ = w.transcode([]byte("\n"))
+		}
+		_ = w.emit()
+	}
+}
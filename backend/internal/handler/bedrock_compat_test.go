@@ -0,0 +1,108 @@
+package handler
+
+import (
+	"bytes"
+	"encoding/base64"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/ShaohongDong/sub2api/internal/pkg/bedrock"
+	"github.com/aws/aws-sdk-go-v2/aws/protocol/eventstream"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/require"
+	"github.com/tidwall/gjson"
+)
+
+func newBedrockTestRouter(next gin.HandlerFunc) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.POST("/model/*modelAction", BedrockInvokeMiddleware(), next)
+	return router
+}
+
+func TestBedrockInvokeMiddleware_Stream(t *testing.T) {
+	var gotBody []byte
+	var gotBeta, gotEndpoint string
+	router := newBedrockTestRouter(func(c *gin.Context) {
+		gotBody, _ = io.ReadAll(c.Request.Body)
+		gotBeta = c.GetHeader("anthropic-beta")
+		gotEndpoint = GetInboundEndpoint(c)
+		c.Header("Content-Type", "text/event-stream")
+		c.Status(http.StatusOK)
+		_, _ = c.Writer.WriteString("event: message_start\ndata: {\"type\":\"message_start\"}\n\n")
+		c.Writer.Flush()
+		// 跨 Write 拆分的事件
+		_, _ = c.Writer.WriteString("event: content_block_delta\ndata: {\"type\":\"content_block_delta\",")
+		_, _ = c.Writer.WriteString("\"delta\":{\"type\":\"text_delta\",\"text\":\"hi\"}}\n\n")
+		_, _ = c.Writer.WriteString("event: error\ndata: {\"type\":\"error\",\"error\":{\"type\":\"overloaded_error\",\"message\":\"Overloaded\"}}\n\n")
+	})
+
+	req := httptest.NewRequest(http.MethodPost, "/model/us.anthropic.claude-sonnet-4-20250514-v1:0/invoke-with-response-stream",
+		strings.NewReader(`{"anthropic_version":"bedrock-2023-05-31","anthropic_beta":["context-1m-2025-08-07"],"max_tokens":16,"messages":[{"role":"user","content":"hi"}]}`))
+	req.Header.Set("anthropic-beta", "oauth-2025-04-20")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	require.Equal(t, http.StatusOK, w.Code)
+	require.Equal(t, bedrock.EventStreamContentType, w.Header().Get("Content-Type"))
+	require.Equal(t, "claude-sonnet-4-20250514", gjson.GetBytes(gotBody, "model").String())
+	require.True(t, gjson.GetBytes(gotBody, "stream").Bool())
+	require.False(t, gjson.GetBytes(gotBody, "anthropic_version").Exists())
+	require.Equal(t, "oauth-2025-04-20,context-1m-2025-08-07", gotBeta)
+	require.Equal(t, "/v1/messages", gotEndpoint)
+
+	dec := eventstream.NewDecoder()
+	body := bytes.NewReader(w.Body.Bytes())
+	var types []string
+	for body.Len() > 0 {
+		msg, err := dec.Decode(body, nil)
+		require.NoError(t, err)
+		if msg.Headers.Get(":message-type").String() == "exception" {
+			types = append(types, "exception:"+msg.Headers.Get(":exception-type").String())
+			continue
+		}
+		raw, err := base64.StdEncoding.DecodeString(gjson.GetBytes(msg.Payload, "bytes").String())
+		require.NoError(t, err)
+		types = append(types, gjson.GetBytes(raw, "type").String())
+	}
+	require.Equal(t, []string{"message_start", "content_block_delta", "exception:serviceUnavailableException"}, types)
+}
+
+func TestBedrockInvokeMiddleware_InvokeAndErrors(t *testing.T) {
+	router := newBedrockTestRouter(func(c *gin.Context) {
+		if gjson.GetBytes(mustReadBody(c), "model").String() == "claude-3-haiku-20240307" {
+			c.JSON(http.StatusOK, gin.H{"type": "message", "content": []any{}})
+			return
+		}
+		c.JSON(http.StatusTooManyRequests, gin.H{"type": "error", "error": gin.H{"type": "rate_limit_error", "message": "Too many requests"}})
+	})
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/model/anthropic.claude-3-haiku-20240307-v1:0/invoke", strings.NewReader(`{"anthropic_version":"bedrock-2023-05-31","max_tokens":8,"messages":[]}`)))
+	require.Equal(t, http.StatusOK, w.Code)
+	require.JSONEq(t, `{"type":"message","content":[]}`, w.Body.String())
+
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/model/anthropic.claude-opus-4-20250514-v1:0/invoke", strings.NewReader(`{"max_tokens":8,"messages":[]}`)))
+	require.Equal(t, http.StatusTooManyRequests, w.Code)
+	require.Equal(t, "ThrottlingException", w.Header().Get("x-amzn-ErrorType"))
+	require.JSONEq(t, `{"message":"Too many requests"}`, w.Body.String())
+
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/model/anthropic.claude-opus-4-20250514-v1:0/converse", strings.NewReader(`{}`)))
+	require.Equal(t, http.StatusNotFound, w.Code)
+	require.Equal(t, "ResourceNotFoundException", w.Header().Get("x-amzn-ErrorType"))
+
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/model/anthropic.claude-opus-4-20250514-v1:0/invoke", strings.NewReader(`not json`)))
+	require.Equal(t, http.StatusBadRequest, w.Code)
+	require.Equal(t, "ValidationException", w.Header().Get("x-amzn-ErrorType"))
+}
+
+func mustReadBody(c *gin.Context) []byte {
+	b, _ := io.ReadAll(c.Request.Body)
+	return b
+}
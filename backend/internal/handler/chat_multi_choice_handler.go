@@ -0,0 +1,84 @@
+package handler
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"net/http"
+
+	"github.com/ShaohongDong/sub2api/internal/pkg/logger"
+	middleware2 "github.com/ShaohongDong/sub2api/internal/server/middleware"
+	"github.com/ShaohongDong/sub2api/internal/service"
+
+	"github.com/gin-gonic/gin"
+	"github.com/tidwall/gjson"
+	"go.uber.org/zap"
+)
+
+// ChatMultiChoiceHandler emulates n > 1 on chat completions. The upstreams
+// behind /v1/chat/completions (Responses API, Anthropic Messages) return a
+// single output, so the request is fanned out as n parallel n=1 requests and
+// the choices are assembled into one response.
+type ChatMultiChoiceHandler struct {
+	service *service.ChatMultiChoiceService
+}
+
+// NewChatMultiChoiceHandler creates a new ChatMultiChoiceHandler
+func NewChatMultiChoiceHandler(svc *service.ChatMultiChoiceService) *ChatMultiChoiceHandler {
+	return &ChatMultiChoiceHandler{service: svc}
+}
+
+// Middleware wraps chat completions endpoints. Requests with n <= 1 pass
+// through untouched; n above gateway.chat_multi_choice.max_n is rejected.
+// Sub-requests are replayed through the gateway router, so each one is
+// scheduled, concurrency-limited and billed like a regular call. Streaming
+// requests receive the merged choices as chat.completion.chunk events once
+// all sub-requests have finished.
+func (h *ChatMultiChoiceHandler) Middleware(c *gin.Context) {
+	if h == nil || !h.service.Enabled() || c.Request.Method != http.MethodPost || c.Request.Body == nil {
+		c.Next()
+		return
+	}
+	body, err := io.ReadAll(c.Request.Body)
+	_ = c.Request.Body.Close()
+	c.Request.Body = io.NopCloser(bytes.NewReader(body))
+	if err != nil {
+		c.Next()
+		return
+	}
+	n := gjson.GetBytes(body, "n")
+	if n.Type != gjson.Number || n.Int() <= 1 {
+		c.Next()
+		return
+	}
+	if n.Int() > int64(h.service.MaxN()) {
+		abortModerationError(c, http.StatusBadRequest, "invalid_value", fmt.Sprintf("n must be at most %d", h.service.MaxN()))
+		return
+	}
+	apiKey, ok := middleware2.GetAPIKeyFromContext(c)
+	if !ok || apiKey == nil {
+		c.Next()
+		return
+	}
+
+	log := logger.FromContext(c.Request.Context())
+	result, err := h.service.Complete(c.Request.Context(), apiKey.Key, c.ClientIP(), body, int(n.Int()))
+	if err != nil {
+		log.Warn("gateway.chat_multi_choice_failed", zap.Int64("n", n.Int()), zap.Error(err))
+		abortModerationError(c, http.StatusBadGateway, "upstream_error", "Upstream request failed")
+		return
+	}
+	if result.StatusCode != http.StatusOK {
+		log.Info("gateway.chat_multi_choice_upstream_error", zap.Int64("n", n.Int()), zap.Int("status", result.StatusCode))
+		c.Data(result.StatusCode, result.Header.Get("Content-Type"), result.Body)
+		c.Abort()
+		return
+	}
+	if gjson.GetBytes(body, "stream").Bool() {
+		c.Header("Cache-Control", "no-cache")
+		c.Data(http.StatusOK, "text/event-stream", service.ChatMultiChoiceSSE(result.Body, gjson.GetBytes(body, "stream_options.include_usage").Bool()))
+	} else {
+		c.Data(http.StatusOK, "application/json", result.Body)
+	}
+	c.Abort()
+}
@@ -0,0 +1,74 @@
+package handler
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/ShaohongDong/sub2api/internal/config"
+	"github.com/ShaohongDong/sub2api/internal/service"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/require"
+	"github.com/tidwall/gjson"
+)
+
+type chatMultiChoiceTestDispatcher struct {
+	apiKey string
+}
+
+func (d *chatMultiChoiceTestDispatcher) Dispatch(_ context.Context, apiKey, _, _, _ string, _ []byte) (*service.BatchDispatchResult, error) {
+	d.apiKey = apiKey
+	return &service.BatchDispatchResult{
+		StatusCode: http.StatusOK,
+		Body:       []byte(`{"id":"chatcmpl-1","object":"chat.completion","model":"gpt-5","choices":[{"index":0,"message":{"role":"assistant","content":"ok"},"finish_reason":"stop"}]}`),
+	}, nil
+}
+
+func TestChatMultiChoiceHandler_Middleware(t *testing.T) {
+	cfg := &config.Config{}
+	cfg.Gateway.ChatMultiChoice = config.GatewayChatMultiChoiceConfig{Enabled: true, MaxN: 3, MaxParallel: 2}
+	svc := service.NewChatMultiChoiceService(cfg)
+	dispatcher := &chatMultiChoiceTestDispatcher{}
+	svc.SetDispatcher(dispatcher)
+	h := NewChatMultiChoiceHandler(svc)
+
+	gin.SetMode(gin.TestMode)
+	passedThrough := false
+	r := gin.New()
+	r.POST("/v1/chat/completions", func(c *gin.Context) {
+		c.Set("api_key", &service.APIKey{ID: 1, Key: "sk-user"})
+		c.Next()
+	}, h.Middleware, func(c *gin.Context) {
+		passedThrough = true
+		c.Status(http.StatusNoContent)
+	})
+	send := func(body string) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		r.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/v1/chat/completions", strings.NewReader(body)))
+		return rec
+	}
+
+	rec := send(`{"model":"gpt-5","n":1,"messages":[]}`)
+	require.Equal(t, http.StatusNoContent, rec.Code)
+	require.True(t, passedThrough)
+
+	passedThrough = false
+	rec = send(`{"model":"gpt-5","n":2,"messages":[]}`)
+	require.Equal(t, http.StatusOK, rec.Code)
+	require.False(t, passedThrough)
+	require.Equal(t, "sk-user", dispatcher.apiKey)
+	require.Len(t, gjson.Get(rec.Body.String(), "choices").Array(), 2)
+	require.Equal(t, int64(1), gjson.Get(rec.Body.String(), "choices.1.index").Int())
+
+	rec = send(`{"model":"gpt-5","n":2,"stream":true,"messages":[]}`)
+	require.Equal(t, http.StatusOK, rec.Code)
+	require.Equal(t, "text/event-stream", rec.Header().Get("Content-Type"))
+	require.True(t, strings.HasSuffix(rec.Body.String(), "data: [DONE]\n\n"))
+
+	rec = send(`{"model":"gpt-5","n":4,"messages":[]}`)
+	require.Equal(t, http.StatusBadRequest, rec.Code)
+	require.Equal(t, "invalid_value", gjson.Get(rec.Body.String(), "error.code").String())
+	require.False(t, passedThrough)
+}
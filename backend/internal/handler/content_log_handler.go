@@ -0,0 +1,130 @@
+package handler
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/ShaohongDong/sub2api/internal/pkg/ctxkey"
+	middleware2 "github.com/ShaohongDong/sub2api/internal/server/middleware"
+	"github.com/ShaohongDong/sub2api/internal/service"
+
+	"github.com/gin-gonic/gin"
+	"github.com/tidwall/gjson"
+)
+
+// ContentLogHandler stores the full prompt and response of requests made
+// with API keys that have log_content enabled, for debugging. These records
+// are separate from the always-on usage and ops logs, which keep metadata only.
+type ContentLogHandler struct {
+	service *service.ContentLogService
+}
+
+// NewContentLogHandler creates a new ContentLogHandler
+func NewContentLogHandler(svc *service.ContentLogService) *ContentLogHandler {
+	return &ContentLogHandler{service: svc}
+}
+
+// Middleware wraps inference endpoints after API key authentication. For
+// opted-in keys it keeps the request body as received from the client and a
+// copy of the response (SSE streams included) up to content_log.max_body_bytes
+// each, and stores them once the handler finishes.
+func (h *ContentLogHandler) Middleware(c *gin.Context) {
+	if h == nil || !h.service.Enabled() || c.Request.Method != http.MethodPost || c.Request.Body == nil {
+		c.Next()
+		return
+	}
+	apiKey, _ := middleware2.GetAPIKeyFromContext(c)
+	if !h.service.ShouldLog(apiKey) {
+		c.Next()
+		return
+	}
+	body, err := io.ReadAll(c.Request.Body)
+	_ = c.Request.Body.Close()
+	c.Request.Body = io.NopCloser(bytes.NewReader(body))
+	if err != nil {
+		c.Next()
+		return
+	}
+
+	limit := h.service.MaxBodyBytes()
+	w := &captureResponseWriter{ResponseWriter: c.Writer, limit: limit}
+	c.Writer = w
+	start := time.Now()
+	c.Next()
+	c.Writer = w.ResponseWriter
+
+	path := c.Request.URL.Path
+	entry := &service.RequestContentLog{
+		RequestID:         c.Writer.Header().Get("X-Request-Id"),
+		Platform:          resolveOpsPlatform(apiKey, guessPlatformFromPath(path)),
+		Model:             strings.TrimSpace(gjson.GetBytes(body, "model").String()),
+		RequestPath:       path,
+		Stream:            gjson.GetBytes(body, "stream").Bool(),
+		StatusCode:        c.Writer.Status(),
+		DurationMs:        int(time.Since(start).Milliseconds()),
+		RequestBody:       contentLogBody(body, limit),
+		RequestBodyBytes:  len(body),
+		ResponseBody:      contentLogBody(w.body.Bytes(), limit),
+		ResponseBodyBytes: w.size,
+		APIKeyID:          &apiKey.ID,
+		UserID:            &apiKey.UserID,
+		GroupID:           apiKey.GroupID,
+	}
+	entry.ClientRequestID, _ = c.Request.Context().Value(ctxkey.ClientRequestID).(string)
+	if model, ok := c.Get(opsModelKey); ok {
+		if s, _ := model.(string); s != "" {
+			entry.Model = s
+		}
+	}
+	if stream, ok := c.Get(opsStreamKey); ok {
+		if b, ok := stream.(bool); ok {
+			entry.Stream = b
+		}
+	}
+	if v, ok := c.Get(opsAccountIDKey); ok {
+		if accountID, ok := v.(int64); ok && accountID > 0 {
+			entry.AccountID = &accountID
+		}
+	}
+	h.service.Record(c.Request.Context(), entry)
+}
+
+// contentLogBody returns at most limit bytes of b as valid UTF-8 text.
+func contentLogBody(b []byte, limit int) string {
+	if len(b) > limit {
+		b = b[:limit]
+	}
+	return strings.ToValidUTF8(string(b), "�")
+}
+
+// captureResponseWriter passes the response through while keeping the first limit
+// bytes and counting the full size.
+type captureResponseWriter struct {
+	gin.ResponseWriter
+	body  bytes.Buffer
+	limit int
+	size  int
+}
+
+func (w *captureResponseWriter) Write(b []byte) (int, error) {
+	w.keep(b)
+	return w.ResponseWriter.Write(b)
+}
+
+func (w *captureResponseWriter) WriteString(s string) (int, error) {
+	w.keep([]byte(s))
+	return w.ResponseWriter.WriteString(s)
+}
+
+func (w *captureResponseWriter) keep(b []byte) {
+	w.size += len(b)
+	if room := w.limit - w.body.Len(); room > 0 {
+		if len(b) > room {
+			b = b[:room]
+		}
+		w.body.Write(b)
+	}
+}
@@ -0,0 +1,120 @@
+package handler
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ShaohongDong/sub2api/internal/config"
+	middleware2 "github.com/ShaohongDong/sub2api/internal/server/middleware"
+	"github.com/ShaohongDong/sub2api/internal/service"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/require"
+)
+
+type contentLogRepoRecorder struct {
+	entries []*service.RequestContentLog
+}
+
+func (r *contentLogRepoRecorder) Insert(ctx context.Context, entry *service.RequestContentLog) error {
+	r.entries = append(r.entries, entry)
+	return nil
+}
+
+func (r *contentLogRepoRecorder) GetByID(ctx context.Context, id int64) (*service.RequestContentLog, error) {
+	return nil, service.ErrContentLogNotFound
+}
+
+func (r *contentLogRepoRecorder) List(ctx context.Context, filter *service.ContentLogFilter) (*service.ContentLogList, error) {
+	return &service.ContentLogList{}, nil
+}
+
+func (r *contentLogRepoRecorder) DeleteCreatedBefore(ctx context.Context, cutoff time.Time) (int64, error) {
+	return 0, nil
+}
+
+func newContentLogTestRouter(repo *contentLogRepoRecorder, maxBodyBytes int, apiKey *service.APIKey, reply string) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	cfg := &config.Config{ContentLog: config.ContentLogConfig{Enabled: true, MaxBodyBytes: maxBodyBytes, RetentionDays: 3}}
+	h := NewContentLogHandler(service.NewContentLogService(repo, cfg))
+
+	r := gin.New()
+	r.Use(func(c *gin.Context) {
+		c.Set(string(middleware2.ContextKeyAPIKey), apiKey)
+		c.Next()
+	})
+	r.POST("/v1/messages", h.Middleware, func(c *gin.Context) {
+		body, _ := io.ReadAll(c.Request.Body)
+		c.Set(opsModelKey, "claude-sonnet-4-mapped")
+		c.Set(opsAccountIDKey, int64(8))
+		c.Header("X-Request-Id", "req-1")
+		c.String(http.StatusOK, reply+string(body))
+	})
+	return r
+}
+
+func TestContentLogMiddleware_RecordsOptedInKey(t *testing.T) {
+	repo := &contentLogRepoRecorder{}
+	groupID := int64(4)
+	apiKey := &service.APIKey{ID: 2, UserID: 3, GroupID: &groupID, LogContent: true,
+		Group: &service.Group{ID: groupID, Platform: service.PlatformAnthropic}}
+	r := newContentLogTestRouter(repo, 1<<10, apiKey, "echo:")
+
+	body := `{"model":"claude-sonnet-4","stream":true,"messages":[]}`
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/v1/messages", strings.NewReader(body)))
+
+	// 下游处理器仍能读到完整请求体
+	require.Equal(t, "echo:"+body, w.Body.String())
+	require.Len(t, repo.entries, 1)
+	entry := repo.entries[0]
+	require.Equal(t, "req-1", entry.RequestID)
+	require.Equal(t, service.PlatformAnthropic, entry.Platform)
+	require.Equal(t, "claude-sonnet-4-mapped", entry.Model)
+	require.True(t, entry.Stream)
+	require.Equal(t, http.StatusOK, entry.StatusCode)
+	require.Equal(t, body, entry.RequestBody)
+	require.Equal(t, "echo:"+body, entry.ResponseBody)
+	require.Equal(t, len("echo:"+body), entry.ResponseBodyBytes)
+	require.Equal(t, int64(2), *entry.APIKeyID)
+	require.Equal(t, int64(3), *entry.UserID)
+	require.Equal(t, groupID, *entry.GroupID)
+	require.Equal(t, int64(8), *entry.AccountID)
+}
+
+func TestContentLogMiddleware_TruncatesToMaxBodyBytes(t *testing.T) {
+	repo := &contentLogRepoRecorder{}
+	r := newContentLogTestRouter(repo, 16, &service.APIKey{ID: 2, UserID: 3, LogContent: true}, strings.Repeat("界", 10))
+
+	body := `{"model":"claude-sonnet-4","messages":[]}`
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/v1/messages", strings.NewReader(body)))
+
+	require.Len(t, repo.entries, 1)
+	entry := repo.entries[0]
+	require.Equal(t, body[:16], entry.RequestBody)
+	require.Equal(t, len(body), entry.RequestBodyBytes)
+	// 截断落在多字节字符中间时替换为 U+FFFD，保证可写入 text 列
+	require.Equal(t, strings.Repeat("界", 5)+"�", entry.ResponseBody)
+	require.Equal(t, w.Body.Len(), entry.ResponseBodyBytes)
+	require.Equal(t, "claude-sonnet-4-mapped", entry.Model)
+}
+
+func TestContentLogMiddleware_SkipsKeysWithoutOptIn(t *testing.T) {
+	repo := &contentLogRepoRecorder{}
+	r := newContentLogTestRouter(repo, 1<<10, &service.APIKey{ID: 2, UserID: 3}, "ok")
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/v1/messages", strings.NewReader(`{"model":"m"}`)))
+	require.Equal(t, http.StatusOK, w.Code)
+	require.Empty(t, repo.entries)
+
+	var nilHandler *ContentLogHandler
+	c, _ := gin.CreateTestContext(httptest.NewRecorder())
+	c.Request = httptest.NewRequest(http.MethodPost, "/v1/messages", nil)
+	require.NotPanics(t, func() { nilHandler.Middleware(c) })
+}
@@ -0,0 +1,84 @@
+package handler
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+
+	infraerrors "github.com/ShaohongDong/sub2api/internal/pkg/errors"
+	"github.com/ShaohongDong/sub2api/internal/pkg/logger"
+	middleware2 "github.com/ShaohongDong/sub2api/internal/server/middleware"
+	"github.com/ShaohongDong/sub2api/internal/service"
+
+	"github.com/gin-gonic/gin"
+	"github.com/tidwall/gjson"
+	"go.uber.org/zap"
+)
+
+// contextOverflowHeader reports how an oversized request was reduced.
+const contextOverflowHeader = "X-Context-Overflow"
+
+// ContextOverflowHandler checks requests against the target model's context
+// window before they are forwarded and rejects, truncates or compacts them
+// according to gateway.context_overflow.
+type ContextOverflowHandler struct {
+	service *service.ContextOverflowService
+}
+
+// NewContextOverflowHandler creates a new ContextOverflowHandler
+func NewContextOverflowHandler(svc *service.ContextOverflowService) *ContextOverflowHandler {
+	return &ContextOverflowHandler{service: svc}
+}
+
+// Middleware returns the context-window check for one inbound request format
+// (service.ReasoningFormat*). It runs after parameter sanitizing so the
+// max-output-token value actually sent upstream is reserved. Rejections use
+// the error shape of the inbound protocol with code context_length_exceeded.
+func (h *ContextOverflowHandler) Middleware(format string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if h == nil || !h.service.Enabled() || c.Request == nil || c.Request.Method != http.MethodPost || c.Request.Body == nil {
+			c.Next()
+			return
+		}
+		body, err := io.ReadAll(c.Request.Body)
+		_ = c.Request.Body.Close()
+		c.Request.Body = io.NopCloser(bytes.NewReader(body))
+		if err != nil {
+			c.Next()
+			return
+		}
+
+		req := service.ContextOverflowRequest{
+			Format:   format,
+			Model:    strings.TrimSpace(gjson.GetBytes(body, "model").String()),
+			Body:     body,
+			ClientIP: c.ClientIP(),
+		}
+		if apiKey, ok := middleware2.GetAPIKeyFromContext(c); ok && apiKey != nil {
+			req.APIKey = apiKey.Key
+		}
+		rewritten, result, err := h.service.Handle(c.Request.Context(), req)
+		log := logger.FromContext(c.Request.Context())
+		if err != nil {
+			log.Info("gateway.context_overflow_rejected",
+				zap.String("model", req.Model), zap.Int("estimated_tokens", result.EstimatedTokens), zap.Int("budget", result.Budget))
+			abortModerationError(c, http.StatusBadRequest, "context_length_exceeded", infraerrors.Message(err))
+			return
+		}
+		if result.Action == "" {
+			c.Next()
+			return
+		}
+		log.Info("gateway.context_overflow_reduced",
+			zap.String("model", req.Model), zap.String("action", result.Action),
+			zap.Int("estimated_tokens", result.EstimatedTokens), zap.Int("budget", result.Budget),
+			zap.Int("dropped_items", result.DroppedItems))
+		c.Header(contextOverflowHeader, result.Action)
+		c.Request.Body = io.NopCloser(bytes.NewReader(rewritten))
+		c.Request.ContentLength = int64(len(rewritten))
+		c.Request.Header.Set("Content-Length", strconv.Itoa(len(rewritten)))
+		c.Next()
+	}
+}
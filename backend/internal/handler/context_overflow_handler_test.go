@@ -0,0 +1,72 @@
+package handler
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/ShaohongDong/sub2api/internal/config"
+	"github.com/ShaohongDong/sub2api/internal/service"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/require"
+	"github.com/tidwall/gjson"
+)
+
+func TestContextOverflowHandler_RejectAndTruncate(t *testing.T) {
+	newRouter := func(strategy string) (*gin.Engine, *string) {
+		cfg := &config.Config{}
+		cfg.Gateway.ContextOverflow = config.GatewayContextOverflowConfig{
+			Strategy: strategy,
+			Rules:    []config.GatewayContextOverflowRule{{Models: []string{"gpt-4o"}, ContextWindow: 300}},
+		}
+		h := NewContextOverflowHandler(service.NewContextOverflowService(cfg, nil))
+		gin.SetMode(gin.TestMode)
+		got := new(string)
+		r := gin.New()
+		r.POST("/v1/chat/completions", h.Middleware(service.ReasoningFormatChatCompletions), func(c *gin.Context) {
+			raw, _ := io.ReadAll(c.Request.Body)
+			*got = string(raw)
+			require.Equal(t, strconv.Itoa(len(raw)), c.GetHeader("Content-Length"))
+			c.Status(http.StatusNoContent)
+		})
+		return r, got
+	}
+	long := strings.Repeat("lorem ipsum ", 300)
+	body := `{"model":"gpt-4o","messages":[{"role":"user","content":"old ` + long + `"},{"role":"assistant","content":"ok"},{"role":"user","content":"new"}]}`
+	send := func(r *gin.Engine, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/v1/chat/completions", strings.NewReader(body))
+		req.Header.Set("Content-Length", strconv.Itoa(len(body)))
+		rec := httptest.NewRecorder()
+		r.ServeHTTP(rec, req)
+		return rec
+	}
+
+	r, got := newRouter(config.ContextOverflowStrategyReject)
+	rec := send(r, body)
+	require.Equal(t, http.StatusBadRequest, rec.Code)
+	require.Equal(t, "context_length_exceeded", gjson.Get(rec.Body.String(), "error.code").String())
+	require.Contains(t, gjson.Get(rec.Body.String(), "error.message").String(), "300-token context window of gpt-4o")
+	require.Empty(t, *got)
+
+	rec = send(r, `{"model":"gpt-4o","messages":[{"role":"user","content":"hi"}]}`)
+	require.Equal(t, http.StatusNoContent, rec.Code)
+	require.Empty(t, rec.Header().Get(contextOverflowHeader))
+
+	r, got = newRouter(config.ContextOverflowStrategyTruncate)
+	rec = send(r, body)
+	require.Equal(t, http.StatusNoContent, rec.Code)
+	require.Equal(t, service.ContextOverflowTruncated, rec.Header().Get(contextOverflowHeader))
+	require.JSONEq(t, `{"model":"gpt-4o","messages":[{"role":"user","content":"new"}]}`, *got)
+
+	// 未装配时透传
+	var nilHandler *ContextOverflowHandler
+	gin.SetMode(gin.TestMode)
+	passthrough := gin.New()
+	passthrough.POST("/v1/chat/completions", nilHandler.Middleware(service.ReasoningFormatChatCompletions), func(c *gin.Context) {
+		c.Status(http.StatusNoContent)
+	})
+	require.Equal(t, http.StatusNoContent, send(passthrough, body).Code)
+}
@@ -0,0 +1,65 @@
+package handler
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+
+	"github.com/ShaohongDong/sub2api/internal/pkg/logger"
+	middleware2 "github.com/ShaohongDong/sub2api/internal/server/middleware"
+	"github.com/ShaohongDong/sub2api/internal/service"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+// ConversationStoreHandler records Responses API turns in the conversation
+// store so a later previous_response_id can be resolved locally when the
+// upstream cannot (different account, HTTP transport or store=false).
+type ConversationStoreHandler struct {
+	service *service.ConversationStoreService
+}
+
+// NewConversationStoreHandler creates a new ConversationStoreHandler
+func NewConversationStoreHandler(svc *service.ConversationStoreService) *ConversationStoreHandler {
+	return &ConversationStoreHandler{service: svc}
+}
+
+// Middleware wraps /responses endpoints. It keeps a copy of the response
+// (JSON or SSE) up to conversation_store.max_bytes and, for a 200 response,
+// stores the turn's input and output items under the new response id.
+func (h *ConversationStoreHandler) Middleware(c *gin.Context) {
+	if h == nil || !h.service.Enabled() || c.Request.Method != http.MethodPost || c.Request.Body == nil {
+		c.Next()
+		return
+	}
+	apiKey, ok := middleware2.GetAPIKeyFromContext(c)
+	if !ok || apiKey == nil {
+		c.Next()
+		return
+	}
+	body, err := io.ReadAll(c.Request.Body)
+	_ = c.Request.Body.Close()
+	c.Request.Body = io.NopCloser(bytes.NewReader(body))
+	if err != nil {
+		c.Next()
+		return
+	}
+
+	limit := h.service.MaxBytes()
+	w := &captureResponseWriter{ResponseWriter: c.Writer, limit: limit}
+	c.Writer = w
+	c.Next()
+	c.Writer = w.ResponseWriter
+
+	if c.Writer.Status() != http.StatusOK || w.size > limit {
+		return
+	}
+	ctx := c.Request.Context()
+	if err := h.service.Record(ctx, apiKey.ID, body, w.body.Bytes()); err != nil {
+		logger.FromContext(ctx).Warn("gateway.conversation_store_failed",
+			zap.Int64("api_key_id", apiKey.ID),
+			zap.Error(err),
+		)
+	}
+}
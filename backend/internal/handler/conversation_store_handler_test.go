@@ -0,0 +1,52 @@
+package handler
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/ShaohongDong/sub2api/internal/config"
+	middleware2 "github.com/ShaohongDong/sub2api/internal/server/middleware"
+	"github.com/ShaohongDong/sub2api/internal/service"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/require"
+)
+
+func TestConversationStoreHandler_RecordsSuccessfulTurns(t *testing.T) {
+	svc := service.NewConversationStoreService(&config.Config{ConversationStore: config.ConversationStoreConfig{
+		Enabled: true, Backend: "memory", TTLSeconds: 60, MaxEntries: 16, MaxBytes: 1 << 10,
+	}}, nil)
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.POST("/v1/responses", func(c *gin.Context) {
+		c.Set(string(middleware2.ContextKeyAPIKey), &service.APIKey{ID: 7, UserID: 3})
+	}, NewConversationStoreHandler(svc).Middleware, func(c *gin.Context) {
+		switch c.GetHeader("X-Test") {
+		case "fail":
+			c.JSON(http.StatusBadGateway, gin.H{"error": "upstream"})
+		case "sse":
+			c.Header("Content-Type", "text/event-stream")
+			c.String(http.StatusOK, "data: {\"type\":\"response.completed\",\"response\":{\"id\":\"resp_sse\",\"output\":[]}}\n\n")
+		default:
+			c.Data(http.StatusOK, "application/json", []byte(`{"id":"resp_json","output":[{"type":"message","role":"assistant","content":"ok"}]}`))
+		}
+	})
+	send := func(header string) {
+		req := httptest.NewRequest(http.MethodPost, "/v1/responses", strings.NewReader(`{"model":"gpt-5","input":"hi"}`))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("X-Test", header)
+		r.ServeHTTP(httptest.NewRecorder(), req)
+	}
+
+	send("")
+	send("sse")
+	send("fail")
+
+	conv, ok := svc.Lookup(context.Background(), 7, "resp_json")
+	require.True(t, ok)
+	require.Len(t, conv.Items, 2)
+	_, ok = svc.Lookup(context.Background(), 7, "resp_sse")
+	require.True(t, ok)
+}
@@ -0,0 +1,136 @@
+package handler
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/ShaohongDong/sub2api/internal/pkg/ctxkey"
+	middleware2 "github.com/ShaohongDong/sub2api/internal/server/middleware"
+	"github.com/ShaohongDong/sub2api/internal/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+// DeadLetterHandler records gateway requests that still failed after every
+// retry and account failover, so admins can replay them once accounts recover.
+type DeadLetterHandler struct {
+	service *service.DeadLetterService
+}
+
+// NewDeadLetterHandler creates a new DeadLetterHandler
+func NewDeadLetterHandler(svc *service.DeadLetterService) *DeadLetterHandler {
+	return &DeadLetterHandler{service: svc}
+}
+
+// Middleware wraps inference endpoints. After the handler finishes it stores
+// the request when the final response is a 5xx / 429 produced by upstream
+// failures (at least one upstream attempt was made). Local rejections such as
+// auth, quota or concurrency errors never reach upstream and are not recorded.
+func (h *DeadLetterHandler) Middleware(c *gin.Context) {
+	if h == nil || !h.service.Enabled() || c.Request.Method != http.MethodPost {
+		c.Next()
+		return
+	}
+	c.Next()
+
+	if entry := buildDeadLetterEntry(c, h.service.MaxBodyBytes()); entry != nil {
+		h.service.Record(c.Request.Context(), entry)
+	}
+}
+
+func buildDeadLetterEntry(c *gin.Context, maxBodyBytes int) *service.DeadLetterRequest {
+	status := c.Writer.Status()
+	if status < http.StatusInternalServerError && status != http.StatusTooManyRequests {
+		return nil
+	}
+	if isCountTokensRequest(c) {
+		return nil
+	}
+	raw, _ := c.Get(opsRequestBodyKey)
+	body, _ := raw.([]byte)
+	if len(body) == 0 {
+		return nil
+	}
+
+	var events []*service.OpsUpstreamErrorEvent
+	if v, ok := c.Get(service.OpsUpstreamErrorsKey); ok {
+		events, _ = v.([]*service.OpsUpstreamErrorEvent)
+	}
+	upstreamStatus := 0
+	if v, ok := c.Get(service.OpsUpstreamStatusCodeKey); ok {
+		switch t := v.(type) {
+		case int:
+			upstreamStatus = t
+		case int64:
+			upstreamStatus = int(t)
+		}
+	}
+	if len(events) == 0 && upstreamStatus <= 0 {
+		return nil
+	}
+
+	path := c.Request.URL.Path
+	apiKey, _ := middleware2.GetAPIKeyFromContext(c)
+	platform := resolveOpsPlatform(apiKey, guessPlatformFromPath(path))
+	if !service.DeadLetterReplayable(platform, path) {
+		return nil
+	}
+
+	entry := &service.DeadLetterRequest{
+		RequestID:          c.Writer.Header().Get("X-Request-Id"),
+		Platform:           platform,
+		RequestPath:        path,
+		UserAgent:          truncateString(c.GetHeader("User-Agent"), 512),
+		RequestBodyBytes:   len(body),
+		StatusCode:         status,
+		UpstreamStatusCode: upstreamStatus,
+		AttemptCount:       len(events),
+	}
+	entry.ClientRequestID, _ = c.Request.Context().Value(ctxkey.ClientRequestID).(string)
+	if model, ok := c.Get(opsModelKey); ok {
+		entry.Model, _ = model.(string)
+	}
+	if stream, ok := c.Get(opsStreamKey); ok {
+		entry.Stream, _ = stream.(bool)
+	}
+	if len(body) <= maxBodyBytes {
+		entry.RequestBody = string(body)
+	}
+	if headers := extractOpsRetryRequestHeaders(c); headers != nil {
+		entry.RequestHeaders = *headers
+	}
+	if apiKey != nil {
+		entry.APIKeyID = &apiKey.ID
+		entry.UserID = &apiKey.UserID
+		entry.GroupID = apiKey.GroupID
+	}
+
+	reason := ""
+	if v, ok := c.Get(service.OpsUpstreamErrorMessageKey); ok {
+		reason, _ = v.(string)
+	}
+	if len(events) > 0 {
+		if last := events[len(events)-1]; last != nil {
+			if last.AccountID > 0 {
+				accountID := last.AccountID
+				entry.AccountID = &accountID
+			}
+			if last.UpstreamStatusCode > 0 {
+				entry.UpstreamStatusCode = last.UpstreamStatusCode
+			}
+			if msg := strings.TrimSpace(last.Message); msg != "" {
+				reason = msg
+			}
+		}
+	}
+	if entry.AccountID == nil {
+		if v, ok := c.Get(opsAccountIDKey); ok {
+			if accountID, ok := v.(int64); ok && accountID > 0 {
+				entry.AccountID = &accountID
+			}
+		}
+	}
+	entry.FailureReason = truncateString(fmt.Sprintf("upstream %d: %s", entry.UpstreamStatusCode, strings.TrimSpace(reason)), 2048)
+	return entry
+}
@@ -0,0 +1,83 @@
+package handler
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	middleware2 "github.com/ShaohongDong/sub2api/internal/server/middleware"
+	"github.com/ShaohongDong/sub2api/internal/service"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/require"
+)
+
+func newDeadLetterTestContext(status int, body string) *gin.Context {
+	gin.SetMode(gin.TestMode)
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest(http.MethodPost, "/v1/messages", strings.NewReader(body))
+	c.Request.Header.Set("User-Agent", "claude-cli/1.0")
+	c.Status(status)
+	c.Writer.WriteHeaderNow()
+	if body != "" {
+		c.Set(opsRequestBodyKey, []byte(body))
+	}
+	return c
+}
+
+func TestBuildDeadLetterEntry_RecordsUpstreamFailure(t *testing.T) {
+	body := `{"model":"claude-sonnet-4","messages":[]}`
+	c := newDeadLetterTestContext(http.StatusBadGateway, body)
+	groupID := int64(4)
+	c.Set(string(middleware2.ContextKeyAPIKey), &service.APIKey{
+		ID:      2,
+		UserID:  3,
+		Group:   &service.Group{ID: groupID, Platform: service.PlatformAnthropic},
+		GroupID: &groupID,
+	})
+	c.Set(opsModelKey, "claude-sonnet-4")
+	c.Set(opsStreamKey, true)
+	c.Set(service.OpsUpstreamErrorsKey, []*service.OpsUpstreamErrorEvent{
+		{AccountID: 7, UpstreamStatusCode: 503, Message: "unavailable"},
+		{AccountID: 8, UpstreamStatusCode: 529, Message: "overloaded"},
+	})
+
+	entry := buildDeadLetterEntry(c, 1<<20)
+	require.NotNil(t, entry)
+	require.Equal(t, service.PlatformAnthropic, entry.Platform)
+	require.Equal(t, body, entry.RequestBody)
+	require.Equal(t, len(body), entry.RequestBodyBytes)
+	require.Equal(t, http.StatusBadGateway, entry.StatusCode)
+	require.Equal(t, 529, entry.UpstreamStatusCode)
+	require.Equal(t, 2, entry.AttemptCount)
+	require.Equal(t, int64(8), *entry.AccountID)
+	require.Equal(t, int64(2), *entry.APIKeyID)
+	require.Equal(t, int64(3), *entry.UserID)
+	require.Equal(t, groupID, *entry.GroupID)
+	require.Equal(t, "claude-sonnet-4", entry.Model)
+	require.True(t, entry.Stream)
+	require.Equal(t, "upstream 529: overloaded", entry.FailureReason)
+
+	// 超过上限时仅保留元数据，不保存请求体
+	oversized := buildDeadLetterEntry(c, 8)
+	require.NotNil(t, oversized)
+	require.Empty(t, oversized.RequestBody)
+	require.Equal(t, len(body), oversized.RequestBodyBytes)
+}
+
+func TestBuildDeadLetterEntry_SkipsLocalRejections(t *testing.T) {
+	// 本地限流：未触达上游
+	c := newDeadLetterTestContext(http.StatusTooManyRequests, `{"model":"m"}`)
+	require.Nil(t, buildDeadLetterEntry(c, 1<<20))
+
+	// 非 5xx/429 状态码
+	c = newDeadLetterTestContext(http.StatusBadRequest, `{"model":"m"}`)
+	c.Set(service.OpsUpstreamStatusCodeKey, 400)
+	require.Nil(t, buildDeadLetterEntry(c, 1<<20))
+
+	// 缺少请求体
+	c = newDeadLetterTestContext(http.StatusBadGateway, "")
+	c.Set(service.OpsUpstreamStatusCodeKey, 502)
+	require.Nil(t, buildDeadLetterEntry(c, 1<<20))
+}
@@ -5,6 +5,7 @@ import (
 	"strconv"
 	"time"
 
+	"github.com/ShaohongDong/sub2api/internal/pkg/timezone"
 	"github.com/ShaohongDong/sub2api/internal/service"
 )
 
@@ -64,6 +65,7 @@ func UserFromServiceAdmin(u *service.User) *AdminUser {
 		GroupRates:            u.GroupRates,
 		SoraStorageQuotaBytes: u.SoraStorageQuotaBytes,
 		SoraStorageUsedBytes:  u.SoraStorageUsedBytes,
+		TenantID:              u.TenantID,
 	}
 }
 
@@ -72,31 +74,56 @@ func APIKeyFromService(k *service.APIKey) *APIKey {
 		return nil
 	}
 	out := &APIKey{
-		ID:            k.ID,
-		UserID:        k.UserID,
-		Key:           k.Key,
-		Name:          k.Name,
-		GroupID:       k.GroupID,
-		Status:        k.Status,
-		IPWhitelist:   k.IPWhitelist,
-		IPBlacklist:   k.IPBlacklist,
-		LastUsedAt:    k.LastUsedAt,
-		Quota:         k.Quota,
-		QuotaUsed:     k.QuotaUsed,
-		ExpiresAt:     k.ExpiresAt,
-		CreatedAt:     k.CreatedAt,
-		UpdatedAt:     k.UpdatedAt,
-		RateLimit5h:   k.RateLimit5h,
-		RateLimit1d:   k.RateLimit1d,
-		RateLimit7d:   k.RateLimit7d,
-		Usage5h:       k.EffectiveUsage5h(),
-		Usage1d:       k.EffectiveUsage1d(),
-		Usage7d:       k.EffectiveUsage7d(),
-		Window5hStart: k.Window5hStart,
-		Window1dStart: k.Window1dStart,
-		Window7dStart: k.Window7dStart,
-		User:          UserFromServiceShallow(k.User),
-		Group:         GroupFromServiceShallow(k.Group),
+		ID:                  k.ID,
+		UserID:              k.UserID,
+		Key:                 k.Key,
+		Name:                k.Name,
+		Description:         k.Description,
+		GroupID:             k.GroupID,
+		Status:              k.Status,
+		IPWhitelist:         k.IPWhitelist,
+		IPBlacklist:         k.IPBlacklist,
+		Scopes:              k.Scopes,
+		AllowedModels:       k.AllowedModels,
+		ClientRestriction:   k.ClientRestriction,
+		Tags:                k.Tags,
+		LastUsedAt:          k.LastUsedAt,
+		Quota:               k.Quota,
+		QuotaUsed:           k.QuotaUsed,
+		ImageQuota:          k.ImageQuota,
+		ImageQuotaUsed:      k.ImageQuotaUsed,
+		SuppressReasoning:   k.SuppressReasoning,
+		ExpiresAt:           k.ExpiresAt,
+		CreatedAt:           k.CreatedAt,
+		UpdatedAt:           k.UpdatedAt,
+		RateLimit5h:         k.RateLimit5h,
+		RateLimit1d:         k.RateLimit1d,
+		RateLimit7d:         k.RateLimit7d,
+		Usage5h:             k.EffectiveUsage5h(),
+		Usage1d:             k.EffectiveUsage1d(),
+		Usage7d:             k.EffectiveUsage7d(),
+		Window5hStart:       k.Window5hStart,
+		Window1dStart:       k.Window1dStart,
+		Window7dStart:       k.Window7dStart,
+		RPMLimit:            k.RPMLimit,
+		TPMLimit:            k.TPMLimit,
+		DailyRequestLimit:   k.DailyRequestLimit,
+		DailyTokenLimit:     k.DailyTokenLimit,
+		MonthlyRequestLimit: k.MonthlyRequestLimit,
+		MonthlyTokenLimit:   k.MonthlyTokenLimit,
+		BudgetAmount:        k.BudgetAmount,
+		BudgetPeriod:        k.BudgetPeriod,
+		BudgetAction:        k.BudgetAction,
+		BudgetFallbackModel: k.BudgetFallbackModel,
+		User:                UserFromServiceShallow(k.User),
+		Group:               GroupFromServiceShallow(k.Group),
+
+		DegradedFallbackModel: k.DegradedFallbackModel,
+		SystemPrompt:          k.SystemPrompt,
+		SystemPromptMode:      k.SystemPromptMode,
+		ModerationMode:        k.ModerationMode,
+		LogContent:            k.LogContent,
+		Simulate:              k.Simulate,
 	}
 	if k.Window5hStart != nil && !service.IsWindowExpired(k.Window5hStart, service.RateLimitWindow5h) {
 		t := k.Window5hStart.Add(service.RateLimitWindow5h)
@@ -110,6 +137,16 @@ func APIKeyFromService(k *service.APIKey) *APIKey {
 		t := k.Window7dStart.Add(service.RateLimitWindow7d)
 		out.Reset7dAt = &t
 	}
+	if k.HasBudget() {
+		now := timezone.Now()
+		t := service.APIKeyBudgetResetAt(k.BudgetPeriod, now)
+		out.BudgetUsed = k.EffectiveBudgetUsed(now)
+		out.BudgetExceeded = k.IsBudgetExceeded(now)
+		out.BudgetResetAt = &t
+	}
+	if k.PreviousKeyExpiresAt != nil && k.PreviousKeyExpiresAt.After(time.Now()) {
+		out.PreviousKeyExpiresAt = k.PreviousKeyExpiresAt
+	}
 	return out
 }
 
@@ -140,9 +177,13 @@ func GroupFromServiceAdmin(g *service.Group) *AdminGroup {
 		ModelRoutingEnabled:  g.ModelRoutingEnabled,
 		MCPXMLInject:         g.MCPXMLInject,
 		DefaultMappedModel:   g.DefaultMappedModel,
+		ModelAliases:         g.ModelAliases,
+		FallbackChain:        g.FallbackChain,
+		SchedulingStrategy:   g.SchedulingStrategy,
 		SupportedModelScopes: g.SupportedModelScopes,
 		AccountCount:         g.AccountCount,
 		SortOrder:            g.SortOrder,
+		TenantID:             g.TenantID,
 	}
 	if len(g.AccountGroups) > 0 {
 		out.AccountGroups = make([]AccountGroup, 0, len(g.AccountGroups))
@@ -495,6 +536,7 @@ func usageLogFromServiceUser(l *service.UsageLog) UsageLog {
 		APIKeyID:              l.APIKeyID,
 		AccountID:             l.AccountID,
 		RequestID:             l.RequestID,
+		GatewayRequestID:      l.GatewayRequestID,
 		Model:                 l.Model,
 		ReasoningEffort:       l.ReasoningEffort,
 		GroupID:               l.GroupID,
@@ -505,6 +547,7 @@ func usageLogFromServiceUser(l *service.UsageLog) UsageLog {
 		CacheReadTokens:       l.CacheReadTokens,
 		CacheCreation5mTokens: l.CacheCreation5mTokens,
 		CacheCreation1hTokens: l.CacheCreation1hTokens,
+		ReasoningTokens:       l.ReasoningTokens,
 		InputCost:             l.InputCost,
 		OutputCost:            l.OutputCost,
 		CacheCreationCost:     l.CacheCreationCost,
@@ -522,6 +565,7 @@ func usageLogFromServiceUser(l *service.UsageLog) UsageLog {
 		ImageSize:             l.ImageSize,
 		MediaType:             l.MediaType,
 		UserAgent:             l.UserAgent,
+		ClientType:            l.ClientType,
 		CacheTTLOverridden:    l.CacheTTLOverridden,
 		CreatedAt:             l.CreatedAt,
 		User:                  UserFromServiceShallow(l.User),
@@ -80,6 +80,8 @@ type SystemSettings struct {
 	MinClaudeCodeVersion string `json:"min_claude_code_version"`
 	MaxClaudeCodeVersion string `json:"max_claude_code_version"`
 
+	MinCodexCLIVersion string `json:"min_codex_cli_version"`
+
 	// 分组隔离
 	AllowUngroupedKeyScheduling bool `json:"allow_ungrouped_key_scheduling"`
 }
@@ -111,6 +113,8 @@ type PublicSettings struct {
 	PurchaseSubscriptionURL          string           `json:"purchase_subscription_url"`
 	CustomMenuItems                  []CustomMenuItem `json:"custom_menu_items"`
 	LinuxDoOAuthEnabled              bool             `json:"linuxdo_oauth_enabled"`
+	OIDCEnabled                      bool             `json:"oidc_enabled"`
+	OIDCProviderName                 string           `json:"oidc_provider_name"`
 	SoraClientEnabled                bool             `json:"sora_client_enabled"`
 	Version                          string           `json:"version"`
 }
@@ -171,10 +175,12 @@ type RectifierSettings struct {
 
 // BetaPolicyRule Beta 策略规则 DTO
 type BetaPolicyRule struct {
-	BetaToken    string `json:"beta_token"`
-	Action       string `json:"action"`
-	Scope        string `json:"scope"`
-	ErrorMessage string `json:"error_message,omitempty"`
+	BetaToken    string   `json:"beta_token"`
+	Action       string   `json:"action"`
+	Scope        string   `json:"scope"`
+	ErrorMessage string   `json:"error_message,omitempty"`
+	Models       []string `json:"models,omitempty"`
+	AccountIDs   []int64  `json:"account_ids,omitempty"`
 }
 
 // BetaPolicySettings Beta 策略配置 DTO
@@ -182,6 +188,122 @@ type BetaPolicySettings struct {
 	Rules []BetaPolicyRule `json:"rules"`
 }
 
+// ClientRoutingRule 客户端路由规则 DTO
+type ClientRoutingRule struct {
+	ClientTypes  []string `json:"client_types"`
+	AccountTypes []string `json:"account_types"`
+	Strict       bool     `json:"strict,omitempty"`
+}
+
+// ClientRoutingSettings 客户端路由策略配置 DTO
+type ClientRoutingSettings struct {
+	Enabled bool                `json:"enabled"`
+	Rules   []ClientRoutingRule `json:"rules"`
+}
+
+// RoutingRuleHeaderMatch 路由规则请求头条件 DTO
+type RoutingRuleHeaderMatch struct {
+	Name  string `json:"name"`
+	Value string `json:"value,omitempty"`
+}
+
+// RoutingRule 路由规则 DTO
+type RoutingRule struct {
+	Name            string                   `json:"name"`
+	Enabled         bool                     `json:"enabled"`
+	Priority        int                      `json:"priority"`
+	Models          []string                 `json:"models,omitempty"`
+	ClientTypes     []string                 `json:"client_types,omitempty"`
+	Headers         []RoutingRuleHeaderMatch `json:"headers,omitempty"`
+	KeyTags         []string                 `json:"key_tags,omitempty"`
+	SourceGroupIDs  []int64                  `json:"source_group_ids,omitempty"`
+	MinRequestBytes int64                    `json:"min_request_bytes,omitempty"`
+	MaxRequestBytes int64                    `json:"max_request_bytes,omitempty"`
+	TargetGroupID   int64                    `json:"target_group_id"`
+}
+
+// RoutingRuleSettings 路由规则配置 DTO
+type RoutingRuleSettings struct {
+	Enabled bool          `json:"enabled"`
+	Rules   []RoutingRule `json:"rules"`
+}
+
+// CanarySplit 金丝雀分流 DTO
+type CanarySplit struct {
+	Name           string   `json:"name"`
+	Enabled        bool     `json:"enabled"`
+	Models         []string `json:"models"`
+	SourceGroupIDs []int64  `json:"source_group_ids,omitempty"`
+	Percent        float64  `json:"percent"`
+	TargetGroupID  int64    `json:"target_group_id,omitempty"`
+	TargetModel    string   `json:"target_model,omitempty"`
+}
+
+// CanarySettings 金丝雀分流配置 DTO
+type CanarySettings struct {
+	Enabled bool          `json:"enabled"`
+	Splits  []CanarySplit `json:"splits"`
+}
+
+// ShadowMirrorRule 影子流量规则 DTO
+type ShadowMirrorRule struct {
+	Name           string   `json:"name"`
+	Enabled        bool     `json:"enabled"`
+	Models         []string `json:"models"`
+	SourceGroupIDs []int64  `json:"source_group_ids,omitempty"`
+	Percent        float64  `json:"percent"`
+	TargetGroupID  int64    `json:"target_group_id,omitempty"`
+	TargetModel    string   `json:"target_model,omitempty"`
+	StoreResponses bool     `json:"store_responses"`
+}
+
+// ShadowMirrorSettings 影子流量配置 DTO
+type ShadowMirrorSettings struct {
+	Enabled bool               `json:"enabled"`
+	Rules   []ShadowMirrorRule `json:"rules"`
+}
+
+// HeaderPolicyRule 头策略规则 DTO
+type HeaderPolicyRule struct {
+	Name                 string            `json:"name"`
+	Enabled              bool              `json:"enabled"`
+	Platforms            []string          `json:"platforms,omitempty"`
+	AccountIDs           []int64           `json:"account_ids,omitempty"`
+	GroupIDs             []int64           `json:"group_ids,omitempty"`
+	Paths                []string          `json:"paths,omitempty"`
+	ForwardHeaders       []string          `json:"forward_headers,omitempty"`
+	StripHeaders         []string          `json:"strip_headers,omitempty"`
+	SetHeaders           map[string]string `json:"set_headers,omitempty"`
+	StripResponseHeaders []string          `json:"strip_response_headers,omitempty"`
+	SetResponseHeaders   map[string]string `json:"set_response_headers,omitempty"`
+}
+
+// HeaderPolicySettings 头策略配置 DTO
+type HeaderPolicySettings struct {
+	Enabled bool               `json:"enabled"`
+	Rules   []HeaderPolicyRule `json:"rules"`
+}
+
+// ModelAliasSettings 全局模型别名表 DTO
+type ModelAliasSettings struct {
+	Aliases map[string]string `json:"aliases"`
+}
+
+// ModelPriceOverride 自定义模型价格 DTO（USD / 百万 token）
+type ModelPriceOverride struct {
+	Model                  string   `json:"model"`
+	InputPerMTok           *float64 `json:"input_per_mtok,omitempty"`
+	OutputPerMTok          *float64 `json:"output_per_mtok,omitempty"`
+	CacheCreationPerMTok   *float64 `json:"cache_creation_per_mtok,omitempty"`
+	CacheCreation1hPerMTok *float64 `json:"cache_creation_1h_per_mtok,omitempty"`
+	CacheReadPerMTok       *float64 `json:"cache_read_per_mtok,omitempty"`
+}
+
+// PricingOverrideSettings 模型价格表 DTO
+type PricingOverrideSettings struct {
+	Overrides []ModelPriceOverride `json:"overrides"`
+}
+
 // ParseCustomMenuItems parses a JSON string into a slice of CustomMenuItem.
 // Returns empty slice on empty/invalid input.
 func ParseCustomMenuItems(raw string) []CustomMenuItem {
@@ -0,0 +1,36 @@
+package dto
+
+import (
+	"time"
+
+	"github.com/ShaohongDong/sub2api/internal/service"
+)
+
+type Tenant struct {
+	ID          int64  `json:"id"`
+	Name        string `json:"name"`
+	Description string `json:"description"`
+	Status      string `json:"status"`
+
+	UserCount  int64 `json:"user_count"`
+	GroupCount int64 `json:"group_count"`
+
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+func TenantFromService(t *service.Tenant) *Tenant {
+	if t == nil {
+		return nil
+	}
+	return &Tenant{
+		ID:          t.ID,
+		Name:        t.Name,
+		Description: t.Description,
+		Status:      t.Status,
+		UserCount:   t.UserCount,
+		GroupCount:  t.GroupCount,
+		CreatedAt:   t.CreatedAt,
+		UpdatedAt:   t.UpdatedAt,
+	}
+}
@@ -29,23 +29,34 @@ type AdminUser struct {
 	GroupRates            map[int64]float64 `json:"group_rates,omitempty"`
 	SoraStorageQuotaBytes int64             `json:"sora_storage_quota_bytes"`
 	SoraStorageUsedBytes  int64             `json:"sora_storage_used_bytes"`
+	TenantID              *int64            `json:"tenant_id"`
 }
 
 type APIKey struct {
-	ID          int64      `json:"id"`
-	UserID      int64      `json:"user_id"`
-	Key         string     `json:"key"`
-	Name        string     `json:"name"`
-	GroupID     *int64     `json:"group_id"`
-	Status      string     `json:"status"`
-	IPWhitelist []string   `json:"ip_whitelist"`
-	IPBlacklist []string   `json:"ip_blacklist"`
-	LastUsedAt  *time.Time `json:"last_used_at"`
-	Quota       float64    `json:"quota"`      // Quota limit in USD (0 = unlimited)
-	QuotaUsed   float64    `json:"quota_used"` // Used quota amount in USD
-	ExpiresAt   *time.Time `json:"expires_at"` // Expiration time (nil = never expires)
-	CreatedAt   time.Time  `json:"created_at"`
-	UpdatedAt   time.Time  `json:"updated_at"`
+	ID                int64      `json:"id"`
+	UserID            int64      `json:"user_id"`
+	Key               string     `json:"key"`
+	Name              string     `json:"name"`
+	Description       string     `json:"description"`
+	GroupID           *int64     `json:"group_id"`
+	Status            string     `json:"status"`
+	IPWhitelist       []string   `json:"ip_whitelist"`
+	IPBlacklist       []string   `json:"ip_blacklist"`
+	Scopes            []string   `json:"scopes"`             // Allowed endpoint scopes (empty = all endpoints)
+	AllowedModels     []string   `json:"allowed_models"`     // Allowed model patterns, trailing * wildcard (empty = all models)
+	ClientRestriction string     `json:"client_restriction"` // Allowed client class (empty = any client)
+	Tags              []string   `json:"tags"`               // Admin-assigned routing tags
+	LastUsedAt        *time.Time `json:"last_used_at"`
+	Quota             float64    `json:"quota"`      // Quota limit in USD (0 = unlimited)
+	QuotaUsed         float64    `json:"quota_used"` // Used quota amount in USD
+	// Image quota (count of generated images, 0 = unlimited)
+	ImageQuota     int `json:"image_quota"`
+	ImageQuotaUsed int `json:"image_quota_used"`
+	// Drop reasoning output for clients other than Codex / Claude Code
+	SuppressReasoning bool       `json:"suppress_reasoning"`
+	ExpiresAt         *time.Time `json:"expires_at"` // Expiration time (nil = never expires)
+	CreatedAt         time.Time  `json:"created_at"`
+	UpdatedAt         time.Time  `json:"updated_at"`
 
 	// Rate limit fields
 	RateLimit5h   float64    `json:"rate_limit_5h"`
@@ -61,6 +72,42 @@ type APIKey struct {
 	Reset1dAt     *time.Time `json:"reset_1d_at,omitempty"`
 	Reset7dAt     *time.Time `json:"reset_7d_at,omitempty"`
 
+	// Request / token limits (0 = unlimited)
+	RPMLimit            int   `json:"rpm_limit"`
+	TPMLimit            int   `json:"tpm_limit"`
+	DailyRequestLimit   int   `json:"daily_request_limit"`
+	DailyTokenLimit     int64 `json:"daily_token_limit"`
+	MonthlyRequestLimit int   `json:"monthly_request_limit"`
+	MonthlyTokenLimit   int64 `json:"monthly_token_limit"`
+
+	// Spend budget (0 = no budget); usage resets at the start of each period
+	BudgetAmount        float64    `json:"budget_amount"`
+	BudgetPeriod        string     `json:"budget_period"`
+	BudgetAction        string     `json:"budget_action"`
+	BudgetFallbackModel string     `json:"budget_fallback_model"`
+	BudgetUsed          float64    `json:"budget_used"`
+	BudgetExceeded      bool       `json:"budget_exceeded"`
+	BudgetResetAt       *time.Time `json:"budget_reset_at,omitempty"`
+
+	// Model served when every account for the requested model is unavailable ('' = return 503)
+	DegradedFallbackModel string `json:"degraded_fallback_model"`
+
+	// System prompt injected into every request ('' = none) and how it is applied (prepend / append / replace)
+	SystemPrompt     string `json:"system_prompt"`
+	SystemPromptMode string `json:"system_prompt_mode"`
+
+	// Prompt moderation pre-filter: off / flag / block ('' = follow the gateway default)
+	ModerationMode string `json:"moderation_mode"`
+
+	// Whether full prompts and responses are stored for debugging (purged after the content log retention period)
+	LogContent bool `json:"log_content"`
+
+	// Whether requests are answered by the built-in mock upstream instead of real accounts (dry-run)
+	Simulate bool `json:"simulate"`
+
+	// End of the grace window in which the secret replaced by the last rotation still works
+	PreviousKeyExpiresAt *time.Time `json:"previous_key_expires_at,omitempty"`
+
 	User  *User  `json:"user,omitempty"`
 	Group *Group `json:"group,omitempty"`
 }
@@ -120,6 +167,13 @@ type AdminGroup struct {
 
 	// OpenAI Messages 调度配置（仅 openai 平台使用）
 	DefaultMappedModel string `json:"default_mapped_model"`
+	SchedulingStrategy string `json:"scheduling_strategy"`
+
+	// 模型别名（覆盖全局别名表）
+	ModelAliases map[string]string `json:"model_aliases"`
+
+	// 跨平台降级链
+	FallbackChain []int64 `json:"fallback_chain"`
 
 	// 支持的模型系列（仅 antigravity 平台使用）
 	SupportedModelScopes []string       `json:"supported_model_scopes"`
@@ -128,6 +182,9 @@ type AdminGroup struct {
 
 	// 分组排序
 	SortOrder int `json:"sort_order"`
+
+	// 所属租户
+	TenantID *int64 `json:"tenant_id"`
 }
 
 type Account struct {
@@ -321,7 +378,9 @@ type UsageLog struct {
 	APIKeyID  int64  `json:"api_key_id"`
 	AccountID int64  `json:"account_id"`
 	RequestID string `json:"request_id"`
-	Model     string `json:"model"`
+	// GatewayRequestID is the gateway's X-Request-ID for the request (RequestID is the upstream one).
+	GatewayRequestID string `json:"gateway_request_id,omitempty"`
+	Model            string `json:"model"`
 	// ServiceTier records the OpenAI service tier used for billing, e.g. "priority" / "flex".
 	ServiceTier *string `json:"service_tier,omitempty"`
 	// ReasoningEffort is the request's reasoning effort level.
@@ -338,6 +397,7 @@ type UsageLog struct {
 
 	CacheCreation5mTokens int `json:"cache_creation_5m_tokens"`
 	CacheCreation1hTokens int `json:"cache_creation_1h_tokens"`
+	ReasoningTokens       int `json:"reasoning_tokens"`
 
 	InputCost         float64 `json:"input_cost"`
 	OutputCost        float64 `json:"output_cost"`
@@ -361,6 +421,8 @@ type UsageLog struct {
 
 	// User-Agent
 	UserAgent *string `json:"user_agent"`
+	// ClientType is the client type detected when the request was handled (empty for legacy rows).
+	ClientType string `json:"client_type,omitempty"`
 
 	// Cache TTL Override 标记
 	CacheTTLOverridden bool `json:"cache_ttl_overridden"`
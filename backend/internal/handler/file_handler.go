@@ -0,0 +1,181 @@
+package handler
+
+import (
+	"io"
+	"mime"
+	"net/http"
+	"strconv"
+
+	middleware2 "github.com/ShaohongDong/sub2api/internal/server/middleware"
+	"github.com/ShaohongDong/sub2api/internal/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+// FileHandler handles the OpenAI-compatible /v1/files endpoints.
+//
+// Uploaded files can be referenced by file_id from Responses input_file /
+// input_image parts, Chat Completions file parts and /v1/batches
+// input_file_id; the gateway inlines them before forwarding.
+type FileHandler struct {
+	userFileService *service.UserFileService
+}
+
+// NewFileHandler creates a new FileHandler
+func NewFileHandler(userFileService *service.UserFileService) *FileHandler {
+	return &FileHandler{userFileService: userFileService}
+}
+
+type fileObject struct {
+	ID        string `json:"id"`
+	Object    string `json:"object"`
+	Bytes     int64  `json:"bytes"`
+	CreatedAt int64  `json:"created_at"`
+	ExpiresAt *int64 `json:"expires_at"`
+	Filename  string `json:"filename"`
+	Purpose   string `json:"purpose"`
+	Status    string `json:"status"`
+}
+
+// Upload handles POST /v1/files (multipart: file, purpose, expires_after[seconds])
+func (h *FileHandler) Upload(c *gin.Context) {
+	apiKey, ok := middleware2.GetAPIKeyFromContext(c)
+	if !ok {
+		openAIAPIErrorResponse(c, http.StatusUnauthorized, "authentication_error", "Invalid API key")
+		return
+	}
+
+	fileHeader, err := c.FormFile("file")
+	if err != nil {
+		if maxErr, ok := extractMaxBytesError(err); ok {
+			openAIAPIErrorResponse(c, http.StatusRequestEntityTooLarge, "invalid_request_error", buildBodyTooLargeMessage(maxErr.Limit))
+			return
+		}
+		openAIAPIErrorResponse(c, http.StatusBadRequest, "invalid_request_error", "file is required")
+		return
+	}
+	f, err := fileHeader.Open()
+	if err != nil {
+		openAIAPIErrorResponse(c, http.StatusBadRequest, "invalid_request_error", "failed to read file")
+		return
+	}
+	defer func() { _ = f.Close() }()
+	data, err := io.ReadAll(f)
+	if err != nil {
+		openAIAPIErrorResponse(c, http.StatusBadRequest, "invalid_request_error", "failed to read file")
+		return
+	}
+
+	in := service.UploadUserFileInput{
+		Filename: fileHeader.Filename,
+		Purpose:  c.PostForm("purpose"),
+		MimeType: fileHeader.Header.Get("Content-Type"),
+		Data:     data,
+	}
+	if v := c.PostForm("expires_after[seconds]"); v != "" {
+		seconds, err := strconv.ParseInt(v, 10, 64)
+		if err != nil {
+			openAIAPIErrorResponse(c, http.StatusBadRequest, "invalid_request_error", "expires_after[seconds] must be an integer")
+			return
+		}
+		in.ExpiresAfterSeconds = seconds
+	}
+
+	file, err := h.userFileService.Upload(c.Request.Context(), apiKey, in)
+	if err != nil {
+		openAIServiceError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, toFileObject(file))
+}
+
+// List handles GET /v1/files
+func (h *FileHandler) List(c *gin.Context) {
+	apiKey, ok := middleware2.GetAPIKeyFromContext(c)
+	if !ok {
+		openAIAPIErrorResponse(c, http.StatusUnauthorized, "authentication_error", "Invalid API key")
+		return
+	}
+	limit, _ := strconv.Atoi(c.Query("limit"))
+
+	files, err := h.userFileService.List(c.Request.Context(), apiKey.UserID, c.Query("purpose"), c.Query("after"), limit)
+	if err != nil {
+		openAIServiceError(c, err)
+		return
+	}
+	data := make([]fileObject, 0, len(files))
+	for _, file := range files {
+		data = append(data, toFileObject(file))
+	}
+	resp := gin.H{
+		"object":   "list",
+		"data":     data,
+		"first_id": nil,
+		"last_id":  nil,
+		"has_more": limit > 0 && len(files) >= limit,
+	}
+	if len(data) > 0 {
+		resp["first_id"] = data[0].ID
+		resp["last_id"] = data[len(data)-1].ID
+	}
+	c.JSON(http.StatusOK, resp)
+}
+
+// Get handles GET /v1/files/:id
+func (h *FileHandler) Get(c *gin.Context) {
+	apiKey, ok := middleware2.GetAPIKeyFromContext(c)
+	if !ok {
+		openAIAPIErrorResponse(c, http.StatusUnauthorized, "authentication_error", "Invalid API key")
+		return
+	}
+	file, err := h.userFileService.Get(c.Request.Context(), apiKey.UserID, c.Param("id"))
+	if err != nil {
+		openAIServiceError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, toFileObject(file))
+}
+
+// Delete handles DELETE /v1/files/:id
+func (h *FileHandler) Delete(c *gin.Context) {
+	apiKey, ok := middleware2.GetAPIKeyFromContext(c)
+	if !ok {
+		openAIAPIErrorResponse(c, http.StatusUnauthorized, "authentication_error", "Invalid API key")
+		return
+	}
+	id := c.Param("id")
+	if err := h.userFileService.Delete(c.Request.Context(), apiKey.UserID, id); err != nil {
+		openAIServiceError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"id": id, "object": "file", "deleted": true})
+}
+
+// Content handles GET /v1/files/:id/content
+func (h *FileHandler) Content(c *gin.Context) {
+	apiKey, ok := middleware2.GetAPIKeyFromContext(c)
+	if !ok {
+		openAIAPIErrorResponse(c, http.StatusUnauthorized, "authentication_error", "Invalid API key")
+		return
+	}
+	file, content, err := h.userFileService.GetContent(c.Request.Context(), apiKey.UserID, c.Param("id"))
+	if err != nil {
+		openAIServiceError(c, err)
+		return
+	}
+	c.Header("Content-Disposition", mime.FormatMediaType("attachment", map[string]string{"filename": file.Filename}))
+	c.Data(http.StatusOK, file.MimeType, content)
+}
+
+func toFileObject(file *service.UserFile) fileObject {
+	return fileObject{
+		ID:        file.ID,
+		Object:    "file",
+		Bytes:     file.Bytes,
+		CreatedAt: file.CreatedAt.Unix(),
+		ExpiresAt: unixPtr(file.ExpiresAt),
+		Filename:  file.Filename,
+		Purpose:   file.Purpose,
+		Status:    "processed",
+	}
+}
@@ -3,6 +3,8 @@ package handler
 import (
 	"context"
 	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
 	"encoding/json"
 	"errors"
 	"fmt"
@@ -15,7 +17,9 @@ import (
 	"github.com/ShaohongDong/sub2api/internal/config"
 	"github.com/ShaohongDong/sub2api/internal/domain"
 	"github.com/ShaohongDong/sub2api/internal/pkg/antigravity"
+	"github.com/ShaohongDong/sub2api/internal/pkg/apicompat"
 	"github.com/ShaohongDong/sub2api/internal/pkg/claude"
+	"github.com/ShaohongDong/sub2api/internal/pkg/clientdetect"
 	"github.com/ShaohongDong/sub2api/internal/pkg/ctxkey"
 	pkgerrors "github.com/ShaohongDong/sub2api/internal/pkg/errors"
 	pkghttputil "github.com/ShaohongDong/sub2api/internal/pkg/httputil"
@@ -185,6 +189,11 @@ func (h *GatewayHandler) Messages(c *gin.Context) {
 		return
 	}
 
+	if !apiKey.AllowsModel(clientRequestedModel(c, reqModel)) {
+		h.errorResponse(c, http.StatusForbidden, "permission_error", apiKeyModelNotAllowedMessage(clientRequestedModel(c, reqModel)))
+		return
+	}
+
 	// Track if we've started streaming (for error handling)
 	streamStarted := false
 
@@ -246,9 +255,10 @@ func (h *GatewayHandler) Messages(c *gin.Context) {
 
 	// 计算粘性会话hash
 	parsedReq.SessionContext = &service.SessionContext{
-		ClientIP:  ip.GetClientIP(c),
-		UserAgent: c.GetHeader("User-Agent"),
-		APIKeyID:  apiKey.ID,
+		ClientIP:    ip.GetClientIP(c),
+		UserAgent:   c.GetHeader("User-Agent"),
+		APIKeyID:    apiKey.ID,
+		AffinityKey: h.gatewayService.ClientSessionAffinityKey(c),
 	}
 	sessionHash := h.gatewayService.GenerateSessionHash(parsedReq)
 
@@ -440,7 +450,7 @@ func (h *GatewayHandler) Messages(c *gin.Context) {
 			}
 
 			// 使用量记录通过有界 worker 池提交，避免请求热路径创建无界 goroutine。
-			h.submitUsageRecordTask(func(ctx context.Context) {
+			h.submitUsageRecordTask(c, func(ctx context.Context) {
 				if err := h.gatewayService.RecordUsage(ctx, &service.RecordUsageInput{
 					Result:            result,
 					APIKey:            apiKey,
@@ -679,7 +689,8 @@ func (h *GatewayHandler) Messages(c *gin.Context) {
 						}
 						if fallbackGroup.Platform != service.PlatformAnthropic ||
 							fallbackGroup.SubscriptionType == service.SubscriptionTypeSubscription ||
-							fallbackGroup.FallbackGroupIDOnInvalidRequest != nil {
+							fallbackGroup.FallbackGroupIDOnInvalidRequest != nil ||
+							!service.TenantMatches(fallbackGroup.TenantID, apiKey.Group.TenantID) {
 							reqLog.Warn("gateway.fallback_group_invalid",
 								zap.Int64("fallback_group_id", fallbackGroup.ID),
 								zap.String("fallback_platform", fallbackGroup.Platform),
@@ -746,7 +757,7 @@ func (h *GatewayHandler) Messages(c *gin.Context) {
 			}
 
 			// 使用量记录通过有界 worker 池提交，避免请求热路径创建无界 goroutine。
-			h.submitUsageRecordTask(func(ctx context.Context) {
+			h.submitUsageRecordTask(c, func(ctx context.Context) {
 				if err := h.gatewayService.RecordUsage(ctx, &service.RecordUsageInput{
 					Result:            result,
 					APIKey:            currentAPIKey,
@@ -778,8 +789,10 @@ func (h *GatewayHandler) Messages(c *gin.Context) {
 
 // Models handles listing available models
 // GET /v1/models
-// Returns models based on account configurations (model_mapping whitelist)
-// Falls back to default models if no whitelist is configured
+// Returns the models currently served by the group's healthy accounts
+// (model_mapping aliases, or platform defaults filtered by account plan).
+// Falls back to the static default list only when the account pool cannot be listed.
+// Keys with a model allowlist only see the models they may use.
 func (h *GatewayHandler) Models(c *gin.Context) {
 	apiKey, _ := middleware2.GetAPIKeyFromContext(c)
 
@@ -797,28 +810,18 @@ func (h *GatewayHandler) Models(c *gin.Context) {
 	if platform == service.PlatformSora {
 		c.JSON(http.StatusOK, gin.H{
 			"object": "list",
-			"data":   service.DefaultSoraModels(h.cfg),
+			"data":   filterModelsForAPIKey(apiKey, service.DefaultSoraModels(h.cfg), openAIModelID),
 		})
 		return
 	}
 
-	// Get available models from account configurations (without platform filter)
+	// Get available models from the live account pool (without platform filter)
 	availableModels := h.gatewayService.GetAvailableModels(c.Request.Context(), groupID, "")
 
-	if len(availableModels) > 0 {
-		// Build model list from whitelist
-		models := make([]claude.Model, 0, len(availableModels))
-		for _, modelID := range availableModels {
-			models = append(models, claude.Model{
-				ID:          modelID,
-				Type:        "model",
-				DisplayName: modelID,
-				CreatedAt:   "2024-01-01T00:00:00Z",
-			})
-		}
+	if availableModels != nil {
 		c.JSON(http.StatusOK, gin.H{
 			"object": "list",
-			"data":   models,
+			"data":   buildModelListEntries(platform, apiKey.FilterAllowedModels(availableModels)),
 		})
 		return
 	}
@@ -827,23 +830,108 @@ func (h *GatewayHandler) Models(c *gin.Context) {
 	if platform == "openai" {
 		c.JSON(http.StatusOK, gin.H{
 			"object": "list",
-			"data":   openai.DefaultModels,
+			"data":   filterModelsForAPIKey(apiKey, openai.DefaultModels, openAIModelID),
 		})
 		return
 	}
 
 	c.JSON(http.StatusOK, gin.H{
 		"object": "list",
-		"data":   claude.DefaultModels,
+		"data":   filterModelsForAPIKey(apiKey, claude.DefaultModels, func(m claude.Model) string { return m.ID }),
 	})
 }
 
+// buildModelListEntries 按分组平台输出模型条目：OpenAI 分组使用 OpenAI 格式，其余使用 Anthropic 格式；
+// 默认模型表中已知的模型沿用其展示名与创建时间。
+func buildModelListEntries(platform string, modelIDs []string) any {
+	if platform == service.PlatformOpenAI {
+		known := make(map[string]openai.Model, len(openai.DefaultModels))
+		for _, m := range openai.DefaultModels {
+			known[m.ID] = m
+		}
+		models := make([]openai.Model, 0, len(modelIDs))
+		for _, modelID := range modelIDs {
+			if m, ok := known[modelID]; ok {
+				models = append(models, m)
+				continue
+			}
+			models = append(models, openai.Model{
+				ID:          modelID,
+				Object:      "model",
+				OwnedBy:     "openai",
+				Type:        "model",
+				DisplayName: modelID,
+			})
+		}
+		return models
+	}
+
+	known := make(map[string]claude.Model, len(claude.DefaultModels))
+	for _, m := range claude.DefaultModels {
+		known[m.ID] = m
+	}
+	models := make([]claude.Model, 0, len(modelIDs))
+	for _, modelID := range modelIDs {
+		if m, ok := known[modelID]; ok {
+			models = append(models, m)
+			continue
+		}
+		models = append(models, claude.Model{
+			ID:          modelID,
+			Type:        "model",
+			DisplayName: modelID,
+			CreatedAt:   "2024-01-01T00:00:00Z",
+		})
+	}
+	return models
+}
+
+// OllamaTags 以 Ollama 格式返回可用模型列表
+// GET /api/tags
+func (h *GatewayHandler) OllamaTags(c *gin.Context) {
+	apiKey, _ := middleware2.GetAPIKeyFromContext(c)
+
+	var groupID *int64
+	var platform string
+	if apiKey != nil && apiKey.Group != nil {
+		groupID = &apiKey.Group.ID
+		platform = apiKey.Group.Platform
+	}
+
+	modelIDs := h.gatewayService.GetAvailableModels(c.Request.Context(), groupID, "")
+	if modelIDs == nil {
+		if platform == service.PlatformOpenAI {
+			modelIDs = openai.DefaultModelIDs()
+		} else {
+			modelIDs = claude.DefaultModelIDs()
+		}
+	}
+	modelIDs = apiKey.FilterAllowedModels(modelIDs)
+
+	models := make([]apicompat.OllamaModel, 0, len(modelIDs))
+	for _, modelID := range modelIDs {
+		digest := sha256.Sum256([]byte(modelID))
+		models = append(models, apicompat.OllamaModel{
+			Name:       modelID,
+			Model:      modelID,
+			ModifiedAt: "2024-01-01T00:00:00Z",
+			Digest:     hex.EncodeToString(digest[:]),
+			Details: apicompat.OllamaModelDetails{
+				Format: "api",
+				Family: platform,
+			},
+		})
+	}
+	c.JSON(http.StatusOK, apicompat.OllamaTagsResponse{Models: models})
+}
+
 // AntigravityModels 返回 Antigravity 支持的全部模型
 // GET /antigravity/models
 func (h *GatewayHandler) AntigravityModels(c *gin.Context) {
+	apiKey, _ := middleware2.GetAPIKeyFromContext(c)
 	c.JSON(http.StatusOK, gin.H{
 		"object": "list",
-		"data":   antigravity.DefaultModels(),
+		"data":   filterModelsForAPIKey(apiKey, antigravity.DefaultModels(), func(m antigravity.ClaudeModel) string { return m.ID }),
 	})
 }
 
@@ -1166,6 +1254,7 @@ func (h *GatewayHandler) handleConcurrencyError(c *gin.Context, err error, slotT
 }
 
 func (h *GatewayHandler) handleFailoverExhausted(c *gin.Context, failoverErr *service.UpstreamFailoverError, platform string, streamStarted bool) {
+	setUpstreamRetryAfter(c, failoverErr)
 	statusCode := failoverErr.StatusCode
 	responseBody := failoverErr.ResponseBody
 
@@ -1366,6 +1455,11 @@ func (h *GatewayHandler) CountTokens(c *gin.Context) {
 
 	setOpsRequestContext(c, parsedReq.Model, parsedReq.Stream, body)
 
+	if !apiKey.AllowsModel(clientRequestedModel(c, parsedReq.Model)) {
+		h.errorResponse(c, http.StatusForbidden, "permission_error", apiKeyModelNotAllowedMessage(clientRequestedModel(c, parsedReq.Model)))
+		return
+	}
+
 	// 获取订阅信息（可能为nil）
 	subscription, _ := middleware2.GetSubscriptionFromContext(c)
 
@@ -1379,9 +1473,10 @@ func (h *GatewayHandler) CountTokens(c *gin.Context) {
 
 	// 计算粘性会话 hash
 	parsedReq.SessionContext = &service.SessionContext{
-		ClientIP:  ip.GetClientIP(c),
-		UserAgent: c.GetHeader("User-Agent"),
-		APIKeyID:  apiKey.ID,
+		ClientIP:    ip.GetClientIP(c),
+		UserAgent:   c.GetHeader("User-Agent"),
+		APIKeyID:    apiKey.ID,
+		AffinityKey: h.gatewayService.ClientSessionAffinityKey(c),
 	}
 	sessionHash := h.gatewayService.GenerateSessionHash(parsedReq)
 
@@ -1670,10 +1765,11 @@ func (h *GatewayHandler) maybeLogCompatibilityFallbackMetrics(reqLog *zap.Logger
 	)
 }
 
-func (h *GatewayHandler) submitUsageRecordTask(task service.UsageRecordTask) {
+func (h *GatewayHandler) submitUsageRecordTask(c *gin.Context, task service.UsageRecordTask) {
 	if task == nil {
 		return
 	}
+	task = withRequestContext(c, task)
 	if h.usageRecordWorkerPool != nil {
 		h.usageRecordWorkerPool.Submit(task)
 		return
@@ -1692,6 +1788,29 @@ func (h *GatewayHandler) submitUsageRecordTask(task service.UsageRecordTask) {
 	task(ctx)
 }
 
+// withRequestContext carries the gateway request ID and the detected client of
+// c into the detached usage task context, so the usage record can be joined
+// with logs and traces and attributed to the same client type as the live path.
+func withRequestContext(c *gin.Context, task service.UsageRecordTask) service.UsageRecordTask {
+	if c == nil || c.Request == nil {
+		return task
+	}
+	requestID := service.GatewayRequestIDFromContext(c.Request.Context())
+	clientInfo, hasClient := clientdetect.FromContext(c.Request.Context())
+	if requestID == "" && !hasClient {
+		return task
+	}
+	return func(ctx context.Context) {
+		if requestID != "" {
+			ctx = context.WithValue(ctx, ctxkey.RequestID, requestID)
+		}
+		if hasClient {
+			ctx = clientdetect.IntoContext(ctx, clientInfo)
+		}
+		task(ctx)
+	}
+}
+
 // getUserMsgQueueMode 获取当前请求的 UMQ 模式
 // 返回 "serialize" | "throttle" | ""
 func (h *GatewayHandler) getUserMsgQueueMode(account *service.Account, parsed *service.ParsedRequest) string {
@@ -1,20 +1,21 @@
 package handler
 
 import (
-	"net/http"
-
 	"github.com/gin-gonic/gin"
 )
 
-// ChatCompletions remains disabled for Anthropic platform groups on this branch.
-// The underlying conversion layer was not present in the selected upstream set.
+// ChatCompletions handles OpenAI Chat Completions API endpoint for Anthropic and Gemini platform groups.
+// POST /v1/chat/completions
+// Requests are converted through the Responses format to Anthropic Messages
+// (including tools / tool_choice / tool_calls), forwarded to Anthropic upstream,
+// and converted back to chat.completion / chat.completion.chunk objects.
+// Gemini API-key accounts are served through Google's OpenAI-compatible endpoint.
 func (h *GatewayHandler) ChatCompletions(c *gin.Context) {
-	h.chatCompletionsErrorResponse(
-		c,
-		http.StatusBadRequest,
-		"invalid_request_error",
-		"Chat Completions compatibility for Anthropic groups is temporarily unavailable. Please use /v1/responses or /v1/messages.",
-	)
+	h.serveAnthropicCompat(c, anthropicCompatEndpoint{
+		name:          "chat_completions",
+		errorResponse: h.chatCompletionsErrorResponse,
+		forward:       h.gatewayService.ForwardAsChatCompletions,
+	})
 }
 
 // chatCompletionsErrorResponse writes an error in OpenAI Chat Completions format.
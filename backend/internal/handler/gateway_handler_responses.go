@@ -15,29 +15,48 @@ import (
 	"go.uber.org/zap"
 )
 
+// anthropicCompatEndpoint describes one OpenAI-compatible endpoint served by
+// Anthropic platform groups through protocol conversion. serveAnthropicCompat
+// shares auth, concurrency, billing, failover and usage recording; the
+// endpoint only supplies its error format and conversion-aware forward call.
+type anthropicCompatEndpoint struct {
+	name          string
+	errorResponse func(c *gin.Context, status int, code, message string)
+	forward       func(ctx context.Context, c *gin.Context, account *service.Account, body []byte, parsed *service.ParsedRequest) (*service.ForwardResult, error)
+}
+
 // Responses handles OpenAI Responses API endpoint for Anthropic platform groups.
 // POST /v1/responses
 // This converts Responses API requests to Anthropic format, forwards to Anthropic
 // upstream, and converts responses back to Responses format.
 func (h *GatewayHandler) Responses(c *gin.Context) {
+	h.serveAnthropicCompat(c, anthropicCompatEndpoint{
+		name:          "responses",
+		errorResponse: h.responsesErrorResponse,
+		forward:       h.gatewayService.ForwardAsResponses,
+	})
+}
+
+// serveAnthropicCompat runs the common request flow for an anthropicCompatEndpoint.
+func (h *GatewayHandler) serveAnthropicCompat(c *gin.Context, ep anthropicCompatEndpoint) {
 	streamStarted := false
 
 	requestStart := time.Now()
 
 	apiKey, ok := middleware2.GetAPIKeyFromContext(c)
 	if !ok {
-		h.responsesErrorResponse(c, http.StatusUnauthorized, "authentication_error", "Invalid API key")
+		ep.errorResponse(c, http.StatusUnauthorized, "authentication_error", "Invalid API key")
 		return
 	}
 
 	subject, ok := middleware2.GetAuthSubjectFromContext(c)
 	if !ok {
-		h.responsesErrorResponse(c, http.StatusInternalServerError, "api_error", "User context not found")
+		ep.errorResponse(c, http.StatusInternalServerError, "api_error", "User context not found")
 		return
 	}
 	reqLog := requestLogger(
 		c,
-		"handler.gateway.responses",
+		"handler.gateway."+ep.name,
 		zap.Int64("user_id", subject.UserID),
 		zap.Int64("api_key_id", apiKey.ID),
 		zap.Any("group_id", apiKey.GroupID),
@@ -47,15 +66,15 @@ func (h *GatewayHandler) Responses(c *gin.Context) {
 	body, err := pkghttputil.ReadRequestBodyWithPrealloc(c.Request)
 	if err != nil {
 		if maxErr, ok := extractMaxBytesError(err); ok {
-			h.responsesErrorResponse(c, http.StatusRequestEntityTooLarge, "invalid_request_error", buildBodyTooLargeMessage(maxErr.Limit))
+			ep.errorResponse(c, http.StatusRequestEntityTooLarge, "invalid_request_error", buildBodyTooLargeMessage(maxErr.Limit))
 			return
 		}
-		h.responsesErrorResponse(c, http.StatusBadRequest, "invalid_request_error", "Failed to read request body")
+		ep.errorResponse(c, http.StatusBadRequest, "invalid_request_error", "Failed to read request body")
 		return
 	}
 
 	if len(body) == 0 {
-		h.responsesErrorResponse(c, http.StatusBadRequest, "invalid_request_error", "Request body is empty")
+		ep.errorResponse(c, http.StatusBadRequest, "invalid_request_error", "Request body is empty")
 		return
 	}
 
@@ -63,14 +82,14 @@ func (h *GatewayHandler) Responses(c *gin.Context) {
 
 	// Validate JSON
 	if !gjson.ValidBytes(body) {
-		h.responsesErrorResponse(c, http.StatusBadRequest, "invalid_request_error", "Failed to parse request body")
+		ep.errorResponse(c, http.StatusBadRequest, "invalid_request_error", "Failed to parse request body")
 		return
 	}
 
 	// Extract model and stream using gjson (like OpenAI handler)
 	modelResult := gjson.GetBytes(body, "model")
 	if !modelResult.Exists() || modelResult.Type != gjson.String || modelResult.String() == "" {
-		h.responsesErrorResponse(c, http.StatusBadRequest, "invalid_request_error", "model is required")
+		ep.errorResponse(c, http.StatusBadRequest, "invalid_request_error", "model is required")
 		return
 	}
 	reqModel := modelResult.String()
@@ -80,14 +99,19 @@ func (h *GatewayHandler) Responses(c *gin.Context) {
 	setOpsRequestContext(c, reqModel, reqStream, body)
 	setOpsEndpointContext(c, "", int16(service.RequestTypeFromLegacy(reqStream, false)))
 
+	if !apiKey.AllowsModel(clientRequestedModel(c, reqModel)) {
+		ep.errorResponse(c, http.StatusForbidden, "permission_error", apiKeyModelNotAllowedMessage(clientRequestedModel(c, reqModel)))
+		return
+	}
+
 	// Claude Code only restriction:
-	// /v1/responses is never a Claude Code endpoint.
-	// When claude_code_only is enabled, this endpoint is rejected.
+	// OpenAI-compatible endpoints (/v1/responses, /v1/chat/completions) are never
+	// Claude Code endpoints. When claude_code_only is enabled, they are rejected.
 	// The existing service-layer checkClaudeCodeRestriction handles degradation
 	// to fallback groups when the Forward path calls SelectAccountForModelWithExclusions.
-	// Here we just reject at handler level since /v1/responses clients can't be Claude Code.
+	// Here we just reject at handler level since these clients can't be Claude Code.
 	if apiKey.Group != nil && apiKey.Group.ClaudeCodeOnly {
-		h.responsesErrorResponse(c, http.StatusForbidden, "permission_error",
+		ep.errorResponse(c, http.StatusForbidden, "permission_error",
 			"This group is restricted to Claude Code clients (/v1/messages only)")
 		return
 	}
@@ -106,9 +130,9 @@ func (h *GatewayHandler) Responses(c *gin.Context) {
 	canWait, err := h.concurrencyHelper.IncrementWaitCount(c.Request.Context(), subject.UserID, maxWait)
 	waitCounted := false
 	if err != nil {
-		reqLog.Warn("gateway.responses.user_wait_counter_increment_failed", zap.Error(err))
+		reqLog.Warn("gateway."+ep.name+".user_wait_counter_increment_failed", zap.Error(err))
 	} else if !canWait {
-		h.responsesErrorResponse(c, http.StatusTooManyRequests, "rate_limit_error", "Too many pending requests, please retry later")
+		ep.errorResponse(c, http.StatusTooManyRequests, "rate_limit_error", "Too many pending requests, please retry later")
 		return
 	}
 	if err == nil && canWait {
@@ -122,7 +146,7 @@ func (h *GatewayHandler) Responses(c *gin.Context) {
 
 	userReleaseFunc, err := h.concurrencyHelper.AcquireUserSlotWithWait(c, subject.UserID, subject.Concurrency, reqStream, &streamStarted)
 	if err != nil {
-		reqLog.Warn("gateway.responses.user_slot_acquire_failed", zap.Error(err))
+		reqLog.Warn("gateway."+ep.name+".user_slot_acquire_failed", zap.Error(err))
 		h.handleConcurrencyError(c, err, "user", streamStarted)
 		return
 	}
@@ -137,21 +161,22 @@ func (h *GatewayHandler) Responses(c *gin.Context) {
 
 	// 2. Re-check billing
 	if err := h.billingCacheService.CheckBillingEligibility(c.Request.Context(), apiKey.User, apiKey, apiKey.Group, subscription); err != nil {
-		reqLog.Info("gateway.responses.billing_check_failed", zap.Error(err))
+		reqLog.Info("gateway."+ep.name+".billing_check_failed", zap.Error(err))
 		status, code, message := billingErrorDetails(err)
-		h.responsesErrorResponse(c, status, code, message)
+		ep.errorResponse(c, status, code, message)
 		return
 	}
 
 	// Parse request for session hash
-	parsedReq, _ := service.ParseGatewayRequest(body, "responses")
+	parsedReq, _ := service.ParseGatewayRequest(body, ep.name)
 	if parsedReq == nil {
 		parsedReq = &service.ParsedRequest{Model: reqModel, Stream: reqStream, Body: body}
 	}
 	parsedReq.SessionContext = &service.SessionContext{
-		ClientIP:  ip.GetClientIP(c),
-		UserAgent: c.GetHeader("User-Agent"),
-		APIKeyID:  apiKey.ID,
+		ClientIP:    ip.GetClientIP(c),
+		UserAgent:   c.GetHeader("User-Agent"),
+		APIKeyID:    apiKey.ID,
+		AffinityKey: h.gatewayService.ClientSessionAffinityKey(c),
 	}
 	sessionHash := h.gatewayService.GenerateSessionHash(parsedReq)
 
@@ -162,7 +187,7 @@ func (h *GatewayHandler) Responses(c *gin.Context) {
 		selection, err := h.gatewayService.SelectAccountWithLoadAwareness(c.Request.Context(), apiKey.GroupID, sessionHash, reqModel, fs.FailedAccountIDs, "")
 		if err != nil {
 			if len(fs.FailedAccountIDs) == 0 {
-				h.responsesErrorResponse(c, http.StatusServiceUnavailable, "api_error", "No available accounts: "+err.Error())
+				ep.errorResponse(c, http.StatusServiceUnavailable, "api_error", "No available accounts: "+err.Error())
 				return
 			}
 			action := fs.HandleSelectionExhausted(c.Request.Context())
@@ -173,9 +198,9 @@ func (h *GatewayHandler) Responses(c *gin.Context) {
 				return
 			default:
 				if fs.LastFailoverErr != nil {
-					h.handleResponsesFailoverExhausted(c, fs.LastFailoverErr, streamStarted)
+					h.handleAnthropicCompatFailoverExhausted(c, ep, fs.LastFailoverErr, streamStarted)
 				} else {
-					h.responsesErrorResponse(c, http.StatusBadGateway, "server_error", "All available accounts exhausted")
+					ep.errorResponse(c, http.StatusBadGateway, "server_error", "All available accounts exhausted")
 				}
 				return
 			}
@@ -187,7 +212,7 @@ func (h *GatewayHandler) Responses(c *gin.Context) {
 		accountReleaseFunc := selection.ReleaseFunc
 		if !selection.Acquired {
 			if selection.WaitPlan == nil {
-				h.responsesErrorResponse(c, http.StatusServiceUnavailable, "api_error", "No available accounts")
+				ep.errorResponse(c, http.StatusServiceUnavailable, "api_error", "No available accounts")
 				return
 			}
 			accountReleaseFunc, err = h.concurrencyHelper.AcquireAccountSlotWithWaitTimeout(
@@ -199,7 +224,7 @@ func (h *GatewayHandler) Responses(c *gin.Context) {
 				&streamStarted,
 			)
 			if err != nil {
-				reqLog.Warn("gateway.responses.account_slot_acquire_failed", zap.Int64("account_id", account.ID), zap.Error(err))
+				reqLog.Warn("gateway."+ep.name+".account_slot_acquire_failed", zap.Int64("account_id", account.ID), zap.Error(err))
 				h.handleConcurrencyError(c, err, "account", streamStarted)
 				return
 			}
@@ -208,7 +233,7 @@ func (h *GatewayHandler) Responses(c *gin.Context) {
 
 		// 5. Forward request
 		writerSizeBeforeForward := c.Writer.Size()
-		result, err := h.gatewayService.ForwardAsResponses(c.Request.Context(), c, account, body, parsedReq)
+		result, err := ep.forward(c.Request.Context(), c, account, body, parsedReq)
 
 		if accountReleaseFunc != nil {
 			accountReleaseFunc()
@@ -219,7 +244,7 @@ func (h *GatewayHandler) Responses(c *gin.Context) {
 			if errors.As(err, &failoverErr) {
 				// Can't failover if streaming content already sent
 				if c.Writer.Size() != writerSizeBeforeForward {
-					h.handleResponsesFailoverExhausted(c, failoverErr, true)
+					h.handleAnthropicCompatFailoverExhausted(c, ep, failoverErr, true)
 					return
 				}
 				action := fs.HandleFailoverError(c.Request.Context(), h.gatewayService, account.ID, account.Platform, failoverErr)
@@ -227,14 +252,14 @@ func (h *GatewayHandler) Responses(c *gin.Context) {
 				case FailoverContinue:
 					continue
 				case FailoverExhausted:
-					h.handleResponsesFailoverExhausted(c, fs.LastFailoverErr, streamStarted)
+					h.handleAnthropicCompatFailoverExhausted(c, ep, fs.LastFailoverErr, streamStarted)
 					return
 				case FailoverCanceled:
 					return
 				}
 			}
 			h.ensureForwardErrorResponse(c, streamStarted)
-			reqLog.Error("gateway.responses.forward_failed",
+			reqLog.Error("gateway."+ep.name+".forward_failed",
 				zap.Int64("account_id", account.ID),
 				zap.Error(err),
 			)
@@ -248,7 +273,7 @@ func (h *GatewayHandler) Responses(c *gin.Context) {
 		inboundEndpoint := GetInboundEndpoint(c)
 		upstreamEndpoint := GetUpstreamEndpoint(c, account.Platform)
 
-		h.submitUsageRecordTask(func(ctx context.Context) {
+		h.submitUsageRecordTask(c, func(ctx context.Context) {
 			if err := h.gatewayService.RecordUsage(ctx, &service.RecordUsageInput{
 				Result:             result,
 				APIKey:             apiKey,
@@ -262,7 +287,7 @@ func (h *GatewayHandler) Responses(c *gin.Context) {
 				RequestPayloadHash: requestPayloadHash,
 				APIKeyService:      h.apiKeyService,
 			}); err != nil {
-				reqLog.Error("gateway.responses.record_usage_failed",
+				reqLog.Error("gateway."+ep.name+".record_usage_failed",
 					zap.Int64("account_id", account.ID),
 					zap.Error(err),
 				)
@@ -282,8 +307,9 @@ func (h *GatewayHandler) responsesErrorResponse(c *gin.Context, status int, code
 	})
 }
 
-// handleResponsesFailoverExhausted writes a failover-exhausted error in Responses format.
-func (h *GatewayHandler) handleResponsesFailoverExhausted(c *gin.Context, lastErr *service.UpstreamFailoverError, streamStarted bool) {
+// handleAnthropicCompatFailoverExhausted writes a failover-exhausted error in the endpoint's format.
+func (h *GatewayHandler) handleAnthropicCompatFailoverExhausted(c *gin.Context, ep anthropicCompatEndpoint, lastErr *service.UpstreamFailoverError, streamStarted bool) {
+	setUpstreamRetryAfter(c, lastErr)
 	if streamStarted {
 		return // Can't write error after stream started
 	}
@@ -291,5 +317,5 @@ func (h *GatewayHandler) handleResponsesFailoverExhausted(c *gin.Context, lastEr
 	if lastErr != nil && lastErr.StatusCode > 0 {
 		statusCode = lastErr.StatusCode
 	}
-	h.responsesErrorResponse(c, statusCode, "server_error", "All available accounts exhausted")
+	ep.errorResponse(c, statusCode, "server_error", "All available accounts exhausted")
 }
@@ -4,8 +4,10 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"math"
 	"math/rand/v2"
 	"net/http"
+	"strconv"
 	"strings"
 	"sync"
 	"time"
@@ -398,3 +400,22 @@ func nextBackoff(current time.Duration) time.Duration {
 	}
 	return jittered
 }
+
+// setUpstreamRetryAfter sets Retry-After on an upstream-429 failover response
+// from the upstream rate-limit window, so SDKs back off instead of retrying
+// immediately. A longer Retry-After already set (e.g. by the per-key limiter)
+// is kept.
+func setUpstreamRetryAfter(c *gin.Context, failoverErr *service.UpstreamFailoverError) {
+	if c == nil || failoverErr == nil || failoverErr.StatusCode != http.StatusTooManyRequests || c.Writer.Written() {
+		return
+	}
+	d, ok := service.UpstreamRetryAfter(failoverErr.ResponseHeaders, time.Now())
+	if !ok {
+		return
+	}
+	secs := int64(math.Ceil(d.Seconds()))
+	if existing, err := strconv.ParseInt(c.Writer.Header().Get("Retry-After"), 10, 64); err == nil && existing >= secs {
+		return
+	}
+	c.Header("Retry-After", strconv.FormatInt(secs, 10))
+}
@@ -0,0 +1,158 @@
+package handler
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/ShaohongDong/sub2api/internal/pkg/ctxkey"
+	"github.com/ShaohongDong/sub2api/internal/pkg/hooks"
+	pkghttputil "github.com/ShaohongDong/sub2api/internal/pkg/httputil"
+	middleware2 "github.com/ShaohongDong/sub2api/internal/server/middleware"
+
+	"github.com/gin-gonic/gin"
+	"github.com/tidwall/gjson"
+)
+
+// GatewayHooksHandler runs the configured request hooks (gateway.hooks) at
+// the pre_auth, pre_translation and post_response extension points. The
+// pre_upstream stage runs inside the HTTP upstream client.
+type GatewayHooksHandler struct {
+	runner *hooks.Runner
+}
+
+// NewGatewayHooksHandler creates a new GatewayHooksHandler
+func NewGatewayHooksHandler(runner *hooks.Runner) *GatewayHooksHandler {
+	return &GatewayHooksHandler{runner: runner}
+}
+
+// PreAuth runs pre_auth hooks on the raw client request, before API key
+// authentication. Credentials are redacted before the request is handed to
+// webhook or script hooks.
+func (h *GatewayHooksHandler) PreAuth(c *gin.Context) {
+	if h == nil || !h.runner.Has(hooks.StagePreAuth) {
+		c.Next()
+		return
+	}
+	req, ok := h.readRequest(c, hooks.StagePreAuth)
+	if !ok {
+		return
+	}
+	if h.apply(c, h.runner.Run(c.Request.Context(), req)) {
+		c.Next()
+	}
+}
+
+// Middleware runs pre_translation hooks after authentication, attaches the
+// request metadata used by pre_upstream hooks, and reports the finished
+// request to post_response hooks.
+func (h *GatewayHooksHandler) Middleware(c *gin.Context) {
+	if h == nil || (!h.runner.Has(hooks.StagePreTranslation) && !h.runner.Has(hooks.StagePreUpstream) && !h.runner.Has(hooks.StagePostResponse)) {
+		c.Next()
+		return
+	}
+	start := time.Now()
+	info := &hooks.Info{Path: c.Request.URL.Path}
+	if v, ok := c.Request.Context().Value(ctxkey.RequestID).(string); ok {
+		info.RequestID = v
+	}
+	if apiKey, ok := middleware2.GetAPIKeyFromContext(c); ok && apiKey != nil {
+		info.APIKeyID = apiKey.ID
+		info.UserID = apiKey.UserID
+		if apiKey.GroupID != nil {
+			info.GroupID = *apiKey.GroupID
+		}
+		if apiKey.Group != nil {
+			info.Platform = apiKey.Group.Platform
+		}
+	}
+
+	var body []byte
+	if h.runner.Has(hooks.StagePreTranslation) || h.runner.Has(hooks.StagePostResponse) {
+		req, ok := h.readRequest(c, hooks.StagePreTranslation)
+		if !ok {
+			return
+		}
+		body = []byte(req.Body)
+		info.Model = gjson.GetBytes(body, "model").String()
+		if h.runner.Has(hooks.StagePreTranslation) {
+			req.RequestID, req.APIKeyID, req.UserID, req.GroupID, req.Platform, req.Model =
+				info.RequestID, info.APIKeyID, info.UserID, info.GroupID, info.Platform, info.Model
+			out := h.runner.Run(c.Request.Context(), req)
+			if !h.apply(c, out) {
+				return
+			}
+			if out.BodyChanged {
+				body = out.Body
+				info.Model = gjson.GetBytes(body, "model").String()
+			}
+		}
+	}
+	c.Request = c.Request.WithContext(hooks.WithInfo(c.Request.Context(), info))
+
+	c.Next()
+
+	if h.runner.Has(hooks.StagePostResponse) {
+		h.runner.RunAsync(&hooks.Request{
+			Stage:      hooks.StagePostResponse,
+			RequestID:  info.RequestID,
+			Method:     c.Request.Method,
+			Path:       info.Path,
+			Headers:    hooks.HeaderMap(c.Request.Header),
+			Body:       string(body),
+			APIKeyID:   info.APIKeyID,
+			UserID:     info.UserID,
+			GroupID:    info.GroupID,
+			Platform:   info.Platform,
+			Model:      info.Model,
+			StatusCode: c.Writer.Status(),
+			DurationMs: time.Since(start).Milliseconds(),
+		})
+	}
+}
+
+// readRequest buffers the body (restoring it for downstream handlers) and
+// describes the request for the given stage.
+func (h *GatewayHooksHandler) readRequest(c *gin.Context, stage hooks.Stage) (*hooks.Request, bool) {
+	var body []byte
+	if c.Request.Body != nil && c.Request.Body != http.NoBody {
+		var err error
+		body, err = pkghttputil.ReadRequestBodyWithPrealloc(c.Request)
+		if err != nil {
+			if maxErr, ok := extractMaxBytesError(err); ok {
+				abortModerationError(c, http.StatusRequestEntityTooLarge, "invalid_request_error", buildBodyTooLargeMessage(maxErr.Limit))
+				return nil, false
+			}
+			abortModerationError(c, http.StatusBadRequest, "invalid_request_error", "Failed to read request body")
+			return nil, false
+		}
+		c.Request.Body = io.NopCloser(bytes.NewReader(body))
+	}
+	req := &hooks.Request{
+		Stage:   stage,
+		Method:  c.Request.Method,
+		Path:    c.Request.URL.Path,
+		Headers: hooks.HeaderMap(c.Request.Header),
+		Body:    string(body),
+	}
+	if v, ok := c.Request.Context().Value(ctxkey.RequestID).(string); ok {
+		req.RequestID = v
+	}
+	return req, true
+}
+
+// apply writes the hook outcome to the request, or aborts with the rejection
+// in the inbound protocol's error format. It reports whether to continue.
+func (h *GatewayHooksHandler) apply(c *gin.Context, out *hooks.Outcome) bool {
+	if out.Rejected != nil {
+		abortModerationError(c, out.Rejected.Status, "request_rejected", out.Rejected.Message)
+		return false
+	}
+	out.ApplyHeaders(c.Request.Header)
+	if out.BodyChanged {
+		c.Request.Body = io.NopCloser(bytes.NewReader(out.Body))
+		c.Request.ContentLength = int64(len(out.Body))
+	}
+	return true
+}
@@ -0,0 +1,114 @@
+package handler
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/ShaohongDong/sub2api/internal/pkg/hooks"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/require"
+	"github.com/tidwall/gjson"
+)
+
+func init() {
+	hooks.Register("handler-test-policy", func(map[string]any) (hooks.Hook, error) {
+		return hooks.HookFunc(func(ctx context.Context, req *hooks.Request) (*hooks.Decision, error) {
+			if req.Headers["X-Block"] != "" {
+				return &hooks.Decision{Reject: true, Status: http.StatusTooManyRequests, Message: "blocked by hook"}, nil
+			}
+			if req.Stage == hooks.StagePreTranslation {
+				body := strings.Replace(req.Body, `"model":"alias"`, `"model":"claude-sonnet-4"`, 1)
+				return &hooks.Decision{SetHeaders: map[string]string{"X-Tenant": "t1"}, RemoveHeaders: []string{"X-Debug"}, Body: &body}, nil
+			}
+			return nil, nil
+		}), nil
+	})
+}
+
+func newHooksTestRouter(t *testing.T, stages ...hooks.Stage) (*gin.Engine, *hooksTestCapture) {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+
+	runner, err := hooks.NewRunner([]hooks.Spec{{Name: "policy", Type: hooks.TypeGo, Plugin: "handler-test-policy", Stages: stages}}, nil)
+	require.NoError(t, err)
+	h := NewGatewayHooksHandler(runner)
+
+	captured := &hooksTestCapture{}
+	router := gin.New()
+	router.POST("/v1/messages", h.PreAuth, h.Middleware, func(c *gin.Context) {
+		b, _ := io.ReadAll(c.Request.Body)
+		captured.body = string(b)
+		captured.header = c.Request.Header.Clone()
+		captured.info = hooks.InfoFromContext(c.Request.Context())
+		c.Status(http.StatusOK)
+	})
+	return router, captured
+}
+
+type hooksTestCapture struct {
+	body   string
+	header http.Header
+	info   *hooks.Info
+}
+
+func TestGatewayHooksHandlerPreAuthReject(t *testing.T) {
+	router, captured := newHooksTestRouter(t, hooks.StagePreAuth)
+
+	req := httptest.NewRequest(http.MethodPost, "/v1/messages", strings.NewReader(`{"model":"alias"}`))
+	req.Header.Set("X-Block", "1")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	require.Equal(t, http.StatusTooManyRequests, w.Code)
+	require.Equal(t, "blocked by hook", gjson.Get(w.Body.String(), "error.message").String())
+	require.Empty(t, captured.body)
+
+	req = httptest.NewRequest(http.MethodPost, "/v1/messages", strings.NewReader(`{"model":"alias"}`))
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	require.Equal(t, http.StatusOK, w.Code)
+	require.Equal(t, `{"model":"alias"}`, captured.body)
+}
+
+func TestGatewayHooksHandlerPreTranslationRewrite(t *testing.T) {
+	router, captured := newHooksTestRouter(t, hooks.StagePreTranslation)
+
+	req := httptest.NewRequest(http.MethodPost, "/v1/messages", strings.NewReader(`{"model":"alias"}`))
+	req.Header.Set("X-Debug", "1")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	require.Equal(t, http.StatusOK, w.Code)
+	require.Equal(t, `{"model":"claude-sonnet-4"}`, captured.body)
+	require.Equal(t, "t1", captured.header.Get("X-Tenant"))
+	require.Empty(t, captured.header.Get("X-Debug"))
+	require.NotNil(t, captured.info)
+	require.Equal(t, "claude-sonnet-4", captured.info.Model)
+	require.Equal(t, "/v1/messages", captured.info.Path)
+}
+
+func TestGatewayHooksHandlerNilPassThrough(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	for _, h := range []*GatewayHooksHandler{nil, NewGatewayHooksHandler(nil)} {
+		var gotBody string
+		var info *hooks.Info
+		router := gin.New()
+		router.POST("/v1/messages", h.PreAuth, h.Middleware, func(c *gin.Context) {
+			b, _ := io.ReadAll(c.Request.Body)
+			gotBody = string(b)
+			info = hooks.InfoFromContext(c.Request.Context())
+			c.Status(http.StatusOK)
+		})
+
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/v1/messages", strings.NewReader(`{"model":"x"}`)))
+		require.Equal(t, http.StatusOK, w.Code)
+		require.Equal(t, `{"model":"x"}`, gotBody)
+		require.Nil(t, info)
+	}
+}
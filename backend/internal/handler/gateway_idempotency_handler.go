@@ -0,0 +1,189 @@
+package handler
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ShaohongDong/sub2api/internal/config"
+	infraerrors "github.com/ShaohongDong/sub2api/internal/pkg/errors"
+	"github.com/ShaohongDong/sub2api/internal/pkg/logger"
+	middleware2 "github.com/ShaohongDong/sub2api/internal/server/middleware"
+	"github.com/ShaohongDong/sub2api/internal/service"
+
+	"github.com/gin-gonic/gin"
+	"github.com/tidwall/gjson"
+	"go.uber.org/zap"
+)
+
+const gatewayIdempotencyScope = "gateway.inference"
+
+// errGatewayResponseNotStored marks a gateway response that was sent to the
+// client but not kept for replay (error status, streamed or too large), so
+// the idempotency record is released for a later retry.
+var errGatewayResponseNotStored = infraerrors.New(http.StatusBadGateway, "GATEWAY_RESPONSE_NOT_STORED", "gateway response not stored")
+
+// gatewayIdempotentResponse is the response kept for replaying a repeated
+// Idempotency-Key. Body is base64 encoded by encoding/json so the upstream
+// bytes are replayed unchanged.
+type gatewayIdempotentResponse struct {
+	StatusCode  int    `json:"status_code"`
+	ContentType string `json:"content_type"`
+	Body        []byte `json:"body"`
+}
+
+// GatewayIdempotencyHandler honors the Idempotency-Key header on non-streaming
+// inference requests (idempotency.gateway). The first request with a key runs
+// normally and its successful response is stored; repeats within the TTL get
+// the stored response without reaching an upstream account, and a repeat
+// arriving while the first is still running gets 409 with Retry-After.
+type GatewayIdempotencyHandler struct {
+	coordinator *service.IdempotencyCoordinator
+	cfg         config.GatewayIdempotencyConfig
+}
+
+// NewGatewayIdempotencyHandler creates a new GatewayIdempotencyHandler
+func NewGatewayIdempotencyHandler(coordinator *service.IdempotencyCoordinator, cfg *config.Config) *GatewayIdempotencyHandler {
+	h := &GatewayIdempotencyHandler{coordinator: coordinator}
+	if cfg != nil {
+		h.cfg = cfg.Idempotency.Gateway
+	}
+	return h
+}
+
+// Middleware returns the idempotency middleware for a route group. It runs
+// after API key authentication, since keys are scoped per API key, and
+// before the priority queue so replays skip it. writeError formats rejected
+// requests for the inbound protocol.
+func (h *GatewayIdempotencyHandler) Middleware(writeError middleware2.GatewayErrorWriter) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if h == nil || h.coordinator == nil || !h.cfg.Enabled || c.Request.Method != http.MethodPost || c.Request.Body == nil {
+			c.Next()
+			return
+		}
+		rawKey := c.GetHeader("Idempotency-Key")
+		if strings.TrimSpace(rawKey) == "" {
+			c.Next()
+			return
+		}
+		apiKey, ok := middleware2.GetAPIKeyFromContext(c)
+		if !ok || apiKey == nil {
+			c.Next()
+			return
+		}
+		body, err := io.ReadAll(c.Request.Body)
+		_ = c.Request.Body.Close()
+		c.Request.Body = io.NopCloser(bytes.NewReader(body))
+		if err != nil || isStreamingGatewayRequest(c, body) {
+			c.Next()
+			return
+		}
+
+		sum := sha256.Sum256(body)
+		limit := h.cfg.MaxResponseBytes
+		var w *captureResponseWriter
+		result, err := h.coordinator.Execute(c.Request.Context(), service.IdempotencyExecuteOptions{
+			Scope:          gatewayIdempotencyScope,
+			ActorScope:     "api_key:" + strconv.FormatInt(apiKey.ID, 10),
+			Method:         c.Request.Method,
+			Route:          c.Request.URL.Path,
+			IdempotencyKey: rawKey,
+			Payload:        hex.EncodeToString(sum[:]),
+			TTL:            time.Duration(h.cfg.TTLSeconds) * time.Second,
+			// base64 body plus the JSON envelope
+			MaxStoredResponseLen: limit/3*4 + 1024,
+		}, func(ctx context.Context) (any, error) {
+			w = &captureResponseWriter{ResponseWriter: c.Writer, limit: limit}
+			c.Writer = w
+			c.Next()
+			c.Writer = w.ResponseWriter
+
+			status := c.Writer.Status()
+			contentType := c.Writer.Header().Get("Content-Type")
+			if status < 200 || status >= 300 || w.size > limit || strings.HasPrefix(contentType, "text/event-stream") {
+				return nil, errGatewayResponseNotStored
+			}
+			return &gatewayIdempotentResponse{StatusCode: status, ContentType: contentType, Body: w.body.Bytes()}, nil
+		})
+		if w != nil {
+			// The handler ran and the client already has its response; a
+			// failure here only means it was not stored for replay.
+			if err != nil && !errors.Is(err, errGatewayResponseNotStored) {
+				logger.FromContext(c.Request.Context()).Warn("gateway.idempotency_store_failed",
+					zap.Int64("api_key_id", apiKey.ID),
+					zap.String("path", c.Request.URL.Path),
+					zap.Error(err),
+				)
+			}
+			return
+		}
+		if err != nil {
+			if retryAfter := service.RetryAfterSecondsFromError(err); retryAfter > 0 {
+				c.Header("Retry-After", strconv.Itoa(retryAfter))
+			}
+			writeError(c, infraerrors.Code(err), infraerrors.Message(err))
+			c.Abort()
+			return
+		}
+
+		stored, err := decodeGatewayIdempotentResponse(result.Data)
+		if err != nil {
+			logger.FromContext(c.Request.Context()).Warn("gateway.idempotency_replay_failed", zap.Error(err))
+			writeError(c, http.StatusServiceUnavailable, infraerrors.Message(service.ErrIdempotencyStoreUnavail))
+			c.Abort()
+			return
+		}
+		c.Header("X-Idempotency-Replayed", "true")
+		c.Data(stored.StatusCode, stored.ContentType, stored.Body)
+		c.Abort()
+	}
+}
+
+// decodeGatewayIdempotentResponse converts a replayed record, which the
+// coordinator returns as generic JSON, back into the stored response.
+func decodeGatewayIdempotentResponse(data any) (*gatewayIdempotentResponse, error) {
+	raw, err := json.Marshal(data)
+	if err != nil {
+		return nil, err
+	}
+	var stored gatewayIdempotentResponse
+	if err := json.Unmarshal(raw, &stored); err != nil {
+		return nil, err
+	}
+	if stored.StatusCode == 0 {
+		stored.StatusCode = http.StatusOK
+	}
+	return &stored, nil
+}
+
+// isStreamingGatewayRequest reports whether the request asks for a streamed
+// response, which cannot be replayed: "stream": true in the body, Gemini's
+// streamGenerateContent / alt=sse, or Bedrock's invoke-with-response-stream.
+func isStreamingGatewayRequest(c *gin.Context, body []byte) bool {
+	path := c.Request.URL.Path
+	return gjson.GetBytes(body, "stream").Bool() ||
+		strings.Contains(path, "streamGenerateContent") ||
+		strings.HasSuffix(path, "/invoke-with-response-stream") ||
+		c.Query("alt") == "sse"
+}
+
+// IdempotencyErrorWriter writes idempotency rejections in the Anthropic error
+// format, which OpenAI clients can also parse through error.message.
+func IdempotencyErrorWriter(c *gin.Context, status int, message string) {
+	errType := "invalid_request_error"
+	if status >= 500 {
+		errType = "api_error"
+	}
+	c.JSON(status, gin.H{
+		"type":  "error",
+		"error": gin.H{"type": errType, "message": message},
+	})
+}
@@ -0,0 +1,134 @@
+package handler
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/ShaohongDong/sub2api/internal/config"
+	middleware2 "github.com/ShaohongDong/sub2api/internal/server/middleware"
+	"github.com/ShaohongDong/sub2api/internal/service"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/require"
+)
+
+func newGatewayIdempotencyTestRouter(t *testing.T, handlerFn gin.HandlerFunc) *gin.Engine {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+	coordinator := service.NewIdempotencyCoordinator(newUserMemoryIdempotencyRepoStub(), service.IdempotencyConfig{
+		DefaultTTL:           time.Hour,
+		ProcessingTimeout:    30 * time.Second,
+		FailedRetryBackoff:   time.Millisecond,
+		MaxStoredResponseLen: 64,
+	})
+	h := NewGatewayIdempotencyHandler(coordinator, &config.Config{Idempotency: config.IdempotencyConfig{
+		Gateway: config.GatewayIdempotencyConfig{Enabled: true, TTLSeconds: 600, MaxResponseBytes: 1 << 10},
+	}})
+
+	r := gin.New()
+	r.Use(func(c *gin.Context) {
+		c.Set(string(middleware2.ContextKeyAPIKey), &service.APIKey{ID: 2, UserID: 3})
+		c.Next()
+	})
+	r.POST("/v1/messages", h.Middleware(IdempotencyErrorWriter), handlerFn)
+	return r
+}
+
+func doGatewayIdempotencyRequest(r http.Handler, key, body string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodPost, "/v1/messages", strings.NewReader(body))
+	if key != "" {
+		req.Header.Set("Idempotency-Key", key)
+	}
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	return w
+}
+
+func TestGatewayIdempotency_ReplaysStoredResponse(t *testing.T) {
+	var calls atomic.Int32
+	// 响应体超过全局 max_stored_response_len（64）也能完整重放
+	reply := `{"id":"msg_1","content":[{"type":"text","text":"` + strings.Repeat("a", 200) + `"}]}`
+	r := newGatewayIdempotencyTestRouter(t, func(c *gin.Context) {
+		calls.Add(1)
+		c.Data(http.StatusOK, "application/json", []byte(reply))
+	})
+
+	body := `{"model":"claude-sonnet-4","messages":[]}`
+	first := doGatewayIdempotencyRequest(r, "key-1", body)
+	require.Equal(t, http.StatusOK, first.Code)
+	require.Equal(t, reply, first.Body.String())
+	require.Empty(t, first.Header().Get("X-Idempotency-Replayed"))
+
+	second := doGatewayIdempotencyRequest(r, "key-1", body)
+	require.Equal(t, http.StatusOK, second.Code)
+	require.Equal(t, reply, second.Body.String())
+	require.Equal(t, "application/json", second.Header().Get("Content-Type"))
+	require.Equal(t, "true", second.Header().Get("X-Idempotency-Replayed"))
+	require.Equal(t, int32(1), calls.Load())
+
+	// 同一个 key 换了请求体：拒绝而不是重放
+	conflict := doGatewayIdempotencyRequest(r, "key-1", `{"model":"claude-opus-4","messages":[]}`)
+	require.Equal(t, http.StatusConflict, conflict.Code)
+	require.Contains(t, conflict.Body.String(), "invalid_request_error")
+
+	// 未携带 key 时照常执行
+	require.Equal(t, http.StatusOK, doGatewayIdempotencyRequest(r, "", body).Code)
+	require.Equal(t, int32(2), calls.Load())
+}
+
+func TestGatewayIdempotency_RejectsConcurrentDuplicate(t *testing.T) {
+	started := make(chan struct{})
+	release := make(chan struct{})
+	r := newGatewayIdempotencyTestRouter(t, func(c *gin.Context) {
+		close(started)
+		<-release
+		c.JSON(http.StatusOK, gin.H{"id": "msg_1"})
+	})
+
+	body := `{"model":"claude-sonnet-4","messages":[]}`
+	done := make(chan *httptest.ResponseRecorder)
+	go func() { done <- doGatewayIdempotencyRequest(r, "key-1", body) }()
+	<-started
+
+	dup := doGatewayIdempotencyRequest(r, "key-1", body)
+	require.Equal(t, http.StatusConflict, dup.Code)
+	require.NotEmpty(t, dup.Header().Get("Retry-After"))
+
+	close(release)
+	require.Equal(t, http.StatusOK, (<-done).Code)
+}
+
+func TestGatewayIdempotency_DoesNotStoreFailuresOrStreams(t *testing.T) {
+	var calls atomic.Int32
+	r := newGatewayIdempotencyTestRouter(t, func(c *gin.Context) {
+		if calls.Add(1) == 1 {
+			c.JSON(http.StatusBadGateway, gin.H{"error": "upstream"})
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{"id": "msg_1"})
+	})
+
+	body := `{"model":"claude-sonnet-4","messages":[]}`
+	require.Equal(t, http.StatusBadGateway, doGatewayIdempotencyRequest(r, "key-1", body).Code)
+	time.Sleep(5 * time.Millisecond) // failed_retry_backoff
+	retry := doGatewayIdempotencyRequest(r, "key-1", body)
+	require.Equal(t, http.StatusOK, retry.Code)
+	require.Empty(t, retry.Header().Get("X-Idempotency-Replayed"))
+	require.Equal(t, int32(2), calls.Load())
+
+	stream := `{"model":"claude-sonnet-4","stream":true,"messages":[]}`
+	require.Equal(t, http.StatusOK, doGatewayIdempotencyRequest(r, "key-2", stream).Code)
+	require.Equal(t, http.StatusOK, doGatewayIdempotencyRequest(r, "key-2", stream).Code)
+	require.Equal(t, int32(4), calls.Load())
+}
+
+func TestGatewayIdempotency_NilHandlerPassesThrough(t *testing.T) {
+	var h *GatewayIdempotencyHandler
+	c, _ := gin.CreateTestContext(httptest.NewRecorder())
+	c.Request = httptest.NewRequest(http.MethodPost, "/v1/messages", strings.NewReader(`{}`))
+	c.Request.Header.Set("Idempotency-Key", "key-1")
+	require.NotPanics(t, func() { h.Middleware(IdempotencyErrorWriter)(c) })
+}
@@ -47,7 +47,9 @@ func (h *GatewayHandler) GeminiV1BetaListModels(c *gin.Context) {
 
 	// 强制 antigravity 模式：返回 antigravity 支持的模型列表
 	if forcePlatform == service.PlatformAntigravity {
-		c.JSON(http.StatusOK, antigravity.FallbackGeminiModelsList())
+		list := antigravity.FallbackGeminiModelsList()
+		list.Models = filterModelsForAPIKey(apiKey, list.Models, func(m antigravity.GeminiModel) string { return m.Name })
+		c.JSON(http.StatusOK, list)
 		return
 	}
 
@@ -57,7 +59,7 @@ func (h *GatewayHandler) GeminiV1BetaListModels(c *gin.Context) {
 		hasAntigravity, _ := h.geminiCompatService.HasAntigravityAccounts(c.Request.Context(), apiKey.GroupID)
 		if hasAntigravity {
 			// antigravity 账户使用静态模型列表
-			c.JSON(http.StatusOK, gemini.FallbackModelsList())
+			c.JSON(http.StatusOK, filterGeminiFallbackModels(apiKey))
 			return
 		}
 		googleError(c, http.StatusServiceUnavailable, "No available Gemini accounts: "+err.Error())
@@ -70,9 +72,12 @@ func (h *GatewayHandler) GeminiV1BetaListModels(c *gin.Context) {
 		return
 	}
 	if shouldFallbackGeminiModels(res) {
-		c.JSON(http.StatusOK, gemini.FallbackModelsList())
+		c.JSON(http.StatusOK, filterGeminiFallbackModels(apiKey))
 		return
 	}
+	if res.StatusCode == http.StatusOK {
+		res.Body = filterGeminiModelsBody(apiKey, res.Body)
+	}
 	writeUpstreamResponse(c, res)
 }
 
@@ -96,6 +101,10 @@ func (h *GatewayHandler) GeminiV1BetaGetModel(c *gin.Context) {
 		googleError(c, http.StatusBadRequest, "Missing model in URL")
 		return
 	}
+	if !apiKey.AllowsModel(clientRequestedModel(c, modelName)) {
+		googleError(c, http.StatusForbidden, apiKeyModelNotAllowedMessage(clientRequestedModel(c, modelName)))
+		return
+	}
 
 	// 强制 antigravity 模式：返回 antigravity 模型信息
 	if forcePlatform == service.PlatformAntigravity {
@@ -183,6 +192,11 @@ func (h *GatewayHandler) GeminiV1BetaModels(c *gin.Context) {
 
 	setOpsRequestContext(c, modelName, stream, body)
 
+	if !apiKey.AllowsModel(clientRequestedModel(c, modelName)) {
+		googleError(c, http.StatusForbidden, apiKeyModelNotAllowedMessage(clientRequestedModel(c, modelName)))
+		return
+	}
+
 	// Get subscription (may be nil)
 	subscription, _ := middleware.GetSubscriptionFromContext(c)
 
@@ -246,9 +260,10 @@ func (h *GatewayHandler) GeminiV1BetaModels(c *gin.Context) {
 		parsedReq, _ := service.ParseGatewayRequest(body, domain.PlatformGemini)
 		if parsedReq != nil {
 			parsedReq.SessionContext = &service.SessionContext{
-				ClientIP:  ip.GetClientIP(c),
-				UserAgent: c.GetHeader("User-Agent"),
-				APIKeyID:  apiKey.ID,
+				ClientIP:    ip.GetClientIP(c),
+				UserAgent:   c.GetHeader("User-Agent"),
+				APIKeyID:    apiKey.ID,
+				AffinityKey: h.gatewayService.ClientSessionAffinityKey(c),
 			}
 		}
 		sessionHash = h.gatewayService.GenerateSessionHash(parsedReq)
@@ -503,7 +518,7 @@ func (h *GatewayHandler) GeminiV1BetaModels(c *gin.Context) {
 		}
 
 		// 使用量记录通过有界 worker 池提交，避免请求热路径创建无界 goroutine。
-		h.submitUsageRecordTask(func(ctx context.Context) {
+		h.submitUsageRecordTask(c, func(ctx context.Context) {
 			if err := h.gatewayService.RecordUsageWithLongContext(ctx, &service.RecordUsageLongContextInput{
 				Result:                result,
 				APIKey:                apiKey,
@@ -555,6 +570,7 @@ func parseGeminiModelAction(rest string) (model string, action string, err error
 }
 
 func (h *GatewayHandler) handleGeminiFailoverExhausted(c *gin.Context, failoverErr *service.UpstreamFailoverError) {
+	setUpstreamRetryAfter(c, failoverErr)
 	if failoverErr == nil {
 		googleError(c, http.StatusBadGateway, "Upstream request failed")
 		return
@@ -28,24 +28,50 @@ type AdminHandlers struct {
 	ErrorPassthrough *admin.ErrorPassthroughHandler
 	APIKey           *admin.AdminAPIKeyHandler
 	ScheduledTest    *admin.ScheduledTestHandler
+	AccountHealth    *admin.AccountHealthHandler
+	AccountValidate  *admin.AccountValidationHandler
+	UsageWindow      *admin.AccountUsageWindowHandler
+	Tenant           *admin.TenantHandler
+	AuditLog         *admin.AuditLogHandler
+	Backup           *admin.BackupHandler
+	RuntimeSettings  *admin.RuntimeSettingsHandler
+	DeadLetter       *admin.DeadLetterHandler
+	ContentLog       *admin.ContentLogHandler
+	Maintenance      *admin.MaintenanceHandler
 }
 
 // Handlers contains all HTTP handlers
 type Handlers struct {
-	Auth          *AuthHandler
-	User          *UserHandler
-	APIKey        *APIKeyHandler
-	Usage         *UsageHandler
-	Redeem        *RedeemHandler
-	Subscription  *SubscriptionHandler
-	Announcement  *AnnouncementHandler
-	Admin         *AdminHandlers
-	Gateway       *GatewayHandler
-	OpenAIGateway *OpenAIGatewayHandler
-	SoraGateway   *SoraGatewayHandler
-	SoraClient    *SoraClientHandler
-	Setting       *SettingHandler
-	Totp          *TotpHandler
+	Auth               *AuthHandler
+	User               *UserHandler
+	APIKey             *APIKeyHandler
+	Usage              *UsageHandler
+	Redeem             *RedeemHandler
+	Subscription       *SubscriptionHandler
+	Announcement       *AnnouncementHandler
+	Admin              *AdminHandlers
+	Gateway            *GatewayHandler
+	OpenAIGateway      *OpenAIGatewayHandler
+	SoraGateway        *SoraGatewayHandler
+	SoraClient         *SoraClientHandler
+	Setting            *SettingHandler
+	Totp               *TotpHandler
+	Batch              *BatchHandler
+	File               *FileHandler
+	BackgroundResponse *BackgroundResponseHandler
+	SSEReplay          *SSEReplayHandler
+	ResponseCache      *ResponseCacheHandler
+	ConversationStore  *ConversationStoreHandler
+	ParamSanitize      *ParamSanitizeHandler
+	ContextOverflow    *ContextOverflowHandler
+	ChatMultiChoice    *ChatMultiChoiceHandler
+	DeadLetter         *DeadLetterHandler
+	ContentLog         *ContentLogHandler
+	Idempotency        *GatewayIdempotencyHandler
+	Maintenance        *MaintenanceHandler
+	Hooks              *GatewayHooksHandler
+	Metrics            *MetricsHandler
+	Health             *HealthHandler
 }
 
 // BuildInfo contains build-time information
@@ -0,0 +1,83 @@
+package handler
+
+import (
+	"crypto/subtle"
+	"net/http"
+	"strings"
+
+	"github.com/ShaohongDong/sub2api/internal/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+// HealthHandler serves GET /health, GET /health/details and the /livez and
+// /readyz probes.
+type HealthHandler struct {
+	service *service.HealthService
+}
+
+// NewHealthHandler creates a new HealthHandler
+func NewHealthHandler(svc *service.HealthService) *HealthHandler {
+	return &HealthHandler{service: svc}
+}
+
+// DetailsEnabled reports whether /health/details should be registered.
+func (h *HealthHandler) DetailsEnabled() bool {
+	return h != nil && h.service.DetailsEnabled()
+}
+
+// Summary handles GET /health for load balancers: 200 while the datastores are
+// reachable, 503 otherwise. The body only carries the summarized status so it
+// is safe to expose publicly.
+func (h *HealthHandler) Summary(c *gin.Context) {
+	if h == nil || h.service == nil {
+		c.JSON(http.StatusOK, gin.H{"status": service.HealthStatusOK})
+		return
+	}
+	report := h.service.Check(c.Request.Context())
+	c.JSON(healthStatusCode(report), gin.H{"status": report.Status})
+}
+
+// Live handles GET /livez: 200 as long as the process serves HTTP. It never
+// touches the datastores, so an outage takes the instance out of rotation via
+// /readyz instead of getting it restarted.
+func (h *HealthHandler) Live(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{"status": service.HealthStatusOK})
+}
+
+// Ready handles GET /readyz: 503 while a datastore is unreachable, no account
+// is healthy, or the instance is shutting down.
+func (h *HealthHandler) Ready(c *gin.Context) {
+	if h == nil || h.service == nil {
+		c.JSON(http.StatusOK, gin.H{"status": "ready"})
+		return
+	}
+	report := h.service.Ready(c.Request.Context())
+	if !report.Ready {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"status": "not_ready", "reason": report.Reason})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"status": "ready"})
+}
+
+// Details handles GET /health/details with datastore latency, per-account
+// state and queue depth for operators.
+func (h *HealthHandler) Details(c *gin.Context) {
+	if token := h.service.AuthToken(); token != "" {
+		provided := strings.TrimSpace(strings.TrimPrefix(c.GetHeader("Authorization"), "Bearer "))
+		if subtle.ConstantTimeCompare([]byte(provided), []byte(token)) != 1 {
+			c.AbortWithStatus(http.StatusUnauthorized)
+			return
+		}
+	}
+	report := h.service.CheckDetails(c.Request.Context())
+	c.JSON(healthStatusCode(report), report)
+}
+
+// healthStatusCode degraded 仍返回 200：实例本身可服务，只是当前没有可调度账号
+func healthStatusCode(report *service.HealthReport) int {
+	if report.Status == service.HealthStatusUnhealthy {
+		return http.StatusServiceUnavailable
+	}
+	return http.StatusOK
+}
@@ -0,0 +1,103 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/ShaohongDong/sub2api/internal/config"
+	"github.com/ShaohongDong/sub2api/internal/service"
+	"github.com/gin-gonic/gin"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/require"
+)
+
+func newHealthTestRouter(t *testing.T, token string) *gin.Engine {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+	db, mock, err := sqlmock.New(sqlmock.MonitorPingsOption(true))
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = db.Close() })
+	mock.ExpectPing()
+	rdb := redis.NewClient(&redis.Options{Addr: "127.0.0.1:1", DialTimeout: 100 * time.Millisecond, MaxRetries: -1})
+	t.Cleanup(func() { _ = rdb.Close() })
+
+	cfg := &config.Config{Health: config.HealthConfig{DetailsEnabled: true, AuthToken: token, CacheSeconds: 5, CheckTimeoutSeconds: 1}}
+	h := NewHealthHandler(service.NewHealthService(cfg, db, rdb, metricsHandlerAccountRepoStub{}, nil))
+
+	r := gin.New()
+	r.GET("/health", h.Summary)
+	r.GET("/health/details", h.Details)
+	return r
+}
+
+func TestHealthHandlerSummaryReturns503WhenDatastoreDown(t *testing.T) {
+	router := newHealthTestRouter(t, "")
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/health", nil))
+	require.Equal(t, http.StatusServiceUnavailable, w.Code)
+	// 汇总接口只暴露状态，不包含依赖错误详情
+	require.JSONEq(t, `{"status":"unhealthy"}`, w.Body.String())
+}
+
+func TestHealthHandlerDetailsRequiresBearerToken(t *testing.T) {
+	router := newHealthTestRouter(t, "health-secret")
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/health/details", nil))
+	require.Equal(t, http.StatusUnauthorized, w.Code)
+
+	req := httptest.NewRequest(http.MethodGet, "/health/details", nil)
+	req.Header.Set("Authorization", "Bearer health-secret")
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	require.Equal(t, http.StatusServiceUnavailable, w.Code)
+
+	var report service.HealthReport
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &report))
+	require.Equal(t, service.HealthStatusOK, report.Database.Status)
+	require.Equal(t, service.HealthStatusUnhealthy, report.Redis.Status)
+	require.NotNil(t, report.Accounts)
+	require.Zero(t, report.Accounts.Total)
+}
+
+func TestHealthHandlerNilServiceReportsOK(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	var h *HealthHandler
+	r := gin.New()
+	r.GET("/health", h.Summary)
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/health", nil))
+	require.Equal(t, http.StatusOK, w.Code)
+	require.False(t, h.DetailsEnabled())
+}
+
+func TestHealthHandlerProbes(t *testing.T) {
+	router := newHealthTestRouter(t, "")
+	var h *HealthHandler
+	router.GET("/livez", h.Live)
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/livez", nil))
+	require.Equal(t, http.StatusOK, w.Code)
+	require.JSONEq(t, `{"status":"ok"}`, w.Body.String())
+
+	svc := service.NewHealthService(&config.Config{}, nil, nil, metricsHandlerAccountRepoStub{}, nil)
+	ready := NewHealthHandler(svc)
+	router.GET("/readyz", ready.Ready)
+
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/readyz", nil))
+	require.Equal(t, http.StatusServiceUnavailable, w.Code)
+	require.JSONEq(t, `{"status":"not_ready","reason":"datastore_unavailable"}`, w.Body.String())
+
+	svc.MarkDraining()
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/readyz", nil))
+	require.JSONEq(t, `{"status":"not_ready","reason":"draining"}`, w.Body.String())
+}
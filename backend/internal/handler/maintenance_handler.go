@@ -0,0 +1,57 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+
+	middleware2 "github.com/ShaohongDong/sub2api/internal/server/middleware"
+	"github.com/ShaohongDong/sub2api/internal/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+// MaintenanceHandler turns away new gateway requests while maintenance mode is
+// on, so the database can be migrated without cutting off running streams.
+type MaintenanceHandler struct {
+	service *service.MaintenanceService
+}
+
+// NewMaintenanceHandler creates a new MaintenanceHandler
+func NewMaintenanceHandler(svc *service.MaintenanceService) *MaintenanceHandler {
+	return &MaintenanceHandler{service: svc}
+}
+
+// Gate returns a middleware that answers new requests with 503 and
+// Retry-After while maintenance mode is on, formatted by writeError for the
+// inbound protocol. It runs before API key authentication so rejected
+// requests never touch the database. Requests already past the gate,
+// including open streams, are not affected.
+func (h *MaintenanceHandler) Gate(writeError middleware2.GatewayErrorWriter) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if h == nil {
+			c.Next()
+			return
+		}
+		st := h.service.Active()
+		if st == nil {
+			c.Next()
+			return
+		}
+		message := st.Message
+		if message == "" {
+			message = service.DefaultMaintenanceMessage
+		}
+		c.Header("Retry-After", strconv.Itoa(st.RetryAfterSeconds))
+		writeError(c, http.StatusServiceUnavailable, message)
+		c.Abort()
+	}
+}
+
+// MaintenanceErrorWriter writes the Anthropic-style overloaded error, which
+// Claude and OpenAI clients treat as retryable.
+func MaintenanceErrorWriter(c *gin.Context, status int, message string) {
+	c.JSON(status, gin.H{
+		"type":  "error",
+		"error": gin.H{"type": "overloaded_error", "message": message},
+	})
+}
@@ -0,0 +1,49 @@
+package handler
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	middleware2 "github.com/ShaohongDong/sub2api/internal/server/middleware"
+	"github.com/ShaohongDong/sub2api/internal/service"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMaintenanceGate(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	settings := &opsErrorLoggerSettingRepoStub{values: map[string]string{}}
+	svc := service.NewMaintenanceService(settings)
+	h := NewMaintenanceHandler(svc)
+
+	r := gin.New()
+	r.POST("/v1/messages", h.Gate(MaintenanceErrorWriter), func(c *gin.Context) { c.Status(http.StatusOK) })
+	r.POST("/v1beta/models/*action", h.Gate(middleware2.GoogleErrorWriter), func(c *gin.Context) { c.Status(http.StatusOK) })
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/v1/messages", nil))
+	require.Equal(t, http.StatusOK, w.Code)
+
+	_, err := svc.Update(context.Background(), &service.MaintenanceState{Enabled: true, RetryAfterSeconds: 90}, 1)
+	require.NoError(t, err)
+
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/v1/messages", nil))
+	require.Equal(t, http.StatusServiceUnavailable, w.Code)
+	require.Equal(t, "90", w.Header().Get("Retry-After"))
+	require.JSONEq(t, `{"type":"error","error":{"type":"overloaded_error","message":"`+service.DefaultMaintenanceMessage+`"}}`, w.Body.String())
+
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/v1beta/models/gemini:generateContent", nil))
+	require.Equal(t, http.StatusServiceUnavailable, w.Code)
+	require.Contains(t, w.Body.String(), `"code":503`)
+
+	// 未注入处理器时直接放行
+	var nilHandler *MaintenanceHandler
+	r.POST("/v1/other", nilHandler.Gate(MaintenanceErrorWriter), func(c *gin.Context) { c.Status(http.StatusOK) })
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/v1/other", nil))
+	require.Equal(t, http.StatusOK, w.Code)
+}
@@ -0,0 +1,98 @@
+package handler
+
+import (
+	"crypto/subtle"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/ShaohongDong/sub2api/internal/pkg/ctxkey"
+	"github.com/ShaohongDong/sub2api/internal/pkg/logger"
+	"github.com/ShaohongDong/sub2api/internal/pkg/promtext"
+	middleware2 "github.com/ShaohongDong/sub2api/internal/server/middleware"
+	"github.com/ShaohongDong/sub2api/internal/service"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+// MetricsHandler serves GET /metrics in the Prometheus text format and
+// records per-request gateway metrics.
+type MetricsHandler struct {
+	service *service.MetricsService
+}
+
+// NewMetricsHandler creates a new MetricsHandler
+func NewMetricsHandler(svc *service.MetricsService) *MetricsHandler {
+	return &MetricsHandler{service: svc}
+}
+
+// Enabled reports whether /metrics should be registered.
+func (h *MetricsHandler) Enabled() bool {
+	return h != nil && h.service.Enabled()
+}
+
+// Middleware records the final status and latency of API key authenticated
+// requests. Model and account labels come from the same context keys the ops
+// error logger uses, so they reflect what the handler actually scheduled.
+// Failed requests are also published to the admin live event stream; successful
+// ones are published once their usage log is written so they carry token counts.
+func (h *MetricsHandler) Middleware(c *gin.Context) {
+	metricsEnabled := h.Enabled()
+	if !metricsEnabled && !service.HasLiveRequestSubscribers() {
+		c.Next()
+		return
+	}
+	start := time.Now()
+	c.Next()
+
+	apiKey, ok := middleware2.GetAPIKeyFromContext(c)
+	if !ok || apiKey == nil {
+		return
+	}
+	platform := ""
+	if apiKey.Group != nil {
+		platform = apiKey.Group.Platform
+	}
+	model := ""
+	if v, ok := c.Get(opsModelKey); ok {
+		model, _ = v.(string)
+	}
+	var accountID int64
+	if v, ok := c.Get(opsAccountIDKey); ok {
+		accountID, _ = v.(int64)
+	}
+	status, latency := c.Writer.Status(), time.Since(start)
+	if metricsEnabled {
+		service.RecordGatewayRequestMetrics(platform, model, accountID, apiKey.ID, status, latency)
+	}
+	if status >= http.StatusBadRequest {
+		requestID, _ := c.Request.Context().Value(ctxkey.RequestID).(string)
+		service.PublishLiveRequestEvent(service.LiveRequestEvent{
+			RequestID: requestID,
+			APIKeyID:  apiKey.ID,
+			UserID:    apiKey.UserID,
+			AccountID: accountID,
+			Platform:  platform,
+			Model:     model,
+			Status:    status,
+			LatencyMs: latency.Milliseconds(),
+		})
+	}
+}
+
+// Serve handles GET /metrics
+func (h *MetricsHandler) Serve(c *gin.Context) {
+	if token := h.service.AuthToken(); token != "" {
+		provided := strings.TrimSpace(strings.TrimPrefix(c.GetHeader("Authorization"), "Bearer "))
+		if subtle.ConstantTimeCompare([]byte(provided), []byte(token)) != 1 {
+			c.AbortWithStatus(http.StatusUnauthorized)
+			return
+		}
+	}
+	c.Header("Content-Type", promtext.ContentType)
+	c.Status(http.StatusOK)
+	if err := h.service.WriteMetrics(c.Request.Context(), c.Writer); err != nil {
+		logger.FromContext(c.Request.Context()).Warn("metrics.write_failed", zap.Error(err))
+	}
+}
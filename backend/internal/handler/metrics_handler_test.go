@@ -0,0 +1,72 @@
+package handler
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/ShaohongDong/sub2api/internal/config"
+	"github.com/ShaohongDong/sub2api/internal/pkg/promtext"
+	middleware2 "github.com/ShaohongDong/sub2api/internal/server/middleware"
+	"github.com/ShaohongDong/sub2api/internal/service"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/require"
+)
+
+type metricsHandlerAccountRepoStub struct {
+	service.AccountRepository
+}
+
+func (metricsHandlerAccountRepoStub) ListActive(context.Context) ([]service.Account, error) {
+	return nil, nil
+}
+
+func newMetricsTestRouter(token string) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	cfg := &config.Config{}
+	cfg.Metrics.Enabled = true
+	cfg.Metrics.AuthToken = token
+	cfg.Metrics.APIKeyLabels = true
+	h := NewMetricsHandler(service.NewMetricsService(cfg, metricsHandlerAccountRepoStub{}))
+
+	r := gin.New()
+	r.Use(h.Middleware)
+	r.GET("/metrics", h.Serve)
+	r.POST("/v1/messages", func(c *gin.Context) {
+		c.Set(string(middleware2.ContextKeyAPIKey), &service.APIKey{ID: 77, Group: &service.Group{Platform: service.PlatformAnthropic}})
+		c.Set(opsModelKey, "metrics-handler-test-model")
+		c.Set(opsAccountIDKey, int64(5))
+		c.Status(http.StatusTooManyRequests)
+	})
+	return r
+}
+
+func TestMetricsHandlerRecordsGatewayRequests(t *testing.T) {
+	router := newMetricsTestRouter("")
+
+	router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodPost, "/v1/messages", nil))
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	require.Equal(t, http.StatusOK, w.Code)
+	require.Equal(t, promtext.ContentType, w.Header().Get("Content-Type"))
+	require.Contains(t, w.Body.String(), `sub2api_gateway_requests_total{platform="anthropic",model="metrics-handler-test-model",account_id="5",api_key_id="77",status="429"} 1`)
+	// /metrics 抓取本身不带 API Key，不计入请求指标
+	require.NotContains(t, w.Body.String(), `status="200"`)
+}
+
+func TestMetricsHandlerRequiresBearerToken(t *testing.T) {
+	router := newMetricsTestRouter("scrape-secret")
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	require.Equal(t, http.StatusUnauthorized, w.Code)
+
+	req := httptest.NewRequest(http.MethodGet, "/metrics", nil)
+	req.Header.Set("Authorization", "Bearer scrape-secret")
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	require.Equal(t, http.StatusOK, w.Code)
+	require.Contains(t, w.Body.String(), "# TYPE sub2api_gateway_requests_total counter")
+}
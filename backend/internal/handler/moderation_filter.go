@@ -0,0 +1,160 @@
+package handler
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/ShaohongDong/sub2api/internal/config"
+	"github.com/ShaohongDong/sub2api/internal/pkg/googleapi"
+	pkghttputil "github.com/ShaohongDong/sub2api/internal/pkg/httputil"
+	"github.com/ShaohongDong/sub2api/internal/pkg/logger"
+	"github.com/ShaohongDong/sub2api/internal/pkg/moderation"
+	middleware2 "github.com/ShaohongDong/sub2api/internal/server/middleware"
+	"github.com/ShaohongDong/sub2api/internal/service"
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+// newModerationClassifier 按 gateway.moderation.categories 构造本地规则分类器。
+// 规则已在配置校验阶段编译过，这里出错时仅记录日志并退化为不标记任何内容。
+func newModerationClassifier(cfg *config.Config) *moderation.RuleClassifier {
+	if cfg == nil {
+		return nil
+	}
+	classifier, err := moderation.NewRuleClassifier(cfg.Gateway.Moderation.Categories)
+	if err != nil {
+		logger.L().Warn("moderation: invalid local classifier rules", zap.Error(err))
+		return nil
+	}
+	return classifier
+}
+
+// ModerationFlaggedHeader lists the categories a request was flagged for
+// without being rejected by the moderation pre-filter.
+const ModerationFlaggedHeader = "X-Sub2API-Moderation-Flagged"
+
+// newModerationPreFilterClassifier 按 gateway.moderation.pre_filter_source 构造前置过滤使用的分类器，无可用分类器时返回 nil
+func newModerationPreFilterClassifier(cfg *config.Config) moderation.Classifier {
+	if cfg == nil {
+		return nil
+	}
+	if cfg.Gateway.Moderation.PreFilterSource == config.ModerationSourceEndpoint {
+		endpoint := cfg.Gateway.Moderation.Endpoint
+		if endpoint.URL == "" {
+			return nil
+		}
+		return moderation.NewEndpointClassifier(endpoint.URL, endpoint.APIKey, endpoint.Model,
+			time.Duration(endpoint.TimeoutSeconds)*time.Second, nil)
+	}
+	classifier := newModerationClassifier(cfg)
+	if classifier.Empty() {
+		return nil
+	}
+	return classifier
+}
+
+// ModerationPreFilterMiddleware 在推理请求转发前审核提示词文本（本地规则或审核接口，见 gateway.moderation.pre_filter_source）。
+// 是否审核由 API Key 的 moderation_mode 决定，未设置时沿用 gateway.moderation.pre_filter：
+// block 模式下命中 block_categories 的请求按入站协议格式返回 400，不进入调度；
+// 命中 flag_categories（或 flag 模式下命中任一类别）时仅记录日志并以 X-Sub2API-Moderation-Flagged 响应头标注。
+// 审核接口不可用时按 endpoint.fail_open 放行或返回 503。
+func ModerationPreFilterMiddleware(cfg *config.Config) gin.HandlerFunc {
+	classifier := newModerationPreFilterClassifier(cfg)
+	if classifier == nil {
+		return func(c *gin.Context) { c.Next() }
+	}
+	moderationCfg := cfg.Gateway.Moderation
+	failOpen := moderationCfg.PreFilterSource != config.ModerationSourceEndpoint || moderationCfg.Endpoint.FailOpen
+	return func(c *gin.Context) {
+		apiKey, _ := middleware2.GetAPIKeyFromContext(c)
+		mode := service.ResolveAPIKeyModeration(cfg, apiKey)
+		if mode == service.APIKeyModerationOff {
+			c.Next()
+			return
+		}
+		body, err := pkghttputil.ReadRequestBodyWithPrealloc(c.Request)
+		if err != nil {
+			if maxErr, ok := extractMaxBytesError(err); ok {
+				abortModerationError(c, http.StatusRequestEntityTooLarge, "invalid_request_error", buildBodyTooLargeMessage(maxErr.Limit))
+				return
+			}
+			abortModerationError(c, http.StatusBadRequest, "invalid_request_error", "Failed to read request body")
+			return
+		}
+		c.Request.Body = io.NopCloser(bytes.NewReader(body))
+		c.Request.ContentLength = int64(len(body))
+
+		texts := moderation.ExtractPromptText(body)
+		if len(texts) == 0 {
+			c.Next()
+			return
+		}
+		results, err := classifier.Classify(c.Request.Context(), []string{strings.Join(texts, "\n")})
+		if err != nil {
+			logger.L().Warn("moderation: pre-filter classification failed",
+				zap.String("path", c.Request.URL.Path),
+				zap.Bool("fail_open", failOpen),
+				zap.Error(err),
+			)
+			if !failOpen {
+				abortModerationError(c, http.StatusServiceUnavailable, "api_error", "Content moderation is temporarily unavailable, please retry later")
+				return
+			}
+			c.Next()
+			return
+		}
+		if len(results) == 0 || !results[0].Flagged {
+			c.Next()
+			return
+		}
+		blocked, flagged := service.SplitModerationCategories(moderationCfg, mode, results[0].FlaggedCategories())
+		var apiKeyID int64
+		if apiKey != nil {
+			apiKeyID = apiKey.ID
+		}
+		if len(blocked) > 0 {
+			logger.L().Info("moderation: request rejected by pre-filter",
+				zap.String("path", c.Request.URL.Path),
+				zap.Int64("api_key_id", apiKeyID),
+				zap.Strings("categories", blocked),
+			)
+			abortModerationError(c, http.StatusBadRequest, "content_policy_violation",
+				"Request was rejected by the content moderation filter (categories: "+strings.Join(blocked, ", ")+")")
+			return
+		}
+		if len(flagged) > 0 {
+			logger.L().Warn("moderation: request flagged by pre-filter",
+				zap.String("path", c.Request.URL.Path),
+				zap.Int64("api_key_id", apiKeyID),
+				zap.Strings("categories", flagged),
+			)
+			c.Header(ModerationFlaggedHeader, strings.Join(flagged, ","))
+		}
+		c.Next()
+	}
+}
+
+// abortModerationError 按入站协议（Anthropic / Gemini / Ollama / OpenAI）格式终止请求。
+func abortModerationError(c *gin.Context, status int, code, message string) {
+	path := c.Request.URL.Path
+	switch {
+	case strings.HasPrefix(path, "/model/") || strings.Contains(GetInboundEndpoint(c), "/messages"):
+		c.AbortWithStatusJSON(status, gin.H{
+			"type":  "error",
+			"error": gin.H{"type": "invalid_request_error", "message": message},
+		})
+	case strings.HasPrefix(path, "/v1beta/"):
+		c.AbortWithStatusJSON(status, gin.H{
+			"error": gin.H{"code": status, "message": message, "status": googleapi.HTTPStatusToGoogleStatus(status)},
+		})
+	case strings.HasPrefix(path, "/api/"):
+		c.AbortWithStatusJSON(status, gin.H{"error": message})
+	default:
+		c.AbortWithStatusJSON(status, gin.H{
+			"error": gin.H{"type": "invalid_request_error", "code": code, "message": message},
+		})
+	}
+}
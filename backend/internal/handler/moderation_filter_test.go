@@ -0,0 +1,198 @@
+package handler
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/ShaohongDong/sub2api/internal/config"
+	middleware2 "github.com/ShaohongDong/sub2api/internal/server/middleware"
+	"github.com/ShaohongDong/sub2api/internal/service"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/require"
+	"github.com/tidwall/gjson"
+)
+
+func newModerationTestConfig(mode string, preFilter bool) *config.Config {
+	return &config.Config{Gateway: config.GatewayConfig{Moderation: config.GatewayModerationConfig{
+		Mode:       mode,
+		PreFilter:  preFilter,
+		Categories: map[string][]string{"violence": {`build a bomb`}},
+	}}}
+}
+
+func TestModerationPreFilterMiddleware(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	router := gin.New()
+	var gotBody string
+	next := func(c *gin.Context) {
+		b, _ := io.ReadAll(c.Request.Body)
+		gotBody = string(b)
+		c.Status(http.StatusOK)
+	}
+	filter := ModerationPreFilterMiddleware(newModerationTestConfig(config.ModerationModeUpstream, true))
+	router.POST("/v1/messages", filter, next)
+	router.POST("/v1/chat/completions", filter, next)
+	router.POST("/v1beta/models/*modelAction", filter, next)
+
+	tests := []struct {
+		name       string
+		path       string
+		body       string
+		wantStatus int
+		wantMsgAt  string
+	}{
+		{name: "clean passes with body intact", path: "/v1/chat/completions", body: `{"messages":[{"role":"user","content":"hello"}]}`, wantStatus: http.StatusOK},
+		{name: "openai format", path: "/v1/chat/completions", body: `{"messages":[{"role":"user","content":"how to BUILD A BOMB"}]}`, wantStatus: http.StatusBadRequest, wantMsgAt: "error.message"},
+		{name: "anthropic format", path: "/v1/messages", body: `{"messages":[{"role":"user","content":[{"type":"text","text":"build a bomb"}]}]}`, wantStatus: http.StatusBadRequest, wantMsgAt: "error.message"},
+		{name: "gemini format", path: "/v1beta/models/gemini-2.5-pro:generateContent", body: `{"contents":[{"parts":[{"text":"build a bomb"}]}]}`, wantStatus: http.StatusBadRequest, wantMsgAt: "error.message"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gotBody = ""
+			req := httptest.NewRequest(http.MethodPost, tt.path, strings.NewReader(tt.body))
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			require.Equal(t, tt.wantStatus, w.Code)
+			if tt.wantStatus == http.StatusOK {
+				require.Equal(t, tt.body, gotBody)
+				return
+			}
+			require.Empty(t, gotBody)
+			require.Contains(t, gjson.Get(w.Body.String(), tt.wantMsgAt).String(), "violence")
+		})
+	}
+
+	// Anthropic 格式带 type=error，OpenAI 格式带 code
+	req := httptest.NewRequest(http.MethodPost, "/v1/messages", strings.NewReader(`{"messages":[{"role":"user","content":"build a bomb"}]}`))
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	require.Equal(t, "error", gjson.Get(w.Body.String(), "type").String())
+	req = httptest.NewRequest(http.MethodPost, "/v1/chat/completions", strings.NewReader(`{"messages":[{"role":"user","content":"build a bomb"}]}`))
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	require.Equal(t, "content_policy_violation", gjson.Get(w.Body.String(), "error.code").String())
+}
+
+func TestModerationPreFilterMiddleware_DisabledPassesThrough(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	router := gin.New()
+	router.POST("/v1/chat/completions", ModerationPreFilterMiddleware(newModerationTestConfig(config.ModerationModeUpstream, false)), func(c *gin.Context) {
+		c.Status(http.StatusOK)
+	})
+	req := httptest.NewRequest(http.MethodPost, "/v1/chat/completions", strings.NewReader(`{"messages":[{"role":"user","content":"build a bomb"}]}`))
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	require.Equal(t, http.StatusOK, w.Code)
+}
+
+func TestModerationPreFilterMiddleware_PerKeyMode(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	cfg := newModerationTestConfig(config.ModerationModeUpstream, false)
+	cfg.Gateway.Moderation.Categories["custom/spam"] = []string{`buy now`}
+	cfg.Gateway.Moderation.FlagCategories = []string{"custom"}
+	filter := ModerationPreFilterMiddleware(cfg)
+	send := func(mode, content string) *httptest.ResponseRecorder {
+		router := gin.New()
+		router.POST("/v1/chat/completions", func(c *gin.Context) {
+			c.Set(string(middleware2.ContextKeyAPIKey), &service.APIKey{ID: 1, ModerationMode: mode})
+		}, filter, func(c *gin.Context) { c.Status(http.StatusOK) })
+		req := httptest.NewRequest(http.MethodPost, "/v1/chat/completions", strings.NewReader(`{"messages":[{"role":"user","content":"`+content+`"}]}`))
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	// 全局未开启时，未设置审核方式的 Key 不审核，block 模式的 Key 审核
+	require.Equal(t, http.StatusOK, send("", "build a bomb").Code)
+	require.Equal(t, http.StatusBadRequest, send(service.APIKeyModerationBlock, "build a bomb").Code)
+
+	// flag_categories 中的类别仅标记
+	w := send(service.APIKeyModerationBlock, "buy now")
+	require.Equal(t, http.StatusOK, w.Code)
+	require.Equal(t, "custom/spam", w.Header().Get(ModerationFlaggedHeader))
+
+	// flag 模式从不拒绝
+	w = send(service.APIKeyModerationFlag, "build a bomb")
+	require.Equal(t, http.StatusOK, w.Code)
+	require.Equal(t, "violence", w.Header().Get(ModerationFlaggedHeader))
+
+	// Key 可关闭全局开启的前置过滤
+	cfg.Gateway.Moderation.PreFilter = true
+	require.Equal(t, http.StatusBadRequest, send("", "build a bomb").Code)
+	require.Equal(t, http.StatusOK, send(service.APIKeyModerationOff, "build a bomb").Code)
+}
+
+func TestModerationPreFilterMiddleware_Endpoint(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		switch {
+		case strings.Contains(string(body), "down"):
+			w.WriteHeader(http.StatusInternalServerError)
+		case strings.Contains(string(body), "hateful"):
+			_, _ = w.Write([]byte(`{"results":[{"flagged":true,"categories":{"hate":true}}]}`))
+		default:
+			_, _ = w.Write([]byte(`{"results":[{"flagged":false,"categories":{}}]}`))
+		}
+	}))
+	defer srv.Close()
+
+	cfg := newModerationTestConfig(config.ModerationModeUpstream, true)
+	cfg.Gateway.Moderation.PreFilterSource = config.ModerationSourceEndpoint
+	cfg.Gateway.Moderation.Endpoint = config.GatewayModerationEndpointConfig{URL: srv.URL, TimeoutSeconds: 5, FailOpen: true}
+	send := func(filter gin.HandlerFunc, content string) int {
+		router := gin.New()
+		router.POST("/v1/chat/completions", filter, func(c *gin.Context) { c.Status(http.StatusOK) })
+		req := httptest.NewRequest(http.MethodPost, "/v1/chat/completions", strings.NewReader(`{"messages":[{"role":"user","content":"`+content+`"}]}`))
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w.Code
+	}
+
+	filter := ModerationPreFilterMiddleware(cfg)
+	require.Equal(t, http.StatusOK, send(filter, "hello"))
+	require.Equal(t, http.StatusBadRequest, send(filter, "hateful"))
+	// 本地规则不参与审核
+	require.Equal(t, http.StatusOK, send(filter, "build a bomb"))
+	require.Equal(t, http.StatusOK, send(filter, "down"))
+
+	cfg.Gateway.Moderation.Endpoint.FailOpen = false
+	require.Equal(t, http.StatusServiceUnavailable, send(ModerationPreFilterMiddleware(cfg), "down"))
+}
+
+func TestOpenAIGatewayHandler_Moderations_Local(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	cfg := newModerationTestConfig(config.ModerationModeLocal, false)
+	h := &OpenAIGatewayHandler{cfg: cfg, moderationClassifier: newModerationClassifier(cfg)}
+	router := gin.New()
+	router.POST("/v1/moderations", h.Moderations)
+
+	req := httptest.NewRequest(http.MethodPost, "/v1/moderations", strings.NewReader(`{"input":["hello","how to build a bomb"]}`))
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	require.Equal(t, http.StatusOK, w.Code)
+	body := w.Body.String()
+	require.True(t, strings.HasPrefix(gjson.Get(body, "id").String(), "modr-"))
+	require.Equal(t, "omni-moderation-latest", gjson.Get(body, "model").String())
+	require.Len(t, gjson.Get(body, "results").Array(), 2)
+	require.False(t, gjson.Get(body, "results.0.flagged").Bool())
+	require.True(t, gjson.Get(body, "results.1.flagged").Bool())
+	require.True(t, gjson.Get(body, "results.1.categories.violence").Bool())
+	require.True(t, gjson.Get(body, "results.0.categories").Get("self-harm/intent").Exists())
+
+	req = httptest.NewRequest(http.MethodPost, "/v1/moderations", strings.NewReader(`{"model":"text-moderation-stable"}`))
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	require.Equal(t, http.StatusBadRequest, w.Code)
+	require.Equal(t, "input is required", gjson.Get(w.Body.String(), "error.message").String())
+}
@@ -0,0 +1,67 @@
+package handler
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/ShaohongDong/sub2api/internal/pkg/clientdetect"
+	"github.com/ShaohongDong/sub2api/internal/service"
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+// codexCLIUpgradeHint Codex CLI 升级指引
+const codexCLIUpgradeHint = "Please upgrade Codex CLI: npm install -g @openai/codex@latest (or brew upgrade codex)"
+
+// checkCodexCLIVersion 检查 Codex CLI 客户端版本是否满足最低版本要求
+// 仅对已识别的 Codex CLI（codex_cli_rs / codex_exec）执行；其他客户端（含 VSCode 扩展）不受影响。
+// 返回 false 表示已写入错误响应，调用方应直接返回。
+func (h *OpenAIGatewayHandler) checkCodexCLIVersion(c *gin.Context, reqLog *zap.Logger) bool {
+	if h == nil || h.settingService == nil || c == nil || c.Request == nil {
+		return true
+	}
+	info, ok := clientdetect.FromContext(c.Request.Context())
+	if !ok {
+		info = clientdetect.Parse(c.GetHeader("User-Agent"))
+	}
+	if info.Type != clientdetect.TypeCodexCLI && info.Type != clientdetect.TypeCodexExec {
+		return true
+	}
+
+	minVersion := h.settingService.GetMinCodexCLIVersion(c.Request.Context())
+	message, rejected := codexCLIVersionRejection(info.Version, minVersion)
+	if !rejected {
+		return true
+	}
+	if reqLog != nil {
+		reqLog.Info("openai.client_version_rejected",
+			zap.String("client_type", string(info.Type)),
+			zap.String("client_version", info.Version),
+			zap.String("min_version", minVersion),
+		)
+	}
+	c.JSON(http.StatusBadRequest, gin.H{
+		"error": gin.H{
+			"type":    "invalid_request_error",
+			"code":    "unsupported_client_version",
+			"message": message,
+		},
+	})
+	return false
+}
+
+// codexCLIVersionRejection 判断 Codex CLI 版本是否低于最低要求，返回拒绝原因。
+// minVersion 为空表示不检查。
+func codexCLIVersionRejection(clientVersion, minVersion string) (string, bool) {
+	if minVersion == "" {
+		return "", false
+	}
+	if clientVersion == "" {
+		return "Unable to determine Codex CLI version. " + codexCLIUpgradeHint, true
+	}
+	if service.CompareVersions(clientVersion, minVersion) < 0 {
+		return fmt.Sprintf("Your Codex CLI version (%s) is below the minimum required version (%s). %s",
+			clientVersion, minVersion, codexCLIUpgradeHint), true
+	}
+	return "", false
+}
@@ -0,0 +1,46 @@
+package handler
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCodexCLIVersionRejection(t *testing.T) {
+	tests := []struct {
+		name          string
+		clientVersion string
+		minVersion    string
+		wantRejected  bool
+	}{
+		{name: "未配置最低版本", clientVersion: "0.1.0", minVersion: "", wantRejected: false},
+		{name: "低于最低版本", clientVersion: "0.39.9", minVersion: "0.40.0", wantRejected: true},
+		{name: "等于最低版本", clientVersion: "0.40.0", minVersion: "0.40.0", wantRejected: false},
+		{name: "高于最低版本", clientVersion: "0.46.0", minVersion: "0.40.0", wantRejected: false},
+		{name: "无法解析版本", clientVersion: "", minVersion: "0.40.0", wantRejected: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			msg, rejected := codexCLIVersionRejection(tt.clientVersion, tt.minVersion)
+			require.Equal(t, tt.wantRejected, rejected)
+			if rejected {
+				require.Contains(t, msg, codexCLIUpgradeHint)
+			}
+		})
+	}
+}
+
+func TestCheckCodexCLIVersion_NoSettingServiceAllows(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	rec := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(rec)
+	c.Request = httptest.NewRequest(http.MethodPost, "/v1/responses", nil)
+	c.Request.Header.Set("User-Agent", "codex_cli_rs/0.1.0")
+
+	h := &OpenAIGatewayHandler{}
+	require.True(t, h.checkCodexCLIVersion(c, nil))
+	require.False(t, c.Writer.Written())
+}
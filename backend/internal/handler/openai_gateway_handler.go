@@ -7,14 +7,16 @@ import (
 	"fmt"
 	"net/http"
 	"runtime/debug"
-	"strconv"
 	"strings"
 	"time"
 
 	"github.com/ShaohongDong/sub2api/internal/config"
+	"github.com/ShaohongDong/sub2api/internal/pkg/ctxkey"
+	infraerrors "github.com/ShaohongDong/sub2api/internal/pkg/errors"
 	pkghttputil "github.com/ShaohongDong/sub2api/internal/pkg/httputil"
 	"github.com/ShaohongDong/sub2api/internal/pkg/ip"
 	"github.com/ShaohongDong/sub2api/internal/pkg/logger"
+	"github.com/ShaohongDong/sub2api/internal/pkg/moderation"
 	middleware2 "github.com/ShaohongDong/sub2api/internal/server/middleware"
 	"github.com/ShaohongDong/sub2api/internal/service"
 
@@ -31,7 +33,10 @@ type OpenAIGatewayHandler struct {
 	apiKeyService           *service.APIKeyService
 	usageRecordWorkerPool   *service.UsageRecordWorkerPool
 	errorPassthroughService *service.ErrorPassthroughService
+	settingService          *service.SettingService
+	userFileService         *service.UserFileService
 	concurrencyHelper       *ConcurrencyHelper
+	moderationClassifier    *moderation.RuleClassifier
 	maxAccountSwitches      int
 	cfg                     *config.Config
 }
@@ -44,6 +49,8 @@ func NewOpenAIGatewayHandler(
 	apiKeyService *service.APIKeyService,
 	usageRecordWorkerPool *service.UsageRecordWorkerPool,
 	errorPassthroughService *service.ErrorPassthroughService,
+	settingService *service.SettingService,
+	userFileService *service.UserFileService,
 	cfg *config.Config,
 ) *OpenAIGatewayHandler {
 	pingInterval := time.Duration(0)
@@ -60,7 +67,10 @@ func NewOpenAIGatewayHandler(
 		apiKeyService:           apiKeyService,
 		usageRecordWorkerPool:   usageRecordWorkerPool,
 		errorPassthroughService: errorPassthroughService,
+		settingService:          settingService,
+		userFileService:         userFileService,
 		concurrencyHelper:       NewConcurrencyHelper(concurrencyService, SSEPingFormatComment, pingInterval),
+		moderationClassifier:    newModerationClassifier(cfg),
 		maxAccountSwitches:      maxAccountSwitches,
 		cfg:                     cfg,
 	}
@@ -100,6 +110,9 @@ func (h *OpenAIGatewayHandler) Responses(c *gin.Context) {
 	if !h.ensureResponsesDependencies(c, reqLog) {
 		return
 	}
+	if !h.checkCodexCLIVersion(c, reqLog) {
+		return
+	}
 
 	// Read request body
 	body, err := pkghttputil.ReadRequestBodyWithPrealloc(c.Request)
@@ -172,6 +185,17 @@ func (h *OpenAIGatewayHandler) Responses(c *gin.Context) {
 	}
 
 	setOpsRequestContext(c, reqModel, reqStream, body)
+	c.Request = c.Request.WithContext(service.WithReasoningEffort(c.Request.Context(), service.RequestReasoningEffort(body, service.ReasoningFormatResponses)))
+
+	if !apiKey.AllowsModel(clientRequestedModel(c, reqModel)) {
+		h.errorResponse(c, http.StatusForbidden, "permission_error", apiKeyModelNotAllowedMessage(clientRequestedModel(c, reqModel)))
+		return
+	}
+
+	body, ok = h.resolveFileReferences(c, subject.UserID, service.UserFileRefFormatResponses, body, reqLog)
+	if !ok {
+		return
+	}
 
 	// 提前校验 function_call_output 是否具备可关联上下文，避免上游 400。
 	if !h.validateFunctionCallOutputRequest(c, body, reqLog) {
@@ -342,7 +366,7 @@ func (h *OpenAIGatewayHandler) Responses(c *gin.Context) {
 		clientIP := ip.GetClientIP(c)
 
 		// 使用量记录通过有界 worker 池提交，避免请求热路径创建无界 goroutine。
-		h.submitUsageRecordTask(func(ctx context.Context) {
+		h.submitUsageRecordTask(c, func(ctx context.Context) {
 			if err := h.gatewayService.RecordUsage(ctx, &service.OpenAIRecordUsageInput{
 				Result:        result,
 				APIKey:        apiKey,
@@ -352,6 +376,9 @@ func (h *OpenAIGatewayHandler) Responses(c *gin.Context) {
 				UserAgent:     userAgent,
 				IPAddress:     clientIP,
 				APIKeyService: h.apiKeyService,
+				InputTokenEstimator: func() int {
+					return service.CountOpenAIRequestTokens(reqModel, body)
+				},
 			}); err != nil {
 				logger.L().With(
 					zap.String("component", "handler.openai_gateway.responses"),
@@ -435,9 +462,16 @@ func (h *OpenAIGatewayHandler) logOpenAIRemoteCompactOutcome(c *gin.Context, sta
 			}
 		}
 		if c.Writer != nil {
-			if upstreamRequestID := strings.TrimSpace(c.Writer.Header().Get("x-request-id")); upstreamRequestID != "" {
-				fields = append(fields, zap.String("upstream_request_id", upstreamRequestID))
-			} else if upstreamRequestID := strings.TrimSpace(c.Writer.Header().Get("X-Request-Id")); upstreamRequestID != "" {
+			// X-Request-Id is the gateway's own ID once the response is written;
+			// the upstream one is moved to X-Upstream-Request-Id.
+			upstreamRequestID := strings.TrimSpace(c.Writer.Header().Get(middleware2.UpstreamRequestIDHeader))
+			if upstreamRequestID == "" {
+				requestID, _ := ctx.Value(ctxkey.RequestID).(string)
+				if v := strings.TrimSpace(c.Writer.Header().Get("X-Request-Id")); v != requestID {
+					upstreamRequestID = v
+				}
+			}
+			if upstreamRequestID != "" {
 				fields = append(fields, zap.String("upstream_request_id", upstreamRequestID))
 			}
 		}
@@ -519,6 +553,12 @@ func (h *OpenAIGatewayHandler) Messages(c *gin.Context) {
 	reqLog = reqLog.With(zap.String("model", reqModel), zap.Bool("stream", reqStream))
 
 	setOpsRequestContext(c, reqModel, reqStream, body)
+	c.Request = c.Request.WithContext(service.WithReasoningEffort(c.Request.Context(), service.RequestReasoningEffort(body, service.ReasoningFormatAnthropic)))
+
+	if !apiKey.AllowsModel(clientRequestedModel(c, reqModel)) {
+		h.anthropicErrorResponse(c, http.StatusForbidden, "permission_error", apiKeyModelNotAllowedMessage(clientRequestedModel(c, reqModel)))
+		return
+	}
 
 	// 绑定错误透传服务，允许 service 层在非 failover 错误场景复用规则。
 	if h.errorPassthroughService != nil {
@@ -710,7 +750,7 @@ func (h *OpenAIGatewayHandler) Messages(c *gin.Context) {
 		userAgent := c.GetHeader("User-Agent")
 		clientIP := ip.GetClientIP(c)
 
-		h.submitUsageRecordTask(func(ctx context.Context) {
+		h.submitUsageRecordTask(c, func(ctx context.Context) {
 			if err := h.gatewayService.RecordUsage(ctx, &service.OpenAIRecordUsageInput{
 				Result:        result,
 				APIKey:        apiKey,
@@ -720,6 +760,9 @@ func (h *OpenAIGatewayHandler) Messages(c *gin.Context) {
 				UserAgent:     userAgent,
 				IPAddress:     clientIP,
 				APIKeyService: h.apiKeyService,
+				InputTokenEstimator: func() int {
+					return service.CountAnthropicRequestTokens(body)
+				},
 			}); err != nil {
 				logger.L().With(
 					zap.String("component", "handler.openai_gateway.messages"),
@@ -773,6 +816,7 @@ func (h *OpenAIGatewayHandler) anthropicStreamingAwareError(c *gin.Context, stat
 
 // handleAnthropicFailoverExhausted maps upstream failover errors to Anthropic format.
 func (h *OpenAIGatewayHandler) handleAnthropicFailoverExhausted(c *gin.Context, failoverErr *service.UpstreamFailoverError, streamStarted bool) {
+	setUpstreamRetryAfter(c, failoverErr)
 	status, errType, errMsg := h.mapUpstreamError(failoverErr.StatusCode)
 	h.anthropicStreamingAwareError(c, status, errType, errMsg, streamStarted)
 }
@@ -993,6 +1037,9 @@ func (h *OpenAIGatewayHandler) ResponsesWebSocket(c *gin.Context) {
 	if !h.ensureResponsesDependencies(c, reqLog) {
 		return
 	}
+	if !h.checkCodexCLIVersion(c, reqLog) {
+		return
+	}
 	reqLog.Info("openai.websocket_ingress_started")
 	clientIP := ip.GetClientIP(c)
 	userAgent := strings.TrimSpace(c.GetHeader("User-Agent"))
@@ -1060,6 +1107,12 @@ func (h *OpenAIGatewayHandler) ResponsesWebSocket(c *gin.Context) {
 		zap.String("previous_response_id_kind", previousResponseIDKind),
 	)
 	setOpsRequestContext(c, reqModel, true, firstMessage)
+	ctx = service.WithReasoningEffort(ctx, service.RequestReasoningEffort(firstMessage, service.ReasoningFormatResponses))
+
+	if !apiKey.AllowsModel(clientRequestedModel(c, reqModel)) {
+		closeOpenAIClientWS(wsConn, coderws.StatusPolicyViolation, apiKeyModelNotAllowedMessage(clientRequestedModel(c, reqModel)))
+		return
+	}
 
 	var currentUserRelease func()
 	var currentAccountRelease func()
@@ -1206,7 +1259,7 @@ func (h *OpenAIGatewayHandler) ResponsesWebSocket(c *gin.Context) {
 				h.gatewayService.UpdateCodexUsageSnapshotFromHeaders(ctx, account.ID, result.ResponseHeaders)
 			}
 			h.gatewayService.ReportOpenAIAccountScheduleResult(account.ID, true, result.FirstTokenMs)
-			h.submitUsageRecordTask(func(taskCtx context.Context) {
+			h.submitUsageRecordTask(c, func(taskCtx context.Context) {
 				if err := h.gatewayService.RecordUsage(taskCtx, &service.OpenAIRecordUsageInput{
 					Result:        result,
 					APIKey:        apiKey,
@@ -1350,10 +1403,11 @@ func getContextInt64(c *gin.Context, key string) (int64, bool) {
 	}
 }
 
-func (h *OpenAIGatewayHandler) submitUsageRecordTask(task service.UsageRecordTask) {
+func (h *OpenAIGatewayHandler) submitUsageRecordTask(c *gin.Context, task service.UsageRecordTask) {
 	if task == nil {
 		return
 	}
+	task = withRequestContext(c, task)
 	if h.usageRecordWorkerPool != nil {
 		h.usageRecordWorkerPool.Submit(task)
 		return
@@ -1379,6 +1433,7 @@ func (h *OpenAIGatewayHandler) handleConcurrencyError(c *gin.Context, err error,
 }
 
 func (h *OpenAIGatewayHandler) handleFailoverExhausted(c *gin.Context, failoverErr *service.UpstreamFailoverError, streamStarted bool) {
+	setUpstreamRetryAfter(c, failoverErr)
 	statusCode := failoverErr.StatusCode
 	responseBody := failoverErr.ResponseBody
 
@@ -1392,10 +1447,13 @@ func (h *OpenAIGatewayHandler) handleFailoverExhausted(c *gin.Context, failoverE
 			}
 
 			// 确定响应消息
-			msg := service.ExtractUpstreamErrorMessage(responseBody)
+			msg := service.ScrubOpenAIClientErrorMessage(service.ExtractUpstreamErrorMessage(responseBody), nil)
 			if !rule.PassthroughBody && rule.CustomMessage != nil {
 				msg = *rule.CustomMessage
 			}
+			if msg == "" {
+				msg = "Upstream request failed"
+			}
 
 			if rule.SkipMonitoring {
 				c.Set(service.OpsSkipPassthroughKey, true)
@@ -1407,42 +1465,31 @@ func (h *OpenAIGatewayHandler) handleFailoverExhausted(c *gin.Context, failoverE
 	}
 
 	// 使用默认的错误映射
-	status, errType, errMsg := h.mapUpstreamError(statusCode)
-	h.handleStreamingAwareError(c, status, errType, errMsg, streamStarted)
+	h.handleStreamingAwareClientError(c, service.MapOpenAIUpstreamStatus(statusCode), streamStarted)
 }
 
 // handleFailoverExhaustedSimple 简化版本，用于没有响应体的情况
 func (h *OpenAIGatewayHandler) handleFailoverExhaustedSimple(c *gin.Context, statusCode int, streamStarted bool) {
-	status, errType, errMsg := h.mapUpstreamError(statusCode)
-	h.handleStreamingAwareError(c, status, errType, errMsg, streamStarted)
+	h.handleStreamingAwareClientError(c, service.MapOpenAIUpstreamStatus(statusCode), streamStarted)
 }
 
+// mapUpstreamError 上游状态码的默认映射，供其他协议格式的错误响应复用
 func (h *OpenAIGatewayHandler) mapUpstreamError(statusCode int) (int, string, string) {
-	switch statusCode {
-	case 401:
-		return http.StatusBadGateway, "upstream_error", "Upstream authentication failed, please contact administrator"
-	case 403:
-		return http.StatusBadGateway, "upstream_error", "Upstream access forbidden, please contact administrator"
-	case 429:
-		return http.StatusTooManyRequests, "rate_limit_error", "Upstream rate limit exceeded, please retry later"
-	case 529:
-		return http.StatusServiceUnavailable, "upstream_error", "Upstream service overloaded, please retry later"
-	case 500, 502, 503, 504:
-		return http.StatusBadGateway, "upstream_error", "Upstream service temporarily unavailable"
-	default:
-		return http.StatusBadGateway, "upstream_error", "Upstream request failed"
-	}
+	mapped := service.MapOpenAIUpstreamStatus(statusCode)
+	return mapped.Status, mapped.Type, mapped.Message
 }
 
 // handleStreamingAwareError handles errors that may occur after streaming has started
 func (h *OpenAIGatewayHandler) handleStreamingAwareError(c *gin.Context, status int, errType, message string, streamStarted bool) {
+	h.handleStreamingAwareClientError(c, service.OpenAIClientError{Status: status, Type: errType, Message: message}, streamStarted)
+}
+
+func (h *OpenAIGatewayHandler) handleStreamingAwareClientError(c *gin.Context, clientErr service.OpenAIClientError, streamStarted bool) {
 	if streamStarted {
 		// Stream already started, send error as SSE event then close
 		flusher, ok := c.Writer.(http.Flusher)
 		if ok {
-			// SSE 错误事件固定 schema，使用 Quote 直拼可避免额外 Marshal 分配。
-			errorEvent := "event: error\ndata: " + `{"error":{"type":` + strconv.Quote(errType) + `,"message":` + strconv.Quote(message) + `}}` + "\n\n"
-			if _, err := fmt.Fprint(c.Writer, errorEvent); err != nil {
+			if _, err := fmt.Fprint(c.Writer, clientErr.SSEEvent()); err != nil {
 				_ = c.Error(err)
 			}
 			flusher.Flush()
@@ -1451,7 +1498,7 @@ func (h *OpenAIGatewayHandler) handleStreamingAwareError(c *gin.Context, status
 	}
 
 	// Normal case: return JSON response with proper status code
-	h.errorResponse(c, status, errType, message)
+	service.WriteOpenAIError(c, clientErr)
 }
 
 // ensureForwardErrorResponse 在 Forward 返回错误但尚未写响应时补写统一错误响应。
@@ -1473,14 +1520,27 @@ func shouldLogOpenAIForwardFailureAsWarn(c *gin.Context, wroteFallback bool) boo
 	return c.Writer.Written()
 }
 
+// resolveFileReferences 展开请求体中引用 /v1/files 上传文件的 file_id（失败时已写入错误响应）。
+func (h *OpenAIGatewayHandler) resolveFileReferences(c *gin.Context, userID int64, format service.UserFileRefFormat, body []byte, reqLog *zap.Logger) ([]byte, bool) {
+	if h.userFileService == nil {
+		return body, true
+	}
+	resolved, err := h.userFileService.ResolveFileReferences(c.Request.Context(), userID, format, body)
+	if err != nil {
+		if infraerrors.IsBadRequest(err) {
+			h.errorResponse(c, http.StatusBadRequest, "invalid_request_error", infraerrors.Message(err))
+			return nil, false
+		}
+		reqLog.Error("openai.resolve_file_references_failed", zap.Error(err))
+		h.errorResponse(c, http.StatusInternalServerError, "api_error", "Failed to load referenced files")
+		return nil, false
+	}
+	return resolved, true
+}
+
 // errorResponse returns OpenAI API format error response
 func (h *OpenAIGatewayHandler) errorResponse(c *gin.Context, status int, errType, message string) {
-	c.JSON(status, gin.H{
-		"error": gin.H{
-			"type":    errType,
-			"message": message,
-		},
-	})
+	service.WriteOpenAIError(c, service.OpenAIClientError{Status: status, Type: errType, Message: message})
 }
 
 func setOpenAIClientTransportHTTP(c *gin.Context) {
@@ -0,0 +1,61 @@
+package handler
+
+import (
+	"github.com/ShaohongDong/sub2api/internal/service"
+
+	"github.com/gin-gonic/gin"
+	"github.com/tidwall/gjson"
+)
+
+// AudioTranscriptions handles speech-to-text requests for OpenAI platform
+// groups: POST /v1/audio/transcriptions
+//
+// The multipart upload is passed through to API key accounts; only the model
+// field is rewritten when the account maps it.
+func (h *OpenAIGatewayHandler) AudioTranscriptions(c *gin.Context) {
+	h.serveOpenAICompat(c, openAICompatEndpoint{
+		name:            "audio_transcriptions",
+		parse:           parseAudioTranscriptionRequest,
+		supportsAccount: service.SupportsOpenAIPlatformAPI,
+		forward:         h.gatewayService.ForwardAudioTranscription,
+	})
+}
+
+// AudioSpeech handles text-to-speech requests for OpenAI platform groups:
+// POST /v1/audio/speech
+//
+// The JSON body is passed through to API key accounts and the audio stream is
+// relayed back as it arrives.
+func (h *OpenAIGatewayHandler) AudioSpeech(c *gin.Context) {
+	h.serveOpenAICompat(c, openAICompatEndpoint{
+		name:            "audio_speech",
+		validate:        validateAudioSpeechBody,
+		supportsAccount: service.SupportsOpenAIPlatformAPI,
+		forward:         h.gatewayService.ForwardAudioSpeech,
+	})
+}
+
+func parseAudioTranscriptionRequest(c *gin.Context, body []byte) (openAICompatRequest, string) {
+	fields, err := service.ParseOpenAIAudioMultipart(c.GetHeader("Content-Type"), body)
+	if err != nil {
+		return openAICompatRequest{}, err.Error()
+	}
+	if fields.Model == "" {
+		return openAICompatRequest{}, "model is required"
+	}
+	if !fields.HasFile {
+		return openAICompatRequest{}, "file is required"
+	}
+	// 音频文件不写入 ops 错误日志
+	return openAICompatRequest{model: fields.Model, stream: fields.Stream}, ""
+}
+
+func validateAudioSpeechBody(body []byte) string {
+	if gjson.GetBytes(body, "input").String() == "" {
+		return "input is required"
+	}
+	if voice := gjson.GetBytes(body, "voice"); !voice.Exists() || voice.Type == gjson.Null {
+		return "voice is required"
+	}
+	return ""
+}
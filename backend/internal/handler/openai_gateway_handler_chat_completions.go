@@ -1,18 +1,471 @@
 package handler
 
 import (
+	"context"
+	"errors"
 	"net/http"
+	"runtime/debug"
+	"time"
+
+	pkghttputil "github.com/ShaohongDong/sub2api/internal/pkg/httputil"
+	"github.com/ShaohongDong/sub2api/internal/pkg/ip"
+	"github.com/ShaohongDong/sub2api/internal/pkg/logger"
+	middleware2 "github.com/ShaohongDong/sub2api/internal/server/middleware"
+	"github.com/ShaohongDong/sub2api/internal/service"
 
 	"github.com/gin-gonic/gin"
+	"github.com/tidwall/gjson"
+	"go.uber.org/zap"
 )
 
-// ChatCompletions is intentionally unsupported for OpenAI platform groups on
-// this branch; they must use the Responses API entrypoint.
+// openAICompatEndpoint describes an OpenAI-format endpoint served on OpenAI
+// groups outside the native Responses handler (chat completions, legacy
+// completions, embeddings, audio, images).
+type openAICompatEndpoint struct {
+	// name is used for the log component and event prefix, e.g. "chat_completions".
+	name string
+	// validate checks endpoint-specific required fields of a JSON body and returns an error message.
+	validate func(body []byte) string
+	// parse, when set, replaces the JSON body parsing (e.g. multipart uploads).
+	parse func(c *gin.Context, body []byte) (openAICompatRequest, string)
+	// supportsAccount, when set, excludes scheduled accounts that cannot serve the endpoint.
+	supportsAccount func(account *service.Account) bool
+	// fileRefFormat, when set, expands file_id references to uploaded files before forwarding.
+	fileRefFormat service.UserFileRefFormat
+	// countInputTokens, when set, estimates input tokens for billing if upstream omits usage.
+	countInputTokens func(model string, body []byte) int
+	forward          func(ctx context.Context, c *gin.Context, account *service.Account, body []byte, promptCacheKey, defaultMappedModel string) (*service.OpenAIForwardResult, error)
+}
+
+// ChatCompletions handles OpenAI Chat Completions API requests for OpenAI
+// platform groups: POST /v1/chat/completions
+//
+// The request is converted to the Responses API and the reply converted back
+// into chat.completion / chat.completion.chunk objects.
 func (h *OpenAIGatewayHandler) ChatCompletions(c *gin.Context) {
-	h.errorResponse(
+	h.serveOpenAICompat(c, openAICompatEndpoint{
+		name:             "chat_completions",
+		validate:         validateChatCompletionsBody,
+		fileRefFormat:    service.UserFileRefFormatChatCompletions,
+		countInputTokens: service.CountOpenAIRequestTokens,
+		forward:          h.gatewayService.ForwardAsChatCompletions,
+	})
+}
+
+// Completions handles legacy text completion requests for OpenAI platform
+// groups: POST /v1/completions
+//
+// The prompt is wrapped into a Responses API call; text / logprobs / echo are
+// mapped back into text_completion objects.
+func (h *OpenAIGatewayHandler) Completions(c *gin.Context) {
+	h.serveOpenAICompat(c, openAICompatEndpoint{
+		name:             "completions",
+		validate:         validateCompletionsBody,
+		countInputTokens: service.CountOpenAIRequestTokens,
+		forward:          h.gatewayService.ForwardAsCompletions,
+	})
+}
+
+// Embeddings handles embeddings requests for OpenAI platform groups:
+// POST /v1/embeddings
+//
+// The body is passed through to API key accounts only; large input arrays are
+// split into several upstream calls and merged.
+func (h *OpenAIGatewayHandler) Embeddings(c *gin.Context) {
+	h.serveOpenAICompat(c, openAICompatEndpoint{
+		name:            "embeddings",
+		validate:        validateEmbeddingsBody,
+		supportsAccount: service.SupportsOpenAIPlatformAPI,
+		forward:         h.gatewayService.ForwardEmbeddings,
+	})
+}
+
+// openAICompatRequest holds the request fields needed for scheduling and ops logging.
+type openAICompatRequest struct {
+	model  string
+	stream bool
+	// opsBody is recorded in ops error logs; nil for binary uploads.
+	opsBody []byte
+	// images is the number of images requested; checked against the API key image quota when > 0.
+	images int
+}
+
+// parseOpenAICompatJSON validates a JSON body: model is required, then the
+// endpoint-specific validate hook.
+func parseOpenAICompatJSON(body []byte, validate func(body []byte) string) (openAICompatRequest, string) {
+	if !gjson.ValidBytes(body) {
+		return openAICompatRequest{}, "Failed to parse request body"
+	}
+	modelResult := gjson.GetBytes(body, "model")
+	if !modelResult.Exists() || modelResult.Type != gjson.String || modelResult.String() == "" {
+		return openAICompatRequest{}, "model is required"
+	}
+	if validate != nil {
+		if msg := validate(body); msg != "" {
+			return openAICompatRequest{}, msg
+		}
+	}
+	return openAICompatRequest{
+		model:   modelResult.String(),
+		stream:  gjson.GetBytes(body, "stream").Bool(),
+		opsBody: body,
+	}, ""
+}
+
+func validateChatCompletionsBody(body []byte) string {
+	if messages := gjson.GetBytes(body, "messages"); !messages.IsArray() || len(messages.Array()) == 0 {
+		return "messages is required"
+	}
+	return ""
+}
+
+func validateCompletionsBody(body []byte) string {
+	if !gjson.GetBytes(body, "prompt").Exists() {
+		return "prompt is required"
+	}
+	return ""
+}
+
+func validateEmbeddingsBody(body []byte) string {
+	input := gjson.GetBytes(body, "input")
+	if !input.Exists() || input.Type == gjson.Null || (input.IsArray() && len(input.Array()) == 0) {
+		return "input is required"
+	}
+	return ""
+}
+
+func (h *OpenAIGatewayHandler) serveOpenAICompat(c *gin.Context, ep openAICompatEndpoint) {
+	streamStarted := false
+	defer h.recoverOpenAICompatPanic(c, ep.name, &streamStarted)
+
+	requestStart := time.Now()
+
+	apiKey, ok := middleware2.GetAPIKeyFromContext(c)
+	if !ok {
+		h.errorResponse(c, http.StatusUnauthorized, "authentication_error", "Invalid API key")
+		return
+	}
+
+	subject, ok := middleware2.GetAuthSubjectFromContext(c)
+	if !ok {
+		h.errorResponse(c, http.StatusInternalServerError, "api_error", "User context not found")
+		return
+	}
+	logPrefix := "openai_" + ep.name + "."
+	fallbackKey := "openai_" + ep.name + "_fallback_model"
+	reqLog := requestLogger(
 		c,
-		http.StatusBadRequest,
-		"invalid_request_error",
-		"Unsupported legacy protocol: /v1/chat/completions is not supported. Please use /v1/responses.",
+		"handler.openai_gateway."+ep.name,
+		zap.Int64("user_id", subject.UserID),
+		zap.Int64("api_key_id", apiKey.ID),
+		zap.Any("group_id", apiKey.GroupID),
+	)
+
+	if !h.ensureResponsesDependencies(c, reqLog) {
+		return
+	}
+
+	body, err := pkghttputil.ReadRequestBodyWithPrealloc(c.Request)
+	if err != nil {
+		if maxErr, ok := extractMaxBytesError(err); ok {
+			h.errorResponse(c, http.StatusRequestEntityTooLarge, "invalid_request_error", buildBodyTooLargeMessage(maxErr.Limit))
+			return
+		}
+		h.errorResponse(c, http.StatusBadRequest, "invalid_request_error", "Failed to read request body")
+		return
+	}
+	if len(body) == 0 {
+		h.errorResponse(c, http.StatusBadRequest, "invalid_request_error", "Request body is empty")
+		return
+	}
+
+	var req openAICompatRequest
+	var msg string
+	if ep.parse != nil {
+		req, msg = ep.parse(c, body)
+	} else {
+		req, msg = parseOpenAICompatJSON(body, ep.validate)
+	}
+	if msg != "" {
+		h.errorResponse(c, http.StatusBadRequest, "invalid_request_error", msg)
+		return
+	}
+	reqModel := req.model
+	reqStream := req.stream
+
+	reqLog = reqLog.With(zap.String("model", reqModel), zap.Bool("stream", reqStream))
+
+	setOpsRequestContext(c, reqModel, reqStream, req.opsBody)
+	c.Request = c.Request.WithContext(service.WithReasoningEffort(c.Request.Context(), service.RequestReasoningEffort(req.opsBody, service.ReasoningFormatChatCompletions)))
+
+	if !apiKey.AllowsModel(clientRequestedModel(c, reqModel)) {
+		h.errorResponse(c, http.StatusForbidden, "permission_error", apiKeyModelNotAllowedMessage(clientRequestedModel(c, reqModel)))
+		return
+	}
+
+	if ep.fileRefFormat != "" {
+		if body, ok = h.resolveFileReferences(c, subject.UserID, ep.fileRefFormat, body, reqLog); !ok {
+			return
+		}
+	}
+
+	// 绑定错误透传服务，允许 service 层在非 failover 错误场景复用规则。
+	if h.errorPassthroughService != nil {
+		service.BindErrorPassthroughService(c, h.errorPassthroughService)
+	}
+
+	subscription, _ := middleware2.GetSubscriptionFromContext(c)
+
+	service.SetOpsLatencyMs(c, service.OpsAuthLatencyMsKey, time.Since(requestStart).Milliseconds())
+	routingStart := time.Now()
+
+	userReleaseFunc, acquired := h.acquireResponsesUserSlot(c, subject.UserID, subject.Concurrency, reqStream, &streamStarted, reqLog)
+	if !acquired {
+		return
+	}
+	if userReleaseFunc != nil {
+		defer userReleaseFunc()
+	}
+
+	if err := h.billingCacheService.CheckBillingEligibility(c.Request.Context(), apiKey.User, apiKey, apiKey.Group, subscription); err != nil {
+		reqLog.Info(logPrefix+"billing_eligibility_check_failed", zap.Error(err))
+		status, code, message := billingErrorDetails(err)
+		h.handleStreamingAwareError(c, status, code, message, streamStarted)
+		return
+	}
+
+	if req.images > 0 && h.apiKeyService != nil {
+		if err := h.apiKeyService.CheckImageQuota(c.Request.Context(), apiKey, req.images); err != nil {
+			reqLog.Info(logPrefix+"image_quota_check_failed", zap.Error(err))
+			if errors.Is(err, service.ErrAPIKeyImageQuotaExhausted) {
+				h.handleStreamingAwareError(c, http.StatusTooManyRequests, "rate_limit_error", "API key image quota exhausted", streamStarted)
+			} else {
+				h.handleStreamingAwareError(c, http.StatusInternalServerError, "api_error", "Failed to check image quota", streamStarted)
+			}
+			return
+		}
+	}
+
+	sessionHash := h.gatewayService.GenerateSessionHash(c, body)
+	promptCacheKey := h.gatewayService.ExtractSessionID(c, body)
+
+	maxAccountSwitches := h.maxAccountSwitches
+	switchCount := 0
+	failedAccountIDs := make(map[int64]struct{})
+	var lastFailoverErr *service.UpstreamFailoverError
+	unsupportedSkipped := false
+
+	for {
+		// 清除上一次迭代的降级模型标记，避免残留影响本次迭代
+		c.Set(fallbackKey, "")
+		reqLog.Debug(logPrefix+"account_selecting", zap.Int("excluded_account_count", len(failedAccountIDs)))
+		selection, _, err := h.gatewayService.SelectAccountWithScheduler(
+			c.Request.Context(),
+			apiKey.GroupID,
+			"",
+			sessionHash,
+			reqModel,
+			failedAccountIDs,
+			service.OpenAIUpstreamTransportAny,
+		)
+		if err != nil {
+			reqLog.Warn(logPrefix+"account_select_failed",
+				zap.Error(err),
+				zap.Int("excluded_account_count", len(failedAccountIDs)),
+			)
+			if len(failedAccountIDs) != 0 {
+				if lastFailoverErr != nil {
+					h.handleFailoverExhausted(c, lastFailoverErr, streamStarted)
+				} else if unsupportedSkipped {
+					h.handleStreamingAwareError(c, http.StatusServiceUnavailable, "api_error", "No available accounts support this endpoint", streamStarted)
+				} else {
+					h.handleStreamingAwareError(c, http.StatusBadGateway, "upstream_error", "Upstream request failed", streamStarted)
+				}
+				return
+			}
+			// 首次调度失败 + 有默认映射模型 → 用默认模型重试
+			defaultModel := ""
+			if apiKey.Group != nil {
+				defaultModel = apiKey.Group.DefaultMappedModel
+			}
+			if defaultModel != "" && defaultModel != reqModel {
+				reqLog.Info(logPrefix+"fallback_to_default_model",
+					zap.String("default_mapped_model", defaultModel),
+				)
+				selection, _, err = h.gatewayService.SelectAccountWithScheduler(
+					c.Request.Context(),
+					apiKey.GroupID,
+					"",
+					sessionHash,
+					defaultModel,
+					failedAccountIDs,
+					service.OpenAIUpstreamTransportAny,
+				)
+				if err == nil && selection != nil {
+					c.Set(fallbackKey, defaultModel)
+				}
+			}
+			if err != nil {
+				h.handleStreamingAwareError(c, http.StatusServiceUnavailable, "api_error", "Service temporarily unavailable", streamStarted)
+				return
+			}
+		}
+		if selection == nil || selection.Account == nil {
+			h.handleStreamingAwareError(c, http.StatusServiceUnavailable, "api_error", "No available accounts", streamStarted)
+			return
+		}
+		account := selection.Account
+		if ep.supportsAccount != nil && !ep.supportsAccount(account) {
+			if selection.Acquired && selection.ReleaseFunc != nil {
+				selection.ReleaseFunc()
+			}
+			reqLog.Debug(logPrefix+"account_unsupported_skipped", zap.Int64("account_id", account.ID), zap.String("account_type", account.Type))
+			failedAccountIDs[account.ID] = struct{}{}
+			unsupportedSkipped = true
+			continue
+		}
+		reqLog.Debug(logPrefix+"account_selected", zap.Int64("account_id", account.ID), zap.String("account_name", account.Name))
+		setOpsSelectedAccount(c, account.ID, account.Platform)
+
+		accountReleaseFunc, acquired := h.acquireResponsesAccountSlot(c, apiKey.GroupID, sessionHash, selection, reqStream, &streamStarted, reqLog)
+		if !acquired {
+			return
+		}
+
+		service.SetOpsLatencyMs(c, service.OpsRoutingLatencyMsKey, time.Since(routingStart).Milliseconds())
+		forwardStart := time.Now()
+
+		defaultMappedModel := ""
+		if apiKey.Group != nil {
+			defaultMappedModel = apiKey.Group.DefaultMappedModel
+		}
+		// 如果使用了降级模型调度，强制使用降级模型
+		if fallbackModel := c.GetString(fallbackKey); fallbackModel != "" {
+			defaultMappedModel = fallbackModel
+		}
+		result, err := ep.forward(c.Request.Context(), c, account, body, promptCacheKey, defaultMappedModel)
+
+		forwardDurationMs := time.Since(forwardStart).Milliseconds()
+		if accountReleaseFunc != nil {
+			accountReleaseFunc()
+		}
+		upstreamLatencyMs, _ := getContextInt64(c, service.OpsUpstreamLatencyMsKey)
+		responseLatencyMs := forwardDurationMs
+		if upstreamLatencyMs > 0 && forwardDurationMs > upstreamLatencyMs {
+			responseLatencyMs = forwardDurationMs - upstreamLatencyMs
+		}
+		service.SetOpsLatencyMs(c, service.OpsResponseLatencyMsKey, responseLatencyMs)
+		if err == nil && result != nil && result.FirstTokenMs != nil {
+			service.SetOpsLatencyMs(c, service.OpsTimeToFirstTokenMsKey, int64(*result.FirstTokenMs))
+		}
+		if err != nil {
+			var failoverErr *service.UpstreamFailoverError
+			if errors.As(err, &failoverErr) {
+				h.gatewayService.ReportOpenAIAccountScheduleResult(account.ID, false, nil)
+				h.gatewayService.RecordOpenAIAccountSwitch()
+				failedAccountIDs[account.ID] = struct{}{}
+				lastFailoverErr = failoverErr
+				// 流式输出中途断开续传时响应已开始写出，后续错误需以 SSE 事件下发
+				if c.Writer.Written() {
+					streamStarted = true
+				}
+				if switchCount >= maxAccountSwitches {
+					h.handleFailoverExhausted(c, failoverErr, streamStarted)
+					return
+				}
+				switchCount++
+				reqLog.Warn(logPrefix+"upstream_failover_switching",
+					zap.Int64("account_id", account.ID),
+					zap.Int("upstream_status", failoverErr.StatusCode),
+					zap.Int("switch_count", switchCount),
+					zap.Int("max_switches", maxAccountSwitches),
+				)
+				continue
+			}
+			h.gatewayService.ReportOpenAIAccountScheduleResult(account.ID, false, nil)
+			if isClientCanceledForwardError(c, err) {
+				if c != nil && c.Writer != nil && !c.Writer.Written() {
+					c.Status(499)
+				}
+				reqLog.Info(logPrefix+"forward_canceled",
+					zap.Int64("account_id", account.ID),
+					zap.Error(err),
+				)
+				return
+			}
+			wroteFallback := h.ensureForwardErrorResponse(c, streamStarted)
+			reqLog.Warn(logPrefix+"forward_failed",
+				zap.Int64("account_id", account.ID),
+				zap.Bool("fallback_error_response_written", wroteFallback),
+				zap.Error(err),
+			)
+			return
+		}
+		if result != nil {
+			h.gatewayService.ReportOpenAIAccountScheduleResult(account.ID, true, result.FirstTokenMs)
+		} else {
+			h.gatewayService.ReportOpenAIAccountScheduleResult(account.ID, true, nil)
+		}
+
+		userAgent := c.GetHeader("User-Agent")
+		clientIP := ip.GetClientIP(c)
+
+		var inputTokenEstimator func() int
+		if ep.countInputTokens != nil {
+			estimateBody := body
+			inputTokenEstimator = func() int { return ep.countInputTokens(reqModel, estimateBody) }
+		}
+
+		h.submitUsageRecordTask(c, func(ctx context.Context) {
+			if err := h.gatewayService.RecordUsage(ctx, &service.OpenAIRecordUsageInput{
+				Result:              result,
+				APIKey:              apiKey,
+				User:                apiKey.User,
+				Account:             account,
+				Subscription:        subscription,
+				UserAgent:           userAgent,
+				IPAddress:           clientIP,
+				APIKeyService:       h.apiKeyService,
+				InputTokenEstimator: inputTokenEstimator,
+			}); err != nil {
+				logger.L().With(
+					zap.String("component", "handler.openai_gateway."+ep.name),
+					zap.Int64("user_id", subject.UserID),
+					zap.Int64("api_key_id", apiKey.ID),
+					zap.Any("group_id", apiKey.GroupID),
+					zap.String("model", reqModel),
+					zap.Int64("account_id", account.ID),
+				).Error(logPrefix+"record_usage_failed", zap.Error(err))
+			}
+			if result != nil && result.ImageCount > 0 && h.apiKeyService != nil {
+				if err := h.apiKeyService.UpdateImageQuotaUsed(ctx, apiKey.ID, result.ImageCount); err != nil {
+					logger.L().With(
+						zap.String("component", "handler.openai_gateway."+ep.name),
+						zap.Int64("api_key_id", apiKey.ID),
+					).Error(logPrefix+"update_image_quota_failed", zap.Error(err))
+				}
+			}
+		})
+		reqLog.Debug(logPrefix+"request_completed",
+			zap.Int64("account_id", account.ID),
+			zap.Int("switch_count", switchCount),
+		)
+		return
+	}
+}
+
+func (h *OpenAIGatewayHandler) recoverOpenAICompatPanic(c *gin.Context, name string, streamStarted *bool) {
+	recovered := recover()
+	if recovered == nil {
+		return
+	}
+
+	started := streamStarted != nil && *streamStarted
+	wroteFallback := h.ensureForwardErrorResponse(c, started)
+	requestLogger(c, "handler.openai_gateway."+name).Error(
+		"openai."+name+"_panic_recovered",
+		zap.Bool("fallback_error_response_written", wroteFallback),
+		zap.Any("panic", recovered),
+		zap.ByteString("stack", debug.Stack()),
 	)
 }
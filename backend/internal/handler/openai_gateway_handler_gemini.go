@@ -0,0 +1,322 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"runtime/debug"
+	"strings"
+	"time"
+
+	pkghttputil "github.com/ShaohongDong/sub2api/internal/pkg/httputil"
+	"github.com/ShaohongDong/sub2api/internal/pkg/ip"
+	"github.com/ShaohongDong/sub2api/internal/pkg/logger"
+	middleware2 "github.com/ShaohongDong/sub2api/internal/server/middleware"
+	"github.com/ShaohongDong/sub2api/internal/service"
+
+	"github.com/gin-gonic/gin"
+	"github.com/tidwall/gjson"
+	"go.uber.org/zap"
+)
+
+// GeminiGenerateContent handles Gemini native generateContent /
+// streamGenerateContent requests for OpenAI platform groups:
+// POST /v1beta/models/{model}:{generateContent|streamGenerateContent}
+//
+// The request is converted to the Responses API and the reply converted back,
+// so Google SDK clients can use OpenAI groups unchanged.
+func (h *OpenAIGatewayHandler) GeminiGenerateContent(c *gin.Context) {
+	streamStarted := false
+	defer h.recoverGeminiGenerateContentPanic(c, &streamStarted)
+
+	requestStart := time.Now()
+
+	apiKey, ok := middleware2.GetAPIKeyFromContext(c)
+	if !ok {
+		googleError(c, http.StatusUnauthorized, "Invalid API key")
+		return
+	}
+
+	subject, ok := middleware2.GetAuthSubjectFromContext(c)
+	if !ok {
+		googleError(c, http.StatusInternalServerError, "User context not found")
+		return
+	}
+	reqLog := requestLogger(
+		c,
+		"handler.openai_gateway.gemini",
+		zap.Int64("user_id", subject.UserID),
+		zap.Int64("api_key_id", apiKey.ID),
+		zap.Any("group_id", apiKey.GroupID),
+	)
+
+	modelName, action, err := parseGeminiModelAction(strings.TrimPrefix(c.Param("modelAction"), "/"))
+	if err != nil {
+		googleError(c, http.StatusNotFound, err.Error())
+		return
+	}
+	if action != "generateContent" && action != "streamGenerateContent" {
+		googleError(c, http.StatusNotFound, "Action "+action+" is not supported for this platform")
+		return
+	}
+	reqStream := action == "streamGenerateContent"
+	reqLog = reqLog.With(zap.String("model", modelName), zap.String("action", action), zap.Bool("stream", reqStream))
+
+	if !h.ensureResponsesDependencies(c, reqLog) {
+		return
+	}
+
+	body, err := pkghttputil.ReadRequestBodyWithPrealloc(c.Request)
+	if err != nil {
+		if maxErr, ok := extractMaxBytesError(err); ok {
+			googleError(c, http.StatusRequestEntityTooLarge, buildBodyTooLargeMessage(maxErr.Limit))
+			return
+		}
+		googleError(c, http.StatusBadRequest, "Failed to read request body")
+		return
+	}
+	if len(body) == 0 {
+		googleError(c, http.StatusBadRequest, "Request body is empty")
+		return
+	}
+	if !gjson.ValidBytes(body) {
+		googleError(c, http.StatusBadRequest, "Failed to parse request body")
+		return
+	}
+
+	setOpsRequestContext(c, modelName, reqStream, body)
+
+	if !apiKey.AllowsModel(clientRequestedModel(c, modelName)) {
+		googleError(c, http.StatusForbidden, apiKeyModelNotAllowedMessage(clientRequestedModel(c, modelName)))
+		return
+	}
+
+	if h.errorPassthroughService != nil {
+		service.BindErrorPassthroughService(c, h.errorPassthroughService)
+	}
+
+	subscription, _ := middleware2.GetSubscriptionFromContext(c)
+
+	service.SetOpsLatencyMs(c, service.OpsAuthLatencyMsKey, time.Since(requestStart).Milliseconds())
+	routingStart := time.Now()
+
+	// Gemini 原生协议没有 ping 帧，排队等待期间不发送 SSE 心跳。
+	userReleaseFunc, acquired := h.acquireResponsesUserSlot(c, subject.UserID, subject.Concurrency, false, &streamStarted, reqLog)
+	if !acquired {
+		return
+	}
+	if userReleaseFunc != nil {
+		defer userReleaseFunc()
+	}
+
+	if err := h.billingCacheService.CheckBillingEligibility(c.Request.Context(), apiKey.User, apiKey, apiKey.Group, subscription); err != nil {
+		reqLog.Info("openai_gemini.billing_eligibility_check_failed", zap.Error(err))
+		status, _, message := billingErrorDetails(err)
+		googleError(c, status, message)
+		return
+	}
+
+	sessionHash := h.gatewayService.GenerateSessionHash(c, body)
+	promptCacheKey := h.gatewayService.ExtractSessionID(c, body)
+
+	maxAccountSwitches := h.maxAccountSwitches
+	switchCount := 0
+	failedAccountIDs := make(map[int64]struct{})
+	var lastFailoverErr *service.UpstreamFailoverError
+
+	for {
+		// 清除上一次迭代的降级模型标记，避免残留影响本次迭代
+		c.Set("openai_gemini_fallback_model", "")
+		reqLog.Debug("openai_gemini.account_selecting", zap.Int("excluded_account_count", len(failedAccountIDs)))
+		selection, _, err := h.gatewayService.SelectAccountWithScheduler(
+			c.Request.Context(),
+			apiKey.GroupID,
+			"",
+			sessionHash,
+			modelName,
+			failedAccountIDs,
+			service.OpenAIUpstreamTransportAny,
+		)
+		if err != nil {
+			reqLog.Warn("openai_gemini.account_select_failed",
+				zap.Error(err),
+				zap.Int("excluded_account_count", len(failedAccountIDs)),
+			)
+			if len(failedAccountIDs) != 0 {
+				h.handleGeminiFailoverExhausted(c, lastFailoverErr)
+				return
+			}
+			// 首次调度失败 + 有默认映射模型 → 用默认模型重试
+			defaultModel := ""
+			if apiKey.Group != nil {
+				defaultModel = apiKey.Group.DefaultMappedModel
+			}
+			if defaultModel != "" && defaultModel != modelName {
+				reqLog.Info("openai_gemini.fallback_to_default_model",
+					zap.String("default_mapped_model", defaultModel),
+				)
+				selection, _, err = h.gatewayService.SelectAccountWithScheduler(
+					c.Request.Context(),
+					apiKey.GroupID,
+					"",
+					sessionHash,
+					defaultModel,
+					failedAccountIDs,
+					service.OpenAIUpstreamTransportAny,
+				)
+				if err == nil && selection != nil {
+					c.Set("openai_gemini_fallback_model", defaultModel)
+				}
+			}
+			if err != nil {
+				googleError(c, http.StatusServiceUnavailable, "Service temporarily unavailable")
+				return
+			}
+		}
+		if selection == nil || selection.Account == nil {
+			googleError(c, http.StatusServiceUnavailable, "No available accounts")
+			return
+		}
+		account := selection.Account
+		reqLog.Debug("openai_gemini.account_selected", zap.Int64("account_id", account.ID), zap.String("account_name", account.Name))
+		setOpsSelectedAccount(c, account.ID, account.Platform)
+
+		accountReleaseFunc, acquired := h.acquireResponsesAccountSlot(c, apiKey.GroupID, sessionHash, selection, false, &streamStarted, reqLog)
+		if !acquired {
+			return
+		}
+
+		service.SetOpsLatencyMs(c, service.OpsRoutingLatencyMsKey, time.Since(routingStart).Milliseconds())
+		forwardStart := time.Now()
+
+		defaultMappedModel := ""
+		if apiKey.Group != nil {
+			defaultMappedModel = apiKey.Group.DefaultMappedModel
+		}
+		if fallbackModel := c.GetString("openai_gemini_fallback_model"); fallbackModel != "" {
+			defaultMappedModel = fallbackModel
+		}
+		result, err := h.gatewayService.ForwardAsGemini(c.Request.Context(), c, account, body, modelName, reqStream, promptCacheKey, defaultMappedModel)
+
+		forwardDurationMs := time.Since(forwardStart).Milliseconds()
+		if accountReleaseFunc != nil {
+			accountReleaseFunc()
+		}
+		upstreamLatencyMs, _ := getContextInt64(c, service.OpsUpstreamLatencyMsKey)
+		responseLatencyMs := forwardDurationMs
+		if upstreamLatencyMs > 0 && forwardDurationMs > upstreamLatencyMs {
+			responseLatencyMs = forwardDurationMs - upstreamLatencyMs
+		}
+		service.SetOpsLatencyMs(c, service.OpsResponseLatencyMsKey, responseLatencyMs)
+		if err == nil && result != nil && result.FirstTokenMs != nil {
+			service.SetOpsLatencyMs(c, service.OpsTimeToFirstTokenMsKey, int64(*result.FirstTokenMs))
+		}
+		if err != nil {
+			var failoverErr *service.UpstreamFailoverError
+			if errors.As(err, &failoverErr) {
+				h.gatewayService.ReportOpenAIAccountScheduleResult(account.ID, false, nil)
+				h.gatewayService.RecordOpenAIAccountSwitch()
+				failedAccountIDs[account.ID] = struct{}{}
+				lastFailoverErr = failoverErr
+				if switchCount >= maxAccountSwitches {
+					h.handleGeminiFailoverExhausted(c, failoverErr)
+					return
+				}
+				switchCount++
+				reqLog.Warn("openai_gemini.upstream_failover_switching",
+					zap.Int64("account_id", account.ID),
+					zap.Int("upstream_status", failoverErr.StatusCode),
+					zap.Int("switch_count", switchCount),
+					zap.Int("max_switches", maxAccountSwitches),
+				)
+				continue
+			}
+			h.gatewayService.ReportOpenAIAccountScheduleResult(account.ID, false, nil)
+			if isClientCanceledForwardError(c, err) {
+				if c != nil && c.Writer != nil && !c.Writer.Written() {
+					c.Status(499)
+				}
+				reqLog.Info("openai_gemini.forward_canceled",
+					zap.Int64("account_id", account.ID),
+					zap.Error(err),
+				)
+				return
+			}
+			wroteFallback := false
+			if c.Writer != nil && !c.Writer.Written() {
+				googleError(c, http.StatusBadGateway, "Upstream request failed")
+				wroteFallback = true
+			}
+			reqLog.Warn("openai_gemini.forward_failed",
+				zap.Int64("account_id", account.ID),
+				zap.Bool("fallback_error_response_written", wroteFallback),
+				zap.Error(err),
+			)
+			return
+		}
+		if result != nil {
+			h.gatewayService.ReportOpenAIAccountScheduleResult(account.ID, true, result.FirstTokenMs)
+		} else {
+			h.gatewayService.ReportOpenAIAccountScheduleResult(account.ID, true, nil)
+		}
+
+		userAgent := c.GetHeader("User-Agent")
+		clientIP := ip.GetClientIP(c)
+
+		h.submitUsageRecordTask(c, func(ctx context.Context) {
+			if err := h.gatewayService.RecordUsage(ctx, &service.OpenAIRecordUsageInput{
+				Result:        result,
+				APIKey:        apiKey,
+				User:          apiKey.User,
+				Account:       account,
+				Subscription:  subscription,
+				UserAgent:     userAgent,
+				IPAddress:     clientIP,
+				APIKeyService: h.apiKeyService,
+			}); err != nil {
+				logger.L().With(
+					zap.String("component", "handler.openai_gateway.gemini"),
+					zap.Int64("user_id", subject.UserID),
+					zap.Int64("api_key_id", apiKey.ID),
+					zap.Any("group_id", apiKey.GroupID),
+					zap.String("model", modelName),
+					zap.Int64("account_id", account.ID),
+				).Error("openai_gemini.record_usage_failed", zap.Error(err))
+			}
+		})
+		reqLog.Debug("openai_gemini.request_completed",
+			zap.Int64("account_id", account.ID),
+			zap.Int("switch_count", switchCount),
+		)
+		return
+	}
+}
+
+// handleGeminiFailoverExhausted maps upstream failover errors to Google API format.
+func (h *OpenAIGatewayHandler) handleGeminiFailoverExhausted(c *gin.Context, failoverErr *service.UpstreamFailoverError) {
+	setUpstreamRetryAfter(c, failoverErr)
+	if failoverErr == nil {
+		googleError(c, http.StatusBadGateway, "Upstream request failed")
+		return
+	}
+	status, _, errMsg := h.mapUpstreamError(failoverErr.StatusCode)
+	googleError(c, status, errMsg)
+}
+
+func (h *OpenAIGatewayHandler) recoverGeminiGenerateContentPanic(c *gin.Context, streamStarted *bool) {
+	recovered := recover()
+	if recovered == nil {
+		return
+	}
+
+	started := streamStarted != nil && *streamStarted
+	requestLogger(c, "handler.openai_gateway.gemini").Error(
+		"openai.gemini_panic_recovered",
+		zap.Bool("stream_started", started),
+		zap.Any("panic", recovered),
+		zap.ByteString("stack", debug.Stack()),
+	)
+	if !started {
+		googleError(c, http.StatusInternalServerError, "Internal server error")
+	}
+}
@@ -0,0 +1,46 @@
+package handler
+
+import (
+	"github.com/ShaohongDong/sub2api/internal/service"
+
+	"github.com/gin-gonic/gin"
+	"github.com/tidwall/gjson"
+)
+
+// ImageGenerations handles image generation requests for OpenAI platform
+// groups: POST /v1/images/generations
+//
+// API key accounts are passed through to the Images API; ChatGPT OAuth
+// accounts on a plan with image models generate through the Responses
+// image_generation tool. The requested image count is checked against the
+// API key image quota before scheduling.
+func (h *OpenAIGatewayHandler) ImageGenerations(c *gin.Context) {
+	h.serveOpenAICompat(c, openAICompatEndpoint{
+		name:            "images",
+		parse:           parseImageGenerationsRequest,
+		supportsAccount: service.SupportsOpenAIImages,
+		forward:         h.gatewayService.ForwardImageGeneration,
+	})
+}
+
+func parseImageGenerationsRequest(_ *gin.Context, body []byte) (openAICompatRequest, string) {
+	req, msg := parseOpenAICompatJSON(body, validateImageGenerationsBody)
+	if msg != "" {
+		return req, msg
+	}
+	req.images = 1
+	if n := gjson.GetBytes(body, "n"); n.Exists() && n.Int() > 0 {
+		req.images = int(n.Int())
+	}
+	return req, ""
+}
+
+func validateImageGenerationsBody(body []byte) string {
+	if gjson.GetBytes(body, "prompt").String() == "" {
+		return "prompt is required"
+	}
+	if n := gjson.GetBytes(body, "n"); n.Exists() && (n.Type != gjson.Number || n.Int() < 1 || n.Int() > 10) {
+		return "n must be an integer between 1 and 10"
+	}
+	return ""
+}
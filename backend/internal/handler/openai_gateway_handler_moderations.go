@@ -0,0 +1,76 @@
+package handler
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/ShaohongDong/sub2api/internal/config"
+	pkghttputil "github.com/ShaohongDong/sub2api/internal/pkg/httputil"
+	"github.com/ShaohongDong/sub2api/internal/pkg/moderation"
+	"github.com/ShaohongDong/sub2api/internal/service"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/tidwall/gjson"
+)
+
+// Moderations handles content moderation requests: POST /v1/moderations
+//
+// With gateway.moderation.mode=upstream the body is passed through to API key
+// accounts of OpenAI groups. With mode=local the gateway answers from its
+// rule classifier without scheduling an account, for any group platform.
+func (h *OpenAIGatewayHandler) Moderations(c *gin.Context) {
+	if h.cfg != nil && h.cfg.Gateway.Moderation.Mode == config.ModerationModeLocal {
+		h.serveLocalModerations(c)
+		return
+	}
+	h.serveOpenAICompat(c, openAICompatEndpoint{
+		name:            "moderations",
+		parse:           parseModerationsRequest,
+		supportsAccount: service.SupportsOpenAIPlatformAPI,
+		forward:         h.gatewayService.ForwardModerations,
+	})
+}
+
+// parseModerationsRequest 校验审核请求；model 为可选字段，缺省时按默认审核模型调度。
+func parseModerationsRequest(_ *gin.Context, body []byte) (openAICompatRequest, string) {
+	if !gjson.ValidBytes(body) {
+		return openAICompatRequest{}, "Failed to parse request body"
+	}
+	if _, err := moderation.ParseInputs(body); err != nil {
+		return openAICompatRequest{}, err.Error()
+	}
+	model := gjson.GetBytes(body, "model").String()
+	if model == "" {
+		model = moderation.DefaultModel
+	}
+	return openAICompatRequest{model: model, opsBody: body}, ""
+}
+
+func (h *OpenAIGatewayHandler) serveLocalModerations(c *gin.Context) {
+	body, err := pkghttputil.ReadRequestBodyWithPrealloc(c.Request)
+	if err != nil {
+		if maxErr, ok := extractMaxBytesError(err); ok {
+			h.errorResponse(c, http.StatusRequestEntityTooLarge, "invalid_request_error", buildBodyTooLargeMessage(maxErr.Limit))
+			return
+		}
+		h.errorResponse(c, http.StatusBadRequest, "invalid_request_error", "Failed to read request body")
+		return
+	}
+	req, msg := parseModerationsRequest(c, body)
+	if msg != "" {
+		h.errorResponse(c, http.StatusBadRequest, "invalid_request_error", msg)
+		return
+	}
+	inputs, _ := moderation.ParseInputs(body)
+	results, err := h.moderationClassifier.Classify(c.Request.Context(), inputs)
+	if err != nil {
+		h.errorResponse(c, http.StatusInternalServerError, "api_error", "Moderation failed")
+		return
+	}
+	c.JSON(http.StatusOK, moderation.Response{
+		ID:      "modr-" + strings.ReplaceAll(uuid.NewString(), "-", ""),
+		Model:   req.model,
+		Results: results,
+	})
+}
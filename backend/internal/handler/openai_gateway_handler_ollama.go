@@ -0,0 +1,343 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"runtime/debug"
+	"strings"
+	"time"
+
+	pkghttputil "github.com/ShaohongDong/sub2api/internal/pkg/httputil"
+	"github.com/ShaohongDong/sub2api/internal/pkg/ip"
+	"github.com/ShaohongDong/sub2api/internal/pkg/logger"
+	middleware2 "github.com/ShaohongDong/sub2api/internal/server/middleware"
+	"github.com/ShaohongDong/sub2api/internal/service"
+
+	"github.com/gin-gonic/gin"
+	"github.com/tidwall/gjson"
+	"go.uber.org/zap"
+)
+
+// OllamaChat handles Ollama chat requests for OpenAI platform groups:
+// POST /api/chat
+func (h *OpenAIGatewayHandler) OllamaChat(c *gin.Context) {
+	h.handleOllama(c, service.OllamaEndpointChat)
+}
+
+// OllamaGenerate handles Ollama completion requests for OpenAI platform groups:
+// POST /api/generate
+func (h *OpenAIGatewayHandler) OllamaGenerate(c *gin.Context) {
+	h.handleOllama(c, service.OllamaEndpointGenerate)
+}
+
+// handleOllama converts Ollama requests to the Responses API and the reply
+// back, so tools that only speak Ollama can use OpenAI groups.
+func (h *OpenAIGatewayHandler) handleOllama(c *gin.Context, endpoint string) {
+	streamStarted := false
+	defer h.recoverOllamaPanic(c, &streamStarted)
+
+	requestStart := time.Now()
+
+	apiKey, ok := middleware2.GetAPIKeyFromContext(c)
+	if !ok {
+		ollamaError(c, http.StatusUnauthorized, "Invalid API key")
+		return
+	}
+
+	subject, ok := middleware2.GetAuthSubjectFromContext(c)
+	if !ok {
+		ollamaError(c, http.StatusInternalServerError, "User context not found")
+		return
+	}
+	reqLog := requestLogger(
+		c,
+		"handler.openai_gateway.ollama",
+		zap.String("endpoint", endpoint),
+		zap.Int64("user_id", subject.UserID),
+		zap.Int64("api_key_id", apiKey.ID),
+		zap.Any("group_id", apiKey.GroupID),
+	)
+
+	if !h.ensureResponsesDependencies(c, reqLog) {
+		return
+	}
+
+	body, err := pkghttputil.ReadRequestBodyWithPrealloc(c.Request)
+	if err != nil {
+		if maxErr, ok := extractMaxBytesError(err); ok {
+			ollamaError(c, http.StatusRequestEntityTooLarge, buildBodyTooLargeMessage(maxErr.Limit))
+			return
+		}
+		ollamaError(c, http.StatusBadRequest, "Failed to read request body")
+		return
+	}
+	if len(body) == 0 {
+		ollamaError(c, http.StatusBadRequest, "Request body is empty")
+		return
+	}
+	if !gjson.ValidBytes(body) {
+		ollamaError(c, http.StatusBadRequest, "Failed to parse request body")
+		return
+	}
+
+	modelResult := gjson.GetBytes(body, "model")
+	if !modelResult.Exists() || modelResult.Type != gjson.String || modelResult.String() == "" {
+		ollamaError(c, http.StatusBadRequest, "model is required")
+		return
+	}
+	reqModel := normalizeOllamaModelName(modelResult.String())
+	// Ollama 默认流式输出，stream 缺省视为 true
+	reqStream := true
+	if stream := gjson.GetBytes(body, "stream"); stream.Exists() {
+		reqStream = stream.Bool()
+	}
+	reqLog = reqLog.With(zap.String("model", reqModel), zap.Bool("stream", reqStream))
+
+	setOpsRequestContext(c, reqModel, reqStream, body)
+
+	if !apiKey.AllowsModel(clientRequestedModel(c, reqModel)) {
+		ollamaError(c, http.StatusForbidden, apiKeyModelNotAllowedMessage(clientRequestedModel(c, reqModel)))
+		return
+	}
+
+	if h.errorPassthroughService != nil {
+		service.BindErrorPassthroughService(c, h.errorPassthroughService)
+	}
+
+	subscription, _ := middleware2.GetSubscriptionFromContext(c)
+
+	service.SetOpsLatencyMs(c, service.OpsAuthLatencyMsKey, time.Since(requestStart).Milliseconds())
+	routingStart := time.Now()
+
+	// Ollama NDJSON 协议没有 ping 帧，排队等待期间不发送心跳。
+	userReleaseFunc, acquired := h.acquireResponsesUserSlot(c, subject.UserID, subject.Concurrency, false, &streamStarted, reqLog)
+	if !acquired {
+		return
+	}
+	if userReleaseFunc != nil {
+		defer userReleaseFunc()
+	}
+
+	if err := h.billingCacheService.CheckBillingEligibility(c.Request.Context(), apiKey.User, apiKey, apiKey.Group, subscription); err != nil {
+		reqLog.Info("openai_ollama.billing_eligibility_check_failed", zap.Error(err))
+		status, _, message := billingErrorDetails(err)
+		ollamaError(c, status, message)
+		return
+	}
+
+	sessionHash := h.gatewayService.GenerateSessionHash(c, body)
+	promptCacheKey := h.gatewayService.ExtractSessionID(c, body)
+
+	maxAccountSwitches := h.maxAccountSwitches
+	switchCount := 0
+	failedAccountIDs := make(map[int64]struct{})
+	var lastFailoverErr *service.UpstreamFailoverError
+
+	for {
+		// 清除上一次迭代的降级模型标记，避免残留影响本次迭代
+		c.Set("openai_ollama_fallback_model", "")
+		reqLog.Debug("openai_ollama.account_selecting", zap.Int("excluded_account_count", len(failedAccountIDs)))
+		selection, _, err := h.gatewayService.SelectAccountWithScheduler(
+			c.Request.Context(),
+			apiKey.GroupID,
+			"",
+			sessionHash,
+			reqModel,
+			failedAccountIDs,
+			service.OpenAIUpstreamTransportAny,
+		)
+		if err != nil {
+			reqLog.Warn("openai_ollama.account_select_failed",
+				zap.Error(err),
+				zap.Int("excluded_account_count", len(failedAccountIDs)),
+			)
+			if len(failedAccountIDs) != 0 {
+				h.handleOllamaFailoverExhausted(c, lastFailoverErr)
+				return
+			}
+			// 首次调度失败 + 有默认映射模型 → 用默认模型重试
+			defaultModel := ""
+			if apiKey.Group != nil {
+				defaultModel = apiKey.Group.DefaultMappedModel
+			}
+			if defaultModel != "" && defaultModel != reqModel {
+				reqLog.Info("openai_ollama.fallback_to_default_model",
+					zap.String("default_mapped_model", defaultModel),
+				)
+				selection, _, err = h.gatewayService.SelectAccountWithScheduler(
+					c.Request.Context(),
+					apiKey.GroupID,
+					"",
+					sessionHash,
+					defaultModel,
+					failedAccountIDs,
+					service.OpenAIUpstreamTransportAny,
+				)
+				if err == nil && selection != nil {
+					c.Set("openai_ollama_fallback_model", defaultModel)
+				}
+			}
+			if err != nil {
+				ollamaError(c, http.StatusServiceUnavailable, "Service temporarily unavailable")
+				return
+			}
+		}
+		if selection == nil || selection.Account == nil {
+			ollamaError(c, http.StatusServiceUnavailable, "No available accounts")
+			return
+		}
+		account := selection.Account
+		reqLog.Debug("openai_ollama.account_selected", zap.Int64("account_id", account.ID), zap.String("account_name", account.Name))
+		setOpsSelectedAccount(c, account.ID, account.Platform)
+
+		accountReleaseFunc, acquired := h.acquireResponsesAccountSlot(c, apiKey.GroupID, sessionHash, selection, false, &streamStarted, reqLog)
+		if !acquired {
+			return
+		}
+
+		service.SetOpsLatencyMs(c, service.OpsRoutingLatencyMsKey, time.Since(routingStart).Milliseconds())
+		forwardStart := time.Now()
+
+		defaultMappedModel := ""
+		if apiKey.Group != nil {
+			defaultMappedModel = apiKey.Group.DefaultMappedModel
+		}
+		if fallbackModel := c.GetString("openai_ollama_fallback_model"); fallbackModel != "" {
+			defaultMappedModel = fallbackModel
+		}
+		result, err := h.gatewayService.ForwardAsOllama(c.Request.Context(), c, account, body, endpoint, reqModel, promptCacheKey, defaultMappedModel)
+
+		forwardDurationMs := time.Since(forwardStart).Milliseconds()
+		if accountReleaseFunc != nil {
+			accountReleaseFunc()
+		}
+		upstreamLatencyMs, _ := getContextInt64(c, service.OpsUpstreamLatencyMsKey)
+		responseLatencyMs := forwardDurationMs
+		if upstreamLatencyMs > 0 && forwardDurationMs > upstreamLatencyMs {
+			responseLatencyMs = forwardDurationMs - upstreamLatencyMs
+		}
+		service.SetOpsLatencyMs(c, service.OpsResponseLatencyMsKey, responseLatencyMs)
+		if err == nil && result != nil && result.FirstTokenMs != nil {
+			service.SetOpsLatencyMs(c, service.OpsTimeToFirstTokenMsKey, int64(*result.FirstTokenMs))
+		}
+		if err != nil {
+			var failoverErr *service.UpstreamFailoverError
+			if errors.As(err, &failoverErr) {
+				h.gatewayService.ReportOpenAIAccountScheduleResult(account.ID, false, nil)
+				h.gatewayService.RecordOpenAIAccountSwitch()
+				failedAccountIDs[account.ID] = struct{}{}
+				lastFailoverErr = failoverErr
+				if switchCount >= maxAccountSwitches {
+					h.handleOllamaFailoverExhausted(c, failoverErr)
+					return
+				}
+				switchCount++
+				reqLog.Warn("openai_ollama.upstream_failover_switching",
+					zap.Int64("account_id", account.ID),
+					zap.Int("upstream_status", failoverErr.StatusCode),
+					zap.Int("switch_count", switchCount),
+					zap.Int("max_switches", maxAccountSwitches),
+				)
+				continue
+			}
+			h.gatewayService.ReportOpenAIAccountScheduleResult(account.ID, false, nil)
+			if isClientCanceledForwardError(c, err) {
+				if c != nil && c.Writer != nil && !c.Writer.Written() {
+					c.Status(499)
+				}
+				reqLog.Info("openai_ollama.forward_canceled",
+					zap.Int64("account_id", account.ID),
+					zap.Error(err),
+				)
+				return
+			}
+			wroteFallback := false
+			if c.Writer != nil && !c.Writer.Written() {
+				ollamaError(c, http.StatusBadGateway, "Upstream request failed")
+				wroteFallback = true
+			}
+			reqLog.Warn("openai_ollama.forward_failed",
+				zap.Int64("account_id", account.ID),
+				zap.Bool("fallback_error_response_written", wroteFallback),
+				zap.Error(err),
+			)
+			return
+		}
+		if result != nil {
+			h.gatewayService.ReportOpenAIAccountScheduleResult(account.ID, true, result.FirstTokenMs)
+		} else {
+			h.gatewayService.ReportOpenAIAccountScheduleResult(account.ID, true, nil)
+		}
+
+		userAgent := c.GetHeader("User-Agent")
+		clientIP := ip.GetClientIP(c)
+
+		h.submitUsageRecordTask(c, func(ctx context.Context) {
+			if err := h.gatewayService.RecordUsage(ctx, &service.OpenAIRecordUsageInput{
+				Result:        result,
+				APIKey:        apiKey,
+				User:          apiKey.User,
+				Account:       account,
+				Subscription:  subscription,
+				UserAgent:     userAgent,
+				IPAddress:     clientIP,
+				APIKeyService: h.apiKeyService,
+			}); err != nil {
+				logger.L().With(
+					zap.String("component", "handler.openai_gateway.ollama"),
+					zap.Int64("user_id", subject.UserID),
+					zap.Int64("api_key_id", apiKey.ID),
+					zap.Any("group_id", apiKey.GroupID),
+					zap.String("model", reqModel),
+					zap.Int64("account_id", account.ID),
+				).Error("openai_ollama.record_usage_failed", zap.Error(err))
+			}
+		})
+		reqLog.Debug("openai_ollama.request_completed",
+			zap.Int64("account_id", account.ID),
+			zap.Int("switch_count", switchCount),
+		)
+		return
+	}
+}
+
+// handleOllamaFailoverExhausted maps upstream failover errors to Ollama format.
+func (h *OpenAIGatewayHandler) handleOllamaFailoverExhausted(c *gin.Context, failoverErr *service.UpstreamFailoverError) {
+	setUpstreamRetryAfter(c, failoverErr)
+	if failoverErr == nil {
+		ollamaError(c, http.StatusBadGateway, "Upstream request failed")
+		return
+	}
+	status, _, errMsg := h.mapUpstreamError(failoverErr.StatusCode)
+	ollamaError(c, status, errMsg)
+}
+
+func (h *OpenAIGatewayHandler) recoverOllamaPanic(c *gin.Context, streamStarted *bool) {
+	recovered := recover()
+	if recovered == nil {
+		return
+	}
+
+	started := streamStarted != nil && *streamStarted
+	requestLogger(c, "handler.openai_gateway.ollama").Error(
+		"openai.ollama_panic_recovered",
+		zap.Bool("stream_started", started),
+		zap.Any("panic", recovered),
+		zap.ByteString("stack", debug.Stack()),
+	)
+	if !started {
+		ollamaError(c, http.StatusInternalServerError, "Internal server error")
+	}
+}
+
+// normalizeOllamaModelName strips the implicit ":latest" tag Ollama clients
+// append to model names.
+func normalizeOllamaModelName(model string) string {
+	return strings.TrimSuffix(strings.TrimSpace(model), ":latest")
+}
+
+// ollamaError writes an error in Ollama API format: {"error": "..."}.
+func ollamaError(c *gin.Context, status int, message string) {
+	c.JSON(status, gin.H{"error": message})
+}
@@ -0,0 +1,206 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/ShaohongDong/sub2api/internal/pkg/ip"
+	middleware2 "github.com/ShaohongDong/sub2api/internal/server/middleware"
+	"github.com/ShaohongDong/sub2api/internal/service"
+
+	coderws "github.com/coder/websocket"
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+// openAIRealtimeSubprotocol is the subprotocol echoed to browser clients that
+// authenticate via "openai-insecure-api-key.<key>" subprotocol entries.
+const openAIRealtimeSubprotocol = "realtime"
+
+// Realtime handles OpenAI Realtime API sessions: GET /v1/realtime?model=...
+//
+// The account is selected and the concurrency slots are acquired before the
+// WebSocket upgrade, so scheduling failures are reported as plain HTTP errors.
+// Slots are held for the whole session; usage of every response.done event is
+// summed and recorded once when the session closes.
+func (h *OpenAIGatewayHandler) Realtime(c *gin.Context) {
+	if !isOpenAIWSUpgradeRequest(c.Request) {
+		h.errorResponse(c, http.StatusUpgradeRequired, "invalid_request_error", "WebSocket upgrade required (Upgrade: websocket)")
+		return
+	}
+	setOpenAIClientTransportWS(c)
+
+	apiKey, ok := middleware2.GetAPIKeyFromContext(c)
+	if !ok {
+		h.errorResponse(c, http.StatusUnauthorized, "authentication_error", "Invalid API key")
+		return
+	}
+	subject, ok := middleware2.GetAuthSubjectFromContext(c)
+	if !ok {
+		h.errorResponse(c, http.StatusInternalServerError, "api_error", "User context not found")
+		return
+	}
+
+	reqModel := strings.TrimSpace(c.Query("model"))
+	if reqModel == "" {
+		h.errorResponse(c, http.StatusBadRequest, "invalid_request_error", "model query parameter is required")
+		return
+	}
+	reqLog := requestLogger(
+		c,
+		"handler.openai_gateway.realtime",
+		zap.Int64("user_id", subject.UserID),
+		zap.Int64("api_key_id", apiKey.ID),
+		zap.Any("group_id", apiKey.GroupID),
+		zap.String("model", reqModel),
+		zap.Bool("openai_ws_mode", true),
+	)
+	if !h.ensureResponsesDependencies(c, reqLog) {
+		return
+	}
+	setOpsRequestContext(c, reqModel, true, nil)
+
+	if !apiKey.AllowsModel(clientRequestedModel(c, reqModel)) {
+		h.errorResponse(c, http.StatusForbidden, "permission_error", apiKeyModelNotAllowedMessage(clientRequestedModel(c, reqModel)))
+		return
+	}
+
+	ctx := c.Request.Context()
+	streamStarted := false
+	userReleaseFunc, acquired := h.acquireResponsesUserSlot(c, subject.UserID, subject.Concurrency, true, &streamStarted, reqLog)
+	if !acquired {
+		return
+	}
+	if userReleaseFunc != nil {
+		defer userReleaseFunc()
+	}
+
+	subscription, _ := middleware2.GetSubscriptionFromContext(c)
+	if err := h.billingCacheService.CheckBillingEligibility(ctx, apiKey.User, apiKey, apiKey.Group, subscription); err != nil {
+		reqLog.Info("openai.realtime_billing_eligibility_check_failed", zap.Error(err))
+		status, code, message := billingErrorDetails(err)
+		h.errorResponse(c, status, code, message)
+		return
+	}
+
+	// Realtime 只有 API Key 账号可承接：跳过不支持的账号后重新调度
+	sessionHash := h.gatewayService.GenerateSessionHash(c, nil)
+	excludedIDs := make(map[int64]struct{})
+	var selection *service.AccountSelectionResult
+	for {
+		var err error
+		selection, _, err = h.gatewayService.SelectAccountWithScheduler(
+			ctx,
+			apiKey.GroupID,
+			"",
+			sessionHash,
+			reqModel,
+			excludedIDs,
+			service.OpenAIUpstreamTransportAny,
+		)
+		if err != nil || selection == nil || selection.Account == nil {
+			reqLog.Warn("openai.realtime_account_select_failed", zap.Error(err), zap.Int("excluded_account_count", len(excludedIDs)))
+			message := "No available accounts"
+			if len(excludedIDs) > 0 {
+				message = "No available accounts support this endpoint"
+			}
+			h.errorResponse(c, http.StatusServiceUnavailable, "api_error", message)
+			return
+		}
+		if service.SupportsOpenAIPlatformAPI(selection.Account) {
+			break
+		}
+		if selection.Acquired && selection.ReleaseFunc != nil {
+			selection.ReleaseFunc()
+		}
+		reqLog.Debug("openai.realtime_account_unsupported_skipped", zap.Int64("account_id", selection.Account.ID), zap.String("account_type", selection.Account.Type))
+		excludedIDs[selection.Account.ID] = struct{}{}
+	}
+	account := selection.Account
+	setOpsSelectedAccount(c, account.ID, account.Platform)
+
+	accountReleaseFunc, acquired := h.acquireResponsesAccountSlot(c, apiKey.GroupID, sessionHash, selection, true, &streamStarted, reqLog)
+	if !acquired {
+		return
+	}
+	if accountReleaseFunc != nil {
+		defer accountReleaseFunc()
+	}
+
+	token, _, err := h.gatewayService.GetAccessToken(ctx, account)
+	if err != nil {
+		reqLog.Warn("openai.realtime_get_access_token_failed", zap.Int64("account_id", account.ID), zap.Error(err))
+		h.errorResponse(c, http.StatusBadGateway, "upstream_error", "Failed to get upstream access token")
+		return
+	}
+
+	clientIP := ip.GetClientIP(c)
+	userAgent := strings.TrimSpace(c.GetHeader("User-Agent"))
+	wsConn, err := coderws.Accept(c.Writer, c.Request, &coderws.AcceptOptions{
+		Subprotocols:    []string{openAIRealtimeSubprotocol},
+		CompressionMode: coderws.CompressionContextTakeover,
+	})
+	if err != nil {
+		reqLog.Warn("openai.realtime_accept_failed", zap.Error(err), zap.String("client_ip", clientIP))
+		return
+	}
+	defer func() {
+		_ = wsConn.CloseNow()
+	}()
+	wsConn.SetReadLimit(16 * 1024 * 1024)
+	reqLog.Info("openai.realtime_session_started", zap.Int64("account_id", account.ID))
+
+	defaultMappedModel := ""
+	if apiKey.Group != nil {
+		defaultMappedModel = apiKey.Group.DefaultMappedModel
+	}
+	sessionStart := time.Now()
+	result, err := h.gatewayService.ProxyRealtimeWebSocket(ctx, c, wsConn, account, token, reqModel, defaultMappedModel)
+	if err != nil {
+		h.gatewayService.ReportOpenAIAccountScheduleResult(account.ID, false, nil)
+		closeStatus, closeReason := summarizeWSCloseErrorForLog(err)
+		reqLog.Warn("openai.realtime_proxy_failed",
+			zap.Int64("account_id", account.ID),
+			zap.Error(err),
+			zap.String("close_status", closeStatus),
+			zap.String("close_reason", closeReason),
+		)
+		var closeErr *service.OpenAIWSClientCloseError
+		if errors.As(err, &closeErr) {
+			closeOpenAIClientWS(wsConn, closeErr.StatusCode(), closeErr.Reason())
+		} else {
+			closeOpenAIClientWS(wsConn, coderws.StatusInternalError, "upstream websocket proxy failed")
+		}
+	} else {
+		h.gatewayService.ReportOpenAIAccountScheduleResult(account.ID, true, result.FirstTokenMs)
+	}
+	reqLog.Info("openai.realtime_session_closed",
+		zap.Int64("account_id", account.ID),
+		zap.Duration("session_duration", time.Since(sessionStart)),
+	)
+	if result == nil {
+		return
+	}
+
+	h.submitUsageRecordTask(c, func(taskCtx context.Context) {
+		if err := h.gatewayService.RecordUsage(taskCtx, &service.OpenAIRecordUsageInput{
+			Result:        result,
+			APIKey:        apiKey,
+			User:          apiKey.User,
+			Account:       account,
+			Subscription:  subscription,
+			UserAgent:     userAgent,
+			IPAddress:     clientIP,
+			APIKeyService: h.apiKeyService,
+		}); err != nil {
+			reqLog.Error("openai.realtime_record_usage_failed",
+				zap.Int64("account_id", account.ID),
+				zap.String("request_id", result.RequestID),
+				zap.Error(err),
+			)
+		}
+	})
+}
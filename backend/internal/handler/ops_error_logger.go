@@ -14,6 +14,7 @@ import (
 	"time"
 	"unicode/utf8"
 
+	"github.com/ShaohongDong/sub2api/internal/pkg/clientdetect"
 	"github.com/ShaohongDong/sub2api/internal/pkg/ctxkey"
 	"github.com/ShaohongDong/sub2api/internal/pkg/ip"
 	middleware2 "github.com/ShaohongDong/sub2api/internal/server/middleware"
@@ -555,8 +556,9 @@ func OpsErrorLoggerMiddleware(ops *service.OpsService) gin.HandlerFunc {
 					}
 					return ""
 				}(),
-				Stream:    stream,
-				UserAgent: c.GetHeader("User-Agent"),
+				Stream:     stream,
+				UserAgent:  c.GetHeader("User-Agent"),
+				ClientType: opsClientType(c),
 
 				ErrorPhase: "upstream",
 				ErrorType:  "upstream_error",
@@ -683,8 +685,9 @@ func OpsErrorLoggerMiddleware(ops *service.OpsService) gin.HandlerFunc {
 				}
 				return ""
 			}(),
-			Stream:    stream,
-			UserAgent: c.GetHeader("User-Agent"),
+			Stream:     stream,
+			UserAgent:  c.GetHeader("User-Agent"),
+			ClientType: opsClientType(c),
 
 			ErrorPhase:        phase,
 			ErrorType:         normalizedType,
@@ -802,6 +805,19 @@ func isCountTokensRequest(c *gin.Context) bool {
 	return strings.Contains(c.Request.URL.Path, "/count_tokens")
 }
 
+// opsClientType returns the client type detected for the request, using the
+// same signals (UA, originator, x-stainless-*) as the live routing path.
+func opsClientType(c *gin.Context) string {
+	if c == nil || c.Request == nil {
+		return ""
+	}
+	info, ok := clientdetect.FromContext(c.Request.Context())
+	if !ok {
+		info = clientdetect.ParseHeaders(c.Request.Header)
+	}
+	return string(info.Type)
+}
+
 func extractOpsRetryRequestHeaders(c *gin.Context) *string {
 	if c == nil || c.Request == nil {
 		return nil
@@ -9,6 +9,7 @@ import (
 	"sync"
 	"testing"
 
+	"github.com/ShaohongDong/sub2api/internal/config"
 	middleware2 "github.com/ShaohongDong/sub2api/internal/server/middleware"
 	"github.com/ShaohongDong/sub2api/internal/service"
 	"github.com/gin-gonic/gin"
@@ -247,7 +248,7 @@ func TestOpsErrorLoggerMiddleware_DoesNotBreakOuterMiddlewares(t *testing.T) {
 	r := gin.New()
 	r.Use(middleware2.Recovery())
 	r.Use(middleware2.RequestLogger())
-	r.Use(middleware2.Logger())
+	r.Use(middleware2.Logger(config.AccessLogConfig{}))
 	r.GET("/v1/messages", OpsErrorLoggerMiddleware(nil), func(c *gin.Context) {
 		c.Status(http.StatusNoContent)
 	})
@@ -0,0 +1,61 @@
+package handler
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/ShaohongDong/sub2api/internal/pkg/logger"
+	"github.com/ShaohongDong/sub2api/internal/service"
+
+	"github.com/gin-gonic/gin"
+	"github.com/tidwall/gjson"
+	"go.uber.org/zap"
+)
+
+// ParamSanitizeHandler clamps and strips request parameters per model before
+// the request is forwarded, so combinations the upstream is known to reject
+// with a 400 are fixed instead of failing.
+type ParamSanitizeHandler struct {
+	service *service.ParamSanitizeService
+}
+
+// NewParamSanitizeHandler creates a new ParamSanitizeHandler
+func NewParamSanitizeHandler(svc *service.ParamSanitizeService) *ParamSanitizeHandler {
+	return &ParamSanitizeHandler{service: svc}
+}
+
+// Middleware returns the sanitizer for one inbound request format
+// (service.ReasoningFormat*). It runs after model aliasing and routing so the
+// limits of the model actually sent upstream apply; every change is logged at
+// debug level.
+func (h *ParamSanitizeHandler) Middleware(format string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if h == nil || !h.service.Enabled() || c.Request == nil || c.Request.Method != http.MethodPost || c.Request.Body == nil {
+			c.Next()
+			return
+		}
+		body, err := io.ReadAll(c.Request.Body)
+		_ = c.Request.Body.Close()
+		c.Request.Body = io.NopCloser(bytes.NewReader(body))
+		if err != nil {
+			c.Next()
+			return
+		}
+
+		model := strings.TrimSpace(gjson.GetBytes(body, "model").String())
+		rewritten, changes := h.service.Sanitize(body, format, model)
+		if len(changes) == 0 {
+			c.Next()
+			return
+		}
+		logger.FromContext(c.Request.Context()).Debug("gateway.param_sanitized",
+			zap.String("model", model), zap.String("format", format), zap.Strings("changes", changes))
+		c.Request.Body = io.NopCloser(bytes.NewReader(rewritten))
+		c.Request.ContentLength = int64(len(rewritten))
+		c.Request.Header.Set("Content-Length", strconv.Itoa(len(rewritten)))
+		c.Next()
+	}
+}
@@ -0,0 +1,52 @@
+package handler
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/ShaohongDong/sub2api/internal/config"
+	"github.com/ShaohongDong/sub2api/internal/service"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParamSanitizeHandler_RewritesBody(t *testing.T) {
+	cfg := &config.Config{}
+	cfg.Gateway.ParamSanitize = config.GatewayParamSanitizeConfig{
+		Enabled: true,
+		Rules:   []config.GatewayParamSanitizeRule{{Models: []string{"glm-*"}, MaxOutputTokens: 8192}},
+	}
+	gin.SetMode(gin.TestMode)
+	var got, contentLength string
+	newRouter := func(h *ParamSanitizeHandler) *gin.Engine {
+		r := gin.New()
+		r.POST("/v1/chat/completions", h.Middleware(service.ReasoningFormatChatCompletions), func(c *gin.Context) {
+			raw, _ := io.ReadAll(c.Request.Body)
+			got = string(raw)
+			contentLength = c.GetHeader("Content-Length")
+			c.Status(http.StatusNoContent)
+		})
+		return r
+	}
+	send := func(r *gin.Engine, body string) {
+		req := httptest.NewRequest(http.MethodPost, "/v1/chat/completions", strings.NewReader(body))
+		req.Header.Set("Content-Length", strconv.Itoa(len(body)))
+		r.ServeHTTP(httptest.NewRecorder(), req)
+	}
+
+	r := newRouter(NewParamSanitizeHandler(service.NewParamSanitizeService(cfg, nil)))
+	send(r, `{"model":"glm-4.6","max_tokens":100000}`)
+	require.JSONEq(t, `{"model":"glm-4.6","max_tokens":8192}`, got)
+	require.Equal(t, strconv.Itoa(len(got)), contentLength)
+
+	send(r, `{"model":"gpt-4o","max_tokens":100000}`)
+	require.Equal(t, `{"model":"gpt-4o","max_tokens":100000}`, got)
+
+	// 未装配时透传
+	send(newRouter(nil), `{"model":"glm-4.6","max_tokens":100000}`)
+	require.Equal(t, `{"model":"glm-4.6","max_tokens":100000}`, got)
+}
@@ -2,8 +2,9 @@ package handler
 
 import (
 	"errors"
-	"fmt"
 	"net/http"
+
+	pkghttputil "github.com/ShaohongDong/sub2api/internal/pkg/httputil"
 )
 
 func extractMaxBytesError(err error) (*http.MaxBytesError, bool) {
@@ -14,14 +15,6 @@ func extractMaxBytesError(err error) (*http.MaxBytesError, bool) {
 	return nil, false
 }
 
-func formatBodyLimit(limit int64) string {
-	const mb = 1024 * 1024
-	if limit >= mb {
-		return fmt.Sprintf("%dMB", limit/mb)
-	}
-	return fmt.Sprintf("%dB", limit)
-}
-
 func buildBodyTooLargeMessage(limit int64) string {
-	return fmt.Sprintf("Request body too large, limit is %s", formatBodyLimit(limit))
+	return pkghttputil.BodyTooLargeMessage(limit)
 }
@@ -0,0 +1,123 @@
+package handler
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"strings"
+
+	pkghttputil "github.com/ShaohongDong/sub2api/internal/pkg/httputil"
+	"github.com/ShaohongDong/sub2api/internal/pkg/logger"
+	middleware2 "github.com/ShaohongDong/sub2api/internal/server/middleware"
+	"github.com/ShaohongDong/sub2api/internal/service"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+// ResponseCacheHeader marks whether a cacheable request was served from the response cache.
+const ResponseCacheHeader = "X-Cache"
+
+// ResponseCacheHandler serves repeated deterministic requests (non-streaming,
+// temperature 0) from the response cache.
+type ResponseCacheHandler struct {
+	service *service.ResponseCacheService
+}
+
+// NewResponseCacheHandler creates a new ResponseCacheHandler
+func NewResponseCacheHandler(svc *service.ResponseCacheService) *ResponseCacheHandler {
+	return &ResponseCacheHandler{service: svc}
+}
+
+// Middleware wraps inference endpoints. A cache hit is answered directly with
+// "x-cache: hit" and never reaches the upstream; on a miss the 200 response is
+// stored for response_cache.ttl_seconds and marked "x-cache: miss".
+func (h *ResponseCacheHandler) Middleware(c *gin.Context) {
+	if h == nil || !h.service.Enabled() {
+		c.Next()
+		return
+	}
+	apiKey, ok := middleware2.GetAPIKeyFromContext(c)
+	if !ok {
+		c.Next()
+		return
+	}
+	body, err := pkghttputil.ReadRequestBodyWithPrealloc(c.Request)
+	if err != nil {
+		if maxErr, ok := extractMaxBytesError(err); ok {
+			openAIAPIErrorResponse(c, http.StatusRequestEntityTooLarge, "invalid_request_error", buildBodyTooLargeMessage(maxErr.Limit))
+		} else {
+			openAIAPIErrorResponse(c, http.StatusBadRequest, "invalid_request_error", "Failed to read request body")
+		}
+		c.Abort()
+		return
+	}
+	c.Request.Body = io.NopCloser(bytes.NewReader(body))
+	c.Request.ContentLength = int64(len(body))
+
+	var groupID int64
+	if apiKey.Group != nil {
+		groupID = apiKey.Group.ID
+	}
+	key, ok := service.ResponseCacheKey(apiKey.UserID, groupID, c.Request.URL.Path, body)
+	if !ok {
+		c.Next()
+		return
+	}
+	ctx := c.Request.Context()
+	if cached, ok := h.service.Get(ctx, key); ok {
+		c.Header(ResponseCacheHeader, "hit")
+		c.Data(cached.Status, cached.ContentType, cached.Body)
+		c.Abort()
+		return
+	}
+
+	c.Header(ResponseCacheHeader, "miss")
+	w := &responseCacheWriter{ResponseWriter: c.Writer, limit: h.service.MaxBodyBytes()}
+	c.Writer = w
+	c.Next()
+	c.Writer = w.ResponseWriter
+
+	if w.Status() != http.StatusOK || w.overflow || w.body.Len() == 0 {
+		return
+	}
+	resp := &service.CachedResponse{
+		Status:      http.StatusOK,
+		ContentType: w.Header().Get("Content-Type"),
+		Body:        w.body.Bytes(),
+	}
+	if err := h.service.Set(ctx, key, resp); err != nil {
+		logger.FromContext(ctx).Warn("gateway.response_cache_store_failed", zap.Error(err))
+	}
+}
+
+// responseCacheWriter passes the response through while keeping a copy for the
+// cache; a response larger than limit (or any SSE stream) is not kept.
+type responseCacheWriter struct {
+	gin.ResponseWriter
+	body     bytes.Buffer
+	limit    int
+	overflow bool
+}
+
+func (w *responseCacheWriter) Write(b []byte) (int, error) {
+	w.keep(b)
+	return w.ResponseWriter.Write(b)
+}
+
+func (w *responseCacheWriter) WriteString(s string) (int, error) {
+	w.keep([]byte(s))
+	return w.ResponseWriter.WriteString(s)
+}
+
+func (w *responseCacheWriter) keep(b []byte) {
+	if w.overflow {
+		return
+	}
+	if w.body.Len()+len(b) > w.limit || strings.HasPrefix(w.Header().Get("Content-Type"), "text/event-stream") {
+		w.overflow = true
+		w.body.Reset()
+		return
+	}
+	w.body.Write(b)
+}
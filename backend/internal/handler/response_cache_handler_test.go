@@ -0,0 +1,64 @@
+package handler
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/ShaohongDong/sub2api/internal/config"
+	middleware2 "github.com/ShaohongDong/sub2api/internal/server/middleware"
+	"github.com/ShaohongDong/sub2api/internal/service"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/require"
+)
+
+func TestResponseCacheHandler_HitsOnRepeatedDeterministicRequest(t *testing.T) {
+	svc := service.NewResponseCacheService(&config.Config{ResponseCache: config.ResponseCacheConfig{
+		Enabled: true, Backend: "memory", TTLSeconds: 60, MaxEntries: 16, MaxBodyBytes: 1 << 10,
+	}}, nil)
+	calls := 0
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.POST("/v1/chat/completions", func(c *gin.Context) {
+		c.Set(string(middleware2.ContextKeyAPIKey), &service.APIKey{ID: 7, UserID: 3})
+	}, NewResponseCacheHandler(svc).Middleware, func(c *gin.Context) {
+		calls++
+		if strings.Contains(c.GetHeader("X-Test"), "fail") {
+			c.JSON(http.StatusBadGateway, gin.H{"error": "upstream"})
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{"n": calls})
+	})
+	send := func(body string, header string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/v1/chat/completions", strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("X-Test", header)
+		rec := httptest.NewRecorder()
+		r.ServeHTTP(rec, req)
+		return rec
+	}
+
+	rec := send(`{"model":"gpt-5","temperature":0,"messages":[{"role":"user","content":"hi"}]}`, "")
+	require.Equal(t, "miss", rec.Header().Get(ResponseCacheHeader))
+	require.JSONEq(t, `{"n":1}`, rec.Body.String())
+
+	// 字段顺序与空白不同的相同请求命中缓存
+	rec = send(`{ "messages":[{"content":"hi","role":"user"}], "temperature":0, "model":"gpt-5" }`, "")
+	require.Equal(t, http.StatusOK, rec.Code)
+	require.Equal(t, "hit", rec.Header().Get(ResponseCacheHeader))
+	require.JSONEq(t, `{"n":1}`, rec.Body.String())
+	require.Equal(t, 1, calls)
+
+	// 非确定性请求不缓存
+	rec = send(`{"model":"gpt-5","temperature":0.7}`, "")
+	require.Empty(t, rec.Header().Get(ResponseCacheHeader))
+	rec = send(`{"model":"gpt-5","temperature":0,"stream":true}`, "")
+	require.Empty(t, rec.Header().Get(ResponseCacheHeader))
+
+	// 失败响应不缓存
+	send(`{"model":"gpt-4o","temperature":0}`, "fail")
+	rec = send(`{"model":"gpt-4o","temperature":0}`, "")
+	require.Equal(t, "miss", rec.Header().Get(ResponseCacheHeader))
+	require.Equal(t, 5, calls)
+}
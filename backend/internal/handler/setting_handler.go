@@ -53,6 +53,8 @@ func (h *SettingHandler) GetPublicSettings(c *gin.Context) {
 		PurchaseSubscriptionURL:          settings.PurchaseSubscriptionURL,
 		CustomMenuItems:                  dto.ParseUserVisibleMenuItems(settings.CustomMenuItems),
 		LinuxDoOAuthEnabled:              settings.LinuxDoOAuthEnabled,
+		OIDCEnabled:                      settings.OIDCEnabled,
+		OIDCProviderName:                 settings.OIDCProviderName,
 		SoraClientEnabled:                settings.SoraClientEnabled,
 		Version:                          h.version,
 	})
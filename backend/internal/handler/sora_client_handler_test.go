@@ -1029,6 +1029,9 @@ func (r *stubAPIKeyRepoForHandler) ListKeysByGroupID(context.Context, int64) ([]
 func (r *stubAPIKeyRepoForHandler) IncrementQuotaUsed(_ context.Context, _ int64, _ float64) (float64, error) {
 	return 0, nil
 }
+func (r *stubAPIKeyRepoForHandler) IncrementImageQuotaUsed(_ context.Context, _ int64, _ int) (int, error) {
+	return 0, nil
+}
 func (r *stubAPIKeyRepoForHandler) UpdateLastUsed(context.Context, int64, time.Time) error {
 	return nil
 }
@@ -1041,6 +1044,16 @@ func (r *stubAPIKeyRepoForHandler) ResetRateLimitWindows(context.Context, int64)
 func (r *stubAPIKeyRepoForHandler) GetRateLimitData(context.Context, int64) (*service.APIKeyRateLimitData, error) {
 	return nil, nil
 }
+func (r *stubAPIKeyRepoForHandler) IncrementBudgetUsed(context.Context, int64, float64, time.Time) (float64, error) {
+	return 0, nil
+}
+func (r *stubAPIKeyRepoForHandler) MarkBudgetExceeded(context.Context, int64, time.Time, time.Time) (bool, error) {
+	return false, nil
+}
+
+func (r *stubAPIKeyRepoForHandler) RotateKey(context.Context, int64, string, string, *time.Time) error {
+	return nil
+}
 
 // newTestAPIKeyService 创建测试用的 APIKeyService
 func newTestAPIKeyService(repo *stubAPIKeyRepoForHandler) *service.APIKeyService {
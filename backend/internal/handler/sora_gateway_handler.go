@@ -160,6 +160,11 @@ func (h *SoraGatewayHandler) ChatCompletions(c *gin.Context) {
 
 	setOpsRequestContext(c, reqModel, clientStream, body)
 
+	if !apiKey.AllowsModel(clientRequestedModel(c, reqModel)) {
+		h.errorResponse(c, http.StatusForbidden, "permission_error", apiKeyModelNotAllowedMessage(clientRequestedModel(c, reqModel)))
+		return
+	}
+
 	platform := ""
 	if forced, ok := middleware2.GetForcePlatformFromContext(c); ok {
 		platform = forced
@@ -401,7 +406,7 @@ func (h *SoraGatewayHandler) ChatCompletions(c *gin.Context) {
 		clientIP := ip.GetClientIP(c)
 
 		// 使用量记录通过有界 worker 池提交，避免请求热路径创建无界 goroutine。
-		h.submitUsageRecordTask(func(ctx context.Context) {
+		h.submitUsageRecordTask(c, func(ctx context.Context) {
 			if err := h.gatewayService.RecordUsage(ctx, &service.RecordUsageInput{
 				Result:       result,
 				APIKey:       apiKey,
@@ -450,10 +455,11 @@ func generateOpenAISessionHash(c *gin.Context, body []byte) string {
 	return hex.EncodeToString(hash[:])
 }
 
-func (h *SoraGatewayHandler) submitUsageRecordTask(task service.UsageRecordTask) {
+func (h *SoraGatewayHandler) submitUsageRecordTask(c *gin.Context, task service.UsageRecordTask) {
 	if task == nil {
 		return
 	}
+	task = withRequestContext(c, task)
 	if h.usageRecordWorkerPool != nil {
 		h.usageRecordWorkerPool.Submit(task)
 		return
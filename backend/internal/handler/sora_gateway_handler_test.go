@@ -337,6 +337,21 @@ func (s *stubUsageLogRepo) GetModelStatsWithFilters(ctx context.Context, startTi
 func (s *stubUsageLogRepo) GetGroupStatsWithFilters(ctx context.Context, startTime, endTime time.Time, userID, apiKeyID, accountID, groupID int64, requestType *int16, stream *bool, billingType *int8) ([]usagestats.GroupStat, error) {
 	return nil, nil
 }
+func (s *stubUsageLogRepo) GetUsageAggregates(ctx context.Context, query usagestats.UsageAggregateQuery) ([]usagestats.UsageAggregate, error) {
+	return nil, nil
+}
+func (s *stubUsageLogRepo) ForEachUsageAggregate(ctx context.Context, query usagestats.UsageAggregateQuery, fn func(*usagestats.UsageAggregate) error) error {
+	return nil
+}
+func (s *stubUsageLogRepo) ForEachUsageExportRow(ctx context.Context, query usagestats.UsageAggregateQuery, fn func(*usagestats.UsageExportRow) error) error {
+	return nil
+}
+func (s *stubUsageLogRepo) GetClientTypeUsage(ctx context.Context, startTime, endTime time.Time) ([]usagestats.ClientTypeUsageRow, error) {
+	return nil, nil
+}
+func (s *stubUsageLogRepo) CountErrorRequests(ctx context.Context, startTime, endTime time.Time) (int64, error) {
+	return 0, nil
+}
 func (s *stubUsageLogRepo) GetAPIKeyUsageTrend(ctx context.Context, startTime, endTime time.Time, granularity string, limit int) ([]usagestats.APIKeyUsageTrendPoint, error) {
 	return nil, nil
 }
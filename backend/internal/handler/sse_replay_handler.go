@@ -0,0 +1,271 @@
+package handler
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	pkghttputil "github.com/ShaohongDong/sub2api/internal/pkg/httputil"
+	middleware2 "github.com/ShaohongDong/sub2api/internal/server/middleware"
+	"github.com/ShaohongDong/sub2api/internal/service"
+
+	"github.com/gin-gonic/gin"
+	"github.com/tidwall/gjson"
+)
+
+// SSEReplayHandler lets streaming clients resume a dropped stream with
+// Last-Event-ID instead of restarting the whole generation.
+type SSEReplayHandler struct {
+	service *service.SSEReplayService
+}
+
+// NewSSEReplayHandler creates a new SSEReplayHandler
+func NewSSEReplayHandler(svc *service.SSEReplayService) *SSEReplayHandler {
+	return &SSEReplayHandler{service: svc}
+}
+
+// Middleware wraps streaming endpoints (stream=true requests).
+//
+// A request carrying Last-Event-ID is answered from the replay buffer: the
+// buffered events after that id are sent, then new events as they arrive. Any
+// other streaming request gets event ids assigned and keeps generating after
+// the client disconnects, until reconnect_grace_seconds pass without a reconnect.
+func (h *SSEReplayHandler) Middleware(c *gin.Context) {
+	if h == nil || !h.service.Enabled() {
+		c.Next()
+		return
+	}
+	if lastEventID := c.GetHeader("Last-Event-ID"); lastEventID != "" {
+		defer c.Abort()
+		h.replay(c, lastEventID)
+		return
+	}
+	apiKey, ok := middleware2.GetAPIKeyFromContext(c)
+	if !ok {
+		c.Next()
+		return
+	}
+	body, err := pkghttputil.ReadRequestBodyWithPrealloc(c.Request)
+	if err != nil {
+		if maxErr, ok := extractMaxBytesError(err); ok {
+			openAIAPIErrorResponse(c, http.StatusRequestEntityTooLarge, "invalid_request_error", buildBodyTooLargeMessage(maxErr.Limit))
+		} else {
+			openAIAPIErrorResponse(c, http.StatusBadRequest, "invalid_request_error", "Failed to read request body")
+		}
+		c.Abort()
+		return
+	}
+	c.Request.Body = io.NopCloser(bytes.NewReader(body))
+	c.Request.ContentLength = int64(len(body))
+	if !gjson.GetBytes(body, "stream").Bool() {
+		c.Next()
+		return
+	}
+
+	// 上游请求与客户端连接解耦：客户端断开后继续生成，无人重连时由 stream 取消
+	clientCtx := c.Request.Context()
+	ctx, cancel := context.WithCancel(context.WithoutCancel(clientCtx))
+	defer cancel()
+	stream := h.service.Begin(apiKey.ID, cancel)
+	w := &sseReplayWriter{ResponseWriter: c.Writer, stream: stream}
+	stopWatch := context.AfterFunc(clientCtx, func() {
+		// 路由超时不是客户端断开，直接终止上游请求
+		if middleware2.IsRouteTimeout(context.Cause(clientCtx)) {
+			cancel()
+		}
+		w.clientGone()
+	})
+	defer stopWatch()
+
+	c.Writer = w
+	c.Request = c.Request.WithContext(ctx)
+	c.Next()
+	w.flushPending()
+	stream.Finish()
+}
+
+// replay serves a reconnect: buffered events after lastEventID, then live events until the stream finishes.
+func (h *SSEReplayHandler) replay(c *gin.Context, lastEventID string) {
+	apiKey, ok := middleware2.GetAPIKeyFromContext(c)
+	if !ok {
+		openAIAPIErrorResponse(c, http.StatusUnauthorized, "authentication_error", "Invalid API key")
+		return
+	}
+	streamID, afterSeq, ok := service.ParseSSEReplayEventID(lastEventID)
+	if !ok {
+		openAIServiceError(c, service.ErrSSEReplayStreamNotFound)
+		return
+	}
+	stream, err := h.service.Get(apiKey.ID, streamID)
+	if err != nil {
+		openAIServiceError(c, err)
+		return
+	}
+	events, finished, notify, err := stream.Since(afterSeq)
+	if err != nil {
+		openAIServiceError(c, err)
+		return
+	}
+
+	stream.Attach()
+	defer stream.Detach()
+
+	c.Writer.Header().Set("Content-Type", "text/event-stream")
+	c.Writer.Header().Set("Cache-Control", "no-cache")
+	c.Writer.Header().Set("Connection", "keep-alive")
+	c.Writer.Header().Set("X-Accel-Buffering", "no")
+	c.Writer.WriteHeader(http.StatusOK)
+	c.Writer.WriteHeaderNow()
+	c.Writer.Flush()
+
+	var heartbeat <-chan time.Time
+	if interval := h.service.HeartbeatInterval(); interval > 0 {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		heartbeat = ticker.C
+	}
+	for {
+		for _, ev := range events {
+			if _, err := c.Writer.Write(sseReplayFrame(stream.ID, ev)); err != nil {
+				return
+			}
+			afterSeq = ev.Seq
+		}
+		if len(events) > 0 {
+			c.Writer.Flush()
+		}
+		if finished {
+			return
+		}
+		select {
+		case <-notify:
+		case <-heartbeat:
+			if _, err := io.WriteString(c.Writer, ":\n\n"); err != nil {
+				return
+			}
+			c.Writer.Flush()
+		case <-c.Request.Context().Done():
+			return
+		}
+		if events, finished, notify, err = stream.Since(afterSeq); err != nil {
+			// 重连连接读取过慢导致事件被覆盖：无法保证完整性，直接结束
+			return
+		}
+	}
+}
+
+// sseReplayFrame prefixes a buffered event with its id line.
+func sseReplayFrame(streamID string, ev service.SSEReplayEvent) []byte {
+	frame := make([]byte, 0, len(ev.Data)+64)
+	frame = append(frame, "id: "...)
+	frame = append(frame, service.SSEReplayEventID(streamID, ev.Seq)...)
+	frame = append(frame, '\n')
+	return append(frame, ev.Data...)
+}
+
+// sseReplayWriter splits an SSE response into events, assigns each an id and
+// records it in the replay buffer. Once the client is gone, writes are
+// swallowed so the handler keeps consuming the upstream stream.
+type sseReplayWriter struct {
+	gin.ResponseWriter
+	stream  *service.SSEReplayStream
+	pending []byte
+	gone    atomic.Bool
+}
+
+func (w *sseReplayWriter) isSSE() bool {
+	return strings.HasPrefix(w.Header().Get("Content-Type"), "text/event-stream")
+}
+
+func (w *sseReplayWriter) Write(p []byte) (int, error) {
+	if !w.isSSE() {
+		return w.ResponseWriter.Write(p)
+	}
+	w.pending = append(w.pending, p...)
+	for {
+		idx := bytes.Index(w.pending, []byte("\n\n"))
+		if idx < 0 {
+			break
+		}
+		event := w.pending[:idx+2]
+		w.pending = w.pending[idx+2:]
+		w.emit(event)
+	}
+	return len(p), nil
+}
+
+func (w *sseReplayWriter) WriteString(s string) (int, error) {
+	return w.Write([]byte(s))
+}
+
+func (w *sseReplayWriter) Flush() {
+	if !w.gone.Load() {
+		w.ResponseWriter.Flush()
+	}
+}
+
+// emit assigns an id to a complete event and forwards it; comment-only events (heartbeats) are not buffered.
+func (w *sseReplayWriter) emit(event []byte) {
+	if isSSECommentEvent(event) {
+		w.forward(event)
+		return
+	}
+	data := stripSSEIDLines(event)
+	seq := w.stream.Append(data)
+	w.forward(sseReplayFrame(w.stream.ID, service.SSEReplayEvent{Seq: seq, Data: data}))
+}
+
+func (w *sseReplayWriter) forward(b []byte) {
+	if w.gone.Load() {
+		return
+	}
+	if _, err := w.ResponseWriter.Write(b); err != nil {
+		w.clientGone()
+	}
+}
+
+// flushPending forwards a trailing event that was not terminated by a blank line.
+func (w *sseReplayWriter) flushPending() {
+	if len(w.pending) == 0 {
+		return
+	}
+	event := append(w.pending, '\n', '\n')
+	w.pending = nil
+	w.emit(event)
+	w.Flush()
+}
+
+func (w *sseReplayWriter) clientGone() {
+	if w.gone.CompareAndSwap(false, true) {
+		w.stream.Detach()
+	}
+}
+
+func isSSECommentEvent(event []byte) bool {
+	for _, line := range strings.Split(strings.TrimRight(string(event), "\n"), "\n") {
+		if !strings.HasPrefix(line, ":") {
+			return false
+		}
+	}
+	return true
+}
+
+// stripSSEIDLines drops upstream id lines so the replay id is the only one.
+func stripSSEIDLines(event []byte) []byte {
+	if !bytes.Contains(event, []byte("id:")) {
+		return event
+	}
+	lines := strings.SplitAfter(string(event), "\n")
+	var b strings.Builder
+	for _, line := range lines {
+		if strings.HasPrefix(line, "id:") {
+			continue
+		}
+		b.WriteString(line)
+	}
+	return []byte(b.String())
+}
@@ -0,0 +1,86 @@
+package handler
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/ShaohongDong/sub2api/internal/config"
+	middleware2 "github.com/ShaohongDong/sub2api/internal/server/middleware"
+	"github.com/ShaohongDong/sub2api/internal/service"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/require"
+)
+
+func newSSEReplayTestRouter(h *SSEReplayHandler) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.POST("/v1/chat/completions", func(c *gin.Context) {
+		c.Set(string(middleware2.ContextKeyAPIKey), &service.APIKey{ID: 7})
+	}, h.Middleware, func(c *gin.Context) {
+		body, _ := io.ReadAll(c.Request.Body)
+		if !strings.Contains(string(body), `"stream":true`) {
+			c.JSON(http.StatusOK, gin.H{"ok": true})
+			return
+		}
+		c.Writer.Header().Set("Content-Type", "text/event-stream")
+		c.Writer.WriteHeader(http.StatusOK)
+		// 事件被拆成多次写入，心跳注释不分配 id
+		_, _ = io.WriteString(c.Writer, "data: {\"n\":1}\n")
+		_, _ = io.WriteString(c.Writer, "\n:\n\n")
+		_, _ = io.WriteString(c.Writer, "data: {\"n\":2}\n\ndata: [DONE]\n\n")
+	})
+	return r
+}
+
+func TestSSEReplayHandler_AssignsIDsAndReplays(t *testing.T) {
+	svc := service.NewSSEReplayService(&config.Config{SSEReplay: config.SSEReplayConfig{
+		Enabled: true, BufferEvents: 16, RetentionSeconds: 60, ReconnectGraceSeconds: 60,
+	}})
+	r := newSSEReplayTestRouter(NewSSEReplayHandler(svc))
+
+	req := httptest.NewRequest(http.MethodPost, "/v1/chat/completions", strings.NewReader(`{"model":"gpt-5","stream":true}`))
+	req.Header.Set("Content-Type", "application/json")
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, req)
+
+	body := rec.Body.String()
+	ids := regexp.MustCompile(`id: (sse_[0-9a-f]+):(\d+)\n`).FindAllStringSubmatch(body, -1)
+	require.Len(t, ids, 3, body)
+	require.Contains(t, body, ":\n\n")
+	require.Contains(t, body, "id: "+ids[0][1]+":1\ndata: {\"n\":1}\n\n")
+	require.Contains(t, body, "id: "+ids[0][1]+":3\ndata: [DONE]\n\n")
+
+	// 携带 Last-Event-ID 重连：只收到之后的事件
+	req = httptest.NewRequest(http.MethodPost, "/v1/chat/completions", strings.NewReader(`{"model":"gpt-5","stream":true}`))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Last-Event-ID", ids[0][1]+":1")
+	rec = httptest.NewRecorder()
+	r.ServeHTTP(rec, req)
+	require.Equal(t, http.StatusOK, rec.Code)
+	require.Equal(t, "text/event-stream", rec.Header().Get("Content-Type"))
+	require.Equal(t, "id: "+ids[0][1]+":2\ndata: {\"n\":2}\n\nid: "+ids[0][1]+":3\ndata: [DONE]\n\n", rec.Body.String())
+
+	// 未知流返回 404
+	req = httptest.NewRequest(http.MethodPost, "/v1/chat/completions", strings.NewReader(`{}`))
+	req.Header.Set("Last-Event-ID", "sse_unknown:1")
+	rec = httptest.NewRecorder()
+	r.ServeHTTP(rec, req)
+	require.Equal(t, http.StatusNotFound, rec.Code)
+}
+
+func TestSSEReplayHandler_NonStreamPassthrough(t *testing.T) {
+	svc := service.NewSSEReplayService(&config.Config{SSEReplay: config.SSEReplayConfig{
+		Enabled: true, BufferEvents: 16, RetentionSeconds: 60, ReconnectGraceSeconds: 60,
+	}})
+	r := newSSEReplayTestRouter(NewSSEReplayHandler(svc))
+
+	req := httptest.NewRequest(http.MethodPost, "/v1/chat/completions", strings.NewReader(`{"model":"gpt-5"}`))
+	req.Header.Set("Content-Type", "application/json")
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, req)
+	require.JSONEq(t, `{"ok":true}`, rec.Body.String())
+}
@@ -0,0 +1,73 @@
+package handler
+
+import (
+	"net/http"
+
+	pkghttputil "github.com/ShaohongDong/sub2api/internal/pkg/httputil"
+	"github.com/ShaohongDong/sub2api/internal/service"
+
+	"github.com/gin-gonic/gin"
+	"github.com/tidwall/gjson"
+)
+
+// LocalCountTokens 以本地 tokenizer 应答 Anthropic 风格的 POST /v1/messages/count_tokens，
+// 用于上游不提供 count_tokens 的分组（如 OpenAI 分组）。不调度账号、不计费。
+func LocalCountTokens(c *gin.Context) {
+	writeError := func(status int, errType, message string) {
+		c.JSON(status, gin.H{"type": "error", "error": gin.H{"type": errType, "message": message}})
+	}
+	body, ok := readTokenCountBody(c, writeError)
+	if !ok {
+		return
+	}
+	if gjson.GetBytes(body, "model").String() == "" {
+		writeError(http.StatusBadRequest, "invalid_request_error", "model is required")
+		return
+	}
+	if !gjson.GetBytes(body, "messages").IsArray() {
+		writeError(http.StatusBadRequest, "invalid_request_error", "messages is required")
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"input_tokens": service.CountAnthropicRequestTokens(body)})
+}
+
+// ResponsesInputTokens 以本地 tokenizer 应答 OpenAI 风格的 POST /v1/responses/input_tokens。
+// 请求体按 Responses 格式解析，同时接受 Chat Completions 的 messages 字段。
+func ResponsesInputTokens(c *gin.Context) {
+	writeError := func(status int, errType, message string) {
+		c.JSON(status, gin.H{"error": gin.H{"type": errType, "message": message}})
+	}
+	body, ok := readTokenCountBody(c, writeError)
+	if !ok {
+		return
+	}
+	if !gjson.GetBytes(body, "input").Exists() && !gjson.GetBytes(body, "messages").Exists() {
+		writeError(http.StatusBadRequest, "invalid_request_error", "input is required")
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"object":       "response.input_tokens",
+		"input_tokens": service.CountOpenAIRequestTokens("", body),
+	})
+}
+
+func readTokenCountBody(c *gin.Context, writeError func(status int, errType, message string)) ([]byte, bool) {
+	body, err := pkghttputil.ReadRequestBodyWithPrealloc(c.Request)
+	if err != nil {
+		if maxErr, ok := extractMaxBytesError(err); ok {
+			writeError(http.StatusRequestEntityTooLarge, "invalid_request_error", buildBodyTooLargeMessage(maxErr.Limit))
+			return nil, false
+		}
+		writeError(http.StatusBadRequest, "invalid_request_error", "Failed to read request body")
+		return nil, false
+	}
+	if len(body) == 0 {
+		writeError(http.StatusBadRequest, "invalid_request_error", "Request body is empty")
+		return nil, false
+	}
+	if !gjson.ValidBytes(body) {
+		writeError(http.StatusBadRequest, "invalid_request_error", "Failed to parse request body")
+		return nil, false
+	}
+	return body, true
+}
@@ -0,0 +1,54 @@
+package handler
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/ShaohongDong/sub2api/internal/service"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/require"
+	"github.com/tidwall/gjson"
+)
+
+func TestLocalCountTokens(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.POST("/v1/messages/count_tokens", LocalCountTokens)
+
+	body := `{"model":"gpt-5","messages":[{"role":"user","content":"hello world"}]}`
+	req := httptest.NewRequest(http.MethodPost, "/v1/messages/count_tokens", strings.NewReader(body))
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	require.Equal(t, http.StatusOK, w.Code)
+	require.Equal(t, int64(service.CountAnthropicRequestTokens([]byte(body))), gjson.Get(w.Body.String(), "input_tokens").Int())
+
+	req = httptest.NewRequest(http.MethodPost, "/v1/messages/count_tokens", strings.NewReader(`{"messages":[]}`))
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	require.Equal(t, http.StatusBadRequest, w.Code)
+	require.Equal(t, "error", gjson.Get(w.Body.String(), "type").String())
+	require.Equal(t, "model is required", gjson.Get(w.Body.String(), "error.message").String())
+}
+
+func TestResponsesInputTokens(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.POST("/v1/responses/input_tokens", ResponsesInputTokens)
+
+	// Chat Completions 形式的 messages 同样可计数
+	body := `{"model":"gpt-4o","messages":[{"role":"user","content":"hello world"}]}`
+	req := httptest.NewRequest(http.MethodPost, "/v1/responses/input_tokens", strings.NewReader(body))
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	require.Equal(t, http.StatusOK, w.Code)
+	require.Equal(t, "response.input_tokens", gjson.Get(w.Body.String(), "object").String())
+	require.Equal(t, int64(service.CountOpenAIRequestTokens("", []byte(body))), gjson.Get(w.Body.String(), "input_tokens").Int())
+
+	req = httptest.NewRequest(http.MethodPost, "/v1/responses/input_tokens", strings.NewReader(`{"model":"gpt-5"}`))
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	require.Equal(t, http.StatusBadRequest, w.Code)
+	require.Equal(t, "input is required", gjson.Get(w.Body.String(), "error.message").String())
+}
@@ -31,6 +31,16 @@ func ProvideAdminHandlers(
 	errorPassthroughHandler *admin.ErrorPassthroughHandler,
 	apiKeyHandler *admin.AdminAPIKeyHandler,
 	scheduledTestHandler *admin.ScheduledTestHandler,
+	accountHealthHandler *admin.AccountHealthHandler,
+	accountValidationHandler *admin.AccountValidationHandler,
+	usageWindowHandler *admin.AccountUsageWindowHandler,
+	tenantHandler *admin.TenantHandler,
+	auditLogHandler *admin.AuditLogHandler,
+	backupHandler *admin.BackupHandler,
+	runtimeSettingsHandler *admin.RuntimeSettingsHandler,
+	deadLetterHandler *admin.DeadLetterHandler,
+	contentLogHandler *admin.ContentLogHandler,
+	maintenanceHandler *admin.MaintenanceHandler,
 ) *AdminHandlers {
 	return &AdminHandlers{
 		Dashboard:        dashboardHandler,
@@ -55,12 +65,22 @@ func ProvideAdminHandlers(
 		ErrorPassthrough: errorPassthroughHandler,
 		APIKey:           apiKeyHandler,
 		ScheduledTest:    scheduledTestHandler,
+		AccountHealth:    accountHealthHandler,
+		AccountValidate:  accountValidationHandler,
+		UsageWindow:      usageWindowHandler,
+		Tenant:           tenantHandler,
+		AuditLog:         auditLogHandler,
+		Backup:           backupHandler,
+		RuntimeSettings:  runtimeSettingsHandler,
+		DeadLetter:       deadLetterHandler,
+		ContentLog:       contentLogHandler,
+		Maintenance:      maintenanceHandler,
 	}
 }
 
 // ProvideSystemHandler creates admin.SystemHandler with UpdateService
-func ProvideSystemHandler(updateService *service.UpdateService, lockService *service.SystemOperationLockService) *admin.SystemHandler {
-	return admin.NewSystemHandler(updateService, lockService)
+func ProvideSystemHandler(updateService *service.UpdateService, lockService *service.SystemOperationLockService, reloadService *service.ConfigReloadService) *admin.SystemHandler {
+	return admin.NewSystemHandler(updateService, lockService, reloadService)
 }
 
 // ProvideSettingHandler creates SettingHandler with version from BuildInfo
@@ -84,24 +104,56 @@ func ProvideHandlers(
 	soraClientHandler *SoraClientHandler,
 	settingHandler *SettingHandler,
 	totpHandler *TotpHandler,
+	batchHandler *BatchHandler,
+	fileHandler *FileHandler,
+	backgroundResponseHandler *BackgroundResponseHandler,
+	sseReplayHandler *SSEReplayHandler,
+	responseCacheHandler *ResponseCacheHandler,
+	conversationStoreHandler *ConversationStoreHandler,
+	paramSanitizeHandler *ParamSanitizeHandler,
+	contextOverflowHandler *ContextOverflowHandler,
+	chatMultiChoiceHandler *ChatMultiChoiceHandler,
+	deadLetterHandler *DeadLetterHandler,
+	contentLogHandler *ContentLogHandler,
+	idempotencyHandler *GatewayIdempotencyHandler,
+	maintenanceHandler *MaintenanceHandler,
+	hooksHandler *GatewayHooksHandler,
+	metricsHandler *MetricsHandler,
+	healthHandler *HealthHandler,
 	_ *service.IdempotencyCoordinator,
 	_ *service.IdempotencyCleanupService,
 ) *Handlers {
 	return &Handlers{
-		Auth:          authHandler,
-		User:          userHandler,
-		APIKey:        apiKeyHandler,
-		Usage:         usageHandler,
-		Redeem:        redeemHandler,
-		Subscription:  subscriptionHandler,
-		Announcement:  announcementHandler,
-		Admin:         adminHandlers,
-		Gateway:       gatewayHandler,
-		OpenAIGateway: openaiGatewayHandler,
-		SoraGateway:   soraGatewayHandler,
-		SoraClient:    soraClientHandler,
-		Setting:       settingHandler,
-		Totp:          totpHandler,
+		Auth:               authHandler,
+		User:               userHandler,
+		APIKey:             apiKeyHandler,
+		Usage:              usageHandler,
+		Redeem:             redeemHandler,
+		Subscription:       subscriptionHandler,
+		Announcement:       announcementHandler,
+		Admin:              adminHandlers,
+		Gateway:            gatewayHandler,
+		OpenAIGateway:      openaiGatewayHandler,
+		SoraGateway:        soraGatewayHandler,
+		SoraClient:         soraClientHandler,
+		Setting:            settingHandler,
+		Totp:               totpHandler,
+		Batch:              batchHandler,
+		File:               fileHandler,
+		BackgroundResponse: backgroundResponseHandler,
+		SSEReplay:          sseReplayHandler,
+		ResponseCache:      responseCacheHandler,
+		ConversationStore:  conversationStoreHandler,
+		ParamSanitize:      paramSanitizeHandler,
+		ContextOverflow:    contextOverflowHandler,
+		ChatMultiChoice:    chatMultiChoiceHandler,
+		DeadLetter:         deadLetterHandler,
+		ContentLog:         contentLogHandler,
+		Idempotency:        idempotencyHandler,
+		Maintenance:        maintenanceHandler,
+		Hooks:              hooksHandler,
+		Metrics:            metricsHandler,
+		Health:             healthHandler,
 	}
 }
 
@@ -119,6 +171,22 @@ var ProviderSet = wire.NewSet(
 	NewOpenAIGatewayHandler,
 	NewSoraGatewayHandler,
 	NewTotpHandler,
+	NewBatchHandler,
+	NewFileHandler,
+	NewBackgroundResponseHandler,
+	NewSSEReplayHandler,
+	NewResponseCacheHandler,
+	NewConversationStoreHandler,
+	NewParamSanitizeHandler,
+	NewContextOverflowHandler,
+	NewChatMultiChoiceHandler,
+	NewDeadLetterHandler,
+	NewContentLogHandler,
+	NewGatewayIdempotencyHandler,
+	NewMaintenanceHandler,
+	NewGatewayHooksHandler,
+	NewMetricsHandler,
+	NewHealthHandler,
 	ProvideSettingHandler,
 
 	// Admin handlers
@@ -144,6 +212,16 @@ var ProviderSet = wire.NewSet(
 	admin.NewErrorPassthroughHandler,
 	admin.NewAdminAPIKeyHandler,
 	admin.NewScheduledTestHandler,
+	admin.NewAccountHealthHandler,
+	admin.NewAccountValidationHandler,
+	admin.NewAccountUsageWindowHandler,
+	admin.NewTenantHandler,
+	admin.NewAuditLogHandler,
+	admin.NewRuntimeSettingsHandler,
+	admin.NewDeadLetterHandler,
+	admin.NewContentLogHandler,
+	admin.NewMaintenanceHandler,
+	admin.NewBackupHandler,
 
 	// AdminHandlers and Handlers constructors
 	ProvideAdminHandlers,
@@ -169,7 +169,7 @@ func TestResponsesToAnthropic_TextOnly(t *testing.T) {
 		Usage: &ResponsesUsage{InputTokens: 10, OutputTokens: 5, TotalTokens: 15},
 	}
 
-	anth := ResponsesToAnthropic(resp, "claude-opus-4-6")
+	anth := ResponsesToAnthropic(resp, "claude-opus-4-6", true)
 	assert.Equal(t, "resp_123", anth.ID)
 	assert.Equal(t, "claude-opus-4-6", anth.Model)
 	assert.Equal(t, "end_turn", anth.StopReason)
@@ -201,7 +201,7 @@ func TestResponsesToAnthropic_ToolUse(t *testing.T) {
 		},
 	}
 
-	anth := ResponsesToAnthropic(resp, "claude-opus-4-6")
+	anth := ResponsesToAnthropic(resp, "claude-opus-4-6", true)
 	assert.Equal(t, "tool_use", anth.StopReason)
 	require.Len(t, anth.Content, 2)
 	assert.Equal(t, "text", anth.Content[0].Type)
@@ -231,12 +231,16 @@ func TestResponsesToAnthropic_Reasoning(t *testing.T) {
 		},
 	}
 
-	anth := ResponsesToAnthropic(resp, "claude-opus-4-6")
+	anth := ResponsesToAnthropic(resp, "claude-opus-4-6", true)
 	require.Len(t, anth.Content, 2)
 	assert.Equal(t, "thinking", anth.Content[0].Type)
 	assert.Equal(t, "Thinking about the answer...", anth.Content[0].Thinking)
 	assert.Equal(t, "text", anth.Content[1].Type)
 	assert.Equal(t, "42", anth.Content[1].Text)
+
+	anth = ResponsesToAnthropic(resp, "claude-opus-4-6", false)
+	require.Len(t, anth.Content, 1)
+	assert.Equal(t, "text", anth.Content[0].Type)
 }
 
 func TestResponsesToAnthropic_Incomplete(t *testing.T) {
@@ -255,7 +259,7 @@ func TestResponsesToAnthropic_Incomplete(t *testing.T) {
 		},
 	}
 
-	anth := ResponsesToAnthropic(resp, "claude-opus-4-6")
+	anth := ResponsesToAnthropic(resp, "claude-opus-4-6", true)
 	assert.Equal(t, "max_tokens", anth.StopReason)
 }
 
@@ -267,7 +271,7 @@ func TestResponsesToAnthropic_EmptyOutput(t *testing.T) {
 		Output: []ResponsesOutput{},
 	}
 
-	anth := ResponsesToAnthropic(resp, "claude-opus-4-6")
+	anth := ResponsesToAnthropic(resp, "claude-opus-4-6", true)
 	require.Len(t, anth.Content, 1)
 	assert.Equal(t, "text", anth.Content[0].Type)
 	assert.Equal(t, "", anth.Content[0].Text)
@@ -421,12 +425,48 @@ func TestStreamingReasoning(t *testing.T) {
 	assert.Equal(t, "thinking_delta", events[0].Delta.Type)
 	assert.Equal(t, "Let me think...", events[0].Delta.Thinking)
 
-	// reasoning done
+	// summary done keeps the block open for the signature
 	events = ResponsesEventToAnthropicEvents(&ResponsesStreamEvent{
 		Type: "response.reasoning_summary_text.done",
 	}, state)
-	require.Len(t, events, 1)
-	assert.Equal(t, "content_block_stop", events[0].Type)
+	assert.Empty(t, events)
+
+	// reasoning item done: encrypted content becomes the signature, then the block closes
+	events = ResponsesEventToAnthropicEvents(&ResponsesStreamEvent{
+		Type:        "response.output_item.done",
+		OutputIndex: 0,
+		Item:        &ResponsesOutput{Type: "reasoning", EncryptedContent: "gAAAA-enc"},
+	}, state)
+	require.Len(t, events, 2)
+	assert.Equal(t, "signature_delta", events[0].Delta.Type)
+	assert.Equal(t, "oai-reasoning:gAAAA-enc", events[0].Delta.Signature)
+	assert.Equal(t, "content_block_stop", events[1].Type)
+}
+
+func TestStreamingReasoningSuppressed(t *testing.T) {
+	state := NewResponsesEventToAnthropicState()
+	state.IncludeThinking = false
+
+	ResponsesEventToAnthropicEvents(&ResponsesStreamEvent{
+		Type:     "response.created",
+		Response: &ResponsesResponse{ID: "resp_4", Model: "gpt-5.2"},
+	}, state)
+
+	var events []AnthropicStreamEvent
+	for _, evt := range []ResponsesStreamEvent{
+		{Type: "response.output_item.added", OutputIndex: 0, Item: &ResponsesOutput{Type: "reasoning"}},
+		{Type: "response.reasoning_text.delta", OutputIndex: 0, Delta: "hidden"},
+		{Type: "response.reasoning_text.done", OutputIndex: 0},
+		{Type: "response.output_item.done", OutputIndex: 0, Item: &ResponsesOutput{Type: "reasoning"}},
+		{Type: "response.output_text.delta", OutputIndex: 1, Delta: "Hi"},
+	} {
+		events = append(events, ResponsesEventToAnthropicEvents(&evt, state)...)
+	}
+	require.Len(t, events, 2)
+	assert.Equal(t, "text", events[0].ContentBlock.Type)
+	require.NotNil(t, events[0].Index)
+	assert.Equal(t, 0, *events[0].Index)
+	assert.Equal(t, "Hi", events[1].Delta.Text)
 }
 
 func TestStreamingIncomplete(t *testing.T) {
@@ -608,7 +648,7 @@ func TestResponsesToAnthropic_Failed(t *testing.T) {
 		Usage:  &ResponsesUsage{InputTokens: 30, OutputTokens: 0},
 	}
 
-	anth := ResponsesToAnthropic(resp, "claude-opus-4-6")
+	anth := ResponsesToAnthropic(resp, "claude-opus-4-6", true)
 	// Failed status defaults to "end_turn" stop reason
 	assert.Equal(t, "end_turn", anth.StopReason)
 	// Should have at least an empty text block
@@ -631,13 +671,43 @@ func TestAnthropicToResponses_ThinkingEnabled(t *testing.T) {
 	resp, err := AnthropicToResponses(req)
 	require.NoError(t, err)
 	require.NotNil(t, resp.Reasoning)
-	// thinking.type is ignored for effort; default high applies.
+	// budget_tokens=10000 falls in the high bucket.
 	assert.Equal(t, "high", resp.Reasoning.Effort)
 	assert.Equal(t, "auto", resp.Reasoning.Summary)
 	assert.Contains(t, resp.Include, "reasoning.encrypted_content")
 	assert.NotContains(t, resp.Include, "reasoning.summary")
 }
 
+func TestAnthropicToResponses_ThinkingBudgetMapsToEffort(t *testing.T) {
+	cases := map[int]string{1024: "low", 4096: "medium", 10240: "high", 32768: "xhigh"}
+	for budget, want := range cases {
+		req := &AnthropicRequest{
+			Model:     "gpt-5.2",
+			MaxTokens: 64000,
+			Messages:  []AnthropicMessage{{Role: "user", Content: json.RawMessage(`"Hello"`)}},
+			Thinking:  &AnthropicThinking{Type: "enabled", BudgetTokens: budget},
+		}
+
+		resp, err := AnthropicToResponses(req)
+		require.NoError(t, err)
+		assert.Equal(t, want, resp.Reasoning.Effort, "budget %d", budget)
+		// Round-trips back to the level's default budget.
+		assert.Equal(t, budget, ThinkingBudgetForEffort(want))
+	}
+
+	// output_config.effort wins over the thinking budget.
+	req := &AnthropicRequest{
+		Model:        "gpt-5.2",
+		MaxTokens:    64000,
+		Messages:     []AnthropicMessage{{Role: "user", Content: json.RawMessage(`"Hello"`)}},
+		Thinking:     &AnthropicThinking{Type: "enabled", BudgetTokens: 1024},
+		OutputConfig: &AnthropicOutputConfig{Effort: "max"},
+	}
+	resp, err := AnthropicToResponses(req)
+	require.NoError(t, err)
+	assert.Equal(t, "xhigh", resp.Reasoning.Effort)
+}
+
 func TestAnthropicToResponses_ThinkingAdaptive(t *testing.T) {
 	req := &AnthropicRequest{
 		Model:     "gpt-5.2",
@@ -831,12 +901,104 @@ func TestAnthropicToResponses_ToolChoiceSpecific(t *testing.T) {
 	resp, err := AnthropicToResponses(req)
 	require.NoError(t, err)
 
+	// Responses API uses the flat function form.
+	assert.JSONEq(t, `{"type":"function","name":"get_weather"}`, string(resp.ToolChoice))
+}
+
+func TestAnthropicToResponses_DisableParallelToolUse(t *testing.T) {
+	req := &AnthropicRequest{
+		Model:      "gpt-5.2",
+		MaxTokens:  1024,
+		Messages:   []AnthropicMessage{{Role: "user", Content: json.RawMessage(`"Hello"`)}},
+		ToolChoice: json.RawMessage(`{"type":"auto","disable_parallel_tool_use":true}`),
+	}
+
+	resp, err := AnthropicToResponses(req)
+	require.NoError(t, err)
+	require.NotNil(t, resp.ParallelToolCalls)
+	assert.False(t, *resp.ParallelToolCalls)
+
+	req.ToolChoice = json.RawMessage(`{"type":"auto"}`)
+	resp, err = AnthropicToResponses(req)
+	require.NoError(t, err)
+	assert.Nil(t, resp.ParallelToolCalls)
+}
+
+func TestResponsesToAnthropicRequest_ToolChoiceAndParallelToolCalls(t *testing.T) {
+	parallel := false
+	req := &ResponsesRequest{
+		Model:             "claude-sonnet-4-5",
+		Input:             json.RawMessage(`"Hello"`),
+		ToolChoice:        json.RawMessage(`{"type":"function","name":"get_weather"}`),
+		ParallelToolCalls: &parallel,
+	}
+
+	out, err := ResponsesToAnthropicRequest(req)
+	require.NoError(t, err)
+
 	var tc map[string]any
-	require.NoError(t, json.Unmarshal(resp.ToolChoice, &tc))
-	assert.Equal(t, "function", tc["type"])
-	fn, ok := tc["function"].(map[string]any)
-	require.True(t, ok)
-	assert.Equal(t, "get_weather", fn["name"])
+	require.NoError(t, json.Unmarshal(out.ToolChoice, &tc))
+	assert.Equal(t, "tool", tc["type"])
+	assert.Equal(t, "get_weather", tc["name"])
+	assert.Equal(t, true, tc["disable_parallel_tool_use"])
+
+	req.ToolChoice = nil
+	out, err = ResponsesToAnthropicRequest(req)
+	require.NoError(t, err)
+	tc = nil
+	require.NoError(t, json.Unmarshal(out.ToolChoice, &tc))
+	assert.Equal(t, "auto", tc["type"])
+	assert.NotContains(t, tc, "name")
+	assert.Equal(t, true, tc["disable_parallel_tool_use"])
+}
+
+func TestResponsesToAnthropicRequest_RejectsCodeInterpreter(t *testing.T) {
+	_, err := ResponsesToAnthropicRequest(&ResponsesRequest{
+		Model: "claude-sonnet-4-5",
+		Input: json.RawMessage(`"Hello"`),
+		Tools: []ResponsesTool{{Type: "code_interpreter", Container: json.RawMessage(`{"type":"auto"}`)}},
+	})
+	require.ErrorContains(t, err, "code_interpreter tool is not supported")
+}
+
+func TestSamplingParams_AnthropicResponsesRoundTrip(t *testing.T) {
+	topK := 40
+	req := &AnthropicRequest{
+		Model:     "gpt-5.2",
+		MaxTokens: 1024,
+		TopK:      &topK,
+		StopSeqs:  []string{"END"},
+		Messages:  []AnthropicMessage{{Role: "user", Content: json.RawMessage(`"Hello"`)}},
+	}
+	resp, err := AnthropicToResponses(req)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"stop_sequences", "top_k"}, resp.DroppedForResponses())
+	body, err := json.Marshal(resp)
+	require.NoError(t, err)
+	assert.NotContains(t, string(body), "top_k")
+	assert.NotContains(t, string(body), "stop")
+
+	hub := &ResponsesRequest{
+		Model:         "claude-sonnet-4-5",
+		Input:         json.RawMessage(`"Hello"`),
+		StopSequences: []string{"\n\n", "END"},
+		TopK:          &topK,
+		DroppedParams: []string{"logit_bias"},
+	}
+	assert.Equal(t, []string{"logit_bias", "stop", "top_k"}, hub.DroppedForResponses())
+	out, err := ResponsesToAnthropicRequest(hub)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"\n\n", "END"}, out.StopSeqs)
+	require.NotNil(t, out.TopK)
+	assert.Equal(t, 40, *out.TopK)
+	assert.Equal(t, []string{"logit_bias"}, hub.DroppedParams)
+
+	// extended thinking rejects top_k
+	hub.Reasoning = &ResponsesReasoning{Effort: "high"}
+	out, err = ResponsesToAnthropicRequest(hub)
+	require.NoError(t, err)
+	assert.Nil(t, out.TopK)
+	assert.Equal(t, []string{"logit_bias", "top_k"}, hub.DroppedParams)
 }
 
 // ---------------------------------------------------------------------------
@@ -1134,3 +1296,105 @@ func TestAnthropicToResponses_ToolWithNilSchema(t *testing.T) {
 	assert.JSONEq(t, `"object"`, string(params["type"]))
 	assert.JSONEq(t, `{}`, string(params["properties"]))
 }
+
+// ---------------------------------------------------------------------------
+// Reasoning round-trip tests
+// ---------------------------------------------------------------------------
+
+func TestReasoningRoundTrip_OpenAIUpstream(t *testing.T) {
+	// OpenAI reasoning → Anthropic thinking block carrying the encrypted content.
+	anth := ResponsesToAnthropic(&ResponsesResponse{
+		ID: "resp_1",
+		Output: []ResponsesOutput{
+			{Type: "reasoning", EncryptedContent: "enc-1", Summary: []ResponsesSummary{{Type: "summary_text", Text: "plan"}}},
+			{Type: "function_call", CallID: "call_1", Name: "lookup", Arguments: "{}"},
+		},
+	}, "gpt-5.2", true)
+	require.Equal(t, "thinking", anth.Content[0].Type)
+	assert.Equal(t, "plan", anth.Content[0].Thinking)
+	assert.Equal(t, "oai-reasoning:enc-1", anth.Content[0].Signature)
+
+	// The client echoes the block back; the next turn restores the reasoning item.
+	assistant, err := json.Marshal(anth.Content)
+	require.NoError(t, err)
+	resp, err := AnthropicToResponses(&AnthropicRequest{
+		Model:     "gpt-5.2",
+		MaxTokens: 1024,
+		Messages: []AnthropicMessage{
+			{Role: "user", Content: json.RawMessage(`"look it up"`)},
+			{Role: "assistant", Content: assistant},
+			{Role: "user", Content: json.RawMessage(`[{"type":"tool_result","tool_use_id":"call_1","content":"42"}]`)},
+		},
+	})
+	require.NoError(t, err)
+	var items []map[string]any
+	require.NoError(t, json.Unmarshal(resp.Input, &items))
+	require.Equal(t, "reasoning", items[1]["type"])
+	assert.Equal(t, "enc-1", items[1]["encrypted_content"])
+	assert.Equal(t, "function_call", items[2]["type"])
+
+	// Native Anthropic thinking cannot be verified by OpenAI and is dropped;
+	// the summary field is always present on reasoning items.
+	resp, err = AnthropicToResponses(&AnthropicRequest{
+		Model:     "gpt-5.2",
+		MaxTokens: 1024,
+		Messages: []AnthropicMessage{
+			{Role: "user", Content: json.RawMessage(`"hi"`)},
+			{Role: "assistant", Content: json.RawMessage(`[{"type":"thinking","thinking":"x","signature":"EqQBanthropic"},{"type":"thinking","signature":"oai-reasoning:enc-2"},{"type":"text","text":"hello"}]`)},
+			{Role: "user", Content: json.RawMessage(`"again"`)},
+		},
+	})
+	require.NoError(t, err)
+	require.NoError(t, json.Unmarshal(resp.Input, &items))
+	require.Len(t, items, 4)
+	assert.Equal(t, "enc-2", items[1]["encrypted_content"])
+	assert.Equal(t, []any{}, items[1]["summary"])
+}
+
+func TestReasoningRoundTrip_AnthropicUpstream(t *testing.T) {
+	// Anthropic thinking → Responses reasoning item carrying the signature.
+	out := AnthropicToResponsesResponse(&AnthropicResponse{
+		ID:    "msg_1",
+		Model: "claude-sonnet-4-5",
+		Content: []AnthropicContentBlock{
+			{Type: "thinking", Thinking: "plan", Signature: "sig-1"},
+			{Type: "text", Text: "answer"},
+		},
+	})
+	require.Equal(t, "reasoning", out.Output[0].Type)
+	assert.Equal(t, "anthropic-thinking:sig-1", out.Output[0].EncryptedContent)
+
+	// The client echoes the item back; the next turn restores the thinking block.
+	input, err := json.Marshal([]map[string]any{
+		{"role": "user", "content": "question"},
+		{"type": "reasoning", "encrypted_content": "anthropic-thinking:sig-1", "summary": []map[string]string{{"type": "summary_text", "text": "plan"}}},
+		{"type": "reasoning", "encrypted_content": "gAAAA-openai", "summary": []any{}},
+		{"role": "assistant", "content": []map[string]string{{"type": "output_text", "text": "answer"}}},
+		{"role": "user", "content": "follow up"},
+	})
+	require.NoError(t, err)
+	anth, err := ResponsesToAnthropicRequest(&ResponsesRequest{Model: "claude-sonnet-4-5", Input: input})
+	require.NoError(t, err)
+	require.Len(t, anth.Messages, 3)
+	var blocks []AnthropicContentBlock
+	require.NoError(t, json.Unmarshal(anth.Messages[1].Content, &blocks))
+	require.Len(t, blocks, 2)
+	assert.Equal(t, AnthropicContentBlock{Type: "thinking", Thinking: "plan", Signature: "sig-1"}, blocks[0])
+	assert.Equal(t, "text", blocks[1].Type)
+}
+
+func TestStreamingThinkingSignatureToResponses(t *testing.T) {
+	state := NewAnthropicEventToResponsesState()
+	idx := 0
+	AnthropicEventToResponsesEvents(&AnthropicStreamEvent{Type: "message_start", Message: &AnthropicResponse{ID: "msg_1", Model: "claude-sonnet-4-5"}}, state)
+	AnthropicEventToResponsesEvents(&AnthropicStreamEvent{Type: "content_block_start", Index: &idx, ContentBlock: &AnthropicContentBlock{Type: "thinking"}}, state)
+	AnthropicEventToResponsesEvents(&AnthropicStreamEvent{Type: "content_block_delta", Index: &idx, Delta: &AnthropicDelta{Type: "thinking_delta", Thinking: "plan"}}, state)
+	AnthropicEventToResponsesEvents(&AnthropicStreamEvent{Type: "content_block_delta", Index: &idx, Delta: &AnthropicDelta{Type: "signature_delta", Signature: "sig-1"}}, state)
+	events := AnthropicEventToResponsesEvents(&AnthropicStreamEvent{Type: "content_block_stop", Index: &idx}, state)
+
+	require.Len(t, events, 2)
+	assert.Equal(t, "plan", events[0].Text)
+	require.Equal(t, "response.output_item.done", events[1].Type)
+	assert.Equal(t, "anthropic-thinking:sig-1", events[1].Item.EncryptedContent)
+	assert.Equal(t, []ResponsesSummary{{Type: "summary_text", Text: "plan"}}, events[1].Item.Summary)
+}
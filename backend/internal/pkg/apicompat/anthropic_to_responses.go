@@ -33,6 +33,14 @@ func AnthropicToResponses(req *AnthropicRequest) (*ResponsesRequest, error) {
 	storeFalse := false
 	out.Store = &storeFalse
 
+	// The Responses API has no stop sequences or top_k; both are dropped.
+	if len(req.StopSeqs) > 0 {
+		out.DroppedParams = append(out.DroppedParams, "stop_sequences")
+	}
+	if req.TopK != nil {
+		out.DroppedParams = append(out.DroppedParams, "top_k")
+	}
+
 	if req.MaxTokens > 0 {
 		v := req.MaxTokens
 		if v < minMaxOutputTokens {
@@ -45,19 +53,28 @@ func AnthropicToResponses(req *AnthropicRequest) (*ResponsesRequest, error) {
 		out.Tools = convertAnthropicToolsToResponses(req.Tools)
 	}
 
-	// Determine reasoning effort: only output_config.effort controls the
-	// level; thinking.type is ignored. Default is high when unset (both
-	// Anthropic and OpenAI default to high).
+	// Determine reasoning effort: output_config.effort wins; otherwise an
+	// explicit thinking budget (type=enabled) is bucketed into a level.
+	// Default is high when unset (both Anthropic and OpenAI default to high).
 	// Anthropic levels map 1:1 to OpenAI: low→low, medium→medium, high→high, max→xhigh.
 	effort := "high" // default → both sides' default
 	if req.OutputConfig != nil && req.OutputConfig.Effort != "" {
-		effort = req.OutputConfig.Effort
+		effort = mapAnthropicEffortToResponses(req.OutputConfig.Effort)
+	} else if req.Thinking != nil && req.Thinking.Type == "enabled" && req.Thinking.BudgetTokens > 0 {
+		effort = EffortForThinkingBudget(req.Thinking.BudgetTokens)
 	}
 	out.Reasoning = &ResponsesReasoning{
-		Effort:  mapAnthropicEffortToResponses(effort),
+		Effort:  effort,
 		Summary: "auto",
 	}
 
+	// output_config.format / output_format → text.format
+	text, err := convertAnthropicOutputFormatToResponses(req)
+	if err != nil {
+		return nil, err
+	}
+	out.Text = text
+
 	// Convert tool_choice
 	if len(req.ToolChoice) > 0 {
 		tc, err := convertAnthropicToolChoiceToResponses(req.ToolChoice)
@@ -65,42 +82,46 @@ func AnthropicToResponses(req *AnthropicRequest) (*ResponsesRequest, error) {
 			return nil, fmt.Errorf("convert tool_choice: %w", err)
 		}
 		out.ToolChoice = tc
+
+		// tool_choice.disable_parallel_tool_use → parallel_tool_calls=false
+		if anthropicToolChoiceDisablesParallel(req.ToolChoice) {
+			parallel := false
+			out.ParallelToolCalls = &parallel
+		}
 	}
 
 	return out, nil
 }
 
+// anthropicToolChoiceDisablesParallel reports whether an Anthropic tool_choice
+// object sets disable_parallel_tool_use=true.
+func anthropicToolChoiceDisablesParallel(raw json.RawMessage) bool {
+	var tc struct {
+		DisableParallelToolUse bool `json:"disable_parallel_tool_use"`
+	}
+	if err := json.Unmarshal(raw, &tc); err != nil {
+		return false
+	}
+	return tc.DisableParallelToolUse
+}
+
 // convertAnthropicToolChoiceToResponses maps Anthropic tool_choice to Responses format.
 //
 //	{"type":"auto"}            → "auto"
 //	{"type":"any"}             → "required"
 //	{"type":"none"}            → "none"
-//	{"type":"tool","name":"X"} → {"type":"function","function":{"name":"X"}}
+//	{"type":"tool","name":"X"} → {"type":"function","name":"X"}
 func convertAnthropicToolChoiceToResponses(raw json.RawMessage) (json.RawMessage, error) {
-	var tc struct {
-		Type string `json:"type"`
-		Name string `json:"name"`
-	}
-	if err := json.Unmarshal(raw, &tc); err != nil {
+	var probe map[string]json.RawMessage
+	if err := json.Unmarshal(raw, &probe); err != nil {
 		return nil, err
 	}
-
-	switch tc.Type {
-	case "auto":
-		return json.Marshal("auto")
-	case "any":
-		return json.Marshal("required")
-	case "none":
-		return json.Marshal("none")
-	case "tool":
-		return json.Marshal(map[string]any{
-			"type":     "function",
-			"function": map[string]string{"name": tc.Name},
-		})
-	default:
+	tc, ok := ParseAnthropicToolChoice(raw)
+	if !ok {
 		// Pass through unknown types as-is
 		return raw, nil
 	}
+	return tc.ResponsesJSON()
 }
 
 // convertAnthropicToResponsesInput builds the Responses API input items array
@@ -232,7 +253,9 @@ func anthropicUserToResponses(raw json.RawMessage) ([]ResponsesInputItem, error)
 // anthropicAssistantToResponses handles an Anthropic assistant message.
 // Text content → assistant message with output_text parts.
 // tool_use blocks → function_call items.
-// thinking blocks → ignored (OpenAI doesn't accept them as input).
+// thinking blocks → reasoning items when they carry OpenAI encrypted reasoning
+// (see encodeOpenAIReasoningSignature); native Anthropic thinking is dropped
+// because OpenAI cannot verify its signature.
 func anthropicAssistantToResponses(raw json.RawMessage) ([]ResponsesInputItem, error) {
 	// Try plain string.
 	var s string
@@ -252,6 +275,30 @@ func anthropicAssistantToResponses(raw json.RawMessage) ([]ResponsesInputItem, e
 
 	var items []ResponsesInputItem
 
+	// Reasoning precedes the message and tool calls it produced.
+	for _, b := range blocks {
+		if b.Type != "thinking" {
+			continue
+		}
+		encrypted, ok := decodeOpenAIReasoningSignature(b.Signature)
+		if !ok {
+			continue
+		}
+		summary := []ResponsesSummary{}
+		if b.Thinking != "" {
+			summary = append(summary, ResponsesSummary{Type: "summary_text", Text: b.Thinking})
+		}
+		summaryJSON, err := json.Marshal(summary)
+		if err != nil {
+			return nil, err
+		}
+		items = append(items, ResponsesInputItem{
+			Type:             "reasoning",
+			EncryptedContent: encrypted,
+			Summary:          summaryJSON,
+		})
+	}
+
 	// Text content → assistant message with output_text content parts.
 	text := extractAnthropicTextFromBlocks(blocks)
 	if text != "" {
@@ -307,10 +354,17 @@ func fromResponsesCallID(id string) string {
 	return id
 }
 
-// anthropicImageToDataURI converts an AnthropicImageSource to a data URI string.
+// anthropicImageToDataURI converts an AnthropicImageSource to a data URI
+// string, or returns the URL as-is for url sources.
 // Returns "" if the source is nil or has no data.
 func anthropicImageToDataURI(src *AnthropicImageSource) string {
-	if src == nil || src.Data == "" {
+	if src == nil {
+		return ""
+	}
+	if src.Type == "url" {
+		return src.URL
+	}
+	if src.Data == "" {
 		return ""
 	}
 	mediaType := src.MediaType
@@ -397,6 +451,27 @@ func mapAnthropicEffortToResponses(effort string) string {
 	return effort // low→low, medium→medium, high→high, unknown→passthrough
 }
 
+// EffortForThinkingBudget buckets an Anthropic thinking.budget_tokens value
+// into a Responses reasoning effort. Inverse of ThinkingBudgetForEffort: each
+// level's default budget falls inside its own bucket.
+//
+//	< 2048          → low
+//	2048  – 8191    → medium
+//	8192  – 32767   → high
+//	>= 32768        → xhigh
+func EffortForThinkingBudget(budget int) string {
+	switch {
+	case budget < 2048:
+		return "low"
+	case budget < 8192:
+		return "medium"
+	case budget < 32768:
+		return "high"
+	default:
+		return "xhigh"
+	}
+}
+
 // convertAnthropicToolsToResponses maps Anthropic tool definitions to
 // Responses API tools. Server-side tools like web_search are mapped to their
 // OpenAI equivalents; regular tools become function tools.
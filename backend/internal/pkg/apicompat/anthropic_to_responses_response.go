@@ -3,8 +3,7 @@ package apicompat
 import (
 	"crypto/rand"
 	"encoding/hex"
-	"encoding/json"
-	"fmt"
+	"strings"
 	"time"
 )
 
@@ -33,15 +32,17 @@ func AnthropicToResponsesResponse(resp *AnthropicResponse) *ResponsesResponse {
 	for _, block := range resp.Content {
 		switch block.Type {
 		case "thinking":
-			if block.Thinking != "" {
-				outputs = append(outputs, ResponsesOutput{
-					Type: "reasoning",
-					ID:   generateItemID(),
-					Summary: []ResponsesSummary{{
-						Type: "summary_text",
-						Text: block.Thinking,
-					}},
-				})
+			if block.Thinking != "" || block.Signature != "" {
+				item := ResponsesOutput{
+					Type:             "reasoning",
+					ID:               generateItemID(),
+					EncryptedContent: encodeAnthropicThinkingEncrypted(block.Signature),
+					Summary:          []ResponsesSummary{},
+				}
+				if block.Thinking != "" {
+					item.Summary = append(item.Summary, ResponsesSummary{Type: "summary_text", Text: block.Thinking})
+				}
+				outputs = append(outputs, item)
 			}
 		case "text":
 			if block.Text != "" {
@@ -63,6 +64,29 @@ func AnthropicToResponsesResponse(resp *AnthropicResponse) *ResponsesResponse {
 				Arguments: args,
 				Status:    "completed",
 			})
+		case "mcp_tool_use":
+			args := "{}"
+			if len(block.Input) > 0 {
+				args = string(block.Input)
+			}
+			outputs = append(outputs, ResponsesOutput{
+				Type:        "mcp_call",
+				ID:          block.ID,
+				ServerLabel: block.ServerName,
+				Name:        block.Name,
+				Arguments:   args,
+				Status:      "completed",
+			})
+		case "mcp_tool_result":
+			for i := len(outputs) - 1; i >= 0; i-- {
+				if outputs[i].Type == "mcp_call" && outputs[i].ID == block.ToolUseID {
+					outputs[i].Output, outputs[i].Error = anthropicMCPToolResultOutput(block)
+					if outputs[i].Error != "" {
+						outputs[i].Status = "failed"
+					}
+					break
+				}
+			}
 		}
 	}
 
@@ -141,14 +165,27 @@ type AnthropicEventToResponsesState struct {
 	// Current output tracking
 	OutputIndex     int
 	CurrentItemID   string
-	CurrentItemType string // "message" | "function_call" | "reasoning"
+	CurrentItemType string // "message" | "function_call" | "reasoning" | "mcp_call"
 
 	// For message output: accumulate text parts
 	ContentIndex int
 
-	// For function_call: track per-output info
-	CurrentCallID string
-	CurrentName   string
+	// For function_call: track per-output info. CurrentArguments accumulates
+	// input_json_delta fragments so the done events carry the full arguments.
+	CurrentCallID    string
+	CurrentName      string
+	CurrentArguments strings.Builder
+
+	// For mcp_call: the item stays open from mcp_tool_use until the matching
+	// mcp_tool_result supplies its output or error.
+	CurrentServerLabel string
+	CurrentMCPOutput   string
+	CurrentMCPError    string
+
+	// For reasoning: thinking text and signature, carried on the done item
+	// (the signature as encrypted_content, see encodeAnthropicThinkingEncrypted).
+	CurrentThinking  strings.Builder
+	CurrentSignature string
 
 	// Usage from message_delta
 	InputTokens          int
@@ -206,12 +243,13 @@ func FinalizeAnthropicResponsesStream(state *AnthropicEventToResponsesState) []R
 }
 
 // ResponsesEventToSSE formats a ResponsesStreamEvent as an SSE data line.
+// Hot paths should use AppendResponsesEventSSE with a reused buffer instead.
 func ResponsesEventToSSE(evt ResponsesStreamEvent) (string, error) {
-	data, err := json.Marshal(evt)
+	data, err := AppendResponsesEventSSE(nil, &evt)
 	if err != nil {
 		return "", err
 	}
-	return fmt.Sprintf("event: %s\ndata: %s\n\n", evt.Type, data), nil
+	return string(data), nil
 }
 
 // --- internal handlers ---
@@ -258,6 +296,10 @@ func anthToResHandleContentBlockStart(evt *AnthropicStreamEvent, state *Anthropi
 		}))
 
 	case "text":
+		// An mcp_call without a result yet is closed before the text starts
+		if state.CurrentItemType == "mcp_call" {
+			events = append(events, closeCurrentResponsesItem(state)...)
+		}
 		// If we don't have an open message item, open one
 		if state.CurrentItemType != "message" {
 			state.CurrentItemID = generateItemID()
@@ -294,6 +336,47 @@ func anthToResHandleContentBlockStart(evt *AnthropicStreamEvent, state *Anthropi
 				Status: "in_progress",
 			},
 		}))
+
+	case "mcp_tool_use":
+		events = append(events, closeCurrentResponsesItem(state)...)
+
+		// The Anthropic id is kept so the item can be replayed as mcp_tool_use.
+		state.CurrentItemID = evt.ContentBlock.ID
+		state.CurrentItemType = "mcp_call"
+		state.CurrentName = evt.ContentBlock.Name
+		state.CurrentServerLabel = evt.ContentBlock.ServerName
+
+		events = append(events,
+			makeResponsesEvent(state, "response.output_item.added", &ResponsesStreamEvent{
+				OutputIndex: state.OutputIndex,
+				Item: &ResponsesOutput{
+					Type:        "mcp_call",
+					ID:          state.CurrentItemID,
+					ServerLabel: state.CurrentServerLabel,
+					Name:        state.CurrentName,
+					Status:      "in_progress",
+				},
+			}),
+			makeResponsesEvent(state, "response.mcp_call.in_progress", &ResponsesStreamEvent{
+				OutputIndex: state.OutputIndex,
+				ItemID:      state.CurrentItemID,
+			}),
+		)
+
+	case "mcp_tool_result":
+		if state.CurrentItemType != "mcp_call" || state.CurrentItemID != evt.ContentBlock.ToolUseID {
+			return nil
+		}
+		state.CurrentMCPOutput, state.CurrentMCPError = anthropicMCPToolResultOutput(*evt.ContentBlock)
+		eventType := "response.mcp_call.completed"
+		if state.CurrentMCPError != "" {
+			eventType = "response.mcp_call.failed"
+		}
+		events = append(events, makeResponsesEvent(state, eventType, &ResponsesStreamEvent{
+			OutputIndex: state.OutputIndex,
+			ItemID:      state.CurrentItemID,
+		}))
+		events = append(events, closeCurrentResponsesItem(state)...)
 	}
 
 	return events
@@ -320,6 +403,7 @@ func anthToResHandleContentBlockDelta(evt *AnthropicStreamEvent, state *Anthropi
 		if evt.Delta.Thinking == "" {
 			return nil
 		}
+		state.CurrentThinking.WriteString(evt.Delta.Thinking)
 		return []ResponsesStreamEvent{makeResponsesEvent(state, "response.reasoning_summary_text.delta", &ResponsesStreamEvent{
 			OutputIndex:  state.OutputIndex,
 			SummaryIndex: 0,
@@ -331,6 +415,14 @@ func anthToResHandleContentBlockDelta(evt *AnthropicStreamEvent, state *Anthropi
 		if evt.Delta.PartialJSON == "" {
 			return nil
 		}
+		state.CurrentArguments.WriteString(evt.Delta.PartialJSON)
+		if state.CurrentItemType == "mcp_call" {
+			return []ResponsesStreamEvent{makeResponsesEvent(state, "response.mcp_call_arguments.delta", &ResponsesStreamEvent{
+				OutputIndex: state.OutputIndex,
+				Delta:       evt.Delta.PartialJSON,
+				ItemID:      state.CurrentItemID,
+			})}
+		}
 		return []ResponsesStreamEvent{makeResponsesEvent(state, "response.function_call_arguments.delta", &ResponsesStreamEvent{
 			OutputIndex: state.OutputIndex,
 			Delta:       evt.Delta.PartialJSON,
@@ -340,7 +432,8 @@ func anthToResHandleContentBlockDelta(evt *AnthropicStreamEvent, state *Anthropi
 		})}
 
 	case "signature_delta":
-		// Anthropic signature deltas have no Responses equivalent; skip
+		// Surfaced as encrypted_content on the reasoning item's done event.
+		state.CurrentSignature += evt.Delta.Signature
 		return nil
 	}
 
@@ -356,6 +449,7 @@ func anthToResHandleContentBlockStop(evt *AnthropicStreamEvent, state *Anthropic
 				OutputIndex:  state.OutputIndex,
 				SummaryIndex: 0,
 				ItemID:       state.CurrentItemID,
+				Text:         state.CurrentThinking.String(),
 			}),
 		}
 		events = append(events, closeCurrentResponsesItem(state)...)
@@ -369,11 +463,23 @@ func anthToResHandleContentBlockStop(evt *AnthropicStreamEvent, state *Anthropic
 				ItemID:      state.CurrentItemID,
 				CallID:      state.CurrentCallID,
 				Name:        state.CurrentName,
+				Arguments:   currentFunctionCallArguments(state),
 			}),
 		}
 		events = append(events, closeCurrentResponsesItem(state)...)
 		return events
 
+	case "mcp_call":
+		// Arguments are complete; the item stays open for mcp_tool_result.
+		// The result block's own stop finds no open item and emits nothing.
+		return []ResponsesStreamEvent{
+			makeResponsesEvent(state, "response.mcp_call_arguments.done", &ResponsesStreamEvent{
+				OutputIndex: state.OutputIndex,
+				ItemID:      state.CurrentItemID,
+				Arguments:   currentFunctionCallArguments(state),
+			}),
+		}
+
 	case "message":
 		// Emit output_text.done (text block is done, but message item stays open for potential more blocks)
 		return []ResponsesStreamEvent{
@@ -427,27 +533,63 @@ func closeCurrentResponsesItem(state *AnthropicEventToResponsesState) []Response
 		return nil
 	}
 
-	itemType := state.CurrentItemType
-	itemID := state.CurrentItemID
+	item := &ResponsesOutput{
+		Type:   state.CurrentItemType,
+		ID:     state.CurrentItemID,
+		Status: "completed",
+	}
+	if item.Type == "function_call" {
+		item.CallID = state.CurrentCallID
+		item.Name = state.CurrentName
+		item.Arguments = currentFunctionCallArguments(state)
+	}
+	if item.Type == "mcp_call" {
+		item.ServerLabel = state.CurrentServerLabel
+		item.Name = state.CurrentName
+		item.Arguments = currentFunctionCallArguments(state)
+		item.Output = state.CurrentMCPOutput
+		item.Error = state.CurrentMCPError
+		if item.Error != "" {
+			item.Status = "failed"
+		}
+	}
+	if item.Type == "reasoning" {
+		item.EncryptedContent = encodeAnthropicThinkingEncrypted(state.CurrentSignature)
+		item.Summary = []ResponsesSummary{}
+		if state.CurrentThinking.Len() > 0 {
+			item.Summary = append(item.Summary, ResponsesSummary{Type: "summary_text", Text: state.CurrentThinking.String()})
+		}
+	}
 
 	// Reset
 	state.CurrentItemType = ""
 	state.CurrentItemID = ""
 	state.CurrentCallID = ""
 	state.CurrentName = ""
+	state.CurrentServerLabel = ""
+	state.CurrentMCPOutput = ""
+	state.CurrentMCPError = ""
+	state.CurrentArguments.Reset()
+	state.CurrentThinking.Reset()
+	state.CurrentSignature = ""
 	state.OutputIndex++
 	state.ContentIndex = 0
 
 	return []ResponsesStreamEvent{makeResponsesEvent(state, "response.output_item.done", &ResponsesStreamEvent{
 		OutputIndex: state.OutputIndex - 1, // Use the index before increment
-		Item: &ResponsesOutput{
-			Type:   itemType,
-			ID:     itemID,
-			Status: "completed",
-		},
+		Item:        item,
 	})}
 }
 
+// currentFunctionCallArguments returns the accumulated tool input JSON. A
+// tool_use without input_json_delta fragments has empty input ({}).
+func currentFunctionCallArguments(state *AnthropicEventToResponsesState) string {
+	if state.CurrentArguments.Len() == 0 {
+		return "{}"
+	}
+	return state.CurrentArguments.String()
+}
+
 func makeResponsesCreatedEvent(state *AnthropicEventToResponsesState) ResponsesStreamEvent {
 	seq := state.SequenceNumber
 	state.SequenceNumber++
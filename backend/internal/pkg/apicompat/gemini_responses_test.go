@@ -0,0 +1,281 @@
+package apicompat
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// ---------------------------------------------------------------------------
+// GeminiToResponses tests
+// ---------------------------------------------------------------------------
+
+func TestGeminiToResponses_BasicText(t *testing.T) {
+	temp := 0.3
+	req := &GeminiRequest{
+		SystemInstruction: &GeminiContent{Parts: []GeminiPart{{Text: "Be brief."}}},
+		Contents: []GeminiContent{
+			{Role: "user", Parts: []GeminiPart{{Text: "Hello"}}},
+			{Role: "model", Parts: []GeminiPart{{Text: "thinking...", Thought: true}, {Text: "Hi!"}}},
+			{Role: "user", Parts: []GeminiPart{{Text: "Describe"}, {InlineData: &GeminiBlob{MimeType: "image/png", Data: "iVBOR"}}}},
+		},
+		GenerationConfig: &GeminiGenerationConfig{Temperature: &temp, MaxOutputTokens: 10},
+		SafetySettings:   json.RawMessage(`[{"category":"HARM_CATEGORY_HARASSMENT","threshold":"BLOCK_NONE"}]`),
+	}
+
+	resp, err := GeminiToResponses(req, "gpt-5.2")
+	require.NoError(t, err)
+	assert.Equal(t, "gpt-5.2", resp.Model)
+	assert.Equal(t, 0.3, *resp.Temperature)
+	assert.Equal(t, minMaxOutputTokens, *resp.MaxOutputTokens)
+	assert.False(t, *resp.Store)
+	require.NotNil(t, resp.Reasoning)
+	assert.Equal(t, "medium", resp.Reasoning.Effort)
+
+	var items []ResponsesInputItem
+	require.NoError(t, json.Unmarshal(resp.Input, &items))
+	require.Len(t, items, 4)
+	assert.Equal(t, "system", items[0].Role)
+	assert.Equal(t, "user", items[1].Role)
+	assert.Equal(t, "assistant", items[2].Role)
+
+	var assistantParts []ResponsesContentPart
+	require.NoError(t, json.Unmarshal(items[2].Content, &assistantParts))
+	require.Len(t, assistantParts, 1)
+	assert.Equal(t, "Hi!", assistantParts[0].Text)
+
+	var userParts []ResponsesContentPart
+	require.NoError(t, json.Unmarshal(items[3].Content, &userParts))
+	require.Len(t, userParts, 2)
+	assert.Equal(t, "input_image", userParts[1].Type)
+	assert.Equal(t, "data:image/png;base64,iVBOR", userParts[1].ImageURL)
+}
+
+func TestGeminiToResponses_FunctionCalls(t *testing.T) {
+	req := &GeminiRequest{
+		Contents: []GeminiContent{
+			{Role: "user", Parts: []GeminiPart{{Text: "Weather in Paris and Rome?"}}},
+			{Role: "model", Parts: []GeminiPart{
+				{FunctionCall: &GeminiFunctionCall{Name: "get_weather", Args: json.RawMessage(`{"city":"Paris"}`)}},
+				{FunctionCall: &GeminiFunctionCall{Name: "get_weather", Args: json.RawMessage(`{"city":"Rome"}`)}},
+			}},
+			{Role: "user", Parts: []GeminiPart{
+				{FunctionResponse: &GeminiFunctionResponse{Name: "get_weather", Response: json.RawMessage(`{"temp":20}`)}},
+				{FunctionResponse: &GeminiFunctionResponse{Name: "get_weather", Response: json.RawMessage(`{"temp":25}`)}},
+				{FunctionResponse: &GeminiFunctionResponse{Name: "unknown_fn", Response: json.RawMessage(`{"ok":true}`)}},
+			}},
+		},
+	}
+
+	resp, err := GeminiToResponses(req, "gpt-5.2")
+	require.NoError(t, err)
+
+	var items []ResponsesInputItem
+	require.NoError(t, json.Unmarshal(resp.Input, &items))
+	require.Len(t, items, 6)
+	assert.Equal(t, "function_call", items[1].Type)
+	assert.Equal(t, `{"city":"Paris"}`, items[1].Arguments)
+	assert.Equal(t, "function_call", items[2].Type)
+	assert.NotEqual(t, items[1].CallID, items[2].CallID)
+
+	assert.Equal(t, "function_call_output", items[3].Type)
+	assert.Equal(t, items[1].CallID, items[3].CallID)
+	assert.Equal(t, `{"temp":20}`, items[3].Output)
+	assert.Equal(t, "function_call_output", items[4].Type)
+	assert.Equal(t, items[2].CallID, items[4].CallID)
+
+	// Orphan functionResponse degrades to user text.
+	assert.Equal(t, "user", items[5].Role)
+	assert.Contains(t, string(items[5].Content), "unknown_fn")
+}
+
+func TestGeminiToResponses_FunctionCallIDs(t *testing.T) {
+	req := &GeminiRequest{
+		Contents: []GeminiContent{
+			{Role: "model", Parts: []GeminiPart{{FunctionCall: &GeminiFunctionCall{ID: "call_1", Name: "f"}}}},
+			{Role: "user", Parts: []GeminiPart{{FunctionResponse: &GeminiFunctionResponse{ID: "call_1", Name: "f"}}}},
+		},
+	}
+
+	resp, err := GeminiToResponses(req, "gpt-5.2")
+	require.NoError(t, err)
+
+	var items []ResponsesInputItem
+	require.NoError(t, json.Unmarshal(resp.Input, &items))
+	require.Len(t, items, 2)
+	assert.Equal(t, "fc_call_1", items[0].CallID)
+	assert.Equal(t, "{}", items[0].Arguments)
+	assert.Equal(t, "fc_call_1", items[1].CallID)
+	assert.Equal(t, "(empty)", items[1].Output)
+}
+
+func TestGeminiToResponses_ToolsAndToolConfig(t *testing.T) {
+	req := &GeminiRequest{
+		Contents: []GeminiContent{{Role: "user", Parts: []GeminiPart{{Text: "hi"}}}},
+		Tools: []GeminiTool{
+			{FunctionDeclarations: []GeminiFunctionDeclaration{
+				{Name: "get_weather", Description: "Get weather", Parameters: json.RawMessage(`{"type":"OBJECT","properties":{"city":{"type":"STRING"},"days":{"type":"ARRAY","items":{"type":"INTEGER"}}}}`)},
+				{Name: "ping"},
+			}},
+			{GoogleSearch: json.RawMessage(`{}`)},
+		},
+		ToolConfig: &GeminiToolConfig{FunctionCallingConfig: &GeminiFunctionCallingConfig{Mode: "ANY", AllowedFunctionNames: []string{"get_weather"}}},
+	}
+
+	resp, err := GeminiToResponses(req, "gpt-5.2")
+	require.NoError(t, err)
+	require.Len(t, resp.Tools, 3)
+	assert.Equal(t, "function", resp.Tools[0].Type)
+	assert.JSONEq(t, `{"type":"object","properties":{"city":{"type":"string"},"days":{"type":"array","items":{"type":"integer"}}}}`, string(resp.Tools[0].Parameters))
+	assert.JSONEq(t, `{"type":"object","properties":{}}`, string(resp.Tools[1].Parameters))
+	assert.Equal(t, "web_search", resp.Tools[2].Type)
+	assert.JSONEq(t, `{"type":"function","name":"get_weather"}`, string(resp.ToolChoice))
+
+	for mode, want := range map[string]string{"AUTO": `"auto"`, "NONE": `"none"`, "ANY": `"required"`} {
+		tc, err := convertGeminiToolConfigToResponses(&GeminiFunctionCallingConfig{Mode: mode})
+		require.NoError(t, err)
+		assert.JSONEq(t, want, string(tc), mode)
+	}
+	_, err = convertGeminiToolConfigToResponses(&GeminiFunctionCallingConfig{Mode: "BOGUS"})
+	require.Error(t, err)
+}
+
+func TestGeminiThinkingToResponsesEffort(t *testing.T) {
+	budget := func(v int) *int { return &v }
+	assert.Equal(t, "", geminiThinkingToResponsesEffort(nil))
+	assert.Equal(t, "high", geminiThinkingToResponsesEffort(&GeminiThinkingConfig{ThinkingLevel: "HIGH", ThinkingBudget: budget(0)}))
+	assert.Equal(t, "", geminiThinkingToResponsesEffort(&GeminiThinkingConfig{ThinkingBudget: budget(-1)}))
+	assert.Equal(t, "low", geminiThinkingToResponsesEffort(&GeminiThinkingConfig{ThinkingBudget: budget(0)}))
+	assert.Equal(t, "medium", geminiThinkingToResponsesEffort(&GeminiThinkingConfig{ThinkingBudget: budget(4096)}))
+	assert.Equal(t, "high", geminiThinkingToResponsesEffort(&GeminiThinkingConfig{ThinkingBudget: budget(24576)}))
+}
+
+// ---------------------------------------------------------------------------
+// ResponsesToGemini tests
+// ---------------------------------------------------------------------------
+
+func TestResponsesToGemini(t *testing.T) {
+	resp := &ResponsesResponse{
+		ID:     "resp_1",
+		Status: "completed",
+		Output: []ResponsesOutput{
+			{Type: "reasoning", Summary: []ResponsesSummary{{Type: "summary_text", Text: "Let me think"}}},
+			{Type: "message", Content: []ResponsesContentPart{{Type: "output_text", Text: "Sunny"}}},
+			{Type: "function_call", CallID: "call_9", Name: "get_weather", Arguments: `{"city":"Paris"}`},
+		},
+		Usage: &ResponsesUsage{
+			InputTokens:         12,
+			OutputTokens:        8,
+			InputTokensDetails:  &ResponsesInputTokensDetails{CachedTokens: 4},
+			OutputTokensDetails: &ResponsesOutputTokensDetails{ReasoningTokens: 3},
+		},
+	}
+
+	out := ResponsesToGemini(resp, "gemini-2.5-pro", false)
+	require.Len(t, out.Candidates, 1)
+	cand := out.Candidates[0]
+	assert.Equal(t, "model", cand.Content.Role)
+	assert.Equal(t, "STOP", cand.FinishReason)
+	require.Len(t, cand.Content.Parts, 2)
+	assert.Equal(t, "Sunny", cand.Content.Parts[0].Text)
+	require.NotNil(t, cand.Content.Parts[1].FunctionCall)
+	assert.Equal(t, "call_9", cand.Content.Parts[1].FunctionCall.ID)
+	assert.JSONEq(t, `{"city":"Paris"}`, string(cand.Content.Parts[1].FunctionCall.Args))
+	assert.Equal(t, "gemini-2.5-pro", out.ModelVersion)
+	assert.Equal(t, "resp_1", out.ResponseID)
+
+	require.NotNil(t, out.UsageMetadata)
+	assert.Equal(t, 12, out.UsageMetadata.PromptTokenCount)
+	assert.Equal(t, 8, out.UsageMetadata.CandidatesTokenCount)
+	assert.Equal(t, 20, out.UsageMetadata.TotalTokenCount)
+	assert.Equal(t, 4, out.UsageMetadata.CachedContentTokenCount)
+	assert.Equal(t, 3, out.UsageMetadata.ThoughtsTokenCount)
+
+	withThoughts := ResponsesToGemini(resp, "gemini-2.5-pro", true)
+	require.Len(t, withThoughts.Candidates[0].Content.Parts, 3)
+	assert.True(t, withThoughts.Candidates[0].Content.Parts[0].Thought)
+}
+
+func TestResponsesToGemini_FinishReasons(t *testing.T) {
+	incomplete := ResponsesToGemini(&ResponsesResponse{
+		Status:            "incomplete",
+		IncompleteDetails: &ResponsesIncompleteDetails{Reason: "max_output_tokens"},
+	}, "m", false)
+	assert.Equal(t, "MAX_TOKENS", incomplete.Candidates[0].FinishReason)
+	assert.NotNil(t, incomplete.Candidates[0].Content.Parts)
+
+	filtered := ResponsesToGemini(&ResponsesResponse{
+		Status:            "incomplete",
+		IncompleteDetails: &ResponsesIncompleteDetails{Reason: "content_filter"},
+	}, "m", false)
+	assert.Equal(t, "SAFETY", filtered.Candidates[0].FinishReason)
+
+	failed := ResponsesToGemini(&ResponsesResponse{Status: "failed"}, "m", false)
+	assert.Equal(t, "OTHER", failed.Candidates[0].FinishReason)
+}
+
+// ---------------------------------------------------------------------------
+// Streaming Responses → Gemini tests
+// ---------------------------------------------------------------------------
+
+func TestResponsesEventToGeminiChunks(t *testing.T) {
+	state := NewResponsesEventToGeminiState()
+	state.Model = "gemini-2.5-flash"
+
+	chunks := ResponsesEventToGeminiChunks(&ResponsesStreamEvent{
+		Type:     "response.created",
+		Response: &ResponsesResponse{ID: "resp_7"},
+	}, state)
+	assert.Empty(t, chunks)
+
+	chunks = ResponsesEventToGeminiChunks(&ResponsesStreamEvent{Type: "response.reasoning_summary_text.delta", Delta: "hmm"}, state)
+	assert.Empty(t, chunks, "thoughts are hidden unless includeThoughts")
+
+	chunks = ResponsesEventToGeminiChunks(&ResponsesStreamEvent{Type: "response.output_text.delta", Delta: "Hel"}, state)
+	require.Len(t, chunks, 1)
+	assert.Equal(t, "Hel", chunks[0].Candidates[0].Content.Parts[0].Text)
+	assert.Equal(t, "resp_7", chunks[0].ResponseID)
+	assert.Equal(t, "gemini-2.5-flash", chunks[0].ModelVersion)
+	assert.Empty(t, chunks[0].Candidates[0].FinishReason)
+
+	chunks = ResponsesEventToGeminiChunks(&ResponsesStreamEvent{
+		Type: "response.output_item.done",
+		Item: &ResponsesOutput{Type: "function_call", CallID: "call_1", Name: "f", Arguments: `{"a":1}`},
+	}, state)
+	require.Len(t, chunks, 1)
+	require.NotNil(t, chunks[0].Candidates[0].Content.Parts[0].FunctionCall)
+	assert.Equal(t, "f", chunks[0].Candidates[0].Content.Parts[0].FunctionCall.Name)
+
+	chunks = ResponsesEventToGeminiChunks(&ResponsesStreamEvent{
+		Type:     "response.completed",
+		Response: &ResponsesResponse{Status: "completed", Usage: &ResponsesUsage{InputTokens: 5, OutputTokens: 2}},
+	}, state)
+	require.Len(t, chunks, 1)
+	assert.Equal(t, "STOP", chunks[0].Candidates[0].FinishReason)
+	require.NotNil(t, chunks[0].UsageMetadata)
+	assert.Equal(t, 7, chunks[0].UsageMetadata.TotalTokenCount)
+
+	assert.Empty(t, ResponsesEventToGeminiChunks(&ResponsesStreamEvent{Type: "response.output_text.delta", Delta: "late"}, state))
+	assert.Empty(t, FinalizeResponsesGeminiStream(state))
+
+	sse, err := GeminiChunkToSSE(chunks[0])
+	require.NoError(t, err)
+	assert.Contains(t, sse, `data: {"candidates":[{"content":{"role":"model","parts":[]},"finishReason":"STOP","index":0}]`)
+	assert.Contains(t, sse, "\n\n")
+}
+
+func TestFinalizeResponsesGeminiStream_AbnormalTermination(t *testing.T) {
+	state := NewResponsesEventToGeminiState()
+	state.IncludeThoughts = true
+
+	chunks := ResponsesEventToGeminiChunks(&ResponsesStreamEvent{Type: "response.reasoning_summary_text.delta", Delta: "hmm"}, state)
+	require.Len(t, chunks, 1)
+	assert.True(t, chunks[0].Candidates[0].Content.Parts[0].Thought)
+
+	final := FinalizeResponsesGeminiStream(state)
+	require.Len(t, final, 1)
+	assert.Equal(t, "OTHER", final[0].Candidates[0].FinishReason)
+	assert.Empty(t, FinalizeResponsesGeminiStream(state))
+}
@@ -0,0 +1,383 @@
+package apicompat
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// GeminiToResponses converts a Gemini generateContent request into a Responses
+// API request. The model comes from the URL path ({model}:generateContent)
+// rather than the body. safetySettings are ignored.
+func GeminiToResponses(req *GeminiRequest, model string) (*ResponsesRequest, error) {
+	input, err := convertGeminiToResponsesInput(req.SystemInstruction, req.Contents)
+	if err != nil {
+		return nil, err
+	}
+
+	inputJSON, err := json.Marshal(input)
+	if err != nil {
+		return nil, err
+	}
+
+	out := &ResponsesRequest{
+		Model:   model,
+		Input:   inputJSON,
+		Include: []string{"reasoning.encrypted_content"},
+	}
+
+	storeFalse := false
+	out.Store = &storeFalse
+
+	effort := ""
+	if gc := req.GenerationConfig; gc != nil {
+		out.Temperature = gc.Temperature
+		out.TopP = gc.TopP
+		if gc.MaxOutputTokens > 0 {
+			v := gc.MaxOutputTokens
+			if v < minMaxOutputTokens {
+				v = minMaxOutputTokens
+			}
+			out.MaxOutputTokens = &v
+		}
+		effort = geminiThinkingToResponsesEffort(gc.ThinkingConfig)
+	}
+	if effort == "" {
+		effort = "medium" // OpenAI default; Gemini defaults to dynamic thinking
+	}
+	out.Reasoning = &ResponsesReasoning{
+		Effort:  effort,
+		Summary: "auto",
+	}
+
+	if len(req.Tools) > 0 {
+		out.Tools = convertGeminiToolsToResponses(req.Tools)
+	}
+
+	if req.ToolConfig != nil && req.ToolConfig.FunctionCallingConfig != nil {
+		tc, err := convertGeminiToolConfigToResponses(req.ToolConfig.FunctionCallingConfig)
+		if err != nil {
+			return nil, fmt.Errorf("convert toolConfig: %w", err)
+		}
+		out.ToolChoice = tc
+	}
+
+	return out, nil
+}
+
+// convertGeminiToResponsesInput builds the Responses input array.
+//
+// Gemini function calls only carry an id in newer API versions, so calls
+// without one get a synthetic id and are paired with the next functionResponse
+// of the same name (Gemini requires responses in call order).
+func convertGeminiToResponsesInput(system *GeminiContent, contents []GeminiContent) ([]ResponsesInputItem, error) {
+	var out []ResponsesInputItem
+
+	if system != nil {
+		if sysText := extractGeminiText(system.Parts); sysText != "" {
+			content, _ := json.Marshal(sysText)
+			out = append(out, ResponsesInputItem{
+				Role:    "system",
+				Content: content,
+			})
+		}
+	}
+
+	calls := &geminiCallTracker{pending: make(map[string][]string)}
+	for _, content := range contents {
+		var (
+			items []ResponsesInputItem
+			err   error
+		)
+		if content.Role == "model" {
+			items, err = geminiModelToResponses(content.Parts, calls)
+		} else {
+			items, err = geminiUserToResponses(content.Parts, calls)
+		}
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, items...)
+	}
+	return out, nil
+}
+
+// geminiCallTracker pairs functionCall / functionResponse parts by id or name.
+type geminiCallTracker struct {
+	pending map[string][]string // function name → unanswered call ids (FIFO)
+	seq     int
+}
+
+func (t *geminiCallTracker) issue(fc *GeminiFunctionCall) string {
+	id := ""
+	if fc.ID != "" {
+		id = toResponsesCallID(fc.ID)
+	} else {
+		t.seq++
+		id = fmt.Sprintf("fc_gemini_%d", t.seq)
+	}
+	t.pending[fc.Name] = append(t.pending[fc.Name], id)
+	return id
+}
+
+// resolve returns the call id a functionResponse answers, or "" when no
+// matching call exists in the history.
+func (t *geminiCallTracker) resolve(fr *GeminiFunctionResponse) string {
+	queue := t.pending[fr.Name]
+	if fr.ID != "" {
+		id := toResponsesCallID(fr.ID)
+		for i, pendingID := range queue {
+			if pendingID == id {
+				t.pending[fr.Name] = append(queue[:i:i], queue[i+1:]...)
+				return id
+			}
+		}
+		return ""
+	}
+	if len(queue) == 0 {
+		return ""
+	}
+	t.pending[fr.Name] = queue[1:]
+	return queue[0]
+}
+
+// geminiUserToResponses handles a user (or function) turn.
+// functionResponse parts → function_call_output items (emitted first, they
+// answer the preceding model turn); text / inline or http(s) fileData images → user message.
+func geminiUserToResponses(parts []GeminiPart, calls *geminiCallTracker) ([]ResponsesInputItem, error) {
+	var items []ResponsesInputItem
+	var contentParts []ResponsesContentPart
+
+	for _, p := range parts {
+		switch {
+		case p.FunctionResponse != nil:
+			output := "(empty)"
+			if len(p.FunctionResponse.Response) > 0 && string(p.FunctionResponse.Response) != "null" {
+				output = string(p.FunctionResponse.Response)
+			}
+			callID := calls.resolve(p.FunctionResponse)
+			if callID == "" {
+				// Orphan response: the Responses API rejects function_call_output
+				// without a matching call, so keep it as plain text.
+				contentParts = append(contentParts, ResponsesContentPart{
+					Type: "input_text",
+					Text: fmt.Sprintf("Function %s returned: %s", p.FunctionResponse.Name, output),
+				})
+				continue
+			}
+			items = append(items, ResponsesInputItem{
+				Type:   "function_call_output",
+				CallID: callID,
+				Output: output,
+			})
+		case p.InlineData != nil:
+			if uri := geminiBlobToDataURI(p.InlineData); uri != "" {
+				contentParts = append(contentParts, ResponsesContentPart{Type: "input_image", ImageURL: uri})
+			}
+		case p.FileData != nil:
+			if uri := geminiFileDataToImageURL(p.FileData); uri != "" {
+				contentParts = append(contentParts, ResponsesContentPart{Type: "input_image", ImageURL: uri})
+			}
+		case p.Text != "" && !p.Thought:
+			contentParts = append(contentParts, ResponsesContentPart{Type: "input_text", Text: p.Text})
+		}
+	}
+
+	if len(contentParts) > 0 {
+		partsJSON, err := json.Marshal(contentParts)
+		if err != nil {
+			return nil, err
+		}
+		items = append(items, ResponsesInputItem{Role: "user", Content: partsJSON})
+	}
+	return items, nil
+}
+
+// geminiModelToResponses handles a model turn.
+// Text → assistant message; functionCall → function_call items;
+// thought parts are dropped (OpenAI doesn't accept them as input).
+func geminiModelToResponses(parts []GeminiPart, calls *geminiCallTracker) ([]ResponsesInputItem, error) {
+	var items []ResponsesInputItem
+
+	if text := extractGeminiText(parts); text != "" {
+		partsJSON, err := json.Marshal([]ResponsesContentPart{{Type: "output_text", Text: text}})
+		if err != nil {
+			return nil, err
+		}
+		items = append(items, ResponsesInputItem{Role: "assistant", Content: partsJSON})
+	}
+
+	for _, p := range parts {
+		if p.FunctionCall == nil {
+			continue
+		}
+		args := "{}"
+		if len(p.FunctionCall.Args) > 0 && string(p.FunctionCall.Args) != "null" {
+			args = string(p.FunctionCall.Args)
+		}
+		fcID := calls.issue(p.FunctionCall)
+		items = append(items, ResponsesInputItem{
+			Type:      "function_call",
+			CallID:    fcID,
+			Name:      p.FunctionCall.Name,
+			Arguments: args,
+			ID:        fcID,
+		})
+	}
+	return items, nil
+}
+
+// extractGeminiText joins all non-thought text parts.
+func extractGeminiText(parts []GeminiPart) string {
+	var texts []string
+	for _, p := range parts {
+		if p.Text != "" && !p.Thought {
+			texts = append(texts, p.Text)
+		}
+	}
+	return strings.Join(texts, "\n\n")
+}
+
+// geminiBlobToDataURI converts inline image data to a data URI.
+// Non-image blobs (audio, PDF, ...) are not supported and return "".
+func geminiBlobToDataURI(blob *GeminiBlob) string {
+	if blob == nil || blob.Data == "" || !strings.HasPrefix(blob.MimeType, "image/") {
+		return ""
+	}
+	return "data:" + blob.MimeType + ";base64," + blob.Data
+}
+
+// geminiFileDataToImageURL returns the URI of an image fileData part when it
+// is a public http(s) URL. Gemini Files API URIs are not reachable by other
+// upstreams and return "".
+func geminiFileDataToImageURL(fd *GeminiFileData) string {
+	if fd == nil || (fd.MimeType != "" && !strings.HasPrefix(fd.MimeType, "image/")) {
+		return ""
+	}
+	if strings.HasPrefix(fd.FileURI, "https://generativelanguage.googleapis.com/") {
+		return ""
+	}
+	if strings.HasPrefix(fd.FileURI, "https://") || strings.HasPrefix(fd.FileURI, "http://") {
+		return fd.FileURI
+	}
+	return ""
+}
+
+// geminiThinkingToResponsesEffort maps thinkingConfig to a reasoning effort.
+// thinkingLevel wins over thinkingBudget; a budget of -1 (dynamic) or an
+// unset config returns "" so the caller applies its default.
+//
+//	budget 0        → low (reasoning models can't disable thinking)
+//	budget ≤ 1024   → low
+//	budget ≤ 8192   → medium
+//	budget > 8192   → high
+func geminiThinkingToResponsesEffort(cfg *GeminiThinkingConfig) string {
+	if cfg == nil {
+		return ""
+	}
+	if level := strings.ToLower(strings.TrimSpace(cfg.ThinkingLevel)); level != "" {
+		return level
+	}
+	if cfg.ThinkingBudget == nil {
+		return ""
+	}
+	switch budget := *cfg.ThinkingBudget; {
+	case budget < 0:
+		return ""
+	case budget <= 1024:
+		return "low"
+	case budget <= 8192:
+		return "medium"
+	default:
+		return "high"
+	}
+}
+
+// convertGeminiToolsToResponses maps function declarations to function tools
+// and googleSearch to web_search.
+func convertGeminiToolsToResponses(tools []GeminiTool) []ResponsesTool {
+	var out []ResponsesTool
+	for _, t := range tools {
+		if len(t.GoogleSearch) > 0 {
+			out = append(out, ResponsesTool{Type: "web_search"})
+		}
+		for _, fd := range t.FunctionDeclarations {
+			params := fd.ParametersJSONSchema
+			if len(params) == 0 {
+				params = normalizeGeminiSchema(fd.Parameters)
+			}
+			out = append(out, ResponsesTool{
+				Type:        "function",
+				Name:        fd.Name,
+				Description: fd.Description,
+				Parameters:  normalizeToolParameters(params),
+			})
+		}
+	}
+	return out
+}
+
+// normalizeGeminiSchema lower-cases the OpenAPI-style "type" values Gemini
+// uses ("OBJECT", "STRING", ...) so the schema is valid JSON Schema.
+func normalizeGeminiSchema(schema json.RawMessage) json.RawMessage {
+	if len(schema) == 0 || string(schema) == "null" {
+		return schema
+	}
+	var v any
+	if err := json.Unmarshal(schema, &v); err != nil {
+		return schema
+	}
+	out, err := json.Marshal(lowercaseSchemaTypes(v))
+	if err != nil {
+		return schema
+	}
+	return out
+}
+
+func lowercaseSchemaTypes(v any) any {
+	switch node := v.(type) {
+	case map[string]any:
+		for k, child := range node {
+			if k == "type" {
+				if s, ok := child.(string); ok {
+					node[k] = strings.ToLower(s)
+					continue
+				}
+			}
+			node[k] = lowercaseSchemaTypes(child)
+		}
+		return node
+	case []any:
+		for i, child := range node {
+			node[i] = lowercaseSchemaTypes(child)
+		}
+		return node
+	default:
+		return v
+	}
+}
+
+// convertGeminiToolConfigToResponses maps functionCallingConfig to tool_choice.
+//
+//	AUTO              → "auto"
+//	NONE              → "none"
+//	ANY / VALIDATED   → "required", or a specific function when exactly one
+//	                    name is allowed
+func convertGeminiToolConfigToResponses(cfg *GeminiFunctionCallingConfig) (json.RawMessage, error) {
+	switch strings.ToUpper(strings.TrimSpace(cfg.Mode)) {
+	case "", "MODE_UNSPECIFIED":
+		return nil, nil
+	}
+	tc, ok := ParseGeminiToolChoice(cfg)
+	if !ok {
+		return nil, fmt.Errorf("unsupported function calling mode %q", cfg.Mode)
+	}
+	return tc.ResponsesJSON()
+}
+
+// IncludeThoughts reports whether the client asked for thought parts in the
+// response (generationConfig.thinkingConfig.includeThoughts).
+func (r *GeminiRequest) IncludeThoughts() bool {
+	return r != nil && r.GenerationConfig != nil && r.GenerationConfig.ThinkingConfig != nil &&
+		r.GenerationConfig.ThinkingConfig.IncludeThoughts
+}
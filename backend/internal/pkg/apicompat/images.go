@@ -0,0 +1,69 @@
+package apicompat
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// RewriteResponsesInputImages calls rewrite for every input_image URL in the
+// request input and stores the returned URL when ok is true. Items are
+// rewritten through generic maps so unknown fields survive untouched.
+func RewriteResponsesInputImages(req *ResponsesRequest, rewrite func(imageURL string) (string, bool)) error {
+	if req == nil || len(req.Input) == 0 || req.Input[0] != '[' {
+		return nil
+	}
+	var items []map[string]json.RawMessage
+	if err := json.Unmarshal(req.Input, &items); err != nil {
+		return fmt.Errorf("parse input: %w", err)
+	}
+
+	changed := false
+	for _, item := range items {
+		content := item["content"]
+		if len(content) == 0 || content[0] != '[' {
+			continue
+		}
+		var parts []map[string]json.RawMessage
+		if err := json.Unmarshal(content, &parts); err != nil {
+			continue
+		}
+		partsChanged := false
+		for _, part := range parts {
+			var partType, imageURL string
+			if json.Unmarshal(part["type"], &partType) != nil || partType != "input_image" {
+				continue
+			}
+			if json.Unmarshal(part["image_url"], &imageURL) != nil || imageURL == "" {
+				continue
+			}
+			next, ok := rewrite(imageURL)
+			if !ok {
+				continue
+			}
+			raw, err := json.Marshal(next)
+			if err != nil {
+				return err
+			}
+			part["image_url"] = raw
+			partsChanged = true
+		}
+		if !partsChanged {
+			continue
+		}
+		raw, err := json.Marshal(parts)
+		if err != nil {
+			return err
+		}
+		item["content"] = raw
+		changed = true
+	}
+	if !changed {
+		return nil
+	}
+	raw, err := json.Marshal(items)
+	if err != nil {
+		return err
+	}
+	req.Input = raw
+	return nil
+}
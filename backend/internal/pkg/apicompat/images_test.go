@@ -0,0 +1,113 @@
+package apicompat
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// ---------------------------------------------------------------------------
+// Vision content translation tests
+// ---------------------------------------------------------------------------
+
+func TestResponsesToAnthropicRequest_ImageSources(t *testing.T) {
+	req := &ResponsesRequest{
+		Model: "claude-sonnet-4-5",
+		Input: json.RawMessage(`[{"role":"user","content":[
+			{"type":"input_text","text":"Compare"},
+			{"type":"input_image","image_url":"data:image/png;base64,iVBORw0KGgo="},
+			{"type":"input_image","image_url":"https://example.com/cat.jpg","detail":"low"},
+			{"type":"input_image","file_id":"file-upstream"}
+		]}]`),
+	}
+
+	out, err := ResponsesToAnthropicRequest(req)
+	require.NoError(t, err)
+	require.Len(t, out.Messages, 1)
+
+	var blocks []AnthropicContentBlock
+	require.NoError(t, json.Unmarshal(out.Messages[0].Content, &blocks))
+	require.Len(t, blocks, 3)
+	assert.Equal(t, "text", blocks[0].Type)
+	require.NotNil(t, blocks[1].Source)
+	assert.Equal(t, AnthropicImageSource{Type: "base64", MediaType: "image/png", Data: "iVBORw0KGgo="}, *blocks[1].Source)
+	require.NotNil(t, blocks[2].Source)
+	assert.Equal(t, AnthropicImageSource{Type: "url", URL: "https://example.com/cat.jpg"}, *blocks[2].Source)
+
+	// url sources must not carry empty base64 fields upstream
+	assert.NotContains(t, string(out.Messages[0].Content), `"data":""`)
+}
+
+func TestAnthropicToResponses_URLImage(t *testing.T) {
+	req := &AnthropicRequest{
+		Model:     "gpt-5.2",
+		MaxTokens: 1024,
+		Messages: []AnthropicMessage{{
+			Role:    "user",
+			Content: json.RawMessage(`[{"type":"image","source":{"type":"url","url":"https://example.com/cat.jpg"}},{"type":"text","text":"What is this?"}]`),
+		}},
+	}
+
+	resp, err := AnthropicToResponses(req)
+	require.NoError(t, err)
+
+	var items []ResponsesInputItem
+	require.NoError(t, json.Unmarshal(resp.Input, &items))
+	require.Len(t, items, 1)
+	var parts []ResponsesContentPart
+	require.NoError(t, json.Unmarshal(items[0].Content, &parts))
+	require.Len(t, parts, 2)
+	assert.Equal(t, ResponsesContentPart{Type: "input_image", ImageURL: "https://example.com/cat.jpg"}, parts[0])
+}
+
+func TestGeminiToResponses_FileDataImage(t *testing.T) {
+	req := &GeminiRequest{
+		Contents: []GeminiContent{{
+			Role: "user",
+			Parts: []GeminiPart{
+				{Text: "Describe"},
+				{FileData: &GeminiFileData{MimeType: "image/png", FileURI: "https://example.com/a.png"}},
+				{FileData: &GeminiFileData{MimeType: "image/png", FileURI: "https://generativelanguage.googleapis.com/v1beta/files/abc"}},
+				{FileData: &GeminiFileData{MimeType: "video/mp4", FileURI: "https://example.com/a.mp4"}},
+			},
+		}},
+	}
+
+	resp, err := GeminiToResponses(req, "gpt-5.2")
+	require.NoError(t, err)
+
+	var items []ResponsesInputItem
+	require.NoError(t, json.Unmarshal(resp.Input, &items))
+	require.Len(t, items, 1)
+	var parts []ResponsesContentPart
+	require.NoError(t, json.Unmarshal(items[0].Content, &parts))
+	require.Len(t, parts, 2)
+	assert.Equal(t, ResponsesContentPart{Type: "input_image", ImageURL: "https://example.com/a.png"}, parts[1])
+}
+
+func TestRewriteResponsesInputImages(t *testing.T) {
+	req := &ResponsesRequest{
+		Input: json.RawMessage(`[{"role":"user","content":[{"type":"input_text","text":"hi"},{"type":"input_image","image_url":"data:image/png;base64,AAAA","detail":"high"}]},{"type":"function_call_output","call_id":"c1","output":"ok"}]`),
+	}
+
+	var seen []string
+	require.NoError(t, RewriteResponsesInputImages(req, func(imageURL string) (string, bool) {
+		seen = append(seen, imageURL)
+		return "data:image/jpeg;base64,BBBB", true
+	}))
+	assert.Equal(t, []string{"data:image/png;base64,AAAA"}, seen)
+	assert.JSONEq(t, `[{"role":"user","content":[{"type":"input_text","text":"hi"},{"type":"input_image","image_url":"data:image/jpeg;base64,BBBB","detail":"high"}]},{"type":"function_call_output","call_id":"c1","output":"ok"}]`, string(req.Input))
+
+	// Unchanged input keeps its original bytes.
+	before := string(req.Input)
+	require.NoError(t, RewriteResponsesInputImages(req, func(string) (string, bool) { return "", false }))
+	assert.Equal(t, before, string(req.Input))
+
+	// String input is left alone.
+	str := &ResponsesRequest{Input: json.RawMessage(`"hello"`)}
+	require.NoError(t, RewriteResponsesInputImages(str, func(string) (string, bool) { return "x", true }))
+	assert.True(t, strings.Contains(string(str.Input), "hello"))
+}
@@ -0,0 +1,206 @@
+package apicompat
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// ---------------------------------------------------------------------------
+// Remote MCP tools
+//
+// The Responses API attaches remote MCP servers as tools of type "mcp"; the
+// model's calls come back as mcp_call output items. Anthropic's equivalent is
+// the MCP connector: servers go in the top-level mcp_servers field (beta
+// mcp-client-2025-04-04) and calls come back as mcp_tool_use / mcp_tool_result
+// content blocks.
+//
+//	Responses tool (type=mcp)          Anthropic mcp_servers entry
+//	server_label                       name
+//	server_url                         url
+//	authorization / Authorization hdr  authorization_token
+//	allowed_tools                      tool_configuration.allowed_tools
+//
+// The connector executes tool calls without asking, so requests that need
+// approval (require_approval other than "never"), OpenAI connectors
+// (connector_id), arbitrary headers and read_only filters are rejected with
+// an explicit error instead of being silently changed.
+// ---------------------------------------------------------------------------
+
+// anthropicMCPToolUseIDPrefix prefixes the ids of MCP connector tool calls.
+// mcp_call items carrying such an id came from Anthropic and can be replayed
+// there as mcp_tool_use / mcp_tool_result blocks.
+const anthropicMCPToolUseIDPrefix = "mcptoolu_"
+
+// splitResponsesMCPTools separates type=mcp tools, converted to Anthropic MCP
+// connector servers, from the remaining tools.
+func splitResponsesMCPTools(tools []ResponsesTool) ([]ResponsesTool, []AnthropicMCPServer, error) {
+	var rest []ResponsesTool
+	var servers []AnthropicMCPServer
+	seen := make(map[string]struct{})
+	for _, t := range tools {
+		if t.Type != "mcp" {
+			rest = append(rest, t)
+			continue
+		}
+		server, err := convertResponsesMCPToolToAnthropic(t)
+		if err != nil {
+			return nil, nil, err
+		}
+		if _, dup := seen[server.Name]; dup {
+			return nil, nil, fmt.Errorf("mcp tool %q: duplicate server_label", server.Name)
+		}
+		seen[server.Name] = struct{}{}
+		servers = append(servers, server)
+	}
+	return rest, servers, nil
+}
+
+func convertResponsesMCPToolToAnthropic(t ResponsesTool) (AnthropicMCPServer, error) {
+	label := strings.TrimSpace(t.ServerLabel)
+	if label == "" {
+		return AnthropicMCPServer{}, fmt.Errorf("mcp tool: server_label is required")
+	}
+	if t.ConnectorID != "" {
+		return AnthropicMCPServer{}, fmt.Errorf("mcp tool %q: connector_id is not supported by Anthropic upstreams, use server_url", label)
+	}
+	if strings.TrimSpace(t.ServerURL) == "" {
+		return AnthropicMCPServer{}, fmt.Errorf("mcp tool %q: server_url is required", label)
+	}
+
+	server := AnthropicMCPServer{Type: "url", URL: strings.TrimSpace(t.ServerURL), Name: label}
+	token := strings.TrimSpace(t.Authorization)
+	for name, value := range t.Headers {
+		if !strings.EqualFold(name, "Authorization") {
+			return AnthropicMCPServer{}, fmt.Errorf("mcp tool %q: only the Authorization header can be sent to MCP servers through Anthropic upstreams, got %q", label, name)
+		}
+		value = strings.TrimSpace(value)
+		if len(value) > len("bearer ") && strings.EqualFold(value[:len("bearer ")], "bearer ") {
+			value = strings.TrimSpace(value[len("bearer "):])
+		}
+		if token == "" {
+			token = value
+		}
+	}
+	server.AuthorizationToken = token
+
+	allowed, err := parseMCPAllowedTools(label, t.AllowedTools)
+	if err != nil {
+		return AnthropicMCPServer{}, err
+	}
+	if len(allowed) > 0 {
+		server.ToolConfiguration = &AnthropicMCPToolConfiguration{AllowedTools: allowed}
+	}
+	if mcpRequiresApproval(t.RequireApproval, allowed) {
+		return AnthropicMCPServer{}, fmt.Errorf(`mcp tool %q: tool call approval is not supported by Anthropic upstreams, set require_approval to "never"`, label)
+	}
+	return server, nil
+}
+
+// parseMCPAllowedTools accepts a list of tool names or a
+// {"tool_names":[...],"read_only":bool} filter.
+func parseMCPAllowedTools(label string, raw json.RawMessage) ([]string, error) {
+	if len(raw) == 0 || string(raw) == "null" {
+		return nil, nil
+	}
+	var names []string
+	if err := json.Unmarshal(raw, &names); err == nil {
+		return names, nil
+	}
+	var filter struct {
+		ToolNames []string `json:"tool_names"`
+		ReadOnly  *bool    `json:"read_only"`
+	}
+	if err := json.Unmarshal(raw, &filter); err != nil {
+		return nil, fmt.Errorf("mcp tool %q: invalid allowed_tools", label)
+	}
+	if filter.ReadOnly != nil && *filter.ReadOnly {
+		return nil, fmt.Errorf("mcp tool %q: read_only tool filters are not supported by Anthropic upstreams, list tool_names instead", label)
+	}
+	return filter.ToolNames, nil
+}
+
+// mcpRequiresApproval reports whether any callable tool needs approval. The
+// Responses API requires approval unless require_approval says otherwise, so
+// an omitted setting counts as "always"; with the object form, tools not
+// listed under never still need approval unless allowed_tools limits the
+// server to listed ones.
+func mcpRequiresApproval(raw json.RawMessage, allowed []string) bool {
+	if len(raw) == 0 || string(raw) == "null" {
+		return true
+	}
+	var mode string
+	if err := json.Unmarshal(raw, &mode); err == nil {
+		return mode != "never"
+	}
+	var filter struct {
+		Always *struct {
+			ToolNames []string `json:"tool_names"`
+		} `json:"always"`
+		Never *struct {
+			ToolNames []string `json:"tool_names"`
+		} `json:"never"`
+	}
+	if err := json.Unmarshal(raw, &filter); err != nil {
+		return true
+	}
+	if filter.Always != nil && len(filter.Always.ToolNames) > 0 {
+		return true
+	}
+	if filter.Never == nil || len(allowed) == 0 {
+		return true
+	}
+	never := make(map[string]struct{}, len(filter.Never.ToolNames))
+	for _, name := range filter.Never.ToolNames {
+		never[name] = struct{}{}
+	}
+	for _, name := range allowed {
+		if _, ok := never[name]; !ok {
+			return true
+		}
+	}
+	return false
+}
+
+// mcpCallToAnthropicContent replays an mcp_call input item. Calls made by
+// the Anthropic MCP connector are sent back as mcp_tool_use /
+// mcp_tool_result blocks; calls from other upstreams become a text summary
+// because their ids cannot be replayed there.
+func mcpCallToAnthropicContent(item ResponsesInputItem) json.RawMessage {
+	if strings.HasPrefix(item.ID, anthropicMCPToolUseIDPrefix) {
+		input := json.RawMessage("{}")
+		if item.Arguments != "" && json.Valid([]byte(item.Arguments)) {
+			input = json.RawMessage(item.Arguments)
+		}
+		result := item.Output
+		if item.Error != "" {
+			result = item.Error
+		}
+		resultContent, _ := json.Marshal([]AnthropicContentBlock{{Type: "text", Text: result}})
+		blocks, _ := json.Marshal([]AnthropicContentBlock{
+			{Type: "mcp_tool_use", ID: item.ID, Name: item.Name, ServerName: item.ServerLabel, Input: input},
+			{Type: "mcp_tool_result", ToolUseID: item.ID, IsError: item.Error != "", Content: resultContent},
+		})
+		return blocks
+	}
+
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "[MCP tool call] %s.%s(%s)", item.ServerLabel, item.Name, item.Arguments)
+	if item.Error != "" {
+		fmt.Fprintf(&sb, "\nError: %s", item.Error)
+	} else {
+		fmt.Fprintf(&sb, "\nResult: %s", item.Output)
+	}
+	blocks, _ := json.Marshal([]AnthropicContentBlock{{Type: "text", Text: sb.String()}})
+	return blocks
+}
+
+// anthropicMCPToolResultOutput returns the mcp_call output and error of an
+// mcp_tool_result block.
+func anthropicMCPToolResultOutput(b AnthropicContentBlock) (output, errMsg string) {
+	text, _ := convertToolResultOutput(b)
+	if b.IsError {
+		return "", text
+	}
+	return text, ""
+}
@@ -0,0 +1,213 @@
+package apicompat
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestResponsesToAnthropicRequest_MCPTools(t *testing.T) {
+	req := &ResponsesRequest{
+		Model: "claude-sonnet-4-5",
+		Input: json.RawMessage(`"Search the docs"`),
+		Tools: []ResponsesTool{
+			{
+				Type:            "mcp",
+				ServerLabel:     "docs",
+				ServerURL:       "https://mcp.example.com/sse",
+				Headers:         map[string]string{"Authorization": "Bearer secret"},
+				AllowedTools:    json.RawMessage(`["search","fetch"]`),
+				RequireApproval: json.RawMessage(`"never"`),
+			},
+			{
+				Type:            "mcp",
+				ServerLabel:     "wiki",
+				ServerURL:       "https://wiki.example.com/mcp",
+				Authorization:   "token-2",
+				AllowedTools:    json.RawMessage(`{"tool_names":["lookup"]}`),
+				RequireApproval: json.RawMessage(`{"never":{"tool_names":["lookup"]}}`),
+			},
+		},
+		ToolChoice: json.RawMessage(`"auto"`),
+	}
+
+	out, err := ResponsesToAnthropicRequest(req)
+	require.NoError(t, err)
+	assert.Empty(t, out.Tools)
+	assert.Empty(t, out.ToolChoice)
+	require.Len(t, out.MCPServers, 2)
+	assert.Equal(t, AnthropicMCPServer{
+		Type:               "url",
+		URL:                "https://mcp.example.com/sse",
+		Name:               "docs",
+		AuthorizationToken: "secret",
+		ToolConfiguration:  &AnthropicMCPToolConfiguration{AllowedTools: []string{"search", "fetch"}},
+	}, out.MCPServers[0])
+	assert.Equal(t, "token-2", out.MCPServers[1].AuthorizationToken)
+	assert.Equal(t, []string{"lookup"}, out.MCPServers[1].ToolConfiguration.AllowedTools)
+
+	// Function tools alongside MCP servers keep their tool_choice.
+	req.Tools = append(req.Tools, ResponsesTool{Type: "function", Name: "run", Parameters: json.RawMessage(`{"type":"object"}`)})
+	out, err = ResponsesToAnthropicRequest(req)
+	require.NoError(t, err)
+	require.Len(t, out.Tools, 1)
+	assert.Equal(t, "run", out.Tools[0].Name)
+	assert.NotEmpty(t, out.ToolChoice)
+}
+
+func TestResponsesToAnthropicRequest_MCPToolErrors(t *testing.T) {
+	never := json.RawMessage(`"never"`)
+	cases := []struct {
+		name string
+		tool ResponsesTool
+		want string
+	}{
+		{name: "missing label", tool: ResponsesTool{Type: "mcp", ServerURL: "https://x", RequireApproval: never}, want: "server_label is required"},
+		{name: "connector", tool: ResponsesTool{Type: "mcp", ServerLabel: "gmail", ConnectorID: "connector_gmail", RequireApproval: never}, want: "connector_id is not supported"},
+		{name: "missing url", tool: ResponsesTool{Type: "mcp", ServerLabel: "docs", RequireApproval: never}, want: "server_url is required"},
+		{name: "approval default", tool: ResponsesTool{Type: "mcp", ServerLabel: "docs", ServerURL: "https://x"}, want: "approval is not supported"},
+		{name: "approval always", tool: ResponsesTool{Type: "mcp", ServerLabel: "docs", ServerURL: "https://x", RequireApproval: json.RawMessage(`"always"`)}, want: "approval is not supported"},
+		{
+			name: "approval partial",
+			tool: ResponsesTool{Type: "mcp", ServerLabel: "docs", ServerURL: "https://x", AllowedTools: json.RawMessage(`["a","b"]`), RequireApproval: json.RawMessage(`{"never":{"tool_names":["a"]}}`)},
+			want: "approval is not supported",
+		},
+		{name: "custom header", tool: ResponsesTool{Type: "mcp", ServerLabel: "docs", ServerURL: "https://x", Headers: map[string]string{"X-Api-Key": "k"}, RequireApproval: never}, want: `got "X-Api-Key"`},
+		{name: "read only", tool: ResponsesTool{Type: "mcp", ServerLabel: "docs", ServerURL: "https://x", AllowedTools: json.RawMessage(`{"read_only":true}`), RequireApproval: never}, want: "read_only"},
+	}
+	for _, tt := range cases {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ResponsesToAnthropicRequest(&ResponsesRequest{
+				Model: "claude-sonnet-4-5",
+				Input: json.RawMessage(`"hi"`),
+				Tools: []ResponsesTool{tt.tool},
+			})
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.want)
+		})
+	}
+
+	dup := ResponsesTool{Type: "mcp", ServerLabel: "docs", ServerURL: "https://x", RequireApproval: never}
+	_, err := ResponsesToAnthropicRequest(&ResponsesRequest{Model: "m", Input: json.RawMessage(`"hi"`), Tools: []ResponsesTool{dup, dup}})
+	require.ErrorContains(t, err, "duplicate server_label")
+}
+
+func TestResponsesToAnthropicRequest_MCPInputItems(t *testing.T) {
+	input := `[
+		{"role":"user","content":"Find the install guide"},
+		{"type":"mcp_list_tools","id":"mcpl_1","server_label":"docs","tools":[]},
+		{"type":"mcp_call","id":"mcptoolu_01","server_label":"docs","name":"search","arguments":"{\"q\":\"install\"}","output":"guide.md"},
+		{"type":"mcp_call","id":"mcp_abc","server_label":"docs","name":"fetch","arguments":"{}","error":"timeout"},
+		{"role":"user","content":"Thanks"}
+	]`
+	out, err := ResponsesToAnthropicRequest(&ResponsesRequest{Model: "m", Input: json.RawMessage(input)})
+	require.NoError(t, err)
+	require.Len(t, out.Messages, 3)
+	assert.Equal(t, "assistant", out.Messages[1].Role)
+
+	var blocks []AnthropicContentBlock
+	require.NoError(t, json.Unmarshal(out.Messages[1].Content, &blocks))
+	require.Len(t, blocks, 3)
+	assert.Equal(t, "mcp_tool_use", blocks[0].Type)
+	assert.Equal(t, "mcptoolu_01", blocks[0].ID)
+	assert.Equal(t, "docs", blocks[0].ServerName)
+	assert.JSONEq(t, `{"q":"install"}`, string(blocks[0].Input))
+	assert.Equal(t, "mcp_tool_result", blocks[1].Type)
+	assert.Equal(t, "mcptoolu_01", blocks[1].ToolUseID)
+	assert.JSONEq(t, `[{"type":"text","text":"guide.md"}]`, string(blocks[1].Content))
+	// Calls made by other upstreams cannot be replayed and become text.
+	assert.Equal(t, "text", blocks[2].Type)
+	assert.Contains(t, blocks[2].Text, "docs.fetch")
+	assert.Contains(t, blocks[2].Text, "Error: timeout")
+
+	_, err = ResponsesToAnthropicRequest(&ResponsesRequest{
+		Model: "m",
+		Input: json.RawMessage(`[{"type":"mcp_approval_response","approval_request_id":"mcpr_1","approve":true}]`),
+	})
+	require.ErrorContains(t, err, "approval is not supported")
+}
+
+func TestAnthropicToResponsesResponse_MCPCall(t *testing.T) {
+	resp := &AnthropicResponse{
+		ID:    "msg_1",
+		Model: "claude-sonnet-4-5",
+		Content: []AnthropicContentBlock{
+			{Type: "mcp_tool_use", ID: "mcptoolu_01", Name: "search", ServerName: "docs", Input: json.RawMessage(`{"q":"install"}`)},
+			{Type: "mcp_tool_result", ToolUseID: "mcptoolu_01", Content: json.RawMessage(`[{"type":"text","text":"guide.md"}]`)},
+			{Type: "mcp_tool_use", ID: "mcptoolu_02", Name: "fetch", ServerName: "docs", Input: json.RawMessage(`{}`)},
+			{Type: "mcp_tool_result", ToolUseID: "mcptoolu_02", IsError: true, Content: json.RawMessage(`[{"type":"text","text":"not found"}]`)},
+			{Type: "text", Text: "See guide.md"},
+		},
+		StopReason: "end_turn",
+	}
+
+	out := AnthropicToResponsesResponse(resp)
+	require.Len(t, out.Output, 3)
+	call := out.Output[0]
+	assert.Equal(t, "mcp_call", call.Type)
+	assert.Equal(t, "mcptoolu_01", call.ID)
+	assert.Equal(t, "docs", call.ServerLabel)
+	assert.Equal(t, "search", call.Name)
+	assert.JSONEq(t, `{"q":"install"}`, call.Arguments)
+	assert.Equal(t, "guide.md", call.Output)
+	assert.Equal(t, "completed", call.Status)
+
+	failed := out.Output[1]
+	assert.Equal(t, "not found", failed.Error)
+	assert.Empty(t, failed.Output)
+	assert.Equal(t, "failed", failed.Status)
+	assert.Equal(t, "message", out.Output[2].Type)
+}
+
+func TestStreamingMCPCallToResponses(t *testing.T) {
+	state := NewAnthropicEventToResponsesState()
+	idx := func(i int) *int { return &i }
+	events := []AnthropicStreamEvent{
+		{Type: "message_start", Message: &AnthropicResponse{ID: "msg_1", Model: "claude-sonnet-4-5"}},
+		{Type: "content_block_start", Index: idx(0), ContentBlock: &AnthropicContentBlock{Type: "mcp_tool_use", ID: "mcptoolu_01", Name: "search", ServerName: "docs", Input: json.RawMessage(`{}`)}},
+		{Type: "content_block_delta", Index: idx(0), Delta: &AnthropicDelta{Type: "input_json_delta", PartialJSON: `{"q":"install"}`}},
+		{Type: "content_block_stop", Index: idx(0)},
+		{Type: "content_block_start", Index: idx(1), ContentBlock: &AnthropicContentBlock{Type: "mcp_tool_result", ToolUseID: "mcptoolu_01", Content: json.RawMessage(`[{"type":"text","text":"guide.md"}]`)}},
+		{Type: "content_block_stop", Index: idx(1)},
+		{Type: "content_block_start", Index: idx(2), ContentBlock: &AnthropicContentBlock{Type: "text"}},
+		{Type: "content_block_delta", Index: idx(2), Delta: &AnthropicDelta{Type: "text_delta", Text: "Done"}},
+		{Type: "content_block_stop", Index: idx(2)},
+		{Type: "message_stop"},
+	}
+
+	var got []ResponsesStreamEvent
+	for i := range events {
+		got = append(got, AnthropicEventToResponsesEvents(&events[i], state)...)
+	}
+
+	var types []string
+	for _, e := range got {
+		types = append(types, e.Type)
+	}
+	assert.Equal(t, []string{
+		"response.created",
+		"response.output_item.added",
+		"response.mcp_call.in_progress",
+		"response.mcp_call_arguments.delta",
+		"response.mcp_call_arguments.done",
+		"response.mcp_call.completed",
+		"response.output_item.done",
+		"response.output_item.added",
+		"response.output_text.delta",
+		"response.output_text.done",
+		"response.output_item.done",
+		"response.completed",
+	}, types)
+
+	done := got[6].Item
+	require.NotNil(t, done)
+	assert.Equal(t, "mcp_call", done.Type)
+	assert.Equal(t, "mcptoolu_01", done.ID)
+	assert.Equal(t, "docs", done.ServerLabel)
+	assert.Equal(t, `{"q":"install"}`, done.Arguments)
+	assert.Equal(t, "guide.md", done.Output)
+	assert.Equal(t, 0, got[6].OutputIndex)
+	assert.Equal(t, 1, got[7].OutputIndex)
+}
@@ -0,0 +1,173 @@
+package apicompat
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// ---------------------------------------------------------------------------
+// OllamaChatToResponses / OllamaGenerateToResponses tests
+// ---------------------------------------------------------------------------
+
+// 1x1 PNG
+const ollamaTestPNG = "iVBORw0KGgoAAAANSUhEUgAAAAEAAAABCAYAAAAfFcSJAAAADUlEQVR42mNkYPhfDwAChwGA60e6kgAAAABJRU5ErkJggg=="
+
+func TestOllamaChatToResponses_Messages(t *testing.T) {
+	numPredict := 10
+	req := &OllamaChatRequest{
+		Model: "gpt-5.1",
+		Messages: []OllamaMessage{
+			{Role: "system", Content: "Be brief."},
+			{Role: "user", Content: "Weather?", Images: []string{ollamaTestPNG, "bm90IGFuIGltYWdl"}},
+			{Role: "assistant", ToolCalls: []OllamaToolCall{
+				{Function: OllamaToolCallFunction{Name: "get_weather", Arguments: json.RawMessage(`{"city":"Paris"}`)}},
+				{Function: OllamaToolCallFunction{Name: "get_time", Arguments: json.RawMessage(`{}`)}},
+			}},
+			{Role: "tool", ToolName: "get_time", Content: "noon"},
+			{Role: "tool", Content: "sunny"},
+			{Role: "tool", ToolName: "get_weather", Content: "late"},
+		},
+		Tools:   []OllamaTool{{Type: "function", Function: OllamaToolFunction{Name: "get_weather"}}},
+		Options: &OllamaOptions{NumPredict: &numPredict},
+		Think:   json.RawMessage(`"high"`),
+	}
+
+	resp, err := OllamaChatToResponses(req, "gpt-5.2")
+	require.NoError(t, err)
+	assert.Equal(t, "gpt-5.2", resp.Model)
+	assert.Equal(t, minMaxOutputTokens, *resp.MaxOutputTokens)
+	require.NotNil(t, resp.Reasoning)
+	assert.Equal(t, "high", resp.Reasoning.Effort)
+	assert.Equal(t, "auto", resp.Reasoning.Summary)
+	require.Len(t, resp.Tools, 1)
+	assert.JSONEq(t, `{"type":"object","properties":{}}`, string(resp.Tools[0].Parameters))
+
+	var items []ResponsesInputItem
+	require.NoError(t, json.Unmarshal(resp.Input, &items))
+	require.Len(t, items, 7)
+	assert.Equal(t, "system", items[0].Role)
+
+	var userParts []ResponsesContentPart
+	require.NoError(t, json.Unmarshal(items[1].Content, &userParts))
+	require.Len(t, userParts, 2, "non-image payload must be dropped")
+	assert.True(t, strings.HasPrefix(userParts[1].ImageURL, "data:image/png;base64,"))
+
+	assert.Equal(t, "function_call", items[2].Type)
+	assert.Equal(t, "fc_ollama_1", items[2].CallID)
+	assert.Equal(t, "fc_ollama_2", items[3].CallID)
+
+	// Named result pairs by name; unnamed result takes the oldest pending call.
+	assert.Equal(t, "function_call_output", items[4].Type)
+	assert.Equal(t, "fc_ollama_2", items[4].CallID)
+	assert.Equal(t, "fc_ollama_1", items[5].CallID)
+	assert.Equal(t, "sunny", items[5].Output)
+
+	// No call left to answer → degraded to user text.
+	assert.Equal(t, "user", items[6].Role)
+	assert.Contains(t, string(items[6].Content), "Function get_weather returned: late")
+}
+
+func TestOllamaChatToResponses_Errors(t *testing.T) {
+	_, err := OllamaChatToResponses(&OllamaChatRequest{Messages: []OllamaMessage{{Role: "function"}}}, "m")
+	require.Error(t, err)
+
+	_, err = OllamaChatToResponses(&OllamaChatRequest{Format: json.RawMessage(`"yaml"`)}, "m")
+	require.Error(t, err)
+}
+
+func TestOllamaGenerateToResponses(t *testing.T) {
+	req := &OllamaGenerateRequest{
+		Prompt: "Say hi",
+		System: "Be brief.",
+		Format: json.RawMessage(`{"type":"object","properties":{"greeting":{"type":"string"}}}`),
+		Think:  json.RawMessage(`false`),
+	}
+
+	resp, err := OllamaGenerateToResponses(req, "gpt-5.2")
+	require.NoError(t, err)
+	assert.Equal(t, "medium", resp.Reasoning.Effort)
+	assert.Empty(t, resp.Reasoning.Summary)
+	assert.Contains(t, string(resp.Text), `"type":"json_schema"`)
+	assert.Contains(t, string(resp.Text), `"greeting"`)
+
+	var items []ResponsesInputItem
+	require.NoError(t, json.Unmarshal(resp.Input, &items))
+	require.Len(t, items, 2)
+	assert.Equal(t, "system", items[0].Role)
+	assert.Equal(t, "user", items[1].Role)
+
+	jsonMode, err := OllamaGenerateToResponses(&OllamaGenerateRequest{Prompt: "x", Format: json.RawMessage(`"json"`)}, "m")
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"format":{"type":"json_object"}}`, string(jsonMode.Text))
+}
+
+// ---------------------------------------------------------------------------
+// ResponsesToOllamaChat / streaming tests
+// ---------------------------------------------------------------------------
+
+func TestResponsesToOllamaChat(t *testing.T) {
+	resp := &ResponsesResponse{
+		Status: "incomplete",
+		Output: []ResponsesOutput{
+			{Type: "reasoning", Summary: []ResponsesSummary{{Type: "summary_text", Text: "hmm"}}},
+			{Type: "message", Content: []ResponsesContentPart{{Type: "output_text", Text: "Hel"}}},
+			{Type: "function_call", Name: "f", Arguments: "not json"},
+		},
+		IncompleteDetails: &ResponsesIncompleteDetails{Reason: "max_output_tokens"},
+		Usage:             &ResponsesUsage{InputTokens: 5, OutputTokens: 2},
+	}
+
+	out := ResponsesToOllamaChat(resp, "gpt-5.1", false)
+	assert.True(t, out.Done)
+	assert.Equal(t, "length", out.DoneReason)
+	assert.Equal(t, "Hel", out.Message.Content)
+	assert.Empty(t, out.Message.Thinking)
+	require.Len(t, out.Message.ToolCalls, 1)
+	assert.JSONEq(t, `{}`, string(out.Message.ToolCalls[0].Function.Arguments))
+	assert.Equal(t, 5, out.PromptEvalCount)
+	assert.Equal(t, 2, out.EvalCount)
+
+	gen := OllamaChatToGenerate(ResponsesToOllamaChat(resp, "gpt-5.1", true))
+	assert.Equal(t, "Hel", gen.Response)
+	assert.Equal(t, "hmm", gen.Thinking)
+}
+
+func TestResponsesEventToOllamaChatChunks(t *testing.T) {
+	state := NewResponsesEventToOllamaState()
+	state.Model = "gpt-5.1"
+
+	events := []ResponsesStreamEvent{
+		{Type: "response.created", Response: &ResponsesResponse{ID: "resp_1"}},
+		{Type: "response.reasoning_summary_text.delta", Delta: "hidden"},
+		{Type: "response.output_text.delta", Delta: "Hi"},
+		{Type: "response.output_item.done", Item: &ResponsesOutput{Type: "function_call", Name: "f", Arguments: `{"a":1}`}},
+		{Type: "response.completed", Response: &ResponsesResponse{Status: "completed", Usage: &ResponsesUsage{InputTokens: 3, OutputTokens: 4}}},
+	}
+	var chunks []OllamaChatResponse
+	for i := range events {
+		chunks = append(chunks, ResponsesEventToOllamaChatChunks(&events[i], state)...)
+	}
+
+	require.Len(t, chunks, 3)
+	assert.Equal(t, "Hi", chunks[0].Message.Content)
+	assert.False(t, chunks[0].Done)
+	require.Len(t, chunks[1].Message.ToolCalls, 1)
+	assert.True(t, chunks[2].Done)
+	assert.Equal(t, "stop", chunks[2].DoneReason)
+	assert.Equal(t, 4, chunks[2].EvalCount)
+	assert.Nil(t, FinalizeResponsesOllamaStream(state))
+
+	line, err := OllamaChunkToNDJSON(chunks[0])
+	require.NoError(t, err)
+	assert.True(t, strings.HasSuffix(line, "}\n"))
+	assert.NotContains(t, line, "eval_count")
+
+	unfinished := NewResponsesEventToOllamaState()
+	final := FinalizeResponsesOllamaStream(unfinished)
+	require.Len(t, final, 1)
+	assert.True(t, final[0].Done)
+}
@@ -0,0 +1,297 @@
+package apicompat
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// OllamaChatToResponses converts an Ollama /api/chat request into a Responses
+// API request. The caller supplies the (already mapped) upstream model.
+func OllamaChatToResponses(req *OllamaChatRequest, model string) (*ResponsesRequest, error) {
+	input, err := convertOllamaMessagesToResponsesInput(req.Messages)
+	if err != nil {
+		return nil, err
+	}
+	out, err := newOllamaResponsesRequest(model, input, req.Options, req.Format, req.Think)
+	if err != nil {
+		return nil, err
+	}
+	for _, t := range req.Tools {
+		if t.Type != "" && t.Type != "function" {
+			continue
+		}
+		out.Tools = append(out.Tools, ResponsesTool{
+			Type:        "function",
+			Name:        t.Function.Name,
+			Description: t.Function.Description,
+			Parameters:  normalizeToolParameters(t.Function.Parameters),
+		})
+	}
+	return out, nil
+}
+
+// OllamaGenerateToResponses converts an Ollama /api/generate request into a
+// Responses API request: system → system message, prompt + images → user
+// message.
+func OllamaGenerateToResponses(req *OllamaGenerateRequest, model string) (*ResponsesRequest, error) {
+	messages := make([]OllamaMessage, 0, 2)
+	if req.System != "" {
+		messages = append(messages, OllamaMessage{Role: "system", Content: req.System})
+	}
+	messages = append(messages, OllamaMessage{Role: "user", Content: req.Prompt, Images: req.Images})
+
+	input, err := convertOllamaMessagesToResponsesInput(messages)
+	if err != nil {
+		return nil, err
+	}
+	return newOllamaResponsesRequest(model, input, req.Options, req.Format, req.Think)
+}
+
+// OllamaThinkEnabled reports whether the client asked for thinking output
+// (think: true or a level string).
+func OllamaThinkEnabled(think json.RawMessage) bool {
+	enabled, _ := parseOllamaThink(think)
+	return enabled
+}
+
+func newOllamaResponsesRequest(model string, input []ResponsesInputItem, opts *OllamaOptions, format, think json.RawMessage) (*ResponsesRequest, error) {
+	inputJSON, err := json.Marshal(input)
+	if err != nil {
+		return nil, err
+	}
+
+	out := &ResponsesRequest{
+		Model:   model,
+		Input:   inputJSON,
+		Include: []string{"reasoning.encrypted_content"},
+	}
+	storeFalse := false
+	out.Store = &storeFalse
+
+	if opts != nil {
+		out.Temperature = opts.Temperature
+		out.TopP = opts.TopP
+		// num_predict -1 (infinite) / -2 (fill context) → no limit
+		if opts.NumPredict != nil && *opts.NumPredict > 0 {
+			v := *opts.NumPredict
+			if v < minMaxOutputTokens {
+				v = minMaxOutputTokens
+			}
+			out.MaxOutputTokens = &v
+		}
+	}
+
+	thinkEnabled, effort := parseOllamaThink(think)
+	if effort == "" {
+		effort = "medium"
+	}
+	out.Reasoning = &ResponsesReasoning{Effort: effort}
+	if thinkEnabled {
+		out.Reasoning.Summary = "auto"
+	}
+
+	text, err := convertOllamaFormatToResponses(format)
+	if err != nil {
+		return nil, err
+	}
+	out.Text = text
+
+	return out, nil
+}
+
+// parseOllamaThink decodes the think field. A level string ("low" | "medium"
+// | "high") both enables thinking and selects the effort; true enables it
+// with the default effort.
+func parseOllamaThink(think json.RawMessage) (enabled bool, effort string) {
+	if len(think) == 0 || string(think) == "null" {
+		return false, ""
+	}
+	var b bool
+	if err := json.Unmarshal(think, &b); err == nil {
+		return b, ""
+	}
+	var level string
+	if err := json.Unmarshal(think, &level); err == nil {
+		level = strings.ToLower(strings.TrimSpace(level))
+		switch level {
+		case "low", "medium", "high":
+			return true, level
+		}
+	}
+	return false, ""
+}
+
+// convertOllamaFormatToResponses maps format: "json" to json_object and a
+// schema object to a non-strict json_schema text format.
+func convertOllamaFormatToResponses(format json.RawMessage) (json.RawMessage, error) {
+	if len(format) == 0 || string(format) == "null" || string(format) == `""` {
+		return nil, nil
+	}
+	var s string
+	if err := json.Unmarshal(format, &s); err == nil {
+		if s != "json" {
+			return nil, fmt.Errorf("unsupported format: %q", s)
+		}
+		return json.RawMessage(`{"format":{"type":"json_object"}}`), nil
+	}
+	var schema map[string]json.RawMessage
+	if err := json.Unmarshal(format, &schema); err != nil {
+		return nil, fmt.Errorf("format must be \"json\" or a JSON schema object")
+	}
+	return json.Marshal(map[string]any{
+		"format": map[string]any{
+			"type":   "json_schema",
+			"name":   "response",
+			"schema": json.RawMessage(format),
+			"strict": false,
+		},
+	})
+}
+
+// convertOllamaMessagesToResponsesInput builds the Responses input array.
+//
+// Ollama tool calls carry no id, so each call gets a synthetic id and tool
+// results are paired with the oldest unanswered call of the same tool_name
+// (or the oldest unanswered call when tool_name is missing).
+func convertOllamaMessagesToResponsesInput(messages []OllamaMessage) ([]ResponsesInputItem, error) {
+	var out []ResponsesInputItem
+	calls := &ollamaCallTracker{}
+
+	for _, m := range messages {
+		switch m.Role {
+		case "system":
+			if m.Content == "" {
+				continue
+			}
+			content, _ := json.Marshal(m.Content)
+			out = append(out, ResponsesInputItem{Role: "system", Content: content})
+		case "user":
+			parts := []ResponsesContentPart{}
+			if m.Content != "" {
+				parts = append(parts, ResponsesContentPart{Type: "input_text", Text: m.Content})
+			}
+			for _, img := range m.Images {
+				if uri := ollamaImageToDataURI(img); uri != "" {
+					parts = append(parts, ResponsesContentPart{Type: "input_image", ImageURL: uri})
+				}
+			}
+			if len(parts) == 0 {
+				continue
+			}
+			content, err := json.Marshal(parts)
+			if err != nil {
+				return nil, err
+			}
+			out = append(out, ResponsesInputItem{Role: "user", Content: content})
+		case "assistant":
+			if m.Content != "" {
+				content, err := json.Marshal([]ResponsesContentPart{{Type: "output_text", Text: m.Content}})
+				if err != nil {
+					return nil, err
+				}
+				out = append(out, ResponsesInputItem{Role: "assistant", Content: content})
+			}
+			for _, tc := range m.ToolCalls {
+				args := "{}"
+				if len(tc.Function.Arguments) > 0 && string(tc.Function.Arguments) != "null" {
+					args = string(tc.Function.Arguments)
+				}
+				fcID := calls.issue(tc.Function.Name)
+				out = append(out, ResponsesInputItem{
+					Type:      "function_call",
+					CallID:    fcID,
+					Name:      tc.Function.Name,
+					Arguments: args,
+					ID:        fcID,
+				})
+			}
+		case "tool":
+			output := m.Content
+			if output == "" {
+				output = "(empty)"
+			}
+			callID := calls.resolve(m.ToolName)
+			if callID == "" {
+				// Orphan result: the Responses API rejects function_call_output
+				// without a matching call, so keep it as plain text.
+				content, err := json.Marshal([]ResponsesContentPart{{
+					Type: "input_text",
+					Text: fmt.Sprintf("Function %s returned: %s", m.ToolName, output),
+				}})
+				if err != nil {
+					return nil, err
+				}
+				out = append(out, ResponsesInputItem{Role: "user", Content: content})
+				continue
+			}
+			out = append(out, ResponsesInputItem{
+				Type:   "function_call_output",
+				CallID: callID,
+				Output: output,
+			})
+		default:
+			return nil, fmt.Errorf("unsupported message role: %q", m.Role)
+		}
+	}
+	return out, nil
+}
+
+// ollamaCallTracker issues synthetic call ids and pairs tool results with
+// unanswered calls in issue order.
+type ollamaCallTracker struct {
+	pending []ollamaPendingCall
+	seq     int
+}
+
+type ollamaPendingCall struct {
+	name string
+	id   string
+}
+
+func (t *ollamaCallTracker) issue(name string) string {
+	t.seq++
+	id := fmt.Sprintf("fc_ollama_%d", t.seq)
+	t.pending = append(t.pending, ollamaPendingCall{name: name, id: id})
+	return id
+}
+
+// resolve returns the call id a tool result answers, or "" when no
+// unanswered call matches.
+func (t *ollamaCallTracker) resolve(name string) string {
+	for i, p := range t.pending {
+		if name == "" || p.name == name {
+			t.pending = append(t.pending[:i:i], t.pending[i+1:]...)
+			return p.id
+		}
+	}
+	return ""
+}
+
+// ollamaImageToDataURI converts an Ollama base64 image (no data URI prefix)
+// to a data URI, sniffing the MIME type from the decoded header bytes.
+// Non-image payloads return "".
+func ollamaImageToDataURI(data string) string {
+	data = strings.TrimSpace(data)
+	if data == "" {
+		return ""
+	}
+	if strings.HasPrefix(data, "data:") {
+		return data
+	}
+	head := data
+	if len(head) > 64 {
+		head = head[:64]
+	}
+	decoded, err := base64.StdEncoding.DecodeString(head[:len(head)/4*4])
+	if err != nil {
+		return ""
+	}
+	mimeType := http.DetectContentType(decoded)
+	if !strings.HasPrefix(mimeType, "image/") {
+		return ""
+	}
+	return "data:" + mimeType + ";base64," + data
+}
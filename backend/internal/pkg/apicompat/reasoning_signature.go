@@ -0,0 +1,57 @@
+package apicompat
+
+import "strings"
+
+// Reasoning state that crosses providers is carried in the other side's opaque
+// field: OpenAI encrypted reasoning content rides in an Anthropic thinking
+// block's signature, and an Anthropic thinking signature rides in a Responses
+// reasoning item's encrypted_content. Clients echo both back verbatim, so the
+// prefixes let a later turn restore the state for the provider that produced
+// it and drop it for the other one, which would reject the foreign blob.
+const (
+	openAIReasoningSignaturePrefix   = "oai-reasoning:"
+	anthropicThinkingEncryptedPrefix = "anthropic-thinking:"
+)
+
+// encodeOpenAIReasoningSignature wraps OpenAI encrypted reasoning content as an
+// Anthropic thinking signature.
+func encodeOpenAIReasoningSignature(encrypted string) string {
+	if encrypted == "" {
+		return ""
+	}
+	return openAIReasoningSignaturePrefix + encrypted
+}
+
+// decodeOpenAIReasoningSignature extracts OpenAI encrypted reasoning content
+// from a thinking signature produced by encodeOpenAIReasoningSignature.
+func decodeOpenAIReasoningSignature(signature string) (string, bool) {
+	encrypted, ok := strings.CutPrefix(signature, openAIReasoningSignaturePrefix)
+	return encrypted, ok && encrypted != ""
+}
+
+// encodeAnthropicThinkingEncrypted wraps an Anthropic thinking signature as
+// Responses reasoning encrypted_content.
+func encodeAnthropicThinkingEncrypted(signature string) string {
+	if signature == "" {
+		return ""
+	}
+	return anthropicThinkingEncryptedPrefix + signature
+}
+
+// decodeAnthropicThinkingEncrypted extracts an Anthropic thinking signature
+// from encrypted_content produced by encodeAnthropicThinkingEncrypted.
+func decodeAnthropicThinkingEncrypted(encrypted string) (string, bool) {
+	signature, ok := strings.CutPrefix(encrypted, anthropicThinkingEncryptedPrefix)
+	return signature, ok && signature != ""
+}
+
+// reasoningSummaryText joins the summary_text parts of a reasoning item.
+func reasoningSummaryText(summary []ResponsesSummary) string {
+	var sb strings.Builder
+	for _, s := range summary {
+		if s.Type == "summary_text" {
+			sb.WriteString(s.Text)
+		}
+	}
+	return sb.String()
+}
@@ -2,7 +2,6 @@ package apicompat
 
 import (
 	"encoding/json"
-	"fmt"
 	"time"
 )
 
@@ -12,8 +11,9 @@ import (
 
 // ResponsesToAnthropic converts a Responses API response directly into an
 // Anthropic Messages response. Reasoning output items are mapped to thinking
-// blocks; function_call items become tool_use blocks.
-func ResponsesToAnthropic(resp *ResponsesResponse, model string) *AnthropicResponse {
+// blocks (dropped when includeThinking is false); function_call items become
+// tool_use blocks.
+func ResponsesToAnthropic(resp *ResponsesResponse, model string, includeThinking bool) *AnthropicResponse {
 	out := &AnthropicResponse{
 		ID:    resp.ID,
 		Type:  "message",
@@ -26,16 +26,17 @@ func ResponsesToAnthropic(resp *ResponsesResponse, model string) *AnthropicRespo
 	for _, item := range resp.Output {
 		switch item.Type {
 		case "reasoning":
-			summaryText := ""
-			for _, s := range item.Summary {
-				if s.Type == "summary_text" && s.Text != "" {
-					summaryText += s.Text
-				}
+			if !includeThinking {
+				continue
 			}
-			if summaryText != "" {
+			// The encrypted reasoning rides in the signature so the client
+			// echoes it back and the next turn can restore it upstream.
+			summaryText := reasoningSummaryText(item.Summary)
+			if summaryText != "" || item.EncryptedContent != "" {
 				blocks = append(blocks, AnthropicContentBlock{
-					Type:     "thinking",
-					Thinking: summaryText,
+					Type:      "thinking",
+					Thinking:  summaryText,
+					Signature: encodeOpenAIReasoningSignature(item.EncryptedContent),
 				})
 			}
 		case "message":
@@ -137,6 +138,9 @@ type ResponsesEventToAnthropicState struct {
 	ResponseID string
 	Model      string
 	Created    int64
+
+	// IncludeThinking streams reasoning as thinking blocks (default true).
+	IncludeThinking bool
 }
 
 // NewResponsesEventToAnthropicState returns an initialised stream state.
@@ -144,6 +148,7 @@ func NewResponsesEventToAnthropicState() *ResponsesEventToAnthropicState {
 	return &ResponsesEventToAnthropicState{
 		OutputIndexToBlockIdx: make(map[int]int),
 		Created:               time.Now().Unix(),
+		IncludeThinking:       true,
 	}
 }
 
@@ -168,10 +173,12 @@ func ResponsesEventToAnthropicEvents(
 		return resToAnthHandleBlockDone(state)
 	case "response.output_item.done":
 		return resToAnthHandleOutputItemDone(evt, state)
-	case "response.reasoning_summary_text.delta":
+	case "response.reasoning_summary_text.delta", "response.reasoning_text.delta":
 		return resToAnthHandleReasoningDelta(evt, state)
-	case "response.reasoning_summary_text.done":
-		return resToAnthHandleBlockDone(state)
+	case "response.reasoning_summary_text.done", "response.reasoning_text.done":
+		// The thinking block stays open until output_item.done, which carries
+		// the encrypted reasoning emitted as the block's signature.
+		return nil
 	case "response.completed", "response.incomplete", "response.failed":
 		return resToAnthHandleCompleted(evt, state)
 	default:
@@ -208,12 +215,13 @@ func FinalizeResponsesAnthropicStream(state *ResponsesEventToAnthropicState) []A
 }
 
 // ResponsesAnthropicEventToSSE formats an AnthropicStreamEvent as an SSE line pair.
+// Hot paths should use AppendResponsesAnthropicEventSSE with a reused buffer instead.
 func ResponsesAnthropicEventToSSE(evt AnthropicStreamEvent) (string, error) {
-	data, err := json.Marshal(evt)
+	data, err := AppendResponsesAnthropicEventSSE(nil, &evt)
 	if err != nil {
 		return "", err
 	}
-	return fmt.Sprintf("event: %s\ndata: %s\n\n", evt.Type, data), nil
+	return string(data), nil
 }
 
 // --- internal handlers ---
@@ -276,6 +284,9 @@ func resToAnthHandleOutputItemAdded(evt *ResponsesStreamEvent, state *ResponsesE
 		return events
 
 	case "reasoning":
+		if !state.IncludeThinking {
+			return nil
+		}
 		var events []AnthropicStreamEvent
 		events = append(events, closeCurrentBlock(state)...)
 
@@ -393,11 +404,29 @@ func resToAnthHandleOutputItemDone(evt *ResponsesStreamEvent, state *ResponsesEv
 	if evt.Item.Type == "web_search_call" && evt.Item.Status == "completed" {
 		return resToAnthHandleWebSearchDone(evt, state)
 	}
+	if evt.Item.Type == "reasoning" && !state.IncludeThinking {
+		return nil
+	}
 
+	var events []AnthropicStreamEvent
+	// encrypted_content only arrives on the done item; emit it as the thinking
+	// block's signature before the block closes.
+	if evt.Item.Type == "reasoning" && evt.Item.EncryptedContent != "" &&
+		state.ContentBlockOpen && state.CurrentBlockType == "thinking" {
+		idx := state.ContentBlockIndex
+		events = append(events, AnthropicStreamEvent{
+			Type:  "content_block_delta",
+			Index: &idx,
+			Delta: &AnthropicDelta{
+				Type:      "signature_delta",
+				Signature: encodeOpenAIReasoningSignature(evt.Item.EncryptedContent),
+			},
+		})
+	}
 	if state.ContentBlockOpen {
-		return closeCurrentBlock(state)
+		events = append(events, closeCurrentBlock(state)...)
 	}
-	return nil
+	return events
 }
 
 // resToAnthHandleWebSearchDone converts an OpenAI web_search_call output item
@@ -21,12 +21,25 @@ func ResponsesToAnthropicRequest(req *ResponsesRequest) (*AnthropicRequest, erro
 		Messages:    messages,
 		Temperature: req.Temperature,
 		TopP:        req.TopP,
+		TopK:        req.TopK,
+		StopSeqs:    req.StopSequences,
 		Stream:      req.Stream,
 	}
 
 	if len(system) > 0 {
 		out.System = system
 	}
+	// instructions → system (placed before any system input item)
+	if strings.TrimSpace(req.Instructions) != "" {
+		combined := req.Instructions
+		if len(out.System) > 0 {
+			existing, _ := parseAnthropicSystemPrompt(out.System)
+			if existing != "" {
+				combined += "\n\n" + existing
+			}
+		}
+		out.System, _ = json.Marshal(combined)
+	}
 
 	// max_output_tokens → max_tokens
 	if req.MaxOutputTokens != nil && *req.MaxOutputTokens > 0 {
@@ -37,13 +50,26 @@ func ResponsesToAnthropicRequest(req *ResponsesRequest) (*AnthropicRequest, erro
 		out.MaxTokens = 8192
 	}
 
-	// Convert tools
-	if len(req.Tools) > 0 {
-		out.Tools = convertResponsesToAnthropicTools(req.Tools)
+	// Convert tools; type=mcp tools become MCP connector servers
+	tools, mcpServers, err := splitResponsesMCPTools(req.Tools)
+	if err != nil {
+		return nil, err
+	}
+	out.MCPServers = mcpServers
+	for _, t := range tools {
+		if t.Type == "code_interpreter" {
+			return nil, fmt.Errorf("code_interpreter tool is not supported by Anthropic upstreams")
+		}
+	}
+	if len(tools) > 0 {
+		out.Tools = convertResponsesToAnthropicTools(tools)
 	}
 
-	// Convert tool_choice (reverse of convertAnthropicToolChoiceToResponses)
-	if len(req.ToolChoice) > 0 {
+	// Convert tool_choice (reverse of convertAnthropicToolChoiceToResponses).
+	// Anthropic rejects tool_choice without tools, so it is dropped when only
+	// MCP servers remain.
+	onlyMCP := len(mcpServers) > 0 && len(out.Tools) == 0
+	if len(req.ToolChoice) > 0 && !onlyMCP {
 		tc, err := convertResponsesToAnthropicToolChoice(req.ToolChoice)
 		if err != nil {
 			return nil, fmt.Errorf("convert tool_choice: %w", err)
@@ -51,6 +77,15 @@ func ResponsesToAnthropicRequest(req *ResponsesRequest) (*AnthropicRequest, erro
 		out.ToolChoice = tc
 	}
 
+	// parallel_tool_calls=false → tool_choice.disable_parallel_tool_use
+	if req.ParallelToolCalls != nil && !*req.ParallelToolCalls && !onlyMCP {
+		tc, err := withAnthropicDisableParallelToolUse(out.ToolChoice)
+		if err != nil {
+			return nil, fmt.Errorf("convert parallel_tool_calls: %w", err)
+		}
+		out.ToolChoice = tc
+	}
+
 	// reasoning.effort → output_config.effort + thinking
 	if req.Reasoning != nil && req.Reasoning.Effort != "" {
 		effort := mapResponsesEffortToAnthropic(req.Reasoning.Effort)
@@ -64,6 +99,17 @@ func ResponsesToAnthropicRequest(req *ResponsesRequest) (*AnthropicRequest, erro
 		}
 	}
 
+	// Extended thinking rejects top_k; drop it and report it in req.DroppedParams.
+	if out.Thinking != nil && out.TopK != nil {
+		out.TopK = nil
+		req.DroppedParams = append(req.DroppedParams, "top_k")
+	}
+
+	// text.format → output_config.format (json_schema) / system instruction (json_object)
+	if err := convertResponsesTextFormatToAnthropic(req.Text, out); err != nil {
+		return nil, err
+	}
+
 	return out, nil
 }
 
@@ -83,6 +129,18 @@ func defaultThinkingBudget(effort string) int {
 	}
 }
 
+// ThinkingBudgetForEffort returns the Anthropic thinking.budget_tokens used
+// for a Responses reasoning effort (low / medium / high / xhigh).
+func ThinkingBudgetForEffort(effort string) int {
+	return defaultThinkingBudget(mapResponsesEffortToAnthropic(effort))
+}
+
+// MapResponsesEffortToAnthropic converts a Responses reasoning effort to the
+// Anthropic output_config.effort level (xhigh → max).
+func MapResponsesEffortToAnthropic(effort string) string {
+	return mapResponsesEffortToAnthropic(effort)
+}
+
 // mapResponsesEffortToAnthropic converts OpenAI Responses reasoning effort to
 // Anthropic effort levels. Reverse of mapAnthropicEffortToResponses.
 //
@@ -143,6 +201,39 @@ func convertResponsesInputToAnthropic(inputRaw json.RawMessage) (json.RawMessage
 				Content: blockJSON,
 			})
 
+		case item.Type == "reasoning":
+			// Only reasoning that came from Anthropic can be replayed there;
+			// OpenAI encrypted reasoning is dropped.
+			signature, ok := decodeAnthropicThinkingEncrypted(item.EncryptedContent)
+			if !ok {
+				continue
+			}
+			var summary []ResponsesSummary
+			_ = json.Unmarshal(item.Summary, &summary)
+			blockJSON, _ := json.Marshal([]AnthropicContentBlock{{
+				Type:      "thinking",
+				Thinking:  reasoningSummaryText(summary),
+				Signature: signature,
+			}})
+			messages = append(messages, AnthropicMessage{
+				Role:    "assistant",
+				Content: blockJSON,
+			})
+
+		case item.Type == "mcp_call":
+			// mcp_call → assistant message with mcp_tool_use / mcp_tool_result
+			messages = append(messages, AnthropicMessage{
+				Role:    "assistant",
+				Content: mcpCallToAnthropicContent(item),
+			})
+
+		case item.Type == "mcp_list_tools":
+			// The MCP connector lists tools itself on every request.
+			continue
+
+		case item.Type == "mcp_approval_request", item.Type == "mcp_approval_response":
+			return nil, nil, fmt.Errorf("%s: MCP tool call approval is not supported by Anthropic upstreams", item.Type)
+
 		case item.Type == "function_call_output":
 			// function_call_output → user message with tool_result block
 			outputContent := item.Output
@@ -252,7 +343,7 @@ func convertResponsesUserToAnthropicContent(raw json.RawMessage) (json.RawMessag
 				})
 			}
 		case "input_image":
-			src := dataURIToAnthropicImageSource(p.ImageURL)
+			src := responsesImageToAnthropicSource(p.ImageURL)
 			if src != nil {
 				blocks = append(blocks, AnthropicContentBlock{
 					Type:   "image",
@@ -322,6 +413,16 @@ func fromResponsesCallIDToAnthropic(id string) string {
 	return id
 }
 
+// responsesImageToAnthropicSource converts an input_image URL into an
+// AnthropicImageSource: data URIs become base64 sources, http(s) URLs become
+// url sources. Anything else (e.g. an unresolved file_id) returns nil.
+func responsesImageToAnthropicSource(imageURL string) *AnthropicImageSource {
+	if strings.HasPrefix(imageURL, "https://") || strings.HasPrefix(imageURL, "http://") {
+		return &AnthropicImageSource{Type: "url", URL: imageURL}
+	}
+	return dataURIToAnthropicImageSource(imageURL)
+}
+
 // dataURIToAnthropicImageSource parses a data URI into an AnthropicImageSource.
 func dataURIToAnthropicImageSource(dataURI string) *AnthropicImageSource {
 	if !strings.HasPrefix(dataURI, "data:") {
@@ -429,36 +530,29 @@ func normalizeAnthropicInputSchema(schema json.RawMessage) json.RawMessage {
 //	"required"                                 → {"type":"any"}
 //	"none"                                     → {"type":"none"}
 //	{"type":"function","function":{"name":"X"}} → {"type":"tool","name":"X"}
+//	{"type":"function","name":"X"}              → {"type":"tool","name":"X"}
 func convertResponsesToAnthropicToolChoice(raw json.RawMessage) (json.RawMessage, error) {
-	// Try as string first
-	var s string
-	if err := json.Unmarshal(raw, &s); err == nil {
-		switch s {
-		case "auto":
-			return json.Marshal(map[string]string{"type": "auto"})
-		case "required":
-			return json.Marshal(map[string]string{"type": "any"})
-		case "none":
-			return json.Marshal(map[string]string{"type": "none"})
-		default:
-			return raw, nil
-		}
+	tc, ok := ParseResponsesToolChoice(raw)
+	if !ok {
+		// Pass through unknown
+		return raw, nil
 	}
+	return tc.AnthropicJSON()
+}
 
-	// Try as object with type=function
-	var tc struct {
-		Type     string `json:"type"`
-		Function struct {
-			Name string `json:"name"`
-		} `json:"function"`
+// withAnthropicDisableParallelToolUse sets disable_parallel_tool_use=true on an
+// Anthropic tool_choice object, defaulting to {"type":"auto"} when unset.
+// tool_choice "none" is left unchanged since no tools will be called.
+func withAnthropicDisableParallelToolUse(raw json.RawMessage) (json.RawMessage, error) {
+	tc := map[string]any{"type": "auto"}
+	if len(raw) > 0 {
+		if err := json.Unmarshal(raw, &tc); err != nil {
+			return nil, err
+		}
 	}
-	if err := json.Unmarshal(raw, &tc); err == nil && tc.Type == "function" && tc.Function.Name != "" {
-		return json.Marshal(map[string]string{
-			"type": "tool",
-			"name": tc.Function.Name,
-		})
+	if tc["type"] == "none" {
+		return raw, nil
 	}
-
-	// Pass through unknown
-	return raw, nil
+	tc["disable_parallel_tool_use"] = true
+	return json.Marshal(tc)
 }
@@ -0,0 +1,215 @@
+package apicompat
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// ---------------------------------------------------------------------------
+// Non-streaming: ResponsesResponse → GeminiResponse
+// ---------------------------------------------------------------------------
+
+// ResponsesToGemini converts a Responses API response into a Gemini
+// generateContent response. Reasoning summaries become thought parts (only
+// when the client set thinkingConfig.includeThoughts); function_call items
+// become functionCall parts.
+func ResponsesToGemini(resp *ResponsesResponse, model string, includeThoughts bool) *GeminiResponse {
+	var parts []GeminiPart
+
+	for _, item := range resp.Output {
+		switch item.Type {
+		case "reasoning":
+			if !includeThoughts {
+				continue
+			}
+			summaryText := ""
+			for _, s := range item.Summary {
+				if s.Type == "summary_text" && s.Text != "" {
+					summaryText += s.Text
+				}
+			}
+			if summaryText != "" {
+				parts = append(parts, GeminiPart{Text: summaryText, Thought: true})
+			}
+		case "message":
+			for _, part := range item.Content {
+				if part.Type == "output_text" && part.Text != "" {
+					parts = append(parts, GeminiPart{Text: part.Text})
+				}
+			}
+		case "function_call":
+			parts = append(parts, GeminiPart{FunctionCall: responsesFunctionCallToGemini(&item)})
+		}
+	}
+
+	if len(parts) == 0 {
+		parts = []GeminiPart{}
+	}
+
+	return &GeminiResponse{
+		Candidates: []GeminiCandidate{{
+			Content:      GeminiContent{Role: "model", Parts: parts},
+			FinishReason: responsesStatusToGeminiFinishReason(resp.Status, resp.IncompleteDetails),
+		}},
+		UsageMetadata: responsesUsageToGemini(resp.Usage),
+		ModelVersion:  model,
+		ResponseID:    resp.ID,
+	}
+}
+
+// responsesFunctionCallToGemini builds a functionCall part. Gemini expects
+// args as a JSON object, so invalid argument strings degrade to {}.
+func responsesFunctionCallToGemini(item *ResponsesOutput) *GeminiFunctionCall {
+	args := json.RawMessage(`{}`)
+	if item.Arguments != "" && json.Valid([]byte(item.Arguments)) {
+		args = json.RawMessage(item.Arguments)
+	}
+	return &GeminiFunctionCall{
+		ID:   fromResponsesCallID(item.CallID),
+		Name: item.Name,
+		Args: args,
+	}
+}
+
+// responsesStatusToGeminiFinishReason maps a Responses status to a Gemini
+// finishReason.
+func responsesStatusToGeminiFinishReason(status string, details *ResponsesIncompleteDetails) string {
+	switch status {
+	case "completed":
+		return "STOP"
+	case "incomplete":
+		if details != nil {
+			switch details.Reason {
+			case "max_output_tokens":
+				return "MAX_TOKENS"
+			case "content_filter":
+				return "SAFETY"
+			}
+		}
+		return "OTHER"
+	default:
+		return "OTHER"
+	}
+}
+
+// responsesUsageToGemini converts Responses usage to Gemini usageMetadata.
+func responsesUsageToGemini(usage *ResponsesUsage) *GeminiUsageMetadata {
+	if usage == nil {
+		return nil
+	}
+	out := &GeminiUsageMetadata{
+		PromptTokenCount:     usage.InputTokens,
+		CandidatesTokenCount: usage.OutputTokens,
+		TotalTokenCount:      usage.InputTokens + usage.OutputTokens,
+	}
+	if usage.InputTokensDetails != nil {
+		out.CachedContentTokenCount = usage.InputTokensDetails.CachedTokens
+	}
+	if usage.OutputTokensDetails != nil {
+		out.ThoughtsTokenCount = usage.OutputTokensDetails.ReasoningTokens
+	}
+	return out
+}
+
+// ---------------------------------------------------------------------------
+// Streaming: ResponsesStreamEvent → GeminiResponse chunks
+// ---------------------------------------------------------------------------
+
+// ResponsesEventToGeminiState tracks state for converting a sequence of
+// Responses SSE events into Gemini streamGenerateContent chunks.
+type ResponsesEventToGeminiState struct {
+	ResponseID      string
+	Model           string
+	IncludeThoughts bool
+	Finished        bool
+}
+
+// NewResponsesEventToGeminiState returns an initialised stream state.
+func NewResponsesEventToGeminiState() *ResponsesEventToGeminiState {
+	return &ResponsesEventToGeminiState{}
+}
+
+// ResponsesEventToGeminiChunks converts a single Responses SSE event into zero
+// or more Gemini chunks. Function calls are emitted whole once their output
+// item is done, matching Gemini's non-incremental functionCall parts.
+func ResponsesEventToGeminiChunks(
+	evt *ResponsesStreamEvent,
+	state *ResponsesEventToGeminiState,
+) []GeminiResponse {
+	if state.Finished {
+		return nil
+	}
+	switch evt.Type {
+	case "response.created":
+		if evt.Response != nil && evt.Response.ID != "" {
+			state.ResponseID = evt.Response.ID
+		}
+		return nil
+	case "response.output_text.delta":
+		if evt.Delta == "" {
+			return nil
+		}
+		return []GeminiResponse{makeGeminiChunk(state, []GeminiPart{{Text: evt.Delta}}, "", nil)}
+	case "response.reasoning_summary_text.delta", "response.reasoning_text.delta":
+		if evt.Delta == "" || !state.IncludeThoughts {
+			return nil
+		}
+		return []GeminiResponse{makeGeminiChunk(state, []GeminiPart{{Text: evt.Delta, Thought: true}}, "", nil)}
+	case "response.output_item.done":
+		if evt.Item == nil || evt.Item.Type != "function_call" {
+			return nil
+		}
+		return []GeminiResponse{makeGeminiChunk(state, []GeminiPart{{FunctionCall: responsesFunctionCallToGemini(evt.Item)}}, "", nil)}
+	case "response.completed", "response.incomplete", "response.failed":
+		state.Finished = true
+		status := ""
+		var details *ResponsesIncompleteDetails
+		var usage *ResponsesUsage
+		if evt.Response != nil {
+			status = evt.Response.Status
+			details = evt.Response.IncompleteDetails
+			usage = evt.Response.Usage
+		}
+		if status == "" {
+			status = strings.TrimPrefix(evt.Type, "response.")
+		}
+		return []GeminiResponse{makeGeminiChunk(state, nil, responsesStatusToGeminiFinishReason(status, details), responsesUsageToGemini(usage))}
+	default:
+		return nil
+	}
+}
+
+// FinalizeResponsesGeminiStream emits a closing chunk (finishReason OTHER)
+// when the upstream stream ended without a terminal event.
+func FinalizeResponsesGeminiStream(state *ResponsesEventToGeminiState) []GeminiResponse {
+	if state.Finished {
+		return nil
+	}
+	state.Finished = true
+	return []GeminiResponse{makeGeminiChunk(state, nil, "OTHER", nil)}
+}
+
+// GeminiChunkToSSE formats a Gemini chunk as an SSE data line (alt=sse).
+func GeminiChunkToSSE(chunk GeminiResponse) (string, error) {
+	data, err := json.Marshal(chunk)
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("data: %s\n\n", data), nil
+}
+
+func makeGeminiChunk(state *ResponsesEventToGeminiState, parts []GeminiPart, finishReason string, usage *GeminiUsageMetadata) GeminiResponse {
+	if parts == nil {
+		parts = []GeminiPart{}
+	}
+	return GeminiResponse{
+		Candidates: []GeminiCandidate{{
+			Content:      GeminiContent{Role: "model", Parts: parts},
+			FinishReason: finishReason,
+		}},
+		UsageMetadata: usage,
+		ModelVersion:  state.Model,
+		ResponseID:    state.ResponseID,
+	}
+}
@@ -0,0 +1,189 @@
+package apicompat
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// ---------------------------------------------------------------------------
+// Non-streaming: ResponsesResponse → OllamaChatResponse
+// ---------------------------------------------------------------------------
+
+// ResponsesToOllamaChat converts a Responses API response into an Ollama
+// /api/chat response. Reasoning summaries become message.thinking (only when
+// the client enabled think); function_call items become tool_calls.
+func ResponsesToOllamaChat(resp *ResponsesResponse, model string, includeThinking bool) *OllamaChatResponse {
+	msg := OllamaMessage{Role: "assistant"}
+
+	for _, item := range resp.Output {
+		switch item.Type {
+		case "reasoning":
+			if !includeThinking {
+				continue
+			}
+			for _, s := range item.Summary {
+				if s.Type == "summary_text" {
+					msg.Thinking += s.Text
+				}
+			}
+		case "message":
+			for _, part := range item.Content {
+				if part.Type == "output_text" {
+					msg.Content += part.Text
+				}
+			}
+		case "function_call":
+			msg.ToolCalls = append(msg.ToolCalls, responsesFunctionCallToOllama(&item))
+		}
+	}
+
+	out := &OllamaChatResponse{
+		Model:      model,
+		CreatedAt:  ollamaTimestamp(),
+		Message:    msg,
+		Done:       true,
+		DoneReason: responsesStatusToOllamaDoneReason(resp.Status, resp.IncompleteDetails),
+	}
+	applyOllamaUsage(&out.OllamaMetrics, resp.Usage)
+	return out
+}
+
+// OllamaChatToGenerate reshapes a chat response (or stream chunk) into the
+// /api/generate format. Tool calls have no equivalent and are dropped.
+func OllamaChatToGenerate(chat *OllamaChatResponse) *OllamaGenerateResponse {
+	return &OllamaGenerateResponse{
+		Model:         chat.Model,
+		CreatedAt:     chat.CreatedAt,
+		Response:      chat.Message.Content,
+		Thinking:      chat.Message.Thinking,
+		Done:          chat.Done,
+		DoneReason:    chat.DoneReason,
+		OllamaMetrics: chat.OllamaMetrics,
+	}
+}
+
+// responsesFunctionCallToOllama builds a tool call. Ollama expects arguments
+// as a JSON object, so invalid argument strings degrade to {}.
+func responsesFunctionCallToOllama(item *ResponsesOutput) OllamaToolCall {
+	args := json.RawMessage(`{}`)
+	if item.Arguments != "" && json.Valid([]byte(item.Arguments)) {
+		args = json.RawMessage(item.Arguments)
+	}
+	return OllamaToolCall{Function: OllamaToolCallFunction{Name: item.Name, Arguments: args}}
+}
+
+// responsesStatusToOllamaDoneReason maps a Responses status to done_reason.
+// Ollama only distinguishes "stop" and "length".
+func responsesStatusToOllamaDoneReason(status string, details *ResponsesIncompleteDetails) string {
+	if status == "incomplete" && details != nil && details.Reason == "max_output_tokens" {
+		return "length"
+	}
+	return "stop"
+}
+
+func applyOllamaUsage(m *OllamaMetrics, usage *ResponsesUsage) {
+	if usage == nil {
+		return
+	}
+	m.PromptEvalCount = usage.InputTokens
+	m.EvalCount = usage.OutputTokens
+}
+
+func ollamaTimestamp() string {
+	return time.Now().UTC().Format(time.RFC3339Nano)
+}
+
+// ---------------------------------------------------------------------------
+// Streaming: ResponsesStreamEvent → OllamaChatResponse chunks
+// ---------------------------------------------------------------------------
+
+// ResponsesEventToOllamaState tracks state for converting a sequence of
+// Responses SSE events into Ollama NDJSON chunks.
+type ResponsesEventToOllamaState struct {
+	Model           string
+	IncludeThinking bool
+	StartTime       time.Time
+	Finished        bool
+}
+
+// NewResponsesEventToOllamaState returns an initialised stream state.
+func NewResponsesEventToOllamaState() *ResponsesEventToOllamaState {
+	return &ResponsesEventToOllamaState{StartTime: time.Now()}
+}
+
+// ResponsesEventToOllamaChatChunks converts a single Responses SSE event into
+// zero or more /api/chat chunks. Tool calls are emitted whole once their
+// output item is done; the terminal event yields the done=true chunk.
+func ResponsesEventToOllamaChatChunks(evt *ResponsesStreamEvent, state *ResponsesEventToOllamaState) []OllamaChatResponse {
+	if state.Finished {
+		return nil
+	}
+	switch evt.Type {
+	case "response.output_text.delta":
+		if evt.Delta == "" {
+			return nil
+		}
+		return []OllamaChatResponse{makeOllamaChunk(state, OllamaMessage{Role: "assistant", Content: evt.Delta})}
+	case "response.reasoning_summary_text.delta", "response.reasoning_text.delta":
+		if evt.Delta == "" || !state.IncludeThinking {
+			return nil
+		}
+		return []OllamaChatResponse{makeOllamaChunk(state, OllamaMessage{Role: "assistant", Thinking: evt.Delta})}
+	case "response.output_item.done":
+		if evt.Item == nil || evt.Item.Type != "function_call" {
+			return nil
+		}
+		return []OllamaChatResponse{makeOllamaChunk(state, OllamaMessage{
+			Role:      "assistant",
+			ToolCalls: []OllamaToolCall{responsesFunctionCallToOllama(evt.Item)},
+		})}
+	case "response.completed", "response.incomplete", "response.failed":
+		status := ""
+		var details *ResponsesIncompleteDetails
+		var usage *ResponsesUsage
+		if evt.Response != nil {
+			status = evt.Response.Status
+			details = evt.Response.IncompleteDetails
+			usage = evt.Response.Usage
+		}
+		return []OllamaChatResponse{makeOllamaDoneChunk(state, responsesStatusToOllamaDoneReason(status, details), usage)}
+	default:
+		return nil
+	}
+}
+
+// FinalizeResponsesOllamaStream emits the done=true chunk when the upstream
+// stream ended without a terminal event.
+func FinalizeResponsesOllamaStream(state *ResponsesEventToOllamaState) []OllamaChatResponse {
+	if state.Finished {
+		return nil
+	}
+	return []OllamaChatResponse{makeOllamaDoneChunk(state, "stop", nil)}
+}
+
+// OllamaChunkToNDJSON encodes a chunk as one NDJSON line.
+func OllamaChunkToNDJSON(chunk any) (string, error) {
+	data, err := json.Marshal(chunk)
+	if err != nil {
+		return "", err
+	}
+	return string(data) + "\n", nil
+}
+
+func makeOllamaChunk(state *ResponsesEventToOllamaState, msg OllamaMessage) OllamaChatResponse {
+	return OllamaChatResponse{
+		Model:     state.Model,
+		CreatedAt: ollamaTimestamp(),
+		Message:   msg,
+	}
+}
+
+func makeOllamaDoneChunk(state *ResponsesEventToOllamaState, doneReason string, usage *ResponsesUsage) OllamaChatResponse {
+	state.Finished = true
+	chunk := makeOllamaChunk(state, OllamaMessage{Role: "assistant"})
+	chunk.Done = true
+	chunk.DoneReason = doneReason
+	chunk.TotalDuration = time.Since(state.StartTime).Nanoseconds()
+	applyOllamaUsage(&chunk.OllamaMetrics, usage)
+	return chunk
+}
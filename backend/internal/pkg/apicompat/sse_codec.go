@@ -0,0 +1,367 @@
+package apicompat
+
+import (
+	"bytes"
+	"encoding/json"
+	"strconv"
+	"sync"
+	"unicode/utf8"
+
+	"github.com/tidwall/gjson"
+)
+
+// 流式转换热路径的编解码。
+//
+// 每个上游 SSE 事件原本要经过一次反射 json.Unmarshal、一次 json.Marshal 和一次 fmt.Sprintf，
+// 高并发流式下产生大量短命对象，GC 停顿会让增量输出出现可感知的卡顿。这里：
+//   - 只含标量字段的"扁平"事件（各类 delta、content_block_stop、message_stop 等，占流的绝大多数）
+//     直接按字段顺序追加编码 / 用 gjson 解码，不经过反射；
+//   - 其余事件的 JSON 编码复用 sync.Pool 中的 json.Encoder 与缓冲区；
+//   - Append* 接口把 SSE 帧追加到调用方复用的缓冲区，由调用方一次性写入 writer。
+//
+// 快速路径的输出与 json.Marshal 逐字节一致（含 HTML 转义、U+2028/U+2029 与非法 UTF-8 的处理）。
+
+// sseEncoder 池化的 JSON 编码器
+type sseEncoder struct {
+	buf bytes.Buffer
+	enc *json.Encoder
+}
+
+// sseEncoderMaxPooledCap 超过该容量的缓冲区不放回池中，避免偶发的大事件（如带完整响应的 completed 事件）长期占用内存
+const sseEncoderMaxPooledCap = 64 << 10
+
+var sseEncoderPool = sync.Pool{
+	New: func() any {
+		e := &sseEncoder{}
+		e.enc = json.NewEncoder(&e.buf)
+		return e
+	},
+}
+
+// appendJSON 以 json.Marshal 相同的格式把 v 追加到 dst
+func appendJSON(dst []byte, v any) ([]byte, error) {
+	e := sseEncoderPool.Get().(*sseEncoder)
+	e.buf.Reset()
+	err := e.enc.Encode(v)
+	if err == nil {
+		out := e.buf.Bytes()
+		dst = append(dst, out[:len(out)-1]...) // 去掉 Encoder 追加的换行
+	}
+	if e.buf.Cap() <= sseEncoderMaxPooledCap {
+		sseEncoderPool.Put(e)
+	}
+	return dst, err
+}
+
+// appendSSEFrame 追加 "event: {type}\ndata: {json}\n\n"
+func appendSSEFrame(dst []byte, eventType string, encode func([]byte) ([]byte, error)) ([]byte, error) {
+	start := len(dst)
+	dst = append(dst, "event: "...)
+	dst = append(dst, eventType...)
+	dst = append(dst, "\ndata: "...)
+	dst, err := encode(dst)
+	if err != nil {
+		return dst[:start], err
+	}
+	return append(dst, '\n', '\n'), nil
+}
+
+// AppendResponsesAnthropicEventSSE 把 Anthropic 流式事件编码为 SSE 帧并追加到 dst；出错时 dst 保持不变
+func AppendResponsesAnthropicEventSSE(dst []byte, evt *AnthropicStreamEvent) ([]byte, error) {
+	return appendSSEFrame(dst, evt.Type, func(b []byte) ([]byte, error) {
+		if evt.Message == nil && evt.ContentBlock == nil && evt.Usage == nil {
+			return appendFlatAnthropicEvent(b, evt), nil
+		}
+		return appendJSON(b, evt)
+	})
+}
+
+// AppendResponsesEventSSE 把 Responses 流式事件编码为 SSE 帧并追加到 dst；出错时 dst 保持不变
+func AppendResponsesEventSSE(dst []byte, evt *ResponsesStreamEvent) ([]byte, error) {
+	return appendSSEFrame(dst, evt.Type, func(b []byte) ([]byte, error) {
+		if evt.Response == nil && evt.Item == nil && len(evt.Logprobs) == 0 && evt.Annotation == nil && evt.Usage == nil {
+			return appendFlatResponsesEvent(b, evt), nil
+		}
+		return appendJSON(b, evt)
+	})
+}
+
+// appendFlatAnthropicEvent 编码不含 message / content_block / usage 的事件，字段顺序与 AnthropicStreamEvent 一致
+func appendFlatAnthropicEvent(dst []byte, evt *AnthropicStreamEvent) []byte {
+	dst = append(dst, `{"type":`...)
+	dst = appendJSONString(dst, evt.Type)
+	if evt.Index != nil {
+		dst = append(dst, `,"index":`...)
+		dst = strconv.AppendInt(dst, int64(*evt.Index), 10)
+	}
+	if d := evt.Delta; d != nil {
+		dst = append(dst, `,"delta":{`...)
+		fields := 0
+		dst = appendStringField(dst, &fields, "type", d.Type)
+		dst = appendStringField(dst, &fields, "text", d.Text)
+		dst = appendStringField(dst, &fields, "partial_json", d.PartialJSON)
+		dst = appendStringField(dst, &fields, "thinking", d.Thinking)
+		dst = appendStringField(dst, &fields, "signature", d.Signature)
+		dst = appendStringField(dst, &fields, "stop_reason", d.StopReason)
+		if d.StopSequence != nil {
+			dst = appendFieldName(dst, &fields, "stop_sequence")
+			dst = appendJSONString(dst, *d.StopSequence)
+		}
+		dst = append(dst, '}')
+	}
+	return append(dst, '}')
+}
+
+// appendFlatResponsesEvent 编码不含 response / item / annotation / usage 且 logprobs 为空的事件，字段顺序与 ResponsesStreamEvent 一致
+func appendFlatResponsesEvent(dst []byte, evt *ResponsesStreamEvent) []byte {
+	dst = append(dst, `{"type":`...)
+	dst = appendJSONString(dst, evt.Type)
+	fields := 1
+	dst = appendIntField(dst, &fields, "output_index", evt.OutputIndex)
+	dst = appendIntField(dst, &fields, "content_index", evt.ContentIndex)
+	dst = appendStringField(dst, &fields, "delta", evt.Delta)
+	dst = appendStringField(dst, &fields, "text", evt.Text)
+	dst = appendStringField(dst, &fields, "item_id", evt.ItemID)
+	dst = appendStringField(dst, &fields, "call_id", evt.CallID)
+	dst = appendStringField(dst, &fields, "name", evt.Name)
+	dst = appendStringField(dst, &fields, "arguments", evt.Arguments)
+	dst = appendIntField(dst, &fields, "summary_index", evt.SummaryIndex)
+	dst = appendIntField(dst, &fields, "annotation_index", evt.AnnotationIndex)
+	dst = appendStringField(dst, &fields, "code", evt.Code)
+	dst = appendStringField(dst, &fields, "param", evt.Param)
+	dst = appendIntField(dst, &fields, "sequence_number", evt.SequenceNumber)
+	return append(dst, '}')
+}
+
+func appendFieldName(dst []byte, fields *int, name string) []byte {
+	if *fields > 0 {
+		dst = append(dst, ',')
+	}
+	*fields++
+	dst = append(dst, '"')
+	dst = append(dst, name...)
+	return append(dst, '"', ':')
+}
+
+// appendStringField 追加 omitempty 语义的字符串字段
+func appendStringField(dst []byte, fields *int, name, value string) []byte {
+	if value == "" {
+		return dst
+	}
+	dst = appendFieldName(dst, fields, name)
+	return appendJSONString(dst, value)
+}
+
+// appendIntField 追加 omitempty 语义的整数字段
+func appendIntField(dst []byte, fields *int, name string, value int) []byte {
+	if value == 0 {
+		return dst
+	}
+	dst = appendFieldName(dst, fields, name)
+	return strconv.AppendInt(dst, int64(value), 10)
+}
+
+const jsonHex = "0123456789abcdef"
+
+// appendJSONString 按 encoding/json 的规则（开启 HTML 转义）编码字符串
+func appendJSONString(dst []byte, s string) []byte {
+	dst = append(dst, '"')
+	start := 0
+	for i := 0; i < len(s); {
+		if b := s[i]; b < utf8.RuneSelf {
+			if b >= 0x20 && b != '"' && b != '\\' && b != '<' && b != '>' && b != '&' {
+				i++
+				continue
+			}
+			dst = append(dst, s[start:i]...)
+			switch b {
+			case '\\', '"':
+				dst = append(dst, '\\', b)
+			case '\b':
+				dst = append(dst, '\\', 'b')
+			case '\f':
+				dst = append(dst, '\\', 'f')
+			case '\n':
+				dst = append(dst, '\\', 'n')
+			case '\r':
+				dst = append(dst, '\\', 'r')
+			case '\t':
+				dst = append(dst, '\\', 't')
+			default:
+				dst = append(dst, '\\', 'u', '0', '0', jsonHex[b>>4], jsonHex[b&0xF])
+			}
+			i++
+			start = i
+			continue
+		}
+		r, size := utf8.DecodeRuneInString(s[i:])
+		if r == utf8.RuneError && size == 1 {
+			dst = append(dst, s[start:i]...)
+			dst = append(dst, "\ufffd"...)
+			i += size
+			start = i
+			continue
+		}
+		if r == '\u2028' || r == '\u2029' {
+			dst = append(dst, s[start:i]...)
+			dst = append(dst, '\\', 'u', '2', '0', '2', jsonHex[r&0xF])
+			i += size
+			start = i
+			continue
+		}
+		i += size
+	}
+	dst = append(dst, s[start:]...)
+	return append(dst, '"')
+}
+
+// ParseResponsesStreamEvent 解码上游 Responses SSE 事件的 data 负载。
+// 只含标量字段的事件用 gjson 直接取值，其余（含 response / item / annotation / usage / 非空 logprobs，或字段类型异常）回退到 json.Unmarshal。
+func ParseResponsesStreamEvent(data []byte, evt *ResponsesStreamEvent) error {
+	parsed := gjson.ParseBytes(data)
+	if !parsed.IsObject() {
+		return json.Unmarshal(data, evt)
+	}
+	flat := true
+	var out ResponsesStreamEvent
+	parsed.ForEach(func(key, value gjson.Result) bool {
+		switch key.Str {
+		case "type":
+			flat = assignString(&out.Type, value)
+		case "output_index":
+			flat = assignInt(&out.OutputIndex, value)
+		case "content_index":
+			flat = assignInt(&out.ContentIndex, value)
+		case "delta":
+			flat = assignString(&out.Delta, value)
+		case "text":
+			flat = assignString(&out.Text, value)
+		case "item_id":
+			flat = assignString(&out.ItemID, value)
+		case "call_id":
+			flat = assignString(&out.CallID, value)
+		case "name":
+			flat = assignString(&out.Name, value)
+		case "arguments":
+			flat = assignString(&out.Arguments, value)
+		case "summary_index":
+			flat = assignInt(&out.SummaryIndex, value)
+		case "annotation_index":
+			flat = assignInt(&out.AnnotationIndex, value)
+		case "code":
+			flat = assignString(&out.Code, value)
+		case "param":
+			flat = assignString(&out.Param, value)
+		case "sequence_number":
+			flat = assignInt(&out.SequenceNumber, value)
+		case "logprobs":
+			// 未请求 logprobs 时上游返回空数组
+			if value.IsArray() && len(value.Array()) == 0 {
+				out.Logprobs = []ResponsesLogprob{}
+				return true
+			}
+			flat = value.Type == gjson.Null
+		case "response", "item", "annotation", "usage":
+			flat = value.Type == gjson.Null
+		}
+		return flat
+	})
+	if !flat {
+		return json.Unmarshal(data, evt)
+	}
+	*evt = out
+	return nil
+}
+
+// ParseAnthropicStreamEvent 解码上游 Anthropic SSE 事件的 data 负载。
+// content_block_delta / content_block_stop / message_stop / ping 等扁平事件用 gjson 直接取值，其余回退到 json.Unmarshal。
+func ParseAnthropicStreamEvent(data []byte, evt *AnthropicStreamEvent) error {
+	parsed := gjson.ParseBytes(data)
+	if !parsed.IsObject() {
+		return json.Unmarshal(data, evt)
+	}
+	flat := true
+	var out AnthropicStreamEvent
+	var delta AnthropicDelta
+	parsed.ForEach(func(key, value gjson.Result) bool {
+		switch key.Str {
+		case "type":
+			flat = assignString(&out.Type, value)
+		case "index":
+			if value.Type == gjson.Null {
+				return true
+			}
+			var idx int
+			if flat = assignInt(&idx, value); flat {
+				out.Index = &idx
+			}
+		case "delta":
+			if value.Type == gjson.Null {
+				return true
+			}
+			if flat = value.IsObject(); flat {
+				out.Delta = &delta
+				value.ForEach(func(k, v gjson.Result) bool {
+					switch k.Str {
+					case "type":
+						flat = assignString(&delta.Type, v)
+					case "text":
+						flat = assignString(&delta.Text, v)
+					case "partial_json":
+						flat = assignString(&delta.PartialJSON, v)
+					case "thinking":
+						flat = assignString(&delta.Thinking, v)
+					case "signature":
+						flat = assignString(&delta.Signature, v)
+					case "stop_reason":
+						flat = assignString(&delta.StopReason, v)
+					case "stop_sequence":
+						if v.Type != gjson.Null {
+							var seq string
+							if flat = assignString(&seq, v); flat {
+								delta.StopSequence = &seq
+							}
+						}
+					}
+					return flat
+				})
+			}
+		case "message", "content_block", "usage":
+			flat = value.Type == gjson.Null
+		}
+		return flat
+	})
+	if !flat {
+		return json.Unmarshal(data, evt)
+	}
+	*evt = out
+	return nil
+}
+
+func assignString(dst *string, value gjson.Result) bool {
+	switch value.Type {
+	case gjson.String:
+		*dst = value.Str
+		return true
+	case gjson.Null:
+		return true
+	default:
+		return false
+	}
+}
+
+func assignInt(dst *int, value gjson.Result) bool {
+	switch value.Type {
+	case gjson.Number:
+		n, err := strconv.Atoi(value.Raw)
+		if err != nil {
+			return false
+		}
+		*dst = n
+		return true
+	case gjson.Null:
+		return true
+	default:
+		return false
+	}
+}
@@ -0,0 +1,165 @@
+package apicompat
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"testing"
+)
+
+var benchmarkBytesSink []byte
+
+// benchmarkResponsesStream 模拟一次典型的 Responses 流式响应：少量生命周期事件加大量文本增量
+func benchmarkResponsesStream(deltas int) [][]byte {
+	lines := [][]byte{
+		[]byte(`{"type":"response.created","response":{"id":"resp_1","object":"response","model":"gpt-5","status":"in_progress","output":[]},"sequence_number":0}`),
+		[]byte(`{"type":"response.output_item.added","output_index":0,"item":{"type":"message","id":"msg_1","role":"assistant","status":"in_progress","content":[]},"sequence_number":1}`),
+		[]byte(`{"type":"response.content_part.added","item_id":"msg_1","output_index":0,"content_index":0,"part":{"type":"output_text","text":""},"sequence_number":2}`),
+	}
+	for i := 0; i < deltas; i++ {
+		lines = append(lines, []byte(`{"type":"response.output_text.delta","item_id":"msg_1","output_index":0,"content_index":0,"delta":"token `+strconv.Itoa(i)+` 你好\n","logprobs":[],"sequence_number":`+strconv.Itoa(i+3)+`}`))
+	}
+	return append(lines,
+		[]byte(`{"type":"response.output_text.done","item_id":"msg_1","output_index":0,"content_index":0,"text":"done","sequence_number":9998}`),
+		[]byte(`{"type":"response.completed","response":{"id":"resp_1","object":"response","model":"gpt-5","status":"completed","output":[{"type":"message","id":"msg_1","role":"assistant","content":[{"type":"output_text","text":"done"}]}],"usage":{"input_tokens":12,"output_tokens":200,"total_tokens":212}},"sequence_number":9999}`),
+	)
+}
+
+// BenchmarkResponsesToAnthropicStream 对比逐事件 Unmarshal/Marshal/Sprintf 与复用缓冲区的流式转换开销。
+func BenchmarkResponsesToAnthropicStream(b *testing.B) {
+	lines := benchmarkResponsesStream(200)
+
+	b.Run("legacy", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			state := NewResponsesEventToAnthropicState()
+			for _, line := range lines {
+				var evt ResponsesStreamEvent
+				if err := json.Unmarshal(line, &evt); err != nil {
+					b.Fatal(err)
+				}
+				for _, out := range ResponsesEventToAnthropicEvents(&evt, state) {
+					data, err := json.Marshal(out)
+					if err != nil {
+						b.Fatal(err)
+					}
+					_, _ = fmt.Fprint(io.Discard, fmt.Sprintf("event: %s\ndata: %s\n\n", out.Type, data))
+				}
+			}
+		}
+	})
+
+	b.Run("pooled", func(b *testing.B) {
+		b.ReportAllocs()
+		var buf []byte
+		for i := 0; i < b.N; i++ {
+			state := NewResponsesEventToAnthropicState()
+			for _, line := range lines {
+				var evt ResponsesStreamEvent
+				if err := ParseResponsesStreamEvent(line, &evt); err != nil {
+					b.Fatal(err)
+				}
+				events := ResponsesEventToAnthropicEvents(&evt, state)
+				buf = buf[:0]
+				for j := range events {
+					var err error
+					if buf, err = AppendResponsesAnthropicEventSSE(buf, &events[j]); err != nil {
+						b.Fatal(err)
+					}
+				}
+				_, _ = io.Discard.Write(buf)
+			}
+		}
+	})
+}
+
+// BenchmarkAnthropicToResponsesStream 反方向（Anthropic 上游 → Responses 客户端）的同类对比。
+func BenchmarkAnthropicToResponsesStream(b *testing.B) {
+	lines := [][]byte{
+		[]byte(`{"type":"message_start","message":{"id":"msg_1","type":"message","role":"assistant","content":[],"model":"claude-sonnet-4-5","usage":{"input_tokens":12,"output_tokens":1}}}`),
+		[]byte(`{"type":"content_block_start","index":0,"content_block":{"type":"text","text":""}}`),
+	}
+	for i := 0; i < 200; i++ {
+		lines = append(lines, []byte(`{"type":"content_block_delta","index":0,"delta":{"type":"text_delta","text":"token `+strconv.Itoa(i)+` 你好\n"}}`))
+	}
+	lines = append(lines,
+		[]byte(`{"type":"content_block_stop","index":0}`),
+		[]byte(`{"type":"message_delta","delta":{"stop_reason":"end_turn","stop_sequence":null},"usage":{"output_tokens":200}}`),
+		[]byte(`{"type":"message_stop"}`),
+	)
+
+	b.Run("legacy", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			state := NewAnthropicEventToResponsesState()
+			for _, line := range lines {
+				var evt AnthropicStreamEvent
+				if err := json.Unmarshal(line, &evt); err != nil {
+					b.Fatal(err)
+				}
+				for _, out := range AnthropicEventToResponsesEvents(&evt, state) {
+					data, err := json.Marshal(out)
+					if err != nil {
+						b.Fatal(err)
+					}
+					_, _ = fmt.Fprint(io.Discard, fmt.Sprintf("event: %s\ndata: %s\n\n", out.Type, data))
+				}
+			}
+		}
+	})
+
+	b.Run("pooled", func(b *testing.B) {
+		b.ReportAllocs()
+		var buf []byte
+		for i := 0; i < b.N; i++ {
+			state := NewAnthropicEventToResponsesState()
+			for _, line := range lines {
+				var evt AnthropicStreamEvent
+				if err := ParseAnthropicStreamEvent(line, &evt); err != nil {
+					b.Fatal(err)
+				}
+				events := AnthropicEventToResponsesEvents(&evt, state)
+				buf = buf[:0]
+				for j := range events {
+					var err error
+					if buf, err = AppendResponsesEventSSE(buf, &events[j]); err != nil {
+						b.Fatal(err)
+					}
+				}
+				_, _ = io.Discard.Write(buf)
+			}
+		}
+	})
+}
+
+// BenchmarkAppendResponsesAnthropicEventSSE_TextDelta 单个文本增量事件的编码开销。
+func BenchmarkAppendResponsesAnthropicEventSSE_TextDelta(b *testing.B) {
+	idx := 0
+	evt := AnthropicStreamEvent{Type: "content_block_delta", Index: &idx, Delta: &AnthropicDelta{Type: "text_delta", Text: "Hello, <world> & \"friends\"\n"}}
+	buf := make([]byte, 0, 256)
+
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		buf, _ = AppendResponsesAnthropicEventSSE(buf[:0], &evt)
+	}
+	benchmarkBytesSink = buf
+}
+
+// BenchmarkAppendResponsesEventSSE_Completed 含完整响应体的事件走池化编码器的开销。
+func BenchmarkAppendResponsesEventSSE_Completed(b *testing.B) {
+	evt := ResponsesStreamEvent{
+		Type: "response.completed",
+		Response: &ResponsesResponse{
+			ID: "resp_1", Object: "response", Model: "gpt-5", Status: "completed",
+			Usage: &ResponsesUsage{InputTokens: 12, OutputTokens: 200, TotalTokens: 212},
+		},
+	}
+	buf := make([]byte, 0, 1024)
+
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		buf, _ = AppendResponsesEventSSE(buf[:0], &evt)
+	}
+	benchmarkBytesSink = buf
+}
@@ -0,0 +1,164 @@
+package apicompat
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// 覆盖 encoding/json 的各类转义分支
+var sseCodecTrickyStrings = []string{
+	"",
+	"hello",
+	"你好，世界 🌏",
+	"quote \" backslash \\ slash /",
+	"line\nbreak\r\ttab\b\f",
+	"\x00\x01\x1f\x7f",
+	"<script>&amp;</script>",
+	"sep \u2028 para \u2029 end",
+	"bad utf8 \xff\xfe tail",
+	"truncated \xe4\xbd",
+	`{"partial": [1, 2`,
+}
+
+func legacySSE(t *testing.T, eventType string, v any) []byte {
+	t.Helper()
+	data, err := json.Marshal(v)
+	require.NoError(t, err)
+	return []byte("event: " + eventType + "\ndata: " + string(data) + "\n\n")
+}
+
+func TestAppendJSONStringMatchesEncodingJSON(t *testing.T) {
+	for _, s := range sseCodecTrickyStrings {
+		want, err := json.Marshal(s)
+		require.NoError(t, err)
+		assert.Equal(t, string(want), string(appendJSONString(nil, s)), "input %q", s)
+	}
+}
+
+func TestAppendResponsesAnthropicEventSSEMatchesMarshal(t *testing.T) {
+	idx := 3
+	stop := "\n\nHuman:"
+	events := []AnthropicStreamEvent{
+		{Type: "message_stop"},
+		{Type: "ping"},
+		{Type: "content_block_stop", Index: &idx},
+		{Type: "message_delta", Delta: &AnthropicDelta{StopReason: "stop_sequence", StopSequence: &stop}},
+		{Type: "message_delta", Delta: &AnthropicDelta{}},
+		{Type: "content_block_delta", Index: &idx, Delta: &AnthropicDelta{Type: "signature_delta", Signature: "sig=="}},
+		{Type: "message_start", Message: &AnthropicResponse{ID: "msg_1", Type: "message", Role: "assistant"}},
+		{Type: "content_block_start", Index: &idx, ContentBlock: &AnthropicContentBlock{Type: "text"}},
+	}
+	for _, s := range sseCodecTrickyStrings {
+		events = append(events,
+			AnthropicStreamEvent{Type: "content_block_delta", Index: &idx, Delta: &AnthropicDelta{Type: "text_delta", Text: s}},
+			AnthropicStreamEvent{Type: "content_block_delta", Index: &idx, Delta: &AnthropicDelta{Type: "input_json_delta", PartialJSON: s}},
+			AnthropicStreamEvent{Type: "content_block_delta", Index: &idx, Delta: &AnthropicDelta{Type: "thinking_delta", Thinking: s}},
+		)
+	}
+	for _, evt := range events {
+		got, err := AppendResponsesAnthropicEventSSE(nil, &evt)
+		require.NoError(t, err)
+		assert.Equal(t, string(legacySSE(t, evt.Type, evt)), string(got))
+	}
+}
+
+func TestAppendResponsesEventSSEMatchesMarshal(t *testing.T) {
+	events := []ResponsesStreamEvent{
+		{Type: "response.output_text.done", OutputIndex: 1, ContentIndex: 2, ItemID: "msg_1", Text: "done", SequenceNumber: 9},
+		{Type: "response.function_call_arguments.delta", OutputIndex: 2, ItemID: "fc_1", CallID: "call_1", Name: "get_weather", Arguments: `{"city":"SF"}`},
+		{Type: "response.reasoning_summary_text.delta", SummaryIndex: 1, Delta: "thinking"},
+		{Type: "error", Code: "server_error", Param: "model"},
+		{Type: "response.output_text.delta", OutputIndex: -1},
+		{Type: "response.created", Response: &ResponsesResponse{ID: "resp_1", Object: "response", Status: "in_progress"}},
+		{Type: "response.output_item.added", Item: &ResponsesOutput{Type: "message", ID: "msg_1"}},
+		{Type: "response.output_text.delta", Delta: "x", Logprobs: []ResponsesLogprob{}},
+		{Type: "response.output_text.annotation.added", ItemID: "msg_1", AnnotationIndex: 1, Annotation: &ResponsesAnnotation{Type: "url_citation", URL: "https://example.com", EndIndex: 4}},
+		{Type: "response.code_interpreter_call_code.done", ItemID: "ci_1", Code: "print(1)"},
+	}
+	for _, s := range sseCodecTrickyStrings {
+		events = append(events, ResponsesStreamEvent{Type: "response.output_text.delta", ItemID: "msg_1", Delta: s})
+	}
+	for _, evt := range events {
+		got, err := AppendResponsesEventSSE(nil, &evt)
+		require.NoError(t, err)
+		assert.Equal(t, string(legacySSE(t, evt.Type, evt)), string(got))
+	}
+}
+
+func TestAppendSSEAppendsToExistingBuffer(t *testing.T) {
+	buf := []byte("event: ping\ndata: {}\n\n")
+	buf, err := AppendResponsesEventSSE(buf, &ResponsesStreamEvent{Type: "response.output_text.delta", Delta: "a"})
+	require.NoError(t, err)
+	assert.Equal(t, "event: ping\ndata: {}\n\nevent: response.output_text.delta\ndata: {\"type\":\"response.output_text.delta\",\"delta\":\"a\"}\n\n", string(buf))
+}
+
+// 事件结构体新增字段时，扁平快速路径（编码与解码）必须同步更新
+func TestSSECodecFastPathCoversStructFields(t *testing.T) {
+	fieldNames := func(v any) []string {
+		typ := reflect.TypeOf(v)
+		names := make([]string, 0, typ.NumField())
+		for i := 0; i < typ.NumField(); i++ {
+			names = append(names, typ.Field(i).Name)
+		}
+		return names
+	}
+	assert.Equal(t, []string{"Type", "Message", "Index", "ContentBlock", "Delta", "Usage"}, fieldNames(AnthropicStreamEvent{}))
+	assert.Equal(t, []string{"Type", "Text", "PartialJSON", "Thinking", "Signature", "StopReason", "StopSequence"}, fieldNames(AnthropicDelta{}))
+	assert.Equal(t, []string{
+		"Type", "Response", "Item", "OutputIndex", "ContentIndex", "Delta", "Text", "ItemID", "Logprobs",
+		"CallID", "Name", "Arguments", "SummaryIndex", "Annotation", "AnnotationIndex", "Usage", "Code", "Param", "SequenceNumber",
+	}, fieldNames(ResponsesStreamEvent{}))
+}
+
+func TestParseResponsesStreamEventMatchesUnmarshal(t *testing.T) {
+	payloads := []string{
+		`{"type":"response.output_text.delta","item_id":"msg_1","output_index":0,"content_index":0,"delta":"Hi é\n","logprobs":[],"sequence_number":4}`,
+		`{"type":"response.function_call_arguments.delta","item_id":"fc_1","output_index":1,"delta":"{\"a\":"}`,
+		`{"type":"response.reasoning_summary_text.delta","summary_index":2,"delta":"x","obfuscation":"abc"}`,
+		`{"type":"response.output_text.delta","delta":null,"response":null}`,
+		`{"type":"response.created","response":{"id":"resp_1","object":"response","status":"in_progress","output":[]}}`,
+		`{"type":"response.output_item.added","output_index":0,"item":{"type":"message","id":"msg_1","role":"assistant"}}`,
+		`{"type":"response.output_text.delta","delta":"x","logprobs":[{"token":"x","logprob":-0.1}]}`,
+		`{"type":"error","code":"rate_limit","message":"slow down"}`,
+		`{"type":"response.output_text.annotation.added","item_id":"msg_1","annotation_index":1,"annotation":{"type":"url_citation","url":"https://example.com","start_index":0,"end_index":4}}`,
+		`{"type":"response.code_interpreter_call_code.delta","item_id":"ci_1","output_index":2,"delta":"print("}`,
+	}
+	for _, payload := range payloads {
+		var want, got ResponsesStreamEvent
+		require.NoError(t, json.Unmarshal([]byte(payload), &want))
+		require.NoError(t, ParseResponsesStreamEvent([]byte(payload), &got))
+		assert.Equal(t, want, got, payload)
+	}
+
+	var evt ResponsesStreamEvent
+	assert.Error(t, ParseResponsesStreamEvent([]byte(`{"type":"x","output_index":"1"}`), &evt))
+	assert.Error(t, ParseResponsesStreamEvent([]byte(`not json`), &evt))
+}
+
+func TestParseAnthropicStreamEventMatchesUnmarshal(t *testing.T) {
+	payloads := []string{
+		`{"type":"content_block_delta","index":0,"delta":{"type":"text_delta","text":"Hello \"world\""}}`,
+		`{"type":"content_block_delta","index":1,"delta":{"type":"input_json_delta","partial_json":"{\"q\":"}}`,
+		`{"type":"content_block_delta","index":0,"delta":{"type":"signature_delta","signature":"abc"}}`,
+		`{"type":"content_block_stop","index":2}`,
+		`{"type":"message_stop"}`,
+		`{"type":"ping"}`,
+		`{"type":"message_delta","delta":{"stop_reason":"end_turn","stop_sequence":null},"usage":{"output_tokens":12}}`,
+		`{"type":"message_delta","delta":{"stop_reason":"stop_sequence","stop_sequence":"END"}}`,
+		`{"type":"message_start","message":{"id":"msg_1","type":"message","role":"assistant","content":[],"model":"claude","usage":{"input_tokens":5,"output_tokens":1}}}`,
+		`{"type":"content_block_start","index":0,"content_block":{"type":"text","text":""}}`,
+	}
+	for _, payload := range payloads {
+		var want, got AnthropicStreamEvent
+		require.NoError(t, json.Unmarshal([]byte(payload), &want))
+		require.NoError(t, ParseAnthropicStreamEvent([]byte(payload), &got))
+		assert.Equal(t, want, got, payload)
+	}
+
+	var evt AnthropicStreamEvent
+	assert.Error(t, ParseAnthropicStreamEvent([]byte(`{"type":"x","delta":"oops"}`), &evt))
+}
@@ -0,0 +1,339 @@
+package apicompat
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"math"
+	"strings"
+)
+
+// ---------------------------------------------------------------------------
+// Structured output translation
+//
+//	Chat Completions  response_format {"type":"json_schema","json_schema":{name,schema,strict}}
+//	Responses         text.format     {"type":"json_schema","name","schema","strict"}
+//	Anthropic         output_config.format {"type":"json_schema","schema"}
+//	                  (legacy beta: top-level output_format, accepted on input)
+//
+// Anthropic has no json_object mode; it is emulated with a system
+// instruction. Anthropic structured outputs are always strict.
+// ---------------------------------------------------------------------------
+
+// Responses text.format types.
+const (
+	TextFormatText       = "text"
+	TextFormatJSONObject = "json_object"
+	TextFormatJSONSchema = "json_schema"
+)
+
+// jsonObjectInstruction is appended to the Anthropic system prompt when a
+// client asks for json_object output.
+const jsonObjectInstruction = "Respond only with a single valid JSON object. Do not wrap it in markdown code fences or add any other text."
+
+// defaultStructuredOutputName is used when a schema arrives without a name
+// (Anthropic output_config.format has none; Responses requires one).
+const defaultStructuredOutputName = "output"
+
+// ResponsesTextFormat is the parsed Responses text.format object.
+type ResponsesTextFormat struct {
+	Type        string          `json:"type"`
+	Name        string          `json:"name,omitempty"`
+	Description string          `json:"description,omitempty"`
+	Schema      json.RawMessage `json:"schema,omitempty"`
+	Strict      *bool           `json:"strict,omitempty"`
+}
+
+// ParseResponsesTextFormat extracts text.format from a Responses text field.
+// It returns nil when no format is set.
+func ParseResponsesTextFormat(text json.RawMessage) (*ResponsesTextFormat, error) {
+	if len(text) == 0 {
+		return nil, nil
+	}
+	var wrapper struct {
+		Format *ResponsesTextFormat `json:"format"`
+	}
+	if err := json.Unmarshal(text, &wrapper); err != nil {
+		return nil, fmt.Errorf("parse text.format: %w", err)
+	}
+	return wrapper.Format, nil
+}
+
+// RequiresJSON reports whether the format constrains the output to JSON.
+func (f *ResponsesTextFormat) RequiresJSON() bool {
+	return f != nil && (f.Type == TextFormatJSONObject || f.Type == TextFormatJSONSchema)
+}
+
+// convertResponsesTextFormatToAnthropic applies a Responses text.format to
+// an Anthropic request: json_schema → output_config.format, json_object →
+// system instruction.
+func convertResponsesTextFormatToAnthropic(text json.RawMessage, out *AnthropicRequest) error {
+	format, err := ParseResponsesTextFormat(text)
+	if err != nil || format == nil {
+		return err
+	}
+	switch format.Type {
+	case TextFormatText, "":
+		return nil
+	case TextFormatJSONObject:
+		out.System = appendAnthropicSystemText(out.System, jsonObjectInstruction)
+		return nil
+	case TextFormatJSONSchema:
+		if len(format.Schema) == 0 {
+			return fmt.Errorf("text.format.schema is required for json_schema")
+		}
+		if out.OutputConfig == nil {
+			out.OutputConfig = &AnthropicOutputConfig{}
+		}
+		out.OutputConfig.Format = &AnthropicOutputFormat{Type: TextFormatJSONSchema, Schema: format.Schema}
+		return nil
+	default:
+		return fmt.Errorf("unsupported text.format type %q", format.Type)
+	}
+}
+
+// convertAnthropicOutputFormatToResponses maps output_config.format (or the
+// legacy top-level output_format) to a Responses text field.
+func convertAnthropicOutputFormatToResponses(req *AnthropicRequest) (json.RawMessage, error) {
+	format := req.OutputFormat
+	if req.OutputConfig != nil && req.OutputConfig.Format != nil {
+		format = req.OutputConfig.Format
+	}
+	if format == nil {
+		return nil, nil
+	}
+	if format.Type != TextFormatJSONSchema {
+		return nil, fmt.Errorf("unsupported output_format type %q", format.Type)
+	}
+	strict := true
+	return json.Marshal(map[string]any{"format": ResponsesTextFormat{
+		Type:   TextFormatJSONSchema,
+		Name:   defaultStructuredOutputName,
+		Schema: format.Schema,
+		Strict: &strict,
+	}})
+}
+
+// appendAnthropicSystemText appends text to an Anthropic system prompt,
+// which may be a JSON string or an array of text blocks.
+func appendAnthropicSystemText(system json.RawMessage, text string) json.RawMessage {
+	if len(system) == 0 {
+		out, _ := json.Marshal(text)
+		return out
+	}
+	var blocks []AnthropicContentBlock
+	if err := json.Unmarshal(system, &blocks); err == nil {
+		blocks = append(blocks, AnthropicContentBlock{Type: "text", Text: text})
+		out, _ := json.Marshal(blocks)
+		return out
+	}
+	existing, _ := parseAnthropicSystemPrompt(system)
+	out, _ := json.Marshal(existing + "\n\n" + text)
+	return out
+}
+
+// ResponsesOutputText concatenates the output_text parts of all message items.
+func ResponsesOutputText(resp *ResponsesResponse) string {
+	if resp == nil {
+		return ""
+	}
+	var sb strings.Builder
+	for _, item := range resp.Output {
+		if item.Type != "message" {
+			continue
+		}
+		for _, part := range item.Content {
+			if part.Type == "output_text" {
+				sb.WriteString(part.Text)
+			}
+		}
+	}
+	return sb.String()
+}
+
+// ValidateStructuredOutput checks that output is valid JSON and, for
+// json_schema formats, that it conforms to the schema. The schema check
+// covers the subset used by structured outputs: type, properties, required,
+// additionalProperties, items, enum, const and anyOf.
+func ValidateStructuredOutput(output string, format *ResponsesTextFormat) error {
+	if !format.RequiresJSON() {
+		return nil
+	}
+	dec := json.NewDecoder(strings.NewReader(strings.TrimSpace(output)))
+	dec.UseNumber()
+	var value any
+	if err := dec.Decode(&value); err != nil {
+		return fmt.Errorf("output is not valid JSON: %w", err)
+	}
+	if dec.More() {
+		return fmt.Errorf("output contains trailing data after the JSON value")
+	}
+	if format.Type == TextFormatJSONObject {
+		if _, ok := value.(map[string]any); !ok {
+			return fmt.Errorf("output is not a JSON object")
+		}
+		return nil
+	}
+	var schema any
+	if err := json.Unmarshal(format.Schema, &schema); err != nil {
+		return fmt.Errorf("parse schema: %w", err)
+	}
+	return validateJSONSchemaValue(value, schema, "$")
+}
+
+func validateJSONSchemaValue(value any, schemaAny any, path string) error {
+	schema, ok := schemaAny.(map[string]any)
+	if !ok {
+		// true / {} / unsupported schema forms accept anything
+		return nil
+	}
+
+	if anyOf, ok := schema["anyOf"].([]any); ok && len(anyOf) > 0 {
+		var firstErr error
+		for _, sub := range anyOf {
+			err := validateJSONSchemaValue(value, sub, path)
+			if err == nil {
+				firstErr = nil
+				break
+			}
+			if firstErr == nil {
+				firstErr = err
+			}
+		}
+		if firstErr != nil {
+			return fmt.Errorf("%s: does not match any anyOf schema: %w", path, firstErr)
+		}
+	}
+
+	if c, ok := schema["const"]; ok && !jsonValuesEqual(value, c) {
+		return fmt.Errorf("%s: must equal const value", path)
+	}
+	if enum, ok := schema["enum"].([]any); ok {
+		matched := false
+		for _, e := range enum {
+			if jsonValuesEqual(value, e) {
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			return fmt.Errorf("%s: not one of the enum values", path)
+		}
+	}
+
+	if t, ok := schema["type"]; ok && !jsonTypeMatches(value, t) {
+		return fmt.Errorf("%s: expected type %v", path, t)
+	}
+
+	switch v := value.(type) {
+	case map[string]any:
+		props, _ := schema["properties"].(map[string]any)
+		if required, ok := schema["required"].([]any); ok {
+			for _, r := range required {
+				name, _ := r.(string)
+				if _, present := v[name]; name != "" && !present {
+					return fmt.Errorf("%s: missing required property %q", path, name)
+				}
+			}
+		}
+		for key, child := range v {
+			if sub, ok := props[key]; ok {
+				if err := validateJSONSchemaValue(child, sub, path+"."+key); err != nil {
+					return err
+				}
+				continue
+			}
+			switch ap := schema["additionalProperties"].(type) {
+			case bool:
+				if !ap {
+					return fmt.Errorf("%s: unexpected property %q", path, key)
+				}
+			case map[string]any:
+				if err := validateJSONSchemaValue(child, ap, path+"."+key); err != nil {
+					return err
+				}
+			}
+		}
+	case []any:
+		if items, ok := schema["items"]; ok {
+			for i, child := range v {
+				if err := validateJSONSchemaValue(child, items, fmt.Sprintf("%s[%d]", path, i)); err != nil {
+					return err
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// jsonTypeMatches checks a decoded value against a schema "type", which may
+// be a string or an array of strings.
+func jsonTypeMatches(value any, t any) bool {
+	switch tt := t.(type) {
+	case string:
+		return jsonValueHasType(value, tt)
+	case []any:
+		for _, item := range tt {
+			if s, ok := item.(string); ok && jsonValueHasType(value, s) {
+				return true
+			}
+		}
+		return false
+	default:
+		return true
+	}
+}
+
+func jsonValueHasType(value any, t string) bool {
+	switch t {
+	case "object":
+		_, ok := value.(map[string]any)
+		return ok
+	case "array":
+		_, ok := value.([]any)
+		return ok
+	case "string":
+		_, ok := value.(string)
+		return ok
+	case "boolean":
+		_, ok := value.(bool)
+		return ok
+	case "null":
+		return value == nil
+	case "number":
+		_, ok := value.(json.Number)
+		return ok
+	case "integer":
+		n, ok := value.(json.Number)
+		if !ok {
+			return false
+		}
+		f, err := n.Float64()
+		return err == nil && f == math.Trunc(f)
+	default:
+		return true
+	}
+}
+
+func jsonValuesEqual(a, b any) bool {
+	ab, errA := json.Marshal(a)
+	bb, errB := json.Marshal(b)
+	if errA != nil || errB != nil {
+		return false
+	}
+	return bytes.Equal(normalizeJSONNumberText(ab), normalizeJSONNumberText(bb))
+}
+
+// normalizeJSONNumberText re-encodes through float64 so that 1 and 1.0
+// compare equal.
+func normalizeJSONNumberText(raw []byte) []byte {
+	var v any
+	if err := json.Unmarshal(raw, &v); err != nil {
+		return raw
+	}
+	out, err := json.Marshal(v)
+	if err != nil {
+		return raw
+	}
+	return out
+}
@@ -0,0 +1,136 @@
+package apicompat
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// ---------------------------------------------------------------------------
+// Structured output mapping tests
+// ---------------------------------------------------------------------------
+
+func TestResponsesToAnthropicRequest_JSONSchemaFormat(t *testing.T) {
+	req := &ResponsesRequest{
+		Model: "claude-sonnet-4-5",
+		Input: json.RawMessage(`"Extract the city"`),
+		Text:  json.RawMessage(`{"format":{"type":"json_schema","name":"city","strict":true,"schema":{"type":"object","properties":{"city":{"type":"string"}},"required":["city"],"additionalProperties":false}}}`),
+		Reasoning: &ResponsesReasoning{
+			Effort: "low",
+		},
+	}
+
+	out, err := ResponsesToAnthropicRequest(req)
+	require.NoError(t, err)
+	require.NotNil(t, out.OutputConfig)
+	assert.Equal(t, "low", out.OutputConfig.Effort)
+	require.NotNil(t, out.OutputConfig.Format)
+	assert.Equal(t, "json_schema", out.OutputConfig.Format.Type)
+	assert.JSONEq(t, `{"type":"object","properties":{"city":{"type":"string"}},"required":["city"],"additionalProperties":false}`, string(out.OutputConfig.Format.Schema))
+
+	body, err := json.Marshal(out)
+	require.NoError(t, err)
+	assert.NotContains(t, string(body), `"output_format"`)
+}
+
+func TestResponsesToAnthropicRequest_JSONObjectFormat(t *testing.T) {
+	req := &ResponsesRequest{
+		Model:        "claude-sonnet-4-5",
+		Instructions: "You are terse.",
+		Input:        json.RawMessage(`[{"role":"system","content":"Use metric units."},{"role":"user","content":"Weather?"}]`),
+		Text:         json.RawMessage(`{"format":{"type":"json_object"}}`),
+	}
+
+	out, err := ResponsesToAnthropicRequest(req)
+	require.NoError(t, err)
+	assert.Nil(t, out.OutputConfig)
+
+	var system string
+	require.NoError(t, json.Unmarshal(out.System, &system))
+	assert.Equal(t, "You are terse.\n\nUse metric units.\n\n"+jsonObjectInstruction, system)
+}
+
+func TestResponsesToAnthropicRequest_InvalidTextFormat(t *testing.T) {
+	_, err := ResponsesToAnthropicRequest(&ResponsesRequest{
+		Model: "claude-sonnet-4-5",
+		Input: json.RawMessage(`"hi"`),
+		Text:  json.RawMessage(`{"format":{"type":"json_schema","name":"x"}}`),
+	})
+	require.Error(t, err)
+}
+
+func TestAnthropicToResponses_OutputFormat(t *testing.T) {
+	for _, req := range []*AnthropicRequest{
+		{OutputConfig: &AnthropicOutputConfig{Format: &AnthropicOutputFormat{Type: "json_schema", Schema: json.RawMessage(`{"type":"object"}`)}}},
+		{OutputFormat: &AnthropicOutputFormat{Type: "json_schema", Schema: json.RawMessage(`{"type":"object"}`)}},
+	} {
+		req.Model = "gpt-5.2"
+		req.MaxTokens = 1024
+		req.Messages = []AnthropicMessage{{Role: "user", Content: json.RawMessage(`"Hello"`)}}
+
+		resp, err := AnthropicToResponses(req)
+		require.NoError(t, err)
+		assert.JSONEq(t, `{"format":{"type":"json_schema","name":"output","schema":{"type":"object"},"strict":true}}`, string(resp.Text))
+	}
+}
+
+// ---------------------------------------------------------------------------
+// ValidateStructuredOutput tests
+// ---------------------------------------------------------------------------
+
+func TestValidateStructuredOutput(t *testing.T) {
+	schema := json.RawMessage(`{
+		"type":"object",
+		"properties":{
+			"city":{"type":"string"},
+			"temp":{"type":"integer"},
+			"unit":{"type":"string","enum":["c","f"]},
+			"tags":{"type":"array","items":{"type":"string"}},
+			"note":{"anyOf":[{"type":"string"},{"type":"null"}]}
+		},
+		"required":["city","temp"],
+		"additionalProperties":false
+	}`)
+	format := &ResponsesTextFormat{Type: TextFormatJSONSchema, Name: "weather", Schema: schema}
+
+	tests := []struct {
+		name   string
+		output string
+		valid  bool
+	}{
+		{name: "valid", output: `{"city":"Paris","temp":21,"unit":"c","tags":["sunny"],"note":null}`, valid: true},
+		{name: "surrounding whitespace", output: "\n  {\"city\":\"Paris\",\"temp\":21}  \n", valid: true},
+		{name: "not json", output: `The weather is nice`},
+		{name: "truncated", output: `{"city":"Par`},
+		{name: "trailing data", output: `{"city":"Paris","temp":21} {}`},
+		{name: "missing required", output: `{"city":"Paris"}`},
+		{name: "wrong type", output: `{"city":"Paris","temp":"21"}`},
+		{name: "non-integer", output: `{"city":"Paris","temp":21.5}`},
+		{name: "enum mismatch", output: `{"city":"Paris","temp":21,"unit":"k"}`},
+		{name: "array item type", output: `{"city":"Paris","temp":21,"tags":[1]}`},
+		{name: "anyOf mismatch", output: `{"city":"Paris","temp":21,"note":3}`},
+		{name: "additional property", output: `{"city":"Paris","temp":21,"wind":3}`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateStructuredOutput(tt.output, format)
+			if tt.valid {
+				assert.NoError(t, err)
+			} else {
+				assert.Error(t, err)
+			}
+		})
+	}
+}
+
+func TestValidateStructuredOutput_JSONObjectAndText(t *testing.T) {
+	jsonObject := &ResponsesTextFormat{Type: TextFormatJSONObject}
+	assert.NoError(t, ValidateStructuredOutput(`{"a":1}`, jsonObject))
+	assert.Error(t, ValidateStructuredOutput(`[1,2]`, jsonObject))
+	assert.Error(t, ValidateStructuredOutput("```json\n{}\n```", jsonObject))
+
+	assert.NoError(t, ValidateStructuredOutput("plain text", &ResponsesTextFormat{Type: TextFormatText}))
+	assert.NoError(t, ValidateStructuredOutput("plain text", nil))
+}
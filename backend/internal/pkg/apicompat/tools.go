@@ -0,0 +1,152 @@
+package apicompat
+
+import (
+	"encoding/json"
+	"strings"
+)
+
+// ---------------------------------------------------------------------------
+// Tool choice translation
+//
+// Every protocol expresses the same four tool_choice modes differently:
+//
+//	mode      Responses / Chat               Anthropic                 Gemini functionCallingConfig
+//	auto      "auto"                         {"type":"auto"}           {"mode":"AUTO"}
+//	none      "none"                         {"type":"none"}           {"mode":"NONE"}
+//	required  "required"                     {"type":"any"}            {"mode":"ANY"}
+//	function  {"type":"function","name":X}   {"type":"tool","name":X}  {"mode":"ANY","allowedFunctionNames":[X]}
+//
+// Chat Completions nests the function name ({"type":"function","function":
+// {"name":X}}); Responses uses the flat form. The converters in this package
+// parse into ToolChoice and render the target protocol from it so that every
+// direction agrees on the mapping.
+// ---------------------------------------------------------------------------
+
+// Tool choice modes.
+const (
+	ToolChoiceAuto     = "auto"
+	ToolChoiceNone     = "none"
+	ToolChoiceRequired = "required"
+	ToolChoiceFunction = "function"
+)
+
+// ToolChoice is the protocol-neutral form of a tool_choice setting.
+// Name is only set when Mode is ToolChoiceFunction.
+type ToolChoice struct {
+	Mode string
+	Name string
+}
+
+// ParseResponsesToolChoice parses a Responses or Chat Completions tool_choice
+// (string, flat or nested function object). ok is false for values that have
+// no cross-protocol equivalent (e.g. hosted tool selectors), which callers
+// should pass through unchanged.
+func ParseResponsesToolChoice(raw json.RawMessage) (ToolChoice, bool) {
+	var s string
+	if err := json.Unmarshal(raw, &s); err == nil {
+		switch s {
+		case ToolChoiceAuto, ToolChoiceNone, ToolChoiceRequired:
+			return ToolChoice{Mode: s}, true
+		}
+		return ToolChoice{}, false
+	}
+
+	var obj struct {
+		Type     string `json:"type"`
+		Name     string `json:"name"`
+		Function *struct {
+			Name string `json:"name"`
+		} `json:"function"`
+	}
+	if err := json.Unmarshal(raw, &obj); err != nil || obj.Type != "function" {
+		return ToolChoice{}, false
+	}
+	name := obj.Name
+	if obj.Function != nil && obj.Function.Name != "" {
+		name = obj.Function.Name
+	}
+	if name == "" {
+		return ToolChoice{}, false
+	}
+	return ToolChoice{Mode: ToolChoiceFunction, Name: name}, true
+}
+
+// ParseAnthropicToolChoice parses an Anthropic tool_choice object.
+// disable_parallel_tool_use is handled separately by the callers.
+func ParseAnthropicToolChoice(raw json.RawMessage) (ToolChoice, bool) {
+	var tc struct {
+		Type string `json:"type"`
+		Name string `json:"name"`
+	}
+	if err := json.Unmarshal(raw, &tc); err != nil {
+		return ToolChoice{}, false
+	}
+	switch tc.Type {
+	case "auto":
+		return ToolChoice{Mode: ToolChoiceAuto}, true
+	case "none":
+		return ToolChoice{Mode: ToolChoiceNone}, true
+	case "any":
+		return ToolChoice{Mode: ToolChoiceRequired}, true
+	case "tool":
+		if tc.Name != "" {
+			return ToolChoice{Mode: ToolChoiceFunction, Name: tc.Name}, true
+		}
+	}
+	return ToolChoice{}, false
+}
+
+// ParseGeminiToolChoice parses a Gemini functionCallingConfig. An unspecified
+// mode yields ok=false (no tool_choice). ANY with exactly one allowed function
+// name selects that function.
+func ParseGeminiToolChoice(cfg *GeminiFunctionCallingConfig) (ToolChoice, bool) {
+	if cfg == nil {
+		return ToolChoice{}, false
+	}
+	switch strings.ToUpper(strings.TrimSpace(cfg.Mode)) {
+	case "AUTO":
+		return ToolChoice{Mode: ToolChoiceAuto}, true
+	case "NONE":
+		return ToolChoice{Mode: ToolChoiceNone}, true
+	case "ANY", "VALIDATED":
+		if len(cfg.AllowedFunctionNames) == 1 {
+			return ToolChoice{Mode: ToolChoiceFunction, Name: cfg.AllowedFunctionNames[0]}, true
+		}
+		return ToolChoice{Mode: ToolChoiceRequired}, true
+	}
+	return ToolChoice{}, false
+}
+
+// ResponsesJSON renders the Responses API form (flat function object).
+func (tc ToolChoice) ResponsesJSON() (json.RawMessage, error) {
+	if tc.Mode == ToolChoiceFunction {
+		return json.Marshal(map[string]string{"type": "function", "name": tc.Name})
+	}
+	return json.Marshal(tc.Mode)
+}
+
+// AnthropicJSON renders the Anthropic tool_choice object.
+func (tc ToolChoice) AnthropicJSON() (json.RawMessage, error) {
+	switch tc.Mode {
+	case ToolChoiceFunction:
+		return json.Marshal(map[string]string{"type": "tool", "name": tc.Name})
+	case ToolChoiceRequired:
+		return json.Marshal(map[string]string{"type": "any"})
+	default:
+		return json.Marshal(map[string]string{"type": tc.Mode})
+	}
+}
+
+// GeminiConfig renders the Gemini functionCallingConfig.
+func (tc ToolChoice) GeminiConfig() *GeminiFunctionCallingConfig {
+	switch tc.Mode {
+	case ToolChoiceFunction:
+		return &GeminiFunctionCallingConfig{Mode: "ANY", AllowedFunctionNames: []string{tc.Name}}
+	case ToolChoiceRequired:
+		return &GeminiFunctionCallingConfig{Mode: "ANY"}
+	case ToolChoiceNone:
+		return &GeminiFunctionCallingConfig{Mode: "NONE"}
+	default:
+		return &GeminiFunctionCallingConfig{Mode: "AUTO"}
+	}
+}
@@ -0,0 +1,200 @@
+package apicompat
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// ---------------------------------------------------------------------------
+// ToolChoice translation tests
+// ---------------------------------------------------------------------------
+
+func TestParseResponsesToolChoice(t *testing.T) {
+	tests := []struct {
+		raw  string
+		want ToolChoice
+		ok   bool
+	}{
+		{raw: `"auto"`, want: ToolChoice{Mode: ToolChoiceAuto}, ok: true},
+		{raw: `"none"`, want: ToolChoice{Mode: ToolChoiceNone}, ok: true},
+		{raw: `"required"`, want: ToolChoice{Mode: ToolChoiceRequired}, ok: true},
+		{raw: `{"type":"function","name":"get_weather"}`, want: ToolChoice{Mode: ToolChoiceFunction, Name: "get_weather"}, ok: true},
+		{raw: `{"type":"function","function":{"name":"get_weather"}}`, want: ToolChoice{Mode: ToolChoiceFunction, Name: "get_weather"}, ok: true},
+		{raw: `{"type":"function"}`},
+		{raw: `{"type":"web_search_preview"}`},
+		{raw: `"bogus"`},
+	}
+	for _, tt := range tests {
+		got, ok := ParseResponsesToolChoice(json.RawMessage(tt.raw))
+		assert.Equal(t, tt.ok, ok, tt.raw)
+		assert.Equal(t, tt.want, got, tt.raw)
+	}
+}
+
+func TestToolChoice_CrossProtocol(t *testing.T) {
+	tests := []struct {
+		name      string
+		tc        ToolChoice
+		responses string
+		anthropic string
+		gemini    GeminiFunctionCallingConfig
+	}{
+		{
+			name:      "auto",
+			tc:        ToolChoice{Mode: ToolChoiceAuto},
+			responses: `"auto"`,
+			anthropic: `{"type":"auto"}`,
+			gemini:    GeminiFunctionCallingConfig{Mode: "AUTO"},
+		},
+		{
+			name:      "none",
+			tc:        ToolChoice{Mode: ToolChoiceNone},
+			responses: `"none"`,
+			anthropic: `{"type":"none"}`,
+			gemini:    GeminiFunctionCallingConfig{Mode: "NONE"},
+		},
+		{
+			name:      "required",
+			tc:        ToolChoice{Mode: ToolChoiceRequired},
+			responses: `"required"`,
+			anthropic: `{"type":"any"}`,
+			gemini:    GeminiFunctionCallingConfig{Mode: "ANY"},
+		},
+		{
+			name:      "function",
+			tc:        ToolChoice{Mode: ToolChoiceFunction, Name: "get_weather"},
+			responses: `{"type":"function","name":"get_weather"}`,
+			anthropic: `{"type":"tool","name":"get_weather"}`,
+			gemini:    GeminiFunctionCallingConfig{Mode: "ANY", AllowedFunctionNames: []string{"get_weather"}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			responses, err := tt.tc.ResponsesJSON()
+			require.NoError(t, err)
+			assert.JSONEq(t, tt.responses, string(responses))
+
+			anthropic, err := tt.tc.AnthropicJSON()
+			require.NoError(t, err)
+			assert.JSONEq(t, tt.anthropic, string(anthropic))
+			assert.Equal(t, &tt.gemini, tt.tc.GeminiConfig())
+
+			// Every rendered form parses back to the same ToolChoice.
+			fromResponses, ok := ParseResponsesToolChoice(responses)
+			require.True(t, ok)
+			assert.Equal(t, tt.tc, fromResponses)
+			fromAnthropic, ok := ParseAnthropicToolChoice(anthropic)
+			require.True(t, ok)
+			assert.Equal(t, tt.tc, fromAnthropic)
+			fromGemini, ok := ParseGeminiToolChoice(tt.tc.GeminiConfig())
+			require.True(t, ok)
+			assert.Equal(t, tt.tc, fromGemini)
+		})
+	}
+}
+
+func TestParseGeminiToolChoice_Unspecified(t *testing.T) {
+	_, ok := ParseGeminiToolChoice(nil)
+	assert.False(t, ok)
+	_, ok = ParseGeminiToolChoice(&GeminiFunctionCallingConfig{Mode: "MODE_UNSPECIFIED"})
+	assert.False(t, ok)
+}
+
+// ---------------------------------------------------------------------------
+// Cross-protocol tool call round trips
+// ---------------------------------------------------------------------------
+
+func TestGeminiToAnthropic_ToolsViaResponses(t *testing.T) {
+	gemReq := &GeminiRequest{
+		Contents: []GeminiContent{
+			{Role: "user", Parts: []GeminiPart{{Text: "weather in Paris?"}}},
+			{Role: "model", Parts: []GeminiPart{{FunctionCall: &GeminiFunctionCall{Name: "get_weather", Args: json.RawMessage(`{"city":"Paris"}`)}}}},
+			{Role: "user", Parts: []GeminiPart{{FunctionResponse: &GeminiFunctionResponse{Name: "get_weather", Response: json.RawMessage(`{"temp":21}`)}}}},
+		},
+		Tools: []GeminiTool{{FunctionDeclarations: []GeminiFunctionDeclaration{{
+			Name:       "get_weather",
+			Parameters: json.RawMessage(`{"type":"OBJECT","properties":{"city":{"type":"STRING"}}}`),
+		}}}},
+		ToolConfig: &GeminiToolConfig{FunctionCallingConfig: &GeminiFunctionCallingConfig{Mode: "ANY", AllowedFunctionNames: []string{"get_weather"}}},
+	}
+
+	respReq, err := GeminiToResponses(gemReq, "claude-sonnet-4-5")
+	require.NoError(t, err)
+	anthReq, err := ResponsesToAnthropicRequest(respReq)
+	require.NoError(t, err)
+
+	require.Len(t, anthReq.Tools, 1)
+	assert.Equal(t, "get_weather", anthReq.Tools[0].Name)
+	assert.JSONEq(t, `{"type":"object","properties":{"city":{"type":"string"}}}`, string(anthReq.Tools[0].InputSchema))
+	assert.JSONEq(t, `{"type":"tool","name":"get_weather"}`, string(anthReq.ToolChoice))
+
+	var toolUseID string
+	var sawResult bool
+	for _, msg := range anthReq.Messages {
+		var blocks []AnthropicContentBlock
+		if err := json.Unmarshal(msg.Content, &blocks); err != nil {
+			continue
+		}
+		for _, b := range blocks {
+			switch b.Type {
+			case "tool_use":
+				toolUseID = b.ID
+				assert.Equal(t, "get_weather", b.Name)
+				assert.JSONEq(t, `{"city":"Paris"}`, string(b.Input))
+			case "tool_result":
+				sawResult = true
+				assert.Equal(t, toolUseID, b.ToolUseID)
+			}
+		}
+	}
+	assert.NotEmpty(t, toolUseID)
+	assert.True(t, sawResult)
+}
+
+func TestAnthropicStream_ToolCallDeltasToGemini(t *testing.T) {
+	// Anthropic upstream stream → Responses events → Gemini chunks: partial
+	// input_json_delta fragments are reassembled into one functionCall.
+	idx := 0
+	anthropicEvents := []AnthropicStreamEvent{
+		{Type: "message_start", Message: &AnthropicResponse{ID: "msg_1", Type: "message", Role: "assistant", Model: "claude"}},
+		{Type: "content_block_start", Index: &idx, ContentBlock: &AnthropicContentBlock{Type: "tool_use", ID: "toolu_1", Name: "get_weather", Input: json.RawMessage(`{}`)}},
+		{Type: "content_block_delta", Index: &idx, Delta: &AnthropicDelta{Type: "input_json_delta", PartialJSON: `{"city":`}},
+		{Type: "content_block_delta", Index: &idx, Delta: &AnthropicDelta{Type: "input_json_delta", PartialJSON: `"Paris"}`}},
+		{Type: "content_block_stop", Index: &idx},
+		{Type: "message_delta", Delta: &AnthropicDelta{StopReason: "tool_use"}, Usage: &AnthropicUsage{OutputTokens: 5}},
+		{Type: "message_stop"},
+	}
+
+	respState := NewAnthropicEventToResponsesState()
+	gemState := NewResponsesEventToGeminiState()
+	var argDeltas []string
+	var chunks []GeminiResponse
+	for i := range anthropicEvents {
+		for _, evt := range AnthropicEventToResponsesEvents(&anthropicEvents[i], respState) {
+			if evt.Type == "response.function_call_arguments.delta" {
+				argDeltas = append(argDeltas, evt.Delta)
+			}
+			chunks = append(chunks, ResponsesEventToGeminiChunks(&evt, gemState)...)
+		}
+	}
+	chunks = append(chunks, FinalizeResponsesGeminiStream(gemState)...)
+
+	assert.Equal(t, []string{`{"city":`, `"Paris"}`}, argDeltas)
+
+	var call *GeminiFunctionCall
+	for _, chunk := range chunks {
+		for _, cand := range chunk.Candidates {
+			for _, part := range cand.Content.Parts {
+				if part.FunctionCall != nil {
+					call = part.FunctionCall
+				}
+			}
+		}
+	}
+	require.NotNil(t, call)
+	assert.Equal(t, "get_weather", call.Name)
+	assert.JSONEq(t, `{"city":"Paris"}`, string(call.Args))
+}
@@ -1,5 +1,6 @@
 // Package apicompat provides type definitions and conversion utilities for
-// translating between Anthropic Messages and OpenAI Responses API formats.
+// translating between Anthropic Messages, Gemini generateContent, Ollama and
+// OpenAI Responses API formats.
 // It enables multi-protocol support so that clients using different API
 // formats can be served through a unified gateway.
 package apicompat
@@ -20,15 +21,44 @@ type AnthropicRequest struct {
 	Stream       bool                   `json:"stream,omitempty"`
 	Temperature  *float64               `json:"temperature,omitempty"`
 	TopP         *float64               `json:"top_p,omitempty"`
+	TopK         *int                   `json:"top_k,omitempty"`
 	StopSeqs     []string               `json:"stop_sequences,omitempty"`
 	Thinking     *AnthropicThinking     `json:"thinking,omitempty"`
 	ToolChoice   json.RawMessage        `json:"tool_choice,omitempty"`
 	OutputConfig *AnthropicOutputConfig `json:"output_config,omitempty"`
+	// MCPServers are remote MCP servers the API connects to on the model's
+	// behalf (MCP connector, beta mcp-client-2025-04-04).
+	MCPServers []AnthropicMCPServer `json:"mcp_servers,omitempty"`
+	// OutputFormat is the legacy (beta) location of output_config.format;
+	// accepted from clients, never sent upstream.
+	OutputFormat *AnthropicOutputFormat `json:"output_format,omitempty"`
+}
+
+// AnthropicMCPServer is a remote MCP server definition for the MCP connector.
+type AnthropicMCPServer struct {
+	Type               string                         `json:"type"` // "url"
+	URL                string                         `json:"url"`
+	Name               string                         `json:"name"`
+	AuthorizationToken string                         `json:"authorization_token,omitempty"`
+	ToolConfiguration  *AnthropicMCPToolConfiguration `json:"tool_configuration,omitempty"`
+}
+
+// AnthropicMCPToolConfiguration restricts the tools exposed by an MCP server.
+type AnthropicMCPToolConfiguration struct {
+	Enabled      *bool    `json:"enabled,omitempty"`
+	AllowedTools []string `json:"allowed_tools,omitempty"`
 }
 
 // AnthropicOutputConfig controls output generation parameters.
 type AnthropicOutputConfig struct {
-	Effort string `json:"effort,omitempty"` // "low" | "medium" | "high"
+	Effort string                 `json:"effort,omitempty"` // "low" | "medium" | "high"
+	Format *AnthropicOutputFormat `json:"format,omitempty"` // structured output
+}
+
+// AnthropicOutputFormat constrains the response to a JSON schema.
+type AnthropicOutputFormat struct {
+	Type   string          `json:"type"` // "json_schema"
+	Schema json.RawMessage `json:"schema,omitempty"`
 }
 
 // AnthropicThinking configures extended thinking in the Anthropic API.
@@ -51,17 +81,20 @@ type AnthropicContentBlock struct {
 	Text string `json:"text,omitempty"`
 
 	// type=thinking
-	Thinking string `json:"thinking,omitempty"`
+	Thinking  string `json:"thinking,omitempty"`
+	Signature string `json:"signature,omitempty"`
 
 	// type=image
 	Source *AnthropicImageSource `json:"source,omitempty"`
 
-	// type=tool_use
+	// type=tool_use / mcp_tool_use
 	ID    string          `json:"id,omitempty"`
 	Name  string          `json:"name,omitempty"`
 	Input json.RawMessage `json:"input,omitempty"`
+	// type=mcp_tool_use: the mcp_servers entry that ran the tool
+	ServerName string `json:"server_name,omitempty"`
 
-	// type=tool_result
+	// type=tool_result / mcp_tool_result
 	ToolUseID string          `json:"tool_use_id,omitempty"`
 	Content   json.RawMessage `json:"content,omitempty"` // string or []AnthropicContentBlock
 	IsError   bool            `json:"is_error,omitempty"`
@@ -69,9 +102,10 @@ type AnthropicContentBlock struct {
 
 // AnthropicImageSource describes the source data for an image content block.
 type AnthropicImageSource struct {
-	Type      string `json:"type"` // "base64"
-	MediaType string `json:"media_type"`
-	Data      string `json:"data"`
+	Type      string `json:"type"` // "base64" | "url"
+	MediaType string `json:"media_type,omitempty"`
+	Data      string `json:"data,omitempty"`
+	URL       string `json:"url,omitempty"` // type=url
 }
 
 // AnthropicTool describes a tool available to the model.
@@ -151,18 +185,45 @@ type AnthropicDelta struct {
 
 // ResponsesRequest is the request body for POST /v1/responses.
 type ResponsesRequest struct {
-	Model           string              `json:"model"`
-	Input           json.RawMessage     `json:"input"` // string or []ResponsesInputItem
-	MaxOutputTokens *int                `json:"max_output_tokens,omitempty"`
-	Temperature     *float64            `json:"temperature,omitempty"`
-	TopP            *float64            `json:"top_p,omitempty"`
-	Stream          bool                `json:"stream,omitempty"`
-	Tools           []ResponsesTool     `json:"tools,omitempty"`
-	Include         []string            `json:"include,omitempty"`
-	Store           *bool               `json:"store,omitempty"`
-	Reasoning       *ResponsesReasoning `json:"reasoning,omitempty"`
-	ToolChoice      json.RawMessage     `json:"tool_choice,omitempty"`
-	ServiceTier     string              `json:"service_tier,omitempty"`
+	Model             string              `json:"model"`
+	Instructions      string              `json:"instructions,omitempty"`
+	Input             json.RawMessage     `json:"input"` // string or []ResponsesInputItem
+	MaxOutputTokens   *int                `json:"max_output_tokens,omitempty"`
+	Temperature       *float64            `json:"temperature,omitempty"`
+	TopP              *float64            `json:"top_p,omitempty"`
+	Stream            bool                `json:"stream,omitempty"`
+	Tools             []ResponsesTool     `json:"tools,omitempty"`
+	Include           []string            `json:"include,omitempty"`
+	Store             *bool               `json:"store,omitempty"`
+	Reasoning         *ResponsesReasoning `json:"reasoning,omitempty"`
+	ToolChoice        json.RawMessage     `json:"tool_choice,omitempty"`
+	ParallelToolCalls *bool               `json:"parallel_tool_calls,omitempty"`
+	ServiceTier       string              `json:"service_tier,omitempty"`
+	Text              json.RawMessage     `json:"text,omitempty"` // {"format": {...}} structured output config
+	TopLogprobs       *int                `json:"top_logprobs,omitempty"`
+
+	// StopSequences and TopK carry Chat Completions stop / top_k through the
+	// hub format. The Responses API rejects both, so they are never serialized;
+	// ResponsesToAnthropicRequest maps them to stop_sequences / top_k.
+	StopSequences []string `json:"-"`
+	TopK          *int     `json:"-"`
+	// DroppedParams lists client parameters the converter could not map to any
+	// upstream (e.g. logit_bias, presence_penalty), reported back to the client.
+	DroppedParams []string `json:"-"`
+}
+
+// DroppedForResponses returns the client parameters that are dropped when the
+// request is sent to a Responses API upstream: DroppedParams plus the hub-only
+// stop / top_k.
+func (r *ResponsesRequest) DroppedForResponses() []string {
+	dropped := append([]string(nil), r.DroppedParams...)
+	if len(r.StopSequences) > 0 {
+		dropped = append(dropped, "stop")
+	}
+	if r.TopK != nil {
+		dropped = append(dropped, "top_k")
+	}
+	return dropped
 }
 
 // ResponsesReasoning configures reasoning effort in the Responses API.
@@ -187,24 +248,106 @@ type ResponsesInputItem struct {
 	Arguments string `json:"arguments,omitempty"`
 	ID        string `json:"id,omitempty"`
 
-	// type=function_call_output
+	// type=function_call_output / mcp_call
 	Output string `json:"output,omitempty"`
+
+	// type=mcp_call / mcp_list_tools / mcp_approval_request
+	ServerLabel string `json:"server_label,omitempty"`
+	Error       string `json:"error,omitempty"`
+
+	// type=web_search_call / code_interpreter_call, replayed from earlier output
+	Status      string                  `json:"status,omitempty"`
+	Action      *WebSearchAction        `json:"action,omitempty"`
+	Code        string                  `json:"code,omitempty"`
+	ContainerID string                  `json:"container_id,omitempty"`
+	Outputs     []CodeInterpreterOutput `json:"outputs,omitempty"`
+
+	// type=reasoning. Summary is a []ResponsesSummary kept raw because the
+	// Responses API requires the field on reasoning items even when empty.
+	EncryptedContent string          `json:"encrypted_content,omitempty"`
+	Summary          json.RawMessage `json:"summary,omitempty"`
 }
 
 // ResponsesContentPart is a typed content part in a Responses message.
 type ResponsesContentPart struct {
-	Type     string `json:"type"` // "input_text" | "output_text" | "input_image"
+	Type     string `json:"type"` // "input_text" | "output_text" | "input_image" | "input_file"
 	Text     string `json:"text,omitempty"`
-	ImageURL string `json:"image_url,omitempty"` // data URI for input_image
+	ImageURL string `json:"image_url,omitempty"` // data URI or http(s) URL for input_image
+	Detail   string `json:"detail,omitempty"`    // input_image only: "auto" | "low" | "high"
+
+	// input_file only
+	FileID   string `json:"file_id,omitempty"`
+	FileData string `json:"file_data,omitempty"` // data URI
+	Filename string `json:"filename,omitempty"`
+
+	// output_text only, when include contains "message.output_text.logprobs"
+	Logprobs []ResponsesLogprob `json:"logprobs,omitempty"`
+	// output_text only: citations from web_search / file_search / code_interpreter
+	Annotations []ResponsesAnnotation `json:"annotations,omitempty"`
+}
+
+// ResponsesAnnotation is a citation attached to output_text.
+type ResponsesAnnotation struct {
+	Type       string `json:"type"` // "url_citation" | "file_citation" | "container_file_citation"
+	URL        string `json:"url,omitempty"`
+	Title      string `json:"title,omitempty"`
+	StartIndex int    `json:"start_index"`
+	EndIndex   int    `json:"end_index"`
+
+	// file_citation / container_file_citation
+	FileID      string `json:"file_id,omitempty"`
+	Filename    string `json:"filename,omitempty"`
+	ContainerID string `json:"container_id,omitempty"`
+}
+
+// ResponsesLogprob is the log probability of one output token.
+type ResponsesLogprob struct {
+	Token       string                `json:"token"`
+	Logprob     float64               `json:"logprob"`
+	TopLogprobs []ResponsesTopLogprob `json:"top_logprobs,omitempty"`
+}
+
+// ResponsesTopLogprob is one of the most likely alternatives for a token.
+type ResponsesTopLogprob struct {
+	Token   string  `json:"token"`
+	Logprob float64 `json:"logprob"`
 }
 
 // ResponsesTool describes a tool in the Responses API.
 type ResponsesTool struct {
-	Type        string          `json:"type"` // "function" | "web_search" | "local_shell" etc.
+	Type        string          `json:"type"` // "function" | "web_search" | "image_generation" | "local_shell" etc.
 	Name        string          `json:"name,omitempty"`
 	Description string          `json:"description,omitempty"`
 	Parameters  json.RawMessage `json:"parameters,omitempty"`
 	Strict      *bool           `json:"strict,omitempty"`
+
+	// type=mcp: a remote MCP server the model may call. AllowedTools is a
+	// list of tool names or a {"tool_names":[...],"read_only":bool} filter;
+	// RequireApproval is "always", "never" or {"always":{...},"never":{...}}.
+	ServerLabel       string            `json:"server_label,omitempty"`
+	ServerURL         string            `json:"server_url,omitempty"`
+	ServerDescription string            `json:"server_description,omitempty"`
+	ConnectorID       string            `json:"connector_id,omitempty"`
+	Authorization     string            `json:"authorization,omitempty"`
+	Headers           map[string]string `json:"headers,omitempty"`
+	AllowedTools      json.RawMessage   `json:"allowed_tools,omitempty"`
+	RequireApproval   json.RawMessage   `json:"require_approval,omitempty"`
+
+	// type=web_search
+	SearchContextSize string          `json:"search_context_size,omitempty"` // "low" | "medium" | "high"
+	UserLocation      json.RawMessage `json:"user_location,omitempty"`
+	Filters           json.RawMessage `json:"filters,omitempty"`
+
+	// type=code_interpreter: a container id or {"type":"auto","file_ids":[...]}
+	Container json.RawMessage `json:"container,omitempty"`
+
+	// type=image_generation
+	Model        string `json:"model,omitempty"`
+	Size         string `json:"size,omitempty"`
+	Quality      string `json:"quality,omitempty"`
+	Background   string `json:"background,omitempty"`
+	OutputFormat string `json:"output_format,omitempty"`
+	Moderation   string `json:"moderation,omitempty"`
 }
 
 // ResponsesResponse is the non-streaming response from POST /v1/responses.
@@ -236,7 +379,7 @@ type ResponsesIncompleteDetails struct {
 
 // ResponsesOutput is one output item in a Responses API response.
 type ResponsesOutput struct {
-	Type string `json:"type"` // "message" | "reasoning" | "function_call" | "web_search_call"
+	Type string `json:"type"` // "message" | "reasoning" | "function_call" | "web_search_call" | "code_interpreter_call" | "image_generation_call" | "mcp_call"
 
 	// type=message
 	ID      string                 `json:"id,omitempty"`
@@ -255,12 +398,48 @@ type ResponsesOutput struct {
 
 	// type=web_search_call
 	Action *WebSearchAction `json:"action,omitempty"`
+
+	// type=code_interpreter_call; Outputs requires include
+	// "code_interpreter_call.outputs"
+	Code        string                  `json:"code,omitempty"`
+	ContainerID string                  `json:"container_id,omitempty"`
+	Outputs     []CodeInterpreterOutput `json:"outputs,omitempty"`
+
+	// type=mcp_call (Name / Arguments above carry the tool and its input)
+	ServerLabel string `json:"server_label,omitempty"`
+	Output      string `json:"output,omitempty"`
+	Error       string `json:"error,omitempty"`
+
+	// type=image_generation_call
+	Result        string `json:"result,omitempty"` // base64-encoded image
+	RevisedPrompt string `json:"revised_prompt,omitempty"`
+	OutputFormat  string `json:"output_format,omitempty"`
+	Size          string `json:"size,omitempty"`
 }
 
 // WebSearchAction describes the search action in a web_search_call output item.
 type WebSearchAction struct {
-	Type  string `json:"type,omitempty"`  // "search"
+	Type  string `json:"type,omitempty"`  // "search" | "open_page" | "find"
 	Query string `json:"query,omitempty"` // primary search query
+	// Sources requires include "web_search_call.action.sources"
+	Sources []WebSearchSource `json:"sources,omitempty"`
+
+	// open_page / find
+	URL     string `json:"url,omitempty"`
+	Pattern string `json:"pattern,omitempty"`
+}
+
+// WebSearchSource is a page consulted by a web search.
+type WebSearchSource struct {
+	Type string `json:"type,omitempty"` // "url"
+	URL  string `json:"url,omitempty"`
+}
+
+// CodeInterpreterOutput is one output of a code_interpreter_call.
+type CodeInterpreterOutput struct {
+	Type string `json:"type"`           // "logs" | "image"
+	Logs string `json:"logs,omitempty"` // type=logs
+	URL  string `json:"url,omitempty"`  // type=image
 }
 
 // ResponsesSummary is a summary text block inside a reasoning output.
@@ -312,6 +491,9 @@ type ResponsesStreamEvent struct {
 	Text         string `json:"text,omitempty"`
 	ItemID       string `json:"item_id,omitempty"`
 
+	// response.output_text.delta, when logprobs were requested
+	Logprobs []ResponsesLogprob `json:"logprobs,omitempty"`
+
 	// response.function_call_arguments.delta / done
 	CallID    string `json:"call_id,omitempty"`
 	Name      string `json:"name,omitempty"`
@@ -321,7 +503,15 @@ type ResponsesStreamEvent struct {
 	// Reuses Text/Delta fields above, SummaryIndex identifies which summary part
 	SummaryIndex int `json:"summary_index,omitempty"`
 
-	// error event fields
+	// response.output_text.annotation.added
+	Annotation      *ResponsesAnnotation `json:"annotation,omitempty"`
+	AnnotationIndex int                  `json:"annotation_index,omitempty"`
+
+	// Some upstreams report usage on the event itself instead of response.usage
+	Usage *ResponsesUsage `json:"usage,omitempty"`
+
+	// error event fields; Code also carries the full code of
+	// response.code_interpreter_call_code.done
 	Code  string `json:"code,omitempty"`
 	Param string `json:"param,omitempty"`
 
@@ -329,6 +519,133 @@ type ResponsesStreamEvent struct {
 	SequenceNumber int `json:"sequence_number,omitempty"`
 }
 
+// ---------------------------------------------------------------------------
+// Gemini generateContent API types
+// ---------------------------------------------------------------------------
+
+// GeminiRequest is the request body for POST /v1beta/models/{model}:generateContent
+// and :streamGenerateContent. safetySettings / cachedContent are accepted but
+// ignored because the Responses API has no equivalent.
+type GeminiRequest struct {
+	Contents          []GeminiContent         `json:"contents"`
+	SystemInstruction *GeminiContent          `json:"systemInstruction,omitempty"`
+	Tools             []GeminiTool            `json:"tools,omitempty"`
+	ToolConfig        *GeminiToolConfig       `json:"toolConfig,omitempty"`
+	GenerationConfig  *GeminiGenerationConfig `json:"generationConfig,omitempty"`
+	SafetySettings    json.RawMessage         `json:"safetySettings,omitempty"`
+	CachedContent     string                  `json:"cachedContent,omitempty"`
+}
+
+// GeminiContent is one turn in a Gemini conversation.
+type GeminiContent struct {
+	Role  string       `json:"role,omitempty"` // "user" | "model" | "function"
+	Parts []GeminiPart `json:"parts"`
+}
+
+// GeminiPart is a single part inside a Gemini content.
+type GeminiPart struct {
+	Text             string                  `json:"text,omitempty"`
+	Thought          bool                    `json:"thought,omitempty"`
+	ThoughtSignature string                  `json:"thoughtSignature,omitempty"`
+	InlineData       *GeminiBlob             `json:"inlineData,omitempty"`
+	FileData         *GeminiFileData         `json:"fileData,omitempty"`
+	FunctionCall     *GeminiFunctionCall     `json:"functionCall,omitempty"`
+	FunctionResponse *GeminiFunctionResponse `json:"functionResponse,omitempty"`
+}
+
+// GeminiBlob carries inline base64 data (e.g. images).
+type GeminiBlob struct {
+	MimeType string `json:"mimeType"`
+	Data     string `json:"data"`
+}
+
+// GeminiFileData references data by URI (Files API or public URL).
+type GeminiFileData struct {
+	MimeType string `json:"mimeType,omitempty"`
+	FileURI  string `json:"fileUri"`
+}
+
+// GeminiFunctionCall is a model-issued function call.
+type GeminiFunctionCall struct {
+	ID   string          `json:"id,omitempty"`
+	Name string          `json:"name"`
+	Args json.RawMessage `json:"args,omitempty"`
+}
+
+// GeminiFunctionResponse is a client-supplied function result.
+type GeminiFunctionResponse struct {
+	ID       string          `json:"id,omitempty"`
+	Name     string          `json:"name"`
+	Response json.RawMessage `json:"response,omitempty"`
+}
+
+// GeminiTool groups function declarations and built-in tools.
+type GeminiTool struct {
+	FunctionDeclarations []GeminiFunctionDeclaration `json:"functionDeclarations,omitempty"`
+	GoogleSearch         json.RawMessage             `json:"googleSearch,omitempty"`
+}
+
+// GeminiFunctionDeclaration describes a callable function. Parameters uses the
+// OpenAPI subset (upper-case types); ParametersJSONSchema is plain JSON Schema.
+type GeminiFunctionDeclaration struct {
+	Name                 string          `json:"name"`
+	Description          string          `json:"description,omitempty"`
+	Parameters           json.RawMessage `json:"parameters,omitempty"`
+	ParametersJSONSchema json.RawMessage `json:"parametersJsonSchema,omitempty"`
+}
+
+// GeminiToolConfig controls function calling behaviour.
+type GeminiToolConfig struct {
+	FunctionCallingConfig *GeminiFunctionCallingConfig `json:"functionCallingConfig,omitempty"`
+}
+
+// GeminiFunctionCallingConfig selects the function calling mode.
+type GeminiFunctionCallingConfig struct {
+	Mode                 string   `json:"mode,omitempty"` // "AUTO" | "ANY" | "NONE" | "VALIDATED"
+	AllowedFunctionNames []string `json:"allowedFunctionNames,omitempty"`
+}
+
+// GeminiGenerationConfig holds sampling and output parameters.
+type GeminiGenerationConfig struct {
+	Temperature     *float64              `json:"temperature,omitempty"`
+	TopP            *float64              `json:"topP,omitempty"`
+	MaxOutputTokens int                   `json:"maxOutputTokens,omitempty"`
+	StopSequences   []string              `json:"stopSequences,omitempty"`
+	ThinkingConfig  *GeminiThinkingConfig `json:"thinkingConfig,omitempty"`
+}
+
+// GeminiThinkingConfig configures model thinking.
+type GeminiThinkingConfig struct {
+	IncludeThoughts bool   `json:"includeThoughts,omitempty"`
+	ThinkingBudget  *int   `json:"thinkingBudget,omitempty"`
+	ThinkingLevel   string `json:"thinkingLevel,omitempty"` // "low" | "medium" | "high"
+}
+
+// GeminiResponse is a generateContent response, or one chunk of a
+// streamGenerateContent response.
+type GeminiResponse struct {
+	Candidates    []GeminiCandidate    `json:"candidates"`
+	UsageMetadata *GeminiUsageMetadata `json:"usageMetadata,omitempty"`
+	ModelVersion  string               `json:"modelVersion,omitempty"`
+	ResponseID    string               `json:"responseId,omitempty"`
+}
+
+// GeminiCandidate is one generated candidate.
+type GeminiCandidate struct {
+	Content      GeminiContent `json:"content"`
+	FinishReason string        `json:"finishReason,omitempty"` // "STOP" | "MAX_TOKENS" | "SAFETY" | "OTHER"
+	Index        int           `json:"index"`
+}
+
+// GeminiUsageMetadata holds token counts in Gemini format.
+type GeminiUsageMetadata struct {
+	PromptTokenCount        int `json:"promptTokenCount"`
+	CandidatesTokenCount    int `json:"candidatesTokenCount"`
+	TotalTokenCount         int `json:"totalTokenCount"`
+	CachedContentTokenCount int `json:"cachedContentTokenCount,omitempty"`
+	ThoughtsTokenCount      int `json:"thoughtsTokenCount,omitempty"`
+}
+
 // ---------------------------------------------------------------------------
 // Shared constants
 // ---------------------------------------------------------------------------
@@ -336,3 +653,128 @@ type ResponsesStreamEvent struct {
 // minMaxOutputTokens is the floor for max_output_tokens in a Responses request.
 // Very small values may cause upstream API errors, so we enforce a minimum.
 const minMaxOutputTokens = 128
+
+// ---------------------------------------------------------------------------
+// Ollama API types
+// ---------------------------------------------------------------------------
+
+// OllamaChatRequest is the request body for POST /api/chat.
+// keep_alive is accepted but ignored.
+type OllamaChatRequest struct {
+	Model    string          `json:"model"`
+	Messages []OllamaMessage `json:"messages"`
+	Tools    []OllamaTool    `json:"tools,omitempty"`
+	Format   json.RawMessage `json:"format,omitempty"` // "json" or a JSON schema object
+	Options  *OllamaOptions  `json:"options,omitempty"`
+	Stream   *bool           `json:"stream,omitempty"` // defaults to true
+	Think    json.RawMessage `json:"think,omitempty"`  // bool or "low" | "medium" | "high"
+}
+
+// OllamaGenerateRequest is the request body for POST /api/generate.
+// suffix / raw / context are accepted but ignored.
+type OllamaGenerateRequest struct {
+	Model   string          `json:"model"`
+	Prompt  string          `json:"prompt"`
+	System  string          `json:"system,omitempty"`
+	Images  []string        `json:"images,omitempty"` // base64, no data URI prefix
+	Format  json.RawMessage `json:"format,omitempty"`
+	Options *OllamaOptions  `json:"options,omitempty"`
+	Stream  *bool           `json:"stream,omitempty"`
+	Think   json.RawMessage `json:"think,omitempty"`
+}
+
+// OllamaMessage is one message in an Ollama chat conversation.
+type OllamaMessage struct {
+	Role      string           `json:"role"` // "system" | "user" | "assistant" | "tool"
+	Content   string           `json:"content"`
+	Thinking  string           `json:"thinking,omitempty"`
+	Images    []string         `json:"images,omitempty"`
+	ToolCalls []OllamaToolCall `json:"tool_calls,omitempty"`
+	ToolName  string           `json:"tool_name,omitempty"` // role=tool: the function being answered
+}
+
+// OllamaTool describes a function tool.
+type OllamaTool struct {
+	Type     string             `json:"type"` // "function"
+	Function OllamaToolFunction `json:"function"`
+}
+
+// OllamaToolFunction is the function definition inside an OllamaTool.
+type OllamaToolFunction struct {
+	Name        string          `json:"name"`
+	Description string          `json:"description,omitempty"`
+	Parameters  json.RawMessage `json:"parameters,omitempty"`
+}
+
+// OllamaToolCall is a tool call issued by the assistant. Ollama tool calls
+// carry no id; arguments are a JSON object rather than a string.
+type OllamaToolCall struct {
+	Function OllamaToolCallFunction `json:"function"`
+}
+
+// OllamaToolCallFunction carries the called function name and arguments.
+type OllamaToolCallFunction struct {
+	Name      string          `json:"name"`
+	Arguments json.RawMessage `json:"arguments"`
+}
+
+// OllamaOptions holds the subset of model options that map to the Responses
+// API. Unsupported options (seed, stop, num_ctx, ...) are ignored.
+type OllamaOptions struct {
+	Temperature *float64 `json:"temperature,omitempty"`
+	TopP        *float64 `json:"top_p,omitempty"`
+	NumPredict  *int     `json:"num_predict,omitempty"`
+}
+
+// OllamaChatResponse is a /api/chat response object (the whole response, or
+// one NDJSON line when streaming).
+type OllamaChatResponse struct {
+	Model      string        `json:"model"`
+	CreatedAt  string        `json:"created_at"`
+	Message    OllamaMessage `json:"message"`
+	Done       bool          `json:"done"`
+	DoneReason string        `json:"done_reason,omitempty"`
+	OllamaMetrics
+}
+
+// OllamaGenerateResponse is a /api/generate response object.
+type OllamaGenerateResponse struct {
+	Model      string `json:"model"`
+	CreatedAt  string `json:"created_at"`
+	Response   string `json:"response"`
+	Thinking   string `json:"thinking,omitempty"`
+	Done       bool   `json:"done"`
+	DoneReason string `json:"done_reason,omitempty"`
+	OllamaMetrics
+}
+
+// OllamaMetrics carries token counts and durations (nanoseconds); only set on
+// the final (done=true) object.
+type OllamaMetrics struct {
+	TotalDuration   int64 `json:"total_duration,omitempty"`
+	PromptEvalCount int   `json:"prompt_eval_count,omitempty"`
+	EvalCount       int   `json:"eval_count,omitempty"`
+}
+
+// OllamaTagsResponse is the response body for GET /api/tags.
+type OllamaTagsResponse struct {
+	Models []OllamaModel `json:"models"`
+}
+
+// OllamaModel is one entry in the /api/tags model list.
+type OllamaModel struct {
+	Name       string             `json:"name"`
+	Model      string             `json:"model"`
+	ModifiedAt string             `json:"modified_at"`
+	Size       int64              `json:"size"`
+	Digest     string             `json:"digest"`
+	Details    OllamaModelDetails `json:"details"`
+}
+
+// OllamaModelDetails describes a listed model.
+type OllamaModelDetails struct {
+	Format            string `json:"format"`
+	Family            string `json:"family"`
+	ParameterSize     string `json:"parameter_size"`
+	QuantizationLevel string `json:"quantization_level"`
+}
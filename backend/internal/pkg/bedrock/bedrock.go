@@ -0,0 +1,183 @@
+// Package bedrock implements the wire details of the AWS Bedrock Runtime
+// InvokeModel / InvokeModelWithResponseStream APIs for Anthropic models:
+// model ID parsing, request body normalization and the binary
+// application/vnd.amazon.eventstream response framing.
+package bedrock
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"regexp"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/aws/protocol/eventstream"
+	"github.com/tidwall/gjson"
+	"github.com/tidwall/sjson"
+)
+
+// EventStreamContentType is the response content type of
+// InvokeModelWithResponseStream.
+const EventStreamContentType = "application/vnd.amazon.eventstream"
+
+// Actions of the /model/{modelId}/{action} route.
+const (
+	ActionInvoke       = "invoke"
+	ActionInvokeStream = "invoke-with-response-stream"
+)
+
+// bedrockVersionSuffix matches the "-v1:0" style revision suffix of Bedrock
+// model IDs.
+var bedrockVersionSuffix = regexp.MustCompile(`-v\d+(:\d+)?$`)
+
+// ParseModelAction splits the "/{modelId}/{action}" wildcard of the Bedrock
+// route. Model IDs may be ARNs containing slashes, so the action is taken
+// from the last segment.
+func ParseModelAction(modelAction string) (modelID, action string, ok bool) {
+	modelAction = strings.TrimPrefix(modelAction, "/")
+	idx := strings.LastIndex(modelAction, "/")
+	if idx <= 0 {
+		return "", "", false
+	}
+	modelID, action = modelAction[:idx], modelAction[idx+1:]
+	if action != ActionInvoke && action != ActionInvokeStream {
+		return "", "", false
+	}
+	return modelID, action, true
+}
+
+// ToAnthropicModelID converts a Bedrock model ID, cross-region inference
+// profile ID or ARN to the Anthropic API model name, e.g.
+//
+//	anthropic.claude-3-5-sonnet-20241022-v2:0          → claude-3-5-sonnet-20241022
+//	us.anthropic.claude-sonnet-4-20250514-v1:0         → claude-sonnet-4-20250514
+//	arn:aws:bedrock:...:inference-profile/global.anthropic.claude-... → claude-...
+//
+// IDs without an "anthropic." segment are returned unchanged so that
+// gateway-side model mappings still apply.
+func ToAnthropicModelID(modelID string) string {
+	id := strings.TrimSpace(modelID)
+	if idx := strings.LastIndex(id, "/"); idx >= 0 {
+		id = id[idx+1:]
+	}
+	idx := strings.Index(id, "anthropic.")
+	if idx < 0 {
+		return id
+	}
+	return bedrockVersionSuffix.ReplaceAllString(id[idx+len("anthropic."):], "")
+}
+
+// ToAnthropicRequest converts a Bedrock Anthropic request body into an
+// Anthropic Messages API body: the model comes from the path, stream from
+// the action, anthropic_version is removed and anthropic_beta (a body field
+// on Bedrock) is returned so it can be sent as the anthropic-beta header.
+func ToAnthropicRequest(body []byte, model string, stream bool) (out []byte, betas []string, err error) {
+	if !gjson.ValidBytes(body) || !gjson.ParseBytes(body).IsObject() {
+		return nil, nil, fmt.Errorf("request body must be a JSON object")
+	}
+	for _, beta := range gjson.GetBytes(body, "anthropic_beta").Array() {
+		if s := strings.TrimSpace(beta.String()); s != "" {
+			betas = append(betas, s)
+		}
+	}
+	out = body
+	for _, key := range []string{"anthropic_version", "anthropic_beta"} {
+		if out, err = sjson.DeleteBytes(out, key); err != nil {
+			return nil, nil, err
+		}
+	}
+	if out, err = sjson.SetBytes(out, "model", model); err != nil {
+		return nil, nil, err
+	}
+	if stream {
+		out, err = sjson.SetBytes(out, "stream", true)
+	} else {
+		out, err = sjson.DeleteBytes(out, "stream")
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+	return out, betas, nil
+}
+
+// EventStreamWriter frames Anthropic stream events as Bedrock "chunk"
+// events: {"bytes": base64(event JSON)}.
+type EventStreamWriter struct {
+	w   io.Writer
+	enc *eventstream.Encoder
+}
+
+// NewEventStreamWriter returns a writer that encodes chunks to w.
+func NewEventStreamWriter(w io.Writer) *EventStreamWriter {
+	return &EventStreamWriter{w: w, enc: eventstream.NewEncoder()}
+}
+
+// WriteChunk writes one Anthropic stream event (raw JSON) as a chunk event.
+func (e *EventStreamWriter) WriteChunk(event []byte) error {
+	payload, err := json.Marshal(map[string]string{"bytes": base64.StdEncoding.EncodeToString(event)})
+	if err != nil {
+		return err
+	}
+	return e.enc.Encode(e.w, eventstream.Message{
+		Headers: eventstream.Headers{
+			{Name: ":event-type", Value: eventstream.StringValue("chunk")},
+			{Name: ":content-type", Value: eventstream.StringValue("application/json")},
+			{Name: ":message-type", Value: eventstream.StringValue("event")},
+		},
+		Payload: payload,
+	})
+}
+
+// WriteException writes a modeled exception event (e.g. "throttlingException",
+// "modelStreamErrorException") with a {"message": ...} payload.
+func (e *EventStreamWriter) WriteException(exceptionType, message string) error {
+	payload, err := json.Marshal(map[string]string{"message": message})
+	if err != nil {
+		return err
+	}
+	return e.enc.Encode(e.w, eventstream.Message{
+		Headers: eventstream.Headers{
+			{Name: ":exception-type", Value: eventstream.StringValue(exceptionType)},
+			{Name: ":content-type", Value: eventstream.StringValue("application/json")},
+			{Name: ":message-type", Value: eventstream.StringValue("exception")},
+		},
+		Payload: payload,
+	})
+}
+
+// ErrorTypeForStatus returns the x-amzn-ErrorType Bedrock uses for an HTTP
+// error status.
+func ErrorTypeForStatus(status int) string {
+	switch {
+	case status == 401 || status == 403:
+		return "AccessDeniedException"
+	case status == 404:
+		return "ResourceNotFoundException"
+	case status == 408:
+		return "ModelTimeoutException"
+	case status == 429:
+		return "ThrottlingException"
+	case status == 503 || status == 529:
+		return "ServiceUnavailableException"
+	case status >= 500:
+		return "InternalServerException"
+	default:
+		return "ValidationException"
+	}
+}
+
+// StreamExceptionType maps an Anthropic stream error type to the Bedrock
+// stream exception event type.
+func StreamExceptionType(anthropicErrorType string) string {
+	switch anthropicErrorType {
+	case "rate_limit_error":
+		return "throttlingException"
+	case "overloaded_error":
+		return "serviceUnavailableException"
+	case "invalid_request_error":
+		return "validationException"
+	default:
+		return "internalServerException"
+	}
+}
@@ -0,0 +1,86 @@
+package bedrock
+
+import (
+	"bytes"
+	"encoding/base64"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws/protocol/eventstream"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tidwall/gjson"
+)
+
+func TestParseModelAction(t *testing.T) {
+	tests := []struct {
+		in         string
+		wantModel  string
+		wantAction string
+		wantOK     bool
+	}{
+		{in: "/anthropic.claude-3-5-sonnet-20241022-v2:0/invoke", wantModel: "anthropic.claude-3-5-sonnet-20241022-v2:0", wantAction: ActionInvoke, wantOK: true},
+		{in: "/us.anthropic.claude-sonnet-4-20250514-v1:0/invoke-with-response-stream", wantModel: "us.anthropic.claude-sonnet-4-20250514-v1:0", wantAction: ActionInvokeStream, wantOK: true},
+		{in: "/arn:aws:bedrock:us-east-1:123:inference-profile/us.anthropic.claude-sonnet-4-20250514-v1:0/invoke", wantModel: "arn:aws:bedrock:us-east-1:123:inference-profile/us.anthropic.claude-sonnet-4-20250514-v1:0", wantAction: ActionInvoke, wantOK: true},
+		{in: "/anthropic.claude-3-haiku/converse"},
+		{in: "/invoke"},
+	}
+	for _, tt := range tests {
+		model, action, ok := ParseModelAction(tt.in)
+		assert.Equal(t, tt.wantOK, ok, tt.in)
+		assert.Equal(t, tt.wantModel, model, tt.in)
+		assert.Equal(t, tt.wantAction, action, tt.in)
+	}
+}
+
+func TestToAnthropicModelID(t *testing.T) {
+	tests := map[string]string{
+		"anthropic.claude-3-5-sonnet-20241022-v2:0":                                                  "claude-3-5-sonnet-20241022",
+		"us.anthropic.claude-sonnet-4-20250514-v1:0":                                                 "claude-sonnet-4-20250514",
+		"global.anthropic.claude-sonnet-4-5-20250929-v1:0":                                           "claude-sonnet-4-5-20250929",
+		"anthropic.claude-3-haiku-20240307-v1":                                                       "claude-3-haiku-20240307",
+		"arn:aws:bedrock:us-east-1:123:inference-profile/eu.anthropic.claude-opus-4-1-20250805-v1:0": "claude-opus-4-1-20250805",
+		"claude-sonnet-4-5": "claude-sonnet-4-5",
+	}
+	for in, want := range tests {
+		assert.Equal(t, want, ToAnthropicModelID(in), in)
+	}
+}
+
+func TestToAnthropicRequest(t *testing.T) {
+	body := []byte(`{"anthropic_version":"bedrock-2023-05-31","anthropic_beta":["context-1m-2025-08-07","interleaved-thinking-2025-05-14"],"max_tokens":64,"messages":[{"role":"user","content":"hi"}],"stream":false}`)
+
+	out, betas, err := ToAnthropicRequest(body, "claude-sonnet-4-20250514", true)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"context-1m-2025-08-07", "interleaved-thinking-2025-05-14"}, betas)
+	assert.JSONEq(t, `{"model":"claude-sonnet-4-20250514","max_tokens":64,"messages":[{"role":"user","content":"hi"}],"stream":true}`, string(out))
+
+	out, betas, err = ToAnthropicRequest([]byte(`{"anthropic_version":"bedrock-2023-05-31","max_tokens":64,"messages":[]}`), "claude-3-haiku-20240307", false)
+	require.NoError(t, err)
+	assert.Empty(t, betas)
+	assert.JSONEq(t, `{"model":"claude-3-haiku-20240307","max_tokens":64,"messages":[]}`, string(out))
+
+	_, _, err = ToAnthropicRequest([]byte(`[1]`), "m", false)
+	require.Error(t, err)
+}
+
+func TestEventStreamWriter(t *testing.T) {
+	var buf bytes.Buffer
+	w := NewEventStreamWriter(&buf)
+	require.NoError(t, w.WriteChunk([]byte(`{"type":"message_stop"}`)))
+	require.NoError(t, w.WriteException("throttlingException", "slow down"))
+
+	dec := eventstream.NewDecoder()
+	msg, err := dec.Decode(&buf, nil)
+	require.NoError(t, err)
+	assert.Equal(t, "chunk", msg.Headers.Get(":event-type").String())
+	assert.Equal(t, "event", msg.Headers.Get(":message-type").String())
+	raw, err := base64.StdEncoding.DecodeString(gjson.GetBytes(msg.Payload, "bytes").String())
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"type":"message_stop"}`, string(raw))
+
+	msg, err = dec.Decode(&buf, nil)
+	require.NoError(t, err)
+	assert.Equal(t, "exception", msg.Headers.Get(":message-type").String())
+	assert.Equal(t, "throttlingException", msg.Headers.Get(":exception-type").String())
+	assert.JSONEq(t, `{"message":"slow down"}`, string(msg.Payload))
+}
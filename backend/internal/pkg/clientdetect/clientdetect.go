@@ -0,0 +1,321 @@
+// Package clientdetect 从 User-Agent 及 originator/x-stainless-* 等指纹头解析下游客户端（Codex/Claude Code/IDE Agent/SDK 等）的类型、版本与平台信息。
+//
+// 相比 openai.IsCodexCLIRequest 的布尔判定，ClientInfo 额外携带版本号与平台，
+// 供路由、日志与统计等下游逻辑按客户端类型/版本做细粒度决策。
+package clientdetect
+
+import (
+	"context"
+	"regexp"
+	"strings"
+
+	"github.com/ShaohongDong/sub2api/internal/pkg/ctxkey"
+	"github.com/ShaohongDong/sub2api/internal/pkg/openai"
+)
+
+// ClientType 客户端类型标识（稳定字符串，可直接用于日志字段与统计维度）。
+type ClientType string
+
+const (
+	TypeUnknown ClientType = "unknown"
+
+	// Codex 官方客户端家族
+	TypeCodexCLI     ClientType = "codex_cli_rs"
+	TypeCodexVSCode  ClientType = "codex_vscode"
+	TypeCodexApp     ClientType = "codex_app"
+	TypeCodexDesktop ClientType = "codex_chatgpt_desktop"
+	TypeCodexAtlas   ClientType = "codex_atlas"
+	TypeCodexExec    ClientType = "codex_exec"
+	TypeCodexSDK     ClientType = "codex_sdk_ts"
+
+	// 其他编码 Agent / IDE 客户端
+	TypeClaudeCode ClientType = "claude_code"
+	TypeCursor     ClientType = "cursor"
+	TypeWindsurf   ClientType = "windsurf"
+	TypeCline      ClientType = "cline"
+	TypeCopilot    ClientType = "github_copilot"
+
+	// 通用客户端
+	TypeOpenAISDK    ClientType = "openai_sdk"
+	TypeAnthropicSDK ClientType = "anthropic_sdk"
+	TypeCurl         ClientType = "curl"
+	TypeHTTPLibrary  ClientType = "http_library"
+	TypeBrowser      ClientType = "browser"
+)
+
+// knownTypes 全部已定义的客户端类型，用于配置校验。
+var knownTypes = map[ClientType]struct{}{
+	TypeUnknown: {}, TypeCodexCLI: {}, TypeCodexVSCode: {}, TypeCodexApp: {}, TypeCodexDesktop: {},
+	TypeCodexAtlas: {}, TypeCodexExec: {}, TypeCodexSDK: {}, TypeClaudeCode: {}, TypeCursor: {},
+	TypeWindsurf: {}, TypeCline: {}, TypeCopilot: {}, TypeOpenAISDK: {}, TypeAnthropicSDK: {},
+	TypeCurl: {}, TypeHTTPLibrary: {}, TypeBrowser: {},
+}
+
+// IsKnownType 判断字符串是否为已定义的客户端类型。
+func IsKnownType(t string) bool {
+	_, ok := knownTypes[ClientType(t)]
+	return ok
+}
+
+// 平台标识
+const (
+	PlatformUnknown = ""
+	PlatformMacOS   = "macos"
+	PlatformWindows = "windows"
+	PlatformLinux   = "linux"
+	PlatformAndroid = "android"
+	PlatformIOS     = "ios"
+)
+
+// ClientInfo 客户端解析结果。
+type ClientInfo struct {
+	// Type 客户端类型
+	Type ClientType `json:"type"`
+	// Version 客户端版本（规范化 semver，如 "0.46.0"、"1.2.0-beta.1"；无法解析时为空）
+	Version string `json:"version,omitempty"`
+	// Platform 客户端运行平台（macos/windows/linux/...；无法解析时为空）
+	Platform string `json:"platform,omitempty"`
+}
+
+// IsCodex 判断是否为 Codex 官方客户端家族。
+func (i ClientInfo) IsCodex() bool {
+	switch i.Type {
+	case TypeCodexCLI, TypeCodexVSCode, TypeCodexApp, TypeCodexDesktop, TypeCodexAtlas, TypeCodexExec, TypeCodexSDK:
+		return true
+	default:
+		return false
+	}
+}
+
+// IsClaudeCode 判断是否为 Claude Code（claude-cli）客户端。
+func (i ClientInfo) IsClaudeCode() bool {
+	return i.Type == TypeClaudeCode
+}
+
+// IsAgentIDE 判断是否为 IDE 内置的编码 Agent（Cursor/Windsurf/Cline/GitHub Copilot）。
+func (i ClientInfo) IsAgentIDE() bool {
+	switch i.Type {
+	case TypeCursor, TypeWindsurf, TypeCline, TypeCopilot:
+		return true
+	default:
+		return false
+	}
+}
+
+// IsCodingAgent 判断是否为任意已识别的编码 Agent 客户端（Codex/Claude Code/IDE Agent）。
+func (i ClientInfo) IsCodingAgent() bool {
+	return i.IsCodex() || i.IsClaudeCode() || i.IsAgentIDE()
+}
+
+// IsUnknown 判断是否未识别出客户端类型。
+func (i ClientInfo) IsUnknown() bool {
+	return i.Type == "" || i.Type == TypeUnknown
+}
+
+// productRule 描述一个 UA 产品标记（如 "codex_cli_rs/"）到客户端类型的映射。
+type productRule struct {
+	token      string // 小写产品标记，包含分隔符
+	clientType ClientType
+}
+
+// codexProductRules Codex 官方客户端家族（按从具体到宽泛排序）。
+var codexProductRules = []productRule{
+	{token: "codex_cli_rs/", clientType: TypeCodexCLI},
+	{token: "codex_vscode/", clientType: TypeCodexVSCode},
+	{token: "codex_chatgpt_desktop/", clientType: TypeCodexDesktop},
+	{token: "codex_atlas/", clientType: TypeCodexAtlas},
+	{token: "codex_exec/", clientType: TypeCodexExec},
+	{token: "codex_sdk_ts/", clientType: TypeCodexSDK},
+	{token: "codex_app/", clientType: TypeCodexApp},
+	{token: "codex ", clientType: TypeCodexDesktop},
+}
+
+// agentProductRules 其他编码 Agent / IDE 客户端。
+// Cursor/Windsurf 基于 Electron，UA 常带 Mozilla/Chrome 标记，因此须先于通用规则匹配。
+var agentProductRules = []productRule{
+	{token: "claude-cli/", clientType: TypeClaudeCode},
+	{token: "claude-code/", clientType: TypeClaudeCode},
+	{token: "githubcopilotchat/", clientType: TypeCopilot},
+	{token: "github-copilot/", clientType: TypeCopilot},
+	{token: "copilot/", clientType: TypeCopilot},
+	{token: "cursor/", clientType: TypeCursor},
+	{token: "windsurf/", clientType: TypeWindsurf},
+	{token: "codeium/", clientType: TypeWindsurf},
+	{token: "cline/", clientType: TypeCline},
+}
+
+// genericProductRules 通用客户端（仅在未命中特定客户端时参与匹配，按顺序优先）。
+var genericProductRules = []productRule{
+	{token: "openai/", clientType: TypeOpenAISDK},
+	{token: "anthropic/", clientType: TypeAnthropicSDK},
+	{token: "curl/", clientType: TypeCurl},
+	{token: "python-requests/", clientType: TypeHTTPLibrary},
+	{token: "python-httpx/", clientType: TypeHTTPLibrary},
+	{token: "aiohttp/", clientType: TypeHTTPLibrary},
+	{token: "go-http-client/", clientType: TypeHTTPLibrary},
+	{token: "axios/", clientType: TypeHTTPLibrary},
+	{token: "node-fetch/", clientType: TypeHTTPLibrary},
+	{token: "undici", clientType: TypeHTTPLibrary},
+	{token: "okhttp/", clientType: TypeHTTPLibrary},
+	{token: "wget/", clientType: TypeHTTPLibrary},
+	{token: "mozilla/", clientType: TypeBrowser},
+}
+
+// semverPattern 匹配 major.minor[.patch][-prerelease]
+var semverPattern = regexp.MustCompile(`v?(\d+)\.(\d+)(?:\.(\d+))?(-[0-9A-Za-z][0-9A-Za-z.-]*)?`)
+
+// Parse 解析 User-Agent 为 ClientInfo。空 UA 或无法识别时返回 TypeUnknown。
+func Parse(userAgent string) ClientInfo {
+	raw := strings.TrimSpace(userAgent)
+	if raw == "" {
+		return ClientInfo{Type: TypeUnknown}
+	}
+	lower := strings.ToLower(raw)
+
+	info := ClientInfo{Type: TypeUnknown, Platform: parsePlatform(lower)}
+
+	if rule, idx, ok := matchProduct(lower, codexProductRules); ok {
+		info.Type = rule.clientType
+		info.Version = productVersion(raw[idx+len(rule.token):])
+		return info
+	}
+	if rule, idx, ok := matchProduct(lower, agentProductRules); ok {
+		info.Type = rule.clientType
+		info.Version = productVersion(raw[idx+len(rule.token):])
+		return info
+	}
+	// 运行时配置的 Codex CLI 前缀（内部 fork / 新版客户端），统一归为 codex_cli_rs。
+	for _, prefix := range openai.ActiveCodexCLIUserAgentPrefixes() {
+		if prefix == "" {
+			continue
+		}
+		if idx := strings.Index(lower, prefix); idx >= 0 {
+			info.Type = TypeCodexCLI
+			info.Version = productVersion(raw[idx+len(prefix):])
+			return info
+		}
+	}
+	if rule, idx, ok := matchProduct(lower, genericProductRules); ok {
+		info.Type = rule.clientType
+		if rule.clientType != TypeBrowser {
+			info.Version = productVersion(raw[idx+len(rule.token):])
+		}
+		return info
+	}
+	return info
+}
+
+// IsClaudeCodeRequest 判断 User-Agent 是否来自 Claude Code（claude-cli）。
+// 注意：这是宽松的 UA 识别，不等同于 service.ClaudeCodeValidator 的严格校验（system prompt/headers）。
+func IsClaudeCodeRequest(userAgent string) bool {
+	return Parse(userAgent).IsClaudeCode()
+}
+
+// IsAgentIDERequest 判断 User-Agent 是否来自 IDE 内置编码 Agent（Cursor/Windsurf/Cline/GitHub Copilot）。
+func IsAgentIDERequest(userAgent string) bool {
+	return Parse(userAgent).IsAgentIDE()
+}
+
+// IsCursorRequest 判断 User-Agent 是否来自 Cursor。
+func IsCursorRequest(userAgent string) bool {
+	return Parse(userAgent).Type == TypeCursor
+}
+
+// IsWindsurfRequest 判断 User-Agent 是否来自 Windsurf（含 Codeium）。
+func IsWindsurfRequest(userAgent string) bool {
+	return Parse(userAgent).Type == TypeWindsurf
+}
+
+// IsClineRequest 判断 User-Agent 是否来自 Cline。
+func IsClineRequest(userAgent string) bool {
+	return Parse(userAgent).Type == TypeCline
+}
+
+// IsCopilotRequest 判断 User-Agent 是否来自 GitHub Copilot。
+func IsCopilotRequest(userAgent string) bool {
+	return Parse(userAgent).Type == TypeCopilot
+}
+
+// matchProduct 按规则顺序查找第一个出现在 UA 中的产品标记，返回命中位置。
+func matchProduct(lowerUA string, rules []productRule) (productRule, int, bool) {
+	for _, rule := range rules {
+		if idx := strings.Index(lowerUA, rule.token); idx >= 0 {
+			return rule, idx, true
+		}
+	}
+	return productRule{}, -1, false
+}
+
+// productVersion 提取产品标记之后的版本号。
+// 兼容标记后仍带产品名/语言名的形式：
+//   - "Codex Desktop/1.2.3"：取同一产品段内 "/" 之后的版本；
+//   - "OpenAI/Python 1.51.0"（Stainless SDK）：取产品段之后紧跟的版本。
+func productVersion(rest string) string {
+	if v := ExtractSemver(rest); v != "" {
+		return v
+	}
+	segment, tail := rest, ""
+	if sp := strings.IndexByte(rest, ' '); sp >= 0 {
+		segment, tail = rest[:sp], rest[sp+1:]
+	}
+	if slash := strings.IndexByte(segment, '/'); slash >= 0 {
+		return ExtractSemver(segment[slash+1:])
+	}
+	return ExtractSemver(tail)
+}
+
+// ExtractSemver 从字符串开头附近提取 semver 版本号，返回规范化的 "major.minor.patch[-pre]"。
+// 仅接受紧随产品标记之后的版本（允许前导 "v" 或空白），避免误取 UA 中其他组件的版本。
+func ExtractSemver(s string) string {
+	s = strings.TrimLeft(s, " \t")
+	loc := semverPattern.FindStringSubmatchIndex(s)
+	if loc == nil || loc[0] != 0 {
+		return ""
+	}
+	major := s[loc[2]:loc[3]]
+	minor := s[loc[4]:loc[5]]
+	patch := "0"
+	if loc[6] >= 0 {
+		patch = s[loc[6]:loc[7]]
+	}
+	version := major + "." + minor + "." + patch
+	if loc[8] >= 0 {
+		version += s[loc[8]:loc[9]]
+	}
+	return version
+}
+
+// parsePlatform 从小写 UA 中推断运行平台。
+func parsePlatform(lowerUA string) string {
+	switch {
+	case strings.Contains(lowerUA, "android"):
+		return PlatformAndroid
+	case strings.Contains(lowerUA, "iphone"), strings.Contains(lowerUA, "ipad"), strings.Contains(lowerUA, "ios "):
+		return PlatformIOS
+	case strings.Contains(lowerUA, "mac os"), strings.Contains(lowerUA, "macos"), strings.Contains(lowerUA, "darwin"):
+		return PlatformMacOS
+	case strings.Contains(lowerUA, "windows"), strings.Contains(lowerUA, "win32"), strings.Contains(lowerUA, "win64"):
+		return PlatformWindows
+	case strings.Contains(lowerUA, "linux"), strings.Contains(lowerUA, "ubuntu"), strings.Contains(lowerUA, "debian"):
+		return PlatformLinux
+	default:
+		return PlatformUnknown
+	}
+}
+
+// IntoContext 将 ClientInfo 写入 context。
+func IntoContext(ctx context.Context, info ClientInfo) context.Context {
+	return context.WithValue(ctx, ctxkey.ClientInfo, info)
+}
+
+// FromContext 从 context 读取 ClientInfo；未设置时返回 TypeUnknown 与 false。
+func FromContext(ctx context.Context) (ClientInfo, bool) {
+	if ctx == nil {
+		return ClientInfo{Type: TypeUnknown}, false
+	}
+	info, ok := ctx.Value(ctxkey.ClientInfo).(ClientInfo)
+	if !ok {
+		return ClientInfo{Type: TypeUnknown}, false
+	}
+	return info, true
+}
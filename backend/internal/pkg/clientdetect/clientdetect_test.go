@@ -0,0 +1,100 @@
+package clientdetect
+
+import (
+	"context"
+	"testing"
+
+	"github.com/ShaohongDong/sub2api/internal/pkg/openai"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		name string
+		ua   string
+		want ClientInfo
+	}{
+		{name: "codex_cli_rs 带平台", ua: "codex_cli_rs/0.46.0 (Mac OS 15.6.1; arm64) iTerm.app/3.6.1", want: ClientInfo{Type: TypeCodexCLI, Version: "0.46.0", Platform: PlatformMacOS}},
+		{name: "codex_vscode windows", ua: "codex_vscode/0.1.2 (Windows 10.0.26100; x86_64) vscode/1.104.0", want: ClientInfo{Type: TypeCodexVSCode, Version: "0.1.2", Platform: PlatformWindows}},
+		{name: "codex_exec linux 预发布", ua: "codex_exec/0.50.0-alpha.3 (Ubuntu 24.04; x86_64)", want: ClientInfo{Type: TypeCodexExec, Version: "0.50.0-alpha.3", Platform: PlatformLinux}},
+		{name: "Codex Desktop", ua: "Codex Desktop/1.2.3", want: ClientInfo{Type: TypeCodexDesktop, Version: "1.2.3"}},
+		{name: "复合 UA 包含 codex", ua: "Mozilla/5.0 codex_cli_rs/0.1.0", want: ClientInfo{Type: TypeCodexCLI, Version: "0.1.0"}},
+		{name: "大小写混合", ua: "Codex_VSCode/1.2", want: ClientInfo{Type: TypeCodexVSCode, Version: "1.2.0"}},
+		{name: "Claude Code", ua: "claude-cli/2.0.14 (external, cli)", want: ClientInfo{Type: TypeClaudeCode, Version: "2.0.14"}},
+		{name: "Cursor Electron", ua: "Mozilla/5.0 (Macintosh; Intel Mac OS X 10_15_7) AppleWebKit/537.36 Cursor/1.7.38 Chrome/132.0 Electron/34.5.8 Safari/537.36", want: ClientInfo{Type: TypeCursor, Version: "1.7.38", Platform: PlatformMacOS}},
+		{name: "Windsurf", ua: "Windsurf/1.12.2 (Windows NT 10.0)", want: ClientInfo{Type: TypeWindsurf, Version: "1.12.2", Platform: PlatformWindows}},
+		{name: "Cline", ua: "Cline/3.32.1", want: ClientInfo{Type: TypeCline, Version: "3.32.1"}},
+		{name: "GitHub Copilot Chat", ua: "GitHubCopilotChat/0.22.4", want: ClientInfo{Type: TypeCopilot, Version: "0.22.4"}},
+		{name: "curl", ua: "curl/8.4.0", want: ClientInfo{Type: TypeCurl, Version: "8.4.0"}},
+		{name: "OpenAI Python SDK", ua: "OpenAI/Python 1.51.0", want: ClientInfo{Type: TypeOpenAISDK, Version: "1.51.0"}},
+		{name: "OpenAI JS SDK", ua: "OpenAI/JS 4.67.3", want: ClientInfo{Type: TypeOpenAISDK, Version: "4.67.3"}},
+		{name: "Anthropic SDK", ua: "Anthropic/Python 0.39.0", want: ClientInfo{Type: TypeAnthropicSDK, Version: "0.39.0"}},
+		{name: "python-requests", ua: "python-requests/2.31.0", want: ClientInfo{Type: TypeHTTPLibrary, Version: "2.31.0"}},
+		{name: "Go http client", ua: "Go-http-client/1.1", want: ClientInfo{Type: TypeHTTPLibrary, Version: "1.1.0"}},
+		{name: "浏览器", ua: "Mozilla/5.0 (Macintosh; Intel Mac OS X 10_15_7) AppleWebKit/537.36 Chrome/120.0 Safari/537.36", want: ClientInfo{Type: TypeBrowser, Platform: PlatformMacOS}},
+		{name: "未知", ua: "my-internal-bot", want: ClientInfo{Type: TypeUnknown}},
+		{name: "空字符串", ua: "   ", want: ClientInfo{Type: TypeUnknown}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require.Equal(t, tt.want, Parse(tt.ua))
+		})
+	}
+}
+
+func TestParse_RuntimeCodexPrefixes(t *testing.T) {
+	t.Cleanup(func() { openai.ConfigureCodexCLIUserAgentPrefixes(nil, false) })
+	openai.ConfigureCodexCLIUserAgentPrefixes([]string{"acme_codex/"}, false)
+
+	info := Parse("acme_codex/2.0.1 (Linux; x86_64)")
+	require.Equal(t, ClientInfo{Type: TypeCodexCLI, Version: "2.0.1", Platform: PlatformLinux}, info)
+	require.True(t, info.IsCodex())
+}
+
+func TestExtractSemver(t *testing.T) {
+	tests := []struct {
+		in   string
+		want string
+	}{
+		{"0.46.0 (Mac OS)", "0.46.0"},
+		{"v1.2.3", "1.2.3"},
+		{"1.2", "1.2.0"},
+		{"2.0.0-rc.1+build", "2.0.0-rc.1"},
+		{" 3.4.5", "3.4.5"},
+		{"abc 1.2.3", ""},
+		{"", ""},
+	}
+	for _, tt := range tests {
+		require.Equal(t, tt.want, ExtractSemver(tt.in), "input=%q", tt.in)
+	}
+}
+
+func TestContextRoundTrip(t *testing.T) {
+	_, ok := FromContext(context.Background())
+	require.False(t, ok)
+
+	ctx := IntoContext(context.Background(), ClientInfo{Type: TypeCodexCLI, Version: "0.46.0"})
+	info, ok := FromContext(ctx)
+	require.True(t, ok)
+	require.Equal(t, TypeCodexCLI, info.Type)
+	require.Equal(t, "0.46.0", info.Version)
+}
+
+func TestAgentClientHelpers(t *testing.T) {
+	require.True(t, IsClaudeCodeRequest("claude-cli/2.1.22 (external, cli)"))
+	require.False(t, IsClaudeCodeRequest("codex_cli_rs/0.46.0"))
+
+	for _, ua := range []string{"Cursor/1.7.38", "Windsurf/1.12.2", "Cline/3.32.1", "GitHubCopilotChat/0.22.4"} {
+		require.True(t, IsAgentIDERequest(ua), "ua=%q", ua)
+		require.True(t, Parse(ua).IsCodingAgent(), "ua=%q", ua)
+	}
+	require.False(t, IsAgentIDERequest("claude-cli/2.1.22"))
+	require.False(t, IsAgentIDERequest("curl/8.4.0"))
+
+	require.True(t, IsCursorRequest("Cursor/1.7.38"))
+	require.True(t, IsWindsurfRequest("Codeium/1.0.0"))
+	require.True(t, IsClineRequest("Cline/3.32.1"))
+	require.True(t, IsCopilotRequest("GitHubCopilotChat/0.22.4"))
+	require.False(t, Parse("OpenAI/Python 1.51.0").IsCodingAgent())
+}
@@ -0,0 +1,109 @@
+package clientdetect
+
+import (
+	"net/http"
+	"strings"
+)
+
+// 参与客户端识别的请求头。
+const (
+	headerOriginator          = "originator"
+	headerCodexVersion        = "version"
+	headerOpenAIBeta          = "OpenAI-Beta"
+	headerAnthropicVersion    = "anthropic-version"
+	headerStainlessPackageVer = "x-stainless-package-version"
+	headerStainlessOS         = "x-stainless-os"
+
+	openAIBetaCodexResponses   = "responses=experimental"
+	openAIBetaCodexResponsesWS = "responses_websockets="
+)
+
+// ParseHeaders 综合 User-Agent 与其他指纹头解析 ClientInfo。
+//
+// 企业代理常改写或清空 UA，此时依次参考：
+//  1. originator（Codex 官方客户端固定携带，如 "codex_cli_rs"、"codex_vscode"）；
+//  2. x-stainless-*（OpenAI / Anthropic 官方 SDK 的 Stainless 指纹）；
+//  3. OpenAI-Beta 中 Codex 专用的 responses 实验开关。
+//
+// UA 已识别出具体客户端（Codex / 编码 Agent）时以 UA 为准，仅补全缺失的版本与平台。
+func ParseHeaders(h http.Header) ClientInfo {
+	if h == nil {
+		return ClientInfo{Type: TypeUnknown}
+	}
+	info := Parse(h.Get("User-Agent"))
+
+	if !info.IsCodingAgent() {
+		if t, ok := codexTypeFromOriginator(h.Get(headerOriginator)); ok {
+			// UA 中的版本属于其他产品（如代理或 HTTP 库），不可沿用。
+			info.Type = t
+			info.Version = ExtractSemver(h.Get(headerCodexVersion))
+		} else if stainlessVersion := strings.TrimSpace(h.Get(headerStainlessPackageVer)); stainlessVersion != "" && isGenericOrUnknown(info.Type) {
+			info.Type = TypeOpenAISDK
+			if strings.TrimSpace(h.Get(headerAnthropicVersion)) != "" {
+				info.Type = TypeAnthropicSDK
+			}
+			info.Version = ExtractSemver(stainlessVersion)
+		} else if isCodexOpenAIBeta(h.Get(headerOpenAIBeta)) && (info.IsUnknown() || info.Type == TypeHTTPLibrary) {
+			info.Type = TypeCodexCLI
+			info.Version = ExtractSemver(h.Get(headerCodexVersion))
+		}
+	}
+
+	if info.Version == "" && info.IsCodex() {
+		info.Version = ExtractSemver(h.Get(headerCodexVersion))
+	}
+	if info.Platform == PlatformUnknown {
+		info.Platform = parseStainlessOS(h.Get(headerStainlessOS))
+	}
+	return info
+}
+
+// codexTypeFromOriginator 将 originator 映射为 Codex 客户端类型。
+// 未知的 "codex_*" originator 统一归为 codex_cli_rs。
+func codexTypeFromOriginator(originator string) (ClientType, bool) {
+	v := strings.ToLower(strings.TrimSpace(originator))
+	if v == "" {
+		return "", false
+	}
+	for _, rule := range codexProductRules {
+		token := strings.TrimRight(rule.token, "/ ")
+		if v == token || strings.HasPrefix(v, rule.token) {
+			return rule.clientType, true
+		}
+	}
+	if strings.HasPrefix(v, "codex_") || strings.HasPrefix(v, "codex ") {
+		return TypeCodexCLI, true
+	}
+	return "", false
+}
+
+// isGenericOrUnknown 判断类型是否为通用客户端（SDK / HTTP 库 / 浏览器）或未识别。
+func isGenericOrUnknown(t ClientType) bool {
+	switch t {
+	case "", TypeUnknown, TypeOpenAISDK, TypeAnthropicSDK, TypeCurl, TypeHTTPLibrary, TypeBrowser:
+		return true
+	default:
+		return false
+	}
+}
+
+func isCodexOpenAIBeta(value string) bool {
+	v := strings.ToLower(value)
+	return strings.Contains(v, openAIBetaCodexResponses) || strings.Contains(v, openAIBetaCodexResponsesWS)
+}
+
+// parseStainlessOS 解析 x-stainless-os（如 "MacOS"、"Linux"、"Windows"、"iOS"、"Android"）。
+func parseStainlessOS(value string) string {
+	v := strings.ToLower(strings.TrimSpace(value))
+	if v == "" {
+		return PlatformUnknown
+	}
+	switch {
+	case v == "ios":
+		return PlatformIOS
+	case strings.HasPrefix(v, "mac"):
+		return PlatformMacOS
+	default:
+		return parsePlatform(v)
+	}
+}
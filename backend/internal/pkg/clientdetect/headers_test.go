@@ -0,0 +1,74 @@
+package clientdetect
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseHeaders(t *testing.T) {
+	tests := []struct {
+		name    string
+		headers map[string]string
+		want    ClientInfo
+	}{
+		{
+			name:    "UA 识别优先并补全版本",
+			headers: map[string]string{"User-Agent": "codex_vscode/dev (Windows 10.0.26100)", "originator": "codex_cli_rs", "version": "0.1.9"},
+			want:    ClientInfo{Type: TypeCodexVSCode, Version: "0.1.9", Platform: PlatformWindows},
+		},
+		{
+			name:    "UA 被代理改写时按 originator 识别",
+			headers: map[string]string{"User-Agent": "Go-http-client/1.1", "originator": "codex_cli_rs", "version": "0.46.0"},
+			want:    ClientInfo{Type: TypeCodexCLI, Version: "0.46.0"},
+		},
+		{
+			name:    "UA 缺失 originator vscode",
+			headers: map[string]string{"originator": "codex_vscode"},
+			want:    ClientInfo{Type: TypeCodexVSCode},
+		},
+		{
+			name:    "未知 codex originator 归为 codex_cli_rs",
+			headers: map[string]string{"originator": "codex_future_client"},
+			want:    ClientInfo{Type: TypeCodexCLI},
+		},
+		{
+			name:    "Stainless 指纹识别 OpenAI SDK",
+			headers: map[string]string{"User-Agent": "corp-proxy/2.0", "x-stainless-package-version": "4.67.3", "x-stainless-os": "MacOS"},
+			want:    ClientInfo{Type: TypeOpenAISDK, Version: "4.67.3", Platform: PlatformMacOS},
+		},
+		{
+			name:    "Stainless 指纹识别 Anthropic SDK",
+			headers: map[string]string{"x-stainless-package-version": "0.39.0", "anthropic-version": "2023-06-01", "x-stainless-os": "Linux"},
+			want:    ClientInfo{Type: TypeAnthropicSDK, Version: "0.39.0", Platform: PlatformLinux},
+		},
+		{
+			name:    "Claude Code UA 不被 Stainless 覆盖",
+			headers: map[string]string{"User-Agent": "claude-cli/2.0.14 (external, cli)", "x-stainless-package-version": "0.60.0", "x-stainless-os": "MacOS"},
+			want:    ClientInfo{Type: TypeClaudeCode, Version: "2.0.14", Platform: PlatformMacOS},
+		},
+		{
+			name:    "OpenAI-Beta responses 实验开关",
+			headers: map[string]string{"User-Agent": "", "OpenAI-Beta": "responses=experimental"},
+			want:    ClientInfo{Type: TypeCodexCLI},
+		},
+		{
+			name:    "普通 curl 不受 OpenAI-Beta 影响",
+			headers: map[string]string{"User-Agent": "curl/8.4.0", "OpenAI-Beta": "responses=experimental"},
+			want:    ClientInfo{Type: TypeCurl, Version: "8.4.0"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := http.Header{}
+			for k, v := range tt.headers {
+				h.Set(k, v)
+			}
+			require.Equal(t, tt.want, ParseHeaders(h))
+		})
+	}
+
+	require.Equal(t, ClientInfo{Type: TypeUnknown}, ParseHeaders(nil))
+}
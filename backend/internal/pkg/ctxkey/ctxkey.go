@@ -23,7 +23,7 @@ const (
 	// AccountID 当前请求最终命中的账号 ID（用于统一请求链路日志字段）。
 	AccountID Key = "ctx_account_id"
 
-	// RetryCount 表示当前请求在网关层的重试次数（用于 Ops 记录与排障）。
+	// RetryCount 表示当前请求在网关层的上游重试次数（*atomic.Int32，由 service.WithUpstreamRetryCounter 挂载）。
 	RetryCount Key = "ctx_retry_count"
 
 	// AccountSwitchCount 表示请求过程中发生的账号切换次数
@@ -55,4 +55,18 @@ const (
 
 	// ClaudeCodeVersion stores the extracted Claude Code version from User-Agent (e.g. "2.1.22")
 	ClaudeCodeVersion Key = "ctx_claude_code_version"
+
+	// ClientInfo 下游客户端解析结果（clientdetect.ClientInfo），由 middleware.ClientDetection 设置
+	ClientInfo Key = "ctx_client_info"
+
+	// HookInfo 请求扩展点使用的请求元信息（*hooks.Info），供 pre_upstream 阶段读取
+	HookInfo Key = "ctx_hook_info"
+
+	// RequestedModel 模型别名改写前客户端请求的模型名，由 middleware.ModelAlias 设置
+	RequestedModel Key = "ctx_requested_model"
+
+	// ReasoningEffort 请求的推理强度（low / medium / high / xhigh），由 OpenAI 网关 handler 设置，供账号调度按订阅计划过滤
+	ReasoningEffort Key = "ctx_reasoning_effort"
+	// Simulate 标识当前请求为演练请求（gateway.simulation 或 Key 的 simulate），上游调用由内置模拟上游应答
+	Simulate Key = "ctx_simulate"
 )
@@ -0,0 +1,105 @@
+package hooks
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os/exec"
+	"strings"
+)
+
+// maxDecisionBytes bounds the decision read from a webhook or script.
+const maxDecisionBytes = 16 << 20
+
+// webhookHook POSTs the request description as JSON and decodes the response
+// body as a Decision. An empty 2xx body lets the request continue.
+type webhookHook struct {
+	url     string
+	headers map[string]string
+	client  *http.Client
+}
+
+func newWebhookHook(url string, headers map[string]string, client *http.Client) *webhookHook {
+	if client == nil {
+		client = http.DefaultClient
+	}
+	return &webhookHook{url: url, headers: headers, client: client}
+}
+
+func (h *webhookHook) Handle(ctx context.Context, req *Request) (*Decision, error) {
+	payload, err := json.Marshal(req.redacted())
+	if err != nil {
+		return nil, err
+	}
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, h.url, bytes.NewReader(payload))
+	if err != nil {
+		return nil, err
+	}
+	httpReq.Header.Set("Content-Type", "application/json")
+	for name, value := range h.headers {
+		httpReq.Header.Set(name, value)
+	}
+	resp, err := h.client.Do(httpReq)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = resp.Body.Close() }()
+	body, err := io.ReadAll(io.LimitReader(resp.Body, maxDecisionBytes))
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return nil, fmt.Errorf("webhook returned HTTP %d", resp.StatusCode)
+	}
+	return decodeDecision(body)
+}
+
+// scriptHook runs a command with the request description on stdin and
+// decodes stdout as a Decision. A non-zero exit status is an error.
+type scriptHook struct {
+	command string
+	args    []string
+}
+
+func newScriptHook(command string, args []string) *scriptHook {
+	return &scriptHook{command: command, args: args}
+}
+
+func (h *scriptHook) Handle(ctx context.Context, req *Request) (*Decision, error) {
+	payload, err := json.Marshal(req.redacted())
+	if err != nil {
+		return nil, err
+	}
+	cmd := exec.CommandContext(ctx, h.command, h.args...)
+	cmd.Stdin = bytes.NewReader(payload)
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			if len(msg) > 512 {
+				msg = msg[:512]
+			}
+			return nil, fmt.Errorf("%w: %s", err, msg)
+		}
+		return nil, err
+	}
+	if stdout.Len() > maxDecisionBytes {
+		return nil, fmt.Errorf("script output exceeds %d bytes", maxDecisionBytes)
+	}
+	return decodeDecision(stdout.Bytes())
+}
+
+func decodeDecision(body []byte) (*Decision, error) {
+	if len(bytes.TrimSpace(body)) == 0 {
+		return nil, nil
+	}
+	var decision Decision
+	if err := json.Unmarshal(body, &decision); err != nil {
+		return nil, fmt.Errorf("decode decision: %w", err)
+	}
+	return &decision, nil
+}
@@ -0,0 +1,173 @@
+// Package hooks implements the gateway extension points. Deployments attach
+// Go extensions, webhooks or external scripts to a request stage to inspect
+// and rewrite requests (or reject them) without changing gateway code.
+//
+// Go extensions register a factory from an init function:
+//
+//	func init() {
+//		hooks.Register("pii-scrub", func(opts map[string]any) (hooks.Hook, error) {
+//			return hooks.HookFunc(scrub), nil
+//		})
+//	}
+//
+// The package is linked in with a blank import in a custom build, or built
+// as a Go plugin and loaded through the handler's plugin_path.
+package hooks
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// Stage identifies an extension point in the request lifecycle.
+type Stage string
+
+const (
+	// StagePreAuth runs before API key authentication on the raw client request.
+	StagePreAuth Stage = "pre_auth"
+	// StagePreTranslation runs after authentication and before the request is
+	// translated to the upstream protocol. The body is in the client's format.
+	StagePreTranslation Stage = "pre_translation"
+	// StagePreUpstream runs right before every upstream attempt (including
+	// retries and account failover). The body is in the upstream format.
+	StagePreUpstream Stage = "pre_upstream"
+	// StagePostResponse runs asynchronously after the response was written.
+	// Decisions are ignored.
+	StagePostResponse Stage = "post_response"
+)
+
+// Stages lists every stage in lifecycle order.
+var Stages = []Stage{StagePreAuth, StagePreTranslation, StagePreUpstream, StagePostResponse}
+
+// Request describes the request at a stage. Hooks may read everything; only
+// changes expressed through a Decision are applied.
+type Request struct {
+	Stage     Stage             `json:"stage"`
+	RequestID string            `json:"request_id,omitempty"`
+	Method    string            `json:"method"`
+	Path      string            `json:"path"`          // inbound path
+	URL       string            `json:"url,omitempty"` // upstream URL without query (pre_upstream only)
+	Headers   map[string]string `json:"headers"`
+	Body      string            `json:"body,omitempty"`
+
+	APIKeyID  int64  `json:"api_key_id,omitempty"`
+	UserID    int64  `json:"user_id,omitempty"`
+	GroupID   int64  `json:"group_id,omitempty"`
+	Platform  string `json:"platform,omitempty"`
+	Model     string `json:"model,omitempty"`
+	AccountID int64  `json:"account_id,omitempty"` // pre_upstream only
+
+	StatusCode int   `json:"status_code,omitempty"` // post_response only
+	DurationMs int64 `json:"duration_ms,omitempty"` // post_response only
+}
+
+// Decision is a hook's answer. A nil Decision lets the request continue
+// unchanged.
+type Decision struct {
+	// Reject stops the request. Status defaults to 403.
+	Reject  bool   `json:"reject,omitempty"`
+	Status  int    `json:"status,omitempty"`
+	Message string `json:"message,omitempty"`
+
+	// SetHeaders adds or replaces request headers; RemoveHeaders deletes them.
+	SetHeaders    map[string]string `json:"set_headers,omitempty"`
+	RemoveHeaders []string          `json:"remove_headers,omitempty"`
+	// Body replaces the request body when non-nil.
+	Body *string `json:"body,omitempty"`
+}
+
+// Hook handles one stage invocation.
+type Hook interface {
+	Handle(ctx context.Context, req *Request) (*Decision, error)
+}
+
+// HookFunc adapts a function to Hook.
+type HookFunc func(ctx context.Context, req *Request) (*Decision, error)
+
+// Handle calls f(ctx, req).
+func (f HookFunc) Handle(ctx context.Context, req *Request) (*Decision, error) {
+	return f(ctx, req)
+}
+
+// Factory builds a Go extension from the handler's options.
+type Factory func(options map[string]any) (Hook, error)
+
+var registry = struct {
+	mu        sync.RWMutex
+	factories map[string]Factory
+}{factories: make(map[string]Factory)}
+
+// Register makes a Go extension available under name. It panics when the
+// name is empty, the factory is nil or the name is already registered.
+func Register(name string, factory Factory) {
+	name = strings.TrimSpace(name)
+	if name == "" || factory == nil {
+		panic("hooks: Register requires a name and a factory")
+	}
+	registry.mu.Lock()
+	defer registry.mu.Unlock()
+	if _, dup := registry.factories[name]; dup {
+		panic("hooks: Register called twice for " + name)
+	}
+	registry.factories[name] = factory
+}
+
+// Registered returns the sorted names of registered Go extensions.
+func Registered() []string {
+	registry.mu.RLock()
+	defer registry.mu.RUnlock()
+	names := make([]string, 0, len(registry.factories))
+	for name := range registry.factories {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func lookup(name string) (Factory, error) {
+	registry.mu.RLock()
+	defer registry.mu.RUnlock()
+	factory, ok := registry.factories[name]
+	if !ok {
+		return nil, fmt.Errorf("hooks: no Go extension registered as %q", name)
+	}
+	return factory, nil
+}
+
+// HeaderMap flattens h to the first value of each header.
+func HeaderMap(h http.Header) map[string]string {
+	out := make(map[string]string, len(h))
+	for name, values := range h {
+		if len(values) > 0 {
+			out[name] = values[0]
+		}
+	}
+	return out
+}
+
+// sensitiveHeaders are redacted before a request leaves the process.
+var sensitiveHeaders = map[string]struct{}{
+	"Authorization":       {},
+	"Proxy-Authorization": {},
+	"X-Api-Key":           {},
+	"X-Goog-Api-Key":      {},
+	"Api-Key":             {},
+	"Cookie":              {},
+}
+
+// redacted returns a shallow copy of req with credential headers masked.
+func (r *Request) redacted() *Request {
+	cp := *r
+	cp.Headers = make(map[string]string, len(r.Headers))
+	for name, value := range r.Headers {
+		if _, ok := sensitiveHeaders[http.CanonicalHeaderKey(name)]; ok && value != "" {
+			value = "[REDACTED]"
+		}
+		cp.Headers[name] = value
+	}
+	return &cp
+}
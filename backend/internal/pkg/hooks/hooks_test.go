@@ -0,0 +1,233 @@
+package hooks
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os/exec"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func registerTestHook(t *testing.T, name string, fn HookFunc) {
+	t.Helper()
+	registry.mu.Lock()
+	registry.factories[name] = func(map[string]any) (Hook, error) { return fn, nil }
+	registry.mu.Unlock()
+	t.Cleanup(func() {
+		registry.mu.Lock()
+		delete(registry.factories, name)
+		registry.mu.Unlock()
+	})
+}
+
+func strPtr(s string) *string { return &s }
+
+func TestRunnerRunAppliesDecisionsInOrder(t *testing.T) {
+	registerTestHook(t, "t-first", func(ctx context.Context, req *Request) (*Decision, error) {
+		return &Decision{SetHeaders: map[string]string{"x-tenant": "a"}, RemoveHeaders: []string{"X-Debug"}, Body: strPtr(`{"model":"m2"}`)}, nil
+	})
+	var seen *Request
+	registerTestHook(t, "t-second", func(ctx context.Context, req *Request) (*Decision, error) {
+		cp := *req
+		seen = &cp
+		return nil, nil
+	})
+	runner, err := NewRunner([]Spec{
+		{Name: "first", Type: TypeGo, Plugin: "t-first", Stages: []Stage{StagePreTranslation}},
+		{Name: "second", Type: TypeGo, Plugin: "t-second", Stages: []Stage{StagePreTranslation}},
+		{Name: "other-path", Type: TypeGo, Plugin: "t-first", Stages: []Stage{StagePreTranslation}, Paths: []string{"/v1beta/"}},
+	}, nil)
+	require.NoError(t, err)
+	require.True(t, runner.Has(StagePreTranslation))
+	require.False(t, runner.Has(StagePreAuth))
+
+	out := runner.Run(context.Background(), &Request{
+		Stage:   StagePreTranslation,
+		Path:    "/v1/messages",
+		Headers: map[string]string{"X-Debug": "1"},
+		Body:    `{"model":"m1"}`,
+	})
+	require.Nil(t, out.Rejected)
+	require.True(t, out.BodyChanged)
+	require.Equal(t, `{"model":"m2"}`, string(out.Body))
+	require.Equal(t, map[string]string{"X-Tenant": "a"}, out.SetHeaders)
+	require.Equal(t, []string{"X-Debug"}, out.RemoveHeaders)
+	require.Equal(t, `{"model":"m2"}`, seen.Body)
+	require.Equal(t, map[string]string{"X-Tenant": "a"}, seen.Headers)
+
+	h := http.Header{"X-Debug": {"1"}}
+	out.ApplyHeaders(h)
+	require.Equal(t, "a", h.Get("X-Tenant"))
+	require.Empty(t, h.Get("X-Debug"))
+}
+
+func TestRunnerRunRejectAndFailure(t *testing.T) {
+	registerTestHook(t, "t-reject", func(ctx context.Context, req *Request) (*Decision, error) {
+		return &Decision{Reject: true}, nil
+	})
+	registerTestHook(t, "t-fail", func(ctx context.Context, req *Request) (*Decision, error) {
+		return nil, errors.New("boom")
+	})
+	registerTestHook(t, "t-panic", func(ctx context.Context, req *Request) (*Decision, error) {
+		panic("bad hook")
+	})
+	registerTestHook(t, "t-slow", func(ctx context.Context, req *Request) (*Decision, error) {
+		<-ctx.Done()
+		return nil, ctx.Err()
+	})
+
+	run := func(specs ...Spec) *Outcome {
+		runner, err := NewRunner(specs, nil)
+		require.NoError(t, err)
+		return runner.Run(context.Background(), &Request{Stage: StagePreAuth, Path: "/v1/messages"})
+	}
+
+	out := run(Spec{Name: "r", Type: TypeGo, Plugin: "t-reject", Stages: []Stage{StagePreAuth}})
+	require.Equal(t, http.StatusForbidden, out.Rejected.Status)
+	require.Equal(t, "Request rejected by policy", out.Rejected.Message)
+
+	out = run(Spec{Name: "f", Type: TypeGo, Plugin: "t-fail", Stages: []Stage{StagePreAuth}})
+	require.Equal(t, http.StatusServiceUnavailable, out.Rejected.Status)
+
+	out = run(Spec{Name: "p", Type: TypeGo, Plugin: "t-panic", Stages: []Stage{StagePreAuth}, FailOpen: true},
+		Spec{Name: "s", Type: TypeGo, Plugin: "t-slow", Stages: []Stage{StagePreAuth}, FailOpen: true, Timeout: 10 * time.Millisecond})
+	require.Nil(t, out.Rejected)
+
+	var nilRunner *Runner
+	require.False(t, nilRunner.Has(StagePreAuth))
+	require.Nil(t, nilRunner.Run(context.Background(), &Request{Stage: StagePreAuth}).Rejected)
+}
+
+func TestNewRunnerUnknownPlugin(t *testing.T) {
+	_, err := NewRunner([]Spec{{Name: "x", Type: TypeGo, Plugin: "missing", Stages: []Stage{StagePreAuth}}}, nil)
+	require.ErrorContains(t, err, `no Go extension registered as "missing"`)
+
+	_, err = NewRunner([]Spec{{Name: "x", Type: TypeGo, Plugin: "missing", PluginPath: "/nonexistent/hook.so", Stages: []Stage{StagePreAuth}}}, nil)
+	require.ErrorContains(t, err, "open Go plugin")
+}
+
+func TestWebhookHook(t *testing.T) {
+	var got Request
+	var auth string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		auth = r.Header.Get("Authorization")
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&got))
+		if got.Path == "/deny" {
+			_, _ = w.Write([]byte(`{"reject":true,"status":451,"message":"blocked"}`))
+			return
+		}
+		if got.Path == "/broken" {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+	}))
+	defer srv.Close()
+
+	hook := newWebhookHook(srv.URL, map[string]string{"Authorization": "Bearer hook-secret"}, srv.Client())
+	decision, err := hook.Handle(context.Background(), &Request{
+		Stage:   StagePreUpstream,
+		Path:    "/v1/messages",
+		Headers: map[string]string{"Authorization": "Bearer upstream-token", "X-Api-Key": "sk-1", "Content-Type": "application/json"},
+	})
+	require.NoError(t, err)
+	require.Nil(t, decision)
+	require.Equal(t, "Bearer hook-secret", auth)
+	require.Equal(t, "[REDACTED]", got.Headers["Authorization"])
+	require.Equal(t, "[REDACTED]", got.Headers["X-Api-Key"])
+	require.Equal(t, "application/json", got.Headers["Content-Type"])
+
+	decision, err = hook.Handle(context.Background(), &Request{Path: "/deny"})
+	require.NoError(t, err)
+	require.True(t, decision.Reject)
+	require.Equal(t, 451, decision.Status)
+
+	_, err = hook.Handle(context.Background(), &Request{Path: "/broken"})
+	require.ErrorContains(t, err, "HTTP 500")
+}
+
+func TestScriptHook(t *testing.T) {
+	if _, err := exec.LookPath("sh"); err != nil {
+		t.Skip("sh not available")
+	}
+	hook := newScriptHook("sh", []string{"-c", `grep -q '"path":"/deny"' && echo '{"reject":true,"message":"nope"}' || true`})
+	decision, err := hook.Handle(context.Background(), &Request{Path: "/deny"})
+	require.NoError(t, err)
+	require.True(t, decision.Reject)
+	require.Equal(t, "nope", decision.Message)
+
+	decision, err = hook.Handle(context.Background(), &Request{Path: "/ok"})
+	require.NoError(t, err)
+	require.Nil(t, decision)
+
+	_, err = newScriptHook("sh", []string{"-c", "echo failed >&2; exit 3"}).Handle(context.Background(), &Request{})
+	require.ErrorContains(t, err, "failed")
+}
+
+type upstreamStub struct {
+	body   string
+	header http.Header
+	calls  int
+}
+
+func (u *upstreamStub) Do(req *http.Request, proxyURL string, accountID int64, accountConcurrency int) (*http.Response, error) {
+	u.calls++
+	raw, _ := io.ReadAll(req.Body)
+	u.body = string(raw)
+	u.header = req.Header.Clone()
+	return &http.Response{StatusCode: http.StatusOK, Body: io.NopCloser(strings.NewReader(""))}, nil
+}
+
+func (u *upstreamStub) DoWithTLS(req *http.Request, proxyURL string, accountID int64, accountConcurrency int, enableTLSFingerprint bool) (*http.Response, error) {
+	return u.Do(req, proxyURL, accountID, accountConcurrency)
+}
+
+func TestWrapUpstream(t *testing.T) {
+	var seen Request
+	registerTestHook(t, "t-upstream", func(ctx context.Context, req *Request) (*Decision, error) {
+		seen = *req
+		if req.Model == "blocked" {
+			return &Decision{Reject: true, Message: "model blocked"}, nil
+		}
+		return &Decision{SetHeaders: map[string]string{"X-Policy": "on"}, Body: strPtr(`{"patched":true}`)}, nil
+	})
+	next := &upstreamStub{}
+	require.Same(t, next, WrapUpstream(next, nil))
+
+	runner, err := NewRunner([]Spec{{Name: "u", Type: TypeGo, Plugin: "t-upstream", Stages: []Stage{StagePreUpstream}}}, nil)
+	require.NoError(t, err)
+	wrapped := WrapUpstream(next, runner)
+
+	// 非网关请求（无 Info）不执行扩展
+	req, _ := http.NewRequest(http.MethodPost, "https://api.example.com/v1/messages?key=secret", strings.NewReader(`{"a":1}`))
+	_, err = wrapped.Do(req, "", 7, 1)
+	require.NoError(t, err)
+	require.Equal(t, `{"a":1}`, next.body)
+	require.Empty(t, seen.Stage)
+
+	ctx := WithInfo(context.Background(), &Info{Path: "/v1/messages", APIKeyID: 3, Model: "claude"})
+	req, _ = http.NewRequestWithContext(ctx, http.MethodPost, "https://api.example.com/v1/messages?key=secret", strings.NewReader(`{"a":1}`))
+	_, err = wrapped.DoWithTLS(req, "", 7, 1, false)
+	require.NoError(t, err)
+	require.Equal(t, `{"patched":true}`, next.body)
+	require.Equal(t, "on", next.header.Get("X-Policy"))
+	require.Equal(t, StagePreUpstream, seen.Stage)
+	require.Equal(t, "https://api.example.com/v1/messages", seen.URL)
+	require.Equal(t, int64(7), seen.AccountID)
+	require.Equal(t, `{"a":1}`, seen.Body)
+
+	calls := next.calls
+	ctx = WithInfo(context.Background(), &Info{Path: "/v1/messages", Model: "blocked"})
+	req, _ = http.NewRequestWithContext(ctx, http.MethodPost, "https://api.example.com/v1/messages", strings.NewReader(`{}`))
+	_, err = wrapped.Do(req, "", 7, 1)
+	var rejected *RejectedError
+	require.ErrorAs(t, err, &rejected)
+	require.Equal(t, "model blocked", rejected.Message)
+	require.Equal(t, calls, next.calls)
+}
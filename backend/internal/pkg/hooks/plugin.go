@@ -0,0 +1,24 @@
+package hooks
+
+import (
+	"fmt"
+	"plugin"
+	"sync"
+)
+
+var loadedPlugins sync.Map // path -> struct{}
+
+// loadPlugin opens a Go plugin once. Opening runs the plugin's init
+// functions, which are expected to call Register. Go plugins require a
+// CGO-enabled build of the gateway using the same toolchain and module
+// versions as the plugin.
+func loadPlugin(path string) error {
+	if _, ok := loadedPlugins.Load(path); ok {
+		return nil
+	}
+	if _, err := plugin.Open(path); err != nil {
+		return fmt.Errorf("open Go plugin %s: %w", path, err)
+	}
+	loadedPlugins.Store(path, struct{}{})
+	return nil
+}
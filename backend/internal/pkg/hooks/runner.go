@@ -0,0 +1,247 @@
+package hooks
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/ShaohongDong/sub2api/internal/pkg/logger"
+	"go.uber.org/zap"
+)
+
+// Handler types accepted in Spec.Type.
+const (
+	TypeGo      = "go"
+	TypeWebhook = "webhook"
+	TypeScript  = "script"
+)
+
+const (
+	defaultTimeout       = 2 * time.Second
+	postResponseParallel = 64
+)
+
+// Spec configures one handler.
+type Spec struct {
+	Name     string
+	Type     string
+	Stages   []Stage
+	Paths    []string // inbound path prefixes; empty matches every path
+	Timeout  time.Duration
+	FailOpen bool
+
+	Plugin     string
+	PluginPath string
+	Options    map[string]any
+
+	URL     string
+	Headers map[string]string
+
+	Command string
+	Args    []string
+}
+
+type boundHook struct {
+	name     string
+	hook     Hook
+	paths    []string
+	timeout  time.Duration
+	failOpen bool
+}
+
+func (b *boundHook) matches(path string) bool {
+	if len(b.paths) == 0 {
+		return true
+	}
+	for _, prefix := range b.paths {
+		if strings.HasPrefix(path, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// Outcome is the combined effect of all hooks of a stage.
+type Outcome struct {
+	// Rejected is set when a hook rejected the request (or failed closed).
+	Rejected *Decision
+
+	SetHeaders    map[string]string
+	RemoveHeaders []string
+	Body          []byte
+	BodyChanged   bool
+}
+
+// ApplyHeaders applies the header changes to h.
+func (o *Outcome) ApplyHeaders(h http.Header) {
+	for _, name := range o.RemoveHeaders {
+		h.Del(name)
+	}
+	for name, value := range o.SetHeaders {
+		h.Set(name, value)
+	}
+}
+
+// Runner dispatches stage invocations to the configured handlers. A nil
+// Runner has no hooks.
+type Runner struct {
+	stages map[Stage][]*boundHook
+	async  chan struct{}
+}
+
+// NewRunner builds the handlers in specs. Go plugins are loaded and Go
+// extensions constructed here, so configuration errors surface at startup.
+func NewRunner(specs []Spec, client *http.Client) (*Runner, error) {
+	r := &Runner{
+		stages: make(map[Stage][]*boundHook),
+		async:  make(chan struct{}, postResponseParallel),
+	}
+	for _, spec := range specs {
+		hook, err := buildHook(spec, client)
+		if err != nil {
+			return nil, fmt.Errorf("hook %q: %w", spec.Name, err)
+		}
+		timeout := spec.Timeout
+		if timeout <= 0 {
+			timeout = defaultTimeout
+		}
+		bound := &boundHook{name: spec.Name, hook: hook, paths: spec.Paths, timeout: timeout, failOpen: spec.FailOpen}
+		for _, stage := range spec.Stages {
+			r.stages[stage] = append(r.stages[stage], bound)
+		}
+	}
+	return r, nil
+}
+
+func buildHook(spec Spec, client *http.Client) (Hook, error) {
+	switch spec.Type {
+	case TypeGo:
+		if spec.PluginPath != "" {
+			if err := loadPlugin(spec.PluginPath); err != nil {
+				return nil, err
+			}
+		}
+		factory, err := lookup(spec.Plugin)
+		if err != nil {
+			return nil, err
+		}
+		return factory(spec.Options)
+	case TypeWebhook:
+		return newWebhookHook(spec.URL, spec.Headers, client), nil
+	case TypeScript:
+		return newScriptHook(spec.Command, spec.Args), nil
+	default:
+		return nil, fmt.Errorf("unknown hook type %q", spec.Type)
+	}
+}
+
+// Has reports whether any handler is attached to stage.
+func (r *Runner) Has(stage Stage) bool {
+	return r != nil && len(r.stages[stage]) > 0
+}
+
+// Run invokes the handlers of req.Stage whose paths match req.Path, in
+// order. Changes made by one handler are visible to the next. Running stops
+// at the first rejection.
+func (r *Runner) Run(ctx context.Context, req *Request) *Outcome {
+	out := &Outcome{}
+	if r == nil {
+		return out
+	}
+	for _, b := range r.stages[req.Stage] {
+		if !b.matches(req.Path) {
+			continue
+		}
+		decision, err := b.call(ctx, req)
+		if err != nil {
+			if b.failOpen {
+				logger.L().Warn("hooks: handler failed, letting request through",
+					zap.String("hook", b.name), zap.String("stage", string(req.Stage)), zap.Error(err))
+				continue
+			}
+			logger.L().Warn("hooks: handler failed, rejecting request",
+				zap.String("hook", b.name), zap.String("stage", string(req.Stage)), zap.Error(err))
+			out.Rejected = &Decision{Reject: true, Status: http.StatusServiceUnavailable, Message: "Request policy check is unavailable"}
+			return out
+		}
+		if decision == nil {
+			continue
+		}
+		if decision.Reject {
+			rejected := *decision
+			if rejected.Status < 400 || rejected.Status > 599 {
+				rejected.Status = http.StatusForbidden
+			}
+			if rejected.Message == "" {
+				rejected.Message = "Request rejected by policy"
+			}
+			out.Rejected = &rejected
+			return out
+		}
+		out.merge(req, decision)
+	}
+	return out
+}
+
+// RunAsync runs the handlers in the background without waiting. It is used
+// for post_response, where decisions are ignored. Invocations are dropped
+// when too many are already in flight.
+func (r *Runner) RunAsync(req *Request) {
+	if !r.Has(req.Stage) {
+		return
+	}
+	select {
+	case r.async <- struct{}{}:
+	default:
+		logger.L().Warn("hooks: too many pending invocations, dropping", zap.String("stage", string(req.Stage)))
+		return
+	}
+	go func() {
+		defer func() { <-r.async }()
+		r.Run(context.Background(), req)
+	}()
+}
+
+func (b *boundHook) call(ctx context.Context, req *Request) (decision *Decision, err error) {
+	ctx, cancel := context.WithTimeout(ctx, b.timeout)
+	defer cancel()
+	defer func() {
+		if p := recover(); p != nil {
+			decision, err = nil, fmt.Errorf("panic: %v", p)
+		}
+	}()
+	return b.hook.Handle(ctx, req)
+}
+
+func (o *Outcome) merge(req *Request, d *Decision) {
+	if req.Headers == nil {
+		req.Headers = make(map[string]string)
+	}
+	for _, name := range d.RemoveHeaders {
+		name = http.CanonicalHeaderKey(strings.TrimSpace(name))
+		if name == "" {
+			continue
+		}
+		delete(req.Headers, name)
+		delete(o.SetHeaders, name)
+		o.RemoveHeaders = append(o.RemoveHeaders, name)
+	}
+	for name, value := range d.SetHeaders {
+		name = http.CanonicalHeaderKey(strings.TrimSpace(name))
+		if name == "" {
+			continue
+		}
+		if o.SetHeaders == nil {
+			o.SetHeaders = make(map[string]string)
+		}
+		req.Headers[name] = value
+		o.SetHeaders[name] = value
+	}
+	if d.Body != nil {
+		req.Body = *d.Body
+		o.Body = []byte(*d.Body)
+		o.BodyChanged = true
+	}
+}
@@ -0,0 +1,131 @@
+package hooks
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+
+	"github.com/ShaohongDong/sub2api/internal/pkg/ctxkey"
+)
+
+// Info is the gateway request metadata that pre_upstream hooks see. The
+// gateway middleware attaches it to the request context; upstream calls
+// without it (account tests, usage probes, background jobs) skip the hooks.
+type Info struct {
+	RequestID string
+	Path      string
+	APIKeyID  int64
+	UserID    int64
+	GroupID   int64
+	Platform  string
+	Model     string
+}
+
+// WithInfo attaches info to ctx.
+func WithInfo(ctx context.Context, info *Info) context.Context {
+	return context.WithValue(ctx, ctxkey.HookInfo, info)
+}
+
+// InfoFromContext returns the Info attached by WithInfo, or nil.
+func InfoFromContext(ctx context.Context) *Info {
+	info, _ := ctx.Value(ctxkey.HookInfo).(*Info)
+	return info
+}
+
+// RejectedError is returned by a wrapped upstream when a pre_upstream hook
+// rejected the request. Gateways treat it like any other request error.
+type RejectedError struct {
+	Status  int
+	Message string
+}
+
+func (e *RejectedError) Error() string {
+	return "rejected by pre_upstream hook: " + e.Message
+}
+
+// Upstream mirrors the gateway's HTTP upstream port.
+type Upstream interface {
+	Do(req *http.Request, proxyURL string, accountID int64, accountConcurrency int) (*http.Response, error)
+	DoWithTLS(req *http.Request, proxyURL string, accountID int64, accountConcurrency int, enableTLSFingerprint bool) (*http.Response, error)
+}
+
+// WrapUpstream runs pre_upstream hooks before each upstream call. It returns
+// upstream unchanged when no pre_upstream hook is configured.
+func WrapUpstream(upstream Upstream, runner *Runner) Upstream {
+	if !runner.Has(StagePreUpstream) {
+		return upstream
+	}
+	return &hookedUpstream{next: upstream, runner: runner}
+}
+
+type hookedUpstream struct {
+	next   Upstream
+	runner *Runner
+}
+
+func (u *hookedUpstream) Do(req *http.Request, proxyURL string, accountID int64, accountConcurrency int) (*http.Response, error) {
+	if err := u.before(req, accountID); err != nil {
+		return nil, err
+	}
+	return u.next.Do(req, proxyURL, accountID, accountConcurrency)
+}
+
+func (u *hookedUpstream) DoWithTLS(req *http.Request, proxyURL string, accountID int64, accountConcurrency int, enableTLSFingerprint bool) (*http.Response, error) {
+	if err := u.before(req, accountID); err != nil {
+		return nil, err
+	}
+	return u.next.DoWithTLS(req, proxyURL, accountID, accountConcurrency, enableTLSFingerprint)
+}
+
+func (u *hookedUpstream) before(req *http.Request, accountID int64) error {
+	info := InfoFromContext(req.Context())
+	if info == nil {
+		return nil
+	}
+	var body []byte
+	if req.Body != nil && req.Body != http.NoBody {
+		var err error
+		body, err = io.ReadAll(req.Body)
+		_ = req.Body.Close()
+		if err != nil {
+			return err
+		}
+		setRequestBody(req, body)
+	}
+
+	target := *req.URL
+	target.RawQuery = ""
+	target.User = nil
+	out := u.runner.Run(req.Context(), &Request{
+		Stage:     StagePreUpstream,
+		RequestID: info.RequestID,
+		Method:    req.Method,
+		Path:      info.Path,
+		URL:       target.String(),
+		Headers:   HeaderMap(req.Header),
+		Body:      string(body),
+		APIKeyID:  info.APIKeyID,
+		UserID:    info.UserID,
+		GroupID:   info.GroupID,
+		Platform:  info.Platform,
+		Model:     info.Model,
+		AccountID: accountID,
+	})
+	if out.Rejected != nil {
+		return &RejectedError{Status: out.Rejected.Status, Message: out.Rejected.Message}
+	}
+	out.ApplyHeaders(req.Header)
+	if out.BodyChanged {
+		setRequestBody(req, out.Body)
+	}
+	return nil
+}
+
+func setRequestBody(req *http.Request, body []byte) {
+	req.Body = io.NopCloser(bytes.NewReader(body))
+	req.ContentLength = int64(len(body))
+	req.GetBody = func() (io.ReadCloser, error) {
+		return io.NopCloser(bytes.NewReader(body)), nil
+	}
+}
@@ -2,13 +2,17 @@ package httputil
 
 import (
 	"bytes"
+	"fmt"
 	"io"
 	"net/http"
 )
 
 const (
-	requestBodyReadInitCap    = 512
-	requestBodyReadMaxInitCap = 1 << 20
+	requestBodyReadInitCap = 512
+	// requestBodyReadMaxInitCap 按 Content-Length 预分配的上限。
+	// 声明长度超过路由限制的请求已在 RequestBodyLimit 中被拒绝，预分配不会超过配置的请求体上限；
+	// 一次分配到位可避免大请求体（如内联图片）在 bytes.Buffer 倍增扩容中产生多份副本。
+	requestBodyReadMaxInitCap = 64 << 20
 )
 
 // ReadRequestBodyWithPrealloc reads request body with preallocated buffer based on content length.
@@ -25,7 +29,8 @@ func ReadRequestBodyWithPrealloc(req *http.Request) ([]byte, error) {
 		case req.ContentLength > int64(requestBodyReadMaxInitCap):
 			capHint = requestBodyReadMaxInitCap
 		default:
-			capHint = int(req.ContentLength)
+			// 额外预留 MinRead，读到 EOF 时 bytes.Buffer 无需再扩容
+			capHint = int(req.ContentLength) + bytes.MinRead
 		}
 	}
 
@@ -35,3 +40,12 @@ func ReadRequestBodyWithPrealloc(req *http.Request) ([]byte, error) {
 	}
 	return buf.Bytes(), nil
 }
+
+// BodyTooLargeMessage 请求体超限时返回给客户端的错误信息
+func BodyTooLargeMessage(limit int64) string {
+	const mb = 1024 * 1024
+	if limit >= mb {
+		return fmt.Sprintf("Request body too large, limit is %dMB", limit/mb)
+	}
+	return fmt.Sprintf("Request body too large, limit is %dB", limit)
+}
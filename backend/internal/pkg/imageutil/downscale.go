@@ -0,0 +1,160 @@
+// Package imageutil downscales and recompresses inline images so that vision
+// requests cost fewer upstream image tokens.
+package imageutil
+
+import (
+	"bytes"
+	"encoding/base64"
+	"errors"
+	"image"
+	"image/draw"
+	_ "image/gif" // register GIF decoder
+	"image/jpeg"
+	"image/png"
+	"strings"
+)
+
+// maxDecodePixels guards against decompression bombs.
+const maxDecodePixels = 40_000_000
+
+// ErrTooLarge is returned when an image exceeds maxDecodePixels.
+var ErrTooLarge = errors.New("image too large to decode")
+
+// Options controls Downscale.
+type Options struct {
+	// MaxDimension is the longest allowed side in pixels.
+	MaxDimension int
+	// JPEGQuality is used when re-encoding opaque images.
+	JPEGQuality int
+	// MinBytes skips images smaller than this that are within MaxDimension.
+	MinBytes int
+}
+
+// Downscale shrinks data so its longest side fits opts.MaxDimension and
+// re-encodes it (JPEG when opaque, PNG otherwise). Formats the standard
+// library cannot decode (e.g. WebP) are returned unchanged. changed is false
+// when the result would not be smaller than the input.
+func Downscale(data []byte, opts Options) (out []byte, mediaType string, changed bool, err error) {
+	cfg, format, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		// Unsupported or malformed images are left for the upstream to judge.
+		return data, "", false, nil
+	}
+	if cfg.Width <= 0 || cfg.Height <= 0 {
+		return data, "", false, nil
+	}
+	if cfg.Width*cfg.Height > maxDecodePixels {
+		return data, "", false, ErrTooLarge
+	}
+	oversized := opts.MaxDimension > 0 && max(cfg.Width, cfg.Height) > opts.MaxDimension
+	if !oversized && len(data) < opts.MinBytes {
+		return data, "", false, nil
+	}
+
+	src, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return data, "", false, nil
+	}
+	dst := toRGBA(src)
+	if oversized {
+		w, h := fitWithin(cfg.Width, cfg.Height, opts.MaxDimension)
+		dst = resizeBox(dst, w, h)
+	}
+
+	var buf bytes.Buffer
+	if dst.Opaque() {
+		quality := opts.JPEGQuality
+		if quality <= 0 || quality > 100 {
+			quality = jpeg.DefaultQuality
+		}
+		err = jpeg.Encode(&buf, dst, &jpeg.Options{Quality: quality})
+		mediaType = "image/jpeg"
+	} else {
+		err = (&png.Encoder{CompressionLevel: png.BestCompression}).Encode(&buf, dst)
+		mediaType = "image/png"
+	}
+	if err != nil {
+		return data, "", false, err
+	}
+	if !oversized && buf.Len() >= len(data) {
+		return data, "image/" + format, false, nil
+	}
+	return buf.Bytes(), mediaType, true, nil
+}
+
+// DownscaleDataURI applies Downscale to a base64 image data URI. Anything
+// that is not a base64 image data URI is returned unchanged.
+func DownscaleDataURI(uri string, opts Options) (string, bool, error) {
+	mediaType, data, ok := ParseDataURI(uri)
+	if !ok || !strings.HasPrefix(mediaType, "image/") {
+		return uri, false, nil
+	}
+	raw, err := base64.StdEncoding.DecodeString(data)
+	if err != nil {
+		return uri, false, nil
+	}
+	out, outType, changed, err := Downscale(raw, opts)
+	if err != nil || !changed {
+		return uri, false, err
+	}
+	return "data:" + outType + ";base64," + base64.StdEncoding.EncodeToString(out), true, nil
+}
+
+// ParseDataURI splits "data:<media_type>;base64,<data>".
+func ParseDataURI(uri string) (mediaType, data string, ok bool) {
+	rest, found := strings.CutPrefix(uri, "data:")
+	if !found {
+		return "", "", false
+	}
+	mediaType, data, found = strings.Cut(rest, ";base64,")
+	if !found {
+		return "", "", false
+	}
+	return mediaType, data, true
+}
+
+// fitWithin scales (w, h) so the longest side equals limit.
+func fitWithin(w, h, limit int) (int, int) {
+	if w >= h {
+		return limit, max(1, h*limit/w)
+	}
+	return max(1, w*limit/h), limit
+}
+
+func toRGBA(src image.Image) *image.RGBA {
+	if rgba, ok := src.(*image.RGBA); ok && rgba.Bounds().Min == (image.Point{}) {
+		return rgba
+	}
+	b := src.Bounds()
+	dst := image.NewRGBA(image.Rect(0, 0, b.Dx(), b.Dy()))
+	draw.Draw(dst, dst.Bounds(), src, b.Min, draw.Src)
+	return dst
+}
+
+// resizeBox downsamples with an area-averaging (box) filter, which gives
+// good quality for reductions without external dependencies.
+func resizeBox(src *image.RGBA, w, h int) *image.RGBA {
+	sw, sh := src.Bounds().Dx(), src.Bounds().Dy()
+	dst := image.NewRGBA(image.Rect(0, 0, w, h))
+	for y := 0; y < h; y++ {
+		y0, y1 := y*sh/h, max((y+1)*sh/h, y*sh/h+1)
+		for x := 0; x < w; x++ {
+			x0, x1 := x*sw/w, max((x+1)*sw/w, x*sw/w+1)
+			var r, g, b, a, n uint64
+			for sy := y0; sy < y1; sy++ {
+				row := src.Pix[sy*src.Stride:]
+				for sx := x0; sx < x1; sx++ {
+					p := row[sx*4 : sx*4+4]
+					r += uint64(p[0])
+					g += uint64(p[1])
+					b += uint64(p[2])
+					a += uint64(p[3])
+					n++
+				}
+			}
+			d := dst.Pix[y*dst.Stride+x*4:]
+			d[0], d[1], d[2], d[3] = uint8(r/n), uint8(g/n), uint8(b/n), uint8(a/n)
+		}
+	}
+	return dst
+}
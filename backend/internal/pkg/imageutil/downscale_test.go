@@ -0,0 +1,89 @@
+package imageutil
+
+import (
+	"bytes"
+	"encoding/base64"
+	"image"
+	"image/color"
+	"image/png"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func encodePNG(t *testing.T, w, h int, alpha uint8) []byte {
+	t.Helper()
+	img := image.NewNRGBA(image.Rect(0, 0, w, h))
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			img.Set(x, y, color.NRGBA{R: uint8(x), G: uint8(y), B: uint8(x ^ y), A: alpha})
+		}
+	}
+	var buf bytes.Buffer
+	require.NoError(t, png.Encode(&buf, img))
+	return buf.Bytes()
+}
+
+func TestDownscale_OversizedOpaqueBecomesJPEG(t *testing.T) {
+	out, mediaType, changed, err := Downscale(encodePNG(t, 400, 200, 255), Options{MaxDimension: 100, JPEGQuality: 80})
+	require.NoError(t, err)
+	require.True(t, changed)
+	assert.Equal(t, "image/jpeg", mediaType)
+
+	cfg, format, err := image.DecodeConfig(bytes.NewReader(out))
+	require.NoError(t, err)
+	assert.Equal(t, "jpeg", format)
+	assert.Equal(t, 100, cfg.Width)
+	assert.Equal(t, 50, cfg.Height)
+}
+
+func TestDownscale_TransparentStaysPNG(t *testing.T) {
+	out, mediaType, changed, err := Downscale(encodePNG(t, 100, 300, 128), Options{MaxDimension: 60})
+	require.NoError(t, err)
+	require.True(t, changed)
+	assert.Equal(t, "image/png", mediaType)
+
+	cfg, _, err := image.DecodeConfig(bytes.NewReader(out))
+	require.NoError(t, err)
+	assert.Equal(t, 20, cfg.Width)
+	assert.Equal(t, 60, cfg.Height)
+}
+
+func TestDownscale_SmallImageUnchanged(t *testing.T) {
+	data := encodePNG(t, 32, 32, 255)
+	out, _, changed, err := Downscale(data, Options{MaxDimension: 100, MinBytes: 1 << 20})
+	require.NoError(t, err)
+	assert.False(t, changed)
+	assert.Equal(t, data, out)
+}
+
+func TestDownscale_UndecodableUnchanged(t *testing.T) {
+	data := []byte("RIFF....WEBPVP8 not really")
+	out, _, changed, err := Downscale(data, Options{MaxDimension: 10})
+	require.NoError(t, err)
+	assert.False(t, changed)
+	assert.Equal(t, data, out)
+}
+
+func TestDownscaleDataURI(t *testing.T) {
+	uri := "data:image/png;base64," + base64.StdEncoding.EncodeToString(encodePNG(t, 300, 300, 255))
+	out, changed, err := DownscaleDataURI(uri, Options{MaxDimension: 50})
+	require.NoError(t, err)
+	require.True(t, changed)
+
+	mediaType, data, ok := ParseDataURI(out)
+	require.True(t, ok)
+	assert.Equal(t, "image/jpeg", mediaType)
+	raw, err := base64.StdEncoding.DecodeString(data)
+	require.NoError(t, err)
+	cfg, _, err := image.DecodeConfig(bytes.NewReader(raw))
+	require.NoError(t, err)
+	assert.Equal(t, 50, cfg.Width)
+
+	remote := "https://example.com/cat.png"
+	out, changed, err = DownscaleDataURI(remote, Options{MaxDimension: 50})
+	require.NoError(t, err)
+	assert.False(t, changed)
+	assert.Equal(t, remote, out)
+}
@@ -0,0 +1,267 @@
+// Package moderation implements the OpenAI /v1/moderations wire format and a
+// pluggable local classifier used when moderation is served by the gateway
+// itself or applied as a pre-filter in front of inference endpoints.
+package moderation
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/tidwall/gjson"
+)
+
+// DefaultModel is reported when the client does not name a moderation model.
+const DefaultModel = "omni-moderation-latest"
+
+// Categories lists the OpenAI moderation categories. Every response carries
+// all of them so strict client SDKs can decode the result.
+var Categories = []string{
+	"harassment",
+	"harassment/threatening",
+	"hate",
+	"hate/threatening",
+	"illicit",
+	"illicit/violent",
+	"self-harm",
+	"self-harm/intent",
+	"self-harm/instructions",
+	"sexual",
+	"sexual/minors",
+	"violence",
+	"violence/graphic",
+}
+
+// Result is the classification of a single input.
+type Result struct {
+	Flagged        bool               `json:"flagged"`
+	Categories     map[string]bool    `json:"categories"`
+	CategoryScores map[string]float64 `json:"category_scores"`
+}
+
+// FlaggedCategories returns the flagged category names in sorted order.
+func (r Result) FlaggedCategories() []string {
+	var out []string
+	for name, flagged := range r.Categories {
+		if flagged {
+			out = append(out, name)
+		}
+	}
+	sort.Strings(out)
+	return out
+}
+
+// Response is the /v1/moderations response body.
+type Response struct {
+	ID      string   `json:"id"`
+	Model   string   `json:"model"`
+	Results []Result `json:"results"`
+}
+
+// Classifier classifies text inputs. Implementations must return one result
+// per input, in order.
+type Classifier interface {
+	Classify(ctx context.Context, inputs []string) ([]Result, error)
+}
+
+// RuleClassifier flags an input for a category when any of the category's
+// regular expressions matches. Scores are 1 for matched categories and 0
+// otherwise.
+type RuleClassifier struct {
+	rules map[string][]*regexp.Regexp
+}
+
+// NewRuleClassifier compiles category → patterns rules. Patterns are matched
+// case-insensitively; categories outside the OpenAI set are allowed and
+// reported alongside the standard ones.
+func NewRuleClassifier(rules map[string][]string) (*RuleClassifier, error) {
+	compiled := make(map[string][]*regexp.Regexp, len(rules))
+	for category, patterns := range rules {
+		category = strings.TrimSpace(category)
+		if category == "" {
+			return nil, fmt.Errorf("moderation category name is empty")
+		}
+		for _, pattern := range patterns {
+			if strings.TrimSpace(pattern) == "" {
+				continue
+			}
+			re, err := regexp.Compile("(?i)" + pattern)
+			if err != nil {
+				return nil, fmt.Errorf("moderation category %q: invalid pattern %q: %w", category, pattern, err)
+			}
+			compiled[category] = append(compiled[category], re)
+		}
+	}
+	return &RuleClassifier{rules: compiled}, nil
+}
+
+// Empty reports whether the classifier has no rules and therefore never flags.
+func (r *RuleClassifier) Empty() bool {
+	return r == nil || len(r.rules) == 0
+}
+
+// Classify implements Classifier.
+func (r *RuleClassifier) Classify(_ context.Context, inputs []string) ([]Result, error) {
+	results := make([]Result, 0, len(inputs))
+	for _, input := range inputs {
+		result := newResult()
+		if r != nil {
+			for category, patterns := range r.rules {
+				for _, re := range patterns {
+					if re.MatchString(input) {
+						result.Categories[category] = true
+						result.CategoryScores[category] = 1
+						result.Flagged = true
+						break
+					}
+				}
+			}
+		}
+		results = append(results, result)
+	}
+	return results, nil
+}
+
+func newResult() Result {
+	result := Result{
+		Categories:     make(map[string]bool, len(Categories)),
+		CategoryScores: make(map[string]float64, len(Categories)),
+	}
+	for _, name := range Categories {
+		result.Categories[name] = false
+		result.CategoryScores[name] = 0
+	}
+	return result
+}
+
+// ParseInputs extracts the text inputs of a /v1/moderations request. input may
+// be a string, an array of strings, or an array of multi-modal parts where
+// only {"type":"text"} parts are classified (other parts become empty inputs
+// so results stay aligned with the request).
+func ParseInputs(body []byte) ([]string, error) {
+	input := gjson.GetBytes(body, "input")
+	switch {
+	case !input.Exists() || input.Type == gjson.Null:
+		return nil, fmt.Errorf("input is required")
+	case input.Type == gjson.String:
+		return []string{input.String()}, nil
+	case input.IsArray():
+		items := input.Array()
+		if len(items) == 0 {
+			return nil, fmt.Errorf("input must not be empty")
+		}
+		inputs := make([]string, 0, len(items))
+		for _, item := range items {
+			switch {
+			case item.Type == gjson.String:
+				inputs = append(inputs, item.String())
+			case item.IsObject() && item.Get("type").String() == "text":
+				inputs = append(inputs, item.Get("text").String())
+			case item.IsObject():
+				inputs = append(inputs, "")
+			default:
+				return nil, fmt.Errorf("input must be a string or an array of strings or content parts")
+			}
+		}
+		return inputs, nil
+	default:
+		return nil, fmt.Errorf("input must be a string or an array of strings or content parts")
+	}
+}
+
+// promptTextFields are the request fields that carry user-visible prompt text
+// across the Anthropic, OpenAI and Gemini request formats.
+var promptTextFields = []string{"system", "messages", "input", "prompt", "instructions", "contents", "systemInstruction"}
+
+// ExtractPromptText collects the text of an inference request body (Anthropic
+// Messages, Chat Completions, Responses, legacy Completions or Gemini) for the
+// moderation pre-filter. Only string values under "text"/"content" keys or
+// plain string fields are collected; images, tool schemas and other metadata
+// are ignored.
+func ExtractPromptText(body []byte) []string {
+	var texts []string
+	for _, field := range promptTextFields {
+		collectText(gjson.GetBytes(body, field), &texts)
+	}
+	return texts
+}
+
+func collectText(value gjson.Result, texts *[]string) {
+	switch {
+	case value.Type == gjson.String:
+		if s := value.String(); s != "" {
+			*texts = append(*texts, s)
+		}
+	case value.IsArray():
+		for _, item := range value.Array() {
+			collectText(item, texts)
+		}
+	case value.IsObject():
+		for _, key := range []string{"text", "content", "parts"} {
+			if child := value.Get(key); child.Exists() {
+				collectText(child, texts)
+			}
+		}
+	}
+}
+
+// EndpointClassifier classifies inputs by calling an OpenAI-compatible
+// /v1/moderations endpoint.
+type EndpointClassifier struct {
+	url    string
+	apiKey string
+	model  string
+	client *http.Client
+}
+
+// NewEndpointClassifier creates a classifier for url. An empty model falls
+// back to DefaultModel; client defaults to one with the given timeout.
+func NewEndpointClassifier(url, apiKey, model string, timeout time.Duration, client *http.Client) *EndpointClassifier {
+	if model == "" {
+		model = DefaultModel
+	}
+	if client == nil {
+		client = &http.Client{Timeout: timeout}
+	}
+	return &EndpointClassifier{url: url, apiKey: apiKey, model: model, client: client}
+}
+
+// Classify implements Classifier.
+func (e *EndpointClassifier) Classify(ctx context.Context, inputs []string) ([]Result, error) {
+	payload, err := json.Marshal(map[string]any{"model": e.model, "input": inputs})
+	if err != nil {
+		return nil, err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, e.url, bytes.NewReader(payload))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if e.apiKey != "" {
+		req.Header.Set("Authorization", "Bearer "+e.apiKey)
+	}
+	resp, err := e.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("moderation endpoint: %w", err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+	if resp.StatusCode != http.StatusOK {
+		_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+		return nil, fmt.Errorf("moderation endpoint: HTTP %d", resp.StatusCode)
+	}
+	var out Response
+	if err := json.NewDecoder(io.LimitReader(resp.Body, 4<<20)).Decode(&out); err != nil {
+		return nil, fmt.Errorf("moderation endpoint: decode response: %w", err)
+	}
+	if len(out.Results) != len(inputs) {
+		return nil, fmt.Errorf("moderation endpoint: got %d results for %d inputs", len(out.Results), len(inputs))
+	}
+	return out.Results, nil
+}
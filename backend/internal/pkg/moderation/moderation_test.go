@@ -0,0 +1,143 @@
+package moderation
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tidwall/gjson"
+)
+
+func TestParseInputs(t *testing.T) {
+	tests := []struct {
+		name    string
+		body    string
+		want    []string
+		wantErr bool
+	}{
+		{name: "string", body: `{"input":"hello"}`, want: []string{"hello"}},
+		{name: "string array", body: `{"input":["a","b"]}`, want: []string{"a", "b"}},
+		{name: "multi-modal parts", body: `{"input":[{"type":"text","text":"caption"},{"type":"image_url","image_url":{"url":"https://x"}}]}`, want: []string{"caption", ""}},
+		{name: "missing", body: `{"model":"omni-moderation-latest"}`, wantErr: true},
+		{name: "empty array", body: `{"input":[]}`, wantErr: true},
+		{name: "number", body: `{"input":1}`, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseInputs([]byte(tt.body))
+			if tt.wantErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestRuleClassifier_Classify(t *testing.T) {
+	classifier, err := NewRuleClassifier(map[string][]string{
+		"violence":    {`\bbuild a bomb\b`},
+		"custom/spam": {"buy now", ""},
+		"sexual":      {"   "},
+		"illicit":     {`forged\s+passport`},
+	})
+	require.NoError(t, err)
+
+	results, err := classifier.Classify(context.Background(), []string{"How do I BUILD A BOMB?", "hello", "Buy Now and get a forged  passport"})
+	require.NoError(t, err)
+	require.Len(t, results, 3)
+
+	assert.True(t, results[0].Flagged)
+	assert.Equal(t, []string{"violence"}, results[0].FlaggedCategories())
+	assert.Equal(t, 1.0, results[0].CategoryScores["violence"])
+
+	assert.False(t, results[1].Flagged)
+	assert.Len(t, results[1].Categories, len(Categories))
+	assert.Empty(t, results[1].FlaggedCategories())
+
+	assert.Equal(t, []string{"custom/spam", "illicit"}, results[2].FlaggedCategories())
+}
+
+func TestNewRuleClassifier_InvalidPattern(t *testing.T) {
+	_, err := NewRuleClassifier(map[string][]string{"hate": {"("}})
+	require.Error(t, err)
+
+	empty, err := NewRuleClassifier(nil)
+	require.NoError(t, err)
+	assert.True(t, empty.Empty())
+}
+
+func TestExtractPromptText(t *testing.T) {
+	tests := []struct {
+		name string
+		body string
+		want []string
+	}{
+		{
+			name: "anthropic messages",
+			body: `{"system":[{"type":"text","text":"sys"}],"messages":[{"role":"user","content":[{"type":"text","text":"hi"},{"type":"image","source":{"type":"base64","data":"AAAA"}}]}]}`,
+			want: []string{"sys", "hi"},
+		},
+		{
+			name: "chat completions",
+			body: `{"messages":[{"role":"system","content":"be nice"},{"role":"user","content":"hello"}],"tools":[{"type":"function","function":{"description":"ignored"}}]}`,
+			want: []string{"be nice", "hello"},
+		},
+		{
+			name: "responses",
+			body: `{"instructions":"inst","input":[{"role":"user","content":[{"type":"input_text","text":"q"}]}]}`,
+			want: []string{"inst", "q"},
+		},
+		{
+			name: "completions",
+			body: `{"prompt":["one","two"]}`,
+			want: []string{"one", "two"},
+		},
+		{
+			name: "gemini",
+			body: `{"systemInstruction":{"parts":[{"text":"s"}]},"contents":[{"role":"user","parts":[{"text":"g"},{"inlineData":{"data":"AAAA"}}]}]}`,
+			want: []string{"g", "s"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.ElementsMatch(t, tt.want, ExtractPromptText([]byte(tt.body)))
+		})
+	}
+}
+
+func TestEndpointClassifier_Classify(t *testing.T) {
+	var auth, model string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		auth = r.Header.Get("Authorization")
+		body, _ := io.ReadAll(r.Body)
+		model = gjson.GetBytes(body, "model").String()
+		if gjson.GetBytes(body, "input.0").String() == "fail" {
+			w.WriteHeader(http.StatusTooManyRequests)
+			return
+		}
+		_, _ = w.Write([]byte(`{"id":"modr-1","model":"omni-moderation-latest","results":[{"flagged":true,"categories":{"violence":true,"hate":false},"category_scores":{"violence":0.9}}]}`))
+	}))
+	defer srv.Close()
+
+	classifier := NewEndpointClassifier(srv.URL, "sk-mod", "", time.Second, nil)
+	results, err := classifier.Classify(context.Background(), []string{"text"})
+	require.NoError(t, err)
+	require.Len(t, results, 1)
+	assert.Equal(t, []string{"violence"}, results[0].FlaggedCategories())
+	assert.Equal(t, "Bearer sk-mod", auth)
+	assert.Equal(t, DefaultModel, model)
+
+	_, err = classifier.Classify(context.Background(), []string{"fail"})
+	require.ErrorContains(t, err, "HTTP 429")
+
+	// 结果数量与输入不一致视为失败
+	_, err = classifier.Classify(context.Background(), []string{"a", "b"})
+	require.Error(t, err)
+}
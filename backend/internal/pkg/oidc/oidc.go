@@ -0,0 +1,470 @@
+// Package oidc implements the OpenID Connect relying-party pieces used for admin SSO:
+// provider discovery, JWKS-backed ID token verification, code exchange and userinfo.
+package oidc
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/tidwall/gjson"
+)
+
+const (
+	discoveryTTL       = time.Hour
+	jwksTTL            = time.Hour
+	jwksMinRefreshGap  = time.Minute
+	maxResponseBytes   = 1 << 20
+	defaultHTTPTimeout = 15 * time.Second
+)
+
+// ErrUnknownKey is returned when the ID token is signed with a key absent from the provider JWKS.
+var ErrUnknownKey = errors.New("oidc: signing key not found in jwks")
+
+// Discovery is the subset of the provider metadata document used by this package.
+type Discovery struct {
+	Issuer                string `json:"issuer"`
+	AuthorizationEndpoint string `json:"authorization_endpoint"`
+	TokenEndpoint         string `json:"token_endpoint"`
+	UserInfoEndpoint      string `json:"userinfo_endpoint"`
+	JWKSURI               string `json:"jwks_uri"`
+}
+
+// TokenResponse is the token endpoint response of the authorization code grant.
+type TokenResponse struct {
+	AccessToken string `json:"access_token"`
+	TokenType   string `json:"token_type"`
+	IDToken     string `json:"id_token"`
+	ExpiresIn   int64  `json:"expires_in"`
+}
+
+// IDToken holds the verified standard claims plus the raw JSON payload for custom claim lookups.
+type IDToken struct {
+	Subject           string
+	Email             string
+	EmailVerified     bool
+	Name              string
+	PreferredUsername string
+	Nonce             string
+	// Claims is the JSON-encoded claim set
+	Claims []byte
+}
+
+// ExchangeParams describes an authorization code exchange.
+type ExchangeParams struct {
+	ClientID     string
+	ClientSecret string
+	// AuthMethod: client_secret_basic (default) / client_secret_post / none
+	AuthMethod   string
+	Code         string
+	RedirectURI  string
+	CodeVerifier string
+}
+
+// Provider is a cached view of one OIDC issuer. It is safe for concurrent use.
+type Provider struct {
+	issuer     string
+	httpClient *http.Client
+
+	mu            sync.Mutex
+	discovery     *Discovery
+	discoveredAt  time.Time
+	keys          map[string]any
+	keysFetchedAt time.Time
+}
+
+// NewProvider creates a provider for issuer. A nil httpClient uses a client with a 15s timeout.
+func NewProvider(issuer string, httpClient *http.Client) *Provider {
+	if httpClient == nil {
+		httpClient = &http.Client{Timeout: defaultHTTPTimeout}
+	}
+	return &Provider{
+		issuer:     strings.TrimRight(strings.TrimSpace(issuer), "/"),
+		httpClient: httpClient,
+	}
+}
+
+// Discover returns the provider metadata, fetching {issuer}/.well-known/openid-configuration when stale.
+func (p *Provider) Discover(ctx context.Context) (*Discovery, error) {
+	p.mu.Lock()
+	if p.discovery != nil && time.Since(p.discoveredAt) < discoveryTTL {
+		d := *p.discovery
+		p.mu.Unlock()
+		return &d, nil
+	}
+	p.mu.Unlock()
+
+	var d Discovery
+	if err := p.getJSON(ctx, p.issuer+"/.well-known/openid-configuration", "", &d); err != nil {
+		return nil, fmt.Errorf("oidc discovery: %w", err)
+	}
+	// OIDC Discovery 1.0 §4.3: the returned issuer must exactly match the configured one.
+	if strings.TrimRight(d.Issuer, "/") != p.issuer {
+		return nil, fmt.Errorf("oidc discovery: issuer mismatch: got %q want %q", d.Issuer, p.issuer)
+	}
+	if d.AuthorizationEndpoint == "" || d.TokenEndpoint == "" || d.JWKSURI == "" {
+		return nil, errors.New("oidc discovery: metadata missing required endpoints")
+	}
+
+	p.mu.Lock()
+	p.discovery = &d
+	p.discoveredAt = time.Now()
+	p.mu.Unlock()
+	out := d
+	return &out, nil
+}
+
+// AuthCodeURL builds the authorization endpoint URL.
+func (p *Provider) AuthCodeURL(ctx context.Context, clientID, redirectURI, scopes, state, nonce, codeChallenge string) (string, error) {
+	d, err := p.Discover(ctx)
+	if err != nil {
+		return "", err
+	}
+	u, err := url.Parse(d.AuthorizationEndpoint)
+	if err != nil {
+		return "", fmt.Errorf("parse authorization_endpoint: %w", err)
+	}
+	q := u.Query()
+	q.Set("response_type", "code")
+	q.Set("client_id", clientID)
+	q.Set("redirect_uri", redirectURI)
+	q.Set("scope", scopes)
+	q.Set("state", state)
+	q.Set("nonce", nonce)
+	if codeChallenge != "" {
+		q.Set("code_challenge", codeChallenge)
+		q.Set("code_challenge_method", "S256")
+	}
+	u.RawQuery = q.Encode()
+	return u.String(), nil
+}
+
+// Exchange redeems an authorization code at the token endpoint.
+func (p *Provider) Exchange(ctx context.Context, params ExchangeParams) (*TokenResponse, error) {
+	d, err := p.Discover(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	form := url.Values{}
+	form.Set("grant_type", "authorization_code")
+	form.Set("code", params.Code)
+	form.Set("redirect_uri", params.RedirectURI)
+	if params.CodeVerifier != "" {
+		form.Set("code_verifier", params.CodeVerifier)
+	}
+
+	method := strings.ToLower(strings.TrimSpace(params.AuthMethod))
+	switch method {
+	case "", "client_secret_basic":
+	case "client_secret_post":
+		form.Set("client_id", params.ClientID)
+		form.Set("client_secret", params.ClientSecret)
+	case "none":
+		form.Set("client_id", params.ClientID)
+	default:
+		return nil, fmt.Errorf("unsupported token_auth_method: %s", params.AuthMethod)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, d.TokenEndpoint, strings.NewReader(form.Encode()))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json")
+	if method == "" || method == "client_secret_basic" {
+		req.SetBasicAuth(url.QueryEscape(params.ClientID), url.QueryEscape(params.ClientSecret))
+	}
+
+	body, status, err := p.do(req)
+	if err != nil {
+		return nil, fmt.Errorf("token request: %w", err)
+	}
+	if status < 200 || status >= 300 {
+		return nil, fmt.Errorf("token endpoint status=%d error=%q error_description=%q",
+			status, gjson.GetBytes(body, "error").String(), gjson.GetBytes(body, "error_description").String())
+	}
+
+	var tok TokenResponse
+	if err := json.Unmarshal(body, &tok); err != nil {
+		return nil, fmt.Errorf("decode token response: %w", err)
+	}
+	if tok.IDToken == "" {
+		return nil, errors.New("token response missing id_token")
+	}
+	return &tok, nil
+}
+
+// UserInfo fetches the userinfo document with the access token.
+func (p *Provider) UserInfo(ctx context.Context, accessToken string) ([]byte, error) {
+	d, err := p.Discover(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if d.UserInfoEndpoint == "" {
+		return nil, errors.New("provider has no userinfo_endpoint")
+	}
+	var raw json.RawMessage
+	if err := p.getJSON(ctx, d.UserInfoEndpoint, accessToken, &raw); err != nil {
+		return nil, fmt.Errorf("userinfo: %w", err)
+	}
+	return raw, nil
+}
+
+// VerifyIDToken checks the signature, issuer, audience, expiry and nonce of rawIDToken.
+func (p *Provider) VerifyIDToken(ctx context.Context, rawIDToken, clientID, nonce string) (*IDToken, error) {
+	d, err := p.Discover(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	claims := jwt.MapClaims{}
+	_, err = jwt.ParseWithClaims(rawIDToken, claims, func(t *jwt.Token) (any, error) {
+		kid, _ := t.Header["kid"].(string)
+		return p.key(ctx, d.JWKSURI, kid)
+	},
+		jwt.WithValidMethods([]string{"RS256", "RS384", "RS512", "PS256", "PS384", "PS512", "ES256", "ES384", "ES512"}),
+		jwt.WithIssuer(d.Issuer),
+		jwt.WithAudience(clientID),
+		jwt.WithExpirationRequired(),
+		jwt.WithIssuedAt(),
+		jwt.WithLeeway(time.Minute),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("verify id_token: %w", err)
+	}
+
+	payload, err := json.Marshal(claims)
+	if err != nil {
+		return nil, fmt.Errorf("encode id_token claims: %w", err)
+	}
+	tok := &IDToken{
+		Subject:           gjson.GetBytes(payload, "sub").String(),
+		Email:             strings.TrimSpace(gjson.GetBytes(payload, "email").String()),
+		EmailVerified:     ClaimBool(gjson.GetBytes(payload, "email_verified")),
+		Name:              gjson.GetBytes(payload, "name").String(),
+		PreferredUsername: gjson.GetBytes(payload, "preferred_username").String(),
+		Nonce:             gjson.GetBytes(payload, "nonce").String(),
+		Claims:            payload,
+	}
+	if tok.Subject == "" {
+		return nil, errors.New("verify id_token: missing sub")
+	}
+	if nonce != "" && tok.Nonce != nonce {
+		return nil, errors.New("verify id_token: nonce mismatch")
+	}
+	// With several audiences the authorized party must be us (OIDC Core §3.1.3.7).
+	if auds := gjson.GetBytes(payload, "aud"); auds.IsArray() && len(auds.Array()) > 1 {
+		if azp := gjson.GetBytes(payload, "azp").String(); azp != clientID {
+			return nil, errors.New("verify id_token: azp mismatch")
+		}
+	}
+	return tok, nil
+}
+
+// ClaimStrings reads a string or string-array claim at a gjson path.
+func ClaimStrings(claims []byte, path string) []string {
+	path = strings.TrimSpace(path)
+	if path == "" || len(claims) == 0 {
+		return nil
+	}
+	res := gjson.GetBytes(claims, path)
+	if !res.Exists() {
+		return nil
+	}
+	if !res.IsArray() {
+		if v := strings.TrimSpace(res.String()); v != "" {
+			return []string{v}
+		}
+		return nil
+	}
+	out := make([]string, 0, len(res.Array()))
+	for _, item := range res.Array() {
+		if v := strings.TrimSpace(item.String()); v != "" {
+			out = append(out, v)
+		}
+	}
+	return out
+}
+
+// ClaimBool accepts both JSON booleans and "true"/"false" strings (some providers send the latter).
+func ClaimBool(res gjson.Result) bool {
+	if res.Type == gjson.String {
+		return strings.EqualFold(strings.TrimSpace(res.String()), "true")
+	}
+	return res.Bool()
+}
+
+func (p *Provider) key(ctx context.Context, jwksURI, kid string) (any, error) {
+	p.mu.Lock()
+	keys, fetchedAt := p.keys, p.keysFetchedAt
+	p.mu.Unlock()
+
+	if k, ok := lookupKey(keys, kid); ok && time.Since(fetchedAt) < jwksTTL {
+		return k, nil
+	}
+	// 未知 kid 可能是提供方轮换了密钥：重新拉取，但限制频率避免被伪造 token 放大请求
+	if keys != nil && time.Since(fetchedAt) < jwksMinRefreshGap {
+		if k, ok := lookupKey(keys, kid); ok {
+			return k, nil
+		}
+		return nil, ErrUnknownKey
+	}
+
+	fresh, err := p.fetchJWKS(ctx, jwksURI)
+	if err != nil {
+		if k, ok := lookupKey(keys, kid); ok {
+			return k, nil
+		}
+		return nil, err
+	}
+	p.mu.Lock()
+	p.keys = fresh
+	p.keysFetchedAt = time.Now()
+	p.mu.Unlock()
+
+	if k, ok := lookupKey(fresh, kid); ok {
+		return k, nil
+	}
+	return nil, ErrUnknownKey
+}
+
+// lookupKey finds a key by kid; when the token has no kid and the set holds exactly one key, that key is used.
+func lookupKey(keys map[string]any, kid string) (any, bool) {
+	if keys == nil {
+		return nil, false
+	}
+	if kid == "" && len(keys) == 1 {
+		for _, k := range keys {
+			return k, true
+		}
+	}
+	k, ok := keys[kid]
+	return k, ok
+}
+
+type jsonWebKey struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Use string `json:"use"`
+	N   string `json:"n"`
+	E   string `json:"e"`
+	Crv string `json:"crv"`
+	X   string `json:"x"`
+	Y   string `json:"y"`
+}
+
+func (p *Provider) fetchJWKS(ctx context.Context, jwksURI string) (map[string]any, error) {
+	var set struct {
+		Keys []jsonWebKey `json:"keys"`
+	}
+	if err := p.getJSON(ctx, jwksURI, "", &set); err != nil {
+		return nil, fmt.Errorf("fetch jwks: %w", err)
+	}
+	keys := make(map[string]any, len(set.Keys))
+	for _, jwk := range set.Keys {
+		if jwk.Use != "" && jwk.Use != "sig" {
+			continue
+		}
+		pub, err := parseJWK(jwk)
+		if err != nil {
+			continue
+		}
+		keys[jwk.Kid] = pub
+	}
+	if len(keys) == 0 {
+		return nil, errors.New("fetch jwks: no usable signing keys")
+	}
+	return keys, nil
+}
+
+func parseJWK(jwk jsonWebKey) (any, error) {
+	switch jwk.Kty {
+	case "RSA":
+		n, err := base64.RawURLEncoding.DecodeString(jwk.N)
+		if err != nil {
+			return nil, err
+		}
+		e, err := base64.RawURLEncoding.DecodeString(jwk.E)
+		if err != nil {
+			return nil, err
+		}
+		exp := new(big.Int).SetBytes(e)
+		if !exp.IsInt64() || exp.Int64() < 3 {
+			return nil, errors.New("invalid rsa exponent")
+		}
+		return &rsa.PublicKey{N: new(big.Int).SetBytes(n), E: int(exp.Int64())}, nil
+	case "EC":
+		var curve elliptic.Curve
+		switch jwk.Crv {
+		case "P-256":
+			curve = elliptic.P256()
+		case "P-384":
+			curve = elliptic.P384()
+		case "P-521":
+			curve = elliptic.P521()
+		default:
+			return nil, fmt.Errorf("unsupported curve: %s", jwk.Crv)
+		}
+		x, err := base64.RawURLEncoding.DecodeString(jwk.X)
+		if err != nil {
+			return nil, err
+		}
+		y, err := base64.RawURLEncoding.DecodeString(jwk.Y)
+		if err != nil {
+			return nil, err
+		}
+		pub := &ecdsa.PublicKey{Curve: curve, X: new(big.Int).SetBytes(x), Y: new(big.Int).SetBytes(y)}
+		if !curve.IsOnCurve(pub.X, pub.Y) {
+			return nil, errors.New("ec point not on curve")
+		}
+		return pub, nil
+	default:
+		return nil, fmt.Errorf("unsupported key type: %s", jwk.Kty)
+	}
+}
+
+func (p *Provider) getJSON(ctx context.Context, endpoint, bearer string, out any) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", "application/json")
+	if bearer != "" {
+		req.Header.Set("Authorization", "Bearer "+bearer)
+	}
+	body, status, err := p.do(req)
+	if err != nil {
+		return err
+	}
+	if status < 200 || status >= 300 {
+		return fmt.Errorf("status=%d", status)
+	}
+	return json.Unmarshal(body, out)
+}
+
+func (p *Provider) do(req *http.Request) ([]byte, int, error) {
+	resp, err := p.httpClient.Do(req)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer func() { _ = resp.Body.Close() }()
+	body, err := io.ReadAll(io.LimitReader(resp.Body, maxResponseBytes))
+	if err != nil {
+		return nil, resp.StatusCode, err
+	}
+	return body, resp.StatusCode, nil
+}
@@ -0,0 +1,205 @@
+//go:build unit
+
+package oidc
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/require"
+)
+
+type testIssuer struct {
+	server     *httptest.Server
+	key        *rsa.PrivateKey
+	jwksHits   int
+	tokenForm  url.Values
+	tokenBasic [2]string
+	idToken    string
+}
+
+func newTestIssuer(t *testing.T) *testIssuer {
+	t.Helper()
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+
+	ti := &testIssuer{key: key}
+	mux := http.NewServeMux()
+	ti.server = httptest.NewServer(mux)
+	t.Cleanup(ti.server.Close)
+
+	mux.HandleFunc("/.well-known/openid-configuration", func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewEncoder(w).Encode(map[string]string{
+			"issuer":                 ti.server.URL,
+			"authorization_endpoint": ti.server.URL + "/authorize",
+			"token_endpoint":         ti.server.URL + "/token",
+			"userinfo_endpoint":      ti.server.URL + "/userinfo",
+			"jwks_uri":               ti.server.URL + "/jwks",
+		})
+	})
+	mux.HandleFunc("/jwks", func(w http.ResponseWriter, r *http.Request) {
+		ti.jwksHits++
+		_ = json.NewEncoder(w).Encode(map[string]any{"keys": []map[string]string{{
+			"kty": "RSA",
+			"kid": "k1",
+			"use": "sig",
+			"n":   base64.RawURLEncoding.EncodeToString(key.PublicKey.N.Bytes()),
+			"e":   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(key.PublicKey.E)).Bytes()),
+		}}})
+	})
+	mux.HandleFunc("/token", func(w http.ResponseWriter, r *http.Request) {
+		_ = r.ParseForm()
+		ti.tokenForm = r.PostForm
+		ti.tokenBasic[0], ti.tokenBasic[1], _ = r.BasicAuth()
+		_ = json.NewEncoder(w).Encode(map[string]any{"access_token": "at", "token_type": "Bearer", "id_token": ti.idToken})
+	})
+	mux.HandleFunc("/userinfo", func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer at" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		_, _ = w.Write([]byte(`{"sub":"u1","groups":["ops"]}`))
+	})
+	return ti
+}
+
+func (ti *testIssuer) sign(t *testing.T, kid string, claims jwt.MapClaims) string {
+	t.Helper()
+	tok := jwt.NewWithClaims(jwt.SigningMethodRS256, claims)
+	tok.Header["kid"] = kid
+	raw, err := tok.SignedString(ti.key)
+	require.NoError(t, err)
+	return raw
+}
+
+func (ti *testIssuer) claims() jwt.MapClaims {
+	now := time.Now()
+	return jwt.MapClaims{
+		"iss":            ti.server.URL,
+		"sub":            "u1",
+		"aud":            "client-1",
+		"exp":            now.Add(time.Hour).Unix(),
+		"iat":            now.Unix(),
+		"nonce":          "n1",
+		"email":          "ops@example.com",
+		"email_verified": "true",
+		"groups":         []string{"sre", "gateway-admins"},
+	}
+}
+
+func TestVerifyIDToken(t *testing.T) {
+	ti := newTestIssuer(t)
+	p := NewProvider(ti.server.URL+"/", nil)
+
+	tok, err := p.VerifyIDToken(context.Background(), ti.sign(t, "k1", ti.claims()), "client-1", "n1")
+	require.NoError(t, err)
+	require.Equal(t, "u1", tok.Subject)
+	require.Equal(t, "ops@example.com", tok.Email)
+	require.True(t, tok.EmailVerified, "string email_verified is accepted")
+	require.Equal(t, []string{"sre", "gateway-admins"}, ClaimStrings(tok.Claims, "groups"))
+}
+
+func TestVerifyIDTokenRejectsInvalidTokens(t *testing.T) {
+	ti := newTestIssuer(t)
+	p := NewProvider(ti.server.URL, nil)
+	ctx := context.Background()
+
+	_, err := p.VerifyIDToken(ctx, ti.sign(t, "k1", ti.claims()), "client-1", "other-nonce")
+	require.ErrorContains(t, err, "nonce mismatch")
+
+	_, err = p.VerifyIDToken(ctx, ti.sign(t, "k1", ti.claims()), "client-2", "n1")
+	require.Error(t, err)
+
+	expired := ti.claims()
+	expired["exp"] = time.Now().Add(-time.Hour).Unix()
+	_, err = p.VerifyIDToken(ctx, ti.sign(t, "k1", expired), "client-1", "n1")
+	require.ErrorIs(t, err, jwt.ErrTokenExpired)
+
+	wrongIss := ti.claims()
+	wrongIss["iss"] = "https://evil.example.com"
+	_, err = p.VerifyIDToken(ctx, ti.sign(t, "k1", wrongIss), "client-1", "n1")
+	require.ErrorIs(t, err, jwt.ErrTokenInvalidIssuer)
+
+	hs := jwt.NewWithClaims(jwt.SigningMethodHS256, ti.claims())
+	raw, err := hs.SignedString([]byte("secret"))
+	require.NoError(t, err)
+	_, err = p.VerifyIDToken(ctx, raw, "client-1", "n1")
+	require.ErrorIs(t, err, jwt.ErrTokenSignatureInvalid)
+}
+
+func TestVerifyIDTokenUnknownKidIsRateLimited(t *testing.T) {
+	ti := newTestIssuer(t)
+	p := NewProvider(ti.server.URL, nil)
+	ctx := context.Background()
+
+	_, err := p.VerifyIDToken(ctx, ti.sign(t, "k1", ti.claims()), "client-1", "n1")
+	require.NoError(t, err)
+	require.Equal(t, 1, ti.jwksHits)
+
+	for i := 0; i < 3; i++ {
+		_, err = p.VerifyIDToken(ctx, ti.sign(t, "rotated", ti.claims()), "client-1", "n1")
+		require.ErrorIs(t, err, ErrUnknownKey)
+	}
+	require.Equal(t, 1, ti.jwksHits, "jwks is not refetched within the refresh gap")
+}
+
+func TestDiscoverRejectsIssuerMismatch(t *testing.T) {
+	ti := newTestIssuer(t)
+	p := NewProvider(ti.server.URL+"/realms/other", nil)
+
+	_, err := p.Discover(context.Background())
+	require.Error(t, err)
+}
+
+func TestExchangeAuthMethods(t *testing.T) {
+	ti := newTestIssuer(t)
+	ti.idToken = "raw-id-token"
+	p := NewProvider(ti.server.URL, nil)
+	ctx := context.Background()
+
+	tok, err := p.Exchange(ctx, ExchangeParams{ClientID: "client-1", ClientSecret: "s3cret", Code: "c", RedirectURI: "https://gw/cb", CodeVerifier: "v"})
+	require.NoError(t, err)
+	require.Equal(t, "raw-id-token", tok.IDToken)
+	require.Equal(t, [2]string{"client-1", "s3cret"}, ti.tokenBasic)
+	require.Equal(t, "v", ti.tokenForm.Get("code_verifier"))
+	require.Empty(t, ti.tokenForm.Get("client_secret"))
+
+	_, err = p.Exchange(ctx, ExchangeParams{ClientID: "client-1", ClientSecret: "s3cret", AuthMethod: "client_secret_post", Code: "c"})
+	require.NoError(t, err)
+	require.Equal(t, "s3cret", ti.tokenForm.Get("client_secret"))
+
+	_, err = p.Exchange(ctx, ExchangeParams{ClientID: "client-1", AuthMethod: "none", Code: "c"})
+	require.NoError(t, err)
+	require.Equal(t, "client-1", ti.tokenForm.Get("client_id"))
+	require.Empty(t, ti.tokenForm.Get("client_secret"))
+
+	info, err := p.UserInfo(ctx, tok.AccessToken)
+	require.NoError(t, err)
+	require.Equal(t, []string{"ops"}, ClaimStrings(info, "groups"))
+}
+
+func TestAuthCodeURL(t *testing.T) {
+	ti := newTestIssuer(t)
+	p := NewProvider(ti.server.URL, nil)
+
+	raw, err := p.AuthCodeURL(context.Background(), "client-1", "https://gw/cb", "openid email", "st", "n1", "chal")
+	require.NoError(t, err)
+	u, err := url.Parse(raw)
+	require.NoError(t, err)
+	q := u.Query()
+	require.Equal(t, "/authorize", u.Path)
+	require.Equal(t, "code", q.Get("response_type"))
+	require.Equal(t, "st", q.Get("state"))
+	require.Equal(t, "n1", q.Get("nonce"))
+	require.Equal(t, "S256", q.Get("code_challenge_method"))
+}
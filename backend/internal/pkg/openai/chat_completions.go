@@ -0,0 +1,253 @@
+package openai
+
+import (
+	"encoding/json"
+
+	"github.com/ShaohongDong/sub2api/internal/pkg/apicompat"
+)
+
+// Chat Completions API 类型定义（/v1/chat/completions）。
+// 仅覆盖与 Responses API 互转所需的字段；未知字段在转换时忽略。
+
+// ChatCompletionRequest is the request body for POST /v1/chat/completions.
+type ChatCompletionRequest struct {
+	Model               string                `json:"model"`
+	Messages            []ChatMessage         `json:"messages"`
+	Stream              bool                  `json:"stream,omitempty"`
+	StreamOptions       *ChatStreamOptions    `json:"stream_options,omitempty"`
+	MaxTokens           *int                  `json:"max_tokens,omitempty"`
+	MaxCompletionTokens *int                  `json:"max_completion_tokens,omitempty"`
+	Temperature         *float64              `json:"temperature,omitempty"`
+	TopP                *float64              `json:"top_p,omitempty"`
+	TopK                *int                  `json:"top_k,omitempty"` // non-standard, accepted by several OpenAI-compatible clients
+	N                   *int                  `json:"n,omitempty"`
+	Stop                json.RawMessage       `json:"stop,omitempty"` // string or []string
+	LogitBias           json.RawMessage       `json:"logit_bias,omitempty"`
+	PresencePenalty     *float64              `json:"presence_penalty,omitempty"`
+	FrequencyPenalty    *float64              `json:"frequency_penalty,omitempty"`
+	Tools               []ChatTool            `json:"tools,omitempty"`
+	ToolChoice          json.RawMessage       `json:"tool_choice,omitempty"`
+	ParallelToolCalls   *bool                 `json:"parallel_tool_calls,omitempty"`
+	ReasoningEffort     string                `json:"reasoning_effort,omitempty"`
+	ResponseFormat      *ChatResponseFormat   `json:"response_format,omitempty"`
+	WebSearchOptions    *ChatWebSearchOptions `json:"web_search_options,omitempty"`
+	ServiceTier         string                `json:"service_tier,omitempty"`
+	User                string                `json:"user,omitempty"`
+}
+
+// ChatStreamOptions controls streaming extras.
+type ChatStreamOptions struct {
+	IncludeUsage bool `json:"include_usage,omitempty"`
+}
+
+// ChatWebSearchOptions enables web search for the request (search models).
+type ChatWebSearchOptions struct {
+	SearchContextSize string          `json:"search_context_size,omitempty"`
+	UserLocation      json.RawMessage `json:"user_location,omitempty"`
+}
+
+// ChatMessage is one message in the conversation. ServerToolCalls replays
+// built-in tool calls returned in an earlier assistant message.
+type ChatMessage struct {
+	Role            string               `json:"role"`              // "system" | "developer" | "user" | "assistant" | "tool"
+	Content         json.RawMessage      `json:"content,omitempty"` // string or []ChatContentPart
+	Name            string               `json:"name,omitempty"`
+	ToolCalls       []ChatToolCall       `json:"tool_calls,omitempty"`
+	ServerToolCalls []ChatServerToolCall `json:"server_tool_calls,omitempty"`
+	ToolCallID      string               `json:"tool_call_id,omitempty"`
+}
+
+// ChatContentPart is a typed content part inside a message.
+type ChatContentPart struct {
+	Type     string        `json:"type"` // "text" | "image_url" | "file"
+	Text     string        `json:"text,omitempty"`
+	ImageURL *ChatImageURL `json:"image_url,omitempty"`
+	File     *ChatFile     `json:"file,omitempty"`
+}
+
+// ChatImageURL references an image by URL or data URI.
+type ChatImageURL struct {
+	URL    string `json:"url"`
+	Detail string `json:"detail,omitempty"`
+}
+
+// UnmarshalJSON also accepts the bare-string form ("image_url": "https://...")
+// sent by some OpenAI-compatible clients.
+func (u *ChatImageURL) UnmarshalJSON(data []byte) error {
+	var s string
+	if err := json.Unmarshal(data, &s); err == nil {
+		*u = ChatImageURL{URL: s}
+		return nil
+	}
+	type plain ChatImageURL
+	return json.Unmarshal(data, (*plain)(u))
+}
+
+// ChatFile references a file by ID or inline data URI.
+type ChatFile struct {
+	FileID   string `json:"file_id,omitempty"`
+	FileData string `json:"file_data,omitempty"`
+	Filename string `json:"filename,omitempty"`
+}
+
+// ChatTool describes a tool available to the model. Besides functions, the
+// built-in web_search (web_search_preview) and code_interpreter tools are
+// accepted with their Responses API options inline.
+type ChatTool struct {
+	Type     string        `json:"type"` // "function" | "web_search" | "web_search_preview" | "code_interpreter"
+	Function *ChatFunction `json:"function,omitempty"`
+
+	// type=web_search
+	SearchContextSize string          `json:"search_context_size,omitempty"`
+	UserLocation      json.RawMessage `json:"user_location,omitempty"`
+	Filters           json.RawMessage `json:"filters,omitempty"`
+
+	// type=code_interpreter
+	Container json.RawMessage `json:"container,omitempty"`
+}
+
+// ChatFunction is a function tool definition.
+type ChatFunction struct {
+	Name        string          `json:"name"`
+	Description string          `json:"description,omitempty"`
+	Parameters  json.RawMessage `json:"parameters,omitempty"`
+	Strict      *bool           `json:"strict,omitempty"`
+}
+
+// ChatToolCall is a tool call issued by the assistant. Index is only set in
+// streaming deltas.
+type ChatToolCall struct {
+	Index    *int             `json:"index,omitempty"`
+	ID       string           `json:"id,omitempty"`
+	Type     string           `json:"type,omitempty"` // "function"
+	Function ChatFunctionCall `json:"function"`
+}
+
+// ChatServerToolCall is a built-in tool call the upstream ran itself
+// (non-standard, like reasoning_content). Clients must not execute it; it is
+// reported for visibility and can be sent back in assistant messages to keep
+// the context. In streaming deltas Index identifies the call and later chunks
+// carry status updates, code fragments and finally the results.
+type ChatServerToolCall struct {
+	Index  *int   `json:"index,omitempty"`
+	ID     string `json:"id,omitempty"`
+	Type   string `json:"type,omitempty"`   // "web_search" | "code_interpreter"
+	Status string `json:"status,omitempty"` // "in_progress" | "searching" | "interpreting" | "completed" | "failed"
+
+	// type=web_search
+	Action *apicompat.WebSearchAction `json:"action,omitempty"`
+
+	// type=code_interpreter
+	Code        string                            `json:"code,omitempty"`
+	ContainerID string                            `json:"container_id,omitempty"`
+	Outputs     []apicompat.CodeInterpreterOutput `json:"outputs,omitempty"`
+}
+
+// ChatAnnotation is a citation in the assistant message content.
+type ChatAnnotation struct {
+	Type        string           `json:"type"` // "url_citation"
+	URLCitation *ChatURLCitation `json:"url_citation,omitempty"`
+}
+
+// ChatURLCitation references a web page used by web search.
+type ChatURLCitation struct {
+	URL        string `json:"url"`
+	Title      string `json:"title,omitempty"`
+	StartIndex int    `json:"start_index"`
+	EndIndex   int    `json:"end_index"`
+}
+
+// ChatFunctionCall carries the function name and JSON-encoded arguments.
+type ChatFunctionCall struct {
+	Name      string `json:"name,omitempty"`
+	Arguments string `json:"arguments"`
+}
+
+// ChatResponseFormat selects structured output.
+type ChatResponseFormat struct {
+	Type       string          `json:"type"` // "text" | "json_object" | "json_schema"
+	JSONSchema *ChatJSONSchema `json:"json_schema,omitempty"`
+}
+
+// ChatJSONSchema is the json_schema response format payload.
+type ChatJSONSchema struct {
+	Name        string          `json:"name"`
+	Description string          `json:"description,omitempty"`
+	Schema      json.RawMessage `json:"schema,omitempty"`
+	Strict      *bool           `json:"strict,omitempty"`
+}
+
+// ChatCompletion is the non-streaming response object.
+type ChatCompletion struct {
+	ID          string       `json:"id"`
+	Object      string       `json:"object"` // "chat.completion"
+	Created     int64        `json:"created"`
+	Model       string       `json:"model"`
+	Choices     []ChatChoice `json:"choices"`
+	Usage       *ChatUsage   `json:"usage,omitempty"`
+	ServiceTier string       `json:"service_tier,omitempty"`
+}
+
+// ChatChoice is one completion choice.
+type ChatChoice struct {
+	Index        int                 `json:"index"`
+	Message      ChatResponseMessage `json:"message"`
+	FinishReason string              `json:"finish_reason"`
+}
+
+// ChatResponseMessage is the assistant message in a completion. Content is
+// null when the model only issued tool calls.
+type ChatResponseMessage struct {
+	Role             string               `json:"role"`
+	Content          *string              `json:"content"`
+	ReasoningContent string               `json:"reasoning_content,omitempty"`
+	ToolCalls        []ChatToolCall       `json:"tool_calls,omitempty"`
+	ServerToolCalls  []ChatServerToolCall `json:"server_tool_calls,omitempty"`
+	Annotations      []ChatAnnotation     `json:"annotations,omitempty"`
+}
+
+// ChatCompletionChunk is one streaming chunk.
+type ChatCompletionChunk struct {
+	ID      string            `json:"id"`
+	Object  string            `json:"object"` // "chat.completion.chunk"
+	Created int64             `json:"created"`
+	Model   string            `json:"model"`
+	Choices []ChatChunkChoice `json:"choices"`
+	Usage   *ChatUsage        `json:"usage,omitempty"`
+}
+
+// ChatChunkChoice is one choice delta. FinishReason is null until the final chunk.
+type ChatChunkChoice struct {
+	Index        int       `json:"index"`
+	Delta        ChatDelta `json:"delta"`
+	FinishReason *string   `json:"finish_reason"`
+}
+
+// ChatDelta carries incremental message content.
+type ChatDelta struct {
+	Role             string               `json:"role,omitempty"`
+	Content          *string              `json:"content,omitempty"`
+	ReasoningContent *string              `json:"reasoning_content,omitempty"`
+	ToolCalls        []ChatToolCall       `json:"tool_calls,omitempty"`
+	ServerToolCalls  []ChatServerToolCall `json:"server_tool_calls,omitempty"`
+	Annotations      []ChatAnnotation     `json:"annotations,omitempty"`
+}
+
+// ChatUsage holds token counts in Chat Completions format.
+type ChatUsage struct {
+	PromptTokens            int                          `json:"prompt_tokens"`
+	CompletionTokens        int                          `json:"completion_tokens"`
+	TotalTokens             int                          `json:"total_tokens"`
+	PromptTokensDetails     *ChatPromptTokensDetails     `json:"prompt_tokens_details,omitempty"`
+	CompletionTokensDetails *ChatCompletionTokensDetails `json:"completion_tokens_details,omitempty"`
+}
+
+// ChatPromptTokensDetails breaks down prompt token usage.
+type ChatPromptTokensDetails struct {
+	CachedTokens int `json:"cached_tokens"`
+}
+
+// ChatCompletionTokensDetails breaks down completion token usage.
+type ChatCompletionTokensDetails struct {
+	ReasoningTokens int `json:"reasoning_tokens"`
+}
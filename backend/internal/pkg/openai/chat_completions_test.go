@@ -0,0 +1,658 @@
+package openai
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/ShaohongDong/sub2api/internal/pkg/apicompat"
+)
+
+func TestChatCompletionsToResponses(t *testing.T) {
+	body := `{
+		"model": "gpt-5.1",
+		"messages": [
+			{"role": "system", "content": "Be brief."},
+			{"role": "developer", "content": [{"type": "text", "text": "Use tools."}]},
+			{"role": "user", "content": [{"type": "text", "text": "Weather?"}, {"type": "image_url", "image_url": {"url": "data:image/png;base64,AAA"}}]},
+			{"role": "assistant", "content": null, "tool_calls": [{"id": "call_1", "type": "function", "function": {"name": "get_weather", "arguments": "{\"city\":\"Paris\"}"}}]},
+			{"role": "tool", "tool_call_id": "call_1", "content": "sunny"}
+		],
+		"max_tokens": 4,
+		"max_completion_tokens": 256,
+		"reasoning_effort": "low",
+		"tools": [
+			{"type": "function", "function": {"name": "get_weather", "parameters": {"type": "object", "properties": {"city": {"type": "string"}}}}},
+			{"type": "function", "function": {"name": "noop"}}
+		],
+		"tool_choice": {"type": "function", "function": {"name": "get_weather"}},
+		"response_format": {"type": "json_schema", "json_schema": {"name": "out", "schema": {"type": "object"}, "strict": true}}
+	}`
+	var req ChatCompletionRequest
+	if err := json.Unmarshal([]byte(body), &req); err != nil {
+		t.Fatalf("unmarshal: %v", err)
+	}
+
+	out, err := ChatCompletionsToResponses(&req)
+	if err != nil {
+		t.Fatalf("ChatCompletionsToResponses: %v", err)
+	}
+	if out.Instructions != "Be brief.\n\nUse tools." {
+		t.Fatalf("instructions = %q", out.Instructions)
+	}
+	if out.MaxOutputTokens == nil || *out.MaxOutputTokens != 256 {
+		t.Fatalf("max_output_tokens = %v", out.MaxOutputTokens)
+	}
+	if out.Reasoning == nil || out.Reasoning.Effort != "low" {
+		t.Fatalf("reasoning = %+v", out.Reasoning)
+	}
+	if string(out.ToolChoice) != `{"name":"get_weather","type":"function"}` {
+		t.Fatalf("tool_choice = %s", out.ToolChoice)
+	}
+	if len(out.Tools) != 2 || out.Tools[0].Name != "get_weather" || string(out.Tools[1].Parameters) != `{"type":"object","properties":{}}` {
+		t.Fatalf("tools = %+v", out.Tools)
+	}
+	if !strings.Contains(string(out.Text), `"type":"json_schema"`) || !strings.Contains(string(out.Text), `"name":"out"`) {
+		t.Fatalf("text = %s", out.Text)
+	}
+
+	var items []apicompat.ResponsesInputItem
+	if err := json.Unmarshal(out.Input, &items); err != nil {
+		t.Fatalf("unmarshal input: %v", err)
+	}
+	if len(items) != 3 {
+		t.Fatalf("len(items) = %d, want 3", len(items))
+	}
+	if items[0].Role != "user" || !strings.Contains(string(items[0].Content), `"input_image"`) {
+		t.Fatalf("user item = %+v", items[0])
+	}
+	if items[1].Type != "function_call" || items[1].CallID != "call_1" || items[1].ID != "" || items[1].Arguments != `{"city":"Paris"}` {
+		t.Fatalf("function_call item = %+v", items[1])
+	}
+	if items[2].Type != "function_call_output" || items[2].CallID != "call_1" || items[2].Output != "sunny" {
+		t.Fatalf("function_call_output item = %+v", items[2])
+	}
+}
+
+func TestChatCompletionsToResponses_FilePart(t *testing.T) {
+	body := `{"model":"gpt-5.1","messages":[{"role":"user","content":[{"type":"text","text":"Summarize"},{"type":"file","file":{"filename":"a.pdf","file_data":"data:application/pdf;base64,JVBE"}}]}]}`
+	var req ChatCompletionRequest
+	if err := json.Unmarshal([]byte(body), &req); err != nil {
+		t.Fatalf("unmarshal: %v", err)
+	}
+	out, err := ChatCompletionsToResponses(&req)
+	if err != nil {
+		t.Fatalf("ChatCompletionsToResponses: %v", err)
+	}
+	var items []apicompat.ResponsesInputItem
+	if err := json.Unmarshal(out.Input, &items); err != nil {
+		t.Fatalf("unmarshal input: %v", err)
+	}
+	var parts []apicompat.ResponsesContentPart
+	if err := json.Unmarshal(items[0].Content, &parts); err != nil {
+		t.Fatalf("unmarshal content: %v", err)
+	}
+	if len(parts) != 2 || parts[1].Type != "input_file" || parts[1].Filename != "a.pdf" || parts[1].FileData != "data:application/pdf;base64,JVBE" {
+		t.Fatalf("parts = %+v", parts)
+	}
+}
+
+func TestChatCompletionsToResponses_SamplingParams(t *testing.T) {
+	body := `{"model":"m","messages":[{"role":"user","content":"hi"}],"stop":["END",""],"top_k":20,` +
+		`"logit_bias":{"50256":-100},"presence_penalty":0.5,"frequency_penalty":0}`
+	var req ChatCompletionRequest
+	if err := json.Unmarshal([]byte(body), &req); err != nil {
+		t.Fatal(err)
+	}
+	out, err := ChatCompletionsToResponses(&req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(out.StopSequences, []string{"END"}) || out.TopK == nil || *out.TopK != 20 {
+		t.Fatalf("stop = %v, top_k = %v", out.StopSequences, out.TopK)
+	}
+	if !reflect.DeepEqual(out.DroppedParams, []string{"logit_bias", "presence_penalty"}) {
+		t.Fatalf("dropped = %v", out.DroppedParams)
+	}
+	if got := out.DroppedForResponses(); !reflect.DeepEqual(got, []string{"logit_bias", "presence_penalty", "stop", "top_k"}) {
+		t.Fatalf("dropped for responses = %v", got)
+	}
+
+	req.Stop = json.RawMessage(`"###"`)
+	out, err = ChatCompletionsToResponses(&req)
+	if err != nil || !reflect.DeepEqual(out.StopSequences, []string{"###"}) {
+		t.Fatalf("stop = %v, err = %v", out.StopSequences, err)
+	}
+	req.Stop = json.RawMessage(`42`)
+	if _, err := ChatCompletionsToResponses(&req); err == nil {
+		t.Fatal("expected error for non-string stop")
+	}
+}
+
+func TestChatCompletionsToResponses_Rejects(t *testing.T) {
+	n := 2
+	if _, err := ChatCompletionsToResponses(&ChatCompletionRequest{Model: "m", N: &n}); err == nil {
+		t.Fatal("expected error for n > 1")
+	}
+	if _, err := ChatCompletionsToResponses(&ChatCompletionRequest{Model: "m", Messages: []ChatMessage{{Role: "function"}}}); err == nil {
+		t.Fatal("expected error for unsupported role")
+	}
+	if _, err := ChatCompletionsToResponses(&ChatCompletionRequest{Model: "m", ResponseFormat: &ChatResponseFormat{Type: "json_schema"}}); err == nil {
+		t.Fatal("expected error for json_schema without schema")
+	}
+}
+
+func TestResponsesToChatCompletion(t *testing.T) {
+	resp := &apicompat.ResponsesResponse{
+		ID:     "resp_abc",
+		Status: "completed",
+		Output: []apicompat.ResponsesOutput{
+			{Type: "reasoning", Summary: []apicompat.ResponsesSummary{{Type: "summary_text", Text: "thinking"}}},
+			{Type: "function_call", CallID: "call_1", Name: "get_weather", Arguments: `{"city":"Paris"}`},
+		},
+		Usage: &apicompat.ResponsesUsage{InputTokens: 10, OutputTokens: 5, InputTokensDetails: &apicompat.ResponsesInputTokensDetails{CachedTokens: 2}},
+	}
+
+	out := ResponsesToChatCompletion(resp, "gpt-5.1", true)
+	if out.ID != "chatcmpl-abc" || out.Object != "chat.completion" {
+		t.Fatalf("id/object = %s/%s", out.ID, out.Object)
+	}
+	choice := out.Choices[0]
+	if choice.FinishReason != "tool_calls" {
+		t.Fatalf("finish_reason = %s", choice.FinishReason)
+	}
+	if choice.Message.Content != nil {
+		t.Fatalf("content = %q, want null", *choice.Message.Content)
+	}
+	if choice.Message.ReasoningContent != "thinking" {
+		t.Fatalf("reasoning_content = %q", choice.Message.ReasoningContent)
+	}
+	if len(choice.Message.ToolCalls) != 1 || choice.Message.ToolCalls[0].ID != "call_1" || choice.Message.ToolCalls[0].Function.Arguments != `{"city":"Paris"}` {
+		t.Fatalf("tool_calls = %+v", choice.Message.ToolCalls)
+	}
+	if out.Usage.TotalTokens != 15 || out.Usage.PromptTokensDetails.CachedTokens != 2 {
+		t.Fatalf("usage = %+v", out.Usage)
+	}
+
+	truncated := ResponsesToChatCompletion(&apicompat.ResponsesResponse{
+		Status:            "incomplete",
+		IncompleteDetails: &apicompat.ResponsesIncompleteDetails{Reason: "max_output_tokens"},
+		Output:            []apicompat.ResponsesOutput{{Type: "message", Content: []apicompat.ResponsesContentPart{{Type: "output_text", Text: "Hel"}}}},
+	}, "gpt-5.1", true)
+	if truncated.Choices[0].FinishReason != "length" || *truncated.Choices[0].Message.Content != "Hel" {
+		t.Fatalf("truncated = %+v", truncated.Choices[0])
+	}
+
+	if hidden := ResponsesToChatCompletion(resp, "gpt-5.1", false); hidden.Choices[0].Message.ReasoningContent != "" {
+		t.Fatalf("reasoning_content = %q, want suppressed", hidden.Choices[0].Message.ReasoningContent)
+	}
+}
+
+func TestResponsesEventToChatChunks_ReasoningSuppressed(t *testing.T) {
+	state := NewResponsesEventToChatState()
+	state.IncludeReasoning = false
+
+	var chunks []ChatCompletionChunk
+	for _, evt := range []apicompat.ResponsesStreamEvent{
+		{Type: "response.reasoning_summary_text.delta", Delta: "hidden"},
+		{Type: "response.reasoning_text.done", Text: "hidden"},
+		{Type: "response.output_text.delta", Delta: "Hi"},
+	} {
+		chunks = append(chunks, ResponsesEventToChatChunks(&evt, state)...)
+	}
+	for _, chunk := range chunks {
+		if chunk.Choices[0].Delta.ReasoningContent != nil {
+			t.Fatalf("unexpected reasoning chunk: %+v", chunk)
+		}
+	}
+	if got := state.EmittedText(); got != "Hi" {
+		t.Fatalf("emitted text = %q", got)
+	}
+}
+
+func TestResponsesEventToChatChunks(t *testing.T) {
+	state := NewResponsesEventToChatState()
+	state.Model = "gpt-5.1"
+	state.IncludeUsage = true
+
+	events := []apicompat.ResponsesStreamEvent{
+		{Type: "response.created", Response: &apicompat.ResponsesResponse{ID: "resp_1"}},
+		{Type: "response.output_text.delta", Delta: "Hi"},
+		{Type: "response.output_item.added", OutputIndex: 1, Item: &apicompat.ResponsesOutput{Type: "function_call", CallID: "call_9", Name: "f"}},
+		{Type: "response.function_call_arguments.delta", OutputIndex: 1, Delta: `{"a":`},
+		{Type: "response.function_call_arguments.delta", OutputIndex: 1, Delta: `1}`},
+		{Type: "response.completed", Response: &apicompat.ResponsesResponse{Status: "completed", Usage: &apicompat.ResponsesUsage{InputTokens: 3, OutputTokens: 4}}},
+	}
+	var chunks []ChatCompletionChunk
+	for i := range events {
+		chunks = append(chunks, ResponsesEventToChatChunks(&events[i], state)...)
+	}
+
+	if len(chunks) != 7 {
+		t.Fatalf("len(chunks) = %d, want 7", len(chunks))
+	}
+	if chunks[0].Choices[0].Delta.Role != "assistant" || chunks[0].ID != "chatcmpl-1" {
+		t.Fatalf("first chunk = %+v", chunks[0])
+	}
+	if *chunks[1].Choices[0].Delta.Content != "Hi" {
+		t.Fatalf("content chunk = %+v", chunks[1])
+	}
+	start := chunks[2].Choices[0].Delta.ToolCalls[0]
+	if *start.Index != 0 || start.ID != "call_9" || start.Function.Name != "f" {
+		t.Fatalf("tool call start = %+v", start)
+	}
+	args := chunks[3].Choices[0].Delta.ToolCalls[0].Function.Arguments + chunks[4].Choices[0].Delta.ToolCalls[0].Function.Arguments
+	if args != `{"a":1}` {
+		t.Fatalf("arguments = %s", args)
+	}
+	if reason := chunks[5].Choices[0].FinishReason; reason == nil || *reason != "tool_calls" {
+		t.Fatalf("finish_reason = %v", reason)
+	}
+	if len(chunks[6].Choices) != 0 || chunks[6].Usage == nil || chunks[6].Usage.TotalTokens != 7 {
+		t.Fatalf("usage chunk = %+v", chunks[6])
+	}
+	if got := FinalizeResponsesChatStream(state); got != nil {
+		t.Fatalf("finalize after completed = %+v", got)
+	}
+
+	sse, err := ChatChunkToSSE(chunks[1])
+	if err != nil {
+		t.Fatalf("ChatChunkToSSE: %v", err)
+	}
+	if !strings.HasPrefix(sse, "data: {") || !strings.Contains(sse, `"finish_reason":null`) {
+		t.Fatalf("sse = %q", sse)
+	}
+}
+
+func TestResponsesEventToChatChunks_ToolCallOrdering(t *testing.T) {
+	state := NewResponsesEventToChatState()
+	events := []apicompat.ResponsesStreamEvent{
+		{Type: "response.created", Response: &apicompat.ResponsesResponse{ID: "resp_2"}},
+		// 参数增量早于 output_item.added 到达
+		{Type: "response.function_call_arguments.delta", OutputIndex: 0, Delta: `{"q":`},
+		{Type: "response.output_item.added", OutputIndex: 0, Item: &apicompat.ResponsesOutput{Type: "function_call", ID: "fc_a", CallID: "call_a", Name: "search"}},
+		{Type: "response.output_item.added", OutputIndex: 1, Item: &apicompat.ResponsesOutput{Type: "function_call", ID: "fc_b", CallID: "call_b", Name: "lookup"}},
+		// 并行调用交错，且只携带 item_id
+		{Type: "response.function_call_arguments.delta", ItemID: "fc_b", Delta: `{"id":2}`},
+		{Type: "response.function_call_arguments.delta", ItemID: "fc_a", Delta: `"x"}`},
+		{Type: "response.function_call_arguments.done", ItemID: "fc_a", Arguments: `{"q":"x"}`},
+		{Type: "response.output_item.done", OutputIndex: 1, Item: &apicompat.ResponsesOutput{Type: "function_call", ID: "fc_b", CallID: "call_b", Name: "lookup", Arguments: `{"id":2}`}},
+		{Type: "response.completed", Response: &apicompat.ResponsesResponse{Status: "completed", Output: []apicompat.ResponsesOutput{
+			{Type: "function_call", CallID: "call_a", Name: "search", Arguments: `{"q":"x"}`},
+			{Type: "function_call", CallID: "call_b", Name: "lookup", Arguments: `{"id":2}`},
+		}}},
+	}
+	var chunks []ChatCompletionChunk
+	for i := range events {
+		chunks = append(chunks, ResponsesEventToChatChunks(&events[i], state)...)
+	}
+
+	args := map[int]string{}
+	ids := map[int]string{}
+	var order []int
+	var finish string
+	for _, chunk := range chunks {
+		if len(chunk.Choices) == 0 {
+			continue
+		}
+		if chunk.Choices[0].FinishReason != nil {
+			finish = *chunk.Choices[0].FinishReason
+		}
+		for _, tc := range chunk.Choices[0].Delta.ToolCalls {
+			if tc.ID != "" {
+				ids[*tc.Index] = tc.ID
+				order = append(order, *tc.Index)
+			} else if _, started := ids[*tc.Index]; !started {
+				t.Fatalf("arguments for index %d sent before its header", *tc.Index)
+			}
+			args[*tc.Index] += tc.Function.Arguments
+		}
+	}
+	if len(order) != 2 || order[0] != 0 || order[1] != 1 || ids[0] != "call_a" || ids[1] != "call_b" {
+		t.Fatalf("tool call headers = %v %v", order, ids)
+	}
+	if args[0] != `{"q":"x"}` || args[1] != `{"id":2}` {
+		t.Fatalf("arguments = %v", args)
+	}
+	if finish != "tool_calls" {
+		t.Fatalf("finish_reason = %q", finish)
+	}
+}
+
+func TestResponsesEventToChatChunks_DoneOnlyUpstream(t *testing.T) {
+	state := NewResponsesEventToChatState()
+	events := []apicompat.ResponsesStreamEvent{
+		{Type: "response.reasoning_summary_text.delta", SummaryIndex: 0, Delta: "a"},
+		{Type: "response.reasoning_summary_text.delta", SummaryIndex: 1, Delta: "b"},
+		{Type: "response.reasoning_summary_text.done", SummaryIndex: 1, Text: "b"},
+		{Type: "response.output_text.done", OutputIndex: 1, Text: "Hello"},
+		{Type: "response.incomplete", Response: &apicompat.ResponsesResponse{
+			Status:            "incomplete",
+			IncompleteDetails: &apicompat.ResponsesIncompleteDetails{Reason: "max_output_tokens"},
+			Output: []apicompat.ResponsesOutput{
+				{Type: "message", Content: []apicompat.ResponsesContentPart{{Type: "output_text", Text: "Hello"}}},
+			},
+		}},
+	}
+	var reasoning, content, finish string
+	for i := range events {
+		for _, chunk := range ResponsesEventToChatChunks(&events[i], state) {
+			choice := chunk.Choices[0]
+			if choice.Delta.ReasoningContent != nil {
+				reasoning += *choice.Delta.ReasoningContent
+			}
+			if choice.Delta.Content != nil {
+				content += *choice.Delta.Content
+			}
+			if choice.FinishReason != nil {
+				finish = *choice.FinishReason
+			}
+		}
+	}
+	if reasoning != "a\n\nb" || content != "Hello" || finish != "length" {
+		t.Fatalf("reasoning=%q content=%q finish=%q", reasoning, content, finish)
+	}
+}
+
+func TestResponsesEventToChatChunks_IncludeUsage(t *testing.T) {
+	// 终止事件未携带 response.usage，用量来自事件顶层
+	state := NewResponsesEventToChatState()
+	state.IncludeUsage = true
+	events := []apicompat.ResponsesStreamEvent{
+		{Type: "response.output_text.delta", Delta: "Hi"},
+		{Type: "response.completed", Usage: &apicompat.ResponsesUsage{
+			InputTokens:         5,
+			OutputTokens:        7,
+			OutputTokensDetails: &apicompat.ResponsesOutputTokensDetails{ReasoningTokens: 3},
+		}, Response: &apicompat.ResponsesResponse{Status: "completed"}},
+	}
+	var chunks []ChatCompletionChunk
+	for i := range events {
+		chunks = append(chunks, ResponsesEventToChatChunks(&events[i], state)...)
+	}
+	last := chunks[len(chunks)-1]
+	if len(last.Choices) != 0 || last.Usage == nil || last.Usage.TotalTokens != 12 || last.Usage.CompletionTokensDetails.ReasoningTokens != 3 {
+		t.Fatalf("usage chunk = %+v", last)
+	}
+
+	// 上游未发送终止事件：补发 finish_reason 与全零用量分片
+	truncated := NewResponsesEventToChatState()
+	truncated.IncludeUsage = true
+	ResponsesEventToChatChunks(&apicompat.ResponsesStreamEvent{Type: "response.output_text.delta", Delta: "x"}, truncated)
+	final := FinalizeResponsesChatStream(truncated)
+	if len(final) != 2 || final[1].Usage == nil || final[1].Usage.TotalTokens != 0 {
+		t.Fatalf("finalize = %+v", final)
+	}
+}
+
+func TestChatCompletionsToResponses_ImageURLForms(t *testing.T) {
+	body := `{
+		"model": "gpt-5.1",
+		"messages": [
+			{"role": "user", "content": [
+				{"type": "image_url", "image_url": "https://example.com/a.png"},
+				{"type": "image_url", "image_url": {"url": "data:image/png;base64,AAA", "detail": "low"}}
+			]}
+		]
+	}`
+	var req ChatCompletionRequest
+	if err := json.Unmarshal([]byte(body), &req); err != nil {
+		t.Fatalf("unmarshal: %v", err)
+	}
+
+	out, err := ChatCompletionsToResponses(&req)
+	if err != nil {
+		t.Fatalf("ChatCompletionsToResponses: %v", err)
+	}
+	var items []apicompat.ResponsesInputItem
+	if err := json.Unmarshal(out.Input, &items); err != nil {
+		t.Fatalf("unmarshal input: %v", err)
+	}
+	if len(items) != 1 {
+		t.Fatalf("items = %+v", items)
+	}
+	var parts []apicompat.ResponsesContentPart
+	if err := json.Unmarshal(items[0].Content, &parts); err != nil {
+		t.Fatalf("unmarshal content: %v", err)
+	}
+	want := []apicompat.ResponsesContentPart{
+		{Type: "input_image", ImageURL: "https://example.com/a.png"},
+		{Type: "input_image", ImageURL: "data:image/png;base64,AAA", Detail: "low"},
+	}
+	if !reflect.DeepEqual(parts, want) {
+		t.Fatalf("parts = %+v", parts)
+	}
+}
+
+func TestAppendResponsesContinuation(t *testing.T) {
+	req := &apicompat.ResponsesRequest{Input: json.RawMessage(`[{"role":"user","content":[{"type":"input_text","text":"hi"}]}]`)}
+	if err := AppendResponsesContinuation(req, ""); err != nil {
+		t.Fatalf("empty partial: %v", err)
+	}
+	if string(req.Input) != `[{"role":"user","content":[{"type":"input_text","text":"hi"}]}]` {
+		t.Fatalf("input changed without partial text: %s", req.Input)
+	}
+
+	if err := AppendResponsesContinuation(req, "Hel"); err != nil {
+		t.Fatalf("append: %v", err)
+	}
+	var items []apicompat.ResponsesInputItem
+	if err := json.Unmarshal(req.Input, &items); err != nil {
+		t.Fatalf("unmarshal input: %v", err)
+	}
+	if len(items) != 3 || items[1].Role != "assistant" || items[2].Role != "user" {
+		t.Fatalf("unexpected items: %s", req.Input)
+	}
+	if !strings.Contains(string(items[1].Content), `"text":"Hel"`) {
+		t.Fatalf("assistant item missing partial text: %s", items[1].Content)
+	}
+
+	// 字符串 input 先包装为 user 消息
+	req = &apicompat.ResponsesRequest{Input: json.RawMessage(`"hi"`)}
+	if err := AppendResponsesContinuation(req, "Hel"); err != nil {
+		t.Fatalf("append to string input: %v", err)
+	}
+	items = nil
+	if err := json.Unmarshal(req.Input, &items); err != nil || len(items) != 3 || string(items[0].Content) != `"hi"` {
+		t.Fatalf("unexpected items for string input: %s", req.Input)
+	}
+}
+
+func TestChatCompletionsToResponses_BuiltinTools(t *testing.T) {
+	body := `{
+		"model": "gpt-5.1",
+		"messages": [
+			{"role": "user", "content": "Plot today's BTC price"},
+			{"role": "assistant", "content": "Done.", "server_tool_calls": [
+				{"id": "ws_1", "type": "web_search", "status": "completed", "action": {"type": "search", "query": "btc price"}},
+				{"id": "ci_1", "type": "code_interpreter", "code": "print(1)", "container_id": "cntr_1", "outputs": [{"type": "logs", "logs": "1"}]},
+				{"type": "web_search"}
+			]},
+			{"role": "user", "content": "Again"}
+		],
+		"tools": [
+			{"type": "web_search_preview", "search_context_size": "low"},
+			{"type": "code_interpreter"},
+			{"type": "file_search"}
+		]
+	}`
+	var req ChatCompletionRequest
+	if err := json.Unmarshal([]byte(body), &req); err != nil {
+		t.Fatalf("unmarshal: %v", err)
+	}
+	out, err := ChatCompletionsToResponses(&req)
+	if err != nil {
+		t.Fatalf("ChatCompletionsToResponses: %v", err)
+	}
+	if len(out.Tools) != 2 || out.Tools[0].Type != "web_search" || out.Tools[0].SearchContextSize != "low" {
+		t.Fatalf("tools = %+v", out.Tools)
+	}
+	if out.Tools[1].Type != "code_interpreter" || string(out.Tools[1].Container) != `{"type":"auto"}` {
+		t.Fatalf("code_interpreter tool = %+v", out.Tools[1])
+	}
+	if !reflect.DeepEqual(out.Include, []string{"web_search_call.action.sources", "code_interpreter_call.outputs"}) {
+		t.Fatalf("include = %v", out.Include)
+	}
+
+	var items []apicompat.ResponsesInputItem
+	if err := json.Unmarshal(out.Input, &items); err != nil {
+		t.Fatalf("unmarshal input: %v", err)
+	}
+	if len(items) != 5 {
+		t.Fatalf("len(items) = %d, want 5", len(items))
+	}
+	if items[1].Type != "web_search_call" || items[1].ID != "ws_1" || items[1].Action == nil || items[1].Action.Query != "btc price" {
+		t.Fatalf("web_search_call item = %+v", items[1])
+	}
+	if items[2].Type != "code_interpreter_call" || items[2].Status != "completed" || items[2].ContainerID != "cntr_1" || len(items[2].Outputs) != 1 {
+		t.Fatalf("code_interpreter_call item = %+v", items[2])
+	}
+	if items[3].Role != "assistant" {
+		t.Fatalf("assistant item = %+v", items[3])
+	}
+}
+
+func TestChatCompletionsToResponses_WebSearchOptions(t *testing.T) {
+	body := `{"model":"gpt-5.1","messages":[{"role":"user","content":"news"}],
+		"web_search_options":{"search_context_size":"high","user_location":{"type":"approximate","approximate":{"country":"FR","city":"Paris"}}}}`
+	var req ChatCompletionRequest
+	if err := json.Unmarshal([]byte(body), &req); err != nil {
+		t.Fatalf("unmarshal: %v", err)
+	}
+	out, err := ChatCompletionsToResponses(&req)
+	if err != nil {
+		t.Fatalf("ChatCompletionsToResponses: %v", err)
+	}
+	if len(out.Tools) != 1 || out.Tools[0].Type != "web_search" || out.Tools[0].SearchContextSize != "high" {
+		t.Fatalf("tools = %+v", out.Tools)
+	}
+	var loc map[string]string
+	if err := json.Unmarshal(out.Tools[0].UserLocation, &loc); err != nil {
+		t.Fatalf("user_location = %s", out.Tools[0].UserLocation)
+	}
+	if !reflect.DeepEqual(loc, map[string]string{"type": "approximate", "country": "FR", "city": "Paris"}) {
+		t.Fatalf("user_location = %v", loc)
+	}
+}
+
+func TestResponsesToChatCompletion_BuiltinTools(t *testing.T) {
+	resp := &apicompat.ResponsesResponse{
+		ID:     "resp_1",
+		Status: "completed",
+		Output: []apicompat.ResponsesOutput{
+			{Type: "web_search_call", ID: "ws_1", Status: "completed", Action: &apicompat.WebSearchAction{
+				Type: "search", Query: "btc price", Sources: []apicompat.WebSearchSource{{Type: "url", URL: "https://example.com/btc"}},
+			}},
+			{Type: "code_interpreter_call", ID: "ci_1", Status: "completed", Code: "print(1)", ContainerID: "cntr_1",
+				Outputs: []apicompat.CodeInterpreterOutput{{Type: "logs", Logs: "1"}}},
+			{Type: "message", Content: []apicompat.ResponsesContentPart{
+				{Type: "output_text", Text: "Price: "},
+				{Type: "output_text", Text: "€60k", Annotations: []apicompat.ResponsesAnnotation{
+					{Type: "url_citation", URL: "https://example.com/btc", Title: "BTC", StartIndex: 0, EndIndex: 4},
+					{Type: "container_file_citation", FileID: "cfile_1", ContainerID: "cntr_1"},
+				}},
+			}},
+		},
+	}
+	msg := ResponsesToChatCompletion(resp, "gpt-5.1", true).Choices[0].Message
+	if msg.Content == nil || *msg.Content != "Price: €60k" {
+		t.Fatalf("content = %v", msg.Content)
+	}
+	if len(msg.ToolCalls) != 0 {
+		t.Fatalf("tool_calls = %+v", msg.ToolCalls)
+	}
+	if len(msg.ServerToolCalls) != 2 {
+		t.Fatalf("server_tool_calls = %+v", msg.ServerToolCalls)
+	}
+	ws, ci := msg.ServerToolCalls[0], msg.ServerToolCalls[1]
+	if ws.Type != "web_search" || ws.ID != "ws_1" || ws.Action == nil || len(ws.Action.Sources) != 1 {
+		t.Fatalf("web_search = %+v", ws)
+	}
+	if ci.Type != "code_interpreter" || ci.Code != "print(1)" || ci.ContainerID != "cntr_1" || ci.Outputs[0].Logs != "1" {
+		t.Fatalf("code_interpreter = %+v", ci)
+	}
+	if len(msg.Annotations) != 1 {
+		t.Fatalf("annotations = %+v", msg.Annotations)
+	}
+	if c := msg.Annotations[0].URLCitation; c.URL != "https://example.com/btc" || c.StartIndex != 7 || c.EndIndex != 11 {
+		t.Fatalf("url_citation = %+v", c)
+	}
+}
+
+func TestResponsesEventToChatChunks_BuiltinToolProgress(t *testing.T) {
+	state := NewResponsesEventToChatState()
+	events := []apicompat.ResponsesStreamEvent{
+		{Type: "response.created", Response: &apicompat.ResponsesResponse{ID: "resp_3"}},
+		{Type: "response.output_item.added", OutputIndex: 0, Item: &apicompat.ResponsesOutput{Type: "web_search_call", ID: "ws_1", Status: "in_progress"}},
+		{Type: "response.web_search_call.in_progress", OutputIndex: 0, ItemID: "ws_1"},
+		{Type: "response.web_search_call.searching", OutputIndex: 0, ItemID: "ws_1"},
+		{Type: "response.output_item.done", OutputIndex: 0, Item: &apicompat.ResponsesOutput{Type: "web_search_call", ID: "ws_1", Status: "completed", Action: &apicompat.WebSearchAction{Type: "search", Query: "btc"}}},
+		{Type: "response.output_item.added", OutputIndex: 1, Item: &apicompat.ResponsesOutput{Type: "code_interpreter_call", ID: "ci_1", Status: "in_progress"}},
+		{Type: "response.code_interpreter_call_code.delta", ItemID: "ci_1", Delta: "print("},
+		{Type: "response.code_interpreter_call_code.delta", ItemID: "ci_1", Delta: "1)"},
+		{Type: "response.code_interpreter_call_code.done", ItemID: "ci_1", Code: "print(1)"},
+		{Type: "response.code_interpreter_call.interpreting", ItemID: "ci_1"},
+		{Type: "response.output_item.done", OutputIndex: 1, Item: &apicompat.ResponsesOutput{Type: "code_interpreter_call", ID: "ci_1", Status: "completed", Code: "print(1)", ContainerID: "cntr_1",
+			Outputs: []apicompat.CodeInterpreterOutput{{Type: "logs", Logs: "1"}}}},
+		{Type: "response.output_text.delta", OutputIndex: 2, Delta: "BTC"},
+		{Type: "response.output_text.annotation.added", OutputIndex: 2, Annotation: &apicompat.ResponsesAnnotation{Type: "url_citation", URL: "https://example.com", StartIndex: 0, EndIndex: 3}},
+		{Type: "response.completed", Response: &apicompat.ResponsesResponse{Status: "completed", Output: []apicompat.ResponsesOutput{
+			{Type: "web_search_call", ID: "ws_1", Status: "completed"},
+			{Type: "code_interpreter_call", ID: "ci_1", Status: "completed"},
+			// 流中未出现的内置工具调用在终止事件补发
+			{Type: "web_search_call", ID: "ws_2", Status: "completed", Action: &apicompat.WebSearchAction{Type: "open_page", URL: "https://example.com"}},
+		}}},
+	}
+	var chunks []ChatCompletionChunk
+	for i := range events {
+		chunks = append(chunks, ResponsesEventToChatChunks(&events[i], state)...)
+	}
+
+	var calls []ChatServerToolCall
+	var annotations []ChatAnnotation
+	var finish string
+	for _, chunk := range chunks {
+		if len(chunk.Choices) == 0 {
+			continue
+		}
+		if chunk.Choices[0].FinishReason != nil {
+			finish = *chunk.Choices[0].FinishReason
+		}
+		if len(chunk.Choices[0].Delta.ToolCalls) > 0 {
+			t.Fatalf("server tool surfaced as tool_calls: %+v", chunk)
+		}
+		calls = append(calls, chunk.Choices[0].Delta.ServerToolCalls...)
+		annotations = append(annotations, chunk.Choices[0].Delta.Annotations...)
+	}
+	if finish != "stop" {
+		t.Fatalf("finish_reason = %q", finish)
+	}
+
+	var statuses []string
+	code := ""
+	for _, c := range calls {
+		if *c.Index == 0 {
+			statuses = append(statuses, c.Status)
+		}
+		if *c.Index == 1 {
+			code += c.Code
+		}
+	}
+	if !reflect.DeepEqual(statuses, []string{"in_progress", "searching", "completed"}) {
+		t.Fatalf("web_search statuses = %v", statuses)
+	}
+	if code != "print(1)" {
+		t.Fatalf("code = %q", code)
+	}
+	if calls[0].ID != "ws_1" || calls[0].Type != "web_search" || calls[2].Action == nil || calls[2].Action.Query != "btc" {
+		t.Fatalf("web_search deltas = %+v", calls[:3])
+	}
+	last := calls[len(calls)-1]
+	if *last.Index != 2 || last.Action == nil || last.Action.Type != "open_page" {
+		t.Fatalf("reconciled call = %+v", last)
+	}
+	if len(annotations) != 1 || annotations[0].URLCitation.URL != "https://example.com" {
+		t.Fatalf("annotations = %+v", annotations)
+	}
+}
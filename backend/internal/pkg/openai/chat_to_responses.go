@@ -0,0 +1,493 @@
+package openai
+
+import (
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/ShaohongDong/sub2api/internal/pkg/apicompat"
+)
+
+// minChatMaxOutputTokens Responses API 要求 max_output_tokens >= 16。
+const minChatMaxOutputTokens = 16
+
+// streamContinuationPrompt 续传时追加的用户指令，要求模型从中断处继续且不重复已输出内容。
+const streamContinuationPrompt = "Your previous reply was cut off by a network error. Continue exactly from where it stopped, without repeating any text already written and without acknowledging the interruption."
+
+// ChatCompletionsToResponses 将 Chat Completions 请求转换为 Responses API 请求。
+//
+//   - 开头连续的 system/developer 消息合并为 instructions，其余消息转为 input items；
+//   - assistant.tool_calls → function_call，tool 消息 → function_call_output，call_id 原样保留；
+//   - function 工具转为 Responses 扁平格式；内置 web_search / code_interpreter 工具（及 web_search_options）
+//     原样转为 Responses 内置工具并请求返回搜索来源与代码输出，assistant.server_tool_calls 回放为对应输出项；
+//     其余非 function 工具暂不支持并丢弃；
+//   - response_format → text.format，max_completion_tokens 优先于 max_tokens；
+//   - stop / top_k 随请求携带（Responses 上游不支持，发往 Anthropic 时转为 stop_sequences / top_k），
+//     logit_bias、非 0 的 presence_penalty / frequency_penalty 在两类上游均不支持，记入 DroppedParams。
+func ChatCompletionsToResponses(req *ChatCompletionRequest) (*apicompat.ResponsesRequest, error) {
+	if req.N != nil && *req.N > 1 {
+		return nil, fmt.Errorf("n > 1 is not supported")
+	}
+
+	instructions, input, err := convertChatMessagesToResponsesInput(req.Messages)
+	if err != nil {
+		return nil, err
+	}
+	inputJSON, err := json.Marshal(input)
+	if err != nil {
+		return nil, err
+	}
+
+	out := &apicompat.ResponsesRequest{
+		Model:             req.Model,
+		Instructions:      instructions,
+		Input:             inputJSON,
+		Temperature:       req.Temperature,
+		TopP:              req.TopP,
+		Stream:            req.Stream,
+		ParallelToolCalls: req.ParallelToolCalls,
+		ServiceTier:       req.ServiceTier,
+	}
+	storeFalse := false
+	out.Store = &storeFalse
+
+	if err := convertChatSamplingParams(req, out); err != nil {
+		return nil, err
+	}
+
+	maxTokens := req.MaxCompletionTokens
+	if maxTokens == nil {
+		maxTokens = req.MaxTokens
+	}
+	if maxTokens != nil && *maxTokens > 0 {
+		v := *maxTokens
+		if v < minChatMaxOutputTokens {
+			v = minChatMaxOutputTokens
+		}
+		out.MaxOutputTokens = &v
+	}
+
+	if effort := strings.TrimSpace(req.ReasoningEffort); effort != "" {
+		out.Reasoning = &apicompat.ResponsesReasoning{Effort: effort, Summary: "auto"}
+	}
+
+	out.Tools = convertChatToolsToResponses(req.Tools, req.WebSearchOptions)
+	out.Include = builtinToolIncludes(out.Tools)
+
+	if len(req.ToolChoice) > 0 {
+		tc, err := convertChatToolChoiceToResponses(req.ToolChoice)
+		if err != nil {
+			return nil, fmt.Errorf("convert tool_choice: %w", err)
+		}
+		out.ToolChoice = tc
+	}
+
+	if req.ResponseFormat != nil {
+		text, err := convertChatResponseFormatToResponses(req.ResponseFormat)
+		if err != nil {
+			return nil, fmt.Errorf("convert response_format: %w", err)
+		}
+		out.Text = text
+	}
+
+	return out, nil
+}
+
+// convertChatSamplingParams 处理 Responses API 没有对应字段的采样参数：stop / top_k 放入中间格式的
+// StopSequences / TopK，其余不支持的参数（取值为默认值时视为未设置）按固定顺序记入 DroppedParams。
+func convertChatSamplingParams(req *ChatCompletionRequest, out *apicompat.ResponsesRequest) error {
+	stop, err := parseChatStop(req.Stop)
+	if err != nil {
+		return fmt.Errorf("parse stop: %w", err)
+	}
+	out.StopSequences = stop
+	if req.TopK != nil && *req.TopK > 0 {
+		v := *req.TopK
+		out.TopK = &v
+	}
+	if bias := strings.TrimSpace(string(req.LogitBias)); bias != "" && bias != "null" && bias != "{}" {
+		out.DroppedParams = append(out.DroppedParams, "logit_bias")
+	}
+	if req.PresencePenalty != nil && *req.PresencePenalty != 0 {
+		out.DroppedParams = append(out.DroppedParams, "presence_penalty")
+	}
+	if req.FrequencyPenalty != nil && *req.FrequencyPenalty != 0 {
+		out.DroppedParams = append(out.DroppedParams, "frequency_penalty")
+	}
+	return nil
+}
+
+// parseChatStop 解析 stop（字符串或字符串数组），忽略空字符串
+func parseChatStop(raw json.RawMessage) ([]string, error) {
+	if len(raw) == 0 || string(raw) == "null" {
+		return nil, nil
+	}
+	var single string
+	if err := json.Unmarshal(raw, &single); err == nil {
+		if single == "" {
+			return nil, nil
+		}
+		return []string{single}, nil
+	}
+	var list []string
+	if err := json.Unmarshal(raw, &list); err != nil {
+		return nil, fmt.Errorf("stop must be a string or an array of strings")
+	}
+	stop := make([]string, 0, len(list))
+	for _, s := range list {
+		if s != "" {
+			stop = append(stop, s)
+		}
+	}
+	if len(stop) == 0 {
+		return nil, nil
+	}
+	return stop, nil
+}
+
+func convertChatMessagesToResponsesInput(messages []ChatMessage) (string, []apicompat.ResponsesInputItem, error) {
+	var instructionParts []string
+	var items []apicompat.ResponsesInputItem
+	leading := true
+
+	for _, m := range messages {
+		switch m.Role {
+		case "system", "developer":
+			text, err := extractChatText(m.Content)
+			if err != nil {
+				return "", nil, fmt.Errorf("parse %s message: %w", m.Role, err)
+			}
+			if leading {
+				if text != "" {
+					instructionParts = append(instructionParts, text)
+				}
+				continue
+			}
+			if text == "" {
+				continue
+			}
+			content, _ := json.Marshal(text)
+			items = append(items, apicompat.ResponsesInputItem{Role: "developer", Content: content})
+		case "user":
+			leading = false
+			parts, err := convertChatUserContent(m.Content)
+			if err != nil {
+				return "", nil, fmt.Errorf("parse user message: %w", err)
+			}
+			if len(parts) == 0 {
+				continue
+			}
+			partsJSON, err := json.Marshal(parts)
+			if err != nil {
+				return "", nil, err
+			}
+			items = append(items, apicompat.ResponsesInputItem{Role: "user", Content: partsJSON})
+		case "assistant":
+			leading = false
+			text, err := extractChatText(m.Content)
+			if err != nil {
+				return "", nil, fmt.Errorf("parse assistant message: %w", err)
+			}
+			// 内置工具调用先于正文产生，按原顺序回放
+			items = append(items, convertChatServerToolCallsToResponses(m.ServerToolCalls)...)
+			if text != "" {
+				partsJSON, err := json.Marshal([]apicompat.ResponsesContentPart{{Type: "output_text", Text: text}})
+				if err != nil {
+					return "", nil, err
+				}
+				items = append(items, apicompat.ResponsesInputItem{Role: "assistant", Content: partsJSON})
+			}
+			for _, tc := range m.ToolCalls {
+				args := tc.Function.Arguments
+				if strings.TrimSpace(args) == "" {
+					args = "{}"
+				}
+				items = append(items, apicompat.ResponsesInputItem{
+					Type:      "function_call",
+					CallID:    tc.ID,
+					Name:      tc.Function.Name,
+					Arguments: args,
+				})
+			}
+		case "tool":
+			leading = false
+			text, err := extractChatText(m.Content)
+			if err != nil {
+				return "", nil, fmt.Errorf("parse tool message: %w", err)
+			}
+			if text == "" {
+				text = "(empty)"
+			}
+			items = append(items, apicompat.ResponsesInputItem{
+				Type:   "function_call_output",
+				CallID: m.ToolCallID,
+				Output: text,
+			})
+		default:
+			return "", nil, fmt.Errorf("unsupported message role %q", m.Role)
+		}
+	}
+
+	return strings.Join(instructionParts, "\n\n"), items, nil
+}
+
+// extractChatText 解析 string 或 content parts 数组中的文本（多段以空行拼接）。
+func extractChatText(raw json.RawMessage) (string, error) {
+	if len(raw) == 0 || string(raw) == "null" {
+		return "", nil
+	}
+	var s string
+	if err := json.Unmarshal(raw, &s); err == nil {
+		return s, nil
+	}
+	var parts []ChatContentPart
+	if err := json.Unmarshal(raw, &parts); err != nil {
+		return "", err
+	}
+	var texts []string
+	for _, p := range parts {
+		if p.Type == "text" && p.Text != "" {
+			texts = append(texts, p.Text)
+		}
+	}
+	return strings.Join(texts, "\n\n"), nil
+}
+
+func convertChatUserContent(raw json.RawMessage) ([]apicompat.ResponsesContentPart, error) {
+	if len(raw) == 0 || string(raw) == "null" {
+		return nil, nil
+	}
+	var s string
+	if err := json.Unmarshal(raw, &s); err == nil {
+		if s == "" {
+			return nil, nil
+		}
+		return []apicompat.ResponsesContentPart{{Type: "input_text", Text: s}}, nil
+	}
+	var parts []ChatContentPart
+	if err := json.Unmarshal(raw, &parts); err != nil {
+		return nil, err
+	}
+	out := make([]apicompat.ResponsesContentPart, 0, len(parts))
+	for _, p := range parts {
+		switch p.Type {
+		case "text":
+			if p.Text != "" {
+				out = append(out, apicompat.ResponsesContentPart{Type: "input_text", Text: p.Text})
+			}
+		case "image_url":
+			if p.ImageURL != nil && p.ImageURL.URL != "" {
+				out = append(out, apicompat.ResponsesContentPart{Type: "input_image", ImageURL: p.ImageURL.URL, Detail: p.ImageURL.Detail})
+			}
+		case "file":
+			if p.File != nil && (p.File.FileID != "" || p.File.FileData != "") {
+				out = append(out, apicompat.ResponsesContentPart{
+					Type:     "input_file",
+					FileID:   p.File.FileID,
+					FileData: p.File.FileData,
+					Filename: p.File.Filename,
+				})
+			}
+		}
+	}
+	return out, nil
+}
+
+func convertChatToolsToResponses(tools []ChatTool, webSearch *ChatWebSearchOptions) []apicompat.ResponsesTool {
+	var out []apicompat.ResponsesTool
+	hasWebSearch := false
+	for _, t := range tools {
+		switch t.Type {
+		case "web_search", "web_search_preview":
+			hasWebSearch = true
+			out = append(out, apicompat.ResponsesTool{
+				Type:              "web_search",
+				SearchContextSize: t.SearchContextSize,
+				UserLocation:      t.UserLocation,
+				Filters:           t.Filters,
+			})
+			continue
+		case "code_interpreter":
+			container := t.Container
+			if len(container) == 0 || string(container) == "null" {
+				// Responses API 要求 container；未指定时自动创建
+				container = json.RawMessage(`{"type":"auto"}`)
+			}
+			out = append(out, apicompat.ResponsesTool{Type: "code_interpreter", Container: container})
+			continue
+		}
+		if t.Type != "function" || t.Function == nil || strings.TrimSpace(t.Function.Name) == "" {
+			continue
+		}
+		params := t.Function.Parameters
+		if len(params) == 0 || string(params) == "null" {
+			params = json.RawMessage(`{"type":"object","properties":{}}`)
+		}
+		out = append(out, apicompat.ResponsesTool{
+			Type:        "function",
+			Name:        t.Function.Name,
+			Description: t.Function.Description,
+			Parameters:  params,
+			Strict:      t.Function.Strict,
+		})
+	}
+	// web_search_options（搜索模型的开关）等价于一个 web_search 工具
+	if webSearch != nil && !hasWebSearch {
+		out = append(out, apicompat.ResponsesTool{
+			Type:              "web_search",
+			SearchContextSize: webSearch.SearchContextSize,
+			UserLocation:      flattenChatUserLocation(webSearch.UserLocation),
+		})
+	}
+	return out
+}
+
+// flattenChatUserLocation 将 Chat 的 {"type":"approximate","approximate":{...}} 展开为 Responses 的扁平格式
+func flattenChatUserLocation(raw json.RawMessage) json.RawMessage {
+	var loc struct {
+		Type        string                     `json:"type"`
+		Approximate map[string]json.RawMessage `json:"approximate"`
+	}
+	if len(raw) == 0 || json.Unmarshal(raw, &loc) != nil || loc.Approximate == nil {
+		return raw
+	}
+	flat := make(map[string]json.RawMessage, len(loc.Approximate)+1)
+	for k, v := range loc.Approximate {
+		flat[k] = v
+	}
+	flat["type"], _ = json.Marshal("approximate")
+	out, err := json.Marshal(flat)
+	if err != nil {
+		return raw
+	}
+	return out
+}
+
+// builtinToolIncludes 为内置工具请求返回搜索来源与代码执行输出，使客户端能看到工具结果
+func builtinToolIncludes(tools []apicompat.ResponsesTool) []string {
+	var include []string
+	for _, t := range tools {
+		var v string
+		switch t.Type {
+		case "web_search":
+			v = "web_search_call.action.sources"
+		case "code_interpreter":
+			v = "code_interpreter_call.outputs"
+		}
+		if v != "" && !slices.Contains(include, v) {
+			include = append(include, v)
+		}
+	}
+	return include
+}
+
+// convertChatServerToolCallsToResponses 将 assistant.server_tool_calls 回放为 web_search_call / code_interpreter_call 输入项；
+// 缺少 id 的调用无法被上游识别，直接跳过。
+func convertChatServerToolCallsToResponses(calls []ChatServerToolCall) []apicompat.ResponsesInputItem {
+	var items []apicompat.ResponsesInputItem
+	for _, call := range calls {
+		if strings.TrimSpace(call.ID) == "" {
+			continue
+		}
+		status := call.Status
+		if status == "" || status == "in_progress" || status == "searching" || status == "interpreting" {
+			status = "completed"
+		}
+		switch call.Type {
+		case "web_search":
+			action := call.Action
+			if action == nil {
+				action = &apicompat.WebSearchAction{Type: "search"}
+			}
+			items = append(items, apicompat.ResponsesInputItem{Type: "web_search_call", ID: call.ID, Status: status, Action: action})
+		case "code_interpreter":
+			items = append(items, apicompat.ResponsesInputItem{
+				Type:        "code_interpreter_call",
+				ID:          call.ID,
+				Status:      status,
+				Code:        call.Code,
+				ContainerID: call.ContainerID,
+				Outputs:     call.Outputs,
+			})
+		}
+	}
+	return items
+}
+
+// convertChatToolChoiceToResponses 转换 tool_choice：
+// 字符串（auto/none/required）原样保留；{"type":"function","function":{"name":X}} → {"type":"function","name":X}。
+func convertChatToolChoiceToResponses(raw json.RawMessage) (json.RawMessage, error) {
+	var s string
+	if err := json.Unmarshal(raw, &s); err == nil {
+		return raw, nil
+	}
+	var obj map[string]json.RawMessage
+	if err := json.Unmarshal(raw, &obj); err != nil {
+		return nil, err
+	}
+	if tc, ok := apicompat.ParseResponsesToolChoice(raw); ok && tc.Mode == apicompat.ToolChoiceFunction {
+		return tc.ResponsesJSON()
+	}
+	return raw, nil
+}
+
+// convertChatResponseFormatToResponses 转换 response_format → Responses text.format。
+func convertChatResponseFormatToResponses(rf *ChatResponseFormat) (json.RawMessage, error) {
+	format := map[string]any{"type": rf.Type}
+	switch rf.Type {
+	case "text", "json_object":
+	case "json_schema":
+		if rf.JSONSchema == nil {
+			return nil, fmt.Errorf("json_schema is required for response_format type json_schema")
+		}
+		format["name"] = rf.JSONSchema.Name
+		if rf.JSONSchema.Description != "" {
+			format["description"] = rf.JSONSchema.Description
+		}
+		if len(rf.JSONSchema.Schema) > 0 {
+			format["schema"] = rf.JSONSchema.Schema
+		}
+		if rf.JSONSchema.Strict != nil {
+			format["strict"] = *rf.JSONSchema.Strict
+		}
+	default:
+		return nil, fmt.Errorf("unsupported response_format type %q", rf.Type)
+	}
+	return json.Marshal(map[string]any{"format": format})
+}
+
+// AppendResponsesContinuation 为上游中途断开后的续传请求追加上下文：
+// 已输出的正文作为 assistant 消息，随后一条要求从断点继续的 user 消息。partial 为空时原样返回。
+func AppendResponsesContinuation(req *apicompat.ResponsesRequest, partial string) error {
+	if partial == "" {
+		return nil
+	}
+	var items []apicompat.ResponsesInputItem
+	if err := json.Unmarshal(req.Input, &items); err != nil {
+		var text string
+		if err := json.Unmarshal(req.Input, &text); err != nil {
+			return fmt.Errorf("parse responses input: %w", err)
+		}
+		content, _ := json.Marshal(text)
+		items = []apicompat.ResponsesInputItem{{Role: "user", Content: content}}
+	}
+	assistant, err := json.Marshal([]apicompat.ResponsesContentPart{{Type: "output_text", Text: partial}})
+	if err != nil {
+		return err
+	}
+	prompt, err := json.Marshal([]apicompat.ResponsesContentPart{{Type: "input_text", Text: streamContinuationPrompt}})
+	if err != nil {
+		return err
+	}
+	items = append(items,
+		apicompat.ResponsesInputItem{Role: "assistant", Content: assistant},
+		apicompat.ResponsesInputItem{Role: "user", Content: prompt},
+	)
+	input, err := json.Marshal(items)
+	if err != nil {
+		return err
+	}
+	req.Input = input
+	return nil
+}
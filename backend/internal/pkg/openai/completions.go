@@ -0,0 +1,51 @@
+package openai
+
+import "encoding/json"
+
+// 旧版 Completions API 类型定义（/v1/completions）。
+// 仅覆盖包装为 Responses 调用所需的字段；best_of / presence_penalty 等字段忽略。
+
+// CompletionRequest is the request body for POST /v1/completions.
+type CompletionRequest struct {
+	Model         string             `json:"model"`
+	Prompt        json.RawMessage    `json:"prompt"` // string or [string]
+	Suffix        string             `json:"suffix,omitempty"`
+	MaxTokens     *int               `json:"max_tokens,omitempty"`
+	Temperature   *float64           `json:"temperature,omitempty"`
+	TopP          *float64           `json:"top_p,omitempty"`
+	N             *int               `json:"n,omitempty"`
+	Stream        bool               `json:"stream,omitempty"`
+	StreamOptions *ChatStreamOptions `json:"stream_options,omitempty"`
+	Logprobs      *int               `json:"logprobs,omitempty"` // top alternatives per token, 0-5
+	Echo          bool               `json:"echo,omitempty"`
+	User          string             `json:"user,omitempty"`
+}
+
+// Completion is the response object for /v1/completions (also used for
+// streaming chunks, with object "text_completion" in both cases).
+type Completion struct {
+	ID      string             `json:"id"`
+	Object  string             `json:"object"` // "text_completion"
+	Created int64              `json:"created"`
+	Model   string             `json:"model"`
+	Choices []CompletionChoice `json:"choices"`
+	Usage   *ChatUsage         `json:"usage,omitempty"`
+}
+
+// CompletionChoice is one completion choice. Logprobs and FinishReason are
+// null when absent (FinishReason stays null until the final stream chunk).
+type CompletionChoice struct {
+	Text         string              `json:"text"`
+	Index        int                 `json:"index"`
+	Logprobs     *CompletionLogprobs `json:"logprobs"`
+	FinishReason *string             `json:"finish_reason"`
+}
+
+// CompletionLogprobs is the legacy column-oriented logprobs object.
+// TextOffset is the character offset of each token in the choice text.
+type CompletionLogprobs struct {
+	Tokens        []string             `json:"tokens"`
+	TokenLogprobs []float64            `json:"token_logprobs"`
+	TopLogprobs   []map[string]float64 `json:"top_logprobs"`
+	TextOffset    []int                `json:"text_offset"`
+}
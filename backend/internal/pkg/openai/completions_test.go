@@ -0,0 +1,109 @@
+package openai
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/ShaohongDong/sub2api/internal/pkg/apicompat"
+)
+
+func TestCompletionsToResponses(t *testing.T) {
+	var req CompletionRequest
+	if err := json.Unmarshal([]byte(`{"model":"gpt-5.1","prompt":["Once upon"],"max_tokens":4,"logprobs":2,"echo":true}`), &req); err != nil {
+		t.Fatalf("unmarshal: %v", err)
+	}
+	out, err := CompletionsToResponses(&req)
+	if err != nil {
+		t.Fatalf("CompletionsToResponses: %v", err)
+	}
+	if out.MaxOutputTokens == nil || *out.MaxOutputTokens != minChatMaxOutputTokens {
+		t.Fatalf("max_output_tokens = %v", out.MaxOutputTokens)
+	}
+	if out.TopLogprobs == nil || *out.TopLogprobs != 2 || len(out.Include) != 1 || out.Include[0] != "message.output_text.logprobs" {
+		t.Fatalf("logprobs mapping = %v / %v", out.TopLogprobs, out.Include)
+	}
+	if !strings.Contains(string(out.Input), `"text":"Once upon"`) {
+		t.Fatalf("input = %s", out.Input)
+	}
+
+	for _, prompt := range []string{`null`, `["a","b"]`, `[1,2,3]`, `{}`} {
+		if _, err := CompletionsToResponses(&CompletionRequest{Model: "m", Prompt: json.RawMessage(prompt)}); err == nil {
+			t.Fatalf("expected error for prompt %s", prompt)
+		}
+	}
+	tooMany := 6
+	if _, err := CompletionsToResponses(&CompletionRequest{Model: "m", Prompt: json.RawMessage(`"x"`), Logprobs: &tooMany}); err == nil {
+		t.Fatal("expected error for logprobs > 5")
+	}
+}
+
+func TestResponsesToCompletion(t *testing.T) {
+	resp := &apicompat.ResponsesResponse{
+		ID:     "resp_xyz",
+		Status: "completed",
+		Output: []apicompat.ResponsesOutput{{Type: "message", Content: []apicompat.ResponsesContentPart{{
+			Type: "output_text",
+			Text: " a time",
+			Logprobs: []apicompat.ResponsesLogprob{
+				{Token: " a", Logprob: -0.1, TopLogprobs: []apicompat.ResponsesTopLogprob{{Token: " a", Logprob: -0.1}}},
+				{Token: " time", Logprob: -0.2},
+			},
+		}}}},
+	}
+
+	out := ResponsesToCompletion(resp, "gpt-5.1", CompletionOptions{Echo: true, Prompt: "Once upon", Logprobs: true})
+	if out.ID != "cmpl-xyz" || out.Object != "text_completion" {
+		t.Fatalf("id/object = %s/%s", out.ID, out.Object)
+	}
+	choice := out.Choices[0]
+	if choice.Text != "Once upon a time" || *choice.FinishReason != "stop" {
+		t.Fatalf("choice = %+v", choice)
+	}
+	lp := choice.Logprobs
+	if lp == nil || len(lp.Tokens) != 2 || lp.TextOffset[0] != 9 || lp.TextOffset[1] != 11 || lp.TopLogprobs[0][" a"] != -0.1 {
+		t.Fatalf("logprobs = %+v", lp)
+	}
+
+	plain := ResponsesToCompletion(resp, "gpt-5.1", CompletionOptions{})
+	data, _ := json.Marshal(plain.Choices[0])
+	if plain.Choices[0].Text != " a time" || !strings.Contains(string(data), `"logprobs":null`) {
+		t.Fatalf("plain choice = %s", data)
+	}
+}
+
+func TestResponsesEventToCompletionChunks(t *testing.T) {
+	state := NewResponsesEventToCompletionState()
+	state.Model = "gpt-5.1"
+	state.IncludeUsage = true
+	state.Options = CompletionOptions{Echo: true, Prompt: "Hi", Logprobs: true}
+
+	events := []apicompat.ResponsesStreamEvent{
+		{Type: "response.created", Response: &apicompat.ResponsesResponse{ID: "resp_1"}},
+		{Type: "response.output_text.delta", Delta: " there", Logprobs: []apicompat.ResponsesLogprob{{Token: " there", Logprob: -0.5}}},
+		{Type: "response.incomplete", Response: &apicompat.ResponsesResponse{IncompleteDetails: &apicompat.ResponsesIncompleteDetails{Reason: "max_output_tokens"}, Usage: &apicompat.ResponsesUsage{InputTokens: 1, OutputTokens: 1}}},
+	}
+	var chunks []Completion
+	for i := range events {
+		chunks = append(chunks, ResponsesEventToCompletionChunks(&events[i], state)...)
+	}
+
+	if len(chunks) != 4 {
+		t.Fatalf("len(chunks) = %d, want 4", len(chunks))
+	}
+	if chunks[0].ID != "cmpl-1" || chunks[0].Choices[0].Text != "Hi" {
+		t.Fatalf("echo chunk = %+v", chunks[0])
+	}
+	if lp := chunks[1].Choices[0].Logprobs; lp == nil || lp.TextOffset[0] != 2 {
+		t.Fatalf("delta logprobs = %+v", lp)
+	}
+	if reason := chunks[2].Choices[0].FinishReason; reason == nil || *reason != "length" {
+		t.Fatalf("finish_reason = %v", reason)
+	}
+	if len(chunks[3].Choices) != 0 || chunks[3].Usage.TotalTokens != 2 {
+		t.Fatalf("usage chunk = %+v", chunks[3])
+	}
+	if FinalizeResponsesCompletionStream(state) != nil {
+		t.Fatal("finalize after terminal event should be nil")
+	}
+}
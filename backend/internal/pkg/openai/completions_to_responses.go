@@ -0,0 +1,88 @@
+package openai
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/ShaohongDong/sub2api/internal/pkg/apicompat"
+)
+
+// maxCompletionLogprobs 旧版 API 允许的 logprobs 上限。
+const maxCompletionLogprobs = 5
+
+// CompletionsToResponses 将旧版 Completions 请求包装为 Responses API 请求：
+// prompt 作为单条 user 消息；logprobs=N 映射为 top_logprobs=N 并请求
+// message.output_text.logprobs。echo 由响应转换阶段处理。
+func CompletionsToResponses(req *CompletionRequest) (*apicompat.ResponsesRequest, error) {
+	if req.N != nil && *req.N > 1 {
+		return nil, fmt.Errorf("n > 1 is not supported")
+	}
+	if req.Suffix != "" {
+		return nil, fmt.Errorf("suffix is not supported")
+	}
+	prompt, err := ParseCompletionPrompt(req.Prompt)
+	if err != nil {
+		return nil, err
+	}
+
+	content, err := json.Marshal([]apicompat.ResponsesContentPart{{Type: "input_text", Text: prompt}})
+	if err != nil {
+		return nil, err
+	}
+	inputJSON, err := json.Marshal([]apicompat.ResponsesInputItem{{Role: "user", Content: content}})
+	if err != nil {
+		return nil, err
+	}
+
+	out := &apicompat.ResponsesRequest{
+		Model:       req.Model,
+		Input:       inputJSON,
+		Temperature: req.Temperature,
+		TopP:        req.TopP,
+		Stream:      req.Stream,
+	}
+	storeFalse := false
+	out.Store = &storeFalse
+
+	if req.MaxTokens != nil && *req.MaxTokens > 0 {
+		v := *req.MaxTokens
+		if v < minChatMaxOutputTokens {
+			v = minChatMaxOutputTokens
+		}
+		out.MaxOutputTokens = &v
+	}
+
+	if req.Logprobs != nil {
+		n := *req.Logprobs
+		if n < 0 || n > maxCompletionLogprobs {
+			return nil, fmt.Errorf("logprobs must be between 0 and %d", maxCompletionLogprobs)
+		}
+		out.TopLogprobs = &n
+		out.Include = []string{"message.output_text.logprobs"}
+	}
+
+	return out, nil
+}
+
+// ParseCompletionPrompt 解析 prompt 字段：支持字符串或单元素字符串数组；
+// 多 prompt 与 token 数组形式暂不支持。
+func ParseCompletionPrompt(raw json.RawMessage) (string, error) {
+	if len(raw) == 0 || string(raw) == "null" {
+		return "", fmt.Errorf("prompt is required")
+	}
+	var s string
+	if err := json.Unmarshal(raw, &s); err == nil {
+		return s, nil
+	}
+	var list []json.RawMessage
+	if err := json.Unmarshal(raw, &list); err != nil {
+		return "", fmt.Errorf("prompt must be a string or an array of strings")
+	}
+	if len(list) != 1 {
+		return "", fmt.Errorf("multiple prompts are not supported")
+	}
+	if err := json.Unmarshal(list[0], &s); err != nil {
+		return "", fmt.Errorf("token array prompts are not supported")
+	}
+	return s, nil
+}
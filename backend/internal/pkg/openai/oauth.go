@@ -8,6 +8,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"net/url"
+	"strconv"
 	"strings"
 	"sync"
 	"time"
@@ -34,6 +35,18 @@ const (
 
 	// Session TTL
 	SessionTTL = 30 * time.Minute
+
+	// Device code login endpoints (Codex CLI `codex login --device-auth`)
+	DeviceAuthUserCodeURL = "https://auth.openai.com/api/accounts/deviceauth/usercode"
+	DeviceAuthTokenURL    = "https://auth.openai.com/api/accounts/deviceauth/token"
+	// DeviceVerificationURL is where the user enters the user code
+	DeviceVerificationURL = "https://auth.openai.com/codex/device"
+	// DeviceAuthRedirectURI is the redirect URI bound to device-flow authorization codes
+	DeviceAuthRedirectURI = "https://auth.openai.com/deviceauth/callback"
+	// DeviceCodeTTL is how long a user code stays valid
+	DeviceCodeTTL = 15 * time.Minute
+	// DefaultDevicePollInterval is used when the server does not return an interval
+	DefaultDevicePollInterval = 5 * time.Second
 )
 
 const (
@@ -51,6 +64,9 @@ type OAuthSession struct {
 	ProxyURL     string    `json:"proxy_url,omitempty"`
 	RedirectURI  string    `json:"redirect_uri"`
 	CreatedAt    time.Time `json:"created_at"`
+	// Device code flow: set when the session was started with a user code
+	DeviceAuthID string `json:"device_auth_id,omitempty"`
+	UserCode     string `json:"user_code,omitempty"`
 }
 
 // SessionStore manages OAuth sessions in memory
@@ -241,6 +257,32 @@ type TokenResponse struct {
 	Scope        string `json:"scope,omitempty"`
 }
 
+// DeviceCodeResponse is returned when a device code login is started
+type DeviceCodeResponse struct {
+	DeviceAuthID string `json:"device_auth_id"`
+	UserCode     string `json:"user_code"`
+	// Interval is the suggested poll interval in seconds (sent as a string or a number)
+	Interval json.RawMessage `json:"interval,omitempty"`
+}
+
+// PollInterval returns the suggested poll interval, falling back to DefaultDevicePollInterval
+func (r *DeviceCodeResponse) PollInterval() time.Duration {
+	raw := strings.Trim(strings.TrimSpace(string(r.Interval)), `"`)
+	if seconds, err := strconv.Atoi(raw); err == nil && seconds > 0 {
+		return time.Duration(seconds) * time.Second
+	}
+	return DefaultDevicePollInterval
+}
+
+// DeviceTokenResponse is returned by the device token endpoint once the user
+// has approved the login; the authorization code is exchanged at TokenURL with
+// DeviceAuthRedirectURI and the returned code verifier.
+type DeviceTokenResponse struct {
+	AuthorizationCode string `json:"authorization_code"`
+	CodeChallenge     string `json:"code_challenge"`
+	CodeVerifier      string `json:"code_verifier"`
+}
+
 // RefreshTokenRequest represents the refresh token request
 type RefreshTokenRequest struct {
 	GrantType    string `json:"grant_type"`
@@ -1,14 +1,61 @@
 package openai
 
-import "strings"
+import (
+	"strings"
+	"sync/atomic"
+)
 
 // CodexCLIUserAgentPrefixes matches Codex CLI User-Agent patterns
 // Examples: "codex_vscode/1.0.0", "codex_cli_rs/0.1.2"
+// 这是内置默认列表；运行时生效的列表见 ConfigureCodexCLIUserAgentPrefixes。
 var CodexCLIUserAgentPrefixes = []string{
 	"codex_vscode/",
 	"codex_cli_rs/",
 }
 
+// runtimeCodexCLIUserAgentPrefixes 运行时生效的 Codex CLI UA 前缀列表（nil 表示使用内置默认列表）。
+// 使用 atomic.Pointer 保证配置热更新时热路径无锁读取。
+var runtimeCodexCLIUserAgentPrefixes atomic.Pointer[[]string]
+
+// ConfigureCodexCLIUserAgentPrefixes 设置运行时 Codex CLI UA 前缀列表。
+// override=false 时在内置列表基础上追加 prefixes；override=true 时以 prefixes 完全替换内置列表
+// （prefixes 为空时回退内置列表，避免误配置导致所有 Codex 请求失配）。
+// 可在配置热更新时重复调用，并发安全。
+func ConfigureCodexCLIUserAgentPrefixes(prefixes []string, override bool) {
+	merged := make([]string, 0, len(CodexCLIUserAgentPrefixes)+len(prefixes))
+	seen := make(map[string]struct{}, cap(merged))
+	appendUnique := func(values []string) {
+		for _, v := range values {
+			normalized := normalizeCodexClientHeader(v)
+			if normalized == "" {
+				continue
+			}
+			if _, ok := seen[normalized]; ok {
+				continue
+			}
+			seen[normalized] = struct{}{}
+			merged = append(merged, normalized)
+		}
+	}
+	if !override {
+		appendUnique(CodexCLIUserAgentPrefixes)
+	}
+	appendUnique(prefixes)
+	if len(merged) == 0 {
+		runtimeCodexCLIUserAgentPrefixes.Store(nil)
+		return
+	}
+	runtimeCodexCLIUserAgentPrefixes.Store(&merged)
+}
+
+// ActiveCodexCLIUserAgentPrefixes 返回当前生效的 Codex CLI UA 前缀列表（只读，调用方不得修改）。
+func ActiveCodexCLIUserAgentPrefixes() []string {
+	if p := runtimeCodexCLIUserAgentPrefixes.Load(); p != nil {
+		return *p
+	}
+	return CodexCLIUserAgentPrefixes
+}
+
 // CodexOfficialClientUserAgentPrefixes matches Codex 官方客户端家族 User-Agent 前缀。
 // 该列表仅用于 OpenAI OAuth `codex_cli_only` 访问限制判定。
 var CodexOfficialClientUserAgentPrefixes = []string{
@@ -36,7 +83,7 @@ func IsCodexCLIRequest(userAgent string) bool {
 	if ua == "" {
 		return false
 	}
-	return matchCodexClientHeaderPrefixes(ua, CodexCLIUserAgentPrefixes)
+	return matchCodexClientHeaderPrefixes(ua, ActiveCodexCLIUserAgentPrefixes())
 }
 
 // IsCodexOfficialClientRequest checks if the User-Agent indicates a Codex 官方客户端请求。
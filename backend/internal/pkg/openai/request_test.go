@@ -108,3 +108,31 @@ func TestIsCodexOfficialClientByHeaders(t *testing.T) {
 		})
 	}
 }
+
+func TestConfigureCodexCLIUserAgentPrefixes(t *testing.T) {
+	t.Cleanup(func() { ConfigureCodexCLIUserAgentPrefixes(nil, false) })
+
+	ConfigureCodexCLIUserAgentPrefixes([]string{" Acme_Codex_Fork/ ", "codex_cli_rs/"}, false)
+	if !IsCodexCLIRequest("acme_codex_fork/1.0.0") {
+		t.Fatalf("extended prefix should match")
+	}
+	if !IsCodexCLIRequest("codex_vscode/1.2.3") {
+		t.Fatalf("built-in prefix should still match when extending")
+	}
+	if got := len(ActiveCodexCLIUserAgentPrefixes()); got != 3 {
+		t.Fatalf("active prefixes = %d, want 3 (deduplicated)", got)
+	}
+
+	ConfigureCodexCLIUserAgentPrefixes([]string{"acme_codex_fork/"}, true)
+	if IsCodexCLIRequest("codex_vscode/1.2.3") {
+		t.Fatalf("built-in prefix should not match after override")
+	}
+	if !IsCodexCLIRequest("acme_codex_fork/1.0.0") {
+		t.Fatalf("override prefix should match")
+	}
+
+	ConfigureCodexCLIUserAgentPrefixes(nil, true)
+	if !IsCodexCLIRequest("codex_cli_rs/0.1.0") {
+		t.Fatalf("empty override should fall back to built-in prefixes")
+	}
+}
@@ -0,0 +1,622 @@
+package openai
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/ShaohongDong/sub2api/internal/pkg/apicompat"
+)
+
+// ResponsesToChatCompletion 将 Responses API 响应转换为 chat.completion 对象。
+// message 文本拼接为 content，reasoning 摘要写入 reasoning_content（includeReasoning 为 false 时丢弃），
+// function_call 转为 tool_calls（call_id 原样作为 tool call id），
+// web_search_call / code_interpreter_call 转为 server_tool_calls，url_citation 标注转为 annotations。
+func ResponsesToChatCompletion(resp *apicompat.ResponsesResponse, model string, includeReasoning bool) *ChatCompletion {
+	var text strings.Builder
+	var reasoning strings.Builder
+	var toolCalls []ChatToolCall
+	var serverToolCalls []ChatServerToolCall
+	var annotations []ChatAnnotation
+
+	for _, item := range resp.Output {
+		switch item.Type {
+		case "message":
+			for _, part := range item.Content {
+				if part.Type == "output_text" {
+					// 多段文本拼接后，标注位置按已有正文的字符数偏移
+					offset := utf8.RuneCountInString(text.String())
+					for _, a := range part.Annotations {
+						if ca, ok := responsesAnnotationToChat(a, offset); ok {
+							annotations = append(annotations, ca)
+						}
+					}
+					text.WriteString(part.Text)
+				}
+			}
+		case "web_search_call", "code_interpreter_call":
+			serverToolCalls = append(serverToolCalls, responsesItemToServerToolCall(&item))
+		case "reasoning":
+			if !includeReasoning {
+				continue
+			}
+			for _, s := range item.Summary {
+				if s.Type == "summary_text" {
+					reasoning.WriteString(s.Text)
+				}
+			}
+		case "function_call":
+			toolCalls = append(toolCalls, ChatToolCall{
+				ID:   item.CallID,
+				Type: "function",
+				Function: ChatFunctionCall{
+					Name:      item.Name,
+					Arguments: item.Arguments,
+				},
+			})
+		}
+	}
+
+	msg := ChatResponseMessage{
+		Role:             "assistant",
+		ReasoningContent: reasoning.String(),
+		ToolCalls:        toolCalls,
+		ServerToolCalls:  serverToolCalls,
+		Annotations:      annotations,
+	}
+	if text.Len() > 0 || len(toolCalls) == 0 {
+		content := text.String()
+		msg.Content = &content
+	}
+
+	return &ChatCompletion{
+		ID:      chatCompletionID(resp.ID),
+		Object:  "chat.completion",
+		Created: time.Now().Unix(),
+		Model:   model,
+		Choices: []ChatChoice{{
+			Index:        0,
+			Message:      msg,
+			FinishReason: responsesToChatFinishReason(resp.Status, resp.IncompleteDetails, len(toolCalls) > 0),
+		}},
+		Usage: responsesUsageToChat(resp.Usage),
+	}
+}
+
+// responsesItemToServerToolCall 将内置工具输出项转换为 server_tool_calls 条目
+func responsesItemToServerToolCall(item *apicompat.ResponsesOutput) ChatServerToolCall {
+	call := ChatServerToolCall{
+		ID:     item.ID,
+		Type:   strings.TrimSuffix(item.Type, "_call"),
+		Status: item.Status,
+	}
+	if call.Status == "" {
+		call.Status = "completed"
+	}
+	if item.Type == "web_search_call" {
+		call.Action = item.Action
+	} else {
+		call.Code = item.Code
+		call.ContainerID = item.ContainerID
+		call.Outputs = item.Outputs
+	}
+	return call
+}
+
+// responsesAnnotationToChat 转换 url_citation 标注；Chat Completions 没有文件引用标注，其余类型丢弃
+func responsesAnnotationToChat(a apicompat.ResponsesAnnotation, offset int) (ChatAnnotation, bool) {
+	if a.Type != "url_citation" || a.URL == "" {
+		return ChatAnnotation{}, false
+	}
+	return ChatAnnotation{Type: "url_citation", URLCitation: &ChatURLCitation{
+		URL:        a.URL,
+		Title:      a.Title,
+		StartIndex: a.StartIndex + offset,
+		EndIndex:   a.EndIndex + offset,
+	}}, true
+}
+
+// responsesToChatFinishReason 映射 Responses 状态到 finish_reason。
+func responsesToChatFinishReason(status string, details *apicompat.ResponsesIncompleteDetails, hasToolCalls bool) string {
+	if status == "incomplete" && details != nil {
+		switch details.Reason {
+		case "max_output_tokens":
+			return "length"
+		case "content_filter":
+			return "content_filter"
+		}
+	}
+	if hasToolCalls {
+		return "tool_calls"
+	}
+	return "stop"
+}
+
+func responsesUsageToChat(usage *apicompat.ResponsesUsage) *ChatUsage {
+	if usage == nil {
+		return nil
+	}
+	out := &ChatUsage{
+		PromptTokens:     usage.InputTokens,
+		CompletionTokens: usage.OutputTokens,
+		TotalTokens:      usage.InputTokens + usage.OutputTokens,
+	}
+	if usage.InputTokensDetails != nil {
+		out.PromptTokensDetails = &ChatPromptTokensDetails{CachedTokens: usage.InputTokensDetails.CachedTokens}
+	}
+	if usage.OutputTokensDetails != nil {
+		out.CompletionTokensDetails = &ChatCompletionTokensDetails{ReasoningTokens: usage.OutputTokensDetails.ReasoningTokens}
+	}
+	return out
+}
+
+// chatCompletionID 由 Responses ID 派生 chatcmpl- 前缀的 ID。
+func chatCompletionID(responseID string) string {
+	if responseID == "" {
+		return fmt.Sprintf("chatcmpl-%d", time.Now().UnixNano())
+	}
+	return "chatcmpl-" + strings.TrimPrefix(responseID, "resp_")
+}
+
+// ResponsesEventToChatState 记录 Responses SSE 事件 → chat.completion.chunk 的转换状态。
+type ResponsesEventToChatState struct {
+	ID           string
+	Model        string
+	Created      int64
+	IncludeUsage bool
+	// IncludeReasoning 是否以 reasoning_content 输出推理增量（默认 true）
+	IncludeReasoning bool
+
+	RoleSent    bool
+	Finished    bool
+	HasToolCall bool
+
+	// NextToolIdx 下一个工具调用的 tool_calls[].index（按调用开始的先后顺序分配）
+	NextToolIdx int
+	// NextServerToolIdx 下一个内置工具调用的 server_tool_calls[].index
+	NextServerToolIdx int
+
+	// toolCalls 按 output_index 跟踪工具调用；itemOutputIndex 兼容只携带 item_id 的参数事件
+	toolCalls       map[int]*chatStreamToolCall
+	toolCallsByID   map[string]*chatStreamToolCall
+	itemOutputIndex map[string]int
+	// serverToolCalls 按 output_index 跟踪内置工具调用（web_search / code_interpreter），serverToolCallsByID 供终止事件对账
+	serverToolCalls     map[int]*chatStreamServerToolCall
+	serverToolCallsByID map[string]*chatStreamServerToolCall
+	// textSeen / reasoningSeen 记录已输出过增量的文本与推理片段，*.done 事件仅在无增量时补发全文
+	textSeen      map[int]bool
+	reasoningSeen map[[2]int]bool
+	reasoningSent bool
+	// usage 最近一次上报的用量，终止事件未携带 usage 时用于 include_usage 分片
+	usage *apicompat.ResponsesUsage
+	// emittedText 已发送给客户端的正文，上游中途断开续传时作为上下文
+	emittedText strings.Builder
+}
+
+// chatStreamToolCall 单个工具调用的流式状态。
+// 参数增量早于 output_item.added 到达时先缓存，待 call_id/name 已知后随首个分片一并发出。
+type chatStreamToolCall struct {
+	index       int
+	started     bool
+	argsSent    bool
+	pendingArgs strings.Builder
+}
+
+// chatStreamServerToolCall 单个内置工具调用的流式状态。
+type chatStreamServerToolCall struct {
+	index    int
+	started  bool
+	codeSent bool
+	done     bool
+}
+
+// NewResponsesEventToChatState returns an initialised stream state.
+func NewResponsesEventToChatState() *ResponsesEventToChatState {
+	return &ResponsesEventToChatState{
+		Created:         time.Now().Unix(),
+		toolCalls:       make(map[int]*chatStreamToolCall),
+		toolCallsByID:   make(map[string]*chatStreamToolCall),
+		itemOutputIndex: make(map[string]int),
+		textSeen:        make(map[int]bool),
+		reasoningSeen:   make(map[[2]int]bool),
+
+		serverToolCalls:     make(map[int]*chatStreamServerToolCall),
+		serverToolCallsByID: make(map[string]*chatStreamServerToolCall),
+
+		IncludeReasoning: true,
+	}
+}
+
+// ResponsesEventToChatChunks 将单个 Responses SSE 事件转换为零或多个 chat.completion.chunk。
+//
+// 工具调用的 tool_calls[].index 按调用开始顺序分配，同一调用的参数增量始终带同一 index；
+// 只发送 *.done / output_item.done（无增量）的上游由完整文本补发，
+// response.completed 时再按最终 output 补齐流中缺失的工具调用与文本。
+func ResponsesEventToChatChunks(evt *apicompat.ResponsesStreamEvent, state *ResponsesEventToChatState) []ChatCompletionChunk {
+	if state.Finished {
+		return nil
+	}
+	var chunks []ChatCompletionChunk
+	if evt.Type == "response.created" && evt.Response != nil && evt.Response.ID != "" && state.ID == "" {
+		state.ID = chatCompletionID(evt.Response.ID)
+	}
+	if usage := ResponsesStreamEventUsage(evt); usage != nil {
+		state.usage = usage
+	}
+	if !state.RoleSent {
+		state.RoleSent = true
+		empty := ""
+		chunks = append(chunks, makeChatChunk(state, ChatDelta{Role: "assistant", Content: &empty}, nil))
+	}
+
+	switch evt.Type {
+	case "response.output_text.delta":
+		if evt.Delta != "" {
+			state.textSeen[state.outputIndex(evt)] = true
+			chunks = append(chunks, makeChatTextChunk(state, evt.Delta))
+		}
+	case "response.output_text.done":
+		idx := state.outputIndex(evt)
+		if !state.textSeen[idx] && evt.Text != "" {
+			state.textSeen[idx] = true
+			chunks = append(chunks, makeChatTextChunk(state, evt.Text))
+		}
+	case "response.reasoning_summary_text.delta", "response.reasoning_text.delta":
+		if evt.Delta != "" && state.IncludeReasoning {
+			chunks = append(chunks, state.reasoningChunk(evt, evt.Delta))
+		}
+	case "response.reasoning_summary_text.done", "response.reasoning_text.done":
+		key := [2]int{state.outputIndex(evt), evt.SummaryIndex}
+		if !state.reasoningSeen[key] && evt.Text != "" && state.IncludeReasoning {
+			chunks = append(chunks, state.reasoningChunk(evt, evt.Text))
+		}
+	case "response.output_text.annotation.added":
+		if evt.Annotation != nil {
+			if ca, ok := responsesAnnotationToChat(*evt.Annotation, 0); ok {
+				chunks = append(chunks, makeChatChunk(state, ChatDelta{Annotations: []ChatAnnotation{ca}}, nil))
+			}
+		}
+	case "response.output_item.added":
+		if evt.Item != nil && evt.Item.Type == "function_call" {
+			if evt.Item.ID != "" {
+				state.itemOutputIndex[evt.Item.ID] = evt.OutputIndex
+			}
+			chunks = append(chunks, state.startToolCall(state.toolCall(evt.OutputIndex), evt.Item.CallID, evt.Item.Name, evt.Item.Arguments)...)
+		}
+		if evt.Item != nil && isServerToolItemType(evt.Item.Type) {
+			if evt.Item.ID != "" {
+				state.itemOutputIndex[evt.Item.ID] = evt.OutputIndex
+			}
+			chunks = append(chunks, state.startServerToolCall(state.serverToolCall(evt.OutputIndex), evt.Item)...)
+		}
+	case "response.web_search_call.searching", "response.code_interpreter_call.interpreting":
+		call := state.serverToolCall(state.outputIndex(evt))
+		if call.started && !call.done {
+			status := evt.Type[strings.LastIndexByte(evt.Type, '.')+1:]
+			chunks = append(chunks, makeChatServerToolChunk(state, ChatServerToolCall{Index: &call.index, Status: status}))
+		}
+	case "response.code_interpreter_call_code.delta":
+		call := state.serverToolCall(state.outputIndex(evt))
+		if call.started && evt.Delta != "" {
+			call.codeSent = true
+			chunks = append(chunks, makeChatServerToolChunk(state, ChatServerToolCall{Index: &call.index, Code: evt.Delta}))
+		}
+	case "response.code_interpreter_call_code.done":
+		call := state.serverToolCall(state.outputIndex(evt))
+		if call.started && !call.codeSent && evt.Code != "" {
+			call.codeSent = true
+			chunks = append(chunks, makeChatServerToolChunk(state, ChatServerToolCall{Index: &call.index, Code: evt.Code}))
+		}
+	case "response.function_call_arguments.delta":
+		if evt.Delta == "" {
+			break
+		}
+		call := state.toolCall(state.outputIndex(evt))
+		if !call.started {
+			call.pendingArgs.WriteString(evt.Delta)
+			break
+		}
+		call.argsSent = true
+		chunks = append(chunks, makeChatToolArgsChunk(state, call.index, evt.Delta))
+	case "response.function_call_arguments.done":
+		call := state.toolCall(state.outputIndex(evt))
+		switch {
+		case call.started:
+			if !call.argsSent && evt.Arguments != "" {
+				call.argsSent = true
+				chunks = append(chunks, makeChatToolArgsChunk(state, call.index, evt.Arguments))
+			}
+		case evt.CallID != "" && evt.Name != "":
+			call.pendingArgs.Reset()
+			chunks = append(chunks, state.startToolCall(call, evt.CallID, evt.Name, evt.Arguments)...)
+		case evt.Arguments != "":
+			call.pendingArgs.Reset()
+			call.pendingArgs.WriteString(evt.Arguments)
+		}
+	case "response.output_item.done":
+		if evt.Item != nil && evt.Item.Type == "function_call" {
+			chunks = append(chunks, state.completeToolCall(state.toolCall(evt.OutputIndex), evt.Item)...)
+		}
+		if evt.Item != nil && isServerToolItemType(evt.Item.Type) {
+			chunks = append(chunks, state.completeServerToolCall(state.serverToolCall(evt.OutputIndex), evt.Item)...)
+		}
+	case "response.completed", "response.incomplete", "response.failed", "response.done":
+		state.Finished = true
+		var details *apicompat.ResponsesIncompleteDetails
+		status := strings.TrimPrefix(evt.Type, "response.")
+		if evt.Response != nil {
+			details = evt.Response.IncompleteDetails
+			if evt.Response.Status != "" {
+				status = evt.Response.Status
+			}
+			chunks = append(chunks, state.reconcileOutput(evt.Response.Output)...)
+		}
+		reason := responsesToChatFinishReason(status, details, state.HasToolCall)
+		chunks = append(chunks, makeChatChunk(state, ChatDelta{}, &reason))
+		chunks = append(chunks, makeChatUsageChunks(state)...)
+	}
+	return chunks
+}
+
+// ResponsesStreamEventUsage 返回事件携带的用量：优先 response.usage，其次事件顶层的 usage。
+func ResponsesStreamEventUsage(evt *apicompat.ResponsesStreamEvent) *apicompat.ResponsesUsage {
+	if evt.Response != nil && evt.Response.Usage != nil {
+		return evt.Response.Usage
+	}
+	return evt.Usage
+}
+
+// makeChatUsageChunks 按 stream_options.include_usage 输出末尾的用量分片（choices 为空）。
+// 上游未上报用量时仍输出全零用量，保证客户端总能收到该分片。
+func makeChatUsageChunks(state *ResponsesEventToChatState) []ChatCompletionChunk {
+	if !state.IncludeUsage {
+		return nil
+	}
+	return []ChatCompletionChunk{{
+		ID:      state.ID,
+		Object:  "chat.completion.chunk",
+		Created: state.Created,
+		Model:   state.Model,
+		Choices: []ChatChunkChoice{},
+		Usage:   streamUsageToChat(state.usage),
+	}}
+}
+
+// streamUsageToChat 同 responsesUsageToChat，但缺失用量时返回全零对象而非 nil。
+func streamUsageToChat(usage *apicompat.ResponsesUsage) *ChatUsage {
+	if out := responsesUsageToChat(usage); out != nil {
+		return out
+	}
+	return &ChatUsage{}
+}
+
+// outputIndex 解析事件所属的 output_index：携带已知 item_id 时以 item_id 为准。
+func (state *ResponsesEventToChatState) outputIndex(evt *apicompat.ResponsesStreamEvent) int {
+	if evt.ItemID != "" {
+		if idx, ok := state.itemOutputIndex[evt.ItemID]; ok {
+			return idx
+		}
+	}
+	return evt.OutputIndex
+}
+
+func (state *ResponsesEventToChatState) toolCall(outputIndex int) *chatStreamToolCall {
+	call, ok := state.toolCalls[outputIndex]
+	if !ok {
+		call = &chatStreamToolCall{}
+		state.toolCalls[outputIndex] = call
+	}
+	return call
+}
+
+// startToolCall 发出工具调用的首个分片（id/name/index），并带上已缓存或已知的参数。
+func (state *ResponsesEventToChatState) startToolCall(call *chatStreamToolCall, callID, name, arguments string) []ChatCompletionChunk {
+	if call.started {
+		return nil
+	}
+	if callID != "" {
+		state.toolCallsByID[callID] = call
+	}
+	call.started = true
+	call.index = state.NextToolIdx
+	state.NextToolIdx++
+	state.HasToolCall = true
+
+	args := call.pendingArgs.String()
+	if args == "" {
+		args = arguments
+	}
+	call.pendingArgs.Reset()
+	call.argsSent = args != ""
+	idx := call.index
+	return []ChatCompletionChunk{makeChatChunk(state, ChatDelta{ToolCalls: []ChatToolCall{{
+		Index:    &idx,
+		ID:       callID,
+		Type:     "function",
+		Function: ChatFunctionCall{Name: name, Arguments: args},
+	}}}, nil)}
+}
+
+// completeToolCall 处理完整的 function_call 输出项：未开始则整体发出，已开始但无参数增量则补发参数。
+func (state *ResponsesEventToChatState) completeToolCall(call *chatStreamToolCall, item *apicompat.ResponsesOutput) []ChatCompletionChunk {
+	if !call.started {
+		return state.startToolCall(call, item.CallID, item.Name, item.Arguments)
+	}
+	if call.argsSent {
+		return nil
+	}
+	args := call.pendingArgs.String()
+	if args == "" {
+		args = item.Arguments
+	}
+	if args == "" {
+		return nil
+	}
+	call.argsSent = true
+	return []ChatCompletionChunk{makeChatToolArgsChunk(state, call.index, args)}
+}
+
+func isServerToolItemType(typ string) bool {
+	return typ == "web_search_call" || typ == "code_interpreter_call"
+}
+
+func (state *ResponsesEventToChatState) serverToolCall(outputIndex int) *chatStreamServerToolCall {
+	call, ok := state.serverToolCalls[outputIndex]
+	if !ok {
+		call = &chatStreamServerToolCall{}
+		state.serverToolCalls[outputIndex] = call
+	}
+	return call
+}
+
+// startServerToolCall 发出内置工具调用的首个分片（index/id/type/status）
+func (state *ResponsesEventToChatState) startServerToolCall(call *chatStreamServerToolCall, item *apicompat.ResponsesOutput) []ChatCompletionChunk {
+	if call.started {
+		return nil
+	}
+	if item.ID != "" {
+		state.serverToolCallsByID[item.ID] = call
+	}
+	call.started = true
+	call.index = state.NextServerToolIdx
+	state.NextServerToolIdx++
+
+	delta := ChatServerToolCall{Index: &call.index, ID: item.ID, Type: strings.TrimSuffix(item.Type, "_call"), Status: "in_progress"}
+	if item.Code != "" {
+		call.codeSent = true
+		delta.Code = item.Code
+	}
+	return []ChatCompletionChunk{makeChatServerToolChunk(state, delta)}
+}
+
+// completeServerToolCall 处理完整的内置工具输出项：补发未开始的调用与未流式输出的代码，并附上最终状态与结果
+func (state *ResponsesEventToChatState) completeServerToolCall(call *chatStreamServerToolCall, item *apicompat.ResponsesOutput) []ChatCompletionChunk {
+	if call.done {
+		return nil
+	}
+	chunks := state.startServerToolCall(call, item)
+	call.done = true
+
+	final := responsesItemToServerToolCall(item)
+	final.Index = &call.index
+	final.ID, final.Type = "", ""
+	if call.codeSent {
+		final.Code = ""
+	}
+	call.codeSent = true
+	return append(chunks, makeChatServerToolChunk(state, final))
+}
+
+// reconcileOutput 按最终 output 补齐流中未出现的工具调用与文本。
+// 工具调用按 call_id 匹配；文本仅在整个流未输出过任何文本时补发，避免与增量重复。
+func (state *ResponsesEventToChatState) reconcileOutput(output []apicompat.ResponsesOutput) []ChatCompletionChunk {
+	var chunks []ChatCompletionChunk
+	textStreamed := len(state.textSeen) > 0
+	for i := range output {
+		item := &output[i]
+		switch item.Type {
+		case "function_call":
+			call, ok := state.toolCallsByID[item.CallID]
+			if !ok || item.CallID == "" {
+				call = &chatStreamToolCall{}
+			}
+			chunks = append(chunks, state.completeToolCall(call, item)...)
+		case "web_search_call", "code_interpreter_call":
+			call, ok := state.serverToolCallsByID[item.ID]
+			if !ok || item.ID == "" {
+				call = &chatStreamServerToolCall{}
+			}
+			chunks = append(chunks, state.completeServerToolCall(call, item)...)
+		case "message":
+			if textStreamed {
+				continue
+			}
+			var text strings.Builder
+			for _, part := range item.Content {
+				if part.Type == "output_text" {
+					text.WriteString(part.Text)
+				}
+			}
+			if text.Len() > 0 {
+				state.textSeen[i] = true
+				chunks = append(chunks, makeChatTextChunk(state, text.String()))
+			}
+		}
+	}
+	return chunks
+}
+
+// reasoningChunk 输出推理增量；不同摘要段之间以空行分隔。
+func (state *ResponsesEventToChatState) reasoningChunk(evt *apicompat.ResponsesStreamEvent, text string) ChatCompletionChunk {
+	key := [2]int{state.outputIndex(evt), evt.SummaryIndex}
+	if !state.reasoningSeen[key] {
+		state.reasoningSeen[key] = true
+		if state.reasoningSent {
+			text = "\n\n" + text
+		}
+	}
+	state.reasoningSent = true
+	return makeChatChunk(state, ChatDelta{ReasoningContent: &text}, nil)
+}
+
+// FinalizeResponsesChatStream 上游未发送终止事件即结束时补发 finish_reason 分片。
+func FinalizeResponsesChatStream(state *ResponsesEventToChatState) []ChatCompletionChunk {
+	if state.Finished {
+		return nil
+	}
+	state.Finished = true
+	reason := "stop"
+	if state.HasToolCall {
+		reason = "tool_calls"
+	}
+	return append([]ChatCompletionChunk{makeChatChunk(state, ChatDelta{}, &reason)}, makeChatUsageChunks(state)...)
+}
+
+// ChatChunkToSSE 将 chunk 编码为 SSE data 行。
+func ChatChunkToSSE(chunk ChatCompletionChunk) (string, error) {
+	data, err := json.Marshal(chunk)
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("data: %s\n\n", data), nil
+}
+
+// EmittedText 返回已发送给客户端的全部正文增量。
+func (state *ResponsesEventToChatState) EmittedText() string {
+	return state.emittedText.String()
+}
+
+func makeChatTextChunk(state *ResponsesEventToChatState, text string) ChatCompletionChunk {
+	state.emittedText.WriteString(text)
+	return makeChatChunk(state, ChatDelta{Content: &text}, nil)
+}
+
+func makeChatToolArgsChunk(state *ResponsesEventToChatState, index int, args string) ChatCompletionChunk {
+	return makeChatChunk(state, ChatDelta{ToolCalls: []ChatToolCall{{
+		Index:    &index,
+		Function: ChatFunctionCall{Arguments: args},
+	}}}, nil)
+}
+
+func makeChatServerToolChunk(state *ResponsesEventToChatState, call ChatServerToolCall) ChatCompletionChunk {
+	return makeChatChunk(state, ChatDelta{ServerToolCalls: []ChatServerToolCall{call}}, nil)
+}
+
+func makeChatChunk(state *ResponsesEventToChatState, delta ChatDelta, finishReason *string) ChatCompletionChunk {
+	if state.ID == "" {
+		state.ID = chatCompletionID("")
+	}
+	return ChatCompletionChunk{
+		ID:      state.ID,
+		Object:  "chat.completion.chunk",
+		Created: state.Created,
+		Model:   state.Model,
+		Choices: []ChatChunkChoice{{
+			Index:        0,
+			Delta:        delta,
+			FinishReason: finishReason,
+		}},
+	}
+}
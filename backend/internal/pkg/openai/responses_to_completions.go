@@ -0,0 +1,212 @@
+package openai
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/ShaohongDong/sub2api/internal/pkg/apicompat"
+)
+
+// CompletionOptions 控制 Responses → text_completion 的回写行为。
+type CompletionOptions struct {
+	// Echo 为 true 时在 text 前拼接原始 prompt（prompt 部分不含 logprobs）。
+	Echo   bool
+	Prompt string
+	// Logprobs 为 true 时按旧版列式结构回写 logprobs。
+	Logprobs bool
+}
+
+// ResponsesToCompletion 将 Responses API 响应转换为 text_completion 对象。
+func ResponsesToCompletion(resp *apicompat.ResponsesResponse, model string, opts CompletionOptions) *Completion {
+	var text strings.Builder
+	if opts.Echo {
+		text.WriteString(opts.Prompt)
+	}
+	var logprobs *CompletionLogprobs
+	if opts.Logprobs {
+		logprobs = newCompletionLogprobs()
+	}
+
+	for _, item := range resp.Output {
+		if item.Type != "message" {
+			continue
+		}
+		for _, part := range item.Content {
+			if part.Type != "output_text" {
+				continue
+			}
+			appendCompletionLogprobs(logprobs, part.Logprobs, text.Len())
+			text.WriteString(part.Text)
+		}
+	}
+
+	reason := responsesToChatFinishReason(resp.Status, resp.IncompleteDetails, false)
+	return &Completion{
+		ID:      completionID(resp.ID),
+		Object:  "text_completion",
+		Created: time.Now().Unix(),
+		Model:   model,
+		Choices: []CompletionChoice{{
+			Text:         text.String(),
+			Index:        0,
+			Logprobs:     logprobs,
+			FinishReason: &reason,
+		}},
+		Usage: responsesUsageToChat(resp.Usage),
+	}
+}
+
+func newCompletionLogprobs() *CompletionLogprobs {
+	return &CompletionLogprobs{
+		Tokens:        []string{},
+		TokenLogprobs: []float64{},
+		TopLogprobs:   []map[string]float64{},
+		TextOffset:    []int{},
+	}
+}
+
+// appendCompletionLogprobs 追加 token 级 logprobs；offset 为首个 token 在 text 中的字符偏移。
+func appendCompletionLogprobs(dst *CompletionLogprobs, src []apicompat.ResponsesLogprob, offset int) {
+	if dst == nil {
+		return
+	}
+	for _, lp := range src {
+		dst.Tokens = append(dst.Tokens, lp.Token)
+		dst.TokenLogprobs = append(dst.TokenLogprobs, lp.Logprob)
+		top := make(map[string]float64, len(lp.TopLogprobs))
+		for _, alt := range lp.TopLogprobs {
+			top[alt.Token] = alt.Logprob
+		}
+		dst.TopLogprobs = append(dst.TopLogprobs, top)
+		dst.TextOffset = append(dst.TextOffset, offset)
+		offset += len(lp.Token)
+	}
+}
+
+// completionID 由 Responses ID 派生 cmpl- 前缀的 ID。
+func completionID(responseID string) string {
+	if responseID == "" {
+		return fmt.Sprintf("cmpl-%d", time.Now().UnixNano())
+	}
+	return "cmpl-" + strings.TrimPrefix(responseID, "resp_")
+}
+
+// ResponsesEventToCompletionState 记录 Responses SSE 事件 → text_completion 分片的转换状态。
+type ResponsesEventToCompletionState struct {
+	ID           string
+	Model        string
+	Created      int64
+	IncludeUsage bool
+	Options      CompletionOptions
+
+	EchoSent bool
+	Finished bool
+	// TextOffset 已输出文本的字符偏移（用于 logprobs.text_offset）
+	TextOffset int
+
+	// usage 最近一次上报的用量，终止事件未携带 usage 时用于 include_usage 分片
+	usage *apicompat.ResponsesUsage
+}
+
+// NewResponsesEventToCompletionState returns an initialised stream state.
+func NewResponsesEventToCompletionState() *ResponsesEventToCompletionState {
+	return &ResponsesEventToCompletionState{Created: time.Now().Unix()}
+}
+
+// ResponsesEventToCompletionChunks 将单个 Responses SSE 事件转换为零或多个 text_completion 分片。
+func ResponsesEventToCompletionChunks(evt *apicompat.ResponsesStreamEvent, state *ResponsesEventToCompletionState) []Completion {
+	if state.Finished {
+		return nil
+	}
+	var chunks []Completion
+	if evt.Type == "response.created" && evt.Response != nil && evt.Response.ID != "" && state.ID == "" {
+		state.ID = completionID(evt.Response.ID)
+	}
+	if usage := ResponsesStreamEventUsage(evt); usage != nil {
+		state.usage = usage
+	}
+	if state.Options.Echo && !state.EchoSent {
+		state.EchoSent = true
+		if state.Options.Prompt != "" {
+			chunks = append(chunks, makeCompletionChunk(state, state.Options.Prompt, nil, nil))
+		}
+	}
+
+	switch evt.Type {
+	case "response.output_text.delta":
+		if evt.Delta == "" {
+			return chunks
+		}
+		var logprobs *CompletionLogprobs
+		if state.Options.Logprobs {
+			logprobs = newCompletionLogprobs()
+			appendCompletionLogprobs(logprobs, evt.Logprobs, state.TextOffset)
+		}
+		chunks = append(chunks, makeCompletionChunk(state, evt.Delta, logprobs, nil))
+	case "response.completed", "response.incomplete", "response.failed":
+		state.Finished = true
+		var details *apicompat.ResponsesIncompleteDetails
+		if evt.Response != nil {
+			details = evt.Response.IncompleteDetails
+		}
+		reason := responsesToChatFinishReason(strings.TrimPrefix(evt.Type, "response."), details, false)
+		chunks = append(chunks, makeCompletionChunk(state, "", nil, &reason))
+		chunks = append(chunks, makeCompletionUsageChunks(state)...)
+	}
+	return chunks
+}
+
+// makeCompletionUsageChunks 按 stream_options.include_usage 输出末尾的用量分片（choices 为空）。
+func makeCompletionUsageChunks(state *ResponsesEventToCompletionState) []Completion {
+	if !state.IncludeUsage {
+		return nil
+	}
+	return []Completion{{
+		ID:      state.ID,
+		Object:  "text_completion",
+		Created: state.Created,
+		Model:   state.Model,
+		Choices: []CompletionChoice{},
+		Usage:   streamUsageToChat(state.usage),
+	}}
+}
+
+// FinalizeResponsesCompletionStream 上游未发送终止事件即结束时补发 finish_reason 分片。
+func FinalizeResponsesCompletionStream(state *ResponsesEventToCompletionState) []Completion {
+	if state.Finished {
+		return nil
+	}
+	state.Finished = true
+	reason := "stop"
+	return append([]Completion{makeCompletionChunk(state, "", nil, &reason)}, makeCompletionUsageChunks(state)...)
+}
+
+// CompletionChunkToSSE 将分片编码为 SSE data 行。
+func CompletionChunkToSSE(chunk Completion) (string, error) {
+	data, err := json.Marshal(chunk)
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("data: %s\n\n", data), nil
+}
+
+func makeCompletionChunk(state *ResponsesEventToCompletionState, text string, logprobs *CompletionLogprobs, finishReason *string) Completion {
+	if state.ID == "" {
+		state.ID = completionID("")
+	}
+	state.TextOffset += len(text)
+	return Completion{
+		ID:      state.ID,
+		Object:  "text_completion",
+		Created: state.Created,
+		Model:   state.Model,
+		Choices: []CompletionChoice{{
+			Text:         text,
+			Index:        0,
+			Logprobs:     logprobs,
+			FinishReason: finishReason,
+		}},
+	}
+}
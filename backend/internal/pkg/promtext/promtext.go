@@ -0,0 +1,239 @@
+// Package promtext 提供最小化的 Prometheus 文本格式（0.0.4）指标：计数器、直方图与抓取时写出的 Gauge。
+package promtext
+
+import (
+	"bufio"
+	"io"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// ContentType 文本格式的响应 Content-Type
+const ContentType = "text/plain; version=0.0.4; charset=utf-8"
+
+// labelSep 拼接标签值作为 map 键（标签值为合法 UTF-8 文本，不含该字节）
+const labelSep = "\xff"
+
+// CounterVec 带标签的计数器
+type CounterVec struct {
+	name   string
+	help   string
+	labels []string
+
+	mu     sync.Mutex
+	series map[string]*counterSeries
+}
+
+type counterSeries struct {
+	values []string
+	value  float64
+}
+
+// NewCounterVec 创建计数器
+func NewCounterVec(name, help string, labels ...string) *CounterVec {
+	return &CounterVec{name: name, help: help, labels: labels, series: make(map[string]*counterSeries)}
+}
+
+// Add 为标签值对应的序列累加 delta（负数忽略）；标签值个数须与声明一致
+func (v *CounterVec) Add(delta float64, labelValues ...string) {
+	if delta < 0 || len(labelValues) != len(v.labels) {
+		return
+	}
+	key := strings.Join(labelValues, labelSep)
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	s := v.series[key]
+	if s == nil {
+		s = &counterSeries{values: append([]string(nil), labelValues...)}
+		v.series[key] = s
+	}
+	s.value += delta
+}
+
+// Inc 累加 1
+func (v *CounterVec) Inc(labelValues ...string) {
+	v.Add(1, labelValues...)
+}
+
+// Write 以文本格式写出
+func (v *CounterVec) Write(w *Writer) {
+	v.mu.Lock()
+	samples := make([]Sample, 0, len(v.series))
+	for _, s := range v.series {
+		samples = append(samples, Sample{Labels: v.labels, Values: s.values, Value: s.value})
+	}
+	v.mu.Unlock()
+	w.WriteFamily(v.name, v.help, "counter", samples)
+}
+
+// HistogramVec 带标签的直方图（累积桶）
+type HistogramVec struct {
+	name    string
+	help    string
+	labels  []string
+	buckets []float64
+
+	mu     sync.Mutex
+	series map[string]*histogramSeries
+}
+
+type histogramSeries struct {
+	values []string
+	counts []uint64 // 与 buckets 一一对应，非累积
+	count  uint64
+	sum    float64
+}
+
+// NewHistogramVec 创建直方图；buckets 为升序上界
+func NewHistogramVec(name, help string, buckets []float64, labels ...string) *HistogramVec {
+	sorted := append([]float64(nil), buckets...)
+	sort.Float64s(sorted)
+	return &HistogramVec{name: name, help: help, labels: labels, buckets: sorted, series: make(map[string]*histogramSeries)}
+}
+
+// Observe 记录一次观测值
+func (v *HistogramVec) Observe(value float64, labelValues ...string) {
+	if math.IsNaN(value) || len(labelValues) != len(v.labels) {
+		return
+	}
+	key := strings.Join(labelValues, labelSep)
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	s := v.series[key]
+	if s == nil {
+		s = &histogramSeries{values: append([]string(nil), labelValues...), counts: make([]uint64, len(v.buckets))}
+		v.series[key] = s
+	}
+	if i := sort.SearchFloat64s(v.buckets, value); i < len(v.buckets) {
+		s.counts[i]++
+	}
+	s.count++
+	s.sum += value
+}
+
+// Write 以文本格式写出（_bucket / _sum / _count）
+func (v *HistogramVec) Write(w *Writer) {
+	bucketLabels := append(append([]string(nil), v.labels...), "le")
+	v.mu.Lock()
+	keys := make([]string, 0, len(v.series))
+	for key := range v.series {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	samples := make([]Sample, 0, len(keys)*(len(v.buckets)+3))
+	for _, key := range keys {
+		s := v.series[key]
+		var cumulative uint64
+		for i, upper := range v.buckets {
+			cumulative += s.counts[i]
+			samples = append(samples, Sample{Suffix: "_bucket", Labels: bucketLabels, Values: append(append([]string(nil), s.values...), formatFloat(upper)), Value: float64(cumulative)})
+		}
+		samples = append(samples,
+			Sample{Suffix: "_bucket", Labels: bucketLabels, Values: append(append([]string(nil), s.values...), "+Inf"), Value: float64(s.count)},
+			Sample{Suffix: "_sum", Labels: v.labels, Values: s.values, Value: s.sum},
+			Sample{Suffix: "_count", Labels: v.labels, Values: s.values, Value: float64(s.count)},
+		)
+	}
+	v.mu.Unlock()
+	w.writeFamilySorted(v.name, v.help, "histogram", samples)
+}
+
+// Sample 单个样本
+type Sample struct {
+	// Suffix 追加在指标名后（直方图的 _bucket / _sum / _count）
+	Suffix string
+	Labels []string
+	Values []string
+	Value  float64
+}
+
+// Writer 按文本格式写出指标族；写出错误记录在 Err 中，后续写入被忽略
+type Writer struct {
+	w   *bufio.Writer
+	err error
+}
+
+// NewWriter 创建文本格式 writer，结束时需调用 Flush
+func NewWriter(w io.Writer) *Writer {
+	return &Writer{w: bufio.NewWriter(w)}
+}
+
+// WriteFamily 写出一个指标族；样本按标签值排序，保证输出稳定
+func (w *Writer) WriteFamily(name, help, metricType string, samples []Sample) {
+	sort.SliceStable(samples, func(i, j int) bool {
+		return strings.Join(samples[i].Values, labelSep) < strings.Join(samples[j].Values, labelSep)
+	})
+	w.writeFamilySorted(name, help, metricType, samples)
+}
+
+func (w *Writer) writeFamilySorted(name, help, metricType string, samples []Sample) {
+	if w.err != nil {
+		return
+	}
+	b := w.w
+	b.WriteString("# HELP ")
+	b.WriteString(name)
+	b.WriteByte(' ')
+	b.WriteString(escapeHelp(help))
+	b.WriteString("\n# TYPE ")
+	b.WriteString(name)
+	b.WriteByte(' ')
+	b.WriteString(metricType)
+	b.WriteByte('\n')
+	for _, s := range samples {
+		b.WriteString(name)
+		b.WriteString(s.Suffix)
+		if len(s.Labels) > 0 {
+			b.WriteByte('{')
+			for i, label := range s.Labels {
+				if i > 0 {
+					b.WriteByte(',')
+				}
+				b.WriteString(label)
+				b.WriteString(`="`)
+				if i < len(s.Values) {
+					b.WriteString(escapeLabelValue(s.Values[i]))
+				}
+				b.WriteByte('"')
+			}
+			b.WriteByte('}')
+		}
+		b.WriteByte(' ')
+		b.WriteString(formatFloat(s.Value))
+		if _, err := b.WriteString("\n"); err != nil {
+			w.err = err
+			return
+		}
+	}
+}
+
+// Flush 刷新缓冲并返回写出过程中的首个错误
+func (w *Writer) Flush() error {
+	if w.err != nil {
+		return w.err
+	}
+	return w.w.Flush()
+}
+
+func formatFloat(v float64) string {
+	switch {
+	case math.IsInf(v, 1):
+		return "+Inf"
+	case math.IsInf(v, -1):
+		return "-Inf"
+	case math.IsNaN(v):
+		return "NaN"
+	}
+	return strconv.FormatFloat(v, 'g', -1, 64)
+}
+
+var (
+	helpEscaper  = strings.NewReplacer(`\`, `\\`, "\n", `\n`)
+	labelEscaper = strings.NewReplacer(`\`, `\\`, "\n", `\n`, `"`, `\"`)
+)
+
+func escapeHelp(s string) string       { return helpEscaper.Replace(s) }
+func escapeLabelValue(s string) string { return labelEscaper.Replace(s) }
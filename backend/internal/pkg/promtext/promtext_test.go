@@ -0,0 +1,56 @@
+package promtext
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestCounterVecWrite(t *testing.T) {
+	v := NewCounterVec("requests_total", "Requests.\nSecond line", "model", "status")
+	v.Inc("gpt-5", "200")
+	v.Add(2, "claude \"opus\"\n", "429")
+	v.Add(-1, "gpt-5", "200") // 负数忽略
+	v.Inc("missing-label")    // 标签个数不符忽略
+	v.Add(0.5, "gpt-5", "200")
+
+	var buf bytes.Buffer
+	w := NewWriter(&buf)
+	v.Write(w)
+	require.NoError(t, w.Flush())
+	require.Equal(t, `# HELP requests_total Requests.\nSecond line
+# TYPE requests_total counter
+requests_total{model="claude \"opus\"\n",status="429"} 2
+requests_total{model="gpt-5",status="200"} 1.5
+`, buf.String())
+}
+
+func TestHistogramVecWrite(t *testing.T) {
+	v := NewHistogramVec("latency_seconds", "Latency.", []float64{1, 0.5}, "model")
+	v.Observe(0.2, "m")
+	v.Observe(0.5, "m") // 等于上界计入该桶
+	v.Observe(3, "m")
+
+	var buf bytes.Buffer
+	w := NewWriter(&buf)
+	v.Write(w)
+	require.NoError(t, w.Flush())
+	require.Equal(t, `# HELP latency_seconds Latency.
+# TYPE latency_seconds histogram
+latency_seconds_bucket{model="m",le="0.5"} 2
+latency_seconds_bucket{model="m",le="1"} 2
+latency_seconds_bucket{model="m",le="+Inf"} 3
+latency_seconds_sum{model="m"} 3.7
+latency_seconds_count{model="m"} 3
+`, buf.String())
+}
+
+func TestWriteFamilyGaugeWithoutLabels(t *testing.T) {
+	var buf bytes.Buffer
+	w := NewWriter(&buf)
+	w.WriteFamily("up", "Up.", "gauge", []Sample{{Value: 1}})
+	w.WriteFamily("empty", "No samples.", "gauge", nil)
+	require.NoError(t, w.Flush())
+	require.Equal(t, "# HELP up Up.\n# TYPE up gauge\nup 1\n# HELP empty No samples.\n# TYPE empty gauge\n", buf.String())
+}
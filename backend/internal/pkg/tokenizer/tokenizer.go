@@ -0,0 +1,108 @@
+// Package tokenizer counts tokens with the tiktoken BPE encodings embedded in
+// the binary (o200k_base, cl100k_base), so no vocabulary is downloaded at
+// runtime. Encodings are loaded lazily on first use; when loading fails a
+// character-based heuristic is used instead.
+//
+// Non-OpenAI models (Claude, Gemini) are counted with o200k_base, which is an
+// approximation of their proprietary tokenizers.
+package tokenizer
+
+import (
+	"strings"
+	"sync"
+
+	"github.com/pkoukk/tiktoken-go"
+	tiktokenloader "github.com/pkoukk/tiktoken-go-loader"
+)
+
+// Supported encodings.
+const (
+	EncodingO200K  = "o200k_base"
+	EncodingCL100K = "cl100k_base"
+)
+
+func init() {
+	tiktoken.SetBpeLoader(tiktokenloader.NewOfflineLoader())
+}
+
+type lazyEncoding struct {
+	once sync.Once
+	enc  *tiktoken.Tiktoken
+	err  error
+}
+
+var encodings = map[string]*lazyEncoding{
+	EncodingO200K:  {},
+	EncodingCL100K: {},
+}
+
+func getEncoding(name string) *tiktoken.Tiktoken {
+	lazy, ok := encodings[name]
+	if !ok {
+		lazy = encodings[EncodingO200K]
+		name = EncodingO200K
+	}
+	lazy.once.Do(func() {
+		lazy.enc, lazy.err = tiktoken.GetEncoding(name)
+	})
+	if lazy.err != nil {
+		return nil
+	}
+	return lazy.enc
+}
+
+// cl100kModelPrefixes are the OpenAI model families that predate o200k_base.
+var cl100kModelPrefixes = []string{"gpt-4", "gpt-3.5", "text-embedding-3", "text-embedding-ada"}
+
+// EncodingForModel returns the encoding used to count tokens for model.
+func EncodingForModel(model string) string {
+	m := strings.ToLower(strings.TrimSpace(model))
+	if strings.HasPrefix(m, "gpt-4o") || strings.HasPrefix(m, "gpt-4.") {
+		return EncodingO200K
+	}
+	for _, prefix := range cl100kModelPrefixes {
+		if strings.HasPrefix(m, prefix) {
+			return EncodingCL100K
+		}
+	}
+	return EncodingO200K
+}
+
+// Count returns the number of tokens of text for model.
+func Count(model, text string) int {
+	return CountWithEncoding(EncodingForModel(model), text)
+}
+
+// CountWithEncoding returns the number of tokens of text in the named
+// encoding; unknown names fall back to o200k_base.
+func CountWithEncoding(encoding, text string) int {
+	if text == "" {
+		return 0
+	}
+	enc := getEncoding(encoding)
+	if enc == nil {
+		return Estimate(text)
+	}
+	return len(enc.Encode(text, nil, nil))
+}
+
+// Estimate approximates the token count without a vocabulary: about four
+// characters per token for ASCII-heavy text and one token per rune for
+// CJK-heavy text.
+func Estimate(text string) int {
+	text = strings.TrimSpace(text)
+	if text == "" {
+		return 0
+	}
+	runes := []rune(text)
+	ascii := 0
+	for _, r := range runes {
+		if r <= 0x7f {
+			ascii++
+		}
+	}
+	if float64(ascii)/float64(len(runes)) >= 0.8 {
+		return (len(runes) + 3) / 4
+	}
+	return len(runes)
+}
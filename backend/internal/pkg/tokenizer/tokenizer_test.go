@@ -0,0 +1,40 @@
+package tokenizer
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEncodingForModel(t *testing.T) {
+	tests := map[string]string{
+		"gpt-5.2":                EncodingO200K,
+		"gpt-4o-mini":            EncodingO200K,
+		"gpt-4.1":                EncodingO200K,
+		"o3-mini":                EncodingO200K,
+		"claude-sonnet-4-5":      EncodingO200K,
+		"":                       EncodingO200K,
+		"gpt-4-turbo":            EncodingCL100K,
+		"GPT-3.5-turbo":          EncodingCL100K,
+		"text-embedding-3-small": EncodingCL100K,
+	}
+	for model, want := range tests {
+		assert.Equal(t, want, EncodingForModel(model), model)
+	}
+}
+
+func TestCount(t *testing.T) {
+	// Reference counts from the tiktoken Python package.
+	assert.Equal(t, 0, Count("gpt-5", ""))
+	assert.Equal(t, 2, Count("gpt-4o", "hello world"))
+	assert.Equal(t, 2, Count("gpt-4", "hello world"))
+	assert.Equal(t, 9, Count("gpt-4o", "The quick brown fox jumps over the lazy dog"))
+	// Special tokens in user text are encoded as ordinary text and never panic.
+	assert.Greater(t, Count("gpt-4o", "<|endoftext|>"), 1)
+}
+
+func TestEstimate(t *testing.T) {
+	assert.Equal(t, 0, Estimate("  "))
+	assert.Equal(t, 3, Estimate("hello world"))
+	assert.Equal(t, 4, Estimate("你好世界"))
+}
@@ -0,0 +1,21 @@
+package tracing
+
+import (
+	"time"
+
+	"github.com/ShaohongDong/sub2api/internal/config"
+)
+
+func OptionsFromConfig(cfg config.TracingConfig) InitOptions {
+	return InitOptions{
+		Enabled:           cfg.Enabled,
+		Endpoint:          cfg.Endpoint,
+		Headers:           cfg.Headers,
+		ServiceName:       cfg.ServiceName,
+		SampleRatio:       cfg.SampleRatio,
+		PropagateUpstream: cfg.PropagateUpstream,
+		QueueSize:         cfg.QueueSize,
+		BatchSize:         cfg.BatchSize,
+		ExportTimeout:     time.Duration(cfg.ExportTimeoutSeconds) * time.Second,
+	}
+}
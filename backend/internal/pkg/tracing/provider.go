@@ -0,0 +1,428 @@
+package tracing
+
+import (
+	"context"
+	"encoding/binary"
+	"math"
+	"math/rand/v2"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+	"go.opentelemetry.io/otel/trace/embedded"
+)
+
+// SpanData 已结束 span 的快照，交给 Exporter 导出
+type SpanData struct {
+	Name              string
+	SpanContext       trace.SpanContext
+	Parent            trace.SpanContext
+	Kind              trace.SpanKind
+	Start             time.Time
+	End               time.Time
+	Attributes        []attribute.KeyValue
+	Events            []Event
+	Status            codes.Code
+	StatusDescription string
+}
+
+// Event span 内的时间点事件
+type Event struct {
+	Name       string
+	Time       time.Time
+	Attributes []attribute.KeyValue
+}
+
+// Exporter 批量导出已结束的 span
+type Exporter interface {
+	ExportSpans(ctx context.Context, spans []SpanData) error
+}
+
+// ProviderOptions 控制采样与批量导出
+type ProviderOptions struct {
+	// SampleRatio 根 span 采样比例（0-1）；有父 span 时沿用父 span 的采样标记
+	SampleRatio   float64
+	QueueSize     int
+	BatchSize     int
+	FlushInterval time.Duration
+	ExportTimeout time.Duration
+	// OnExportError 导出失败回调（可为 nil）
+	OnExportError func(err error, spans int)
+}
+
+// Provider 最小化的 OpenTelemetry TracerProvider 实现：比例采样 + 异步批量导出。
+// span 结束后写入有界队列，队列满时丢弃，导出不阻塞请求路径。
+type Provider struct {
+	embedded.TracerProvider
+
+	opts        ProviderOptions
+	exporter    Exporter
+	sampleBound uint64
+
+	queue    chan SpanData
+	flushReq chan chan struct{}
+	stop     chan struct{}
+	done     chan struct{}
+	stopOnce sync.Once
+	stopped  atomic.Bool
+	dropped  atomic.Int64
+}
+
+var _ trace.TracerProvider = (*Provider)(nil)
+
+// NewProvider 创建 Provider 并启动后台导出协程，退出前需调用 Shutdown
+func NewProvider(opts ProviderOptions, exporter Exporter) *Provider {
+	if opts.QueueSize <= 0 {
+		opts.QueueSize = 2048
+	}
+	if opts.BatchSize <= 0 {
+		opts.BatchSize = 512
+	}
+	if opts.FlushInterval <= 0 {
+		opts.FlushInterval = 5 * time.Second
+	}
+	if opts.ExportTimeout <= 0 {
+		opts.ExportTimeout = 10 * time.Second
+	}
+	p := &Provider{
+		opts:        opts,
+		exporter:    exporter,
+		sampleBound: sampleBound(opts.SampleRatio),
+		queue:       make(chan SpanData, opts.QueueSize),
+		flushReq:    make(chan chan struct{}),
+		stop:        make(chan struct{}),
+		done:        make(chan struct{}),
+	}
+	go p.run()
+	return p
+}
+
+// sampleBound 与 OTel TraceIDRatioBased 一致：取 trace id 低 8 字节右移一位与上界比较
+func sampleBound(ratio float64) uint64 {
+	switch {
+	case ratio >= 1:
+		return math.MaxUint64
+	case ratio <= 0:
+		return 0
+	}
+	return uint64(ratio * (1 << 63))
+}
+
+// Tracer 实现 trace.TracerProvider
+func (p *Provider) Tracer(name string, opts ...trace.TracerOption) trace.Tracer {
+	return &tracer{provider: p, scope: name}
+}
+
+// Dropped 队列满或已关闭时丢弃的 span 数
+func (p *Provider) Dropped() int64 {
+	return p.dropped.Load()
+}
+
+// ForceFlush 导出当前队列中的全部 span
+func (p *Provider) ForceFlush(ctx context.Context) error {
+	ack := make(chan struct{})
+	select {
+	case p.flushReq <- ack:
+	case <-p.done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+	select {
+	case <-ack:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Shutdown 停止接收新 span，导出剩余 span 后返回
+func (p *Provider) Shutdown(ctx context.Context) error {
+	p.stopOnce.Do(func() {
+		p.stopped.Store(true)
+		close(p.stop)
+	})
+	select {
+	case <-p.done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (p *Provider) enqueue(data SpanData) {
+	if p.stopped.Load() {
+		p.dropped.Add(1)
+		return
+	}
+	select {
+	case p.queue <- data:
+	default:
+		p.dropped.Add(1)
+	}
+}
+
+func (p *Provider) run() {
+	defer close(p.done)
+	ticker := time.NewTicker(p.opts.FlushInterval)
+	defer ticker.Stop()
+
+	batch := make([]SpanData, 0, p.opts.BatchSize)
+	export := func() {
+		if len(batch) == 0 {
+			return
+		}
+		ctx, cancel := context.WithTimeout(context.Background(), p.opts.ExportTimeout)
+		err := p.exporter.ExportSpans(ctx, batch)
+		cancel()
+		if err != nil && p.opts.OnExportError != nil {
+			p.opts.OnExportError(err, len(batch))
+		}
+		batch = make([]SpanData, 0, p.opts.BatchSize)
+	}
+	drain := func() {
+		for {
+			select {
+			case data := <-p.queue:
+				batch = append(batch, data)
+				if len(batch) >= p.opts.BatchSize {
+					export()
+				}
+			default:
+				export()
+				return
+			}
+		}
+	}
+
+	for {
+		select {
+		case data := <-p.queue:
+			batch = append(batch, data)
+			if len(batch) >= p.opts.BatchSize {
+				export()
+			}
+		case <-ticker.C:
+			export()
+		case ack := <-p.flushReq:
+			drain()
+			close(ack)
+		case <-p.stop:
+			drain()
+			return
+		}
+	}
+}
+
+type tracer struct {
+	embedded.Tracer
+
+	provider *Provider
+	scope    string
+}
+
+// Start 实现 trace.Tracer
+func (t *tracer) Start(ctx context.Context, name string, opts ...trace.SpanStartOption) (context.Context, trace.Span) {
+	cfg := trace.NewSpanStartConfig(opts...)
+
+	var parent trace.SpanContext
+	if !cfg.NewRoot() {
+		parent = trace.SpanContextFromContext(ctx)
+	}
+
+	traceID := parent.TraceID()
+	if !parent.IsValid() {
+		traceID = newTraceID()
+	}
+	sampled := parent.IsSampled()
+	if !parent.IsValid() {
+		sampled = binary.BigEndian.Uint64(traceID[8:16])>>1 < t.provider.sampleBound
+	}
+	flags := trace.TraceFlags(0)
+	if sampled {
+		flags = trace.FlagsSampled
+	}
+	sc := trace.NewSpanContext(trace.SpanContextConfig{
+		TraceID:    traceID,
+		SpanID:     newSpanID(),
+		TraceFlags: flags,
+		TraceState: parent.TraceState(),
+	})
+
+	if !sampled {
+		s := &nonRecordingSpan{sc: sc, provider: t.provider}
+		return trace.ContextWithSpan(ctx, s), s
+	}
+
+	start := cfg.Timestamp()
+	if start.IsZero() {
+		start = time.Now()
+	}
+	kind := cfg.SpanKind()
+	if kind == trace.SpanKindUnspecified {
+		kind = trace.SpanKindInternal
+	}
+	s := &recordingSpan{
+		tracer: t,
+		data: SpanData{
+			Name:        name,
+			SpanContext: sc,
+			Parent:      parent,
+			Kind:        kind,
+			Start:       start,
+			Attributes:  append([]attribute.KeyValue(nil), cfg.Attributes()...),
+		},
+	}
+	return trace.ContextWithSpan(ctx, s), s
+}
+
+func newTraceID() trace.TraceID {
+	var id trace.TraceID
+	for id == (trace.TraceID{}) {
+		binary.BigEndian.PutUint64(id[0:8], rand.Uint64())
+		binary.BigEndian.PutUint64(id[8:16], rand.Uint64())
+	}
+	return id
+}
+
+func newSpanID() trace.SpanID {
+	var id trace.SpanID
+	for id == (trace.SpanID{}) {
+		binary.BigEndian.PutUint64(id[:], rand.Uint64())
+	}
+	return id
+}
+
+// recordingSpan 采样命中的 span，End 时快照写入导出队列
+type recordingSpan struct {
+	embedded.Span
+
+	tracer *tracer
+
+	mu    sync.Mutex
+	data  SpanData
+	ended bool
+}
+
+func (s *recordingSpan) SpanContext() trace.SpanContext { return s.data.SpanContext }
+
+func (s *recordingSpan) IsRecording() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return !s.ended
+}
+
+func (s *recordingSpan) End(options ...trace.SpanEndOption) {
+	cfg := trace.NewSpanEndConfig(options...)
+	end := cfg.Timestamp()
+	if end.IsZero() {
+		end = time.Now()
+	}
+	s.mu.Lock()
+	if s.ended {
+		s.mu.Unlock()
+		return
+	}
+	s.ended = true
+	s.data.End = end
+	data := s.data
+	s.mu.Unlock()
+	s.tracer.provider.enqueue(data)
+}
+
+func (s *recordingSpan) AddEvent(name string, options ...trace.EventOption) {
+	cfg := trace.NewEventConfig(options...)
+	ts := cfg.Timestamp()
+	if ts.IsZero() {
+		ts = time.Now()
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.ended {
+		return
+	}
+	s.data.Events = append(s.data.Events, Event{Name: name, Time: ts, Attributes: append([]attribute.KeyValue(nil), cfg.Attributes()...)})
+}
+
+// AddLink 未实现链接导出，忽略
+func (s *recordingSpan) AddLink(trace.Link) {}
+
+func (s *recordingSpan) RecordError(err error, options ...trace.EventOption) {
+	if err == nil {
+		return
+	}
+	options = append(options, trace.WithAttributes(
+		attribute.String("exception.message", err.Error()),
+	))
+	s.AddEvent("exception", options...)
+}
+
+func (s *recordingSpan) SetStatus(code codes.Code, description string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.ended {
+		return
+	}
+	// 与 OTel 语义一致：Unset 不生效，Ok 不可被覆盖，仅 Error 携带描述
+	if code == codes.Unset || s.data.Status == codes.Ok {
+		return
+	}
+	s.data.Status = code
+	s.data.StatusDescription = ""
+	if code == codes.Error {
+		s.data.StatusDescription = description
+	}
+}
+
+func (s *recordingSpan) SetName(name string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if !s.ended {
+		s.data.Name = name
+	}
+}
+
+func (s *recordingSpan) SetAttributes(kv ...attribute.KeyValue) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.ended {
+		return
+	}
+	for _, attr := range kv {
+		replaced := false
+		for i := range s.data.Attributes {
+			if s.data.Attributes[i].Key == attr.Key {
+				s.data.Attributes[i] = attr
+				replaced = true
+				break
+			}
+		}
+		if !replaced {
+			s.data.Attributes = append(s.data.Attributes, attr)
+		}
+	}
+}
+
+func (s *recordingSpan) TracerProvider() trace.TracerProvider { return s.tracer.provider }
+
+// nonRecordingSpan 未采样的 span：仅携带 span context 以继续向下游传播
+type nonRecordingSpan struct {
+	embedded.Span
+
+	sc       trace.SpanContext
+	provider *Provider
+}
+
+func (s *nonRecordingSpan) SpanContext() trace.SpanContext          { return s.sc }
+func (s *nonRecordingSpan) IsRecording() bool                       { return false }
+func (s *nonRecordingSpan) End(...trace.SpanEndOption)              {}
+func (s *nonRecordingSpan) AddEvent(string, ...trace.EventOption)   {}
+func (s *nonRecordingSpan) AddLink(trace.Link)                      {}
+func (s *nonRecordingSpan) RecordError(error, ...trace.EventOption) {}
+func (s *nonRecordingSpan) SetStatus(codes.Code, string)            {}
+func (s *nonRecordingSpan) SetName(string)                          {}
+func (s *nonRecordingSpan) SetAttributes(...attribute.KeyValue)     {}
+func (s *nonRecordingSpan) TracerProvider() trace.TracerProvider    { return s.provider }
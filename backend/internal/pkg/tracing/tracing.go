@@ -0,0 +1,139 @@
+// Package tracing 基于 OpenTelemetry API 的链路追踪：请求生命周期各阶段的 span、
+// W3C trace context 传播（入站提取、上游注入）与 OTLP/HTTP 导出。
+//
+// 未启用时全局 TracerProvider 为 OTel 默认的 noop 实现，埋点调用开销可以忽略。
+package tracing
+
+import (
+	"context"
+	"net/http"
+	"sync/atomic"
+	"time"
+
+	"github.com/ShaohongDong/sub2api/internal/pkg/logger"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/zap"
+)
+
+const instrumentationName = "github.com/ShaohongDong/sub2api"
+
+type InitOptions struct {
+	Enabled           bool
+	Endpoint          string
+	Headers           map[string]string
+	ServiceName       string
+	ServiceVersion    string
+	SampleRatio       float64
+	PropagateUpstream bool
+	QueueSize         int
+	BatchSize         int
+	ExportTimeout     time.Duration
+}
+
+var (
+	globalProvider    atomic.Pointer[Provider]
+	propagateUpstream atomic.Bool
+	propagator        = propagation.NewCompositeTextMapPropagator(propagation.TraceContext{}, propagation.Baggage{})
+)
+
+// Init 按配置安装全局 TracerProvider；未启用时不做任何事
+func Init(opts InitOptions) error {
+	if !opts.Enabled {
+		return nil
+	}
+	resource := []attribute.KeyValue{attribute.String("service.name", opts.ServiceName)}
+	if opts.ServiceVersion != "" {
+		resource = append(resource, attribute.String("service.version", opts.ServiceVersion))
+	}
+	exporter := NewOTLPExporter(opts.Endpoint, opts.Headers, resource, &http.Client{Timeout: opts.ExportTimeout})
+	p := NewProvider(ProviderOptions{
+		SampleRatio:   opts.SampleRatio,
+		QueueSize:     opts.QueueSize,
+		BatchSize:     opts.BatchSize,
+		ExportTimeout: opts.ExportTimeout,
+		OnExportError: func(err error, spans int) {
+			logger.L().Warn("tracing.export_failed", zap.Int("spans", spans), zap.Error(err))
+		},
+	}, exporter)
+	Install(p, opts.PropagateUpstream)
+	return nil
+}
+
+// Install 将 p 设为全局 TracerProvider（测试可直接传入自定义 Exporter 的 Provider）
+func Install(p *Provider, upstream bool) {
+	globalProvider.Store(p)
+	propagateUpstream.Store(upstream)
+	otel.SetTracerProvider(p)
+	otel.SetTextMapPropagator(propagator)
+}
+
+// Shutdown 导出剩余 span 并停止后台协程
+func Shutdown(ctx context.Context) error {
+	p := globalProvider.Swap(nil)
+	if p == nil {
+		return nil
+	}
+	return p.Shutdown(ctx)
+}
+
+// Tracer 返回本服务的 tracer
+func Tracer() trace.Tracer {
+	return otel.Tracer(instrumentationName)
+}
+
+// Start 开启 INTERNAL span
+func Start(ctx context.Context, name string, attrs ...attribute.KeyValue) (context.Context, trace.Span) {
+	return Tracer().Start(ctx, name, trace.WithAttributes(attrs...))
+}
+
+// End 结束 span，err 非空时记录异常事件并标记为错误
+func End(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
+
+// Extract 从入站请求头提取 traceparent/tracestate/baggage
+func Extract(ctx context.Context, header http.Header) context.Context {
+	return propagator.Extract(ctx, propagation.HeaderCarrier(header))
+}
+
+// StartHTTPClient 为上游 HTTP 请求开启 CLIENT span，并按配置向请求头注入 trace context。
+// 返回的请求携带 span 上下文；span 应在收到响应头后通过 EndHTTPClient 结束。
+func StartHTTPClient(req *http.Request, name string, attrs ...attribute.KeyValue) (*http.Request, trace.Span) {
+	attrs = append(attrs, attribute.String("http.request.method", req.Method))
+	if req.URL != nil {
+		attrs = append(attrs, attribute.String("server.address", req.URL.Hostname()), attribute.String("url.path", req.URL.Path))
+	}
+	ctx, span := Tracer().Start(req.Context(), name, trace.WithSpanKind(trace.SpanKindClient), trace.WithAttributes(attrs...))
+	if propagateUpstream.Load() && span.SpanContext().IsValid() {
+		propagator.Inject(ctx, propagation.HeaderCarrier(req.Header))
+	}
+	return req.WithContext(ctx), span
+}
+
+// EndHTTPClient 记录上游响应状态并结束 CLIENT span（4xx/5xx 视为错误）
+func EndHTTPClient(span trace.Span, resp *http.Response, err error) {
+	if err == nil && resp != nil {
+		span.SetAttributes(attribute.Int("http.response.status_code", resp.StatusCode))
+		if resp.StatusCode >= 400 {
+			span.SetStatus(codes.Error, http.StatusText(resp.StatusCode))
+		}
+	}
+	End(span, err)
+}
+
+// TraceID 返回 ctx 中已采样 span 的 trace id（用于日志关联），否则为空
+func TraceID(ctx context.Context) string {
+	sc := trace.SpanContextFromContext(ctx)
+	if !sc.IsValid() || !sc.IsSampled() {
+		return ""
+	}
+	return sc.TraceID().String()
+}
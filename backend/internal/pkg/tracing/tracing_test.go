@@ -0,0 +1,224 @@
+package tracing
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+	"go.opentelemetry.io/otel/trace/noop"
+)
+
+type memoryExporter struct {
+	mu    sync.Mutex
+	spans []SpanData
+}
+
+func (e *memoryExporter) ExportSpans(_ context.Context, spans []SpanData) error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.spans = append(e.spans, spans...)
+	return nil
+}
+
+func (e *memoryExporter) byName() map[string]SpanData {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	out := make(map[string]SpanData, len(e.spans))
+	for _, s := range e.spans {
+		out[s.Name] = s
+	}
+	return out
+}
+
+func installTestProvider(t *testing.T, ratio float64, upstream bool) (*Provider, *memoryExporter) {
+	t.Helper()
+	exp := &memoryExporter{}
+	p := NewProvider(ProviderOptions{SampleRatio: ratio, BatchSize: 2}, exp)
+	Install(p, upstream)
+	t.Cleanup(func() {
+		_ = Shutdown(context.Background())
+		propagateUpstream.Store(false)
+		otel.SetTracerProvider(noop.NewTracerProvider())
+	})
+	return p, exp
+}
+
+func TestProviderRecordsParentChildSpans(t *testing.T) {
+	p, exp := installTestProvider(t, 1, false)
+
+	ctx, root := Tracer().Start(context.Background(), "root", trace.WithSpanKind(trace.SpanKindServer))
+	_, child := Start(ctx, "child", attribute.String("k", "v"))
+	child.SetAttributes(attribute.String("k", "v2"), attribute.Int("n", 1))
+	End(child, errors.New("boom"))
+	child.SetName("ignored after end")
+	root.SetStatus(codes.Ok, "")
+	root.SetStatus(codes.Error, "cannot override ok")
+	root.End()
+	root.End()
+
+	require.NoError(t, p.ForceFlush(context.Background()))
+	spans := exp.byName()
+	require.Len(t, spans, 2)
+
+	rootData, childData := spans["root"], spans["child"]
+	require.False(t, rootData.Parent.IsValid())
+	require.Equal(t, trace.SpanKindServer, rootData.Kind)
+	require.Equal(t, codes.Ok, rootData.Status)
+	require.Equal(t, rootData.SpanContext.TraceID(), childData.SpanContext.TraceID())
+	require.Equal(t, rootData.SpanContext.SpanID(), childData.Parent.SpanID())
+	require.Equal(t, trace.SpanKindInternal, childData.Kind)
+	require.Equal(t, codes.Error, childData.Status)
+	require.Equal(t, "boom", childData.StatusDescription)
+	require.Equal(t, []attribute.KeyValue{attribute.String("k", "v2"), attribute.Int("n", 1)}, childData.Attributes)
+	require.Len(t, childData.Events, 1)
+	require.Equal(t, "exception", childData.Events[0].Name)
+	require.False(t, childData.End.Before(childData.Start))
+}
+
+func TestProviderSamplingFollowsRatioAndParent(t *testing.T) {
+	p, exp := installTestProvider(t, 0, false)
+
+	ctx, span := Start(context.Background(), "unsampled")
+	require.False(t, span.IsRecording())
+	require.True(t, span.SpanContext().IsValid(), "unsampled spans still carry a context for propagation")
+	require.Empty(t, TraceID(ctx))
+	span.End()
+
+	// 远端父 span 已采样时，即使本地比例为 0 也记录
+	remote := trace.NewSpanContext(trace.SpanContextConfig{
+		TraceID:    trace.TraceID{1},
+		SpanID:     trace.SpanID{2},
+		TraceFlags: trace.FlagsSampled,
+		Remote:     true,
+	})
+	ctx, span = Start(trace.ContextWithRemoteSpanContext(context.Background(), remote), "sampled-by-parent")
+	require.True(t, span.IsRecording())
+	require.Equal(t, remote.TraceID().String(), TraceID(ctx))
+	span.End()
+
+	require.NoError(t, p.ForceFlush(context.Background()))
+	spans := exp.byName()
+	require.Len(t, spans, 1)
+	require.Equal(t, remote.SpanID(), spans["sampled-by-parent"].Parent.SpanID())
+}
+
+func TestProviderDropsWhenQueueFullOrStopped(t *testing.T) {
+	blocked := make(chan struct{})
+	exp := exporterFunc(func(context.Context, []SpanData) error {
+		<-blocked
+		return nil
+	})
+	p := NewProvider(ProviderOptions{SampleRatio: 1, QueueSize: 1, BatchSize: 1}, exp)
+	tr := p.Tracer("test")
+	for i := 0; i < 10; i++ {
+		_, span := tr.Start(context.Background(), "s")
+		span.End()
+	}
+	require.Positive(t, p.Dropped())
+	close(blocked)
+	require.NoError(t, p.Shutdown(context.Background()))
+
+	before := p.Dropped()
+	_, span := tr.Start(context.Background(), "after-shutdown")
+	span.End()
+	require.Equal(t, before+1, p.Dropped())
+}
+
+type exporterFunc func(ctx context.Context, spans []SpanData) error
+
+func (f exporterFunc) ExportSpans(ctx context.Context, spans []SpanData) error { return f(ctx, spans) }
+
+func TestStartHTTPClientInjectsTraceContextWhenEnabled(t *testing.T) {
+	installTestProvider(t, 1, true)
+
+	ctx, parent := Start(context.Background(), "parent")
+	req := httptest.NewRequest(http.MethodPost, "https://api.example.com/v1/messages", nil).WithContext(ctx)
+	req, span := StartHTTPClient(req, "upstream.request")
+	require.Equal(t, trace.SpanContextFromContext(req.Context()), span.SpanContext())
+	require.Equal(t, "00-"+span.SpanContext().TraceID().String()+"-"+span.SpanContext().SpanID().String()+"-01", req.Header.Get("traceparent"))
+	EndHTTPClient(span, &http.Response{StatusCode: http.StatusTooManyRequests}, nil)
+	parent.End()
+
+	propagateUpstream.Store(false)
+	req = httptest.NewRequest(http.MethodPost, "https://api.example.com/v1/messages", nil).WithContext(ctx)
+	_, span = StartHTTPClient(req, "upstream.request")
+	span.End()
+	require.Empty(t, req.Header.Get("traceparent"))
+}
+
+func TestExtractReadsTraceparent(t *testing.T) {
+	installTestProvider(t, 1, false)
+
+	header := http.Header{}
+	header.Set("traceparent", "00-0af7651916cd43dd8448eb211c80319c-b7ad6b7169203331-01")
+	sc := trace.SpanContextFromContext(Extract(context.Background(), header))
+	require.True(t, sc.IsRemote())
+	require.Equal(t, "0af7651916cd43dd8448eb211c80319c", sc.TraceID().String())
+	require.True(t, sc.IsSampled())
+}
+
+func TestOTLPExporterPostsJSON(t *testing.T) {
+	var (
+		gotHeader http.Header
+		gotBody   map[string]any
+	)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotHeader = r.Header.Clone()
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&gotBody))
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer srv.Close()
+
+	start := time.Unix(1700000000, 5)
+	sc := trace.NewSpanContext(trace.SpanContextConfig{TraceID: trace.TraceID{0xab}, SpanID: trace.SpanID{0xcd}, TraceFlags: trace.FlagsSampled})
+	parent := trace.NewSpanContext(trace.SpanContextConfig{TraceID: trace.TraceID{0xab}, SpanID: trace.SpanID{0xef}})
+	exp := NewOTLPExporter(srv.URL, map[string]string{"Authorization": "Bearer x"}, []attribute.KeyValue{attribute.String("service.name", "sub2api")}, srv.Client())
+	err := exp.ExportSpans(context.Background(), []SpanData{{
+		Name:        "upstream.request",
+		SpanContext: sc,
+		Parent:      parent,
+		Kind:        trace.SpanKindClient,
+		Start:       start,
+		End:         start.Add(time.Second),
+		Attributes:  []attribute.KeyValue{attribute.Int64("sub2api.account_id", 42), attribute.Bool("ok", false), attribute.StringSlice("tags", []string{"a"})},
+		Status:      codes.Error,
+	}})
+	require.NoError(t, err)
+	require.Equal(t, "application/json", gotHeader.Get("Content-Type"))
+	require.Equal(t, "Bearer x", gotHeader.Get("Authorization"))
+
+	rs := gotBody["resourceSpans"].([]any)[0].(map[string]any)
+	require.Equal(t, "service.name", rs["resource"].(map[string]any)["attributes"].([]any)[0].(map[string]any)["key"])
+	span := rs["scopeSpans"].([]any)[0].(map[string]any)["spans"].([]any)[0].(map[string]any)
+	require.Equal(t, sc.TraceID().String(), span["traceId"])
+	require.Equal(t, parent.SpanID().String(), span["parentSpanId"])
+	require.Equal(t, float64(3), span["kind"])
+	require.Equal(t, "1700000000000000005", span["startTimeUnixNano"])
+	require.Equal(t, float64(2), span["status"].(map[string]any)["code"])
+	attrs := span["attributes"].([]any)
+	require.Equal(t, map[string]any{"intValue": "42"}, attrs[0].(map[string]any)["value"])
+	require.Equal(t, map[string]any{"boolValue": false}, attrs[1].(map[string]any)["value"])
+	require.Equal(t, map[string]any{"arrayValue": map[string]any{"values": []any{map[string]any{"stringValue": "a"}}}}, attrs[2].(map[string]any)["value"])
+}
+
+func TestOTLPExporterReportsHTTPErrors(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "bad payload", http.StatusBadRequest)
+	}))
+	defer srv.Close()
+
+	exp := NewOTLPExporter(srv.URL, nil, nil, srv.Client())
+	err := exp.ExportSpans(context.Background(), []SpanData{{Name: "x"}})
+	require.ErrorContains(t, err, "400")
+	require.ErrorContains(t, err, "bad payload")
+}
@@ -91,6 +91,84 @@ type GroupStat struct {
 	ActualCost  float64 `json:"actual_cost"` // 实际扣除
 }
 
+// ClientTypeUsageRow 按记录的客户端类型聚合的用量与错误数（用于客户端类型统计）。
+// ClientType 为空表示该列出现之前的历史数据，此时按原始 UserAgent 分组。
+type ClientTypeUsageRow struct {
+	ClientType          string  `json:"client_type"`
+	UserAgent           string  `json:"user_agent,omitempty"`
+	Requests            int64   `json:"requests"`
+	Errors              int64   `json:"errors"`
+	InputTokens         int64   `json:"input_tokens"`
+	OutputTokens        int64   `json:"output_tokens"`
+	CacheCreationTokens int64   `json:"cache_creation_tokens"`
+	CacheReadTokens     int64   `json:"cache_read_tokens"`
+	TotalTokens         int64   `json:"total_tokens"`
+	Cost                float64 `json:"cost"`
+	ActualCost          float64 `json:"actual_cost"`
+}
+
+// ClientTypeStat 按客户端类型（clientdetect.ClientType）聚合的用量统计
+type ClientTypeStat struct {
+	ClientType          string  `json:"client_type"`
+	Requests            int64   `json:"requests"`      // 成功请求数（usage_logs）
+	Errors              int64   `json:"errors"`        // 失败请求数（ops_error_logs，不含业务限流）
+	ErrorRate           float64 `json:"error_rate"`    // errors / (requests + errors)
+	RequestShare        float64 `json:"request_share"` // 占全部成功请求的比例
+	InputTokens         int64   `json:"input_tokens"`
+	OutputTokens        int64   `json:"output_tokens"`
+	CacheCreationTokens int64   `json:"cache_creation_tokens"`
+	CacheReadTokens     int64   `json:"cache_read_tokens"`
+	TotalTokens         int64   `json:"total_tokens"`
+	Cost                float64 `json:"cost"`        // 标准计费
+	ActualCost          float64 `json:"actual_cost"` // 实际扣除
+}
+
+// DashboardOverview 仪表盘概览：今日核心指标与 Top 模型/API Key，由服务端一次性聚合
+type DashboardOverview struct {
+	GeneratedAt string `json:"generated_at"`
+	Date        string `json:"date"` // 今日日期（服务端时区）
+
+	Requests            int64   `json:"requests"`   // 今日成功请求数
+	Errors              int64   `json:"errors"`     // 今日失败请求数（ops_error_logs，不含业务限流）
+	ErrorRate           float64 `json:"error_rate"` // errors / (requests + errors)
+	InputTokens         int64   `json:"input_tokens"`
+	OutputTokens        int64   `json:"output_tokens"`
+	CacheCreationTokens int64   `json:"cache_creation_tokens"`
+	CacheReadTokens     int64   `json:"cache_read_tokens"`
+	TotalTokens         int64   `json:"total_tokens"`
+	Cost                float64 `json:"cost"`        // 标准计费
+	ActualCost          float64 `json:"actual_cost"` // 实际扣除
+
+	ActiveAccounts int64 `json:"active_accounts"` // 正常可调度账号数
+	TotalAccounts  int64 `json:"total_accounts"`
+	ActiveUsers    int64 `json:"active_users"` // 今日有请求的用户数
+	Rpm            int64 `json:"rpm"`
+	Tpm            int64 `json:"tpm"`
+	StatsStale     bool  `json:"stats_stale"` // 预聚合数据是否滞后
+
+	TopModels  []DashboardOverviewModel  `json:"top_models"`
+	TopAPIKeys []DashboardOverviewAPIKey `json:"top_api_keys"`
+}
+
+// DashboardOverviewModel 概览中的 Top 模型（按 Token 总量降序）
+type DashboardOverviewModel struct {
+	Model       string  `json:"model"`
+	Requests    int64   `json:"requests"`
+	TotalTokens int64   `json:"total_tokens"`
+	Cost        float64 `json:"cost"`
+	ActualCost  float64 `json:"actual_cost"`
+}
+
+// DashboardOverviewAPIKey 概览中的 Top API Key（按 Token 总量降序）
+type DashboardOverviewAPIKey struct {
+	APIKeyID    int64   `json:"api_key_id"`
+	APIKeyName  string  `json:"api_key_name"`
+	Requests    int64   `json:"requests"`
+	TotalTokens int64   `json:"total_tokens"`
+	Cost        float64 `json:"cost"`
+	ActualCost  float64 `json:"actual_cost"`
+}
+
 // UserUsageTrendPoint represents user usage trend data point
 type UserUsageTrendPoint struct {
 	Date       string  `json:"date"`
@@ -111,6 +189,82 @@ type APIKeyUsageTrendPoint struct {
 	Tokens   int64  `json:"tokens"`
 }
 
+// Usage aggregate dimensions（UsageAggregateQuery.GroupBy 可选值）
+const (
+	UsageAggregateByAPIKey  = "api_key"
+	UsageAggregateByModel   = "model"
+	UsageAggregateByAccount = "account"
+	UsageAggregateByUser    = "user"
+	UsageAggregateByGroup   = "group"
+)
+
+// UsageAggregateQuery 按任意维度组合与时间桶聚合用量的查询条件
+type UsageAggregateQuery struct {
+	StartTime time.Time
+	EndTime   time.Time
+	// GroupBy 分组维度（api_key/model/account/user/group），可为空（仅按时间桶或整体汇总）
+	GroupBy []string
+	// Granularity 时间桶粒度（hour/day/week/month），为空时不分桶
+	Granularity string
+	UserID      int64
+	APIKeyID    int64
+	AccountID   int64
+	GroupID     int64
+	Model       string
+	Limit       int
+}
+
+// UsageAggregate 单个分组的聚合用量；未参与分组的维度字段为零值并在 JSON 中省略
+type UsageAggregate struct {
+	Bucket              string  `json:"bucket,omitempty"`
+	APIKeyID            int64   `json:"api_key_id,omitempty"`
+	APIKeyName          string  `json:"api_key_name,omitempty"`
+	Model               string  `json:"model,omitempty"`
+	AccountID           int64   `json:"account_id,omitempty"`
+	AccountName         string  `json:"account_name,omitempty"`
+	UserID              int64   `json:"user_id,omitempty"`
+	UserEmail           string  `json:"user_email,omitempty"`
+	GroupID             int64   `json:"group_id,omitempty"`
+	GroupName           string  `json:"group_name,omitempty"`
+	Requests            int64   `json:"requests"`
+	InputTokens         int64   `json:"input_tokens"`
+	OutputTokens        int64   `json:"output_tokens"`
+	ReasoningTokens     int64   `json:"reasoning_tokens"` // 已包含在 output_tokens 中
+	CacheCreationTokens int64   `json:"cache_creation_tokens"`
+	CacheReadTokens     int64   `json:"cache_read_tokens"`
+	TotalTokens         int64   `json:"total_tokens"`
+	Cost                float64 `json:"cost"`         // 标准计费
+	ActualCost          float64 `json:"actual_cost"`  // 实际扣除（用户侧倍率）
+	AccountCost         float64 `json:"account_cost"` // 账号成本（账号倍率）
+}
+
+// UsageExportRow 用量明细导出的单条请求记录（分摊对账）
+type UsageExportRow struct {
+	ID                  int64     `json:"id"`
+	CreatedAt           time.Time `json:"created_at"`
+	RequestID           string    `json:"request_id"`
+	UserID              int64     `json:"user_id"`
+	UserEmail           string    `json:"user_email"`
+	APIKeyID            int64     `json:"api_key_id"`
+	APIKeyName          string    `json:"api_key_name"`
+	AccountID           int64     `json:"account_id"`
+	AccountName         string    `json:"account_name"`
+	GroupID             int64     `json:"group_id"`
+	GroupName           string    `json:"group_name"`
+	Model               string    `json:"model"`
+	Stream              bool      `json:"stream"`
+	InputTokens         int64     `json:"input_tokens"`
+	OutputTokens        int64     `json:"output_tokens"`
+	ReasoningTokens     int64     `json:"reasoning_tokens"`
+	CacheCreationTokens int64     `json:"cache_creation_tokens"`
+	CacheReadTokens     int64     `json:"cache_read_tokens"`
+	TotalTokens         int64     `json:"total_tokens"`
+	Cost                float64   `json:"cost"`
+	ActualCost          float64   `json:"actual_cost"`
+	AccountCost         float64   `json:"account_cost"`
+	DurationMs          int64     `json:"duration_ms"`
+}
+
 // UserDashboardStats 用户仪表盘统计
 type UserDashboardStats struct {
 	// API Key 统计
@@ -151,6 +305,7 @@ type UsageLogFilters struct {
 	APIKeyID    int64
 	AccountID   int64
 	GroupID     int64
+	TenantID    int64 // 按用户所属租户过滤
 	Model       string
 	RequestType *int16
 	Stream      *bool
@@ -242,3 +397,31 @@ type AccountUsageStatsResponse struct {
 	Summary AccountUsageSummary   `json:"summary"`
 	Models  []ModelStat           `json:"models"`
 }
+
+// AccountUsageBucket represents an account's usage within one hour or day bucket.
+// Requests counts successful requests (usage_logs); Errors counts failed requests
+// recorded in ops_error_logs, excluding business-limited rejections.
+type AccountUsageBucket struct {
+	Bucket              string     `json:"bucket"`
+	Requests            int64      `json:"requests"`
+	Errors              int64      `json:"errors"`
+	ErrorRate           float64    `json:"error_rate"` // errors / (requests + errors)
+	InputTokens         int64      `json:"input_tokens"`
+	OutputTokens        int64      `json:"output_tokens"`
+	ReasoningTokens     int64      `json:"reasoning_tokens"` // 已包含在 output_tokens 中
+	CacheCreationTokens int64      `json:"cache_creation_tokens"`
+	CacheReadTokens     int64      `json:"cache_read_tokens"`
+	Cost                float64    `json:"cost"` // 账号口径费用（total_cost * account_rate_multiplier）
+	LastUsedAt          *time.Time `json:"last_used_at,omitempty"`
+}
+
+// AccountUsageBucketsResponse represents bucketed usage statistics for an account
+type AccountUsageBucketsResponse struct {
+	AccountID   int64                `json:"account_id"`
+	Granularity string               `json:"granularity"`
+	StartTime   time.Time            `json:"start_time"`
+	EndTime     time.Time            `json:"end_time"`
+	Buckets     []AccountUsageBucket `json:"buckets"`
+	Totals      AccountUsageBucket   `json:"totals"`
+	LastUsedAt  *time.Time           `json:"last_used_at,omitempty"`
+}
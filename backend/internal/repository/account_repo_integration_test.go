@@ -9,6 +9,7 @@ import (
 
 	dbent "github.com/ShaohongDong/sub2api/ent"
 	"github.com/ShaohongDong/sub2api/ent/accountgroup"
+	"github.com/ShaohongDong/sub2api/internal/config"
 	"github.com/ShaohongDong/sub2api/internal/pkg/pagination"
 	"github.com/ShaohongDong/sub2api/internal/service"
 	"github.com/stretchr/testify/suite"
@@ -101,6 +102,38 @@ func (s *AccountRepoSuite) TestCreate() {
 	s.Require().Equal("test-create", got.Name)
 }
 
+func (s *AccountRepoSuite) TestCreate_EncryptsCredentialsAtRest() {
+	cipher, err := NewCredentialCipher(&config.Config{CredentialEncryption: config.CredentialEncryptionConfig{
+		Key:    "000102030405060708090a0b0c0d0e0f101112131415161718191a1b1c1d1e1f",
+		KeyID:  "t1",
+		Fields: []string{"refresh_token"},
+	}})
+	s.Require().NoError(err)
+	s.repo.cipher = cipher
+	defer func() { s.repo.cipher = nil }()
+
+	account := &service.Account{
+		Name:        "test-encrypted",
+		Platform:    service.PlatformOpenAI,
+		Type:        service.AccountTypeOAuth,
+		Status:      service.StatusActive,
+		Credentials: map[string]any{"refresh_token": "rt-secret", "email": "a@example.com"},
+		Extra:       map[string]any{},
+		Concurrency: 1,
+		Schedulable: true,
+	}
+	s.Require().NoError(s.repo.Create(s.ctx, account))
+
+	raw, err := s.client.Account.Get(s.ctx, account.ID)
+	s.Require().NoError(err)
+	s.Require().Contains(raw.Credentials["refresh_token"], "enc:v1:t1:")
+	s.Require().Equal("a@example.com", raw.Credentials["email"])
+
+	got, err := s.repo.GetByID(s.ctx, account.ID)
+	s.Require().NoError(err)
+	s.Require().Equal("rt-secret", got.Credentials["refresh_token"])
+}
+
 func (s *AccountRepoSuite) TestGetByID_NotFound() {
 	_, err := s.repo.GetByID(s.ctx, 999999)
 	s.Require().Error(err, "expected error for non-existent ID")
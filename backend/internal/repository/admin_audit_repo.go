@@ -0,0 +1,130 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"strings"
+
+	"github.com/ShaohongDong/sub2api/internal/service"
+)
+
+type adminAuditRepository struct {
+	db *sql.DB
+}
+
+func NewAdminAuditRepository(db *sql.DB) service.AdminAuditRepository {
+	return &adminAuditRepository{db: db}
+}
+
+func (r *adminAuditRepository) Insert(ctx context.Context, entry *service.AdminAuditLog) error {
+	var changes any
+	if len(entry.Changes) > 0 {
+		raw, err := json.Marshal(entry.Changes)
+		if err != nil {
+			return err
+		}
+		changes = string(raw)
+	}
+	return r.db.QueryRowContext(ctx, `
+		INSERT INTO admin_audit_logs (
+			actor_user_id, actor_role, auth_method, method, route, path, resource_type, resource_id,
+			status_code, client_ip, request_id, request_body, changes, created_at
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13::jsonb, NOW())
+		RETURNING id, created_at
+	`, entry.ActorUserID, entry.ActorRole, entry.AuthMethod, entry.Method, entry.Route, entry.Path,
+		entry.ResourceType, entry.ResourceID, entry.StatusCode, entry.ClientIP, entry.RequestID,
+		entry.RequestBody, changes,
+	).Scan(&entry.ID, &entry.CreatedAt)
+}
+
+func (r *adminAuditRepository) List(ctx context.Context, filter *service.AdminAuditFilter) (*service.AdminAuditLogList, error) {
+	page := filter.Page
+	if page <= 0 {
+		page = 1
+	}
+	pageSize := filter.PageSize
+	if pageSize <= 0 {
+		pageSize = 50
+	}
+	if pageSize > service.AdminAuditMaxExportRows {
+		pageSize = service.AdminAuditMaxExportRows
+	}
+
+	where, args := buildAdminAuditWhere(filter)
+	var total int64
+	if err := r.db.QueryRowContext(ctx, "SELECT COUNT(*) FROM admin_audit_logs l "+where, args...).Scan(&total); err != nil {
+		return nil, err
+	}
+
+	query := `
+SELECT
+  l.id, l.actor_user_id, COALESCE(u.email, ''), l.actor_role, l.auth_method, l.method, l.route, l.path,
+  l.resource_type, l.resource_id, l.status_code, l.client_ip, l.request_id, l.request_body,
+  COALESCE(l.changes::text, ''), l.created_at
+FROM admin_audit_logs l
+LEFT JOIN users u ON u.id = l.actor_user_id
+` + where + `
+ORDER BY l.created_at DESC, l.id DESC
+LIMIT $` + itoa(len(args)+1) + ` OFFSET $` + itoa(len(args)+2)
+
+	rows, err := r.db.QueryContext(ctx, query, append(args, pageSize, (page-1)*pageSize)...)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = rows.Close() }()
+
+	items := make([]*service.AdminAuditLog, 0, pageSize)
+	for rows.Next() {
+		entry := &service.AdminAuditLog{}
+		var changes string
+		if err := rows.Scan(
+			&entry.ID, &entry.ActorUserID, &entry.ActorEmail, &entry.ActorRole, &entry.AuthMethod, &entry.Method,
+			&entry.Route, &entry.Path, &entry.ResourceType, &entry.ResourceID, &entry.StatusCode, &entry.ClientIP,
+			&entry.RequestID, &entry.RequestBody, &changes, &entry.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		if changes != "" {
+			if err := json.Unmarshal([]byte(changes), &entry.Changes); err != nil {
+				return nil, err
+			}
+		}
+		items = append(items, entry)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return &service.AdminAuditLogList{Items: items, Total: total, Page: page, PageSize: pageSize}, nil
+}
+
+func buildAdminAuditWhere(filter *service.AdminAuditFilter) (string, []any) {
+	clauses := make([]string, 0, 6)
+	args := make([]any, 0, 6)
+	add := func(clause string, arg any) {
+		args = append(args, arg)
+		clauses = append(clauses, strings.ReplaceAll(clause, "?", "$"+itoa(len(args))))
+	}
+	if filter.StartTime != nil {
+		add("l.created_at >= ?", *filter.StartTime)
+	}
+	if filter.EndTime != nil {
+		add("l.created_at < ?", *filter.EndTime)
+	}
+	if filter.ActorUserID > 0 {
+		add("l.actor_user_id = ?", filter.ActorUserID)
+	}
+	if filter.Method != "" {
+		add("l.method = ?", filter.Method)
+	}
+	if filter.ResourceType != "" {
+		add("l.resource_type = ?", filter.ResourceType)
+	}
+	if filter.ResourceID != "" {
+		add("l.resource_id = ?", filter.ResourceID)
+	}
+	if len(clauses) == 0 {
+		return "", args
+	}
+	return "WHERE " + strings.Join(clauses, " AND "), args
+}
@@ -0,0 +1,71 @@
+package repository
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/ShaohongDong/sub2api/internal/service"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAdminAuditRepositoryInsert(t *testing.T) {
+	db, mock := newSQLMock(t)
+	repo := NewAdminAuditRepository(db)
+
+	createdAt := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	entry := &service.AdminAuditLog{
+		ActorUserID:  1,
+		ActorRole:    service.RoleAdmin,
+		AuthMethod:   "jwt",
+		Method:       "PUT",
+		Route:        "/api/v1/admin/accounts/:id",
+		Path:         "/api/v1/admin/accounts/9",
+		ResourceType: "accounts",
+		ResourceID:   "9",
+		StatusCode:   200,
+		Changes:      map[string]service.AdminAuditChange{"name": {Before: "a", After: "b"}},
+	}
+	mock.ExpectQuery("INSERT INTO admin_audit_logs").
+		WithArgs(int64(1), service.RoleAdmin, "jwt", "PUT", entry.Route, entry.Path, "accounts", "9", 200, "", "", "",
+			`{"name":{"before":"a","after":"b"}}`).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "created_at"}).AddRow(int64(7), createdAt))
+
+	require.NoError(t, repo.Insert(context.Background(), entry))
+	require.Equal(t, int64(7), entry.ID)
+	require.Equal(t, createdAt, entry.CreatedAt)
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestAdminAuditRepositoryListFilters(t *testing.T) {
+	db, mock := newSQLMock(t)
+	repo := NewAdminAuditRepository(db)
+
+	start := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	mock.ExpectQuery(`SELECT COUNT\(\*\) FROM admin_audit_logs l WHERE l.created_at >= \$1 AND l.actor_user_id = \$2 AND l.resource_type = \$3`).
+		WithArgs(start, int64(1), "accounts").
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(int64(1)))
+	mock.ExpectQuery(`FROM admin_audit_logs l\s+LEFT JOIN users u .* LIMIT \$4 OFFSET \$5`).
+		WithArgs(start, int64(1), "accounts", 20, 20).
+		WillReturnRows(sqlmock.NewRows([]string{
+			"id", "actor_user_id", "email", "actor_role", "auth_method", "method", "route", "path",
+			"resource_type", "resource_id", "status_code", "client_ip", "request_id", "request_body", "changes", "created_at",
+		}).AddRow(int64(3), int64(1), "admin@example.com", "admin", "jwt", "DELETE", "/api/v1/admin/accounts/:id",
+			"/api/v1/admin/accounts/5", "accounts", "5", 200, "127.0.0.1", "req-1", "",
+			`{"name":{"before":"old","after":null}}`, start))
+
+	result, err := repo.List(context.Background(), &service.AdminAuditFilter{
+		StartTime:    &start,
+		ActorUserID:  1,
+		ResourceType: "accounts",
+		Page:         2,
+		PageSize:     20,
+	})
+	require.NoError(t, err)
+	require.Equal(t, int64(1), result.Total)
+	require.Len(t, result.Items, 1)
+	require.Equal(t, "admin@example.com", result.Items[0].ActorEmail)
+	require.Equal(t, service.AdminAuditChange{Before: "old", After: nil}, result.Items[0].Changes["name"])
+	require.NoError(t, mock.ExpectationsWereMet())
+}
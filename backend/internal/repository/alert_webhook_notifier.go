@@ -0,0 +1,166 @@
+package repository
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/ShaohongDong/sub2api/internal/config"
+	"github.com/ShaohongDong/sub2api/internal/pkg/httpclient"
+	"github.com/ShaohongDong/sub2api/internal/service"
+)
+
+const (
+	alertWebhookDefaultTemplate = "[{{.Severity}}] {{.Title}}\n{{.Message}}"
+	alertTelegramDefaultBaseURL = "https://api.telegram.org"
+
+	// 各渠道单条消息的长度上限
+	alertDiscordMaxContent = 2000
+	alertTelegramMaxText   = 4096
+)
+
+type alertWebhookTarget struct {
+	cfg       config.AlertWebhookTarget
+	events    map[string]bool
+	template  *template.Template
+	templates map[string]*template.Template
+}
+
+type alertWebhookNotifier struct {
+	httpClient *http.Client
+	targets    []*alertWebhookTarget
+}
+
+// NewAlertWebhookNotifier 创建运维告警 webhook 通知器；未启用 alert_webhooks 或没有目标时返回 nil。
+// 地址只能由管理员在配置文件中设置，因此允许指向内网服务。
+func NewAlertWebhookNotifier(cfg *config.Config) service.AlertNotifier {
+	if cfg == nil || !cfg.AlertWebhooks.Enabled || len(cfg.AlertWebhooks.Targets) == 0 {
+		return nil
+	}
+	timeout := time.Duration(cfg.AlertWebhooks.TimeoutSeconds) * time.Second
+	if timeout <= 0 {
+		timeout = 10 * time.Second
+	}
+	sharedClient, err := httpclient.GetClient(httpclient.Options{Timeout: timeout})
+	if err != nil {
+		sharedClient = &http.Client{Timeout: timeout}
+	}
+
+	n := &alertWebhookNotifier{httpClient: sharedClient}
+	for _, targetCfg := range cfg.AlertWebhooks.Targets {
+		target := &alertWebhookTarget{cfg: targetCfg, templates: make(map[string]*template.Template)}
+		if len(targetCfg.Events) > 0 {
+			target.events = make(map[string]bool, len(targetCfg.Events))
+			for _, event := range targetCfg.Events {
+				target.events[event] = true
+			}
+		}
+		// 模板已在配置校验阶段解析过，这里的错误不会发生
+		text := targetCfg.Template
+		if strings.TrimSpace(text) == "" && targetCfg.Type != config.AlertWebhookTypeGeneric {
+			text = alertWebhookDefaultTemplate
+		}
+		if strings.TrimSpace(text) != "" {
+			target.template, _ = config.ParseAlertWebhookTemplate(text)
+		}
+		for event, text := range targetCfg.Templates {
+			if tmpl, err := config.ParseAlertWebhookTemplate(text); err == nil {
+				target.templates[event] = tmpl
+			}
+		}
+		n.targets = append(n.targets, target)
+	}
+	return n
+}
+
+// Notify 将事件发送到订阅了该事件类型的所有目标，返回各目标的错误汇总
+func (n *alertWebhookNotifier) Notify(ctx context.Context, event *service.AlertEvent) error {
+	var errs []error
+	for _, target := range n.targets {
+		if target.events != nil && !target.events[event.Type] {
+			continue
+		}
+		if err := n.send(ctx, target, event); err != nil {
+			name := target.cfg.Name
+			if name == "" {
+				name = target.cfg.Type
+			}
+			errs = append(errs, fmt.Errorf("%s: %w", name, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func (n *alertWebhookNotifier) send(ctx context.Context, target *alertWebhookTarget, event *service.AlertEvent) error {
+	tmpl := target.template
+	if t, ok := target.templates[event.Type]; ok {
+		tmpl = t
+	}
+	text := ""
+	if tmpl != nil {
+		var buf bytes.Buffer
+		if err := tmpl.Execute(&buf, event); err != nil {
+			return fmt.Errorf("render template: %w", err)
+		}
+		text = buf.String()
+	}
+
+	url := target.cfg.URL
+	var payload []byte
+	var err error
+	switch target.cfg.Type {
+	case config.AlertWebhookTypeSlack:
+		payload, err = json.Marshal(map[string]any{"text": text})
+	case config.AlertWebhookTypeDiscord:
+		payload, err = json.Marshal(map[string]any{"content": truncateAlertText(text, alertDiscordMaxContent)})
+	case config.AlertWebhookTypeTelegram:
+		base := strings.TrimRight(strings.TrimSpace(url), "/")
+		if base == "" {
+			base = alertTelegramDefaultBaseURL
+		}
+		url = base + "/bot" + target.cfg.TelegramBotToken + "/sendMessage"
+		payload, err = json.Marshal(map[string]any{
+			"chat_id":                  target.cfg.TelegramChatID,
+			"text":                     truncateAlertText(text, alertTelegramMaxText),
+			"disable_web_page_preview": true,
+		})
+	default:
+		// generic：配置了模板时渲染结果即请求体，否则发送事件 JSON
+		if tmpl != nil {
+			payload = []byte(text)
+		} else {
+			payload, err = json.Marshal(event)
+		}
+	}
+	if err != nil {
+		return fmt.Errorf("marshal payload: %w", err)
+	}
+
+	// 仅 generic 目标签名，Slack / Discord / Telegram 不校验签名
+	secret := ""
+	if target.cfg.Type == config.AlertWebhookTypeGeneric {
+		secret = target.cfg.Secret
+	}
+	if err := postSignedJSON(ctx, n.httpClient, url, secret, payload); err != nil {
+		if token := target.cfg.TelegramBotToken; token != "" {
+			// 错误信息中的 URL 包含 Telegram bot token
+			return errors.New(strings.ReplaceAll(err.Error(), token, "***"))
+		}
+		return err
+	}
+	return nil
+}
+
+func truncateAlertText(text string, maxRunes int) string {
+	runes := []rune(text)
+	if len(runes) <= maxRunes {
+		return text
+	}
+	return string(runes[:maxRunes-1]) + "…"
+}
@@ -0,0 +1,130 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"sync"
+	"testing"
+
+	"github.com/ShaohongDong/sub2api/internal/config"
+	"github.com/ShaohongDong/sub2api/internal/service"
+	"github.com/stretchr/testify/require"
+)
+
+type capturedAlertRequest struct {
+	path      string
+	body      []byte
+	signature string
+}
+
+func newTestAlertWebhookNotifier(t *testing.T, targets []config.AlertWebhookTarget) (*alertWebhookNotifier, func() map[string]capturedAlertRequest) {
+	t.Helper()
+	cfg := &config.Config{AlertWebhooks: config.AlertWebhooksConfig{Enabled: true, TimeoutSeconds: 5, Targets: targets}}
+	notifier, ok := NewAlertWebhookNotifier(cfg).(*alertWebhookNotifier)
+	require.True(t, ok, "type assertion failed")
+
+	var mu sync.Mutex
+	captured := make(map[string]capturedAlertRequest)
+	notifier.httpClient = &http.Client{Transport: newInProcessTransport(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		mu.Lock()
+		captured[r.Host] = capturedAlertRequest{path: r.URL.Path, body: body, signature: r.Header.Get(webhookSignatureHeader)}
+		mu.Unlock()
+		w.WriteHeader(http.StatusOK)
+	}, nil)}
+	return notifier, func() map[string]capturedAlertRequest {
+		mu.Lock()
+		defer mu.Unlock()
+		return captured
+	}
+}
+
+func testAlertEvent() *service.AlertEvent {
+	return &service.AlertEvent{
+		Type:     service.AlertEventAccountDisabled,
+		Severity: service.AlertSeverityCritical,
+		Title:    "Account disabled: acc-1",
+		Message:  "401 Unauthorized",
+		Fields:   map[string]any{"account_id": 1},
+	}
+}
+
+func TestNewAlertWebhookNotifier_Disabled(t *testing.T) {
+	require.Nil(t, NewAlertWebhookNotifier(nil))
+	require.Nil(t, NewAlertWebhookNotifier(&config.Config{}))
+	require.Nil(t, NewAlertWebhookNotifier(&config.Config{AlertWebhooks: config.AlertWebhooksConfig{Enabled: true}}))
+}
+
+func TestAlertWebhookNotifier_ChannelPayloads(t *testing.T) {
+	notifier, captured := newTestAlertWebhookNotifier(t, []config.AlertWebhookTarget{
+		{Name: "slack", Type: config.AlertWebhookTypeSlack, URL: "http://slack.local/hook"},
+		{Name: "discord", Type: config.AlertWebhookTypeDiscord, URL: "http://discord.local/hook"},
+		{Name: "tg", Type: config.AlertWebhookTypeTelegram, URL: "http://telegram.local", TelegramBotToken: "123:abc", TelegramChatID: "-100"},
+		{Name: "generic", Type: config.AlertWebhookTypeGeneric, URL: "http://generic.local/hook", Secret: "s3cret"},
+	})
+
+	require.NoError(t, notifier.Notify(context.Background(), testAlertEvent()))
+	got := captured()
+	require.Len(t, got, 4)
+
+	var slack map[string]string
+	require.NoError(t, json.Unmarshal(got["slack.local"].body, &slack))
+	require.Equal(t, "[critical] Account disabled: acc-1\n401 Unauthorized", slack["text"])
+
+	var discord map[string]string
+	require.NoError(t, json.Unmarshal(got["discord.local"].body, &discord))
+	require.Equal(t, slack["text"], discord["content"])
+
+	require.Equal(t, "/bot123:abc/sendMessage", got["telegram.local"].path)
+	var tg map[string]any
+	require.NoError(t, json.Unmarshal(got["telegram.local"].body, &tg))
+	require.Equal(t, "-100", tg["chat_id"])
+	require.Equal(t, slack["text"], tg["text"])
+
+	generic := got["generic.local"]
+	var event map[string]any
+	require.NoError(t, json.Unmarshal(generic.body, &event))
+	require.Equal(t, service.AlertEventAccountDisabled, event["event"])
+	require.Equal(t, float64(1), event["fields"].(map[string]any)["account_id"])
+	require.Equal(t, signWebhookPayload("s3cret", generic.body), generic.signature)
+	require.Empty(t, got["slack.local"].signature, "only generic targets are signed")
+}
+
+func TestAlertWebhookNotifier_RedactsTelegramTokenInErrors(t *testing.T) {
+	notifier, _ := newTestAlertWebhookNotifier(t, []config.AlertWebhookTarget{
+		{Name: "tg", Type: config.AlertWebhookTypeTelegram, URL: "http://telegram.local", TelegramBotToken: "123:abc", TelegramChatID: "-100"},
+	})
+	notifier.httpClient = &http.Client{Transport: roundTripFunc(func(*http.Request) (*http.Response, error) {
+		return nil, errors.New("connection refused")
+	})}
+
+	err := notifier.Notify(context.Background(), testAlertEvent())
+	require.ErrorContains(t, err, "connection refused")
+	require.NotContains(t, err.Error(), "123:abc")
+}
+
+func TestAlertWebhookNotifier_EventRoutingAndTemplates(t *testing.T) {
+	notifier, captured := newTestAlertWebhookNotifier(t, []config.AlertWebhookTarget{
+		{Name: "quota-only", Type: config.AlertWebhookTypeSlack, URL: "http://quota.local/hook", Events: []string{service.AlertEventQuotaThreshold}},
+		{
+			Name:      "custom",
+			Type:      config.AlertWebhookTypeGeneric,
+			URL:       "http://custom.local/hook",
+			Template:  `{"msg":{{json .Title}}}`,
+			Templates: map[string]string{service.AlertEventAccountDisabled: `{"disabled":{{json .Fields.account_id}}}`},
+		},
+	})
+
+	require.NoError(t, notifier.Notify(context.Background(), testAlertEvent()))
+	got := captured()
+	require.NotContains(t, got, "quota.local")
+	require.JSONEq(t, `{"disabled":1}`, string(got["custom.local"].body))
+
+	event := testAlertEvent()
+	event.Type = service.AlertEventOpsAlert
+	require.NoError(t, notifier.Notify(context.Background(), event))
+	require.JSONEq(t, `{"msg":"Account disabled: acc-1"}`, string(captured()["custom.local"].body))
+}
@@ -0,0 +1,50 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/ShaohongDong/sub2api/internal/config"
+	"github.com/ShaohongDong/sub2api/internal/pkg/httpclient"
+	"github.com/ShaohongDong/sub2api/internal/service"
+)
+
+type apiKeyBudgetWebhook struct {
+	httpClient *http.Client
+	url        string
+	secret     string
+}
+
+// NewAPIKeyBudgetWebhook 创建预算超限 webhook 通知器；未配置 api_key_budget.webhook_url 时返回 nil。
+// 地址只能由管理员在配置文件中设置，因此允许指向内网服务。
+func NewAPIKeyBudgetWebhook(cfg *config.Config) service.APIKeyBudgetNotifier {
+	if cfg == nil || strings.TrimSpace(cfg.APIKeyBudget.WebhookURL) == "" {
+		return nil
+	}
+	timeout := time.Duration(cfg.APIKeyBudget.WebhookTimeoutSeconds) * time.Second
+	if timeout <= 0 {
+		timeout = 10 * time.Second
+	}
+	sharedClient, err := httpclient.GetClient(httpclient.Options{Timeout: timeout})
+	if err != nil {
+		sharedClient = &http.Client{Timeout: timeout}
+	}
+	return &apiKeyBudgetWebhook{
+		httpClient: sharedClient,
+		url:        strings.TrimSpace(cfg.APIKeyBudget.WebhookURL),
+		secret:     cfg.APIKeyBudget.WebhookSecret,
+	}
+}
+
+func (w *apiKeyBudgetWebhook) NotifyBudgetExceeded(ctx context.Context, event *service.APIKeyBudgetEvent) error {
+	payload, err := json.Marshal(event)
+	if err != nil {
+		return fmt.Errorf("marshal event: %w", err)
+	}
+
+	return postSignedJSON(ctx, w.httpClient, w.url, w.secret, payload)
+}
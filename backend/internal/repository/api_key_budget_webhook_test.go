@@ -0,0 +1,77 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/ShaohongDong/sub2api/internal/config"
+	"github.com/ShaohongDong/sub2api/internal/service"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestBudgetWebhook(t *testing.T, secret string, handler http.HandlerFunc) *apiKeyBudgetWebhook {
+	t.Helper()
+	cfg := &config.Config{APIKeyBudget: config.APIKeyBudgetConfig{
+		WebhookURL:            "http://in-process/budget",
+		WebhookSecret:         secret,
+		WebhookTimeoutSeconds: 5,
+	}}
+	webhook, ok := NewAPIKeyBudgetWebhook(cfg).(*apiKeyBudgetWebhook)
+	require.True(t, ok, "type assertion failed")
+	webhook.httpClient = &http.Client{Transport: newInProcessTransport(handler, nil)}
+	return webhook
+}
+
+func TestNewAPIKeyBudgetWebhook_DisabledWithoutURL(t *testing.T) {
+	require.Nil(t, NewAPIKeyBudgetWebhook(&config.Config{}))
+	require.Nil(t, NewAPIKeyBudgetWebhook(nil))
+}
+
+func TestAPIKeyBudgetWebhook_PostsSignedEvent(t *testing.T) {
+	var (
+		body      []byte
+		signature string
+	)
+	webhook := newTestBudgetWebhook(t, "s3cret", func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, http.MethodPost, r.Method)
+		require.Equal(t, "application/json", r.Header.Get("Content-Type"))
+		body, _ = io.ReadAll(r.Body)
+		signature = r.Header.Get(webhookSignatureHeader)
+		w.WriteHeader(http.StatusNoContent)
+	})
+
+	event := &service.APIKeyBudgetEvent{
+		Event:       service.APIKeyBudgetExceededEvent,
+		APIKeyID:    7,
+		APIKeyName:  "ci",
+		UserID:      3,
+		Budget:      10,
+		Used:        10.5,
+		Period:      service.APIKeyBudgetPeriodMonth,
+		PeriodStart: time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC),
+		Action:      service.APIKeyBudgetActionDisable,
+	}
+	require.NoError(t, webhook.NotifyBudgetExceeded(context.Background(), event))
+
+	var got map[string]any
+	require.NoError(t, json.Unmarshal(body, &got))
+	require.Equal(t, "api_key.budget_exceeded", got["event"])
+	require.Equal(t, float64(7), got["api_key_id"])
+	require.Equal(t, 10.5, got["used"])
+
+	require.Equal(t, signWebhookPayload("s3cret", body), signature)
+}
+
+func TestAPIKeyBudgetWebhook_ErrorStatus(t *testing.T) {
+	webhook := newTestBudgetWebhook(t, "", func(w http.ResponseWriter, r *http.Request) {
+		require.Empty(t, r.Header.Get(webhookSignatureHeader))
+		w.WriteHeader(http.StatusBadGateway)
+	})
+
+	err := webhook.NotifyBudgetExceeded(context.Background(), &service.APIKeyBudgetEvent{APIKeyID: 1})
+	require.ErrorContains(t, err, "502")
+}
@@ -38,15 +38,33 @@ func (r *apiKeyRepository) Create(ctx context.Context, key *service.APIKey) erro
 		SetUserID(key.UserID).
 		SetKey(key.Key).
 		SetName(key.Name).
+		SetDescription(key.Description).
 		SetStatus(key.Status).
 		SetNillableGroupID(key.GroupID).
 		SetNillableLastUsedAt(key.LastUsedAt).
 		SetQuota(key.Quota).
 		SetQuotaUsed(key.QuotaUsed).
+		SetImageQuota(key.ImageQuota).
+		SetImageQuotaUsed(key.ImageQuotaUsed).
+		SetSuppressReasoning(key.SuppressReasoning).
 		SetNillableExpiresAt(key.ExpiresAt).
 		SetRateLimit5h(key.RateLimit5h).
 		SetRateLimit1d(key.RateLimit1d).
-		SetRateLimit7d(key.RateLimit7d)
+		SetRateLimit7d(key.RateLimit7d).
+		SetRpmLimit(key.RPMLimit).
+		SetTpmLimit(key.TPMLimit).
+		SetDailyRequestLimit(key.DailyRequestLimit).
+		SetDailyTokenLimit(key.DailyTokenLimit).
+		SetMonthlyRequestLimit(key.MonthlyRequestLimit).
+		SetMonthlyTokenLimit(key.MonthlyTokenLimit).
+		SetBudgetAmount(key.BudgetAmount).
+		SetBudgetFallbackModel(key.BudgetFallbackModel)
+	if key.BudgetPeriod != "" {
+		builder.SetBudgetPeriod(key.BudgetPeriod)
+	}
+	if key.BudgetAction != "" {
+		builder.SetBudgetAction(key.BudgetAction)
+	}
 
 	if len(key.IPWhitelist) > 0 {
 		builder.SetIPWhitelist(key.IPWhitelist)
@@ -54,6 +72,33 @@ func (r *apiKeyRepository) Create(ctx context.Context, key *service.APIKey) erro
 	if len(key.IPBlacklist) > 0 {
 		builder.SetIPBlacklist(key.IPBlacklist)
 	}
+	if len(key.Scopes) > 0 {
+		builder.SetScopes(key.Scopes)
+	}
+	if len(key.AllowedModels) > 0 {
+		builder.SetAllowedModels(key.AllowedModels)
+	}
+	if key.ClientRestriction != "" {
+		builder.SetClientRestriction(key.ClientRestriction)
+	}
+	if len(key.Tags) > 0 {
+		builder.SetTags(key.Tags)
+	}
+	if key.DegradedFallbackModel != "" {
+		builder.SetDegradedFallbackModel(key.DegradedFallbackModel)
+	}
+	if key.SystemPrompt != "" {
+		builder.SetSystemPrompt(key.SystemPrompt).SetSystemPromptMode(key.SystemPromptMode)
+	}
+	if key.ModerationMode != "" {
+		builder.SetModerationMode(key.ModerationMode)
+	}
+	if key.LogContent {
+		builder.SetLogContent(true)
+	}
+	if key.Simulate {
+		builder.SetSimulate(true)
+	}
 
 	created, err := builder.Save(ctx)
 	if err == nil {
@@ -116,7 +161,11 @@ func (r *apiKeyRepository) GetByKey(ctx context.Context, key string) (*service.A
 
 func (r *apiKeyRepository) GetByKeyForAuth(ctx context.Context, key string) (*service.APIKey, error) {
 	m, err := r.activeQuery().
-		Where(apikey.KeyEQ(key)).
+		Where(apikey.Or(
+			apikey.KeyEQ(key),
+			// 轮换宽限期内的旧密钥
+			apikey.And(apikey.PreviousKeyEQ(key), apikey.PreviousKeyExpiresAtGT(time.Now())),
+		)).
 		Select(
 			apikey.FieldID,
 			apikey.FieldUserID,
@@ -124,12 +173,38 @@ func (r *apiKeyRepository) GetByKeyForAuth(ctx context.Context, key string) (*se
 			apikey.FieldStatus,
 			apikey.FieldIPWhitelist,
 			apikey.FieldIPBlacklist,
+			apikey.FieldScopes,
+			apikey.FieldAllowedModels,
+			apikey.FieldClientRestriction,
+			apikey.FieldTags,
+			apikey.FieldDegradedFallbackModel,
+			apikey.FieldSystemPrompt,
+			apikey.FieldSystemPromptMode,
+			apikey.FieldModerationMode,
+			apikey.FieldLogContent,
+			apikey.FieldSimulate,
 			apikey.FieldQuota,
 			apikey.FieldQuotaUsed,
+			apikey.FieldImageQuota,
+			apikey.FieldSuppressReasoning,
 			apikey.FieldExpiresAt,
 			apikey.FieldRateLimit5h,
 			apikey.FieldRateLimit1d,
 			apikey.FieldRateLimit7d,
+			apikey.FieldRpmLimit,
+			apikey.FieldTpmLimit,
+			apikey.FieldDailyRequestLimit,
+			apikey.FieldDailyTokenLimit,
+			apikey.FieldMonthlyRequestLimit,
+			apikey.FieldMonthlyTokenLimit,
+			apikey.FieldBudgetAmount,
+			apikey.FieldBudgetPeriod,
+			apikey.FieldBudgetAction,
+			apikey.FieldBudgetFallbackModel,
+			apikey.FieldBudgetPeriodStart,
+			apikey.FieldBudgetExceededAt,
+			apikey.FieldPreviousKey,
+			apikey.FieldPreviousKeyExpiresAt,
 		).
 		WithUser(func(q *dbent.UserQuery) {
 			q.Select(
@@ -138,6 +213,7 @@ func (r *apiKeyRepository) GetByKeyForAuth(ctx context.Context, key string) (*se
 				user.FieldRole,
 				user.FieldBalance,
 				user.FieldConcurrency,
+				user.FieldTenantID,
 			)
 		}).
 		WithGroup(func(q *dbent.GroupQuery) {
@@ -167,6 +243,10 @@ func (r *apiKeyRepository) GetByKeyForAuth(ctx context.Context, key string) (*se
 				group.FieldSupportedModelScopes,
 				group.FieldAllowMessagesDispatch,
 				group.FieldDefaultMappedModel,
+				group.FieldModelAliases,
+				group.FieldFallbackChain,
+				group.FieldSchedulingStrategy,
+				group.FieldTenantID,
 			)
 		}).
 		Only(ctx)
@@ -190,12 +270,25 @@ func (r *apiKeyRepository) Update(ctx context.Context, key *service.APIKey) erro
 	builder := client.APIKey.Update().
 		Where(apikey.IDEQ(key.ID), apikey.DeletedAtIsNil()).
 		SetName(key.Name).
+		SetDescription(key.Description).
 		SetStatus(key.Status).
 		SetQuota(key.Quota).
 		SetQuotaUsed(key.QuotaUsed).
+		SetImageQuota(key.ImageQuota).
+		SetImageQuotaUsed(key.ImageQuotaUsed).
+		SetSuppressReasoning(key.SuppressReasoning).
 		SetRateLimit5h(key.RateLimit5h).
 		SetRateLimit1d(key.RateLimit1d).
 		SetRateLimit7d(key.RateLimit7d).
+		SetRpmLimit(key.RPMLimit).
+		SetTpmLimit(key.TPMLimit).
+		SetDailyRequestLimit(key.DailyRequestLimit).
+		SetDailyTokenLimit(key.DailyTokenLimit).
+		SetMonthlyRequestLimit(key.MonthlyRequestLimit).
+		SetMonthlyTokenLimit(key.MonthlyTokenLimit).
+		SetBudgetAmount(key.BudgetAmount).
+		SetBudgetFallbackModel(key.BudgetFallbackModel).
+		SetBudgetUsed(key.BudgetUsed).
 		SetUsage5h(key.Usage5h).
 		SetUsage1d(key.Usage1d).
 		SetUsage7d(key.Usage7d).
@@ -230,6 +323,24 @@ func (r *apiKeyRepository) Update(ctx context.Context, key *service.APIKey) erro
 		builder.ClearWindow7dStart()
 	}
 
+	// Budget period state
+	if key.BudgetPeriod != "" {
+		builder.SetBudgetPeriod(key.BudgetPeriod)
+	}
+	if key.BudgetAction != "" {
+		builder.SetBudgetAction(key.BudgetAction)
+	}
+	if key.BudgetPeriodStart != nil {
+		builder.SetBudgetPeriodStart(*key.BudgetPeriodStart)
+	} else {
+		builder.ClearBudgetPeriodStart()
+	}
+	if key.BudgetExceededAt != nil {
+		builder.SetBudgetExceededAt(*key.BudgetExceededAt)
+	} else {
+		builder.ClearBudgetExceededAt()
+	}
+
 	// IP 限制字段
 	if len(key.IPWhitelist) > 0 {
 		builder.SetIPWhitelist(key.IPWhitelist)
@@ -241,6 +352,27 @@ func (r *apiKeyRepository) Update(ctx context.Context, key *service.APIKey) erro
 	} else {
 		builder.ClearIPBlacklist()
 	}
+	if len(key.Scopes) > 0 {
+		builder.SetScopes(key.Scopes)
+	} else {
+		builder.ClearScopes()
+	}
+	if len(key.AllowedModels) > 0 {
+		builder.SetAllowedModels(key.AllowedModels)
+	} else {
+		builder.ClearAllowedModels()
+	}
+	builder.SetClientRestriction(key.ClientRestriction)
+	if len(key.Tags) > 0 {
+		builder.SetTags(key.Tags)
+	} else {
+		builder.ClearTags()
+	}
+	builder.SetDegradedFallbackModel(key.DegradedFallbackModel)
+	builder.SetSystemPrompt(key.SystemPrompt).SetSystemPromptMode(key.SystemPromptMode)
+	builder.SetModerationMode(key.ModerationMode)
+	builder.SetLogContent(key.LogContent)
+	builder.SetSimulate(key.Simulate)
 
 	affected, err := builder.Save(ctx)
 	if err != nil {
@@ -347,7 +479,10 @@ func (r *apiKeyRepository) CountByUserID(ctx context.Context, userID int64) (int
 }
 
 func (r *apiKeyRepository) ExistsByKey(ctx context.Context, key string) (bool, error) {
-	count, err := r.activeQuery().Where(apikey.KeyEQ(key)).Count(ctx)
+	// 宽限期内的旧密钥同样占用该值，避免认证时命中两条记录
+	count, err := r.activeQuery().
+		Where(apikey.Or(apikey.KeyEQ(key), apikey.PreviousKeyEQ(key))).
+		Count(ctx)
 	return count > 0, err
 }
 
@@ -452,6 +587,21 @@ func (r *apiKeyRepository) IncrementQuotaUsed(ctx context.Context, id int64, amo
 	return updated.QuotaUsed, nil
 }
 
+// IncrementImageQuotaUsed 使用 Ent 原子递增 image_quota_used 字段并返回新值
+func (r *apiKeyRepository) IncrementImageQuotaUsed(ctx context.Context, id int64, count int) (int, error) {
+	updated, err := r.client.APIKey.UpdateOneID(id).
+		Where(apikey.DeletedAtIsNil()).
+		AddImageQuotaUsed(count).
+		Save(ctx)
+	if err != nil {
+		if dbent.IsNotFound(err) {
+			return 0, service.ErrAPIKeyNotFound
+		}
+		return 0, err
+	}
+	return updated.ImageQuotaUsed, nil
+}
+
 func (r *apiKeyRepository) UpdateLastUsed(ctx context.Context, id int64, usedAt time.Time) error {
 	affected, err := r.client.APIKey.Update().
 		Where(apikey.IDEQ(id), apikey.DeletedAtIsNil()).
@@ -525,34 +675,136 @@ func (r *apiKeyRepository) GetRateLimitData(ctx context.Context, id int64) (resu
 	return data, rows.Err()
 }
 
+// IncrementBudgetUsed atomically adds amount to budget_used and returns the new value.
+// When the stored period is older than periodStart the counter (and the exceeded mark)
+// is reset first, so the budget rolls over without a background job.
+func (r *apiKeyRepository) IncrementBudgetUsed(ctx context.Context, id int64, amount float64, periodStart time.Time) (used float64, err error) {
+	rows, err := r.sql.QueryContext(ctx, `
+		UPDATE api_keys SET
+			budget_used = CASE WHEN budget_period_start IS NULL OR budget_period_start < $2 THEN $1 ELSE budget_used + $1 END,
+			budget_exceeded_at = CASE WHEN budget_period_start IS NULL OR budget_period_start < $2 THEN NULL ELSE budget_exceeded_at END,
+			budget_period_start = CASE WHEN budget_period_start IS NULL OR budget_period_start < $2 THEN $2 ELSE budget_period_start END,
+			updated_at = NOW()
+		WHERE id = $3 AND deleted_at IS NULL
+		RETURNING budget_used`,
+		amount, periodStart, id)
+	if err != nil {
+		return 0, err
+	}
+	defer func() {
+		if closeErr := rows.Close(); closeErr != nil && err == nil {
+			err = closeErr
+		}
+	}()
+	if !rows.Next() {
+		if err := rows.Err(); err != nil {
+			return 0, err
+		}
+		return 0, service.ErrAPIKeyNotFound
+	}
+	if err := rows.Scan(&used); err != nil {
+		return 0, err
+	}
+	return used, rows.Err()
+}
+
+// MarkBudgetExceeded records when the budget of the period starting at periodStart was exceeded.
+// Only the first caller per period gets true, which keeps the alert to one per period.
+func (r *apiKeyRepository) MarkBudgetExceeded(ctx context.Context, id int64, periodStart, exceededAt time.Time) (bool, error) {
+	res, err := r.sql.ExecContext(ctx, `
+		UPDATE api_keys SET budget_exceeded_at = $1, updated_at = NOW()
+		WHERE id = $2 AND deleted_at IS NULL AND budget_exceeded_at IS NULL AND budget_period_start = $3`,
+		exceededAt, id, periodStart)
+	if err != nil {
+		return false, err
+	}
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	return affected > 0, nil
+}
+
+// RotateKey 以 key 为乐观锁条件替换密钥；graceUntil 为 nil 时清除旧密钥
+func (r *apiKeyRepository) RotateKey(ctx context.Context, id int64, oldKey, newKey string, graceUntil *time.Time) error {
+	builder := r.client.APIKey.Update().
+		Where(apikey.IDEQ(id), apikey.KeyEQ(oldKey), apikey.DeletedAtIsNil()).
+		SetKey(newKey).
+		SetUpdatedAt(time.Now())
+	if graceUntil != nil {
+		builder.SetPreviousKey(oldKey).SetPreviousKeyExpiresAt(*graceUntil)
+	} else {
+		builder.ClearPreviousKey().ClearPreviousKeyExpiresAt()
+	}
+	affected, err := builder.Save(ctx)
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		return service.ErrAPIKeyNotFound
+	}
+	return nil
+}
+
 func apiKeyEntityToService(m *dbent.APIKey) *service.APIKey {
 	if m == nil {
 		return nil
 	}
 	out := &service.APIKey{
-		ID:            m.ID,
-		UserID:        m.UserID,
-		Key:           m.Key,
-		Name:          m.Name,
-		Status:        m.Status,
-		IPWhitelist:   m.IPWhitelist,
-		IPBlacklist:   m.IPBlacklist,
-		LastUsedAt:    m.LastUsedAt,
-		CreatedAt:     m.CreatedAt,
-		UpdatedAt:     m.UpdatedAt,
-		GroupID:       m.GroupID,
-		Quota:         m.Quota,
-		QuotaUsed:     m.QuotaUsed,
-		ExpiresAt:     m.ExpiresAt,
-		RateLimit5h:   m.RateLimit5h,
-		RateLimit1d:   m.RateLimit1d,
-		RateLimit7d:   m.RateLimit7d,
-		Usage5h:       m.Usage5h,
-		Usage1d:       m.Usage1d,
-		Usage7d:       m.Usage7d,
-		Window5hStart: m.Window5hStart,
-		Window1dStart: m.Window1dStart,
-		Window7dStart: m.Window7dStart,
+		ID:                  m.ID,
+		UserID:              m.UserID,
+		Key:                 m.Key,
+		Name:                m.Name,
+		Status:              m.Status,
+		Description:         m.Description,
+		IPWhitelist:         m.IPWhitelist,
+		IPBlacklist:         m.IPBlacklist,
+		Scopes:              m.Scopes,
+		AllowedModels:       m.AllowedModels,
+		ClientRestriction:   m.ClientRestriction,
+		Tags:                m.Tags,
+		LastUsedAt:          m.LastUsedAt,
+		CreatedAt:           m.CreatedAt,
+		UpdatedAt:           m.UpdatedAt,
+		GroupID:             m.GroupID,
+		Quota:               m.Quota,
+		QuotaUsed:           m.QuotaUsed,
+		ImageQuota:          m.ImageQuota,
+		ImageQuotaUsed:      m.ImageQuotaUsed,
+		SuppressReasoning:   m.SuppressReasoning,
+		ExpiresAt:           m.ExpiresAt,
+		RateLimit5h:         m.RateLimit5h,
+		RateLimit1d:         m.RateLimit1d,
+		RateLimit7d:         m.RateLimit7d,
+		Usage5h:             m.Usage5h,
+		Usage1d:             m.Usage1d,
+		Usage7d:             m.Usage7d,
+		Window5hStart:       m.Window5hStart,
+		Window1dStart:       m.Window1dStart,
+		Window7dStart:       m.Window7dStart,
+		RPMLimit:            m.RpmLimit,
+		TPMLimit:            m.TpmLimit,
+		DailyRequestLimit:   m.DailyRequestLimit,
+		DailyTokenLimit:     m.DailyTokenLimit,
+		MonthlyRequestLimit: m.MonthlyRequestLimit,
+		MonthlyTokenLimit:   m.MonthlyTokenLimit,
+		BudgetAmount:        m.BudgetAmount,
+		BudgetPeriod:        m.BudgetPeriod,
+		BudgetAction:        m.BudgetAction,
+		BudgetFallbackModel: m.BudgetFallbackModel,
+		BudgetUsed:          m.BudgetUsed,
+		BudgetPeriodStart:   m.BudgetPeriodStart,
+		BudgetExceededAt:    m.BudgetExceededAt,
+
+		DegradedFallbackModel: m.DegradedFallbackModel,
+		SystemPrompt:          m.SystemPrompt,
+		SystemPromptMode:      m.SystemPromptMode,
+		ModerationMode:        m.ModerationMode,
+		LogContent:            m.LogContent,
+		Simulate:              m.Simulate,
+
+		PreviousKey:          m.PreviousKey,
+		PreviousKeyExpiresAt: m.PreviousKeyExpiresAt,
 	}
 	if m.Edges.User != nil {
 		out.User = userEntityToService(m.Edges.User)
@@ -582,6 +834,7 @@ func userEntityToService(u *dbent.User) *service.User {
 		TotpSecretEncrypted:   u.TotpSecretEncrypted,
 		TotpEnabled:           u.TotpEnabled,
 		TotpEnabledAt:         u.TotpEnabledAt,
+		TenantID:              u.TenantID,
 		CreatedAt:             u.CreatedAt,
 		UpdatedAt:             u.UpdatedAt,
 	}
@@ -623,6 +876,10 @@ func groupEntityToService(g *dbent.Group) *service.Group {
 		SortOrder:                       g.SortOrder,
 		AllowMessagesDispatch:           g.AllowMessagesDispatch,
 		DefaultMappedModel:              g.DefaultMappedModel,
+		ModelAliases:                    g.ModelAliases,
+		FallbackChain:                   g.FallbackChain,
+		SchedulingStrategy:              g.SchedulingStrategy,
+		TenantID:                        g.TenantID,
 		CreatedAt:                       g.CreatedAt,
 		UpdatedAt:                       g.UpdatedAt,
 	}
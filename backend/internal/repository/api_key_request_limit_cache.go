@@ -0,0 +1,111 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+
+	"github.com/ShaohongDong/sub2api/internal/service"
+	"github.com/redis/go-redis/v9"
+)
+
+// Redis Key 模式（hash tag 确保同一 API Key 的所有窗口落入同一 slot，Lua 脚本可跨 key 原子执行）
+// 格式: apikey_limit:{apiKeyID}:{bucket}，HASH 字段 req（请求数）/ tok（token 数）
+const apiKeyLimitKeyPrefix = "apikey_limit:"
+
+// Lua 脚本：检查所有窗口的请求数/token 数是否已达上限，全部未超限时各窗口请求数 +1
+// KEYS[i] = 窗口 key；ARGV[(i-1)*3+1..3] = 请求上限、token 上限、TTL 秒（上限 0 = 不限制）
+// 返回 {命中窗口下标(从 1 开始，0 = 放行), 维度, 窗口1请求数, 窗口1 token 数, ...}（计数为本次计入前的值）
+var apiKeyAcquireRequestScript = redis.NewScript(`
+local usage = {}
+for i = 1, #KEYS do
+    local reqLimit = tonumber(ARGV[(i-1)*3+1])
+    local tokLimit = tonumber(ARGV[(i-1)*3+2])
+    local vals = redis.call('HMGET', KEYS[i], 'req', 'tok')
+    local req = tonumber(vals[1]) or 0
+    local tok = tonumber(vals[2]) or 0
+    if reqLimit > 0 and req >= reqLimit then
+        return {i, 'requests'}
+    end
+    if tokLimit > 0 and tok >= tokLimit then
+        return {i, 'tokens'}
+    end
+    usage[#usage+1] = req
+    usage[#usage+1] = tok
+end
+for i = 1, #KEYS do
+    redis.call('HINCRBY', KEYS[i], 'req', 1)
+    redis.call('EXPIRE', KEYS[i], tonumber(ARGV[(i-1)*3+3]))
+end
+local res = {0, ''}
+for i = 1, #usage do
+    res[#res+1] = usage[i]
+end
+return res
+`)
+
+type apiKeyRequestLimitCache struct {
+	rdb *redis.Client
+}
+
+// NewAPIKeyRequestLimitCache 创建 API Key 请求/token 限制计数缓存
+func NewAPIKeyRequestLimitCache(rdb *redis.Client) service.APIKeyRequestLimitCache {
+	return &apiKeyRequestLimitCache{rdb: rdb}
+}
+
+func apiKeyLimitKey(apiKeyID int64, bucket string) string {
+	// 格式: apikey_limit:{123}:m29431234
+	return apiKeyLimitKeyPrefix + "{" + strconv.FormatInt(apiKeyID, 10) + "}:" + bucket
+}
+
+func (c *apiKeyRequestLimitCache) AcquireRequest(ctx context.Context, apiKeyID int64, buckets []service.APIKeyLimitBucket) (int, string, []service.APIKeyLimitUsage, error) {
+	if len(buckets) == 0 {
+		return -1, "", nil, nil
+	}
+	keys := make([]string, 0, len(buckets))
+	args := make([]any, 0, len(buckets)*3)
+	for _, b := range buckets {
+		keys = append(keys, apiKeyLimitKey(apiKeyID, b.Bucket))
+		args = append(args, b.RequestLimit, b.TokenLimit, int64(b.TTL.Seconds()))
+	}
+	res, err := apiKeyAcquireRequestScript.Run(ctx, c.rdb, keys, args...).Slice()
+	if err != nil {
+		return -1, "", nil, fmt.Errorf("api key request limit acquire: %w", err)
+	}
+	if len(res) < 2 {
+		return -1, "", nil, fmt.Errorf("api key request limit acquire: unexpected result %v", res)
+	}
+	idx, ok := res[0].(int64)
+	if !ok {
+		return -1, "", nil, fmt.Errorf("api key request limit acquire: unexpected index %v", res[0])
+	}
+	dimension, _ := res[1].(string)
+	if idx > 0 {
+		return int(idx) - 1, dimension, nil, nil
+	}
+	var usage []service.APIKeyLimitUsage
+	if counts := res[2:]; len(counts) == 2*len(buckets) {
+		usage = make([]service.APIKeyLimitUsage, len(buckets))
+		for i := range buckets {
+			usage[i].Requests, _ = counts[2*i].(int64)
+			usage[i].Tokens, _ = counts[2*i+1].(int64)
+		}
+	}
+	return -1, "", usage, nil
+}
+
+func (c *apiKeyRequestLimitCache) AddTokens(ctx context.Context, apiKeyID int64, buckets []service.APIKeyLimitBucket, tokens int64) error {
+	if len(buckets) == 0 || tokens <= 0 {
+		return nil
+	}
+	pipe := c.rdb.Pipeline()
+	for _, b := range buckets {
+		key := apiKeyLimitKey(apiKeyID, b.Bucket)
+		pipe.HIncrBy(ctx, key, "tok", tokens)
+		pipe.Expire(ctx, key, b.TTL)
+	}
+	if _, err := pipe.Exec(ctx); err != nil {
+		return fmt.Errorf("api key request limit add tokens: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,139 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/ShaohongDong/sub2api/internal/service"
+)
+
+type backgroundResponseRepository struct {
+	db *sql.DB
+}
+
+func NewBackgroundResponseRepository(db *sql.DB) service.BackgroundResponseRepository {
+	return &backgroundResponseRepository{db: db}
+}
+
+const backgroundResponseColumns = `id, user_id, api_key_id, model, status, client_ip, request_body, attempts,
+	response_status, response_body, request_id, error_message, started_at, completed_at, created_at, updated_at`
+
+func (r *backgroundResponseRepository) Create(ctx context.Context, resp *service.BackgroundResponse) error {
+	return r.db.QueryRowContext(ctx, `
+		INSERT INTO background_responses (id, user_id, api_key_id, model, status, client_ip, request_body, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, NOW(), NOW())
+		RETURNING created_at, updated_at
+	`, resp.ID, resp.UserID, resp.APIKeyID, resp.Model, resp.Status, resp.ClientIP, string(resp.RequestBody),
+	).Scan(&resp.CreatedAt, &resp.UpdatedAt)
+}
+
+func (r *backgroundResponseRepository) Get(ctx context.Context, id string) (*service.BackgroundResponse, error) {
+	row := r.db.QueryRowContext(ctx, `SELECT `+backgroundResponseColumns+` FROM background_responses WHERE id = $1`, id)
+	resp, err := scanBackgroundResponse(row)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, service.ErrBackgroundResponseNotFound
+	}
+	return resp, err
+}
+
+func (r *backgroundResponseRepository) CountActiveByAPIKey(ctx context.Context, apiKeyID int64) (int, error) {
+	var count int
+	err := r.db.QueryRowContext(ctx, `
+		SELECT COUNT(*) FROM background_responses WHERE api_key_id = $1 AND status IN ($2, $3)
+	`, apiKeyID, service.BackgroundResponseStatusQueued, service.BackgroundResponseStatusInProgress).Scan(&count)
+	return count, err
+}
+
+func (r *backgroundResponseRepository) Claim(ctx context.Context, limit int, staleBefore time.Time) ([]*service.BackgroundResponse, error) {
+	rows, err := r.db.QueryContext(ctx, `
+		UPDATE background_responses
+		SET status = $3, attempts = attempts + 1, started_at = NOW(), updated_at = NOW()
+		WHERE id IN (
+			SELECT id FROM background_responses
+			WHERE (status = $4 AND next_attempt_at <= NOW()) OR (status = $3 AND started_at < $2)
+			ORDER BY created_at ASC
+			LIMIT $1
+			FOR UPDATE SKIP LOCKED
+		)
+		RETURNING `+backgroundResponseColumns+`
+	`, limit, staleBefore, service.BackgroundResponseStatusInProgress, service.BackgroundResponseStatusQueued)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = rows.Close() }()
+
+	var items []*service.BackgroundResponse
+	for rows.Next() {
+		resp, err := scanBackgroundResponse(rows)
+		if err != nil {
+			return nil, err
+		}
+		items = append(items, resp)
+	}
+	return items, rows.Err()
+}
+
+func (r *backgroundResponseRepository) Finish(ctx context.Context, resp *service.BackgroundResponse) error {
+	_, err := r.db.ExecContext(ctx, `
+		UPDATE background_responses
+		SET status = $2, response_status = $3, response_body = $4, request_id = $5, error_message = $6,
+			completed_at = NOW(), updated_at = NOW()
+		WHERE id = $1 AND status = $7
+	`, resp.ID, resp.Status, resp.ResponseStatus, string(resp.ResponseBody), resp.RequestID, resp.ErrorMessage,
+		service.BackgroundResponseStatusInProgress)
+	return err
+}
+
+func (r *backgroundResponseRepository) Retry(ctx context.Context, id string, nextAttemptAt time.Time, errMsg string) error {
+	_, err := r.db.ExecContext(ctx, `
+		UPDATE background_responses
+		SET status = $2, next_attempt_at = $3, error_message = $4, updated_at = NOW()
+		WHERE id = $1 AND status = $5
+	`, id, service.BackgroundResponseStatusQueued, nextAttemptAt, errMsg, service.BackgroundResponseStatusInProgress)
+	return err
+}
+
+func (r *backgroundResponseRepository) Cancel(ctx context.Context, id string) (bool, error) {
+	res, err := r.db.ExecContext(ctx, `
+		UPDATE background_responses
+		SET status = $2, completed_at = NOW(), updated_at = NOW()
+		WHERE id = $1 AND status IN ($3, $4)
+	`, id, service.BackgroundResponseStatusCancelled, service.BackgroundResponseStatusQueued, service.BackgroundResponseStatusInProgress)
+	if err != nil {
+		return false, err
+	}
+	n, err := res.RowsAffected()
+	return n > 0, err
+}
+
+func (r *backgroundResponseRepository) Delete(ctx context.Context, id string) error {
+	_, err := r.db.ExecContext(ctx, `DELETE FROM background_responses WHERE id = $1`, id)
+	return err
+}
+
+func (r *backgroundResponseRepository) DeleteFinishedBefore(ctx context.Context, before time.Time) (int64, error) {
+	res, err := r.db.ExecContext(ctx, `
+		DELETE FROM background_responses WHERE status NOT IN ($2, $3) AND updated_at < $1
+	`, before, service.BackgroundResponseStatusQueued, service.BackgroundResponseStatusInProgress)
+	if err != nil {
+		return 0, err
+	}
+	return res.RowsAffected()
+}
+
+func scanBackgroundResponse(row scannable) (*service.BackgroundResponse, error) {
+	resp := &service.BackgroundResponse{}
+	var requestBody, responseBody string
+	if err := row.Scan(
+		&resp.ID, &resp.UserID, &resp.APIKeyID, &resp.Model, &resp.Status, &resp.ClientIP, &requestBody, &resp.Attempts,
+		&resp.ResponseStatus, &responseBody, &resp.RequestID, &resp.ErrorMessage, &resp.StartedAt, &resp.CompletedAt,
+		&resp.CreatedAt, &resp.UpdatedAt,
+	); err != nil {
+		return nil, err
+	}
+	resp.RequestBody = []byte(requestBody)
+	resp.ResponseBody = []byte(responseBody)
+	return resp, nil
+}
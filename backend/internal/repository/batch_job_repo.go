@@ -0,0 +1,284 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/ShaohongDong/sub2api/internal/service"
+)
+
+type batchJobRepository struct {
+	db *sql.DB
+}
+
+func NewBatchJobRepository(db *sql.DB) service.BatchJobRepository {
+	return &batchJobRepository{db: db}
+}
+
+const batchJobColumns = `id, user_id, api_key_id, endpoint, completion_window, status, client_ip, metadata,
+	total_count, completed_count, failed_count, error_message, expires_at, in_progress_at, completed_at,
+	cancelling_at, cancelled_at, expired_at, created_at, updated_at`
+
+func (r *batchJobRepository) CreateJob(ctx context.Context, job *service.BatchJob, items []*service.BatchJobItem) error {
+	metadata, err := marshalBatchMetadata(job.Metadata)
+	if err != nil {
+		return err
+	}
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	if err := tx.QueryRowContext(ctx, `
+		INSERT INTO batch_jobs (id, user_id, api_key_id, endpoint, completion_window, status, client_ip, metadata,
+			total_count, expires_at, in_progress_at, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, NOW(), NOW())
+		RETURNING created_at, updated_at
+	`, job.ID, job.UserID, job.APIKeyID, job.Endpoint, job.CompletionWindow, job.Status, job.ClientIP, metadata,
+		job.TotalCount, job.ExpiresAt, job.InProgressAt,
+	).Scan(&job.CreatedAt, &job.UpdatedAt); err != nil {
+		return err
+	}
+
+	stmt, err := tx.PrepareContext(ctx, `
+		INSERT INTO batch_job_items (job_id, line_index, custom_id, method, url, body)
+		VALUES ($1, $2, $3, $4, $5, $6)
+	`)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = stmt.Close() }()
+	for _, item := range items {
+		if _, err := stmt.ExecContext(ctx, job.ID, item.LineIndex, item.CustomID, item.Method, item.URL, string(item.Body)); err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+func (r *batchJobRepository) GetJob(ctx context.Context, id string) (*service.BatchJob, error) {
+	row := r.db.QueryRowContext(ctx, `SELECT `+batchJobColumns+` FROM batch_jobs WHERE id = $1`, id)
+	job, err := scanBatchJob(row)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, service.ErrBatchNotFound
+	}
+	return job, err
+}
+
+func (r *batchJobRepository) ListJobsByAPIKey(ctx context.Context, apiKeyID int64, after string, limit int) ([]*service.BatchJob, error) {
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT `+batchJobColumns+`
+		FROM batch_jobs
+		WHERE api_key_id = $1
+		  AND ($2 = '' OR (created_at, id) < (SELECT created_at, id FROM batch_jobs WHERE id = $2))
+		ORDER BY created_at DESC, id DESC
+		LIMIT $3
+	`, apiKeyID, after, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = rows.Close() }()
+	return scanBatchJobs(rows)
+}
+
+func (r *batchJobRepository) CountActiveJobsByAPIKey(ctx context.Context, apiKeyID int64) (int, error) {
+	var count int
+	err := r.db.QueryRowContext(ctx, `
+		SELECT COUNT(*) FROM batch_jobs WHERE api_key_id = $1 AND status IN ($2, $3)
+	`, apiKeyID, service.BatchStatusInProgress, service.BatchStatusCancelling).Scan(&count)
+	return count, err
+}
+
+func (r *batchJobRepository) ListActiveJobs(ctx context.Context) ([]*service.BatchJob, error) {
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT `+batchJobColumns+`
+		FROM batch_jobs
+		WHERE status IN ($1, $2)
+		ORDER BY created_at ASC
+	`, service.BatchStatusInProgress, service.BatchStatusCancelling)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = rows.Close() }()
+	return scanBatchJobs(rows)
+}
+
+func (r *batchJobRepository) UpdateJob(ctx context.Context, job *service.BatchJob) error {
+	return r.db.QueryRowContext(ctx, `
+		UPDATE batch_jobs
+		SET status = $2, completed_count = $3, failed_count = $4, error_message = $5,
+			completed_at = $6, cancelling_at = $7, cancelled_at = $8, expired_at = $9, updated_at = NOW()
+		WHERE id = $1
+		RETURNING updated_at
+	`, job.ID, job.Status, job.CompletedCount, job.FailedCount, job.ErrorMessage,
+		job.CompletedAt, job.CancellingAt, job.CancelledAt, job.ExpiredAt,
+	).Scan(&job.UpdatedAt)
+}
+
+func (r *batchJobRepository) ClaimItems(ctx context.Context, jobID string, limit int, staleBefore time.Time) ([]*service.BatchJobItem, error) {
+	rows, err := r.db.QueryContext(ctx, `
+		UPDATE batch_job_items
+		SET status = $4, attempts = attempts + 1, started_at = NOW()
+		WHERE id IN (
+			SELECT id FROM batch_job_items
+			WHERE job_id = $1
+			  AND ((status = $5 AND next_attempt_at <= NOW()) OR (status = $4 AND started_at < $3))
+			ORDER BY line_index ASC
+			LIMIT $2
+			FOR UPDATE SKIP LOCKED
+		)
+		RETURNING id, job_id, line_index, custom_id, method, url, body, status, attempts
+	`, jobID, limit, staleBefore, service.BatchItemStatusRunning, service.BatchItemStatusPending)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = rows.Close() }()
+
+	var items []*service.BatchJobItem
+	for rows.Next() {
+		item := &service.BatchJobItem{}
+		var body string
+		if err := rows.Scan(&item.ID, &item.JobID, &item.LineIndex, &item.CustomID, &item.Method, &item.URL,
+			&body, &item.Status, &item.Attempts); err != nil {
+			return nil, err
+		}
+		item.Body = []byte(body)
+		items = append(items, item)
+	}
+	return items, rows.Err()
+}
+
+func (r *batchJobRepository) FinishItem(ctx context.Context, item *service.BatchJobItem) error {
+	_, err := r.db.ExecContext(ctx, `
+		UPDATE batch_job_items
+		SET status = $2, response_status = $3, response_body = $4, request_id = $5, error_message = $6, finished_at = NOW()
+		WHERE id = $1
+	`, item.ID, item.Status, item.ResponseStatus, string(item.ResponseBody), item.RequestID, item.ErrorMessage)
+	return err
+}
+
+func (r *batchJobRepository) RetryItem(ctx context.Context, itemID int64, nextAttemptAt time.Time, errMsg string) error {
+	_, err := r.db.ExecContext(ctx, `
+		UPDATE batch_job_items
+		SET status = $2, next_attempt_at = $3, error_message = $4
+		WHERE id = $1
+	`, itemID, service.BatchItemStatusPending, nextAttemptAt, errMsg)
+	return err
+}
+
+func (r *batchJobRepository) AbortPendingItems(ctx context.Context, jobID string, status string, errMsg string, staleBefore time.Time) (int64, error) {
+	res, err := r.db.ExecContext(ctx, `
+		UPDATE batch_job_items
+		SET status = $2, error_message = $3, finished_at = NOW()
+		WHERE job_id = $1
+		  AND (status = $5 OR (status = $6 AND started_at < $4))
+	`, jobID, status, errMsg, staleBefore, service.BatchItemStatusPending, service.BatchItemStatusRunning)
+	if err != nil {
+		return 0, err
+	}
+	return res.RowsAffected()
+}
+
+func (r *batchJobRepository) CountItemsByStatus(ctx context.Context, jobID string) (map[string]int, error) {
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT status, COUNT(*) FROM batch_job_items WHERE job_id = $1 GROUP BY status
+	`, jobID)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = rows.Close() }()
+
+	counts := make(map[string]int)
+	for rows.Next() {
+		var status string
+		var count int
+		if err := rows.Scan(&status, &count); err != nil {
+			return nil, err
+		}
+		counts[status] = count
+	}
+	return counts, rows.Err()
+}
+
+func (r *batchJobRepository) ListItemResults(ctx context.Context, jobID string, afterLine int, limit int) ([]*service.BatchJobItem, error) {
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT id, job_id, line_index, custom_id, status, attempts, response_status, response_body, request_id, error_message
+		FROM batch_job_items
+		WHERE job_id = $1 AND line_index > $2 AND status NOT IN ($4, $5)
+		ORDER BY line_index ASC
+		LIMIT $3
+	`, jobID, afterLine, limit, service.BatchItemStatusPending, service.BatchItemStatusRunning)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = rows.Close() }()
+
+	var items []*service.BatchJobItem
+	for rows.Next() {
+		item := &service.BatchJobItem{}
+		var responseBody string
+		if err := rows.Scan(&item.ID, &item.JobID, &item.LineIndex, &item.CustomID, &item.Status, &item.Attempts,
+			&item.ResponseStatus, &responseBody, &item.RequestID, &item.ErrorMessage); err != nil {
+			return nil, err
+		}
+		item.ResponseBody = []byte(responseBody)
+		items = append(items, item)
+	}
+	return items, rows.Err()
+}
+
+func (r *batchJobRepository) DeleteFinishedBefore(ctx context.Context, before time.Time) (int64, error) {
+	res, err := r.db.ExecContext(ctx, `
+		DELETE FROM batch_jobs WHERE status NOT IN ($2, $3) AND updated_at < $1
+	`, before, service.BatchStatusInProgress, service.BatchStatusCancelling)
+	if err != nil {
+		return 0, err
+	}
+	return res.RowsAffected()
+}
+
+// --- scan helpers ---
+
+func marshalBatchMetadata(metadata map[string]string) (any, error) {
+	if len(metadata) == 0 {
+		return nil, nil
+	}
+	raw, err := json.Marshal(metadata)
+	if err != nil {
+		return nil, err
+	}
+	return string(raw), nil
+}
+
+func scanBatchJob(row scannable) (*service.BatchJob, error) {
+	job := &service.BatchJob{}
+	var metadata []byte
+	if err := row.Scan(
+		&job.ID, &job.UserID, &job.APIKeyID, &job.Endpoint, &job.CompletionWindow, &job.Status, &job.ClientIP, &metadata,
+		&job.TotalCount, &job.CompletedCount, &job.FailedCount, &job.ErrorMessage, &job.ExpiresAt, &job.InProgressAt,
+		&job.CompletedAt, &job.CancellingAt, &job.CancelledAt, &job.ExpiredAt, &job.CreatedAt, &job.UpdatedAt,
+	); err != nil {
+		return nil, err
+	}
+	if len(metadata) > 0 {
+		_ = json.Unmarshal(metadata, &job.Metadata)
+	}
+	return job, nil
+}
+
+func scanBatchJobs(rows *sql.Rows) ([]*service.BatchJob, error) {
+	var jobs []*service.BatchJob
+	for rows.Next() {
+		job, err := scanBatchJob(rows)
+		if err != nil {
+			return nil, err
+		}
+		jobs = append(jobs, job)
+	}
+	return jobs, rows.Err()
+}
@@ -0,0 +1,153 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/ShaohongDong/sub2api/internal/service"
+)
+
+type contentLogRepository struct {
+	db *sql.DB
+}
+
+func NewContentLogRepository(db *sql.DB) service.ContentLogRepository {
+	return &contentLogRepository{db: db}
+}
+
+// contentLogListColumns 列表查询的列（不含请求体与响应体）
+const contentLogListColumns = `
+  l.id, l.request_id, l.client_request_id, l.user_id, COALESCE(u.email, ''), l.api_key_id, l.group_id, l.account_id,
+  l.platform, l.model, l.request_path, l.stream, l.status_code, l.duration_ms,
+  l.request_body_bytes, l.response_body_bytes, l.created_at`
+
+func (r *contentLogRepository) Insert(ctx context.Context, entry *service.RequestContentLog) error {
+	return r.db.QueryRowContext(ctx, `
+		INSERT INTO request_content_logs (
+			request_id, client_request_id, user_id, api_key_id, group_id, account_id, platform, model,
+			request_path, stream, status_code, duration_ms, request_body, request_body_bytes,
+			response_body, response_body_bytes, created_at
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, NOW())
+		RETURNING id, created_at
+	`, entry.RequestID, entry.ClientRequestID, entry.UserID, entry.APIKeyID, entry.GroupID, entry.AccountID,
+		entry.Platform, entry.Model, entry.RequestPath, entry.Stream, entry.StatusCode, entry.DurationMs,
+		entry.RequestBody, entry.RequestBodyBytes, entry.ResponseBody, entry.ResponseBodyBytes,
+	).Scan(&entry.ID, &entry.CreatedAt)
+}
+
+func (r *contentLogRepository) GetByID(ctx context.Context, id int64) (*service.RequestContentLog, error) {
+	entry := &service.RequestContentLog{}
+	row := r.db.QueryRowContext(ctx, `
+SELECT`+contentLogListColumns+`, l.request_body, l.response_body
+FROM request_content_logs l
+LEFT JOIN users u ON u.id = l.user_id
+WHERE l.id = $1`, id)
+	dest := append(contentLogScanDest(entry), &entry.RequestBody, &entry.ResponseBody)
+	if err := row.Scan(dest...); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, service.ErrContentLogNotFound
+		}
+		return nil, err
+	}
+	return entry, nil
+}
+
+func (r *contentLogRepository) List(ctx context.Context, filter *service.ContentLogFilter) (*service.ContentLogList, error) {
+	page := filter.Page
+	if page <= 0 {
+		page = 1
+	}
+	pageSize := filter.PageSize
+	if pageSize <= 0 {
+		pageSize = 50
+	}
+
+	where, args := buildContentLogWhere(filter)
+	var total int64
+	if err := r.db.QueryRowContext(ctx, "SELECT COUNT(*) FROM request_content_logs l "+where, args...).Scan(&total); err != nil {
+		return nil, err
+	}
+
+	query := `
+SELECT` + contentLogListColumns + `
+FROM request_content_logs l
+LEFT JOIN users u ON u.id = l.user_id
+` + where + `
+ORDER BY l.created_at DESC, l.id DESC
+LIMIT $` + itoa(len(args)+1) + ` OFFSET $` + itoa(len(args)+2)
+
+	rows, err := r.db.QueryContext(ctx, query, append(args, pageSize, (page-1)*pageSize)...)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = rows.Close() }()
+
+	items := make([]*service.RequestContentLog, 0, pageSize)
+	for rows.Next() {
+		entry := &service.RequestContentLog{}
+		if err := rows.Scan(contentLogScanDest(entry)...); err != nil {
+			return nil, err
+		}
+		items = append(items, entry)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return &service.ContentLogList{Items: items, Total: total, Page: page, PageSize: pageSize}, nil
+}
+
+func (r *contentLogRepository) DeleteCreatedBefore(ctx context.Context, cutoff time.Time) (int64, error) {
+	res, err := r.db.ExecContext(ctx, `DELETE FROM request_content_logs WHERE created_at < $1`, cutoff)
+	if err != nil {
+		return 0, err
+	}
+	return res.RowsAffected()
+}
+
+func contentLogScanDest(entry *service.RequestContentLog) []any {
+	return []any{
+		&entry.ID, &entry.RequestID, &entry.ClientRequestID, &entry.UserID, &entry.UserEmail, &entry.APIKeyID,
+		&entry.GroupID, &entry.AccountID, &entry.Platform, &entry.Model, &entry.RequestPath, &entry.Stream,
+		&entry.StatusCode, &entry.DurationMs, &entry.RequestBodyBytes, &entry.ResponseBodyBytes, &entry.CreatedAt,
+	}
+}
+
+func buildContentLogWhere(filter *service.ContentLogFilter) (string, []any) {
+	clauses := make([]string, 0, 8)
+	args := make([]any, 0, 8)
+	add := func(clause string, arg any) {
+		args = append(args, arg)
+		clauses = append(clauses, strings.ReplaceAll(clause, "?", "$"+itoa(len(args))))
+	}
+	if filter.StartTime != nil {
+		add("l.created_at >= ?", *filter.StartTime)
+	}
+	if filter.EndTime != nil {
+		add("l.created_at < ?", *filter.EndTime)
+	}
+	if filter.UserID > 0 {
+		add("l.user_id = ?", filter.UserID)
+	}
+	if filter.APIKeyID > 0 {
+		add("l.api_key_id = ?", filter.APIKeyID)
+	}
+	if filter.Model != "" {
+		add("l.model = ?", filter.Model)
+	}
+	if filter.RequestID != "" {
+		add("(l.request_id = ? OR l.client_request_id = ?)", filter.RequestID)
+	}
+	if filter.StatusCode > 0 {
+		add("l.status_code = ?", filter.StatusCode)
+	}
+	if filter.Query != "" {
+		add("(l.request_body ILIKE ? OR l.response_body ILIKE ?)", "%"+filter.Query+"%")
+	}
+	if len(clauses) == 0 {
+		return "", args
+	}
+	return "WHERE " + strings.Join(clauses, " AND "), args
+}
@@ -0,0 +1,108 @@
+package repository
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/ShaohongDong/sub2api/internal/service"
+	"github.com/stretchr/testify/require"
+)
+
+func TestContentLogRepositoryInsert(t *testing.T) {
+	db, mock := newSQLMock(t)
+	repo := NewContentLogRepository(db)
+
+	createdAt := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	userID, keyID := int64(3), int64(5)
+	entry := &service.RequestContentLog{
+		RequestID:         "req-1",
+		UserID:            &userID,
+		APIKeyID:          &keyID,
+		Platform:          service.PlatformAnthropic,
+		Model:             "claude-sonnet-4",
+		RequestPath:       "/v1/messages",
+		StatusCode:        200,
+		DurationMs:        1200,
+		RequestBody:       `{"model":"claude-sonnet-4"}`,
+		RequestBodyBytes:  27,
+		ResponseBody:      `{"content":[]}`,
+		ResponseBodyBytes: 14,
+	}
+	mock.ExpectQuery("INSERT INTO request_content_logs").
+		WithArgs("req-1", "", &userID, &keyID, nil, nil, service.PlatformAnthropic, "claude-sonnet-4",
+			"/v1/messages", false, 200, 1200, entry.RequestBody, 27, entry.ResponseBody, 14).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "created_at"}).AddRow(int64(9), createdAt))
+
+	require.NoError(t, repo.Insert(context.Background(), entry))
+	require.Equal(t, int64(9), entry.ID)
+	require.Equal(t, createdAt, entry.CreatedAt)
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestContentLogRepositoryListFilters(t *testing.T) {
+	db, mock := newSQLMock(t)
+	repo := NewContentLogRepository(db)
+
+	start := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	mock.ExpectQuery(`SELECT COUNT\(\*\) FROM request_content_logs l WHERE l.created_at >= \$1 AND l.api_key_id = \$2 AND \(l.request_id = \$3 OR l.client_request_id = \$3\) AND \(l.request_body ILIKE \$4 OR l.response_body ILIKE \$4\)`).
+		WithArgs(start, int64(5), "req-2", "%refund%").
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(int64(1)))
+	mock.ExpectQuery(`FROM request_content_logs l\s+LEFT JOIN users u .* LIMIT \$5 OFFSET \$6`).
+		WithArgs(start, int64(5), "req-2", "%refund%", 20, 20).
+		WillReturnRows(sqlmock.NewRows([]string{
+			"id", "request_id", "client_request_id", "user_id", "email", "api_key_id", "group_id", "account_id",
+			"platform", "model", "request_path", "stream", "status_code", "duration_ms",
+			"request_body_bytes", "response_body_bytes", "created_at",
+		}).AddRow(int64(2), "req-2", "", int64(3), "user@example.com", int64(5), nil, int64(8),
+			"anthropic", "claude-sonnet-4", "/v1/messages", true, 200, 900, 120, 4096, start))
+
+	result, err := repo.List(context.Background(), &service.ContentLogFilter{
+		StartTime: &start,
+		APIKeyID:  5,
+		RequestID: "req-2",
+		Query:     "refund",
+		Page:      2,
+		PageSize:  20,
+	})
+	require.NoError(t, err)
+	require.Equal(t, int64(1), result.Total)
+	require.Len(t, result.Items, 1)
+	item := result.Items[0]
+	require.Equal(t, "user@example.com", item.UserEmail)
+	require.Equal(t, int64(8), *item.AccountID)
+	require.Nil(t, item.GroupID)
+	require.True(t, item.Stream)
+	require.Empty(t, item.RequestBody)
+	require.Empty(t, item.ResponseBody)
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestContentLogRepositoryGetByIDNotFound(t *testing.T) {
+	db, mock := newSQLMock(t)
+	repo := NewContentLogRepository(db)
+
+	mock.ExpectQuery(`FROM request_content_logs l\s+LEFT JOIN users u ON u.id = l.user_id\s+WHERE l.id = \$1`).
+		WithArgs(int64(42)).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}))
+
+	_, err := repo.GetByID(context.Background(), 42)
+	require.ErrorIs(t, err, service.ErrContentLogNotFound)
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestContentLogRepositoryDeleteCreatedBefore(t *testing.T) {
+	db, mock := newSQLMock(t)
+	repo := NewContentLogRepository(db)
+
+	cutoff := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	mock.ExpectExec(`DELETE FROM request_content_logs WHERE created_at < \$1`).
+		WithArgs(cutoff).
+		WillReturnResult(sqlmock.NewResult(0, 7))
+
+	n, err := repo.DeleteCreatedBefore(context.Background(), cutoff)
+	require.NoError(t, err)
+	require.Equal(t, int64(7), n)
+	require.NoError(t, mock.ExpectationsWereMet())
+}
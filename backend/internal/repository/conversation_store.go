@@ -0,0 +1,45 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/ShaohongDong/sub2api/internal/service"
+	"github.com/redis/go-redis/v9"
+)
+
+const conversationStoreKeyPrefix = "conversation_store:"
+
+type conversationStore struct {
+	rdb *redis.Client
+}
+
+// NewConversationStore 创建 Redis 会话存储（conversation_store.backend=redis 时使用）
+func NewConversationStore(rdb *redis.Client) service.ConversationStore {
+	return &conversationStore{rdb: rdb}
+}
+
+func (s *conversationStore) GetConversation(ctx context.Context, key string) (*service.StoredConversation, error) {
+	raw, err := s.rdb.Get(ctx, conversationStoreKeyPrefix+key).Bytes()
+	if err != nil {
+		if errors.Is(err, redis.Nil) {
+			return nil, service.ErrConversationNotFound
+		}
+		return nil, err
+	}
+	var conv service.StoredConversation
+	if err := json.Unmarshal(raw, &conv); err != nil {
+		return nil, err
+	}
+	return &conv, nil
+}
+
+func (s *conversationStore) SetConversation(ctx context.Context, key string, conv *service.StoredConversation, ttl time.Duration) error {
+	raw, err := json.Marshal(conv)
+	if err != nil {
+		return err
+	}
+	return s.rdb.Set(ctx, conversationStoreKeyPrefix+key, raw, ttl).Err()
+}
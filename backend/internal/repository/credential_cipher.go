@@ -0,0 +1,230 @@
+package repository
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/ShaohongDong/sub2api/internal/config"
+	"github.com/ShaohongDong/sub2api/internal/pkg/logger"
+)
+
+// credentialCipherPrefix 加密字段的前缀，完整格式：enc:v1:<key_id>:base64(nonce + ciphertext + tag)
+const credentialCipherPrefix = "enc:v1:"
+
+// CredentialCipher 按字段加解密账号凭证（AES-256-GCM，字段名作为附加数据）。
+// 主密钥用于加密，旧密钥仅用于解密，以支持密钥轮换；未加密的历史数据原样读取。
+// nil 表示未配置密钥：写入不加密，读取时仍保留已加密字段原文。
+type CredentialCipher struct {
+	primaryID string
+	aeads     map[string]cipher.AEAD
+	fields    map[string]struct{}
+}
+
+// NewCredentialCipher 根据 credential_encryption 配置创建加密器；未配置主密钥时返回 nil
+func NewCredentialCipher(cfg *config.Config) (*CredentialCipher, error) {
+	encCfg := cfg.CredentialEncryption
+	primary := strings.TrimSpace(encCfg.Key)
+	if primary == "" && strings.TrimSpace(encCfg.KeyFile) != "" {
+		raw, err := os.ReadFile(strings.TrimSpace(encCfg.KeyFile))
+		if err != nil {
+			return nil, fmt.Errorf("read credential encryption key file: %w", err)
+		}
+		primary = strings.TrimSpace(string(raw))
+	}
+	if primary == "" {
+		if strings.TrimSpace(encCfg.PreviousKeys) != "" {
+			return nil, fmt.Errorf("credential_encryption.previous_keys requires a primary key")
+		}
+		return nil, nil
+	}
+
+	primaryID := strings.TrimSpace(encCfg.KeyID)
+	if primaryID == "" {
+		primaryID = "k1"
+	}
+	if strings.Contains(primaryID, ":") {
+		return nil, fmt.Errorf("credential_encryption.key_id must not contain ':'")
+	}
+
+	c := &CredentialCipher{
+		primaryID: primaryID,
+		aeads:     make(map[string]cipher.AEAD),
+		fields:    make(map[string]struct{}, len(encCfg.Fields)),
+	}
+	if err := c.addKey(primaryID, primary); err != nil {
+		return nil, err
+	}
+	for _, item := range strings.Split(encCfg.PreviousKeys, ",") {
+		item = strings.TrimSpace(item)
+		if item == "" {
+			continue
+		}
+		id, key, ok := strings.Cut(item, ":")
+		if !ok || strings.TrimSpace(id) == "" {
+			return nil, fmt.Errorf("credential_encryption.previous_keys entry must be id:hex")
+		}
+		id = strings.TrimSpace(id)
+		if _, exists := c.aeads[id]; exists {
+			return nil, fmt.Errorf("duplicate credential encryption key id: %s", id)
+		}
+		if err := c.addKey(id, key); err != nil {
+			return nil, err
+		}
+	}
+	for _, field := range encCfg.Fields {
+		if field = strings.TrimSpace(field); field != "" {
+			c.fields[field] = struct{}{}
+		}
+	}
+	return c, nil
+}
+
+func (c *CredentialCipher) addKey(id, hexKey string) error {
+	key, err := hex.DecodeString(strings.TrimSpace(hexKey))
+	if err != nil {
+		return fmt.Errorf("invalid credential encryption key %s: %w", id, err)
+	}
+	if len(key) != 32 {
+		return fmt.Errorf("credential encryption key %s must be 32 bytes (64 hex chars), got %d bytes", id, len(key))
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return fmt.Errorf("create cipher: %w", err)
+	}
+	gcm, err := cipher.NewGCM(block)
+	if err != nil {
+		return fmt.Errorf("create gcm: %w", err)
+	}
+	c.aeads[id] = gcm
+	return nil
+}
+
+// parseCredentialCiphertext 拆分密文，返回密钥 ID 与 base64 数据
+func parseCredentialCiphertext(value string) (string, string, bool) {
+	rest, ok := strings.CutPrefix(value, credentialCipherPrefix)
+	if !ok {
+		return "", "", false
+	}
+	return strings.Cut(rest, ":")
+}
+
+func (c *CredentialCipher) encryptValue(field, plaintext string) (string, error) {
+	gcm := c.aeads[c.primaryID]
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err := io.ReadFull(rand.Reader, nonce); err != nil {
+		return "", fmt.Errorf("generate nonce: %w", err)
+	}
+	sealed := gcm.Seal(nonce, nonce, []byte(plaintext), []byte(field))
+	return credentialCipherPrefix + c.primaryID + ":" + base64.StdEncoding.EncodeToString(sealed), nil
+}
+
+func (c *CredentialCipher) decryptValue(field, value string) (string, error) {
+	id, payload, ok := parseCredentialCiphertext(value)
+	if !ok {
+		return "", fmt.Errorf("malformed ciphertext")
+	}
+	gcm, ok := c.aeads[id]
+	if !ok {
+		return "", fmt.Errorf("unknown key id: %s", id)
+	}
+	data, err := base64.StdEncoding.DecodeString(payload)
+	if err != nil {
+		return "", fmt.Errorf("decode base64: %w", err)
+	}
+	if len(data) < gcm.NonceSize() {
+		return "", fmt.Errorf("ciphertext too short")
+	}
+	plaintext, err := gcm.Open(nil, data[:gcm.NonceSize()], data[gcm.NonceSize():], []byte(field))
+	if err != nil {
+		return "", fmt.Errorf("decrypt: %w", err)
+	}
+	return string(plaintext), nil
+}
+
+// EncryptCredentials 返回加密后的凭证副本：明文敏感字段使用主密钥加密，已加密字段保持不变
+func (c *CredentialCipher) EncryptCredentials(credentials map[string]any) (map[string]any, error) {
+	if c == nil || len(credentials) == 0 {
+		return credentials, nil
+	}
+	out := make(map[string]any, len(credentials))
+	for key, value := range credentials {
+		out[key] = value
+		s, ok := value.(string)
+		if !ok || s == "" || strings.HasPrefix(s, credentialCipherPrefix) {
+			continue
+		}
+		if _, sensitive := c.fields[key]; !sensitive {
+			continue
+		}
+		encrypted, err := c.encryptValue(key, s)
+		if err != nil {
+			return nil, fmt.Errorf("encrypt credential %s: %w", key, err)
+		}
+		out[key] = encrypted
+	}
+	return out, nil
+}
+
+// DecryptCredentials 解密凭证中的加密字段（原 map 不会被修改）；无法解密的字段保留原文并记录日志
+func (c *CredentialCipher) DecryptCredentials(credentials map[string]any) map[string]any {
+	if len(credentials) == 0 {
+		return credentials
+	}
+	var out map[string]any
+	for key, value := range credentials {
+		s, ok := value.(string)
+		if !ok || !strings.HasPrefix(s, credentialCipherPrefix) {
+			continue
+		}
+		if c == nil {
+			logger.LegacyPrintf("repository.credential_cipher", "credential %s is encrypted but credential_encryption.key is not configured", key)
+			continue
+		}
+		plaintext, err := c.decryptValue(key, s)
+		if err != nil {
+			logger.LegacyPrintf("repository.credential_cipher", "decrypt credential %s failed: %v", key, err)
+			continue
+		}
+		if out == nil {
+			out = make(map[string]any, len(credentials))
+			for k, v := range credentials {
+				out[k] = v
+			}
+		}
+		out[key] = plaintext
+	}
+	if out == nil {
+		return credentials
+	}
+	return out
+}
+
+// needsReencrypt 判断凭证是否包含明文敏感字段或非主密钥加密的字段
+func (c *CredentialCipher) needsReencrypt(credentials map[string]any) bool {
+	if c == nil {
+		return false
+	}
+	for key, value := range credentials {
+		s, ok := value.(string)
+		if !ok || s == "" {
+			continue
+		}
+		if id, _, encrypted := parseCredentialCiphertext(s); encrypted {
+			if id != c.primaryID {
+				return true
+			}
+			continue
+		}
+		if _, sensitive := c.fields[key]; sensitive {
+			return true
+		}
+	}
+	return false
+}
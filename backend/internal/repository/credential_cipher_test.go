@@ -0,0 +1,117 @@
+//go:build unit
+
+package repository
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/ShaohongDong/sub2api/internal/config"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	testCredentialKeyA = "000102030405060708090a0b0c0d0e0f101112131415161718191a1b1c1d1e1f"
+	testCredentialKeyB = "1f1e1d1c1b1a191817161514131211100f0e0d0c0b0a09080706050403020100"
+)
+
+func newTestCredentialCipher(t *testing.T, encCfg config.CredentialEncryptionConfig) *CredentialCipher {
+	t.Helper()
+	if encCfg.Fields == nil {
+		encCfg.Fields = []string{"access_token", "refresh_token"}
+	}
+	c, err := NewCredentialCipher(&config.Config{CredentialEncryption: encCfg})
+	require.NoError(t, err)
+	return c
+}
+
+func TestCredentialCipher_RoundTrip(t *testing.T) {
+	c := newTestCredentialCipher(t, config.CredentialEncryptionConfig{Key: testCredentialKeyA, KeyID: "a"})
+	plain := map[string]any{"access_token": "at", "refresh_token": "rt", "email": "x@example.com", "expires_at": 123}
+
+	enc, err := c.EncryptCredentials(plain)
+	require.NoError(t, err)
+	require.True(t, strings.HasPrefix(enc["access_token"].(string), "enc:v1:a:"))
+	require.True(t, strings.HasPrefix(enc["refresh_token"].(string), "enc:v1:a:"))
+	require.Equal(t, "x@example.com", enc["email"])
+	require.Equal(t, 123, enc["expires_at"])
+	require.Equal(t, "at", plain["access_token"], "input must not be mutated")
+
+	// 已加密字段不会被二次加密
+	again, err := c.EncryptCredentials(enc)
+	require.NoError(t, err)
+	require.Equal(t, enc["access_token"], again["access_token"])
+
+	require.Equal(t, plain, c.DecryptCredentials(enc))
+	require.False(t, c.needsReencrypt(enc))
+	require.True(t, c.needsReencrypt(plain))
+}
+
+func TestCredentialCipher_FieldBoundCiphertext(t *testing.T) {
+	c := newTestCredentialCipher(t, config.CredentialEncryptionConfig{Key: testCredentialKeyA})
+	enc, err := c.EncryptCredentials(map[string]any{"access_token": "at"})
+	require.NoError(t, err)
+
+	// 把密文挪到其他字段后无法解密，保留原文
+	moved := map[string]any{"refresh_token": enc["access_token"]}
+	require.Equal(t, moved, c.DecryptCredentials(moved))
+}
+
+func TestCredentialCipher_KeyRotation(t *testing.T) {
+	oldCipher := newTestCredentialCipher(t, config.CredentialEncryptionConfig{Key: testCredentialKeyA, KeyID: "old"})
+	enc, err := oldCipher.EncryptCredentials(map[string]any{"refresh_token": "rt"})
+	require.NoError(t, err)
+
+	rotated := newTestCredentialCipher(t, config.CredentialEncryptionConfig{
+		Key:          testCredentialKeyB,
+		KeyID:        "new",
+		PreviousKeys: "old:" + testCredentialKeyA,
+	})
+	require.Equal(t, "rt", rotated.DecryptCredentials(enc)["refresh_token"])
+	require.True(t, rotated.needsReencrypt(enc))
+
+	reencrypted, err := rotated.EncryptCredentials(rotated.DecryptCredentials(enc))
+	require.NoError(t, err)
+	require.True(t, strings.HasPrefix(reencrypted["refresh_token"].(string), "enc:v1:new:"))
+	require.False(t, hasForeignCiphertext(reencrypted, "new"))
+
+	// 缺少旧密钥时无法解密，标记为失败
+	withoutOld := newTestCredentialCipher(t, config.CredentialEncryptionConfig{Key: testCredentialKeyB, KeyID: "new"})
+	require.True(t, hasForeignCiphertext(withoutOld.DecryptCredentials(enc), "new"))
+}
+
+func TestCredentialCipher_NilPassesThrough(t *testing.T) {
+	var c *CredentialCipher
+	plain := map[string]any{"access_token": "at"}
+	out, err := c.EncryptCredentials(plain)
+	require.NoError(t, err)
+	require.Equal(t, plain, out)
+	require.Equal(t, plain, c.DecryptCredentials(plain))
+	require.False(t, c.needsReencrypt(plain))
+
+	disabled, err := NewCredentialCipher(&config.Config{})
+	require.NoError(t, err)
+	require.Nil(t, disabled)
+}
+
+func TestNewCredentialCipher_KeyFileAndValidation(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "key")
+	require.NoError(t, os.WriteFile(path, []byte(testCredentialKeyA+"\n"), 0o600))
+	c := newTestCredentialCipher(t, config.CredentialEncryptionConfig{KeyFile: path})
+	require.NotNil(t, c)
+	require.Equal(t, "k1", c.primaryID)
+
+	for _, encCfg := range []config.CredentialEncryptionConfig{
+		{Key: "abcd"},
+		{Key: testCredentialKeyA, KeyID: "a:b"},
+		{Key: testCredentialKeyA, PreviousKeys: "missing-separator"},
+		{Key: testCredentialKeyA, KeyID: "a", PreviousKeys: "a:" + testCredentialKeyB},
+		{PreviousKeys: "old:" + testCredentialKeyA},
+		{KeyFile: filepath.Join(t.TempDir(), "absent")},
+	} {
+		_, err := NewCredentialCipher(&config.Config{CredentialEncryption: encCfg})
+		require.Error(t, err, "%+v", encCfg)
+	}
+}
@@ -0,0 +1,106 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	dbent "github.com/ShaohongDong/sub2api/ent"
+	dbaccount "github.com/ShaohongDong/sub2api/ent/account"
+)
+
+const credentialReencryptBatchSize = 200
+
+// CredentialReencryptResult 凭证重加密（明文迁移 / 密钥轮换）结果
+type CredentialReencryptResult struct {
+	Scanned int `json:"scanned"`
+	// Updated 已使用主密钥重新加密的账号数（dry-run 时为待处理数）
+	Updated int `json:"updated"`
+	// Conflicts 读取后凭证被并发修改而跳过的账号数，重新执行即可
+	Conflicts int `json:"conflicts"`
+	// Failed 存在无法解密字段（未知密钥 ID 或密文损坏）的账号 ID
+	Failed []int64 `json:"failed,omitempty"`
+}
+
+// ReencryptAccountCredentials 使用主密钥重新加密所有账号凭证：
+// 明文敏感字段被加密，旧密钥加密的字段被解密后用主密钥重新加密。
+// 仅在凭证未被并发修改时写回（比较原 JSONB），不会覆盖刷新中的 token。
+func ReencryptAccountCredentials(ctx context.Context, client *dbent.Client, sqlDB *sql.DB, c *CredentialCipher, dryRun bool) (CredentialReencryptResult, error) {
+	result := CredentialReencryptResult{}
+	if c == nil {
+		return result, fmt.Errorf("credential encryption key is not configured")
+	}
+
+	var lastID int64
+	for {
+		accounts, err := client.Account.Query().
+			Where(dbaccount.IDGT(lastID)).
+			Order(dbent.Asc(dbaccount.FieldID)).
+			Limit(credentialReencryptBatchSize).
+			Select(dbaccount.FieldID, dbaccount.FieldCredentials).
+			All(ctx)
+		if err != nil {
+			return result, err
+		}
+		if len(accounts) == 0 {
+			return result, nil
+		}
+
+		for _, acc := range accounts {
+			lastID = acc.ID
+			result.Scanned++
+			if !c.needsReencrypt(acc.Credentials) {
+				continue
+			}
+
+			reencrypted, err := c.EncryptCredentials(c.DecryptCredentials(acc.Credentials))
+			if err != nil {
+				return result, fmt.Errorf("account %d: %w", acc.ID, err)
+			}
+			if hasForeignCiphertext(reencrypted, c.primaryID) {
+				result.Failed = append(result.Failed, acc.ID)
+				continue
+			}
+			if dryRun {
+				result.Updated++
+				continue
+			}
+
+			oldPayload, err := json.Marshal(acc.Credentials)
+			if err != nil {
+				return result, err
+			}
+			newPayload, err := json.Marshal(reencrypted)
+			if err != nil {
+				return result, err
+			}
+			res, err := sqlDB.ExecContext(ctx,
+				"UPDATE accounts SET credentials = $1::jsonb WHERE id = $2 AND credentials = $3::jsonb",
+				newPayload, acc.ID, oldPayload)
+			if err != nil {
+				return result, fmt.Errorf("account %d: %w", acc.ID, err)
+			}
+			if affected, _ := res.RowsAffected(); affected == 0 {
+				result.Conflicts++
+				continue
+			}
+			result.Updated++
+		}
+	}
+}
+
+// hasForeignCiphertext 判断凭证是否仍包含非主密钥加密的字段（解密失败）
+func hasForeignCiphertext(credentials map[string]any, primaryID string) bool {
+	for _, value := range credentials {
+		s, ok := value.(string)
+		if !ok || !strings.HasPrefix(s, credentialCipherPrefix) {
+			continue
+		}
+		if id, _, _ := parseCredentialCiphertext(s); id != primaryID {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,220 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/ShaohongDong/sub2api/internal/service"
+	"github.com/lib/pq"
+)
+
+type deadLetterRepository struct {
+	db *sql.DB
+}
+
+func NewDeadLetterRepository(db *sql.DB) service.DeadLetterRepository {
+	return &deadLetterRepository{db: db}
+}
+
+// deadLetterListColumns 列表查询的列（不含请求体与响应体）
+const deadLetterListColumns = `
+  d.id, d.request_id, d.client_request_id, d.user_id, COALESCE(u.email, ''), d.api_key_id, d.group_id, d.account_id,
+  d.platform, d.model, d.request_path, d.stream, d.user_agent, d.request_body_bytes,
+  d.status_code, d.upstream_status_code, d.attempt_count, d.failure_reason,
+  d.status, d.replay_count, d.last_replay_at, d.last_replay_by_user_id, d.last_replay_account_id,
+  d.last_replay_status_code, d.last_replay_error, d.created_at, d.updated_at`
+
+func (r *deadLetterRepository) Insert(ctx context.Context, entry *service.DeadLetterRequest) error {
+	return r.db.QueryRowContext(ctx, `
+		INSERT INTO dead_letter_requests (
+			request_id, client_request_id, user_id, api_key_id, group_id, account_id, platform, model,
+			request_path, stream, user_agent, request_headers, request_body, request_body_bytes,
+			status_code, upstream_status_code, attempt_count, failure_reason, status, created_at, updated_at
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18, $19, NOW(), NOW())
+		RETURNING id, created_at, updated_at
+	`, entry.RequestID, entry.ClientRequestID, entry.UserID, entry.APIKeyID, entry.GroupID, entry.AccountID,
+		entry.Platform, entry.Model, entry.RequestPath, entry.Stream, entry.UserAgent, entry.RequestHeaders,
+		entry.RequestBody, entry.RequestBodyBytes, entry.StatusCode, entry.UpstreamStatusCode, entry.AttemptCount,
+		entry.FailureReason, entry.Status,
+	).Scan(&entry.ID, &entry.CreatedAt, &entry.UpdatedAt)
+}
+
+func (r *deadLetterRepository) GetByID(ctx context.Context, id int64) (*service.DeadLetterRequest, error) {
+	entry := &service.DeadLetterRequest{}
+	row := r.db.QueryRowContext(ctx, `
+SELECT`+deadLetterListColumns+`, d.request_headers, d.request_body, d.response_body
+FROM dead_letter_requests d
+LEFT JOIN users u ON u.id = d.user_id
+WHERE d.id = $1`, id)
+	dest := append(deadLetterScanDest(entry), &entry.RequestHeaders, &entry.RequestBody, &entry.ResponseBody)
+	if err := row.Scan(dest...); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, service.ErrDeadLetterNotFound
+		}
+		return nil, err
+	}
+	return entry, nil
+}
+
+func (r *deadLetterRepository) List(ctx context.Context, filter *service.DeadLetterFilter) (*service.DeadLetterList, error) {
+	page := filter.Page
+	if page <= 0 {
+		page = 1
+	}
+	pageSize := filter.PageSize
+	if pageSize <= 0 {
+		pageSize = 50
+	}
+
+	where, args := buildDeadLetterWhere(filter)
+	var total int64
+	if err := r.db.QueryRowContext(ctx, "SELECT COUNT(*) FROM dead_letter_requests d "+where, args...).Scan(&total); err != nil {
+		return nil, err
+	}
+
+	query := `
+SELECT` + deadLetterListColumns + `
+FROM dead_letter_requests d
+LEFT JOIN users u ON u.id = d.user_id
+` + where + `
+ORDER BY d.created_at DESC, d.id DESC
+LIMIT $` + itoa(len(args)+1) + ` OFFSET $` + itoa(len(args)+2)
+
+	rows, err := r.db.QueryContext(ctx, query, append(args, pageSize, (page-1)*pageSize)...)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = rows.Close() }()
+
+	items := make([]*service.DeadLetterRequest, 0, pageSize)
+	for rows.Next() {
+		entry := &service.DeadLetterRequest{}
+		if err := rows.Scan(deadLetterScanDest(entry)...); err != nil {
+			return nil, err
+		}
+		items = append(items, entry)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return &service.DeadLetterList{Items: items, Total: total, Page: page, PageSize: pageSize}, nil
+}
+
+func (r *deadLetterRepository) ClaimForReplay(ctx context.Context, ids []int64, operatorID int64) ([]int64, error) {
+	rows, err := r.db.QueryContext(ctx, `
+		UPDATE dead_letter_requests
+		SET status = $2, last_replay_at = NOW(), last_replay_by_user_id = $3, updated_at = NOW()
+		WHERE id = ANY($1) AND status IN ($4, $5) AND request_body <> ''
+		RETURNING id
+	`, pq.Array(ids), service.DeadLetterStatusReplaying, operatorID,
+		service.DeadLetterStatusPending, service.DeadLetterStatusFailed)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = rows.Close() }()
+
+	claimed := make([]int64, 0, len(ids))
+	for rows.Next() {
+		var id int64
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		claimed = append(claimed, id)
+	}
+	return claimed, rows.Err()
+}
+
+func (r *deadLetterRepository) FinishReplay(ctx context.Context, outcome *service.DeadLetterReplayOutcome) error {
+	_, err := r.db.ExecContext(ctx, `
+		UPDATE dead_letter_requests
+		SET status = $2, replay_count = replay_count + 1, last_replay_account_id = $3,
+		    last_replay_status_code = $4, last_replay_error = $5, response_body = $6, updated_at = NOW()
+		WHERE id = $1
+	`, outcome.ID, outcome.Status, outcome.AccountID, outcome.StatusCode, outcome.Error, outcome.ResponseBody)
+	return err
+}
+
+func (r *deadLetterRepository) Discard(ctx context.Context, ids []int64) (int64, error) {
+	res, err := r.db.ExecContext(ctx, `
+		UPDATE dead_letter_requests
+		SET status = $2, updated_at = NOW()
+		WHERE id = ANY($1) AND status <> $3
+	`, pq.Array(ids), service.DeadLetterStatusDiscarded, service.DeadLetterStatusReplaying)
+	if err != nil {
+		return 0, err
+	}
+	return res.RowsAffected()
+}
+
+func (r *deadLetterRepository) DeleteCreatedBefore(ctx context.Context, cutoff time.Time) (int64, error) {
+	res, err := r.db.ExecContext(ctx, `
+		DELETE FROM dead_letter_requests WHERE created_at < $1 AND status <> $2
+	`, cutoff, service.DeadLetterStatusReplaying)
+	if err != nil {
+		return 0, err
+	}
+	return res.RowsAffected()
+}
+
+func (r *deadLetterRepository) FailStaleReplays(ctx context.Context, startedBefore time.Time) (int64, error) {
+	res, err := r.db.ExecContext(ctx, `
+		UPDATE dead_letter_requests
+		SET status = $1, replay_count = replay_count + 1, last_replay_error = 'replay interrupted', updated_at = NOW()
+		WHERE status = $2 AND last_replay_at < $3
+	`, service.DeadLetterStatusFailed, service.DeadLetterStatusReplaying, startedBefore)
+	if err != nil {
+		return 0, err
+	}
+	return res.RowsAffected()
+}
+
+func deadLetterScanDest(entry *service.DeadLetterRequest) []any {
+	return []any{
+		&entry.ID, &entry.RequestID, &entry.ClientRequestID, &entry.UserID, &entry.UserEmail, &entry.APIKeyID,
+		&entry.GroupID, &entry.AccountID, &entry.Platform, &entry.Model, &entry.RequestPath, &entry.Stream,
+		&entry.UserAgent, &entry.RequestBodyBytes, &entry.StatusCode, &entry.UpstreamStatusCode,
+		&entry.AttemptCount, &entry.FailureReason, &entry.Status, &entry.ReplayCount, &entry.LastReplayAt,
+		&entry.LastReplayByUserID, &entry.LastReplayAccountID, &entry.LastReplayStatusCode,
+		&entry.LastReplayError, &entry.CreatedAt, &entry.UpdatedAt,
+	}
+}
+
+func buildDeadLetterWhere(filter *service.DeadLetterFilter) (string, []any) {
+	clauses := make([]string, 0, 8)
+	args := make([]any, 0, 8)
+	add := func(clause string, arg any) {
+		args = append(args, arg)
+		clauses = append(clauses, strings.ReplaceAll(clause, "?", "$"+itoa(len(args))))
+	}
+	if filter.StartTime != nil {
+		add("d.created_at >= ?", *filter.StartTime)
+	}
+	if filter.EndTime != nil {
+		add("d.created_at < ?", *filter.EndTime)
+	}
+	if filter.Status != "" {
+		add("d.status = ?", filter.Status)
+	}
+	if filter.UserID > 0 {
+		add("d.user_id = ?", filter.UserID)
+	}
+	if filter.APIKeyID > 0 {
+		add("d.api_key_id = ?", filter.APIKeyID)
+	}
+	if filter.GroupID > 0 {
+		add("d.group_id = ?", filter.GroupID)
+	}
+	if filter.AccountID > 0 {
+		add("d.account_id = ?", filter.AccountID)
+	}
+	if filter.Model != "" {
+		add("d.model = ?", filter.Model)
+	}
+	if len(clauses) == 0 {
+		return "", args
+	}
+	return "WHERE " + strings.Join(clauses, " AND "), args
+}
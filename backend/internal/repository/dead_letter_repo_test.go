@@ -0,0 +1,113 @@
+package repository
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/ShaohongDong/sub2api/internal/service"
+	"github.com/lib/pq"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDeadLetterRepositoryInsert(t *testing.T) {
+	db, mock := newSQLMock(t)
+	repo := NewDeadLetterRepository(db)
+
+	createdAt := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	userID, groupID := int64(3), int64(4)
+	entry := &service.DeadLetterRequest{
+		RequestID:          "req-1",
+		UserID:             &userID,
+		GroupID:            &groupID,
+		Platform:           service.PlatformAnthropic,
+		Model:              "claude-sonnet-4",
+		RequestPath:        "/v1/messages",
+		RequestBody:        `{"model":"claude-sonnet-4"}`,
+		RequestBodyBytes:   27,
+		StatusCode:         502,
+		UpstreamStatusCode: 529,
+		AttemptCount:       3,
+		FailureReason:      "upstream 529: overloaded",
+		Status:             service.DeadLetterStatusPending,
+	}
+	mock.ExpectQuery("INSERT INTO dead_letter_requests").
+		WithArgs("req-1", "", &userID, nil, &groupID, nil, service.PlatformAnthropic, "claude-sonnet-4",
+			"/v1/messages", false, "", "", entry.RequestBody, 27, 502, 529, 3, "upstream 529: overloaded",
+			service.DeadLetterStatusPending).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "created_at", "updated_at"}).AddRow(int64(9), createdAt, createdAt))
+
+	require.NoError(t, repo.Insert(context.Background(), entry))
+	require.Equal(t, int64(9), entry.ID)
+	require.Equal(t, createdAt, entry.CreatedAt)
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestDeadLetterRepositoryListFilters(t *testing.T) {
+	db, mock := newSQLMock(t)
+	repo := NewDeadLetterRepository(db)
+
+	start := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	mock.ExpectQuery(`SELECT COUNT\(\*\) FROM dead_letter_requests d WHERE d.created_at >= \$1 AND d.status = \$2 AND d.api_key_id = \$3`).
+		WithArgs(start, service.DeadLetterStatusPending, int64(5)).
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(int64(1)))
+	mock.ExpectQuery(`FROM dead_letter_requests d\s+LEFT JOIN users u .* LIMIT \$4 OFFSET \$5`).
+		WithArgs(start, service.DeadLetterStatusPending, int64(5), 20, 0).
+		WillReturnRows(sqlmock.NewRows([]string{
+			"id", "request_id", "client_request_id", "user_id", "email", "api_key_id", "group_id", "account_id",
+			"platform", "model", "request_path", "stream", "user_agent", "request_body_bytes",
+			"status_code", "upstream_status_code", "attempt_count", "failure_reason",
+			"status", "replay_count", "last_replay_at", "last_replay_by_user_id", "last_replay_account_id",
+			"last_replay_status_code", "last_replay_error", "created_at", "updated_at",
+		}).AddRow(int64(2), "req-2", "", int64(3), "user@example.com", int64(5), int64(4), int64(8),
+			"anthropic", "claude-sonnet-4", "/v1/messages", true, "cli", 120,
+			502, 503, 2, "upstream 503: unavailable",
+			"pending", 0, nil, nil, nil, 0, "", start, start))
+
+	result, err := repo.List(context.Background(), &service.DeadLetterFilter{
+		StartTime: &start,
+		Status:    service.DeadLetterStatusPending,
+		APIKeyID:  5,
+		Page:      1,
+		PageSize:  20,
+	})
+	require.NoError(t, err)
+	require.Equal(t, int64(1), result.Total)
+	require.Len(t, result.Items, 1)
+	item := result.Items[0]
+	require.Equal(t, "user@example.com", item.UserEmail)
+	require.Equal(t, int64(8), *item.AccountID)
+	require.True(t, item.Stream)
+	require.Nil(t, item.LastReplayAt)
+	require.Empty(t, item.RequestBody)
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestDeadLetterRepositoryClaimForReplay(t *testing.T) {
+	db, mock := newSQLMock(t)
+	repo := NewDeadLetterRepository(db)
+
+	mock.ExpectQuery(`UPDATE dead_letter_requests\s+SET status = \$2.*WHERE id = ANY\(\$1\) AND status IN \(\$4, \$5\) AND request_body <> ''`).
+		WithArgs(pq.Array([]int64{1, 2, 3}), service.DeadLetterStatusReplaying, int64(7),
+			service.DeadLetterStatusPending, service.DeadLetterStatusFailed).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(int64(1)).AddRow(int64(3)))
+
+	claimed, err := repo.ClaimForReplay(context.Background(), []int64{1, 2, 3}, 7)
+	require.NoError(t, err)
+	require.Equal(t, []int64{1, 3}, claimed)
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestDeadLetterRepositoryGetByIDNotFound(t *testing.T) {
+	db, mock := newSQLMock(t)
+	repo := NewDeadLetterRepository(db)
+
+	mock.ExpectQuery(`FROM dead_letter_requests d\s+LEFT JOIN users u ON u.id = d.user_id\s+WHERE d.id = \$1`).
+		WithArgs(int64(42)).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}))
+
+	_, err := repo.GetByID(context.Background(), 42)
+	require.ErrorIs(t, err, service.ErrDeadLetterNotFound)
+	require.NoError(t, mock.ExpectationsWereMet())
+}
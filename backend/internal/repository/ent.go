@@ -97,3 +97,47 @@ func InitEnt(cfg *config.Config) (*ent.Client, *sql.DB, error) {
 
 	return client, drv.DB(), nil
 }
+
+// RunMigrations 只执行数据库迁移后关闭连接，不创建 Ent 客户端、不补齐启动数据。
+// 用于 --migrate-only：在滚动发布前单独完成 schema 升级。
+func RunMigrations(cfg *config.Config) error {
+	db, err := openMigrationDB(cfg)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = db.Close() }()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Minute)
+	defer cancel()
+	return applyMigrationsFS(ctx, db, migrations.FS)
+}
+
+// RunMigrationRollback 回滚最近应用的 steps 个迁移，返回已回滚的迁移文件名。
+func RunMigrationRollback(cfg *config.Config, steps int) ([]string, error) {
+	db, err := openMigrationDB(cfg)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = db.Close() }()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Minute)
+	defer cancel()
+	return rollbackMigrationsFS(ctx, db, migrations.FS, steps)
+}
+
+func openMigrationDB(cfg *config.Config) (*sql.DB, error) {
+	if err := timezone.Init(cfg.Timezone); err != nil {
+		return nil, err
+	}
+	db, err := sql.Open("postgres", cfg.Database.DSNWithTimezone(cfg.Timezone))
+	if err != nil {
+		return nil, err
+	}
+	pingCtx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	if err := db.PingContext(pingCtx); err != nil {
+		_ = db.Close()
+		return nil, fmt.Errorf("connect database: %w", err)
+	}
+	return db, nil
+}
@@ -0,0 +1,52 @@
+package repository
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/ShaohongDong/sub2api/internal/config"
+	"github.com/ShaohongDong/sub2api/internal/pkg/hooks"
+	"github.com/ShaohongDong/sub2api/internal/pkg/httpclient"
+	"github.com/ShaohongDong/sub2api/internal/service"
+)
+
+// ProvideGatewayHookRunner 按 gateway.hooks 构造请求扩展点执行器；未启用或未配置处理器时返回 nil。
+// Webhook 地址只能由管理员在配置文件中设置，因此允许指向内网服务；单次调用超时由各处理器的 timeout_ms 控制。
+func ProvideGatewayHookRunner(cfg *config.Config) (*hooks.Runner, error) {
+	if cfg == nil || !cfg.Gateway.Hooks.Enabled || len(cfg.Gateway.Hooks.Handlers) == 0 {
+		return nil, nil
+	}
+	client, err := httpclient.GetClient(httpclient.Options{Timeout: time.Minute})
+	if err != nil {
+		client = &http.Client{Timeout: time.Minute}
+	}
+
+	specs := make([]hooks.Spec, 0, len(cfg.Gateway.Hooks.Handlers))
+	for _, h := range cfg.Gateway.Hooks.Handlers {
+		stages := make([]hooks.Stage, 0, len(h.Stages))
+		for _, stage := range h.Stages {
+			stages = append(stages, hooks.Stage(stage))
+		}
+		specs = append(specs, hooks.Spec{
+			Name:       h.Name,
+			Type:       h.Type,
+			Stages:     stages,
+			Paths:      h.Paths,
+			Timeout:    time.Duration(h.TimeoutMS) * time.Millisecond,
+			FailOpen:   h.FailOpen,
+			Plugin:     h.Plugin,
+			PluginPath: h.PluginPath,
+			Options:    h.Options,
+			URL:        h.URL,
+			Headers:    h.Headers,
+			Command:    h.Command,
+			Args:       h.Args,
+		})
+	}
+	return hooks.NewRunner(specs, client)
+}
+
+// ProvideHTTPUpstream 创建上游 HTTP 客户端；配置了 pre_upstream 扩展时在每次上游请求前执行。
+func ProvideHTTPUpstream(cfg *config.Config, hookRunner *hooks.Runner) service.HTTPUpstream {
+	return hooks.WrapUpstream(NewHTTPUpstream(cfg), hookRunner)
+}
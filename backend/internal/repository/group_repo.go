@@ -61,13 +61,24 @@ func (r *groupRepository) Create(ctx context.Context, groupIn *service.Group) er
 		SetMcpXMLInject(groupIn.MCPXMLInject).
 		SetSoraStorageQuotaBytes(groupIn.SoraStorageQuotaBytes).
 		SetAllowMessagesDispatch(groupIn.AllowMessagesDispatch).
-		SetDefaultMappedModel(groupIn.DefaultMappedModel)
+		SetDefaultMappedModel(groupIn.DefaultMappedModel).
+		SetSchedulingStrategy(groupIn.SchedulingStrategy)
 
 	// 设置模型路由配置
 	if groupIn.ModelRouting != nil {
 		builder = builder.SetModelRouting(groupIn.ModelRouting)
 	}
 
+	// 设置模型别名
+	if groupIn.ModelAliases != nil {
+		builder = builder.SetModelAliases(groupIn.ModelAliases)
+	}
+
+	// 设置跨平台降级链
+	if len(groupIn.FallbackChain) > 0 {
+		builder = builder.SetFallbackChain(groupIn.FallbackChain)
+	}
+
 	// 设置支持的模型系列（始终设置，空数组表示不限制）
 	builder = builder.SetSupportedModelScopes(groupIn.SupportedModelScopes)
 
@@ -129,7 +140,8 @@ func (r *groupRepository) Update(ctx context.Context, groupIn *service.Group) er
 		SetMcpXMLInject(groupIn.MCPXMLInject).
 		SetSoraStorageQuotaBytes(groupIn.SoraStorageQuotaBytes).
 		SetAllowMessagesDispatch(groupIn.AllowMessagesDispatch).
-		SetDefaultMappedModel(groupIn.DefaultMappedModel)
+		SetDefaultMappedModel(groupIn.DefaultMappedModel).
+		SetSchedulingStrategy(groupIn.SchedulingStrategy)
 
 	// 显式处理可空字段：nil 需要 clear，非 nil 需要 set。
 	if groupIn.DailyLimitUSD != nil {
@@ -183,6 +195,20 @@ func (r *groupRepository) Update(ctx context.Context, groupIn *service.Group) er
 		builder = builder.ClearModelRouting()
 	}
 
+	// 处理 ModelAliases：nil 时清除，否则设置
+	if groupIn.ModelAliases != nil {
+		builder = builder.SetModelAliases(groupIn.ModelAliases)
+	} else {
+		builder = builder.ClearModelAliases()
+	}
+
+	// 处理 FallbackChain：空时清除，否则设置
+	if len(groupIn.FallbackChain) > 0 {
+		builder = builder.SetFallbackChain(groupIn.FallbackChain)
+	} else {
+		builder = builder.ClearFallbackChain()
+	}
+
 	// 处理 SupportedModelScopes（始终设置，空数组表示不限制）
 	builder = builder.SetSupportedModelScopes(groupIn.SupportedModelScopes)
 
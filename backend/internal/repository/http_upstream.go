@@ -1,6 +1,7 @@
 package repository
 
 import (
+	"crypto/tls"
 	"errors"
 	"fmt"
 	"io"
@@ -14,11 +15,14 @@ import (
 	"time"
 
 	"github.com/ShaohongDong/sub2api/internal/config"
+	"github.com/ShaohongDong/sub2api/internal/pkg/ctxkey"
 	"github.com/ShaohongDong/sub2api/internal/pkg/proxyurl"
 	"github.com/ShaohongDong/sub2api/internal/pkg/proxyutil"
 	"github.com/ShaohongDong/sub2api/internal/pkg/tlsfingerprint"
+	"github.com/ShaohongDong/sub2api/internal/pkg/tracing"
 	"github.com/ShaohongDong/sub2api/internal/service"
 	"github.com/ShaohongDong/sub2api/internal/util/urlvalidator"
+	"go.opentelemetry.io/otel/attribute"
 )
 
 // 默认配置常量
@@ -45,6 +49,14 @@ const (
 	defaultMaxUpstreamClients = 5000
 	// defaultClientIdleTTLSeconds: 默认客户端空闲回收阈值（15分钟）
 	defaultClientIdleTTLSeconds = 900
+	// defaultDialTimeout: 默认 TCP 建连超时
+	defaultDialTimeout = 10 * time.Second
+	// defaultTLSHandshakeTimeout: 默认 TLS 握手超时
+	defaultTLSHandshakeTimeout = 10 * time.Second
+	// defaultTLSSessionCacheSize: 默认每个客户端的 TLS 会话缓存容量
+	defaultTLSSessionCacheSize = 64
+	// dialKeepAlive: TCP keep-alive 探测间隔
+	dialKeepAlive = 30 * time.Second
 )
 
 var errUpstreamClientLimitReached = errors.New("upstream client cache limit reached")
@@ -57,6 +69,10 @@ type poolSettings struct {
 	maxConnsPerHost       int           // 每主机最大连接数（含活跃）
 	idleConnTimeout       time.Duration // 空闲连接超时时间
 	responseHeaderTimeout time.Duration // 等待响应头超时时间
+	dialTimeout           time.Duration // TCP 建连超时
+	tlsHandshakeTimeout   time.Duration // TLS 握手超时
+	tlsSessionCacheSize   int           // TLS 会话缓存容量，0 表示关闭
+	disableHTTP2          bool          // 关闭 HTTP/2
 }
 
 // upstreamClientEntry 上游客户端缓存条目
@@ -99,12 +115,25 @@ type httpUpstreamService struct {
 //   - cfg: 全局配置，包含连接池参数和隔离策略
 //
 // 返回:
-//   - service.HTTPUpstream 接口实现
+//   - service.HTTPUpstream 接口实现（包装统一重试，是否重试由 gateway.upstream_retry 与运行时设置决定）
 func NewHTTPUpstream(cfg *config.Config) service.HTTPUpstream {
-	return &httpUpstreamService{
+	upstream := &httpUpstreamService{
 		cfg:     cfg,
 		clients: make(map[string]*upstreamClientEntry),
 	}
+	if cfg == nil {
+		return upstream
+	}
+	var wrapped service.HTTPUpstream = upstream
+	service.ConfigureAccountCircuitBreaker(cfg.Gateway.AccountCircuitBreaker)
+	if cfg.Gateway.AccountCircuitBreaker.Enabled {
+		// 熔断在重试之内记录，每次尝试都计入账号的错误率
+		wrapped = newCircuitBreakerHTTPUpstream(wrapped)
+	}
+	// 始终挂载重试层：是否重试与重试次数、退避可通过运行时设置随时调整
+	wrapped = newRetryingHTTPUpstream(wrapped, cfg.Gateway.UpstreamRetry)
+	// 演练请求在最外层直接由模拟上游应答，不经过重试、熔断与连接池
+	return newSimulatedHTTPUpstream(wrapped, cfg)
 }
 
 // Do 执行 HTTP 请求
@@ -127,6 +156,7 @@ func (s *httpUpstreamService) Do(req *http.Request, proxyURL string, accountID i
 	if err := s.validateRequestHost(req); err != nil {
 		return nil, err
 	}
+	s.setRequestIDHeader(req)
 
 	// 获取或创建对应的客户端，并标记请求占用
 	entry, err := s.acquireClient(proxyURL, accountID, accountConcurrency)
@@ -135,7 +165,7 @@ func (s *httpUpstreamService) Do(req *http.Request, proxyURL string, accountID i
 	}
 
 	// 执行请求
-	resp, err := entry.client.Do(req)
+	resp, err := doTracedUpstream(entry.client, req, accountID, false)
 	if err != nil {
 		// 请求失败，立即减少计数
 		atomic.AddInt64(&entry.inFlight, -1)
@@ -187,6 +217,7 @@ func (s *httpUpstreamService) DoWithTLS(req *http.Request, proxyURL string, acco
 	if err := s.validateRequestHost(req); err != nil {
 		return nil, err
 	}
+	s.setRequestIDHeader(req)
 
 	// 获取 TLS 指纹 Profile
 	registry := tlsfingerprint.GlobalRegistry()
@@ -207,7 +238,7 @@ func (s *httpUpstreamService) DoWithTLS(req *http.Request, proxyURL string, acco
 	}
 
 	// 执行请求
-	resp, err := entry.client.Do(req)
+	resp, err := doTracedUpstream(entry.client, req, accountID, true)
 	if err != nil {
 		// 请求失败，立即减少计数
 		atomic.AddInt64(&entry.inFlight, -1)
@@ -227,6 +258,32 @@ func (s *httpUpstreamService) DoWithTLS(req *http.Request, proxyURL string, acco
 	return resp, nil
 }
 
+// setRequestIDHeader 按 gateway.upstream_request_id_header 向上游请求携带网关请求 ID，便于与上游日志对账
+func (s *httpUpstreamService) setRequestIDHeader(req *http.Request) {
+	if req == nil || s.cfg == nil || s.cfg.Gateway.UpstreamRequestIDHeader == "" {
+		return
+	}
+	if requestID, _ := req.Context().Value(ctxkey.RequestID).(string); requestID != "" {
+		req.Header.Set(s.cfg.Gateway.UpstreamRequestIDHeader, requestID)
+	}
+}
+
+// doTracedUpstream 发送上游请求并记录 CLIENT span（至收到响应头为止，即上游首字节耗时），
+// 按 tracing.propagate_upstream 注入 traceparent。
+func doTracedUpstream(client *http.Client, req *http.Request, accountID int64, tlsFingerprint bool) (*http.Response, error) {
+	req, span := tracing.StartHTTPClient(req, "upstream.request",
+		attribute.Int64("sub2api.account_id", accountID),
+		attribute.Bool("sub2api.tls_fingerprint", tlsFingerprint),
+	)
+	// 调试重放时记录实际发往上游的请求与响应（已脱敏）
+	rec := service.UpstreamTraceFromContext(req.Context())
+	ex := rec.RecordRequest(req)
+	resp, err := client.Do(req)
+	tracing.EndHTTPClient(span, resp, err)
+	rec.RecordResponse(ex, resp, err)
+	return resp, err
+}
+
 // acquireClientWithTLS 获取或创建带 TLS 指纹的客户端
 func (s *httpUpstreamService) acquireClientWithTLS(proxyURL string, accountID int64, accountConcurrency int, profile *tlsfingerprint.Profile) (*upstreamClientEntry, error) {
 	return s.getClientEntryWithTLS(proxyURL, accountID, accountConcurrency, profile, true, true)
@@ -734,6 +791,10 @@ func defaultPoolSettings(cfg *config.Config) poolSettings {
 	maxConnsPerHost := defaultMaxConnsPerHost
 	idleConnTimeout := defaultIdleConnTimeout
 	responseHeaderTimeout := defaultResponseHeaderTimeout
+	dialTimeout := defaultDialTimeout
+	tlsHandshakeTimeout := defaultTLSHandshakeTimeout
+	tlsSessionCacheSize := defaultTLSSessionCacheSize
+	disableHTTP2 := false
 
 	if cfg != nil {
 		if cfg.Gateway.MaxIdleConns > 0 {
@@ -751,6 +812,14 @@ func defaultPoolSettings(cfg *config.Config) poolSettings {
 		if cfg.Gateway.ResponseHeaderTimeout > 0 {
 			responseHeaderTimeout = time.Duration(cfg.Gateway.ResponseHeaderTimeout) * time.Second
 		}
+		if cfg.Gateway.DialTimeoutSeconds > 0 {
+			dialTimeout = time.Duration(cfg.Gateway.DialTimeoutSeconds) * time.Second
+		}
+		if cfg.Gateway.TLSHandshakeTimeoutSeconds > 0 {
+			tlsHandshakeTimeout = time.Duration(cfg.Gateway.TLSHandshakeTimeoutSeconds) * time.Second
+		}
+		tlsSessionCacheSize = cfg.Gateway.TLSSessionCacheSize
+		disableHTTP2 = cfg.Gateway.DisableUpstreamHTTP2
 	}
 
 	return poolSettings{
@@ -759,6 +828,10 @@ func defaultPoolSettings(cfg *config.Config) poolSettings {
 		maxConnsPerHost:       maxConnsPerHost,
 		idleConnTimeout:       idleConnTimeout,
 		responseHeaderTimeout: responseHeaderTimeout,
+		dialTimeout:           dialTimeout,
+		tlsHandshakeTimeout:   tlsHandshakeTimeout,
+		tlsSessionCacheSize:   tlsSessionCacheSize,
+		disableHTTP2:          disableHTTP2,
 	}
 }
 
@@ -779,6 +852,9 @@ func defaultPoolSettings(cfg *config.Config) poolSettings {
 //   - MaxConnsPerHost: 每主机最大连接数（达到后新请求等待）
 //   - IdleConnTimeout: 空闲连接超时（超时后关闭）
 //   - ResponseHeaderTimeout: 等待响应头超时（不影响流式传输）
+//   - DialContext / TLSHandshakeTimeout: 建连与握手超时（SOCKS5 代理由代理 Dialer 建连，受请求上下文控制）
+//   - ClientSessionCache: TLS 会话缓存，突发流量新建连接时恢复会话，避免反复完整握手
+//   - ForceAttemptHTTP2: 自定义 DialContext / TLSClientConfig 后标准库不再自动启用 HTTP/2，需显式开启
 func buildUpstreamTransport(settings poolSettings, proxyURL *url.URL) (*http.Transport, error) {
 	transport := &http.Transport{
 		MaxIdleConns:          settings.maxIdleConns,
@@ -786,6 +862,16 @@ func buildUpstreamTransport(settings poolSettings, proxyURL *url.URL) (*http.Tra
 		MaxConnsPerHost:       settings.maxConnsPerHost,
 		IdleConnTimeout:       settings.idleConnTimeout,
 		ResponseHeaderTimeout: settings.responseHeaderTimeout,
+		DialContext:           (&net.Dialer{Timeout: settings.dialTimeout, KeepAlive: dialKeepAlive}).DialContext,
+		TLSHandshakeTimeout:   settings.tlsHandshakeTimeout,
+		ForceAttemptHTTP2:     !settings.disableHTTP2,
+	}
+	if settings.tlsSessionCacheSize > 0 {
+		transport.TLSClientConfig = &tls.Config{ClientSessionCache: tls.NewLRUClientSessionCache(settings.tlsSessionCacheSize)}
+	}
+	if settings.disableHTTP2 {
+		// 非 nil 的空 TLSNextProto 禁止协商 HTTP/2
+		transport.TLSNextProto = map[string]func(string, *tls.Conn) http.RoundTripper{}
 	}
 	if err := proxyutil.ConfigureTransportProxy(transport, proxyURL); err != nil {
 		return nil, err
@@ -824,7 +910,7 @@ func buildUpstreamTransportWithTLSFingerprint(settings poolSettings, proxyURL *u
 	if proxyURL == nil {
 		// 直连：使用 TLSFingerprintDialer
 		slog.Debug("tls_fingerprint_transport_direct")
-		dialer := tlsfingerprint.NewDialer(profile, nil)
+		dialer := tlsfingerprint.NewDialer(profile, (&net.Dialer{Timeout: settings.dialTimeout, KeepAlive: dialKeepAlive}).DialContext)
 		transport.DialTLSContext = dialer.DialTLSContext
 	} else {
 		scheme := strings.ToLower(proxyURL.Scheme)
@@ -0,0 +1,46 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/ShaohongDong/sub2api/internal/service"
+)
+
+// circuitBreakerHTTPUpstream 记录每次上游调用的结果与响应头耗时，供账号熔断（gateway.account_circuit_breaker）统计。
+// 连接失败与 5xx 计为失败；调用方主动取消的请求不计入。
+type circuitBreakerHTTPUpstream struct {
+	next service.HTTPUpstream
+}
+
+func newCircuitBreakerHTTPUpstream(next service.HTTPUpstream) *circuitBreakerHTTPUpstream {
+	return &circuitBreakerHTTPUpstream{next: next}
+}
+
+func (u *circuitBreakerHTTPUpstream) Do(req *http.Request, proxyURL string, accountID int64, accountConcurrency int) (*http.Response, error) {
+	start := time.Now()
+	resp, err := u.next.Do(req, proxyURL, accountID, accountConcurrency)
+	recordCircuitResult(accountID, resp, err, time.Since(start))
+	return resp, err
+}
+
+func (u *circuitBreakerHTTPUpstream) DoWithTLS(req *http.Request, proxyURL string, accountID int64, accountConcurrency int, enableTLSFingerprint bool) (*http.Response, error) {
+	start := time.Now()
+	resp, err := u.next.DoWithTLS(req, proxyURL, accountID, accountConcurrency, enableTLSFingerprint)
+	recordCircuitResult(accountID, resp, err, time.Since(start))
+	return resp, err
+}
+
+func recordCircuitResult(accountID int64, resp *http.Response, err error, latency time.Duration) {
+	if err != nil {
+		if errors.Is(err, context.Canceled) {
+			return
+		}
+		service.RecordAccountUpstreamResult(accountID, true, latency)
+		return
+	}
+	failed := resp != nil && resp.StatusCode >= http.StatusInternalServerError
+	service.RecordAccountUpstreamResult(accountID, failed, latency)
+}
@@ -0,0 +1,43 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/ShaohongDong/sub2api/internal/config"
+	"github.com/ShaohongDong/sub2api/internal/service"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCircuitBreakerHTTPUpstream_RecordsFailures(t *testing.T) {
+	service.ConfigureAccountCircuitBreaker(config.GatewayAccountCircuitBreakerConfig{
+		Enabled: true, WindowSeconds: 60, MinRequests: 2, ErrorRateThreshold: 0.5, OpenSeconds: 30, HalfOpenSuccesses: 1,
+	})
+	t.Cleanup(func() { service.ConfigureAccountCircuitBreaker(config.GatewayAccountCircuitBreakerConfig{}) })
+
+	next := &scriptedUpstream{}
+	upstream := newCircuitBreakerHTTPUpstream(next)
+	req := newRetryTestRequest(t, context.Background())
+
+	// 调用方取消不计入
+	next.results = []func() (*http.Response, error){errResult(context.Canceled)}
+	_, _ = upstream.Do(req, "", 7, 1)
+	_, _ = upstream.Do(req, "", 7, 1)
+	require.True(t, service.AccountCircuitAllows(7))
+
+	next.results = []func() (*http.Response, error){statusResult(http.StatusOK, nil), statusResult(http.StatusBadGateway, nil)}
+	_, _ = upstream.Do(req, "", 7, 1)
+	_, _ = upstream.DoWithTLS(req, "", 7, 1, false)
+	require.False(t, service.AccountCircuitAllows(7))
+
+	// 连接失败计为失败，但单次调用未达到 MinRequests
+	next.results = []func() (*http.Response, error){errResult(errors.New("dial failed"))}
+	_, _ = upstream.Do(req, "", 8, 1)
+	require.True(t, service.AccountCircuitAllows(8))
+}
+
+func errResult(err error) func() (*http.Response, error) {
+	return func() (*http.Response, error) { return nil, err }
+}
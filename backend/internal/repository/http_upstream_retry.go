@@ -0,0 +1,232 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	mathrand "math/rand"
+	"net/http"
+	"strconv"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/ShaohongDong/sub2api/internal/config"
+	"github.com/ShaohongDong/sub2api/internal/service"
+)
+
+const (
+	// retryBudgetMaxTokens 重试预算的累积上限，避免长时间低错误率后积攒过多额度在故障时集中重试
+	retryBudgetMaxTokens = 100
+	// retryDrainMaxBytes 丢弃可重试响应时最多读取的字节数（读完可复用连接）
+	retryDrainMaxBytes = 64 << 10
+)
+
+// retryingHTTPUpstream 为上游请求提供统一重试（gateway.upstream_retry，启用状态、次数与退避可被运行时设置覆盖）。
+// 只重试请求未被上游处理的失败：连接重置/拒绝、配置的 5xx，以及 Retry-After 较短的 429。
+// 重试在响应返回调用方之前完成，调用方拿到的始终是最后一次尝试的结果，因此不会在已向客户端输出后重试。
+type retryingHTTPUpstream struct {
+	next     service.HTTPUpstream
+	cfg      config.GatewayUpstreamRetryConfig
+	statuses map[int]struct{}
+	budget   *retryBudget
+}
+
+func newRetryingHTTPUpstream(next service.HTTPUpstream, cfg config.GatewayUpstreamRetryConfig) *retryingHTTPUpstream {
+	statuses := make(map[int]struct{}, len(cfg.RetryStatusCodes))
+	for _, code := range cfg.RetryStatusCodes {
+		statuses[code] = struct{}{}
+	}
+	return &retryingHTTPUpstream{
+		next:     next,
+		cfg:      cfg,
+		statuses: statuses,
+		budget:   newRetryBudget(cfg.BudgetRatio, cfg.BudgetMinPerSecond),
+	}
+}
+
+func (u *retryingHTTPUpstream) Do(req *http.Request, proxyURL string, accountID int64, accountConcurrency int) (*http.Response, error) {
+	return u.do(req, accountID, func(r *http.Request) (*http.Response, error) {
+		return u.next.Do(r, proxyURL, accountID, accountConcurrency)
+	})
+}
+
+func (u *retryingHTTPUpstream) DoWithTLS(req *http.Request, proxyURL string, accountID int64, accountConcurrency int, enableTLSFingerprint bool) (*http.Response, error) {
+	return u.do(req, accountID, func(r *http.Request) (*http.Response, error) {
+		return u.next.DoWithTLS(r, proxyURL, accountID, accountConcurrency, enableTLSFingerprint)
+	})
+}
+
+func (u *retryingHTTPUpstream) do(req *http.Request, accountID int64, send func(*http.Request) (*http.Response, error)) (*http.Response, error) {
+	policy := service.EffectiveUpstreamRetry(u.cfg)
+	if !policy.Enabled {
+		return send(req)
+	}
+	u.budget.deposit(time.Now())
+	// 请求体无法重放（未设置 GetBody）时不重试
+	replayable := req.Body == nil || req.Body == http.NoBody || req.GetBody != nil
+	ctx := req.Context()
+	attemptReq := req
+	for attempt := 1; ; attempt++ {
+		resp, err := send(attemptReq)
+		if attempt >= policy.MaxAttempts || !replayable || ctx.Err() != nil {
+			return resp, err
+		}
+		wait, ok := u.retryDelay(policy, attempt, resp, err)
+		if !ok {
+			return resp, err
+		}
+		nextReq, cloneErr := cloneRequestForRetry(req)
+		if cloneErr != nil || !u.budget.withdraw(time.Now()) {
+			return resp, err
+		}
+		status := 0
+		if resp != nil {
+			status = resp.StatusCode
+			_, _ = io.CopyN(io.Discard, resp.Body, retryDrainMaxBytes)
+			_ = resp.Body.Close()
+		}
+		slog.Debug("upstream_retry", "account_id", accountID, "attempt", attempt, "status", status, "error", err, "wait", wait)
+		if sleepErr := sleepWithContext(ctx, wait); sleepErr != nil {
+			return nil, sleepErr
+		}
+		service.RecordUpstreamRetry(ctx)
+		attemptReq = nextReq
+	}
+}
+
+// retryDelay 判断本次结果是否可重试，并返回重试前的等待时间
+func (u *retryingHTTPUpstream) retryDelay(policy config.GatewayUpstreamRetryConfig, attempt int, resp *http.Response, err error) (time.Duration, bool) {
+	backoff := retryBackoff(policy, attempt)
+	if err != nil {
+		return backoff, isRetryableConnError(err)
+	}
+	if resp == nil {
+		return 0, false
+	}
+	if _, ok := u.statuses[resp.StatusCode]; ok {
+		return backoff, true
+	}
+	if resp.StatusCode == http.StatusTooManyRequests && u.cfg.Retry429MaxRetryAfterSeconds > 0 {
+		retryAfter, ok := parseRetryAfter(resp.Header.Get("Retry-After"), time.Now())
+		if !ok || retryAfter > time.Duration(u.cfg.Retry429MaxRetryAfterSeconds)*time.Second {
+			return 0, false
+		}
+		return max(retryAfter, backoff), true
+	}
+	return 0, false
+}
+
+// retryBackoff 第 attempt 次失败后的等待：BaseDelayMS * 2^(attempt-1)，上限 MaxDelayMS，并在 [delay/2, delay] 内随机抖动
+func retryBackoff(policy config.GatewayUpstreamRetryConfig, attempt int) time.Duration {
+	delay := time.Duration(policy.BaseDelayMS) * time.Millisecond
+	maxDelay := time.Duration(policy.MaxDelayMS) * time.Millisecond
+	for i := 1; i < attempt && delay < maxDelay; i++ {
+		delay *= 2
+	}
+	if delay > maxDelay {
+		delay = maxDelay
+	}
+	half := delay / 2
+	if half <= 0 {
+		return delay
+	}
+	return half + time.Duration(mathrand.Int63n(int64(half)+1))
+}
+
+// isRetryableConnError 判断请求错误是否发生在上游处理请求之前（连接被重置/拒绝、空闲连接被对端关闭）
+func isRetryableConnError(err error) bool {
+	if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return false
+	}
+	return errors.Is(err, syscall.ECONNRESET) ||
+		errors.Is(err, syscall.ECONNREFUSED) ||
+		errors.Is(err, io.EOF) ||
+		errors.Is(err, io.ErrUnexpectedEOF)
+}
+
+// parseRetryAfter 解析 Retry-After（秒数或 HTTP 日期）
+func parseRetryAfter(value string, now time.Time) (time.Duration, bool) {
+	if value == "" {
+		return 0, false
+	}
+	if secs, err := strconv.Atoi(value); err == nil {
+		if secs < 0 {
+			return 0, false
+		}
+		return time.Duration(secs) * time.Second, true
+	}
+	if at, err := http.ParseTime(value); err == nil {
+		return max(at.Sub(now), 0), true
+	}
+	return 0, false
+}
+
+// cloneRequestForRetry 复制请求并重新获取请求体
+func cloneRequestForRetry(req *http.Request) (*http.Request, error) {
+	cloned := req.Clone(req.Context())
+	if req.GetBody != nil {
+		body, err := req.GetBody()
+		if err != nil {
+			return nil, err
+		}
+		cloned.Body = body
+	}
+	return cloned, nil
+}
+
+func sleepWithContext(ctx context.Context, d time.Duration) error {
+	if d <= 0 {
+		return nil
+	}
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+// retryBudget 全局重试预算：每个请求累积 ratio 个额度，另按时间每秒补充 minPerSecond 个，每次重试消耗 1 个
+type retryBudget struct {
+	mu           sync.Mutex
+	ratio        float64
+	minPerSecond float64
+	tokens       float64
+	lastRefill   time.Time
+}
+
+func newRetryBudget(ratio float64, minPerSecond int) *retryBudget {
+	return &retryBudget{ratio: ratio, minPerSecond: float64(minPerSecond), tokens: float64(minPerSecond), lastRefill: time.Now()}
+}
+
+func (b *retryBudget) refillLocked(now time.Time) {
+	if elapsed := now.Sub(b.lastRefill).Seconds(); elapsed > 0 {
+		b.tokens += elapsed * b.minPerSecond
+		b.lastRefill = now
+	}
+	if b.tokens > retryBudgetMaxTokens {
+		b.tokens = retryBudgetMaxTokens
+	}
+}
+
+func (b *retryBudget) deposit(now time.Time) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.tokens += b.ratio
+	b.refillLocked(now)
+}
+
+func (b *retryBudget) withdraw(now time.Time) bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.refillLocked(now)
+	if b.tokens < 1 {
+		return false
+	}
+	b.tokens--
+	return true
+}
@@ -0,0 +1,191 @@
+package repository
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/ShaohongDong/sub2api/internal/config"
+	"github.com/ShaohongDong/sub2api/internal/service"
+	"github.com/stretchr/testify/require"
+)
+
+// scriptedUpstream 按顺序返回预设结果，并记录每次收到的请求体
+type scriptedUpstream struct {
+	results []func() (*http.Response, error)
+	bodies  []string
+}
+
+func (u *scriptedUpstream) Do(req *http.Request, _ string, _ int64, _ int) (*http.Response, error) {
+	body := ""
+	if req.Body != nil {
+		b, _ := io.ReadAll(req.Body)
+		body = string(b)
+	}
+	u.bodies = append(u.bodies, body)
+	next := u.results[0]
+	if len(u.results) > 1 {
+		u.results = u.results[1:]
+	}
+	return next()
+}
+
+func (u *scriptedUpstream) DoWithTLS(req *http.Request, proxyURL string, accountID int64, accountConcurrency int, _ bool) (*http.Response, error) {
+	return u.Do(req, proxyURL, accountID, accountConcurrency)
+}
+
+func statusResult(code int, header http.Header) func() (*http.Response, error) {
+	return func() (*http.Response, error) {
+		if header == nil {
+			header = http.Header{}
+		}
+		return &http.Response{StatusCode: code, Header: header, Body: io.NopCloser(strings.NewReader(fmt.Sprintf("status %d", code)))}, nil
+	}
+}
+
+// unwrapRetryingHTTPUpstream 返回重试层包装的内层实现，未包装时原样返回
+func unwrapRetryingHTTPUpstream(up service.HTTPUpstream) service.HTTPUpstream {
+	if sim, ok := up.(*simulatedHTTPUpstream); ok {
+		up = sim.next
+	}
+	if r, ok := up.(*retryingHTTPUpstream); ok {
+		return r.next
+	}
+	return up
+}
+
+func testRetryConfig() config.GatewayUpstreamRetryConfig {
+	return config.GatewayUpstreamRetryConfig{
+		Enabled:            true,
+		MaxAttempts:        3,
+		BaseDelayMS:        1,
+		MaxDelayMS:         2,
+		RetryStatusCodes:   []int{502, 503},
+		BudgetRatio:        0.1,
+		BudgetMinPerSecond: 5,
+	}
+}
+
+func newRetryTestRequest(t *testing.T, ctx context.Context) *http.Request {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, "http://upstream.test/v1/messages", bytes.NewReader([]byte(`{"model":"m"}`)))
+	require.NoError(t, err)
+	return req
+}
+
+func TestRetryingHTTPUpstream_RetriesRetryableStatusWithFullBody(t *testing.T) {
+	inner := &scriptedUpstream{results: []func() (*http.Response, error){statusResult(502, nil), statusResult(503, nil), statusResult(200, nil)}}
+	up := newRetryingHTTPUpstream(inner, testRetryConfig())
+	ctx, counter := service.WithUpstreamRetryCounter(context.Background())
+
+	resp, err := up.Do(newRetryTestRequest(t, ctx), "", 1, 1)
+	require.NoError(t, err)
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	require.Equal(t, []string{`{"model":"m"}`, `{"model":"m"}`, `{"model":"m"}`}, inner.bodies)
+	require.Equal(t, int32(2), counter.Load())
+}
+
+func TestRetryingHTTPUpstream_StopsAtMaxAttempts(t *testing.T) {
+	inner := &scriptedUpstream{results: []func() (*http.Response, error){statusResult(503, nil)}}
+	up := newRetryingHTTPUpstream(inner, testRetryConfig())
+
+	resp, err := up.Do(newRetryTestRequest(t, context.Background()), "", 1, 1)
+	require.NoError(t, err)
+	require.Equal(t, http.StatusServiceUnavailable, resp.StatusCode)
+	body, _ := io.ReadAll(resp.Body)
+	require.Equal(t, "status 503", string(body), "最后一次结果原样返回")
+	require.Len(t, inner.bodies, 3)
+}
+
+func TestRetryingHTTPUpstream_ConnectionErrors(t *testing.T) {
+	reset := func() (*http.Response, error) { return nil, fmt.Errorf("read: %w", syscall.ECONNRESET) }
+	inner := &scriptedUpstream{results: []func() (*http.Response, error){reset, statusResult(200, nil)}}
+	resp, err := newRetryingHTTPUpstream(inner, testRetryConfig()).Do(newRetryTestRequest(t, context.Background()), "", 1, 1)
+	require.NoError(t, err)
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	require.Len(t, inner.bodies, 2)
+
+	timeout := func() (*http.Response, error) { return nil, context.DeadlineExceeded }
+	inner = &scriptedUpstream{results: []func() (*http.Response, error){timeout, statusResult(200, nil)}}
+	_, err = newRetryingHTTPUpstream(inner, testRetryConfig()).Do(newRetryTestRequest(t, context.Background()), "", 1, 1)
+	require.ErrorIs(t, err, context.DeadlineExceeded)
+	require.Len(t, inner.bodies, 1)
+}
+
+func TestRetryingHTTPUpstream_Only429WithShortRetryAfter(t *testing.T) {
+	cfg := testRetryConfig()
+	cfg.Retry429MaxRetryAfterSeconds = 1
+
+	inner := &scriptedUpstream{results: []func() (*http.Response, error){statusResult(429, http.Header{"Retry-After": {"0"}}), statusResult(200, nil)}}
+	resp, err := newRetryingHTTPUpstream(inner, cfg).Do(newRetryTestRequest(t, context.Background()), "", 1, 1)
+	require.NoError(t, err)
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+
+	for _, header := range []http.Header{nil, {"Retry-After": {"30"}}} {
+		inner = &scriptedUpstream{results: []func() (*http.Response, error){statusResult(429, header), statusResult(200, nil)}}
+		resp, err = newRetryingHTTPUpstream(inner, cfg).Do(newRetryTestRequest(t, context.Background()), "", 1, 1)
+		require.NoError(t, err)
+		require.Equal(t, http.StatusTooManyRequests, resp.StatusCode)
+		require.Len(t, inner.bodies, 1)
+	}
+}
+
+func TestRetryingHTTPUpstream_RespectsBudgetAndReplayability(t *testing.T) {
+	cfg := testRetryConfig()
+	cfg.BudgetRatio = 0
+	cfg.BudgetMinPerSecond = 0
+	inner := &scriptedUpstream{results: []func() (*http.Response, error){statusResult(502, nil), statusResult(200, nil)}}
+	resp, err := newRetryingHTTPUpstream(inner, cfg).Do(newRetryTestRequest(t, context.Background()), "", 1, 1)
+	require.NoError(t, err)
+	require.Equal(t, http.StatusBadGateway, resp.StatusCode)
+	require.Len(t, inner.bodies, 1)
+
+	// 请求体无法重放时不重试
+	inner = &scriptedUpstream{results: []func() (*http.Response, error){statusResult(502, nil), statusResult(200, nil)}}
+	req := newRetryTestRequest(t, context.Background())
+	req.GetBody = nil
+	resp, err = newRetryingHTTPUpstream(inner, testRetryConfig()).Do(req, "", 1, 1)
+	require.NoError(t, err)
+	require.Equal(t, http.StatusBadGateway, resp.StatusCode)
+	require.Len(t, inner.bodies, 1)
+}
+
+func TestRetryBudget(t *testing.T) {
+	now := time.Now()
+	budget := &retryBudget{ratio: 0.5, lastRefill: now}
+	require.False(t, budget.withdraw(now))
+	budget.deposit(now)
+	budget.deposit(now)
+	require.True(t, budget.withdraw(now))
+	require.False(t, budget.withdraw(now))
+
+	budget = &retryBudget{minPerSecond: 2, lastRefill: now}
+	require.True(t, budget.withdraw(now.Add(time.Second)))
+	require.True(t, budget.withdraw(now.Add(time.Second)))
+	require.False(t, budget.withdraw(now.Add(time.Second)))
+}
+
+func TestNewHTTPUpstream_AlwaysWrapsRetry(t *testing.T) {
+	// 重试可在运行时开启，因此即使配置未启用也挂载重试层
+	// 演练层在最外层，其内为重试层
+	sim, ok := NewHTTPUpstream(&config.Config{}).(*simulatedHTTPUpstream)
+	require.True(t, ok)
+	_, ok = sim.next.(*retryingHTTPUpstream)
+	require.True(t, ok)
+}
+
+func TestRetryingHTTPUpstream_DisabledPolicyPassesThrough(t *testing.T) {
+	inner := &scriptedUpstream{results: []func() (*http.Response, error){statusResult(503, nil), statusResult(200, nil)}}
+	cfg := testRetryConfig()
+	cfg.Enabled = false
+
+	resp, err := newRetryingHTTPUpstream(inner, cfg).Do(newRetryTestRequest(t, context.Background()), "", 1, 1)
+	require.NoError(t, err)
+	require.Equal(t, http.StatusServiceUnavailable, resp.StatusCode)
+	require.Len(t, inner.bodies, 1)
+}
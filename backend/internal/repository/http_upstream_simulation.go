@@ -0,0 +1,584 @@
+package repository
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/ShaohongDong/sub2api/internal/config"
+	"github.com/ShaohongDong/sub2api/internal/pkg/tokenizer"
+	"github.com/ShaohongDong/sub2api/internal/service"
+	"github.com/google/uuid"
+	"github.com/tidwall/gjson"
+)
+
+// simulationDefaultCannedText 未配置 canned_text 时的响应文本
+const simulationDefaultCannedText = "This is a simulated response from the sub2api dry-run upstream."
+
+// simulatedHTTPUpstream 演练模式的模拟上游（gateway.simulation / Key 的 simulate）。
+// 标记为演练的请求（service.WithSimulation）不会发出网络调用，而是按请求路径识别的上游协议
+// 返回固定文本或回显内容：流式响应按配置的首 token 延迟与分块间隔逐词输出，usage 按 tokenizer 估算。
+// 其余请求原样交给下一层，因此始终挂载也不影响正常流量。
+type simulatedHTTPUpstream struct {
+	next service.HTTPUpstream
+	cfg  *config.Config
+}
+
+func newSimulatedHTTPUpstream(next service.HTTPUpstream, cfg *config.Config) *simulatedHTTPUpstream {
+	return &simulatedHTTPUpstream{next: next, cfg: cfg}
+}
+
+func (u *simulatedHTTPUpstream) Do(req *http.Request, proxyURL string, accountID int64, accountConcurrency int) (*http.Response, error) {
+	if service.IsSimulatedRequest(req.Context()) {
+		return u.simulate(req)
+	}
+	return u.next.Do(req, proxyURL, accountID, accountConcurrency)
+}
+
+func (u *simulatedHTTPUpstream) DoWithTLS(req *http.Request, proxyURL string, accountID int64, accountConcurrency int, enableTLSFingerprint bool) (*http.Response, error) {
+	if service.IsSimulatedRequest(req.Context()) {
+		return u.simulate(req)
+	}
+	return u.next.DoWithTLS(req, proxyURL, accountID, accountConcurrency, enableTLSFingerprint)
+}
+
+// simulationProtocol 模拟上游应答使用的协议格式
+type simulationProtocol int
+
+const (
+	simulationAnthropic simulationProtocol = iota
+	simulationAnthropicCountTokens
+	simulationResponses
+	simulationChatCompletions
+	simulationGemini
+	simulationGeminiCountTokens
+)
+
+// simulationCall 单次模拟调用的解析结果
+type simulationCall struct {
+	protocol simulationProtocol
+	stream   bool
+	model    string
+	// codeAssist Gemini Code Assist / Antigravity 的 v1internal 接口，响应包裹在 response 字段中
+	codeAssist bool
+	// geminiSSE streamGenerateContent 使用 alt=sse；否则返回 JSON 数组
+	geminiSSE bool
+
+	inputTokens  int
+	chunks       []string
+	outputTokens int
+	truncated    bool
+}
+
+func (u *simulatedHTTPUpstream) simulate(req *http.Request) (*http.Response, error) {
+	var body []byte
+	if req.Body != nil && req.Body != http.NoBody {
+		var err error
+		body, err = io.ReadAll(req.Body)
+		_ = req.Body.Close()
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	call, ok := parseSimulationCall(req, body)
+	if !ok {
+		return simulationErrorResponse(req, http.StatusBadRequest, fmt.Sprintf("simulation mode does not support %s", req.URL.Path)), nil
+	}
+	var sim config.GatewaySimulationConfig
+	if u.cfg != nil {
+		sim = u.cfg.Gateway.Simulation
+	}
+	call.inputTokens = tokenizer.Count(call.model, simulationInputText(body))
+	if call.protocol == simulationAnthropicCountTokens || call.protocol == simulationGeminiCountTokens {
+		return simulationJSONResponse(req, call.countTokensBody()), nil
+	}
+
+	text := sim.CannedText
+	if sim.ResponseMode == "echo" {
+		if echo := simulationLastUserText(body); echo != "" {
+			text = echo
+		}
+	}
+	if strings.TrimSpace(text) == "" {
+		text = simulationDefaultCannedText
+	}
+	call.chunks, call.outputTokens, call.truncated = simulationChunks(call.model, text, sim.OutputTokens, simulationMaxTokens(body))
+
+	firstToken := time.Duration(sim.FirstTokenLatencyMS) * time.Millisecond
+	interval := time.Duration(sim.ChunkIntervalMS) * time.Millisecond
+	if !call.stream {
+		// 非流式请求等待完整生成耗时
+		if err := sleepWithContext(req.Context(), firstToken+time.Duration(len(call.chunks))*interval); err != nil {
+			return nil, err
+		}
+		return simulationJSONResponse(req, call.jsonBody()), nil
+	}
+
+	pr, pw := io.Pipe()
+	go func() {
+		pw.CloseWithError(call.writeStream(req.Context(), pw, firstToken, interval))
+	}()
+	resp := simulationResponse(req, http.StatusOK, "text/event-stream", pr)
+	if call.protocol == simulationGemini && !call.geminiSSE {
+		resp.Header.Set("Content-Type", "application/json")
+	}
+	return resp, nil
+}
+
+// parseSimulationCall 按上游 URL 识别协议：Anthropic Messages、OpenAI Responses（含 Codex）、Chat Completions 与 Gemini
+func parseSimulationCall(req *http.Request, body []byte) (*simulationCall, bool) {
+	path := req.URL.Path
+	call := &simulationCall{model: gjson.GetBytes(body, "model").String(), stream: gjson.GetBytes(body, "stream").Bool()}
+	switch {
+	case strings.HasSuffix(path, "/messages/count_tokens"):
+		call.protocol = simulationAnthropicCountTokens
+	case strings.HasSuffix(path, "/messages"):
+		call.protocol = simulationAnthropic
+	case strings.HasSuffix(path, "/responses"):
+		call.protocol = simulationResponses
+	case strings.HasSuffix(path, "/chat/completions"):
+		call.protocol = simulationChatCompletions
+	default:
+		idx := strings.LastIndex(path, ":")
+		if idx < 0 {
+			return nil, false
+		}
+		method := path[idx+1:]
+		call.codeAssist = strings.Contains(path, "/v1internal:")
+		if !call.codeAssist {
+			if m := path[:idx]; strings.Contains(m, "/models/") {
+				call.model = m[strings.LastIndex(m, "/models/")+len("/models/"):]
+			}
+		}
+		switch method {
+		case "generateContent":
+			call.protocol, call.stream = simulationGemini, false
+		case "streamGenerateContent":
+			call.protocol, call.stream = simulationGemini, true
+			call.geminiSSE = req.URL.Query().Get("alt") == "sse"
+		case "countTokens":
+			call.protocol = simulationGeminiCountTokens
+		default:
+			return nil, false
+		}
+	}
+	return call, true
+}
+
+// simulationInputText 汇总请求体中的文本字段，用于估算输入 token
+func simulationInputText(body []byte) string {
+	var sb strings.Builder
+	var walk func(key string, value gjson.Result)
+	walk = func(key string, value gjson.Result) {
+		switch {
+		case value.IsObject() || value.IsArray():
+			value.ForEach(func(k, v gjson.Result) bool {
+				walk(k.String(), v)
+				return true
+			})
+		case value.Type == gjson.String:
+			switch key {
+			case "model", "role", "type", "id", "tool_use_id", "call_id", "signature", "data", "url", "image_url", "mimeType", "media_type":
+				return
+			}
+			sb.WriteString(value.String())
+			sb.WriteByte('\n')
+		}
+	}
+	walk("", gjson.ParseBytes(body))
+	return sb.String()
+}
+
+// simulationLastUserText 提取最后一条用户消息的文本（messages / input / contents / request.contents）
+func simulationLastUserText(body []byte) string {
+	parsed := gjson.ParseBytes(body)
+	if input := parsed.Get("input"); input.Type == gjson.String {
+		return input.String()
+	}
+	for _, path := range []string{"messages", "input", "contents", "request.contents"} {
+		items := parsed.Get(path).Array()
+		for i := len(items) - 1; i >= 0; i-- {
+			if role := items[i].Get("role").String(); role != "" && role != "user" {
+				continue
+			}
+			if text := simulationMessageText(items[i]); text != "" {
+				return text
+			}
+		}
+	}
+	return ""
+}
+
+func simulationMessageText(message gjson.Result) string {
+	if content := message.Get("content"); content.Type == gjson.String {
+		return content.String()
+	}
+	var parts []string
+	for _, field := range []string{"content", "parts"} {
+		message.Get(field).ForEach(func(_, part gjson.Result) bool {
+			if text := part.Get("text").String(); text != "" {
+				parts = append(parts, text)
+			}
+			return true
+		})
+	}
+	return strings.Join(parts, "\n")
+}
+
+// simulationMaxTokens 请求的最大输出 token 数（0 表示未指定）
+func simulationMaxTokens(body []byte) int {
+	for _, path := range []string{"max_tokens", "max_output_tokens", "max_completion_tokens", "generationConfig.maxOutputTokens", "request.generationConfig.maxOutputTokens"} {
+		if v := gjson.GetBytes(body, path).Int(); v > 0 {
+			return int(v)
+		}
+	}
+	return 0
+}
+
+// simulationChunks 将文本按词切分为流式分块：target > 0 时循环重复文本直至约 target 个 token，
+// 超过请求 max_tokens 时截断（对应 stop_reason=max_tokens）。返回分块、输出 token 数与是否截断。
+func simulationChunks(model, text string, target, limit int) ([]string, int, bool) {
+	words := strings.Fields(text)
+	if len(words) == 0 {
+		return nil, 0, false
+	}
+	wordTokens := make(map[string]int, len(words))
+	countWord := func(chunk string) int {
+		n, ok := wordTokens[chunk]
+		if !ok {
+			n = tokenizer.Count(model, chunk)
+			if n < 1 {
+				n = 1
+			}
+			wordTokens[chunk] = n
+		}
+		return n
+	}
+
+	var chunks []string
+	total := 0
+	truncated := false
+	for i := 0; ; i++ {
+		if target > 0 && total >= target {
+			break
+		}
+		if target <= 0 && i >= len(words) {
+			break
+		}
+		chunk := words[i%len(words)]
+		if i > 0 {
+			chunk = " " + chunk
+		}
+		n := countWord(chunk)
+		if limit > 0 && total+n > limit {
+			truncated = true
+			break
+		}
+		chunks = append(chunks, chunk)
+		total += n
+	}
+	return chunks, total, truncated
+}
+
+func (c *simulationCall) text() string {
+	return strings.Join(c.chunks, "")
+}
+
+func (c *simulationCall) countTokensBody() any {
+	if c.protocol == simulationGeminiCountTokens {
+		return map[string]any{"totalTokens": c.inputTokens}
+	}
+	return map[string]any{"input_tokens": c.inputTokens}
+}
+
+func (c *simulationCall) jsonBody() any {
+	switch c.protocol {
+	case simulationResponses:
+		return c.responsesObject("completed", c.text())
+	case simulationChatCompletions:
+		return map[string]any{
+			"id":      "chatcmpl-sim-" + simulationID(),
+			"object":  "chat.completion",
+			"created": time.Now().Unix(),
+			"model":   c.model,
+			"choices": []any{map[string]any{
+				"index":         0,
+				"message":       map[string]any{"role": "assistant", "content": c.text()},
+				"finish_reason": c.finishReason("stop", "length"),
+			}},
+			"usage": c.chatUsage(),
+		}
+	case simulationGemini:
+		return c.geminiChunk(c.text(), true)
+	default:
+		return map[string]any{
+			"id":            "msg_sim_" + simulationID(),
+			"type":          "message",
+			"role":          "assistant",
+			"model":         c.model,
+			"content":       []any{map[string]any{"type": "text", "text": c.text()}},
+			"stop_reason":   c.finishReason("end_turn", "max_tokens"),
+			"stop_sequence": nil,
+			"usage":         c.anthropicUsage(c.outputTokens),
+		}
+	}
+}
+
+func (c *simulationCall) finishReason(stop, length string) string {
+	if c.truncated {
+		return length
+	}
+	return stop
+}
+
+func (c *simulationCall) anthropicUsage(outputTokens int) map[string]any {
+	return map[string]any{
+		"input_tokens":                c.inputTokens,
+		"output_tokens":               outputTokens,
+		"cache_creation_input_tokens": 0,
+		"cache_read_input_tokens":     0,
+	}
+}
+
+func (c *simulationCall) chatUsage() map[string]any {
+	return map[string]any{
+		"prompt_tokens":     c.inputTokens,
+		"completion_tokens": c.outputTokens,
+		"total_tokens":      c.inputTokens + c.outputTokens,
+	}
+}
+
+func (c *simulationCall) responsesObject(status, text string) map[string]any {
+	obj := map[string]any{
+		"id":         "resp_sim_" + simulationID(),
+		"object":     "response",
+		"created_at": time.Now().Unix(),
+		"status":     status,
+		"model":      c.model,
+		"output":     []any{},
+		"usage":      nil,
+	}
+	if status == "completed" {
+		obj["output"] = []any{c.responsesMessage("completed", text)}
+		obj["usage"] = map[string]any{
+			"input_tokens":          c.inputTokens,
+			"input_tokens_details":  map[string]any{"cached_tokens": 0},
+			"output_tokens":         c.outputTokens,
+			"output_tokens_details": map[string]any{"reasoning_tokens": 0},
+			"total_tokens":          c.inputTokens + c.outputTokens,
+		}
+		if c.truncated {
+			obj["status"] = "incomplete"
+			obj["incomplete_details"] = map[string]any{"reason": "max_output_tokens"}
+		}
+	}
+	return obj
+}
+
+func (c *simulationCall) responsesMessage(status, text string) map[string]any {
+	content := []any{}
+	if status == "completed" {
+		content = []any{map[string]any{"type": "output_text", "text": text, "annotations": []any{}}}
+	}
+	return map[string]any{"id": "msg_sim", "type": "message", "status": status, "role": "assistant", "content": content}
+}
+
+func (c *simulationCall) geminiChunk(text string, final bool) any {
+	candidate := map[string]any{
+		"content": map[string]any{"role": "model", "parts": []any{map[string]any{"text": text}}},
+		"index":   0,
+	}
+	outputTokens := 0
+	if final {
+		candidate["finishReason"] = c.finishReason("STOP", "MAX_TOKENS")
+		outputTokens = c.outputTokens
+	}
+	chunk := map[string]any{
+		"candidates": []any{candidate},
+		"usageMetadata": map[string]any{
+			"promptTokenCount":     c.inputTokens,
+			"candidatesTokenCount": outputTokens,
+			"totalTokenCount":      c.inputTokens + outputTokens,
+		},
+		"modelVersion": c.model,
+	}
+	if c.codeAssist {
+		return map[string]any{"response": chunk}
+	}
+	return chunk
+}
+
+// writeStream 按协议输出流式事件：首个内容分块前等待 firstToken，之后每个分块间隔 interval；客户端断开时立即停止
+func (c *simulationCall) writeStream(ctx context.Context, w io.Writer, firstToken, interval time.Duration) error {
+	events := c.streamEvents()
+	if c.protocol == simulationGemini && !c.geminiSSE {
+		if _, err := io.WriteString(w, "["); err != nil {
+			return err
+		}
+	}
+	delay := firstToken
+	for i, ev := range events {
+		if ev.content {
+			if err := sleepWithContext(ctx, delay); err != nil {
+				return err
+			}
+			delay = interval
+		}
+		data, err := json.Marshal(ev.data)
+		if err != nil {
+			return err
+		}
+		var buf bytes.Buffer
+		switch {
+		case c.protocol == simulationGemini && !c.geminiSSE:
+			if i > 0 {
+				buf.WriteString(",\n")
+			}
+			buf.Write(data)
+		case ev.name != "":
+			fmt.Fprintf(&buf, "event: %s\ndata: %s\n\n", ev.name, data)
+		default:
+			fmt.Fprintf(&buf, "data: %s\n\n", data)
+		}
+		if _, err := w.Write(buf.Bytes()); err != nil {
+			return err
+		}
+	}
+	switch {
+	case c.protocol == simulationGemini && !c.geminiSSE:
+		_, err := io.WriteString(w, "]")
+		return err
+	case c.protocol == simulationChatCompletions:
+		_, err := io.WriteString(w, "data: [DONE]\n\n")
+		return err
+	}
+	return nil
+}
+
+// simulationEvent 一个流式事件；content 为 true 时输出前等待分块间隔
+type simulationEvent struct {
+	name    string
+	data    any
+	content bool
+}
+
+func (c *simulationCall) streamEvents() []simulationEvent {
+	var events []simulationEvent
+	switch c.protocol {
+	case simulationResponses:
+		created := c.responsesObject("in_progress", "")
+		events = append(events,
+			simulationEvent{name: "response.created", data: map[string]any{"type": "response.created", "response": created}},
+			simulationEvent{name: "response.output_item.added", data: map[string]any{"type": "response.output_item.added", "output_index": 0, "item": c.responsesMessage("in_progress", "")}},
+			simulationEvent{name: "response.content_part.added", data: map[string]any{"type": "response.content_part.added", "item_id": "msg_sim", "output_index": 0, "content_index": 0, "part": map[string]any{"type": "output_text", "text": "", "annotations": []any{}}}},
+		)
+		for _, chunk := range c.chunks {
+			events = append(events, simulationEvent{name: "response.output_text.delta", content: true, data: map[string]any{"type": "response.output_text.delta", "item_id": "msg_sim", "output_index": 0, "content_index": 0, "delta": chunk}})
+		}
+		completed := c.responsesObject("completed", c.text())
+		completed["id"] = created["id"]
+		completed["created_at"] = created["created_at"]
+		events = append(events,
+			simulationEvent{name: "response.output_text.done", data: map[string]any{"type": "response.output_text.done", "item_id": "msg_sim", "output_index": 0, "content_index": 0, "text": c.text()}},
+			simulationEvent{name: "response.content_part.done", data: map[string]any{"type": "response.content_part.done", "item_id": "msg_sim", "output_index": 0, "content_index": 0, "part": map[string]any{"type": "output_text", "text": c.text(), "annotations": []any{}}}},
+			simulationEvent{name: "response.output_item.done", data: map[string]any{"type": "response.output_item.done", "output_index": 0, "item": c.responsesMessage("completed", c.text())}},
+		)
+		doneType := "response.completed"
+		if c.truncated {
+			doneType = "response.incomplete"
+		}
+		events = append(events, simulationEvent{name: doneType, data: map[string]any{"type": doneType, "response": completed}})
+	case simulationChatCompletions:
+		id, created := "chatcmpl-sim-"+simulationID(), time.Now().Unix()
+		chunk := func(delta map[string]any, finish any) map[string]any {
+			return map[string]any{
+				"id": id, "object": "chat.completion.chunk", "created": created, "model": c.model,
+				"choices": []any{map[string]any{"index": 0, "delta": delta, "finish_reason": finish}},
+			}
+		}
+		events = append(events, simulationEvent{data: chunk(map[string]any{"role": "assistant", "content": ""}, nil)})
+		for _, text := range c.chunks {
+			events = append(events, simulationEvent{content: true, data: chunk(map[string]any{"content": text}, nil)})
+		}
+		events = append(events, simulationEvent{data: chunk(map[string]any{}, c.finishReason("stop", "length"))})
+		// 与 OpenAI 一致：usage 以 choices 为空的独立分块返回（网关转发时按需强制 include_usage）
+		events = append(events, simulationEvent{data: map[string]any{
+			"id": id, "object": "chat.completion.chunk", "created": created, "model": c.model,
+			"choices": []any{}, "usage": c.chatUsage(),
+		}})
+	case simulationGemini:
+		for i, text := range c.chunks {
+			events = append(events, simulationEvent{content: true, data: c.geminiChunk(text, i == len(c.chunks)-1)})
+		}
+		if len(c.chunks) == 0 {
+			events = append(events, simulationEvent{data: c.geminiChunk("", true)})
+		}
+	default:
+		id := "msg_sim_" + simulationID()
+		events = append(events,
+			simulationEvent{name: "message_start", data: map[string]any{"type": "message_start", "message": map[string]any{
+				"id": id, "type": "message", "role": "assistant", "model": c.model, "content": []any{},
+				"stop_reason": nil, "stop_sequence": nil, "usage": c.anthropicUsage(1),
+			}}},
+			simulationEvent{name: "content_block_start", data: map[string]any{"type": "content_block_start", "index": 0, "content_block": map[string]any{"type": "text", "text": ""}}},
+		)
+		for _, text := range c.chunks {
+			events = append(events, simulationEvent{name: "content_block_delta", content: true, data: map[string]any{"type": "content_block_delta", "index": 0, "delta": map[string]any{"type": "text_delta", "text": text}}})
+		}
+		events = append(events,
+			simulationEvent{name: "content_block_stop", data: map[string]any{"type": "content_block_stop", "index": 0}},
+			simulationEvent{name: "message_delta", data: map[string]any{
+				"type":  "message_delta",
+				"delta": map[string]any{"stop_reason": c.finishReason("end_turn", "max_tokens"), "stop_sequence": nil},
+				"usage": map[string]any{"output_tokens": c.outputTokens},
+			}},
+			simulationEvent{name: "message_stop", data: map[string]any{"type": "message_stop"}},
+		)
+	}
+	return events
+}
+
+func simulationID() string {
+	return strings.ReplaceAll(uuid.NewString(), "-", "")
+}
+
+func simulationResponse(req *http.Request, status int, contentType string, body io.ReadCloser) *http.Response {
+	header := http.Header{}
+	header.Set("Content-Type", contentType)
+	// 与真实上游一致地返回请求 ID，便于在日志中关联演练请求
+	header.Set("Request-Id", "req_sim_"+simulationID())
+	header.Set("X-Request-Id", "req_sim_"+simulationID())
+	return &http.Response{
+		Status:     fmt.Sprintf("%d %s", status, http.StatusText(status)),
+		StatusCode: status,
+		Proto:      "HTTP/1.1",
+		ProtoMajor: 1,
+		ProtoMinor: 1,
+		Header:     header,
+		Body:       body,
+		Request:    req,
+	}
+}
+
+func simulationJSONResponse(req *http.Request, payload any) *http.Response {
+	data, _ := json.Marshal(payload)
+	resp := simulationResponse(req, http.StatusOK, "application/json", io.NopCloser(bytes.NewReader(data)))
+	resp.ContentLength = int64(len(data))
+	return resp
+}
+
+func simulationErrorResponse(req *http.Request, status int, message string) *http.Response {
+	data, _ := json.Marshal(map[string]any{
+		"type":  "error",
+		"error": map[string]any{"type": "invalid_request_error", "message": message},
+	})
+	resp := simulationResponse(req, status, "application/json", io.NopCloser(bytes.NewReader(data)))
+	resp.ContentLength = int64(len(data))
+	return resp
+}
@@ -0,0 +1,149 @@
+package repository
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ShaohongDong/sub2api/internal/config"
+	"github.com/ShaohongDong/sub2api/internal/service"
+	"github.com/stretchr/testify/require"
+	"github.com/tidwall/gjson"
+)
+
+// unreachableUpstream 演练请求不应到达真实上游
+type unreachableUpstream struct{ calls int }
+
+func (u *unreachableUpstream) Do(*http.Request, string, int64, int) (*http.Response, error) {
+	u.calls++
+	return &http.Response{StatusCode: http.StatusOK, Body: http.NoBody, Header: http.Header{}}, nil
+}
+
+func (u *unreachableUpstream) DoWithTLS(req *http.Request, proxyURL string, accountID int64, accountConcurrency int, _ bool) (*http.Response, error) {
+	return u.Do(req, proxyURL, accountID, accountConcurrency)
+}
+
+func newSimulationTestUpstream(sim config.GatewaySimulationConfig) (*simulatedHTTPUpstream, *unreachableUpstream) {
+	next := &unreachableUpstream{}
+	cfg := &config.Config{}
+	cfg.Gateway.Simulation = sim
+	return newSimulatedHTTPUpstream(next, cfg), next
+}
+
+func doSimulated(t *testing.T, u *simulatedHTTPUpstream, url, body string) (*http.Response, string) {
+	t.Helper()
+	req, err := http.NewRequestWithContext(service.WithSimulation(context.Background()), http.MethodPost, url, strings.NewReader(body))
+	require.NoError(t, err)
+	resp, err := u.Do(req, "", 1, 1)
+	require.NoError(t, err)
+	data, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+	_ = resp.Body.Close()
+	return resp, string(data)
+}
+
+func TestSimulatedUpstreamPassesThroughRegularRequests(t *testing.T) {
+	u, next := newSimulationTestUpstream(config.GatewaySimulationConfig{Enabled: true})
+	req, err := http.NewRequest(http.MethodPost, "https://api.anthropic.com/v1/messages", strings.NewReader(`{}`))
+	require.NoError(t, err)
+	_, err = u.Do(req, "", 1, 1)
+	require.NoError(t, err)
+	require.Equal(t, 1, next.calls)
+}
+
+func TestSimulatedUpstreamAnthropic(t *testing.T) {
+	u, next := newSimulationTestUpstream(config.GatewaySimulationConfig{ResponseMode: "echo"})
+	body := `{"model":"claude-sonnet-4-5","messages":[{"role":"user","content":"hello from the load test"}]}`
+
+	resp, out := doSimulated(t, u, "https://api.anthropic.com/v1/messages", body)
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	require.Equal(t, "hello from the load test", gjson.Get(out, "content.0.text").String())
+	require.Equal(t, "end_turn", gjson.Get(out, "stop_reason").String())
+	require.Positive(t, gjson.Get(out, "usage.input_tokens").Int())
+	require.Positive(t, gjson.Get(out, "usage.output_tokens").Int())
+
+	resp, out = doSimulated(t, u, "https://api.anthropic.com/v1/messages", strings.Replace(body, `"messages"`, `"stream":true,"messages"`, 1))
+	require.Equal(t, "text/event-stream", resp.Header.Get("Content-Type"))
+	require.Contains(t, out, "event: message_start")
+	require.Contains(t, out, `"text":" load"`)
+	require.True(t, strings.HasSuffix(out, "event: message_stop\ndata: {\"type\":\"message_stop\"}\n\n"))
+	require.Zero(t, next.calls)
+}
+
+func TestSimulatedUpstreamOutputTokensAndMaxTokens(t *testing.T) {
+	u, _ := newSimulationTestUpstream(config.GatewaySimulationConfig{CannedText: "alpha beta gamma", OutputTokens: 200})
+
+	_, out := doSimulated(t, u, "https://api.openai.com/v1/chat/completions", `{"model":"gpt-4o","messages":[{"role":"user","content":"hi"}]}`)
+	require.GreaterOrEqual(t, gjson.Get(out, "usage.completion_tokens").Int(), int64(200))
+	require.Equal(t, "stop", gjson.Get(out, "choices.0.finish_reason").String())
+
+	_, out = doSimulated(t, u, "https://api.openai.com/v1/chat/completions", `{"model":"gpt-4o","max_tokens":10,"messages":[{"role":"user","content":"hi"}]}`)
+	require.LessOrEqual(t, gjson.Get(out, "usage.completion_tokens").Int(), int64(10))
+	require.Equal(t, "length", gjson.Get(out, "choices.0.finish_reason").String())
+}
+
+func TestSimulatedUpstreamResponsesStream(t *testing.T) {
+	u, _ := newSimulationTestUpstream(config.GatewaySimulationConfig{CannedText: "simulated codex reply"})
+	_, out := doSimulated(t, u, "https://chatgpt.com/backend-api/codex/responses", `{"model":"gpt-5","stream":true,"input":[{"role":"user","content":[{"type":"input_text","text":"hi"}]}]}`)
+
+	require.Contains(t, out, "event: response.created")
+	require.Contains(t, out, `"delta":" codex"`)
+	idx := strings.Index(out, "event: response.completed\ndata: ")
+	require.GreaterOrEqual(t, idx, 0)
+	completed := gjson.Parse(strings.TrimSpace(out[idx+len("event: response.completed\ndata: "):]))
+	require.Equal(t, "simulated codex reply", completed.Get("response.output.0.content.0.text").String())
+	require.Positive(t, completed.Get("response.usage.output_tokens").Int())
+}
+
+func TestSimulatedUpstreamGemini(t *testing.T) {
+	u, _ := newSimulationTestUpstream(config.GatewaySimulationConfig{CannedText: "gemini says hi"})
+	body := `{"contents":[{"role":"user","parts":[{"text":"hello"}]}]}`
+
+	_, out := doSimulated(t, u, "https://generativelanguage.googleapis.com/v1beta/models/gemini-2.5-flash:generateContent", body)
+	require.Equal(t, "gemini says hi", gjson.Get(out, "candidates.0.content.parts.0.text").String())
+	require.Equal(t, "gemini-2.5-flash", gjson.Get(out, "modelVersion").String())
+	require.Positive(t, gjson.Get(out, "usageMetadata.candidatesTokenCount").Int())
+
+	_, out = doSimulated(t, u, "https://cloudcode-pa.googleapis.com/v1internal:streamGenerateContent?alt=sse", `{"model":"gemini-2.5-pro","request":`+body+`}`)
+	lines := strings.Split(strings.TrimSpace(out), "\n\n")
+	last := gjson.Parse(strings.TrimPrefix(lines[len(lines)-1], "data: "))
+	require.Equal(t, "STOP", last.Get("response.candidates.0.finishReason").String())
+	require.Positive(t, last.Get("response.usageMetadata.promptTokenCount").Int())
+
+	_, out = doSimulated(t, u, "https://generativelanguage.googleapis.com/v1beta/models/gemini-2.5-flash:streamGenerateContent", body)
+	require.True(t, gjson.Valid(out))
+	require.Len(t, gjson.Parse(out).Array(), 3)
+}
+
+func TestSimulatedUpstreamUnsupportedEndpoint(t *testing.T) {
+	u, next := newSimulationTestUpstream(config.GatewaySimulationConfig{})
+	resp, out := doSimulated(t, u, "https://api.openai.com/v1/images/generations", `{"prompt":"cat"}`)
+	require.Equal(t, http.StatusBadRequest, resp.StatusCode)
+	require.Contains(t, gjson.Get(out, "error.message").String(), "simulation mode")
+	require.Zero(t, next.calls)
+}
+
+func TestSimulatedUpstreamStreamStopsOnCancel(t *testing.T) {
+	u, _ := newSimulationTestUpstream(config.GatewaySimulationConfig{CannedText: "one two three four", ChunkIntervalMS: 1000})
+	ctx, cancel := context.WithCancel(service.WithSimulation(context.Background()))
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, "https://api.anthropic.com/v1/messages", strings.NewReader(`{"model":"claude","stream":true}`))
+	require.NoError(t, err)
+	resp, err := u.Do(req, "", 1, 1)
+	require.NoError(t, err)
+
+	done := make(chan error, 1)
+	go func() {
+		_, err := io.ReadAll(resp.Body)
+		done <- err
+	}()
+	cancel()
+	select {
+	case err := <-done:
+		require.ErrorIs(t, err, context.Canceled)
+	case <-time.After(2 * time.Second):
+		t.Fatal("simulated stream did not stop after cancellation")
+	}
+}
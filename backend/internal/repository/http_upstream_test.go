@@ -1,13 +1,17 @@
 package repository
 
 import (
+	"context"
 	"io"
 	"net/http"
+	"strings"
 	"sync/atomic"
 	"testing"
 	"time"
 
 	"github.com/ShaohongDong/sub2api/internal/config"
+	"github.com/ShaohongDong/sub2api/internal/pkg/ctxkey"
+	"github.com/ShaohongDong/sub2api/internal/service"
 	"github.com/stretchr/testify/require"
 	"github.com/stretchr/testify/suite"
 )
@@ -34,7 +38,7 @@ func (s *HTTPUpstreamSuite) SetupTest() {
 // newService 创建测试用的 httpUpstreamService 实例
 // 返回具体类型以便访问内部状态进行断言
 func (s *HTTPUpstreamSuite) newService() *httpUpstreamService {
-	up := NewHTTPUpstream(s.cfg)
+	up := unwrapRetryingHTTPUpstream(NewHTTPUpstream(s.cfg))
 	svc, ok := up.(*httpUpstreamService)
 	require.True(s.T(), ok, "expected *httpUpstreamService")
 	return svc
@@ -61,6 +65,39 @@ func (s *HTTPUpstreamSuite) TestCustomResponseHeaderTimeout() {
 	require.Equal(s.T(), 7*time.Second, transport.ResponseHeaderTimeout, "ResponseHeaderTimeout mismatch")
 }
 
+// TestTransportTuning 测试建连/握手超时、TLS 会话缓存与 HTTP/2 配置
+func (s *HTTPUpstreamSuite) TestTransportTuning() {
+	s.cfg.Gateway = config.GatewayConfig{DialTimeoutSeconds: 3, TLSHandshakeTimeoutSeconds: 4, TLSSessionCacheSize: 16}
+	svc := s.newService()
+	entry := mustGetOrCreateClient(s.T(), svc, "", 0, 0)
+	transport, ok := entry.client.Transport.(*http.Transport)
+	require.True(s.T(), ok, "expected *http.Transport")
+	require.Equal(s.T(), 4*time.Second, transport.TLSHandshakeTimeout)
+	require.NotNil(s.T(), transport.DialContext)
+	require.True(s.T(), transport.ForceAttemptHTTP2)
+	require.NotNil(s.T(), transport.TLSClientConfig)
+	require.NotNil(s.T(), transport.TLSClientConfig.ClientSessionCache)
+	require.Nil(s.T(), transport.TLSNextProto)
+
+	// 同一账号+代理复用同一 Transport（沿用 TLS 会话缓存与空闲连接）
+	again := mustGetOrCreateClient(s.T(), svc, "", 0, 0)
+	require.Same(s.T(), entry.client, again.client)
+}
+
+// TestTransportTuning_DisableHTTP2 测试关闭 HTTP/2 与 TLS 会话缓存
+func (s *HTTPUpstreamSuite) TestTransportTuning_DisableHTTP2() {
+	s.cfg.Gateway = config.GatewayConfig{DisableUpstreamHTTP2: true}
+	svc := s.newService()
+	entry := mustGetOrCreateClient(s.T(), svc, "socks5://127.0.0.1:1080", 1, 0)
+	transport, ok := entry.client.Transport.(*http.Transport)
+	require.True(s.T(), ok, "expected *http.Transport")
+	require.False(s.T(), transport.ForceAttemptHTTP2)
+	require.NotNil(s.T(), transport.TLSNextProto)
+	require.Empty(s.T(), transport.TLSNextProto)
+	require.Nil(s.T(), transport.TLSClientConfig)
+	require.Equal(s.T(), defaultTLSHandshakeTimeout, transport.TLSHandshakeTimeout)
+}
+
 // TestGetOrCreateClient_InvalidURLReturnsError 测试无效代理 URL 返回错误
 // 验证解析失败时拒绝回退到直连模式
 func (s *HTTPUpstreamSuite) TestGetOrCreateClient_InvalidURLReturnsError() {
@@ -116,6 +153,70 @@ func (s *HTTPUpstreamSuite) TestDo_WithoutProxy_GoesDirect() {
 	require.Equal(s.T(), "direct", string(b), "unexpected body")
 }
 
+// TestDo_RecordsUpstreamTrace 测试调试重放时记录上游请求与响应
+// 验证 context 携带记录器时留存脱敏后的请求头、查询参数、请求体与响应体
+func (s *HTTPUpstreamSuite) TestDo_RecordsUpstreamTrace() {
+	upstream := newLocalTestServer(s.T(), http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("x-request-id", "req_1")
+		w.WriteHeader(http.StatusBadRequest)
+		_, _ = io.WriteString(w, `{"error":"bad tool schema"}`)
+	}))
+	s.T().Cleanup(upstream.Close)
+
+	up := NewHTTPUpstream(s.cfg)
+	rec := service.NewUpstreamTraceRecorder(1024)
+	ctx := service.WithUpstreamTrace(context.Background(), rec)
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, upstream.URL+"/v1/responses?key=secret&alt=sse", strings.NewReader(`{"model":"gpt-5","api_key":"sk-x"}`))
+	require.NoError(s.T(), err, "NewRequest")
+	req.Header.Set("Authorization", "Bearer sk-live")
+	req.Header.Set("x-goog-api-key", "g-key")
+	resp, err := up.Do(req, "", 1, 1)
+	require.NoError(s.T(), err, "Do")
+	_, _ = io.ReadAll(resp.Body)
+	_ = resp.Body.Close()
+
+	exchanges := rec.Exchanges()
+	require.Len(s.T(), exchanges, 1)
+	ex := exchanges[0]
+	require.Equal(s.T(), http.MethodPost, ex.Method)
+	require.Contains(s.T(), ex.URL, "key=%5BREDACTED%5D")
+	require.Contains(s.T(), ex.URL, "alt=sse")
+	require.Equal(s.T(), "Bearer [redacted]", ex.RequestHeaders["Authorization"])
+	require.Equal(s.T(), "[redacted]", ex.RequestHeaders["X-Goog-Api-Key"])
+	require.JSONEq(s.T(), `{"model":"gpt-5","api_key":"[REDACTED]"}`, ex.RequestBody)
+	require.Equal(s.T(), http.StatusBadRequest, ex.StatusCode)
+	require.Equal(s.T(), "req_1", ex.ResponseHeaders["X-Request-Id"])
+	require.Equal(s.T(), `{"error":"bad tool schema"}`, ex.ResponseBody)
+}
+
+// TestDo_SetsRequestIDHeader 测试向上游携带网关请求 ID
+// 验证按 gateway.upstream_request_id_header 设置请求头，留空时不携带
+func (s *HTTPUpstreamSuite) TestDo_SetsRequestIDHeader() {
+	var got atomic.Value
+	upstream := newLocalTestServer(s.T(), http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got.Store(r.Header.Get("X-Request-Id"))
+	}))
+	s.T().Cleanup(upstream.Close)
+
+	ctx := context.WithValue(context.Background(), ctxkey.RequestID, "rid-1")
+	for _, tc := range []struct {
+		header string
+		want   string
+	}{
+		{header: "X-Request-Id", want: "rid-1"},
+		{header: "", want: ""},
+	} {
+		s.cfg.Gateway.UpstreamRequestIDHeader = tc.header
+		up := NewHTTPUpstream(s.cfg)
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, upstream.URL+"/x", nil)
+		require.NoError(s.T(), err, "NewRequest")
+		resp, err := up.Do(req, "", 1, 1)
+		require.NoError(s.T(), err, "Do")
+		_ = resp.Body.Close()
+		require.Equal(s.T(), tc.want, got.Load(), "header=%q", tc.header)
+	}
+}
+
 // TestDo_WithHTTPProxy_UsesProxy 测试 HTTP 代理功能
 // 验证请求通过代理服务器转发，使用绝对 URI 格式
 func (s *HTTPUpstreamSuite) TestDo_WithHTTPProxy_UsesProxy() {
@@ -0,0 +1,53 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/ShaohongDong/sub2api/internal/service"
+	"github.com/redis/go-redis/v9"
+)
+
+// clusterLeaderKey 集群领导者租约：值为领导者实例 ID，PX 为租约时长
+const clusterLeaderKey = "cluster:leader"
+
+// Lua 脚本：获取或续期领导者租约（空闲时获取，自己持有时续期，被他人持有时失败）
+var acquireLeaderScript = redis.NewScript(`
+local cur = redis.call('GET', KEYS[1])
+if cur == ARGV[1] then
+    redis.call('PEXPIRE', KEYS[1], tonumber(ARGV[2]))
+    return 1
+end
+if cur ~= false then return 0 end
+redis.call('SET', KEYS[1], ARGV[1], 'PX', tonumber(ARGV[2]))
+return 1
+`)
+
+// Lua 脚本：仅当租约仍由自己持有时释放
+var releaseLeaderScript = redis.NewScript(`
+if redis.call('GET', KEYS[1]) == ARGV[1] then
+    return redis.call('DEL', KEYS[1])
+end
+return 0
+`)
+
+type leaderLockCache struct {
+	rdb *redis.Client
+}
+
+// NewLeaderLockCache 创建集群领导者租约缓存
+func NewLeaderLockCache(rdb *redis.Client) service.LeaderLockCache {
+	return &leaderLockCache{rdb: rdb}
+}
+
+func (c *leaderLockCache) AcquireLeader(ctx context.Context, instanceID string, lease time.Duration) (bool, error) {
+	n, err := acquireLeaderScript.Run(ctx, c.rdb, []string{clusterLeaderKey}, instanceID, lease.Milliseconds()).Int()
+	if err != nil {
+		return false, err
+	}
+	return n == 1, nil
+}
+
+func (c *leaderLockCache) ReleaseLeader(ctx context.Context, instanceID string) error {
+	return releaseLeaderScript.Run(ctx, c.rdb, []string{clusterLeaderKey}, instanceID).Err()
+}
@@ -0,0 +1,55 @@
+//go:build integration
+
+package repository
+
+import (
+	"testing"
+	"time"
+
+	"github.com/ShaohongDong/sub2api/internal/service"
+	"github.com/stretchr/testify/require"
+	"github.com/stretchr/testify/suite"
+)
+
+type LeaderLockCacheSuite struct {
+	IntegrationRedisSuite
+	cache service.LeaderLockCache
+}
+
+func (s *LeaderLockCacheSuite) SetupTest() {
+	s.IntegrationRedisSuite.SetupTest()
+	s.cache = NewLeaderLockCache(s.rdb)
+}
+
+func (s *LeaderLockCacheSuite) TestAcquireRenewAndRelease() {
+	ok, err := s.cache.AcquireLeader(s.ctx, "node-a", time.Minute)
+	require.NoError(s.T(), err)
+	require.True(s.T(), ok)
+
+	ok, err = s.cache.AcquireLeader(s.ctx, "node-b", time.Minute)
+	require.NoError(s.T(), err)
+	require.False(s.T(), ok, "lease held by another instance")
+
+	// 持有者再次获取即续期
+	ok, err = s.cache.AcquireLeader(s.ctx, "node-a", 2*time.Minute)
+	require.NoError(s.T(), err)
+	require.True(s.T(), ok)
+	ttl, err := s.rdb.PTTL(s.ctx, clusterLeaderKey).Result()
+	require.NoError(s.T(), err)
+	require.Greater(s.T(), ttl, time.Minute)
+
+	// 非持有者释放不生效
+	require.NoError(s.T(), s.cache.ReleaseLeader(s.ctx, "node-b"))
+	owner, err := s.rdb.Get(s.ctx, clusterLeaderKey).Result()
+	require.NoError(s.T(), err)
+	require.Equal(s.T(), "node-a", owner)
+
+	require.NoError(s.T(), s.cache.ReleaseLeader(s.ctx, "node-a"))
+	ok, err = s.cache.AcquireLeader(s.ctx, "node-b", time.Minute)
+	require.NoError(s.T(), err)
+	require.True(s.T(), ok)
+}
+
+func TestLeaderLockCacheSuite(t *testing.T) {
+	suite.Run(t, new(LeaderLockCacheSuite))
+}
@@ -193,59 +193,189 @@ func applyMigrationsFS(ctx context.Context, db *sql.DB, fsys fs.FS) error {
 			return fmt.Errorf("check migration %s: %w", name, rowErr)
 		}
 
-		nonTx, err := validateMigrationExecutionMode(name, content)
-		if err != nil {
-			return fmt.Errorf("validate migration %s: %w", name, err)
+		// 只执行 Up 部分；校验和仍基于完整文件内容，与历史记录保持一致。
+		up, _, _ := parseMigrationSections(content)
+		if err := execMigrationSQL(ctx, db, name, up, "INSERT INTO schema_migrations (filename, checksum) VALUES ($1, $2)", name, checksum); err != nil {
+			return err
 		}
+	}
 
-		if nonTx {
-			// *_notx.sql：用于 CREATE/DROP INDEX CONCURRENTLY 场景，必须非事务执行。
-			// 逐条语句执行，避免将多条 CONCURRENTLY 语句放入同一个隐式事务块。
-			statements := splitSQLStatements(content)
-			for i, stmt := range statements {
-				trimmed := strings.TrimSpace(stmt)
-				if trimmed == "" {
-					continue
-				}
-				if stripSQLLineComment(trimmed) == "" {
-					continue
-				}
-				if _, err := db.ExecContext(ctx, trimmed); err != nil {
-					return fmt.Errorf("apply migration %s (non-tx statement %d): %w", name, i+1, err)
-				}
+	return nil
+}
+
+// execMigrationSQL 执行一段迁移 SQL（Up 或 Down），并在同一事务内执行 record 语句更新迁移记录。
+// *_notx.sql 逐条非事务执行，全部成功后再执行 record。
+func execMigrationSQL(ctx context.Context, db *sql.DB, name, body, record string, recordArgs ...any) error {
+	nonTx, err := validateMigrationExecutionMode(name, body)
+	if err != nil {
+		return fmt.Errorf("validate migration %s: %w", name, err)
+	}
+
+	if nonTx {
+		// *_notx.sql：用于 CREATE/DROP INDEX CONCURRENTLY 场景，必须非事务执行。
+		// 逐条语句执行，避免将多条 CONCURRENTLY 语句放入同一个隐式事务块。
+		statements := splitSQLStatements(body)
+		for i, stmt := range statements {
+			trimmed := strings.TrimSpace(stmt)
+			if trimmed == "" {
+				continue
 			}
-			if _, err := db.ExecContext(ctx, "INSERT INTO schema_migrations (filename, checksum) VALUES ($1, $2)", name, checksum); err != nil {
-				return fmt.Errorf("record migration %s (non-tx): %w", name, err)
+			if stripSQLLineComment(trimmed) == "" {
+				continue
+			}
+			if _, err := db.ExecContext(ctx, trimmed); err != nil {
+				return fmt.Errorf("apply migration %s (non-tx statement %d): %w", name, i+1, err)
 			}
-			continue
 		}
-
-		// 默认迁移在事务中执行，确保原子性：要么完全成功，要么完全回滚。
-		tx, err := db.BeginTx(ctx, nil)
-		if err != nil {
-			return fmt.Errorf("begin migration %s: %w", name, err)
+		if _, err := db.ExecContext(ctx, record, recordArgs...); err != nil {
+			return fmt.Errorf("record migration %s (non-tx): %w", name, err)
 		}
+		return nil
+	}
 
-		// 执行迁移 SQL
-		if _, err := tx.ExecContext(ctx, content); err != nil {
+	// 默认迁移在事务中执行，确保原子性：要么完全成功，要么完全回滚。
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("begin migration %s: %w", name, err)
+	}
+
+	// 执行迁移 SQL（仅含注释时跳过）
+	if stripSQLLineComment(body) != "" {
+		if _, err := tx.ExecContext(ctx, body); err != nil {
 			_ = tx.Rollback()
 			return fmt.Errorf("apply migration %s: %w", name, err)
 		}
+	}
 
-		// 记录迁移已完成，保存文件名和校验和
-		if _, err := tx.ExecContext(ctx, "INSERT INTO schema_migrations (filename, checksum) VALUES ($1, $2)", name, checksum); err != nil {
-			_ = tx.Rollback()
-			return fmt.Errorf("record migration %s: %w", name, err)
+	// 记录迁移状态变更
+	if _, err := tx.ExecContext(ctx, record, recordArgs...); err != nil {
+		_ = tx.Rollback()
+		return fmt.Errorf("record migration %s: %w", name, err)
+	}
+
+	// 提交事务
+	if err := tx.Commit(); err != nil {
+		_ = tx.Rollback()
+		return fmt.Errorf("commit migration %s: %w", name, err)
+	}
+	return nil
+}
+
+// RollbackMigrations 按文件名倒序回滚最近应用的 steps 个迁移，返回已回滚的迁移文件名。
+//
+// 只有包含 "-- +goose Down" 部分的迁移可以回滚；任一待回滚迁移缺少 Down 部分、
+// 文件不在当前版本中或校验和不匹配时，不执行任何回滚并返回错误。
+func RollbackMigrations(ctx context.Context, db *sql.DB, steps int) ([]string, error) {
+	if db == nil {
+		return nil, errors.New("nil sql db")
+	}
+	return rollbackMigrationsFS(ctx, db, migrations.FS, steps)
+}
+
+func rollbackMigrationsFS(ctx context.Context, db *sql.DB, fsys fs.FS, steps int) ([]string, error) {
+	if steps <= 0 {
+		return nil, fmt.Errorf("rollback steps must be positive, got %d", steps)
+	}
+	if err := pgAdvisoryLock(ctx, db); err != nil {
+		return nil, err
+	}
+	defer func() {
+		_ = pgAdvisoryUnlock(context.Background(), db)
+	}()
+
+	rows, err := db.QueryContext(ctx, "SELECT filename, checksum FROM schema_migrations ORDER BY filename DESC LIMIT $1", steps)
+	if err != nil {
+		return nil, fmt.Errorf("list applied migrations: %w", err)
+	}
+	type pendingRollback struct {
+		name     string
+		checksum string
+		down     string
+	}
+	var pending []pendingRollback
+	for rows.Next() {
+		var name, applied string
+		if err := rows.Scan(&name, &applied); err != nil {
+			_ = rows.Close()
+			return nil, fmt.Errorf("scan applied migration: %w", err)
 		}
+		pending = append(pending, pendingRollback{name: name, checksum: applied})
+	}
+	if err := rows.Close(); err != nil {
+		return nil, fmt.Errorf("list applied migrations: %w", err)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("list applied migrations: %w", err)
+	}
 
-		// 提交事务
-		if err := tx.Commit(); err != nil {
-			_ = tx.Rollback()
-			return fmt.Errorf("commit migration %s: %w", name, err)
+	// 先全部校验再执行，避免回滚到一半才发现某个迁移不可回滚
+	for i := range pending {
+		name, applied := pending[i].name, pending[i].checksum
+		contentBytes, err := fs.ReadFile(fsys, name)
+		if err != nil {
+			return nil, fmt.Errorf("migration %s is not part of this build, roll back with the version that applied it: %w", name, err)
 		}
+		content := strings.TrimSpace(string(contentBytes))
+		sum := sha256.Sum256([]byte(content))
+		checksum := hex.EncodeToString(sum[:])
+		if applied != checksum && !isMigrationChecksumCompatible(name, applied, checksum) {
+			return nil, fmt.Errorf("migration %s checksum mismatch (db=%s file=%s)", name, applied, checksum)
+		}
+		_, down, hasDown := parseMigrationSections(content)
+		if !hasDown {
+			return nil, fmt.Errorf("migration %s has no -- +goose Down section and cannot be rolled back", name)
+		}
+		pending[i].down = down
 	}
 
-	return nil
+	rolledBack := make([]string, 0, len(pending))
+	for _, item := range pending {
+		if err := execMigrationSQL(ctx, db, item.name, item.down, "DELETE FROM schema_migrations WHERE filename = $1", item.name); err != nil {
+			return rolledBack, fmt.Errorf("rollback: %w", err)
+		}
+		rolledBack = append(rolledBack, item.name)
+	}
+	return rolledBack, nil
+}
+
+const (
+	migrationUpMarker   = "-- +goose up"
+	migrationDownMarker = "-- +goose down"
+)
+
+// parseMigrationSections 按 goose 注释拆分迁移文件的 Up / Down 部分，并去掉 "-- +goose" 注释行。
+// 没有 "-- +goose Up" 标记的文件整体视为 Up，且不可回滚。
+func parseMigrationSections(content string) (up, down string, hasDown bool) {
+	lines := strings.Split(content, "\n")
+	var upLines, downLines []string
+	section := ""
+	hasMarkers := false
+	for _, line := range lines {
+		marker := strings.ToLower(strings.Join(strings.Fields(line), " "))
+		switch {
+		case strings.HasPrefix(marker, migrationUpMarker):
+			section, hasMarkers = "up", true
+			continue
+		case strings.HasPrefix(marker, migrationDownMarker):
+			section, hasMarkers, hasDown = "down", true, true
+			continue
+		case strings.HasPrefix(marker, "-- +goose"):
+			continue
+		}
+		switch section {
+		case "up":
+			upLines = append(upLines, line)
+		case "down":
+			downLines = append(downLines, line)
+		default:
+			// 第一个标记之前的内容（通常是说明注释）归入 Up
+			upLines = append(upLines, line)
+		}
+	}
+	if !hasMarkers {
+		return content, "", false
+	}
+	return strings.TrimSpace(strings.Join(upLines, "\n")), strings.TrimSpace(strings.Join(downLines, "\n")), hasDown
 }
 
 func ensureAtlasBaselineAligned(ctx context.Context, db *sql.DB, fsys fs.FS) error {
@@ -0,0 +1,144 @@
+package repository
+
+import (
+	"context"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"strings"
+	"testing"
+	"testing/fstest"
+
+	sqlmock "github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/require"
+)
+
+const gooseMigrationFixture = `-- 说明注释
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE t (id INT);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS t;
+-- +goose StatementEnd`
+
+func migrationFixtureChecksum(content string) string {
+	sum := sha256.Sum256([]byte(strings.TrimSpace(content)))
+	return hex.EncodeToString(sum[:])
+}
+
+func TestParseMigrationSections(t *testing.T) {
+	t.Run("goose标记拆分Up与Down", func(t *testing.T) {
+		up, down, hasDown := parseMigrationSections(gooseMigrationFixture)
+		require.Equal(t, "-- 说明注释\nCREATE TABLE t (id INT);", up)
+		require.Equal(t, "DROP TABLE IF EXISTS t;", down)
+		require.True(t, hasDown)
+	})
+
+	t.Run("无标记时整体为Up且不可回滚", func(t *testing.T) {
+		up, down, hasDown := parseMigrationSections("ALTER TABLE t ADD COLUMN a INT;")
+		require.Equal(t, "ALTER TABLE t ADD COLUMN a INT;", up)
+		require.Empty(t, down)
+		require.False(t, hasDown)
+	})
+
+	t.Run("空Down部分仍可回滚", func(t *testing.T) {
+		up, down, hasDown := parseMigrationSections("-- +goose Up\nSELECT 1;\n-- +goose Down\n-- 无需回滚操作")
+		require.Equal(t, "SELECT 1;", up)
+		require.Equal(t, "-- 无需回滚操作", down)
+		require.True(t, hasDown)
+	})
+}
+
+func TestApplyMigrationsFS_OnlyRunsUpSection(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer func() { _ = db.Close() }()
+
+	prepareMigrationsBootstrapExpectations(mock)
+	mock.ExpectQuery("SELECT checksum FROM schema_migrations WHERE filename = \\$1").
+		WithArgs("001_create_t.sql").
+		WillReturnError(sql.ErrNoRows)
+	mock.ExpectBegin()
+	mock.ExpectExec("^-- 说明注释\nCREATE TABLE t \\(id INT\\);$").
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec("INSERT INTO schema_migrations \\(filename, checksum\\) VALUES \\(\\$1, \\$2\\)").
+		WithArgs("001_create_t.sql", migrationFixtureChecksum(gooseMigrationFixture)).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectCommit()
+	mock.ExpectExec("SELECT pg_advisory_unlock\\(\\$1\\)").
+		WithArgs(migrationsAdvisoryLockID).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	fsys := fstest.MapFS{"001_create_t.sql": &fstest.MapFile{Data: []byte(gooseMigrationFixture)}}
+	require.NoError(t, applyMigrationsFS(context.Background(), db, fsys))
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestRollbackMigrationsFS_RunsDownAndDeletesRecord(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer func() { _ = db.Close() }()
+
+	mock.ExpectQuery("SELECT pg_try_advisory_lock\\(\\$1\\)").
+		WithArgs(migrationsAdvisoryLockID).
+		WillReturnRows(sqlmock.NewRows([]string{"pg_try_advisory_lock"}).AddRow(true))
+	mock.ExpectQuery("SELECT filename, checksum FROM schema_migrations ORDER BY filename DESC LIMIT \\$1").
+		WithArgs(1).
+		WillReturnRows(sqlmock.NewRows([]string{"filename", "checksum"}).AddRow("002_create_t.sql", migrationFixtureChecksum(gooseMigrationFixture)))
+	mock.ExpectBegin()
+	mock.ExpectExec("^DROP TABLE IF EXISTS t;$").
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec("DELETE FROM schema_migrations WHERE filename = \\$1").
+		WithArgs("002_create_t.sql").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+	mock.ExpectExec("SELECT pg_advisory_unlock\\(\\$1\\)").
+		WithArgs(migrationsAdvisoryLockID).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	fsys := fstest.MapFS{
+		"001_init.sql":     &fstest.MapFile{Data: []byte("CREATE TABLE a (id INT);")},
+		"002_create_t.sql": &fstest.MapFile{Data: []byte(gooseMigrationFixture)},
+	}
+	rolledBack, err := rollbackMigrationsFS(context.Background(), db, fsys, 1)
+	require.NoError(t, err)
+	require.Equal(t, []string{"002_create_t.sql"}, rolledBack)
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestRollbackMigrationsFS_RejectsIrreversibleBeforeChanges(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer func() { _ = db.Close() }()
+
+	irreversible := "CREATE TABLE a (id INT);"
+	mock.ExpectQuery("SELECT pg_try_advisory_lock\\(\\$1\\)").
+		WithArgs(migrationsAdvisoryLockID).
+		WillReturnRows(sqlmock.NewRows([]string{"pg_try_advisory_lock"}).AddRow(true))
+	mock.ExpectQuery("SELECT filename, checksum FROM schema_migrations ORDER BY filename DESC LIMIT \\$1").
+		WithArgs(2).
+		WillReturnRows(sqlmock.NewRows([]string{"filename", "checksum"}).
+			AddRow("002_create_t.sql", migrationFixtureChecksum(gooseMigrationFixture)).
+			AddRow("001_init.sql", migrationFixtureChecksum(irreversible)))
+	mock.ExpectExec("SELECT pg_advisory_unlock\\(\\$1\\)").
+		WithArgs(migrationsAdvisoryLockID).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	fsys := fstest.MapFS{
+		"001_init.sql":     &fstest.MapFile{Data: []byte(irreversible)},
+		"002_create_t.sql": &fstest.MapFile{Data: []byte(gooseMigrationFixture)},
+	}
+	rolledBack, err := rollbackMigrationsFS(context.Background(), db, fsys, 2)
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "001_init.sql has no -- +goose Down section")
+	require.Empty(t, rolledBack)
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestRollbackMigrationsFS_InvalidSteps(t *testing.T) {
+	_, err := rollbackMigrationsFS(context.Background(), nil, fstest.MapFS{}, 0)
+	require.Error(t, err)
+}
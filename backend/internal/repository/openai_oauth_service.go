@@ -15,11 +15,17 @@ import (
 
 // NewOpenAIOAuthClient creates a new OpenAI OAuth client
 func NewOpenAIOAuthClient() service.OpenAIOAuthClient {
-	return &openaiOAuthService{tokenURL: openai.TokenURL}
+	return &openaiOAuthService{
+		tokenURL:       openai.TokenURL,
+		deviceCodeURL:  openai.DeviceAuthUserCodeURL,
+		deviceTokenURL: openai.DeviceAuthTokenURL,
+	}
 }
 
 type openaiOAuthService struct {
-	tokenURL string
+	tokenURL       string
+	deviceCodeURL  string
+	deviceTokenURL string
 }
 
 func (s *openaiOAuthService) ExchangeCode(ctx context.Context, code, codeVerifier, redirectURI, proxyURL, clientID string) (*openai.TokenResponse, error) {
@@ -108,6 +114,64 @@ func (s *openaiOAuthService) refreshTokenWithClientID(ctx context.Context, refre
 	return &tokenResp, nil
 }
 
+func (s *openaiOAuthService) RequestDeviceCode(ctx context.Context, proxyURL, clientID string) (*openai.DeviceCodeResponse, error) {
+	client, err := createOpenAIReqClient(proxyURL)
+	if err != nil {
+		return nil, infraerrors.Newf(http.StatusBadGateway, "OPENAI_OAUTH_CLIENT_INIT_FAILED", "create HTTP client: %v", err)
+	}
+	clientID = strings.TrimSpace(clientID)
+	if clientID == "" {
+		clientID = openai.ClientID
+	}
+
+	var codeResp openai.DeviceCodeResponse
+	resp, err := client.R().
+		SetContext(ctx).
+		SetHeader("User-Agent", "codex-cli/0.91.0").
+		SetBodyJsonMarshal(map[string]string{"client_id": clientID}).
+		SetSuccessResult(&codeResp).
+		Post(s.deviceCodeURL)
+	if err != nil {
+		return nil, infraerrors.Newf(http.StatusBadGateway, "OPENAI_OAUTH_REQUEST_FAILED", "request failed: %v", err)
+	}
+	if !resp.IsSuccessState() {
+		return nil, infraerrors.Newf(http.StatusBadGateway, "OPENAI_OAUTH_DEVICE_CODE_FAILED", "device code request failed: status %d, body: %s", resp.StatusCode, resp.String())
+	}
+	if codeResp.DeviceAuthID == "" || codeResp.UserCode == "" {
+		return nil, infraerrors.New(http.StatusBadGateway, "OPENAI_OAUTH_DEVICE_CODE_FAILED", "device code response is missing device_auth_id or user_code")
+	}
+	return &codeResp, nil
+}
+
+func (s *openaiOAuthService) PollDeviceToken(ctx context.Context, deviceAuthID, userCode, proxyURL string) (*openai.DeviceTokenResponse, error) {
+	client, err := createOpenAIReqClient(proxyURL)
+	if err != nil {
+		return nil, infraerrors.Newf(http.StatusBadGateway, "OPENAI_OAUTH_CLIENT_INIT_FAILED", "create HTTP client: %v", err)
+	}
+
+	var tokenResp openai.DeviceTokenResponse
+	resp, err := client.R().
+		SetContext(ctx).
+		SetHeader("User-Agent", "codex-cli/0.91.0").
+		SetBodyJsonMarshal(map[string]string{"device_auth_id": deviceAuthID, "user_code": userCode}).
+		SetSuccessResult(&tokenResp).
+		Post(s.deviceTokenURL)
+	if err != nil {
+		return nil, infraerrors.Newf(http.StatusBadGateway, "OPENAI_OAUTH_REQUEST_FAILED", "request failed: %v", err)
+	}
+	// 用户尚未在验证页完成授权时返回 403 / 404
+	if resp.StatusCode == http.StatusForbidden || resp.StatusCode == http.StatusNotFound {
+		return nil, nil
+	}
+	if !resp.IsSuccessState() {
+		return nil, infraerrors.Newf(http.StatusBadGateway, "OPENAI_OAUTH_DEVICE_TOKEN_FAILED", "device token request failed: status %d, body: %s", resp.StatusCode, resp.String())
+	}
+	if tokenResp.AuthorizationCode == "" || tokenResp.CodeVerifier == "" {
+		return nil, infraerrors.New(http.StatusBadGateway, "OPENAI_OAUTH_DEVICE_TOKEN_FAILED", "device token response is missing authorization_code or code_verifier")
+	}
+	return &tokenResp, nil
+}
+
 func createOpenAIReqClient(proxyURL string) (*req.Client, error) {
 	return getSharedReqClient(reqClientOptions{
 		ProxyURL: proxyURL,
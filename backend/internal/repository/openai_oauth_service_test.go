@@ -2,11 +2,13 @@ package repository
 
 import (
 	"context"
+	"encoding/json"
 	"io"
 	"net/http"
 	"net/http/httptest"
 	"net/url"
 	"testing"
+	"time"
 
 	"github.com/ShaohongDong/sub2api/internal/pkg/openai"
 	"github.com/stretchr/testify/require"
@@ -35,7 +37,7 @@ func (s *OpenAIOAuthServiceSuite) TearDownTest() {
 
 func (s *OpenAIOAuthServiceSuite) setupServer(handler http.HandlerFunc) {
 	s.srv = newLocalTestServer(s.T(), handler)
-	s.svc = &openaiOAuthService{tokenURL: s.srv.URL}
+	s.svc = &openaiOAuthService{tokenURL: s.srv.URL, deviceCodeURL: s.srv.URL, deviceTokenURL: s.srv.URL}
 }
 
 func (s *OpenAIOAuthServiceSuite) TestExchangeCode_DefaultRedirectURI() {
@@ -228,6 +230,58 @@ func (s *OpenAIOAuthServiceSuite) TestRequestError_ClosedServer() {
 	require.ErrorContains(s.T(), err, "request failed")
 }
 
+func (s *OpenAIOAuthServiceSuite) TestRequestDeviceCode_SendsClientID() {
+	var body map[string]string
+	s.setupServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewDecoder(r.Body).Decode(&body)
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = io.WriteString(w, `{"device_auth_id":"dev-1","user_code":"ABCD-1234","interval":"7"}`)
+	}))
+
+	resp, err := s.svc.RequestDeviceCode(s.ctx, "", "")
+	require.NoError(s.T(), err)
+	require.Equal(s.T(), openai.ClientID, body["client_id"])
+	require.Equal(s.T(), "dev-1", resp.DeviceAuthID)
+	require.Equal(s.T(), "ABCD-1234", resp.UserCode)
+	require.Equal(s.T(), 7*time.Second, resp.PollInterval())
+}
+
+func (s *OpenAIOAuthServiceSuite) TestPollDeviceToken_PendingThenApproved() {
+	approved := false
+	var body map[string]string
+	s.setupServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewDecoder(r.Body).Decode(&body)
+		if !approved {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = io.WriteString(w, `{"authorization_code":"auth-code","code_challenge":"chal","code_verifier":"ver"}`)
+	}))
+
+	resp, err := s.svc.PollDeviceToken(s.ctx, "dev-1", "ABCD-1234", "")
+	require.NoError(s.T(), err)
+	require.Nil(s.T(), resp)
+	require.Equal(s.T(), map[string]string{"device_auth_id": "dev-1", "user_code": "ABCD-1234"}, body)
+
+	approved = true
+	resp, err = s.svc.PollDeviceToken(s.ctx, "dev-1", "ABCD-1234", "")
+	require.NoError(s.T(), err)
+	require.Equal(s.T(), "auth-code", resp.AuthorizationCode)
+	require.Equal(s.T(), "ver", resp.CodeVerifier)
+}
+
+func (s *OpenAIOAuthServiceSuite) TestPollDeviceToken_NonSuccessStatus() {
+	s.setupServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadRequest)
+		_, _ = io.WriteString(w, "expired")
+	}))
+
+	_, err := s.svc.PollDeviceToken(s.ctx, "dev-1", "ABCD-1234", "")
+	require.ErrorContains(s.T(), err, "status 400")
+	require.ErrorContains(s.T(), err, "expired")
+}
+
 func (s *OpenAIOAuthServiceSuite) TestContextCancel() {
 	started := make(chan struct{})
 	block := make(chan struct{})
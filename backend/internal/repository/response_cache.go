@@ -0,0 +1,45 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/ShaohongDong/sub2api/internal/service"
+	"github.com/redis/go-redis/v9"
+)
+
+const responseCacheKeyPrefix = "response_cache:"
+
+type responseCache struct {
+	rdb *redis.Client
+}
+
+// NewResponseCacheStore 创建 Redis 响应缓存存储（response_cache.backend=redis 时使用）
+func NewResponseCacheStore(rdb *redis.Client) service.ResponseCacheStore {
+	return &responseCache{rdb: rdb}
+}
+
+func (c *responseCache) GetResponse(ctx context.Context, key string) (*service.CachedResponse, error) {
+	raw, err := c.rdb.Get(ctx, responseCacheKeyPrefix+key).Bytes()
+	if err != nil {
+		if errors.Is(err, redis.Nil) {
+			return nil, service.ErrResponseCacheMiss
+		}
+		return nil, err
+	}
+	var resp service.CachedResponse
+	if err := json.Unmarshal(raw, &resp); err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+func (c *responseCache) SetResponse(ctx context.Context, key string, resp *service.CachedResponse, ttl time.Duration) error {
+	raw, err := json.Marshal(resp)
+	if err != nil {
+		return err
+	}
+	return c.rdb.Set(ctx, responseCacheKeyPrefix+key, raw, ttl).Err()
+}
@@ -0,0 +1,126 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ShaohongDong/sub2api/internal/service"
+	"github.com/redis/go-redis/v9"
+)
+
+const (
+	// sharedCircuitOpenKey 账号熔断 ZSET：member 为账号 ID，score 为到期时间（unix 毫秒）
+	sharedCircuitOpenKey = "shared_state:account_circuit_open"
+	// sharedUsageWindowPrefix Codex 用量窗口 HASH：field 为 "<slot>:r" / "<slot>:t"
+	sharedUsageWindowPrefix = "shared_state:openai_usage_window:"
+	// sharedUsageWindowTTL 用量窗口最长 7 天，多保留一天便于窗口边界统计
+	sharedUsageWindowTTL = 8 * 24 * time.Hour
+)
+
+type sharedStateCache struct {
+	rdb *redis.Client
+}
+
+func NewSharedStateCache(rdb *redis.Client) service.SharedStateCache {
+	return &sharedStateCache{rdb: rdb}
+}
+
+func sharedUsageWindowKey(accountID int64) string {
+	return fmt.Sprintf("%s%d", sharedUsageWindowPrefix, accountID)
+}
+
+// SetAccountCircuitOpen 记录账号熔断到期时间（只延长不缩短）
+func (c *sharedStateCache) SetAccountCircuitOpen(ctx context.Context, accountID int64, until time.Time) error {
+	return c.rdb.ZAddArgs(ctx, sharedCircuitOpenKey, redis.ZAddArgs{
+		GT:      true,
+		Members: []redis.Z{{Score: float64(until.UnixMilli()), Member: strconv.FormatInt(accountID, 10)}},
+	}).Err()
+}
+
+// ListOpenAccountCircuits 清理已到期的熔断并返回其余记录
+func (c *sharedStateCache) ListOpenAccountCircuits(ctx context.Context, now time.Time) (map[int64]time.Time, error) {
+	nowMilli := strconv.FormatInt(now.UnixMilli(), 10)
+	pipe := c.rdb.Pipeline()
+	pipe.ZRemRangeByScore(ctx, sharedCircuitOpenKey, "-inf", nowMilli)
+	rangeCmd := pipe.ZRangeByScoreWithScores(ctx, sharedCircuitOpenKey, &redis.ZRangeBy{Min: "(" + nowMilli, Max: "+inf"})
+	if _, err := pipe.Exec(ctx); err != nil {
+		return nil, err
+	}
+	out := make(map[int64]time.Time, len(rangeCmd.Val()))
+	for _, z := range rangeCmd.Val() {
+		member, _ := z.Member.(string)
+		accountID, err := strconv.ParseInt(member, 10, 64)
+		if err != nil {
+			continue
+		}
+		out[accountID] = time.UnixMilli(int64(z.Score))
+	}
+	return out, nil
+}
+
+// IncrOpenAIUsageWindow 累加账号用量窗口计数并续期
+func (c *sharedStateCache) IncrOpenAIUsageWindow(ctx context.Context, accountID, slot, tokens int64) error {
+	key := sharedUsageWindowKey(accountID)
+	field := strconv.FormatInt(slot, 10)
+	pipe := c.rdb.TxPipeline()
+	pipe.HIncrBy(ctx, key, field+":r", 1)
+	if tokens > 0 {
+		pipe.HIncrBy(ctx, key, field+":t", tokens)
+	}
+	pipe.Expire(ctx, key, sharedUsageWindowTTL)
+	_, err := pipe.Exec(ctx)
+	return err
+}
+
+// GetOpenAIUsageWindow 读取账号用量窗口，顺带删除早于 fromSlot 的桶
+func (c *sharedStateCache) GetOpenAIUsageWindow(ctx context.Context, accountID, fromSlot int64) ([]service.SharedUsageWindowBucket, error) {
+	key := sharedUsageWindowKey(accountID)
+	raw, err := c.rdb.HGetAll(ctx, key).Result()
+	if err != nil {
+		return nil, err
+	}
+
+	buckets := make(map[int64]*service.SharedUsageWindowBucket)
+	var stale []string
+	for field, value := range raw {
+		slotStr, kind, ok := strings.Cut(field, ":")
+		if !ok {
+			continue
+		}
+		slot, err := strconv.ParseInt(slotStr, 10, 64)
+		if err != nil {
+			continue
+		}
+		if slot < fromSlot {
+			stale = append(stale, field)
+			continue
+		}
+		n, err := strconv.ParseInt(value, 10, 64)
+		if err != nil {
+			continue
+		}
+		b, ok := buckets[slot]
+		if !ok {
+			b = &service.SharedUsageWindowBucket{Slot: slot}
+			buckets[slot] = b
+		}
+		switch kind {
+		case "r":
+			b.Requests = n
+		case "t":
+			b.Tokens = n
+		}
+	}
+	if len(stale) > 0 {
+		_ = c.rdb.HDel(ctx, key, stale...).Err()
+	}
+
+	out := make([]service.SharedUsageWindowBucket, 0, len(buckets))
+	for _, b := range buckets {
+		out = append(out, *b)
+	}
+	return out, nil
+}
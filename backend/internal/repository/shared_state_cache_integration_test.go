@@ -0,0 +1,61 @@
+//go:build integration
+
+package repository
+
+import (
+	"testing"
+	"time"
+
+	"github.com/ShaohongDong/sub2api/internal/service"
+	"github.com/stretchr/testify/require"
+	"github.com/stretchr/testify/suite"
+)
+
+type SharedStateCacheSuite struct {
+	IntegrationRedisSuite
+	cache service.SharedStateCache
+}
+
+func (s *SharedStateCacheSuite) SetupTest() {
+	s.IntegrationRedisSuite.SetupTest()
+	s.cache = NewSharedStateCache(s.rdb)
+}
+
+func (s *SharedStateCacheSuite) TestAccountCircuitOpen_ExtendsAndExpires() {
+	now := time.Now()
+	require.NoError(s.T(), s.cache.SetAccountCircuitOpen(s.ctx, 1, now.Add(time.Minute)))
+	// 更早的到期时间不会缩短已有熔断
+	require.NoError(s.T(), s.cache.SetAccountCircuitOpen(s.ctx, 1, now.Add(10*time.Second)))
+	require.NoError(s.T(), s.cache.SetAccountCircuitOpen(s.ctx, 2, now.Add(-time.Second)))
+
+	open, err := s.cache.ListOpenAccountCircuits(s.ctx, now)
+	require.NoError(s.T(), err)
+	require.Len(s.T(), open, 1)
+	require.WithinDuration(s.T(), now.Add(time.Minute), open[1], time.Millisecond)
+
+	count, err := s.rdb.ZCard(s.ctx, sharedCircuitOpenKey).Result()
+	require.NoError(s.T(), err)
+	require.Equal(s.T(), int64(1), count, "expired circuits should be removed")
+}
+
+func (s *SharedStateCacheSuite) TestOpenAIUsageWindow_IncrAndPrune() {
+	require.NoError(s.T(), s.cache.IncrOpenAIUsageWindow(s.ctx, 7, 100, 50))
+	require.NoError(s.T(), s.cache.IncrOpenAIUsageWindow(s.ctx, 7, 200, 30))
+	require.NoError(s.T(), s.cache.IncrOpenAIUsageWindow(s.ctx, 7, 200, 20))
+
+	buckets, err := s.cache.GetOpenAIUsageWindow(s.ctx, 7, 150)
+	require.NoError(s.T(), err)
+	require.Equal(s.T(), []service.SharedUsageWindowBucket{{Slot: 200, Requests: 2, Tokens: 50}}, buckets)
+
+	exists, err := s.rdb.HExists(s.ctx, sharedUsageWindowKey(7), "100:r").Result()
+	require.NoError(s.T(), err)
+	require.False(s.T(), exists, "buckets before fromSlot should be pruned")
+
+	ttl, err := s.rdb.TTL(s.ctx, sharedUsageWindowKey(7)).Result()
+	require.NoError(s.T(), err)
+	require.Greater(s.T(), ttl, 7*24*time.Hour)
+}
+
+func TestSharedStateCacheSuite(t *testing.T) {
+	suite.Run(t, new(SharedStateCacheSuite))
+}
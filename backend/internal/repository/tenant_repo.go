@@ -0,0 +1,257 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+
+	dbent "github.com/ShaohongDong/sub2api/ent"
+	"github.com/ShaohongDong/sub2api/ent/apikey"
+	"github.com/ShaohongDong/sub2api/ent/group"
+	"github.com/ShaohongDong/sub2api/ent/tenant"
+	dbuser "github.com/ShaohongDong/sub2api/ent/user"
+	"github.com/ShaohongDong/sub2api/internal/pkg/pagination"
+	"github.com/ShaohongDong/sub2api/internal/service"
+	"github.com/lib/pq"
+)
+
+type tenantRepository struct {
+	client *dbent.Client
+	sql    sqlExecutor
+}
+
+func NewTenantRepository(client *dbent.Client, sqlDB *sql.DB) service.TenantRepository {
+	return &tenantRepository{client: client, sql: sqlDB}
+}
+
+func (r *tenantRepository) Create(ctx context.Context, t *service.Tenant) error {
+	client := clientFromContext(ctx, r.client)
+	created, err := client.Tenant.Create().
+		SetName(t.Name).
+		SetDescription(t.Description).
+		SetStatus(t.Status).
+		Save(ctx)
+	if err != nil {
+		return translatePersistenceError(err, nil, service.ErrTenantExists)
+	}
+	t.ID = created.ID
+	t.CreatedAt = created.CreatedAt
+	t.UpdatedAt = created.UpdatedAt
+	return nil
+}
+
+func (r *tenantRepository) GetByID(ctx context.Context, id int64) (*service.Tenant, error) {
+	m, err := clientFromContext(ctx, r.client).Tenant.Get(ctx, id)
+	if err != nil {
+		return nil, translatePersistenceError(err, service.ErrTenantNotFound, nil)
+	}
+	out := []service.Tenant{*tenantEntityToService(m)}
+	if err := r.loadMemberCounts(ctx, out); err != nil {
+		return nil, err
+	}
+	return &out[0], nil
+}
+
+func (r *tenantRepository) Update(ctx context.Context, t *service.Tenant) error {
+	client := clientFromContext(ctx, r.client)
+	updated, err := client.Tenant.UpdateOneID(t.ID).
+		SetName(t.Name).
+		SetDescription(t.Description).
+		SetStatus(t.Status).
+		Save(ctx)
+	if err != nil {
+		return translatePersistenceError(err, service.ErrTenantNotFound, service.ErrTenantExists)
+	}
+	t.UpdatedAt = updated.UpdatedAt
+	return nil
+}
+
+func (r *tenantRepository) Delete(ctx context.Context, id int64) error {
+	client := clientFromContext(ctx, r.client)
+	err := client.Tenant.DeleteOneID(id).Exec(ctx)
+	return translatePersistenceError(err, service.ErrTenantNotFound, nil)
+}
+
+func (r *tenantRepository) List(ctx context.Context, params pagination.PaginationParams, filters service.TenantListFilters) ([]service.Tenant, *pagination.PaginationResult, error) {
+	q := r.client.Tenant.Query()
+	if filters.Status != "" {
+		q = q.Where(tenant.StatusEQ(filters.Status))
+	}
+	if filters.Search != "" {
+		q = q.Where(tenant.Or(
+			tenant.NameContainsFold(filters.Search),
+			tenant.DescriptionContainsFold(filters.Search),
+		))
+	}
+
+	total, err := q.Clone().Count(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	items, err := q.
+		Offset(params.Offset()).
+		Limit(params.Limit()).
+		Order(dbent.Asc(tenant.FieldID)).
+		All(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	out := make([]service.Tenant, 0, len(items))
+	for i := range items {
+		out = append(out, *tenantEntityToService(items[i]))
+	}
+	if err := r.loadMemberCounts(ctx, out); err != nil {
+		return nil, nil, err
+	}
+	return out, paginationResultFromTotal(int64(total), params), nil
+}
+
+func (r *tenantRepository) CountMembers(ctx context.Context, tenantID int64) (int64, int64, int64, error) {
+	client := clientFromContext(ctx, r.client)
+	users, err := client.User.Query().Where(dbuser.TenantIDEQ(tenantID)).Count(ctx)
+	if err != nil {
+		return 0, 0, 0, err
+	}
+	groups, err := client.Group.Query().Where(group.TenantIDEQ(tenantID)).Count(ctx)
+	if err != nil {
+		return 0, 0, 0, err
+	}
+	apiKeys, err := client.APIKey.Query().
+		Where(apikey.HasUserWith(dbuser.TenantIDEQ(tenantID))).
+		Count(ctx)
+	if err != nil {
+		return 0, 0, 0, err
+	}
+	return int64(users), int64(groups), int64(apiKeys), nil
+}
+
+func (r *tenantRepository) AssignUsers(ctx context.Context, tenantID int64, userIDs []int64) (int64, error) {
+	affected, err := clientFromContext(ctx, r.client).User.Update().
+		Where(dbuser.IDIn(userIDs...)).
+		SetTenantID(tenantID).
+		Save(ctx)
+	return int64(affected), err
+}
+
+func (r *tenantRepository) RemoveUsers(ctx context.Context, tenantID int64, userIDs []int64) (int64, error) {
+	affected, err := clientFromContext(ctx, r.client).User.Update().
+		Where(dbuser.IDIn(userIDs...), dbuser.TenantIDEQ(tenantID)).
+		ClearTenantID().
+		Save(ctx)
+	return int64(affected), err
+}
+
+func (r *tenantRepository) AssignGroups(ctx context.Context, tenantID int64, groupIDs []int64) (int64, error) {
+	affected, err := clientFromContext(ctx, r.client).Group.Update().
+		Where(group.IDIn(groupIDs...)).
+		SetTenantID(tenantID).
+		Save(ctx)
+	return int64(affected), err
+}
+
+func (r *tenantRepository) RemoveGroups(ctx context.Context, tenantID int64, groupIDs []int64) (int64, error) {
+	affected, err := clientFromContext(ctx, r.client).Group.Update().
+		Where(group.IDIn(groupIDs...), group.TenantIDEQ(tenantID)).
+		ClearTenantID().
+		Save(ctx)
+	return int64(affected), err
+}
+
+// ListSharedAccountIDs 查找 groupIDs 内同时绑定了 groupIDs 之外、且租户归属不同于 tenantID 的分组的账号
+func (r *tenantRepository) ListSharedAccountIDs(ctx context.Context, tenantID *int64, groupIDs []int64) ([]int64, error) {
+	if len(groupIDs) == 0 {
+		return nil, nil
+	}
+	var target sql.NullInt64
+	if tenantID != nil {
+		target = sql.NullInt64{Int64: *tenantID, Valid: true}
+	}
+	rows, err := r.sql.QueryContext(ctx, `
+		SELECT DISTINCT ag.account_id
+		FROM account_groups ag
+		JOIN accounts a ON a.id = ag.account_id AND a.deleted_at IS NULL
+		JOIN account_groups other ON other.account_id = ag.account_id AND NOT (other.group_id = ANY($1))
+		JOIN groups g ON g.id = other.group_id AND g.deleted_at IS NULL
+		WHERE ag.group_id = ANY($1) AND g.tenant_id IS DISTINCT FROM $2
+		ORDER BY ag.account_id`,
+		pq.Array(groupIDs), target,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = rows.Close() }()
+
+	var accountIDs []int64
+	for rows.Next() {
+		var accountID int64
+		if err := rows.Scan(&accountID); err != nil {
+			return nil, err
+		}
+		accountIDs = append(accountIDs, accountID)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return accountIDs, nil
+}
+
+// loadMemberCounts 批量填充租户的用户数与分组数
+func (r *tenantRepository) loadMemberCounts(ctx context.Context, tenants []service.Tenant) error {
+	if len(tenants) == 0 {
+		return nil
+	}
+	ids := make([]int64, 0, len(tenants))
+	for i := range tenants {
+		ids = append(ids, tenants[i].ID)
+	}
+	userCounts, err := r.countByTenant(ctx, "users", ids)
+	if err != nil {
+		return err
+	}
+	groupCounts, err := r.countByTenant(ctx, "groups", ids)
+	if err != nil {
+		return err
+	}
+	for i := range tenants {
+		tenants[i].UserCount = userCounts[tenants[i].ID]
+		tenants[i].GroupCount = groupCounts[tenants[i].ID]
+	}
+	return nil
+}
+
+// countByTenant 统计指定表（users / groups）中各租户的未删除记录数
+func (r *tenantRepository) countByTenant(ctx context.Context, table string, tenantIDs []int64) (map[int64]int64, error) {
+	rows, err := r.sql.QueryContext(ctx,
+		"SELECT tenant_id, COUNT(*) FROM "+table+" WHERE tenant_id = ANY($1) AND deleted_at IS NULL GROUP BY tenant_id",
+		pq.Array(tenantIDs),
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = rows.Close() }()
+
+	out := make(map[int64]int64, len(tenantIDs))
+	for rows.Next() {
+		var tenantID, count int64
+		if err := rows.Scan(&tenantID, &count); err != nil {
+			return nil, err
+		}
+		out[tenantID] = count
+	}
+	return out, rows.Err()
+}
+
+func tenantEntityToService(m *dbent.Tenant) *service.Tenant {
+	if m == nil {
+		return nil
+	}
+	return &service.Tenant{
+		ID:          m.ID,
+		Name:        m.Name,
+		Description: m.Description,
+		Status:      m.Status,
+		CreatedAt:   m.CreatedAt,
+		UpdatedAt:   m.UpdatedAt,
+	}
+}
@@ -22,7 +22,7 @@ import (
 	"github.com/lib/pq"
 )
 
-const usageLogSelectColumns = "id, user_id, api_key_id, account_id, request_id, model, group_id, subscription_id, input_tokens, output_tokens, cache_creation_tokens, cache_read_tokens, cache_creation_5m_tokens, cache_creation_1h_tokens, input_cost, output_cost, cache_creation_cost, cache_read_cost, total_cost, actual_cost, rate_multiplier, account_rate_multiplier, billing_type, request_type, stream, openai_ws_mode, duration_ms, first_token_ms, user_agent, ip_address, image_count, image_size, media_type, reasoning_effort, cache_ttl_overridden, created_at"
+const usageLogSelectColumns = "id, user_id, api_key_id, account_id, request_id, model, group_id, subscription_id, input_tokens, output_tokens, cache_creation_tokens, cache_read_tokens, cache_creation_5m_tokens, cache_creation_1h_tokens, input_cost, output_cost, cache_creation_cost, cache_read_cost, total_cost, actual_cost, rate_multiplier, account_rate_multiplier, billing_type, request_type, stream, openai_ws_mode, duration_ms, first_token_ms, user_agent, ip_address, image_count, image_size, media_type, reasoning_effort, cache_ttl_overridden, created_at, reasoning_tokens, gateway_request_id, client_type"
 
 // dateFormatWhitelist 将 granularity 参数映射为 PostgreSQL TO_CHAR 格式字符串，防止外部输入直接拼入 SQL
 var dateFormatWhitelist = map[string]string{
@@ -137,14 +137,17 @@ func (r *usageLogRepository) Create(ctx context.Context, log *service.UsageLog)
 			media_type,
 			reasoning_effort,
 			cache_ttl_overridden,
-			created_at
+			created_at,
+			reasoning_tokens,
+			gateway_request_id,
+			client_type
 		) VALUES (
 			$1, $2, $3, $4, $5,
 			$6, $7,
 			$8, $9, $10, $11,
 			$12, $13,
 			$14, $15, $16, $17, $18, $19,
-			$20, $21, $22, $23, $24, $25, $26, $27, $28, $29, $30, $31, $32, $33, $34, $35
+			$20, $21, $22, $23, $24, $25, $26, $27, $28, $29, $30, $31, $32, $33, $34, $35, $36, $37, $38
 		)
 		ON CONFLICT (request_id, api_key_id) DO NOTHING
 		RETURNING id, created_at
@@ -159,6 +162,8 @@ func (r *usageLogRepository) Create(ctx context.Context, log *service.UsageLog)
 	imageSize := nullString(log.ImageSize)
 	mediaType := nullString(log.MediaType)
 	reasoningEffort := nullString(log.ReasoningEffort)
+	gatewayRequestID := sql.NullString{String: log.GatewayRequestID, Valid: log.GatewayRequestID != ""}
+	clientType := sql.NullString{String: log.ClientType, Valid: log.ClientType != ""}
 
 	var requestIDArg any
 	if requestID != "" {
@@ -201,6 +206,9 @@ func (r *usageLogRepository) Create(ctx context.Context, log *service.UsageLog)
 		reasoningEffort,
 		log.CacheTTLOverridden,
 		createdAt,
+		log.ReasoningTokens,
+		gatewayRequestID,
+		clientType,
 	}
 	if err := scanSingleRow(ctx, sqlq, query, args, &log.ID, &log.CreatedAt); err != nil {
 		if errors.Is(err, sql.ErrNoRows) && requestID != "" {
@@ -1457,6 +1465,10 @@ func (r *usageLogRepository) ListWithFilters(ctx context.Context, params paginat
 		conditions = append(conditions, fmt.Sprintf("group_id = $%d", len(args)+1))
 		args = append(args, filters.GroupID)
 	}
+	if filters.TenantID > 0 {
+		conditions = append(conditions, fmt.Sprintf("user_id IN (SELECT id FROM users WHERE tenant_id = $%d)", len(args)+1))
+		args = append(args, filters.TenantID)
+	}
 	if filters.Model != "" {
 		conditions = append(conditions, fmt.Sprintf("model = $%d", len(args)+1))
 		args = append(args, filters.Model)
@@ -1936,6 +1948,357 @@ func (r *usageLogRepository) GetGroupStatsWithFilters(ctx context.Context, start
 	return results, nil
 }
 
+// UsageAggregate 按任意维度组合聚合用量
+type UsageAggregate = usagestats.UsageAggregate
+
+// usageAggregateDimension 描述一个分组维度对应的 SELECT 列、GROUP BY 表达式与关联表
+type usageAggregateDimension struct {
+	selects []string
+	groupBy []string
+	join    string
+	scan    func(row *UsageAggregate) []any
+}
+
+// usageAggregateDimensions 维度白名单，防止外部输入直接拼入 SQL
+var usageAggregateDimensions = map[string]usageAggregateDimension{
+	usagestats.UsageAggregateByAPIKey: {
+		selects: []string{"ul.api_key_id", "COALESCE(k.name, '')"},
+		groupBy: []string{"ul.api_key_id", "k.name"},
+		join:    "LEFT JOIN api_keys k ON k.id = ul.api_key_id",
+		scan:    func(row *UsageAggregate) []any { return []any{&row.APIKeyID, &row.APIKeyName} },
+	},
+	usagestats.UsageAggregateByModel: {
+		selects: []string{"ul.model"},
+		groupBy: []string{"ul.model"},
+		scan:    func(row *UsageAggregate) []any { return []any{&row.Model} },
+	},
+	usagestats.UsageAggregateByAccount: {
+		selects: []string{"ul.account_id", "COALESCE(a.name, '')"},
+		groupBy: []string{"ul.account_id", "a.name"},
+		join:    "LEFT JOIN accounts a ON a.id = ul.account_id",
+		scan:    func(row *UsageAggregate) []any { return []any{&row.AccountID, &row.AccountName} },
+	},
+	usagestats.UsageAggregateByUser: {
+		selects: []string{"ul.user_id", "COALESCE(u.email, '')"},
+		groupBy: []string{"ul.user_id", "u.email"},
+		join:    "LEFT JOIN users u ON u.id = ul.user_id",
+		scan:    func(row *UsageAggregate) []any { return []any{&row.UserID, &row.UserEmail} },
+	},
+	usagestats.UsageAggregateByGroup: {
+		selects: []string{"COALESCE(ul.group_id, 0)", "COALESCE(g.name, '')"},
+		groupBy: []string{"COALESCE(ul.group_id, 0)", "g.name"},
+		join:    "LEFT JOIN groups g ON g.id = ul.group_id",
+		scan:    func(row *UsageAggregate) []any { return []any{&row.GroupID, &row.GroupName} },
+	},
+}
+
+// GetUsageAggregates 按维度组合与时间桶聚合用量。
+// 维度须已由调用方校验（未知维度会被忽略）；有时间桶时按时间升序、桶内按 token 数降序。
+func (r *usageLogRepository) GetUsageAggregates(ctx context.Context, q usagestats.UsageAggregateQuery) ([]UsageAggregate, error) {
+	results := make([]UsageAggregate, 0)
+	err := r.ForEachUsageAggregate(ctx, q, func(row *UsageAggregate) error {
+		results = append(results, *row)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return results, nil
+}
+
+// ForEachUsageAggregate 与 GetUsageAggregates 相同的聚合查询，但逐行回调而不在内存中汇总结果，供大数据量导出使用。
+// fn 返回错误时停止遍历并返回该错误。
+func (r *usageLogRepository) ForEachUsageAggregate(ctx context.Context, q usagestats.UsageAggregateQuery, fn func(*UsageAggregate) error) (err error) {
+	var (
+		selects []string
+		groupBy []string
+		joins   []string
+		scans   []func(row *UsageAggregate) []any
+	)
+	if q.Granularity != "" {
+		bucketExpr := fmt.Sprintf("TO_CHAR(ul.created_at, '%s')", safeDateFormat(q.Granularity))
+		selects = append(selects, bucketExpr)
+		groupBy = append(groupBy, bucketExpr)
+		scans = append(scans, func(row *UsageAggregate) []any { return []any{&row.Bucket} })
+	}
+	for _, name := range q.GroupBy {
+		dim, ok := usageAggregateDimensions[name]
+		if !ok {
+			continue
+		}
+		selects = append(selects, dim.selects...)
+		groupBy = append(groupBy, dim.groupBy...)
+		if dim.join != "" {
+			joins = append(joins, dim.join)
+		}
+		scans = append(scans, dim.scan)
+	}
+	selects = append(selects,
+		"COUNT(*) as requests",
+		"COALESCE(SUM(ul.input_tokens), 0) as input_tokens",
+		"COALESCE(SUM(ul.output_tokens), 0) as output_tokens",
+		"COALESCE(SUM(ul.reasoning_tokens), 0) as reasoning_tokens",
+		"COALESCE(SUM(ul.cache_creation_tokens), 0) as cache_creation_tokens",
+		"COALESCE(SUM(ul.cache_read_tokens), 0) as cache_read_tokens",
+		"COALESCE(SUM(ul.input_tokens + ul.output_tokens + ul.cache_creation_tokens + ul.cache_read_tokens), 0) as total_tokens",
+		"COALESCE(SUM(ul.total_cost), 0) as cost",
+		"COALESCE(SUM(ul.actual_cost), 0) as actual_cost",
+		"COALESCE(SUM(ul.total_cost * COALESCE(ul.account_rate_multiplier, 1)), 0) as account_cost",
+	)
+
+	query := "SELECT " + strings.Join(selects, ", ") + " FROM usage_logs ul"
+	for _, join := range joins {
+		query += " " + join
+	}
+	where, args := buildUsageAggregateWhere(q)
+	query += where
+	if len(groupBy) > 0 {
+		query += " GROUP BY " + strings.Join(groupBy, ", ")
+	}
+	if q.Granularity != "" {
+		query += " ORDER BY 1 ASC, total_tokens DESC"
+	} else {
+		query += " ORDER BY total_tokens DESC"
+	}
+	if q.Limit > 0 {
+		query += fmt.Sprintf(" LIMIT $%d", len(args)+1)
+		args = append(args, q.Limit)
+	}
+
+	rows, err := r.sql.QueryContext(ctx, query, args...)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		// 保持主错误优先；仅在无错误时回传 Close 失败。
+		if closeErr := rows.Close(); closeErr != nil && err == nil {
+			err = closeErr
+		}
+	}()
+
+	for rows.Next() {
+		var row UsageAggregate
+		dest := make([]any, 0, len(selects))
+		for _, scan := range scans {
+			dest = append(dest, scan(&row)...)
+		}
+		dest = append(dest,
+			&row.Requests,
+			&row.InputTokens,
+			&row.OutputTokens,
+			&row.ReasoningTokens,
+			&row.CacheCreationTokens,
+			&row.CacheReadTokens,
+			&row.TotalTokens,
+			&row.Cost,
+			&row.ActualCost,
+			&row.AccountCost,
+		)
+		if err = rows.Scan(dest...); err != nil {
+			return err
+		}
+		if err = fn(&row); err != nil {
+			return err
+		}
+	}
+	return rows.Err()
+}
+
+// ForEachUsageExportRow 按时间升序逐条回调时间范围内的请求明细（含用户/Key/账号/分组名称），
+// 结果不在内存中汇总，供分摊报表流式导出。过滤条件与 ForEachUsageAggregate 一致，忽略 GroupBy/Granularity/Limit。
+func (r *usageLogRepository) ForEachUsageExportRow(ctx context.Context, q usagestats.UsageAggregateQuery, fn func(*usagestats.UsageExportRow) error) (err error) {
+	query := `SELECT ul.id, ul.created_at, COALESCE(ul.request_id, ''),
+			ul.user_id, COALESCE(u.email, ''), ul.api_key_id, COALESCE(k.name, ''),
+			ul.account_id, COALESCE(a.name, ''), COALESCE(ul.group_id, 0), COALESCE(g.name, ''),
+			ul.model, ul.stream,
+			ul.input_tokens, ul.output_tokens, COALESCE(ul.reasoning_tokens, 0), ul.cache_creation_tokens, ul.cache_read_tokens,
+			ul.input_tokens + ul.output_tokens + ul.cache_creation_tokens + ul.cache_read_tokens,
+			ul.total_cost, ul.actual_cost, ul.total_cost * COALESCE(ul.account_rate_multiplier, 1),
+			COALESCE(ul.duration_ms, 0)
+		FROM usage_logs ul
+		LEFT JOIN users u ON u.id = ul.user_id
+		LEFT JOIN api_keys k ON k.id = ul.api_key_id
+		LEFT JOIN accounts a ON a.id = ul.account_id
+		LEFT JOIN groups g ON g.id = ul.group_id`
+	where, args := buildUsageAggregateWhere(q)
+	query += where + " ORDER BY ul.created_at ASC, ul.id ASC"
+
+	rows, err := r.sql.QueryContext(ctx, query, args...)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if closeErr := rows.Close(); closeErr != nil && err == nil {
+			err = closeErr
+		}
+	}()
+
+	for rows.Next() {
+		var row usagestats.UsageExportRow
+		if err = rows.Scan(
+			&row.ID, &row.CreatedAt, &row.RequestID,
+			&row.UserID, &row.UserEmail, &row.APIKeyID, &row.APIKeyName,
+			&row.AccountID, &row.AccountName, &row.GroupID, &row.GroupName,
+			&row.Model, &row.Stream,
+			&row.InputTokens, &row.OutputTokens, &row.ReasoningTokens, &row.CacheCreationTokens, &row.CacheReadTokens,
+			&row.TotalTokens,
+			&row.Cost, &row.ActualCost, &row.AccountCost,
+			&row.DurationMs,
+		); err != nil {
+			return err
+		}
+		if err = fn(&row); err != nil {
+			return err
+		}
+	}
+	return rows.Err()
+}
+
+// buildUsageAggregateWhere 生成聚合/导出查询共用的时间范围与过滤条件（参数从 $1 开始）
+func buildUsageAggregateWhere(q usagestats.UsageAggregateQuery) (string, []any) {
+	where := " WHERE ul.created_at >= $1 AND ul.created_at < $2"
+	args := []any{q.StartTime, q.EndTime}
+	if q.UserID > 0 {
+		where += fmt.Sprintf(" AND ul.user_id = $%d", len(args)+1)
+		args = append(args, q.UserID)
+	}
+	if q.APIKeyID > 0 {
+		where += fmt.Sprintf(" AND ul.api_key_id = $%d", len(args)+1)
+		args = append(args, q.APIKeyID)
+	}
+	if q.AccountID > 0 {
+		where += fmt.Sprintf(" AND ul.account_id = $%d", len(args)+1)
+		args = append(args, q.AccountID)
+	}
+	if q.GroupID > 0 {
+		where += fmt.Sprintf(" AND ul.group_id = $%d", len(args)+1)
+		args = append(args, q.GroupID)
+	}
+	if q.Model != "" {
+		where += fmt.Sprintf(" AND ul.model = $%d", len(args)+1)
+		args = append(args, q.Model)
+	}
+	return where, args
+}
+
+// GetClientTypeUsage 按请求处理时记录的 client_type 聚合时间范围内的成功用量（usage_logs）与失败请求数（ops_error_logs）。
+// 新增 client_type 列之前的历史行该列为空，按原始 User-Agent 分组返回，由调用方回退解析。
+// 失败请求口径与运维看板 error_sla 一致：status_code >= 400 且非业务限流。
+func (r *usageLogRepository) GetClientTypeUsage(ctx context.Context, startTime, endTime time.Time) (results []usagestats.ClientTypeUsageRow, err error) {
+	usageQuery := `
+		SELECT
+			COALESCE(client_type, '') as client_type,
+			CASE WHEN client_type IS NULL THEN COALESCE(user_agent, '') ELSE '' END as user_agent,
+			COUNT(*) as requests,
+			COALESCE(SUM(input_tokens), 0) as input_tokens,
+			COALESCE(SUM(output_tokens), 0) as output_tokens,
+			COALESCE(SUM(cache_creation_tokens), 0) as cache_creation_tokens,
+			COALESCE(SUM(cache_read_tokens), 0) as cache_read_tokens,
+			COALESCE(SUM(input_tokens + output_tokens + cache_creation_tokens + cache_read_tokens), 0) as total_tokens,
+			COALESCE(SUM(total_cost), 0) as cost,
+			COALESCE(SUM(actual_cost), 0) as actual_cost
+		FROM usage_logs
+		WHERE created_at >= $1 AND created_at < $2
+		GROUP BY 1, 2
+	`
+	rows, err := r.sql.QueryContext(ctx, usageQuery, startTime, endTime)
+	if err != nil {
+		return nil, err
+	}
+	type rowKey struct{ clientType, userAgent string }
+	byKey := make(map[rowKey]*usagestats.ClientTypeUsageRow)
+	order := make([]rowKey, 0)
+	for rows.Next() {
+		var row usagestats.ClientTypeUsageRow
+		if err := rows.Scan(
+			&row.ClientType,
+			&row.UserAgent,
+			&row.Requests,
+			&row.InputTokens,
+			&row.OutputTokens,
+			&row.CacheCreationTokens,
+			&row.CacheReadTokens,
+			&row.TotalTokens,
+			&row.Cost,
+			&row.ActualCost,
+		); err != nil {
+			_ = rows.Close()
+			return nil, err
+		}
+		key := rowKey{row.ClientType, row.UserAgent}
+		byKey[key] = &row
+		order = append(order, key)
+	}
+	if err := rows.Err(); err != nil {
+		_ = rows.Close()
+		return nil, err
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+
+	errorQuery := `
+		SELECT
+			COALESCE(client_type, '') as client_type,
+			CASE WHEN client_type IS NULL THEN COALESCE(user_agent, '') ELSE '' END as user_agent,
+			COUNT(*) as errors
+		FROM ops_error_logs
+		WHERE created_at >= $1 AND created_at < $2
+			AND COALESCE(status_code, 0) >= 400
+			AND NOT is_business_limited
+		GROUP BY 1, 2
+	`
+	errRows, err := r.sql.QueryContext(ctx, errorQuery, startTime, endTime)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		if closeErr := errRows.Close(); closeErr != nil && err == nil {
+			err = closeErr
+			results = nil
+		}
+	}()
+	for errRows.Next() {
+		var key rowKey
+		var errCount int64
+		if err := errRows.Scan(&key.clientType, &key.userAgent, &errCount); err != nil {
+			return nil, err
+		}
+		row, ok := byKey[key]
+		if !ok {
+			row = &usagestats.ClientTypeUsageRow{ClientType: key.clientType, UserAgent: key.userAgent}
+			byKey[key] = row
+			order = append(order, key)
+		}
+		row.Errors = errCount
+	}
+	if err := errRows.Err(); err != nil {
+		return nil, err
+	}
+
+	results = make([]usagestats.ClientTypeUsageRow, 0, len(order))
+	for _, key := range order {
+		results = append(results, *byKey[key])
+	}
+	return results, nil
+}
+
+// CountErrorRequests 统计时间范围内的失败请求数，口径与 GetClientTypeUsage 一致（status_code >= 400 且非业务限流）。
+func (r *usageLogRepository) CountErrorRequests(ctx context.Context, startTime, endTime time.Time) (int64, error) {
+	query := `
+		SELECT COUNT(*)
+		FROM ops_error_logs
+		WHERE created_at >= $1 AND created_at < $2
+			AND COALESCE(status_code, 0) >= 400
+			AND NOT is_business_limited
+	`
+	var count int64
+	if err := scanSingleRow(ctx, r.sql, query, []any{startTime, endTime}, &count); err != nil {
+		return 0, err
+	}
+	return count, nil
+}
+
 // GetGlobalStats gets usage statistics for all users within a time range
 func (r *usageLogRepository) GetGlobalStats(ctx context.Context, startTime, endTime time.Time) (*UsageStats, error) {
 	query := `
@@ -1992,6 +2355,10 @@ func (r *usageLogRepository) GetStatsWithFilters(ctx context.Context, filters Us
 		conditions = append(conditions, fmt.Sprintf("group_id = $%d", len(args)+1))
 		args = append(args, filters.GroupID)
 	}
+	if filters.TenantID > 0 {
+		conditions = append(conditions, fmt.Sprintf("user_id IN (SELECT id FROM users WHERE tenant_id = $%d)", len(args)+1))
+		args = append(args, filters.TenantID)
+	}
 	if filters.Model != "" {
 		conditions = append(conditions, fmt.Sprintf("model = $%d", len(args)+1))
 		args = append(args, filters.Model)
@@ -2229,6 +2596,94 @@ func (r *usageLogRepository) GetAccountUsageStats(ctx context.Context, accountID
 	return resp, nil
 }
 
+// GetAccountUsageBuckets 按小时或按天聚合单个账号的用量与错误数。
+// 成功请求来自 usage_logs，失败请求来自 ops_error_logs（排除业务限流），两侧按时间桶 FULL JOIN，
+// 只有错误没有成功请求的时间桶同样会返回。
+func (r *usageLogRepository) GetAccountUsageBuckets(ctx context.Context, accountID int64, startTime, endTime time.Time, granularity string) (buckets []usagestats.AccountUsageBucket, err error) {
+	dateFormat := safeDateFormat(granularity)
+	query := fmt.Sprintf(`
+		WITH usage_buckets AS (
+			SELECT
+				TO_CHAR(created_at, '%[1]s') AS bucket,
+				COUNT(*) AS requests,
+				COALESCE(SUM(input_tokens), 0) AS input_tokens,
+				COALESCE(SUM(output_tokens), 0) AS output_tokens,
+				COALESCE(SUM(reasoning_tokens), 0) AS reasoning_tokens,
+				COALESCE(SUM(cache_creation_tokens), 0) AS cache_creation_tokens,
+				COALESCE(SUM(cache_read_tokens), 0) AS cache_read_tokens,
+				COALESCE(SUM(total_cost * COALESCE(account_rate_multiplier, 1)), 0) AS cost,
+				MAX(created_at) AS last_used_at
+			FROM usage_logs
+			WHERE account_id = $1 AND created_at >= $2 AND created_at < $3
+			GROUP BY bucket
+		),
+		error_buckets AS (
+			SELECT
+				TO_CHAR(created_at, '%[1]s') AS bucket,
+				COUNT(*) AS errors
+			FROM ops_error_logs
+			WHERE account_id = $1 AND created_at >= $2 AND created_at < $3
+				AND COALESCE(status_code, 0) >= 400
+				AND NOT COALESCE(is_business_limited, false)
+			GROUP BY bucket
+		)
+		SELECT
+			COALESCE(u.bucket, e.bucket) AS bucket,
+			COALESCE(u.requests, 0),
+			COALESCE(e.errors, 0),
+			COALESCE(u.input_tokens, 0),
+			COALESCE(u.output_tokens, 0),
+			COALESCE(u.reasoning_tokens, 0),
+			COALESCE(u.cache_creation_tokens, 0),
+			COALESCE(u.cache_read_tokens, 0),
+			COALESCE(u.cost, 0),
+			u.last_used_at
+		FROM usage_buckets u
+		FULL OUTER JOIN error_buckets e ON e.bucket = u.bucket
+		ORDER BY bucket ASC
+	`, dateFormat)
+
+	rows, err := r.sql.QueryContext(ctx, query, accountID, startTime, endTime)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		if closeErr := rows.Close(); closeErr != nil && err == nil {
+			err = closeErr
+			buckets = nil
+		}
+	}()
+
+	buckets = make([]usagestats.AccountUsageBucket, 0)
+	for rows.Next() {
+		var b usagestats.AccountUsageBucket
+		var lastUsedAt sql.NullTime
+		if err = rows.Scan(
+			&b.Bucket,
+			&b.Requests,
+			&b.Errors,
+			&b.InputTokens,
+			&b.OutputTokens,
+			&b.ReasoningTokens,
+			&b.CacheCreationTokens,
+			&b.CacheReadTokens,
+			&b.Cost,
+			&lastUsedAt,
+		); err != nil {
+			return nil, err
+		}
+		if lastUsedAt.Valid {
+			t := lastUsedAt.Time
+			b.LastUsedAt = &t
+		}
+		buckets = append(buckets, b)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+	return buckets, nil
+}
+
 func (r *usageLogRepository) listUsageLogsWithPagination(ctx context.Context, whereClause string, args []any, params pagination.PaginationParams) ([]service.UsageLog, *pagination.PaginationResult, error) {
 	countQuery := "SELECT COUNT(*) FROM usage_logs " + whereClause
 	var total int64
@@ -2508,6 +2963,9 @@ func scanUsageLog(scanner interface{ Scan(...any) error }) (*service.UsageLog, e
 		reasoningEffort       sql.NullString
 		cacheTTLOverridden    bool
 		createdAt             time.Time
+		reasoningTokens       int
+		gatewayRequestID      sql.NullString
+		clientType            sql.NullString
 	)
 
 	if err := scanner.Scan(
@@ -2547,6 +3005,9 @@ func scanUsageLog(scanner interface{ Scan(...any) error }) (*service.UsageLog, e
 		&reasoningEffort,
 		&cacheTTLOverridden,
 		&createdAt,
+		&reasoningTokens,
+		&gatewayRequestID,
+		&clientType,
 	); err != nil {
 		return nil, err
 	}
@@ -2563,6 +3024,7 @@ func scanUsageLog(scanner interface{ Scan(...any) error }) (*service.UsageLog, e
 		CacheReadTokens:       cacheReadTokens,
 		CacheCreation5mTokens: cacheCreation5m,
 		CacheCreation1hTokens: cacheCreation1h,
+		ReasoningTokens:       reasoningTokens,
 		InputCost:             inputCost,
 		OutputCost:            outputCost,
 		CacheCreationCost:     cacheCreationCost,
@@ -2586,6 +3048,12 @@ func scanUsageLog(scanner interface{ Scan(...any) error }) (*service.UsageLog, e
 	if requestID.Valid {
 		log.RequestID = requestID.String
 	}
+	if gatewayRequestID.Valid {
+		log.GatewayRequestID = gatewayRequestID.String
+	}
+	if clientType.Valid {
+		log.ClientType = clientType.String
+	}
 	if groupID.Valid {
 		value := groupID.Int64
 		log.GroupID = &value
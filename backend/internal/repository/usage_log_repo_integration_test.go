@@ -1152,6 +1152,46 @@ func (s *UsageLogRepoSuite) TestGetAccountUsageStats_EmptyRange() {
 	s.Require().Equal(int64(0), resp.Summary.TotalRequests)
 }
 
+// --- GetAccountUsageBuckets ---
+
+func (s *UsageLogRepoSuite) TestGetAccountUsageBuckets() {
+	user := mustCreateUser(s.T(), s.client, &service.User{Email: "accbuckets@test.com"})
+	apiKey := mustCreateApiKey(s.T(), s.client, &service.APIKey{UserID: user.ID, Key: "sk-accbuckets", Name: "k"})
+	account := mustCreateAccount(s.T(), s.client, &service.Account{Name: "acc-accbuckets"})
+
+	base := time.Date(2025, 1, 15, 0, 0, 0, 0, time.UTC)
+	for _, offset := range []time.Duration{10 * time.Minute, 20 * time.Minute, 2 * time.Hour} {
+		_, err := s.repo.Create(s.ctx, &service.UsageLog{
+			UserID:          user.ID,
+			APIKeyID:        apiKey.ID,
+			AccountID:       account.ID,
+			Model:           "gpt-5",
+			InputTokens:     100,
+			OutputTokens:    50,
+			ReasoningTokens: 30,
+			TotalCost:       0.1,
+			ActualCost:      0.1,
+			CreatedAt:       base.Add(offset),
+		})
+		s.Require().NoError(err)
+	}
+
+	buckets, err := s.repo.GetAccountUsageBuckets(s.ctx, account.ID, base, base.Add(24*time.Hour), "hour")
+	s.Require().NoError(err, "GetAccountUsageBuckets")
+	s.Require().Len(buckets, 2, "expected 2 hourly buckets")
+	s.Require().Equal(int64(2), buckets[0].Requests)
+	s.Require().Equal(int64(60), buckets[0].ReasoningTokens)
+	s.Require().Equal(int64(100), buckets[0].OutputTokens)
+	s.Require().NotNil(buckets[0].LastUsedAt)
+	s.Require().Equal(int64(1), buckets[1].Requests)
+
+	buckets, err = s.repo.GetAccountUsageBuckets(s.ctx, account.ID, base, base.Add(24*time.Hour), "day")
+	s.Require().NoError(err, "GetAccountUsageBuckets day")
+	s.Require().Len(buckets, 1)
+	s.Require().Equal(int64(3), buckets[0].Requests)
+	s.Require().Equal(int64(300), buckets[0].InputTokens)
+}
+
 // --- GetUserUsageTrend ---
 
 func (s *UsageLogRepoSuite) TestGetUserUsageTrend() {
@@ -3,6 +3,7 @@ package repository
 import (
 	"context"
 	"database/sql"
+	"errors"
 	"fmt"
 	"reflect"
 	"testing"
@@ -21,20 +22,22 @@ func TestUsageLogRepositoryCreateSyncRequestTypeAndLegacyFields(t *testing.T) {
 
 	createdAt := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
 	log := &service.UsageLog{
-		UserID:       1,
-		APIKeyID:     2,
-		AccountID:    3,
-		RequestID:    "req-1",
-		Model:        "gpt-5",
-		InputTokens:  10,
-		OutputTokens: 20,
-		TotalCost:    1,
-		ActualCost:   1,
-		BillingType:  service.BillingTypeBalance,
-		RequestType:  service.RequestTypeWSV2,
-		Stream:       false,
-		OpenAIWSMode: false,
-		CreatedAt:    createdAt,
+		UserID:           1,
+		APIKeyID:         2,
+		AccountID:        3,
+		RequestID:        "req-1",
+		Model:            "gpt-5",
+		InputTokens:      10,
+		OutputTokens:     20,
+		TotalCost:        1,
+		ActualCost:       1,
+		BillingType:      service.BillingTypeBalance,
+		RequestType:      service.RequestTypeWSV2,
+		Stream:           false,
+		OpenAIWSMode:     false,
+		CreatedAt:        createdAt,
+		GatewayRequestID: "gw-req-1",
+		ClientType:       "codex_cli",
 	}
 
 	mock.ExpectQuery("INSERT INTO usage_logs").
@@ -74,6 +77,9 @@ func TestUsageLogRepositoryCreateSyncRequestTypeAndLegacyFields(t *testing.T) {
 			sqlmock.AnyArg(), // reasoning_effort
 			log.CacheTTLOverridden,
 			createdAt,
+			log.ReasoningTokens,
+			sql.NullString{String: "gw-req-1", Valid: true},  // gateway_request_id
+			sql.NullString{String: "codex_cli", Valid: true}, // client_type
 		).
 		WillReturnRows(sqlmock.NewRows([]string{"id", "created_at"}).AddRow(int64(99), createdAt))
 
@@ -283,6 +289,9 @@ func TestScanUsageLogRequestTypeAndLegacyFallback(t *testing.T) {
 			sql.NullString{},
 			false,
 			now,
+			0,                // reasoning_tokens
+			sql.NullString{}, // gateway_request_id
+			sql.NullString{}, // client_type
 		}})
 		require.NoError(t, err)
 		require.Equal(t, service.RequestTypeWSV2, log.RequestType)
@@ -319,6 +328,9 @@ func TestScanUsageLogRequestTypeAndLegacyFallback(t *testing.T) {
 			sql.NullString{},
 			false,
 			now,
+			0,                // reasoning_tokens
+			sql.NullString{}, // gateway_request_id
+			sql.NullString{}, // client_type
 		}})
 		require.NoError(t, err)
 		require.Equal(t, service.RequestTypeStream, log.RequestType)
@@ -326,3 +338,115 @@ func TestScanUsageLogRequestTypeAndLegacyFallback(t *testing.T) {
 		require.False(t, log.OpenAIWSMode)
 	})
 }
+
+func TestUsageLogRepositoryGetUsageAggregatesBuildsGroupedQuery(t *testing.T) {
+	db, mock := newSQLMock(t)
+	repo := &usageLogRepository{sql: db}
+
+	start := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	end := time.Date(2026, 2, 1, 0, 0, 0, 0, time.UTC)
+	mock.ExpectQuery(`SELECT TO_CHAR\(ul\.created_at, 'YYYY-MM-DD'\), ul\.api_key_id, COALESCE\(k\.name, ''\), ul\.model, COUNT\(\*\).*FROM usage_logs ul LEFT JOIN api_keys k ON k\.id = ul\.api_key_id WHERE ul\.created_at >= \$1 AND ul\.created_at < \$2 AND ul\.account_id = \$3 GROUP BY TO_CHAR\(ul\.created_at, 'YYYY-MM-DD'\), ul\.api_key_id, k\.name, ul\.model ORDER BY 1 ASC, total_tokens DESC LIMIT \$4`).
+		WithArgs(start, end, int64(9), 100).
+		WillReturnRows(sqlmock.NewRows([]string{
+			"bucket", "api_key_id", "key_name", "model",
+			"requests", "input_tokens", "output_tokens", "reasoning_tokens", "cache_creation_tokens", "cache_read_tokens", "total_tokens",
+			"cost", "actual_cost", "account_cost",
+		}).AddRow("2026-01-02", int64(3), "team-a", "gpt-5", int64(2), int64(10), int64(20), int64(7), int64(0), int64(5), int64(35), 1.5, 1.2, 0.9))
+
+	rows, err := repo.GetUsageAggregates(context.Background(), usagestats.UsageAggregateQuery{
+		StartTime:   start,
+		EndTime:     end,
+		GroupBy:     []string{usagestats.UsageAggregateByAPIKey, usagestats.UsageAggregateByModel},
+		Granularity: "day",
+		AccountID:   9,
+		Limit:       100,
+	})
+	require.NoError(t, err)
+	require.Equal(t, []usagestats.UsageAggregate{{
+		Bucket: "2026-01-02", APIKeyID: 3, APIKeyName: "team-a", Model: "gpt-5",
+		Requests: 2, InputTokens: 10, OutputTokens: 20, ReasoningTokens: 7, CacheReadTokens: 5, TotalTokens: 35,
+		Cost: 1.5, ActualCost: 1.2, AccountCost: 0.9,
+	}}, rows)
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestUsageLogRepositoryForEachUsageExportRowStreamsDetail(t *testing.T) {
+	db, mock := newSQLMock(t)
+	repo := &usageLogRepository{sql: db}
+
+	start := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	end := time.Date(2026, 2, 1, 0, 0, 0, 0, time.UTC)
+	createdAt := start.Add(time.Hour)
+	mock.ExpectQuery(`SELECT ul\.id, ul\.created_at, COALESCE\(ul\.request_id, ''\).*FROM usage_logs ul\s+LEFT JOIN users u ON u\.id = ul\.user_id.*WHERE ul\.created_at >= \$1 AND ul\.created_at < \$2 AND ul\.group_id = \$3 AND ul\.model = \$4 ORDER BY ul\.created_at ASC, ul\.id ASC$`).
+		WithArgs(start, end, int64(4), "gpt-5").
+		WillReturnRows(sqlmock.NewRows([]string{
+			"id", "created_at", "request_id", "user_id", "email", "api_key_id", "key_name", "account_id", "account_name", "group_id", "group_name",
+			"model", "stream", "input_tokens", "output_tokens", "reasoning_tokens", "cache_creation_tokens", "cache_read_tokens", "total_tokens",
+			"cost", "actual_cost", "account_cost", "duration_ms",
+		}).
+			AddRow(int64(1), createdAt, "req-1", int64(2), "a@example.com", int64(3), "team-a", int64(5), "acc", int64(4), "vip",
+				"gpt-5", true, int64(10), int64(20), int64(7), int64(0), int64(5), int64(35), 1.5, 1.2, 0.9, int64(800)).
+			AddRow(int64(2), createdAt, "req-2", int64(2), "a@example.com", int64(3), "team-a", int64(5), "acc", int64(4), "vip",
+				"gpt-5", false, int64(1), int64(1), int64(0), int64(0), int64(0), int64(2), 0.1, 0.1, 0.1, int64(0)))
+
+	var ids []int64
+	stop := errors.New("stop")
+	err := repo.ForEachUsageExportRow(context.Background(), usagestats.UsageAggregateQuery{
+		StartTime: start,
+		EndTime:   end,
+		GroupID:   4,
+		Model:     "gpt-5",
+	}, func(row *usagestats.UsageExportRow) error {
+		ids = append(ids, row.ID)
+		require.Equal(t, "team-a", row.APIKeyName)
+		require.Equal(t, int64(35), row.TotalTokens)
+		return stop
+	})
+	require.ErrorIs(t, err, stop, "callback errors stop iteration")
+	require.Equal(t, []int64{1}, ids)
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestUsageLogRepositoryCountErrorRequests(t *testing.T) {
+	db, mock := newSQLMock(t)
+	repo := &usageLogRepository{sql: db}
+	start := time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)
+	end := start.AddDate(0, 0, 1)
+
+	mock.ExpectQuery(`SELECT COUNT\(\*\)\s+FROM ops_error_logs\s+WHERE created_at >= \$1 AND created_at < \$2\s+AND COALESCE\(status_code, 0\) >= 400\s+AND NOT is_business_limited`).
+		WithArgs(start, end).
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(int64(12)))
+
+	count, err := repo.CountErrorRequests(context.Background(), start, end)
+	require.NoError(t, err)
+	require.Equal(t, int64(12), count)
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestUsageLogRepositoryGetClientTypeUsage(t *testing.T) {
+	db, mock := newSQLMock(t)
+	repo := &usageLogRepository{sql: db}
+	start := time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)
+	end := start.AddDate(0, 0, 1)
+
+	usageCols := []string{"client_type", "user_agent", "requests", "input_tokens", "output_tokens", "cache_creation_tokens", "cache_read_tokens", "total_tokens", "cost", "actual_cost"}
+	mock.ExpectQuery(`SELECT\s+COALESCE\(client_type, ''\) as client_type,\s+CASE WHEN client_type IS NULL THEN COALESCE\(user_agent, ''\) ELSE '' END as user_agent,.+FROM usage_logs.+GROUP BY 1, 2`).
+		WithArgs(start, end).
+		WillReturnRows(sqlmock.NewRows(usageCols).
+			AddRow("codex_cli", "", int64(5), int64(100), int64(50), int64(0), int64(0), int64(150), 1.5, 1.5).
+			AddRow("", "curl/8.4.0", int64(1), int64(1), int64(1), int64(0), int64(0), int64(2), 0.1, 0.1))
+	mock.ExpectQuery(`SELECT\s+COALESCE\(client_type, ''\) as client_type,.+FROM ops_error_logs.+NOT is_business_limited\s+GROUP BY 1, 2`).
+		WithArgs(start, end).
+		WillReturnRows(sqlmock.NewRows([]string{"client_type", "user_agent", "errors"}).
+			AddRow("codex_cli", "", int64(2)).
+			AddRow("codex_vscode", "", int64(1)))
+
+	rows, err := repo.GetClientTypeUsage(context.Background(), start, end)
+	require.NoError(t, err)
+	require.Equal(t, []usagestats.ClientTypeUsageRow{
+		{ClientType: "codex_cli", Requests: 5, Errors: 2, InputTokens: 100, OutputTokens: 50, TotalTokens: 150, Cost: 1.5, ActualCost: 1.5},
+		{UserAgent: "curl/8.4.0", Requests: 1, InputTokens: 1, OutputTokens: 1, TotalTokens: 2, Cost: 0.1, ActualCost: 0.1},
+		{ClientType: "codex_vscode", Errors: 1},
+	}, rows)
+	require.NoError(t, mock.ExpectationsWereMet())
+}
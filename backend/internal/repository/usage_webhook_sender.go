@@ -0,0 +1,55 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/ShaohongDong/sub2api/internal/config"
+	"github.com/ShaohongDong/sub2api/internal/pkg/httpclient"
+	"github.com/ShaohongDong/sub2api/internal/service"
+)
+
+type usageWebhookSender struct {
+	httpClient *http.Client
+	url        string
+	secret     string
+}
+
+// usageWebhookPayload 推送请求体，records 为按完成顺序排列的用量记录
+type usageWebhookPayload struct {
+	Records []*service.UsageWebhookRecord `json:"records"`
+}
+
+// NewUsageWebhookSender 创建用量推送 sender；未启用 usage_webhook 时返回 nil。
+// 签名方式与其他出站 webhook 相同（见 postSignedJSON），接收端可复用同一套校验逻辑。
+func NewUsageWebhookSender(cfg *config.Config) service.UsageWebhookSender {
+	if cfg == nil || !cfg.UsageWebhook.Enabled || strings.TrimSpace(cfg.UsageWebhook.URL) == "" {
+		return nil
+	}
+	timeout := time.Duration(cfg.UsageWebhook.TimeoutSeconds) * time.Second
+	if timeout <= 0 {
+		timeout = 10 * time.Second
+	}
+	sharedClient, err := httpclient.GetClient(httpclient.Options{Timeout: timeout})
+	if err != nil {
+		sharedClient = &http.Client{Timeout: timeout}
+	}
+	return &usageWebhookSender{
+		httpClient: sharedClient,
+		url:        strings.TrimSpace(cfg.UsageWebhook.URL),
+		secret:     cfg.UsageWebhook.Secret,
+	}
+}
+
+func (w *usageWebhookSender) SendUsageBatch(ctx context.Context, records []*service.UsageWebhookRecord) error {
+	payload, err := json.Marshal(usageWebhookPayload{Records: records})
+	if err != nil {
+		return fmt.Errorf("marshal records: %w", err)
+	}
+
+	return postSignedJSON(ctx, w.httpClient, w.url, w.secret, payload)
+}
@@ -0,0 +1,88 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/ShaohongDong/sub2api/internal/config"
+	"github.com/ShaohongDong/sub2api/internal/service"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestUsageWebhookSender(t *testing.T, secret string, handler http.HandlerFunc) *usageWebhookSender {
+	t.Helper()
+	cfg := &config.Config{UsageWebhook: config.UsageWebhookConfig{
+		Enabled:        true,
+		URL:            "http://in-process/usage",
+		Secret:         secret,
+		TimeoutSeconds: 5,
+	}}
+	sender, ok := NewUsageWebhookSender(cfg).(*usageWebhookSender)
+	require.True(t, ok, "type assertion failed")
+	sender.httpClient = &http.Client{Transport: newInProcessTransport(handler, nil)}
+	return sender
+}
+
+func TestNewUsageWebhookSender_Disabled(t *testing.T) {
+	require.Nil(t, NewUsageWebhookSender(nil))
+	require.Nil(t, NewUsageWebhookSender(&config.Config{}))
+	require.Nil(t, NewUsageWebhookSender(&config.Config{UsageWebhook: config.UsageWebhookConfig{URL: "http://x/usage"}}))
+}
+
+func TestUsageWebhookSender_PostsSignedBatch(t *testing.T) {
+	var (
+		body      []byte
+		signature string
+	)
+	sender := newTestUsageWebhookSender(t, "s3cret", func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, http.MethodPost, r.Method)
+		require.Equal(t, "application/json", r.Header.Get("Content-Type"))
+		body, _ = io.ReadAll(r.Body)
+		signature = r.Header.Get(webhookSignatureHeader)
+		w.WriteHeader(http.StatusAccepted)
+	})
+
+	latency := 1200
+	records := []*service.UsageWebhookRecord{{
+		RequestID:    "req_1",
+		UserID:       3,
+		APIKeyID:     7,
+		AccountID:    11,
+		Platform:     service.PlatformOpenAI,
+		Model:        "gpt-5",
+		InputTokens:  100,
+		OutputTokens: 20,
+		TotalCost:    0.01,
+		ActualCost:   0.012,
+		LatencyMs:    &latency,
+		Stream:       true,
+		CreatedAt:    time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC),
+	}}
+	require.NoError(t, sender.SendUsageBatch(context.Background(), records))
+
+	require.Equal(t, signWebhookPayload("s3cret", body), signature)
+
+	var got struct {
+		Records []map[string]any `json:"records"`
+	}
+	require.NoError(t, json.Unmarshal(body, &got))
+	require.Len(t, got.Records, 1)
+	require.Equal(t, "req_1", got.Records[0]["request_id"])
+	require.Equal(t, float64(7), got.Records[0]["api_key_id"])
+	require.Equal(t, "gpt-5", got.Records[0]["model"])
+	require.Equal(t, float64(1200), got.Records[0]["latency_ms"])
+	require.Equal(t, 0.012, got.Records[0]["actual_cost"])
+}
+
+func TestUsageWebhookSender_Non2xxIsError(t *testing.T) {
+	sender := newTestUsageWebhookSender(t, "", func(w http.ResponseWriter, r *http.Request) {
+		require.Empty(t, r.Header.Get(webhookSignatureHeader))
+		w.WriteHeader(http.StatusServiceUnavailable)
+	})
+	err := sender.SendUsageBatch(context.Background(), []*service.UsageWebhookRecord{{RequestID: "req_1"}})
+	require.ErrorContains(t, err, "status 503")
+}
@@ -0,0 +1,123 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/ShaohongDong/sub2api/internal/service"
+)
+
+type userFileRepository struct {
+	db *sql.DB
+}
+
+func NewUserFileRepository(db *sql.DB) service.UserFileRepository {
+	return &userFileRepository{db: db}
+}
+
+const userFileColumns = `id, user_id, api_key_id, filename, purpose, mime_type, bytes, expires_at, created_at`
+
+// userFileNotExpired 过滤已过期但尚未被清理的文件。
+const userFileNotExpired = `(expires_at IS NULL OR expires_at > NOW())`
+
+func (r *userFileRepository) Create(ctx context.Context, file *service.UserFile, content []byte) error {
+	return r.db.QueryRowContext(ctx, `
+		INSERT INTO user_files (id, user_id, api_key_id, filename, purpose, mime_type, bytes, content, expires_at, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, NOW())
+		RETURNING created_at
+	`, file.ID, file.UserID, file.APIKeyID, file.Filename, file.Purpose, file.MimeType, file.Bytes, content, file.ExpiresAt,
+	).Scan(&file.CreatedAt)
+}
+
+func (r *userFileRepository) GetByID(ctx context.Context, userID int64, id string) (*service.UserFile, error) {
+	row := r.db.QueryRowContext(ctx, `
+		SELECT `+userFileColumns+` FROM user_files
+		WHERE id = $1 AND user_id = $2 AND `+userFileNotExpired, id, userID)
+	file, err := scanUserFile(row)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, service.ErrUserFileNotFound
+	}
+	return file, err
+}
+
+func (r *userFileRepository) GetContent(ctx context.Context, userID int64, id string) (*service.UserFile, []byte, error) {
+	file := &service.UserFile{}
+	var apiKeyID sql.NullInt64
+	var content []byte
+	err := r.db.QueryRowContext(ctx, `
+		SELECT `+userFileColumns+`, content FROM user_files
+		WHERE id = $1 AND user_id = $2 AND `+userFileNotExpired, id, userID,
+	).Scan(&file.ID, &file.UserID, &apiKeyID, &file.Filename, &file.Purpose, &file.MimeType, &file.Bytes,
+		&file.ExpiresAt, &file.CreatedAt, &content)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil, service.ErrUserFileNotFound
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+	file.APIKeyID = apiKeyID.Int64
+	return file, content, nil
+}
+
+func (r *userFileRepository) ListByUser(ctx context.Context, userID int64, purpose string, after string, limit int) ([]*service.UserFile, error) {
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT `+userFileColumns+`
+		FROM user_files
+		WHERE user_id = $1 AND `+userFileNotExpired+`
+		  AND ($2 = '' OR purpose = $2)
+		  AND ($3 = '' OR (created_at, id) < (SELECT created_at, id FROM user_files WHERE id = $3))
+		ORDER BY created_at DESC, id DESC
+		LIMIT $4
+	`, userID, purpose, after, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = rows.Close() }()
+
+	var files []*service.UserFile
+	for rows.Next() {
+		file, err := scanUserFile(rows)
+		if err != nil {
+			return nil, err
+		}
+		files = append(files, file)
+	}
+	return files, rows.Err()
+}
+
+func (r *userFileRepository) CountByUser(ctx context.Context, userID int64) (int, error) {
+	var count int
+	err := r.db.QueryRowContext(ctx, `
+		SELECT COUNT(*) FROM user_files WHERE user_id = $1 AND `+userFileNotExpired, userID).Scan(&count)
+	return count, err
+}
+
+func (r *userFileRepository) Delete(ctx context.Context, userID int64, id string) (bool, error) {
+	res, err := r.db.ExecContext(ctx, `DELETE FROM user_files WHERE id = $1 AND user_id = $2`, id, userID)
+	if err != nil {
+		return false, err
+	}
+	affected, err := res.RowsAffected()
+	return affected > 0, err
+}
+
+func (r *userFileRepository) DeleteExpired(ctx context.Context, now time.Time) (int64, error) {
+	res, err := r.db.ExecContext(ctx, `DELETE FROM user_files WHERE expires_at IS NOT NULL AND expires_at <= $1`, now)
+	if err != nil {
+		return 0, err
+	}
+	return res.RowsAffected()
+}
+
+func scanUserFile(row scannable) (*service.UserFile, error) {
+	file := &service.UserFile{}
+	var apiKeyID sql.NullInt64
+	if err := row.Scan(&file.ID, &file.UserID, &apiKeyID, &file.Filename, &file.Purpose, &file.MimeType, &file.Bytes,
+		&file.ExpiresAt, &file.CreatedAt); err != nil {
+		return nil, err
+	}
+	file.APIKeyID = apiKeyID.Int64
+	return file, nil
+}
@@ -189,6 +189,9 @@ func (r *userRepository) ListWithFilters(ctx context.Context, params pagination.
 	if filters.Role != "" {
 		q = q.Where(dbuser.RoleEQ(filters.Role))
 	}
+	if filters.TenantID > 0 {
+		q = q.Where(dbuser.TenantIDEQ(filters.TenantID))
+	}
 	if filters.Search != "" {
 		q = q.Where(
 			dbuser.Or(
@@ -0,0 +1,48 @@
+package repository
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestSignWebhookPayload(t *testing.T) {
+	mac := hmac.New(sha256.New, []byte("s3cret"))
+	_, _ = mac.Write([]byte(`{"a":1}`))
+	require.Equal(t, "sha256="+hex.EncodeToString(mac.Sum(nil)), signWebhookPayload("s3cret", []byte(`{"a":1}`)))
+}
+
+func TestPostSignedJSON(t *testing.T) {
+	var (
+		body      []byte
+		signature string
+	)
+	client := &http.Client{Transport: newInProcessTransport(func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, http.MethodPost, r.Method)
+		require.Equal(t, "application/json", r.Header.Get("Content-Type"))
+		body, _ = io.ReadAll(r.Body)
+		signature = r.Header.Get(webhookSignatureHeader)
+		w.WriteHeader(http.StatusNoContent)
+	}, nil)}
+
+	require.NoError(t, postSignedJSON(context.Background(), client, "http://in-process/hook", "s3cret", []byte(`{"a":1}`)))
+	require.Equal(t, `{"a":1}`, string(body))
+	require.Equal(t, signWebhookPayload("s3cret", body), signature)
+
+	require.NoError(t, postSignedJSON(context.Background(), client, "http://in-process/hook", "", []byte(`{}`)))
+	require.Empty(t, signature)
+}
+
+func TestPostSignedJSON_Non2xxIsError(t *testing.T) {
+	client := &http.Client{Transport: newInProcessTransport(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadGateway)
+	}, nil)}
+	err := postSignedJSON(context.Background(), client, "http://in-process/hook", "", []byte(`{}`))
+	require.ErrorContains(t, err, "status 502")
+}
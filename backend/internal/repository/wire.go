@@ -61,9 +61,20 @@ var ProviderSet = wire.NewSet(
 	NewPromoCodeRepository,
 	NewAnnouncementRepository,
 	NewAnnouncementReadRepository,
+	NewTenantRepository,
 	NewUsageLogRepository,
 	NewIdempotencyRepository,
 	NewUsageCleanupRepository,
+	NewBatchJobRepository,
+	NewBackgroundResponseRepository,
+	NewAdminAuditRepository,
+	NewDeadLetterRepository,
+	NewContentLogRepository,
+	NewAlertWebhookNotifier,
+	NewUsageWebhookSender,
+	NewSharedStateCache,
+	NewLeaderLockCache,
+	NewUserFileRepository,
 	NewDashboardAggregationRepository,
 	NewSettingRepository,
 	NewOpsRepository,
@@ -84,6 +95,8 @@ var ProviderSet = wire.NewSet(
 	NewRPMCache,
 	NewUserMsgQueueCache,
 	NewDashboardCache,
+	NewResponseCacheStore,
+	NewConversationStore,
 	NewEmailCache,
 	NewIdentityCache,
 	NewRedeemCache,
@@ -98,6 +111,7 @@ var ProviderSet = wire.NewSet(
 
 	// Encryptors
 	NewAESEncryptor,
+	NewCredentialCipher,
 
 	// HTTP service ports (DI Strategy A: return interface directly)
 	NewTurnstileVerifier,
@@ -106,7 +120,8 @@ var ProviderSet = wire.NewSet(
 	NewProxyExitInfoProber,
 	NewClaudeUsageFetcher,
 	NewClaudeOAuthClient,
-	NewHTTPUpstream,
+	ProvideGatewayHookRunner,
+	ProvideHTTPUpstream,
 	NewOpenAIOAuthClient,
 	NewGeminiOAuthClient,
 	NewGeminiCliCodeAssistClient,
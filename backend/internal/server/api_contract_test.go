@@ -79,13 +79,21 @@ func TestAPIContracts(t *testing.T) {
 					"user_id": 1,
 					"key": "sk_custom_1234567890",
 					"name": "Key One",
+					"description": "",
 					"group_id": null,
 					"status": "active",
 					"ip_whitelist": null,
 					"ip_blacklist": null,
+					"scopes": null,
+					"allowed_models": null,
+					"client_restriction": "",
+					"tags": null,
 					"last_used_at": null,
 					"quota": 0,
 					"quota_used": 0,
+					"image_quota": 0,
+					"image_quota_used": 0,
+					"suppress_reasoning": false,
 					"rate_limit_5h": 0,
 					"rate_limit_1d": 0,
 					"rate_limit_7d": 0,
@@ -95,6 +103,24 @@ func TestAPIContracts(t *testing.T) {
 					"window_5h_start": null,
 					"window_1d_start": null,
 					"window_7d_start": null,
+					"rpm_limit": 0,
+					"tpm_limit": 0,
+					"daily_request_limit": 0,
+					"daily_token_limit": 0,
+					"monthly_request_limit": 0,
+					"monthly_token_limit": 0,
+					"budget_amount": 0,
+					"budget_period": "month",
+					"budget_action": "disable",
+					"budget_fallback_model": "",
+					"budget_used": 0,
+					"budget_exceeded": false,
+					"degraded_fallback_model": "",
+					"system_prompt": "",
+					"system_prompt_mode": "",
+					"moderation_mode": "",
+					"log_content": false,
+					"simulate": false,
 					"expires_at": null,
 					"created_at": "2025-01-02T03:04:05Z",
 					"updated_at": "2025-01-02T03:04:05Z"
@@ -113,6 +139,9 @@ func TestAPIContracts(t *testing.T) {
 					Status:    service.StatusActive,
 					CreatedAt: deps.now,
 					UpdatedAt: deps.now,
+
+					BudgetPeriod: service.APIKeyBudgetPeriodMonth,
+					BudgetAction: service.APIKeyBudgetActionDisable,
 				})
 			},
 			method:     http.MethodGet,
@@ -128,13 +157,21 @@ func TestAPIContracts(t *testing.T) {
 							"user_id": 1,
 							"key": "sk_custom_1234567890",
 							"name": "Key One",
+							"description": "",
 							"group_id": null,
 							"status": "active",
 							"ip_whitelist": null,
 							"ip_blacklist": null,
+							"scopes": null,
+							"allowed_models": null,
+							"client_restriction": "",
+							"tags": null,
 							"last_used_at": null,
 							"quota": 0,
 							"quota_used": 0,
+							"image_quota": 0,
+							"image_quota_used": 0,
+							"suppress_reasoning": false,
 							"rate_limit_5h": 0,
 							"rate_limit_1d": 0,
 							"rate_limit_7d": 0,
@@ -144,6 +181,24 @@ func TestAPIContracts(t *testing.T) {
 							"window_5h_start": null,
 							"window_1d_start": null,
 							"window_7d_start": null,
+							"rpm_limit": 0,
+							"tpm_limit": 0,
+							"daily_request_limit": 0,
+							"daily_token_limit": 0,
+							"monthly_request_limit": 0,
+							"monthly_token_limit": 0,
+							"budget_amount": 0,
+							"budget_period": "month",
+							"budget_action": "disable",
+							"budget_fallback_model": "",
+							"budget_used": 0,
+							"budget_exceeded": false,
+							"degraded_fallback_model": "",
+							"system_prompt": "",
+							"system_prompt_mode": "",
+							"moderation_mode": "",
+							"log_content": false,
+							"simulate": false,
 							"expires_at": null,
 							"created_at": "2025-01-02T03:04:05Z",
 							"updated_at": "2025-01-02T03:04:05Z"
@@ -417,6 +472,7 @@ func TestAPIContracts(t *testing.T) {
 							"cache_read_tokens": 2,
 							"cache_creation_5m_tokens": 0,
 							"cache_creation_1h_tokens": 0,
+							"reasoning_tokens": 0,
 							"input_cost": 0,
 							"output_cost": 0,
 							"cache_creation_cost": 0,
@@ -537,6 +593,7 @@ func TestAPIContracts(t *testing.T) {
 					"purchase_subscription_url": "",
 					"min_claude_code_version": "",
 					"max_claude_code_version": "",
+					"min_codex_cli_version": "",
 					"allow_ungrouped_key_scheduling": false,
 					"custom_menu_items": []
 				}
@@ -1524,6 +1581,10 @@ func (r *stubApiKeyRepo) IncrementQuotaUsed(ctx context.Context, id int64, amoun
 	return 0, errors.New("not implemented")
 }
 
+func (r *stubApiKeyRepo) IncrementImageQuotaUsed(ctx context.Context, id int64, count int) (int, error) {
+	return 0, errors.New("not implemented")
+}
+
 func (r *stubApiKeyRepo) UpdateLastUsed(ctx context.Context, id int64, usedAt time.Time) error {
 	key, ok := r.byID[id]
 	if !ok {
@@ -1548,6 +1609,18 @@ func (r *stubApiKeyRepo) GetRateLimitData(ctx context.Context, id int64) (*servi
 	return nil, nil
 }
 
+func (r *stubApiKeyRepo) IncrementBudgetUsed(ctx context.Context, id int64, amount float64, periodStart time.Time) (float64, error) {
+	return 0, errors.New("not implemented")
+}
+
+func (r *stubApiKeyRepo) MarkBudgetExceeded(ctx context.Context, id int64, periodStart, exceededAt time.Time) (bool, error) {
+	return false, errors.New("not implemented")
+}
+
+func (r *stubApiKeyRepo) RotateKey(ctx context.Context, id int64, oldKey, newKey string, graceUntil *time.Time) error {
+	return errors.New("not implemented")
+}
+
 type stubUsageLogRepo struct {
 	userLogs map[int64][]service.UsageLog
 }
@@ -1628,6 +1701,26 @@ func (r *stubUsageLogRepo) GetGroupStatsWithFilters(ctx context.Context, startTi
 	return nil, errors.New("not implemented")
 }
 
+func (r *stubUsageLogRepo) GetUsageAggregates(ctx context.Context, query usagestats.UsageAggregateQuery) ([]usagestats.UsageAggregate, error) {
+	return nil, errors.New("not implemented")
+}
+
+func (r *stubUsageLogRepo) ForEachUsageAggregate(ctx context.Context, query usagestats.UsageAggregateQuery, fn func(*usagestats.UsageAggregate) error) error {
+	return errors.New("not implemented")
+}
+
+func (r *stubUsageLogRepo) ForEachUsageExportRow(ctx context.Context, query usagestats.UsageAggregateQuery, fn func(*usagestats.UsageExportRow) error) error {
+	return errors.New("not implemented")
+}
+
+func (r *stubUsageLogRepo) GetClientTypeUsage(ctx context.Context, startTime, endTime time.Time) ([]usagestats.ClientTypeUsageRow, error) {
+	return nil, errors.New("not implemented")
+}
+
+func (r *stubUsageLogRepo) CountErrorRequests(ctx context.Context, startTime, endTime time.Time) (int64, error) {
+	return 0, errors.New("not implemented")
+}
+
 func (r *stubUsageLogRepo) GetAPIKeyUsageTrend(ctx context.Context, startTime, endTime time.Time, granularity string, limit int) ([]usagestats.APIKeyUsageTrendPoint, error) {
 	return nil, errors.New("not implemented")
 }
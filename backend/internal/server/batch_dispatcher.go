@@ -0,0 +1,68 @@
+package server
+
+import (
+	"bytes"
+	"context"
+	"net"
+	"net/http"
+
+	"github.com/ShaohongDong/sub2api/internal/service"
+)
+
+// batchDispatcher 在进程内将批处理请求重放到网关路由，
+// 使其与普通客户端请求走完全相同的鉴权、调度、限流与计费链路。
+type batchDispatcher struct {
+	handler http.Handler
+}
+
+func newBatchDispatcher(handler http.Handler) service.BatchRequestDispatcher {
+	return &batchDispatcher{handler: handler}
+}
+
+func (d *batchDispatcher) Dispatch(ctx context.Context, apiKey string, clientIP string, method string, url string, body []byte) (*service.BatchDispatchResult, error) {
+	req, err := http.NewRequestWithContext(ctx, method, url, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Authorization", "Bearer "+apiKey)
+	req.Header.Set("Content-Type", "application/json")
+	if clientIP == "" {
+		clientIP = "127.0.0.1"
+	}
+	req.RemoteAddr = net.JoinHostPort(clientIP, "0")
+
+	w := &batchResponseWriter{header: make(http.Header)}
+	d.handler.ServeHTTP(w, req)
+	if w.status == 0 {
+		w.status = http.StatusOK
+	}
+	return &service.BatchDispatchResult{
+		StatusCode: w.status,
+		Header:     w.header,
+		Body:       w.body.Bytes(),
+	}, nil
+}
+
+// batchResponseWriter 缓存响应；Flush 为空实现以兼容流式处理分支。
+type batchResponseWriter struct {
+	header http.Header
+	status int
+	body   bytes.Buffer
+}
+
+func (w *batchResponseWriter) Header() http.Header { return w.header }
+
+func (w *batchResponseWriter) WriteHeader(status int) {
+	if w.status == 0 {
+		w.status = status
+	}
+}
+
+func (w *batchResponseWriter) Write(p []byte) (int, error) {
+	if w.status == 0 {
+		w.status = http.StatusOK
+	}
+	return w.body.Write(p)
+}
+
+func (w *batchResponseWriter) Flush() {}
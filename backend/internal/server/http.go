@@ -2,12 +2,14 @@
 package server
 
 import (
+	"encoding/json"
 	"log"
 	"net/http"
 	"time"
 
 	"github.com/ShaohongDong/sub2api/internal/config"
 	"github.com/ShaohongDong/sub2api/internal/handler"
+	pkghttputil "github.com/ShaohongDong/sub2api/internal/pkg/httputil"
 	middleware2 "github.com/ShaohongDong/sub2api/internal/server/middleware"
 	"github.com/ShaohongDong/sub2api/internal/service"
 
@@ -22,6 +24,8 @@ import (
 var ProviderSet = wire.NewSet(
 	ProvideRouter,
 	ProvideHTTPServer,
+	ProvideShutdownDrainer,
+	ProvideTLSManager,
 )
 
 // ProvideRouter 提供路由器
@@ -35,6 +39,10 @@ func ProvideRouter(
 	subscriptionService *service.SubscriptionService,
 	opsService *service.OpsService,
 	settingService *service.SettingService,
+	batchService *service.BatchService,
+	backgroundResponseService *service.BackgroundResponseService,
+	contextOverflowService *service.ContextOverflowService,
+	chatMultiChoiceService *service.ChatMultiChoiceService,
 	redisClient *redis.Client,
 ) *gin.Engine {
 	if cfg.Server.Mode == "release" {
@@ -42,6 +50,8 @@ func ProvideRouter(
 	}
 
 	r := gin.New()
+	// multipart 表单（文件上传）超过该大小的部分写入临时文件，请求结束后由 net/http 清理
+	r.MaxMultipartMemory = cfg.Gateway.MultipartMaxMemory
 	r.Use(middleware2.Recovery())
 	if len(cfg.Server.TrustedProxies) > 0 {
 		if err := r.SetTrustedProxies(cfg.Server.TrustedProxies); err != nil {
@@ -56,26 +66,34 @@ func ProvideRouter(
 		}
 	}
 
-	return SetupRouter(r, handlers, jwtAuth, adminAuth, apiKeyAuth, apiKeyService, subscriptionService, opsService, settingService, cfg, redisClient)
+	engine := SetupRouter(r, handlers, jwtAuth, adminAuth, apiKeyAuth, apiKeyService, subscriptionService, opsService, settingService, cfg, redisClient)
+	// 批处理、background 响应、上下文摘要压缩与 n > 1 子请求经由路由在进程内执行（在此注入以避免 service -> server 的依赖环）
+	dispatcher := newBatchDispatcher(engine)
+	batchService.SetDispatcher(dispatcher)
+	backgroundResponseService.SetDispatcher(dispatcher)
+	contextOverflowService.SetDispatcher(dispatcher)
+	chatMultiChoiceService.SetDispatcher(dispatcher)
+	return engine
 }
 
 // ProvideHTTPServer 提供 HTTP 服务器
-func ProvideHTTPServer(cfg *config.Config, router *gin.Engine) *http.Server {
-	httpHandler := http.Handler(router)
+func ProvideHTTPServer(cfg *config.Config, router *gin.Engine, drainer *ShutdownDrainer, tlsManager *TLSManager) *http.Server {
+	// 排空包装位于 h2c 之内，按单个请求（HTTP/2 流）计数，而不是按被接管的连接
+	httpHandler := drainer.Wrap(router)
 
 	globalMaxSize := cfg.Server.MaxRequestBodySize
 	if globalMaxSize <= 0 {
 		globalMaxSize = cfg.Gateway.MaxBodySize
 	}
 	if globalMaxSize > 0 {
-		httpHandler = http.MaxBytesHandler(httpHandler, globalMaxSize)
+		httpHandler = maxRequestBodyHandler(httpHandler, globalMaxSize)
 		log.Printf("Global max request body size: %d bytes (%.2f MB)", globalMaxSize, float64(globalMaxSize)/(1<<20))
 	}
 
-	// 根据配置决定是否启用 H2C
-	if cfg.Server.H2C.Enabled {
+	// 根据配置决定是否启用 H2C（内置 TLS 时 HTTP/2 经 ALPN 协商，不再需要 h2c）
+	if cfg.Server.H2C.Enabled && !tlsManager.Enabled() {
 		h2cConfig := cfg.Server.H2C
-		httpHandler = h2c.NewHandler(router, &http2.Server{
+		httpHandler = h2c.NewHandler(httpHandler, &http2.Server{
 			MaxConcurrentStreams:         h2cConfig.MaxConcurrentStreams,
 			IdleTimeout:                  time.Duration(h2cConfig.IdleTimeout) * time.Second,
 			MaxReadFrameSize:             uint32(h2cConfig.MaxReadFrameSize),
@@ -91,7 +109,7 @@ func ProvideHTTPServer(cfg *config.Config, router *gin.Engine) *http.Server {
 		)
 	}
 
-	return &http.Server{
+	srv := &http.Server{
 		Addr:    cfg.Server.Address(),
 		Handler: httpHandler,
 		// ReadHeaderTimeout: 读取请求头的超时时间，防止慢速请求头攻击
@@ -101,4 +119,32 @@ func ProvideHTTPServer(cfg *config.Config, router *gin.Engine) *http.Server {
 		// 注意：不设置 WriteTimeout，因为流式响应可能持续十几分钟
 		// 不设置 ReadTimeout，因为大请求体可能需要较长时间读取
 	}
+	if tlsManager.Enabled() {
+		srv.TLSConfig = tlsManager.tlsConfig
+	}
+	return srv
+}
+
+// maxRequestBodyHandler 全局请求体上限：声明的 Content-Length 超限时直接返回 413（不读取请求体），
+// 其余请求由 MaxBytesReader 在读取时截断
+func maxRequestBodyHandler(next http.Handler, maxBytes int64) http.Handler {
+	limited := http.MaxBytesHandler(next, maxBytes)
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.ContentLength > maxBytes {
+			w.Header().Set("Connection", "close")
+			w.Header().Set("Content-Type", "application/json; charset=utf-8")
+			w.WriteHeader(http.StatusRequestEntityTooLarge)
+			body, _ := json.Marshal(map[string]any{
+				"error": map[string]any{
+					"message": pkghttputil.BodyTooLargeMessage(maxBytes),
+					"type":    "invalid_request_error",
+					"param":   nil,
+					"code":    "request_too_large",
+				},
+			})
+			_, _ = w.Write(body)
+			return
+		}
+		limited.ServeHTTP(w, r)
+	})
 }
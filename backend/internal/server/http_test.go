@@ -0,0 +1,51 @@
+//go:build unit
+
+package server
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/ShaohongDong/sub2api/internal/config"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/require"
+	"github.com/tidwall/gjson"
+)
+
+func TestProvideHTTPServer_GlobalBodyLimitAppliesWithH2C(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	for _, h2cEnabled := range []bool{false, true} {
+		router := gin.New()
+		reads := 0
+		router.POST("/v1/messages", func(c *gin.Context) {
+			reads++
+			if _, err := io.ReadAll(c.Request.Body); err != nil {
+				c.Status(http.StatusRequestEntityTooLarge)
+				return
+			}
+			c.Status(http.StatusOK)
+		})
+		cfg := &config.Config{}
+		cfg.Server.MaxRequestBodySize = 16
+		cfg.Server.H2C.Enabled = h2cEnabled
+		srv := ProvideHTTPServer(cfg, router, NewShutdownDrainer(), nil)
+
+		// 声明长度超限：不进入路由
+		w := httptest.NewRecorder()
+		srv.Handler.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/v1/messages", bytes.NewReader(bytes.Repeat([]byte("a"), 17))))
+		require.Equal(t, http.StatusRequestEntityTooLarge, w.Code, "h2c=%v", h2cEnabled)
+		require.Equal(t, "request_too_large", gjson.Get(w.Body.String(), "error.code").String())
+		require.Zero(t, reads)
+
+		// 未声明长度：读取时截断
+		req := httptest.NewRequest(http.MethodPost, "/v1/messages", bytes.NewReader(bytes.Repeat([]byte("a"), 17)))
+		req.ContentLength = -1
+		w = httptest.NewRecorder()
+		srv.Handler.ServeHTTP(w, req)
+		require.Equal(t, http.StatusRequestEntityTooLarge, w.Code, "h2c=%v", h2cEnabled)
+		require.Equal(t, 1, reads)
+	}
+}
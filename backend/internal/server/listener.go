@@ -0,0 +1,86 @@
+package server
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"net"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/ShaohongDong/sub2api/internal/config"
+)
+
+// systemdListenFDsStart systemd socket activation 传入的第一个文件描述符（SD_LISTEN_FDS_START）
+const systemdListenFDsStart = 3
+
+// Listen 按配置创建主服务监听：systemd socket activation 优先，其次 Unix domain socket，最后 TCP host:port
+func Listen(cfg config.ServerConfig) (net.Listener, error) {
+	if cfg.SystemdSocketActivation {
+		return systemdListener()
+	}
+	if path := strings.TrimSpace(cfg.UnixSocket); path != "" {
+		mode, err := cfg.UnixSocketFileMode()
+		if err != nil {
+			return nil, err
+		}
+		return unixSocketListener(path, mode)
+	}
+	return net.Listen("tcp", cfg.Address())
+}
+
+// unixSocketListener 监听 Unix socket 并设置文件权限；上次异常退出遗留的 socket 文件会先被删除，
+// 但拒绝覆盖非 socket 文件，避免配置错误误删数据
+func unixSocketListener(path string, mode os.FileMode) (net.Listener, error) {
+	if info, err := os.Lstat(path); err == nil {
+		if info.Mode()&fs.ModeSocket == 0 {
+			return nil, fmt.Errorf("unix socket path %s exists and is not a socket", path)
+		}
+		if err := os.Remove(path); err != nil {
+			return nil, fmt.Errorf("remove stale unix socket: %w", err)
+		}
+	} else if !errors.Is(err, fs.ErrNotExist) {
+		return nil, fmt.Errorf("stat unix socket path: %w", err)
+	}
+
+	ln, err := net.Listen("unix", path)
+	if err != nil {
+		return nil, err
+	}
+	if err := os.Chmod(path, mode); err != nil {
+		_ = ln.Close()
+		return nil, fmt.Errorf("chmod unix socket: %w", err)
+	}
+	return ln, nil
+}
+
+// systemdListener 接管 systemd 传入的监听 socket（仅使用第一个），并清理相关环境变量避免子进程误用
+func systemdListener() (net.Listener, error) {
+	defer func() {
+		_ = os.Unsetenv("LISTEN_PID")
+		_ = os.Unsetenv("LISTEN_FDS")
+		_ = os.Unsetenv("LISTEN_FDNAMES")
+	}()
+
+	pid, err := strconv.Atoi(os.Getenv("LISTEN_PID"))
+	if err != nil || pid != os.Getpid() {
+		return nil, errors.New("systemd socket activation enabled but LISTEN_PID does not match this process")
+	}
+	count, err := strconv.Atoi(os.Getenv("LISTEN_FDS"))
+	if err != nil || count < 1 {
+		return nil, errors.New("systemd socket activation enabled but no sockets were passed (LISTEN_FDS)")
+	}
+
+	name := "systemd-socket"
+	if names := strings.Split(os.Getenv("LISTEN_FDNAMES"), ":"); names[0] != "" {
+		name = names[0]
+	}
+	file := os.NewFile(uintptr(systemdListenFDsStart), name)
+	defer func() { _ = file.Close() }()
+	ln, err := net.FileListener(file)
+	if err != nil {
+		return nil, fmt.Errorf("use systemd socket: %w", err)
+	}
+	return ln, nil
+}
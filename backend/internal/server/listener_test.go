@@ -0,0 +1,77 @@
+//go:build unit
+
+package server
+
+import (
+	"context"
+	"io"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+
+	"github.com/ShaohongDong/sub2api/internal/config"
+	"github.com/stretchr/testify/require"
+)
+
+func TestListenUnixSocket(t *testing.T) {
+	// Unix socket 路径长度有限，避免使用过长的临时目录
+	dir, err := os.MkdirTemp("", "s2a")
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = os.RemoveAll(dir) })
+	path := filepath.Join(dir, "gw.sock")
+
+	// 遗留的 socket 文件会被替换
+	stale, err := net.Listen("unix", path)
+	require.NoError(t, err)
+	stale.(*net.UnixListener).SetUnlinkOnClose(false)
+	require.NoError(t, stale.Close())
+
+	ln, err := Listen(config.ServerConfig{UnixSocket: path, UnixSocketMode: "0600"})
+	require.NoError(t, err)
+	info, err := os.Stat(path)
+	require.NoError(t, err)
+	require.Equal(t, os.FileMode(0o600), info.Mode().Perm())
+
+	srv := &http.Server{Handler: http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		_, _ = io.WriteString(w, "ok")
+	})}
+	go func() { _ = srv.Serve(ln) }()
+	t.Cleanup(func() { _ = srv.Close() })
+
+	client := &http.Client{Transport: &http.Transport{
+		DialContext: func(ctx context.Context, _, _ string) (net.Conn, error) {
+			return (&net.Dialer{}).DialContext(ctx, "unix", path)
+		},
+	}}
+	resp, err := client.Get("http://gateway/healthz")
+	require.NoError(t, err)
+	body, _ := io.ReadAll(resp.Body)
+	_ = resp.Body.Close()
+	require.Equal(t, "ok", string(body))
+}
+
+func TestListenUnixSocketRefusesRegularFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "data.db")
+	require.NoError(t, os.WriteFile(path, []byte("keep"), 0o600))
+
+	_, err := Listen(config.ServerConfig{UnixSocket: path})
+	require.ErrorContains(t, err, "not a socket")
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	require.Equal(t, "keep", string(data))
+}
+
+func TestListenSystemdRequiresMatchingPID(t *testing.T) {
+	t.Setenv("LISTEN_PID", strconv.Itoa(os.Getpid()+1))
+	t.Setenv("LISTEN_FDS", "1")
+	_, err := Listen(config.ServerConfig{SystemdSocketActivation: true})
+	require.ErrorContains(t, err, "LISTEN_PID")
+
+	t.Setenv("LISTEN_PID", strconv.Itoa(os.Getpid()))
+	t.Setenv("LISTEN_FDS", "0")
+	_, err = Listen(config.ServerConfig{SystemdSocketActivation: true})
+	require.ErrorContains(t, err, "LISTEN_FDS")
+}
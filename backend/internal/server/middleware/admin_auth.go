@@ -23,7 +23,7 @@ func NewAdminAuthMiddleware(
 // adminAuth 管理员认证中间件实现
 // 支持两种认证方式（通过不同的 header 区分）：
 // 1. Admin API Key: x-api-key: <admin-api-key>
-// 2. JWT Token: Authorization: Bearer <jwt-token> (需要管理员角色)
+// 2. JWT Token: Authorization: Bearer <jwt-token> (需要 viewer / operator / admin 角色)
 func adminAuth(
 	authService *service.AuthService,
 	userService *service.UserService,
@@ -187,8 +187,8 @@ func validateJWTForAdmin(
 		return false
 	}
 
-	// 检查管理员权限
-	if !user.IsAdmin() {
+	// 检查管理后台访问权限（viewer / operator / admin），具体路由的角色要求由 AdminRoleAuthorization 校验
+	if !user.HasAdminAccess() {
 		AbortWithError(c, 403, "FORBIDDEN", "Admin access required")
 		return false
 	}
@@ -0,0 +1,62 @@
+package middleware
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/ShaohongDong/sub2api/internal/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+// AdminRoleRule 为匹配的管理路由指定最低角色
+type AdminRoleRule struct {
+	// Method 为空表示匹配所有方法
+	Method string
+	// Path 为路由模板（c.FullPath()），以 "/" 结尾时按前缀匹配
+	Path string
+	Role string
+}
+
+// AdminRoleAuthorization 管理后台按路由授权中间件，必须在 AdminAuth 之后使用。
+// 按顺序匹配 rules，首条命中的规则决定最低角色；未命中时只读请求（GET/HEAD/OPTIONS）
+// 需要 viewer，其余请求需要 operator。
+func AdminRoleAuthorization(rules []AdminRoleRule) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		role, ok := GetUserRoleFromContext(c)
+		if !ok {
+			AbortWithError(c, 401, "UNAUTHORIZED", "User not found in context")
+			return
+		}
+
+		required := requiredAdminRole(rules, c.Request.Method, c.FullPath())
+		if !service.AdminRoleAtLeast(role, required) {
+			AbortWithError(c, 403, "FORBIDDEN", "Role '"+required+"' or higher required")
+			return
+		}
+
+		c.Next()
+	}
+}
+
+// RequireAdminRole 单个路由的最低角色要求，必须在 AdminAuth 之后使用
+func RequireAdminRole(required string) gin.HandlerFunc {
+	return AdminRoleAuthorization([]AdminRoleRule{{Path: "/", Role: required}})
+}
+
+func requiredAdminRole(rules []AdminRoleRule, method, path string) string {
+	for _, rule := range rules {
+		if rule.Method != "" && !strings.EqualFold(rule.Method, method) {
+			continue
+		}
+		if rule.Path == path || (strings.HasSuffix(rule.Path, "/") && strings.HasPrefix(path, rule.Path)) {
+			return rule.Role
+		}
+	}
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions:
+		return service.RoleViewer
+	default:
+		return service.RoleOperator
+	}
+}
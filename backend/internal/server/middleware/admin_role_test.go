@@ -0,0 +1,119 @@
+//go:build unit
+
+package middleware
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/ShaohongDong/sub2api/internal/config"
+	"github.com/ShaohongDong/sub2api/internal/service"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/require"
+)
+
+func newAdminRoleTestRouter(role string, rules []AdminRoleRule) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	admin := router.Group("/admin")
+	admin.Use(func(c *gin.Context) {
+		c.Set(string(ContextKeyUserRole), role)
+		c.Next()
+	}, AdminRoleAuthorization(rules))
+	ok := func(c *gin.Context) { c.JSON(http.StatusOK, gin.H{"ok": true}) }
+	admin.GET("/accounts", ok)
+	admin.POST("/accounts", ok)
+	admin.POST("/dashboard/users-usage", ok)
+	admin.GET("/settings", ok)
+	admin.PUT("/settings/rectifier", ok)
+	return router
+}
+
+func TestAdminRoleAuthorization(t *testing.T) {
+	rules := []AdminRoleRule{
+		{Method: http.MethodPost, Path: "/admin/dashboard/users-usage", Role: service.RoleViewer},
+		{Path: "/admin/settings", Role: service.RoleAdmin},
+		{Path: "/admin/settings/", Role: service.RoleAdmin},
+	}
+
+	cases := []struct {
+		role   string
+		method string
+		path   string
+		want   int
+	}{
+		{service.RoleViewer, http.MethodGet, "/admin/accounts", http.StatusOK},
+		{service.RoleViewer, http.MethodPost, "/admin/accounts", http.StatusForbidden},
+		{service.RoleViewer, http.MethodPost, "/admin/dashboard/users-usage", http.StatusOK},
+		{service.RoleViewer, http.MethodGet, "/admin/settings", http.StatusForbidden},
+		{service.RoleOperator, http.MethodPost, "/admin/accounts", http.StatusOK},
+		{service.RoleOperator, http.MethodGet, "/admin/settings", http.StatusForbidden},
+		{service.RoleOperator, http.MethodPut, "/admin/settings/rectifier", http.StatusForbidden},
+		{service.RoleAdmin, http.MethodPut, "/admin/settings/rectifier", http.StatusOK},
+		{service.RoleUser, http.MethodGet, "/admin/accounts", http.StatusForbidden},
+	}
+	for _, tc := range cases {
+		router := newAdminRoleTestRouter(tc.role, rules)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest(tc.method, tc.path, nil))
+		require.Equal(t, tc.want, w.Code, "%s %s %s", tc.role, tc.method, tc.path)
+	}
+}
+
+func TestAdminRoleAuthorizationRequiresRoleInContext(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(RequireAdminRole(service.RoleViewer))
+	router.GET("/t", func(c *gin.Context) { c.Status(http.StatusOK) })
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/t", nil))
+	require.Equal(t, http.StatusUnauthorized, w.Code)
+}
+
+func TestAdminAuthJWTAllowsConsoleRoles(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	cfg := &config.Config{JWT: config.JWTConfig{Secret: "test-secret", ExpireHour: 1}}
+	authService := service.NewAuthService(nil, nil, nil, cfg, nil, nil, nil, nil, nil, nil)
+
+	users := map[int64]*service.User{
+		1: {ID: 1, Role: service.RoleViewer, Status: service.StatusActive},
+		2: {ID: 2, Role: service.RoleOperator, Status: service.StatusActive},
+		3: {ID: 3, Role: service.RoleUser, Status: service.StatusActive},
+	}
+	userRepo := &stubUserRepo{
+		getByID: func(ctx context.Context, id int64) (*service.User, error) {
+			u, ok := users[id]
+			if !ok {
+				return nil, service.ErrUserNotFound
+			}
+			clone := *u
+			return &clone, nil
+		},
+	}
+	userService := service.NewUserService(userRepo, nil, nil)
+
+	router := gin.New()
+	router.Use(gin.HandlerFunc(NewAdminAuthMiddleware(authService, userService, nil)), RequireAdminRole(service.RoleViewer))
+	router.GET("/t", func(c *gin.Context) {
+		role, _ := GetUserRoleFromContext(c)
+		c.JSON(http.StatusOK, gin.H{"role": role})
+	})
+
+	for id, want := range map[int64]int{1: http.StatusOK, 2: http.StatusOK, 3: http.StatusForbidden} {
+		token, err := authService.GenerateToken(users[id])
+		require.NoError(t, err)
+
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodGet, "/t", nil)
+		req.Header.Set("Authorization", "Bearer "+token)
+		router.ServeHTTP(w, req)
+		require.Equal(t, want, w.Code, "user %d", id)
+		if want == http.StatusOK {
+			require.Contains(t, w.Body.String(), users[id].Role)
+		}
+	}
+}
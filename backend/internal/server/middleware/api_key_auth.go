@@ -8,9 +8,11 @@ import (
 	"github.com/ShaohongDong/sub2api/internal/config"
 	"github.com/ShaohongDong/sub2api/internal/pkg/ctxkey"
 	"github.com/ShaohongDong/sub2api/internal/pkg/ip"
+	"github.com/ShaohongDong/sub2api/internal/pkg/tracing"
 	"github.com/ShaohongDong/sub2api/internal/service"
 
 	"github.com/gin-gonic/gin"
+	"go.opentelemetry.io/otel/attribute"
 )
 
 // NewAPIKeyAuthMiddleware 创建 API Key 认证中间件
@@ -27,6 +29,10 @@ func NewAPIKeyAuthMiddleware(apiKeyService *service.APIKeyService, subscriptionS
 // /v1/usage 端点只需鉴权，不需要计费执行（允许过期/配额耗尽的 Key 查询自身用量）。
 func apiKeyAuthWithSubscription(apiKeyService *service.APIKeyService, subscriptionService *service.SubscriptionService, cfg *config.Config) gin.HandlerFunc {
 	return func(c *gin.Context) {
+		// 鉴权阶段 span：放行前结束，不覆盖后续处理
+		authCtx, authSpan := tracing.Start(c.Request.Context(), "auth.api_key")
+		defer endAuthSpan(c, authSpan)
+
 		// ── 1. 提取 API Key ──────────────────────────────────────────
 
 		queryKey := strings.TrimSpace(c.Query("key"))
@@ -58,23 +64,40 @@ func apiKeyAuthWithSubscription(apiKeyService *service.APIKeyService, subscripti
 			apiKeyString = c.GetHeader("x-goog-api-key")
 		}
 
+		// 如果x-goog-api-key header中没有，尝试从api-key header中提取（Azure OpenAI兼容）
+		if apiKeyString == "" {
+			apiKeyString = c.GetHeader("api-key")
+		}
+
+		// AWS SigV4（Bedrock SDK 兼容）：以 Credential 中的 Access Key ID 作为 API Key
+		if apiKeyString == "" {
+			apiKeyString = extractSigV4AccessKeyID(authHeader)
+		}
+
+		// 浏览器 WebSocket 无法设置请求头（Realtime API 兼容）：从 Sec-WebSocket-Protocol 子协议中提取
+		if apiKeyString == "" {
+			apiKeyString = extractRealtimeSubprotocolAPIKey(c.GetHeader("Sec-WebSocket-Protocol"))
+		}
+
 		// 如果所有header都没有API key
 		if apiKeyString == "" {
-			AbortWithError(c, 401, "API_KEY_REQUIRED", "API key is required in Authorization header (Bearer scheme), x-api-key header, or x-goog-api-key header")
+			AbortWithError(c, 401, "API_KEY_REQUIRED", "API key is required in Authorization header (Bearer scheme), x-api-key header, x-goog-api-key header, or api-key header")
 			return
 		}
 
 		// ── 2. 验证 Key 存在 ─────────────────────────────────────────
 
-		apiKey, err := apiKeyService.GetByKey(c.Request.Context(), apiKeyString)
+		apiKey, err := apiKeyService.GetByKey(authCtx, apiKeyString)
 		if err != nil {
 			if errors.Is(err, service.ErrAPIKeyNotFound) {
 				AbortWithError(c, 401, "INVALID_API_KEY", "Invalid API key")
 				return
 			}
+			authSpan.RecordError(err)
 			AbortWithError(c, 500, "INTERNAL_ERROR", "Failed to validate API key")
 			return
 		}
+		authSpan.SetAttributes(attribute.Int64("sub2api.api_key_id", apiKey.ID))
 
 		// ── 3. 基础鉴权（始终执行） ─────────────────────────────────
 
@@ -88,13 +111,21 @@ func apiKeyAuthWithSubscription(apiKeyService *service.APIKeyService, subscripti
 
 		// 检查 IP 限制（白名单/黑名单）
 		// 注意：错误信息故意模糊，避免暴露具体的 IP 限制机制
-		if len(apiKey.IPWhitelist) > 0 || len(apiKey.IPBlacklist) > 0 {
-			clientIP := ip.GetTrustedClientIP(c)
-			allowed, _ := ip.CheckIPRestrictionWithCompiledRules(clientIP, apiKey.CompiledIPWhitelist, apiKey.CompiledIPBlacklist)
-			if !allowed {
-				AbortWithError(c, 403, "ACCESS_DENIED", "Access denied")
-				return
-			}
+		if !apiKeyAllowsClientIP(c, apiKey) {
+			AbortWithError(c, 403, "ACCESS_DENIED", "Access denied")
+			return
+		}
+
+		// 检查 scope：Key 限定了端点类别时，其他类别的端点一律拒绝
+		if !apiKeyAllowsRequest(apiKey, c.Request) {
+			AbortWithError(c, 403, "API_KEY_SCOPE_DENIED", "API key is not allowed to access this endpoint")
+			return
+		}
+
+		// 检查客户端限制：如仅限 Codex 的 Key 不能被通用脚本使用
+		if !apiKeyAllowsRequestClient(c, apiKey) {
+			AbortWithError(c, 403, "API_KEY_CLIENT_DENIED", "API key is not allowed to be used from this client")
+			return
 		}
 
 		// 检查关联的用户
@@ -109,6 +140,12 @@ func apiKeyAuthWithSubscription(apiKeyService *service.APIKeyService, subscripti
 			return
 		}
 
+		// 租户隔离：Key 绑定的分组必须与用户属于同一租户（用户被移入其他租户后旧 Key 随即失效）
+		if apiKey.Group != nil && !service.TenantMatches(apiKey.User.TenantID, apiKey.Group.TenantID) {
+			AbortWithError(c, 403, "TENANT_MISMATCH", "API key group belongs to a different tenant")
+			return
+		}
+
 		// ── 4. SimpleMode → early return ─────────────────────────────
 
 		if cfg.RunMode == config.RunModeSimple {
@@ -119,7 +156,9 @@ func apiKeyAuthWithSubscription(apiKeyService *service.APIKeyService, subscripti
 			})
 			c.Set(string(ContextKeyUserRole), apiKey.User.Role)
 			setGroupContext(c, apiKey.Group)
+			setSimulationContext(c, cfg, apiKey)
 			_ = apiKeyService.TouchLastUsed(c.Request.Context(), apiKey.ID)
+			endAuthSpan(c, authSpan)
 			c.Next()
 			return
 		}
@@ -134,7 +173,7 @@ func apiKeyAuthWithSubscription(apiKeyService *service.APIKeyService, subscripti
 
 		if isSubscriptionType && subscriptionService != nil {
 			sub, subErr := subscriptionService.GetActiveSubscription(
-				c.Request.Context(),
+				authCtx,
 				apiKey.User.ID,
 				apiKey.Group.ID,
 			)
@@ -171,6 +210,10 @@ func apiKeyAuthWithSubscription(apiKeyService *service.APIKeyService, subscripti
 				AbortWithError(c, 429, "API_KEY_QUOTA_EXHAUSTED", "API key 额度已用完")
 				return
 			}
+			// 花费预算：超限后禁用，或改写为降级模型
+			if !checkAPIKeyBudget(c, apiKey, abortWithOpenAIBudgetError) {
+				return
+			}
 
 			// 订阅模式：验证订阅限额
 			if subscription != nil {
@@ -200,6 +243,11 @@ func apiKeyAuthWithSubscription(apiKeyService *service.APIKeyService, subscripti
 					return
 				}
 			}
+
+			// Key 级 RPM/TPM 与日/月请求、token 配额（最后检查，避免被拒请求占用计数）
+			if !checkAPIKeyRequestLimits(c, apiKeyService, apiKey, abortWithOpenAIRateLimitError) {
+				return
+			}
 		}
 
 		// ── 7. 设置上下文 → Next ─────────────────────────────────────
@@ -214,8 +262,10 @@ func apiKeyAuthWithSubscription(apiKeyService *service.APIKeyService, subscripti
 		})
 		c.Set(string(ContextKeyUserRole), apiKey.User.Role)
 		setGroupContext(c, apiKey.Group)
+		setSimulationContext(c, cfg, apiKey)
 		_ = apiKeyService.TouchLastUsed(c.Request.Context(), apiKey.ID)
 
+		endAuthSpan(c, authSpan)
 		c.Next()
 	}
 }
@@ -250,3 +300,63 @@ func setGroupContext(c *gin.Context, group *service.Group) {
 	ctx := context.WithValue(c.Request.Context(), ctxkey.Group, group)
 	c.Request = c.Request.WithContext(ctx)
 }
+
+// apiKeyAllowsClientIP 按 Key 的 IP 白名单/黑名单校验客户端 IP。
+// 客户端 IP 取自 gin 的可信代理链（server.trusted_proxies）：只有来自可信代理的
+// X-Forwarded-For 才会被采信，直连客户端伪造的转发头不会绕过白名单。
+func apiKeyAllowsClientIP(c *gin.Context, apiKey *service.APIKey) bool {
+	if len(apiKey.IPWhitelist) == 0 && len(apiKey.IPBlacklist) == 0 {
+		return true
+	}
+	whitelist, blacklist := apiKey.CompiledIPWhitelist, apiKey.CompiledIPBlacklist
+	// 规则未预编译时（如绕过 service 构造的 Key）现场编译，避免 nil 规则被当作"不限制"
+	if whitelist == nil {
+		whitelist = ip.CompileIPRules(apiKey.IPWhitelist)
+	}
+	if blacklist == nil {
+		blacklist = ip.CompileIPRules(apiKey.IPBlacklist)
+	}
+	allowed, _ := ip.CheckIPRestrictionWithCompiledRules(ip.GetTrustedClientIP(c), whitelist, blacklist)
+	return allowed
+}
+
+// extractSigV4AccessKeyID 从 "AWS4-HMAC-SHA256 Credential=<AccessKeyID>/<date>/<region>/<service>/aws4_request, ..."
+// 中提取 Access Key ID。签名本身不校验：网关没有对应的 Secret，Access Key ID 即 API Key，等同 Bearer 鉴权。
+func extractSigV4AccessKeyID(authHeader string) string {
+	rest, ok := strings.CutPrefix(strings.TrimSpace(authHeader), "AWS4-HMAC-SHA256 ")
+	if !ok {
+		return ""
+	}
+	for _, field := range strings.Split(rest, ",") {
+		credential, ok := strings.CutPrefix(strings.TrimSpace(field), "Credential=")
+		if !ok {
+			continue
+		}
+		accessKeyID, _, _ := strings.Cut(credential, "/")
+		return strings.TrimSpace(accessKeyID)
+	}
+	return ""
+}
+
+// realtimeAPIKeySubprotocolPrefix OpenAI Realtime 浏览器客户端携带 API Key 的子协议前缀。
+const realtimeAPIKeySubprotocolPrefix = "openai-insecure-api-key."
+
+// extractRealtimeSubprotocolAPIKey 从 "realtime, openai-insecure-api-key.<key>, ..." 中提取 API Key。
+func extractRealtimeSubprotocolAPIKey(header string) string {
+	for _, protocol := range strings.Split(header, ",") {
+		if key, ok := strings.CutPrefix(strings.TrimSpace(protocol), realtimeAPIKeySubprotocolPrefix); ok {
+			return strings.TrimSpace(key)
+		}
+	}
+	return ""
+}
+
+// setSimulationContext 演练请求（gateway.simulation 或 Key 开启 simulate）标记 context，上游调用改由内置模拟上游应答，
+// 并通过响应头告知客户端本次响应为模拟数据
+func setSimulationContext(c *gin.Context, cfg *config.Config, apiKey *service.APIKey) {
+	if !service.ShouldSimulate(cfg, apiKey) {
+		return
+	}
+	c.Request = c.Request.WithContext(service.WithSimulation(c.Request.Context()))
+	c.Header("X-Sub2API-Simulated", "true")
+}
@@ -6,9 +6,11 @@ import (
 
 	"github.com/ShaohongDong/sub2api/internal/config"
 	"github.com/ShaohongDong/sub2api/internal/pkg/googleapi"
+	"github.com/ShaohongDong/sub2api/internal/pkg/tracing"
 	"github.com/ShaohongDong/sub2api/internal/service"
 
 	"github.com/gin-gonic/gin"
+	"go.opentelemetry.io/otel/attribute"
 )
 
 // APIKeyAuthGoogle is a Google-style error wrapper for API key auth.
@@ -22,6 +24,9 @@ func APIKeyAuthGoogle(apiKeyService *service.APIKeyService, cfg *config.Config)
 // It is intended for Gemini native endpoints (/v1beta) to match Gemini SDK expectations.
 func APIKeyAuthWithSubscriptionGoogle(apiKeyService *service.APIKeyService, subscriptionService *service.SubscriptionService, cfg *config.Config) gin.HandlerFunc {
 	return func(c *gin.Context) {
+		authCtx, authSpan := tracing.Start(c.Request.Context(), "auth.api_key")
+		defer endAuthSpan(c, authSpan)
+
 		if v := strings.TrimSpace(c.Query("api_key")); v != "" {
 			abortWithGoogleError(c, 400, "Query parameter api_key is deprecated. Use Authorization header or key instead.")
 			return
@@ -32,15 +37,17 @@ func APIKeyAuthWithSubscriptionGoogle(apiKeyService *service.APIKeyService, subs
 			return
 		}
 
-		apiKey, err := apiKeyService.GetByKey(c.Request.Context(), apiKeyString)
+		apiKey, err := apiKeyService.GetByKey(authCtx, apiKeyString)
 		if err != nil {
 			if errors.Is(err, service.ErrAPIKeyNotFound) {
 				abortWithGoogleError(c, 401, "Invalid API key")
 				return
 			}
+			authSpan.RecordError(err)
 			abortWithGoogleError(c, 500, "Failed to validate API key")
 			return
 		}
+		authSpan.SetAttributes(attribute.Int64("sub2api.api_key_id", apiKey.ID))
 
 		if !apiKey.IsActive() {
 			abortWithGoogleError(c, 401, "API key is disabled")
@@ -54,6 +61,22 @@ func APIKeyAuthWithSubscriptionGoogle(apiKeyService *service.APIKeyService, subs
 			abortWithGoogleError(c, 401, "User account is not active")
 			return
 		}
+		if !apiKeyAllowsClientIP(c, apiKey) {
+			abortWithGoogleError(c, 403, "Access denied")
+			return
+		}
+		if apiKey.Group != nil && !service.TenantMatches(apiKey.User.TenantID, apiKey.Group.TenantID) {
+			abortWithGoogleError(c, 403, "API key group belongs to a different tenant")
+			return
+		}
+		if !apiKeyAllowsRequest(apiKey, c.Request) {
+			abortWithGoogleError(c, 403, "API key is not allowed to access this endpoint")
+			return
+		}
+		if !apiKeyAllowsRequestClient(c, apiKey) {
+			abortWithGoogleError(c, 403, "API key is not allowed to be used from this client")
+			return
+		}
 
 		// 简易模式：跳过余额和订阅检查
 		if cfg.RunMode == config.RunModeSimple {
@@ -64,7 +87,9 @@ func APIKeyAuthWithSubscriptionGoogle(apiKeyService *service.APIKeyService, subs
 			})
 			c.Set(string(ContextKeyUserRole), apiKey.User.Role)
 			setGroupContext(c, apiKey.Group)
+			setSimulationContext(c, cfg, apiKey)
 			_ = apiKeyService.TouchLastUsed(c.Request.Context(), apiKey.ID)
+			endAuthSpan(c, authSpan)
 			c.Next()
 			return
 		}
@@ -72,7 +97,7 @@ func APIKeyAuthWithSubscriptionGoogle(apiKeyService *service.APIKeyService, subs
 		isSubscriptionType := apiKey.Group != nil && apiKey.Group.IsSubscriptionType()
 		if isSubscriptionType && subscriptionService != nil {
 			subscription, err := subscriptionService.GetActiveSubscription(
-				c.Request.Context(),
+				authCtx,
 				apiKey.User.ID,
 				apiKey.Group.ID,
 			)
@@ -106,6 +131,13 @@ func APIKeyAuthWithSubscriptionGoogle(apiKeyService *service.APIKeyService, subs
 			}
 		}
 
+		if !checkAPIKeyBudget(c, apiKey, abortWithGoogleBudgetError) {
+			return
+		}
+		if !checkAPIKeyRequestLimits(c, apiKeyService, apiKey, abortWithGoogleRateLimitError) {
+			return
+		}
+
 		c.Set(string(ContextKeyAPIKey), apiKey)
 		c.Set(string(ContextKeyUser), AuthSubject{
 			UserID:      apiKey.User.ID,
@@ -113,7 +145,9 @@ func APIKeyAuthWithSubscriptionGoogle(apiKeyService *service.APIKeyService, subs
 		})
 		c.Set(string(ContextKeyUserRole), apiKey.User.Role)
 		setGroupContext(c, apiKey.Group)
+		setSimulationContext(c, cfg, apiKey)
 		_ = apiKeyService.TouchLastUsed(c.Request.Context(), apiKey.ID)
+		endAuthSpan(c, authSpan)
 		c.Next()
 	}
 }
@@ -89,6 +89,9 @@ func (f fakeAPIKeyRepo) ListKeysByGroupID(ctx context.Context, groupID int64) ([
 func (f fakeAPIKeyRepo) IncrementQuotaUsed(ctx context.Context, id int64, amount float64) (float64, error) {
 	return 0, errors.New("not implemented")
 }
+func (f fakeAPIKeyRepo) IncrementImageQuotaUsed(ctx context.Context, id int64, count int) (int, error) {
+	return 0, errors.New("not implemented")
+}
 func (f fakeAPIKeyRepo) UpdateLastUsed(ctx context.Context, id int64, usedAt time.Time) error {
 	if f.updateLastUsed != nil {
 		return f.updateLastUsed(ctx, id, usedAt)
@@ -104,6 +107,16 @@ func (f fakeAPIKeyRepo) ResetRateLimitWindows(ctx context.Context, id int64) err
 func (f fakeAPIKeyRepo) GetRateLimitData(ctx context.Context, id int64) (*service.APIKeyRateLimitData, error) {
 	return &service.APIKeyRateLimitData{}, nil
 }
+func (f fakeAPIKeyRepo) IncrementBudgetUsed(ctx context.Context, id int64, amount float64, periodStart time.Time) (float64, error) {
+	return 0, errors.New("not implemented")
+}
+func (f fakeAPIKeyRepo) MarkBudgetExceeded(ctx context.Context, id int64, periodStart, exceededAt time.Time) (bool, error) {
+	return false, errors.New("not implemented")
+}
+
+func (f fakeAPIKeyRepo) RotateKey(ctx context.Context, id int64, oldKey, newKey string, graceUntil *time.Time) error {
+	return errors.New("not implemented")
+}
 
 func (f fakeGoogleSubscriptionRepo) Create(ctx context.Context, sub *service.UserSubscription) error {
 	return errors.New("not implemented")
@@ -428,6 +441,50 @@ func TestApiKeyAuthWithSubscriptionGoogle_DisabledKey(t *testing.T) {
 	require.Equal(t, "UNAUTHENTICATED", resp.Error.Status)
 }
 
+func TestApiKeyAuthWithSubscriptionGoogle_EnforcesIPWhitelistBehindTrustedProxy(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	r := gin.New()
+	require.NoError(t, r.SetTrustedProxies([]string{"10.0.0.0/8"}))
+	apiKeyService := newTestAPIKeyService(fakeAPIKeyRepo{
+		getByKey: func(ctx context.Context, key string) (*service.APIKey, error) {
+			return &service.APIKey{
+				ID:          1,
+				Key:         key,
+				Status:      service.StatusActive,
+				IPWhitelist: []string{"1.2.3.0/24"},
+				User: &service.User{
+					ID:     123,
+					Status: service.StatusActive,
+				},
+			}, nil
+		},
+	})
+	r.Use(APIKeyAuthWithSubscriptionGoogle(apiKeyService, nil, &config.Config{RunMode: config.RunModeSimple}))
+	r.GET("/v1beta/test", func(c *gin.Context) { c.JSON(200, gin.H{"ok": true}) })
+
+	cases := []struct {
+		remoteAddr string
+		xff        string
+		want       int
+	}{
+		{"10.0.0.5:1234", "1.2.3.4", http.StatusOK},
+		// 客户端自带伪造的 XFF，经可信代理追加真实 IP 后取最右侧非可信地址
+		{"10.0.0.5:1234", "1.2.3.4, 5.6.7.8", http.StatusForbidden},
+		// 非可信来源的 XFF 不被采信
+		{"9.9.9.9:1234", "1.2.3.4", http.StatusForbidden},
+	}
+	for _, tc := range cases {
+		req := httptest.NewRequest(http.MethodGet, "/v1beta/test", nil)
+		req.RemoteAddr = tc.remoteAddr
+		req.Header.Set("Authorization", "Bearer k")
+		req.Header.Set("X-Forwarded-For", tc.xff)
+		rec := httptest.NewRecorder()
+		r.ServeHTTP(rec, req)
+		require.Equal(t, tc.want, rec.Code, "remote=%s xff=%s", tc.remoteAddr, tc.xff)
+	}
+}
+
 func TestApiKeyAuthWithSubscriptionGoogle_InsufficientBalance(t *testing.T) {
 	gin.SetMode(gin.TestMode)
 
@@ -122,6 +122,48 @@ func TestSimpleModeBypassesQuotaCheck(t *testing.T) {
 		require.Equal(t, http.StatusOK, w.Code)
 	})
 
+	t.Run("simple_mode_accepts_azure_api_key_header", func(t *testing.T) {
+		cfg := &config.Config{RunMode: config.RunModeSimple}
+		apiKeyService := service.NewAPIKeyService(apiKeyRepo, nil, nil, nil, nil, nil, cfg)
+		subscriptionService := service.NewSubscriptionService(nil, &stubUserSubscriptionRepo{}, nil, nil, cfg)
+		router := newAuthTestRouter(apiKeyService, subscriptionService, cfg)
+
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodGet, "/t", nil)
+		req.Header.Set("api-key", apiKey.Key)
+		router.ServeHTTP(w, req)
+
+		require.Equal(t, http.StatusOK, w.Code)
+	})
+
+	t.Run("simple_mode_accepts_sigv4_access_key_id", func(t *testing.T) {
+		cfg := &config.Config{RunMode: config.RunModeSimple}
+		apiKeyService := service.NewAPIKeyService(apiKeyRepo, nil, nil, nil, nil, nil, cfg)
+		subscriptionService := service.NewSubscriptionService(nil, &stubUserSubscriptionRepo{}, nil, nil, cfg)
+		router := newAuthTestRouter(apiKeyService, subscriptionService, cfg)
+
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodGet, "/t", nil)
+		req.Header.Set("Authorization", "AWS4-HMAC-SHA256 Credential="+apiKey.Key+"/20260101/us-east-1/bedrock/aws4_request, SignedHeaders=host;x-amz-date, Signature=abc")
+		router.ServeHTTP(w, req)
+
+		require.Equal(t, http.StatusOK, w.Code)
+	})
+
+	t.Run("simple_mode_accepts_realtime_subprotocol_key", func(t *testing.T) {
+		cfg := &config.Config{RunMode: config.RunModeSimple}
+		apiKeyService := service.NewAPIKeyService(apiKeyRepo, nil, nil, nil, nil, nil, cfg)
+		subscriptionService := service.NewSubscriptionService(nil, &stubUserSubscriptionRepo{}, nil, nil, cfg)
+		router := newAuthTestRouter(apiKeyService, subscriptionService, cfg)
+
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodGet, "/t", nil)
+		req.Header.Set("Sec-WebSocket-Protocol", "realtime, openai-insecure-api-key."+apiKey.Key+", openai-beta.realtime-v1")
+		router.ServeHTTP(w, req)
+
+		require.Equal(t, http.StatusOK, w.Code)
+	})
+
 	t.Run("simple_mode_accepts_lowercase_bearer", func(t *testing.T) {
 		cfg := &config.Config{RunMode: config.RunModeSimple}
 		apiKeyService := service.NewAPIKeyService(apiKeyRepo, nil, nil, nil, nil, nil, cfg)
@@ -235,6 +277,57 @@ func TestAPIKeyAuthSetsGroupContext(t *testing.T) {
 	require.Equal(t, http.StatusOK, w.Code)
 }
 
+func TestAPIKeyAuthMarksSimulatedRequests(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	user := &service.User{ID: 7, Role: service.RoleUser, Status: service.StatusActive, Balance: 10, Concurrency: 3}
+	keys := map[string]*service.APIKey{
+		"live-key": {ID: 100, UserID: user.ID, Key: "live-key", Status: service.StatusActive, User: user},
+		"sim-key":  {ID: 101, UserID: user.ID, Key: "sim-key", Status: service.StatusActive, User: user, Simulate: true},
+	}
+	apiKeyRepo := &stubApiKeyRepo{
+		getByKey: func(ctx context.Context, key string) (*service.APIKey, error) {
+			apiKey, ok := keys[key]
+			if !ok {
+				return nil, service.ErrAPIKeyNotFound
+			}
+			clone := *apiKey
+			return &clone, nil
+		},
+	}
+
+	run := func(cfg *config.Config, key string) (bool, string) {
+		apiKeyService := service.NewAPIKeyService(apiKeyRepo, nil, nil, nil, nil, nil, cfg)
+		router := gin.New()
+		router.Use(gin.HandlerFunc(NewAPIKeyAuthMiddleware(apiKeyService, nil, cfg)))
+		simulated := false
+		router.GET("/t", func(c *gin.Context) {
+			simulated = service.IsSimulatedRequest(c.Request.Context())
+			c.Status(http.StatusOK)
+		})
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodGet, "/t", nil)
+		req.Header.Set("x-api-key", key)
+		router.ServeHTTP(w, req)
+		require.Equal(t, http.StatusOK, w.Code)
+		return simulated, w.Header().Get("X-Sub2API-Simulated")
+	}
+
+	cfg := &config.Config{RunMode: config.RunModeSimple}
+	simulated, header := run(cfg, "live-key")
+	require.False(t, simulated)
+	require.Empty(t, header)
+
+	simulated, header = run(cfg, "sim-key")
+	require.True(t, simulated)
+	require.Equal(t, "true", header)
+
+	global := &config.Config{RunMode: config.RunModeSimple}
+	global.Gateway.Simulation.Enabled = true
+	simulated, _ = run(global, "live-key")
+	require.True(t, simulated)
+}
+
 func TestAPIKeyAuthOverwritesInvalidContextGroup(t *testing.T) {
 	gin.SetMode(gin.TestMode)
 
@@ -351,6 +444,124 @@ func TestAPIKeyAuthIPRestrictionDoesNotTrustSpoofedForwardHeaders(t *testing.T)
 	require.Contains(t, w.Body.String(), "ACCESS_DENIED")
 }
 
+func TestAPIKeyAuthEnforcesScopes(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	user := &service.User{
+		ID:          7,
+		Role:        service.RoleUser,
+		Status:      service.StatusActive,
+		Balance:     10,
+		Concurrency: 3,
+	}
+	apiKey := &service.APIKey{
+		ID:     100,
+		UserID: user.ID,
+		Key:    "test-key",
+		Status: service.StatusActive,
+		User:   user,
+		Scopes: []string{service.APIKeyScopeMessages},
+	}
+
+	apiKeyRepo := &stubApiKeyRepo{
+		getByKey: func(ctx context.Context, key string) (*service.APIKey, error) {
+			if key != apiKey.Key {
+				return nil, service.ErrAPIKeyNotFound
+			}
+			clone := *apiKey
+			return &clone, nil
+		},
+	}
+
+	cfg := &config.Config{RunMode: config.RunModeSimple}
+	apiKeyService := service.NewAPIKeyService(apiKeyRepo, nil, nil, nil, nil, nil, cfg)
+	router := gin.New()
+	router.Use(gin.HandlerFunc(NewAPIKeyAuthMiddleware(apiKeyService, nil, cfg)))
+	ok := func(c *gin.Context) { c.JSON(http.StatusOK, gin.H{"ok": true}) }
+	router.POST("/v1/messages", ok)
+	router.POST("/v1/chat/completions", ok)
+	router.GET("/v1/models", ok)
+
+	cases := []struct {
+		method string
+		path   string
+		want   int
+	}{
+		{http.MethodPost, "/v1/messages", http.StatusOK},
+		{http.MethodPost, "/v1/chat/completions", http.StatusForbidden},
+		{http.MethodGet, "/v1/models", http.StatusOK},
+	}
+	for _, tc := range cases {
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest(tc.method, tc.path, nil)
+		req.Header.Set("x-api-key", apiKey.Key)
+		router.ServeHTTP(w, req)
+
+		require.Equal(t, tc.want, w.Code, tc.path)
+		if tc.want == http.StatusForbidden {
+			require.Contains(t, w.Body.String(), "API_KEY_SCOPE_DENIED")
+		}
+	}
+}
+
+func TestAPIKeyAuthEnforcesClientRestriction(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	user := &service.User{
+		ID:          7,
+		Role:        service.RoleUser,
+		Status:      service.StatusActive,
+		Balance:     10,
+		Concurrency: 3,
+	}
+	apiKey := &service.APIKey{
+		ID:                101,
+		UserID:            user.ID,
+		Key:               "codex-only-key",
+		Status:            service.StatusActive,
+		User:              user,
+		ClientRestriction: service.APIKeyClientRestrictionCodex,
+	}
+
+	apiKeyRepo := &stubApiKeyRepo{
+		getByKey: func(ctx context.Context, key string) (*service.APIKey, error) {
+			if key != apiKey.Key {
+				return nil, service.ErrAPIKeyNotFound
+			}
+			clone := *apiKey
+			return &clone, nil
+		},
+	}
+
+	cfg := &config.Config{RunMode: config.RunModeSimple}
+	apiKeyService := service.NewAPIKeyService(apiKeyRepo, nil, nil, nil, nil, nil, cfg)
+	router := gin.New()
+	router.Use(ClientDetection())
+	router.Use(gin.HandlerFunc(NewAPIKeyAuthMiddleware(apiKeyService, nil, cfg)))
+	router.POST("/v1/responses", func(c *gin.Context) { c.JSON(http.StatusOK, gin.H{"ok": true}) })
+
+	cases := []struct {
+		userAgent string
+		want      int
+	}{
+		{"codex_cli_rs/0.46.0 (Mac OS 15.6.1; arm64) iTerm.app/3.6.1", http.StatusOK},
+		{"curl/8.4.0", http.StatusForbidden},
+		{"", http.StatusForbidden},
+	}
+	for _, tc := range cases {
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodPost, "/v1/responses", nil)
+		req.Header.Set("Authorization", "Bearer "+apiKey.Key)
+		req.Header.Set("User-Agent", tc.userAgent)
+		router.ServeHTTP(w, req)
+
+		require.Equal(t, tc.want, w.Code, tc.userAgent)
+		if tc.want == http.StatusForbidden {
+			require.Contains(t, w.Body.String(), "API_KEY_CLIENT_DENIED")
+		}
+	}
+}
+
 func TestAPIKeyAuthTouchesLastUsedOnSuccess(t *testing.T) {
 	gin.SetMode(gin.TestMode)
 
@@ -492,6 +703,166 @@ func TestAPIKeyAuthTouchesLastUsedInStandardMode(t *testing.T) {
 	require.Equal(t, 1, touchCalls)
 }
 
+// countingRequestLimitCache 按 (key, bucket) 计数的内存实现，用于验证限流判定
+type countingRequestLimitCache struct {
+	requests map[string]int64
+}
+
+func (c *countingRequestLimitCache) AcquireRequest(ctx context.Context, apiKeyID int64, buckets []service.APIKeyLimitBucket) (int, string, []service.APIKeyLimitUsage, error) {
+	for i, b := range buckets {
+		if b.RequestLimit > 0 && c.requests[b.Bucket] >= b.RequestLimit {
+			return i, service.APIKeyLimitDimensionRequests, nil, nil
+		}
+	}
+	usage := make([]service.APIKeyLimitUsage, len(buckets))
+	for i, b := range buckets {
+		usage[i].Requests = c.requests[b.Bucket]
+		c.requests[b.Bucket]++
+	}
+	return -1, "", usage, nil
+}
+
+func (c *countingRequestLimitCache) AddTokens(ctx context.Context, apiKeyID int64, buckets []service.APIKeyLimitBucket, tokens int64) error {
+	return nil
+}
+
+func TestAPIKeyAuthEnforcesRequestLimits(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	user := &service.User{
+		ID:          11,
+		Role:        service.RoleUser,
+		Status:      service.StatusActive,
+		Balance:     10,
+		Concurrency: 3,
+	}
+	apiKey := &service.APIKey{
+		ID:       104,
+		UserID:   user.ID,
+		Key:      "rpm-limited",
+		Status:   service.StatusActive,
+		User:     user,
+		RPMLimit: 2,
+	}
+
+	apiKeyRepo := &stubApiKeyRepo{
+		getByKey: func(ctx context.Context, key string) (*service.APIKey, error) {
+			if key != apiKey.Key {
+				return nil, service.ErrAPIKeyNotFound
+			}
+			clone := *apiKey
+			return &clone, nil
+		},
+	}
+
+	cfg := &config.Config{RunMode: config.RunModeStandard}
+	apiKeyService := service.NewAPIKeyService(apiKeyRepo, nil, nil, nil, nil, nil, cfg)
+	apiKeyService.SetRequestLimitCache(&countingRequestLimitCache{requests: map[string]int64{}})
+	router := newAuthTestRouter(apiKeyService, nil, cfg)
+
+	codes := make([]int, 0, 3)
+	recorders := make([]*httptest.ResponseRecorder, 0, 3)
+	var last *httptest.ResponseRecorder
+	for i := 0; i < 3; i++ {
+		last = httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodGet, "/t", nil)
+		req.Header.Set("x-api-key", apiKey.Key)
+		router.ServeHTTP(last, req)
+		codes = append(codes, last.Code)
+		recorders = append(recorders, last)
+	}
+
+	require.Equal(t, []int{http.StatusOK, http.StatusOK, http.StatusTooManyRequests}, codes)
+	require.Equal(t, "2", recorders[0].Header().Get("x-ratelimit-limit-requests"))
+	require.Equal(t, "1", recorders[0].Header().Get("x-ratelimit-remaining-requests"))
+	require.NotEmpty(t, recorders[0].Header().Get("x-ratelimit-reset-requests"))
+	require.Empty(t, recorders[0].Header().Get("Retry-After"))
+	require.Equal(t, "0", recorders[1].Header().Get("x-ratelimit-remaining-requests"))
+	require.NotEmpty(t, recorders[1].Header().Get("Retry-After"))
+	require.Empty(t, recorders[1].Header().Get("x-ratelimit-limit-tokens"))
+	require.NotEmpty(t, last.Header().Get("Retry-After"))
+	require.Contains(t, last.Body.String(), `"code":"rate_limit_exceeded"`)
+	require.Contains(t, last.Body.String(), `"type":"requests"`)
+}
+
+func TestSetAPIKeyRateLimitHeadersOverridesUpstream(t *testing.T) {
+	h := http.Header{}
+	h.Set("x-ratelimit-remaining-requests", "4999")
+	h.Set("Retry-After", "120")
+	setAPIKeyRateLimitHeaders(h, &service.APIKeyRateLimitStatus{
+		RequestLimit:      60,
+		RemainingRequests: 0,
+		RequestsReset:     1500 * time.Millisecond,
+		TokenLimit:        1000,
+		RemainingTokens:   250,
+		TokensReset:       30 * time.Second,
+	})
+	require.Equal(t, []string{"0"}, h.Values("x-ratelimit-remaining-requests"))
+	require.Equal(t, "2s", h.Get("x-ratelimit-reset-requests"))
+	require.Equal(t, "250", h.Get("x-ratelimit-remaining-tokens"))
+	require.Equal(t, "30s", h.Get("x-ratelimit-reset-tokens"))
+	require.Equal(t, "120", h.Get("Retry-After"), "longer upstream Retry-After is kept")
+}
+
+func TestAPIKeyAuthRejectsCrossTenantGroup(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	tenantA, tenantB := int64(1), int64(2)
+	user := &service.User{
+		ID:          12,
+		Role:        service.RoleUser,
+		Status:      service.StatusActive,
+		Balance:     10,
+		Concurrency: 3,
+		TenantID:    &tenantA,
+	}
+	group := &service.Group{
+		ID:       21,
+		Name:     "team-b",
+		Platform: service.PlatformAnthropic,
+		Status:   service.StatusActive,
+		Hydrated: true,
+		TenantID: &tenantB,
+	}
+	apiKey := &service.APIKey{
+		ID:      105,
+		UserID:  user.ID,
+		Key:     "cross-tenant",
+		Status:  service.StatusActive,
+		User:    user,
+		GroupID: &group.ID,
+		Group:   group,
+	}
+
+	apiKeyRepo := &stubApiKeyRepo{
+		getByKey: func(ctx context.Context, key string) (*service.APIKey, error) {
+			if key != apiKey.Key {
+				return nil, service.ErrAPIKeyNotFound
+			}
+			clone := *apiKey
+			return &clone, nil
+		},
+	}
+
+	cfg := &config.Config{RunMode: config.RunModeSimple}
+	apiKeyService := service.NewAPIKeyService(apiKeyRepo, nil, nil, nil, nil, nil, cfg)
+	router := newAuthTestRouter(apiKeyService, nil, cfg)
+
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodGet, "/t", nil)
+	req.Header.Set("x-api-key", apiKey.Key)
+	router.ServeHTTP(w, req)
+	require.Equal(t, http.StatusForbidden, w.Code)
+	require.Contains(t, w.Body.String(), "TENANT_MISMATCH")
+
+	group.TenantID = &tenantA
+	w = httptest.NewRecorder()
+	req = httptest.NewRequest(http.MethodGet, "/t", nil)
+	req.Header.Set("x-api-key", apiKey.Key)
+	router.ServeHTTP(w, req)
+	require.Equal(t, http.StatusOK, w.Code)
+}
+
 func newAuthTestRouter(apiKeyService *service.APIKeyService, subscriptionService *service.SubscriptionService, cfg *config.Config) *gin.Engine {
 	router := gin.New()
 	router.Use(gin.HandlerFunc(NewAPIKeyAuthMiddleware(apiKeyService, subscriptionService, cfg)))
@@ -581,6 +952,10 @@ func (r *stubApiKeyRepo) IncrementQuotaUsed(ctx context.Context, id int64, amoun
 	return 0, errors.New("not implemented")
 }
 
+func (r *stubApiKeyRepo) IncrementImageQuotaUsed(ctx context.Context, id int64, count int) (int, error) {
+	return 0, errors.New("not implemented")
+}
+
 func (r *stubApiKeyRepo) UpdateLastUsed(ctx context.Context, id int64, usedAt time.Time) error {
 	if r.updateLastUsed != nil {
 		return r.updateLastUsed(ctx, id, usedAt)
@@ -598,6 +973,18 @@ func (r *stubApiKeyRepo) GetRateLimitData(ctx context.Context, id int64) (*servi
 	return nil, nil
 }
 
+func (r *stubApiKeyRepo) IncrementBudgetUsed(ctx context.Context, id int64, amount float64, periodStart time.Time) (float64, error) {
+	return 0, errors.New("not implemented")
+}
+
+func (r *stubApiKeyRepo) MarkBudgetExceeded(ctx context.Context, id int64, periodStart, exceededAt time.Time) (bool, error) {
+	return false, errors.New("not implemented")
+}
+
+func (r *stubApiKeyRepo) RotateKey(ctx context.Context, id int64, oldKey, newKey string, graceUntil *time.Time) error {
+	return errors.New("not implemented")
+}
+
 type stubUserSubscriptionRepo struct {
 	getActive      func(ctx context.Context, userID, groupID int64) (*service.UserSubscription, error)
 	updateStatus   func(ctx context.Context, subscriptionID int64, status string) error
@@ -0,0 +1,107 @@
+package middleware
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/ShaohongDong/sub2api/internal/pkg/timezone"
+	"github.com/ShaohongDong/sub2api/internal/service"
+
+	"github.com/gin-gonic/gin"
+	"github.com/tidwall/gjson"
+	"github.com/tidwall/sjson"
+)
+
+// checkAPIKeyBudget 执行 Key 级花费预算检查；预算未超限时直接放行。
+// 超限且处理方式为 downgrade 时把请求的模型替换为降级模型后放行；
+// 处理方式为 disable 或无法改写模型时写入 429 并返回 false。
+// writeError 负责按入口协议（OpenAI 风格 / Google 风格）输出错误体。
+func checkAPIKeyBudget(c *gin.Context, apiKey *service.APIKey, writeError func(c *gin.Context, message string)) bool {
+	now := timezone.Now()
+	if !apiKey.IsBudgetExceeded(now) {
+		return true
+	}
+	if apiKey.BudgetAction == service.APIKeyBudgetActionDowngrade && rewriteRequestModel(c, apiKey.BudgetFallbackModel) {
+		c.Header("X-Sub2API-Budget-Downgraded", apiKey.BudgetFallbackModel)
+		return true
+	}
+	resetAt := service.APIKeyBudgetResetAt(apiKey.BudgetPeriod, now)
+	retryAfter := int64(resetAt.Sub(now).Seconds()) + 1
+	c.Header("Retry-After", strconv.FormatInt(retryAfter, 10))
+	writeError(c, "API key spend budget exceeded for the current "+apiKey.BudgetPeriod)
+	return false
+}
+
+// rewriteRequestModel 将请求的模型替换为 model：
+//   - JSON 请求体顶层的 "model" 字段（OpenAI / Anthropic 风格）
+//   - Gemini 风格路径参数 /models/{model}:{action}
+//
+// 不携带模型的只读请求（如 GET /v1/models）原样放行；其余无法确定模型的请求返回 false。
+// Azure 风格路由的模型由 deployment 决定，后续中间件会覆盖请求体，因此同样返回 false。
+func rewriteRequestModel(c *gin.Context, model string) bool {
+	if model == "" || c.Param("deployment") != "" {
+		return false
+	}
+	if modelAction := c.Param("modelAction"); modelAction != "" {
+		return rewriteGeminiModelActionParam(c, modelAction, model)
+	}
+	switch c.Request.Method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions:
+		return true
+	}
+	if c.Request.Body == nil {
+		return false
+	}
+	body, err := io.ReadAll(c.Request.Body)
+	_ = c.Request.Body.Close()
+	if err != nil {
+		c.Request.Body = io.NopCloser(bytes.NewReader(body))
+		return false
+	}
+	restore := func(b []byte) {
+		c.Request.Body = io.NopCloser(bytes.NewReader(b))
+		c.Request.ContentLength = int64(len(b))
+		c.Request.Header.Set("Content-Length", strconv.Itoa(len(b)))
+	}
+	if current := gjson.GetBytes(body, "model"); current.Type != gjson.String {
+		restore(body)
+		return false
+	}
+	rewritten, err := sjson.SetBytes(body, "model", model)
+	if err != nil {
+		restore(body)
+		return false
+	}
+	restore(rewritten)
+	return true
+}
+
+// rewriteGeminiModelActionParam 改写 "/{model}:{action}" 形式的路径参数；其他格式（如 Bedrock 路径）不处理
+func rewriteGeminiModelActionParam(c *gin.Context, modelAction, model string) bool {
+	trimmed := strings.TrimPrefix(modelAction, "/")
+	idx := strings.LastIndex(trimmed, ":")
+	if idx <= 0 || strings.Contains(trimmed[:idx], "/") {
+		return false
+	}
+	rewritten := "/" + model + trimmed[idx:]
+	for i := range c.Params {
+		if c.Params[i].Key == "modelAction" {
+			c.Params[i].Value = rewritten
+			return true
+		}
+	}
+	return false
+}
+
+// abortWithOpenAIBudgetError 输出预算超限错误
+func abortWithOpenAIBudgetError(c *gin.Context, message string) {
+	AbortWithError(c, http.StatusTooManyRequests, "API_KEY_BUDGET_EXCEEDED", message)
+}
+
+// abortWithGoogleBudgetError 输出 Gemini 兼容的预算超限错误体
+func abortWithGoogleBudgetError(c *gin.Context, message string) {
+	abortWithGoogleError(c, http.StatusTooManyRequests, message)
+}
@@ -0,0 +1,107 @@
+//go:build unit
+
+package middleware
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ShaohongDong/sub2api/internal/pkg/timezone"
+	"github.com/ShaohongDong/sub2api/internal/service"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/require"
+)
+
+func exceededBudgetKey(action, fallback string) *service.APIKey {
+	now := timezone.Now()
+	start := service.APIKeyBudgetPeriodStart(service.APIKeyBudgetPeriodDay, now)
+	exceededAt := now
+	return &service.APIKey{
+		ID:                  1,
+		BudgetAmount:        1,
+		BudgetPeriod:        service.APIKeyBudgetPeriodDay,
+		BudgetAction:        action,
+		BudgetFallbackModel: fallback,
+		BudgetUsed:          2,
+		BudgetPeriodStart:   &start,
+		BudgetExceededAt:    &exceededAt,
+	}
+}
+
+func newBudgetTestRouter(apiKey *service.APIKey, path string, writeError func(c *gin.Context, message string)) (*gin.Engine, *string, *string) {
+	gin.SetMode(gin.TestMode)
+	var gotBody, gotParam string
+	r := gin.New()
+	r.POST(path, func(c *gin.Context) {
+		if !checkAPIKeyBudget(c, apiKey, writeError) {
+			return
+		}
+		body, _ := io.ReadAll(c.Request.Body)
+		gotBody = string(body)
+		gotParam = c.Param("modelAction")
+		c.Status(http.StatusOK)
+	})
+	return r, &gotBody, &gotParam
+}
+
+func TestCheckAPIKeyBudgetDisableRejects(t *testing.T) {
+	router, _, _ := newBudgetTestRouter(exceededBudgetKey(service.APIKeyBudgetActionDisable, ""), "/v1/messages", abortWithOpenAIBudgetError)
+
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodPost, "/v1/messages", strings.NewReader(`{"model":"claude-opus-4"}`))
+	router.ServeHTTP(w, req)
+
+	require.Equal(t, http.StatusTooManyRequests, w.Code)
+	require.Contains(t, w.Body.String(), "API_KEY_BUDGET_EXCEEDED")
+	require.NotEmpty(t, w.Header().Get("Retry-After"))
+}
+
+func TestCheckAPIKeyBudgetDowngradeRewritesBodyModel(t *testing.T) {
+	router, gotBody, _ := newBudgetTestRouter(exceededBudgetKey(service.APIKeyBudgetActionDowngrade, "claude-haiku-4-5"), "/v1/messages", abortWithOpenAIBudgetError)
+
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodPost, "/v1/messages", strings.NewReader(`{"model":"claude-opus-4","max_tokens":16}`))
+	router.ServeHTTP(w, req)
+
+	require.Equal(t, http.StatusOK, w.Code)
+	require.Equal(t, `{"model":"claude-haiku-4-5","max_tokens":16}`, *gotBody)
+	require.Equal(t, "claude-haiku-4-5", w.Header().Get("X-Sub2API-Budget-Downgraded"))
+}
+
+func TestCheckAPIKeyBudgetDowngradeRewritesGeminiPath(t *testing.T) {
+	router, _, gotParam := newBudgetTestRouter(exceededBudgetKey(service.APIKeyBudgetActionDowngrade, "gemini-2.5-flash"), "/v1beta/models/*modelAction", abortWithGoogleBudgetError)
+
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodPost, "/v1beta/models/gemini-2.5-pro:generateContent", strings.NewReader(`{}`))
+	router.ServeHTTP(w, req)
+
+	require.Equal(t, http.StatusOK, w.Code)
+	require.Equal(t, "/gemini-2.5-flash:generateContent", *gotParam)
+}
+
+func TestCheckAPIKeyBudgetDowngradeWithoutModelRejects(t *testing.T) {
+	router, _, _ := newBudgetTestRouter(exceededBudgetKey(service.APIKeyBudgetActionDowngrade, "claude-haiku-4-5"), "/v1/messages", abortWithOpenAIBudgetError)
+
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodPost, "/v1/messages", strings.NewReader(`{"input":"hi"}`))
+	router.ServeHTTP(w, req)
+
+	require.Equal(t, http.StatusTooManyRequests, w.Code)
+}
+
+func TestCheckAPIKeyBudgetPassesPreviousPeriodMark(t *testing.T) {
+	apiKey := exceededBudgetKey(service.APIKeyBudgetActionDisable, "")
+	yesterday := apiKey.BudgetPeriodStart.Add(-24 * time.Hour)
+	apiKey.BudgetPeriodStart = &yesterday
+	router, _, _ := newBudgetTestRouter(apiKey, "/v1/messages", abortWithOpenAIBudgetError)
+
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodPost, "/v1/messages", strings.NewReader(`{"model":"claude-opus-4"}`))
+	router.ServeHTTP(w, req)
+
+	require.Equal(t, http.StatusOK, w.Code)
+}
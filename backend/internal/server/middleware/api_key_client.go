@@ -0,0 +1,21 @@
+package middleware
+
+import (
+	"github.com/ShaohongDong/sub2api/internal/pkg/clientdetect"
+	"github.com/ShaohongDong/sub2api/internal/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+// apiKeyAllowsRequestClient 按 ClientDetection 的识别结果判断 Key 的客户端限制是否放行当前请求。
+// 路由未挂载 ClientDetection 时现场解析请求头，保证限制不会因中间件顺序失效。
+func apiKeyAllowsRequestClient(c *gin.Context, apiKey *service.APIKey) bool {
+	if apiKey == nil || apiKey.ClientRestriction == "" {
+		return true
+	}
+	info, ok := clientdetect.FromContext(c.Request.Context())
+	if !ok {
+		info = clientdetect.ParseHeaders(c.Request.Header)
+	}
+	return apiKey.AllowsClient(info)
+}
@@ -0,0 +1,120 @@
+package middleware
+
+import (
+	"errors"
+	"math"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/ShaohongDong/sub2api/internal/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+// checkAPIKeyRequestLimits 执行 Key 级 RPM/TPM 与日/月配额检查；超限时写入 429 并返回 false。
+// 放行时按计入后的额度在响应中输出 x-ratelimit-* 标准限流头。
+// writeError 负责按入口协议（OpenAI 风格 / Google 风格）输出错误体。
+func checkAPIKeyRequestLimits(c *gin.Context, apiKeyService *service.APIKeyService, apiKey *service.APIKey, writeError func(c *gin.Context, limitErr *service.APIKeyRequestLimitError)) bool {
+	status, err := apiKeyService.CheckRequestLimits(c.Request.Context(), apiKey)
+	if err == nil {
+		if status != nil {
+			c.Writer = &rateLimitHeaderWriter{ResponseWriter: c.Writer, status: status}
+		}
+		return true
+	}
+	var limitErr *service.APIKeyRequestLimitError
+	if !errors.As(err, &limitErr) {
+		return true
+	}
+	c.Header("Retry-After", limitErr.RetryAfterSeconds())
+	writeError(c, limitErr)
+	return false
+}
+
+// rateLimitHeaderWriter 在首次写出响应头前写入 Key 级限流头。
+// 透传的上游同名头反映的是共享账号的额度，对客户端没有意义，因此被覆盖。
+type rateLimitHeaderWriter struct {
+	gin.ResponseWriter
+	status  *service.APIKeyRateLimitStatus
+	applied bool
+}
+
+func (w *rateLimitHeaderWriter) apply() {
+	if w.applied || w.ResponseWriter.Written() {
+		return
+	}
+	w.applied = true
+	setAPIKeyRateLimitHeaders(w.Header(), w.status)
+}
+
+func (w *rateLimitHeaderWriter) WriteHeader(code int) {
+	w.apply()
+	w.ResponseWriter.WriteHeader(code)
+}
+
+func (w *rateLimitHeaderWriter) WriteHeaderNow() {
+	w.apply()
+	w.ResponseWriter.WriteHeaderNow()
+}
+
+func (w *rateLimitHeaderWriter) Write(b []byte) (int, error) {
+	w.apply()
+	return w.ResponseWriter.Write(b)
+}
+
+func (w *rateLimitHeaderWriter) WriteString(s string) (int, error) {
+	w.apply()
+	return w.ResponseWriter.WriteString(s)
+}
+
+func (w *rateLimitHeaderWriter) Flush() {
+	w.apply()
+	w.ResponseWriter.Flush()
+}
+
+// setAPIKeyRateLimitHeaders 按 OpenAI 约定输出限流头（reset 为 Go duration 格式，如 "42s"）；
+// 任一维度已耗尽时附带 Retry-After（下一次请求将被拒绝），已有更长的 Retry-After（如上游限流）时保留
+func setAPIKeyRateLimitHeaders(h http.Header, status *service.APIKeyRateLimitStatus) {
+	if status.RequestLimit > 0 {
+		h.Set("x-ratelimit-limit-requests", strconv.FormatInt(status.RequestLimit, 10))
+		h.Set("x-ratelimit-remaining-requests", strconv.FormatInt(status.RemainingRequests, 10))
+		h.Set("x-ratelimit-reset-requests", formatRateLimitReset(status.RequestsReset))
+	}
+	if status.TokenLimit > 0 {
+		h.Set("x-ratelimit-limit-tokens", strconv.FormatInt(status.TokenLimit, 10))
+		h.Set("x-ratelimit-remaining-tokens", strconv.FormatInt(status.RemainingTokens, 10))
+		h.Set("x-ratelimit-reset-tokens", formatRateLimitReset(status.TokensReset))
+	}
+	if retry := status.RetryAfter(); retry > 0 {
+		secs := int64(math.Ceil(retry.Seconds()))
+		if existing, err := strconv.ParseInt(h.Get("Retry-After"), 10, 64); err != nil || existing < secs {
+			h.Set("Retry-After", strconv.FormatInt(secs, 10))
+		}
+	}
+}
+
+func formatRateLimitReset(d time.Duration) string {
+	if d < 0 {
+		d = 0
+	}
+	return d.Round(time.Second).String()
+}
+
+// abortWithOpenAIRateLimitError 输出 OpenAI 兼容的限流错误体
+func abortWithOpenAIRateLimitError(c *gin.Context, limitErr *service.APIKeyRequestLimitError) {
+	c.JSON(http.StatusTooManyRequests, gin.H{
+		"error": gin.H{
+			"message": limitErr.Error(),
+			"type":    limitErr.Dimension,
+			"param":   nil,
+			"code":    "rate_limit_exceeded",
+		},
+	})
+	c.Abort()
+}
+
+// abortWithGoogleRateLimitError 输出 Gemini 兼容的限流错误体
+func abortWithGoogleRateLimitError(c *gin.Context, limitErr *service.APIKeyRequestLimitError) {
+	abortWithGoogleError(c, http.StatusTooManyRequests, limitErr.Error())
+}
@@ -0,0 +1,72 @@
+package middleware
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/ShaohongDong/sub2api/internal/service"
+)
+
+// apiKeyScopeRoutes 网关路径（已去掉 /v1、/antigravity、/sora、Azure deployment 等前缀）到 scope 的映射
+var apiKeyScopeRoutes = []struct {
+	prefix string
+	scope  string
+}{
+	{"/messages", service.APIKeyScopeMessages},
+	{"/responses", service.APIKeyScopeResponses},
+	{"/chat/completions", service.APIKeyScopeChat},
+	{"/completions", service.APIKeyScopeChat},
+	{"/api/chat", service.APIKeyScopeChat},
+	{"/api/generate", service.APIKeyScopeChat},
+	{"/embeddings", service.APIKeyScopeEmbeddings},
+	{"/images", service.APIKeyScopeImages},
+	{"/audio", service.APIKeyScopeAudio},
+	{"/realtime", service.APIKeyScopeAudio},
+	{"/moderations", service.APIKeyScopeModerations},
+	{"/batches", service.APIKeyScopeBatches},
+	{"/files", service.APIKeyScopeBatches},
+}
+
+// apiKeyScopeForRequest 返回请求所需的 scope；模型列表、用量查询、媒体代理等只读端点返回空字符串（不受 scope 限制）
+func apiKeyScopeForRequest(method, path string) string {
+	path = strings.TrimPrefix(path, "/antigravity")
+	path = strings.TrimPrefix(path, "/sora")
+
+	// Gemini 原生 API：GET 为模型列表/详情，其余为推理请求
+	if path == "/v1beta" || strings.HasPrefix(path, "/v1beta/") {
+		if method == http.MethodGet {
+			return ""
+		}
+		return service.APIKeyScopeGemini
+	}
+	// Bedrock Runtime 风格路由复用 Messages
+	if strings.HasPrefix(path, "/model/") {
+		return service.APIKeyScopeMessages
+	}
+
+	path = strings.TrimPrefix(path, "/v1")
+	if rest, ok := strings.CutPrefix(path, "/openai/deployments/"); ok {
+		// Azure 风格：/openai/deployments/{deployment}/chat/completions
+		path = ""
+		if i := strings.IndexByte(rest, '/'); i >= 0 {
+			path = rest[i:]
+		}
+	} else {
+		path = strings.TrimPrefix(path, "/openai")
+	}
+
+	for _, route := range apiKeyScopeRoutes {
+		if path == route.prefix || strings.HasPrefix(path, route.prefix+"/") {
+			return route.scope
+		}
+	}
+	return ""
+}
+
+// apiKeyAllowsRequest 判断 Key 的 scope 是否覆盖当前请求
+func apiKeyAllowsRequest(apiKey *service.APIKey, r *http.Request) bool {
+	if apiKey == nil || len(apiKey.Scopes) == 0 || r == nil || r.URL == nil {
+		return true
+	}
+	return apiKey.AllowsScope(apiKeyScopeForRequest(r.Method, r.URL.Path))
+}
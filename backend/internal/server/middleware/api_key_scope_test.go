@@ -0,0 +1,49 @@
+//go:build unit
+
+package middleware
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/ShaohongDong/sub2api/internal/service"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAPIKeyScopeForRequest(t *testing.T) {
+	cases := []struct {
+		method string
+		path   string
+		want   string
+	}{
+		{http.MethodPost, "/v1/messages", service.APIKeyScopeMessages},
+		{http.MethodPost, "/v1/messages/count_tokens", service.APIKeyScopeMessages},
+		{http.MethodPost, "/antigravity/v1/messages", service.APIKeyScopeMessages},
+		{http.MethodPost, "/model/claude-sonnet/invoke", service.APIKeyScopeMessages},
+		{http.MethodPost, "/v1/responses", service.APIKeyScopeResponses},
+		{http.MethodGet, "/responses", service.APIKeyScopeResponses},
+		{http.MethodPost, "/openai/responses", service.APIKeyScopeResponses},
+		{http.MethodPost, "/v1/chat/completions", service.APIKeyScopeChat},
+		{http.MethodPost, "/chat/completions", service.APIKeyScopeChat},
+		{http.MethodPost, "/sora/v1/chat/completions", service.APIKeyScopeChat},
+		{http.MethodPost, "/openai/deployments/gpt-4o/chat/completions", service.APIKeyScopeChat},
+		{http.MethodPost, "/v1/completions", service.APIKeyScopeChat},
+		{http.MethodPost, "/api/chat", service.APIKeyScopeChat},
+		{http.MethodPost, "/openai/deployments/embed/embeddings", service.APIKeyScopeEmbeddings},
+		{http.MethodPost, "/v1/images/generations", service.APIKeyScopeImages},
+		{http.MethodPost, "/v1/audio/speech", service.APIKeyScopeAudio},
+		{http.MethodGet, "/v1/realtime", service.APIKeyScopeAudio},
+		{http.MethodPost, "/v1/moderations", service.APIKeyScopeModerations},
+		{http.MethodGet, "/v1/batches/batch_1", service.APIKeyScopeBatches},
+		{http.MethodPost, "/v1/files", service.APIKeyScopeBatches},
+		{http.MethodPost, "/v1beta/models/gemini-2.5-pro:generateContent", service.APIKeyScopeGemini},
+		{http.MethodGet, "/v1beta/models", ""},
+		{http.MethodGet, "/v1/models", ""},
+		{http.MethodGet, "/v1/usage", ""},
+		{http.MethodGet, "/api/tags", ""},
+		{http.MethodGet, "/sora/media/a.png", ""},
+	}
+	for _, tc := range cases {
+		require.Equal(t, tc.want, apiKeyScopeForRequest(tc.method, tc.path), "%s %s", tc.method, tc.path)
+	}
+}
@@ -0,0 +1,107 @@
+package middleware
+
+import (
+	"context"
+	"time"
+
+	"github.com/ShaohongDong/sub2api/internal/pkg/logger"
+	"github.com/ShaohongDong/sub2api/internal/service"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+// canarySource 提供金丝雀分流配置（由 SettingService 实现，带进程内缓存）
+type canarySource interface {
+	GetCanarySplits(ctx context.Context) *service.CanarySettings
+}
+
+// CanarySplit 按分流配置将命中模型的部分请求（Percent%）改由目标分组和/或目标模型处理，
+// 并分别记录 control / canary 两侧的请求数、错误数与耗时。
+// 目标分组不存在或不满足 service.CanRouteToGroup 时本次请求按 control 处理。
+// 必须位于 API Key 认证、模型别名与路由规则之后。
+func CanarySplit(source canarySource, resolver routingGroupResolver) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if source == nil || resolver == nil || c.Request == nil {
+			c.Next()
+			return
+		}
+		apiKey, ok := GetAPIKeyFromContext(c)
+		if !ok || apiKey.Group == nil {
+			c.Next()
+			return
+		}
+		settings := source.GetCanarySplits(c.Request.Context())
+		if settings == nil || !settings.Enabled || len(settings.Splits) == 0 {
+			c.Next()
+			return
+		}
+		model := requestModel(c)
+		split := service.MatchCanarySplit(settings, apiKey.Group.ID, model)
+		if split == nil {
+			c.Next()
+			return
+		}
+
+		canary := service.RollPercent(split.Percent) && applyCanary(c, resolver, apiKey, split, model)
+		start := time.Now()
+		if !canary {
+			c.Next()
+			service.RecordCanaryResult(split.Name, false, c.Writer.Status(), time.Since(start))
+			return
+		}
+
+		// canary 改写了模型且外层 ModelAlias 未改写时，把响应中的模型名改回客户端请求的名称
+		var w *modelAliasResponseWriter
+		if split.TargetModel != "" && split.TargetModel != model {
+			if _, aliased := service.RequestedModelFromContext(c.Request.Context()); !aliased {
+				c.Request = c.Request.WithContext(service.WithRequestedModel(c.Request.Context(), model))
+				w = &modelAliasResponseWriter{ResponseWriter: c.Writer, replacement: modelAliasReplacement(model)}
+				c.Writer = w
+			}
+		}
+		c.Next()
+		if w != nil {
+			w.finish()
+			c.Writer = w.ResponseWriter
+		}
+		service.RecordCanaryResult(split.Name, true, c.Writer.Status(), time.Since(start))
+	}
+}
+
+// applyCanary 将请求切换到分流的目标分组/模型，无法切换时返回 false（按 control 处理）
+func applyCanary(c *gin.Context, resolver routingGroupResolver, apiKey *service.APIKey, split *service.CanarySplit, model string) bool {
+	reqLog := logger.FromContext(c.Request.Context()).With(
+		zap.String("canary_split", split.Name),
+		zap.Int64("from_group_id", apiKey.Group.ID),
+		zap.Int64("target_group_id", split.TargetGroupID),
+		zap.String("target_model", split.TargetModel),
+	)
+	var target *service.Group
+	if split.TargetGroupID > 0 && split.TargetGroupID != apiKey.Group.ID {
+		group, err := resolver.ResolveRoutingGroup(c.Request.Context(), split.TargetGroupID)
+		if err != nil {
+			reqLog.Warn("gateway.canary_target_unavailable", zap.Error(err))
+			return false
+		}
+		if !service.CanRouteToGroup(apiKey.Group, group) {
+			reqLog.Warn("gateway.canary_target_invalid",
+				zap.String("target_platform", group.Platform),
+				zap.String("target_subscription_type", group.SubscriptionType),
+				zap.String("target_status", group.Status),
+			)
+			return false
+		}
+		target = group
+	}
+	if split.TargetModel != "" && split.TargetModel != model && !rewriteRequestModel(c, split.TargetModel) {
+		reqLog.Warn("gateway.canary_model_rewrite_failed")
+		return false
+	}
+	if target != nil {
+		c.Set(string(ContextKeyAPIKey), cloneAPIKeyWithGroup(apiKey, target))
+		setGroupContext(c, target)
+	}
+	reqLog.Debug("gateway.canary_selected")
+	return true
+}
@@ -0,0 +1,97 @@
+//go:build unit
+
+package middleware
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/ShaohongDong/sub2api/internal/service"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/require"
+	"github.com/tidwall/gjson"
+)
+
+type canarySourceStub struct {
+	settings *service.CanarySettings
+}
+
+func (s *canarySourceStub) GetCanarySplits(context.Context) *service.CanarySettings {
+	return s.settings
+}
+
+func canaryMetrics(t *testing.T, name string) service.CanarySplitMetrics {
+	t.Helper()
+	for _, split := range service.GetCanaryMetricsSnapshot().Splits {
+		if split.Name == name {
+			return split
+		}
+	}
+	return service.CanarySplitMetrics{Name: name}
+}
+
+func newCanaryTestRouter(settings *service.CanarySettings, groups routingGroupResolverStub) (*gin.Engine, *int64, *string) {
+	gin.SetMode(gin.TestMode)
+	var gotGroupID int64
+	var gotModel string
+	r := gin.New()
+	r.Use(func(c *gin.Context) {
+		c.Set(string(ContextKeyAPIKey), routingTestKey())
+		c.Next()
+	})
+	r.POST("/v1/messages", CanarySplit(&canarySourceStub{settings: settings}, groups), func(c *gin.Context) {
+		key, _ := GetAPIKeyFromContext(c)
+		gotGroupID = *key.GroupID
+		body, _ := io.ReadAll(c.Request.Body)
+		gotModel = gjson.GetBytes(body, "model").String()
+		c.JSON(http.StatusOK, gin.H{"model": gotModel})
+	})
+	return r, &gotGroupID, &gotModel
+}
+
+func TestCanarySplitRoutesCanaryTraffic(t *testing.T) {
+	settings := &service.CanarySettings{Enabled: true, Splits: []service.CanarySplit{{
+		Name:          "mw-canary-all",
+		Enabled:       true,
+		Models:        []string{"claude-sonnet-*"},
+		Percent:       100,
+		TargetGroupID: 2,
+		TargetModel:   "claude-sonnet-next",
+	}}}
+	groups := routingGroupResolverStub{2: {ID: 2, Platform: service.PlatformAnthropic, Status: service.StatusActive, Hydrated: true, SubscriptionType: service.SubscriptionTypeStandard}}
+	router, gotGroupID, gotModel := newCanaryTestRouter(settings, groups)
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/v1/messages", strings.NewReader(`{"model":"claude-sonnet-4-5"}`)))
+	require.Equal(t, http.StatusOK, w.Code)
+	require.Equal(t, int64(2), *gotGroupID)
+	require.Equal(t, "claude-sonnet-next", *gotModel)
+	require.Equal(t, "claude-sonnet-4-5", gjson.Get(w.Body.String(), "model").String())
+
+	metrics := canaryMetrics(t, "mw-canary-all")
+	require.Equal(t, uint64(1), metrics.Canary.Requests)
+	require.Equal(t, uint64(0), metrics.Control.Requests)
+}
+
+func TestCanarySplitControlTraffic(t *testing.T) {
+	settings := &service.CanarySettings{Enabled: true, Splits: []service.CanarySplit{
+		{Name: "mw-canary-none", Enabled: true, Models: []string{"claude-*"}, Percent: 0, TargetModel: "claude-next"},
+	}}
+	router, gotGroupID, gotModel := newCanaryTestRouter(settings, routingGroupResolverStub{})
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/v1/messages", strings.NewReader(`{"model":"claude-sonnet-4-5"}`)))
+	require.Equal(t, int64(1), *gotGroupID)
+	require.Equal(t, "claude-sonnet-4-5", *gotModel)
+	require.Equal(t, uint64(1), canaryMetrics(t, "mw-canary-none").Control.Requests)
+
+	// 目标分组无效时按 control 处理
+	settings.Splits[0] = service.CanarySplit{Name: "mw-canary-invalid", Enabled: true, Models: []string{"claude-*"}, Percent: 100, TargetGroupID: 404}
+	router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodPost, "/v1/messages", strings.NewReader(`{"model":"claude-sonnet-4-5"}`)))
+	require.Equal(t, int64(1), *gotGroupID)
+	require.Equal(t, uint64(1), canaryMetrics(t, "mw-canary-invalid").Control.Requests)
+}
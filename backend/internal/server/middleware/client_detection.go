@@ -0,0 +1,40 @@
+package middleware
+
+import (
+	"github.com/ShaohongDong/sub2api/internal/pkg/clientdetect"
+	"github.com/ShaohongDong/sub2api/internal/pkg/logger"
+	"github.com/ShaohongDong/sub2api/internal/pkg/tracing"
+	"github.com/gin-gonic/gin"
+	"go.opentelemetry.io/otel/attribute"
+	"go.uber.org/zap"
+)
+
+// ClientDetection 解析下游客户端信息（类型/版本/平台，综合 UA 与 originator/x-stainless-* 等指纹头）并写入 request.Context()。
+//
+// 解析结果供路由、日志与统计复用，同时注入 request-scoped logger 的 client_type/client_version 字段。
+func ClientDetection() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if c.Request == nil {
+			c.Next()
+			return
+		}
+
+		if _, ok := clientdetect.FromContext(c.Request.Context()); ok {
+			c.Next()
+			return
+		}
+
+		_, span := tracing.Start(c.Request.Context(), "client_detection")
+		info := clientdetect.ParseHeaders(c.Request.Header)
+		span.SetAttributes(attribute.String("sub2api.client.type", string(info.Type)), attribute.String("sub2api.client.version", info.Version))
+		span.End()
+		ctx := clientdetect.IntoContext(c.Request.Context(), info)
+		fields := []zap.Field{zap.String("client_type", string(info.Type))}
+		if info.Version != "" {
+			fields = append(fields, zap.String("client_version", info.Version))
+		}
+		ctx = logger.IntoContext(ctx, logger.FromContext(ctx).With(fields...))
+		c.Request = c.Request.WithContext(ctx)
+		c.Next()
+	}
+}
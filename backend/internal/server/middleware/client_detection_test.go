@@ -0,0 +1,74 @@
+//go:build unit
+
+package middleware
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/ShaohongDong/sub2api/internal/pkg/clientdetect"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/require"
+)
+
+func TestClientDetection_ParsesUserAgent(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	r := gin.New()
+	r.Use(ClientDetection())
+	r.GET("/t", func(c *gin.Context) {
+		info, ok := clientdetect.FromContext(c.Request.Context())
+		require.True(t, ok)
+		require.Equal(t, clientdetect.TypeCodexCLI, info.Type)
+		require.Equal(t, "0.46.0", info.Version)
+		require.Equal(t, clientdetect.PlatformMacOS, info.Platform)
+		c.Status(http.StatusOK)
+	})
+
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodGet, "/t", nil)
+	req.Header.Set("User-Agent", "codex_cli_rs/0.46.0 (Mac OS 15.6.1; arm64)")
+	r.ServeHTTP(w, req)
+	require.Equal(t, http.StatusOK, w.Code)
+}
+
+func TestClientDetection_FallsBackToOriginator(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	r := gin.New()
+	r.Use(ClientDetection())
+	r.GET("/t", func(c *gin.Context) {
+		info, _ := clientdetect.FromContext(c.Request.Context())
+		require.Equal(t, clientdetect.TypeCodexVSCode, info.Type)
+		c.Status(http.StatusOK)
+	})
+
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodGet, "/t", nil)
+	req.Header.Set("User-Agent", "corp-egress-proxy/1.0")
+	req.Header.Set("originator", "codex_vscode")
+	r.ServeHTTP(w, req)
+	require.Equal(t, http.StatusOK, w.Code)
+}
+
+func TestClientDetection_PreservesExisting(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	r := gin.New()
+	r.Use(ClientDetection())
+	r.GET("/t", func(c *gin.Context) {
+		info, ok := clientdetect.FromContext(c.Request.Context())
+		require.True(t, ok)
+		require.Equal(t, clientdetect.TypeCurl, info.Type)
+		c.Status(http.StatusOK)
+	})
+
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodGet, "/t", nil)
+	req.Header.Set("User-Agent", "codex_cli_rs/0.46.0")
+	req = req.WithContext(clientdetect.IntoContext(context.Background(), clientdetect.ClientInfo{Type: clientdetect.TypeCurl}))
+	r.ServeHTTP(w, req)
+	require.Equal(t, http.StatusOK, w.Code)
+}
@@ -0,0 +1,52 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ErrClientDisconnected 向下游写出失败，客户端连接已断开
+var ErrClientDisconnected = errors.New("client disconnected")
+
+// ClientDisconnect 在向下游写出失败时立即取消请求 context（取消原因为 ErrClientDisconnected）。
+//
+// 上游请求基于请求 context 发起，取消后随即中止，不再为无人接收的输出消耗账号额度；
+// 流式处理读取上游时得到 context canceled，按已收到的 usage 计费。
+// 连接关闭时 net/http 也会取消请求 context，此处额外覆盖连接未关闭但写出已失败的情况（如反向代理半关闭）。
+func ClientDisconnect() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ctx, cancel := context.WithCancelCause(c.Request.Context())
+		defer cancel(nil)
+		c.Writer = &clientDisconnectWriter{ResponseWriter: c.Writer, cancel: cancel}
+		c.Request = c.Request.WithContext(ctx)
+		c.Next()
+	}
+}
+
+// IsClientDisconnected 判断请求 context 的取消原因是否为客户端断开
+func IsClientDisconnected(ctx context.Context) bool {
+	return errors.Is(context.Cause(ctx), ErrClientDisconnected)
+}
+
+type clientDisconnectWriter struct {
+	gin.ResponseWriter
+	cancel context.CancelCauseFunc
+}
+
+func (w *clientDisconnectWriter) Write(p []byte) (int, error) {
+	n, err := w.ResponseWriter.Write(p)
+	if err != nil {
+		w.cancel(ErrClientDisconnected)
+	}
+	return n, err
+}
+
+func (w *clientDisconnectWriter) WriteString(s string) (int, error) {
+	n, err := w.ResponseWriter.WriteString(s)
+	if err != nil {
+		w.cancel(ErrClientDisconnected)
+	}
+	return n, err
+}
@@ -0,0 +1,57 @@
+package middleware
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/require"
+)
+
+// brokenResponseWriter 模拟客户端已断开：所有写出均失败
+type brokenResponseWriter struct {
+	header http.Header
+}
+
+func (w *brokenResponseWriter) Header() http.Header       { return w.header }
+func (w *brokenResponseWriter) WriteHeader(int)           {}
+func (w *brokenResponseWriter) Write([]byte) (int, error) { return 0, errors.New("broken pipe") }
+
+func TestClientDisconnect_CancelsOnWriteFailure(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.Use(ClientDisconnect())
+
+	var canceled, disconnected bool
+	r.POST("/v1/responses", func(c *gin.Context) {
+		ctx := c.Request.Context()
+		require.NoError(t, ctx.Err())
+		_, err := io.WriteString(c.Writer, "data: {}\n\n")
+		require.Error(t, err)
+		canceled = ctx.Err() != nil
+		disconnected = IsClientDisconnected(ctx)
+	})
+
+	r.ServeHTTP(&brokenResponseWriter{header: http.Header{}}, httptest.NewRequest(http.MethodPost, "/v1/responses", nil))
+	require.True(t, canceled)
+	require.True(t, disconnected)
+}
+
+func TestClientDisconnect_HealthyWriter(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.Use(ClientDisconnect())
+
+	r.POST("/v1/responses", func(c *gin.Context) {
+		_, err := c.Writer.WriteString("ok")
+		require.NoError(t, err)
+		require.NoError(t, c.Request.Context().Err())
+	})
+
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/v1/responses", nil))
+	require.Equal(t, "ok", rec.Body.String())
+}
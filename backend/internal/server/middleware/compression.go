@@ -0,0 +1,272 @@
+package middleware
+
+import (
+	"compress/gzip"
+	"io"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/ShaohongDong/sub2api/internal/config"
+	"github.com/andybalholm/brotli"
+	"github.com/gin-gonic/gin"
+)
+
+// brotliLevel 兼顾压缩率与首字节延迟（默认级别 6 对大响应的 CPU 开销偏高）
+const brotliLevel = 5
+
+var (
+	gzipWriterPool = sync.Pool{New: func() any {
+		w, _ := gzip.NewWriterLevel(io.Discard, gzip.DefaultCompression)
+		return w
+	}}
+	brotliWriterPool = sync.Pool{New: func() any {
+		return brotli.NewWriterLevel(io.Discard, brotliLevel)
+	}}
+)
+
+// compressEncoder gzip.Writer 与 brotli.Writer 的公共方法
+type compressEncoder interface {
+	io.WriteCloser
+	Flush() error
+	Reset(io.Writer)
+}
+
+type compressionRule struct {
+	prefix string
+	cfg    config.GatewayCompressionRouteConfig
+}
+
+// Compression 按客户端 Accept-Encoding 协商 br / gzip 压缩响应。
+//
+// 非流式 JSON 响应先缓冲 min_bytes，达到后才开始压缩（更小的响应原样返回）；
+// SSE 仅在 stream 开启时压缩，每次 Flush 同步刷出已压缩数据，保证事件及时到达。
+// 已带 Content-Encoding 的响应（如透传上游的压缩体）与 WebSocket 升级请求不处理。
+// 需放在 RequestLogger 之前，使 JSON 错误体注入 request_id 后再压缩。
+func Compression(cfg config.GatewayCompressionConfig) gin.HandlerFunc {
+	rules := make([]compressionRule, 0, len(cfg.Routes))
+	for prefix, routeCfg := range cfg.Routes {
+		rules = append(rules, compressionRule{prefix: prefix, cfg: routeCfg})
+	}
+	sort.Slice(rules, func(i, j int) bool { return len(rules[i].prefix) > len(rules[j].prefix) })
+
+	return func(c *gin.Context) {
+		if c.Request.Method == http.MethodHead || c.GetHeader("Upgrade") != "" {
+			c.Next()
+			return
+		}
+		enabled, stream := cfg.Enabled, cfg.Stream
+		for i := range rules {
+			if strings.HasPrefix(c.Request.URL.Path, rules[i].prefix) {
+				enabled, stream = rules[i].cfg.Enabled, rules[i].cfg.Stream
+				break
+			}
+		}
+		if !enabled && !stream {
+			c.Next()
+			return
+		}
+		encoding := negotiateEncoding(c.GetHeader("Accept-Encoding"), cfg.Algorithms)
+		if encoding == "" {
+			c.Next()
+			return
+		}
+
+		w := &compressWriter{
+			ResponseWriter: c.Writer,
+			encoding:       encoding,
+			json:           enabled,
+			stream:         stream,
+			minBytes:       cfg.MinBytes,
+		}
+		c.Writer = w
+		defer w.finish()
+		c.Next()
+	}
+}
+
+// negotiateEncoding 在客户端接受（q > 0）的编码中按 algorithms 的优先级选择一个
+func negotiateEncoding(acceptEncoding string, algorithms []string) string {
+	if acceptEncoding == "" {
+		return ""
+	}
+	accepted := make(map[string]bool)
+	wildcard := false
+	for _, part := range strings.Split(acceptEncoding, ",") {
+		name, params, _ := strings.Cut(strings.TrimSpace(part), ";")
+		name = strings.ToLower(strings.TrimSpace(name))
+		q := 1.0
+		for _, param := range strings.Split(params, ";") {
+			if key, value, ok := strings.Cut(strings.TrimSpace(param), "="); ok && strings.TrimSpace(key) == "q" {
+				if parsed, err := strconv.ParseFloat(strings.TrimSpace(value), 64); err == nil {
+					q = parsed
+				}
+			}
+		}
+		if name == "*" {
+			wildcard = q > 0
+			continue
+		}
+		accepted[name] = q > 0
+	}
+	for _, algorithm := range algorithms {
+		if ok, listed := accepted[algorithm]; ok || (!listed && wildcard) {
+			return algorithm
+		}
+	}
+	return ""
+}
+
+// compressWriter 在首次写出时决定是否压缩：JSON 响应缓冲到 minBytes 后再决定，SSE 立即决定。
+type compressWriter struct {
+	gin.ResponseWriter
+
+	encoding string
+	json     bool
+	stream   bool
+	minBytes int
+
+	decided bool
+	enc     compressEncoder
+	buf     []byte
+	wrote   bool
+	size    int
+}
+
+// compressible 判断当前响应是否可以压缩；streaming 表示响应已被要求立即刷出
+func (w *compressWriter) compressible(streaming bool) bool {
+	status := w.ResponseWriter.Status()
+	h := w.Header()
+	if status < http.StatusOK || status == http.StatusNoContent || status == http.StatusNotModified ||
+		h.Get("Content-Encoding") != "" {
+		return false
+	}
+	contentType := h.Get("Content-Type")
+	if strings.HasPrefix(contentType, "text/event-stream") {
+		return w.stream
+	}
+	return w.json && !streaming && strings.Contains(contentType, "json")
+}
+
+// start 设置压缩响应头并开始压缩，写出已缓冲的数据
+func (w *compressWriter) start() {
+	h := w.Header()
+	h.Set("Content-Encoding", w.encoding)
+	h.Add("Vary", "Accept-Encoding")
+	h.Del("Content-Length")
+	if w.encoding == "br" {
+		w.enc = brotliWriterPool.Get().(*brotli.Writer)
+	} else {
+		w.enc = gzipWriterPool.Get().(*gzip.Writer)
+	}
+	w.enc.Reset(w.ResponseWriter)
+	w.decided = true
+	if len(w.buf) > 0 {
+		_, _ = w.enc.Write(w.buf)
+		w.buf = nil
+	}
+}
+
+// passthrough 放弃压缩，原样写出已缓冲的数据
+func (w *compressWriter) passthrough() error {
+	w.decided = true
+	if len(w.buf) == 0 {
+		return nil
+	}
+	buf := w.buf
+	w.buf = nil
+	_, err := w.ResponseWriter.Write(buf)
+	return err
+}
+
+func (w *compressWriter) Write(p []byte) (int, error) {
+	w.wrote = true
+	w.size += len(p)
+	if !w.decided {
+		streaming := strings.HasPrefix(w.Header().Get("Content-Type"), "text/event-stream")
+		switch {
+		case !w.compressible(false):
+			if err := w.passthrough(); err != nil {
+				return 0, err
+			}
+		case streaming:
+			w.start()
+		default:
+			w.buf = append(w.buf, p...)
+			if len(w.buf) >= w.minBytes {
+				w.start()
+			}
+			return len(p), nil
+		}
+	}
+	if w.enc != nil {
+		if _, err := w.enc.Write(p); err != nil {
+			return 0, err
+		}
+		return len(p), nil
+	}
+	return w.ResponseWriter.Write(p)
+}
+
+func (w *compressWriter) WriteString(s string) (int, error) {
+	return w.Write([]byte(s))
+}
+
+// WriteHeaderNow 响应头须立即写出时，只有可压缩的 SSE 仍能压缩
+func (w *compressWriter) WriteHeaderNow() {
+	if !w.decided {
+		if w.compressible(true) {
+			w.start()
+		} else {
+			_ = w.passthrough()
+		}
+	}
+	w.ResponseWriter.WriteHeaderNow()
+}
+
+func (w *compressWriter) Flush() {
+	if !w.decided {
+		if w.compressible(true) {
+			w.start()
+		} else {
+			_ = w.passthrough()
+		}
+	}
+	if w.enc != nil {
+		_ = w.enc.Flush()
+	}
+	w.ResponseWriter.Flush()
+}
+
+func (w *compressWriter) Written() bool {
+	return w.wrote || w.ResponseWriter.Written()
+}
+
+// Size 返回处理器写出的（未压缩）字节数
+func (w *compressWriter) Size() int {
+	if !w.wrote {
+		return w.ResponseWriter.Size()
+	}
+	return w.size
+}
+
+// finish 写出未达到 minBytes 的缓冲数据，或结束压缩流并归还编码器
+func (w *compressWriter) finish() {
+	if !w.decided {
+		_ = w.passthrough()
+		return
+	}
+	if w.enc == nil {
+		return
+	}
+	_ = w.enc.Close()
+	w.enc.Reset(io.Discard)
+	if w.encoding == "br" {
+		brotliWriterPool.Put(w.enc)
+	} else {
+		gzipWriterPool.Put(w.enc)
+	}
+	w.enc = nil
+}
@@ -0,0 +1,198 @@
+package middleware
+
+import (
+	"compress/gzip"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/ShaohongDong/sub2api/internal/config"
+	"github.com/andybalholm/brotli"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/require"
+)
+
+func newCompressionTestRouter(cfg config.GatewayCompressionConfig, handler gin.HandlerFunc) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.Use(Compression(cfg))
+	r.Any("/*path", handler)
+	return r
+}
+
+func defaultCompressionConfig() config.GatewayCompressionConfig {
+	return config.GatewayCompressionConfig{
+		Enabled:    true,
+		MinBytes:   64,
+		Algorithms: []string{"br", "gzip"},
+	}
+}
+
+func compressionTestRequest(path, acceptEncoding string) *http.Request {
+	req := httptest.NewRequest(http.MethodPost, path, nil)
+	if acceptEncoding != "" {
+		req.Header.Set("Accept-Encoding", acceptEncoding)
+	}
+	return req
+}
+
+func TestNegotiateEncoding(t *testing.T) {
+	algorithms := []string{"br", "gzip"}
+	require.Equal(t, "br", negotiateEncoding("gzip, deflate, br", algorithms))
+	require.Equal(t, "gzip", negotiateEncoding("gzip", algorithms))
+	require.Equal(t, "gzip", negotiateEncoding("br;q=0, gzip;q=0.5", algorithms))
+	require.Equal(t, "br", negotiateEncoding("*", algorithms))
+	require.Equal(t, "gzip", negotiateEncoding("*, br;q=0", algorithms))
+	require.Equal(t, "", negotiateEncoding("identity", algorithms))
+	require.Equal(t, "", negotiateEncoding("", algorithms))
+	require.Equal(t, "gzip", negotiateEncoding("br, gzip", []string{"gzip"}))
+}
+
+func TestCompression_LargeJSONCompressed(t *testing.T) {
+	payload := `{"content":"` + strings.Repeat("tool output ", 200) + `"}`
+	r := newCompressionTestRouter(defaultCompressionConfig(), func(c *gin.Context) {
+		c.Header("Content-Length", "1")
+		c.Data(http.StatusOK, "application/json", []byte(payload))
+	})
+
+	for _, tc := range []struct {
+		accept string
+		decode func(io.Reader) (io.Reader, error)
+	}{
+		{accept: "gzip", decode: func(r io.Reader) (io.Reader, error) { return gzip.NewReader(r) }},
+		{accept: "br", decode: func(r io.Reader) (io.Reader, error) { return brotli.NewReader(r), nil }},
+	} {
+		rec := httptest.NewRecorder()
+		r.ServeHTTP(rec, compressionTestRequest("/v1/messages", tc.accept))
+		require.Equal(t, http.StatusOK, rec.Code)
+		require.Equal(t, tc.accept, rec.Header().Get("Content-Encoding"))
+		require.Equal(t, "Accept-Encoding", rec.Header().Get("Vary"))
+		require.Empty(t, rec.Header().Get("Content-Length"))
+		require.Less(t, rec.Body.Len(), len(payload))
+
+		reader, err := tc.decode(rec.Body)
+		require.NoError(t, err)
+		body, err := io.ReadAll(reader)
+		require.NoError(t, err)
+		require.Equal(t, payload, string(body))
+	}
+}
+
+func TestCompression_SmallOrNotNegotiatedUntouched(t *testing.T) {
+	r := newCompressionTestRouter(defaultCompressionConfig(), func(c *gin.Context) {
+		if c.Query("big") != "" {
+			c.Data(http.StatusOK, "application/json", []byte(`{"x":"`+strings.Repeat("a", 200)+`"}`))
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{"ok": true})
+	})
+
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, compressionTestRequest("/v1/messages", "gzip"))
+	require.Empty(t, rec.Header().Get("Content-Encoding"))
+	require.Equal(t, `{"ok":true}`, rec.Body.String())
+
+	rec = httptest.NewRecorder()
+	r.ServeHTTP(rec, compressionTestRequest("/v1/messages?big=1", ""))
+	require.Empty(t, rec.Header().Get("Content-Encoding"))
+	require.True(t, strings.HasPrefix(rec.Body.String(), `{"x":"aaa`))
+}
+
+func TestCompression_SkipsNonJSONAndEncodedResponses(t *testing.T) {
+	big := strings.Repeat("b", 500)
+	r := newCompressionTestRouter(defaultCompressionConfig(), func(c *gin.Context) {
+		if c.Query("encoded") != "" {
+			c.Header("Content-Encoding", "gzip")
+			c.Data(http.StatusOK, "application/json", []byte(big))
+			return
+		}
+		c.Data(http.StatusOK, "text/plain", []byte(big))
+	})
+
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, compressionTestRequest("/v1/files", "gzip"))
+	require.Empty(t, rec.Header().Get("Content-Encoding"))
+	require.Equal(t, big, rec.Body.String())
+
+	rec = httptest.NewRecorder()
+	r.ServeHTTP(rec, compressionTestRequest("/v1/messages?encoded=1", "br"))
+	require.Equal(t, "gzip", rec.Header().Get("Content-Encoding"))
+	require.Equal(t, big, rec.Body.String())
+}
+
+func TestCompression_SSEOnlyWhenStreamEnabled(t *testing.T) {
+	events := []string{"data: {\"delta\":\"a\"}\n\n", "data: {\"delta\":\"b\"}\n\n", "data: [DONE]\n\n"}
+	handler := func(c *gin.Context) {
+		c.Header("Content-Type", "text/event-stream")
+		c.Status(http.StatusOK)
+		for _, e := range events {
+			_, _ = io.WriteString(c.Writer, e)
+			c.Writer.Flush()
+		}
+	}
+
+	cfg := defaultCompressionConfig()
+	cfg.Routes = map[string]config.GatewayCompressionRouteConfig{
+		"/v1/chat/completions": {Enabled: true, Stream: true},
+	}
+	r := newCompressionTestRouter(cfg, handler)
+
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, compressionTestRequest("/v1/messages", "gzip"))
+	require.Empty(t, rec.Header().Get("Content-Encoding"))
+	require.Equal(t, strings.Join(events, ""), rec.Body.String())
+
+	rec = httptest.NewRecorder()
+	r.ServeHTTP(rec, compressionTestRequest("/v1/chat/completions", "gzip"))
+	require.Equal(t, "gzip", rec.Header().Get("Content-Encoding"))
+	require.True(t, rec.Flushed)
+	reader, err := gzip.NewReader(rec.Body)
+	require.NoError(t, err)
+	body, err := io.ReadAll(reader)
+	require.NoError(t, err)
+	require.Equal(t, strings.Join(events, ""), string(body))
+}
+
+func TestCompression_RouteOverrideDisables(t *testing.T) {
+	cfg := defaultCompressionConfig()
+	cfg.Routes = map[string]config.GatewayCompressionRouteConfig{
+		"/api/v1/admin/": {Enabled: false},
+	}
+	payload := `{"x":"` + strings.Repeat("a", 500) + `"}`
+	r := newCompressionTestRouter(cfg, func(c *gin.Context) {
+		c.Data(http.StatusOK, "application/json", []byte(payload))
+	})
+
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, compressionTestRequest("/api/v1/admin/accounts", "gzip"))
+	require.Empty(t, rec.Header().Get("Content-Encoding"))
+	require.Equal(t, payload, rec.Body.String())
+
+	rec = httptest.NewRecorder()
+	r.ServeHTTP(rec, compressionTestRequest("/api/v1/keys", "gzip"))
+	require.Equal(t, "gzip", rec.Header().Get("Content-Encoding"))
+}
+
+func TestCompression_RequestIDInjectedBeforeCompression(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.Use(Compression(config.GatewayCompressionConfig{Enabled: true, Algorithms: []string{"gzip"}}))
+	r.Use(RequestLogger())
+	r.POST("/v1/messages", func(c *gin.Context) {
+		c.JSON(http.StatusBadGateway, gin.H{"error": gin.H{"message": "upstream failed"}})
+	})
+
+	req := compressionTestRequest("/v1/messages", "gzip")
+	req.Header.Set(requestIDHeader, "rid-gz")
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, req)
+	require.Equal(t, http.StatusBadGateway, rec.Code)
+	require.Equal(t, "gzip", rec.Header().Get("Content-Encoding"))
+	reader, err := gzip.NewReader(rec.Body)
+	require.NoError(t, err)
+	body, err := io.ReadAll(reader)
+	require.NoError(t, err)
+	require.Contains(t, string(body), `"request_id":"rid-gz"`)
+}
@@ -3,6 +3,7 @@ package middleware
 import (
 	"log"
 	"net/http"
+	"strconv"
 	"strings"
 	"sync"
 
@@ -12,7 +13,15 @@ import (
 
 var corsWarningOnce sync.Once
 
+// defaultCORSExposeHeaders 浏览器客户端可读取的响应头（请求 ID、限流重试与幂等重放标记）
+var defaultCORSExposeHeaders = []string{
+	"ETag", "X-Request-ID", UpstreamRequestIDHeader, "Retry-After", "X-Idempotency-Replayed",
+}
+
 // CORS 跨域中间件
+//
+// 允许的来源支持 "*"、精确匹配与 "https://*.example.com" 形式的子域名通配；
+// 请求头在内置列表（含各协议 SDK 的网关请求头）之外可按 allowed_headers 追加，"*" 时回显预检声明的请求头。
 func CORS(cfg config.CORSConfig) gin.HandlerFunc {
 	allowedOrigins := normalizeOrigins(cfg.AllowedOrigins)
 	allowAll := false
@@ -44,15 +53,26 @@ func CORS(cfg config.CORSConfig) gin.HandlerFunc {
 	}
 
 	allowedSet := make(map[string]struct{}, len(allowedOrigins))
+	// 子域名通配：scheme 与 "." 开头的域名后缀
+	type originSuffix struct{ scheme, suffix string }
+	var allowedSuffixes []originSuffix
 	for _, origin := range allowedOrigins {
 		if origin == "" || origin == "*" {
 			continue
 		}
+		if scheme, host, ok := strings.Cut(origin, "://*."); ok {
+			allowedSuffixes = append(allowedSuffixes, originSuffix{scheme: scheme + "://", suffix: "." + host})
+			continue
+		}
 		allowedSet[origin] = struct{}{}
 	}
 	allowHeaders := []string{
 		"Content-Type", "Content-Length", "Accept-Encoding", "X-CSRF-Token", "Authorization",
 		"accept", "origin", "Cache-Control", "X-Requested-With", "X-API-Key",
+		// 网关协议请求头：Anthropic / Gemini / OpenAI SDK 及幂等、请求 ID
+		"anthropic-version", "anthropic-beta", "anthropic-dangerous-direct-browser-access",
+		"x-goog-api-key", "x-goog-api-client", "openai-organization", "openai-project", "openai-beta",
+		"Idempotency-Key", "X-Request-ID",
 	}
 	// OpenAI Node SDK 会发送 x-stainless-* 请求头，需在 CORS 中显式放行。
 	openAIProperties := []string{
@@ -62,13 +82,30 @@ func CORS(cfg config.CORSConfig) gin.HandlerFunc {
 	for _, prop := range openAIProperties {
 		allowHeaders = append(allowHeaders, "x-stainless-"+prop)
 	}
+	reflectHeaders := false
+	for _, header := range cfg.AllowedHeaders {
+		if header == "*" {
+			reflectHeaders = true
+			continue
+		}
+		allowHeaders = append(allowHeaders, header)
+	}
 	allowHeadersValue := strings.Join(allowHeaders, ", ")
+	exposeHeadersValue := strings.Join(append(append([]string{}, defaultCORSExposeHeaders...), cfg.ExposedHeaders...), ", ")
+	maxAge := "86400"
+	if cfg.MaxAgeSeconds > 0 {
+		maxAge = strconv.Itoa(cfg.MaxAgeSeconds)
+	}
 
 	return func(c *gin.Context) {
 		origin := strings.TrimSpace(c.GetHeader("Origin"))
 		originAllowed := allowAll
 		if origin != "" && !allowAll {
 			_, originAllowed = allowedSet[origin]
+			for i := 0; !originAllowed && i < len(allowedSuffixes); i++ {
+				rest, ok := strings.CutPrefix(origin, allowedSuffixes[i].scheme)
+				originAllowed = ok && strings.HasSuffix(rest, allowedSuffixes[i].suffix) && !strings.ContainsAny(rest, "/?#@")
+			}
 		}
 
 		if originAllowed {
@@ -81,10 +118,15 @@ func CORS(cfg config.CORSConfig) gin.HandlerFunc {
 			if allowCredentials {
 				c.Writer.Header().Set("Access-Control-Allow-Credentials", "true")
 			}
-			c.Writer.Header().Set("Access-Control-Allow-Headers", allowHeadersValue)
+			headersValue := allowHeadersValue
+			if requested := strings.TrimSpace(c.GetHeader("Access-Control-Request-Headers")); reflectHeaders && requested != "" {
+				headersValue = requested
+				c.Writer.Header().Add("Vary", "Access-Control-Request-Headers")
+			}
+			c.Writer.Header().Set("Access-Control-Allow-Headers", headersValue)
 			c.Writer.Header().Set("Access-Control-Allow-Methods", "POST, OPTIONS, GET, PUT, DELETE, PATCH")
-			c.Writer.Header().Set("Access-Control-Expose-Headers", "ETag")
-			c.Writer.Header().Set("Access-Control-Max-Age", "86400")
+			c.Writer.Header().Set("Access-Control-Expose-Headers", exposeHeadersValue)
+			c.Writer.Header().Set("Access-Control-Max-Age", maxAge)
 		}
 		// 处理预检请求
 		if c.Request.Method == http.MethodOptions {
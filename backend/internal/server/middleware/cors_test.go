@@ -287,6 +287,84 @@ func TestCORS_VaryHeader_SetForSpecificOrigin(t *testing.T) {
 		"非通配符允许的 origin 应设置 Vary: Origin")
 }
 
+func TestCORS_SubdomainWildcardOrigin(t *testing.T) {
+	middleware := CORS(config.CORSConfig{AllowedOrigins: []string{"https://*.example.com"}})
+
+	tests := []struct {
+		origin  string
+		allowed bool
+	}{
+		{origin: "https://playground.example.com", allowed: true},
+		{origin: "https://a.b.example.com", allowed: true},
+		{origin: "https://example.com", allowed: false},
+		{origin: "http://playground.example.com", allowed: false},
+		{origin: "https://evil-example.com", allowed: false},
+		{origin: "https://example.com.evil.com", allowed: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.origin, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(w)
+			c.Request = httptest.NewRequest(http.MethodOptions, "/v1/messages", nil)
+			c.Request.Header.Set("Origin", tt.origin)
+
+			middleware(c)
+
+			if tt.allowed {
+				assert.Equal(t, http.StatusNoContent, w.Code)
+				assert.Equal(t, tt.origin, w.Header().Get("Access-Control-Allow-Origin"))
+			} else {
+				assert.Equal(t, http.StatusForbidden, w.Code)
+				assert.Empty(t, w.Header().Get("Access-Control-Allow-Origin"))
+			}
+		})
+	}
+}
+
+func TestCORS_GatewayHeadersAndConfiguredHeaders(t *testing.T) {
+	middleware := CORS(config.CORSConfig{
+		AllowedOrigins: []string{"https://tools.example.com"},
+		AllowedHeaders: []string{"X-Team"},
+		ExposedHeaders: []string{"X-Ratelimit-Remaining-Requests"},
+		MaxAgeSeconds:  600,
+	})
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest(http.MethodOptions, "/v1/messages", nil)
+	c.Request.Header.Set("Origin", "https://tools.example.com")
+
+	middleware(c)
+
+	allowHeaders := w.Header().Get("Access-Control-Allow-Headers")
+	for _, header := range []string{"anthropic-version", "anthropic-dangerous-direct-browser-access", "x-goog-api-key", "Idempotency-Key", "X-Team"} {
+		assert.Contains(t, allowHeaders, header)
+	}
+	exposeHeaders := w.Header().Get("Access-Control-Expose-Headers")
+	for _, header := range []string{"X-Request-ID", "Retry-After", "X-Ratelimit-Remaining-Requests"} {
+		assert.Contains(t, exposeHeaders, header)
+	}
+	assert.Equal(t, "600", w.Header().Get("Access-Control-Max-Age"))
+}
+
+func TestCORS_WildcardAllowedHeadersReflectsPreflight(t *testing.T) {
+	middleware := CORS(config.CORSConfig{
+		AllowedOrigins: []string{"https://tools.example.com"},
+		AllowedHeaders: []string{"*"},
+	})
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest(http.MethodOptions, "/v1/messages", nil)
+	c.Request.Header.Set("Origin", "https://tools.example.com")
+	c.Request.Header.Set("Access-Control-Request-Headers", "x-custom-trace, content-type")
+
+	middleware(c)
+
+	assert.Equal(t, "x-custom-trace, content-type", w.Header().Get("Access-Control-Allow-Headers"))
+	assert.Contains(t, w.Header().Values("Vary"), "Access-Control-Request-Headers")
+}
+
 func TestNormalizeOrigins(t *testing.T) {
 	tests := []struct {
 		name   string
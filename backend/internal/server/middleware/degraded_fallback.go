@@ -0,0 +1,83 @@
+package middleware
+
+import (
+	"bytes"
+	"io"
+	"strconv"
+
+	"github.com/ShaohongDong/sub2api/internal/pkg/logger"
+	"github.com/ShaohongDong/sub2api/internal/service"
+
+	"github.com/gin-gonic/gin"
+	"github.com/tidwall/gjson"
+	"github.com/tidwall/sjson"
+	"go.uber.org/zap"
+)
+
+// DegradedFallback 包装推理处理器：Key 配置了降级模型（degraded_fallback_model）时，
+// 若请求模型的所有账号都不可用（故障、冷却或限流中，处理器以 503 "No available accounts" 结束）且尚未输出任何内容，
+// 则把请求模型替换为降级模型（按分组/全局别名表解析）在原分组重新处理，并以 X-Sub2API-Degraded-Model 响应头标注替换后的模型。
+// 位于 ProviderFallback 之外：跨平台降级链仍使用原模型，链上分组全部不可用后才替换模型。
+// 仅用于请求体顶层携带 "model" 的端点（Messages / Chat Completions / Responses）；运行时设置关闭降级模式时直接放行。
+func DegradedFallback(aliases modelAliasSource) func(gin.HandlerFunc) gin.HandlerFunc {
+	return func(next gin.HandlerFunc) gin.HandlerFunc {
+		return func(c *gin.Context) {
+			apiKey, ok := GetAPIKeyFromContext(c)
+			if !ok || apiKey.DegradedFallbackModel == "" || !service.DegradedModeEnabled() || c.Request == nil || c.Request.Body == nil {
+				next(c)
+				return
+			}
+			body, err := io.ReadAll(c.Request.Body)
+			_ = c.Request.Body.Close()
+			c.Request.Body = io.NopCloser(bytes.NewReader(body))
+			if err != nil {
+				next(c)
+				return
+			}
+			baseCtx := c.Request.Context()
+			requested, aliased := service.RequestedModelFromContext(baseCtx)
+			if !aliased {
+				requested = gjson.GetBytes(body, "model").String()
+			}
+			fallback, ok := apiKey.DegradedFallbackFor(requested)
+			if !ok || gjson.GetBytes(body, "model").Type != gjson.String {
+				next(c)
+				return
+			}
+
+			baseHeader := c.Writer.Header().Clone()
+			w := &fallbackResponseWriter{ResponseWriter: c.Writer}
+			c.Writer = w
+			next(c)
+
+			if w.status != 0 && baseCtx.Err() == nil && service.IsNoAvailableAccountsError(w.status, w.body.Bytes()) {
+				upstream := fallback
+				if aliases != nil {
+					if resolved, ok := service.ResolveModelAlias(apiKey.Group, aliases.GetModelAliases(baseCtx), fallback); ok {
+						upstream = resolved
+					}
+				}
+				if attemptBody, err := sjson.SetBytes(body, "model", upstream); err == nil {
+					logger.FromContext(baseCtx).Info("gateway.degraded_fallback",
+						zap.String("requested_model", requested),
+						zap.String("fallback_model", fallback),
+						zap.Int64("api_key_id", apiKey.ID),
+					)
+					// 恢复首次处理前的状态（ProviderFallback 可能已切换分组与请求上下文）
+					w.reset(baseHeader)
+					w.Header().Set("X-Sub2API-Degraded-Model", fallback)
+					c.Request = c.Request.WithContext(baseCtx)
+					c.Request.Body = io.NopCloser(bytes.NewReader(attemptBody))
+					c.Request.ContentLength = int64(len(attemptBody))
+					c.Request.Header.Set("Content-Length", strconv.Itoa(len(attemptBody)))
+					c.Set(service.OpenAIParsedRequestBodyKey, nil)
+					c.Set(string(ContextKeyAPIKey), apiKey)
+					next(c)
+				}
+			}
+
+			w.commit()
+			c.Writer = w.ResponseWriter
+		}
+	}
+}
@@ -0,0 +1,112 @@
+//go:build unit
+
+package middleware
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/ShaohongDong/sub2api/internal/service"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/require"
+	"github.com/tidwall/gjson"
+)
+
+// newDegradedFallbackTestRouter 模型不在 available 中时返回 503 "No available accounts"，记录每次尝试的请求模型
+func newDegradedFallbackTestRouter(apiKey *service.APIKey, aliases map[string]string, available map[string]int) (*gin.Engine, *[]string) {
+	gin.SetMode(gin.TestMode)
+	attempts := &[]string{}
+	r := gin.New()
+	r.Use(func(c *gin.Context) {
+		c.Set(string(ContextKeyAPIKey), apiKey)
+		c.Next()
+	})
+	r.POST("/v1/messages", DegradedFallback(modelAliasSourceStub(aliases))(func(c *gin.Context) {
+		body, _ := io.ReadAll(c.Request.Body)
+		model := gjson.GetBytes(body, "model").String()
+		*attempts = append(*attempts, model)
+		status, ok := available[model]
+		if !ok {
+			c.JSON(http.StatusServiceUnavailable, gin.H{"type": "error", "error": gin.H{"type": "api_error", "message": "No available accounts"}})
+			return
+		}
+		c.JSON(status, gin.H{"model": model})
+	}))
+	return r, attempts
+}
+
+func degradedTestKey(fallback string) *service.APIKey {
+	groupID := int64(1)
+	return &service.APIKey{
+		ID:                    9,
+		GroupID:               &groupID,
+		Group:                 &service.Group{ID: 1, Platform: service.PlatformAnthropic, Status: service.StatusActive, Hydrated: true},
+		DegradedFallbackModel: fallback,
+	}
+}
+
+func TestDegradedFallbackServesFallbackModel(t *testing.T) {
+	router, attempts := newDegradedFallbackTestRouter(degradedTestKey("claude-haiku-4-5"), nil,
+		map[string]int{"claude-haiku-4-5": http.StatusOK})
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/v1/messages", strings.NewReader(`{"model":"claude-opus-4-5"}`)))
+
+	require.Equal(t, http.StatusOK, w.Code)
+	require.Equal(t, []string{"claude-opus-4-5", "claude-haiku-4-5"}, *attempts)
+	require.Equal(t, "claude-haiku-4-5", w.Header().Get("X-Sub2API-Degraded-Model"))
+	require.Equal(t, "claude-haiku-4-5", gjson.Get(w.Body.String(), "model").String())
+}
+
+func TestDegradedFallbackResolvesAlias(t *testing.T) {
+	router, attempts := newDegradedFallbackTestRouter(degradedTestKey("fast"), map[string]string{"fast": "claude-haiku-4-5"},
+		map[string]int{"claude-haiku-4-5": http.StatusOK})
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/v1/messages", strings.NewReader(`{"model":"claude-opus-4-5"}`)))
+
+	require.Equal(t, http.StatusOK, w.Code)
+	require.Equal(t, []string{"claude-opus-4-5", "claude-haiku-4-5"}, *attempts)
+	require.Equal(t, "fast", w.Header().Get("X-Sub2API-Degraded-Model"))
+}
+
+func TestDegradedFallbackOnlyForNoAvailableAccounts(t *testing.T) {
+	// 上游返回的 503 与 429 不属于"无可用账号"，原样透传
+	for _, status := range []int{http.StatusServiceUnavailable, http.StatusTooManyRequests} {
+		router, attempts := newDegradedFallbackTestRouter(degradedTestKey("claude-haiku-4-5"), nil,
+			map[string]int{"claude-opus-4-5": status, "claude-haiku-4-5": http.StatusOK})
+
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/v1/messages", strings.NewReader(`{"model":"claude-opus-4-5"}`)))
+
+		require.Equal(t, status, w.Code)
+		require.Equal(t, []string{"claude-opus-4-5"}, *attempts)
+		require.Empty(t, w.Header().Get("X-Sub2API-Degraded-Model"))
+	}
+}
+
+func TestDegradedFallbackReturnsErrorWhenFallbackUnavailable(t *testing.T) {
+	router, attempts := newDegradedFallbackTestRouter(degradedTestKey("claude-haiku-4-5"), nil, nil)
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/v1/messages", strings.NewReader(`{"model":"claude-opus-4-5"}`)))
+
+	require.Equal(t, http.StatusServiceUnavailable, w.Code)
+	require.Equal(t, []string{"claude-opus-4-5", "claude-haiku-4-5"}, *attempts)
+	require.Contains(t, w.Body.String(), "No available accounts")
+}
+
+func TestDegradedFallbackSkipsWithoutConfigOrSameModel(t *testing.T) {
+	for _, fallback := range []string{"", "claude-opus-4-5"} {
+		router, attempts := newDegradedFallbackTestRouter(degradedTestKey(fallback), nil, nil)
+
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/v1/messages", strings.NewReader(`{"model":"claude-opus-4-5"}`)))
+
+		require.Equal(t, http.StatusServiceUnavailable, w.Code)
+		require.Equal(t, []string{"claude-opus-4-5"}, *attempts)
+	}
+}
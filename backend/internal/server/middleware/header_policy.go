@@ -0,0 +1,76 @@
+package middleware
+
+import (
+	"context"
+
+	"github.com/ShaohongDong/sub2api/internal/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+// headerPolicySource 提供头策略配置（由 SettingService 实现，带进程内缓存）
+type headerPolicySource interface {
+	GetHeaderPolicy(ctx context.Context) *service.HeaderPolicySettings
+}
+
+// HeaderPolicy 在响应头写出前按头策略剥离或注入返回给客户端的响应头。
+// 规则在首次写出时按当时的分组与最终命中的账号匹配，因此需位于 API Key 认证之后。
+func HeaderPolicy(source headerPolicySource) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if source == nil || c.Request == nil {
+			c.Next()
+			return
+		}
+		policy := source.GetHeaderPolicy(c.Request.Context())
+		if !policy.HasResponseRules() {
+			c.Next()
+			return
+		}
+		w := &headerPolicyResponseWriter{ResponseWriter: c.Writer, c: c, policy: policy}
+		c.Writer = w
+		c.Next()
+		c.Writer = w.ResponseWriter
+	}
+}
+
+// headerPolicyResponseWriter 在首次写出响应头前应用响应头规则
+type headerPolicyResponseWriter struct {
+	gin.ResponseWriter
+	c       *gin.Context
+	policy  *service.HeaderPolicySettings
+	applied bool
+}
+
+func (w *headerPolicyResponseWriter) apply() {
+	if w.applied || w.ResponseWriter.Written() {
+		return
+	}
+	w.applied = true
+	scope := service.HeaderPolicyScopeFromContext(w.c.Request.Context(), w.c.Request.URL.Path)
+	w.policy.ApplyResponse(scope, w.Header())
+}
+
+func (w *headerPolicyResponseWriter) WriteHeader(code int) {
+	w.apply()
+	w.ResponseWriter.WriteHeader(code)
+}
+
+func (w *headerPolicyResponseWriter) WriteHeaderNow() {
+	w.apply()
+	w.ResponseWriter.WriteHeaderNow()
+}
+
+func (w *headerPolicyResponseWriter) Write(b []byte) (int, error) {
+	w.apply()
+	return w.ResponseWriter.Write(b)
+}
+
+func (w *headerPolicyResponseWriter) WriteString(s string) (int, error) {
+	w.apply()
+	return w.ResponseWriter.WriteString(s)
+}
+
+func (w *headerPolicyResponseWriter) Flush() {
+	w.apply()
+	w.ResponseWriter.Flush()
+}
@@ -0,0 +1,73 @@
+//go:build unit
+
+package middleware
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/ShaohongDong/sub2api/internal/pkg/ctxkey"
+	"github.com/ShaohongDong/sub2api/internal/service"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/require"
+)
+
+type headerPolicySourceStub struct {
+	settings *service.HeaderPolicySettings
+}
+
+func (s *headerPolicySourceStub) GetHeaderPolicy(context.Context) *service.HeaderPolicySettings {
+	return s.settings
+}
+
+func TestHeaderPolicyRewritesResponseHeaders(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	source := &headerPolicySourceStub{settings: &service.HeaderPolicySettings{Enabled: true, Rules: []service.HeaderPolicyRule{
+		{Name: "hide upstream", Enabled: true, Platforms: []string{service.PlatformOpenAI},
+			StripResponseHeaders: []string{"openai-*", "x-request-id"}, SetResponseHeaders: map[string]string{"X-Gateway": "sub2api"}},
+		{Name: "other account", Enabled: true, AccountIDs: []int64{1}, SetResponseHeaders: map[string]string{"X-Other": "1"}},
+	}}}
+	r := gin.New()
+	r.Use(HeaderPolicy(source))
+	r.POST("/v1/responses", func(c *gin.Context) {
+		// 模拟网关选定账号后写入请求上下文
+		ctx := context.WithValue(c.Request.Context(), ctxkey.AccountID, int64(7))
+		ctx = context.WithValue(ctx, ctxkey.Platform, service.PlatformOpenAI)
+		c.Request = c.Request.WithContext(ctx)
+		c.Header("Openai-Organization", "org-upstream")
+		c.Header("Openai-Processing-Ms", "12")
+		c.Header("X-Request-Id", "req_1")
+		c.Header("Content-Type", "application/json")
+		c.String(http.StatusOK, `{}`)
+	})
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/v1/responses", nil))
+
+	require.Equal(t, http.StatusOK, w.Code)
+	require.Empty(t, w.Header().Get("Openai-Organization"))
+	require.Empty(t, w.Header().Get("Openai-Processing-Ms"))
+	require.Empty(t, w.Header().Get("X-Request-Id"))
+	require.Equal(t, "application/json", w.Header().Get("Content-Type"))
+	require.Equal(t, "sub2api", w.Header().Get("X-Gateway"))
+	require.Empty(t, w.Header().Get("X-Other"))
+}
+
+func TestHeaderPolicySkipsWithoutResponseRules(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	source := &headerPolicySourceStub{settings: &service.HeaderPolicySettings{Enabled: true, Rules: []service.HeaderPolicyRule{
+		{Name: "request only", Enabled: true, SetHeaders: map[string]string{"X-Env": "prod"}},
+	}}}
+	var wrapped bool
+	r := gin.New()
+	r.Use(HeaderPolicy(source))
+	r.GET("/v1/models", func(c *gin.Context) {
+		_, wrapped = c.Writer.(*headerPolicyResponseWriter)
+		c.Status(http.StatusOK)
+	})
+
+	r.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/v1/models", nil))
+	require.False(t, wrapped)
+}
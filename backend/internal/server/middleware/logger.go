@@ -1,16 +1,70 @@
 package middleware
 
 import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"hash"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
 	"time"
 
+	"github.com/ShaohongDong/sub2api/internal/config"
 	"github.com/ShaohongDong/sub2api/internal/pkg/ctxkey"
 	"github.com/ShaohongDong/sub2api/internal/pkg/logger"
+	"github.com/ShaohongDong/sub2api/internal/util/logredact"
 	"github.com/gin-gonic/gin"
 	"go.uber.org/zap"
 )
 
+const redactedValue = "***"
+
+// accessLogCredentialHeaders 始终脱敏的凭证类请求头（小写）。
+// Sec-WebSocket-Protocol 可能以子协议形式携带 API Key（如 openai-insecure-api-key.*）。
+var accessLogCredentialHeaders = []string{
+	"authorization",
+	"proxy-authorization",
+	"x-api-key",
+	"x-goog-api-key",
+	"api-key",
+	"cookie",
+	"set-cookie",
+	"sec-websocket-protocol",
+}
+
+// accessLogCredentialQueryParams 始终脱敏的凭证类查询参数（小写），Gemini 兼容入口使用 ?key=
+var accessLogCredentialQueryParams = map[string]struct{}{
+	"key":          {},
+	"api_key":      {},
+	"access_token": {},
+	"token":        {},
+}
+
 // Logger 请求日志中间件
-func Logger() gin.HandlerFunc {
+//
+// 凭证请求头与凭证查询参数始终脱敏；cfg 控制是否附加请求头、请求体哈希，
+// 以及对指定 API Key 记录截断的请求体。
+func Logger(cfg config.AccessLogConfig) gin.HandlerFunc {
+	redactHeaders := make(map[string]struct{}, len(accessLogCredentialHeaders)+len(cfg.RedactHeaders))
+	for _, name := range accessLogCredentialHeaders {
+		redactHeaders[name] = struct{}{}
+	}
+	for _, name := range cfg.RedactHeaders {
+		if name = strings.ToLower(strings.TrimSpace(name)); name != "" {
+			redactHeaders[name] = struct{}{}
+		}
+	}
+	bodyKeyIDs := make(map[int64]struct{}, len(cfg.BodyAPIKeyIDs))
+	for _, id := range cfg.BodyAPIKeyIDs {
+		bodyKeyIDs[id] = struct{}{}
+	}
+	bodyMaxBytes := 0
+	if len(bodyKeyIDs) > 0 {
+		bodyMaxBytes = cfg.BodyMaxBytes
+	}
+
 	return func(c *gin.Context) {
 		// 开始时间
 		startTime := time.Now()
@@ -18,11 +72,18 @@ func Logger() gin.HandlerFunc {
 		// 请求路径
 		path := c.Request.URL.Path
 
+		// API Key 在路由组的鉴权中间件中才确定，请求体需在入口处先行捕获
+		var capture *accessLogBodyCapture
+		if (cfg.HashPrompts || bodyMaxBytes > 0) && c.Request.Body != nil && c.Request.Body != http.NoBody {
+			capture = newAccessLogBodyCapture(c.Request.Body, cfg.HashPrompts, bodyMaxBytes)
+			c.Request.Body = capture
+		}
+
 		// 处理请求
 		c.Next()
 
 		// 跳过健康检查等高频探针路径的日志
-		if path == "/health" || path == "/setup/status" {
+		if path == "/health" || path == "/health/details" || path == "/setup/status" {
 			return
 		}
 
@@ -46,6 +107,9 @@ func Logger() gin.HandlerFunc {
 			zap.String("method", method),
 			zap.String("path", path),
 		}
+		if rawQuery := c.Request.URL.RawQuery; rawQuery != "" {
+			fields = append(fields, zap.String("query", redactAccessLogQuery(rawQuery)))
+		}
 		if hasAccountID && accountID > 0 {
 			fields = append(fields, zap.Int64("account_id", accountID))
 		}
@@ -55,6 +119,28 @@ func Logger() gin.HandlerFunc {
 		if model != "" {
 			fields = append(fields, zap.String("model", model))
 		}
+		var apiKeyID int64
+		if apiKey, ok := GetAPIKeyFromContext(c); ok && apiKey != nil {
+			apiKeyID = apiKey.ID
+			fields = append(fields, zap.Int64("api_key_id", apiKeyID))
+		}
+		if cfg.Headers {
+			fields = append(fields, zap.Any("request_headers", redactAccessLogHeaders(c.Request.Header, redactHeaders)))
+		}
+		if capture != nil && capture.size > 0 {
+			if capture.hasher != nil {
+				fields = append(fields,
+					zap.String("prompt_sha256", hex.EncodeToString(capture.hasher.Sum(nil))),
+					zap.Int64("request_body_bytes", capture.size),
+				)
+			}
+			if _, ok := bodyKeyIDs[apiKeyID]; ok && apiKeyID > 0 {
+				fields = append(fields,
+					zap.String("request_body", logredact.RedactText(capture.buf.String())),
+					zap.Bool("request_body_truncated", capture.truncated),
+				)
+			}
+		}
 
 		l := logger.FromContext(c.Request.Context()).With(fields...)
 		l.Info("http request completed", zap.Time("completed_at", endTime))
@@ -64,3 +150,72 @@ func Logger() gin.HandlerFunc {
 		}
 	}
 }
+
+// accessLogBodyCapture 在处理器读取请求体时旁路计算哈希并保留前 maxBytes 字节，不改变读取结果。
+// 仅统计处理器实际读取的部分：未读取或中途放弃的请求体不会被补读。
+type accessLogBodyCapture struct {
+	io.ReadCloser
+	hasher    hash.Hash
+	buf       bytes.Buffer
+	maxBytes  int
+	size      int64
+	truncated bool
+}
+
+func newAccessLogBodyCapture(body io.ReadCloser, hashBody bool, maxBytes int) *accessLogBodyCapture {
+	capture := &accessLogBodyCapture{ReadCloser: body, maxBytes: maxBytes}
+	if hashBody {
+		capture.hasher = sha256.New()
+	}
+	return capture
+}
+
+func (b *accessLogBodyCapture) Read(p []byte) (int, error) {
+	n, err := b.ReadCloser.Read(p)
+	if n > 0 {
+		chunk := p[:n]
+		b.size += int64(n)
+		if b.hasher != nil {
+			_, _ = b.hasher.Write(chunk)
+		}
+		if remaining := b.maxBytes - b.buf.Len(); remaining > 0 {
+			if len(chunk) > remaining {
+				chunk = chunk[:remaining]
+				b.truncated = true
+			}
+			_, _ = b.buf.Write(chunk)
+		} else if b.maxBytes > 0 {
+			b.truncated = true
+		}
+	}
+	return n, err
+}
+
+// redactAccessLogHeaders 展开请求头并脱敏凭证类字段
+func redactAccessLogHeaders(header http.Header, redact map[string]struct{}) map[string]string {
+	out := make(map[string]string, len(header))
+	for name, values := range header {
+		lower := strings.ToLower(name)
+		if _, ok := redact[lower]; ok {
+			out[lower] = redactedValue
+			continue
+		}
+		out[lower] = strings.Join(values, ", ")
+	}
+	return out
+}
+
+// redactAccessLogQuery 脱敏查询串中的凭证参数，其余参数与顺序原样保留
+func redactAccessLogQuery(rawQuery string) string {
+	parts := strings.Split(rawQuery, "&")
+	for i, part := range parts {
+		name, _, _ := strings.Cut(part, "=")
+		if unescaped, err := url.QueryUnescape(name); err == nil {
+			name = unescaped
+		}
+		if _, ok := accessLogCredentialQueryParams[strings.ToLower(strings.TrimSpace(name))]; ok {
+			parts[i] = name + "=" + redactedValue
+		}
+	}
+	return strings.Join(parts, "&")
+}
@@ -101,6 +101,11 @@ func GoogleErrorWriter(c *gin.Context, status int, message string) {
 	})
 }
 
+// OllamaErrorWriter 按 Ollama API 规范输出错误
+func OllamaErrorWriter(c *gin.Context, status int, message string) {
+	c.JSON(status, gin.H{"error": message})
+}
+
 // RequireGroupAssignment 检查 API Key 是否已分配到分组，
 // 如果未分组且系统设置不允许未分组 Key 调度则返回 403。
 func RequireGroupAssignment(settingService *service.SettingService, writeError GatewayErrorWriter) gin.HandlerFunc {
@@ -0,0 +1,153 @@
+package middleware
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/ShaohongDong/sub2api/internal/service"
+
+	"github.com/gin-gonic/gin"
+	"github.com/tidwall/gjson"
+)
+
+// modelAliasSource 提供全局模型别名表（由 SettingService 实现，带进程内缓存）
+type modelAliasSource interface {
+	GetModelAliases(ctx context.Context) map[string]string
+}
+
+// ModelAlias 在路由与调度前按分组别名表、全局别名表解析请求模型并改写为上游模型，
+// 同时把响应中的模型名（JSON / SSE / NDJSON 的 "model"、Gemini 的 "modelVersion"）改回客户端请求的名称。
+// 必须位于 API Key 认证之后；Azure deployment 与 Bedrock 路径的模型不在此处理。
+func ModelAlias(source modelAliasSource) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if source == nil || c.Request == nil {
+			c.Next()
+			return
+		}
+		requested := requestModel(c)
+		if requested == "" {
+			c.Next()
+			return
+		}
+		var group *service.Group
+		if apiKey, ok := GetAPIKeyFromContext(c); ok {
+			group = apiKey.Group
+		}
+		upstream, ok := service.ResolveModelAlias(group, source.GetModelAliases(c.Request.Context()), requested)
+		if !ok || !rewriteRequestModel(c, upstream) {
+			c.Next()
+			return
+		}
+		c.Request = c.Request.WithContext(service.WithRequestedModel(c.Request.Context(), requested))
+
+		w := &modelAliasResponseWriter{ResponseWriter: c.Writer, replacement: modelAliasReplacement(requested)}
+		c.Writer = w
+		c.Next()
+		w.finish()
+		c.Writer = w.ResponseWriter
+	}
+}
+
+// requestModel 读取请求模型：Gemini 风格路径参数 /{model}:{action} 或 JSON 请求体顶层 "model"
+func requestModel(c *gin.Context) string {
+	if c.Param("deployment") != "" {
+		return ""
+	}
+	if modelAction := c.Param("modelAction"); modelAction != "" {
+		trimmed := strings.TrimPrefix(modelAction, "/")
+		idx := strings.LastIndex(trimmed, ":")
+		if idx <= 0 || strings.Contains(trimmed[:idx], "/") {
+			return ""
+		}
+		return trimmed[:idx]
+	}
+	switch c.Request.Method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions:
+		return ""
+	}
+	if c.Request.Body == nil {
+		return ""
+	}
+	body, err := io.ReadAll(c.Request.Body)
+	_ = c.Request.Body.Close()
+	c.Request.Body = io.NopCloser(bytes.NewReader(body))
+	if err != nil {
+		return ""
+	}
+	model := gjson.GetBytes(body, "model")
+	if model.Type != gjson.String {
+		return ""
+	}
+	return strings.TrimSpace(model.String())
+}
+
+// modelAliasFieldPattern 匹配 JSON 中未转义的 "model" / "modelVersion" 字符串字段
+var modelAliasFieldPattern = regexp.MustCompile(`"(model|modelVersion)"(\s*:\s*)"(?:[^"\\]|\\.)*"`)
+
+func modelAliasReplacement(requested string) []byte {
+	quoted := strconv.Quote(requested)
+	// regexp 模板中的 $ 需转义
+	return []byte(`"$1"$2` + strings.ReplaceAll(quoted, "$", "$$"))
+}
+
+// modelAliasResponseWriter 按行改写响应中的模型名；未完成的行缓存到下一次写入、Flush 或请求结束。
+// 改写会改变响应长度，因此移除上游透传的 Content-Length。
+type modelAliasResponseWriter struct {
+	gin.ResponseWriter
+	replacement []byte
+	pending     []byte
+}
+
+func (w *modelAliasResponseWriter) WriteHeader(code int) {
+	w.Header().Del("Content-Length")
+	w.ResponseWriter.WriteHeader(code)
+}
+
+func (w *modelAliasResponseWriter) Write(b []byte) (int, error) {
+	w.pending = append(w.pending, b...)
+	idx := bytes.LastIndexByte(w.pending, '\n')
+	if idx < 0 {
+		return len(b), nil
+	}
+	if err := w.forward(w.pending[:idx+1]); err != nil {
+		return 0, err
+	}
+	w.pending = append(w.pending[:0], w.pending[idx+1:]...)
+	return len(b), nil
+}
+
+func (w *modelAliasResponseWriter) WriteString(s string) (int, error) {
+	return w.Write([]byte(s))
+}
+
+// Written 缓存中有未输出的内容时也视为已写出，避免处理器在失败重试时重复写入响应体
+func (w *modelAliasResponseWriter) Written() bool {
+	return len(w.pending) > 0 || w.ResponseWriter.Written()
+}
+
+func (w *modelAliasResponseWriter) Flush() {
+	w.finish()
+	w.ResponseWriter.Flush()
+}
+
+// finish 输出缓存中剩余的内容
+func (w *modelAliasResponseWriter) finish() {
+	if len(w.pending) == 0 {
+		return
+	}
+	_ = w.forward(w.pending)
+	w.pending = w.pending[:0]
+}
+
+func (w *modelAliasResponseWriter) forward(b []byte) error {
+	if !w.ResponseWriter.Written() {
+		w.Header().Del("Content-Length")
+	}
+	_, err := w.ResponseWriter.Write(modelAliasFieldPattern.ReplaceAll(b, w.replacement))
+	return err
+}
@@ -0,0 +1,102 @@
+//go:build unit
+
+package middleware
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/ShaohongDong/sub2api/internal/service"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/require"
+)
+
+type modelAliasSourceStub map[string]string
+
+func (s modelAliasSourceStub) GetModelAliases(context.Context) map[string]string {
+	return s
+}
+
+func newModelAliasTestRouter(group *service.Group, global map[string]string, path string, handler gin.HandlerFunc) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.Use(func(c *gin.Context) {
+		c.Set(string(ContextKeyAPIKey), &service.APIKey{ID: 1, Group: group})
+		c.Next()
+	})
+	r.POST(path, ModelAlias(modelAliasSourceStub(global)), handler)
+	return r
+}
+
+func TestModelAliasRewritesRequestAndResponse(t *testing.T) {
+	var gotBody, gotRequested string
+	router := newModelAliasTestRouter(nil, map[string]string{"sonnet": "claude-sonnet-4-5"}, "/v1/messages", func(c *gin.Context) {
+		body, _ := io.ReadAll(c.Request.Body)
+		gotBody = string(body)
+		gotRequested, _ = service.RequestedModelFromContext(c.Request.Context())
+		c.Header("Content-Length", "999")
+		c.Data(http.StatusOK, "application/json", []byte(`{"id":"msg_1","model":"claude-sonnet-4-5","content":[{"type":"text","text":"say \"model\":\"x\""}]}`))
+	})
+
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodPost, "/v1/messages", strings.NewReader(`{"model":"sonnet","max_tokens":8}`))
+	router.ServeHTTP(w, req)
+
+	require.Equal(t, http.StatusOK, w.Code)
+	require.Equal(t, `{"model":"claude-sonnet-4-5","max_tokens":8}`, gotBody)
+	require.Equal(t, "sonnet", gotRequested)
+	require.Equal(t, `{"id":"msg_1","model":"sonnet","content":[{"type":"text","text":"say \"model\":\"x\""}]}`, w.Body.String())
+	require.Empty(t, w.Header().Get("Content-Length"))
+}
+
+func TestModelAliasRewritesStreamAcrossWrites(t *testing.T) {
+	router := newModelAliasTestRouter(nil, map[string]string{"gpt-*": "gpt-4.1"}, "/v1/chat/completions", func(c *gin.Context) {
+		c.Header("Content-Type", "text/event-stream")
+		c.Status(http.StatusOK)
+		_, _ = c.Writer.WriteString("data: {\"model\":\"gpt-4.1-2025")
+		_, _ = c.Writer.WriteString("-04-14\",\"choices\":[]}\n\n")
+		c.Writer.Flush()
+		_, _ = c.Writer.WriteString("data: [DONE]\n\n")
+	})
+
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodPost, "/v1/chat/completions", strings.NewReader(`{"model":"gpt-fast","stream":true}`))
+	router.ServeHTTP(w, req)
+
+	require.Equal(t, "data: {\"model\":\"gpt-fast\",\"choices\":[]}\n\ndata: [DONE]\n\n", w.Body.String())
+}
+
+func TestModelAliasGroupOverridesGlobalAndGeminiPath(t *testing.T) {
+	group := &service.Group{ModelAliases: map[string]string{"gemini-pro": "gemini-2.5-pro"}}
+	var gotParam string
+	router := newModelAliasTestRouter(group, map[string]string{"gemini-pro": "gemini-2.5-flash"}, "/v1beta/models/*modelAction", func(c *gin.Context) {
+		gotParam = c.Param("modelAction")
+		c.Data(http.StatusOK, "application/json", []byte(`{"candidates":[],"modelVersion": "gemini-2.5-pro"}`))
+	})
+
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodPost, "/v1beta/models/gemini-pro:generateContent", strings.NewReader(`{}`))
+	router.ServeHTTP(w, req)
+
+	require.Equal(t, "/gemini-2.5-pro:generateContent", gotParam)
+	require.Equal(t, `{"candidates":[],"modelVersion": "gemini-pro"}`, w.Body.String())
+}
+
+func TestModelAliasPassesThroughWithoutMatch(t *testing.T) {
+	var gotRequested bool
+	router := newModelAliasTestRouter(nil, map[string]string{"sonnet": "claude-sonnet-4-5"}, "/v1/messages", func(c *gin.Context) {
+		_, gotRequested = service.RequestedModelFromContext(c.Request.Context())
+		c.Data(http.StatusOK, "application/json", []byte(`{"model":"claude-opus-4"}`))
+	})
+
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodPost, "/v1/messages", strings.NewReader(`{"model":"claude-opus-4"}`))
+	router.ServeHTTP(w, req)
+
+	require.False(t, gotRequested)
+	require.Equal(t, `{"model":"claude-opus-4"}`, w.Body.String())
+}
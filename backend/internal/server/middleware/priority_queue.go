@@ -0,0 +1,59 @@
+package middleware
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+
+	infraerrors "github.com/ShaohongDong/sub2api/internal/pkg/errors"
+	"github.com/ShaohongDong/sub2api/internal/pkg/logger"
+	"github.com/ShaohongDong/sub2api/internal/service"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+// PriorityQueue 在账号调度前按分组排队推理请求（gateway.priority_queue）：
+// 分组在途请求达到上限时按 Key 优先级排队，队列已满或等待超时返回 429 并附 Retry-After。
+// 仅作用于 POST 请求（count_tokens 除外）；队列未启用（nil）或 Key 未分组时直接放行。
+func PriorityQueue(queue *service.PriorityRequestQueue, writeError GatewayErrorWriter) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if queue == nil || c.Request.Method != http.MethodPost || strings.HasSuffix(c.Request.URL.Path, "/count_tokens") {
+			c.Next()
+			return
+		}
+		apiKey, ok := GetAPIKeyFromContext(c)
+		if !ok || apiKey.GroupID == nil {
+			c.Next()
+			return
+		}
+		release, err := queue.Acquire(c.Request.Context(), *apiKey.GroupID, queue.PriorityOf(apiKey))
+		if err != nil {
+			if !errors.Is(err, service.ErrPriorityQueueFull) && !errors.Is(err, service.ErrPriorityQueueTimeout) {
+				// 客户端在排队期间断开
+				c.Abort()
+				return
+			}
+			logger.FromContext(c.Request.Context()).Warn("gateway.priority_queue_rejected",
+				zap.Int64("group_id", *apiKey.GroupID),
+				zap.Int64("api_key_id", apiKey.ID),
+				zap.Error(err),
+			)
+			c.Header("Retry-After", strconv.Itoa(queue.RetryAfterSeconds()))
+			writeError(c, http.StatusTooManyRequests, infraerrors.Message(err))
+			c.Abort()
+			return
+		}
+		defer release()
+		c.Next()
+	}
+}
+
+// RateLimitErrorWriter 输出限流错误（Anthropic 格式，error.message 同样可被 OpenAI 客户端解析）
+func RateLimitErrorWriter(c *gin.Context, status int, message string) {
+	c.JSON(status, gin.H{
+		"type":  "error",
+		"error": gin.H{"type": "rate_limit_error", "message": message},
+	})
+}
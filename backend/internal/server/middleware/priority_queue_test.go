@@ -0,0 +1,53 @@
+//go:build unit
+
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/ShaohongDong/sub2api/internal/config"
+	"github.com/ShaohongDong/sub2api/internal/service"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPriorityQueue_RejectsWithRetryAfterWhenFull(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	queue := service.NewPriorityRequestQueue(config.GatewayPriorityQueueConfig{
+		Enabled: true, MaxInFlightPerGroup: 1, MaxQueuePerGroup: 0, MaxWaitSeconds: 1, RetryAfterSeconds: 7,
+	})
+	groupID := int64(2)
+	release, err := queue.Acquire(t.Context(), groupID, 0)
+	require.NoError(t, err)
+
+	r := gin.New()
+	handled := 0
+	setKey := func(c *gin.Context) {
+		c.Set(string(ContextKeyAPIKey), &service.APIKey{ID: 1, GroupID: &groupID})
+	}
+	ok := func(c *gin.Context) {
+		handled++
+		c.Status(http.StatusOK)
+	}
+	r.POST("/v1/messages", setKey, PriorityQueue(queue, RateLimitErrorWriter), ok)
+	r.POST("/v1/messages/count_tokens", setKey, PriorityQueue(queue, RateLimitErrorWriter), ok)
+
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/v1/messages", nil))
+	require.Equal(t, http.StatusTooManyRequests, rec.Code)
+	require.Equal(t, "7", rec.Header().Get("Retry-After"))
+	require.Contains(t, rec.Body.String(), "rate_limit_error")
+
+	// count_tokens 不排队
+	rec = httptest.NewRecorder()
+	r.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/v1/messages/count_tokens", nil))
+	require.Equal(t, http.StatusOK, rec.Code)
+
+	release()
+	rec = httptest.NewRecorder()
+	r.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/v1/messages", nil))
+	require.Equal(t, http.StatusOK, rec.Code)
+	require.Equal(t, 2, handled)
+}
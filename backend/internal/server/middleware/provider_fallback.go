@@ -0,0 +1,220 @@
+package middleware
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"strconv"
+
+	"github.com/ShaohongDong/sub2api/internal/pkg/logger"
+	"github.com/ShaohongDong/sub2api/internal/service"
+
+	"github.com/gin-gonic/gin"
+	"github.com/tidwall/gjson"
+	"github.com/tidwall/sjson"
+	"go.uber.org/zap"
+)
+
+// ProviderFallback 包装按分组平台分发的推理处理器：分组配置了跨平台降级链时，
+// 若处理器最终以限流（429）或上游故障（5xx）结束且尚未向客户端输出任何内容，
+// 则按链上顺序改由下一个分组重新处理同一请求（目标平台的处理器负责格式转换），
+// 并按目标分组的别名表重新解析模型。已开始的流式响应不会降级。
+// 仅用于请求体顶层携带 "model" 的端点（Messages / Chat Completions / Responses）。
+func ProviderFallback(resolver routingGroupResolver, aliases modelAliasSource) func(gin.HandlerFunc) gin.HandlerFunc {
+	return func(next gin.HandlerFunc) gin.HandlerFunc {
+		return func(c *gin.Context) {
+			if resolver == nil || c.Request == nil || c.Request.Body == nil {
+				next(c)
+				return
+			}
+			apiKey, ok := GetAPIKeyFromContext(c)
+			if !ok || apiKey.Group == nil || len(apiKey.Group.FallbackChain) == 0 {
+				next(c)
+				return
+			}
+			body, err := io.ReadAll(c.Request.Body)
+			_ = c.Request.Body.Close()
+			c.Request.Body = io.NopCloser(bytes.NewReader(body))
+			if err != nil {
+				next(c)
+				return
+			}
+
+			primary := apiKey.Group
+			baseCtx := c.Request.Context()
+			baseHeader := c.Writer.Header().Clone()
+			requested, aliased := service.RequestedModelFromContext(baseCtx)
+			if !aliased {
+				requested = gjson.GetBytes(body, "model").String()
+			}
+
+			w := &fallbackResponseWriter{ResponseWriter: c.Writer}
+			c.Writer = w
+			next(c)
+
+			for _, groupID := range primary.FallbackChain {
+				if w.status == 0 || baseCtx.Err() != nil {
+					break
+				}
+				reqLog := logger.FromContext(baseCtx).With(
+					zap.Int64("from_group_id", primary.ID),
+					zap.Int64("fallback_group_id", groupID),
+					zap.Int("upstream_status", w.status),
+				)
+				target, err := resolver.ResolveRoutingGroup(baseCtx, groupID)
+				if err != nil {
+					reqLog.Warn("gateway.provider_fallback_group_unavailable", zap.Error(err))
+					continue
+				}
+				if !service.CanFallbackToGroup(primary, target) {
+					reqLog.Warn("gateway.provider_fallback_group_invalid",
+						zap.String("fallback_platform", target.Platform),
+						zap.String("fallback_subscription_type", target.SubscriptionType),
+						zap.String("fallback_status", target.Status),
+					)
+					continue
+				}
+
+				// 重置上一次尝试的状态：响应头、请求体、请求上下文与已缓存的解析结果
+				w.reset(baseHeader)
+				ctx := baseCtx
+				attemptBody := body
+				var aliasWriter *modelAliasResponseWriter
+				if requested != "" {
+					var global map[string]string
+					if aliases != nil {
+						global = aliases.GetModelAliases(baseCtx)
+					}
+					upstream, ok := service.ResolveModelAlias(target, global, requested)
+					if !ok {
+						upstream = requested
+					}
+					if upstream != gjson.GetBytes(body, "model").String() {
+						if rewritten, err := sjson.SetBytes(body, "model", upstream); err == nil {
+							attemptBody = rewritten
+						}
+					}
+					// 外层 ModelAlias 未改写时，由本次尝试负责把响应中的模型名改回客户端请求的名称
+					if !aliased && upstream != requested {
+						ctx = service.WithRequestedModel(ctx, requested)
+						aliasWriter = &modelAliasResponseWriter{ResponseWriter: w, replacement: modelAliasReplacement(requested)}
+					}
+				}
+				c.Request = c.Request.WithContext(ctx)
+				c.Request.Body = io.NopCloser(bytes.NewReader(attemptBody))
+				c.Request.ContentLength = int64(len(attemptBody))
+				c.Request.Header.Set("Content-Length", strconv.Itoa(len(attemptBody)))
+				c.Set(service.OpenAIParsedRequestBodyKey, nil)
+				c.Set(string(ContextKeyAPIKey), cloneAPIKeyWithGroup(apiKey, target))
+				setGroupContext(c, target)
+
+				reqLog.Info("gateway.provider_fallback", zap.String("fallback_platform", target.Platform))
+				if aliasWriter != nil {
+					c.Writer = aliasWriter
+					next(c)
+					aliasWriter.finish()
+					c.Writer = w
+				} else {
+					next(c)
+				}
+			}
+
+			w.commit()
+			c.Writer = w.ResponseWriter
+		}
+	}
+}
+
+func cloneAPIKeyWithGroup(apiKey *service.APIKey, group *service.Group) *service.APIKey {
+	cloned := *apiKey
+	cloned.GroupID = &group.ID
+	cloned.Group = group
+	return &cloned
+}
+
+// fallbackResponseWriter 在尚未输出任何内容时拦截可降级的错误响应（429 / 5xx），
+// 以便降级链改由下一个分组重试；其余响应直接透传。
+type fallbackResponseWriter struct {
+	gin.ResponseWriter
+	status int // 被拦截的错误状态码，0 表示未拦截
+	body   bytes.Buffer
+}
+
+func (w *fallbackResponseWriter) WriteHeader(code int) {
+	if w.status != 0 {
+		return
+	}
+	if !w.ResponseWriter.Written() && service.IsFallbackRetryableStatus(code) {
+		w.status = code
+		return
+	}
+	w.ResponseWriter.WriteHeader(code)
+}
+
+func (w *fallbackResponseWriter) WriteHeaderNow() {
+	if w.status != 0 {
+		return
+	}
+	w.ResponseWriter.WriteHeaderNow()
+}
+
+func (w *fallbackResponseWriter) Write(b []byte) (int, error) {
+	if w.status != 0 {
+		return w.body.Write(b)
+	}
+	return w.ResponseWriter.Write(b)
+}
+
+func (w *fallbackResponseWriter) WriteString(s string) (int, error) {
+	return w.Write([]byte(s))
+}
+
+func (w *fallbackResponseWriter) Status() int {
+	if w.status != 0 {
+		return w.status
+	}
+	return w.ResponseWriter.Status()
+}
+
+func (w *fallbackResponseWriter) Size() int {
+	if w.status != 0 {
+		return w.body.Len()
+	}
+	return w.ResponseWriter.Size()
+}
+
+// Written 拦截的错误响应视为已写出，处理器据此判断是否需要补写错误
+func (w *fallbackResponseWriter) Written() bool {
+	return w.status != 0 || w.ResponseWriter.Written()
+}
+
+func (w *fallbackResponseWriter) Flush() {
+	if w.status != 0 {
+		return
+	}
+	w.ResponseWriter.Flush()
+}
+
+// reset 丢弃拦截的错误响应，并把响应头恢复为首次处理前的状态
+func (w *fallbackResponseWriter) reset(header http.Header) {
+	w.status = 0
+	w.body.Reset()
+	current := w.Header()
+	for k := range current {
+		delete(current, k)
+	}
+	for k, v := range header {
+		current[k] = append([]string(nil), v...)
+	}
+}
+
+// commit 降级链耗尽后输出最后一次拦截的错误响应
+func (w *fallbackResponseWriter) commit() {
+	if w.status == 0 {
+		return
+	}
+	w.ResponseWriter.WriteHeader(w.status)
+	_, _ = w.ResponseWriter.Write(w.body.Bytes())
+	w.status = 0
+	w.body.Reset()
+}
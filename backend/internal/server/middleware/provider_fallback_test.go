@@ -0,0 +1,107 @@
+//go:build unit
+
+package middleware
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/ShaohongDong/sub2api/internal/service"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/require"
+	"github.com/tidwall/gjson"
+)
+
+type fallbackAttempt struct {
+	groupID  int64
+	platform string
+	model    string
+}
+
+// newProviderFallbackTestRouter 按分组返回预设状态码，记录每次尝试的分组与请求模型
+func newProviderFallbackTestRouter(apiKey *service.APIKey, groups routingGroupResolverStub, aliases map[string]string, statuses map[int64]int) (*gin.Engine, *[]fallbackAttempt) {
+	gin.SetMode(gin.TestMode)
+	attempts := &[]fallbackAttempt{}
+	r := gin.New()
+	r.Use(func(c *gin.Context) {
+		c.Set(string(ContextKeyAPIKey), apiKey)
+		c.Next()
+	})
+	providerFallback := ProviderFallback(groups, modelAliasSourceStub(aliases))
+	r.POST("/v1/messages", providerFallback(func(c *gin.Context) {
+		key, _ := GetAPIKeyFromContext(c)
+		body, _ := io.ReadAll(c.Request.Body)
+		model := gjson.GetBytes(body, "model").String()
+		*attempts = append(*attempts, fallbackAttempt{groupID: key.Group.ID, platform: key.Group.Platform, model: model})
+		c.Header("X-Attempt-Group", key.Group.Platform)
+		status := statuses[key.Group.ID]
+		if status == 0 {
+			status = http.StatusOK
+		}
+		c.JSON(status, gin.H{"model": model, "group": key.Group.ID})
+	}))
+	return r, attempts
+}
+
+func fallbackTestKey(chain ...int64) *service.APIKey {
+	groupID := int64(1)
+	return &service.APIKey{
+		ID:      9,
+		GroupID: &groupID,
+		Group: &service.Group{ID: 1, Platform: service.PlatformOpenAI, Status: service.StatusActive, Hydrated: true,
+			SubscriptionType: service.SubscriptionTypeStandard, FallbackChain: chain},
+	}
+}
+
+func fallbackTestGroups() routingGroupResolverStub {
+	return routingGroupResolverStub{
+		2: {ID: 2, Platform: service.PlatformAnthropic, Status: service.StatusActive, Hydrated: true, SubscriptionType: service.SubscriptionTypeStandard,
+			ModelAliases: map[string]string{"gpt-*": "claude-sonnet-4-5"}},
+		3: {ID: 3, Platform: service.PlatformOpenAI, Status: service.StatusActive, Hydrated: true, SubscriptionType: service.SubscriptionTypeStandard},
+		4: {ID: 4, Platform: service.PlatformAnthropic, Status: service.StatusActive, Hydrated: true, SubscriptionType: service.SubscriptionTypeSubscription},
+	}
+}
+
+func TestProviderFallbackRetriesNextProvider(t *testing.T) {
+	router, attempts := newProviderFallbackTestRouter(fallbackTestKey(4, 2, 3), fallbackTestGroups(), nil,
+		map[int64]int{1: http.StatusTooManyRequests})
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/v1/messages", strings.NewReader(`{"model":"gpt-5"}`)))
+
+	require.Equal(t, http.StatusOK, w.Code)
+	// 订阅分组 4 被跳过；分组 2 按自身别名表改写模型，响应中改回客户端请求的模型名
+	require.Equal(t, []fallbackAttempt{
+		{groupID: 1, platform: service.PlatformOpenAI, model: "gpt-5"},
+		{groupID: 2, platform: service.PlatformAnthropic, model: "claude-sonnet-4-5"},
+	}, *attempts)
+	require.Equal(t, "gpt-5", gjson.Get(w.Body.String(), "model").String())
+	require.Equal(t, int64(2), gjson.Get(w.Body.String(), "group").Int())
+	require.Equal(t, service.PlatformAnthropic, w.Header().Get("X-Attempt-Group"))
+}
+
+func TestProviderFallbackReturnsLastErrorWhenChainExhausted(t *testing.T) {
+	router, attempts := newProviderFallbackTestRouter(fallbackTestKey(2, 3), fallbackTestGroups(), nil,
+		map[int64]int{1: http.StatusTooManyRequests, 2: http.StatusBadGateway, 3: http.StatusServiceUnavailable})
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/v1/messages", strings.NewReader(`{"model":"gpt-5"}`)))
+
+	require.Equal(t, http.StatusServiceUnavailable, w.Code)
+	require.Len(t, *attempts, 3)
+	require.Equal(t, int64(3), gjson.Get(w.Body.String(), "group").Int())
+}
+
+func TestProviderFallbackSkipsNonRetryableErrors(t *testing.T) {
+	router, attempts := newProviderFallbackTestRouter(fallbackTestKey(2), fallbackTestGroups(), nil,
+		map[int64]int{1: http.StatusBadRequest})
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/v1/messages", strings.NewReader(`{"model":"gpt-5"}`)))
+
+	require.Equal(t, http.StatusBadRequest, w.Code)
+	require.Len(t, *attempts, 1)
+}
@@ -0,0 +1,63 @@
+package middleware
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"strconv"
+
+	"github.com/ShaohongDong/sub2api/internal/config"
+	"github.com/ShaohongDong/sub2api/internal/pkg/clientdetect"
+	"github.com/ShaohongDong/sub2api/internal/pkg/logger"
+	"github.com/ShaohongDong/sub2api/internal/service"
+
+	"github.com/gin-gonic/gin"
+	"github.com/tidwall/gjson"
+	"go.uber.org/zap"
+)
+
+// ReasoningDefaults 按 gateway.reasoning_defaults 为请求补充或强制推理强度。
+// format 为入口请求格式（service.ReasoningFormat*），优先级见 config.GatewayReasoningDefaultRule。
+// 需位于模型别名、路由规则与金丝雀分流之后，使规则按最终请求模型匹配。
+func ReasoningDefaults(cfg *config.Config, format string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if cfg == nil || len(cfg.Gateway.ReasoningDefaults) == 0 || c.Request == nil ||
+			c.Request.Method != http.MethodPost || c.Request.Body == nil {
+			c.Next()
+			return
+		}
+		body, err := io.ReadAll(c.Request.Body)
+		_ = c.Request.Body.Close()
+		c.Request.Body = io.NopCloser(bytes.NewReader(body))
+		if err != nil || !gjson.ValidBytes(body) {
+			c.Next()
+			return
+		}
+
+		info, ok := clientdetect.FromContext(c.Request.Context())
+		if !ok {
+			info = clientdetect.ParseHeaders(c.Request.Header)
+		}
+		var apiKeyID int64
+		if apiKey, ok := GetAPIKeyFromContext(c); ok {
+			apiKeyID = apiKey.ID
+		}
+		model := gjson.GetBytes(body, "model").String()
+		effort := service.ResolveReasoningDefault(cfg.Gateway.ReasoningDefaults, body, format, model, info, apiKeyID)
+		if effort == "" {
+			c.Next()
+			return
+		}
+		rewritten, ok := service.ApplyReasoningEffort(body, format, effort)
+		if !ok {
+			logger.FromContext(c.Request.Context()).Debug("gateway.reasoning_default_skipped",
+				zap.String("model", model), zap.String("effort", effort))
+			c.Next()
+			return
+		}
+		c.Request.Body = io.NopCloser(bytes.NewReader(rewritten))
+		c.Request.ContentLength = int64(len(rewritten))
+		c.Request.Header.Set("Content-Length", strconv.Itoa(len(rewritten)))
+		c.Next()
+	}
+}
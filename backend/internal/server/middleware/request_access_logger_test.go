@@ -2,14 +2,19 @@ package middleware
 
 import (
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
 	"net/http"
 	"net/http/httptest"
 	"strings"
 	"sync"
 	"testing"
 
+	"github.com/ShaohongDong/sub2api/internal/config"
 	"github.com/ShaohongDong/sub2api/internal/pkg/ctxkey"
 	"github.com/ShaohongDong/sub2api/internal/pkg/logger"
+	"github.com/ShaohongDong/sub2api/internal/service"
 	"github.com/gin-gonic/gin"
 )
 
@@ -112,12 +117,87 @@ func TestRequestLogger_KeepIncomingRequestID(t *testing.T) {
 	}
 }
 
+func TestRequestLogger_ReplacesInvalidIncomingRequestID(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.Use(RequestLogger())
+	r.GET("/t", func(c *gin.Context) { c.Status(http.StatusOK) })
+
+	for _, incoming := range []string{"has space", "bad\x01id", strings.Repeat("a", maxRequestIDLength+1)} {
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodGet, "/t", nil)
+		req.Header.Set(requestIDHeader, incoming)
+		r.ServeHTTP(w, req)
+		if got := w.Header().Get(requestIDHeader); got == "" || got == incoming {
+			t.Fatalf("incoming=%q should be replaced, header=%q", incoming, got)
+		}
+	}
+}
+
+func TestRequestLogger_MovesUpstreamRequestIDHeader(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.Use(RequestLogger())
+	r.GET("/t", func(c *gin.Context) {
+		// 模拟透传上游响应头
+		c.Writer.Header().Set("x-request-id", "req_upstream")
+		c.Data(http.StatusOK, "application/json", []byte(`{"ok":true}`))
+	})
+
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodGet, "/t", nil)
+	req.Header.Set(requestIDHeader, "rid-gw")
+	r.ServeHTTP(w, req)
+	if got := w.Header().Get(requestIDHeader); got != "rid-gw" {
+		t.Fatalf("X-Request-ID=%q, want rid-gw", got)
+	}
+	if got := w.Header().Get(UpstreamRequestIDHeader); got != "req_upstream" {
+		t.Fatalf("X-Upstream-Request-Id=%q, want req_upstream", got)
+	}
+	if w.Body.String() != `{"ok":true}` {
+		t.Fatalf("success body should be untouched, got %s", w.Body.String())
+	}
+}
+
+func TestRequestLogger_InjectsRequestIDIntoJSONErrorBody(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.Use(RequestLogger())
+	r.GET("/json", func(c *gin.Context) {
+		c.Header("Content-Length", "99")
+		c.JSON(http.StatusBadGateway, gin.H{"error": gin.H{"message": "upstream failed"}})
+	})
+	r.GET("/text", func(c *gin.Context) {
+		c.String(http.StatusBadRequest, "bad request")
+	})
+
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodGet, "/json", nil)
+	req.Header.Set(requestIDHeader, "rid-err")
+	r.ServeHTTP(w, req)
+	if w.Code != http.StatusBadGateway {
+		t.Fatalf("status=%d", w.Code)
+	}
+	if !strings.Contains(w.Body.String(), `"request_id":"rid-err"`) || !strings.Contains(w.Body.String(), "upstream failed") {
+		t.Fatalf("body=%s, want request_id injected", w.Body.String())
+	}
+	if w.Header().Get("Content-Length") != "" {
+		t.Fatalf("Content-Length should be dropped after injection")
+	}
+
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/text", nil))
+	if w.Body.String() != "bad request" {
+		t.Fatalf("non-JSON body should be untouched, got %s", w.Body.String())
+	}
+}
+
 func TestLogger_AccessLogIncludesCoreFields(t *testing.T) {
 	gin.SetMode(gin.TestMode)
 	sink := initMiddlewareTestLogger(t)
 
 	r := gin.New()
-	r.Use(Logger())
+	r.Use(Logger(config.AccessLogConfig{}))
 	r.Use(func(c *gin.Context) {
 		ctx := c.Request.Context()
 		ctx = context.WithValue(ctx, ctxkey.AccountID, int64(101))
@@ -185,7 +265,7 @@ func TestLogger_HealthPathSkipped(t *testing.T) {
 	sink := initMiddlewareTestLogger(t)
 
 	r := gin.New()
-	r.Use(Logger())
+	r.Use(Logger(config.AccessLogConfig{}))
 	r.GET("/health", func(c *gin.Context) {
 		c.Status(http.StatusOK)
 	})
@@ -207,7 +287,7 @@ func TestLogger_AccessLogDroppedWhenLevelWarn(t *testing.T) {
 
 	r := gin.New()
 	r.Use(RequestLogger())
-	r.Use(Logger())
+	r.Use(Logger(config.AccessLogConfig{}))
 	r.GET("/api/test", func(c *gin.Context) {
 		c.Status(http.StatusCreated)
 	})
@@ -226,3 +306,141 @@ func TestLogger_AccessLogDroppedWhenLevelWarn(t *testing.T) {
 		}
 	}
 }
+
+func findAccessLogEvent(t *testing.T, sink *testLogSink) *logger.LogEvent {
+	t.Helper()
+	for _, event := range sink.list() {
+		if event != nil && event.Message == "http request completed" {
+			return event
+		}
+	}
+	t.Fatalf("access log event not found")
+	return nil
+}
+
+func TestLogger_RedactsCredentialHeadersAndQuery(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	sink := initMiddlewareTestLogger(t)
+
+	r := gin.New()
+	r.Use(Logger(config.AccessLogConfig{Headers: true, RedactHeaders: []string{"X-Internal-Token"}}))
+	r.GET("/v1beta/models", func(c *gin.Context) {
+		c.Status(http.StatusOK)
+	})
+
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodGet, "/v1beta/models?alt=sse&key=AIza-secret&Access_Token=tok", nil)
+	req.Header.Set("Authorization", "Bearer sk-secret")
+	req.Header.Set("X-Goog-Api-Key", "AIza-secret")
+	req.Header.Set("X-Internal-Token", "internal-secret")
+	req.Header.Set("Anthropic-Version", "2023-06-01")
+	r.ServeHTTP(w, req)
+
+	event := findAccessLogEvent(t, sink)
+	if got := event.Fields["query"]; got != "alt=sse&key=***&Access_Token=***" {
+		t.Fatalf("query=%v", got)
+	}
+	headers, ok := event.Fields["request_headers"].(map[string]string)
+	if !ok {
+		t.Fatalf("request_headers type mismatch: %T", event.Fields["request_headers"])
+	}
+	for _, name := range []string{"authorization", "x-goog-api-key", "x-internal-token"} {
+		if headers[name] != "***" {
+			t.Fatalf("header %s not redacted: %v", name, headers[name])
+		}
+	}
+	if headers["anthropic-version"] != "2023-06-01" {
+		t.Fatalf("anthropic-version=%v", headers["anthropic-version"])
+	}
+}
+
+func TestLogger_HeadersOmittedByDefault(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	sink := initMiddlewareTestLogger(t)
+
+	r := gin.New()
+	r.Use(Logger(config.AccessLogConfig{}))
+	r.POST("/v1/messages", func(c *gin.Context) {
+		c.Status(http.StatusOK)
+	})
+
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodPost, "/v1/messages", strings.NewReader(`{"prompt":"hi"}`))
+	req.Header.Set("x-api-key", "sk-secret")
+	r.ServeHTTP(w, req)
+
+	event := findAccessLogEvent(t, sink)
+	for _, field := range []string{"request_headers", "prompt_sha256", "request_body"} {
+		if _, ok := event.Fields[field]; ok {
+			t.Fatalf("field %s should be omitted by default: %+v", field, event.Fields)
+		}
+	}
+}
+
+func TestLogger_HashPromptsAndBodyForSelectedKey(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	sink := initMiddlewareTestLogger(t)
+
+	body := `{"model":"claude","password":"p@ss","messages":[{"role":"user","content":"hello"}]}`
+	r := gin.New()
+	r.Use(Logger(config.AccessLogConfig{HashPrompts: true, BodyAPIKeyIDs: []int64{7}, BodyMaxBytes: 4096}))
+	r.Use(func(c *gin.Context) {
+		c.Set(string(ContextKeyAPIKey), &service.APIKey{ID: 7})
+		c.Next()
+	})
+	r.POST("/v1/messages", func(c *gin.Context) {
+		raw, err := io.ReadAll(c.Request.Body)
+		if err != nil || string(raw) != body {
+			t.Errorf("handler read body=%q err=%v", raw, err)
+		}
+		c.Status(http.StatusOK)
+	})
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/v1/messages", strings.NewReader(body)))
+
+	event := findAccessLogEvent(t, sink)
+	sum := sha256.Sum256([]byte(body))
+	if got := event.Fields["prompt_sha256"]; got != hex.EncodeToString(sum[:]) {
+		t.Fatalf("prompt_sha256=%v", got)
+	}
+	logged, _ := event.Fields["request_body"].(string)
+	if !strings.Contains(logged, `"content":"hello"`) || strings.Contains(logged, "p@ss") {
+		t.Fatalf("request_body not redacted as expected: %s", logged)
+	}
+	if event.Fields["request_body_truncated"] != false {
+		t.Fatalf("request_body_truncated=%v", event.Fields["request_body_truncated"])
+	}
+}
+
+func TestLogger_BodyTruncatedAndSkippedForOtherKeys(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	sink := initMiddlewareTestLogger(t)
+
+	keyID := int64(7)
+	r := gin.New()
+	r.Use(Logger(config.AccessLogConfig{BodyAPIKeyIDs: []int64{7}, BodyMaxBytes: 8}))
+	r.Use(func(c *gin.Context) {
+		c.Set(string(ContextKeyAPIKey), &service.APIKey{ID: keyID})
+		c.Next()
+	})
+	r.POST("/v1/messages", func(c *gin.Context) {
+		_, _ = io.Copy(io.Discard, c.Request.Body)
+		c.Status(http.StatusOK)
+	})
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/v1/messages", strings.NewReader("0123456789abcdef")))
+	event := findAccessLogEvent(t, sink)
+	if event.Fields["request_body"] != "01234567" || event.Fields["request_body_truncated"] != true {
+		t.Fatalf("unexpected body fields: %+v", event.Fields)
+	}
+
+	keyID = 8
+	sink2 := initMiddlewareTestLogger(t)
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/v1/messages", strings.NewReader("0123456789abcdef")))
+	if _, ok := findAccessLogEvent(t, sink2).Fields["request_body"]; ok {
+		t.Fatalf("request_body should only be logged for selected keys")
+	}
+}
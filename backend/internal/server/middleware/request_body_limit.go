@@ -3,13 +3,41 @@ package middleware
 import (
 	"net/http"
 
+	pkghttputil "github.com/ShaohongDong/sub2api/internal/pkg/httputil"
+
 	"github.com/gin-gonic/gin"
 )
 
 // RequestBodyLimit 使用 MaxBytesReader 限制请求体大小。
+// 声明的 Content-Length 已超过限制时直接返回 413，不读取请求体；
+// 分块传输等未声明长度的请求由 MaxBytesReader 在读取时截断，由处理器按各自协议返回 413。
 func RequestBodyLimit(maxBytes int64) gin.HandlerFunc {
 	return func(c *gin.Context) {
+		if c.Request.ContentLength > maxBytes {
+			abortWithBodyTooLarge(c, maxBytes)
+			return
+		}
 		c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, maxBytes)
 		c.Next()
 	}
 }
+
+// abortWithBodyTooLarge 输出请求体超限错误：Gemini 入口使用 Google 风格，其余使用 OpenAI 风格。
+// 请求体未被读取，告知客户端关闭连接，避免服务端继续接收剩余数据。
+func abortWithBodyTooLarge(c *gin.Context, maxBytes int64) {
+	c.Header("Connection", "close")
+	message := pkghttputil.BodyTooLargeMessage(maxBytes)
+	if allowGoogleQueryKey(c.Request.URL.Path) {
+		abortWithGoogleError(c, http.StatusRequestEntityTooLarge, message)
+		return
+	}
+	c.JSON(http.StatusRequestEntityTooLarge, gin.H{
+		"error": gin.H{
+			"message": message,
+			"type":    "invalid_request_error",
+			"param":   nil,
+			"code":    "request_too_large",
+		},
+	})
+	c.Abort()
+}
@@ -0,0 +1,78 @@
+//go:build unit
+
+package middleware
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/require"
+	"github.com/tidwall/gjson"
+)
+
+func newRequestBodyLimitTestRouter(limit int64, handlerCalled *bool) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.Use(RequestBodyLimit(limit))
+	handler := func(c *gin.Context) {
+		*handlerCalled = true
+		if _, err := io.ReadAll(c.Request.Body); err != nil {
+			c.Status(http.StatusRequestEntityTooLarge)
+			return
+		}
+		c.Status(http.StatusOK)
+	}
+	r.POST("/v1/chat/completions", handler)
+	r.POST("/v1beta/models/*modelAction", handler)
+	return r
+}
+
+func TestRequestBodyLimitRejectsDeclaredLengthWithoutReading(t *testing.T) {
+	called := false
+	router := newRequestBodyLimitTestRouter(16, &called)
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/v1/chat/completions", bytes.NewReader(bytes.Repeat([]byte("a"), 17))))
+
+	require.False(t, called)
+	require.Equal(t, http.StatusRequestEntityTooLarge, w.Code)
+	require.Equal(t, "close", w.Header().Get("Connection"))
+	require.Equal(t, "invalid_request_error", gjson.Get(w.Body.String(), "error.type").String())
+	require.Equal(t, "request_too_large", gjson.Get(w.Body.String(), "error.code").String())
+	require.Equal(t, "Request body too large, limit is 16B", gjson.Get(w.Body.String(), "error.message").String())
+}
+
+func TestRequestBodyLimitGoogleStyleForGeminiPaths(t *testing.T) {
+	called := false
+	router := newRequestBodyLimitTestRouter(16, &called)
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/v1beta/models/gemini-2.5-pro:generateContent", bytes.NewReader(bytes.Repeat([]byte("a"), 17))))
+
+	require.False(t, called)
+	require.Equal(t, http.StatusRequestEntityTooLarge, w.Code)
+	require.Equal(t, int64(http.StatusRequestEntityTooLarge), gjson.Get(w.Body.String(), "error.code").Int())
+}
+
+func TestRequestBodyLimitTruncatesUndeclaredLength(t *testing.T) {
+	called := false
+	router := newRequestBodyLimitTestRouter(16, &called)
+
+	req := httptest.NewRequest(http.MethodPost, "/v1/chat/completions", bytes.NewReader(bytes.Repeat([]byte("a"), 17)))
+	req.ContentLength = -1 // 分块传输
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	require.True(t, called)
+	require.Equal(t, http.StatusRequestEntityTooLarge, w.Code)
+
+	called = false
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/v1/chat/completions", bytes.NewReader(bytes.Repeat([]byte("a"), 16))))
+	require.True(t, called)
+	require.Equal(t, http.StatusOK, w.Code)
+}
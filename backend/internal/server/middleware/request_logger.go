@@ -1,19 +1,33 @@
 package middleware
 
 import (
+	"bytes"
 	"context"
+	"encoding/json"
+	"net/http"
 	"strings"
 
 	"github.com/ShaohongDong/sub2api/internal/pkg/ctxkey"
 	"github.com/ShaohongDong/sub2api/internal/pkg/logger"
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
+	"github.com/tidwall/sjson"
 	"go.uber.org/zap"
 )
 
-const requestIDHeader = "X-Request-ID"
+const (
+	requestIDHeader = "X-Request-ID"
+	// UpstreamRequestIDHeader 上游返回的 x-request-id（透传的上游响应头让位给网关自己的请求 ID）
+	UpstreamRequestIDHeader = "X-Upstream-Request-Id"
+	// maxRequestIDLength 客户端传入的请求 ID 最大长度，超长或含非法字符时改为服务端生成
+	maxRequestIDLength = 128
+)
 
 // RequestLogger 在请求入口注入 request-scoped logger。
+//
+// 请求 ID 优先沿用客户端的 X-Request-ID（需为不超过 128 个字符的可见 ASCII），否则生成 UUID；
+// 写入 context 供日志、trace、用量记录与上游请求使用，并保证作为最终的 X-Request-ID 响应头返回
+// （上游透传的同名响应头改为 X-Upstream-Request-Id），JSON 错误响应体同时带上 request_id 字段。
 func RequestLogger() gin.HandlerFunc {
 	return func(c *gin.Context) {
 		if c.Request == nil {
@@ -21,11 +35,12 @@ func RequestLogger() gin.HandlerFunc {
 			return
 		}
 
-		requestID := strings.TrimSpace(c.GetHeader(requestIDHeader))
+		requestID := normalizeRequestID(c.GetHeader(requestIDHeader))
 		if requestID == "" {
 			requestID = uuid.NewString()
 		}
 		c.Header(requestIDHeader, requestID)
+		c.Writer = &requestIDWriter{ResponseWriter: c.Writer, requestID: requestID}
 
 		ctx := context.WithValue(c.Request.Context(), ctxkey.RequestID, requestID)
 		clientRequestID, _ := ctx.Value(ctxkey.ClientRequestID).(string)
@@ -43,3 +58,85 @@ func RequestLogger() gin.HandlerFunc {
 		c.Next()
 	}
 }
+
+// normalizeRequestID 校验客户端传入的请求 ID，不合法时返回空字符串
+func normalizeRequestID(raw string) string {
+	id := strings.TrimSpace(raw)
+	if id == "" || len(id) > maxRequestIDLength {
+		return ""
+	}
+	for i := 0; i < len(id); i++ {
+		if id[i] < 33 || id[i] > 126 {
+			return ""
+		}
+	}
+	return id
+}
+
+// requestIDWriter 在响应头写出前恢复网关的 X-Request-ID，并向 JSON 错误响应体注入 request_id
+type requestIDWriter struct {
+	gin.ResponseWriter
+	requestID string
+}
+
+// fixHeader 上游透传的 x-request-id 覆盖了网关的请求 ID 时，将其移到 X-Upstream-Request-Id
+func (w *requestIDWriter) fixHeader() {
+	if w.ResponseWriter.Written() {
+		return
+	}
+	h := w.Header()
+	if current := h.Get(requestIDHeader); current != w.requestID {
+		if current != "" && h.Get(UpstreamRequestIDHeader) == "" {
+			h.Set(UpstreamRequestIDHeader, current)
+		}
+		h.Set(requestIDHeader, w.requestID)
+	}
+}
+
+func (w *requestIDWriter) WriteHeaderNow() {
+	w.fixHeader()
+	w.ResponseWriter.WriteHeaderNow()
+}
+
+func (w *requestIDWriter) Write(b []byte) (int, error) {
+	if w.ResponseWriter.Written() {
+		return w.ResponseWriter.Write(b)
+	}
+	out := w.injectErrorBody(b)
+	w.fixHeader()
+	if _, err := w.ResponseWriter.Write(out); err != nil {
+		return 0, err
+	}
+	// 按调用方写入的长度返回，避免 io.Copy 等把注入后的长度差当作写入错误
+	return len(b), nil
+}
+
+func (w *requestIDWriter) WriteString(s string) (int, error) {
+	if w.ResponseWriter.Written() {
+		return w.ResponseWriter.WriteString(s)
+	}
+	return w.Write([]byte(s))
+}
+
+func (w *requestIDWriter) Flush() {
+	w.fixHeader()
+	w.ResponseWriter.Flush()
+}
+
+// injectErrorBody 在一次性写出的 JSON 错误响应体（对象）中设置 request_id；
+// 响应体因此变长，须同时去掉上游透传的 Content-Length
+func (w *requestIDWriter) injectErrorBody(b []byte) []byte {
+	if w.ResponseWriter.Status() < http.StatusBadRequest || !strings.Contains(w.Header().Get("Content-Type"), "json") {
+		return b
+	}
+	trimmed := bytes.TrimSpace(b)
+	if len(trimmed) == 0 || trimmed[0] != '{' || !json.Valid(trimmed) {
+		return b
+	}
+	out, err := sjson.SetBytes(trimmed, "request_id", w.requestID)
+	if err != nil {
+		return b
+	}
+	w.Header().Del("Content-Length")
+	return out
+}
@@ -0,0 +1,229 @@
+package middleware
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ShaohongDong/sub2api/internal/config"
+	"github.com/ShaohongDong/sub2api/internal/service"
+	"github.com/gin-gonic/gin"
+)
+
+var (
+	// ErrRouteTTFBTimeout 客户端在首字节超时内未收到任何数据
+	ErrRouteTTFBTimeout = errors.New("time to first byte exceeded the route limit")
+	// ErrRouteTotalTimeout 请求总时长超过路由上限
+	ErrRouteTotalTimeout = errors.New("request exceeded the route total timeout")
+)
+
+// IsRouteTimeout 判断请求 context 的取消原因是否为路由超时（而非客户端断开）
+func IsRouteTimeout(cause error) bool {
+	return errors.Is(cause, ErrRouteTTFBTimeout) || errors.Is(cause, ErrRouteTotalTimeout)
+}
+
+// managementAPIPrefix 管理与用户 API 前缀，不应用运行时默认超时（仅作用于网关请求）
+const managementAPIPrefix = "/api/v1/"
+
+type routeTimeoutRule struct {
+	prefix string
+	cfg    config.GatewayRouteTimeoutConfig
+}
+
+// RouteTimeouts 按 POST 路径前缀（最长前缀优先）应用首字节超时、总超时与写出刷新策略。
+//
+// 未匹配任何前缀的网关 POST 请求使用运行时设置中的默认超时（未设置时不限制）。
+// 超时通过取消请求 context 终止上游请求（取消原因可用 IsRouteTimeout 识别）；
+// 未写出任何数据时返回 504，流式响应已开始时补发一条 SSE error 事件。
+func RouteTimeouts(routes map[string]config.GatewayRouteTimeoutConfig) gin.HandlerFunc {
+	rules := make([]routeTimeoutRule, 0, len(routes))
+	for prefix, cfg := range routes {
+		rules = append(rules, routeTimeoutRule{prefix: prefix, cfg: cfg})
+	}
+	sort.Slice(rules, func(i, j int) bool { return len(rules[i].prefix) > len(rules[j].prefix) })
+
+	return func(c *gin.Context) {
+		if c.Request.Method != http.MethodPost {
+			c.Next()
+			return
+		}
+		var cfg *config.GatewayRouteTimeoutConfig
+		for i := range rules {
+			if strings.HasPrefix(c.Request.URL.Path, rules[i].prefix) {
+				cfg = &rules[i].cfg
+				break
+			}
+		}
+		if cfg == nil && !strings.HasPrefix(c.Request.URL.Path, managementAPIPrefix) {
+			cfg = service.RuntimeDefaultRouteTimeout()
+		}
+		if cfg == nil {
+			c.Next()
+			return
+		}
+
+		ctx, cancel := context.WithCancelCause(c.Request.Context())
+		defer cancel(nil)
+		w := newRouteTimeoutWriter(c.Writer, cfg)
+		defer w.stop()
+		if cfg.TTFBTimeoutSeconds > 0 {
+			ttfb := time.AfterFunc(time.Duration(cfg.TTFBTimeoutSeconds)*time.Second, func() {
+				if !w.Written() {
+					cancel(ErrRouteTTFBTimeout)
+				}
+			})
+			defer ttfb.Stop()
+		}
+		if cfg.TotalTimeoutSeconds > 0 {
+			total := time.AfterFunc(time.Duration(cfg.TotalTimeoutSeconds)*time.Second, func() {
+				cancel(ErrRouteTotalTimeout)
+			})
+			defer total.Stop()
+		}
+
+		c.Writer = w
+		c.Request = c.Request.WithContext(ctx)
+		c.Next()
+
+		if cause := context.Cause(ctx); IsRouteTimeout(cause) {
+			writeRouteTimeoutError(c, w, cause)
+		}
+		w.stop()
+	}
+}
+
+func writeRouteTimeoutError(c *gin.Context, w *routeTimeoutWriter, cause error) {
+	message := cause.Error()
+	if !w.Written() {
+		c.JSON(http.StatusGatewayTimeout, gin.H{
+			"error": gin.H{
+				"type":    "timeout_error",
+				"message": message,
+			},
+		})
+		return
+	}
+	if !strings.HasPrefix(w.Header().Get("Content-Type"), "text/event-stream") {
+		return
+	}
+	errorEvent := "event: error\ndata: " + `{"error":{"type":"timeout_error","message":` + strconv.Quote(message) + `}}` + "\n\n"
+	if _, err := fmt.Fprint(w, errorEvent); err == nil {
+		w.Flush()
+	}
+}
+
+// routeTimeoutWriter 记录是否已写出数据，并按配置缓冲写出、合并刷新。
+type routeTimeoutWriter struct {
+	gin.ResponseWriter
+
+	mu            sync.Mutex
+	buf           *bufio.Writer
+	flushInterval time.Duration
+	lastFlush     time.Time
+	dirty         bool
+	wrote         bool
+	done          chan struct{}
+	stopOnce      sync.Once
+}
+
+func newRouteTimeoutWriter(w gin.ResponseWriter, cfg *config.GatewayRouteTimeoutConfig) *routeTimeoutWriter {
+	rw := &routeTimeoutWriter{
+		ResponseWriter: w,
+		flushInterval:  time.Duration(cfg.FlushIntervalMs) * time.Millisecond,
+		done:           make(chan struct{}),
+	}
+	if cfg.WriteBufferBytes > 0 {
+		rw.buf = bufio.NewWriterSize(w, cfg.WriteBufferBytes)
+	}
+	if rw.flushInterval > 0 {
+		go rw.flushLoop()
+	}
+	return rw
+}
+
+// flushLoop 在合并刷新间隔到期后补刷被推迟的数据
+func (w *routeTimeoutWriter) flushLoop() {
+	ticker := time.NewTicker(w.flushInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			w.mu.Lock()
+			if w.dirty {
+				w.flushLocked()
+			}
+			w.mu.Unlock()
+		case <-w.done:
+			return
+		}
+	}
+}
+
+func (w *routeTimeoutWriter) Write(p []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.wrote = true
+	if w.buf != nil {
+		return w.buf.Write(p)
+	}
+	return w.ResponseWriter.Write(p)
+}
+
+func (w *routeTimeoutWriter) WriteString(s string) (int, error) {
+	return w.Write([]byte(s))
+}
+
+func (w *routeTimeoutWriter) Flush() {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.flushInterval > 0 && time.Since(w.lastFlush) < w.flushInterval {
+		w.dirty = true
+		return
+	}
+	w.flushLocked()
+}
+
+func (w *routeTimeoutWriter) flushLocked() {
+	if w.buf != nil {
+		_ = w.buf.Flush()
+	}
+	w.ResponseWriter.Flush()
+	w.lastFlush = time.Now()
+	w.dirty = false
+}
+
+func (w *routeTimeoutWriter) Written() bool {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.wrote || w.ResponseWriter.Written()
+}
+
+func (w *routeTimeoutWriter) Size() int {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	size := w.ResponseWriter.Size()
+	if w.buf != nil && w.buf.Buffered() > 0 {
+		// gin 未写出时 Size 为 -1
+		return max(size, 0) + w.buf.Buffered()
+	}
+	return size
+}
+
+// stop 停止合并刷新并写出剩余缓冲（可重复调用）
+func (w *routeTimeoutWriter) stop() {
+	w.stopOnce.Do(func() {
+		close(w.done)
+		w.mu.Lock()
+		defer w.mu.Unlock()
+		if w.dirty || (w.buf != nil && w.buf.Buffered() > 0) {
+			w.flushLocked()
+		}
+	})
+}
@@ -0,0 +1,89 @@
+package middleware
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/ShaohongDong/sub2api/internal/config"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/require"
+)
+
+func newRouteTimeoutTestRouter(routes map[string]config.GatewayRouteTimeoutConfig, handler gin.HandlerFunc) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.Use(RouteTimeouts(routes))
+	r.POST("/*path", handler)
+	return r
+}
+
+func TestRouteTimeouts_TTFB(t *testing.T) {
+	var cause error
+	r := newRouteTimeoutTestRouter(map[string]config.GatewayRouteTimeoutConfig{
+		"/v1":           {TTFBTimeoutSeconds: 60},
+		"/v1/responses": {TTFBTimeoutSeconds: 1},
+	}, func(c *gin.Context) {
+		<-c.Request.Context().Done()
+		cause = context.Cause(c.Request.Context())
+	})
+
+	rec := httptest.NewRecorder()
+	start := time.Now()
+	r.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/v1/responses", nil))
+	require.Less(t, time.Since(start), 5*time.Second)
+	require.True(t, IsRouteTimeout(cause))
+	require.Equal(t, http.StatusGatewayTimeout, rec.Code)
+	require.Contains(t, rec.Body.String(), `"type":"timeout_error"`)
+}
+
+func TestRouteTimeouts_TotalTimeoutAfterStreamStarted(t *testing.T) {
+	r := newRouteTimeoutTestRouter(map[string]config.GatewayRouteTimeoutConfig{
+		"/v1/chat/completions": {TTFBTimeoutSeconds: 1, TotalTimeoutSeconds: 2},
+	}, func(c *gin.Context) {
+		c.Writer.Header().Set("Content-Type", "text/event-stream")
+		_, _ = io.WriteString(c.Writer, "data: {}\n\n")
+		c.Writer.Flush()
+		// 已写出首字节：首字节超时不再生效，仅受总超时约束
+		<-c.Request.Context().Done()
+		require.ErrorIs(t, context.Cause(c.Request.Context()), ErrRouteTotalTimeout)
+	})
+
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/v1/chat/completions", nil))
+	require.Equal(t, http.StatusOK, rec.Code)
+	require.Equal(t, "data: {}\n\nevent: error\ndata: {\"error\":{\"type\":\"timeout_error\",\"message\":\"request exceeded the route total timeout\"}}\n\n", rec.Body.String())
+}
+
+func TestRouteTimeouts_BufferedWritesFlushed(t *testing.T) {
+	r := newRouteTimeoutTestRouter(map[string]config.GatewayRouteTimeoutConfig{
+		"/v1/messages": {FlushIntervalMs: 10000, WriteBufferBytes: 4096},
+	}, func(c *gin.Context) {
+		for i := 0; i < 3; i++ {
+			_, _ = io.WriteString(c.Writer, "data: x\n\n")
+			c.Writer.Flush()
+		}
+		require.True(t, c.Writer.Written())
+	})
+
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/v1/messages", nil))
+	require.Equal(t, "data: x\n\ndata: x\n\ndata: x\n\n", rec.Body.String())
+}
+
+func TestRouteTimeouts_Unmatched(t *testing.T) {
+	r := newRouteTimeoutTestRouter(map[string]config.GatewayRouteTimeoutConfig{
+		"/v1/responses": {TTFBTimeoutSeconds: 1},
+	}, func(c *gin.Context) {
+		_, isRouteWriter := c.Writer.(*routeTimeoutWriter)
+		require.False(t, isRouteWriter)
+		c.Status(http.StatusNoContent)
+	})
+
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/v1/embeddings", nil))
+	require.Equal(t, http.StatusNoContent, rec.Code)
+}
@@ -0,0 +1,130 @@
+package middleware
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"slices"
+
+	"github.com/ShaohongDong/sub2api/internal/pkg/clientdetect"
+	"github.com/ShaohongDong/sub2api/internal/pkg/logger"
+	"github.com/ShaohongDong/sub2api/internal/service"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+// routingRuleSource 提供路由规则配置（由 SettingService 实现，带进程内缓存）
+type routingRuleSource interface {
+	GetRoutingRules(ctx context.Context) *service.RoutingRuleSettings
+}
+
+// routingGroupResolver 加载路由规则的目标分组（由 APIKeyService 实现）
+type routingGroupResolver interface {
+	ResolveRoutingGroup(ctx context.Context, groupID int64) (*service.Group, error)
+}
+
+// crossPlatformRoutePaths 按分组平台分派并自动转换格式的端点：路由规则在这些端点可将请求改由其他平台的分组调度
+// （如 OpenAI 分组的 Claude 模型请求改由 Anthropic OAuth 分组处理），其余端点仅允许同平台分组。
+// 值为该端点处理器支持的平台，nil 表示降级链支持的全部平台
+var crossPlatformRoutePaths = map[string][]string{
+	"/v1/messages":              nil,
+	"/v1/messages/count_tokens": nil,
+	"/v1/responses":             nil,
+	"/v1/chat/completions":      nil,
+	"/responses":                nil,
+	"/chat/completions":         nil,
+	// Gemini 原生格式：Gemini 分组直连，OpenAI 分组转换为 Responses
+	"/v1beta/models/*modelAction": {service.PlatformGemini, service.PlatformOpenAI},
+}
+
+// RoutingRules 按优先级匹配路由规则（模型、客户端类型、请求头、Key 标签、来源分组、请求体大小），
+// 命中后将本次请求改由规则的目标分组调度。目标分组不存在或不满足 service.CanRouteToGroup
+// （格式转换端点为 service.CanRouteAcrossPlatforms）时保持原分组。
+// 必须位于 API Key 认证与模型别名之后。
+func RoutingRules(source routingRuleSource, resolver routingGroupResolver) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if source == nil || resolver == nil || c.Request == nil {
+			c.Next()
+			return
+		}
+		apiKey, ok := GetAPIKeyFromContext(c)
+		if !ok || apiKey.Group == nil {
+			c.Next()
+			return
+		}
+		settings := source.GetRoutingRules(c.Request.Context())
+		if settings == nil || !settings.Enabled || len(settings.Rules) == 0 {
+			c.Next()
+			return
+		}
+
+		info, ok := clientdetect.FromContext(c.Request.Context())
+		if !ok {
+			info = clientdetect.ParseHeaders(c.Request.Header)
+		}
+		rule := service.MatchRoutingRule(settings, &service.RoutingRequest{
+			Model:       requestModel(c),
+			ClientType:  info.Type,
+			Header:      c.Request.Header,
+			APIKey:      apiKey,
+			RequestSize: requestBodySize(c),
+		})
+		if rule == nil || rule.TargetGroupID == apiKey.Group.ID {
+			c.Next()
+			return
+		}
+
+		reqLog := logger.FromContext(c.Request.Context()).With(
+			zap.String("routing_rule", rule.Name),
+			zap.Int64("from_group_id", apiKey.Group.ID),
+			zap.Int64("target_group_id", rule.TargetGroupID),
+		)
+		target, err := resolver.ResolveRoutingGroup(c.Request.Context(), rule.TargetGroupID)
+		if err != nil {
+			reqLog.Warn("gateway.routing_rule_target_unavailable", zap.Error(err))
+			c.Next()
+			return
+		}
+		if !canRouteRequestToGroup(c, apiKey.Group, target) {
+			reqLog.Warn("gateway.routing_rule_target_invalid",
+				zap.String("target_platform", target.Platform),
+				zap.String("target_subscription_type", target.SubscriptionType),
+				zap.String("target_status", target.Status),
+			)
+			c.Next()
+			return
+		}
+
+		c.Set(string(ContextKeyAPIKey), cloneAPIKeyWithGroup(apiKey, target))
+		setGroupContext(c, target)
+		reqLog.Debug("gateway.routing_rule_matched")
+		c.Next()
+	}
+}
+
+// canRouteRequestToGroup 判断当前请求能否改由 target 分组调度
+func canRouteRequestToGroup(c *gin.Context, from, target *service.Group) bool {
+	platforms, ok := crossPlatformRoutePaths[c.FullPath()]
+	if !ok || (platforms != nil && (!slices.Contains(platforms, from.Platform) || !slices.Contains(platforms, target.Platform))) {
+		return service.CanRouteToGroup(from, target)
+	}
+	return service.CanRouteAcrossPlatforms(from, target)
+}
+
+// requestBodySize 返回请求体字节数；未声明 Content-Length 时读取请求体计算
+func requestBodySize(c *gin.Context) int64 {
+	if c.Request.ContentLength >= 0 {
+		return c.Request.ContentLength
+	}
+	if c.Request.Body == nil {
+		return 0
+	}
+	body, err := io.ReadAll(c.Request.Body)
+	_ = c.Request.Body.Close()
+	c.Request.Body = io.NopCloser(bytes.NewReader(body))
+	if err != nil {
+		return 0
+	}
+	return int64(len(body))
+}
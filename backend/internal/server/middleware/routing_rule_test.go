@@ -0,0 +1,197 @@
+//go:build unit
+
+package middleware
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/ShaohongDong/sub2api/internal/pkg/ctxkey"
+	"github.com/ShaohongDong/sub2api/internal/service"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/require"
+)
+
+type routingRuleSourceStub struct {
+	settings *service.RoutingRuleSettings
+}
+
+func (s *routingRuleSourceStub) GetRoutingRules(context.Context) *service.RoutingRuleSettings {
+	return s.settings
+}
+
+type routingGroupResolverStub map[int64]*service.Group
+
+func (s routingGroupResolverStub) ResolveRoutingGroup(_ context.Context, groupID int64) (*service.Group, error) {
+	group, ok := s[groupID]
+	if !ok {
+		return nil, service.ErrGroupNotFound
+	}
+	return group, nil
+}
+
+func newRoutingRuleTestRouter(apiKey *service.APIKey, settings *service.RoutingRuleSettings, groups routingGroupResolverStub) (*gin.Engine, *int64, *int64) {
+	gin.SetMode(gin.TestMode)
+	var gotGroupID, gotCtxGroupID int64
+	r := gin.New()
+	r.Use(func(c *gin.Context) {
+		c.Set(string(ContextKeyAPIKey), apiKey)
+		c.Next()
+	})
+	r.POST("/v1/messages", RoutingRules(&routingRuleSourceStub{settings: settings}, groups), func(c *gin.Context) {
+		key, _ := GetAPIKeyFromContext(c)
+		gotGroupID = *key.GroupID
+		if group, ok := c.Request.Context().Value(ctxkey.Group).(*service.Group); ok {
+			gotCtxGroupID = group.ID
+		}
+		c.Status(http.StatusOK)
+	})
+	return r, &gotGroupID, &gotCtxGroupID
+}
+
+func routingTestKey() *service.APIKey {
+	groupID := int64(1)
+	return &service.APIKey{
+		ID:      9,
+		GroupID: &groupID,
+		Group:   &service.Group{ID: 1, Platform: service.PlatformAnthropic, Status: service.StatusActive, SubscriptionType: service.SubscriptionTypeStandard},
+		Tags:    []string{"pro"},
+	}
+}
+
+func TestRoutingRulesRoutesBigRequestsToTargetGroup(t *testing.T) {
+	settings := &service.RoutingRuleSettings{Enabled: true, Rules: []service.RoutingRule{{
+		Name:            "big context",
+		Enabled:         true,
+		Models:          []string{"claude-*"},
+		KeyTags:         []string{"pro"},
+		MinRequestBytes: 64,
+		TargetGroupID:   2,
+	}}}
+	groups := routingGroupResolverStub{2: {ID: 2, Platform: service.PlatformAnthropic, Status: service.StatusActive, Hydrated: true, SubscriptionType: service.SubscriptionTypeStandard}}
+
+	apiKey := routingTestKey()
+	router, gotGroupID, gotCtxGroupID := newRoutingRuleTestRouter(apiKey, settings, groups)
+
+	w := httptest.NewRecorder()
+	big := `{"model":"claude-sonnet-4-5","messages":[{"role":"user","content":"` + strings.Repeat("x", 100) + `"}]}`
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/v1/messages", strings.NewReader(big)))
+	require.Equal(t, http.StatusOK, w.Code)
+	require.Equal(t, int64(2), *gotGroupID)
+	require.Equal(t, int64(2), *gotCtxGroupID)
+	require.Equal(t, int64(1), *apiKey.GroupID, "the cached key must not be mutated")
+
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/v1/messages", strings.NewReader(`{"model":"claude-sonnet-4-5"}`)))
+	require.Equal(t, int64(1), *gotGroupID)
+}
+
+func TestRoutingRulesKeepsGroupWhenTargetInvalid(t *testing.T) {
+	settings := &service.RoutingRuleSettings{Enabled: true, Rules: []service.RoutingRule{
+		{Name: "cross platform", Enabled: true, TargetGroupID: 3},
+	}}
+	groups := routingGroupResolverStub{3: {ID: 3, Platform: service.PlatformSora, Status: service.StatusActive}}
+	router, gotGroupID, _ := newRoutingRuleTestRouter(routingTestKey(), settings, groups)
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/v1/messages", strings.NewReader(`{"model":"claude-sonnet-4-5"}`)))
+	require.Equal(t, http.StatusOK, w.Code)
+	require.Equal(t, int64(1), *gotGroupID)
+
+	settings.Rules[0].TargetGroupID = 404
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/v1/messages", strings.NewReader(`{"model":"claude-sonnet-4-5"}`)))
+	require.Equal(t, int64(1), *gotGroupID)
+}
+
+func TestRoutingRulesKeepsTenantKeysInOwnGroups(t *testing.T) {
+	tenantA, tenantB := int64(10), int64(20)
+	settings := &service.RoutingRuleSettings{Enabled: true, Rules: []service.RoutingRule{
+		// 绑定到租户 B 分组的规则：租户 A 的 Key 不在来源分组内，不命中
+		{Name: "tenant b only", Enabled: true, Priority: 1, SourceGroupIDs: []int64{2}, TargetGroupID: 3},
+		// 未绑定来源分组但目标属于租户 B：命中后因租户不一致被拒绝
+		{Name: "cross tenant", Enabled: true, Priority: 2, TargetGroupID: 3},
+	}}
+	groups := routingGroupResolverStub{3: {ID: 3, Platform: service.PlatformAnthropic, Status: service.StatusActive, Hydrated: true, SubscriptionType: service.SubscriptionTypeStandard, TenantID: &tenantB}}
+	apiKey := routingTestKey()
+	apiKey.Group.TenantID = &tenantA
+	router, gotGroupID, _ := newRoutingRuleTestRouter(apiKey, settings, groups)
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/v1/messages", strings.NewReader(`{"model":"claude-sonnet-4-5"}`)))
+	require.Equal(t, http.StatusOK, w.Code)
+	require.Equal(t, int64(1), *gotGroupID)
+
+	// 同租户且 Key 位于来源分组时才改道
+	groups[3].TenantID = &tenantA
+	settings.Rules[0].SourceGroupIDs = []int64{1}
+	router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodPost, "/v1/messages", strings.NewReader(`{"model":"claude-sonnet-4-5"}`)))
+	require.Equal(t, int64(3), *gotGroupID)
+}
+
+func TestRoutingRulesRoutesAcrossPlatformsOnConvertibleEndpoints(t *testing.T) {
+	settings := &service.RoutingRuleSettings{Enabled: true, Rules: []service.RoutingRule{
+		{Name: "claude subscriptions", Enabled: true, Models: []string{"claude-*"}, TargetGroupID: 3},
+	}}
+	groups := routingGroupResolverStub{3: {ID: 3, Platform: service.PlatformAnthropic, Status: service.StatusActive, Hydrated: true, SubscriptionType: service.SubscriptionTypeStandard}}
+	groupID := int64(1)
+	apiKey := &service.APIKey{
+		ID:      9,
+		GroupID: &groupID,
+		Group:   &service.Group{ID: 1, Platform: service.PlatformOpenAI, Status: service.StatusActive, SubscriptionType: service.SubscriptionTypeStandard},
+	}
+	router, gotGroupID, gotCtxGroupID := newRoutingRuleTestRouter(apiKey, settings, groups)
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/v1/messages", strings.NewReader(`{"model":"claude-sonnet-4-5"}`)))
+	require.Equal(t, http.StatusOK, w.Code)
+	require.Equal(t, int64(3), *gotGroupID)
+	require.Equal(t, int64(3), *gotCtxGroupID)
+
+	// 其他端点不做格式转换，保持同平台限制
+	var embeddingsGroupID int64
+	router.POST("/v1/embeddings", RoutingRules(&routingRuleSourceStub{settings: settings}, groups), func(c *gin.Context) {
+		key, _ := GetAPIKeyFromContext(c)
+		embeddingsGroupID = *key.GroupID
+		c.Status(http.StatusOK)
+	})
+	router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodPost, "/v1/embeddings", strings.NewReader(`{"model":"claude-sonnet-4-5"}`)))
+	require.Equal(t, int64(1), embeddingsGroupID)
+}
+
+func TestRoutingRulesRoutesGeminiNativeRequestsBetweenGeminiAndOpenAI(t *testing.T) {
+	settings := &service.RoutingRuleSettings{Enabled: true, Rules: []service.RoutingRule{
+		{Name: "code assist pool", Enabled: true, Models: []string{"gemini-*"}, TargetGroupID: 3},
+	}}
+	groups := routingGroupResolverStub{3: {ID: 3, Platform: service.PlatformGemini, Status: service.StatusActive, Hydrated: true, SubscriptionType: service.SubscriptionTypeStandard}}
+	groupID := int64(1)
+	newKey := func(platform string) *service.APIKey {
+		return &service.APIKey{
+			ID:      9,
+			GroupID: &groupID,
+			Group:   &service.Group{ID: 1, Platform: platform, Status: service.StatusActive, SubscriptionType: service.SubscriptionTypeStandard},
+		}
+	}
+	serve := func(apiKey *service.APIKey) int64 {
+		gin.SetMode(gin.TestMode)
+		var got int64
+		r := gin.New()
+		r.Use(func(c *gin.Context) {
+			c.Set(string(ContextKeyAPIKey), apiKey)
+			c.Next()
+		})
+		r.POST("/v1beta/models/*modelAction", RoutingRules(&routingRuleSourceStub{settings: settings}, groups), func(c *gin.Context) {
+			key, _ := GetAPIKeyFromContext(c)
+			got = *key.GroupID
+			c.Status(http.StatusOK)
+		})
+		r.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodPost, "/v1beta/models/gemini-2.5-pro:generateContent", strings.NewReader(`{"contents":[]}`)))
+		return got
+	}
+
+	require.Equal(t, int64(3), serve(newKey(service.PlatformOpenAI)))
+	// Anthropic 分组的处理器不支持 Gemini 原生格式，不跨平台改派
+	require.Equal(t, int64(1), serve(newKey(service.PlatformAnthropic)))
+}
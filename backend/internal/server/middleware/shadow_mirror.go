@@ -0,0 +1,287 @@
+package middleware
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/ShaohongDong/sub2api/internal/pkg/logger"
+	"github.com/ShaohongDong/sub2api/internal/service"
+
+	"github.com/gin-gonic/gin"
+	"github.com/tidwall/gjson"
+	"github.com/tidwall/sjson"
+	"go.uber.org/zap"
+)
+
+const (
+	// shadowMirrorMaxConcurrent 本实例同时进行的影子请求上限，已满时跳过镜像而不是排队
+	shadowMirrorMaxConcurrent = 32
+	// shadowMirrorTimeout 单个影子请求的最长处理时间
+	shadowMirrorTimeout = 5 * time.Minute
+)
+
+var shadowMirrorSlots = make(chan struct{}, shadowMirrorMaxConcurrent)
+
+// shadowMirrorSource 提供影子流量配置（由 SettingService 实现，带进程内缓存）
+type shadowMirrorSource interface {
+	GetShadowMirrors(ctx context.Context) *service.ShadowMirrorSettings
+}
+
+// ShadowMirror 包装推理处理器：命中影子流量规则的请求按 Percent 比例在主请求完成后，
+// 以相同请求体异步再处理一次（目标分组和/或目标模型），影子请求使用独立的响应缓冲与上下文，
+// 不影响客户端响应，且由 APIKey.Shadow 标记跳过计费。结果计入影子流量指标，
+// 规则开启 StoreResponses 时同时保留主/影子响应体（截断）供对比。
+// 目标分组不存在或不满足 service.CanFallbackToGroup 时不镜像。
+// 仅用于请求体顶层携带 "model" 的端点（Messages / Chat Completions / Responses）。
+func ShadowMirror(source shadowMirrorSource, resolver routingGroupResolver) func(gin.HandlerFunc) gin.HandlerFunc {
+	return func(next gin.HandlerFunc) gin.HandlerFunc {
+		return func(c *gin.Context) {
+			if source == nil || resolver == nil || c.Request == nil || c.Request.Body == nil {
+				next(c)
+				return
+			}
+			apiKey, ok := GetAPIKeyFromContext(c)
+			if !ok || apiKey.Group == nil || apiKey.Shadow {
+				next(c)
+				return
+			}
+			settings := source.GetShadowMirrors(c.Request.Context())
+			if settings == nil || !settings.Enabled || len(settings.Rules) == 0 {
+				next(c)
+				return
+			}
+			body, err := io.ReadAll(c.Request.Body)
+			_ = c.Request.Body.Close()
+			c.Request.Body = io.NopCloser(bytes.NewReader(body))
+			if err != nil {
+				next(c)
+				return
+			}
+			model := gjson.GetBytes(body, "model").String()
+			rule := service.MatchShadowMirrorRule(settings, apiKey.Group.ID, model)
+			if rule == nil || !service.RollPercent(rule.Percent) {
+				next(c)
+				return
+			}
+			target, ok := resolveShadowTarget(c, resolver, apiKey.Group, rule)
+			if !ok {
+				next(c)
+				return
+			}
+			select {
+			case shadowMirrorSlots <- struct{}{}:
+			default:
+				service.RecordShadowMirrorSkipped(rule.Name)
+				next(c)
+				return
+			}
+
+			// 在主请求处理前复制上下文与请求，避免与主请求对请求头、上下文键的修改产生竞争
+			shadow := c.Copy()
+			shadowReq := c.Request.Clone(context.WithoutCancel(c.Request.Context()))
+			shadowRule := *rule
+
+			var tee *shadowTeeWriter
+			if rule.StoreResponses {
+				tee = &shadowTeeWriter{ResponseWriter: c.Writer}
+				c.Writer = tee
+			}
+			start := time.Now()
+			next(c)
+			sample := service.ShadowMirrorSample{
+				Rule:              shadowRule.Name,
+				At:                start,
+				Model:             model,
+				ShadowModel:       model,
+				SourceGroupID:     apiKey.Group.ID,
+				TargetGroupID:     target.ID,
+				PrimaryStatus:     c.Writer.Status(),
+				PrimaryDurationMs: time.Since(start).Milliseconds(),
+			}
+			if tee != nil {
+				c.Writer = tee.ResponseWriter
+				sample.PrimaryBody = tee.body.String()
+			}
+			if shadowRule.TargetModel != "" {
+				sample.ShadowModel = shadowRule.TargetModel
+			}
+
+			shadowKey := cloneAPIKeyWithGroup(apiKey, target)
+			shadowKey.Shadow = true
+			go runShadowMirror(next, shadow, shadowReq, shadowKey, body, shadowRule.StoreResponses, sample)
+		}
+	}
+}
+
+// resolveShadowTarget 返回影子请求调度的分组：未配置目标分组时沿用原分组。
+// 影子请求不做跨平台降级，因此目标分组的降级链被清空。
+func resolveShadowTarget(c *gin.Context, resolver routingGroupResolver, source *service.Group, rule *service.ShadowMirrorRule) (*service.Group, bool) {
+	target := source
+	if rule.TargetGroupID > 0 && rule.TargetGroupID != source.ID {
+		reqLog := logger.FromContext(c.Request.Context()).With(
+			zap.String("shadow_rule", rule.Name),
+			zap.Int64("from_group_id", source.ID),
+			zap.Int64("target_group_id", rule.TargetGroupID),
+		)
+		resolved, err := resolver.ResolveRoutingGroup(c.Request.Context(), rule.TargetGroupID)
+		if err != nil {
+			reqLog.Warn("gateway.shadow_mirror_group_unavailable", zap.Error(err))
+			return nil, false
+		}
+		if !service.CanFallbackToGroup(source, resolved) {
+			reqLog.Warn("gateway.shadow_mirror_group_invalid",
+				zap.String("target_platform", resolved.Platform),
+				zap.String("target_subscription_type", resolved.SubscriptionType),
+				zap.String("target_status", resolved.Status),
+			)
+			return nil, false
+		}
+		target = resolved
+	}
+	cloned := *target
+	cloned.FallbackChain = nil
+	return &cloned, true
+}
+
+// runShadowMirror 在独立的上下文与响应缓冲中处理影子请求并记录对比结果
+func runShadowMirror(next gin.HandlerFunc, shadow *gin.Context, req *http.Request, apiKey *service.APIKey, body []byte, store bool, sample service.ShadowMirrorSample) {
+	defer func() { <-shadowMirrorSlots }()
+	ctx, cancel := context.WithTimeout(req.Context(), shadowMirrorTimeout)
+	defer cancel()
+
+	if sample.ShadowModel != sample.Model {
+		if rewritten, err := sjson.SetBytes(body, "model", sample.ShadowModel); err == nil {
+			body = rewritten
+		}
+	}
+	req = req.WithContext(ctx)
+	req.Body = io.NopCloser(bytes.NewReader(body))
+	req.ContentLength = int64(len(body))
+	req.Header.Set("Content-Length", strconv.Itoa(len(body)))
+
+	w := newShadowResponseWriter(store)
+	shadow.Request = req
+	shadow.Writer = w
+	shadow.Set(string(ContextKeyAPIKey), apiKey)
+	shadow.Set(service.OpenAIParsedRequestBodyKey, nil)
+	setGroupContext(shadow, apiKey.Group)
+
+	start := time.Now()
+	func() {
+		defer func() {
+			if r := recover(); r != nil {
+				sample.ShadowError = fmt.Sprintf("panic: %v", r)
+			}
+		}()
+		next(shadow)
+	}()
+	sample.ShadowDurationMs = time.Since(start).Milliseconds()
+	sample.ShadowStatus = w.Status()
+	if sample.ShadowError == "" && ctx.Err() != nil {
+		sample.ShadowError = ctx.Err().Error()
+	}
+	if store {
+		sample.ShadowBody = w.body.String()
+	}
+	service.RecordShadowMirrorResult(sample)
+	if sample.ShadowError != "" {
+		logger.FromContext(ctx).Warn("gateway.shadow_mirror_failed",
+			zap.String("shadow_rule", sample.Rule),
+			zap.String("error", sample.ShadowError),
+		)
+	}
+}
+
+// shadowTeeWriter 透传主请求响应，同时保留前 service.ShadowMirrorMaxBodyBytes 字节供对比
+type shadowTeeWriter struct {
+	gin.ResponseWriter
+	body bytes.Buffer
+}
+
+func (w *shadowTeeWriter) Write(b []byte) (int, error) {
+	appendCapped(&w.body, b)
+	return w.ResponseWriter.Write(b)
+}
+
+func (w *shadowTeeWriter) WriteString(s string) (int, error) {
+	appendCapped(&w.body, []byte(s))
+	return w.ResponseWriter.WriteString(s)
+}
+
+func appendCapped(buf *bytes.Buffer, b []byte) {
+	if remain := service.ShadowMirrorMaxBodyBytes - buf.Len(); remain > 0 {
+		if len(b) > remain {
+			b = b[:remain]
+		}
+		buf.Write(b)
+	}
+}
+
+// shadowResponseWriter 影子请求的响应缓冲：不连接任何客户端，store 为 false 时丢弃响应体
+type shadowResponseWriter struct {
+	header http.Header
+	status int
+	size   int
+	store  bool
+	body   bytes.Buffer
+}
+
+func newShadowResponseWriter(store bool) *shadowResponseWriter {
+	return &shadowResponseWriter{header: make(http.Header), size: -1, store: store}
+}
+
+func (w *shadowResponseWriter) Header() http.Header { return w.header }
+
+func (w *shadowResponseWriter) WriteHeader(code int) {
+	if code > 0 && !w.Written() {
+		w.status = code
+	}
+}
+
+func (w *shadowResponseWriter) WriteHeaderNow() {
+	if !w.Written() {
+		w.size = 0
+	}
+}
+
+func (w *shadowResponseWriter) Write(b []byte) (int, error) {
+	w.WriteHeaderNow()
+	w.size += len(b)
+	if w.store {
+		appendCapped(&w.body, b)
+	}
+	return len(b), nil
+}
+
+func (w *shadowResponseWriter) WriteString(s string) (int, error) {
+	return w.Write([]byte(s))
+}
+
+func (w *shadowResponseWriter) Status() int {
+	if w.status == 0 {
+		return http.StatusOK
+	}
+	return w.status
+}
+
+func (w *shadowResponseWriter) Size() int { return w.size }
+
+func (w *shadowResponseWriter) Written() bool { return w.size != -1 }
+
+func (w *shadowResponseWriter) Flush() { w.WriteHeaderNow() }
+
+func (w *shadowResponseWriter) CloseNotify() <-chan bool { return make(chan bool) }
+
+func (w *shadowResponseWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	return nil, nil, errors.New("shadow response writer does not support hijacking")
+}
+
+func (w *shadowResponseWriter) Pusher() http.Pusher { return nil }
@@ -0,0 +1,111 @@
+//go:build unit
+
+package middleware
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ShaohongDong/sub2api/internal/service"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/require"
+	"github.com/tidwall/gjson"
+)
+
+type shadowMirrorSourceStub struct {
+	settings *service.ShadowMirrorSettings
+}
+
+func (s shadowMirrorSourceStub) GetShadowMirrors(context.Context) *service.ShadowMirrorSettings {
+	return s.settings
+}
+
+type shadowAttempt struct {
+	groupID int64
+	model   string
+	shadow  bool
+}
+
+func newShadowMirrorTestRouter(settings *service.ShadowMirrorSettings, groups routingGroupResolverStub) (*gin.Engine, chan shadowAttempt) {
+	gin.SetMode(gin.TestMode)
+	attempts := make(chan shadowAttempt, 4)
+	r := gin.New()
+	r.Use(func(c *gin.Context) {
+		c.Set(string(ContextKeyAPIKey), fallbackTestKey())
+		c.Next()
+	})
+	shadowMirror := ShadowMirror(shadowMirrorSourceStub{settings: settings}, groups)
+	r.POST("/v1/messages", shadowMirror(func(c *gin.Context) {
+		key, _ := GetAPIKeyFromContext(c)
+		body, _ := io.ReadAll(c.Request.Body)
+		model := gjson.GetBytes(body, "model").String()
+		attempts <- shadowAttempt{groupID: key.Group.ID, model: model, shadow: key.Shadow}
+		status := http.StatusOK
+		if key.Shadow {
+			status = http.StatusBadGateway
+		}
+		c.JSON(status, gin.H{"model": model, "group": key.Group.ID})
+	}))
+	return r, attempts
+}
+
+func TestShadowMirrorCopiesRequestWithoutAffectingResponse(t *testing.T) {
+	settings := &service.ShadowMirrorSettings{Enabled: true, Rules: []service.ShadowMirrorRule{
+		{Name: "next", Enabled: true, Models: []string{"gpt-*"}, Percent: 100, TargetGroupID: 3, TargetModel: "gpt-next", StoreResponses: true},
+	}}
+	router, attempts := newShadowMirrorTestRouter(settings, fallbackTestGroups())
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/v1/messages", strings.NewReader(`{"model":"gpt-5"}`)))
+
+	require.Equal(t, http.StatusOK, w.Code)
+	require.JSONEq(t, `{"model":"gpt-5","group":1}`, w.Body.String())
+	require.Equal(t, shadowAttempt{groupID: 1, model: "gpt-5"}, <-attempts)
+	select {
+	case got := <-attempts:
+		require.Equal(t, shadowAttempt{groupID: 3, model: "gpt-next", shadow: true}, got)
+	case <-time.After(2 * time.Second):
+		t.Fatal("shadow request was not sent")
+	}
+
+	// 结果在影子请求结束后异步记录
+	var sample service.ShadowMirrorSample
+	require.Eventually(t, func() bool {
+		for _, s := range service.GetShadowMirrorSnapshot().Samples {
+			if s.Rule == "next" {
+				sample = s
+				return true
+			}
+		}
+		return false
+	}, 2*time.Second, 10*time.Millisecond)
+	require.Equal(t, http.StatusOK, sample.PrimaryStatus)
+	require.Equal(t, http.StatusBadGateway, sample.ShadowStatus)
+	require.JSONEq(t, `{"model":"gpt-5","group":1}`, sample.PrimaryBody)
+	require.JSONEq(t, `{"model":"gpt-next","group":3}`, sample.ShadowBody)
+	require.Equal(t, int64(3), sample.TargetGroupID)
+	require.Equal(t, "gpt-next", sample.ShadowModel)
+}
+
+func TestShadowMirrorSkipsInvalidTargetGroup(t *testing.T) {
+	settings := &service.ShadowMirrorSettings{Enabled: true, Rules: []service.ShadowMirrorRule{
+		{Name: "subscription", Enabled: true, Models: []string{"gpt-*"}, Percent: 100, TargetGroupID: 4},
+	}}
+	router, attempts := newShadowMirrorTestRouter(settings, fallbackTestGroups())
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/v1/messages", strings.NewReader(`{"model":"gpt-5"}`)))
+	require.Equal(t, http.StatusOK, w.Code)
+
+	require.Equal(t, shadowAttempt{groupID: 1, model: "gpt-5"}, <-attempts)
+	select {
+	case got := <-attempts:
+		t.Fatalf("unexpected shadow request: %+v", got)
+	case <-time.After(100 * time.Millisecond):
+	}
+}
@@ -0,0 +1,90 @@
+package middleware
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/ShaohongDong/sub2api/internal/config"
+	"github.com/ShaohongDong/sub2api/internal/pkg/logger"
+	"github.com/ShaohongDong/sub2api/internal/pkg/openai"
+	"github.com/ShaohongDong/sub2api/internal/service"
+
+	"github.com/gin-gonic/gin"
+	"github.com/tidwall/gjson"
+	"go.uber.org/zap"
+)
+
+// SystemPrompt 按入口格式向请求注入系统提示词：先应用管理员为 API Key 设置的系统提示词，
+// 再应用首条命中的 gateway.system_prompts 路由规则（按入口路径与请求模型匹配）。
+// format 为入口请求格式（service.ReasoningFormat* / service.SystemPromptFormat*）。
+// 需位于模型别名、路由规则与本地审核之后：规则按最终请求模型匹配，注入内容不参与审核；
+// 请求体随后由各网关按目标平台转换，注入的系统提示词随之转换。
+// Codex CLI 的 Responses 请求一律按 append 注入，保证 Codex 自带的指令块仍在 instructions 最前。
+func SystemPrompt(cfg *config.Config, format string) gin.HandlerFunc {
+	var rules []config.GatewaySystemPromptRule
+	forceCodexCLI := false
+	if cfg != nil {
+		rules = cfg.Gateway.SystemPrompts
+		forceCodexCLI = cfg.Gateway.ForceCodexCLI
+	}
+	return func(c *gin.Context) {
+		if c.Request == nil || c.Request.Method != http.MethodPost || c.Request.Body == nil {
+			c.Next()
+			return
+		}
+		apiKey, _ := GetAPIKeyFromContext(c)
+		if len(rules) == 0 && (apiKey == nil || apiKey.SystemPrompt == "") {
+			c.Next()
+			return
+		}
+		body, err := io.ReadAll(c.Request.Body)
+		_ = c.Request.Body.Close()
+		c.Request.Body = io.NopCloser(bytes.NewReader(body))
+		if err != nil || !gjson.ValidBytes(body) {
+			c.Next()
+			return
+		}
+
+		model := systemPromptRequestModel(c, body)
+		preserveCodex := format == service.ReasoningFormatResponses &&
+			(forceCodexCLI || openai.IsCodexOfficialClientByHeaders(c.GetHeader("User-Agent"), c.GetHeader("originator")))
+		modeFor := func(mode string) string {
+			if preserveCodex {
+				return service.SystemPromptModeAppend
+			}
+			return mode
+		}
+		rewritten, changed := body, false
+		if apiKey != nil && apiKey.SystemPrompt != "" {
+			if out, ok := service.ApplySystemPrompt(rewritten, format, modeFor(apiKey.SystemPromptMode), apiKey.SystemPrompt); ok {
+				rewritten, changed = out, true
+			}
+		}
+		if rule := service.MatchSystemPromptRule(rules, c.Request.URL.Path, model); rule != nil {
+			if out, ok := service.ApplySystemPrompt(rewritten, format, modeFor(rule.Mode), rule.Prompt); ok {
+				rewritten, changed = out, true
+			}
+		}
+		if !changed {
+			logger.FromContext(c.Request.Context()).Debug("gateway.system_prompt_skipped",
+				zap.String("model", model), zap.String("format", format))
+			c.Next()
+			return
+		}
+		c.Request.Body = io.NopCloser(bytes.NewReader(rewritten))
+		c.Request.ContentLength = int64(len(rewritten))
+		c.Request.Header.Set("Content-Length", strconv.Itoa(len(rewritten)))
+		c.Next()
+	}
+}
+
+// systemPromptRequestModel 读取请求模型：请求体顶层 "model"，或 Gemini 风格路径参数 /{model}:{action}
+func systemPromptRequestModel(c *gin.Context, body []byte) string {
+	if model := gjson.GetBytes(body, "model"); model.Type == gjson.String {
+		return strings.TrimSpace(model.String())
+	}
+	return requestModel(c)
+}
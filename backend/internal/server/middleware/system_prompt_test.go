@@ -0,0 +1,99 @@
+//go:build unit
+
+package middleware
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/ShaohongDong/sub2api/internal/config"
+	"github.com/ShaohongDong/sub2api/internal/service"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/require"
+	"github.com/tidwall/gjson"
+)
+
+func newSystemPromptTestRouter(cfg *config.Config, apiKey *service.APIKey, format string) (*gin.Engine, *string) {
+	gin.SetMode(gin.TestMode)
+	got := new(string)
+	r := gin.New()
+	r.Use(func(c *gin.Context) {
+		if apiKey != nil {
+			c.Set(string(ContextKeyAPIKey), apiKey)
+		}
+		c.Next()
+	})
+	handler := func(c *gin.Context) {
+		body, _ := io.ReadAll(c.Request.Body)
+		*got = string(body)
+		c.Status(http.StatusOK)
+	}
+	r.POST("/v1/messages", SystemPrompt(cfg, format), handler)
+	r.POST("/v1/responses", SystemPrompt(cfg, format), handler)
+	r.POST("/v1beta/models/*modelAction", SystemPrompt(cfg, format), handler)
+	return r, got
+}
+
+func TestSystemPromptKeyThenRouteRule(t *testing.T) {
+	cfg := &config.Config{Gateway: config.GatewayConfig{SystemPrompts: []config.GatewaySystemPromptRule{
+		{Paths: []string{"/v1/messages"}, Models: []string{"claude-*"}, Mode: service.SystemPromptModePrepend, Prompt: "Org"},
+	}}}
+	key := &service.APIKey{ID: 1, SystemPrompt: "Team", SystemPromptMode: service.SystemPromptModeReplace}
+	r, got := newSystemPromptTestRouter(cfg, key, service.ReasoningFormatAnthropic)
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/v1/messages", strings.NewReader(`{"model":"claude-sonnet-4","system":"Client"}`)))
+	require.Equal(t, http.StatusOK, w.Code)
+	require.Equal(t, "Org\n\nTeam", gjson.Get(*got, "system").String())
+
+	// 路由规则不匹配时只应用 Key 级系统提示词
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/v1/messages", strings.NewReader(`{"model":"gpt-5","system":"Client"}`)))
+	require.Equal(t, "Team", gjson.Get(*got, "system").String())
+}
+
+func TestSystemPromptGeminiModelFromPath(t *testing.T) {
+	cfg := &config.Config{Gateway: config.GatewayConfig{SystemPrompts: []config.GatewaySystemPromptRule{
+		{Models: []string{"gemini-2.5-*"}, Mode: service.SystemPromptModePrepend, Prompt: "Org"},
+	}}}
+	r, got := newSystemPromptTestRouter(cfg, &service.APIKey{ID: 1}, service.SystemPromptFormatGemini)
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/v1beta/models/gemini-2.5-pro:generateContent", strings.NewReader(`{"contents":[]}`)))
+	require.Equal(t, http.StatusOK, w.Code)
+	require.Equal(t, "Org", gjson.Get(*got, "systemInstruction.parts.0.text").String())
+
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/v1beta/models/gemini-2.0-flash:generateContent", strings.NewReader(`{"contents":[]}`)))
+	require.Equal(t, `{"contents":[]}`, *got)
+}
+
+func TestSystemPromptPassThrough(t *testing.T) {
+	r, got := newSystemPromptTestRouter(&config.Config{}, &service.APIKey{ID: 1}, service.ReasoningFormatAnthropic)
+	body := `{"model":"claude","system":"Client"}`
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/v1/messages", strings.NewReader(body)))
+	require.Equal(t, http.StatusOK, w.Code)
+	require.Equal(t, body, *got)
+}
+
+func TestSystemPromptKeepsCodexInstructionsFirst(t *testing.T) {
+	key := &service.APIKey{ID: 1, SystemPrompt: "Team", SystemPromptMode: service.SystemPromptModeReplace}
+	r, got := newSystemPromptTestRouter(&config.Config{}, key, service.ReasoningFormatResponses)
+	body := `{"model":"gpt-5.1-codex","instructions":"Codex block"}`
+
+	req := httptest.NewRequest(http.MethodPost, "/v1/responses", strings.NewReader(body))
+	req.Header.Set("User-Agent", "codex_cli_rs/0.104.0")
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	require.Equal(t, http.StatusOK, w.Code)
+	require.Equal(t, "Codex block\n\nTeam", gjson.Get(*got, "instructions").String())
+
+	// 其他客户端按配置的注入方式处理
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/v1/responses", strings.NewReader(body)))
+	require.Equal(t, "Team", gjson.Get(*got, "instructions").String())
+}
@@ -0,0 +1,75 @@
+package middleware
+
+import (
+	"net/http"
+
+	"github.com/ShaohongDong/sub2api/internal/pkg/ctxkey"
+	"github.com/ShaohongDong/sub2api/internal/pkg/logger"
+	"github.com/ShaohongDong/sub2api/internal/pkg/tracing"
+	"github.com/gin-gonic/gin"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/zap"
+)
+
+// Tracing 为每个请求开启 SERVER span（沿用入站 traceparent），作为鉴权、调度、上游调用等阶段 span 的父 span。
+//
+// 已采样时向 request-scoped logger 注入 trace_id，span 上记录网关 request_id，便于日志与 trace 互查。需放在 RequestLogger 之后。
+func Tracing() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if c.Request == nil {
+			c.Next()
+			return
+		}
+
+		ctx := tracing.Extract(c.Request.Context(), c.Request.Header)
+		ctx, span := tracing.Tracer().Start(ctx, c.Request.Method,
+			trace.WithSpanKind(trace.SpanKindServer),
+			trace.WithAttributes(
+				attribute.String("http.request.method", c.Request.Method),
+				attribute.String("url.path", c.Request.URL.Path),
+			),
+		)
+		defer span.End()
+		if requestID, _ := ctx.Value(ctxkey.RequestID).(string); requestID != "" {
+			span.SetAttributes(attribute.String("sub2api.request_id", requestID))
+		}
+		if traceID := tracing.TraceID(ctx); traceID != "" {
+			ctx = logger.IntoContext(ctx, logger.FromContext(ctx).With(zap.String("trace_id", traceID)))
+		}
+		c.Request = c.Request.WithContext(ctx)
+
+		c.Next()
+
+		if !span.IsRecording() {
+			return
+		}
+		// 路由模板在匹配后才确定，span 名按 OTel HTTP 语义使用 "METHOD route"
+		if route := c.FullPath(); route != "" {
+			span.SetName(c.Request.Method + " " + route)
+			span.SetAttributes(attribute.String("http.route", route))
+		}
+		status := c.Writer.Status()
+		span.SetAttributes(attribute.Int("http.response.status_code", status))
+		if apiKey, ok := GetAPIKeyFromContext(c); ok && apiKey != nil {
+			span.SetAttributes(attribute.Int64("sub2api.api_key_id", apiKey.ID))
+			if apiKey.GroupID != nil {
+				span.SetAttributes(attribute.Int64("sub2api.group_id", *apiKey.GroupID))
+			}
+		}
+		if status >= http.StatusInternalServerError {
+			span.SetStatus(codes.Error, http.StatusText(status))
+		}
+	}
+}
+
+// endAuthSpan 结束鉴权 span；请求被拦截时记录拒绝的状态码（重复调用无副作用）
+func endAuthSpan(c *gin.Context, span trace.Span) {
+	if c.IsAborted() {
+		status := c.Writer.Status()
+		span.SetAttributes(attribute.Int("http.response.status_code", status))
+		span.SetStatus(codes.Error, http.StatusText(status))
+	}
+	span.End()
+}
@@ -0,0 +1,164 @@
+//go:build unit
+
+package middleware
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ShaohongDong/sub2api/internal/pkg/tracing"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+	"go.opentelemetry.io/otel/trace/noop"
+)
+
+type tracingSpanRecorder struct {
+	mu    sync.Mutex
+	spans []tracing.SpanData
+}
+
+func (r *tracingSpanRecorder) ExportSpans(_ context.Context, spans []tracing.SpanData) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.spans = append(r.spans, spans...)
+	return nil
+}
+
+func installTracingRecorder(t *testing.T) (*tracing.Provider, *tracingSpanRecorder) {
+	t.Helper()
+	rec := &tracingSpanRecorder{}
+	p := tracing.NewProvider(tracing.ProviderOptions{SampleRatio: 1}, rec)
+	tracing.Install(p, false)
+	t.Cleanup(func() {
+		_ = tracing.Shutdown(context.Background())
+		otel.SetTracerProvider(noop.NewTracerProvider())
+	})
+	return p, rec
+}
+
+func spanAttr(s tracing.SpanData, key string) (attribute.Value, bool) {
+	for _, kv := range s.Attributes {
+		if string(kv.Key) == key {
+			return kv.Value, true
+		}
+	}
+	return attribute.Value{}, false
+}
+
+func TestTracing_ServerSpanContinuesIncomingTrace(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	p, rec := installTracingRecorder(t)
+
+	r := gin.New()
+	r.Use(Tracing())
+	r.Use(ClientDetection())
+	r.POST("/v1/messages/:id", func(c *gin.Context) {
+		_, span := tracing.Start(c.Request.Context(), "handler")
+		span.End()
+		c.Status(http.StatusBadGateway)
+	})
+
+	req := httptest.NewRequest(http.MethodPost, "/v1/messages/abc", nil)
+	req.Header.Set("traceparent", "00-0af7651916cd43dd8448eb211c80319c-b7ad6b7169203331-01")
+	req.Header.Set("User-Agent", "claude-cli/2.0.0 (external, cli)")
+	r.ServeHTTP(httptest.NewRecorder(), req)
+	require.NoError(t, p.ForceFlush(context.Background()))
+
+	byName := map[string]tracing.SpanData{}
+	for _, s := range rec.spans {
+		byName[s.Name] = s
+	}
+	server, ok := byName["POST /v1/messages/:id"]
+	require.True(t, ok, "server span is renamed to the matched route")
+	require.Equal(t, trace.SpanKindServer, server.Kind)
+	require.Equal(t, "0af7651916cd43dd8448eb211c80319c", server.SpanContext.TraceID().String())
+	require.Equal(t, "b7ad6b7169203331", server.Parent.SpanID().String())
+	require.Equal(t, codes.Error, server.Status)
+	status, _ := spanAttr(server, "http.response.status_code")
+	require.Equal(t, int64(http.StatusBadGateway), status.AsInt64())
+
+	for _, name := range []string{"client_detection", "handler"} {
+		child, ok := byName[name]
+		require.True(t, ok, name)
+		require.Equal(t, server.SpanContext.SpanID(), child.Parent.SpanID(), name)
+	}
+}
+
+func TestTracing_AuthSpanEndsBeforeNextAndRecordsRejection(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	p, rec := installTracingRecorder(t)
+
+	auth := func(c *gin.Context) {
+		_, span := tracing.Start(c.Request.Context(), "auth.api_key")
+		defer endAuthSpan(c, span)
+		if c.GetHeader("x-api-key") == "" {
+			AbortWithError(c, http.StatusUnauthorized, "API_KEY_REQUIRED", "API key is required")
+			return
+		}
+		endAuthSpan(c, span)
+		c.Next()
+	}
+	var handlerStarted time.Time
+	r := gin.New()
+	r.Use(Tracing())
+	r.GET("/t", auth, func(c *gin.Context) {
+		handlerStarted = time.Now()
+		c.Status(http.StatusOK)
+	})
+
+	r.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/t", nil))
+	req := httptest.NewRequest(http.MethodGet, "/t", nil)
+	req.Header.Set("x-api-key", "sk-test")
+	r.ServeHTTP(httptest.NewRecorder(), req)
+	require.NoError(t, p.ForceFlush(context.Background()))
+
+	var authSpans []tracing.SpanData
+	servers := map[trace.TraceID]tracing.SpanData{}
+	for _, s := range rec.spans {
+		switch s.Name {
+		case "auth.api_key":
+			authSpans = append(authSpans, s)
+		case "GET /t":
+			servers[s.SpanContext.TraceID()] = s
+		}
+	}
+	require.Len(t, authSpans, 2)
+	require.Len(t, servers, 2)
+
+	rejected, accepted := authSpans[0], authSpans[1]
+	require.Equal(t, codes.Error, rejected.Status)
+	status, _ := spanAttr(rejected, "http.response.status_code")
+	require.Equal(t, int64(http.StatusUnauthorized), status.AsInt64())
+
+	require.Equal(t, codes.Unset, accepted.Status)
+	require.False(t, accepted.End.After(handlerStarted), "auth span must not cover the downstream handler")
+	require.Equal(t, servers[accepted.SpanContext.TraceID()].SpanContext.SpanID(), accepted.Parent.SpanID())
+}
+
+func TestTracing_ServerSpanRecordsRequestID(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	p, rec := installTracingRecorder(t)
+
+	r := gin.New()
+	r.Use(RequestLogger())
+	r.Use(Tracing())
+	r.GET("/t", func(c *gin.Context) { c.Status(http.StatusOK) })
+
+	req := httptest.NewRequest(http.MethodGet, "/t", nil)
+	req.Header.Set(requestIDHeader, "rid-trace")
+	r.ServeHTTP(httptest.NewRecorder(), req)
+	require.NoError(t, p.ForceFlush(context.Background()))
+
+	require.Len(t, rec.spans, 1)
+	requestID, ok := spanAttr(rec.spans[0], "sub2api.request_id")
+	require.True(t, ok)
+	require.Equal(t, "rid-trace", requestID.AsString())
+}
@@ -0,0 +1,70 @@
+package middleware
+
+import (
+	"strconv"
+	"sync/atomic"
+
+	"github.com/ShaohongDong/sub2api/internal/config"
+	"github.com/ShaohongDong/sub2api/internal/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+// UpstreamRetries 为请求挂载上游重试计数器，并在响应头写出前以 service.UpstreamRetriesHeader 标注重试次数（未重试时不输出）。
+// 未启用上游重试（gateway.upstream_retry 或运行时设置）时直接放行。
+func UpstreamRetries(cfg *config.Config) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if cfg == nil || !service.EffectiveUpstreamRetry(cfg.Gateway.UpstreamRetry).Enabled || c.Request == nil {
+			c.Next()
+			return
+		}
+		ctx, counter := service.WithUpstreamRetryCounter(c.Request.Context())
+		c.Request = c.Request.WithContext(ctx)
+		w := &upstreamRetryResponseWriter{ResponseWriter: c.Writer, counter: counter}
+		c.Writer = w
+		c.Next()
+		c.Writer = w.ResponseWriter
+	}
+}
+
+// upstreamRetryResponseWriter 在首次写出响应头前写入重试次数
+type upstreamRetryResponseWriter struct {
+	gin.ResponseWriter
+	counter *atomic.Int32
+	applied bool
+}
+
+func (w *upstreamRetryResponseWriter) apply() {
+	if w.applied || w.ResponseWriter.Written() {
+		return
+	}
+	w.applied = true
+	if n := w.counter.Load(); n > 0 {
+		w.Header().Set(service.UpstreamRetriesHeader, strconv.Itoa(int(n)))
+	}
+}
+
+func (w *upstreamRetryResponseWriter) WriteHeader(code int) {
+	w.apply()
+	w.ResponseWriter.WriteHeader(code)
+}
+
+func (w *upstreamRetryResponseWriter) WriteHeaderNow() {
+	w.apply()
+	w.ResponseWriter.WriteHeaderNow()
+}
+
+func (w *upstreamRetryResponseWriter) Write(b []byte) (int, error) {
+	w.apply()
+	return w.ResponseWriter.Write(b)
+}
+
+func (w *upstreamRetryResponseWriter) WriteString(s string) (int, error) {
+	w.apply()
+	return w.ResponseWriter.WriteString(s)
+}
+
+func (w *upstreamRetryResponseWriter) Flush() {
+	w.apply()
+	w.ResponseWriter.Flush()
+}
@@ -0,0 +1,39 @@
+//go:build unit
+
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/ShaohongDong/sub2api/internal/config"
+	"github.com/ShaohongDong/sub2api/internal/service"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUpstreamRetriesAnnotatesResponse(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	cfg := &config.Config{}
+	cfg.Gateway.UpstreamRetry.Enabled = true
+
+	r := gin.New()
+	r.Use(UpstreamRetries(cfg))
+	r.GET("/retried", func(c *gin.Context) {
+		service.RecordUpstreamRetry(c.Request.Context())
+		service.RecordUpstreamRetry(c.Request.Context())
+		c.String(http.StatusOK, "ok")
+	})
+	r.GET("/clean", func(c *gin.Context) {
+		c.String(http.StatusOK, "ok")
+	})
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/retried", nil))
+	require.Equal(t, "2", w.Header().Get(service.UpstreamRetriesHeader))
+
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/clean", nil))
+	require.Empty(t, w.Header().Get(service.UpstreamRetriesHeader))
+}
@@ -51,9 +51,19 @@ func SetupRouter(
 	refreshFrameOrigins() // 启动时初始化
 
 	// 应用中间件
+	// 响应压缩放在最外层，压缩的是注入 request_id 等处理之后的最终响应体
+	r.Use(middleware2.Compression(cfg.Gateway.Compression))
 	r.Use(middleware2.RequestLogger())
-	r.Use(middleware2.Logger())
+	r.Use(middleware2.Logger(cfg.Log.Access))
+	// 链路追踪：每个请求一个 SERVER span（tracing.enabled 关闭时为 noop）
+	r.Use(middleware2.Tracing())
+	// 网关请求计数与延迟（metrics.enabled 关闭时直接放行）
+	r.Use(handlers.Metrics.Middleware)
 	r.Use(middleware2.CORS(cfg.CORS))
+	// 下游写出失败时立即取消请求 context，中止上游生成
+	r.Use(middleware2.ClientDisconnect())
+	// 按路由前缀的首字节超时 / 总超时 / 刷新策略（未匹配的网关请求使用运行时默认超时）
+	r.Use(middleware2.RouteTimeouts(cfg.Gateway.RouteTimeouts))
 	r.Use(middleware2.SecurityHeaders(cfg.Security.CSP, func() []string {
 		if p := cachedFrameOrigins.Load(); p != nil {
 			return *p
@@ -101,7 +111,10 @@ func registerRoutes(
 	redisClient *redis.Client,
 ) {
 	// 通用路由（健康检查、状态等）
-	routes.RegisterCommonRoutes(r)
+	routes.RegisterCommonRoutes(r, h)
+	if h.Metrics.Enabled() {
+		r.GET("/metrics", h.Metrics.Serve)
+	}
 
 	// API v1
 	v1 := r.Group("/api/v1")
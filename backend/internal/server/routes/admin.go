@@ -4,6 +4,7 @@ package routes
 import (
 	"github.com/ShaohongDong/sub2api/internal/handler"
 	"github.com/ShaohongDong/sub2api/internal/server/middleware"
+	"github.com/ShaohongDong/sub2api/internal/service"
 
 	"github.com/gin-gonic/gin"
 )
@@ -15,7 +16,8 @@ func RegisterAdminRoutes(
 	adminAuth middleware.AdminAuthMiddleware,
 ) {
 	admin := v1.Group("/admin")
-	admin.Use(gin.HandlerFunc(adminAuth))
+	// 审计中间件位于角色校验之前，被拒绝的写操作同样留痕
+	admin.Use(gin.HandlerFunc(adminAuth), h.Admin.AuditLog.Middleware, middleware.AdminRoleAuthorization(adminRoleRules(admin.BasePath())))
 	{
 		// 仪表盘
 		registerDashboardRoutes(admin, h)
@@ -32,6 +34,9 @@ func RegisterAdminRoutes(
 		// 公告管理
 		registerAnnouncementRoutes(admin, h)
 
+		// 租户管理
+		registerTenantRoutes(admin, h)
+
 		// OpenAI OAuth
 		registerOpenAIOAuthRoutes(admin, h)
 		// Sora OAuth（实现复用 OpenAI OAuth 服务，入口独立）
@@ -81,13 +86,96 @@ func RegisterAdminRoutes(
 
 		// 定时测试计划
 		registerScheduledTestRoutes(admin, h)
+
+		// 操作审计日志
+		admin.GET("/audit-logs", h.Admin.AuditLog.List)
+
+		// 死信：重试耗尽的网关请求，账号恢复后可选择重放
+		deadLetters := admin.Group("/dead-letters")
+		{
+			deadLetters.GET("", h.Admin.DeadLetter.List)
+			deadLetters.GET("/:id", h.Admin.DeadLetter.Get)
+			deadLetters.POST("/replay", h.Admin.DeadLetter.Replay)
+			deadLetters.POST("/discard", h.Admin.DeadLetter.Discard)
+		}
+
+		// 请求内容记录：开启了 log_content 的 Key 的完整提示词与响应
+		contentLogs := admin.Group("/content-logs")
+		{
+			contentLogs.GET("", h.Admin.ContentLog.List)
+			contentLogs.GET("/:id", h.Admin.ContentLog.Get)
+		}
+
+		// 配置热重载（与 SIGHUP 相同）
+		admin.POST("/reload", h.Admin.System.ReloadConfig)
+
+		// 加密备份与恢复
+		admin.POST("/backup", h.Admin.Backup.Export)
+		admin.POST("/backup/restore", h.Admin.Backup.Restore)
+	}
+}
+
+// adminRoleRules 管理路由的角色要求（首条命中生效）。
+// 未命中的路由：只读请求需要 viewer，写操作需要 operator。
+func adminRoleRules(base string) []middleware.AdminRoleRule {
+	return []middleware.AdminRoleRule{
+		// 只读但使用 POST 传参的查询接口
+		{Method: "POST", Path: base + "/dashboard/users-usage", Role: service.RoleViewer},
+		{Method: "POST", Path: base + "/dashboard/api-keys-usage", Role: service.RoleViewer},
+		{Method: "POST", Path: base + "/accounts/today-stats/batch", Role: service.RoleViewer},
+		{Method: "POST", Path: base + "/accounts/check-mixed-channel", Role: service.RoleViewer},
+		{Method: "POST", Path: base + "/user-attributes/batch", Role: service.RoleViewer},
+
+		// 含凭证的数据导出
+		{Method: "GET", Path: base + "/accounts/data", Role: service.RoleAdmin},
+		{Method: "GET", Path: base + "/proxies/data", Role: service.RoleAdmin},
+		{Method: "GET", Path: base + "/settings/admin-api-key", Role: service.RoleAdmin},
+		// 返回完整 API Key 的列表：只读角色不可见
+		{Method: "GET", Path: base + "/users/:id/api-keys", Role: service.RoleOperator},
+		{Method: "GET", Path: base + "/groups/:id/api-keys", Role: service.RoleOperator},
+
+		// 系统设置读取接口已对密钥脱敏，可供只读角色查看（前端据此渲染功能开关）
+		{Method: "GET", Path: base + "/settings", Role: service.RoleViewer},
+		{Method: "GET", Path: base + "/settings/", Role: service.RoleViewer},
+
+		// 角色授予、系统设置、数据管理、备份恢复、系统升级、租户、审计日志、死信与内容记录仅限 admin
+		{Path: base + "/users/:id/role", Role: service.RoleAdmin},
+		{Path: base + "/audit-logs", Role: service.RoleAdmin},
+		// 死信与请求内容记录包含完整的请求体与提示词
+		{Path: base + "/dead-letters", Role: service.RoleAdmin},
+		{Path: base + "/dead-letters/", Role: service.RoleAdmin},
+		{Path: base + "/content-logs", Role: service.RoleAdmin},
+		{Path: base + "/content-logs/", Role: service.RoleAdmin},
+		{Path: base + "/settings/", Role: service.RoleAdmin},
+		{Path: base + "/settings", Role: service.RoleAdmin},
+		{Path: base + "/data-management/", Role: service.RoleAdmin},
+		{Path: base + "/system/", Role: service.RoleAdmin},
+		{Path: base + "/reload", Role: service.RoleAdmin},
+		{Path: base + "/backup", Role: service.RoleAdmin},
+		{Path: base + "/backup/", Role: service.RoleAdmin},
+		{Path: base + "/ops/runtime/", Role: service.RoleAdmin},
+		{Path: base + "/ops/advanced-settings", Role: service.RoleAdmin},
+		{Path: base + "/ops/email-notification/config", Role: service.RoleAdmin},
+		{Method: "POST", Path: base + "/tenants", Role: service.RoleAdmin},
+		{Method: "PUT", Path: base + "/tenants/", Role: service.RoleAdmin},
+		{Method: "POST", Path: base + "/tenants/", Role: service.RoleAdmin},
+		{Method: "DELETE", Path: base + "/tenants/", Role: service.RoleAdmin},
 	}
 }
 
 func registerAdminAPIKeyRoutes(admin *gin.RouterGroup, h *handler.Handlers) {
 	apiKeys := admin.Group("/api-keys")
 	{
+		apiKeys.POST("", h.Admin.APIKey.Create)
+		apiKeys.GET("/scopes", h.Admin.APIKey.ListScopes)
 		apiKeys.PUT("/:id", h.Admin.APIKey.UpdateGroup)
+		apiKeys.POST("/:id/revoke", h.Admin.APIKey.Revoke)
+		apiKeys.POST("/:id/rotate", h.Admin.APIKey.Rotate)
+		apiKeys.PUT("/:id/tags", h.Admin.APIKey.UpdateTags)
+		apiKeys.PUT("/:id/system-prompt", h.Admin.APIKey.UpdateSystemPrompt)
+		apiKeys.PUT("/:id/moderation", h.Admin.APIKey.UpdateModeration)
+		apiKeys.PUT("/:id/content-logging", h.Admin.APIKey.UpdateContentLogging)
+		apiKeys.PUT("/:id/simulation", h.Admin.APIKey.UpdateSimulation)
 	}
 }
 
@@ -98,6 +186,8 @@ func registerOpsRoutes(admin *gin.RouterGroup, h *handler.Handlers) {
 		ops.GET("/concurrency", h.Admin.Ops.GetConcurrencyStats)
 		ops.GET("/user-concurrency", h.Admin.Ops.GetUserConcurrencyStats)
 		ops.GET("/account-availability", h.Admin.Ops.GetAccountAvailability)
+		ops.GET("/account-latency", h.Admin.Ops.GetAccountLatency)
+		ops.GET("/account-circuit-breakers", h.Admin.Ops.GetAccountCircuitBreakers)
 		ops.GET("/realtime-traffic", h.Admin.Ops.GetRealtimeTrafficSummary)
 
 		// Alerts (rules + events)
@@ -146,6 +236,7 @@ func registerOpsRoutes(admin *gin.RouterGroup, h *handler.Handlers) {
 		ops.GET("/errors/:id", h.Admin.Ops.GetErrorLogByID)
 		ops.GET("/errors/:id/retries", h.Admin.Ops.ListRetryAttempts)
 		ops.POST("/errors/:id/retry", h.Admin.Ops.RetryErrorRequest)
+		ops.POST("/errors/:id/replay", h.Admin.Ops.ReplayError)
 		ops.PUT("/errors/:id/resolve", h.Admin.Ops.UpdateErrorResolution)
 
 		// Request errors (client-visible failures)
@@ -190,12 +281,21 @@ func registerDashboardRoutes(admin *gin.RouterGroup, h *handler.Handlers) {
 		dashboard.GET("/trend", h.Admin.Dashboard.GetUsageTrend)
 		dashboard.GET("/models", h.Admin.Dashboard.GetModelStats)
 		dashboard.GET("/groups", h.Admin.Dashboard.GetGroupStats)
+		dashboard.GET("/aggregates", h.Admin.Dashboard.GetUsageAggregates)
+		dashboard.GET("/live", h.Admin.Dashboard.StreamLiveEvents)
 		dashboard.GET("/api-keys-trend", h.Admin.Dashboard.GetAPIKeyUsageTrend)
 		dashboard.GET("/users-trend", h.Admin.Dashboard.GetUserUsageTrend)
 		dashboard.POST("/users-usage", h.Admin.Dashboard.GetBatchUsersUsage)
 		dashboard.POST("/api-keys-usage", h.Admin.Dashboard.GetBatchAPIKeysUsage)
 		dashboard.POST("/aggregation/backfill", h.Admin.Dashboard.BackfillAggregation)
 	}
+
+	// 统计（按客户端类型等维度的用量拆分）
+	stats := admin.Group("/stats")
+	{
+		stats.GET("/overview", h.Admin.Dashboard.GetOverview)
+		stats.GET("/clients", h.Admin.Dashboard.GetClientStats)
+	}
 }
 
 func registerUserManagementRoutes(admin *gin.RouterGroup, h *handler.Handlers) {
@@ -205,6 +305,7 @@ func registerUserManagementRoutes(admin *gin.RouterGroup, h *handler.Handlers) {
 		users.GET("/:id", h.Admin.User.GetByID)
 		users.POST("", h.Admin.User.Create)
 		users.PUT("/:id", h.Admin.User.Update)
+		users.PUT("/:id/role", h.Admin.User.UpdateRole)
 		users.DELETE("/:id", h.Admin.User.Delete)
 		users.POST("/:id/balance", h.Admin.User.UpdateBalance)
 		users.GET("/:id/api-keys", h.Admin.User.GetUserAPIKeys)
@@ -248,18 +349,30 @@ func registerAccountRoutes(admin *gin.RouterGroup, h *handler.Handlers) {
 		accounts.POST("/:id/refresh-tier", h.Admin.Account.RefreshTier)
 		accounts.GET("/:id/stats", h.Admin.Account.GetStats)
 		accounts.POST("/:id/clear-error", h.Admin.Account.ClearError)
+		accounts.POST("/:id/archive", h.Admin.Account.Archive)
+		accounts.POST("/:id/restore", h.Admin.Account.Restore)
 		accounts.GET("/:id/usage", h.Admin.Account.GetUsage)
+		accounts.GET("/:id/upstream-limits", h.Admin.Account.GetUpstreamLimits)
+		accounts.GET("/:id/usage-stats", h.Admin.Account.GetUsageStats)
 		accounts.GET("/:id/today-stats", h.Admin.Account.GetTodayStats)
 		accounts.POST("/today-stats/batch", h.Admin.Account.GetBatchTodayStats)
 		accounts.POST("/:id/clear-rate-limit", h.Admin.Account.ClearRateLimit)
+		accounts.GET("/cooldowns", h.Admin.Account.ListCooldowns)
+		accounts.GET("/:id/cooldowns", h.Admin.Account.GetCooldowns)
 		accounts.POST("/:id/reset-quota", h.Admin.Account.ResetQuota)
 		accounts.GET("/:id/temp-unschedulable", h.Admin.Account.GetTempUnschedulable)
 		accounts.DELETE("/:id/temp-unschedulable", h.Admin.Account.ClearTempUnschedulable)
+		accounts.GET("/health", h.Admin.AccountHealth.List)
+		accounts.POST("/validate", h.Admin.AccountValidate.Validate)
+		accounts.GET("/:id/health", h.Admin.AccountHealth.Get)
+		accounts.DELETE("/:id/health", h.Admin.AccountHealth.Reset)
+		accounts.GET("/:id/usage-windows", h.Admin.UsageWindow.Get)
 		accounts.POST("/:id/schedulable", h.Admin.Account.SetSchedulable)
 		accounts.GET("/:id/models", h.Admin.Account.GetAvailableModels)
 		accounts.POST("/batch", h.Admin.Account.BatchCreate)
 		accounts.GET("/data", h.Admin.Account.ExportData)
 		accounts.POST("/data", h.Admin.Account.ImportData)
+		accounts.POST("/data/csv", h.Admin.Account.ImportDataCSV)
 		accounts.POST("/batch-update-credentials", h.Admin.Account.BatchUpdateCredentials)
 		accounts.POST("/batch-refresh-tier", h.Admin.Account.BatchRefreshTier)
 		accounts.POST("/bulk-update", h.Admin.Account.BulkUpdate)
@@ -289,6 +402,22 @@ func registerAnnouncementRoutes(admin *gin.RouterGroup, h *handler.Handlers) {
 	}
 }
 
+func registerTenantRoutes(admin *gin.RouterGroup, h *handler.Handlers) {
+	tenants := admin.Group("/tenants")
+	{
+		tenants.GET("", h.Admin.Tenant.List)
+		tenants.POST("", h.Admin.Tenant.Create)
+		tenants.GET("/:id", h.Admin.Tenant.GetByID)
+		tenants.PUT("/:id", h.Admin.Tenant.Update)
+		tenants.DELETE("/:id", h.Admin.Tenant.Delete)
+		tenants.GET("/:id/stats", h.Admin.Tenant.GetStats)
+		tenants.POST("/:id/users", h.Admin.Tenant.AssignUsers)
+		tenants.POST("/:id/users/remove", h.Admin.Tenant.RemoveUsers)
+		tenants.POST("/:id/groups", h.Admin.Tenant.AssignGroups)
+		tenants.POST("/:id/groups/remove", h.Admin.Tenant.RemoveGroups)
+	}
+}
+
 func registerOpenAIOAuthRoutes(admin *gin.RouterGroup, h *handler.Handlers) {
 	openai := admin.Group("/openai")
 	{
@@ -297,6 +426,8 @@ func registerOpenAIOAuthRoutes(admin *gin.RouterGroup, h *handler.Handlers) {
 		openai.POST("/refresh-token", h.Admin.OpenAIOAuth.RefreshToken)
 		openai.POST("/accounts/:id/refresh", h.Admin.OpenAIOAuth.RefreshAccountToken)
 		openai.POST("/create-from-oauth", h.Admin.OpenAIOAuth.CreateAccountFromOAuth)
+		openai.POST("/device-login/start", h.Admin.OpenAIOAuth.StartDeviceLogin)
+		openai.POST("/device-login/poll", h.Admin.OpenAIOAuth.PollDeviceLogin)
 	}
 }
 
@@ -389,6 +520,13 @@ func registerSettingsRoutes(admin *gin.RouterGroup, h *handler.Handlers) {
 		adminSettings.GET("/admin-api-key", h.Admin.Setting.GetAdminAPIKey)
 		adminSettings.POST("/admin-api-key/regenerate", h.Admin.Setting.RegenerateAdminAPIKey)
 		adminSettings.DELETE("/admin-api-key", h.Admin.Setting.DeleteAdminAPIKey)
+		// 运行时参数（超时、心跳、重试、日志级别、降级模式），修改后无需重启
+		adminSettings.GET("/runtime", h.Admin.RuntimeSettings.Get)
+		adminSettings.PUT("/runtime", h.Admin.RuntimeSettings.Update)
+		adminSettings.POST("/runtime/reset", h.Admin.RuntimeSettings.Reset)
+		// 维护模式：开启后网关拒绝新请求（503 + Retry-After），管理后台与后台任务不受影响
+		adminSettings.GET("/maintenance", h.Admin.Maintenance.Get)
+		adminSettings.PUT("/maintenance", h.Admin.Maintenance.Update)
 		// 流超时处理配置
 		adminSettings.GET("/stream-timeout", h.Admin.Setting.GetStreamTimeoutSettings)
 		adminSettings.PUT("/stream-timeout", h.Admin.Setting.UpdateStreamTimeoutSettings)
@@ -398,6 +536,29 @@ func registerSettingsRoutes(admin *gin.RouterGroup, h *handler.Handlers) {
 		// Beta 策略配置
 		adminSettings.GET("/beta-policy", h.Admin.Setting.GetBetaPolicySettings)
 		adminSettings.PUT("/beta-policy", h.Admin.Setting.UpdateBetaPolicySettings)
+		// 客户端路由策略
+		adminSettings.GET("/client-routing", h.Admin.Setting.GetClientRoutingSettings)
+		adminSettings.PUT("/client-routing", h.Admin.Setting.UpdateClientRoutingSettings)
+		// 请求路由规则
+		adminSettings.GET("/routing-rules", h.Admin.Setting.GetRoutingRuleSettings)
+		adminSettings.PUT("/routing-rules", h.Admin.Setting.UpdateRoutingRuleSettings)
+		// 金丝雀分流
+		adminSettings.GET("/canary-splits", h.Admin.Setting.GetCanarySettings)
+		adminSettings.PUT("/canary-splits", h.Admin.Setting.UpdateCanarySettings)
+		adminSettings.GET("/canary-splits/metrics", h.Admin.Setting.GetCanaryMetrics)
+		// 影子流量
+		adminSettings.GET("/shadow-mirror", h.Admin.Setting.GetShadowMirrorSettings)
+		adminSettings.PUT("/shadow-mirror", h.Admin.Setting.UpdateShadowMirrorSettings)
+		adminSettings.GET("/shadow-mirror/samples", h.Admin.Setting.GetShadowMirrorSamples)
+		// 请求/响应头策略
+		adminSettings.GET("/header-policy", h.Admin.Setting.GetHeaderPolicySettings)
+		adminSettings.PUT("/header-policy", h.Admin.Setting.UpdateHeaderPolicySettings)
+		// 全局模型别名表
+		adminSettings.GET("/model-aliases", h.Admin.Setting.GetModelAliasSettings)
+		adminSettings.PUT("/model-aliases", h.Admin.Setting.UpdateModelAliasSettings)
+		// 模型价格表（覆盖远程价格数据与配置文件）
+		adminSettings.GET("/pricing-overrides", h.Admin.Setting.GetPricingOverrideSettings)
+		adminSettings.PUT("/pricing-overrides", h.Admin.Setting.UpdatePricingOverrideSettings)
 		// Sora S3 存储配置
 		adminSettings.GET("/sora-s3", h.Admin.Setting.GetSoraS3Settings)
 		adminSettings.PUT("/sora-s3", h.Admin.Setting.UpdateSoraS3Settings)
@@ -468,6 +629,7 @@ func registerUsageRoutes(admin *gin.RouterGroup, h *handler.Handlers) {
 	{
 		usage.GET("", h.Admin.Usage.List)
 		usage.GET("/stats", h.Admin.Usage.Stats)
+		usage.GET("/export", h.Admin.Usage.Export)
 		usage.GET("/search-users", h.Admin.Usage.SearchUsers)
 		usage.GET("/search-api-keys", h.Admin.Usage.SearchAPIKeys)
 		usage.GET("/cleanup-tasks", h.Admin.Usage.ListCleanupTasks)
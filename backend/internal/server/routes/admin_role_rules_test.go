@@ -0,0 +1,65 @@
+package routes
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	servermiddleware "github.com/ShaohongDong/sub2api/internal/server/middleware"
+	"github.com/ShaohongDong/sub2api/internal/service"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/require"
+)
+
+func newAdminRoleRulesTestRouter(role string) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	admin := router.Group("/api/v1/admin")
+	admin.Use(func(c *gin.Context) {
+		c.Set(string(servermiddleware.ContextKeyUserRole), role)
+		c.Next()
+	}, servermiddleware.AdminRoleAuthorization(adminRoleRules(admin.BasePath())))
+	ok := func(c *gin.Context) { c.Status(http.StatusOK) }
+	admin.GET("/users/:id", ok)
+	admin.GET("/users/:id/api-keys", ok)
+	admin.GET("/groups/:id/api-keys", ok)
+	admin.GET("/dead-letters", ok)
+	admin.GET("/dead-letters/:id", ok)
+	admin.POST("/dead-letters/replay", ok)
+	admin.GET("/content-logs", ok)
+	admin.GET("/content-logs/:id", ok)
+	return router
+}
+
+func TestAdminRoleRules(t *testing.T) {
+	cases := []struct {
+		role   string
+		method string
+		path   string
+		want   int
+	}{
+		{service.RoleViewer, http.MethodGet, "/api/v1/admin/users/1", http.StatusOK},
+		// 返回完整 API Key，只读角色不可访问
+		{service.RoleViewer, http.MethodGet, "/api/v1/admin/users/1/api-keys", http.StatusForbidden},
+		{service.RoleViewer, http.MethodGet, "/api/v1/admin/groups/1/api-keys", http.StatusForbidden},
+		{service.RoleOperator, http.MethodGet, "/api/v1/admin/users/1/api-keys", http.StatusOK},
+		{service.RoleOperator, http.MethodGet, "/api/v1/admin/groups/1/api-keys", http.StatusOK},
+		// 死信与请求内容记录仅限 admin
+		{service.RoleViewer, http.MethodGet, "/api/v1/admin/dead-letters", http.StatusForbidden},
+		{service.RoleOperator, http.MethodGet, "/api/v1/admin/dead-letters/1", http.StatusForbidden},
+		{service.RoleOperator, http.MethodPost, "/api/v1/admin/dead-letters/replay", http.StatusForbidden},
+		{service.RoleAdmin, http.MethodGet, "/api/v1/admin/dead-letters", http.StatusOK},
+		{service.RoleAdmin, http.MethodPost, "/api/v1/admin/dead-letters/replay", http.StatusOK},
+		{service.RoleViewer, http.MethodGet, "/api/v1/admin/content-logs", http.StatusForbidden},
+		{service.RoleOperator, http.MethodGet, "/api/v1/admin/content-logs", http.StatusForbidden},
+		{service.RoleOperator, http.MethodGet, "/api/v1/admin/content-logs/1", http.StatusForbidden},
+		{service.RoleAdmin, http.MethodGet, "/api/v1/admin/content-logs", http.StatusOK},
+		{service.RoleAdmin, http.MethodGet, "/api/v1/admin/content-logs/1", http.StatusOK},
+	}
+	for _, tc := range cases {
+		router := newAdminRoleRulesTestRouter(tc.role)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest(tc.method, tc.path, nil))
+		require.Equal(t, tc.want, w.Code, "%s %s %s", tc.role, tc.method, tc.path)
+	}
+}
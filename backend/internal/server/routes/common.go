@@ -3,15 +3,21 @@ package routes
 import (
 	"net/http"
 
+	"github.com/ShaohongDong/sub2api/internal/handler"
+
 	"github.com/gin-gonic/gin"
 )
 
 // RegisterCommonRoutes 注册通用路由（健康检查、状态等）
-func RegisterCommonRoutes(r *gin.Engine) {
-	// 健康检查
-	r.GET("/health", func(c *gin.Context) {
-		c.JSON(http.StatusOK, gin.H{"status": "ok"})
-	})
+func RegisterCommonRoutes(r *gin.Engine, h *handler.Handlers) {
+	// 健康检查：/health 供负载均衡探测，/health/details 供运维查看（health.details_enabled）
+	r.GET("/health", h.Health.Summary)
+	// Kubernetes 探针：/livez 仅表示进程存活，/readyz 反映数据存储、账号可用性与停机状态
+	r.GET("/livez", h.Health.Live)
+	r.GET("/readyz", h.Health.Ready)
+	if h.Health.DetailsEnabled() {
+		r.GET("/health/details", h.Health.Details)
+	}
 
 	// Claude Code 遥测日志（忽略，直接返回200）
 	r.POST("/api/event_logging/batch", func(c *gin.Context) {
@@ -28,41 +28,127 @@ func RegisterGatewayRoutes(
 		soraMaxBodySize = cfg.Gateway.MaxBodySize
 	}
 	soraBodyLimit := middleware.RequestBodyLimit(soraMaxBodySize)
+	audioMaxBodySize := cfg.Gateway.AudioMaxBodySize
+	if audioMaxBodySize <= 0 {
+		audioMaxBodySize = cfg.Gateway.MaxBodySize
+	}
+	audioBodyLimit := middleware.RequestBodyLimit(audioMaxBodySize)
+	batchBodyLimit := middleware.RequestBodyLimit(cfg.Batch.MaxFileSize)
+	// multipart 表单额外预留 1MB 开销
+	fileBodyLimit := middleware.RequestBodyLimit(cfg.Files.MaxFileSize + 1<<20)
 	clientRequestID := middleware.ClientRequestID()
+	// 维护模式：开启后新请求在鉴权前直接返回 503 + Retry-After（按入口协议格式），在途请求照常完成
+	maintenance := h.Maintenance.Gate(handler.MaintenanceErrorWriter)
+	maintenanceGoogle := h.Maintenance.Gate(middleware.GoogleErrorWriter)
+	maintenanceOllama := h.Maintenance.Gate(middleware.OllamaErrorWriter)
+	clientDetection := middleware.ClientDetection()
 	opsErrorLogger := handler.OpsErrorLoggerMiddleware(opsService)
 	endpointNorm := handler.InboundEndpointMiddleware()
+	// 死信：重试与账号切换均失败的请求连同请求体落库，账号恢复后可在管理后台重放
+	deadLetter := h.DeadLetter.Middleware
+	// 推理请求的提示词审核前置过滤（本地规则或审核接口；按 Key 的 moderation_mode 或 gateway.moderation.pre_filter 决定是否审核）
+	moderationFilter := handler.ModerationPreFilterMiddleware(cfg)
+	// 请求扩展点（gateway.hooks）：pre_auth 在鉴权前执行；认证后的 pre_translation / post_response 由 gatewayHooks 执行，
+	// pre_upstream 由上游 HTTP 客户端在每次上游请求前执行
+	hooksPreAuth := h.Hooks.PreAuth
+	gatewayHooks := h.Hooks.Middleware
+	// 请求内容记录：开启了 log_content 的 Key 保存完整提示词与响应（与常驻的元数据日志分开，按 content_log.retention_days 清理）
+	contentLog := h.ContentLog.Middleware
+	// 流式请求的断线续传：分配事件 id 并缓冲，携带 Last-Event-ID 的重连直接从缓冲续传
+	sseReplay := h.SSEReplay.Middleware
+	// 响应缓存：temperature 为 0 的非流式请求按规范化请求体缓存，命中时直接返回并带 x-cache: hit
+	responseCache := h.ResponseCache.Middleware
+	// 会话存储：保存 /responses 每轮的输入与输出，previous_response_id 在上游不可用时由本地上下文重建
+	conversationStore := h.ConversationStore.Middleware
+	// n > 1 模拟：Chat Completions 并行发起 n 个子请求并合并 choices（位于模型别名之前，子请求重新经过完整链路）
+	chatMultiChoice := h.ChatMultiChoice.Middleware
+	// 模型别名：按分组/全局别名表改写请求模型，响应中改回客户端请求的模型名
+	modelAlias := middleware.ModelAlias(settingService)
+	// 路由规则：按模型/客户端/请求头/Key 标签/请求体大小将请求改由目标分组调度
+	routingRules := middleware.RoutingRules(settingService, apiKeyService)
+	// 金丝雀分流：命中模型的部分请求按比例改由目标分组/模型处理，并分别统计指标
+	canarySplit := middleware.CanarySplit(settingService, apiKeyService)
+	// 跨平台降级链：分组限流或上游故障时按链上顺序改由下一个分组（可跨平台，自动格式转换）重试
+	providerFallback := middleware.ProviderFallback(apiKeyService, settingService)
+	// 降级模式：请求模型的所有账号均不可用时按 Key 配置改用降级模型（X-Sub2API-Degraded-Model 标注），而不是返回 503
+	degradedFallback := middleware.DegradedFallback(settingService)
+	// 影子流量：命中模型的部分请求在主请求完成后异步复制一份发往目标分组/模型（不计费、不影响响应），用于对比
+	shadowMirror := middleware.ShadowMirror(settingService, apiKeyService)
+	// 头策略：按平台/账号/分组/路径剥离或注入返回给客户端的响应头（上游请求头由网关服务在构建请求时应用）
+	headerPolicy := middleware.HeaderPolicy(settingService)
+	// 上游重试次数：启用 gateway.upstream_retry 时以 X-Sub2API-Upstream-Retries 响应头标注本次请求的上游重试次数
+	upstreamRetries := middleware.UpstreamRetries(cfg)
+	// 优先级队列：分组在途请求达到 gateway.priority_queue 上限时按 Key 优先级排队，队列满或等待超时返回 429
+	priorityQueue := service.NewPriorityRequestQueue(cfg.Gateway.PriorityQueue)
+	queueAnthropic := middleware.PriorityQueue(priorityQueue, middleware.RateLimitErrorWriter)
+	queueGoogle := middleware.PriorityQueue(priorityQueue, middleware.GoogleErrorWriter)
+	queueOllama := middleware.PriorityQueue(priorityQueue, middleware.OllamaErrorWriter)
+	// Idempotency-Key：非流式推理请求在 idempotency.gateway.ttl_seconds 内重复提交时直接重放首次的成功响应（不转发上游、不计费），
+	// 并发的重复请求返回 409 + Retry-After；位于排队之前，重放不占用分组并发
+	idempotency := h.Idempotency.Middleware(handler.IdempotencyErrorWriter)
+	idempotencyGoogle := h.Idempotency.Middleware(middleware.GoogleErrorWriter)
+	// 推理强度默认值：按 gateway.reasoning_defaults 补充或强制推理强度（按入口格式写入）
+	reasoningMessages := middleware.ReasoningDefaults(cfg, service.ReasoningFormatAnthropic)
+	reasoningResponses := middleware.ReasoningDefaults(cfg, service.ReasoningFormatResponses)
+	reasoningChat := middleware.ReasoningDefaults(cfg, service.ReasoningFormatChatCompletions)
+	// 系统提示词注入：管理员为 Key 设置的系统提示词与 gateway.system_prompts 路由规则（按入口格式写入，位于本地审核之后）
+	systemPromptMessages := middleware.SystemPrompt(cfg, service.ReasoningFormatAnthropic)
+	systemPromptResponses := middleware.SystemPrompt(cfg, service.ReasoningFormatResponses)
+	systemPromptChat := middleware.SystemPrompt(cfg, service.ReasoningFormatChatCompletions)
+	systemPromptGemini := middleware.SystemPrompt(cfg, service.SystemPromptFormatGemini)
+	systemPromptOllamaGenerate := middleware.SystemPrompt(cfg, service.SystemPromptFormatOllamaGenerate)
+	// 参数清理：按模型钳制最大输出 token 数并移除上游必然拒绝的参数（gateway.param_sanitize，位于系统提示词注入之后）
+	sanitizeMessages := h.ParamSanitize.Middleware(service.ReasoningFormatAnthropic)
+	sanitizeResponses := h.ParamSanitize.Middleware(service.ReasoningFormatResponses)
+	sanitizeChat := h.ParamSanitize.Middleware(service.ReasoningFormatChatCompletions)
+	// 上下文窗口超限处理：拒绝 / 丢弃最早的轮次 / 摘要压缩（gateway.context_overflow，位于参数清理之后）
+	contextOverflowMessages := h.ContextOverflow.Middleware(service.ReasoningFormatAnthropic)
+	contextOverflowResponses := h.ContextOverflow.Middleware(service.ReasoningFormatResponses)
+	contextOverflowChat := h.ContextOverflow.Middleware(service.ReasoningFormatChatCompletions)
 
 	// 未分组 Key 拦截中间件（按协议格式区分错误响应）
 	requireGroupAnthropic := middleware.RequireGroupAssignment(settingService, middleware.AnthropicErrorWriter)
 	requireGroupGoogle := middleware.RequireGroupAssignment(settingService, middleware.GoogleErrorWriter)
+	requireGroupOllama := middleware.RequireGroupAssignment(settingService, middleware.OllamaErrorWriter)
+
+	messagesHandler := func(c *gin.Context) {
+		if getGroupPlatform(c) == service.PlatformOpenAI {
+			h.OpenAIGateway.Messages(c)
+			return
+		}
+		h.Gateway.Messages(c)
+	}
+
+	// upstream 模式仅 OpenAI 分组支持；local 模式所有分组均可用
+	moderationsHandler := requireOpenAIGroup("Moderations are not supported for this platform", h.OpenAIGateway.Moderations)
+	if cfg.Gateway.Moderation.Mode == config.ModerationModeLocal {
+		moderationsHandler = h.OpenAIGateway.Moderations
+	}
 
 	// API网关（Claude API兼容）
 	gateway := r.Group("/v1")
 	gateway.Use(bodyLimit)
 	gateway.Use(clientRequestID)
+	gateway.Use(maintenance)
+	gateway.Use(clientDetection)
 	gateway.Use(opsErrorLogger)
+	gateway.Use(deadLetter)
 	gateway.Use(endpointNorm)
+	gateway.Use(hooksPreAuth)
 	gateway.Use(gin.HandlerFunc(apiKeyAuth))
 	gateway.Use(requireGroupAnthropic)
+	gateway.Use(headerPolicy)
+	gateway.Use(upstreamRetries)
+	gateway.Use(idempotency)
+	gateway.Use(queueAnthropic)
+	gateway.Use(gatewayHooks, contentLog)
 	{
 		// /v1/messages: auto-route based on group platform
-		gateway.POST("/messages", func(c *gin.Context) {
-			if getGroupPlatform(c) == service.PlatformOpenAI {
-				h.OpenAIGateway.Messages(c)
-				return
-			}
-			h.Gateway.Messages(c)
-		})
-		// /v1/messages/count_tokens: OpenAI groups get 404
-		gateway.POST("/messages/count_tokens", func(c *gin.Context) {
+		gateway.POST("/messages", sseReplay, responseCache, modelAlias, routingRules, canarySplit, reasoningMessages, moderationFilter, systemPromptMessages, sanitizeMessages, contextOverflowMessages, shadowMirror(degradedFallback(providerFallback(messagesHandler))))
+		// /v1/messages/count_tokens: OpenAI groups are counted locally with the embedded tokenizer
+		gateway.POST("/messages/count_tokens", modelAlias, routingRules, canarySplit, func(c *gin.Context) {
 			if getGroupPlatform(c) == service.PlatformOpenAI {
-				c.JSON(http.StatusNotFound, gin.H{
-					"type": "error",
-					"error": gin.H{
-						"type":    "not_found_error",
-						"message": "Token counting is not supported for this platform",
-					},
-				})
+				handler.LocalCountTokens(c)
 				return
 			}
 			h.Gateway.CountTokens(c)
@@ -70,14 +156,24 @@ func RegisterGatewayRoutes(
 		gateway.GET("/models", h.Gateway.Models)
 		gateway.GET("/usage", h.Gateway.Usage)
 		// OpenAI Responses API: auto-route based on group platform
-		gateway.POST("/responses", func(c *gin.Context) {
+		// background=true 的请求由本地执行器接管，结果通过 GET /v1/responses/:id 轮询
+		gateway.POST("/responses", sseReplay, responseCache, conversationStore, modelAlias, routingRules, canarySplit, reasoningResponses, moderationFilter, systemPromptResponses, sanitizeResponses, contextOverflowResponses, h.BackgroundResponse.Intercept, shadowMirror(degradedFallback(providerFallback(func(c *gin.Context) {
 			if getGroupPlatform(c) == service.PlatformOpenAI {
 				h.OpenAIGateway.Responses(c)
 				return
 			}
 			h.Gateway.Responses(c)
-		})
-		gateway.POST("/responses/*subpath", func(c *gin.Context) {
+		}))))
+		gateway.POST("/responses/*subpath", moderationFilter, func(c *gin.Context) {
+			// /v1/responses/input_tokens: 本地 tokenizer 计数，与分组平台无关
+			if c.Param("subpath") == "/input_tokens" {
+				handler.ResponsesInputTokens(c)
+				return
+			}
+			if _, ok := handler.BackgroundResponseCancelID(c.Param("subpath")); ok {
+				h.BackgroundResponse.Cancel(c)
+				return
+			}
 			if getGroupPlatform(c) == service.PlatformOpenAI {
 				h.OpenAIGateway.Responses(c)
 				return
@@ -85,66 +181,210 @@ func RegisterGatewayRoutes(
 			h.Gateway.Responses(c)
 		})
 		gateway.GET("/responses", h.OpenAIGateway.ResponsesWebSocket)
+		gateway.GET("/responses/:id", h.BackgroundResponse.Get)
+		gateway.DELETE("/responses/:id", h.BackgroundResponse.Delete)
 		// OpenAI Chat Completions API: auto-route based on group platform
-		gateway.POST("/chat/completions", func(c *gin.Context) {
+		gateway.POST("/chat/completions", sseReplay, responseCache, chatMultiChoice, modelAlias, routingRules, canarySplit, reasoningChat, moderationFilter, systemPromptChat, sanitizeChat, contextOverflowChat, shadowMirror(degradedFallback(providerFallback(func(c *gin.Context) {
 			if getGroupPlatform(c) == service.PlatformOpenAI {
 				h.OpenAIGateway.ChatCompletions(c)
 				return
 			}
 			h.Gateway.ChatCompletions(c)
-		})
+		}))))
+		// 旧版 Completions API：仅 OpenAI 分组支持（包装为 Responses 调用）
+		gateway.POST("/completions", sseReplay, responseCache, modelAlias, routingRules, canarySplit, moderationFilter, requireOpenAIGroup("Legacy completions are not supported for this platform", h.OpenAIGateway.Completions))
+		// Embeddings API：仅 OpenAI 分组的 API Key 账号支持（透传）
+		gateway.POST("/embeddings", modelAlias, routingRules, canarySplit, requireOpenAIGroup("Embeddings are not supported for this platform", h.OpenAIGateway.Embeddings))
+		// Moderations API：upstream 模式仅 OpenAI 分组的 API Key 账号支持（透传）；local 模式由网关本地分类器应答
+		gateway.POST("/moderations", moderationsHandler)
+		// Audio API：仅 OpenAI 分组的 API Key 账号支持（透传，单独的请求体上限）
+		gateway.POST("/audio/transcriptions", audioBodyLimit, requireOpenAIGroup("Audio is not supported for this platform", h.OpenAIGateway.AudioTranscriptions))
+		gateway.POST("/audio/speech", audioBodyLimit, requireOpenAIGroup("Audio is not supported for this platform", h.OpenAIGateway.AudioSpeech))
+		// Realtime API（WebSocket）：仅 OpenAI 分组的 API Key 账号支持（透传，会话结束时记录用量）
+		gateway.GET("/realtime", requireOpenAIGroup("Realtime API is not supported for this platform", h.OpenAIGateway.Realtime))
+		// Images API：OpenAI 分组的 API Key 账号及含图片模型的 OAuth 账号支持
+		gateway.POST("/images/generations", requireOpenAIGroup("Image generation is not supported for this platform", h.OpenAIGateway.ImageGenerations))
+		// Batch API：后台按行分发到上述端点，与平台无关
+		gateway.POST("/batches", batchBodyLimit, h.Batch.Create)
+		gateway.GET("/batches", h.Batch.List)
+		gateway.GET("/batches/:id", h.Batch.Get)
+		gateway.POST("/batches/:id/cancel", h.Batch.Cancel)
+		gateway.GET("/batches/:id/output", h.Batch.Output)
+		// Files API：上传的文件可在 Responses / Chat Completions / Batch 中以 file_id 引用
+		gateway.POST("/files", fileBodyLimit, h.File.Upload)
+		gateway.GET("/files", h.File.List)
+		gateway.GET("/files/:id", h.File.Get)
+		gateway.DELETE("/files/:id", h.File.Delete)
+		gateway.GET("/files/:id/content", h.File.Content)
 	}
 
 	// Gemini 原生 API 兼容层（Gemini SDK/CLI 直连）
 	gemini := r.Group("/v1beta")
 	gemini.Use(bodyLimit)
 	gemini.Use(clientRequestID)
+	gemini.Use(maintenanceGoogle)
+	gemini.Use(clientDetection)
 	gemini.Use(opsErrorLogger)
+	gemini.Use(deadLetter)
 	gemini.Use(endpointNorm)
+	gemini.Use(hooksPreAuth)
 	gemini.Use(middleware.APIKeyAuthWithSubscriptionGoogle(apiKeyService, subscriptionService, cfg))
 	gemini.Use(requireGroupGoogle)
+	gemini.Use(headerPolicy)
+	gemini.Use(upstreamRetries)
+	gemini.Use(idempotencyGoogle)
+	gemini.Use(queueGoogle)
+	gemini.Use(gatewayHooks, contentLog)
 	{
 		gemini.GET("/models", h.Gateway.GeminiV1BetaListModels)
 		gemini.GET("/models/:model", h.Gateway.GeminiV1BetaGetModel)
 		// Gin treats ":" as a param marker, but Gemini uses "{model}:{action}" in the same segment.
-		gemini.POST("/models/*modelAction", h.Gateway.GeminiV1BetaModels)
+		// OpenAI 分组：generateContent/streamGenerateContent 转换为 Responses API
+		gemini.POST("/models/*modelAction", modelAlias, routingRules, canarySplit, moderationFilter, systemPromptGemini, func(c *gin.Context) {
+			if getGroupPlatform(c) == service.PlatformOpenAI {
+				h.OpenAIGateway.GeminiGenerateContent(c)
+				return
+			}
+			h.Gateway.GeminiV1BetaModels(c)
+		})
+	}
+
+	// Ollama 兼容 API（仅 OpenAI 分组支持对话/补全，模型列表对所有分组开放）
+	ollama := r.Group("/api")
+	ollama.Use(bodyLimit)
+	ollama.Use(clientRequestID)
+	ollama.Use(maintenanceOllama)
+	ollama.Use(clientDetection)
+	ollama.Use(opsErrorLogger)
+	ollama.Use(endpointNorm)
+	ollama.Use(hooksPreAuth)
+	ollama.Use(gin.HandlerFunc(apiKeyAuth))
+	ollama.Use(requireGroupOllama)
+	ollama.Use(headerPolicy)
+	ollama.Use(upstreamRetries)
+	ollama.Use(queueOllama)
+	ollama.Use(gatewayHooks, contentLog)
+	{
+		ollama.GET("/tags", h.Gateway.OllamaTags)
+		ollama.POST("/chat", modelAlias, routingRules, canarySplit, moderationFilter, systemPromptChat, func(c *gin.Context) {
+			if getGroupPlatform(c) != service.PlatformOpenAI {
+				middleware.OllamaErrorWriter(c, http.StatusNotFound, "Ollama API is only supported for OpenAI groups")
+				return
+			}
+			h.OpenAIGateway.OllamaChat(c)
+		})
+		ollama.POST("/generate", modelAlias, routingRules, canarySplit, moderationFilter, systemPromptOllamaGenerate, func(c *gin.Context) {
+			if getGroupPlatform(c) != service.PlatformOpenAI {
+				middleware.OllamaErrorWriter(c, http.StatusNotFound, "Ollama API is only supported for OpenAI groups")
+				return
+			}
+			h.OpenAIGateway.OllamaGenerate(c)
+		})
 	}
 
 	// OpenAI Responses API（不带v1前缀的别名）— auto-route based on group platform
 	responsesHandler := func(c *gin.Context) {
+		if c.Param("subpath") == "/input_tokens" {
+			handler.ResponsesInputTokens(c)
+			return
+		}
+		if _, ok := handler.BackgroundResponseCancelID(c.Param("subpath")); ok {
+			h.BackgroundResponse.Cancel(c)
+			return
+		}
 		if getGroupPlatform(c) == service.PlatformOpenAI {
 			h.OpenAIGateway.Responses(c)
 			return
 		}
 		h.Gateway.Responses(c)
 	}
-	r.POST("/responses", bodyLimit, clientRequestID, opsErrorLogger, endpointNorm, gin.HandlerFunc(apiKeyAuth), requireGroupAnthropic, responsesHandler)
-	r.POST("/responses/*subpath", bodyLimit, clientRequestID, opsErrorLogger, endpointNorm, gin.HandlerFunc(apiKeyAuth), requireGroupAnthropic, responsesHandler)
-	r.GET("/responses", bodyLimit, clientRequestID, opsErrorLogger, endpointNorm, gin.HandlerFunc(apiKeyAuth), requireGroupAnthropic, h.OpenAIGateway.ResponsesWebSocket)
+	r.POST("/responses", bodyLimit, clientRequestID, maintenance, clientDetection, opsErrorLogger, deadLetter, endpointNorm, hooksPreAuth, gin.HandlerFunc(apiKeyAuth), requireGroupAnthropic, headerPolicy, upstreamRetries, idempotency, queueAnthropic, gatewayHooks, contentLog, sseReplay, responseCache, conversationStore, modelAlias, routingRules, canarySplit, reasoningResponses, moderationFilter, systemPromptResponses, sanitizeResponses, contextOverflowResponses, h.BackgroundResponse.Intercept, shadowMirror(degradedFallback(providerFallback(responsesHandler))))
+	r.POST("/responses/*subpath", bodyLimit, clientRequestID, maintenance, clientDetection, opsErrorLogger, endpointNorm, hooksPreAuth, gin.HandlerFunc(apiKeyAuth), requireGroupAnthropic, gatewayHooks, contentLog, moderationFilter, responsesHandler)
+	r.GET("/responses", bodyLimit, clientRequestID, maintenance, clientDetection, opsErrorLogger, endpointNorm, hooksPreAuth, gin.HandlerFunc(apiKeyAuth), requireGroupAnthropic, gatewayHooks, h.OpenAIGateway.ResponsesWebSocket)
+	r.GET("/responses/:id", clientRequestID, maintenance, opsErrorLogger, endpointNorm, hooksPreAuth, gin.HandlerFunc(apiKeyAuth), requireGroupAnthropic, gatewayHooks, h.BackgroundResponse.Get)
+	r.DELETE("/responses/:id", clientRequestID, maintenance, opsErrorLogger, endpointNorm, hooksPreAuth, gin.HandlerFunc(apiKeyAuth), requireGroupAnthropic, gatewayHooks, h.BackgroundResponse.Delete)
 	// OpenAI Chat Completions API（不带v1前缀的别名）— auto-route based on group platform
-	r.POST("/chat/completions", bodyLimit, clientRequestID, opsErrorLogger, endpointNorm, gin.HandlerFunc(apiKeyAuth), requireGroupAnthropic, func(c *gin.Context) {
+	chatCompletionsHandler := func(c *gin.Context) {
 		if getGroupPlatform(c) == service.PlatformOpenAI {
 			h.OpenAIGateway.ChatCompletions(c)
 			return
 		}
 		h.Gateway.ChatCompletions(c)
-	})
+	}
+	r.POST("/chat/completions", bodyLimit, clientRequestID, maintenance, clientDetection, opsErrorLogger, endpointNorm, hooksPreAuth, gin.HandlerFunc(apiKeyAuth), requireGroupAnthropic, headerPolicy, upstreamRetries, idempotency, queueAnthropic, gatewayHooks, contentLog, sseReplay, responseCache, chatMultiChoice, modelAlias, routingRules, canarySplit, reasoningChat, moderationFilter, systemPromptChat, sanitizeChat, contextOverflowChat, shadowMirror(degradedFallback(providerFallback(chatCompletionsHandler))))
+
+	// Azure OpenAI 风格路由：/openai/deployments/{deployment}/...?api-version=...，支持 api-key 头鉴权。
+	// deployment 名映射为模型后复用上述端点；completions/embeddings/images 仍仅 OpenAI 分组支持。
+	azure := r.Group("/openai")
+	azure.Use(bodyLimit)
+	azure.Use(clientRequestID)
+	azure.Use(maintenance)
+	azure.Use(clientDetection)
+	azure.Use(opsErrorLogger)
+	azure.Use(endpointNorm)
+	azure.Use(hooksPreAuth)
+	azure.Use(gin.HandlerFunc(apiKeyAuth))
+	azure.Use(requireGroupAnthropic)
+	azure.Use(headerPolicy)
+	azure.Use(upstreamRetries)
+	azure.Use(idempotency)
+	azure.Use(queueAnthropic)
+	azure.Use(gatewayHooks, contentLog)
+	{
+		deployment := azure.Group("/deployments/:deployment", handler.AzureDeploymentMiddleware(cfg))
+		deployment.POST("/chat/completions", sseReplay, responseCache, chatMultiChoice, reasoningChat, moderationFilter, systemPromptChat, sanitizeChat, contextOverflowChat, chatCompletionsHandler)
+		deployment.POST("/completions", sseReplay, responseCache, moderationFilter, requireOpenAIGroup("Legacy completions are not supported for this platform", h.OpenAIGateway.Completions))
+		deployment.POST("/embeddings", requireOpenAIGroup("Embeddings are not supported for this platform", h.OpenAIGateway.Embeddings))
+		deployment.POST("/images/generations", requireOpenAIGroup("Image generation is not supported for this platform", h.OpenAIGateway.ImageGenerations))
+		// Azure Responses API 在请求体中携带 model，不经过 deployment 段
+		azure.POST("/responses", sseReplay, responseCache, conversationStore, modelAlias, routingRules, canarySplit, reasoningResponses, moderationFilter, systemPromptResponses, sanitizeResponses, contextOverflowResponses, shadowMirror(degradedFallback(providerFallback(responsesHandler))))
+	}
+
+	// AWS Bedrock Runtime 风格路由：/model/{modelId}/invoke 与 /model/{modelId}/invoke-with-response-stream，
+	// 请求体为 Anthropic on Bedrock 格式，转换后复用 /v1/messages 路由
+	bedrockRuntime := r.Group("/model")
+	bedrockRuntime.Use(bodyLimit)
+	bedrockRuntime.Use(clientRequestID)
+	bedrockRuntime.Use(maintenance)
+	bedrockRuntime.Use(clientDetection)
+	bedrockRuntime.Use(opsErrorLogger)
+	bedrockRuntime.Use(endpointNorm)
+	bedrockRuntime.Use(hooksPreAuth)
+	bedrockRuntime.Use(gin.HandlerFunc(apiKeyAuth))
+	bedrockRuntime.Use(requireGroupAnthropic)
+	bedrockRuntime.Use(headerPolicy)
+	bedrockRuntime.Use(upstreamRetries)
+	bedrockRuntime.Use(idempotency)
+	bedrockRuntime.Use(queueAnthropic)
+	bedrockRuntime.Use(gatewayHooks, contentLog)
+	{
+		bedrockRuntime.POST("/*modelAction", handler.BedrockInvokeMiddleware(), moderationFilter, systemPromptMessages, sanitizeMessages, contextOverflowMessages, messagesHandler)
+	}
 
 	// Antigravity 模型列表
-	r.GET("/antigravity/models", gin.HandlerFunc(apiKeyAuth), requireGroupAnthropic, h.Gateway.AntigravityModels)
+	r.GET("/antigravity/models", maintenance, hooksPreAuth, gin.HandlerFunc(apiKeyAuth), requireGroupAnthropic, gatewayHooks, h.Gateway.AntigravityModels)
 
 	// Antigravity 专用路由（仅使用 antigravity 账户，不混合调度）
 	antigravityV1 := r.Group("/antigravity/v1")
 	antigravityV1.Use(bodyLimit)
 	antigravityV1.Use(clientRequestID)
+	antigravityV1.Use(maintenance)
+	antigravityV1.Use(clientDetection)
 	antigravityV1.Use(opsErrorLogger)
+	antigravityV1.Use(deadLetter)
 	antigravityV1.Use(endpointNorm)
 	antigravityV1.Use(middleware.ForcePlatform(service.PlatformAntigravity))
+	antigravityV1.Use(hooksPreAuth)
 	antigravityV1.Use(gin.HandlerFunc(apiKeyAuth))
 	antigravityV1.Use(requireGroupAnthropic)
+	antigravityV1.Use(headerPolicy)
+	antigravityV1.Use(upstreamRetries)
+	antigravityV1.Use(idempotency)
+	antigravityV1.Use(queueAnthropic)
+	antigravityV1.Use(gatewayHooks, contentLog)
 	{
-		antigravityV1.POST("/messages", h.Gateway.Messages)
-		antigravityV1.POST("/messages/count_tokens", h.Gateway.CountTokens)
+		antigravityV1.POST("/messages", modelAlias, moderationFilter, systemPromptMessages, sanitizeMessages, contextOverflowMessages, h.Gateway.Messages)
+		antigravityV1.POST("/messages/count_tokens", modelAlias, h.Gateway.CountTokens)
 		antigravityV1.GET("/models", h.Gateway.AntigravityModels)
 		antigravityV1.GET("/usage", h.Gateway.Usage)
 	}
@@ -152,26 +392,42 @@ func RegisterGatewayRoutes(
 	antigravityV1Beta := r.Group("/antigravity/v1beta")
 	antigravityV1Beta.Use(bodyLimit)
 	antigravityV1Beta.Use(clientRequestID)
+	antigravityV1Beta.Use(maintenanceGoogle)
+	antigravityV1Beta.Use(clientDetection)
 	antigravityV1Beta.Use(opsErrorLogger)
+	antigravityV1Beta.Use(deadLetter)
 	antigravityV1Beta.Use(endpointNorm)
 	antigravityV1Beta.Use(middleware.ForcePlatform(service.PlatformAntigravity))
+	antigravityV1Beta.Use(hooksPreAuth)
 	antigravityV1Beta.Use(middleware.APIKeyAuthWithSubscriptionGoogle(apiKeyService, subscriptionService, cfg))
 	antigravityV1Beta.Use(requireGroupGoogle)
+	antigravityV1Beta.Use(headerPolicy)
+	antigravityV1Beta.Use(upstreamRetries)
+	antigravityV1Beta.Use(idempotencyGoogle)
+	antigravityV1Beta.Use(queueGoogle)
+	antigravityV1Beta.Use(gatewayHooks, contentLog)
 	{
 		antigravityV1Beta.GET("/models", h.Gateway.GeminiV1BetaListModels)
 		antigravityV1Beta.GET("/models/:model", h.Gateway.GeminiV1BetaGetModel)
-		antigravityV1Beta.POST("/models/*modelAction", h.Gateway.GeminiV1BetaModels)
+		antigravityV1Beta.POST("/models/*modelAction", modelAlias, moderationFilter, systemPromptGemini, h.Gateway.GeminiV1BetaModels)
 	}
 
 	// Sora 专用路由（强制使用 sora 平台）
 	soraV1 := r.Group("/sora/v1")
 	soraV1.Use(soraBodyLimit)
 	soraV1.Use(clientRequestID)
+	soraV1.Use(maintenance)
+	soraV1.Use(clientDetection)
 	soraV1.Use(opsErrorLogger)
 	soraV1.Use(endpointNorm)
 	soraV1.Use(middleware.ForcePlatform(service.PlatformSora))
+	soraV1.Use(hooksPreAuth)
 	soraV1.Use(gin.HandlerFunc(apiKeyAuth))
 	soraV1.Use(requireGroupAnthropic)
+	soraV1.Use(headerPolicy)
+	soraV1.Use(upstreamRetries)
+	soraV1.Use(queueAnthropic)
+	soraV1.Use(gatewayHooks, contentLog)
 	{
 		soraV1.POST("/chat/completions", h.SoraGateway.ChatCompletions)
 		soraV1.GET("/models", h.Gateway.Models)
@@ -187,6 +443,22 @@ func RegisterGatewayRoutes(
 	r.GET("/sora/media-signed/*filepath", h.SoraGateway.MediaProxySigned)
 }
 
+// requireOpenAIGroup 仅允许 OpenAI 分组访问 next，其余分组返回 404。
+func requireOpenAIGroup(message string, next gin.HandlerFunc) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if getGroupPlatform(c) != service.PlatformOpenAI {
+			c.JSON(http.StatusNotFound, gin.H{
+				"error": gin.H{
+					"type":    "not_found_error",
+					"message": message,
+				},
+			})
+			return
+		}
+		next(c)
+	}
+}
+
 // getGroupPlatform extracts the group platform from the API Key stored in context.
 func getGroupPlatform(c *gin.Context) string {
 	apiKey, ok := middleware.GetAPIKeyFromContext(c)
@@ -14,6 +14,10 @@ import (
 )
 
 func newGatewayRoutesTestRouter() *gin.Engine {
+	return newGatewayRoutesTestRouterWithConfig(&config.Config{})
+}
+
+func newGatewayRoutesTestRouterWithConfig(cfg *config.Config) *gin.Engine {
 	gin.SetMode(gin.TestMode)
 	router := gin.New()
 
@@ -31,7 +35,7 @@ func newGatewayRoutesTestRouter() *gin.Engine {
 		nil,
 		nil,
 		nil,
-		&config.Config{},
+		cfg,
 	)
 
 	return router
@@ -49,3 +53,57 @@ func TestGatewayRoutesOpenAIResponsesCompactPathIsRegistered(t *testing.T) {
 		require.NotEqual(t, http.StatusNotFound, w.Code, "path=%s should hit OpenAI responses handler", path)
 	}
 }
+
+func TestGatewayRoutesAzureDeploymentPathsAreRegistered(t *testing.T) {
+	router := newGatewayRoutesTestRouterWithConfig(&config.Config{Gateway: config.GatewayConfig{MaxBodySize: 1 << 20}})
+
+	for _, path := range []string{
+		"/openai/deployments/my-gpt/chat/completions?api-version=2024-10-21",
+		"/openai/responses?api-version=preview",
+	} {
+		req := httptest.NewRequest(http.MethodPost, path, strings.NewReader(`{"messages":[]}`))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+
+		router.ServeHTTP(w, req)
+		require.NotEqual(t, http.StatusNotFound, w.Code, "path=%s should be registered", path)
+	}
+
+	// 非 OpenAI 分组访问仅 OpenAI 支持的 Azure 端点返回 404 错误体
+	req := httptest.NewRequest(http.MethodPost, "/openai/deployments/my-embed/embeddings?api-version=2024-10-21", strings.NewReader(`{"input":"hi"}`))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	require.Equal(t, http.StatusNotFound, w.Code)
+	require.Contains(t, w.Body.String(), "Embeddings are not supported for this platform")
+}
+
+func TestGatewayRoutesBedrockInvokePathsAreRegistered(t *testing.T) {
+	router := newGatewayRoutesTestRouterWithConfig(&config.Config{Gateway: config.GatewayConfig{MaxBodySize: 1 << 20}})
+
+	for _, path := range []string{
+		"/model/anthropic.claude-3-5-sonnet-20241022-v2:0/invoke",
+		"/model/us.anthropic.claude-sonnet-4-20250514-v1:0/invoke-with-response-stream",
+	} {
+		req := httptest.NewRequest(http.MethodPost, path, strings.NewReader(`{"anthropic_version":"bedrock-2023-05-31","max_tokens":8,"messages":[]}`))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+
+		router.ServeHTTP(w, req)
+		require.NotEqual(t, http.StatusNotFound, w.Code, "path=%s should be registered", path)
+	}
+}
+
+func TestGatewayRoutesResponsesInputTokensCountedLocally(t *testing.T) {
+	router := newGatewayRoutesTestRouterWithConfig(&config.Config{Gateway: config.GatewayConfig{MaxBodySize: 1 << 20}})
+
+	for _, path := range []string{"/v1/responses/input_tokens", "/responses/input_tokens"} {
+		req := httptest.NewRequest(http.MethodPost, path, strings.NewReader(`{"model":"gpt-5","input":"hello world"}`))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+
+		router.ServeHTTP(w, req)
+		require.Equal(t, http.StatusOK, w.Code, "path=%s", path)
+		require.Contains(t, w.Body.String(), `"object":"response.input_tokens"`)
+	}
+}
@@ -0,0 +1,103 @@
+package server
+
+import (
+	"context"
+	"net/http"
+	"sync"
+)
+
+// ShutdownDrainer 跟踪在途请求，用于优雅停机时排空流式响应。
+//
+// http.Server.Shutdown 不会等待被接管的连接（WebSocket、h2c），且 h2c 连接在 Shutdown 后仍可能收到新流，
+// 因此在最外层包装 handler：进入排空后新请求直接返回 503 并要求关闭连接，已在途的请求（含 WebSocket 会话）计入等待。
+// 批处理等进程内分发直接调用 gin 路由，不经过此包装。
+type ShutdownDrainer struct {
+	mu       sync.Mutex
+	draining bool
+	inFlight int
+	idle     chan struct{} // 排空开始后、在途请求归零时关闭
+}
+
+// NewShutdownDrainer 创建停机排空跟踪器
+func NewShutdownDrainer() *ShutdownDrainer {
+	return &ShutdownDrainer{idle: make(chan struct{})}
+}
+
+// ProvideShutdownDrainer 提供停机排空跟踪器
+func ProvideShutdownDrainer() *ShutdownDrainer {
+	return NewShutdownDrainer()
+}
+
+// shutdownDrainProbePaths 探针请求不计入在途请求，排空期间照常响应：
+// /livez 保持存活避免实例被重启，/readyz 报告未就绪
+var shutdownDrainProbePaths = map[string]struct{}{"/livez": {}, "/readyz": {}}
+
+// Wrap 包装 handler：排空期间拒绝新请求，否则计入在途请求
+func (d *ShutdownDrainer) Wrap(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if _, ok := shutdownDrainProbePaths[r.URL.Path]; ok {
+			next.ServeHTTP(w, r)
+			return
+		}
+		if !d.enter() {
+			w.Header().Set("Connection", "close")
+			w.Header().Set("Retry-After", "1")
+			w.Header().Set("Content-Type", "application/json; charset=utf-8")
+			w.WriteHeader(http.StatusServiceUnavailable)
+			_, _ = w.Write([]byte(`{"code":"SERVER_SHUTTING_DOWN","message":"server is shutting down, retry on another instance"}`))
+			return
+		}
+		defer d.leave()
+		next.ServeHTTP(w, r)
+	})
+}
+
+// BeginDrain 进入排空状态，此后的新请求被拒绝
+func (d *ShutdownDrainer) BeginDrain() {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if d.draining {
+		return
+	}
+	d.draining = true
+	if d.inFlight == 0 {
+		close(d.idle)
+	}
+}
+
+// InFlight 当前在途请求数
+func (d *ShutdownDrainer) InFlight() int {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return d.inFlight
+}
+
+// Wait 进入排空状态并等待在途请求全部结束；ctx 到期时返回 ctx.Err()
+func (d *ShutdownDrainer) Wait(ctx context.Context) error {
+	d.BeginDrain()
+	select {
+	case <-d.idle:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (d *ShutdownDrainer) enter() bool {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if d.draining {
+		return false
+	}
+	d.inFlight++
+	return true
+}
+
+func (d *ShutdownDrainer) leave() {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.inFlight--
+	if d.draining && d.inFlight == 0 {
+		close(d.idle)
+	}
+}
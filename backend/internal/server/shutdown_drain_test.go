@@ -0,0 +1,76 @@
+//go:build unit
+
+package server
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestShutdownDrainer_WaitsForInFlightAndRejectsNew(t *testing.T) {
+	drainer := NewShutdownDrainer()
+	started := make(chan struct{})
+	finish := make(chan struct{})
+	handler := drainer.Wrap(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		close(started)
+		<-finish
+		w.WriteHeader(http.StatusOK)
+	}))
+
+	inFlight := httptest.NewRecorder()
+	done := make(chan struct{})
+	go func() {
+		handler.ServeHTTP(inFlight, httptest.NewRequest(http.MethodPost, "/v1/messages", nil))
+		close(done)
+	}()
+	<-started
+
+	drainer.BeginDrain()
+	rejected := httptest.NewRecorder()
+	handler.ServeHTTP(rejected, httptest.NewRequest(http.MethodGet, "/health", nil))
+	require.Equal(t, http.StatusServiceUnavailable, rejected.Code)
+	require.Equal(t, "close", rejected.Header().Get("Connection"))
+	require.Contains(t, rejected.Body.String(), "SERVER_SHUTTING_DOWN")
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	require.ErrorIs(t, drainer.Wait(ctx), context.DeadlineExceeded)
+	require.Equal(t, 1, drainer.InFlight())
+
+	close(finish)
+	<-done
+	require.NoError(t, drainer.Wait(context.Background()))
+	require.Equal(t, http.StatusOK, inFlight.Code)
+	require.Equal(t, 0, drainer.InFlight())
+}
+
+func TestShutdownDrainer_WaitWithoutInFlight(t *testing.T) {
+	drainer := NewShutdownDrainer()
+	require.NoError(t, drainer.Wait(context.Background()))
+	// 重复进入排空状态不应 panic
+	drainer.BeginDrain()
+	require.NoError(t, drainer.Wait(context.Background()))
+}
+
+func TestShutdownDrainer_ProbesBypassDrain(t *testing.T) {
+	drainer := NewShutdownDrainer()
+	handler := drainer.Wrap(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	drainer.BeginDrain()
+
+	for _, path := range []string{"/livez", "/readyz"} {
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, httptest.NewRequest(http.MethodGet, path, nil))
+		require.Equal(t, http.StatusOK, w.Code, path)
+	}
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/health", nil))
+	require.Equal(t, http.StatusServiceUnavailable, w.Code)
+	require.Equal(t, 0, drainer.InFlight())
+}
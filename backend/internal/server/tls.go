@@ -0,0 +1,202 @@
+package server
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"log"
+	"net"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ShaohongDong/sub2api/internal/config"
+
+	"golang.org/x/crypto/acme"
+	"golang.org/x/crypto/acme/autocert"
+)
+
+// TLSManager 内置 TLS 终止：提供握手证书（证书文件热加载或 ACME 自动签发续期），
+// 并在启用 ACME 时监听 HTTP-01 质询端口。未启用时为空实现，服务以明文 HTTP 启动。
+type TLSManager struct {
+	tlsConfig *tls.Config
+
+	challengeAddr    string
+	challengeHandler http.Handler
+	challengeServer  *http.Server
+}
+
+// ProvideTLSManager 提供 TLS 管理器
+func ProvideTLSManager(cfg *config.Config) (*TLSManager, error) {
+	return NewTLSManager(cfg.Server.TLS)
+}
+
+// NewTLSManager 按配置创建 TLS 管理器；证书文件模式下启动时即加载一次证书，配置错误立即失败
+func NewTLSManager(cfg config.TLSConfig) (*TLSManager, error) {
+	m := &TLSManager{}
+	if !cfg.Enabled {
+		return m, nil
+	}
+
+	if cfg.ACME.Enabled {
+		domains := make([]string, 0, len(cfg.ACME.Domains))
+		for _, domain := range cfg.ACME.Domains {
+			domains = append(domains, strings.ToLower(strings.TrimSpace(domain)))
+		}
+		manager := &autocert.Manager{
+			Prompt:     autocert.AcceptTOS,
+			HostPolicy: autocert.HostWhitelist(domains...),
+			Cache:      autocert.DirCache(cfg.ACME.CacheDir),
+			Email:      strings.TrimSpace(cfg.ACME.Email),
+		}
+		if dir := strings.TrimSpace(cfg.ACME.DirectoryURL); dir != "" {
+			manager.Client = &acme.Client{DirectoryURL: dir}
+		}
+		// autocert 的 TLSConfig 已包含 h2 / http/1.1 与 TLS-ALPN-01 所需的 acme-tls/1
+		m.tlsConfig = manager.TLSConfig()
+		m.tlsConfig.MinVersion = tls.VersionTLS12
+		if addr := strings.TrimSpace(cfg.ACME.HTTPChallengeAddr); addr != "" {
+			m.challengeAddr = addr
+			// 非质询请求重定向到 HTTPS
+			m.challengeHandler = manager.HTTPHandler(nil)
+		}
+		log.Printf("TLS enabled with ACME certificates for %s (cache: %s)", strings.Join(domains, ", "), cfg.ACME.CacheDir)
+		return m, nil
+	}
+
+	reloader, err := newCertReloader(cfg.CertFile, cfg.KeyFile, time.Duration(cfg.ReloadCheckSeconds)*time.Second)
+	if err != nil {
+		return nil, err
+	}
+	m.tlsConfig = &tls.Config{
+		MinVersion:     tls.VersionTLS12,
+		NextProtos:     []string{"h2", "http/1.1"},
+		GetCertificate: reloader.GetCertificate,
+	}
+	log.Printf("TLS enabled with certificate file %s (reload check: %ds)", cfg.CertFile, cfg.ReloadCheckSeconds)
+	return m, nil
+}
+
+// Enabled 是否由服务自身终止 TLS
+func (m *TLSManager) Enabled() bool {
+	return m != nil && m.tlsConfig != nil
+}
+
+// Serve 在 ln 上启动服务：启用 TLS 时以 HTTPS 提供服务（HTTP/2 经 ALPN 协商），并在后台启动 ACME HTTP-01 质询监听
+func (m *TLSManager) Serve(srv *http.Server, ln net.Listener) error {
+	if !m.Enabled() {
+		return srv.Serve(ln)
+	}
+	if m.challengeHandler != nil {
+		m.challengeServer = &http.Server{
+			Addr:              m.challengeAddr,
+			Handler:           m.challengeHandler,
+			ReadHeaderTimeout: srv.ReadHeaderTimeout,
+			IdleTimeout:       srv.IdleTimeout,
+		}
+		go func() {
+			if err := m.challengeServer.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+				log.Printf("ACME HTTP challenge listener on %s failed: %v", m.challengeAddr, err)
+			}
+		}()
+	}
+	return srv.ServeTLS(ln, "", "")
+}
+
+// Shutdown 关闭 ACME HTTP-01 质询监听
+func (m *TLSManager) Shutdown(ctx context.Context) error {
+	if m == nil || m.challengeServer == nil {
+		return nil
+	}
+	return m.challengeServer.Shutdown(ctx)
+}
+
+// certReloader 在 TLS 握手时按间隔检查证书文件修改时间，变更后重新加载；
+// 加载失败时保留旧证书，避免续期工具写文件过程中的半成品导致服务中断。
+type certReloader struct {
+	certFile string
+	keyFile  string
+	interval time.Duration
+
+	mu          sync.RWMutex
+	cert        *tls.Certificate
+	certModTime time.Time
+	keyModTime  time.Time
+	lastCheck   time.Time
+}
+
+func newCertReloader(certFile, keyFile string, interval time.Duration) (*certReloader, error) {
+	r := &certReloader{certFile: certFile, keyFile: keyFile, interval: interval}
+	certInfo, keyInfo, err := r.stat()
+	if err != nil {
+		return nil, err
+	}
+	if err := r.load(certInfo.ModTime(), keyInfo.ModTime()); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+func (r *certReloader) stat() (os.FileInfo, os.FileInfo, error) {
+	certInfo, err := os.Stat(r.certFile)
+	if err != nil {
+		return nil, nil, fmt.Errorf("stat tls cert_file: %w", err)
+	}
+	keyInfo, err := os.Stat(r.keyFile)
+	if err != nil {
+		return nil, nil, fmt.Errorf("stat tls key_file: %w", err)
+	}
+	return certInfo, keyInfo, nil
+}
+
+func (r *certReloader) load(certModTime, keyModTime time.Time) error {
+	cert, err := tls.LoadX509KeyPair(r.certFile, r.keyFile)
+	if err != nil {
+		return fmt.Errorf("load tls certificate: %w", err)
+	}
+	r.mu.Lock()
+	r.cert = &cert
+	r.certModTime = certModTime
+	r.keyModTime = keyModTime
+	r.lastCheck = time.Now()
+	r.mu.Unlock()
+	return nil
+}
+
+// GetCertificate 实现 tls.Config.GetCertificate
+func (r *certReloader) GetCertificate(*tls.ClientHelloInfo) (*tls.Certificate, error) {
+	r.mu.RLock()
+	cert, due := r.cert, r.interval > 0 && time.Since(r.lastCheck) >= r.interval
+	r.mu.RUnlock()
+	if due {
+		r.maybeReload()
+		r.mu.RLock()
+		cert = r.cert
+		r.mu.RUnlock()
+	}
+	return cert, nil
+}
+
+func (r *certReloader) maybeReload() {
+	r.mu.Lock()
+	r.lastCheck = time.Now()
+	certModTime, keyModTime := r.certModTime, r.keyModTime
+	r.mu.Unlock()
+
+	certInfo, keyInfo, err := r.stat()
+	if err != nil {
+		log.Printf("TLS certificate reload check failed: %v", err)
+		return
+	}
+	if certInfo.ModTime().Equal(certModTime) && keyInfo.ModTime().Equal(keyModTime) {
+		return
+	}
+	if err := r.load(certInfo.ModTime(), keyInfo.ModTime()); err != nil {
+		log.Printf("TLS certificate reload failed, keeping previous certificate: %v", err)
+		return
+	}
+	log.Printf("TLS certificate reloaded from %s", r.certFile)
+}
@@ -0,0 +1,105 @@
+//go:build unit
+
+package server
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/ShaohongDong/sub2api/internal/config"
+	"github.com/stretchr/testify/require"
+)
+
+func writeTestCertificate(t *testing.T, dir, commonName string, modTime time.Time) (string, string) {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	tmpl := &x509.Certificate{
+		SerialNumber: big.NewInt(time.Now().UnixNano()),
+		Subject:      pkix.Name{CommonName: commonName},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		DNSNames:     []string{commonName},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &key.PublicKey, key)
+	require.NoError(t, err)
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	require.NoError(t, err)
+
+	certFile := filepath.Join(dir, "tls.crt")
+	keyFile := filepath.Join(dir, "tls.key")
+	require.NoError(t, os.WriteFile(certFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0o600))
+	require.NoError(t, os.WriteFile(keyFile, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}), 0o600))
+	require.NoError(t, os.Chtimes(certFile, modTime, modTime))
+	require.NoError(t, os.Chtimes(keyFile, modTime, modTime))
+	return certFile, keyFile
+}
+
+func leafCommonName(t *testing.T, cert *tls.Certificate) string {
+	t.Helper()
+	leaf, err := x509.ParseCertificate(cert.Certificate[0])
+	require.NoError(t, err)
+	return leaf.Subject.CommonName
+}
+
+func TestCertReloaderReloadsChangedFiles(t *testing.T) {
+	dir := t.TempDir()
+	base := time.Now().Add(-time.Minute)
+	certFile, keyFile := writeTestCertificate(t, dir, "old.example.com", base)
+
+	reloader, err := newCertReloader(certFile, keyFile, time.Millisecond)
+	require.NoError(t, err)
+	cert, err := reloader.GetCertificate(nil)
+	require.NoError(t, err)
+	require.Equal(t, "old.example.com", leafCommonName(t, cert))
+
+	writeTestCertificate(t, dir, "new.example.com", base.Add(time.Second))
+	time.Sleep(2 * time.Millisecond)
+	cert, err = reloader.GetCertificate(nil)
+	require.NoError(t, err)
+	require.Equal(t, "new.example.com", leafCommonName(t, cert))
+
+	// 写入损坏文件时保留旧证书
+	require.NoError(t, os.WriteFile(certFile, []byte("broken"), 0o600))
+	require.NoError(t, os.Chtimes(certFile, base.Add(2*time.Second), base.Add(2*time.Second)))
+	time.Sleep(2 * time.Millisecond)
+	cert, err = reloader.GetCertificate(nil)
+	require.NoError(t, err)
+	require.Equal(t, "new.example.com", leafCommonName(t, cert))
+}
+
+func TestNewTLSManager(t *testing.T) {
+	m, err := NewTLSManager(config.TLSConfig{})
+	require.NoError(t, err)
+	require.False(t, m.Enabled())
+
+	_, err = NewTLSManager(config.TLSConfig{Enabled: true, CertFile: "/nonexistent.crt", KeyFile: "/nonexistent.key"})
+	require.Error(t, err)
+
+	certFile, keyFile := writeTestCertificate(t, t.TempDir(), "api.example.com", time.Now())
+	m, err = NewTLSManager(config.TLSConfig{Enabled: true, CertFile: certFile, KeyFile: keyFile, ReloadCheckSeconds: 60})
+	require.NoError(t, err)
+	require.True(t, m.Enabled())
+	require.Contains(t, m.tlsConfig.NextProtos, "h2")
+
+	m, err = NewTLSManager(config.TLSConfig{Enabled: true, ACME: config.ACMEConfig{
+		Enabled:           true,
+		Domains:           []string{"API.example.com"},
+		CacheDir:          t.TempDir(),
+		HTTPChallengeAddr: ":80",
+	}})
+	require.NoError(t, err)
+	require.True(t, m.Enabled())
+	require.Contains(t, m.tlsConfig.NextProtos, "acme-tls/1")
+	require.NotNil(t, m.challengeHandler)
+}
@@ -119,6 +119,10 @@ func (a *Account) IsSchedulable() bool {
 	if a.TempUnschedulableUntil != nil && now.Before(*a.TempUnschedulableUntil) {
 		return false
 	}
+	// 账号熔断（gateway.account_circuit_breaker）处于 open 时暂不调度
+	if !AccountCircuitAllows(a.ID) {
+		return false
+	}
 	return true
 }
 
@@ -847,6 +851,10 @@ func (a *Account) IsOpenAIResponsesWebSocketV2Enabled() bool {
 	if a == nil || !a.IsOpenAI() || a.Extra == nil {
 		return false
 	}
+	// ChatGPT 网页后端模式没有 WebSocket 接口
+	if a.IsChatGPTWebUpstream() {
+		return false
+	}
 	if a.IsOpenAIOAuth() {
 		if enabled, ok := a.Extra["openai_oauth_responses_websockets_v2_enabled"].(bool); ok {
 			return enabled
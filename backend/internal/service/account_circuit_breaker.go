@@ -0,0 +1,260 @@
+package service
+
+import (
+	"log/slog"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/ShaohongDong/sub2api/internal/config"
+)
+
+// 账号熔断状态
+const (
+	AccountCircuitClosed   = "closed"
+	AccountCircuitOpen     = "open"
+	AccountCircuitHalfOpen = "half_open"
+)
+
+// accountCircuitBuckets 统计窗口划分的桶数
+const accountCircuitBuckets = 10
+
+// AccountCircuitState 账号熔断状态快照（本实例）
+type AccountCircuitState struct {
+	AccountID int64      `json:"account_id"`
+	State     string     `json:"state"`
+	Requests  int        `json:"requests"` // 当前窗口内的调用数
+	Failures  int        `json:"failures"`
+	SlowCalls int        `json:"slow_calls"`
+	OpenedAt  *time.Time `json:"opened_at,omitempty"`
+	// HalfOpenAt 熔断到期、重新接收流量的时间
+	HalfOpenAt *time.Time `json:"half_open_at,omitempty"`
+}
+
+type accountCircuitBucket struct {
+	index    int64 // 桶序号（unix nano / 桶宽），用于判断桶是否过期
+	total    int
+	failures int
+	slow     int
+}
+
+type accountCircuit struct {
+	mu                sync.Mutex
+	buckets           [accountCircuitBuckets]accountCircuitBucket
+	openedAt          time.Time // 非零表示处于 open / half-open
+	halfOpenSuccesses int
+}
+
+type accountCircuitRegistry struct {
+	cfg      atomic.Pointer[config.GatewayAccountCircuitBreakerConfig]
+	circuits sync.Map // int64 -> *accountCircuit
+
+	// remoteOpen 其他实例打开的熔断（accountID -> 到期时间），由 SharedStateService 定期同步
+	remoteOpen atomic.Pointer[map[int64]time.Time]
+	// onOpen 本实例熔断打开时的回调，用于发布到共享状态
+	onOpen atomic.Pointer[func(accountID int64, until time.Time)]
+}
+
+var defaultAccountCircuits = &accountCircuitRegistry{}
+
+// ConfigureAccountCircuitBreaker 设置账号熔断配置；未启用时清空已有状态，所有账号视为 closed
+func ConfigureAccountCircuitBreaker(cfg config.GatewayAccountCircuitBreakerConfig) {
+	defaultAccountCircuits.configure(cfg)
+}
+
+// RecordAccountUpstreamResult 记录一次账号上游调用结果：failed 表示连接失败或 5xx，latency 为响应头耗时
+func RecordAccountUpstreamResult(accountID int64, failed bool, latency time.Duration) {
+	defaultAccountCircuits.record(accountID, failed, latency, time.Now())
+}
+
+// AccountCircuitAllows 判断账号熔断器是否允许调度（open 状态返回 false）
+func AccountCircuitAllows(accountID int64) bool {
+	return defaultAccountCircuits.allows(accountID, time.Now())
+}
+
+// SnapshotAccountCircuits 返回非 closed 或窗口内有调用的账号熔断状态（open 在前，其余按账号 ID 排序）
+func SnapshotAccountCircuits() []AccountCircuitState {
+	return defaultAccountCircuits.snapshot(time.Now())
+}
+
+func (r *accountCircuitRegistry) configure(cfg config.GatewayAccountCircuitBreakerConfig) {
+	if !cfg.Enabled {
+		r.cfg.Store(nil)
+		r.circuits.Range(func(key, _ any) bool {
+			r.circuits.Delete(key)
+			return true
+		})
+		return
+	}
+	r.cfg.Store(&cfg)
+}
+
+func (r *accountCircuitRegistry) circuit(accountID int64) *accountCircuit {
+	circuit, ok := r.circuits.Load(accountID)
+	if !ok {
+		circuit, _ = r.circuits.LoadOrStore(accountID, &accountCircuit{})
+	}
+	return circuit.(*accountCircuit)
+}
+
+func (r *accountCircuitRegistry) allows(accountID int64, now time.Time) bool {
+	cfg := r.cfg.Load()
+	if cfg == nil || accountID <= 0 {
+		return true
+	}
+	if circuit, ok := r.circuits.Load(accountID); ok && circuit.(*accountCircuit).state(cfg, now) == AccountCircuitOpen {
+		return false
+	}
+	if remote := r.remoteOpen.Load(); remote != nil {
+		if until, ok := (*remote)[accountID]; ok && now.Before(until) {
+			return false
+		}
+	}
+	return true
+}
+
+func (r *accountCircuitRegistry) record(accountID int64, failed bool, latency time.Duration, now time.Time) {
+	cfg := r.cfg.Load()
+	if cfg == nil || accountID <= 0 {
+		return
+	}
+	slow := cfg.SlowCallMS > 0 && latency >= time.Duration(cfg.SlowCallMS)*time.Millisecond
+	if from, to, changed := r.circuit(accountID).record(cfg, failed, slow, now); changed {
+		slog.Warn("account_circuit.state_changed", "account_id", accountID, "from", from, "to", to)
+		if onOpen := r.onOpen.Load(); onOpen != nil && to == AccountCircuitOpen {
+			(*onOpen)(accountID, now.Add(time.Duration(cfg.OpenSeconds)*time.Second))
+		}
+	}
+}
+
+func (r *accountCircuitRegistry) snapshot(now time.Time) []AccountCircuitState {
+	out := make([]AccountCircuitState, 0)
+	cfg := r.cfg.Load()
+	if cfg == nil {
+		return out
+	}
+	r.circuits.Range(func(key, value any) bool {
+		st := value.(*accountCircuit).snapshot(cfg, key.(int64), now)
+		if st.State != AccountCircuitClosed || st.Requests > 0 {
+			out = append(out, st)
+		}
+		return true
+	})
+	sort.Slice(out, func(a, b int) bool {
+		if (out[a].State == AccountCircuitOpen) != (out[b].State == AccountCircuitOpen) {
+			return out[a].State == AccountCircuitOpen
+		}
+		return out[a].AccountID < out[b].AccountID
+	})
+	return out
+}
+
+func bucketWidth(cfg *config.GatewayAccountCircuitBreakerConfig) int64 {
+	width := int64(time.Duration(cfg.WindowSeconds) * time.Second / accountCircuitBuckets)
+	if width <= 0 {
+		width = int64(time.Second)
+	}
+	return width
+}
+
+// stateLocked 计算当前状态；open 到期后惰性转为 half-open
+func (c *accountCircuit) stateLocked(cfg *config.GatewayAccountCircuitBreakerConfig, now time.Time) string {
+	if c.openedAt.IsZero() {
+		return AccountCircuitClosed
+	}
+	if now.Sub(c.openedAt) < time.Duration(cfg.OpenSeconds)*time.Second {
+		return AccountCircuitOpen
+	}
+	return AccountCircuitHalfOpen
+}
+
+func (c *accountCircuit) state(cfg *config.GatewayAccountCircuitBreakerConfig, now time.Time) string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.stateLocked(cfg, now)
+}
+
+// windowLocked 汇总统计窗口内的调用
+func (c *accountCircuit) windowLocked(cfg *config.GatewayAccountCircuitBreakerConfig, now time.Time) (total, failures, slow int) {
+	current := now.UnixNano() / bucketWidth(cfg)
+	for i := range c.buckets {
+		b := &c.buckets[i]
+		if current-b.index < accountCircuitBuckets {
+			total += b.total
+			failures += b.failures
+			slow += b.slow
+		}
+	}
+	return total, failures, slow
+}
+
+func (c *accountCircuit) resetLocked() {
+	c.buckets = [accountCircuitBuckets]accountCircuitBucket{}
+	c.halfOpenSuccesses = 0
+}
+
+// record 记录调用结果并按需切换状态，返回切换前后的状态
+func (c *accountCircuit) record(cfg *config.GatewayAccountCircuitBreakerConfig, failed, slow bool, now time.Time) (string, string, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	switch from := c.stateLocked(cfg, now); from {
+	case AccountCircuitOpen:
+		// 熔断期间仍在进行的请求结果不影响状态
+		return from, from, false
+	case AccountCircuitHalfOpen:
+		if failed || slow {
+			c.openedAt = now
+			c.resetLocked()
+			return from, AccountCircuitOpen, true
+		}
+		c.halfOpenSuccesses++
+		if c.halfOpenSuccesses < cfg.HalfOpenSuccesses {
+			return from, from, false
+		}
+		c.openedAt = time.Time{}
+		c.resetLocked()
+		return from, AccountCircuitClosed, true
+	}
+
+	index := now.UnixNano() / bucketWidth(cfg)
+	b := &c.buckets[index%accountCircuitBuckets]
+	if b.index != index {
+		*b = accountCircuitBucket{index: index}
+	}
+	b.total++
+	if failed {
+		b.failures++
+	}
+	if slow {
+		b.slow++
+	}
+
+	total, failures, slowCalls := c.windowLocked(cfg, now)
+	if total < cfg.MinRequests {
+		return AccountCircuitClosed, AccountCircuitClosed, false
+	}
+	tripped := float64(failures)/float64(total) >= cfg.ErrorRateThreshold ||
+		(cfg.SlowCallMS > 0 && float64(slowCalls)/float64(total) >= cfg.SlowCallRateThreshold)
+	if !tripped {
+		return AccountCircuitClosed, AccountCircuitClosed, false
+	}
+	c.openedAt = now
+	c.resetLocked()
+	return AccountCircuitClosed, AccountCircuitOpen, true
+}
+
+func (c *accountCircuit) snapshot(cfg *config.GatewayAccountCircuitBreakerConfig, accountID int64, now time.Time) AccountCircuitState {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	st := AccountCircuitState{AccountID: accountID, State: c.stateLocked(cfg, now)}
+	st.Requests, st.Failures, st.SlowCalls = c.windowLocked(cfg, now)
+	if !c.openedAt.IsZero() {
+		openedAt := c.openedAt
+		halfOpenAt := openedAt.Add(time.Duration(cfg.OpenSeconds) * time.Second)
+		st.OpenedAt = &openedAt
+		st.HalfOpenAt = &halfOpenAt
+	}
+	return st
+}
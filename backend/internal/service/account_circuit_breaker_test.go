@@ -0,0 +1,109 @@
+//go:build unit
+
+package service
+
+import (
+	"testing"
+	"time"
+
+	"github.com/ShaohongDong/sub2api/internal/config"
+	"github.com/stretchr/testify/require"
+)
+
+func testCircuitRegistry() *accountCircuitRegistry {
+	r := &accountCircuitRegistry{}
+	r.configure(config.GatewayAccountCircuitBreakerConfig{
+		Enabled:               true,
+		WindowSeconds:         60,
+		MinRequests:           4,
+		ErrorRateThreshold:    0.5,
+		SlowCallMS:            1000,
+		SlowCallRateThreshold: 0.75,
+		OpenSeconds:           30,
+		HalfOpenSuccesses:     2,
+	})
+	return r
+}
+
+func TestAccountCircuit_OpensOnErrorRateAndRecovers(t *testing.T) {
+	r := testCircuitRegistry()
+	now := time.Now()
+
+	r.record(1, true, 10*time.Millisecond, now)
+	r.record(1, true, 10*time.Millisecond, now)
+	r.record(1, false, 10*time.Millisecond, now)
+	// 未达到 MinRequests 时不熔断
+	require.True(t, r.allows(1, now))
+
+	r.record(1, false, 10*time.Millisecond, now)
+	require.False(t, r.allows(1, now))
+	require.True(t, r.allows(2, now))
+
+	snapshot := r.snapshot(now)
+	require.Len(t, snapshot, 1)
+	require.Equal(t, AccountCircuitOpen, snapshot[0].State)
+	require.NotNil(t, snapshot[0].HalfOpenAt)
+
+	// 熔断期满后进入 half-open，连续成功 HalfOpenSuccesses 次后关闭
+	later := now.Add(31 * time.Second)
+	require.True(t, r.allows(1, later))
+	r.record(1, false, 10*time.Millisecond, later)
+	require.Equal(t, AccountCircuitHalfOpen, r.snapshot(later)[0].State)
+	r.record(1, false, 10*time.Millisecond, later)
+	require.Empty(t, r.snapshot(later))
+	require.True(t, r.allows(1, later))
+}
+
+func TestAccountCircuit_HalfOpenFailureReopens(t *testing.T) {
+	r := testCircuitRegistry()
+	now := time.Now()
+	for i := 0; i < 4; i++ {
+		r.record(1, true, 10*time.Millisecond, now)
+	}
+	later := now.Add(31 * time.Second)
+	r.record(1, true, 10*time.Millisecond, later)
+	require.False(t, r.allows(1, later))
+	require.True(t, r.allows(1, later.Add(31*time.Second)))
+}
+
+func TestAccountCircuit_SlowCallsAndWindowExpiry(t *testing.T) {
+	r := testCircuitRegistry()
+	now := time.Now()
+	for i := 0; i < 3; i++ {
+		r.record(1, false, 2*time.Second, now)
+	}
+	// 窗口外的慢调用不计入
+	later := now.Add(61 * time.Second)
+	r.record(1, false, 2*time.Second, later)
+	require.True(t, r.allows(1, later))
+
+	for i := 0; i < 3; i++ {
+		r.record(1, false, 2*time.Second, later)
+	}
+	require.False(t, r.allows(1, later))
+}
+
+func TestAccountCircuit_DisabledAllowsAll(t *testing.T) {
+	r := testCircuitRegistry()
+	now := time.Now()
+	for i := 0; i < 4; i++ {
+		r.record(1, true, 0, now)
+	}
+	require.False(t, r.allows(1, now))
+
+	r.configure(config.GatewayAccountCircuitBreakerConfig{})
+	require.True(t, r.allows(1, now))
+	require.Empty(t, r.snapshot(now))
+}
+
+func TestAccount_IsSchedulable_CircuitOpen(t *testing.T) {
+	ConfigureAccountCircuitBreaker(config.GatewayAccountCircuitBreakerConfig{
+		Enabled: true, WindowSeconds: 60, MinRequests: 1, ErrorRateThreshold: 0.5, OpenSeconds: 30, HalfOpenSuccesses: 1,
+	})
+	t.Cleanup(func() { ConfigureAccountCircuitBreaker(config.GatewayAccountCircuitBreakerConfig{}) })
+
+	account := &Account{ID: 9201, Status: StatusActive, Schedulable: true}
+	require.True(t, account.IsSchedulable())
+	RecordAccountUpstreamResult(account.ID, true, time.Millisecond)
+	require.False(t, account.IsSchedulable())
+}
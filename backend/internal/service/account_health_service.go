@@ -0,0 +1,414 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/ShaohongDong/sub2api/internal/config"
+)
+
+var errAccountHealthNoProber = errors.New("account health probe unavailable")
+
+// AccountHealthState 账号健康状态（进程内）
+type AccountHealthState struct {
+	AccountID           int64      `json:"account_id"`
+	Healthy             bool       `json:"healthy"`
+	ConsecutiveFailures int        `json:"consecutive_failures"`
+	LastStatusCode      int        `json:"last_status_code,omitempty"`
+	LastError           string     `json:"last_error,omitempty"`
+	LastFailureAt       *time.Time `json:"last_failure_at,omitempty"`
+	UnhealthySince      *time.Time `json:"unhealthy_since,omitempty"`
+	ProbeAttempts       int        `json:"probe_attempts"`
+	LastProbeAt         *time.Time `json:"last_probe_at,omitempty"`
+	LastProbeError      string     `json:"last_probe_error,omitempty"`
+	// LastProbeProxyError 最近一次探测失败发生在连接账号代理阶段（代理不可达 / 认证失败），而非上游账号本身
+	LastProbeProxyError bool       `json:"last_probe_proxy_error,omitempty"`
+	NextProbeAt         *time.Time `json:"next_probe_at,omitempty"`
+	RecoveredAt         *time.Time `json:"recovered_at,omitempty"`
+
+	// disabledByError 账号因错误被置为 error 状态，恢复时需要一并清除
+	disabledByError bool
+	windowStart     time.Time
+}
+
+func (st *AccountHealthState) clone() *AccountHealthState {
+	cp := *st
+	return &cp
+}
+
+// AccountHealthService 根据上游 401/403/5xx 跟踪账号健康：
+// 窗口内连续失败达到阈值即标记不健康并移出调度，随后按指数退避定期探测，探测成功自动恢复。
+type AccountHealthService struct {
+	accountRepo      AccountRepository
+	accountTestSvc   *AccountTestService
+	rateLimitSvc     *RateLimitService
+	tempUnschedCache TempUnschedCache
+	cfg              config.AccountHealthConfig
+	leader           *LeaderElectionService
+
+	mu     sync.Mutex
+	states map[int64]*AccountHealthState
+
+	// probe 探测账号连通性，便于测试替换
+	probe func(ctx context.Context, accountID int64) error
+
+	stopCh   chan struct{}
+	stopOnce sync.Once
+	wg       sync.WaitGroup
+}
+
+// NewAccountHealthService 创建账号健康检查服务
+func NewAccountHealthService(
+	accountRepo AccountRepository,
+	accountTestSvc *AccountTestService,
+	rateLimitSvc *RateLimitService,
+	tempUnschedCache TempUnschedCache,
+	cfg *config.Config,
+) *AccountHealthService {
+	s := &AccountHealthService{
+		accountRepo:      accountRepo,
+		accountTestSvc:   accountTestSvc,
+		rateLimitSvc:     rateLimitSvc,
+		tempUnschedCache: tempUnschedCache,
+		states:           make(map[int64]*AccountHealthState),
+		stopCh:           make(chan struct{}),
+	}
+	if cfg != nil {
+		s.cfg = cfg.AccountHealth
+	}
+	s.probe = s.probeWithAccountTest
+	return s
+}
+
+// SetLeaderElection 设置集群领导者选举（可选依赖）；启用集群模式时只有领导者执行探测
+func (s *AccountHealthService) SetLeaderElection(leader *LeaderElectionService) {
+	s.leader = leader
+}
+
+// Start 启动探测循环
+func (s *AccountHealthService) Start() {
+	if s == nil || !s.cfg.Enabled {
+		return
+	}
+	interval := time.Duration(s.cfg.ProbeIntervalSeconds) * time.Second
+	if interval <= 0 {
+		interval = 30 * time.Second
+	}
+	s.wg.Add(1)
+	go func() {
+		defer s.wg.Done()
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				s.runDueProbes()
+			case <-s.stopCh:
+				return
+			}
+		}
+	}()
+	slog.Info("account_health.service_started",
+		"failure_threshold", s.failureThreshold(),
+		"probe_interval_seconds", int(interval/time.Second),
+	)
+}
+
+// Stop 停止探测循环
+func (s *AccountHealthService) Stop() {
+	if s == nil {
+		return
+	}
+	s.stopOnce.Do(func() {
+		close(s.stopCh)
+	})
+	s.wg.Wait()
+}
+
+// RecordFailure 记录一次上游失败；仅 401/403/5xx 计入健康判定。
+// disabled 表示调用方已将账号移出调度（如 403 已置为 error），此时直接进入不健康并开始探测。
+func (s *AccountHealthService) RecordFailure(ctx context.Context, account *Account, statusCode int, errorMsg string, disabled bool) {
+	s.recordFailure(ctx, account, statusCode, errorMsg, disabled, false)
+}
+
+// MarkUnhealthy 不经失败阈值直接将账号标记为不健康并开始探测（用于预热等主动检查发现的凭证失效）
+func (s *AccountHealthService) MarkUnhealthy(ctx context.Context, account *Account, statusCode int, errorMsg string) {
+	s.recordFailure(ctx, account, statusCode, errorMsg, false, true)
+}
+
+func (s *AccountHealthService) recordFailure(ctx context.Context, account *Account, statusCode int, errorMsg string, disabled, force bool) {
+	if s == nil || !s.cfg.Enabled || account == nil {
+		return
+	}
+	if statusCode != 401 && statusCode != 403 && statusCode < 500 {
+		return
+	}
+
+	now := time.Now()
+	s.mu.Lock()
+	st, ok := s.states[account.ID]
+	if !ok {
+		st = &AccountHealthState{AccountID: account.ID, Healthy: true}
+		s.states[account.ID] = st
+	}
+	if !st.Healthy {
+		// 已处于不健康状态，仅刷新最近错误，探测节奏不变
+		st.LastStatusCode = statusCode
+		st.LastError = errorMsg
+		st.LastFailureAt = &now
+		s.mu.Unlock()
+		return
+	}
+	if st.windowStart.IsZero() || now.Sub(st.windowStart) > s.failureWindow() {
+		st.windowStart = now
+		st.ConsecutiveFailures = 0
+	}
+	st.ConsecutiveFailures++
+	st.LastStatusCode = statusCode
+	st.LastError = errorMsg
+	st.LastFailureAt = &now
+
+	if !disabled && !force && st.ConsecutiveFailures < s.failureThreshold() {
+		s.mu.Unlock()
+		return
+	}
+
+	nextProbe := now.Add(s.probeBackoff(0))
+	st.Healthy = false
+	st.UnhealthySince = &now
+	st.ProbeAttempts = 0
+	st.NextProbeAt = &nextProbe
+	st.RecoveredAt = nil
+	st.disabledByError = disabled && statusCode < 500
+	failures := st.ConsecutiveFailures
+	s.mu.Unlock()
+
+	if !disabled {
+		s.holdUnschedulable(ctx, account.ID, nextProbe, statusCode, errorMsg)
+	}
+	slog.Warn("account_health.marked_unhealthy",
+		"account_id", account.ID,
+		"status_code", statusCode,
+		"consecutive_failures", failures,
+		"next_probe_at", nextProbe,
+	)
+}
+
+// GetState 返回单个账号的健康状态；未记录过失败的账号返回 nil
+func (s *AccountHealthService) GetState(accountID int64) *AccountHealthState {
+	if s == nil {
+		return nil
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	st, ok := s.states[accountID]
+	if !ok {
+		return nil
+	}
+	return st.clone()
+}
+
+// ListStates 返回所有被跟踪账号的健康状态，不健康的账号排在前面
+func (s *AccountHealthService) ListStates() []*AccountHealthState {
+	if s == nil {
+		return nil
+	}
+	s.mu.Lock()
+	out := make([]*AccountHealthState, 0, len(s.states))
+	for _, st := range s.states {
+		out = append(out, st.clone())
+	}
+	s.mu.Unlock()
+	sort.Slice(out, func(i, j int) bool {
+		if out[i].Healthy != out[j].Healthy {
+			return !out[i].Healthy
+		}
+		return out[i].AccountID < out[j].AccountID
+	})
+	return out
+}
+
+// Reset 清除账号的健康跟踪记录（不改变账号调度状态）
+func (s *AccountHealthService) Reset(accountID int64) {
+	if s == nil {
+		return
+	}
+	s.mu.Lock()
+	delete(s.states, accountID)
+	s.mu.Unlock()
+}
+
+// runDueProbes 探测到期的不健康账号。
+// 集群模式下非领导者不探测：到期账号的调度保持期已过，直接释放本地跟踪，
+// 账号重新参与调度，若再次失败会重新标记（领导者标记的账号仍由领导者探测恢复）。
+func (s *AccountHealthService) runDueProbes() {
+	now := time.Now()
+	leader := s.leader.IsLeader()
+	var due []int64
+	s.mu.Lock()
+	for id, st := range s.states {
+		if !st.Healthy && st.NextProbeAt != nil && !st.NextProbeAt.After(now) {
+			if !leader {
+				delete(s.states, id)
+				continue
+			}
+			due = append(due, id)
+		}
+	}
+	s.mu.Unlock()
+
+	for _, id := range due {
+		select {
+		case <-s.stopCh:
+			return
+		default:
+		}
+		s.probeAccount(id)
+	}
+}
+
+// probeAccount 探测单个账号并根据结果恢复或延后下次探测
+func (s *AccountHealthService) probeAccount(accountID int64) {
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Minute)
+	defer cancel()
+
+	probeErr := s.probe(ctx, accountID)
+	now := time.Now()
+
+	s.mu.Lock()
+	st, ok := s.states[accountID]
+	if !ok || st.Healthy {
+		s.mu.Unlock()
+		return
+	}
+	st.LastProbeAt = &now
+	if probeErr != nil {
+		st.ProbeAttempts++
+		st.LastProbeError = probeErr.Error()
+		st.LastProbeProxyError = IsProxyConnectError(probeErr)
+		nextProbe := now.Add(s.probeBackoff(st.ProbeAttempts))
+		st.NextProbeAt = &nextProbe
+		attempts, disabledByError, proxyErr := st.ProbeAttempts, st.disabledByError, st.LastProbeProxyError
+		statusCode, lastErr := st.LastStatusCode, st.LastError
+		s.mu.Unlock()
+
+		if !disabledByError {
+			s.holdUnschedulable(ctx, accountID, nextProbe, statusCode, lastErr)
+		}
+		if proxyErr {
+			slog.Warn("account_health.probe_proxy_failed", "account_id", accountID, "attempts", attempts, "next_probe_at", nextProbe, "error", probeErr)
+			return
+		}
+		slog.Info("account_health.probe_failed", "account_id", accountID, "attempts", attempts, "next_probe_at", nextProbe, "error", probeErr)
+		return
+	}
+
+	disabledByError := st.disabledByError
+	st.Healthy = true
+	st.ConsecutiveFailures = 0
+	st.LastProbeError = ""
+	st.LastProbeProxyError = false
+	st.NextProbeAt = nil
+	st.UnhealthySince = nil
+	st.RecoveredAt = &now
+	st.disabledByError = false
+	st.windowStart = time.Time{}
+	s.mu.Unlock()
+
+	if disabledByError {
+		if err := s.accountRepo.ClearError(ctx, accountID); err != nil {
+			slog.Warn("account_health.clear_error_failed", "account_id", accountID, "error", err)
+		}
+	}
+	if s.rateLimitSvc != nil {
+		if err := s.rateLimitSvc.ClearTempUnschedulable(ctx, accountID); err != nil {
+			slog.Warn("account_health.clear_temp_unsched_failed", "account_id", accountID, "error", err)
+		}
+	}
+	slog.Info("account_health.recovered", "account_id", accountID)
+}
+
+// probeWithAccountTest 复用账号测试连接作为健康探测
+func (s *AccountHealthService) probeWithAccountTest(ctx context.Context, accountID int64) error {
+	if s.accountTestSvc == nil {
+		return errAccountHealthNoProber
+	}
+	result, err := s.accountTestSvc.RunTestBackground(ctx, accountID, s.cfg.ProbeModel)
+	if err != nil {
+		return err
+	}
+	if result.Status != "success" {
+		if result.ErrorMessage == "" {
+			return errors.New("health probe failed")
+		}
+		return errors.New(result.ErrorMessage)
+	}
+	return nil
+}
+
+// holdUnschedulable 将账号临时移出调度直到下次探测
+func (s *AccountHealthService) holdUnschedulable(ctx context.Context, accountID int64, until time.Time, statusCode int, errorMsg string) {
+	state := &TempUnschedState{
+		UntilUnix:       until.Unix(),
+		TriggeredAtUnix: time.Now().Unix(),
+		StatusCode:      statusCode,
+		ErrorMessage:    "Health check: " + strconv.Itoa(statusCode) + " " + errorMsg,
+		RuleIndex:       -1,
+	}
+	reason := state.ErrorMessage
+	if raw, err := json.Marshal(state); err == nil {
+		reason = string(raw)
+	}
+	if err := s.accountRepo.SetTempUnschedulable(ctx, accountID, until, reason); err != nil {
+		slog.Warn("account_health.set_temp_unsched_failed", "account_id", accountID, "error", err)
+		return
+	}
+	if s.tempUnschedCache != nil {
+		if err := s.tempUnschedCache.SetTempUnsched(ctx, accountID, state); err != nil {
+			slog.Warn("temp_unsched_cache_set_failed", "account_id", accountID, "error", err)
+		}
+	}
+}
+
+func (s *AccountHealthService) failureThreshold() int {
+	if s.cfg.FailureThreshold <= 0 {
+		return 3
+	}
+	return s.cfg.FailureThreshold
+}
+
+func (s *AccountHealthService) failureWindow() time.Duration {
+	if s.cfg.FailureWindowSeconds <= 0 {
+		return 5 * time.Minute
+	}
+	return time.Duration(s.cfg.FailureWindowSeconds) * time.Second
+}
+
+// probeBackoff 第 attempts 次失败后的探测间隔：base * 2^attempts，封顶 max
+func (s *AccountHealthService) probeBackoff(attempts int) time.Duration {
+	base := time.Duration(s.cfg.ProbeBackoffBaseSeconds) * time.Second
+	if base <= 0 {
+		base = time.Minute
+	}
+	maxBackoff := time.Duration(s.cfg.ProbeBackoffMaxSeconds) * time.Second
+	if maxBackoff < base {
+		maxBackoff = 30 * time.Minute
+		if maxBackoff < base {
+			maxBackoff = base
+		}
+	}
+	backoff := base
+	for i := 0; i < attempts; i++ {
+		backoff *= 2
+		if backoff >= maxBackoff {
+			return maxBackoff
+		}
+	}
+	return backoff
+}
@@ -0,0 +1,142 @@
+//go:build unit
+
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/ShaohongDong/sub2api/internal/config"
+	"github.com/stretchr/testify/require"
+)
+
+type accountHealthRepoStub struct {
+	mockAccountRepoForGemini
+	tempUnschedUntil  map[int64]time.Time
+	clearErrorCalls   int
+	clearTempUnschIDs []int64
+}
+
+func (r *accountHealthRepoStub) SetTempUnschedulable(ctx context.Context, id int64, until time.Time, reason string) error {
+	if r.tempUnschedUntil == nil {
+		r.tempUnschedUntil = make(map[int64]time.Time)
+	}
+	r.tempUnschedUntil[id] = until
+	return nil
+}
+
+func (r *accountHealthRepoStub) ClearTempUnschedulable(ctx context.Context, id int64) error {
+	r.clearTempUnschIDs = append(r.clearTempUnschIDs, id)
+	return nil
+}
+
+func (r *accountHealthRepoStub) ClearError(ctx context.Context, id int64) error {
+	r.clearErrorCalls++
+	return nil
+}
+
+func newAccountHealthTestService(repo *accountHealthRepoStub) *AccountHealthService {
+	cfg := &config.Config{AccountHealth: config.AccountHealthConfig{
+		Enabled:                 true,
+		FailureThreshold:        3,
+		FailureWindowSeconds:    60,
+		ProbeBackoffBaseSeconds: 10,
+		ProbeBackoffMaxSeconds:  50,
+	}}
+	rateLimit := NewRateLimitService(repo, nil, cfg, nil, nil)
+	return NewAccountHealthService(repo, nil, rateLimit, nil, cfg)
+}
+
+func TestAccountHealth_MarksUnhealthyAfterThreshold(t *testing.T) {
+	repo := &accountHealthRepoStub{}
+	svc := newAccountHealthTestService(repo)
+	account := &Account{ID: 1}
+
+	svc.RecordFailure(context.Background(), account, 502, "bad gateway", false)
+	svc.RecordFailure(context.Background(), account, 400, "ignored", false)
+	svc.RecordFailure(context.Background(), account, 503, "unavailable", false)
+	require.True(t, svc.GetState(1).Healthy)
+	require.Empty(t, repo.tempUnschedUntil)
+
+	svc.RecordFailure(context.Background(), account, 500, "boom", false)
+	state := svc.GetState(1)
+	require.False(t, state.Healthy)
+	require.Equal(t, 3, state.ConsecutiveFailures)
+	require.NotNil(t, state.NextProbeAt)
+	require.Contains(t, repo.tempUnschedUntil, int64(1))
+}
+
+func TestAccountHealth_DisabledAccountTrackedImmediately(t *testing.T) {
+	repo := &accountHealthRepoStub{}
+	svc := newAccountHealthTestService(repo)
+
+	svc.RecordFailure(context.Background(), &Account{ID: 2}, 403, "forbidden", true)
+	state := svc.GetState(2)
+	require.False(t, state.Healthy)
+	// 已由调用方移出调度，不再重复设置临时不可调度
+	require.Empty(t, repo.tempUnschedUntil)
+
+	svc.probe = func(ctx context.Context, accountID int64) error { return nil }
+	svc.probeAccount(2)
+	require.True(t, svc.GetState(2).Healthy)
+	require.Equal(t, 1, repo.clearErrorCalls)
+	require.Equal(t, []int64{2}, repo.clearTempUnschIDs)
+}
+
+func TestAccountHealth_ProbeFailureBacksOff(t *testing.T) {
+	repo := &accountHealthRepoStub{}
+	svc := newAccountHealthTestService(repo)
+	account := &Account{ID: 3}
+	for i := 0; i < 3; i++ {
+		svc.RecordFailure(context.Background(), account, 401, "unauthorized", false)
+	}
+	svc.probe = func(ctx context.Context, accountID int64) error { return errors.New("still failing") }
+
+	for i := 1; i <= 3; i++ {
+		svc.probeAccount(3)
+	}
+	state := svc.GetState(3)
+	require.False(t, state.Healthy)
+	require.Equal(t, 3, state.ProbeAttempts)
+	require.Equal(t, "still failing", state.LastProbeError)
+	require.WithinDuration(t, time.Now().Add(50*time.Second), *state.NextProbeAt, 2*time.Second)
+	require.Equal(t, 0, repo.clearErrorCalls)
+}
+
+func TestAccountHealth_ProbeProxyFailureFlagged(t *testing.T) {
+	repo := &accountHealthRepoStub{}
+	svc := newAccountHealthTestService(repo)
+	account := &Account{ID: 5}
+	for i := 0; i < 3; i++ {
+		svc.RecordFailure(context.Background(), account, 502, "bad gateway", false)
+	}
+
+	svc.probe = func(ctx context.Context, accountID int64) error {
+		return errors.New(`Proxy connection failed: Post "https://api.anthropic.com/v1/messages": proxyconnect tcp: dial tcp 10.0.0.1:8080: connect: connection refused`)
+	}
+	svc.probeAccount(5)
+	require.True(t, svc.GetState(5).LastProbeProxyError)
+
+	svc.probe = func(ctx context.Context, accountID int64) error { return errors.New("API returned 401") }
+	svc.probeAccount(5)
+	require.False(t, svc.GetState(5).LastProbeProxyError)
+}
+
+func TestAccountHealth_ProbeBackoff(t *testing.T) {
+	svc := newAccountHealthTestService(&accountHealthRepoStub{})
+	require.Equal(t, 10*time.Second, svc.probeBackoff(0))
+	require.Equal(t, 20*time.Second, svc.probeBackoff(1))
+	require.Equal(t, 40*time.Second, svc.probeBackoff(2))
+	require.Equal(t, 50*time.Second, svc.probeBackoff(3))
+	require.Equal(t, 50*time.Second, svc.probeBackoff(10))
+}
+
+func TestAccountHealth_DisabledConfigIgnoresFailures(t *testing.T) {
+	repo := &accountHealthRepoStub{}
+	svc := NewAccountHealthService(repo, nil, nil, nil, &config.Config{})
+	svc.RecordFailure(context.Background(), &Account{ID: 4}, 500, "boom", true)
+	require.Nil(t, svc.GetState(4))
+	require.Empty(t, svc.ListStates())
+}
@@ -0,0 +1,201 @@
+package service
+
+import (
+	mathrand "math/rand"
+	"sort"
+	"sync"
+	"time"
+)
+
+const (
+	// accountLatencyWindowSize 每个账号保留的最近延迟样本数
+	accountLatencyWindowSize = 256
+	// accountLatencySampleTTL 超过该时长的样本不再参与统计，避免早已恢复的慢账号长期被降权
+	accountLatencySampleTTL = 30 * time.Minute
+	// accountLatencyStatsTTL 百分位计算结果的缓存时长（调度热路径上避免每次排序样本）
+	accountLatencyStatsTTL = time.Second
+	// latencyAwareMinWeight 延迟加权的最低权重，保证慢账号仍有少量流量以刷新样本
+	latencyAwareMinWeight = 0.05
+)
+
+// AccountLatencyStats 账号滚动延迟统计（本实例，最近 accountLatencyWindowSize 个且未过期的样本）。
+// 流式请求取首字时间，非流式请求取总耗时。
+type AccountLatencyStats struct {
+	AccountID int64 `json:"account_id"`
+	Samples   int   `json:"samples"`
+	P50Ms     int64 `json:"p50_ms"`
+	P95Ms     int64 `json:"p95_ms"`
+}
+
+type accountLatencySample struct {
+	ms int64
+	at int64 // unix nano
+}
+
+type accountLatencyWindow struct {
+	mu      sync.Mutex
+	samples [accountLatencyWindowSize]accountLatencySample
+	next    int
+	count   int
+
+	stats       AccountLatencyStats
+	statsExpiry int64 // unix nano，0 表示需要重新计算
+}
+
+func (w *accountLatencyWindow) add(ms int64, now time.Time) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.samples[w.next] = accountLatencySample{ms: ms, at: now.UnixNano()}
+	w.next = (w.next + 1) % accountLatencyWindowSize
+	if w.count < accountLatencyWindowSize {
+		w.count++
+	}
+	w.statsExpiry = 0
+}
+
+func (w *accountLatencyWindow) snapshot(accountID int64, now time.Time) AccountLatencyStats {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	nowNano := now.UnixNano()
+	if w.statsExpiry > nowNano {
+		return w.stats
+	}
+	cutoff := now.Add(-accountLatencySampleTTL).UnixNano()
+	values := make([]int64, 0, w.count)
+	for i := 0; i < w.count; i++ {
+		if sample := w.samples[i]; sample.at >= cutoff {
+			values = append(values, sample.ms)
+		}
+	}
+	stats := AccountLatencyStats{AccountID: accountID, Samples: len(values)}
+	if len(values) > 0 {
+		sort.Slice(values, func(a, b int) bool { return values[a] < values[b] })
+		stats.P50Ms = latencyPercentile(values, 0.50)
+		stats.P95Ms = latencyPercentile(values, 0.95)
+	}
+	w.stats = stats
+	w.statsExpiry = now.Add(accountLatencyStatsTTL).UnixNano()
+	return stats
+}
+
+// latencyPercentile 最近秩法取百分位（values 已升序）
+func latencyPercentile(values []int64, p float64) int64 {
+	idx := int(float64(len(values))*p+0.5) - 1
+	if idx < 0 {
+		idx = 0
+	}
+	if idx >= len(values) {
+		idx = len(values) - 1
+	}
+	return values[idx]
+}
+
+type accountLatencyTracker struct {
+	windows sync.Map // int64 -> *accountLatencyWindow
+}
+
+var defaultAccountLatency = &accountLatencyTracker{}
+
+func (t *accountLatencyTracker) record(accountID int64, latency time.Duration, now time.Time) {
+	if accountID <= 0 || latency <= 0 {
+		return
+	}
+	window, ok := t.windows.Load(accountID)
+	if !ok {
+		window, _ = t.windows.LoadOrStore(accountID, &accountLatencyWindow{})
+	}
+	window.(*accountLatencyWindow).add(latency.Milliseconds(), now)
+}
+
+func (t *accountLatencyTracker) get(accountID int64, now time.Time) AccountLatencyStats {
+	window, ok := t.windows.Load(accountID)
+	if !ok {
+		return AccountLatencyStats{AccountID: accountID}
+	}
+	return window.(*accountLatencyWindow).snapshot(accountID, now)
+}
+
+// RecordAccountLatency 记录一次账号请求延迟样本
+func RecordAccountLatency(accountID int64, latency time.Duration) {
+	defaultAccountLatency.record(accountID, latency, time.Now())
+}
+
+// recordAccountRequestLatency 按请求结果记录延迟样本：流式请求取首字时间，否则取总耗时
+func recordAccountRequestLatency(account *Account, duration time.Duration, firstTokenMs *int) {
+	if account == nil {
+		return
+	}
+	if firstTokenMs != nil && *firstTokenMs > 0 {
+		duration = time.Duration(*firstTokenMs) * time.Millisecond
+	}
+	RecordAccountLatency(account.ID, duration)
+}
+
+// GetAccountLatencyStats 返回账号的滚动延迟统计
+func GetAccountLatencyStats(accountID int64) AccountLatencyStats {
+	return defaultAccountLatency.get(accountID, time.Now())
+}
+
+// SnapshotAccountLatency 返回所有有样本账号的滚动延迟统计（按账号 ID 排序）
+func SnapshotAccountLatency() []AccountLatencyStats {
+	now := time.Now()
+	out := make([]AccountLatencyStats, 0)
+	defaultAccountLatency.windows.Range(func(key, value any) bool {
+		stats := value.(*accountLatencyWindow).snapshot(key.(int64), now)
+		if stats.Samples > 0 {
+			out = append(out, stats)
+		}
+		return true
+	})
+	sort.Slice(out, func(a, b int) bool { return out[a].AccountID < out[b].AccountID })
+	return out
+}
+
+// selectByLatencyWeight 按滚动 p50 延迟加权随机选择账号（权重 = 最快 p50 / 自身 p50）。
+// 样本不足 minSamples 的账号按中性权重 1 参与，保证新账号仍能获得流量以积累样本；
+// 少于两个账号有足够样本时无法比较快慢，回退为 LRU 选择。
+func selectByLatencyWeight(accounts []accountWithLoad, preferOAuth bool, minSamples int) *accountWithLoad {
+	if len(accounts) <= 1 {
+		return selectByLRU(accounts, preferOAuth)
+	}
+	now := time.Now()
+	p50s := make([]int64, len(accounts))
+	var fastest int64
+	measured := 0
+	for i, acc := range accounts {
+		stats := defaultAccountLatency.get(acc.account.ID, now)
+		if stats.Samples < minSamples || stats.P50Ms <= 0 {
+			continue
+		}
+		p50s[i] = stats.P50Ms
+		measured++
+		if fastest == 0 || stats.P50Ms < fastest {
+			fastest = stats.P50Ms
+		}
+	}
+	if measured < 2 {
+		return selectByLRU(accounts, preferOAuth)
+	}
+
+	weights := make([]float64, len(accounts))
+	total := 0.0
+	for i := range accounts {
+		weight := 1.0
+		if p50s[i] > 0 {
+			weight = float64(fastest) / float64(p50s[i])
+			if weight < latencyAwareMinWeight {
+				weight = latencyAwareMinWeight
+			}
+		}
+		weights[i] = weight
+		total += weight
+	}
+	pick := mathrand.Float64() * total
+	for i, weight := range weights {
+		pick -= weight
+		if pick < 0 {
+			return &accounts[i]
+		}
+	}
+	return &accounts[len(accounts)-1]
+}
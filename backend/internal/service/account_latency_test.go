@@ -0,0 +1,74 @@
+//go:build unit
+
+package service
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestAccountLatencyTracker_Percentiles(t *testing.T) {
+	tracker := &accountLatencyTracker{}
+	now := time.Now()
+	for i := 1; i <= 100; i++ {
+		tracker.record(1, time.Duration(i)*time.Millisecond, now)
+	}
+	// 过期样本不计入统计
+	tracker.record(2, time.Second, now.Add(-accountLatencySampleTTL-time.Minute))
+
+	stats := tracker.get(1, now)
+	require.Equal(t, 100, stats.Samples)
+	require.Equal(t, int64(50), stats.P50Ms)
+	require.Equal(t, int64(95), stats.P95Ms)
+	require.Equal(t, 0, tracker.get(2, now).Samples)
+	require.Equal(t, 0, tracker.get(3, now).Samples)
+
+	// 窗口满后覆盖最旧样本
+	for i := 0; i < accountLatencyWindowSize; i++ {
+		tracker.record(1, 500*time.Millisecond, now.Add(time.Second))
+	}
+	stats = tracker.get(1, now.Add(2*time.Second))
+	require.Equal(t, accountLatencyWindowSize, stats.Samples)
+	require.Equal(t, int64(500), stats.P50Ms)
+}
+
+func TestSelectByLatencyWeight_PrefersFasterAccounts(t *testing.T) {
+	fast := &Account{ID: 9101, Priority: 1}
+	slow := &Account{ID: 9102, Priority: 1}
+	fresh := &Account{ID: 9103, Priority: 1}
+	for i := 0; i < 30; i++ {
+		RecordAccountLatency(fast.ID, 100*time.Millisecond)
+		RecordAccountLatency(slow.ID, 1000*time.Millisecond)
+	}
+	RecordAccountLatency(fresh.ID, 5*time.Second) // 样本不足，按中性权重参与
+
+	accounts := []accountWithLoad{
+		{account: fast, loadInfo: &AccountLoadInfo{AccountID: fast.ID}},
+		{account: slow, loadInfo: &AccountLoadInfo{AccountID: slow.ID}},
+		{account: fresh, loadInfo: &AccountLoadInfo{AccountID: fresh.ID}},
+	}
+	counts := map[int64]int{}
+	for i := 0; i < 3000; i++ {
+		counts[selectByLatencyWeight(accounts, false, 20).account.ID]++
+	}
+	// 权重约为 fast:slow:fresh = 1 : 0.1 : 1
+	require.Greater(t, counts[fast.ID], 4*counts[slow.ID])
+	require.Greater(t, counts[fresh.ID], 4*counts[slow.ID])
+	require.Positive(t, counts[slow.ID])
+
+	stats := GetAccountLatencyStats(slow.ID)
+	require.Equal(t, int64(1000), stats.P95Ms)
+	require.Contains(t, SnapshotAccountLatency(), stats)
+}
+
+func TestSelectByLatencyWeight_FallsBackToLRUWithoutSamples(t *testing.T) {
+	older := time.Now().Add(-time.Hour)
+	newer := time.Now()
+	accounts := []accountWithLoad{
+		{account: &Account{ID: 9201, LastUsedAt: &newer}, loadInfo: &AccountLoadInfo{}},
+		{account: &Account{ID: 9202, LastUsedAt: &older}, loadInfo: &AccountLoadInfo{}},
+	}
+	require.Equal(t, int64(9202), selectByLatencyWeight(accounts, false, 20).account.ID)
+}
@@ -10,8 +10,9 @@ import (
 )
 
 var (
-	ErrAccountNotFound = infraerrors.NotFound("ACCOUNT_NOT_FOUND", "account not found")
-	ErrAccountNilInput = infraerrors.BadRequest("ACCOUNT_NIL_INPUT", "account input cannot be nil")
+	ErrAccountNotFound    = infraerrors.NotFound("ACCOUNT_NOT_FOUND", "account not found")
+	ErrAccountNotArchived = infraerrors.BadRequest("ACCOUNT_NOT_ARCHIVED", "account is not archived")
+	ErrAccountNilInput    = infraerrors.BadRequest("ACCOUNT_NIL_INPUT", "account input cannot be nil")
 )
 
 type AccountRepository interface {
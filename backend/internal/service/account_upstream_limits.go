@@ -0,0 +1,102 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	infraerrors "github.com/ShaohongDong/sub2api/internal/pkg/errors"
+)
+
+// 上游限额数据来源
+const (
+	UpstreamLimitsSourceUpstream  = "upstream"  // 实时查询上游（Anthropic usage API / Codex 限流响应头 / Antigravity 配额接口）
+	UpstreamLimitsSourceLocal     = "local"     // 按本地配额策略与用量日志计算（Gemini）
+	UpstreamLimitsSourceEstimated = "estimated" // 由 session_window 推算（Setup Token，无 profile scope）
+)
+
+var ErrUpstreamLimitsUnsupported = infraerrors.BadRequest(
+	"UPSTREAM_LIMITS_UNSUPPORTED",
+	"upstream limits are only available for OAuth and Setup Token accounts",
+)
+
+// AccountUpstreamLimits 账号当前的上游限额窗口与套餐状态（统一格式）
+type AccountUpstreamLimits struct {
+	AccountID int64            `json:"account_id"`
+	Name      string           `json:"name"`
+	Platform  string           `json:"platform"`
+	Type      string           `json:"type"`
+	Plan      string           `json:"plan,omitempty"`
+	Status    string           `json:"status"`
+	Source    string           `json:"source"`
+	FetchedAt time.Time        `json:"fetched_at"`
+	Headroom  *AccountHeadroom `json:"headroom"`
+}
+
+// QueryUpstreamLimits 跳过用量缓存，立即向上游查询账号的限额窗口，并与本地限流状态一起返回。
+// 上游查询失败时仍返回本地状态，错误写入 headroom.usage_error。
+func (s *AccountUsageService) QueryUpstreamLimits(ctx context.Context, accountID int64) (*AccountUpstreamLimits, error) {
+	account, err := s.accountRepo.GetByID(ctx, accountID)
+	if err != nil {
+		return nil, err
+	}
+	if !account.CanGetUsage() && account.Type != AccountTypeSetupToken {
+		return nil, ErrUpstreamLimitsUnsupported
+	}
+
+	now := time.Now()
+	result := &AccountUpstreamLimits{
+		AccountID: account.ID,
+		Name:      account.Name,
+		Platform:  account.Platform,
+		Type:      account.Type,
+		Plan:      accountPlan(account),
+		Status:    account.Status,
+		Source:    upstreamLimitsSource(account),
+		FetchedAt: now,
+		Headroom:  accountLocalHeadroom(account, now),
+	}
+	s.invalidateUsageCache(account.ID)
+
+	var usage *UsageInfo
+	if account.IsOpenAIOAuth() {
+		// Codex 额度只能从响应头获取：强制探测一次，再复用快照解析逻辑
+		if s.cache != nil {
+			s.cache.openAIProbeCache.Store(account.ID, now)
+		}
+		updates, probeErr := s.probeOpenAICodexSnapshot(ctx, account)
+		if probeErr != nil {
+			result.Headroom.UsageError = probeErr.Error()
+		}
+		mergeAccountExtra(account, updates)
+		usage, err = s.getOpenAIUsage(ctx, account)
+	} else {
+		usage, err = s.GetUsage(ctx, account.ID)
+	}
+	if err != nil {
+		result.Headroom.UsageError = err.Error()
+		return result, nil
+	}
+	result.Headroom.Windows = usageWindows(usage)
+	return result, nil
+}
+
+// invalidateUsageCache 清除账号的上游用量缓存，下次 GetUsage 将重新请求上游
+func (s *AccountUsageService) invalidateUsageCache(accountID int64) {
+	if s.cache == nil {
+		return
+	}
+	s.cache.apiCache.Delete(accountID)
+	s.cache.antigravityCache.Delete(accountID)
+	s.cache.openAIProbeCache.Delete(accountID)
+}
+
+func upstreamLimitsSource(account *Account) string {
+	switch {
+	case account.Type == AccountTypeSetupToken:
+		return UpstreamLimitsSourceEstimated
+	case account.Platform == PlatformGemini:
+		return UpstreamLimitsSourceLocal
+	default:
+		return UpstreamLimitsSourceUpstream
+	}
+}
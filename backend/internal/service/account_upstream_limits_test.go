@@ -0,0 +1,66 @@
+//go:build unit
+
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	infraerrors "github.com/ShaohongDong/sub2api/internal/pkg/errors"
+	"github.com/stretchr/testify/require"
+)
+
+type upstreamLimitsFetcherStub struct {
+	calls int
+	resp  *ClaudeUsageResponse
+}
+
+func (f *upstreamLimitsFetcherStub) FetchUsage(ctx context.Context, accessToken, proxyURL string) (*ClaudeUsageResponse, error) {
+	return f.FetchUsageWithOptions(ctx, &ClaudeUsageFetchOptions{AccessToken: accessToken, ProxyURL: proxyURL})
+}
+
+func (f *upstreamLimitsFetcherStub) FetchUsageWithOptions(context.Context, *ClaudeUsageFetchOptions) (*ClaudeUsageResponse, error) {
+	f.calls++
+	return f.resp, nil
+}
+
+func TestQueryUpstreamLimits_BypassesUsageCache(t *testing.T) {
+	resetAt := time.Now().Add(72 * time.Hour).UTC().Truncate(time.Second)
+	fetcher := &upstreamLimitsFetcherStub{resp: &ClaudeUsageResponse{}}
+	fetcher.resp.FiveHour.Utilization = 12
+	fetcher.resp.SevenDay.Utilization = 80
+	fetcher.resp.SevenDay.ResetsAt = resetAt.Format(time.RFC3339)
+
+	rateLimitedUntil := time.Now().Add(time.Minute)
+	accounts := &mockAccountRepoForGemini{accountsByID: map[int64]*Account{
+		5: {ID: 5, Name: "claude-max", Platform: PlatformAnthropic, Type: AccountTypeOAuth, Status: StatusActive,
+			Credentials: map[string]any{"access_token": "tok", "subscription_type": "max"}, RateLimitResetAt: &rateLimitedUntil},
+	}}
+	cache := NewUsageCache()
+	cache.apiCache.Store(int64(5), &apiUsageCache{response: &ClaudeUsageResponse{}, timestamp: time.Now()})
+	cache.windowStatsCache.Store(int64(5), &windowStatsCache{stats: &WindowStats{}, timestamp: time.Now()})
+	svc := NewAccountUsageService(accounts, nil, fetcher, nil, nil, cache, nil)
+
+	limits, err := svc.QueryUpstreamLimits(context.Background(), 5)
+	require.NoError(t, err)
+	require.Equal(t, 1, fetcher.calls, "cached usage must not be served")
+	require.Equal(t, "max", limits.Plan)
+	require.Equal(t, UpstreamLimitsSourceUpstream, limits.Source)
+	require.True(t, limits.Headroom.RateLimited)
+	require.Empty(t, limits.Headroom.UsageError)
+	require.Len(t, limits.Headroom.Windows, 2)
+	require.Equal(t, "seven_day", limits.Headroom.Windows[1].Name)
+	require.InDelta(t, 20, limits.Headroom.Windows[1].RemainingPercent, 0.001)
+	require.True(t, resetAt.Equal(*limits.Headroom.Windows[1].ResetsAt))
+}
+
+func TestQueryUpstreamLimits_RejectsAPIKeyAccounts(t *testing.T) {
+	accounts := &mockAccountRepoForGemini{accountsByID: map[int64]*Account{
+		6: {ID: 6, Platform: PlatformAnthropic, Type: AccountTypeAPIKey},
+	}}
+	svc := NewAccountUsageService(accounts, nil, nil, nil, nil, NewUsageCache(), nil)
+
+	_, err := svc.QueryUpstreamLimits(context.Background(), 6)
+	require.Equal(t, "UPSTREAM_LIMITS_UNSUPPORTED", infraerrors.Reason(err))
+}
@@ -0,0 +1,101 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/ShaohongDong/sub2api/internal/pkg/usagestats"
+	"github.com/stretchr/testify/require"
+)
+
+type accountUsageBucketsAccountRepoStub struct {
+	AccountRepository
+
+	account *Account
+}
+
+func (s *accountUsageBucketsAccountRepoStub) GetByID(ctx context.Context, id int64) (*Account, error) {
+	if s.account == nil || s.account.ID != id {
+		return nil, ErrAccountNotFound
+	}
+	return s.account, nil
+}
+
+type accountUsageBucketsUsageRepoStub struct {
+	UsageLogRepository
+
+	buckets     []usagestats.AccountUsageBucket
+	granularity string
+}
+
+func (s *accountUsageBucketsUsageRepoStub) GetAccountUsageBuckets(ctx context.Context, accountID int64, startTime, endTime time.Time, granularity string) ([]usagestats.AccountUsageBucket, error) {
+	s.granularity = granularity
+	return s.buckets, nil
+}
+
+func TestAccountUsageService_GetAccountUsageBuckets(t *testing.T) {
+	staleLastUsed := time.Date(2026, 3, 1, 8, 0, 0, 0, time.UTC)
+	first := time.Date(2026, 3, 2, 9, 30, 0, 0, time.UTC)
+	latest := time.Date(2026, 3, 2, 10, 45, 0, 0, time.UTC)
+
+	usageRepo := &accountUsageBucketsUsageRepoStub{
+		buckets: []usagestats.AccountUsageBucket{
+			{Bucket: "2026-03-02 09:00", Requests: 3, Errors: 1, InputTokens: 100, OutputTokens: 60, ReasoningTokens: 20, Cost: 0.5, LastUsedAt: &first},
+			{Bucket: "2026-03-02 10:00", Requests: 1, InputTokens: 40, OutputTokens: 10, CacheReadTokens: 5, Cost: 0.25, LastUsedAt: &latest},
+			{Bucket: "2026-03-02 11:00", Errors: 2},
+		},
+	}
+	svc := &AccountUsageService{
+		accountRepo:  &accountUsageBucketsAccountRepoStub{account: &Account{ID: 7, LastUsedAt: &staleLastUsed}},
+		usageLogRepo: usageRepo,
+	}
+
+	start := time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)
+	end := start.Add(48 * time.Hour)
+	resp, err := svc.GetAccountUsageBuckets(context.Background(), 7, start, end, "hour")
+	require.NoError(t, err)
+	require.Equal(t, "hour", usageRepo.granularity)
+	require.Equal(t, int64(7), resp.AccountID)
+	require.Len(t, resp.Buckets, 3)
+
+	require.InDelta(t, 0.25, resp.Buckets[0].ErrorRate, 1e-9)
+	require.Zero(t, resp.Buckets[1].ErrorRate)
+	require.InDelta(t, 1.0, resp.Buckets[2].ErrorRate, 1e-9)
+
+	require.Equal(t, int64(4), resp.Totals.Requests)
+	require.Equal(t, int64(3), resp.Totals.Errors)
+	require.Equal(t, int64(140), resp.Totals.InputTokens)
+	require.Equal(t, int64(70), resp.Totals.OutputTokens)
+	require.Equal(t, int64(20), resp.Totals.ReasoningTokens)
+	require.Equal(t, int64(5), resp.Totals.CacheReadTokens)
+	require.InDelta(t, 0.75, resp.Totals.Cost, 1e-9)
+	require.InDelta(t, 3.0/7.0, resp.Totals.ErrorRate, 1e-9)
+	require.Equal(t, latest, *resp.Totals.LastUsedAt)
+	require.Equal(t, latest, *resp.LastUsedAt, "log activity newer than the account's last_used_at should win")
+}
+
+func TestAccountUsageService_GetAccountUsageBuckets_NoUsageKeepsAccountLastUsed(t *testing.T) {
+	lastUsed := time.Date(2026, 2, 1, 8, 0, 0, 0, time.UTC)
+	svc := &AccountUsageService{
+		accountRepo:  &accountUsageBucketsAccountRepoStub{account: &Account{ID: 7, LastUsedAt: &lastUsed}},
+		usageLogRepo: &accountUsageBucketsUsageRepoStub{},
+	}
+
+	resp, err := svc.GetAccountUsageBuckets(context.Background(), 7, time.Now().Add(-time.Hour), time.Now(), "day")
+	require.NoError(t, err)
+	require.Empty(t, resp.Buckets)
+	require.Zero(t, resp.Totals.ErrorRate)
+	require.Nil(t, resp.Totals.LastUsedAt)
+	require.Equal(t, lastUsed, *resp.LastUsedAt)
+}
+
+func TestAccountUsageService_GetAccountUsageBuckets_AccountNotFound(t *testing.T) {
+	svc := &AccountUsageService{
+		accountRepo:  &accountUsageBucketsAccountRepoStub{},
+		usageLogRepo: &accountUsageBucketsUsageRepoStub{},
+	}
+
+	_, err := svc.GetAccountUsageBuckets(context.Background(), 7, time.Now().Add(-time.Hour), time.Now(), "day")
+	require.ErrorIs(t, err, ErrAccountNotFound)
+}
@@ -4,6 +4,7 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"log"
 	"log/slog"
@@ -46,6 +47,11 @@ type UsageLogRepository interface {
 	GetUsageTrendWithFilters(ctx context.Context, startTime, endTime time.Time, granularity string, userID, apiKeyID, accountID, groupID int64, model string, requestType *int16, stream *bool, billingType *int8) ([]usagestats.TrendDataPoint, error)
 	GetModelStatsWithFilters(ctx context.Context, startTime, endTime time.Time, userID, apiKeyID, accountID, groupID int64, requestType *int16, stream *bool, billingType *int8) ([]usagestats.ModelStat, error)
 	GetGroupStatsWithFilters(ctx context.Context, startTime, endTime time.Time, userID, apiKeyID, accountID, groupID int64, requestType *int16, stream *bool, billingType *int8) ([]usagestats.GroupStat, error)
+	GetUsageAggregates(ctx context.Context, query usagestats.UsageAggregateQuery) ([]usagestats.UsageAggregate, error)
+	ForEachUsageAggregate(ctx context.Context, query usagestats.UsageAggregateQuery, fn func(*usagestats.UsageAggregate) error) error
+	ForEachUsageExportRow(ctx context.Context, query usagestats.UsageAggregateQuery, fn func(*usagestats.UsageExportRow) error) error
+	GetClientTypeUsage(ctx context.Context, startTime, endTime time.Time) ([]usagestats.ClientTypeUsageRow, error)
+	CountErrorRequests(ctx context.Context, startTime, endTime time.Time) (int64, error)
 	GetAPIKeyUsageTrend(ctx context.Context, startTime, endTime time.Time, granularity string, limit int) ([]usagestats.APIKeyUsageTrendPoint, error)
 	GetUserUsageTrend(ctx context.Context, startTime, endTime time.Time, granularity string, limit int) ([]usagestats.UserUsageTrendPoint, error)
 	GetBatchUserUsageStats(ctx context.Context, userIDs []int64, startTime, endTime time.Time) (map[int64]*usagestats.BatchUserUsageStats, error)
@@ -77,6 +83,10 @@ type accountWindowStatsBatchReader interface {
 	GetAccountWindowStatsBatch(ctx context.Context, accountIDs []int64, startTime time.Time) (map[int64]*usagestats.AccountStats, error)
 }
 
+type accountUsageBucketReader interface {
+	GetAccountUsageBuckets(ctx context.Context, accountID int64, startTime, endTime time.Time, granularity string) ([]usagestats.AccountUsageBucket, error)
+}
+
 // apiUsageCache 缓存从 Anthropic API 获取的使用率数据（utilization, resets_at）
 // 同时支持缓存错误响应（负缓存），防止 429 等错误导致的重试风暴
 type apiUsageCache struct {
@@ -98,12 +108,11 @@ type antigravityUsageCache struct {
 }
 
 const (
-	apiCacheTTL             = 3 * time.Minute
-	apiErrorCacheTTL        = 1 * time.Minute        // 负缓存 TTL：429 等错误缓存 1 分钟
-	apiQueryMaxJitter       = 800 * time.Millisecond // 用量查询最大随机延迟
-	windowStatsCacheTTL     = 1 * time.Minute
-	openAIProbeCacheTTL     = 10 * time.Minute
-	openAICodexProbeVersion = "0.104.0"
+	apiCacheTTL         = 3 * time.Minute
+	apiErrorCacheTTL    = 1 * time.Minute        // 负缓存 TTL：429 等错误缓存 1 分钟
+	apiQueryMaxJitter   = 800 * time.Millisecond // 用量查询最大随机延迟
+	windowStatsCacheTTL = 1 * time.Minute
+	openAIProbeCacheTTL = 10 * time.Minute
 )
 
 // UsageCache 封装账户使用量相关的缓存
@@ -564,8 +573,8 @@ func (s *AccountUsageService) probeOpenAICodexSnapshot(ctx context.Context, acco
 	req.Header.Set("Accept", "text/event-stream")
 	req.Header.Set("OpenAI-Beta", "responses=experimental")
 	req.Header.Set("Originator", "codex_cli_rs")
-	req.Header.Set("Version", openAICodexProbeVersion)
-	req.Header.Set("User-Agent", codexCLIUserAgent)
+	req.Header.Set("Version", currentCodexCLIVersion())
+	req.Header.Set("User-Agent", currentCodexCLIUserAgent())
 	if s.identityCache != nil {
 		if fp, fpErr := s.identityCache.GetFingerprint(reqCtx, account.ID); fpErr == nil && fp != nil && strings.TrimSpace(fp.UserAgent) != "" {
 			req.Header.Set("User-Agent", strings.TrimSpace(fp.UserAgent))
@@ -941,6 +950,61 @@ func (s *AccountUsageService) GetAccountUsageStats(ctx context.Context, accountI
 	return stats, nil
 }
 
+// GetAccountUsageBuckets 按小时（granularity=hour）或按天（granularity=day）返回账号的请求数、
+// token、错误率与最近使用时间，用于发现即将触达周期额度的账号
+func (s *AccountUsageService) GetAccountUsageBuckets(ctx context.Context, accountID int64, startTime, endTime time.Time, granularity string) (*usagestats.AccountUsageBucketsResponse, error) {
+	account, err := s.accountRepo.GetByID(ctx, accountID)
+	if err != nil {
+		return nil, fmt.Errorf("get account failed: %w", err)
+	}
+	reader, ok := s.usageLogRepo.(accountUsageBucketReader)
+	if !ok {
+		return nil, errors.New("account usage buckets are not supported by usage log repository")
+	}
+	buckets, err := reader.GetAccountUsageBuckets(ctx, accountID, startTime, endTime, granularity)
+	if err != nil {
+		return nil, fmt.Errorf("get account usage buckets failed: %w", err)
+	}
+
+	resp := &usagestats.AccountUsageBucketsResponse{
+		AccountID:   accountID,
+		Granularity: granularity,
+		StartTime:   startTime,
+		EndTime:     endTime,
+		Buckets:     buckets,
+		LastUsedAt:  account.LastUsedAt,
+	}
+	totals := &resp.Totals
+	for i := range buckets {
+		b := &buckets[i]
+		b.ErrorRate = accountUsageErrorRate(b.Requests, b.Errors)
+		totals.Requests += b.Requests
+		totals.Errors += b.Errors
+		totals.InputTokens += b.InputTokens
+		totals.OutputTokens += b.OutputTokens
+		totals.ReasoningTokens += b.ReasoningTokens
+		totals.CacheCreationTokens += b.CacheCreationTokens
+		totals.CacheReadTokens += b.CacheReadTokens
+		totals.Cost += b.Cost
+		if b.LastUsedAt != nil && (totals.LastUsedAt == nil || b.LastUsedAt.After(*totals.LastUsedAt)) {
+			totals.LastUsedAt = b.LastUsedAt
+		}
+	}
+	totals.ErrorRate = accountUsageErrorRate(totals.Requests, totals.Errors)
+	// 账号的 last_used_at 为批量延迟写入，窗口内的日志时间更新时以日志为准
+	if totals.LastUsedAt != nil && (resp.LastUsedAt == nil || totals.LastUsedAt.After(*resp.LastUsedAt)) {
+		resp.LastUsedAt = totals.LastUsedAt
+	}
+	return resp, nil
+}
+
+func accountUsageErrorRate(requests, errs int64) float64 {
+	if requests+errs <= 0 {
+		return 0
+	}
+	return float64(errs) / float64(requests+errs)
+}
+
 // fetchOAuthUsageRaw 从 Anthropic API 获取原始响应（不构建 UsageInfo）
 // 如果账号开启了 TLS 指纹，则使用 TLS 指纹伪装
 // 如果有缓存的 Fingerprint，则使用缓存的 User-Agent 等信息
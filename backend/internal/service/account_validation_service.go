@@ -0,0 +1,288 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	infraerrors "github.com/ShaohongDong/sub2api/internal/pkg/errors"
+	"github.com/ShaohongDong/sub2api/internal/pkg/pagination"
+)
+
+const (
+	// AccountValidationDefaultConcurrency 批量验证默认并发数
+	AccountValidationDefaultConcurrency = 5
+	// AccountValidationMaxConcurrency 批量验证并发上限，避免同时向上游发起过多测试请求
+	AccountValidationMaxConcurrency = 20
+	// AccountValidationMaxAccounts 单次批量验证的账号数上限
+	AccountValidationMaxAccounts = 1000
+	// accountValidationTimeout 单个账号测试 + 用量查询的超时
+	accountValidationTimeout = 90 * time.Second
+)
+
+var ErrAccountValidationTooMany = infraerrors.BadRequest(
+	"ACCOUNT_VALIDATION_TOO_MANY",
+	fmt.Sprintf("at most %d accounts can be validated at once", AccountValidationMaxAccounts),
+)
+
+// AccountValidationRequest 批量验证参数；AccountIDs 为空时验证全部账号（可按平台过滤）
+type AccountValidationRequest struct {
+	AccountIDs  []int64
+	Platform    string
+	Model       string
+	Concurrency int
+}
+
+// AccountUsageWindow 单个上游用量窗口的剩余额度
+type AccountUsageWindow struct {
+	Name             string     `json:"name"`
+	Utilization      float64    `json:"utilization"`
+	RemainingPercent float64    `json:"remaining_percent"`
+	ResetsAt         *time.Time `json:"resets_at,omitempty"`
+}
+
+// AccountHeadroom 账号限流余量：本地限流 / 过载 / 临时不可调度状态 + 上游用量窗口
+type AccountHeadroom struct {
+	RateLimited      bool                 `json:"rate_limited"`
+	RateLimitResetAt *time.Time           `json:"rate_limit_reset_at,omitempty"`
+	Overloaded       bool                 `json:"overloaded"`
+	TempUnschedUntil *time.Time           `json:"temp_unschedulable_until,omitempty"`
+	Windows          []AccountUsageWindow `json:"windows,omitempty"`
+	UsageError       string               `json:"usage_error,omitempty"`
+}
+
+// AccountValidationResult 单个账号的验证结果
+type AccountValidationResult struct {
+	AccountID int64            `json:"account_id"`
+	Name      string           `json:"name"`
+	Platform  string           `json:"platform"`
+	Type      string           `json:"type"`
+	AuthOK    bool             `json:"auth_ok"`
+	Error     string           `json:"error,omitempty"`
+	Plan      string           `json:"plan,omitempty"`
+	LatencyMs int64            `json:"latency_ms"`
+	Headroom  *AccountHeadroom `json:"headroom"`
+}
+
+// AccountValidationReport 批量验证汇总
+type AccountValidationReport struct {
+	Total      int                        `json:"total"`
+	Passed     int                        `json:"passed"`
+	Failed     int                        `json:"failed"`
+	DurationMs int64                      `json:"duration_ms"`
+	Results    []*AccountValidationResult `json:"results"`
+}
+
+// AccountValidationService 以有界并发批量测试账号连通性，并汇总套餐与限流余量
+type AccountValidationService struct {
+	accountRepo    AccountRepository
+	accountTestSvc *AccountTestService
+	usageSvc       *AccountUsageService
+
+	// test / usage 便于测试替换
+	test  func(ctx context.Context, accountID int64, model string) (*ScheduledTestResult, error)
+	usage func(ctx context.Context, accountID int64) (*UsageInfo, error)
+}
+
+// NewAccountValidationService 创建账号批量验证服务
+func NewAccountValidationService(accountRepo AccountRepository, accountTestSvc *AccountTestService, usageSvc *AccountUsageService) *AccountValidationService {
+	s := &AccountValidationService{
+		accountRepo:    accountRepo,
+		accountTestSvc: accountTestSvc,
+		usageSvc:       usageSvc,
+	}
+	s.test = accountTestSvc.RunTestBackground
+	s.usage = usageSvc.GetUsage
+	return s
+}
+
+// Validate 并发验证账号，结果按账号 ID 排序
+func (s *AccountValidationService) Validate(ctx context.Context, req AccountValidationRequest) (*AccountValidationReport, error) {
+	accounts, err := s.selectAccounts(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+
+	concurrency := req.Concurrency
+	if concurrency <= 0 {
+		concurrency = AccountValidationDefaultConcurrency
+	}
+	concurrency = min(concurrency, AccountValidationMaxConcurrency)
+
+	start := time.Now()
+	results := make([]*AccountValidationResult, len(accounts))
+	sem := make(chan struct{}, concurrency)
+	var wg sync.WaitGroup
+	for i := range accounts {
+		sem <- struct{}{}
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			defer func() { <-sem }()
+			results[i] = s.validateOne(ctx, &accounts[i], req.Model)
+		}(i)
+	}
+	wg.Wait()
+
+	report := &AccountValidationReport{
+		Total:      len(results),
+		DurationMs: time.Since(start).Milliseconds(),
+		Results:    results,
+	}
+	for _, r := range results {
+		if r.AuthOK {
+			report.Passed++
+		} else {
+			report.Failed++
+		}
+	}
+	sort.Slice(results, func(i, j int) bool { return results[i].AccountID < results[j].AccountID })
+	return report, nil
+}
+
+func (s *AccountValidationService) selectAccounts(ctx context.Context, req AccountValidationRequest) ([]Account, error) {
+	if len(req.AccountIDs) > 0 {
+		if len(req.AccountIDs) > AccountValidationMaxAccounts {
+			return nil, ErrAccountValidationTooMany
+		}
+		fetched, err := s.accountRepo.GetByIDs(ctx, req.AccountIDs)
+		if err != nil {
+			return nil, err
+		}
+		accounts := make([]Account, 0, len(fetched))
+		for _, acc := range fetched {
+			if acc != nil {
+				accounts = append(accounts, *acc)
+			}
+		}
+		return accounts, nil
+	}
+
+	accounts, page, err := s.accountRepo.ListWithFilters(ctx, pagination.PaginationParams{Page: 1, PageSize: AccountValidationMaxAccounts}, req.Platform, "", "", "", 0)
+	if err != nil {
+		return nil, err
+	}
+	if page != nil && page.Total > AccountValidationMaxAccounts {
+		return nil, ErrAccountValidationTooMany
+	}
+	return accounts, nil
+}
+
+func (s *AccountValidationService) validateOne(parent context.Context, account *Account, model string) *AccountValidationResult {
+	ctx, cancel := context.WithTimeout(parent, accountValidationTimeout)
+	defer cancel()
+
+	result := &AccountValidationResult{
+		AccountID: account.ID,
+		Name:      account.Name,
+		Platform:  account.Platform,
+		Type:      account.Type,
+		Plan:      accountPlan(account),
+		Headroom:  accountLocalHeadroom(account, time.Now()),
+	}
+
+	started := time.Now()
+	testResult, err := s.test(ctx, account.ID, model)
+	switch {
+	case err != nil:
+		result.Error = err.Error()
+		result.LatencyMs = time.Since(started).Milliseconds()
+	case testResult != nil:
+		result.AuthOK = testResult.Status == "success"
+		result.Error = testResult.ErrorMessage
+		result.LatencyMs = testResult.LatencyMs
+	}
+
+	if account.CanGetUsage() {
+		usage, err := s.usage(ctx, account.ID)
+		if err != nil {
+			result.Headroom.UsageError = err.Error()
+		} else {
+			result.Headroom.Windows = usageWindows(usage)
+		}
+	}
+	return result
+}
+
+// accountPlan 从凭证中读取套餐信息（OpenAI plan_type / Gemini tier_id / 订阅类型）
+func accountPlan(account *Account) string {
+	for _, key := range []string{"plan_type", "tier_id", "subscription_type"} {
+		if v := strings.TrimSpace(account.GetCredential(key)); v != "" {
+			return v
+		}
+	}
+	if account.Platform == PlatformGemini && account.Type == AccountTypeOAuth {
+		return account.GeminiOAuthType()
+	}
+	return ""
+}
+
+func accountLocalHeadroom(account *Account, now time.Time) *AccountHeadroom {
+	h := &AccountHeadroom{}
+	if account.RateLimitResetAt != nil && now.Before(*account.RateLimitResetAt) {
+		h.RateLimited = true
+		h.RateLimitResetAt = account.RateLimitResetAt
+	}
+	if account.OverloadUntil != nil && now.Before(*account.OverloadUntil) {
+		h.Overloaded = true
+	}
+	if account.TempUnschedulableUntil != nil && now.Before(*account.TempUnschedulableUntil) {
+		h.TempUnschedUntil = account.TempUnschedulableUntil
+	}
+	return h
+}
+
+// usageWindows 将上游用量转换为统一的窗口余量列表
+func usageWindows(usage *UsageInfo) []AccountUsageWindow {
+	if usage == nil {
+		return nil
+	}
+	named := []struct {
+		name string
+		p    *UsageProgress
+	}{
+		{"five_hour", usage.FiveHour},
+		{"seven_day", usage.SevenDay},
+		{"seven_day_sonnet", usage.SevenDaySonnet},
+		{"gemini_shared_daily", usage.GeminiSharedDaily},
+		{"gemini_pro_daily", usage.GeminiProDaily},
+		{"gemini_flash_daily", usage.GeminiFlashDaily},
+	}
+	var windows []AccountUsageWindow
+	for _, w := range named {
+		if w.p == nil {
+			continue
+		}
+		windows = append(windows, AccountUsageWindow{
+			Name:             w.name,
+			Utilization:      w.p.Utilization,
+			RemainingPercent: math.Max(0, 100-w.p.Utilization),
+			ResetsAt:         w.p.ResetsAt,
+		})
+	}
+	models := make([]string, 0, len(usage.AntigravityQuota))
+	for model := range usage.AntigravityQuota {
+		models = append(models, model)
+	}
+	sort.Strings(models)
+	for _, model := range models {
+		q := usage.AntigravityQuota[model]
+		if q == nil {
+			continue
+		}
+		w := AccountUsageWindow{
+			Name:             "antigravity:" + model,
+			Utilization:      float64(q.Utilization),
+			RemainingPercent: math.Max(0, float64(100-q.Utilization)),
+		}
+		if t, err := time.Parse(time.RFC3339, q.ResetTime); err == nil {
+			w.ResetsAt = &t
+		}
+		windows = append(windows, w)
+	}
+	return windows
+}
@@ -0,0 +1,111 @@
+//go:build unit
+
+package service
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/ShaohongDong/sub2api/internal/pkg/pagination"
+	"github.com/stretchr/testify/require"
+)
+
+type accountValidationRepoStub struct {
+	mockAccountRepoForGemini
+	all   []Account
+	total int64
+}
+
+func (r *accountValidationRepoStub) ListWithFilters(_ context.Context, params pagination.PaginationParams, platform, _, _, _ string, _ int64) ([]Account, *pagination.PaginationResult, error) {
+	var out []Account
+	for _, a := range r.all {
+		if platform == "" || a.Platform == platform {
+			out = append(out, a)
+		}
+	}
+	total := r.total
+	if total == 0 {
+		total = int64(len(out))
+	}
+	return out, &pagination.PaginationResult{Total: total, Page: params.Page, PageSize: params.PageSize}, nil
+}
+
+func TestAccountValidation_ReportsPerAccountResults(t *testing.T) {
+	resetAt := time.Now().Add(time.Hour)
+	repo := &accountValidationRepoStub{}
+	repo.accountsByID = map[int64]*Account{
+		1: {ID: 1, Name: "codex", Platform: PlatformOpenAI, Type: AccountTypeOAuth, Credentials: map[string]any{"plan_type": "plus"}},
+		2: {ID: 2, Name: "key", Platform: PlatformAnthropic, Type: AccountTypeAPIKey, RateLimitResetAt: &resetAt},
+	}
+	svc := NewAccountValidationService(repo, nil, nil)
+	svc.test = func(_ context.Context, accountID int64, _ string) (*ScheduledTestResult, error) {
+		if accountID == 2 {
+			return &ScheduledTestResult{Status: "failed", ErrorMessage: "API returned 401", LatencyMs: 12}, nil
+		}
+		return &ScheduledTestResult{Status: "success", LatencyMs: 34}, nil
+	}
+	svc.usage = func(_ context.Context, accountID int64) (*UsageInfo, error) {
+		require.Equal(t, int64(1), accountID, "usage only fetched for OAuth accounts")
+		return &UsageInfo{FiveHour: &UsageProgress{Utilization: 30}, SevenDay: &UsageProgress{Utilization: 120}}, nil
+	}
+
+	report, err := svc.Validate(context.Background(), AccountValidationRequest{AccountIDs: []int64{2, 1}})
+	require.NoError(t, err)
+	require.Equal(t, 2, report.Total)
+	require.Equal(t, 1, report.Passed)
+	require.Equal(t, 1, report.Failed)
+
+	ok := report.Results[0]
+	require.Equal(t, int64(1), ok.AccountID)
+	require.True(t, ok.AuthOK)
+	require.Equal(t, "plus", ok.Plan)
+	require.Equal(t, int64(34), ok.LatencyMs)
+	require.Equal(t, []AccountUsageWindow{
+		{Name: "five_hour", Utilization: 30, RemainingPercent: 70},
+		{Name: "seven_day", Utilization: 120, RemainingPercent: 0},
+	}, ok.Headroom.Windows)
+
+	failed := report.Results[1]
+	require.False(t, failed.AuthOK)
+	require.Equal(t, "API returned 401", failed.Error)
+	require.True(t, failed.Headroom.RateLimited)
+	require.Empty(t, failed.Headroom.Windows)
+}
+
+func TestAccountValidation_BoundedConcurrency(t *testing.T) {
+	repo := &accountValidationRepoStub{}
+	for i := 1; i <= 12; i++ {
+		repo.all = append(repo.all, Account{ID: int64(i), Platform: PlatformAnthropic, Type: AccountTypeAPIKey})
+	}
+	svc := NewAccountValidationService(repo, nil, nil)
+	var running, peak atomic.Int32
+	svc.test = func(_ context.Context, _ int64, _ string) (*ScheduledTestResult, error) {
+		n := running.Add(1)
+		for {
+			p := peak.Load()
+			if n <= p || peak.CompareAndSwap(p, n) {
+				break
+			}
+		}
+		time.Sleep(10 * time.Millisecond)
+		running.Add(-1)
+		return nil, errors.New("boom")
+	}
+
+	report, err := svc.Validate(context.Background(), AccountValidationRequest{Concurrency: 3})
+	require.NoError(t, err)
+	require.Equal(t, 12, report.Failed)
+	require.LessOrEqual(t, peak.Load(), int32(3))
+	require.Equal(t, "boom", report.Results[0].Error)
+}
+
+func TestAccountValidation_RejectsTooManyAccounts(t *testing.T) {
+	repo := &accountValidationRepoStub{total: AccountValidationMaxAccounts + 1}
+	svc := NewAccountValidationService(repo, nil, nil)
+
+	_, err := svc.Validate(context.Background(), AccountValidationRequest{})
+	require.ErrorIs(t, err, ErrAccountValidationTooMany)
+}
@@ -0,0 +1,205 @@
+package service
+
+import (
+	"context"
+	"log/slog"
+	"regexp"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/ShaohongDong/sub2api/internal/config"
+)
+
+// accountWarmupStatusPattern 从测试错误信息中提取上游状态码（如 "API returned 401: ..."）
+var accountWarmupStatusPattern = regexp.MustCompile(`(?:returned|HTTP) (\d{3})\b`)
+
+// AccountWarmupService 定时向空闲账号发送极小的测试请求：保持会话活跃，
+// 并在真实流量到来前发现被静默吊销的凭证（401/403 直接交给健康检查移出调度并探测恢复）。
+type AccountWarmupService struct {
+	accountRepo    AccountRepository
+	accountTestSvc *AccountTestService
+	healthSvc      *AccountHealthService
+	cfg            config.AccountWarmupConfig
+	leader         *LeaderElectionService
+
+	mu         sync.Mutex
+	lastWarmup map[int64]time.Time
+
+	// ping 预热单个账号，返回失败信息（成功时为空），便于测试替换
+	ping func(ctx context.Context, accountID int64) string
+
+	stopCh   chan struct{}
+	stopOnce sync.Once
+	wg       sync.WaitGroup
+}
+
+// NewAccountWarmupService 创建空闲账号预热服务
+func NewAccountWarmupService(
+	accountRepo AccountRepository,
+	accountTestSvc *AccountTestService,
+	healthSvc *AccountHealthService,
+	cfg *config.Config,
+) *AccountWarmupService {
+	s := &AccountWarmupService{
+		accountRepo:    accountRepo,
+		accountTestSvc: accountTestSvc,
+		healthSvc:      healthSvc,
+		lastWarmup:     make(map[int64]time.Time),
+		stopCh:         make(chan struct{}),
+	}
+	if cfg != nil {
+		s.cfg = cfg.AccountWarmup
+	}
+	s.ping = s.pingWithAccountTest
+	return s
+}
+
+// SetLeaderElection 设置集群领导者选举（可选依赖）；启用集群模式时只有领导者执行预热
+func (s *AccountWarmupService) SetLeaderElection(leader *LeaderElectionService) {
+	s.leader = leader
+}
+
+// Start 启动预热扫描循环
+func (s *AccountWarmupService) Start() {
+	if s == nil || !s.cfg.Enabled {
+		return
+	}
+	interval := time.Duration(s.cfg.CheckIntervalMinutes) * time.Minute
+	if interval <= 0 {
+		interval = 10 * time.Minute
+	}
+	s.wg.Add(1)
+	go func() {
+		defer s.wg.Done()
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				s.runOnce(time.Now())
+			case <-s.stopCh:
+				return
+			}
+		}
+	}()
+	slog.Info("account_warmup.service_started",
+		"interval_hours", s.cfg.IntervalHours,
+		"check_interval_minutes", int(interval/time.Minute),
+	)
+}
+
+// Stop 停止预热扫描循环
+func (s *AccountWarmupService) Stop() {
+	if s == nil {
+		return
+	}
+	s.stopOnce.Do(func() {
+		close(s.stopCh)
+	})
+	s.wg.Wait()
+}
+
+func (s *AccountWarmupService) runOnce(now time.Time) {
+	if !s.leader.IsLeader() {
+		return
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Minute)
+	defer cancel()
+
+	accounts, err := s.accountRepo.ListSchedulable(ctx)
+	if err != nil {
+		slog.Warn("account_warmup.list_failed", "error", err)
+		return
+	}
+	due := s.dueAccounts(accounts, now)
+	if len(due) == 0 {
+		return
+	}
+
+	workers := s.cfg.MaxConcurrency
+	if workers <= 0 {
+		workers = 1
+	}
+	sem := make(chan struct{}, workers)
+	var wg sync.WaitGroup
+	for i := range due {
+		sem <- struct{}{}
+		wg.Add(1)
+		go func(account *Account) {
+			defer wg.Done()
+			defer func() { <-sem }()
+			s.warmup(ctx, account)
+		}(due[i])
+	}
+	wg.Wait()
+}
+
+// dueAccounts 挑选需要预热的账号：超过 interval_hours 未被使用且未在该间隔内预热过。
+// 选中的账号立即记为已预热，避免慢请求在下一轮扫描中被重复选中。
+func (s *AccountWarmupService) dueAccounts(accounts []Account, now time.Time) []*Account {
+	interval := time.Duration(s.cfg.IntervalHours) * time.Hour
+	if interval <= 0 {
+		interval = 6 * time.Hour
+	}
+	idleSince := now.Add(-interval)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	due := make([]*Account, 0)
+	for i := range accounts {
+		account := &accounts[i]
+		if account.Type == AccountTypeAPIKey && !s.cfg.IncludeAPIKey {
+			continue
+		}
+		if account.LastUsedAt != nil && account.LastUsedAt.After(idleSince) {
+			continue
+		}
+		if last, ok := s.lastWarmup[account.ID]; ok && last.After(idleSince) {
+			continue
+		}
+		s.lastWarmup[account.ID] = now
+		due = append(due, account)
+	}
+	return due
+}
+
+func (s *AccountWarmupService) warmup(ctx context.Context, account *Account) {
+	failure := s.ping(ctx, account.ID)
+	if failure == "" {
+		slog.Debug("account_warmup.ok", "account_id", account.ID)
+		return
+	}
+
+	statusCode := 0
+	if m := accountWarmupStatusPattern.FindStringSubmatch(failure); m != nil {
+		statusCode, _ = strconv.Atoi(m[1])
+	}
+	slog.Warn("account_warmup.failed", "account_id", account.ID, "status_code", statusCode, "error", failure)
+
+	switch {
+	case statusCode == 401 || statusCode == 403:
+		// 凭证已失效：不等失败阈值，直接移出调度并进入健康探测
+		s.healthSvc.MarkUnhealthy(ctx, account, statusCode, failure)
+	case statusCode >= 500:
+		s.healthSvc.RecordFailure(ctx, account, statusCode, failure, false)
+	}
+}
+
+// pingWithAccountTest 复用账号测试连接发送预热请求
+func (s *AccountWarmupService) pingWithAccountTest(ctx context.Context, accountID int64) string {
+	if s.accountTestSvc == nil {
+		return "account test service unavailable"
+	}
+	result, err := s.accountTestSvc.RunTestBackground(ctx, accountID, s.cfg.Model)
+	if err != nil {
+		return err.Error()
+	}
+	if result.Status == "success" {
+		return ""
+	}
+	if result.ErrorMessage == "" {
+		return "warmup request failed"
+	}
+	return result.ErrorMessage
+}
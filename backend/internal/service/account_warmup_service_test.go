@@ -0,0 +1,82 @@
+//go:build unit
+
+package service
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ShaohongDong/sub2api/internal/config"
+	"github.com/stretchr/testify/require"
+)
+
+type accountWarmupRepoStub struct {
+	accountHealthRepoStub
+	schedulable []Account
+}
+
+func (r *accountWarmupRepoStub) ListSchedulable(ctx context.Context) ([]Account, error) {
+	return r.schedulable, nil
+}
+
+func TestAccountWarmup_PingsIdleAccountsOnce(t *testing.T) {
+	now := time.Now()
+	recent := now.Add(-time.Hour)
+	stale := now.Add(-7 * time.Hour)
+	repo := &accountWarmupRepoStub{schedulable: []Account{
+		{ID: 1, Type: AccountTypeOAuth, LastUsedAt: &stale},
+		{ID: 2, Type: AccountTypeOAuth, LastUsedAt: &recent},
+		{ID: 3, Type: AccountTypeOAuth},
+		{ID: 4, Type: AccountTypeAPIKey},
+	}}
+	cfg := &config.Config{AccountWarmup: config.AccountWarmupConfig{Enabled: true, IntervalHours: 6, MaxConcurrency: 2}}
+	svc := NewAccountWarmupService(repo, nil, newAccountHealthTestService(&repo.accountHealthRepoStub), cfg)
+
+	var mu sync.Mutex
+	var pinged []int64
+	svc.ping = func(ctx context.Context, accountID int64) string {
+		mu.Lock()
+		defer mu.Unlock()
+		pinged = append(pinged, accountID)
+		return ""
+	}
+
+	svc.runOnce(now)
+	require.ElementsMatch(t, []int64{1, 3}, pinged)
+
+	// 间隔内不重复预热
+	svc.runOnce(now.Add(time.Hour))
+	require.Len(t, pinged, 2)
+
+	// 间隔过后账号 2 也已空闲
+	svc.runOnce(now.Add(7 * time.Hour))
+	require.ElementsMatch(t, []int64{1, 3, 1, 2, 3}, pinged)
+}
+
+func TestAccountWarmup_RevokedCredentialsMarkedUnhealthy(t *testing.T) {
+	repo := &accountWarmupRepoStub{schedulable: []Account{
+		{ID: 7, Type: AccountTypeOAuth},
+		{ID: 8, Type: AccountTypeOAuth},
+	}}
+	cfg := &config.Config{AccountWarmup: config.AccountWarmupConfig{Enabled: true, IntervalHours: 6, MaxConcurrency: 1}}
+	health := newAccountHealthTestService(&repo.accountHealthRepoStub)
+	svc := NewAccountWarmupService(repo, nil, health, cfg)
+	svc.ping = func(ctx context.Context, accountID int64) string {
+		if accountID == 7 {
+			return `API returned 401: {"error":{"message":"token revoked"}}`
+		}
+		return "Proxy connection failed: proxyconnect tcp: EOF"
+	}
+
+	svc.runOnce(time.Now())
+
+	state := health.GetState(7)
+	require.NotNil(t, state)
+	require.False(t, state.Healthy)
+	require.Equal(t, 401, state.LastStatusCode)
+	require.Contains(t, repo.tempUnschedUntil, int64(7))
+
+	require.Nil(t, health.GetState(8))
+}
@@ -0,0 +1,215 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"reflect"
+	"strings"
+	"time"
+
+	infraerrors "github.com/ShaohongDong/sub2api/internal/pkg/errors"
+	"github.com/ShaohongDong/sub2api/internal/pkg/logger"
+	"go.uber.org/zap"
+)
+
+const (
+	// adminAuditWriteTimeout 审计写入超时；与请求 context 解耦，客户端断开也要落库
+	adminAuditWriteTimeout = 5 * time.Second
+	// AdminAuditMaxExportRows 单次导出的最大行数
+	AdminAuditMaxExportRows = 10000
+)
+
+var ErrAdminAuditInvalidTimeRange = infraerrors.BadRequest("ADMIN_AUDIT_INVALID_TIME_RANGE", "start_time must be before end_time")
+
+// AdminAuditChange 单个字段的变更前后值（敏感字段以 *** 代替）
+type AdminAuditChange struct {
+	Before any `json:"before"`
+	After  any `json:"after"`
+}
+
+// AdminAuditLog 管理后台写操作审计记录
+type AdminAuditLog struct {
+	ID          int64  `json:"id"`
+	ActorUserID int64  `json:"actor_user_id"`
+	ActorEmail  string `json:"actor_email,omitempty"`
+	ActorRole   string `json:"actor_role"`
+	// AuthMethod jwt / admin_api_key
+	AuthMethod string `json:"auth_method"`
+	Method     string `json:"method"`
+	// Route 路由模板（如 /api/v1/admin/accounts/:id），Path 为实际请求路径
+	Route        string `json:"route"`
+	Path         string `json:"path"`
+	ResourceType string `json:"resource_type"`
+	ResourceID   string `json:"resource_id,omitempty"`
+	StatusCode   int    `json:"status_code"`
+	ClientIP     string `json:"client_ip"`
+	RequestID    string `json:"request_id,omitempty"`
+	// RequestBody 脱敏并截断后的请求体
+	RequestBody string                      `json:"request_body,omitempty"`
+	Changes     map[string]AdminAuditChange `json:"changes,omitempty"`
+	CreatedAt   time.Time                   `json:"created_at"`
+}
+
+// AdminAuditFilter 审计日志查询条件，零值字段不参与过滤
+type AdminAuditFilter struct {
+	StartTime    *time.Time
+	EndTime      *time.Time
+	ActorUserID  int64
+	Method       string
+	ResourceType string
+	ResourceID   string
+	Page         int
+	PageSize     int
+}
+
+// AdminAuditLogList 审计日志分页结果
+type AdminAuditLogList struct {
+	Items    []*AdminAuditLog `json:"items"`
+	Total    int64            `json:"total"`
+	Page     int              `json:"page"`
+	PageSize int              `json:"page_size"`
+}
+
+// AdminAuditRepository 审计日志存储
+type AdminAuditRepository interface {
+	Insert(ctx context.Context, entry *AdminAuditLog) error
+	List(ctx context.Context, filter *AdminAuditFilter) (*AdminAuditLogList, error)
+}
+
+// AdminAuditService 记录并查询管理后台写操作
+type AdminAuditService struct {
+	repo AdminAuditRepository
+}
+
+// NewAdminAuditService 创建审计服务
+func NewAdminAuditService(repo AdminAuditRepository) *AdminAuditService {
+	return &AdminAuditService{repo: repo}
+}
+
+// Record 写入一条审计记录；失败只记录日志，不影响已完成的管理操作
+func (s *AdminAuditService) Record(ctx context.Context, entry *AdminAuditLog) {
+	if s == nil || s.repo == nil || entry == nil {
+		return
+	}
+	writeCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), adminAuditWriteTimeout)
+	defer cancel()
+	if err := s.repo.Insert(writeCtx, entry); err != nil {
+		logger.FromContext(ctx).Error("admin_audit.insert_failed",
+			zap.Error(err),
+			zap.Int64("actor_user_id", entry.ActorUserID),
+			zap.String("method", entry.Method),
+			zap.String("path", entry.Path),
+		)
+	}
+}
+
+// List 分页查询审计日志（按时间倒序）
+func (s *AdminAuditService) List(ctx context.Context, filter *AdminAuditFilter) (*AdminAuditLogList, error) {
+	if filter.StartTime != nil && filter.EndTime != nil && !filter.StartTime.Before(*filter.EndTime) {
+		return nil, ErrAdminAuditInvalidTimeRange
+	}
+	filter.Method = strings.ToUpper(strings.TrimSpace(filter.Method))
+	filter.ResourceType = strings.TrimSpace(filter.ResourceType)
+	filter.ResourceID = strings.TrimSpace(filter.ResourceID)
+	return s.repo.List(ctx, filter)
+}
+
+// AdminAuditResourceType 从路由模板推导资源类型：/api/v1/admin/accounts/:id -> accounts
+func AdminAuditResourceType(route string) string {
+	_, rest, ok := strings.Cut(route, "/admin/")
+	if !ok {
+		return ""
+	}
+	resource, _, _ := strings.Cut(rest, "/")
+	return resource
+}
+
+// DiffAdminAuditSnapshots 按顶层 JSON 字段比较变更前后的快照，返回有变化的字段。
+// before 为 nil 表示新建，after 为 nil 表示删除；敏感字段只记录发生了变化，不记录取值。
+func DiffAdminAuditSnapshots(before, after any) map[string]AdminAuditChange {
+	beforeFields, afterFields := adminAuditFields(before), adminAuditFields(after)
+	changes := make(map[string]AdminAuditChange)
+	for key, b := range beforeFields {
+		a, ok := afterFields[key]
+		if ok && reflect.DeepEqual(a, b) {
+			continue
+		}
+		changes[key] = adminAuditChange(key, b, a)
+	}
+	for key, a := range afterFields {
+		if _, ok := beforeFields[key]; !ok {
+			changes[key] = adminAuditChange(key, nil, a)
+		}
+	}
+	return changes
+}
+
+func adminAuditChange(key string, before, after any) AdminAuditChange {
+	if isAdminAuditSensitiveKey(key) {
+		if before != nil {
+			before = "***"
+		}
+		if after != nil {
+			after = "***"
+		}
+	}
+	return AdminAuditChange{Before: before, After: after}
+}
+
+func adminAuditFields(snapshot any) map[string]any {
+	if snapshot == nil {
+		return nil
+	}
+	raw, err := json.Marshal(snapshot)
+	if err != nil {
+		return nil
+	}
+	var fields map[string]any
+	if err := json.Unmarshal(raw, &fields); err != nil {
+		// 非对象快照（如单个值）整体作为 value 字段比较
+		var value any
+		if json.Unmarshal(raw, &value) != nil {
+			return nil
+		}
+		return map[string]any{"value": value}
+	}
+	return fields
+}
+
+// isAdminAuditSensitiveKey 判断字段是否为凭证类字段；兼容 snake_case 与 CamelCase，*_configured 标记位除外
+func isAdminAuditSensitiveKey(key string) bool {
+	k := strings.ToLower(strings.ReplaceAll(key, "_", ""))
+	if strings.HasSuffix(k, "configured") {
+		return false
+	}
+	for _, part := range []string{"password", "secret", "credential", "privatekey", "cookie"} {
+		if strings.Contains(k, part) {
+			return true
+		}
+	}
+	return k == "key" || strings.HasSuffix(k, "token") || strings.HasSuffix(k, "apikey") || strings.HasSuffix(k, "sessionkey")
+}
+
+// RedactAdminAuditValue 对请求体等 JSON 值中的敏感字段递归脱敏
+func RedactAdminAuditValue(value any) any {
+	switch v := value.(type) {
+	case map[string]any:
+		out := make(map[string]any, len(v))
+		for key, item := range v {
+			if isAdminAuditSensitiveKey(key) {
+				out[key] = "***"
+				continue
+			}
+			out[key] = RedactAdminAuditValue(item)
+		}
+		return out
+	case []any:
+		out := make([]any, len(v))
+		for i, item := range v {
+			out[i] = RedactAdminAuditValue(item)
+		}
+		return out
+	default:
+		return value
+	}
+}
@@ -0,0 +1,63 @@
+//go:build unit
+
+package service
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestDiffAdminAuditSnapshots(t *testing.T) {
+	type snapshot struct {
+		ID          int64             `json:"id"`
+		Name        string            `json:"name"`
+		Credentials map[string]string `json:"credentials"`
+		Key         string            `json:"key"`
+		MaxTokens   int               `json:"max_tokens"`
+		Configured  bool              `json:"smtp_password_configured"`
+	}
+	before := snapshot{ID: 1, Name: "old", Credentials: map[string]string{"api_key": "sk-1"}, Key: "k1", MaxTokens: 10}
+	after := snapshot{ID: 1, Name: "new", Credentials: map[string]string{"api_key": "sk-2"}, Key: "k1", MaxTokens: 20, Configured: true}
+
+	changes := DiffAdminAuditSnapshots(before, after)
+	require.Equal(t, map[string]AdminAuditChange{
+		"name":                     {Before: "old", After: "new"},
+		"credentials":              {Before: "***", After: "***"},
+		"max_tokens":               {Before: float64(10), After: float64(20)},
+		"smtp_password_configured": {Before: false, After: true},
+	}, changes)
+
+	created := DiffAdminAuditSnapshots(nil, snapshot{ID: 2, Name: "x"})
+	require.Equal(t, AdminAuditChange{Before: nil, After: float64(2)}, created["id"])
+	require.Equal(t, AdminAuditChange{Before: nil, After: "***"}, created["key"])
+
+	deleted := DiffAdminAuditSnapshots(snapshot{ID: 3}, nil)
+	require.Equal(t, AdminAuditChange{Before: float64(3), After: nil}, deleted["id"])
+}
+
+func TestAdminAuditSensitiveKeys(t *testing.T) {
+	for _, key := range []string{"password", "SMTPPassword", "TurnstileSecretKey", "access_token", "AccessToken", "key", "api_key", "session_key", "credentials"} {
+		require.True(t, isAdminAuditSensitiveKey(key), key)
+	}
+	for _, key := range []string{"name", "max_tokens", "InputTokens", "key_id", "smtp_password_configured", "turnstile_site_key_id"} {
+		require.False(t, isAdminAuditSensitiveKey(key), key)
+	}
+
+	redacted := RedactAdminAuditValue(map[string]any{
+		"name":        "a",
+		"credentials": map[string]any{"api_key": "sk"},
+		"items":       []any{map[string]any{"password": "p", "id": float64(1)}},
+	})
+	require.Equal(t, map[string]any{
+		"name":        "a",
+		"credentials": "***",
+		"items":       []any{map[string]any{"password": "***", "id": float64(1)}},
+	}, redacted)
+}
+
+func TestAdminAuditResourceType(t *testing.T) {
+	require.Equal(t, "accounts", AdminAuditResourceType("/api/v1/admin/accounts/:id"))
+	require.Equal(t, "settings", AdminAuditResourceType("/api/v1/admin/settings"))
+	require.Equal(t, "", AdminAuditResourceType("/api/v1/keys"))
+}
@@ -6,6 +6,8 @@ import (
 	"fmt"
 	"io"
 	"net/http"
+	"slices"
+	"strconv"
 	"strings"
 	"time"
 
@@ -58,6 +60,8 @@ type AdminService interface {
 	ClearAccountError(ctx context.Context, id int64) (*Account, error)
 	SetAccountError(ctx context.Context, id int64, errorMsg string) error
 	SetAccountSchedulable(ctx context.Context, id int64, schedulable bool) (*Account, error)
+	ArchiveAccount(ctx context.Context, id int64) (*Account, error)
+	RestoreAccount(ctx context.Context, id int64) (*Account, error)
 	BulkUpdateAccounts(ctx context.Context, input *BulkUpdateAccountsInput) (*BulkUpdateAccountsResult, error)
 	CheckMixedChannelRisk(ctx context.Context, currentAccountID int64, currentAccountPlatform string, groupIDs []int64) error
 
@@ -112,6 +116,8 @@ type UpdateUserInput struct {
 	// map[groupID]*rate，nil 表示删除该分组的专属倍率
 	GroupRates            map[int64]*float64
 	SoraStorageQuotaBytes *int64
+	// Role 可选：user / viewer / operator（管理员角色不可通过此处授予或撤销）
+	Role *string
 }
 
 type CreateGroupInput struct {
@@ -148,6 +154,12 @@ type CreateGroupInput struct {
 	// OpenAI Messages 调度配置（仅 openai 平台使用）
 	AllowMessagesDispatch bool
 	DefaultMappedModel    string
+	// 模型别名（覆盖全局别名表）
+	ModelAliases map[string]string
+	// 跨平台降级链
+	FallbackChain []int64
+	// 账号调度策略（仅 openai 平台使用）
+	SchedulingStrategy string
 	// 从指定分组复制账号（创建分组后在同一事务内绑定）
 	CopyAccountsFromGroupIDs []int64
 }
@@ -187,6 +199,12 @@ type UpdateGroupInput struct {
 	// OpenAI Messages 调度配置（仅 openai 平台使用）
 	AllowMessagesDispatch *bool
 	DefaultMappedModel    *string
+	// 模型别名（nil 表示不修改，空表表示清除）
+	ModelAliases map[string]string
+	// 跨平台降级链（nil 表示不修改，空数组表示清除）
+	FallbackChain []int64
+	// 账号调度策略（仅 openai 平台使用）
+	SchedulingStrategy *string
 	// 从指定分组复制账号（同步操作：先清空当前分组的账号绑定，再绑定源分组的账号）
 	CopyAccountsFromGroupIDs []int64
 }
@@ -243,13 +261,22 @@ type BulkUpdateAccountsInput struct {
 	Status         string
 	Schedulable    *bool
 	GroupIDs       *[]int64
-	Credentials    map[string]any
-	Extra          map[string]any
+	// GroupIDsMode 分组绑定方式：replace（默认，覆盖原有分组）/ add（追加，批量打标签）/ remove（移除）
+	GroupIDsMode string
+	Credentials  map[string]any
+	Extra        map[string]any
 	// SkipMixedChannelCheck skips the mixed channel risk check when binding groups.
 	// This should only be set when the caller has explicitly confirmed the risk.
 	SkipMixedChannelCheck bool
 }
 
+// 批量更新账号时的分组绑定方式
+const (
+	BulkGroupModeReplace = "replace"
+	BulkGroupModeAdd     = "add"
+	BulkGroupModeRemove  = "remove"
+)
+
 // BulkUpdateAccountResult captures the result for a single account update.
 type BulkUpdateAccountResult struct {
 	AccountID int64  `json:"account_id"`
@@ -619,6 +646,16 @@ func (s *adminServiceImpl) UpdateUser(ctx context.Context, id int64, input *Upda
 		user.Status = input.Status
 	}
 
+	if input.Role != nil && *input.Role != user.Role {
+		if user.Role == RoleAdmin {
+			return nil, ErrAdminRoleImmutable
+		}
+		if !IsAssignableRole(*input.Role) {
+			return nil, ErrInvalidUserRole
+		}
+		user.Role = *input.Role
+	}
+
 	if input.Concurrency != nil {
 		user.Concurrency = *input.Concurrency
 	}
@@ -823,6 +860,13 @@ func (s *adminServiceImpl) CreateGroup(ctx context.Context, input *CreateGroupIn
 	if subscriptionType == "" {
 		subscriptionType = SubscriptionTypeStandard
 	}
+	if !IsValidSchedulingStrategy(input.SchedulingStrategy) {
+		return nil, ErrInvalidSchedulingStrategy
+	}
+	modelAliases, err := normalizeModelAliases(input.ModelAliases)
+	if err != nil {
+		return nil, err
+	}
 
 	// 限额字段：0 和 nil 都表示"无限制"
 	dailyLimit := normalizeLimit(input.DailyLimitUSD)
@@ -843,6 +887,9 @@ func (s *adminServiceImpl) CreateGroup(ctx context.Context, input *CreateGroupIn
 		if err := s.validateFallbackGroup(ctx, 0, *input.FallbackGroupID); err != nil {
 			return nil, err
 		}
+		if err := s.validateFallbackGroupTenant(ctx, nil, *input.FallbackGroupID); err != nil {
+			return nil, err
+		}
 	}
 	fallbackOnInvalidRequest := input.FallbackGroupIDOnInvalidRequest
 	if fallbackOnInvalidRequest != nil && *fallbackOnInvalidRequest <= 0 {
@@ -853,6 +900,13 @@ func (s *adminServiceImpl) CreateGroup(ctx context.Context, input *CreateGroupIn
 		if err := s.validateFallbackGroupOnInvalidRequest(ctx, 0, platform, subscriptionType, *fallbackOnInvalidRequest); err != nil {
 			return nil, err
 		}
+		if err := s.validateFallbackGroupTenant(ctx, nil, *fallbackOnInvalidRequest); err != nil {
+			return nil, err
+		}
+	}
+	fallbackChain, err := s.validateFallbackChain(ctx, 0, nil, subscriptionType, input.FallbackChain)
+	if err != nil {
+		return nil, err
 	}
 
 	// MCPXMLInject：默认为 true，仅当显式传入 false 时关闭
@@ -883,6 +937,10 @@ func (s *adminServiceImpl) CreateGroup(ctx context.Context, input *CreateGroupIn
 			if srcGroup.Platform != platform {
 				return nil, fmt.Errorf("source group %d platform mismatch: expected %s, got %s", srcGroupID, platform, srcGroup.Platform)
 			}
+			// 新建分组不属于任何租户，不能复制租户账号池中的账号
+			if srcGroup.TenantID != nil {
+				return nil, ErrTenantMismatch.WithMetadata(map[string]string{"source_group_id": strconv.FormatInt(srcGroupID, 10)})
+			}
 		}
 
 		// 获取所有源分组的账号（去重）
@@ -920,6 +978,9 @@ func (s *adminServiceImpl) CreateGroup(ctx context.Context, input *CreateGroupIn
 		SoraStorageQuotaBytes:           input.SoraStorageQuotaBytes,
 		AllowMessagesDispatch:           input.AllowMessagesDispatch,
 		DefaultMappedModel:              input.DefaultMappedModel,
+		ModelAliases:                    modelAliases,
+		FallbackChain:                   fallbackChain,
+		SchedulingStrategy:              input.SchedulingStrategy,
 	}
 	if err := s.groupRepo.Create(ctx, group); err != nil {
 		return nil, err
@@ -990,6 +1051,63 @@ func (s *adminServiceImpl) validateFallbackGroup(ctx context.Context, currentGro
 	}
 }
 
+// validateFallbackGroupTenant 校验降级/兜底分组与当前分组属于同一租户，避免请求回落到其他租户的账号池
+func (s *adminServiceImpl) validateFallbackGroupTenant(ctx context.Context, tenantID *int64, fallbackGroupID int64) error {
+	fallbackGroup, err := s.groupRepo.GetByIDLite(ctx, fallbackGroupID)
+	if err != nil {
+		return fmt.Errorf("fallback group not found: %w", err)
+	}
+	if !TenantMatches(fallbackGroup.TenantID, tenantID) {
+		return ErrTenantMismatch
+	}
+	return nil
+}
+
+// validateFallbackChain 校验并规范化跨平台降级链（去重、去除 0 值），返回 nil 表示未配置。
+// 降级链仅对标准计费分组生效；链上分组须存在、非订阅分组、与当前分组同租户，且平台支持格式转换。
+func (s *adminServiceImpl) validateFallbackChain(ctx context.Context, currentGroupID int64, tenantID *int64, subscriptionType string, chain []int64) ([]int64, error) {
+	normalized := make([]int64, 0, len(chain))
+	seen := make(map[int64]struct{}, len(chain))
+	for _, id := range chain {
+		if id <= 0 {
+			continue
+		}
+		if _, ok := seen[id]; ok {
+			continue
+		}
+		seen[id] = struct{}{}
+		normalized = append(normalized, id)
+	}
+	if len(normalized) == 0 {
+		return nil, nil
+	}
+	if subscriptionType == SubscriptionTypeSubscription {
+		return nil, fmt.Errorf("subscription groups cannot set fallback chain")
+	}
+	if len(normalized) > MaxFallbackChainLength {
+		return nil, fmt.Errorf("fallback chain supports at most %d groups", MaxFallbackChainLength)
+	}
+	for _, id := range normalized {
+		if currentGroupID > 0 && id == currentGroupID {
+			return nil, fmt.Errorf("cannot add self to fallback chain")
+		}
+		fallbackGroup, err := s.groupRepo.GetByIDLite(ctx, id)
+		if err != nil {
+			return nil, fmt.Errorf("fallback chain group %d not found: %w", id, err)
+		}
+		if !IsFallbackChainPlatform(fallbackGroup.Platform) {
+			return nil, fmt.Errorf("fallback chain group %d platform %s is not supported", id, fallbackGroup.Platform)
+		}
+		if fallbackGroup.SubscriptionType == SubscriptionTypeSubscription {
+			return nil, fmt.Errorf("fallback chain group %d cannot be subscription type", id)
+		}
+		if !TenantMatches(fallbackGroup.TenantID, tenantID) {
+			return nil, ErrTenantMismatch
+		}
+	}
+	return normalized, nil
+}
+
 // validateFallbackGroupOnInvalidRequest 校验无效请求兜底分组的有效性
 // currentGroupID: 当前分组 ID（新建时为 0）
 // platform/subscriptionType: 当前分组的有效平台/订阅类型
@@ -1096,6 +1214,9 @@ func (s *adminServiceImpl) UpdateGroup(ctx context.Context, id int64, input *Upd
 			if err := s.validateFallbackGroup(ctx, id, *input.FallbackGroupID); err != nil {
 				return nil, err
 			}
+			if err := s.validateFallbackGroupTenant(ctx, group.TenantID, *input.FallbackGroupID); err != nil {
+				return nil, err
+			}
 			group.FallbackGroupID = input.FallbackGroupID
 		} else {
 			// 传入 0 或负数表示清除降级分组
@@ -1114,8 +1235,18 @@ func (s *adminServiceImpl) UpdateGroup(ctx context.Context, id int64, input *Upd
 		if err := s.validateFallbackGroupOnInvalidRequest(ctx, id, group.Platform, group.SubscriptionType, *fallbackOnInvalidRequest); err != nil {
 			return nil, err
 		}
+		if err := s.validateFallbackGroupTenant(ctx, group.TenantID, *fallbackOnInvalidRequest); err != nil {
+			return nil, err
+		}
 	}
 	group.FallbackGroupIDOnInvalidRequest = fallbackOnInvalidRequest
+	if input.FallbackChain != nil {
+		fallbackChain, err := s.validateFallbackChain(ctx, id, group.TenantID, group.SubscriptionType, input.FallbackChain)
+		if err != nil {
+			return nil, err
+		}
+		group.FallbackChain = fallbackChain
+	}
 
 	// 模型路由配置
 	if input.ModelRouting != nil {
@@ -1140,6 +1271,19 @@ func (s *adminServiceImpl) UpdateGroup(ctx context.Context, id int64, input *Upd
 	if input.DefaultMappedModel != nil {
 		group.DefaultMappedModel = *input.DefaultMappedModel
 	}
+	if input.ModelAliases != nil {
+		modelAliases, err := normalizeModelAliases(input.ModelAliases)
+		if err != nil {
+			return nil, err
+		}
+		group.ModelAliases = modelAliases
+	}
+	if input.SchedulingStrategy != nil {
+		if !IsValidSchedulingStrategy(*input.SchedulingStrategy) {
+			return nil, ErrInvalidSchedulingStrategy
+		}
+		group.SchedulingStrategy = *input.SchedulingStrategy
+	}
 
 	if err := s.groupRepo.Update(ctx, group); err != nil {
 		return nil, err
@@ -1171,6 +1315,9 @@ func (s *adminServiceImpl) UpdateGroup(ctx context.Context, id int64, input *Upd
 			if srcGroup.Platform != group.Platform {
 				return nil, fmt.Errorf("source group %d platform mismatch: expected %s, got %s", srcGroupID, group.Platform, srcGroup.Platform)
 			}
+			if !TenantMatches(srcGroup.TenantID, group.TenantID) {
+				return nil, ErrTenantMismatch.WithMetadata(map[string]string{"source_group_id": strconv.FormatInt(srcGroupID, 10)})
+			}
 		}
 
 		// 获取所有源分组的账号（去重）
@@ -1280,6 +1427,10 @@ func (s *adminServiceImpl) AdminUpdateAPIKeyGroupID(ctx context.Context, keyID i
 		if group.Status != StatusActive {
 			return nil, infraerrors.BadRequest("GROUP_NOT_ACTIVE", "target group is not active")
 		}
+		// 租户隔离：Key 所属用户与目标分组须属于同一租户
+		if apiKey.User != nil && !TenantMatches(apiKey.User.TenantID, group.TenantID) {
+			return nil, ErrTenantMismatch
+		}
 		// 订阅类型分组：用户须持有该分组的有效订阅才可绑定
 		if group.IsSubscriptionType() {
 			if s.userSubRepo == nil {
@@ -1407,6 +1558,9 @@ func (s *adminServiceImpl) CreateAccount(ctx context.Context, input *CreateAccou
 			return nil, err
 		}
 	}
+	if err := s.validateAccountGroupsTenant(ctx, groupIDs); err != nil {
+		return nil, err
+	}
 
 	// Sora apikey 账号的 base_url 必填校验
 	if input.Platform == PlatformSora && input.Type == AccountTypeAPIKey {
@@ -1571,6 +1725,9 @@ func (s *adminServiceImpl) UpdateAccount(ctx context.Context, id int64, input *U
 		if err := s.validateGroupIDsExist(ctx, *input.GroupIDs); err != nil {
 			return nil, err
 		}
+		if err := s.validateAccountGroupsTenant(ctx, *input.GroupIDs); err != nil {
+			return nil, err
+		}
 
 		// 检查混合渠道风险（除非用户已确认）
 		if !input.SkipMixedChannelCheck {
@@ -1611,27 +1768,51 @@ func (s *adminServiceImpl) BulkUpdateAccounts(ctx context.Context, input *BulkUp
 	if len(input.AccountIDs) == 0 {
 		return result, nil
 	}
-	if input.GroupIDs != nil {
+	groupMode := input.GroupIDsMode
+	if groupMode == "" {
+		groupMode = BulkGroupModeReplace
+	}
+	if groupMode != BulkGroupModeReplace && groupMode != BulkGroupModeAdd && groupMode != BulkGroupModeRemove {
+		return nil, infraerrors.BadRequest("INVALID_GROUP_IDS_MODE", "group_ids_mode must be one of: replace, add, remove")
+	}
+	if input.GroupIDs != nil && groupMode != BulkGroupModeRemove {
 		if err := s.validateGroupIDsExist(ctx, *input.GroupIDs); err != nil {
 			return nil, err
 		}
+		if err := s.validateAccountGroupsTenant(ctx, *input.GroupIDs); err != nil {
+			return nil, err
+		}
 	}
 
-	needMixedChannelCheck := input.GroupIDs != nil && !input.SkipMixedChannelCheck
+	// 移除分组不会引入混合渠道风险，无需检查
+	needMixedChannelCheck := input.GroupIDs != nil && !input.SkipMixedChannelCheck && groupMode != BulkGroupModeRemove
+	// add / remove 需在各账号现有分组的基础上计算最终分组
+	needCurrentGroups := input.GroupIDs != nil && groupMode != BulkGroupModeReplace
 
-	// 预加载账号平台信息（混合渠道检查需要）。
+	// 预加载账号平台与现有分组（混合渠道检查与增量分组需要）。
 	platformByID := map[int64]string{}
-	if needMixedChannelCheck {
+	groupIDsByAccount := map[int64][]int64{}
+	if needMixedChannelCheck || needCurrentGroups {
 		accounts, err := s.accountRepo.GetByIDs(ctx, input.AccountIDs)
 		if err != nil {
 			return nil, err
 		}
 		for _, account := range accounts {
-			if account != nil {
-				platformByID[account.ID] = account.Platform
+			if account == nil {
+				continue
+			}
+			platformByID[account.ID] = account.Platform
+			if needCurrentGroups {
+				groupIDsByAccount[account.ID] = mergeBulkGroupIDs(groupMode, account.GroupIDs, *input.GroupIDs)
 			}
 		}
 	}
+	targetGroupIDs := func(accountID int64) []int64 {
+		if needCurrentGroups {
+			return groupIDsByAccount[accountID]
+		}
+		return *input.GroupIDs
+	}
 
 	// 预检查混合渠道风险：在任何写操作之前，若发现风险立即返回错误。
 	if needMixedChannelCheck {
@@ -1640,7 +1821,7 @@ func (s *adminServiceImpl) BulkUpdateAccounts(ctx context.Context, input *BulkUp
 			if platform == "" {
 				continue
 			}
-			if err := s.checkMixedChannelRisk(ctx, accountID, platform, *input.GroupIDs); err != nil {
+			if err := s.checkMixedChannelRisk(ctx, accountID, platform, targetGroupIDs(accountID)); err != nil {
 				return nil, err
 			}
 		}
@@ -1698,7 +1879,13 @@ func (s *adminServiceImpl) BulkUpdateAccounts(ctx context.Context, input *BulkUp
 		entry := BulkUpdateAccountResult{AccountID: accountID}
 
 		if input.GroupIDs != nil {
-			if err := s.accountRepo.BindGroups(ctx, accountID, *input.GroupIDs); err != nil {
+			var err error
+			if _, found := platformByID[accountID]; needCurrentGroups && !found {
+				err = ErrAccountNotFound
+			} else {
+				err = s.accountRepo.BindGroups(ctx, accountID, targetGroupIDs(accountID))
+			}
+			if err != nil {
 				entry.Success = false
 				entry.Error = err.Error()
 				result.Failed++
@@ -1717,6 +1904,28 @@ func (s *adminServiceImpl) BulkUpdateAccounts(ctx context.Context, input *BulkUp
 	return result, nil
 }
 
+// mergeBulkGroupIDs 按批量分组方式计算账号的最终分组（保持原有顺序，去重）
+func mergeBulkGroupIDs(mode string, current, groupIDs []int64) []int64 {
+	out := make([]int64, 0, len(current)+len(groupIDs))
+	switch mode {
+	case BulkGroupModeAdd:
+		for _, id := range append(append([]int64{}, current...), groupIDs...) {
+			if !slices.Contains(out, id) {
+				out = append(out, id)
+			}
+		}
+	case BulkGroupModeRemove:
+		for _, id := range current {
+			if !slices.Contains(groupIDs, id) && !slices.Contains(out, id) {
+				out = append(out, id)
+			}
+		}
+	default:
+		out = append(out, groupIDs...)
+	}
+	return out
+}
+
 func (s *adminServiceImpl) DeleteAccount(ctx context.Context, id int64) error {
 	if err := s.accountRepo.Delete(ctx, id); err != nil {
 		return err
@@ -1761,6 +1970,41 @@ func (s *adminServiceImpl) SetAccountSchedulable(ctx context.Context, id int64,
 	return updated, nil
 }
 
+// ArchiveAccount 归档账号：移出调度，保留凭证、分组与用量统计
+func (s *adminServiceImpl) ArchiveAccount(ctx context.Context, id int64) (*Account, error) {
+	account, err := s.accountRepo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if account.Status == StatusArchived {
+		return account, nil
+	}
+	account.Status = StatusArchived
+	account.Schedulable = false
+	if err := s.accountRepo.Update(ctx, account); err != nil {
+		return nil, err
+	}
+	return account, nil
+}
+
+// RestoreAccount 恢复已归档的账号并重新加入调度
+func (s *adminServiceImpl) RestoreAccount(ctx context.Context, id int64) (*Account, error) {
+	account, err := s.accountRepo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if account.Status != StatusArchived {
+		return nil, ErrAccountNotArchived
+	}
+	account.Status = StatusActive
+	account.ErrorMessage = ""
+	account.Schedulable = true
+	if err := s.accountRepo.Update(ctx, account); err != nil {
+		return nil, err
+	}
+	return account, nil
+}
+
 // Proxy management implementations
 func (s *adminServiceImpl) ListProxies(ctx context.Context, page, pageSize int, protocol, status, search string) ([]Proxy, int64, error) {
 	params := pagination.PaginationParams{Page: page, PageSize: pageSize}
@@ -2405,6 +2649,28 @@ func (s *adminServiceImpl) validateGroupIDsExist(ctx context.Context, groupIDs [
 	return nil
 }
 
+// validateAccountGroupsTenant 校验账号绑定的分组均属于同一租户，保证账号池不跨租户共享
+func (s *adminServiceImpl) validateAccountGroupsTenant(ctx context.Context, groupIDs []int64) error {
+	if len(groupIDs) < 2 {
+		return nil
+	}
+	var tenantID *int64
+	for i, groupID := range groupIDs {
+		group, err := s.groupRepo.GetByIDLite(ctx, groupID)
+		if err != nil {
+			return fmt.Errorf("get group: %w", err)
+		}
+		if i == 0 {
+			tenantID = group.TenantID
+			continue
+		}
+		if !TenantMatches(group.TenantID, tenantID) {
+			return ErrTenantGroupsMixed
+		}
+	}
+	return nil
+}
+
 // CheckMixedChannelRisk checks whether target groups contain mixed channels for the current account platform.
 func (s *adminServiceImpl) CheckMixedChannelRisk(ctx context.Context, currentAccountID int64, currentAccountPlatform string, groupIDs []int64) error {
 	return s.checkMixedChannelRisk(ctx, currentAccountID, currentAccountPlatform, groupIDs)
@@ -140,6 +140,9 @@ func (s *apiKeyRepoStubForGroupUpdate) ListKeysByGroupID(context.Context, int64)
 func (s *apiKeyRepoStubForGroupUpdate) IncrementQuotaUsed(context.Context, int64, float64) (float64, error) {
 	panic("unexpected")
 }
+func (s *apiKeyRepoStubForGroupUpdate) IncrementImageQuotaUsed(context.Context, int64, int) (int, error) {
+	panic("unexpected")
+}
 func (s *apiKeyRepoStubForGroupUpdate) UpdateLastUsed(context.Context, int64, time.Time) error {
 	panic("unexpected")
 }
@@ -152,6 +155,16 @@ func (s *apiKeyRepoStubForGroupUpdate) ResetRateLimitWindows(context.Context, in
 func (s *apiKeyRepoStubForGroupUpdate) GetRateLimitData(context.Context, int64) (*APIKeyRateLimitData, error) {
 	panic("unexpected")
 }
+func (s *apiKeyRepoStubForGroupUpdate) IncrementBudgetUsed(context.Context, int64, float64, time.Time) (float64, error) {
+	panic("unexpected")
+}
+func (s *apiKeyRepoStubForGroupUpdate) MarkBudgetExceeded(context.Context, int64, time.Time, time.Time) (bool, error) {
+	panic("unexpected")
+}
+
+func (s *apiKeyRepoStubForGroupUpdate) RotateKey(context.Context, int64, string, string, *time.Time) error {
+	panic("unexpected")
+}
 
 // groupRepoStubForGroupUpdate implements GroupRepository for AdminUpdateAPIKeyGroupID tests.
 type groupRepoStubForGroupUpdate struct {
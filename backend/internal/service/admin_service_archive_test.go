@@ -0,0 +1,61 @@
+//go:build unit
+
+package service
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+type accountRepoStubForArchive struct {
+	accountRepoStub
+	account *Account
+	updated []Account
+}
+
+func (s *accountRepoStubForArchive) GetByID(_ context.Context, id int64) (*Account, error) {
+	if s.account == nil || s.account.ID != id {
+		return nil, ErrAccountNotFound
+	}
+	cp := *s.account
+	return &cp, nil
+}
+
+func (s *accountRepoStubForArchive) Update(_ context.Context, account *Account) error {
+	s.updated = append(s.updated, *account)
+	cp := *account
+	s.account = &cp
+	return nil
+}
+
+func TestAdminService_ArchiveAndRestoreAccount(t *testing.T) {
+	repo := &accountRepoStubForArchive{account: &Account{
+		ID:          1,
+		Status:      StatusActive,
+		Schedulable: true,
+		Credentials: map[string]any{"access_token": "tok"},
+	}}
+	svc := &adminServiceImpl{accountRepo: repo}
+
+	archived, err := svc.ArchiveAccount(context.Background(), 1)
+	require.NoError(t, err)
+	require.Equal(t, StatusArchived, archived.Status)
+	require.False(t, archived.Schedulable)
+	require.False(t, archived.IsSchedulable())
+	require.Equal(t, "tok", archived.Credentials["access_token"])
+
+	// 重复归档不再写库
+	_, err = svc.ArchiveAccount(context.Background(), 1)
+	require.NoError(t, err)
+	require.Len(t, repo.updated, 1)
+
+	restored, err := svc.RestoreAccount(context.Background(), 1)
+	require.NoError(t, err)
+	require.Equal(t, StatusActive, restored.Status)
+	require.True(t, restored.Schedulable)
+
+	_, err = svc.RestoreAccount(context.Background(), 1)
+	require.ErrorIs(t, err, ErrAccountNotArchived)
+}
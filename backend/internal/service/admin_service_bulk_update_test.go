@@ -16,6 +16,7 @@ type accountRepoStubForBulkUpdate struct {
 	bulkUpdateIDs    []int64
 	bindGroupErrByID map[int64]error
 	bindGroupsCalls  []int64
+	bindGroupsArgs   map[int64][]int64
 	getByIDsAccounts []*Account
 	getByIDsErr      error
 	getByIDsCalled   bool
@@ -35,8 +36,12 @@ func (s *accountRepoStubForBulkUpdate) BulkUpdate(_ context.Context, ids []int64
 	return int64(len(ids)), nil
 }
 
-func (s *accountRepoStubForBulkUpdate) BindGroups(_ context.Context, accountID int64, _ []int64) error {
+func (s *accountRepoStubForBulkUpdate) BindGroups(_ context.Context, accountID int64, groupIDs []int64) error {
 	s.bindGroupsCalls = append(s.bindGroupsCalls, accountID)
+	if s.bindGroupsArgs == nil {
+		s.bindGroupsArgs = map[int64][]int64{}
+	}
+	s.bindGroupsArgs[accountID] = groupIDs
 	if err, ok := s.bindGroupErrByID[accountID]; ok {
 		return err
 	}
@@ -170,3 +175,65 @@ func TestAdminService_BulkUpdateAccounts_MixedChannelPreCheckBlocksOnExistingCon
 	// No BindGroups should have been called since the check runs before any write.
 	require.Empty(t, repo.bindGroupsCalls)
 }
+
+// TestAdminService_BulkUpdateAccounts_GroupModeAddTagsAccounts verifies that add mode keeps
+// each account's existing groups and appends the new ones (bulk tagging).
+func TestAdminService_BulkUpdateAccounts_GroupModeAddTagsAccounts(t *testing.T) {
+	repo := &accountRepoStubForBulkUpdate{
+		getByIDsAccounts: []*Account{
+			{ID: 1, Platform: PlatformOpenAI, GroupIDs: []int64{5}},
+			{ID: 2, Platform: PlatformOpenAI, GroupIDs: []int64{10}},
+		},
+	}
+	svc := &adminServiceImpl{
+		accountRepo: repo,
+		groupRepo:   &groupRepoStubForAdmin{getByID: &Group{ID: 10, Name: "team-a"}},
+	}
+
+	groupIDs := []int64{10}
+	result, err := svc.BulkUpdateAccounts(context.Background(), &BulkUpdateAccountsInput{
+		AccountIDs:            []int64{1, 2, 3},
+		GroupIDs:              &groupIDs,
+		GroupIDsMode:          BulkGroupModeAdd,
+		SkipMixedChannelCheck: true,
+	})
+	require.NoError(t, err)
+	require.Equal(t, []int64{5, 10}, repo.bindGroupsArgs[1])
+	require.Equal(t, []int64{10}, repo.bindGroupsArgs[2])
+	// Account 3 does not exist and is reported as failed instead of being bound.
+	require.ElementsMatch(t, []int64{1, 2}, result.SuccessIDs)
+	require.Equal(t, []int64{3}, result.FailedIDs)
+	require.NotContains(t, repo.bindGroupsCalls, int64(3))
+}
+
+// TestAdminService_BulkUpdateAccounts_GroupModeRemove verifies that remove mode only drops the
+// given groups and skips the group existence and mixed channel checks.
+func TestAdminService_BulkUpdateAccounts_GroupModeRemove(t *testing.T) {
+	repo := &accountRepoStubForBulkUpdate{
+		getByIDsAccounts: []*Account{
+			{ID: 1, Platform: PlatformOpenAI, GroupIDs: []int64{5, 10, 11}},
+		},
+	}
+	svc := &adminServiceImpl{accountRepo: repo}
+
+	groupIDs := []int64{10, 11}
+	result, err := svc.BulkUpdateAccounts(context.Background(), &BulkUpdateAccountsInput{
+		AccountIDs:   []int64{1},
+		GroupIDs:     &groupIDs,
+		GroupIDsMode: BulkGroupModeRemove,
+	})
+	require.NoError(t, err)
+	require.Equal(t, 1, result.Success)
+	require.Equal(t, []int64{5}, repo.bindGroupsArgs[1])
+}
+
+func TestAdminService_BulkUpdateAccounts_InvalidGroupMode(t *testing.T) {
+	svc := &adminServiceImpl{accountRepo: &accountRepoStubForBulkUpdate{}}
+	groupIDs := []int64{10}
+	_, err := svc.BulkUpdateAccounts(context.Background(), &BulkUpdateAccountsInput{
+		AccountIDs:   []int64{1},
+		GroupIDs:     &groupIDs,
+		GroupIDsMode: "merge",
+	})
+	require.ErrorContains(t, err, "group_ids_mode")
+}
@@ -785,3 +785,76 @@ func TestAdminService_UpdateGroup_InvalidRequestFallbackAllowsAntigravity(t *tes
 	require.NotNil(t, repo.updated)
 	require.Equal(t, fallbackID, *repo.updated.FallbackGroupIDOnInvalidRequest)
 }
+
+func TestAdminService_CreateGroup_FallbackChainNormalizes(t *testing.T) {
+	repo := &groupRepoStubForInvalidRequestFallback{
+		groups: map[int64]*Group{
+			10: {ID: 10, Platform: PlatformOpenAI, SubscriptionType: SubscriptionTypeStandard},
+			11: {ID: 11, Platform: PlatformAnthropic, SubscriptionType: SubscriptionTypeStandard},
+		},
+	}
+	svc := &adminServiceImpl{groupRepo: repo}
+
+	group, err := svc.CreateGroup(context.Background(), &CreateGroupInput{
+		Name:          "codex",
+		Platform:      PlatformOpenAI,
+		FallbackChain: []int64{11, 0, 10, 11},
+	})
+	require.NoError(t, err)
+	require.Equal(t, []int64{11, 10}, group.FallbackChain)
+	require.Equal(t, []int64{11, 10}, repo.created.FallbackChain)
+}
+
+func TestAdminService_CreateGroup_FallbackChainRejectsInvalidGroups(t *testing.T) {
+	tenantID := int64(3)
+	repo := &groupRepoStubForInvalidRequestFallback{
+		groups: map[int64]*Group{
+			10: {ID: 10, Platform: PlatformSora, SubscriptionType: SubscriptionTypeStandard},
+			11: {ID: 11, Platform: PlatformAnthropic, SubscriptionType: SubscriptionTypeSubscription},
+			12: {ID: 12, Platform: PlatformAnthropic, SubscriptionType: SubscriptionTypeStandard, TenantID: &tenantID},
+			13: {ID: 13, Platform: PlatformAnthropic, SubscriptionType: SubscriptionTypeStandard},
+		},
+	}
+	svc := &adminServiceImpl{groupRepo: repo}
+
+	cases := []struct {
+		name  string
+		input CreateGroupInput
+		want  string
+	}{
+		{"unsupported platform", CreateGroupInput{Name: "g", FallbackChain: []int64{10}}, "is not supported"},
+		{"subscription target", CreateGroupInput{Name: "g", FallbackChain: []int64{11}}, "cannot be subscription type"},
+		{"tenant mismatch", CreateGroupInput{Name: "g", FallbackChain: []int64{12}}, ErrTenantMismatch.Error()},
+		{"not found", CreateGroupInput{Name: "g", FallbackChain: []int64{99}}, "not found"},
+		{"subscription source", CreateGroupInput{Name: "g", SubscriptionType: SubscriptionTypeSubscription, FallbackChain: []int64{13}}, "subscription groups cannot set fallback chain"},
+		{"too long", CreateGroupInput{Name: "g", FallbackChain: []int64{13, 14, 15, 16, 17, 18}}, "at most"},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := svc.CreateGroup(context.Background(), &tc.input)
+			require.ErrorContains(t, err, tc.want)
+		})
+	}
+	require.Nil(t, repo.created)
+}
+
+func TestAdminService_UpdateGroup_FallbackChain(t *testing.T) {
+	repo := &groupRepoStubForInvalidRequestFallback{
+		groups: map[int64]*Group{
+			1:  {ID: 1, Name: "primary", Platform: PlatformOpenAI, Status: StatusActive, SubscriptionType: SubscriptionTypeStandard},
+			10: {ID: 10, Platform: PlatformAnthropic, SubscriptionType: SubscriptionTypeStandard},
+		},
+	}
+	svc := &adminServiceImpl{groupRepo: repo}
+
+	_, err := svc.UpdateGroup(context.Background(), 1, &UpdateGroupInput{FallbackChain: []int64{1}})
+	require.ErrorContains(t, err, "cannot add self to fallback chain")
+
+	group, err := svc.UpdateGroup(context.Background(), 1, &UpdateGroupInput{FallbackChain: []int64{10}})
+	require.NoError(t, err)
+	require.Equal(t, []int64{10}, group.FallbackChain)
+
+	group, err = svc.UpdateGroup(context.Background(), 1, &UpdateGroupInput{FallbackChain: []int64{}})
+	require.NoError(t, err)
+	require.Nil(t, group.FallbackChain)
+}
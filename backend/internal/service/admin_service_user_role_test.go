@@ -0,0 +1,49 @@
+//go:build unit
+
+package service
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestAdminService_UpdateUser_ChangesRole(t *testing.T) {
+	repo := &balanceUserRepoStub{userRepoStub: &userRepoStub{user: &User{ID: 7, Role: RoleUser}}}
+	invalidator := &authCacheInvalidatorStub{}
+	svc := &adminServiceImpl{userRepo: repo, authCacheInvalidator: invalidator}
+
+	role := RoleViewer
+	user, err := svc.UpdateUser(context.Background(), 7, &UpdateUserInput{Role: &role})
+	require.NoError(t, err)
+	require.Equal(t, RoleViewer, user.Role)
+	require.True(t, user.HasAdminAccess())
+	require.Len(t, repo.updated, 1)
+	require.Equal(t, []int64{7}, invalidator.userIDs)
+}
+
+func TestAdminService_UpdateUser_RejectsAdminRoleChanges(t *testing.T) {
+	repo := &balanceUserRepoStub{userRepoStub: &userRepoStub{user: &User{ID: 1, Role: RoleAdmin}}}
+	svc := &adminServiceImpl{userRepo: repo}
+
+	role := RoleOperator
+	_, err := svc.UpdateUser(context.Background(), 1, &UpdateUserInput{Role: &role})
+	require.ErrorIs(t, err, ErrAdminRoleImmutable)
+
+	repo.userRepoStub.user = &User{ID: 2, Role: RoleUser}
+	role = RoleAdmin
+	_, err = svc.UpdateUser(context.Background(), 2, &UpdateUserInput{Role: &role})
+	require.ErrorIs(t, err, ErrInvalidUserRole)
+	require.Empty(t, repo.updated)
+}
+
+func TestAdminRoleAtLeast(t *testing.T) {
+	require.True(t, AdminRoleAtLeast(RoleAdmin, RoleOperator))
+	require.True(t, AdminRoleAtLeast(RoleOperator, RoleOperator))
+	require.True(t, AdminRoleAtLeast(RoleViewer, RoleViewer))
+	require.False(t, AdminRoleAtLeast(RoleViewer, RoleOperator))
+	require.False(t, AdminRoleAtLeast(RoleOperator, RoleAdmin))
+	require.False(t, AdminRoleAtLeast(RoleUser, RoleViewer))
+	require.False(t, AdminRoleAtLeast("", ""))
+}
@@ -0,0 +1,149 @@
+package service
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/ShaohongDong/sub2api/internal/config"
+)
+
+// 运维告警事件类型（与 config.AlertWebhookEvents 一致）
+const (
+	AlertEventAccountDisabled    = "account_disabled"
+	AlertEventTokenRefreshFailed = "token_refresh_failed"
+	AlertEventQuotaThreshold     = "quota_threshold"
+	AlertEventErrorRateSpike     = "error_rate_spike"
+	AlertEventOpsAlert           = "ops_alert"
+)
+
+// 告警级别
+const (
+	AlertSeverityInfo     = "info"
+	AlertSeverityWarning  = "warning"
+	AlertSeverityCritical = "critical"
+)
+
+const (
+	// alertMaxInflight 同时发送中的告警上限，超出时丢弃并记录日志，避免告警风暴拖垮网关
+	alertMaxInflight = 16
+	// alertDedupMaxEntries 去重表的容量上限，超出时清理已过冷却期的记录
+	alertDedupMaxEntries = 4096
+)
+
+// AlertEvent 运维告警事件，Fields 为事件相关的结构化字段（账号 ID、额度等），可在模板中引用
+type AlertEvent struct {
+	Type       string         `json:"event"`
+	Severity   string         `json:"severity"`
+	Title      string         `json:"title"`
+	Message    string         `json:"message"`
+	Fields     map[string]any `json:"fields,omitempty"`
+	OccurredAt time.Time      `json:"occurred_at"`
+	// DedupKey 同一 DedupKey 在冷却期内只通知一次；为空时按事件类型去重
+	DedupKey string `json:"-"`
+}
+
+// AlertNotifier 将告警事件发送到外部渠道（如 webhook）
+type AlertNotifier interface {
+	Notify(ctx context.Context, event *AlertEvent) error
+}
+
+// AlertService 分发运维告警：按冷却期去重后异步发送，发送失败只记录日志
+type AlertService struct {
+	notifier       AlertNotifier
+	cooldown       time.Duration
+	timeout        time.Duration
+	quotaThreshold float64
+
+	inflight chan struct{}
+
+	mu       sync.Mutex
+	lastSent map[string]time.Time
+}
+
+// NewAlertService 创建告警服务；未启用 alert_webhooks 或 notifier 为 nil 时 Fire 为空操作
+func NewAlertService(cfg *config.Config, notifier AlertNotifier) *AlertService {
+	s := &AlertService{
+		timeout:  10 * time.Second,
+		inflight: make(chan struct{}, alertMaxInflight),
+		lastSent: make(map[string]time.Time),
+	}
+	if cfg == nil || !cfg.AlertWebhooks.Enabled {
+		return s
+	}
+	s.notifier = notifier
+	s.cooldown = time.Duration(cfg.AlertWebhooks.CooldownSeconds) * time.Second
+	if cfg.AlertWebhooks.TimeoutSeconds > 0 {
+		s.timeout = time.Duration(cfg.AlertWebhooks.TimeoutSeconds) * time.Second
+	}
+	s.quotaThreshold = cfg.AlertWebhooks.QuotaThresholdPercent / 100
+	return s
+}
+
+// Enabled 是否配置了告警渠道
+func (s *AlertService) Enabled() bool {
+	return s != nil && s.notifier != nil
+}
+
+// QuotaThreshold 返回触发 quota_threshold 的额度消耗比例（0-1），未启用时为 0
+func (s *AlertService) QuotaThreshold() float64 {
+	if !s.Enabled() {
+		return 0
+	}
+	return s.quotaThreshold
+}
+
+// Fire 异步发送告警事件；冷却期内的重复事件与超出并发上限的事件被丢弃
+func (s *AlertService) Fire(ctx context.Context, event *AlertEvent) {
+	if !s.Enabled() || event == nil || event.Type == "" {
+		return
+	}
+	if event.OccurredAt.IsZero() {
+		event.OccurredAt = time.Now()
+	}
+	if event.Severity == "" {
+		event.Severity = AlertSeverityWarning
+	}
+	if !s.shouldSend(event) {
+		return
+	}
+
+	select {
+	case s.inflight <- struct{}{}:
+	default:
+		slog.Warn("alert.dropped_inflight_full", "event", event.Type, "title", event.Title)
+		return
+	}
+	go func() {
+		defer func() { <-s.inflight }()
+		sendCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), s.timeout)
+		defer cancel()
+		if err := s.notifier.Notify(sendCtx, event); err != nil {
+			slog.Warn("alert.notify_failed", "event", event.Type, "title", event.Title, "error", err)
+		}
+	}()
+}
+
+func (s *AlertService) shouldSend(event *AlertEvent) bool {
+	if s.cooldown <= 0 {
+		return true
+	}
+	key := event.Type + ":" + event.DedupKey
+	now := event.OccurredAt
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if last, ok := s.lastSent[key]; ok && now.Sub(last) < s.cooldown {
+		return false
+	}
+	if len(s.lastSent) >= alertDedupMaxEntries {
+		for k, last := range s.lastSent {
+			if now.Sub(last) >= s.cooldown {
+				delete(s.lastSent, k)
+			}
+		}
+	}
+	s.lastSent[key] = now
+	return true
+}
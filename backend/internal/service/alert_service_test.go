@@ -0,0 +1,85 @@
+//go:build unit
+
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/ShaohongDong/sub2api/internal/config"
+	"github.com/stretchr/testify/require"
+)
+
+type alertNotifierStub struct {
+	events chan *AlertEvent
+}
+
+func (s *alertNotifierStub) Notify(_ context.Context, event *AlertEvent) error {
+	s.events <- event
+	return nil
+}
+
+func newAlertServiceForTest(cooldownSeconds int) (*AlertService, *alertNotifierStub) {
+	notifier := &alertNotifierStub{events: make(chan *AlertEvent, 8)}
+	cfg := &config.Config{AlertWebhooks: config.AlertWebhooksConfig{
+		Enabled:               true,
+		TimeoutSeconds:        5,
+		CooldownSeconds:       cooldownSeconds,
+		QuotaThresholdPercent: 80,
+	}}
+	return NewAlertService(cfg, notifier), notifier
+}
+
+func receiveAlert(t *testing.T, ch <-chan *AlertEvent) *AlertEvent {
+	t.Helper()
+	select {
+	case event := <-ch:
+		return event
+	case <-time.After(2 * time.Second):
+		t.Fatal("alert not delivered")
+		return nil
+	}
+}
+
+func TestAlertService_DisabledIsNoop(t *testing.T) {
+	notifier := &alertNotifierStub{events: make(chan *AlertEvent, 1)}
+	svc := NewAlertService(&config.Config{}, notifier)
+	require.False(t, svc.Enabled())
+	require.Zero(t, svc.QuotaThreshold())
+	svc.Fire(context.Background(), &AlertEvent{Type: AlertEventOpsAlert})
+	require.Empty(t, notifier.events)
+
+	var nilSvc *AlertService
+	require.False(t, nilSvc.Enabled())
+	nilSvc.Fire(context.Background(), &AlertEvent{Type: AlertEventOpsAlert})
+}
+
+func TestAlertService_DedupWithinCooldown(t *testing.T) {
+	svc, notifier := newAlertServiceForTest(600)
+	require.InDelta(t, 0.8, svc.QuotaThreshold(), 1e-9)
+
+	svc.Fire(context.Background(), &AlertEvent{Type: AlertEventAccountDisabled, DedupKey: "1", Title: "first"})
+	got := receiveAlert(t, notifier.events)
+	require.Equal(t, "first", got.Title)
+	require.Equal(t, AlertSeverityWarning, got.Severity)
+	require.False(t, got.OccurredAt.IsZero())
+
+	// 相同 DedupKey 在冷却期内被丢弃，不同 DedupKey 正常发送
+	svc.Fire(context.Background(), &AlertEvent{Type: AlertEventAccountDisabled, DedupKey: "1", Title: "dup"})
+	svc.Fire(context.Background(), &AlertEvent{Type: AlertEventAccountDisabled, DedupKey: "2", Title: "other"})
+	require.Equal(t, "other", receiveAlert(t, notifier.events).Title)
+
+	// 冷却期过后再次发送
+	svc.Fire(context.Background(), &AlertEvent{Type: AlertEventAccountDisabled, DedupKey: "1", Title: "later", OccurredAt: time.Now().Add(11 * time.Minute)})
+	require.Equal(t, "later", receiveAlert(t, notifier.events).Title)
+	require.Empty(t, notifier.events)
+}
+
+func TestAlertService_ZeroCooldownSendsEveryEvent(t *testing.T) {
+	svc, notifier := newAlertServiceForTest(0)
+	for i := 0; i < 3; i++ {
+		svc.Fire(context.Background(), &AlertEvent{Type: AlertEventErrorRateSpike, DedupKey: "rule-1"})
+		receiveAlert(t, notifier.events)
+	}
+}
@@ -0,0 +1,54 @@
+package service
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+
+	"github.com/tidwall/gjson"
+)
+
+// anthropicCachePrefixKey 把 Anthropic 请求中的 cache_control 断点转换为非 Anthropic 上游的缓存键。
+// OpenAI 等上游按前缀自动缓存、以 prompt_cache_key 决定请求落到哪台缓存机器，没有显式断点：
+// 这里按 tools → system → messages 的顺序取到第一个带 cache_control 的块为止（通常是稳定的工具定义与系统提示词），
+// 以该前缀的内容哈希作为缓存键，使共享同一前缀的请求命中同一份上游缓存。请求中没有断点时返回空字符串。
+func anthropicCachePrefixKey(body []byte, model string) string {
+	if len(body) == 0 {
+		return ""
+	}
+	h := sha256.New()
+	_, _ = h.Write([]byte(model))
+	found := false
+	consume := func(block gjson.Result) bool {
+		_, _ = h.Write([]byte(block.Raw))
+		if block.Get("cache_control").IsObject() {
+			found = true
+		}
+		return !found
+	}
+
+	parsed := gjson.ParseBytes(body)
+	parsed.Get("tools").ForEach(func(_, tool gjson.Result) bool { return consume(tool) })
+	if !found {
+		if system := parsed.Get("system"); system.IsArray() {
+			system.ForEach(func(_, block gjson.Result) bool { return consume(block) })
+		} else if system.Exists() {
+			_, _ = h.Write([]byte(system.Raw))
+		}
+	}
+	if !found {
+		parsed.Get("messages").ForEach(func(_, message gjson.Result) bool {
+			_, _ = h.Write([]byte(message.Get("role").String()))
+			content := message.Get("content")
+			if !content.IsArray() {
+				_, _ = h.Write([]byte(content.Raw))
+				return true
+			}
+			content.ForEach(func(_, block gjson.Result) bool { return consume(block) })
+			return !found
+		})
+	}
+	if !found {
+		return ""
+	}
+	return generateSessionUUID("cache_control:" + hex.EncodeToString(h.Sum(nil)))
+}
@@ -0,0 +1,36 @@
+package service
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestAnthropicCachePrefixKey(t *testing.T) {
+	first := []byte(`{
+		"system":[{"type":"text","text":"You are helpful.","cache_control":{"type":"ephemeral"}}],
+		"messages":[{"role":"user","content":"hello"}]
+	}`)
+	followUp := []byte(`{
+		"system":[{"type":"text","text":"You are helpful.","cache_control":{"type":"ephemeral"}}],
+		"messages":[{"role":"user","content":"hello"},{"role":"assistant","content":"hi"},{"role":"user","content":"more"}]
+	}`)
+	otherSystem := []byte(`{
+		"system":[{"type":"text","text":"You are terse.","cache_control":{"type":"ephemeral"}}],
+		"messages":[{"role":"user","content":"hello"}]
+	}`)
+
+	key := anthropicCachePrefixKey(first, "claude-sonnet-4-5")
+	require.NotEmpty(t, key)
+	require.Equal(t, key, anthropicCachePrefixKey(followUp, "claude-sonnet-4-5"), "same cached prefix shares the key")
+	require.NotEqual(t, key, anthropicCachePrefixKey(otherSystem, "claude-sonnet-4-5"))
+	require.NotEqual(t, key, anthropicCachePrefixKey(first, "claude-opus-4-5"))
+
+	// 断点位于消息中：前缀包含断点之前的全部消息
+	inMessages := []byte(`{"system":"sys","messages":[{"role":"user","content":[{"type":"text","text":"doc","cache_control":{"type":"ephemeral"}},{"type":"text","text":"q1"}]}]}`)
+	inMessages2 := []byte(`{"system":"sys","messages":[{"role":"user","content":[{"type":"text","text":"doc","cache_control":{"type":"ephemeral"}},{"type":"text","text":"q2"}]}]}`)
+	require.NotEmpty(t, anthropicCachePrefixKey(inMessages, "m"))
+	require.Equal(t, anthropicCachePrefixKey(inMessages, "m"), anthropicCachePrefixKey(inMessages2, "m"))
+
+	require.Empty(t, anthropicCachePrefixKey([]byte(`{"system":"sys","messages":[{"role":"user","content":"hello"}]}`), "m"))
+}
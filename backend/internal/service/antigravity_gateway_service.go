@@ -2793,7 +2793,7 @@ type antigravityStreamResult struct {
 }
 
 // antigravityClientWriter 封装流式响应的客户端写入，自动检测断开并标记。
-// 断开后所有写入操作变为 no-op，调用方通过 Disconnected() 判断是否继续 drain 上游。
+// 断开后所有写入操作变为 no-op，上游请求随请求 context 取消而结束，调用方通过 Disconnected() 返回已收集的 usage。
 type antigravityClientWriter struct {
 	w            gin.ResponseWriter
 	flusher      http.Flusher
@@ -2835,7 +2835,7 @@ func (cw *antigravityClientWriter) Disconnected() bool { return cw.disconnected
 
 func (cw *antigravityClientWriter) markDisconnected() {
 	cw.disconnected = true
-	logger.LegacyPrintf("service.antigravity_gateway", "Client disconnected during streaming (%s), continuing to drain upstream for billing", cw.prefix)
+	logger.LegacyPrintf("service.antigravity_gateway", "Client disconnected during streaming (%s), upstream request canceled", cw.prefix)
 }
 
 // handleStreamReadError 处理上游读取错误的通用逻辑。
@@ -31,10 +31,31 @@ type APIKey struct {
 	UserID      int64
 	Key         string
 	Name        string
+	Description string
 	GroupID     *int64
 	Status      string
 	IPWhitelist []string
 	IPBlacklist []string
+	// Scopes 限定可访问的端点类别（见 APIKeyScope* 常量），为空表示不限制
+	Scopes []string
+	// AllowedModels 限定可使用的模型（支持末尾 * 通配符），为空表示不限制
+	AllowedModels []string
+	// ClientRestriction 限定可使用的客户端类别（见 APIKeyClientRestriction* 常量），为空表示不限制
+	ClientRestriction string
+	// Tags 管理员分配的标签，供路由规则匹配
+	Tags []string
+	// DegradedFallbackModel 请求模型的所有账号均不可用时改用的模型，为空表示直接返回 503
+	DegradedFallbackModel string
+	// SystemPrompt 管理员为该 Key 设置的系统提示词，注入到每个推理请求（先于 gateway.system_prompts 路由规则应用），为空表示不注入
+	SystemPrompt string
+	// SystemPromptMode 系统提示词注入方式（见 SystemPromptMode* 常量），为空按 prepend 处理
+	SystemPromptMode string
+	// ModerationMode 提示词审核前置过滤方式（见 APIKeyModeration* 常量），为空表示沿用 gateway.moderation.pre_filter
+	ModerationMode string
+	// LogContent 是否保存该 Key 请求的完整提示词与响应（用于排查问题，按 content_log.retention_days 自动清理）
+	LogContent bool
+	// Simulate 演练模式：该 Key 的请求由内置模拟上游应答，不会到达真实账号（路由、配额、计费与用量记录照常执行）
+	Simulate bool
 	// 预编译的 IP 规则，用于认证热路径避免重复 ParseIP/ParseCIDR。
 	CompiledIPWhitelist *ip.CompiledIPRules `json:"-"`
 	CompiledIPBlacklist *ip.CompiledIPRules `json:"-"`
@@ -49,6 +70,16 @@ type APIKey struct {
 	QuotaUsed float64    // Used quota amount
 	ExpiresAt *time.Time // Expiration time (nil = never expires)
 
+	// Image quota fields (count of generated images)
+	ImageQuota     int // Max images (0 = unlimited)
+	ImageQuotaUsed int // Images generated so far
+
+	// SuppressReasoning drops reasoning/thinking output for clients other than Codex and Claude Code
+	SuppressReasoning bool
+
+	// Shadow 标记影子流量请求（仅进程内使用，不持久化）：用量不计费、不写入记录
+	Shadow bool `json:"-"`
+
 	// Rate limit fields
 	RateLimit5h   float64    // Rate limit in USD per 5h (0 = unlimited)
 	RateLimit1d   float64    // Rate limit in USD per 1d (0 = unlimited)
@@ -59,6 +90,27 @@ type APIKey struct {
 	Window5hStart *time.Time // Start of current 5h window
 	Window1dStart *time.Time // Start of current 1d window
 	Window7dStart *time.Time // Start of current 7d window
+
+	// Request / token limits (0 = unlimited), counted per minute and per calendar day/month
+	RPMLimit            int
+	TPMLimit            int
+	DailyRequestLimit   int
+	DailyTokenLimit     int64
+	MonthlyRequestLimit int
+	MonthlyTokenLimit   int64
+
+	// Spend budget (0 = no budget), reset at the start of each calendar period
+	BudgetAmount        float64
+	BudgetPeriod        string     // day / week / month (see APIKeyBudgetPeriod*)
+	BudgetAction        string     // disable / downgrade (see APIKeyBudgetAction*)
+	BudgetFallbackModel string     // Model used once the budget is exceeded with the downgrade action
+	BudgetUsed          float64    // Spent amount in the current period
+	BudgetPeriodStart   *time.Time // Start of the period BudgetUsed belongs to
+	BudgetExceededAt    *time.Time // When the budget was crossed in that period (nil = within budget)
+
+	// Key rotation: the replaced secret keeps working until PreviousKeyExpiresAt
+	PreviousKey          *string
+	PreviousKeyExpiresAt *time.Time
 }
 
 func (k *APIKey) IsActive() bool {
@@ -70,6 +122,35 @@ func (k *APIKey) HasRateLimits() bool {
 	return k.RateLimit5h > 0 || k.RateLimit1d > 0 || k.RateLimit7d > 0
 }
 
+// HasRequestLimits returns true if any request/token limit is configured
+func (k *APIKey) HasRequestLimits() bool {
+	return k.RPMLimit > 0 || k.TPMLimit > 0 ||
+		k.DailyRequestLimit > 0 || k.DailyTokenLimit > 0 ||
+		k.MonthlyRequestLimit > 0 || k.MonthlyTokenLimit > 0
+}
+
+// HasBudget returns true if a spend budget is configured
+func (k *APIKey) HasBudget() bool {
+	return k.BudgetAmount > 0
+}
+
+// IsBudgetExceeded reports whether the key crossed its budget in the period containing now.
+// A mark left over from an earlier period is ignored, so the key recovers once the period rolls.
+func (k *APIKey) IsBudgetExceeded(now time.Time) bool {
+	if !k.HasBudget() || k.BudgetExceededAt == nil || k.BudgetPeriodStart == nil {
+		return false
+	}
+	return !k.BudgetPeriodStart.Before(APIKeyBudgetPeriodStart(k.BudgetPeriod, now))
+}
+
+// EffectiveBudgetUsed returns the spend of the period containing now, or 0 if the stored period has ended.
+func (k *APIKey) EffectiveBudgetUsed(now time.Time) float64 {
+	if k.BudgetPeriodStart == nil || k.BudgetPeriodStart.Before(APIKeyBudgetPeriodStart(k.BudgetPeriod, now)) {
+		return 0
+	}
+	return k.BudgetUsed
+}
+
 // IsExpired checks if the API key has expired
 func (k *APIKey) IsExpired() bool {
 	if k.ExpiresAt == nil {
@@ -4,19 +4,29 @@ import "time"
 
 // APIKeyAuthSnapshot API Key 认证缓存快照（仅包含认证所需字段）
 type APIKeyAuthSnapshot struct {
-	APIKeyID    int64                    `json:"api_key_id"`
-	UserID      int64                    `json:"user_id"`
-	GroupID     *int64                   `json:"group_id,omitempty"`
-	Status      string                   `json:"status"`
-	IPWhitelist []string                 `json:"ip_whitelist,omitempty"`
-	IPBlacklist []string                 `json:"ip_blacklist,omitempty"`
-	User        APIKeyAuthUserSnapshot   `json:"user"`
-	Group       *APIKeyAuthGroupSnapshot `json:"group,omitempty"`
+	APIKeyID          int64                    `json:"api_key_id"`
+	UserID            int64                    `json:"user_id"`
+	GroupID           *int64                   `json:"group_id,omitempty"`
+	Status            string                   `json:"status"`
+	IPWhitelist       []string                 `json:"ip_whitelist,omitempty"`
+	IPBlacklist       []string                 `json:"ip_blacklist,omitempty"`
+	Scopes            []string                 `json:"scopes,omitempty"`
+	AllowedModels     []string                 `json:"allowed_models,omitempty"`
+	ClientRestriction string                   `json:"client_restriction,omitempty"`
+	Tags              []string                 `json:"tags,omitempty"`
+	User              APIKeyAuthUserSnapshot   `json:"user"`
+	Group             *APIKeyAuthGroupSnapshot `json:"group,omitempty"`
 
 	// Quota fields for API Key independent quota feature
 	Quota     float64 `json:"quota"`      // Quota limit in USD (0 = unlimited)
 	QuotaUsed float64 `json:"quota_used"` // Used quota amount
 
+	// Image quota limit (usage is read from DB at check time)
+	ImageQuota int `json:"image_quota,omitempty"`
+
+	// SuppressReasoning drops reasoning output for plain API clients
+	SuppressReasoning bool `json:"suppress_reasoning,omitempty"`
+
 	// Expiration field for API Key expiration feature
 	ExpiresAt *time.Time `json:"expires_at,omitempty"` // Expiration time (nil = never expires)
 
@@ -24,6 +34,29 @@ type APIKeyAuthSnapshot struct {
 	RateLimit5h float64 `json:"rate_limit_5h"`
 	RateLimit1d float64 `json:"rate_limit_1d"`
 	RateLimit7d float64 `json:"rate_limit_7d"`
+
+	// Request / token limits (usage read from Redis at check time)
+	RPMLimit            int   `json:"rpm_limit,omitempty"`
+	TPMLimit            int   `json:"tpm_limit,omitempty"`
+	DailyRequestLimit   int   `json:"daily_request_limit,omitempty"`
+	DailyTokenLimit     int64 `json:"daily_token_limit,omitempty"`
+	MonthlyRequestLimit int   `json:"monthly_request_limit,omitempty"`
+	MonthlyTokenLimit   int64 `json:"monthly_token_limit,omitempty"`
+
+	// Spend budget (usage is tracked in the DB; only the exceeded mark is needed at auth time)
+	BudgetAmount        float64    `json:"budget_amount,omitempty"`
+	BudgetPeriod        string     `json:"budget_period,omitempty"`
+	BudgetAction        string     `json:"budget_action,omitempty"`
+	BudgetFallbackModel string     `json:"budget_fallback_model,omitempty"`
+	BudgetPeriodStart   *time.Time `json:"budget_period_start,omitempty"`
+	BudgetExceededAt    *time.Time `json:"budget_exceeded_at,omitempty"`
+
+	DegradedFallbackModel string `json:"degraded_fallback_model,omitempty"`
+	SystemPrompt          string `json:"system_prompt,omitempty"`
+	SystemPromptMode      string `json:"system_prompt_mode,omitempty"`
+	ModerationMode        string `json:"moderation_mode,omitempty"`
+	LogContent            bool   `json:"log_content,omitempty"`
+	Simulate              bool   `json:"simulate,omitempty"`
 }
 
 // APIKeyAuthUserSnapshot 用户快照
@@ -33,6 +66,7 @@ type APIKeyAuthUserSnapshot struct {
 	Role        string  `json:"role"`
 	Balance     float64 `json:"balance"`
 	Concurrency int     `json:"concurrency"`
+	TenantID    *int64  `json:"tenant_id,omitempty"`
 }
 
 // APIKeyAuthGroupSnapshot 分组快照
@@ -69,6 +103,18 @@ type APIKeyAuthGroupSnapshot struct {
 	// OpenAI Messages 调度配置（仅 openai 平台使用）
 	AllowMessagesDispatch bool   `json:"allow_messages_dispatch"`
 	DefaultMappedModel    string `json:"default_mapped_model,omitempty"`
+
+	// 模型别名（覆盖全局别名表）
+	ModelAliases map[string]string `json:"model_aliases,omitempty"`
+
+	// 跨平台降级链
+	FallbackChain []int64 `json:"fallback_chain,omitempty"`
+
+	// 账号调度策略（仅 openai 平台使用）
+	SchedulingStrategy string `json:"scheduling_strategy,omitempty"`
+
+	// 所属租户（用于运行时租户隔离校验）
+	TenantID *int64 `json:"tenant_id,omitempty"`
 }
 
 // APIKeyAuthCacheEntry 缓存条目，支持负缓存
@@ -172,13 +172,17 @@ func (s *APIKeyService) loadAuthCacheEntry(ctx context.Context, key, cacheKey st
 		}
 		return nil, fmt.Errorf("get api key: %w", err)
 	}
+	graceKey := apiKey.isGraceKey(key)
 	apiKey.Key = key
 	snapshot := s.snapshotFromAPIKey(apiKey)
 	if snapshot == nil {
 		return nil, fmt.Errorf("get api key: %w", ErrAPIKeyNotFound)
 	}
 	entry := &APIKeyAuthCacheEntry{Snapshot: snapshot}
-	s.setAuthCacheEntry(ctx, cacheKey, entry, s.authCfg.l2TTL)
+	// 轮换宽限期内的旧密钥不写缓存：其失效时间与后续删除/再次轮换都无法通过按 key 失效覆盖
+	if !graceKey {
+		s.setAuthCacheEntry(ctx, cacheKey, entry, s.authCfg.l2TTL)
+	}
 	return entry, nil
 }
 
@@ -200,24 +204,50 @@ func (s *APIKeyService) snapshotFromAPIKey(apiKey *APIKey) *APIKeyAuthSnapshot {
 		return nil
 	}
 	snapshot := &APIKeyAuthSnapshot{
-		APIKeyID:    apiKey.ID,
-		UserID:      apiKey.UserID,
-		GroupID:     apiKey.GroupID,
-		Status:      apiKey.Status,
-		IPWhitelist: apiKey.IPWhitelist,
-		IPBlacklist: apiKey.IPBlacklist,
-		Quota:       apiKey.Quota,
-		QuotaUsed:   apiKey.QuotaUsed,
-		ExpiresAt:   apiKey.ExpiresAt,
-		RateLimit5h: apiKey.RateLimit5h,
-		RateLimit1d: apiKey.RateLimit1d,
-		RateLimit7d: apiKey.RateLimit7d,
+		APIKeyID:            apiKey.ID,
+		UserID:              apiKey.UserID,
+		GroupID:             apiKey.GroupID,
+		Status:              apiKey.Status,
+		IPWhitelist:         apiKey.IPWhitelist,
+		IPBlacklist:         apiKey.IPBlacklist,
+		Scopes:              apiKey.Scopes,
+		AllowedModels:       apiKey.AllowedModels,
+		Tags:                apiKey.Tags,
+		ClientRestriction:   apiKey.ClientRestriction,
+		Quota:               apiKey.Quota,
+		QuotaUsed:           apiKey.QuotaUsed,
+		ImageQuota:          apiKey.ImageQuota,
+		SuppressReasoning:   apiKey.SuppressReasoning,
+		ExpiresAt:           apiKey.ExpiresAt,
+		RateLimit5h:         apiKey.RateLimit5h,
+		RateLimit1d:         apiKey.RateLimit1d,
+		RateLimit7d:         apiKey.RateLimit7d,
+		RPMLimit:            apiKey.RPMLimit,
+		TPMLimit:            apiKey.TPMLimit,
+		DailyRequestLimit:   apiKey.DailyRequestLimit,
+		DailyTokenLimit:     apiKey.DailyTokenLimit,
+		MonthlyRequestLimit: apiKey.MonthlyRequestLimit,
+		MonthlyTokenLimit:   apiKey.MonthlyTokenLimit,
+		BudgetAmount:        apiKey.BudgetAmount,
+		BudgetPeriod:        apiKey.BudgetPeriod,
+		BudgetAction:        apiKey.BudgetAction,
+		BudgetFallbackModel: apiKey.BudgetFallbackModel,
+		BudgetPeriodStart:   apiKey.BudgetPeriodStart,
+		BudgetExceededAt:    apiKey.BudgetExceededAt,
+
+		DegradedFallbackModel: apiKey.DegradedFallbackModel,
+		SystemPrompt:          apiKey.SystemPrompt,
+		SystemPromptMode:      apiKey.SystemPromptMode,
+		ModerationMode:        apiKey.ModerationMode,
+		LogContent:            apiKey.LogContent,
+		Simulate:              apiKey.Simulate,
 		User: APIKeyAuthUserSnapshot{
 			ID:          apiKey.User.ID,
 			Status:      apiKey.User.Status,
 			Role:        apiKey.User.Role,
 			Balance:     apiKey.User.Balance,
 			Concurrency: apiKey.User.Concurrency,
+			TenantID:    apiKey.User.TenantID,
 		},
 	}
 	if apiKey.Group != nil {
@@ -247,6 +277,10 @@ func (s *APIKeyService) snapshotFromAPIKey(apiKey *APIKey) *APIKeyAuthSnapshot {
 			SupportedModelScopes:            apiKey.Group.SupportedModelScopes,
 			AllowMessagesDispatch:           apiKey.Group.AllowMessagesDispatch,
 			DefaultMappedModel:              apiKey.Group.DefaultMappedModel,
+			ModelAliases:                    apiKey.Group.ModelAliases,
+			FallbackChain:                   apiKey.Group.FallbackChain,
+			SchedulingStrategy:              apiKey.Group.SchedulingStrategy,
+			TenantID:                        apiKey.Group.TenantID,
 		}
 	}
 	return snapshot
@@ -257,25 +291,51 @@ func (s *APIKeyService) snapshotToAPIKey(key string, snapshot *APIKeyAuthSnapsho
 		return nil
 	}
 	apiKey := &APIKey{
-		ID:          snapshot.APIKeyID,
-		UserID:      snapshot.UserID,
-		GroupID:     snapshot.GroupID,
-		Key:         key,
-		Status:      snapshot.Status,
-		IPWhitelist: snapshot.IPWhitelist,
-		IPBlacklist: snapshot.IPBlacklist,
-		Quota:       snapshot.Quota,
-		QuotaUsed:   snapshot.QuotaUsed,
-		ExpiresAt:   snapshot.ExpiresAt,
-		RateLimit5h: snapshot.RateLimit5h,
-		RateLimit1d: snapshot.RateLimit1d,
-		RateLimit7d: snapshot.RateLimit7d,
+		ID:                  snapshot.APIKeyID,
+		UserID:              snapshot.UserID,
+		GroupID:             snapshot.GroupID,
+		Key:                 key,
+		Status:              snapshot.Status,
+		IPWhitelist:         snapshot.IPWhitelist,
+		IPBlacklist:         snapshot.IPBlacklist,
+		Scopes:              snapshot.Scopes,
+		AllowedModels:       snapshot.AllowedModels,
+		Tags:                snapshot.Tags,
+		ClientRestriction:   snapshot.ClientRestriction,
+		Quota:               snapshot.Quota,
+		QuotaUsed:           snapshot.QuotaUsed,
+		ImageQuota:          snapshot.ImageQuota,
+		SuppressReasoning:   snapshot.SuppressReasoning,
+		ExpiresAt:           snapshot.ExpiresAt,
+		RateLimit5h:         snapshot.RateLimit5h,
+		RateLimit1d:         snapshot.RateLimit1d,
+		RateLimit7d:         snapshot.RateLimit7d,
+		RPMLimit:            snapshot.RPMLimit,
+		TPMLimit:            snapshot.TPMLimit,
+		DailyRequestLimit:   snapshot.DailyRequestLimit,
+		DailyTokenLimit:     snapshot.DailyTokenLimit,
+		MonthlyRequestLimit: snapshot.MonthlyRequestLimit,
+		MonthlyTokenLimit:   snapshot.MonthlyTokenLimit,
+		BudgetAmount:        snapshot.BudgetAmount,
+		BudgetPeriod:        snapshot.BudgetPeriod,
+		BudgetAction:        snapshot.BudgetAction,
+		BudgetFallbackModel: snapshot.BudgetFallbackModel,
+		BudgetPeriodStart:   snapshot.BudgetPeriodStart,
+		BudgetExceededAt:    snapshot.BudgetExceededAt,
+
+		DegradedFallbackModel: snapshot.DegradedFallbackModel,
+		SystemPrompt:          snapshot.SystemPrompt,
+		SystemPromptMode:      snapshot.SystemPromptMode,
+		ModerationMode:        snapshot.ModerationMode,
+		LogContent:            snapshot.LogContent,
+		Simulate:              snapshot.Simulate,
 		User: &User{
 			ID:          snapshot.User.ID,
 			Status:      snapshot.User.Status,
 			Role:        snapshot.User.Role,
 			Balance:     snapshot.User.Balance,
 			Concurrency: snapshot.User.Concurrency,
+			TenantID:    snapshot.User.TenantID,
 		},
 	}
 	if snapshot.Group != nil {
@@ -306,6 +366,10 @@ func (s *APIKeyService) snapshotToAPIKey(key string, snapshot *APIKeyAuthSnapsho
 			SupportedModelScopes:            snapshot.Group.SupportedModelScopes,
 			AllowMessagesDispatch:           snapshot.Group.AllowMessagesDispatch,
 			DefaultMappedModel:              snapshot.Group.DefaultMappedModel,
+			ModelAliases:                    snapshot.Group.ModelAliases,
+			FallbackChain:                   snapshot.Group.FallbackChain,
+			SchedulingStrategy:              snapshot.Group.SchedulingStrategy,
+			TenantID:                        snapshot.Group.TenantID,
 		}
 	}
 	s.compileAPIKeyIPRules(apiKey)
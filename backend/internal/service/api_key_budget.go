@@ -0,0 +1,191 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+
+	infraerrors "github.com/ShaohongDong/sub2api/internal/pkg/errors"
+	"github.com/ShaohongDong/sub2api/internal/pkg/timezone"
+)
+
+// API Key 花费预算重置周期（按业务时区的自然日/周/月划分）
+const (
+	APIKeyBudgetPeriodDay   = "day"
+	APIKeyBudgetPeriodWeek  = "week"
+	APIKeyBudgetPeriodMonth = "month"
+)
+
+// API Key 花费预算超限后的处理方式
+const (
+	// APIKeyBudgetActionDisable 拒绝请求，直到下个周期开始
+	APIKeyBudgetActionDisable = "disable"
+	// APIKeyBudgetActionDowngrade 将请求的模型替换为 BudgetFallbackModel
+	APIKeyBudgetActionDowngrade = "downgrade"
+)
+
+// APIKeyBudgetExceededEvent webhook 通知中的事件名
+const APIKeyBudgetExceededEvent = "api_key.budget_exceeded"
+
+var (
+	ErrInvalidAPIKeyBudget  = infraerrors.BadRequest("INVALID_API_KEY_BUDGET", "invalid api key budget")
+	ErrAPIKeyBudgetExceeded = infraerrors.TooManyRequests("API_KEY_BUDGET_EXCEEDED", "api key spend budget exceeded for the current period")
+)
+
+// APIKeyBudgetEvent 预算超限通知内容
+type APIKeyBudgetEvent struct {
+	Event         string    `json:"event"`
+	APIKeyID      int64     `json:"api_key_id"`
+	APIKeyName    string    `json:"api_key_name"`
+	UserID        int64     `json:"user_id"`
+	Budget        float64   `json:"budget"`
+	Used          float64   `json:"used"`
+	Period        string    `json:"period"`
+	PeriodStart   time.Time `json:"period_start"`
+	ResetAt       time.Time `json:"reset_at"`
+	Action        string    `json:"action"`
+	FallbackModel string    `json:"fallback_model,omitempty"`
+	ExceededAt    time.Time `json:"exceeded_at"`
+}
+
+// APIKeyBudgetNotifier 发送预算超限通知（如 webhook）
+type APIKeyBudgetNotifier interface {
+	NotifyBudgetExceeded(ctx context.Context, event *APIKeyBudgetEvent) error
+}
+
+// APIKeyBudgetPeriodStart 返回 now 所在预算周期的开始时间；未知周期按自然月处理
+func APIKeyBudgetPeriodStart(period string, now time.Time) time.Time {
+	switch period {
+	case APIKeyBudgetPeriodDay:
+		return timezone.StartOfDay(now)
+	case APIKeyBudgetPeriodWeek:
+		return timezone.StartOfWeek(now)
+	default:
+		return timezone.StartOfMonth(now)
+	}
+}
+
+// APIKeyBudgetResetAt 返回 now 所在预算周期的结束（即下个周期开始）时间
+func APIKeyBudgetResetAt(period string, now time.Time) time.Time {
+	start := APIKeyBudgetPeriodStart(period, now)
+	switch period {
+	case APIKeyBudgetPeriodDay:
+		return start.AddDate(0, 0, 1)
+	case APIKeyBudgetPeriodWeek:
+		return start.AddDate(0, 0, 7)
+	default:
+		return start.AddDate(0, 1, 0)
+	}
+}
+
+// normalizeAPIKeyBudget 补全默认周期与处理方式并校验预算配置；降级模型必须在 Key 的模型白名单内
+func normalizeAPIKeyBudget(apiKey *APIKey) error {
+	if apiKey.BudgetAmount < 0 {
+		return ErrInvalidAPIKeyBudget.WithMetadata(map[string]string{"reason": "budget must not be negative"})
+	}
+	apiKey.BudgetPeriod = strings.TrimSpace(apiKey.BudgetPeriod)
+	switch apiKey.BudgetPeriod {
+	case "":
+		apiKey.BudgetPeriod = APIKeyBudgetPeriodMonth
+	case APIKeyBudgetPeriodDay, APIKeyBudgetPeriodWeek, APIKeyBudgetPeriodMonth:
+	default:
+		return ErrInvalidAPIKeyBudget.WithMetadata(map[string]string{"period": apiKey.BudgetPeriod})
+	}
+	apiKey.BudgetAction = strings.TrimSpace(apiKey.BudgetAction)
+	switch apiKey.BudgetAction {
+	case "":
+		apiKey.BudgetAction = APIKeyBudgetActionDisable
+	case APIKeyBudgetActionDisable, APIKeyBudgetActionDowngrade:
+	default:
+		return ErrInvalidAPIKeyBudget.WithMetadata(map[string]string{"action": apiKey.BudgetAction})
+	}
+	apiKey.BudgetFallbackModel = strings.TrimSpace(apiKey.BudgetFallbackModel)
+	if apiKey.BudgetAction != APIKeyBudgetActionDowngrade {
+		return nil
+	}
+	model := apiKey.BudgetFallbackModel
+	if model == "" || strings.Contains(model, "*") || len(model) > 100 {
+		return ErrInvalidAPIKeyBudget.WithMetadata(map[string]string{"reason": "downgrade requires a concrete fallback model"})
+	}
+	if !apiKey.AllowsModel(model) {
+		return ErrInvalidAPIKeyBudget.WithMetadata(map[string]string{"reason": "fallback model is not in allowed_models", "model": model})
+	}
+	return nil
+}
+
+// SetBudgetNotifier 注入预算超限通知器；未注入时仅执行禁用/降级，不发送通知
+func (s *APIKeyService) SetBudgetNotifier(notifier APIKeyBudgetNotifier) {
+	s.budgetNotifier = notifier
+}
+
+// UpdateBudgetUsed 在请求完成后累加 Key 当前预算周期的花费。
+// 首次越过预算时记录超限时间、使认证缓存失效（后续请求立即被禁用或降级），并异步发送通知；
+// 同一周期内只通知一次。下个周期开始后超限标记自然失效，无需后台任务恢复。
+func (s *APIKeyService) UpdateBudgetUsed(ctx context.Context, apiKey *APIKey, cost float64) error {
+	if apiKey == nil || cost <= 0 || !apiKey.HasBudget() {
+		return nil
+	}
+	now := timezone.Now()
+	periodStart := APIKeyBudgetPeriodStart(apiKey.BudgetPeriod, now)
+	used, err := s.apiKeyRepo.IncrementBudgetUsed(ctx, apiKey.ID, cost, periodStart)
+	if err != nil {
+		return fmt.Errorf("increment budget used: %w", err)
+	}
+	if used < apiKey.BudgetAmount {
+		return nil
+	}
+	marked, err := s.apiKeyRepo.MarkBudgetExceeded(ctx, apiKey.ID, periodStart, now)
+	if err != nil {
+		return fmt.Errorf("mark budget exceeded: %w", err)
+	}
+	if !marked {
+		return nil
+	}
+
+	slog.Info("api_key.budget_exceeded",
+		"api_key_id", apiKey.ID,
+		"budget", apiKey.BudgetAmount,
+		"used", used,
+		"period", apiKey.BudgetPeriod,
+		"action", apiKey.BudgetAction)
+	if apiKey.Key != "" {
+		s.InvalidateAuthCacheByKey(ctx, apiKey.Key)
+	}
+	if s.budgetNotifier != nil {
+		// 认证快照不含 Key 名称，通知前补查一次（每个周期最多一次）
+		name := apiKey.Name
+		if name == "" {
+			if current, err := s.apiKeyRepo.GetByID(ctx, apiKey.ID); err == nil {
+				name = current.Name
+			}
+		}
+		event := &APIKeyBudgetEvent{
+			Event:       APIKeyBudgetExceededEvent,
+			APIKeyID:    apiKey.ID,
+			APIKeyName:  name,
+			UserID:      apiKey.UserID,
+			Budget:      apiKey.BudgetAmount,
+			Used:        used,
+			Period:      apiKey.BudgetPeriod,
+			PeriodStart: periodStart,
+			ResetAt:     APIKeyBudgetResetAt(apiKey.BudgetPeriod, now),
+			Action:      apiKey.BudgetAction,
+			ExceededAt:  now,
+		}
+		if apiKey.BudgetAction == APIKeyBudgetActionDowngrade {
+			event.FallbackModel = apiKey.BudgetFallbackModel
+		}
+		go s.notifyBudgetExceeded(event)
+	}
+	return nil
+}
+
+func (s *APIKeyService) notifyBudgetExceeded(event *APIKeyBudgetEvent) {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+	if err := s.budgetNotifier.NotifyBudgetExceeded(ctx, event); err != nil {
+		slog.Warn("api_key.budget_notify_failed", "api_key_id", event.APIKeyID, "error", err)
+	}
+}
@@ -0,0 +1,188 @@
+//go:build unit
+
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/ShaohongDong/sub2api/internal/config"
+	infraerrors "github.com/ShaohongDong/sub2api/internal/pkg/errors"
+	"github.com/ShaohongDong/sub2api/internal/pkg/timezone"
+	"github.com/stretchr/testify/require"
+)
+
+type budgetRepoStub struct {
+	APIKeyRepository
+
+	used        float64
+	periodStart time.Time
+	exceededAt  *time.Time
+	increments  int
+}
+
+func (s *budgetRepoStub) IncrementBudgetUsed(ctx context.Context, id int64, amount float64, periodStart time.Time) (float64, error) {
+	s.increments++
+	if s.periodStart.Before(periodStart) {
+		s.used = 0
+		s.exceededAt = nil
+		s.periodStart = periodStart
+	}
+	s.used += amount
+	return s.used, nil
+}
+
+func (s *budgetRepoStub) MarkBudgetExceeded(ctx context.Context, id int64, periodStart, exceededAt time.Time) (bool, error) {
+	if s.exceededAt != nil || !s.periodStart.Equal(periodStart) {
+		return false, nil
+	}
+	s.exceededAt = &exceededAt
+	return true, nil
+}
+
+func (s *budgetRepoStub) GetByID(ctx context.Context, id int64) (*APIKey, error) {
+	return &APIKey{ID: id, Name: "ci-key"}, nil
+}
+
+type budgetNotifierStub struct {
+	events chan *APIKeyBudgetEvent
+}
+
+func (s *budgetNotifierStub) NotifyBudgetExceeded(ctx context.Context, event *APIKeyBudgetEvent) error {
+	s.events <- event
+	return nil
+}
+
+func TestAPIKeyBudgetPeriodStartAndResetAt(t *testing.T) {
+	now := time.Date(2026, 3, 18, 15, 4, 5, 0, timezone.Location())
+
+	require.Equal(t, timezone.StartOfDay(now), APIKeyBudgetPeriodStart(APIKeyBudgetPeriodDay, now))
+	require.Equal(t, timezone.StartOfWeek(now), APIKeyBudgetPeriodStart(APIKeyBudgetPeriodWeek, now))
+	require.Equal(t, timezone.StartOfMonth(now), APIKeyBudgetPeriodStart(APIKeyBudgetPeriodMonth, now))
+	require.Equal(t, timezone.StartOfMonth(now), APIKeyBudgetPeriodStart("", now), "unknown period falls back to month")
+
+	require.Equal(t, timezone.StartOfDay(now).AddDate(0, 0, 1), APIKeyBudgetResetAt(APIKeyBudgetPeriodDay, now))
+	require.Equal(t, timezone.StartOfWeek(now).AddDate(0, 0, 7), APIKeyBudgetResetAt(APIKeyBudgetPeriodWeek, now))
+	require.Equal(t, timezone.StartOfMonth(now).AddDate(0, 1, 0), APIKeyBudgetResetAt(APIKeyBudgetPeriodMonth, now))
+}
+
+func TestNormalizeAPIKeyBudget(t *testing.T) {
+	key := &APIKey{BudgetAmount: 5}
+	require.NoError(t, normalizeAPIKeyBudget(key))
+	require.Equal(t, APIKeyBudgetPeriodMonth, key.BudgetPeriod)
+	require.Equal(t, APIKeyBudgetActionDisable, key.BudgetAction)
+
+	cases := []*APIKey{
+		{BudgetAmount: -1},
+		{BudgetPeriod: "year"},
+		{BudgetAction: "throttle"},
+		{BudgetAction: APIKeyBudgetActionDowngrade},
+		{BudgetAction: APIKeyBudgetActionDowngrade, BudgetFallbackModel: "claude-haiku-*"},
+		{BudgetAction: APIKeyBudgetActionDowngrade, BudgetFallbackModel: "gpt-4o-mini", AllowedModels: []string{"claude-*"}},
+	}
+	for _, tc := range cases {
+		err := normalizeAPIKeyBudget(tc)
+		require.ErrorIs(t, err, ErrInvalidAPIKeyBudget, "%+v", tc)
+		require.True(t, infraerrors.IsBadRequest(err))
+	}
+
+	key = &APIKey{BudgetAction: APIKeyBudgetActionDowngrade, BudgetFallbackModel: " claude-haiku-4-5 ", AllowedModels: []string{"claude-*"}}
+	require.NoError(t, normalizeAPIKeyBudget(key))
+	require.Equal(t, "claude-haiku-4-5", key.BudgetFallbackModel)
+}
+
+func TestAPIKey_IsBudgetExceededResetsWithPeriod(t *testing.T) {
+	now := time.Date(2026, 3, 18, 12, 0, 0, 0, timezone.Location())
+	thisMonth := timezone.StartOfMonth(now)
+	lastMonth := thisMonth.AddDate(0, -1, 0)
+	exceededAt := thisMonth.Add(time.Hour)
+
+	key := &APIKey{BudgetAmount: 10, BudgetPeriod: APIKeyBudgetPeriodMonth, BudgetUsed: 12, BudgetPeriodStart: &thisMonth, BudgetExceededAt: &exceededAt}
+	require.True(t, key.IsBudgetExceeded(now))
+	require.Equal(t, 12.0, key.EffectiveBudgetUsed(now))
+
+	key.BudgetPeriodStart = &lastMonth
+	require.False(t, key.IsBudgetExceeded(now), "a mark from the previous period no longer applies")
+	require.Zero(t, key.EffectiveBudgetUsed(now))
+
+	key.BudgetPeriodStart = &thisMonth
+	key.BudgetAmount = 0
+	require.False(t, key.IsBudgetExceeded(now), "removing the budget lifts the block")
+}
+
+func TestAPIKeyService_UpdateBudgetUsedMarksAndNotifiesOncePerPeriod(t *testing.T) {
+	repo := &budgetRepoStub{}
+	cache := &authCacheStub{}
+	notifier := &budgetNotifierStub{events: make(chan *APIKeyBudgetEvent, 4)}
+	svc := NewAPIKeyService(repo, nil, nil, nil, nil, cache, &config.Config{})
+	svc.SetBudgetNotifier(notifier)
+
+	key := &APIKey{ID: 7, UserID: 3, Key: "sk-budget", BudgetAmount: 1, BudgetPeriod: APIKeyBudgetPeriodDay, BudgetAction: APIKeyBudgetActionDisable}
+
+	require.NoError(t, svc.UpdateBudgetUsed(context.Background(), key, 0.6))
+	require.Nil(t, repo.exceededAt)
+	require.Empty(t, cache.deleteAuthKeys)
+
+	require.NoError(t, svc.UpdateBudgetUsed(context.Background(), key, 0.6))
+	require.NotNil(t, repo.exceededAt)
+	require.Equal(t, []string{svc.authCacheKey("sk-budget")}, cache.deleteAuthKeys)
+
+	select {
+	case event := <-notifier.events:
+		require.Equal(t, APIKeyBudgetExceededEvent, event.Event)
+		require.Equal(t, int64(7), event.APIKeyID)
+		require.Equal(t, "ci-key", event.APIKeyName)
+		require.Equal(t, int64(3), event.UserID)
+		require.InDelta(t, 1.2, event.Used, 1e-9)
+		require.Equal(t, APIKeyBudgetPeriodDay, event.Period)
+		require.Equal(t, repo.periodStart, event.PeriodStart)
+		require.Empty(t, event.FallbackModel)
+	case <-time.After(time.Second):
+		t.Fatal("expected budget notification")
+	}
+
+	// 同一周期内再次越过预算不再通知
+	require.NoError(t, svc.UpdateBudgetUsed(context.Background(), key, 0.5))
+	require.Len(t, cache.deleteAuthKeys, 1)
+	select {
+	case <-notifier.events:
+		t.Fatal("unexpected second notification in the same period")
+	case <-time.After(50 * time.Millisecond):
+	}
+}
+
+func TestAPIKeyService_UpdateBudgetUsedSkipsKeysWithoutBudget(t *testing.T) {
+	repo := &budgetRepoStub{}
+	svc := NewAPIKeyService(repo, nil, nil, nil, nil, nil, &config.Config{})
+
+	require.NoError(t, svc.UpdateBudgetUsed(context.Background(), &APIKey{ID: 1}, 5))
+	require.NoError(t, svc.UpdateBudgetUsed(context.Background(), &APIKey{ID: 1, BudgetAmount: 1}, 0))
+	require.Zero(t, repo.increments)
+}
+
+func TestAPIKeyService_BudgetFieldsSurviveAuthSnapshot(t *testing.T) {
+	svc := NewAPIKeyService(nil, nil, nil, nil, nil, nil, &config.Config{})
+	start := time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)
+	exceeded := start.Add(time.Hour)
+	key := &APIKey{
+		ID:                  9,
+		UserID:              1,
+		Status:              StatusActive,
+		User:                &User{ID: 1, Status: StatusActive},
+		BudgetAmount:        25,
+		BudgetPeriod:        APIKeyBudgetPeriodWeek,
+		BudgetAction:        APIKeyBudgetActionDowngrade,
+		BudgetFallbackModel: "gpt-4o-mini",
+		BudgetPeriodStart:   &start,
+		BudgetExceededAt:    &exceeded,
+	}
+
+	restored := svc.snapshotToAPIKey("sk-snap", svc.snapshotFromAPIKey(key))
+	require.Equal(t, key.BudgetAmount, restored.BudgetAmount)
+	require.Equal(t, key.BudgetPeriod, restored.BudgetPeriod)
+	require.Equal(t, key.BudgetAction, restored.BudgetAction)
+	require.Equal(t, key.BudgetFallbackModel, restored.BudgetFallbackModel)
+	require.Equal(t, key.BudgetPeriodStart, restored.BudgetPeriodStart)
+	require.Equal(t, key.BudgetExceededAt, restored.BudgetExceededAt)
+}
@@ -0,0 +1,64 @@
+package service
+
+import (
+	"strings"
+
+	"github.com/ShaohongDong/sub2api/internal/pkg/clientdetect"
+)
+
+// API Key 客户端限制（按 clientdetect 识别结果判定，空值表示不限制）
+const (
+	APIKeyClientRestrictionCodex       = "codex"        // 仅 Codex 官方客户端（CLI / IDE 插件 / 桌面端等）
+	APIKeyClientRestrictionClaudeCode  = "claude_code"  // 仅 Claude Code
+	APIKeyClientRestrictionCodingAgent = "coding_agent" // 任意已识别的编码 Agent（Codex / Claude Code / IDE Agent）
+	APIKeyClientRestrictionAPI         = "api"          // 仅普通 API 调用（SDK、脚本等非编码 Agent 客户端）
+)
+
+// APIKeyClientRestrictions 全部可用的客户端限制，供前端渲染选项
+var APIKeyClientRestrictions = []string{
+	APIKeyClientRestrictionCodex,
+	APIKeyClientRestrictionClaudeCode,
+	APIKeyClientRestrictionCodingAgent,
+	APIKeyClientRestrictionAPI,
+}
+
+// normalizeAPIKeyClientRestriction 去除空白并转小写；未知取值返回 ErrInvalidAPIKeyClientRestriction
+func normalizeAPIKeyClientRestriction(restriction string) (string, error) {
+	restriction = strings.ToLower(strings.TrimSpace(restriction))
+	if restriction == "" {
+		return "", nil
+	}
+	for _, known := range APIKeyClientRestrictions {
+		if restriction == known {
+			return restriction, nil
+		}
+	}
+	return "", ErrInvalidAPIKeyClientRestriction.WithMetadata(map[string]string{"client_restriction": restriction})
+}
+
+// AllowsClient 判断 Key 的客户端限制是否放行识别出的客户端；未配置限制时始终放行
+func (k *APIKey) AllowsClient(info clientdetect.ClientInfo) bool {
+	if k == nil {
+		return true
+	}
+	return ClientMatchesRestriction(k.ClientRestriction, info)
+}
+
+// ClientMatchesRestriction 判断识别出的客户端是否属于 restriction 指定的类型；空值匹配所有客户端
+func ClientMatchesRestriction(restriction string, info clientdetect.ClientInfo) bool {
+	switch restriction {
+	case "":
+		return true
+	case APIKeyClientRestrictionCodex:
+		return info.IsCodex()
+	case APIKeyClientRestrictionClaudeCode:
+		return info.IsClaudeCode()
+	case APIKeyClientRestrictionCodingAgent:
+		return info.IsCodingAgent()
+	case APIKeyClientRestrictionAPI:
+		return !info.IsCodingAgent()
+	default:
+		// 未知取值按最严格处理，避免配置错误时放开限制
+		return false
+	}
+}
@@ -0,0 +1,53 @@
+//go:build unit
+
+package service
+
+import (
+	"testing"
+
+	"github.com/ShaohongDong/sub2api/internal/pkg/clientdetect"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNormalizeAPIKeyClientRestriction(t *testing.T) {
+	restriction, err := normalizeAPIKeyClientRestriction(" Codex ")
+	require.NoError(t, err)
+	require.Equal(t, APIKeyClientRestrictionCodex, restriction)
+
+	restriction, err = normalizeAPIKeyClientRestriction("  ")
+	require.NoError(t, err)
+	require.Empty(t, restriction)
+
+	_, err = normalizeAPIKeyClientRestriction("codex_cli_rs")
+	require.ErrorIs(t, err, ErrInvalidAPIKeyClientRestriction)
+}
+
+func TestAPIKeyAllowsClient(t *testing.T) {
+	codex := clientdetect.ClientInfo{Type: clientdetect.TypeCodexCLI}
+	claude := clientdetect.ClientInfo{Type: clientdetect.TypeClaudeCode}
+	cursor := clientdetect.ClientInfo{Type: clientdetect.TypeCursor}
+	sdk := clientdetect.ClientInfo{Type: clientdetect.TypeOpenAISDK}
+	unknown := clientdetect.ClientInfo{Type: clientdetect.TypeUnknown}
+
+	cases := []struct {
+		restriction string
+		allowed     []clientdetect.ClientInfo
+		denied      []clientdetect.ClientInfo
+	}{
+		{"", []clientdetect.ClientInfo{codex, claude, cursor, sdk, unknown}, nil},
+		{APIKeyClientRestrictionCodex, []clientdetect.ClientInfo{codex}, []clientdetect.ClientInfo{claude, cursor, sdk, unknown}},
+		{APIKeyClientRestrictionClaudeCode, []clientdetect.ClientInfo{claude}, []clientdetect.ClientInfo{codex, cursor, sdk, unknown}},
+		{APIKeyClientRestrictionCodingAgent, []clientdetect.ClientInfo{codex, claude, cursor}, []clientdetect.ClientInfo{sdk, unknown}},
+		{APIKeyClientRestrictionAPI, []clientdetect.ClientInfo{sdk, unknown}, []clientdetect.ClientInfo{codex, claude, cursor}},
+		{"bogus", nil, []clientdetect.ClientInfo{codex, sdk, unknown}},
+	}
+	for _, tc := range cases {
+		key := &APIKey{ClientRestriction: tc.restriction}
+		for _, info := range tc.allowed {
+			require.True(t, key.AllowsClient(info), "%s should allow %s", tc.restriction, info.Type)
+		}
+		for _, info := range tc.denied {
+			require.False(t, key.AllowsClient(info), "%s should deny %s", tc.restriction, info.Type)
+		}
+	}
+}
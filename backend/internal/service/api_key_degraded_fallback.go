@@ -0,0 +1,33 @@
+package service
+
+import "strings"
+
+// normalizeAPIKeyDegradedFallback 规范化降级模型：去除空白，必须是具体模型（不含通配符）且在 allowed_models 内
+func normalizeAPIKeyDegradedFallback(apiKey *APIKey) error {
+	apiKey.DegradedFallbackModel = strings.TrimSpace(apiKey.DegradedFallbackModel)
+	model := apiKey.DegradedFallbackModel
+	if model == "" {
+		return nil
+	}
+	if strings.Contains(model, "*") || len(model) > 100 {
+		return ErrInvalidAPIKeyDegradedFallback.WithMetadata(map[string]string{"reason": "fallback model must be a concrete model name"})
+	}
+	if !apiKey.AllowsModel(model) {
+		return ErrInvalidAPIKeyDegradedFallback.WithMetadata(map[string]string{"reason": "fallback model is not in allowed_models", "model": model})
+	}
+	return nil
+}
+
+// DegradedFallbackFor 返回请求模型在所有账号不可用时应改用的降级模型；未配置或与请求模型相同时返回 false
+func (k *APIKey) DegradedFallbackFor(requested string) (string, bool) {
+	if k == nil || k.DegradedFallbackModel == "" || k.DegradedFallbackModel == requested {
+		return "", false
+	}
+	return k.DegradedFallbackModel, true
+}
+
+// IsNoAvailableAccountsError 判断错误响应是否表示当前模型没有可调度的账号（全部故障、冷却或限流中），
+// 与运维错误日志的归类规则一致：按错误信息中的 "no available accounts" 识别。
+func IsNoAvailableAccountsError(status int, body []byte) bool {
+	return status == 503 && strings.Contains(strings.ToLower(string(body)), "no available accounts")
+}
@@ -0,0 +1,42 @@
+//go:build unit
+
+package service
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestNormalizeAPIKeyDegradedFallback(t *testing.T) {
+	key := &APIKey{DegradedFallbackModel: " claude-haiku-4-5 "}
+	require.NoError(t, normalizeAPIKeyDegradedFallback(key))
+	require.Equal(t, "claude-haiku-4-5", key.DegradedFallbackModel)
+
+	key = &APIKey{DegradedFallbackModel: "claude-*"}
+	require.ErrorIs(t, normalizeAPIKeyDegradedFallback(key), ErrInvalidAPIKeyDegradedFallback)
+
+	key = &APIKey{AllowedModels: []string{"claude-opus-*"}, DegradedFallbackModel: "claude-haiku-4-5"}
+	require.ErrorIs(t, normalizeAPIKeyDegradedFallback(key), ErrInvalidAPIKeyDegradedFallback)
+
+	key = &APIKey{AllowedModels: []string{"claude-*"}, DegradedFallbackModel: ""}
+	require.NoError(t, normalizeAPIKeyDegradedFallback(key))
+}
+
+func TestAPIKeyDegradedFallbackFor(t *testing.T) {
+	key := &APIKey{DegradedFallbackModel: "claude-haiku-4-5"}
+	model, ok := key.DegradedFallbackFor("claude-opus-4-5")
+	require.True(t, ok)
+	require.Equal(t, "claude-haiku-4-5", model)
+
+	_, ok = key.DegradedFallbackFor("claude-haiku-4-5")
+	require.False(t, ok)
+	_, ok = (&APIKey{}).DegradedFallbackFor("claude-opus-4-5")
+	require.False(t, ok)
+}
+
+func TestIsNoAvailableAccountsError(t *testing.T) {
+	require.True(t, IsNoAvailableAccountsError(503, []byte(`{"error":{"message":"No available accounts: no available accounts supporting model: x"}}`)))
+	require.False(t, IsNoAvailableAccountsError(502, []byte(`{"error":{"message":"No available accounts"}}`)))
+	require.False(t, IsNoAvailableAccountsError(503, []byte(`{"error":{"message":"Upstream service temporarily unavailable"}}`)))
+}
@@ -0,0 +1,65 @@
+package service
+
+import (
+	"slices"
+	"strings"
+)
+
+// maxAPIKeyAllowedModels 单个 Key 模型白名单的最大条目数
+const maxAPIKeyAllowedModels = 100
+
+// normalizeAPIKeyAllowedModels 去除空白并去重；仅允许末尾 * 通配符，条目过多或格式不合法时返回错误
+func normalizeAPIKeyAllowedModels(models []string) ([]string, error) {
+	if len(models) == 0 {
+		return nil, nil
+	}
+	out := make([]string, 0, len(models))
+	for _, model := range models {
+		model = strings.TrimSpace(model)
+		if model == "" {
+			continue
+		}
+		if strings.Contains(strings.TrimSuffix(model, "*"), "*") {
+			return nil, ErrInvalidAPIKeyAllowedModels.WithMetadata(map[string]string{"model": model})
+		}
+		if !slices.Contains(out, model) {
+			out = append(out, model)
+		}
+	}
+	if len(out) > maxAPIKeyAllowedModels {
+		return nil, ErrInvalidAPIKeyAllowedModels.WithMetadata(map[string]string{"reason": "too many models"})
+	}
+	if len(out) == 0 {
+		return nil, nil
+	}
+	return out, nil
+}
+
+// AllowsModel 判断 Key 是否可使用指定模型；未配置白名单时始终放行。
+// 兼容 Gemini 风格的 "models/" 前缀。
+func (k *APIKey) AllowsModel(model string) bool {
+	if k == nil || len(k.AllowedModels) == 0 {
+		return true
+	}
+	model = strings.TrimPrefix(strings.TrimSpace(model), "models/")
+	for _, pattern := range k.AllowedModels {
+		if matchModelPattern(pattern, model) {
+			return true
+		}
+	}
+	return false
+}
+
+// FilterAllowedModels 按 Key 的模型白名单过滤模型 ID 列表；未配置白名单时原样返回
+func (k *APIKey) FilterAllowedModels(models []string) []string {
+	if k == nil || len(k.AllowedModels) == 0 {
+		return models
+	}
+	out := make([]string, 0, len(models))
+	for _, model := range models {
+		if k.AllowsModel(model) {
+			out = append(out, model)
+		}
+	}
+	return out
+}
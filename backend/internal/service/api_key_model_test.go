@@ -0,0 +1,36 @@
+//go:build unit
+
+package service
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestNormalizeAPIKeyAllowedModels(t *testing.T) {
+	models, err := normalizeAPIKeyAllowedModels([]string{" claude-haiku-* ", "", "gpt-5-mini", "claude-haiku-*"})
+	require.NoError(t, err)
+	require.Equal(t, []string{"claude-haiku-*", "gpt-5-mini"}, models)
+
+	models, err = normalizeAPIKeyAllowedModels([]string{" ", ""})
+	require.NoError(t, err)
+	require.Nil(t, models)
+
+	_, err = normalizeAPIKeyAllowedModels([]string{"claude-*-haiku"})
+	require.ErrorIs(t, err, ErrInvalidAPIKeyAllowedModels)
+}
+
+func TestAPIKeyAllowsModel(t *testing.T) {
+	var nilKey *APIKey
+	require.True(t, nilKey.AllowsModel("claude-opus-4-1"))
+	require.True(t, (&APIKey{}).AllowsModel("claude-opus-4-1"))
+
+	k := &APIKey{AllowedModels: []string{"claude-haiku-*", "gemini-2.5-flash"}}
+	require.True(t, k.AllowsModel("claude-haiku-4-5-20251001"))
+	require.True(t, k.AllowsModel("models/gemini-2.5-flash"))
+	require.False(t, k.AllowsModel("claude-opus-4-1"))
+	require.False(t, k.AllowsModel("gemini-2.5-pro"))
+
+	require.Equal(t, []string{"claude-haiku-4-5"}, k.FilterAllowedModels([]string{"claude-opus-4-1", "claude-haiku-4-5"}))
+}
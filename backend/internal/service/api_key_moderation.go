@@ -0,0 +1,82 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/ShaohongDong/sub2api/internal/config"
+)
+
+// Key 级提示词审核前置过滤方式
+const (
+	APIKeyModerationOff   = "off"   // 不审核（即使开启了 gateway.moderation.pre_filter）
+	APIKeyModerationFlag  = "flag"  // 审核但仅标记：记录日志并在响应头标注命中类别，不拒绝
+	APIKeyModerationBlock = "block" // 审核并按 gateway.moderation 的类别配置拒绝或标记
+)
+
+// normalizeAPIKeyModerationMode 规范化 Key 级审核方式：小写并校验取值，空值表示沿用全局配置
+func normalizeAPIKeyModerationMode(apiKey *APIKey) error {
+	apiKey.ModerationMode = strings.ToLower(strings.TrimSpace(apiKey.ModerationMode))
+	switch apiKey.ModerationMode {
+	case "", APIKeyModerationOff, APIKeyModerationFlag, APIKeyModerationBlock:
+		return nil
+	default:
+		return ErrInvalidAPIKeyModerationMode
+	}
+}
+
+// SetModerationMode 设置 Key 级提示词审核方式（仅管理员可设置）；mode 为空表示沿用 gateway.moderation.pre_filter
+func (s *APIKeyService) SetModerationMode(ctx context.Context, id int64, mode string) (*APIKey, error) {
+	apiKey, err := s.apiKeyRepo.GetByID(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("get api key: %w", err)
+	}
+	apiKey.ModerationMode = mode
+	if err := normalizeAPIKeyModerationMode(apiKey); err != nil {
+		return nil, err
+	}
+	if err := s.apiKeyRepo.Update(ctx, apiKey); err != nil {
+		return nil, fmt.Errorf("update api key: %w", err)
+	}
+
+	s.InvalidateAuthCacheByKey(ctx, apiKey.Key)
+	s.compileAPIKeyIPRules(apiKey)
+	return apiKey, nil
+}
+
+// ResolveAPIKeyModeration 返回请求应采用的审核方式：Key 级设置优先，未设置时开启 gateway.moderation.pre_filter 即为 block
+func ResolveAPIKeyModeration(cfg *config.Config, apiKey *APIKey) string {
+	if apiKey != nil && apiKey.ModerationMode != "" {
+		return apiKey.ModerationMode
+	}
+	if cfg != nil && cfg.Gateway.Moderation.PreFilter {
+		return APIKeyModerationBlock
+	}
+	return APIKeyModerationOff
+}
+
+// SplitModerationCategories 按 gateway.moderation 的类别配置把命中的类别分为拒绝与仅标记两组：
+// flag_categories 中的类别仅标记；block_categories 为空时其余类别均拒绝，否则仅拒绝其中列出的类别，未列出的类别忽略。
+// mode 为 flag 时所有命中类别均仅标记。
+func SplitModerationCategories(cfg config.GatewayModerationConfig, mode string, categories []string) (blocked, flagged []string) {
+	for _, category := range categories {
+		switch {
+		case mode == APIKeyModerationFlag || moderationCategoryListed(cfg.FlagCategories, category):
+			flagged = append(flagged, category)
+		case len(cfg.BlockCategories) == 0 || moderationCategoryListed(cfg.BlockCategories, category):
+			blocked = append(blocked, category)
+		}
+	}
+	return blocked, flagged
+}
+
+// moderationCategoryListed 类别是否在列表中；列表项 "violence" 同时匹配其子类别 "violence/graphic"
+func moderationCategoryListed(list []string, category string) bool {
+	for _, item := range list {
+		if item == category || strings.HasPrefix(category, item+"/") {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,55 @@
+//go:build unit
+
+package service
+
+import (
+	"testing"
+
+	"github.com/ShaohongDong/sub2api/internal/config"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNormalizeAPIKeyModerationMode(t *testing.T) {
+	key := &APIKey{ModerationMode: " Flag "}
+	require.NoError(t, normalizeAPIKeyModerationMode(key))
+	require.Equal(t, APIKeyModerationFlag, key.ModerationMode)
+
+	key = &APIKey{ModerationMode: "strict"}
+	require.ErrorIs(t, normalizeAPIKeyModerationMode(key), ErrInvalidAPIKeyModerationMode)
+}
+
+func TestResolveAPIKeyModeration(t *testing.T) {
+	cfg := &config.Config{}
+	require.Equal(t, APIKeyModerationOff, ResolveAPIKeyModeration(cfg, &APIKey{}))
+	require.Equal(t, APIKeyModerationFlag, ResolveAPIKeyModeration(cfg, &APIKey{ModerationMode: APIKeyModerationFlag}))
+
+	cfg.Gateway.Moderation.PreFilter = true
+	require.Equal(t, APIKeyModerationBlock, ResolveAPIKeyModeration(cfg, &APIKey{}))
+	require.Equal(t, APIKeyModerationBlock, ResolveAPIKeyModeration(cfg, nil))
+	require.Equal(t, APIKeyModerationOff, ResolveAPIKeyModeration(cfg, &APIKey{ModerationMode: APIKeyModerationOff}))
+}
+
+func TestSplitModerationCategories(t *testing.T) {
+	categories := []string{"harassment", "sexual/minors", "violence/graphic"}
+
+	// 未配置类别时全部拒绝
+	blocked, flagged := SplitModerationCategories(config.GatewayModerationConfig{}, APIKeyModerationBlock, categories)
+	require.Equal(t, categories, blocked)
+	require.Empty(t, flagged)
+
+	cfg := config.GatewayModerationConfig{FlagCategories: []string{"violence"}}
+	blocked, flagged = SplitModerationCategories(cfg, APIKeyModerationBlock, categories)
+	require.Equal(t, []string{"harassment", "sexual/minors"}, blocked)
+	require.Equal(t, []string{"violence/graphic"}, flagged)
+
+	// 配置 block_categories 后未列出的类别忽略
+	cfg.BlockCategories = []string{"sexual/minors"}
+	blocked, flagged = SplitModerationCategories(cfg, APIKeyModerationBlock, categories)
+	require.Equal(t, []string{"sexual/minors"}, blocked)
+	require.Equal(t, []string{"violence/graphic"}, flagged)
+
+	// flag 模式从不拒绝
+	blocked, flagged = SplitModerationCategories(cfg, APIKeyModerationFlag, categories)
+	require.Empty(t, blocked)
+	require.Equal(t, categories, flagged)
+}
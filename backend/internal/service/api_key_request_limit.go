@@ -0,0 +1,228 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strconv"
+	"time"
+
+	"github.com/ShaohongDong/sub2api/internal/pkg/timezone"
+)
+
+// API Key 请求/token 限制窗口
+const (
+	APIKeyLimitWindowMinute = "minute"
+	APIKeyLimitWindowDay    = "day"
+	APIKeyLimitWindowMonth  = "month"
+)
+
+// API Key 请求/token 限制维度
+const (
+	APIKeyLimitDimensionRequests = "requests"
+	APIKeyLimitDimensionTokens   = "tokens"
+)
+
+// APIKeyLimitBucket 单个限制窗口的计数桶：Bucket 标识当前窗口（如当前分钟、当天、当月），
+// 窗口滚动后自然切换到新桶，旧桶由 TTL 回收
+type APIKeyLimitBucket struct {
+	Window       string
+	Bucket       string
+	TTL          time.Duration
+	RequestLimit int64 // 0 = 不限制
+	TokenLimit   int64 // 0 = 不限制
+	ResetAt      time.Time
+}
+
+// APIKeyLimitUsage 单个窗口在本次请求计入前的已用请求数与 token 数
+type APIKeyLimitUsage struct {
+	Requests int64
+	Tokens   int64
+}
+
+// APIKeyRequestLimitCache 按 API Key 统计各窗口的请求数与 token 数（Redis）
+type APIKeyRequestLimitCache interface {
+	// AcquireRequest 原子地检查所有桶是否已达上限，均未超限时为每个桶的请求数 +1。
+	// 返回超限桶的下标与维度；未超限时返回 -1，并按桶顺序返回计入前的用量（实现不支持时可为 nil）。
+	AcquireRequest(ctx context.Context, apiKeyID int64, buckets []APIKeyLimitBucket) (hitIndex int, dimension string, usage []APIKeyLimitUsage, err error)
+	// AddTokens 累加请求完成后的 token 用量
+	AddTokens(ctx context.Context, apiKeyID int64, buckets []APIKeyLimitBucket, tokens int64) error
+}
+
+// APIKeyRequestLimitError 表示 API Key 的请求/token 限制已触发
+type APIKeyRequestLimitError struct {
+	Window     string
+	Dimension  string
+	Limit      int64
+	RetryAfter time.Duration
+}
+
+func (e *APIKeyRequestLimitError) Error() string {
+	return fmt.Sprintf("API key %s limit reached: %d %s per %s, retry after %s",
+		e.Window, e.Limit, e.Dimension, e.Window, e.RetryAfter.Round(time.Second))
+}
+
+// RetryAfterSeconds 返回 Retry-After 头的秒数（至少 1 秒）
+func (e *APIKeyRequestLimitError) RetryAfterSeconds() string {
+	secs := int64(e.RetryAfter / time.Second)
+	if e.RetryAfter%time.Second != 0 {
+		secs++
+	}
+	if secs < 1 {
+		secs = 1
+	}
+	return strconv.FormatInt(secs, 10)
+}
+
+// APIKeyRateLimitStatus 本次请求计入后 Key 最紧的请求窗口与 token 窗口状态，
+// 用于向客户端输出 x-ratelimit-* 标准响应头，便于 SDK 自行降速。Limit 为 0 表示该维度不限制。
+type APIKeyRateLimitStatus struct {
+	RequestLimit      int64
+	RemainingRequests int64
+	RequestsReset     time.Duration
+	TokenLimit        int64
+	RemainingTokens   int64
+	TokensReset       time.Duration
+}
+
+// RetryAfter 任一维度已耗尽时返回到该窗口重置的时长，否则返回 0
+func (s *APIKeyRateLimitStatus) RetryAfter() time.Duration {
+	if s == nil {
+		return 0
+	}
+	var retry time.Duration
+	if s.RequestLimit > 0 && s.RemainingRequests <= 0 {
+		retry = s.RequestsReset
+	}
+	if s.TokenLimit > 0 && s.RemainingTokens <= 0 && s.TokensReset > retry {
+		retry = s.TokensReset
+	}
+	return retry
+}
+
+// apiKeyRateLimitStatus 由各窗口计入前的用量计算剩余额度，每个维度取剩余最少的窗口
+func apiKeyRateLimitStatus(buckets []APIKeyLimitBucket, usage []APIKeyLimitUsage, now time.Time) *APIKeyRateLimitStatus {
+	if len(usage) != len(buckets) {
+		return nil
+	}
+	status := &APIKeyRateLimitStatus{}
+	requestsSet, tokensSet := false, false
+	for i, b := range buckets {
+		reset := b.ResetAt.Sub(now)
+		if b.RequestLimit > 0 {
+			remaining := b.RequestLimit - usage[i].Requests - 1
+			if remaining < 0 {
+				remaining = 0
+			}
+			if !requestsSet || remaining < status.RemainingRequests {
+				status.RequestLimit, status.RemainingRequests, status.RequestsReset = b.RequestLimit, remaining, reset
+				requestsSet = true
+			}
+		}
+		if b.TokenLimit > 0 {
+			remaining := b.TokenLimit - usage[i].Tokens
+			if remaining < 0 {
+				remaining = 0
+			}
+			if !tokensSet || remaining < status.RemainingTokens {
+				status.TokenLimit, status.RemainingTokens, status.TokensReset = b.TokenLimit, remaining, reset
+				tokensSet = true
+			}
+		}
+	}
+	return status
+}
+
+// apiKeyLimitBuckets 计算 Key 在 now 时刻需要参与计数的桶；仅包含设置了请求或 token 上限的窗口。
+// 日/月窗口按业务时区的自然日、自然月划分。
+func apiKeyLimitBuckets(apiKey *APIKey, now time.Time) []APIKeyLimitBucket {
+	buckets := make([]APIKeyLimitBucket, 0, 3)
+	if apiKey.RPMLimit > 0 || apiKey.TPMLimit > 0 {
+		minute := now.Unix() / 60
+		buckets = append(buckets, APIKeyLimitBucket{
+			Window:       APIKeyLimitWindowMinute,
+			Bucket:       "m" + strconv.FormatInt(minute, 10),
+			TTL:          2 * time.Minute,
+			RequestLimit: int64(apiKey.RPMLimit),
+			TokenLimit:   int64(apiKey.TPMLimit),
+			ResetAt:      time.Unix((minute+1)*60, 0),
+		})
+	}
+	if apiKey.DailyRequestLimit > 0 || apiKey.DailyTokenLimit > 0 {
+		buckets = append(buckets, APIKeyLimitBucket{
+			Window:       APIKeyLimitWindowDay,
+			Bucket:       "d" + now.Format("20060102"),
+			TTL:          48 * time.Hour,
+			RequestLimit: int64(apiKey.DailyRequestLimit),
+			TokenLimit:   apiKey.DailyTokenLimit,
+			ResetAt:      timezone.StartOfDay(now.AddDate(0, 0, 1)),
+		})
+	}
+	if apiKey.MonthlyRequestLimit > 0 || apiKey.MonthlyTokenLimit > 0 {
+		buckets = append(buckets, APIKeyLimitBucket{
+			Window:       APIKeyLimitWindowMonth,
+			Bucket:       "M" + now.Format("200601"),
+			TTL:          33 * 24 * time.Hour,
+			RequestLimit: int64(apiKey.MonthlyRequestLimit),
+			TokenLimit:   apiKey.MonthlyTokenLimit,
+			ResetAt:      timezone.StartOfMonth(now).AddDate(0, 1, 0),
+		})
+	}
+	return buckets
+}
+
+// SetRequestLimitCache 注入请求/token 限制计数缓存；未注入时不执行 RPM/TPM 与日/月配额限制
+func (s *APIKeyService) SetRequestLimitCache(cache APIKeyRequestLimitCache) {
+	s.requestLimitCache = cache
+}
+
+// CheckRequestLimits 检查 Key 的 RPM/TPM 与日/月请求、token 配额，未超限时计入本次请求并返回计入后的额度状态。
+// 超限时返回 *APIKeyRequestLimitError；计数缓存不可用时放行（fail-open），避免 Redis 故障阻断全部流量。
+// TPM 与 token 配额在请求完成后才累加，因此以"已用量达到上限"作为拒绝条件，单个请求可能略微超出。
+// 未配置限制或计数缓存不可用时状态为 nil。
+func (s *APIKeyService) CheckRequestLimits(ctx context.Context, apiKey *APIKey) (*APIKeyRateLimitStatus, error) {
+	if s == nil || s.requestLimitCache == nil || apiKey == nil || !apiKey.HasRequestLimits() {
+		return nil, nil
+	}
+	now := timezone.Now()
+	buckets := apiKeyLimitBuckets(apiKey, now)
+	hit, dimension, usage, err := s.requestLimitCache.AcquireRequest(ctx, apiKey.ID, buckets)
+	if err != nil {
+		slog.Warn("api_key.request_limit_check_failed", "api_key_id", apiKey.ID, "error", err)
+		return nil, nil
+	}
+	if hit < 0 || hit >= len(buckets) {
+		return apiKeyRateLimitStatus(buckets, usage, now), nil
+	}
+	bucket := buckets[hit]
+	limit := bucket.RequestLimit
+	if dimension == APIKeyLimitDimensionTokens {
+		limit = bucket.TokenLimit
+	}
+	return nil, &APIKeyRequestLimitError{
+		Window:     bucket.Window,
+		Dimension:  dimension,
+		Limit:      limit,
+		RetryAfter: bucket.ResetAt.Sub(now),
+	}
+}
+
+// RecordTokenUsage 在请求完成后累加 token 用量（用于 TPM 与日/月 token 配额）
+func (s *APIKeyService) RecordTokenUsage(ctx context.Context, apiKey *APIKey, tokens int) {
+	if s == nil || s.requestLimitCache == nil || apiKey == nil || tokens <= 0 {
+		return
+	}
+	if apiKey.TPMLimit <= 0 && apiKey.DailyTokenLimit <= 0 && apiKey.MonthlyTokenLimit <= 0 {
+		return
+	}
+	buckets := apiKeyLimitBuckets(apiKey, timezone.Now())
+	tokenBuckets := buckets[:0]
+	for _, b := range buckets {
+		if b.TokenLimit > 0 {
+			tokenBuckets = append(tokenBuckets, b)
+		}
+	}
+	if err := s.requestLimitCache.AddTokens(ctx, apiKey.ID, tokenBuckets, int64(tokens)); err != nil {
+		slog.Warn("api_key.record_token_usage_failed", "api_key_id", apiKey.ID, "tokens", tokens, "error", err)
+	}
+}
@@ -0,0 +1,123 @@
+//go:build unit
+
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+type apiKeyRequestLimitCacheStub struct {
+	hit       int
+	dimension string
+	usage     []APIKeyLimitUsage
+	err       error
+
+	acquired    []APIKeyLimitBucket
+	tokenBucket []APIKeyLimitBucket
+	tokens      int64
+}
+
+func (s *apiKeyRequestLimitCacheStub) AcquireRequest(ctx context.Context, apiKeyID int64, buckets []APIKeyLimitBucket) (int, string, []APIKeyLimitUsage, error) {
+	s.acquired = buckets
+	return s.hit, s.dimension, s.usage, s.err
+}
+
+func (s *apiKeyRequestLimitCacheStub) AddTokens(ctx context.Context, apiKeyID int64, buckets []APIKeyLimitBucket, tokens int64) error {
+	s.tokenBucket = buckets
+	s.tokens += tokens
+	return nil
+}
+
+func TestAPIKeyLimitBuckets(t *testing.T) {
+	now := time.Date(2026, 3, 31, 23, 59, 30, 0, time.UTC)
+
+	require.Empty(t, apiKeyLimitBuckets(&APIKey{}, now))
+
+	buckets := apiKeyLimitBuckets(&APIKey{
+		RPMLimit:          60,
+		DailyTokenLimit:   1000,
+		MonthlyTokenLimit: 50000,
+	}, now)
+	require.Len(t, buckets, 3)
+
+	require.Equal(t, APIKeyLimitWindowMinute, buckets[0].Window)
+	require.Equal(t, int64(60), buckets[0].RequestLimit)
+	require.Zero(t, buckets[0].TokenLimit)
+	require.Equal(t, 30*time.Second, buckets[0].ResetAt.Sub(now))
+
+	require.Equal(t, APIKeyLimitWindowDay, buckets[1].Window)
+	require.Equal(t, "d20260331", buckets[1].Bucket)
+	require.Equal(t, int64(1000), buckets[1].TokenLimit)
+
+	require.Equal(t, APIKeyLimitWindowMonth, buckets[2].Window)
+	require.Equal(t, "M202603", buckets[2].Bucket)
+	require.True(t, buckets[2].ResetAt.After(now))
+}
+
+func TestAPIKeyService_CheckRequestLimits(t *testing.T) {
+	apiKey := &APIKey{ID: 1, RPMLimit: 10, DailyTokenLimit: 500}
+
+	t.Run("allowed", func(t *testing.T) {
+		cache := &apiKeyRequestLimitCacheStub{hit: -1, usage: []APIKeyLimitUsage{{Requests: 3}, {Requests: 40, Tokens: 500}}}
+		svc := &APIKeyService{requestLimitCache: cache}
+		status, err := svc.CheckRequestLimits(context.Background(), apiKey)
+		require.NoError(t, err)
+		require.Len(t, cache.acquired, 2)
+		require.Equal(t, int64(10), status.RequestLimit)
+		require.Equal(t, int64(6), status.RemainingRequests)
+		require.Equal(t, int64(500), status.TokenLimit)
+		require.Zero(t, status.RemainingTokens)
+		require.Equal(t, status.TokensReset, status.RetryAfter())
+	})
+
+	t.Run("token limit hit", func(t *testing.T) {
+		cache := &apiKeyRequestLimitCacheStub{hit: 1, dimension: APIKeyLimitDimensionTokens}
+		svc := &APIKeyService{requestLimitCache: cache}
+		_, err := svc.CheckRequestLimits(context.Background(), apiKey)
+
+		var limitErr *APIKeyRequestLimitError
+		require.ErrorAs(t, err, &limitErr)
+		require.Equal(t, APIKeyLimitWindowDay, limitErr.Window)
+		require.Equal(t, APIKeyLimitDimensionTokens, limitErr.Dimension)
+		require.Equal(t, int64(500), limitErr.Limit)
+		require.Positive(t, limitErr.RetryAfter)
+	})
+
+	t.Run("cache error fails open", func(t *testing.T) {
+		svc := &APIKeyService{requestLimitCache: &apiKeyRequestLimitCacheStub{err: errors.New("redis down")}}
+		status, err := svc.CheckRequestLimits(context.Background(), apiKey)
+		require.NoError(t, err)
+		require.Nil(t, status)
+	})
+
+	t.Run("no limits skips cache", func(t *testing.T) {
+		cache := &apiKeyRequestLimitCacheStub{hit: 0, dimension: APIKeyLimitDimensionRequests}
+		svc := &APIKeyService{requestLimitCache: cache}
+		status, err := svc.CheckRequestLimits(context.Background(), &APIKey{ID: 2})
+		require.NoError(t, err)
+		require.Nil(t, status)
+		require.Nil(t, cache.acquired)
+	})
+}
+
+func TestAPIKeyService_RecordTokenUsageOnlyTokenWindows(t *testing.T) {
+	cache := &apiKeyRequestLimitCacheStub{}
+	svc := &APIKeyService{requestLimitCache: cache}
+
+	svc.RecordTokenUsage(context.Background(), &APIKey{ID: 1, RPMLimit: 10, MonthlyTokenLimit: 1000}, 42)
+
+	require.Equal(t, int64(42), cache.tokens)
+	require.Len(t, cache.tokenBucket, 1)
+	require.Equal(t, APIKeyLimitWindowMonth, cache.tokenBucket[0].Window)
+}
+
+func TestAPIKeyRequestLimitError_RetryAfterSeconds(t *testing.T) {
+	require.Equal(t, "1", (&APIKeyRequestLimitError{RetryAfter: 0}).RetryAfterSeconds())
+	require.Equal(t, "2", (&APIKeyRequestLimitError{RetryAfter: 1500 * time.Millisecond}).RetryAfterSeconds())
+	require.Equal(t, "60", (&APIKeyRequestLimitError{RetryAfter: time.Minute}).RetryAfterSeconds())
+}
@@ -0,0 +1,64 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	infraerrors "github.com/ShaohongDong/sub2api/internal/pkg/errors"
+)
+
+// MaxAPIKeyRotationGrace 轮换后旧密钥宽限期的上限
+const MaxAPIKeyRotationGrace = 7 * 24 * time.Hour
+
+var ErrInvalidAPIKeyRotationGrace = infraerrors.BadRequest("INVALID_API_KEY_ROTATION_GRACE", "grace period must be between 0 and 7 days")
+
+// isGraceKey 判断认证时提交的 key 是否为轮换前的旧密钥
+func (k *APIKey) isGraceKey(key string) bool {
+	return k.PreviousKey != nil && *k.PreviousKey == key
+}
+
+// Rotate 为 API Key 签发新密钥。密钥记录本身（ID、配额、白名单、限额、用量与使用记录）保持不变。
+// grace > 0 时旧密钥在宽限期内仍可认证，便于客户端平滑切换；grace 为 0 时旧密钥立即失效。
+// 再次轮换会覆盖上一次的宽限密钥。
+func (s *APIKeyService) Rotate(ctx context.Context, id int64, grace time.Duration) (*APIKey, error) {
+	if grace < 0 || grace > MaxAPIKeyRotationGrace {
+		return nil, ErrInvalidAPIKeyRotationGrace
+	}
+
+	apiKey, err := s.apiKeyRepo.GetByID(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("get api key: %w", err)
+	}
+
+	newKey, err := s.GenerateKey()
+	if err != nil {
+		return nil, fmt.Errorf("generate key: %w", err)
+	}
+
+	oldKey := apiKey.Key
+	var graceUntil *time.Time
+	if grace > 0 {
+		until := time.Now().Add(grace)
+		graceUntil = &until
+	}
+	if err := s.apiKeyRepo.RotateKey(ctx, id, oldKey, newKey, graceUntil); err != nil {
+		return nil, fmt.Errorf("rotate api key: %w", err)
+	}
+
+	// 旧密钥的缓存快照来自轮换前，需清除后按宽限期规则重新认证；上一轮的宽限密钥随之失效
+	s.InvalidateAuthCacheByKey(ctx, oldKey)
+	if apiKey.PreviousKey != nil {
+		s.InvalidateAuthCacheByKey(ctx, *apiKey.PreviousKey)
+	}
+	s.InvalidateAuthCacheByKey(ctx, newKey)
+
+	apiKey.Key = newKey
+	apiKey.PreviousKey = nil
+	if graceUntil != nil {
+		apiKey.PreviousKey = &oldKey
+	}
+	apiKey.PreviousKeyExpiresAt = graceUntil
+	s.compileAPIKeyIPRules(apiKey)
+	return apiKey, nil
+}
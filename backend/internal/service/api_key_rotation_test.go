@@ -0,0 +1,141 @@
+//go:build unit
+
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/ShaohongDong/sub2api/internal/config"
+	"github.com/stretchr/testify/require"
+)
+
+type rotationRepoStub struct {
+	APIKeyRepository
+
+	key *APIKey
+
+	rotatedOld string
+	rotatedNew string
+	graceUntil *time.Time
+}
+
+func (s *rotationRepoStub) GetByID(ctx context.Context, id int64) (*APIKey, error) {
+	if s.key == nil || s.key.ID != id {
+		return nil, ErrAPIKeyNotFound
+	}
+	clone := *s.key
+	return &clone, nil
+}
+
+func (s *rotationRepoStub) RotateKey(ctx context.Context, id int64, oldKey, newKey string, graceUntil *time.Time) error {
+	s.rotatedOld = oldKey
+	s.rotatedNew = newKey
+	s.graceUntil = graceUntil
+	return nil
+}
+
+func TestAPIKeyService_RotateKeepsRecordAndSetsGrace(t *testing.T) {
+	oldPrevious := "sk-older"
+	repo := &rotationRepoStub{key: &APIKey{
+		ID:          5,
+		UserID:      2,
+		Key:         "sk-old",
+		Quota:       10,
+		QuotaUsed:   4,
+		IPWhitelist: []string{"10.0.0.0/8"},
+		PreviousKey: &oldPrevious,
+	}}
+	cache := &authCacheStub{}
+	svc := NewAPIKeyService(repo, nil, nil, nil, nil, cache, &config.Config{})
+
+	before := time.Now()
+	rotated, err := svc.Rotate(context.Background(), 5, time.Hour)
+	require.NoError(t, err)
+
+	require.Equal(t, int64(5), rotated.ID)
+	require.NotEqual(t, "sk-old", rotated.Key)
+	require.Equal(t, repo.rotatedNew, rotated.Key)
+	require.Equal(t, "sk-old", repo.rotatedOld)
+	require.Equal(t, 4.0, rotated.QuotaUsed)
+	require.Equal(t, []string{"10.0.0.0/8"}, rotated.IPWhitelist)
+
+	require.NotNil(t, repo.graceUntil)
+	require.WithinDuration(t, before.Add(time.Hour), *repo.graceUntil, 5*time.Second)
+	require.NotNil(t, rotated.PreviousKey)
+	require.Equal(t, "sk-old", *rotated.PreviousKey)
+	require.Equal(t, repo.graceUntil, rotated.PreviousKeyExpiresAt)
+
+	require.ElementsMatch(t, []string{
+		svc.authCacheKey("sk-old"),
+		svc.authCacheKey("sk-older"),
+		svc.authCacheKey(rotated.Key),
+	}, cache.deleteAuthKeys)
+}
+
+func TestAPIKeyService_RotateWithoutGraceDropsOldKey(t *testing.T) {
+	repo := &rotationRepoStub{key: &APIKey{ID: 5, Key: "sk-old"}}
+	svc := NewAPIKeyService(repo, nil, nil, nil, nil, nil, &config.Config{})
+
+	rotated, err := svc.Rotate(context.Background(), 5, 0)
+	require.NoError(t, err)
+	require.Nil(t, repo.graceUntil)
+	require.Nil(t, rotated.PreviousKey)
+	require.Nil(t, rotated.PreviousKeyExpiresAt)
+}
+
+func TestAPIKeyService_RotateRejectsInvalidGrace(t *testing.T) {
+	svc := NewAPIKeyService(&rotationRepoStub{}, nil, nil, nil, nil, nil, &config.Config{})
+
+	_, err := svc.Rotate(context.Background(), 5, -time.Minute)
+	require.ErrorIs(t, err, ErrInvalidAPIKeyRotationGrace)
+	_, err = svc.Rotate(context.Background(), 5, MaxAPIKeyRotationGrace+time.Minute)
+	require.ErrorIs(t, err, ErrInvalidAPIKeyRotationGrace)
+
+	_, err = svc.Rotate(context.Background(), 5, time.Minute)
+	require.ErrorIs(t, err, ErrAPIKeyNotFound)
+}
+
+func TestAPIKeyService_GetByKey_GraceKeyIsNotCached(t *testing.T) {
+	oldKey := "sk-old"
+	expires := time.Now().Add(time.Hour)
+	calls := 0
+	cache := &authCacheStub{}
+	repo := &authRepoStub{
+		getByKeyForAuth: func(ctx context.Context, key string) (*APIKey, error) {
+			calls++
+			return &APIKey{
+				ID:                   5,
+				UserID:               2,
+				Status:               StatusActive,
+				User:                 &User{ID: 2, Status: StatusActive, Role: RoleUser},
+				PreviousKey:          &oldKey,
+				PreviousKeyExpiresAt: &expires,
+			}, nil
+		},
+	}
+	cfg := &config.Config{
+		APIKeyAuth: config.APIKeyAuthCacheConfig{
+			L1Size:        100,
+			L1TTLSeconds:  60,
+			L2TTLSeconds:  60,
+			Singleflight:  true,
+			JitterPercent: 0,
+		},
+	}
+	svc := NewAPIKeyService(repo, nil, nil, nil, nil, cache, cfg)
+
+	for i := 0; i < 2; i++ {
+		apiKey, err := svc.GetByKey(context.Background(), oldKey)
+		require.NoError(t, err)
+		require.Equal(t, int64(5), apiKey.ID)
+		require.Equal(t, oldKey, apiKey.Key)
+	}
+	require.Equal(t, 2, calls, "grace key lookups must hit the repository every time")
+	require.Empty(t, cache.setAuthKeys)
+
+	_, err := svc.GetByKey(context.Background(), "sk-new")
+	require.NoError(t, err)
+	require.Equal(t, []string{svc.authCacheKey("sk-new")}, cache.setAuthKeys)
+}
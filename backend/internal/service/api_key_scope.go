@@ -0,0 +1,64 @@
+package service
+
+import (
+	"slices"
+	"strings"
+)
+
+// API Key scope constants：按端点类别授权，Key 未配置 scope 时可访问全部端点
+const (
+	APIKeyScopeMessages    = "messages"    // Anthropic Messages（含 Bedrock 风格路由）
+	APIKeyScopeResponses   = "responses"   // OpenAI Responses（含 WebSocket 与后台任务）
+	APIKeyScopeChat        = "chat"        // Chat Completions、旧版 Completions 与 Ollama 对话
+	APIKeyScopeGemini      = "gemini"      // Gemini 原生 API（/v1beta）
+	APIKeyScopeEmbeddings  = "embeddings"  // Embeddings
+	APIKeyScopeImages      = "images"      // 图片生成
+	APIKeyScopeAudio       = "audio"       // 语音转写 / 合成与 Realtime
+	APIKeyScopeModerations = "moderations" // 内容审核
+	APIKeyScopeBatches     = "batches"     // Batch 与 Files
+)
+
+// APIKeyScopes 全部可配置的 scope
+var APIKeyScopes = []string{
+	APIKeyScopeMessages,
+	APIKeyScopeResponses,
+	APIKeyScopeChat,
+	APIKeyScopeGemini,
+	APIKeyScopeEmbeddings,
+	APIKeyScopeImages,
+	APIKeyScopeAudio,
+	APIKeyScopeModerations,
+	APIKeyScopeBatches,
+}
+
+// normalizeAPIKeyScopes 去除空白、统一小写并去重；包含未知 scope 时返回错误
+func normalizeAPIKeyScopes(scopes []string) ([]string, error) {
+	if len(scopes) == 0 {
+		return nil, nil
+	}
+	out := make([]string, 0, len(scopes))
+	for _, scope := range scopes {
+		scope = strings.ToLower(strings.TrimSpace(scope))
+		if scope == "" {
+			continue
+		}
+		if !slices.Contains(APIKeyScopes, scope) {
+			return nil, ErrInvalidAPIKeyScope.WithMetadata(map[string]string{"scope": scope})
+		}
+		if !slices.Contains(out, scope) {
+			out = append(out, scope)
+		}
+	}
+	if len(out) == 0 {
+		return nil, nil
+	}
+	return out, nil
+}
+
+// AllowsScope 判断 Key 是否可访问指定类别的端点；scope 为空的端点（模型列表、用量查询等）始终放行
+func (k *APIKey) AllowsScope(scope string) bool {
+	if scope == "" || len(k.Scopes) == 0 {
+		return true
+	}
+	return slices.Contains(k.Scopes, scope)
+}
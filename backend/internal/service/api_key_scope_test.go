@@ -0,0 +1,32 @@
+//go:build unit
+
+package service
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestNormalizeAPIKeyScopes(t *testing.T) {
+	scopes, err := normalizeAPIKeyScopes([]string{" Messages ", "responses", "messages", ""})
+	require.NoError(t, err)
+	require.Equal(t, []string{APIKeyScopeMessages, APIKeyScopeResponses}, scopes)
+
+	scopes, err = normalizeAPIKeyScopes([]string{" ", ""})
+	require.NoError(t, err)
+	require.Nil(t, scopes)
+
+	_, err = normalizeAPIKeyScopes([]string{"messages", "admin"})
+	require.ErrorIs(t, err, ErrInvalidAPIKeyScope)
+}
+
+func TestAPIKeyAllowsScope(t *testing.T) {
+	unrestricted := &APIKey{}
+	require.True(t, unrestricted.AllowsScope(APIKeyScopeChat))
+
+	restricted := &APIKey{Scopes: []string{APIKeyScopeMessages}}
+	require.True(t, restricted.AllowsScope(APIKeyScopeMessages))
+	require.False(t, restricted.AllowsScope(APIKeyScopeChat))
+	require.True(t, restricted.AllowsScope(""), "unscoped endpoints stay reachable")
+}
@@ -6,6 +6,7 @@ import (
 	"encoding/hex"
 	"fmt"
 	"strconv"
+	"strings"
 	"sync"
 	"time"
 
@@ -19,17 +20,25 @@ import (
 )
 
 var (
-	ErrAPIKeyNotFound     = infraerrors.NotFound("API_KEY_NOT_FOUND", "api key not found")
-	ErrGroupNotAllowed    = infraerrors.Forbidden("GROUP_NOT_ALLOWED", "user is not allowed to bind this group")
-	ErrAPIKeyExists       = infraerrors.Conflict("API_KEY_EXISTS", "api key already exists")
-	ErrAPIKeyTooShort     = infraerrors.BadRequest("API_KEY_TOO_SHORT", "api key must be at least 16 characters")
-	ErrAPIKeyInvalidChars = infraerrors.BadRequest("API_KEY_INVALID_CHARS", "api key can only contain letters, numbers, underscores, and hyphens")
-	ErrAPIKeyRateLimited  = infraerrors.TooManyRequests("API_KEY_RATE_LIMITED", "too many failed attempts, please try again later")
-	ErrInvalidIPPattern   = infraerrors.BadRequest("INVALID_IP_PATTERN", "invalid IP or CIDR pattern")
+	ErrAPIKeyNotFound                 = infraerrors.NotFound("API_KEY_NOT_FOUND", "api key not found")
+	ErrGroupNotAllowed                = infraerrors.Forbidden("GROUP_NOT_ALLOWED", "user is not allowed to bind this group")
+	ErrAPIKeyExists                   = infraerrors.Conflict("API_KEY_EXISTS", "api key already exists")
+	ErrAPIKeyTooShort                 = infraerrors.BadRequest("API_KEY_TOO_SHORT", "api key must be at least 16 characters")
+	ErrAPIKeyInvalidChars             = infraerrors.BadRequest("API_KEY_INVALID_CHARS", "api key can only contain letters, numbers, underscores, and hyphens")
+	ErrAPIKeyRateLimited              = infraerrors.TooManyRequests("API_KEY_RATE_LIMITED", "too many failed attempts, please try again later")
+	ErrInvalidIPPattern               = infraerrors.BadRequest("INVALID_IP_PATTERN", "invalid IP or CIDR pattern")
+	ErrInvalidAPIKeyScope             = infraerrors.BadRequest("INVALID_API_KEY_SCOPE", "invalid api key scope")
+	ErrInvalidAPIKeyAllowedModels     = infraerrors.BadRequest("INVALID_API_KEY_ALLOWED_MODELS", "invalid api key allowed models (only a trailing * wildcard is supported)")
+	ErrInvalidAPIKeyClientRestriction = infraerrors.BadRequest("INVALID_API_KEY_CLIENT_RESTRICTION", "invalid api key client restriction")
+	ErrInvalidAPIKeyDegradedFallback  = infraerrors.BadRequest("INVALID_API_KEY_DEGRADED_FALLBACK", "invalid api key degraded fallback model")
+	ErrInvalidAPIKeySystemPrompt      = infraerrors.BadRequest("INVALID_API_KEY_SYSTEM_PROMPT", "invalid api key system prompt")
+	ErrInvalidAPIKeyModerationMode    = infraerrors.BadRequest("INVALID_API_KEY_MODERATION_MODE", "invalid api key moderation mode (must be off, flag or block)")
 	// ErrAPIKeyExpired        = infraerrors.Forbidden("API_KEY_EXPIRED", "api key has expired")
 	ErrAPIKeyExpired = infraerrors.Forbidden("API_KEY_EXPIRED", "api key 已过期")
 	// ErrAPIKeyQuotaExhausted = infraerrors.TooManyRequests("API_KEY_QUOTA_EXHAUSTED", "api key quota exhausted")
 	ErrAPIKeyQuotaExhausted = infraerrors.TooManyRequests("API_KEY_QUOTA_EXHAUSTED", "api key 额度已用完")
+	// ErrAPIKeyImageQuotaExhausted = infraerrors.TooManyRequests("API_KEY_IMAGE_QUOTA_EXHAUSTED", "api key image quota exhausted")
+	ErrAPIKeyImageQuotaExhausted = infraerrors.TooManyRequests("API_KEY_IMAGE_QUOTA_EXHAUSTED", "api key 图片额度已用完")
 
 	// Rate limit errors
 	ErrAPIKeyRateLimit5hExceeded = infraerrors.TooManyRequests("API_KEY_RATE_5H_EXCEEDED", "api key 5小时限额已用完")
@@ -68,12 +77,20 @@ type APIKeyRepository interface {
 
 	// Quota methods
 	IncrementQuotaUsed(ctx context.Context, id int64, amount float64) (float64, error)
+	IncrementImageQuotaUsed(ctx context.Context, id int64, count int) (int, error)
 	UpdateLastUsed(ctx context.Context, id int64, usedAt time.Time) error
 
 	// Rate limit methods
 	IncrementRateLimitUsage(ctx context.Context, id int64, cost float64) error
 	ResetRateLimitWindows(ctx context.Context, id int64) error
 	GetRateLimitData(ctx context.Context, id int64) (*APIKeyRateLimitData, error)
+
+	// Budget methods
+	IncrementBudgetUsed(ctx context.Context, id int64, amount float64, periodStart time.Time) (float64, error)
+	MarkBudgetExceeded(ctx context.Context, id int64, periodStart, exceededAt time.Time) (bool, error)
+
+	// RotateKey 将 oldKey 替换为 newKey；graceUntil 非 nil 时旧密钥在此之前仍可认证
+	RotateKey(ctx context.Context, id int64, oldKey, newKey string, graceUntil *time.Time) error
 }
 
 // APIKeyRateLimitData holds rate limit usage and window state for an API key.
@@ -137,29 +154,64 @@ type APIKeyAuthCacheInvalidator interface {
 
 // CreateAPIKeyRequest 创建API Key请求
 type CreateAPIKeyRequest struct {
-	Name        string   `json:"name"`
-	GroupID     *int64   `json:"group_id"`
-	CustomKey   *string  `json:"custom_key"`   // 可选的自定义key
-	IPWhitelist []string `json:"ip_whitelist"` // IP 白名单
-	IPBlacklist []string `json:"ip_blacklist"` // IP 黑名单
+	Name              string   `json:"name"`
+	Description       string   `json:"description"`
+	GroupID           *int64   `json:"group_id"`
+	CustomKey         *string  `json:"custom_key"`         // 可选的自定义key
+	IPWhitelist       []string `json:"ip_whitelist"`       // IP 白名单
+	IPBlacklist       []string `json:"ip_blacklist"`       // IP 黑名单
+	Scopes            []string `json:"scopes"`             // 可访问的端点类别（空表示不限制）
+	AllowedModels     []string `json:"allowed_models"`     // 可使用的模型（支持末尾 * 通配符，空表示不限制）
+	ClientRestriction string   `json:"client_restriction"` // 允许的客户端类别（空表示不限制）
+	Tags              []string `json:"tags"`               // 路由标签（仅管理员创建时可设置）
 
 	// Quota fields
-	Quota         float64 `json:"quota"`           // Quota limit in USD (0 = unlimited)
-	ExpiresInDays *int    `json:"expires_in_days"` // Days until expiry (nil = never expires)
+	Quota             float64 `json:"quota"`              // Quota limit in USD (0 = unlimited)
+	ImageQuota        int     `json:"image_quota"`        // Max generated images (0 = unlimited)
+	SuppressReasoning bool    `json:"suppress_reasoning"` // Drop reasoning output for plain API clients
+	ExpiresInDays     *int    `json:"expires_in_days"`    // Days until expiry (nil = never expires)
 
 	// Rate limit fields (0 = unlimited)
 	RateLimit5h float64 `json:"rate_limit_5h"`
 	RateLimit1d float64 `json:"rate_limit_1d"`
 	RateLimit7d float64 `json:"rate_limit_7d"`
+
+	// Request / token limits (0 = unlimited)
+	RPMLimit            int   `json:"rpm_limit"`
+	TPMLimit            int   `json:"tpm_limit"`
+	DailyRequestLimit   int   `json:"daily_request_limit"`
+	DailyTokenLimit     int64 `json:"daily_token_limit"`
+	MonthlyRequestLimit int   `json:"monthly_request_limit"`
+	MonthlyTokenLimit   int64 `json:"monthly_token_limit"`
+
+	// Spend budget (0 = no budget)
+	BudgetAmount        float64 `json:"budget_amount"`
+	BudgetPeriod        string  `json:"budget_period"`         // day / week / month (default month)
+	BudgetAction        string  `json:"budget_action"`         // disable / downgrade (default disable)
+	BudgetFallbackModel string  `json:"budget_fallback_model"` // Required for downgrade
+
+	// Degraded mode: model served when every account for the requested model is unavailable ('' = return 503)
+	DegradedFallbackModel string `json:"degraded_fallback_model"`
+
+	// System prompt injected into every request ('' = none, admin only); mode is prepend / append / replace (default prepend)
+	SystemPrompt     string `json:"system_prompt"`
+	SystemPromptMode string `json:"system_prompt_mode"`
+
+	// Prompt moderation pre-filter: off / flag / block ('' = follow gateway.moderation.pre_filter, admin only)
+	ModerationMode string `json:"moderation_mode"`
 }
 
 // UpdateAPIKeyRequest 更新API Key请求
 type UpdateAPIKeyRequest struct {
-	Name        *string  `json:"name"`
-	GroupID     *int64   `json:"group_id"`
-	Status      *string  `json:"status"`
-	IPWhitelist []string `json:"ip_whitelist"` // IP 白名单（空数组清空）
-	IPBlacklist []string `json:"ip_blacklist"` // IP 黑名单（空数组清空）
+	Name              *string  `json:"name"`
+	Description       *string  `json:"description"`
+	GroupID           *int64   `json:"group_id"`
+	Status            *string  `json:"status"`
+	IPWhitelist       []string `json:"ip_whitelist"`       // IP 白名单（空数组清空）
+	IPBlacklist       []string `json:"ip_blacklist"`       // IP 黑名单（空数组清空）
+	Scopes            []string `json:"scopes"`             // 可访问的端点类别（nil 不修改，空数组清空）
+	AllowedModels     []string `json:"allowed_models"`     // 可使用的模型（nil 不修改，空数组清空）
+	ClientRestriction *string  `json:"client_restriction"` // 允许的客户端类别（nil 不修改，空字符串清除）
 
 	// Quota fields
 	Quota           *float64   `json:"quota"`       // Quota limit in USD (nil = no change, 0 = unlimited)
@@ -167,11 +219,35 @@ type UpdateAPIKeyRequest struct {
 	ClearExpiration bool       `json:"-"`           // Clear expiration (internal use)
 	ResetQuota      *bool      `json:"reset_quota"` // Reset quota_used to 0
 
+	// Image quota fields (nil = no change, 0 = unlimited)
+	ImageQuota      *int  `json:"image_quota"`
+	ResetImageQuota *bool `json:"reset_image_quota"` // Reset image_quota_used to 0
+
+	SuppressReasoning *bool `json:"suppress_reasoning"` // nil = no change
+
 	// Rate limit fields (nil = no change, 0 = unlimited)
 	RateLimit5h         *float64 `json:"rate_limit_5h"`
 	RateLimit1d         *float64 `json:"rate_limit_1d"`
 	RateLimit7d         *float64 `json:"rate_limit_7d"`
 	ResetRateLimitUsage *bool    `json:"reset_rate_limit_usage"` // Reset all usage counters to 0
+
+	// Request / token limits (nil = no change, 0 = unlimited)
+	RPMLimit            *int   `json:"rpm_limit"`
+	TPMLimit            *int   `json:"tpm_limit"`
+	DailyRequestLimit   *int   `json:"daily_request_limit"`
+	DailyTokenLimit     *int64 `json:"daily_token_limit"`
+	MonthlyRequestLimit *int   `json:"monthly_request_limit"`
+	MonthlyTokenLimit   *int64 `json:"monthly_token_limit"`
+
+	// Spend budget (nil = no change, 0 = no budget)
+	BudgetAmount        *float64 `json:"budget_amount"`
+	BudgetPeriod        *string  `json:"budget_period"`
+	BudgetAction        *string  `json:"budget_action"`
+	BudgetFallbackModel *string  `json:"budget_fallback_model"`
+	ResetBudgetUsage    *bool    `json:"reset_budget_usage"` // Reset budget_used and lift the exceeded state
+
+	// Degraded mode fallback model (nil = no change, '' = clear)
+	DegradedFallbackModel *string `json:"degraded_fallback_model"`
 }
 
 // APIKeyService API Key服务
@@ -188,6 +264,9 @@ type APIKeyService struct {
 	userGroupRateRepo     UserGroupRateRepository
 	cache                 APIKeyCache
 	rateLimitCacheInvalid RateLimitCacheInvalidator // optional: invalidate Redis rate limit cache
+	requestLimitCache     APIKeyRequestLimitCache   // optional: RPM/TPM 与日/月请求、token 配额计数
+	budgetNotifier        APIKeyBudgetNotifier      // optional: 预算超限通知（webhook）
+	alertService          *AlertService             // optional: 额度消耗达到阈值时告警
 	cfg                   *config.Config
 	authCacheL1           *ristretto.Cache
 	authCfg               apiKeyAuthCacheConfig
@@ -225,6 +304,11 @@ func (s *APIKeyService) SetRateLimitCacheInvalidator(inv RateLimitCacheInvalidat
 	s.rateLimitCacheInvalid = inv
 }
 
+// SetAlertService sets the optional ops alert service used for quota threshold alerts.
+func (s *APIKeyService) SetAlertService(alertService *AlertService) {
+	s.alertService = alertService
+}
+
 func (s *APIKeyService) compileAPIKeyIPRules(apiKey *APIKey) {
 	if apiKey == nil {
 		return
@@ -304,6 +388,10 @@ func (s *APIKeyService) incrementAPIKeyErrorCount(ctx context.Context, userID in
 // 对于订阅类型分组：检查用户是否有有效订阅
 // 对于标准类型分组：使用原有的 AllowedGroups 和 IsExclusive 逻辑
 func (s *APIKeyService) canUserBindGroup(ctx context.Context, user *User, group *Group) bool {
+	// 租户隔离：只能绑定同租户的分组
+	if !TenantMatches(user.TenantID, group.TenantID) {
+		return false
+	}
 	// 订阅类型分组：需要有效订阅
 	if group.IsSubscriptionType() {
 		_, err := s.userSubRepo.GetActiveByUserIDAndGroupID(ctx, user.ID, group.ID)
@@ -335,6 +423,23 @@ func (s *APIKeyService) Create(ctx context.Context, userID int64, req CreateAPIK
 		}
 	}
 
+	scopes, err := normalizeAPIKeyScopes(req.Scopes)
+	if err != nil {
+		return nil, err
+	}
+	allowedModels, err := normalizeAPIKeyAllowedModels(req.AllowedModels)
+	if err != nil {
+		return nil, err
+	}
+	clientRestriction, err := normalizeAPIKeyClientRestriction(req.ClientRestriction)
+	if err != nil {
+		return nil, err
+	}
+	tags, err := normalizeAPIKeyTags(req.Tags)
+	if err != nil {
+		return nil, err
+	}
+
 	// 验证分组权限（如果指定了分组）
 	if req.GroupID != nil {
 		group, err := s.groupRepo.GetByID(ctx, *req.GroupID)
@@ -385,18 +490,56 @@ func (s *APIKeyService) Create(ctx context.Context, userID int64, req CreateAPIK
 
 	// 创建API Key记录
 	apiKey := &APIKey{
-		UserID:      userID,
-		Key:         key,
-		Name:        req.Name,
-		GroupID:     req.GroupID,
-		Status:      StatusActive,
-		IPWhitelist: req.IPWhitelist,
-		IPBlacklist: req.IPBlacklist,
-		Quota:       req.Quota,
-		QuotaUsed:   0,
-		RateLimit5h: req.RateLimit5h,
-		RateLimit1d: req.RateLimit1d,
-		RateLimit7d: req.RateLimit7d,
+		UserID:            userID,
+		Key:               key,
+		Name:              req.Name,
+		Description:       strings.TrimSpace(req.Description),
+		GroupID:           req.GroupID,
+		Status:            StatusActive,
+		IPWhitelist:       req.IPWhitelist,
+		IPBlacklist:       req.IPBlacklist,
+		Scopes:            scopes,
+		AllowedModels:     allowedModels,
+		ClientRestriction: clientRestriction,
+		Tags:              tags,
+		Quota:             req.Quota,
+		QuotaUsed:         0,
+		ImageQuota:        req.ImageQuota,
+		SuppressReasoning: req.SuppressReasoning,
+		RateLimit5h:       req.RateLimit5h,
+		RateLimit1d:       req.RateLimit1d,
+		RateLimit7d:       req.RateLimit7d,
+
+		RPMLimit:            req.RPMLimit,
+		TPMLimit:            req.TPMLimit,
+		DailyRequestLimit:   req.DailyRequestLimit,
+		DailyTokenLimit:     req.DailyTokenLimit,
+		MonthlyRequestLimit: req.MonthlyRequestLimit,
+		MonthlyTokenLimit:   req.MonthlyTokenLimit,
+
+		BudgetAmount:        req.BudgetAmount,
+		BudgetPeriod:        req.BudgetPeriod,
+		BudgetAction:        req.BudgetAction,
+		BudgetFallbackModel: req.BudgetFallbackModel,
+
+		DegradedFallbackModel: req.DegradedFallbackModel,
+
+		SystemPrompt:     req.SystemPrompt,
+		SystemPromptMode: req.SystemPromptMode,
+
+		ModerationMode: req.ModerationMode,
+	}
+	if err := normalizeAPIKeyBudget(apiKey); err != nil {
+		return nil, err
+	}
+	if err := normalizeAPIKeyDegradedFallback(apiKey); err != nil {
+		return nil, err
+	}
+	if err := normalizeAPIKeySystemPrompt(apiKey); err != nil {
+		return nil, err
+	}
+	if err := normalizeAPIKeyModerationMode(apiKey); err != nil {
+		return nil, err
 	}
 
 	// Set expiration time if specified
@@ -528,6 +671,30 @@ func (s *APIKeyService) Update(ctx context.Context, id int64, userID int64, req
 	if req.Name != nil {
 		apiKey.Name = *req.Name
 	}
+	if req.Description != nil {
+		apiKey.Description = strings.TrimSpace(*req.Description)
+	}
+	if req.Scopes != nil {
+		scopes, err := normalizeAPIKeyScopes(req.Scopes)
+		if err != nil {
+			return nil, err
+		}
+		apiKey.Scopes = scopes
+	}
+	if req.AllowedModels != nil {
+		allowedModels, err := normalizeAPIKeyAllowedModels(req.AllowedModels)
+		if err != nil {
+			return nil, err
+		}
+		apiKey.AllowedModels = allowedModels
+	}
+	if req.ClientRestriction != nil {
+		clientRestriction, err := normalizeAPIKeyClientRestriction(*req.ClientRestriction)
+		if err != nil {
+			return nil, err
+		}
+		apiKey.ClientRestriction = clientRestriction
+	}
 
 	if req.GroupID != nil {
 		// 验证分组权限
@@ -571,6 +738,15 @@ func (s *APIKeyService) Update(ctx context.Context, id int64, userID int64, req
 			apiKey.Status = StatusActive
 		}
 	}
+	if req.ImageQuota != nil {
+		apiKey.ImageQuota = *req.ImageQuota
+	}
+	if req.ResetImageQuota != nil && *req.ResetImageQuota {
+		apiKey.ImageQuotaUsed = 0
+	}
+	if req.SuppressReasoning != nil {
+		apiKey.SuppressReasoning = *req.SuppressReasoning
+	}
 	if req.ClearExpiration {
 		apiKey.ExpiresAt = nil
 		// If clearing expiry and status was expired, reactivate
@@ -599,6 +775,60 @@ func (s *APIKeyService) Update(ctx context.Context, id int64, userID int64, req
 	if req.RateLimit7d != nil {
 		apiKey.RateLimit7d = *req.RateLimit7d
 	}
+	if req.RPMLimit != nil {
+		apiKey.RPMLimit = *req.RPMLimit
+	}
+	if req.TPMLimit != nil {
+		apiKey.TPMLimit = *req.TPMLimit
+	}
+	if req.DailyRequestLimit != nil {
+		apiKey.DailyRequestLimit = *req.DailyRequestLimit
+	}
+	if req.DailyTokenLimit != nil {
+		apiKey.DailyTokenLimit = *req.DailyTokenLimit
+	}
+	if req.MonthlyRequestLimit != nil {
+		apiKey.MonthlyRequestLimit = *req.MonthlyRequestLimit
+	}
+	if req.MonthlyTokenLimit != nil {
+		apiKey.MonthlyTokenLimit = *req.MonthlyTokenLimit
+	}
+	budgetChanged := false
+	if req.BudgetAmount != nil {
+		budgetChanged = budgetChanged || *req.BudgetAmount != apiKey.BudgetAmount
+		apiKey.BudgetAmount = *req.BudgetAmount
+	}
+	if req.BudgetPeriod != nil {
+		budgetChanged = budgetChanged || *req.BudgetPeriod != apiKey.BudgetPeriod
+		apiKey.BudgetPeriod = *req.BudgetPeriod
+	}
+	if req.BudgetAction != nil {
+		apiKey.BudgetAction = *req.BudgetAction
+	}
+	if req.BudgetFallbackModel != nil {
+		apiKey.BudgetFallbackModel = *req.BudgetFallbackModel
+	}
+	if req.BudgetAction != nil || req.BudgetFallbackModel != nil || req.AllowedModels != nil || budgetChanged {
+		if err := normalizeAPIKeyBudget(apiKey); err != nil {
+			return nil, err
+		}
+	}
+	if req.DegradedFallbackModel != nil {
+		apiKey.DegradedFallbackModel = *req.DegradedFallbackModel
+	}
+	if req.DegradedFallbackModel != nil || req.AllowedModels != nil {
+		if err := normalizeAPIKeyDegradedFallback(apiKey); err != nil {
+			return nil, err
+		}
+	}
+	if req.ResetBudgetUsage != nil && *req.ResetBudgetUsage {
+		apiKey.BudgetUsed = 0
+		apiKey.BudgetExceededAt = nil
+	} else if budgetChanged && apiKey.BudgetExceededAt != nil && apiKey.BudgetUsed < apiKey.BudgetAmount {
+		// 调高预算后立即恢复；再次越过时重新告警
+		apiKey.BudgetExceededAt = nil
+	}
+
 	resetRateLimit := req.ResetRateLimitUsage != nil && *req.ResetRateLimitUsage
 	if resetRateLimit {
 		apiKey.Usage5h = 0
@@ -650,6 +880,15 @@ func (s *APIKeyService) Delete(ctx context.Context, id int64, userID int64) erro
 	return nil
 }
 
+// Revoke 由管理员吊销 API Key（软删除，立即失效；不校验所有者）
+func (s *APIKeyService) Revoke(ctx context.Context, id int64) error {
+	_, ownerID, err := s.apiKeyRepo.GetKeyAndOwnerID(ctx, id)
+	if err != nil {
+		return fmt.Errorf("get api key: %w", err)
+	}
+	return s.Delete(ctx, id, ownerID)
+}
+
 // ValidateKey 验证API Key是否有效（用于认证中间件）
 func (s *APIKeyService) ValidateKey(ctx context.Context, key string) (*APIKey, *User, error) {
 	// 获取API Key
@@ -765,6 +1004,9 @@ func (s *APIKeyService) GetAvailableGroups(ctx context.Context, userID int64) ([
 
 // canUserBindGroupInternal 内部方法，检查用户是否可以绑定分组（使用预加载的订阅数据）
 func (s *APIKeyService) canUserBindGroupInternal(user *User, group *Group, subscribedGroupIDs map[int64]bool) bool {
+	if !TenantMatches(user.TenantID, group.TenantID) {
+		return false
+	}
 	// 订阅类型分组：需要有效订阅
 	if group.IsSubscriptionType() {
 		return subscribedGroupIDs[group.ID]
@@ -829,6 +1071,8 @@ func (s *APIKeyService) UpdateQuotaUsed(ctx context.Context, apiKeyID int64, cos
 		return nil // Don't fail the request, just log
 	}
 
+	s.maybeFireQuotaThresholdAlert(ctx, apiKey, newQuotaUsed-cost, newQuotaUsed)
+
 	// If quota is set and now exhausted, update status
 	if apiKey.Quota > 0 && newQuotaUsed >= apiKey.Quota {
 		apiKey.Status = StatusAPIKeyQuotaExhausted
@@ -842,6 +1086,66 @@ func (s *APIKeyService) UpdateQuotaUsed(ctx context.Context, apiKeyID int64, cos
 	return nil
 }
 
+// maybeFireQuotaThresholdAlert fires quota_threshold when this increment crosses the
+// configured percentage. quota_used is incremented atomically, so exactly one request
+// observes the crossing.
+func (s *APIKeyService) maybeFireQuotaThresholdAlert(ctx context.Context, apiKey *APIKey, before, after float64) {
+	threshold := s.alertService.QuotaThreshold()
+	if threshold <= 0 || apiKey.Quota <= 0 {
+		return
+	}
+	limit := apiKey.Quota * threshold
+	if before >= limit || after < limit {
+		return
+	}
+	s.alertService.Fire(ctx, &AlertEvent{
+		Type:     AlertEventQuotaThreshold,
+		Severity: AlertSeverityWarning,
+		Title:    fmt.Sprintf("API key quota %.0f%% consumed: %s (#%d)", threshold*100, apiKey.Name, apiKey.ID),
+		Message:  fmt.Sprintf("Used $%.4f of $%.4f quota", after, apiKey.Quota),
+		Fields: map[string]any{
+			"api_key_id":   apiKey.ID,
+			"api_key_name": apiKey.Name,
+			"user_id":      apiKey.UserID,
+			"quota":        apiKey.Quota,
+			"quota_used":   after,
+			"percent":      after / apiKey.Quota * 100,
+		},
+		DedupKey: strconv.FormatInt(apiKey.ID, 10),
+	})
+}
+
+// CheckImageQuota checks whether the API key may generate n more images.
+// The used counter is read from the database because the auth cache only
+// carries the limit.
+func (s *APIKeyService) CheckImageQuota(ctx context.Context, apiKey *APIKey, n int) error {
+	if apiKey == nil || apiKey.ImageQuota <= 0 {
+		return nil
+	}
+	if n < 1 {
+		n = 1
+	}
+	current, err := s.apiKeyRepo.GetByID(ctx, apiKey.ID)
+	if err != nil {
+		return fmt.Errorf("get api key: %w", err)
+	}
+	if current.ImageQuota > 0 && current.ImageQuotaUsed+n > current.ImageQuota {
+		return ErrAPIKeyImageQuotaExhausted
+	}
+	return nil
+}
+
+// UpdateImageQuotaUsed atomically adds generated images to image_quota_used.
+func (s *APIKeyService) UpdateImageQuotaUsed(ctx context.Context, apiKeyID int64, count int) error {
+	if count <= 0 {
+		return nil
+	}
+	if _, err := s.apiKeyRepo.IncrementImageQuotaUsed(ctx, apiKeyID, count); err != nil {
+		return fmt.Errorf("increment image quota used: %w", err)
+	}
+	return nil
+}
+
 // GetRateLimitData returns rate limit usage and window state for an API key.
 func (s *APIKeyService) GetRateLimitData(ctx context.Context, id int64) (*APIKeyRateLimitData, error) {
 	return s.apiKeyRepo.GetRateLimitData(ctx, id)
@@ -103,6 +103,10 @@ func (s *authRepoStub) IncrementQuotaUsed(ctx context.Context, id int64, amount
 	panic("unexpected IncrementQuotaUsed call")
 }
 
+func (s *authRepoStub) IncrementImageQuotaUsed(ctx context.Context, id int64, count int) (int, error) {
+	panic("unexpected IncrementImageQuotaUsed call")
+}
+
 func (s *authRepoStub) UpdateLastUsed(ctx context.Context, id int64, usedAt time.Time) error {
 	panic("unexpected UpdateLastUsed call")
 }
@@ -116,6 +120,18 @@ func (s *authRepoStub) GetRateLimitData(ctx context.Context, id int64) (*APIKeyR
 	panic("unexpected GetRateLimitData call")
 }
 
+func (s *authRepoStub) IncrementBudgetUsed(ctx context.Context, id int64, amount float64, periodStart time.Time) (float64, error) {
+	panic("unexpected IncrementBudgetUsed call")
+}
+
+func (s *authRepoStub) MarkBudgetExceeded(ctx context.Context, id int64, periodStart, exceededAt time.Time) (bool, error) {
+	panic("unexpected MarkBudgetExceeded call")
+}
+
+func (s *authRepoStub) RotateKey(ctx context.Context, id int64, oldKey, newKey string, graceUntil *time.Time) error {
+	panic("unexpected RotateKey call")
+}
+
 type authCacheStub struct {
 	getAuthCache   func(ctx context.Context, key string) (*APIKeyAuthCacheEntry, error)
 	setAuthKeys    []string
@@ -125,6 +125,10 @@ func (s *apiKeyRepoStub) IncrementQuotaUsed(ctx context.Context, id int64, amoun
 	panic("unexpected IncrementQuotaUsed call")
 }
 
+func (s *apiKeyRepoStub) IncrementImageQuotaUsed(ctx context.Context, id int64, count int) (int, error) {
+	panic("unexpected IncrementImageQuotaUsed call")
+}
+
 func (s *apiKeyRepoStub) UpdateLastUsed(ctx context.Context, id int64, usedAt time.Time) error {
 	s.touchedIDs = append(s.touchedIDs, id)
 	s.touchedUsedAts = append(s.touchedUsedAts, usedAt)
@@ -146,6 +150,18 @@ func (s *apiKeyRepoStub) GetRateLimitData(ctx context.Context, id int64) (*APIKe
 	panic("unexpected GetRateLimitData call")
 }
 
+func (s *apiKeyRepoStub) IncrementBudgetUsed(ctx context.Context, id int64, amount float64, periodStart time.Time) (float64, error) {
+	panic("unexpected IncrementBudgetUsed call")
+}
+
+func (s *apiKeyRepoStub) MarkBudgetExceeded(ctx context.Context, id int64, periodStart, exceededAt time.Time) (bool, error) {
+	panic("unexpected MarkBudgetExceeded call")
+}
+
+func (s *apiKeyRepoStub) RotateKey(ctx context.Context, id int64, oldKey, newKey string, graceUntil *time.Time) error {
+	panic("unexpected RotateKey call")
+}
+
 // apiKeyCacheStub 是 APIKeyCache 接口的测试桩实现。
 // 用于验证删除操作时缓存清理逻辑是否被正确调用。
 //
@@ -0,0 +1,250 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/ShaohongDong/sub2api/internal/config"
+	"github.com/tidwall/gjson"
+	"github.com/tidwall/sjson"
+)
+
+// 系统提示词注入方式
+const (
+	SystemPromptModePrepend = "prepend" // 置于客户端系统提示词之前（默认）
+	SystemPromptModeAppend  = "append"  // 置于客户端系统提示词之后
+	SystemPromptModeReplace = "replace" // 丢弃客户端系统提示词，仅保留注入内容
+)
+
+// 系统提示词注入额外支持的入口请求格式；Anthropic / Responses / Chat Completions 沿用 ReasoningFormat* 常量
+// （Ollama /api/chat 的 messages 结构与 Chat Completions 相同）
+const (
+	SystemPromptFormatGemini         = "gemini"          // /v1beta/models/*：systemInstruction.parts
+	SystemPromptFormatOllamaGenerate = "ollama_generate" // /api/generate：system
+)
+
+// maxAPIKeySystemPromptLength Key 级系统提示词的最大长度（字符）
+const maxAPIKeySystemPromptLength = 32000
+
+// normalizeAPIKeySystemPrompt 规范化 Key 级系统提示词：去除首尾空白，注入方式小写；提示词为空时清空注入方式
+func normalizeAPIKeySystemPrompt(apiKey *APIKey) error {
+	apiKey.SystemPrompt = strings.TrimSpace(apiKey.SystemPrompt)
+	apiKey.SystemPromptMode = strings.ToLower(strings.TrimSpace(apiKey.SystemPromptMode))
+	if apiKey.SystemPrompt == "" {
+		apiKey.SystemPromptMode = ""
+		return nil
+	}
+	if len([]rune(apiKey.SystemPrompt)) > maxAPIKeySystemPromptLength {
+		return ErrInvalidAPIKeySystemPrompt.WithMetadata(map[string]string{"reason": "system prompt is too long"})
+	}
+	switch apiKey.SystemPromptMode {
+	case "":
+		apiKey.SystemPromptMode = SystemPromptModePrepend
+	case SystemPromptModePrepend, SystemPromptModeAppend, SystemPromptModeReplace:
+	default:
+		return ErrInvalidAPIKeySystemPrompt.WithMetadata(map[string]string{"reason": "system_prompt_mode must be prepend, append or replace"})
+	}
+	return nil
+}
+
+// SetSystemPrompt 设置 Key 级系统提示词（仅管理员可设置，用户无法通过自助接口修改或清除）；prompt 为空表示清除
+func (s *APIKeyService) SetSystemPrompt(ctx context.Context, id int64, prompt, mode string) (*APIKey, error) {
+	apiKey, err := s.apiKeyRepo.GetByID(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("get api key: %w", err)
+	}
+	apiKey.SystemPrompt = prompt
+	apiKey.SystemPromptMode = mode
+	if err := normalizeAPIKeySystemPrompt(apiKey); err != nil {
+		return nil, err
+	}
+	if err := s.apiKeyRepo.Update(ctx, apiKey); err != nil {
+		return nil, fmt.Errorf("update api key: %w", err)
+	}
+
+	s.InvalidateAuthCacheByKey(ctx, apiKey.Key)
+	s.compileAPIKeyIPRules(apiKey)
+	return apiKey, nil
+}
+
+// MatchSystemPromptRule 返回首条命中（入口路径前缀与请求模型均匹配）的 gateway.system_prompts 规则，未命中返回 nil
+func MatchSystemPromptRule(rules []config.GatewaySystemPromptRule, path, model string) *config.GatewaySystemPromptRule {
+	for i := range rules {
+		rule := &rules[i]
+		if len(rule.Paths) > 0 && !systemPromptPathMatches(rule.Paths, path) {
+			continue
+		}
+		if len(rule.Models) > 0 && !routingModelMatches(rule.Models, model) {
+			continue
+		}
+		return rule
+	}
+	return nil
+}
+
+func systemPromptPathMatches(prefixes []string, path string) bool {
+	for _, prefix := range prefixes {
+		if strings.HasPrefix(path, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// ApplySystemPrompt 按入口格式把系统提示词写入请求体，写入后再由各网关按目标平台转换：
+//   - Anthropic：system（字符串或文本块数组）
+//   - Chat Completions：messages 开头的 system / developer 消息
+//   - Responses：instructions
+//   - Gemini：systemInstruction.parts
+//   - Ollama generate：system
+//
+// 无法安全写入（请求体结构不符合预期）时返回 false 并保持原请求体。
+func ApplySystemPrompt(body []byte, format, mode, prompt string) ([]byte, bool) {
+	if prompt == "" || !gjson.ValidBytes(body) {
+		return body, false
+	}
+	var out []byte
+	var err error
+	switch format {
+	case ReasoningFormatAnthropic:
+		out, err = applySystemPromptText(body, "system", mode, prompt, `{"type":"text","text":`)
+	case ReasoningFormatResponses:
+		out, err = applySystemPromptText(body, "instructions", mode, prompt, "")
+	case ReasoningFormatChatCompletions:
+		out, err = applyChatSystemPrompt(body, mode, prompt)
+	case SystemPromptFormatGemini:
+		out, err = applyGeminiSystemPrompt(body, mode, prompt)
+	case SystemPromptFormatOllamaGenerate:
+		out, err = applySystemPromptText(body, "system", mode, prompt, "")
+	default:
+		return body, false
+	}
+	if err != nil || out == nil {
+		return body, false
+	}
+	return out, true
+}
+
+// errSystemPromptShape 请求体中的系统提示词字段类型不符合预期
+var errSystemPromptShape = errors.New("unexpected system prompt shape")
+
+// applySystemPromptText 处理字符串形式的系统提示词字段；blockPrefix 非空时字段也可以是文本块数组
+func applySystemPromptText(body []byte, path, mode, prompt, blockPrefix string) ([]byte, error) {
+	current := gjson.GetBytes(body, path)
+	if mode == SystemPromptModeReplace || !current.Exists() || current.Type == gjson.Null ||
+		(current.Type == gjson.String && current.String() == "") {
+		return sjson.SetBytes(body, path, prompt)
+	}
+	switch {
+	case current.Type == gjson.String:
+		return sjson.SetBytes(body, path, joinSystemPrompt(mode, prompt, current.String()))
+	case current.IsArray() && blockPrefix != "":
+		block, err := json.Marshal(prompt)
+		if err != nil {
+			return nil, err
+		}
+		return insertSystemPromptItem(body, path, current, []byte(blockPrefix+string(block)+"}"), systemPromptInsertIndex(mode, current))
+	default:
+		return nil, errSystemPromptShape
+	}
+}
+
+// applyChatSystemPrompt 处理 Chat Completions：replace 移除开头连续的 system / developer 消息后插入新的 system 消息，
+// prepend 插入到最前，append 插入到开头连续的 system / developer 消息之后
+func applyChatSystemPrompt(body []byte, mode, prompt string) ([]byte, error) {
+	messages := gjson.GetBytes(body, "messages")
+	if !messages.IsArray() {
+		return nil, errSystemPromptShape
+	}
+	content, err := json.Marshal(prompt)
+	if err != nil {
+		return nil, err
+	}
+	item := []byte(`{"role":"system","content":` + string(content) + `}`)
+
+	leading := 0
+	for _, msg := range messages.Array() {
+		role := msg.Get("role").String()
+		if role != "system" && role != "developer" {
+			break
+		}
+		leading++
+	}
+	switch mode {
+	case SystemPromptModeReplace:
+		items := []json.RawMessage{item}
+		for i, msg := range messages.Array() {
+			if i >= leading {
+				items = append(items, json.RawMessage(msg.Raw))
+			}
+		}
+		raw, err := json.Marshal(items)
+		if err != nil {
+			return nil, err
+		}
+		return sjson.SetRawBytes(body, "messages", raw)
+	case SystemPromptModeAppend:
+		return insertSystemPromptItem(body, "messages", messages, item, leading)
+	default:
+		return insertSystemPromptItem(body, "messages", messages, item, 0)
+	}
+}
+
+// applyGeminiSystemPrompt 处理 Gemini：systemInstruction / system_instruction 的 parts 数组
+func applyGeminiSystemPrompt(body []byte, mode, prompt string) ([]byte, error) {
+	text, err := json.Marshal(prompt)
+	if err != nil {
+		return nil, err
+	}
+	part := []byte(`{"text":` + string(text) + `}`)
+	path := "systemInstruction"
+	if !gjson.GetBytes(body, path).Exists() && gjson.GetBytes(body, "system_instruction").Exists() {
+		path = "system_instruction"
+	}
+	parts := gjson.GetBytes(body, path+".parts")
+	if mode == SystemPromptModeReplace || !parts.Exists() || parts.Type == gjson.Null {
+		return sjson.SetRawBytes(body, path, []byte(`{"parts":[`+string(part)+`]}`))
+	}
+	if !parts.IsArray() {
+		return nil, errSystemPromptShape
+	}
+	return insertSystemPromptItem(body, path+".parts", parts, part, systemPromptInsertIndex(mode, parts))
+}
+
+// systemPromptInsertIndex 返回注入项在数组中的位置：append 追加到末尾，否则插入到最前
+func systemPromptInsertIndex(mode string, arr gjson.Result) int {
+	if mode == SystemPromptModeAppend {
+		return len(arr.Array())
+	}
+	return 0
+}
+
+// insertSystemPromptItem 在数组字段的 index 处插入一项
+func insertSystemPromptItem(body []byte, path string, arr gjson.Result, item []byte, index int) ([]byte, error) {
+	existing := arr.Array()
+	items := make([]json.RawMessage, 0, len(existing)+1)
+	for i, v := range existing {
+		if i == index {
+			items = append(items, item)
+		}
+		items = append(items, json.RawMessage(v.Raw))
+	}
+	if index >= len(existing) {
+		items = append(items, item)
+	}
+	raw, err := json.Marshal(items)
+	if err != nil {
+		return nil, err
+	}
+	return sjson.SetRawBytes(body, path, raw)
+}
+
+func joinSystemPrompt(mode, prompt, current string) string {
+	if mode == SystemPromptModeAppend {
+		return current + "\n\n" + prompt
+	}
+	return prompt + "\n\n" + current
+}
@@ -0,0 +1,127 @@
+//go:build unit
+
+package service
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/ShaohongDong/sub2api/internal/config"
+	"github.com/stretchr/testify/require"
+	"github.com/tidwall/gjson"
+)
+
+func TestNormalizeAPIKeySystemPrompt(t *testing.T) {
+	key := &APIKey{SystemPrompt: "  Be concise.  ", SystemPromptMode: " Append "}
+	require.NoError(t, normalizeAPIKeySystemPrompt(key))
+	require.Equal(t, "Be concise.", key.SystemPrompt)
+	require.Equal(t, SystemPromptModeAppend, key.SystemPromptMode)
+
+	key = &APIKey{SystemPrompt: "Be concise."}
+	require.NoError(t, normalizeAPIKeySystemPrompt(key))
+	require.Equal(t, SystemPromptModePrepend, key.SystemPromptMode)
+
+	key = &APIKey{SystemPrompt: " ", SystemPromptMode: "replace"}
+	require.NoError(t, normalizeAPIKeySystemPrompt(key))
+	require.Empty(t, key.SystemPromptMode)
+
+	key = &APIKey{SystemPrompt: "x", SystemPromptMode: "override"}
+	require.ErrorIs(t, normalizeAPIKeySystemPrompt(key), ErrInvalidAPIKeySystemPrompt)
+
+	key = &APIKey{SystemPrompt: strings.Repeat("x", maxAPIKeySystemPromptLength+1)}
+	require.ErrorIs(t, normalizeAPIKeySystemPrompt(key), ErrInvalidAPIKeySystemPrompt)
+}
+
+func TestMatchSystemPromptRule(t *testing.T) {
+	rules := []config.GatewaySystemPromptRule{
+		{Paths: []string{"/v1beta/"}, Mode: SystemPromptModePrepend, Prompt: "gemini"},
+		{Models: []string{"claude-*"}, Mode: SystemPromptModeAppend, Prompt: "claude"},
+		{Mode: SystemPromptModeReplace, Prompt: "default"},
+	}
+	require.Equal(t, "gemini", MatchSystemPromptRule(rules, "/v1beta/models/gemini-2.5-pro:generateContent", "gemini-2.5-pro").Prompt)
+	require.Equal(t, "claude", MatchSystemPromptRule(rules, "/v1/messages", "claude-sonnet-4").Prompt)
+	require.Equal(t, "default", MatchSystemPromptRule(rules, "/v1/chat/completions", "gpt-5").Prompt)
+	require.Nil(t, MatchSystemPromptRule(rules[:2], "/v1/chat/completions", "gpt-5"))
+}
+
+func TestApplySystemPromptAnthropic(t *testing.T) {
+	out, ok := ApplySystemPrompt([]byte(`{"model":"claude","messages":[]}`), ReasoningFormatAnthropic, SystemPromptModePrepend, "Guard")
+	require.True(t, ok)
+	require.Equal(t, "Guard", gjson.GetBytes(out, "system").String())
+
+	out, ok = ApplySystemPrompt([]byte(`{"system":"Client"}`), ReasoningFormatAnthropic, SystemPromptModePrepend, "Guard")
+	require.True(t, ok)
+	require.Equal(t, "Guard\n\nClient", gjson.GetBytes(out, "system").String())
+
+	out, ok = ApplySystemPrompt([]byte(`{"system":"Client"}`), ReasoningFormatAnthropic, SystemPromptModeAppend, "Guard")
+	require.True(t, ok)
+	require.Equal(t, "Client\n\nGuard", gjson.GetBytes(out, "system").String())
+
+	blocks := []byte(`{"system":[{"type":"text","text":"Client","cache_control":{"type":"ephemeral"}}]}`)
+	out, ok = ApplySystemPrompt(blocks, ReasoningFormatAnthropic, SystemPromptModePrepend, "Guard")
+	require.True(t, ok)
+	require.Equal(t, "Guard", gjson.GetBytes(out, "system.0.text").String())
+	require.Equal(t, "Client", gjson.GetBytes(out, "system.1.text").String())
+	require.Equal(t, "ephemeral", gjson.GetBytes(out, "system.1.cache_control.type").String())
+
+	out, ok = ApplySystemPrompt(blocks, ReasoningFormatAnthropic, SystemPromptModeAppend, "Guard")
+	require.True(t, ok)
+	require.Equal(t, "Guard", gjson.GetBytes(out, "system.1.text").String())
+
+	out, ok = ApplySystemPrompt(blocks, ReasoningFormatAnthropic, SystemPromptModeReplace, "Guard")
+	require.True(t, ok)
+	require.Equal(t, "Guard", gjson.GetBytes(out, "system").String())
+
+	_, ok = ApplySystemPrompt([]byte(`{"system":42}`), ReasoningFormatAnthropic, SystemPromptModePrepend, "Guard")
+	require.False(t, ok)
+}
+
+func TestApplySystemPromptChatCompletions(t *testing.T) {
+	body := []byte(`{"messages":[{"role":"system","content":"Client"},{"role":"developer","content":"Dev"},{"role":"user","content":"hi"}]}`)
+
+	out, ok := ApplySystemPrompt(body, ReasoningFormatChatCompletions, SystemPromptModePrepend, "Guard")
+	require.True(t, ok)
+	require.Equal(t, []string{"system:Guard", "system:Client", "developer:Dev", "user:hi"}, chatRoles(out))
+
+	out, ok = ApplySystemPrompt(body, ReasoningFormatChatCompletions, SystemPromptModeAppend, "Guard")
+	require.True(t, ok)
+	require.Equal(t, []string{"system:Client", "developer:Dev", "system:Guard", "user:hi"}, chatRoles(out))
+
+	out, ok = ApplySystemPrompt(body, ReasoningFormatChatCompletions, SystemPromptModeReplace, "Guard")
+	require.True(t, ok)
+	require.Equal(t, []string{"system:Guard", "user:hi"}, chatRoles(out))
+
+	_, ok = ApplySystemPrompt([]byte(`{"prompt":"hi"}`), ReasoningFormatChatCompletions, SystemPromptModePrepend, "Guard")
+	require.False(t, ok)
+}
+
+func chatRoles(body []byte) []string {
+	var roles []string
+	for _, msg := range gjson.GetBytes(body, "messages").Array() {
+		roles = append(roles, msg.Get("role").String()+":"+msg.Get("content").String())
+	}
+	return roles
+}
+
+func TestApplySystemPromptResponsesAndGemini(t *testing.T) {
+	out, ok := ApplySystemPrompt([]byte(`{"instructions":"Client","input":"hi"}`), ReasoningFormatResponses, SystemPromptModePrepend, "Guard")
+	require.True(t, ok)
+	require.Equal(t, "Guard\n\nClient", gjson.GetBytes(out, "instructions").String())
+
+	out, ok = ApplySystemPrompt([]byte(`{"contents":[]}`), SystemPromptFormatGemini, SystemPromptModeAppend, "Guard")
+	require.True(t, ok)
+	require.Equal(t, "Guard", gjson.GetBytes(out, "systemInstruction.parts.0.text").String())
+
+	out, ok = ApplySystemPrompt([]byte(`{"system_instruction":{"parts":[{"text":"Client"}]}}`), SystemPromptFormatGemini, SystemPromptModeAppend, "Guard")
+	require.True(t, ok)
+	require.Equal(t, "Client", gjson.GetBytes(out, "system_instruction.parts.0.text").String())
+	require.Equal(t, "Guard", gjson.GetBytes(out, "system_instruction.parts.1.text").String())
+	require.False(t, gjson.GetBytes(out, "systemInstruction").Exists())
+
+	out, ok = ApplySystemPrompt([]byte(`{"prompt":"hi","system":"Client"}`), SystemPromptFormatOllamaGenerate, SystemPromptModeReplace, "Guard")
+	require.True(t, ok)
+	require.Equal(t, "Guard", gjson.GetBytes(out, "system").String())
+
+	_, ok = ApplySystemPrompt([]byte(`not json`), ReasoningFormatResponses, SystemPromptModePrepend, "Guard")
+	require.False(t, ok)
+}
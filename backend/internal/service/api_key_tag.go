@@ -0,0 +1,81 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+
+	infraerrors "github.com/ShaohongDong/sub2api/internal/pkg/errors"
+)
+
+// maxAPIKeyTags 单个 Key 的最大标签数
+const maxAPIKeyTags = 20
+
+// maxAPIKeyTagLen 单个标签的最大长度
+const maxAPIKeyTagLen = 32
+
+var ErrInvalidAPIKeyTags = infraerrors.BadRequest("INVALID_API_KEY_TAGS", "invalid api key tags (up to 20 tags of letters, digits, '-', '_', '.' or ':')")
+
+// normalizeAPIKeyTags 去除空白、转小写并去重；标签仅允许字母、数字与 - _ . :
+func normalizeAPIKeyTags(tags []string) ([]string, error) {
+	if len(tags) == 0 {
+		return nil, nil
+	}
+	out := make([]string, 0, len(tags))
+	for _, tag := range tags {
+		tag = strings.ToLower(strings.TrimSpace(tag))
+		if tag == "" {
+			continue
+		}
+		if len(tag) > maxAPIKeyTagLen || strings.IndexFunc(tag, func(r rune) bool {
+			return !(r >= 'a' && r <= 'z' || r >= '0' && r <= '9' || r == '-' || r == '_' || r == '.' || r == ':')
+		}) >= 0 {
+			return nil, ErrInvalidAPIKeyTags.WithMetadata(map[string]string{"tag": tag})
+		}
+		if !slices.Contains(out, tag) {
+			out = append(out, tag)
+		}
+	}
+	if len(out) > maxAPIKeyTags {
+		return nil, ErrInvalidAPIKeyTags.WithMetadata(map[string]string{"reason": "too many tags"})
+	}
+	if len(out) == 0 {
+		return nil, nil
+	}
+	return out, nil
+}
+
+// HasAnyTag 判断 Key 是否带有 tags 中的任一标签
+func (k *APIKey) HasAnyTag(tags []string) bool {
+	if k == nil {
+		return false
+	}
+	for _, tag := range tags {
+		if slices.Contains(k.Tags, tag) {
+			return true
+		}
+	}
+	return false
+}
+
+// SetTags 设置 Key 的标签（仅管理员可用，标签参与路由规则匹配，不允许用户自行修改）
+func (s *APIKeyService) SetTags(ctx context.Context, id int64, tags []string) (*APIKey, error) {
+	normalized, err := normalizeAPIKeyTags(tags)
+	if err != nil {
+		return nil, err
+	}
+
+	apiKey, err := s.apiKeyRepo.GetByID(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("get api key: %w", err)
+	}
+	apiKey.Tags = normalized
+	if err := s.apiKeyRepo.Update(ctx, apiKey); err != nil {
+		return nil, fmt.Errorf("update api key: %w", err)
+	}
+
+	s.InvalidateAuthCacheByKey(ctx, apiKey.Key)
+	s.compileAPIKeyIPRules(apiKey)
+	return apiKey, nil
+}
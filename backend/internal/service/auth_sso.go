@@ -0,0 +1,138 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/mail"
+	"strings"
+
+	"github.com/ShaohongDong/sub2api/internal/config"
+	infraerrors "github.com/ShaohongDong/sub2api/internal/pkg/errors"
+	"github.com/ShaohongDong/sub2api/internal/pkg/logger"
+)
+
+var ErrSSORoleNotGranted = infraerrors.Forbidden("SSO_ROLE_NOT_GRANTED", "identity provider groups do not grant console access")
+
+// ResolveOIDCRole 根据 IdP 组映射出管理后台角色：取所有命中映射中的最高角色，
+// 无命中时回退 defaultRole；返回空串表示拒绝登录。组名比较区分大小写。
+func ResolveOIDCRole(groups []string, mappings []config.OIDCRoleMapping, defaultRole string) string {
+	member := make(map[string]struct{}, len(groups))
+	for _, g := range groups {
+		member[g] = struct{}{}
+	}
+
+	best := ""
+	for _, m := range mappings {
+		if _, ok := member[m.Group]; !ok {
+			continue
+		}
+		if adminRoleLevel(m.Role) > adminRoleLevel(best) {
+			best = m.Role
+		}
+	}
+	if best == "" && adminRoleLevel(defaultRole) > 0 {
+		best = defaultRole
+	}
+	return best
+}
+
+// LoginOrProvisionSSOUser 管理后台 OIDC 单点登录：按邮箱查找或创建用户，并把角色同步为 IdP 映射结果。
+// 与普通 OAuth 登录不同，首次登录不受"开放注册"开关限制（访问由 IdP 组映射控制）；
+// 已是 admin 的账号不会被降级，以免 IdP 配置错误锁死超级管理员。
+func (s *AuthService) LoginOrProvisionSSOUser(ctx context.Context, email, username, role string) (*TokenPair, *User, error) {
+	if s.refreshTokenCache == nil {
+		return nil, nil, errors.New("refresh token cache not configured")
+	}
+	if adminRoleLevel(role) == 0 {
+		return nil, nil, ErrSSORoleNotGranted
+	}
+
+	email = strings.TrimSpace(email)
+	if email == "" || len(email) > 255 {
+		return nil, nil, infraerrors.BadRequest("INVALID_EMAIL", "invalid email")
+	}
+	if _, err := mail.ParseAddress(email); err != nil {
+		return nil, nil, infraerrors.BadRequest("INVALID_EMAIL", "invalid email")
+	}
+
+	username = strings.TrimSpace(username)
+	if len([]rune(username)) > 100 {
+		username = string([]rune(username)[:100])
+	}
+
+	user, err := s.userRepo.GetByEmail(ctx, email)
+	if err != nil {
+		if !errors.Is(err, ErrUserNotFound) {
+			logger.LegacyPrintf("service.auth", "[Auth] Database error during sso login: %v", err)
+			return nil, nil, ErrServiceUnavailable
+		}
+
+		randomPassword, err := randomHexString(32)
+		if err != nil {
+			logger.LegacyPrintf("service.auth", "[Auth] Failed to generate random password for sso user: %v", err)
+			return nil, nil, ErrServiceUnavailable
+		}
+		hashedPassword, err := s.HashPassword(randomPassword)
+		if err != nil {
+			return nil, nil, fmt.Errorf("hash password: %w", err)
+		}
+
+		defaultBalance := s.cfg.Default.UserBalance
+		defaultConcurrency := s.cfg.Default.UserConcurrency
+		if s.settingService != nil {
+			defaultBalance = s.settingService.GetDefaultBalance(ctx)
+			defaultConcurrency = s.settingService.GetDefaultConcurrency(ctx)
+		}
+
+		newUser := &User{
+			Email:        email,
+			Username:     username,
+			PasswordHash: hashedPassword,
+			Role:         role,
+			Balance:      defaultBalance,
+			Concurrency:  defaultConcurrency,
+			Status:       StatusActive,
+		}
+		if err := s.userRepo.Create(ctx, newUser); err != nil {
+			if !errors.Is(err, ErrEmailExists) {
+				logger.LegacyPrintf("service.auth", "[Auth] Database error creating sso user: %v", err)
+				return nil, nil, ErrServiceUnavailable
+			}
+			user, err = s.userRepo.GetByEmail(ctx, email)
+			if err != nil {
+				logger.LegacyPrintf("service.auth", "[Auth] Database error getting user after conflict: %v", err)
+				return nil, nil, ErrServiceUnavailable
+			}
+		} else {
+			user = newUser
+		}
+	}
+
+	if !user.IsActive() {
+		return nil, nil, ErrUserNotActive
+	}
+
+	changed := false
+	if user.Role != role && user.Role != RoleAdmin {
+		logger.LegacyPrintf("service.auth", "[Auth] SSO role sync: user_id=%d %s -> %s", user.ID, user.Role, role)
+		user.Role = role
+		changed = true
+	}
+	if user.Username == "" && username != "" {
+		user.Username = username
+		changed = true
+	}
+	if changed {
+		if err := s.userRepo.Update(ctx, user); err != nil {
+			logger.LegacyPrintf("service.auth", "[Auth] Failed to update user after sso login: %v", err)
+			return nil, nil, ErrServiceUnavailable
+		}
+	}
+
+	tokenPair, err := s.GenerateTokenPair(ctx, user, "")
+	if err != nil {
+		return nil, nil, fmt.Errorf("generate token pair: %w", err)
+	}
+	return tokenPair, user, nil
+}
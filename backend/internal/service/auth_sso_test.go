@@ -0,0 +1,129 @@
+//go:build unit
+
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/ShaohongDong/sub2api/internal/config"
+	"github.com/stretchr/testify/require"
+)
+
+type ssoUserRepoStub struct {
+	UserRepository
+
+	users   map[string]*User
+	nextID  int64
+	updates int
+}
+
+func (s *ssoUserRepoStub) GetByEmail(ctx context.Context, email string) (*User, error) {
+	u, ok := s.users[email]
+	if !ok {
+		return nil, ErrUserNotFound
+	}
+	clone := *u
+	return &clone, nil
+}
+
+func (s *ssoUserRepoStub) Create(ctx context.Context, user *User) error {
+	s.nextID++
+	user.ID = s.nextID
+	clone := *user
+	s.users[user.Email] = &clone
+	return nil
+}
+
+func (s *ssoUserRepoStub) Update(ctx context.Context, user *User) error {
+	s.updates++
+	clone := *user
+	s.users[user.Email] = &clone
+	return nil
+}
+
+type ssoRefreshTokenCacheStub struct {
+	RefreshTokenCache
+}
+
+func (ssoRefreshTokenCacheStub) StoreRefreshToken(ctx context.Context, tokenHash string, data *RefreshTokenData, ttl time.Duration) error {
+	return nil
+}
+
+func (ssoRefreshTokenCacheStub) AddToUserTokenSet(ctx context.Context, userID int64, tokenHash string, ttl time.Duration) error {
+	return nil
+}
+
+func (ssoRefreshTokenCacheStub) AddToFamilyTokenSet(ctx context.Context, familyID string, tokenHash string, ttl time.Duration) error {
+	return nil
+}
+
+func newSSOAuthService(repo *ssoUserRepoStub) *AuthService {
+	cfg := &config.Config{
+		JWT:     config.JWTConfig{Secret: "test-secret", ExpireHour: 1},
+		Default: config.DefaultConfig{UserBalance: 1, UserConcurrency: 2},
+	}
+	return NewAuthService(repo, nil, ssoRefreshTokenCacheStub{}, cfg, nil, nil, nil, nil, nil, nil)
+}
+
+func TestResolveOIDCRole(t *testing.T) {
+	mappings := []config.OIDCRoleMapping{
+		{Group: "gw-viewers", Role: RoleViewer},
+		{Group: "gw-admins", Role: RoleAdmin},
+		{Group: "gw-ops", Role: RoleOperator},
+	}
+
+	require.Equal(t, RoleOperator, ResolveOIDCRole([]string{"gw-viewers", "gw-ops"}, mappings, ""))
+	require.Equal(t, RoleAdmin, ResolveOIDCRole([]string{"gw-admins", "gw-viewers"}, mappings, RoleViewer))
+	require.Equal(t, "", ResolveOIDCRole([]string{"GW-OPS"}, mappings, ""), "group names are case sensitive")
+	require.Equal(t, RoleViewer, ResolveOIDCRole(nil, mappings, RoleViewer))
+	require.Equal(t, "", ResolveOIDCRole(nil, mappings, RoleUser), "default role must be a console role")
+}
+
+func TestLoginOrProvisionSSOUser_CreatesUserWithMappedRole(t *testing.T) {
+	repo := &ssoUserRepoStub{users: map[string]*User{}}
+	svc := newSSOAuthService(repo)
+
+	pair, user, err := svc.LoginOrProvisionSSOUser(context.Background(), "ops@example.com", "ops", RoleOperator)
+	require.NoError(t, err)
+	require.NotEmpty(t, pair.AccessToken)
+	require.Equal(t, RoleOperator, user.Role)
+	require.Equal(t, StatusActive, repo.users["ops@example.com"].Status)
+	require.Equal(t, 2, repo.users["ops@example.com"].Concurrency)
+}
+
+func TestLoginOrProvisionSSOUser_SyncsRoleButNeverDemotesAdmin(t *testing.T) {
+	repo := &ssoUserRepoStub{users: map[string]*User{
+		"v@example.com": {ID: 1, Email: "v@example.com", Username: "v", Role: RoleOperator, Status: StatusActive},
+		"a@example.com": {ID: 2, Email: "a@example.com", Username: "a", Role: RoleAdmin, Status: StatusActive},
+	}}
+	svc := newSSOAuthService(repo)
+
+	_, user, err := svc.LoginOrProvisionSSOUser(context.Background(), "v@example.com", "", RoleViewer)
+	require.NoError(t, err)
+	require.Equal(t, RoleViewer, user.Role)
+	require.Equal(t, RoleViewer, repo.users["v@example.com"].Role)
+
+	_, user, err = svc.LoginOrProvisionSSOUser(context.Background(), "a@example.com", "", RoleViewer)
+	require.NoError(t, err)
+	require.Equal(t, RoleAdmin, user.Role)
+	require.Equal(t, 1, repo.updates)
+}
+
+func TestLoginOrProvisionSSOUser_Rejects(t *testing.T) {
+	repo := &ssoUserRepoStub{users: map[string]*User{
+		"off@example.com": {ID: 1, Email: "off@example.com", Role: RoleViewer, Status: StatusDisabled},
+	}}
+	svc := newSSOAuthService(repo)
+
+	_, _, err := svc.LoginOrProvisionSSOUser(context.Background(), "new@example.com", "", RoleUser)
+	require.ErrorIs(t, err, ErrSSORoleNotGranted)
+	require.NotContains(t, repo.users, "new@example.com")
+
+	_, _, err = svc.LoginOrProvisionSSOUser(context.Background(), "off@example.com", "", RoleViewer)
+	require.ErrorIs(t, err, ErrUserNotActive)
+
+	_, _, err = svc.LoginOrProvisionSSOUser(context.Background(), "not-an-email", "", RoleViewer)
+	require.Error(t, err)
+}
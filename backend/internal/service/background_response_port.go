@@ -0,0 +1,67 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	infraerrors "github.com/ShaohongDong/sub2api/internal/pkg/errors"
+)
+
+// Background response statuses (OpenAI Responses API compatible).
+const (
+	BackgroundResponseStatusQueued     = "queued"
+	BackgroundResponseStatusInProgress = "in_progress"
+	BackgroundResponseStatusCompleted  = "completed"
+	BackgroundResponseStatusFailed     = "failed"
+	BackgroundResponseStatusCancelled  = "cancelled"
+)
+
+var (
+	ErrBackgroundResponseNotFound      = infraerrors.NotFound("BACKGROUND_RESPONSE_NOT_FOUND", "response not found")
+	ErrBackgroundResponseDisabled      = infraerrors.ServiceUnavailable("BACKGROUND_RESPONSE_DISABLED", "background responses are disabled")
+	ErrBackgroundResponseTooManyActive = infraerrors.TooManyRequests("BACKGROUND_RESPONSE_TOO_MANY_ACTIVE", "too many queued background responses for this api key")
+	ErrBackgroundResponseNotCancelable = infraerrors.Conflict("BACKGROUND_RESPONSE_NOT_CANCELABLE", "response has already finished and can no longer be cancelled")
+)
+
+// BackgroundResponse is one /v1/responses request submitted with background=true.
+type BackgroundResponse struct {
+	ID             string
+	UserID         int64
+	APIKeyID       int64
+	Model          string
+	Status         string
+	ClientIP       string
+	RequestBody    []byte
+	Attempts       int
+	ResponseStatus int
+	ResponseBody   []byte
+	RequestID      string
+	ErrorMessage   string
+	StartedAt      *time.Time
+	CompletedAt    *time.Time
+	CreatedAt      time.Time
+	UpdatedAt      time.Time
+}
+
+// IsActive reports whether the worker still has to process the response.
+func (r *BackgroundResponse) IsActive() bool {
+	return r.Status == BackgroundResponseStatusQueued || r.Status == BackgroundResponseStatusInProgress
+}
+
+// BackgroundResponseRepository defines the data access interface for background responses.
+type BackgroundResponseRepository interface {
+	Create(ctx context.Context, resp *BackgroundResponse) error
+	Get(ctx context.Context, id string) (*BackgroundResponse, error)
+	CountActiveByAPIKey(ctx context.Context, apiKeyID int64) (int, error)
+
+	// Claim 认领排队中的请求（含执行超时的 in_progress 项），并发安全。
+	Claim(ctx context.Context, limit int, staleBefore time.Time) ([]*BackgroundResponse, error)
+	// Finish 写入执行结果；已被取消的请求保持 cancelled 不变。
+	Finish(ctx context.Context, resp *BackgroundResponse) error
+	Retry(ctx context.Context, id string, nextAttemptAt time.Time, errMsg string) error
+	// Cancel 将未结束的请求标记为 cancelled，返回是否发生了状态变更。
+	Cancel(ctx context.Context, id string) (bool, error)
+	Delete(ctx context.Context, id string) error
+
+	DeleteFinishedBefore(ctx context.Context, before time.Time) (int64, error)
+}
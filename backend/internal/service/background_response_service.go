@@ -0,0 +1,423 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/ShaohongDong/sub2api/internal/config"
+	infraerrors "github.com/ShaohongDong/sub2api/internal/pkg/errors"
+	"github.com/ShaohongDong/sub2api/internal/pkg/logger"
+	"github.com/google/uuid"
+	"github.com/tidwall/gjson"
+	"github.com/tidwall/sjson"
+)
+
+const (
+	backgroundResponseWorkerName = "background_response_worker"
+	backgroundResponseEndpoint   = "/v1/responses"
+)
+
+// BackgroundResponseService 负责 Responses API background 模式：请求持久化后立即返回 queued，
+// 后台执行器将其以非流式请求重放到 /v1/responses，结果落库供 GET /v1/responses/{id} 轮询。
+//
+// 与 BatchService 一样经由 BatchRequestDispatcher 在进程内执行，
+// 鉴权、分组调度、并发/限流与计费逻辑与普通请求一致；客户端断开不会中断执行。
+type BackgroundResponseService struct {
+	repo        BackgroundResponseRepository
+	apiKeyRepo  APIKeyRepository
+	timingWheel *TimingWheelService
+	cfg         *config.Config
+
+	dispatcherMu sync.RWMutex
+	dispatcher   BatchRequestDispatcher
+
+	// slots 限制全局同时执行的请求数；执行跨越多个轮询周期，不随 runOnce 返回而释放
+	slots chan struct{}
+
+	inflightMu sync.Mutex
+	inflight   map[string]context.CancelFunc
+
+	running   int32
+	startOnce sync.Once
+	stopOnce  sync.Once
+
+	workerCtx    context.Context
+	workerCancel context.CancelFunc
+}
+
+func NewBackgroundResponseService(repo BackgroundResponseRepository, apiKeyRepo APIKeyRepository, timingWheel *TimingWheelService, cfg *config.Config) *BackgroundResponseService {
+	workerCtx, workerCancel := context.WithCancel(context.Background())
+	s := &BackgroundResponseService{
+		repo:         repo,
+		apiKeyRepo:   apiKeyRepo,
+		timingWheel:  timingWheel,
+		cfg:          cfg,
+		inflight:     make(map[string]context.CancelFunc),
+		workerCtx:    workerCtx,
+		workerCancel: workerCancel,
+	}
+	s.slots = make(chan struct{}, s.workerConcurrency())
+	return s
+}
+
+// SetDispatcher 注入请求分发器（路由构建完成后由 server 层设置）。
+func (s *BackgroundResponseService) SetDispatcher(d BatchRequestDispatcher) {
+	if s == nil {
+		return
+	}
+	s.dispatcherMu.Lock()
+	s.dispatcher = d
+	s.dispatcherMu.Unlock()
+}
+
+func (s *BackgroundResponseService) getDispatcher() BatchRequestDispatcher {
+	s.dispatcherMu.RLock()
+	defer s.dispatcherMu.RUnlock()
+	return s.dispatcher
+}
+
+// Enabled 关闭时 background 字段原样转发给上游。
+func (s *BackgroundResponseService) Enabled() bool {
+	return s != nil && s.repo != nil && (s.cfg == nil || s.cfg.BackgroundResponses.Enabled)
+}
+
+func (s *BackgroundResponseService) Start() {
+	if s == nil {
+		return
+	}
+	if s.cfg != nil && !s.cfg.BackgroundResponses.Enabled {
+		logger.LegacyPrintf("service.background_response", "[BackgroundResponse] not started (disabled)")
+		return
+	}
+	if s.repo == nil || s.timingWheel == nil {
+		logger.LegacyPrintf("service.background_response", "[BackgroundResponse] not started (missing deps)")
+		return
+	}
+
+	interval := s.workerInterval()
+	s.startOnce.Do(func() {
+		s.timingWheel.ScheduleRecurring(backgroundResponseWorkerName, interval, s.runOnce)
+		logger.LegacyPrintf("service.background_response", "[BackgroundResponse] started (interval=%s worker_concurrency=%d)", interval, cap(s.slots))
+	})
+}
+
+func (s *BackgroundResponseService) Stop() {
+	if s == nil {
+		return
+	}
+	s.stopOnce.Do(func() {
+		if s.workerCancel != nil {
+			s.workerCancel()
+		}
+		if s.timingWheel != nil {
+			s.timingWheel.Cancel(backgroundResponseWorkerName)
+		}
+		logger.LegacyPrintf("service.background_response", "[BackgroundResponse] stopped")
+	})
+}
+
+// Create 持久化 background=true 的 Responses 请求并返回 queued 状态的记录。
+func (s *BackgroundResponseService) Create(ctx context.Context, apiKey *APIKey, body []byte, clientIP string) (*BackgroundResponse, error) {
+	if !s.Enabled() {
+		return nil, ErrBackgroundResponseDisabled
+	}
+	if apiKey == nil {
+		return nil, infraerrors.Unauthorized("BACKGROUND_RESPONSE_INVALID_API_KEY", "invalid api key")
+	}
+	requestBody, err := PrepareBackgroundResponseBody(body)
+	if err != nil {
+		return nil, err
+	}
+
+	if limit := s.maxActivePerKey(); limit > 0 {
+		active, err := s.repo.CountActiveByAPIKey(ctx, apiKey.ID)
+		if err != nil {
+			return nil, fmt.Errorf("count active background responses: %w", err)
+		}
+		if active >= limit {
+			return nil, ErrBackgroundResponseTooManyActive
+		}
+	}
+
+	resp := &BackgroundResponse{
+		ID:          "resp_" + strings.ReplaceAll(uuid.NewString(), "-", ""),
+		UserID:      apiKey.UserID,
+		APIKeyID:    apiKey.ID,
+		Model:       gjson.GetBytes(requestBody, "model").String(),
+		Status:      BackgroundResponseStatusQueued,
+		ClientIP:    clientIP,
+		RequestBody: requestBody,
+	}
+	if err := s.repo.Create(ctx, resp); err != nil {
+		return nil, fmt.Errorf("create background response: %w", err)
+	}
+	logger.LegacyPrintf("service.background_response", "[BackgroundResponse] queued: response=%s api_key=%d model=%s", resp.ID, apiKey.ID, resp.Model)
+	go s.runOnce()
+	return resp, nil
+}
+
+// PrepareBackgroundResponseBody 校验请求体并去掉 background 字段，以非流式请求重放。
+// 流式 background 响应需要可恢复的事件流，暂不支持。
+func PrepareBackgroundResponseBody(body []byte) ([]byte, error) {
+	parsed := gjson.ParseBytes(body)
+	if !parsed.IsObject() {
+		return nil, infraerrors.BadRequest("BACKGROUND_RESPONSE_INVALID_BODY", "request body must be a JSON object")
+	}
+	if parsed.Get("stream").Bool() {
+		return nil, infraerrors.BadRequest("BACKGROUND_RESPONSE_STREAM_UNSUPPORTED", "stream is not supported with background mode; poll GET /v1/responses/{id} instead")
+	}
+	out, err := sjson.DeleteBytes(body, "background")
+	if err != nil {
+		return nil, infraerrors.BadRequest("BACKGROUND_RESPONSE_INVALID_BODY", "invalid request body")
+	}
+	if parsed.Get("stream").Exists() {
+		if out, err = sjson.DeleteBytes(out, "stream"); err != nil {
+			return nil, infraerrors.BadRequest("BACKGROUND_RESPONSE_INVALID_BODY", "invalid request body")
+		}
+	}
+	return out, nil
+}
+
+// Get 返回属于该 API Key 的 background 响应。
+func (s *BackgroundResponseService) Get(ctx context.Context, apiKeyID int64, id string) (*BackgroundResponse, error) {
+	if s == nil || s.repo == nil {
+		return nil, ErrBackgroundResponseNotFound
+	}
+	resp, err := s.repo.Get(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if resp.APIKeyID != apiKeyID {
+		return nil, ErrBackgroundResponseNotFound
+	}
+	return resp, nil
+}
+
+// Cancel 取消排队或执行中的请求；执行中的请求会中断其进程内调用。
+func (s *BackgroundResponseService) Cancel(ctx context.Context, apiKeyID int64, id string) (*BackgroundResponse, error) {
+	resp, err := s.Get(ctx, apiKeyID, id)
+	if err != nil {
+		return nil, err
+	}
+	if resp.Status == BackgroundResponseStatusCancelled {
+		return resp, nil
+	}
+	if !resp.IsActive() {
+		return nil, ErrBackgroundResponseNotCancelable
+	}
+	changed, err := s.repo.Cancel(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("cancel background response: %w", err)
+	}
+	s.abortInflight(id)
+	if !changed {
+		// 与执行器结束写入竞争，返回最新状态
+		return s.Get(ctx, apiKeyID, id)
+	}
+	resp.Status = BackgroundResponseStatusCancelled
+	logger.LegacyPrintf("service.background_response", "[BackgroundResponse] cancelled: response=%s", id)
+	return resp, nil
+}
+
+// Delete 删除记录；仍在执行的请求会被中断。
+func (s *BackgroundResponseService) Delete(ctx context.Context, apiKeyID int64, id string) error {
+	if _, err := s.Get(ctx, apiKeyID, id); err != nil {
+		return err
+	}
+	if err := s.repo.Delete(ctx, id); err != nil {
+		return fmt.Errorf("delete background response: %w", err)
+	}
+	s.abortInflight(id)
+	return nil
+}
+
+func (s *BackgroundResponseService) trackInflight(id string, cancel context.CancelFunc) {
+	s.inflightMu.Lock()
+	s.inflight[id] = cancel
+	s.inflightMu.Unlock()
+}
+
+func (s *BackgroundResponseService) untrackInflight(id string) {
+	s.inflightMu.Lock()
+	delete(s.inflight, id)
+	s.inflightMu.Unlock()
+}
+
+func (s *BackgroundResponseService) abortInflight(id string) {
+	s.inflightMu.Lock()
+	cancel := s.inflight[id]
+	s.inflightMu.Unlock()
+	if cancel != nil {
+		cancel()
+	}
+}
+
+func (s *BackgroundResponseService) runOnce() {
+	if s == nil || s.repo == nil {
+		return
+	}
+	if !atomic.CompareAndSwapInt32(&s.running, 0, 1) {
+		return
+	}
+	defer atomic.StoreInt32(&s.running, 0)
+
+	ctx := s.workerCtx
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	if ctx.Err() != nil {
+		return
+	}
+
+	if deleted, err := s.repo.DeleteFinishedBefore(ctx, time.Now().Add(-s.retention())); err != nil {
+		logger.LegacyPrintf("service.background_response", "[BackgroundResponse] cleanup failed: %v", err)
+	} else if deleted > 0 {
+		logger.LegacyPrintf("service.background_response", "[BackgroundResponse] cleanup removed %d finished responses", deleted)
+	}
+
+	dispatcher := s.getDispatcher()
+	if dispatcher == nil {
+		return
+	}
+	free := cap(s.slots) - len(s.slots)
+	if free <= 0 {
+		return
+	}
+	items, err := s.repo.Claim(ctx, free, time.Now().Add(-s.staleAfter()))
+	if err != nil {
+		logger.LegacyPrintf("service.background_response", "[BackgroundResponse] claim failed: %v", err)
+		return
+	}
+	for _, item := range items {
+		s.slots <- struct{}{}
+		go func(item *BackgroundResponse) {
+			defer func() {
+				<-s.slots
+				// 空出执行槽后立即认领排队中的请求，无需等待下一轮轮询
+				go s.runOnce()
+			}()
+			s.execute(ctx, dispatcher, item)
+		}(item)
+	}
+}
+
+// execute 执行单个请求：2xx 记为完成；429/过载在尝试次数内退避重排；其余记为失败。
+func (s *BackgroundResponseService) execute(ctx context.Context, dispatcher BatchRequestDispatcher, resp *BackgroundResponse) {
+	apiKey, err := s.apiKeyRepo.GetByID(ctx, resp.APIKeyID)
+	if err != nil {
+		resp.Status = BackgroundResponseStatusFailed
+		resp.ErrorMessage = "api key is no longer available"
+		s.finish(ctx, resp)
+		return
+	}
+
+	reqCtx, cancel := context.WithTimeout(ctx, s.requestTimeout())
+	s.trackInflight(resp.ID, cancel)
+	defer func() {
+		s.untrackInflight(resp.ID)
+		cancel()
+	}()
+
+	res, err := dispatcher.Dispatch(reqCtx, apiKey.Key, resp.ClientIP, http.MethodPost, backgroundResponseEndpoint, resp.RequestBody)
+	if ctx.Err() != nil {
+		// 服务停止：保持 in_progress，重启后按超时重新认领
+		return
+	}
+
+	if err != nil || batchRetryableStatus(res.StatusCode) {
+		if resp.Attempts < s.maxAttempts() {
+			var header http.Header
+			errMsg := ""
+			if err != nil {
+				errMsg = err.Error()
+			} else {
+				header = res.Header
+				errMsg = fmt.Sprintf("upstream returned %d", res.StatusCode)
+			}
+			next := time.Now().Add(batchRetryDelay(resp.Attempts, header))
+			if rerr := s.repo.Retry(ctx, resp.ID, next, errMsg); rerr != nil {
+				logger.LegacyPrintf("service.background_response", "[BackgroundResponse] requeue failed: response=%s err=%v", resp.ID, rerr)
+			}
+			return
+		}
+	}
+
+	if err != nil {
+		resp.Status = BackgroundResponseStatusFailed
+		resp.ErrorMessage = err.Error()
+	} else {
+		resp.ResponseStatus = res.StatusCode
+		resp.ResponseBody = res.Body
+		resp.RequestID = res.Header.Get("x-request-id")
+		if res.StatusCode >= 200 && res.StatusCode < 300 {
+			resp.Status = BackgroundResponseStatusCompleted
+		} else {
+			resp.Status = BackgroundResponseStatusFailed
+			resp.ErrorMessage = extractUpstreamErrorMessage(res.Body)
+			if resp.ErrorMessage == "" {
+				resp.ErrorMessage = http.StatusText(res.StatusCode)
+			}
+		}
+	}
+	s.finish(ctx, resp)
+}
+
+func (s *BackgroundResponseService) finish(ctx context.Context, resp *BackgroundResponse) {
+	if err := s.repo.Finish(ctx, resp); err != nil {
+		logger.LegacyPrintf("service.background_response", "[BackgroundResponse] save result failed: response=%s err=%v", resp.ID, err)
+		return
+	}
+	logger.LegacyPrintf("service.background_response", "[BackgroundResponse] finished: response=%s status=%s attempts=%d", resp.ID, resp.Status, resp.Attempts)
+}
+
+func (s *BackgroundResponseService) workerInterval() time.Duration {
+	if s.cfg != nil && s.cfg.BackgroundResponses.WorkerIntervalSeconds > 0 {
+		return time.Duration(s.cfg.BackgroundResponses.WorkerIntervalSeconds) * time.Second
+	}
+	return 5 * time.Second
+}
+
+func (s *BackgroundResponseService) workerConcurrency() int {
+	if s.cfg != nil && s.cfg.BackgroundResponses.WorkerConcurrency > 0 {
+		return s.cfg.BackgroundResponses.WorkerConcurrency
+	}
+	return 16
+}
+
+func (s *BackgroundResponseService) maxActivePerKey() int {
+	if s.cfg != nil {
+		return s.cfg.BackgroundResponses.MaxActivePerKey
+	}
+	return 0
+}
+
+func (s *BackgroundResponseService) maxAttempts() int {
+	if s.cfg != nil && s.cfg.BackgroundResponses.MaxAttempts > 0 {
+		return s.cfg.BackgroundResponses.MaxAttempts
+	}
+	return 5
+}
+
+func (s *BackgroundResponseService) requestTimeout() time.Duration {
+	if s.cfg != nil && s.cfg.BackgroundResponses.RequestTimeoutSeconds > 0 {
+		return time.Duration(s.cfg.BackgroundResponses.RequestTimeoutSeconds) * time.Second
+	}
+	return 30 * time.Minute
+}
+
+// staleAfter in_progress 状态超过该时长视为执行器已中断，可重新认领。
+func (s *BackgroundResponseService) staleAfter() time.Duration {
+	return s.requestTimeout() + time.Minute
+}
+
+func (s *BackgroundResponseService) retention() time.Duration {
+	if s.cfg != nil && s.cfg.BackgroundResponses.RetentionHours > 0 {
+		return time.Duration(s.cfg.BackgroundResponses.RetentionHours) * time.Hour
+	}
+	return 72 * time.Hour
+}
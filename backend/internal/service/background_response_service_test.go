@@ -0,0 +1,100 @@
+package service
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/ShaohongDong/sub2api/internal/config"
+	infraerrors "github.com/ShaohongDong/sub2api/internal/pkg/errors"
+	"github.com/stretchr/testify/require"
+)
+
+type backgroundResponseRepoStub struct {
+	BackgroundResponseRepository
+	finished []*BackgroundResponse
+	retried  []string
+}
+
+func (r *backgroundResponseRepoStub) Finish(_ context.Context, resp *BackgroundResponse) error {
+	r.finished = append(r.finished, resp)
+	return nil
+}
+
+func (r *backgroundResponseRepoStub) Retry(_ context.Context, id string, _ time.Time, _ string) error {
+	r.retried = append(r.retried, id)
+	return nil
+}
+
+type backgroundResponseAPIKeyRepoStub struct {
+	APIKeyRepository
+}
+
+func (backgroundResponseAPIKeyRepoStub) GetByID(_ context.Context, id int64) (*APIKey, error) {
+	return &APIKey{ID: id, Key: "sk-test"}, nil
+}
+
+type backgroundResponseDispatcherStub struct {
+	res    *BatchDispatchResult
+	url    string
+	body   []byte
+	apiKey string
+}
+
+func (d *backgroundResponseDispatcherStub) Dispatch(_ context.Context, apiKey string, _ string, _ string, url string, body []byte) (*BatchDispatchResult, error) {
+	d.apiKey = apiKey
+	d.url = url
+	d.body = body
+	return d.res, nil
+}
+
+func TestPrepareBackgroundResponseBody(t *testing.T) {
+	out, err := PrepareBackgroundResponseBody([]byte(`{"model":"gpt-5","background":true,"stream":false,"input":"hi"}`))
+	require.NoError(t, err)
+	require.JSONEq(t, `{"model":"gpt-5","input":"hi"}`, string(out))
+
+	_, err = PrepareBackgroundResponseBody([]byte(`{"model":"gpt-5","background":true,"stream":true}`))
+	require.Equal(t, "BACKGROUND_RESPONSE_STREAM_UNSUPPORTED", infraerrors.Reason(err))
+
+	_, err = PrepareBackgroundResponseBody([]byte(`[]`))
+	require.Equal(t, http.StatusBadRequest, infraerrors.Code(err))
+}
+
+func TestBackgroundResponseService_Execute(t *testing.T) {
+	cfg := &config.Config{BackgroundResponses: config.BackgroundResponsesConfig{Enabled: true, MaxAttempts: 2, RequestTimeoutSeconds: 5}}
+	repo := &backgroundResponseRepoStub{}
+	svc := NewBackgroundResponseService(repo, backgroundResponseAPIKeyRepoStub{}, nil, cfg)
+
+	overloaded := &backgroundResponseDispatcherStub{res: &BatchDispatchResult{StatusCode: 529, Header: http.Header{}}}
+	svc.execute(context.Background(), overloaded, &BackgroundResponse{ID: "resp_1", APIKeyID: 1, Attempts: 1, RequestBody: []byte(`{"model":"gpt-5"}`)})
+	require.Equal(t, []string{"resp_1"}, repo.retried)
+	require.Empty(t, repo.finished)
+	require.Equal(t, "sk-test", overloaded.apiKey)
+	require.Equal(t, "/v1/responses", overloaded.url)
+	require.JSONEq(t, `{"model":"gpt-5"}`, string(overloaded.body))
+
+	// 达到最大尝试次数后记为失败
+	svc.execute(context.Background(), overloaded, &BackgroundResponse{ID: "resp_2", APIKeyID: 1, Attempts: 2})
+	require.Len(t, repo.finished, 1)
+	require.Equal(t, BackgroundResponseStatusFailed, repo.finished[0].Status)
+
+	ok := &backgroundResponseDispatcherStub{res: &BatchDispatchResult{StatusCode: http.StatusOK, Header: http.Header{"X-Request-Id": []string{"req_1"}}, Body: []byte(`{"id":"resp_upstream","status":"completed"}`)}}
+	svc.execute(context.Background(), ok, &BackgroundResponse{ID: "resp_3", APIKeyID: 1, Attempts: 1})
+	require.Equal(t, BackgroundResponseStatusCompleted, repo.finished[1].Status)
+	require.Equal(t, "req_1", repo.finished[1].RequestID)
+	require.Equal(t, http.StatusOK, repo.finished[1].ResponseStatus)
+
+	// 执行器停止时不写入结果，保持 in_progress 等待重新认领
+	stopped, cancel := context.WithCancel(context.Background())
+	cancel()
+	svc.execute(stopped, ok, &BackgroundResponse{ID: "resp_4", APIKeyID: 1, Attempts: 1})
+	require.Len(t, repo.finished, 2)
+}
+
+func TestBackgroundResponseService_CreateDisabled(t *testing.T) {
+	svc := NewBackgroundResponseService(&backgroundResponseRepoStub{}, nil, nil, &config.Config{})
+	require.False(t, svc.Enabled())
+	_, err := svc.Create(context.Background(), &APIKey{ID: 1}, []byte(`{"model":"gpt-5","background":true}`), "")
+	require.Equal(t, "BACKGROUND_RESPONSE_DISABLED", infraerrors.Reason(err))
+}
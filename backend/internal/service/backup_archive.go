@@ -0,0 +1,164 @@
+package service
+
+import (
+	"bytes"
+	"compress/gzip"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/json"
+	"fmt"
+	"io"
+	"time"
+
+	infraerrors "github.com/ShaohongDong/sub2api/internal/pkg/errors"
+	"golang.org/x/crypto/scrypt"
+)
+
+const (
+	// BackupArchiveVersion 备份内容格式版本
+	BackupArchiveVersion = 1
+	// BackupPassphraseMinLength 备份口令最短长度
+	BackupPassphraseMinLength = 12
+	// backupArchiveMaxSize 解密后内容的上限，防止恶意压缩包耗尽内存
+	backupArchiveMaxSize = 256 << 20
+)
+
+// 加密格式：magic(6) | salt(16) | nonce(12) | AES-256-GCM(gzip(JSON))，magic 同时作为 AAD。
+// 密钥由口令经 scrypt(N=32768, r=8, p=1) 派生。
+var backupArchiveMagic = []byte("S2ABK\x01")
+
+const (
+	backupSaltSize  = 16
+	backupNonceSize = 12
+	backupScryptN   = 1 << 15
+	backupScryptR   = 8
+	backupScryptP   = 1
+)
+
+var (
+	ErrBackupPassphraseRequired = infraerrors.BadRequest("BACKUP_PASSPHRASE_REQUIRED", "backup passphrase is required")
+	ErrBackupPassphraseTooShort = infraerrors.BadRequest("BACKUP_PASSPHRASE_TOO_SHORT", fmt.Sprintf("backup passphrase must be at least %d characters", BackupPassphraseMinLength))
+	ErrBackupArchiveInvalid     = infraerrors.BadRequest("BACKUP_ARCHIVE_INVALID", "not a sub2api backup archive")
+	ErrBackupDecryptFailed      = infraerrors.BadRequest("BACKUP_DECRYPT_FAILED", "wrong passphrase or corrupted backup archive")
+)
+
+// BackupArchive 备份内容（加密前的明文结构）
+type BackupArchive struct {
+	Version   int               `json:"version"`
+	CreatedAt time.Time         `json:"created_at"`
+	Sections  []string          `json:"sections"`
+	Proxies   []BackupProxy     `json:"proxies,omitempty"`
+	Groups    []BackupGroup     `json:"groups,omitempty"`
+	Users     []BackupUser      `json:"users,omitempty"`
+	Accounts  []BackupAccount   `json:"accounts,omitempty"`
+	APIKeys   []BackupAPIKey    `json:"api_keys,omitempty"`
+	Settings  map[string]string `json:"settings,omitempty"`
+}
+
+// HasSection 备份中是否包含指定部分
+func (a *BackupArchive) HasSection(section string) bool {
+	for _, s := range a.Sections {
+		if s == section {
+			return true
+		}
+	}
+	return false
+}
+
+// EncryptBackupArchive 将备份序列化、压缩并用口令加密
+func EncryptBackupArchive(archive *BackupArchive, passphrase string) ([]byte, error) {
+	if err := validateBackupPassphrase(passphrase); err != nil {
+		return nil, err
+	}
+	plain, err := json.Marshal(archive)
+	if err != nil {
+		return nil, fmt.Errorf("marshal backup: %w", err)
+	}
+	var compressed bytes.Buffer
+	zw := gzip.NewWriter(&compressed)
+	if _, err := zw.Write(plain); err != nil {
+		return nil, fmt.Errorf("compress backup: %w", err)
+	}
+	if err := zw.Close(); err != nil {
+		return nil, fmt.Errorf("compress backup: %w", err)
+	}
+
+	header := make([]byte, len(backupArchiveMagic)+backupSaltSize+backupNonceSize)
+	copy(header, backupArchiveMagic)
+	salt := header[len(backupArchiveMagic) : len(backupArchiveMagic)+backupSaltSize]
+	nonce := header[len(backupArchiveMagic)+backupSaltSize:]
+	if _, err := rand.Read(salt); err != nil {
+		return nil, fmt.Errorf("generate salt: %w", err)
+	}
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, fmt.Errorf("generate nonce: %w", err)
+	}
+	aead, err := newBackupAEAD(passphrase, salt)
+	if err != nil {
+		return nil, err
+	}
+	return aead.Seal(header, nonce, compressed.Bytes(), backupArchiveMagic), nil
+}
+
+// DecryptBackupArchive 解密并解析备份
+func DecryptBackupArchive(data []byte, passphrase string) (*BackupArchive, error) {
+	if passphrase == "" {
+		return nil, ErrBackupPassphraseRequired
+	}
+	headerSize := len(backupArchiveMagic) + backupSaltSize + backupNonceSize
+	if len(data) < headerSize || !bytes.Equal(data[:len(backupArchiveMagic)], backupArchiveMagic) {
+		return nil, ErrBackupArchiveInvalid
+	}
+	salt := data[len(backupArchiveMagic) : len(backupArchiveMagic)+backupSaltSize]
+	nonce := data[len(backupArchiveMagic)+backupSaltSize : headerSize]
+	aead, err := newBackupAEAD(passphrase, salt)
+	if err != nil {
+		return nil, err
+	}
+	compressed, err := aead.Open(nil, nonce, data[headerSize:], backupArchiveMagic)
+	if err != nil {
+		return nil, ErrBackupDecryptFailed
+	}
+
+	zr, err := gzip.NewReader(bytes.NewReader(compressed))
+	if err != nil {
+		return nil, ErrBackupArchiveInvalid
+	}
+	plain, err := io.ReadAll(io.LimitReader(zr, backupArchiveMaxSize+1))
+	if err != nil || len(plain) > backupArchiveMaxSize {
+		return nil, ErrBackupArchiveInvalid
+	}
+	var archive BackupArchive
+	if err := json.Unmarshal(plain, &archive); err != nil {
+		return nil, ErrBackupArchiveInvalid.WithMetadata(map[string]string{"reason": err.Error()})
+	}
+	if archive.Version < 1 || archive.Version > BackupArchiveVersion {
+		return nil, ErrBackupArchiveInvalid.WithMetadata(map[string]string{
+			"reason": fmt.Sprintf("unsupported backup version %d", archive.Version),
+		})
+	}
+	return &archive, nil
+}
+
+func validateBackupPassphrase(passphrase string) error {
+	if passphrase == "" {
+		return ErrBackupPassphraseRequired
+	}
+	if len([]rune(passphrase)) < BackupPassphraseMinLength {
+		return ErrBackupPassphraseTooShort
+	}
+	return nil
+}
+
+func newBackupAEAD(passphrase string, salt []byte) (cipher.AEAD, error) {
+	key, err := scrypt.Key([]byte(passphrase), salt, backupScryptN, backupScryptR, backupScryptP, 32)
+	if err != nil {
+		return nil, fmt.Errorf("derive backup key: %w", err)
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, fmt.Errorf("create cipher: %w", err)
+	}
+	return cipher.NewGCM(block)
+}
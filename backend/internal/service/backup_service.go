@@ -0,0 +1,1114 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"reflect"
+	"sort"
+	"strings"
+	"time"
+
+	infraerrors "github.com/ShaohongDong/sub2api/internal/pkg/errors"
+	"github.com/ShaohongDong/sub2api/internal/pkg/pagination"
+)
+
+// 备份包含的部分，BackupSections 按恢复顺序排列（账号依赖代理与分组，API Key 依赖用户与分组）
+const (
+	BackupSectionProxies  = "proxies"
+	BackupSectionGroups   = "groups"
+	BackupSectionUsers    = "users"
+	BackupSectionAccounts = "accounts"
+	BackupSectionAPIKeys  = "api_keys"
+	BackupSectionSettings = "settings"
+)
+
+var BackupSections = []string{
+	BackupSectionProxies,
+	BackupSectionGroups,
+	BackupSectionUsers,
+	BackupSectionAccounts,
+	BackupSectionAPIKeys,
+	BackupSectionSettings,
+}
+
+// 恢复时每条记录的处理结果
+const (
+	BackupRestoreActionCreate    = "create"
+	BackupRestoreActionUpdate    = "update"
+	BackupRestoreActionUnchanged = "unchanged"
+	BackupRestoreActionSkip      = "skip"
+	BackupRestoreActionFailed    = "failed"
+)
+
+const backupListPageSize = 100
+
+var ErrBackupSectionInvalid = infraerrors.BadRequest("BACKUP_SECTION_INVALID", "invalid backup section")
+
+// BackupProxy 备份中的代理，按 protocol/host/port/username 匹配已有代理
+type BackupProxy struct {
+	Name     string `json:"name"`
+	Protocol string `json:"protocol"`
+	Host     string `json:"host"`
+	Port     int    `json:"port"`
+	Username string `json:"username,omitempty"`
+	Password string `json:"password,omitempty"`
+	Status   string `json:"status"`
+}
+
+// BackupGroup 备份中的分组，按名称匹配
+type BackupGroup struct {
+	Name                string   `json:"name"`
+	Description         string   `json:"description,omitempty"`
+	Platform            string   `json:"platform"`
+	RateMultiplier      float64  `json:"rate_multiplier"`
+	IsExclusive         bool     `json:"is_exclusive"`
+	Status              string   `json:"status"`
+	SubscriptionType    string   `json:"subscription_type"`
+	DailyLimitUSD       *float64 `json:"daily_limit_usd,omitempty"`
+	WeeklyLimitUSD      *float64 `json:"weekly_limit_usd,omitempty"`
+	MonthlyLimitUSD     *float64 `json:"monthly_limit_usd,omitempty"`
+	DefaultValidityDays int      `json:"default_validity_days,omitempty"`
+	ClaudeCodeOnly      bool     `json:"claude_code_only,omitempty"`
+	SortOrder           int      `json:"sort_order"`
+}
+
+// BackupUser 备份中的用户（API Key 的所有者），按邮箱匹配
+type BackupUser struct {
+	Email         string   `json:"email"`
+	Username      string   `json:"username,omitempty"`
+	Notes         string   `json:"notes,omitempty"`
+	PasswordHash  string   `json:"password_hash"`
+	Role          string   `json:"role"`
+	Balance       float64  `json:"balance"`
+	Concurrency   int      `json:"concurrency"`
+	Status        string   `json:"status"`
+	AllowedGroups []string `json:"allowed_groups,omitempty"`
+}
+
+// BackupAccount 备份中的上游账号，按 platform + name 匹配；凭证为明文，仅存在于加密后的备份中
+type BackupAccount struct {
+	Name               string         `json:"name"`
+	Notes              *string        `json:"notes,omitempty"`
+	Platform           string         `json:"platform"`
+	Type               string         `json:"type"`
+	Credentials        map[string]any `json:"credentials"`
+	Extra              map[string]any `json:"extra,omitempty"`
+	Proxy              *string        `json:"proxy,omitempty"`
+	Concurrency        int            `json:"concurrency"`
+	Priority           int            `json:"priority"`
+	RateMultiplier     *float64       `json:"rate_multiplier,omitempty"`
+	LoadFactor         *int           `json:"load_factor,omitempty"`
+	Status             string         `json:"status"`
+	Schedulable        bool           `json:"schedulable"`
+	ExpiresAt          *time.Time     `json:"expires_at,omitempty"`
+	AutoPauseOnExpired bool           `json:"auto_pause_on_expired"`
+	Groups             []string       `json:"groups,omitempty"`
+}
+
+// BackupAPIKey 备份中的 API Key，按 key 匹配；用量计数不备份，恢复后从零开始
+type BackupAPIKey struct {
+	Key                   string     `json:"key"`
+	Name                  string     `json:"name"`
+	Description           string     `json:"description,omitempty"`
+	User                  string     `json:"user"`
+	Group                 *string    `json:"group,omitempty"`
+	Status                string     `json:"status"`
+	IPWhitelist           []string   `json:"ip_whitelist,omitempty"`
+	IPBlacklist           []string   `json:"ip_blacklist,omitempty"`
+	Scopes                []string   `json:"scopes,omitempty"`
+	AllowedModels         []string   `json:"allowed_models,omitempty"`
+	ClientRestriction     string     `json:"client_restriction,omitempty"`
+	Tags                  []string   `json:"tags,omitempty"`
+	DegradedFallbackModel string     `json:"degraded_fallback_model,omitempty"`
+	SystemPrompt          string     `json:"system_prompt,omitempty"`
+	SystemPromptMode      string     `json:"system_prompt_mode,omitempty"`
+	ModerationMode        string     `json:"moderation_mode,omitempty"`
+	LogContent            bool       `json:"log_content,omitempty"`
+	Simulate              bool       `json:"simulate,omitempty"`
+	SuppressReasoning     bool       `json:"suppress_reasoning,omitempty"`
+	Quota                 float64    `json:"quota,omitempty"`
+	ImageQuota            int        `json:"image_quota,omitempty"`
+	ExpiresAt             *time.Time `json:"expires_at,omitempty"`
+	RateLimit5h           float64    `json:"rate_limit_5h,omitempty"`
+	RateLimit1d           float64    `json:"rate_limit_1d,omitempty"`
+	RateLimit7d           float64    `json:"rate_limit_7d,omitempty"`
+	RPMLimit              int        `json:"rpm_limit,omitempty"`
+	TPMLimit              int        `json:"tpm_limit,omitempty"`
+	DailyRequestLimit     int        `json:"daily_request_limit,omitempty"`
+	DailyTokenLimit       int64      `json:"daily_token_limit,omitempty"`
+	MonthlyRequestLimit   int        `json:"monthly_request_limit,omitempty"`
+	MonthlyTokenLimit     int64      `json:"monthly_token_limit,omitempty"`
+	BudgetAmount          float64    `json:"budget_amount,omitempty"`
+	BudgetPeriod          string     `json:"budget_period,omitempty"`
+	BudgetAction          string     `json:"budget_action,omitempty"`
+	BudgetFallbackModel   string     `json:"budget_fallback_model,omitempty"`
+}
+
+// BackupRestoreOptions 恢复选项
+type BackupRestoreOptions struct {
+	// Sections 要恢复的部分，为空表示备份中包含的全部部分
+	Sections []string
+	// DryRun 只比较差异、不写入
+	DryRun bool
+	// Overwrite 用备份内容覆盖已存在且有差异的记录；否则跳过这些记录
+	Overwrite bool
+}
+
+// BackupRestoreItem 单条记录的恢复结果；Changes 只列出字段名，不包含字段值
+type BackupRestoreItem struct {
+	Section string   `json:"section"`
+	Name    string   `json:"name"`
+	Action  string   `json:"action"`
+	Changes []string `json:"changes,omitempty"`
+	Reason  string   `json:"reason,omitempty"`
+}
+
+// BackupRestoreCounts 某一部分的恢复统计
+type BackupRestoreCounts struct {
+	Created   int `json:"created"`
+	Updated   int `json:"updated"`
+	Unchanged int `json:"unchanged"`
+	Skipped   int `json:"skipped"`
+	Failed    int `json:"failed"`
+}
+
+// BackupRestoreReport 恢复（或 dry-run）报告；Items 不包含无变化的记录
+type BackupRestoreReport struct {
+	DryRun    bool                            `json:"dry_run"`
+	Overwrite bool                            `json:"overwrite"`
+	CreatedAt time.Time                       `json:"backup_created_at"`
+	Sections  []string                        `json:"sections"`
+	Summary   map[string]*BackupRestoreCounts `json:"summary"`
+	Items     []BackupRestoreItem             `json:"items"`
+}
+
+func (r *BackupRestoreReport) record(section, name, action string, changes []string, reason string) {
+	counts := r.Summary[section]
+	if counts == nil {
+		counts = &BackupRestoreCounts{}
+		r.Summary[section] = counts
+	}
+	switch action {
+	case BackupRestoreActionCreate:
+		counts.Created++
+	case BackupRestoreActionUpdate:
+		counts.Updated++
+	case BackupRestoreActionUnchanged:
+		counts.Unchanged++
+		return
+	case BackupRestoreActionSkip:
+		counts.Skipped++
+	case BackupRestoreActionFailed:
+		counts.Failed++
+	}
+	r.Items = append(r.Items, BackupRestoreItem{Section: section, Name: name, Action: action, Changes: changes, Reason: reason})
+}
+
+// BackupService 导出与恢复代理、分组、用户、账号、API Key 与系统设置，用于迁移到新主机。
+// 与数据管理的整库备份不同，这里按业务主键（而非数据库 ID）匹配记录，可以选择性地恢复到已有数据的实例。
+type BackupService struct {
+	proxyRepo            ProxyRepository
+	groupRepo            GroupRepository
+	userRepo             UserRepository
+	accountRepo          AccountRepository
+	apiKeyRepo           APIKeyRepository
+	settingRepo          SettingRepository
+	settingService       *SettingService
+	authCacheInvalidator APIKeyAuthCacheInvalidator
+}
+
+// NewBackupService 创建备份服务
+func NewBackupService(
+	proxyRepo ProxyRepository,
+	groupRepo GroupRepository,
+	userRepo UserRepository,
+	accountRepo AccountRepository,
+	apiKeyRepo APIKeyRepository,
+	settingRepo SettingRepository,
+	settingService *SettingService,
+	authCacheInvalidator APIKeyAuthCacheInvalidator,
+) *BackupService {
+	return &BackupService{
+		proxyRepo:            proxyRepo,
+		groupRepo:            groupRepo,
+		userRepo:             userRepo,
+		accountRepo:          accountRepo,
+		apiKeyRepo:           apiKeyRepo,
+		settingRepo:          settingRepo,
+		settingService:       settingService,
+		authCacheInvalidator: authCacheInvalidator,
+	}
+}
+
+// ParseBackupSections 解析逗号分隔的部分列表，空字符串表示全部；结果按恢复顺序排列
+func ParseBackupSections(raw string) ([]string, error) {
+	if strings.TrimSpace(raw) == "" {
+		return append([]string(nil), BackupSections...), nil
+	}
+	return normalizeBackupSections(strings.Split(raw, ","))
+}
+
+func normalizeBackupSections(sections []string) ([]string, error) {
+	want := make(map[string]bool, len(sections))
+	for _, s := range sections {
+		s = strings.ToLower(strings.TrimSpace(s))
+		if s == "" {
+			continue
+		}
+		if !isBackupSection(s) {
+			return nil, ErrBackupSectionInvalid.WithMetadata(map[string]string{
+				"reason": fmt.Sprintf("unknown section %q, expected one of %s", s, strings.Join(BackupSections, ",")),
+			})
+		}
+		want[s] = true
+	}
+	out := make([]string, 0, len(want))
+	for _, s := range BackupSections {
+		if want[s] {
+			out = append(out, s)
+		}
+	}
+	return out, nil
+}
+
+func isBackupSection(section string) bool {
+	for _, s := range BackupSections {
+		if s == section {
+			return true
+		}
+	}
+	return false
+}
+
+func backupProxyKey(protocol, host string, port int, username string) string {
+	return fmt.Sprintf("%s://%s@%s:%d", strings.TrimSpace(protocol), strings.TrimSpace(username), strings.TrimSpace(host), port)
+}
+
+func backupAccountKey(platform, name string) string {
+	return platform + "/" + name
+}
+
+// maskBackupAPIKey 报告中只显示 key 的首尾，避免泄露
+func maskBackupAPIKey(key string) string {
+	if len(key) <= 12 {
+		return "***"
+	}
+	return key[:6] + "..." + key[len(key)-4:]
+}
+
+// backupSnapshot 现有数据的索引，导出与恢复共用
+type backupSnapshot struct {
+	proxies       map[string]*Proxy // proxy key -> proxy
+	proxyKeyByID  map[int64]string
+	groups        map[string]*Group // name -> group
+	groupNameByID map[int64]string
+	users         map[string]*User // email -> user
+	userEmailByID map[int64]string
+	accounts      map[string]*Account // platform/name -> account
+	accountList   []Account
+	userList      []User
+}
+
+func (s *BackupService) loadSnapshot(ctx context.Context, sections []string) (*backupSnapshot, error) {
+	snap := &backupSnapshot{
+		proxies:       make(map[string]*Proxy),
+		proxyKeyByID:  make(map[int64]string),
+		groups:        make(map[string]*Group),
+		groupNameByID: make(map[int64]string),
+		users:         make(map[string]*User),
+		userEmailByID: make(map[int64]string),
+		accounts:      make(map[string]*Account),
+	}
+	need := make(map[string]bool, len(sections))
+	for _, section := range sections {
+		need[section] = true
+	}
+
+	if need[BackupSectionProxies] || need[BackupSectionAccounts] {
+		proxies, err := listAllPages(ctx, s.proxyRepo.List)
+		if err != nil {
+			return nil, fmt.Errorf("list proxies: %w", err)
+		}
+		for i := range proxies {
+			p := &proxies[i]
+			key := backupProxyKey(p.Protocol, p.Host, p.Port, p.Username)
+			if _, exists := snap.proxies[key]; !exists {
+				snap.proxies[key] = p
+			}
+			snap.proxyKeyByID[p.ID] = key
+		}
+	}
+	if need[BackupSectionGroups] || need[BackupSectionUsers] || need[BackupSectionAccounts] || need[BackupSectionAPIKeys] {
+		groups, err := listAllPages(ctx, s.groupRepo.List)
+		if err != nil {
+			return nil, fmt.Errorf("list groups: %w", err)
+		}
+		for i := range groups {
+			g := &groups[i]
+			if _, exists := snap.groups[g.Name]; !exists {
+				snap.groups[g.Name] = g
+			}
+			snap.groupNameByID[g.ID] = g.Name
+		}
+	}
+	if need[BackupSectionUsers] || need[BackupSectionAPIKeys] {
+		users, err := listAllPages(ctx, s.userRepo.List)
+		if err != nil {
+			return nil, fmt.Errorf("list users: %w", err)
+		}
+		snap.userList = users
+		for i := range users {
+			u := &snap.userList[i]
+			snap.users[strings.ToLower(u.Email)] = u
+			snap.userEmailByID[u.ID] = u.Email
+		}
+	}
+	if need[BackupSectionAccounts] {
+		accounts, err := listAllPages(ctx, s.accountRepo.List)
+		if err != nil {
+			return nil, fmt.Errorf("list accounts: %w", err)
+		}
+		snap.accountList = accounts
+		for i := range accounts {
+			a := &snap.accountList[i]
+			key := backupAccountKey(a.Platform, a.Name)
+			if _, exists := snap.accounts[key]; !exists {
+				snap.accounts[key] = a
+			}
+		}
+	}
+	return snap, nil
+}
+
+func listAllPages[T any](ctx context.Context, list func(context.Context, pagination.PaginationParams) ([]T, *pagination.PaginationResult, error)) ([]T, error) {
+	var out []T
+	for page := 1; ; page++ {
+		items, result, err := list(ctx, pagination.PaginationParams{Page: page, PageSize: backupListPageSize})
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, items...)
+		if len(items) == 0 || result == nil || int64(len(out)) >= result.Total {
+			return out, nil
+		}
+	}
+}
+
+// Export 导出指定部分（为空表示全部）
+func (s *BackupService) Export(ctx context.Context, sections []string) (*BackupArchive, error) {
+	sections, err := normalizeBackupSections(sections)
+	if err != nil {
+		return nil, err
+	}
+	if len(sections) == 0 {
+		sections = append([]string(nil), BackupSections...)
+	}
+	snap, err := s.loadSnapshot(ctx, sections)
+	if err != nil {
+		return nil, err
+	}
+
+	archive := &BackupArchive{
+		Version:   BackupArchiveVersion,
+		CreatedAt: time.Now().UTC(),
+		Sections:  sections,
+	}
+	for _, section := range sections {
+		switch section {
+		case BackupSectionProxies:
+			archive.Proxies = make([]BackupProxy, 0, len(snap.proxies))
+			for _, key := range sortedBackupKeys(snap.proxies) {
+				archive.Proxies = append(archive.Proxies, backupProxyFrom(snap.proxies[key]))
+			}
+		case BackupSectionGroups:
+			archive.Groups = make([]BackupGroup, 0, len(snap.groups))
+			for _, name := range sortedBackupKeys(snap.groups) {
+				archive.Groups = append(archive.Groups, backupGroupFrom(snap.groups[name]))
+			}
+		case BackupSectionUsers:
+			archive.Users = make([]BackupUser, 0, len(snap.userList))
+			for i := range snap.userList {
+				archive.Users = append(archive.Users, backupUserFrom(&snap.userList[i], snap))
+			}
+		case BackupSectionAccounts:
+			archive.Accounts = make([]BackupAccount, 0, len(snap.accountList))
+			for i := range snap.accountList {
+				archive.Accounts = append(archive.Accounts, backupAccountFrom(&snap.accountList[i], snap))
+			}
+		case BackupSectionAPIKeys:
+			for i := range snap.userList {
+				keys, err := listAllPages(ctx, func(ctx context.Context, params pagination.PaginationParams) ([]APIKey, *pagination.PaginationResult, error) {
+					return s.apiKeyRepo.ListByUserID(ctx, snap.userList[i].ID, params, APIKeyListFilters{})
+				})
+				if err != nil {
+					return nil, fmt.Errorf("list api keys of user %d: %w", snap.userList[i].ID, err)
+				}
+				for j := range keys {
+					archive.APIKeys = append(archive.APIKeys, backupAPIKeyFrom(&keys[j], snap))
+				}
+			}
+		case BackupSectionSettings:
+			settings, err := s.settingRepo.GetAll(ctx)
+			if err != nil {
+				return nil, fmt.Errorf("list settings: %w", err)
+			}
+			archive.Settings = settings
+		}
+	}
+	slog.InfoContext(ctx, "backup.exported",
+		"sections", strings.Join(sections, ","),
+		"proxies", len(archive.Proxies),
+		"groups", len(archive.Groups),
+		"users", len(archive.Users),
+		"accounts", len(archive.Accounts),
+		"api_keys", len(archive.APIKeys),
+		"settings", len(archive.Settings),
+	)
+	return archive, nil
+}
+
+// Restore 将备份恢复到当前实例。已存在且无差异的记录保持不变；有差异的记录在 Overwrite 时更新，否则跳过。
+// 单条记录失败不会中断恢复，结果记录在报告中；DryRun 时只生成报告。
+func (s *BackupService) Restore(ctx context.Context, archive *BackupArchive, opts BackupRestoreOptions) (*BackupRestoreReport, error) {
+	if archive == nil {
+		return nil, ErrBackupArchiveInvalid
+	}
+	sections, err := normalizeBackupSections(opts.Sections)
+	if err != nil {
+		return nil, err
+	}
+	if len(opts.Sections) == 0 {
+		sections, _ = normalizeBackupSections(archive.Sections)
+	}
+	selected := sections[:0]
+	for _, section := range sections {
+		if !archive.HasSection(section) {
+			return nil, ErrBackupSectionInvalid.WithMetadata(map[string]string{
+				"reason": fmt.Sprintf("section %q is not in the backup", section),
+			})
+		}
+		selected = append(selected, section)
+	}
+
+	snap, err := s.loadSnapshot(ctx, selected)
+	if err != nil {
+		return nil, err
+	}
+	report := &BackupRestoreReport{
+		DryRun:    opts.DryRun,
+		Overwrite: opts.Overwrite,
+		CreatedAt: archive.CreatedAt,
+		Sections:  selected,
+		Summary:   make(map[string]*BackupRestoreCounts, len(selected)),
+		Items:     []BackupRestoreItem{},
+	}
+	for _, section := range selected {
+		report.Summary[section] = &BackupRestoreCounts{}
+		switch section {
+		case BackupSectionProxies:
+			s.restoreProxies(ctx, archive.Proxies, opts, snap, report)
+		case BackupSectionGroups:
+			s.restoreGroups(ctx, archive.Groups, opts, snap, report)
+		case BackupSectionUsers:
+			s.restoreUsers(ctx, archive.Users, opts, snap, report)
+		case BackupSectionAccounts:
+			s.restoreAccounts(ctx, archive.Accounts, opts, snap, report)
+		case BackupSectionAPIKeys:
+			s.restoreAPIKeys(ctx, archive.APIKeys, opts, snap, report)
+		case BackupSectionSettings:
+			if err := s.restoreSettings(ctx, archive.Settings, opts, report); err != nil {
+				return nil, err
+			}
+		}
+	}
+	slog.InfoContext(ctx, "backup.restored", "dry_run", opts.DryRun, "overwrite", opts.Overwrite, "sections", strings.Join(selected, ","))
+	return report, nil
+}
+
+// decideRestore 根据差异与选项决定已存在记录的处理方式，返回是否需要写入
+func decideRestore(report *BackupRestoreReport, section, name string, current, desired any, opts BackupRestoreOptions) bool {
+	changes := backupFieldChanges(current, desired)
+	switch {
+	case len(changes) == 0:
+		report.record(section, name, BackupRestoreActionUnchanged, nil, "")
+		return false
+	case !opts.Overwrite:
+		report.record(section, name, BackupRestoreActionSkip, changes, "already exists")
+		return false
+	default:
+		report.record(section, name, BackupRestoreActionUpdate, changes, "")
+		return !opts.DryRun
+	}
+}
+
+// failLastItem 写入失败时把刚记录的 create/update 改为 failed
+func failLastItem(report *BackupRestoreReport, section string, err error) {
+	if len(report.Items) == 0 {
+		return
+	}
+	item := &report.Items[len(report.Items)-1]
+	counts := report.Summary[section]
+	switch item.Action {
+	case BackupRestoreActionCreate:
+		counts.Created--
+	case BackupRestoreActionUpdate:
+		counts.Updated--
+	}
+	counts.Failed++
+	item.Action = BackupRestoreActionFailed
+	item.Reason = err.Error()
+}
+
+func (s *BackupService) restoreProxies(ctx context.Context, items []BackupProxy, opts BackupRestoreOptions, snap *backupSnapshot, report *BackupRestoreReport) {
+	for _, item := range items {
+		key := backupProxyKey(item.Protocol, item.Host, item.Port, item.Username)
+		if strings.TrimSpace(item.Protocol) == "" || strings.TrimSpace(item.Host) == "" || item.Port <= 0 || item.Port > 65535 {
+			report.record(BackupSectionProxies, key, BackupRestoreActionFailed, nil, "protocol, host and port are required")
+			continue
+		}
+		status := defaultBackupStatus(item.Status)
+		existing := snap.proxies[key]
+		if existing == nil {
+			report.record(BackupSectionProxies, key, BackupRestoreActionCreate, nil, "")
+			proxy := &Proxy{Name: item.Name, Protocol: item.Protocol, Host: item.Host, Port: item.Port, Username: item.Username, Password: item.Password, Status: status}
+			if !opts.DryRun {
+				if err := s.proxyRepo.Create(ctx, proxy); err != nil {
+					failLastItem(report, BackupSectionProxies, err)
+					continue
+				}
+			}
+			snap.proxies[key] = proxy
+			continue
+		}
+		if !decideRestore(report, BackupSectionProxies, key, backupProxyFrom(existing), item, opts) {
+			continue
+		}
+		existing.Name = item.Name
+		existing.Password = item.Password
+		existing.Status = status
+		if err := s.proxyRepo.Update(ctx, existing); err != nil {
+			failLastItem(report, BackupSectionProxies, err)
+		}
+	}
+}
+
+func (s *BackupService) restoreGroups(ctx context.Context, items []BackupGroup, opts BackupRestoreOptions, snap *backupSnapshot, report *BackupRestoreReport) {
+	for _, item := range items {
+		if strings.TrimSpace(item.Name) == "" || strings.TrimSpace(item.Platform) == "" {
+			report.record(BackupSectionGroups, item.Name, BackupRestoreActionFailed, nil, "name and platform are required")
+			continue
+		}
+		existing := snap.groups[item.Name]
+		if existing == nil {
+			report.record(BackupSectionGroups, item.Name, BackupRestoreActionCreate, nil, "")
+			group := &Group{}
+			applyBackupGroup(group, item)
+			if !opts.DryRun {
+				if err := s.groupRepo.Create(ctx, group); err != nil {
+					failLastItem(report, BackupSectionGroups, err)
+					continue
+				}
+			}
+			snap.groups[item.Name] = group
+			continue
+		}
+		if !decideRestore(report, BackupSectionGroups, item.Name, backupGroupFrom(existing), item, opts) {
+			continue
+		}
+		applyBackupGroup(existing, item)
+		if err := s.groupRepo.Update(ctx, existing); err != nil {
+			failLastItem(report, BackupSectionGroups, err)
+		}
+	}
+}
+
+func (s *BackupService) restoreUsers(ctx context.Context, items []BackupUser, opts BackupRestoreOptions, snap *backupSnapshot, report *BackupRestoreReport) {
+	for _, item := range items {
+		email := strings.TrimSpace(item.Email)
+		if email == "" || item.PasswordHash == "" {
+			report.record(BackupSectionUsers, email, BackupRestoreActionFailed, nil, "email and password_hash are required")
+			continue
+		}
+		allowedGroups, missing := resolveBackupGroups(item.AllowedGroups, snap)
+		if missing != "" {
+			report.record(BackupSectionUsers, email, BackupRestoreActionFailed, nil, "group not found: "+missing)
+			continue
+		}
+		existing := snap.users[strings.ToLower(email)]
+		if existing == nil {
+			report.record(BackupSectionUsers, email, BackupRestoreActionCreate, nil, "")
+			user := &User{}
+			applyBackupUser(user, item, allowedGroups)
+			if !opts.DryRun {
+				if err := s.userRepo.Create(ctx, user); err != nil {
+					failLastItem(report, BackupSectionUsers, err)
+					continue
+				}
+			}
+			snap.users[strings.ToLower(email)] = user
+			continue
+		}
+		if !decideRestore(report, BackupSectionUsers, email, backupUserFrom(existing, snap), item, opts) {
+			continue
+		}
+		applyBackupUser(existing, item, allowedGroups)
+		if err := s.userRepo.Update(ctx, existing); err != nil {
+			failLastItem(report, BackupSectionUsers, err)
+		}
+	}
+}
+
+func (s *BackupService) restoreAccounts(ctx context.Context, items []BackupAccount, opts BackupRestoreOptions, snap *backupSnapshot, report *BackupRestoreReport) {
+	for _, item := range items {
+		key := backupAccountKey(item.Platform, item.Name)
+		if strings.TrimSpace(item.Name) == "" || strings.TrimSpace(item.Platform) == "" || strings.TrimSpace(item.Type) == "" {
+			report.record(BackupSectionAccounts, key, BackupRestoreActionFailed, nil, "name, platform and type are required")
+			continue
+		}
+		var proxy *Proxy
+		if item.Proxy != nil && *item.Proxy != "" {
+			if proxy = snap.proxies[*item.Proxy]; proxy == nil {
+				report.record(BackupSectionAccounts, key, BackupRestoreActionFailed, nil, "proxy not found: "+*item.Proxy)
+				continue
+			}
+		}
+		groupIDs, missing := resolveBackupGroups(item.Groups, snap)
+		if missing != "" {
+			report.record(BackupSectionAccounts, key, BackupRestoreActionFailed, nil, "group not found: "+missing)
+			continue
+		}
+
+		existing := snap.accounts[key]
+		if existing == nil {
+			report.record(BackupSectionAccounts, key, BackupRestoreActionCreate, nil, "")
+			if opts.DryRun {
+				continue
+			}
+			account := &Account{}
+			applyBackupAccount(account, item, proxy)
+			if err := s.accountRepo.Create(ctx, account); err != nil {
+				failLastItem(report, BackupSectionAccounts, err)
+				continue
+			}
+			if len(groupIDs) > 0 {
+				if err := s.accountRepo.BindGroups(ctx, account.ID, groupIDs); err != nil {
+					failLastItem(report, BackupSectionAccounts, fmt.Errorf("bind groups: %w", err))
+				}
+			}
+			continue
+		}
+		if !decideRestore(report, BackupSectionAccounts, key, backupAccountFrom(existing, snap), item, opts) {
+			continue
+		}
+		applyBackupAccount(existing, item, proxy)
+		if err := s.accountRepo.Update(ctx, existing); err != nil {
+			failLastItem(report, BackupSectionAccounts, err)
+			continue
+		}
+		if err := s.accountRepo.BindGroups(ctx, existing.ID, groupIDs); err != nil {
+			failLastItem(report, BackupSectionAccounts, fmt.Errorf("bind groups: %w", err))
+		}
+	}
+}
+
+func (s *BackupService) restoreAPIKeys(ctx context.Context, items []BackupAPIKey, opts BackupRestoreOptions, snap *backupSnapshot, report *BackupRestoreReport) {
+	for _, item := range items {
+		name := maskBackupAPIKey(item.Key)
+		if strings.TrimSpace(item.Key) == "" {
+			report.record(BackupSectionAPIKeys, name, BackupRestoreActionFailed, nil, "key is required")
+			continue
+		}
+		owner := snap.users[strings.ToLower(strings.TrimSpace(item.User))]
+		if owner == nil {
+			report.record(BackupSectionAPIKeys, name, BackupRestoreActionFailed, nil, "user not found: "+item.User)
+			continue
+		}
+		var group *Group
+		if item.Group != nil && *item.Group != "" {
+			if group = snap.groups[*item.Group]; group == nil {
+				report.record(BackupSectionAPIKeys, name, BackupRestoreActionFailed, nil, "group not found: "+*item.Group)
+				continue
+			}
+		}
+
+		existing, err := s.apiKeyRepo.GetByKey(ctx, item.Key)
+		if err != nil && !errors.Is(err, ErrAPIKeyNotFound) {
+			report.record(BackupSectionAPIKeys, name, BackupRestoreActionFailed, nil, err.Error())
+			continue
+		}
+		if existing == nil {
+			report.record(BackupSectionAPIKeys, name, BackupRestoreActionCreate, nil, "")
+			if opts.DryRun {
+				continue
+			}
+			key := &APIKey{}
+			applyBackupAPIKey(key, item, owner, group)
+			if err := s.apiKeyRepo.Create(ctx, key); err != nil {
+				failLastItem(report, BackupSectionAPIKeys, err)
+			}
+			continue
+		}
+		if !decideRestore(report, BackupSectionAPIKeys, name, backupAPIKeyFrom(existing, snap), item, opts) {
+			continue
+		}
+		applyBackupAPIKey(existing, item, owner, group)
+		if err := s.apiKeyRepo.Update(ctx, existing); err != nil {
+			failLastItem(report, BackupSectionAPIKeys, err)
+			continue
+		}
+		if s.authCacheInvalidator != nil {
+			s.authCacheInvalidator.InvalidateAuthCacheByKey(ctx, existing.Key)
+		}
+	}
+}
+
+func (s *BackupService) restoreSettings(ctx context.Context, items map[string]string, opts BackupRestoreOptions, report *BackupRestoreReport) error {
+	current, err := s.settingRepo.GetAll(ctx)
+	if err != nil {
+		return fmt.Errorf("list settings: %w", err)
+	}
+	writes := make(map[string]string)
+	for _, key := range sortedBackupKeys(items) {
+		value := items[key]
+		existing, ok := current[key]
+		switch {
+		case !ok:
+			report.record(BackupSectionSettings, key, BackupRestoreActionCreate, nil, "")
+			writes[key] = value
+		case existing == value:
+			report.record(BackupSectionSettings, key, BackupRestoreActionUnchanged, nil, "")
+		case !opts.Overwrite:
+			report.record(BackupSectionSettings, key, BackupRestoreActionSkip, []string{"value"}, "already exists")
+		default:
+			report.record(BackupSectionSettings, key, BackupRestoreActionUpdate, []string{"value"}, "")
+			writes[key] = value
+		}
+	}
+	if opts.DryRun || len(writes) == 0 {
+		return nil
+	}
+	if err := s.settingRepo.SetMultiple(ctx, writes); err != nil {
+		// 设置批量写入，失败时整批标记为失败
+		for i := range report.Items {
+			item := &report.Items[i]
+			if item.Section != BackupSectionSettings || (item.Action != BackupRestoreActionCreate && item.Action != BackupRestoreActionUpdate) {
+				continue
+			}
+			counts := report.Summary[BackupSectionSettings]
+			if item.Action == BackupRestoreActionCreate {
+				counts.Created--
+			} else {
+				counts.Updated--
+			}
+			counts.Failed++
+			item.Action = BackupRestoreActionFailed
+			item.Reason = err.Error()
+		}
+		return nil
+	}
+	s.settingService.InvalidateRuntimeSettingCaches()
+	return nil
+}
+
+func resolveBackupGroups(names []string, snap *backupSnapshot) ([]int64, string) {
+	ids := make([]int64, 0, len(names))
+	for _, name := range names {
+		group := snap.groups[name]
+		if group == nil {
+			return nil, name
+		}
+		// dry-run 中待创建的分组没有 ID，只用于判断引用是否有效
+		if group.ID > 0 {
+			ids = append(ids, group.ID)
+		}
+	}
+	return ids, ""
+}
+
+func defaultBackupStatus(status string) string {
+	if strings.TrimSpace(status) == "" {
+		return StatusActive
+	}
+	return status
+}
+
+func backupProxyFrom(p *Proxy) BackupProxy {
+	return BackupProxy{
+		Name:     p.Name,
+		Protocol: p.Protocol,
+		Host:     p.Host,
+		Port:     p.Port,
+		Username: p.Username,
+		Password: p.Password,
+		Status:   p.Status,
+	}
+}
+
+func backupGroupFrom(g *Group) BackupGroup {
+	return BackupGroup{
+		Name:                g.Name,
+		Description:         g.Description,
+		Platform:            g.Platform,
+		RateMultiplier:      g.RateMultiplier,
+		IsExclusive:         g.IsExclusive,
+		Status:              g.Status,
+		SubscriptionType:    g.SubscriptionType,
+		DailyLimitUSD:       g.DailyLimitUSD,
+		WeeklyLimitUSD:      g.WeeklyLimitUSD,
+		MonthlyLimitUSD:     g.MonthlyLimitUSD,
+		DefaultValidityDays: g.DefaultValidityDays,
+		ClaudeCodeOnly:      g.ClaudeCodeOnly,
+		SortOrder:           g.SortOrder,
+	}
+}
+
+func applyBackupGroup(g *Group, item BackupGroup) {
+	g.Name = item.Name
+	g.Description = item.Description
+	g.Platform = item.Platform
+	g.RateMultiplier = item.RateMultiplier
+	g.IsExclusive = item.IsExclusive
+	g.Status = defaultBackupStatus(item.Status)
+	g.SubscriptionType = item.SubscriptionType
+	if g.SubscriptionType == "" {
+		g.SubscriptionType = SubscriptionTypeStandard
+	}
+	g.DailyLimitUSD = item.DailyLimitUSD
+	g.WeeklyLimitUSD = item.WeeklyLimitUSD
+	g.MonthlyLimitUSD = item.MonthlyLimitUSD
+	g.DefaultValidityDays = item.DefaultValidityDays
+	g.ClaudeCodeOnly = item.ClaudeCodeOnly
+	g.SortOrder = item.SortOrder
+}
+
+func backupUserFrom(u *User, snap *backupSnapshot) BackupUser {
+	return BackupUser{
+		Email:         u.Email,
+		Username:      u.Username,
+		Notes:         u.Notes,
+		PasswordHash:  u.PasswordHash,
+		Role:          u.Role,
+		Balance:       u.Balance,
+		Concurrency:   u.Concurrency,
+		Status:        u.Status,
+		AllowedGroups: backupGroupNames(u.AllowedGroups, snap),
+	}
+}
+
+func applyBackupUser(u *User, item BackupUser, allowedGroups []int64) {
+	u.Email = strings.TrimSpace(item.Email)
+	u.Username = item.Username
+	u.Notes = item.Notes
+	u.PasswordHash = item.PasswordHash
+	u.Role = item.Role
+	if u.Role == "" {
+		u.Role = RoleUser
+	}
+	u.Balance = item.Balance
+	u.Concurrency = item.Concurrency
+	u.Status = defaultBackupStatus(item.Status)
+	u.AllowedGroups = allowedGroups
+}
+
+func backupAccountFrom(a *Account, snap *backupSnapshot) BackupAccount {
+	out := BackupAccount{
+		Name:               a.Name,
+		Notes:              a.Notes,
+		Platform:           a.Platform,
+		Type:               a.Type,
+		Credentials:        a.Credentials,
+		Extra:              a.Extra,
+		Concurrency:        a.Concurrency,
+		Priority:           a.Priority,
+		RateMultiplier:     a.RateMultiplier,
+		LoadFactor:         a.LoadFactor,
+		Status:             a.Status,
+		Schedulable:        a.Schedulable,
+		ExpiresAt:          a.ExpiresAt,
+		AutoPauseOnExpired: a.AutoPauseOnExpired,
+	}
+	if a.ProxyID != nil {
+		if key, ok := snap.proxyKeyByID[*a.ProxyID]; ok {
+			out.Proxy = &key
+		}
+	}
+	groupIDs := a.GroupIDs
+	if len(groupIDs) == 0 {
+		for _, g := range a.Groups {
+			if g != nil {
+				groupIDs = append(groupIDs, g.ID)
+			}
+		}
+	}
+	out.Groups = backupGroupNames(groupIDs, snap)
+	return out
+}
+
+func applyBackupAccount(a *Account, item BackupAccount, proxy *Proxy) {
+	a.Name = item.Name
+	a.Notes = item.Notes
+	a.Platform = item.Platform
+	a.Type = item.Type
+	a.Credentials = item.Credentials
+	a.Extra = item.Extra
+	a.ProxyID = nil
+	if proxy != nil {
+		id := proxy.ID
+		a.ProxyID = &id
+	}
+	a.Concurrency = item.Concurrency
+	a.Priority = item.Priority
+	a.RateMultiplier = item.RateMultiplier
+	a.LoadFactor = item.LoadFactor
+	a.Status = defaultBackupStatus(item.Status)
+	a.Schedulable = item.Schedulable
+	a.ExpiresAt = item.ExpiresAt
+	a.AutoPauseOnExpired = item.AutoPauseOnExpired
+}
+
+func backupAPIKeyFrom(k *APIKey, snap *backupSnapshot) BackupAPIKey {
+	out := BackupAPIKey{
+		Key:                   k.Key,
+		Name:                  k.Name,
+		Description:           k.Description,
+		User:                  snap.userEmailByID[k.UserID],
+		Status:                k.Status,
+		IPWhitelist:           k.IPWhitelist,
+		IPBlacklist:           k.IPBlacklist,
+		Scopes:                k.Scopes,
+		AllowedModels:         k.AllowedModels,
+		ClientRestriction:     k.ClientRestriction,
+		Tags:                  k.Tags,
+		DegradedFallbackModel: k.DegradedFallbackModel,
+		SystemPrompt:          k.SystemPrompt,
+		SystemPromptMode:      k.SystemPromptMode,
+		ModerationMode:        k.ModerationMode,
+		LogContent:            k.LogContent,
+		Simulate:              k.Simulate,
+		SuppressReasoning:     k.SuppressReasoning,
+		Quota:                 k.Quota,
+		ImageQuota:            k.ImageQuota,
+		ExpiresAt:             k.ExpiresAt,
+		RateLimit5h:           k.RateLimit5h,
+		RateLimit1d:           k.RateLimit1d,
+		RateLimit7d:           k.RateLimit7d,
+		RPMLimit:              k.RPMLimit,
+		TPMLimit:              k.TPMLimit,
+		DailyRequestLimit:     k.DailyRequestLimit,
+		DailyTokenLimit:       k.DailyTokenLimit,
+		MonthlyRequestLimit:   k.MonthlyRequestLimit,
+		MonthlyTokenLimit:     k.MonthlyTokenLimit,
+		BudgetAmount:          k.BudgetAmount,
+		BudgetPeriod:          k.BudgetPeriod,
+		BudgetAction:          k.BudgetAction,
+		BudgetFallbackModel:   k.BudgetFallbackModel,
+	}
+	if k.GroupID != nil {
+		if name, ok := snap.groupNameByID[*k.GroupID]; ok {
+			out.Group = &name
+		}
+	}
+	return out
+}
+
+func applyBackupAPIKey(k *APIKey, item BackupAPIKey, owner *User, group *Group) {
+	k.Key = item.Key
+	k.UserID = owner.ID
+	k.Name = item.Name
+	k.Description = item.Description
+	k.GroupID = nil
+	if group != nil {
+		id := group.ID
+		k.GroupID = &id
+	}
+	k.Status = defaultBackupStatus(item.Status)
+	k.IPWhitelist = item.IPWhitelist
+	k.IPBlacklist = item.IPBlacklist
+	k.Scopes = item.Scopes
+	k.AllowedModels = item.AllowedModels
+	k.ClientRestriction = item.ClientRestriction
+	k.Tags = item.Tags
+	k.DegradedFallbackModel = item.DegradedFallbackModel
+	k.SystemPrompt = item.SystemPrompt
+	k.SystemPromptMode = item.SystemPromptMode
+	k.ModerationMode = item.ModerationMode
+	k.LogContent = item.LogContent
+	k.Simulate = item.Simulate
+	k.SuppressReasoning = item.SuppressReasoning
+	k.Quota = item.Quota
+	k.ImageQuota = item.ImageQuota
+	k.ExpiresAt = item.ExpiresAt
+	k.RateLimit5h = item.RateLimit5h
+	k.RateLimit1d = item.RateLimit1d
+	k.RateLimit7d = item.RateLimit7d
+	k.RPMLimit = item.RPMLimit
+	k.TPMLimit = item.TPMLimit
+	k.DailyRequestLimit = item.DailyRequestLimit
+	k.DailyTokenLimit = item.DailyTokenLimit
+	k.MonthlyRequestLimit = item.MonthlyRequestLimit
+	k.MonthlyTokenLimit = item.MonthlyTokenLimit
+	k.BudgetAmount = item.BudgetAmount
+	k.BudgetPeriod = item.BudgetPeriod
+	k.BudgetAction = item.BudgetAction
+	k.BudgetFallbackModel = item.BudgetFallbackModel
+}
+
+func backupGroupNames(ids []int64, snap *backupSnapshot) []string {
+	if len(ids) == 0 {
+		return nil
+	}
+	names := make([]string, 0, len(ids))
+	for _, id := range ids {
+		if name, ok := snap.groupNameByID[id]; ok {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+// backupFieldChanges 比较两条记录的 JSON 表示，返回有差异的字段名
+func backupFieldChanges(current, desired any) []string {
+	a, errA := backupFieldMap(current)
+	b, errB := backupFieldMap(desired)
+	if errA != nil || errB != nil {
+		return []string{"*"}
+	}
+	var changes []string
+	for key, value := range b {
+		if !reflect.DeepEqual(a[key], value) {
+			changes = append(changes, key)
+		}
+	}
+	for key := range a {
+		if _, ok := b[key]; !ok {
+			changes = append(changes, key)
+		}
+	}
+	sort.Strings(changes)
+	return changes
+}
+
+func backupFieldMap(v any) (map[string]any, error) {
+	raw, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	var out map[string]any
+	if err := json.Unmarshal(raw, &out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func sortedBackupKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
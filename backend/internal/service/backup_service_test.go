@@ -0,0 +1,163 @@
+//go:build unit
+
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	infraerrors "github.com/ShaohongDong/sub2api/internal/pkg/errors"
+	"github.com/ShaohongDong/sub2api/internal/pkg/pagination"
+	"github.com/stretchr/testify/require"
+)
+
+type backupProxyRepoStub struct {
+	ProxyRepository
+	proxies []Proxy
+	created []*Proxy
+	updated []*Proxy
+}
+
+func (r *backupProxyRepoStub) List(_ context.Context, params pagination.PaginationParams) ([]Proxy, *pagination.PaginationResult, error) {
+	if params.Page > 1 {
+		return nil, &pagination.PaginationResult{Total: int64(len(r.proxies))}, nil
+	}
+	return r.proxies, &pagination.PaginationResult{Total: int64(len(r.proxies))}, nil
+}
+
+func (r *backupProxyRepoStub) Create(_ context.Context, proxy *Proxy) error {
+	proxy.ID = int64(100 + len(r.created))
+	r.created = append(r.created, proxy)
+	return nil
+}
+
+func (r *backupProxyRepoStub) Update(_ context.Context, proxy *Proxy) error {
+	r.updated = append(r.updated, proxy)
+	return nil
+}
+
+type backupSettingRepoStub struct {
+	SettingRepository
+	values map[string]string
+	writes []map[string]string
+}
+
+func (r *backupSettingRepoStub) GetAll(context.Context) (map[string]string, error) {
+	out := make(map[string]string, len(r.values))
+	for k, v := range r.values {
+		out[k] = v
+	}
+	return out, nil
+}
+
+func (r *backupSettingRepoStub) SetMultiple(_ context.Context, settings map[string]string) error {
+	r.writes = append(r.writes, settings)
+	for k, v := range settings {
+		r.values[k] = v
+	}
+	return nil
+}
+
+func TestBackupArchive_EncryptDecryptRoundTrip(t *testing.T) {
+	archive := &BackupArchive{
+		Version:   BackupArchiveVersion,
+		CreatedAt: time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC),
+		Sections:  []string{BackupSectionSettings},
+		Settings:  map[string]string{"site_name": "sub2api"},
+	}
+	data, err := EncryptBackupArchive(archive, "correct horse battery")
+	require.NoError(t, err)
+	require.NotContains(t, string(data), "site_name")
+
+	got, err := DecryptBackupArchive(data, "correct horse battery")
+	require.NoError(t, err)
+	require.Equal(t, archive.Settings, got.Settings)
+	require.True(t, got.CreatedAt.Equal(archive.CreatedAt))
+
+	_, err = DecryptBackupArchive(data, "wrong horse battery")
+	require.ErrorIs(t, err, ErrBackupDecryptFailed)
+
+	_, err = DecryptBackupArchive([]byte("plain text"), "correct horse battery")
+	require.ErrorIs(t, err, ErrBackupArchiveInvalid)
+}
+
+func TestBackupArchive_RejectsShortPassphrase(t *testing.T) {
+	_, err := EncryptBackupArchive(&BackupArchive{Version: BackupArchiveVersion}, "short")
+	require.ErrorIs(t, err, ErrBackupPassphraseTooShort)
+}
+
+func TestParseBackupSections(t *testing.T) {
+	sections, err := ParseBackupSections("")
+	require.NoError(t, err)
+	require.Equal(t, BackupSections, sections)
+
+	sections, err = ParseBackupSections("settings, accounts,proxies")
+	require.NoError(t, err)
+	require.Equal(t, []string{BackupSectionProxies, BackupSectionAccounts, BackupSectionSettings}, sections)
+
+	_, err = ParseBackupSections("accounts,usage_logs")
+	require.Equal(t, "BACKUP_SECTION_INVALID", infraerrors.Reason(err))
+}
+
+func TestBackupService_RestoreDryRunReportsDiff(t *testing.T) {
+	proxyRepo := &backupProxyRepoStub{proxies: []Proxy{
+		{ID: 1, Name: "hk", Protocol: "http", Host: "10.0.0.1", Port: 3128, Password: "old", Status: StatusActive},
+	}}
+	settingRepo := &backupSettingRepoStub{values: map[string]string{"site_name": "old", "same": "v"}}
+	svc := NewBackupService(proxyRepo, nil, nil, nil, nil, settingRepo, nil, nil)
+
+	archive := &BackupArchive{
+		Version:  BackupArchiveVersion,
+		Sections: []string{BackupSectionProxies, BackupSectionSettings},
+		Proxies: []BackupProxy{
+			{Name: "hk", Protocol: "http", Host: "10.0.0.1", Port: 3128, Password: "new", Status: StatusActive},
+			{Name: "jp", Protocol: "socks5", Host: "10.0.0.2", Port: 1080, Status: StatusActive},
+		},
+		Settings: map[string]string{"site_name": "new", "same": "v", "fresh": "1"},
+	}
+
+	report, err := svc.Restore(context.Background(), archive, BackupRestoreOptions{DryRun: true, Overwrite: true})
+	require.NoError(t, err)
+	require.Equal(t, &BackupRestoreCounts{Created: 1, Updated: 1}, report.Summary[BackupSectionProxies])
+	require.Equal(t, &BackupRestoreCounts{Created: 1, Updated: 1, Unchanged: 1}, report.Summary[BackupSectionSettings])
+	require.Equal(t, []string{"password"}, report.Items[0].Changes)
+	require.Empty(t, proxyRepo.created)
+	require.Empty(t, proxyRepo.updated)
+	require.Empty(t, settingRepo.writes)
+}
+
+func TestBackupService_RestoreSkipsExistingWithoutOverwrite(t *testing.T) {
+	proxyRepo := &backupProxyRepoStub{proxies: []Proxy{
+		{ID: 1, Name: "hk", Protocol: "http", Host: "10.0.0.1", Port: 3128, Password: "old", Status: StatusActive},
+	}}
+	settingRepo := &backupSettingRepoStub{values: map[string]string{"site_name": "old"}}
+	svc := NewBackupService(proxyRepo, nil, nil, nil, nil, settingRepo, nil, nil)
+
+	archive := &BackupArchive{
+		Version:  BackupArchiveVersion,
+		Sections: []string{BackupSectionProxies, BackupSectionSettings},
+		Proxies: []BackupProxy{
+			{Name: "hk", Protocol: "http", Host: "10.0.0.1", Port: 3128, Password: "new", Status: StatusActive},
+			{Name: "jp", Protocol: "socks5", Host: "10.0.0.2", Port: 1080},
+		},
+		Settings: map[string]string{"site_name": "new", "fresh": "1"},
+	}
+
+	report, err := svc.Restore(context.Background(), archive, BackupRestoreOptions{})
+	require.NoError(t, err)
+	require.Equal(t, &BackupRestoreCounts{Created: 1, Skipped: 1}, report.Summary[BackupSectionProxies])
+	require.Len(t, proxyRepo.created, 1)
+	require.Equal(t, StatusActive, proxyRepo.created[0].Status)
+	require.Empty(t, proxyRepo.updated)
+	require.Equal(t, map[string]string{"site_name": "old", "fresh": "1"}, settingRepo.values)
+}
+
+func TestBackupService_RestoreSelectedSectionMustBeInBackup(t *testing.T) {
+	svc := NewBackupService(nil, nil, nil, nil, nil, &backupSettingRepoStub{values: map[string]string{}}, nil, nil)
+	archive := &BackupArchive{Version: BackupArchiveVersion, Sections: []string{BackupSectionSettings}}
+
+	_, err := svc.Restore(context.Background(), archive, BackupRestoreOptions{Sections: []string{BackupSectionAccounts}})
+	require.True(t, errors.Is(err, ErrBackupSectionInvalid))
+}
@@ -0,0 +1,117 @@
+package service
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	infraerrors "github.com/ShaohongDong/sub2api/internal/pkg/errors"
+)
+
+// Batch job statuses (OpenAI Batch API compatible).
+const (
+	BatchStatusInProgress = "in_progress"
+	BatchStatusCompleted  = "completed"
+	BatchStatusFailed     = "failed"
+	BatchStatusCancelling = "cancelling"
+	BatchStatusCancelled  = "cancelled"
+	BatchStatusExpired    = "expired"
+)
+
+// Batch item statuses.
+const (
+	BatchItemStatusPending   = "pending"
+	BatchItemStatusRunning   = "running"
+	BatchItemStatusCompleted = "completed"
+	BatchItemStatusFailed    = "failed"
+	BatchItemStatusCancelled = "cancelled"
+	BatchItemStatusExpired   = "expired"
+)
+
+var (
+	ErrBatchNotFound      = infraerrors.NotFound("BATCH_NOT_FOUND", "batch not found")
+	ErrBatchDisabled      = infraerrors.ServiceUnavailable("BATCH_DISABLED", "batch api is disabled")
+	ErrBatchTooManyActive = infraerrors.TooManyRequests("BATCH_TOO_MANY_ACTIVE", "too many in-progress batches for this api key")
+	ErrBatchNotCancelable = infraerrors.Conflict("BATCH_NOT_CANCELABLE", "batch can no longer be cancelled")
+)
+
+// BatchJob is one submitted batch.
+type BatchJob struct {
+	ID               string
+	UserID           int64
+	APIKeyID         int64
+	Endpoint         string
+	CompletionWindow string
+	Status           string
+	ClientIP         string
+	Metadata         map[string]string
+	TotalCount       int
+	CompletedCount   int
+	FailedCount      int
+	ErrorMessage     string
+	ExpiresAt        time.Time
+	InProgressAt     *time.Time
+	CompletedAt      *time.Time
+	CancellingAt     *time.Time
+	CancelledAt      *time.Time
+	ExpiredAt        *time.Time
+	CreatedAt        time.Time
+	UpdatedAt        time.Time
+}
+
+// IsActive reports whether the worker still has to process the job.
+func (j *BatchJob) IsActive() bool {
+	return j.Status == BatchStatusInProgress || j.Status == BatchStatusCancelling
+}
+
+// BatchJobItem is one JSONL line of a batch and its result.
+type BatchJobItem struct {
+	ID             int64
+	JobID          string
+	LineIndex      int
+	CustomID       string
+	Method         string
+	URL            string
+	Body           []byte
+	Status         string
+	Attempts       int
+	ResponseStatus int
+	ResponseBody   []byte
+	RequestID      string
+	ErrorMessage   string
+}
+
+// BatchJobRepository defines the data access interface for batch jobs.
+type BatchJobRepository interface {
+	CreateJob(ctx context.Context, job *BatchJob, items []*BatchJobItem) error
+	GetJob(ctx context.Context, id string) (*BatchJob, error)
+	ListJobsByAPIKey(ctx context.Context, apiKeyID int64, after string, limit int) ([]*BatchJob, error)
+	CountActiveJobsByAPIKey(ctx context.Context, apiKeyID int64) (int, error)
+	ListActiveJobs(ctx context.Context) ([]*BatchJob, error)
+	UpdateJob(ctx context.Context, job *BatchJob) error
+
+	// ClaimItems 认领待执行的请求（含执行超时的 running 项），并发安全。
+	ClaimItems(ctx context.Context, jobID string, limit int, staleBefore time.Time) ([]*BatchJobItem, error)
+	FinishItem(ctx context.Context, item *BatchJobItem) error
+	RetryItem(ctx context.Context, itemID int64, nextAttemptAt time.Time, errMsg string) error
+	// AbortPendingItems 将 pending 及 staleBefore 之前开始的 running 项标记为终态。
+	AbortPendingItems(ctx context.Context, jobID string, status string, errMsg string, staleBefore time.Time) (int64, error)
+	CountItemsByStatus(ctx context.Context, jobID string) (map[string]int, error)
+	ListItemResults(ctx context.Context, jobID string, afterLine int, limit int) ([]*BatchJobItem, error)
+
+	DeleteFinishedBefore(ctx context.Context, before time.Time) (int64, error)
+}
+
+// BatchDispatchResult is the response of one in-process batch request.
+type BatchDispatchResult struct {
+	StatusCode int
+	Header     http.Header
+	Body       []byte
+}
+
+// BatchRequestDispatcher executes one batch request through the gateway so
+// that auth, scheduling, rate limits and billing apply exactly as for a
+// regular client call.
+type BatchRequestDispatcher interface {
+	Dispatch(ctx context.Context, apiKey string, clientIP string, method string, url string, body []byte) (*BatchDispatchResult, error)
+}
@@ -0,0 +1,653 @@
+package service
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/ShaohongDong/sub2api/internal/config"
+	infraerrors "github.com/ShaohongDong/sub2api/internal/pkg/errors"
+	"github.com/ShaohongDong/sub2api/internal/pkg/logger"
+	"github.com/google/uuid"
+	"github.com/tidwall/gjson"
+)
+
+const (
+	batchWorkerName              = "batch_worker"
+	batchDefaultCompletionWindow = "24h"
+	batchMaxCompletionWindow     = 7 * 24 * time.Hour
+	batchOutputPageSize          = 500
+	batchMaxRetryBackoff         = 5 * time.Minute
+	batchListDefaultLimit        = 20
+	batchListMaxLimit            = 100
+	batchMaxCustomIDLength       = 255
+)
+
+// batchSupportedEndpoints 允许出现在批处理中的网关端点（均为非流式 JSON 请求）。
+var batchSupportedEndpoints = map[string]struct{}{
+	"/v1/responses":        {},
+	"/v1/chat/completions": {},
+	"/v1/completions":      {},
+	"/v1/embeddings":       {},
+	"/v1/messages":         {},
+}
+
+// CreateBatchInput 创建批处理任务的参数。
+type CreateBatchInput struct {
+	// Endpoint 为空时取 JSONL 首行的 url
+	Endpoint         string
+	CompletionWindow string
+	Metadata         map[string]string
+	Input            []byte // JSONL
+	ClientIP         string
+}
+
+// batchInputLine 是 JSONL 中的一行（OpenAI Batch API 输入格式）。
+type batchInputLine struct {
+	CustomID string          `json:"custom_id"`
+	Method   string          `json:"method"`
+	URL      string          `json:"url"`
+	Body     json.RawMessage `json:"body"`
+}
+
+// batchOutputLine 是结果 JSONL 中的一行（OpenAI Batch API 输出格式）。
+type batchOutputLine struct {
+	ID       string               `json:"id"`
+	CustomID string               `json:"custom_id"`
+	Response *batchOutputResponse `json:"response"`
+	Error    *batchOutputError    `json:"error"`
+}
+
+type batchOutputResponse struct {
+	StatusCode int             `json:"status_code"`
+	RequestID  string          `json:"request_id"`
+	Body       json.RawMessage `json:"body"`
+}
+
+type batchOutputError struct {
+	Code    string `json:"code"`
+	Message string `json:"message"`
+}
+
+// BatchService 负责 /v1/batches 任务的创建、查询，以及后台将请求分发到账号池执行。
+//
+// 每条请求经由 BatchRequestDispatcher 在进程内重放到网关路由，
+// 因此鉴权、分组调度、并发/限流与计费逻辑与普通请求完全一致；
+// 遇到 429/过载时按退避重新排队。
+type BatchService struct {
+	repo        BatchJobRepository
+	apiKeyRepo  APIKeyRepository
+	timingWheel *TimingWheelService
+	cfg         *config.Config
+
+	dispatcherMu sync.RWMutex
+	dispatcher   BatchRequestDispatcher
+
+	running   int32
+	startOnce sync.Once
+	stopOnce  sync.Once
+
+	workerCtx    context.Context
+	workerCancel context.CancelFunc
+}
+
+func NewBatchService(repo BatchJobRepository, apiKeyRepo APIKeyRepository, timingWheel *TimingWheelService, cfg *config.Config) *BatchService {
+	workerCtx, workerCancel := context.WithCancel(context.Background())
+	return &BatchService{
+		repo:         repo,
+		apiKeyRepo:   apiKeyRepo,
+		timingWheel:  timingWheel,
+		cfg:          cfg,
+		workerCtx:    workerCtx,
+		workerCancel: workerCancel,
+	}
+}
+
+// SetDispatcher 注入请求分发器（路由构建完成后由 server 层设置）。
+func (s *BatchService) SetDispatcher(d BatchRequestDispatcher) {
+	if s == nil {
+		return
+	}
+	s.dispatcherMu.Lock()
+	s.dispatcher = d
+	s.dispatcherMu.Unlock()
+}
+
+func (s *BatchService) getDispatcher() BatchRequestDispatcher {
+	s.dispatcherMu.RLock()
+	defer s.dispatcherMu.RUnlock()
+	return s.dispatcher
+}
+
+func (s *BatchService) Start() {
+	if s == nil {
+		return
+	}
+	if s.cfg != nil && !s.cfg.Batch.Enabled {
+		logger.LegacyPrintf("service.batch", "[Batch] not started (disabled)")
+		return
+	}
+	if s.repo == nil || s.timingWheel == nil {
+		logger.LegacyPrintf("service.batch", "[Batch] not started (missing deps)")
+		return
+	}
+
+	interval := s.workerInterval()
+	s.startOnce.Do(func() {
+		s.timingWheel.ScheduleRecurring(batchWorkerName, interval, s.runOnce)
+		logger.LegacyPrintf("service.batch", "[Batch] started (interval=%s worker_concurrency=%d per_job_concurrency=%d)", interval, s.workerConcurrency(), s.perJobConcurrency())
+	})
+}
+
+func (s *BatchService) Stop() {
+	if s == nil {
+		return
+	}
+	s.stopOnce.Do(func() {
+		if s.workerCancel != nil {
+			s.workerCancel()
+		}
+		if s.timingWheel != nil {
+			s.timingWheel.Cancel(batchWorkerName)
+		}
+		logger.LegacyPrintf("service.batch", "[Batch] stopped")
+	})
+}
+
+// CreateBatch 校验 JSONL 并持久化批处理任务，后台执行器随后开始分发。
+func (s *BatchService) CreateBatch(ctx context.Context, apiKey *APIKey, in CreateBatchInput) (*BatchJob, error) {
+	if s.cfg != nil && !s.cfg.Batch.Enabled {
+		return nil, ErrBatchDisabled
+	}
+	if apiKey == nil {
+		return nil, infraerrors.Unauthorized("BATCH_INVALID_API_KEY", "invalid api key")
+	}
+
+	window := strings.TrimSpace(in.CompletionWindow)
+	if window == "" {
+		window = batchDefaultCompletionWindow
+	}
+	windowDuration, err := time.ParseDuration(window)
+	if err != nil || windowDuration <= 0 || windowDuration > batchMaxCompletionWindow {
+		return nil, infraerrors.BadRequest("BATCH_INVALID_COMPLETION_WINDOW", "completion_window must be a duration up to 168h, e.g. 24h")
+	}
+
+	endpoint, items, err := ParseBatchInput(in.Input, in.Endpoint, s.maxRequestsPerBatch())
+	if err != nil {
+		return nil, err
+	}
+
+	if limit := s.maxActiveJobsPerKey(); limit > 0 {
+		active, err := s.repo.CountActiveJobsByAPIKey(ctx, apiKey.ID)
+		if err != nil {
+			return nil, fmt.Errorf("count active batches: %w", err)
+		}
+		if active >= limit {
+			return nil, ErrBatchTooManyActive
+		}
+	}
+
+	now := time.Now()
+	job := &BatchJob{
+		ID:               "batch_" + strings.ReplaceAll(uuid.NewString(), "-", ""),
+		UserID:           apiKey.UserID,
+		APIKeyID:         apiKey.ID,
+		Endpoint:         endpoint,
+		CompletionWindow: window,
+		Status:           BatchStatusInProgress,
+		ClientIP:         in.ClientIP,
+		Metadata:         in.Metadata,
+		TotalCount:       len(items),
+		ExpiresAt:        now.Add(windowDuration),
+		InProgressAt:     &now,
+	}
+	if err := s.repo.CreateJob(ctx, job, items); err != nil {
+		return nil, fmt.Errorf("create batch: %w", err)
+	}
+	logger.LegacyPrintf("service.batch", "[Batch] created: batch=%s api_key=%d endpoint=%s requests=%d", job.ID, apiKey.ID, endpoint, len(items))
+	go s.runOnce()
+	return job, nil
+}
+
+// ParseBatchInput 解析并校验 JSONL：每行需包含唯一 custom_id、method=POST、
+// 与任务一致的 url 以及对象类型的 body，且不允许流式请求。
+func ParseBatchInput(data []byte, endpoint string, maxLines int) (string, []*BatchJobItem, error) {
+	endpoint = strings.TrimSpace(endpoint)
+	items := make([]*BatchJobItem, 0, 64)
+	seen := make(map[string]struct{})
+
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	scanner.Buffer(make([]byte, 0, 64*1024), len(data)+1)
+	lineNo := 0
+	for scanner.Scan() {
+		lineNo++
+		raw := bytes.TrimSpace(scanner.Bytes())
+		if len(raw) == 0 {
+			continue
+		}
+		if maxLines > 0 && len(items) >= maxLines {
+			return "", nil, infraerrors.Newf(http.StatusBadRequest, "BATCH_TOO_MANY_REQUESTS", "batch exceeds the limit of %d requests", maxLines)
+		}
+
+		var line batchInputLine
+		if err := json.Unmarshal(raw, &line); err != nil {
+			return "", nil, batchLineError(lineNo, "invalid JSON")
+		}
+		if line.CustomID == "" {
+			return "", nil, batchLineError(lineNo, "custom_id is required")
+		}
+		if len(line.CustomID) > batchMaxCustomIDLength {
+			return "", nil, batchLineError(lineNo, "custom_id is too long")
+		}
+		if _, dup := seen[line.CustomID]; dup {
+			return "", nil, batchLineError(lineNo, "duplicate custom_id "+strconv.Quote(line.CustomID))
+		}
+		seen[line.CustomID] = struct{}{}
+		if line.Method != "" && !strings.EqualFold(line.Method, http.MethodPost) {
+			return "", nil, batchLineError(lineNo, "method must be POST")
+		}
+		if endpoint == "" {
+			endpoint = line.URL
+		}
+		if _, ok := batchSupportedEndpoints[endpoint]; !ok {
+			return "", nil, infraerrors.Newf(http.StatusBadRequest, "BATCH_UNSUPPORTED_ENDPOINT", "unsupported batch endpoint %q", endpoint)
+		}
+		if line.URL != "" && line.URL != endpoint {
+			return "", nil, batchLineError(lineNo, "url must match the batch endpoint "+endpoint)
+		}
+		body := gjson.ParseBytes(line.Body)
+		if !body.IsObject() {
+			return "", nil, batchLineError(lineNo, "body must be a JSON object")
+		}
+		if body.Get("stream").Bool() {
+			return "", nil, batchLineError(lineNo, "streaming requests are not supported in batches")
+		}
+
+		items = append(items, &BatchJobItem{
+			LineIndex: len(items),
+			CustomID:  line.CustomID,
+			Method:    http.MethodPost,
+			URL:       endpoint,
+			Body:      []byte(line.Body),
+		})
+	}
+	if err := scanner.Err(); err != nil {
+		return "", nil, infraerrors.BadRequest("BATCH_INVALID_INPUT", "failed to read input: "+err.Error())
+	}
+	if len(items) == 0 {
+		return "", nil, infraerrors.BadRequest("BATCH_EMPTY_INPUT", "input contains no requests")
+	}
+	return endpoint, items, nil
+}
+
+func batchLineError(lineNo int, msg string) error {
+	return infraerrors.Newf(http.StatusBadRequest, "BATCH_INVALID_INPUT", "line %d: %s", lineNo, msg)
+}
+
+// GetBatch 返回属于该 API Key 的任务。
+func (s *BatchService) GetBatch(ctx context.Context, apiKeyID int64, id string) (*BatchJob, error) {
+	job, err := s.repo.GetJob(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if job.APIKeyID != apiKeyID {
+		return nil, ErrBatchNotFound
+	}
+	return job, nil
+}
+
+// ListBatches 按创建时间倒序列出任务，after 为上一页最后一个任务 ID。
+func (s *BatchService) ListBatches(ctx context.Context, apiKeyID int64, after string, limit int) ([]*BatchJob, error) {
+	if limit <= 0 {
+		limit = batchListDefaultLimit
+	}
+	if limit > batchListMaxLimit {
+		limit = batchListMaxLimit
+	}
+	return s.repo.ListJobsByAPIKey(ctx, apiKeyID, after, limit)
+}
+
+// CancelBatch 将进行中的任务标记为 cancelling，执行器停止分发剩余请求后转为 cancelled。
+func (s *BatchService) CancelBatch(ctx context.Context, apiKeyID int64, id string) (*BatchJob, error) {
+	job, err := s.GetBatch(ctx, apiKeyID, id)
+	if err != nil {
+		return nil, err
+	}
+	switch job.Status {
+	case BatchStatusCancelling, BatchStatusCancelled:
+		return job, nil
+	case BatchStatusInProgress:
+	default:
+		return nil, ErrBatchNotCancelable
+	}
+	now := time.Now()
+	job.Status = BatchStatusCancelling
+	job.CancellingAt = &now
+	if err := s.repo.UpdateJob(ctx, job); err != nil {
+		return nil, fmt.Errorf("cancel batch: %w", err)
+	}
+	go s.runOnce()
+	return job, nil
+}
+
+// WriteBatchOutput 按行序写出已结束请求的结果 JSONL。
+func (s *BatchService) WriteBatchOutput(ctx context.Context, job *BatchJob, w io.Writer) error {
+	enc := json.NewEncoder(w)
+	enc.SetEscapeHTML(false)
+	after := -1
+	for {
+		items, err := s.repo.ListItemResults(ctx, job.ID, after, batchOutputPageSize)
+		if err != nil {
+			return err
+		}
+		for _, item := range items {
+			if err := enc.Encode(batchOutputLineFromItem(item)); err != nil {
+				return err
+			}
+			after = item.LineIndex
+		}
+		if len(items) < batchOutputPageSize {
+			return nil
+		}
+	}
+}
+
+func batchOutputLineFromItem(item *BatchJobItem) batchOutputLine {
+	line := batchOutputLine{
+		ID:       "batch_req_" + strconv.FormatInt(item.ID, 10),
+		CustomID: item.CustomID,
+	}
+	if item.ResponseStatus > 0 {
+		body := json.RawMessage(item.ResponseBody)
+		if !json.Valid(body) {
+			body, _ = json.Marshal(string(item.ResponseBody))
+		}
+		line.Response = &batchOutputResponse{
+			StatusCode: item.ResponseStatus,
+			RequestID:  item.RequestID,
+			Body:       body,
+		}
+	}
+	if item.Status != BatchItemStatusCompleted {
+		code := item.Status
+		if item.Status == BatchItemStatusFailed && item.ResponseStatus > 0 {
+			code = "http_" + strconv.Itoa(item.ResponseStatus)
+		}
+		line.Error = &batchOutputError{Code: code, Message: item.ErrorMessage}
+	}
+	return line
+}
+
+func (s *BatchService) runOnce() {
+	if s == nil || s.repo == nil {
+		return
+	}
+	if !atomic.CompareAndSwapInt32(&s.running, 0, 1) {
+		return
+	}
+	defer atomic.StoreInt32(&s.running, 0)
+
+	ctx := s.workerCtx
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	if deleted, err := s.repo.DeleteFinishedBefore(ctx, time.Now().Add(-s.retention())); err != nil {
+		logger.LegacyPrintf("service.batch", "[Batch] cleanup failed: %v", err)
+	} else if deleted > 0 {
+		logger.LegacyPrintf("service.batch", "[Batch] cleanup removed %d finished batches", deleted)
+	}
+
+	dispatcher := s.getDispatcher()
+	if dispatcher == nil {
+		return
+	}
+	// 持续分发直到本轮没有可认领的请求（退避中的请求留给后续轮询）
+	for ctx.Err() == nil {
+		if !s.dispatchPass(ctx, dispatcher) {
+			return
+		}
+	}
+}
+
+// dispatchPass 对所有进行中的任务各认领一批请求并并发执行，返回本轮是否执行了请求。
+func (s *BatchService) dispatchPass(ctx context.Context, dispatcher BatchRequestDispatcher) bool {
+	jobs, err := s.repo.ListActiveJobs(ctx)
+	if err != nil {
+		logger.LegacyPrintf("service.batch", "[Batch] list active batches failed: %v", err)
+		return false
+	}
+
+	sem := make(chan struct{}, s.workerConcurrency())
+	var wg sync.WaitGroup
+	dispatched := false
+	for _, job := range jobs {
+		if !s.settleJob(ctx, job) {
+			continue
+		}
+		apiKey, err := s.apiKeyRepo.GetByID(ctx, job.APIKeyID)
+		if err != nil {
+			s.failJob(ctx, job, "api key is no longer available")
+			continue
+		}
+		items, err := s.repo.ClaimItems(ctx, job.ID, s.perJobConcurrency(), time.Now().Add(-s.itemStaleAfter()))
+		if err != nil {
+			logger.LegacyPrintf("service.batch", "[Batch] claim items failed: batch=%s err=%v", job.ID, err)
+			continue
+		}
+		for _, item := range items {
+			dispatched = true
+			sem <- struct{}{}
+			wg.Add(1)
+			go func(job *BatchJob, item *BatchJobItem) {
+				defer wg.Done()
+				defer func() { <-sem }()
+				s.executeItem(ctx, dispatcher, apiKey.Key, job, item)
+			}(job, item)
+		}
+	}
+	wg.Wait()
+	return dispatched
+}
+
+// settleJob 处理取消、过期与完成状态的迁移，返回任务是否仍可分发请求。
+func (s *BatchService) settleJob(ctx context.Context, job *BatchJob) bool {
+	now := time.Now()
+	staleBefore := now.Add(-s.itemStaleAfter())
+
+	switch {
+	case job.Status == BatchStatusCancelling:
+		if _, err := s.repo.AbortPendingItems(ctx, job.ID, BatchItemStatusCancelled, "batch was cancelled before this request was processed", staleBefore); err != nil {
+			logger.LegacyPrintf("service.batch", "[Batch] abort cancelled items failed: batch=%s err=%v", job.ID, err)
+			return false
+		}
+	case now.After(job.ExpiresAt):
+		if _, err := s.repo.AbortPendingItems(ctx, job.ID, BatchItemStatusExpired, "batch expired before this request was processed", staleBefore); err != nil {
+			logger.LegacyPrintf("service.batch", "[Batch] abort expired items failed: batch=%s err=%v", job.ID, err)
+			return false
+		}
+	}
+
+	counts, err := s.repo.CountItemsByStatus(ctx, job.ID)
+	if err != nil {
+		logger.LegacyPrintf("service.batch", "[Batch] count items failed: batch=%s err=%v", job.ID, err)
+		return false
+	}
+	job.CompletedCount = counts[BatchItemStatusCompleted]
+	job.FailedCount = counts[BatchItemStatusFailed]
+	remaining := counts[BatchItemStatusPending] + counts[BatchItemStatusRunning]
+
+	if remaining == 0 || (job.Status != BatchStatusInProgress && counts[BatchItemStatusRunning] == 0) {
+		switch {
+		case job.Status == BatchStatusCancelling:
+			job.Status = BatchStatusCancelled
+			job.CancelledAt = &now
+		case counts[BatchItemStatusExpired] > 0:
+			job.Status = BatchStatusExpired
+			job.ExpiredAt = &now
+		default:
+			job.Status = BatchStatusCompleted
+			job.CompletedAt = &now
+		}
+		logger.LegacyPrintf("service.batch", "[Batch] finished: batch=%s status=%s completed=%d failed=%d", job.ID, job.Status, job.CompletedCount, job.FailedCount)
+	}
+	if err := s.repo.UpdateJob(ctx, job); err != nil {
+		logger.LegacyPrintf("service.batch", "[Batch] update batch failed: batch=%s err=%v", job.ID, err)
+		return false
+	}
+	return job.Status == BatchStatusInProgress && remaining > 0
+}
+
+func (s *BatchService) failJob(ctx context.Context, job *BatchJob, msg string) {
+	now := time.Now()
+	if _, err := s.repo.AbortPendingItems(ctx, job.ID, BatchItemStatusFailed, msg, now); err != nil {
+		logger.LegacyPrintf("service.batch", "[Batch] abort items failed: batch=%s err=%v", job.ID, err)
+	}
+	job.Status = BatchStatusFailed
+	job.ErrorMessage = msg
+	job.CompletedAt = &now
+	if err := s.repo.UpdateJob(ctx, job); err != nil {
+		logger.LegacyPrintf("service.batch", "[Batch] mark batch failed: batch=%s err=%v", job.ID, err)
+	}
+	logger.LegacyPrintf("service.batch", "[Batch] failed: batch=%s reason=%s", job.ID, msg)
+}
+
+// executeItem 执行单条请求：2xx 记为完成；429/过载在尝试次数内退避重排；其余记为失败。
+func (s *BatchService) executeItem(ctx context.Context, dispatcher BatchRequestDispatcher, apiKey string, job *BatchJob, item *BatchJobItem) {
+	reqCtx, cancel := context.WithTimeout(ctx, s.requestTimeout())
+	defer cancel()
+
+	res, err := dispatcher.Dispatch(reqCtx, apiKey, job.ClientIP, item.Method, item.URL, item.Body)
+	if ctx.Err() != nil {
+		// 服务停止：保持 running，重启后按超时重新认领
+		return
+	}
+
+	if err != nil || batchRetryableStatus(res.StatusCode) {
+		if item.Attempts < s.maxAttempts() {
+			var header http.Header
+			errMsg := ""
+			if err != nil {
+				errMsg = err.Error()
+			} else {
+				header = res.Header
+				errMsg = fmt.Sprintf("upstream returned %d", res.StatusCode)
+			}
+			next := time.Now().Add(batchRetryDelay(item.Attempts, header))
+			if rerr := s.repo.RetryItem(ctx, item.ID, next, errMsg); rerr != nil {
+				logger.LegacyPrintf("service.batch", "[Batch] requeue item failed: batch=%s item=%d err=%v", job.ID, item.ID, rerr)
+			}
+			return
+		}
+	}
+
+	if err != nil {
+		item.Status = BatchItemStatusFailed
+		item.ErrorMessage = err.Error()
+	} else {
+		item.ResponseStatus = res.StatusCode
+		item.ResponseBody = res.Body
+		item.RequestID = res.Header.Get("x-request-id")
+		if res.StatusCode >= 200 && res.StatusCode < 300 {
+			item.Status = BatchItemStatusCompleted
+		} else {
+			item.Status = BatchItemStatusFailed
+			item.ErrorMessage = extractUpstreamErrorMessage(res.Body)
+			if item.ErrorMessage == "" {
+				item.ErrorMessage = http.StatusText(res.StatusCode)
+			}
+		}
+	}
+	if ferr := s.repo.FinishItem(ctx, item); ferr != nil {
+		logger.LegacyPrintf("service.batch", "[Batch] save item result failed: batch=%s item=%d err=%v", job.ID, item.ID, ferr)
+	}
+}
+
+// batchRetryableStatus 判断是否为限流/过载类响应（应退避后重试而非记为失败）。
+func batchRetryableStatus(status int) bool {
+	return status == http.StatusTooManyRequests || status == http.StatusServiceUnavailable || status == 529
+}
+
+// batchRetryDelay 优先使用 Retry-After，否则按尝试次数指数退避（10s 起，最长 5 分钟）。
+func batchRetryDelay(attempts int, header http.Header) time.Duration {
+	if header != nil {
+		if secs, err := strconv.Atoi(strings.TrimSpace(header.Get("Retry-After"))); err == nil && secs > 0 {
+			return min(time.Duration(secs)*time.Second, batchMaxRetryBackoff)
+		}
+	}
+	if attempts < 1 {
+		attempts = 1
+	}
+	delay := 10 * time.Second << min(attempts-1, 5)
+	return min(delay, batchMaxRetryBackoff)
+}
+
+func (s *BatchService) workerInterval() time.Duration {
+	if s.cfg != nil && s.cfg.Batch.WorkerIntervalSeconds > 0 {
+		return time.Duration(s.cfg.Batch.WorkerIntervalSeconds) * time.Second
+	}
+	return 5 * time.Second
+}
+
+func (s *BatchService) workerConcurrency() int {
+	if s.cfg != nil && s.cfg.Batch.WorkerConcurrency > 0 {
+		return s.cfg.Batch.WorkerConcurrency
+	}
+	return 8
+}
+
+func (s *BatchService) perJobConcurrency() int {
+	if s.cfg != nil && s.cfg.Batch.PerJobConcurrency > 0 {
+		return s.cfg.Batch.PerJobConcurrency
+	}
+	return 4
+}
+
+func (s *BatchService) maxRequestsPerBatch() int {
+	if s.cfg != nil && s.cfg.Batch.MaxRequestsPerBatch > 0 {
+		return s.cfg.Batch.MaxRequestsPerBatch
+	}
+	return 50000
+}
+
+func (s *BatchService) maxActiveJobsPerKey() int {
+	if s.cfg != nil {
+		return s.cfg.Batch.MaxActiveJobsPerKey
+	}
+	return 0
+}
+
+func (s *BatchService) maxAttempts() int {
+	if s.cfg != nil && s.cfg.Batch.MaxAttempts > 0 {
+		return s.cfg.Batch.MaxAttempts
+	}
+	return 5
+}
+
+func (s *BatchService) requestTimeout() time.Duration {
+	if s.cfg != nil && s.cfg.Batch.RequestTimeoutSeconds > 0 {
+		return time.Duration(s.cfg.Batch.RequestTimeoutSeconds) * time.Second
+	}
+	return 10 * time.Minute
+}
+
+// itemStaleAfter running 状态超过该时长视为执行器已中断，可重新认领。
+func (s *BatchService) itemStaleAfter() time.Duration {
+	return s.requestTimeout() + time.Minute
+}
+
+func (s *BatchService) retention() time.Duration {
+	if s.cfg != nil && s.cfg.Batch.RetentionHours > 0 {
+		return time.Duration(s.cfg.Batch.RetentionHours) * time.Hour
+	}
+	return 7 * 24 * time.Hour
+}
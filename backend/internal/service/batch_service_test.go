@@ -0,0 +1,114 @@
+package service
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/ShaohongDong/sub2api/internal/config"
+	infraerrors "github.com/ShaohongDong/sub2api/internal/pkg/errors"
+	"github.com/stretchr/testify/require"
+)
+
+type batchRepoStub struct {
+	BatchJobRepository
+	finished []*BatchJobItem
+	retried  []int64
+}
+
+func (r *batchRepoStub) FinishItem(_ context.Context, item *BatchJobItem) error {
+	r.finished = append(r.finished, item)
+	return nil
+}
+
+func (r *batchRepoStub) RetryItem(_ context.Context, itemID int64, _ time.Time, _ string) error {
+	r.retried = append(r.retried, itemID)
+	return nil
+}
+
+type batchDispatcherStub struct {
+	res *BatchDispatchResult
+}
+
+func (d *batchDispatcherStub) Dispatch(context.Context, string, string, string, string, []byte) (*BatchDispatchResult, error) {
+	return d.res, nil
+}
+
+func TestParseBatchInput(t *testing.T) {
+	input := []byte(`{"custom_id":"a","method":"POST","url":"/v1/chat/completions","body":{"model":"gpt-5","messages":[]}}
+
+{"custom_id":"b","method":"POST","url":"/v1/chat/completions","body":{"model":"gpt-5","messages":[]}}
+`)
+	endpoint, items, err := ParseBatchInput(input, "", 10)
+	require.NoError(t, err)
+	require.Equal(t, "/v1/chat/completions", endpoint)
+	require.Len(t, items, 2)
+	require.Equal(t, 1, items[1].LineIndex)
+	require.Equal(t, "b", items[1].CustomID)
+	require.JSONEq(t, `{"model":"gpt-5","messages":[]}`, string(items[0].Body))
+}
+
+func TestParseBatchInput_Rejects(t *testing.T) {
+	cases := map[string]string{
+		"duplicate custom_id": `{"custom_id":"a","url":"/v1/responses","body":{}}` + "\n" + `{"custom_id":"a","url":"/v1/responses","body":{}}`,
+		"url mismatch":        `{"custom_id":"a","url":"/v1/responses","body":{}}` + "\n" + `{"custom_id":"b","url":"/v1/embeddings","body":{}}`,
+		"stream":              `{"custom_id":"a","url":"/v1/responses","body":{"stream":true}}`,
+		"unsupported":         `{"custom_id":"a","url":"/v1/images/generations","body":{}}`,
+		"non-object body":     `{"custom_id":"a","url":"/v1/responses","body":"x"}`,
+		"empty":               "\n\n",
+	}
+	for name, input := range cases {
+		_, _, err := ParseBatchInput([]byte(input), "", 10)
+		require.Error(t, err, name)
+		require.Equal(t, http.StatusBadRequest, infraerrors.Code(err), name)
+	}
+
+	lines := `{"custom_id":"a","url":"/v1/responses","body":{}}` + "\n" + `{"custom_id":"b","url":"/v1/responses","body":{}}`
+	_, _, err := ParseBatchInput([]byte(lines), "", 1)
+	require.Equal(t, "BATCH_TOO_MANY_REQUESTS", infraerrors.Reason(err))
+}
+
+func TestBatchOutputLineFromItem(t *testing.T) {
+	ok := batchOutputLineFromItem(&BatchJobItem{ID: 7, CustomID: "a", Status: BatchItemStatusCompleted, ResponseStatus: 200, ResponseBody: []byte(`{"id":"x"}`), RequestID: "req_1"})
+	require.Equal(t, "batch_req_7", ok.ID)
+	require.Nil(t, ok.Error)
+	require.JSONEq(t, `{"id":"x"}`, string(ok.Response.Body))
+
+	failed := batchOutputLineFromItem(&BatchJobItem{ID: 8, CustomID: "b", Status: BatchItemStatusFailed, ResponseStatus: 400, ResponseBody: []byte("bad"), ErrorMessage: "invalid"})
+	require.Equal(t, "http_400", failed.Error.Code)
+	require.JSONEq(t, `"bad"`, string(failed.Response.Body))
+
+	cancelled := batchOutputLineFromItem(&BatchJobItem{ID: 9, CustomID: "c", Status: BatchItemStatusCancelled})
+	require.Nil(t, cancelled.Response)
+	require.Equal(t, BatchItemStatusCancelled, cancelled.Error.Code)
+}
+
+func TestBatchRetryDelay(t *testing.T) {
+	require.Equal(t, 10*time.Second, batchRetryDelay(1, nil))
+	require.Equal(t, 40*time.Second, batchRetryDelay(3, nil))
+	require.Equal(t, batchMaxRetryBackoff, batchRetryDelay(10, nil))
+	require.Equal(t, 3*time.Second, batchRetryDelay(1, http.Header{"Retry-After": []string{"3"}}))
+}
+
+func TestBatchService_ExecuteItem(t *testing.T) {
+	cfg := &config.Config{Batch: config.BatchConfig{MaxAttempts: 2, RequestTimeoutSeconds: 5}}
+	job := &BatchJob{ID: "batch_1"}
+
+	repo := &batchRepoStub{}
+	svc := NewBatchService(repo, nil, nil, cfg)
+	limited := &batchDispatcherStub{res: &BatchDispatchResult{StatusCode: http.StatusTooManyRequests, Header: http.Header{}}}
+	svc.executeItem(context.Background(), limited, "sk-test", job, &BatchJobItem{ID: 1, Attempts: 1})
+	require.Equal(t, []int64{1}, repo.retried)
+	require.Empty(t, repo.finished)
+
+	// 达到最大尝试次数后记为失败
+	svc.executeItem(context.Background(), limited, "sk-test", job, &BatchJobItem{ID: 2, Attempts: 2})
+	require.Len(t, repo.finished, 1)
+	require.Equal(t, BatchItemStatusFailed, repo.finished[0].Status)
+
+	okDispatcher := &batchDispatcherStub{res: &BatchDispatchResult{StatusCode: http.StatusOK, Header: http.Header{"X-Request-Id": []string{"req_9"}}, Body: []byte(`{}`)}}
+	svc.executeItem(context.Background(), okDispatcher, "sk-test", job, &BatchJobItem{ID: 3, Attempts: 1})
+	require.Equal(t, BatchItemStatusCompleted, repo.finished[1].Status)
+	require.Equal(t, "req_9", repo.finished[1].RequestID)
+}
@@ -6,6 +6,7 @@ import (
 
 	"log"
 	"strings"
+	"sync/atomic"
 
 	"github.com/ShaohongDong/sub2api/internal/config"
 )
@@ -81,11 +82,18 @@ type CostBreakdown struct {
 	ActualCost        float64 // 应用倍率后的实际费用
 }
 
+// PricingOverrideSource 提供管理后台维护的模型价格表（由 SettingService 实现）
+type PricingOverrideSource interface {
+	GetPricingOverrides(ctx context.Context) []ModelPriceOverride
+}
+
 // BillingService 计费服务
 type BillingService struct {
-	cfg            *config.Config
-	pricingService *PricingService
-	fallbackPrices map[string]*ModelPricing // 硬编码回退价格
+	cfg             *config.Config
+	pricingService  *PricingService
+	fallbackPrices  map[string]*ModelPricing             // 硬编码回退价格
+	configOverrides atomic.Pointer[[]ModelPriceOverride] // 配置文件 pricing.overrides，支持热更新
+	overrideSource  PricingOverrideSource                // 管理后台价格表（可选）
 }
 
 // NewBillingService 创建计费服务实例
@@ -95,6 +103,7 @@ func NewBillingService(cfg *config.Config, pricingService *PricingService) *Bill
 		pricingService: pricingService,
 		fallbackPrices: make(map[string]*ModelPricing),
 	}
+	s.ReloadConfigPriceOverrides(cfg)
 
 	// 初始化硬编码回退价格（当动态价格不可用时使用）
 	s.initFallbackPricing()
@@ -253,11 +262,47 @@ func (s *BillingService) getFallbackPricing(model string) *ModelPricing {
 	return nil
 }
 
+// SetPricingOverrideSource 设置管理后台价格表来源
+func (s *BillingService) SetPricingOverrideSource(source PricingOverrideSource) {
+	s.overrideSource = source
+}
+
 // GetModelPricing 获取模型价格配置
+// 优先级：管理后台价格表 > 配置文件 pricing.overrides > 动态价格 > 硬编码回退价格。
+// 覆盖价格仅替换已设置的字段，因此也可为动态价格中不存在的模型定价。
 func (s *BillingService) GetModelPricing(model string) (*ModelPricing, error) {
 	// 标准化模型名称（转小写）
 	model = strings.ToLower(model)
 
+	pricing, err := s.getBaseModelPricing(model)
+	if override := s.lookupPriceOverride(model); override != nil {
+		return applyPriceOverride(pricing, override), nil
+	}
+	return pricing, err
+}
+
+// lookupPriceOverride 依次在管理后台价格表与配置文件价格表中查找覆盖价格
+func (s *BillingService) lookupPriceOverride(model string) *ModelPriceOverride {
+	if s.overrideSource != nil {
+		if override := lookupPriceOverride(s.overrideSource.GetPricingOverrides(context.Background()), model); override != nil {
+			return override
+		}
+	}
+	if overrides := s.configOverrides.Load(); overrides != nil {
+		return lookupPriceOverride(*overrides, model)
+	}
+	return nil
+}
+
+// ReloadConfigPriceOverrides 替换配置文件价格表（配置重载时调用），进行中的请求不受影响
+func (s *BillingService) ReloadConfigPriceOverrides(cfg *config.Config) {
+	overrides := pricingOverridesFromConfig(cfg)
+	s.configOverrides.Store(&overrides)
+}
+
+// getBaseModelPricing 从动态价格与硬编码回退价格中获取模型价格
+func (s *BillingService) getBaseModelPricing(model string) (*ModelPricing, error) {
+
 	// 1. 优先从动态价格服务获取
 	if s.pricingService != nil {
 		litellmPricing := s.pricingService.GetModelPricing(model)
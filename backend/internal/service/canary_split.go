@@ -0,0 +1,304 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"math"
+	"math/rand/v2"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"golang.org/x/sync/singleflight"
+)
+
+// maxCanarySplits 金丝雀分流的最大条数
+const maxCanarySplits = 50
+
+// MatchCanarySplit 返回首条匹配请求分组与模型的分流；未启用或无命中时返回 nil
+func MatchCanarySplit(settings *CanarySettings, groupID int64, model string) *CanarySplit {
+	if settings == nil || !settings.Enabled {
+		return nil
+	}
+	for i := range settings.Splits {
+		split := &settings.Splits[i]
+		if !split.Enabled || !routingModelMatches(split.Models, model) {
+			continue
+		}
+		if len(split.SourceGroupIDs) > 0 && !containsInt64(split.SourceGroupIDs, groupID) {
+			continue
+		}
+		return split
+	}
+	return nil
+}
+
+// RollPercent 按百分比随机决定本次请求是否命中（金丝雀分流与影子流量共用）
+func RollPercent(percent float64) bool {
+	if percent <= 0 {
+		return false
+	}
+	return percent >= 100 || rand.Float64()*100 < percent
+}
+
+// normalizeCanarySettings 校验并规范化分流配置
+func normalizeCanarySettings(settings *CanarySettings) error {
+	if len(settings.Splits) > maxCanarySplits {
+		return fmt.Errorf("at most %d canary splits are allowed", maxCanarySplits)
+	}
+	names := make(map[string]struct{}, len(settings.Splits))
+	for i := range settings.Splits {
+		split := &settings.Splits[i]
+		split.Name = strings.TrimSpace(split.Name)
+		if split.Name == "" {
+			return fmt.Errorf("split[%d]: name cannot be empty", i)
+		}
+		if _, ok := names[split.Name]; ok {
+			return fmt.Errorf("split[%d]: duplicate name %q", i, split.Name)
+		}
+		names[split.Name] = struct{}{}
+		models, err := normalizeAPIKeyAllowedModels(split.Models)
+		if err != nil {
+			return fmt.Errorf("split[%d]: invalid models (only a trailing * wildcard is supported)", i)
+		}
+		if len(models) == 0 {
+			return fmt.Errorf("split[%d]: models cannot be empty", i)
+		}
+		split.Models = models
+		if math.IsNaN(split.Percent) || split.Percent < 0 || split.Percent > 100 {
+			return fmt.Errorf("split[%d]: percent must be between 0 and 100", i)
+		}
+		sourceIDs := make([]int64, 0, len(split.SourceGroupIDs))
+		for _, id := range split.SourceGroupIDs {
+			if id <= 0 {
+				return fmt.Errorf("split[%d]: invalid source group id %d", i, id)
+			}
+			if !containsInt64(sourceIDs, id) {
+				sourceIDs = append(sourceIDs, id)
+			}
+		}
+		sort.Slice(sourceIDs, func(a, b int) bool { return sourceIDs[a] < sourceIDs[b] })
+		split.SourceGroupIDs = sourceIDs
+		split.TargetModel = strings.TrimSpace(split.TargetModel)
+		if split.TargetGroupID < 0 {
+			return fmt.Errorf("split[%d]: invalid target_group_id", i)
+		}
+		if split.TargetGroupID == 0 && split.TargetModel == "" {
+			return fmt.Errorf("split[%d]: target_group_id or target_model is required", i)
+		}
+	}
+	if settings.Splits == nil {
+		settings.Splits = []CanarySplit{}
+	}
+	return nil
+}
+
+// cachedCanarySettings 分流配置进程内缓存
+type cachedCanarySettings struct {
+	settings  *CanarySettings
+	expiresAt int64 // unix nano
+}
+
+var canaryCache atomic.Value // *cachedCanarySettings
+
+var canarySF singleflight.Group
+
+// canaryCacheTTL 缓存有效期
+const canaryCacheTTL = 60 * time.Second
+
+// GetCanarySettings 获取金丝雀分流配置
+func (s *SettingService) GetCanarySettings(ctx context.Context) (*CanarySettings, error) {
+	value, err := s.settingRepo.GetValue(ctx, SettingKeyCanarySettings)
+	if err != nil {
+		if errors.Is(err, ErrSettingNotFound) {
+			return DefaultCanarySettings(), nil
+		}
+		return nil, fmt.Errorf("get canary settings: %w", err)
+	}
+	if value == "" {
+		return DefaultCanarySettings(), nil
+	}
+
+	var settings CanarySettings
+	if err := json.Unmarshal([]byte(value), &settings); err != nil {
+		return DefaultCanarySettings(), nil
+	}
+	if settings.Splits == nil {
+		settings.Splits = []CanarySplit{}
+	}
+	return &settings, nil
+}
+
+// SetCanarySettings 设置金丝雀分流配置，刷新本实例缓存并清零分流指标（其他实例在缓存过期后生效）
+func (s *SettingService) SetCanarySettings(ctx context.Context, settings *CanarySettings) error {
+	if settings == nil {
+		return fmt.Errorf("settings cannot be nil")
+	}
+	if err := normalizeCanarySettings(settings); err != nil {
+		return err
+	}
+
+	data, err := json.Marshal(settings)
+	if err != nil {
+		return fmt.Errorf("marshal canary settings: %w", err)
+	}
+	if err := s.settingRepo.Set(ctx, SettingKeyCanarySettings, string(data)); err != nil {
+		return err
+	}
+	canarySF.Forget("canary")
+	canaryCache.Store(&cachedCanarySettings{
+		settings:  settings,
+		expiresAt: time.Now().Add(canaryCacheTTL).UnixNano(),
+	})
+	defaultCanaryMetrics.reset()
+	return nil
+}
+
+// GetCanarySplits 返回金丝雀分流配置（进程内缓存，60 秒 TTL），供网关热路径使用。
+// 读取失败时返回 nil（fail-open，不分流）。
+func (s *SettingService) GetCanarySplits(ctx context.Context) *CanarySettings {
+	if s == nil {
+		return nil
+	}
+	if cached, ok := canaryCache.Load().(*cachedCanarySettings); ok {
+		if time.Now().UnixNano() < cached.expiresAt {
+			return cached.settings
+		}
+	}
+	result, _, _ := canarySF.Do("canary", func() (any, error) {
+		if cached, ok := canaryCache.Load().(*cachedCanarySettings); ok {
+			if time.Now().UnixNano() < cached.expiresAt {
+				return cached.settings, nil
+			}
+		}
+		dbCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), versionBoundsDBTimeout)
+		defer cancel()
+		settings, err := s.GetCanarySettings(dbCtx)
+		if err != nil {
+			slog.Warn("failed to get canary settings, skipping canary splits", "error", err)
+			canaryCache.Store(&cachedCanarySettings{
+				expiresAt: time.Now().Add(versionBoundsErrorTTL).UnixNano(),
+			})
+			return (*CanarySettings)(nil), nil
+		}
+		canaryCache.Store(&cachedCanarySettings{
+			settings:  settings,
+			expiresAt: time.Now().Add(canaryCacheTTL).UnixNano(),
+		})
+		return settings, nil
+	})
+	settings, _ := result.(*CanarySettings)
+	return settings
+}
+
+// CanaryArmMetrics 分流单侧（control / canary）的进程内累计指标
+type CanaryArmMetrics struct {
+	Requests        uint64  `json:"requests"`
+	Errors          uint64  `json:"errors"` // 响应状态码 >= 400
+	DurationTotalMs float64 `json:"duration_total_ms"`
+	AvgDurationMs   float64 `json:"avg_duration_ms"`
+}
+
+// CanarySplitMetrics 单条分流的指标
+type CanarySplitMetrics struct {
+	Name    string           `json:"name"`
+	Control CanaryArmMetrics `json:"control"`
+	Canary  CanaryArmMetrics `json:"canary"`
+}
+
+// CanaryMetricsSnapshot 金丝雀分流指标快照（本实例自 Since 起累计，保存配置时清零）
+type CanaryMetricsSnapshot struct {
+	Since  time.Time            `json:"since"`
+	Splits []CanarySplitMetrics `json:"splits"`
+}
+
+type canaryArmCounters struct {
+	requests       atomic.Uint64
+	errors         atomic.Uint64
+	durationMicros atomic.Uint64
+}
+
+func (a *canaryArmCounters) snapshot() CanaryArmMetrics {
+	requests := a.requests.Load()
+	out := CanaryArmMetrics{
+		Requests:        requests,
+		Errors:          a.errors.Load(),
+		DurationTotalMs: float64(a.durationMicros.Load()) / 1000.0,
+	}
+	if requests > 0 {
+		out.AvgDurationMs = out.DurationTotalMs / float64(requests)
+	}
+	return out
+}
+
+type canaryMetricsStore struct {
+	mu     sync.RWMutex
+	since  time.Time
+	splits map[string]*[2]canaryArmCounters // [0] control, [1] canary
+}
+
+var defaultCanaryMetrics = &canaryMetricsStore{since: time.Now(), splits: map[string]*[2]canaryArmCounters{}}
+
+func (m *canaryMetricsStore) arms(name string) *[2]canaryArmCounters {
+	m.mu.RLock()
+	arms, ok := m.splits[name]
+	m.mu.RUnlock()
+	if ok {
+		return arms
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if arms, ok = m.splits[name]; !ok {
+		arms = &[2]canaryArmCounters{}
+		m.splits[name] = arms
+	}
+	return arms
+}
+
+func (m *canaryMetricsStore) reset() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.since = time.Now()
+	m.splits = map[string]*[2]canaryArmCounters{}
+}
+
+// RecordCanaryResult 记录一次分流请求的结果
+func RecordCanaryResult(split string, canary bool, status int, duration time.Duration) {
+	idx := 0
+	if canary {
+		idx = 1
+	}
+	if duration < 0 {
+		duration = 0
+	}
+	arm := &defaultCanaryMetrics.arms(split)[idx]
+	arm.requests.Add(1)
+	if status >= 400 {
+		arm.errors.Add(1)
+	}
+	arm.durationMicros.Add(uint64(duration.Microseconds()))
+}
+
+// GetCanaryMetricsSnapshot 返回当前分流指标快照（按分流名称排序）
+func GetCanaryMetricsSnapshot() CanaryMetricsSnapshot {
+	defaultCanaryMetrics.mu.RLock()
+	defer defaultCanaryMetrics.mu.RUnlock()
+	out := CanaryMetricsSnapshot{
+		Since:  defaultCanaryMetrics.since,
+		Splits: make([]CanarySplitMetrics, 0, len(defaultCanaryMetrics.splits)),
+	}
+	for name, arms := range defaultCanaryMetrics.splits {
+		out.Splits = append(out.Splits, CanarySplitMetrics{
+			Name:    name,
+			Control: arms[0].snapshot(),
+			Canary:  arms[1].snapshot(),
+		})
+	}
+	sort.Slice(out.Splits, func(a, b int) bool { return out.Splits[a].Name < out.Splits[b].Name })
+	return out
+}
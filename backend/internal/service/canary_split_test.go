@@ -0,0 +1,85 @@
+//go:build unit
+
+package service
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/ShaohongDong/sub2api/internal/config"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSetCanarySettings_Validates(t *testing.T) {
+	svc := NewSettingService(newRuntimeSettingRepoStub(), &config.Config{})
+	ctx := context.Background()
+	t.Cleanup(func() { canaryCache.Store(&cachedCanarySettings{}) })
+
+	invalid := []CanarySplit{
+		{Name: "", Models: []string{"claude-*"}, Percent: 5, TargetGroupID: 2},
+		{Name: "no models", Percent: 5, TargetGroupID: 2},
+		{Name: "bad model", Models: []string{"*-opus"}, Percent: 5, TargetGroupID: 2},
+		{Name: "bad percent", Models: []string{"claude-*"}, Percent: 101, TargetGroupID: 2},
+		{Name: "no target", Models: []string{"claude-*"}, Percent: 5},
+		{Name: "bad source", Models: []string{"claude-*"}, Percent: 5, TargetGroupID: 2, SourceGroupIDs: []int64{0}},
+	}
+	for _, split := range invalid {
+		err := svc.SetCanarySettings(ctx, &CanarySettings{Enabled: true, Splits: []CanarySplit{split}})
+		require.Error(t, err, split.Name)
+	}
+	err := svc.SetCanarySettings(ctx, &CanarySettings{Enabled: true, Splits: []CanarySplit{
+		{Name: "a", Models: []string{"claude-*"}, Percent: 5, TargetModel: "claude-next"},
+		{Name: " a ", Models: []string{"gpt-*"}, Percent: 5, TargetModel: "gpt-next"},
+	}})
+	require.ErrorContains(t, err, "duplicate name")
+
+	err = svc.SetCanarySettings(ctx, &CanarySettings{Enabled: true, Splits: []CanarySplit{
+		{Name: " sonnet ", Enabled: true, Models: []string{" claude-sonnet-* "}, Percent: 5, TargetGroupID: 2, SourceGroupIDs: []int64{3, 1, 3}},
+	}})
+	require.NoError(t, err)
+	got, err := svc.GetCanarySettings(ctx)
+	require.NoError(t, err)
+	require.Equal(t, "sonnet", got.Splits[0].Name)
+	require.Equal(t, []string{"claude-sonnet-*"}, got.Splits[0].Models)
+	require.Equal(t, []int64{1, 3}, got.Splits[0].SourceGroupIDs)
+	require.Same(t, svc.GetCanarySplits(ctx), svc.GetCanarySplits(ctx))
+}
+
+func TestMatchCanarySplit(t *testing.T) {
+	settings := &CanarySettings{Enabled: true, Splits: []CanarySplit{
+		{Name: "disabled", Models: []string{"claude-*"}, Percent: 100, TargetModel: "x"},
+		{Name: "group scoped", Enabled: true, Models: []string{"claude-*"}, SourceGroupIDs: []int64{7}, Percent: 10, TargetGroupID: 8},
+		{Name: "all groups", Enabled: true, Models: []string{"claude-sonnet-*"}, Percent: 10, TargetModel: "claude-sonnet-next"},
+	}}
+
+	require.Equal(t, "group scoped", MatchCanarySplit(settings, 7, "claude-opus-4").Name)
+	require.Equal(t, "all groups", MatchCanarySplit(settings, 1, "claude-sonnet-4-5").Name)
+	require.Nil(t, MatchCanarySplit(settings, 1, "claude-opus-4"))
+	require.Nil(t, MatchCanarySplit(settings, 1, ""))
+	settings.Enabled = false
+	require.Nil(t, MatchCanarySplit(settings, 7, "claude-opus-4"))
+
+	require.False(t, RollPercent(0))
+	require.True(t, RollPercent(100))
+}
+
+func TestCanaryMetrics(t *testing.T) {
+	defaultCanaryMetrics.reset()
+	t.Cleanup(defaultCanaryMetrics.reset)
+
+	RecordCanaryResult("sonnet", false, http.StatusOK, 100*time.Millisecond)
+	RecordCanaryResult("sonnet", false, http.StatusOK, 300*time.Millisecond)
+	RecordCanaryResult("sonnet", true, http.StatusBadGateway, 50*time.Millisecond)
+
+	snapshot := GetCanaryMetricsSnapshot()
+	require.Len(t, snapshot.Splits, 1)
+	split := snapshot.Splits[0]
+	require.Equal(t, "sonnet", split.Name)
+	require.Equal(t, CanaryArmMetrics{Requests: 2, DurationTotalMs: 400, AvgDurationMs: 200}, split.Control)
+	require.Equal(t, CanaryArmMetrics{Requests: 1, Errors: 1, DurationTotalMs: 50, AvgDurationMs: 50}, split.Canary)
+
+	defaultCanaryMetrics.reset()
+	require.Empty(t, GetCanaryMetricsSnapshot().Splits)
+}
@@ -0,0 +1,259 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+
+	"github.com/ShaohongDong/sub2api/internal/config"
+	"github.com/tidwall/gjson"
+	"github.com/tidwall/sjson"
+)
+
+// chatMultiChoiceEndpoint n > 1 子请求重放的入口
+const chatMultiChoiceEndpoint = "/v1/chat/completions"
+
+// ChatMultiChoiceService 模拟 Chat Completions 的 n > 1：上游（Responses / Messages）每次只返回一个结果，
+// 因此并行发起 n 个 n=1 的子请求，再将各自的 choice 合并为一个多 choice 响应。
+// 子请求经由网关路由在进程内执行，账号调度、账号并发上限与计费均与普通请求一致。
+type ChatMultiChoiceService struct {
+	cfg        config.GatewayChatMultiChoiceConfig
+	dispatcher BatchRequestDispatcher
+}
+
+// NewChatMultiChoiceService 创建 n > 1 模拟服务
+func NewChatMultiChoiceService(cfg *config.Config) *ChatMultiChoiceService {
+	s := &ChatMultiChoiceService{}
+	if cfg != nil {
+		s.cfg = cfg.Gateway.ChatMultiChoice
+	}
+	return s
+}
+
+// SetDispatcher 注入进程内请求分发器（路由构建完成后由 server 层设置）
+func (s *ChatMultiChoiceService) SetDispatcher(d BatchRequestDispatcher) {
+	s.dispatcher = d
+}
+
+// Enabled 是否启用且已注入分发器
+func (s *ChatMultiChoiceService) Enabled() bool {
+	return s != nil && s.cfg.Enabled && s.dispatcher != nil
+}
+
+// MaxN 允许的最大 n
+func (s *ChatMultiChoiceService) MaxN() int {
+	return s.cfg.MaxN
+}
+
+// Complete 以 apiKey 并行发起 n 个子请求并合并结果。
+// 任一子请求失败时取消其余子请求，并原样返回最先失败的响应（状态码与错误体）；
+// 全部成功时返回合并后的 chat.completion（非流式 JSON）。
+func (s *ChatMultiChoiceService) Complete(ctx context.Context, apiKey, clientIP string, body []byte, n int) (*BatchDispatchResult, error) {
+	if !s.Enabled() {
+		return nil, fmt.Errorf("chat multi choice: not enabled")
+	}
+	if n < 2 {
+		return nil, fmt.Errorf("chat multi choice: n must be greater than 1")
+	}
+	bodies := make([][]byte, n)
+	for i := range bodies {
+		sub, err := chatMultiChoiceSubRequest(body, i)
+		if err != nil {
+			return nil, err
+		}
+		bodies[i] = sub
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	parallel := s.cfg.MaxParallel
+	if parallel <= 0 || parallel > n {
+		parallel = n
+	}
+	sem := make(chan struct{}, parallel)
+	results := make([]*BatchDispatchResult, n)
+	var (
+		wg       sync.WaitGroup
+		mu       sync.Mutex
+		failed   *BatchDispatchResult
+		firstErr error
+	)
+	for i := range bodies {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			select {
+			case sem <- struct{}{}:
+			case <-ctx.Done():
+				return
+			}
+			defer func() { <-sem }()
+			if ctx.Err() != nil {
+				return
+			}
+			res, err := s.dispatcher.Dispatch(ctx, apiKey, clientIP, http.MethodPost, chatMultiChoiceEndpoint, bodies[i])
+			mu.Lock()
+			defer mu.Unlock()
+			switch {
+			case err != nil:
+				if firstErr == nil && failed == nil {
+					firstErr = err
+				}
+				cancel()
+			case res.StatusCode < 200 || res.StatusCode >= 300:
+				if firstErr == nil && failed == nil {
+					failed = res
+				}
+				cancel()
+			default:
+				results[i] = res
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	if failed != nil {
+		return failed, nil
+	}
+	if firstErr != nil {
+		return nil, firstErr
+	}
+	responses := make([][]byte, n)
+	for i, res := range results {
+		if res == nil {
+			return nil, fmt.Errorf("chat multi choice: request %d did not complete", i)
+		}
+		responses[i] = res.Body
+	}
+	merged, err := mergeChatMultiChoice(responses)
+	if err != nil {
+		return nil, err
+	}
+	header := make(http.Header)
+	header.Set("Content-Type", "application/json")
+	return &BatchDispatchResult{StatusCode: http.StatusOK, Header: header, Body: merged}, nil
+}
+
+// chatMultiChoiceSubRequest 构造第 i 个子请求：n=1、非流式；带 seed 时按序号偏移，避免 n 个结果完全相同
+func chatMultiChoiceSubRequest(body []byte, i int) ([]byte, error) {
+	sub, err := sjson.DeleteBytes(body, "n")
+	if err != nil {
+		return nil, err
+	}
+	if sub, err = sjson.SetBytes(sub, "stream", false); err != nil {
+		return nil, err
+	}
+	if gjson.GetBytes(sub, "stream_options").Exists() {
+		if sub, err = sjson.DeleteBytes(sub, "stream_options"); err != nil {
+			return nil, err
+		}
+	}
+	if seed := gjson.GetBytes(sub, "seed"); seed.Type == gjson.Number && i > 0 {
+		if sub, err = sjson.SetBytes(sub, "seed", seed.Int()+int64(i)); err != nil {
+			return nil, err
+		}
+	}
+	return sub, nil
+}
+
+// mergeChatMultiChoice 以第一个响应为模板，将各响应的首个 choice 按序号合并为 choices；
+// usage 为各子请求之和（与实际计费一致）
+func mergeChatMultiChoice(responses [][]byte) ([]byte, error) {
+	if len(responses) == 0 || !gjson.ValidBytes(responses[0]) {
+		return nil, fmt.Errorf("chat multi choice: invalid response")
+	}
+	choices := make([]json.RawMessage, 0, len(responses))
+	usage := map[string]int64{}
+	for i, resp := range responses {
+		choice := gjson.GetBytes(resp, "choices.0")
+		if !choice.IsObject() {
+			return nil, fmt.Errorf("chat multi choice: response %d has no choice", i)
+		}
+		raw, err := sjson.SetBytes([]byte(choice.Raw), "index", i)
+		if err != nil {
+			return nil, err
+		}
+		choices = append(choices, raw)
+		for _, field := range []string{"prompt_tokens", "completion_tokens", "total_tokens"} {
+			usage[field] += gjson.GetBytes(resp, "usage."+field).Int()
+		}
+	}
+	out, err := sjson.SetBytes(responses[0], "choices", choices)
+	if err != nil {
+		return nil, err
+	}
+	if gjson.GetBytes(out, "usage").IsObject() {
+		for field, value := range usage {
+			if out, err = sjson.SetBytes(out, "usage."+field, value); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return out, nil
+}
+
+// ChatMultiChoiceSSE 将合并后的 chat.completion 转为 chat.completion.chunk 事件流：
+// 每个 choice 依次输出角色与内容、tool_calls 以及 finish_reason；includeUsage 时追加 usage 事件
+func ChatMultiChoiceSSE(merged []byte, includeUsage bool) []byte {
+	base := `{}`
+	for _, field := range []string{"id", "created", "model", "system_fingerprint"} {
+		if v := gjson.GetBytes(merged, field); v.Exists() {
+			base, _ = sjson.SetRaw(base, field, v.Raw)
+		}
+	}
+	base, _ = sjson.Set(base, "object", "chat.completion.chunk")
+
+	var sb strings.Builder
+	writeChunk := func(chunk string) {
+		sb.WriteString("data: ")
+		sb.WriteString(chunk)
+		sb.WriteString("\n\n")
+	}
+	gjson.GetBytes(merged, "choices").ForEach(func(_, choice gjson.Result) bool {
+		index := choice.Get("index").Int()
+		message := choice.Get("message")
+		delta := `{}`
+		delta, _ = sjson.Set(delta, "role", "assistant")
+		for _, field := range []string{"content", "refusal", "reasoning_content", "tool_calls"} {
+			if v := message.Get(field); v.Exists() && v.Type != gjson.Null {
+				raw := v.Raw
+				if field == "tool_calls" {
+					raw = chatMultiChoiceIndexedToolCalls(v)
+				}
+				delta, _ = sjson.SetRaw(delta, field, raw)
+			}
+		}
+		chunk, _ := sjson.Set(base, "choices.0.index", index)
+		chunk, _ = sjson.SetRaw(chunk, "choices.0.delta", delta)
+		if lp := choice.Get("logprobs"); lp.Exists() {
+			chunk, _ = sjson.SetRaw(chunk, "choices.0.logprobs", lp.Raw)
+		}
+		chunk, _ = sjson.SetRaw(chunk, "choices.0.finish_reason", "null")
+		writeChunk(chunk)
+
+		finish, _ := sjson.Set(base, "choices.0.index", index)
+		finish, _ = sjson.SetRaw(finish, "choices.0.delta", `{}`)
+		finish, _ = sjson.Set(finish, "choices.0.finish_reason", choice.Get("finish_reason").String())
+		writeChunk(finish)
+		return true
+	})
+	if usage := gjson.GetBytes(merged, "usage"); includeUsage && usage.IsObject() {
+		chunk, _ := sjson.SetRaw(base, "choices", `[]`)
+		chunk, _ = sjson.SetRaw(chunk, "usage", usage.Raw)
+		writeChunk(chunk)
+	}
+	sb.WriteString("data: [DONE]\n\n")
+	return []byte(sb.String())
+}
+
+// chatMultiChoiceIndexedToolCalls 流式 tool_calls 需要 index 字段
+func chatMultiChoiceIndexedToolCalls(calls gjson.Result) string {
+	out := calls.Raw
+	for i := range calls.Array() {
+		out, _ = sjson.Set(out, fmt.Sprintf("%d.index", i), i)
+	}
+	return out
+}
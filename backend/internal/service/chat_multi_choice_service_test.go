@@ -0,0 +1,136 @@
+//go:build unit
+
+package service
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/ShaohongDong/sub2api/internal/config"
+	"github.com/stretchr/testify/require"
+	"github.com/tidwall/gjson"
+)
+
+type chatMultiChoiceDispatcherStub struct {
+	mu       sync.Mutex
+	bodies   [][]byte
+	inflight atomic.Int32
+	peak     atomic.Int32
+	respond  func(body []byte) *BatchDispatchResult
+}
+
+func (d *chatMultiChoiceDispatcherStub) Dispatch(ctx context.Context, _, _, _, url string, body []byte) (*BatchDispatchResult, error) {
+	cur := d.inflight.Add(1)
+	defer d.inflight.Add(-1)
+	for {
+		peak := d.peak.Load()
+		if cur <= peak || d.peak.CompareAndSwap(peak, cur) {
+			break
+		}
+	}
+	time.Sleep(10 * time.Millisecond)
+	d.mu.Lock()
+	d.bodies = append(d.bodies, body)
+	d.mu.Unlock()
+	if url != chatMultiChoiceEndpoint {
+		return &BatchDispatchResult{StatusCode: http.StatusNotFound}, nil
+	}
+	return d.respond(body), nil
+}
+
+func newTestChatMultiChoiceService(d BatchRequestDispatcher, maxParallel int) *ChatMultiChoiceService {
+	cfg := &config.Config{}
+	cfg.Gateway.ChatMultiChoice = config.GatewayChatMultiChoiceConfig{Enabled: true, MaxN: 8, MaxParallel: maxParallel}
+	svc := NewChatMultiChoiceService(cfg)
+	svc.SetDispatcher(d)
+	return svc
+}
+
+func chatMultiChoiceTestResponse(body []byte) *BatchDispatchResult {
+	text := "seed-" + gjson.GetBytes(body, "seed").String()
+	return &BatchDispatchResult{
+		StatusCode: http.StatusOK,
+		Body: []byte(`{"id":"chatcmpl-1","object":"chat.completion","created":1,"model":"gpt-5",` +
+			`"choices":[{"index":0,"message":{"role":"assistant","content":"` + text + `"},"finish_reason":"stop"}],` +
+			`"usage":{"prompt_tokens":10,"completion_tokens":3,"total_tokens":13}}`),
+	}
+}
+
+func TestChatMultiChoiceService_CompleteMergesChoices(t *testing.T) {
+	d := &chatMultiChoiceDispatcherStub{respond: chatMultiChoiceTestResponse}
+	svc := newTestChatMultiChoiceService(d, 2)
+
+	body := []byte(`{"model":"gpt-5","n":4,"seed":7,"stream":true,"stream_options":{"include_usage":true},"messages":[{"role":"user","content":"hi"}]}`)
+	res, err := svc.Complete(context.Background(), "sk-user", "", body, 4)
+	require.NoError(t, err)
+	require.Equal(t, http.StatusOK, res.StatusCode)
+	require.LessOrEqual(t, d.peak.Load(), int32(2))
+
+	require.Len(t, d.bodies, 4)
+	for _, sub := range d.bodies {
+		require.False(t, gjson.GetBytes(sub, "n").Exists())
+		require.False(t, gjson.GetBytes(sub, "stream").Bool())
+		require.False(t, gjson.GetBytes(sub, "stream_options").Exists())
+	}
+
+	choices := gjson.GetBytes(res.Body, "choices").Array()
+	require.Len(t, choices, 4)
+	seen := map[string]bool{}
+	for i, choice := range choices {
+		require.Equal(t, int64(i), choice.Get("index").Int())
+		seen[choice.Get("message.content").String()] = true
+	}
+	require.Len(t, seen, 4, "seed is offset per sub-request")
+	require.True(t, seen["seed-7"])
+	require.Equal(t, int64(40), gjson.GetBytes(res.Body, "usage.prompt_tokens").Int())
+	require.Equal(t, int64(12), gjson.GetBytes(res.Body, "usage.completion_tokens").Int())
+}
+
+func TestChatMultiChoiceService_ReturnsFirstFailure(t *testing.T) {
+	var calls atomic.Int32
+	d := &chatMultiChoiceDispatcherStub{respond: func(body []byte) *BatchDispatchResult {
+		if calls.Add(1) == 2 {
+			return &BatchDispatchResult{StatusCode: http.StatusTooManyRequests, Header: http.Header{}, Body: []byte(`{"error":{"message":"slow down"}}`)}
+		}
+		return chatMultiChoiceTestResponse(body)
+	}}
+	svc := newTestChatMultiChoiceService(d, 1)
+
+	res, err := svc.Complete(context.Background(), "sk-user", "", []byte(`{"model":"gpt-5","n":5,"messages":[]}`), 5)
+	require.NoError(t, err)
+	require.Equal(t, http.StatusTooManyRequests, res.StatusCode)
+	require.Contains(t, string(res.Body), "slow down")
+	require.Less(t, len(d.bodies), 5, "remaining sub-requests are canceled")
+}
+
+func TestChatMultiChoiceSSE(t *testing.T) {
+	merged := []byte(`{"id":"chatcmpl-1","object":"chat.completion","created":1,"model":"gpt-5","choices":[` +
+		`{"index":0,"message":{"role":"assistant","content":"a"},"finish_reason":"stop"},` +
+		`{"index":1,"message":{"role":"assistant","content":null,"tool_calls":[{"id":"call_1","type":"function","function":{"name":"f","arguments":"{}"}}]},"finish_reason":"tool_calls"}],` +
+		`"usage":{"prompt_tokens":2,"completion_tokens":2,"total_tokens":4}}`)
+
+	stream := string(ChatMultiChoiceSSE(merged, true))
+	require.True(t, strings.HasSuffix(stream, "data: [DONE]\n\n"))
+	var events []gjson.Result
+	for _, line := range strings.Split(stream, "\n") {
+		if data, ok := strings.CutPrefix(line, "data: "); ok && data != "[DONE]" {
+			events = append(events, gjson.Parse(data))
+		}
+	}
+	require.Len(t, events, 5)
+	require.Equal(t, "chat.completion.chunk", events[0].Get("object").String())
+	require.Equal(t, "a", events[0].Get("choices.0.delta.content").String())
+	require.Equal(t, "stop", events[1].Get("choices.0.finish_reason").String())
+	require.Equal(t, int64(1), events[2].Get("choices.0.index").Int())
+	require.False(t, events[2].Get("choices.0.delta.content").Exists())
+	require.Equal(t, int64(0), events[2].Get("choices.0.delta.tool_calls.0.index").Int())
+	require.Equal(t, "tool_calls", events[3].Get("choices.0.finish_reason").String())
+	require.Equal(t, int64(4), events[4].Get("usage.total_tokens").Int())
+
+	require.NotContains(t, string(ChatMultiChoiceSSE(merged, false)), `"usage"`)
+}
@@ -0,0 +1,101 @@
+package service
+
+import (
+	"context"
+	"strings"
+
+	"github.com/ShaohongDong/sub2api/internal/pkg/clientdetect"
+	"github.com/ShaohongDong/sub2api/internal/pkg/logger"
+	"go.uber.org/zap"
+)
+
+// ClientRoutingMatchAll 客户端路由规则中匹配任意客户端类型的通配符。
+const ClientRoutingMatchAll = "*"
+
+// clientRoutingConstraint 单次请求命中的客户端路由约束。
+// nil 表示不做限制。
+type clientRoutingConstraint struct {
+	clientType   string
+	accountTypes map[string]struct{}
+	strict       bool
+}
+
+// allows 判断账号类型是否满足约束；nil 约束放行所有账号。
+func (c *clientRoutingConstraint) allows(account *Account) bool {
+	if c == nil {
+		return true
+	}
+	if account == nil {
+		return false
+	}
+	_, ok := c.accountTypes[account.Type]
+	return ok
+}
+
+// matchClientRoutingRule 按顺序匹配规则，返回首条命中规则对应的约束。
+func matchClientRoutingRule(settings *ClientRoutingSettings, clientType clientdetect.ClientType) *clientRoutingConstraint {
+	if settings == nil || !settings.Enabled {
+		return nil
+	}
+	ct := string(clientType)
+	for _, rule := range settings.Rules {
+		if !clientRoutingRuleMatches(rule, ct) || len(rule.AccountTypes) == 0 {
+			continue
+		}
+		types := make(map[string]struct{}, len(rule.AccountTypes))
+		for _, at := range rule.AccountTypes {
+			types[at] = struct{}{}
+		}
+		return &clientRoutingConstraint{
+			clientType:   ct,
+			accountTypes: types,
+			strict:       rule.Strict,
+		}
+	}
+	return nil
+}
+
+func clientRoutingRuleMatches(rule ClientRoutingRule, clientType string) bool {
+	for _, ct := range rule.ClientTypes {
+		if ct == ClientRoutingMatchAll || ct == clientType {
+			return true
+		}
+	}
+	return false
+}
+
+// normalizeClientRoutingValues 去除空白、转小写并去重。
+func normalizeClientRoutingValues(values []string) []string {
+	if len(values) == 0 {
+		return nil
+	}
+	seen := make(map[string]struct{}, len(values))
+	out := make([]string, 0, len(values))
+	for _, v := range values {
+		v = strings.ToLower(strings.TrimSpace(v))
+		if v == "" {
+			continue
+		}
+		if _, ok := seen[v]; ok {
+			continue
+		}
+		seen[v] = struct{}{}
+		out = append(out, v)
+	}
+	return out
+}
+
+// resolveClientRoutingConstraint 根据 context 中的客户端信息与路由配置计算约束。
+// 读取配置失败时 fail-open（不做限制）。
+func (s *OpenAIGatewayService) resolveClientRoutingConstraint(ctx context.Context) *clientRoutingConstraint {
+	if s == nil || s.settingService == nil {
+		return nil
+	}
+	settings, err := s.settingService.GetClientRoutingSettings(ctx)
+	if err != nil {
+		logger.FromContext(ctx).Warn("openai.client_routing_settings_load_failed", zap.Error(err))
+		return nil
+	}
+	info, _ := clientdetect.FromContext(ctx)
+	return matchClientRoutingRule(settings, info.Type)
+}
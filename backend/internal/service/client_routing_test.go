@@ -0,0 +1,140 @@
+package service
+
+import (
+	"context"
+	"testing"
+
+	"github.com/ShaohongDong/sub2api/internal/config"
+	"github.com/ShaohongDong/sub2api/internal/pkg/clientdetect"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSettingService_SetClientRoutingSettings_ValidatesAndNormalizes(t *testing.T) {
+	repo := newRuntimeSettingRepoStub()
+	svc := NewSettingService(repo, &config.Config{})
+	ctx := context.Background()
+
+	got, err := svc.GetClientRoutingSettings(ctx)
+	require.NoError(t, err)
+	require.False(t, got.Enabled)
+	require.Empty(t, got.Rules)
+
+	err = svc.SetClientRoutingSettings(ctx, &ClientRoutingSettings{
+		Enabled: true,
+		Rules:   []ClientRoutingRule{{ClientTypes: []string{"not_a_client"}, AccountTypes: []string{AccountTypeOAuth}}},
+	})
+	require.ErrorContains(t, err, "unknown client type")
+
+	err = svc.SetClientRoutingSettings(ctx, &ClientRoutingSettings{
+		Enabled: true,
+		Rules:   []ClientRoutingRule{{ClientTypes: []string{"codex_cli_rs"}, AccountTypes: []string{"cookie"}}},
+	})
+	require.ErrorContains(t, err, "invalid account type")
+
+	err = svc.SetClientRoutingSettings(ctx, &ClientRoutingSettings{
+		Enabled: true,
+		Rules: []ClientRoutingRule{
+			{ClientTypes: []string{" Codex_CLI_RS ", "codex_cli_rs"}, AccountTypes: []string{"OAuth"}, Strict: true},
+			{ClientTypes: []string{"*"}, AccountTypes: []string{AccountTypeAPIKey}},
+		},
+	})
+	require.NoError(t, err)
+
+	got, err = svc.GetClientRoutingSettings(ctx)
+	require.NoError(t, err)
+	require.True(t, got.Enabled)
+	require.Len(t, got.Rules, 2)
+	require.Equal(t, []string{"codex_cli_rs"}, got.Rules[0].ClientTypes)
+	require.Equal(t, []string{AccountTypeOAuth}, got.Rules[0].AccountTypes)
+	require.True(t, got.Rules[0].Strict)
+}
+
+func TestMatchClientRoutingRule(t *testing.T) {
+	settings := &ClientRoutingSettings{
+		Enabled: true,
+		Rules: []ClientRoutingRule{
+			{ClientTypes: []string{"codex_cli_rs", "codex_vscode"}, AccountTypes: []string{AccountTypeOAuth}},
+			{ClientTypes: []string{ClientRoutingMatchAll}, AccountTypes: []string{AccountTypeAPIKey}, Strict: true},
+		},
+	}
+
+	codex := matchClientRoutingRule(settings, clientdetect.TypeCodexVSCode)
+	require.NotNil(t, codex)
+	require.True(t, codex.allows(&Account{Type: AccountTypeOAuth}))
+	require.False(t, codex.allows(&Account{Type: AccountTypeAPIKey}))
+	require.False(t, codex.strict)
+
+	generic := matchClientRoutingRule(settings, clientdetect.TypeCurl)
+	require.NotNil(t, generic)
+	require.True(t, generic.allows(&Account{Type: AccountTypeAPIKey}))
+	require.True(t, generic.strict)
+
+	settings.Enabled = false
+	require.Nil(t, matchClientRoutingRule(settings, clientdetect.TypeCodexCLI))
+	var none *clientRoutingConstraint
+	require.True(t, none.allows(&Account{Type: AccountTypeUpstream}))
+}
+
+func TestOpenAIGatewayService_SelectAccountWithScheduler_ClientRouting(t *testing.T) {
+	groupID := int64(10301)
+	oauth := &Account{ID: 33001, Platform: PlatformOpenAI, Type: AccountTypeOAuth, Status: StatusActive, Schedulable: true, Concurrency: 1, Priority: 5}
+	apiKey := &Account{ID: 33002, Platform: PlatformOpenAI, Type: AccountTypeAPIKey, Status: StatusActive, Schedulable: true, Concurrency: 1, Priority: 0}
+
+	newSvc := func(t *testing.T, settings *ClientRoutingSettings, accounts ...*Account) *OpenAIGatewayService {
+		t.Helper()
+		settingService := NewSettingService(newRuntimeSettingRepoStub(), &config.Config{})
+		require.NoError(t, settingService.SetClientRoutingSettings(context.Background(), settings))
+		byID := make(map[int64]*Account, len(accounts))
+		repoAccounts := make([]Account, 0, len(accounts))
+		for _, a := range accounts {
+			byID[a.ID] = a
+			repoAccounts = append(repoAccounts, *a)
+		}
+		snapshotService := &SchedulerSnapshotService{cache: &openAISnapshotCacheStub{snapshotAccounts: accounts, accountsByID: byID}}
+		return &OpenAIGatewayService{
+			accountRepo:        stubOpenAIAccountRepo{accounts: repoAccounts},
+			cfg:                &config.Config{},
+			schedulerSnapshot:  snapshotService,
+			concurrencyService: NewConcurrencyService(stubConcurrencyCache{}),
+			settingService:     settingService,
+		}
+	}
+	codexCtx := clientdetect.IntoContext(context.Background(), clientdetect.ClientInfo{Type: clientdetect.TypeCodexCLI})
+	curlCtx := clientdetect.IntoContext(context.Background(), clientdetect.ClientInfo{Type: clientdetect.TypeCurl})
+	rules := func(strict bool) *ClientRoutingSettings {
+		return &ClientRoutingSettings{
+			Enabled: true,
+			Rules: []ClientRoutingRule{
+				{ClientTypes: []string{string(clientdetect.TypeCodexCLI)}, AccountTypes: []string{AccountTypeOAuth}, Strict: strict},
+				{ClientTypes: []string{ClientRoutingMatchAll}, AccountTypes: []string{AccountTypeAPIKey}, Strict: strict},
+			},
+		}
+	}
+
+	t.Run("routes by client type", func(t *testing.T) {
+		svc := newSvc(t, rules(false), oauth, apiKey)
+
+		selection, _, err := svc.SelectAccountWithScheduler(codexCtx, &groupID, "", "", "gpt-5.1", nil, OpenAIUpstreamTransportAny)
+		require.NoError(t, err)
+		require.Equal(t, oauth.ID, selection.Account.ID)
+
+		selection, _, err = svc.SelectAccountWithScheduler(curlCtx, &groupID, "", "", "gpt-5.1", nil, OpenAIUpstreamTransportAny)
+		require.NoError(t, err)
+		require.Equal(t, apiKey.ID, selection.Account.ID)
+	})
+
+	t.Run("non-strict falls back when no account matches", func(t *testing.T) {
+		svc := newSvc(t, rules(false), apiKey)
+
+		selection, _, err := svc.SelectAccountWithScheduler(codexCtx, &groupID, "", "", "gpt-5.1", nil, OpenAIUpstreamTransportAny)
+		require.NoError(t, err)
+		require.Equal(t, apiKey.ID, selection.Account.ID)
+	})
+
+	t.Run("strict rejects when no account matches", func(t *testing.T) {
+		svc := newSvc(t, rules(true), apiKey)
+
+		_, _, err := svc.SelectAccountWithScheduler(codexCtx, &groupID, "", "", "gpt-5.1", nil, OpenAIUpstreamTransportAny)
+		require.Error(t, err)
+	})
+}
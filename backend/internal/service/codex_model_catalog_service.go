@@ -0,0 +1,313 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"slices"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/ShaohongDong/sub2api/internal/config"
+	"github.com/ShaohongDong/sub2api/internal/pkg/httpclient"
+)
+
+const (
+	// chatgptCodexModelsURL Codex 后端的模型目录接口，按账号返回当前可用的模型
+	chatgptCodexModelsURL = "https://chatgpt.com/backend-api/codex/models"
+
+	// codexModelsExtraKey 上游模型目录中该账号可用的模型（写入 extra）
+	codexModelsExtraKey = "codex_models"
+	// codexModelsSyncedAtExtraKey 最近一次同步模型目录的时间
+	codexModelsSyncedAtExtraKey = "codex_models_synced_at"
+
+	codexModelsResponseMaxBytes = 1 << 20
+)
+
+// codexDiscoveredModels 已同步到的上游 Codex 模型（小写 -> 上游 slug），进程内共享，
+// 供模型归一化在回退到内置映射前直接放行上游新发布的模型
+var codexDiscoveredModels atomic.Pointer[map[string]string]
+
+// setCodexDiscoveredModels 替换已发现的上游 Codex 模型集合
+func setCodexDiscoveredModels(models []string) {
+	index := make(map[string]string, len(models))
+	for _, model := range models {
+		if model = strings.TrimSpace(model); model != "" {
+			index[strings.ToLower(model)] = model
+		}
+	}
+	codexDiscoveredModels.Store(&index)
+}
+
+// lookupCodexDiscoveredModel 返回与 modelID 一致（不区分大小写）的上游模型，未发现时返回空字符串
+func lookupCodexDiscoveredModel(modelID string) string {
+	index := codexDiscoveredModels.Load()
+	if index == nil || modelID == "" {
+		return ""
+	}
+	return (*index)[strings.ToLower(modelID)]
+}
+
+// CodexModels 返回模型目录同步记录的该账号可用的上游模型
+func (a *Account) CodexModels() []string {
+	if a == nil || a.Extra == nil {
+		return nil
+	}
+	switch raw := a.Extra[codexModelsExtraKey].(type) {
+	case []string:
+		return raw
+	case []any:
+		models := make([]string, 0, len(raw))
+		for _, v := range raw {
+			if s, ok := v.(string); ok && s != "" {
+				models = append(models, s)
+			}
+		}
+		return models
+	}
+	return nil
+}
+
+// CodexModelCatalogService 定时从上游拉取各 OpenAI OAuth 账号可用的 Codex 模型：
+// 结果写入账号 extra（/v1/models 按账号展示），并汇总到进程内的模型归一化表，
+// 使上游新发布的 Codex 模型无需发版即可直接透传。
+type CodexModelCatalogService struct {
+	accountRepo   AccountRepository
+	tokenProvider *OpenAITokenProvider
+	cfg           config.CodexModelSyncConfig
+	leader        *LeaderElectionService
+
+	// fetch 拉取单个账号的模型目录，便于测试替换
+	fetch func(ctx context.Context, account *Account) ([]string, error)
+
+	stopCh   chan struct{}
+	stopOnce sync.Once
+	wg       sync.WaitGroup
+}
+
+// NewCodexModelCatalogService 创建 Codex 模型目录同步服务
+func NewCodexModelCatalogService(
+	accountRepo AccountRepository,
+	tokenProvider *OpenAITokenProvider,
+	cfg *config.Config,
+) *CodexModelCatalogService {
+	s := &CodexModelCatalogService{
+		accountRepo:   accountRepo,
+		tokenProvider: tokenProvider,
+		stopCh:        make(chan struct{}),
+	}
+	if cfg != nil {
+		s.cfg = cfg.CodexModelSync
+	}
+	s.fetch = s.fetchUpstreamModels
+	return s
+}
+
+// SetLeaderElection 设置集群领导者选举（可选依赖）；启用集群模式时只有领导者向上游拉取，
+// 其余实例仅从账号 extra 加载同步结果
+func (s *CodexModelCatalogService) SetLeaderElection(leader *LeaderElectionService) {
+	s.leader = leader
+}
+
+// Start 启动同步循环（启动时立即同步一次）
+func (s *CodexModelCatalogService) Start() {
+	if s == nil || !s.cfg.Enabled {
+		return
+	}
+	interval := time.Duration(s.cfg.IntervalMinutes) * time.Minute
+	if interval <= 0 {
+		interval = 6 * time.Hour
+	}
+	s.wg.Add(1)
+	go func() {
+		defer s.wg.Done()
+		s.runOnce(time.Now())
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				s.runOnce(time.Now())
+			case <-s.stopCh:
+				return
+			}
+		}
+	}()
+	slog.Info("codex_model_sync.service_started", "interval_minutes", int(interval/time.Minute))
+}
+
+// Stop 停止同步循环
+func (s *CodexModelCatalogService) Stop() {
+	if s == nil {
+		return
+	}
+	s.stopOnce.Do(func() {
+		close(s.stopCh)
+	})
+	s.wg.Wait()
+}
+
+func (s *CodexModelCatalogService) runOnce(now time.Time) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Minute)
+	defer cancel()
+
+	accounts, err := s.accountRepo.ListSchedulable(ctx)
+	if err != nil {
+		slog.Warn("codex_model_sync.list_failed", "error", err)
+		return
+	}
+	if s.leader.IsLeader() {
+		s.syncAccounts(ctx, accounts, now)
+	}
+
+	var models []string
+	for i := range accounts {
+		models = append(models, accounts[i].CodexModels()...)
+	}
+	setCodexDiscoveredModels(models)
+}
+
+// syncAccounts 并发拉取各 OpenAI OAuth 账号的模型目录，模型列表变化时写回 extra
+func (s *CodexModelCatalogService) syncAccounts(ctx context.Context, accounts []Account, now time.Time) {
+	workers := s.cfg.MaxConcurrency
+	if workers <= 0 {
+		workers = 1
+	}
+	sem := make(chan struct{}, workers)
+	var wg sync.WaitGroup
+	for i := range accounts {
+		account := &accounts[i]
+		if !account.IsOpenAIOAuth() {
+			continue
+		}
+		sem <- struct{}{}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			defer func() { <-sem }()
+			s.syncAccount(ctx, account, now)
+		}()
+	}
+	wg.Wait()
+}
+
+func (s *CodexModelCatalogService) syncAccount(ctx context.Context, account *Account, now time.Time) {
+	models, err := s.fetch(ctx, account)
+	if err != nil {
+		slog.Warn("codex_model_sync.fetch_failed", "account_id", account.ID, "error", err)
+		return
+	}
+	if len(models) == 0 {
+		return
+	}
+	previous := account.CodexModels()
+	updates := map[string]any{
+		codexModelsExtraKey:         models,
+		codexModelsSyncedAtExtraKey: now.UTC().Format(time.RFC3339),
+	}
+	if err := s.accountRepo.UpdateExtra(ctx, account.ID, updates); err != nil {
+		slog.Warn("codex_model_sync.update_failed", "account_id", account.ID, "error", err)
+		return
+	}
+	mergeAccountExtra(account, updates)
+	if added := newCodexModels(previous, models); len(added) > 0 {
+		slog.Info("codex_model_sync.models_discovered", "account_id", account.ID, "models", added)
+	}
+}
+
+// newCodexModels 返回 current 中相对 previous 新增的模型
+func newCodexModels(previous, current []string) []string {
+	var added []string
+	for _, model := range current {
+		if !slices.Contains(previous, model) {
+			added = append(added, model)
+		}
+	}
+	return added
+}
+
+// codexModelsResponse 上游模型目录响应（仅解析用到的字段）
+type codexModelsResponse struct {
+	Models []struct {
+		Slug       string `json:"slug"`
+		Visibility string `json:"visibility"`
+	} `json:"models"`
+}
+
+// fetchUpstreamModels 以账号身份请求 Codex 模型目录，返回可见模型的 slug（隐藏模型忽略）
+func (s *CodexModelCatalogService) fetchUpstreamModels(ctx context.Context, account *Account) ([]string, error) {
+	if s.tokenProvider == nil {
+		return nil, fmt.Errorf("openai token provider unavailable")
+	}
+	accessToken, err := s.tokenProvider.GetAccessToken(ctx, account)
+	if err != nil {
+		return nil, fmt.Errorf("get access token: %w", err)
+	}
+
+	reqCtx, cancel := context.WithTimeout(ctx, 15*time.Second)
+	defer cancel()
+	endpoint := chatgptCodexModelsURL + "?client_version=" + url.QueryEscape(currentCodexCLIVersion())
+	req, err := http.NewRequestWithContext(reqCtx, http.MethodGet, endpoint, nil)
+	if err != nil {
+		return nil, fmt.Errorf("create codex models request: %w", err)
+	}
+	req.Host = "chatgpt.com"
+	req.Header.Set("Authorization", "Bearer "+accessToken)
+	req.Header.Set("Accept", "application/json")
+	req.Header.Set("Originator", "codex_cli_rs")
+	req.Header.Set("Version", currentCodexCLIVersion())
+	req.Header.Set("User-Agent", currentCodexCLIUserAgent())
+	if chatgptAccountID := account.GetChatGPTAccountID(); chatgptAccountID != "" {
+		req.Header.Set("chatgpt-account-id", chatgptAccountID)
+	}
+
+	proxyURL := ""
+	if account.ProxyID != nil && account.Proxy != nil {
+		proxyURL = account.Proxy.URL()
+	}
+	client, err := httpclient.GetClient(httpclient.Options{
+		ProxyURL:              proxyURL,
+		Timeout:               15 * time.Second,
+		ResponseHeaderTimeout: 10 * time.Second,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("build codex models client: %w", err)
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("codex models request failed: %w", err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, codexModelsResponseMaxBytes))
+	if err != nil {
+		return nil, fmt.Errorf("read codex models response: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("codex models request returned %d", resp.StatusCode)
+	}
+	return parseCodexModelsResponse(body)
+}
+
+// parseCodexModelsResponse 解析上游模型目录，返回去重后的可见模型 slug
+func parseCodexModelsResponse(body []byte) ([]string, error) {
+	var parsed codexModelsResponse
+	if err := json.Unmarshal(body, &parsed); err != nil {
+		return nil, fmt.Errorf("parse codex models response: %w", err)
+	}
+	models := make([]string, 0, len(parsed.Models))
+	for _, m := range parsed.Models {
+		slug := strings.TrimSpace(m.Slug)
+		if slug == "" || strings.EqualFold(m.Visibility, "hide") || slices.Contains(models, slug) {
+			continue
+		}
+		models = append(models, slug)
+	}
+	return models, nil
+}
@@ -0,0 +1,96 @@
+//go:build unit
+
+package service
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ShaohongDong/sub2api/internal/config"
+	"github.com/stretchr/testify/require"
+)
+
+type codexModelCatalogRepoStub struct {
+	mockAccountRepoForGemini
+	schedulable []Account
+
+	mu      sync.Mutex
+	updates map[int64]map[string]any
+}
+
+func (r *codexModelCatalogRepoStub) ListSchedulable(ctx context.Context) ([]Account, error) {
+	return r.schedulable, nil
+}
+
+func (r *codexModelCatalogRepoStub) UpdateExtra(ctx context.Context, id int64, updates map[string]any) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.updates == nil {
+		r.updates = make(map[int64]map[string]any)
+	}
+	r.updates[id] = updates
+	return nil
+}
+
+func TestCodexModelCatalog_SyncsOpenAIOAuthAccounts(t *testing.T) {
+	t.Cleanup(func() { setCodexDiscoveredModels(nil) })
+
+	repo := &codexModelCatalogRepoStub{schedulable: []Account{
+		{ID: 1, Platform: PlatformOpenAI, Type: AccountTypeOAuth},
+		{ID: 2, Platform: PlatformOpenAI, Type: AccountTypeAPIKey},
+		{ID: 3, Platform: PlatformAnthropic, Type: AccountTypeOAuth},
+		{ID: 4, Platform: PlatformOpenAI, Type: AccountTypeOAuth},
+	}}
+	cfg := &config.Config{CodexModelSync: config.CodexModelSyncConfig{Enabled: true, IntervalMinutes: 60, MaxConcurrency: 2}}
+	svc := NewCodexModelCatalogService(repo, nil, cfg)
+
+	var mu sync.Mutex
+	var fetched []int64
+	svc.fetch = func(ctx context.Context, account *Account) ([]string, error) {
+		mu.Lock()
+		fetched = append(fetched, account.ID)
+		mu.Unlock()
+		if account.ID == 4 {
+			return nil, errors.New("boom")
+		}
+		return []string{"gpt-5.1-codex", "GPT-5.9-Codex-Mini"}, nil
+	}
+
+	svc.runOnce(time.Now())
+
+	require.ElementsMatch(t, []int64{1, 4}, fetched)
+	require.Len(t, repo.updates, 1)
+	require.Equal(t, []string{"gpt-5.1-codex", "GPT-5.9-Codex-Mini"}, repo.updates[1][codexModelsExtraKey])
+	require.NotEmpty(t, repo.updates[1][codexModelsSyncedAtExtraKey])
+
+	// 新模型原样放行，不再被模糊匹配降级
+	require.Equal(t, "GPT-5.9-Codex-Mini", normalizeCodexModel("gpt-5.9-codex-mini"))
+	require.Equal(t, "gpt-5.1-codex", normalizeCodexModel("gpt-5.8-codex"))
+}
+
+func TestCodexModelCatalog_DefaultModelIDsIncludeDiscovered(t *testing.T) {
+	acc := &Account{Platform: PlatformOpenAI, Type: AccountTypeOAuth,
+		Credentials: map[string]any{"plan_type": "plus"},
+		Extra:       map[string]any{codexModelsExtraKey: []any{"gpt-5.9-codex", "gpt-5.3-codex-spark"}}}
+
+	ids := defaultModelIDsForAccount(acc)
+	require.Contains(t, ids, "gpt-5.9-codex")
+	require.NotContains(t, ids, "gpt-5.3-codex-spark", "plan-restricted models stay filtered")
+}
+
+func TestParseCodexModelsResponse(t *testing.T) {
+	models, err := parseCodexModelsResponse([]byte(`{"models":[
+		{"slug":"gpt-5.1-codex","visibility":"list"},
+		{"slug":"internal-eval","visibility":"hide"},
+		{"slug":"gpt-5.1-codex"},
+		{"slug":" "}
+	]}`))
+	require.NoError(t, err)
+	require.Equal(t, []string{"gpt-5.1-codex"}, models)
+
+	_, err = parseCodexModelsResponse([]byte(`not json`))
+	require.Error(t, err)
+}
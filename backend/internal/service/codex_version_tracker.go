@@ -0,0 +1,133 @@
+package service
+
+import (
+	"context"
+	"log/slog"
+	"regexp"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/ShaohongDong/sub2api/internal/config"
+)
+
+// codexReleaseVersionPattern Codex CLI 正式发布版本号（tag 形如 rust-v0.105.0，忽略 alpha/beta 等预发布版本）
+var codexReleaseVersionPattern = regexp.MustCompile(`^\d+\.\d+\.\d+$`)
+
+// trackedCodexCLIVersion 版本跟踪器获取到的 Codex CLI 最新发布版本，进程内共享；未获取到时为空
+var trackedCodexCLIVersion atomic.Pointer[string]
+
+// currentCodexCLIVersion 返回网关自身发起请求（用量探测、模型目录同步等）声明的 Codex CLI 版本：
+// 跟踪到的最新版本优先，其次为内置版本
+func currentCodexCLIVersion() string {
+	if v := trackedCodexCLIVersion.Load(); v != nil && *v != "" {
+		return *v
+	}
+	return codexCLIVersion
+}
+
+// currentCodexCLIUserAgent 返回与 currentCodexCLIVersion 对应的 Codex CLI User-Agent
+func currentCodexCLIUserAgent() string {
+	if v := trackedCodexCLIVersion.Load(); v != nil && *v != "" {
+		return "codex_cli_rs/" + *v
+	}
+	return codexCLIUserAgent
+}
+
+// normalizeCodexReleaseVersion 从 release tag 中提取版本号（去掉 rust-v / v 前缀），非正式版本返回空字符串
+func normalizeCodexReleaseVersion(tag string) string {
+	version := strings.TrimSpace(tag)
+	version = strings.TrimPrefix(version, "rust-")
+	version = strings.TrimPrefix(version, "v")
+	if !codexReleaseVersionPattern.MatchString(version) {
+		return ""
+	}
+	return version
+}
+
+// CodexVersionTracker 定期从 GitHub 获取 Codex CLI 最新发布版本，
+// 供发往上游的 User-Agent / version 头使用，避免长期声明过旧的客户端版本。
+type CodexVersionTracker struct {
+	githubClient GitHubReleaseClient
+	cfg          config.GatewayUpstreamUserAgentConfig
+
+	stopCh   chan struct{}
+	stopOnce sync.Once
+	wg       sync.WaitGroup
+}
+
+// NewCodexVersionTracker 创建 Codex CLI 版本跟踪器
+func NewCodexVersionTracker(githubClient GitHubReleaseClient, cfg *config.Config) *CodexVersionTracker {
+	t := &CodexVersionTracker{
+		githubClient: githubClient,
+		stopCh:       make(chan struct{}),
+	}
+	if cfg != nil {
+		t.cfg = cfg.Gateway.UpstreamUserAgent
+	}
+	return t
+}
+
+// Start 启动版本获取循环（启动时立即获取一次）
+func (t *CodexVersionTracker) Start() {
+	if t == nil || !t.cfg.AutoUpdate || t.githubClient == nil {
+		return
+	}
+	interval := time.Duration(t.cfg.AutoUpdateIntervalMinutes) * time.Minute
+	if interval <= 0 {
+		interval = 6 * time.Hour
+	}
+	t.wg.Add(1)
+	go func() {
+		defer t.wg.Done()
+		t.refresh()
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				t.refresh()
+			case <-t.stopCh:
+				return
+			}
+		}
+	}()
+	slog.Info("codex_version_tracker.started", "repo", t.cfg.ReleaseRepo, "interval_minutes", int(interval/time.Minute))
+}
+
+// Stop 停止版本获取循环
+func (t *CodexVersionTracker) Stop() {
+	if t == nil {
+		return
+	}
+	t.stopOnce.Do(func() {
+		close(t.stopCh)
+	})
+	t.wg.Wait()
+}
+
+// refresh 获取最新发布版本，仅在高于当前跟踪版本时更新
+func (t *CodexVersionTracker) refresh() {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	release, err := t.githubClient.FetchLatestRelease(ctx, t.cfg.ReleaseRepo)
+	if err != nil {
+		slog.Warn("codex_version_tracker.fetch_failed", "repo", t.cfg.ReleaseRepo, "error", err)
+		return
+	}
+	if release == nil {
+		return
+	}
+	version := normalizeCodexReleaseVersion(release.TagName)
+	if version == "" {
+		slog.Warn("codex_version_tracker.unrecognized_tag", "repo", t.cfg.ReleaseRepo, "tag", release.TagName)
+		return
+	}
+	if CompareVersions(version, currentCodexCLIVersion()) <= 0 {
+		return
+	}
+	trackedCodexCLIVersion.Store(&version)
+	slog.Info("codex_version_tracker.updated", "version", version)
+}
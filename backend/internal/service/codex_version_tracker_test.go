@@ -0,0 +1,51 @@
+//go:build unit
+
+package service
+
+import (
+	"testing"
+
+	"github.com/ShaohongDong/sub2api/internal/config"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNormalizeCodexReleaseVersion(t *testing.T) {
+	require.Equal(t, "0.105.0", normalizeCodexReleaseVersion("rust-v0.105.0"))
+	require.Equal(t, "0.105.0", normalizeCodexReleaseVersion("v0.105.0"))
+	require.Empty(t, normalizeCodexReleaseVersion("rust-v0.106.0-alpha.2"))
+	require.Empty(t, normalizeCodexReleaseVersion("latest"))
+}
+
+func TestCodexVersionTracker_Refresh(t *testing.T) {
+	t.Cleanup(func() { trackedCodexCLIVersion.Store(nil) })
+
+	client := &githubReleaseClientStub{latestRelease: &GitHubRelease{TagName: "rust-v0.200.0"}}
+	cfg := &config.Config{}
+	cfg.Gateway.UpstreamUserAgent = config.GatewayUpstreamUserAgentConfig{
+		Template:    "codex_cli_rs/{version}",
+		Version:     "0.104.0",
+		AutoUpdate:  true,
+		ReleaseRepo: "openai/codex",
+	}
+	tracker := NewCodexVersionTracker(client, cfg)
+
+	tracker.refresh()
+	require.Equal(t, "openai/codex", client.repoArg)
+	require.Equal(t, "0.200.0", currentCodexCLIVersion())
+	require.Equal(t, "codex_cli_rs/0.200.0", currentCodexCLIUserAgent())
+
+	// 网关请求取配置版本与跟踪版本中较新者
+	svc := &OpenAIGatewayService{cfg: cfg}
+	require.Equal(t, "0.200.0", svc.upstreamCodexVersion())
+	require.Equal(t, "codex_cli_rs/0.200.0", svc.upstreamCodexUserAgent(""))
+	cfg.Gateway.UpstreamUserAgent.Version = "0.300.0"
+	require.Equal(t, "0.300.0", svc.upstreamCodexVersion())
+
+	// 不会回退到更旧的版本，也忽略预发布版本
+	client.latestRelease = &GitHubRelease{TagName: "rust-v0.150.0"}
+	tracker.refresh()
+	require.Equal(t, "0.200.0", currentCodexCLIVersion())
+	client.latestRelease = &GitHubRelease{TagName: "rust-v0.300.0-alpha.1"}
+	tracker.refresh()
+	require.Equal(t, "0.200.0", currentCodexCLIVersion())
+}
@@ -0,0 +1,107 @@
+package service
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/ShaohongDong/sub2api/internal/config"
+	infraerrors "github.com/ShaohongDong/sub2api/internal/pkg/errors"
+)
+
+// ErrConfigReloadFailed 配置文件读取或校验失败，旧配置继续生效
+var ErrConfigReloadFailed = infraerrors.BadRequest("CONFIG_RELOAD_FAILED", "config reload failed, previous config kept")
+
+// ConfigReloadResult 一次配置重载的结果
+type ConfigReloadResult struct {
+	ReloadedAt time.Time `json:"reloaded_at"`
+	// ConfigFile 重新读取的配置文件，未使用配置文件时为空
+	ConfigFile string `json:"config_file,omitempty"`
+	// Reloaded 已生效的配置项
+	Reloaded []string `json:"reloaded"`
+}
+
+// configReloadItems 支持热重载的配置项（其余配置项仍需重启生效）
+var configReloadItems = []string{
+	"gateway.codex_cli_user_agent_prefixes",
+	"pricing.overrides",
+	"settings.routing_rules",
+	"settings.model_aliases",
+	"settings.pricing_overrides",
+	"settings.header_policy",
+	"settings.canary",
+	"settings.shadow_mirror",
+	"settings.client_version_bounds",
+}
+
+// ConfigReloadService 处理 SIGHUP / 管理接口触发的配置热重载：
+// 重新读取配置文件并回调已注册的重载回调，同时使数据库中的运行时设置缓存失效。
+// 各配置项以原子替换的方式生效，进行中的请求（含流式响应）继续使用旧值，不会被中断。
+type ConfigReloadService struct {
+	billingService *BillingService
+	settingService *SettingService
+
+	mu sync.Mutex
+}
+
+// NewConfigReloadService 创建配置重载服务，并注册配置文件价格表的重载回调
+func NewConfigReloadService(billingService *BillingService, settingService *SettingService) *ConfigReloadService {
+	s := &ConfigReloadService{
+		billingService: billingService,
+		settingService: settingService,
+	}
+	config.OnConfigReload(s.applyConfig)
+	return s
+}
+
+func (s *ConfigReloadService) applyConfig(cfg *config.Config) {
+	if s.billingService != nil {
+		s.billingService.ReloadConfigPriceOverrides(cfg)
+	}
+}
+
+// Reload 重新加载配置文件与运行时设置；配置文件校验失败时返回错误并保留旧配置
+func (s *ConfigReloadService) Reload(ctx context.Context) (*ConfigReloadResult, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, err := config.Reload(); err != nil {
+		slog.WarnContext(ctx, "config.reload_rejected", "error", err)
+		return nil, ErrConfigReloadFailed.WithMetadata(map[string]string{"reason": err.Error()})
+	}
+	s.settingService.InvalidateRuntimeSettingCaches()
+
+	result := &ConfigReloadResult{
+		ReloadedAt: time.Now(),
+		ConfigFile: config.ConfigFileUsed(),
+		Reloaded:   append([]string(nil), configReloadItems...),
+	}
+	slog.InfoContext(ctx, "config.reloaded", "config_file", result.ConfigFile)
+	return result, nil
+}
+
+// InvalidateRuntimeSettingCaches 使路由规则、模型别名、价格表等设置的进程内缓存立即失效，
+// 下次读取时从数据库重新加载
+func (s *SettingService) InvalidateRuntimeSettingCaches() {
+	if s == nil {
+		return
+	}
+	routingRuleSF.Forget("routing_rules")
+	routingRuleCache.Store(&cachedRoutingRules{})
+	modelAliasSF.Forget("model_aliases")
+	modelAliasCache.Store(&cachedModelAliases{})
+	pricingOverrideSF.Forget("pricing_overrides")
+	pricingOverrideCache.Store(&cachedPricingOverrides{})
+	headerPolicySF.Forget("header_policy")
+	headerPolicyCache.Store(&cachedHeaderPolicySettings{})
+	canarySF.Forget("canary")
+	canaryCache.Store(&cachedCanarySettings{})
+	shadowMirrorSF.Forget("shadow_mirror")
+	shadowMirrorCache.Store(&cachedShadowMirrorSettings{})
+	versionBoundsSF.Forget("version_bounds")
+	versionBoundsCache.Store(&cachedVersionBounds{})
+	if s.onUpdate != nil {
+		s.onUpdate()
+	}
+}
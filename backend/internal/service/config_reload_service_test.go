@@ -0,0 +1,51 @@
+//go:build unit
+
+package service
+
+import (
+	"context"
+	"testing"
+
+	"github.com/ShaohongDong/sub2api/internal/config"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBillingService_ReloadConfigPriceOverrides(t *testing.T) {
+	svc := NewBillingService(&config.Config{Pricing: config.PricingConfig{Overrides: []config.PricingOverrideConfig{
+		{Model: "claude-sonnet-4", InputPerMTok: floatPtr(1)},
+	}}}, nil)
+	pricing, err := svc.GetModelPricing("claude-sonnet-4")
+	require.NoError(t, err)
+	require.InDelta(t, 1e-6, pricing.InputPricePerToken, 1e-12)
+
+	svc.ReloadConfigPriceOverrides(&config.Config{Pricing: config.PricingConfig{Overrides: []config.PricingOverrideConfig{
+		{Model: "claude-sonnet-4", InputPerMTok: floatPtr(2)},
+	}}})
+	pricing, err = svc.GetModelPricing("claude-sonnet-4")
+	require.NoError(t, err)
+	require.InDelta(t, 2e-6, pricing.InputPricePerToken, 1e-12)
+
+	// 配置中移除后回退到内置价格 $3/MTok
+	svc.ReloadConfigPriceOverrides(&config.Config{})
+	pricing, err = svc.GetModelPricing("claude-sonnet-4")
+	require.NoError(t, err)
+	require.InDelta(t, 3e-6, pricing.InputPricePerToken, 1e-12)
+}
+
+func TestSettingService_InvalidateRuntimeSettingCaches(t *testing.T) {
+	repo := newRuntimeSettingRepoStub()
+	svc := NewSettingService(repo, &config.Config{})
+	ctx := context.Background()
+	t.Cleanup(func() { svc.InvalidateRuntimeSettingCaches() })
+
+	require.NoError(t, svc.SetModelAliasSettings(ctx, &ModelAliasSettings{Aliases: map[string]string{"sonnet": "claude-sonnet-4-5"}}))
+	// 其他实例直接修改了数据库，本实例缓存尚未过期
+	repo.values[SettingKeyModelAliasSettings] = `{"aliases":{"sonnet":"claude-sonnet-4-6"}}`
+	require.Equal(t, "claude-sonnet-4-5", svc.GetModelAliases(ctx)["sonnet"])
+
+	updated := false
+	svc.SetOnUpdateCallback(func() { updated = true })
+	svc.InvalidateRuntimeSettingCaches()
+	require.True(t, updated)
+	require.Equal(t, "claude-sonnet-4-6", svc.GetModelAliases(ctx)["sonnet"])
+}
@@ -0,0 +1,211 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ShaohongDong/sub2api/internal/config"
+	infraerrors "github.com/ShaohongDong/sub2api/internal/pkg/errors"
+	"github.com/ShaohongDong/sub2api/internal/pkg/logger"
+	"go.uber.org/zap"
+)
+
+const (
+	// contentLogWriteTimeout 内容记录写入超时；与请求 context 解耦，客户端断开也要落库
+	contentLogWriteTimeout = 5 * time.Second
+	// contentLogCleanupInterval 过期记录清理间隔
+	contentLogCleanupInterval = time.Hour
+	// contentLogMaxQueryLength 检索关键字的最大长度
+	contentLogMaxQueryLength = 200
+)
+
+var (
+	ErrContentLogNotFound     = infraerrors.NotFound("CONTENT_LOG_NOT_FOUND", "content log not found")
+	ErrContentLogInvalidQuery = infraerrors.BadRequest("CONTENT_LOG_INVALID_QUERY",
+		fmt.Sprintf("q must be at most %d characters", contentLogMaxQueryLength))
+)
+
+// RequestContentLog 开启了 log_content 的 API Key 的一次网关请求的完整内容
+type RequestContentLog struct {
+	ID              int64  `json:"id"`
+	RequestID       string `json:"request_id,omitempty"`
+	ClientRequestID string `json:"client_request_id,omitempty"`
+	UserID          *int64 `json:"user_id,omitempty"`
+	UserEmail       string `json:"user_email,omitempty"`
+	APIKeyID        *int64 `json:"api_key_id,omitempty"`
+	GroupID         *int64 `json:"group_id,omitempty"`
+	AccountID       *int64 `json:"account_id,omitempty"`
+	Platform        string `json:"platform"`
+	Model           string `json:"model"`
+	RequestPath     string `json:"request_path"`
+	Stream          bool   `json:"stream"`
+	StatusCode      int    `json:"status_code"`
+	DurationMs      int    `json:"duration_ms"`
+	// RequestBody / ResponseBody 超过 content_log.max_body_bytes 的部分被截断；*Bytes 为原始大小
+	RequestBody       string `json:"request_body,omitempty"`
+	RequestBodyBytes  int    `json:"request_body_bytes"`
+	ResponseBody      string `json:"response_body,omitempty"`
+	ResponseBodyBytes int    `json:"response_body_bytes"`
+
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// ContentLogFilter 内容记录查询条件，零值字段不参与过滤
+type ContentLogFilter struct {
+	StartTime  *time.Time
+	EndTime    *time.Time
+	UserID     int64
+	APIKeyID   int64
+	Model      string
+	RequestID  string
+	StatusCode int
+	// Query 在请求体与响应体中检索的关键字（不区分大小写）
+	Query    string
+	Page     int
+	PageSize int
+}
+
+// ContentLogList 内容记录分页结果（列表不含请求体与响应体）
+type ContentLogList struct {
+	Items    []*RequestContentLog `json:"items"`
+	Total    int64                `json:"total"`
+	Page     int                  `json:"page"`
+	PageSize int                  `json:"page_size"`
+}
+
+// ContentLogRepository 内容记录存储
+type ContentLogRepository interface {
+	Insert(ctx context.Context, entry *RequestContentLog) error
+	GetByID(ctx context.Context, id int64) (*RequestContentLog, error)
+	List(ctx context.Context, filter *ContentLogFilter) (*ContentLogList, error)
+	DeleteCreatedBefore(ctx context.Context, cutoff time.Time) (int64, error)
+}
+
+// ContentLogService 保存开启了 log_content 的 API Key 的完整提示词与响应，供管理员排查问题。
+// 记录与常驻的用量 / 运维元数据日志分开存放，超过 content_log.retention_days 自动清理。
+type ContentLogService struct {
+	repo ContentLogRepository
+	cfg  config.ContentLogConfig
+
+	ctx      context.Context
+	cancel   context.CancelFunc
+	stopOnce sync.Once
+	wg       sync.WaitGroup
+}
+
+// NewContentLogService 创建内容记录服务
+func NewContentLogService(repo ContentLogRepository, cfg *config.Config) *ContentLogService {
+	var clCfg config.ContentLogConfig
+	if cfg != nil {
+		clCfg = cfg.ContentLog
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	return &ContentLogService{repo: repo, cfg: clCfg, ctx: ctx, cancel: cancel}
+}
+
+// Enabled 是否允许记录请求内容
+func (s *ContentLogService) Enabled() bool {
+	return s != nil && s.repo != nil && s.cfg.Enabled
+}
+
+// ShouldLog 是否记录该 Key 的请求内容
+func (s *ContentLogService) ShouldLog(apiKey *APIKey) bool {
+	return s.Enabled() && apiKey != nil && apiKey.LogContent
+}
+
+// MaxBodyBytes 请求体与响应体各自保存的最大字节数
+func (s *ContentLogService) MaxBodyBytes() int {
+	return s.cfg.MaxBodyBytes
+}
+
+// Start 启动过期清理
+func (s *ContentLogService) Start() {
+	if !s.Enabled() {
+		return
+	}
+	s.wg.Add(1)
+	go func() {
+		defer s.wg.Done()
+		ticker := time.NewTicker(contentLogCleanupInterval)
+		defer ticker.Stop()
+		s.cleanup()
+		for {
+			select {
+			case <-ticker.C:
+				s.cleanup()
+			case <-s.ctx.Done():
+				return
+			}
+		}
+	}()
+}
+
+// Stop 停止过期清理
+func (s *ContentLogService) Stop() {
+	if s == nil {
+		return
+	}
+	s.stopOnce.Do(s.cancel)
+	s.wg.Wait()
+}
+
+func (s *ContentLogService) cleanup() {
+	ctx, cancel := context.WithTimeout(s.ctx, time.Minute)
+	defer cancel()
+	if n, err := s.repo.DeleteCreatedBefore(ctx, time.Now().AddDate(0, 0, -s.cfg.RetentionDays)); err != nil {
+		logger.L().Warn("content_log.cleanup_failed", zap.Error(err))
+	} else if n > 0 {
+		logger.L().Info("content_log.cleanup", zap.Int64("deleted", n))
+	}
+}
+
+// Record 写入一条内容记录；失败只记录日志，不影响已返回给客户端的响应
+func (s *ContentLogService) Record(ctx context.Context, entry *RequestContentLog) {
+	if !s.Enabled() || entry == nil {
+		return
+	}
+	writeCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), contentLogWriteTimeout)
+	defer cancel()
+	if err := s.repo.Insert(writeCtx, entry); err != nil {
+		logger.FromContext(ctx).Error("content_log.insert_failed",
+			zap.Error(err),
+			zap.String("request_path", entry.RequestPath),
+			zap.String("model", entry.Model),
+		)
+	}
+}
+
+// List 分页检索内容记录（按时间倒序）
+func (s *ContentLogService) List(ctx context.Context, filter *ContentLogFilter) (*ContentLogList, error) {
+	filter.Model = strings.TrimSpace(filter.Model)
+	filter.RequestID = strings.TrimSpace(filter.RequestID)
+	filter.Query = strings.TrimSpace(filter.Query)
+	if len([]rune(filter.Query)) > contentLogMaxQueryLength {
+		return nil, ErrContentLogInvalidQuery
+	}
+	return s.repo.List(ctx, filter)
+}
+
+// Get 返回单条内容记录（含请求体与响应体）
+func (s *ContentLogService) Get(ctx context.Context, id int64) (*RequestContentLog, error) {
+	return s.repo.GetByID(ctx, id)
+}
+
+// SetContentLogging 开启或关闭 Key 的请求内容记录（仅管理员可设置）
+func (s *APIKeyService) SetContentLogging(ctx context.Context, id int64, enabled bool) (*APIKey, error) {
+	apiKey, err := s.apiKeyRepo.GetByID(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("get api key: %w", err)
+	}
+	apiKey.LogContent = enabled
+	if err := s.apiKeyRepo.Update(ctx, apiKey); err != nil {
+		return nil, fmt.Errorf("update api key: %w", err)
+	}
+
+	s.InvalidateAuthCacheByKey(ctx, apiKey.Key)
+	s.compileAPIKeyIPRules(apiKey)
+	return apiKey, nil
+}
@@ -0,0 +1,104 @@
+//go:build unit
+
+package service
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ShaohongDong/sub2api/internal/config"
+	infraerrors "github.com/ShaohongDong/sub2api/internal/pkg/errors"
+	"github.com/stretchr/testify/require"
+)
+
+type contentLogRepoStub struct {
+	mu       sync.Mutex
+	inserted []*RequestContentLog
+	insertOK bool
+	filter   *ContentLogFilter
+	cutoff   time.Time
+}
+
+func (r *contentLogRepoStub) Insert(ctx context.Context, entry *RequestContentLog) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.insertOK = ctx.Err() == nil
+	r.inserted = append(r.inserted, entry)
+	return nil
+}
+
+func (r *contentLogRepoStub) GetByID(ctx context.Context, id int64) (*RequestContentLog, error) {
+	return nil, ErrContentLogNotFound
+}
+
+func (r *contentLogRepoStub) List(ctx context.Context, filter *ContentLogFilter) (*ContentLogList, error) {
+	r.filter = filter
+	return &ContentLogList{Page: filter.Page, PageSize: filter.PageSize}, nil
+}
+
+func (r *contentLogRepoStub) DeleteCreatedBefore(ctx context.Context, cutoff time.Time) (int64, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.cutoff = cutoff
+	return 0, nil
+}
+
+func newContentLogTestConfig() *config.Config {
+	return &config.Config{ContentLog: config.ContentLogConfig{Enabled: true, MaxBodyBytes: 1024, RetentionDays: 3}}
+}
+
+func TestContentLogService_ShouldLogRequiresKeyOptIn(t *testing.T) {
+	svc := NewContentLogService(&contentLogRepoStub{}, newContentLogTestConfig())
+	require.False(t, svc.ShouldLog(nil))
+	require.False(t, svc.ShouldLog(&APIKey{ID: 1}))
+	require.True(t, svc.ShouldLog(&APIKey{ID: 1, LogContent: true}))
+
+	// 全局关闭后即使 Key 开启也不记录
+	cfg := newContentLogTestConfig()
+	cfg.ContentLog.Enabled = false
+	require.False(t, NewContentLogService(&contentLogRepoStub{}, cfg).ShouldLog(&APIKey{ID: 1, LogContent: true}))
+
+	var nilSvc *ContentLogService
+	require.False(t, nilSvc.ShouldLog(&APIKey{ID: 1, LogContent: true}))
+}
+
+func TestContentLogService_RecordSurvivesCanceledRequest(t *testing.T) {
+	repo := &contentLogRepoStub{}
+	svc := NewContentLogService(repo, newContentLogTestConfig())
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	svc.Record(ctx, &RequestContentLog{RequestPath: "/v1/messages"})
+
+	require.Len(t, repo.inserted, 1)
+	require.True(t, repo.insertOK, "insert should not inherit the client's cancellation")
+}
+
+func TestContentLogService_ListValidatesQuery(t *testing.T) {
+	repo := &contentLogRepoStub{}
+	svc := NewContentLogService(repo, newContentLogTestConfig())
+
+	_, err := svc.List(context.Background(), &ContentLogFilter{Query: strings.Repeat("界", contentLogMaxQueryLength+1)})
+	require.ErrorIs(t, err, ErrContentLogInvalidQuery)
+	require.Equal(t, 400, infraerrors.Code(err))
+
+	_, err = svc.List(context.Background(), &ContentLogFilter{Query: "  refund ", Model: " claude ", Page: 1, PageSize: 20})
+	require.NoError(t, err)
+	require.Equal(t, "refund", repo.filter.Query)
+	require.Equal(t, "claude", repo.filter.Model)
+}
+
+func TestContentLogService_StartPurgesExpiredRecords(t *testing.T) {
+	repo := &contentLogRepoStub{}
+	svc := NewContentLogService(repo, newContentLogTestConfig())
+	svc.Start()
+	svc.Stop()
+
+	repo.mu.Lock()
+	defer repo.mu.Unlock()
+	require.False(t, repo.cutoff.IsZero())
+	require.WithinDuration(t, time.Now().AddDate(0, 0, -3), repo.cutoff, time.Minute)
+}
@@ -0,0 +1,451 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/ShaohongDong/sub2api/internal/config"
+	infraerrors "github.com/ShaohongDong/sub2api/internal/pkg/errors"
+	"github.com/ShaohongDong/sub2api/internal/pkg/tokenizer"
+	"github.com/tidwall/gjson"
+	"github.com/tidwall/sjson"
+)
+
+// ErrContextWindowExceeded 请求超出模型上下文窗口且无法（或不允许）裁剪
+var ErrContextWindowExceeded = infraerrors.BadRequest("CONTEXT_WINDOW_EXCEEDED", "request exceeds the model context window")
+
+// 上下文窗口超限的处理结果（X-Context-Overflow 响应头）
+const (
+	ContextOverflowTruncated = "truncated"
+	ContextOverflowCompacted = "compacted"
+)
+
+// contextOverflowSummaryPrompt 压缩摘要请求的系统提示词
+const contextOverflowSummaryPrompt = "Summarize the following earlier part of a conversation so it can replace those turns. " +
+	"Keep facts, decisions, names, file paths, code identifiers, open questions and any instructions the user gave. " +
+	"Write the summary only, without preamble."
+
+// contextOverflowSummaryHeader 摘要附加到系统提示词时的前缀
+const contextOverflowSummaryHeader = "Summary of the earlier conversation (older turns were compacted to fit the context window):\n"
+
+// ContextOverflowResult 一次超限处理的结果
+type ContextOverflowResult struct {
+	// Action 为 truncated / compacted，未超限时为空
+	Action          string
+	EstimatedTokens int
+	Budget          int
+	DroppedItems    int
+}
+
+// ContextOverflowService 在转发前估算请求的输入 token 数，超出模型上下文窗口时按配置的策略
+// 直接拒绝（给出准确的 token 数与上限）、丢弃最早的对话轮次，或先将这些轮次交由模型摘要后再丢弃，
+// 避免把必然失败的请求转发给上游换来一个笼统的 400。
+type ContextOverflowService struct {
+	cfg        *config.Config
+	pricing    *PricingService
+	dispatcher BatchRequestDispatcher
+}
+
+// NewContextOverflowService 创建上下文窗口超限处理服务
+func NewContextOverflowService(cfg *config.Config, pricing *PricingService) *ContextOverflowService {
+	return &ContextOverflowService{cfg: cfg, pricing: pricing}
+}
+
+// SetDispatcher 注入进程内请求分发器，compact 策略经由网关路由生成摘要（按同一 Key 计费）
+func (s *ContextOverflowService) SetDispatcher(d BatchRequestDispatcher) {
+	s.dispatcher = d
+}
+
+// Enabled 是否配置了任何非 off 的策略
+func (s *ContextOverflowService) Enabled() bool {
+	if s == nil || s.cfg == nil {
+		return false
+	}
+	oc := s.cfg.Gateway.ContextOverflow
+	if oc.Strategy != "" && oc.Strategy != config.ContextOverflowStrategyOff {
+		return true
+	}
+	for _, rule := range oc.Rules {
+		if rule.Strategy != "" && rule.Strategy != config.ContextOverflowStrategyOff {
+			return true
+		}
+	}
+	return false
+}
+
+// ContextOverflowRequest 一次超限检查的输入
+type ContextOverflowRequest struct {
+	// Format 入口请求格式（ReasoningFormatAnthropic / Responses / ChatCompletions）
+	Format   string
+	Model    string
+	Body     []byte
+	APIKey   string
+	ClientIP string
+}
+
+// Handle 检查请求是否超出上下文窗口并按策略处理，返回（可能被改写的）请求体。
+// 拒绝时返回 ErrContextWindowExceeded（消息中带估算的 token 数与上限）。
+func (s *ContextOverflowService) Handle(ctx context.Context, req ContextOverflowRequest) ([]byte, ContextOverflowResult, error) {
+	var result ContextOverflowResult
+	if !s.Enabled() || !gjson.ValidBytes(req.Body) {
+		return req.Body, result, nil
+	}
+	window, strategy := s.resolve(req.Model)
+	if window <= 0 || strategy == config.ContextOverflowStrategyOff {
+		return req.Body, result, nil
+	}
+	reserved := 0
+	for _, field := range paramSanitizeMaxTokenFields(req.Format) {
+		if v := gjson.GetBytes(req.Body, field).Int(); v > int64(reserved) {
+			reserved = int(v)
+		}
+	}
+	budget := (window - reserved) * (100 - s.cfg.Gateway.ContextOverflow.SafetyMarginPercent) / 100
+	estimated := countContextOverflowTokens(req.Format, req.Model, req.Body)
+	result.EstimatedTokens, result.Budget = estimated, budget
+	if estimated <= budget {
+		return req.Body, result, nil
+	}
+
+	exceeded := infraerrors.Newf(http.StatusBadRequest, ErrContextWindowExceeded.Reason,
+		"input is about %d tokens, which exceeds the %d-token context window of %s (%d tokens reserved for output, %d%% safety margin)",
+		estimated, window, req.Model, reserved, s.cfg.Gateway.ContextOverflow.SafetyMarginPercent)
+	if strategy == config.ContextOverflowStrategyReject {
+		return req.Body, result, exceeded
+	}
+
+	conv := splitContextOverflowTurns(req.Format, req.Body)
+	if conv == nil {
+		return req.Body, result, exceeded
+	}
+	target := budget
+	if strategy == config.ContextOverflowStrategyCompact {
+		target -= s.cfg.Gateway.ContextOverflow.CompactMaxTokens
+	}
+	cut, ok := conv.cutPoint(req.Format, req.Model, req.Body, target)
+	if !ok {
+		return req.Body, result, exceeded
+	}
+	out, err := conv.withoutItems(req.Body, cut)
+	if err != nil {
+		return req.Body, result, exceeded
+	}
+	result.Action = ContextOverflowTruncated
+	result.DroppedItems = cut - conv.prefix
+
+	if strategy == config.ContextOverflowStrategyCompact {
+		if summary, err := s.summarize(ctx, req, conv.items[conv.prefix:cut], budget/2); err == nil && summary != "" {
+			if compacted, ok := ApplySystemPrompt(out, req.Format, SystemPromptModeAppend, contextOverflowSummaryHeader+summary); ok &&
+				countContextOverflowTokens(req.Format, req.Model, compacted) <= budget {
+				out = compacted
+				result.Action = ContextOverflowCompacted
+			}
+		}
+	}
+	return out, result, nil
+}
+
+// resolve 返回模型的上下文窗口与生效的策略：首条命中的规则优先，其次为价格数据中的 max_input_tokens
+func (s *ContextOverflowService) resolve(model string) (int, string) {
+	oc := s.cfg.Gateway.ContextOverflow
+	window, strategy := 0, oc.Strategy
+	for i := range oc.Rules {
+		rule := &oc.Rules[i]
+		if len(rule.Models) > 0 && !routingModelMatches(rule.Models, model) {
+			continue
+		}
+		window = rule.ContextWindow
+		if rule.Strategy != "" {
+			strategy = rule.Strategy
+		}
+		break
+	}
+	if window <= 0 && oc.UsePricingLimits && s.pricing != nil && model != "" {
+		if pricing := s.pricing.GetModelPricing(model); pricing != nil {
+			window = pricing.MaxInputTokens
+		}
+	}
+	return window, strategy
+}
+
+// summarize 经由网关路由以同一入口格式请求模型摘要被丢弃的轮次；摘要输入超过 maxTokens 时只保留较新的部分
+func (s *ContextOverflowService) summarize(ctx context.Context, req ContextOverflowRequest, dropped []gjson.Result, maxTokens int) (string, error) {
+	if s.dispatcher == nil || req.APIKey == "" {
+		return "", fmt.Errorf("context overflow: no dispatcher")
+	}
+	model := s.cfg.Gateway.ContextOverflow.CompactModel
+	if model == "" {
+		model = req.Model
+	}
+	transcript := renderContextOverflowTranscript(dropped)
+	for len(transcript) > 0 && tokenizer.Count(model, transcript) > maxTokens {
+		transcript = transcript[len(transcript)/4:]
+	}
+	transcript = strings.ToValidUTF8(transcript, "")
+	if transcript == "" {
+		return "", fmt.Errorf("context overflow: empty transcript")
+	}
+
+	maxOutput := s.cfg.Gateway.ContextOverflow.CompactMaxTokens
+	var endpoint string
+	body := []byte(`{}`)
+	var err error
+	set := func(path string, value any) {
+		if err == nil {
+			body, err = sjson.SetBytes(body, path, value)
+		}
+	}
+	set("model", model)
+	switch req.Format {
+	case ReasoningFormatAnthropic:
+		endpoint = "/v1/messages"
+		set("max_tokens", maxOutput)
+		set("system", contextOverflowSummaryPrompt)
+		set("messages.0.role", "user")
+		set("messages.0.content", transcript)
+	case ReasoningFormatChatCompletions:
+		endpoint = "/v1/chat/completions"
+		set("max_tokens", maxOutput)
+		set("messages.0.role", "system")
+		set("messages.0.content", contextOverflowSummaryPrompt)
+		set("messages.1.role", "user")
+		set("messages.1.content", transcript)
+	case ReasoningFormatResponses:
+		endpoint = "/v1/responses"
+		set("max_output_tokens", maxOutput)
+		set("instructions", contextOverflowSummaryPrompt)
+		set("input", transcript)
+		set("store", false)
+	default:
+		return "", fmt.Errorf("context overflow: unsupported format %q", req.Format)
+	}
+	if err != nil {
+		return "", err
+	}
+
+	res, err := s.dispatcher.Dispatch(ctx, req.APIKey, req.ClientIP, http.MethodPost, endpoint, body)
+	if err != nil {
+		return "", err
+	}
+	if res.StatusCode < 200 || res.StatusCode >= 300 {
+		return "", fmt.Errorf("context overflow: summary request returned %d", res.StatusCode)
+	}
+	return strings.TrimSpace(extractContextOverflowSummary(req.Format, res.Body)), nil
+}
+
+// extractContextOverflowSummary 从摘要响应中取出文本
+func extractContextOverflowSummary(format string, body []byte) string {
+	var sb strings.Builder
+	switch format {
+	case ReasoningFormatAnthropic:
+		gjson.GetBytes(body, "content").ForEach(func(_, block gjson.Result) bool {
+			if block.Get("type").String() == "text" {
+				sb.WriteString(block.Get("text").String())
+			}
+			return true
+		})
+	case ReasoningFormatChatCompletions:
+		sb.WriteString(gjson.GetBytes(body, "choices.0.message.content").String())
+	case ReasoningFormatResponses:
+		gjson.GetBytes(body, "output").ForEach(func(_, item gjson.Result) bool {
+			item.Get("content").ForEach(func(_, part gjson.Result) bool {
+				if part.Get("type").String() == "output_text" {
+					sb.WriteString(part.Get("text").String())
+				}
+				return true
+			})
+			return true
+		})
+	}
+	return sb.String()
+}
+
+// countContextOverflowTokens 按入口格式估算请求的输入 token 数
+func countContextOverflowTokens(format, model string, body []byte) int {
+	if format == ReasoningFormatAnthropic {
+		return CountAnthropicRequestTokens(body)
+	}
+	return CountOpenAIRequestTokens(model, body)
+}
+
+// contextOverflowConversation 请求中的对话数组：items[:prefix] 为始终保留的开头系统 / developer 消息，
+// starts 为各轮（非工具结果的用户消息）的起始下标
+type contextOverflowConversation struct {
+	path   string
+	items  []gjson.Result
+	prefix int
+	starts []int
+}
+
+// splitContextOverflowTurns 按入口格式拆分对话轮次；对话不是数组时返回 nil
+func splitContextOverflowTurns(format string, body []byte) *contextOverflowConversation {
+	path := "messages"
+	if format == ReasoningFormatResponses {
+		path = "input"
+	}
+	arr := gjson.GetBytes(body, path)
+	if !arr.IsArray() {
+		return nil
+	}
+	conv := &contextOverflowConversation{path: path, items: arr.Array()}
+	if format != ReasoningFormatAnthropic {
+		for conv.prefix < len(conv.items) {
+			role := conv.items[conv.prefix].Get("role").String()
+			if role != "system" && role != "developer" {
+				break
+			}
+			conv.prefix++
+		}
+	}
+	for i := conv.prefix; i < len(conv.items); i++ {
+		if isContextOverflowTurnStart(format, conv.items[i]) {
+			conv.starts = append(conv.starts, i)
+		}
+	}
+	return conv
+}
+
+// isContextOverflowTurnStart 判断是否为新一轮的开始：用户消息，且不是 Anthropic 的工具结果消息
+// （工具调用与其结果留在同一轮，成对丢弃）
+func isContextOverflowTurnStart(format string, item gjson.Result) bool {
+	if item.Get("role").String() != "user" {
+		return false
+	}
+	if format == ReasoningFormatResponses {
+		if typ := item.Get("type").String(); typ != "" && typ != "message" {
+			return false
+		}
+	}
+	if format == ReasoningFormatAnthropic {
+		content := item.Get("content")
+		if content.IsArray() {
+			for _, block := range content.Array() {
+				if block.Get("type").String() != "tool_result" {
+					return true
+				}
+			}
+			return len(content.Array()) == 0
+		}
+	}
+	return true
+}
+
+// cutPoint 返回需丢弃到的下标（丢弃 items[prefix:cut]），使剩余请求不超过 target；
+// 最后一轮也放不下时返回 false
+func (conv *contextOverflowConversation) cutPoint(format, model string, body []byte, target int) (int, bool) {
+	empty, err := sjson.SetRawBytes(body, conv.path, []byte("[]"))
+	if err != nil {
+		return 0, false
+	}
+	base := countContextOverflowTokens(format, model, empty)
+	tokens := make([]int, len(conv.items))
+	total := base
+	for i, item := range conv.items {
+		single, err := sjson.SetRawBytes(empty, conv.path, []byte("["+item.Raw+"]"))
+		if err != nil {
+			return 0, false
+		}
+		tokens[i] = countContextOverflowTokens(format, model, single) - base
+		total += tokens[i]
+	}
+	dropped := 0
+	for k, start := range conv.starts {
+		if k == 0 {
+			continue
+		}
+		for i := conv.starts[k-1]; i < start; i++ {
+			dropped += tokens[i]
+		}
+		if k == 1 {
+			for i := conv.prefix; i < conv.starts[0]; i++ {
+				dropped += tokens[i]
+			}
+		}
+		if total-dropped <= target {
+			return start, true
+		}
+	}
+	return 0, false
+}
+
+// withoutItems 返回丢弃 items[prefix:cut] 后的请求体
+func (conv *contextOverflowConversation) withoutItems(body []byte, cut int) ([]byte, error) {
+	var sb strings.Builder
+	sb.WriteByte('[')
+	n := 0
+	for i, item := range conv.items {
+		if i >= conv.prefix && i < cut {
+			continue
+		}
+		if n > 0 {
+			sb.WriteByte(',')
+		}
+		sb.WriteString(item.Raw)
+		n++
+	}
+	sb.WriteByte(']')
+	return sjson.SetRawBytes(body, conv.path, []byte(sb.String()))
+}
+
+// renderContextOverflowTranscript 将被丢弃的消息渲染为 "role: text" 形式的纯文本对话记录
+func renderContextOverflowTranscript(items []gjson.Result) string {
+	var sb strings.Builder
+	for _, item := range items {
+		role := item.Get("role").String()
+		if role == "" {
+			role = item.Get("type").String()
+		}
+		text := contextOverflowItemText(item)
+		if text == "" {
+			continue
+		}
+		sb.WriteString(role)
+		sb.WriteString(": ")
+		sb.WriteString(text)
+		sb.WriteString("\n\n")
+	}
+	return sb.String()
+}
+
+// contextOverflowItemText 提取消息中的文本；工具调用与结果只保留名称与内容
+func contextOverflowItemText(item gjson.Result) string {
+	switch item.Get("type").String() {
+	case "function_call":
+		return "[tool call " + item.Get("name").String() + "] " + item.Get("arguments").String()
+	case "function_call_output":
+		return "[tool result] " + item.Get("output").String()
+	}
+	var parts []string
+	if calls := item.Get("tool_calls"); calls.IsArray() {
+		for _, call := range calls.Array() {
+			parts = append(parts, "[tool call "+call.Get("function.name").String()+"] "+call.Get("function.arguments").String())
+		}
+	}
+	content := item.Get("content")
+	if content.Type == gjson.String {
+		parts = append(parts, content.String())
+	}
+	content.ForEach(func(_, block gjson.Result) bool {
+		switch block.Get("type").String() {
+		case "text", "input_text", "output_text":
+			parts = append(parts, block.Get("text").String())
+		case "tool_use":
+			parts = append(parts, "[tool call "+block.Get("name").String()+"] "+block.Get("input").Raw)
+		case "tool_result":
+			if inner := block.Get("content"); inner.Type == gjson.String {
+				parts = append(parts, "[tool result] "+inner.String())
+			} else {
+				inner.ForEach(func(_, b gjson.Result) bool {
+					if b.Get("type").String() == "text" {
+						parts = append(parts, "[tool result] "+b.Get("text").String())
+					}
+					return true
+				})
+			}
+		}
+		return true
+	})
+	return strings.TrimSpace(strings.Join(parts, "\n"))
+}
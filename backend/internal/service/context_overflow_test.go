@@ -0,0 +1,175 @@
+//go:build unit
+
+package service
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/ShaohongDong/sub2api/internal/config"
+	infraerrors "github.com/ShaohongDong/sub2api/internal/pkg/errors"
+	"github.com/stretchr/testify/require"
+	"github.com/tidwall/gjson"
+)
+
+type contextOverflowDispatcherStub struct {
+	url    string
+	body   []byte
+	apiKey string
+	resp   *BatchDispatchResult
+	err    error
+}
+
+func (d *contextOverflowDispatcherStub) Dispatch(_ context.Context, apiKey, _, _, url string, body []byte) (*BatchDispatchResult, error) {
+	d.apiKey, d.url, d.body = apiKey, url, body
+	return d.resp, d.err
+}
+
+func newTestContextOverflowService(strategy string, window int) *ContextOverflowService {
+	cfg := &config.Config{}
+	cfg.Gateway.ContextOverflow = config.GatewayContextOverflowConfig{
+		Strategy:         strategy,
+		CompactMaxTokens: 50,
+		Rules:            []config.GatewayContextOverflowRule{{Models: []string{"claude-*"}, ContextWindow: window}},
+	}
+	return NewContextOverflowService(cfg, nil)
+}
+
+// contextOverflowTestBody 构造 n 轮的 Anthropic 请求，每轮的用户消息约 200 token
+func contextOverflowTestBody(turns int) []byte {
+	var sb strings.Builder
+	sb.WriteString(`{"model":"claude-sonnet-4","max_tokens":100,"system":"be brief","messages":[`)
+	for i := 0; i < turns; i++ {
+		if i > 0 {
+			sb.WriteByte(',')
+		}
+		sb.WriteString(`{"role":"user","content":"turn` + string(rune('a'+i)) + ` ` + strings.Repeat("lorem ipsum ", 100) + `"},`)
+		sb.WriteString(`{"role":"assistant","content":[{"type":"tool_use","id":"t` + string(rune('a'+i)) + `","name":"read","input":{}}]},`)
+		sb.WriteString(`{"role":"user","content":[{"type":"tool_result","tool_use_id":"t` + string(rune('a'+i)) + `","content":"ok"}]}`)
+	}
+	sb.WriteString(`]}`)
+	return []byte(sb.String())
+}
+
+func TestContextOverflowService_UnderLimitOrUnknownModelUntouched(t *testing.T) {
+	svc := newTestContextOverflowService(config.ContextOverflowStrategyReject, 100000)
+	body := contextOverflowTestBody(3)
+	out, result, err := svc.Handle(context.Background(), ContextOverflowRequest{Format: ReasoningFormatAnthropic, Model: "claude-sonnet-4", Body: body})
+	require.NoError(t, err)
+	require.Empty(t, result.Action)
+	require.Equal(t, body, out)
+
+	svc = newTestContextOverflowService(config.ContextOverflowStrategyReject, 100)
+	out, _, err = svc.Handle(context.Background(), ContextOverflowRequest{Format: ReasoningFormatAnthropic, Model: "gpt-4o", Body: body})
+	require.NoError(t, err)
+	require.Equal(t, body, out)
+
+	require.False(t, newTestContextOverflowService(config.ContextOverflowStrategyOff, 100).Enabled())
+}
+
+func TestContextOverflowService_RejectReportsTokens(t *testing.T) {
+	svc := newTestContextOverflowService(config.ContextOverflowStrategyReject, 500)
+	_, result, err := svc.Handle(context.Background(), ContextOverflowRequest{Format: ReasoningFormatAnthropic, Model: "claude-sonnet-4", Body: contextOverflowTestBody(3)})
+	require.Error(t, err)
+	require.True(t, errors.Is(err, ErrContextWindowExceeded))
+	require.Equal(t, http.StatusBadRequest, infraerrors.Code(err))
+	require.Greater(t, result.EstimatedTokens, result.Budget)
+	require.Contains(t, infraerrors.Message(err), "500-token context window of claude-sonnet-4")
+	require.Contains(t, infraerrors.Message(err), "100 tokens reserved for output")
+}
+
+func TestContextOverflowService_TruncateDropsOldestTurns(t *testing.T) {
+	svc := newTestContextOverflowService(config.ContextOverflowStrategyTruncate, 1000)
+	out, result, err := svc.Handle(context.Background(), ContextOverflowRequest{Format: ReasoningFormatAnthropic, Model: "claude-sonnet-4", Body: contextOverflowTestBody(5)})
+	require.NoError(t, err)
+	require.Equal(t, ContextOverflowTruncated, result.Action)
+	require.LessOrEqual(t, CountAnthropicRequestTokens(out), result.Budget)
+
+	messages := gjson.GetBytes(out, "messages").Array()
+	require.NotEmpty(t, messages)
+	require.Zero(t, len(messages)%3, "turns are dropped whole, tool results stay with their calls")
+	require.Equal(t, result.DroppedItems+len(messages), 15)
+	require.True(t, strings.HasPrefix(messages[0].Get("content").String(), "turn"))
+	require.True(t, strings.HasPrefix(messages[len(messages)-3].Get("content").String(), "turne"))
+	require.Equal(t, "be brief", gjson.GetBytes(out, "system").String())
+
+	// 最后一轮也放不下时拒绝
+	svc = newTestContextOverflowService(config.ContextOverflowStrategyTruncate, 200)
+	_, _, err = svc.Handle(context.Background(), ContextOverflowRequest{Format: ReasoningFormatAnthropic, Model: "claude-sonnet-4", Body: contextOverflowTestBody(5)})
+	require.True(t, errors.Is(err, ErrContextWindowExceeded))
+}
+
+func TestContextOverflowService_TruncateChatKeepsSystemPrefix(t *testing.T) {
+	cfg := &config.Config{}
+	cfg.Gateway.ContextOverflow = config.GatewayContextOverflowConfig{
+		Strategy: config.ContextOverflowStrategyTruncate,
+		Rules:    []config.GatewayContextOverflowRule{{ContextWindow: 400}},
+	}
+	svc := NewContextOverflowService(cfg, nil)
+	long := strings.Repeat("lorem ipsum ", 100)
+	body := []byte(`{"model":"gpt-4o","messages":[{"role":"system","content":"sys"},` +
+		`{"role":"user","content":"one ` + long + `"},{"role":"assistant","content":"a1"},` +
+		`{"role":"user","content":"two ` + long + `"},{"role":"assistant","content":"a2"},` +
+		`{"role":"user","content":"three"}]}`)
+	out, result, err := svc.Handle(context.Background(), ContextOverflowRequest{Format: ReasoningFormatChatCompletions, Model: "gpt-4o", Body: body})
+	require.NoError(t, err)
+	require.Equal(t, ContextOverflowTruncated, result.Action)
+	messages := gjson.GetBytes(out, "messages").Array()
+	require.Equal(t, "sys", messages[0].Get("content").String())
+	require.True(t, strings.HasPrefix(messages[1].Get("content").String(), "two"))
+	require.Equal(t, "three", messages[len(messages)-1].Get("content").String())
+}
+
+func TestContextOverflowService_CompactAppendsSummary(t *testing.T) {
+	svc := newTestContextOverflowService(config.ContextOverflowStrategyCompact, 1000)
+	dispatcher := &contextOverflowDispatcherStub{resp: &BatchDispatchResult{
+		StatusCode: http.StatusOK,
+		Body:       []byte(`{"content":[{"type":"text","text":"user asked about lorem ipsum"}]}`),
+	}}
+	svc.SetDispatcher(dispatcher)
+
+	out, result, err := svc.Handle(context.Background(), ContextOverflowRequest{
+		Format: ReasoningFormatAnthropic, Model: "claude-sonnet-4", Body: contextOverflowTestBody(5), APIKey: "sk-user",
+	})
+	require.NoError(t, err)
+	require.Equal(t, ContextOverflowCompacted, result.Action)
+	require.Equal(t, "/v1/messages", dispatcher.url)
+	require.Equal(t, "sk-user", dispatcher.apiKey)
+	require.Equal(t, int64(50), gjson.GetBytes(dispatcher.body, "max_tokens").Int())
+	require.Contains(t, gjson.GetBytes(dispatcher.body, "messages.0.content").String(), "user: turna")
+	require.Contains(t, gjson.GetBytes(out, "system").String(), "user asked about lorem ipsum")
+	require.LessOrEqual(t, CountAnthropicRequestTokens(out), result.Budget)
+
+	// 摘要失败时退化为 truncate
+	dispatcher.resp = &BatchDispatchResult{StatusCode: http.StatusTooManyRequests}
+	out, result, err = svc.Handle(context.Background(), ContextOverflowRequest{
+		Format: ReasoningFormatAnthropic, Model: "claude-sonnet-4", Body: contextOverflowTestBody(5), APIKey: "sk-user",
+	})
+	require.NoError(t, err)
+	require.Equal(t, ContextOverflowTruncated, result.Action)
+	require.Equal(t, "be brief", gjson.GetBytes(out, "system").String())
+}
+
+func TestContextOverflowService_ResolveUsesPricingLimits(t *testing.T) {
+	cfg := &config.Config{}
+	cfg.Gateway.ContextOverflow = config.GatewayContextOverflowConfig{
+		Strategy:         config.ContextOverflowStrategyReject,
+		UsePricingLimits: true,
+		Rules: []config.GatewayContextOverflowRule{
+			{Models: []string{"gpt-5*"}, Strategy: config.ContextOverflowStrategyTruncate},
+		},
+	}
+	pricing := &PricingService{pricingData: map[string]*LiteLLMModelPricing{"gpt-5": {MaxInputTokens: 272000}}}
+	svc := NewContextOverflowService(cfg, pricing)
+
+	window, strategy := svc.resolve("gpt-5")
+	require.Equal(t, 272000, window)
+	require.Equal(t, config.ContextOverflowStrategyTruncate, strategy)
+
+	window, strategy = svc.resolve("unknown-model")
+	require.Zero(t, window)
+	require.Equal(t, config.ContextOverflowStrategyReject, strategy)
+}
@@ -0,0 +1,254 @@
+package service
+
+import (
+	"bufio"
+	"bytes"
+	"container/list"
+	"context"
+	"encoding/json"
+	"errors"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ShaohongDong/sub2api/internal/config"
+	"github.com/tidwall/gjson"
+	"github.com/tidwall/sjson"
+)
+
+// ErrConversationNotFound 标记会话存储中不存在该 response id
+var ErrConversationNotFound = errors.New("conversation not found")
+
+// StoredConversation 截至某个响应（含）的完整上下文 items，可直接作为下一轮的 input 前缀
+type StoredConversation struct {
+	Items []json.RawMessage `json:"items"`
+}
+
+// ConversationStore 会话存储（memory 由 ConversationStoreService 内置，redis 由 repository 提供）
+type ConversationStore interface {
+	GetConversation(ctx context.Context, key string) (*StoredConversation, error)
+	SetConversation(ctx context.Context, key string, conv *StoredConversation, ttl time.Duration) error
+}
+
+// ConversationStoreService 在本地保存 Responses API 每轮的输入与输出 items（按 API Key 隔离），
+// 续链请求无法依赖上游存储时（HTTP 上游不透传 previous_response_id、换到了其他账号、
+// 或上游 store=false 未保存），用本地上下文重建 input，使 previous_response_id 仍然可用。
+type ConversationStoreService struct {
+	cfg   config.ConversationStoreConfig
+	store ConversationStore
+}
+
+// NewConversationStoreService creates a new ConversationStoreService.
+// backend 为 redis 时使用 redisStore，否则使用进程内存储。
+func NewConversationStoreService(cfg *config.Config, redisStore ConversationStore) *ConversationStoreService {
+	s := &ConversationStoreService{}
+	if cfg == nil || !cfg.ConversationStore.Enabled {
+		return s
+	}
+	s.cfg = cfg.ConversationStore
+	if s.cfg.Backend == "redis" && redisStore != nil {
+		s.store = redisStore
+	} else {
+		s.store = newMemoryConversationStore(s.cfg.MaxEntries)
+	}
+	return s
+}
+
+// Enabled 是否启用会话存储
+func (s *ConversationStoreService) Enabled() bool {
+	return s != nil && s.store != nil
+}
+
+// MaxBytes 单个响应体与单条会话上下文的最大字节数
+func (s *ConversationStoreService) MaxBytes() int {
+	return s.cfg.MaxBytes
+}
+
+// Lookup 读取 response id 对应的上下文；存储出错按不存在处理
+func (s *ConversationStoreService) Lookup(ctx context.Context, apiKeyID int64, responseID string) (*StoredConversation, bool) {
+	responseID = strings.TrimSpace(responseID)
+	if !s.Enabled() || responseID == "" {
+		return nil, false
+	}
+	conv, err := s.store.GetConversation(ctx, conversationStoreKey(apiKeyID, responseID))
+	if err != nil || conv == nil {
+		return nil, false
+	}
+	return conv, true
+}
+
+// ExpandInput 若 reqBody 的 previous_response_id 在本地有记录，将其上下文拼接到 input 之前并移除
+// previous_response_id，返回是否已重建；本地没有记录时 reqBody 保持不变。
+func (s *ConversationStoreService) ExpandInput(ctx context.Context, apiKeyID int64, reqBody map[string]any) bool {
+	if !s.Enabled() || reqBody == nil {
+		return false
+	}
+	previousResponseID, _ := reqBody["previous_response_id"].(string)
+	conv, ok := s.Lookup(ctx, apiKeyID, previousResponseID)
+	if !ok {
+		return false
+	}
+	input := make([]any, 0, len(conv.Items)+1)
+	for _, raw := range conv.Items {
+		var item any
+		if err := json.Unmarshal(raw, &item); err != nil {
+			return false
+		}
+		input = append(input, item)
+	}
+	switch current := reqBody["input"].(type) {
+	case string:
+		input = append(input, map[string]any{"type": "message", "role": "user", "content": current})
+	case []any:
+		input = append(input, current...)
+	}
+	reqBody["input"] = input
+	delete(reqBody, "previous_response_id")
+	return true
+}
+
+// Record 保存一轮成功的 Responses 请求：上下文为上一轮的上下文（若有 previous_response_id）、
+// 本轮 input 与响应 output。responseBody 可以是 JSON 响应或 SSE 流（取 response.completed 事件）。
+// 上一轮不在本地存储中时无法得到完整上下文，本轮不保存。
+func (s *ConversationStoreService) Record(ctx context.Context, apiKeyID int64, requestBody, responseBody []byte) error {
+	if !s.Enabled() || len(responseBody) > s.cfg.MaxBytes {
+		return nil
+	}
+	response := conversationResponseFromBody(responseBody)
+	responseID := strings.TrimSpace(response.Get("id").String())
+	if responseID == "" || !response.Get("output").IsArray() {
+		return nil
+	}
+
+	var items []json.RawMessage
+	if previousResponseID := strings.TrimSpace(gjson.GetBytes(requestBody, "previous_response_id").String()); previousResponseID != "" {
+		conv, ok := s.Lookup(ctx, apiKeyID, previousResponseID)
+		if !ok {
+			return nil
+		}
+		items = append(items, conv.Items...)
+	}
+	input := gjson.GetBytes(requestBody, "input")
+	switch {
+	case input.Type == gjson.String:
+		item, err := json.Marshal(map[string]any{"type": "message", "role": "user", "content": input.String()})
+		if err != nil {
+			return err
+		}
+		items = append(items, item)
+	case input.IsArray():
+		for _, item := range input.Array() {
+			items = append(items, json.RawMessage(item.Raw))
+		}
+	}
+	for _, item := range response.Get("output").Array() {
+		if replay, ok := conversationReplayItem(item); ok {
+			items = append(items, replay)
+		}
+	}
+
+	size := 0
+	for _, item := range items {
+		size += len(item)
+	}
+	if size > s.cfg.MaxBytes {
+		return nil
+	}
+	return s.store.SetConversation(ctx, conversationStoreKey(apiKeyID, responseID), &StoredConversation{Items: items}, time.Duration(s.cfg.TTLSeconds)*time.Second)
+}
+
+func conversationStoreKey(apiKeyID int64, responseID string) string {
+	return strconv.FormatInt(apiKeyID, 10) + ":" + responseID
+}
+
+// conversationResponseFromBody 从 JSON 响应或 SSE 流中取出最终的 response 对象
+func conversationResponseFromBody(body []byte) gjson.Result {
+	trimmed := bytes.TrimSpace(body)
+	if len(trimmed) > 0 && trimmed[0] == '{' {
+		return gjson.ParseBytes(trimmed)
+	}
+	var completed gjson.Result
+	scanner := bufio.NewScanner(bytes.NewReader(body))
+	scanner.Buffer(make([]byte, 0, 64*1024), len(body)+1)
+	for scanner.Scan() {
+		data, ok := strings.CutPrefix(scanner.Text(), "data:")
+		if !ok {
+			continue
+		}
+		event := gjson.Parse(strings.TrimSpace(data))
+		if event.Get("type").String() == "response.completed" {
+			completed = event.Get("response")
+		}
+	}
+	return completed
+}
+
+// conversationReplayItem 将上游 output item 转为可重放的 input item：去掉上游分配的 id
+// （store=false 或换账号后上游无法解析这些 id），不带 encrypted_content 的 reasoning 无法重放，直接丢弃。
+func conversationReplayItem(item gjson.Result) (json.RawMessage, bool) {
+	if !item.IsObject() {
+		return nil, false
+	}
+	if item.Get("type").String() == "reasoning" && item.Get("encrypted_content").String() == "" {
+		return nil, false
+	}
+	raw := []byte(item.Raw)
+	if item.Get("id").Exists() {
+		next, err := sjson.DeleteBytes(raw, "id")
+		if err != nil {
+			return nil, false
+		}
+		raw = next
+	}
+	return json.RawMessage(raw), true
+}
+
+type memoryConversationEntry struct {
+	key       string
+	conv      *StoredConversation
+	expiresAt time.Time
+}
+
+// memoryConversationStore 进程内会话存储，超出容量时淘汰最早写入的条目
+type memoryConversationStore struct {
+	mu         sync.Mutex
+	maxEntries int
+	entries    map[string]*list.Element
+	order      *list.List // 按写入顺序，最早写入在前
+}
+
+func newMemoryConversationStore(maxEntries int) *memoryConversationStore {
+	return &memoryConversationStore{maxEntries: maxEntries, entries: make(map[string]*list.Element), order: list.New()}
+}
+
+func (m *memoryConversationStore) GetConversation(_ context.Context, key string) (*StoredConversation, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	el, ok := m.entries[key]
+	if !ok {
+		return nil, ErrConversationNotFound
+	}
+	entry := el.Value.(*memoryConversationEntry)
+	if !time.Now().Before(entry.expiresAt) {
+		m.order.Remove(el)
+		delete(m.entries, key)
+		return nil, ErrConversationNotFound
+	}
+	return entry.conv, nil
+}
+
+func (m *memoryConversationStore) SetConversation(_ context.Context, key string, conv *StoredConversation, ttl time.Duration) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if el, ok := m.entries[key]; ok {
+		m.order.Remove(el)
+	}
+	m.entries[key] = m.order.PushBack(&memoryConversationEntry{key: key, conv: conv, expiresAt: time.Now().Add(ttl)})
+	for m.maxEntries > 0 && m.order.Len() > m.maxEntries {
+		oldest := m.order.Front()
+		m.order.Remove(oldest)
+		delete(m.entries, oldest.Value.(*memoryConversationEntry).key)
+	}
+	return nil
+}
@@ -0,0 +1,149 @@
+//go:build unit
+
+package service
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ShaohongDong/sub2api/internal/config"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/require"
+	"github.com/tidwall/gjson"
+)
+
+func newTestConversationStoreService() *ConversationStoreService {
+	return NewConversationStoreService(&config.Config{ConversationStore: config.ConversationStoreConfig{
+		Enabled:    true,
+		Backend:    "memory",
+		TTLSeconds: 60,
+		MaxEntries: 100,
+		MaxBytes:   1 << 20,
+	}}, nil)
+}
+
+func TestConversationStoreService_RecordAndExpandChain(t *testing.T) {
+	ctx := context.Background()
+	svc := newTestConversationStoreService()
+
+	first := `{"model":"gpt-5","input":"hi"}`
+	firstResp := `{"id":"resp_1","output":[` +
+		`{"id":"rs_1","type":"reasoning","summary":[]},` +
+		`{"id":"rs_2","type":"reasoning","summary":[],"encrypted_content":"enc"},` +
+		`{"id":"msg_1","type":"message","role":"assistant","content":[{"type":"output_text","text":"hello"}]}]}`
+	require.NoError(t, svc.Record(ctx, 7, []byte(first), []byte(firstResp)))
+
+	// 第二轮为 SSE 响应，上下文应累积第一轮
+	second := `{"model":"gpt-5","previous_response_id":"resp_1","input":[{"type":"message","role":"user","content":"again"}]}`
+	secondResp := "event: response.created\ndata: {\"type\":\"response.created\",\"response\":{\"id\":\"resp_2\",\"output\":[]}}\n\n" +
+		"event: response.completed\ndata: {\"type\":\"response.completed\",\"response\":{\"id\":\"resp_2\",\"output\":[{\"id\":\"msg_2\",\"type\":\"message\",\"role\":\"assistant\",\"content\":[{\"type\":\"output_text\",\"text\":\"hello again\"}]}]}}\n\n"
+	require.NoError(t, svc.Record(ctx, 7, []byte(second), []byte(secondResp)))
+
+	conv, ok := svc.Lookup(ctx, 7, "resp_2")
+	require.True(t, ok)
+	require.Len(t, conv.Items, 5)
+	require.Equal(t, "hi", gjson.GetBytes(conv.Items[0], "content").String())
+	require.Equal(t, "enc", gjson.GetBytes(conv.Items[1], "encrypted_content").String())
+	require.False(t, gjson.GetBytes(conv.Items[1], "id").Exists())
+	require.False(t, gjson.GetBytes(conv.Items[2], "id").Exists())
+	require.Equal(t, "again", gjson.GetBytes(conv.Items[3], "content").String())
+	require.Equal(t, "hello again", gjson.GetBytes(conv.Items[4], "content.0.text").String())
+
+	// 按 API Key 隔离
+	_, ok = svc.Lookup(ctx, 8, "resp_2")
+	require.False(t, ok)
+
+	reqBody := map[string]any{"previous_response_id": "resp_2", "input": "third"}
+	require.True(t, svc.ExpandInput(ctx, 7, reqBody))
+	require.NotContains(t, reqBody, "previous_response_id")
+	input := reqBody["input"].([]any)
+	require.Len(t, input, 6)
+	require.Equal(t, "third", input[5].(map[string]any)["content"])
+
+	unknown := map[string]any{"previous_response_id": "resp_upstream", "input": "x"}
+	require.False(t, svc.ExpandInput(ctx, 7, unknown))
+	require.Equal(t, "resp_upstream", unknown["previous_response_id"])
+}
+
+func TestConversationStoreService_SkipsUnknownChainAndOversize(t *testing.T) {
+	ctx := context.Background()
+	svc := newTestConversationStoreService()
+
+	// 上一轮不在本地时无法得到完整上下文，不保存
+	require.NoError(t, svc.Record(ctx, 1, []byte(`{"previous_response_id":"resp_x","input":"a"}`), []byte(`{"id":"resp_y","output":[]}`)))
+	_, ok := svc.Lookup(ctx, 1, "resp_y")
+	require.False(t, ok)
+
+	svc.cfg.MaxBytes = 32
+	require.NoError(t, svc.Record(ctx, 1, []byte(`{"input":"`+strings.Repeat("a", 64)+`"}`), []byte(`{"id":"resp_z","output":[]}`)))
+	_, ok = svc.Lookup(ctx, 1, "resp_z")
+	require.False(t, ok)
+
+	var disabled *ConversationStoreService
+	require.False(t, disabled.ExpandInput(ctx, 1, map[string]any{"previous_response_id": "resp_1"}))
+}
+
+func TestMemoryConversationStore_ExpiryAndEviction(t *testing.T) {
+	ctx := context.Background()
+	store := newMemoryConversationStore(2)
+	require.NoError(t, store.SetConversation(ctx, "a", &StoredConversation{}, time.Minute))
+	require.NoError(t, store.SetConversation(ctx, "b", &StoredConversation{}, -time.Second))
+	_, err := store.GetConversation(ctx, "b")
+	require.ErrorIs(t, err, ErrConversationNotFound)
+
+	require.NoError(t, store.SetConversation(ctx, "c", &StoredConversation{}, time.Minute))
+	require.NoError(t, store.SetConversation(ctx, "d", &StoredConversation{}, time.Minute))
+	_, err = store.GetConversation(ctx, "a")
+	require.ErrorIs(t, err, ErrConversationNotFound)
+	_, err = store.GetConversation(ctx, "d")
+	require.NoError(t, err)
+}
+
+func TestOpenAIGatewayService_Forward_ExpandsStoredConversationOverHTTP(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	ctx := context.Background()
+	store := newTestConversationStoreService()
+	require.NoError(t, store.Record(ctx, 42, []byte(`{"input":"hi"}`), []byte(`{"id":"resp_1","output":[{"id":"msg_1","type":"message","role":"assistant","content":[{"type":"output_text","text":"hello"}]}]}`)))
+
+	rec := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(rec)
+	c.Request = httptest.NewRequest(http.MethodPost, "/v1/responses", bytes.NewReader(nil))
+	c.Request.Header.Set("Content-Type", "application/json")
+	c.Set("api_key", &APIKey{ID: 42})
+
+	upstream := &httpUpstreamRecorder{
+		resp: &http.Response{
+			StatusCode: http.StatusOK,
+			Header:     http.Header{"Content-Type": []string{"application/json"}},
+			Body:       io.NopCloser(strings.NewReader(`{"id":"resp_2","output":[],"usage":{"input_tokens":1,"output_tokens":1}}`)),
+		},
+	}
+	svc := &OpenAIGatewayService{cfg: &config.Config{}, httpUpstream: upstream}
+	svc.SetConversationStore(store)
+	account := &Account{
+		ID:          1,
+		Name:        "apikey",
+		Platform:    PlatformOpenAI,
+		Type:        AccountTypeAPIKey,
+		Concurrency: 1,
+		Credentials: map[string]any{"api_key": "sk-test"},
+		Status:      StatusActive,
+		Schedulable: true,
+	}
+	body := []byte(`{"model":"gpt-5","stream":false,"previous_response_id":"resp_1","input":"again"}`)
+
+	_, err := svc.Forward(ctx, c, account, body)
+	require.NoError(t, err)
+	require.False(t, gjson.GetBytes(upstream.lastBody, "previous_response_id").Exists())
+	input := gjson.GetBytes(upstream.lastBody, "input").Array()
+	require.Len(t, input, 3)
+	require.Equal(t, "hi", input[0].Get("content").String())
+	require.Equal(t, "hello", input[1].Get("content.0.text").String())
+	require.Equal(t, "again", input[2].Get("content").String())
+}
@@ -0,0 +1,68 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/ShaohongDong/sub2api/internal/pkg/clientdetect"
+	"github.com/ShaohongDong/sub2api/internal/pkg/usagestats"
+)
+
+// GetClientTypeStats 按客户端类型汇总时间范围内的请求数、Token 用量与错误率。
+//
+// 客户端类型取请求处理时记录的 client_type（与实时路由使用同一识别结果，含 originator 等请求头信号）；
+// 该列出现之前的历史数据只能由持久化的 User-Agent 经 clientdetect.Parse 回退归类。
+func (s *DashboardService) GetClientTypeStats(ctx context.Context, startTime, endTime time.Time) ([]usagestats.ClientTypeStat, error) {
+	rows, err := s.usageRepo.GetClientTypeUsage(ctx, startTime, endTime)
+	if err != nil {
+		return nil, fmt.Errorf("get client type usage: %w", err)
+	}
+	return aggregateClientTypeStats(rows), nil
+}
+
+// aggregateClientTypeStats 将按客户端类型（历史数据按 UA）聚合的行归并为客户端类型维度，并按成功请求数降序排列。
+func aggregateClientTypeStats(rows []usagestats.ClientTypeUsageRow) []usagestats.ClientTypeStat {
+	byType := make(map[clientdetect.ClientType]*usagestats.ClientTypeStat)
+	var totalRequests int64
+	for _, row := range rows {
+		clientType := clientdetect.ClientType(row.ClientType)
+		if clientType == "" {
+			clientType = clientdetect.Parse(row.UserAgent).Type
+		}
+		stat, ok := byType[clientType]
+		if !ok {
+			stat = &usagestats.ClientTypeStat{ClientType: string(clientType)}
+			byType[clientType] = stat
+		}
+		stat.Requests += row.Requests
+		stat.Errors += row.Errors
+		stat.InputTokens += row.InputTokens
+		stat.OutputTokens += row.OutputTokens
+		stat.CacheCreationTokens += row.CacheCreationTokens
+		stat.CacheReadTokens += row.CacheReadTokens
+		stat.TotalTokens += row.TotalTokens
+		stat.Cost += row.Cost
+		stat.ActualCost += row.ActualCost
+		totalRequests += row.Requests
+	}
+
+	results := make([]usagestats.ClientTypeStat, 0, len(byType))
+	for _, stat := range byType {
+		if attempts := stat.Requests + stat.Errors; attempts > 0 {
+			stat.ErrorRate = float64(stat.Errors) / float64(attempts)
+		}
+		if totalRequests > 0 {
+			stat.RequestShare = float64(stat.Requests) / float64(totalRequests)
+		}
+		results = append(results, *stat)
+	}
+	sort.Slice(results, func(i, j int) bool {
+		if results[i].Requests != results[j].Requests {
+			return results[i].Requests > results[j].Requests
+		}
+		return results[i].ClientType < results[j].ClientType
+	})
+	return results
+}
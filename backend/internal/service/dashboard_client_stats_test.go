@@ -0,0 +1,56 @@
+package service
+
+import (
+	"testing"
+
+	"github.com/ShaohongDong/sub2api/internal/pkg/clientdetect"
+	"github.com/ShaohongDong/sub2api/internal/pkg/usagestats"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAggregateClientTypeStats(t *testing.T) {
+	rows := []usagestats.ClientTypeUsageRow{
+		{ClientType: string(clientdetect.TypeCodexCLI), Requests: 6, Errors: 2, InputTokens: 100, OutputTokens: 50, TotalTokens: 150, Cost: 1.5},
+		// Legacy rows recorded before client_type existed fall back to the stored UA.
+		{UserAgent: "codex_cli_rs/0.50.0 (Ubuntu 24.04; x86_64)", Requests: 2, InputTokens: 20, OutputTokens: 10, TotalTokens: 30, Cost: 0.3},
+		{ClientType: string(clientdetect.TypeCodexVSCode), Requests: 1, TotalTokens: 5},
+		{ClientType: string(clientdetect.TypeCurl), Requests: 1, Errors: 1, TotalTokens: 3},
+		{ClientType: string(clientdetect.TypeUnknown), Errors: 4},
+	}
+
+	got := aggregateClientTypeStats(rows)
+	require.Len(t, got, 4)
+
+	require.Equal(t, string(clientdetect.TypeCodexCLI), got[0].ClientType)
+	require.Equal(t, int64(8), got[0].Requests)
+	require.Equal(t, int64(2), got[0].Errors)
+	require.Equal(t, int64(180), got[0].TotalTokens)
+	require.InDelta(t, 1.8, got[0].Cost, 1e-9)
+	require.InDelta(t, 0.2, got[0].ErrorRate, 1e-9)
+	require.InDelta(t, 0.8, got[0].RequestShare, 1e-9)
+
+	require.Equal(t, string(clientdetect.TypeCodexVSCode), got[1].ClientType)
+	require.Equal(t, string(clientdetect.TypeCurl), got[2].ClientType)
+	require.InDelta(t, 0.5, got[2].ErrorRate, 1e-9)
+
+	require.Equal(t, string(clientdetect.TypeUnknown), got[3].ClientType)
+	require.Equal(t, int64(0), got[3].Requests)
+	require.InDelta(t, 1.0, got[3].ErrorRate, 1e-9)
+
+	require.Empty(t, aggregateClientTypeStats(nil))
+}
+
+func TestAggregateClientTypeStats_PrefersRecordedClientType(t *testing.T) {
+	// A Codex CLI request behind a proxy that rewrote the UA: the live path
+	// classified it from originator headers, so the stored type wins over the UA.
+	rows := []usagestats.ClientTypeUsageRow{
+		{ClientType: string(clientdetect.TypeCodexCLI), UserAgent: "", Requests: 3},
+		{UserAgent: "Mozilla/5.0 corporate-proxy", Requests: 1},
+	}
+
+	got := aggregateClientTypeStats(rows)
+	require.Len(t, got, 2)
+	require.Equal(t, string(clientdetect.TypeCodexCLI), got[0].ClientType)
+	require.Equal(t, int64(3), got[0].Requests)
+	require.Equal(t, string(clientdetect.Parse("Mozilla/5.0 corporate-proxy").Type), got[1].ClientType)
+}
@@ -0,0 +1,118 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/ShaohongDong/sub2api/internal/pkg/timezone"
+	"github.com/ShaohongDong/sub2api/internal/pkg/usagestats"
+	"golang.org/x/sync/errgroup"
+)
+
+const (
+	DefaultDashboardOverviewTopN = 5
+	MaxDashboardOverviewTopN     = 20
+)
+
+// GetOverview 汇总今日请求量、Token、费用、错误率、可用账号数及 Top 模型/API Key。
+//
+// 各项查询并发执行：核心指标复用仪表盘统计缓存，错误数与 Top 榜单各一次聚合查询，
+// 前端刷新时只需调用一次。topN 超出范围时取默认值或上限。
+func (s *DashboardService) GetOverview(ctx context.Context, topN int) (*usagestats.DashboardOverview, error) {
+	if topN <= 0 {
+		topN = DefaultDashboardOverviewTopN
+	} else if topN > MaxDashboardOverviewTopN {
+		topN = MaxDashboardOverviewTopN
+	}
+
+	todayStart := timezone.Today()
+	todayEnd := todayStart.AddDate(0, 0, 1)
+	query := usagestats.UsageAggregateQuery{StartTime: todayStart, EndTime: todayEnd, Limit: topN}
+
+	var (
+		stats      *usagestats.DashboardStats
+		errorCount int64
+		models     []usagestats.UsageAggregate
+		apiKeys    []usagestats.UsageAggregate
+	)
+	g, gctx := errgroup.WithContext(ctx)
+	g.Go(func() error {
+		var err error
+		stats, err = s.GetDashboardStats(gctx)
+		return err
+	})
+	g.Go(func() error {
+		var err error
+		if errorCount, err = s.usageRepo.CountErrorRequests(gctx, todayStart, todayEnd); err != nil {
+			return fmt.Errorf("count error requests: %w", err)
+		}
+		return nil
+	})
+	g.Go(func() error {
+		q := query
+		q.GroupBy = []string{usagestats.UsageAggregateByModel}
+		var err error
+		models, err = s.GetUsageAggregates(gctx, q)
+		return err
+	})
+	g.Go(func() error {
+		q := query
+		q.GroupBy = []string{usagestats.UsageAggregateByAPIKey}
+		var err error
+		apiKeys, err = s.GetUsageAggregates(gctx, q)
+		return err
+	})
+	if err := g.Wait(); err != nil {
+		return nil, fmt.Errorf("get dashboard overview: %w", err)
+	}
+
+	return buildDashboardOverview(stats, errorCount, models, apiKeys, todayStart), nil
+}
+
+func buildDashboardOverview(stats *usagestats.DashboardStats, errorCount int64, models, apiKeys []usagestats.UsageAggregate, today time.Time) *usagestats.DashboardOverview {
+	overview := &usagestats.DashboardOverview{
+		GeneratedAt:         time.Now().UTC().Format(time.RFC3339),
+		Date:                today.Format("2006-01-02"),
+		Requests:            stats.TodayRequests,
+		Errors:              errorCount,
+		InputTokens:         stats.TodayInputTokens,
+		OutputTokens:        stats.TodayOutputTokens,
+		CacheCreationTokens: stats.TodayCacheCreationTokens,
+		CacheReadTokens:     stats.TodayCacheReadTokens,
+		TotalTokens:         stats.TodayTokens,
+		Cost:                stats.TodayCost,
+		ActualCost:          stats.TodayActualCost,
+		ActiveAccounts:      stats.NormalAccounts,
+		TotalAccounts:       stats.TotalAccounts,
+		ActiveUsers:         stats.ActiveUsers,
+		Rpm:                 stats.Rpm,
+		Tpm:                 stats.Tpm,
+		StatsStale:          stats.StatsStale,
+		TopModels:           make([]usagestats.DashboardOverviewModel, 0, len(models)),
+		TopAPIKeys:          make([]usagestats.DashboardOverviewAPIKey, 0, len(apiKeys)),
+	}
+	if attempts := overview.Requests + errorCount; attempts > 0 {
+		overview.ErrorRate = float64(errorCount) / float64(attempts)
+	}
+	for _, m := range models {
+		overview.TopModels = append(overview.TopModels, usagestats.DashboardOverviewModel{
+			Model:       m.Model,
+			Requests:    m.Requests,
+			TotalTokens: m.TotalTokens,
+			Cost:        m.Cost,
+			ActualCost:  m.ActualCost,
+		})
+	}
+	for _, k := range apiKeys {
+		overview.TopAPIKeys = append(overview.TopAPIKeys, usagestats.DashboardOverviewAPIKey{
+			APIKeyID:    k.APIKeyID,
+			APIKeyName:  k.APIKeyName,
+			Requests:    k.Requests,
+			TotalTokens: k.TotalTokens,
+			Cost:        k.Cost,
+			ActualCost:  k.ActualCost,
+		})
+	}
+	return overview
+}
@@ -0,0 +1,97 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ShaohongDong/sub2api/internal/pkg/usagestats"
+	"github.com/stretchr/testify/require"
+)
+
+type overviewUsageRepoStub struct {
+	usageRepoStub
+	errorCount int64
+	errorErr   error
+
+	mu      sync.Mutex
+	queries []usagestats.UsageAggregateQuery
+}
+
+func (s *overviewUsageRepoStub) CountErrorRequests(ctx context.Context, startTime, endTime time.Time) (int64, error) {
+	return s.errorCount, s.errorErr
+}
+
+func (s *overviewUsageRepoStub) GetUsageAggregates(ctx context.Context, query usagestats.UsageAggregateQuery) ([]usagestats.UsageAggregate, error) {
+	s.mu.Lock()
+	s.queries = append(s.queries, query)
+	s.mu.Unlock()
+	switch query.GroupBy[0] {
+	case usagestats.UsageAggregateByModel:
+		return []usagestats.UsageAggregate{
+			{Model: "claude-sonnet-4", Requests: 6, TotalTokens: 900, Cost: 1.2, ActualCost: 1.0},
+			{Model: "gpt-5", Requests: 2, TotalTokens: 100, Cost: 0.3, ActualCost: 0.3},
+		}, nil
+	default:
+		return []usagestats.UsageAggregate{{APIKeyID: 7, APIKeyName: "ci", Requests: 8, TotalTokens: 1000, Cost: 1.5, ActualCost: 1.3}}, nil
+	}
+}
+
+func TestDashboardService_GetOverview(t *testing.T) {
+	repo := &overviewUsageRepoStub{
+		usageRepoStub: usageRepoStub{stats: &usagestats.DashboardStats{
+			TodayRequests:  8,
+			TodayTokens:    1000,
+			TodayCost:      1.5,
+			NormalAccounts: 3,
+			TotalAccounts:  4,
+			ActiveUsers:    2,
+		}},
+		errorCount: 2,
+	}
+	svc := NewDashboardService(repo, nil, nil, nil)
+
+	overview, err := svc.GetOverview(context.Background(), 0)
+	require.NoError(t, err)
+	require.Equal(t, int64(8), overview.Requests)
+	require.Equal(t, int64(2), overview.Errors)
+	require.InDelta(t, 0.2, overview.ErrorRate, 1e-9)
+	require.Equal(t, int64(1000), overview.TotalTokens)
+	require.Equal(t, int64(3), overview.ActiveAccounts)
+	require.Len(t, overview.TopModels, 2)
+	require.Equal(t, "claude-sonnet-4", overview.TopModels[0].Model)
+	require.Len(t, overview.TopAPIKeys, 1)
+	require.Equal(t, "ci", overview.TopAPIKeys[0].APIKeyName)
+
+	require.Len(t, repo.queries, 2)
+	for _, q := range repo.queries {
+		require.Equal(t, DefaultDashboardOverviewTopN, q.Limit)
+		require.Equal(t, q.StartTime.AddDate(0, 0, 1), q.EndTime)
+	}
+
+	repo.queries = nil
+	_, err = svc.GetOverview(context.Background(), 100)
+	require.NoError(t, err)
+	require.Equal(t, MaxDashboardOverviewTopN, repo.queries[0].Limit)
+}
+
+func TestDashboardService_GetOverviewError(t *testing.T) {
+	repo := &overviewUsageRepoStub{
+		usageRepoStub: usageRepoStub{stats: &usagestats.DashboardStats{}},
+		errorErr:      errors.New("db down"),
+	}
+	svc := NewDashboardService(repo, nil, nil, nil)
+
+	_, err := svc.GetOverview(context.Background(), 5)
+	require.ErrorContains(t, err, "db down")
+}
+
+func TestBuildDashboardOverviewNoTraffic(t *testing.T) {
+	overview := buildDashboardOverview(&usagestats.DashboardStats{}, 0, nil, nil, time.Date(2026, 3, 4, 0, 0, 0, 0, time.UTC))
+	require.Equal(t, "2026-03-04", overview.Date)
+	require.Zero(t, overview.ErrorRate)
+	require.NotNil(t, overview.TopModels)
+	require.NotNil(t, overview.TopAPIKeys)
+}
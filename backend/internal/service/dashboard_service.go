@@ -9,6 +9,7 @@ import (
 	"time"
 
 	"github.com/ShaohongDong/sub2api/internal/config"
+	infraerrors "github.com/ShaohongDong/sub2api/internal/pkg/errors"
 	"github.com/ShaohongDong/sub2api/internal/pkg/logger"
 	"github.com/ShaohongDong/sub2api/internal/pkg/usagestats"
 )
@@ -148,6 +149,66 @@ func (s *DashboardService) GetGroupStatsWithFilters(ctx context.Context, startTi
 	return stats, nil
 }
 
+const (
+	defaultUsageAggregateLimit = 1000
+	maxUsageAggregateLimit     = 10000
+)
+
+var (
+	ErrUsageAggregateInvalidGroupBy     = infraerrors.BadRequest("USAGE_AGGREGATE_INVALID_GROUP_BY", "group_by must be a comma-separated list of: api_key, model, account, user, group")
+	ErrUsageAggregateInvalidGranularity = infraerrors.BadRequest("USAGE_AGGREGATE_INVALID_GRANULARITY", "granularity must be one of: hour, day, week, month")
+	ErrUsageAggregateInvalidRange       = infraerrors.BadRequest("USAGE_AGGREGATE_INVALID_RANGE", "end time must be after start time")
+)
+
+// GetUsageAggregates 按 Key/模型/账号/用户/分组与时间桶的任意组合聚合用量（仪表盘与分摊报表）。
+// 重复维度会被去重；Limit 为 0 时取默认值，超过上限时截断。
+func (s *DashboardService) GetUsageAggregates(ctx context.Context, query usagestats.UsageAggregateQuery) ([]usagestats.UsageAggregate, error) {
+	query, err := normalizeUsageAggregateQuery(query)
+	if err != nil {
+		return nil, err
+	}
+	if query.Limit <= 0 {
+		query.Limit = defaultUsageAggregateLimit
+	} else if query.Limit > maxUsageAggregateLimit {
+		query.Limit = maxUsageAggregateLimit
+	}
+
+	aggregates, err := s.usageRepo.GetUsageAggregates(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("get usage aggregates: %w", err)
+	}
+	return aggregates, nil
+}
+
+// normalizeUsageAggregateQuery 校验时间范围、时间桶与维度，并对维度去重
+func normalizeUsageAggregateQuery(query usagestats.UsageAggregateQuery) (usagestats.UsageAggregateQuery, error) {
+	if !query.EndTime.After(query.StartTime) {
+		return query, ErrUsageAggregateInvalidRange
+	}
+	switch query.Granularity {
+	case "", "hour", "day", "week", "month":
+	default:
+		return query, ErrUsageAggregateInvalidGranularity
+	}
+	groupBy := make([]string, 0, len(query.GroupBy))
+	seen := make(map[string]struct{}, len(query.GroupBy))
+	for _, dim := range query.GroupBy {
+		switch dim {
+		case usagestats.UsageAggregateByAPIKey, usagestats.UsageAggregateByModel, usagestats.UsageAggregateByAccount,
+			usagestats.UsageAggregateByUser, usagestats.UsageAggregateByGroup:
+		default:
+			return query, ErrUsageAggregateInvalidGroupBy
+		}
+		if _, ok := seen[dim]; ok {
+			continue
+		}
+		seen[dim] = struct{}{}
+		groupBy = append(groupBy, dim)
+	}
+	query.GroupBy = groupBy
+	return query, nil
+}
+
 func (s *DashboardService) getCachedDashboardStats(ctx context.Context) (*usagestats.DashboardStats, bool, error) {
 	data, err := s.cache.GetDashboardStats(ctx)
 	if err != nil {
@@ -0,0 +1,392 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ShaohongDong/sub2api/internal/config"
+	infraerrors "github.com/ShaohongDong/sub2api/internal/pkg/errors"
+	"github.com/ShaohongDong/sub2api/internal/pkg/logger"
+	"go.uber.org/zap"
+)
+
+// 死信状态
+const (
+	DeadLetterStatusPending   = "pending"   // 待处理
+	DeadLetterStatusReplaying = "replaying" // 重放中
+	DeadLetterStatusSucceeded = "succeeded" // 重放成功，响应体已保存
+	DeadLetterStatusFailed    = "failed"    // 重放失败，可再次重放
+	DeadLetterStatusDiscarded = "discarded" // 已丢弃
+)
+
+const (
+	// deadLetterWriteTimeout 死信写入超时；与请求 context 解耦，客户端断开也要落库
+	deadLetterWriteTimeout = 5 * time.Second
+	// deadLetterCleanupInterval 过期记录清理与卡住的重放回收间隔
+	deadLetterCleanupInterval = time.Hour
+	// deadLetterStaleReplayAfter 重放状态超过该时长仍未结束（实例重启等）视为失败
+	deadLetterStaleReplayAfter = 10 * time.Minute
+	// DeadLetterMaxReplayBatch 单次重放 / 丢弃的最大条目数
+	DeadLetterMaxReplayBatch = 100
+)
+
+var (
+	ErrDeadLetterNotFound     = infraerrors.NotFound("DEAD_LETTER_NOT_FOUND", "dead letter not found")
+	ErrDeadLetterInvalidBatch = infraerrors.BadRequest("DEAD_LETTER_INVALID_BATCH",
+		fmt.Sprintf("ids must contain 1 to %d entries", DeadLetterMaxReplayBatch))
+	ErrDeadLetterReplayUnavailable = infraerrors.ServiceUnavailable("DEAD_LETTER_REPLAY_UNAVAILABLE", "dead letter replay is not available")
+)
+
+// DeadLetterRequest 重试与账号切换均失败的网关请求
+type DeadLetterRequest struct {
+	ID              int64  `json:"id"`
+	RequestID       string `json:"request_id,omitempty"`
+	ClientRequestID string `json:"client_request_id,omitempty"`
+	UserID          *int64 `json:"user_id,omitempty"`
+	UserEmail       string `json:"user_email,omitempty"`
+	APIKeyID        *int64 `json:"api_key_id,omitempty"`
+	GroupID         *int64 `json:"group_id,omitempty"`
+	// AccountID 最后一次失败的上游账号
+	AccountID   *int64 `json:"account_id,omitempty"`
+	Platform    string `json:"platform"`
+	Model       string `json:"model"`
+	RequestPath string `json:"request_path"`
+	Stream      bool   `json:"stream"`
+	UserAgent   string `json:"user_agent,omitempty"`
+	// RequestHeaders 重放所需的白名单请求头（JSON），不含凭证
+	RequestHeaders string `json:"request_headers,omitempty"`
+	// RequestBody 完整请求体；超过 dead_letter.max_body_bytes 时为空，无法重放
+	RequestBody      string `json:"request_body,omitempty"`
+	RequestBodyBytes int    `json:"request_body_bytes"`
+
+	StatusCode         int    `json:"status_code"`
+	UpstreamStatusCode int    `json:"upstream_status_code,omitempty"`
+	AttemptCount       int    `json:"attempt_count"`
+	FailureReason      string `json:"failure_reason"`
+
+	Status               string     `json:"status"`
+	ReplayCount          int        `json:"replay_count"`
+	LastReplayAt         *time.Time `json:"last_replay_at,omitempty"`
+	LastReplayByUserID   *int64     `json:"last_replay_by_user_id,omitempty"`
+	LastReplayAccountID  *int64     `json:"last_replay_account_id,omitempty"`
+	LastReplayStatusCode int        `json:"last_replay_status_code,omitempty"`
+	LastReplayError      string     `json:"last_replay_error,omitempty"`
+	// ResponseBody 重放成功时的响应体（流式请求为 SSE 文本），最多保存 64KB
+	ResponseBody string `json:"response_body,omitempty"`
+
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// DeadLetterFilter 死信查询条件，零值字段不参与过滤
+type DeadLetterFilter struct {
+	StartTime *time.Time
+	EndTime   *time.Time
+	Status    string
+	UserID    int64
+	APIKeyID  int64
+	GroupID   int64
+	AccountID int64
+	Model     string
+	Page      int
+	PageSize  int
+}
+
+// DeadLetterList 死信分页结果（列表不含请求体与响应体）
+type DeadLetterList struct {
+	Items    []*DeadLetterRequest `json:"items"`
+	Total    int64                `json:"total"`
+	Page     int                  `json:"page"`
+	PageSize int                  `json:"page_size"`
+}
+
+// DeadLetterReplayOutcome 单条死信的重放结果
+type DeadLetterReplayOutcome struct {
+	ID           int64
+	Status       string
+	AccountID    *int64
+	StatusCode   int
+	Error        string
+	ResponseBody string
+}
+
+// DeadLetterReplayResult 重放请求的受理结果；重放在后台执行，结果通过查询接口查看
+type DeadLetterReplayResult struct {
+	Accepted []int64 `json:"accepted"`
+	// Skipped 不存在、正在重放、已成功 / 丢弃或未保存请求体的条目
+	Skipped []int64 `json:"skipped"`
+}
+
+// DeadLetterRepository 死信存储
+type DeadLetterRepository interface {
+	Insert(ctx context.Context, entry *DeadLetterRequest) error
+	GetByID(ctx context.Context, id int64) (*DeadLetterRequest, error)
+	List(ctx context.Context, filter *DeadLetterFilter) (*DeadLetterList, error)
+	// ClaimForReplay 将 pending / failed 且保存了请求体的条目置为 replaying，返回认领成功的 ID
+	ClaimForReplay(ctx context.Context, ids []int64, operatorID int64) ([]int64, error)
+	FinishReplay(ctx context.Context, outcome *DeadLetterReplayOutcome) error
+	// Discard 丢弃未在重放中的条目，返回影响行数
+	Discard(ctx context.Context, ids []int64) (int64, error)
+	DeleteCreatedBefore(ctx context.Context, cutoff time.Time) (int64, error)
+	// FailStaleReplays 将重放开始时间早于 startedBefore 仍处于 replaying 的条目置为 failed
+	FailStaleReplays(ctx context.Context, startedBefore time.Time) (int64, error)
+}
+
+// DeadLetterService 记录重试耗尽的网关请求，并支持在账号恢复后重放。
+// 重放复用运维重试的执行逻辑（按原分组重新选择账号），与运维重试一样不计费。
+type DeadLetterService struct {
+	repo       DeadLetterRepository
+	opsService *OpsService
+	cfg        config.DeadLetterConfig
+
+	replaySem chan struct{}
+	ctx       context.Context
+	cancel    context.CancelFunc
+	stopOnce  sync.Once
+	wg        sync.WaitGroup
+}
+
+// NewDeadLetterService 创建死信服务
+func NewDeadLetterService(repo DeadLetterRepository, opsService *OpsService, cfg *config.Config) *DeadLetterService {
+	var dlCfg config.DeadLetterConfig
+	if cfg != nil {
+		dlCfg = cfg.DeadLetter
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	return &DeadLetterService{
+		repo:       repo,
+		opsService: opsService,
+		cfg:        dlCfg,
+		replaySem:  make(chan struct{}, max(dlCfg.ReplayConcurrency, 1)),
+		ctx:        ctx,
+		cancel:     cancel,
+	}
+}
+
+// Enabled 是否记录死信
+func (s *DeadLetterService) Enabled() bool {
+	return s != nil && s.repo != nil && s.cfg.Enabled
+}
+
+// MaxBodyBytes 保存请求体的最大字节数
+func (s *DeadLetterService) MaxBodyBytes() int {
+	return s.cfg.MaxBodyBytes
+}
+
+// Start 启动过期清理
+func (s *DeadLetterService) Start() {
+	if !s.Enabled() {
+		return
+	}
+	s.wg.Add(1)
+	go func() {
+		defer s.wg.Done()
+		ticker := time.NewTicker(deadLetterCleanupInterval)
+		defer ticker.Stop()
+		s.cleanup()
+		for {
+			select {
+			case <-ticker.C:
+				s.cleanup()
+			case <-s.ctx.Done():
+				return
+			}
+		}
+	}()
+}
+
+// Stop 停止清理并中断进行中的重放（中断的条目记为失败）
+func (s *DeadLetterService) Stop() {
+	if s == nil {
+		return
+	}
+	s.stopOnce.Do(s.cancel)
+	s.wg.Wait()
+}
+
+func (s *DeadLetterService) cleanup() {
+	ctx, cancel := context.WithTimeout(s.ctx, time.Minute)
+	defer cancel()
+	now := time.Now()
+	if n, err := s.repo.DeleteCreatedBefore(ctx, now.AddDate(0, 0, -s.cfg.RetentionDays)); err != nil {
+		logger.L().Warn("dead_letter.cleanup_failed", zap.Error(err))
+	} else if n > 0 {
+		logger.L().Info("dead_letter.cleanup", zap.Int64("deleted", n))
+	}
+	if _, err := s.repo.FailStaleReplays(ctx, now.Add(-deadLetterStaleReplayAfter)); err != nil {
+		logger.L().Warn("dead_letter.fail_stale_replays_failed", zap.Error(err))
+	}
+}
+
+// DeadLetterReplayable 判断请求能否由重放执行器处理：
+// Anthropic 格式的 /messages（非 OpenAI 分组）、OpenAI 分组的 /responses 与 Gemini 原生 /v1beta 接口。
+func DeadLetterReplayable(platform, path string) bool {
+	switch detectOpsRetryType(path) {
+	case opsRetryTypeOpenAI:
+		return platform == PlatformOpenAI
+	case opsRetryTypeGeminiV1B:
+		return platform == PlatformGemini || platform == PlatformAntigravity
+	default:
+		return strings.HasSuffix(path, "/messages") && platform != PlatformOpenAI
+	}
+}
+
+// Record 写入一条死信；失败只记录日志，不影响已返回给客户端的响应
+func (s *DeadLetterService) Record(ctx context.Context, entry *DeadLetterRequest) {
+	if !s.Enabled() || entry == nil {
+		return
+	}
+	entry.Status = DeadLetterStatusPending
+	writeCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), deadLetterWriteTimeout)
+	defer cancel()
+	if err := s.repo.Insert(writeCtx, entry); err != nil {
+		logger.FromContext(ctx).Error("dead_letter.insert_failed",
+			zap.Error(err),
+			zap.String("request_path", entry.RequestPath),
+			zap.String("model", entry.Model),
+		)
+	}
+}
+
+// List 分页查询死信（按时间倒序）
+func (s *DeadLetterService) List(ctx context.Context, filter *DeadLetterFilter) (*DeadLetterList, error) {
+	filter.Status = strings.ToLower(strings.TrimSpace(filter.Status))
+	switch filter.Status {
+	case "", DeadLetterStatusPending, DeadLetterStatusReplaying, DeadLetterStatusSucceeded, DeadLetterStatusFailed, DeadLetterStatusDiscarded:
+	default:
+		return nil, infraerrors.BadRequest("DEAD_LETTER_INVALID_STATUS", "invalid status")
+	}
+	filter.Model = strings.TrimSpace(filter.Model)
+	return s.repo.List(ctx, filter)
+}
+
+// Get 返回单条死信（含请求体与重放响应体）
+func (s *DeadLetterService) Get(ctx context.Context, id int64) (*DeadLetterRequest, error) {
+	return s.repo.GetByID(ctx, id)
+}
+
+// Discard 丢弃选中的死信
+func (s *DeadLetterService) Discard(ctx context.Context, ids []int64) (int64, error) {
+	ids, err := normalizeDeadLetterIDs(ids)
+	if err != nil {
+		return 0, err
+	}
+	return s.repo.Discard(ctx, ids)
+}
+
+// Replay 认领选中的死信并在后台重放；同时执行的重放数受 dead_letter.replay_concurrency 限制
+func (s *DeadLetterService) Replay(ctx context.Context, ids []int64, operatorID int64) (*DeadLetterReplayResult, error) {
+	if s.opsService == nil {
+		return nil, ErrDeadLetterReplayUnavailable
+	}
+	ids, err := normalizeDeadLetterIDs(ids)
+	if err != nil {
+		return nil, err
+	}
+	claimed, err := s.repo.ClaimForReplay(ctx, ids, operatorID)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &DeadLetterReplayResult{Accepted: claimed, Skipped: []int64{}}
+	accepted := make(map[int64]struct{}, len(claimed))
+	for _, id := range claimed {
+		accepted[id] = struct{}{}
+	}
+	for _, id := range ids {
+		if _, ok := accepted[id]; !ok {
+			result.Skipped = append(result.Skipped, id)
+		}
+	}
+
+	for _, id := range claimed {
+		s.wg.Add(1)
+		go func(id int64) {
+			defer s.wg.Done()
+			s.replayOne(id)
+		}(id)
+	}
+	return result, nil
+}
+
+func (s *DeadLetterService) replayOne(id int64) {
+	outcome := &DeadLetterReplayOutcome{ID: id, Status: DeadLetterStatusFailed}
+	defer func() {
+		finishCtx, cancel := context.WithTimeout(context.Background(), deadLetterWriteTimeout)
+		defer cancel()
+		if err := s.repo.FinishReplay(finishCtx, outcome); err != nil {
+			logger.L().Error("dead_letter.finish_replay_failed", zap.Int64("id", id), zap.Error(err))
+		}
+	}()
+
+	select {
+	case s.replaySem <- struct{}{}:
+		defer func() { <-s.replaySem }()
+	case <-s.ctx.Done():
+		outcome.Error = "replay interrupted"
+		return
+	}
+
+	entry, err := s.repo.GetByID(s.ctx, id)
+	if err != nil {
+		outcome.Error = err.Error()
+		return
+	}
+
+	replayCtx, cancel := context.WithTimeout(s.ctx, opsRetryTimeout)
+	defer cancel()
+	exec := s.opsService.executeRetry(replayCtx, deadLetterRetryLog(entry), OpsRetryModeClient, nil)
+	if exec == nil {
+		outcome.Error = "replay returned no result"
+		return
+	}
+	outcome.AccountID = exec.usedAccountID
+	outcome.StatusCode = exec.httpStatusCode
+	if exec.status == opsRetryStatusSucceeded {
+		outcome.Status = DeadLetterStatusSucceeded
+		outcome.ResponseBody = exec.responseBody
+		return
+	}
+	outcome.Error = exec.errorMessage
+	if s.ctx.Err() != nil {
+		outcome.Error = "replay interrupted"
+	}
+}
+
+// deadLetterRetryLog 将死信转换为运维重试执行器使用的错误日志结构
+func deadLetterRetryLog(entry *DeadLetterRequest) *OpsErrorLogDetail {
+	detail := &OpsErrorLogDetail{
+		UserAgent:      entry.UserAgent,
+		RequestBody:    entry.RequestBody,
+		RequestHeaders: entry.RequestHeaders,
+	}
+	detail.GroupID = entry.GroupID
+	detail.AccountID = entry.AccountID
+	detail.Model = entry.Model
+	detail.Stream = entry.Stream
+	detail.RequestPath = entry.RequestPath
+	detail.Platform = entry.Platform
+	return detail
+}
+
+func normalizeDeadLetterIDs(ids []int64) ([]int64, error) {
+	seen := make(map[int64]struct{}, len(ids))
+	out := make([]int64, 0, len(ids))
+	for _, id := range ids {
+		if id <= 0 {
+			return nil, ErrDeadLetterInvalidBatch
+		}
+		if _, ok := seen[id]; ok {
+			continue
+		}
+		seen[id] = struct{}{}
+		out = append(out, id)
+	}
+	if len(out) == 0 || len(out) > DeadLetterMaxReplayBatch {
+		return nil, ErrDeadLetterInvalidBatch
+	}
+	return out, nil
+}
@@ -0,0 +1,162 @@
+//go:build unit
+
+package service
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ShaohongDong/sub2api/internal/config"
+	infraerrors "github.com/ShaohongDong/sub2api/internal/pkg/errors"
+	"github.com/stretchr/testify/require"
+)
+
+type deadLetterRepoStub struct {
+	mu       sync.Mutex
+	entries  map[int64]*DeadLetterRequest
+	outcomes map[int64]*DeadLetterReplayOutcome
+}
+
+func newDeadLetterRepoStub(entries ...*DeadLetterRequest) *deadLetterRepoStub {
+	repo := &deadLetterRepoStub{entries: map[int64]*DeadLetterRequest{}, outcomes: map[int64]*DeadLetterReplayOutcome{}}
+	for _, e := range entries {
+		repo.entries[e.ID] = e
+	}
+	return repo
+}
+
+func (r *deadLetterRepoStub) Insert(ctx context.Context, entry *DeadLetterRequest) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	entry.ID = int64(len(r.entries) + 1)
+	r.entries[entry.ID] = entry
+	return nil
+}
+
+func (r *deadLetterRepoStub) GetByID(ctx context.Context, id int64) (*DeadLetterRequest, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if e, ok := r.entries[id]; ok {
+		cp := *e
+		return &cp, nil
+	}
+	return nil, ErrDeadLetterNotFound
+}
+
+func (r *deadLetterRepoStub) List(ctx context.Context, filter *DeadLetterFilter) (*DeadLetterList, error) {
+	panic("unexpected List call")
+}
+
+func (r *deadLetterRepoStub) ClaimForReplay(ctx context.Context, ids []int64, operatorID int64) ([]int64, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var claimed []int64
+	for _, id := range ids {
+		e, ok := r.entries[id]
+		if !ok || e.RequestBody == "" || (e.Status != DeadLetterStatusPending && e.Status != DeadLetterStatusFailed) {
+			continue
+		}
+		e.Status = DeadLetterStatusReplaying
+		e.LastReplayByUserID = &operatorID
+		claimed = append(claimed, id)
+	}
+	return claimed, nil
+}
+
+func (r *deadLetterRepoStub) FinishReplay(ctx context.Context, outcome *DeadLetterReplayOutcome) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.outcomes[outcome.ID] = outcome
+	r.entries[outcome.ID].Status = outcome.Status
+	return nil
+}
+
+func (r *deadLetterRepoStub) outcome(id int64) *DeadLetterReplayOutcome {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.outcomes[id]
+}
+
+func (r *deadLetterRepoStub) Discard(ctx context.Context, ids []int64) (int64, error) {
+	panic("unexpected Discard call")
+}
+
+func (r *deadLetterRepoStub) DeleteCreatedBefore(ctx context.Context, cutoff time.Time) (int64, error) {
+	return 0, nil
+}
+
+func (r *deadLetterRepoStub) FailStaleReplays(ctx context.Context, startedBefore time.Time) (int64, error) {
+	return 0, nil
+}
+
+func newDeadLetterServiceForTest(repo DeadLetterRepository) *DeadLetterService {
+	cfg := &config.Config{DeadLetter: config.DeadLetterConfig{Enabled: true, MaxBodyBytes: 1 << 20, RetentionDays: 7, ReplayConcurrency: 2}}
+	return NewDeadLetterService(repo, &OpsService{}, cfg)
+}
+
+func TestDeadLetterService_ReplayClaimsAndSkips(t *testing.T) {
+	repo := newDeadLetterRepoStub(
+		// 无分组：执行器无法重新选择账号，重放失败
+		&DeadLetterRequest{ID: 1, Status: DeadLetterStatusPending, RequestPath: "/v1/messages", RequestBody: `{"model":"m"}`},
+		&DeadLetterRequest{ID: 2, Status: DeadLetterStatusSucceeded, RequestPath: "/v1/messages", RequestBody: `{}`},
+		&DeadLetterRequest{ID: 3, Status: DeadLetterStatusPending, RequestPath: "/v1/messages"},
+	)
+	svc := newDeadLetterServiceForTest(repo)
+
+	result, err := svc.Replay(context.Background(), []int64{1, 2, 3, 1, 99}, 7)
+	require.NoError(t, err)
+	require.Equal(t, []int64{1}, result.Accepted)
+	require.Equal(t, []int64{2, 3, 99}, result.Skipped)
+
+	require.Eventually(t, func() bool { return repo.outcome(1) != nil }, 5*time.Second, 10*time.Millisecond)
+	svc.Stop()
+	outcome := repo.outcome(1)
+	require.Equal(t, DeadLetterStatusFailed, outcome.Status)
+	require.Contains(t, outcome.Error, "group_id missing")
+	require.Equal(t, DeadLetterStatusFailed, repo.entries[1].Status)
+}
+
+func TestDeadLetterService_ReplayRejectsInvalidBatch(t *testing.T) {
+	svc := newDeadLetterServiceForTest(newDeadLetterRepoStub())
+	defer svc.Stop()
+
+	_, err := svc.Replay(context.Background(), nil, 1)
+	require.Equal(t, "DEAD_LETTER_INVALID_BATCH", infraerrors.Reason(err))
+
+	ids := make([]int64, DeadLetterMaxReplayBatch+1)
+	for i := range ids {
+		ids[i] = int64(i + 1)
+	}
+	_, err = svc.Replay(context.Background(), ids, 1)
+	require.Equal(t, "DEAD_LETTER_INVALID_BATCH", infraerrors.Reason(err))
+
+	_, err = svc.Replay(context.Background(), []int64{0}, 1)
+	require.Equal(t, "DEAD_LETTER_INVALID_BATCH", infraerrors.Reason(err))
+}
+
+func TestDeadLetterService_RecordSetsPending(t *testing.T) {
+	repo := newDeadLetterRepoStub()
+	svc := newDeadLetterServiceForTest(repo)
+	defer svc.Stop()
+
+	svc.Record(context.Background(), &DeadLetterRequest{RequestPath: "/v1/messages", Status: DeadLetterStatusSucceeded})
+	require.Equal(t, DeadLetterStatusPending, repo.entries[1].Status)
+
+	disabled := NewDeadLetterService(repo, nil, &config.Config{})
+	require.False(t, disabled.Enabled())
+	disabled.Record(context.Background(), &DeadLetterRequest{})
+	require.Len(t, repo.entries, 1)
+}
+
+func TestDeadLetterReplayable(t *testing.T) {
+	require.True(t, DeadLetterReplayable(PlatformAnthropic, "/v1/messages"))
+	require.True(t, DeadLetterReplayable(PlatformAntigravity, "/antigravity/v1/messages"))
+	require.False(t, DeadLetterReplayable(PlatformOpenAI, "/v1/messages"))
+	require.False(t, DeadLetterReplayable(PlatformAnthropic, "/v1/chat/completions"))
+	require.True(t, DeadLetterReplayable(PlatformOpenAI, "/v1/responses"))
+	require.False(t, DeadLetterReplayable(PlatformAnthropic, "/v1/responses"))
+	require.True(t, DeadLetterReplayable(PlatformGemini, "/v1beta/models/gemini-2.5-pro:generateContent"))
+	require.False(t, DeadLetterReplayable(PlatformOpenAI, "/v1beta/models/gemini-2.5-pro:generateContent"))
+}
@@ -10,12 +10,15 @@ const (
 	StatusUnused   = domain.StatusUnused
 	StatusUsed     = domain.StatusUsed
 	StatusExpired  = domain.StatusExpired
+	StatusArchived = domain.StatusArchived
 )
 
 // Role constants
 const (
-	RoleAdmin = domain.RoleAdmin
-	RoleUser  = domain.RoleUser
+	RoleAdmin    = domain.RoleAdmin
+	RoleUser     = domain.RoleUser
+	RoleOperator = domain.RoleOperator
+	RoleViewer   = domain.RoleViewer
 )
 
 // Platform constants
@@ -189,6 +192,65 @@ const (
 	// SettingKeyBetaPolicySettings stores JSON config for beta policy rules.
 	SettingKeyBetaPolicySettings = "beta_policy_settings"
 
+	// =========================
+	// Client Routing Settings
+	// =========================
+
+	// SettingKeyClientRoutingSettings stores JSON config for client-type routing rules.
+	SettingKeyClientRoutingSettings = "client_routing_settings"
+
+	// =========================
+	// Model Alias Settings
+	// =========================
+
+	// SettingKeyModelAliasSettings stores JSON config for the global model alias table.
+	SettingKeyModelAliasSettings = "model_alias_settings"
+
+	// =========================
+	// Pricing Override Settings
+	// =========================
+
+	// SettingKeyPricingOverrideSettings stores JSON config for admin-managed model price overrides.
+	SettingKeyPricingOverrideSettings = "pricing_override_settings"
+
+	// =========================
+	// Routing Rule Settings
+	// =========================
+
+	// SettingKeyRoutingRuleSettings stores JSON config for the request routing rules engine.
+	SettingKeyRoutingRuleSettings = "routing_rule_settings"
+
+	// =========================
+	// Canary Split Settings
+	// =========================
+
+	// SettingKeyCanarySettings stores JSON config for percentage-based canary traffic splits.
+	SettingKeyCanarySettings = "canary_settings"
+
+	// =========================
+	// Header Policy Settings
+	// =========================
+
+	// SettingKeyHeaderPolicySettings stores JSON config for upstream request / client response header rules.
+	SettingKeyHeaderPolicySettings = "header_policy_settings"
+
+	// =========================
+	// Shadow Mirror Settings
+	// =========================
+
+	// SettingKeyShadowMirrorSettings stores JSON config for shadow traffic mirroring.
+	SettingKeyShadowMirrorSettings = "shadow_mirror_settings"
+
+	// =========================
+	// Runtime Settings
+	// =========================
+
+	// SettingKeyRuntimeSettings stores JSON config for gateway tunables applied without restart.
+	SettingKeyRuntimeSettings = "runtime_settings"
+
+	// SettingKeyMaintenanceMode stores JSON state of the gateway maintenance mode.
+	SettingKeyMaintenanceMode = "maintenance_mode"
+
 	// =========================
 	// Sora S3 存储配置
 	// =========================
@@ -220,6 +282,13 @@ const (
 	// SettingKeyMaxClaudeCodeVersion 最高 Claude Code 版本号限制 (semver, 如 "3.0.0"，空值=不检查)
 	SettingKeyMaxClaudeCodeVersion = "max_claude_code_version"
 
+	// =========================
+	// Codex CLI Version Check
+	// =========================
+
+	// SettingKeyMinCodexCLIVersion 最低 Codex CLI 版本号要求 (semver, 如 "0.40.0"，空值=不检查)
+	SettingKeyMinCodexCLIVersion = "min_codex_cli_version"
+
 	// SettingKeyAllowUngroupedKeyScheduling 允许未分组 API Key 调度（默认 false：未分组 Key 返回 403）
 	SettingKeyAllowUngroupedKeyScheduling = "allow_ungrouped_key_scheduling"
 )
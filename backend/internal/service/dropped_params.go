@@ -0,0 +1,24 @@
+package service
+
+import (
+	"strings"
+
+	"github.com/ShaohongDong/sub2api/internal/pkg/logger"
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+// DroppedParamsHeader 协议转换时目标上游不支持、被丢弃的客户端参数（逗号分隔），
+// 如 Chat Completions 的 stop / logit_bias 发往 Responses 上游、Anthropic 的 top_k 发往 OpenAI 上游
+const DroppedParamsHeader = "X-Sub2API-Dropped-Params"
+
+// setDroppedParamsHeader 在响应头中列出被丢弃的参数；列表为空时不设置
+func setDroppedParamsHeader(c *gin.Context, params []string) {
+	if c == nil || len(params) == 0 {
+		return
+	}
+	c.Header(DroppedParamsHeader, strings.Join(params, ","))
+	if c.Request != nil {
+		logger.FromContext(c.Request.Context()).Debug("gateway.params_dropped", zap.Strings("params", params))
+	}
+}
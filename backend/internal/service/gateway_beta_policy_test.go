@@ -0,0 +1,46 @@
+//go:build unit
+
+package service
+
+import (
+	"context"
+	"testing"
+
+	"github.com/ShaohongDong/sub2api/internal/config"
+	"github.com/ShaohongDong/sub2api/internal/pkg/claude"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEvaluateBetaPolicy_InjectByModelAndAccount(t *testing.T) {
+	settingService := NewSettingService(&runtimeSettingsRepoStub{values: map[string]string{}}, &config.Config{})
+	require.NoError(t, settingService.SetBetaPolicySettings(context.Background(), &BetaPolicySettings{Rules: []BetaPolicyRule{
+		{BetaToken: claude.BetaContext1M, Action: BetaPolicyActionInject, Scope: BetaPolicyScopeAll, Models: []string{" claude-sonnet-4* "}, AccountIDs: []int64{7}},
+		{BetaToken: claude.BetaFineGrainedToolStreaming, Action: BetaPolicyActionInject, Scope: BetaPolicyScopeAPIKey},
+	}}))
+	svc := &GatewayService{settingService: settingService}
+
+	oauth := &Account{ID: 7, Platform: PlatformAnthropic, Type: AccountTypeOAuth}
+	policy := svc.evaluateBetaPolicy(context.Background(), "", oauth, "claude-sonnet-4-5")
+	require.Equal(t, []string{claude.BetaContext1M}, policy.injectTokens)
+
+	require.Empty(t, svc.evaluateBetaPolicy(context.Background(), "", oauth, "claude-haiku-4-5").injectTokens, "model must match")
+	other := &Account{ID: 8, Platform: PlatformAnthropic, Type: AccountTypeOAuth}
+	require.Empty(t, svc.evaluateBetaPolicy(context.Background(), "", other, "claude-sonnet-4-5").injectTokens, "account must match")
+
+	apiKey := &Account{ID: 9, Platform: PlatformAnthropic, Type: AccountTypeAPIKey}
+	require.Equal(t, []string{claude.BetaFineGrainedToolStreaming}, svc.evaluateBetaPolicy(context.Background(), "", apiKey, "claude-haiku-4-5").injectTokens)
+}
+
+func TestSetBetaPolicySettings_ValidatesTargets(t *testing.T) {
+	settingService := NewSettingService(&runtimeSettingsRepoStub{values: map[string]string{}}, &config.Config{})
+
+	err := settingService.SetBetaPolicySettings(context.Background(), &BetaPolicySettings{Rules: []BetaPolicyRule{
+		{BetaToken: claude.BetaContext1M, Action: BetaPolicyActionInject, Scope: BetaPolicyScopeAll, Models: []string{"claude-*-4"}},
+	}})
+	require.Error(t, err)
+
+	err = settingService.SetBetaPolicySettings(context.Background(), &BetaPolicySettings{Rules: []BetaPolicyRule{
+		{BetaToken: claude.BetaContext1M, Action: BetaPolicyActionInject, Scope: BetaPolicyScopeAll, AccountIDs: []int64{0}},
+	}})
+	require.Error(t, err)
+}
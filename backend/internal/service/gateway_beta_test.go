@@ -224,3 +224,16 @@ func TestIsCountTokensUnsupported404(t *testing.T) {
 		})
 	}
 }
+
+func TestInjectBetaTokens(t *testing.T) {
+	require.Equal(t, "", injectBetaTokens("", nil))
+	require.Equal(t, claude.BetaContext1M, injectBetaTokens("", []string{claude.BetaContext1M}))
+	require.Equal(t,
+		claude.BetaOAuth+","+claude.BetaContext1M,
+		injectBetaTokens(claude.BetaOAuth+", "+claude.BetaContext1M, []string{claude.BetaContext1M}),
+	)
+	require.Equal(t,
+		claude.BetaOAuth+","+claude.BetaFineGrainedToolStreaming,
+		injectBetaTokens(claude.BetaOAuth, []string{claude.BetaFineGrainedToolStreaming}),
+	)
+}
@@ -0,0 +1,103 @@
+//go:build unit
+
+package service
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// slotCountingConcurrencyCache 按账号记录真实占用的槽位数，超过 maxConcurrency 时拒绝获取
+type slotCountingConcurrencyCache struct {
+	mockConcurrencyCache
+	inFlight map[int64]map[string]struct{}
+}
+
+func newSlotCountingConcurrencyCache() *slotCountingConcurrencyCache {
+	return &slotCountingConcurrencyCache{inFlight: map[int64]map[string]struct{}{}}
+}
+
+func (c *slotCountingConcurrencyCache) AcquireAccountSlot(_ context.Context, accountID int64, maxConcurrency int, requestID string) (bool, error) {
+	slots := c.inFlight[accountID]
+	if slots == nil {
+		slots = map[string]struct{}{}
+		c.inFlight[accountID] = slots
+	}
+	if len(slots) >= maxConcurrency {
+		return false, nil
+	}
+	slots[requestID] = struct{}{}
+	return true, nil
+}
+
+func (c *slotCountingConcurrencyCache) ReleaseAccountSlot(_ context.Context, accountID int64, requestID string) error {
+	delete(c.inFlight[accountID], requestID)
+	return nil
+}
+
+func (c *slotCountingConcurrencyCache) GetAccountsLoadBatch(_ context.Context, accounts []AccountWithConcurrency) (map[int64]*AccountLoadInfo, error) {
+	result := make(map[int64]*AccountLoadInfo, len(accounts))
+	for _, acc := range accounts {
+		current := len(c.inFlight[acc.ID])
+		loadRate := 0
+		if acc.MaxConcurrency > 0 {
+			loadRate = current * 100 / acc.MaxConcurrency
+		}
+		result[acc.ID] = &AccountLoadInfo{AccountID: acc.ID, CurrentConcurrency: current, LoadRate: loadRate}
+	}
+	return result, nil
+}
+
+// TestGatewayService_ConcurrencyCapReroutesThenQueues 请求数超过账号并发上限时：
+// 先改派到池内其他有空闲槽位的账号，全部占满后返回等待计划排队，任何账号的在途请求都不超过其上限。
+func TestGatewayService_ConcurrencyCapReroutesThenQueues(t *testing.T) {
+	ctx := context.Background()
+	repo := &mockAccountRepoForPlatform{
+		accounts: []Account{
+			{ID: 1, Platform: PlatformAnthropic, Priority: 1, Status: StatusActive, Schedulable: true, Concurrency: 2},
+			{ID: 2, Platform: PlatformAnthropic, Priority: 1, Status: StatusActive, Schedulable: true, Concurrency: 1},
+		},
+		accountsByID: map[int64]*Account{},
+	}
+	for i := range repo.accounts {
+		repo.accountsByID[repo.accounts[i].ID] = &repo.accounts[i]
+	}
+	cfg := testConfig()
+	cfg.Gateway.Scheduling.LoadBatchEnabled = true
+	concurrencyCache := newSlotCountingConcurrencyCache()
+	svc := &GatewayService{
+		accountRepo:        repo,
+		cache:              &mockGatewayCacheForPlatform{},
+		cfg:                cfg,
+		concurrencyService: NewConcurrencyService(concurrencyCache),
+	}
+
+	// 总容量为 3：前 3 个请求都直接拿到槽位，分布在两个账号上
+	var releases []func()
+	for i := 0; i < 3; i++ {
+		result, err := svc.SelectAccountWithLoadAwareness(ctx, nil, "", "claude-3-5-sonnet-20241022", nil, "")
+		require.NoError(t, err)
+		require.True(t, result.Acquired, "request %d should acquire a slot", i)
+		releases = append(releases, result.ReleaseFunc)
+	}
+	require.Len(t, concurrencyCache.inFlight[1], 2)
+	require.Len(t, concurrencyCache.inFlight[2], 1)
+
+	// 第 4 个请求超过所有账号上限，进入等待队列而不是超额发往上游
+	result, err := svc.SelectAccountWithLoadAwareness(ctx, nil, "", "claude-3-5-sonnet-20241022", nil, "")
+	require.NoError(t, err)
+	require.False(t, result.Acquired)
+	require.NotNil(t, result.WaitPlan)
+	require.Equal(t, result.Account.ID, result.WaitPlan.AccountID)
+	require.Equal(t, result.Account.Concurrency, result.WaitPlan.MaxConcurrency)
+	require.Len(t, concurrencyCache.inFlight[1], 2)
+	require.Len(t, concurrencyCache.inFlight[2], 1)
+
+	// 释放一个槽位后，下一个请求可以立即获取
+	releases[0]()
+	result, err = svc.SelectAccountWithLoadAwareness(ctx, nil, "", "claude-3-5-sonnet-20241022", nil, "")
+	require.NoError(t, err)
+	require.True(t, result.Acquired)
+}
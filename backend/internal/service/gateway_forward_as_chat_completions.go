@@ -1,39 +1,258 @@
 package service
 
 import (
+	"bufio"
 	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"net/http"
+	"strings"
+	"time"
 
+	"github.com/ShaohongDong/sub2api/internal/pkg/apicompat"
 	"github.com/ShaohongDong/sub2api/internal/pkg/logger"
+	"github.com/ShaohongDong/sub2api/internal/pkg/openai"
+	"github.com/ShaohongDong/sub2api/internal/util/responseheaders"
 	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
 )
 
-const anthropicChatCompletionsUnsupportedMessage = "Chat Completions compatibility for Anthropic groups is temporarily unavailable. Please use /v1/responses or /v1/messages."
-
-// ForwardAsChatCompletions is intentionally disabled on this branch.
-//
-// The cherry-picked Anthropic compatibility chain brought in handlers/routes
-// before the corresponding apicompat Chat Completions converters landed.  Keep
-// the method as a guarded stub so the rest of the Claude/Responses integration
-// can compile and ship safely.
+// ForwardAsChatCompletions accepts a Chat Completions request body and serves
+// it from an Anthropic platform account. The request is converted through the
+// Responses hub format (Chat → Responses → Anthropic Messages) and the
+// Anthropic stream is converted back (Anthropic → Responses events →
+// chat.completion.chunk), so tools / tool_choice / tool_calls and streaming
+// partial tool-call argument deltas survive the round trip. Gemini API-key
+// accounts bypass the conversion (see forwardChatCompletionsToGeminiOpenAICompat).
 func (s *GatewayService) ForwardAsChatCompletions(
-	_ context.Context,
+	ctx context.Context,
 	c *gin.Context,
 	account *Account,
-	_ []byte,
-	_ *ParsedRequest,
+	body []byte,
+	parsed *ParsedRequest,
+) (*ForwardResult, error) {
+	startTime := time.Now()
+
+	// 1. Parse Chat Completions request
+	var chatReq openai.ChatCompletionRequest
+	if err := json.Unmarshal(body, &chatReq); err != nil {
+		return nil, fmt.Errorf("parse chat completions request: %w", err)
+	}
+	originalModel := chatReq.Model
+	clientStream := chatReq.Stream
+	includeUsage := chatReq.StreamOptions != nil && chatReq.StreamOptions.IncludeUsage
+
+	// Gemini groups: AI Studio API-key accounts speak Chat Completions natively
+	// through Google's OpenAI-compatible endpoint, so skip the Anthropic round trip.
+	if account.Platform == PlatformGemini {
+		if !account.IsGeminiOpenAICompatEgress() {
+			writeOpenAICompatError(c, http.StatusBadRequest, "Chat Completions on Gemini groups requires an AI Studio API key account")
+			return nil, fmt.Errorf("gemini account %d does not support openai compat egress", account.ID)
+		}
+		var reasoningEffort *string
+		if normalized := normalizeOpenAIReasoningEffort(chatReq.ReasoningEffort); normalized != "" {
+			reasoningEffort = &normalized
+		}
+		return s.forwardChatCompletionsToGeminiOpenAICompat(ctx, c, account, body, originalModel, clientStream, includeUsage, reasoningEffort)
+	}
+
+	// 2. Convert Chat Completions → Responses
+	responsesReq, err := openai.ChatCompletionsToResponses(&chatReq)
+	if err != nil {
+		writeOpenAICompatError(c, http.StatusBadRequest, err.Error())
+		return nil, fmt.Errorf("convert chat completions to responses: %w", err)
+	}
+	var reasoningEffort *string
+	if responsesReq.Reasoning != nil {
+		if normalized := normalizeOpenAIReasoningEffort(responsesReq.Reasoning.Effort); normalized != "" {
+			reasoningEffort = &normalized
+		}
+	}
+
+	// 3. Convert, map model and send upstream (errors are written in OpenAI error format)
+	resp, mappedModel, err := s.doAnthropicCompatRequest(ctx, c, account, responsesReq, writeChatCompletionsCompatError)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	// 4. Handle normal response (convert Anthropic → Chat Completions)
+	if clientStream {
+		return s.handleChatCompletionsFromAnthropicStream(resp, c, originalModel, mappedModel, reasoningEffort, includeUsage, startTime)
+	}
+	return s.handleChatCompletionsFromAnthropicBuffered(resp, c, originalModel, mappedModel, reasoningEffort, startTime,
+		s.anthropicStructuredOutputRetry(ctx, c, account, responsesReq))
+}
+
+// writeChatCompletionsCompatError adapts writeOpenAICompatError to the
+// doAnthropicCompatRequest error callback.
+func writeChatCompletionsCompatError(c *gin.Context, statusCode int, _ string, message string) {
+	writeOpenAICompatError(c, statusCode, message)
+}
+
+// handleChatCompletionsFromAnthropicBuffered assembles the full Anthropic
+// response and writes a single chat.completion object.
+func (s *GatewayService) handleChatCompletionsFromAnthropicBuffered(
+	resp *http.Response,
+	c *gin.Context,
+	originalModel string,
+	mappedModel string,
+	reasoningEffort *string,
+	startTime time.Time,
+	retry *anthropicStructuredOutputRetry,
 ) (*ForwardResult, error) {
-	if account != nil {
-		logger.LegacyPrintf("service.gateway", "chat_completions_compat_disabled account=%d platform=%s", account.ID, account.Platform)
-	}
-	if c != nil {
-		c.JSON(http.StatusBadRequest, gin.H{
-			"error": gin.H{
-				"type":    "invalid_request_error",
-				"message": anthropicChatCompletionsUnsupportedMessage,
-			},
-		})
-	}
-	return nil, fmt.Errorf("anthropic chat completions compatibility disabled")
+	requestID := resp.Header.Get("x-request-id")
+
+	finalResp, usage := s.collectAnthropicStreamResponse(resp, "forward_as_chat_completions buffered")
+	if finalResp == nil {
+		writeOpenAICompatError(c, http.StatusBadGateway, "Upstream stream ended without a response")
+		return nil, fmt.Errorf("upstream stream ended without response")
+	}
+
+	responsesResp := retry.apply(apicompat.AnthropicToResponsesResponse(finalResp), &usage, "forward_as_chat_completions buffered")
+
+	if s.responseHeaderFilter != nil {
+		responseheaders.WriteFilteredHeaders(c.Writer.Header(), resp.Header, s.responseHeaderFilter)
+	}
+	c.JSON(http.StatusOK, openai.ResponsesToChatCompletion(responsesResp, originalModel, compatReasoningVisible(c)))
+
+	return &ForwardResult{
+		RequestID:       requestID,
+		Usage:           usage,
+		Model:           originalModel,
+		UpstreamModel:   mappedModel,
+		ReasoningEffort: reasoningEffort,
+		Stream:          false,
+		Duration:        time.Since(startTime),
+	}, nil
+}
+
+// handleChatCompletionsFromAnthropicStream converts Anthropic SSE events into
+// chat.completion.chunk SSE events, terminated by data: [DONE].
+func (s *GatewayService) handleChatCompletionsFromAnthropicStream(
+	resp *http.Response,
+	c *gin.Context,
+	originalModel string,
+	mappedModel string,
+	reasoningEffort *string,
+	includeUsage bool,
+	startTime time.Time,
+) (*ForwardResult, error) {
+	requestID := resp.Header.Get("x-request-id")
+
+	if s.responseHeaderFilter != nil {
+		responseheaders.WriteFilteredHeaders(c.Writer.Header(), resp.Header, s.responseHeaderFilter)
+	}
+	c.Writer.Header().Set("Content-Type", "text/event-stream")
+	c.Writer.Header().Set("Cache-Control", "no-cache")
+	c.Writer.Header().Set("Connection", "keep-alive")
+	c.Writer.Header().Set("X-Accel-Buffering", "no")
+	c.Writer.WriteHeader(http.StatusOK)
+
+	responsesState := apicompat.NewAnthropicEventToResponsesState()
+	responsesState.Model = originalModel
+	chatState := openai.NewResponsesEventToChatState()
+	chatState.Model = originalModel
+	chatState.IncludeUsage = includeUsage
+	chatState.IncludeReasoning = compatReasoningVisible(c)
+	var usage ClaudeUsage
+	var firstTokenMs *int
+	firstChunk := true
+
+	resultWithUsage := func() *ForwardResult {
+		return &ForwardResult{
+			RequestID:       requestID,
+			Usage:           usage,
+			Model:           originalModel,
+			UpstreamModel:   mappedModel,
+			ReasoningEffort: reasoningEffort,
+			Stream:          true,
+			Duration:        time.Since(startTime),
+			FirstTokenMs:    firstTokenMs,
+		}
+	}
+
+	// writeChunks returns false when the client has disconnected.
+	writeChunks := func(chunks []openai.ChatCompletionChunk) bool {
+		for _, chunk := range chunks {
+			sse, err := openai.ChatChunkToSSE(chunk)
+			if err != nil {
+				continue
+			}
+			if _, err := fmt.Fprint(c.Writer, sse); err != nil {
+				logger.L().Info("forward_as_chat_completions stream: client disconnected",
+					zap.String("request_id", requestID),
+				)
+				return false
+			}
+		}
+		if len(chunks) > 0 {
+			c.Writer.Flush()
+		}
+		return true
+	}
+
+	// writeResponsesEvents feeds converted Responses events into the chat state.
+	writeResponsesEvents := func(events []apicompat.ResponsesStreamEvent) bool {
+		var chunks []openai.ChatCompletionChunk
+		for i := range events {
+			chunks = append(chunks, openai.ResponsesEventToChatChunks(&events[i], chatState)...)
+		}
+		return writeChunks(chunks)
+	}
+
+	scanner := bufio.NewScanner(resp.Body)
+	maxLineSize := defaultMaxLineSize
+	if s.cfg != nil && s.cfg.Gateway.MaxLineSize > 0 {
+		maxLineSize = s.cfg.Gateway.MaxLineSize
+	}
+	scanner.Buffer(make([]byte, 0, 64*1024), maxLineSize)
+
+	for scanner.Scan() {
+		line := scanner.Text()
+		if !strings.HasPrefix(line, "data: ") {
+			continue
+		}
+
+		var event apicompat.AnthropicStreamEvent
+		if err := json.Unmarshal([]byte(line[6:]), &event); err != nil {
+			logger.L().Warn("forward_as_chat_completions stream: failed to parse event",
+				zap.Error(err),
+				zap.String("request_id", requestID),
+			)
+			continue
+		}
+		if firstChunk {
+			firstChunk = false
+			ms := int(time.Since(startTime).Milliseconds())
+			firstTokenMs = &ms
+		}
+		if event.Type == "message_start" && event.Message != nil {
+			mergeAnthropicUsage(&usage, event.Message.Usage)
+		}
+		if event.Type == "message_delta" && event.Usage != nil {
+			mergeAnthropicUsage(&usage, *event.Usage)
+		}
+
+		if !writeResponsesEvents(apicompat.AnthropicEventToResponsesEvents(&event, responsesState)) {
+			return resultWithUsage(), nil
+		}
+	}
+	if err := scanner.Err(); err != nil && !errors.Is(err, context.Canceled) && !errors.Is(err, context.DeadlineExceeded) {
+		logger.L().Warn("forward_as_chat_completions stream: read error",
+			zap.Error(err),
+			zap.String("request_id", requestID),
+		)
+	}
+
+	if !writeResponsesEvents(apicompat.FinalizeAnthropicResponsesStream(responsesState)) {
+		return resultWithUsage(), nil
+	}
+	if !writeChunks(openai.FinalizeResponsesChatStream(chatState)) {
+		return resultWithUsage(), nil
+	}
+	fmt.Fprint(c.Writer, "data: [DONE]\n\n") //nolint:errcheck
+	c.Writer.Flush()
+	return resultWithUsage(), nil
 }
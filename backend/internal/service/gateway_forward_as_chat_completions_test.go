@@ -3,38 +3,178 @@
 package service
 
 import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
+	"time"
 
+	"github.com/ShaohongDong/sub2api/internal/pkg/apicompat"
 	"github.com/gin-gonic/gin"
 	"github.com/stretchr/testify/require"
+	"github.com/tidwall/gjson"
 )
 
-func TestForwardAsChatCompletions_Disabled_ReturnsBadRequest(t *testing.T) {
+func anthropicToolUseStreamResponse() *http.Response {
+	return &http.Response{
+		Header: http.Header{"x-request-id": []string{"rid_chat"}},
+		Body: io.NopCloser(strings.NewReader(strings.Join([]string{
+			`event: message_start`,
+			`data: {"type":"message_start","message":{"id":"msg_1","type":"message","role":"assistant","content":[],"model":"claude-sonnet-4.5","stop_reason":"","usage":{"input_tokens":15,"cache_read_input_tokens":6}}}`,
+			``,
+			`event: content_block_start`,
+			`data: {"type":"content_block_start","index":0,"content_block":{"type":"tool_use","id":"toolu_1","name":"get_weather","input":{}}}`,
+			``,
+			`event: content_block_delta`,
+			`data: {"type":"content_block_delta","index":0,"delta":{"type":"input_json_delta","partial_json":"{\"city\":"}}`,
+			``,
+			`event: content_block_delta`,
+			`data: {"type":"content_block_delta","index":0,"delta":{"type":"input_json_delta","partial_json":"\"Paris\"}"}}`,
+			``,
+			`event: content_block_stop`,
+			`data: {"type":"content_block_stop","index":0}`,
+			``,
+			`event: message_delta`,
+			`data: {"type":"message_delta","delta":{"stop_reason":"tool_use"},"usage":{"output_tokens":9}}`,
+			``,
+			`event: message_stop`,
+			`data: {"type":"message_stop"}`,
+			``,
+		}, "\n"))),
+	}
+}
+
+func TestHandleChatCompletionsFromAnthropicStream_ToolCallDeltas(t *testing.T) {
 	t.Parallel()
 	gin.SetMode(gin.TestMode)
 
 	rec := httptest.NewRecorder()
 	c, _ := gin.CreateTestContext(rec)
-	c.Request = httptest.NewRequest("POST", "/v1/chat/completions", nil)
-	account := &Account{ID: 42, Platform: PlatformAnthropic}
 
 	svc := &GatewayService{}
-	result, err := svc.ForwardAsChatCompletions(c.Request.Context(), c, account, []byte(`{"model":"gpt-5"}`), &ParsedRequest{Model: "gpt-5"})
+	result, err := svc.handleChatCompletionsFromAnthropicStream(anthropicToolUseStreamResponse(), c, "claude-sonnet-4.5", "claude-sonnet-4-5", nil, true, time.Now())
+	require.NoError(t, err)
+	require.NotNil(t, result)
+	require.True(t, result.Stream)
+	require.Equal(t, 15, result.Usage.InputTokens)
+	require.Equal(t, 9, result.Usage.OutputTokens)
+	require.Equal(t, 6, result.Usage.CacheReadInputTokens)
 
-	require.Nil(t, result)
-	require.EqualError(t, err, "anthropic chat completions compatibility disabled")
-	require.Equal(t, 400, rec.Code)
-	require.Contains(t, rec.Body.String(), anthropicChatCompletionsUnsupportedMessage)
-	require.Contains(t, rec.Body.String(), `"invalid_request_error"`)
+	var args strings.Builder
+	var toolName, finishReason string
+	for _, line := range strings.Split(rec.Body.String(), "\n") {
+		if !strings.HasPrefix(line, "data: ") || line == "data: [DONE]" {
+			continue
+		}
+		chunk := gjson.Parse(line[6:])
+		call := chunk.Get("choices.0.delta.tool_calls.0")
+		if name := call.Get("function.name").String(); name != "" {
+			toolName = name
+		}
+		args.WriteString(call.Get("function.arguments").String())
+		if fr := chunk.Get("choices.0.finish_reason").String(); fr != "" {
+			finishReason = fr
+		}
+	}
+	require.Equal(t, "get_weather", toolName)
+	require.JSONEq(t, `{"city":"Paris"}`, args.String())
+	require.Equal(t, "tool_calls", finishReason)
+	require.True(t, strings.HasSuffix(rec.Body.String(), "data: [DONE]\n\n"))
+}
+
+func TestHandleChatCompletionsFromAnthropicBuffered_ToolCalls(t *testing.T) {
+	t.Parallel()
+	gin.SetMode(gin.TestMode)
+
+	rec := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(rec)
+
+	svc := &GatewayService{}
+	result, err := svc.handleChatCompletionsFromAnthropicBuffered(anthropicToolUseStreamResponse(), c, "claude-sonnet-4.5", "claude-sonnet-4-5", nil, time.Now(), nil)
+	require.NoError(t, err)
+	require.NotNil(t, result)
+	require.False(t, result.Stream)
+
+	out := gjson.ParseBytes(rec.Body.Bytes())
+	require.Equal(t, "chat.completion", out.Get("object").String())
+	require.Equal(t, "claude-sonnet-4.5", out.Get("model").String())
+	require.Equal(t, "tool_calls", out.Get("choices.0.finish_reason").String())
+	require.Equal(t, "get_weather", out.Get("choices.0.message.tool_calls.0.function.name").String())
+	require.JSONEq(t, `{"city":"Paris"}`, out.Get("choices.0.message.tool_calls.0.function.arguments").String())
 }
 
-func TestForwardAsChatCompletions_Disabled_WithoutGinContextStillErrors(t *testing.T) {
+func TestForwardAsChatCompletions_InvalidBody(t *testing.T) {
 	t.Parallel()
 
 	svc := &GatewayService{}
-	result, err := svc.ForwardAsChatCompletions(nil, nil, &Account{ID: 7, Platform: PlatformAnthropic}, nil, nil)
+	result, err := svc.ForwardAsChatCompletions(nil, nil, &Account{ID: 7, Platform: PlatformAnthropic}, []byte(`not json`), nil)
 
 	require.Nil(t, result)
-	require.EqualError(t, err, "anthropic chat completions compatibility disabled")
+	require.ErrorContains(t, err, "parse chat completions request")
+}
+
+func anthropicTextStreamResponse(text string, outputTokens int) *http.Response {
+	delta, _ := json.Marshal(text)
+	return &http.Response{
+		Header: http.Header{"x-request-id": []string{"rid_chat"}},
+		Body: io.NopCloser(strings.NewReader(strings.Join([]string{
+			`event: message_start`,
+			`data: {"type":"message_start","message":{"id":"msg_1","type":"message","role":"assistant","content":[],"model":"claude-sonnet-4.5","stop_reason":"","usage":{"input_tokens":10}}}`,
+			`event: content_block_start`,
+			`data: {"type":"content_block_start","index":0,"content_block":{"type":"text","text":""}}`,
+			`event: content_block_delta`,
+			`data: {"type":"content_block_delta","index":0,"delta":{"type":"text_delta","text":` + string(delta) + `}}`,
+			`event: content_block_stop`,
+			`data: {"type":"content_block_stop","index":0}`,
+			`event: message_delta`,
+			fmt.Sprintf(`data: {"type":"message_delta","delta":{"stop_reason":"end_turn"},"usage":{"output_tokens":%d}}`, outputTokens),
+			`event: message_stop`,
+			`data: {"type":"message_stop"}`,
+			``,
+		}, "\n"))),
+	}
+}
+
+func TestHandleChatCompletionsFromAnthropicBuffered_RetriesInvalidStructuredOutput(t *testing.T) {
+	t.Parallel()
+	gin.SetMode(gin.TestMode)
+
+	rec := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(rec)
+
+	svc := &GatewayService{}
+	resends := 0
+	retry := &anthropicStructuredOutputRetry{
+		s:          svc,
+		format:     &apicompat.ResponsesTextFormat{Type: apicompat.TextFormatJSONSchema, Name: "city", Schema: json.RawMessage(`{"type":"object","required":["city"]}`)},
+		maxRetries: 2,
+		resend: func() (*http.Response, error) {
+			resends++
+			return anthropicTextStreamResponse(`{"city":"Paris"}`, 4), nil
+		},
+	}
+
+	result, err := svc.handleChatCompletionsFromAnthropicBuffered(anthropicTextStreamResponse(`Paris`, 3), c, "claude-sonnet-4.5", "claude-sonnet-4-5", nil, time.Now(), retry)
+	require.NoError(t, err)
+	require.Equal(t, 1, resends)
+	require.Equal(t, 20, result.Usage.InputTokens)
+	require.Equal(t, 7, result.Usage.OutputTokens)
+	require.JSONEq(t, `{"city":"Paris"}`, gjson.GetBytes(rec.Body.Bytes(), "choices.0.message.content").String())
+}
+
+func TestSetDroppedParamsHeader(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	rec := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(rec)
+	c.Request = httptest.NewRequest(http.MethodPost, "/v1/chat/completions", nil)
+
+	setDroppedParamsHeader(c, nil)
+	require.Empty(t, rec.Header().Get(DroppedParamsHeader))
+
+	hub := &apicompat.ResponsesRequest{StopSequences: []string{"END"}, DroppedParams: []string{"logit_bias"}}
+	setDroppedParamsHeader(c, hub.DroppedForResponses())
+	require.Equal(t, "logit_bias,stop", rec.Header().Get(DroppedParamsHeader))
 }
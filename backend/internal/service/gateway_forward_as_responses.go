@@ -15,6 +15,7 @@ import (
 	"github.com/ShaohongDong/sub2api/internal/pkg/apicompat"
 	"github.com/ShaohongDong/sub2api/internal/pkg/claude"
 	"github.com/ShaohongDong/sub2api/internal/pkg/logger"
+	"github.com/ShaohongDong/sub2api/internal/pkg/tracing"
 	"github.com/ShaohongDong/sub2api/internal/util/responseheaders"
 	"github.com/gin-gonic/gin"
 	"github.com/tidwall/gjson"
@@ -44,20 +45,59 @@ func (s *GatewayService) ForwardAsResponses(
 	}
 	originalModel := responsesReq.Model
 	clientStream := responsesReq.Stream
+	reasoningEffort := ExtractResponsesReasoningEffortFromBody(body)
 
-	// 2. Convert Responses → Anthropic
-	anthropicReq, err := apicompat.ResponsesToAnthropicRequest(&responsesReq)
+	// 2. Convert, map model and send upstream (errors are written in Responses format)
+	resp, mappedModel, err := s.doAnthropicCompatRequest(ctx, c, account, &responsesReq, writeResponsesError)
 	if err != nil {
-		return nil, fmt.Errorf("convert responses to anthropic: %w", err)
+		return nil, err
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	// 3. Handle normal response (convert Anthropic → Responses)
+	var result *ForwardResult
+	var handleErr error
+	if clientStream {
+		result, handleErr = s.handleResponsesStreamingResponse(resp, c, originalModel, mappedModel, reasoningEffort, startTime)
+	} else {
+		result, handleErr = s.handleResponsesBufferedStreamingResponse(resp, c, originalModel, mappedModel, reasoningEffort, startTime,
+			s.anthropicStructuredOutputRetry(ctx, c, account, &responsesReq))
 	}
 
-	// 3. Force upstream streaming (Anthropic works best with streaming)
+	return result, handleErr
+}
+
+// doAnthropicCompatRequest converts a Responses API request to Anthropic
+// Messages format, applies model mapping and Claude Code mimicry, and sends it
+// to the Anthropic upstream (always streaming). Shared by the Responses and
+// Chat Completions compatibility entry points; writeError renders errors in
+// the client's protocol. On success the caller must close resp.Body.
+func (s *GatewayService) doAnthropicCompatRequest(
+	ctx context.Context,
+	c *gin.Context,
+	account *Account,
+	responsesReq *apicompat.ResponsesRequest,
+	writeError func(c *gin.Context, statusCode int, code, message string),
+) (*http.Response, string, error) {
+	originalModel := responsesReq.Model
+	downscaleResponsesImages(s.cfg, responsesReq)
+
+	// 1. Convert Responses → Anthropic
+	translateSpan := startTranslateSpan(ctx, "responses", "anthropic")
+	anthropicReq, err := apicompat.ResponsesToAnthropicRequest(responsesReq)
+	tracing.End(translateSpan, err)
+	if err != nil {
+		writeError(c, http.StatusBadRequest, "invalid_request_error", err.Error())
+		return nil, "", fmt.Errorf("convert responses to anthropic: %w", err)
+	}
+	setDroppedParamsHeader(c, responsesReq.DroppedParams)
+
+	// 2. Force upstream streaming (Anthropic works best with streaming)
 	anthropicReq.Stream = true
 	reqStream := true
 
-	// 4. Model mapping
+	// 3. Model mapping
 	mappedModel := originalModel
-	reasoningEffort := ExtractResponsesReasoningEffortFromBody(body)
 	if account.Type == AccountTypeAPIKey {
 		mappedModel = account.GetMappedModel(originalModel)
 	}
@@ -69,21 +109,21 @@ func (s *GatewayService) ForwardAsResponses(
 	}
 	anthropicReq.Model = mappedModel
 
-	logger.L().Debug("gateway forward_as_responses: model mapping applied",
+	logger.L().Debug("gateway anthropic compat: model mapping applied",
 		zap.Int64("account_id", account.ID),
 		zap.String("original_model", originalModel),
 		zap.String("mapped_model", mappedModel),
-		zap.Bool("client_stream", clientStream),
+		zap.Bool("client_stream", responsesReq.Stream),
 	)
 
-	// 5. Marshal Anthropic request body
+	// 4. Marshal Anthropic request body
 	anthropicBody, err := json.Marshal(anthropicReq)
 	if err != nil {
-		return nil, fmt.Errorf("marshal anthropic request: %w", err)
+		return nil, "", fmt.Errorf("marshal anthropic request: %w", err)
 	}
 
-	// 6. Apply Claude Code mimicry for OAuth accounts (non-Claude-Code endpoints)
-	isClaudeCode := false // Responses API is never Claude Code
+	// 5. Apply Claude Code mimicry for OAuth accounts (non-Claude-Code endpoints)
+	isClaudeCode := false // compat endpoints are never Claude Code
 	shouldMimicClaudeCode := account.IsOAuth() && !isClaudeCode
 
 	if shouldMimicClaudeCode {
@@ -93,30 +133,32 @@ func (s *GatewayService) ForwardAsResponses(
 		}
 	}
 
-	// 7. Enforce cache_control block limit
+	// 6. Enforce cache_control block limit
 	anthropicBody = enforceCacheControlLimit(anthropicBody)
 
-	// 8. Get access token
+	// 7. Get access token
 	token, tokenType, err := s.GetAccessToken(ctx, account)
 	if err != nil {
-		return nil, fmt.Errorf("get access token: %w", err)
+		return nil, "", fmt.Errorf("get access token: %w", err)
 	}
 
-	// 9. Get proxy URL
+	// 8. Get proxy URL
 	proxyURL := ""
 	if account.ProxyID != nil && account.Proxy != nil {
 		proxyURL = account.Proxy.URL()
 	}
 
-	// 10. Build upstream request
-	upstreamCtx, releaseUpstreamCtx := detachStreamUpstreamContext(ctx, reqStream)
-	upstreamReq, err := s.buildUpstreamRequest(upstreamCtx, c, account, anthropicBody, token, tokenType, mappedModel, reqStream, shouldMimicClaudeCode)
-	releaseUpstreamCtx()
+	// 9. Build upstream request
+	upstreamReq, err := s.buildUpstreamRequest(ctx, c, account, anthropicBody, token, tokenType, mappedModel, reqStream, shouldMimicClaudeCode)
 	if err != nil {
-		return nil, fmt.Errorf("build upstream request: %w", err)
+		return nil, "", fmt.Errorf("build upstream request: %w", err)
+	}
+	// Remote MCP servers (Responses type=mcp tools) need the MCP connector beta
+	if len(anthropicReq.MCPServers) > 0 {
+		upstreamReq.Header.Set("anthropic-beta", mergeAnthropicBeta([]string{claude.BetaMCPClient}, upstreamReq.Header.Get("anthropic-beta")))
 	}
 
-	// 11. Send request
+	// 10. Send request
 	resp, err := s.httpUpstream.DoWithTLS(upstreamReq, proxyURL, account.ID, account.Concurrency, account.IsTLSFingerprintEnabled())
 	if err != nil {
 		if resp != nil && resp.Body != nil {
@@ -132,12 +174,11 @@ func (s *GatewayService) ForwardAsResponses(
 			Kind:               "request_error",
 			Message:            safeErr,
 		})
-		writeResponsesError(c, http.StatusBadGateway, "server_error", "Upstream request failed")
-		return nil, fmt.Errorf("upstream request failed: %s", safeErr)
+		writeError(c, http.StatusBadGateway, "server_error", "Upstream request failed")
+		return nil, "", fmt.Errorf("upstream request failed: %s", safeErr)
 	}
-	defer func() { _ = resp.Body.Close() }()
 
-	// 12. Handle error response with failover
+	// 11. Handle error response with failover
 	if resp.StatusCode >= 400 {
 		respBody, _ := io.ReadAll(io.LimitReader(resp.Body, 2<<20))
 		_ = resp.Body.Close()
@@ -159,27 +200,18 @@ func (s *GatewayService) ForwardAsResponses(
 			if s.rateLimitService != nil {
 				s.rateLimitService.HandleUpstreamError(ctx, account, resp.StatusCode, resp.Header, respBody)
 			}
-			return nil, &UpstreamFailoverError{
+			return nil, "", &UpstreamFailoverError{
 				StatusCode:   resp.StatusCode,
 				ResponseBody: respBody,
 			}
 		}
 
-		// Non-failover error: return Responses-formatted error to client
-		writeResponsesError(c, mapUpstreamStatusCode(resp.StatusCode), "server_error", upstreamMsg)
-		return nil, fmt.Errorf("upstream error: %d %s", resp.StatusCode, upstreamMsg)
+		// Non-failover error: return client-formatted error
+		writeError(c, mapUpstreamStatusCode(resp.StatusCode), "server_error", upstreamMsg)
+		return nil, "", fmt.Errorf("upstream error: %d %s", resp.StatusCode, upstreamMsg)
 	}
 
-	// 13. Handle normal response (convert Anthropic → Responses)
-	var result *ForwardResult
-	var handleErr error
-	if clientStream {
-		result, handleErr = s.handleResponsesStreamingResponse(resp, c, originalModel, mappedModel, reasoningEffort, startTime)
-	} else {
-		result, handleErr = s.handleResponsesBufferedStreamingResponse(resp, c, originalModel, mappedModel, reasoningEffort, startTime)
-	}
-
-	return result, handleErr
+	return resp, mappedModel, nil
 }
 
 // ExtractResponsesReasoningEffortFromBody reads Responses API reasoning.effort
@@ -224,9 +256,99 @@ func (s *GatewayService) handleResponsesBufferedStreamingResponse(
 	mappedModel string,
 	reasoningEffort *string,
 	startTime time.Time,
+	retry *anthropicStructuredOutputRetry,
 ) (*ForwardResult, error) {
 	requestID := resp.Header.Get("x-request-id")
 
+	finalResp, usage := s.collectAnthropicStreamResponse(resp, "forward_as_responses buffered")
+	if finalResp == nil {
+		writeResponsesError(c, http.StatusBadGateway, "server_error", "Upstream stream ended without a response")
+		return nil, fmt.Errorf("upstream stream ended without response")
+	}
+
+	// Convert to Responses format
+	responsesResp := retry.apply(apicompat.AnthropicToResponsesResponse(finalResp), &usage, "forward_as_responses buffered")
+	responsesResp.Model = originalModel // Use original model name
+
+	if s.responseHeaderFilter != nil {
+		responseheaders.WriteFilteredHeaders(c.Writer.Header(), resp.Header, s.responseHeaderFilter)
+	}
+	c.JSON(http.StatusOK, responsesResp)
+
+	return &ForwardResult{
+		RequestID:       requestID,
+		Usage:           usage,
+		Model:           originalModel,
+		UpstreamModel:   mappedModel,
+		ReasoningEffort: reasoningEffort,
+		Stream:          false,
+		Duration:        time.Since(startTime),
+	}, nil
+}
+
+// anthropicStructuredOutputRetry re-sends a buffered Anthropic compat request
+// when the structured output (text.format) fails validation. A nil retry
+// only passes the response through.
+type anthropicStructuredOutputRetry struct {
+	s          *GatewayService
+	format     *apicompat.ResponsesTextFormat
+	maxRetries int
+	resend     func() (*http.Response, error)
+}
+
+// anthropicStructuredOutputRetry returns nil when the request does not ask
+// for JSON output.
+func (s *GatewayService) anthropicStructuredOutputRetry(
+	ctx context.Context,
+	c *gin.Context,
+	account *Account,
+	responsesReq *apicompat.ResponsesRequest,
+) *anthropicStructuredOutputRetry {
+	format, _ := apicompat.ParseResponsesTextFormat(responsesReq.Text)
+	if !format.RequiresJSON() {
+		return nil
+	}
+	return &anthropicStructuredOutputRetry{
+		s:          s,
+		format:     format,
+		maxRetries: structuredOutputMaxRetries(s.cfg),
+		resend: func() (*http.Response, error) {
+			resp, _, err := s.doAnthropicCompatRequest(ctx, c, account, responsesReq, discardAnthropicCompatError)
+			return resp, err
+		},
+	}
+}
+
+// apply validates resp and retries as configured, adding the usage of every
+// retry attempt to usage.
+func (r *anthropicStructuredOutputRetry) apply(resp *apicompat.ResponsesResponse, usage *ClaudeUsage, logPrefix string) *apicompat.ResponsesResponse {
+	if r == nil {
+		return resp
+	}
+	return retryInvalidStructuredOutput(r.format, r.maxRetries, resp, func() (*apicompat.ResponsesResponse, error) {
+		upstream, err := r.resend()
+		if err != nil {
+			return nil, err
+		}
+		defer func() { _ = upstream.Body.Close() }()
+		next, nextUsage := r.s.collectAnthropicStreamResponse(upstream, logPrefix)
+		if next == nil {
+			return nil, fmt.Errorf("upstream stream ended without response")
+		}
+		usage.InputTokens += nextUsage.InputTokens
+		usage.OutputTokens += nextUsage.OutputTokens
+		usage.CacheCreationInputTokens += nextUsage.CacheCreationInputTokens
+		usage.CacheReadInputTokens += nextUsage.CacheReadInputTokens
+		return apicompat.AnthropicToResponsesResponse(next), nil
+	}, logPrefix)
+}
+
+// collectAnthropicStreamResponse reads all Anthropic SSE events from the
+// upstream streaming response and assembles them into a complete Anthropic
+// response. It returns nil when the stream ended without message_start.
+func (s *GatewayService) collectAnthropicStreamResponse(resp *http.Response, logPrefix string) (*apicompat.AnthropicResponse, ClaudeUsage) {
+	requestID := resp.Header.Get("x-request-id")
+
 	scanner := bufio.NewScanner(resp.Body)
 	maxLineSize := defaultMaxLineSize
 	if s.cfg != nil && s.cfg.Gateway.MaxLineSize > 0 {
@@ -257,7 +379,7 @@ func (s *GatewayService) handleResponsesBufferedStreamingResponse(
 
 		var event apicompat.AnthropicStreamEvent
 		if err := json.Unmarshal([]byte(payload), &event); err != nil {
-			logger.L().Warn("forward_as_responses buffered: failed to parse event",
+			logger.L().Warn(logPrefix+": failed to parse event",
 				zap.Error(err),
 				zap.String("request_id", requestID),
 				zap.String("event_type", eventType),
@@ -283,7 +405,13 @@ func (s *GatewayService) handleResponsesBufferedStreamingResponse(
 
 		// Accumulate content blocks
 		if event.Type == "content_block_start" && event.ContentBlock != nil && finalResp != nil {
-			finalResp.Content = append(finalResp.Content, *event.ContentBlock)
+			block := *event.ContentBlock
+			if block.Type == "tool_use" || block.Type == "mcp_tool_use" {
+				// content_block_start carries a placeholder input ({}); the full
+				// arguments arrive via input_json_delta fragments.
+				block.Input = nil
+			}
+			finalResp.Content = append(finalResp.Content, block)
 		}
 		if event.Type == "content_block_delta" && event.Delta != nil && finalResp != nil && event.Index != nil {
 			idx := *event.Index
@@ -302,7 +430,7 @@ func (s *GatewayService) handleResponsesBufferedStreamingResponse(
 
 	if err := scanner.Err(); err != nil {
 		if !errors.Is(err, context.Canceled) && !errors.Is(err, context.DeadlineExceeded) {
-			logger.L().Warn("forward_as_responses buffered: read error",
+			logger.L().Warn(logPrefix+": read error",
 				zap.Error(err),
 				zap.String("request_id", requestID),
 			)
@@ -310,8 +438,12 @@ func (s *GatewayService) handleResponsesBufferedStreamingResponse(
 	}
 
 	if finalResp == nil {
-		writeResponsesError(c, http.StatusBadGateway, "server_error", "Upstream stream ended without a response")
-		return nil, fmt.Errorf("upstream stream ended without response")
+		return nil, usage
+	}
+	for i := range finalResp.Content {
+		if t := finalResp.Content[i].Type; (t == "tool_use" || t == "mcp_tool_use") && len(finalResp.Content[i].Input) == 0 {
+			finalResp.Content[i].Input = json.RawMessage("{}")
+		}
 	}
 
 	// Update usage from accumulated delta
@@ -324,24 +456,7 @@ func (s *GatewayService) handleResponsesBufferedStreamingResponse(
 		}
 	}
 
-	// Convert to Responses format
-	responsesResp := apicompat.AnthropicToResponsesResponse(finalResp)
-	responsesResp.Model = originalModel // Use original model name
-
-	if s.responseHeaderFilter != nil {
-		responseheaders.WriteFilteredHeaders(c.Writer.Header(), resp.Header, s.responseHeaderFilter)
-	}
-	c.JSON(http.StatusOK, responsesResp)
-
-	return &ForwardResult{
-		RequestID:       requestID,
-		Usage:           usage,
-		Model:           originalModel,
-		UpstreamModel:   mappedModel,
-		ReasoningEffort: reasoningEffort,
-		Stream:          false,
-		Duration:        time.Since(startTime),
-	}, nil
+	return finalResp, usage
 }
 
 // handleResponsesStreamingResponse reads Anthropic SSE events from upstream,
@@ -391,6 +506,10 @@ func (s *GatewayService) handleResponsesStreamingResponse(
 		}
 	}
 
+	// out is reused across events: all Responses events converted from one
+	// upstream event are encoded into it and written with a single Write.
+	var out []byte
+
 	// processEvent handles a single parsed Anthropic SSE event.
 	processEvent := func(event *apicompat.AnthropicStreamEvent) bool {
 		if firstChunk {
@@ -410,23 +529,23 @@ func (s *GatewayService) handleResponsesStreamingResponse(
 
 		// Convert to Responses events
 		events := apicompat.AnthropicEventToResponsesEvents(event, state)
-		for _, evt := range events {
-			sse, err := apicompat.ResponsesEventToSSE(evt)
-			if err != nil {
+		out = out[:0]
+		for i := range events {
+			var err error
+			if out, err = apicompat.AppendResponsesEventSSE(out, &events[i]); err != nil {
 				logger.L().Warn("forward_as_responses stream: failed to marshal event",
 					zap.Error(err),
 					zap.String("request_id", requestID),
 				)
-				continue
 			}
-			if _, err := fmt.Fprint(c.Writer, sse); err != nil {
+		}
+		if len(out) > 0 {
+			if _, err := c.Writer.Write(out); err != nil {
 				logger.L().Info("forward_as_responses stream: client disconnected",
 					zap.String("request_id", requestID),
 				)
 				return true // client disconnected
 			}
-		}
-		if len(events) > 0 {
 			c.Writer.Flush()
 		}
 		return false
@@ -434,13 +553,11 @@ func (s *GatewayService) handleResponsesStreamingResponse(
 
 	finalizeStream := func() (*ForwardResult, error) {
 		if finalEvents := apicompat.FinalizeAnthropicResponsesStream(state); len(finalEvents) > 0 {
-			for _, evt := range finalEvents {
-				sse, err := apicompat.ResponsesEventToSSE(evt)
-				if err != nil {
-					continue
-				}
-				fmt.Fprint(c.Writer, sse) //nolint:errcheck
+			out = out[:0]
+			for i := range finalEvents {
+				out, _ = apicompat.AppendResponsesEventSSE(out, &finalEvents[i])
 			}
+			_, _ = c.Writer.Write(out)
 			c.Writer.Flush()
 		}
 		return resultWithUsage(), nil
@@ -448,24 +565,24 @@ func (s *GatewayService) handleResponsesStreamingResponse(
 
 	// Read Anthropic SSE events
 	for scanner.Scan() {
-		line := scanner.Text()
-		if !strings.HasPrefix(line, "event: ") {
+		line := scanner.Bytes()
+		if !bytes.HasPrefix(line, sseEventLinePrefix) {
 			continue
 		}
-		eventType := strings.TrimPrefix(line, "event: ")
+		eventType := string(line[len(sseEventLinePrefix):])
 
 		// Read data line
 		if !scanner.Scan() {
 			break
 		}
-		dataLine := scanner.Text()
-		if !strings.HasPrefix(dataLine, "data: ") {
+		dataLine := scanner.Bytes()
+		if !bytes.HasPrefix(dataLine, sseDataLinePrefix) {
 			continue
 		}
-		payload := dataLine[6:]
+		payload := dataLine[len(sseDataLinePrefix):]
 
 		var event apicompat.AnthropicStreamEvent
-		if err := json.Unmarshal([]byte(payload), &event); err != nil {
+		if err := apicompat.ParseAnthropicStreamEvent(payload, &event); err != nil {
 			logger.L().Warn("forward_as_responses stream: failed to parse event",
 				zap.Error(err),
 				zap.String("request_id", requestID),
@@ -0,0 +1,301 @@
+package service
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/ShaohongDong/sub2api/internal/pkg/geminicli"
+	"github.com/ShaohongDong/sub2api/internal/pkg/logger"
+	"github.com/ShaohongDong/sub2api/internal/util/responseheaders"
+	"github.com/gin-gonic/gin"
+	"github.com/tidwall/gjson"
+	"github.com/tidwall/sjson"
+	"go.uber.org/zap"
+)
+
+// geminiOpenAICompatChatPath is Google's OpenAI-compatible Chat Completions
+// endpoint, relative to the AI Studio base URL.
+const geminiOpenAICompatChatPath = "/v1beta/openai/chat/completions"
+
+// geminiOpenAICompatDroppedFields are Chat Completions fields the Gemini
+// compatibility endpoint rejects; they only affect OpenAI-side bookkeeping.
+var geminiOpenAICompatDroppedFields = []string{
+	"store",
+	"metadata",
+	"service_tier",
+	"prompt_cache_key",
+	"safety_identifier",
+}
+
+// IsGeminiOpenAICompatEgress reports whether Chat Completions requests for this
+// account can be sent to Google's OpenAI-compatible endpoint. The endpoint
+// only accepts AI Studio API keys (Bearer auth), so OAuth / Code Assist
+// accounts are excluded.
+func (a *Account) IsGeminiOpenAICompatEgress() bool {
+	return a != nil && a.Platform == PlatformGemini && a.Type == AccountTypeAPIKey
+}
+
+// forwardChatCompletionsToGeminiOpenAICompat serves a Chat Completions request
+// from a Gemini AI Studio API-key account via Google's OpenAI-compatible
+// endpoint. The client body is passed through with only the model mapped, so
+// tools / tool_choice / tool_calls keep their OpenAI semantics instead of
+// going through a generateContent translation.
+func (s *GatewayService) forwardChatCompletionsToGeminiOpenAICompat(
+	ctx context.Context,
+	c *gin.Context,
+	account *Account,
+	body []byte,
+	originalModel string,
+	clientStream bool,
+	includeUsage bool,
+	reasoningEffort *string,
+) (*ForwardResult, error) {
+	startTime := time.Now()
+
+	mappedModel := account.GetMappedModel(originalModel)
+	upstreamBody, err := buildGeminiOpenAICompatBody(body, mappedModel, clientStream)
+	if err != nil {
+		writeOpenAICompatError(c, http.StatusBadRequest, err.Error())
+		return nil, fmt.Errorf("build gemini openai compat body: %w", err)
+	}
+
+	logger.L().Debug("gateway gemini openai compat: model mapping applied",
+		zap.Int64("account_id", account.ID),
+		zap.String("original_model", originalModel),
+		zap.String("mapped_model", mappedModel),
+		zap.Bool("client_stream", clientStream),
+	)
+
+	apiKey := strings.TrimSpace(account.GetCredential("api_key"))
+	if apiKey == "" {
+		writeOpenAICompatError(c, http.StatusBadGateway, "Upstream account is missing an API key")
+		return nil, errors.New("gemini api_key not configured")
+	}
+	baseURL, err := s.validateUpstreamBaseURL(account.GetGeminiBaseURL(geminicli.AIStudioBaseURL))
+	if err != nil {
+		writeOpenAICompatError(c, http.StatusBadGateway, "Upstream base URL is invalid")
+		return nil, err
+	}
+
+	upstreamReq, err := http.NewRequestWithContext(ctx, http.MethodPost, strings.TrimRight(baseURL, "/")+geminiOpenAICompatChatPath, bytes.NewReader(upstreamBody))
+	if err != nil {
+		return nil, fmt.Errorf("build upstream request: %w", err)
+	}
+	upstreamReq.Header.Set("Content-Type", "application/json")
+	upstreamReq.Header.Set("Authorization", "Bearer "+apiKey)
+
+	proxyURL := ""
+	if account.ProxyID != nil && account.Proxy != nil {
+		proxyURL = account.Proxy.URL()
+	}
+
+	resp, err := s.httpUpstream.DoWithTLS(upstreamReq, proxyURL, account.ID, account.Concurrency, account.IsTLSFingerprintEnabled())
+	if err != nil {
+		if resp != nil && resp.Body != nil {
+			_ = resp.Body.Close()
+		}
+		safeErr := sanitizeUpstreamErrorMessage(err.Error())
+		setOpsUpstreamError(c, 0, safeErr, "")
+		appendOpsUpstreamError(c, OpsUpstreamErrorEvent{
+			Platform:           account.Platform,
+			AccountID:          account.ID,
+			AccountName:        account.Name,
+			UpstreamStatusCode: 0,
+			Kind:               "request_error",
+			Message:            safeErr,
+		})
+		writeOpenAICompatError(c, http.StatusBadGateway, "Upstream request failed")
+		return nil, fmt.Errorf("upstream request failed: %s", safeErr)
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode >= 400 {
+		respBody, _ := io.ReadAll(io.LimitReader(resp.Body, 2<<20))
+		upstreamMsg := sanitizeUpstreamErrorMessage(strings.TrimSpace(extractUpstreamErrorMessage(respBody)))
+		if s.shouldFailoverUpstreamError(resp.StatusCode) {
+			appendOpsUpstreamError(c, OpsUpstreamErrorEvent{
+				Platform:           account.Platform,
+				AccountID:          account.ID,
+				AccountName:        account.Name,
+				UpstreamStatusCode: resp.StatusCode,
+				UpstreamRequestID:  geminiOpenAICompatRequestID(resp.Header),
+				Kind:               "failover",
+				Message:            upstreamMsg,
+			})
+			if s.rateLimitService != nil {
+				s.rateLimitService.HandleUpstreamError(ctx, account, resp.StatusCode, resp.Header, respBody)
+			}
+			return nil, &UpstreamFailoverError{
+				StatusCode:      resp.StatusCode,
+				ResponseBody:    respBody,
+				ResponseHeaders: resp.Header.Clone(),
+			}
+		}
+		writeOpenAICompatError(c, mapUpstreamStatusCode(resp.StatusCode), upstreamMsg)
+		return nil, fmt.Errorf("upstream error: %d %s", resp.StatusCode, upstreamMsg)
+	}
+
+	if s.responseHeaderFilter != nil {
+		responseheaders.WriteFilteredHeaders(c.Writer.Header(), resp.Header, s.responseHeaderFilter)
+	}
+	result := &ForwardResult{
+		RequestID:       geminiOpenAICompatRequestID(resp.Header),
+		Model:           originalModel,
+		UpstreamModel:   mappedModel,
+		ReasoningEffort: reasoningEffort,
+		Stream:          clientStream,
+	}
+	if clientStream {
+		err = s.streamGeminiOpenAICompatResponse(resp.Body, c, result, originalModel, includeUsage, startTime)
+	} else {
+		err = s.writeGeminiOpenAICompatResponse(resp.Body, c, result, originalModel)
+	}
+	result.Duration = time.Since(startTime)
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// buildGeminiOpenAICompatBody rewrites the client body for the Gemini
+// compatibility endpoint: maps the model, drops unsupported fields and, for
+// streams, always asks for the trailing usage chunk so the request is billed.
+func buildGeminiOpenAICompatBody(body []byte, mappedModel string, stream bool) ([]byte, error) {
+	out, err := sjson.SetBytes(body, "model", mappedModel)
+	if err != nil {
+		return nil, err
+	}
+	for _, field := range geminiOpenAICompatDroppedFields {
+		if gjson.GetBytes(out, field).Exists() {
+			if out, err = sjson.DeleteBytes(out, field); err != nil {
+				return nil, err
+			}
+		}
+	}
+	if stream {
+		if out, err = sjson.SetBytes(out, "stream_options.include_usage", true); err != nil {
+			return nil, err
+		}
+	}
+	return out, nil
+}
+
+// writeGeminiOpenAICompatResponse relays a non-streaming chat.completion,
+// restoring the client's model name.
+func (s *GatewayService) writeGeminiOpenAICompatResponse(body io.Reader, c *gin.Context, result *ForwardResult, originalModel string) error {
+	respBody, err := io.ReadAll(body)
+	if err != nil {
+		writeOpenAICompatError(c, http.StatusBadGateway, "Failed to read upstream response")
+		return fmt.Errorf("read upstream response: %w", err)
+	}
+	if usage, ok := geminiOpenAICompatUsage(gjson.GetBytes(respBody, "usage")); ok {
+		result.Usage = usage
+	}
+	if out, err := sjson.SetBytes(respBody, "model", originalModel); err == nil {
+		respBody = out
+	}
+	c.Data(http.StatusOK, "application/json", respBody)
+	return nil
+}
+
+// streamGeminiOpenAICompatResponse relays chat.completion.chunk events,
+// restoring the client's model name. The usage chunk forced upstream is only
+// forwarded when the client asked for it via stream_options.include_usage.
+func (s *GatewayService) streamGeminiOpenAICompatResponse(body io.Reader, c *gin.Context, result *ForwardResult, originalModel string, includeUsage bool, startTime time.Time) error {
+	c.Writer.Header().Set("Content-Type", "text/event-stream")
+	c.Writer.Header().Set("Cache-Control", "no-cache")
+	c.Writer.Header().Set("Connection", "keep-alive")
+	c.Writer.Header().Set("X-Accel-Buffering", "no")
+	c.Writer.WriteHeader(http.StatusOK)
+
+	scanner := bufio.NewScanner(body)
+	maxLineSize := defaultMaxLineSize
+	if s.cfg != nil && s.cfg.Gateway.MaxLineSize > 0 {
+		maxLineSize = s.cfg.Gateway.MaxLineSize
+	}
+	scanner.Buffer(make([]byte, 0, 64*1024), maxLineSize)
+
+	// After the client disconnects keep draining so the usage chunk is still billed.
+	clientGone := false
+	for scanner.Scan() {
+		payload, ok := strings.CutPrefix(scanner.Text(), "data:")
+		if !ok {
+			continue
+		}
+		payload = strings.TrimSpace(payload)
+		if payload == "[DONE]" {
+			break
+		}
+		chunk := []byte(payload)
+		if usage, ok := geminiOpenAICompatUsage(gjson.GetBytes(chunk, "usage")); ok {
+			result.Usage = usage
+			if !includeUsage {
+				if len(gjson.GetBytes(chunk, "choices").Array()) == 0 {
+					continue
+				}
+				chunk, _ = sjson.DeleteBytes(chunk, "usage")
+			}
+		}
+		if out, err := sjson.SetBytes(chunk, "model", originalModel); err == nil {
+			chunk = out
+		}
+		if result.FirstTokenMs == nil {
+			ms := int(time.Since(startTime).Milliseconds())
+			result.FirstTokenMs = &ms
+		}
+		if clientGone {
+			continue
+		}
+		if _, err := fmt.Fprintf(c.Writer, "data: %s\n\n", chunk); err != nil {
+			logger.L().Info("gemini openai compat stream: client disconnected",
+				zap.String("request_id", result.RequestID),
+			)
+			clientGone = true
+			continue
+		}
+		c.Writer.Flush()
+	}
+	if err := scanner.Err(); err != nil {
+		if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+			return nil
+		}
+		if !clientGone {
+			writeOpenAICompatStreamError(c, http.StatusBadGateway, "Upstream stream interrupted")
+		}
+		return fmt.Errorf("read upstream stream: %w", err)
+	}
+	if clientGone {
+		return nil
+	}
+	_, _ = fmt.Fprint(c.Writer, "data: [DONE]\n\n")
+	c.Writer.Flush()
+	return nil
+}
+
+// geminiOpenAICompatUsage converts Chat Completions usage to ClaudeUsage.
+// prompt_tokens includes cached tokens, which are billed separately.
+func geminiOpenAICompatUsage(usage gjson.Result) (ClaudeUsage, bool) {
+	if !usage.IsObject() {
+		return ClaudeUsage{}, false
+	}
+	cached := int(usage.Get("prompt_tokens_details.cached_tokens").Int())
+	return ClaudeUsage{
+		InputTokens:          int(usage.Get("prompt_tokens").Int()) - cached,
+		OutputTokens:         int(usage.Get("completion_tokens").Int()),
+		CacheReadInputTokens: cached,
+	}, true
+}
+
+func geminiOpenAICompatRequestID(header http.Header) string {
+	if id := header.Get("x-request-id"); id != "" {
+		return id
+	}
+	return header.Get("x-goog-request-id")
+}
@@ -0,0 +1,122 @@
+package service
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/ShaohongDong/sub2api/internal/config"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/require"
+	"github.com/tidwall/gjson"
+)
+
+func newGeminiOpenAICompatTestAccount() *Account {
+	return &Account{
+		ID:          31,
+		Name:        "gemini-key",
+		Platform:    PlatformGemini,
+		Type:        AccountTypeAPIKey,
+		Concurrency: 1,
+		Credentials: map[string]any{
+			"api_key":       "AIza-test",
+			"model_mapping": map[string]any{"gpt-4o": "gemini-2.5-flash"},
+		},
+	}
+}
+
+func TestAccountIsGeminiOpenAICompatEgress(t *testing.T) {
+	require.True(t, newGeminiOpenAICompatTestAccount().IsGeminiOpenAICompatEgress())
+	require.False(t, (&Account{Platform: PlatformGemini, Type: AccountTypeOAuth}).IsGeminiOpenAICompatEgress())
+	require.False(t, (&Account{Platform: PlatformOpenAI, Type: AccountTypeAPIKey}).IsGeminiOpenAICompatEgress())
+}
+
+func TestBuildGeminiOpenAICompatBody(t *testing.T) {
+	body, err := buildGeminiOpenAICompatBody([]byte(`{"model":"gpt-4o","stream":true,"store":true,"metadata":{"a":"b"},"tools":[{"type":"function","function":{"name":"lookup"}}]}`), "gemini-2.5-flash", true)
+	require.NoError(t, err)
+	parsed := gjson.ParseBytes(body)
+	require.Equal(t, "gemini-2.5-flash", parsed.Get("model").String())
+	require.False(t, parsed.Get("store").Exists())
+	require.False(t, parsed.Get("metadata").Exists())
+	require.True(t, parsed.Get("stream_options.include_usage").Bool())
+	require.Equal(t, "lookup", parsed.Get("tools.0.function.name").String())
+}
+
+func TestGatewayForwardAsChatCompletions_GeminiOpenAICompatBuffered(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	rec := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(rec)
+	c.Request = httptest.NewRequest(http.MethodPost, "/v1/chat/completions", nil)
+
+	upstream := &httpUpstreamRecorder{resp: &http.Response{
+		StatusCode: http.StatusOK,
+		Header:     http.Header{"Content-Type": []string{"application/json"}},
+		Body: io.NopCloser(strings.NewReader(`{"id":"c1","object":"chat.completion","model":"gemini-2.5-flash",` +
+			`"choices":[{"index":0,"message":{"role":"assistant","tool_calls":[{"id":"call_1","type":"function","function":{"name":"lookup","arguments":"{}"}}]},"finish_reason":"tool_calls"}],` +
+			`"usage":{"prompt_tokens":12,"completion_tokens":5,"prompt_tokens_details":{"cached_tokens":4}}}`)),
+	}}
+	svc := &GatewayService{cfg: &config.Config{}, httpUpstream: upstream}
+
+	body := []byte(`{"model":"gpt-4o","messages":[{"role":"user","content":"hi"}],"tools":[{"type":"function","function":{"name":"lookup"}}]}`)
+	result, err := svc.ForwardAsChatCompletions(context.Background(), c, newGeminiOpenAICompatTestAccount(), body, nil)
+	require.NoError(t, err)
+
+	require.Equal(t, "https://generativelanguage.googleapis.com/v1beta/openai/chat/completions", upstream.lastReq.URL.String())
+	require.Equal(t, "Bearer AIza-test", upstream.lastReq.Header.Get("Authorization"))
+	require.Equal(t, "gemini-2.5-flash", gjson.GetBytes(upstream.lastBody, "model").String())
+
+	require.Equal(t, http.StatusOK, rec.Code)
+	require.Equal(t, "gpt-4o", gjson.Get(rec.Body.String(), "model").String())
+	require.Equal(t, "lookup", gjson.Get(rec.Body.String(), "choices.0.message.tool_calls.0.function.name").String())
+	require.Equal(t, "gemini-2.5-flash", result.UpstreamModel)
+	require.Equal(t, ClaudeUsage{InputTokens: 8, OutputTokens: 5, CacheReadInputTokens: 4}, result.Usage)
+}
+
+func TestGatewayForwardAsChatCompletions_GeminiOpenAICompatStreamHidesForcedUsage(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	rec := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(rec)
+	c.Request = httptest.NewRequest(http.MethodPost, "/v1/chat/completions", nil)
+
+	upstream := &httpUpstreamRecorder{resp: &http.Response{
+		StatusCode: http.StatusOK,
+		Header:     http.Header{"Content-Type": []string{"text/event-stream"}},
+		Body: io.NopCloser(strings.NewReader(strings.Join([]string{
+			`data: {"id":"c1","object":"chat.completion.chunk","model":"gemini-2.5-flash","choices":[{"index":0,"delta":{"content":"Hi"}}]}`,
+			``,
+			`data: {"id":"c1","object":"chat.completion.chunk","model":"gemini-2.5-flash","choices":[],"usage":{"prompt_tokens":3,"completion_tokens":1}}`,
+			``,
+			`data: [DONE]`,
+			``,
+		}, "\n"))),
+	}}
+	svc := &GatewayService{cfg: &config.Config{}, httpUpstream: upstream}
+
+	body := []byte(`{"model":"gpt-4o","stream":true,"messages":[{"role":"user","content":"hi"}]}`)
+	result, err := svc.ForwardAsChatCompletions(context.Background(), c, newGeminiOpenAICompatTestAccount(), body, nil)
+	require.NoError(t, err)
+	require.True(t, gjson.GetBytes(upstream.lastBody, "stream_options.include_usage").Bool())
+
+	out := rec.Body.String()
+	require.Contains(t, out, `"content":"Hi"`)
+	require.Contains(t, out, `"model":"gpt-4o"`)
+	require.NotContains(t, out, `"usage"`)
+	require.True(t, strings.HasSuffix(out, "data: [DONE]\n\n"))
+	require.Equal(t, ClaudeUsage{InputTokens: 3, OutputTokens: 1}, result.Usage)
+	require.NotNil(t, result.FirstTokenMs)
+}
+
+func TestGatewayForwardAsChatCompletions_GeminiOAuthRejected(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	rec := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(rec)
+	c.Request = httptest.NewRequest(http.MethodPost, "/v1/chat/completions", nil)
+
+	svc := &GatewayService{cfg: &config.Config{}}
+	_, err := svc.ForwardAsChatCompletions(context.Background(), c, &Account{ID: 1, Platform: PlatformGemini, Type: AccountTypeOAuth}, []byte(`{"model":"gemini-2.5-pro","messages":[]}`), nil)
+	require.Error(t, err)
+	require.Equal(t, http.StatusBadRequest, rec.Code)
+}
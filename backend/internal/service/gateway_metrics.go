@@ -0,0 +1,216 @@
+package service
+
+import (
+	"context"
+	"io"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/ShaohongDong/sub2api/internal/config"
+	"github.com/ShaohongDong/sub2api/internal/pkg/logger"
+	"github.com/ShaohongDong/sub2api/internal/pkg/promtext"
+	"go.uber.org/zap"
+)
+
+// 网关 Prometheus 指标（metrics.enabled）。
+// 请求与用量计数为进程内累计（与 idempotency 指标一致使用包级状态），账号健康与冷却在抓取时从账号表读取。
+
+var gatewayLatencyBuckets = []float64{0.1, 0.25, 0.5, 1, 2.5, 5, 10, 20, 30, 60, 120, 300}
+
+type gatewayMetricsRegistry struct {
+	requests        *promtext.CounterVec
+	requestDuration *promtext.HistogramVec
+	firstToken      *promtext.HistogramVec
+	tokens          *promtext.CounterVec
+	cost            *promtext.CounterVec
+
+	// apiKeyLabels 为 false 时 api_key_id 标签留空，控制 Key 数量大时的序列基数
+	apiKeyLabels  atomic.Bool
+	priorityQueue atomic.Pointer[PriorityRequestQueue]
+}
+
+var gatewayMetrics = newGatewayMetricsRegistry()
+
+func newGatewayMetricsRegistry() *gatewayMetricsRegistry {
+	r := &gatewayMetricsRegistry{
+		requests: promtext.NewCounterVec("sub2api_gateway_requests_total",
+			"Gateway inference requests by final HTTP status.",
+			"platform", "model", "account_id", "api_key_id", "status"),
+		requestDuration: promtext.NewHistogramVec("sub2api_gateway_request_duration_seconds",
+			"Gateway request latency including streaming time.",
+			gatewayLatencyBuckets, "platform", "model"),
+		firstToken: promtext.NewHistogramVec("sub2api_gateway_first_token_seconds",
+			"Time to first upstream token for streamed requests.",
+			gatewayLatencyBuckets, "model"),
+		tokens: promtext.NewCounterVec("sub2api_gateway_tokens_total",
+			"Billed tokens by type (input, output, cache_creation, cache_read).",
+			"model", "account_id", "api_key_id", "type"),
+		cost: promtext.NewCounterVec("sub2api_gateway_cost_usd_total",
+			"Actual billed cost in USD.",
+			"model", "account_id", "api_key_id"),
+	}
+	r.apiKeyLabels.Store(true)
+	return r
+}
+
+func (r *gatewayMetricsRegistry) apiKeyLabel(apiKeyID int64) string {
+	if !r.apiKeyLabels.Load() || apiKeyID <= 0 {
+		return ""
+	}
+	return strconv.FormatInt(apiKeyID, 10)
+}
+
+func idLabel(id int64) string {
+	if id <= 0 {
+		return ""
+	}
+	return strconv.FormatInt(id, 10)
+}
+
+// RecordGatewayRequestMetrics 记录一次网关请求的最终状态与耗时
+func RecordGatewayRequestMetrics(platform, model string, accountID, apiKeyID int64, status int, duration time.Duration) {
+	r := gatewayMetrics
+	r.requests.Inc(platform, model, idLabel(accountID), r.apiKeyLabel(apiKeyID), strconv.Itoa(status))
+	r.requestDuration.Observe(duration.Seconds(), platform, model)
+}
+
+// recordUsageLogMetrics 在用量日志写入成功后累计 token 与费用（幂等重试跳过的记录不计入）
+func recordUsageLogMetrics(log *UsageLog) {
+	if log == nil {
+		return
+	}
+	r := gatewayMetrics
+	account, apiKey := idLabel(log.AccountID), r.apiKeyLabel(log.APIKeyID)
+	r.tokens.Add(float64(log.InputTokens), log.Model, account, apiKey, "input")
+	r.tokens.Add(float64(log.OutputTokens), log.Model, account, apiKey, "output")
+	if log.CacheCreationTokens > 0 {
+		r.tokens.Add(float64(log.CacheCreationTokens), log.Model, account, apiKey, "cache_creation")
+	}
+	if log.CacheReadTokens > 0 {
+		r.tokens.Add(float64(log.CacheReadTokens), log.Model, account, apiKey, "cache_read")
+	}
+	r.cost.Add(log.ActualCost, log.Model, account, apiKey)
+	if log.Stream && log.FirstTokenMs != nil {
+		r.firstToken.Observe(float64(*log.FirstTokenMs)/1000, log.Model)
+	}
+}
+
+// registerPriorityQueueMetrics 登记用于导出队列深度的优先级队列（进程内仅在注册路由时创建一个）
+func registerPriorityQueueMetrics(q *PriorityRequestQueue) {
+	gatewayMetrics.priorityQueue.Store(q)
+}
+
+// metricsAccountCacheTTL 账号健康指标的缓存时长，避免高频抓取时反复查询账号表
+const metricsAccountCacheTTL = 10 * time.Second
+
+// MetricsService 以 Prometheus 文本格式导出网关指标
+type MetricsService struct {
+	cfg         config.MetricsConfig
+	accountRepo AccountRepository
+
+	mu         sync.Mutex
+	accounts   []Account
+	accountsAt time.Time
+}
+
+// NewMetricsService 创建指标服务
+func NewMetricsService(cfg *config.Config, accountRepo AccountRepository) *MetricsService {
+	gatewayMetrics.apiKeyLabels.Store(cfg.Metrics.APIKeyLabels)
+	return &MetricsService{cfg: cfg.Metrics, accountRepo: accountRepo}
+}
+
+// Enabled 是否开放 /metrics
+func (s *MetricsService) Enabled() bool {
+	return s != nil && s.cfg.Enabled
+}
+
+// AuthToken 抓取所需的 Bearer 令牌，为空时不校验
+func (s *MetricsService) AuthToken() string {
+	return s.cfg.AuthToken
+}
+
+// WriteMetrics 写出全部指标
+func (s *MetricsService) WriteMetrics(ctx context.Context, out io.Writer) error {
+	w := promtext.NewWriter(out)
+	r := gatewayMetrics
+	r.requests.Write(w)
+	r.requestDuration.Write(w)
+	r.firstToken.Write(w)
+	r.tokens.Write(w)
+	r.cost.Write(w)
+	writePriorityQueueMetrics(w, r.priorityQueue.Load())
+	writeAccountMetrics(w, s.loadAccounts(ctx), time.Now())
+	return w.Flush()
+}
+
+func (s *MetricsService) loadAccounts(ctx context.Context) []Account {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.accounts != nil && time.Since(s.accountsAt) < metricsAccountCacheTTL {
+		return s.accounts
+	}
+	accounts, err := s.accountRepo.ListActive(ctx)
+	if err != nil {
+		// 查询失败时沿用上次结果，不让抓取整体失败
+		logger.FromContext(ctx).Warn("metrics.list_accounts_failed", zap.Error(err))
+		return s.accounts
+	}
+	s.accounts, s.accountsAt = accounts, time.Now()
+	return accounts
+}
+
+func writePriorityQueueMetrics(w *promtext.Writer, q *PriorityRequestQueue) {
+	var depth, inFlight []promtext.Sample
+	for groupID, stats := range q.Stats() {
+		labels, values := []string{"group_id"}, []string{strconv.FormatInt(groupID, 10)}
+		depth = append(depth, promtext.Sample{Labels: labels, Values: values, Value: float64(stats.Queued)})
+		inFlight = append(inFlight, promtext.Sample{Labels: labels, Values: values, Value: float64(stats.InFlight)})
+	}
+	w.WriteFamily("sub2api_priority_queue_depth", "Requests waiting in the per-group priority queue.", "gauge", depth)
+	w.WriteFamily("sub2api_priority_queue_in_flight", "Requests holding a per-group priority queue slot.", "gauge", inFlight)
+}
+
+func writeAccountMetrics(w *promtext.Writer, accounts []Account, now time.Time) {
+	healthLabels := []string{"account_id", "account_name", "platform"}
+	cooldownLabels := []string{"account_id", "platform", "reason"}
+	schedulable := make([]promtext.Sample, 0, len(accounts))
+	circuitOpen := make([]promtext.Sample, 0, len(accounts))
+	var cooldown []promtext.Sample
+	remaining := func(until *time.Time) float64 {
+		if until == nil || !now.Before(*until) {
+			return 0
+		}
+		return until.Sub(now).Seconds()
+	}
+	for i := range accounts {
+		a := &accounts[i]
+		id := strconv.FormatInt(a.ID, 10)
+		health := []string{id, a.Name, a.Platform}
+		schedulable = append(schedulable, promtext.Sample{Labels: healthLabels, Values: health, Value: boolGauge(a.IsSchedulable())})
+		circuitOpen = append(circuitOpen, promtext.Sample{Labels: healthLabels, Values: health, Value: boolGauge(!AccountCircuitAllows(a.ID))})
+		for _, c := range []struct {
+			reason string
+			until  *time.Time
+		}{
+			{"rate_limit", a.RateLimitResetAt},
+			{"overload", a.OverloadUntil},
+			{"temp_unschedulable", a.TempUnschedulableUntil},
+		} {
+			if secs := remaining(c.until); secs > 0 {
+				cooldown = append(cooldown, promtext.Sample{Labels: cooldownLabels, Values: []string{id, a.Platform, c.reason}, Value: secs})
+			}
+		}
+	}
+	w.WriteFamily("sub2api_account_schedulable", "Whether an active account can currently be scheduled (1) or not (0).", "gauge", schedulable)
+	w.WriteFamily("sub2api_account_circuit_open", "Whether the account circuit breaker is open.", "gauge", circuitOpen)
+	w.WriteFamily("sub2api_account_cooldown_remaining_seconds", "Remaining cooldown for accounts that are rate limited, overloaded or temporarily unschedulable.", "gauge", cooldown)
+}
+
+func boolGauge(v bool) float64 {
+	if v {
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,121 @@
+//go:build unit
+
+package service
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/ShaohongDong/sub2api/internal/config"
+	"github.com/stretchr/testify/require"
+)
+
+type metricsAccountRepoStub struct {
+	AccountRepository
+	accounts []Account
+	err      error
+	calls    int
+}
+
+func (s *metricsAccountRepoStub) ListActive(ctx context.Context) ([]Account, error) {
+	s.calls++
+	return s.accounts, s.err
+}
+
+func useFreshGatewayMetrics(t *testing.T) {
+	t.Helper()
+	old := gatewayMetrics
+	gatewayMetrics = newGatewayMetricsRegistry()
+	t.Cleanup(func() { gatewayMetrics = old })
+}
+
+func newTestMetricsService(repo AccountRepository, apiKeyLabels bool) *MetricsService {
+	cfg := &config.Config{}
+	cfg.Metrics.Enabled = true
+	cfg.Metrics.APIKeyLabels = apiKeyLabels
+	return NewMetricsService(cfg, repo)
+}
+
+func TestMetricsServiceWritesRequestAndUsageMetrics(t *testing.T) {
+	useFreshGatewayMetrics(t)
+	svc := newTestMetricsService(&metricsAccountRepoStub{}, true)
+
+	RecordGatewayRequestMetrics(PlatformAnthropic, "claude-sonnet-4-5", 3, 7, 200, 1500*time.Millisecond)
+	RecordGatewayRequestMetrics(PlatformAnthropic, "claude-sonnet-4-5", 0, 7, 503, 20*time.Millisecond)
+	firstToken := 800
+	recordUsageLogMetrics(&UsageLog{
+		APIKeyID: 7, AccountID: 3, Model: "claude-sonnet-4-5",
+		InputTokens: 100, OutputTokens: 20, CacheReadTokens: 50, ActualCost: 0.25,
+		Stream: true, FirstTokenMs: &firstToken,
+	})
+
+	var buf bytes.Buffer
+	require.NoError(t, svc.WriteMetrics(context.Background(), &buf))
+	out := buf.String()
+	require.Contains(t, out, `sub2api_gateway_requests_total{platform="anthropic",model="claude-sonnet-4-5",account_id="3",api_key_id="7",status="200"} 1`)
+	require.Contains(t, out, `sub2api_gateway_requests_total{platform="anthropic",model="claude-sonnet-4-5",account_id="",api_key_id="7",status="503"} 1`)
+	require.Contains(t, out, `sub2api_gateway_request_duration_seconds_bucket{platform="anthropic",model="claude-sonnet-4-5",le="2.5"} 2`)
+	require.Contains(t, out, `sub2api_gateway_request_duration_seconds_count{platform="anthropic",model="claude-sonnet-4-5"} 2`)
+	require.Contains(t, out, `sub2api_gateway_tokens_total{model="claude-sonnet-4-5",account_id="3",api_key_id="7",type="input"} 100`)
+	require.Contains(t, out, `sub2api_gateway_tokens_total{model="claude-sonnet-4-5",account_id="3",api_key_id="7",type="cache_read"} 50`)
+	require.NotContains(t, out, `type="cache_creation"`)
+	require.Contains(t, out, `sub2api_gateway_cost_usd_total{model="claude-sonnet-4-5",account_id="3",api_key_id="7"} 0.25`)
+	require.Contains(t, out, `sub2api_gateway_first_token_seconds_bucket{model="claude-sonnet-4-5",le="1"} 1`)
+}
+
+func TestMetricsServiceOmitsAPIKeyLabelsWhenDisabled(t *testing.T) {
+	useFreshGatewayMetrics(t)
+	svc := newTestMetricsService(&metricsAccountRepoStub{}, false)
+
+	RecordGatewayRequestMetrics(PlatformOpenAI, "gpt-5", 1, 42, 200, time.Second)
+
+	var buf bytes.Buffer
+	require.NoError(t, svc.WriteMetrics(context.Background(), &buf))
+	require.Contains(t, buf.String(), `sub2api_gateway_requests_total{platform="openai",model="gpt-5",account_id="1",api_key_id="",status="200"} 1`)
+}
+
+func TestMetricsServiceWritesAccountHealthAndQueueDepth(t *testing.T) {
+	useFreshGatewayMetrics(t)
+	resetAt := time.Now().Add(90 * time.Second)
+	repo := &metricsAccountRepoStub{accounts: []Account{
+		{ID: 1, Name: "healthy", Platform: PlatformAnthropic, Status: StatusActive, Schedulable: true},
+		{ID: 2, Name: "limited", Platform: PlatformOpenAI, Status: StatusActive, Schedulable: true, RateLimitResetAt: &resetAt},
+	}}
+	svc := newTestMetricsService(repo, true)
+
+	queue := NewPriorityRequestQueue(config.GatewayPriorityQueueConfig{Enabled: true, MaxInFlightPerGroup: 1, MaxQueuePerGroup: 4, MaxWaitSeconds: 5})
+	release, err := queue.Acquire(context.Background(), 9, 0)
+	require.NoError(t, err)
+	defer release()
+
+	var buf bytes.Buffer
+	require.NoError(t, svc.WriteMetrics(context.Background(), &buf))
+	out := buf.String()
+	require.Contains(t, out, `sub2api_account_schedulable{account_id="1",account_name="healthy",platform="anthropic"} 1`)
+	require.Contains(t, out, `sub2api_account_schedulable{account_id="2",account_name="limited",platform="openai"} 0`)
+	require.Contains(t, out, `sub2api_account_cooldown_remaining_seconds{account_id="2",platform="openai",reason="rate_limit"} `)
+	require.NotContains(t, out, `account_id="1",platform="anthropic",reason=`)
+	require.Contains(t, out, `sub2api_priority_queue_in_flight{group_id="9"} 1`)
+	require.Contains(t, out, `sub2api_priority_queue_depth{group_id="9"} 0`)
+}
+
+func TestMetricsServiceCachesAccountsAndKeepsLastOnError(t *testing.T) {
+	useFreshGatewayMetrics(t)
+	repo := &metricsAccountRepoStub{accounts: []Account{{ID: 1, Name: "a", Platform: PlatformAnthropic, Status: StatusActive, Schedulable: true}}}
+	svc := newTestMetricsService(repo, true)
+
+	var buf bytes.Buffer
+	require.NoError(t, svc.WriteMetrics(context.Background(), &buf))
+	require.NoError(t, svc.WriteMetrics(context.Background(), &buf))
+	require.Equal(t, 1, repo.calls)
+
+	svc.accountsAt = time.Now().Add(-metricsAccountCacheTTL)
+	repo.err = errors.New("db down")
+	buf.Reset()
+	require.NoError(t, svc.WriteMetrics(context.Background(), &buf))
+	require.Equal(t, 2, repo.calls)
+	require.Contains(t, buf.String(), `sub2api_account_schedulable{account_id="1",account_name="a",platform="anthropic"} 1`)
+}
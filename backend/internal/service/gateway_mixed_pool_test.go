@@ -0,0 +1,112 @@
+//go:build unit
+
+package service
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/ShaohongDong/sub2api/internal/pkg/ctxkey"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/require"
+	"github.com/tidwall/gjson"
+)
+
+// TestMixedPool_RoutesChatGPTKeyToClaudeOAuthAndRecordsUsage ChatGPT 与 Claude 订阅混合池：
+// OpenAI 分组的 Key 请求 claude-* 模型时由路由规则改道到 Claude OAuth 分组，
+// 请求以 OAuth token 与 Claude Code 系统提示词发往上游，用量按改道后的分组与账号落库。
+func TestMixedPool_RoutesChatGPTKeyToClaudeOAuthAndRecordsUsage(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	ctx := context.Background()
+
+	chatGPTGroup := &Group{ID: 1, Platform: PlatformOpenAI, Status: StatusActive, SubscriptionType: SubscriptionTypeStandard, RateMultiplier: 1}
+	claudeGroup := &Group{ID: 2, Platform: PlatformAnthropic, Status: StatusActive, SubscriptionType: SubscriptionTypeStandard, RateMultiplier: 1, Hydrated: true}
+	apiKey := &APIKey{ID: 9, UserID: 5, GroupID: &chatGPTGroup.ID, Group: chatGPTGroup}
+
+	// 1. 路由规则命中并允许跨平台改道
+	rules := &RoutingRuleSettings{Enabled: true, Rules: []RoutingRule{
+		{Name: "claude subscriptions", Enabled: true, Models: []string{"claude-*"}, TargetGroupID: claudeGroup.ID},
+	}}
+	rule := MatchRoutingRule(rules, &RoutingRequest{Model: "claude-sonnet-4-5", APIKey: apiKey})
+	require.NotNil(t, rule)
+	require.Equal(t, claudeGroup.ID, rule.TargetGroupID)
+	require.False(t, CanRouteToGroup(chatGPTGroup, claudeGroup), "same-platform routing must not allow the switch")
+	require.True(t, CanRouteAcrossPlatforms(chatGPTGroup, claudeGroup))
+	require.Nil(t, MatchRoutingRule(rules, &RoutingRequest{Model: "gpt-5", APIKey: apiKey}), "ChatGPT models stay in the ChatGPT pool")
+
+	routedKey := *apiKey
+	routedKey.GroupID = &claudeGroup.ID
+	routedKey.Group = claudeGroup
+	// 与 RoutingRules 中间件一致：改道后的分组写入请求上下文
+	ctx = context.WithValue(ctx, ctxkey.Group, claudeGroup)
+
+	// 2. 在 Claude 分组内调度，只会选到该分组的 OAuth 账号
+	accounts := []Account{
+		{ID: 10, Platform: PlatformOpenAI, Type: AccountTypeOAuth, Priority: 1, Status: StatusActive, Schedulable: true,
+			AccountGroups: []AccountGroup{{GroupID: chatGPTGroup.ID}}},
+		{ID: 20, Platform: PlatformAnthropic, Type: AccountTypeOAuth, Priority: 1, Status: StatusActive, Schedulable: true, Concurrency: 1,
+			Credentials:   map[string]any{"access_token": "claude-oauth-token"},
+			AccountGroups: []AccountGroup{{GroupID: claudeGroup.ID}}},
+		{ID: 30, Platform: PlatformAnthropic, Type: AccountTypeOAuth, Priority: 0, Status: StatusActive, Schedulable: true,
+			Credentials:   map[string]any{"access_token": "other-group-token"},
+			AccountGroups: []AccountGroup{{GroupID: 3}}},
+	}
+	usageRepo := &openAIRecordUsageLogRepoStub{inserted: true}
+	userRepo := &openAIRecordUsageUserRepoStub{}
+	upstream := &anthropicHTTPUpstreamRecorder{resp: &http.Response{
+		StatusCode: http.StatusOK,
+		Header:     http.Header{"Content-Type": []string{"application/json"}, "Request-Id": []string{"req_claude_1"}},
+		Body: io.NopCloser(strings.NewReader(`{"id":"msg_1","type":"message","role":"assistant","model":"claude-sonnet-4-5",` +
+			`"content":[{"type":"text","text":"hi"}],"stop_reason":"end_turn","usage":{"input_tokens":12,"output_tokens":5}}`)),
+	}}
+	cfg := testConfig()
+	svc := &GatewayService{
+		accountRepo:         newGroupAwareMockRepo(accounts),
+		cache:               &mockGatewayCacheForPlatform{},
+		cfg:                 cfg,
+		httpUpstream:        upstream,
+		rateLimitService:    &RateLimitService{},
+		usageLogRepo:        usageRepo,
+		userRepo:            userRepo,
+		billingService:      NewBillingService(cfg, nil),
+		billingCacheService: &BillingCacheService{},
+		deferredService:     &DeferredService{},
+	}
+
+	account, err := svc.selectAccountForModelWithPlatform(ctx, routedKey.GroupID, "", "claude-sonnet-4-5", nil, PlatformAnthropic)
+	require.NoError(t, err)
+	require.Equal(t, int64(20), account.ID)
+
+	// 3. 非 Claude Code 客户端经 OAuth 账号转发：使用 OAuth token 并注入 Claude Code 系统提示词
+	rec := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(rec)
+	c.Request = httptest.NewRequest(http.MethodPost, "/v1/messages", nil)
+	c.Request.Header.Set("User-Agent", "openai-python/1.0")
+	body := []byte(`{"model":"claude-sonnet-4-5","max_tokens":16,"messages":[{"role":"user","content":"hello"}]}`)
+	result, err := svc.Forward(ctx, c, account, &ParsedRequest{Body: body, Model: "claude-sonnet-4-5"})
+	require.NoError(t, err)
+	require.Equal(t, "Bearer claude-oauth-token", upstream.lastReq.Header.Get("Authorization"))
+	require.Contains(t, gjson.GetBytes(upstream.lastBody, "system").Raw, claudeCodeSystemPrompt)
+	require.Equal(t, 12, result.Usage.InputTokens)
+	require.Equal(t, 5, result.Usage.OutputTokens)
+
+	// 4. 用量按改道后的分组与 Claude 账号记录并扣费
+	require.NoError(t, svc.RecordUsage(ctx, &RecordUsageInput{
+		Result:  result,
+		APIKey:  &routedKey,
+		User:    &User{ID: 5},
+		Account: account,
+	}))
+	require.Equal(t, 1, usageRepo.calls)
+	require.Equal(t, int64(20), usageRepo.lastLog.AccountID)
+	require.Equal(t, claudeGroup.ID, *usageRepo.lastLog.GroupID)
+	require.Equal(t, "claude-sonnet-4-5", usageRepo.lastLog.Model)
+	require.Equal(t, 12, usageRepo.lastLog.InputTokens)
+	require.Equal(t, 5, usageRepo.lastLog.OutputTokens)
+	require.Positive(t, usageRepo.lastLog.ActualCost)
+	require.Equal(t, 1, userRepo.deductCalls)
+}
@@ -0,0 +1,68 @@
+package service
+
+import (
+	"fmt"
+)
+
+// priorityOverflowKey 调度池标识（分组 + 平台），溢出状态按调度池独立维护
+func priorityOverflowKey(groupID *int64, platform string) string {
+	return fmt.Sprintf("%d:%s", derefGroupID(groupID), platform)
+}
+
+// priorityOverflowActive 判断调度池是否处于溢出状态（带滞回）：
+// 最高优先级层的最低负载率达到 priority_overflow_load_percent 时开始溢出到下一层，
+// 降到 priority_recover_load_percent 以下才退出，避免在阈值附近来回切换。
+// 未配置溢出阈值时始终返回 false（仅当高优先级账号满载或失败时才使用低优先级账号）。
+func (s *GatewayService) priorityOverflowActive(key string, available []accountWithLoad) bool {
+	if s == nil || s.cfg == nil || len(available) == 0 {
+		return false
+	}
+	overflowAt := s.cfg.Gateway.Scheduling.PriorityOverflowLoadPercent
+	if overflowAt <= 0 {
+		return false
+	}
+	recoverAt := s.cfg.Gateway.Scheduling.PriorityRecoverLoadPercent
+	if recoverAt <= 0 || recoverAt > overflowAt {
+		recoverAt = overflowAt
+	}
+
+	topTier := filterByMinPriority(available)
+	if len(topTier) == len(available) {
+		// 只有一层，无处溢出；重置状态以免下一层上线后沿用旧状态
+		s.priorityOverflow.Delete(key)
+		return false
+	}
+	minLoad := topTier[0].loadInfo.LoadRate
+	for _, acc := range topTier[1:] {
+		if acc.loadInfo.LoadRate < minLoad {
+			minLoad = acc.loadInfo.LoadRate
+		}
+	}
+
+	_, overflowing := s.priorityOverflow.Load(key)
+	switch {
+	case !overflowing && minLoad >= overflowAt:
+		s.priorityOverflow.Store(key, struct{}{})
+		return true
+	case overflowing && minLoad < recoverAt:
+		s.priorityOverflow.Delete(key)
+		return false
+	}
+	return overflowing
+}
+
+// filterByPriorityTier 取参与本次选择的优先级层：正常时仅最高优先级层，溢出时合并下一层
+func filterByPriorityTier(accounts []accountWithLoad, overflow bool) []accountWithLoad {
+	topTier := filterByMinPriority(accounts)
+	if !overflow || len(topTier) == len(accounts) {
+		return topTier
+	}
+	topPriority := topTier[0].account.Priority
+	rest := make([]accountWithLoad, 0, len(accounts)-len(topTier))
+	for _, acc := range accounts {
+		if acc.account.Priority != topPriority {
+			rest = append(rest, acc)
+		}
+	}
+	return append(topTier, filterByMinPriority(rest)...)
+}
@@ -0,0 +1,59 @@
+//go:build unit
+
+package service
+
+import (
+	"testing"
+
+	"github.com/ShaohongDong/sub2api/internal/config"
+	"github.com/stretchr/testify/require"
+)
+
+func priorityOverflowTestAccounts(tier1Load, tier2Load int) []accountWithLoad {
+	return []accountWithLoad{
+		{account: &Account{ID: 1, Priority: 1}, loadInfo: &AccountLoadInfo{LoadRate: tier1Load}},
+		{account: &Account{ID: 2, Priority: 1}, loadInfo: &AccountLoadInfo{LoadRate: tier1Load + 10}},
+		{account: &Account{ID: 3, Priority: 2}, loadInfo: &AccountLoadInfo{LoadRate: tier2Load}},
+		{account: &Account{ID: 4, Priority: 3}, loadInfo: &AccountLoadInfo{LoadRate: 0}},
+	}
+}
+
+func TestPriorityOverflowActive_Hysteresis(t *testing.T) {
+	cfg := &config.Config{}
+	cfg.Gateway.Scheduling.PriorityOverflowLoadPercent = 80
+	cfg.Gateway.Scheduling.PriorityRecoverLoadPercent = 50
+	svc := &GatewayService{cfg: cfg}
+
+	require.False(t, svc.priorityOverflowActive("g", priorityOverflowTestAccounts(70, 0)))
+	require.True(t, svc.priorityOverflowActive("g", priorityOverflowTestAccounts(80, 0)))
+	// 负载回落但仍高于恢复阈值：保持溢出
+	require.True(t, svc.priorityOverflowActive("g", priorityOverflowTestAccounts(60, 0)))
+	// 其他调度池状态独立
+	require.False(t, svc.priorityOverflowActive("other", priorityOverflowTestAccounts(60, 0)))
+	require.False(t, svc.priorityOverflowActive("g", priorityOverflowTestAccounts(40, 0)))
+	require.False(t, svc.priorityOverflowActive("g", priorityOverflowTestAccounts(60, 0)))
+}
+
+func TestPriorityOverflowActive_DisabledOrSingleTier(t *testing.T) {
+	svc := &GatewayService{cfg: &config.Config{}}
+	require.False(t, svc.priorityOverflowActive("g", priorityOverflowTestAccounts(99, 0)))
+
+	svc.cfg.Gateway.Scheduling.PriorityOverflowLoadPercent = 80
+	single := []accountWithLoad{
+		{account: &Account{ID: 1, Priority: 1}, loadInfo: &AccountLoadInfo{LoadRate: 90}},
+	}
+	require.False(t, svc.priorityOverflowActive("g", single))
+}
+
+func TestFilterByPriorityTier(t *testing.T) {
+	accounts := priorityOverflowTestAccounts(90, 0)
+
+	normal := filterByPriorityTier(accounts, false)
+	require.Len(t, normal, 2)
+
+	overflow := filterByPriorityTier(accounts, true)
+	require.Len(t, overflow, 3)
+	require.Equal(t, int64(3), overflow[2].account.ID)
+	// 溢出时下一层负载更低，负载率过滤后选中下一层账号
+	require.Equal(t, int64(3), filterByMinLoadRate(overflow)[0].account.ID)
+}
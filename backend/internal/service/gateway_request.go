@@ -31,12 +31,14 @@ var (
 )
 
 // SessionContext 粘性会话上下文，用于区分不同来源的请求。
-// 仅在 GenerateSessionHash 第 3 级 fallback（消息内容 hash）时混入，
+// ClientIP/UserAgent/APIKeyID 仅在 GenerateSessionHash 第 3 级 fallback（消息内容 hash）时混入，
 // 避免不同用户发送相同消息产生相同 hash 导致账号集中。
 type SessionContext struct {
 	ClientIP  string
 	UserAgent string
 	APIKeyID  int64
+	// AffinityKey 客户端通过亲和请求头显式指定的会话标识（已按 API Key 隔离），非空时优先使用
+	AffinityKey string
 }
 
 // ParsedRequest 保存网关请求的预解析结果
@@ -14,9 +14,11 @@ import (
 	"net/http"
 	"os"
 	"regexp"
+	"slices"
 	"sort"
 	"strconv"
 	"strings"
+	"sync"
 	"sync/atomic"
 	"time"
 
@@ -528,9 +530,17 @@ type GatewayService struct {
 	modelsListCache       *gocache.Cache
 	modelsListCacheTTL    time.Duration
 	settingService        *SettingService
+	usageWebhook          *UsageWebhookService // optional: 逐请求用量推送
 	responseHeaderFilter  *responseheaders.CompiledHeaderFilter
 	debugModelRouting     atomic.Bool
 	debugClaudeMimic      atomic.Bool
+	// priorityOverflow 处于优先级溢出状态的调度池（key: 分组:平台）
+	priorityOverflow sync.Map
+}
+
+// SetUsageWebhook 注入逐请求用量推送服务；未注入时不推送
+func (s *GatewayService) SetUsageWebhook(svc *UsageWebhookService) {
+	s.usageWebhook = svc
 }
 
 // NewGatewayService creates a new GatewayService
@@ -605,6 +615,11 @@ func (s *GatewayService) GenerateSessionHash(parsed *ParsedRequest) string {
 		return ""
 	}
 
+	// 0. 客户端显式指定的会话亲和标识
+	if parsed.SessionContext != nil && parsed.SessionContext.AffinityKey != "" {
+		return s.hashContent(parsed.SessionContext.AffinityKey)
+	}
+
 	// 1. 最高优先级：从 metadata.user_id 提取 session_xxx
 	if parsed.MetadataUserID != "" {
 		if uid := ParseMetadataUserID(parsed.MetadataUserID); uid != nil && uid.SessionID != "" {
@@ -666,7 +681,7 @@ func (s *GatewayService) BindStickySession(ctx context.Context, groupID *int64,
 	if sessionHash == "" || accountID <= 0 || s.cache == nil {
 		return nil
 	}
-	return s.cache.SetSessionAccountID(ctx, derefGroupID(groupID), sessionHash, accountID, stickySessionTTL)
+	return s.cache.SetSessionAccountID(ctx, derefGroupID(groupID), sessionHash, accountID, s.stickySessionTTL())
 }
 
 // GetCachedSessionAccountID retrieves the account ID bound to a sticky session.
@@ -803,6 +818,41 @@ func (s *GatewayService) hashContent(content string) string {
 	return strconv.FormatUint(h, 36)
 }
 
+// stickySessionTTL 返回粘性会话绑定 TTL（gateway.sticky_session_ttl_seconds，未配置时 1 小时）
+func (s *GatewayService) stickySessionTTL() time.Duration {
+	if s != nil && s.cfg != nil && s.cfg.Gateway.StickySessionTTLSeconds > 0 {
+		return time.Duration(s.cfg.Gateway.StickySessionTTLSeconds) * time.Second
+	}
+	return stickySessionTTL
+}
+
+// ClientSessionAffinityKey 读取客户端通过 gateway.session_affinity_header 显式指定的会话标识，用于 SessionContext.AffinityKey
+func (s *GatewayService) ClientSessionAffinityKey(c *gin.Context) string {
+	if s == nil {
+		return ""
+	}
+	return clientSessionAffinityKey(c, s.cfg)
+}
+
+// clientSessionAffinityKey 读取 gateway.session_affinity_header 指定的会话标识。
+// 标识由客户端任意指定，因此混入 API Key ID，避免不同 Key 使用相同取值时共享绑定。
+func clientSessionAffinityKey(c *gin.Context, cfg *config.Config) string {
+	if c == nil || cfg == nil || cfg.Gateway.SessionAffinityHeader == "" {
+		return ""
+	}
+	value := strings.TrimSpace(c.GetHeader(cfg.Gateway.SessionAffinityHeader))
+	if value == "" {
+		return ""
+	}
+	var apiKeyID int64
+	if v, ok := c.Get("api_key"); ok {
+		if apiKey, ok := v.(*APIKey); ok && apiKey != nil {
+			apiKeyID = apiKey.ID
+		}
+	}
+	return "affinity:" + strconv.FormatInt(apiKeyID, 10) + ":" + value
+}
+
 // replaceModelInBody 替换请求体中的model字段
 // 使用 json.RawMessage 保留其他字段的原始字节，避免 thinking 块等内容被修改
 func (s *GatewayService) replaceModelInBody(body []byte, newModel string) []byte {
@@ -1059,6 +1109,13 @@ func (s *GatewayService) SelectAccountForModelWithExclusions(ctx context.Context
 // SelectAccountWithLoadAwareness selects account with load-awareness and wait plan.
 // metadataUserID: 已废弃参数，会话限制现在统一使用 sessionHash
 func (s *GatewayService) SelectAccountWithLoadAwareness(ctx context.Context, groupID *int64, sessionHash string, requestedModel string, excludedIDs map[int64]struct{}, metadataUserID string) (*AccountSelectionResult, error) {
+	ctx, span := startSchedulingSpan(ctx, groupID, requestedModel, len(excludedIDs))
+	selection, err := s.selectAccountWithLoadAwareness(ctx, groupID, sessionHash, requestedModel, excludedIDs, metadataUserID)
+	endSchedulingSpan(span, selection, err)
+	return selection, err
+}
+
+func (s *GatewayService) selectAccountWithLoadAwareness(ctx context.Context, groupID *int64, sessionHash string, requestedModel string, excludedIDs map[int64]struct{}, metadataUserID string) (*AccountSelectionResult, error) {
 	// 调试日志：记录调度入口参数
 	excludedIDsList := make([]int64, 0, len(excludedIDs))
 	for id := range excludedIDs {
@@ -1295,6 +1352,7 @@ func (s *GatewayService) SelectAccountWithLoadAwareness(ctx context.Context, gro
 									if s.debugModelRoutingEnabled() {
 										logger.LegacyPrintf("service.gateway", "[ModelRoutingDebug] routed sticky hit: group_id=%v model=%s session=%s account=%d", derefGroupID(groupID), requestedModel, shortSessionHash(sessionHash), stickyAccountID)
 									}
+									_ = s.cache.RefreshSessionTTL(ctx, derefGroupID(groupID), sessionHash, s.stickySessionTTL())
 									return &AccountSelectionResult{
 										Account:     stickyAccount,
 										Acquired:    true,
@@ -1383,7 +1441,7 @@ func (s *GatewayService) SelectAccountWithLoadAwareness(ctx context.Context, gro
 							continue
 						}
 						if sessionHash != "" && s.cache != nil {
-							_ = s.cache.SetSessionAccountID(ctx, derefGroupID(groupID), sessionHash, item.account.ID, stickySessionTTL)
+							_ = s.cache.SetSessionAccountID(ctx, derefGroupID(groupID), sessionHash, item.account.ID, s.stickySessionTTL())
 						}
 						if s.debugModelRoutingEnabled() {
 							logger.LegacyPrintf("service.gateway", "[ModelRoutingDebug] routed select: group_id=%v model=%s session=%s account=%d", derefGroupID(groupID), requestedModel, shortSessionHash(sessionHash), item.account.ID)
@@ -1449,6 +1507,7 @@ func (s *GatewayService) SelectAccountWithLoadAwareness(ctx context.Context, gro
 						if !s.checkAndRegisterSession(ctx, account, sessionHash) {
 							result.ReleaseFunc() // 释放槽位，继续到 Layer 2
 						} else {
+							_ = s.cache.RefreshSessionTTL(ctx, derefGroupID(groupID), sessionHash, s.stickySessionTTL())
 							return &AccountSelectionResult{
 								Account:     account,
 								Acquired:    true,
@@ -1551,13 +1610,19 @@ func (s *GatewayService) SelectAccountWithLoadAwareness(ctx context.Context, gro
 		}
 
 		// 分层过滤选择：优先级 → 负载率 → LRU
+		overflow := s.priorityOverflowActive(priorityOverflowKey(groupID, platform), available)
 		for len(available) > 0 {
-			// 1. 取优先级最小的集合
-			candidates := filterByMinPriority(available)
+			// 1. 取优先级最小的集合（溢出时合并下一优先级层）
+			candidates := filterByPriorityTier(available, overflow)
 			// 2. 取负载率最低的集合
 			candidates = filterByMinLoadRate(candidates)
-			// 3. LRU 选择最久未用的账号
-			selected := selectByLRU(candidates, preferOAuth)
+			// 3. LRU 选择最久未用的账号（开启延迟感知调度时按滚动 p50 延迟加权选择）
+			var selected *accountWithLoad
+			if cfg.LatencyAwareEnabled {
+				selected = selectByLatencyWeight(candidates, preferOAuth, cfg.LatencyAwareMinSamples)
+			} else {
+				selected = selectByLRU(candidates, preferOAuth)
+			}
 			if selected == nil {
 				break
 			}
@@ -1569,7 +1634,7 @@ func (s *GatewayService) SelectAccountWithLoadAwareness(ctx context.Context, gro
 					result.ReleaseFunc() // 释放槽位，继续尝试下一个账号
 				} else {
 					if sessionHash != "" && s.cache != nil {
-						_ = s.cache.SetSessionAccountID(ctx, derefGroupID(groupID), sessionHash, selected.account.ID, stickySessionTTL)
+						_ = s.cache.SetSessionAccountID(ctx, derefGroupID(groupID), sessionHash, selected.account.ID, s.stickySessionTTL())
 					}
 					return &AccountSelectionResult{
 						Account:     selected.account,
@@ -1624,7 +1689,7 @@ func (s *GatewayService) tryAcquireByLegacyOrder(ctx context.Context, candidates
 				continue
 			}
 			if sessionHash != "" && s.cache != nil {
-				_ = s.cache.SetSessionAccountID(ctx, derefGroupID(groupID), sessionHash, acc.ID, stickySessionTTL)
+				_ = s.cache.SetSessionAccountID(ctx, derefGroupID(groupID), sessionHash, acc.ID, s.stickySessionTTL())
 			}
 			return &AccountSelectionResult{
 				Account:     acc,
@@ -1716,6 +1781,7 @@ func (s *GatewayService) resolveGatewayGroup(ctx context.Context, groupID *int64
 
 	currentID := *groupID
 	visited := map[int64]struct{}{}
+	var originTenantID *int64
 	for {
 		if _, seen := visited[currentID]; seen {
 			return nil, nil, fmt.Errorf("fallback group cycle detected")
@@ -1726,6 +1792,12 @@ func (s *GatewayService) resolveGatewayGroup(ctx context.Context, groupID *int64
 		if err != nil {
 			return nil, nil, err
 		}
+		if currentID == *groupID {
+			originTenantID = group.TenantID
+		} else if !TenantMatches(group.TenantID, originTenantID) {
+			// 租户隔离：降级分组不能跨租户
+			return nil, nil, ErrTenantMismatch
+		}
 
 		if !group.ClaudeCodeOnly || IsClaudeCodeClient(ctx) {
 			return group, &currentID, nil
@@ -2715,7 +2787,7 @@ func (s *GatewayService) selectAccountForModelWithPlatform(ctx context.Context,
 
 		if selected != nil {
 			if sessionHash != "" && s.cache != nil {
-				if err := s.cache.SetSessionAccountID(ctx, derefGroupID(groupID), sessionHash, selected.ID, stickySessionTTL); err != nil {
+				if err := s.cache.SetSessionAccountID(ctx, derefGroupID(groupID), sessionHash, selected.ID, s.stickySessionTTL()); err != nil {
 					logger.LegacyPrintf("service.gateway", "set session account failed: session=%s account_id=%d err=%v", sessionHash, selected.ID, err)
 				}
 			}
@@ -2825,7 +2897,7 @@ func (s *GatewayService) selectAccountForModelWithPlatform(ctx context.Context,
 
 	// 4. 建立粘性绑定
 	if sessionHash != "" && s.cache != nil {
-		if err := s.cache.SetSessionAccountID(ctx, derefGroupID(groupID), sessionHash, selected.ID, stickySessionTTL); err != nil {
+		if err := s.cache.SetSessionAccountID(ctx, derefGroupID(groupID), sessionHash, selected.ID, s.stickySessionTTL()); err != nil {
 			logger.LegacyPrintf("service.gateway", "set session account failed: session=%s account_id=%d err=%v", sessionHash, selected.ID, err)
 		}
 	}
@@ -2951,7 +3023,7 @@ func (s *GatewayService) selectAccountWithMixedScheduling(ctx context.Context, g
 
 		if selected != nil {
 			if sessionHash != "" && s.cache != nil {
-				if err := s.cache.SetSessionAccountID(ctx, derefGroupID(groupID), sessionHash, selected.ID, stickySessionTTL); err != nil {
+				if err := s.cache.SetSessionAccountID(ctx, derefGroupID(groupID), sessionHash, selected.ID, s.stickySessionTTL()); err != nil {
 					logger.LegacyPrintf("service.gateway", "set session account failed: session=%s account_id=%d err=%v", sessionHash, selected.ID, err)
 				}
 			}
@@ -3063,7 +3135,7 @@ func (s *GatewayService) selectAccountWithMixedScheduling(ctx context.Context, g
 
 	// 4. 建立粘性绑定
 	if sessionHash != "" && s.cache != nil {
-		if err := s.cache.SetSessionAccountID(ctx, derefGroupID(groupID), sessionHash, selected.ID, stickySessionTTL); err != nil {
+		if err := s.cache.SetSessionAccountID(ctx, derefGroupID(groupID), sessionHash, selected.ID, s.stickySessionTTL()); err != nil {
 			logger.LegacyPrintf("service.gateway", "set session account failed: session=%s account_id=%d err=%v", sessionHash, selected.ID, err)
 		}
 	}
@@ -3972,15 +4044,11 @@ func (s *GatewayService) Forward(ctx context.Context, c *gin.Context, account *A
 	// Beta policy: evaluate once; block check + cache filter set for buildUpstreamRequest.
 	// Always overwrite the cache to prevent stale values from a previous retry with a different account.
 	if account.Platform == PlatformAnthropic && c != nil {
-		policy := s.evaluateBetaPolicy(ctx, c.GetHeader("anthropic-beta"), account)
+		policy := s.evaluateBetaPolicy(ctx, c.GetHeader("anthropic-beta"), account, parsed.Model)
 		if policy.blockErr != nil {
 			return nil, policy.blockErr
 		}
-		filterSet := policy.filterSet
-		if filterSet == nil {
-			filterSet = map[string]struct{}{}
-		}
-		c.Set(betaPolicyFilterSetKey, filterSet)
+		c.Set(betaPolicyResultKey, policy)
 	}
 
 	body := parsed.Body
@@ -4727,6 +4795,7 @@ func (s *GatewayService) buildUpstreamRequestAnthropicAPIKeyPassthrough(
 	req.Header.Del("authorization")
 	req.Header.Del("x-api-key")
 	req.Header.Del("x-goog-api-key")
+	req.Header.Del("api-key")
 	req.Header.Del("cookie")
 	req.Header.Set("x-api-key", token)
 
@@ -4737,6 +4806,9 @@ func (s *GatewayService) buildUpstreamRequestAnthropicAPIKeyPassthrough(
 		req.Header.Set("anthropic-version", "2023-06-01")
 	}
 
+	// 按头策略额外透传、剥离或注入上游请求头
+	s.settingService.ApplyUpstreamHeaderPolicy(c, req, account)
+
 	return req, nil
 }
 
@@ -4873,10 +4945,10 @@ func (s *GatewayService) handleStreamingResponseAnthropicAPIKeyPassthrough(
 			if !clientDisconnected {
 				if _, err := io.WriteString(w, line); err != nil {
 					clientDisconnected = true
-					logger.LegacyPrintf("service.gateway", "[Anthropic passthrough] Client disconnected during streaming, continue draining upstream for usage: account=%d", account.ID)
+					logger.LegacyPrintf("service.gateway", "[Anthropic passthrough] Client disconnected during streaming, upstream request canceled: account=%d", account.ID)
 				} else if _, err := io.WriteString(w, "\n"); err != nil {
 					clientDisconnected = true
-					logger.LegacyPrintf("service.gateway", "[Anthropic passthrough] Client disconnected during streaming, continue draining upstream for usage: account=%d", account.ID)
+					logger.LegacyPrintf("service.gateway", "[Anthropic passthrough] Client disconnected during streaming, upstream request canceled: account=%d", account.ID)
 				} else if line == "" {
 					// 按 SSE 事件边界刷出，减少每行 flush 带来的 syscall 开销。
 					flusher.Flush()
@@ -5153,7 +5225,8 @@ func (s *GatewayService) buildUpstreamRequest(ctx context.Context, c *gin.Contex
 	}
 
 	// Build effective drop set: merge static defaults with dynamic beta policy filter rules
-	policyFilterSet := s.getBetaPolicyFilterSet(ctx, c, account)
+	policy := s.getBetaPolicy(ctx, c, account, modelID)
+	policyFilterSet := policy.filterSet
 	effectiveDropSet := mergeDropSets(policyFilterSet)
 	effectiveDropWithClaudeCodeSet := mergeDropSets(policyFilterSet, claude.BetaClaudeCode)
 
@@ -5190,6 +5263,11 @@ func (s *GatewayService) buildUpstreamRequest(ctx context.Context, c *gin.Contex
 		}
 	}
 
+	// Beta 策略的 inject 规则：按账号/模型补齐上游所需的 beta（如 1M 上下文、细粒度工具流式）
+	if beta := injectBetaTokens(req.Header.Get("anthropic-beta"), policy.injectTokens); beta != "" {
+		req.Header.Set("anthropic-beta", beta)
+	}
+
 	// Always capture a compact fingerprint line for later error diagnostics.
 	// We only print it when needed (or when the explicit debug flag is enabled).
 	if c != nil && tokenType == "oauth" {
@@ -5199,6 +5277,9 @@ func (s *GatewayService) buildUpstreamRequest(ctx context.Context, c *gin.Contex
 		logClaudeMimicDebug(req, body, account, tokenType, mimicClaudeCode)
 	}
 
+	// 按头策略额外透传、剥离或注入上游请求头
+	s.settingService.ApplyUpstreamHeaderPolicy(c, req, account)
+
 	return req, nil
 }
 
@@ -5372,12 +5453,14 @@ func (e *BetaBlockedError) Error() string { return e.Message }
 
 // betaPolicyResult holds the evaluated result of beta policy rules for a single request.
 type betaPolicyResult struct {
-	blockErr  *BetaBlockedError   // non-nil if a block rule matched
-	filterSet map[string]struct{} // tokens to filter (may be nil)
+	blockErr     *BetaBlockedError   // non-nil if a block rule matched
+	filterSet    map[string]struct{} // tokens to filter (may be nil)
+	injectTokens []string            // tokens to add to the upstream beta header (may be nil)
 }
 
 // evaluateBetaPolicy loads settings once and evaluates all rules against the given request.
-func (s *GatewayService) evaluateBetaPolicy(ctx context.Context, betaHeader string, account *Account) betaPolicyResult {
+// Rules restricted to models or accounts only apply when the request model / account matches.
+func (s *GatewayService) evaluateBetaPolicy(ctx context.Context, betaHeader string, account *Account, model string) betaPolicyResult {
 	if s.settingService == nil {
 		return betaPolicyResult{}
 	}
@@ -5388,7 +5471,7 @@ func (s *GatewayService) evaluateBetaPolicy(ctx context.Context, betaHeader stri
 	isOAuth := account.IsOAuth()
 	var result betaPolicyResult
 	for _, rule := range settings.Rules {
-		if !betaPolicyScopeMatches(rule.Scope, isOAuth) {
+		if !betaPolicyScopeMatches(rule.Scope, isOAuth) || !betaPolicyTargetMatches(rule, account, model) {
 			continue
 		}
 		switch rule.Action {
@@ -5405,11 +5488,32 @@ func (s *GatewayService) evaluateBetaPolicy(ctx context.Context, betaHeader stri
 				result.filterSet = make(map[string]struct{})
 			}
 			result.filterSet[rule.BetaToken] = struct{}{}
+		case BetaPolicyActionInject:
+			result.injectTokens = append(result.injectTokens, rule.BetaToken)
 		}
 	}
 	return result
 }
 
+// betaPolicyTargetMatches checks whether a rule's model / account restrictions match the request.
+func betaPolicyTargetMatches(rule BetaPolicyRule, account *Account, model string) bool {
+	if len(rule.AccountIDs) > 0 && (account == nil || !slices.Contains(rule.AccountIDs, account.ID)) {
+		return false
+	}
+	if len(rule.Models) > 0 && !routingModelMatches(rule.Models, model) {
+		return false
+	}
+	return true
+}
+
+// injectBetaTokens appends the given tokens to a comma-separated beta header, skipping ones already present.
+func injectBetaTokens(header string, tokens []string) string {
+	if len(tokens) == 0 {
+		return header
+	}
+	return mergeAnthropicBeta(strings.Split(header, ","), strings.Join(tokens, ","))
+}
+
 // mergeDropSets merges the static defaultDroppedBetasSet with dynamic policy filter tokens.
 // Returns defaultDroppedBetasSet directly when policySet is empty (zero allocation).
 func mergeDropSets(policySet map[string]struct{}, extra ...string) map[string]struct{} {
@@ -5429,22 +5533,22 @@ func mergeDropSets(policySet map[string]struct{}, extra ...string) map[string]st
 	return m
 }
 
-// betaPolicyFilterSetKey is the gin.Context key for caching the policy filter set within a request.
-const betaPolicyFilterSetKey = "betaPolicyFilterSet"
+// betaPolicyResultKey is the gin.Context key for caching the evaluated beta policy within a request.
+const betaPolicyResultKey = "betaPolicyResult"
 
-// getBetaPolicyFilterSet returns the beta policy filter set, using the gin context cache if available.
+// getBetaPolicy returns the evaluated beta policy, using the gin context cache if available.
 // In the /v1/messages path, Forward() evaluates the policy first and caches the result;
 // buildUpstreamRequest reuses it (zero extra DB calls). In the count_tokens path, this
 // evaluates on demand (one DB call).
-func (s *GatewayService) getBetaPolicyFilterSet(ctx context.Context, c *gin.Context, account *Account) map[string]struct{} {
+func (s *GatewayService) getBetaPolicy(ctx context.Context, c *gin.Context, account *Account, model string) betaPolicyResult {
 	if c != nil {
-		if v, ok := c.Get(betaPolicyFilterSetKey); ok {
-			if fs, ok := v.(map[string]struct{}); ok {
-				return fs
+		if v, ok := c.Get(betaPolicyResultKey); ok {
+			if policy, ok := v.(betaPolicyResult); ok {
+				return policy
 			}
 		}
 	}
-	return s.evaluateBetaPolicy(ctx, "", account).filterSet
+	return s.evaluateBetaPolicy(ctx, "", account, model)
 }
 
 // betaPolicyScopeMatches checks whether a rule's scope matches the current account type.
@@ -5921,6 +6025,17 @@ type streamingResult struct {
 }
 
 func (s *GatewayService) handleStreamingResponse(ctx context.Context, resp *http.Response, c *gin.Context, account *Account, startTime time.Time, originalModel, mappedModel string, mimicClaudeCode bool) (*streamingResult, error) {
+	ctx, span := startStreamRelaySpan(ctx, account, mappedModel)
+	result, err := s.relayStreamingResponse(ctx, resp, c, account, startTime, originalModel, mappedModel, mimicClaudeCode)
+	if result != nil {
+		endStreamRelaySpan(span, result.firstTokenMs, result.clientDisconnect, err)
+	} else {
+		endStreamRelaySpan(span, nil, false, err)
+	}
+	return result, err
+}
+
+func (s *GatewayService) relayStreamingResponse(ctx context.Context, resp *http.Response, c *gin.Context, account *Account, startTime time.Time, originalModel, mappedModel string, mimicClaudeCode bool) (*streamingResult, error) {
 	// 更新5h窗口状态
 	s.rateLimitService.UpdateSessionWindow(ctx, account, resp.Header)
 
@@ -6004,10 +6119,7 @@ func (s *GatewayService) handleStreamingResponse(ctx context.Context, resp *http
 	}
 
 	// 下游 keepalive：防止代理/Cloudflare Tunnel 因连接空闲而断开
-	keepaliveInterval := time.Duration(0)
-	if s.cfg != nil && s.cfg.Gateway.StreamKeepaliveInterval > 0 {
-		keepaliveInterval = time.Duration(s.cfg.Gateway.StreamKeepaliveInterval) * time.Second
-	}
+	keepaliveInterval := streamKeepaliveInterval(s.cfg)
 	var keepaliveTicker *time.Ticker
 	if keepaliveInterval > 0 {
 		keepaliveTicker = time.NewTicker(keepaliveInterval)
@@ -6031,7 +6143,7 @@ func (s *GatewayService) handleStreamingResponse(ctx context.Context, resp *http
 	}
 
 	needModelReplace := originalModel != mappedModel
-	clientDisconnected := false // 客户端断开标志，断开后继续读取上游以获取完整usage
+	clientDisconnected := false // 客户端断开标志，断开后上游随请求 context 取消而结束，按已收集 usage 计费
 
 	pendingEventLines := make([]string, 0, 4)
 
@@ -6204,7 +6316,7 @@ func (s *GatewayService) handleStreamingResponse(ctx context.Context, resp *http
 					if !clientDisconnected {
 						if _, werr := fmt.Fprint(w, block); werr != nil {
 							clientDisconnected = true
-							logger.LegacyPrintf("service.gateway", "Client disconnected during streaming, continuing to drain upstream for billing")
+							logger.LegacyPrintf("service.gateway", "Client disconnected during streaming, upstream request canceled, returning collected usage")
 							break
 						}
 						flusher.Flush()
@@ -6254,7 +6366,7 @@ func (s *GatewayService) handleStreamingResponse(ctx context.Context, resp *http
 			// 同时保持连接活跃防止 Cloudflare Tunnel 等代理断开
 			if _, werr := fmt.Fprint(w, "event: ping\ndata: {\"type\": \"ping\"}\n\n"); werr != nil {
 				clientDisconnected = true
-				logger.LegacyPrintf("service.gateway", "Client disconnected during keepalive ping, continuing to drain upstream for billing")
+				logger.LegacyPrintf("service.gateway", "Client disconnected during keepalive ping, upstream request canceled, returning collected usage")
 				continue
 			}
 			flusher.Flush()
@@ -6615,6 +6727,16 @@ type APIKeyQuotaUpdater interface {
 	UpdateRateLimitUsage(ctx context.Context, apiKeyID int64, cost float64) error
 }
 
+// apiKeyTokenUsageRecorder 可选接口：记录 Key 的 token 用量（TPM 与日/月 token 配额），由 *APIKeyService 实现
+type apiKeyTokenUsageRecorder interface {
+	RecordTokenUsage(ctx context.Context, apiKey *APIKey, tokens int)
+}
+
+// apiKeyBudgetRecorder 可选接口：累加 Key 当前预算周期的花费，由 *APIKeyService 实现
+type apiKeyBudgetRecorder interface {
+	UpdateBudgetUsed(ctx context.Context, apiKey *APIKey, cost float64) error
+}
+
 // postUsageBillingParams 统一扣费所需的参数
 type postUsageBillingParams struct {
 	Cost                  *CostBreakdown
@@ -6624,6 +6746,7 @@ type postUsageBillingParams struct {
 	Subscription          *UserSubscription
 	IsSubscriptionBill    bool
 	AccountRateMultiplier float64
+	TotalTokens           int
 	APIKeyService         APIKeyQuotaUpdater
 }
 
@@ -6631,6 +6754,8 @@ type postUsageBillingParams struct {
 //   - 订阅/余额扣费
 //   - API Key 配额更新
 //   - API Key 限速用量更新
+//   - API Key token 用量（TPM / 日月 token 配额）
+//   - API Key 预算花费
 //   - 账号配额用量更新（账号口径：TotalCost × 账号计费倍率）
 func postUsageBilling(ctx context.Context, p *postUsageBillingParams, deps *billingDeps) {
 	cost := p.Cost
@@ -6667,7 +6792,23 @@ func postUsageBilling(ctx context.Context, p *postUsageBillingParams, deps *bill
 		deps.billingCacheService.QueueUpdateAPIKeyRateLimitUsage(p.APIKey.ID, cost.ActualCost)
 	}
 
-	// 4. 账号配额用量（账号口径：TotalCost × 账号计费倍率）
+	// 4. API Key token 用量（TPM 与日/月 token 配额）
+	if p.TotalTokens > 0 && p.APIKey.HasRequestLimits() {
+		if recorder, ok := p.APIKeyService.(apiKeyTokenUsageRecorder); ok {
+			recorder.RecordTokenUsage(ctx, p.APIKey, p.TotalTokens)
+		}
+	}
+
+	// 5. API Key 预算花费（越过预算时禁用/降级并告警）
+	if cost.ActualCost > 0 && p.APIKey.HasBudget() {
+		if recorder, ok := p.APIKeyService.(apiKeyBudgetRecorder); ok {
+			if err := recorder.UpdateBudgetUsed(ctx, p.APIKey, cost.ActualCost); err != nil {
+				slog.Error("update api key budget usage failed", "api_key_id", p.APIKey.ID, "error", err)
+			}
+		}
+	}
+
+	// 6. 账号配额用量（账号口径：TotalCost × 账号计费倍率）
 	if cost.TotalCost > 0 && p.Account.Type == AccountTypeAPIKey && p.Account.HasAnyQuotaLimit() {
 		accountCost := cost.TotalCost * p.AccountRateMultiplier
 		if err := deps.accountRepo.IncrementQuotaUsed(ctx, p.Account.ID, accountCost); err != nil {
@@ -6675,7 +6816,7 @@ func postUsageBilling(ctx context.Context, p *postUsageBillingParams, deps *bill
 		}
 	}
 
-	// 5. 更新账号最近使用时间
+	// 7. 更新账号最近使用时间
 	deps.deferredService.ScheduleLastUsedUpdate(p.Account.ID)
 }
 
@@ -6705,6 +6846,11 @@ func (s *GatewayService) RecordUsage(ctx context.Context, input *RecordUsageInpu
 	user := input.User
 	account := input.Account
 	subscription := input.Subscription
+	recordAccountRequestLatency(account, result.Duration, result.FirstTokenMs)
+	// 影子流量只参与延迟统计，不计费
+	if input.APIKey != nil && input.APIKey.Shadow {
+		return nil
+	}
 
 	// 强制缓存计费：将 input_tokens 转为 cache_read_input_tokens
 	// 用于粘性会话切换时的特殊计费处理
@@ -6804,6 +6950,8 @@ func (s *GatewayService) RecordUsage(ctx context.Context, input *RecordUsageInpu
 		APIKeyID:              apiKey.ID,
 		AccountID:             account.ID,
 		RequestID:             result.RequestID,
+		GatewayRequestID:      GatewayRequestIDFromContext(ctx),
+		ClientType:            ClientTypeFromContext(ctx),
 		Model:                 result.Model,
 		ReasoningEffort:       result.ReasoningEffort,
 		InputTokens:           result.Usage.InputTokens,
@@ -6853,6 +7001,11 @@ func (s *GatewayService) RecordUsage(ctx context.Context, input *RecordUsageInpu
 	if err != nil {
 		logger.LegacyPrintf("service.gateway", "Create usage log failed: %v", err)
 	}
+	if inserted || err != nil {
+		recordUsageLogMetrics(usageLog)
+		publishUsageLogLiveEvent(usageLog, account.Platform)
+		s.usageWebhook.Enqueue(usageLog, account.Platform)
+	}
 
 	if s.cfg != nil && s.cfg.RunMode == config.RunModeSimple {
 		logger.LegacyPrintf("service.gateway", "[SIMPLE MODE] Usage recorded (not billed): user=%d, tokens=%d", usageLog.UserID, usageLog.TotalTokens())
@@ -6871,6 +7024,7 @@ func (s *GatewayService) RecordUsage(ctx context.Context, input *RecordUsageInpu
 			Subscription:          subscription,
 			IsSubscriptionBill:    isSubscriptionBilling,
 			AccountRateMultiplier: accountRateMultiplier,
+			TotalTokens:           usageLog.TotalTokens(),
 			APIKeyService:         input.APIKeyService,
 		}, s.billingDeps())
 	} else {
@@ -6902,6 +7056,11 @@ func (s *GatewayService) RecordUsageWithLongContext(ctx context.Context, input *
 	user := input.User
 	account := input.Account
 	subscription := input.Subscription
+	recordAccountRequestLatency(account, result.Duration, result.FirstTokenMs)
+	// 影子流量只参与延迟统计，不计费
+	if input.APIKey != nil && input.APIKey.Shadow {
+		return nil
+	}
 
 	// 强制缓存计费：将 input_tokens 转为 cache_read_input_tokens
 	// 用于粘性会话切换时的特殊计费处理
@@ -6980,6 +7139,8 @@ func (s *GatewayService) RecordUsageWithLongContext(ctx context.Context, input *
 		APIKeyID:              apiKey.ID,
 		AccountID:             account.ID,
 		RequestID:             result.RequestID,
+		GatewayRequestID:      GatewayRequestIDFromContext(ctx),
+		ClientType:            ClientTypeFromContext(ctx),
 		Model:                 result.Model,
 		ReasoningEffort:       result.ReasoningEffort,
 		InputTokens:           result.Usage.InputTokens,
@@ -7028,6 +7189,11 @@ func (s *GatewayService) RecordUsageWithLongContext(ctx context.Context, input *
 	if err != nil {
 		logger.LegacyPrintf("service.gateway", "Create usage log failed: %v", err)
 	}
+	if inserted || err != nil {
+		recordUsageLogMetrics(usageLog)
+		publishUsageLogLiveEvent(usageLog, account.Platform)
+		s.usageWebhook.Enqueue(usageLog, account.Platform)
+	}
 
 	if s.cfg != nil && s.cfg.RunMode == config.RunModeSimple {
 		logger.LegacyPrintf("service.gateway", "[SIMPLE MODE] Usage recorded (not billed): user=%d, tokens=%d", usageLog.UserID, usageLog.TotalTokens())
@@ -7046,6 +7212,7 @@ func (s *GatewayService) RecordUsageWithLongContext(ctx context.Context, input *
 			Subscription:          subscription,
 			IsSubscriptionBill:    isSubscriptionBilling,
 			AccountRateMultiplier: accountRateMultiplier,
+			TotalTokens:           usageLog.TotalTokens(),
 			APIKeyService:         input.APIKeyService,
 		}, s.billingDeps())
 	} else {
@@ -7085,10 +7252,10 @@ func (s *GatewayService) ForwardCountTokens(ctx context.Context, c *gin.Context,
 		body, reqModel = normalizeClaudeOAuthRequestBody(body, reqModel, normalizeOpts)
 	}
 
-	// Antigravity 账户不支持 count_tokens，返回 404 让客户端 fallback 到本地估算。
+	// Antigravity 账户不支持上游 count_tokens，使用本地 tokenizer 计数。
 	// 返回 nil 避免 handler 层记录为错误，也不设置 ops 上游错误上下文。
 	if account.Platform == PlatformAntigravity {
-		s.countTokensError(c, http.StatusNotFound, "not_found_error", "count_tokens endpoint is not supported for this platform")
+		c.JSON(http.StatusOK, gin.H{"input_tokens": CountAnthropicRequestTokens(parsed.Body)})
 		return nil
 	}
 
@@ -7385,6 +7552,7 @@ func (s *GatewayService) buildCountTokensRequestAnthropicAPIKeyPassthrough(
 	req.Header.Del("authorization")
 	req.Header.Del("x-api-key")
 	req.Header.Del("x-goog-api-key")
+	req.Header.Del("api-key")
 	req.Header.Del("cookie")
 	req.Header.Set("x-api-key", token)
 
@@ -7395,6 +7563,9 @@ func (s *GatewayService) buildCountTokensRequestAnthropicAPIKeyPassthrough(
 		req.Header.Set("anthropic-version", "2023-06-01")
 	}
 
+	// 按头策略额外透传、剥离或注入上游请求头
+	s.settingService.ApplyUpstreamHeaderPolicy(c, req, account)
+
 	return req, nil
 }
 
@@ -7474,7 +7645,8 @@ func (s *GatewayService) buildCountTokensRequest(ctx context.Context, c *gin.Con
 	}
 
 	// Build effective drop set for count_tokens: merge static defaults with dynamic beta policy filter rules
-	ctEffectiveDropSet := mergeDropSets(s.getBetaPolicyFilterSet(ctx, c, account))
+	ctPolicy := s.getBetaPolicy(ctx, c, account, modelID)
+	ctEffectiveDropSet := mergeDropSets(ctPolicy.filterSet)
 
 	// OAuth 账号：处理 anthropic-beta header
 	if tokenType == "oauth" {
@@ -7510,6 +7682,10 @@ func (s *GatewayService) buildCountTokensRequest(ctx context.Context, c *gin.Con
 		}
 	}
 
+	if beta := injectBetaTokens(req.Header.Get("anthropic-beta"), ctPolicy.injectTokens); beta != "" {
+		req.Header.Set("anthropic-beta", beta)
+	}
+
 	if c != nil && tokenType == "oauth" {
 		c.Set(claudeMimicDebugInfoKey, buildClaudeMimicDebugLine(req, body, account, tokenType, mimicClaudeCode))
 	}
@@ -7517,6 +7693,9 @@ func (s *GatewayService) buildCountTokensRequest(ctx context.Context, c *gin.Con
 		logClaudeMimicDebug(req, body, account, tokenType, mimicClaudeCode)
 	}
 
+	// 按头策略额外透传、剥离或注入上游请求头
+	s.settingService.ApplyUpstreamHeaderPolicy(c, req, account)
+
 	return req, nil
 }
 
@@ -7550,15 +7729,23 @@ func (s *GatewayService) validateUpstreamBaseURL(raw string) (string, error) {
 	return normalized, nil
 }
 
-// GetAvailableModels returns the list of models available for a group
-// It aggregates model_mapping keys from all schedulable accounts in the group
+// GetAvailableModels returns the list of models available for a group.
+// It is assembled from the live account pool: accounts that are currently
+// rate-limited, overloaded or temporarily unschedulable are skipped; accounts
+// with model_mapping contribute their alias names, the others contribute the
+// platform defaults allowed by their plan.
+// Returns nil when the pool cannot be listed or is empty (callers fall back to
+// the static defaults) and an empty slice when no account is healthy.
 func (s *GatewayService) GetAvailableModels(ctx context.Context, groupID *int64, platform string) []string {
 	cacheKey := modelsListCacheKey(groupID, platform)
 	if s.modelsListCache != nil {
 		if cached, found := s.modelsListCache.Get(cacheKey); found {
 			if models, ok := cached.([]string); ok {
 				modelsListCacheHitTotal.Add(1)
-				return cloneStringSlice(models)
+				if models == nil {
+					return nil
+				}
+				return append([]string{}, models...)
 			}
 		}
 	}
@@ -7573,7 +7760,7 @@ func (s *GatewayService) GetAvailableModels(ctx context.Context, groupID *int64,
 		accounts, err = s.accountRepo.ListSchedulable(ctx)
 	}
 
-	if err != nil || len(accounts) == 0 {
+	if err != nil {
 		return nil
 	}
 
@@ -7588,41 +7775,19 @@ func (s *GatewayService) GetAvailableModels(ctx context.Context, groupID *int64,
 		accounts = filtered
 	}
 
-	// Collect unique models from all accounts
-	modelSet := make(map[string]struct{})
-	hasAnyMapping := false
-
-	for _, acc := range accounts {
-		mapping := acc.GetModelMapping()
-		if len(mapping) > 0 {
-			hasAnyMapping = true
-			for model := range mapping {
-				modelSet[model] = struct{}{}
-			}
-		}
-	}
-
-	// If no account has model_mapping, return nil (use default)
-	if !hasAnyMapping {
-		if s.modelsListCache != nil {
-			s.modelsListCache.Set(cacheKey, []string(nil), s.modelsListCacheTTL)
-			modelsListCacheStoreTotal.Add(1)
-		}
-		return nil
-	}
-
-	// Convert to slice
-	models := make([]string, 0, len(modelSet))
-	for model := range modelSet {
-		models = append(models, model)
+	var models []string
+	if len(accounts) > 0 {
+		models = aggregateAccountModelIDs(accounts, time.Now())
 	}
-	sort.Strings(models)
 
 	if s.modelsListCache != nil {
-		s.modelsListCache.Set(cacheKey, cloneStringSlice(models), s.modelsListCacheTTL)
+		s.modelsListCache.Set(cacheKey, models, s.modelsListCacheTTL)
 		modelsListCacheStoreTotal.Add(1)
 	}
-	return cloneStringSlice(models)
+	if models == nil {
+		return nil
+	}
+	return append([]string{}, models...)
 }
 
 func (s *GatewayService) InvalidateAvailableModelsCache(groupID *int64, platform string) {
@@ -0,0 +1,76 @@
+package service
+
+import (
+	"context"
+
+	"github.com/ShaohongDong/sub2api/internal/pkg/tracing"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// 请求生命周期中由服务层负责的阶段 span：格式转换、账号调度、流式转发。
+// 鉴权与客户端识别在中间件中记录，上游调用在 HTTPUpstream 实现中记录。
+
+// startTranslateSpan 开启请求格式转换 span（from/to 为协议格式，如 anthropic → responses）
+func startTranslateSpan(ctx context.Context, from, to string) trace.Span {
+	_, span := tracing.Start(ctx, "gateway.translate",
+		attribute.String("sub2api.translate.from", from),
+		attribute.String("sub2api.translate.to", to),
+	)
+	return span
+}
+
+func startSchedulingSpan(ctx context.Context, groupID *int64, model string, excluded int) (context.Context, trace.Span) {
+	attrs := []attribute.KeyValue{
+		attribute.String("sub2api.model", model),
+		attribute.Int("sub2api.schedule.excluded_accounts", excluded),
+	}
+	if groupID != nil {
+		attrs = append(attrs, attribute.Int64("sub2api.group_id", *groupID))
+	}
+	return tracing.Start(ctx, "gateway.select_account", attrs...)
+}
+
+// endSchedulingSpan 记录选中账号（或需排队等待）后结束调度 span
+func endSchedulingSpan(span trace.Span, selection *AccountSelectionResult, err error) {
+	if selection != nil && selection.Account != nil {
+		span.SetAttributes(
+			attribute.Int64("sub2api.account_id", selection.Account.ID),
+			attribute.String("sub2api.platform", selection.Account.Platform),
+			attribute.Bool("sub2api.schedule.acquired", selection.Acquired),
+		)
+		if selection.WaitPlan != nil {
+			span.SetAttributes(attribute.Bool("sub2api.schedule.wait", true))
+		}
+	}
+	tracing.End(span, err)
+}
+
+func annotateScheduleDecision(span trace.Span, decision OpenAIAccountScheduleDecision) {
+	if decision.Layer == "" {
+		return
+	}
+	span.SetAttributes(
+		attribute.String("sub2api.schedule.layer", decision.Layer),
+		attribute.Int("sub2api.schedule.candidates", decision.CandidateCount),
+	)
+}
+
+func startStreamRelaySpan(ctx context.Context, account *Account, model string) (context.Context, trace.Span) {
+	attrs := []attribute.KeyValue{attribute.String("sub2api.model", model)}
+	if account != nil {
+		attrs = append(attrs, attribute.Int64("sub2api.account_id", account.ID), attribute.String("sub2api.platform", account.Platform))
+	}
+	return tracing.Start(ctx, "gateway.stream_relay", attrs...)
+}
+
+// endStreamRelaySpan 记录首 token 耗时（自请求开始计）与客户端是否中途断开
+func endStreamRelaySpan(span trace.Span, firstTokenMs *int, clientDisconnect bool, err error) {
+	if firstTokenMs != nil {
+		span.SetAttributes(attribute.Int("sub2api.first_token_ms", *firstTokenMs))
+	}
+	if clientDisconnect {
+		span.SetAttributes(attribute.Bool("sub2api.client_disconnect", true))
+	}
+	tracing.End(span, err)
+}
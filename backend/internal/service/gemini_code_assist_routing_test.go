@@ -0,0 +1,113 @@
+//go:build unit
+
+package service
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ShaohongDong/sub2api/internal/config"
+	"github.com/ShaohongDong/sub2api/internal/pkg/ctxkey"
+	"github.com/ShaohongDong/sub2api/internal/pkg/geminicli"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/require"
+	"github.com/tidwall/gjson"
+)
+
+// TestGeminiCodeAssist_RoutingRuleReachesOAuthPoolMember 路由规则把 OpenAI 分组 Key 的 Gemini 原生请求改道到
+// Gemini 分组后，调度选中分组内的 Gemini CLI / Code Assist OAuth 账号，并以 Code Assist 格式发往上游。
+func TestGeminiCodeAssist_RoutingRuleReachesOAuthPoolMember(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	ctx := context.Background()
+
+	openAIGroup := &Group{ID: 1, Platform: PlatformOpenAI, Status: StatusActive, SubscriptionType: SubscriptionTypeStandard}
+	geminiGroup := &Group{ID: 2, Platform: PlatformGemini, Status: StatusActive, SubscriptionType: SubscriptionTypeStandard, Hydrated: true}
+	apiKey := &APIKey{ID: 9, GroupID: &openAIGroup.ID, Group: openAIGroup}
+
+	rules := &RoutingRuleSettings{Enabled: true, Rules: []RoutingRule{
+		{Name: "gemini cli pool", Enabled: true, Models: []string{"gemini-*"}, TargetGroupID: geminiGroup.ID},
+	}}
+	rule := MatchRoutingRule(rules, &RoutingRequest{Model: "models/gemini-2.5-pro", APIKey: apiKey})
+	require.NotNil(t, rule)
+	require.True(t, CanRouteAcrossPlatforms(openAIGroup, geminiGroup))
+	// 与 RoutingRules 中间件一致：改道后的分组写入请求上下文
+	ctx = context.WithValue(ctx, ctxkey.Group, geminiGroup)
+
+	expiresAt := time.Now().Add(time.Hour).Format(time.RFC3339)
+	accounts := []Account{
+		{ID: 20, Platform: PlatformGemini, Type: AccountTypeOAuth, Priority: 1, Status: StatusActive, Schedulable: true,
+			Credentials: map[string]any{
+				"oauth_type":   "code_assist",
+				"access_token": "code-assist-token",
+				"project_id":   "proj-1",
+				"expires_at":   expiresAt,
+			},
+			AccountGroups: []AccountGroup{{GroupID: geminiGroup.ID}}},
+		{ID: 30, Platform: PlatformGemini, Type: AccountTypeAPIKey, Priority: 0, Status: StatusActive, Schedulable: true,
+			Credentials:   map[string]any{"api_key": "other-group-key"},
+			AccountGroups: []AccountGroup{{GroupID: 3}}},
+	}
+	repo := &mockAccountRepoForGemini{
+		accounts:     accounts,
+		accountsByID: map[int64]*Account{},
+		listByGroupFunc: func(_ context.Context, groupID int64, platforms []string) ([]Account, error) {
+			var result []Account
+			for _, acc := range accounts {
+				for _, ag := range acc.AccountGroups {
+					if ag.GroupID == groupID && slices.Contains(platforms, acc.Platform) {
+						result = append(result, acc)
+					}
+				}
+			}
+			return result, nil
+		},
+	}
+	for i := range repo.accounts {
+		repo.accountsByID[repo.accounts[i].ID] = &repo.accounts[i]
+	}
+
+	upstreamSSE := strings.Join([]string{
+		`data: {"response":{"candidates":[{"content":{"role":"model","parts":[{"text":"hi"}]},"finishReason":"STOP"}],` +
+			`"usageMetadata":{"promptTokenCount":7,"candidatesTokenCount":3,"totalTokenCount":10}}}`,
+		"",
+	}, "\n")
+	upstream := &anthropicHTTPUpstreamRecorder{resp: &http.Response{
+		StatusCode: http.StatusOK,
+		Header:     http.Header{"Content-Type": []string{"text/event-stream"}},
+		Body:       io.NopCloser(strings.NewReader(upstreamSSE)),
+	}}
+	svc := &GeminiMessagesCompatService{
+		accountRepo:      repo,
+		groupRepo:        &mockGroupRepoForGemini{groups: map[int64]*Group{}},
+		cache:            &mockGatewayCacheForGemini{},
+		cfg:              &config.Config{},
+		tokenProvider:    NewGeminiTokenProvider(repo, nil, nil),
+		httpUpstream:     upstream,
+		rateLimitService: &RateLimitService{},
+	}
+
+	account, err := svc.SelectAccountForModel(ctx, &geminiGroup.ID, "", "gemini-2.5-pro")
+	require.NoError(t, err)
+	require.Equal(t, int64(20), account.ID, "only the routed group's Code Assist account is scheduled")
+
+	rec := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(rec)
+	c.Request = httptest.NewRequest(http.MethodPost, "/v1beta/models/gemini-2.5-pro:streamGenerateContent", nil)
+	body := []byte(`{"contents":[{"role":"user","parts":[{"text":"hello"}]}]}`)
+	result, err := svc.ForwardNative(ctx, c, account, "gemini-2.5-pro", "streamGenerateContent", true, body)
+	require.NoError(t, err)
+
+	require.Equal(t, geminicli.GeminiCliBaseURL+"/v1internal:streamGenerateContent?alt=sse", upstream.lastReq.URL.String())
+	require.Equal(t, "Bearer code-assist-token", upstream.lastReq.Header.Get("Authorization"))
+	require.Equal(t, "proj-1", gjson.GetBytes(upstream.lastBody, "project").String())
+	require.Equal(t, "gemini-2.5-pro", gjson.GetBytes(upstream.lastBody, "model").String())
+	require.Equal(t, "hello", gjson.GetBytes(upstream.lastBody, "request.contents.0.parts.0.text").String())
+	require.Equal(t, 7, result.Usage.InputTokens)
+	require.Equal(t, 3, result.Usage.OutputTokens)
+}
@@ -13,16 +13,21 @@ import (
 	"log"
 	"math"
 	mathrand "math/rand"
+	"mime"
 	"net/http"
+	"net/url"
+	"path"
 	"regexp"
 	"strings"
 	"time"
 
 	"github.com/ShaohongDong/sub2api/internal/config"
+	"github.com/ShaohongDong/sub2api/internal/pkg/apicompat"
 	"github.com/ShaohongDong/sub2api/internal/pkg/ctxkey"
 	"github.com/ShaohongDong/sub2api/internal/pkg/geminicli"
 	"github.com/ShaohongDong/sub2api/internal/pkg/googleapi"
 	"github.com/ShaohongDong/sub2api/internal/pkg/logger"
+	"github.com/ShaohongDong/sub2api/internal/pkg/tokenizer"
 	"github.com/ShaohongDong/sub2api/internal/util/responseheaders"
 	"github.com/ShaohongDong/sub2api/internal/util/urlvalidator"
 
@@ -2363,27 +2368,7 @@ func estimateGeminiCountTokens(reqBody []byte) int {
 }
 
 func estimateTokensForText(s string) int {
-	s = strings.TrimSpace(s)
-	if s == "" {
-		return 0
-	}
-	runes := []rune(s)
-	if len(runes) == 0 {
-		return 0
-	}
-	ascii := 0
-	for _, r := range runes {
-		if r <= 0x7f {
-			ascii++
-		}
-	}
-	asciiRatio := float64(ascii) / float64(len(runes))
-	if asciiRatio >= 0.8 {
-		// Roughly 4 chars per token for English-like text.
-		return (len(runes) + 3) / 4
-	}
-	// For CJK-heavy text, approximate 1 rune per token.
-	return len(runes)
+	return tokenizer.Count("", strings.TrimSpace(s))
 }
 
 type UpstreamHTTPResult struct {
@@ -2960,6 +2945,9 @@ func convertClaudeMessagesToGeminiGenerateContent(body []byte) ([]byte, error) {
 
 	if tools := convertClaudeToolsToGeminiTools(req["tools"]); tools != nil {
 		out["tools"] = tools
+		if toolConfig := convertClaudeToolChoiceToGeminiToolConfig(req["tool_choice"]); toolConfig != nil {
+			out["toolConfig"] = toolConfig
+		}
 	}
 
 	generationConfig := convertClaudeGenerationConfig(req)
@@ -3102,7 +3090,8 @@ func convertClaudeMessagesToGeminiContents(messages any, toolUseIDToName map[str
 					})
 				case "image":
 					if src, ok := bm["source"].(map[string]any); ok {
-						if srcType, _ := src["type"].(string); srcType == "base64" {
+						switch srcType, _ := src["type"].(string); srcType {
+						case "base64":
 							mediaType, _ := src["media_type"].(string)
 							data, _ := src["data"].(string)
 							if mediaType != "" && data != "" {
@@ -3113,6 +3102,16 @@ func convertClaudeMessagesToGeminiContents(messages any, toolUseIDToName map[str
 									},
 								})
 							}
+						case "url":
+							// 远程图片：Gemini 通过 fileData 直接引用公网 URL
+							if imageURL, _ := src["url"].(string); imageURL != "" {
+								parts = append(parts, map[string]any{
+									"fileData": map[string]any{
+										"mimeType": guessImageMimeTypeFromURL(imageURL),
+										"fileUri":  imageURL,
+									},
+								})
+							}
 						}
 					}
 				default:
@@ -3223,6 +3222,23 @@ func convertClaudeToolsToGeminiTools(tools any) []any {
 	}
 }
 
+// convertClaudeToolChoiceToGeminiToolConfig 将 Claude tool_choice 转为 Gemini toolConfig.functionCallingConfig，
+// 未设置或无法识别时返回 nil（沿用上游默认 AUTO）。
+func convertClaudeToolChoiceToGeminiToolConfig(toolChoice any) map[string]any {
+	if toolChoice == nil {
+		return nil
+	}
+	raw, err := json.Marshal(toolChoice)
+	if err != nil {
+		return nil
+	}
+	tc, ok := apicompat.ParseAnthropicToolChoice(raw)
+	if !ok {
+		return nil
+	}
+	return map[string]any{"functionCallingConfig": tc.GeminiConfig()}
+}
+
 // cleanToolSchema 清理工具的 JSON Schema，移除 Gemini 不支持的字段
 func cleanToolSchema(schema any) any {
 	if schema == nil {
@@ -3278,6 +3294,16 @@ func convertClaudeGenerationConfig(req map[string]any) map[string]any {
 	return out
 }
 
+// guessImageMimeTypeFromURL 根据 URL 扩展名推断图片 MIME 类型，无法识别时回退为 image/jpeg
+func guessImageMimeTypeFromURL(rawURL string) string {
+	if u, err := url.Parse(rawURL); err == nil {
+		if mt := mime.TypeByExtension(strings.ToLower(path.Ext(u.Path))); strings.HasPrefix(mt, "image/") {
+			return mt
+		}
+	}
+	return "image/jpeg"
+}
+
 // extractImageSize 从 Gemini 请求中提取 image_size 参数
 func (s *GeminiMessagesCompatService) extractImageSize(body []byte) string {
 	var req struct {
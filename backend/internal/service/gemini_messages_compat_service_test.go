@@ -221,6 +221,41 @@ func TestConvertClaudeMessagesToGeminiGenerateContent_AddsThoughtSignatureForToo
 	}
 }
 
+func TestConvertClaudeMessagesToGeminiGenerateContent_ToolChoice(t *testing.T) {
+	tests := []struct {
+		name       string
+		toolChoice any
+		want       string
+	}{
+		{name: "specific tool", toolChoice: map[string]any{"type": "tool", "name": "get_weather"}, want: `{"functionCallingConfig":{"mode":"ANY","allowedFunctionNames":["get_weather"]}}`},
+		{name: "any", toolChoice: map[string]any{"type": "any"}, want: `{"functionCallingConfig":{"mode":"ANY"}}`},
+		{name: "none", toolChoice: map[string]any{"type": "none"}, want: `{"functionCallingConfig":{"mode":"NONE"}}`},
+		{name: "unset"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			claudeReq := map[string]any{
+				"messages": []any{map[string]any{"role": "user", "content": "weather?"}},
+				"tools":    []any{map[string]any{"name": "get_weather", "input_schema": map[string]any{"type": "object"}}},
+			}
+			if tt.toolChoice != nil {
+				claudeReq["tool_choice"] = tt.toolChoice
+			}
+			b, _ := json.Marshal(claudeReq)
+
+			out, err := convertClaudeMessagesToGeminiGenerateContent(b)
+			require.NoError(t, err)
+			var got map[string]json.RawMessage
+			require.NoError(t, json.Unmarshal(out, &got))
+			if tt.want == "" {
+				require.NotContains(t, got, "toolConfig")
+				return
+			}
+			require.JSONEq(t, tt.want, string(got["toolConfig"]))
+		})
+	}
+}
+
 func TestEnsureGeminiFunctionCallThoughtSignatures_InsertsWhenMissing(t *testing.T) {
 	geminiReq := map[string]any{
 		"contents": []any{
@@ -565,3 +600,28 @@ func TestParseGeminiRateLimitResetTime(t *testing.T) {
 		})
 	}
 }
+
+func TestConvertClaudeMessagesToGeminiGenerateContent_Images(t *testing.T) {
+	claudeReq := map[string]any{
+		"messages": []any{map[string]any{"role": "user", "content": []any{
+			map[string]any{"type": "image", "source": map[string]any{"type": "base64", "media_type": "image/png", "data": "AAAA"}},
+			map[string]any{"type": "image", "source": map[string]any{"type": "url", "url": "https://example.com/cat.webp?s=1"}},
+			map[string]any{"type": "image", "source": map[string]any{"type": "url", "url": "https://example.com/cat"}},
+		}}},
+	}
+	b, _ := json.Marshal(claudeReq)
+
+	out, err := convertClaudeMessagesToGeminiGenerateContent(b)
+	require.NoError(t, err)
+	var got struct {
+		Contents []struct {
+			Parts []json.RawMessage `json:"parts"`
+		} `json:"contents"`
+	}
+	require.NoError(t, json.Unmarshal(out, &got))
+	require.Len(t, got.Contents, 1)
+	require.Len(t, got.Contents[0].Parts, 3)
+	require.JSONEq(t, `{"inlineData":{"mimeType":"image/png","data":"AAAA"}}`, string(got.Contents[0].Parts[0]))
+	require.JSONEq(t, `{"fileData":{"mimeType":"image/webp","fileUri":"https://example.com/cat.webp?s=1"}}`, string(got.Contents[0].Parts[1]))
+	require.JSONEq(t, `{"fileData":{"mimeType":"image/jpeg","fileUri":"https://example.com/cat"}}`, string(got.Contents[0].Parts[2]))
+}
@@ -36,6 +36,22 @@ func TestGenerateSessionHash_MetadataHasHighestPriority(t *testing.T) {
 	require.Equal(t, "123e4567-e89b-12d3-a456-426614174000", hash, "metadata session_id should have highest priority")
 }
 
+func TestGenerateSessionHash_AffinityKeyOverridesMetadata(t *testing.T) {
+	svc := &GatewayService{}
+
+	parsed := &ParsedRequest{
+		MetadataUserID: "user_a1b2c3d4e5f6a1b2c3d4e5f6a1b2c3d4e5f6a1b2c3d4e5f6a1b2c3d4e5f6a1b2_account__session_123e4567-e89b-12d3-a456-426614174000",
+		SessionContext: &SessionContext{APIKeyID: 1, AffinityKey: "affinity:1:conv-1"},
+	}
+
+	hash := svc.GenerateSessionHash(parsed)
+	require.NotEmpty(t, hash)
+	require.NotEqual(t, "123e4567-e89b-12d3-a456-426614174000", hash)
+
+	parsed.SessionContext.AffinityKey = "affinity:2:conv-1"
+	require.NotEqual(t, hash, svc.GenerateSessionHash(parsed))
+}
+
 // ============ System + Messages 基础测试 ============
 
 func TestGenerateSessionHash_SystemPlusMessages(t *testing.T) {
@@ -61,6 +61,18 @@ type Group struct {
 	AllowMessagesDispatch bool
 	DefaultMappedModel    string
 
+	// 模型别名：请求模型（支持末尾 * 通配符）-> 上游模型，优先于全局别名表
+	ModelAliases map[string]string
+
+	// 跨平台降级链：本分组限流或上游故障时依次尝试的分组 ID（仅标准计费分组）
+	FallbackChain []int64
+
+	// 账号调度策略（仅 openai 平台使用，空值为综合评分）
+	SchedulingStrategy string
+
+	// TenantID 所属租户（nil 表示未归属任何租户）
+	TenantID *int64
+
 	CreatedAt time.Time
 	UpdatedAt time.Time
 
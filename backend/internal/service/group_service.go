@@ -11,6 +11,8 @@ import (
 var (
 	ErrGroupNotFound = infraerrors.NotFound("GROUP_NOT_FOUND", "group not found")
 	ErrGroupExists   = infraerrors.Conflict("GROUP_EXISTS", "group name already exists")
+
+	ErrInvalidSchedulingStrategy = infraerrors.BadRequest("INVALID_SCHEDULING_STRATEGY", "scheduling_strategy must be one of round_robin, weighted, least_in_flight, least_recent_error or empty")
 )
 
 type GroupRepository interface {
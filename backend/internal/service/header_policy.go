@@ -0,0 +1,455 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"slices"
+	"sort"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/ShaohongDong/sub2api/internal/pkg/ctxkey"
+	"github.com/gin-gonic/gin"
+	"golang.org/x/net/http/httpguts"
+	"golang.org/x/sync/singleflight"
+)
+
+// maxHeaderPolicyRules 头策略规则的最大条数
+const maxHeaderPolicyRules = 50
+
+// headerPolicyProtectedHeaders 鉴权凭证与由 HTTP 库管理的头，策略不可透传、剥离或注入
+var headerPolicyProtectedHeaders = map[string]struct{}{
+	"authorization":       {},
+	"proxy-authorization": {},
+	"x-api-key":           {},
+	"x-goog-api-key":      {},
+	"api-key":             {},
+	"cookie":              {},
+	"set-cookie":          {},
+	"host":                {},
+	"content-length":      {},
+	"transfer-encoding":   {},
+	"connection":          {},
+}
+
+// headerPolicyInternalHeaders 代理链与客户端真实 IP 相关的头，不允许配置为透传到上游
+var headerPolicyInternalHeaders = map[string]struct{}{
+	"x-real-ip":        {},
+	"forwarded":        {},
+	"via":              {},
+	"cf-connecting-ip": {},
+	"true-client-ip":   {},
+}
+
+// headerPolicyInternalPrefixes 网关内部与代理链头前缀，不允许配置为透传到上游
+var headerPolicyInternalPrefixes = []string{"x-forwarded-", "x-sub2api-"}
+
+func isHeaderPolicyProtected(name string) bool {
+	_, ok := headerPolicyProtectedHeaders[strings.ToLower(name)]
+	return ok
+}
+
+func isHeaderPolicyInternal(name string) bool {
+	lower := strings.ToLower(name)
+	if _, ok := headerPolicyInternalHeaders[lower]; ok {
+		return true
+	}
+	for _, prefix := range headerPolicyInternalPrefixes {
+		if strings.HasPrefix(lower, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// HeaderPolicyScope 头策略的匹配维度
+type HeaderPolicyScope struct {
+	Platform  string
+	AccountID int64
+	GroupID   int64
+	Path      string
+}
+
+// HeaderPolicyScopeFromContext 从请求上下文读取分组与最终命中的账号/平台；
+// 未选定账号时平台取分组平台。
+func HeaderPolicyScopeFromContext(ctx context.Context, path string) HeaderPolicyScope {
+	scope := HeaderPolicyScope{Path: path}
+	if ctx == nil {
+		return scope
+	}
+	if group, ok := ctx.Value(ctxkey.Group).(*Group); ok && IsGroupContextValid(group) {
+		scope.GroupID = group.ID
+		scope.Platform = group.Platform
+	}
+	if accountID, ok := ctx.Value(ctxkey.AccountID).(int64); ok {
+		scope.AccountID = accountID
+	}
+	if platform, ok := ctx.Value(ctxkey.Platform).(string); ok && platform != "" {
+		scope.Platform = platform
+	}
+	return scope
+}
+
+func (r *HeaderPolicyRule) matches(scope HeaderPolicyScope) bool {
+	if !r.Enabled {
+		return false
+	}
+	if len(r.Platforms) > 0 && !slices.Contains(r.Platforms, scope.Platform) {
+		return false
+	}
+	if len(r.AccountIDs) > 0 && !containsInt64(r.AccountIDs, scope.AccountID) {
+		return false
+	}
+	if len(r.GroupIDs) > 0 && !containsInt64(r.GroupIDs, scope.GroupID) {
+		return false
+	}
+	if len(r.Paths) > 0 {
+		matched := false
+		for _, prefix := range r.Paths {
+			if strings.HasPrefix(scope.Path, prefix) {
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			return false
+		}
+	}
+	return true
+}
+
+// HasResponseRules 判断是否存在需要改写客户端响应头的启用规则
+func (p *HeaderPolicySettings) HasResponseRules() bool {
+	if p == nil || !p.Enabled {
+		return false
+	}
+	for i := range p.Rules {
+		r := &p.Rules[i]
+		if r.Enabled && (len(r.StripResponseHeaders) > 0 || len(r.SetResponseHeaders) > 0) {
+			return true
+		}
+	}
+	return false
+}
+
+// ApplyRequest 按顺序对上游请求头应用所有命中规则：先透传、再剥离、最后注入
+func (p *HeaderPolicySettings) ApplyRequest(scope HeaderPolicyScope, client, upstream http.Header) {
+	if p == nil || !p.Enabled || upstream == nil {
+		return
+	}
+	for i := range p.Rules {
+		r := &p.Rules[i]
+		if !r.matches(scope) {
+			continue
+		}
+		for _, name := range r.ForwardHeaders {
+			if upstream.Get(name) != "" {
+				continue
+			}
+			for _, v := range client.Values(name) {
+				upstream.Add(name, v)
+			}
+		}
+		for _, pattern := range r.StripHeaders {
+			stripHeaderPattern(upstream, pattern)
+		}
+		for name, value := range r.SetHeaders {
+			stripHeaderPattern(upstream, strings.ToLower(name))
+			upstream.Set(name, value)
+		}
+	}
+}
+
+// ApplyResponse 按顺序对客户端响应头应用所有命中规则：先剥离、再注入
+func (p *HeaderPolicySettings) ApplyResponse(scope HeaderPolicyScope, header http.Header) {
+	if p == nil || !p.Enabled || header == nil {
+		return
+	}
+	for i := range p.Rules {
+		r := &p.Rules[i]
+		if !r.matches(scope) {
+			continue
+		}
+		for _, pattern := range r.StripResponseHeaders {
+			stripHeaderPattern(header, pattern)
+		}
+		for name, value := range r.SetResponseHeaders {
+			stripHeaderPattern(header, strings.ToLower(name))
+			header.Set(name, value)
+		}
+	}
+}
+
+// stripHeaderPattern 删除匹配的头（模式已小写，支持末尾 * 通配符），受保护的头不受影响
+func stripHeaderPattern(header http.Header, pattern string) {
+	for key := range header {
+		lower := strings.ToLower(key)
+		if matchModelPattern(pattern, lower) && !isHeaderPolicyProtected(lower) {
+			delete(header, key)
+		}
+	}
+}
+
+// ApplyUpstreamHeaderPolicy 在上游请求构建完成后应用头策略（进程内缓存配置，未启用时无操作）
+func (s *SettingService) ApplyUpstreamHeaderPolicy(c *gin.Context, req *http.Request, account *Account) {
+	if s == nil || req == nil {
+		return
+	}
+	policy := s.GetHeaderPolicy(req.Context())
+	if policy == nil || !policy.Enabled {
+		return
+	}
+	var client http.Header
+	path := ""
+	ctx := req.Context()
+	if c != nil && c.Request != nil {
+		client = c.Request.Header
+		path = c.Request.URL.Path
+		ctx = c.Request.Context()
+	}
+	scope := HeaderPolicyScopeFromContext(ctx, path)
+	if account != nil {
+		scope.AccountID = account.ID
+		scope.Platform = account.Platform
+	}
+	policy.ApplyRequest(scope, client, req.Header)
+}
+
+// normalizeHeaderNames 校验并规范化头名称列表（小写、去重），allowWildcard 时允许末尾 *
+func normalizeHeaderNames(names []string, allowWildcard bool) ([]string, error) {
+	out := make([]string, 0, len(names))
+	seen := make(map[string]struct{}, len(names))
+	for _, raw := range names {
+		name := strings.ToLower(strings.TrimSpace(raw))
+		if name == "" {
+			continue
+		}
+		base := name
+		if allowWildcard && strings.HasSuffix(name, "*") {
+			base = strings.TrimSuffix(name, "*")
+			if base == "" {
+				return nil, fmt.Errorf("wildcard %q must have a prefix", raw)
+			}
+		}
+		if !httpguts.ValidHeaderFieldName(base) {
+			return nil, fmt.Errorf("invalid header name %q", raw)
+		}
+		if base == name && isHeaderPolicyProtected(name) {
+			return nil, fmt.Errorf("header %q is protected", raw)
+		}
+		if _, ok := seen[name]; ok {
+			continue
+		}
+		seen[name] = struct{}{}
+		out = append(out, name)
+	}
+	return out, nil
+}
+
+// normalizeHeaderValues 校验并规范化注入头（名称转为规范格式）
+func normalizeHeaderValues(values map[string]string) (map[string]string, error) {
+	if len(values) == 0 {
+		return nil, nil
+	}
+	out := make(map[string]string, len(values))
+	for raw, value := range values {
+		name := strings.TrimSpace(raw)
+		if !httpguts.ValidHeaderFieldName(name) {
+			return nil, fmt.Errorf("invalid header name %q", raw)
+		}
+		if isHeaderPolicyProtected(name) {
+			return nil, fmt.Errorf("header %q is protected", raw)
+		}
+		if !httpguts.ValidHeaderFieldValue(value) {
+			return nil, fmt.Errorf("invalid value for header %q", raw)
+		}
+		out[http.CanonicalHeaderKey(name)] = value
+	}
+	return out, nil
+}
+
+// normalizeHeaderPolicySettings 校验并规范化头策略配置
+func normalizeHeaderPolicySettings(settings *HeaderPolicySettings) error {
+	if len(settings.Rules) > maxHeaderPolicyRules {
+		return fmt.Errorf("at most %d header policy rules are allowed", maxHeaderPolicyRules)
+	}
+	names := make(map[string]struct{}, len(settings.Rules))
+	for i := range settings.Rules {
+		rule := &settings.Rules[i]
+		rule.Name = strings.TrimSpace(rule.Name)
+		if rule.Name == "" {
+			return fmt.Errorf("rule[%d]: name cannot be empty", i)
+		}
+		if _, ok := names[rule.Name]; ok {
+			return fmt.Errorf("rule[%d]: duplicate name %q", i, rule.Name)
+		}
+		names[rule.Name] = struct{}{}
+
+		platforms := make([]string, 0, len(rule.Platforms))
+		for _, p := range rule.Platforms {
+			p = strings.ToLower(strings.TrimSpace(p))
+			if p != "" && !slices.Contains(platforms, p) {
+				platforms = append(platforms, p)
+			}
+		}
+		rule.Platforms = platforms
+		for _, ids := range []*[]int64{&rule.AccountIDs, &rule.GroupIDs} {
+			deduped := make([]int64, 0, len(*ids))
+			for _, id := range *ids {
+				if id <= 0 {
+					return fmt.Errorf("rule[%d]: invalid id %d", i, id)
+				}
+				if !containsInt64(deduped, id) {
+					deduped = append(deduped, id)
+				}
+			}
+			sort.Slice(deduped, func(a, b int) bool { return deduped[a] < deduped[b] })
+			*ids = deduped
+		}
+		paths := make([]string, 0, len(rule.Paths))
+		for _, p := range rule.Paths {
+			p = strings.TrimSpace(p)
+			if p == "" {
+				continue
+			}
+			if !strings.HasPrefix(p, "/") {
+				return fmt.Errorf("rule[%d]: path %q must start with /", i, p)
+			}
+			paths = append(paths, p)
+		}
+		rule.Paths = paths
+
+		forward, err := normalizeHeaderNames(rule.ForwardHeaders, false)
+		if err != nil {
+			return fmt.Errorf("rule[%d]: forward_headers: %w", i, err)
+		}
+		for _, name := range forward {
+			if isHeaderPolicyInternal(name) {
+				return fmt.Errorf("rule[%d]: forward_headers: internal header %q cannot be forwarded upstream", i, name)
+			}
+		}
+		rule.ForwardHeaders = forward
+		if rule.StripHeaders, err = normalizeHeaderNames(rule.StripHeaders, true); err != nil {
+			return fmt.Errorf("rule[%d]: strip_headers: %w", i, err)
+		}
+		if rule.StripResponseHeaders, err = normalizeHeaderNames(rule.StripResponseHeaders, true); err != nil {
+			return fmt.Errorf("rule[%d]: strip_response_headers: %w", i, err)
+		}
+		if rule.SetHeaders, err = normalizeHeaderValues(rule.SetHeaders); err != nil {
+			return fmt.Errorf("rule[%d]: set_headers: %w", i, err)
+		}
+		if rule.SetResponseHeaders, err = normalizeHeaderValues(rule.SetResponseHeaders); err != nil {
+			return fmt.Errorf("rule[%d]: set_response_headers: %w", i, err)
+		}
+		if len(rule.ForwardHeaders) == 0 && len(rule.StripHeaders) == 0 && len(rule.SetHeaders) == 0 &&
+			len(rule.StripResponseHeaders) == 0 && len(rule.SetResponseHeaders) == 0 {
+			return fmt.Errorf("rule[%d]: at least one header action is required", i)
+		}
+	}
+	if settings.Rules == nil {
+		settings.Rules = []HeaderPolicyRule{}
+	}
+	return nil
+}
+
+// cachedHeaderPolicySettings 头策略配置进程内缓存
+type cachedHeaderPolicySettings struct {
+	settings  *HeaderPolicySettings
+	expiresAt int64 // unix nano
+}
+
+var headerPolicyCache atomic.Value // *cachedHeaderPolicySettings
+
+var headerPolicySF singleflight.Group
+
+// headerPolicyCacheTTL 缓存有效期
+const headerPolicyCacheTTL = 60 * time.Second
+
+// GetHeaderPolicySettings 获取头策略配置
+func (s *SettingService) GetHeaderPolicySettings(ctx context.Context) (*HeaderPolicySettings, error) {
+	value, err := s.settingRepo.GetValue(ctx, SettingKeyHeaderPolicySettings)
+	if err != nil {
+		if errors.Is(err, ErrSettingNotFound) {
+			return DefaultHeaderPolicySettings(), nil
+		}
+		return nil, fmt.Errorf("get header policy settings: %w", err)
+	}
+	if value == "" {
+		return DefaultHeaderPolicySettings(), nil
+	}
+
+	var settings HeaderPolicySettings
+	if err := json.Unmarshal([]byte(value), &settings); err != nil {
+		return DefaultHeaderPolicySettings(), nil
+	}
+	if settings.Rules == nil {
+		settings.Rules = []HeaderPolicyRule{}
+	}
+	return &settings, nil
+}
+
+// SetHeaderPolicySettings 设置头策略配置，并刷新本实例缓存（其他实例在缓存过期后生效）
+func (s *SettingService) SetHeaderPolicySettings(ctx context.Context, settings *HeaderPolicySettings) error {
+	if settings == nil {
+		return fmt.Errorf("settings cannot be nil")
+	}
+	if err := normalizeHeaderPolicySettings(settings); err != nil {
+		return err
+	}
+
+	data, err := json.Marshal(settings)
+	if err != nil {
+		return fmt.Errorf("marshal header policy settings: %w", err)
+	}
+	if err := s.settingRepo.Set(ctx, SettingKeyHeaderPolicySettings, string(data)); err != nil {
+		return err
+	}
+	headerPolicySF.Forget("header_policy")
+	headerPolicyCache.Store(&cachedHeaderPolicySettings{
+		settings:  settings,
+		expiresAt: time.Now().Add(headerPolicyCacheTTL).UnixNano(),
+	})
+	return nil
+}
+
+// GetHeaderPolicy 返回头策略配置（进程内缓存，60 秒 TTL），供网关热路径使用。
+// 读取失败时返回 nil（fail-open，保持内置行为）。
+func (s *SettingService) GetHeaderPolicy(ctx context.Context) *HeaderPolicySettings {
+	if s == nil {
+		return nil
+	}
+	if cached, ok := headerPolicyCache.Load().(*cachedHeaderPolicySettings); ok {
+		if time.Now().UnixNano() < cached.expiresAt {
+			return cached.settings
+		}
+	}
+	result, _, _ := headerPolicySF.Do("header_policy", func() (any, error) {
+		if cached, ok := headerPolicyCache.Load().(*cachedHeaderPolicySettings); ok {
+			if time.Now().UnixNano() < cached.expiresAt {
+				return cached.settings, nil
+			}
+		}
+		dbCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), versionBoundsDBTimeout)
+		defer cancel()
+		settings, err := s.GetHeaderPolicySettings(dbCtx)
+		if err != nil {
+			slog.Warn("failed to get header policy settings, skipping header policy", "error", err)
+			headerPolicyCache.Store(&cachedHeaderPolicySettings{
+				expiresAt: time.Now().Add(versionBoundsErrorTTL).UnixNano(),
+			})
+			return (*HeaderPolicySettings)(nil), nil
+		}
+		headerPolicyCache.Store(&cachedHeaderPolicySettings{
+			settings:  settings,
+			expiresAt: time.Now().Add(headerPolicyCacheTTL).UnixNano(),
+		})
+		return settings, nil
+	})
+	settings, _ := result.(*HeaderPolicySettings)
+	return settings
+}
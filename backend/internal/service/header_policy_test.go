@@ -0,0 +1,109 @@
+//go:build unit
+
+package service
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/ShaohongDong/sub2api/internal/config"
+	"github.com/ShaohongDong/sub2api/internal/pkg/ctxkey"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSetHeaderPolicySettings_Validates(t *testing.T) {
+	svc := NewSettingService(newRuntimeSettingRepoStub(), &config.Config{})
+	ctx := context.Background()
+	t.Cleanup(func() { headerPolicyCache.Store(&cachedHeaderPolicySettings{}) })
+
+	invalid := []HeaderPolicyRule{
+		{Name: "", StripHeaders: []string{"x-trace-*"}},
+		{Name: "no action"},
+		{Name: "protected strip", StripHeaders: []string{"Authorization"}},
+		{Name: "protected set", SetHeaders: map[string]string{"x-api-key": "sk-upstream"}},
+		{Name: "internal forward", ForwardHeaders: []string{"X-Forwarded-For"}},
+		{Name: "bare wildcard", StripResponseHeaders: []string{"*"}},
+		{Name: "bad name", SetHeaders: map[string]string{"bad header": "v"}},
+		{Name: "bad value", SetResponseHeaders: map[string]string{"X-Env": "a\nb"}},
+		{Name: "bad path", Paths: []string{"v1/messages"}, StripHeaders: []string{"x-trace"}},
+		{Name: "bad account", AccountIDs: []int64{0}, StripHeaders: []string{"x-trace"}},
+	}
+	for _, rule := range invalid {
+		err := svc.SetHeaderPolicySettings(ctx, &HeaderPolicySettings{Enabled: true, Rules: []HeaderPolicyRule{rule}})
+		require.Error(t, err, rule.Name)
+	}
+
+	err := svc.SetHeaderPolicySettings(ctx, &HeaderPolicySettings{Enabled: true, Rules: []HeaderPolicyRule{
+		{Name: " tenant ", Enabled: true, Platforms: []string{" Anthropic "}, GroupIDs: []int64{3, 1, 3},
+			ForwardHeaders: []string{"X-Tenant", "x-tenant"}, StripHeaders: []string{"X-Debug-*"},
+			SetHeaders: map[string]string{"x-env": "prod"}},
+	}})
+	require.NoError(t, err)
+	got, err := svc.GetHeaderPolicySettings(ctx)
+	require.NoError(t, err)
+	rule := got.Rules[0]
+	require.Equal(t, "tenant", rule.Name)
+	require.Equal(t, []string{PlatformAnthropic}, rule.Platforms)
+	require.Equal(t, []int64{1, 3}, rule.GroupIDs)
+	require.Equal(t, []string{"x-tenant"}, rule.ForwardHeaders)
+	require.Equal(t, []string{"x-debug-*"}, rule.StripHeaders)
+	require.Equal(t, map[string]string{"X-Env": "prod"}, rule.SetHeaders)
+	require.Same(t, svc.GetHeaderPolicy(ctx), svc.GetHeaderPolicy(ctx))
+}
+
+func TestHeaderPolicyApplyRequest(t *testing.T) {
+	policy := &HeaderPolicySettings{Enabled: true, Rules: []HeaderPolicyRule{
+		{Name: "disabled", SetHeaders: map[string]string{"X-Disabled": "1"}},
+		{Name: "anthropic", Enabled: true, Platforms: []string{PlatformAnthropic},
+			ForwardHeaders: []string{"x-tenant"}, StripHeaders: []string{"x-stainless-*"},
+			SetHeaders: map[string]string{"Anthropic-Version": "2024-01-01"}},
+		{Name: "other account", Enabled: true, AccountIDs: []int64{99}, SetHeaders: map[string]string{"X-Other": "1"}},
+		{Name: "messages path", Enabled: true, Paths: []string{"/v1/messages"}, StripHeaders: []string{"x-api-key"}, SetHeaders: map[string]string{"X-Path": "1"}},
+	}}
+	client := http.Header{}
+	client.Set("X-Tenant", "acme")
+	client.Set("X-Internal", "secret")
+	upstream := http.Header{}
+	upstream.Set("x-api-key", "sk-upstream")
+	upstream.Set("X-Stainless-Os", "Linux")
+	upstream["anthropic-version"] = []string{"2023-06-01"}
+
+	policy.ApplyRequest(HeaderPolicyScope{Platform: PlatformAnthropic, AccountID: 7, Path: "/v1/messages"}, client, upstream)
+
+	require.Equal(t, "acme", upstream.Get("X-Tenant"))
+	require.Empty(t, upstream.Get("X-Internal"))
+	require.Empty(t, upstream.Get("X-Stainless-Os"))
+	require.Equal(t, "sk-upstream", upstream.Get("x-api-key"), "protected headers are never stripped")
+	require.Nil(t, upstream["anthropic-version"])
+	require.Equal(t, "2024-01-01", upstream.Get("Anthropic-Version"))
+	require.Empty(t, upstream.Get("X-Disabled"))
+	require.Empty(t, upstream.Get("X-Other"))
+	require.Equal(t, "1", upstream.Get("X-Path"))
+}
+
+func TestApplyUpstreamHeaderPolicy_UsesRequestScope(t *testing.T) {
+	svc := NewSettingService(newRuntimeSettingRepoStub(), &config.Config{})
+	ctx := context.Background()
+	t.Cleanup(func() { headerPolicyCache.Store(&cachedHeaderPolicySettings{}) })
+	require.NoError(t, svc.SetHeaderPolicySettings(ctx, &HeaderPolicySettings{Enabled: true, Rules: []HeaderPolicyRule{
+		{Name: "group 5", Enabled: true, GroupIDs: []int64{5}, SetHeaders: map[string]string{"X-Group": "5"}},
+		{Name: "account 9", Enabled: true, AccountIDs: []int64{9}, SetHeaders: map[string]string{"X-Account": "9"}},
+	}}))
+
+	gin.SetMode(gin.TestMode)
+	c, _ := gin.CreateTestContext(httptest.NewRecorder())
+	inbound := httptest.NewRequest(http.MethodPost, "/v1/messages", nil)
+	group := &Group{ID: 5, Platform: PlatformAnthropic, Status: StatusActive, Hydrated: true}
+	c.Request = inbound.WithContext(context.WithValue(inbound.Context(), ctxkey.Group, group))
+	req := httptest.NewRequest(http.MethodPost, "https://api.anthropic.com/v1/messages", nil)
+
+	svc.ApplyUpstreamHeaderPolicy(c, req, &Account{ID: 9, Platform: PlatformAnthropic})
+	require.Equal(t, "5", req.Header.Get("X-Group"))
+	require.Equal(t, "9", req.Header.Get("X-Account"))
+
+	var nilSvc *SettingService
+	nilSvc.ApplyUpstreamHeaderPolicy(c, req, nil)
+}
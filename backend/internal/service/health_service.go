@@ -0,0 +1,300 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/ShaohongDong/sub2api/internal/config"
+	"github.com/redis/go-redis/v9"
+)
+
+// 健康状态汇总值
+const (
+	HealthStatusOK        = "ok"
+	HealthStatusDegraded  = "degraded"
+	HealthStatusUnhealthy = "unhealthy"
+)
+
+// 未就绪原因
+const (
+	ReadinessReasonDraining             = "draining"
+	ReadinessReasonDatastoreUnavailable = "datastore_unavailable"
+	ReadinessReasonNoHealthyAccount     = "no_healthy_account"
+)
+
+var errHealthDependencyNotConfigured = errors.New("not configured")
+
+// HealthDependency 单个数据存储的检查结果
+type HealthDependency struct {
+	Status    string `json:"status"`
+	LatencyMs int64  `json:"latency_ms"`
+	Error     string `json:"error,omitempty"`
+}
+
+// HealthAccountStatus 单个账号的调度状态
+type HealthAccountStatus struct {
+	ID          int64  `json:"id"`
+	Name        string `json:"name"`
+	Platform    string `json:"platform"`
+	Type        string `json:"type"`
+	Schedulable bool   `json:"schedulable"`
+	CircuitOpen bool   `json:"circuit_open"`
+	// Cooldown 当前生效的冷却原因（rate_limit / overload / temp_unschedulable），CooldownUntil 为最晚的结束时间
+	Cooldown      string     `json:"cooldown,omitempty"`
+	CooldownUntil *time.Time `json:"cooldown_until,omitempty"`
+	ExpiresAt     *time.Time `json:"expires_at,omitempty"`
+	Expired       bool       `json:"expired"`
+	// Healthy 来自账号健康检查（account_health），未被跟踪的账号视为健康
+	Healthy             bool   `json:"healthy"`
+	ConsecutiveFailures int    `json:"consecutive_failures,omitempty"`
+	LastError           string `json:"last_error,omitempty"`
+}
+
+// HealthAccountsReport 账号健康汇总
+type HealthAccountsReport struct {
+	Total       int                   `json:"total"`
+	Schedulable int                   `json:"schedulable"`
+	Cooldown    int                   `json:"cooldown"`
+	Expired     int                   `json:"expired"`
+	Unhealthy   int                   `json:"unhealthy"`
+	Items       []HealthAccountStatus `json:"items"`
+}
+
+// HealthQueueStatus 分组优先级队列深度
+type HealthQueueStatus struct {
+	GroupID  int64 `json:"group_id"`
+	Queued   int   `json:"queued"`
+	InFlight int   `json:"in_flight"`
+}
+
+// HealthReport 健康检查结果；Accounts 与 Queues 仅在详细检查中返回
+type HealthReport struct {
+	Status    string                `json:"status"`
+	CheckedAt time.Time             `json:"checked_at"`
+	Database  HealthDependency      `json:"database"`
+	Redis     HealthDependency      `json:"redis"`
+	Accounts  *HealthAccountsReport `json:"accounts,omitempty"`
+	Queues    []HealthQueueStatus   `json:"queues,omitempty"`
+}
+
+// ReadinessReport 就绪检查结果，Reason 为未就绪原因
+type ReadinessReport struct {
+	Ready  bool   `json:"ready"`
+	Reason string `json:"reason,omitempty"`
+}
+
+// HealthService 汇总数据存储连通性、账号调度状态与队列深度。
+// 数据库或 Redis 不可用时为 unhealthy；存在启用账号但全部不可调度时为 degraded。
+type HealthService struct {
+	cfg           config.HealthConfig
+	db            *sql.DB
+	redisClient   *redis.Client
+	accountRepo   AccountRepository
+	accountHealth *AccountHealthService
+
+	mu       sync.Mutex
+	summary  *HealthReport
+	detailed *HealthReport
+	draining atomic.Bool
+}
+
+// NewHealthService 创建健康检查服务
+func NewHealthService(cfg *config.Config, db *sql.DB, redisClient *redis.Client, accountRepo AccountRepository, accountHealth *AccountHealthService) *HealthService {
+	return &HealthService{
+		cfg:           cfg.Health,
+		db:            db,
+		redisClient:   redisClient,
+		accountRepo:   accountRepo,
+		accountHealth: accountHealth,
+	}
+}
+
+// DetailsEnabled 是否开放 /health/details
+func (s *HealthService) DetailsEnabled() bool {
+	return s != nil && s.cfg.DetailsEnabled
+}
+
+// AuthToken 访问 /health/details 所需的 Bearer 令牌，为空时不校验
+func (s *HealthService) AuthToken() string {
+	return s.cfg.AuthToken
+}
+
+// Check 返回汇总健康状态（仅检查数据存储），结果按 cache_seconds 缓存
+func (s *HealthService) Check(ctx context.Context) *HealthReport {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.fresh(s.summary) {
+		return s.summary
+	}
+	report := s.checkDatastores(ctx)
+	s.summary = report
+	return report
+}
+
+// CheckDetails 返回详细健康状态（数据存储、账号与队列），结果按 cache_seconds 缓存
+func (s *HealthService) CheckDetails(ctx context.Context) *HealthReport {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.fresh(s.detailed) {
+		return s.detailed
+	}
+	report := s.checkDatastores(ctx)
+	s.summary = report
+
+	detailed := *report
+	if accounts, err := s.accountRepo.ListActive(ctx); err == nil {
+		detailed.Accounts = s.buildAccountsReport(accounts, time.Now())
+		if detailed.Status == HealthStatusOK && detailed.Accounts.Total > 0 && detailed.Accounts.Schedulable == 0 {
+			detailed.Status = HealthStatusDegraded
+		}
+	}
+	detailed.Queues = healthQueueStatuses(gatewayMetrics.priorityQueue.Load())
+	s.detailed = &detailed
+	return &detailed
+}
+
+// MarkDraining 标记实例进入停机流程，此后就绪检查始终返回未就绪
+func (s *HealthService) MarkDraining() {
+	if s != nil {
+		s.draining.Store(true)
+	}
+}
+
+// Ready 返回就绪状态：停机中、数据存储不可用，或（readiness_require_account 开启时）
+// 没有健康可调度账号时未就绪。账号状态复用详细检查的缓存结果。
+func (s *HealthService) Ready(ctx context.Context) *ReadinessReport {
+	if s.draining.Load() {
+		return &ReadinessReport{Reason: ReadinessReasonDraining}
+	}
+	if !s.cfg.ReadinessRequireAccount {
+		if s.Check(ctx).Status == HealthStatusUnhealthy {
+			return &ReadinessReport{Reason: ReadinessReasonDatastoreUnavailable}
+		}
+		return &ReadinessReport{Ready: true}
+	}
+
+	report := s.CheckDetails(ctx)
+	if report.Status == HealthStatusUnhealthy {
+		return &ReadinessReport{Reason: ReadinessReasonDatastoreUnavailable}
+	}
+	if report.Accounts == nil {
+		return &ReadinessReport{Reason: ReadinessReasonNoHealthyAccount}
+	}
+	for _, item := range report.Accounts.Items {
+		if item.Schedulable && item.Healthy && !item.CircuitOpen && !item.Expired && item.Cooldown == "" {
+			return &ReadinessReport{Ready: true}
+		}
+	}
+	return &ReadinessReport{Reason: ReadinessReasonNoHealthyAccount}
+}
+
+func (s *HealthService) fresh(report *HealthReport) bool {
+	return report != nil && s.cfg.CacheSeconds > 0 &&
+		time.Since(report.CheckedAt) < time.Duration(s.cfg.CacheSeconds)*time.Second
+}
+
+func (s *HealthService) checkDatastores(ctx context.Context) *HealthReport {
+	timeout := time.Duration(s.cfg.CheckTimeoutSeconds) * time.Second
+	if timeout <= 0 {
+		timeout = 2 * time.Second
+	}
+	report := &HealthReport{
+		Status:    HealthStatusOK,
+		CheckedAt: time.Now(),
+		Database: checkHealthDependency(ctx, timeout, func(ctx context.Context) error {
+			if s.db == nil {
+				return errHealthDependencyNotConfigured
+			}
+			return s.db.PingContext(ctx)
+		}),
+		Redis: checkHealthDependency(ctx, timeout, func(ctx context.Context) error {
+			if s.redisClient == nil {
+				return errHealthDependencyNotConfigured
+			}
+			return s.redisClient.Ping(ctx).Err()
+		}),
+	}
+	if report.Database.Status != HealthStatusOK || report.Redis.Status != HealthStatusOK {
+		report.Status = HealthStatusUnhealthy
+	}
+	return report
+}
+
+func checkHealthDependency(ctx context.Context, timeout time.Duration, check func(context.Context) error) HealthDependency {
+	checkCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), timeout)
+	defer cancel()
+	start := time.Now()
+	err := check(checkCtx)
+	dep := HealthDependency{Status: HealthStatusOK, LatencyMs: time.Since(start).Milliseconds()}
+	if err != nil {
+		dep.Status = HealthStatusUnhealthy
+		dep.Error = err.Error()
+	}
+	return dep
+}
+
+func (s *HealthService) buildAccountsReport(accounts []Account, now time.Time) *HealthAccountsReport {
+	report := &HealthAccountsReport{Total: len(accounts), Items: make([]HealthAccountStatus, 0, len(accounts))}
+	for i := range accounts {
+		a := &accounts[i]
+		item := HealthAccountStatus{
+			ID:          a.ID,
+			Name:        a.Name,
+			Platform:    a.Platform,
+			Type:        a.Type,
+			Schedulable: a.IsSchedulable(),
+			CircuitOpen: !AccountCircuitAllows(a.ID),
+			ExpiresAt:   a.ExpiresAt,
+			Expired:     a.ExpiresAt != nil && !now.Before(*a.ExpiresAt),
+			Healthy:     true,
+		}
+		for _, c := range []struct {
+			reason string
+			until  *time.Time
+		}{
+			{"rate_limit", a.RateLimitResetAt},
+			{"overload", a.OverloadUntil},
+			{"temp_unschedulable", a.TempUnschedulableUntil},
+		} {
+			if c.until != nil && now.Before(*c.until) && (item.CooldownUntil == nil || c.until.After(*item.CooldownUntil)) {
+				item.Cooldown, item.CooldownUntil = c.reason, c.until
+			}
+		}
+		if st := s.accountHealth.GetState(a.ID); st != nil {
+			item.Healthy = st.Healthy
+			item.ConsecutiveFailures = st.ConsecutiveFailures
+			item.LastError = st.LastError
+		}
+
+		if item.Schedulable {
+			report.Schedulable++
+		}
+		if item.Cooldown != "" {
+			report.Cooldown++
+		}
+		if item.Expired {
+			report.Expired++
+		}
+		if !item.Healthy {
+			report.Unhealthy++
+		}
+		report.Items = append(report.Items, item)
+	}
+	sort.Slice(report.Items, func(i, j int) bool { return report.Items[i].ID < report.Items[j].ID })
+	return report
+}
+
+func healthQueueStatuses(q *PriorityRequestQueue) []HealthQueueStatus {
+	stats := q.Stats()
+	out := make([]HealthQueueStatus, 0, len(stats))
+	for groupID, st := range stats {
+		out = append(out, HealthQueueStatus{GroupID: groupID, Queued: st.Queued, InFlight: st.InFlight})
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].GroupID < out[j].GroupID })
+	return out
+}
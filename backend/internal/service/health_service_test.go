@@ -0,0 +1,116 @@
+//go:build unit
+
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/ShaohongDong/sub2api/internal/config"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/require"
+)
+
+type healthAccountRepoStub struct {
+	AccountRepository
+	accounts []Account
+	calls    int
+}
+
+func (s *healthAccountRepoStub) ListActive(context.Context) ([]Account, error) {
+	s.calls++
+	return s.accounts, nil
+}
+
+func newHealthTestService(t *testing.T, cacheSeconds int, repo AccountRepository) (*HealthService, sqlmock.Sqlmock) {
+	t.Helper()
+	db, mock, err := sqlmock.New(sqlmock.MonitorPingsOption(true))
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = db.Close() })
+	// 指向未监听端口，Redis 检查必然失败
+	rdb := redis.NewClient(&redis.Options{Addr: "127.0.0.1:1", DialTimeout: 100 * time.Millisecond, MaxRetries: -1})
+	t.Cleanup(func() { _ = rdb.Close() })
+
+	cfg := &config.Config{Health: config.HealthConfig{CacheSeconds: cacheSeconds, CheckTimeoutSeconds: 1}}
+	return NewHealthService(cfg, db, rdb, repo, nil), mock
+}
+
+func TestHealthServiceCheckReportsUnreachableDatastore(t *testing.T) {
+	svc, mock := newHealthTestService(t, 60, &healthAccountRepoStub{})
+	mock.ExpectPing()
+
+	report := svc.Check(context.Background())
+	require.Equal(t, HealthStatusUnhealthy, report.Status)
+	require.Equal(t, HealthStatusOK, report.Database.Status)
+	require.Equal(t, HealthStatusUnhealthy, report.Redis.Status)
+	require.NotEmpty(t, report.Redis.Error)
+	require.Nil(t, report.Accounts)
+
+	// 缓存期内不再访问数据存储（sqlmock 未声明第二次 Ping）
+	require.Same(t, report, svc.Check(context.Background()))
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestHealthServiceCheckDetailsAccounts(t *testing.T) {
+	now := time.Now()
+	past, future := now.Add(-time.Hour), now.Add(time.Hour)
+	repo := &healthAccountRepoStub{accounts: []Account{
+		{ID: 2, Name: "limited", Platform: PlatformAnthropic, Status: StatusActive, Schedulable: true, RateLimitResetAt: &future},
+		{ID: 1, Name: "ok", Platform: PlatformOpenAI, Status: StatusActive, Schedulable: true, ExpiresAt: &future},
+		{ID: 3, Name: "expired", Platform: PlatformOpenAI, Status: StatusActive, Schedulable: true, ExpiresAt: &past, AutoPauseOnExpired: true},
+	}}
+	svc, mock := newHealthTestService(t, 0, repo)
+	mock.ExpectPing()
+
+	report := svc.CheckDetails(context.Background())
+	require.NotNil(t, report.Accounts)
+	accounts := report.Accounts
+	require.Equal(t, 3, accounts.Total)
+	require.Equal(t, 1, accounts.Schedulable)
+	require.Equal(t, 1, accounts.Cooldown)
+	require.Equal(t, 1, accounts.Expired)
+	require.Equal(t, []int64{1, 2, 3}, []int64{accounts.Items[0].ID, accounts.Items[1].ID, accounts.Items[2].ID})
+	require.Equal(t, "rate_limit", accounts.Items[1].Cooldown)
+	require.True(t, accounts.Items[2].Expired)
+	require.False(t, accounts.Items[2].Schedulable)
+	require.True(t, accounts.Items[0].Healthy)
+	require.Equal(t, 1, repo.calls)
+}
+
+func TestHealthServiceAccountsReportCooldown(t *testing.T) {
+	svc := &HealthService{}
+	future := time.Now().Add(time.Hour)
+	report := svc.buildAccountsReport([]Account{
+		{ID: 1, Status: StatusActive, Schedulable: true, OverloadUntil: &future},
+	}, time.Now())
+	require.Equal(t, 1, report.Total)
+	require.Zero(t, report.Schedulable)
+	require.Equal(t, "overload", report.Items[0].Cooldown)
+}
+
+func TestHealthServiceReady(t *testing.T) {
+	svc, mock := newHealthTestService(t, 60, &healthAccountRepoStub{})
+	mock.ExpectPing()
+	require.Equal(t, &ReadinessReport{Reason: ReadinessReasonDatastoreUnavailable}, svc.Ready(context.Background()))
+
+	svc.cfg.ReadinessRequireAccount = true
+	// 预置缓存的详细检查结果，避免依赖真实 Redis
+	svc.detailed = &HealthReport{
+		Status:    HealthStatusDegraded,
+		CheckedAt: time.Now(),
+		Accounts: &HealthAccountsReport{Items: []HealthAccountStatus{
+			{ID: 1, Schedulable: true, Healthy: false},
+			{ID: 2, Schedulable: true, Healthy: true, CircuitOpen: true},
+		}},
+	}
+	require.Equal(t, &ReadinessReport{Reason: ReadinessReasonNoHealthyAccount}, svc.Ready(context.Background()))
+
+	svc.detailed.Accounts.Items = append(svc.detailed.Accounts.Items, HealthAccountStatus{ID: 3, Schedulable: true, Healthy: true})
+	require.Equal(t, &ReadinessReport{Ready: true}, svc.Ready(context.Background()))
+
+	svc.MarkDraining()
+	require.Equal(t, &ReadinessReport{Reason: ReadinessReasonDraining}, svc.Ready(context.Background()))
+	require.NoError(t, mock.ExpectationsWereMet())
+}
@@ -86,6 +86,8 @@ type IdempotencyExecuteOptions struct {
 	Payload        any
 	TTL            time.Duration
 	RequireKey     bool
+	// MaxStoredResponseLen 覆盖 cfg.MaxStoredResponseLen（0 沿用全局配置）
+	MaxStoredResponseLen int
 }
 
 type IdempotencyExecuteResult struct {
@@ -416,7 +418,7 @@ func (c *IdempotencyCoordinator) Execute(
 		return nil, execErr
 	}
 
-	storedBody, marshalErr := c.marshalStoredResponse(data)
+	storedBody, marshalErr := c.marshalStoredResponse(data, opts.MaxStoredResponseLen)
 	if marshalErr != nil {
 		RecordIdempotencyStoreUnavailable(opts.Route, opts.Scope, "marshal_response_error")
 		logIdempotencyAudit(opts.Route, opts.Scope, keyHash, "processing->store_unavailable", false, map[string]string{
@@ -447,14 +449,17 @@ func (c *IdempotencyCoordinator) conflictWithRetryAfter(base *infraerrors.Applic
 	return base.WithMetadata(map[string]string{"retry_after": strconv.Itoa(sec)})
 }
 
-func (c *IdempotencyCoordinator) marshalStoredResponse(data any) (string, error) {
+func (c *IdempotencyCoordinator) marshalStoredResponse(data any, maxLen int) (string, error) {
 	raw, err := json.Marshal(data)
 	if err != nil {
 		return "", err
 	}
+	if maxLen <= 0 {
+		maxLen = c.cfg.MaxStoredResponseLen
+	}
 	redacted := logredact.RedactText(string(raw))
-	if c.cfg.MaxStoredResponseLen > 0 && len(redacted) > c.cfg.MaxStoredResponseLen {
-		redacted = redacted[:c.cfg.MaxStoredResponseLen] + "...(truncated)"
+	if maxLen > 0 && len(redacted) > maxLen {
+		redacted = redacted[:maxLen] + "...(truncated)"
 	}
 	return redacted, nil
 }
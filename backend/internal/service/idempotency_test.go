@@ -789,9 +789,13 @@ func TestIdempotencyCoordinator_HelperBranches(t *testing.T) {
 	require.Equal(t, infraerrors.Code(base), infraerrors.Code(err))
 
 	// marshalStoredResponse should truncate.
-	body, err := c.marshalStoredResponse(map[string]any{"long": "abcdefghijklmnopqrstuvwxyz"})
+	body, err := c.marshalStoredResponse(map[string]any{"long": "abcdefghijklmnopqrstuvwxyz"}, 0)
 	require.NoError(t, err)
 	require.Contains(t, body, "...(truncated)")
+	// 调用方覆盖的上限优先于全局配置
+	body, err = c.marshalStoredResponse(map[string]any{"long": "abcdefghijklmnopqrstuvwxyz"}, 1024)
+	require.NoError(t, err)
+	require.Equal(t, `{"long":"abcdefghijklmnopqrstuvwxyz"}`, body)
 
 	// decodeStoredResponse empty and invalid json.
 	out, err := c.decodeStoredResponse(nil)
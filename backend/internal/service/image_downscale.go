@@ -0,0 +1,39 @@
+package service
+
+import (
+	"github.com/ShaohongDong/sub2api/internal/config"
+	"github.com/ShaohongDong/sub2api/internal/pkg/apicompat"
+	"github.com/ShaohongDong/sub2api/internal/pkg/imageutil"
+	"github.com/ShaohongDong/sub2api/internal/pkg/logger"
+	"go.uber.org/zap"
+)
+
+// downscaleResponsesImages 按 gateway.image_downscale 配置对协议转换后请求中的内联图片（data URI）
+// 进行缩放/重压缩。远程 URL 图片不拉取；处理失败的图片保持原样。
+func downscaleResponsesImages(cfg *config.Config, req *apicompat.ResponsesRequest) {
+	if cfg == nil || !cfg.Gateway.ImageDownscale.Enabled || req == nil {
+		return
+	}
+	opts := imageutil.Options{
+		MaxDimension: cfg.Gateway.ImageDownscale.MaxDimension,
+		JPEGQuality:  cfg.Gateway.ImageDownscale.JPEGQuality,
+		MinBytes:     cfg.Gateway.ImageDownscale.MinBytes,
+	}
+	err := apicompat.RewriteResponsesInputImages(req, func(imageURL string) (string, bool) {
+		out, changed, err := imageutil.DownscaleDataURI(imageURL, opts)
+		if err != nil {
+			logger.L().Debug("image downscale skipped", zap.Error(err))
+			return "", false
+		}
+		if changed {
+			logger.L().Debug("image downscaled",
+				zap.Int("before_bytes", len(imageURL)),
+				zap.Int("after_bytes", len(out)),
+			)
+		}
+		return out, changed
+	})
+	if err != nil {
+		logger.L().Warn("image downscale: rewrite input failed", zap.Error(err))
+	}
+}
@@ -0,0 +1,158 @@
+package service
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/ShaohongDong/sub2api/internal/config"
+	"github.com/google/uuid"
+)
+
+// LeaderLockCache 集群领导者租约存储（Redis）
+type LeaderLockCache interface {
+	// AcquireLeader 租约空闲时获取、自己持有时续期；被其他实例持有时返回 false
+	AcquireLeader(ctx context.Context, instanceID string, lease time.Duration) (bool, error)
+	// ReleaseLeader 仅当租约仍由 instanceID 持有时释放
+	ReleaseLeader(ctx context.Context, instanceID string) error
+}
+
+// leaderElectionOpTimeout 单次获取 / 续期 / 释放租约的超时
+const leaderElectionOpTimeout = 3 * time.Second
+
+// LeaderElectionService 集群模式下通过 Redis 租约选举唯一的领导者，
+// 只在领导者上运行 Token 刷新、账号健康探测与空闲预热等后台任务，所有实例照常处理请求。
+// 未启用 cluster 时每个实例都视为领导者，行为与单实例部署一致。
+type LeaderElectionService struct {
+	cache      LeaderLockCache
+	enabled    bool
+	instanceID string
+	lease      time.Duration
+
+	// leaseUntil 本实例持有租约的本地到期时间（unix 纳秒），0 表示非领导者。
+	// 续期失败时到期前仍视为领导者，到期后立即放弃，避免与新领导者重叠。
+	leaseUntil atomic.Int64
+
+	stopCh   chan struct{}
+	stopOnce sync.Once
+	wg       sync.WaitGroup
+}
+
+// NewLeaderElectionService 创建领导者选举服务
+func NewLeaderElectionService(cache LeaderLockCache, cfg *config.Config) *LeaderElectionService {
+	s := &LeaderElectionService{
+		cache:      cache,
+		instanceID: uuid.NewString(),
+		lease:      15 * time.Second,
+		stopCh:     make(chan struct{}),
+	}
+	if cfg != nil && cfg.Cluster.Enabled && cache != nil {
+		s.enabled = true
+		if cfg.Cluster.LeaderLeaseSeconds > 0 {
+			s.lease = time.Duration(cfg.Cluster.LeaderLeaseSeconds) * time.Second
+		}
+	}
+	return s
+}
+
+// Enabled 是否启用了集群领导者选举
+func (s *LeaderElectionService) Enabled() bool {
+	return s != nil && s.enabled
+}
+
+// InstanceID 本实例的选举 ID
+func (s *LeaderElectionService) InstanceID() string {
+	if s == nil {
+		return ""
+	}
+	return s.instanceID
+}
+
+// IsLeader 本实例是否应运行单实例后台任务；未启用集群模式时恒为 true
+func (s *LeaderElectionService) IsLeader() bool {
+	if !s.Enabled() {
+		return true
+	}
+	until := s.leaseUntil.Load()
+	return until > 0 && time.Now().UnixNano() < until
+}
+
+// Start 立即参与一次选举并启动续期循环（每 1/3 租约一次）
+func (s *LeaderElectionService) Start() {
+	if !s.Enabled() {
+		return
+	}
+	s.tick()
+	s.wg.Add(1)
+	go func() {
+		defer s.wg.Done()
+		ticker := time.NewTicker(s.lease / 3)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				s.tick()
+			case <-s.stopCh:
+				return
+			}
+		}
+	}()
+	slog.Info("cluster.leader_election_started",
+		"instance_id", s.instanceID,
+		"lease_seconds", int(s.lease/time.Second),
+	)
+}
+
+// Stop 停止续期循环；本实例为领导者时主动释放租约，其他实例无需等待租约到期即可接管
+func (s *LeaderElectionService) Stop() {
+	if !s.Enabled() {
+		return
+	}
+	s.stopOnce.Do(func() {
+		close(s.stopCh)
+	})
+	s.wg.Wait()
+
+	if s.leaseUntil.Swap(0) == 0 {
+		return
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), leaderElectionOpTimeout)
+	defer cancel()
+	if err := s.cache.ReleaseLeader(ctx, s.instanceID); err != nil {
+		slog.Warn("cluster.leader_release_failed", "instance_id", s.instanceID, "error", err)
+		return
+	}
+	slog.Info("cluster.leader_released", "instance_id", s.instanceID)
+}
+
+// tick 获取或续期租约，并在领导权变化时记录日志
+func (s *LeaderElectionService) tick() {
+	wasLeader := s.IsLeader()
+	start := time.Now()
+
+	ctx, cancel := context.WithTimeout(context.Background(), leaderElectionOpTimeout)
+	acquired, err := s.cache.AcquireLeader(ctx, s.instanceID, s.lease)
+	cancel()
+
+	switch {
+	case err != nil:
+		slog.Warn("cluster.leader_acquire_failed", "instance_id", s.instanceID, "error", err)
+		if wasLeader && !s.IsLeader() {
+			s.leaseUntil.Store(0)
+			slog.Warn("cluster.leadership_lost", "instance_id", s.instanceID, "reason", "lease_expired")
+		}
+	case acquired:
+		// 以发起请求的时间计算本地到期，保证本地视图不晚于 Redis 中的租约
+		s.leaseUntil.Store(start.Add(s.lease).UnixNano())
+		if !wasLeader {
+			slog.Info("cluster.leadership_acquired", "instance_id", s.instanceID)
+		}
+	default:
+		s.leaseUntil.Store(0)
+		if wasLeader {
+			slog.Warn("cluster.leadership_lost", "instance_id", s.instanceID, "reason", "lease_taken")
+		}
+	}
+}
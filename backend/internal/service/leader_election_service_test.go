@@ -0,0 +1,130 @@
+//go:build unit
+
+package service
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ShaohongDong/sub2api/internal/config"
+	"github.com/stretchr/testify/require"
+)
+
+// leaderLockCacheStub 内存版领导者租约，忽略租约到期
+type leaderLockCacheStub struct {
+	mu       sync.Mutex
+	owner    string
+	err      error
+	released []string
+}
+
+func (c *leaderLockCacheStub) AcquireLeader(_ context.Context, instanceID string, _ time.Duration) (bool, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.err != nil {
+		return false, c.err
+	}
+	if c.owner == "" || c.owner == instanceID {
+		c.owner = instanceID
+		return true, nil
+	}
+	return false, nil
+}
+
+func (c *leaderLockCacheStub) ReleaseLeader(_ context.Context, instanceID string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.released = append(c.released, instanceID)
+	if c.owner == instanceID {
+		c.owner = ""
+	}
+	return nil
+}
+
+func newClusterConfig() *config.Config {
+	return &config.Config{Cluster: config.ClusterConfig{Enabled: true, LeaderLeaseSeconds: 15}}
+}
+
+func TestLeaderElection_DisabledAlwaysLeader(t *testing.T) {
+	var nilSvc *LeaderElectionService
+	require.True(t, nilSvc.IsLeader())
+
+	svc := NewLeaderElectionService(&leaderLockCacheStub{owner: "other"}, &config.Config{})
+	svc.Start()
+	defer svc.Stop()
+	require.False(t, svc.Enabled())
+	require.True(t, svc.IsLeader())
+}
+
+func TestLeaderElection_OnlyOneLeaderAndFailover(t *testing.T) {
+	cache := &leaderLockCacheStub{}
+	a := NewLeaderElectionService(cache, newClusterConfig())
+	b := NewLeaderElectionService(cache, newClusterConfig())
+
+	a.tick()
+	b.tick()
+	require.True(t, a.IsLeader())
+	require.False(t, b.IsLeader())
+
+	// 领导者停止时释放租约，其他实例下一轮即可接管
+	a.Stop()
+	require.False(t, a.IsLeader())
+	require.Equal(t, []string{a.InstanceID()}, cache.released)
+	b.tick()
+	require.True(t, b.IsLeader())
+}
+
+func TestLeaderElection_RenewFailureKeepsLeadershipUntilLeaseExpires(t *testing.T) {
+	cache := &leaderLockCacheStub{}
+	svc := NewLeaderElectionService(cache, newClusterConfig())
+	svc.tick()
+	require.True(t, svc.IsLeader())
+
+	cache.err = errors.New("redis down")
+	svc.tick()
+	require.True(t, svc.IsLeader(), "lease still valid locally")
+
+	svc.leaseUntil.Store(time.Now().Add(-time.Second).UnixNano())
+	svc.tick()
+	require.False(t, svc.IsLeader())
+}
+
+func TestLeaderElection_LostLeaseTakenByOther(t *testing.T) {
+	cache := &leaderLockCacheStub{}
+	svc := NewLeaderElectionService(cache, newClusterConfig())
+	svc.tick()
+	require.True(t, svc.IsLeader())
+
+	cache.owner = "other"
+	svc.tick()
+	require.False(t, svc.IsLeader())
+}
+
+func TestAccountHealth_FollowerReleasesDueStatesWithoutProbing(t *testing.T) {
+	repo := &accountHealthRepoStub{}
+	svc := newAccountHealthTestService(repo)
+	svc.SetLeaderElection(NewLeaderElectionService(&leaderLockCacheStub{owner: "other"}, newClusterConfig()))
+	probed := false
+	svc.probe = func(ctx context.Context, accountID int64) error {
+		probed = true
+		return nil
+	}
+
+	svc.MarkUnhealthy(context.Background(), &Account{ID: 9}, 401, "unauthorized")
+	past := time.Now().Add(-time.Second)
+	svc.states[9].NextProbeAt = &past
+
+	svc.runDueProbes()
+	require.False(t, probed)
+	require.Nil(t, svc.GetState(9))
+}
+
+func TestAccountWarmup_FollowerSkipsRun(t *testing.T) {
+	svc := NewAccountWarmupService(nil, nil, nil, &config.Config{AccountWarmup: config.AccountWarmupConfig{Enabled: true}})
+	svc.SetLeaderElection(NewLeaderElectionService(&leaderLockCacheStub{owner: "other"}, newClusterConfig()))
+	// accountRepo 为 nil：若未跳过会直接 panic
+	require.NotPanics(t, func() { svc.runOnce(time.Now()) })
+}
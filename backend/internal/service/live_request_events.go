@@ -0,0 +1,194 @@
+package service
+
+import (
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	infraerrors "github.com/ShaohongDong/sub2api/internal/pkg/errors"
+)
+
+// 管理后台实时请求事件流（live tail）。
+// 事件只在进程内广播、不落库；多实例部署时每个实例只推送自身处理的请求。
+// 成功请求在用量日志写入后发布（携带 token 与费用），失败请求（HTTP 状态 >= 400）由请求中间件发布。
+
+const (
+	// maxLiveRequestSubscribers 同时订阅的连接上限，超出后拒绝新订阅
+	maxLiveRequestSubscribers = 32
+	// liveRequestSubscriberBuffer 每个订阅者的事件缓冲，消费跟不上时丢弃新事件而不阻塞网关热路径
+	liveRequestSubscriberBuffer = 256
+)
+
+var ErrLiveRequestSubscribersExceeded = infraerrors.ServiceUnavailable("LIVE_EVENT_SUBSCRIBERS_EXCEEDED", "too many live event subscribers")
+
+// LiveRequestEvent 单个请求的实时事件
+type LiveRequestEvent struct {
+	Timestamp           time.Time `json:"timestamp"`
+	RequestID           string    `json:"request_id,omitempty"`
+	APIKeyID            int64     `json:"api_key_id"`
+	UserID              int64     `json:"user_id,omitempty"`
+	AccountID           int64     `json:"account_id,omitempty"`
+	Platform            string    `json:"platform,omitempty"`
+	Model               string    `json:"model,omitempty"`
+	Status              int       `json:"status"`
+	LatencyMs           int64     `json:"latency_ms"`
+	FirstTokenMs        *int      `json:"first_token_ms,omitempty"`
+	Stream              bool      `json:"stream"`
+	InputTokens         int       `json:"input_tokens"`
+	OutputTokens        int       `json:"output_tokens"`
+	CacheCreationTokens int       `json:"cache_creation_tokens"`
+	CacheReadTokens     int       `json:"cache_read_tokens"`
+	ActualCost          float64   `json:"actual_cost"`
+}
+
+// LiveRequestEventFilter 订阅过滤条件，零值字段不参与过滤
+type LiveRequestEventFilter struct {
+	APIKeyID   int64
+	AccountID  int64
+	Model      string
+	ErrorsOnly bool
+}
+
+func (f LiveRequestEventFilter) match(e *LiveRequestEvent) bool {
+	if f.APIKeyID > 0 && e.APIKeyID != f.APIKeyID {
+		return false
+	}
+	if f.AccountID > 0 && e.AccountID != f.AccountID {
+		return false
+	}
+	if f.Model != "" && !strings.EqualFold(e.Model, f.Model) {
+		return false
+	}
+	if f.ErrorsOnly && e.Status < 400 {
+		return false
+	}
+	return true
+}
+
+// LiveRequestSubscription 一个实时事件订阅
+type LiveRequestSubscription struct {
+	hub     *liveRequestHub
+	filter  LiveRequestEventFilter
+	events  chan LiveRequestEvent
+	dropped atomic.Int64
+	once    sync.Once
+}
+
+// Events 事件通道；取消订阅后关闭
+func (s *LiveRequestSubscription) Events() <-chan LiveRequestEvent {
+	return s.events
+}
+
+// Dropped 因消费过慢被丢弃的事件数
+func (s *LiveRequestSubscription) Dropped() int64 {
+	return s.dropped.Load()
+}
+
+// Close 取消订阅，可重复调用
+func (s *LiveRequestSubscription) Close() {
+	s.once.Do(func() {
+		s.hub.remove(s)
+	})
+}
+
+type liveRequestHub struct {
+	mu          sync.RWMutex
+	subscribers map[*LiveRequestSubscription]struct{}
+	// active 订阅者数量，无人订阅时发布方可跳过构造事件
+	active atomic.Int32
+}
+
+var liveRequestEvents = &liveRequestHub{subscribers: make(map[*LiveRequestSubscription]struct{})}
+
+func (h *liveRequestHub) subscribe(filter LiveRequestEventFilter) (*LiveRequestSubscription, error) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if len(h.subscribers) >= maxLiveRequestSubscribers {
+		return nil, ErrLiveRequestSubscribersExceeded
+	}
+	sub := &LiveRequestSubscription{
+		hub:    h,
+		filter: filter,
+		events: make(chan LiveRequestEvent, liveRequestSubscriberBuffer),
+	}
+	h.subscribers[sub] = struct{}{}
+	h.active.Store(int32(len(h.subscribers)))
+	return sub, nil
+}
+
+func (h *liveRequestHub) remove(sub *LiveRequestSubscription) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if _, ok := h.subscribers[sub]; !ok {
+		return
+	}
+	delete(h.subscribers, sub)
+	h.active.Store(int32(len(h.subscribers)))
+	close(sub.events)
+}
+
+func (h *liveRequestHub) publish(event LiveRequestEvent) {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	for sub := range h.subscribers {
+		if !sub.filter.match(&event) {
+			continue
+		}
+		select {
+		case sub.events <- event:
+		default:
+			sub.dropped.Add(1)
+		}
+	}
+}
+
+// SubscribeLiveRequestEvents 订阅本实例的实时请求事件，调用方须在结束时 Close
+func SubscribeLiveRequestEvents(filter LiveRequestEventFilter) (*LiveRequestSubscription, error) {
+	filter.Model = strings.TrimSpace(filter.Model)
+	return liveRequestEvents.subscribe(filter)
+}
+
+// HasLiveRequestSubscribers 是否有订阅者，供发布方在热路径上提前跳过
+func HasLiveRequestSubscribers() bool {
+	return liveRequestEvents.active.Load() > 0
+}
+
+// PublishLiveRequestEvent 广播一个请求事件（非阻塞）
+func PublishLiveRequestEvent(event LiveRequestEvent) {
+	if !HasLiveRequestSubscribers() {
+		return
+	}
+	if event.Timestamp.IsZero() {
+		event.Timestamp = time.Now()
+	}
+	liveRequestEvents.publish(event)
+}
+
+// publishUsageLogLiveEvent 用量日志写入成功后发布成功请求事件
+func publishUsageLogLiveEvent(log *UsageLog, platform string) {
+	if log == nil || !HasLiveRequestSubscribers() {
+		return
+	}
+	event := LiveRequestEvent{
+		Timestamp:           log.CreatedAt,
+		RequestID:           log.RequestID,
+		APIKeyID:            log.APIKeyID,
+		UserID:              log.UserID,
+		AccountID:           log.AccountID,
+		Platform:            platform,
+		Model:               log.Model,
+		Status:              200,
+		FirstTokenMs:        log.FirstTokenMs,
+		Stream:              log.Stream,
+		InputTokens:         log.InputTokens,
+		OutputTokens:        log.OutputTokens,
+		CacheCreationTokens: log.CacheCreationTokens,
+		CacheReadTokens:     log.CacheReadTokens,
+		ActualCost:          log.ActualCost,
+	}
+	if log.DurationMs != nil {
+		event.LatencyMs = int64(*log.DurationMs)
+	}
+	PublishLiveRequestEvent(event)
+}
@@ -0,0 +1,82 @@
+//go:build unit
+
+package service
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestLiveRequestEventsFilterAndDrop(t *testing.T) {
+	all, err := SubscribeLiveRequestEvents(LiveRequestEventFilter{})
+	require.NoError(t, err)
+	defer all.Close()
+	errorsOnly, err := SubscribeLiveRequestEvents(LiveRequestEventFilter{APIKeyID: 7, ErrorsOnly: true})
+	require.NoError(t, err)
+	defer errorsOnly.Close()
+	require.True(t, HasLiveRequestSubscribers())
+
+	PublishLiveRequestEvent(LiveRequestEvent{APIKeyID: 7, Model: "gpt-5", Status: 200})
+	PublishLiveRequestEvent(LiveRequestEvent{APIKeyID: 7, Model: "gpt-5", Status: 429})
+	PublishLiveRequestEvent(LiveRequestEvent{APIKeyID: 8, Model: "gpt-5", Status: 500})
+
+	require.Len(t, all.Events(), 3)
+	require.Len(t, errorsOnly.Events(), 1)
+	got := <-errorsOnly.Events()
+	require.Equal(t, 429, got.Status)
+	require.False(t, got.Timestamp.IsZero())
+
+	// 缓冲写满后丢弃新事件而不阻塞发布方
+	for i := 0; i < liveRequestSubscriberBuffer; i++ {
+		PublishLiveRequestEvent(LiveRequestEvent{APIKeyID: 9, Status: 200})
+	}
+	require.Equal(t, int64(3), all.Dropped())
+	require.Zero(t, errorsOnly.Dropped())
+}
+
+func TestLiveRequestEventsCloseAndLimit(t *testing.T) {
+	subs := make([]*LiveRequestSubscription, 0, maxLiveRequestSubscribers)
+	for i := 0; i < maxLiveRequestSubscribers; i++ {
+		sub, err := SubscribeLiveRequestEvents(LiveRequestEventFilter{})
+		require.NoError(t, err)
+		subs = append(subs, sub)
+	}
+	_, err := SubscribeLiveRequestEvents(LiveRequestEventFilter{})
+	require.ErrorIs(t, err, ErrLiveRequestSubscribersExceeded)
+
+	for _, sub := range subs {
+		sub.Close()
+		sub.Close()
+		_, ok := <-sub.Events()
+		require.False(t, ok)
+	}
+	require.False(t, HasLiveRequestSubscribers())
+}
+
+func TestPublishUsageLogLiveEvent(t *testing.T) {
+	sub, err := SubscribeLiveRequestEvents(LiveRequestEventFilter{Model: " claude-sonnet-4 "})
+	require.NoError(t, err)
+	defer sub.Close()
+
+	duration := 1200
+	publishUsageLogLiveEvent(&UsageLog{
+		RequestID:    "req-1",
+		APIKeyID:     3,
+		AccountID:    5,
+		Model:        "claude-sonnet-4",
+		InputTokens:  10,
+		OutputTokens: 20,
+		ActualCost:   0.5,
+		DurationMs:   &duration,
+		CreatedAt:    time.Now(),
+	}, PlatformAnthropic)
+
+	got := <-sub.Events()
+	require.Equal(t, "req-1", got.RequestID)
+	require.Equal(t, 200, got.Status)
+	require.Equal(t, int64(1200), got.LatencyMs)
+	require.Equal(t, PlatformAnthropic, got.Platform)
+	require.Equal(t, 20, got.OutputTokens)
+}
@@ -0,0 +1,191 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+	"unicode/utf8"
+
+	infraerrors "github.com/ShaohongDong/sub2api/internal/pkg/errors"
+)
+
+const (
+	// maintenanceRefreshInterval 定期从数据库重新加载维护状态，使其他实例的切换在该间隔内生效
+	maintenanceRefreshInterval = 5 * time.Second
+	maintenanceDBTimeout       = 5 * time.Second
+
+	// DefaultMaintenanceMessage 未填写提示语时返回给客户端的说明
+	DefaultMaintenanceMessage       = "The service is undergoing scheduled maintenance. Please retry shortly."
+	defaultMaintenanceRetryAfter    = 60
+	maxMaintenanceRetryAfterSeconds = 3600
+	maxMaintenanceMessageLength     = 500
+)
+
+// MaintenanceState 网关维护模式状态
+type MaintenanceState struct {
+	Enabled bool `json:"enabled"`
+	// Message 返回给客户端的提示语，为空时使用默认提示
+	Message string `json:"message"`
+	// RetryAfterSeconds 写入 Retry-After 响应头的秒数
+	RetryAfterSeconds int `json:"retry_after_seconds"`
+
+	StartedAt       string `json:"started_at,omitempty"`
+	UpdatedAt       string `json:"updated_at,omitempty"`
+	UpdatedByUserID int64  `json:"updated_by_user_id,omitempty"`
+}
+
+func defaultMaintenanceState() *MaintenanceState {
+	return &MaintenanceState{RetryAfterSeconds: defaultMaintenanceRetryAfter}
+}
+
+func validateMaintenanceState(st *MaintenanceState) error {
+	st.Message = strings.TrimSpace(st.Message)
+	if utf8.RuneCountInString(st.Message) > maxMaintenanceMessageLength {
+		return fmt.Errorf("message must be at most %d characters", maxMaintenanceMessageLength)
+	}
+	if st.RetryAfterSeconds == 0 {
+		st.RetryAfterSeconds = defaultMaintenanceRetryAfter
+	}
+	if st.RetryAfterSeconds < 1 || st.RetryAfterSeconds > maxMaintenanceRetryAfterSeconds {
+		return fmt.Errorf("retry_after_seconds must be between 1 and %d", maxMaintenanceRetryAfterSeconds)
+	}
+	return nil
+}
+
+// MaintenanceService 管理网关维护模式：开启后网关拒绝新的推理请求（503 + Retry-After），
+// 在途请求与流式响应照常完成，管理后台、健康检查与后台任务（令牌刷新、健康探测等）不受影响。
+// 状态持久化在 settings 表，切换后立即在本实例生效，其他实例在 maintenanceRefreshInterval 内生效；
+// 数据库不可用（如迁移期间）时保留最后一次加载的状态。
+type MaintenanceService struct {
+	settingRepo SettingRepository
+	active      atomic.Pointer[MaintenanceState]
+
+	mu       sync.Mutex // 串行化修改
+	stopCh   chan struct{}
+	stopOnce sync.Once
+	wg       sync.WaitGroup
+}
+
+// NewMaintenanceService 创建维护模式服务
+func NewMaintenanceService(settingRepo SettingRepository) *MaintenanceService {
+	s := &MaintenanceService{settingRepo: settingRepo, stopCh: make(chan struct{})}
+	s.active.Store(defaultMaintenanceState())
+	return s
+}
+
+// Start 加载已保存的维护状态，随后定期重新加载
+func (s *MaintenanceService) Start() {
+	s.refresh()
+	s.wg.Add(1)
+	go func() {
+		defer s.wg.Done()
+		ticker := time.NewTicker(maintenanceRefreshInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				s.refresh()
+			case <-s.stopCh:
+				return
+			}
+		}
+	}()
+}
+
+// Stop 停止定期加载
+func (s *MaintenanceService) Stop() {
+	s.stopOnce.Do(func() { close(s.stopCh) })
+	s.wg.Wait()
+}
+
+func (s *MaintenanceService) refresh() {
+	ctx, cancel := context.WithTimeout(context.Background(), maintenanceDBTimeout)
+	defer cancel()
+	st, err := s.load(ctx)
+	if err != nil {
+		slog.Warn("maintenance.refresh_failed", "error", err)
+		return
+	}
+	if prev := s.active.Load(); prev.Enabled != st.Enabled {
+		slog.Info("maintenance.state_changed", "enabled", st.Enabled)
+	}
+	s.active.Store(st)
+}
+
+// Active 返回生效中的维护状态（网关热路径无锁读取），未开启维护模式时返回 nil
+func (s *MaintenanceService) Active() *MaintenanceState {
+	if s == nil {
+		return nil
+	}
+	if st := s.active.Load(); st != nil && st.Enabled {
+		return st
+	}
+	return nil
+}
+
+// Get 返回当前维护状态
+func (s *MaintenanceService) Get(ctx context.Context) (*MaintenanceState, error) {
+	return s.load(ctx)
+}
+
+func (s *MaintenanceService) load(ctx context.Context) (*MaintenanceState, error) {
+	raw, err := s.settingRepo.GetValue(ctx, SettingKeyMaintenanceMode)
+	if err != nil {
+		if errors.Is(err, ErrSettingNotFound) {
+			return defaultMaintenanceState(), nil
+		}
+		return nil, fmt.Errorf("get maintenance state: %w", err)
+	}
+	st := defaultMaintenanceState()
+	if err := json.Unmarshal([]byte(raw), st); err != nil {
+		return nil, fmt.Errorf("decode maintenance state: %w", err)
+	}
+	if err := validateMaintenanceState(st); err != nil {
+		slog.Warn("maintenance.invalid_stored_value", "error", err)
+		st.RetryAfterSeconds = defaultMaintenanceRetryAfter
+	}
+	return st, nil
+}
+
+// Update 校验并保存维护状态，立即在本实例生效
+func (s *MaintenanceService) Update(ctx context.Context, req *MaintenanceState, operatorID int64) (*MaintenanceState, error) {
+	if req == nil {
+		return nil, infraerrors.BadRequest("MAINTENANCE_INVALID", "invalid maintenance state")
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	next := *req
+	if err := validateMaintenanceState(&next); err != nil {
+		return nil, infraerrors.BadRequest("MAINTENANCE_INVALID", err.Error())
+	}
+	now := time.Now().UTC().Format(time.RFC3339Nano)
+	next.StartedAt = ""
+	if next.Enabled {
+		// 保持已开启维护的起始时间，仅修改提示语时不重置
+		if cur, err := s.load(ctx); err == nil && cur.Enabled && cur.StartedAt != "" {
+			next.StartedAt = cur.StartedAt
+		} else {
+			next.StartedAt = now
+		}
+	}
+	next.UpdatedAt = now
+	next.UpdatedByUserID = operatorID
+
+	data, err := json.Marshal(&next)
+	if err != nil {
+		return nil, fmt.Errorf("marshal maintenance state: %w", err)
+	}
+	if err := s.settingRepo.Set(ctx, SettingKeyMaintenanceMode, string(data)); err != nil {
+		return nil, fmt.Errorf("save maintenance state: %w", err)
+	}
+	s.active.Store(&next)
+	slog.Info("maintenance.updated", "enabled", next.Enabled, "operator_id", operatorID)
+	return &next, nil
+}
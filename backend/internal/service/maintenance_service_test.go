@@ -0,0 +1,64 @@
+//go:build unit
+
+package service
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	infraerrors "github.com/ShaohongDong/sub2api/internal/pkg/errors"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMaintenanceService_UpdateAppliesImmediately(t *testing.T) {
+	repo := &runtimeSettingsRepoStub{values: map[string]string{}}
+	svc := NewMaintenanceService(repo)
+	require.Nil(t, svc.Active())
+
+	st, err := svc.Update(context.Background(), &MaintenanceState{Enabled: true, Message: "  migrating  "}, 7)
+	require.NoError(t, err)
+	require.Equal(t, "migrating", st.Message)
+	require.Equal(t, defaultMaintenanceRetryAfter, st.RetryAfterSeconds)
+	require.NotEmpty(t, st.StartedAt)
+	require.Equal(t, int64(7), st.UpdatedByUserID)
+	require.Same(t, st, svc.Active())
+	require.Contains(t, repo.values[SettingKeyMaintenanceMode], `"enabled":true`)
+
+	// 维护中修改提示语不重置起始时间
+	updated, err := svc.Update(context.Background(), &MaintenanceState{Enabled: true, RetryAfterSeconds: 120}, 8)
+	require.NoError(t, err)
+	require.Equal(t, st.StartedAt, updated.StartedAt)
+	require.Equal(t, 120, svc.Active().RetryAfterSeconds)
+
+	off, err := svc.Update(context.Background(), &MaintenanceState{Enabled: false}, 7)
+	require.NoError(t, err)
+	require.Empty(t, off.StartedAt)
+	require.Nil(t, svc.Active())
+}
+
+func TestMaintenanceService_RefreshPicksUpOtherInstances(t *testing.T) {
+	repo := &runtimeSettingsRepoStub{values: map[string]string{}}
+	svc := NewMaintenanceService(repo)
+
+	repo.values[SettingKeyMaintenanceMode] = `{"enabled":true,"retry_after_seconds":30}`
+	svc.refresh()
+	require.NotNil(t, svc.Active())
+	require.Equal(t, 30, svc.Active().RetryAfterSeconds)
+
+	// 存储值损坏时保留上一次加载的状态
+	repo.values[SettingKeyMaintenanceMode] = `{broken`
+	svc.refresh()
+	require.NotNil(t, svc.Active())
+}
+
+func TestMaintenanceService_UpdateRejectsInvalid(t *testing.T) {
+	svc := NewMaintenanceService(&runtimeSettingsRepoStub{values: map[string]string{}})
+
+	_, err := svc.Update(context.Background(), &MaintenanceState{Enabled: true, RetryAfterSeconds: maxMaintenanceRetryAfterSeconds + 1}, 1)
+	require.Equal(t, "MAINTENANCE_INVALID", infraerrors.Reason(err))
+
+	_, err = svc.Update(context.Background(), &MaintenanceState{Enabled: true, Message: strings.Repeat("x", maxMaintenanceMessageLength+1)}, 1)
+	require.Equal(t, "MAINTENANCE_INVALID", infraerrors.Reason(err))
+	require.Nil(t, svc.Active())
+}
@@ -0,0 +1,203 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/ShaohongDong/sub2api/internal/pkg/ctxkey"
+	infraerrors "github.com/ShaohongDong/sub2api/internal/pkg/errors"
+	"golang.org/x/sync/singleflight"
+)
+
+// maxModelAliases 单张别名表（全局或分组）的最大条目数
+const maxModelAliases = 200
+
+// maxModelAliasNameLen 别名表中模型名的最大长度
+const maxModelAliasNameLen = 100
+
+var ErrInvalidModelAliases = infraerrors.BadRequest("INVALID_MODEL_ALIASES", "invalid model aliases (source supports a trailing * wildcard, target must be a concrete model)")
+
+// normalizeModelAliases 去除首尾空白并校验别名表：源模型仅允许末尾 * 通配符，目标模型必须是具体模型名。
+// 输入为空时返回 nil。
+func normalizeModelAliases(aliases map[string]string) (map[string]string, error) {
+	if len(aliases) == 0 {
+		return nil, nil
+	}
+	out := make(map[string]string, len(aliases))
+	for from, to := range aliases {
+		from = strings.TrimSpace(from)
+		to = strings.TrimSpace(to)
+		if from == "" {
+			continue
+		}
+		if strings.Contains(strings.TrimSuffix(from, "*"), "*") || to == "" || strings.Contains(to, "*") ||
+			len(from) > maxModelAliasNameLen || len(to) > maxModelAliasNameLen {
+			return nil, ErrInvalidModelAliases.WithMetadata(map[string]string{"model": from})
+		}
+		out[from] = to
+	}
+	if len(out) > maxModelAliases {
+		return nil, ErrInvalidModelAliases.WithMetadata(map[string]string{"reason": "too many aliases"})
+	}
+	if len(out) == 0 {
+		return nil, nil
+	}
+	return out, nil
+}
+
+// lookupModelAlias 在别名表中查找模型：精确匹配优先，其次最长的通配符前缀
+func lookupModelAlias(aliases map[string]string, model string) (string, bool) {
+	if len(aliases) == 0 || model == "" {
+		return "", false
+	}
+	if to, ok := aliases[model]; ok {
+		return to, true
+	}
+	best, target := "", ""
+	for pattern, to := range aliases {
+		if !strings.HasSuffix(pattern, "*") || !matchModelPattern(pattern, model) {
+			continue
+		}
+		if len(pattern) > len(best) || (len(pattern) == len(best) && pattern < best) {
+			best, target = pattern, to
+		}
+	}
+	return target, best != ""
+}
+
+// ResolveModelAlias 按分组别名表、全局别名表的顺序解析请求模型对应的上游模型。
+// 分组表命中即生效（包括映射到自身，用于在分组内屏蔽某条全局别名）；未命中任何别名时返回原模型与 false。
+func ResolveModelAlias(group *Group, global map[string]string, model string) (string, bool) {
+	model = strings.TrimSpace(model)
+	if group != nil {
+		if to, ok := lookupModelAlias(group.ModelAliases, model); ok {
+			return to, to != model
+		}
+	}
+	if to, ok := lookupModelAlias(global, model); ok {
+		return to, to != model
+	}
+	return model, false
+}
+
+// WithRequestedModel 记录模型别名改写前客户端请求的模型名
+func WithRequestedModel(ctx context.Context, model string) context.Context {
+	return context.WithValue(ctx, ctxkey.RequestedModel, model)
+}
+
+// RequestedModelFromContext 返回模型别名改写前客户端请求的模型名；请求未经别名改写时返回 false
+func RequestedModelFromContext(ctx context.Context) (string, bool) {
+	if ctx == nil {
+		return "", false
+	}
+	model, ok := ctx.Value(ctxkey.RequestedModel).(string)
+	return model, ok && model != ""
+}
+
+// cachedModelAliases 全局别名表进程内缓存
+type cachedModelAliases struct {
+	aliases   map[string]string
+	expiresAt int64 // unix nano
+}
+
+var modelAliasCache atomic.Value // *cachedModelAliases
+
+var modelAliasSF singleflight.Group
+
+// modelAliasCacheTTL 缓存有效期（与版本号要求缓存一致）
+const modelAliasCacheTTL = 60 * time.Second
+
+// GetModelAliasSettings 获取全局模型别名表
+func (s *SettingService) GetModelAliasSettings(ctx context.Context) (*ModelAliasSettings, error) {
+	value, err := s.settingRepo.GetValue(ctx, SettingKeyModelAliasSettings)
+	if err != nil {
+		if errors.Is(err, ErrSettingNotFound) {
+			return DefaultModelAliasSettings(), nil
+		}
+		return nil, fmt.Errorf("get model alias settings: %w", err)
+	}
+	if value == "" {
+		return DefaultModelAliasSettings(), nil
+	}
+
+	var settings ModelAliasSettings
+	if err := json.Unmarshal([]byte(value), &settings); err != nil {
+		return DefaultModelAliasSettings(), nil
+	}
+	if settings.Aliases == nil {
+		settings.Aliases = map[string]string{}
+	}
+	return &settings, nil
+}
+
+// SetModelAliasSettings 设置全局模型别名表，并立即刷新本实例缓存（其他实例在缓存过期后生效）
+func (s *SettingService) SetModelAliasSettings(ctx context.Context, settings *ModelAliasSettings) error {
+	if settings == nil {
+		return fmt.Errorf("settings cannot be nil")
+	}
+	aliases, err := normalizeModelAliases(settings.Aliases)
+	if err != nil {
+		return err
+	}
+	if aliases == nil {
+		aliases = map[string]string{}
+	}
+	settings.Aliases = aliases
+
+	data, err := json.Marshal(settings)
+	if err != nil {
+		return fmt.Errorf("marshal model alias settings: %w", err)
+	}
+	if err := s.settingRepo.Set(ctx, SettingKeyModelAliasSettings, string(data)); err != nil {
+		return err
+	}
+	modelAliasSF.Forget("model_aliases")
+	modelAliasCache.Store(&cachedModelAliases{
+		aliases:   aliases,
+		expiresAt: time.Now().Add(modelAliasCacheTTL).UnixNano(),
+	})
+	return nil
+}
+
+// GetModelAliases 返回全局别名表（进程内缓存，60 秒 TTL），供网关热路径使用。
+// 读取失败时返回空表（fail-open），不阻塞请求。
+func (s *SettingService) GetModelAliases(ctx context.Context) map[string]string {
+	if s == nil {
+		return nil
+	}
+	if cached, ok := modelAliasCache.Load().(*cachedModelAliases); ok {
+		if time.Now().UnixNano() < cached.expiresAt {
+			return cached.aliases
+		}
+	}
+	result, _, _ := modelAliasSF.Do("model_aliases", func() (any, error) {
+		if cached, ok := modelAliasCache.Load().(*cachedModelAliases); ok {
+			if time.Now().UnixNano() < cached.expiresAt {
+				return cached.aliases, nil
+			}
+		}
+		dbCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), versionBoundsDBTimeout)
+		defer cancel()
+		settings, err := s.GetModelAliasSettings(dbCtx)
+		if err != nil {
+			slog.Warn("failed to get model alias settings, skipping aliases", "error", err)
+			modelAliasCache.Store(&cachedModelAliases{
+				expiresAt: time.Now().Add(versionBoundsErrorTTL).UnixNano(),
+			})
+			return map[string]string(nil), nil
+		}
+		modelAliasCache.Store(&cachedModelAliases{
+			aliases:   settings.Aliases,
+			expiresAt: time.Now().Add(modelAliasCacheTTL).UnixNano(),
+		})
+		return settings.Aliases, nil
+	})
+	aliases, _ := result.(map[string]string)
+	return aliases
+}
@@ -0,0 +1,114 @@
+//go:build unit
+
+package service
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/ShaohongDong/sub2api/internal/config"
+	infraerrors "github.com/ShaohongDong/sub2api/internal/pkg/errors"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNormalizeModelAliases(t *testing.T) {
+	got, err := normalizeModelAliases(map[string]string{
+		" claude-sonnet ": " claude-sonnet-4-5 ",
+		"gpt-4*":          "gpt-4.1",
+		"":                "ignored",
+	})
+	require.NoError(t, err)
+	require.Equal(t, map[string]string{"claude-sonnet": "claude-sonnet-4-5", "gpt-4*": "gpt-4.1"}, got)
+
+	got, err = normalizeModelAliases(map[string]string{" ": "x"})
+	require.NoError(t, err)
+	require.Nil(t, got)
+
+	invalid := []map[string]string{
+		{"gpt-*-mini": "gpt-4o-mini"},
+		{"gpt-4": ""},
+		{"gpt-4": "gpt-4*"},
+		{strings.Repeat("a", maxModelAliasNameLen+1): "gpt-4"},
+	}
+	for _, aliases := range invalid {
+		_, err := normalizeModelAliases(aliases)
+		require.ErrorIs(t, err, ErrInvalidModelAliases, "%v", aliases)
+		require.True(t, infraerrors.IsBadRequest(err))
+	}
+
+	tooMany := make(map[string]string, maxModelAliases+1)
+	for i := 0; i <= maxModelAliases; i++ {
+		tooMany[strings.Repeat("m", i+1)] = "gpt-4"
+	}
+	_, err = normalizeModelAliases(tooMany)
+	require.ErrorIs(t, err, ErrInvalidModelAliases)
+}
+
+func TestResolveModelAlias(t *testing.T) {
+	global := map[string]string{
+		"sonnet":    "claude-sonnet-4-5",
+		"claude-*":  "claude-haiku-4-5",
+		"claude-o*": "claude-opus-4-1",
+		"fast":      "gpt-4o-mini",
+	}
+	group := &Group{ModelAliases: map[string]string{
+		"sonnet": "claude-sonnet-4",
+		"fast":   "fast",
+	}}
+
+	cases := []struct {
+		name    string
+		group   *Group
+		model   string
+		want    string
+		aliased bool
+	}{
+		{"exact global", nil, "sonnet", "claude-sonnet-4-5", true},
+		{"longest wildcard wins", nil, "claude-opus-4", "claude-opus-4-1", true},
+		{"shorter wildcard", nil, "claude-3-haiku", "claude-haiku-4-5", true},
+		{"no match", nil, "gpt-4o", "gpt-4o", false},
+		{"group overrides global", group, "sonnet", "claude-sonnet-4", true},
+		{"group self-mapping opts out of global", group, "fast", "fast", false},
+		{"falls back to global", group, "claude-opus-4", "claude-opus-4-1", true},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			got, ok := ResolveModelAlias(tc.group, global, tc.model)
+			require.Equal(t, tc.want, got)
+			require.Equal(t, tc.aliased, ok)
+		})
+	}
+}
+
+func TestRequestedModelFromContext(t *testing.T) {
+	_, ok := RequestedModelFromContext(context.Background())
+	require.False(t, ok)
+
+	model, ok := RequestedModelFromContext(WithRequestedModel(context.Background(), "sonnet"))
+	require.True(t, ok)
+	require.Equal(t, "sonnet", model)
+}
+
+func TestSettingService_SetModelAliasSettings_ValidatesAndRefreshesCache(t *testing.T) {
+	repo := newRuntimeSettingRepoStub()
+	svc := NewSettingService(repo, &config.Config{})
+	ctx := context.Background()
+	t.Cleanup(func() { modelAliasCache.Store(&cachedModelAliases{}) })
+
+	got, err := svc.GetModelAliasSettings(ctx)
+	require.NoError(t, err)
+	require.Empty(t, got.Aliases)
+
+	err = svc.SetModelAliasSettings(ctx, &ModelAliasSettings{Aliases: map[string]string{"a*b": "x"}})
+	require.ErrorIs(t, err, ErrInvalidModelAliases)
+
+	require.NoError(t, svc.SetModelAliasSettings(ctx, &ModelAliasSettings{Aliases: map[string]string{" sonnet ": "claude-sonnet-4-5"}}))
+	got, err = svc.GetModelAliasSettings(ctx)
+	require.NoError(t, err)
+	require.Equal(t, map[string]string{"sonnet": "claude-sonnet-4-5"}, got.Aliases)
+
+	// 写入后立即刷新本实例缓存
+	repo.values[SettingKeyModelAliasSettings] = `{"aliases":{"stale":"x"}}`
+	require.Equal(t, map[string]string{"sonnet": "claude-sonnet-4-5"}, svc.GetModelAliases(ctx))
+}
@@ -0,0 +1,139 @@
+package service
+
+import (
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/ShaohongDong/sub2api/internal/pkg/antigravity"
+	"github.com/ShaohongDong/sub2api/internal/pkg/claude"
+	"github.com/ShaohongDong/sub2api/internal/pkg/geminicli"
+	"github.com/ShaohongDong/sub2api/internal/pkg/openai"
+)
+
+// openAIPlanRestrictedModels 仅部分 ChatGPT 订阅计划提供的模型：OAuth 账号按 plan_type 过滤，
+// API Key 账号不受限制。
+var openAIPlanRestrictedModels = map[string][]string{
+	"gpt-5.3-codex-spark": {"pro"},
+}
+
+// aggregateAccountModelIDs 汇总账号池中当前可用账号的模型 ID（去重、排序）。
+func aggregateAccountModelIDs(accounts []Account, now time.Time) []string {
+	modelSet := make(map[string]struct{})
+	for i := range accounts {
+		acc := &accounts[i]
+		if !isAccountAvailableForModelList(acc, now) {
+			continue
+		}
+		for _, id := range accountModelIDs(acc) {
+			modelSet[id] = struct{}{}
+		}
+	}
+	models := make([]string, 0, len(modelSet))
+	for id := range modelSet {
+		models = append(models, id)
+	}
+	sort.Strings(models)
+	return models
+}
+
+// isAccountAvailableForModelList 判断账号当前能否承接请求（限流、过载、临时不可调度、已过期的账号不计入模型列表）。
+// 账号状态与 schedulable 开关已由 ListSchedulable 查询过滤。
+func isAccountAvailableForModelList(acc *Account, now time.Time) bool {
+	if acc.RateLimitResetAt != nil && now.Before(*acc.RateLimitResetAt) {
+		return false
+	}
+	if acc.OverloadUntil != nil && now.Before(*acc.OverloadUntil) {
+		return false
+	}
+	if acc.TempUnschedulableUntil != nil && now.Before(*acc.TempUnschedulableUntil) {
+		return false
+	}
+	if acc.AutoPauseOnExpired && acc.ExpiresAt != nil && !now.Before(*acc.ExpiresAt) {
+		return false
+	}
+	return true
+}
+
+// accountModelIDs 返回账号对外提供的模型 ID：配置了 model_mapping 时为映射的请求侧名称
+// （通配符按平台默认模型展开），否则为平台默认模型。
+func accountModelIDs(acc *Account) []string {
+	mapping := acc.GetModelMapping()
+	if len(mapping) == 0 {
+		return defaultModelIDsForAccount(acc)
+	}
+	ids := make([]string, 0, len(mapping))
+	var defaults []string
+	for pattern := range mapping {
+		if !strings.Contains(pattern, "*") {
+			ids = append(ids, pattern)
+			continue
+		}
+		if defaults == nil {
+			defaults = defaultModelIDsForAccount(acc)
+		}
+		for _, id := range defaults {
+			if matchWildcard(pattern, id) {
+				ids = append(ids, id)
+			}
+		}
+	}
+	return ids
+}
+
+// defaultModelIDsForAccount 返回账号所属平台的默认模型，并按订阅计划过滤。
+func defaultModelIDsForAccount(acc *Account) []string {
+	switch acc.Platform {
+	case PlatformOpenAI:
+		ids := make([]string, 0, len(openai.DefaultModels))
+		for _, m := range openai.DefaultModels {
+			if openAIPlanAllowsModel(acc, m.ID) {
+				ids = append(ids, m.ID)
+			}
+		}
+		// 模型目录同步发现的该账号可用的上游新模型
+		for _, id := range acc.CodexModels() {
+			if !slices.Contains(ids, id) && openAIPlanAllowsModel(acc, id) {
+				ids = append(ids, id)
+			}
+		}
+		return ids
+	case PlatformGemini:
+		ids := make([]string, 0, len(geminicli.DefaultModels))
+		for _, m := range geminicli.DefaultModels {
+			ids = append(ids, m.ID)
+		}
+		return ids
+	case PlatformAntigravity:
+		models := antigravity.DefaultModels()
+		ids := make([]string, 0, len(models))
+		for _, m := range models {
+			ids = append(ids, m.ID)
+		}
+		return ids
+	case PlatformSora:
+		models := DefaultSoraModels(nil)
+		ids := make([]string, 0, len(models))
+		for _, m := range models {
+			ids = append(ids, m.ID)
+		}
+		return ids
+	default:
+		return claude.DefaultModelIDs()
+	}
+}
+
+func openAIPlanAllowsModel(acc *Account, model string) bool {
+	plans, restricted := openAIPlanRestrictedModels[model]
+	if !restricted || acc.Type != AccountTypeOAuth {
+		return true
+	}
+	planType := acc.OpenAIPlanType()
+	for _, plan := range plans {
+		if strings.EqualFold(planType, plan) {
+			return true
+		}
+	}
+	return false
+}
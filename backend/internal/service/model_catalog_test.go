@@ -0,0 +1,81 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/ShaohongDong/sub2api/internal/pkg/claude"
+	"github.com/ShaohongDong/sub2api/internal/pkg/openai"
+	gocache "github.com/patrickmn/go-cache"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAggregateAccountModelIDs_SkipsUnhealthyAccounts(t *testing.T) {
+	now := time.Now()
+	future := now.Add(time.Minute)
+	past := now.Add(-time.Minute)
+	accounts := []Account{
+		{ID: 1, Platform: PlatformAnthropic, Credentials: map[string]any{"model_mapping": map[string]any{"sonnet": "claude-sonnet-4-5"}}},
+		{ID: 2, Platform: PlatformAnthropic, RateLimitResetAt: &future, Credentials: map[string]any{"model_mapping": map[string]any{"limited": "x"}}},
+		{ID: 3, Platform: PlatformAnthropic, OverloadUntil: &future, Credentials: map[string]any{"model_mapping": map[string]any{"overloaded": "x"}}},
+		{ID: 4, Platform: PlatformAnthropic, TempUnschedulableUntil: &future, Credentials: map[string]any{"model_mapping": map[string]any{"paused": "x"}}},
+		{ID: 5, Platform: PlatformAnthropic, RateLimitResetAt: &past, Credentials: map[string]any{"model_mapping": map[string]any{"recovered": "x"}}},
+	}
+
+	require.Equal(t, []string{"recovered", "sonnet"}, aggregateAccountModelIDs(accounts, now))
+}
+
+func TestAccountModelIDs_DefaultsAndWildcards(t *testing.T) {
+	unmapped := &Account{Platform: PlatformAnthropic}
+	require.ElementsMatch(t, claude.DefaultModelIDs(), accountModelIDs(unmapped))
+
+	wildcard := &Account{
+		Platform: PlatformOpenAI,
+		Type:     AccountTypeAPIKey,
+		Credentials: map[string]any{"model_mapping": map[string]any{
+			"gpt-5.1-codex*": "gpt-5.1-codex",
+			"my-alias":       "gpt-5.4",
+		}},
+	}
+	require.ElementsMatch(t, []string{"gpt-5.1-codex", "gpt-5.1-codex-max", "gpt-5.1-codex-mini", "my-alias"}, accountModelIDs(wildcard))
+}
+
+func TestDefaultModelIDsForAccount_FiltersByOpenAIPlan(t *testing.T) {
+	plus := &Account{Platform: PlatformOpenAI, Type: AccountTypeOAuth, Credentials: map[string]any{"plan_type": "plus"}}
+	pro := &Account{Platform: PlatformOpenAI, Type: AccountTypeOAuth, Credentials: map[string]any{"plan_type": "pro"}}
+	apiKey := &Account{Platform: PlatformOpenAI, Type: AccountTypeAPIKey}
+
+	require.NotContains(t, defaultModelIDsForAccount(plus), "gpt-5.3-codex-spark")
+	require.Contains(t, defaultModelIDsForAccount(pro), "gpt-5.3-codex-spark")
+	require.Equal(t, openai.DefaultModelIDs(), defaultModelIDsForAccount(apiKey))
+}
+
+func TestGetAvailableModels_EmptyWhenNoHealthyAccount(t *testing.T) {
+	resetGatewayHotpathStatsForTest()
+
+	groupID := int64(11)
+	future := time.Now().Add(time.Hour)
+	repo := &modelsListAccountRepoStub{
+		byGroup: map[int64][]Account{
+			groupID: {{ID: 1, Platform: PlatformAnthropic, RateLimitResetAt: &future}},
+		},
+	}
+	svc := &GatewayService{
+		accountRepo:        repo,
+		modelsListCache:    gocache.New(time.Minute, time.Minute),
+		modelsListCacheTTL: time.Minute,
+	}
+
+	models := svc.GetAvailableModels(context.Background(), &groupID, "")
+	require.NotNil(t, models)
+	require.Empty(t, models)
+
+	// 缓存命中时同样区分“无健康账号”与“账号池为空”
+	models = svc.GetAvailableModels(context.Background(), &groupID, "")
+	require.NotNil(t, models)
+	require.Empty(t, models)
+
+	emptyGroupID := int64(12)
+	require.Nil(t, svc.GetAvailableModels(context.Background(), &emptyGroupID, ""))
+}
@@ -15,6 +15,10 @@ type OpenAIOAuthClient interface {
 	ExchangeCode(ctx context.Context, code, codeVerifier, redirectURI, proxyURL, clientID string) (*openai.TokenResponse, error)
 	RefreshToken(ctx context.Context, refreshToken, proxyURL string) (*openai.TokenResponse, error)
 	RefreshTokenWithClientID(ctx context.Context, refreshToken, proxyURL string, clientID string) (*openai.TokenResponse, error)
+	// RequestDeviceCode starts a device code login and returns the user code
+	RequestDeviceCode(ctx context.Context, proxyURL, clientID string) (*openai.DeviceCodeResponse, error)
+	// PollDeviceToken checks a device code login; it returns (nil, nil) while the user has not approved it yet
+	PollDeviceToken(ctx context.Context, deviceAuthID, userCode, proxyURL string) (*openai.DeviceTokenResponse, error)
 }
 
 // ClaudeOAuthClient handles HTTP requests for Claude OAuth flows
@@ -0,0 +1,119 @@
+package service
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"sync/atomic"
+
+	"github.com/ShaohongDong/sub2api/internal/pkg/ctxkey"
+)
+
+// 分组级账号调度策略（仅 openai 平台使用），作用于粘性会话未命中后的负载均衡层。
+// 空值沿用综合评分（优先级/负载/排队/错误率/首字延迟）加权选择。
+const (
+	SchedulingStrategyScore            = ""
+	SchedulingStrategyRoundRobin       = "round_robin"
+	SchedulingStrategyWeighted         = "weighted"
+	SchedulingStrategyLeastInFlight    = "least_in_flight"
+	SchedulingStrategyLeastRecentError = "least_recent_error"
+)
+
+// IsValidSchedulingStrategy 判断是否为支持的调度策略
+func IsValidSchedulingStrategy(strategy string) bool {
+	switch strategy {
+	case SchedulingStrategyScore, SchedulingStrategyRoundRobin, SchedulingStrategyWeighted,
+		SchedulingStrategyLeastInFlight, SchedulingStrategyLeastRecentError:
+		return true
+	default:
+		return false
+	}
+}
+
+// openAIGroupSchedulingStrategy 读取认证阶段写入 context 的分组调度策略
+func openAIGroupSchedulingStrategy(ctx context.Context, groupID *int64) string {
+	if ctx == nil || groupID == nil {
+		return SchedulingStrategyScore
+	}
+	if group, ok := ctx.Value(ctxkey.Group).(*Group); ok && IsGroupContextValid(group) && group.ID == *groupID {
+		return group.SchedulingStrategy
+	}
+	return SchedulingStrategyScore
+}
+
+// openAIAccountPool 按分组调度策略排列负载均衡候选账号。
+//
+// 候选先按账号优先级分层（数值越小越优先），层内按策略排序；
+// 调用方按返回顺序依次尝试获取并发槽位。
+type openAIAccountPool struct {
+	// rrCursors 轮询游标，key 为分组 ID（未分组为 0）
+	rrCursors sync.Map
+}
+
+func (p *openAIAccountPool) order(strategy string, req OpenAIAccountScheduleRequest, candidates []openAIAccountCandidateScore) []openAIAccountCandidateScore {
+	pool := append([]openAIAccountCandidateScore(nil), candidates...)
+	sort.SliceStable(pool, func(i, j int) bool {
+		if pool[i].account.Priority != pool[j].account.Priority {
+			return pool[i].account.Priority < pool[j].account.Priority
+		}
+		return pool[i].account.ID < pool[j].account.ID
+	})
+
+	var cursor uint64
+	if strategy == SchedulingStrategyRoundRobin {
+		cursor = p.nextCursor(req.GroupID)
+	}
+	for start := 0; start < len(pool); {
+		end := start + 1
+		for end < len(pool) && pool[end].account.Priority == pool[start].account.Priority {
+			end++
+		}
+		p.orderTier(strategy, req, pool[start:end], cursor)
+		start = end
+	}
+	return pool
+}
+
+// orderTier 对同一优先级的候选原地排序
+func (p *openAIAccountPool) orderTier(strategy string, req OpenAIAccountScheduleRequest, tier []openAIAccountCandidateScore, cursor uint64) {
+	if len(tier) <= 1 {
+		return
+	}
+	switch strategy {
+	case SchedulingStrategyRoundRobin:
+		shift := int(cursor % uint64(len(tier)))
+		rotated := append(append([]openAIAccountCandidateScore(nil), tier[shift:]...), tier[:shift]...)
+		copy(tier, rotated)
+	case SchedulingStrategyWeighted:
+		// 权重取账号负载系数（未设置时为并发上限），按权重随机抽取顺序
+		weights := make([]float64, len(tier))
+		for i := range tier {
+			weights[i] = float64(tier[i].account.EffectiveLoadFactor())
+		}
+		copy(tier, drawOpenAIWeightedOrder(tier, weights, req))
+	case SchedulingStrategyLeastInFlight:
+		sort.SliceStable(tier, func(i, j int) bool {
+			if tier[i].loadInfo.CurrentConcurrency != tier[j].loadInfo.CurrentConcurrency {
+				return tier[i].loadInfo.CurrentConcurrency < tier[j].loadInfo.CurrentConcurrency
+			}
+			return tier[i].loadInfo.WaitingCount < tier[j].loadInfo.WaitingCount
+		})
+	case SchedulingStrategyLeastRecentError:
+		// 从未出错或最久之前出错的账号优先，同等条件下负载低者优先
+		sort.SliceStable(tier, func(i, j int) bool {
+			if tier[i].lastErrorAt != tier[j].lastErrorAt {
+				return tier[i].lastErrorAt < tier[j].lastErrorAt
+			}
+			return tier[i].loadInfo.LoadRate < tier[j].loadInfo.LoadRate
+		})
+	}
+}
+
+func (p *openAIAccountPool) nextCursor(groupID *int64) uint64 {
+	var key int64
+	if groupID != nil {
+		key = *groupID
+	}
+	value, _ := p.rrCursors.LoadOrStore(key, &atomic.Uint64{})
+	return value.(*atomic.Uint64).Add(1) - 1
+}
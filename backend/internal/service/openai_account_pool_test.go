@@ -0,0 +1,111 @@
+package service
+
+import (
+	"context"
+	"testing"
+
+	"github.com/ShaohongDong/sub2api/internal/pkg/ctxkey"
+	"github.com/stretchr/testify/require"
+)
+
+func poolCandidate(id int64, priority int, concurrency int, lastErrorAt int64) openAIAccountCandidateScore {
+	return openAIAccountCandidateScore{
+		account:     &Account{ID: id, Priority: priority, Concurrency: 10},
+		loadInfo:    &AccountLoadInfo{AccountID: id, CurrentConcurrency: concurrency, LoadRate: concurrency * 10},
+		lastErrorAt: lastErrorAt,
+	}
+}
+
+func poolOrderIDs(order []openAIAccountCandidateScore) []int64 {
+	ids := make([]int64, 0, len(order))
+	for _, item := range order {
+		ids = append(ids, item.account.ID)
+	}
+	return ids
+}
+
+func TestIsValidSchedulingStrategy(t *testing.T) {
+	for _, strategy := range []string{"", "round_robin", "weighted", "least_in_flight", "least_recent_error"} {
+		require.True(t, IsValidSchedulingStrategy(strategy), strategy)
+	}
+	require.False(t, IsValidSchedulingStrategy("random"))
+}
+
+func TestOpenAIAccountPool_RoundRobinRotatesWithinPriority(t *testing.T) {
+	var pool openAIAccountPool
+	groupID := int64(7)
+	req := OpenAIAccountScheduleRequest{GroupID: &groupID}
+	candidates := []openAIAccountCandidateScore{
+		poolCandidate(3, 1, 0, 0),
+		poolCandidate(1, 1, 0, 0),
+		poolCandidate(2, 1, 0, 0),
+		poolCandidate(9, 0, 5, 0),
+	}
+
+	require.Equal(t, []int64{9, 1, 2, 3}, poolOrderIDs(pool.order(SchedulingStrategyRoundRobin, req, candidates)))
+	require.Equal(t, []int64{9, 2, 3, 1}, poolOrderIDs(pool.order(SchedulingStrategyRoundRobin, req, candidates)))
+	require.Equal(t, []int64{9, 3, 1, 2}, poolOrderIDs(pool.order(SchedulingStrategyRoundRobin, req, candidates)))
+
+	// 不同分组的游标互不影响
+	otherGroupID := int64(8)
+	require.Equal(t, []int64{9, 1, 2, 3}, poolOrderIDs(pool.order(SchedulingStrategyRoundRobin, OpenAIAccountScheduleRequest{GroupID: &otherGroupID}, candidates)))
+}
+
+func TestOpenAIAccountPool_LeastInFlight(t *testing.T) {
+	var pool openAIAccountPool
+	candidates := []openAIAccountCandidateScore{
+		poolCandidate(1, 0, 4, 0),
+		poolCandidate(2, 0, 1, 0),
+		poolCandidate(3, 0, 2, 0),
+		poolCandidate(4, 1, 0, 0),
+	}
+	require.Equal(t, []int64{2, 3, 1, 4}, poolOrderIDs(pool.order(SchedulingStrategyLeastInFlight, OpenAIAccountScheduleRequest{}, candidates)))
+}
+
+func TestOpenAIAccountPool_LeastRecentError(t *testing.T) {
+	var pool openAIAccountPool
+	candidates := []openAIAccountCandidateScore{
+		poolCandidate(1, 0, 0, 300),
+		poolCandidate(2, 0, 3, 0),
+		poolCandidate(3, 0, 0, 100),
+		poolCandidate(4, 0, 1, 0),
+	}
+	require.Equal(t, []int64{4, 2, 3, 1}, poolOrderIDs(pool.order(SchedulingStrategyLeastRecentError, OpenAIAccountScheduleRequest{}, candidates)))
+}
+
+func TestOpenAIAccountPool_WeightedFavorsLoadFactor(t *testing.T) {
+	var pool openAIAccountPool
+	heavy := 99
+	light := 1
+	candidates := []openAIAccountCandidateScore{
+		{account: &Account{ID: 1, LoadFactor: &light}, loadInfo: &AccountLoadInfo{AccountID: 1}},
+		{account: &Account{ID: 2, LoadFactor: &heavy}, loadInfo: &AccountLoadInfo{AccountID: 2}},
+	}
+	first := map[int64]int{}
+	for i := 0; i < 200; i++ {
+		order := pool.order(SchedulingStrategyWeighted, OpenAIAccountScheduleRequest{}, candidates)
+		require.Len(t, order, 2)
+		first[order[0].account.ID]++
+	}
+	require.Greater(t, first[2], first[1])
+}
+
+func TestOpenAIAccountRuntimeStats_LastErrorAt(t *testing.T) {
+	stats := newOpenAIAccountRuntimeStats()
+	stats.report(1, true, nil)
+	require.Zero(t, stats.lastErrorAt(1))
+	stats.report(1, false, nil)
+	require.NotZero(t, stats.lastErrorAt(1))
+	require.Zero(t, stats.lastErrorAt(2))
+}
+
+func TestOpenAIGroupSchedulingStrategy(t *testing.T) {
+	groupID := int64(5)
+	group := &Group{ID: groupID, Platform: PlatformOpenAI, Status: StatusActive, Hydrated: true, SchedulingStrategy: SchedulingStrategyLeastInFlight}
+	ctx := context.WithValue(context.Background(), ctxkey.Group, group)
+	require.Equal(t, SchedulingStrategyLeastInFlight, openAIGroupSchedulingStrategy(ctx, &groupID))
+
+	otherID := int64(6)
+	require.Equal(t, SchedulingStrategyScore, openAIGroupSchedulingStrategy(ctx, &otherID))
+	require.Equal(t, SchedulingStrategyScore, openAIGroupSchedulingStrategy(ctx, nil))
+}
@@ -28,6 +28,10 @@ type OpenAIAccountScheduleRequest struct {
 	RequestedModel     string
 	RequiredTransport  OpenAIUpstreamTransport
 	ExcludedIDs        map[int64]struct{}
+	// ClientRouting 客户端类型路由约束（nil 表示不限制账号类型）
+	ClientRouting *clientRoutingConstraint
+	// SchedulingStrategy 分组调度策略（空值为综合评分）
+	SchedulingStrategy string
 }
 
 type OpenAIAccountScheduleDecision struct {
@@ -101,11 +105,15 @@ func (m *openAIAccountSchedulerMetrics) recordSwitch() {
 type openAIAccountRuntimeStats struct {
 	accounts     sync.Map
 	accountCount atomic.Int64
+	// headroom accountID -> *openAIRateLimitHeadroom，上游限额响应头快照
+	headroom sync.Map
 }
 
 type openAIAccountRuntimeStat struct {
 	errorRateEWMABits atomic.Uint64
 	ttftEWMABits      atomic.Uint64
+	// lastErrorAt 最近一次失败的时间（UnixNano，0 表示未失败过）
+	lastErrorAt atomic.Int64
 }
 
 func newOpenAIAccountRuntimeStats() *openAIAccountRuntimeStats {
@@ -155,6 +163,8 @@ func (s *openAIAccountRuntimeStats) report(accountID int64, success bool, firstT
 	errorSample := 1.0
 	if success {
 		errorSample = 0.0
+	} else {
+		stat.lastErrorAt.Store(time.Now().UnixNano())
 	}
 	updateEWMAAtomic(&stat.errorRateEWMABits, errorSample, alpha)
 
@@ -198,6 +208,22 @@ func (s *openAIAccountRuntimeStats) snapshot(accountID int64) (errorRate float64
 	return errorRate, ttftValue, true
 }
 
+// lastErrorAt 返回账号最近一次失败的时间（UnixNano），未失败过时为 0
+func (s *openAIAccountRuntimeStats) lastErrorAt(accountID int64) int64 {
+	if s == nil || accountID <= 0 {
+		return 0
+	}
+	value, ok := s.accounts.Load(accountID)
+	if !ok {
+		return 0
+	}
+	stat, _ := value.(*openAIAccountRuntimeStat)
+	if stat == nil {
+		return 0
+	}
+	return stat.lastErrorAt.Load()
+}
+
 func (s *openAIAccountRuntimeStats) size() int {
 	if s == nil {
 		return 0
@@ -209,6 +235,7 @@ type defaultOpenAIAccountScheduler struct {
 	service *OpenAIGatewayService
 	metrics openAIAccountSchedulerMetrics
 	stats   *openAIAccountRuntimeStats
+	pool    openAIAccountPool
 }
 
 func newDefaultOpenAIAccountScheduler(service *OpenAIGatewayService, stats *openAIAccountRuntimeStats) OpenAIAccountScheduler {
@@ -247,6 +274,11 @@ func (s *defaultOpenAIAccountScheduler) Select(
 		if selection != nil && selection.Account != nil {
 			if !s.isAccountTransportCompatible(selection.Account, req.RequiredTransport) {
 				selection = nil
+			} else if !req.ClientRouting.allows(selection.Account) {
+				if selection.ReleaseFunc != nil {
+					selection.ReleaseFunc()
+				}
+				selection = nil
 			}
 		}
 		if selection != nil && selection.Account != nil {
@@ -323,7 +355,10 @@ func (s *defaultOpenAIAccountScheduler) selectBySessionHash(
 		_ = s.service.deleteStickySessionAccountID(ctx, req.GroupID, sessionHash)
 		return nil, nil
 	}
-	if req.RequestedModel != "" && !account.IsModelSupported(req.RequestedModel) {
+	if req.RequestedModel != "" && (!account.IsModelSupported(req.RequestedModel) || !s.service.isAccountPlanCompatible(ctx, account, req.RequestedModel)) {
+		return nil, nil
+	}
+	if !req.ClientRouting.allows(account) {
 		return nil, nil
 	}
 	if !s.isAccountTransportCompatible(account, req.RequiredTransport) {
@@ -358,12 +393,14 @@ func (s *defaultOpenAIAccountScheduler) selectBySessionHash(
 }
 
 type openAIAccountCandidateScore struct {
-	account   *Account
-	loadInfo  *AccountLoadInfo
-	score     float64
-	errorRate float64
-	ttft      float64
-	hasTTFT   bool
+	account     *Account
+	loadInfo    *AccountLoadInfo
+	score       float64
+	errorRate   float64
+	ttft        float64
+	hasTTFT     bool
+	lastErrorAt int64
+	headroom    float64
 }
 
 type openAIAccountCandidateHeap []openAIAccountCandidateScore
@@ -511,17 +548,29 @@ func buildOpenAIWeightedSelectionOrder(
 		return append([]openAIAccountCandidateScore(nil), candidates...)
 	}
 
-	pool := append([]openAIAccountCandidateScore(nil), candidates...)
-	weights := make([]float64, len(pool))
-	minScore := pool[0].score
-	for i := 1; i < len(pool); i++ {
-		if pool[i].score < minScore {
-			minScore = pool[i].score
+	weights := make([]float64, len(candidates))
+	minScore := candidates[0].score
+	for i := 1; i < len(candidates); i++ {
+		if candidates[i].score < minScore {
+			minScore = candidates[i].score
 		}
 	}
-	for i := range pool {
+	for i := range candidates {
 		// 将 top-K 分值平移到正区间，避免“单一最高分账号”长期垄断。
-		weight := (pool[i].score - minScore) + 1.0
+		weights[i] = (candidates[i].score - minScore) + 1.0
+	}
+	return drawOpenAIWeightedOrder(candidates, weights, req)
+}
+
+// drawOpenAIWeightedOrder 按权重无放回随机抽取，得到完整的尝试顺序（非法权重按 1 处理）。
+func drawOpenAIWeightedOrder(
+	candidates []openAIAccountCandidateScore,
+	candidateWeights []float64,
+	req OpenAIAccountScheduleRequest,
+) []openAIAccountCandidateScore {
+	pool := append([]openAIAccountCandidateScore(nil), candidates...)
+	weights := make([]float64, len(candidateWeights))
+	for i, weight := range candidateWeights {
 		if math.IsNaN(weight) || math.IsInf(weight, 0) || weight <= 0 {
 			weight = 1.0
 		}
@@ -571,7 +620,8 @@ func (s *defaultOpenAIAccountScheduler) selectByLoadBalance(
 	}
 
 	filtered := make([]*Account, 0, len(accounts))
-	loadReq := make([]AccountWithConcurrency, 0, len(accounts))
+	// routingRejected 仅因客户端路由约束被排除的账号；非严格模式下无匹配账号时回退使用。
+	var routingRejected []*Account
 	for i := range accounts {
 		account := &accounts[i]
 		if req.ExcludedIDs != nil {
@@ -582,21 +632,47 @@ func (s *defaultOpenAIAccountScheduler) selectByLoadBalance(
 		if !account.IsSchedulable() || !account.IsOpenAI() {
 			continue
 		}
-		if req.RequestedModel != "" && !account.IsModelSupported(req.RequestedModel) {
+		if req.RequestedModel != "" && (!account.IsModelSupported(req.RequestedModel) || !s.service.isAccountPlanCompatible(ctx, account, req.RequestedModel)) {
 			continue
 		}
 		if !s.isAccountTransportCompatible(account, req.RequiredTransport) {
 			continue
 		}
+		if !req.ClientRouting.allows(account) {
+			routingRejected = append(routingRejected, account)
+			continue
+		}
 		filtered = append(filtered, account)
+	}
+	if len(filtered) == 0 && len(routingRejected) > 0 && !req.ClientRouting.strict {
+		filtered = routingRejected
+	}
+	if len(filtered) == 0 {
+		return nil, 0, 0, 0, errors.New("no available OpenAI accounts")
+	}
+
+	// 上游限额已耗尽（remaining=0 且未到重置时间）或预测即将超出 Codex 用量窗口的账号暂不调度；
+	// 全部耗尽时仍按原集合尝试
+	now := time.Now()
+	headroomByID := make(map[int64]float64, len(filtered))
+	withHeadroom := filtered[:0:0]
+	for _, account := range filtered {
+		factor, exhausted := openAIAccountHeadroom(account, s.stats.loadHeadroom(account.ID), now)
+		headroomByID[account.ID] = factor
+		if !exhausted && !s.service.openAIUsageWindowWillExceed(account, now) {
+			withHeadroom = append(withHeadroom, account)
+		}
+	}
+	if len(withHeadroom) > 0 {
+		filtered = withHeadroom
+	}
+	loadReq := make([]AccountWithConcurrency, 0, len(filtered))
+	for _, account := range filtered {
 		loadReq = append(loadReq, AccountWithConcurrency{
 			ID:             account.ID,
 			MaxConcurrency: account.EffectiveLoadFactor(),
 		})
 	}
-	if len(filtered) == 0 {
-		return nil, 0, 0, 0, errors.New("no available OpenAI accounts")
-	}
 
 	loadMap := map[int64]*AccountLoadInfo{}
 	if s.service.concurrencyService != nil {
@@ -644,11 +720,13 @@ func (s *defaultOpenAIAccountScheduler) selectByLoadBalance(
 		loadRateSum += loadRate
 		loadRateSumSquares += loadRate * loadRate
 		candidates = append(candidates, openAIAccountCandidateScore{
-			account:   account,
-			loadInfo:  loadInfo,
-			errorRate: errorRate,
-			ttft:      ttft,
-			hasTTFT:   hasTTFT,
+			account:     account,
+			loadInfo:    loadInfo,
+			errorRate:   errorRate,
+			ttft:        ttft,
+			hasTTFT:     hasTTFT,
+			lastErrorAt: s.stats.lastErrorAt(account.ID),
+			headroom:    headroomByID[account.ID],
 		})
 	}
 	loadSkew := calcLoadSkewByMoments(loadRateSum, loadRateSumSquares, len(candidates))
@@ -672,18 +750,25 @@ func (s *defaultOpenAIAccountScheduler) selectByLoadBalance(
 			weights.Load*loadFactor +
 			weights.Queue*queueFactor +
 			weights.ErrorRate*errorFactor +
-			weights.TTFT*ttftFactor
-	}
-
-	topK := s.service.openAIWSLBTopK()
-	if topK > len(candidates) {
-		topK = len(candidates)
-	}
-	if topK <= 0 {
-		topK = 1
+			weights.TTFT*ttftFactor +
+			weights.Headroom*item.headroom
+	}
+
+	var selectionOrder []openAIAccountCandidateScore
+	topK := len(candidates)
+	if req.SchedulingStrategy != SchedulingStrategyScore {
+		selectionOrder = s.pool.order(req.SchedulingStrategy, req, candidates)
+	} else {
+		topK = s.service.openAIWSLBTopK()
+		if topK > len(candidates) {
+			topK = len(candidates)
+		}
+		if topK <= 0 {
+			topK = 1
+		}
+		rankedCandidates := selectTopKOpenAICandidates(candidates, topK)
+		selectionOrder = buildOpenAIWeightedSelectionOrder(rankedCandidates, req)
 	}
-	rankedCandidates := selectTopKOpenAICandidates(candidates, topK)
-	selectionOrder := buildOpenAIWeightedSelectionOrder(rankedCandidates, req)
 
 	for i := 0; i < len(selectionOrder); i++ {
 		candidate := selectionOrder[i]
@@ -806,6 +891,22 @@ func (s *OpenAIGatewayService) SelectAccountWithScheduler(
 	requestedModel string,
 	excludedIDs map[int64]struct{},
 	requiredTransport OpenAIUpstreamTransport,
+) (*AccountSelectionResult, OpenAIAccountScheduleDecision, error) {
+	ctx, span := startSchedulingSpan(ctx, groupID, requestedModel, len(excludedIDs))
+	selection, decision, err := s.selectAccountWithScheduler(ctx, groupID, previousResponseID, sessionHash, requestedModel, excludedIDs, requiredTransport)
+	annotateScheduleDecision(span, decision)
+	endSchedulingSpan(span, selection, err)
+	return selection, decision, err
+}
+
+func (s *OpenAIGatewayService) selectAccountWithScheduler(
+	ctx context.Context,
+	groupID *int64,
+	previousResponseID string,
+	sessionHash string,
+	requestedModel string,
+	excludedIDs map[int64]struct{},
+	requiredTransport OpenAIUpstreamTransport,
 ) (*AccountSelectionResult, OpenAIAccountScheduleDecision, error) {
 	decision := OpenAIAccountScheduleDecision{}
 	scheduler := s.getOpenAIAccountScheduler()
@@ -830,6 +931,8 @@ func (s *OpenAIGatewayService) SelectAccountWithScheduler(
 		RequestedModel:     requestedModel,
 		RequiredTransport:  requiredTransport,
 		ExcludedIDs:        excludedIDs,
+		ClientRouting:      s.resolveClientRoutingConstraint(ctx),
+		SchedulingStrategy: openAIGroupSchedulingStrategy(ctx, groupID),
 	})
 }
 
@@ -879,6 +982,7 @@ func (s *OpenAIGatewayService) openAIWSSchedulerWeights() GatewayOpenAIWSSchedul
 			Queue:     s.cfg.Gateway.OpenAIWS.SchedulerScoreWeights.Queue,
 			ErrorRate: s.cfg.Gateway.OpenAIWS.SchedulerScoreWeights.ErrorRate,
 			TTFT:      s.cfg.Gateway.OpenAIWS.SchedulerScoreWeights.TTFT,
+			Headroom:  s.cfg.Gateway.OpenAIWS.SchedulerScoreWeights.Headroom,
 		}
 	}
 	return GatewayOpenAIWSSchedulerScoreWeightsView{
@@ -887,6 +991,7 @@ func (s *OpenAIGatewayService) openAIWSSchedulerWeights() GatewayOpenAIWSSchedul
 		Queue:     0.7,
 		ErrorRate: 0.8,
 		TTFT:      0.5,
+		Headroom:  1.0,
 	}
 }
 
@@ -896,6 +1001,7 @@ type GatewayOpenAIWSSchedulerScoreWeightsView struct {
 	Queue     float64
 	ErrorRate float64
 	TTFT      float64
+	Headroom  float64
 }
 
 func clamp01(value float64) float64 {
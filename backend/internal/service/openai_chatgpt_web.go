@@ -0,0 +1,486 @@
+package service
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/ShaohongDong/sub2api/internal/pkg/apicompat"
+	"github.com/ShaohongDong/sub2api/internal/pkg/tokenizer"
+	"github.com/google/uuid"
+	"github.com/tidwall/gjson"
+)
+
+// ChatGPT 网页后端（conversation 接口）上游模式：供 Codex / Responses 接口不可用的 OAuth 账号使用。
+// 发往上游前把已构造好的 Responses 请求改写为 conversation 请求，并把 conversation 流转换回
+// Responses SSE，使下游的流式处理、非流式聚合、用量记录、故障转移与分组降级链保持不变。
+const (
+	// OpenAIUpstreamModeChatGPTWeb accounts.extra.openai_upstream_mode 取值：改走 ChatGPT 网页后端
+	OpenAIUpstreamModeChatGPTWeb = "chatgpt_web"
+
+	chatgptWebConversationURL = "https://chatgpt.com/backend-api/conversation"
+	chatgptWebRequirementsURL = "https://chatgpt.com/backend-api/sentinel/chat-requirements"
+	chatgptWebUserAgent       = "Mozilla/5.0 (Macintosh; Intel Mac OS X 10_15_7) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/131.0.0.0 Safari/537.36"
+	// chatgptWebDefaultModel 未配置 chatgpt_web_model 时使用的网页端模型（由上游自动选择）
+	chatgptWebDefaultModel = "auto"
+
+	chatgptWebTextPartPath = "/message/content/parts/0"
+	chatgptWebMaxLineBytes = 4 << 20
+)
+
+// errChatGPTWebProofOfWork 上游要求工作量证明 / 人机验证时返回，交由故障转移与分组降级链处理
+var errChatGPTWebProofOfWork = errors.New("chatgpt web backend requires proof-of-work or turnstile verification")
+
+// IsChatGPTWebUpstream 返回 OpenAI OAuth 账号是否改走 ChatGPT 网页后端（conversation 接口）。
+// 字段：accounts.extra.openai_upstream_mode = "chatgpt_web"。
+func (a *Account) IsChatGPTWebUpstream() bool {
+	return a != nil && a.IsOpenAIOAuth() && strings.EqualFold(strings.TrimSpace(a.GetExtraString("openai_upstream_mode")), OpenAIUpstreamModeChatGPTWeb)
+}
+
+// chatGPTWebModel 返回网页后端使用的模型：accounts.extra.chatgpt_web_model，未配置时为 auto
+func (a *Account) chatGPTWebModel() string {
+	if model := strings.TrimSpace(a.GetExtraString("chatgpt_web_model")); model != "" {
+		return model
+	}
+	return chatgptWebDefaultModel
+}
+
+// sendOpenAIResponsesUpstream 发送已构造好的 Responses 上游请求；ChatGPT 网页后端模式的账号改走 conversation 接口
+func (s *OpenAIGatewayService) sendOpenAIResponsesUpstream(req *http.Request, proxyURL string, account *Account) (*http.Response, error) {
+	if account.IsChatGPTWebUpstream() {
+		return s.doChatGPTWebUpstream(req, proxyURL, account)
+	}
+	return s.httpUpstream.Do(req, proxyURL, account.ID, account.Concurrency)
+}
+
+// doChatGPTWebUpstream 把 Responses 请求改写为 conversation 请求发送：
+// 上游错误响应原样返回（沿用原有的错误映射与故障转移），成功时返回转换后的 Responses SSE 响应。
+func (s *OpenAIGatewayService) doChatGPTWebUpstream(req *http.Request, proxyURL string, account *Account) (*http.Response, error) {
+	if isOpenAIResponsesCompactRequestPath(req.URL.Path) {
+		return nil, fmt.Errorf("responses compact is not supported in chatgpt web upstream mode")
+	}
+	body, err := readUpstreamRequestBody(req)
+	if err != nil {
+		return nil, err
+	}
+	ctx := req.Context()
+	authorization := req.Header.Get("authorization")
+	deviceID := generateSessionUUID(fmt.Sprintf("chatgpt-web-device:%d", account.ID))
+
+	requirementsToken, resp, err := s.fetchChatGPTWebRequirements(ctx, account, proxyURL, authorization, deviceID)
+	if err != nil || resp != nil {
+		return resp, err
+	}
+
+	convBody, prompt, err := buildChatGPTWebConversationBody(body, account.chatGPTWebModel())
+	if err != nil {
+		return nil, err
+	}
+	convReq, err := http.NewRequestWithContext(ctx, http.MethodPost, chatgptWebConversationURL, bytes.NewReader(convBody))
+	if err != nil {
+		return nil, err
+	}
+	setChatGPTWebHeaders(convReq, account, authorization, deviceID)
+	convReq.Header.Set("accept", "text/event-stream")
+	if requirementsToken != "" {
+		convReq.Header.Set("openai-sentinel-chat-requirements-token", requirementsToken)
+	}
+
+	upstream, err := s.httpUpstream.Do(convReq, proxyURL, account.ID, account.Concurrency)
+	if err != nil || upstream.StatusCode >= 400 {
+		return upstream, err
+	}
+
+	model := gjson.GetBytes(body, "model").String()
+	pr, pw := io.Pipe()
+	go func() {
+		defer func() { _ = upstream.Body.Close() }()
+		_ = pw.CloseWithError(translateChatGPTWebStream(upstream.Body, pw, model, tokenizer.Count(model, prompt)))
+	}()
+
+	header := make(http.Header)
+	header.Set("Content-Type", "text/event-stream")
+	if requestID := upstream.Header.Get("x-request-id"); requestID != "" {
+		header.Set("x-request-id", requestID)
+	}
+	return &http.Response{
+		Status:     "200 OK",
+		StatusCode: http.StatusOK,
+		Proto:      upstream.Proto,
+		ProtoMajor: upstream.ProtoMajor,
+		ProtoMinor: upstream.ProtoMinor,
+		Header:     header,
+		Body:       pr,
+		Request:    req,
+	}, nil
+}
+
+// fetchChatGPTWebRequirements 获取会话请求所需的 sentinel token；上游返回错误时原样返回该响应。
+// 暂不支持工作量证明与 Turnstile 人机验证，遇到时返回 errChatGPTWebProofOfWork。
+func (s *OpenAIGatewayService) fetchChatGPTWebRequirements(ctx context.Context, account *Account, proxyURL, authorization, deviceID string) (string, *http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, chatgptWebRequirementsURL, strings.NewReader(`{}`))
+	if err != nil {
+		return "", nil, err
+	}
+	setChatGPTWebHeaders(req, account, authorization, deviceID)
+	req.Header.Set("accept", "application/json")
+
+	resp, err := s.httpUpstream.Do(req, proxyURL, account.ID, account.Concurrency)
+	if err != nil {
+		return "", nil, err
+	}
+	if resp.StatusCode >= 400 {
+		return "", resp, nil
+	}
+	defer func() { _ = resp.Body.Close() }()
+	payload, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		return "", nil, fmt.Errorf("read chatgpt web requirements: %w", err)
+	}
+	if gjson.GetBytes(payload, "proofofwork.required").Bool() || gjson.GetBytes(payload, "turnstile.required").Bool() {
+		return "", nil, errChatGPTWebProofOfWork
+	}
+	return gjson.GetBytes(payload, "token").String(), nil, nil
+}
+
+func setChatGPTWebHeaders(req *http.Request, account *Account, authorization, deviceID string) {
+	req.Host = "chatgpt.com"
+	req.Header.Set("authorization", authorization)
+	req.Header.Set("content-type", "application/json")
+	req.Header.Set("user-agent", chatgptWebUserAgent)
+	req.Header.Set("origin", "https://chatgpt.com")
+	req.Header.Set("referer", "https://chatgpt.com/")
+	req.Header.Set("oai-device-id", deviceID)
+	req.Header.Set("oai-language", "en-US")
+	if chatgptAccountID := account.GetChatGPTAccountID(); chatgptAccountID != "" {
+		req.Header.Set("chatgpt-account-id", chatgptAccountID)
+	}
+}
+
+func isOpenAIResponsesCompactRequestPath(path string) bool {
+	return strings.HasSuffix(strings.TrimRight(path, "/"), "/compact")
+}
+
+func readUpstreamRequestBody(req *http.Request) ([]byte, error) {
+	if req.GetBody != nil {
+		rc, err := req.GetBody()
+		if err != nil {
+			return nil, err
+		}
+		defer func() { _ = rc.Close() }()
+		return io.ReadAll(rc)
+	}
+	if req.Body == nil {
+		return nil, nil
+	}
+	defer func() { _ = req.Body.Close() }()
+	return io.ReadAll(req.Body)
+}
+
+// chatGPTWebMessage conversation 接口的一条消息
+type chatGPTWebMessage struct {
+	ID      string                   `json:"id"`
+	Author  chatGPTWebMessageAuthor  `json:"author"`
+	Content chatGPTWebMessageContent `json:"content"`
+}
+
+type chatGPTWebMessageAuthor struct {
+	Role string `json:"role"`
+}
+
+type chatGPTWebMessageContent struct {
+	ContentType string   `json:"content_type"`
+	Parts       []string `json:"parts"`
+}
+
+// chatGPTWebConversationRequest conversation 接口请求体（不保存历史：每次携带完整上下文）
+type chatGPTWebConversationRequest struct {
+	Action                     string                     `json:"action"`
+	Messages                   []chatGPTWebMessage        `json:"messages"`
+	ParentMessageID            string                     `json:"parent_message_id"`
+	Model                      string                     `json:"model"`
+	HistoryAndTrainingDisabled bool                       `json:"history_and_training_disabled"`
+	TimezoneOffsetMin          int                        `json:"timezone_offset_min"`
+	ConversationMode           chatGPTWebConversationMode `json:"conversation_mode"`
+	SupportsBuffering          bool                       `json:"supports_buffering"`
+	SupportedEncodings         []string                   `json:"supported_encodings"`
+}
+
+type chatGPTWebConversationMode struct {
+	Kind string `json:"kind"`
+}
+
+// buildChatGPTWebConversationBody 把 Responses 请求体转换为 conversation 请求体。
+// 网页后端只支持纯文本对话：instructions 作为 system 消息，工具调用及其结果以文本形式保留在上下文中，
+// 图片、文件等非文本内容以占位文本代替，tools 定义被忽略。同时返回全部消息文本，用于估算输入用量。
+func buildChatGPTWebConversationBody(responsesBody []byte, model string) ([]byte, string, error) {
+	var req apicompat.ResponsesRequest
+	if err := json.Unmarshal(responsesBody, &req); err != nil {
+		return nil, "", fmt.Errorf("parse responses request: %w", err)
+	}
+	messages := make([]chatGPTWebMessage, 0, 4)
+	add := func(role, text string) {
+		if strings.TrimSpace(text) == "" {
+			return
+		}
+		// 相邻的同角色消息合并，减少网页端的消息数
+		if n := len(messages); n > 0 && messages[n-1].Author.Role == role {
+			messages[n-1].Content.Parts[0] += "\n\n" + text
+			return
+		}
+		messages = append(messages, chatGPTWebMessage{
+			ID:      uuid.NewString(),
+			Author:  chatGPTWebMessageAuthor{Role: role},
+			Content: chatGPTWebMessageContent{ContentType: "text", Parts: []string{text}},
+		})
+	}
+
+	add("system", req.Instructions)
+	input := bytes.TrimSpace(req.Input)
+	if len(input) > 0 && input[0] == '"' {
+		var text string
+		if err := json.Unmarshal(input, &text); err != nil {
+			return nil, "", fmt.Errorf("parse responses input: %w", err)
+		}
+		add("user", text)
+	} else if len(input) > 0 {
+		var items []apicompat.ResponsesInputItem
+		if err := json.Unmarshal(input, &items); err != nil {
+			return nil, "", fmt.Errorf("parse responses input: %w", err)
+		}
+		for _, item := range items {
+			switch item.Type {
+			case "", "message":
+				role := item.Role
+				if role == "developer" {
+					role = "system"
+				}
+				add(role, chatGPTWebContentText(item.Content))
+			case "function_call":
+				add("assistant", fmt.Sprintf("[Called tool %s with arguments: %s]", item.Name, item.Arguments))
+			case "function_call_output":
+				add("user", fmt.Sprintf("[Tool result for %s]\n%s", item.CallID, item.Output))
+			}
+		}
+	}
+	if len(messages) == 0 || messages[len(messages)-1].Author.Role == "assistant" {
+		return nil, "", fmt.Errorf("chatgpt web upstream requires a user message")
+	}
+
+	var prompt strings.Builder
+	for _, m := range messages {
+		prompt.WriteString(m.Content.Parts[0])
+		prompt.WriteByte('\n')
+	}
+	body, err := json.Marshal(chatGPTWebConversationRequest{
+		Action:                     "next",
+		Messages:                   messages,
+		ParentMessageID:            uuid.NewString(),
+		Model:                      model,
+		HistoryAndTrainingDisabled: true,
+		ConversationMode:           chatGPTWebConversationMode{Kind: "primary_assistant"},
+		SupportsBuffering:          true,
+		SupportedEncodings:         []string{"v1"},
+	})
+	return body, prompt.String(), err
+}
+
+// chatGPTWebContentText 提取消息内容中的文本（字符串或内容块数组）
+func chatGPTWebContentText(raw json.RawMessage) string {
+	raw = bytes.TrimSpace(raw)
+	if len(raw) == 0 {
+		return ""
+	}
+	if raw[0] == '"' {
+		var text string
+		_ = json.Unmarshal(raw, &text)
+		return text
+	}
+	var parts []apicompat.ResponsesContentPart
+	if err := json.Unmarshal(raw, &parts); err != nil {
+		return ""
+	}
+	texts := make([]string, 0, len(parts))
+	for _, part := range parts {
+		switch part.Type {
+		case "input_text", "output_text", "text":
+			texts = append(texts, part.Text)
+		case "input_image":
+			texts = append(texts, "[image omitted]")
+		case "input_file":
+			texts = append(texts, "[file omitted]")
+		}
+	}
+	return strings.Join(texts, "\n")
+}
+
+// chatGPTWebStreamDecoder 解析 conversation 流，提取助手文本增量。
+// 同时兼容 v1 增量编码（add / append / patch 操作）与旧版每次返回完整消息的格式。
+type chatGPTWebStreamDecoder struct {
+	assistant bool   // 当前消息是否为助手文本消息
+	current   string // 当前消息已输出的文本（旧版格式按前缀计算增量）
+	lastPath  string
+	lastOp    string
+	errMsg    string
+}
+
+// feed 处理一条 data 负载，返回新增的助手文本
+func (d *chatGPTWebStreamDecoder) feed(data []byte) string {
+	parsed := gjson.ParseBytes(data)
+	if !parsed.IsObject() {
+		return ""
+	}
+	if errMsg := parsed.Get("error"); errMsg.Exists() && errMsg.Type != gjson.Null {
+		if msg := errMsg.Get("message"); msg.Exists() {
+			d.errMsg = msg.String()
+		} else {
+			d.errMsg = errMsg.String()
+		}
+		return ""
+	}
+	if message := parsed.Get("message"); message.IsObject() {
+		return d.replaceMessage(message)
+	}
+	return d.applyOp(parsed)
+}
+
+// replaceMessage 处理完整消息（旧版格式或 v1 的 add 操作）
+func (d *chatGPTWebStreamDecoder) replaceMessage(message gjson.Result) string {
+	assistant := message.Get("author.role").String() == "assistant" && message.Get("content.content_type").String() == "text"
+	text := message.Get("content.parts.0").String()
+	if !assistant {
+		d.assistant = false
+		return ""
+	}
+	if !d.assistant || !strings.HasPrefix(text, d.current) {
+		d.assistant = true
+		d.current = text
+		return text
+	}
+	delta := text[len(d.current):]
+	d.current = text
+	return delta
+}
+
+// applyOp 处理 v1 增量编码的单个操作；省略 p / o 时沿用上一个操作的路径与类型
+func (d *chatGPTWebStreamDecoder) applyOp(op gjson.Result) string {
+	path, kind := d.lastPath, d.lastOp
+	if p := op.Get("p"); p.Exists() {
+		path = p.String()
+		kind = op.Get("o").String()
+	}
+	d.lastPath, d.lastOp = path, kind
+	value := op.Get("v")
+
+	switch {
+	case kind == "patch" && value.IsArray():
+		var delta strings.Builder
+		value.ForEach(func(_, sub gjson.Result) bool {
+			delta.WriteString(d.applyOp(sub))
+			return true
+		})
+		// patch 内的子操作不影响后续省略路径的操作
+		d.lastPath, d.lastOp = path, kind
+		return delta.String()
+	case kind == "add" && path == "" && value.Get("message").IsObject():
+		d.assistant = false
+		d.current = ""
+		return d.replaceMessage(value.Get("message"))
+	case kind == "append" && path == chatgptWebTextPartPath && d.assistant && value.Type == gjson.String:
+		d.current += value.String()
+		return value.String()
+	}
+	return ""
+}
+
+// translateChatGPTWebStream 把 conversation SSE 转换为 Responses SSE 写入 w
+func translateChatGPTWebStream(r io.Reader, w io.Writer, model string, inputTokens int) error {
+	responseID := "resp_" + strings.ReplaceAll(uuid.NewString(), "-", "")
+	itemID := "msg_" + strings.ReplaceAll(uuid.NewString(), "-", "")
+	seq := 0
+	emit := func(evt *apicompat.ResponsesStreamEvent) error {
+		seq++
+		evt.SequenceNumber = seq
+		frame, err := apicompat.AppendResponsesEventSSE(nil, evt)
+		if err != nil {
+			return err
+		}
+		_, err = w.Write(frame)
+		return err
+	}
+
+	if err := emit(&apicompat.ResponsesStreamEvent{
+		Type:     "response.created",
+		Response: &apicompat.ResponsesResponse{ID: responseID, Object: "response", Model: model, Status: "in_progress", Output: []apicompat.ResponsesOutput{}},
+	}); err != nil {
+		return err
+	}
+	if err := emit(&apicompat.ResponsesStreamEvent{
+		Type: "response.output_item.added",
+		Item: &apicompat.ResponsesOutput{Type: "message", ID: itemID, Role: "assistant", Status: "in_progress", Content: []apicompat.ResponsesContentPart{}},
+	}); err != nil {
+		return err
+	}
+
+	var decoder chatGPTWebStreamDecoder
+	var text strings.Builder
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), chatgptWebMaxLineBytes)
+	for scanner.Scan() {
+		line := scanner.Bytes()
+		if !bytes.HasPrefix(line, []byte("data:")) {
+			continue
+		}
+		data := bytes.TrimSpace(line[len("data:"):])
+		if bytes.Equal(data, []byte("[DONE]")) {
+			break
+		}
+		delta := decoder.feed(data)
+		if delta == "" {
+			continue
+		}
+		text.WriteString(delta)
+		if err := emit(&apicompat.ResponsesStreamEvent{Type: "response.output_text.delta", ItemID: itemID, Delta: delta}); err != nil {
+			return err
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return err
+	}
+
+	if text.Len() == 0 && decoder.errMsg != "" {
+		return emit(&apicompat.ResponsesStreamEvent{
+			Type: "response.failed",
+			Response: &apicompat.ResponsesResponse{
+				ID: responseID, Object: "response", Model: model, Status: "failed",
+				Output: []apicompat.ResponsesOutput{},
+				Error:  &apicompat.ResponsesError{Code: "server_error", Message: decoder.errMsg},
+			},
+		})
+	}
+
+	content := []apicompat.ResponsesContentPart{{Type: "output_text", Text: text.String()}}
+	item := apicompat.ResponsesOutput{Type: "message", ID: itemID, Role: "assistant", Status: "completed", Content: content}
+	if err := emit(&apicompat.ResponsesStreamEvent{Type: "response.output_text.done", ItemID: itemID, Text: text.String()}); err != nil {
+		return err
+	}
+	if err := emit(&apicompat.ResponsesStreamEvent{Type: "response.output_item.done", Item: &item}); err != nil {
+		return err
+	}
+	// 网页后端不返回用量，按文本估算
+	outputTokens := tokenizer.Count(model, text.String())
+	return emit(&apicompat.ResponsesStreamEvent{
+		Type: "response.completed",
+		Response: &apicompat.ResponsesResponse{
+			ID: responseID, Object: "response", Model: model, Status: "completed",
+			Output: []apicompat.ResponsesOutput{item},
+			Usage:  &apicompat.ResponsesUsage{InputTokens: inputTokens, OutputTokens: outputTokens, TotalTokens: inputTokens + outputTokens},
+		},
+	})
+}
@@ -0,0 +1,137 @@
+package service
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/tidwall/gjson"
+)
+
+func TestAccountIsChatGPTWebUpstream(t *testing.T) {
+	account := &Account{Platform: PlatformOpenAI, Type: AccountTypeOAuth, Extra: map[string]any{"openai_upstream_mode": "chatgpt_web"}}
+	require.True(t, account.IsChatGPTWebUpstream())
+	require.Equal(t, "auto", account.chatGPTWebModel())
+	require.False(t, account.IsOpenAIResponsesWebSocketV2Enabled())
+
+	account.Extra["chatgpt_web_model"] = "gpt-4o"
+	require.Equal(t, "gpt-4o", account.chatGPTWebModel())
+
+	apiKey := &Account{Platform: PlatformOpenAI, Type: AccountTypeAPIKey, Extra: map[string]any{"openai_upstream_mode": "chatgpt_web"}}
+	require.False(t, apiKey.IsChatGPTWebUpstream())
+	require.False(t, (&Account{Platform: PlatformOpenAI, Type: AccountTypeOAuth}).IsChatGPTWebUpstream())
+}
+
+func TestBuildChatGPTWebConversationBody(t *testing.T) {
+	body, prompt, err := buildChatGPTWebConversationBody([]byte(`{
+		"model":"gpt-5",
+		"instructions":"be brief",
+		"input":[
+			{"role":"developer","content":"answer in English"},
+			{"role":"user","content":[{"type":"input_text","text":"what is this?"},{"type":"input_image","image_url":"data:image/png;base64,AA=="}]},
+			{"type":"function_call","name":"lookup","call_id":"call_1","arguments":"{\"q\":1}"},
+			{"type":"function_call_output","call_id":"call_1","output":"42"}
+		],
+		"tools":[{"type":"function","name":"lookup"}]
+	}`), "auto")
+	require.NoError(t, err)
+
+	parsed := gjson.ParseBytes(body)
+	require.Equal(t, "next", parsed.Get("action").String())
+	require.Equal(t, "auto", parsed.Get("model").String())
+	require.True(t, parsed.Get("history_and_training_disabled").Bool())
+
+	messages := parsed.Get("messages").Array()
+	require.Len(t, messages, 4)
+	require.Equal(t, "system", messages[0].Get("author.role").String())
+	require.Equal(t, "be brief\n\nanswer in English", messages[0].Get("content.parts.0").String())
+	require.Equal(t, "what is this?\n[image omitted]", messages[1].Get("content.parts.0").String())
+	require.Equal(t, "assistant", messages[2].Get("author.role").String())
+	require.Contains(t, messages[2].Get("content.parts.0").String(), "lookup")
+	require.Equal(t, "user", messages[3].Get("author.role").String())
+	require.Contains(t, messages[3].Get("content.parts.0").String(), "42")
+	require.Contains(t, prompt, "what is this?")
+
+	_, _, err = buildChatGPTWebConversationBody([]byte(`{"model":"gpt-5","input":"hello"}`), "auto")
+	require.NoError(t, err)
+
+	_, _, err = buildChatGPTWebConversationBody([]byte(`{"model":"gpt-5","input":[{"role":"assistant","content":"hi"}]}`), "auto")
+	require.Error(t, err)
+}
+
+func TestChatGPTWebStreamDecoder(t *testing.T) {
+	t.Run("v1 encoding", func(t *testing.T) {
+		var d chatGPTWebStreamDecoder
+		require.Empty(t, d.feed([]byte(`{"p":"","o":"add","v":{"message":{"author":{"role":"user"},"content":{"content_type":"text","parts":["hi"]}}}}`)))
+		require.Equal(t, "Hel", d.feed([]byte(`{"p":"","o":"add","v":{"message":{"author":{"role":"assistant"},"content":{"content_type":"text","parts":["Hel"]}}}}`)))
+		require.Equal(t, "lo", d.feed([]byte(`{"p":"/message/content/parts/0","o":"append","v":"lo"}`)))
+		require.Equal(t, " wor", d.feed([]byte(`{"v":" wor"}`)))
+		require.Equal(t, "ld", d.feed([]byte(`{"p":"","o":"patch","v":[{"p":"/message/content/parts/0","o":"append","v":"ld"},{"p":"/message/status","o":"replace","v":"finished_successfully"}]}`)))
+		require.Empty(t, d.feed([]byte(`{"type":"message_stream_complete"}`)))
+		require.Equal(t, "Hello world", d.current)
+	})
+
+	t.Run("legacy full messages", func(t *testing.T) {
+		var d chatGPTWebStreamDecoder
+		require.Equal(t, "Hi", d.feed([]byte(`{"message":{"author":{"role":"assistant"},"content":{"content_type":"text","parts":["Hi"]}}}`)))
+		require.Equal(t, " there", d.feed([]byte(`{"message":{"author":{"role":"assistant"},"content":{"content_type":"text","parts":["Hi there"]}}}`)))
+	})
+
+	t.Run("error", func(t *testing.T) {
+		var d chatGPTWebStreamDecoder
+		require.Empty(t, d.feed([]byte(`{"error":"Something went wrong"}`)))
+		require.Equal(t, "Something went wrong", d.errMsg)
+	})
+}
+
+func TestTranslateChatGPTWebStream(t *testing.T) {
+	upstream := strings.Join([]string{
+		`event: delta_encoding`,
+		`data: "v1"`,
+		``,
+		`data: {"p":"","o":"add","v":{"message":{"author":{"role":"assistant"},"content":{"content_type":"text","parts":[""]}}}}`,
+		``,
+		`data: {"p":"/message/content/parts/0","o":"append","v":"Hello"}`,
+		``,
+		`data: {"v":" world"}`,
+		``,
+		`data: [DONE]`,
+		``,
+	}, "\n")
+
+	var out bytes.Buffer
+	require.NoError(t, translateChatGPTWebStream(strings.NewReader(upstream), &out, "gpt-5", 7))
+
+	var events []gjson.Result
+	for _, line := range strings.Split(out.String(), "\n") {
+		if data, ok := strings.CutPrefix(line, "data: "); ok {
+			events = append(events, gjson.Parse(data))
+		}
+	}
+	types := make([]string, 0, len(events))
+	for _, evt := range events {
+		types = append(types, evt.Get("type").String())
+	}
+	require.Equal(t, []string{
+		"response.created",
+		"response.output_item.added",
+		"response.output_text.delta",
+		"response.output_text.delta",
+		"response.output_text.done",
+		"response.output_item.done",
+		"response.completed",
+	}, types)
+
+	completed := events[len(events)-1]
+	require.Equal(t, "Hello world", completed.Get("response.output.0.content.0.text").String())
+	require.Equal(t, int64(7), completed.Get("response.usage.input_tokens").Int())
+	require.Positive(t, completed.Get("response.usage.output_tokens").Int())
+}
+
+func TestTranslateChatGPTWebStreamFailed(t *testing.T) {
+	var out bytes.Buffer
+	require.NoError(t, translateChatGPTWebStream(strings.NewReader("data: {\"error\":\"Too many requests\"}\n\ndata: [DONE]\n"), &out, "gpt-5", 1))
+	require.Contains(t, out.String(), `"type":"response.failed"`)
+	require.Contains(t, out.String(), "Too many requests")
+}
@@ -0,0 +1,50 @@
+package service
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// Codex 额度窗口响应头：将上游 x-codex-primary/secondary-* 归一为 5h / 7d 窗口后下发给客户端，
+// 便于客户端（包括经 Chat Completions / Messages 等兼容入口接入的客户端）得知额度重置时间。
+const (
+	codexRateLimitHeaderPrefix5h = "X-Ratelimit-5h-"
+	codexRateLimitHeaderPrefix7d = "X-Ratelimit-7d-"
+)
+
+// writeCodexRateLimitHeaders 解析上游 Codex 额度头并写出 x-ratelimit-{5h,7d}-* 响应头：
+// used-percent、window-minutes、reset-after-seconds 与 reset-at（Unix 秒）。上游未返回时不写。
+func writeCodexRateLimitHeaders(dst http.Header, src http.Header) {
+	if dst == nil || src == nil {
+		return
+	}
+	snapshot := ParseCodexRateLimitHeaders(src)
+	if snapshot == nil {
+		return
+	}
+	normalized := snapshot.Normalize()
+	if normalized == nil {
+		return
+	}
+	now := time.Now()
+	writeCodexRateLimitWindowHeaders(dst, codexRateLimitHeaderPrefix5h, normalized.Used5hPercent, normalized.Window5hMinutes, normalized.Reset5hSeconds, now)
+	writeCodexRateLimitWindowHeaders(dst, codexRateLimitHeaderPrefix7d, normalized.Used7dPercent, normalized.Window7dMinutes, normalized.Reset7dSeconds, now)
+}
+
+func writeCodexRateLimitWindowHeaders(dst http.Header, prefix string, usedPercent *float64, windowMinutes, resetAfterSeconds *int, now time.Time) {
+	if usedPercent != nil {
+		dst.Set(prefix+"Used-Percent", strconv.FormatFloat(*usedPercent, 'f', -1, 64))
+	}
+	if windowMinutes != nil {
+		dst.Set(prefix+"Window-Minutes", strconv.Itoa(*windowMinutes))
+	}
+	if resetAfterSeconds != nil {
+		seconds := *resetAfterSeconds
+		if seconds < 0 {
+			seconds = 0
+		}
+		dst.Set(prefix+"Reset-After-Seconds", strconv.Itoa(seconds))
+		dst.Set(prefix+"Reset-At", strconv.FormatInt(now.Add(time.Duration(seconds)*time.Second).Unix(), 10))
+	}
+}
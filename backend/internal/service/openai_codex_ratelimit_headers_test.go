@@ -0,0 +1,40 @@
+package service
+
+import (
+	"net/http"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestWriteCodexRateLimitHeaders(t *testing.T) {
+	src := http.Header{}
+	// primary 为周窗口、secondary 为 5h 窗口时按 window_minutes 归一
+	src.Set("x-codex-primary-used-percent", "40.5")
+	src.Set("x-codex-primary-window-minutes", "10080")
+	src.Set("x-codex-primary-reset-after-seconds", "86400")
+	src.Set("x-codex-secondary-used-percent", "90")
+	src.Set("x-codex-secondary-window-minutes", "300")
+	src.Set("x-codex-secondary-reset-after-seconds", "600")
+
+	dst := http.Header{}
+	before := time.Now().Unix()
+	writeCodexRateLimitHeaders(dst, src)
+
+	require.Equal(t, "90", dst.Get("x-ratelimit-5h-used-percent"))
+	require.Equal(t, "300", dst.Get("x-ratelimit-5h-window-minutes"))
+	require.Equal(t, "600", dst.Get("x-ratelimit-5h-reset-after-seconds"))
+	resetAt, err := strconv.ParseInt(dst.Get("x-ratelimit-5h-reset-at"), 10, 64)
+	require.NoError(t, err)
+	require.GreaterOrEqual(t, resetAt, before+600)
+
+	require.Equal(t, "40.5", dst.Get("x-ratelimit-7d-used-percent"))
+	require.Equal(t, "10080", dst.Get("x-ratelimit-7d-window-minutes"))
+	require.Equal(t, "86400", dst.Get("x-ratelimit-7d-reset-after-seconds"))
+
+	empty := http.Header{}
+	writeCodexRateLimitHeaders(empty, http.Header{"Content-Type": []string{"application/json"}})
+	require.Empty(t, empty)
+}
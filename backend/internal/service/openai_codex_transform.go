@@ -2,8 +2,13 @@ package service
 
 import (
 	"strings"
+
+	"github.com/ShaohongDong/sub2api/internal/config"
 )
 
+// defaultCodexInstructions 请求缺少 instructions 时填充的通用提示词（ChatGPT Codex 上游要求 instructions 必填）
+const defaultCodexInstructions = "You are a helpful coding assistant."
+
 var codexModelMap = map[string]string{
 	"gpt-5.4":                    "gpt-5.4",
 	"gpt-5.4-none":               "gpt-5.4",
@@ -239,7 +244,8 @@ func getNormalizedCodexModel(modelID string) string {
 			return value
 		}
 	}
-	return ""
+	// 模型目录同步发现的上游模型原样放行，避免新模型被下方的模糊匹配降级
+	return lookupCodexDiscoveredModel(modelID)
 }
 
 // applyInstructions 处理 instructions 字段：仅在 instructions 为空时填充默认值。
@@ -247,10 +253,57 @@ func applyInstructions(reqBody map[string]any, isCodexCLI bool) bool {
 	if !isInstructionsEmpty(reqBody) {
 		return false
 	}
-	reqBody["instructions"] = "You are a helpful coding assistant."
+	reqBody["instructions"] = defaultCodexInstructions
 	return true
 }
 
+// applyCodexInstructionsConfig 按 gateway.codex_instructions 处理 instructions：
+//   - Codex CLI：自带的指令块原样保留在最前（上游据此校验），仅在其后追加 Append；
+//   - 非 Codex 客户端：配置了 Default 时以其作为 instructions，客户端自己的 instructions
+//     改为 input 开头的 developer 消息，避免被上游拒绝的同时保留客户端意图；
+//   - Append 以空行分隔附加在最后，instructions 已包含时不重复追加。
+func applyCodexInstructionsConfig(reqBody map[string]any, isCodexCLI bool, cfg config.GatewayCodexInstructionsConfig) bool {
+	modified := false
+	if synthesized := strings.TrimSpace(cfg.Default); !isCodexCLI && synthesized != "" {
+		current, _ := reqBody["instructions"].(string)
+		if strings.TrimSpace(current) != synthesized {
+			if strings.TrimSpace(current) != "" {
+				prependCodexDeveloperMessage(reqBody, current)
+			}
+			reqBody["instructions"] = synthesized
+			modified = true
+		}
+	}
+	if addition := strings.TrimSpace(cfg.Append); addition != "" {
+		current, _ := reqBody["instructions"].(string)
+		if !strings.Contains(current, addition) {
+			if strings.TrimSpace(current) == "" {
+				current = defaultCodexInstructions
+			}
+			reqBody["instructions"] = strings.TrimRight(current, "\n") + "\n\n" + addition
+			modified = true
+		}
+	}
+	return modified
+}
+
+// prependCodexDeveloperMessage 将文本作为 developer 消息插入 input 开头（字符串 input 先转为消息数组）
+func prependCodexDeveloperMessage(reqBody map[string]any, text string) {
+	message := map[string]any{"type": "message", "role": "developer", "content": text}
+	switch input := reqBody["input"].(type) {
+	case []any:
+		reqBody["input"] = append([]any{message}, input...)
+	case string:
+		items := []any{message}
+		if strings.TrimSpace(input) != "" {
+			items = append(items, map[string]any{"type": "message", "role": "user", "content": input})
+		}
+		reqBody["input"] = items
+	default:
+		reqBody["input"] = []any{message}
+	}
+}
+
 // isInstructionsEmpty 检查 instructions 字段是否为空
 // 处理以下情况：字段不存在、nil、空字符串、纯空白字符串
 func isInstructionsEmpty(reqBody map[string]any) bool {
@@ -314,7 +367,9 @@ func filterCodexInput(input []any, preserveReferences bool) []any {
 			}
 		}
 
-		if !preserveReferences {
+		// MCP 项（mcp_call / mcp_list_tools / mcp_approval_request 等）以 id 作为上游必填标识，
+		// 审批响应还需通过 approval_request_id 关联，需原样保留。
+		if !preserveReferences && !isCodexMCPItemType(typ) {
 			ensureCopy()
 			delete(newItem, "id")
 			if !isCodexToolCallItemType(typ) {
@@ -328,12 +383,17 @@ func filterCodexInput(input []any, preserveReferences bool) []any {
 }
 
 func isCodexToolCallItemType(typ string) bool {
-	if typ == "" {
+	if typ == "" || isCodexMCPItemType(typ) {
 		return false
 	}
 	return strings.HasSuffix(typ, "_call") || strings.HasSuffix(typ, "_call_output")
 }
 
+// isCodexMCPItemType 判断是否为远程 MCP 工具相关的 input 项（mcp_call 等不使用 call_id）
+func isCodexMCPItemType(typ string) bool {
+	return strings.HasPrefix(typ, "mcp_")
+}
+
 func normalizeCodexTools(reqBody map[string]any) bool {
 	rawTools, ok := reqBody["tools"]
 	if !ok || rawTools == nil {
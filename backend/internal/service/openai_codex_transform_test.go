@@ -3,6 +3,7 @@ package service
 import (
 	"testing"
 
+	"github.com/ShaohongDong/sub2api/internal/config"
 	"github.com/stretchr/testify/require"
 )
 
@@ -137,6 +138,30 @@ func TestFilterCodexInput_RemovesItemReferenceWhenNotPreserved(t *testing.T) {
 	require.False(t, hasID)
 }
 
+func TestFilterCodexInput_KeepsMCPItemIDs(t *testing.T) {
+	// mcp_call 等 MCP 项依赖 id，且不应被补上 call_id。
+	input := []any{
+		map[string]any{"type": "mcp_list_tools", "id": "mcpl_1", "server_label": "docs"},
+		map[string]any{"type": "mcp_call", "id": "mcp_1", "server_label": "docs", "name": "search", "arguments": "{}"},
+		map[string]any{"type": "function_call", "id": "fc_1", "name": "run", "arguments": "{}"},
+	}
+
+	filtered := filterCodexInput(input, false)
+	require.Len(t, filtered, 3)
+	for _, raw := range filtered[:2] {
+		item, ok := raw.(map[string]any)
+		require.True(t, ok)
+		require.NotEmpty(t, item["id"])
+		_, hasCallID := item["call_id"]
+		require.False(t, hasCallID)
+	}
+	fc, ok := filtered[2].(map[string]any)
+	require.True(t, ok)
+	require.Equal(t, "fc_1", fc["call_id"])
+	_, hasID := fc["id"]
+	require.False(t, hasID)
+}
+
 func TestApplyCodexOAuthTransform_NormalizeCodexTools_PreservesResponsesFunctionTools(t *testing.T) {
 	reqBody := map[string]any{
 		"model": "gpt-5.1",
@@ -293,6 +318,42 @@ func TestApplyCodexOAuthTransform_StringInputWithToolsField(t *testing.T) {
 	require.Len(t, input, 1)
 }
 
+func TestApplyCodexInstructionsConfig_CodexCLIKeepsBlockFirst(t *testing.T) {
+	cfg := config.GatewayCodexInstructionsConfig{Default: "Synthesized", Append: "Operator rules"}
+	reqBody := map[string]any{"instructions": "Codex block", "input": "hi"}
+
+	require.True(t, applyCodexInstructionsConfig(reqBody, true, cfg))
+	require.Equal(t, "Codex block\n\nOperator rules", reqBody["instructions"])
+	require.Equal(t, "hi", reqBody["input"])
+
+	// 已追加过时不重复追加
+	require.False(t, applyCodexInstructionsConfig(reqBody, true, cfg))
+}
+
+func TestApplyCodexInstructionsConfig_NonCodexSynthesizesInstructions(t *testing.T) {
+	cfg := config.GatewayCodexInstructionsConfig{Default: "Synthesized"}
+	reqBody := map[string]any{
+		"instructions": "Client system prompt",
+		"input":        []any{map[string]any{"type": "message", "role": "user", "content": "hi"}},
+	}
+
+	require.True(t, applyCodexInstructionsConfig(reqBody, false, cfg))
+	require.Equal(t, "Synthesized", reqBody["instructions"])
+	input := reqBody["input"].([]any)
+	require.Len(t, input, 2)
+	require.Equal(t, map[string]any{"type": "message", "role": "developer", "content": "Client system prompt"}, input[0])
+
+	stringInput := map[string]any{"instructions": "Client", "input": "hi"}
+	require.True(t, applyCodexInstructionsConfig(stringInput, false, cfg))
+	require.Len(t, stringInput["input"].([]any), 2)
+
+	// 未配置 Default：保留客户端 instructions；缺失时 Append 附加在通用提示词之后
+	empty := map[string]any{"input": "hi"}
+	require.True(t, applyCodexInstructionsConfig(empty, false, config.GatewayCodexInstructionsConfig{Append: "Extra"}))
+	require.Equal(t, defaultCodexInstructions+"\n\nExtra", empty["instructions"])
+	require.False(t, applyCodexInstructionsConfig(map[string]any{"instructions": "Client"}, false, config.GatewayCodexInstructionsConfig{}))
+}
+
 func TestIsInstructionsEmpty(t *testing.T) {
 	tests := []struct {
 		name     string
@@ -0,0 +1,251 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/tidwall/gjson"
+)
+
+// OpenAI 格式错误的 code 取值（客户端据此区分错误来源，message 仅供阅读）
+const (
+	OpenAIErrorCodeUpstreamAuth        = "upstream_authentication_failed"
+	OpenAIErrorCodeUpstreamBilling     = "upstream_billing_error"
+	OpenAIErrorCodeUpstreamForbidden   = "upstream_permission_denied"
+	OpenAIErrorCodeRateLimitExceeded   = "rate_limit_exceeded"
+	OpenAIErrorCodeUpstreamOverloaded  = "upstream_overloaded"
+	OpenAIErrorCodeUpstreamUnavailable = "upstream_unavailable"
+	OpenAIErrorCodeUpstreamTimeout     = "upstream_timeout"
+	OpenAIErrorCodeProxyError          = "upstream_proxy_error"
+	OpenAIErrorCodeUpstreamError       = "upstream_error"
+)
+
+// openAIErrorMessageMaxLen 透传给客户端的上游错误消息长度上限
+const openAIErrorMessageMaxLen = 1024
+
+// OpenAIClientError 返回给客户端的 OpenAI 格式错误：{"error":{"message","type","param","code"}}
+type OpenAIClientError struct {
+	Status  int
+	Type    string
+	Code    string
+	Message string
+}
+
+// Body 返回 OpenAI 格式的错误响应体；code 为空时输出 null，与 OpenAI 官方响应一致
+func (e OpenAIClientError) Body() gin.H {
+	var code any
+	if e.Code != "" {
+		code = e.Code
+	}
+	return gin.H{
+		"error": gin.H{
+			"message": e.Message,
+			"type":    e.Type,
+			"param":   nil,
+			"code":    code,
+		},
+	}
+}
+
+// SSEEvent 返回流式响应已开始后使用的 SSE 错误事件
+func (e OpenAIClientError) SSEEvent() string {
+	// 固定 schema，使用 Quote 直拼可避免额外 Marshal 分配
+	code := "null"
+	if e.Code != "" {
+		code = strconv.Quote(e.Code)
+	}
+	return "event: error\ndata: " + `{"error":{"message":` + strconv.Quote(e.Message) + `,"type":` + strconv.Quote(e.Type) +
+		`,"param":null,"code":` + code + `}}` + "\n\n"
+}
+
+// WriteOpenAIError 写出 OpenAI 格式的错误响应
+func WriteOpenAIError(c *gin.Context, e OpenAIClientError) {
+	c.JSON(e.Status, e.Body())
+}
+
+// MapOpenAIUpstreamStatus 将上游 HTTP 状态码映射为面向客户端的错误。
+// 上游账号的认证 / 计费 / 权限问题属于网关侧故障，统一以 502 返回，不暴露给客户端具体账号信息。
+func MapOpenAIUpstreamStatus(statusCode int) OpenAIClientError {
+	switch statusCode {
+	case http.StatusUnauthorized:
+		return OpenAIClientError{http.StatusBadGateway, "upstream_error", OpenAIErrorCodeUpstreamAuth,
+			"Upstream authentication failed, please contact administrator"}
+	case http.StatusPaymentRequired:
+		return OpenAIClientError{http.StatusBadGateway, "upstream_error", OpenAIErrorCodeUpstreamBilling,
+			"Upstream payment required: insufficient balance or billing issue"}
+	case http.StatusForbidden:
+		return OpenAIClientError{http.StatusBadGateway, "upstream_error", OpenAIErrorCodeUpstreamForbidden,
+			"Upstream access forbidden, please contact administrator"}
+	case http.StatusTooManyRequests:
+		return OpenAIClientError{http.StatusTooManyRequests, "rate_limit_error", OpenAIErrorCodeRateLimitExceeded,
+			"Upstream rate limit exceeded, please retry later"}
+	case 529:
+		return OpenAIClientError{http.StatusServiceUnavailable, "upstream_error", OpenAIErrorCodeUpstreamOverloaded,
+			"Upstream service overloaded, please retry later"}
+	case http.StatusGatewayTimeout:
+		return OpenAIClientError{http.StatusGatewayTimeout, "upstream_error", OpenAIErrorCodeUpstreamTimeout,
+			"Upstream request timed out, please retry later"}
+	case http.StatusInternalServerError, http.StatusBadGateway, http.StatusServiceUnavailable:
+		return OpenAIClientError{http.StatusBadGateway, "upstream_error", OpenAIErrorCodeUpstreamUnavailable,
+			"Upstream service temporarily unavailable"}
+	default:
+		return OpenAIClientError{http.StatusBadGateway, "upstream_error", OpenAIErrorCodeUpstreamError,
+			"Upstream request failed"}
+	}
+}
+
+// MapOpenAIRequestError 将未拿到上游响应的请求错误（超时、代理、连接失败）映射为客户端错误
+func MapOpenAIRequestError(err error) OpenAIClientError {
+	if IsProxyConnectError(err) {
+		return OpenAIClientError{http.StatusBadGateway, "upstream_error", OpenAIErrorCodeProxyError,
+			"Upstream proxy connection failed, please retry later"}
+	}
+	if isOpenAIRequestTimeout(err) {
+		return OpenAIClientError{http.StatusGatewayTimeout, "upstream_error", OpenAIErrorCodeUpstreamTimeout,
+			"Upstream request timed out, please retry later"}
+	}
+	return OpenAIClientError{http.StatusBadGateway, "upstream_error", OpenAIErrorCodeUpstreamError,
+		"Upstream request failed"}
+}
+
+func isOpenAIRequestTimeout(err error) bool {
+	if err == nil {
+		return false
+	}
+	if errors.Is(err, context.DeadlineExceeded) {
+		return true
+	}
+	var netErr net.Error
+	if errors.As(err, &netErr) && netErr.Timeout() {
+		return true
+	}
+	msg := strings.ToLower(err.Error())
+	return strings.Contains(msg, "timeout") || strings.Contains(msg, "deadline exceeded")
+}
+
+// NormalizeOpenAIUpstreamError 将上游错误响应体（OpenAI / Anthropic / Gemini / ChatGPT detail / HTML 等）
+// 转换为 OpenAI 格式，保留上游状态码与可读消息，用于透传模式。
+// 消息中的账号标识被脱敏；HTML 页面、非 JSON 响应与 5xx/认证类错误使用 MapOpenAIUpstreamStatus 的通用消息。
+func NormalizeOpenAIUpstreamError(statusCode int, body []byte, account *Account) OpenAIClientError {
+	mapped := MapOpenAIUpstreamStatus(statusCode)
+	out := OpenAIClientError{Status: statusCode, Type: mapped.Type, Code: mapped.Code, Message: mapped.Message}
+	if statusCode < 400 {
+		out.Status = http.StatusBadGateway
+	}
+
+	trimmed := strings.TrimSpace(string(body))
+	if trimmed == "" || !gjson.Valid(trimmed) || isOpenAIUpstreamHTMLError(trimmed) {
+		return out
+	}
+
+	if t := openAIUpstreamErrorType(body); t != "" {
+		out.Type = t
+	}
+	if c := openAIUpstreamErrorCode(body); c != "" {
+		out.Code = c
+	}
+	// 账号认证、权限类错误的原始消息通常包含组织 / 工作区信息，只返回通用消息
+	switch statusCode {
+	case http.StatusUnauthorized, http.StatusForbidden, http.StatusPaymentRequired:
+		out.Type, out.Code = mapped.Type, mapped.Code
+		return out
+	}
+	if statusCode >= 500 {
+		return out
+	}
+	if msg := ScrubOpenAIClientErrorMessage(extractUpstreamErrorMessage(body), account); msg != "" {
+		out.Message = msg
+	}
+	return out
+}
+
+// openAIUpstreamErrorType 提取上游错误类型：OpenAI / Anthropic 的 error.type，Gemini 的 error.status
+func openAIUpstreamErrorType(body []byte) string {
+	for _, path := range []string{"error.type", "error.status"} {
+		if v := gjson.GetBytes(body, path); v.Type == gjson.String {
+			if t := strings.ToLower(strings.TrimSpace(v.String())); openAIErrorTokenRegex.MatchString(t) {
+				return t
+			}
+		}
+	}
+	return ""
+}
+
+// openAIUpstreamErrorCode 提取字符串形式的 error.code（Gemini 的数字 code 即 HTTP 状态码，忽略）
+func openAIUpstreamErrorCode(body []byte) string {
+	if v := gjson.GetBytes(body, "error.code"); v.Type == gjson.String {
+		if c := strings.TrimSpace(v.String()); openAIErrorTokenRegex.MatchString(c) {
+			return c
+		}
+	}
+	return ""
+}
+
+func isOpenAIUpstreamHTMLError(body string) bool {
+	lower := strings.ToLower(body[:min(len(body), 256)])
+	return strings.HasPrefix(lower, "<!doctype") || strings.HasPrefix(lower, "<html") || strings.Contains(lower, "<body")
+}
+
+var (
+	openAIErrorTokenRegex = regexp.MustCompile(`^[a-z0-9_.\-]{1,64}$`)
+
+	// openAIAccountIdentifierRegexes 上游错误消息中可能出现的账号标识：邮箱、组织 / 项目 / 用户 ID、工作区 UUID
+	openAIAccountIdentifierRegexes = []*regexp.Regexp{
+		regexp.MustCompile(`[A-Za-z0-9._%+\-]+@[A-Za-z0-9.\-]+\.[A-Za-z]{2,}`),
+		regexp.MustCompile(`\b(?:org|proj|user|acct|workspace)[-_][A-Za-z0-9]{6,}\b`),
+		regexp.MustCompile(`\b[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}\b`),
+		regexp.MustCompile(`\b(?:sk|sess|rt)-[A-Za-z0-9_\-]{8,}\b`),
+	}
+)
+
+// ScrubOpenAIClientErrorMessage 清理准备返回给客户端的上游错误消息：
+// 去除 HTML、账号凭证与标识（含该账号已知的 ChatGPT 账号 / 用户 / 组织 ID），并限制长度
+func ScrubOpenAIClientErrorMessage(msg string, account *Account) string {
+	msg = strings.TrimSpace(sanitizeUpstreamErrorMessage(msg))
+	if msg == "" || isOpenAIUpstreamHTMLError(msg) {
+		return ""
+	}
+	if account != nil {
+		for _, id := range []string{
+			account.GetChatGPTAccountID(),
+			account.GetChatGPTUserID(),
+			account.GetOpenAIOrganizationID(),
+			account.GetCredential("email"),
+		} {
+			if id = strings.TrimSpace(id); len(id) >= 4 {
+				msg = strings.ReplaceAll(msg, id, "[redacted]")
+			}
+		}
+	}
+	for _, re := range openAIAccountIdentifierRegexes {
+		msg = re.ReplaceAllString(msg, "[redacted]")
+	}
+	if len(msg) > openAIErrorMessageMaxLen {
+		msg = truncateString(msg, openAIErrorMessageMaxLen)
+	}
+	return msg
+}
+
+// isOpenAIPassthroughErrorBodySafe 判断透传模式下上游错误体能否原样返回：
+// 必须是带 error.message 的 OpenAI 格式 JSON，且不是账号认证类错误、不含账号标识
+func isOpenAIPassthroughErrorBodySafe(statusCode int, body []byte, account *Account) bool {
+	switch statusCode {
+	case http.StatusUnauthorized, http.StatusForbidden, http.StatusPaymentRequired:
+		return false
+	}
+	if !gjson.ValidBytes(body) {
+		return false
+	}
+	msg := gjson.GetBytes(body, "error.message")
+	if msg.Type != gjson.String {
+		return false
+	}
+	raw := strings.TrimSpace(msg.String())
+	return raw != "" && ScrubOpenAIClientErrorMessage(raw, account) == raw
+}
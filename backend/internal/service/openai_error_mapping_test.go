@@ -0,0 +1,139 @@
+//go:build unit
+
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/ShaohongDong/sub2api/internal/config"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMapOpenAIUpstreamStatus(t *testing.T) {
+	cases := []struct {
+		upstream int
+		status   int
+		errType  string
+		code     string
+	}{
+		{http.StatusUnauthorized, http.StatusBadGateway, "upstream_error", OpenAIErrorCodeUpstreamAuth},
+		{http.StatusForbidden, http.StatusBadGateway, "upstream_error", OpenAIErrorCodeUpstreamForbidden},
+		{http.StatusTooManyRequests, http.StatusTooManyRequests, "rate_limit_error", OpenAIErrorCodeRateLimitExceeded},
+		{529, http.StatusServiceUnavailable, "upstream_error", OpenAIErrorCodeUpstreamOverloaded},
+		{http.StatusGatewayTimeout, http.StatusGatewayTimeout, "upstream_error", OpenAIErrorCodeUpstreamTimeout},
+		{http.StatusServiceUnavailable, http.StatusBadGateway, "upstream_error", OpenAIErrorCodeUpstreamUnavailable},
+		{418, http.StatusBadGateway, "upstream_error", OpenAIErrorCodeUpstreamError},
+	}
+	for _, tc := range cases {
+		got := MapOpenAIUpstreamStatus(tc.upstream)
+		require.Equal(t, tc.status, got.Status, "upstream %d", tc.upstream)
+		require.Equal(t, tc.errType, got.Type, "upstream %d", tc.upstream)
+		require.Equal(t, tc.code, got.Code, "upstream %d", tc.upstream)
+	}
+}
+
+func TestMapOpenAIRequestError(t *testing.T) {
+	proxyErr := MapOpenAIRequestError(errors.New(`Post "https://chatgpt.com": proxyconnect tcp: dial tcp 10.0.0.1:8080: connection refused`))
+	require.Equal(t, http.StatusBadGateway, proxyErr.Status)
+	require.Equal(t, OpenAIErrorCodeProxyError, proxyErr.Code)
+	require.NotContains(t, proxyErr.Message, "10.0.0.1")
+
+	timeoutErr := MapOpenAIRequestError(fmt.Errorf("do request: %w", context.DeadlineExceeded))
+	require.Equal(t, http.StatusGatewayTimeout, timeoutErr.Status)
+	require.Equal(t, OpenAIErrorCodeUpstreamTimeout, timeoutErr.Code)
+
+	other := MapOpenAIRequestError(errors.New("connection reset by peer"))
+	require.Equal(t, http.StatusBadGateway, other.Status)
+	require.Equal(t, OpenAIErrorCodeUpstreamError, other.Code)
+}
+
+func TestNormalizeOpenAIUpstreamError_Shapes(t *testing.T) {
+	account := &Account{
+		Platform:    PlatformOpenAI,
+		Type:        AccountTypeOAuth,
+		Credentials: map[string]any{"chatgpt_account_id": "acc-7f3e9b21", "email": "owner@example.com"},
+	}
+
+	// OpenAI 格式：保留 type / code，消息中的账号标识被脱敏
+	got := NormalizeOpenAIUpstreamError(http.StatusBadRequest,
+		[]byte(`{"error":{"message":"Model not allowed for workspace acc-7f3e9b21 (owner@example.com)","type":"invalid_request_error","code":"model_not_found"}}`), account)
+	require.Equal(t, http.StatusBadRequest, got.Status)
+	require.Equal(t, "invalid_request_error", got.Type)
+	require.Equal(t, "model_not_found", got.Code)
+	require.NotContains(t, got.Message, "acc-7f3e9b21")
+	require.NotContains(t, got.Message, "owner@example.com")
+
+	// Anthropic 格式
+	got = NormalizeOpenAIUpstreamError(http.StatusBadRequest,
+		[]byte(`{"type":"error","error":{"type":"invalid_request_error","message":"max_tokens: too large"}}`), nil)
+	require.Equal(t, "invalid_request_error", got.Type)
+	require.Equal(t, "max_tokens: too large", got.Message)
+
+	// Gemini 格式：数字 code 不作为 OpenAI code
+	got = NormalizeOpenAIUpstreamError(http.StatusBadRequest,
+		[]byte(`{"error":{"code":400,"message":"Invalid JSON payload","status":"INVALID_ARGUMENT"}}`), nil)
+	require.Equal(t, "invalid_argument", got.Type)
+	require.Equal(t, "Invalid JSON payload", got.Message)
+
+	// Codex 401：只返回通用消息
+	got = NormalizeOpenAIUpstreamError(http.StatusUnauthorized,
+		[]byte(`{"error":{"message":"Your token for org-AbCdEf123456 has expired","type":"invalid_request_error","code":"token_expired"}}`), nil)
+	require.Equal(t, http.StatusUnauthorized, got.Status)
+	require.Equal(t, OpenAIErrorCodeUpstreamAuth, got.Code)
+	require.NotContains(t, got.Message, "org-")
+
+	// HTML 错误页
+	got = NormalizeOpenAIUpstreamError(http.StatusBadGateway, []byte("<!DOCTYPE html><html><body>cloudflare</body></html>"), nil)
+	require.Equal(t, "Upstream service temporarily unavailable", got.Message)
+	require.Equal(t, OpenAIErrorCodeUpstreamUnavailable, got.Code)
+}
+
+func TestOpenAIClientErrorSchema(t *testing.T) {
+	e := OpenAIClientError{Status: http.StatusTooManyRequests, Type: "rate_limit_error", Code: OpenAIErrorCodeRateLimitExceeded, Message: `retry "later"`}
+
+	raw, err := json.Marshal(e.Body())
+	require.NoError(t, err)
+	require.JSONEq(t, `{"error":{"message":"retry \"later\"","type":"rate_limit_error","param":null,"code":"rate_limit_exceeded"}}`, string(raw))
+
+	event := e.SSEEvent()
+	require.True(t, strings.HasPrefix(event, "event: error\ndata: "))
+	require.JSONEq(t, string(raw), strings.TrimSuffix(strings.TrimPrefix(event, "event: error\ndata: "), "\n\n"))
+
+	raw, err = json.Marshal(OpenAIClientError{Type: "api_error", Message: "x"}.Body())
+	require.NoError(t, err)
+	require.JSONEq(t, `{"error":{"message":"x","type":"api_error","param":null,"code":null}}`, string(raw))
+}
+
+func TestOpenAIPassthroughUpstreamHTMLErrorIsNormalized(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	rec := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(rec)
+	c.Request = httptest.NewRequest(http.MethodPost, "/v1/responses", nil)
+
+	svc := &OpenAIGatewayService{cfg: &config.Config{}}
+	account := &Account{ID: 1, Name: "acc", Platform: PlatformOpenAI, Type: AccountTypeAPIKey}
+	resp := &http.Response{
+		StatusCode: http.StatusBadGateway,
+		Header:     http.Header{"Content-Type": []string{"text/html"}},
+		Body:       io.NopCloser(strings.NewReader("<html><body><h1>502 Bad Gateway</h1></body></html>")),
+	}
+
+	require.Error(t, svc.handleErrorResponsePassthrough(context.Background(), resp, c, account, nil))
+	require.Equal(t, http.StatusBadGateway, rec.Code)
+	require.Contains(t, rec.Header().Get("Content-Type"), "application/json")
+	require.NotContains(t, rec.Body.String(), "<html>")
+
+	var parsed map[string]map[string]any
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &parsed))
+	require.Equal(t, "upstream_error", parsed["error"]["type"])
+	require.Equal(t, OpenAIErrorCodeUpstreamUnavailable, parsed["error"]["code"])
+}
@@ -0,0 +1,337 @@
+package service
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/ShaohongDong/sub2api/internal/pkg/logger"
+	"github.com/ShaohongDong/sub2api/internal/util/responseheaders"
+	"github.com/gin-gonic/gin"
+	"github.com/tidwall/gjson"
+	"github.com/tidwall/sjson"
+	"go.uber.org/zap"
+)
+
+// OpenAIAudioMultipartFields 是转写请求 multipart 表单中网关关心的字段。
+type OpenAIAudioMultipartFields struct {
+	Model   string
+	Stream  bool
+	HasFile bool
+}
+
+// ParseOpenAIAudioMultipart 解析 /v1/audio/transcriptions 的 multipart 表单，
+// 只读取文本字段与 file 是否存在，不解码音频内容。
+func ParseOpenAIAudioMultipart(contentType string, body []byte) (OpenAIAudioMultipartFields, error) {
+	var fields OpenAIAudioMultipartFields
+	reader, err := newAudioMultipartReader(contentType, body)
+	if err != nil {
+		return fields, err
+	}
+	for {
+		part, err := reader.NextPart()
+		if errors.Is(err, io.EOF) {
+			return fields, nil
+		}
+		if err != nil {
+			return fields, fmt.Errorf("invalid multipart body: %w", err)
+		}
+		switch part.FormName() {
+		case "file":
+			fields.HasFile = true
+		case "model":
+			value, _ := io.ReadAll(io.LimitReader(part, 1024))
+			fields.Model = strings.TrimSpace(string(value))
+		case "stream":
+			value, _ := io.ReadAll(io.LimitReader(part, 16))
+			fields.Stream = strings.EqualFold(strings.TrimSpace(string(value)), "true")
+		}
+		_ = part.Close()
+	}
+}
+
+func newAudioMultipartReader(contentType string, body []byte) (*multipart.Reader, error) {
+	mediaType, params, err := mime.ParseMediaType(contentType)
+	if err != nil || !strings.EqualFold(mediaType, "multipart/form-data") {
+		return nil, fmt.Errorf("content type must be multipart/form-data")
+	}
+	boundary := params["boundary"]
+	if boundary == "" {
+		return nil, fmt.Errorf("multipart boundary is missing")
+	}
+	return multipart.NewReader(bytes.NewReader(body), boundary), nil
+}
+
+// rewriteAudioMultipartModel 以原 boundary 重建 multipart 表单并替换 model 字段，
+// 其余 part（含音频文件）按原样复制。
+func rewriteAudioMultipartModel(contentType string, body []byte, model string) ([]byte, error) {
+	reader, err := newAudioMultipartReader(contentType, body)
+	if err != nil {
+		return nil, err
+	}
+	_, params, _ := mime.ParseMediaType(contentType)
+
+	var buf bytes.Buffer
+	buf.Grow(len(body))
+	writer := multipart.NewWriter(&buf)
+	if err := writer.SetBoundary(params["boundary"]); err != nil {
+		return nil, err
+	}
+	for {
+		part, err := reader.NextPart()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("invalid multipart body: %w", err)
+		}
+		dst, err := writer.CreatePart(part.Header)
+		if err != nil {
+			return nil, err
+		}
+		if part.FormName() == "model" {
+			_, err = io.WriteString(dst, model)
+		} else {
+			_, err = io.Copy(dst, part)
+		}
+		_ = part.Close()
+		if err != nil {
+			return nil, err
+		}
+	}
+	if err := writer.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// ForwardAudioTranscription 透传 /v1/audio/transcriptions（multipart）到 API Key 账号。
+// 应用模型映射后按原格式返回；usage 取自 JSON 响应或流式 transcript.text.done 事件
+// （whisper-1 等按时长计费的模型不返回 token 用量，仅记录请求）。
+func (s *OpenAIGatewayService) ForwardAudioTranscription(
+	ctx context.Context,
+	c *gin.Context,
+	account *Account,
+	body []byte,
+	_ string,
+	defaultMappedModel string,
+) (*OpenAIForwardResult, error) {
+	startTime := time.Now()
+
+	if !SupportsOpenAIPlatformAPI(account) {
+		writeOpenAICompatError(c, http.StatusBadRequest, "Audio is not supported by this account type")
+		return nil, fmt.Errorf("audio not supported for account type %s", account.Type)
+	}
+
+	contentType := c.GetHeader("Content-Type")
+	fields, err := ParseOpenAIAudioMultipart(contentType, body)
+	if err != nil {
+		writeOpenAICompatError(c, http.StatusBadRequest, err.Error())
+		return nil, fmt.Errorf("parse transcription form: %w", err)
+	}
+
+	originalModel := fields.Model
+	mappedModel := s.resolveOpenAIAudioModel(account, originalModel, defaultMappedModel)
+	if mappedModel != originalModel {
+		body, err = rewriteAudioMultipartModel(contentType, body, mappedModel)
+		if err != nil {
+			writeOpenAICompatError(c, http.StatusBadRequest, err.Error())
+			return nil, fmt.Errorf("rewrite transcription model: %w", err)
+		}
+	}
+
+	return s.forwardOpenAIAudio(ctx, c, account, "/audio/transcriptions", body, contentType, originalModel, mappedModel, OpenAIUsage{}, startTime)
+}
+
+// ForwardAudioSpeech 透传 /v1/audio/speech（JSON）到 API Key 账号，音频响应按块转发。
+// 上游仅在 stream_format=sse 时返回 usage；否则按 input 文本估算输入 token。
+func (s *OpenAIGatewayService) ForwardAudioSpeech(
+	ctx context.Context,
+	c *gin.Context,
+	account *Account,
+	body []byte,
+	_ string,
+	defaultMappedModel string,
+) (*OpenAIForwardResult, error) {
+	startTime := time.Now()
+
+	if !SupportsOpenAIPlatformAPI(account) {
+		writeOpenAICompatError(c, http.StatusBadRequest, "Audio is not supported by this account type")
+		return nil, fmt.Errorf("audio not supported for account type %s", account.Type)
+	}
+
+	originalModel := gjson.GetBytes(body, "model").String()
+	mappedModel := s.resolveOpenAIAudioModel(account, originalModel, defaultMappedModel)
+	if mappedModel != originalModel {
+		var err error
+		body, err = sjson.SetBytes(body, "model", mappedModel)
+		if err != nil {
+			return nil, fmt.Errorf("set mapped model: %w", err)
+		}
+	}
+	estimated := OpenAIUsage{InputTokens: estimateTokensForText(gjson.GetBytes(body, "input").String())}
+
+	return s.forwardOpenAIAudio(ctx, c, account, "/audio/speech", body, "application/json", originalModel, mappedModel, estimated, startTime)
+}
+
+func (s *OpenAIGatewayService) resolveOpenAIAudioModel(account *Account, originalModel, defaultMappedModel string) string {
+	mappedModel := resolveOpenAIMappedModel(account, originalModel, defaultMappedModel)
+	logger.L().Debug("openai audio: model mapping applied",
+		zap.Int64("account_id", account.ID),
+		zap.String("original_model", originalModel),
+		zap.String("mapped_model", mappedModel),
+	)
+	return mappedModel
+}
+
+// forwardOpenAIAudio 发送音频请求并按上游 Content-Type 回写：
+// SSE 逐行转发并提取 usage；JSON 整体读取后提取 usage；其余（音频 / 纯文本字幕）按块转发。
+// fallbackUsage 在上游未返回 usage 时使用。
+func (s *OpenAIGatewayService) forwardOpenAIAudio(
+	ctx context.Context,
+	c *gin.Context,
+	account *Account,
+	path string,
+	body []byte,
+	contentType string,
+	originalModel string,
+	mappedModel string,
+	fallbackUsage OpenAIUsage,
+	startTime time.Time,
+) (*OpenAIForwardResult, error) {
+	targetURL, err := s.buildOpenAIPlatformURL(account, path)
+	if err != nil {
+		return nil, fmt.Errorf("build upstream url: %w", err)
+	}
+	token, _, err := s.GetAccessToken(ctx, account)
+	if err != nil {
+		return nil, fmt.Errorf("get access token: %w", err)
+	}
+
+	resp, err := s.doOpenAIPlatformRequest(ctx, c, account, targetURL, token, body, contentType)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	if s.responseHeaderFilter != nil {
+		responseheaders.WriteFilteredHeaders(c.Writer.Header(), resp.Header, s.responseHeaderFilter)
+	}
+	respContentType := resp.Header.Get("Content-Type")
+	mediaType, _, _ := mime.ParseMediaType(respContentType)
+
+	result := &OpenAIForwardResult{
+		RequestID:    resp.Header.Get("x-request-id"),
+		Usage:        fallbackUsage,
+		Model:        originalModel,
+		BillingModel: mappedModel,
+	}
+
+	switch mediaType {
+	case "text/event-stream":
+		result.Stream = true
+		usage, firstTokenMs, err := s.streamOpenAIAudioEvents(resp, c, startTime)
+		if usage != nil {
+			result.Usage = *usage
+		}
+		result.FirstTokenMs = firstTokenMs
+		if err != nil {
+			return nil, err
+		}
+	case "application/json":
+		respBody, err := readUpstreamResponseBodyLimited(resp.Body, resolveUpstreamResponseReadLimit(s.cfg))
+		if err != nil {
+			if errors.Is(err, ErrUpstreamResponseBodyTooLarge) {
+				writeOpenAICompatError(c, http.StatusBadGateway, "Upstream response too large")
+			}
+			return nil, fmt.Errorf("read upstream body: %w", err)
+		}
+		if usage := parseOpenAIAudioUsage(gjson.GetBytes(respBody, "usage")); usage != nil {
+			result.Usage = *usage
+		}
+		c.Data(resp.StatusCode, respContentType, respBody)
+	default:
+		if respContentType != "" {
+			c.Header("Content-Type", respContentType)
+		}
+		c.Status(resp.StatusCode)
+		if _, err := io.Copy(flushWriter{c.Writer}, resp.Body); err != nil {
+			return nil, fmt.Errorf("copy audio response: %w", err)
+		}
+	}
+
+	result.Duration = time.Since(startTime)
+	return result, nil
+}
+
+// streamOpenAIAudioEvents 逐行转发音频 SSE 流，从 transcript.text.done / speech.audio.done 事件提取 usage。
+func (s *OpenAIGatewayService) streamOpenAIAudioEvents(resp *http.Response, c *gin.Context, startTime time.Time) (*OpenAIUsage, *int, error) {
+	c.Writer.Header().Set("Content-Type", "text/event-stream")
+	c.Writer.Header().Set("Cache-Control", "no-cache")
+	c.Writer.Header().Set("Connection", "keep-alive")
+	c.Writer.Header().Set("X-Accel-Buffering", "no")
+	c.Writer.WriteHeader(resp.StatusCode)
+
+	var usage *OpenAIUsage
+	var firstTokenMs *int
+	scanner := bufio.NewScanner(resp.Body)
+	maxLineSize := defaultMaxLineSize
+	if s.cfg != nil && s.cfg.Gateway.MaxLineSize > 0 {
+		maxLineSize = s.cfg.Gateway.MaxLineSize
+	}
+	scanner.Buffer(make([]byte, 0, 64*1024), maxLineSize)
+
+	for scanner.Scan() {
+		line := scanner.Text()
+		if payload, ok := strings.CutPrefix(line, "data: "); ok {
+			if firstTokenMs == nil {
+				ms := int(time.Since(startTime).Milliseconds())
+				firstTokenMs = &ms
+			}
+			if u := parseOpenAIAudioUsage(gjson.Get(payload, "usage")); u != nil {
+				usage = u
+			}
+		}
+		if _, err := fmt.Fprintf(c.Writer, "%s\n", line); err != nil {
+			return usage, firstTokenMs, fmt.Errorf("write audio stream: %w", err)
+		}
+		if line == "" {
+			c.Writer.Flush()
+		}
+	}
+	c.Writer.Flush()
+	if err := scanner.Err(); err != nil {
+		return usage, firstTokenMs, fmt.Errorf("read audio stream: %w", err)
+	}
+	return usage, firstTokenMs, nil
+}
+
+// parseOpenAIAudioUsage 解析音频接口的 token 用量；按时长计费（type=duration）时返回 nil。
+func parseOpenAIAudioUsage(u gjson.Result) *OpenAIUsage {
+	if !u.Exists() || u.Get("type").String() == "duration" {
+		return nil
+	}
+	return &OpenAIUsage{
+		InputTokens:  int(u.Get("input_tokens").Int()),
+		OutputTokens: int(u.Get("output_tokens").Int()),
+	}
+}
+
+// flushWriter 每次写入后立即 flush，用于按块转发音频数据。
+type flushWriter struct {
+	w gin.ResponseWriter
+}
+
+func (f flushWriter) Write(p []byte) (int, error) {
+	n, err := f.w.Write(p)
+	f.w.Flush()
+	return n, err
+}
@@ -0,0 +1,128 @@
+package service
+
+import (
+	"bytes"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/ShaohongDong/sub2api/internal/config"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/require"
+)
+
+func newAudioMultipartBody(t *testing.T, model string) ([]byte, string) {
+	t.Helper()
+	var buf bytes.Buffer
+	w := multipart.NewWriter(&buf)
+	fw, err := w.CreateFormFile("file", "speech.mp3")
+	require.NoError(t, err)
+	_, _ = fw.Write([]byte("ID3\x00fake-audio"))
+	require.NoError(t, w.WriteField("model", model))
+	require.NoError(t, w.WriteField("response_format", "json"))
+	require.NoError(t, w.Close())
+	return buf.Bytes(), w.FormDataContentType()
+}
+
+func TestParseOpenAIAudioMultipart(t *testing.T) {
+	body, contentType := newAudioMultipartBody(t, "whisper-1")
+	fields, err := ParseOpenAIAudioMultipart(contentType, body)
+	require.NoError(t, err)
+	require.Equal(t, "whisper-1", fields.Model)
+	require.True(t, fields.HasFile)
+	require.False(t, fields.Stream)
+
+	_, err = ParseOpenAIAudioMultipart("application/json", body)
+	require.Error(t, err)
+}
+
+func TestRewriteAudioMultipartModel(t *testing.T) {
+	body, contentType := newAudioMultipartBody(t, "stt")
+	out, err := rewriteAudioMultipartModel(contentType, body, "gpt-4o-transcribe")
+	require.NoError(t, err)
+
+	fields, err := ParseOpenAIAudioMultipart(contentType, out)
+	require.NoError(t, err)
+	require.Equal(t, "gpt-4o-transcribe", fields.Model)
+	require.True(t, fields.HasFile)
+	require.Contains(t, string(out), "ID3\x00fake-audio")
+	require.Contains(t, string(out), "response_format")
+}
+
+func TestOpenAIGatewayService_ForwardAudioTranscription(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	upstream := &httpUpstreamRecorder{resp: newJSONResponseWithHeader(http.StatusOK,
+		`{"text":"hello","usage":{"type":"tokens","input_tokens":12,"output_tokens":3,"total_tokens":15}}`,
+		"Content-Type", "application/json")}
+	svc := &OpenAIGatewayService{cfg: &config.Config{}, httpUpstream: upstream}
+
+	body, contentType := newAudioMultipartBody(t, "stt")
+	rec := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(rec)
+	c.Request = httptest.NewRequest(http.MethodPost, "/v1/audio/transcriptions", bytes.NewReader(body))
+	c.Request.Header.Set("Content-Type", contentType)
+
+	account := newEmbeddingsTestAccount()
+	account.Credentials["model_mapping"] = map[string]any{"stt": "gpt-4o-transcribe"}
+	result, err := svc.ForwardAudioTranscription(c.Request.Context(), c, account, body, "", "")
+	require.NoError(t, err)
+	require.Equal(t, "https://example.com/v1/audio/transcriptions", upstream.lastReq.URL.String())
+	require.Equal(t, contentType, upstream.lastReq.Header.Get("Content-Type"))
+	require.Contains(t, string(upstream.lastBody), "gpt-4o-transcribe")
+
+	require.Equal(t, "stt", result.Model)
+	require.Equal(t, "gpt-4o-transcribe", result.BillingModel)
+	require.Equal(t, 12, result.Usage.InputTokens)
+	require.Equal(t, 3, result.Usage.OutputTokens)
+	require.JSONEq(t, `{"text":"hello","usage":{"type":"tokens","input_tokens":12,"output_tokens":3,"total_tokens":15}}`, rec.Body.String())
+}
+
+func TestOpenAIGatewayService_ForwardAudioSpeech_Binary(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	resp := &http.Response{
+		StatusCode: http.StatusOK,
+		Header:     http.Header{"Content-Type": []string{"audio/mpeg"}},
+		Body:       io.NopCloser(strings.NewReader("mp3-bytes")),
+	}
+	svc := &OpenAIGatewayService{cfg: &config.Config{}, httpUpstream: &httpUpstreamRecorder{resp: resp}}
+
+	rec := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(rec)
+	c.Request = httptest.NewRequest(http.MethodPost, "/v1/audio/speech", nil)
+
+	body := []byte(`{"model":"gpt-4o-mini-tts","input":"Hello there, how are you today?","voice":"alloy"}`)
+	result, err := svc.ForwardAudioSpeech(c.Request.Context(), c, newEmbeddingsTestAccount(), body, "", "")
+	require.NoError(t, err)
+	require.False(t, result.Stream)
+	require.Positive(t, result.Usage.InputTokens)
+	require.Equal(t, "audio/mpeg", rec.Header().Get("Content-Type"))
+	require.Equal(t, "mp3-bytes", rec.Body.String())
+}
+
+func TestOpenAIGatewayService_ForwardAudioSpeech_SSEUsage(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	stream := "data: {\"type\":\"speech.audio.delta\",\"audio\":\"AAA=\"}\n\n" +
+		"data: {\"type\":\"speech.audio.done\",\"usage\":{\"input_tokens\":7,\"output_tokens\":40,\"total_tokens\":47}}\n\n"
+	resp := &http.Response{
+		StatusCode: http.StatusOK,
+		Header:     http.Header{"Content-Type": []string{"text/event-stream"}},
+		Body:       io.NopCloser(strings.NewReader(stream)),
+	}
+	svc := &OpenAIGatewayService{cfg: &config.Config{}, httpUpstream: &httpUpstreamRecorder{resp: resp}}
+
+	rec := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(rec)
+	c.Request = httptest.NewRequest(http.MethodPost, "/v1/audio/speech", nil)
+
+	body := []byte(`{"model":"gpt-4o-mini-tts","input":"Hi","voice":"alloy","stream_format":"sse"}`)
+	result, err := svc.ForwardAudioSpeech(c.Request.Context(), c, newEmbeddingsTestAccount(), body, "", "")
+	require.NoError(t, err)
+	require.True(t, result.Stream)
+	require.NotNil(t, result.FirstTokenMs)
+	require.Equal(t, 7, result.Usage.InputTokens)
+	require.Equal(t, 40, result.Usage.OutputTokens)
+	require.Equal(t, stream, rec.Body.String())
+}
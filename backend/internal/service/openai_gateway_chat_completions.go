@@ -0,0 +1,436 @@
+package service
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/ShaohongDong/sub2api/internal/pkg/apicompat"
+	"github.com/ShaohongDong/sub2api/internal/pkg/logger"
+	"github.com/ShaohongDong/sub2api/internal/pkg/openai"
+	"github.com/ShaohongDong/sub2api/internal/pkg/tracing"
+	"github.com/ShaohongDong/sub2api/internal/util/responseheaders"
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+// ForwardAsChatCompletions accepts a Chat Completions request body, converts
+// it to OpenAI Responses API format, forwards to the OpenAI upstream, and
+// converts the response back into chat.completion / chat.completion.chunk
+// objects. The upstream is always streamed; the client's stream flag decides
+// the response format.
+func (s *OpenAIGatewayService) ForwardAsChatCompletions(
+	ctx context.Context,
+	c *gin.Context,
+	account *Account,
+	body []byte,
+	promptCacheKey string,
+	defaultMappedModel string,
+) (*OpenAIForwardResult, error) {
+	startTime := time.Now()
+
+	// 1. Parse Chat Completions request
+	var chatReq openai.ChatCompletionRequest
+	if err := json.Unmarshal(body, &chatReq); err != nil {
+		return nil, fmt.Errorf("parse chat completions request: %w", err)
+	}
+	originalModel := chatReq.Model
+	clientStream := chatReq.Stream
+
+	// 2. Convert Chat Completions → Responses
+	translateSpan := startTranslateSpan(ctx, "chat_completions", "responses")
+	responsesReq, err := openai.ChatCompletionsToResponses(&chatReq)
+	tracing.End(translateSpan, err)
+	if err != nil {
+		writeOpenAICompatError(c, http.StatusBadRequest, err.Error())
+		return nil, fmt.Errorf("convert chat completions to responses: %w", err)
+	}
+	responsesReq.Stream = true
+
+	// 3. Model mapping
+	mappedModel := resolveOpenAIMappedModel(account, originalModel, defaultMappedModel)
+	responsesReq.Model = mappedModel
+
+	// 上一账号流式输出中途断开：带上已输出的正文从断点续传，错误以 SSE 分片写入已开始的流
+	writeError := compatErrorWriter(writeOpenAICompatError)
+	if resume := openAIChatStreamResumeFromContext(c); resume != nil {
+		if err := openai.AppendResponsesContinuation(responsesReq, resume.state.EmittedText()); err != nil {
+			writeOpenAICompatStreamError(c, http.StatusInternalServerError, "Failed to resume stream")
+			return nil, fmt.Errorf("build stream continuation: %w", err)
+		}
+		writeError = writeOpenAICompatStreamError
+	}
+
+	logger.L().Debug("openai chat completions: model mapping applied",
+		zap.Int64("account_id", account.ID),
+		zap.String("original_model", originalModel),
+		zap.String("mapped_model", mappedModel),
+		zap.Bool("stream", clientStream),
+	)
+
+	// 4. Send upstream request (errors are written in OpenAI error format)
+	resp, err := s.doCompatResponsesRequest(ctx, c, account, responsesReq, promptCacheKey, writeError)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	// 5. Handle normal response
+	var result *OpenAIForwardResult
+	var handleErr error
+	if clientStream {
+		includeUsage := chatReq.StreamOptions != nil && chatReq.StreamOptions.IncludeUsage
+		result, handleErr = s.handleChatCompletionsStreamingResponse(resp, c, originalModel, mappedModel, includeUsage, startTime)
+	} else {
+		retry := s.chatCompletionsStructuredOutputRetry(ctx, c, account, responsesReq, promptCacheKey)
+		result, handleErr = s.handleChatCompletionsBufferedStreamingResponse(resp, c, originalModel, mappedModel, startTime, retry)
+	}
+
+	if handleErr == nil && result != nil {
+		if responsesReq.ServiceTier != "" {
+			st := responsesReq.ServiceTier
+			result.ServiceTier = &st
+		}
+		if responsesReq.Reasoning != nil && responsesReq.Reasoning.Effort != "" {
+			re := responsesReq.Reasoning.Effort
+			result.ReasoningEffort = &re
+		}
+	}
+
+	s.recordOpenAIRateLimitHeaders(account.ID, resp.Header)
+
+	// Extract and save Codex usage snapshot from response headers (for OAuth accounts)
+	if handleErr == nil && account.Type == AccountTypeOAuth {
+		if snapshot := ParseCodexRateLimitHeaders(resp.Header); snapshot != nil {
+			s.updateCodexUsageSnapshot(ctx, account.ID, snapshot)
+		}
+	}
+
+	return result, handleErr
+}
+
+// handleChatCompletionsBufferedStreamingResponse reads the upstream Responses
+// SSE stream until the terminal event and writes a single chat.completion.
+func (s *OpenAIGatewayService) handleChatCompletionsBufferedStreamingResponse(
+	resp *http.Response,
+	c *gin.Context,
+	originalModel string,
+	mappedModel string,
+	startTime time.Time,
+	retry *openAIStructuredOutputRetry,
+) (*OpenAIForwardResult, error) {
+	requestID := resp.Header.Get("x-request-id")
+
+	finalResponse, usage := s.collectResponsesStreamResponse(resp, "openai chat completions buffered")
+	if finalResponse == nil {
+		writeOpenAICompatError(c, http.StatusBadGateway, "Upstream stream ended without a terminal response event")
+		return nil, fmt.Errorf("upstream stream ended without terminal event")
+	}
+	finalResponse = retry.apply(finalResponse, &usage, "openai chat completions buffered")
+
+	if s.responseHeaderFilter != nil {
+		responseheaders.WriteFilteredHeaders(c.Writer.Header(), resp.Header, s.responseHeaderFilter)
+	}
+	c.JSON(http.StatusOK, openai.ResponsesToChatCompletion(finalResponse, originalModel, compatReasoningVisible(c)))
+
+	return &OpenAIForwardResult{
+		RequestID:    requestID,
+		Usage:        usage,
+		Model:        originalModel,
+		BillingModel: mappedModel,
+		Stream:       false,
+		Duration:     time.Since(startTime),
+	}, nil
+}
+
+// openAIStructuredOutputRetry re-sends a buffered compat request when the
+// structured output (text.format) fails validation. A nil retry only passes
+// the response through.
+type openAIStructuredOutputRetry struct {
+	s          *OpenAIGatewayService
+	format     *apicompat.ResponsesTextFormat
+	maxRetries int
+	resend     func() (*http.Response, error)
+}
+
+// chatCompletionsStructuredOutputRetry returns nil when the request does not
+// ask for JSON output.
+func (s *OpenAIGatewayService) chatCompletionsStructuredOutputRetry(
+	ctx context.Context,
+	c *gin.Context,
+	account *Account,
+	responsesReq *apicompat.ResponsesRequest,
+	promptCacheKey string,
+) *openAIStructuredOutputRetry {
+	format, _ := apicompat.ParseResponsesTextFormat(responsesReq.Text)
+	if !format.RequiresJSON() {
+		return nil
+	}
+	return &openAIStructuredOutputRetry{
+		s:          s,
+		format:     format,
+		maxRetries: structuredOutputMaxRetries(s.cfg),
+		resend: func() (*http.Response, error) {
+			return s.doCompatResponsesRequest(ctx, c, account, responsesReq, promptCacheKey, discardOpenAICompatError)
+		},
+	}
+}
+
+// apply validates resp and retries as configured, adding the usage of every
+// retry attempt to usage.
+func (r *openAIStructuredOutputRetry) apply(resp *apicompat.ResponsesResponse, usage *OpenAIUsage, logPrefix string) *apicompat.ResponsesResponse {
+	if r == nil {
+		return resp
+	}
+	return retryInvalidStructuredOutput(r.format, r.maxRetries, resp, func() (*apicompat.ResponsesResponse, error) {
+		upstream, err := r.resend()
+		if err != nil {
+			return nil, err
+		}
+		defer func() { _ = upstream.Body.Close() }()
+		next, nextUsage := r.s.collectResponsesStreamResponse(upstream, logPrefix)
+		if next == nil {
+			return nil, fmt.Errorf("upstream stream ended without terminal event")
+		}
+		usage.InputTokens += nextUsage.InputTokens
+		usage.OutputTokens += nextUsage.OutputTokens
+		usage.CacheCreationInputTokens += nextUsage.CacheCreationInputTokens
+		usage.CacheReadInputTokens += nextUsage.CacheReadInputTokens
+		return next, nil
+	}, logPrefix)
+}
+
+// collectResponsesStreamResponse reads an upstream Responses SSE stream until
+// the terminal event. It returns nil when the stream ended without one.
+func (s *OpenAIGatewayService) collectResponsesStreamResponse(resp *http.Response, logPrefix string) (*apicompat.ResponsesResponse, OpenAIUsage) {
+	requestID := resp.Header.Get("x-request-id")
+
+	scanner := bufio.NewScanner(resp.Body)
+	maxLineSize := defaultMaxLineSize
+	if s.cfg != nil && s.cfg.Gateway.MaxLineSize > 0 {
+		maxLineSize = s.cfg.Gateway.MaxLineSize
+	}
+	scanner.Buffer(make([]byte, 0, 64*1024), maxLineSize)
+
+	var finalResponse *apicompat.ResponsesResponse
+	var usage OpenAIUsage
+
+	for scanner.Scan() {
+		line := scanner.Text()
+		if !strings.HasPrefix(line, "data: ") || line == "data: [DONE]" {
+			continue
+		}
+
+		var event apicompat.ResponsesStreamEvent
+		if err := json.Unmarshal([]byte(line[6:]), &event); err != nil {
+			logger.L().Warn(logPrefix+": failed to parse event",
+				zap.Error(err),
+				zap.String("request_id", requestID),
+			)
+			continue
+		}
+
+		if isResponsesTerminalEvent(event.Type) && event.Response != nil {
+			finalResponse = event.Response
+			usage = openAIUsageFromResponses(event.Response.Usage)
+		}
+	}
+
+	if err := scanner.Err(); err != nil {
+		if !errors.Is(err, context.Canceled) && !errors.Is(err, context.DeadlineExceeded) {
+			logger.L().Warn(logPrefix+": read error",
+				zap.Error(err),
+				zap.String("request_id", requestID),
+			)
+		}
+	}
+
+	return finalResponse, usage
+}
+
+// handleChatCompletionsStreamingResponse converts upstream Responses SSE
+// events into chat.completion.chunk SSE events, terminated by data: [DONE].
+func (s *OpenAIGatewayService) handleChatCompletionsStreamingResponse(
+	resp *http.Response,
+	c *gin.Context,
+	originalModel string,
+	mappedModel string,
+	includeUsage bool,
+	startTime time.Time,
+) (*OpenAIForwardResult, error) {
+	requestID := resp.Header.Get("x-request-id")
+
+	// 续传时沿用已下发的流状态（chunk id、role、工具调用序号），响应头已写出
+	var state *openai.ResponsesEventToChatState
+	if resume := openAIChatStreamResumeFromContext(c); resume != nil {
+		state = resume.state
+	} else {
+		if s.responseHeaderFilter != nil {
+			responseheaders.WriteFilteredHeaders(c.Writer.Header(), resp.Header, s.responseHeaderFilter)
+		}
+		c.Writer.Header().Set("Content-Type", "text/event-stream")
+		c.Writer.Header().Set("Cache-Control", "no-cache")
+		c.Writer.Header().Set("Connection", "keep-alive")
+		c.Writer.Header().Set("X-Accel-Buffering", "no")
+		c.Writer.WriteHeader(http.StatusOK)
+
+		state = openai.NewResponsesEventToChatState()
+		state.Model = originalModel
+		state.IncludeUsage = includeUsage
+		state.IncludeReasoning = compatReasoningVisible(c)
+	}
+	sawDone := false
+	var usage OpenAIUsage
+	var firstTokenMs *int
+	firstChunk := true
+
+	resultWithUsage := func() *OpenAIForwardResult {
+		return &OpenAIForwardResult{
+			RequestID:    requestID,
+			Usage:        usage,
+			Model:        originalModel,
+			BillingModel: mappedModel,
+			Stream:       true,
+			Duration:     time.Since(startTime),
+			FirstTokenMs: firstTokenMs,
+		}
+	}
+
+	// writeChunks returns false when the client has disconnected.
+	writeChunks := func(chunks []openai.ChatCompletionChunk) bool {
+		for _, chunk := range chunks {
+			sse, err := openai.ChatChunkToSSE(chunk)
+			if err != nil {
+				continue
+			}
+			if _, err := fmt.Fprint(c.Writer, sse); err != nil {
+				logger.L().Info("openai chat completions stream: client disconnected",
+					zap.String("request_id", requestID),
+				)
+				return false
+			}
+		}
+		if len(chunks) > 0 {
+			c.Writer.Flush()
+		}
+		return true
+	}
+
+	scanner := bufio.NewScanner(resp.Body)
+	maxLineSize := defaultMaxLineSize
+	if s.cfg != nil && s.cfg.Gateway.MaxLineSize > 0 {
+		maxLineSize = s.cfg.Gateway.MaxLineSize
+	}
+	scanner.Buffer(make([]byte, 0, 64*1024), maxLineSize)
+
+	// 长时间推理期间没有可输出的分片时按 stream_keepalive_interval 发送 SSE 注释心跳
+	completed, err := forEachSSELine(scanner, c.Writer, streamKeepaliveInterval(s.cfg), sseCommentHeartbeat, func(line string) bool {
+		if line == "data: [DONE]" {
+			sawDone = true
+			return true
+		}
+		if !strings.HasPrefix(line, "data: ") {
+			return true
+		}
+		if firstChunk {
+			firstChunk = false
+			ms := int(time.Since(startTime).Milliseconds())
+			firstTokenMs = &ms
+		}
+
+		var event apicompat.ResponsesStreamEvent
+		if err := json.Unmarshal([]byte(line[6:]), &event); err != nil {
+			logger.L().Warn("openai chat completions stream: failed to parse event",
+				zap.Error(err),
+				zap.String("request_id", requestID),
+			)
+			return true
+		}
+		// 用量可能出现在终止事件的 response.usage 或事件顶层，任一处上报即记录
+		if u := openai.ResponsesStreamEventUsage(&event); u != nil {
+			usage = openAIUsageFromResponses(u)
+		}
+		return writeChunks(openai.ResponsesEventToChatChunks(&event, state))
+	})
+	if !completed {
+		return resultWithUsage(), nil
+	}
+	if err != nil && !errors.Is(err, context.Canceled) && !errors.Is(err, context.DeadlineExceeded) {
+		logger.L().Warn("openai chat completions stream: read error",
+			zap.Error(err),
+			zap.String("request_id", requestID),
+		)
+	}
+	if c.Request.Context().Err() != nil {
+		return resultWithUsage(), nil
+	}
+	if !state.Finished && !sawDone {
+		return resultWithUsage(), s.chatCompletionsStreamTruncated(c, state, requestID, err)
+	}
+
+	if !writeChunks(openai.FinalizeResponsesChatStream(state)) {
+		return resultWithUsage(), nil
+	}
+	fmt.Fprint(c.Writer, "data: [DONE]\n\n") //nolint:errcheck
+	c.Writer.Flush()
+	return resultWithUsage(), nil
+}
+
+// openAIChatStreamResumeKey 续传状态在 gin.Context 中的键。
+const openAIChatStreamResumeKey = "openai_chat_stream_resume"
+
+// openAIChatStreamResume 上游流式输出中途断开时已下发给客户端的流状态，
+// 换号重试时在同一客户端流上继续输出。
+type openAIChatStreamResume struct {
+	state    *openai.ResponsesEventToChatState
+	attempts int
+}
+
+func openAIChatStreamResumeFromContext(c *gin.Context) *openAIChatStreamResume {
+	if c == nil {
+		return nil
+	}
+	v, ok := c.Get(openAIChatStreamResumeKey)
+	if !ok {
+		return nil
+	}
+	resume, _ := v.(*openAIChatStreamResume)
+	return resume
+}
+
+// chatCompletionsStreamTruncated 处理上游在终止事件前断开的流。
+//
+// 尚未输出工具调用且未超过 gateway.stream_resume_max_attempts 时保存流状态并返回
+// *UpstreamFailoverError，由 handler 换号后带上已输出正文续传；否则写出错误分片，
+// 避免客户端把截断的输出当作正常结束。
+func (s *OpenAIGatewayService) chatCompletionsStreamTruncated(c *gin.Context, state *openai.ResponsesEventToChatState, requestID string, readErr error) error {
+	attempts := 0
+	if resume := openAIChatStreamResumeFromContext(c); resume != nil {
+		attempts = resume.attempts
+	}
+	maxAttempts := 0
+	if s.cfg != nil {
+		maxAttempts = s.cfg.Gateway.StreamResumeMaxAttempts
+	}
+	if !state.HasToolCall && attempts < maxAttempts {
+		c.Set(openAIChatStreamResumeKey, &openAIChatStreamResume{state: state, attempts: attempts + 1})
+		logger.L().Warn("openai chat completions stream: upstream truncated, resuming on another account",
+			zap.String("request_id", requestID),
+			zap.Int("emitted_chars", len(state.EmittedText())),
+			zap.Int("attempt", attempts+1),
+			zap.Error(readErr),
+		)
+		return &UpstreamFailoverError{StatusCode: http.StatusBadGateway}
+	}
+
+	writeOpenAICompatStreamError(c, http.StatusBadGateway, "Upstream stream ended without a terminal response event")
+	if readErr != nil {
+		return fmt.Errorf("upstream stream truncated: %w", readErr)
+	}
+	return errors.New("upstream stream truncated")
+}
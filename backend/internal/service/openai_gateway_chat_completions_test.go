@@ -0,0 +1,236 @@
+package service
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ShaohongDong/sub2api/internal/config"
+	"github.com/ShaohongDong/sub2api/internal/pkg/apicompat"
+	"github.com/ShaohongDong/sub2api/internal/pkg/clientdetect"
+	"github.com/ShaohongDong/sub2api/internal/pkg/openai"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/require"
+)
+
+func TestOpenAIGatewayService_HandleChatCompletionsStreamingResponse(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	svc := &OpenAIGatewayService{cfg: &config.Config{}}
+
+	rec := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(rec)
+	c.Request = httptest.NewRequest(http.MethodPost, "/v1/chat/completions", nil)
+
+	// 复用 Gemini 测试的上游 Responses SSE 流
+	result, err := svc.handleChatCompletionsStreamingResponse(newGeminiTestUpstreamResponse(), c, "gpt-4o", "gpt-5.1", true, time.Now())
+	require.NoError(t, err)
+	require.Equal(t, 3, result.Usage.InputTokens)
+	require.Equal(t, "gpt-5.1", result.BillingModel)
+	require.True(t, result.Stream)
+	require.Equal(t, "text/event-stream", rec.Header().Get("Content-Type"))
+
+	body := rec.Body.String()
+	require.Contains(t, body, `"object":"chat.completion.chunk"`)
+	require.Contains(t, body, `"content":"Hello"`)
+	require.Contains(t, body, `"finish_reason":"stop"`)
+	require.Contains(t, body, `"prompt_tokens":3`)
+	require.True(t, strings.HasSuffix(body, "data: [DONE]\n\n"))
+}
+
+func TestOpenAIGatewayService_HandleChatCompletionsStreamingResponse_TruncatedResume(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	svc := &OpenAIGatewayService{cfg: &config.Config{Gateway: config.GatewayConfig{StreamResumeMaxAttempts: 1}}}
+
+	rec := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(rec)
+	c.Request = httptest.NewRequest(http.MethodPost, "/v1/chat/completions", nil)
+
+	// 上游输出部分正文后断开，未发送终止事件
+	truncated := &http.Response{
+		StatusCode: http.StatusOK,
+		Header:     http.Header{},
+		Body: io.NopCloser(strings.NewReader("data: {\"type\":\"response.created\",\"response\":{\"id\":\"resp_1\"}}\n\n" +
+			"data: {\"type\":\"response.output_text.delta\",\"delta\":\"Hel\"}\n\n")),
+	}
+	_, err := svc.handleChatCompletionsStreamingResponse(truncated, c, "gpt-4o", "gpt-5.1", false, time.Now())
+	var failoverErr *UpstreamFailoverError
+	require.ErrorAs(t, err, &failoverErr)
+	resume := openAIChatStreamResumeFromContext(c)
+	require.NotNil(t, resume)
+	require.Equal(t, "Hel", resume.state.EmittedText())
+	require.NotContains(t, rec.Body.String(), "finish_reason\":\"stop")
+
+	// 换号续传：沿用同一 chunk id，不再重复 role 分片
+	_, err = svc.handleChatCompletionsStreamingResponse(newGeminiTestUpstreamResponse(), c, "gpt-4o", "gpt-5.1", false, time.Now())
+	require.NoError(t, err)
+	body := rec.Body.String()
+	require.Equal(t, 1, strings.Count(body, `"role":"assistant"`))
+	require.Equal(t, 1, strings.Count(body, `"finish_reason":"stop"`))
+	require.Contains(t, body, `"id":"chatcmpl-1"`)
+	require.True(t, strings.HasSuffix(body, "data: [DONE]\n\n"))
+
+	// 续传后再次断开且次数用尽：以错误分片结束流
+	rec = httptest.NewRecorder()
+	c, _ = gin.CreateTestContext(rec)
+	c.Request = httptest.NewRequest(http.MethodPost, "/v1/chat/completions", nil)
+	for i := 0; i < 2; i++ {
+		_, err = svc.handleChatCompletionsStreamingResponse(&http.Response{
+			StatusCode: http.StatusOK,
+			Header:     http.Header{},
+			Body:       io.NopCloser(strings.NewReader("data: {\"type\":\"response.output_text.delta\",\"delta\":\"x\"}\n\n")),
+		}, c, "gpt-4o", "gpt-5.1", false, time.Now())
+	}
+	require.Error(t, err)
+	require.False(t, errors.As(err, &failoverErr))
+	require.Contains(t, rec.Body.String(), `data: {"error":`)
+}
+
+func TestOpenAIGatewayService_HandleChatCompletionsStreamingResponse_TruncatedError(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	svc := &OpenAIGatewayService{cfg: &config.Config{}}
+
+	rec := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(rec)
+	c.Request = httptest.NewRequest(http.MethodPost, "/v1/chat/completions", nil)
+
+	truncated := &http.Response{
+		StatusCode: http.StatusOK,
+		Header:     http.Header{},
+		Body:       io.NopCloser(strings.NewReader("data: {\"type\":\"response.output_text.delta\",\"delta\":\"Hel\"}\n\n")),
+	}
+	_, err := svc.handleChatCompletionsStreamingResponse(truncated, c, "gpt-4o", "gpt-5.1", false, time.Now())
+	require.Error(t, err)
+	body := rec.Body.String()
+	require.NotContains(t, body, `"finish_reason":"stop"`)
+	require.True(t, strings.HasSuffix(body, "data: {\"error\":{\"code\":null,\"message\":\"Upstream stream ended without a terminal response event\",\"param\":null,\"type\":\"api_error\"}}\n\n"))
+}
+
+func TestOpenAIGatewayService_HandleChatCompletionsBufferedStreamingResponse(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	svc := &OpenAIGatewayService{cfg: &config.Config{}}
+
+	rec := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(rec)
+	c.Request = httptest.NewRequest(http.MethodPost, "/v1/chat/completions", nil)
+
+	result, err := svc.handleChatCompletionsBufferedStreamingResponse(newGeminiTestUpstreamResponse(), c, "gpt-4o", "gpt-5.1", time.Now(), nil)
+	require.NoError(t, err)
+	require.False(t, result.Stream)
+	require.Equal(t, 1, result.Usage.OutputTokens)
+
+	var completion openai.ChatCompletion
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &completion))
+	require.Equal(t, "chatcmpl-1", completion.ID)
+	require.Equal(t, "gpt-4o", completion.Model)
+	require.Len(t, completion.Choices, 1)
+	require.NotNil(t, completion.Choices[0].Message.Content)
+	require.Equal(t, "Hello", *completion.Choices[0].Message.Content)
+	require.Equal(t, "stop", completion.Choices[0].FinishReason)
+}
+
+func newResponsesTestUpstream(text string, outputTokens int) *http.Response {
+	completed := map[string]any{
+		"type": "response.completed",
+		"response": map[string]any{
+			"id":     "resp_1",
+			"object": "response",
+			"status": "completed",
+			"output": []any{map[string]any{
+				"type":    "message",
+				"role":    "assistant",
+				"content": []any{map[string]any{"type": "output_text", "text": text}},
+			}},
+			"usage": map[string]any{"input_tokens": 10, "output_tokens": outputTokens},
+		},
+	}
+	data, _ := json.Marshal(completed)
+	return &http.Response{
+		StatusCode: http.StatusOK,
+		Header:     http.Header{},
+		Body:       io.NopCloser(strings.NewReader("data: " + string(data) + "\n\n")),
+	}
+}
+
+func TestOpenAIGatewayService_HandleChatCompletionsBuffered_RetriesInvalidStructuredOutput(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	svc := &OpenAIGatewayService{cfg: &config.Config{}}
+
+	rec := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(rec)
+	c.Request = httptest.NewRequest(http.MethodPost, "/v1/chat/completions", nil)
+
+	resends := 0
+	retry := &openAIStructuredOutputRetry{
+		s: svc,
+		format: &apicompat.ResponsesTextFormat{
+			Type:   apicompat.TextFormatJSONSchema,
+			Schema: json.RawMessage(`{"type":"object","properties":{"city":{"type":"string"}},"required":["city"]}`),
+		},
+		maxRetries: 2,
+		resend: func() (*http.Response, error) {
+			resends++
+			return newResponsesTestUpstream(`{"city":"Paris"}`, 4), nil
+		},
+	}
+
+	result, err := svc.handleChatCompletionsBufferedStreamingResponse(newResponsesTestUpstream(`{"town":`, 3), c, "gpt-4o", "gpt-5.1", time.Now(), retry)
+	require.NoError(t, err)
+	require.Equal(t, 1, resends)
+	// 重试消耗的 token 一并计费
+	require.Equal(t, 20, result.Usage.InputTokens)
+	require.Equal(t, 7, result.Usage.OutputTokens)
+
+	var completion openai.ChatCompletion
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &completion))
+	require.Equal(t, `{"city":"Paris"}`, *completion.Choices[0].Message.Content)
+}
+
+func TestOpenAIGatewayService_HandleChatCompletionsBuffered_StructuredOutputRetriesExhausted(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	svc := &OpenAIGatewayService{cfg: &config.Config{}}
+
+	rec := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(rec)
+	c.Request = httptest.NewRequest(http.MethodPost, "/v1/chat/completions", nil)
+
+	resends := 0
+	retry := &openAIStructuredOutputRetry{
+		s:          svc,
+		format:     &apicompat.ResponsesTextFormat{Type: apicompat.TextFormatJSONObject},
+		maxRetries: 1,
+		resend: func() (*http.Response, error) {
+			resends++
+			return newResponsesTestUpstream("still not json", 2), nil
+		},
+	}
+
+	_, err := svc.handleChatCompletionsBufferedStreamingResponse(newResponsesTestUpstream("not json", 2), c, "gpt-4o", "gpt-5.1", time.Now(), retry)
+	require.NoError(t, err)
+	require.Equal(t, 1, resends)
+
+	var completion openai.ChatCompletion
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &completion))
+	require.Equal(t, "still not json", *completion.Choices[0].Message.Content)
+}
+
+func TestCompatReasoningVisible(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	newContext := func(clientType clientdetect.ClientType, suppress bool) *gin.Context {
+		c, _ := gin.CreateTestContext(httptest.NewRecorder())
+		req := httptest.NewRequest(http.MethodPost, "/v1/chat/completions", nil)
+		c.Request = req.WithContext(clientdetect.IntoContext(req.Context(), clientdetect.ClientInfo{Type: clientType}))
+		c.Set("api_key", &APIKey{ID: 1, SuppressReasoning: suppress})
+		return c
+	}
+
+	require.True(t, compatReasoningVisible(newContext(clientdetect.TypeOpenAISDK, false)))
+	require.False(t, compatReasoningVisible(newContext(clientdetect.TypeOpenAISDK, true)))
+	// 能渲染推理过程的客户端不受开关影响
+	require.True(t, compatReasoningVisible(newContext(clientdetect.TypeCodexCLI, true)))
+	require.True(t, compatReasoningVisible(newContext(clientdetect.TypeClaudeCode, true)))
+}
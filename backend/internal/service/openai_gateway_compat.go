@@ -0,0 +1,293 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/ShaohongDong/sub2api/internal/pkg/apicompat"
+	"github.com/ShaohongDong/sub2api/internal/pkg/clientdetect"
+	"github.com/gin-gonic/gin"
+)
+
+// compatErrorWriter 以客户端协议格式写出错误响应（Gemini / Chat Completions 等兼容入口）。
+type compatErrorWriter func(c *gin.Context, statusCode int, message string)
+
+// doCompatResponsesRequest 发送由其他协议转换而来的 Responses 请求：
+// OAuth 账号应用 codex 改写 → 获取 token → 构造请求 → 发送 → 错误处理。
+//
+// 成功时返回上游响应（调用方负责关闭 Body）；可 failover 的上游错误返回
+// *UpstreamFailoverError，其余错误已通过 writeError 写回客户端。
+func (s *OpenAIGatewayService) doCompatResponsesRequest(
+	ctx context.Context,
+	c *gin.Context,
+	account *Account,
+	responsesReq *apicompat.ResponsesRequest,
+	promptCacheKey string,
+	writeError compatErrorWriter,
+) (*http.Response, error) {
+	downscaleResponsesImages(s.cfg, responsesReq)
+	setDroppedParamsHeader(c, responsesReq.DroppedForResponses())
+	responsesBody, err := json.Marshal(responsesReq)
+	if err != nil {
+		return nil, fmt.Errorf("marshal responses request: %w", err)
+	}
+
+	if account.Type == AccountTypeOAuth {
+		var reqBody map[string]any
+		if err := json.Unmarshal(responsesBody, &reqBody); err != nil {
+			return nil, fmt.Errorf("unmarshal for codex transform: %w", err)
+		}
+		applyCodexInstructionsConfig(reqBody, false, s.codexInstructionsConfig())
+		codexResult := applyCodexOAuthTransform(reqBody, false, false)
+		if codexResult.PromptCacheKey != "" {
+			promptCacheKey = codexResult.PromptCacheKey
+		} else if promptCacheKey != "" {
+			reqBody["prompt_cache_key"] = promptCacheKey
+		}
+		responsesBody, err = json.Marshal(reqBody)
+		if err != nil {
+			return nil, fmt.Errorf("remarshal after codex transform: %w", err)
+		}
+	}
+
+	token, _, err := s.GetAccessToken(ctx, account)
+	if err != nil {
+		return nil, fmt.Errorf("get access token: %w", err)
+	}
+
+	upstreamReq, err := s.buildUpstreamRequest(ctx, c, account, responsesBody, token, responsesReq.Stream, promptCacheKey, false)
+	if err != nil {
+		return nil, fmt.Errorf("build upstream request: %w", err)
+	}
+	if promptCacheKey != "" {
+		upstreamReq.Header.Set("session_id", s.scopedOpenAISessionValue(account, generateSessionUUID(promptCacheKey)))
+	}
+
+	return s.doCompatUpstream(ctx, c, account, upstreamReq, writeError)
+}
+
+// doCompatUpstream 发送已构造好的上游请求并统一处理错误：
+// 成功（<400）时返回响应；可 failover 的错误返回 *UpstreamFailoverError，
+// 其余错误已通过 writeError 写回客户端。
+func (s *OpenAIGatewayService) doCompatUpstream(
+	ctx context.Context,
+	c *gin.Context,
+	account *Account,
+	upstreamReq *http.Request,
+	writeError compatErrorWriter,
+) (*http.Response, error) {
+	proxyURL := ""
+	if account.Proxy != nil {
+		proxyURL = account.Proxy.URL()
+	}
+	resp, err := s.sendOpenAIResponsesUpstream(upstreamReq, proxyURL, account)
+	if err != nil {
+		safeErr := sanitizeUpstreamErrorMessage(err.Error())
+		setOpsUpstreamError(c, 0, safeErr, "")
+		appendOpsUpstreamError(c, OpsUpstreamErrorEvent{
+			Platform:           account.Platform,
+			AccountID:          account.ID,
+			AccountName:        account.Name,
+			UpstreamStatusCode: 0,
+			Kind:               "request_error",
+			Message:            safeErr,
+		})
+		mapped := MapOpenAIRequestError(err)
+		writeError(c, mapped.Status, mapped.Message)
+		return nil, fmt.Errorf("upstream request failed: %s", safeErr)
+	}
+
+	if resp.StatusCode < 400 {
+		if c != nil {
+			writeCodexRateLimitHeaders(c.Writer.Header(), resp.Header)
+		}
+		return resp, nil
+	}
+	defer func() { _ = resp.Body.Close() }()
+	respBody, _ := io.ReadAll(io.LimitReader(resp.Body, 2<<20))
+
+	upstreamMsg := sanitizeUpstreamErrorMessage(strings.TrimSpace(extractUpstreamErrorMessage(respBody)))
+	upstreamDetail := ""
+	if s.cfg != nil && s.cfg.Gateway.LogUpstreamErrorBody {
+		maxBytes := s.cfg.Gateway.LogUpstreamErrorBodyMaxBytes
+		if maxBytes <= 0 {
+			maxBytes = 2048
+		}
+		upstreamDetail = truncateString(string(respBody), maxBytes)
+	}
+
+	if s.shouldFailoverUpstreamError(resp.StatusCode) {
+		appendOpsUpstreamError(c, OpsUpstreamErrorEvent{
+			Platform:           account.Platform,
+			AccountID:          account.ID,
+			AccountName:        account.Name,
+			UpstreamStatusCode: resp.StatusCode,
+			UpstreamRequestID:  resp.Header.Get("x-request-id"),
+			Kind:               "failover",
+			Message:            upstreamMsg,
+			Detail:             upstreamDetail,
+		})
+		if s.rateLimitService != nil {
+			s.rateLimitService.HandleUpstreamError(ctx, account, resp.StatusCode, resp.Header, respBody)
+		}
+		return nil, &UpstreamFailoverError{StatusCode: resp.StatusCode, ResponseBody: respBody}
+	}
+
+	if upstreamMsg == "" {
+		upstreamMsg = fmt.Sprintf("Upstream error: %d", resp.StatusCode)
+	}
+	setOpsUpstreamError(c, resp.StatusCode, upstreamMsg, upstreamDetail)
+
+	if status, _, errMsg, matched := applyErrorPassthroughRule(
+		c, account.Platform, resp.StatusCode, respBody,
+		http.StatusBadGateway, "api_error", "Upstream request failed",
+	); matched {
+		writeError(c, status, errMsg)
+		return nil, fmt.Errorf("upstream error: %d (passthrough rule matched) message=%s", resp.StatusCode, upstreamMsg)
+	}
+
+	// 上游原始消息可能包含账号标识或 HTML 错误页，转换后再写回客户端
+	normalized := NormalizeOpenAIUpstreamError(resp.StatusCode, respBody, account)
+	writeError(c, normalized.Status, normalized.Message)
+	return nil, fmt.Errorf("upstream error: %d %s", resp.StatusCode, upstreamMsg)
+}
+
+// SupportsOpenAIPlatformAPI 判断账号能否承接 Platform API 端点（embeddings / audio）：
+// ChatGPT OAuth 账号只能访问 Codex Responses 接口，仅 API Key 账号支持。
+func SupportsOpenAIPlatformAPI(account *Account) bool {
+	return account != nil && account.Type == AccountTypeAPIKey
+}
+
+// buildOpenAIPlatformURL 组装 API Key 账号的 Platform API 端点（path 形如 "/embeddings"），
+// 自定义 base_url 需通过校验；base 以 /v1 结尾时直接追加 path。
+func (s *OpenAIGatewayService) buildOpenAIPlatformURL(account *Account, path string) (string, error) {
+	baseURL := account.GetOpenAIBaseURL()
+	if baseURL == "" {
+		baseURL = "https://api.openai.com"
+	}
+	validatedURL, err := s.validateUpstreamBaseURL(baseURL)
+	if err != nil {
+		return "", err
+	}
+	normalized := strings.TrimRight(strings.TrimSpace(validatedURL), "/")
+	if strings.HasSuffix(normalized, path) {
+		return normalized, nil
+	}
+	if strings.HasSuffix(normalized, "/v1") {
+		return normalized + path, nil
+	}
+	return normalized + "/v1" + path, nil
+}
+
+// doOpenAIPlatformRequest 以 API Key 鉴权向 Platform API 发送请求体（透传白名单请求头），
+// 错误处理同 doCompatUpstream（OpenAI 错误格式）。
+func (s *OpenAIGatewayService) doOpenAIPlatformRequest(
+	ctx context.Context,
+	c *gin.Context,
+	account *Account,
+	targetURL string,
+	token string,
+	body []byte,
+	contentType string,
+) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, targetURL, bytes.NewReader(body))
+	if err != nil {
+		return nil, fmt.Errorf("build upstream request: %w", err)
+	}
+	req.Header.Set("authorization", "Bearer "+token)
+
+	// Whitelist passthrough headers
+	for key, values := range c.Request.Header {
+		if openaiAllowedHeaders[strings.ToLower(key)] {
+			for _, v := range values {
+				req.Header.Add(key, v)
+			}
+		}
+	}
+	if ua := s.resolveUpstreamUserAgent(ctx, account, req.Header.Get("user-agent")); ua != "" {
+		req.Header.Set("user-agent", ua)
+	}
+	req.Header.Set("content-type", contentType)
+	s.settingService.ApplyUpstreamHeaderPolicy(c, req, account)
+
+	return s.doCompatUpstream(ctx, c, account, req, writeOpenAICompatError)
+}
+
+// isResponsesTerminalEvent reports whether an event carries the final response.
+func isResponsesTerminalEvent(eventType string) bool {
+	return eventType == "response.completed" || eventType == "response.incomplete" || eventType == "response.failed"
+}
+
+// openAIUsageFromResponses converts Responses usage into OpenAIUsage.
+func openAIUsageFromResponses(u *apicompat.ResponsesUsage) OpenAIUsage {
+	if u == nil {
+		return OpenAIUsage{}
+	}
+	usage := OpenAIUsage{
+		InputTokens:  u.InputTokens,
+		OutputTokens: u.OutputTokens,
+	}
+	if u.InputTokensDetails != nil {
+		usage.CacheReadInputTokens = u.InputTokensDetails.CachedTokens
+	}
+	if u.OutputTokensDetails != nil {
+		usage.ReasoningTokens = u.OutputTokensDetails.ReasoningTokens
+	}
+	return usage
+}
+
+// compatReasoningVisible 判断协议转换时是否向下游输出推理内容（reasoning_content / thinking 块）。
+//
+// Codex 与 Claude Code 客户端能渲染推理过程，始终输出；其他客户端在 API Key 开启
+// suppress_reasoning 时丢弃，避免普通 API 调用方收到无法识别的字段。
+func compatReasoningVisible(c *gin.Context) bool {
+	if c == nil {
+		return true
+	}
+	if c.Request != nil {
+		if info, ok := clientdetect.FromContext(c.Request.Context()); ok && (info.IsCodex() || info.IsClaudeCode()) {
+			return true
+		}
+	}
+	v, exists := c.Get("api_key")
+	if !exists {
+		return true
+	}
+	apiKey, ok := v.(*APIKey)
+	return !ok || apiKey == nil || !apiKey.SuppressReasoning
+}
+
+// writeOpenAICompatError writes an error response in OpenAI API format.
+func writeOpenAICompatError(c *gin.Context, statusCode int, message string) {
+	WriteOpenAIError(c, OpenAIClientError{Status: statusCode, Type: openAICompatErrorType(statusCode), Message: message})
+}
+
+// writeOpenAICompatStreamError 在已开始输出的 SSE 流中以 data 行写出 OpenAI 格式错误，
+// 客户端 SDK 据此抛出异常而不是把截断的输出当作正常结束。
+func writeOpenAICompatStreamError(c *gin.Context, statusCode int, message string) {
+	data, err := json.Marshal(OpenAIClientError{Type: openAICompatErrorType(statusCode), Message: message}.Body())
+	if err != nil {
+		return
+	}
+	if _, err := fmt.Fprintf(c.Writer, "data: %s\n\n", data); err != nil {
+		return
+	}
+	c.Writer.Flush()
+}
+
+func openAICompatErrorType(statusCode int) string {
+	switch statusCode {
+	case http.StatusBadRequest:
+		return "invalid_request_error"
+	case http.StatusNotFound:
+		return "not_found_error"
+	case http.StatusTooManyRequests:
+		return "rate_limit_error"
+	}
+	return "api_error"
+}
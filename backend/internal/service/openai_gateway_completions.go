@@ -0,0 +1,293 @@
+package service
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/ShaohongDong/sub2api/internal/pkg/apicompat"
+	"github.com/ShaohongDong/sub2api/internal/pkg/logger"
+	"github.com/ShaohongDong/sub2api/internal/pkg/openai"
+	"github.com/ShaohongDong/sub2api/internal/util/responseheaders"
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+// ForwardAsCompletions accepts a legacy /v1/completions request body, wraps
+// the prompt into a Responses API call, forwards to the OpenAI upstream, and
+// converts the response back into text_completion objects (honouring echo and
+// logprobs). The upstream is always streamed; the client's stream flag
+// decides the response format.
+func (s *OpenAIGatewayService) ForwardAsCompletions(
+	ctx context.Context,
+	c *gin.Context,
+	account *Account,
+	body []byte,
+	promptCacheKey string,
+	defaultMappedModel string,
+) (*OpenAIForwardResult, error) {
+	startTime := time.Now()
+
+	// 1. Parse Completions request
+	var complReq openai.CompletionRequest
+	if err := json.Unmarshal(body, &complReq); err != nil {
+		return nil, fmt.Errorf("parse completions request: %w", err)
+	}
+	originalModel := complReq.Model
+	clientStream := complReq.Stream
+
+	// 2. Convert Completions → Responses
+	responsesReq, err := openai.CompletionsToResponses(&complReq)
+	if err != nil {
+		writeOpenAICompatError(c, http.StatusBadRequest, err.Error())
+		return nil, fmt.Errorf("convert completions to responses: %w", err)
+	}
+	responsesReq.Stream = true
+	prompt, _ := openai.ParseCompletionPrompt(complReq.Prompt)
+	opts := openai.CompletionOptions{
+		Echo:     complReq.Echo,
+		Prompt:   prompt,
+		Logprobs: complReq.Logprobs != nil,
+	}
+
+	// 3. Model mapping
+	mappedModel := resolveOpenAIMappedModel(account, originalModel, defaultMappedModel)
+	responsesReq.Model = mappedModel
+
+	logger.L().Debug("openai completions: model mapping applied",
+		zap.Int64("account_id", account.ID),
+		zap.String("original_model", originalModel),
+		zap.String("mapped_model", mappedModel),
+		zap.Bool("stream", clientStream),
+	)
+
+	// 4. Send upstream request (errors are written in OpenAI error format)
+	resp, err := s.doCompatResponsesRequest(ctx, c, account, responsesReq, promptCacheKey, writeOpenAICompatError)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	// 5. Handle normal response
+	var result *OpenAIForwardResult
+	var handleErr error
+	if clientStream {
+		includeUsage := complReq.StreamOptions != nil && complReq.StreamOptions.IncludeUsage
+		result, handleErr = s.handleCompletionsStreamingResponse(resp, c, originalModel, mappedModel, opts, includeUsage, startTime)
+	} else {
+		result, handleErr = s.handleCompletionsBufferedStreamingResponse(resp, c, originalModel, mappedModel, opts, startTime)
+	}
+
+	s.recordOpenAIRateLimitHeaders(account.ID, resp.Header)
+
+	// Extract and save Codex usage snapshot from response headers (for OAuth accounts)
+	if handleErr == nil && account.Type == AccountTypeOAuth {
+		if snapshot := ParseCodexRateLimitHeaders(resp.Header); snapshot != nil {
+			s.updateCodexUsageSnapshot(ctx, account.ID, snapshot)
+		}
+	}
+
+	return result, handleErr
+}
+
+// handleCompletionsBufferedStreamingResponse reads the upstream Responses SSE
+// stream until the terminal event and writes a single text_completion.
+func (s *OpenAIGatewayService) handleCompletionsBufferedStreamingResponse(
+	resp *http.Response,
+	c *gin.Context,
+	originalModel string,
+	mappedModel string,
+	opts openai.CompletionOptions,
+	startTime time.Time,
+) (*OpenAIForwardResult, error) {
+	requestID := resp.Header.Get("x-request-id")
+
+	scanner := bufio.NewScanner(resp.Body)
+	maxLineSize := defaultMaxLineSize
+	if s.cfg != nil && s.cfg.Gateway.MaxLineSize > 0 {
+		maxLineSize = s.cfg.Gateway.MaxLineSize
+	}
+	scanner.Buffer(make([]byte, 0, 64*1024), maxLineSize)
+
+	var finalResponse *apicompat.ResponsesResponse
+	var usage OpenAIUsage
+
+	for scanner.Scan() {
+		line := scanner.Text()
+		if !strings.HasPrefix(line, "data: ") || line == "data: [DONE]" {
+			continue
+		}
+
+		var event apicompat.ResponsesStreamEvent
+		if err := json.Unmarshal([]byte(line[6:]), &event); err != nil {
+			logger.L().Warn("openai completions buffered: failed to parse event",
+				zap.Error(err),
+				zap.String("request_id", requestID),
+			)
+			continue
+		}
+
+		if isResponsesTerminalEvent(event.Type) && event.Response != nil {
+			finalResponse = event.Response
+			usage = openAIUsageFromResponses(event.Response.Usage)
+		}
+	}
+
+	if err := scanner.Err(); err != nil {
+		if !errors.Is(err, context.Canceled) && !errors.Is(err, context.DeadlineExceeded) {
+			logger.L().Warn("openai completions buffered: read error",
+				zap.Error(err),
+				zap.String("request_id", requestID),
+			)
+		}
+	}
+
+	if finalResponse == nil {
+		writeOpenAICompatError(c, http.StatusBadGateway, "Upstream stream ended without a terminal response event")
+		return nil, fmt.Errorf("upstream stream ended without terminal event")
+	}
+
+	if s.responseHeaderFilter != nil {
+		responseheaders.WriteFilteredHeaders(c.Writer.Header(), resp.Header, s.responseHeaderFilter)
+	}
+	c.JSON(http.StatusOK, openai.ResponsesToCompletion(finalResponse, originalModel, opts))
+
+	return &OpenAIForwardResult{
+		RequestID:    requestID,
+		Usage:        usage,
+		Model:        originalModel,
+		BillingModel: mappedModel,
+		Stream:       false,
+		Duration:     time.Since(startTime),
+	}, nil
+}
+
+// handleCompletionsStreamingResponse converts upstream Responses SSE events
+// into text_completion SSE chunks, terminated by data: [DONE].
+func (s *OpenAIGatewayService) handleCompletionsStreamingResponse(
+	resp *http.Response,
+	c *gin.Context,
+	originalModel string,
+	mappedModel string,
+	opts openai.CompletionOptions,
+	includeUsage bool,
+	startTime time.Time,
+) (*OpenAIForwardResult, error) {
+	requestID := resp.Header.Get("x-request-id")
+
+	if s.responseHeaderFilter != nil {
+		responseheaders.WriteFilteredHeaders(c.Writer.Header(), resp.Header, s.responseHeaderFilter)
+	}
+	c.Writer.Header().Set("Content-Type", "text/event-stream")
+	c.Writer.Header().Set("Cache-Control", "no-cache")
+	c.Writer.Header().Set("Connection", "keep-alive")
+	c.Writer.Header().Set("X-Accel-Buffering", "no")
+	c.Writer.WriteHeader(http.StatusOK)
+
+	state := openai.NewResponsesEventToCompletionState()
+	state.Model = originalModel
+	state.IncludeUsage = includeUsage
+	state.Options = opts
+	var usage OpenAIUsage
+	var firstTokenMs *int
+	firstChunk := true
+
+	resultWithUsage := func() *OpenAIForwardResult {
+		return &OpenAIForwardResult{
+			RequestID:    requestID,
+			Usage:        usage,
+			Model:        originalModel,
+			BillingModel: mappedModel,
+			Stream:       true,
+			Duration:     time.Since(startTime),
+			FirstTokenMs: firstTokenMs,
+		}
+	}
+
+	// writeChunks returns false when the client has disconnected.
+	writeChunks := func(chunks []openai.Completion) bool {
+		for _, chunk := range chunks {
+			sse, err := openai.CompletionChunkToSSE(chunk)
+			if err != nil {
+				continue
+			}
+			if _, err := fmt.Fprint(c.Writer, sse); err != nil {
+				logger.L().Info("openai completions stream: client disconnected",
+					zap.String("request_id", requestID),
+				)
+				return false
+			}
+		}
+		if len(chunks) > 0 {
+			c.Writer.Flush()
+		}
+		return true
+	}
+
+	scanner := bufio.NewScanner(resp.Body)
+	maxLineSize := defaultMaxLineSize
+	if s.cfg != nil && s.cfg.Gateway.MaxLineSize > 0 {
+		maxLineSize = s.cfg.Gateway.MaxLineSize
+	}
+	scanner.Buffer(make([]byte, 0, 64*1024), maxLineSize)
+
+	sawDone := false
+	completed, err := forEachSSELine(scanner, c.Writer, streamKeepaliveInterval(s.cfg), sseCommentHeartbeat, func(line string) bool {
+		if line == "data: [DONE]" {
+			sawDone = true
+			return true
+		}
+		if !strings.HasPrefix(line, "data: ") {
+			return true
+		}
+		if firstChunk {
+			firstChunk = false
+			ms := int(time.Since(startTime).Milliseconds())
+			firstTokenMs = &ms
+		}
+
+		var event apicompat.ResponsesStreamEvent
+		if err := json.Unmarshal([]byte(line[6:]), &event); err != nil {
+			logger.L().Warn("openai completions stream: failed to parse event",
+				zap.Error(err),
+				zap.String("request_id", requestID),
+			)
+			return true
+		}
+		// 用量可能出现在终止事件的 response.usage 或事件顶层，任一处上报即记录
+		if u := openai.ResponsesStreamEventUsage(&event); u != nil {
+			usage = openAIUsageFromResponses(u)
+		}
+		return writeChunks(openai.ResponsesEventToCompletionChunks(&event, state))
+	})
+	if !completed {
+		return resultWithUsage(), nil
+	}
+	if err != nil && !errors.Is(err, context.Canceled) && !errors.Is(err, context.DeadlineExceeded) {
+		logger.L().Warn("openai completions stream: read error",
+			zap.Error(err),
+			zap.String("request_id", requestID),
+		)
+	}
+
+	if c.Request.Context().Err() != nil {
+		return resultWithUsage(), nil
+	}
+	if !state.Finished && !sawDone {
+		// 上游在终止事件前断开：写出错误分片，避免客户端把截断的输出当作正常结束
+		writeOpenAICompatStreamError(c, http.StatusBadGateway, "Upstream stream ended without a terminal response event")
+		return resultWithUsage(), errors.New("upstream stream truncated")
+	}
+
+	if !writeChunks(openai.FinalizeResponsesCompletionStream(state)) {
+		return resultWithUsage(), nil
+	}
+	fmt.Fprint(c.Writer, "data: [DONE]\n\n") //nolint:errcheck
+	c.Writer.Flush()
+	return resultWithUsage(), nil
+}
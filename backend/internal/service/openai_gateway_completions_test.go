@@ -0,0 +1,57 @@
+package service
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ShaohongDong/sub2api/internal/config"
+	"github.com/ShaohongDong/sub2api/internal/pkg/openai"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/require"
+)
+
+func TestOpenAIGatewayService_HandleCompletionsStreamingResponse_Echo(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	svc := &OpenAIGatewayService{cfg: &config.Config{}}
+
+	rec := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(rec)
+	c.Request = httptest.NewRequest(http.MethodPost, "/v1/completions", nil)
+
+	// 复用 Gemini 测试的上游 Responses SSE 流
+	opts := openai.CompletionOptions{Echo: true, Prompt: "Say: "}
+	result, err := svc.handleCompletionsStreamingResponse(newGeminiTestUpstreamResponse(), c, "gpt-5.1", "gpt-5.1", opts, false, time.Now())
+	require.NoError(t, err)
+	require.True(t, result.Stream)
+	require.Equal(t, 3, result.Usage.InputTokens)
+
+	body := rec.Body.String()
+	require.Contains(t, body, `"object":"text_completion"`)
+	require.Less(t, strings.Index(body, `"text":"Say: "`), strings.Index(body, `"text":"Hello"`))
+	require.Contains(t, body, `"finish_reason":"stop"`)
+	require.True(t, strings.HasSuffix(body, "data: [DONE]\n\n"))
+}
+
+func TestOpenAIGatewayService_HandleCompletionsBufferedStreamingResponse(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	svc := &OpenAIGatewayService{cfg: &config.Config{}}
+
+	rec := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(rec)
+	c.Request = httptest.NewRequest(http.MethodPost, "/v1/completions", nil)
+
+	result, err := svc.handleCompletionsBufferedStreamingResponse(newGeminiTestUpstreamResponse(), c, "gpt-5.1", "gpt-5.1", openai.CompletionOptions{Logprobs: true}, time.Now())
+	require.NoError(t, err)
+	require.False(t, result.Stream)
+
+	var out openai.Completion
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &out))
+	require.Equal(t, "cmpl-1", out.ID)
+	require.Len(t, out.Choices, 1)
+	require.Equal(t, "Hello", out.Choices[0].Text)
+	require.NotNil(t, out.Choices[0].Logprobs)
+}
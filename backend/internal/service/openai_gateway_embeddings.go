@@ -0,0 +1,211 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/ShaohongDong/sub2api/internal/pkg/logger"
+	"github.com/ShaohongDong/sub2api/internal/util/responseheaders"
+	"github.com/gin-gonic/gin"
+	"github.com/tidwall/gjson"
+	"github.com/tidwall/sjson"
+	"go.uber.org/zap"
+)
+
+// openAIEmbeddingsMaxBatchInputs 上游单次请求允许的最大 input 条数，超出时网关拆分为多次请求。
+const openAIEmbeddingsMaxBatchInputs = 2048
+
+// openAIEmbeddingsResponse 是 /v1/embeddings 响应中网关需要合并的字段。
+type openAIEmbeddingsResponse struct {
+	Object string                  `json:"object"`
+	Data   []openAIEmbeddingsDatum `json:"data"`
+	Model  string                  `json:"model"`
+	Usage  openAIEmbeddingsUsage   `json:"usage"`
+}
+
+type openAIEmbeddingsDatum struct {
+	Object    string          `json:"object"`
+	Index     int             `json:"index"`
+	Embedding json.RawMessage `json:"embedding"` // float 数组或 base64 字符串
+}
+
+type openAIEmbeddingsUsage struct {
+	PromptTokens int `json:"prompt_tokens"`
+	TotalTokens  int `json:"total_tokens"`
+}
+
+// ForwardEmbeddings 将 /v1/embeddings 请求透传到 API Key 账号的上游。
+// 应用模型映射；input 数组超过上游单次上限时拆分为多次请求，
+// 合并 data（按原始顺序重排 index）并累加 usage 后返回给客户端。
+func (s *OpenAIGatewayService) ForwardEmbeddings(
+	ctx context.Context,
+	c *gin.Context,
+	account *Account,
+	body []byte,
+	_ string,
+	defaultMappedModel string,
+) (*OpenAIForwardResult, error) {
+	startTime := time.Now()
+
+	if !SupportsOpenAIPlatformAPI(account) {
+		writeOpenAICompatError(c, http.StatusBadRequest, "Embeddings are not supported by this account type")
+		return nil, fmt.Errorf("embeddings not supported for account type %s", account.Type)
+	}
+
+	// 1. Model mapping
+	originalModel := gjson.GetBytes(body, "model").String()
+	mappedModel := resolveOpenAIMappedModel(account, originalModel, defaultMappedModel)
+	if mappedModel != originalModel {
+		var err error
+		body, err = sjson.SetBytes(body, "model", mappedModel)
+		if err != nil {
+			return nil, fmt.Errorf("set mapped model: %w", err)
+		}
+	}
+
+	logger.L().Debug("openai embeddings: model mapping applied",
+		zap.Int64("account_id", account.ID),
+		zap.String("original_model", originalModel),
+		zap.String("mapped_model", mappedModel),
+	)
+
+	// 2. Build upstream URL and token
+	targetURL, err := s.buildOpenAIPlatformURL(account, "/embeddings")
+	if err != nil {
+		return nil, fmt.Errorf("build upstream url: %w", err)
+	}
+	token, _, err := s.GetAccessToken(ctx, account)
+	if err != nil {
+		return nil, fmt.Errorf("get access token: %w", err)
+	}
+
+	// 3. Split oversized inputs into batches
+	batches, err := splitEmbeddingsInput(body, openAIEmbeddingsMaxBatchInputs)
+	if err != nil {
+		writeOpenAICompatError(c, http.StatusBadRequest, err.Error())
+		return nil, fmt.Errorf("split embeddings input: %w", err)
+	}
+
+	// 4. Single batch: passthrough the upstream body unchanged
+	if len(batches) == 1 {
+		resp, err := s.doOpenAIPlatformRequest(ctx, c, account, targetURL, token, batches[0], "application/json")
+		if err != nil {
+			return nil, err
+		}
+		defer func() { _ = resp.Body.Close() }()
+
+		respBody, err := io.ReadAll(resp.Body)
+		if err != nil {
+			return nil, fmt.Errorf("read upstream body: %w", err)
+		}
+		if s.responseHeaderFilter != nil {
+			responseheaders.WriteFilteredHeaders(c.Writer.Header(), resp.Header, s.responseHeaderFilter)
+		}
+		contentType := resp.Header.Get("Content-Type")
+		if contentType == "" {
+			contentType = "application/json"
+		}
+		c.Data(resp.StatusCode, contentType, respBody)
+
+		return &OpenAIForwardResult{
+			RequestID:    resp.Header.Get("x-request-id"),
+			Usage:        OpenAIUsage{InputTokens: int(gjson.GetBytes(respBody, "usage.prompt_tokens").Int())},
+			Model:        originalModel,
+			BillingModel: mappedModel,
+			Duration:     time.Since(startTime),
+		}, nil
+	}
+
+	// 5. Multiple batches: send sequentially and merge
+	merged := openAIEmbeddingsResponse{Object: "list", Data: []openAIEmbeddingsDatum{}}
+	requestID := ""
+	var upstreamHeader http.Header
+	for i, batch := range batches {
+		resp, err := s.doOpenAIPlatformRequest(ctx, c, account, targetURL, token, batch, "application/json")
+		if err != nil {
+			return nil, err
+		}
+		respBody, err := io.ReadAll(resp.Body)
+		_ = resp.Body.Close()
+		if err != nil {
+			return nil, fmt.Errorf("read upstream body: %w", err)
+		}
+		var part openAIEmbeddingsResponse
+		if err := json.Unmarshal(respBody, &part); err != nil {
+			writeOpenAICompatError(c, http.StatusBadGateway, "Failed to parse upstream response")
+			return nil, fmt.Errorf("parse embeddings batch %d: %w", i, err)
+		}
+		offset := len(merged.Data)
+		for _, d := range part.Data {
+			d.Index += offset
+			merged.Data = append(merged.Data, d)
+		}
+		merged.Usage.PromptTokens += part.Usage.PromptTokens
+		merged.Usage.TotalTokens += part.Usage.TotalTokens
+		if merged.Model == "" {
+			merged.Model = part.Model
+		}
+		if requestID == "" {
+			requestID = resp.Header.Get("x-request-id")
+			upstreamHeader = resp.Header
+		}
+	}
+
+	if s.responseHeaderFilter != nil && upstreamHeader != nil {
+		responseheaders.WriteFilteredHeaders(c.Writer.Header(), upstreamHeader, s.responseHeaderFilter)
+	}
+	c.JSON(http.StatusOK, merged)
+
+	return &OpenAIForwardResult{
+		RequestID:    requestID,
+		Usage:        OpenAIUsage{InputTokens: merged.Usage.PromptTokens},
+		Model:        originalModel,
+		BillingModel: mappedModel,
+		Duration:     time.Since(startTime),
+	}, nil
+}
+
+// splitEmbeddingsInput 按 maxInputs 拆分请求体中的 input 数组。
+// 字符串或单个 token 数组（整数数组）视为一条输入，不拆分。
+func splitEmbeddingsInput(body []byte, maxInputs int) ([][]byte, error) {
+	input := gjson.GetBytes(body, "input")
+	if !input.Exists() {
+		return nil, fmt.Errorf("input is required")
+	}
+	if !input.IsArray() {
+		return [][]byte{body}, nil
+	}
+	items := input.Array()
+	if len(items) == 0 {
+		return nil, fmt.Errorf("input must not be empty")
+	}
+	if items[0].Type == gjson.Number || len(items) <= maxInputs {
+		return [][]byte{body}, nil
+	}
+
+	batches := make([][]byte, 0, (len(items)+maxInputs-1)/maxInputs)
+	for start := 0; start < len(items); start += maxInputs {
+		end := start + maxInputs
+		if end > len(items) {
+			end = len(items)
+		}
+		raws := make([]json.RawMessage, 0, end-start)
+		for _, item := range items[start:end] {
+			raws = append(raws, json.RawMessage(item.Raw))
+		}
+		chunk, err := json.Marshal(raws)
+		if err != nil {
+			return nil, err
+		}
+		batch, err := sjson.SetRawBytes(body, "input", chunk)
+		if err != nil {
+			return nil, err
+		}
+		batches = append(batches, batch)
+	}
+	return batches, nil
+}
@@ -0,0 +1,151 @@
+package service
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/ShaohongDong/sub2api/internal/config"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/require"
+	"github.com/tidwall/gjson"
+)
+
+// embeddingsUpstreamStub 按请求中的 input 条数返回等量的 embedding，每条计 1 个 token。
+type embeddingsUpstreamStub struct {
+	requests []*http.Request
+	bodies   [][]byte
+}
+
+func (u *embeddingsUpstreamStub) Do(req *http.Request, _ string, _ int64, _ int) (*http.Response, error) {
+	body, _ := io.ReadAll(req.Body)
+	u.requests = append(u.requests, req)
+	u.bodies = append(u.bodies, body)
+
+	n := len(gjson.GetBytes(body, "input").Array())
+	data := make([]string, 0, n)
+	for i := 0; i < n; i++ {
+		data = append(data, fmt.Sprintf(`{"object":"embedding","index":%d,"embedding":[0.%d]}`, i, i))
+	}
+	respBody := fmt.Sprintf(`{"object":"list","data":[%s],"model":%q,"usage":{"prompt_tokens":%d,"total_tokens":%d}}`,
+		strings.Join(data, ","), gjson.GetBytes(body, "model").String(), n, n)
+	resp := newJSONResponseWithHeader(http.StatusOK, respBody, "x-request-id", fmt.Sprintf("req_%d", len(u.requests)))
+	resp.Header.Set("Content-Type", "application/json")
+	return resp, nil
+}
+
+func (u *embeddingsUpstreamStub) DoWithTLS(req *http.Request, proxyURL string, accountID int64, accountConcurrency int, _ bool) (*http.Response, error) {
+	return u.Do(req, proxyURL, accountID, accountConcurrency)
+}
+
+func newEmbeddingsTestAccount() *Account {
+	return &Account{
+		ID:       1,
+		Platform: PlatformOpenAI,
+		Type:     AccountTypeAPIKey,
+		Credentials: map[string]any{
+			"api_key":  "sk-test",
+			"base_url": "https://example.com/v1",
+			"model_mapping": map[string]any{
+				"embed-small": "text-embedding-3-small",
+			},
+		},
+	}
+}
+
+func TestOpenAIGatewayService_ForwardEmbeddings_Passthrough(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	upstream := &embeddingsUpstreamStub{}
+	svc := &OpenAIGatewayService{cfg: &config.Config{}, httpUpstream: upstream}
+
+	rec := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(rec)
+	c.Request = httptest.NewRequest(http.MethodPost, "/v1/embeddings", nil)
+
+	body := []byte(`{"model":"embed-small","input":["a","b"],"encoding_format":"float"}`)
+	result, err := svc.ForwardEmbeddings(c.Request.Context(), c, newEmbeddingsTestAccount(), body, "", "")
+	require.NoError(t, err)
+	require.Len(t, upstream.requests, 1)
+	require.Equal(t, "https://example.com/v1/embeddings", upstream.requests[0].URL.String())
+	require.Equal(t, "Bearer sk-test", upstream.requests[0].Header.Get("authorization"))
+	require.Equal(t, "text-embedding-3-small", gjson.GetBytes(upstream.bodies[0], "model").String())
+	require.Equal(t, "float", gjson.GetBytes(upstream.bodies[0], "encoding_format").String())
+
+	require.Equal(t, "embed-small", result.Model)
+	require.Equal(t, "text-embedding-3-small", result.BillingModel)
+	require.Equal(t, 2, result.Usage.InputTokens)
+	require.Equal(t, "req_1", result.RequestID)
+	require.Equal(t, 2, len(gjson.Get(rec.Body.String(), "data").Array()))
+}
+
+func TestOpenAIGatewayService_ForwardEmbeddings_Batching(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	upstream := &embeddingsUpstreamStub{}
+	svc := &OpenAIGatewayService{cfg: &config.Config{}, httpUpstream: upstream}
+
+	rec := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(rec)
+	c.Request = httptest.NewRequest(http.MethodPost, "/v1/embeddings", nil)
+
+	inputs := make([]string, openAIEmbeddingsMaxBatchInputs+3)
+	for i := range inputs {
+		inputs[i] = fmt.Sprintf("text %d", i)
+	}
+	inputJSON, _ := json.Marshal(inputs)
+	body := []byte(`{"model":"text-embedding-3-small","input":` + string(inputJSON) + `}`)
+
+	result, err := svc.ForwardEmbeddings(c.Request.Context(), c, newEmbeddingsTestAccount(), body, "", "")
+	require.NoError(t, err)
+	require.Len(t, upstream.requests, 2)
+	require.Len(t, gjson.GetBytes(upstream.bodies[1], "input").Array(), 3)
+	require.Equal(t, "text 2048", gjson.GetBytes(upstream.bodies[1], "input.0").String())
+	require.Equal(t, len(inputs), result.Usage.InputTokens)
+
+	var out openAIEmbeddingsResponse
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &out))
+	require.Equal(t, "list", out.Object)
+	require.Len(t, out.Data, len(inputs))
+	for i, d := range out.Data {
+		require.Equal(t, i, d.Index)
+	}
+	require.Equal(t, len(inputs), out.Usage.TotalTokens)
+}
+
+func TestOpenAIGatewayService_ForwardEmbeddings_RejectsOAuthAccount(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	svc := &OpenAIGatewayService{cfg: &config.Config{}, httpUpstream: &embeddingsUpstreamStub{}}
+
+	rec := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(rec)
+	c.Request = httptest.NewRequest(http.MethodPost, "/v1/embeddings", nil)
+
+	account := &Account{ID: 2, Platform: PlatformOpenAI, Type: AccountTypeOAuth}
+	_, err := svc.ForwardEmbeddings(c.Request.Context(), c, account, []byte(`{"model":"m","input":"x"}`), "", "")
+	require.Error(t, err)
+	require.Equal(t, http.StatusBadRequest, rec.Code)
+}
+
+func TestSplitEmbeddingsInput(t *testing.T) {
+	batches, err := splitEmbeddingsInput([]byte(`{"input":"hello"}`), 2)
+	require.NoError(t, err)
+	require.Len(t, batches, 1)
+
+	// 单个 token 数组视为一条输入
+	batches, err = splitEmbeddingsInput([]byte(`{"input":[1,2,3,4,5]}`), 2)
+	require.NoError(t, err)
+	require.Len(t, batches, 1)
+
+	batches, err = splitEmbeddingsInput([]byte(`{"model":"m","input":[[1],[2],[3]]}`), 2)
+	require.NoError(t, err)
+	require.Len(t, batches, 2)
+	require.JSONEq(t, `{"model":"m","input":[[3]]}`, string(batches[1]))
+
+	_, err = splitEmbeddingsInput([]byte(`{"input":[]}`), 2)
+	require.Error(t, err)
+	_, err = splitEmbeddingsInput([]byte(`{"model":"m"}`), 2)
+	require.Error(t, err)
+}
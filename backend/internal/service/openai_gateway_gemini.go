@@ -0,0 +1,345 @@
+package service
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/ShaohongDong/sub2api/internal/pkg/apicompat"
+	"github.com/ShaohongDong/sub2api/internal/pkg/googleapi"
+	"github.com/ShaohongDong/sub2api/internal/pkg/logger"
+	"github.com/ShaohongDong/sub2api/internal/pkg/tracing"
+	"github.com/ShaohongDong/sub2api/internal/util/responseheaders"
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+// ForwardAsGemini accepts a Gemini generateContent / streamGenerateContent
+// request body, converts it to OpenAI Responses API format, forwards to the
+// OpenAI upstream, and converts the response back to Gemini format. This lets
+// Google SDK clients reach OpenAI groups through /v1beta/models/{model}:action.
+//
+// For streaming, "?alt=sse" selects SSE framing (data: {chunk}); otherwise the
+// chunks are streamed as a JSON array, matching the Gemini API.
+func (s *OpenAIGatewayService) ForwardAsGemini(
+	ctx context.Context,
+	c *gin.Context,
+	account *Account,
+	body []byte,
+	originalModel string,
+	clientStream bool,
+	promptCacheKey string,
+	defaultMappedModel string,
+) (*OpenAIForwardResult, error) {
+	startTime := time.Now()
+
+	// 1. Parse Gemini request
+	var geminiReq apicompat.GeminiRequest
+	if err := json.Unmarshal(body, &geminiReq); err != nil {
+		return nil, fmt.Errorf("parse gemini request: %w", err)
+	}
+
+	// 2. Model mapping
+	mappedModel := resolveOpenAIMappedModel(account, originalModel, defaultMappedModel)
+
+	// 3. Convert Gemini → Responses
+	translateSpan := startTranslateSpan(ctx, "gemini", "responses")
+	responsesReq, err := apicompat.GeminiToResponses(&geminiReq, mappedModel)
+	tracing.End(translateSpan, err)
+	if err != nil {
+		writeGeminiError(c, http.StatusBadRequest, err.Error())
+		return nil, fmt.Errorf("convert gemini to responses: %w", err)
+	}
+	// Upstream always uses streaming; the client's action decides the response format.
+	responsesReq.Stream = true
+
+	logger.L().Debug("openai gemini: model mapping applied",
+		zap.Int64("account_id", account.ID),
+		zap.String("original_model", originalModel),
+		zap.String("mapped_model", mappedModel),
+		zap.Bool("stream", clientStream),
+	)
+
+	// 4. Send upstream request (errors are written in Google API format)
+	resp, err := s.doCompatResponsesRequest(ctx, c, account, responsesReq, promptCacheKey, writeGeminiError)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	// 5. Handle normal response
+	includeThoughts := geminiReq.IncludeThoughts() && compatReasoningVisible(c)
+	var result *OpenAIForwardResult
+	var handleErr error
+	if clientStream {
+		result, handleErr = s.handleGeminiStreamingResponse(resp, c, originalModel, mappedModel, includeThoughts, startTime)
+	} else {
+		result, handleErr = s.handleGeminiBufferedStreamingResponse(resp, c, originalModel, mappedModel, includeThoughts, startTime)
+	}
+
+	if handleErr == nil && result != nil && responsesReq.Reasoning != nil && responsesReq.Reasoning.Effort != "" {
+		re := responsesReq.Reasoning.Effort
+		result.ReasoningEffort = &re
+	}
+
+	s.recordOpenAIRateLimitHeaders(account.ID, resp.Header)
+
+	// Extract and save Codex usage snapshot from response headers (for OAuth accounts)
+	if handleErr == nil && account.Type == AccountTypeOAuth {
+		if snapshot := ParseCodexRateLimitHeaders(resp.Header); snapshot != nil {
+			s.updateCodexUsageSnapshot(ctx, account.ID, snapshot)
+		}
+	}
+
+	return result, handleErr
+}
+
+// handleGeminiBufferedStreamingResponse reads the upstream Responses SSE stream
+// until the terminal event and writes a single generateContent JSON response.
+func (s *OpenAIGatewayService) handleGeminiBufferedStreamingResponse(
+	resp *http.Response,
+	c *gin.Context,
+	originalModel string,
+	mappedModel string,
+	includeThoughts bool,
+	startTime time.Time,
+) (*OpenAIForwardResult, error) {
+	requestID := resp.Header.Get("x-request-id")
+
+	scanner := bufio.NewScanner(resp.Body)
+	maxLineSize := defaultMaxLineSize
+	if s.cfg != nil && s.cfg.Gateway.MaxLineSize > 0 {
+		maxLineSize = s.cfg.Gateway.MaxLineSize
+	}
+	scanner.Buffer(make([]byte, 0, 64*1024), maxLineSize)
+
+	var finalResponse *apicompat.ResponsesResponse
+	var usage OpenAIUsage
+
+	for scanner.Scan() {
+		line := scanner.Text()
+		if !strings.HasPrefix(line, "data: ") || line == "data: [DONE]" {
+			continue
+		}
+
+		var event apicompat.ResponsesStreamEvent
+		if err := json.Unmarshal([]byte(line[6:]), &event); err != nil {
+			logger.L().Warn("openai gemini buffered: failed to parse event",
+				zap.Error(err),
+				zap.String("request_id", requestID),
+			)
+			continue
+		}
+
+		if isResponsesTerminalEvent(event.Type) && event.Response != nil {
+			finalResponse = event.Response
+			usage = openAIUsageFromResponses(event.Response.Usage)
+		}
+	}
+
+	if err := scanner.Err(); err != nil {
+		if !errors.Is(err, context.Canceled) && !errors.Is(err, context.DeadlineExceeded) {
+			logger.L().Warn("openai gemini buffered: read error",
+				zap.Error(err),
+				zap.String("request_id", requestID),
+			)
+		}
+	}
+
+	if finalResponse == nil {
+		writeGeminiError(c, http.StatusBadGateway, "Upstream stream ended without a terminal response event")
+		return nil, fmt.Errorf("upstream stream ended without terminal event")
+	}
+
+	if s.responseHeaderFilter != nil {
+		responseheaders.WriteFilteredHeaders(c.Writer.Header(), resp.Header, s.responseHeaderFilter)
+	}
+	c.JSON(http.StatusOK, apicompat.ResponsesToGemini(finalResponse, originalModel, includeThoughts))
+
+	return &OpenAIForwardResult{
+		RequestID:    requestID,
+		Usage:        usage,
+		Model:        originalModel,
+		BillingModel: mappedModel,
+		Stream:       false,
+		Duration:     time.Since(startTime),
+	}, nil
+}
+
+// handleGeminiStreamingResponse converts upstream Responses SSE events into
+// Gemini streamGenerateContent chunks. Gemini has no ping frame, so no
+// keepalive is sent during upstream silence.
+func (s *OpenAIGatewayService) handleGeminiStreamingResponse(
+	resp *http.Response,
+	c *gin.Context,
+	originalModel string,
+	mappedModel string,
+	includeThoughts bool,
+	startTime time.Time,
+) (*OpenAIForwardResult, error) {
+	requestID := resp.Header.Get("x-request-id")
+	useSSE := strings.EqualFold(strings.TrimSpace(c.Query("alt")), "sse")
+
+	if s.responseHeaderFilter != nil {
+		responseheaders.WriteFilteredHeaders(c.Writer.Header(), resp.Header, s.responseHeaderFilter)
+	}
+	if useSSE {
+		c.Writer.Header().Set("Content-Type", "text/event-stream")
+		c.Writer.Header().Set("Connection", "keep-alive")
+		c.Writer.Header().Set("X-Accel-Buffering", "no")
+	} else {
+		c.Writer.Header().Set("Content-Type", "application/json")
+	}
+	c.Writer.Header().Set("Cache-Control", "no-cache")
+	c.Writer.WriteHeader(http.StatusOK)
+
+	state := apicompat.NewResponsesEventToGeminiState()
+	state.Model = originalModel
+	state.IncludeThoughts = includeThoughts
+	var usage OpenAIUsage
+	var firstTokenMs *int
+	firstChunk := true
+	chunksWritten := 0
+
+	resultWithUsage := func() *OpenAIForwardResult {
+		return &OpenAIForwardResult{
+			RequestID:    requestID,
+			Usage:        usage,
+			Model:        originalModel,
+			BillingModel: mappedModel,
+			Stream:       true,
+			Duration:     time.Since(startTime),
+			FirstTokenMs: firstTokenMs,
+		}
+	}
+
+	// writeFrame writes one JSON element in SSE or JSON-array framing.
+	// Returns false when the client has disconnected.
+	writeFrame := func(data []byte) bool {
+		var frame string
+		switch {
+		case useSSE:
+			frame = "data: " + string(data) + "\n\n"
+		case chunksWritten == 0:
+			frame = "[" + string(data)
+		default:
+			frame = ",\r\n" + string(data)
+		}
+		if _, err := fmt.Fprint(c.Writer, frame); err != nil {
+			logger.L().Info("openai gemini stream: client disconnected",
+				zap.String("request_id", requestID),
+			)
+			return false
+		}
+		chunksWritten++
+		return true
+	}
+
+	// writeChunks returns false when the client has disconnected.
+	writeChunks := func(chunks []apicompat.GeminiResponse) bool {
+		for _, chunk := range chunks {
+			data, err := json.Marshal(chunk)
+			if err != nil {
+				continue
+			}
+			if !writeFrame(data) {
+				return false
+			}
+		}
+		if len(chunks) > 0 {
+			c.Writer.Flush()
+		}
+		return true
+	}
+
+	scanner := bufio.NewScanner(resp.Body)
+	maxLineSize := defaultMaxLineSize
+	if s.cfg != nil && s.cfg.Gateway.MaxLineSize > 0 {
+		maxLineSize = s.cfg.Gateway.MaxLineSize
+	}
+	scanner.Buffer(make([]byte, 0, 64*1024), maxLineSize)
+
+	// JSON 数组模式无法插入注释行，仅 SSE（alt=sse）模式发送心跳
+	keepalive := time.Duration(0)
+	if useSSE {
+		keepalive = streamKeepaliveInterval(s.cfg)
+	}
+	sawDone := false
+	completed, err := forEachSSELine(scanner, c.Writer, keepalive, sseCommentHeartbeat, func(line string) bool {
+		if line == "data: [DONE]" {
+			sawDone = true
+			return true
+		}
+		if !strings.HasPrefix(line, "data: ") {
+			return true
+		}
+		if firstChunk {
+			firstChunk = false
+			ms := int(time.Since(startTime).Milliseconds())
+			firstTokenMs = &ms
+		}
+
+		var event apicompat.ResponsesStreamEvent
+		if err := json.Unmarshal([]byte(line[6:]), &event); err != nil {
+			logger.L().Warn("openai gemini stream: failed to parse event",
+				zap.Error(err),
+				zap.String("request_id", requestID),
+			)
+			return true
+		}
+		if isResponsesTerminalEvent(event.Type) && event.Response != nil && event.Response.Usage != nil {
+			usage = openAIUsageFromResponses(event.Response.Usage)
+		}
+		return writeChunks(apicompat.ResponsesEventToGeminiChunks(&event, state))
+	})
+	if !completed {
+		return resultWithUsage(), nil
+	}
+	if err != nil && !errors.Is(err, context.Canceled) && !errors.Is(err, context.DeadlineExceeded) {
+		logger.L().Warn("openai gemini stream: read error",
+			zap.Error(err),
+			zap.String("request_id", requestID),
+		)
+	}
+
+	if c.Request.Context().Err() != nil {
+		return resultWithUsage(), nil
+	}
+	var truncatedErr error
+	if !state.Finished && !sawDone {
+		// 上游在终止事件前断开：以 Google 错误对象结束流，避免客户端把截断的输出当作正常结束
+		data, _ := json.Marshal(gin.H{"error": gin.H{
+			"code":    http.StatusBadGateway,
+			"message": "Upstream stream ended without a terminal response event",
+			"status":  googleapi.HTTPStatusToGoogleStatus(http.StatusBadGateway),
+		}})
+		if !writeFrame(data) {
+			return resultWithUsage(), nil
+		}
+		c.Writer.Flush()
+		truncatedErr = errors.New("upstream stream truncated")
+	} else if !writeChunks(apicompat.FinalizeResponsesGeminiStream(state)) {
+		return resultWithUsage(), nil
+	}
+	if !useSSE {
+		fmt.Fprint(c.Writer, "]") //nolint:errcheck
+		c.Writer.Flush()
+	}
+	return resultWithUsage(), truncatedErr
+}
+
+// writeGeminiError writes an error response in Google API format.
+func writeGeminiError(c *gin.Context, statusCode int, message string) {
+	c.JSON(statusCode, gin.H{
+		"error": gin.H{
+			"code":    statusCode,
+			"message": message,
+			"status":  googleapi.HTTPStatusToGoogleStatus(statusCode),
+		},
+	})
+}
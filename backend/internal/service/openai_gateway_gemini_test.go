@@ -0,0 +1,86 @@
+package service
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ShaohongDong/sub2api/internal/config"
+	"github.com/ShaohongDong/sub2api/internal/pkg/apicompat"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/require"
+)
+
+const geminiTestUpstreamStream = "data: {\"type\":\"response.created\",\"response\":{\"id\":\"resp_1\"}}\n\n" +
+	"data: {\"type\":\"response.output_text.delta\",\"delta\":\"Hello\"}\n\n" +
+	"data: {\"type\":\"response.completed\",\"response\":{\"id\":\"resp_1\",\"status\":\"completed\",\"output\":[{\"type\":\"message\",\"content\":[{\"type\":\"output_text\",\"text\":\"Hello\"}]}],\"usage\":{\"input_tokens\":3,\"output_tokens\":1,\"total_tokens\":4}}}\n\n"
+
+func newGeminiTestUpstreamResponse() *http.Response {
+	return &http.Response{
+		StatusCode: http.StatusOK,
+		Header:     http.Header{"X-Request-Id": []string{"req_1"}},
+		Body:       io.NopCloser(strings.NewReader(geminiTestUpstreamStream)),
+	}
+}
+
+func TestOpenAIGatewayService_HandleGeminiStreamingResponse_Framing(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	svc := &OpenAIGatewayService{cfg: &config.Config{}}
+
+	t.Run("alt=sse", func(t *testing.T) {
+		rec := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(rec)
+		c.Request = httptest.NewRequest(http.MethodPost, "/v1beta/models/gemini-2.5-pro:streamGenerateContent?alt=sse", nil)
+
+		result, err := svc.handleGeminiStreamingResponse(newGeminiTestUpstreamResponse(), c, "gemini-2.5-pro", "gpt-5.1", false, time.Now())
+		require.NoError(t, err)
+		require.Equal(t, 3, result.Usage.InputTokens)
+		require.Equal(t, "gpt-5.1", result.BillingModel)
+		require.True(t, result.Stream)
+		require.Equal(t, "text/event-stream", rec.Header().Get("Content-Type"))
+
+		body := rec.Body.String()
+		require.Equal(t, 2, strings.Count(body, "data: "))
+		require.Contains(t, body, `"text":"Hello"`)
+		require.Contains(t, body, `"finishReason":"STOP"`)
+	})
+
+	t.Run("json array", func(t *testing.T) {
+		rec := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(rec)
+		c.Request = httptest.NewRequest(http.MethodPost, "/v1beta/models/gemini-2.5-pro:streamGenerateContent", nil)
+
+		_, err := svc.handleGeminiStreamingResponse(newGeminiTestUpstreamResponse(), c, "gemini-2.5-pro", "gpt-5.1", false, time.Now())
+		require.NoError(t, err)
+
+		var chunks []apicompat.GeminiResponse
+		require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &chunks))
+		require.Len(t, chunks, 2)
+		require.Equal(t, "Hello", chunks[0].Candidates[0].Content.Parts[0].Text)
+		require.Equal(t, "STOP", chunks[1].Candidates[0].FinishReason)
+		require.Equal(t, 4, chunks[1].UsageMetadata.TotalTokenCount)
+	})
+}
+
+func TestOpenAIGatewayService_HandleGeminiBufferedStreamingResponse(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	svc := &OpenAIGatewayService{cfg: &config.Config{}}
+	rec := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(rec)
+	c.Request = httptest.NewRequest(http.MethodPost, "/v1beta/models/gemini-2.5-pro:generateContent", nil)
+
+	result, err := svc.handleGeminiBufferedStreamingResponse(newGeminiTestUpstreamResponse(), c, "gemini-2.5-pro", "gpt-5.1", false, time.Now())
+	require.NoError(t, err)
+	require.False(t, result.Stream)
+	require.Equal(t, 1, result.Usage.OutputTokens)
+
+	var resp apicompat.GeminiResponse
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &resp))
+	require.Equal(t, "resp_1", resp.ResponseID)
+	require.Equal(t, "gemini-2.5-pro", resp.ModelVersion)
+	require.Equal(t, "Hello", resp.Candidates[0].Content.Parts[0].Text)
+}
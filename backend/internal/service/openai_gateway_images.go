@@ -0,0 +1,517 @@
+package service
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ShaohongDong/sub2api/internal/pkg/apicompat"
+	"github.com/ShaohongDong/sub2api/internal/pkg/logger"
+	"github.com/ShaohongDong/sub2api/internal/util/responseheaders"
+	"github.com/gin-gonic/gin"
+	"github.com/tidwall/gjson"
+	"github.com/tidwall/sjson"
+	"go.uber.org/zap"
+)
+
+// openAIImagesResponseReadMinBytes 图片响应（base64）读取上限的下限，避免默认上限截断多图响应。
+const openAIImagesResponseReadMinBytes int64 = 64 << 20
+
+// openAIImagesOAuthHostModel OAuth 账号通过 Responses image_generation 工具出图时使用的宿主模型。
+const openAIImagesOAuthHostModel = "gpt-5.1"
+
+// openAIImagesRequest 是 /v1/images/generations 请求中网关关心的字段。
+type openAIImagesRequest struct {
+	Model          string `json:"model"`
+	Prompt         string `json:"prompt"`
+	N              int    `json:"n"`
+	Size           string `json:"size"`
+	Quality        string `json:"quality"`
+	Background     string `json:"background"`
+	OutputFormat   string `json:"output_format"`
+	Moderation     string `json:"moderation"`
+	ResponseFormat string `json:"response_format"` // "url" | "b64_json"
+	Stream         bool   `json:"stream"`
+}
+
+// openAIImagesResponse 是 OAuth 账号出图后网关构造的 Images API 响应。
+type openAIImagesResponse struct {
+	Created      int64              `json:"created"`
+	Data         []openAIImageDatum `json:"data"`
+	OutputFormat string             `json:"output_format,omitempty"`
+	Usage        *openAIImagesUsage `json:"usage,omitempty"`
+}
+
+type openAIImageDatum struct {
+	B64JSON       string `json:"b64_json,omitempty"`
+	URL           string `json:"url,omitempty"`
+	RevisedPrompt string `json:"revised_prompt,omitempty"`
+}
+
+type openAIImagesUsage struct {
+	InputTokens  int `json:"input_tokens"`
+	OutputTokens int `json:"output_tokens"`
+	TotalTokens  int `json:"total_tokens"`
+}
+
+// SupportsOpenAIImages 判断账号能否承接图片生成：API Key 账号直连 Images API；
+// ChatGPT OAuth 账号通过 Responses image_generation 工具出图，免费计划不包含图片模型。
+func SupportsOpenAIImages(account *Account) bool {
+	if account == nil {
+		return false
+	}
+	switch account.Type {
+	case AccountTypeAPIKey:
+		return true
+	case AccountTypeOAuth:
+		return !strings.EqualFold(strings.TrimSpace(account.GetCredential("plan_type")), "free")
+	}
+	return false
+}
+
+// ForwardImageGeneration 处理 /v1/images/generations 请求。
+// API Key 账号透传到上游 Images API；OAuth 账号改写为带 image_generation 工具的
+// Responses 请求（n 张图依次请求）。response_format=url 时 base64 结果转换为 data URL。
+func (s *OpenAIGatewayService) ForwardImageGeneration(
+	ctx context.Context,
+	c *gin.Context,
+	account *Account,
+	body []byte,
+	promptCacheKey string,
+	defaultMappedModel string,
+) (*OpenAIForwardResult, error) {
+	startTime := time.Now()
+
+	if !SupportsOpenAIImages(account) {
+		writeOpenAICompatError(c, http.StatusBadRequest, "Image generation is not supported by this account")
+		return nil, fmt.Errorf("image generation not supported for account %d", account.ID)
+	}
+
+	var req openAIImagesRequest
+	if err := json.Unmarshal(body, &req); err != nil {
+		writeOpenAICompatError(c, http.StatusBadRequest, "Failed to parse request body")
+		return nil, fmt.Errorf("parse images request: %w", err)
+	}
+	if req.N <= 0 {
+		req.N = 1
+	}
+
+	// 1. Model mapping
+	originalModel := req.Model
+	mappedModel := resolveOpenAIMappedModel(account, originalModel, defaultMappedModel)
+
+	logger.L().Debug("openai images: model mapping applied",
+		zap.Int64("account_id", account.ID),
+		zap.String("original_model", originalModel),
+		zap.String("mapped_model", mappedModel),
+		zap.Int("n", req.N),
+		zap.Bool("stream", req.Stream),
+	)
+
+	// 2. Forward by account type
+	var result *OpenAIForwardResult
+	var err error
+	if account.Type == AccountTypeOAuth {
+		result, err = s.forwardImagesViaResponses(ctx, c, account, &req, promptCacheKey, startTime)
+	} else {
+		result, err = s.forwardImagesToPlatform(ctx, c, account, body, &req, mappedModel, startTime)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	result.Model = originalModel
+	result.BillingModel = mappedModel
+	result.ImageSize = openAIImageSizeTier(req.Size)
+	result.Duration = time.Since(startTime)
+	return result, nil
+}
+
+// forwardImagesToPlatform 透传请求到 API Key 账号的 Images API。
+func (s *OpenAIGatewayService) forwardImagesToPlatform(
+	ctx context.Context,
+	c *gin.Context,
+	account *Account,
+	body []byte,
+	req *openAIImagesRequest,
+	mappedModel string,
+	startTime time.Time,
+) (*OpenAIForwardResult, error) {
+	var err error
+	if mappedModel != req.Model {
+		if body, err = sjson.SetBytes(body, "model", mappedModel); err != nil {
+			return nil, fmt.Errorf("set mapped model: %w", err)
+		}
+	}
+	// gpt-image 系列总是返回 b64_json 且不接受 response_format，由网关负责转换
+	if isOpenAIGPTImageModel(mappedModel) && gjson.GetBytes(body, "response_format").Exists() {
+		if body, err = sjson.DeleteBytes(body, "response_format"); err != nil {
+			return nil, fmt.Errorf("strip response_format: %w", err)
+		}
+	}
+
+	targetURL, err := s.buildOpenAIPlatformURL(account, "/images/generations")
+	if err != nil {
+		return nil, fmt.Errorf("build upstream url: %w", err)
+	}
+	token, _, err := s.GetAccessToken(ctx, account)
+	if err != nil {
+		return nil, fmt.Errorf("get access token: %w", err)
+	}
+
+	resp, err := s.doOpenAIPlatformRequest(ctx, c, account, targetURL, token, body, "application/json")
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	if s.responseHeaderFilter != nil {
+		responseheaders.WriteFilteredHeaders(c.Writer.Header(), resp.Header, s.responseHeaderFilter)
+	}
+	result := &OpenAIForwardResult{RequestID: resp.Header.Get("x-request-id")}
+
+	if strings.HasPrefix(resp.Header.Get("Content-Type"), "text/event-stream") {
+		result.Stream = true
+		count, usage, firstTokenMs, err := s.streamOpenAIImageEvents(resp, c, startTime)
+		result.ImageCount = count
+		result.Usage = usage
+		result.FirstTokenMs = firstTokenMs
+		if err != nil {
+			return nil, err
+		}
+		return result, nil
+	}
+
+	respBody, err := readUpstreamResponseBodyLimited(resp.Body, openAIImagesReadLimit(s))
+	if err != nil {
+		if errors.Is(err, ErrUpstreamResponseBodyTooLarge) {
+			writeOpenAICompatError(c, http.StatusBadGateway, "Upstream response too large")
+		}
+		return nil, fmt.Errorf("read upstream body: %w", err)
+	}
+	if req.ResponseFormat == "url" {
+		outputFormat := gjson.GetBytes(respBody, "output_format").String()
+		if outputFormat == "" {
+			outputFormat = req.OutputFormat
+		}
+		if respBody, err = convertImagesB64ToDataURL(respBody, outputFormat); err != nil {
+			return nil, fmt.Errorf("convert image data url: %w", err)
+		}
+	}
+	c.Data(resp.StatusCode, "application/json", respBody)
+
+	result.ImageCount = int(gjson.GetBytes(respBody, "data.#").Int())
+	result.Usage = parseOpenAIImagesUsage(gjson.GetBytes(respBody, "usage"))
+	return result, nil
+}
+
+// streamOpenAIImageEvents 逐行转发 Images API 的 SSE 流，
+// 统计 image_generation.completed 事件数并累加其中的 usage。
+func (s *OpenAIGatewayService) streamOpenAIImageEvents(resp *http.Response, c *gin.Context, startTime time.Time) (int, OpenAIUsage, *int, error) {
+	c.Writer.Header().Set("Content-Type", "text/event-stream")
+	c.Writer.Header().Set("Cache-Control", "no-cache")
+	c.Writer.Header().Set("Connection", "keep-alive")
+	c.Writer.Header().Set("X-Accel-Buffering", "no")
+	c.Writer.WriteHeader(resp.StatusCode)
+
+	var usage OpenAIUsage
+	var firstTokenMs *int
+	count := 0
+	scanner := bufio.NewScanner(resp.Body)
+	maxLineSize := defaultMaxLineSize
+	if s.cfg != nil && s.cfg.Gateway.MaxLineSize > 0 {
+		maxLineSize = s.cfg.Gateway.MaxLineSize
+	}
+	scanner.Buffer(make([]byte, 0, 64*1024), maxLineSize)
+
+	for scanner.Scan() {
+		line := scanner.Text()
+		if firstTokenMs == nil && strings.HasPrefix(line, "data: ") {
+			ms := int(time.Since(startTime).Milliseconds())
+			firstTokenMs = &ms
+		}
+		if strings.HasPrefix(line, "data: ") && gjson.Get(line[6:], "type").String() == "image_generation.completed" {
+			count++
+			u := parseOpenAIImagesUsage(gjson.Get(line[6:], "usage"))
+			usage.InputTokens += u.InputTokens
+			usage.OutputTokens += u.OutputTokens
+		}
+		if _, err := fmt.Fprintf(c.Writer, "%s\n", line); err != nil {
+			return count, usage, firstTokenMs, fmt.Errorf("write image stream: %w", err)
+		}
+		if line == "" {
+			c.Writer.Flush()
+		}
+	}
+	c.Writer.Flush()
+	if err := scanner.Err(); err != nil && !errors.Is(err, context.Canceled) && !errors.Is(err, context.DeadlineExceeded) {
+		logger.L().Warn("openai images stream: read error", zap.Error(err))
+	}
+	return count, usage, firstTokenMs, nil
+}
+
+// forwardImagesViaResponses 使用 Responses image_generation 工具为 OAuth 账号出图，
+// 每张图发起一次请求，结果组装为 Images API 格式。
+func (s *OpenAIGatewayService) forwardImagesViaResponses(
+	ctx context.Context,
+	c *gin.Context,
+	account *Account,
+	req *openAIImagesRequest,
+	promptCacheKey string,
+	startTime time.Time,
+) (*OpenAIForwardResult, error) {
+	input, err := json.Marshal(req.Prompt)
+	if err != nil {
+		return nil, fmt.Errorf("marshal prompt: %w", err)
+	}
+	responsesReq := &apicompat.ResponsesRequest{
+		Model:  openAIImagesOAuthHostModel,
+		Input:  input,
+		Stream: true,
+		Tools: []apicompat.ResponsesTool{{
+			Type:         "image_generation",
+			Size:         req.Size,
+			Quality:      req.Quality,
+			Background:   req.Background,
+			OutputFormat: req.OutputFormat,
+			Moderation:   req.Moderation,
+		}},
+		ToolChoice: json.RawMessage(`{"type":"image_generation"}`),
+	}
+
+	result := &OpenAIForwardResult{}
+	images := make([]apicompat.ResponsesOutput, 0, req.N)
+	for i := 0; i < req.N; i++ {
+		resp, err := s.doCompatResponsesRequest(ctx, c, account, responsesReq, promptCacheKey, writeOpenAICompatError)
+		if err != nil {
+			return nil, err
+		}
+		if result.RequestID == "" {
+			result.RequestID = resp.Header.Get("x-request-id")
+		}
+		items, usage, err := s.collectResponsesImages(resp)
+		s.recordOpenAIRateLimitHeaders(account.ID, resp.Header)
+		if snapshot := ParseCodexRateLimitHeaders(resp.Header); snapshot != nil {
+			s.updateCodexUsageSnapshot(ctx, account.ID, snapshot)
+		}
+		_ = resp.Body.Close()
+		if err != nil {
+			writeOpenAICompatError(c, http.StatusBadGateway, err.Error())
+			return nil, fmt.Errorf("collect generated images: %w", err)
+		}
+		images = append(images, items...)
+		result.Usage.InputTokens += usage.InputTokens
+		result.Usage.OutputTokens += usage.OutputTokens
+		result.Usage.CacheReadInputTokens += usage.CacheReadInputTokens
+	}
+	if len(images) == 0 {
+		writeOpenAICompatError(c, http.StatusBadGateway, "Upstream returned no image")
+		return nil, errors.New("upstream returned no image")
+	}
+	if len(images) > req.N {
+		images = images[:req.N]
+	}
+	ms := int(time.Since(startTime).Milliseconds())
+	result.FirstTokenMs = &ms
+	result.ImageCount = len(images)
+
+	outputFormat := images[0].OutputFormat
+	if outputFormat == "" {
+		outputFormat = req.OutputFormat
+	}
+	if outputFormat == "" {
+		outputFormat = "png"
+	}
+	usage := &openAIImagesUsage{
+		InputTokens:  result.Usage.InputTokens,
+		OutputTokens: result.Usage.OutputTokens,
+		TotalTokens:  result.Usage.InputTokens + result.Usage.OutputTokens,
+	}
+	created := time.Now().Unix()
+
+	if req.Stream {
+		result.Stream = true
+		writeOpenAIImageCompletedEvents(c, images, outputFormat, created, usage)
+		return result, nil
+	}
+
+	out := openAIImagesResponse{
+		Created:      created,
+		Data:         make([]openAIImageDatum, 0, len(images)),
+		OutputFormat: outputFormat,
+		Usage:        usage,
+	}
+	for _, img := range images {
+		datum := openAIImageDatum{RevisedPrompt: img.RevisedPrompt}
+		if req.ResponseFormat == "url" {
+			datum.URL = openAIImageDataURL(img.Result, outputFormat)
+		} else {
+			datum.B64JSON = img.Result
+		}
+		out.Data = append(out.Data, datum)
+	}
+	c.JSON(http.StatusOK, out)
+	return result, nil
+}
+
+// collectResponsesImages 读取 Responses SSE 流，收集 image_generation_call 输出项。
+func (s *OpenAIGatewayService) collectResponsesImages(resp *http.Response) ([]apicompat.ResponsesOutput, OpenAIUsage, error) {
+	scanner := bufio.NewScanner(resp.Body)
+	maxLineSize := defaultMaxLineSize
+	if s.cfg != nil && s.cfg.Gateway.MaxLineSize > 0 {
+		maxLineSize = s.cfg.Gateway.MaxLineSize
+	}
+	scanner.Buffer(make([]byte, 0, 64*1024), maxLineSize)
+
+	var images []apicompat.ResponsesOutput
+	var finalResponse *apicompat.ResponsesResponse
+	for scanner.Scan() {
+		line := scanner.Text()
+		if !strings.HasPrefix(line, "data: ") || line == "data: [DONE]" {
+			continue
+		}
+		var event apicompat.ResponsesStreamEvent
+		if err := json.Unmarshal([]byte(line[6:]), &event); err != nil {
+			continue
+		}
+		if event.Type == "response.output_item.done" && event.Item != nil &&
+			event.Item.Type == "image_generation_call" && event.Item.Result != "" {
+			images = append(images, *event.Item)
+		}
+		if isResponsesTerminalEvent(event.Type) && event.Response != nil {
+			finalResponse = event.Response
+		}
+	}
+	if err := scanner.Err(); err != nil && !errors.Is(err, context.Canceled) && !errors.Is(err, context.DeadlineExceeded) {
+		return nil, OpenAIUsage{}, fmt.Errorf("read upstream stream: %w", err)
+	}
+	if finalResponse == nil {
+		return nil, OpenAIUsage{}, errors.New("upstream stream ended without a terminal response event")
+	}
+	if finalResponse.Status == "failed" && len(images) == 0 {
+		msg := "image generation failed"
+		if finalResponse.Error != nil && finalResponse.Error.Message != "" {
+			msg = finalResponse.Error.Message
+		}
+		return nil, OpenAIUsage{}, errors.New(msg)
+	}
+	// 部分上游只在终态响应中返回输出项
+	if len(images) == 0 {
+		for _, item := range finalResponse.Output {
+			if item.Type == "image_generation_call" && item.Result != "" {
+				images = append(images, item)
+			}
+		}
+	}
+	return images, openAIUsageFromResponses(finalResponse.Usage), nil
+}
+
+// writeOpenAIImageCompletedEvents 以 Images API 流式格式输出生成结果，usage 附在最后一个事件上。
+func writeOpenAIImageCompletedEvents(c *gin.Context, images []apicompat.ResponsesOutput, outputFormat string, created int64, usage *openAIImagesUsage) {
+	c.Writer.Header().Set("Content-Type", "text/event-stream")
+	c.Writer.Header().Set("Cache-Control", "no-cache")
+	c.Writer.Header().Set("Connection", "keep-alive")
+	c.Writer.Header().Set("X-Accel-Buffering", "no")
+	c.Writer.WriteHeader(http.StatusOK)
+
+	for i, img := range images {
+		event := map[string]any{
+			"type":          "image_generation.completed",
+			"b64_json":      img.Result,
+			"created_at":    created,
+			"output_format": outputFormat,
+		}
+		if img.Size != "" {
+			event["size"] = img.Size
+		}
+		if i == len(images)-1 {
+			event["usage"] = usage
+		}
+		data, err := json.Marshal(event)
+		if err != nil {
+			continue
+		}
+		_, _ = fmt.Fprintf(c.Writer, "event: image_generation.completed\ndata: %s\n\n", data)
+		c.Writer.Flush()
+	}
+}
+
+// convertImagesB64ToDataURL 将响应 data[i].b64_json 改写为 data URL（response_format=url）。
+func convertImagesB64ToDataURL(body []byte, outputFormat string) ([]byte, error) {
+	var err error
+	for i, item := range gjson.GetBytes(body, "data").Array() {
+		b64 := item.Get("b64_json").String()
+		if b64 == "" || item.Get("url").String() != "" {
+			continue
+		}
+		if body, err = sjson.SetBytes(body, fmt.Sprintf("data.%d.url", i), openAIImageDataURL(b64, outputFormat)); err != nil {
+			return nil, err
+		}
+		if body, err = sjson.DeleteBytes(body, fmt.Sprintf("data.%d.b64_json", i)); err != nil {
+			return nil, err
+		}
+	}
+	return body, nil
+}
+
+// openAIImageDataURL 组装 base64 图片的 data URL。
+func openAIImageDataURL(b64 string, outputFormat string) string {
+	mimeType := "image/png"
+	switch strings.ToLower(outputFormat) {
+	case "jpeg", "jpg":
+		mimeType = "image/jpeg"
+	case "webp":
+		mimeType = "image/webp"
+	}
+	return "data:" + mimeType + ";base64," + b64
+}
+
+// openAIImageSizeTier 将 "WxH" 尺寸映射为计费档位：长边 ≤1024 为 1K，≤2048 为 2K，其余为 4K。
+// auto 或无法解析时按 1K 计费。
+func openAIImageSizeTier(size string) string {
+	w, h, ok := strings.Cut(strings.ToLower(strings.TrimSpace(size)), "x")
+	if !ok {
+		return "1K"
+	}
+	width, errW := strconv.Atoi(w)
+	height, errH := strconv.Atoi(h)
+	if errW != nil || errH != nil {
+		return "1K"
+	}
+	switch longest := max(width, height); {
+	case longest <= 1024:
+		return "1K"
+	case longest <= 2048:
+		return "2K"
+	default:
+		return "4K"
+	}
+}
+
+func isOpenAIGPTImageModel(model string) bool {
+	return strings.HasPrefix(strings.ToLower(model), "gpt-image")
+}
+
+func parseOpenAIImagesUsage(u gjson.Result) OpenAIUsage {
+	if !u.Exists() {
+		return OpenAIUsage{}
+	}
+	return OpenAIUsage{
+		InputTokens:  int(u.Get("input_tokens").Int()),
+		OutputTokens: int(u.Get("output_tokens").Int()),
+	}
+}
+
+func openAIImagesReadLimit(s *OpenAIGatewayService) int64 {
+	limit := resolveUpstreamResponseReadLimit(s.cfg)
+	if limit < openAIImagesResponseReadMinBytes {
+		limit = openAIImagesResponseReadMinBytes
+	}
+	return limit
+}
@@ -0,0 +1,74 @@
+package service
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/ShaohongDong/sub2api/internal/config"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/require"
+	"github.com/tidwall/gjson"
+)
+
+func TestOpenAIGatewayService_ForwardImageGeneration_URLFormat(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	upstream := &httpUpstreamRecorder{resp: newJSONResponseWithHeader(http.StatusOK,
+		`{"created":1,"data":[{"b64_json":"QUJD"}],"output_format":"webp","usage":{"input_tokens":10,"output_tokens":100,"total_tokens":110}}`,
+		"Content-Type", "application/json")}
+	svc := &OpenAIGatewayService{cfg: &config.Config{}, httpUpstream: upstream}
+
+	body := []byte(`{"model":"gpt-image-1","prompt":"a cat","size":"1536x1024","response_format":"url"}`)
+	rec := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(rec)
+	c.Request = httptest.NewRequest(http.MethodPost, "/v1/images/generations", bytes.NewReader(body))
+
+	result, err := svc.ForwardImageGeneration(c.Request.Context(), c, newEmbeddingsTestAccount(), body, "", "")
+	require.NoError(t, err)
+	require.Equal(t, "https://example.com/v1/images/generations", upstream.lastReq.URL.String())
+	require.False(t, gjson.GetBytes(upstream.lastBody, "response_format").Exists())
+
+	require.Equal(t, 1, result.ImageCount)
+	require.Equal(t, "2K", result.ImageSize)
+	require.Equal(t, 10, result.Usage.InputTokens)
+	require.Equal(t, 100, result.Usage.OutputTokens)
+
+	require.Equal(t, "data:image/webp;base64,QUJD", gjson.Get(rec.Body.String(), "data.0.url").String())
+	require.False(t, gjson.Get(rec.Body.String(), "data.0.b64_json").Exists())
+}
+
+func TestOpenAIGatewayService_CollectResponsesImages(t *testing.T) {
+	stream := "data: {\"type\":\"response.output_item.done\",\"item\":{\"type\":\"image_generation_call\",\"id\":\"ig_1\",\"result\":\"QUJD\",\"revised_prompt\":\"a cute cat\"}}\n\n" +
+		"data: {\"type\":\"response.completed\",\"response\":{\"id\":\"resp_1\",\"status\":\"completed\",\"output\":[],\"usage\":{\"input_tokens\":20,\"output_tokens\":5}}}\n\n"
+	resp := &http.Response{
+		StatusCode: http.StatusOK,
+		Header:     http.Header{"Content-Type": []string{"text/event-stream"}},
+		Body:       io.NopCloser(strings.NewReader(stream)),
+	}
+	svc := &OpenAIGatewayService{cfg: &config.Config{}}
+
+	images, usage, err := svc.collectResponsesImages(resp)
+	require.NoError(t, err)
+	require.Len(t, images, 1)
+	require.Equal(t, "QUJD", images[0].Result)
+	require.Equal(t, "a cute cat", images[0].RevisedPrompt)
+	require.Equal(t, 20, usage.InputTokens)
+}
+
+func TestSupportsOpenAIImages(t *testing.T) {
+	require.True(t, SupportsOpenAIImages(&Account{Type: AccountTypeAPIKey}))
+	require.True(t, SupportsOpenAIImages(&Account{Type: AccountTypeOAuth, Credentials: map[string]any{"plan_type": "plus"}}))
+	require.False(t, SupportsOpenAIImages(&Account{Type: AccountTypeOAuth, Credentials: map[string]any{"plan_type": "free"}}))
+	require.False(t, SupportsOpenAIImages(nil))
+}
+
+func TestOpenAIImageSizeTier(t *testing.T) {
+	require.Equal(t, "1K", openAIImageSizeTier("1024x1024"))
+	require.Equal(t, "2K", openAIImageSizeTier("1792x1024"))
+	require.Equal(t, "4K", openAIImageSizeTier("4096x4096"))
+	require.Equal(t, "1K", openAIImageSizeTier("auto"))
+	require.Equal(t, "1K", openAIImageSizeTier(""))
+}
@@ -2,6 +2,7 @@ package service
 
 import (
 	"bufio"
+	"bytes"
 	"context"
 	"encoding/json"
 	"errors"
@@ -14,11 +15,30 @@ import (
 	"github.com/ShaohongDong/sub2api/internal/pkg/apicompat"
 	"github.com/ShaohongDong/sub2api/internal/pkg/claude"
 	"github.com/ShaohongDong/sub2api/internal/pkg/logger"
+	"github.com/ShaohongDong/sub2api/internal/pkg/tracing"
 	"github.com/ShaohongDong/sub2api/internal/util/responseheaders"
 	"github.com/gin-gonic/gin"
+	"github.com/tidwall/sjson"
 	"go.uber.org/zap"
 )
 
+// 流式转换按字节处理上游 SSE 行，避免逐行分配字符串
+var (
+	sseDataLinePrefix  = []byte("data: ")
+	sseEventLinePrefix = []byte("event: ")
+	sseDoneLine        = []byte("data: [DONE]")
+)
+
+// resolveOpenAIMappedModel 返回转发给上游的模型：优先账号级模型映射，
+// 账号未映射时降级到分组默认映射模型（分组级降级）
+func resolveOpenAIMappedModel(account *Account, originalModel, defaultMappedModel string) string {
+	mappedModel := account.GetMappedModel(originalModel)
+	if mappedModel == originalModel && defaultMappedModel != "" {
+		return defaultMappedModel
+	}
+	return mappedModel
+}
+
 // ForwardAsAnthropic accepts an Anthropic Messages request body, converts it
 // to OpenAI Responses API format, forwards to the OpenAI upstream, and converts
 // the response back to Anthropic Messages format. This enables Claude Code
@@ -42,10 +62,14 @@ func (s *OpenAIGatewayService) ForwardAsAnthropic(
 	clientStream := anthropicReq.Stream // client's original stream preference
 
 	// 2. Convert Anthropic → Responses
+	translateSpan := startTranslateSpan(ctx, "anthropic", "responses")
 	responsesReq, err := apicompat.AnthropicToResponses(&anthropicReq)
+	tracing.End(translateSpan, err)
 	if err != nil {
 		return nil, fmt.Errorf("convert anthropic to responses: %w", err)
 	}
+	downscaleResponsesImages(s.cfg, responsesReq)
+	setDroppedParamsHeader(c, responsesReq.DroppedForResponses())
 
 	// Upstream always uses streaming (upstream may not support sync mode).
 	// The client's original preference determines the response format.
@@ -58,11 +82,7 @@ func (s *OpenAIGatewayService) ForwardAsAnthropic(
 	}
 
 	// 3. Model mapping
-	mappedModel := account.GetMappedModel(originalModel)
-	// 分组级降级：账号未映射时使用分组默认映射模型
-	if mappedModel == originalModel && defaultMappedModel != "" {
-		mappedModel = defaultMappedModel
-	}
+	mappedModel := resolveOpenAIMappedModel(account, originalModel, defaultMappedModel)
 	responsesReq.Model = mappedModel
 
 	logger.L().Debug("openai messages: model mapping applied",
@@ -78,11 +98,21 @@ func (s *OpenAIGatewayService) ForwardAsAnthropic(
 		return nil, fmt.Errorf("marshal responses request: %w", err)
 	}
 
+	// 客户端未携带会话标识时，按 cache_control 断点派生缓存键，使共享同一缓存前缀的请求命中同一份上游缓存
+	if promptCacheKey == "" {
+		if promptCacheKey = anthropicCachePrefixKey(body, originalModel); promptCacheKey != "" && account.Type != AccountTypeOAuth {
+			if responsesBody, err = sjson.SetBytes(responsesBody, "prompt_cache_key", promptCacheKey); err != nil {
+				return nil, fmt.Errorf("set prompt cache key: %w", err)
+			}
+		}
+	}
+
 	if account.Type == AccountTypeOAuth {
 		var reqBody map[string]any
 		if err := json.Unmarshal(responsesBody, &reqBody); err != nil {
 			return nil, fmt.Errorf("unmarshal for codex transform: %w", err)
 		}
+		applyCodexInstructionsConfig(reqBody, false, s.codexInstructionsConfig())
 		codexResult := applyCodexOAuthTransform(reqBody, false, false)
 		if codexResult.PromptCacheKey != "" {
 			promptCacheKey = codexResult.PromptCacheKey
@@ -113,7 +143,7 @@ func (s *OpenAIGatewayService) ForwardAsAnthropic(
 	// Override session_id with a deterministic UUID derived from the sticky
 	// session key (buildUpstreamRequest may have set it to the raw value).
 	if promptCacheKey != "" {
-		upstreamReq.Header.Set("session_id", generateSessionUUID(promptCacheKey))
+		upstreamReq.Header.Set("session_id", s.scopedOpenAISessionValue(account, generateSessionUUID(promptCacheKey)))
 	}
 
 	// 7. Send request
@@ -121,7 +151,7 @@ func (s *OpenAIGatewayService) ForwardAsAnthropic(
 	if account.Proxy != nil {
 		proxyURL = account.Proxy.URL()
 	}
-	resp, err := s.httpUpstream.Do(upstreamReq, proxyURL, account.ID, account.Concurrency)
+	resp, err := s.sendOpenAIResponsesUpstream(upstreamReq, proxyURL, account)
 	if err != nil {
 		safeErr := sanitizeUpstreamErrorMessage(err.Error())
 		setOpsUpstreamError(c, 0, safeErr, "")
@@ -174,6 +204,7 @@ func (s *OpenAIGatewayService) ForwardAsAnthropic(
 	}
 
 	// 9. Handle normal response
+	writeCodexRateLimitHeaders(c.Writer.Header(), resp.Header)
 	// Upstream is always streaming; choose response format based on client preference.
 	var result *OpenAIForwardResult
 	var handleErr error
@@ -196,6 +227,8 @@ func (s *OpenAIGatewayService) ForwardAsAnthropic(
 		}
 	}
 
+	s.recordOpenAIRateLimitHeaders(account.ID, resp.Header)
+
 	// Extract and save Codex usage snapshot from response headers (for OAuth accounts)
 	if handleErr == nil && account.Type == AccountTypeOAuth {
 		if snapshot := ParseCodexRateLimitHeaders(resp.Header); snapshot != nil {
@@ -310,13 +343,7 @@ func (s *OpenAIGatewayService) handleAnthropicBufferedStreamingResponse(
 			event.Response != nil {
 			finalResponse = event.Response
 			if event.Response.Usage != nil {
-				usage = OpenAIUsage{
-					InputTokens:  event.Response.Usage.InputTokens,
-					OutputTokens: event.Response.Usage.OutputTokens,
-				}
-				if event.Response.Usage.InputTokensDetails != nil {
-					usage.CacheReadInputTokens = event.Response.Usage.InputTokensDetails.CachedTokens
-				}
+				usage = openAIUsageFromResponses(event.Response.Usage)
 			}
 		}
 	}
@@ -335,7 +362,7 @@ func (s *OpenAIGatewayService) handleAnthropicBufferedStreamingResponse(
 		return nil, fmt.Errorf("upstream stream ended without terminal event")
 	}
 
-	anthropicResp := apicompat.ResponsesToAnthropic(finalResponse, originalModel)
+	anthropicResp := apicompat.ResponsesToAnthropic(finalResponse, originalModel, compatReasoningVisible(c))
 
 	if s.responseHeaderFilter != nil {
 		responseheaders.WriteFilteredHeaders(c.Writer.Header(), resp.Header, s.responseHeaderFilter)
@@ -377,6 +404,7 @@ func (s *OpenAIGatewayService) handleAnthropicStreamingResponse(
 
 	state := apicompat.NewResponsesEventToAnthropicState()
 	state.Model = originalModel
+	state.IncludeThinking = compatReasoningVisible(c)
 	var usage OpenAIUsage
 	var firstTokenMs *int
 	firstChunk := true
@@ -401,9 +429,13 @@ func (s *OpenAIGatewayService) handleAnthropicStreamingResponse(
 		}
 	}
 
+	// out is reused across events: all Anthropic events converted from one
+	// upstream line are encoded into it and written with a single Write.
+	var out []byte
+
 	// processDataLine handles a single "data: ..." SSE line from upstream.
 	// Returns (clientDisconnected bool).
-	processDataLine := func(payload string) bool {
+	processDataLine := func(payload []byte) bool {
 		if firstChunk {
 			firstChunk = false
 			ms := int(time.Since(startTime).Milliseconds())
@@ -411,7 +443,7 @@ func (s *OpenAIGatewayService) handleAnthropicStreamingResponse(
 		}
 
 		var event apicompat.ResponsesStreamEvent
-		if err := json.Unmarshal([]byte(payload), &event); err != nil {
+		if err := apicompat.ParseResponsesStreamEvent(payload, &event); err != nil {
 			logger.L().Warn("openai messages stream: failed to parse event",
 				zap.Error(err),
 				zap.String("request_id", requestID),
@@ -422,34 +454,28 @@ func (s *OpenAIGatewayService) handleAnthropicStreamingResponse(
 		// Extract usage from completion events
 		if (event.Type == "response.completed" || event.Type == "response.incomplete" || event.Type == "response.failed") &&
 			event.Response != nil && event.Response.Usage != nil {
-			usage = OpenAIUsage{
-				InputTokens:  event.Response.Usage.InputTokens,
-				OutputTokens: event.Response.Usage.OutputTokens,
-			}
-			if event.Response.Usage.InputTokensDetails != nil {
-				usage.CacheReadInputTokens = event.Response.Usage.InputTokensDetails.CachedTokens
-			}
+			usage = openAIUsageFromResponses(event.Response.Usage)
 		}
 
 		// Convert to Anthropic events
 		events := apicompat.ResponsesEventToAnthropicEvents(&event, state)
-		for _, evt := range events {
-			sse, err := apicompat.ResponsesAnthropicEventToSSE(evt)
-			if err != nil {
+		out = out[:0]
+		for i := range events {
+			var err error
+			if out, err = apicompat.AppendResponsesAnthropicEventSSE(out, &events[i]); err != nil {
 				logger.L().Warn("openai messages stream: failed to marshal event",
 					zap.Error(err),
 					zap.String("request_id", requestID),
 				)
-				continue
 			}
-			if _, err := fmt.Fprint(c.Writer, sse); err != nil {
+		}
+		if len(out) > 0 {
+			if _, err := c.Writer.Write(out); err != nil {
 				logger.L().Info("openai messages stream: client disconnected",
 					zap.String("request_id", requestID),
 				)
 				return true
 			}
-		}
-		if len(events) > 0 {
 			c.Writer.Flush()
 		}
 		return false
@@ -458,13 +484,11 @@ func (s *OpenAIGatewayService) handleAnthropicStreamingResponse(
 	// finalizeStream sends any remaining Anthropic events and returns the result.
 	finalizeStream := func() (*OpenAIForwardResult, error) {
 		if finalEvents := apicompat.FinalizeResponsesAnthropicStream(state); len(finalEvents) > 0 {
-			for _, evt := range finalEvents {
-				sse, err := apicompat.ResponsesAnthropicEventToSSE(evt)
-				if err != nil {
-					continue
-				}
-				fmt.Fprint(c.Writer, sse) //nolint:errcheck
+			out = out[:0]
+			for i := range finalEvents {
+				out, _ = apicompat.AppendResponsesAnthropicEventSSE(out, &finalEvents[i])
 			}
+			_, _ = c.Writer.Write(out)
 			c.Writer.Flush()
 		}
 		return resultWithUsage(), nil
@@ -481,19 +505,16 @@ func (s *OpenAIGatewayService) handleAnthropicStreamingResponse(
 	}
 
 	// ── Determine keepalive interval ──
-	keepaliveInterval := time.Duration(0)
-	if s.cfg != nil && s.cfg.Gateway.StreamKeepaliveInterval > 0 {
-		keepaliveInterval = time.Duration(s.cfg.Gateway.StreamKeepaliveInterval) * time.Second
-	}
+	keepaliveInterval := streamKeepaliveInterval(s.cfg)
 
 	// ── No keepalive: fast synchronous path (no goroutine overhead) ──
 	if keepaliveInterval <= 0 {
 		for scanner.Scan() {
-			line := scanner.Text()
-			if !strings.HasPrefix(line, "data: ") || line == "data: [DONE]" {
+			line := scanner.Bytes()
+			if !bytes.HasPrefix(line, sseDataLinePrefix) || bytes.Equal(line, sseDoneLine) {
 				continue
 			}
-			if processDataLine(line[6:]) {
+			if processDataLine(line[len(sseDataLinePrefix):]) {
 				return resultWithUsage(), nil
 			}
 		}
@@ -503,7 +524,7 @@ func (s *OpenAIGatewayService) handleAnthropicStreamingResponse(
 
 	// ── With keepalive: goroutine + channel + select ──
 	type scanEvent struct {
-		line string
+		line []byte
 		err  error
 	}
 	events := make(chan scanEvent, 16)
@@ -519,7 +540,7 @@ func (s *OpenAIGatewayService) handleAnthropicStreamingResponse(
 	go func() {
 		defer close(events)
 		for scanner.Scan() {
-			if !sendEvent(scanEvent{line: scanner.Text()}) {
+			if !sendEvent(scanEvent{line: bytes.Clone(scanner.Bytes())}) {
 				return
 			}
 		}
@@ -546,10 +567,10 @@ func (s *OpenAIGatewayService) handleAnthropicStreamingResponse(
 			}
 			lastDataAt = time.Now()
 			line := ev.line
-			if !strings.HasPrefix(line, "data: ") || line == "data: [DONE]" {
+			if !bytes.HasPrefix(line, sseDataLinePrefix) || bytes.Equal(line, sseDoneLine) {
 				continue
 			}
-			if processDataLine(line[6:]) {
+			if processDataLine(line[len(sseDataLinePrefix):]) {
 				return resultWithUsage(), nil
 			}
 
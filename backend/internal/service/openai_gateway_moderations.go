@@ -0,0 +1,90 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/ShaohongDong/sub2api/internal/pkg/logger"
+	"github.com/ShaohongDong/sub2api/internal/pkg/moderation"
+	"github.com/ShaohongDong/sub2api/internal/util/responseheaders"
+	"github.com/gin-gonic/gin"
+	"github.com/tidwall/gjson"
+	"github.com/tidwall/sjson"
+	"go.uber.org/zap"
+)
+
+// ForwardModerations 将 /v1/moderations 请求透传到 API Key 账号的上游。
+// model 为可选字段：未指定时按 moderation.DefaultModel 调度与记录，请求体保持不变；
+// 上游审核接口不计费，用量记录为 0 token。
+func (s *OpenAIGatewayService) ForwardModerations(
+	ctx context.Context,
+	c *gin.Context,
+	account *Account,
+	body []byte,
+	_ string,
+	defaultMappedModel string,
+) (*OpenAIForwardResult, error) {
+	startTime := time.Now()
+
+	if !SupportsOpenAIPlatformAPI(account) {
+		writeOpenAICompatError(c, http.StatusBadRequest, "Moderations are not supported by this account type")
+		return nil, fmt.Errorf("moderations not supported for account type %s", account.Type)
+	}
+
+	originalModel := gjson.GetBytes(body, "model").String()
+	if originalModel == "" {
+		originalModel = moderation.DefaultModel
+	}
+	mappedModel := resolveOpenAIMappedModel(account, originalModel, defaultMappedModel)
+	if mappedModel != originalModel {
+		var err error
+		body, err = sjson.SetBytes(body, "model", mappedModel)
+		if err != nil {
+			return nil, fmt.Errorf("set mapped model: %w", err)
+		}
+	}
+
+	logger.L().Debug("openai moderations: model mapping applied",
+		zap.Int64("account_id", account.ID),
+		zap.String("original_model", originalModel),
+		zap.String("mapped_model", mappedModel),
+	)
+
+	targetURL, err := s.buildOpenAIPlatformURL(account, "/moderations")
+	if err != nil {
+		return nil, fmt.Errorf("build upstream url: %w", err)
+	}
+	token, _, err := s.GetAccessToken(ctx, account)
+	if err != nil {
+		return nil, fmt.Errorf("get access token: %w", err)
+	}
+
+	resp, err := s.doOpenAIPlatformRequest(ctx, c, account, targetURL, token, body, "application/json")
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("read upstream body: %w", err)
+	}
+	if s.responseHeaderFilter != nil {
+		responseheaders.WriteFilteredHeaders(c.Writer.Header(), resp.Header, s.responseHeaderFilter)
+	}
+	contentType := resp.Header.Get("Content-Type")
+	if contentType == "" {
+		contentType = "application/json"
+	}
+	c.Data(resp.StatusCode, contentType, respBody)
+
+	return &OpenAIForwardResult{
+		RequestID:    resp.Header.Get("x-request-id"),
+		Model:        originalModel,
+		BillingModel: mappedModel,
+		Duration:     time.Since(startTime),
+	}, nil
+}
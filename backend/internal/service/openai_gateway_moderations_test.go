@@ -0,0 +1,52 @@
+package service
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/ShaohongDong/sub2api/internal/config"
+	"github.com/ShaohongDong/sub2api/internal/pkg/moderation"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/require"
+	"github.com/tidwall/gjson"
+)
+
+func TestOpenAIGatewayService_ForwardModerations_Passthrough(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	upstream := &httpUpstreamRecorder{resp: newJSONResponseWithHeader(http.StatusOK, `{"id":"modr-1","model":"omni-moderation-latest","results":[{"flagged":false}]}`, "x-request-id", "req_mod")}
+	svc := &OpenAIGatewayService{cfg: &config.Config{}, httpUpstream: upstream}
+
+	rec := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(rec)
+	c.Request = httptest.NewRequest(http.MethodPost, "/v1/moderations", nil)
+
+	body := []byte(`{"input":"hello"}`)
+	result, err := svc.ForwardModerations(c.Request.Context(), c, newEmbeddingsTestAccount(), body, "", "")
+	require.NoError(t, err)
+	require.NotNil(t, upstream.lastReq)
+	require.Equal(t, "https://example.com/v1/moderations", upstream.lastReq.URL.String())
+	require.Equal(t, "Bearer sk-test", upstream.lastReq.Header.Get("authorization"))
+	// 未指定 model 时请求体保持不变，由上游使用默认模型
+	require.False(t, gjson.GetBytes(upstream.lastBody, "model").Exists())
+
+	require.Equal(t, moderation.DefaultModel, result.Model)
+	require.Equal(t, "req_mod", result.RequestID)
+	require.Zero(t, result.Usage.InputTokens)
+	require.Equal(t, http.StatusOK, rec.Code)
+	require.Equal(t, "modr-1", gjson.Get(rec.Body.String(), "id").String())
+}
+
+func TestOpenAIGatewayService_ForwardModerations_RejectsOAuthAccount(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	svc := &OpenAIGatewayService{cfg: &config.Config{}, httpUpstream: &httpUpstreamRecorder{}}
+
+	rec := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(rec)
+	c.Request = httptest.NewRequest(http.MethodPost, "/v1/moderations", nil)
+
+	account := &Account{ID: 2, Platform: PlatformOpenAI, Type: AccountTypeOAuth}
+	_, err := svc.ForwardModerations(c.Request.Context(), c, account, []byte(`{"input":"hi"}`), "", "")
+	require.Error(t, err)
+	require.Equal(t, http.StatusBadRequest, rec.Code)
+}
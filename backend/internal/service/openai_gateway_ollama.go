@@ -0,0 +1,324 @@
+package service
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/ShaohongDong/sub2api/internal/pkg/apicompat"
+	"github.com/ShaohongDong/sub2api/internal/pkg/logger"
+	"github.com/ShaohongDong/sub2api/internal/util/responseheaders"
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+// Ollama 兼容端点
+const (
+	OllamaEndpointChat     = "chat"     // POST /api/chat
+	OllamaEndpointGenerate = "generate" // POST /api/generate
+)
+
+// ForwardAsOllama accepts an Ollama /api/chat or /api/generate request body,
+// converts it to OpenAI Responses API format, forwards to the OpenAI upstream,
+// and converts the response back to Ollama format. Ollama streams by default
+// (NDJSON, one object per line); "stream": false returns a single object.
+//
+// originalModel is the model name the client sent, normalised by the handler
+// (":latest" stripped).
+func (s *OpenAIGatewayService) ForwardAsOllama(
+	ctx context.Context,
+	c *gin.Context,
+	account *Account,
+	body []byte,
+	endpoint string,
+	originalModel string,
+	promptCacheKey string,
+	defaultMappedModel string,
+) (*OpenAIForwardResult, error) {
+	startTime := time.Now()
+
+	// 1. Model mapping
+	mappedModel := resolveOpenAIMappedModel(account, originalModel, defaultMappedModel)
+
+	// 2. Parse and convert Ollama → Responses
+	var (
+		responsesReq *apicompat.ResponsesRequest
+		clientStream = true
+		think        json.RawMessage
+		err          error
+	)
+	switch endpoint {
+	case OllamaEndpointGenerate:
+		var genReq apicompat.OllamaGenerateRequest
+		if err := json.Unmarshal(body, &genReq); err != nil {
+			return nil, fmt.Errorf("parse ollama generate request: %w", err)
+		}
+		if genReq.Stream != nil {
+			clientStream = *genReq.Stream
+		}
+		think = genReq.Think
+		responsesReq, err = apicompat.OllamaGenerateToResponses(&genReq, mappedModel)
+	default:
+		var chatReq apicompat.OllamaChatRequest
+		if err := json.Unmarshal(body, &chatReq); err != nil {
+			return nil, fmt.Errorf("parse ollama chat request: %w", err)
+		}
+		if chatReq.Stream != nil {
+			clientStream = *chatReq.Stream
+		}
+		think = chatReq.Think
+		responsesReq, err = apicompat.OllamaChatToResponses(&chatReq, mappedModel)
+	}
+	if err != nil {
+		writeOllamaError(c, http.StatusBadRequest, err.Error())
+		return nil, fmt.Errorf("convert ollama to responses: %w", err)
+	}
+	// Upstream always uses streaming; the client's stream flag decides the response format.
+	responsesReq.Stream = true
+	includeThinking := apicompat.OllamaThinkEnabled(think) && compatReasoningVisible(c)
+
+	logger.L().Debug("openai ollama: model mapping applied",
+		zap.Int64("account_id", account.ID),
+		zap.String("endpoint", endpoint),
+		zap.String("original_model", originalModel),
+		zap.String("mapped_model", mappedModel),
+		zap.Bool("stream", clientStream),
+	)
+
+	// 3. Send upstream request (errors are written in Ollama error format)
+	resp, err := s.doCompatResponsesRequest(ctx, c, account, responsesReq, promptCacheKey, writeOllamaError)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	// 4. Handle normal response
+	var result *OpenAIForwardResult
+	var handleErr error
+	if clientStream {
+		result, handleErr = s.handleOllamaStreamingResponse(resp, c, endpoint, originalModel, mappedModel, includeThinking, startTime)
+	} else {
+		result, handleErr = s.handleOllamaBufferedStreamingResponse(resp, c, endpoint, originalModel, mappedModel, includeThinking, startTime)
+	}
+
+	if handleErr == nil && result != nil && responsesReq.Reasoning != nil && responsesReq.Reasoning.Effort != "" {
+		re := responsesReq.Reasoning.Effort
+		result.ReasoningEffort = &re
+	}
+
+	s.recordOpenAIRateLimitHeaders(account.ID, resp.Header)
+
+	// Extract and save Codex usage snapshot from response headers (for OAuth accounts)
+	if handleErr == nil && account.Type == AccountTypeOAuth {
+		if snapshot := ParseCodexRateLimitHeaders(resp.Header); snapshot != nil {
+			s.updateCodexUsageSnapshot(ctx, account.ID, snapshot)
+		}
+	}
+
+	return result, handleErr
+}
+
+// handleOllamaBufferedStreamingResponse reads the upstream Responses SSE stream
+// until the terminal event and writes a single Ollama response object.
+func (s *OpenAIGatewayService) handleOllamaBufferedStreamingResponse(
+	resp *http.Response,
+	c *gin.Context,
+	endpoint string,
+	originalModel string,
+	mappedModel string,
+	includeThinking bool,
+	startTime time.Time,
+) (*OpenAIForwardResult, error) {
+	requestID := resp.Header.Get("x-request-id")
+
+	scanner := bufio.NewScanner(resp.Body)
+	maxLineSize := defaultMaxLineSize
+	if s.cfg != nil && s.cfg.Gateway.MaxLineSize > 0 {
+		maxLineSize = s.cfg.Gateway.MaxLineSize
+	}
+	scanner.Buffer(make([]byte, 0, 64*1024), maxLineSize)
+
+	var finalResponse *apicompat.ResponsesResponse
+	var usage OpenAIUsage
+
+	for scanner.Scan() {
+		line := scanner.Text()
+		if !strings.HasPrefix(line, "data: ") || line == "data: [DONE]" {
+			continue
+		}
+
+		var event apicompat.ResponsesStreamEvent
+		if err := json.Unmarshal([]byte(line[6:]), &event); err != nil {
+			logger.L().Warn("openai ollama buffered: failed to parse event",
+				zap.Error(err),
+				zap.String("request_id", requestID),
+			)
+			continue
+		}
+
+		if isResponsesTerminalEvent(event.Type) && event.Response != nil {
+			finalResponse = event.Response
+			usage = openAIUsageFromResponses(event.Response.Usage)
+		}
+	}
+
+	if err := scanner.Err(); err != nil {
+		if !errors.Is(err, context.Canceled) && !errors.Is(err, context.DeadlineExceeded) {
+			logger.L().Warn("openai ollama buffered: read error",
+				zap.Error(err),
+				zap.String("request_id", requestID),
+			)
+		}
+	}
+
+	if finalResponse == nil {
+		writeOllamaError(c, http.StatusBadGateway, "Upstream stream ended without a terminal response event")
+		return nil, fmt.Errorf("upstream stream ended without terminal event")
+	}
+
+	if s.responseHeaderFilter != nil {
+		responseheaders.WriteFilteredHeaders(c.Writer.Header(), resp.Header, s.responseHeaderFilter)
+	}
+	chatResp := apicompat.ResponsesToOllamaChat(finalResponse, originalModel, includeThinking)
+	chatResp.TotalDuration = time.Since(startTime).Nanoseconds()
+	if endpoint == OllamaEndpointGenerate {
+		c.JSON(http.StatusOK, apicompat.OllamaChatToGenerate(chatResp))
+	} else {
+		c.JSON(http.StatusOK, chatResp)
+	}
+
+	return &OpenAIForwardResult{
+		RequestID:    requestID,
+		Usage:        usage,
+		Model:        originalModel,
+		BillingModel: mappedModel,
+		Stream:       false,
+		Duration:     time.Since(startTime),
+	}, nil
+}
+
+// handleOllamaStreamingResponse converts upstream Responses SSE events into
+// Ollama NDJSON chunks. Ollama has no ping frame, so no keepalive is sent
+// during upstream silence.
+func (s *OpenAIGatewayService) handleOllamaStreamingResponse(
+	resp *http.Response,
+	c *gin.Context,
+	endpoint string,
+	originalModel string,
+	mappedModel string,
+	includeThinking bool,
+	startTime time.Time,
+) (*OpenAIForwardResult, error) {
+	requestID := resp.Header.Get("x-request-id")
+
+	if s.responseHeaderFilter != nil {
+		responseheaders.WriteFilteredHeaders(c.Writer.Header(), resp.Header, s.responseHeaderFilter)
+	}
+	c.Writer.Header().Set("Content-Type", "application/x-ndjson")
+	c.Writer.Header().Set("Cache-Control", "no-cache")
+	c.Writer.Header().Set("X-Accel-Buffering", "no")
+	c.Writer.WriteHeader(http.StatusOK)
+
+	state := apicompat.NewResponsesEventToOllamaState()
+	state.Model = originalModel
+	state.IncludeThinking = includeThinking
+	state.StartTime = startTime
+	var usage OpenAIUsage
+	var firstTokenMs *int
+	firstChunk := true
+
+	resultWithUsage := func() *OpenAIForwardResult {
+		return &OpenAIForwardResult{
+			RequestID:    requestID,
+			Usage:        usage,
+			Model:        originalModel,
+			BillingModel: mappedModel,
+			Stream:       true,
+			Duration:     time.Since(startTime),
+			FirstTokenMs: firstTokenMs,
+		}
+	}
+
+	// writeChunks writes chunks as NDJSON lines (reshaped for /api/generate).
+	// Returns false when the client has disconnected.
+	writeChunks := func(chunks []apicompat.OllamaChatResponse) bool {
+		for i := range chunks {
+			var payload any = &chunks[i]
+			if endpoint == OllamaEndpointGenerate {
+				gen := apicompat.OllamaChatToGenerate(&chunks[i])
+				// tool-call-only chunks have nothing to show in generate format
+				if !gen.Done && gen.Response == "" && gen.Thinking == "" {
+					continue
+				}
+				payload = gen
+			}
+			line, err := apicompat.OllamaChunkToNDJSON(payload)
+			if err != nil {
+				continue
+			}
+			if _, err := fmt.Fprint(c.Writer, line); err != nil {
+				logger.L().Info("openai ollama stream: client disconnected",
+					zap.String("request_id", requestID),
+				)
+				return false
+			}
+		}
+		if len(chunks) > 0 {
+			c.Writer.Flush()
+		}
+		return true
+	}
+
+	scanner := bufio.NewScanner(resp.Body)
+	maxLineSize := defaultMaxLineSize
+	if s.cfg != nil && s.cfg.Gateway.MaxLineSize > 0 {
+		maxLineSize = s.cfg.Gateway.MaxLineSize
+	}
+	scanner.Buffer(make([]byte, 0, 64*1024), maxLineSize)
+
+	for scanner.Scan() {
+		line := scanner.Text()
+		if !strings.HasPrefix(line, "data: ") || line == "data: [DONE]" {
+			continue
+		}
+		if firstChunk {
+			firstChunk = false
+			ms := int(time.Since(startTime).Milliseconds())
+			firstTokenMs = &ms
+		}
+
+		var event apicompat.ResponsesStreamEvent
+		if err := json.Unmarshal([]byte(line[6:]), &event); err != nil {
+			logger.L().Warn("openai ollama stream: failed to parse event",
+				zap.Error(err),
+				zap.String("request_id", requestID),
+			)
+			continue
+		}
+		if isResponsesTerminalEvent(event.Type) && event.Response != nil && event.Response.Usage != nil {
+			usage = openAIUsageFromResponses(event.Response.Usage)
+		}
+		if !writeChunks(apicompat.ResponsesEventToOllamaChatChunks(&event, state)) {
+			return resultWithUsage(), nil
+		}
+	}
+	if err := scanner.Err(); err != nil && !errors.Is(err, context.Canceled) && !errors.Is(err, context.DeadlineExceeded) {
+		logger.L().Warn("openai ollama stream: read error",
+			zap.Error(err),
+			zap.String("request_id", requestID),
+		)
+	}
+
+	writeChunks(apicompat.FinalizeResponsesOllamaStream(state))
+	return resultWithUsage(), nil
+}
+
+// writeOllamaError writes an error response in Ollama API format.
+func writeOllamaError(c *gin.Context, statusCode int, message string) {
+	c.JSON(statusCode, gin.H{"error": message})
+}
@@ -0,0 +1,69 @@
+package service
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ShaohongDong/sub2api/internal/config"
+	"github.com/ShaohongDong/sub2api/internal/pkg/apicompat"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/require"
+)
+
+func TestOpenAIGatewayService_HandleOllamaStreamingResponse(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	svc := &OpenAIGatewayService{cfg: &config.Config{}}
+
+	for _, endpoint := range []string{OllamaEndpointChat, OllamaEndpointGenerate} {
+		t.Run(endpoint, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(rec)
+			c.Request = httptest.NewRequest(http.MethodPost, "/api/"+endpoint, nil)
+
+			// 复用 Gemini 测试的上游 Responses SSE 流
+			result, err := svc.handleOllamaStreamingResponse(newGeminiTestUpstreamResponse(), c, endpoint, "gpt-5.1", "gpt-5.1", false, time.Now())
+			require.NoError(t, err)
+			require.True(t, result.Stream)
+			require.Equal(t, 3, result.Usage.InputTokens)
+			require.Equal(t, "application/x-ndjson", rec.Header().Get("Content-Type"))
+
+			lines := strings.Split(strings.TrimSpace(rec.Body.String()), "\n")
+			require.Len(t, lines, 2)
+
+			var last map[string]any
+			require.NoError(t, json.Unmarshal([]byte(lines[1]), &last))
+			require.Equal(t, true, last["done"])
+			require.Equal(t, "stop", last["done_reason"])
+			require.EqualValues(t, 1, last["eval_count"])
+			if endpoint == OllamaEndpointGenerate {
+				require.Contains(t, lines[0], `"response":"Hello"`)
+			} else {
+				require.Contains(t, lines[0], `"content":"Hello"`)
+			}
+		})
+	}
+}
+
+func TestOpenAIGatewayService_HandleOllamaBufferedStreamingResponse(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	svc := &OpenAIGatewayService{cfg: &config.Config{}}
+
+	rec := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(rec)
+	c.Request = httptest.NewRequest(http.MethodPost, "/api/chat", nil)
+
+	result, err := svc.handleOllamaBufferedStreamingResponse(newGeminiTestUpstreamResponse(), c, OllamaEndpointChat, "gpt-5.1", "gpt-5.1", false, time.Now())
+	require.NoError(t, err)
+	require.False(t, result.Stream)
+
+	var out apicompat.OllamaChatResponse
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &out))
+	require.True(t, out.Done)
+	require.Equal(t, "Hello", out.Message.Content)
+	require.Equal(t, 3, out.PromptEvalCount)
+	require.Positive(t, out.TotalDuration)
+}
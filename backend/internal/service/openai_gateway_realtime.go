@@ -0,0 +1,174 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/ShaohongDong/sub2api/internal/pkg/logger"
+	openaiwsv2 "github.com/ShaohongDong/sub2api/internal/service/openai_ws_v2"
+	coderws "github.com/coder/websocket"
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+// buildOpenAIRealtimeWSURL 组装 Realtime API 的上游 WebSocket 地址（wss://.../v1/realtime?model=...）。
+func (s *OpenAIGatewayService) buildOpenAIRealtimeWSURL(account *Account, model string) (string, error) {
+	targetURL, err := s.buildOpenAIPlatformURL(account, "/realtime")
+	if err != nil {
+		return "", err
+	}
+	parsed, err := url.Parse(targetURL)
+	if err != nil {
+		return "", err
+	}
+	switch strings.ToLower(parsed.Scheme) {
+	case "https":
+		parsed.Scheme = "wss"
+	case "http":
+		parsed.Scheme = "ws"
+	}
+	query := parsed.Query()
+	query.Set("model", model)
+	parsed.RawQuery = query.Encode()
+	return parsed.String(), nil
+}
+
+// openAIRealtimeBetaHeader 取客户端的 OpenAI-Beta 头；浏览器客户端无法设置请求头，
+// 改从 "openai-beta.realtime-v1" 子协议还原为 "realtime=v1"。
+func openAIRealtimeBetaHeader(c *gin.Context) string {
+	if beta := strings.TrimSpace(c.GetHeader("OpenAI-Beta")); beta != "" {
+		return beta
+	}
+	for _, protocol := range strings.Split(c.GetHeader("Sec-WebSocket-Protocol"), ",") {
+		if value, ok := strings.CutPrefix(strings.TrimSpace(protocol), "openai-beta."); ok && value != "" {
+			if idx := strings.LastIndex(value, "-"); idx > 0 {
+				return value[:idx] + "=" + value[idx+1:]
+			}
+			return value
+		}
+	}
+	return ""
+}
+
+// ProxyRealtimeWebSocket 将客户端 /v1/realtime WebSocket 会话透传到 API Key 账号的上游。
+// 音频与文本事件原样双向转发；会话内每个 response.done 的 usage 累加，
+// 会话结束（任一侧关闭）时返回整场会话的用量，由调用方一次性记录。
+// 客户端主动断开视为正常结束，此时 error 为 nil。
+func (s *OpenAIGatewayService) ProxyRealtimeWebSocket(
+	ctx context.Context,
+	c *gin.Context,
+	clientConn *coderws.Conn,
+	account *Account,
+	token string,
+	model string,
+	defaultMappedModel string,
+) (*OpenAIForwardResult, error) {
+	if clientConn == nil {
+		return nil, errors.New("client websocket is nil")
+	}
+	if !SupportsOpenAIPlatformAPI(account) {
+		return nil, NewOpenAIWSClientCloseError(coderws.StatusPolicyViolation, "realtime is not supported by this account type", nil)
+	}
+	if strings.TrimSpace(token) == "" {
+		return nil, errors.New("token is empty")
+	}
+
+	mappedModel := resolveOpenAIMappedModel(account, model, defaultMappedModel)
+	wsURL, err := s.buildOpenAIRealtimeWSURL(account, mappedModel)
+	if err != nil {
+		return nil, fmt.Errorf("build realtime ws url: %w", err)
+	}
+
+	headers := http.Header{}
+	headers.Set("Authorization", "Bearer "+token)
+	if c != nil {
+		if beta := openAIRealtimeBetaHeader(c); beta != "" {
+			headers.Set("OpenAI-Beta", beta)
+		}
+	}
+	proxyURL := ""
+	if account.ProxyID != nil && account.Proxy != nil {
+		proxyURL = account.Proxy.URL()
+	}
+
+	dialer := s.getOpenAIWSPassthroughDialer()
+	if dialer == nil {
+		return nil, errors.New("openai ws passthrough dialer is nil")
+	}
+	dialCtx, cancelDial := context.WithTimeout(ctx, s.openAIWSDialTimeout())
+	upstreamConn, statusCode, handshakeHeaders, err := dialer.Dial(dialCtx, wsURL, headers, proxyURL)
+	cancelDial()
+	if err != nil {
+		logger.L().Warn("openai realtime: upstream dial failed",
+			zap.Int64("account_id", account.ID),
+			zap.Int("status_code", statusCode),
+			zap.Error(err),
+		)
+		return nil, s.mapOpenAIWSPassthroughDialError(err, statusCode, handshakeHeaders)
+	}
+	defer func() {
+		_ = upstreamConn.Close()
+	}()
+	upstreamFrameConn, ok := upstreamConn.(openaiwsv2.FrameConn)
+	if !ok {
+		return nil, errors.New("openai realtime upstream connection does not support frame relay")
+	}
+
+	relayResult, relayExit := openaiwsv2.RunEntry(openaiwsv2.EntryInput{
+		Ctx:          ctx,
+		ClientConn:   &openAIWSClientFrameConn{conn: clientConn},
+		UpstreamConn: upstreamFrameConn,
+		Options: openaiwsv2.RelayOptions{
+			WriteTimeout: s.openAIWSWriteTimeout(),
+			IdleTimeout:  s.openAIWSPassthroughIdleTimeout(),
+			OnUsageParseFailure: func(eventType string, usageRaw string) {
+				logger.L().Warn("openai realtime: usage parse failed",
+					zap.Int64("account_id", account.ID),
+					zap.String("event_type", eventType),
+					zap.String("usage_raw", truncateOpenAIWSLogValue(usageRaw, openAIWSLogValueMaxLen)),
+				)
+			},
+		},
+	})
+
+	result := &OpenAIForwardResult{
+		RequestID: handshakeHeaders.Get("x-request-id"),
+		Usage: OpenAIUsage{
+			InputTokens:              relayResult.Usage.InputTokens,
+			OutputTokens:             relayResult.Usage.OutputTokens,
+			CacheCreationInputTokens: relayResult.Usage.CacheCreationInputTokens,
+			CacheReadInputTokens:     relayResult.Usage.CacheReadInputTokens,
+		},
+		Model:           model,
+		BillingModel:    mappedModel,
+		Stream:          true,
+		OpenAIWSMode:    true,
+		ResponseHeaders: cloneHeader(handshakeHeaders),
+		Duration:        relayResult.Duration,
+		FirstTokenMs:    relayResult.FirstTokenMs,
+	}
+	if result.RequestID == "" {
+		result.RequestID = relayResult.RequestID
+	}
+	logger.L().Info("openai realtime: session closed",
+		zap.Int64("account_id", account.ID),
+		zap.String("model", mappedModel),
+		zap.Duration("duration", result.Duration),
+		zap.Int("input_tokens", result.Usage.InputTokens),
+		zap.Int("output_tokens", result.Usage.OutputTokens),
+		zap.Int64("client_frames", relayResult.ClientToUpstreamFrames),
+		zap.Int64("upstream_frames", relayResult.UpstreamToClientFrames),
+	)
+
+	if relayExit == nil || relayExit.Stage == "client_disconnected" {
+		return result, nil
+	}
+	if relayExit.Stage == "idle_timeout" {
+		return result, NewOpenAIWSClientCloseError(coderws.StatusPolicyViolation, "realtime session idle timeout", relayExit.Err)
+	}
+	return result, fmt.Errorf("realtime relay %s: %w", relayExit.Stage, relayExit.Err)
+}
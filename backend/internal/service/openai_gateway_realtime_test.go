@@ -0,0 +1,137 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ShaohongDong/sub2api/internal/config"
+	coderws "github.com/coder/websocket"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/require"
+	"github.com/tidwall/gjson"
+)
+
+type openAIRealtimeCaptureDialer struct {
+	mu          sync.Mutex
+	conn        *openAIWSCaptureConn
+	handshake   http.Header
+	lastURL     string
+	lastHeaders http.Header
+}
+
+func (d *openAIRealtimeCaptureDialer) Dial(_ context.Context, wsURL string, headers http.Header, _ string) (openAIWSClientConn, int, http.Header, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.lastURL = wsURL
+	d.lastHeaders = cloneHeader(headers)
+	return d.conn, http.StatusSwitchingProtocols, cloneHeader(d.handshake), nil
+}
+
+func TestBuildOpenAIRealtimeWSURL(t *testing.T) {
+	svc := &OpenAIGatewayService{cfg: &config.Config{}}
+
+	wsURL, err := svc.buildOpenAIRealtimeWSURL(newEmbeddingsTestAccount(), "gpt-4o-realtime-preview")
+	require.NoError(t, err)
+	require.Equal(t, "wss://example.com/v1/realtime?model=gpt-4o-realtime-preview", wsURL)
+}
+
+func TestProxyRealtimeWebSocket_RejectsOAuthAccount(t *testing.T) {
+	svc := &OpenAIGatewayService{cfg: &config.Config{}}
+	account := &Account{ID: 1, Platform: PlatformOpenAI, Type: AccountTypeOAuth}
+
+	_, err := svc.ProxyRealtimeWebSocket(context.Background(), nil, &coderws.Conn{}, account, "tok", "gpt-4o-realtime-preview", "")
+	var closeErr *OpenAIWSClientCloseError
+	require.True(t, errors.As(err, &closeErr))
+	require.Equal(t, coderws.StatusPolicyViolation, closeErr.StatusCode())
+}
+
+func TestProxyRealtimeWebSocket_RelaysEventsAndAccumulatesUsage(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	cfg := &config.Config{}
+	cfg.Gateway.OpenAIWS.DialTimeoutSeconds = 3
+	cfg.Gateway.OpenAIWS.ReadTimeoutSeconds = 3
+	cfg.Gateway.OpenAIWS.WriteTimeoutSeconds = 3
+
+	dialer := &openAIRealtimeCaptureDialer{
+		conn: &openAIWSCaptureConn{
+			events: [][]byte{
+				[]byte(`{"type":"session.created","session":{"model":"gpt-4o-realtime-preview"}}`),
+				[]byte(`{"type":"response.done","response":{"id":"resp_rt_1","usage":{"input_tokens":100,"output_tokens":20,"input_token_details":{"cached_tokens":40}}}}`),
+				[]byte(`{"type":"response.done","response":{"id":"resp_rt_2","usage":{"input_tokens":50,"output_tokens":10,"input_token_details":{"cached_tokens":0}}}}`),
+			},
+		},
+		handshake: http.Header{"X-Request-Id": []string{"req_rt_session"}},
+	}
+	svc := &OpenAIGatewayService{cfg: cfg, openaiWSPassthroughDialer: dialer}
+	account := newEmbeddingsTestAccount()
+	account.Credentials["model_mapping"] = map[string]any{"gpt-realtime": "gpt-4o-realtime-preview"}
+
+	type proxyOutcome struct {
+		result *OpenAIForwardResult
+		err    error
+	}
+	outcomeCh := make(chan proxyOutcome, 1)
+	wsServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, err := coderws.Accept(w, r, nil)
+		if err != nil {
+			outcomeCh <- proxyOutcome{err: err}
+			return
+		}
+		defer func() {
+			_ = conn.CloseNow()
+		}()
+		ginCtx, _ := gin.CreateTestContext(httptest.NewRecorder())
+		ginCtx.Request = r
+		result, err := svc.ProxyRealtimeWebSocket(r.Context(), ginCtx, conn, account, "sk-test", "gpt-realtime", "")
+		outcomeCh <- proxyOutcome{result: result, err: err}
+	}))
+	defer wsServer.Close()
+
+	dialCtx, cancelDial := context.WithTimeout(context.Background(), 3*time.Second)
+	clientConn, _, err := coderws.Dial(dialCtx, "ws"+strings.TrimPrefix(wsServer.URL, "http"), &coderws.DialOptions{
+		HTTPHeader: http.Header{"OpenAI-Beta": []string{"realtime=v1"}},
+	})
+	cancelDial()
+	require.NoError(t, err)
+	defer func() {
+		_ = clientConn.CloseNow()
+	}()
+
+	var eventTypes []string
+	for i := 0; i < 3; i++ {
+		readCtx, cancelRead := context.WithTimeout(context.Background(), 3*time.Second)
+		_, event, readErr := clientConn.Read(readCtx)
+		cancelRead()
+		require.NoError(t, readErr)
+		eventTypes = append(eventTypes, gjson.GetBytes(event, "type").String())
+	}
+	require.Equal(t, []string{"session.created", "response.done", "response.done"}, eventTypes)
+
+	select {
+	case outcome := <-outcomeCh:
+		require.NoError(t, outcome.err)
+		require.NotNil(t, outcome.result)
+		require.Equal(t, "req_rt_session", outcome.result.RequestID)
+		require.Equal(t, "gpt-realtime", outcome.result.Model)
+		require.Equal(t, "gpt-4o-realtime-preview", outcome.result.BillingModel)
+		require.Equal(t, 150, outcome.result.Usage.InputTokens)
+		require.Equal(t, 30, outcome.result.Usage.OutputTokens)
+		require.Equal(t, 40, outcome.result.Usage.CacheReadInputTokens)
+		require.True(t, outcome.result.OpenAIWSMode)
+	case <-time.After(5 * time.Second):
+		t.Fatal("等待 realtime 会话结束超时")
+	}
+
+	dialer.mu.Lock()
+	defer dialer.mu.Unlock()
+	require.Equal(t, "wss://example.com/v1/realtime?model=gpt-4o-realtime-preview", dialer.lastURL)
+	require.Equal(t, "Bearer sk-test", dialer.lastHeaders.Get("Authorization"))
+	require.Equal(t, "realtime=v1", dialer.lastHeaders.Get("OpenAI-Beta"))
+}
@@ -334,3 +334,31 @@ func TestOpenAIGatewayServiceRecordUsage_ClampsActualInputTokensToZero(t *testin
 	require.NotNil(t, usageRepo.lastLog)
 	require.Equal(t, 0, usageRepo.lastLog.InputTokens)
 }
+
+func TestOpenAIGatewayServiceRecordUsage_EstimatesInputTokensWhenUsageMissing(t *testing.T) {
+	usageRepo := &openAIRecordUsageLogRepoStub{inserted: true}
+	svc := newOpenAIRecordUsageServiceForTest(usageRepo, &openAIRecordUsageUserRepoStub{}, &openAIRecordUsageSubRepoStub{}, nil)
+
+	// 无估算器时保持原有行为：不写入用量
+	err := svc.RecordUsage(context.Background(), &OpenAIRecordUsageInput{
+		Result:  &OpenAIForwardResult{RequestID: "resp_no_usage", Model: "gpt-5.1", Duration: time.Second},
+		APIKey:  &APIKey{ID: 1007},
+		User:    &User{ID: 2007},
+		Account: &Account{ID: 3007},
+	})
+	require.NoError(t, err)
+	require.Equal(t, 0, usageRepo.calls)
+
+	err = svc.RecordUsage(context.Background(), &OpenAIRecordUsageInput{
+		Result:              &OpenAIForwardResult{RequestID: "resp_estimated", Model: "gpt-5.1", Duration: time.Second},
+		APIKey:              &APIKey{ID: 1007},
+		User:                &User{ID: 2007},
+		Account:             &Account{ID: 3007},
+		InputTokenEstimator: func() int { return 42 },
+	})
+	require.NoError(t, err)
+	require.Equal(t, 1, usageRepo.calls)
+	require.NotNil(t, usageRepo.lastLog)
+	require.Equal(t, 42, usageRepo.lastLog.InputTokens)
+	require.Equal(t, 0, usageRepo.lastLog.OutputTokens)
+}
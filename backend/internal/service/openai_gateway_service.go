@@ -201,6 +201,8 @@ type OpenAIUsage struct {
 	OutputTokens             int `json:"output_tokens"`
 	CacheCreationInputTokens int `json:"cache_creation_input_tokens,omitempty"`
 	CacheReadInputTokens     int `json:"cache_read_input_tokens,omitempty"`
+	// ReasoningTokens 推理 token 数（已包含在 OutputTokens 中）
+	ReasoningTokens int `json:"reasoning_tokens,omitempty"`
 }
 
 // OpenAIForwardResult represents the result of forwarding
@@ -224,6 +226,10 @@ type OpenAIForwardResult struct {
 	ResponseHeaders http.Header
 	Duration        time.Duration
 	FirstTokenMs    *int
+	// ImageCount/ImageSize are set by the image generation path; when
+	// ImageCount > 0 the request is billed per image instead of per token.
+	ImageCount int    // 生成的图片数量
+	ImageSize  string // 图片尺寸 "1K", "2K", "4K"
 }
 
 type OpenAIWSRetryMetricsSnapshot struct {
@@ -275,6 +281,7 @@ type OpenAIGatewayService struct {
 	openAITokenProvider   *OpenAITokenProvider
 	toolCorrector         *CodexToolCorrector
 	openaiWSResolver      OpenAIWSProtocolResolver
+	settingService        *SettingService
 
 	openaiWSPoolOnce              sync.Once
 	openaiWSStateStoreOnce        sync.Once
@@ -287,6 +294,10 @@ type OpenAIGatewayService struct {
 	openaiAccountStats            *openAIAccountRuntimeStats
 
 	openaiWSFallbackUntil sync.Map // key: int64(accountID), value: time.Time
+	openaiUsageWindows    sync.Map // key: int64(accountID), value: *openAIUsageWindowCounter
+	sharedState           *SharedStateService
+	conversationStore     *ConversationStoreService
+	usageWebhook          *UsageWebhookService // optional: 逐请求用量推送
 	openaiWSRetryMetrics  openAIWSRetryMetrics
 	responseHeaderFilter  *responseheaders.CompiledHeaderFilter
 }
@@ -308,6 +319,7 @@ func NewOpenAIGatewayService(
 	httpUpstream HTTPUpstream,
 	deferredService *DeferredService,
 	openAITokenProvider *OpenAITokenProvider,
+	settingService *SettingService,
 ) *OpenAIGatewayService {
 	svc := &OpenAIGatewayService{
 		accountRepo:         accountRepo,
@@ -334,12 +346,31 @@ func NewOpenAIGatewayService(
 		openAITokenProvider:  openAITokenProvider,
 		toolCorrector:        NewCodexToolCorrector(),
 		openaiWSResolver:     NewOpenAIWSProtocolResolver(cfg),
+		settingService:       settingService,
 		responseHeaderFilter: compileResponseHeaderFilter(cfg),
 	}
 	svc.logOpenAIWSModeBootstrap()
 	return svc
 }
 
+// codexInstructionsConfig 返回 gateway.codex_instructions 配置
+func (s *OpenAIGatewayService) codexInstructionsConfig() config.GatewayCodexInstructionsConfig {
+	if s == nil || s.cfg == nil {
+		return config.GatewayCodexInstructionsConfig{}
+	}
+	return s.cfg.Gateway.CodexInstructions
+}
+
+// SetConversationStore 注入会话存储；previous_response_id 在上游不可用时由本地上下文重建
+func (s *OpenAIGatewayService) SetConversationStore(store *ConversationStoreService) {
+	s.conversationStore = store
+}
+
+// SetUsageWebhook 注入逐请求用量推送服务；未注入时不推送
+func (s *OpenAIGatewayService) SetUsageWebhook(svc *UsageWebhookService) {
+	s.usageWebhook = svc
+}
+
 func (s *OpenAIGatewayService) billingDeps() *billingDeps {
 	return &billingDeps{
 		accountRepo:         s.accountRepo,
@@ -561,11 +592,15 @@ func (s *OpenAIGatewayService) writeOpenAIWSFallbackErrorResponse(c *gin.Context
 			Message:            upstreamMessage,
 		})
 	}
-	c.JSON(statusCode, gin.H{
-		"error": gin.H{
-			"type":    errType,
-			"message": clientMessage,
-		},
+	code := MapOpenAIUpstreamStatus(statusCode).Code
+	if errType == "invalid_request_error" {
+		code = ""
+	}
+	WriteOpenAIError(c, OpenAIClientError{
+		Status:  statusCode,
+		Type:    errType,
+		Code:    code,
+		Message: ScrubOpenAIClientErrorMessage(clientMessage, account),
 	})
 	return true
 }
@@ -930,6 +965,7 @@ func (s *OpenAIGatewayService) ExtractSessionID(c *gin.Context, body []byte) str
 // GenerateSessionHash generates a sticky-session hash for OpenAI requests.
 //
 // Priority:
+//  0. Header: gateway.session_affinity_header (scoped by API key)
 //  1. Header: session_id
 //  2. Header: conversation_id
 //  3. Body:   prompt_cache_key (opencode)
@@ -938,7 +974,10 @@ func (s *OpenAIGatewayService) GenerateSessionHash(c *gin.Context, body []byte)
 		return ""
 	}
 
-	sessionID := strings.TrimSpace(c.GetHeader("session_id"))
+	sessionID := clientSessionAffinityKey(c, s.cfg)
+	if sessionID == "" {
+		sessionID = strings.TrimSpace(c.GetHeader("session_id"))
+	}
 	if sessionID == "" {
 		sessionID = strings.TrimSpace(c.GetHeader("conversation_id"))
 	}
@@ -1087,7 +1126,7 @@ func (s *OpenAIGatewayService) tryStickySessionHit(ctx context.Context, groupID
 	if !account.IsSchedulable() || !account.IsOpenAI() {
 		return nil
 	}
-	if requestedModel != "" && !account.IsModelSupported(requestedModel) {
+	if requestedModel != "" && (!account.IsModelSupported(requestedModel) || !s.isAccountPlanCompatible(ctx, account, requestedModel)) {
 		return nil
 	}
 
@@ -1241,7 +1280,7 @@ func (s *OpenAIGatewayService) SelectAccountWithLoadAwareness(ctx context.Contex
 					_ = s.deleteStickySessionAccountID(ctx, groupID, sessionHash)
 				}
 				if !clearSticky && account.IsSchedulable() && account.IsOpenAI() &&
-					(requestedModel == "" || account.IsModelSupported(requestedModel)) {
+					(requestedModel == "" || (account.IsModelSupported(requestedModel) && s.isAccountPlanCompatible(ctx, account, requestedModel))) {
 					result, err := s.tryAcquireAccountSlot(ctx, accountID, account.Concurrency)
 					if err == nil && result.Acquired {
 						_ = s.refreshStickySessionTTL(ctx, groupID, sessionHash, openaiStickySessionTTL)
@@ -1282,7 +1321,7 @@ func (s *OpenAIGatewayService) SelectAccountWithLoadAwareness(ctx context.Contex
 		if !acc.IsSchedulable() {
 			continue
 		}
-		if requestedModel != "" && !acc.IsModelSupported(requestedModel) {
+		if requestedModel != "" && (!acc.IsModelSupported(requestedModel) || !s.isAccountPlanCompatible(ctx, acc, requestedModel)) {
 			continue
 		}
 		candidates = append(candidates, acc)
@@ -1443,7 +1482,7 @@ func (s *OpenAIGatewayService) resolveFreshSchedulableOpenAIAccount(ctx context.
 	if !fresh.IsSchedulable() || !fresh.IsOpenAI() {
 		return nil
 	}
-	if requestedModel != "" && !fresh.IsModelSupported(requestedModel) {
+	if requestedModel != "" && (!fresh.IsModelSupported(requestedModel) || !s.isAccountPlanCompatible(ctx, fresh, requestedModel)) {
 		return nil
 	}
 	return fresh
@@ -1692,6 +1731,10 @@ func (s *OpenAIGatewayService) Forward(ctx context.Context, c *gin.Context, acco
 	}
 
 	if account.Type == AccountTypeOAuth {
+		if applyCodexInstructionsConfig(reqBody, isCodexCLI, s.codexInstructionsConfig()) {
+			bodyModified = true
+			disablePatch()
+		}
 		codexResult := applyCodexOAuthTransform(reqBody, isCodexCLI, isOpenAIResponsesCompactPath(c))
 		if codexResult.Modified {
 			bodyModified = true
@@ -1761,7 +1804,12 @@ func (s *OpenAIGatewayService) Forward(ctx context.Context, c *gin.Context, acco
 
 	// 仅在 WSv2 模式保留 previous_response_id，其他模式（HTTP/WSv1）统一过滤。
 	// 注意：该规则同样适用于 Codex CLI 请求，避免 WSv1 向上游透传不支持字段。
+	// 会话存储中有该轮记录时，先用本地上下文重建 input，续链不因过滤而丢失。
 	if wsDecision.Transport != OpenAIUpstreamTransportResponsesWebsocketV2 {
+		if s.conversationStore.ExpandInput(ctx, apiKeyID, reqBody) {
+			bodyModified = true
+			disablePatch()
+		}
 		if _, has := reqBody["previous_response_id"]; has {
 			delete(reqBody, "previous_response_id")
 			bodyModified = true
@@ -1838,6 +1886,16 @@ func (s *OpenAIGatewayService) Forward(ctx context.Context, c *gin.Context, acco
 				)
 				return false
 			}
+			if s.conversationStore.ExpandInput(ctx, apiKeyID, wsReqBody) {
+				wsPrevResponseRecoveryTried = true
+				logOpenAIWSModeInfo(
+					"reconnect_prev_response_recovery account_id=%d attempt=%d action=expand_local_conversation retry=1 previous_response_id=%s",
+					account.ID,
+					attempt,
+					truncateOpenAIWSLogValue(previousResponseID, openAIWSIDValueMaxLen),
+				)
+				return true
+			}
 			if HasFunctionCallOutput(wsReqBody) {
 				logOpenAIWSModeInfo(
 					"reconnect_prev_response_recovery_skip account_id=%d attempt=%d reason=has_function_call_output previous_response_id_present=true",
@@ -1990,7 +2048,7 @@ func (s *OpenAIGatewayService) Forward(ctx context.Context, c *gin.Context, acco
 
 	// Send request
 	upstreamStart := time.Now()
-	resp, err := s.httpUpstream.Do(upstreamReq, proxyURL, account.ID, account.Concurrency)
+	resp, err := s.sendOpenAIResponsesUpstream(upstreamReq, proxyURL, account)
 	SetOpsLatencyMs(c, OpsUpstreamLatencyMsKey, time.Since(upstreamStart).Milliseconds())
 	if err != nil {
 		// Ensure the client receives an error response (handlers assume Forward writes on non-failover errors).
@@ -2004,12 +2062,7 @@ func (s *OpenAIGatewayService) Forward(ctx context.Context, c *gin.Context, acco
 			Kind:               "request_error",
 			Message:            safeErr,
 		})
-		c.JSON(http.StatusBadGateway, gin.H{
-			"error": gin.H{
-				"type":    "upstream_error",
-				"message": "Upstream request failed",
-			},
-		})
+		WriteOpenAIError(c, MapOpenAIRequestError(err))
 		return nil, fmt.Errorf("upstream request failed: %s", safeErr)
 	}
 	defer func() { _ = resp.Body.Close() }()
@@ -2065,6 +2118,8 @@ func (s *OpenAIGatewayService) Forward(ctx context.Context, c *gin.Context, acco
 		}
 	}
 
+	s.recordOpenAIRateLimitHeaders(account.ID, resp.Header)
+
 	// Extract and save Codex usage snapshot from response headers (for OAuth accounts)
 	if account.Type == AccountTypeOAuth {
 		if snapshot := ParseCodexRateLimitHeaders(resp.Header); snapshot != nil {
@@ -2179,7 +2234,7 @@ func (s *OpenAIGatewayService) forwardOpenAIPassthrough(
 	}
 
 	upstreamStart := time.Now()
-	resp, err := s.httpUpstream.Do(upstreamReq, proxyURL, account.ID, account.Concurrency)
+	resp, err := s.sendOpenAIResponsesUpstream(upstreamReq, proxyURL, account)
 	SetOpsLatencyMs(c, OpsUpstreamLatencyMsKey, time.Since(upstreamStart).Milliseconds())
 	if err != nil {
 		safeErr := sanitizeUpstreamErrorMessage(err.Error())
@@ -2193,12 +2248,7 @@ func (s *OpenAIGatewayService) forwardOpenAIPassthrough(
 			Kind:               "request_error",
 			Message:            safeErr,
 		})
-		c.JSON(http.StatusBadGateway, gin.H{
-			"error": gin.H{
-				"type":    "upstream_error",
-				"message": "Upstream request failed",
-			},
-		})
+		WriteOpenAIError(c, MapOpenAIRequestError(err))
 		return nil, fmt.Errorf("upstream request failed: %s", safeErr)
 	}
 	defer func() { _ = resp.Body.Close() }()
@@ -2224,6 +2274,7 @@ func (s *OpenAIGatewayService) forwardOpenAIPassthrough(
 		}
 	}
 
+	s.recordOpenAIRateLimitHeaders(account.ID, resp.Header)
 	if snapshot := ParseCodexRateLimitHeaders(resp.Header); snapshot != nil {
 		s.updateCodexUsageSnapshot(ctx, account.ID, snapshot)
 	}
@@ -2321,19 +2372,17 @@ func (s *OpenAIGatewayService) buildUpstreamRequestOpenAIPassthrough(
 	req.Header.Del("authorization")
 	req.Header.Del("x-api-key")
 	req.Header.Del("x-goog-api-key")
+	req.Header.Del("api-key")
 	req.Header.Set("authorization", "Bearer "+token)
 
 	// OAuth 透传到 ChatGPT internal API 时补齐必要头。
 	if account.Type == AccountTypeOAuth {
 		promptCacheKey := strings.TrimSpace(gjson.GetBytes(body, "prompt_cache_key").String())
 		req.Host = "chatgpt.com"
-		if chatgptAccountID := account.GetChatGPTAccountID(); chatgptAccountID != "" {
-			req.Header.Set("chatgpt-account-id", chatgptAccountID)
-		}
 		if isOpenAIResponsesCompactPath(c) {
 			req.Header.Set("accept", "application/json")
 			if req.Header.Get("version") == "" {
-				req.Header.Set("version", codexCLIVersion)
+				req.Header.Set("version", s.upstreamCodexVersion())
 			}
 			if req.Header.Get("session_id") == "" {
 				req.Header.Set("session_id", resolveOpenAICompactSessionID(c))
@@ -2357,23 +2406,24 @@ func (s *OpenAIGatewayService) buildUpstreamRequestOpenAIPassthrough(
 		}
 	}
 
-	// 透传模式也支持账户自定义 User-Agent 与 ForceCodexCLI 兜底。
-	customUA := account.GetOpenAIUserAgent()
-	if customUA != "" {
-		req.Header.Set("user-agent", customUA)
-	}
-	if s.cfg != nil && s.cfg.Gateway.ForceCodexCLI {
-		req.Header.Set("user-agent", codexCLIUserAgent)
+	// 透传模式也支持账户自定义 User-Agent、模板改写与 ForceCodexCLI 兜底。
+	if ua := s.resolveUpstreamUserAgent(ctx, account, req.Header.Get("user-agent")); ua != "" {
+		req.Header.Set("user-agent", ua)
 	}
 	// OAuth 安全透传：对非 Codex UA 统一兜底，降低被上游风控拦截概率。
 	if account.Type == AccountTypeOAuth && !openai.IsCodexCLIRequest(req.Header.Get("user-agent")) {
-		req.Header.Set("user-agent", codexCLIUserAgent)
+		req.Header.Set("user-agent", s.upstreamCodexUserAgentForContext(ctx))
 	}
 
 	if req.Header.Get("content-type") == "" {
 		req.Header.Set("content-type", "application/json")
 	}
 
+	// 按头策略额外透传、剥离或注入上游请求头
+	s.settingService.ApplyUpstreamHeaderPolicy(c, req, account)
+	// 账号与会话标识头最后按当前账号确定，不受客户端或头策略影响
+	s.scopeOpenAISessionHeaders(req.Header, account)
+
 	return req, nil
 }
 
@@ -2412,11 +2462,17 @@ func (s *OpenAIGatewayService) handleErrorResponsePassthrough(
 	})
 
 	writeOpenAIPassthroughResponseHeaders(c.Writer.Header(), resp.Header, s.responseHeaderFilter)
-	contentType := resp.Header.Get("Content-Type")
-	if contentType == "" {
-		contentType = "application/json"
+	if isOpenAIPassthroughErrorBodySafe(resp.StatusCode, body, account) {
+		contentType := resp.Header.Get("Content-Type")
+		if contentType == "" {
+			contentType = "application/json"
+		}
+		c.Data(resp.StatusCode, contentType, body)
+	} else {
+		// HTML 错误页、非 OpenAI 格式或含账号标识的错误体统一转换为 OpenAI 格式
+		c.Writer.Header().Set("Content-Type", "application/json; charset=utf-8")
+		WriteOpenAIError(c, NormalizeOpenAIUpstreamError(resp.StatusCode, body, account))
 	}
-	c.Data(resp.StatusCode, contentType, body)
 
 	if upstreamMsg == "" {
 		return fmt.Errorf("upstream error: %d", resp.StatusCode)
@@ -2528,7 +2584,7 @@ func (s *OpenAIGatewayService) handleStreamingResponsePassthrough(
 		if !clientDisconnected {
 			if _, err := fmt.Fprintln(w, line); err != nil {
 				clientDisconnected = true
-				logger.LegacyPrintf("service.openai_gateway", "[OpenAI passthrough] Client disconnected during streaming, continue draining upstream for usage: account=%d", account.ID)
+				logger.LegacyPrintf("service.openai_gateway", "[OpenAI passthrough] Client disconnected during streaming, upstream request canceled: account=%d", account.ID)
 			} else {
 				flusher.Flush()
 			}
@@ -2656,6 +2712,7 @@ func writeOpenAICodexResponseHeaders(dst http.Header, src http.Header) {
 
 	synthesizeOpenAICodexResetAtHeader(dst, src, "primary")
 	synthesizeOpenAICodexResetAtHeader(dst, src, "secondary")
+	writeCodexRateLimitHeaders(dst, src)
 }
 
 func copyOpenAIResponseHeaderCaseInsensitive(dst http.Header, src http.Header, want string) bool {
@@ -2751,12 +2808,8 @@ func (s *OpenAIGatewayService) buildUpstreamRequest(ctx context.Context, c *gin.
 	// Set headers specific to OAuth accounts (ChatGPT internal API)
 	if account.Type == AccountTypeOAuth {
 		// Required: set Host for ChatGPT API (must use req.Host, not Header.Set)
+		// chatgpt-account-id is set by scopeOpenAISessionHeaders below.
 		req.Host = "chatgpt.com"
-		// Required: set chatgpt-account-id header
-		chatgptAccountID := account.GetChatGPTAccountID()
-		if chatgptAccountID != "" {
-			req.Header.Set("chatgpt-account-id", chatgptAccountID)
-		}
 	}
 
 	// Whitelist passthrough headers
@@ -2774,7 +2827,7 @@ func (s *OpenAIGatewayService) buildUpstreamRequest(ctx context.Context, c *gin.
 		if isOpenAIResponsesCompactPath(c) {
 			req.Header.Set("accept", "application/json")
 			if req.Header.Get("version") == "" {
-				req.Header.Set("version", codexCLIVersion)
+				req.Header.Set("version", s.upstreamCodexVersion())
 			}
 			if req.Header.Get("session_id") == "" {
 				req.Header.Set("session_id", resolveOpenAICompactSessionID(c))
@@ -2788,16 +2841,10 @@ func (s *OpenAIGatewayService) buildUpstreamRequest(ctx context.Context, c *gin.
 		}
 	}
 
-	// Apply custom User-Agent if configured
-	customUA := account.GetOpenAIUserAgent()
-	if customUA != "" {
-		req.Header.Set("user-agent", customUA)
-	}
-
-	// 若开启 ForceCodexCLI，则强制将上游 User-Agent 伪装为 Codex CLI。
+	// 应用账号自定义 / 模板 User-Agent；若开启 ForceCodexCLI，则强制将上游 User-Agent 伪装为 Codex CLI。
 	// 用于网关未透传/改写 User-Agent 时，仍能命中 Codex 侧识别逻辑。
-	if s.cfg != nil && s.cfg.Gateway.ForceCodexCLI {
-		req.Header.Set("user-agent", codexCLIUserAgent)
+	if ua := s.resolveUpstreamUserAgent(ctx, account, req.Header.Get("user-agent")); ua != "" {
+		req.Header.Set("user-agent", ua)
 	}
 
 	// Ensure required headers exist
@@ -2805,6 +2852,11 @@ func (s *OpenAIGatewayService) buildUpstreamRequest(ctx context.Context, c *gin.
 		req.Header.Set("content-type", "application/json")
 	}
 
+	// 按头策略额外透传、剥离或注入上游请求头
+	s.settingService.ApplyUpstreamHeaderPolicy(c, req, account)
+	// 账号与会话标识头最后按当前账号确定，不受客户端或头策略影响
+	s.scopeOpenAISessionHeaders(req.Header, account)
+
 	return req, nil
 }
 
@@ -2850,12 +2902,12 @@ func (s *OpenAIGatewayService) handleErrorResponse(
 		"upstream_error",
 		"Upstream request failed",
 	); matched {
-		c.JSON(status, gin.H{
-			"error": gin.H{
-				"type":    errType,
-				"message": errMsg,
-			},
-		})
+		if clientMsg := ScrubOpenAIClientErrorMessage(errMsg, account); clientMsg != "" {
+			errMsg = clientMsg
+		} else {
+			errMsg = "Upstream request failed"
+		}
+		WriteOpenAIError(c, OpenAIClientError{Status: status, Type: errType, Message: errMsg})
 		if upstreamMsg == "" {
 			upstreamMsg = errMsg
 		}
@@ -2877,11 +2929,11 @@ func (s *OpenAIGatewayService) handleErrorResponse(
 			Message:            upstreamMsg,
 			Detail:             upstreamDetail,
 		})
-		c.JSON(http.StatusInternalServerError, gin.H{
-			"error": gin.H{
-				"type":    "upstream_error",
-				"message": "Upstream gateway error",
-			},
+		WriteOpenAIError(c, OpenAIClientError{
+			Status:  http.StatusInternalServerError,
+			Type:    "upstream_error",
+			Code:    OpenAIErrorCodeUpstreamError,
+			Message: "Upstream gateway error",
 		})
 		if upstreamMsg == "" {
 			return nil, fmt.Errorf("upstream error: %d (not in custom error codes)", resp.StatusCode)
@@ -2913,38 +2965,7 @@ func (s *OpenAIGatewayService) handleErrorResponse(
 	}
 
 	// Return appropriate error response
-	var errType, errMsg string
-	var statusCode int
-
-	switch resp.StatusCode {
-	case 401:
-		statusCode = http.StatusBadGateway
-		errType = "upstream_error"
-		errMsg = "Upstream authentication failed, please contact administrator"
-	case 402:
-		statusCode = http.StatusBadGateway
-		errType = "upstream_error"
-		errMsg = "Upstream payment required: insufficient balance or billing issue"
-	case 403:
-		statusCode = http.StatusBadGateway
-		errType = "upstream_error"
-		errMsg = "Upstream access forbidden, please contact administrator"
-	case 429:
-		statusCode = http.StatusTooManyRequests
-		errType = "rate_limit_error"
-		errMsg = "Upstream rate limit exceeded, please retry later"
-	default:
-		statusCode = http.StatusBadGateway
-		errType = "upstream_error"
-		errMsg = "Upstream request failed"
-	}
-
-	c.JSON(statusCode, gin.H{
-		"error": gin.H{
-			"type":    errType,
-			"message": errMsg,
-		},
-	})
+	WriteOpenAIError(c, MapOpenAIUpstreamStatus(resp.StatusCode))
 
 	if upstreamMsg == "" {
 		return nil, fmt.Errorf("upstream error: %d", resp.StatusCode)
@@ -2959,6 +2980,17 @@ type openaiStreamingResult struct {
 }
 
 func (s *OpenAIGatewayService) handleStreamingResponse(ctx context.Context, resp *http.Response, c *gin.Context, account *Account, startTime time.Time, originalModel, mappedModel string) (*openaiStreamingResult, error) {
+	ctx, span := startStreamRelaySpan(ctx, account, mappedModel)
+	result, err := s.relayStreamingResponse(ctx, resp, c, account, startTime, originalModel, mappedModel)
+	if result != nil {
+		endStreamRelaySpan(span, result.firstTokenMs, false, err)
+	} else {
+		endStreamRelaySpan(span, nil, false, err)
+	}
+	return result, err
+}
+
+func (s *OpenAIGatewayService) relayStreamingResponse(ctx context.Context, resp *http.Response, c *gin.Context, account *Account, startTime time.Time, originalModel, mappedModel string) (*openaiStreamingResult, error) {
 	if s.responseHeaderFilter != nil {
 		responseheaders.WriteFilteredHeaders(c.Writer.Header(), resp.Header, s.responseHeaderFilter)
 	}
@@ -3014,10 +3046,7 @@ func (s *OpenAIGatewayService) handleStreamingResponse(ctx context.Context, resp
 		intervalCh = intervalTicker.C
 	}
 
-	keepaliveInterval := time.Duration(0)
-	if s.cfg != nil && s.cfg.Gateway.StreamKeepaliveInterval > 0 {
-		keepaliveInterval = time.Duration(s.cfg.Gateway.StreamKeepaliveInterval) * time.Second
-	}
+	keepaliveInterval := streamKeepaliveInterval(s.cfg)
 	// 下游 keepalive 仅用于防止代理空闲断开
 	var keepaliveTicker *time.Ticker
 	if keepaliveInterval > 0 {
@@ -3035,7 +3064,7 @@ func (s *OpenAIGatewayService) handleStreamingResponse(ctx context.Context, resp
 	// 注意：OpenAI `/v1/responses` streaming 事件必须符合 OpenAI Responses schema；
 	// 否则下游 SDK（例如 OpenCode）会因为类型校验失败而报错。
 	errorEventSent := false
-	clientDisconnected := false // 客户端断开后继续 drain 上游以收集 usage
+	clientDisconnected := false // 客户端断开后不再写出，上游随请求 context 取消而结束，按已收集 usage 计费
 	sendErrorEvent := func(reason string) {
 		if errorEventSent || clientDisconnected {
 			return
@@ -3112,7 +3141,7 @@ func (s *OpenAIGatewayService) handleStreamingResponse(ctx context.Context, resp
 				line = "data: " + data
 			}
 
-			// 写入客户端（客户端断开后继续 drain 上游）
+			// 写入客户端（客户端断开后跳过写出）
 			if !clientDisconnected {
 				shouldFlush := queueDrained
 				if firstTokenMs == nil && data != "" && data != "[DONE]" {
@@ -3121,14 +3150,14 @@ func (s *OpenAIGatewayService) handleStreamingResponse(ctx context.Context, resp
 				}
 				if _, err := bufferedWriter.WriteString(line); err != nil {
 					clientDisconnected = true
-					logger.LegacyPrintf("service.openai_gateway", "Client disconnected during streaming, continuing to drain upstream for billing")
+					logger.LegacyPrintf("service.openai_gateway", "Client disconnected during streaming, upstream request canceled, returning collected usage")
 				} else if _, err := bufferedWriter.WriteString("\n"); err != nil {
 					clientDisconnected = true
-					logger.LegacyPrintf("service.openai_gateway", "Client disconnected during streaming, continuing to drain upstream for billing")
+					logger.LegacyPrintf("service.openai_gateway", "Client disconnected during streaming, upstream request canceled, returning collected usage")
 				} else if shouldFlush {
 					if err := flushBuffered(); err != nil {
 						clientDisconnected = true
-						logger.LegacyPrintf("service.openai_gateway", "Client disconnected during streaming flush, continuing to drain upstream for billing")
+						logger.LegacyPrintf("service.openai_gateway", "Client disconnected during streaming flush, upstream request canceled, returning collected usage")
 					}
 				}
 			}
@@ -3146,14 +3175,14 @@ func (s *OpenAIGatewayService) handleStreamingResponse(ctx context.Context, resp
 		if !clientDisconnected {
 			if _, err := bufferedWriter.WriteString(line); err != nil {
 				clientDisconnected = true
-				logger.LegacyPrintf("service.openai_gateway", "Client disconnected during streaming, continuing to drain upstream for billing")
+				logger.LegacyPrintf("service.openai_gateway", "Client disconnected during streaming, upstream request canceled, returning collected usage")
 			} else if _, err := bufferedWriter.WriteString("\n"); err != nil {
 				clientDisconnected = true
-				logger.LegacyPrintf("service.openai_gateway", "Client disconnected during streaming, continuing to drain upstream for billing")
+				logger.LegacyPrintf("service.openai_gateway", "Client disconnected during streaming, upstream request canceled, returning collected usage")
 			} else if queueDrained {
 				if err := flushBuffered(); err != nil {
 					clientDisconnected = true
-					logger.LegacyPrintf("service.openai_gateway", "Client disconnected during streaming flush, continuing to drain upstream for billing")
+					logger.LegacyPrintf("service.openai_gateway", "Client disconnected during streaming flush, upstream request canceled, returning collected usage")
 				}
 			}
 		}
@@ -3240,12 +3269,12 @@ func (s *OpenAIGatewayService) handleStreamingResponse(ctx context.Context, resp
 			}
 			if _, err := bufferedWriter.WriteString(":\n\n"); err != nil {
 				clientDisconnected = true
-				logger.LegacyPrintf("service.openai_gateway", "Client disconnected during streaming, continuing to drain upstream for billing")
+				logger.LegacyPrintf("service.openai_gateway", "Client disconnected during streaming, upstream request canceled, returning collected usage")
 				continue
 			}
 			if err := flushBuffered(); err != nil {
 				clientDisconnected = true
-				logger.LegacyPrintf("service.openai_gateway", "Client disconnected during keepalive flush, continuing to drain upstream for billing")
+				logger.LegacyPrintf("service.openai_gateway", "Client disconnected during keepalive flush, upstream request canceled, returning collected usage")
 			}
 		}
 	}
@@ -3330,6 +3359,7 @@ func (s *OpenAIGatewayService) parseSSEUsageBytes(data []byte, usage *OpenAIUsag
 	usage.InputTokens = int(gjson.GetBytes(data, "response.usage.input_tokens").Int())
 	usage.OutputTokens = int(gjson.GetBytes(data, "response.usage.output_tokens").Int())
 	usage.CacheReadInputTokens = int(gjson.GetBytes(data, "response.usage.input_tokens_details.cached_tokens").Int())
+	usage.ReasoningTokens = int(gjson.GetBytes(data, "response.usage.output_tokens_details.reasoning_tokens").Int())
 }
 
 func extractOpenAIUsageFromJSONBytes(body []byte) (OpenAIUsage, bool) {
@@ -3341,11 +3371,13 @@ func extractOpenAIUsageFromJSONBytes(body []byte) (OpenAIUsage, bool) {
 		"usage.input_tokens",
 		"usage.output_tokens",
 		"usage.input_tokens_details.cached_tokens",
+		"usage.output_tokens_details.reasoning_tokens",
 	)
 	return OpenAIUsage{
 		InputTokens:          int(values[0].Int()),
 		OutputTokens:         int(values[1].Int()),
 		CacheReadInputTokens: int(values[2].Int()),
+		ReasoningTokens:      int(values[3].Int()),
 	}, true
 }
 
@@ -3686,16 +3718,38 @@ type OpenAIRecordUsageInput struct {
 	UserAgent     string // 请求的 User-Agent
 	IPAddress     string // 请求的客户端 IP 地址
 	APIKeyService APIKeyQuotaUpdater
+	// InputTokenEstimator 可选：上游未返回 usage 时按请求体本地估算输入 token（惰性调用，仅在需要时计数）
+	InputTokenEstimator func() int
 }
 
 // RecordUsage records usage and deducts balance
 func (s *OpenAIGatewayService) RecordUsage(ctx context.Context, input *OpenAIRecordUsageInput) error {
 	result := input.Result
+	recordAccountRequestLatency(input.Account, result.Duration, result.FirstTokenMs)
+	// 影子流量只参与延迟统计，不计费
+	if input.APIKey != nil && input.APIKey.Shadow {
+		return nil
+	}
 
-	// 跳过所有 token 均为零的用量记录——上游未返回 usage 时不应写入数据库
-	if result.Usage.InputTokens == 0 && result.Usage.OutputTokens == 0 &&
+	// 跳过所有 token 均为零的用量记录——上游未返回 usage 时不应写入数据库，
+	// 除非调用方提供了本地估算（按估算的输入 token 记录）
+	// 图片生成按张计费，即使上游未返回 usage 也需要记录
+	if result.ImageCount == 0 && result.Usage.InputTokens == 0 && result.Usage.OutputTokens == 0 &&
 		result.Usage.CacheCreationInputTokens == 0 && result.Usage.CacheReadInputTokens == 0 {
-		return nil
+		if input.InputTokenEstimator == nil {
+			return nil
+		}
+		estimated := input.InputTokenEstimator()
+		if estimated <= 0 {
+			return nil
+		}
+		logger.FromContext(ctx).Info("openai.record_usage_estimated_input_tokens",
+			zap.String("component", "service.openai_gateway"),
+			zap.String("request_id", result.RequestID),
+			zap.String("model", result.Model),
+			zap.Int("input_tokens", estimated),
+		)
+		result.Usage.InputTokens = estimated
 	}
 
 	apiKey := input.APIKey
@@ -3718,6 +3772,8 @@ func (s *OpenAIGatewayService) RecordUsage(ctx context.Context, input *OpenAIRec
 		CacheReadTokens:     result.Usage.CacheReadInputTokens,
 	}
 
+	s.recordOpenAIUsageWindow(account, int64(result.Usage.InputTokens+result.Usage.OutputTokens+result.Usage.CacheCreationInputTokens), time.Now())
+
 	// Get rate multiplier
 	multiplier := s.cfg.Default.RateMultiplier
 	if apiKey.GroupID != nil && apiKey.Group != nil {
@@ -3732,9 +3788,24 @@ func (s *OpenAIGatewayService) RecordUsage(ctx context.Context, input *OpenAIRec
 	if result.BillingModel != "" {
 		billingModel = result.BillingModel
 	}
-	cost, err := s.billingService.CalculateCost(billingModel, tokens, multiplier)
-	if err != nil {
-		cost = &CostBreakdown{ActualCost: 0}
+	var cost *CostBreakdown
+	if result.ImageCount > 0 {
+		// 图片生成计费
+		var groupConfig *ImagePriceConfig
+		if apiKey.Group != nil {
+			groupConfig = &ImagePriceConfig{
+				Price1K: apiKey.Group.ImagePrice1K,
+				Price2K: apiKey.Group.ImagePrice2K,
+				Price4K: apiKey.Group.ImagePrice4K,
+			}
+		}
+		cost = s.billingService.CalculateImageCost(billingModel, result.ImageSize, result.ImageCount, groupConfig, multiplier)
+	} else {
+		var err error
+		cost, err = s.billingService.CalculateCost(billingModel, tokens, multiplier)
+		if err != nil {
+			cost = &CostBreakdown{ActualCost: 0}
+		}
 	}
 
 	// Determine billing type
@@ -3747,17 +3818,24 @@ func (s *OpenAIGatewayService) RecordUsage(ctx context.Context, input *OpenAIRec
 	// Create usage log
 	durationMs := int(result.Duration.Milliseconds())
 	accountRateMultiplier := account.BillingRateMultiplier()
+	var imageSize *string
+	if result.ImageSize != "" {
+		imageSize = &result.ImageSize
+	}
 	usageLog := &UsageLog{
 		UserID:                user.ID,
 		APIKeyID:              apiKey.ID,
 		AccountID:             account.ID,
 		RequestID:             result.RequestID,
+		GatewayRequestID:      GatewayRequestIDFromContext(ctx),
+		ClientType:            ClientTypeFromContext(ctx),
 		Model:                 billingModel,
 		ReasoningEffort:       result.ReasoningEffort,
 		InputTokens:           actualInputTokens,
 		OutputTokens:          result.Usage.OutputTokens,
 		CacheCreationTokens:   result.Usage.CacheCreationInputTokens,
 		CacheReadTokens:       result.Usage.CacheReadInputTokens,
+		ReasoningTokens:       result.Usage.ReasoningTokens,
 		InputCost:             cost.InputCost,
 		OutputCost:            cost.OutputCost,
 		CacheCreationCost:     cost.CacheCreationCost,
@@ -3771,6 +3849,8 @@ func (s *OpenAIGatewayService) RecordUsage(ctx context.Context, input *OpenAIRec
 		OpenAIWSMode:          result.OpenAIWSMode,
 		DurationMs:            &durationMs,
 		FirstTokenMs:          result.FirstTokenMs,
+		ImageCount:            result.ImageCount,
+		ImageSize:             imageSize,
 		CreatedAt:             time.Now(),
 	}
 
@@ -3792,6 +3872,11 @@ func (s *OpenAIGatewayService) RecordUsage(ctx context.Context, input *OpenAIRec
 	}
 
 	inserted, err := s.usageLogRepo.Create(ctx, usageLog)
+	if inserted || err != nil {
+		recordUsageLogMetrics(usageLog)
+		publishUsageLogLiveEvent(usageLog, account.Platform)
+		s.usageWebhook.Enqueue(usageLog, account.Platform)
+	}
 	if s.cfg != nil && s.cfg.RunMode == config.RunModeSimple {
 		logger.LegacyPrintf("service.openai_gateway", "[SIMPLE MODE] Usage recorded (not billed): user=%d, tokens=%d", usageLog.UserID, usageLog.TotalTokens())
 		s.deferredService.ScheduleLastUsedUpdate(account.ID)
@@ -3809,6 +3894,7 @@ func (s *OpenAIGatewayService) RecordUsage(ctx context.Context, input *OpenAIRec
 			Subscription:          subscription,
 			IsSubscriptionBill:    isSubscriptionBilling,
 			AccountRateMultiplier: accountRateMultiplier,
+			TotalTokens:           usageLog.TotalTokens(),
 			APIKeyService:         input.APIKeyService,
 		}, s.billingDeps())
 	} else {
@@ -4070,6 +4156,7 @@ func (s *OpenAIGatewayService) UpdateCodexUsageSnapshotFromHeaders(ctx context.C
 	if accountID <= 0 || headers == nil {
 		return
 	}
+	s.recordOpenAIRateLimitHeaders(accountID, headers)
 	if snapshot := ParseCodexRateLimitHeaders(headers); snapshot != nil {
 		s.updateCodexUsageSnapshot(ctx, accountID, snapshot)
 	}
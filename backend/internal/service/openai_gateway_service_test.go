@@ -189,6 +189,33 @@ func TestOpenAIGatewayService_GenerateSessionHash_Priority(t *testing.T) {
 	}
 }
 
+func TestOpenAIGatewayService_GenerateSessionHash_AffinityHeader(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	rec := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(rec)
+	c.Request = httptest.NewRequest(http.MethodPost, "/openai/v1/responses", nil)
+	c.Request.Header.Set("session_id", "sess-123")
+
+	cfg := &config.Config{}
+	cfg.Gateway.SessionAffinityHeader = "X-Session-Affinity"
+	svc := &OpenAIGatewayService{cfg: cfg}
+	withoutAffinity := svc.GenerateSessionHash(c, nil)
+
+	// 亲和请求头优先于 session_id，且按 API Key 隔离
+	c.Request.Header.Set("X-Session-Affinity", "conv-1")
+	c.Set("api_key", &APIKey{ID: 1})
+	keyOne := svc.GenerateSessionHash(c, nil)
+	c.Set("api_key", &APIKey{ID: 2})
+	keyTwo := svc.GenerateSessionHash(c, nil)
+	require.NotEmpty(t, keyOne)
+	require.NotEqual(t, withoutAffinity, keyOne)
+	require.NotEqual(t, keyOne, keyTwo)
+
+	// 未配置请求头名称时忽略
+	cfg.Gateway.SessionAffinityHeader = ""
+	require.Equal(t, withoutAffinity, svc.GenerateSessionHash(c, nil))
+}
+
 func TestOpenAIGatewayService_GenerateSessionHash_UsesXXHash64(t *testing.T) {
 	gin.SetMode(gin.TestMode)
 	rec := httptest.NewRecorder()
@@ -1820,3 +1847,11 @@ func TestHandleOAuthSSEToJSON_ResponseFailedReturnsProtocolError(t *testing.T) {
 	require.Contains(t, rec.Body.String(), "upstream rejected request")
 	require.Contains(t, rec.Header().Get("Content-Type"), "application/json")
 }
+
+func TestResolveOpenAIMappedModel(t *testing.T) {
+	account := &Account{Credentials: map[string]any{"model_mapping": map[string]any{"gpt-4o": "gpt-4.1"}}}
+
+	require.Equal(t, "gpt-4.1", resolveOpenAIMappedModel(account, "gpt-4o", "gpt-5"), "account mapping wins over group default")
+	require.Equal(t, "gpt-5", resolveOpenAIMappedModel(account, "gpt-4o-mini", "gpt-5"), "unmapped model falls back to group default")
+	require.Equal(t, "gpt-4o-mini", resolveOpenAIMappedModel(account, "gpt-4o-mini", ""))
+}
@@ -0,0 +1,90 @@
+package service
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	infraerrors "github.com/ShaohongDong/sub2api/internal/pkg/errors"
+	"github.com/ShaohongDong/sub2api/internal/pkg/openai"
+)
+
+// OpenAIDeviceLoginResult is returned when a device code login is started.
+// The admin opens VerificationURL, enters UserCode and the UI polls with
+// SessionID every Interval seconds until the login completes.
+type OpenAIDeviceLoginResult struct {
+	SessionID       string `json:"session_id"`
+	VerificationURL string `json:"verification_url"`
+	UserCode        string `json:"user_code"`
+	Interval        int    `json:"interval"`
+	ExpiresIn       int    `json:"expires_in"`
+}
+
+// StartDeviceLogin starts a Codex device code login. Unlike GenerateAuthURL it
+// does not need a reachable redirect URI, so accounts can be added from a
+// headless deployment.
+func (s *OpenAIOAuthService) StartDeviceLogin(ctx context.Context, proxyID *int64) (*OpenAIDeviceLoginResult, error) {
+	proxyURL, err := s.resolveProxyURL(ctx, proxyID)
+	if err != nil {
+		return nil, err
+	}
+
+	sessionID, err := openai.GenerateSessionID()
+	if err != nil {
+		return nil, infraerrors.Newf(http.StatusInternalServerError, "OPENAI_OAUTH_SESSION_FAILED", "failed to generate session ID: %v", err)
+	}
+
+	codeResp, err := s.oauthClient.RequestDeviceCode(ctx, proxyURL, openai.ClientID)
+	if err != nil {
+		return nil, err
+	}
+
+	s.sessionStore.Set(sessionID, &openai.OAuthSession{
+		ClientID:     openai.ClientID,
+		RedirectURI:  openai.DeviceAuthRedirectURI,
+		ProxyURL:     proxyURL,
+		CreatedAt:    time.Now(),
+		DeviceAuthID: codeResp.DeviceAuthID,
+		UserCode:     codeResp.UserCode,
+	})
+
+	return &OpenAIDeviceLoginResult{
+		SessionID:       sessionID,
+		VerificationURL: openai.DeviceVerificationURL,
+		UserCode:        codeResp.UserCode,
+		Interval:        int(codeResp.PollInterval() / time.Second),
+		ExpiresIn:       int(openai.DeviceCodeTTL / time.Second),
+	}, nil
+}
+
+// PollDeviceLogin checks a device code login started by StartDeviceLogin.
+// It returns (nil, nil) while the user has not entered the code yet; once the
+// login is approved the authorization code is exchanged for tokens and the
+// session is removed.
+func (s *OpenAIOAuthService) PollDeviceLogin(ctx context.Context, sessionID string) (*OpenAITokenInfo, error) {
+	session, ok := s.sessionStore.Get(sessionID)
+	if !ok || session.DeviceAuthID == "" {
+		return nil, infraerrors.New(http.StatusBadRequest, "OPENAI_OAUTH_SESSION_NOT_FOUND", "session not found or expired")
+	}
+	if time.Since(session.CreatedAt) > openai.DeviceCodeTTL {
+		s.sessionStore.Delete(sessionID)
+		return nil, infraerrors.New(http.StatusBadRequest, "OPENAI_OAUTH_DEVICE_CODE_EXPIRED", "device code expired, please start a new login")
+	}
+
+	deviceResp, err := s.oauthClient.PollDeviceToken(ctx, session.DeviceAuthID, session.UserCode, session.ProxyURL)
+	if err != nil {
+		return nil, err
+	}
+	if deviceResp == nil {
+		return nil, nil
+	}
+
+	tokenResp, err := s.oauthClient.ExchangeCode(ctx, deviceResp.AuthorizationCode, deviceResp.CodeVerifier, session.RedirectURI, session.ProxyURL, session.ClientID)
+	if err != nil {
+		return nil, err
+	}
+
+	s.sessionStore.Delete(sessionID)
+
+	return newOpenAITokenInfo(tokenResp, session.ClientID), nil
+}
@@ -0,0 +1,86 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	infraerrors "github.com/ShaohongDong/sub2api/internal/pkg/errors"
+	"github.com/ShaohongDong/sub2api/internal/pkg/openai"
+	"github.com/stretchr/testify/require"
+)
+
+type openaiOAuthClientDeviceStub struct {
+	approved         bool
+	exchangeCode     string
+	exchangeVerifier string
+	exchangeRedirect string
+}
+
+func (s *openaiOAuthClientDeviceStub) ExchangeCode(ctx context.Context, code, codeVerifier, redirectURI, proxyURL, clientID string) (*openai.TokenResponse, error) {
+	s.exchangeCode = code
+	s.exchangeVerifier = codeVerifier
+	s.exchangeRedirect = redirectURI
+	return &openai.TokenResponse{AccessToken: "at", RefreshToken: "rt", ExpiresIn: 3600}, nil
+}
+
+func (s *openaiOAuthClientDeviceStub) RefreshToken(ctx context.Context, refreshToken, proxyURL string) (*openai.TokenResponse, error) {
+	return nil, errors.New("not implemented")
+}
+
+func (s *openaiOAuthClientDeviceStub) RefreshTokenWithClientID(ctx context.Context, refreshToken, proxyURL string, clientID string) (*openai.TokenResponse, error) {
+	return nil, errors.New("not implemented")
+}
+
+func (s *openaiOAuthClientDeviceStub) RequestDeviceCode(ctx context.Context, proxyURL, clientID string) (*openai.DeviceCodeResponse, error) {
+	return &openai.DeviceCodeResponse{DeviceAuthID: "dev-1", UserCode: "ABCD-1234"}, nil
+}
+
+func (s *openaiOAuthClientDeviceStub) PollDeviceToken(ctx context.Context, deviceAuthID, userCode, proxyURL string) (*openai.DeviceTokenResponse, error) {
+	if !s.approved {
+		return nil, nil
+	}
+	return &openai.DeviceTokenResponse{AuthorizationCode: "auth-code", CodeVerifier: "ver"}, nil
+}
+
+func TestOpenAIOAuthService_DeviceLogin(t *testing.T) {
+	client := &openaiOAuthClientDeviceStub{}
+	svc := NewOpenAIOAuthService(nil, client)
+	defer svc.Stop()
+
+	started, err := svc.StartDeviceLogin(context.Background(), nil)
+	require.NoError(t, err)
+	require.Equal(t, openai.DeviceVerificationURL, started.VerificationURL)
+	require.Equal(t, "ABCD-1234", started.UserCode)
+	require.Equal(t, int(openai.DefaultDevicePollInterval/time.Second), started.Interval)
+
+	info, err := svc.PollDeviceLogin(context.Background(), started.SessionID)
+	require.NoError(t, err)
+	require.Nil(t, info)
+
+	client.approved = true
+	info, err = svc.PollDeviceLogin(context.Background(), started.SessionID)
+	require.NoError(t, err)
+	require.Equal(t, "at", info.AccessToken)
+	require.Equal(t, openai.ClientID, info.ClientID)
+	require.Equal(t, "auth-code", client.exchangeCode)
+	require.Equal(t, "ver", client.exchangeVerifier)
+	require.Equal(t, openai.DeviceAuthRedirectURI, client.exchangeRedirect)
+
+	_, err = svc.PollDeviceLogin(context.Background(), started.SessionID)
+	require.Equal(t, "OPENAI_OAUTH_SESSION_NOT_FOUND", infraerrors.Reason(err))
+}
+
+func TestOpenAIOAuthService_DeviceLoginExpired(t *testing.T) {
+	svc := NewOpenAIOAuthService(nil, &openaiOAuthClientDeviceStub{approved: true})
+	defer svc.Stop()
+
+	svc.sessionStore.Set("sid", &openai.OAuthSession{
+		DeviceAuthID: "dev-1",
+		UserCode:     "ABCD-1234",
+		CreatedAt:    time.Now().Add(-openai.DeviceCodeTTL - time.Minute),
+	})
+	_, err := svc.PollDeviceLogin(context.Background(), "sid")
+	require.Equal(t, "OPENAI_OAUTH_DEVICE_CODE_EXPIRED", infraerrors.Reason(err))
+}
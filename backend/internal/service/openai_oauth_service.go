@@ -175,6 +175,15 @@ func (s *OpenAIOAuthService) ExchangeCode(ctx context.Context, input *OpenAIExch
 		return nil, err
 	}
 
+	// Delete session after successful exchange
+	s.sessionStore.Delete(input.SessionID)
+
+	return newOpenAITokenInfo(tokenResp, clientID), nil
+}
+
+// newOpenAITokenInfo builds token info from a code exchange response, filling
+// user info from the ID token when present
+func newOpenAITokenInfo(tokenResp *openai.TokenResponse, clientID string) *OpenAITokenInfo {
 	// Parse ID token to get user info
 	var userInfo *openai.UserInfo
 	if tokenResp.IDToken != "" {
@@ -186,9 +195,6 @@ func (s *OpenAIOAuthService) ExchangeCode(ctx context.Context, input *OpenAIExch
 		}
 	}
 
-	// Delete session after successful exchange
-	s.sessionStore.Delete(input.SessionID)
-
 	tokenInfo := &OpenAITokenInfo{
 		AccessToken:  tokenResp.AccessToken,
 		RefreshToken: tokenResp.RefreshToken,
@@ -206,7 +212,7 @@ func (s *OpenAIOAuthService) ExchangeCode(ctx context.Context, input *OpenAIExch
 		tokenInfo.PlanType = userInfo.PlanType
 	}
 
-	return tokenInfo, nil
+	return tokenInfo
 }
 
 // RefreshToken refreshes an OpenAI OAuth token
@@ -26,6 +26,14 @@ func (s *openaiOAuthClientNoopStub) RefreshTokenWithClientID(ctx context.Context
 	return nil, errors.New("not implemented")
 }
 
+func (s *openaiOAuthClientNoopStub) RequestDeviceCode(ctx context.Context, proxyURL, clientID string) (*openai.DeviceCodeResponse, error) {
+	return nil, errors.New("not implemented")
+}
+
+func (s *openaiOAuthClientNoopStub) PollDeviceToken(ctx context.Context, deviceAuthID, userCode, proxyURL string) (*openai.DeviceTokenResponse, error) {
+	return nil, errors.New("not implemented")
+}
+
 func TestOpenAIOAuthService_ExchangeSoraSessionToken_Success(t *testing.T) {
 	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		require.Equal(t, http.MethodGet, r.Method)
@@ -0,0 +1,120 @@
+package service
+
+import (
+	"context"
+	"log/slog"
+	"slices"
+	"strings"
+
+	"github.com/ShaohongDong/sub2api/internal/config"
+	"github.com/ShaohongDong/sub2api/internal/pkg/ctxkey"
+	"github.com/tidwall/gjson"
+)
+
+// codexPlanTypeExtraKey 上游响应中识别到的 ChatGPT 订阅计划（写入 extra），优先于 id_token 中的 plan_type：
+// 套餐升降级后 id_token 要等到下次刷新才会更新
+const codexPlanTypeExtraKey = "codex_plan_type"
+
+// OpenAIPlanType 返回 OpenAI OAuth 账号的订阅计划（小写，如 plus / pro / team）：
+// 上游响应中识别到的计划优先，其次为 id_token 的 plan_type；未知时返回空字符串
+func (a *Account) OpenAIPlanType() string {
+	if a == nil {
+		return ""
+	}
+	if plan := strings.TrimSpace(a.GetExtraString(codexPlanTypeExtraKey)); plan != "" {
+		return strings.ToLower(plan)
+	}
+	return strings.ToLower(strings.TrimSpace(a.GetCredential("plan_type")))
+}
+
+// WithReasoningEffort 记录请求的推理强度，供账号调度按订阅计划过滤
+func WithReasoningEffort(ctx context.Context, effort string) context.Context {
+	if effort == "" {
+		return ctx
+	}
+	return context.WithValue(ctx, ctxkey.ReasoningEffort, effort)
+}
+
+// ReasoningEffortFromContext 返回 WithReasoningEffort 记录的推理强度
+func ReasoningEffortFromContext(ctx context.Context) string {
+	if ctx == nil {
+		return ""
+	}
+	effort, _ := ctx.Value(ctxkey.ReasoningEffort).(string)
+	return effort
+}
+
+// RequestReasoningEffort 读取客户端在请求体中设置的推理强度，归一为 low / medium / high / xhigh；
+// 未设置或为 none / minimal 时返回空字符串
+func RequestReasoningEffort(body []byte, format string) string {
+	var raw string
+	switch format {
+	case ReasoningFormatAnthropic:
+		raw = gjson.GetBytes(body, "output_config.effort").String()
+	case ReasoningFormatResponses:
+		raw = gjson.GetBytes(body, "reasoning.effort").String()
+	case ReasoningFormatChatCompletions:
+		raw = gjson.GetBytes(body, "reasoning_effort").String()
+		if raw == "" {
+			raw = gjson.GetBytes(body, "reasoning.effort").String()
+		}
+	}
+	return normalizeOpenAIReasoningEffort(raw)
+}
+
+// openAIPlanAllowsRequest 判断 OpenAI OAuth 账号的订阅计划能否承接该上游模型与推理强度：
+// 先检查内置的计划限定模型，再检查 gateway.openai_plan_restrictions 中命中的规则。
+// API Key 账号与非 OpenAI 平台账号不受限制。
+func openAIPlanAllowsRequest(acc *Account, rules []config.GatewayOpenAIPlanRestriction, model, effort string) bool {
+	if acc == nil || acc.Platform != PlatformOpenAI || acc.Type != AccountTypeOAuth || model == "" {
+		return true
+	}
+	if !openAIPlanAllowsModel(acc, model) {
+		return false
+	}
+	plan := acc.OpenAIPlanType()
+	for i := range rules {
+		rule := &rules[i]
+		if !routingModelMatches(rule.Models, model) {
+			continue
+		}
+		if len(rule.ReasoningEfforts) > 0 && !slices.Contains(rule.ReasoningEfforts, effort) {
+			continue
+		}
+		if !slices.Contains(rule.Plans, plan) {
+			return false
+		}
+	}
+	return true
+}
+
+// isAccountPlanCompatible 按账号订阅计划判断能否承接本次请求（模型映射之后的上游模型 + 请求的推理强度）
+func (s *OpenAIGatewayService) isAccountPlanCompatible(ctx context.Context, account *Account, requestedModel string) bool {
+	if account == nil || requestedModel == "" {
+		return true
+	}
+	var rules []config.GatewayOpenAIPlanRestriction
+	if s != nil && s.cfg != nil {
+		rules = s.cfg.Gateway.OpenAIPlanRestrictions
+	}
+	return openAIPlanAllowsRequest(account, rules, account.GetMappedModel(requestedModel), ReasoningEffortFromContext(ctx))
+}
+
+// syncOpenAIPlanFromError 从上游错误体（如 usage_limit_reached 的 error.plan_type）识别账号的订阅计划，
+// 与当前记录不一致时写入 extra
+func (s *RateLimitService) syncOpenAIPlanFromError(ctx context.Context, account *Account, responseBody []byte) {
+	if account == nil || account.Platform != PlatformOpenAI || account.Type != AccountTypeOAuth || len(responseBody) == 0 {
+		return
+	}
+	plan := strings.ToLower(strings.TrimSpace(gjson.GetBytes(responseBody, "error.plan_type").String()))
+	if plan == "" || plan == account.OpenAIPlanType() {
+		return
+	}
+	updates := map[string]any{codexPlanTypeExtraKey: plan}
+	if err := s.accountRepo.UpdateExtra(ctx, account.ID, updates); err != nil {
+		slog.Warn("openai_plan_type_update_failed", "account_id", account.ID, "error", err)
+		return
+	}
+	mergeAccountExtra(account, updates)
+	slog.Info("openai_plan_type_detected", "account_id", account.ID, "plan_type", plan)
+}
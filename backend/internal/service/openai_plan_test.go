@@ -0,0 +1,79 @@
+//go:build unit
+
+package service
+
+import (
+	"context"
+	"testing"
+
+	"github.com/ShaohongDong/sub2api/internal/config"
+	"github.com/stretchr/testify/require"
+)
+
+func TestOpenAIPlanAllowsRequest(t *testing.T) {
+	plus := &Account{Platform: PlatformOpenAI, Type: AccountTypeOAuth, Credentials: map[string]any{"plan_type": "plus"}}
+	pro := &Account{Platform: PlatformOpenAI, Type: AccountTypeOAuth, Credentials: map[string]any{"plan_type": "Pro"}}
+	apiKey := &Account{Platform: PlatformOpenAI, Type: AccountTypeAPIKey}
+	rules := []config.GatewayOpenAIPlanRestriction{
+		{Models: []string{"gpt-5.1-codex-max"}, ReasoningEfforts: []string{"xhigh"}, Plans: []string{"pro"}},
+		{Models: []string{"gpt-5-pro*"}, Plans: []string{"pro", "team"}},
+	}
+
+	// 内置限制：spark 仅 Pro
+	require.False(t, openAIPlanAllowsRequest(plus, nil, "gpt-5.3-codex-spark", ""))
+	require.True(t, openAIPlanAllowsRequest(pro, nil, "gpt-5.3-codex-spark", ""))
+	require.True(t, openAIPlanAllowsRequest(apiKey, nil, "gpt-5.3-codex-spark", ""))
+
+	// 推理强度限定的规则只拦截对应强度
+	require.True(t, openAIPlanAllowsRequest(plus, rules, "gpt-5.1-codex-max", "high"))
+	require.False(t, openAIPlanAllowsRequest(plus, rules, "gpt-5.1-codex-max", "xhigh"))
+	require.True(t, openAIPlanAllowsRequest(pro, rules, "gpt-5.1-codex-max", "xhigh"))
+
+	require.False(t, openAIPlanAllowsRequest(plus, rules, "gpt-5-pro-2025", ""))
+	require.True(t, openAIPlanAllowsRequest(plus, rules, "gpt-5.1", ""))
+
+	// 上游识别到的计划优先于 id_token
+	upgraded := &Account{Platform: PlatformOpenAI, Type: AccountTypeOAuth,
+		Credentials: map[string]any{"plan_type": "plus"}, Extra: map[string]any{codexPlanTypeExtraKey: "pro"}}
+	require.Equal(t, "pro", upgraded.OpenAIPlanType())
+	require.True(t, openAIPlanAllowsRequest(upgraded, rules, "gpt-5.1-codex-max", "xhigh"))
+}
+
+func TestOpenAIGatewayService_IsAccountPlanCompatible(t *testing.T) {
+	cfg := &config.Config{}
+	cfg.Gateway.OpenAIPlanRestrictions = []config.GatewayOpenAIPlanRestriction{
+		{Models: []string{"gpt-5.1-codex-max"}, ReasoningEfforts: []string{"xhigh"}, Plans: []string{"pro"}},
+	}
+	svc := &OpenAIGatewayService{cfg: cfg}
+	// 按模型映射后的上游模型判断
+	plus := &Account{Platform: PlatformOpenAI, Type: AccountTypeOAuth, Credentials: map[string]any{
+		"plan_type":     "plus",
+		"model_mapping": map[string]any{"codex": "gpt-5.1-codex-max"},
+	}}
+
+	require.True(t, svc.isAccountPlanCompatible(context.Background(), plus, "codex"))
+	ctx := WithReasoningEffort(context.Background(), "xhigh")
+	require.False(t, svc.isAccountPlanCompatible(ctx, plus, "codex"))
+	require.True(t, svc.isAccountPlanCompatible(ctx, plus, ""))
+}
+
+func TestRequestReasoningEffort(t *testing.T) {
+	require.Equal(t, "xhigh", RequestReasoningEffort([]byte(`{"reasoning":{"effort":"x-high"}}`), ReasoningFormatResponses))
+	require.Equal(t, "low", RequestReasoningEffort([]byte(`{"reasoning_effort":"low"}`), ReasoningFormatChatCompletions))
+	require.Equal(t, "high", RequestReasoningEffort([]byte(`{"output_config":{"effort":"high"}}`), ReasoningFormatAnthropic))
+	require.Empty(t, RequestReasoningEffort([]byte(`{"reasoning":{"effort":"minimal"}}`), ReasoningFormatResponses))
+}
+
+func TestRateLimitService_SyncOpenAIPlanFromError(t *testing.T) {
+	repo := &openAI429SnapshotRepo{}
+	svc := NewRateLimitService(repo, nil, nil, nil, nil)
+	account := &Account{ID: 7, Platform: PlatformOpenAI, Type: AccountTypeOAuth, Credentials: map[string]any{"plan_type": "pro"}}
+
+	svc.syncOpenAIPlanFromError(context.Background(), account, []byte(`{"error":{"type":"usage_limit_reached","plan_type":"plus","resets_in_seconds":60}}`))
+	require.Equal(t, map[string]any{codexPlanTypeExtraKey: "plus"}, repo.updatedExtra)
+	require.Equal(t, "plus", account.OpenAIPlanType())
+
+	repo.updatedExtra = nil
+	svc.syncOpenAIPlanFromError(context.Background(), account, []byte(`{"error":{"type":"usage_limit_reached","plan_type":"plus"}}`))
+	require.Nil(t, repo.updatedExtra, "unchanged plan is not rewritten")
+}
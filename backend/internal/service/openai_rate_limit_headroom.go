@@ -0,0 +1,139 @@
+package service
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// openAIRateLimitHeadroomStaleAfter 未携带重置时间的限额快照的有效期
+const openAIRateLimitHeadroomStaleAfter = time.Minute
+
+// openAIRateLimitHeadroom 上游 x-ratelimit-* 响应头的最近一次快照（不可变，整体替换）
+type openAIRateLimitHeadroom struct {
+	requestsLimit     int64
+	requestsRemaining int64
+	requestsResetAt   time.Time
+	tokensLimit       int64
+	tokensRemaining   int64
+	tokensResetAt     time.Time
+	observedAt        time.Time
+}
+
+// parseOpenAIRateLimitHeaders 解析 x-ratelimit-{limit,remaining,reset}-{requests,tokens}，无相关头时返回 nil
+func parseOpenAIRateLimitHeaders(headers http.Header, now time.Time) *openAIRateLimitHeadroom {
+	if headers == nil {
+		return nil
+	}
+	h := &openAIRateLimitHeadroom{observedAt: now}
+	var hasRequests, hasTokens bool
+	h.requestsLimit, h.requestsRemaining, h.requestsResetAt, hasRequests = parseOpenAIRateLimitDimension(headers, "requests", now)
+	h.tokensLimit, h.tokensRemaining, h.tokensResetAt, hasTokens = parseOpenAIRateLimitDimension(headers, "tokens", now)
+	if !hasRequests && !hasTokens {
+		return nil
+	}
+	return h
+}
+
+func parseOpenAIRateLimitDimension(headers http.Header, dim string, now time.Time) (limit, remaining int64, resetAt time.Time, ok bool) {
+	limit, errLimit := strconv.ParseInt(strings.TrimSpace(headers.Get("x-ratelimit-limit-"+dim)), 10, 64)
+	remaining, errRemaining := strconv.ParseInt(strings.TrimSpace(headers.Get("x-ratelimit-remaining-"+dim)), 10, 64)
+	if errLimit != nil || errRemaining != nil || limit <= 0 {
+		return 0, 0, time.Time{}, false
+	}
+	if remaining < 0 {
+		remaining = 0
+	}
+	if d, ok := parseOpenAIRateLimitReset(headers.Get("x-ratelimit-reset-" + dim)); ok {
+		resetAt = now.Add(d)
+	}
+	return limit, remaining, resetAt, true
+}
+
+// parseOpenAIRateLimitReset 解析重置时间，支持 Go duration（"6m0s"、"20ms"）与纯秒数
+func parseOpenAIRateLimitReset(raw string) (time.Duration, bool) {
+	raw = strings.TrimSpace(raw)
+	if raw == "" {
+		return 0, false
+	}
+	if d, err := time.ParseDuration(raw); err == nil && d >= 0 {
+		return d, true
+	}
+	if sec, err := strconv.ParseFloat(raw, 64); err == nil && sec >= 0 {
+		return time.Duration(sec * float64(time.Second)), true
+	}
+	return 0, false
+}
+
+// ratio 返回单个维度的剩余比例；窗口已重置或快照过期时视为未知
+func (h *openAIRateLimitHeadroom) ratio(limit, remaining int64, resetAt, now time.Time) (float64, time.Time, bool) {
+	if limit <= 0 {
+		return 0, time.Time{}, false
+	}
+	if resetAt.IsZero() {
+		if now.Sub(h.observedAt) > openAIRateLimitHeadroomStaleAfter {
+			return 0, time.Time{}, false
+		}
+	} else if !now.Before(resetAt) {
+		return 0, time.Time{}, false
+	}
+	return clamp01(float64(remaining) / float64(limit)), resetAt, true
+}
+
+// openAIAccountHeadroom 计算账号剩余限额因子（0~1，未知为 1），并判断是否已耗尽到重置前
+func openAIAccountHeadroom(account *Account, h *openAIRateLimitHeadroom, now time.Time) (factor float64, exhausted bool) {
+	factor = 1
+	if h != nil {
+		if r, resetAt, ok := h.ratio(h.requestsLimit, h.requestsRemaining, h.requestsResetAt, now); ok {
+			factor = min(factor, r)
+			exhausted = exhausted || (r <= 0 && !resetAt.IsZero())
+		}
+		if r, resetAt, ok := h.ratio(h.tokensLimit, h.tokensRemaining, h.tokensResetAt, now); ok {
+			factor = min(factor, r)
+			exhausted = exhausted || (r <= 0 && !resetAt.IsZero())
+		}
+	}
+	if account != nil && account.IsOpenAIOAuth() {
+		for _, window := range []string{"5h", "7d"} {
+			progress := buildCodexUsageProgressFromExtra(account.Extra, window, now)
+			if progress == nil || progress.ResetsAt == nil || !now.Before(*progress.ResetsAt) {
+				continue
+			}
+			factor = min(factor, 1-clamp01(progress.Utilization/100))
+		}
+	}
+	return factor, exhausted
+}
+
+func (s *openAIAccountRuntimeStats) recordHeadroom(accountID int64, h *openAIRateLimitHeadroom) {
+	if s == nil || accountID <= 0 || h == nil {
+		return
+	}
+	s.headroom.Store(accountID, h)
+}
+
+func (s *openAIAccountRuntimeStats) loadHeadroom(accountID int64) *openAIRateLimitHeadroom {
+	if s == nil || accountID <= 0 {
+		return nil
+	}
+	value, ok := s.headroom.Load(accountID)
+	if !ok {
+		return nil
+	}
+	h, _ := value.(*openAIRateLimitHeadroom)
+	return h
+}
+
+// recordOpenAIRateLimitHeaders 记录上游限额响应头，供调度器优先选择剩余限额多的账号
+func (s *OpenAIGatewayService) recordOpenAIRateLimitHeaders(accountID int64, headers http.Header) {
+	if s == nil || accountID <= 0 {
+		return
+	}
+	h := parseOpenAIRateLimitHeaders(headers, time.Now())
+	if h == nil {
+		return
+	}
+	s.getOpenAIAccountScheduler()
+	s.openaiAccountStats.recordHeadroom(accountID, h)
+}
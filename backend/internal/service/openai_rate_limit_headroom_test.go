@@ -0,0 +1,146 @@
+//go:build unit
+
+package service
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/ShaohongDong/sub2api/internal/config"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseOpenAIRateLimitHeaders(t *testing.T) {
+	now := time.Now()
+	headers := http.Header{}
+	headers.Set("x-ratelimit-limit-requests", "100")
+	headers.Set("x-ratelimit-remaining-requests", "25")
+	headers.Set("x-ratelimit-reset-requests", "6m0s")
+	headers.Set("x-ratelimit-limit-tokens", "10000")
+	headers.Set("x-ratelimit-remaining-tokens", "9000")
+	headers.Set("x-ratelimit-reset-tokens", "20ms")
+
+	h := parseOpenAIRateLimitHeaders(headers, now)
+	require.NotNil(t, h)
+	require.Equal(t, int64(100), h.requestsLimit)
+	require.Equal(t, int64(25), h.requestsRemaining)
+	require.Equal(t, now.Add(6*time.Minute), h.requestsResetAt)
+	require.Equal(t, now.Add(20*time.Millisecond), h.tokensResetAt)
+
+	factor, exhausted := openAIAccountHeadroom(nil, h, now)
+	require.InDelta(t, 0.25, factor, 1e-9)
+	require.False(t, exhausted)
+
+	require.Nil(t, parseOpenAIRateLimitHeaders(http.Header{}, now))
+}
+
+func TestParseOpenAIRateLimitReset(t *testing.T) {
+	d, ok := parseOpenAIRateLimitReset("1m30s")
+	require.True(t, ok)
+	require.Equal(t, 90*time.Second, d)
+
+	d, ok = parseOpenAIRateLimitReset("2.5")
+	require.True(t, ok)
+	require.Equal(t, 2500*time.Millisecond, d)
+
+	_, ok = parseOpenAIRateLimitReset("soon")
+	require.False(t, ok)
+}
+
+func TestOpenAIAccountHeadroom_ExhaustedUntilReset(t *testing.T) {
+	now := time.Now()
+	h := &openAIRateLimitHeadroom{
+		requestsLimit:     60,
+		requestsRemaining: 0,
+		requestsResetAt:   now.Add(10 * time.Second),
+		observedAt:        now,
+	}
+
+	factor, exhausted := openAIAccountHeadroom(nil, h, now)
+	require.Zero(t, factor)
+	require.True(t, exhausted)
+
+	// 重置时间过后快照失效
+	factor, exhausted = openAIAccountHeadroom(nil, h, now.Add(11*time.Second))
+	require.Equal(t, 1.0, factor)
+	require.False(t, exhausted)
+}
+
+func TestOpenAIAccountHeadroom_StaleSnapshotWithoutReset(t *testing.T) {
+	now := time.Now()
+	h := &openAIRateLimitHeadroom{requestsLimit: 10, requestsRemaining: 2, observedAt: now}
+
+	factor, _ := openAIAccountHeadroom(nil, h, now)
+	require.InDelta(t, 0.2, factor, 1e-9)
+
+	factor, _ = openAIAccountHeadroom(nil, h, now.Add(2*openAIRateLimitHeadroomStaleAfter))
+	require.Equal(t, 1.0, factor)
+}
+
+func TestOpenAIAccountHeadroom_CodexUsageWindows(t *testing.T) {
+	now := time.Now()
+	account := &Account{
+		Platform: PlatformOpenAI,
+		Type:     AccountTypeOAuth,
+		Extra: map[string]any{
+			"codex_5h_used_percent": 40.0,
+			"codex_5h_reset_at":     now.Add(time.Hour).Format(time.RFC3339),
+			"codex_7d_used_percent": 90.0,
+			"codex_7d_reset_at":     now.Add(48 * time.Hour).Format(time.RFC3339),
+		},
+	}
+	factor, exhausted := openAIAccountHeadroom(account, nil, now)
+	require.InDelta(t, 0.1, factor, 1e-9)
+	require.False(t, exhausted)
+
+	// 已过重置时间的窗口不再计入
+	account.Extra["codex_7d_reset_at"] = now.Add(-time.Minute).Format(time.RFC3339)
+	factor, _ = openAIAccountHeadroom(account, nil, now)
+	require.InDelta(t, 0.6, factor, 1e-9)
+}
+
+func TestOpenAIGatewayService_RecordOpenAIRateLimitHeaders(t *testing.T) {
+	svc := &OpenAIGatewayService{}
+	headers := http.Header{}
+	headers.Set("x-ratelimit-limit-requests", "10")
+	headers.Set("x-ratelimit-remaining-requests", "0")
+	headers.Set("x-ratelimit-reset-requests", "30s")
+
+	svc.recordOpenAIRateLimitHeaders(7, headers)
+	h := svc.openaiAccountStats.loadHeadroom(7)
+	require.NotNil(t, h)
+	_, exhausted := openAIAccountHeadroom(nil, h, time.Now())
+	require.True(t, exhausted)
+}
+
+func TestOpenAIGatewayService_SelectAccountWithScheduler_SkipsExhaustedHeadroom(t *testing.T) {
+	groupID := int64(12)
+	accounts := []Account{
+		{ID: 4001, Platform: PlatformOpenAI, Type: AccountTypeAPIKey, Status: StatusActive, Schedulable: true, Concurrency: 1, Priority: 0},
+		{ID: 4002, Platform: PlatformOpenAI, Type: AccountTypeAPIKey, Status: StatusActive, Schedulable: true, Concurrency: 1, Priority: 5},
+	}
+	svc := &OpenAIGatewayService{
+		accountRepo: stubOpenAIAccountRepo{accounts: accounts},
+		cache:       &stubGatewayCache{},
+		cfg:         &config.Config{},
+		concurrencyService: NewConcurrencyService(stubConcurrencyCache{
+			acquireResults: map[int64]bool{4001: true, 4002: true},
+		}),
+	}
+
+	headers := http.Header{}
+	headers.Set("x-ratelimit-limit-requests", "100")
+	headers.Set("x-ratelimit-remaining-requests", "0")
+	headers.Set("x-ratelimit-reset-requests", "1m")
+	svc.recordOpenAIRateLimitHeaders(4001, headers)
+
+	selection, decision, err := svc.SelectAccountWithScheduler(context.Background(), &groupID, "", "", "gpt-5.1", nil, OpenAIUpstreamTransportAny)
+	require.NoError(t, err)
+	require.Equal(t, int64(4002), selection.Account.ID)
+	require.Equal(t, 1, decision.CandidateCount)
+	if selection.ReleaseFunc != nil {
+		selection.ReleaseFunc()
+	}
+}
@@ -0,0 +1,64 @@
+package service
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/ShaohongDong/sub2api/internal/config"
+)
+
+// openAISessionHeaderNames 需要按账号派生的上游会话标识头
+var openAISessionHeaderNames = []string{"session_id", "conversation_id"}
+
+// scopeOpenAISessionHeaders 在上游请求头构造完成后统一处理会话与账号标识：
+//   - chatgpt-account-id 只取当前账号的值，清除客户端或头策略带入的其他值；
+//   - 启用 gateway.openai_session_headers 时，session_id / conversation_id 改为按账号派生的值。
+//
+// 每次选中账号都会重新构造请求头，故障转移到其他账号时不会沿用上一个账号的标识。
+func (s *OpenAIGatewayService) scopeOpenAISessionHeaders(h http.Header, account *Account) {
+	if h == nil || account == nil {
+		return
+	}
+	h.Del("chatgpt-account-id")
+	if account.Type == AccountTypeOAuth {
+		if chatgptAccountID := account.GetChatGPTAccountID(); chatgptAccountID != "" {
+			h.Set("chatgpt-account-id", chatgptAccountID)
+		}
+	}
+	for _, name := range openAISessionHeaderNames {
+		if value := strings.TrimSpace(h.Get(name)); value != "" {
+			h.Set(name, s.scopedOpenAISessionValue(account, value))
+		}
+	}
+}
+
+// scopedOpenAISessionValue 由「账号 + 客户端会话标识 + 轮换周期」派生发往上游的会话标识（UUID 格式）：
+// 同一会话在同一账号、同一轮换周期内保持稳定；未启用时原样返回
+func (s *OpenAIGatewayService) scopedOpenAISessionValue(account *Account, value string) string {
+	cfg := s.openAISessionHeadersConfig()
+	if !cfg.Enabled || account == nil || value == "" {
+		return value
+	}
+	epoch := openAISessionRotationEpoch(account.ID, cfg.RotationHours, time.Now())
+	return generateSessionUUID(fmt.Sprintf("%d:%d:%s", account.ID, epoch, value))
+}
+
+func (s *OpenAIGatewayService) openAISessionHeadersConfig() config.GatewayOpenAISessionHeadersConfig {
+	if s == nil || s.cfg == nil {
+		return config.GatewayOpenAISessionHeadersConfig{}
+	}
+	return s.cfg.Gateway.OpenAISessionHeaders
+}
+
+// openAISessionRotationEpoch 返回账号当前所处的轮换周期序号；rotationHours <= 0 时不轮换（恒为 0）。
+// 按账号错开轮换时刻，避免所有账号的会话在同一时间点整体更换标识。
+func openAISessionRotationEpoch(accountID int64, rotationHours int, now time.Time) int64 {
+	if rotationHours <= 0 {
+		return 0
+	}
+	period := int64(rotationHours) * int64(time.Hour/time.Second)
+	offset := int64(uint64(accountID) * 2654435761 % uint64(period))
+	return (now.Unix() + offset) / period
+}
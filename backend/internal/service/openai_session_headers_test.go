@@ -0,0 +1,75 @@
+package service
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/ShaohongDong/sub2api/internal/config"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/require"
+)
+
+func TestScopeOpenAISessionHeaders(t *testing.T) {
+	cfg := &config.Config{}
+	cfg.Gateway.OpenAISessionHeaders = config.GatewayOpenAISessionHeadersConfig{Enabled: true}
+	svc := &OpenAIGatewayService{cfg: cfg}
+	accountA := &Account{ID: 1, Platform: PlatformOpenAI, Type: AccountTypeOAuth, Credentials: map[string]any{"chatgpt_account_id": "acc-a"}}
+	accountB := &Account{ID: 2, Platform: PlatformOpenAI, Type: AccountTypeOAuth, Credentials: map[string]any{"chatgpt_account_id": "acc-b"}}
+
+	build := func(account *Account) http.Header {
+		h := http.Header{}
+		h.Set("session_id", "client-session")
+		h.Set("conversation_id", "client-session")
+		h.Set("chatgpt-account-id", "acc-a")
+		svc.scopeOpenAISessionHeaders(h, account)
+		return h
+	}
+
+	first := build(accountA)
+	require.Equal(t, "acc-a", first.Get("chatgpt-account-id"))
+	require.NotEqual(t, "client-session", first.Get("session_id"))
+	require.Equal(t, first.Get("session_id"), first.Get("conversation_id"))
+	require.Equal(t, first.Get("session_id"), build(accountA).Get("session_id"), "stable per account and session")
+
+	// 故障转移到其他账号：不沿用上一个账号的任何标识
+	failover := build(accountB)
+	require.Equal(t, "acc-b", failover.Get("chatgpt-account-id"))
+	require.NotEqual(t, first.Get("session_id"), failover.Get("session_id"))
+
+	apiKey := build(&Account{ID: 3, Platform: PlatformOpenAI, Type: AccountTypeAPIKey})
+	require.Empty(t, apiKey.Get("chatgpt-account-id"))
+
+	// 未启用时原样透传会话标识
+	svc.cfg = &config.Config{}
+	require.Equal(t, "client-session", build(accountA).Get("session_id"))
+}
+
+func TestOpenAISessionRotationEpoch(t *testing.T) {
+	now := time.Unix(1_700_000_000, 0)
+	require.Zero(t, openAISessionRotationEpoch(1, 0, now))
+
+	epoch := openAISessionRotationEpoch(1, 24, now)
+	require.Equal(t, epoch+1, openAISessionRotationEpoch(1, 24, now.Add(24*time.Hour)))
+}
+
+func TestOpenAIBuildUpstreamRequestScopesSessionHeaders(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	rec := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(rec)
+	c.Request = httptest.NewRequest(http.MethodPost, "/v1/responses", bytes.NewReader([]byte(`{"model":"gpt-5"}`)))
+	c.Request.Header.Set("session_id", "client-session")
+
+	cfg := &config.Config{}
+	cfg.Gateway.OpenAISessionHeaders = config.GatewayOpenAISessionHeadersConfig{Enabled: true, RotationHours: 24}
+	svc := &OpenAIGatewayService{cfg: cfg}
+	account := &Account{ID: 9, Platform: PlatformOpenAI, Type: AccountTypeOAuth, Credentials: map[string]any{"chatgpt_account_id": "chatgpt-acc"}}
+
+	req, err := svc.buildUpstreamRequest(c.Request.Context(), c, account, []byte(`{"model":"gpt-5"}`), "token", true, "", true)
+	require.NoError(t, err)
+	require.Equal(t, "chatgpt-acc", req.Header.Get("chatgpt-account-id"))
+	require.Equal(t, svc.scopedOpenAISessionValue(account, "client-session"), req.Header.Get("session_id"))
+	require.NotEqual(t, "client-session", req.Header.Get("session_id"))
+}
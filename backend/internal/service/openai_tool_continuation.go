@@ -46,7 +46,8 @@ func NeedsToolContinuation(reqBody map[string]any) bool {
 			continue
 		}
 		itemType, _ := itemMap["type"].(string)
-		if itemType == "function_call_output" || itemType == "item_reference" {
+		// mcp_approval_response 需引用上一轮响应中的 mcp_approval_request
+		if itemType == "function_call_output" || itemType == "item_reference" || itemType == "mcp_approval_response" {
 			return true
 		}
 	}
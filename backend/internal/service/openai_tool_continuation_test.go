@@ -18,6 +18,7 @@ func TestNeedsToolContinuationSignals(t *testing.T) {
 		{name: "previous_response_id_blank", body: map[string]any{"previous_response_id": "  "}, want: false},
 		{name: "function_call_output", body: map[string]any{"input": []any{map[string]any{"type": "function_call_output"}}}, want: true},
 		{name: "item_reference", body: map[string]any{"input": []any{map[string]any{"type": "item_reference"}}}, want: true},
+		{name: "mcp_approval_response", body: map[string]any{"input": []any{map[string]any{"type": "mcp_approval_response"}}}, want: true},
 		{name: "tools", body: map[string]any{"tools": []any{map[string]any{"type": "function"}}}, want: true},
 		{name: "tools_empty", body: map[string]any{"tools": []any{}}, want: false},
 		{name: "tools_invalid", body: map[string]any{"tools": "bad"}, want: false},
@@ -0,0 +1,234 @@
+package service
+
+import (
+	"fmt"
+	"math"
+	"sync"
+	"time"
+)
+
+// Codex 用量窗口
+const (
+	OpenAIUsageWindow5h = "5h"
+	OpenAIUsageWindow7d = "7d"
+)
+
+const (
+	openAIUsageWindowBucketSize = 5 * time.Minute
+	openAIUsageWindowMaxSpan    = 7 * 24 * time.Hour
+	openAIUsageWindowBuckets    = int(openAIUsageWindowMaxSpan / openAIUsageWindowBucketSize)
+	// openAIUsageWindowBurnSpan 计算近期消耗速率的回看时长
+	openAIUsageWindowBurnSpan = 15 * time.Minute
+	// openAIUsageWindowMinCalibrationPercent 上游用量百分比低于该值时不用于估算窗口上限（误差过大）
+	openAIUsageWindowMinCalibrationPercent = 1.0
+)
+
+func openAIUsageWindowLength(window string) time.Duration {
+	if window == OpenAIUsageWindow5h {
+		return 5 * time.Hour
+	}
+	return openAIUsageWindowMaxSpan
+}
+
+// OpenAIUsageWindowStatus 账号在单个用量窗口内的本地统计与预测
+type OpenAIUsageWindowStatus struct {
+	Window   string `json:"window"`
+	Requests int64  `json:"requests"`
+	Tokens   int64  `json:"tokens"`
+	// UpstreamUsedPercent 上游最近一次返回的用量百分比
+	UpstreamUsedPercent *float64 `json:"upstream_used_percent,omitempty"`
+	// EstimatedTokenLimit 窗口 token 上限（配置值或按上游百分比估算），0 表示未知
+	EstimatedTokenLimit int64      `json:"estimated_token_limit"`
+	UsedPercent         float64    `json:"used_percent"`
+	RemainingPercent    float64    `json:"remaining_percent"`
+	PredictedPercent    float64    `json:"predicted_percent"`
+	ResetsAt            *time.Time `json:"resets_at,omitempty"`
+	WillExceed          bool       `json:"will_exceed"`
+}
+
+type openAIUsageWindowBucket struct {
+	slot     int64
+	requests int64
+	tokens   int64
+}
+
+// openAIUsageWindowCounter 以 5 分钟为粒度的 7 天环形计数
+type openAIUsageWindowCounter struct {
+	mu      sync.Mutex
+	buckets [openAIUsageWindowBuckets]openAIUsageWindowBucket
+}
+
+func openAIUsageWindowSlot(t time.Time) int64 {
+	return t.Unix() / int64(openAIUsageWindowBucketSize/time.Second)
+}
+
+func (c *openAIUsageWindowCounter) record(tokens int64, now time.Time) {
+	slot := openAIUsageWindowSlot(now)
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	b := &c.buckets[slot%int64(openAIUsageWindowBuckets)]
+	if b.slot != slot {
+		*b = openAIUsageWindowBucket{slot: slot}
+	}
+	b.requests++
+	b.tokens += tokens
+}
+
+// sum 统计 [since, until] 内的请求数与 token 数（按桶粒度）
+func (c *openAIUsageWindowCounter) sum(since, until time.Time) (requests, tokens int64) {
+	from, to := openAIUsageWindowSlot(since), openAIUsageWindowSlot(until)
+	if to-from >= int64(openAIUsageWindowBuckets) {
+		from = to - int64(openAIUsageWindowBuckets) + 1
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for slot := from; slot <= to; slot++ {
+		b := c.buckets[slot%int64(openAIUsageWindowBuckets)]
+		if b.slot == slot {
+			requests += b.requests
+			tokens += b.tokens
+		}
+	}
+	return requests, tokens
+}
+
+// SetSharedStateService 注入跨实例共享状态；启用后用量窗口按所有实例的流量统计
+func (s *OpenAIGatewayService) SetSharedStateService(sharedState *SharedStateService) {
+	s.sharedState = sharedState
+}
+
+func (s *OpenAIGatewayService) usageWindowCounter(accountID int64, create bool) *openAIUsageWindowCounter {
+	if !create {
+		if counter := s.sharedState.openAIUsageCounter(accountID, time.Now()); counter != nil {
+			return counter
+		}
+	}
+	if value, ok := s.openaiUsageWindows.Load(accountID); ok {
+		return value.(*openAIUsageWindowCounter)
+	}
+	if !create {
+		return nil
+	}
+	value, _ := s.openaiUsageWindows.LoadOrStore(accountID, &openAIUsageWindowCounter{})
+	return value.(*openAIUsageWindowCounter)
+}
+
+// recordOpenAIUsageWindow 累计 Codex OAuth 账号的本地用量
+func (s *OpenAIGatewayService) recordOpenAIUsageWindow(account *Account, tokens int64, now time.Time) {
+	if s == nil || account == nil || account.ID <= 0 || !account.IsOpenAIOAuth() {
+		return
+	}
+	if tokens < 0 {
+		tokens = 0
+	}
+	s.usageWindowCounter(account.ID, true).record(tokens, now)
+	s.sharedState.recordOpenAIUsage(account.ID, tokens, now)
+}
+
+// OpenAIUsageWindowStatuses 返回账号 5 小时与 7 天窗口的本地用量与预测
+func (s *OpenAIGatewayService) OpenAIUsageWindowStatuses(account *Account, now time.Time) []OpenAIUsageWindowStatus {
+	if s == nil || account == nil {
+		return nil
+	}
+	counter := s.usageWindowCounter(account.ID, false)
+	if counter == nil {
+		counter = &openAIUsageWindowCounter{}
+	}
+	return []OpenAIUsageWindowStatus{
+		s.openAIUsageWindowStatus(account, counter, OpenAIUsageWindow5h, now),
+		s.openAIUsageWindowStatus(account, counter, OpenAIUsageWindow7d, now),
+	}
+}
+
+// openAIUsageWindowWillExceed 预测账号是否会在 lookahead 内超出任一窗口
+func (s *OpenAIGatewayService) openAIUsageWindowWillExceed(account *Account, now time.Time) bool {
+	if s == nil || s.cfg == nil || !s.cfg.Gateway.OpenAIUsageWindow.Enabled || account == nil || !account.IsOpenAIOAuth() {
+		return false
+	}
+	counter := s.usageWindowCounter(account.ID, false)
+	if counter == nil {
+		return false
+	}
+	for _, window := range []string{OpenAIUsageWindow5h, OpenAIUsageWindow7d} {
+		if s.openAIUsageWindowStatus(account, counter, window, now).WillExceed {
+			return true
+		}
+	}
+	return false
+}
+
+func (s *OpenAIGatewayService) openAIUsageWindowStatus(account *Account, counter *openAIUsageWindowCounter, window string, now time.Time) OpenAIUsageWindowStatus {
+	length := openAIUsageWindowLength(window)
+	status := OpenAIUsageWindowStatus{Window: window}
+
+	var lookahead time.Duration
+	var threshold float64 = 100
+	var configuredLimit int64
+	if s.cfg != nil {
+		usageCfg := s.cfg.Gateway.OpenAIUsageWindow
+		lookahead = time.Duration(usageCfg.LookaheadSeconds) * time.Second
+		if usageCfg.StopThresholdPercent > 0 {
+			threshold = usageCfg.StopThresholdPercent
+		}
+		configuredLimit = usageCfg.FiveHourTokenLimit
+		if window == OpenAIUsageWindow7d {
+			configuredLimit = usageCfg.WeeklyTokenLimit
+		}
+	}
+
+	// 上游快照给出了窗口重置时间时按固定窗口统计，否则按滚动窗口统计
+	windowStart := now.Add(-length)
+	progress := buildCodexUsageProgressFromExtra(account.Extra, window, now)
+	if progress != nil && progress.ResetsAt != nil && now.Before(*progress.ResetsAt) {
+		status.ResetsAt = progress.ResetsAt
+		windowStart = progress.ResetsAt.Add(-length)
+		used := progress.Utilization
+		status.UpstreamUsedPercent = &used
+	} else {
+		progress = nil
+	}
+	status.Requests, status.Tokens = counter.sum(windowStart, now)
+
+	limit := configuredLimit
+	snapshotAt := now
+	if progress != nil {
+		if raw, ok := account.Extra["codex_usage_updated_at"]; ok {
+			if t, err := parseTime(fmt.Sprint(raw)); err == nil && !t.After(now) {
+				snapshotAt = t
+			}
+		}
+		if limit <= 0 && progress.Utilization >= openAIUsageWindowMinCalibrationPercent {
+			if _, tokensAtSnapshot := counter.sum(windowStart, snapshotAt); tokensAtSnapshot > 0 {
+				limit = int64(float64(tokensAtSnapshot) * 100 / progress.Utilization)
+			}
+		}
+	}
+	status.EstimatedTokenLimit = limit
+
+	switch {
+	case progress != nil:
+		status.UsedPercent = progress.Utilization
+		// 快照之后的本地用量（跳过快照所在的桶，避免与上游百分比重复计算）
+		if limit > 0 && snapshotAt.Before(now) {
+			if _, since := counter.sum(snapshotAt.Add(openAIUsageWindowBucketSize), now); since > 0 {
+				status.UsedPercent += float64(since) * 100 / float64(limit)
+			}
+		}
+	case limit > 0:
+		status.UsedPercent = float64(status.Tokens) * 100 / float64(limit)
+	}
+
+	status.PredictedPercent = status.UsedPercent
+	if limit > 0 && lookahead > 0 {
+		horizon := lookahead
+		if status.ResetsAt != nil && status.ResetsAt.Sub(now) < horizon {
+			horizon = status.ResetsAt.Sub(now)
+		}
+		_, recent := counter.sum(now.Add(-openAIUsageWindowBurnSpan), now)
+		projected := float64(recent) * (float64(horizon) / float64(openAIUsageWindowBurnSpan))
+		status.PredictedPercent += projected * 100 / float64(limit)
+	}
+	status.RemainingPercent = math.Max(0, 100-status.UsedPercent)
+	status.WillExceed = (progress != nil || limit > 0) && status.PredictedPercent >= threshold
+	return status
+}
@@ -0,0 +1,133 @@
+//go:build unit
+
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/ShaohongDong/sub2api/internal/config"
+	"github.com/stretchr/testify/require"
+)
+
+func newUsageWindowTestService(usageCfg config.GatewayOpenAIUsageWindowConfig) *OpenAIGatewayService {
+	cfg := &config.Config{}
+	cfg.Gateway.OpenAIUsageWindow = usageCfg
+	return &OpenAIGatewayService{cfg: cfg}
+}
+
+func newUsageWindowTestAccount(id int64, extra map[string]any) *Account {
+	return &Account{ID: id, Platform: PlatformOpenAI, Type: AccountTypeOAuth, Status: StatusActive, Schedulable: true, Concurrency: 1, Extra: extra}
+}
+
+func TestOpenAIUsageWindowCounter_SumDropsExpiredBuckets(t *testing.T) {
+	now := time.Now()
+	c := &openAIUsageWindowCounter{}
+	c.record(100, now.Add(-6*time.Hour))
+	c.record(200, now.Add(-time.Hour))
+	c.record(300, now)
+
+	requests, tokens := c.sum(now.Add(-5*time.Hour), now)
+	require.Equal(t, int64(2), requests)
+	require.Equal(t, int64(500), tokens)
+
+	// 7 天后写入同一个环形位置会覆盖旧桶
+	old := now.Add(-6 * time.Hour)
+	c.record(50, old.Add(openAIUsageWindowMaxSpan))
+	_, tokens = c.sum(old, old)
+	require.Zero(t, tokens)
+	_, tokens = c.sum(old.Add(openAIUsageWindowMaxSpan), old.Add(openAIUsageWindowMaxSpan))
+	require.Equal(t, int64(50), tokens)
+}
+
+func TestOpenAIUsageWindowStatus_CalibratedFromUpstreamPercent(t *testing.T) {
+	svc := newUsageWindowTestService(config.GatewayOpenAIUsageWindowConfig{
+		Enabled:              true,
+		LookaheadSeconds:     600,
+		StopThresholdPercent: 80,
+	})
+	now := time.Now()
+	account := newUsageWindowTestAccount(1, map[string]any{
+		"codex_5h_used_percent":  50.0,
+		"codex_5h_reset_at":      now.Add(2 * time.Hour).Format(time.RFC3339),
+		"codex_usage_updated_at": now.Format(time.RFC3339),
+	})
+	svc.recordOpenAIUsageWindow(account, 1000, now)
+
+	statuses := svc.OpenAIUsageWindowStatuses(account, now)
+	require.Len(t, statuses, 2)
+	fiveHour := statuses[0]
+	require.Equal(t, OpenAIUsageWindow5h, fiveHour.Window)
+	require.Equal(t, int64(1), fiveHour.Requests)
+	require.Equal(t, int64(2000), fiveHour.EstimatedTokenLimit)
+	require.InDelta(t, 50, fiveHour.UsedPercent, 1e-9)
+	// 最近 15 分钟消耗 1000 token，向前预测 10 分钟约 667 token ≈ 33%
+	require.InDelta(t, 83.3, fiveHour.PredictedPercent, 0.1)
+	require.True(t, fiveHour.WillExceed)
+
+	// 7 天窗口无上游快照也无配置上限，无法预测
+	require.Nil(t, statuses[1].UpstreamUsedPercent)
+	require.False(t, statuses[1].WillExceed)
+	require.True(t, svc.openAIUsageWindowWillExceed(account, now))
+}
+
+func TestOpenAIUsageWindowStatus_ConfiguredLimitRollingWindow(t *testing.T) {
+	svc := newUsageWindowTestService(config.GatewayOpenAIUsageWindowConfig{
+		Enabled:              true,
+		StopThresholdPercent: 100,
+		FiveHourTokenLimit:   1000,
+	})
+	now := time.Now()
+	account := newUsageWindowTestAccount(2, nil)
+	svc.recordOpenAIUsageWindow(account, 600, now.Add(-6*time.Hour))
+	svc.recordOpenAIUsageWindow(account, 900, now.Add(-time.Hour))
+
+	status := svc.OpenAIUsageWindowStatuses(account, now)[0]
+	require.Equal(t, int64(900), status.Tokens)
+	require.InDelta(t, 90, status.UsedPercent, 1e-9)
+	require.InDelta(t, 10, status.RemainingPercent, 1e-9)
+	require.False(t, status.WillExceed)
+
+	svc.recordOpenAIUsageWindow(account, 100, now)
+	require.True(t, svc.openAIUsageWindowWillExceed(account, now))
+
+	svc.cfg.Gateway.OpenAIUsageWindow.Enabled = false
+	require.False(t, svc.openAIUsageWindowWillExceed(account, now))
+}
+
+func TestOpenAIUsageWindow_IgnoresNonOAuthAccounts(t *testing.T) {
+	svc := newUsageWindowTestService(config.GatewayOpenAIUsageWindowConfig{Enabled: true, FiveHourTokenLimit: 1})
+	account := &Account{ID: 3, Platform: PlatformOpenAI, Type: AccountTypeAPIKey}
+	svc.recordOpenAIUsageWindow(account, 100, time.Now())
+	require.Nil(t, svc.usageWindowCounter(3, false))
+	require.False(t, svc.openAIUsageWindowWillExceed(account, time.Now()))
+}
+
+func TestOpenAIGatewayService_SelectAccountWithScheduler_SkipsPredictedWindowExceed(t *testing.T) {
+	groupID := int64(13)
+	accounts := []Account{
+		*newUsageWindowTestAccount(5001, nil),
+		*newUsageWindowTestAccount(5002, nil),
+	}
+	accounts[1].Priority = 5
+	cfg := &config.Config{}
+	cfg.Gateway.OpenAIUsageWindow = config.GatewayOpenAIUsageWindowConfig{Enabled: true, StopThresholdPercent: 100, FiveHourTokenLimit: 1000}
+	svc := &OpenAIGatewayService{
+		accountRepo: stubOpenAIAccountRepo{accounts: accounts},
+		cache:       &stubGatewayCache{},
+		cfg:         cfg,
+		concurrencyService: NewConcurrencyService(stubConcurrencyCache{
+			acquireResults: map[int64]bool{5001: true, 5002: true},
+		}),
+	}
+	svc.recordOpenAIUsageWindow(&accounts[0], 1000, time.Now())
+
+	selection, decision, err := svc.SelectAccountWithScheduler(context.Background(), &groupID, "", "", "gpt-5.1", nil, OpenAIUpstreamTransportAny)
+	require.NoError(t, err)
+	require.Equal(t, int64(5002), selection.Account.ID)
+	require.Equal(t, 1, decision.CandidateCount)
+	if selection.ReleaseFunc != nil {
+		selection.ReleaseFunc()
+	}
+}
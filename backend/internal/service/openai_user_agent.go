@@ -0,0 +1,81 @@
+package service
+
+import (
+	"context"
+	"strings"
+
+	"github.com/ShaohongDong/sub2api/internal/pkg/clientdetect"
+	"github.com/ShaohongDong/sub2api/internal/pkg/openai"
+)
+
+// renderUpstreamUserAgent 渲染 UA 模板中的 {version}/{client_version} 占位符。
+func renderUpstreamUserAgent(template, version, clientVersion string) string {
+	template = strings.TrimSpace(template)
+	if template == "" || !strings.Contains(template, "{") {
+		return template
+	}
+	if clientVersion == "" {
+		clientVersion = version
+	}
+	return strings.NewReplacer(
+		"{version}", version,
+		"{client_version}", clientVersion,
+	).Replace(template)
+}
+
+// upstreamCodexVersion 返回发往上游的 Codex CLI 版本（填充 {version} 与 version 请求头）：
+// 取配置的 upstream_user_agent.version 与版本跟踪器获取到的最新版本中较新者。
+func (s *OpenAIGatewayService) upstreamCodexVersion() string {
+	version := currentCodexCLIVersion()
+	if s == nil || s.cfg == nil {
+		return version
+	}
+	if configured := strings.TrimSpace(s.cfg.Gateway.UpstreamUserAgent.Version); configured != "" && CompareVersions(configured, version) >= 0 {
+		return configured
+	}
+	return version
+}
+
+// upstreamCodexUserAgent 返回按全局模板渲染的 Codex CLI UA；
+// 未配置模板或渲染结果不被识别为 Codex CLI 时回退内置 Codex CLI UA（版本随版本跟踪器更新）。
+func (s *OpenAIGatewayService) upstreamCodexUserAgent(clientVersion string) string {
+	if s == nil || s.cfg == nil {
+		return currentCodexCLIUserAgent()
+	}
+	ua := renderUpstreamUserAgent(s.cfg.Gateway.UpstreamUserAgent.Template, s.upstreamCodexVersion(), clientVersion)
+	if ua == "" || !openai.IsCodexCLIRequest(ua) {
+		return currentCodexCLIUserAgent()
+	}
+	return ua
+}
+
+// resolveUpstreamUserAgent 计算发往 OpenAI 上游的 User-Agent。
+//
+// 优先级：账号自定义 UA（支持模板） > 全局模板（upstream_user_agent.enabled） > 原始 UA；
+// 开启 ForceCodexCLI 时最终强制覆盖为模板渲染的 Codex CLI UA。
+func (s *OpenAIGatewayService) resolveUpstreamUserAgent(ctx context.Context, account *Account, currentUA string) string {
+	info, _ := clientdetect.FromContext(ctx)
+	ua := strings.TrimSpace(currentUA)
+
+	customUA := ""
+	if account != nil {
+		customUA = strings.TrimSpace(account.GetOpenAIUserAgent())
+	}
+	switch {
+	case customUA != "":
+		ua = renderUpstreamUserAgent(customUA, s.upstreamCodexVersion(), info.Version)
+	case s != nil && s.cfg != nil && s.cfg.Gateway.UpstreamUserAgent.Enabled:
+		ua = s.upstreamCodexUserAgent(info.Version)
+	}
+
+	if s != nil && s.cfg != nil && s.cfg.Gateway.ForceCodexCLI {
+		ua = s.upstreamCodexUserAgent(info.Version)
+	}
+	return ua
+}
+
+// upstreamCodexUserAgentForContext 按 context 中的客户端版本渲染 Codex CLI UA。
+func (s *OpenAIGatewayService) upstreamCodexUserAgentForContext(ctx context.Context) string {
+	info, _ := clientdetect.FromContext(ctx)
+	return s.upstreamCodexUserAgent(info.Version)
+}
@@ -0,0 +1,94 @@
+package service
+
+import (
+	"context"
+	"testing"
+
+	"github.com/ShaohongDong/sub2api/internal/config"
+	"github.com/ShaohongDong/sub2api/internal/pkg/clientdetect"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRenderUpstreamUserAgent(t *testing.T) {
+	require.Equal(t, "codex_cli_rs/0.104.0", renderUpstreamUserAgent("codex_cli_rs/{version}", "0.104.0", ""))
+	require.Equal(t, "codex_cli_rs/0.120.0", renderUpstreamUserAgent("codex_cli_rs/{client_version}", "0.104.0", "0.120.0"))
+	require.Equal(t, "codex_cli_rs/0.104.0", renderUpstreamUserAgent("codex_cli_rs/{client_version}", "0.104.0", ""))
+	require.Equal(t, "static-ua/1.0", renderUpstreamUserAgent(" static-ua/1.0 ", "0.104.0", ""))
+	require.Empty(t, renderUpstreamUserAgent("", "0.104.0", ""))
+}
+
+func TestOpenAIGatewayService_ResolveUpstreamUserAgent(t *testing.T) {
+	codexCtx := clientdetect.IntoContext(context.Background(), clientdetect.ClientInfo{Type: clientdetect.TypeCodexCLI, Version: "0.130.0"})
+	apiKeyAccount := &Account{Platform: PlatformOpenAI, Type: AccountTypeAPIKey}
+	customAccount := &Account{Platform: PlatformOpenAI, Type: AccountTypeAPIKey, Credentials: map[string]any{"user_agent": "my-agent/{version}"}}
+
+	tests := []struct {
+		name    string
+		cfg     config.GatewayUpstreamUserAgentConfig
+		force   bool
+		ctx     context.Context
+		account *Account
+		current string
+		want    string
+	}{
+		{
+			name:    "disabled keeps client ua",
+			cfg:     config.GatewayUpstreamUserAgentConfig{Template: "codex_cli_rs/{version}", Version: "0.104.0"},
+			ctx:     context.Background(),
+			account: apiKeyAccount,
+			current: "curl/8.0",
+			want:    "curl/8.0",
+		},
+		{
+			name:    "enabled rewrites to template",
+			cfg:     config.GatewayUpstreamUserAgentConfig{Enabled: true, Template: "codex_cli_rs/{version}", Version: "0.110.0"},
+			ctx:     context.Background(),
+			account: apiKeyAccount,
+			current: "curl/8.0",
+			want:    "codex_cli_rs/0.110.0",
+		},
+		{
+			name:    "template follows client version",
+			cfg:     config.GatewayUpstreamUserAgentConfig{Enabled: true, Template: "codex_cli_rs/{client_version}", Version: "0.110.0"},
+			ctx:     codexCtx,
+			account: apiKeyAccount,
+			current: "codex_cli_rs/0.130.0",
+			want:    "codex_cli_rs/0.130.0",
+		},
+		{
+			name:    "account override wins over template",
+			cfg:     config.GatewayUpstreamUserAgentConfig{Enabled: true, Template: "codex_cli_rs/{version}", Version: "0.110.0"},
+			ctx:     context.Background(),
+			account: customAccount,
+			current: "curl/8.0",
+			want:    "my-agent/0.110.0",
+		},
+		{
+			name:    "non codex template falls back to builtin",
+			cfg:     config.GatewayUpstreamUserAgentConfig{Enabled: true, Template: "curl/{version}", Version: "8.0"},
+			ctx:     context.Background(),
+			account: apiKeyAccount,
+			current: "curl/8.0",
+			want:    codexCLIUserAgent,
+		},
+		{
+			name:    "force codex cli overrides account ua",
+			cfg:     config.GatewayUpstreamUserAgentConfig{Template: "codex_cli_rs/{version}", Version: "0.110.0"},
+			force:   true,
+			ctx:     context.Background(),
+			account: customAccount,
+			current: "curl/8.0",
+			want:    "codex_cli_rs/0.110.0",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := &config.Config{}
+			cfg.Gateway.UpstreamUserAgent = tt.cfg
+			cfg.Gateway.ForceCodexCLI = tt.force
+			svc := &OpenAIGatewayService{cfg: cfg}
+			require.Equal(t, tt.want, svc.resolveUpstreamUserAgent(tt.ctx, tt.account, tt.current))
+		})
+	}
+}
@@ -400,10 +400,12 @@ func parseOpenAIWSResponseUsageFromCompletedEvent(message []byte, usage *OpenAIU
 		"response.usage.input_tokens",
 		"response.usage.output_tokens",
 		"response.usage.input_tokens_details.cached_tokens",
+		"response.usage.output_tokens_details.reasoning_tokens",
 	)
 	usage.InputTokens = int(values[0].Int())
 	usage.OutputTokens = int(values[1].Int())
 	usage.CacheReadInputTokens = int(values[2].Int())
+	usage.ReasoningTokens = int(values[3].Int())
 }
 
 func parseOpenAIWSErrorEventFields(message []byte) (code string, errType string, errMessage string) {
@@ -1138,9 +1140,6 @@ func (s *OpenAIGatewayService) buildOpenAIWSHeaders(
 	}
 
 	if account != nil && account.Type == AccountTypeOAuth {
-		if chatgptAccountID := account.GetChatGPTAccountID(); chatgptAccountID != "" {
-			headers.Set("chatgpt-account-id", chatgptAccountID)
-		}
 		headers.Set("originator", resolveOpenAIUpstreamOriginator(c, isCodexCLI))
 	}
 
@@ -1150,23 +1149,21 @@ func (s *OpenAIGatewayService) buildOpenAIWSHeaders(
 	}
 	headers.Set("OpenAI-Beta", betaValue)
 
-	customUA := ""
-	if account != nil {
-		customUA = account.GetOpenAIUserAgent()
-	}
-	if strings.TrimSpace(customUA) != "" {
-		headers.Set("user-agent", customUA)
-	} else if c != nil {
-		if ua := strings.TrimSpace(c.GetHeader("User-Agent")); ua != "" {
-			headers.Set("user-agent", ua)
+	uaCtx := context.Background()
+	clientUA := ""
+	if c != nil {
+		clientUA = c.GetHeader("User-Agent")
+		if c.Request != nil {
+			uaCtx = c.Request.Context()
 		}
 	}
-	if s != nil && s.cfg != nil && s.cfg.Gateway.ForceCodexCLI {
-		headers.Set("user-agent", codexCLIUserAgent)
+	if ua := s.resolveUpstreamUserAgent(uaCtx, account, clientUA); ua != "" {
+		headers.Set("user-agent", ua)
 	}
 	if account != nil && account.Type == AccountTypeOAuth && !openai.IsCodexCLIRequest(headers.Get("user-agent")) {
-		headers.Set("user-agent", codexCLIUserAgent)
+		headers.Set("user-agent", s.upstreamCodexUserAgentForContext(uaCtx))
 	}
+	s.scopeOpenAISessionHeaders(headers, account)
 
 	return headers, sessionResolution
 }
@@ -3747,10 +3744,12 @@ func populateOpenAIUsageFromResponseJSON(body []byte, usage *OpenAIUsage) {
 		"usage.input_tokens",
 		"usage.output_tokens",
 		"usage.input_tokens_details.cached_tokens",
+		"usage.output_tokens_details.reasoning_tokens",
 	)
 	usage.InputTokens = int(values[0].Int())
 	usage.OutputTokens = int(values[1].Int())
 	usage.CacheReadInputTokens = int(values[2].Int())
+	usage.ReasoningTokens = int(values[3].Int())
 }
 
 func getOpenAIGroupIDFromContext(c *gin.Context) int64 {
@@ -3813,7 +3812,7 @@ func (s *OpenAIGatewayService) SelectAccountByPreviousResponseID(
 		_ = store.DeleteResponseAccount(ctx, derefGroupID(groupID), responseID)
 		return nil, nil
 	}
-	if requestedModel != "" && !account.IsModelSupported(requestedModel) {
+	if requestedModel != "" && (!account.IsModelSupported(requestedModel) || !s.isAccountPlanCompatible(ctx, account, requestedModel)) {
 		return nil, nil
 	}
 
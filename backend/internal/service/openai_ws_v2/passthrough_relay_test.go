@@ -414,6 +414,39 @@ func TestRelay_MultipleUpstreamMessages(t *testing.T) {
 	require.Len(t, clientWrites, 3)
 }
 
+func TestRelay_EmptyFirstMessageRealtimeSession(t *testing.T) {
+	t.Parallel()
+
+	// Realtime 会话无首帧：上游先下发 session.created，多轮 response.done 的 usage 累加
+	clientConn := newPassthroughTestFrameConn(nil, false)
+	upstreamConn := newPassthroughTestFrameConn([]passthroughTestFrame{
+		{
+			msgType: coderws.MessageText,
+			payload: []byte(`{"type":"session.created","session":{"model":"gpt-4o-realtime-preview"}}`),
+		},
+		{
+			msgType: coderws.MessageText,
+			payload: []byte(`{"type":"response.done","response":{"id":"resp_rt_1","usage":{"input_tokens":120,"output_tokens":40,"input_token_details":{"cached_tokens":64,"audio_tokens":100}}}}`),
+		},
+		{
+			msgType: coderws.MessageText,
+			payload: []byte(`{"type":"response.done","response":{"id":"resp_rt_2","usage":{"input_tokens":30,"output_tokens":10,"input_token_details":{"cached_tokens":0}}}}`),
+		},
+	}, true)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+
+	result, relayExit := Relay(ctx, clientConn, upstreamConn, nil, RelayOptions{})
+	require.Nil(t, relayExit)
+	require.Equal(t, 150, result.Usage.InputTokens)
+	require.Equal(t, 50, result.Usage.OutputTokens)
+	require.Equal(t, 64, result.Usage.CacheReadInputTokens)
+	require.Equal(t, int64(0), result.ClientToUpstreamFrames)
+	require.Empty(t, upstreamConn.Writes())
+	require.Len(t, clientConn.Writes(), 3)
+}
+
 func TestRelay_OnTurnComplete_PerTerminalEvent(t *testing.T) {
 	t.Parallel()
 
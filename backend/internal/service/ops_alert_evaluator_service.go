@@ -35,6 +35,7 @@ type OpsAlertEvaluatorService struct {
 	opsService   *OpsService
 	opsRepo      OpsRepository
 	emailService *EmailService
+	alertService *AlertService
 
 	redisClient *redis.Client
 	cfg         *config.Config
@@ -80,6 +81,11 @@ func NewOpsAlertEvaluatorService(
 	}
 }
 
+// SetAlertService 设置运维告警 webhook 服务（可选依赖），规则触发时额外推送 webhook
+func (s *OpsAlertEvaluatorService) SetAlertService(alertService *AlertService) {
+	s.alertService = alertService
+}
+
 func (s *OpsAlertEvaluatorService) Start() {
 	if s == nil {
 		return
@@ -292,6 +298,7 @@ func (s *OpsAlertEvaluatorService) evaluateOnce(interval time.Duration) {
 				if s.maybeSendAlertEmail(ctx, runtimeCfg, rule, created) {
 					emailsSent++
 				}
+				s.fireAlertWebhook(ctx, runtimeCfg, rule, created)
 			}
 			continue
 		}
@@ -699,6 +706,47 @@ func (s *OpsAlertEvaluatorService) maybeSendAlertEmail(ctx context.Context, runt
 	return anySent
 }
 
+// fireAlertWebhook 将触发的告警事件推送到 alert_webhooks；错误率类规则映射为 error_rate_spike。
+// 静默规则同样适用于 webhook。
+func (s *OpsAlertEvaluatorService) fireAlertWebhook(ctx context.Context, runtimeCfg *OpsAlertRuntimeSettings, rule *OpsAlertRule, event *OpsAlertEvent) {
+	if !s.alertService.Enabled() {
+		return
+	}
+	if runtimeCfg != nil && isOpsAlertSilenced(time.Now().UTC(), rule, event, runtimeCfg.Silencing) {
+		return
+	}
+
+	eventType := AlertEventOpsAlert
+	switch strings.TrimSpace(rule.MetricType) {
+	case "error_rate", "upstream_error_rate":
+		eventType = AlertEventErrorRateSpike
+	}
+	severity := opsEmailSeverityForOps(rule.Severity)
+	fields := map[string]any{
+		"rule_id":     rule.ID,
+		"rule_name":   rule.Name,
+		"metric_type": rule.MetricType,
+		"operator":    rule.Operator,
+		"threshold":   rule.Threshold,
+		"event_id":    event.ID,
+	}
+	if event.MetricValue != nil {
+		fields["metric_value"] = *event.MetricValue
+	}
+	for k, v := range event.Dimensions {
+		fields[k] = v
+	}
+	s.alertService.Fire(ctx, &AlertEvent{
+		Type:       eventType,
+		Severity:   severity,
+		Title:      event.Title,
+		Message:    event.Description,
+		Fields:     fields,
+		OccurredAt: event.FiredAt,
+		DedupKey:   strconv.FormatInt(rule.ID, 10),
+	})
+}
+
 func buildOpsAlertEmailBody(rule *OpsAlertRule, event *OpsAlertEvent) string {
 	if rule == nil || event == nil {
 		return ""
@@ -79,6 +79,8 @@ type OpsInsertErrorLogInput struct {
 	RequestPath string
 	Stream      bool
 	UserAgent   string
+	// ClientType clientdetect.ClientType，与实时路由使用同一识别结果
+	ClientType string
 
 	ErrorPhase        string
 	ErrorType         string
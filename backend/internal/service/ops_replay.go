@@ -0,0 +1,141 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"time"
+
+	infraerrors "github.com/ShaohongDong/sub2api/internal/pkg/errors"
+)
+
+// OpsReplayTrace 调试重放的转换追踪：客户端原始请求 → 账号模型映射 → 实际发往上游的每次请求与响应
+type OpsReplayTrace struct {
+	RequestPath  string `json:"request_path"`
+	RequestType  string `json:"request_type"`
+	InboundModel string `json:"inbound_model,omitempty"`
+	MappedModel  string `json:"mapped_model,omitempty"`
+	Stream       bool   `json:"stream"`
+	UserAgent    string `json:"user_agent,omitempty"`
+
+	// InboundBody 重放使用的客户端请求体（入库时已脱敏）；InboundBodyModified 表示重放前是否过滤了 thinking 块
+	InboundBody         string `json:"inbound_body"`
+	InboundBodyModified bool   `json:"inbound_body_modified"`
+
+	Upstream       []UpstreamExchange       `json:"upstream"`
+	UpstreamErrors []*OpsUpstreamErrorEvent `json:"upstream_errors,omitempty"`
+}
+
+// OpsReplayResult 调试重放结果
+type OpsReplayResult struct {
+	ErrorID     int64  `json:"error_id"`
+	AccountID   int64  `json:"account_id"`
+	AccountName string `json:"account_name"`
+	Platform    string `json:"platform"`
+
+	Status            string `json:"status"`
+	HTTPStatusCode    int    `json:"http_status_code"`
+	UpstreamRequestID string `json:"upstream_request_id,omitempty"`
+	ResponsePreview   string `json:"response_preview"`
+	ResponseTruncated bool   `json:"response_truncated"`
+	ErrorMessage      string `json:"error_message,omitempty"`
+	DurationMs        int64  `json:"duration_ms"`
+
+	Trace *OpsReplayTrace `json:"trace"`
+}
+
+// ReplayError 将已记录的失败请求重放到指定账号（未指定时使用原账号），并返回完整的转换追踪，
+// 用于复现“客户端直连正常、经网关失败”一类问题。
+//
+// 与 RetryError 不同，调试重放不要求账号可调度或与原请求同组，不写入重试记录，也不会标记错误已解决。
+func (s *OpsService) ReplayError(ctx context.Context, errorID int64, accountID int64) (*OpsReplayResult, error) {
+	if err := s.RequireMonitoringEnabled(ctx); err != nil {
+		return nil, err
+	}
+	if s.opsRepo == nil {
+		return nil, infraerrors.ServiceUnavailable("OPS_REPO_UNAVAILABLE", "Ops repository not available")
+	}
+	if s.accountRepo == nil {
+		return nil, infraerrors.ServiceUnavailable("ACCOUNT_REPO_UNAVAILABLE", "Account repository not available")
+	}
+
+	errorLog, err := s.GetErrorLogByID(ctx, errorID)
+	if err != nil {
+		return nil, err
+	}
+	if errorLog == nil {
+		return nil, infraerrors.NotFound("OPS_ERROR_NOT_FOUND", "ops error log not found")
+	}
+	if strings.TrimSpace(errorLog.RequestBody) == "" {
+		return nil, infraerrors.BadRequest("OPS_RETRY_NO_REQUEST_BODY", "No request body found to retry")
+	}
+	if accountID <= 0 {
+		if errorLog.AccountID == nil || *errorLog.AccountID <= 0 {
+			return nil, infraerrors.BadRequest("OPS_REPLAY_ACCOUNT_REQUIRED", "account_id is required: the original request has no account")
+		}
+		accountID = *errorLog.AccountID
+	}
+	account, err := s.accountRepo.GetByID(ctx, accountID)
+	if err != nil {
+		return nil, err
+	}
+
+	if s.concurrencyService != nil {
+		acq, err := s.concurrencyService.AcquireAccountSlot(ctx, account.ID, account.Concurrency)
+		if err != nil {
+			return nil, infraerrors.ServiceUnavailable("OPS_REPLAY_SLOT_FAILED", "Failed to acquire account slot").WithCause(err)
+		}
+		if acq == nil || !acq.Acquired {
+			return nil, infraerrors.Conflict("OPS_REPLAY_ACCOUNT_BUSY", "account concurrency limit reached")
+		}
+		if acq.ReleaseFunc != nil {
+			defer acq.ReleaseFunc()
+		}
+	}
+
+	reqType := detectOpsRetryType(errorLog.RequestPath)
+	original := []byte(errorLog.RequestBody)
+	body := original
+	if reqType == opsRetryTypeMessages {
+		body = FilterThinkingBlocksForRetry(body)
+	}
+
+	trace := &OpsReplayTrace{
+		RequestPath:         errorLog.RequestPath,
+		RequestType:         string(reqType),
+		UserAgent:           errorLog.UserAgent,
+		InboundBody:         string(body),
+		InboundBodyModified: !bytes.Equal(original, body),
+	}
+	if model, stream, err := extractRetryModelAndStream(reqType, errorLog, body); err == nil {
+		trace.InboundModel = model
+		trace.Stream = stream
+		if model != "" {
+			trace.MappedModel = account.GetMappedModel(model)
+		}
+	}
+
+	rec := NewUpstreamTraceRecorder(opsRetryCaptureBytesLimit)
+	execCtx, cancel := context.WithTimeout(WithUpstreamTrace(ctx, rec), opsRetryTimeout)
+	defer cancel()
+
+	startedAt := time.Now()
+	exec := s.executeWithAccount(execCtx, reqType, errorLog, body, account)
+	trace.Upstream = rec.Exchanges()
+	trace.UpstreamErrors = exec.upstreamErrors
+
+	return &OpsReplayResult{
+		ErrorID:           errorID,
+		AccountID:         account.ID,
+		AccountName:       account.Name,
+		Platform:          account.Platform,
+		Status:            exec.status,
+		HTTPStatusCode:    exec.httpStatusCode,
+		UpstreamRequestID: exec.upstreamRequestID,
+		ResponsePreview:   exec.responsePreview,
+		ResponseTruncated: exec.responseTruncated,
+		ErrorMessage:      exec.errorMessage,
+		DurationMs:        time.Since(startedAt).Milliseconds(),
+		Trace:             trace,
+	}, nil
+}
@@ -0,0 +1,95 @@
+//go:build unit
+
+package service
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+
+	infraerrors "github.com/ShaohongDong/sub2api/internal/pkg/errors"
+	"github.com/stretchr/testify/require"
+)
+
+type opsReplayRepoStub struct {
+	opsRepoMock
+	detail *OpsErrorLogDetail
+}
+
+func (r *opsReplayRepoStub) GetErrorLogByID(context.Context, int64) (*OpsErrorLogDetail, error) {
+	return r.detail, nil
+}
+
+func TestOpsReplayError_BuildsTranslationTrace(t *testing.T) {
+	origID := int64(7)
+	repo := &opsReplayRepoStub{detail: &OpsErrorLogDetail{
+		OpsErrorLog: OpsErrorLog{RequestPath: "/v1/responses", AccountID: &origID},
+		UserAgent:   "codex_cli_rs/0.50.0",
+		RequestBody: `{"model":"gpt-5","stream":true,"input":"hi"}`,
+	}}
+	accounts := &mockAccountRepoForGemini{accountsByID: map[int64]*Account{
+		7: {ID: 7, Name: "orig", Platform: PlatformOpenAI},
+		9: {ID: 9, Name: "chosen", Platform: PlatformOpenAI, Credentials: map[string]any{"model_mapping": map[string]any{"gpt-5": "gpt-5-codex"}}},
+	}}
+	svc := &OpsService{opsRepo: repo, accountRepo: accounts}
+
+	result, err := svc.ReplayError(context.Background(), 1, 9)
+	require.NoError(t, err)
+	require.Equal(t, int64(9), result.AccountID)
+	require.Equal(t, opsRetryStatusFailed, result.Status)
+	require.Equal(t, "openai gateway service not available", result.ErrorMessage)
+
+	trace := result.Trace
+	require.Equal(t, string(opsRetryTypeOpenAI), trace.RequestType)
+	require.Equal(t, "gpt-5", trace.InboundModel)
+	require.Equal(t, "gpt-5-codex", trace.MappedModel)
+	require.True(t, trace.Stream)
+	require.Equal(t, "codex_cli_rs/0.50.0", trace.UserAgent)
+	require.False(t, trace.InboundBodyModified)
+
+	// 未指定账号时回退到原请求账号
+	result, err = svc.ReplayError(context.Background(), 1, 0)
+	require.NoError(t, err)
+	require.Equal(t, "orig", result.AccountName)
+}
+
+func TestOpsReplayError_RequiresAccountAndBody(t *testing.T) {
+	repo := &opsReplayRepoStub{detail: &OpsErrorLogDetail{
+		OpsErrorLog: OpsErrorLog{RequestPath: "/v1/messages"},
+		RequestBody: `{"model":"claude-sonnet-4-5"}`,
+	}}
+	svc := &OpsService{opsRepo: repo, accountRepo: &mockAccountRepoForGemini{}}
+
+	_, err := svc.ReplayError(context.Background(), 1, 0)
+	require.Equal(t, "OPS_REPLAY_ACCOUNT_REQUIRED", infraerrors.Reason(err))
+
+	repo.detail.RequestBody = ""
+	_, err = svc.ReplayError(context.Background(), 1, 3)
+	require.Equal(t, "OPS_RETRY_NO_REQUEST_BODY", infraerrors.Reason(err))
+}
+
+func TestUpstreamTraceRecorder_TruncatesResponseBody(t *testing.T) {
+	rec := NewUpstreamTraceRecorder(4)
+	req, err := http.NewRequest(http.MethodPost, "https://example.com/v1/messages", strings.NewReader("not json"))
+	require.NoError(t, err)
+
+	ex := rec.RecordRequest(req)
+	resp := &http.Response{StatusCode: http.StatusOK, Header: http.Header{}, Body: io.NopCloser(strings.NewReader("event: ping"))}
+	rec.RecordResponse(ex, resp, nil)
+	body, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+	require.Equal(t, "event: ping", string(body), "caller still receives the full body")
+
+	exchanges := rec.Exchanges()
+	require.Len(t, exchanges, 1)
+	require.Equal(t, "even", exchanges[0].ResponseBody)
+	require.True(t, exchanges[0].ResponseTruncated)
+	require.Empty(t, exchanges[0].RequestBody, "non-JSON request bodies are not retained")
+	require.Equal(t, len("not json"), exchanges[0].RequestBodyBytes)
+
+	var nilRec *UpstreamTraceRecorder
+	require.Nil(t, nilRec.RecordRequest(req))
+	require.Nil(t, UpstreamTraceFromContext(context.Background()))
+}
@@ -344,8 +344,13 @@ type opsRetryExecution struct {
 
 	responsePreview   string
 	responseTruncated bool
+	// responseBody 捕获的完整响应体（不超过 opsRetryCaptureBytesLimit），供死信重放保存结果
+	responseBody string
 
 	errorMessage string
+
+	// upstreamErrors 重放期间网关记录的上游错误事件（供调试重放展示）
+	upstreamErrors []*OpsUpstreamErrorEvent
 }
 
 func (s *OpsService) executeRetry(ctx context.Context, errorLog *OpsErrorLogDetail, mode string, pinnedAccountID *int64) *opsRetryExecution {
@@ -620,8 +625,12 @@ func (s *OpsService) executeWithAccount(ctx context.Context, reqType opsRetryReq
 		upstreamRequestID: upstreamReqID,
 		responsePreview:   preview,
 		responseTruncated: truncated,
+		responseBody:      string(w.bodyBytes()),
 		errorMessage:      "",
 	}
+	if v, ok := c.Get(OpsUpstreamErrorsKey); ok {
+		exec.upstreamErrors, _ = v.([]*OpsUpstreamErrorEvent)
+	}
 
 	if err == nil && statusCode < 400 {
 		exec.status = opsRetryStatusSucceeded
@@ -0,0 +1,257 @@
+package service
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/ShaohongDong/sub2api/internal/config"
+	"github.com/tidwall/gjson"
+	"github.com/tidwall/sjson"
+)
+
+// openAIReasoningUnsupportedParams OpenAI 推理模型不接受的采样参数（推理强度为 none 时除外）
+var openAIReasoningUnsupportedParams = []string{
+	"temperature",
+	"top_p",
+	"presence_penalty",
+	"frequency_penalty",
+	"logprobs",
+	"top_logprobs",
+	"logit_bias",
+}
+
+// responsesUnsupportedParams Responses API 不接受的 Chat Completions 参数
+var responsesUnsupportedParams = []string{
+	"presence_penalty",
+	"frequency_penalty",
+}
+
+// claudeModelVersionPattern 解析 claude-{family}-{major}[-{minor}]，minor 不超过两位以免误把日期后缀当作版本
+var claudeModelVersionPattern = regexp.MustCompile(`^claude-(opus|sonnet|haiku)-(\d+)(?:[-.](\d{1,2}))?(?:-|$)`)
+
+// ParamSanitizeService 按模型清理请求参数：钳制最大输出 token 数、移除上游不支持的参数、修正已知客户端兼容问题，
+// 避免把上游必然以 400 拒绝的参数组合转发出去。
+type ParamSanitizeService struct {
+	cfg     *config.Config
+	pricing *PricingService
+}
+
+// NewParamSanitizeService 创建请求参数清理服务
+func NewParamSanitizeService(cfg *config.Config, pricing *PricingService) *ParamSanitizeService {
+	return &ParamSanitizeService{cfg: cfg, pricing: pricing}
+}
+
+// Enabled 是否启用参数清理
+func (s *ParamSanitizeService) Enabled() bool {
+	return s != nil && s.cfg != nil && s.cfg.Gateway.ParamSanitize.Enabled
+}
+
+// Sanitize 按入口格式（ReasoningFormat*）清理请求体，返回清理后的请求体与所做修改的说明（未修改时为空）。
+// 请求体不是 JSON 对象时原样返回。
+func (s *ParamSanitizeService) Sanitize(body []byte, format, model string) ([]byte, []string) {
+	if !s.Enabled() || !gjson.ValidBytes(body) || !gjson.ParseBytes(body).IsObject() {
+		return body, nil
+	}
+	san := &paramSanitizer{body: body}
+	rule := matchParamSanitizeRule(s.cfg.Gateway.ParamSanitize.Rules, model)
+	if rule != nil {
+		for _, param := range rule.DropParams {
+			san.drop(param, "rule")
+		}
+	}
+
+	switch format {
+	case ReasoningFormatChatCompletions:
+		if isOpenAIReasoningModel(model) && san.get("reasoning_effort").String() != "none" {
+			san.dropAll(openAIReasoningUnsupportedParams, "reasoning model")
+			if san.has("max_tokens") {
+				if san.has("max_completion_tokens") {
+					san.drop("max_tokens", "reasoning model")
+				} else {
+					san.rename("max_tokens", "max_completion_tokens")
+				}
+			}
+		}
+		if san.has("stream_options") && !san.get("stream").Bool() {
+			san.drop("stream_options", "not streaming")
+		}
+		san.dropNonPositive("max_completion_tokens")
+	case ReasoningFormatResponses:
+		san.dropAll(responsesUnsupportedParams, "responses api")
+		if isOpenAIReasoningModel(model) && san.get("reasoning.effort").String() != "none" {
+			san.dropAll(openAIReasoningUnsupportedParams, "reasoning model")
+		}
+		san.dropNonPositive("max_output_tokens")
+	}
+
+	if limit := s.maxOutputTokens(rule, model); limit > 0 {
+		for _, field := range paramSanitizeMaxTokenFields(format) {
+			san.clamp(field, limit)
+		}
+	}
+
+	if format == ReasoningFormatAnthropic {
+		sanitizeAnthropicParams(san, model)
+	}
+	return san.body, san.changes
+}
+
+// maxOutputTokens 返回模型的最大输出 token 数：命中规则的上限优先，其次为价格数据中的模型上限；0 表示未知
+func (s *ParamSanitizeService) maxOutputTokens(rule *config.GatewayParamSanitizeRule, model string) int {
+	if rule != nil && rule.MaxOutputTokens > 0 {
+		return rule.MaxOutputTokens
+	}
+	if !s.cfg.Gateway.ParamSanitize.UsePricingLimits || s.pricing == nil || model == "" {
+		return 0
+	}
+	if pricing := s.pricing.GetModelPricing(model); pricing != nil {
+		return pricing.MaxOutputTokens
+	}
+	return 0
+}
+
+// sanitizeAnthropicParams 修正 Anthropic Messages 请求：
+// 开启 thinking 时 temperature 只能为 1、不支持 top_k、top_p 需不小于 0.95，且 budget_tokens 必须小于 max_tokens；
+// Claude Opus 4.1、Sonnet / Haiku 4.5 及更新模型不允许同时设置 temperature 与 top_p。
+func sanitizeAnthropicParams(san *paramSanitizer, model string) {
+	switch san.get("thinking.type").String() {
+	case "enabled", "adaptive":
+		if temp := san.get("temperature"); temp.Exists() && temp.Float() != 1 {
+			san.drop("temperature", "thinking enabled")
+		}
+		san.drop("top_k", "thinking enabled")
+		if topP := san.get("top_p"); topP.Exists() && topP.Float() < 0.95 {
+			san.drop("top_p", "thinking enabled")
+		}
+		budget := san.get("thinking.budget_tokens").Int()
+		maxTokens := san.get("max_tokens").Int()
+		if budget > 0 && maxTokens > 0 && budget >= maxTokens && maxTokens-1 >= anthropicMinThinkingBudget {
+			san.set("thinking.budget_tokens", maxTokens-1, "budget_tokens must be below max_tokens")
+		}
+	}
+	if san.has("temperature") && san.has("top_p") && claudeRejectsTemperatureWithTopP(model) {
+		san.drop("top_p", "temperature and top_p are exclusive")
+	}
+}
+
+// matchParamSanitizeRule 返回首条命中请求模型的规则，未命中返回 nil
+func matchParamSanitizeRule(rules []config.GatewayParamSanitizeRule, model string) *config.GatewayParamSanitizeRule {
+	for i := range rules {
+		if len(rules[i].Models) == 0 || routingModelMatches(rules[i].Models, model) {
+			return &rules[i]
+		}
+	}
+	return nil
+}
+
+// paramSanitizeMaxTokenFields 返回入口格式中表示最大输出 token 数的字段
+func paramSanitizeMaxTokenFields(format string) []string {
+	switch format {
+	case ReasoningFormatAnthropic:
+		return []string{"max_tokens"}
+	case ReasoningFormatChatCompletions:
+		return []string{"max_tokens", "max_completion_tokens"}
+	case ReasoningFormatResponses:
+		return []string{"max_output_tokens"}
+	default:
+		return nil
+	}
+}
+
+// isOpenAIReasoningModel 判断是否为 OpenAI 推理模型（o 系列与 gpt-5 系列，gpt-5-chat 除外）
+func isOpenAIReasoningModel(model string) bool {
+	model = strings.ToLower(strings.TrimSpace(model))
+	if idx := strings.LastIndex(model, "/"); idx >= 0 {
+		model = model[idx+1:]
+	}
+	if len(model) >= 2 && model[0] == 'o' && model[1] >= '1' && model[1] <= '9' {
+		return true
+	}
+	return strings.HasPrefix(model, "gpt-5") && !strings.Contains(model, "-chat")
+}
+
+// claudeRejectsTemperatureWithTopP 判断模型是否拒绝同时设置 temperature 与 top_p（Opus 4.1、Sonnet / Haiku 4.5 及更新模型）
+func claudeRejectsTemperatureWithTopP(model string) bool {
+	m := claudeModelVersionPattern.FindStringSubmatch(strings.ToLower(model))
+	if m == nil {
+		return false
+	}
+	major, _ := strconv.Atoi(m[2])
+	minor := 0
+	if m[3] != "" {
+		minor, _ = strconv.Atoi(m[3])
+	}
+	switch {
+	case major > 4:
+		return true
+	case major < 4:
+		return false
+	case m[1] == "opus":
+		return minor >= 1
+	default:
+		return minor >= 5
+	}
+}
+
+// paramSanitizer 在请求体上逐项修改并记录修改说明；单项修改失败时跳过该项
+type paramSanitizer struct {
+	body    []byte
+	changes []string
+}
+
+func (p *paramSanitizer) get(path string) gjson.Result {
+	return gjson.GetBytes(p.body, path)
+}
+
+func (p *paramSanitizer) has(path string) bool {
+	return p.get(path).Exists()
+}
+
+func (p *paramSanitizer) drop(path, reason string) {
+	if !p.has(path) {
+		return
+	}
+	if out, err := sjson.DeleteBytes(p.body, path); err == nil {
+		p.body = out
+		p.changes = append(p.changes, "drop "+path+" ("+reason+")")
+	}
+}
+
+func (p *paramSanitizer) dropAll(paths []string, reason string) {
+	for _, path := range paths {
+		p.drop(path, reason)
+	}
+}
+
+func (p *paramSanitizer) dropNonPositive(path string) {
+	if v := p.get(path); v.Exists() && v.Type == gjson.Number && v.Int() <= 0 {
+		p.drop(path, "non-positive")
+	}
+}
+
+func (p *paramSanitizer) set(path string, value any, reason string) {
+	if out, err := sjson.SetBytes(p.body, path, value); err == nil {
+		p.body = out
+		p.changes = append(p.changes, "set "+path+" ("+reason+")")
+	}
+}
+
+func (p *paramSanitizer) rename(from, to string) {
+	raw := p.get(from).Raw
+	out, err := sjson.SetRawBytes(p.body, to, []byte(raw))
+	if err != nil {
+		return
+	}
+	if out, err = sjson.DeleteBytes(out, from); err != nil {
+		return
+	}
+	p.body = out
+	p.changes = append(p.changes, "rename "+from+" to "+to)
+}
+
+func (p *paramSanitizer) clamp(path string, limit int) {
+	if v := p.get(path); v.Type == gjson.Number && v.Int() > int64(limit) {
+		p.set(path, limit, "model limit "+strconv.Itoa(limit))
+	}
+}
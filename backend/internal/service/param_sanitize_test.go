@@ -0,0 +1,106 @@
+//go:build unit
+
+package service
+
+import (
+	"testing"
+
+	"github.com/ShaohongDong/sub2api/internal/config"
+	"github.com/stretchr/testify/require"
+	"github.com/tidwall/gjson"
+)
+
+func newTestParamSanitizeService(rules ...config.GatewayParamSanitizeRule) *ParamSanitizeService {
+	cfg := &config.Config{}
+	cfg.Gateway.ParamSanitize = config.GatewayParamSanitizeConfig{Enabled: true, UsePricingLimits: true, Rules: rules}
+	pricing := &PricingService{pricingData: map[string]*LiteLLMModelPricing{
+		"claude-sonnet-4-5": {InputCostPerToken: 1, MaxOutputTokens: 64000},
+		"gpt-4o":            {InputCostPerToken: 1, MaxOutputTokens: 16384},
+	}}
+	return NewParamSanitizeService(cfg, pricing)
+}
+
+func TestParamSanitize_ClampsMaxTokensToModelLimit(t *testing.T) {
+	svc := newTestParamSanitizeService(config.GatewayParamSanitizeRule{Models: []string{"glm-*"}, MaxOutputTokens: 8192, DropParams: []string{"seed"}})
+
+	out, changes := svc.Sanitize([]byte(`{"model":"claude-sonnet-4-5","max_tokens":128000}`), ReasoningFormatAnthropic, "claude-sonnet-4-5")
+	require.Equal(t, int64(64000), gjson.GetBytes(out, "max_tokens").Int())
+	require.Len(t, changes, 1)
+
+	// 规则上限优先于价格数据，并移除规则中列出的参数
+	out, _ = svc.Sanitize([]byte(`{"model":"glm-4.6","max_tokens":100000,"seed":1}`), ReasoningFormatChatCompletions, "glm-4.6")
+	require.Equal(t, int64(8192), gjson.GetBytes(out, "max_tokens").Int())
+	require.False(t, gjson.GetBytes(out, "seed").Exists())
+
+	// 未超出上限或上限未知时不修改
+	body := []byte(`{"model":"gpt-4o","max_tokens":1000}`)
+	out, changes = svc.Sanitize(body, ReasoningFormatChatCompletions, "gpt-4o")
+	require.Equal(t, body, out)
+	require.Empty(t, changes)
+	_, changes = svc.Sanitize([]byte(`{"model":"unknown","max_output_tokens":999999}`), ReasoningFormatResponses, "unknown")
+	require.Empty(t, changes)
+
+	svc.cfg.Gateway.ParamSanitize.UsePricingLimits = false
+	_, changes = svc.Sanitize([]byte(`{"model":"gpt-4o","max_tokens":100000}`), ReasoningFormatChatCompletions, "gpt-4o")
+	require.Empty(t, changes)
+
+	svc.cfg.Gateway.ParamSanitize.Enabled = false
+	_, changes = svc.Sanitize([]byte(`{"model":"glm-4.6","max_tokens":100000}`), ReasoningFormatChatCompletions, "glm-4.6")
+	require.Empty(t, changes)
+}
+
+func TestParamSanitize_OpenAIReasoningModels(t *testing.T) {
+	svc := newTestParamSanitizeService()
+
+	out, _ := svc.Sanitize([]byte(`{"model":"o3","temperature":0.2,"top_p":0.9,"logit_bias":{},"max_tokens":500,"stream_options":{"include_usage":true}}`), ReasoningFormatChatCompletions, "o3")
+	require.JSONEq(t, `{"model":"o3","max_completion_tokens":500}`, string(out))
+
+	// gpt-5 在推理强度为 none 时支持采样参数
+	body := []byte(`{"model":"gpt-5.1","reasoning_effort":"none","temperature":0.2,"stream":true,"stream_options":{"include_usage":true}}`)
+	out, _ = svc.Sanitize(body, ReasoningFormatChatCompletions, "gpt-5.1")
+	require.Equal(t, body, out)
+
+	// gpt-5-chat 不是推理模型
+	out, _ = svc.Sanitize([]byte(`{"model":"gpt-5-chat-latest","temperature":0.2}`), ReasoningFormatChatCompletions, "gpt-5-chat-latest")
+	require.True(t, gjson.GetBytes(out, "temperature").Exists())
+
+	out, _ = svc.Sanitize([]byte(`{"model":"gpt-5","temperature":1,"presence_penalty":0.5,"max_output_tokens":0}`), ReasoningFormatResponses, "gpt-5")
+	require.JSONEq(t, `{"model":"gpt-5"}`, string(out))
+	out, _ = svc.Sanitize([]byte(`{"model":"gpt-4.1","temperature":1,"frequency_penalty":0.5}`), ReasoningFormatResponses, "gpt-4.1")
+	require.JSONEq(t, `{"model":"gpt-4.1","temperature":1}`, string(out))
+
+	require.True(t, isOpenAIReasoningModel("openai/o4-mini"))
+	require.False(t, isOpenAIReasoningModel("omni-moderation-latest"))
+	require.False(t, isOpenAIReasoningModel("gpt-4o"))
+}
+
+func TestParamSanitize_AnthropicThinkingAndSampling(t *testing.T) {
+	svc := newTestParamSanitizeService()
+
+	out, _ := svc.Sanitize([]byte(`{"model":"claude-3-7-sonnet-20250219","max_tokens":4000,"temperature":0.5,"top_k":5,"top_p":0.9,"thinking":{"type":"enabled","budget_tokens":8000}}`), ReasoningFormatAnthropic, "claude-3-7-sonnet-20250219")
+	require.JSONEq(t, `{"model":"claude-3-7-sonnet-20250219","max_tokens":4000,"thinking":{"type":"enabled","budget_tokens":3999}}`, string(out))
+
+	// max_tokens 过小时无法得到合法的 budget_tokens，保持原样交由上游报错
+	out, _ = svc.Sanitize([]byte(`{"model":"claude-3-7-sonnet","max_tokens":1000,"thinking":{"type":"enabled","budget_tokens":1024}}`), ReasoningFormatAnthropic, "claude-3-7-sonnet")
+	require.Equal(t, int64(1024), gjson.GetBytes(out, "thinking.budget_tokens").Int())
+
+	// Sonnet 4.5 不允许同时设置 temperature 与 top_p；Sonnet 4 允许
+	out, _ = svc.Sanitize([]byte(`{"model":"claude-sonnet-4-5-20250929","temperature":0.5,"top_p":0.9}`), ReasoningFormatAnthropic, "claude-sonnet-4-5-20250929")
+	require.JSONEq(t, `{"model":"claude-sonnet-4-5-20250929","temperature":0.5}`, string(out))
+	_, changes := svc.Sanitize([]byte(`{"model":"claude-sonnet-4-20250514","temperature":0.5,"top_p":0.9}`), ReasoningFormatAnthropic, "claude-sonnet-4-20250514")
+	require.Empty(t, changes)
+
+	require.True(t, claudeRejectsTemperatureWithTopP("claude-opus-4-1-20250805"))
+	require.False(t, claudeRejectsTemperatureWithTopP("claude-opus-4-20250514"))
+	require.True(t, claudeRejectsTemperatureWithTopP("claude-haiku-4-5"))
+	require.False(t, claudeRejectsTemperatureWithTopP("claude-3-5-haiku-20241022"))
+}
+
+func TestParamSanitize_IgnoresNonObjectBodies(t *testing.T) {
+	svc := newTestParamSanitizeService()
+	for _, body := range []string{`not json`, `[1,2]`, ``} {
+		out, changes := svc.Sanitize([]byte(body), ReasoningFormatChatCompletions, "o3")
+		require.Equal(t, body, string(out))
+		require.Empty(t, changes)
+	}
+}
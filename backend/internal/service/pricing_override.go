@@ -0,0 +1,217 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/ShaohongDong/sub2api/internal/config"
+	infraerrors "github.com/ShaohongDong/sub2api/internal/pkg/errors"
+	"golang.org/x/sync/singleflight"
+)
+
+// maxPricingOverrides 价格覆盖表的最大条目数
+const maxPricingOverrides = 500
+
+var ErrInvalidPricingOverrides = infraerrors.BadRequest("INVALID_PRICING_OVERRIDES", "invalid pricing overrides (model supports a trailing * wildcard, prices must be non-negative)")
+
+// normalizePricingOverrides 去除首尾空白、统一小写并校验价格表；同一模型重复出现时后者覆盖前者
+func normalizePricingOverrides(overrides []ModelPriceOverride) ([]ModelPriceOverride, error) {
+	out := make([]ModelPriceOverride, 0, len(overrides))
+	index := make(map[string]int, len(overrides))
+	for _, override := range overrides {
+		override.Model = strings.ToLower(strings.TrimSpace(override.Model))
+		if override.Model == "" || strings.Contains(strings.TrimSuffix(override.Model, "*"), "*") ||
+			len(override.Model) > maxModelAliasNameLen {
+			return nil, ErrInvalidPricingOverrides.WithMetadata(map[string]string{"model": override.Model})
+		}
+		for _, price := range override.prices() {
+			if price != nil && *price < 0 {
+				return nil, ErrInvalidPricingOverrides.WithMetadata(map[string]string{"model": override.Model})
+			}
+		}
+		if i, ok := index[override.Model]; ok {
+			out[i] = override
+			continue
+		}
+		index[override.Model] = len(out)
+		out = append(out, override)
+	}
+	if len(out) > maxPricingOverrides {
+		return nil, ErrInvalidPricingOverrides.WithMetadata(map[string]string{"reason": "too many overrides"})
+	}
+	return out, nil
+}
+
+func (o ModelPriceOverride) prices() []*float64 {
+	return []*float64{o.InputPerMTok, o.OutputPerMTok, o.CacheCreationPerMTok, o.CacheCreation1hPerMTok, o.CacheReadPerMTok}
+}
+
+// pricingOverridesFromConfig 转换配置文件中的 pricing.overrides（已由 config.Validate 校验）
+func pricingOverridesFromConfig(cfg *config.Config) []ModelPriceOverride {
+	if cfg == nil || len(cfg.Pricing.Overrides) == 0 {
+		return nil
+	}
+	out := make([]ModelPriceOverride, 0, len(cfg.Pricing.Overrides))
+	for _, o := range cfg.Pricing.Overrides {
+		out = append(out, ModelPriceOverride{
+			Model:                  strings.ToLower(strings.TrimSpace(o.Model)),
+			InputPerMTok:           o.InputPerMTok,
+			OutputPerMTok:          o.OutputPerMTok,
+			CacheCreationPerMTok:   o.CacheCreationPerMTok,
+			CacheCreation1hPerMTok: o.CacheCreation1hPerMTok,
+			CacheReadPerMTok:       o.CacheReadPerMTok,
+		})
+	}
+	return out
+}
+
+// lookupPriceOverride 在价格表中查找模型：精确匹配优先，其次最长的通配符前缀
+func lookupPriceOverride(overrides []ModelPriceOverride, model string) *ModelPriceOverride {
+	var best *ModelPriceOverride
+	for i := range overrides {
+		pattern := overrides[i].Model
+		if pattern == model {
+			return &overrides[i]
+		}
+		if !strings.HasSuffix(pattern, "*") || !matchModelPattern(pattern, model) {
+			continue
+		}
+		if best == nil || len(pattern) > len(best.Model) {
+			best = &overrides[i]
+		}
+	}
+	return best
+}
+
+// applyPriceOverride 将覆盖价格叠加到基础价格上（返回副本）；base 为 nil 时未设置的字段为 0
+func applyPriceOverride(base *ModelPricing, override *ModelPriceOverride) *ModelPricing {
+	pricing := ModelPricing{}
+	if base != nil {
+		pricing = *base
+	}
+	if override.InputPerMTok != nil {
+		pricing.InputPricePerToken = *override.InputPerMTok / 1e6
+	}
+	if override.OutputPerMTok != nil {
+		pricing.OutputPricePerToken = *override.OutputPerMTok / 1e6
+	}
+	if override.CacheCreationPerMTok != nil {
+		pricing.CacheCreationPricePerToken = *override.CacheCreationPerMTok / 1e6
+		pricing.CacheCreation5mPrice = pricing.CacheCreationPricePerToken
+	}
+	if override.CacheCreation1hPerMTok != nil {
+		pricing.CacheCreation1hPrice = *override.CacheCreation1hPerMTok / 1e6
+	}
+	if override.CacheReadPerMTok != nil {
+		pricing.CacheReadPricePerToken = *override.CacheReadPerMTok / 1e6
+	}
+	if override.CacheCreationPerMTok != nil || override.CacheCreation1hPerMTok != nil {
+		pricing.SupportsCacheBreakdown = pricing.CacheCreation1hPrice > 0 && pricing.CacheCreation1hPrice > pricing.CacheCreation5mPrice
+	}
+	return &pricing
+}
+
+// cachedPricingOverrides 管理后台价格表进程内缓存
+type cachedPricingOverrides struct {
+	overrides []ModelPriceOverride
+	expiresAt int64 // unix nano
+}
+
+var pricingOverrideCache atomic.Value // *cachedPricingOverrides
+
+var pricingOverrideSF singleflight.Group
+
+// pricingOverrideCacheTTL 缓存有效期（与模型别名缓存一致）
+const pricingOverrideCacheTTL = 60 * time.Second
+
+// GetPricingOverrideSettings 获取管理后台维护的模型价格表
+func (s *SettingService) GetPricingOverrideSettings(ctx context.Context) (*PricingOverrideSettings, error) {
+	value, err := s.settingRepo.GetValue(ctx, SettingKeyPricingOverrideSettings)
+	if err != nil {
+		if errors.Is(err, ErrSettingNotFound) {
+			return DefaultPricingOverrideSettings(), nil
+		}
+		return nil, fmt.Errorf("get pricing override settings: %w", err)
+	}
+	if value == "" {
+		return DefaultPricingOverrideSettings(), nil
+	}
+
+	var settings PricingOverrideSettings
+	if err := json.Unmarshal([]byte(value), &settings); err != nil {
+		return DefaultPricingOverrideSettings(), nil
+	}
+	if settings.Overrides == nil {
+		settings.Overrides = []ModelPriceOverride{}
+	}
+	return &settings, nil
+}
+
+// SetPricingOverrideSettings 设置模型价格表，并立即刷新本实例缓存（其他实例在缓存过期后生效）
+func (s *SettingService) SetPricingOverrideSettings(ctx context.Context, settings *PricingOverrideSettings) error {
+	if settings == nil {
+		return fmt.Errorf("settings cannot be nil")
+	}
+	overrides, err := normalizePricingOverrides(settings.Overrides)
+	if err != nil {
+		return err
+	}
+	settings.Overrides = overrides
+
+	data, err := json.Marshal(settings)
+	if err != nil {
+		return fmt.Errorf("marshal pricing override settings: %w", err)
+	}
+	if err := s.settingRepo.Set(ctx, SettingKeyPricingOverrideSettings, string(data)); err != nil {
+		return err
+	}
+	pricingOverrideSF.Forget("pricing_overrides")
+	pricingOverrideCache.Store(&cachedPricingOverrides{
+		overrides: overrides,
+		expiresAt: time.Now().Add(pricingOverrideCacheTTL).UnixNano(),
+	})
+	return nil
+}
+
+// GetPricingOverrides 返回管理后台价格表（进程内缓存，60 秒 TTL），供计费热路径使用。
+// 读取失败时返回空表，回退到配置文件与远程价格。
+func (s *SettingService) GetPricingOverrides(ctx context.Context) []ModelPriceOverride {
+	if s == nil {
+		return nil
+	}
+	if cached, ok := pricingOverrideCache.Load().(*cachedPricingOverrides); ok {
+		if time.Now().UnixNano() < cached.expiresAt {
+			return cached.overrides
+		}
+	}
+	result, _, _ := pricingOverrideSF.Do("pricing_overrides", func() (any, error) {
+		if cached, ok := pricingOverrideCache.Load().(*cachedPricingOverrides); ok {
+			if time.Now().UnixNano() < cached.expiresAt {
+				return cached.overrides, nil
+			}
+		}
+		dbCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), versionBoundsDBTimeout)
+		defer cancel()
+		settings, err := s.GetPricingOverrideSettings(dbCtx)
+		if err != nil {
+			slog.Warn("failed to get pricing override settings, skipping overrides", "error", err)
+			pricingOverrideCache.Store(&cachedPricingOverrides{
+				expiresAt: time.Now().Add(versionBoundsErrorTTL).UnixNano(),
+			})
+			return []ModelPriceOverride(nil), nil
+		}
+		pricingOverrideCache.Store(&cachedPricingOverrides{
+			overrides: settings.Overrides,
+			expiresAt: time.Now().Add(pricingOverrideCacheTTL).UnixNano(),
+		})
+		return settings.Overrides, nil
+	})
+	overrides, _ := result.([]ModelPriceOverride)
+	return overrides
+}
@@ -0,0 +1,131 @@
+//go:build unit
+
+package service
+
+import (
+	"context"
+	"testing"
+
+	"github.com/ShaohongDong/sub2api/internal/config"
+	"github.com/stretchr/testify/require"
+)
+
+type staticPricingOverrideSource []ModelPriceOverride
+
+func (s staticPricingOverrideSource) GetPricingOverrides(context.Context) []ModelPriceOverride {
+	return s
+}
+
+func floatPtr(v float64) *float64 { return &v }
+
+func TestNormalizePricingOverrides(t *testing.T) {
+	got, err := normalizePricingOverrides([]ModelPriceOverride{
+		{Model: " My-Model ", InputPerMTok: floatPtr(1)},
+		{Model: "gpt-*", OutputPerMTok: floatPtr(2)},
+		{Model: "my-model", InputPerMTok: floatPtr(3)},
+	})
+	require.NoError(t, err)
+	require.Len(t, got, 2)
+	require.Equal(t, "my-model", got[0].Model)
+	require.Equal(t, 3.0, *got[0].InputPerMTok)
+
+	_, err = normalizePricingOverrides([]ModelPriceOverride{{Model: "a*b"}})
+	require.ErrorIs(t, err, ErrInvalidPricingOverrides)
+	_, err = normalizePricingOverrides([]ModelPriceOverride{{Model: "x", CacheReadPerMTok: floatPtr(-1)}})
+	require.ErrorIs(t, err, ErrInvalidPricingOverrides)
+}
+
+func TestLookupPriceOverride_ExactThenLongestPrefix(t *testing.T) {
+	overrides := []ModelPriceOverride{
+		{Model: "claude-*"},
+		{Model: "claude-sonnet-*"},
+		{Model: "claude-sonnet-4"},
+	}
+	require.Equal(t, "claude-sonnet-4", lookupPriceOverride(overrides, "claude-sonnet-4").Model)
+	require.Equal(t, "claude-sonnet-*", lookupPriceOverride(overrides, "claude-sonnet-4-5").Model)
+	require.Equal(t, "claude-*", lookupPriceOverride(overrides, "claude-opus-4").Model)
+	require.Nil(t, lookupPriceOverride(overrides, "gpt-5"))
+}
+
+func TestBillingService_ConfigOverrideReplacesOnlySetFields(t *testing.T) {
+	svc := NewBillingService(&config.Config{Pricing: config.PricingConfig{Overrides: []config.PricingOverrideConfig{
+		{Model: "Claude-Sonnet-4", InputPerMTok: floatPtr(1)},
+	}}}, nil)
+
+	pricing, err := svc.GetModelPricing("claude-sonnet-4")
+	require.NoError(t, err)
+	require.InDelta(t, 1e-6, pricing.InputPricePerToken, 1e-12)
+	// 输出价格沿用内置回退价格 $15/MTok
+	require.InDelta(t, 15e-6, pricing.OutputPricePerToken, 1e-12)
+
+	// 回退价格表本身不被修改
+	require.InDelta(t, 3e-6, svc.fallbackPrices["claude-sonnet-4"].InputPricePerToken, 1e-12)
+}
+
+func TestBillingService_OverridePricesUnknownModel(t *testing.T) {
+	svc := NewBillingService(&config.Config{}, nil)
+	_, err := svc.GetModelPricing("my-finetune")
+	require.Error(t, err)
+
+	svc.SetPricingOverrideSource(staticPricingOverrideSource{
+		{Model: "my-*", InputPerMTok: floatPtr(2), OutputPerMTok: floatPtr(4), CacheReadPerMTok: floatPtr(0.5)},
+	})
+	cost, err := svc.CalculateCost("my-finetune", UsageTokens{InputTokens: 1000, OutputTokens: 500, CacheReadTokens: 2000}, 2)
+	require.NoError(t, err)
+	require.InDelta(t, 1000*2e-6, cost.InputCost, 1e-12)
+	require.InDelta(t, 500*4e-6, cost.OutputCost, 1e-12)
+	require.InDelta(t, 2000*0.5e-6, cost.CacheReadCost, 1e-12)
+	require.InDelta(t, cost.TotalCost*2, cost.ActualCost, 1e-12)
+}
+
+func TestBillingService_AdminOverrideTakesPrecedenceOverConfig(t *testing.T) {
+	svc := NewBillingService(&config.Config{Pricing: config.PricingConfig{Overrides: []config.PricingOverrideConfig{
+		{Model: "gpt-5.1", InputPerMTok: floatPtr(9)},
+	}}}, nil)
+	svc.SetPricingOverrideSource(staticPricingOverrideSource{{Model: "gpt-5.1", InputPerMTok: floatPtr(7)}})
+
+	pricing, err := svc.GetModelPricing("GPT-5.1")
+	require.NoError(t, err)
+	require.InDelta(t, 7e-6, pricing.InputPricePerToken, 1e-12)
+}
+
+func TestBillingService_CacheCreationOverrideEnablesBreakdown(t *testing.T) {
+	svc := NewBillingService(&config.Config{}, nil)
+	svc.SetPricingOverrideSource(staticPricingOverrideSource{
+		{Model: "custom", CacheCreationPerMTok: floatPtr(3.75), CacheCreation1hPerMTok: floatPtr(6)},
+	})
+
+	pricing, err := svc.GetModelPricing("custom")
+	require.NoError(t, err)
+	require.True(t, pricing.SupportsCacheBreakdown)
+	require.InDelta(t, 3.75e-6, pricing.CacheCreation5mPrice, 1e-12)
+	require.InDelta(t, 6e-6, pricing.CacheCreation1hPrice, 1e-12)
+}
+
+func TestSettingService_SetPricingOverrideSettings_ValidatesAndRefreshesCache(t *testing.T) {
+	repo := newRuntimeSettingRepoStub()
+	svc := NewSettingService(repo, &config.Config{})
+	ctx := context.Background()
+	t.Cleanup(func() { pricingOverrideCache.Store(&cachedPricingOverrides{}) })
+
+	got, err := svc.GetPricingOverrideSettings(ctx)
+	require.NoError(t, err)
+	require.Empty(t, got.Overrides)
+
+	err = svc.SetPricingOverrideSettings(ctx, &PricingOverrideSettings{Overrides: []ModelPriceOverride{{Model: ""}}})
+	require.ErrorIs(t, err, ErrInvalidPricingOverrides)
+
+	require.NoError(t, svc.SetPricingOverrideSettings(ctx, &PricingOverrideSettings{Overrides: []ModelPriceOverride{
+		{Model: " Custom ", InputPerMTok: floatPtr(1)},
+	}}))
+	got, err = svc.GetPricingOverrideSettings(ctx)
+	require.NoError(t, err)
+	require.Len(t, got.Overrides, 1)
+	require.Equal(t, "custom", got.Overrides[0].Model)
+
+	// 写入后立即刷新本实例缓存
+	repo.values[SettingKeyPricingOverrideSettings] = `{"overrides":[{"model":"stale"}]}`
+	cached := svc.GetPricingOverrides(ctx)
+	require.Len(t, cached, 1)
+	require.Equal(t, "custom", cached[0].Model)
+}
@@ -50,7 +50,9 @@ type LiteLLMModelPricing struct {
 	LiteLLMProvider                     string  `json:"litellm_provider"`
 	Mode                                string  `json:"mode"`
 	SupportsPromptCaching               bool    `json:"supports_prompt_caching"`
-	OutputCostPerImage                  float64 `json:"output_cost_per_image"` // 图片生成模型每张图片价格
+	OutputCostPerImage                  float64 `json:"output_cost_per_image"`       // 图片生成模型每张图片价格
+	MaxOutputTokens                     int     `json:"max_output_tokens,omitempty"` // 单次请求最大输出 token 数（0 表示未知）
+	MaxInputTokens                      int     `json:"max_input_tokens,omitempty"`  // 上下文窗口（最大输入 token 数，0 表示未知）
 }
 
 // PricingRemoteClient 远程价格数据获取接口
@@ -70,6 +72,9 @@ type LiteLLMRawEntry struct {
 	Mode                                string   `json:"mode"`
 	SupportsPromptCaching               bool     `json:"supports_prompt_caching"`
 	OutputCostPerImage                  *float64 `json:"output_cost_per_image"`
+	// 个别条目的 max_output_tokens 不是数字，按原始 JSON 解析以免整条价格被跳过
+	MaxOutputTokens json.RawMessage `json:"max_output_tokens"`
+	MaxInputTokens  json.RawMessage `json:"max_input_tokens"`
 }
 
 // PricingService 动态价格服务
@@ -347,6 +352,14 @@ func (s *PricingService) parsePricingData(body []byte) (map[string]*LiteLLMModel
 		if entry.OutputCostPerImage != nil {
 			pricing.OutputCostPerImage = *entry.OutputCostPerImage
 		}
+		var maxOutputTokens float64
+		if len(entry.MaxOutputTokens) > 0 && json.Unmarshal(entry.MaxOutputTokens, &maxOutputTokens) == nil && maxOutputTokens > 0 {
+			pricing.MaxOutputTokens = int(maxOutputTokens)
+		}
+		var maxInputTokens float64
+		if len(entry.MaxInputTokens) > 0 && json.Unmarshal(entry.MaxInputTokens, &maxInputTokens) == nil && maxInputTokens > 0 {
+			pricing.MaxInputTokens = int(maxInputTokens)
+		}
 
 		result[modelName] = pricing
 	}
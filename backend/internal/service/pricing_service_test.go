@@ -115,3 +115,28 @@ func TestResolveWritableDataDir_UsesExplicitConfiguredPath(t *testing.T) {
 	require.NoError(t, err)
 	require.Equal(t, expected, resolved)
 }
+
+func TestParsePricingData_MaxOutputTokens(t *testing.T) {
+	svc := &PricingService{}
+	data, err := svc.parsePricingData([]byte(`{
+		"claude-sonnet-4-5": {"input_cost_per_token": 0.000003, "litellm_provider": "anthropic", "max_output_tokens": 64000},
+		"gpt-4o": {"input_cost_per_token": 0.0000025, "litellm_provider": "openai", "max_output_tokens": "16384"},
+		"gpt-4": {"input_cost_per_token": 0.00003, "litellm_provider": "openai"}
+	}`))
+	require.NoError(t, err)
+	require.Equal(t, 64000, data["claude-sonnet-4-5"].MaxOutputTokens)
+	// 非数字的上限按未知处理
+	require.Zero(t, data["gpt-4o"].MaxOutputTokens)
+	require.Zero(t, data["gpt-4"].MaxOutputTokens)
+}
+
+func TestParsePricingData_MaxInputTokens(t *testing.T) {
+	svc := &PricingService{}
+	data, err := svc.parsePricingData([]byte(`{
+		"claude-sonnet-4-5": {"input_cost_per_token": 0.000003, "litellm_provider": "anthropic", "max_input_tokens": 200000},
+		"gpt-4": {"input_cost_per_token": 0.00003, "litellm_provider": "openai", "max_input_tokens": "8192"}
+	}`))
+	require.NoError(t, err)
+	require.Equal(t, 200000, data["claude-sonnet-4-5"].MaxInputTokens)
+	require.Zero(t, data["gpt-4"].MaxInputTokens)
+}
@@ -0,0 +1,209 @@
+package service
+
+import (
+	"container/heap"
+	"context"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ShaohongDong/sub2api/internal/config"
+	infraerrors "github.com/ShaohongDong/sub2api/internal/pkg/errors"
+)
+
+var (
+	ErrPriorityQueueFull    = infraerrors.TooManyRequests("REQUEST_QUEUE_FULL", "too many queued requests for this group, retry later")
+	ErrPriorityQueueTimeout = infraerrors.TooManyRequests("REQUEST_QUEUE_TIMEOUT", "request waited too long in queue, retry later")
+)
+
+// PriorityRequestQueue 分组级有界优先级请求队列（gateway.priority_queue）。
+// 每个分组的在途请求不超过 MaxInFlightPerGroup；超出时按优先级（高者先、同级先到先得）排队，
+// 请求结束后名额直接移交给队首请求。
+type PriorityRequestQueue struct {
+	cfg     config.GatewayPriorityQueueConfig
+	classes []config.GatewayPriorityClass
+
+	mu     sync.Mutex
+	groups map[int64]*priorityGroupQueue
+	seq    uint64
+}
+
+type priorityGroupQueue struct {
+	inFlight int
+	waiters  priorityWaiterHeap
+}
+
+type priorityWaiter struct {
+	priority int
+	seq      uint64
+	ready    chan struct{} // 名额移交后关闭
+	index    int           // 在堆中的位置，-1 表示已出队
+}
+
+// NewPriorityRequestQueue 创建优先级请求队列；未启用时返回 nil（nil 接收者放行所有请求）
+func NewPriorityRequestQueue(cfg config.GatewayPriorityQueueConfig) *PriorityRequestQueue {
+	if !cfg.Enabled {
+		return nil
+	}
+	classes := make([]config.GatewayPriorityClass, 0, len(cfg.Classes))
+	for _, class := range cfg.Classes {
+		tags := make([]string, 0, len(class.KeyTags))
+		for _, tag := range class.KeyTags {
+			if tag = strings.ToLower(strings.TrimSpace(tag)); tag != "" {
+				tags = append(tags, tag)
+			}
+		}
+		class.KeyTags = tags
+		classes = append(classes, class)
+	}
+	q := &PriorityRequestQueue{cfg: cfg, classes: classes, groups: make(map[int64]*priorityGroupQueue)}
+	registerPriorityQueueMetrics(q)
+	return q
+}
+
+// PriorityQueueGroupStats 分组的在途与排队请求数
+type PriorityQueueGroupStats struct {
+	InFlight int
+	Queued   int
+}
+
+// Stats 返回当前有在途或排队请求的分组状态；nil 接收者返回空
+func (q *PriorityRequestQueue) Stats() map[int64]PriorityQueueGroupStats {
+	if q == nil {
+		return nil
+	}
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	out := make(map[int64]PriorityQueueGroupStats, len(q.groups))
+	for groupID, g := range q.groups {
+		out[groupID] = PriorityQueueGroupStats{InFlight: g.inFlight, Queued: g.waiters.Len()}
+	}
+	return out
+}
+
+// RetryAfterSeconds 队列满或超时时 429 响应的 Retry-After
+func (q *PriorityRequestQueue) RetryAfterSeconds() int {
+	return q.cfg.RetryAfterSeconds
+}
+
+// PriorityOf 返回 Key 命中的最高类别优先级，未命中时为 0
+func (q *PriorityRequestQueue) PriorityOf(apiKey *APIKey) int {
+	priority, matched := 0, false
+	for _, class := range q.classes {
+		if apiKey.HasAnyTag(class.KeyTags) && (!matched || class.Priority > priority) {
+			priority, matched = class.Priority, true
+		}
+	}
+	return priority
+}
+
+// Acquire 为分组获取一个处理名额，必要时排队等待；返回的 release 必须在请求结束后调用。
+// 队列已满返回 ErrPriorityQueueFull，等待超过 MaxWaitSeconds 返回 ErrPriorityQueueTimeout，ctx 取消时返回 ctx.Err()。
+func (q *PriorityRequestQueue) Acquire(ctx context.Context, groupID int64, priority int) (func(), error) {
+	if q == nil {
+		return func() {}, nil
+	}
+	q.mu.Lock()
+	g := q.groups[groupID]
+	if g == nil {
+		g = &priorityGroupQueue{}
+		q.groups[groupID] = g
+	}
+	if g.inFlight < q.cfg.MaxInFlightPerGroup && g.waiters.Len() == 0 {
+		g.inFlight++
+		q.mu.Unlock()
+		return q.releaseFunc(groupID), nil
+	}
+	if g.waiters.Len() >= q.cfg.MaxQueuePerGroup {
+		q.mu.Unlock()
+		return nil, ErrPriorityQueueFull
+	}
+	q.seq++
+	w := &priorityWaiter{priority: priority, seq: q.seq, ready: make(chan struct{})}
+	heap.Push(&g.waiters, w)
+	q.mu.Unlock()
+
+	timer := time.NewTimer(time.Duration(q.cfg.MaxWaitSeconds) * time.Second)
+	defer timer.Stop()
+	var err error
+	select {
+	case <-w.ready:
+		return q.releaseFunc(groupID), nil
+	case <-timer.C:
+		err = ErrPriorityQueueTimeout
+	case <-ctx.Done():
+		err = ctx.Err()
+	}
+
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if w.index < 0 {
+		// 超时与移交同时发生：名额已归属本请求，转交给下一个等待者
+		q.releaseLocked(groupID)
+		return nil, err
+	}
+	heap.Remove(&g.waiters, w.index)
+	return nil, err
+}
+
+func (q *PriorityRequestQueue) releaseFunc(groupID int64) func() {
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			q.mu.Lock()
+			defer q.mu.Unlock()
+			q.releaseLocked(groupID)
+		})
+	}
+}
+
+// releaseLocked 释放名额：有等待者时直接移交给队首，否则在途数减一
+func (q *PriorityRequestQueue) releaseLocked(groupID int64) {
+	g := q.groups[groupID]
+	if g == nil {
+		return
+	}
+	if g.waiters.Len() > 0 {
+		w := heap.Pop(&g.waiters).(*priorityWaiter)
+		close(w.ready)
+		return
+	}
+	g.inFlight--
+	if g.inFlight <= 0 {
+		delete(q.groups, groupID)
+	}
+}
+
+// priorityWaiterHeap 按优先级降序、入队顺序升序排列
+type priorityWaiterHeap []*priorityWaiter
+
+func (h priorityWaiterHeap) Len() int { return len(h) }
+
+func (h priorityWaiterHeap) Less(i, j int) bool {
+	if h[i].priority != h[j].priority {
+		return h[i].priority > h[j].priority
+	}
+	return h[i].seq < h[j].seq
+}
+
+func (h priorityWaiterHeap) Swap(i, j int) {
+	h[i], h[j] = h[j], h[i]
+	h[i].index = i
+	h[j].index = j
+}
+
+func (h *priorityWaiterHeap) Push(x any) {
+	w := x.(*priorityWaiter)
+	w.index = len(*h)
+	*h = append(*h, w)
+}
+
+func (h *priorityWaiterHeap) Pop() any {
+	old := *h
+	n := len(old)
+	w := old[n-1]
+	old[n-1] = nil
+	w.index = -1
+	*h = old[:n-1]
+	return w
+}
@@ -0,0 +1,114 @@
+//go:build unit
+
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/ShaohongDong/sub2api/internal/config"
+	"github.com/stretchr/testify/require"
+)
+
+func testPriorityQueue(maxQueue, waitSeconds int) *PriorityRequestQueue {
+	return NewPriorityRequestQueue(config.GatewayPriorityQueueConfig{
+		Enabled:             true,
+		MaxInFlightPerGroup: 1,
+		MaxQueuePerGroup:    maxQueue,
+		MaxWaitSeconds:      waitSeconds,
+		RetryAfterSeconds:   5,
+		Classes: []config.GatewayPriorityClass{
+			{Name: "premium", KeyTags: []string{" Premium "}, Priority: 10},
+			{Name: "pro", KeyTags: []string{"pro"}, Priority: 5},
+		},
+	})
+}
+
+func TestPriorityRequestQueue_PriorityOf(t *testing.T) {
+	q := testPriorityQueue(1, 1)
+	require.Equal(t, 10, q.PriorityOf(&APIKey{Tags: []string{"pro", "premium"}}))
+	require.Equal(t, 5, q.PriorityOf(&APIKey{Tags: []string{"pro"}}))
+	require.Equal(t, 0, q.PriorityOf(&APIKey{}))
+	require.Nil(t, NewPriorityRequestQueue(config.GatewayPriorityQueueConfig{}))
+}
+
+func TestPriorityRequestQueue_HandsOffByPriority(t *testing.T) {
+	q := testPriorityQueue(4, 5)
+	ctx := context.Background()
+	release, err := q.Acquire(ctx, 1, 0)
+	require.NoError(t, err)
+
+	order := make(chan int, 3)
+	start := func(priority int) {
+		go func() {
+			r, err := q.Acquire(ctx, 1, priority)
+			if err != nil {
+				order <- -1
+				return
+			}
+			order <- priority
+			r()
+		}()
+	}
+	start(0)
+	waitForQueued(t, q, 1, 1)
+	start(10)
+	waitForQueued(t, q, 1, 2)
+	start(5)
+	waitForQueued(t, q, 1, 3)
+
+	// 其它分组不受影响
+	other, err := q.Acquire(ctx, 2, 0)
+	require.NoError(t, err)
+	other()
+
+	release()
+	require.Equal(t, 10, <-order)
+	require.Equal(t, 5, <-order)
+	require.Equal(t, 0, <-order)
+}
+
+func TestPriorityRequestQueue_FullTimeoutAndCancel(t *testing.T) {
+	q := testPriorityQueue(1, 1)
+	release, err := q.Acquire(context.Background(), 1, 0)
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() {
+		_, err := q.Acquire(ctx, 1, 0)
+		done <- err
+	}()
+	waitForQueued(t, q, 1, 1)
+
+	_, err = q.Acquire(context.Background(), 1, 10)
+	require.ErrorIs(t, err, ErrPriorityQueueFull)
+
+	cancel()
+	require.ErrorIs(t, <-done, context.Canceled)
+
+	start := time.Now()
+	_, err = q.Acquire(context.Background(), 1, 0)
+	require.ErrorIs(t, err, ErrPriorityQueueTimeout)
+	require.GreaterOrEqual(t, time.Since(start), time.Second)
+
+	release()
+	release() // 重复释放无副作用
+	r, err := q.Acquire(context.Background(), 1, 0)
+	require.NoError(t, err)
+	r()
+	q.mu.Lock()
+	require.Empty(t, q.groups)
+	q.mu.Unlock()
+}
+
+func waitForQueued(t *testing.T, q *PriorityRequestQueue, groupID int64, n int) {
+	t.Helper()
+	require.Eventually(t, func() bool {
+		q.mu.Lock()
+		defer q.mu.Unlock()
+		g := q.groups[groupID]
+		return g != nil && g.waiters.Len() == n
+	}, time.Second, time.Millisecond)
+}
@@ -0,0 +1,35 @@
+package service
+
+import "net/http"
+
+// MaxFallbackChainLength 跨平台降级链的最大分组数
+const MaxFallbackChainLength = 5
+
+// IsFallbackChainPlatform 判断分组平台能否加入降级链：
+// 这些平台的网关处理器均支持 Messages / Chat Completions / Responses 之间的格式转换。
+func IsFallbackChainPlatform(platform string) bool {
+	switch platform {
+	case PlatformAnthropic, PlatformOpenAI, PlatformGemini, PlatformAntigravity:
+		return true
+	default:
+		return false
+	}
+}
+
+// CanFallbackToGroup 判断请求能否从 from 分组降级到 to 分组重试：
+// 允许跨平台，但目标分组须处于启用状态、平台支持格式转换、与原分组同租户，且双方均为标准（余额）计费分组。
+func CanFallbackToGroup(from, to *Group) bool {
+	if from == nil || to == nil || from.ID == to.ID {
+		return false
+	}
+	return to.IsActive() &&
+		IsFallbackChainPlatform(to.Platform) &&
+		from.SubscriptionType != SubscriptionTypeSubscription &&
+		to.SubscriptionType != SubscriptionTypeSubscription &&
+		TenantMatches(from.TenantID, to.TenantID)
+}
+
+// IsFallbackRetryableStatus 判断最终响应状态码是否触发降级链：限流（429）与上游/服务端故障（5xx）
+func IsFallbackRetryableStatus(status int) bool {
+	return status == http.StatusTooManyRequests || status >= http.StatusInternalServerError
+}
@@ -1,7 +1,9 @@
 package service
 
 import (
-	"fmt"
+	"net"
+	"net/url"
+	"strconv"
 	"time"
 )
 
@@ -22,11 +24,13 @@ func (p *Proxy) IsActive() bool {
 	return p.Status == StatusActive
 }
 
+// URL 返回代理地址；认证信息会做 URL 转义，密码中的特殊字符（@ : / 等）不会破坏解析
 func (p *Proxy) URL() string {
+	u := url.URL{Scheme: p.Protocol, Host: net.JoinHostPort(p.Host, strconv.Itoa(p.Port))}
 	if p.Username != "" && p.Password != "" {
-		return fmt.Sprintf("%s://%s:%s@%s:%d", p.Protocol, p.Username, p.Password, p.Host, p.Port)
+		u.User = url.UserPassword(p.Username, p.Password)
 	}
-	return fmt.Sprintf("%s://%s:%d", p.Protocol, p.Host, p.Port)
+	return u.String()
 }
 
 type ProxyWithAccountCount struct {
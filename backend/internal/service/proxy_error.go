@@ -0,0 +1,47 @@
+package service
+
+import (
+	"fmt"
+	"strings"
+)
+
+// proxyConnectErrorMarkers 代理拨号 / 握手失败时的错误特征：
+// net/http 的 HTTP(S) 代理与 SOCKS5 代理，以及 TLS 指纹拨号器自建的代理隧道
+var proxyConnectErrorMarkers = []string{
+	"proxyconnect",
+	"socks connect",
+	"socks5 connect",
+	"create socks5 dialer",
+	"connect to proxy",
+	"proxy connect failed",
+	"write connect request",
+	"read connect response",
+	"proxy connection failed",
+}
+
+// IsProxyConnectError 判断错误是否发生在连接账号代理阶段（代理不可达、认证失败、CONNECT 被拒绝等），
+// 而不是上游本身的问题
+func IsProxyConnectError(err error) bool {
+	if err == nil {
+		return false
+	}
+	return isProxyConnectErrorMessage(err.Error())
+}
+
+func isProxyConnectErrorMessage(msg string) bool {
+	msg = strings.ToLower(msg)
+	for _, marker := range proxyConnectErrorMarkers {
+		if strings.Contains(msg, marker) {
+			return true
+		}
+	}
+	return false
+}
+
+// upstreamRequestErrorMessage 生成账号测试的请求失败提示，代理连接失败单独标识
+func upstreamRequestErrorMessage(err error) string {
+	if IsProxyConnectError(err) {
+		return fmt.Sprintf("Proxy connection failed: %s", err.Error())
+	}
+	return fmt.Sprintf("Request failed: %s", err.Error())
+}
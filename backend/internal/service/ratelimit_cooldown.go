@@ -0,0 +1,234 @@
+package service
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// 429 冷却来源
+const (
+	CooldownSourceHeaders    = "headers"     // 上游限流窗口头（codex / anthropic）
+	CooldownSourceBody       = "body"        // 响应体中的重置时间
+	CooldownSourceRetryAfter = "retry_after" // Retry-After 头
+	CooldownSourceBackoff    = "backoff"     // 无重置信息时的指数退避
+)
+
+const (
+	defaultCooldownBackoffBase = 5 * time.Minute
+	defaultCooldownBackoffMax  = time.Hour
+	defaultCooldownHistorySize = 500
+)
+
+// RateLimitCooldownRecord 一次 429 冷却记录
+type RateLimitCooldownRecord struct {
+	AccountID       int64     `json:"account_id"`
+	Platform        string    `json:"platform"`
+	Source          string    `json:"source"`
+	Attempt         int       `json:"attempt"`
+	CooldownSeconds int64     `json:"cooldown_seconds"`
+	Until           time.Time `json:"until"`
+	CreatedAt       time.Time `json:"created_at"`
+}
+
+type rateLimitCooldownStreak struct {
+	attempts  int
+	lastUntil time.Time
+}
+
+// rateLimitCooldownTracker 记录账号连续 429 次数（用于指数退避）与最近的冷却历史（环形缓冲）
+type rateLimitCooldownTracker struct {
+	mu      sync.Mutex
+	streaks map[int64]*rateLimitCooldownStreak
+	history []RateLimitCooldownRecord
+	next    int
+	full    bool
+}
+
+func newRateLimitCooldownTracker(size int) *rateLimitCooldownTracker {
+	if size <= 0 {
+		size = defaultCooldownHistorySize
+	}
+	return &rateLimitCooldownTracker{
+		streaks: make(map[int64]*rateLimitCooldownStreak),
+		history: make([]RateLimitCooldownRecord, size),
+	}
+}
+
+// nextAttempt 返回本次 429 在连续序列中的序号（从 1 开始）；
+// 上次冷却结束后超过 defaultCooldownBackoffMax 未再触发则视为序列结束
+func (t *rateLimitCooldownTracker) nextAttempt(accountID int64, now time.Time) int {
+	if t == nil {
+		return 1
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	streak := t.streaks[accountID]
+	if streak == nil || now.Sub(streak.lastUntil) > defaultCooldownBackoffMax {
+		return 1
+	}
+	return streak.attempts + 1
+}
+
+func (t *rateLimitCooldownTracker) record(rec RateLimitCooldownRecord) {
+	if t == nil {
+		return
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	streak := t.streaks[rec.AccountID]
+	if streak == nil {
+		streak = &rateLimitCooldownStreak{}
+		t.streaks[rec.AccountID] = streak
+	}
+	streak.attempts = rec.Attempt
+	streak.lastUntil = rec.Until
+
+	t.history[t.next] = rec
+	t.next = (t.next + 1) % len(t.history)
+	if t.next == 0 {
+		t.full = true
+	}
+}
+
+func (t *rateLimitCooldownTracker) reset(accountID int64) {
+	if t == nil {
+		return
+	}
+	t.mu.Lock()
+	delete(t.streaks, accountID)
+	t.mu.Unlock()
+}
+
+// list 按时间倒序返回最近的冷却记录；accountID<=0 时返回所有账号
+func (t *rateLimitCooldownTracker) list(accountID int64, limit int) []RateLimitCooldownRecord {
+	out := make([]RateLimitCooldownRecord, 0)
+	if t == nil {
+		return out
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	count := t.next
+	if t.full {
+		count = len(t.history)
+	}
+	for i := 0; i < count; i++ {
+		idx := (t.next - 1 - i + len(t.history)) % len(t.history)
+		rec := t.history[idx]
+		if accountID > 0 && rec.AccountID != accountID {
+			continue
+		}
+		out = append(out, rec)
+		if limit > 0 && len(out) >= limit {
+			break
+		}
+	}
+	return out
+}
+
+// parseRetryAfter 解析 Retry-After 头（秒数或 HTTP 日期）
+func parseRetryAfter(headers http.Header, now time.Time) (time.Duration, bool) {
+	if headers == nil {
+		return 0, false
+	}
+	raw := strings.TrimSpace(headers.Get("Retry-After"))
+	if raw == "" {
+		return 0, false
+	}
+	if secs, err := strconv.ParseFloat(raw, 64); err == nil {
+		if secs <= 0 {
+			return 0, false
+		}
+		return time.Duration(secs * float64(time.Second)), true
+	}
+	if at, err := http.ParseTime(raw); err == nil && at.After(now) {
+		return at.Sub(now), true
+	}
+	return 0, false
+}
+
+// UpstreamRetryAfter 从上游 429 响应头推算客户端应等待的时长：优先 Retry-After，
+// 其次取 OpenAI x-ratelimit-reset-* 与 Anthropic anthropic-ratelimit-*-reset 中最早到期的短窗口。
+// 不使用 5h/7d 订阅窗口：它们只针对单个账号，其余账号可能很快恢复。
+func UpstreamRetryAfter(headers http.Header, now time.Time) (time.Duration, bool) {
+	if headers == nil {
+		return 0, false
+	}
+	if d, ok := parseRetryAfter(headers, now); ok {
+		return d, true
+	}
+	var best time.Duration
+	consider := func(d time.Duration) {
+		if d > 0 && (best == 0 || d < best) {
+			best = d
+		}
+	}
+	for _, name := range []string{"x-ratelimit-reset-requests", "x-ratelimit-reset-tokens"} {
+		if d, ok := parseOpenAIRateLimitReset(headers.Get(name)); ok {
+			consider(d)
+		}
+	}
+	for _, name := range []string{"anthropic-ratelimit-requests-reset", "anthropic-ratelimit-tokens-reset", "anthropic-ratelimit-input-tokens-reset", "anthropic-ratelimit-output-tokens-reset"} {
+		if at, err := time.Parse(time.RFC3339, strings.TrimSpace(headers.Get(name))); err == nil {
+			consider(at.Sub(now))
+		}
+	}
+	return best, best > 0
+}
+
+// cooldownBackoff 第 attempt 次连续 429 的冷却时长：base * 2^(attempt-1)，封顶 max
+func (s *RateLimitService) cooldownBackoff(attempt int) time.Duration {
+	base, maxBackoff := defaultCooldownBackoffBase, defaultCooldownBackoffMax
+	if s.cfg != nil {
+		if v := s.cfg.RateLimit.CooldownBackoffBaseSeconds; v > 0 {
+			base = time.Duration(v) * time.Second
+		}
+		if v := s.cfg.RateLimit.CooldownBackoffMaxSeconds; v > 0 {
+			maxBackoff = time.Duration(v) * time.Second
+		}
+	}
+	if maxBackoff < base {
+		maxBackoff = base
+	}
+	backoff := base
+	for i := 1; i < attempt; i++ {
+		backoff *= 2
+		if backoff >= maxBackoff {
+			return maxBackoff
+		}
+	}
+	return backoff
+}
+
+// fallback429Cooldown 上游未给出窗口重置时间时的冷却：优先 Retry-After，否则按连续次数指数退避
+func (s *RateLimitService) fallback429Cooldown(account *Account, headers http.Header, now time.Time) (time.Time, string, int) {
+	attempt := s.cooldowns.nextAttempt(account.ID, now)
+	if d, ok := parseRetryAfter(headers, now); ok {
+		return now.Add(d), CooldownSourceRetryAfter, attempt
+	}
+	return now.Add(s.cooldownBackoff(attempt)), CooldownSourceBackoff, attempt
+}
+
+// recordCooldown 写入冷却历史
+func (s *RateLimitService) recordCooldown(account *Account, until time.Time, source string, attempt int) {
+	if attempt <= 0 {
+		attempt = s.cooldowns.nextAttempt(account.ID, time.Now())
+	}
+	now := time.Now()
+	s.cooldowns.record(RateLimitCooldownRecord{
+		AccountID:       account.ID,
+		Platform:        account.Platform,
+		Source:          source,
+		Attempt:         attempt,
+		CooldownSeconds: int64(until.Sub(now).Seconds()),
+		Until:           until,
+		CreatedAt:       now,
+	})
+}
+
+// ListCooldownHistory 返回最近的 429 冷却记录（倒序）；accountID<=0 返回所有账号
+func (s *RateLimitService) ListCooldownHistory(accountID int64, limit int) []RateLimitCooldownRecord {
+	return s.cooldowns.list(accountID, limit)
+}
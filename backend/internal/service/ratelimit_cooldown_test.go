@@ -0,0 +1,134 @@
+//go:build unit
+
+package service
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/ShaohongDong/sub2api/internal/config"
+	"github.com/stretchr/testify/require"
+)
+
+type cooldownRepoStub struct {
+	mockAccountRepoForGemini
+	resetAts []time.Time
+}
+
+func (r *cooldownRepoStub) SetRateLimited(ctx context.Context, id int64, resetAt time.Time) error {
+	r.resetAts = append(r.resetAts, resetAt)
+	return nil
+}
+
+func newCooldownTestService(repo *cooldownRepoStub) *RateLimitService {
+	cfg := &config.Config{RateLimit: config.RateLimitConfig{
+		CooldownBackoffBaseSeconds: 60,
+		CooldownBackoffMaxSeconds:  300,
+		CooldownHistorySize:        4,
+	}}
+	return NewRateLimitService(repo, nil, cfg, nil, nil)
+}
+
+func TestHandle429_ExponentialBackoffWithoutResetInfo(t *testing.T) {
+	repo := &cooldownRepoStub{}
+	svc := newCooldownTestService(repo)
+	account := &Account{ID: 1, Platform: PlatformGemini}
+
+	for i := 0; i < 4; i++ {
+		svc.handle429(context.Background(), account, http.Header{}, nil)
+	}
+
+	require.Len(t, repo.resetAts, 4)
+	history := svc.ListCooldownHistory(1, 0)
+	require.Len(t, history, 4)
+	// 倒序：最新的在前
+	require.Equal(t, 4, history[0].Attempt)
+	require.Equal(t, CooldownSourceBackoff, history[0].Source)
+	require.InDelta(t, 300, history[0].CooldownSeconds, 1)
+	require.InDelta(t, 240, history[1].CooldownSeconds, 1)
+	require.InDelta(t, 120, history[2].CooldownSeconds, 1)
+	require.InDelta(t, 60, history[3].CooldownSeconds, 1)
+}
+
+func TestHandle429_RetryAfterHeader(t *testing.T) {
+	repo := &cooldownRepoStub{}
+	svc := newCooldownTestService(repo)
+	account := &Account{ID: 2, Platform: PlatformGemini}
+
+	headers := http.Header{}
+	headers.Set("Retry-After", "42")
+	svc.handle429(context.Background(), account, headers, nil)
+
+	require.Len(t, repo.resetAts, 1)
+	require.WithinDuration(t, time.Now().Add(42*time.Second), repo.resetAts[0], time.Second)
+	history := svc.ListCooldownHistory(2, 10)
+	require.Len(t, history, 1)
+	require.Equal(t, CooldownSourceRetryAfter, history[0].Source)
+}
+
+func TestCooldownHistory_RingBufferAndFilter(t *testing.T) {
+	repo := &cooldownRepoStub{}
+	svc := newCooldownTestService(repo)
+
+	for i := int64(1); i <= 6; i++ {
+		svc.handle429(context.Background(), &Account{ID: i % 2, Platform: PlatformGemini}, http.Header{}, nil)
+	}
+	all := svc.ListCooldownHistory(0, 0)
+	require.Len(t, all, 4)
+	require.Equal(t, int64(0), all[0].AccountID)
+	require.Len(t, svc.ListCooldownHistory(1, 0), 2)
+	require.Len(t, svc.ListCooldownHistory(0, 3), 3)
+}
+
+func TestParseRetryAfter(t *testing.T) {
+	now := time.Now()
+	headers := http.Header{}
+	headers.Set("Retry-After", now.Add(90*time.Second).UTC().Format(http.TimeFormat))
+	d, ok := parseRetryAfter(headers, now)
+	require.True(t, ok)
+	require.InDelta(t, 90, d.Seconds(), 1)
+
+	headers.Set("Retry-After", "0")
+	_, ok = parseRetryAfter(headers, now)
+	require.False(t, ok)
+
+	_, ok = parseRetryAfter(nil, now)
+	require.False(t, ok)
+}
+
+func TestClearRateLimit_ResetsCooldownStreak(t *testing.T) {
+	repo := &rateLimitClearRepoStub{}
+	svc := NewRateLimitService(repo, nil, &config.Config{}, nil, nil)
+	now := time.Now()
+	svc.recordCooldown(&Account{ID: 5}, now.Add(time.Minute), CooldownSourceBackoff, 3)
+	require.Equal(t, 4, svc.cooldowns.nextAttempt(5, now))
+
+	require.NoError(t, svc.ClearRateLimit(context.Background(), 5))
+	require.Equal(t, 1, svc.cooldowns.nextAttempt(5, now))
+}
+
+func TestUpstreamRetryAfter(t *testing.T) {
+	now := time.Date(2026, 5, 1, 12, 0, 0, 0, time.UTC)
+
+	_, ok := UpstreamRetryAfter(http.Header{}, now)
+	require.False(t, ok)
+
+	d, ok := UpstreamRetryAfter(http.Header{"Retry-After": []string{"7"}, "X-Ratelimit-Reset-Requests": []string{"1s"}}, now)
+	require.True(t, ok)
+	require.Equal(t, 7*time.Second, d)
+
+	d, ok = UpstreamRetryAfter(http.Header{"X-Ratelimit-Reset-Requests": []string{"20s"}, "X-Ratelimit-Reset-Tokens": []string{"6m0s"}}, now)
+	require.True(t, ok)
+	require.Equal(t, 20*time.Second, d)
+
+	d, ok = UpstreamRetryAfter(http.Header{
+		"Anthropic-Ratelimit-Tokens-Reset":        []string{now.Add(45 * time.Second).Format(time.RFC3339)},
+		"Anthropic-Ratelimit-Unified-5h-Reset":    []string{"1777777777"},
+		"Anthropic-Ratelimit-Requests-Reset":      []string{now.Add(-time.Second).Format(time.RFC3339)},
+		"Anthropic-Ratelimit-Output-Tokens-Reset": []string{"garbage"},
+	}, now)
+	require.True(t, ok)
+	require.Equal(t, 45*time.Second, d)
+}
@@ -3,6 +3,7 @@ package service
 import (
 	"context"
 	"encoding/json"
+	"fmt"
 	"log/slog"
 	"net/http"
 	"strconv"
@@ -24,6 +25,9 @@ type RateLimitService struct {
 	timeoutCounterCache   TimeoutCounterCache
 	settingService        *SettingService
 	tokenCacheInvalidator TokenCacheInvalidator
+	accountHealth         *AccountHealthService
+	alertService          *AlertService
+	cooldowns             *rateLimitCooldownTracker
 	usageCacheMu          sync.RWMutex
 	usageCache            map[int64]*geminiUsageCacheEntry
 }
@@ -42,12 +46,17 @@ const geminiPrecheckCacheTTL = time.Minute
 
 // NewRateLimitService 创建RateLimitService实例
 func NewRateLimitService(accountRepo AccountRepository, usageRepo UsageLogRepository, cfg *config.Config, geminiQuotaService *GeminiQuotaService, tempUnschedCache TempUnschedCache) *RateLimitService {
+	historySize := 0
+	if cfg != nil {
+		historySize = cfg.RateLimit.CooldownHistorySize
+	}
 	return &RateLimitService{
 		accountRepo:        accountRepo,
 		usageRepo:          usageRepo,
 		cfg:                cfg,
 		geminiQuotaService: geminiQuotaService,
 		tempUnschedCache:   tempUnschedCache,
+		cooldowns:          newRateLimitCooldownTracker(historySize),
 		usageCache:         make(map[int64]*geminiUsageCacheEntry),
 	}
 }
@@ -67,6 +76,33 @@ func (s *RateLimitService) SetTokenCacheInvalidator(invalidator TokenCacheInvali
 	s.tokenCacheInvalidator = invalidator
 }
 
+// SetAccountHealthService 设置账号健康检查服务（可选依赖）
+func (s *RateLimitService) SetAccountHealthService(health *AccountHealthService) {
+	s.accountHealth = health
+}
+
+// SetAlertService 设置运维告警服务（可选依赖）
+func (s *RateLimitService) SetAlertService(alertService *AlertService) {
+	s.alertService = alertService
+}
+
+// fireAccountDisabledAlert 账号被标记为 error 状态（停止调度）时发送告警
+func (s *RateLimitService) fireAccountDisabledAlert(ctx context.Context, account *Account, reason string) {
+	s.alertService.Fire(ctx, &AlertEvent{
+		Type:     AlertEventAccountDisabled,
+		Severity: AlertSeverityCritical,
+		Title:    fmt.Sprintf("Account disabled: %s (#%d)", account.Name, account.ID),
+		Message:  reason,
+		Fields: map[string]any{
+			"account_id":   account.ID,
+			"account_name": account.Name,
+			"platform":     account.Platform,
+			"type":         account.Type,
+		},
+		DedupKey: strconv.FormatInt(account.ID, 10),
+	})
+}
+
 // ErrorPolicyResult 表示错误策略检查的结果
 type ErrorPolicyResult int
 
@@ -96,6 +132,9 @@ func (s *RateLimitService) CheckErrorPolicy(ctx context.Context, account *Accoun
 // HandleUpstreamError 处理上游错误响应，标记账号状态
 // 返回是否应该停止该账号的调度
 func (s *RateLimitService) HandleUpstreamError(ctx context.Context, account *Account, statusCode int, headers http.Header, responseBody []byte) (shouldDisable bool) {
+	// 上游错误体可能携带账号当前的订阅计划（如 usage_limit_reached），用于按计划过滤模型
+	s.syncOpenAIPlanFromError(ctx, account, responseBody)
+
 	// apikey 类型账号：检查自定义错误码配置
 	// 如果启用且错误码不在列表中，则不处理（不停止调度、不标记限流/过载）
 	customErrorCodesEnabled := account.IsCustomErrorCodesEnabled()
@@ -218,6 +257,7 @@ func (s *RateLimitService) HandleUpstreamError(ctx context.Context, account *Acc
 		}
 	}
 
+	s.accountHealth.RecordFailure(ctx, account, statusCode, upstreamMsg, shouldDisable)
 	return shouldDisable
 }
 
@@ -598,6 +638,7 @@ func (s *RateLimitService) handleAuthError(ctx context.Context, account *Account
 		return
 	}
 	slog.Warn("account_disabled_auth_error", "account_id", account.ID, "error", errorMsg)
+	s.fireAccountDisabledAlert(ctx, account, errorMsg)
 }
 
 // handleCustomErrorCode 处理自定义错误码，停止账号调度
@@ -608,6 +649,7 @@ func (s *RateLimitService) handleCustomErrorCode(ctx context.Context, account *A
 		return
 	}
 	slog.Warn("account_disabled_custom_error", "account_id", account.ID, "status_code", statusCode, "error", errorMsg)
+	s.fireAccountDisabledAlert(ctx, account, msg)
 }
 
 // handle429 处理429限流错误
@@ -621,6 +663,7 @@ func (s *RateLimitService) handle429(ctx context.Context, account *Account, head
 				slog.Warn("rate_limit_set_failed", "account_id", account.ID, "error", err)
 				return
 			}
+			s.recordCooldown(account, *resetAt, CooldownSourceHeaders, 0)
 			slog.Info("openai_account_rate_limited", "account_id", account.ID, "reset_at", *resetAt)
 			return
 		}
@@ -643,6 +686,7 @@ func (s *RateLimitService) handle429(ctx context.Context, account *Account, head
 			slog.Warn("rate_limit_update_session_window_failed", "account_id", account.ID, "error", err)
 		}
 
+		s.recordCooldown(account, result.resetAt, CooldownSourceHeaders, 0)
 		slog.Info("anthropic_account_rate_limited", "account_id", account.ID, "reset_at", result.resetAt, "reset_in", time.Until(result.resetAt).Truncate(time.Second))
 		return
 	}
@@ -661,6 +705,7 @@ func (s *RateLimitService) handle429(ctx context.Context, account *Account, head
 					slog.Warn("rate_limit_set_failed", "account_id", account.ID, "error", err)
 					return
 				}
+				s.recordCooldown(account, resetTime, CooldownSourceBody, 0)
 				slog.Info("account_rate_limited", "account_id", account.ID, "platform", account.Platform, "reset_at", resetTime, "reset_in", time.Until(resetTime).Truncate(time.Second))
 				return
 			}
@@ -672,6 +717,7 @@ func (s *RateLimitService) handle429(ctx context.Context, account *Account, head
 					slog.Warn("rate_limit_set_failed", "account_id", account.ID, "error", err)
 					return
 				}
+				s.recordCooldown(account, resetTime, CooldownSourceBody, 0)
 				slog.Info("account_rate_limited", "account_id", account.ID, "platform", account.Platform, "reset_at", resetTime, "reset_in", time.Until(resetTime).Truncate(time.Second))
 				return
 			}
@@ -687,12 +733,8 @@ func (s *RateLimitService) handle429(ctx context.Context, account *Account, head
 			return
 		}
 
-		// 其他平台：没有重置时间，使用默认5分钟
-		resetAt := time.Now().Add(5 * time.Minute)
-		slog.Warn("rate_limit_no_reset_time", "account_id", account.ID, "platform", account.Platform, "using_default", "5m")
-		if err := s.accountRepo.SetRateLimited(ctx, account.ID, resetAt); err != nil {
-			slog.Warn("rate_limit_set_failed", "account_id", account.ID, "error", err)
-		}
+		// 其他平台：没有重置时间，使用 Retry-After 或按连续次数指数退避
+		s.applyFallback429Cooldown(ctx, account, headers)
 		return
 	}
 
@@ -700,10 +742,7 @@ func (s *RateLimitService) handle429(ctx context.Context, account *Account, head
 	ts, err := strconv.ParseInt(resetTimestamp, 10, 64)
 	if err != nil {
 		slog.Warn("rate_limit_reset_parse_failed", "reset_timestamp", resetTimestamp, "error", err)
-		resetAt := time.Now().Add(5 * time.Minute)
-		if err := s.accountRepo.SetRateLimited(ctx, account.ID, resetAt); err != nil {
-			slog.Warn("rate_limit_set_failed", "account_id", account.ID, "error", err)
-		}
+		s.applyFallback429Cooldown(ctx, account, headers)
 		return
 	}
 
@@ -722,9 +761,21 @@ func (s *RateLimitService) handle429(ctx context.Context, account *Account, head
 		slog.Warn("rate_limit_update_session_window_failed", "account_id", account.ID, "error", err)
 	}
 
+	s.recordCooldown(account, resetAt, CooldownSourceHeaders, 0)
 	slog.Info("account_rate_limited", "account_id", account.ID, "reset_at", resetAt)
 }
 
+// applyFallback429Cooldown 无窗口重置时间的 429：按 Retry-After / 指数退避设置冷却并记录历史
+func (s *RateLimitService) applyFallback429Cooldown(ctx context.Context, account *Account, headers http.Header) {
+	resetAt, source, attempt := s.fallback429Cooldown(account, headers, time.Now())
+	slog.Warn("rate_limit_no_reset_time", "account_id", account.ID, "platform", account.Platform, "source", source, "attempt", attempt, "cooldown", time.Until(resetAt).Truncate(time.Second))
+	if err := s.accountRepo.SetRateLimited(ctx, account.ID, resetAt); err != nil {
+		slog.Warn("rate_limit_set_failed", "account_id", account.ID, "error", err)
+		return
+	}
+	s.recordCooldown(account, resetAt, source, attempt)
+}
+
 // calculateOpenAI429ResetTime 从 OpenAI 429 响应头计算正确的重置时间
 // 返回 nil 表示无法从响应头中确定重置时间
 func (s *RateLimitService) calculateOpenAI429ResetTime(headers http.Header) *time.Time {
@@ -1047,6 +1098,7 @@ func (s *RateLimitService) ClearRateLimit(ctx context.Context, accountID int64)
 	if err := s.accountRepo.ClearRateLimit(ctx, accountID); err != nil {
 		return err
 	}
+	s.cooldowns.reset(accountID)
 	if err := s.accountRepo.ClearAntigravityQuotaScopes(ctx, accountID); err != nil {
 		return err
 	}
@@ -1399,5 +1451,6 @@ func (s *RateLimitService) triggerStreamTimeoutError(ctx context.Context, accoun
 	}
 
 	slog.Warn("stream_timeout_account_error", "account_id", account.ID, "model", model)
+	s.fireAccountDisabledAlert(ctx, account, errorMsg)
 	return true
 }
@@ -0,0 +1,132 @@
+package service
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/ShaohongDong/sub2api/internal/config"
+	"github.com/ShaohongDong/sub2api/internal/pkg/apicompat"
+	"github.com/ShaohongDong/sub2api/internal/pkg/clientdetect"
+	"github.com/tidwall/gjson"
+	"github.com/tidwall/sjson"
+)
+
+// 推理强度默认值支持的入口请求格式
+const (
+	ReasoningFormatAnthropic       = "anthropic"        // /v1/messages：thinking.budget_tokens / output_config.effort
+	ReasoningFormatResponses       = "responses"        // /v1/responses：reasoning.effort
+	ReasoningFormatChatCompletions = "chat_completions" // /v1/chat/completions：reasoning_effort
+)
+
+// anthropicMinThinkingBudget Anthropic thinking.budget_tokens 的最小值
+const anthropicMinThinkingBudget = 1024
+
+// RequestSetsReasoningEffort 判断客户端是否在请求体中显式设置了推理强度（含显式关闭思考）
+func RequestSetsReasoningEffort(body []byte, format string) bool {
+	switch format {
+	case ReasoningFormatAnthropic:
+		return gjson.GetBytes(body, "thinking.type").Exists() || gjson.GetBytes(body, "output_config.effort").Exists()
+	case ReasoningFormatResponses:
+		return gjson.GetBytes(body, "reasoning.effort").Exists()
+	case ReasoningFormatChatCompletions:
+		return gjson.GetBytes(body, "reasoning_effort").Exists() || gjson.GetBytes(body, "reasoning.effort").Exists()
+	default:
+		return false
+	}
+}
+
+// reasoningRuleMatches 判断规则是否命中当前请求
+func reasoningRuleMatches(rule *config.GatewayReasoningDefaultRule, model string, info clientdetect.ClientInfo, apiKeyID int64) bool {
+	if len(rule.Models) > 0 && !routingModelMatches(rule.Models, model) {
+		return false
+	}
+	if len(rule.APIKeyIDs) > 0 && !containsInt64(rule.APIKeyIDs, apiKeyID) {
+		return false
+	}
+	if len(rule.Clients) > 0 {
+		for _, client := range rule.Clients {
+			if ClientMatchesRestriction(client, info) {
+				return true
+			}
+		}
+		return false
+	}
+	return true
+}
+
+// ResolveReasoningDefault 按优先级返回本次请求应写入的推理强度：
+// 首条命中的 force 规则 > 客户端显式设置 > 首条命中的非 force 规则。返回空字符串表示保持请求不变。
+func ResolveReasoningDefault(rules []config.GatewayReasoningDefaultRule, body []byte, format, model string, info clientdetect.ClientInfo, apiKeyID int64) string {
+	for i := range rules {
+		if rules[i].Force && reasoningRuleMatches(&rules[i], model, info, apiKeyID) {
+			return rules[i].Effort
+		}
+	}
+	if RequestSetsReasoningEffort(body, format) {
+		return ""
+	}
+	for i := range rules {
+		if !rules[i].Force && reasoningRuleMatches(&rules[i], model, info, apiKeyID) {
+			return rules[i].Effort
+		}
+	}
+	return ""
+}
+
+// ApplyReasoningEffort 按入口格式把推理强度写入请求体；无法安全写入时返回 false 并保持原请求体。
+// Anthropic 格式写入 thinking.budget_tokens（按 apicompat.ThinkingBudgetForEffort 换算），
+// 客户端已带 output_config.effort 时一并改写。
+func ApplyReasoningEffort(body []byte, format, effort string) ([]byte, bool) {
+	path := ""
+	switch format {
+	case ReasoningFormatResponses:
+		path = "reasoning.effort"
+	case ReasoningFormatChatCompletions:
+		path = "reasoning_effort"
+		if gjson.GetBytes(body, "reasoning.effort").Exists() {
+			path = "reasoning.effort"
+		}
+	case ReasoningFormatAnthropic:
+		return applyAnthropicReasoningEffort(body, effort)
+	default:
+		return body, false
+	}
+	out, err := sjson.SetBytes(body, path, effort)
+	if err != nil {
+		return body, false
+	}
+	return out, true
+}
+
+// applyAnthropicReasoningEffort 启用 extended thinking 并设置预算。
+// Anthropic 要求 budget_tokens < max_tokens，且思考模式不能与自定义 temperature / top_k 或强制工具调用同时使用，
+// 不满足时跳过而不是让请求失败。
+func applyAnthropicReasoningEffort(body []byte, effort string) ([]byte, bool) {
+	if temp := gjson.GetBytes(body, "temperature"); temp.Exists() && temp.Float() != 1 {
+		return body, false
+	}
+	if gjson.GetBytes(body, "top_k").Exists() {
+		return body, false
+	}
+	switch strings.ToLower(gjson.GetBytes(body, "tool_choice.type").String()) {
+	case "any", "tool":
+		return body, false
+	}
+	budget := apicompat.ThinkingBudgetForEffort(effort)
+	if maxTokens := gjson.GetBytes(body, "max_tokens").Int(); maxTokens > 0 && int64(budget) >= maxTokens {
+		budget = int(maxTokens) - 1
+	}
+	if budget < anthropicMinThinkingBudget {
+		return body, false
+	}
+	out, err := sjson.SetRawBytes(body, "thinking", []byte(`{"type":"enabled","budget_tokens":`+strconv.Itoa(budget)+`}`))
+	if err != nil {
+		return body, false
+	}
+	if gjson.GetBytes(out, "output_config.effort").Exists() {
+		if out, err = sjson.SetBytes(out, "output_config.effort", apicompat.MapResponsesEffortToAnthropic(effort)); err != nil {
+			return body, false
+		}
+	}
+	return out, true
+}
@@ -0,0 +1,68 @@
+//go:build unit
+
+package service
+
+import (
+	"testing"
+
+	"github.com/ShaohongDong/sub2api/internal/config"
+	"github.com/ShaohongDong/sub2api/internal/pkg/clientdetect"
+	"github.com/stretchr/testify/require"
+	"github.com/tidwall/gjson"
+)
+
+func TestResolveReasoningDefault_Precedence(t *testing.T) {
+	rules := []config.GatewayReasoningDefaultRule{
+		{Models: []string{"gpt-5-codex*"}, Clients: []string{APIKeyClientRestrictionCodex}, Effort: "high", Force: true},
+		{Models: []string{"gpt-5*"}, Effort: "low"},
+		{APIKeyIDs: []int64{42}, Effort: "medium"},
+	}
+	codex := clientdetect.ClientInfo{Type: clientdetect.TypeCodexCLI}
+	sdk := clientdetect.ClientInfo{Type: clientdetect.TypeOpenAISDK}
+
+	// force 规则覆盖客户端显式设置
+	explicit := []byte(`{"model":"gpt-5-codex","reasoning":{"effort":"minimal"}}`)
+	require.Equal(t, "high", ResolveReasoningDefault(rules, explicit, ReasoningFormatResponses, "gpt-5-codex", codex, 1))
+	// 非 Codex 客户端不命中 force 规则，客户端显式设置优先于默认规则
+	require.Empty(t, ResolveReasoningDefault(rules, explicit, ReasoningFormatResponses, "gpt-5-codex", sdk, 1))
+	// 客户端未设置时按首条命中的默认规则补充
+	plain := []byte(`{"model":"gpt-5"}`)
+	require.Equal(t, "low", ResolveReasoningDefault(rules, plain, ReasoningFormatResponses, "gpt-5", sdk, 42))
+	require.Equal(t, "medium", ResolveReasoningDefault(rules, plain, ReasoningFormatResponses, "claude-sonnet-4-5", sdk, 42))
+	require.Empty(t, ResolveReasoningDefault(rules, plain, ReasoningFormatResponses, "claude-sonnet-4-5", sdk, 7))
+	// Anthropic 显式关闭思考同样视为客户端设置
+	disabled := []byte(`{"model":"gpt-5","thinking":{"type":"disabled"}}`)
+	require.Empty(t, ResolveReasoningDefault(rules, disabled, ReasoningFormatAnthropic, "gpt-5", sdk, 1))
+}
+
+func TestApplyReasoningEffort_Formats(t *testing.T) {
+	out, ok := ApplyReasoningEffort([]byte(`{"model":"gpt-5"}`), ReasoningFormatResponses, "high")
+	require.True(t, ok)
+	require.Equal(t, "high", gjson.GetBytes(out, "reasoning.effort").String())
+
+	out, ok = ApplyReasoningEffort([]byte(`{"model":"gpt-5"}`), ReasoningFormatChatCompletions, "xhigh")
+	require.True(t, ok)
+	require.Equal(t, "xhigh", gjson.GetBytes(out, "reasoning_effort").String())
+
+	out, ok = ApplyReasoningEffort([]byte(`{"model":"claude","max_tokens":64000,"output_config":{"effort":"low"}}`), ReasoningFormatAnthropic, "xhigh")
+	require.True(t, ok)
+	require.Equal(t, "enabled", gjson.GetBytes(out, "thinking.type").String())
+	require.Equal(t, int64(32768), gjson.GetBytes(out, "thinking.budget_tokens").Int())
+	require.Equal(t, "max", gjson.GetBytes(out, "output_config.effort").String())
+
+	// 预算受 max_tokens 约束
+	out, ok = ApplyReasoningEffort([]byte(`{"model":"claude","max_tokens":8000}`), ReasoningFormatAnthropic, "high")
+	require.True(t, ok)
+	require.Equal(t, int64(7999), gjson.GetBytes(out, "thinking.budget_tokens").Int())
+
+	// 与思考模式不兼容的请求保持不变
+	for _, body := range []string{
+		`{"model":"claude","max_tokens":500}`,
+		`{"model":"claude","max_tokens":8000,"temperature":0.2}`,
+		`{"model":"claude","max_tokens":8000,"tool_choice":{"type":"any"}}`,
+	} {
+		out, ok = ApplyReasoningEffort([]byte(body), ReasoningFormatAnthropic, "high")
+		require.False(t, ok, body)
+		require.JSONEq(t, body, string(out))
+	}
+}
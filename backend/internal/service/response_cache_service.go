@@ -0,0 +1,170 @@
+package service
+
+import (
+	"bytes"
+	"container/list"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/ShaohongDong/sub2api/internal/config"
+	"github.com/tidwall/gjson"
+)
+
+// ErrResponseCacheMiss 标记响应缓存未命中
+var ErrResponseCacheMiss = errors.New("response cache miss")
+
+// CachedResponse 缓存的上游响应（仅状态 200 的非流式响应）
+type CachedResponse struct {
+	Status      int    `json:"status"`
+	ContentType string `json:"content_type"`
+	Body        []byte `json:"body"`
+}
+
+// ResponseCacheStore 响应缓存存储（memory 由 ResponseCacheService 内置，redis 由 repository 提供）
+type ResponseCacheStore interface {
+	GetResponse(ctx context.Context, key string) (*CachedResponse, error)
+	SetResponse(ctx context.Context, key string, resp *CachedResponse, ttl time.Duration) error
+}
+
+// ResponseCacheService 缓存确定性的非流式请求（temperature 显式为 0）的响应。
+// 缓存键为用户、分组、请求路径与规范化请求体（键排序、去空白）的 SHA-256，
+// 因此字段顺序或格式不同的相同请求也能命中；命中的请求不经过上游、不计费。
+type ResponseCacheService struct {
+	cfg   config.ResponseCacheConfig
+	store ResponseCacheStore
+}
+
+// NewResponseCacheService creates a new ResponseCacheService.
+// backend 为 redis 时使用 redisStore，否则使用进程内存储。
+func NewResponseCacheService(cfg *config.Config, redisStore ResponseCacheStore) *ResponseCacheService {
+	s := &ResponseCacheService{}
+	if cfg == nil || !cfg.ResponseCache.Enabled {
+		return s
+	}
+	s.cfg = cfg.ResponseCache
+	if s.cfg.Backend == "redis" && redisStore != nil {
+		s.store = redisStore
+	} else {
+		s.store = newMemoryResponseCacheStore(s.cfg.MaxEntries)
+	}
+	return s
+}
+
+// Enabled 是否启用响应缓存
+func (s *ResponseCacheService) Enabled() bool {
+	return s != nil && s.store != nil
+}
+
+// MaxBodyBytes 可缓存的最大响应体字节数
+func (s *ResponseCacheService) MaxBodyBytes() int {
+	return s.cfg.MaxBodyBytes
+}
+
+// Get 读取缓存；存储出错按未命中处理
+func (s *ResponseCacheService) Get(ctx context.Context, key string) (*CachedResponse, bool) {
+	if !s.Enabled() {
+		return nil, false
+	}
+	resp, err := s.store.GetResponse(ctx, key)
+	if err != nil || resp == nil {
+		return nil, false
+	}
+	return resp, true
+}
+
+// Set 写入缓存，超过 MaxBodyBytes 的响应不缓存
+func (s *ResponseCacheService) Set(ctx context.Context, key string, resp *CachedResponse) error {
+	if !s.Enabled() || resp == nil || len(resp.Body) > s.cfg.MaxBodyBytes {
+		return nil
+	}
+	return s.store.SetResponse(ctx, key, resp, time.Duration(s.cfg.TTLSeconds)*time.Second)
+}
+
+// ResponseCacheKey 返回请求的缓存键；流式、后台、temperature 未显式为 0 或请求体不是 JSON 对象时返回 false
+func ResponseCacheKey(userID, groupID int64, path string, body []byte) (string, bool) {
+	if !gjson.ValidBytes(body) {
+		return "", false
+	}
+	parsed := gjson.ParseBytes(body)
+	if !parsed.IsObject() || parsed.Get("stream").Bool() || parsed.Get("background").Bool() {
+		return "", false
+	}
+	temperature := parsed.Get("temperature")
+	if temperature.Type != gjson.Number || temperature.Float() != 0 {
+		return "", false
+	}
+	canonical, err := canonicalizeJSON(body)
+	if err != nil {
+		return "", false
+	}
+	h := sha256.New()
+	h.Write([]byte(strconv.FormatInt(userID, 10) + ":" + strconv.FormatInt(groupID, 10) + ":" + path + ":"))
+	h.Write(canonical)
+	return hex.EncodeToString(h.Sum(nil)), true
+}
+
+// canonicalizeJSON 以键排序、无空白的形式重新编码 JSON（数字按原文保留）
+func canonicalizeJSON(body []byte) ([]byte, error) {
+	dec := json.NewDecoder(bytes.NewReader(body))
+	dec.UseNumber()
+	var v any
+	if err := dec.Decode(&v); err != nil {
+		return nil, err
+	}
+	return json.Marshal(v)
+}
+
+type memoryResponseCacheEntry struct {
+	key       string
+	resp      *CachedResponse
+	expiresAt time.Time
+}
+
+// memoryResponseCacheStore 进程内响应缓存，超出容量时淘汰最早写入的条目
+type memoryResponseCacheStore struct {
+	mu         sync.Mutex
+	maxEntries int
+	entries    map[string]*list.Element
+	order      *list.List // 按写入顺序，最早写入在前
+}
+
+func newMemoryResponseCacheStore(maxEntries int) *memoryResponseCacheStore {
+	return &memoryResponseCacheStore{maxEntries: maxEntries, entries: make(map[string]*list.Element), order: list.New()}
+}
+
+func (m *memoryResponseCacheStore) GetResponse(_ context.Context, key string) (*CachedResponse, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	el, ok := m.entries[key]
+	if !ok {
+		return nil, ErrResponseCacheMiss
+	}
+	entry := el.Value.(*memoryResponseCacheEntry)
+	if !time.Now().Before(entry.expiresAt) {
+		m.order.Remove(el)
+		delete(m.entries, key)
+		return nil, ErrResponseCacheMiss
+	}
+	return entry.resp, nil
+}
+
+func (m *memoryResponseCacheStore) SetResponse(_ context.Context, key string, resp *CachedResponse, ttl time.Duration) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if el, ok := m.entries[key]; ok {
+		m.order.Remove(el)
+	}
+	m.entries[key] = m.order.PushBack(&memoryResponseCacheEntry{key: key, resp: resp, expiresAt: time.Now().Add(ttl)})
+	for m.maxEntries > 0 && m.order.Len() > m.maxEntries {
+		oldest := m.order.Front()
+		m.order.Remove(oldest)
+		delete(m.entries, oldest.Value.(*memoryResponseCacheEntry).key)
+	}
+	return nil
+}
@@ -0,0 +1,55 @@
+//go:build unit
+
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestResponseCacheKey(t *testing.T) {
+	key, ok := ResponseCacheKey(1, 2, "/v1/messages", []byte(`{"model":"claude","temperature":0,"max_tokens":10}`))
+	require.True(t, ok)
+	same, ok := ResponseCacheKey(1, 2, "/v1/messages", []byte(`{"max_tokens":10, "temperature":0, "model":"claude"}`))
+	require.True(t, ok)
+	require.Equal(t, key, same)
+
+	// 用户、分组、路径不同则键不同
+	other, _ := ResponseCacheKey(9, 2, "/v1/messages", []byte(`{"model":"claude","temperature":0,"max_tokens":10}`))
+	require.NotEqual(t, key, other)
+	other, _ = ResponseCacheKey(1, 2, "/v1/chat/completions", []byte(`{"model":"claude","temperature":0,"max_tokens":10}`))
+	require.NotEqual(t, key, other)
+
+	for _, body := range []string{
+		`{"model":"claude"}`,
+		`{"model":"claude","temperature":"0"}`,
+		`{"model":"claude","temperature":0.2}`,
+		`{"model":"claude","temperature":0,"stream":true}`,
+		`{"model":"claude","temperature":0,"background":true}`,
+		`[1,2]`,
+		`not json`,
+	} {
+		_, ok := ResponseCacheKey(1, 2, "/v1/messages", []byte(body))
+		require.False(t, ok, body)
+	}
+}
+
+func TestMemoryResponseCacheStore_ExpiryAndEviction(t *testing.T) {
+	ctx := context.Background()
+	store := newMemoryResponseCacheStore(2)
+	require.NoError(t, store.SetResponse(ctx, "a", &CachedResponse{Status: 200, Body: []byte("a")}, time.Minute))
+	require.NoError(t, store.SetResponse(ctx, "b", &CachedResponse{Status: 200, Body: []byte("b")}, -time.Second))
+	_, err := store.GetResponse(ctx, "b")
+	require.ErrorIs(t, err, ErrResponseCacheMiss)
+
+	require.NoError(t, store.SetResponse(ctx, "c", &CachedResponse{Status: 200, Body: []byte("c")}, time.Minute))
+	require.NoError(t, store.SetResponse(ctx, "d", &CachedResponse{Status: 200, Body: []byte("d")}, time.Minute))
+	_, err = store.GetResponse(ctx, "a")
+	require.ErrorIs(t, err, ErrResponseCacheMiss)
+	resp, err := store.GetResponse(ctx, "d")
+	require.NoError(t, err)
+	require.Equal(t, "d", string(resp.Body))
+}
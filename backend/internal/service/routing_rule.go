@@ -0,0 +1,313 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"sort"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/ShaohongDong/sub2api/internal/pkg/clientdetect"
+	"golang.org/x/sync/singleflight"
+)
+
+// maxRoutingRules 路由规则的最大条数
+const maxRoutingRules = 100
+
+// RoutingRequest 路由规则匹配所需的请求特征
+type RoutingRequest struct {
+	Model       string
+	ClientType  clientdetect.ClientType
+	Header      http.Header
+	APIKey      *APIKey
+	RequestSize int64
+}
+
+// groupID 返回请求 API Key 当前绑定的分组 ID，未绑定时为 0
+func (req *RoutingRequest) groupID() int64 {
+	if req.APIKey == nil || req.APIKey.GroupID == nil {
+		return 0
+	}
+	return *req.APIKey.GroupID
+}
+
+// Matches 判断请求是否满足规则的全部条件
+func (r *RoutingRule) Matches(req *RoutingRequest) bool {
+	if !r.Enabled || req == nil {
+		return false
+	}
+	if len(r.Models) > 0 && !routingModelMatches(r.Models, req.Model) {
+		return false
+	}
+	if len(r.ClientTypes) > 0 && !clientRoutingRuleMatches(ClientRoutingRule{ClientTypes: r.ClientTypes}, string(req.ClientType)) {
+		return false
+	}
+	for _, h := range r.Headers {
+		if !routingHeaderMatches(h, req.Header) {
+			return false
+		}
+	}
+	if len(r.KeyTags) > 0 && !req.APIKey.HasAnyTag(r.KeyTags) {
+		return false
+	}
+	if len(r.SourceGroupIDs) > 0 && !containsInt64(r.SourceGroupIDs, req.groupID()) {
+		return false
+	}
+	if r.MinRequestBytes > 0 && req.RequestSize < r.MinRequestBytes {
+		return false
+	}
+	if r.MaxRequestBytes > 0 && req.RequestSize > r.MaxRequestBytes {
+		return false
+	}
+	return true
+}
+
+func routingModelMatches(patterns []string, model string) bool {
+	model = strings.TrimPrefix(model, "models/")
+	if model == "" {
+		return false
+	}
+	for _, pattern := range patterns {
+		if matchModelPattern(pattern, model) {
+			return true
+		}
+	}
+	return false
+}
+
+func routingHeaderMatches(match RoutingRuleHeaderMatch, header http.Header) bool {
+	values := header.Values(match.Name)
+	if len(values) == 0 {
+		return false
+	}
+	if match.Value == "" {
+		return true
+	}
+	for _, v := range values {
+		if matchModelPattern(match.Value, strings.ToLower(strings.TrimSpace(v))) {
+			return true
+		}
+	}
+	return false
+}
+
+// MatchRoutingRule 按优先级返回首条命中的规则；未启用或无命中时返回 nil。
+// settings.Rules 须已按优先级排序（SetRoutingRuleSettings 保存时排序）。
+func MatchRoutingRule(settings *RoutingRuleSettings, req *RoutingRequest) *RoutingRule {
+	if settings == nil || !settings.Enabled {
+		return nil
+	}
+	for i := range settings.Rules {
+		if settings.Rules[i].Matches(req) {
+			return &settings.Rules[i]
+		}
+	}
+	return nil
+}
+
+// CanRouteToGroup 判断请求能否从 from 分组改由 to 分组调度：
+// 目标分组须处于启用状态、与原分组同平台同租户，且双方均为标准（余额）计费分组。
+func CanRouteToGroup(from, to *Group) bool {
+	if from == nil || to == nil {
+		return false
+	}
+	return to.IsActive() &&
+		to.Platform == from.Platform &&
+		from.SubscriptionType != SubscriptionTypeSubscription &&
+		to.SubscriptionType != SubscriptionTypeSubscription &&
+		TenantMatches(from.TenantID, to.TenantID)
+}
+
+// CanRouteAcrossPlatforms 判断请求能否从 from 分组改由其他平台的 to 分组调度（如 ChatGPT 与 Claude 订阅混合池）：
+// 双方平台的网关处理器均须支持 Messages / Chat Completions / Responses 之间的格式转换，其余条件同 CanRouteToGroup。
+func CanRouteAcrossPlatforms(from, to *Group) bool {
+	if from == nil || to == nil {
+		return false
+	}
+	return to.IsActive() &&
+		IsFallbackChainPlatform(from.Platform) &&
+		IsFallbackChainPlatform(to.Platform) &&
+		from.SubscriptionType != SubscriptionTypeSubscription &&
+		to.SubscriptionType != SubscriptionTypeSubscription &&
+		TenantMatches(from.TenantID, to.TenantID)
+}
+
+// normalizeRoutingRuleSettings 校验并规范化规则，按优先级稳定排序
+func normalizeRoutingRuleSettings(settings *RoutingRuleSettings) error {
+	if len(settings.Rules) > maxRoutingRules {
+		return fmt.Errorf("at most %d routing rules are allowed", maxRoutingRules)
+	}
+	for i := range settings.Rules {
+		rule := &settings.Rules[i]
+		rule.Name = strings.TrimSpace(rule.Name)
+		if rule.Name == "" {
+			return fmt.Errorf("rule[%d]: name cannot be empty", i)
+		}
+		if rule.TargetGroupID <= 0 {
+			return fmt.Errorf("rule[%d]: target_group_id is required", i)
+		}
+		models, err := normalizeAPIKeyAllowedModels(rule.Models)
+		if err != nil {
+			return fmt.Errorf("rule[%d]: invalid models (only a trailing * wildcard is supported)", i)
+		}
+		rule.Models = models
+		rule.ClientTypes = normalizeClientRoutingValues(rule.ClientTypes)
+		for _, ct := range rule.ClientTypes {
+			if ct != ClientRoutingMatchAll && !clientdetect.IsKnownType(ct) {
+				return fmt.Errorf("rule[%d]: unknown client type %q", i, ct)
+			}
+		}
+		for j := range rule.Headers {
+			h := &rule.Headers[j]
+			h.Name = http.CanonicalHeaderKey(strings.TrimSpace(h.Name))
+			h.Value = strings.ToLower(strings.TrimSpace(h.Value))
+			if h.Name == "" {
+				return fmt.Errorf("rule[%d]: header name cannot be empty", i)
+			}
+			if strings.Contains(strings.TrimSuffix(h.Value, "*"), "*") {
+				return fmt.Errorf("rule[%d]: header %s value only supports a trailing * wildcard", i, h.Name)
+			}
+		}
+		tags, err := normalizeAPIKeyTags(rule.KeyTags)
+		if err != nil {
+			return fmt.Errorf("rule[%d]: invalid key tags", i)
+		}
+		rule.KeyTags = tags
+		sourceIDs := make([]int64, 0, len(rule.SourceGroupIDs))
+		for _, id := range rule.SourceGroupIDs {
+			if id <= 0 {
+				return fmt.Errorf("rule[%d]: invalid source group id %d", i, id)
+			}
+			if !containsInt64(sourceIDs, id) {
+				sourceIDs = append(sourceIDs, id)
+			}
+		}
+		sort.Slice(sourceIDs, func(a, b int) bool { return sourceIDs[a] < sourceIDs[b] })
+		rule.SourceGroupIDs = sourceIDs
+		if rule.MinRequestBytes < 0 || rule.MaxRequestBytes < 0 ||
+			(rule.MaxRequestBytes > 0 && rule.MinRequestBytes > rule.MaxRequestBytes) {
+			return fmt.Errorf("rule[%d]: invalid request size range", i)
+		}
+	}
+	sort.SliceStable(settings.Rules, func(a, b int) bool {
+		return settings.Rules[a].Priority < settings.Rules[b].Priority
+	})
+	if settings.Rules == nil {
+		settings.Rules = []RoutingRule{}
+	}
+	return nil
+}
+
+// cachedRoutingRules 路由规则进程内缓存
+type cachedRoutingRules struct {
+	settings  *RoutingRuleSettings
+	expiresAt int64 // unix nano
+}
+
+var routingRuleCache atomic.Value // *cachedRoutingRules
+
+var routingRuleSF singleflight.Group
+
+// routingRuleCacheTTL 缓存有效期
+const routingRuleCacheTTL = 60 * time.Second
+
+// GetRoutingRuleSettings 获取路由规则配置
+func (s *SettingService) GetRoutingRuleSettings(ctx context.Context) (*RoutingRuleSettings, error) {
+	value, err := s.settingRepo.GetValue(ctx, SettingKeyRoutingRuleSettings)
+	if err != nil {
+		if errors.Is(err, ErrSettingNotFound) {
+			return DefaultRoutingRuleSettings(), nil
+		}
+		return nil, fmt.Errorf("get routing rule settings: %w", err)
+	}
+	if value == "" {
+		return DefaultRoutingRuleSettings(), nil
+	}
+
+	var settings RoutingRuleSettings
+	if err := json.Unmarshal([]byte(value), &settings); err != nil {
+		return DefaultRoutingRuleSettings(), nil
+	}
+	if settings.Rules == nil {
+		settings.Rules = []RoutingRule{}
+	}
+	return &settings, nil
+}
+
+// SetRoutingRuleSettings 设置路由规则配置，并立即刷新本实例缓存（其他实例在缓存过期后生效）
+func (s *SettingService) SetRoutingRuleSettings(ctx context.Context, settings *RoutingRuleSettings) error {
+	if settings == nil {
+		return fmt.Errorf("settings cannot be nil")
+	}
+	if err := normalizeRoutingRuleSettings(settings); err != nil {
+		return err
+	}
+
+	data, err := json.Marshal(settings)
+	if err != nil {
+		return fmt.Errorf("marshal routing rule settings: %w", err)
+	}
+	if err := s.settingRepo.Set(ctx, SettingKeyRoutingRuleSettings, string(data)); err != nil {
+		return err
+	}
+	routingRuleSF.Forget("routing_rules")
+	routingRuleCache.Store(&cachedRoutingRules{
+		settings:  settings,
+		expiresAt: time.Now().Add(routingRuleCacheTTL).UnixNano(),
+	})
+	return nil
+}
+
+// GetRoutingRules 返回路由规则配置（进程内缓存，60 秒 TTL），供网关热路径使用。
+// 读取失败时返回 nil（fail-open，不改写路由）。
+func (s *SettingService) GetRoutingRules(ctx context.Context) *RoutingRuleSettings {
+	if s == nil {
+		return nil
+	}
+	if cached, ok := routingRuleCache.Load().(*cachedRoutingRules); ok {
+		if time.Now().UnixNano() < cached.expiresAt {
+			return cached.settings
+		}
+	}
+	result, _, _ := routingRuleSF.Do("routing_rules", func() (any, error) {
+		if cached, ok := routingRuleCache.Load().(*cachedRoutingRules); ok {
+			if time.Now().UnixNano() < cached.expiresAt {
+				return cached.settings, nil
+			}
+		}
+		dbCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), versionBoundsDBTimeout)
+		defer cancel()
+		settings, err := s.GetRoutingRuleSettings(dbCtx)
+		if err != nil {
+			slog.Warn("failed to get routing rule settings, skipping routing rules", "error", err)
+			routingRuleCache.Store(&cachedRoutingRules{
+				expiresAt: time.Now().Add(versionBoundsErrorTTL).UnixNano(),
+			})
+			return (*RoutingRuleSettings)(nil), nil
+		}
+		routingRuleCache.Store(&cachedRoutingRules{
+			settings:  settings,
+			expiresAt: time.Now().Add(routingRuleCacheTTL).UnixNano(),
+		})
+		return settings, nil
+	})
+	settings, _ := result.(*RoutingRuleSettings)
+	return settings
+}
+
+// ResolveRoutingGroup 加载路由规则的目标分组
+func (s *APIKeyService) ResolveRoutingGroup(ctx context.Context, groupID int64) (*Group, error) {
+	if s == nil || s.groupRepo == nil {
+		return nil, ErrGroupNotFound
+	}
+	group, err := s.groupRepo.GetByIDLite(ctx, groupID)
+	if err != nil {
+		return nil, fmt.Errorf("get group: %w", err)
+	}
+	return group, nil
+}
@@ -0,0 +1,146 @@
+//go:build unit
+
+package service
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/ShaohongDong/sub2api/internal/config"
+	"github.com/ShaohongDong/sub2api/internal/pkg/clientdetect"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRoutingRuleMatches(t *testing.T) {
+	header := http.Header{}
+	header.Set("X-Team", "Research")
+	groupID := int64(5)
+	key := &APIKey{Tags: []string{"pro"}, GroupID: &groupID}
+	req := &RoutingRequest{
+		Model:       "claude-sonnet-4-5",
+		ClientType:  clientdetect.TypeClaudeCode,
+		Header:      header,
+		APIKey:      key,
+		RequestSize: 300_000,
+	}
+
+	cases := []struct {
+		name string
+		rule RoutingRule
+		want bool
+	}{
+		{"no conditions", RoutingRule{Enabled: true}, true},
+		{"disabled", RoutingRule{}, false},
+		{"model wildcard", RoutingRule{Enabled: true, Models: []string{"claude-sonnet-*"}}, true},
+		{"model mismatch", RoutingRule{Enabled: true, Models: []string{"gpt-*"}}, false},
+		{"client type", RoutingRule{Enabled: true, ClientTypes: []string{string(clientdetect.TypeClaudeCode)}}, true},
+		{"client type mismatch", RoutingRule{Enabled: true, ClientTypes: []string{string(clientdetect.TypeCodexCLI)}}, false},
+		{"header present", RoutingRule{Enabled: true, Headers: []RoutingRuleHeaderMatch{{Name: "X-Team"}}}, true},
+		{"header value wildcard", RoutingRule{Enabled: true, Headers: []RoutingRuleHeaderMatch{{Name: "X-Team", Value: "res*"}}}, true},
+		{"header missing", RoutingRule{Enabled: true, Headers: []RoutingRuleHeaderMatch{{Name: "X-Other"}}}, false},
+		{"key tag", RoutingRule{Enabled: true, KeyTags: []string{"free", "pro"}}, true},
+		{"key tag mismatch", RoutingRule{Enabled: true, KeyTags: []string{"free"}}, false},
+		{"source group", RoutingRule{Enabled: true, SourceGroupIDs: []int64{4, 5}}, true},
+		{"source group mismatch", RoutingRule{Enabled: true, SourceGroupIDs: []int64{4}}, false},
+		{"big request", RoutingRule{Enabled: true, MinRequestBytes: 200_000}, true},
+		{"small request only", RoutingRule{Enabled: true, MaxRequestBytes: 100_000}, false},
+		{"all conditions", RoutingRule{Enabled: true, Models: []string{"claude-*"}, KeyTags: []string{"pro"}, MinRequestBytes: 1}, true},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			require.Equal(t, tc.want, tc.rule.Matches(req))
+		})
+	}
+}
+
+func TestSetRoutingRuleSettings_NormalizesAndOrdersByPriority(t *testing.T) {
+	repo := newRuntimeSettingRepoStub()
+	svc := NewSettingService(repo, &config.Config{})
+	ctx := context.Background()
+	t.Cleanup(func() { routingRuleCache.Store(&cachedRoutingRules{}) })
+
+	invalid := []RoutingRule{
+		{Name: "", TargetGroupID: 1},
+		{Name: "no target"},
+		{Name: "bad model", TargetGroupID: 1, Models: []string{"claude-*-opus"}},
+		{Name: "bad client", TargetGroupID: 1, ClientTypes: []string{"not_a_client"}},
+		{Name: "bad header", TargetGroupID: 1, Headers: []RoutingRuleHeaderMatch{{Name: " "}}},
+		{Name: "bad tag", TargetGroupID: 1, KeyTags: []string{"has space"}},
+		{Name: "bad source group", TargetGroupID: 1, SourceGroupIDs: []int64{0}},
+		{Name: "bad size", TargetGroupID: 1, MinRequestBytes: 10, MaxRequestBytes: 5},
+	}
+	for _, rule := range invalid {
+		err := svc.SetRoutingRuleSettings(ctx, &RoutingRuleSettings{Enabled: true, Rules: []RoutingRule{rule}})
+		require.Error(t, err, rule.Name)
+	}
+
+	err := svc.SetRoutingRuleSettings(ctx, &RoutingRuleSettings{
+		Enabled: true,
+		Rules: []RoutingRule{
+			{Name: "fallback", Enabled: true, Priority: 10, TargetGroupID: 3, SourceGroupIDs: []int64{7, 1, 7}},
+			{Name: " big context ", Enabled: true, Priority: 1, MinRequestBytes: 200_000, KeyTags: []string{" PRO "}, TargetGroupID: 2,
+				Headers: []RoutingRuleHeaderMatch{{Name: "x-team", Value: " Research "}}},
+		},
+	})
+	require.NoError(t, err)
+
+	got, err := svc.GetRoutingRuleSettings(ctx)
+	require.NoError(t, err)
+	require.Len(t, got.Rules, 2)
+	require.Equal(t, "big context", got.Rules[0].Name)
+	require.Equal(t, []string{"pro"}, got.Rules[0].KeyTags)
+	require.Equal(t, []RoutingRuleHeaderMatch{{Name: "X-Team", Value: "research"}}, got.Rules[0].Headers)
+	require.Equal(t, "fallback", got.Rules[1].Name)
+	require.Equal(t, []int64{1, 7}, got.Rules[1].SourceGroupIDs)
+
+	// 写入后立即刷新本实例缓存，按优先级首条命中
+	rules := svc.GetRoutingRules(ctx)
+	big := MatchRoutingRule(rules, &RoutingRequest{APIKey: &APIKey{Tags: []string{"pro"}}, Header: http.Header{"X-Team": {"research"}}, RequestSize: 250_000})
+	require.NotNil(t, big)
+	require.Equal(t, int64(2), big.TargetGroupID)
+	sourceGroupID := int64(7)
+	small := MatchRoutingRule(rules, &RoutingRequest{APIKey: &APIKey{Tags: []string{"pro"}, GroupID: &sourceGroupID}, RequestSize: 1_000})
+	require.NotNil(t, small)
+	require.Equal(t, int64(3), small.TargetGroupID)
+	// 不在来源分组内的 Key 不受该规则影响
+	require.Nil(t, MatchRoutingRule(rules, &RoutingRequest{APIKey: &APIKey{Tags: []string{"pro"}}, RequestSize: 1_000}))
+
+	rules.Enabled = false
+	require.Nil(t, MatchRoutingRule(rules, &RoutingRequest{}))
+}
+
+func TestCanRouteToGroup(t *testing.T) {
+	tenant := int64(7)
+	from := &Group{ID: 1, Platform: PlatformAnthropic, Status: StatusActive, SubscriptionType: SubscriptionTypeStandard}
+
+	require.True(t, CanRouteToGroup(from, &Group{ID: 2, Platform: PlatformAnthropic, Status: StatusActive, SubscriptionType: SubscriptionTypeStandard}))
+	require.False(t, CanRouteToGroup(from, &Group{ID: 2, Platform: PlatformOpenAI, Status: StatusActive}), "platform must match")
+	require.False(t, CanRouteToGroup(from, &Group{ID: 2, Platform: PlatformAnthropic, Status: "inactive"}), "target must be active")
+	require.False(t, CanRouteToGroup(from, &Group{ID: 2, Platform: PlatformAnthropic, Status: StatusActive, SubscriptionType: SubscriptionTypeSubscription}))
+	require.False(t, CanRouteToGroup(from, &Group{ID: 2, Platform: PlatformAnthropic, Status: StatusActive, TenantID: &tenant}), "tenant must match")
+	require.False(t, CanRouteToGroup(&Group{Platform: PlatformAnthropic, SubscriptionType: SubscriptionTypeSubscription}, &Group{Platform: PlatformAnthropic, Status: StatusActive}))
+}
+
+func TestCanRouteAcrossPlatforms(t *testing.T) {
+	from := &Group{ID: 1, Platform: PlatformOpenAI, Status: StatusActive, SubscriptionType: SubscriptionTypeStandard}
+
+	require.True(t, CanRouteAcrossPlatforms(from, &Group{ID: 2, Platform: PlatformAnthropic, Status: StatusActive, SubscriptionType: SubscriptionTypeStandard}))
+	require.True(t, CanRouteAcrossPlatforms(from, &Group{ID: 2, Platform: PlatformOpenAI, Status: StatusActive}))
+	require.False(t, CanRouteAcrossPlatforms(from, &Group{ID: 2, Platform: PlatformSora, Status: StatusActive}), "target platform must support format conversion")
+	require.False(t, CanRouteAcrossPlatforms(from, &Group{ID: 2, Platform: PlatformAnthropic, Status: "inactive"}))
+	require.False(t, CanRouteAcrossPlatforms(from, &Group{ID: 2, Platform: PlatformAnthropic, Status: StatusActive, SubscriptionType: SubscriptionTypeSubscription}))
+}
+
+func TestNormalizeAPIKeyTags(t *testing.T) {
+	tags, err := normalizeAPIKeyTags([]string{" Pro ", "pro", "team:research", ""})
+	require.NoError(t, err)
+	require.Equal(t, []string{"pro", "team:research"}, tags)
+
+	_, err = normalizeAPIKeyTags([]string{"no spaces"})
+	require.ErrorIs(t, err, ErrInvalidAPIKeyTags)
+
+	tags, err = normalizeAPIKeyTags([]string{" "})
+	require.NoError(t, err)
+	require.Nil(t, tags)
+}
@@ -0,0 +1,332 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/ShaohongDong/sub2api/internal/config"
+	infraerrors "github.com/ShaohongDong/sub2api/internal/pkg/errors"
+	"github.com/ShaohongDong/sub2api/internal/pkg/logger"
+)
+
+const (
+	// runtimeSettingsRefreshInterval 定期从数据库重新加载运行时设置，使其他实例的修改在该间隔内生效
+	runtimeSettingsRefreshInterval = 30 * time.Second
+	runtimeSettingsDBTimeout       = 5 * time.Second
+
+	maxRuntimeTimeoutSeconds   = 86400
+	maxRuntimeKeepaliveSeconds = 300
+	maxRuntimeRetryAttempts    = 10
+	maxRuntimeRetryDelayMS     = 60000
+)
+
+// 运行时设置来源
+const (
+	RuntimeSettingsSourceBaseline = "baseline"        // 未设置覆盖，取配置文件 / 环境变量
+	RuntimeSettingsSourceRuntime  = "runtime_setting" // 管理接口设置并持久化在数据库
+)
+
+// RuntimeRetryPolicy 上游重试策略中可在运行时调整的部分；可重试状态码、429 处理与重试预算沿用 gateway.upstream_retry
+type RuntimeRetryPolicy struct {
+	Enabled     bool `json:"enabled"`
+	MaxAttempts int  `json:"max_attempts"`
+	BaseDelayMS int  `json:"base_delay_ms"`
+	MaxDelayMS  int  `json:"max_delay_ms"`
+}
+
+// RuntimeSettings 无需重启即可生效的网关参数
+type RuntimeSettings struct {
+	// DefaultTTFBTimeoutSeconds / DefaultTotalTimeoutSeconds 未匹配 gateway.route_timeouts 的网关 POST 请求的
+	// 首字节超时与总超时（秒，0 表示不限制）
+	DefaultTTFBTimeoutSeconds  int `json:"default_ttfb_timeout_seconds"`
+	DefaultTotalTimeoutSeconds int `json:"default_total_timeout_seconds"`
+	// StreamKeepaliveIntervalSeconds 流式响应心跳间隔（秒，0 表示禁用），覆盖 gateway.stream_keepalive_interval
+	StreamKeepaliveIntervalSeconds int                `json:"stream_keepalive_interval_seconds"`
+	UpstreamRetry                  RuntimeRetryPolicy `json:"upstream_retry"`
+	// LogLevel 与运维日志配置（ops runtime logging）共用同一份持久化配置
+	LogLevel string `json:"log_level"`
+	// DegradedModeEnabled 降级模式总开关：关闭后所有 Key 的 degraded_fallback_model 均不生效
+	DegradedModeEnabled bool `json:"degraded_mode_enabled"`
+
+	Source          string `json:"source"`
+	UpdatedAt       string `json:"updated_at,omitempty"`
+	UpdatedByUserID int64  `json:"updated_by_user_id,omitempty"`
+}
+
+// activeRuntimeSettings 当前生效的运行时设置，网关热路径无锁读取；nil 表示尚未加载（按配置文件执行）
+var activeRuntimeSettings atomic.Pointer[RuntimeSettings]
+
+// CurrentRuntimeSettings 返回当前生效的运行时设置，尚未加载时返回 nil
+func CurrentRuntimeSettings() *RuntimeSettings {
+	return activeRuntimeSettings.Load()
+}
+
+// EffectiveUpstreamRetry 以运行时设置覆盖配置文件中的上游重试策略
+func EffectiveUpstreamRetry(base config.GatewayUpstreamRetryConfig) config.GatewayUpstreamRetryConfig {
+	if rs := CurrentRuntimeSettings(); rs != nil {
+		base.Enabled = rs.UpstreamRetry.Enabled
+		base.MaxAttempts = rs.UpstreamRetry.MaxAttempts
+		base.BaseDelayMS = rs.UpstreamRetry.BaseDelayMS
+		base.MaxDelayMS = rs.UpstreamRetry.MaxDelayMS
+	}
+	return base
+}
+
+// DegradedModeEnabled 降级模式是否启用（默认启用）
+func DegradedModeEnabled() bool {
+	rs := CurrentRuntimeSettings()
+	return rs == nil || rs.DegradedModeEnabled
+}
+
+// RuntimeDefaultRouteTimeout 返回未匹配路由超时规则的网关请求的默认超时，未设置时返回 nil
+func RuntimeDefaultRouteTimeout() *config.GatewayRouteTimeoutConfig {
+	rs := CurrentRuntimeSettings()
+	if rs == nil || (rs.DefaultTTFBTimeoutSeconds <= 0 && rs.DefaultTotalTimeoutSeconds <= 0) {
+		return nil
+	}
+	return &config.GatewayRouteTimeoutConfig{
+		TTFBTimeoutSeconds:  rs.DefaultTTFBTimeoutSeconds,
+		TotalTimeoutSeconds: rs.DefaultTotalTimeoutSeconds,
+	}
+}
+
+func defaultRuntimeSettings(cfg *config.Config) *RuntimeSettings {
+	out := &RuntimeSettings{
+		UpstreamRetry:       RuntimeRetryPolicy{MaxAttempts: 3, BaseDelayMS: 200, MaxDelayMS: 2000},
+		LogLevel:            "info",
+		DegradedModeEnabled: true,
+		Source:              RuntimeSettingsSourceBaseline,
+	}
+	if cfg == nil {
+		return out
+	}
+	out.StreamKeepaliveIntervalSeconds = max(cfg.Gateway.StreamKeepaliveInterval, 0)
+	retry := cfg.Gateway.UpstreamRetry
+	out.UpstreamRetry.Enabled = retry.Enabled
+	if retry.MaxAttempts > 0 {
+		out.UpstreamRetry.MaxAttempts = retry.MaxAttempts
+	}
+	if retry.BaseDelayMS > 0 {
+		out.UpstreamRetry.BaseDelayMS = retry.BaseDelayMS
+	}
+	if retry.MaxDelayMS > 0 {
+		out.UpstreamRetry.MaxDelayMS = retry.MaxDelayMS
+	}
+	if level := strings.ToLower(strings.TrimSpace(cfg.Log.Level)); level != "" {
+		out.LogLevel = level
+	}
+	return out
+}
+
+func validateRuntimeSettings(rs *RuntimeSettings) error {
+	rs.LogLevel = strings.ToLower(strings.TrimSpace(rs.LogLevel))
+	switch rs.LogLevel {
+	case "debug", "info", "warn", "error":
+	default:
+		return errors.New("log_level must be one of: debug/info/warn/error")
+	}
+	if rs.DefaultTTFBTimeoutSeconds < 0 || rs.DefaultTTFBTimeoutSeconds > maxRuntimeTimeoutSeconds {
+		return fmt.Errorf("default_ttfb_timeout_seconds must be between 0 and %d", maxRuntimeTimeoutSeconds)
+	}
+	if rs.DefaultTotalTimeoutSeconds < 0 || rs.DefaultTotalTimeoutSeconds > maxRuntimeTimeoutSeconds {
+		return fmt.Errorf("default_total_timeout_seconds must be between 0 and %d", maxRuntimeTimeoutSeconds)
+	}
+	if rs.StreamKeepaliveIntervalSeconds < 0 || rs.StreamKeepaliveIntervalSeconds > maxRuntimeKeepaliveSeconds {
+		return fmt.Errorf("stream_keepalive_interval_seconds must be between 0 and %d", maxRuntimeKeepaliveSeconds)
+	}
+	retry := rs.UpstreamRetry
+	if retry.MaxAttempts < 1 || retry.MaxAttempts > maxRuntimeRetryAttempts {
+		return fmt.Errorf("upstream_retry.max_attempts must be between 1 and %d", maxRuntimeRetryAttempts)
+	}
+	if retry.BaseDelayMS <= 0 {
+		return errors.New("upstream_retry.base_delay_ms must be positive")
+	}
+	if retry.MaxDelayMS < retry.BaseDelayMS || retry.MaxDelayMS > maxRuntimeRetryDelayMS {
+		return fmt.Errorf("upstream_retry.max_delay_ms must be between base_delay_ms and %d", maxRuntimeRetryDelayMS)
+	}
+	return nil
+}
+
+// RuntimeSettingsService 管理运行时设置：持久化在 settings 表，修改后立即在本实例生效，
+// 其他实例通过定期重新加载在 runtimeSettingsRefreshInterval 内生效
+type RuntimeSettingsService struct {
+	settingRepo SettingRepository
+	opsService  *OpsService
+	cfg         *config.Config
+
+	mu       sync.Mutex // 串行化修改
+	stopCh   chan struct{}
+	stopOnce sync.Once
+	wg       sync.WaitGroup
+}
+
+// NewRuntimeSettingsService 创建运行时设置服务
+func NewRuntimeSettingsService(settingRepo SettingRepository, opsService *OpsService, cfg *config.Config) *RuntimeSettingsService {
+	return &RuntimeSettingsService{
+		settingRepo: settingRepo,
+		opsService:  opsService,
+		cfg:         cfg,
+		stopCh:      make(chan struct{}),
+	}
+}
+
+// Start 加载并应用已保存的运行时设置，随后定期重新加载
+func (s *RuntimeSettingsService) Start() {
+	s.refresh()
+	s.wg.Add(1)
+	go func() {
+		defer s.wg.Done()
+		ticker := time.NewTicker(runtimeSettingsRefreshInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				s.refresh()
+			case <-s.stopCh:
+				return
+			}
+		}
+	}()
+}
+
+// Stop 停止定期加载
+func (s *RuntimeSettingsService) Stop() {
+	s.stopOnce.Do(func() { close(s.stopCh) })
+	s.wg.Wait()
+}
+
+func (s *RuntimeSettingsService) refresh() {
+	ctx, cancel := context.WithTimeout(context.Background(), runtimeSettingsDBTimeout)
+	defer cancel()
+	rs, err := s.load(ctx)
+	if err != nil {
+		slog.Warn("runtime_settings.refresh_failed", "error", err)
+		return
+	}
+	activeRuntimeSettings.Store(rs)
+}
+
+// Get 返回当前运行时设置（未设置覆盖时为配置文件基线）
+func (s *RuntimeSettingsService) Get(ctx context.Context) (*RuntimeSettings, error) {
+	rs, err := s.load(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if level, err := s.currentLogLevel(ctx); err == nil && level != "" {
+		rs.LogLevel = level
+	}
+	return rs, nil
+}
+
+func (s *RuntimeSettingsService) load(ctx context.Context) (*RuntimeSettings, error) {
+	baseline := defaultRuntimeSettings(s.cfg)
+	raw, err := s.settingRepo.GetValue(ctx, SettingKeyRuntimeSettings)
+	if err != nil {
+		if errors.Is(err, ErrSettingNotFound) {
+			return baseline, nil
+		}
+		return nil, fmt.Errorf("get runtime settings: %w", err)
+	}
+	rs := *baseline
+	if err := json.Unmarshal([]byte(raw), &rs); err != nil {
+		return baseline, nil
+	}
+	// 日志级别不在此处持久化，始终以配置文件基线或运维日志配置为准
+	rs.LogLevel = baseline.LogLevel
+	if err := validateRuntimeSettings(&rs); err != nil {
+		slog.Warn("runtime_settings.invalid_stored_value", "error", err)
+		return baseline, nil
+	}
+	return &rs, nil
+}
+
+// Update 校验并保存运行时设置，立即在本实例生效
+func (s *RuntimeSettingsService) Update(ctx context.Context, req *RuntimeSettings, operatorID int64) (*RuntimeSettings, error) {
+	if req == nil {
+		return nil, infraerrors.BadRequest("RUNTIME_SETTINGS_INVALID", "invalid runtime settings")
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	next := *req
+	if err := validateRuntimeSettings(&next); err != nil {
+		return nil, infraerrors.BadRequest("RUNTIME_SETTINGS_INVALID", err.Error())
+	}
+	if err := s.applyLogLevel(ctx, next.LogLevel, operatorID); err != nil {
+		return nil, err
+	}
+
+	next.Source = RuntimeSettingsSourceRuntime
+	next.UpdatedAt = time.Now().UTC().Format(time.RFC3339Nano)
+	next.UpdatedByUserID = operatorID
+	stored := next
+	stored.LogLevel = ""
+	data, err := json.Marshal(&stored)
+	if err != nil {
+		return nil, fmt.Errorf("marshal runtime settings: %w", err)
+	}
+	if err := s.settingRepo.Set(ctx, SettingKeyRuntimeSettings, string(data)); err != nil {
+		return nil, fmt.Errorf("save runtime settings: %w", err)
+	}
+	activeRuntimeSettings.Store(&next)
+	slog.Info("runtime_settings.updated", "operator_id", operatorID)
+	return &next, nil
+}
+
+// Reset 删除运行时覆盖，恢复配置文件基线（含日志级别）
+func (s *RuntimeSettingsService) Reset(ctx context.Context, operatorID int64) (*RuntimeSettings, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	baseline := defaultRuntimeSettings(s.cfg)
+	if err := s.settingRepo.Delete(ctx, SettingKeyRuntimeSettings); err != nil && !errors.Is(err, ErrSettingNotFound) {
+		return nil, fmt.Errorf("delete runtime settings: %w", err)
+	}
+	if err := s.applyLogLevel(ctx, baseline.LogLevel, operatorID); err != nil {
+		return nil, err
+	}
+	activeRuntimeSettings.Store(baseline)
+	slog.Info("runtime_settings.reset", "operator_id", operatorID)
+	return baseline, nil
+}
+
+func (s *RuntimeSettingsService) currentLogLevel(ctx context.Context) (string, error) {
+	if s.opsService == nil {
+		return logger.CurrentLevel(), nil
+	}
+	logCfg, err := s.opsService.GetRuntimeLogConfig(ctx)
+	if err != nil {
+		return "", err
+	}
+	return logCfg.Level, nil
+}
+
+// applyLogLevel 修改日志级别：写入运维日志配置（保持两处设置一致），未启用运维服务时直接调整进程日志级别
+func (s *RuntimeSettingsService) applyLogLevel(ctx context.Context, level string, operatorID int64) error {
+	current, err := s.currentLogLevel(ctx)
+	if err != nil {
+		return fmt.Errorf("get log level: %w", err)
+	}
+	if current == level {
+		return nil
+	}
+	if s.opsService == nil {
+		return logger.SetLevel(level)
+	}
+	logCfg, err := s.opsService.GetRuntimeLogConfig(ctx)
+	if err != nil {
+		return fmt.Errorf("get log level: %w", err)
+	}
+	logCfg.Level = level
+	if _, err := s.opsService.UpdateRuntimeLogConfig(ctx, logCfg, operatorID); err != nil {
+		return infraerrors.BadRequest("RUNTIME_SETTINGS_INVALID", err.Error())
+	}
+	return nil
+}
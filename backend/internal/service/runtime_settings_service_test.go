@@ -0,0 +1,131 @@
+//go:build unit
+
+package service
+
+import (
+	"context"
+	"testing"
+
+	"github.com/ShaohongDong/sub2api/internal/config"
+	infraerrors "github.com/ShaohongDong/sub2api/internal/pkg/errors"
+	"github.com/stretchr/testify/require"
+)
+
+type runtimeSettingsRepoStub struct {
+	values map[string]string
+}
+
+func (s *runtimeSettingsRepoStub) Get(ctx context.Context, key string) (*Setting, error) {
+	panic("unexpected Get call")
+}
+
+func (s *runtimeSettingsRepoStub) GetValue(ctx context.Context, key string) (string, error) {
+	v, ok := s.values[key]
+	if !ok {
+		return "", ErrSettingNotFound
+	}
+	return v, nil
+}
+
+func (s *runtimeSettingsRepoStub) Set(ctx context.Context, key, value string) error {
+	s.values[key] = value
+	return nil
+}
+
+func (s *runtimeSettingsRepoStub) GetMultiple(ctx context.Context, keys []string) (map[string]string, error) {
+	panic("unexpected GetMultiple call")
+}
+
+func (s *runtimeSettingsRepoStub) SetMultiple(ctx context.Context, settings map[string]string) error {
+	panic("unexpected SetMultiple call")
+}
+
+func (s *runtimeSettingsRepoStub) GetAll(ctx context.Context) (map[string]string, error) {
+	panic("unexpected GetAll call")
+}
+
+func (s *runtimeSettingsRepoStub) Delete(ctx context.Context, key string) error {
+	delete(s.values, key)
+	return nil
+}
+
+func newRuntimeSettingsServiceForTest(t *testing.T) (*RuntimeSettingsService, *runtimeSettingsRepoStub) {
+	t.Helper()
+	prev := activeRuntimeSettings.Load()
+	t.Cleanup(func() { activeRuntimeSettings.Store(prev) })
+	activeRuntimeSettings.Store(nil)
+
+	cfg := &config.Config{}
+	cfg.Gateway.StreamKeepaliveInterval = 10
+	cfg.Gateway.UpstreamRetry = config.GatewayUpstreamRetryConfig{MaxAttempts: 2, BaseDelayMS: 100, MaxDelayMS: 1000}
+	repo := &runtimeSettingsRepoStub{values: map[string]string{}}
+	return NewRuntimeSettingsService(repo, nil, cfg), repo
+}
+
+func TestRuntimeSettingsService_UpdateAppliesImmediately(t *testing.T) {
+	svc, repo := newRuntimeSettingsServiceForTest(t)
+	require.True(t, DegradedModeEnabled())
+	require.Nil(t, RuntimeDefaultRouteTimeout())
+
+	updated, err := svc.Update(context.Background(), &RuntimeSettings{
+		DefaultTTFBTimeoutSeconds:      30,
+		DefaultTotalTimeoutSeconds:     600,
+		StreamKeepaliveIntervalSeconds: 15,
+		UpstreamRetry:                  RuntimeRetryPolicy{Enabled: true, MaxAttempts: 4, BaseDelayMS: 50, MaxDelayMS: 500},
+		LogLevel:                       "INFO",
+		DegradedModeEnabled:            false,
+	}, 7)
+	require.NoError(t, err)
+	require.Equal(t, RuntimeSettingsSourceRuntime, updated.Source)
+	require.Equal(t, int64(7), updated.UpdatedByUserID)
+	require.Equal(t, "info", updated.LogLevel)
+	require.NotContains(t, repo.values[SettingKeyRuntimeSettings], "log_level\":\"info")
+
+	require.False(t, DegradedModeEnabled())
+	require.Equal(t, &config.GatewayRouteTimeoutConfig{TTFBTimeoutSeconds: 30, TotalTimeoutSeconds: 600}, RuntimeDefaultRouteTimeout())
+	retry := EffectiveUpstreamRetry(config.GatewayUpstreamRetryConfig{RetryStatusCodes: []int{502}})
+	require.True(t, retry.Enabled)
+	require.Equal(t, 4, retry.MaxAttempts)
+	require.Equal(t, []int{502}, retry.RetryStatusCodes)
+
+	// 其他实例重新加载后得到相同设置
+	activeRuntimeSettings.Store(nil)
+	svc.refresh()
+	require.False(t, DegradedModeEnabled())
+	require.Equal(t, 15, CurrentRuntimeSettings().StreamKeepaliveIntervalSeconds)
+}
+
+func TestRuntimeSettingsService_UpdateRejectsInvalid(t *testing.T) {
+	svc, repo := newRuntimeSettingsServiceForTest(t)
+
+	for _, req := range []*RuntimeSettings{
+		{LogLevel: "verbose", UpstreamRetry: RuntimeRetryPolicy{MaxAttempts: 1, BaseDelayMS: 1, MaxDelayMS: 1}},
+		{LogLevel: "info", DefaultTTFBTimeoutSeconds: -1, UpstreamRetry: RuntimeRetryPolicy{MaxAttempts: 1, BaseDelayMS: 1, MaxDelayMS: 1}},
+		{LogLevel: "info", UpstreamRetry: RuntimeRetryPolicy{MaxAttempts: 0, BaseDelayMS: 1, MaxDelayMS: 1}},
+		{LogLevel: "info", UpstreamRetry: RuntimeRetryPolicy{MaxAttempts: 1, BaseDelayMS: 500, MaxDelayMS: 100}},
+	} {
+		_, err := svc.Update(context.Background(), req, 1)
+		require.Equal(t, "RUNTIME_SETTINGS_INVALID", infraerrors.Reason(err))
+	}
+	require.Empty(t, repo.values)
+	require.Nil(t, CurrentRuntimeSettings())
+}
+
+func TestRuntimeSettingsService_ResetRestoresBaseline(t *testing.T) {
+	svc, repo := newRuntimeSettingsServiceForTest(t)
+
+	_, err := svc.Update(context.Background(), &RuntimeSettings{
+		LogLevel:      "info",
+		UpstreamRetry: RuntimeRetryPolicy{Enabled: true, MaxAttempts: 5, BaseDelayMS: 10, MaxDelayMS: 10},
+	}, 1)
+	require.NoError(t, err)
+
+	reset, err := svc.Reset(context.Background(), 1)
+	require.NoError(t, err)
+	require.Empty(t, repo.values)
+	require.Equal(t, RuntimeSettingsSourceBaseline, reset.Source)
+	require.Equal(t, 10, reset.StreamKeepaliveIntervalSeconds)
+	require.Equal(t, RuntimeRetryPolicy{MaxAttempts: 2, BaseDelayMS: 100, MaxDelayMS: 1000}, reset.UpstreamRetry)
+	require.True(t, DegradedModeEnabled())
+	require.False(t, EffectiveUpstreamRetry(config.GatewayUpstreamRetryConfig{Enabled: true}).Enabled)
+}
@@ -15,6 +15,7 @@ import (
 	"time"
 
 	"github.com/ShaohongDong/sub2api/internal/config"
+	"github.com/ShaohongDong/sub2api/internal/pkg/clientdetect"
 	infraerrors "github.com/ShaohongDong/sub2api/internal/pkg/errors"
 	"golang.org/x/sync/singleflight"
 )
@@ -44,10 +45,11 @@ type SettingRepository interface {
 	Delete(ctx context.Context, key string) error
 }
 
-// cachedVersionBounds 缓存 Claude Code 版本号上下限（进程内缓存，60s TTL）
+// cachedVersionBounds 缓存客户端版本号要求（Claude Code 上下限 + Codex CLI 下限，进程内缓存，60s TTL）
 type cachedVersionBounds struct {
 	min       string // 空字符串 = 不检查
 	max       string // 空字符串 = 不检查
+	codexMin  string // Codex CLI 最低版本，空字符串 = 不检查
 	expiresAt int64  // unix nano
 }
 
@@ -173,6 +175,8 @@ func (s *SettingService) GetPublicSettings(ctx context.Context) (*PublicSettings
 		SoraClientEnabled:                settings[SettingKeySoraClientEnabled] == "true",
 		CustomMenuItems:                  settings[SettingKeyCustomMenuItems],
 		LinuxDoOAuthEnabled:              linuxDoEnabled,
+		OIDCEnabled:                      s.cfg != nil && s.cfg.OIDC.Enabled,
+		OIDCProviderName:                 oidcProviderName(s.cfg),
 	}, nil
 }
 
@@ -224,6 +228,8 @@ func (s *SettingService) GetPublicSettingsForInjection(ctx context.Context) (any
 		SoraClientEnabled                bool            `json:"sora_client_enabled"`
 		CustomMenuItems                  json.RawMessage `json:"custom_menu_items"`
 		LinuxDoOAuthEnabled              bool            `json:"linuxdo_oauth_enabled"`
+		OIDCEnabled                      bool            `json:"oidc_enabled"`
+		OIDCProviderName                 string          `json:"oidc_provider_name,omitempty"`
 		Version                          string          `json:"version,omitempty"`
 	}{
 		RegistrationEnabled:              settings.RegistrationEnabled,
@@ -248,10 +254,19 @@ func (s *SettingService) GetPublicSettingsForInjection(ctx context.Context) (any
 		SoraClientEnabled:                settings.SoraClientEnabled,
 		CustomMenuItems:                  filterUserVisibleMenuItems(settings.CustomMenuItems),
 		LinuxDoOAuthEnabled:              settings.LinuxDoOAuthEnabled,
+		OIDCEnabled:                      settings.OIDCEnabled,
+		OIDCProviderName:                 settings.OIDCProviderName,
 		Version:                          s.version,
 	}, nil
 }
 
+func oidcProviderName(cfg *config.Config) string {
+	if cfg == nil || !cfg.OIDC.Enabled {
+		return ""
+	}
+	return cfg.OIDC.ProviderName
+}
+
 // filterUserVisibleMenuItems filters out admin-only menu items from a raw JSON
 // array string, returning only items with visibility != "admin".
 func filterUserVisibleMenuItems(raw string) json.RawMessage {
@@ -460,6 +475,9 @@ func (s *SettingService) UpdateSettings(ctx context.Context, settings *SystemSet
 	updates[SettingKeyMinClaudeCodeVersion] = settings.MinClaudeCodeVersion
 	updates[SettingKeyMaxClaudeCodeVersion] = settings.MaxClaudeCodeVersion
 
+	// Codex CLI version check
+	updates[SettingKeyMinCodexCLIVersion] = settings.MinCodexCLIVersion
+
 	// 分组隔离
 	updates[SettingKeyAllowUngroupedKeyScheduling] = strconv.FormatBool(settings.AllowUngroupedKeyScheduling)
 
@@ -470,6 +488,7 @@ func (s *SettingService) UpdateSettings(ctx context.Context, settings *SystemSet
 		versionBoundsCache.Store(&cachedVersionBounds{
 			min:       settings.MinClaudeCodeVersion,
 			max:       settings.MaxClaudeCodeVersion,
+			codexMin:  settings.MinCodexCLIVersion,
 			expiresAt: time.Now().Add(versionBoundsCacheTTL).UnixNano(),
 		})
 		if s.onUpdate != nil {
@@ -684,6 +703,9 @@ func (s *SettingService) InitializeDefaultSettings(ctx context.Context) error {
 		SettingKeyMinClaudeCodeVersion: "",
 		SettingKeyMaxClaudeCodeVersion: "",
 
+		// Codex CLI version check (default: empty = disabled)
+		SettingKeyMinCodexCLIVersion: "",
+
 		// 分组隔离（默认不允许未分组 Key 调度）
 		SettingKeyAllowUngroupedKeyScheduling: "false",
 	}
@@ -818,6 +840,9 @@ func (s *SettingService) parseSettings(settings map[string]string) *SystemSettin
 	result.MinClaudeCodeVersion = settings[SettingKeyMinClaudeCodeVersion]
 	result.MaxClaudeCodeVersion = settings[SettingKeyMaxClaudeCodeVersion]
 
+	// Codex CLI version check
+	result.MinCodexCLIVersion = settings[SettingKeyMinCodexCLIVersion]
+
 	// 分组隔离
 	result.AllowUngroupedKeyScheduling = settings[SettingKeyAllowUngroupedKeyScheduling] == "true"
 
@@ -1157,18 +1182,29 @@ func (s *SettingService) IsUngroupedKeySchedulingAllowed(ctx context.Context) bo
 // singleflight 防止缓存过期时 thundering herd
 // 返回空字符串表示不做对应方向的版本检查
 func (s *SettingService) GetClaudeCodeVersionBounds(ctx context.Context) (min, max string) {
+	b := s.loadVersionBounds(ctx)
+	return b.min, b.max
+}
+
+// GetMinCodexCLIVersion 获取 Codex CLI 最低版本号要求（与 Claude Code 版本上下限共享缓存）
+// 返回空字符串表示不检查
+func (s *SettingService) GetMinCodexCLIVersion(ctx context.Context) string {
+	return s.loadVersionBounds(ctx).codexMin
+}
+
+// loadVersionBounds 读取（必要时刷新）客户端版本号要求缓存
+func (s *SettingService) loadVersionBounds(ctx context.Context) cachedVersionBounds {
 	if cached, ok := versionBoundsCache.Load().(*cachedVersionBounds); ok {
 		if time.Now().UnixNano() < cached.expiresAt {
-			return cached.min, cached.max
+			return *cached
 		}
 	}
 	// singleflight: 同一时刻只有一个 goroutine 查询 DB，其余复用结果
-	type bounds struct{ min, max string }
 	result, err, _ := versionBoundsSF.Do("version_bounds", func() (any, error) {
 		// 二次检查，避免排队的 goroutine 重复查询
 		if cached, ok := versionBoundsCache.Load().(*cachedVersionBounds); ok {
 			if time.Now().UnixNano() < cached.expiresAt {
-				return bounds{cached.min, cached.max}, nil
+				return *cached, nil
 			}
 		}
 		// 使用独立 context：断开请求取消链，避免客户端断连导致空值被长期缓存
@@ -1177,36 +1213,33 @@ func (s *SettingService) GetClaudeCodeVersionBounds(ctx context.Context) (min, m
 		values, err := s.settingRepo.GetMultiple(dbCtx, []string{
 			SettingKeyMinClaudeCodeVersion,
 			SettingKeyMaxClaudeCodeVersion,
+			SettingKeyMinCodexCLIVersion,
 		})
 		if err != nil {
 			// fail-open: DB 错误时不阻塞请求，但记录日志并使用短 TTL 快速重试
-			slog.Warn("failed to get claude code version bounds setting, skipping version check", "error", err)
+			slog.Warn("failed to get client version bounds setting, skipping version check", "error", err)
 			versionBoundsCache.Store(&cachedVersionBounds{
-				min:       "",
-				max:       "",
 				expiresAt: time.Now().Add(versionBoundsErrorTTL).UnixNano(),
 			})
-			return bounds{"", ""}, nil
-		}
-		b := bounds{
-			min: values[SettingKeyMinClaudeCodeVersion],
-			max: values[SettingKeyMaxClaudeCodeVersion],
+			return cachedVersionBounds{}, nil
 		}
-		versionBoundsCache.Store(&cachedVersionBounds{
-			min:       b.min,
-			max:       b.max,
+		b := cachedVersionBounds{
+			min:       values[SettingKeyMinClaudeCodeVersion],
+			max:       values[SettingKeyMaxClaudeCodeVersion],
+			codexMin:  values[SettingKeyMinCodexCLIVersion],
 			expiresAt: time.Now().Add(versionBoundsCacheTTL).UnixNano(),
-		})
+		}
+		versionBoundsCache.Store(&b)
 		return b, nil
 	})
 	if err != nil {
-		return "", ""
+		return cachedVersionBounds{}
 	}
-	b, ok := result.(bounds)
+	b, ok := result.(cachedVersionBounds)
 	if !ok {
-		return "", ""
+		return cachedVersionBounds{}
 	}
-	return b.min, b.max
+	return b
 }
 
 // GetRectifierSettings 获取请求整流器配置
@@ -1290,7 +1323,7 @@ func (s *SettingService) SetBetaPolicySettings(ctx context.Context, settings *Be
 	}
 
 	validActions := map[string]bool{
-		BetaPolicyActionPass: true, BetaPolicyActionFilter: true, BetaPolicyActionBlock: true,
+		BetaPolicyActionPass: true, BetaPolicyActionFilter: true, BetaPolicyActionBlock: true, BetaPolicyActionInject: true,
 	}
 	validScopes := map[string]bool{
 		BetaPolicyScopeAll: true, BetaPolicyScopeOAuth: true, BetaPolicyScopeAPIKey: true,
@@ -1306,6 +1339,16 @@ func (s *SettingService) SetBetaPolicySettings(ctx context.Context, settings *Be
 		if !validScopes[rule.Scope] {
 			return fmt.Errorf("rule[%d]: invalid scope %q", i, rule.Scope)
 		}
+		models, err := normalizeAPIKeyAllowedModels(rule.Models)
+		if err != nil {
+			return fmt.Errorf("rule[%d]: invalid models (only a trailing * wildcard is supported)", i)
+		}
+		settings.Rules[i].Models = models
+		for _, id := range rule.AccountIDs {
+			if id <= 0 {
+				return fmt.Errorf("rule[%d]: invalid account id %d", i, id)
+			}
+		}
 	}
 
 	data, err := json.Marshal(settings)
@@ -1316,6 +1359,73 @@ func (s *SettingService) SetBetaPolicySettings(ctx context.Context, settings *Be
 	return s.settingRepo.Set(ctx, SettingKeyBetaPolicySettings, string(data))
 }
 
+// GetClientRoutingSettings 获取客户端路由策略配置
+func (s *SettingService) GetClientRoutingSettings(ctx context.Context) (*ClientRoutingSettings, error) {
+	value, err := s.settingRepo.GetValue(ctx, SettingKeyClientRoutingSettings)
+	if err != nil {
+		if errors.Is(err, ErrSettingNotFound) {
+			return DefaultClientRoutingSettings(), nil
+		}
+		return nil, fmt.Errorf("get client routing settings: %w", err)
+	}
+	if value == "" {
+		return DefaultClientRoutingSettings(), nil
+	}
+
+	var settings ClientRoutingSettings
+	if err := json.Unmarshal([]byte(value), &settings); err != nil {
+		return DefaultClientRoutingSettings(), nil
+	}
+	if settings.Rules == nil {
+		settings.Rules = []ClientRoutingRule{}
+	}
+
+	return &settings, nil
+}
+
+// SetClientRoutingSettings 设置客户端路由策略配置
+func (s *SettingService) SetClientRoutingSettings(ctx context.Context, settings *ClientRoutingSettings) error {
+	if settings == nil {
+		return fmt.Errorf("settings cannot be nil")
+	}
+
+	validAccountTypes := map[string]bool{
+		AccountTypeOAuth: true, AccountTypeSetupToken: true, AccountTypeAPIKey: true, AccountTypeUpstream: true,
+	}
+
+	for i := range settings.Rules {
+		rule := &settings.Rules[i]
+		rule.ClientTypes = normalizeClientRoutingValues(rule.ClientTypes)
+		rule.AccountTypes = normalizeClientRoutingValues(rule.AccountTypes)
+		if len(rule.ClientTypes) == 0 {
+			return fmt.Errorf("rule[%d]: client_types cannot be empty", i)
+		}
+		for _, ct := range rule.ClientTypes {
+			if ct != ClientRoutingMatchAll && !clientdetect.IsKnownType(ct) {
+				return fmt.Errorf("rule[%d]: unknown client type %q", i, ct)
+			}
+		}
+		if len(rule.AccountTypes) == 0 {
+			return fmt.Errorf("rule[%d]: account_types cannot be empty", i)
+		}
+		for _, at := range rule.AccountTypes {
+			if !validAccountTypes[at] {
+				return fmt.Errorf("rule[%d]: invalid account type %q", i, at)
+			}
+		}
+	}
+	if settings.Rules == nil {
+		settings.Rules = []ClientRoutingRule{}
+	}
+
+	data, err := json.Marshal(settings)
+	if err != nil {
+		return fmt.Errorf("marshal client routing settings: %w", err)
+	}
+
+	return s.settingRepo.Set(ctx, SettingKeyClientRoutingSettings, string(data))
+}
+
 // SetStreamTimeoutSettings 设置流超时处理配置
 func (s *SettingService) SetStreamTimeoutSettings(ctx context.Context, settings *StreamTimeoutSettings) error {
 	if settings == nil {
@@ -194,6 +194,20 @@ func TestSettingService_UpdateSettings_RegistrationEmailSuffixWhitelist_Invalid(
 	require.Equal(t, "INVALID_REGISTRATION_EMAIL_SUFFIX_WHITELIST", infraerrors.Reason(err))
 }
 
+func TestSettingService_UpdateSettings_MinCodexCLIVersion_PersistsAndRefreshesCache(t *testing.T) {
+	repo := &settingUpdateRepoStub{}
+	svc := NewSettingService(repo, &config.Config{})
+	t.Cleanup(func() { versionBoundsCache.Store(&cachedVersionBounds{}) })
+
+	err := svc.UpdateSettings(context.Background(), &SystemSettings{
+		MinCodexCLIVersion: "0.40.0",
+	})
+	require.NoError(t, err)
+	require.Equal(t, "0.40.0", repo.updates[SettingKeyMinCodexCLIVersion])
+	// 缓存已在更新时刷新，读取不会触发 GetMultiple（stub 会 panic）
+	require.Equal(t, "0.40.0", svc.GetMinCodexCLIVersion(context.Background()))
+}
+
 func TestParseDefaultSubscriptions_NormalizesValues(t *testing.T) {
 	got := parseDefaultSubscriptions(`[{"group_id":11,"validity_days":30},{"group_id":11,"validity_days":60},{"group_id":0,"validity_days":10},{"group_id":12,"validity_days":99999}]`)
 	require.Equal(t, []DefaultSubscriptionSetting{
@@ -68,6 +68,9 @@ type SystemSettings struct {
 	MinClaudeCodeVersion string
 	MaxClaudeCodeVersion string
 
+	// Codex CLI version check
+	MinCodexCLIVersion string
+
 	// 分组隔离：允许未分组 Key 调度（默认 false → 403）
 	AllowUngroupedKeyScheduling bool
 }
@@ -102,7 +105,10 @@ type PublicSettings struct {
 	CustomMenuItems             string // JSON array of custom menu items
 
 	LinuxDoOAuthEnabled bool
-	Version             string
+	// OIDC 管理后台单点登录（仅来自配置文件）
+	OIDCEnabled      bool
+	OIDCProviderName string
+	Version          string
 }
 
 // SoraS3Settings Sora S3 存储配置
@@ -198,6 +204,7 @@ const (
 	BetaPolicyActionPass   = "pass"   // 透传，不做任何处理
 	BetaPolicyActionFilter = "filter" // 过滤，从 beta header 中移除该 token
 	BetaPolicyActionBlock  = "block"  // 拦截，直接返回错误
+	BetaPolicyActionInject = "inject" // 注入，上游请求的 beta header 缺少该 token 时补齐
 
 	BetaPolicyScopeAll    = "all"    // 所有账号类型
 	BetaPolicyScopeOAuth  = "oauth"  // 仅 OAuth 账号
@@ -206,10 +213,12 @@ const (
 
 // BetaPolicyRule 单条 Beta 策略规则
 type BetaPolicyRule struct {
-	BetaToken    string `json:"beta_token"`              // beta token 值
-	Action       string `json:"action"`                  // "pass" | "filter" | "block"
-	Scope        string `json:"scope"`                   // "all" | "oauth" | "apikey"
-	ErrorMessage string `json:"error_message,omitempty"` // 自定义错误消息 (action=block 时生效)
+	BetaToken    string   `json:"beta_token"`              // beta token 值
+	Action       string   `json:"action"`                  // "pass" | "filter" | "block" | "inject"
+	Scope        string   `json:"scope"`                   // "all" | "oauth" | "apikey"
+	ErrorMessage string   `json:"error_message,omitempty"` // 自定义错误消息 (action=block 时生效)
+	Models       []string `json:"models,omitempty"`        // 仅对匹配的请求模型生效（支持末尾 * 通配），为空时匹配全部
+	AccountIDs   []int64  `json:"account_ids,omitempty"`   // 仅对指定账号生效，为空时匹配全部
 }
 
 // BetaPolicySettings Beta 策略配置
@@ -234,3 +243,174 @@ func DefaultBetaPolicySettings() *BetaPolicySettings {
 		},
 	}
 }
+
+// ClientRoutingRule 按客户端类型的账号路由规则
+type ClientRoutingRule struct {
+	ClientTypes  []string `json:"client_types"`     // 匹配的客户端类型（clientdetect.ClientType），"*" 匹配全部
+	AccountTypes []string `json:"account_types"`    // 允许调度的账号类型（oauth / setup-token / apikey / upstream）
+	Strict       bool     `json:"strict,omitempty"` // 严格模式：无匹配账号时直接失败，不回退到全部账号
+}
+
+// ClientRoutingSettings 客户端路由策略配置（按顺序匹配，首条命中生效）
+type ClientRoutingSettings struct {
+	Enabled bool                `json:"enabled"`
+	Rules   []ClientRoutingRule `json:"rules"`
+}
+
+// DefaultClientRoutingSettings 返回默认的客户端路由配置（关闭，无规则）
+func DefaultClientRoutingSettings() *ClientRoutingSettings {
+	return &ClientRoutingSettings{
+		Enabled: false,
+		Rules:   []ClientRoutingRule{},
+	}
+}
+
+// ModelAliasSettings 全局模型别名表：请求模型（支持末尾 * 通配符）-> 上游模型。
+// 分组的 model_aliases 优先于此表。
+type ModelAliasSettings struct {
+	Aliases map[string]string `json:"aliases"`
+}
+
+// DefaultModelAliasSettings 返回默认的模型别名配置（空表）
+func DefaultModelAliasSettings() *ModelAliasSettings {
+	return &ModelAliasSettings{Aliases: map[string]string{}}
+}
+
+// ModelPriceOverride 自定义模型价格（USD / 百万 token），未设置的字段沿用远程/内置价格
+type ModelPriceOverride struct {
+	Model                  string   `json:"model"` // 模型名（不区分大小写），支持末尾 * 通配符
+	InputPerMTok           *float64 `json:"input_per_mtok,omitempty"`
+	OutputPerMTok          *float64 `json:"output_per_mtok,omitempty"`
+	CacheCreationPerMTok   *float64 `json:"cache_creation_per_mtok,omitempty"`
+	CacheCreation1hPerMTok *float64 `json:"cache_creation_1h_per_mtok,omitempty"`
+	CacheReadPerMTok       *float64 `json:"cache_read_per_mtok,omitempty"`
+}
+
+// PricingOverrideSettings 管理后台维护的模型价格表，优先于配置文件 pricing.overrides
+type PricingOverrideSettings struct {
+	Overrides []ModelPriceOverride `json:"overrides"`
+}
+
+// DefaultPricingOverrideSettings 返回默认的价格覆盖配置（空表）
+func DefaultPricingOverrideSettings() *PricingOverrideSettings {
+	return &PricingOverrideSettings{Overrides: []ModelPriceOverride{}}
+}
+
+// RoutingRuleHeaderMatch 路由规则的请求头条件
+type RoutingRuleHeaderMatch struct {
+	Name  string `json:"name"`            // 请求头名称（不区分大小写）
+	Value string `json:"value,omitempty"` // 期望值（不区分大小写，支持末尾 * 通配符），为空表示只要求请求头存在
+}
+
+// RoutingRule 请求路由规则：已配置的条件全部满足时，请求改由目标分组调度。
+// 各条件内部为"任一命中"，未配置的条件不参与匹配。
+type RoutingRule struct {
+	Name            string                   `json:"name"`
+	Enabled         bool                     `json:"enabled"`
+	Priority        int                      `json:"priority"`                    // 数值越小越先匹配，相同优先级按配置顺序
+	Models          []string                 `json:"models,omitempty"`            // 请求模型（支持末尾 * 通配符）
+	ClientTypes     []string                 `json:"client_types,omitempty"`      // 客户端类型（clientdetect.ClientType）
+	Headers         []RoutingRuleHeaderMatch `json:"headers,omitempty"`           // 请求头条件（全部满足）
+	KeyTags         []string                 `json:"key_tags,omitempty"`          // API Key 标签
+	SourceGroupIDs  []int64                  `json:"source_group_ids,omitempty"`  // 规则生效的原分组，为空表示所有分组
+	MinRequestBytes int64                    `json:"min_request_bytes,omitempty"` // 请求体下限（字节，含）
+	MaxRequestBytes int64                    `json:"max_request_bytes,omitempty"` // 请求体上限（字节，含）
+	TargetGroupID   int64                    `json:"target_group_id"`             // 命中后调度的分组
+}
+
+// RoutingRuleSettings 请求路由规则配置（按优先级匹配，首条命中生效）
+type RoutingRuleSettings struct {
+	Enabled bool          `json:"enabled"`
+	Rules   []RoutingRule `json:"rules"`
+}
+
+// DefaultRoutingRuleSettings 返回默认的路由规则配置（关闭，无规则）
+func DefaultRoutingRuleSettings() *RoutingRuleSettings {
+	return &RoutingRuleSettings{
+		Enabled: false,
+		Rules:   []RoutingRule{},
+	}
+}
+
+// CanarySplit 金丝雀分流：命中的请求按 Percent 比例改由目标分组和/或目标模型处理（canary），
+// 其余请求保持原配置（control），两组分别统计指标。
+type CanarySplit struct {
+	Name           string   `json:"name"`
+	Enabled        bool     `json:"enabled"`
+	Models         []string `json:"models"`                     // 请求模型（支持末尾 * 通配符），至少一项
+	SourceGroupIDs []int64  `json:"source_group_ids,omitempty"` // 参与分流的原分组，为空表示所有分组
+	Percent        float64  `json:"percent"`                    // 分到 canary 的流量比例（0-100）
+	TargetGroupID  int64    `json:"target_group_id,omitempty"`  // canary 调度的分组，0 表示保持原分组
+	TargetModel    string   `json:"target_model,omitempty"`     // canary 使用的上游模型，为空表示保持原模型
+}
+
+// CanarySettings 金丝雀分流配置（按顺序匹配，首条命中生效）
+type CanarySettings struct {
+	Enabled bool          `json:"enabled"`
+	Splits  []CanarySplit `json:"splits"`
+}
+
+// DefaultCanarySettings 返回默认的金丝雀分流配置（关闭，无分流）
+func DefaultCanarySettings() *CanarySettings {
+	return &CanarySettings{
+		Enabled: false,
+		Splits:  []CanarySplit{},
+	}
+}
+
+// ShadowMirrorRule 影子流量规则：命中的请求按 Percent 比例额外复制一份发往目标分组和/或目标模型，
+// 影子请求不计费、不影响客户端响应，结果仅用于对比。
+type ShadowMirrorRule struct {
+	Name           string   `json:"name"`
+	Enabled        bool     `json:"enabled"`
+	Models         []string `json:"models"`                     // 请求模型（支持末尾 * 通配符），至少一项
+	SourceGroupIDs []int64  `json:"source_group_ids,omitempty"` // 参与镜像的原分组，为空表示所有分组
+	Percent        float64  `json:"percent"`                    // 镜像的流量比例（0-100）
+	TargetGroupID  int64    `json:"target_group_id,omitempty"`  // 影子请求调度的分组，0 表示保持原分组
+	TargetModel    string   `json:"target_model,omitempty"`     // 影子请求使用的上游模型，为空表示保持原模型
+	StoreResponses bool     `json:"store_responses"`            // 保留主/影子响应体（截断）供对比，false 时直接丢弃
+}
+
+// ShadowMirrorSettings 影子流量配置（按顺序匹配，首条命中生效）
+type ShadowMirrorSettings struct {
+	Enabled bool               `json:"enabled"`
+	Rules   []ShadowMirrorRule `json:"rules"`
+}
+
+// DefaultShadowMirrorSettings 返回默认的影子流量配置（关闭，无规则）
+func DefaultShadowMirrorSettings() *ShadowMirrorSettings {
+	return &ShadowMirrorSettings{
+		Enabled: false,
+		Rules:   []ShadowMirrorRule{},
+	}
+}
+
+// HeaderPolicyRule 请求/响应头策略规则：作用域内的请求额外透传、剥离或注入固定上游请求头，
+// 并可剥离或注入返回给客户端的响应头。作用域字段为空表示不限制。
+type HeaderPolicyRule struct {
+	Name                 string            `json:"name"`
+	Enabled              bool              `json:"enabled"`
+	Platforms            []string          `json:"platforms,omitempty"`              // 账号平台
+	AccountIDs           []int64           `json:"account_ids,omitempty"`            // 上游账号
+	GroupIDs             []int64           `json:"group_ids,omitempty"`              // API Key 分组
+	Paths                []string          `json:"paths,omitempty"`                  // 入站请求路径前缀
+	ForwardHeaders       []string          `json:"forward_headers,omitempty"`        // 在内置白名单之外额外透传的客户端请求头
+	StripHeaders         []string          `json:"strip_headers,omitempty"`          // 从上游请求中剥离的头（支持末尾 * 通配符）
+	SetHeaders           map[string]string `json:"set_headers,omitempty"`            // 注入/覆盖的上游请求头
+	StripResponseHeaders []string          `json:"strip_response_headers,omitempty"` // 从客户端响应中剥离的头（支持末尾 * 通配符）
+	SetResponseHeaders   map[string]string `json:"set_response_headers,omitempty"`   // 注入/覆盖的客户端响应头
+}
+
+// HeaderPolicySettings 请求/响应头策略配置（按顺序应用所有命中的规则）
+type HeaderPolicySettings struct {
+	Enabled bool               `json:"enabled"`
+	Rules   []HeaderPolicyRule `json:"rules"`
+}
+
+// DefaultHeaderPolicySettings 返回默认的头策略配置（关闭，无规则）
+func DefaultHeaderPolicySettings() *HeaderPolicySettings {
+	return &HeaderPolicySettings{
+		Enabled: false,
+		Rules:   []HeaderPolicyRule{},
+	}
+}
@@ -0,0 +1,334 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"math"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"golang.org/x/sync/singleflight"
+)
+
+const (
+	// maxShadowMirrorRules 影子流量规则的最大条数
+	maxShadowMirrorRules = 50
+	// shadowMirrorSampleLimit 进程内保留的最近对比样本数
+	shadowMirrorSampleLimit = 100
+	// ShadowMirrorMaxBodyBytes 对比样本中每个响应体保留的最大字节数
+	ShadowMirrorMaxBodyBytes = 64 << 10
+)
+
+// MatchShadowMirrorRule 返回首条匹配请求分组与模型的影子流量规则；未启用或无命中时返回 nil
+func MatchShadowMirrorRule(settings *ShadowMirrorSettings, groupID int64, model string) *ShadowMirrorRule {
+	if settings == nil || !settings.Enabled {
+		return nil
+	}
+	for i := range settings.Rules {
+		rule := &settings.Rules[i]
+		if !rule.Enabled || !routingModelMatches(rule.Models, model) {
+			continue
+		}
+		if len(rule.SourceGroupIDs) > 0 && !containsInt64(rule.SourceGroupIDs, groupID) {
+			continue
+		}
+		return rule
+	}
+	return nil
+}
+
+// normalizeShadowMirrorSettings 校验并规范化影子流量配置
+func normalizeShadowMirrorSettings(settings *ShadowMirrorSettings) error {
+	if len(settings.Rules) > maxShadowMirrorRules {
+		return fmt.Errorf("at most %d shadow mirror rules are allowed", maxShadowMirrorRules)
+	}
+	names := make(map[string]struct{}, len(settings.Rules))
+	for i := range settings.Rules {
+		rule := &settings.Rules[i]
+		rule.Name = strings.TrimSpace(rule.Name)
+		if rule.Name == "" {
+			return fmt.Errorf("rule[%d]: name cannot be empty", i)
+		}
+		if _, ok := names[rule.Name]; ok {
+			return fmt.Errorf("rule[%d]: duplicate name %q", i, rule.Name)
+		}
+		names[rule.Name] = struct{}{}
+		models, err := normalizeAPIKeyAllowedModels(rule.Models)
+		if err != nil {
+			return fmt.Errorf("rule[%d]: invalid models (only a trailing * wildcard is supported)", i)
+		}
+		if len(models) == 0 {
+			return fmt.Errorf("rule[%d]: models cannot be empty", i)
+		}
+		rule.Models = models
+		if math.IsNaN(rule.Percent) || rule.Percent < 0 || rule.Percent > 100 {
+			return fmt.Errorf("rule[%d]: percent must be between 0 and 100", i)
+		}
+		sourceIDs := make([]int64, 0, len(rule.SourceGroupIDs))
+		for _, id := range rule.SourceGroupIDs {
+			if id <= 0 {
+				return fmt.Errorf("rule[%d]: invalid source group id %d", i, id)
+			}
+			if !containsInt64(sourceIDs, id) {
+				sourceIDs = append(sourceIDs, id)
+			}
+		}
+		sort.Slice(sourceIDs, func(a, b int) bool { return sourceIDs[a] < sourceIDs[b] })
+		rule.SourceGroupIDs = sourceIDs
+		rule.TargetModel = strings.TrimSpace(rule.TargetModel)
+		if rule.TargetGroupID < 0 {
+			return fmt.Errorf("rule[%d]: invalid target_group_id", i)
+		}
+		if rule.TargetGroupID == 0 && rule.TargetModel == "" {
+			return fmt.Errorf("rule[%d]: target_group_id or target_model is required", i)
+		}
+	}
+	if settings.Rules == nil {
+		settings.Rules = []ShadowMirrorRule{}
+	}
+	return nil
+}
+
+// cachedShadowMirrorSettings 影子流量配置进程内缓存
+type cachedShadowMirrorSettings struct {
+	settings  *ShadowMirrorSettings
+	expiresAt int64 // unix nano
+}
+
+var shadowMirrorCache atomic.Value // *cachedShadowMirrorSettings
+
+var shadowMirrorSF singleflight.Group
+
+// shadowMirrorCacheTTL 缓存有效期
+const shadowMirrorCacheTTL = 60 * time.Second
+
+// GetShadowMirrorSettings 获取影子流量配置
+func (s *SettingService) GetShadowMirrorSettings(ctx context.Context) (*ShadowMirrorSettings, error) {
+	value, err := s.settingRepo.GetValue(ctx, SettingKeyShadowMirrorSettings)
+	if err != nil {
+		if errors.Is(err, ErrSettingNotFound) {
+			return DefaultShadowMirrorSettings(), nil
+		}
+		return nil, fmt.Errorf("get shadow mirror settings: %w", err)
+	}
+	if value == "" {
+		return DefaultShadowMirrorSettings(), nil
+	}
+
+	var settings ShadowMirrorSettings
+	if err := json.Unmarshal([]byte(value), &settings); err != nil {
+		return DefaultShadowMirrorSettings(), nil
+	}
+	if settings.Rules == nil {
+		settings.Rules = []ShadowMirrorRule{}
+	}
+	return &settings, nil
+}
+
+// SetShadowMirrorSettings 设置影子流量配置，刷新本实例缓存并清空对比指标与样本（其他实例在缓存过期后生效）
+func (s *SettingService) SetShadowMirrorSettings(ctx context.Context, settings *ShadowMirrorSettings) error {
+	if settings == nil {
+		return fmt.Errorf("settings cannot be nil")
+	}
+	if err := normalizeShadowMirrorSettings(settings); err != nil {
+		return err
+	}
+
+	data, err := json.Marshal(settings)
+	if err != nil {
+		return fmt.Errorf("marshal shadow mirror settings: %w", err)
+	}
+	if err := s.settingRepo.Set(ctx, SettingKeyShadowMirrorSettings, string(data)); err != nil {
+		return err
+	}
+	shadowMirrorSF.Forget("shadow_mirror")
+	shadowMirrorCache.Store(&cachedShadowMirrorSettings{
+		settings:  settings,
+		expiresAt: time.Now().Add(shadowMirrorCacheTTL).UnixNano(),
+	})
+	defaultShadowMirrorStats.reset()
+	return nil
+}
+
+// GetShadowMirrors 返回影子流量配置（进程内缓存，60 秒 TTL），供网关热路径使用。
+// 读取失败时返回 nil（fail-open，不镜像）。
+func (s *SettingService) GetShadowMirrors(ctx context.Context) *ShadowMirrorSettings {
+	if s == nil {
+		return nil
+	}
+	if cached, ok := shadowMirrorCache.Load().(*cachedShadowMirrorSettings); ok {
+		if time.Now().UnixNano() < cached.expiresAt {
+			return cached.settings
+		}
+	}
+	result, _, _ := shadowMirrorSF.Do("shadow_mirror", func() (any, error) {
+		if cached, ok := shadowMirrorCache.Load().(*cachedShadowMirrorSettings); ok {
+			if time.Now().UnixNano() < cached.expiresAt {
+				return cached.settings, nil
+			}
+		}
+		dbCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), versionBoundsDBTimeout)
+		defer cancel()
+		settings, err := s.GetShadowMirrorSettings(dbCtx)
+		if err != nil {
+			slog.Warn("failed to get shadow mirror settings, skipping shadow traffic", "error", err)
+			shadowMirrorCache.Store(&cachedShadowMirrorSettings{
+				expiresAt: time.Now().Add(versionBoundsErrorTTL).UnixNano(),
+			})
+			return (*ShadowMirrorSettings)(nil), nil
+		}
+		shadowMirrorCache.Store(&cachedShadowMirrorSettings{
+			settings:  settings,
+			expiresAt: time.Now().Add(shadowMirrorCacheTTL).UnixNano(),
+		})
+		return settings, nil
+	})
+	settings, _ := result.(*ShadowMirrorSettings)
+	return settings
+}
+
+// ShadowMirrorSample 一次主请求与影子请求的对比样本
+type ShadowMirrorSample struct {
+	Rule              string    `json:"rule"`
+	At                time.Time `json:"at"`
+	Model             string    `json:"model"`
+	ShadowModel       string    `json:"shadow_model"`
+	SourceGroupID     int64     `json:"source_group_id"`
+	TargetGroupID     int64     `json:"target_group_id"`
+	PrimaryStatus     int       `json:"primary_status"`
+	ShadowStatus      int       `json:"shadow_status"`
+	PrimaryDurationMs int64     `json:"primary_duration_ms"`
+	ShadowDurationMs  int64     `json:"shadow_duration_ms"`
+	ShadowError       string    `json:"shadow_error,omitempty"`
+	PrimaryBody       string    `json:"primary_body,omitempty"` // 仅 StoreResponses 时保留，超过 ShadowMirrorMaxBodyBytes 截断
+	ShadowBody        string    `json:"shadow_body,omitempty"`
+}
+
+// ShadowMirrorRuleMetrics 单条规则的进程内累计指标
+type ShadowMirrorRuleMetrics struct {
+	Name                 string  `json:"name"`
+	Mirrored             uint64  `json:"mirrored"`
+	Skipped              uint64  `json:"skipped"`           // 影子并发已满而未镜像
+	StatusMismatches     uint64  `json:"status_mismatches"` // 主/影子响应状态码不一致
+	ShadowErrors         uint64  `json:"shadow_errors"`     // 影子请求失败（状态码 >= 400 或异常）
+	PrimaryAvgDurationMs float64 `json:"primary_avg_duration_ms"`
+	ShadowAvgDurationMs  float64 `json:"shadow_avg_duration_ms"`
+}
+
+// ShadowMirrorSnapshot 影子流量指标与最近样本快照（本实例自 Since 起累计，保存配置时清空）
+type ShadowMirrorSnapshot struct {
+	Since   time.Time                 `json:"since"`
+	Rules   []ShadowMirrorRuleMetrics `json:"rules"`
+	Samples []ShadowMirrorSample      `json:"samples"` // 最新的在前
+}
+
+type shadowMirrorCounters struct {
+	mirrored         uint64
+	skipped          uint64
+	statusMismatches uint64
+	shadowErrors     uint64
+	primaryMs        uint64
+	shadowMs         uint64
+}
+
+type shadowMirrorStatsStore struct {
+	mu      sync.Mutex
+	since   time.Time
+	rules   map[string]*shadowMirrorCounters
+	samples []ShadowMirrorSample // 环形缓冲
+	next    int
+}
+
+var defaultShadowMirrorStats = &shadowMirrorStatsStore{since: time.Now(), rules: map[string]*shadowMirrorCounters{}}
+
+func (m *shadowMirrorStatsStore) counters(name string) *shadowMirrorCounters {
+	counters, ok := m.rules[name]
+	if !ok {
+		counters = &shadowMirrorCounters{}
+		m.rules[name] = counters
+	}
+	return counters
+}
+
+func (m *shadowMirrorStatsStore) reset() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.since = time.Now()
+	m.rules = map[string]*shadowMirrorCounters{}
+	m.samples = nil
+	m.next = 0
+}
+
+// RecordShadowMirrorSkipped 记录一次因影子并发已满而未镜像的请求
+func RecordShadowMirrorSkipped(rule string) {
+	defaultShadowMirrorStats.mu.Lock()
+	defer defaultShadowMirrorStats.mu.Unlock()
+	defaultShadowMirrorStats.counters(rule).skipped++
+}
+
+// RecordShadowMirrorResult 记录一次镜像结果并保存为对比样本
+func RecordShadowMirrorResult(sample ShadowMirrorSample) {
+	m := defaultShadowMirrorStats
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	counters := m.counters(sample.Rule)
+	counters.mirrored++
+	if sample.PrimaryStatus != sample.ShadowStatus {
+		counters.statusMismatches++
+	}
+	if sample.ShadowError != "" || sample.ShadowStatus >= 400 {
+		counters.shadowErrors++
+	}
+	if sample.PrimaryDurationMs > 0 {
+		counters.primaryMs += uint64(sample.PrimaryDurationMs)
+	}
+	if sample.ShadowDurationMs > 0 {
+		counters.shadowMs += uint64(sample.ShadowDurationMs)
+	}
+
+	if len(m.samples) < shadowMirrorSampleLimit {
+		m.samples = append(m.samples, sample)
+		return
+	}
+	m.samples[m.next] = sample
+	m.next = (m.next + 1) % shadowMirrorSampleLimit
+}
+
+// GetShadowMirrorSnapshot 返回当前影子流量指标（按规则名称排序）与最近样本
+func GetShadowMirrorSnapshot() ShadowMirrorSnapshot {
+	m := defaultShadowMirrorStats
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	out := ShadowMirrorSnapshot{
+		Since:   m.since,
+		Rules:   make([]ShadowMirrorRuleMetrics, 0, len(m.rules)),
+		Samples: make([]ShadowMirrorSample, 0, len(m.samples)),
+	}
+	for name, counters := range m.rules {
+		metrics := ShadowMirrorRuleMetrics{
+			Name:             name,
+			Mirrored:         counters.mirrored,
+			Skipped:          counters.skipped,
+			StatusMismatches: counters.statusMismatches,
+			ShadowErrors:     counters.shadowErrors,
+		}
+		if counters.mirrored > 0 {
+			metrics.PrimaryAvgDurationMs = float64(counters.primaryMs) / float64(counters.mirrored)
+			metrics.ShadowAvgDurationMs = float64(counters.shadowMs) / float64(counters.mirrored)
+		}
+		out.Rules = append(out.Rules, metrics)
+	}
+	sort.Slice(out.Rules, func(a, b int) bool { return out.Rules[a].Name < out.Rules[b].Name })
+	// 环形缓冲中 next 之前的为最新写入
+	for i := 0; i < len(m.samples); i++ {
+		idx := (m.next - 1 - i + 2*len(m.samples)) % len(m.samples)
+		out.Samples = append(out.Samples, m.samples[idx])
+	}
+	return out
+}
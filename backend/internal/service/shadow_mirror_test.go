@@ -0,0 +1,72 @@
+//go:build unit
+
+package service
+
+import (
+	"context"
+	"testing"
+
+	"github.com/ShaohongDong/sub2api/internal/config"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSetShadowMirrorSettings_Validates(t *testing.T) {
+	svc := NewSettingService(newRuntimeSettingRepoStub(), &config.Config{})
+	ctx := context.Background()
+	t.Cleanup(func() { shadowMirrorCache.Store(&cachedShadowMirrorSettings{}) })
+
+	invalid := []ShadowMirrorRule{
+		{Name: "", Models: []string{"claude-*"}, Percent: 5, TargetGroupID: 2},
+		{Name: "no models", Percent: 5, TargetGroupID: 2},
+		{Name: "bad percent", Models: []string{"claude-*"}, Percent: -1, TargetGroupID: 2},
+		{Name: "no target", Models: []string{"claude-*"}, Percent: 5},
+		{Name: "bad source", Models: []string{"claude-*"}, Percent: 5, TargetGroupID: 2, SourceGroupIDs: []int64{-3}},
+	}
+	for _, rule := range invalid {
+		err := svc.SetShadowMirrorSettings(ctx, &ShadowMirrorSettings{Enabled: true, Rules: []ShadowMirrorRule{rule}})
+		require.Error(t, err, rule.Name)
+	}
+
+	err := svc.SetShadowMirrorSettings(ctx, &ShadowMirrorSettings{Enabled: true, Rules: []ShadowMirrorRule{
+		{Name: " new-account ", Enabled: true, Models: []string{" claude-* "}, Percent: 10, TargetGroupID: 2, SourceGroupIDs: []int64{5, 1, 5}, StoreResponses: true},
+	}})
+	require.NoError(t, err)
+	got, err := svc.GetShadowMirrorSettings(ctx)
+	require.NoError(t, err)
+	require.Equal(t, "new-account", got.Rules[0].Name)
+	require.Equal(t, []string{"claude-*"}, got.Rules[0].Models)
+	require.Equal(t, []int64{1, 5}, got.Rules[0].SourceGroupIDs)
+	require.True(t, got.Rules[0].StoreResponses)
+	require.Same(t, svc.GetShadowMirrors(ctx), svc.GetShadowMirrors(ctx))
+
+	require.Equal(t, "new-account", MatchShadowMirrorRule(got, 5, "claude-sonnet-4-5").Name)
+	require.Nil(t, MatchShadowMirrorRule(got, 2, "claude-sonnet-4-5"))
+	require.Nil(t, MatchShadowMirrorRule(got, 5, "gpt-5"))
+}
+
+func TestShadowMirrorSnapshot_CountsAndKeepsLatestSamples(t *testing.T) {
+	defaultShadowMirrorStats.reset()
+	t.Cleanup(defaultShadowMirrorStats.reset)
+
+	for i := 0; i < shadowMirrorSampleLimit+5; i++ {
+		RecordShadowMirrorResult(ShadowMirrorSample{Rule: "a", PrimaryStatus: 200, ShadowStatus: 200, PrimaryDurationMs: 100, ShadowDurationMs: int64(i)})
+	}
+	RecordShadowMirrorResult(ShadowMirrorSample{Rule: "b", PrimaryStatus: 200, ShadowStatus: 502, PrimaryDurationMs: 10, ShadowDurationMs: 30})
+	RecordShadowMirrorSkipped("b")
+
+	snapshot := GetShadowMirrorSnapshot()
+	require.Len(t, snapshot.Rules, 2)
+	require.Equal(t, "a", snapshot.Rules[0].Name)
+	require.Equal(t, uint64(shadowMirrorSampleLimit+5), snapshot.Rules[0].Mirrored)
+	require.Zero(t, snapshot.Rules[0].StatusMismatches)
+	require.InDelta(t, 100, snapshot.Rules[0].PrimaryAvgDurationMs, 0.001)
+	require.Equal(t, ShadowMirrorRuleMetrics{
+		Name: "b", Mirrored: 1, Skipped: 1, StatusMismatches: 1, ShadowErrors: 1,
+		PrimaryAvgDurationMs: 10, ShadowAvgDurationMs: 30,
+	}, snapshot.Rules[1])
+
+	require.Len(t, snapshot.Samples, shadowMirrorSampleLimit)
+	require.Equal(t, "b", snapshot.Samples[0].Rule)
+	require.Equal(t, int64(shadowMirrorSampleLimit+4), snapshot.Samples[1].ShadowDurationMs)
+	require.Equal(t, int64(6), snapshot.Samples[len(snapshot.Samples)-1].ShadowDurationMs)
+}
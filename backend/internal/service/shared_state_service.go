@@ -0,0 +1,188 @@
+package service
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/ShaohongDong/sub2api/internal/config"
+)
+
+// SharedUsageWindowBucket Codex 用量窗口中单个 5 分钟桶的计数
+type SharedUsageWindowBucket struct {
+	Slot     int64
+	Requests int64
+	Tokens   int64
+}
+
+// SharedStateCache 多实例共享的账号运行时状态存储（Redis）
+type SharedStateCache interface {
+	// SetAccountCircuitOpen 记录账号熔断打开，until 为熔断到期时间
+	SetAccountCircuitOpen(ctx context.Context, accountID int64, until time.Time) error
+	// ListOpenAccountCircuits 返回所有实例上尚未到期的账号熔断（accountID -> 到期时间）
+	ListOpenAccountCircuits(ctx context.Context, now time.Time) (map[int64]time.Time, error)
+	// IncrOpenAIUsageWindow 累加账号在 slot 桶内的请求数与 token 数
+	IncrOpenAIUsageWindow(ctx context.Context, accountID, slot, tokens int64) error
+	// GetOpenAIUsageWindow 返回账号 slot >= fromSlot 的所有桶
+	GetOpenAIUsageWindow(ctx context.Context, accountID, fromSlot int64) ([]SharedUsageWindowBucket, error)
+}
+
+// sharedStateWriteTimeout 单次写入共享状态的超时，写入失败只记录日志（本实例状态仍然生效）
+const sharedStateWriteTimeout = 2 * time.Second
+
+type sharedUsageWindowSnapshot struct {
+	counter    atomic.Pointer[openAIUsageWindowCounter]
+	fetchedAt  atomic.Int64 // unix nano
+	refreshing atomic.Bool
+}
+
+// SharedStateService 在多个网关实例之间同步账号熔断冷却与 Codex 用量窗口，
+// 避免负载均衡后的各实例只看到自己的流量、重复消耗同一账号的额度。
+// 读路径只使用本地缓存的快照，快照按 sync_interval_seconds 在后台刷新，不阻塞调度。
+type SharedStateService struct {
+	cache    SharedStateCache
+	enabled  bool
+	interval time.Duration
+
+	usageSnapshots sync.Map // accountID -> *sharedUsageWindowSnapshot
+
+	stopCh   chan struct{}
+	stopOnce sync.Once
+	wg       sync.WaitGroup
+}
+
+// NewSharedStateService 创建共享状态服务；未启用 shared_state 时所有方法为空操作
+func NewSharedStateService(cache SharedStateCache, cfg *config.Config) *SharedStateService {
+	s := &SharedStateService{
+		cache:    cache,
+		interval: 2 * time.Second,
+		stopCh:   make(chan struct{}),
+	}
+	if cfg != nil && cfg.SharedState.Enabled && cache != nil {
+		s.enabled = true
+		if cfg.SharedState.SyncIntervalSeconds > 0 {
+			s.interval = time.Duration(cfg.SharedState.SyncIntervalSeconds) * time.Second
+		}
+	}
+	return s
+}
+
+// Enabled 是否启用了跨实例共享
+func (s *SharedStateService) Enabled() bool {
+	return s != nil && s.enabled
+}
+
+// Start 注册熔断发布回调并启动同步循环
+func (s *SharedStateService) Start() {
+	if !s.Enabled() {
+		return
+	}
+	publish := s.publishAccountCircuitOpen
+	defaultAccountCircuits.onOpen.Store(&publish)
+
+	s.wg.Add(1)
+	go func() {
+		defer s.wg.Done()
+		s.syncAccountCircuits()
+		ticker := time.NewTicker(s.interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				s.syncAccountCircuits()
+			case <-s.stopCh:
+				return
+			}
+		}
+	}()
+	slog.Info("shared_state.service_started", "sync_interval_seconds", int(s.interval/time.Second))
+}
+
+// Stop 停止同步循环并注销熔断发布回调
+func (s *SharedStateService) Stop() {
+	if !s.Enabled() {
+		return
+	}
+	s.stopOnce.Do(func() {
+		defaultAccountCircuits.onOpen.Store(nil)
+		defaultAccountCircuits.remoteOpen.Store(nil)
+		close(s.stopCh)
+	})
+	s.wg.Wait()
+}
+
+func (s *SharedStateService) publishAccountCircuitOpen(accountID int64, until time.Time) {
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), sharedStateWriteTimeout)
+		defer cancel()
+		if err := s.cache.SetAccountCircuitOpen(ctx, accountID, until); err != nil {
+			slog.Warn("shared_state.circuit_publish_failed", "account_id", accountID, "error", err)
+		}
+	}()
+}
+
+func (s *SharedStateService) syncAccountCircuits() {
+	ctx, cancel := context.WithTimeout(context.Background(), s.interval)
+	defer cancel()
+	open, err := s.cache.ListOpenAccountCircuits(ctx, time.Now())
+	if err != nil {
+		// 拉取失败时保留上一次的结果，熔断到期时间仍会在 allows 中检查
+		slog.Warn("shared_state.circuit_sync_failed", "error", err)
+		return
+	}
+	defaultAccountCircuits.remoteOpen.Store(&open)
+}
+
+// recordOpenAIUsage 异步累加账号用量到共享窗口
+func (s *SharedStateService) recordOpenAIUsage(accountID, tokens int64, now time.Time) {
+	if !s.Enabled() {
+		return
+	}
+	slot := openAIUsageWindowSlot(now)
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), sharedStateWriteTimeout)
+		defer cancel()
+		if err := s.cache.IncrOpenAIUsageWindow(ctx, accountID, slot, tokens); err != nil {
+			slog.Warn("shared_state.usage_window_incr_failed", "account_id", accountID, "error", err)
+		}
+	}()
+}
+
+// openAIUsageCounter 返回账号的全实例用量窗口快照；快照过期时在后台刷新，
+// 首次访问尚无快照时返回 nil，调用方回退到本实例计数。
+func (s *SharedStateService) openAIUsageCounter(accountID int64, now time.Time) *openAIUsageWindowCounter {
+	if !s.Enabled() {
+		return nil
+	}
+	value, _ := s.usageSnapshots.LoadOrStore(accountID, &sharedUsageWindowSnapshot{})
+	snapshot := value.(*sharedUsageWindowSnapshot)
+	if now.UnixNano()-snapshot.fetchedAt.Load() >= int64(s.interval) && snapshot.refreshing.CompareAndSwap(false, true) {
+		go s.refreshOpenAIUsage(accountID, snapshot)
+	}
+	return snapshot.counter.Load()
+}
+
+func (s *SharedStateService) refreshOpenAIUsage(accountID int64, snapshot *sharedUsageWindowSnapshot) {
+	defer snapshot.refreshing.Store(false)
+	ctx, cancel := context.WithTimeout(context.Background(), s.interval)
+	defer cancel()
+
+	now := time.Now()
+	fromSlot := openAIUsageWindowSlot(now) - int64(openAIUsageWindowBuckets) + 1
+	buckets, err := s.cache.GetOpenAIUsageWindow(ctx, accountID, fromSlot)
+	if err != nil {
+		slog.Warn("shared_state.usage_window_fetch_failed", "account_id", accountID, "error", err)
+		return
+	}
+	counter := &openAIUsageWindowCounter{}
+	for _, b := range buckets {
+		if b.Slot < fromSlot {
+			continue
+		}
+		counter.buckets[b.Slot%int64(openAIUsageWindowBuckets)] = openAIUsageWindowBucket{slot: b.Slot, requests: b.Requests, tokens: b.Tokens}
+	}
+	snapshot.counter.Store(counter)
+	snapshot.fetchedAt.Store(now.UnixNano())
+}
@@ -0,0 +1,138 @@
+//go:build unit
+
+package service
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ShaohongDong/sub2api/internal/config"
+	"github.com/stretchr/testify/require"
+)
+
+type sharedStateCacheStub struct {
+	mu      sync.Mutex
+	open    map[int64]time.Time
+	buckets map[int64]map[int64]*SharedUsageWindowBucket
+	incrs   chan struct{}
+}
+
+func newSharedStateCacheStub() *sharedStateCacheStub {
+	return &sharedStateCacheStub{
+		open:    make(map[int64]time.Time),
+		buckets: make(map[int64]map[int64]*SharedUsageWindowBucket),
+		incrs:   make(chan struct{}, 16),
+	}
+}
+
+func (c *sharedStateCacheStub) SetAccountCircuitOpen(_ context.Context, accountID int64, until time.Time) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.open[accountID] = until
+	return nil
+}
+
+func (c *sharedStateCacheStub) ListOpenAccountCircuits(_ context.Context, now time.Time) (map[int64]time.Time, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	out := make(map[int64]time.Time)
+	for id, until := range c.open {
+		if until.After(now) {
+			out[id] = until
+		}
+	}
+	return out, nil
+}
+
+func (c *sharedStateCacheStub) IncrOpenAIUsageWindow(_ context.Context, accountID, slot, tokens int64) error {
+	c.mu.Lock()
+	if c.buckets[accountID] == nil {
+		c.buckets[accountID] = make(map[int64]*SharedUsageWindowBucket)
+	}
+	b := c.buckets[accountID][slot]
+	if b == nil {
+		b = &SharedUsageWindowBucket{Slot: slot}
+		c.buckets[accountID][slot] = b
+	}
+	b.Requests++
+	b.Tokens += tokens
+	c.mu.Unlock()
+	c.incrs <- struct{}{}
+	return nil
+}
+
+func (c *sharedStateCacheStub) GetOpenAIUsageWindow(_ context.Context, accountID, fromSlot int64) ([]SharedUsageWindowBucket, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	var out []SharedUsageWindowBucket
+	for slot, b := range c.buckets[accountID] {
+		if slot >= fromSlot {
+			out = append(out, *b)
+		}
+	}
+	return out, nil
+}
+
+func newSharedStateServiceForTest(cache SharedStateCache) *SharedStateService {
+	return NewSharedStateService(cache, &config.Config{SharedState: config.SharedStateConfig{Enabled: true, SyncIntervalSeconds: 1}})
+}
+
+func TestSharedStateService_DisabledIsNoop(t *testing.T) {
+	svc := NewSharedStateService(newSharedStateCacheStub(), &config.Config{})
+	require.False(t, svc.Enabled())
+	require.Nil(t, svc.openAIUsageCounter(1, time.Now()))
+	svc.Start()
+	svc.Stop()
+
+	var nilSvc *SharedStateService
+	require.False(t, nilSvc.Enabled())
+	nilSvc.recordOpenAIUsage(1, 10, time.Now())
+}
+
+func TestAccountCircuit_RemoteOpenBlocksScheduling(t *testing.T) {
+	r := testCircuitRegistry()
+	now := time.Now()
+	remote := map[int64]time.Time{5: now.Add(time.Minute)}
+	r.remoteOpen.Store(&remote)
+
+	require.False(t, r.allows(5, now))
+	require.True(t, r.allows(6, now))
+	// 到期后恢复调度
+	require.True(t, r.allows(5, now.Add(2*time.Minute)))
+}
+
+func TestAccountCircuit_PublishesOnOpen(t *testing.T) {
+	r := testCircuitRegistry()
+	var published []int64
+	onOpen := func(accountID int64, until time.Time) { published = append(published, accountID) }
+	r.onOpen.Store(&onOpen)
+
+	now := time.Now()
+	for i := 0; i < 4; i++ {
+		r.record(3, true, 10*time.Millisecond, now)
+	}
+	require.Equal(t, []int64{3}, published)
+}
+
+func TestSharedStateService_OpenAIUsageWindowAcrossInstances(t *testing.T) {
+	cache := newSharedStateCacheStub()
+	instanceA := newSharedStateServiceForTest(cache)
+	instanceB := newSharedStateServiceForTest(cache)
+	now := time.Now()
+
+	instanceA.recordOpenAIUsage(9, 100, now)
+	<-cache.incrs
+
+	// 首次访问尚无快照，触发后台刷新
+	require.Nil(t, instanceB.openAIUsageCounter(9, now))
+	require.Eventually(t, func() bool {
+		counter := instanceB.openAIUsageCounter(9, now)
+		if counter == nil {
+			return false
+		}
+		requests, tokens := counter.sum(now.Add(-time.Hour), now)
+		return requests == 1 && tokens == 100
+	}, 2*time.Second, 10*time.Millisecond)
+}
@@ -0,0 +1,47 @@
+package service
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/ShaohongDong/sub2api/internal/config"
+	"github.com/ShaohongDong/sub2api/internal/pkg/ctxkey"
+)
+
+// ShouldSimulate 判断请求是否以演练模式处理：全局开启 gateway.simulation.enabled，或管理员为该 Key 开启了 simulate
+func ShouldSimulate(cfg *config.Config, apiKey *APIKey) bool {
+	if cfg != nil && cfg.Gateway.Simulation.Enabled {
+		return true
+	}
+	return apiKey != nil && apiKey.Simulate
+}
+
+// WithSimulation 标记请求为演练请求，之后由该 context 发出的上游调用都由内置模拟上游应答
+func WithSimulation(ctx context.Context) context.Context {
+	return context.WithValue(ctx, ctxkey.Simulate, true)
+}
+
+// IsSimulatedRequest 判断 context 是否属于演练请求
+func IsSimulatedRequest(ctx context.Context) bool {
+	if ctx == nil {
+		return false
+	}
+	simulated, _ := ctx.Value(ctxkey.Simulate).(bool)
+	return simulated
+}
+
+// SetSimulation 开启或关闭 Key 的演练模式（仅管理员可设置）
+func (s *APIKeyService) SetSimulation(ctx context.Context, id int64, enabled bool) (*APIKey, error) {
+	apiKey, err := s.apiKeyRepo.GetByID(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("get api key: %w", err)
+	}
+	apiKey.Simulate = enabled
+	if err := s.apiKeyRepo.Update(ctx, apiKey); err != nil {
+		return nil, fmt.Errorf("update api key: %w", err)
+	}
+
+	s.InvalidateAuthCacheByKey(ctx, apiKey.Key)
+	s.compileAPIKeyIPRules(apiKey)
+	return apiKey, nil
+}
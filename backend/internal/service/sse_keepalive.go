@@ -0,0 +1,114 @@
+package service
+
+import (
+	"bufio"
+	"fmt"
+	"time"
+
+	"github.com/ShaohongDong/sub2api/internal/config"
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	// sseCommentHeartbeat SSE 注释行，客户端解析器会忽略
+	sseCommentHeartbeat = ":\n\n"
+	// sseAnthropicPingHeartbeat Anthropic Messages 流的 ping 事件
+	sseAnthropicPingHeartbeat = "event: ping\ndata: {\"type\":\"ping\"}\n\n"
+)
+
+// streamKeepaliveInterval 返回流式心跳间隔（运行时设置优先，其次 gateway.stream_keepalive_interval），0 表示禁用。
+func streamKeepaliveInterval(cfg *config.Config) time.Duration {
+	if rs := CurrentRuntimeSettings(); rs != nil {
+		return time.Duration(rs.StreamKeepaliveIntervalSeconds) * time.Second
+	}
+	if cfg != nil && cfg.Gateway.StreamKeepaliveInterval > 0 {
+		return time.Duration(cfg.Gateway.StreamKeepaliveInterval) * time.Second
+	}
+	return 0
+}
+
+// forEachSSELine 逐行读取上游 SSE 交给 onLine 处理，onLine 返回 false（客户端已断开）时停止读取。
+//
+// interval > 0 时由独立 goroutine 读取上游：下游连续 interval 未写出任何数据时写出 heartbeat。
+// 以下游实际写出为准而非上游收到数据，因此模型长时间推理、上游只发送不产生下游分片的事件时
+// 同样会发送心跳，避免反向代理与客户端因空闲超时断开。
+// 返回 false 表示因客户端断开而提前结束；error 为上游读取错误。
+func forEachSSELine(scanner *bufio.Scanner, w gin.ResponseWriter, interval time.Duration, heartbeat string, onLine func(line string) bool) (bool, error) {
+	if interval <= 0 {
+		for scanner.Scan() {
+			if !onLine(scanner.Text()) {
+				return false, nil
+			}
+		}
+		return true, scanner.Err()
+	}
+
+	type scanEvent struct {
+		line string
+		err  error
+	}
+	events := make(chan scanEvent, 16)
+	done := make(chan struct{})
+	defer close(done)
+	sendEvent := func(ev scanEvent) bool {
+		select {
+		case events <- ev:
+			return true
+		case <-done:
+			return false
+		}
+	}
+	go func() {
+		defer close(events)
+		for scanner.Scan() {
+			if !sendEvent(scanEvent{line: scanner.Text()}) {
+				return
+			}
+		}
+		if err := scanner.Err(); err != nil {
+			_ = sendEvent(scanEvent{err: err})
+		}
+	}()
+
+	// 检查粒度取 interval/5（至少 1 秒），心跳最迟在空闲 interval 后一个检查周期内发出
+	tick := interval / 5
+	if tick < time.Second {
+		tick = time.Second
+	}
+	if tick > interval {
+		tick = interval
+	}
+	ticker := time.NewTicker(tick)
+	defer ticker.Stop()
+	lastSize := w.Size()
+	lastWriteAt := time.Now()
+
+	for {
+		select {
+		case ev, ok := <-events:
+			if !ok {
+				return true, nil
+			}
+			if ev.err != nil {
+				return true, ev.err
+			}
+			if !onLine(ev.line) {
+				return false, nil
+			}
+		case now := <-ticker.C:
+			if size := w.Size(); size != lastSize {
+				lastSize = size
+				lastWriteAt = now
+			}
+			if now.Sub(lastWriteAt) < interval {
+				continue
+			}
+			if _, err := fmt.Fprint(w, heartbeat); err != nil {
+				return false, nil
+			}
+			w.Flush()
+			lastSize = w.Size()
+			lastWriteAt = now
+		}
+	}
+}
@@ -0,0 +1,56 @@
+package service
+
+import (
+	"bufio"
+	"io"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/require"
+)
+
+func TestForEachSSELine_HeartbeatWhileUpstreamIdle(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	rec := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(rec)
+
+	pr, pw := io.Pipe()
+	go func() {
+		// 模拟推理阶段：上游仅发送不会转换为下游分片的事件
+		_, _ = io.WriteString(pw, "data: reasoning\n")
+		time.Sleep(2500 * time.Millisecond)
+		_, _ = io.WriteString(pw, "data: delta\n")
+		_ = pw.Close()
+	}()
+
+	var lines []string
+	completed, err := forEachSSELine(bufio.NewScanner(pr), c.Writer, time.Second, sseCommentHeartbeat, func(line string) bool {
+		lines = append(lines, line)
+		if line == "data: delta" {
+			_, _ = io.WriteString(c.Writer, "data: chunk\n\n")
+		}
+		return true
+	})
+	require.True(t, completed)
+	require.NoError(t, err)
+	require.Equal(t, []string{"data: reasoning", "data: delta"}, lines)
+	require.True(t, strings.HasPrefix(rec.Body.String(), sseCommentHeartbeat))
+	require.True(t, strings.HasSuffix(rec.Body.String(), "data: chunk\n\n"))
+}
+
+func TestForEachSSELine_StopsWhenClientGone(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	c, _ := gin.CreateTestContext(httptest.NewRecorder())
+
+	calls := 0
+	completed, err := forEachSSELine(bufio.NewScanner(strings.NewReader("a\nb\nc\n")), c.Writer, 0, sseCommentHeartbeat, func(string) bool {
+		calls++
+		return false
+	})
+	require.False(t, completed)
+	require.NoError(t, err)
+	require.Equal(t, 1, calls)
+}
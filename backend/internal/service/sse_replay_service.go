@@ -0,0 +1,204 @@
+package service
+
+import (
+	"context"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ShaohongDong/sub2api/internal/config"
+	infraerrors "github.com/ShaohongDong/sub2api/internal/pkg/errors"
+	"github.com/google/uuid"
+)
+
+var (
+	ErrSSEReplayStreamNotFound = infraerrors.NotFound("SSE_REPLAY_STREAM_NOT_FOUND", "stream not found or no longer replayable")
+	ErrSSEReplayEventExpired   = infraerrors.Conflict("SSE_REPLAY_EVENT_EXPIRED", "requested event is no longer buffered, restart the request")
+)
+
+// SSEReplayService 为进行中的流式响应保存最近输出的 SSE 事件（进程内环形缓冲）。
+//
+// 客户端断线后上游继续生成，客户端携带 Last-Event-ID 重新发起同一请求即可从断点继续接收；
+// 无人重连超过 reconnect_grace_seconds 时取消上游请求。缓冲不跨实例共享。
+type SSEReplayService struct {
+	cfg *config.Config
+
+	mu      sync.Mutex
+	streams map[string]*SSEReplayStream
+}
+
+// NewSSEReplayService creates a new SSEReplayService
+func NewSSEReplayService(cfg *config.Config) *SSEReplayService {
+	return &SSEReplayService{cfg: cfg, streams: make(map[string]*SSEReplayStream)}
+}
+
+// Enabled 是否启用断线续传
+func (s *SSEReplayService) Enabled() bool {
+	return s != nil && s.cfg != nil && s.cfg.SSEReplay.Enabled
+}
+
+// HeartbeatInterval 重连连接在无新事件时发送心跳的间隔，沿用 gateway.stream_keepalive_interval
+func (s *SSEReplayService) HeartbeatInterval() time.Duration {
+	return streamKeepaliveInterval(s.cfg)
+}
+
+// Begin 登记一个新的流。cancel 用于在无人重连时取消上游请求；原始连接视为首个订阅者。
+func (s *SSEReplayService) Begin(apiKeyID int64, cancel context.CancelFunc) *SSEReplayStream {
+	st := &SSEReplayStream{
+		ID:          "sse_" + strings.ReplaceAll(uuid.NewString(), "-", ""),
+		APIKeyID:    apiKeyID,
+		buf:         make([]SSEReplayEvent, s.cfg.SSEReplay.BufferEvents),
+		notify:      make(chan struct{}),
+		subscribers: 1,
+		cancel:      cancel,
+		grace:       time.Duration(s.cfg.SSEReplay.ReconnectGraceSeconds) * time.Second,
+		retention:   time.Duration(s.cfg.SSEReplay.RetentionSeconds) * time.Second,
+		remove:      s.remove,
+	}
+	s.mu.Lock()
+	s.streams[st.ID] = st
+	s.mu.Unlock()
+	return st
+}
+
+// Get 返回属于 apiKeyID 的流，不存在或不属于该 Key 时返回 ErrSSEReplayStreamNotFound
+func (s *SSEReplayService) Get(apiKeyID int64, streamID string) (*SSEReplayStream, error) {
+	s.mu.Lock()
+	st, ok := s.streams[streamID]
+	s.mu.Unlock()
+	if !ok || st.APIKeyID != apiKeyID {
+		return nil, ErrSSEReplayStreamNotFound
+	}
+	return st, nil
+}
+
+func (s *SSEReplayService) remove(streamID string) {
+	s.mu.Lock()
+	delete(s.streams, streamID)
+	s.mu.Unlock()
+}
+
+// SSEReplayEventID 组装事件 id：<stream_id>:<seq>
+func SSEReplayEventID(streamID string, seq int64) string {
+	return streamID + ":" + strconv.FormatInt(seq, 10)
+}
+
+// ParseSSEReplayEventID 解析 Last-Event-ID
+func ParseSSEReplayEventID(id string) (string, int64, bool) {
+	streamID, seqStr, ok := strings.Cut(strings.TrimSpace(id), ":")
+	if !ok || !strings.HasPrefix(streamID, "sse_") {
+		return "", 0, false
+	}
+	seq, err := strconv.ParseInt(seqStr, 10, 64)
+	if err != nil || seq < 0 {
+		return "", 0, false
+	}
+	return streamID, seq, true
+}
+
+// SSEReplayEvent 一个已缓冲的 SSE 事件（不含 id 行）
+type SSEReplayEvent struct {
+	Seq  int64
+	Data []byte
+}
+
+// SSEReplayStream 单个流的事件缓冲与订阅状态
+type SSEReplayStream struct {
+	ID       string
+	APIKeyID int64
+
+	mu sync.Mutex
+	// buf 环形缓冲，序号为 seq 的事件位于 buf[(seq-1)%len(buf)]，序号从 1 开始
+	buf      []SSEReplayEvent
+	lastSeq  int64
+	finished bool
+	// notify 每次追加事件或结束时关闭并替换，用于唤醒等待中的重连连接
+	notify chan struct{}
+
+	subscribers int
+	cancel      context.CancelFunc
+	graceTimer  *time.Timer
+	grace       time.Duration
+	retention   time.Duration
+	remove      func(streamID string)
+}
+
+// Append 追加一个事件并返回其序号
+func (st *SSEReplayStream) Append(data []byte) int64 {
+	st.mu.Lock()
+	defer st.mu.Unlock()
+	st.lastSeq++
+	st.buf[(st.lastSeq-1)%int64(len(st.buf))] = SSEReplayEvent{Seq: st.lastSeq, Data: append([]byte(nil), data...)}
+	st.wakeLocked()
+	return st.lastSeq
+}
+
+// Since 返回序号大于 afterSeq 的已缓冲事件、流是否已结束，以及有新事件时会被关闭的通知 channel。
+// afterSeq 之后的事件已被覆盖时返回 ErrSSEReplayEventExpired。
+func (st *SSEReplayStream) Since(afterSeq int64) ([]SSEReplayEvent, bool, <-chan struct{}, error) {
+	st.mu.Lock()
+	defer st.mu.Unlock()
+	oldest := st.lastSeq - int64(len(st.buf)) + 1
+	if oldest < 1 {
+		oldest = 1
+	}
+	if afterSeq+1 < oldest || afterSeq > st.lastSeq {
+		return nil, st.finished, st.notify, ErrSSEReplayEventExpired
+	}
+	events := make([]SSEReplayEvent, 0, st.lastSeq-afterSeq)
+	for seq := afterSeq + 1; seq <= st.lastSeq; seq++ {
+		events = append(events, st.buf[(seq-1)%int64(len(st.buf))])
+	}
+	return events, st.finished, st.notify, nil
+}
+
+// Attach 登记一个重连订阅者，停止取消上游的倒计时
+func (st *SSEReplayStream) Attach() {
+	st.mu.Lock()
+	defer st.mu.Unlock()
+	st.subscribers++
+	if st.graceTimer != nil {
+		st.graceTimer.Stop()
+		st.graceTimer = nil
+	}
+}
+
+// Detach 注销一个订阅者（含原始连接断开）；流未结束且无人订阅时开始等待重连倒计时
+func (st *SSEReplayStream) Detach() {
+	st.mu.Lock()
+	defer st.mu.Unlock()
+	st.subscribers--
+	if st.subscribers > 0 || st.finished || st.graceTimer != nil {
+		return
+	}
+	st.graceTimer = time.AfterFunc(st.grace, func() {
+		st.mu.Lock()
+		idle := st.subscribers <= 0 && !st.finished
+		st.mu.Unlock()
+		if idle && st.cancel != nil {
+			st.cancel()
+		}
+	})
+}
+
+// Finish 标记流结束，缓冲在 retention_seconds 后释放
+func (st *SSEReplayStream) Finish() {
+	st.mu.Lock()
+	defer st.mu.Unlock()
+	if st.finished {
+		return
+	}
+	st.finished = true
+	if st.graceTimer != nil {
+		st.graceTimer.Stop()
+		st.graceTimer = nil
+	}
+	st.wakeLocked()
+	time.AfterFunc(st.retention, func() { st.remove(st.ID) })
+}
+
+func (st *SSEReplayStream) wakeLocked() {
+	close(st.notify)
+	st.notify = make(chan struct{})
+}
@@ -0,0 +1,75 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/ShaohongDong/sub2api/internal/config"
+	"github.com/stretchr/testify/require"
+)
+
+func newSSEReplayTestService(bufferEvents int) *SSEReplayService {
+	return NewSSEReplayService(&config.Config{SSEReplay: config.SSEReplayConfig{
+		Enabled: true, BufferEvents: bufferEvents, RetentionSeconds: 60, ReconnectGraceSeconds: 1,
+	}})
+}
+
+func TestParseSSEReplayEventID(t *testing.T) {
+	streamID, seq, ok := ParseSSEReplayEventID(SSEReplayEventID("sse_abc", 12))
+	require.True(t, ok)
+	require.Equal(t, "sse_abc", streamID)
+	require.Equal(t, int64(12), seq)
+
+	for _, id := range []string{"", "sse_abc", "abc:1", "sse_abc:x", "sse_abc:-1"} {
+		_, _, ok := ParseSSEReplayEventID(id)
+		require.False(t, ok, id)
+	}
+}
+
+func TestSSEReplayStream_RingBuffer(t *testing.T) {
+	svc := newSSEReplayTestService(2)
+	st := svc.Begin(1, func() {})
+	for _, data := range []string{"a", "b", "c"} {
+		st.Append([]byte(data))
+	}
+
+	events, finished, _, err := st.Since(1)
+	require.NoError(t, err)
+	require.False(t, finished)
+	require.Len(t, events, 2)
+	require.Equal(t, int64(2), events[0].Seq)
+	require.Equal(t, "c", string(events[1].Data))
+
+	// 序号 1 之后的事件已被覆盖
+	_, _, _, err = st.Since(0)
+	require.ErrorIs(t, err, ErrSSEReplayEventExpired)
+
+	got, err := svc.Get(1, st.ID)
+	require.NoError(t, err)
+	require.Same(t, st, got)
+	_, err = svc.Get(2, st.ID)
+	require.ErrorIs(t, err, ErrSSEReplayStreamNotFound)
+
+	_, _, notify, _ := st.Since(3)
+	st.Finish()
+	select {
+	case <-notify:
+	default:
+		t.Fatal("finish should wake subscribers")
+	}
+}
+
+func TestSSEReplayStream_CancelWithoutReconnect(t *testing.T) {
+	svc := newSSEReplayTestService(4)
+	ctx, cancel := context.WithCancel(context.Background())
+	st := svc.Begin(1, cancel)
+
+	// 重连后再断开：倒计时重新开始，无人重连时取消上游
+	st.Detach()
+	st.Attach()
+	time.Sleep(1200 * time.Millisecond)
+	require.NoError(t, ctx.Err())
+	st.Detach()
+	require.Eventually(t, func() bool { return ctx.Err() != nil }, 3*time.Second, 50*time.Millisecond)
+}
@@ -0,0 +1,78 @@
+package service
+
+import (
+	"github.com/ShaohongDong/sub2api/internal/config"
+	"github.com/ShaohongDong/sub2api/internal/pkg/apicompat"
+	"github.com/ShaohongDong/sub2api/internal/pkg/logger"
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+// structuredOutputAttempt 重新请求上游并返回转换后的 Responses 响应；返回 error 时停止重试并保留上一次结果。
+type structuredOutputAttempt func() (*apicompat.ResponsesResponse, error)
+
+// discardAnthropicCompatError 用于结构化输出重试：重试失败时保留上一次响应，不向客户端写错误。
+func discardAnthropicCompatError(*gin.Context, int, string, string) {}
+
+// discardOpenAICompatError 同 discardAnthropicCompatError，用于 OpenAI 上游。
+func discardOpenAICompatError(*gin.Context, int, string) {}
+
+func structuredOutputMaxRetries(cfg *config.Config) int {
+	if cfg == nil {
+		return 0
+	}
+	return cfg.Gateway.StructuredOutputMaxRetries
+}
+
+// validateStructuredOutputResponse 校验非流式响应是否满足请求的 text.format。
+// 未完成（截断/失败）或包含工具调用的响应不做校验。
+func validateStructuredOutputResponse(format *apicompat.ResponsesTextFormat, resp *apicompat.ResponsesResponse) error {
+	if !format.RequiresJSON() || resp == nil || resp.Status != "completed" {
+		return nil
+	}
+	for _, item := range resp.Output {
+		if item.Type == "function_call" {
+			return nil
+		}
+	}
+	return apicompat.ValidateStructuredOutput(apicompat.ResponsesOutputText(resp), format)
+}
+
+// retryInvalidStructuredOutput 在结构化输出校验失败时按配置次数重新请求上游，
+// 返回第一个通过校验的响应；重试耗尽或重试请求失败时返回最后一次拿到的响应。
+func retryInvalidStructuredOutput(
+	format *apicompat.ResponsesTextFormat,
+	maxRetries int,
+	resp *apicompat.ResponsesResponse,
+	attempt structuredOutputAttempt,
+	logPrefix string,
+) *apicompat.ResponsesResponse {
+	for retry := 0; ; retry++ {
+		err := validateStructuredOutputResponse(format, resp)
+		if err == nil {
+			return resp
+		}
+		if attempt == nil || retry >= maxRetries {
+			logger.L().Warn(logPrefix+": structured output failed validation",
+				zap.String("format", format.Type),
+				zap.Int("retries", retry),
+				zap.Error(err),
+			)
+			return resp
+		}
+		logger.L().Info(logPrefix+": structured output invalid, retrying",
+			zap.String("format", format.Type),
+			zap.Int("attempt", retry+1),
+			zap.Error(err),
+		)
+		next, attemptErr := attempt()
+		if attemptErr != nil {
+			logger.L().Warn(logPrefix+": structured output retry failed",
+				zap.Int("attempt", retry+1),
+				zap.Error(attemptErr),
+			)
+			return resp
+		}
+		resp = next
+	}
+}
@@ -0,0 +1,70 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"github.com/ShaohongDong/sub2api/internal/pkg/pagination"
+	"github.com/ShaohongDong/sub2api/internal/pkg/usagestats"
+)
+
+// Tenant 租户：一组用户及其专属分组（账号池）的隔离单元。
+// 用户只能使用同租户的分组，分组内的账号不与其他租户共享，用量统计可按租户汇总。
+type Tenant struct {
+	ID          int64
+	Name        string
+	Description string
+	Status      string
+	CreatedAt   time.Time
+	UpdatedAt   time.Time
+
+	UserCount  int64
+	GroupCount int64
+}
+
+func (t *Tenant) IsActive() bool {
+	return t.Status == StatusActive
+}
+
+// TenantMatches 判断两个租户归属是否一致（均未归属租户也视为一致）
+func TenantMatches(a, b *int64) bool {
+	if a == nil || b == nil {
+		return a == nil && b == nil
+	}
+	return *a == *b
+}
+
+type TenantListFilters struct {
+	Status string
+	Search string
+}
+
+// TenantStats 租户维度的资源数量与用量汇总
+type TenantStats struct {
+	TenantID    int64                  `json:"tenant_id"`
+	UserCount   int64                  `json:"user_count"`
+	GroupCount  int64                  `json:"group_count"`
+	APIKeyCount int64                  `json:"api_key_count"`
+	Usage       *usagestats.UsageStats `json:"usage"`
+}
+
+type TenantRepository interface {
+	Create(ctx context.Context, tenant *Tenant) error
+	GetByID(ctx context.Context, id int64) (*Tenant, error)
+	Update(ctx context.Context, tenant *Tenant) error
+	Delete(ctx context.Context, id int64) error
+	List(ctx context.Context, params pagination.PaginationParams, filters TenantListFilters) ([]Tenant, *pagination.PaginationResult, error)
+
+	// CountMembers 统计租户下的用户数、分组数与 API Key 数
+	CountMembers(ctx context.Context, tenantID int64) (users, groups, apiKeys int64, err error)
+	// AssignUsers 将用户归入租户（覆盖原有归属），返回实际更新的行数
+	AssignUsers(ctx context.Context, tenantID int64, userIDs []int64) (int64, error)
+	// RemoveUsers 将属于该租户的用户移出租户，返回实际更新的行数
+	RemoveUsers(ctx context.Context, tenantID int64, userIDs []int64) (int64, error)
+	// AssignGroups 将分组归入租户（覆盖原有归属），返回实际更新的行数
+	AssignGroups(ctx context.Context, tenantID int64, groupIDs []int64) (int64, error)
+	// RemoveGroups 将属于该租户的分组移出租户，返回实际更新的行数
+	RemoveGroups(ctx context.Context, tenantID int64, groupIDs []int64) (int64, error)
+	// ListSharedAccountIDs 返回 groupIDs 中同时绑定了归属不同于 tenantID（nil = 无租户）的分组的账号 ID，用于保证账号池隔离
+	ListSharedAccountIDs(ctx context.Context, tenantID *int64, groupIDs []int64) ([]int64, error)
+}
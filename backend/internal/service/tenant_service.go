@@ -0,0 +1,305 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	infraerrors "github.com/ShaohongDong/sub2api/internal/pkg/errors"
+	"github.com/ShaohongDong/sub2api/internal/pkg/pagination"
+	"github.com/ShaohongDong/sub2api/internal/pkg/usagestats"
+)
+
+var (
+	ErrTenantNotFound      = infraerrors.NotFound("TENANT_NOT_FOUND", "tenant not found")
+	ErrTenantExists        = infraerrors.Conflict("TENANT_EXISTS", "tenant name already exists")
+	ErrTenantInUse         = infraerrors.Conflict("TENANT_IN_USE", "tenant still has users or groups")
+	ErrTenantDisabled      = infraerrors.BadRequest("TENANT_DISABLED", "tenant is disabled")
+	ErrTenantMismatch      = infraerrors.Forbidden("TENANT_MISMATCH", "group belongs to a different tenant")
+	ErrTenantAccountShared = infraerrors.Conflict("TENANT_ACCOUNT_SHARED", "accounts in these groups are also bound to groups of another tenant")
+	ErrTenantGroupsMixed   = infraerrors.BadRequest("TENANT_GROUPS_MIXED", "an account cannot be bound to groups of different tenants")
+)
+
+const maxTenantAssignBatch = 1000
+
+type TenantService struct {
+	tenantRepo           TenantRepository
+	usageRepo            UsageLogRepository
+	authCacheInvalidator APIKeyAuthCacheInvalidator
+}
+
+func NewTenantService(tenantRepo TenantRepository, usageRepo UsageLogRepository, authCacheInvalidator APIKeyAuthCacheInvalidator) *TenantService {
+	return &TenantService{
+		tenantRepo:           tenantRepo,
+		usageRepo:            usageRepo,
+		authCacheInvalidator: authCacheInvalidator,
+	}
+}
+
+type CreateTenantInput struct {
+	Name        string
+	Description string
+	Status      string
+}
+
+type UpdateTenantInput struct {
+	Name        *string
+	Description *string
+	Status      *string
+}
+
+func (s *TenantService) List(ctx context.Context, params pagination.PaginationParams, filters TenantListFilters) ([]Tenant, *pagination.PaginationResult, error) {
+	return s.tenantRepo.List(ctx, params, filters)
+}
+
+func (s *TenantService) GetByID(ctx context.Context, id int64) (*Tenant, error) {
+	return s.tenantRepo.GetByID(ctx, id)
+}
+
+func (s *TenantService) Create(ctx context.Context, input *CreateTenantInput) (*Tenant, error) {
+	if input == nil {
+		return nil, fmt.Errorf("create tenant: nil input")
+	}
+	name := strings.TrimSpace(input.Name)
+	if name == "" || len([]rune(name)) > 100 {
+		return nil, infraerrors.BadRequest("INVALID_TENANT_NAME", "tenant name must be 1-100 characters")
+	}
+	status := strings.TrimSpace(input.Status)
+	if status == "" {
+		status = StatusActive
+	}
+	if !isValidTenantStatus(status) {
+		return nil, infraerrors.BadRequest("INVALID_TENANT_STATUS", "tenant status must be active or disabled")
+	}
+
+	tenant := &Tenant{
+		Name:        name,
+		Description: strings.TrimSpace(input.Description),
+		Status:      status,
+	}
+	if err := s.tenantRepo.Create(ctx, tenant); err != nil {
+		return nil, err
+	}
+	return tenant, nil
+}
+
+func (s *TenantService) Update(ctx context.Context, id int64, input *UpdateTenantInput) (*Tenant, error) {
+	if input == nil {
+		return nil, fmt.Errorf("update tenant: nil input")
+	}
+	tenant, err := s.tenantRepo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if input.Name != nil {
+		name := strings.TrimSpace(*input.Name)
+		if name == "" || len([]rune(name)) > 100 {
+			return nil, infraerrors.BadRequest("INVALID_TENANT_NAME", "tenant name must be 1-100 characters")
+		}
+		tenant.Name = name
+	}
+	if input.Description != nil {
+		tenant.Description = strings.TrimSpace(*input.Description)
+	}
+	if input.Status != nil {
+		status := strings.TrimSpace(*input.Status)
+		if !isValidTenantStatus(status) {
+			return nil, infraerrors.BadRequest("INVALID_TENANT_STATUS", "tenant status must be active or disabled")
+		}
+		tenant.Status = status
+	}
+	if err := s.tenantRepo.Update(ctx, tenant); err != nil {
+		return nil, err
+	}
+	return tenant, nil
+}
+
+// Delete 删除租户；租户下仍有用户或分组时拒绝删除，避免成员意外回落到"无租户"的共享池
+func (s *TenantService) Delete(ctx context.Context, id int64) error {
+	if _, err := s.tenantRepo.GetByID(ctx, id); err != nil {
+		return err
+	}
+	users, groups, _, err := s.tenantRepo.CountMembers(ctx, id)
+	if err != nil {
+		return fmt.Errorf("count tenant members: %w", err)
+	}
+	if users > 0 || groups > 0 {
+		return ErrTenantInUse.WithMetadata(map[string]string{
+			"users":  strconv.FormatInt(users, 10),
+			"groups": strconv.FormatInt(groups, 10),
+		})
+	}
+	return s.tenantRepo.Delete(ctx, id)
+}
+
+// AssignUsers 将用户归入租户（已属于其他租户的用户会被移入）。
+// 用户的 API Key 若绑定了其他租户的分组，将在网关鉴权时被拒绝。
+func (s *TenantService) AssignUsers(ctx context.Context, tenantID int64, userIDs []int64) (int64, error) {
+	userIDs, err := normalizeTenantMemberIDs(userIDs)
+	if err != nil || len(userIDs) == 0 {
+		return 0, err
+	}
+	if err := s.ensureAssignableTenant(ctx, tenantID); err != nil {
+		return 0, err
+	}
+	affected, err := s.tenantRepo.AssignUsers(ctx, tenantID, userIDs)
+	if err != nil {
+		return 0, fmt.Errorf("assign tenant users: %w", err)
+	}
+	s.invalidateUsers(ctx, userIDs)
+	return affected, nil
+}
+
+// RemoveUsers 将用户移出租户；不属于该租户的用户被忽略
+func (s *TenantService) RemoveUsers(ctx context.Context, tenantID int64, userIDs []int64) (int64, error) {
+	userIDs, err := normalizeTenantMemberIDs(userIDs)
+	if err != nil || len(userIDs) == 0 {
+		return 0, err
+	}
+	if _, err := s.tenantRepo.GetByID(ctx, tenantID); err != nil {
+		return 0, err
+	}
+	affected, err := s.tenantRepo.RemoveUsers(ctx, tenantID, userIDs)
+	if err != nil {
+		return 0, fmt.Errorf("remove tenant users: %w", err)
+	}
+	s.invalidateUsers(ctx, userIDs)
+	return affected, nil
+}
+
+// AssignGroups 将分组（及其账号池）归入租户。
+// 分组内的账号若同时绑定了归属不同的分组则拒绝，保证各租户账号池互不共享。
+func (s *TenantService) AssignGroups(ctx context.Context, tenantID int64, groupIDs []int64) (int64, error) {
+	groupIDs, err := normalizeTenantMemberIDs(groupIDs)
+	if err != nil || len(groupIDs) == 0 {
+		return 0, err
+	}
+	if err := s.ensureAssignableTenant(ctx, tenantID); err != nil {
+		return 0, err
+	}
+	if err := s.ensureNoSharedAccounts(ctx, &tenantID, groupIDs); err != nil {
+		return 0, err
+	}
+	affected, err := s.tenantRepo.AssignGroups(ctx, tenantID, groupIDs)
+	if err != nil {
+		return 0, fmt.Errorf("assign tenant groups: %w", err)
+	}
+	s.invalidateGroups(ctx, groupIDs)
+	return affected, nil
+}
+
+// RemoveGroups 将分组移出租户；不属于该租户的分组被忽略
+func (s *TenantService) RemoveGroups(ctx context.Context, tenantID int64, groupIDs []int64) (int64, error) {
+	groupIDs, err := normalizeTenantMemberIDs(groupIDs)
+	if err != nil || len(groupIDs) == 0 {
+		return 0, err
+	}
+	if _, err := s.tenantRepo.GetByID(ctx, tenantID); err != nil {
+		return 0, err
+	}
+	if err := s.ensureNoSharedAccounts(ctx, nil, groupIDs); err != nil {
+		return 0, err
+	}
+	affected, err := s.tenantRepo.RemoveGroups(ctx, tenantID, groupIDs)
+	if err != nil {
+		return 0, fmt.Errorf("remove tenant groups: %w", err)
+	}
+	s.invalidateGroups(ctx, groupIDs)
+	return affected, nil
+}
+
+// GetStats 返回租户的成员数量与时间范围内的用量汇总
+func (s *TenantService) GetStats(ctx context.Context, tenantID int64, startTime, endTime *time.Time) (*TenantStats, error) {
+	if _, err := s.tenantRepo.GetByID(ctx, tenantID); err != nil {
+		return nil, err
+	}
+	users, groups, apiKeys, err := s.tenantRepo.CountMembers(ctx, tenantID)
+	if err != nil {
+		return nil, fmt.Errorf("count tenant members: %w", err)
+	}
+	usage, err := s.usageRepo.GetStatsWithFilters(ctx, usagestats.UsageLogFilters{
+		TenantID:  tenantID,
+		StartTime: startTime,
+		EndTime:   endTime,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("get tenant usage stats: %w", err)
+	}
+	return &TenantStats{
+		TenantID:    tenantID,
+		UserCount:   users,
+		GroupCount:  groups,
+		APIKeyCount: apiKeys,
+		Usage:       usage,
+	}, nil
+}
+
+func (s *TenantService) ensureAssignableTenant(ctx context.Context, tenantID int64) error {
+	tenant, err := s.tenantRepo.GetByID(ctx, tenantID)
+	if err != nil {
+		return err
+	}
+	if !tenant.IsActive() {
+		return ErrTenantDisabled
+	}
+	return nil
+}
+
+func (s *TenantService) ensureNoSharedAccounts(ctx context.Context, tenantID *int64, groupIDs []int64) error {
+	shared, err := s.tenantRepo.ListSharedAccountIDs(ctx, tenantID, groupIDs)
+	if err != nil {
+		return fmt.Errorf("check shared accounts: %w", err)
+	}
+	if len(shared) == 0 {
+		return nil
+	}
+	ids := make([]string, 0, len(shared))
+	for _, id := range shared {
+		ids = append(ids, strconv.FormatInt(id, 10))
+	}
+	return ErrTenantAccountShared.WithMetadata(map[string]string{"account_ids": strings.Join(ids, ",")})
+}
+
+func (s *TenantService) invalidateUsers(ctx context.Context, userIDs []int64) {
+	if s.authCacheInvalidator == nil {
+		return
+	}
+	for _, userID := range userIDs {
+		s.authCacheInvalidator.InvalidateAuthCacheByUserID(ctx, userID)
+	}
+}
+
+func (s *TenantService) invalidateGroups(ctx context.Context, groupIDs []int64) {
+	if s.authCacheInvalidator == nil {
+		return
+	}
+	for _, groupID := range groupIDs {
+		s.authCacheInvalidator.InvalidateAuthCacheByGroupID(ctx, groupID)
+	}
+}
+
+func isValidTenantStatus(status string) bool {
+	return status == StatusActive || status == StatusDisabled
+}
+
+// normalizeTenantMemberIDs 去重并丢弃非正数 ID，限制单次操作数量
+func normalizeTenantMemberIDs(ids []int64) ([]int64, error) {
+	if len(ids) > maxTenantAssignBatch {
+		return nil, infraerrors.BadRequest("TOO_MANY_IDS", fmt.Sprintf("at most %d ids per request", maxTenantAssignBatch))
+	}
+	seen := make(map[int64]struct{}, len(ids))
+	out := make([]int64, 0, len(ids))
+	for _, id := range ids {
+		if id <= 0 {
+			continue
+		}
+		if _, ok := seen[id]; ok {
+			continue
+		}
+		seen[id] = struct{}{}
+		out = append(out, id)
+	}
+	return out, nil
+}
@@ -0,0 +1,216 @@
+//go:build unit
+
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	infraerrors "github.com/ShaohongDong/sub2api/internal/pkg/errors"
+	"github.com/ShaohongDong/sub2api/internal/pkg/usagestats"
+	"github.com/stretchr/testify/require"
+)
+
+type tenantRepoStub struct {
+	TenantRepository
+
+	tenants      map[int64]*Tenant
+	users        int64
+	groups       int64
+	apiKeys      int64
+	shared       []int64
+	sharedTarget *int64
+	assigned     []int64
+	removed      []int64
+	deleted      int64
+}
+
+func (s *tenantRepoStub) GetByID(ctx context.Context, id int64) (*Tenant, error) {
+	t, ok := s.tenants[id]
+	if !ok {
+		return nil, ErrTenantNotFound
+	}
+	clone := *t
+	return &clone, nil
+}
+
+func (s *tenantRepoStub) Delete(ctx context.Context, id int64) error {
+	s.deleted = id
+	return nil
+}
+
+func (s *tenantRepoStub) CountMembers(ctx context.Context, tenantID int64) (int64, int64, int64, error) {
+	return s.users, s.groups, s.apiKeys, nil
+}
+
+func (s *tenantRepoStub) AssignUsers(ctx context.Context, tenantID int64, userIDs []int64) (int64, error) {
+	s.assigned = userIDs
+	return int64(len(userIDs)), nil
+}
+
+func (s *tenantRepoStub) AssignGroups(ctx context.Context, tenantID int64, groupIDs []int64) (int64, error) {
+	s.assigned = groupIDs
+	return int64(len(groupIDs)), nil
+}
+
+func (s *tenantRepoStub) RemoveGroups(ctx context.Context, tenantID int64, groupIDs []int64) (int64, error) {
+	s.removed = groupIDs
+	return int64(len(groupIDs)), nil
+}
+
+func (s *tenantRepoStub) ListSharedAccountIDs(ctx context.Context, tenantID *int64, groupIDs []int64) ([]int64, error) {
+	s.sharedTarget = tenantID
+	return s.shared, nil
+}
+
+type tenantUsageRepoStub struct {
+	UsageLogRepository
+
+	filters usagestats.UsageLogFilters
+}
+
+func (s *tenantUsageRepoStub) GetStatsWithFilters(ctx context.Context, filters usagestats.UsageLogFilters) (*usagestats.UsageStats, error) {
+	s.filters = filters
+	return &usagestats.UsageStats{TotalRequests: 9, TotalActualCost: 1.5}, nil
+}
+
+type tenantAuthCacheInvalidatorStub struct {
+	userIDs  []int64
+	groupIDs []int64
+}
+
+func (s *tenantAuthCacheInvalidatorStub) InvalidateAuthCacheByKey(ctx context.Context, key string) {}
+
+func (s *tenantAuthCacheInvalidatorStub) InvalidateAuthCacheByUserID(ctx context.Context, userID int64) {
+	s.userIDs = append(s.userIDs, userID)
+}
+
+func (s *tenantAuthCacheInvalidatorStub) InvalidateAuthCacheByGroupID(ctx context.Context, groupID int64) {
+	s.groupIDs = append(s.groupIDs, groupID)
+}
+
+func newTenantRepoStub() *tenantRepoStub {
+	return &tenantRepoStub{tenants: map[int64]*Tenant{
+		1: {ID: 1, Name: "team-a", Status: StatusActive},
+		2: {ID: 2, Name: "team-b", Status: StatusDisabled},
+	}}
+}
+
+func TestTenantMatches(t *testing.T) {
+	a, a2, b := int64(1), int64(1), int64(2)
+	require.True(t, TenantMatches(nil, nil))
+	require.True(t, TenantMatches(&a, &a2))
+	require.False(t, TenantMatches(&a, &b))
+	require.False(t, TenantMatches(&a, nil))
+	require.False(t, TenantMatches(nil, &b))
+}
+
+func TestTenantService_DeleteRejectsTenantInUse(t *testing.T) {
+	repo := newTenantRepoStub()
+	repo.users, repo.groups = 3, 1
+	svc := NewTenantService(repo, nil, nil)
+
+	err := svc.Delete(context.Background(), 1)
+	require.ErrorIs(t, err, ErrTenantInUse)
+	require.Equal(t, "3", infraerrors.FromError(err).Metadata["users"])
+	require.Zero(t, repo.deleted)
+
+	repo.users, repo.groups = 0, 0
+	require.NoError(t, svc.Delete(context.Background(), 1))
+	require.Equal(t, int64(1), repo.deleted)
+}
+
+func TestTenantService_AssignUsersNormalizesAndInvalidates(t *testing.T) {
+	repo := newTenantRepoStub()
+	invalidator := &tenantAuthCacheInvalidatorStub{}
+	svc := NewTenantService(repo, nil, invalidator)
+
+	affected, err := svc.AssignUsers(context.Background(), 1, []int64{5, 0, 5, -1, 7})
+	require.NoError(t, err)
+	require.Equal(t, int64(2), affected)
+	require.Equal(t, []int64{5, 7}, repo.assigned)
+	require.Equal(t, []int64{5, 7}, invalidator.userIDs)
+}
+
+func TestTenantService_AssignRejectsDisabledOrMissingTenant(t *testing.T) {
+	repo := newTenantRepoStub()
+	svc := NewTenantService(repo, nil, nil)
+
+	_, err := svc.AssignUsers(context.Background(), 2, []int64{5})
+	require.ErrorIs(t, err, ErrTenantDisabled)
+
+	_, err = svc.AssignGroups(context.Background(), 99, []int64{5})
+	require.ErrorIs(t, err, ErrTenantNotFound)
+	require.Nil(t, repo.assigned)
+}
+
+func TestTenantService_AssignGroupsRejectsSharedAccounts(t *testing.T) {
+	repo := newTenantRepoStub()
+	repo.shared = []int64{31, 32}
+	invalidator := &tenantAuthCacheInvalidatorStub{}
+	svc := NewTenantService(repo, nil, invalidator)
+
+	_, err := svc.AssignGroups(context.Background(), 1, []int64{10, 11})
+	require.ErrorIs(t, err, ErrTenantAccountShared)
+	require.Equal(t, "31,32", infraerrors.FromError(err).Metadata["account_ids"])
+	require.Equal(t, int64(1), *repo.sharedTarget)
+	require.Nil(t, repo.assigned)
+	require.Empty(t, invalidator.groupIDs)
+
+	repo.shared = nil
+	affected, err := svc.AssignGroups(context.Background(), 1, []int64{10, 11})
+	require.NoError(t, err)
+	require.Equal(t, int64(2), affected)
+	require.Equal(t, []int64{10, 11}, invalidator.groupIDs)
+}
+
+func TestTenantService_RemoveGroupsChecksUnassignedPool(t *testing.T) {
+	repo := newTenantRepoStub()
+	svc := NewTenantService(repo, nil, nil)
+
+	_, err := svc.RemoveGroups(context.Background(), 1, []int64{10})
+	require.NoError(t, err)
+	require.Nil(t, repo.sharedTarget, "removed groups join the unassigned pool")
+	require.Equal(t, []int64{10}, repo.removed)
+}
+
+func TestTenantService_AssignRejectsOversizedBatch(t *testing.T) {
+	svc := NewTenantService(newTenantRepoStub(), nil, nil)
+
+	ids := make([]int64, maxTenantAssignBatch+1)
+	for i := range ids {
+		ids[i] = int64(i + 1)
+	}
+	_, err := svc.AssignUsers(context.Background(), 1, ids)
+	require.True(t, infraerrors.IsBadRequest(err))
+}
+
+func TestTenantService_GetStatsFiltersUsageByTenant(t *testing.T) {
+	repo := newTenantRepoStub()
+	repo.users, repo.groups, repo.apiKeys = 4, 2, 6
+	usageRepo := &tenantUsageRepoStub{}
+	svc := NewTenantService(repo, usageRepo, nil)
+
+	start := time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)
+	stats, err := svc.GetStats(context.Background(), 1, &start, nil)
+	require.NoError(t, err)
+	require.Equal(t, int64(1), usageRepo.filters.TenantID)
+	require.Equal(t, &start, usageRepo.filters.StartTime)
+	require.Nil(t, usageRepo.filters.EndTime)
+	require.Equal(t, int64(4), stats.UserCount)
+	require.Equal(t, int64(2), stats.GroupCount)
+	require.Equal(t, int64(6), stats.APIKeyCount)
+	require.Equal(t, int64(9), stats.Usage.TotalRequests)
+}
+
+func TestAPIKeyService_CanUserBindGroupRequiresSameTenant(t *testing.T) {
+	tenantA, tenantB := int64(1), int64(2)
+	svc := &APIKeyService{}
+	user := &User{ID: 1, TenantID: &tenantA}
+
+	require.True(t, svc.canUserBindGroup(context.Background(), user, &Group{ID: 10, TenantID: &tenantA}))
+	require.False(t, svc.canUserBindGroup(context.Background(), user, &Group{ID: 11, TenantID: &tenantB}))
+	require.False(t, svc.canUserBindGroup(context.Background(), user, &Group{ID: 12}))
+	require.True(t, svc.canUserBindGroup(context.Background(), &User{ID: 2}, &Group{ID: 12}))
+}
@@ -0,0 +1,174 @@
+package service
+
+import (
+	"strings"
+
+	"github.com/ShaohongDong/sub2api/internal/pkg/tokenizer"
+	"github.com/tidwall/gjson"
+)
+
+// 本地 token 计数：用于不支持上游 count_tokens 的平台，以及上游未返回 usage 时的用量估算。
+// 文本按 tokenizer 编码精确计数；图片等非文本内容按固定值估算。
+const (
+	// tokenCountPerMessage 每条消息的角色/分隔符开销（OpenAI Chat 格式约 3 token）
+	tokenCountPerMessage = 3
+	// tokenCountReplyPriming 回复起始标记开销
+	tokenCountReplyPriming = 3
+	// tokenCountImage 未知尺寸图片的估算值（约为 Anthropic 1092x1092 / OpenAI high detail 的量级）
+	tokenCountImage = 1600
+	// tokenCountImageLowDetail OpenAI detail=low 图片固定 85 token
+	tokenCountImageLowDetail = 85
+)
+
+// CountAnthropicRequestTokens 估算 Anthropic Messages 请求（/v1/messages、/v1/messages/count_tokens）的输入 token 数，
+// 包含 system、messages 与 tools 定义。
+func CountAnthropicRequestTokens(body []byte) int {
+	model := gjson.GetBytes(body, "model").String()
+	total := 0
+	system := gjson.GetBytes(body, "system")
+	if system.Exists() {
+		total += countAnthropicContentTokens(model, system)
+	}
+	gjson.GetBytes(body, "messages").ForEach(func(_, msg gjson.Result) bool {
+		total += tokenCountPerMessage + countAnthropicContentTokens(model, msg.Get("content"))
+		return true
+	})
+	total += countToolDefinitionTokens(model, gjson.GetBytes(body, "tools"))
+	if total > 0 {
+		total += tokenCountReplyPriming
+	}
+	return total
+}
+
+func countAnthropicContentTokens(model string, content gjson.Result) int {
+	if content.Type == gjson.String {
+		return tokenizer.Count(model, content.String())
+	}
+	total := 0
+	content.ForEach(func(_, block gjson.Result) bool {
+		switch block.Get("type").String() {
+		case "text":
+			total += tokenizer.Count(model, block.Get("text").String())
+		case "thinking":
+			total += tokenizer.Count(model, block.Get("thinking").String())
+		case "tool_use", "server_tool_use":
+			total += tokenizer.Count(model, block.Get("name").String()) + tokenizer.Count(model, block.Get("input").Raw)
+		case "tool_result":
+			total += countAnthropicContentTokens(model, block.Get("content"))
+		case "image":
+			total += tokenCountImage
+		case "document":
+			if block.Get("source.type").String() == "text" {
+				total += tokenizer.Count(model, block.Get("source.data").String())
+			} else {
+				total += tokenCountImage
+			}
+		}
+		return true
+	})
+	return total
+}
+
+// CountOpenAIRequestTokens 估算 OpenAI 格式请求的输入 token 数，按请求体字段自动识别：
+// Responses（input / instructions）、Chat Completions（messages）与旧版 Completions（prompt）。
+func CountOpenAIRequestTokens(model string, body []byte) int {
+	if model == "" {
+		model = gjson.GetBytes(body, "model").String()
+	}
+	total := 0
+	if instructions := gjson.GetBytes(body, "instructions"); instructions.Type == gjson.String {
+		total += tokenCountPerMessage + tokenizer.Count(model, instructions.String())
+	}
+	if input := gjson.GetBytes(body, "input"); input.Exists() {
+		total += countResponsesInputTokens(model, input)
+	}
+	gjson.GetBytes(body, "messages").ForEach(func(_, msg gjson.Result) bool {
+		total += tokenCountPerMessage + countOpenAIContentTokens(model, msg.Get("content"))
+		if name := msg.Get("name").String(); name != "" {
+			total += 1 + tokenizer.Count(model, name)
+		}
+		msg.Get("tool_calls").ForEach(func(_, call gjson.Result) bool {
+			total += tokenizer.Count(model, call.Get("function.name").String()) + tokenizer.Count(model, call.Get("function.arguments").String())
+			return true
+		})
+		return true
+	})
+	prompt := gjson.GetBytes(body, "prompt")
+	if prompt.Type == gjson.String {
+		total += tokenizer.Count(model, prompt.String())
+	} else {
+		prompt.ForEach(func(_, p gjson.Result) bool {
+			if p.Type == gjson.String {
+				total += tokenizer.Count(model, p.String())
+			}
+			return true
+		})
+	}
+	total += countToolDefinitionTokens(model, gjson.GetBytes(body, "tools"))
+	if total > 0 && !prompt.Exists() {
+		total += tokenCountReplyPriming
+	}
+	return total
+}
+
+func countResponsesInputTokens(model string, input gjson.Result) int {
+	if input.Type == gjson.String {
+		return tokenCountPerMessage + tokenizer.Count(model, input.String())
+	}
+	total := 0
+	input.ForEach(func(_, item gjson.Result) bool {
+		switch item.Get("type").String() {
+		case "function_call", "custom_tool_call":
+			total += tokenizer.Count(model, item.Get("name").String()) + tokenizer.Count(model, item.Get("arguments").String()) + tokenizer.Count(model, item.Get("input").String())
+		case "function_call_output", "custom_tool_call_output":
+			total += countOpenAIContentTokens(model, item.Get("output"))
+		case "reasoning":
+			item.Get("summary").ForEach(func(_, s gjson.Result) bool {
+				total += tokenizer.Count(model, s.Get("text").String())
+				return true
+			})
+		default:
+			total += tokenCountPerMessage + countOpenAIContentTokens(model, item.Get("content"))
+		}
+		return true
+	})
+	return total
+}
+
+// countOpenAIContentTokens 统计 Chat / Responses 消息内容：字符串或 text / image 内容分片数组。
+func countOpenAIContentTokens(model string, content gjson.Result) int {
+	if content.Type == gjson.String {
+		return tokenizer.Count(model, content.String())
+	}
+	total := 0
+	content.ForEach(func(_, part gjson.Result) bool {
+		switch part.Get("type").String() {
+		case "text", "input_text", "output_text", "refusal":
+			total += tokenizer.Count(model, part.Get("text").String()+part.Get("refusal").String())
+		case "image_url", "input_image":
+			detail := part.Get("detail").String()
+			if detail == "" {
+				detail = part.Get("image_url.detail").String()
+			}
+			if strings.EqualFold(detail, "low") {
+				total += tokenCountImageLowDetail
+			} else {
+				total += tokenCountImage
+			}
+		case "input_file", "file":
+			total += tokenCountImage
+		}
+		return true
+	})
+	return total
+}
+
+// countToolDefinitionTokens 按工具定义的 JSON 文本估算 tools 开销（Anthropic / Chat / Responses 通用）。
+func countToolDefinitionTokens(model string, tools gjson.Result) int {
+	total := 0
+	tools.ForEach(func(_, tool gjson.Result) bool {
+		total += tokenizer.Count(model, tool.Raw)
+		return true
+	})
+	return total
+}
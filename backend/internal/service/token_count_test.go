@@ -0,0 +1,48 @@
+package service
+
+import (
+	"testing"
+
+	"github.com/ShaohongDong/sub2api/internal/pkg/tokenizer"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCountAnthropicRequestTokens(t *testing.T) {
+	body := []byte(`{
+		"model":"claude-sonnet-4-5",
+		"system":"You are terse.",
+		"messages":[
+			{"role":"user","content":"hello world"},
+			{"role":"assistant","content":[{"type":"tool_use","id":"t1","name":"lookup","input":{"q":"x"}}]},
+			{"role":"user","content":[{"type":"tool_result","tool_use_id":"t1","content":[{"type":"text","text":"ok"}]},{"type":"image","source":{"type":"base64","media_type":"image/png","data":"AAAA"}}]}
+		]
+	}`)
+	want := tokenizer.Count("", "You are terse.") +
+		3 + tokenizer.Count("", "hello world") +
+		3 + tokenizer.Count("", "lookup") + tokenizer.Count("", `{"q":"x"}`) +
+		3 + tokenizer.Count("", "ok") + tokenCountImage +
+		tokenCountReplyPriming
+	require.Equal(t, want, CountAnthropicRequestTokens(body))
+
+	withTools := []byte(`{"model":"claude-sonnet-4-5","messages":[{"role":"user","content":"hello world"}],"tools":[{"name":"lookup","input_schema":{"type":"object"}}]}`)
+	require.Greater(t, CountAnthropicRequestTokens(withTools), 3+tokenizer.Count("", "hello world")+tokenCountReplyPriming)
+
+	require.Zero(t, CountAnthropicRequestTokens([]byte(`{"model":"x","messages":[]}`)))
+}
+
+func TestCountOpenAIRequestTokens(t *testing.T) {
+	chat := []byte(`{"model":"gpt-4o","messages":[{"role":"system","content":"be nice"},{"role":"user","content":[{"type":"text","text":"hello world"},{"type":"image_url","image_url":{"url":"https://x","detail":"low"}}]}]}`)
+	require.Equal(t,
+		3+tokenizer.Count("gpt-4o", "be nice")+3+tokenizer.Count("gpt-4o", "hello world")+tokenCountImageLowDetail+tokenCountReplyPriming,
+		CountOpenAIRequestTokens("", chat))
+
+	responses := []byte(`{"model":"gpt-5","instructions":"inst","input":[{"role":"user","content":[{"type":"input_text","text":"q"}]},{"type":"function_call","call_id":"c1","name":"f","arguments":"{}"},{"type":"function_call_output","call_id":"c1","output":"done"}]}`)
+	require.Equal(t,
+		3+tokenizer.Count("gpt-5", "inst")+3+tokenizer.Count("gpt-5", "q")+tokenizer.Count("gpt-5", "f")+tokenizer.Count("gpt-5", "{}")+tokenizer.Count("gpt-5", "done")+tokenCountReplyPriming,
+		CountOpenAIRequestTokens("", responses))
+
+	require.Equal(t, 3+tokenizer.Count("gpt-5", "hi")+tokenCountReplyPriming, CountOpenAIRequestTokens("gpt-5", []byte(`{"input":"hi"}`)))
+
+	completions := []byte(`{"model":"gpt-3.5-turbo-instruct","prompt":["one","two"]}`)
+	require.Equal(t, tokenizer.Count("gpt-3.5-turbo-instruct", "one")+tokenizer.Count("gpt-3.5-turbo-instruct", "two"), CountOpenAIRequestTokens("", completions))
+}
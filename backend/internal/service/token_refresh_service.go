@@ -3,7 +3,9 @@ package service
 import (
 	"context"
 	"fmt"
+	"hash/fnv"
 	"log/slog"
+	"strconv"
 	"strings"
 	"sync"
 	"time"
@@ -20,9 +22,18 @@ type TokenRefreshService struct {
 	cacheInvalidator TokenCacheInvalidator
 	schedulerCache   SchedulerCache   // 用于同步更新调度器缓存，解决 token 刷新后缓存不一致问题
 	tempUnschedCache TempUnschedCache // 用于清除 Redis 中的临时不可调度缓存
+	alertService     *AlertService
+	leader           *LeaderElectionService
 
 	stopCh chan struct{}
 	wg     sync.WaitGroup
+
+	// 到期前无法被下一轮巡检覆盖的账号按 expires_at 单独定时刷新
+	mu        sync.Mutex
+	stopped   bool
+	scheduled map[int64]*time.Timer
+	// inFlight 正在刷新的账号，避免巡检与定时刷新同时刷新同一账号
+	inFlight sync.Map
 }
 
 // NewTokenRefreshService 创建token刷新服务
@@ -44,6 +55,7 @@ func NewTokenRefreshService(
 		schedulerCache:   schedulerCache,
 		tempUnschedCache: tempUnschedCache,
 		stopCh:           make(chan struct{}),
+		scheduled:        make(map[int64]*time.Timer),
 	}
 
 	openAIRefresher := NewOpenAITokenRefresher(openaiOAuthService, accountRepo)
@@ -72,6 +84,37 @@ func (s *TokenRefreshService) SetSoraAccountRepo(repo SoraAccountRepository) {
 	}
 }
 
+// SetAlertService 设置运维告警服务（可选依赖）
+func (s *TokenRefreshService) SetAlertService(alertService *AlertService) {
+	s.alertService = alertService
+}
+
+// SetLeaderElection 设置集群领导者选举（可选依赖）；启用集群模式时只有领导者刷新 token
+func (s *TokenRefreshService) SetLeaderElection(leader *LeaderElectionService) {
+	s.leader = leader
+}
+
+// fireRefreshFailedAlert 刷新失败告警；disabled 表示账号已被标记为 error 状态
+func (s *TokenRefreshService) fireRefreshFailedAlert(account *Account, err error, disabled bool) {
+	severity := AlertSeverityWarning
+	if disabled {
+		severity = AlertSeverityCritical
+	}
+	s.alertService.Fire(context.Background(), &AlertEvent{
+		Type:     AlertEventTokenRefreshFailed,
+		Severity: severity,
+		Title:    fmt.Sprintf("Token refresh failed: %s (#%d)", account.Name, account.ID),
+		Message:  err.Error(),
+		Fields: map[string]any{
+			"account_id":   account.ID,
+			"account_name": account.Name,
+			"platform":     account.Platform,
+			"disabled":     disabled,
+		},
+		DedupKey: strconv.FormatInt(account.ID, 10),
+	})
+}
+
 // Start 启动后台刷新服务
 func (s *TokenRefreshService) Start() {
 	if !s.cfg.Enabled {
@@ -85,11 +128,19 @@ func (s *TokenRefreshService) Start() {
 	slog.Info("token_refresh.service_started",
 		"check_interval_minutes", s.cfg.CheckIntervalMinutes,
 		"refresh_before_expiry_hours", s.cfg.RefreshBeforeExpiryHours,
+		"jitter_seconds", s.cfg.JitterSeconds,
 	)
 }
 
 // Stop 停止刷新服务
 func (s *TokenRefreshService) Stop() {
+	s.mu.Lock()
+	s.stopped = true
+	for id, timer := range s.scheduled {
+		timer.Stop()
+		delete(s.scheduled, id)
+	}
+	s.mu.Unlock()
 	close(s.stopCh)
 	s.wg.Wait()
 	slog.Info("token_refresh.service_stopped")
@@ -99,13 +150,7 @@ func (s *TokenRefreshService) Stop() {
 func (s *TokenRefreshService) refreshLoop() {
 	defer s.wg.Done()
 
-	// 计算检查间隔
-	checkInterval := time.Duration(s.cfg.CheckIntervalMinutes) * time.Minute
-	if checkInterval < time.Minute {
-		checkInterval = 5 * time.Minute
-	}
-
-	ticker := time.NewTicker(checkInterval)
+	ticker := time.NewTicker(s.checkInterval())
 	defer ticker.Stop()
 
 	// 启动时立即执行一次检查
@@ -121,13 +166,35 @@ func (s *TokenRefreshService) refreshLoop() {
 	}
 }
 
+// checkInterval 巡检间隔
+func (s *TokenRefreshService) checkInterval() time.Duration {
+	checkInterval := time.Duration(s.cfg.CheckIntervalMinutes) * time.Minute
+	if checkInterval < time.Minute {
+		checkInterval = 5 * time.Minute
+	}
+	return checkInterval
+}
+
+// refreshWindow 返回账号的提前刷新窗口：refresh_before_expiry_hours 加上按账号固定的随机抖动，
+// 分散同一时间过期的账号的刷新时刻；抖动按账号 ID 哈希得出，保证各轮巡检判断一致。
+func (s *TokenRefreshService) refreshWindow(accountID int64) time.Duration {
+	window := time.Duration(s.cfg.RefreshBeforeExpiryHours * float64(time.Hour))
+	if s.cfg.JitterSeconds <= 0 {
+		return window
+	}
+	h := fnv.New32a()
+	_, _ = h.Write([]byte(strconv.FormatInt(accountID, 10)))
+	return window + time.Duration(h.Sum32()%uint32(s.cfg.JitterSeconds))*time.Second
+}
+
 // processRefresh 执行一次刷新检查
 func (s *TokenRefreshService) processRefresh() {
+	if !s.leader.IsLeader() {
+		slog.Debug("token_refresh.cycle_skipped_not_leader")
+		return
+	}
 	ctx := context.Background()
 
-	// 计算刷新窗口
-	refreshWindow := time.Duration(s.cfg.RefreshBeforeExpiryHours * float64(time.Hour))
-
 	// 获取所有active状态的账号
 	accounts, err := s.listActiveAccounts(ctx)
 	if err != nil {
@@ -152,14 +219,17 @@ func (s *TokenRefreshService) processRefresh() {
 			oauthAccounts++
 
 			// 检查是否需要刷新
+			refreshWindow := s.refreshWindow(account.ID)
 			if !refresher.NeedsRefresh(account, refreshWindow) {
-				break // 不需要刷新，跳过
+				// 不需要刷新：若刷新时刻早于下一轮巡检，按到期时间单独定时
+				s.scheduleRefresh(account, refreshWindow)
+				break
 			}
 
 			needsRefresh++
 
 			// 执行刷新
-			if err := s.refreshWithRetry(ctx, account, refresher); err != nil {
+			if err := s.refreshAccount(ctx, account, refresher); err != nil {
 				slog.Warn("token_refresh.account_refresh_failed",
 					"account_id", account.ID,
 					"account_name", account.Name,
@@ -195,6 +265,83 @@ func (s *TokenRefreshService) processRefresh() {
 	}
 }
 
+// scheduleRefresh 在账号进入刷新窗口时单独刷新，避免巡检间隔大于剩余刷新余量时 token 先过期
+func (s *TokenRefreshService) scheduleRefresh(account *Account, refreshWindow time.Duration) {
+	expiresAt := account.GetCredentialAsTime("expires_at")
+	if expiresAt == nil {
+		return
+	}
+	delay := time.Until(expiresAt.Add(-refreshWindow))
+	if delay <= 0 || delay >= s.checkInterval() {
+		return
+	}
+
+	accountID := account.ID
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.stopped {
+		return
+	}
+	if timer, ok := s.scheduled[accountID]; ok {
+		timer.Stop()
+	}
+	s.scheduled[accountID] = time.AfterFunc(delay, func() {
+		s.mu.Lock()
+		delete(s.scheduled, accountID)
+		if s.stopped {
+			s.mu.Unlock()
+			return
+		}
+		s.wg.Add(1)
+		s.mu.Unlock()
+		defer s.wg.Done()
+		s.refreshScheduled(accountID)
+	})
+}
+
+// refreshScheduled 定时刷新：重新加载账号，确认仍需刷新后执行
+func (s *TokenRefreshService) refreshScheduled(accountID int64) {
+	// 定时期间领导权可能已转移，由新领导者的巡检接管
+	if !s.leader.IsLeader() {
+		return
+	}
+	ctx := context.Background()
+	account, err := s.accountRepo.GetByID(ctx, accountID)
+	if err != nil || account == nil || !account.IsActive() {
+		return
+	}
+	for _, refresher := range s.refreshers {
+		if !refresher.CanRefresh(account) {
+			continue
+		}
+		if !refresher.NeedsRefresh(account, s.refreshWindow(account.ID)) {
+			return
+		}
+		if err := s.refreshAccount(ctx, account, refresher); err != nil {
+			slog.Warn("token_refresh.scheduled_refresh_failed",
+				"account_id", account.ID,
+				"account_name", account.Name,
+				"error", err,
+			)
+		} else {
+			slog.Info("token_refresh.scheduled_refreshed",
+				"account_id", account.ID,
+				"account_name", account.Name,
+			)
+		}
+		return
+	}
+}
+
+// refreshAccount 刷新单个账号，同一账号同时只允许一个刷新
+func (s *TokenRefreshService) refreshAccount(ctx context.Context, account *Account, refresher TokenRefresher) error {
+	if _, busy := s.inFlight.LoadOrStore(account.ID, struct{}{}); busy {
+		return nil
+	}
+	defer s.inFlight.Delete(account.ID)
+	return s.refreshWithRetry(ctx, account, refresher)
+}
+
 // listActiveAccounts 获取所有active状态的账号
 // 使用ListActive确保刷新所有活跃账号的token（包括临时禁用的）
 func (s *TokenRefreshService) listActiveAccounts(ctx context.Context) ([]Account, error) {
@@ -289,6 +436,7 @@ func (s *TokenRefreshService) refreshWithRetry(ctx context.Context, account *Acc
 					"error", setErr,
 				)
 			}
+			s.fireRefreshFailedAlert(account, err, true)
 			return err
 		}
 
@@ -304,7 +452,11 @@ func (s *TokenRefreshService) refreshWithRetry(ctx context.Context, account *Acc
 		if attempt < s.cfg.MaxRetries {
 			// 指数退避：2^(attempt-1) * baseSeconds
 			backoff := time.Duration(s.cfg.RetryBackoffSeconds) * time.Second * time.Duration(1<<(attempt-1))
-			time.Sleep(backoff)
+			select {
+			case <-time.After(backoff):
+			case <-s.stopCh:
+				return lastErr
+			}
 		}
 	}
 
@@ -315,6 +467,9 @@ func (s *TokenRefreshService) refreshWithRetry(ctx context.Context, account *Acc
 		"max_retries", s.cfg.MaxRetries,
 		"error", lastErr,
 	)
+	if lastErr != nil {
+		s.fireRefreshFailedAlert(account, lastErr, false)
+	}
 
 	return lastErr
 }
@@ -453,3 +453,75 @@ func TestIsNonRetryableRefreshError(t *testing.T) {
 		})
 	}
 }
+
+func TestTokenRefreshService_RefreshWindowJitter(t *testing.T) {
+	cfg := &config.Config{
+		TokenRefresh: config.TokenRefreshConfig{
+			RefreshBeforeExpiryHours: 0.5,
+			JitterSeconds:            120,
+		},
+	}
+	service := NewTokenRefreshService(&tokenRefreshAccountRepo{}, nil, nil, nil, nil, nil, nil, cfg, nil)
+
+	for id := int64(1); id <= 50; id++ {
+		window := service.refreshWindow(id)
+		require.GreaterOrEqual(t, window, 30*time.Minute)
+		require.Less(t, window, 32*time.Minute)
+		require.Equal(t, window, service.refreshWindow(id), "jitter must be stable per account")
+	}
+
+	cfg.TokenRefresh.JitterSeconds = 0
+	require.Equal(t, 30*time.Minute, service.refreshWindow(1))
+}
+
+func TestTokenRefreshService_ScheduleRefresh(t *testing.T) {
+	account := &Account{
+		ID:          9,
+		Platform:    PlatformGemini,
+		Type:        AccountTypeOAuth,
+		Status:      StatusActive,
+		Schedulable: true,
+		Credentials: map[string]any{
+			"expires_at": time.Now().Add(100 * time.Millisecond).Format(time.RFC3339Nano),
+		},
+	}
+	repo := &tokenRefreshAccountRepo{}
+	repo.accountsByID = map[int64]*Account{account.ID: account}
+	cfg := &config.Config{TokenRefresh: config.TokenRefreshConfig{MaxRetries: 1}}
+	service := NewTokenRefreshService(repo, nil, nil, nil, nil, nil, nil, cfg, nil)
+	service.refreshers = []TokenRefresher{&tokenRefresherStub{credentials: map[string]any{"access_token": "new-token"}}}
+
+	// 刷新时刻晚于下一轮巡检时交给巡检处理
+	far := &Account{ID: 10, Credentials: map[string]any{"expires_at": time.Now().Add(time.Hour).Format(time.RFC3339)}}
+	service.scheduleRefresh(far, 0)
+
+	service.scheduleRefresh(account, 0)
+	service.mu.Lock()
+	require.Len(t, service.scheduled, 1)
+	service.mu.Unlock()
+
+	require.Eventually(t, func() bool {
+		service.mu.Lock()
+		defer service.mu.Unlock()
+		return len(service.scheduled) == 0
+	}, 2*time.Second, 10*time.Millisecond)
+	service.Stop()
+	require.Equal(t, 1, repo.updateCalls)
+	require.Equal(t, "new-token", repo.lastAccount.GetCredential("access_token"))
+}
+
+func TestTokenRefreshService_StopCancelsScheduledRefresh(t *testing.T) {
+	repo := &tokenRefreshAccountRepo{}
+	cfg := &config.Config{TokenRefresh: config.TokenRefreshConfig{MaxRetries: 1}}
+	service := NewTokenRefreshService(repo, nil, nil, nil, nil, nil, nil, cfg, nil)
+	account := &Account{ID: 11, Credentials: map[string]any{"expires_at": time.Now().Add(time.Minute).Format(time.RFC3339)}}
+
+	service.scheduleRefresh(account, 0)
+	service.Stop()
+
+	// 停止后不再登记新的定时刷新
+	service.scheduleRefresh(account, 0)
+	service.mu.Lock()
+	defer service.mu.Unlock()
+	require.Empty(t, service.scheduled)
+}
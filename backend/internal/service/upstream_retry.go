@@ -0,0 +1,24 @@
+package service
+
+import (
+	"context"
+	"sync/atomic"
+
+	"github.com/ShaohongDong/sub2api/internal/pkg/ctxkey"
+)
+
+// UpstreamRetriesHeader 请求过程中发生上游重试时，返回给客户端的重试次数响应头
+const UpstreamRetriesHeader = "X-Sub2API-Upstream-Retries"
+
+// WithUpstreamRetryCounter 在请求上下文中挂载上游重试计数器（gateway.upstream_retry 每次重试时累加）
+func WithUpstreamRetryCounter(ctx context.Context) (context.Context, *atomic.Int32) {
+	counter := &atomic.Int32{}
+	return context.WithValue(ctx, ctxkey.RetryCount, counter), counter
+}
+
+// RecordUpstreamRetry 为请求上下文中的重试计数器加一；未挂载计数器时忽略
+func RecordUpstreamRetry(ctx context.Context) {
+	if counter, ok := ctx.Value(ctxkey.RetryCount).(*atomic.Int32); ok && counter != nil {
+		counter.Add(1)
+	}
+}
@@ -0,0 +1,197 @@
+package service
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+// upstreamTraceContextKey 上游调试追踪记录器在 context 中的 key
+type upstreamTraceContextKey struct{}
+
+// UpstreamExchange 一次上游 HTTP 往返的调试记录。
+// 请求头、URL 查询参数与 JSON 请求体中的凭证字段均已脱敏。
+type UpstreamExchange struct {
+	StartedAt            time.Time         `json:"started_at"`
+	Method               string            `json:"method"`
+	URL                  string            `json:"url"`
+	RequestHeaders       map[string]string `json:"request_headers,omitempty"`
+	RequestBody          string            `json:"request_body,omitempty"`
+	RequestBodyBytes     int               `json:"request_body_bytes"`
+	RequestBodyTruncated bool              `json:"request_body_truncated,omitempty"`
+
+	StatusCode        int               `json:"status_code,omitempty"`
+	ResponseHeaders   map[string]string `json:"response_headers,omitempty"`
+	ResponseBody      string            `json:"response_body,omitempty"`
+	ResponseTruncated bool              `json:"response_truncated,omitempty"`
+	// HeaderLatencyMs 从发出请求到收到响应头的耗时
+	HeaderLatencyMs int64  `json:"header_latency_ms"`
+	Error           string `json:"error,omitempty"`
+
+	responseBody []byte
+}
+
+// UpstreamTraceRecorder 收集单个调试请求期间发出的全部上游请求（含 HTTP 层重试），
+// 由 HTTPUpstream 实现在 context 中携带记录器时写入，正常网关流量不受影响。
+type UpstreamTraceRecorder struct {
+	mu        sync.Mutex
+	bodyLimit int
+	exchanges []*UpstreamExchange
+}
+
+// NewUpstreamTraceRecorder 创建记录器；bodyLimit 为单个请求/响应体保留的最大字节数
+func NewUpstreamTraceRecorder(bodyLimit int) *UpstreamTraceRecorder {
+	if bodyLimit <= 0 {
+		bodyLimit = opsRetryResponsePreviewMax
+	}
+	return &UpstreamTraceRecorder{bodyLimit: bodyLimit}
+}
+
+// WithUpstreamTrace 将记录器附加到 context
+func WithUpstreamTrace(ctx context.Context, rec *UpstreamTraceRecorder) context.Context {
+	if rec == nil {
+		return ctx
+	}
+	return context.WithValue(ctx, upstreamTraceContextKey{}, rec)
+}
+
+// UpstreamTraceFromContext 取出 context 中的记录器，未启用时返回 nil
+func UpstreamTraceFromContext(ctx context.Context) *UpstreamTraceRecorder {
+	if ctx == nil {
+		return nil
+	}
+	rec, _ := ctx.Value(upstreamTraceContextKey{}).(*UpstreamTraceRecorder)
+	return rec
+}
+
+// RecordRequest 在发出上游请求前记录请求信息，返回的 exchange 需交给 RecordResponse 补全
+func (r *UpstreamTraceRecorder) RecordRequest(req *http.Request) *UpstreamExchange {
+	if r == nil || req == nil {
+		return nil
+	}
+	ex := &UpstreamExchange{
+		StartedAt:      time.Now(),
+		Method:         req.Method,
+		RequestHeaders: redactTraceHeaders(req.Header),
+	}
+	if req.URL != nil {
+		ex.URL = redactTraceURL(req)
+	}
+	if req.GetBody != nil {
+		if body, err := req.GetBody(); err == nil && body != nil {
+			raw, _ := io.ReadAll(body)
+			_ = body.Close()
+			ex.RequestBody, ex.RequestBodyTruncated, ex.RequestBodyBytes = sanitizeAndTrimRequestBody(raw, r.bodyLimit)
+		}
+	}
+
+	r.mu.Lock()
+	r.exchanges = append(r.exchanges, ex)
+	r.mu.Unlock()
+	return ex
+}
+
+// RecordResponse 记录响应状态与响应头，并包装响应体以在调用方读取时留存前 bodyLimit 字节
+func (r *UpstreamTraceRecorder) RecordResponse(ex *UpstreamExchange, resp *http.Response, err error) {
+	if r == nil || ex == nil {
+		return
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	ex.HeaderLatencyMs = time.Since(ex.StartedAt).Milliseconds()
+	if err != nil {
+		ex.Error = err.Error()
+		return
+	}
+	if resp == nil {
+		return
+	}
+	ex.StatusCode = resp.StatusCode
+	ex.ResponseHeaders = redactTraceHeaders(resp.Header)
+	if resp.Body != nil {
+		resp.Body = &upstreamTraceBody{ReadCloser: resp.Body, rec: r, ex: ex}
+	}
+}
+
+// Exchanges 返回已记录上游往返的快照
+func (r *UpstreamTraceRecorder) Exchanges() []UpstreamExchange {
+	if r == nil {
+		return nil
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	out := make([]UpstreamExchange, 0, len(r.exchanges))
+	for _, ex := range r.exchanges {
+		cp := *ex
+		cp.ResponseBody = string(ex.responseBody)
+		cp.responseBody = nil
+		out = append(out, cp)
+	}
+	return out
+}
+
+type upstreamTraceBody struct {
+	io.ReadCloser
+	rec *UpstreamTraceRecorder
+	ex  *UpstreamExchange
+}
+
+func (b *upstreamTraceBody) Read(p []byte) (int, error) {
+	n, err := b.ReadCloser.Read(p)
+	if n > 0 {
+		b.rec.mu.Lock()
+		remaining := b.rec.bodyLimit - len(b.ex.responseBody)
+		switch {
+		case remaining >= n:
+			b.ex.responseBody = append(b.ex.responseBody, p[:n]...)
+		case remaining > 0:
+			b.ex.responseBody = append(b.ex.responseBody, p[:remaining]...)
+			b.ex.ResponseTruncated = true
+		default:
+			b.ex.ResponseTruncated = true
+		}
+		b.rec.mu.Unlock()
+	}
+	return n, err
+}
+
+func redactTraceHeaders(h http.Header) map[string]string {
+	if len(h) == 0 {
+		return nil
+	}
+	out := make(map[string]string, len(h))
+	for k, vals := range h {
+		v := strings.Join(vals, ", ")
+		if isSensitiveTraceName(k) {
+			v = redactAuthHeaderValue(v)
+		}
+		out[k] = v
+	}
+	return out
+}
+
+func redactTraceURL(req *http.Request) string {
+	u := *req.URL
+	if u.User != nil {
+		u.User = nil
+	}
+	if u.RawQuery != "" {
+		q := u.Query()
+		for k := range q {
+			if isSensitiveTraceName(k) {
+				q.Set(k, "[REDACTED]")
+			}
+		}
+		u.RawQuery = q.Encode()
+	}
+	return u.String()
+}
+
+// isSensitiveTraceName 在通用凭证字段判断之外，额外覆盖 x-goog-api-key 与 Gemini 的 ?key= 参数
+func isSensitiveTraceName(name string) bool {
+	k := strings.ToLower(strings.TrimSpace(name))
+	return k == "key" || strings.HasSuffix(k, "-key") || isSensitiveKey(k)
+}
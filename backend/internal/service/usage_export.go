@@ -0,0 +1,39 @@
+package service
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/ShaohongDong/sub2api/internal/pkg/usagestats"
+)
+
+// IsUsageExportDetail 报告导出是否为逐请求明细：未指定分组维度与时间桶时输出明细，否则输出聚合行
+func IsUsageExportDetail(query usagestats.UsageAggregateQuery) bool {
+	return len(query.GroupBy) == 0 && query.Granularity == ""
+}
+
+// ExportUsage 流式导出时间范围内的用量与成本（分摊对账）。
+// 明细模式逐条回调 onRow，聚合模式逐行回调 onAggregate（不受仪表盘聚合的行数上限约束）；
+// 结果不在内存中汇总，回调返回错误时停止导出。
+func (s *UsageService) ExportUsage(
+	ctx context.Context,
+	query usagestats.UsageAggregateQuery,
+	onAggregate func(*usagestats.UsageAggregate) error,
+	onRow func(*usagestats.UsageExportRow) error,
+) error {
+	query, err := normalizeUsageAggregateQuery(query)
+	if err != nil {
+		return err
+	}
+	query.Limit = 0
+
+	if IsUsageExportDetail(query) {
+		err = s.usageRepo.ForEachUsageExportRow(ctx, query, onRow)
+	} else {
+		err = s.usageRepo.ForEachUsageAggregate(ctx, query, onAggregate)
+	}
+	if err != nil {
+		return fmt.Errorf("export usage: %w", err)
+	}
+	return nil
+}
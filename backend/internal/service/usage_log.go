@@ -1,9 +1,13 @@
 package service
 
 import (
+	"context"
 	"fmt"
 	"strings"
 	"time"
+
+	"github.com/ShaohongDong/sub2api/internal/pkg/clientdetect"
+	"github.com/ShaohongDong/sub2api/internal/pkg/ctxkey"
 )
 
 const (
@@ -97,7 +101,10 @@ type UsageLog struct {
 	APIKeyID  int64
 	AccountID int64
 	RequestID string
-	Model     string
+	// GatewayRequestID is the gateway's own X-Request-ID for the request, used to
+	// join usage with logs, traces and error reports. RequestID is the upstream ID.
+	GatewayRequestID string
+	Model            string
 	// ServiceTier records the OpenAI service tier used for billing, e.g. "priority" / "flex".
 	ServiceTier *string
 	// ReasoningEffort is the request's reasoning effort level.
@@ -115,6 +122,8 @@ type UsageLog struct {
 
 	CacheCreation5mTokens int `gorm:"column:cache_creation_5m_tokens"`
 	CacheCreation1hTokens int `gorm:"column:cache_creation_1h_tokens"`
+	// ReasoningTokens 推理 token 数（已包含在 OutputTokens 中，仅用于统计）
+	ReasoningTokens int
 
 	InputCost         float64
 	OutputCost        float64
@@ -134,6 +143,9 @@ type UsageLog struct {
 	FirstTokenMs *int
 	UserAgent    *string
 	IPAddress    *string
+	// ClientType is the clientdetect.ClientType resolved on the live request path
+	// (UA plus originator / x-stainless-* headers). Empty for legacy rows.
+	ClientType string
 
 	// Cache TTL Override 标记（管理员强制替换了缓存 TTL 计费）
 	CacheTTLOverridden bool
@@ -174,3 +186,23 @@ func (u *UsageLog) SyncRequestTypeAndLegacyFields() {
 	u.RequestType = requestType
 	u.Stream, u.OpenAIWSMode = ApplyLegacyRequestFields(requestType, u.Stream, u.OpenAIWSMode)
 }
+
+// GatewayRequestIDFromContext returns the gateway request ID that RequestLogger
+// put in ctx, or "" when absent.
+func GatewayRequestIDFromContext(ctx context.Context) string {
+	if ctx == nil {
+		return ""
+	}
+	requestID, _ := ctx.Value(ctxkey.RequestID).(string)
+	return requestID
+}
+
+// ClientTypeFromContext returns the client type that ClientDetection put in
+// ctx, or "" when absent.
+func ClientTypeFromContext(ctx context.Context) string {
+	info, ok := clientdetect.FromContext(ctx)
+	if !ok {
+		return ""
+	}
+	return string(info.Type)
+}
@@ -0,0 +1,230 @@
+package service
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/ShaohongDong/sub2api/internal/config"
+)
+
+// UsageWebhookRecord 推送给外部计费系统的单条用量记录
+type UsageWebhookRecord struct {
+	// RequestID 上游请求 ID；GatewayRequestID 为网关自身的 X-Request-ID
+	RequestID           string    `json:"request_id"`
+	GatewayRequestID    string    `json:"gateway_request_id,omitempty"`
+	UserID              int64     `json:"user_id"`
+	APIKeyID            int64     `json:"api_key_id"`
+	AccountID           int64     `json:"account_id"`
+	GroupID             *int64    `json:"group_id,omitempty"`
+	Platform            string    `json:"platform,omitempty"`
+	Model               string    `json:"model"`
+	InputTokens         int       `json:"input_tokens"`
+	OutputTokens        int       `json:"output_tokens"`
+	CacheCreationTokens int       `json:"cache_creation_tokens"`
+	CacheReadTokens     int       `json:"cache_read_tokens"`
+	TotalCost           float64   `json:"total_cost"`
+	ActualCost          float64   `json:"actual_cost"`
+	LatencyMs           *int      `json:"latency_ms,omitempty"`
+	FirstTokenMs        *int      `json:"first_token_ms,omitempty"`
+	Stream              bool      `json:"stream"`
+	CreatedAt           time.Time `json:"created_at"`
+}
+
+// UsageWebhookSender 将一批用量记录推送到外部接收端
+type UsageWebhookSender interface {
+	SendUsageBatch(ctx context.Context, records []*UsageWebhookRecord) error
+}
+
+// UsageWebhookService 逐请求用量推送：请求完成后入队，后台按批量/间隔推送，失败按指数退避重试。
+// 入队不阻塞请求路径，队列满时丢弃并记录日志；重试耗尽的批次同样丢弃，用量仍以数据库记录为准。
+type UsageWebhookService struct {
+	sender UsageWebhookSender
+
+	queue         chan *UsageWebhookRecord
+	batchSize     int
+	flushInterval time.Duration
+	timeout       time.Duration
+	maxRetries    int
+	retryBackoff  time.Duration
+
+	ctx       context.Context
+	cancel    context.CancelFunc
+	wg        sync.WaitGroup
+	startOnce sync.Once
+	stopOnce  sync.Once
+
+	droppedCount uint64
+}
+
+// NewUsageWebhookService 创建用量推送服务；未启用 usage_webhook 或 sender 为 nil 时 Enqueue 为空操作
+func NewUsageWebhookService(cfg *config.Config, sender UsageWebhookSender) *UsageWebhookService {
+	ctx, cancel := context.WithCancel(context.Background())
+	s := &UsageWebhookService{ctx: ctx, cancel: cancel}
+	if cfg == nil || !cfg.UsageWebhook.Enabled || sender == nil {
+		return s
+	}
+	c := cfg.UsageWebhook
+	s.sender = sender
+	s.batchSize = max(c.BatchSize, 1)
+	s.queue = make(chan *UsageWebhookRecord, max(c.QueueSize, s.batchSize))
+	s.flushInterval = time.Duration(c.FlushIntervalMS) * time.Millisecond
+	if s.flushInterval <= 0 {
+		s.flushInterval = time.Second
+	}
+	s.timeout = time.Duration(c.TimeoutSeconds) * time.Second
+	if s.timeout <= 0 {
+		s.timeout = 10 * time.Second
+	}
+	s.maxRetries = max(c.MaxRetries, 0)
+	s.retryBackoff = time.Duration(max(c.RetryBackoffMS, 0)) * time.Millisecond
+	return s
+}
+
+// Enabled 是否配置了用量推送
+func (s *UsageWebhookService) Enabled() bool {
+	return s != nil && s.sender != nil
+}
+
+// Start 启动后台推送协程
+func (s *UsageWebhookService) Start() {
+	if !s.Enabled() {
+		return
+	}
+	s.startOnce.Do(func() {
+		s.wg.Add(1)
+		go s.run()
+	})
+}
+
+// Stop 停止后台协程并尽力推送队列中剩余的记录（不再重试）
+func (s *UsageWebhookService) Stop() {
+	if s == nil {
+		return
+	}
+	s.stopOnce.Do(func() {
+		s.cancel()
+		s.wg.Wait()
+	})
+}
+
+// DroppedCount 因队列满或重试耗尽而丢弃的记录数
+func (s *UsageWebhookService) DroppedCount() uint64 {
+	if s == nil {
+		return 0
+	}
+	return atomic.LoadUint64(&s.droppedCount)
+}
+
+// Enqueue 将一条已落库的使用记录加入推送队列
+func (s *UsageWebhookService) Enqueue(usageLog *UsageLog, platform string) {
+	if !s.Enabled() || usageLog == nil {
+		return
+	}
+	select {
+	case <-s.ctx.Done():
+		return
+	default:
+	}
+
+	record := newUsageWebhookRecord(usageLog, platform)
+	select {
+	case s.queue <- record:
+	default:
+		atomic.AddUint64(&s.droppedCount, 1)
+		slog.Warn("usage_webhook.dropped_queue_full", "request_id", record.RequestID, "api_key_id", record.APIKeyID)
+	}
+}
+
+func newUsageWebhookRecord(usageLog *UsageLog, platform string) *UsageWebhookRecord {
+	createdAt := usageLog.CreatedAt
+	if createdAt.IsZero() {
+		createdAt = time.Now()
+	}
+	return &UsageWebhookRecord{
+		RequestID:           usageLog.RequestID,
+		GatewayRequestID:    usageLog.GatewayRequestID,
+		UserID:              usageLog.UserID,
+		APIKeyID:            usageLog.APIKeyID,
+		AccountID:           usageLog.AccountID,
+		GroupID:             usageLog.GroupID,
+		Platform:            platform,
+		Model:               usageLog.Model,
+		InputTokens:         usageLog.InputTokens,
+		OutputTokens:        usageLog.OutputTokens,
+		CacheCreationTokens: usageLog.CacheCreationTokens,
+		CacheReadTokens:     usageLog.CacheReadTokens,
+		TotalCost:           usageLog.TotalCost,
+		ActualCost:          usageLog.ActualCost,
+		LatencyMs:           usageLog.DurationMs,
+		FirstTokenMs:        usageLog.FirstTokenMs,
+		Stream:              usageLog.Stream,
+		CreatedAt:           createdAt.UTC(),
+	}
+}
+
+func (s *UsageWebhookService) run() {
+	defer s.wg.Done()
+
+	ticker := time.NewTicker(s.flushInterval)
+	defer ticker.Stop()
+
+	batch := make([]*UsageWebhookRecord, 0, s.batchSize)
+	flush := func() {
+		if len(batch) == 0 {
+			return
+		}
+		s.sendWithRetry(batch)
+		batch = make([]*UsageWebhookRecord, 0, s.batchSize)
+	}
+
+	for {
+		select {
+		case <-s.ctx.Done():
+			for {
+				select {
+				case record := <-s.queue:
+					batch = append(batch, record)
+					if len(batch) >= s.batchSize {
+						flush()
+					}
+				default:
+					flush()
+					return
+				}
+			}
+		case record := <-s.queue:
+			batch = append(batch, record)
+			if len(batch) >= s.batchSize {
+				flush()
+			}
+		case <-ticker.C:
+			flush()
+		}
+	}
+}
+
+// sendWithRetry 推送一批记录，失败后按 retry_backoff_ms 起始的指数退避重试；服务停止后不再重试
+func (s *UsageWebhookService) sendWithRetry(batch []*UsageWebhookRecord) {
+	backoff := s.retryBackoff
+	for attempt := 0; ; attempt++ {
+		ctx, cancel := context.WithTimeout(context.Background(), s.timeout)
+		err := s.sender.SendUsageBatch(ctx, batch)
+		cancel()
+		if err == nil {
+			return
+		}
+		if attempt >= s.maxRetries || s.ctx.Err() != nil {
+			atomic.AddUint64(&s.droppedCount, uint64(len(batch)))
+			slog.Warn("usage_webhook.send_failed", "records", len(batch), "attempts", attempt+1, "error", err)
+			return
+		}
+		select {
+		case <-s.ctx.Done():
+		case <-time.After(backoff):
+		}
+		backoff *= 2
+	}
+}
@@ -0,0 +1,153 @@
+//go:build unit
+
+package service
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ShaohongDong/sub2api/internal/config"
+	"github.com/stretchr/testify/require"
+)
+
+type usageWebhookSenderStub struct {
+	mu       sync.Mutex
+	batches  [][]*UsageWebhookRecord
+	failures int
+	calls    int
+}
+
+func (s *usageWebhookSenderStub) SendUsageBatch(_ context.Context, records []*UsageWebhookRecord) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.calls++
+	if s.failures > 0 {
+		s.failures--
+		return errors.New("upstream unavailable")
+	}
+	s.batches = append(s.batches, records)
+	return nil
+}
+
+func (s *usageWebhookSenderStub) snapshot() (int, [][]*UsageWebhookRecord) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.calls, append([][]*UsageWebhookRecord(nil), s.batches...)
+}
+
+func newUsageWebhookTestConfig() *config.Config {
+	return &config.Config{UsageWebhook: config.UsageWebhookConfig{
+		Enabled:         true,
+		URL:             "http://billing.local/usage",
+		TimeoutSeconds:  1,
+		BatchSize:       2,
+		FlushIntervalMS: 20,
+		QueueSize:       10,
+		MaxRetries:      2,
+		RetryBackoffMS:  1,
+	}}
+}
+
+func TestUsageWebhookService_DisabledIsNoop(t *testing.T) {
+	var nilSvc *UsageWebhookService
+	nilSvc.Enqueue(&UsageLog{RequestID: "r"}, PlatformOpenAI)
+	nilSvc.Stop()
+
+	svc := NewUsageWebhookService(&config.Config{}, &usageWebhookSenderStub{})
+	require.False(t, svc.Enabled())
+	svc.Start()
+	svc.Enqueue(&UsageLog{RequestID: "r"}, PlatformOpenAI)
+	svc.Stop()
+}
+
+func TestUsageWebhookService_BatchesRecords(t *testing.T) {
+	sender := &usageWebhookSenderStub{}
+	svc := NewUsageWebhookService(newUsageWebhookTestConfig(), sender)
+	svc.Start()
+	defer svc.Stop()
+
+	duration := 850
+	groupID := int64(4)
+	for _, id := range []string{"r1", "r2", "r3"} {
+		svc.Enqueue(&UsageLog{
+			RequestID:    id,
+			UserID:       1,
+			APIKeyID:     2,
+			AccountID:    3,
+			GroupID:      &groupID,
+			Model:        "claude-sonnet-4-5",
+			InputTokens:  10,
+			OutputTokens: 5,
+			ActualCost:   0.5,
+			DurationMs:   &duration,
+		}, PlatformAnthropic)
+	}
+
+	require.Eventually(t, func() bool {
+		_, batches := sender.snapshot()
+		n := 0
+		for _, b := range batches {
+			n += len(b)
+		}
+		return n == 3
+	}, time.Second, 5*time.Millisecond)
+
+	_, batches := sender.snapshot()
+	require.Len(t, batches[0], 2, "full batch is sent without waiting for the interval")
+	record := batches[0][0]
+	require.Equal(t, "r1", record.RequestID)
+	require.Equal(t, PlatformAnthropic, record.Platform)
+	require.Equal(t, &groupID, record.GroupID)
+	require.Equal(t, &duration, record.LatencyMs)
+	require.False(t, record.CreatedAt.IsZero())
+}
+
+func TestUsageWebhookService_RetriesThenDrops(t *testing.T) {
+	sender := &usageWebhookSenderStub{failures: 2}
+	svc := NewUsageWebhookService(newUsageWebhookTestConfig(), sender)
+	svc.Start()
+	svc.Enqueue(&UsageLog{RequestID: "r1"}, PlatformOpenAI)
+	require.Eventually(t, func() bool {
+		_, batches := sender.snapshot()
+		return len(batches) == 1
+	}, time.Second, 5*time.Millisecond)
+	calls, _ := sender.snapshot()
+	require.Equal(t, 3, calls)
+	require.Zero(t, svc.DroppedCount())
+
+	sender.mu.Lock()
+	sender.failures = 10
+	sender.mu.Unlock()
+	svc.Enqueue(&UsageLog{RequestID: "r2"}, PlatformOpenAI)
+	require.Eventually(t, func() bool { return svc.DroppedCount() == 1 }, time.Second, 5*time.Millisecond)
+	calls, _ = sender.snapshot()
+	require.Equal(t, 6, calls)
+	svc.Stop()
+}
+
+func TestUsageWebhookService_DropsWhenQueueFullAndFlushesOnStop(t *testing.T) {
+	cfg := newUsageWebhookTestConfig()
+	cfg.UsageWebhook.BatchSize = 5
+	cfg.UsageWebhook.QueueSize = 5
+	cfg.UsageWebhook.FlushIntervalMS = 60_000
+	sender := &usageWebhookSenderStub{}
+	svc := NewUsageWebhookService(cfg, sender)
+
+	// 未启动时队列不被消费，可稳定触发队列满
+	for i := 0; i < 7; i++ {
+		svc.Enqueue(&UsageLog{RequestID: "r"}, PlatformOpenAI)
+	}
+	require.Equal(t, uint64(2), svc.DroppedCount())
+
+	svc.Start()
+	svc.Stop()
+	_, batches := sender.snapshot()
+	require.Len(t, batches, 1)
+	require.Len(t, batches[0], 5)
+
+	svc.Enqueue(&UsageLog{RequestID: "late"}, PlatformOpenAI)
+	require.Equal(t, uint64(2), svc.DroppedCount())
+}
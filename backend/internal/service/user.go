@@ -21,6 +21,9 @@ type User struct {
 	CreatedAt     time.Time
 	UpdatedAt     time.Time
 
+	// TenantID 所属租户（nil 表示未归属任何租户）
+	TenantID *int64
+
 	// GroupRates 用户专属分组倍率配置
 	// map[groupID]rateMultiplier
 	GroupRates map[int64]float64
@@ -42,6 +45,35 @@ func (u *User) IsAdmin() bool {
 	return u.Role == RoleAdmin
 }
 
+// HasAdminAccess 是否可访问管理后台（viewer / operator / admin）
+func (u *User) HasAdminAccess() bool {
+	return adminRoleLevel(u.Role) > 0
+}
+
+// AdminRoleAtLeast 判断 role 的管理权限是否不低于 required（viewer < operator < admin）
+func AdminRoleAtLeast(role, required string) bool {
+	level := adminRoleLevel(role)
+	return level > 0 && level >= adminRoleLevel(required)
+}
+
+// IsAssignableRole 管理员可通过角色接口授予的角色（admin 不可授予，避免权限扩散）
+func IsAssignableRole(role string) bool {
+	return role == RoleUser || role == RoleViewer || role == RoleOperator
+}
+
+func adminRoleLevel(role string) int {
+	switch role {
+	case RoleViewer:
+		return 1
+	case RoleOperator:
+		return 2
+	case RoleAdmin:
+		return 3
+	default:
+		return 0
+	}
+}
+
 func (u *User) IsActive() bool {
 	return u.Status == StatusActive
 }
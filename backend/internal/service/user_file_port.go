@@ -0,0 +1,50 @@
+package service
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	infraerrors "github.com/ShaohongDong/sub2api/internal/pkg/errors"
+)
+
+// Allowed upload purposes (OpenAI Files API compatible).
+const (
+	UserFilePurposeAssistants = "assistants"
+	UserFilePurposeBatch      = "batch"
+	UserFilePurposeUserData   = "user_data"
+	UserFilePurposeVision     = "vision"
+	UserFilePurposeEvals      = "evals"
+)
+
+var (
+	ErrUserFileNotFound     = infraerrors.NotFound("FILE_NOT_FOUND", "file not found")
+	ErrUserFilesDisabled    = infraerrors.ServiceUnavailable("FILES_DISABLED", "files api is disabled")
+	ErrUserFileTooLarge     = infraerrors.New(http.StatusRequestEntityTooLarge, "FILE_TOO_LARGE", "file exceeds the maximum allowed size")
+	ErrUserFileLimitReached = infraerrors.TooManyRequests("FILE_LIMIT_REACHED", "too many stored files, delete some before uploading")
+)
+
+// UserFile is the metadata of one uploaded file.
+type UserFile struct {
+	ID        string
+	UserID    int64
+	APIKeyID  int64
+	Filename  string
+	Purpose   string
+	MimeType  string
+	Bytes     int64
+	ExpiresAt *time.Time
+	CreatedAt time.Time
+}
+
+// UserFileRepository defines the data access interface for uploaded files.
+// Expired files are treated as missing by all read methods.
+type UserFileRepository interface {
+	Create(ctx context.Context, file *UserFile, content []byte) error
+	GetByID(ctx context.Context, userID int64, id string) (*UserFile, error)
+	GetContent(ctx context.Context, userID int64, id string) (*UserFile, []byte, error)
+	ListByUser(ctx context.Context, userID int64, purpose string, after string, limit int) ([]*UserFile, error)
+	CountByUser(ctx context.Context, userID int64) (int, error)
+	Delete(ctx context.Context, userID int64, id string) (bool, error)
+	DeleteExpired(ctx context.Context, now time.Time) (int64, error)
+}
@@ -0,0 +1,368 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"fmt"
+	"mime"
+	"net/http"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ShaohongDong/sub2api/internal/config"
+	infraerrors "github.com/ShaohongDong/sub2api/internal/pkg/errors"
+	"github.com/ShaohongDong/sub2api/internal/pkg/logger"
+	"github.com/google/uuid"
+	"github.com/tidwall/gjson"
+	"github.com/tidwall/sjson"
+)
+
+const (
+	userFileCleanupName        = "user_files_cleanup"
+	userFileMaxFilenameLength  = 255
+	userFileListDefaultLimit   = 20
+	userFileListMaxLimit       = 10000
+	userFileMinExpiresSeconds  = 3600
+	userFileMaxExpiresSeconds  = 30 * 24 * 3600
+	userFileDefaultContentType = "application/octet-stream"
+)
+
+// UserFileRefFormat 标识需要解析 file_id 引用的请求体格式。
+type UserFileRefFormat string
+
+const (
+	UserFileRefFormatResponses       UserFileRefFormat = "responses"
+	UserFileRefFormatChatCompletions UserFileRefFormat = "chat_completions"
+)
+
+var userFileAllowedPurposes = map[string]struct{}{
+	UserFilePurposeAssistants: {},
+	UserFilePurposeBatch:      {},
+	UserFilePurposeUserData:   {},
+	UserFilePurposeVision:     {},
+	UserFilePurposeEvals:      {},
+}
+
+// UploadUserFileInput 上传文件的参数。
+type UploadUserFileInput struct {
+	Filename string
+	Purpose  string
+	MimeType string
+	Data     []byte
+	// ExpiresAfterSeconds 为 0 时使用配置的默认保留时长
+	ExpiresAfterSeconds int64
+}
+
+// UserFileService 提供 /v1/files 存储，并将网关请求中的 file_id 引用
+// 展开为内联 base64 数据，使任意上游账号（API Key / OAuth）都能处理文件输入。
+type UserFileService struct {
+	repo        UserFileRepository
+	timingWheel *TimingWheelService
+	cfg         *config.Config
+
+	startOnce sync.Once
+	stopOnce  sync.Once
+}
+
+func NewUserFileService(repo UserFileRepository, timingWheel *TimingWheelService, cfg *config.Config) *UserFileService {
+	return &UserFileService{repo: repo, timingWheel: timingWheel, cfg: cfg}
+}
+
+// Enabled 是否启用文件接口。
+func (s *UserFileService) Enabled() bool {
+	return s != nil && s.cfg != nil && s.cfg.Files.Enabled
+}
+
+func (s *UserFileService) Start() {
+	if !s.Enabled() {
+		logger.LegacyPrintf("service.user_file", "[UserFile] cleanup not started (disabled)")
+		return
+	}
+	if s.repo == nil || s.timingWheel == nil {
+		logger.LegacyPrintf("service.user_file", "[UserFile] cleanup not started (missing deps)")
+		return
+	}
+	interval := time.Duration(s.cfg.Files.CleanupIntervalMinutes) * time.Minute
+	if interval <= 0 {
+		interval = time.Hour
+	}
+	s.startOnce.Do(func() {
+		s.timingWheel.ScheduleRecurring(userFileCleanupName, interval, s.cleanupExpired)
+		logger.LegacyPrintf("service.user_file", "[UserFile] cleanup started (interval=%s)", interval)
+	})
+}
+
+func (s *UserFileService) Stop() {
+	if s == nil {
+		return
+	}
+	s.stopOnce.Do(func() {
+		if s.timingWheel != nil {
+			s.timingWheel.Cancel(userFileCleanupName)
+		}
+		logger.LegacyPrintf("service.user_file", "[UserFile] cleanup stopped")
+	})
+}
+
+func (s *UserFileService) cleanupExpired() {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+	deleted, err := s.repo.DeleteExpired(ctx, time.Now())
+	if err != nil {
+		logger.LegacyPrintf("service.user_file", "[UserFile] cleanup failed: %v", err)
+		return
+	}
+	if deleted > 0 {
+		logger.LegacyPrintf("service.user_file", "[UserFile] cleanup removed %d expired files", deleted)
+	}
+}
+
+// Upload 保存上传的文件。
+func (s *UserFileService) Upload(ctx context.Context, apiKey *APIKey, in UploadUserFileInput) (*UserFile, error) {
+	if !s.Enabled() {
+		return nil, ErrUserFilesDisabled
+	}
+	if apiKey == nil {
+		return nil, infraerrors.Unauthorized("FILE_INVALID_API_KEY", "invalid api key")
+	}
+	purpose := strings.TrimSpace(in.Purpose)
+	if _, ok := userFileAllowedPurposes[purpose]; !ok {
+		return nil, infraerrors.BadRequest("FILE_INVALID_PURPOSE", "purpose must be one of assistants, batch, user_data, vision, evals")
+	}
+	filename := filepath.Base(strings.TrimSpace(in.Filename))
+	if filename == "" || filename == "." || filename == "/" {
+		return nil, infraerrors.BadRequest("FILE_INVALID_NAME", "filename is required")
+	}
+	if len(filename) > userFileMaxFilenameLength {
+		return nil, infraerrors.BadRequest("FILE_INVALID_NAME", "filename is too long")
+	}
+	if len(in.Data) == 0 {
+		return nil, infraerrors.BadRequest("FILE_EMPTY", "file is empty")
+	}
+	if int64(len(in.Data)) > s.cfg.Files.MaxFileSize {
+		return nil, ErrUserFileTooLarge
+	}
+	if in.ExpiresAfterSeconds != 0 && (in.ExpiresAfterSeconds < userFileMinExpiresSeconds || in.ExpiresAfterSeconds > userFileMaxExpiresSeconds) {
+		return nil, infraerrors.BadRequest("FILE_INVALID_EXPIRES_AFTER", "expires_after seconds must be between 3600 and 2592000")
+	}
+
+	if limit := s.cfg.Files.MaxFilesPerUser; limit > 0 {
+		count, err := s.repo.CountByUser(ctx, apiKey.UserID)
+		if err != nil {
+			return nil, fmt.Errorf("count files: %w", err)
+		}
+		if count >= limit {
+			return nil, ErrUserFileLimitReached
+		}
+	}
+
+	now := time.Now()
+	file := &UserFile{
+		ID:        "file-" + strings.ReplaceAll(uuid.NewString(), "-", ""),
+		UserID:    apiKey.UserID,
+		APIKeyID:  apiKey.ID,
+		Filename:  filename,
+		Purpose:   purpose,
+		MimeType:  detectUserFileMimeType(filename, in.MimeType, in.Data),
+		Bytes:     int64(len(in.Data)),
+		CreatedAt: now,
+	}
+	switch {
+	case in.ExpiresAfterSeconds > 0:
+		expiresAt := now.Add(time.Duration(in.ExpiresAfterSeconds) * time.Second)
+		file.ExpiresAt = &expiresAt
+	case s.cfg.Files.RetentionDays > 0:
+		expiresAt := now.AddDate(0, 0, s.cfg.Files.RetentionDays)
+		file.ExpiresAt = &expiresAt
+	}
+	if err := s.repo.Create(ctx, file, in.Data); err != nil {
+		return nil, fmt.Errorf("create file: %w", err)
+	}
+	return file, nil
+}
+
+// Get 返回文件元数据。
+func (s *UserFileService) Get(ctx context.Context, userID int64, id string) (*UserFile, error) {
+	if !s.Enabled() {
+		return nil, ErrUserFilesDisabled
+	}
+	return s.repo.GetByID(ctx, userID, id)
+}
+
+// GetContent 返回文件元数据及内容。
+func (s *UserFileService) GetContent(ctx context.Context, userID int64, id string) (*UserFile, []byte, error) {
+	if !s.Enabled() {
+		return nil, nil, ErrUserFilesDisabled
+	}
+	return s.repo.GetContent(ctx, userID, id)
+}
+
+// List 按创建时间倒序列出文件，after 为上一页最后一个文件 ID。
+func (s *UserFileService) List(ctx context.Context, userID int64, purpose string, after string, limit int) ([]*UserFile, error) {
+	if !s.Enabled() {
+		return nil, ErrUserFilesDisabled
+	}
+	if limit <= 0 {
+		limit = userFileListDefaultLimit
+	}
+	if limit > userFileListMaxLimit {
+		limit = userFileListMaxLimit
+	}
+	return s.repo.ListByUser(ctx, userID, purpose, after, limit)
+}
+
+// Delete 删除文件。
+func (s *UserFileService) Delete(ctx context.Context, userID int64, id string) error {
+	if !s.Enabled() {
+		return ErrUserFilesDisabled
+	}
+	deleted, err := s.repo.Delete(ctx, userID, id)
+	if err != nil {
+		return err
+	}
+	if !deleted {
+		return ErrUserFileNotFound
+	}
+	return nil
+}
+
+// userFileRef 请求体中的一处 file_id 引用。
+type userFileRef struct {
+	path   string // 内容块路径（sjson）
+	kind   string // input_file | input_image | chat_file
+	fileID string
+}
+
+// ResolveFileReferences 将请求体中引用本站文件的 file_id 展开为内联 base64：
+//   - Responses: input_file → file_data + filename，input_image → image_url（data URL）
+//   - Chat Completions: file.file_id → file.file_data + file.filename
+//
+// 未在本站找到的 file_id 原样保留（可能是上游账号自有的文件）。
+func (s *UserFileService) ResolveFileReferences(ctx context.Context, userID int64, format UserFileRefFormat, body []byte) ([]byte, error) {
+	if !s.Enabled() || !bytes.Contains(body, []byte(`"file_id"`)) {
+		return body, nil
+	}
+	refs := collectUserFileRefs(format, body)
+	if len(refs) == 0 {
+		return body, nil
+	}
+
+	type loaded struct {
+		file    *UserFile
+		dataURL string
+	}
+	cache := make(map[string]*loaded, len(refs))
+	out := body
+	for _, ref := range refs {
+		entry, seen := cache[ref.fileID]
+		if !seen {
+			file, content, err := s.repo.GetContent(ctx, userID, ref.fileID)
+			switch {
+			case err == nil:
+				entry = &loaded{file: file, dataURL: "data:" + file.MimeType + ";base64," + base64.StdEncoding.EncodeToString(content)}
+			case infraerrors.IsNotFound(err):
+				entry = nil
+			default:
+				return nil, fmt.Errorf("load file %s: %w", ref.fileID, err)
+			}
+			cache[ref.fileID] = entry
+		}
+		if entry == nil {
+			continue
+		}
+
+		var err error
+		switch ref.kind {
+		case "input_image":
+			if !strings.HasPrefix(entry.file.MimeType, "image/") {
+				return nil, infraerrors.Newf(http.StatusBadRequest, "FILE_NOT_IMAGE", "file %s is not an image", ref.fileID)
+			}
+			out, err = rewriteUserFileRef(out, ref.path, "file_id", map[string]string{"image_url": entry.dataURL})
+		case "input_file":
+			out, err = rewriteUserFileRef(out, ref.path, "file_id", map[string]string{"file_data": entry.dataURL, "filename": entry.file.Filename})
+		case "chat_file":
+			out, err = rewriteUserFileRef(out, ref.path+".file", "file_id", map[string]string{"file_data": entry.dataURL, "filename": entry.file.Filename})
+		}
+		if err != nil {
+			return nil, fmt.Errorf("rewrite file reference: %w", err)
+		}
+	}
+	return out, nil
+}
+
+func collectUserFileRefs(format UserFileRefFormat, body []byte) []userFileRef {
+	var refs []userFileRef
+	switch format {
+	case UserFileRefFormatResponses:
+		input := gjson.GetBytes(body, "input")
+		if !input.IsArray() {
+			return nil
+		}
+		input.ForEach(func(i, item gjson.Result) bool {
+			item.Get("content").ForEach(func(j, part gjson.Result) bool {
+				kind := part.Get("type").String()
+				fileID := part.Get("file_id").String()
+				if fileID != "" && (kind == "input_file" || kind == "input_image") {
+					refs = append(refs, userFileRef{path: "input." + i.String() + ".content." + j.String(), kind: kind, fileID: fileID})
+				}
+				return true
+			})
+			return true
+		})
+	case UserFileRefFormatChatCompletions:
+		gjson.GetBytes(body, "messages").ForEach(func(i, msg gjson.Result) bool {
+			content := msg.Get("content")
+			if !content.IsArray() {
+				return true
+			}
+			content.ForEach(func(j, part gjson.Result) bool {
+				fileID := part.Get("file.file_id").String()
+				if part.Get("type").String() == "file" && fileID != "" {
+					refs = append(refs, userFileRef{path: "messages." + i.String() + ".content." + j.String(), kind: "chat_file", fileID: fileID})
+				}
+				return true
+			})
+			return true
+		})
+	}
+	return refs
+}
+
+func rewriteUserFileRef(body []byte, path string, removeKey string, set map[string]string) ([]byte, error) {
+	out, err := sjson.DeleteBytes(body, path+"."+removeKey)
+	if err != nil {
+		return nil, err
+	}
+	for key, value := range set {
+		if key == "filename" && gjson.GetBytes(out, path+".filename").String() != "" {
+			continue
+		}
+		if out, err = sjson.SetBytes(out, path+"."+key, value); err != nil {
+			return nil, err
+		}
+	}
+	return out, nil
+}
+
+// detectUserFileMimeType 优先使用扩展名推断类型，其次为客户端声明的类型，最后嗅探内容。
+func detectUserFileMimeType(filename, declared string, data []byte) string {
+	if ext := strings.ToLower(filepath.Ext(filename)); ext != "" {
+		if t := mime.TypeByExtension(ext); t != "" {
+			if mt, _, err := mime.ParseMediaType(t); err == nil {
+				return mt
+			}
+		}
+	}
+	if declared = strings.TrimSpace(declared); declared != "" && declared != userFileDefaultContentType {
+		if mt, _, err := mime.ParseMediaType(declared); err == nil {
+			return mt
+		}
+	}
+	if mt, _, err := mime.ParseMediaType(http.DetectContentType(data)); err == nil {
+		return mt
+	}
+	return userFileDefaultContentType
+}
@@ -0,0 +1,82 @@
+package service
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/ShaohongDong/sub2api/internal/config"
+	infraerrors "github.com/ShaohongDong/sub2api/internal/pkg/errors"
+	"github.com/stretchr/testify/require"
+	"github.com/tidwall/gjson"
+)
+
+type userFileRepoStub struct {
+	UserFileRepository
+	files map[string]*UserFile
+	data  map[string][]byte
+}
+
+func (r *userFileRepoStub) GetContent(_ context.Context, userID int64, id string) (*UserFile, []byte, error) {
+	f, ok := r.files[id]
+	if !ok || f.UserID != userID {
+		return nil, nil, ErrUserFileNotFound
+	}
+	return f, r.data[id], nil
+}
+
+func newUserFileServiceForTest() *UserFileService {
+	repo := &userFileRepoStub{
+		files: map[string]*UserFile{
+			"file-pdf": {ID: "file-pdf", UserID: 1, Filename: "report.pdf", MimeType: "application/pdf"},
+			"file-png": {ID: "file-png", UserID: 1, Filename: "cat.png", MimeType: "image/png"},
+		},
+		data: map[string][]byte{"file-pdf": []byte("%PDF"), "file-png": []byte("PNG")},
+	}
+	return NewUserFileService(repo, nil, &config.Config{Files: config.FilesConfig{Enabled: true, MaxFileSize: 1 << 20}})
+}
+
+func TestUserFileService_ResolveFileReferences_Responses(t *testing.T) {
+	svc := newUserFileServiceForTest()
+	body := []byte(`{"model":"gpt-5","input":[{"role":"user","content":[{"type":"input_text","text":"hi"},{"type":"input_file","file_id":"file-pdf"},{"type":"input_image","file_id":"file-png"},{"type":"input_file","file_id":"file-upstream"}]}]}`)
+
+	out, err := svc.ResolveFileReferences(context.Background(), 1, UserFileRefFormatResponses, body)
+	require.NoError(t, err)
+	require.False(t, gjson.GetBytes(out, "input.0.content.1.file_id").Exists())
+	require.Equal(t, "data:application/pdf;base64,JVBERg==", gjson.GetBytes(out, "input.0.content.1.file_data").String())
+	require.Equal(t, "report.pdf", gjson.GetBytes(out, "input.0.content.1.filename").String())
+	require.Equal(t, "data:image/png;base64,UE5H", gjson.GetBytes(out, "input.0.content.2.image_url").String())
+	// 非本站文件保持原样
+	require.Equal(t, "file-upstream", gjson.GetBytes(out, "input.0.content.3.file_id").String())
+
+	// 其他用户的文件不会被解析
+	out, err = svc.ResolveFileReferences(context.Background(), 2, UserFileRefFormatResponses, body)
+	require.NoError(t, err)
+	require.Equal(t, string(body), string(out))
+}
+
+func TestUserFileService_ResolveFileReferences_ChatCompletions(t *testing.T) {
+	svc := newUserFileServiceForTest()
+	body := []byte(`{"model":"gpt-5","messages":[{"role":"user","content":[{"type":"file","file":{"file_id":"file-pdf","filename":"custom.pdf"}}]}]}`)
+
+	out, err := svc.ResolveFileReferences(context.Background(), 1, UserFileRefFormatChatCompletions, body)
+	require.NoError(t, err)
+	require.False(t, gjson.GetBytes(out, "messages.0.content.0.file.file_id").Exists())
+	require.Equal(t, "data:application/pdf;base64,JVBERg==", gjson.GetBytes(out, "messages.0.content.0.file.file_data").String())
+	require.Equal(t, "custom.pdf", gjson.GetBytes(out, "messages.0.content.0.file.filename").String())
+}
+
+func TestUserFileService_ResolveFileReferences_ImageTypeMismatch(t *testing.T) {
+	svc := newUserFileServiceForTest()
+	body := []byte(`{"input":[{"role":"user","content":[{"type":"input_image","file_id":"file-pdf"}]}]}`)
+
+	_, err := svc.ResolveFileReferences(context.Background(), 1, UserFileRefFormatResponses, body)
+	require.Error(t, err)
+	require.Equal(t, http.StatusBadRequest, infraerrors.Code(err))
+}
+
+func TestDetectUserFileMimeType(t *testing.T) {
+	require.Equal(t, "application/pdf", detectUserFileMimeType("a.pdf", "", []byte("x")))
+	require.Equal(t, "image/png", detectUserFileMimeType("blob", "image/png", nil))
+	require.Equal(t, "text/plain", detectUserFileMimeType("batch.jsonl", "application/octet-stream", []byte(`{"custom_id":"a"}`)))
+}
@@ -11,9 +11,11 @@ import (
 )
 
 var (
-	ErrUserNotFound      = infraerrors.NotFound("USER_NOT_FOUND", "user not found")
-	ErrPasswordIncorrect = infraerrors.BadRequest("PASSWORD_INCORRECT", "current password is incorrect")
-	ErrInsufficientPerms = infraerrors.Forbidden("INSUFFICIENT_PERMISSIONS", "insufficient permissions")
+	ErrUserNotFound       = infraerrors.NotFound("USER_NOT_FOUND", "user not found")
+	ErrPasswordIncorrect  = infraerrors.BadRequest("PASSWORD_INCORRECT", "current password is incorrect")
+	ErrInsufficientPerms  = infraerrors.Forbidden("INSUFFICIENT_PERMISSIONS", "insufficient permissions")
+	ErrInvalidUserRole    = infraerrors.BadRequest("INVALID_USER_ROLE", "role must be one of user, viewer, operator")
+	ErrAdminRoleImmutable = infraerrors.Forbidden("ADMIN_ROLE_IMMUTABLE", "cannot change the role of an admin user")
 )
 
 // UserListFilters contains all filter options for listing users
@@ -22,6 +24,7 @@ type UserListFilters struct {
 	Role       string           // User role filter
 	Search     string           // Search in email, username
 	Attributes map[int64]string // Custom attribute filters: attributeID -> value
+	TenantID   int64            // Tenant filter (0 = all tenants)
 	// IncludeSubscriptions controls whether ListWithFilters should load active subscriptions.
 	// For large datasets this can be expensive; admin list pages should enable it on demand.
 	// nil means not specified (default: load subscriptions for backward compatibility).
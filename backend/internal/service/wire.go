@@ -49,10 +49,14 @@ func ProvideTokenRefreshService(
 	schedulerCache SchedulerCache,
 	cfg *config.Config,
 	tempUnschedCache TempUnschedCache,
+	alertService *AlertService,
+	leader *LeaderElectionService,
 ) *TokenRefreshService {
 	svc := NewTokenRefreshService(accountRepo, oauthService, openaiOAuthService, geminiOAuthService, antigravityOAuthService, cacheInvalidator, schedulerCache, cfg, tempUnschedCache)
 	// 注入 Sora 账号扩展表仓储，用于 OpenAI Token 刷新时同步 sora_accounts 表
 	svc.SetSoraAccountRepo(soraAccountRepo)
+	svc.SetAlertService(alertService)
+	svc.SetLeaderElection(leader)
 	svc.Start()
 	return svc
 }
@@ -71,6 +75,27 @@ func ProvideUsageCleanupService(repo UsageCleanupRepository, timingWheel *Timing
 	return svc
 }
 
+// ProvideBatchService 创建并启动批处理任务执行服务
+func ProvideBatchService(repo BatchJobRepository, apiKeyRepo APIKeyRepository, timingWheel *TimingWheelService, cfg *config.Config) *BatchService {
+	svc := NewBatchService(repo, apiKeyRepo, timingWheel, cfg)
+	svc.Start()
+	return svc
+}
+
+// ProvideBackgroundResponseService 创建并启动 Responses API background 模式执行服务
+func ProvideBackgroundResponseService(repo BackgroundResponseRepository, apiKeyRepo APIKeyRepository, timingWheel *TimingWheelService, cfg *config.Config) *BackgroundResponseService {
+	svc := NewBackgroundResponseService(repo, apiKeyRepo, timingWheel, cfg)
+	svc.Start()
+	return svc
+}
+
+// ProvideUserFileService 创建文件服务并启动过期文件清理
+func ProvideUserFileService(repo UserFileRepository, timingWheel *TimingWheelService, cfg *config.Config) *UserFileService {
+	svc := NewUserFileService(repo, timingWheel, cfg)
+	svc.Start()
+	return svc
+}
+
 // ProvideAccountExpiryService creates and starts AccountExpiryService.
 func ProvideAccountExpiryService(accountRepo AccountRepository) *AccountExpiryService {
 	svc := NewAccountExpiryService(accountRepo, time.Minute)
@@ -78,6 +103,128 @@ func ProvideAccountExpiryService(accountRepo AccountRepository) *AccountExpirySe
 	return svc
 }
 
+// ProvideAccountHealthService creates AccountHealthService, registers it with RateLimitService and starts probing.
+func ProvideAccountHealthService(
+	accountRepo AccountRepository,
+	accountTestSvc *AccountTestService,
+	rateLimitSvc *RateLimitService,
+	tempUnschedCache TempUnschedCache,
+	leader *LeaderElectionService,
+	cfg *config.Config,
+) *AccountHealthService {
+	svc := NewAccountHealthService(accountRepo, accountTestSvc, rateLimitSvc, tempUnschedCache, cfg)
+	rateLimitSvc.SetAccountHealthService(svc)
+	svc.SetLeaderElection(leader)
+	svc.Start()
+	return svc
+}
+
+// ProvideAccountWarmupService creates and starts AccountWarmupService.
+func ProvideAccountWarmupService(
+	accountRepo AccountRepository,
+	accountTestSvc *AccountTestService,
+	healthSvc *AccountHealthService,
+	leader *LeaderElectionService,
+	cfg *config.Config,
+) *AccountWarmupService {
+	svc := NewAccountWarmupService(accountRepo, accountTestSvc, healthSvc, cfg)
+	svc.SetLeaderElection(leader)
+	svc.Start()
+	return svc
+}
+
+// ProvideCodexModelCatalogService creates and starts CodexModelCatalogService.
+func ProvideCodexModelCatalogService(
+	accountRepo AccountRepository,
+	tokenProvider *OpenAITokenProvider,
+	leader *LeaderElectionService,
+	cfg *config.Config,
+) *CodexModelCatalogService {
+	svc := NewCodexModelCatalogService(accountRepo, tokenProvider, cfg)
+	svc.SetLeaderElection(leader)
+	svc.Start()
+	return svc
+}
+
+// ProvideCodexVersionTracker creates and starts CodexVersionTracker.
+func ProvideCodexVersionTracker(githubClient GitHubReleaseClient, cfg *config.Config) *CodexVersionTracker {
+	tracker := NewCodexVersionTracker(githubClient, cfg)
+	tracker.Start()
+	return tracker
+}
+
+// ProvideLeaderElectionService creates and starts LeaderElectionService.
+func ProvideLeaderElectionService(cache LeaderLockCache, cfg *config.Config) *LeaderElectionService {
+	svc := NewLeaderElectionService(cache, cfg)
+	svc.Start()
+	return svc
+}
+
+// ProvideSharedStateService creates and starts SharedStateService, attaching it to the OpenAI gateway.
+func ProvideSharedStateService(
+	cache SharedStateCache,
+	openAIGateway *OpenAIGatewayService,
+	cfg *config.Config,
+) *SharedStateService {
+	svc := NewSharedStateService(cache, cfg)
+	openAIGateway.SetSharedStateService(svc)
+	svc.Start()
+	return svc
+}
+
+// ProvideConversationStoreService creates ConversationStoreService and attaches it to the OpenAI gateway.
+func ProvideConversationStoreService(
+	cfg *config.Config,
+	redisStore ConversationStore,
+	openAIGateway *OpenAIGatewayService,
+) *ConversationStoreService {
+	svc := NewConversationStoreService(cfg, redisStore)
+	openAIGateway.SetConversationStore(svc)
+	return svc
+}
+
+// ProvideRuntimeSettingsService creates RuntimeSettingsService and applies the stored runtime settings.
+func ProvideRuntimeSettingsService(settingRepo SettingRepository, opsService *OpsService, cfg *config.Config) *RuntimeSettingsService {
+	svc := NewRuntimeSettingsService(settingRepo, opsService, cfg)
+	svc.Start()
+	return svc
+}
+
+// ProvideMaintenanceService creates MaintenanceService and loads the stored maintenance state.
+func ProvideMaintenanceService(settingRepo SettingRepository) *MaintenanceService {
+	svc := NewMaintenanceService(settingRepo)
+	svc.Start()
+	return svc
+}
+
+// ProvideUsageWebhookService creates and starts UsageWebhookService, attaching it to both gateways.
+func ProvideUsageWebhookService(
+	sender UsageWebhookSender,
+	gateway *GatewayService,
+	openAIGateway *OpenAIGatewayService,
+	cfg *config.Config,
+) *UsageWebhookService {
+	svc := NewUsageWebhookService(cfg, sender)
+	gateway.SetUsageWebhook(svc)
+	openAIGateway.SetUsageWebhook(svc)
+	svc.Start()
+	return svc
+}
+
+// ProvideDeadLetterService creates DeadLetterService and starts its retention cleanup.
+func ProvideDeadLetterService(repo DeadLetterRepository, opsService *OpsService, cfg *config.Config) *DeadLetterService {
+	svc := NewDeadLetterService(repo, opsService, cfg)
+	svc.Start()
+	return svc
+}
+
+// ProvideContentLogService creates ContentLogService and starts its retention cleanup.
+func ProvideContentLogService(repo ContentLogRepository, cfg *config.Config) *ContentLogService {
+	svc := NewContentLogService(repo, cfg)
+	svc.Start()
+	return svc
+}
+
 // ProvideSubscriptionExpiryService creates and starts SubscriptionExpiryService.
 func ProvideSubscriptionExpiryService(userSubRepo UserSubscriptionRepository) *SubscriptionExpiryService {
 	svc := NewSubscriptionExpiryService(userSubRepo, time.Minute)
@@ -146,11 +293,13 @@ func ProvideRateLimitService(
 	timeoutCounterCache TimeoutCounterCache,
 	settingService *SettingService,
 	tokenCacheInvalidator TokenCacheInvalidator,
+	alertService *AlertService,
 ) *RateLimitService {
 	svc := NewRateLimitService(accountRepo, usageRepo, cfg, geminiQuotaService, tempUnschedCache)
 	svc.SetTimeoutCounterCache(timeoutCounterCache)
 	svc.SetSettingService(settingService)
 	svc.SetTokenCacheInvalidator(tokenCacheInvalidator)
+	svc.SetAlertService(alertService)
 	return svc
 }
 
@@ -189,8 +338,10 @@ func ProvideOpsAlertEvaluatorService(
 	emailService *EmailService,
 	redisClient *redis.Client,
 	cfg *config.Config,
+	alertService *AlertService,
 ) *OpsAlertEvaluatorService {
 	svc := NewOpsAlertEvaluatorService(opsService, opsRepo, emailService, redisClient, cfg)
+	svc.SetAlertService(alertService)
 	svc.Start()
 	return svc
 }
@@ -324,6 +475,13 @@ func ProvideSettingService(settingRepo SettingRepository, groupRepo GroupReposit
 	return svc
 }
 
+// ProvideBillingService wires BillingService with admin-managed pricing overrides.
+func ProvideBillingService(cfg *config.Config, pricingService *PricingService, settingService *SettingService) *BillingService {
+	svc := NewBillingService(cfg, pricingService)
+	svc.SetPricingOverrideSource(settingService)
+	return svc
+}
+
 // ProviderSet is the Wire provider set for all services
 var ProviderSet = wire.NewSet(
 	// Core services
@@ -339,9 +497,13 @@ var ProviderSet = wire.NewSet(
 	NewUsageService,
 	NewDashboardService,
 	ProvidePricingService,
-	NewBillingService,
+	ProvideBillingService,
 	NewBillingCacheService,
 	NewAnnouncementService,
+	NewTenantService,
+	NewAdminAuditService,
+	ProvideDeadLetterService,
+	ProvideContentLogService,
 	NewAdminService,
 	NewGatewayService,
 	ProvideSoraMediaStorage,
@@ -389,10 +551,34 @@ var ProviderSet = wire.NewSet(
 	ProvideUpdateService,
 	ProvideTokenRefreshService,
 	ProvideAccountExpiryService,
+	ProvideAccountHealthService,
+	NewAccountValidationService,
+	ProvideAccountWarmupService,
+	ProvideCodexModelCatalogService,
+	ProvideCodexVersionTracker,
+	ProvideSharedStateService,
+	ProvideLeaderElectionService,
+	NewConfigReloadService,
+	ProvideRuntimeSettingsService,
+	ProvideMaintenanceService,
+	NewBackupService,
 	ProvideSubscriptionExpiryService,
 	ProvideTimingWheelService,
 	ProvideDashboardAggregationService,
 	ProvideUsageCleanupService,
+	ProvideBatchService,
+	ProvideBackgroundResponseService,
+	NewSSEReplayService,
+	NewResponseCacheService,
+	ProvideConversationStoreService,
+	NewParamSanitizeService,
+	NewContextOverflowService,
+	NewChatMultiChoiceService,
+	NewMetricsService,
+	NewHealthService,
+	NewAlertService,
+	ProvideUsageWebhookService,
+	ProvideUserFileService,
 	ProvideDeferredService,
 	NewAntigravityQuotaFetcher,
 	NewUserAttributeService,
@@ -230,6 +230,8 @@ func shouldBypassEmbeddedFrontend(path string) bool {
 		strings.HasPrefix(trimmed, "/antigravity/") ||
 		strings.HasPrefix(trimmed, "/setup/") ||
 		trimmed == "/health" ||
+		trimmed == "/health/details" ||
+		trimmed == "/metrics" ||
 		trimmed == "/responses" ||
 		strings.HasPrefix(trimmed, "/responses/")
 }
@@ -366,6 +366,8 @@ func TestFrontendServer_Middleware(t *testing.T) {
 			"/antigravity/test",
 			"/setup/init",
 			"/health",
+			"/health/details",
+			"/metrics",
 			"/responses",
 			"/responses/compact",
 		}
@@ -569,6 +571,8 @@ func TestServeEmbeddedFrontend(t *testing.T) {
 			"/antigravity/test",
 			"/setup/init",
 			"/health",
+			"/health/details",
+			"/metrics",
 			"/responses",
 			"/responses/compact",
 		}
@@ -0,0 +1,3 @@
+-- Add per-key image generation quota to api_keys table (0 = unlimited)
+ALTER TABLE api_keys ADD COLUMN IF NOT EXISTS image_quota integer NOT NULL DEFAULT 0;
+ALTER TABLE api_keys ADD COLUMN IF NOT EXISTS image_quota_used integer NOT NULL DEFAULT 0;
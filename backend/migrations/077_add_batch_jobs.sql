@@ -0,0 +1,48 @@
+-- 077_add_batch_jobs.sql
+-- Batch API emulation: jobs and their per-line requests
+
+CREATE TABLE IF NOT EXISTS batch_jobs (
+    id                VARCHAR(64) PRIMARY KEY,
+    user_id           BIGINT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    api_key_id        BIGINT NOT NULL REFERENCES api_keys(id) ON DELETE CASCADE,
+    endpoint          VARCHAR(100) NOT NULL,
+    completion_window VARCHAR(20) NOT NULL DEFAULT '24h',
+    status            VARCHAR(20) NOT NULL DEFAULT 'in_progress',
+    client_ip         VARCHAR(64) NOT NULL DEFAULT '',
+    metadata          JSONB,
+    total_count       INT NOT NULL DEFAULT 0,
+    completed_count   INT NOT NULL DEFAULT 0,
+    failed_count      INT NOT NULL DEFAULT 0,
+    error_message     TEXT NOT NULL DEFAULT '',
+    expires_at        TIMESTAMPTZ NOT NULL,
+    in_progress_at    TIMESTAMPTZ,
+    completed_at      TIMESTAMPTZ,
+    cancelling_at     TIMESTAMPTZ,
+    cancelled_at      TIMESTAMPTZ,
+    expired_at        TIMESTAMPTZ,
+    created_at        TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at        TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+CREATE INDEX IF NOT EXISTS idx_batch_jobs_api_key_created ON batch_jobs(api_key_id, created_at DESC);
+CREATE INDEX IF NOT EXISTS idx_batch_jobs_active ON batch_jobs(status) WHERE status IN ('in_progress', 'cancelling');
+
+CREATE TABLE IF NOT EXISTS batch_job_items (
+    id              BIGSERIAL PRIMARY KEY,
+    job_id          VARCHAR(64) NOT NULL REFERENCES batch_jobs(id) ON DELETE CASCADE,
+    line_index      INT NOT NULL,
+    custom_id       VARCHAR(255) NOT NULL,
+    method          VARCHAR(10) NOT NULL DEFAULT 'POST',
+    url             VARCHAR(100) NOT NULL,
+    body            TEXT NOT NULL,
+    status          VARCHAR(20) NOT NULL DEFAULT 'pending',
+    attempts        INT NOT NULL DEFAULT 0,
+    next_attempt_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    response_status INT NOT NULL DEFAULT 0,
+    response_body   TEXT NOT NULL DEFAULT '',
+    request_id      VARCHAR(255) NOT NULL DEFAULT '',
+    error_message   TEXT NOT NULL DEFAULT '',
+    started_at      TIMESTAMPTZ,
+    finished_at     TIMESTAMPTZ,
+    UNIQUE (job_id, line_index)
+);
+CREATE INDEX IF NOT EXISTS idx_batch_job_items_claim ON batch_job_items(job_id, status, next_attempt_at);
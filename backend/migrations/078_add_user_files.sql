@@ -0,0 +1,17 @@
+-- 078_add_user_files.sql
+-- Files API: uploaded files referenced by file_id in gateway requests
+
+CREATE TABLE IF NOT EXISTS user_files (
+    id         VARCHAR(64) PRIMARY KEY,
+    user_id    BIGINT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    api_key_id BIGINT REFERENCES api_keys(id) ON DELETE SET NULL,
+    filename   VARCHAR(255) NOT NULL,
+    purpose    VARCHAR(32) NOT NULL,
+    mime_type  VARCHAR(128) NOT NULL DEFAULT 'application/octet-stream',
+    bytes      BIGINT NOT NULL DEFAULT 0,
+    content    BYTEA NOT NULL,
+    expires_at TIMESTAMPTZ,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+CREATE INDEX IF NOT EXISTS idx_user_files_user_created ON user_files(user_id, created_at DESC);
+CREATE INDEX IF NOT EXISTS idx_user_files_expires ON user_files(expires_at) WHERE expires_at IS NOT NULL;
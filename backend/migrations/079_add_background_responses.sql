@@ -0,0 +1,25 @@
+-- 079_add_background_responses.sql
+-- Responses API background mode: queued requests and their stored results
+
+CREATE TABLE IF NOT EXISTS background_responses (
+    id              VARCHAR(64) PRIMARY KEY,
+    user_id         BIGINT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    api_key_id      BIGINT NOT NULL REFERENCES api_keys(id) ON DELETE CASCADE,
+    model           VARCHAR(255) NOT NULL DEFAULT '',
+    status          VARCHAR(20) NOT NULL DEFAULT 'queued',
+    client_ip       VARCHAR(64) NOT NULL DEFAULT '',
+    request_body    TEXT NOT NULL,
+    attempts        INT NOT NULL DEFAULT 0,
+    next_attempt_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    response_status INT NOT NULL DEFAULT 0,
+    response_body   TEXT NOT NULL DEFAULT '',
+    request_id      VARCHAR(255) NOT NULL DEFAULT '',
+    error_message   TEXT NOT NULL DEFAULT '',
+    started_at      TIMESTAMPTZ,
+    completed_at    TIMESTAMPTZ,
+    created_at      TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at      TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+CREATE INDEX IF NOT EXISTS idx_background_responses_claim ON background_responses(status, next_attempt_at) WHERE status IN ('queued', 'in_progress');
+CREATE INDEX IF NOT EXISTS idx_background_responses_api_key ON background_responses(api_key_id, status);
+CREATE INDEX IF NOT EXISTS idx_background_responses_updated ON background_responses(updated_at);
@@ -0,0 +1,2 @@
+-- Add per-key toggle to drop reasoning/thinking output for plain API clients
+ALTER TABLE api_keys ADD COLUMN IF NOT EXISTS suppress_reasoning boolean NOT NULL DEFAULT false;
@@ -0,0 +1,2 @@
+-- Add per-group account scheduling strategy (empty = score-based load balancing)
+ALTER TABLE groups ADD COLUMN IF NOT EXISTS scheduling_strategy varchar(32) NOT NULL DEFAULT '';
@@ -0,0 +1,2 @@
+-- Track reasoning tokens separately (already included in output_tokens; statistics only)
+ALTER TABLE usage_logs ADD COLUMN IF NOT EXISTS reasoning_tokens INT NOT NULL DEFAULT 0;
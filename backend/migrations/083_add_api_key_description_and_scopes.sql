@@ -0,0 +1,3 @@
+-- Add free-form description and endpoint scopes to API keys (empty scopes = all endpoints)
+ALTER TABLE api_keys ADD COLUMN IF NOT EXISTS description varchar(500) NOT NULL DEFAULT '';
+ALTER TABLE api_keys ADD COLUMN IF NOT EXISTS scopes JSONB DEFAULT NULL;
@@ -0,0 +1,7 @@
+-- Add per-key request/token limits: per-minute throughput plus calendar day/month quotas (0 = unlimited)
+ALTER TABLE api_keys ADD COLUMN IF NOT EXISTS rpm_limit integer NOT NULL DEFAULT 0;
+ALTER TABLE api_keys ADD COLUMN IF NOT EXISTS tpm_limit integer NOT NULL DEFAULT 0;
+ALTER TABLE api_keys ADD COLUMN IF NOT EXISTS daily_request_limit integer NOT NULL DEFAULT 0;
+ALTER TABLE api_keys ADD COLUMN IF NOT EXISTS daily_token_limit bigint NOT NULL DEFAULT 0;
+ALTER TABLE api_keys ADD COLUMN IF NOT EXISTS monthly_request_limit integer NOT NULL DEFAULT 0;
+ALTER TABLE api_keys ADD COLUMN IF NOT EXISTS monthly_token_limit bigint NOT NULL DEFAULT 0;
@@ -0,0 +1,2 @@
+-- Add per-key model allowlist to API keys (trailing * wildcard supported, empty = all models)
+ALTER TABLE api_keys ADD COLUMN IF NOT EXISTS allowed_models JSONB DEFAULT NULL;
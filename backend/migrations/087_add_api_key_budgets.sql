@@ -0,0 +1,8 @@
+-- Add per-key spend budgets: when a key crosses its budget it is disabled or downgraded until the period resets
+ALTER TABLE api_keys ADD COLUMN IF NOT EXISTS budget_amount decimal(20,8) NOT NULL DEFAULT 0;
+ALTER TABLE api_keys ADD COLUMN IF NOT EXISTS budget_period VARCHAR(10) NOT NULL DEFAULT 'month';
+ALTER TABLE api_keys ADD COLUMN IF NOT EXISTS budget_action VARCHAR(20) NOT NULL DEFAULT 'disable';
+ALTER TABLE api_keys ADD COLUMN IF NOT EXISTS budget_fallback_model VARCHAR(100) NOT NULL DEFAULT '';
+ALTER TABLE api_keys ADD COLUMN IF NOT EXISTS budget_used decimal(20,8) NOT NULL DEFAULT 0;
+ALTER TABLE api_keys ADD COLUMN IF NOT EXISTS budget_period_start TIMESTAMPTZ DEFAULT NULL;
+ALTER TABLE api_keys ADD COLUMN IF NOT EXISTS budget_exceeded_at TIMESTAMPTZ DEFAULT NULL;
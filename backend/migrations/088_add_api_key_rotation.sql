@@ -0,0 +1,7 @@
+-- Key rotation: the replaced secret keeps authenticating until previous_key_expires_at
+ALTER TABLE api_keys ADD COLUMN IF NOT EXISTS previous_key VARCHAR(128) DEFAULT NULL;
+ALTER TABLE api_keys ADD COLUMN IF NOT EXISTS previous_key_expires_at TIMESTAMPTZ DEFAULT NULL;
+
+CREATE INDEX IF NOT EXISTS idx_api_keys_previous_key
+ON api_keys(previous_key)
+WHERE previous_key IS NOT NULL AND deleted_at IS NULL;
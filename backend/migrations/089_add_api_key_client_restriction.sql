@@ -0,0 +1,2 @@
+-- Restrict a key to a client class detected from User-Agent / originator headers ('' = any client)
+ALTER TABLE api_keys ADD COLUMN IF NOT EXISTS client_restriction VARCHAR(20) NOT NULL DEFAULT '';
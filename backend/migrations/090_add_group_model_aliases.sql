@@ -0,0 +1,2 @@
+-- Per-group model aliases: requested model (trailing * wildcard) -> upstream model, overriding the global alias table
+ALTER TABLE groups ADD COLUMN IF NOT EXISTS model_aliases JSONB DEFAULT NULL;
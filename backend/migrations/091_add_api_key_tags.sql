@@ -0,0 +1,2 @@
+-- Admin-assigned key tags, matched by routing rules (NULL = no tags)
+ALTER TABLE api_keys ADD COLUMN IF NOT EXISTS tags JSONB DEFAULT NULL;
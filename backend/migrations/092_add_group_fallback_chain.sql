@@ -0,0 +1,2 @@
+-- Cross-provider fallback chain: ordered group IDs retried when this group's upstreams are rate-limited or failing
+ALTER TABLE groups ADD COLUMN IF NOT EXISTS fallback_chain JSONB DEFAULT NULL;
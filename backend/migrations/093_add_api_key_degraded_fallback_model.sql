@@ -0,0 +1,2 @@
+-- Model served when every account for the requested model is down or cooling off ('' = return 503)
+ALTER TABLE api_keys ADD COLUMN IF NOT EXISTS degraded_fallback_model VARCHAR(100) NOT NULL DEFAULT '';
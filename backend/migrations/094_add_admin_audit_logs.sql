@@ -0,0 +1,23 @@
+-- Admin action audit log: every admin write request with actor, target resource and before/after diff.
+-- actor_user_id is not a foreign key so records survive user deletion.
+CREATE TABLE IF NOT EXISTS admin_audit_logs (
+    id             BIGSERIAL PRIMARY KEY,
+    actor_user_id  BIGINT NOT NULL DEFAULT 0,
+    actor_role     VARCHAR(20) NOT NULL DEFAULT '',
+    auth_method    VARCHAR(20) NOT NULL DEFAULT '',
+    method         VARCHAR(10) NOT NULL,
+    route          VARCHAR(255) NOT NULL DEFAULT '',
+    path           VARCHAR(1024) NOT NULL DEFAULT '',
+    resource_type  VARCHAR(64) NOT NULL DEFAULT '',
+    resource_id    VARCHAR(64) NOT NULL DEFAULT '',
+    status_code    INT NOT NULL DEFAULT 0,
+    client_ip      VARCHAR(64) NOT NULL DEFAULT '',
+    request_id     VARCHAR(255) NOT NULL DEFAULT '',
+    request_body   TEXT NOT NULL DEFAULT '',
+    changes        JSONB,
+    created_at     TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_admin_audit_logs_created_at ON admin_audit_logs(created_at);
+CREATE INDEX IF NOT EXISTS idx_admin_audit_logs_actor ON admin_audit_logs(actor_user_id, created_at);
+CREATE INDEX IF NOT EXISTS idx_admin_audit_logs_resource ON admin_audit_logs(resource_type, resource_id, created_at);
@@ -0,0 +1,28 @@
+-- +goose Up
+-- +goose StatementBegin
+-- 早期的迁移执行器会连同 "-- +goose Down" 部分一起执行，
+-- 导致 037 创建的 ops_alert_silences 随即被删除；这里补建该表（已存在时不做任何操作）。
+
+CREATE TABLE IF NOT EXISTS ops_alert_silences (
+    id BIGSERIAL PRIMARY KEY,
+
+    rule_id BIGINT NOT NULL,
+    platform VARCHAR(64) NOT NULL,
+    group_id BIGINT,
+    region VARCHAR(64),
+
+    until TIMESTAMPTZ NOT NULL,
+    reason TEXT,
+
+    created_by BIGINT,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_ops_alert_silences_lookup
+    ON ops_alert_silences (rule_id, platform, group_id, region, until);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+-- 表由 037 负责，回滚本迁移不删除
+-- +goose StatementEnd
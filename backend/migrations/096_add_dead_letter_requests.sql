@@ -0,0 +1,39 @@
+-- Dead-letter store: gateway requests that still failed after all retries / account failovers,
+-- kept with the full request body so they can be replayed once accounts recover.
+-- user/api_key/group/account ids are not foreign keys so records survive deletions.
+CREATE TABLE IF NOT EXISTS dead_letter_requests (
+    id                      BIGSERIAL PRIMARY KEY,
+    request_id              VARCHAR(255) NOT NULL DEFAULT '',
+    client_request_id       VARCHAR(255) NOT NULL DEFAULT '',
+    user_id                 BIGINT,
+    api_key_id              BIGINT,
+    group_id                BIGINT,
+    account_id              BIGINT,
+    platform                VARCHAR(32) NOT NULL DEFAULT '',
+    model                   VARCHAR(255) NOT NULL DEFAULT '',
+    request_path            VARCHAR(255) NOT NULL DEFAULT '',
+    stream                  BOOLEAN NOT NULL DEFAULT FALSE,
+    user_agent              VARCHAR(512) NOT NULL DEFAULT '',
+    request_headers         TEXT NOT NULL DEFAULT '',
+    request_body            TEXT NOT NULL DEFAULT '',
+    request_body_bytes      INT NOT NULL DEFAULT 0,
+    status_code             INT NOT NULL DEFAULT 0,
+    upstream_status_code    INT NOT NULL DEFAULT 0,
+    attempt_count           INT NOT NULL DEFAULT 0,
+    failure_reason          TEXT NOT NULL DEFAULT '',
+    status                  VARCHAR(20) NOT NULL DEFAULT 'pending',
+    replay_count            INT NOT NULL DEFAULT 0,
+    last_replay_at          TIMESTAMPTZ,
+    last_replay_by_user_id  BIGINT,
+    last_replay_account_id  BIGINT,
+    last_replay_status_code INT NOT NULL DEFAULT 0,
+    last_replay_error       TEXT NOT NULL DEFAULT '',
+    response_body           TEXT NOT NULL DEFAULT '',
+    created_at              TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at              TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_dead_letter_requests_created_at ON dead_letter_requests(created_at);
+CREATE INDEX IF NOT EXISTS idx_dead_letter_requests_status ON dead_letter_requests(status, created_at);
+CREATE INDEX IF NOT EXISTS idx_dead_letter_requests_user ON dead_letter_requests(user_id, created_at);
+CREATE INDEX IF NOT EXISTS idx_dead_letter_requests_api_key ON dead_letter_requests(api_key_id, created_at);
@@ -0,0 +1,4 @@
+-- Per-key system prompt injected into every inference request ('' = none).
+-- system_prompt_mode: prepend / append / replace ('' = prepend)
+ALTER TABLE api_keys ADD COLUMN IF NOT EXISTS system_prompt TEXT NOT NULL DEFAULT '';
+ALTER TABLE api_keys ADD COLUMN IF NOT EXISTS system_prompt_mode VARCHAR(20) NOT NULL DEFAULT '';
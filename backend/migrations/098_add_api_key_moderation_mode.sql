@@ -0,0 +1,2 @@
+-- Per-key prompt moderation pre-filter: off / flag / block ('' = follow gateway.moderation.pre_filter)
+ALTER TABLE api_keys ADD COLUMN IF NOT EXISTS moderation_mode VARCHAR(20) NOT NULL DEFAULT '';
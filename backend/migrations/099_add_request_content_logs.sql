@@ -0,0 +1,31 @@
+-- Opt-in prompt / response logging: keys with log_content = TRUE store the full request and
+-- response bodies of their gateway requests in request_content_logs for debugging.
+-- Kept apart from the always-on usage / ops logs and purged after content_log.retention_days.
+-- user/api_key/group/account ids are not foreign keys so records survive deletions.
+ALTER TABLE api_keys ADD COLUMN IF NOT EXISTS log_content BOOLEAN NOT NULL DEFAULT FALSE;
+
+CREATE TABLE IF NOT EXISTS request_content_logs (
+    id                  BIGSERIAL PRIMARY KEY,
+    request_id          VARCHAR(255) NOT NULL DEFAULT '',
+    client_request_id   VARCHAR(255) NOT NULL DEFAULT '',
+    user_id             BIGINT,
+    api_key_id          BIGINT,
+    group_id            BIGINT,
+    account_id          BIGINT,
+    platform            VARCHAR(32) NOT NULL DEFAULT '',
+    model               VARCHAR(255) NOT NULL DEFAULT '',
+    request_path        VARCHAR(255) NOT NULL DEFAULT '',
+    stream              BOOLEAN NOT NULL DEFAULT FALSE,
+    status_code         INT NOT NULL DEFAULT 0,
+    duration_ms         INT NOT NULL DEFAULT 0,
+    request_body        TEXT NOT NULL DEFAULT '',
+    request_body_bytes  INT NOT NULL DEFAULT 0,
+    response_body       TEXT NOT NULL DEFAULT '',
+    response_body_bytes INT NOT NULL DEFAULT 0,
+    created_at          TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_request_content_logs_created_at ON request_content_logs(created_at);
+CREATE INDEX IF NOT EXISTS idx_request_content_logs_api_key ON request_content_logs(api_key_id, created_at);
+CREATE INDEX IF NOT EXISTS idx_request_content_logs_user ON request_content_logs(user_id, created_at);
+CREATE INDEX IF NOT EXISTS idx_request_content_logs_request_id ON request_content_logs(request_id);
@@ -0,0 +1,2 @@
+-- Gateway X-Request-ID of the request (request_id keeps the upstream ID used for dedupe)
+ALTER TABLE usage_logs ADD COLUMN IF NOT EXISTS gateway_request_id VARCHAR(128);
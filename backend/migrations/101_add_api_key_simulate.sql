@@ -0,0 +1,3 @@
+-- Dry-run mode: requests of keys with simulate = TRUE are answered by the built-in mock upstream
+-- (gateway.simulation) and never reach real accounts. Routing, quotas, billing and usage logs still run.
+ALTER TABLE api_keys ADD COLUMN IF NOT EXISTS simulate BOOLEAN NOT NULL DEFAULT FALSE;
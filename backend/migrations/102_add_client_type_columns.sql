@@ -0,0 +1,4 @@
+-- Client type detected on the live request path (clientdetect: UA + originator + x-stainless-* headers),
+-- so client stats no longer re-parse the stored User-Agent. NULL for rows recorded before this column.
+ALTER TABLE usage_logs ADD COLUMN IF NOT EXISTS client_type VARCHAR(32);
+ALTER TABLE ops_error_logs ADD COLUMN IF NOT EXISTS client_type VARCHAR(32);
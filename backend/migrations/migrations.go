@@ -20,6 +20,7 @@ import "embed"
 //   - 必须是幂等的（可重复执行而不产生错误）
 //   - 推荐使用 IF NOT EXISTS / IF EXISTS 语法
 //   - 一旦应用，不应修改已有的迁移文件（通过 checksum 校验）
+//   - 使用 "-- +goose Up" / "-- +goose Down" 注释时只执行 Up 部分，Down 部分供 -migrate-rollback 使用
 //
 // 示例迁移文件：
 //
@@ -230,6 +230,42 @@ export async function getUsage(id: number, source?: 'passive' | 'active'): Promi
   return data
 }
 
+export interface AccountUpstreamLimitWindow {
+  name: string
+  utilization: number
+  remaining_percent: number
+  resets_at?: string
+}
+
+export interface AccountUpstreamLimits {
+  account_id: number
+  name: string
+  platform: string
+  type: string
+  plan?: string
+  status: string
+  source: 'upstream' | 'local' | 'estimated'
+  fetched_at: string
+  headroom: {
+    rate_limited: boolean
+    rate_limit_reset_at?: string
+    overloaded: boolean
+    temp_unschedulable_until?: string
+    windows?: AccountUpstreamLimitWindow[]
+    usage_error?: string
+  }
+}
+
+/**
+ * Query upstream for current rate-limit windows and plan status (bypasses usage cache)
+ * @param id - Account ID
+ * @returns Normalized upstream limits
+ */
+export async function getUpstreamLimits(id: number): Promise<AccountUpstreamLimits> {
+  const { data } = await apiClient.get<AccountUpstreamLimits>(`/admin/accounts/${id}/upstream-limits`)
+  return data
+}
+
 /**
  * Clear account rate limit status
  * @param id - Account ID
@@ -587,6 +623,7 @@ export const accountsAPI = {
   getStats,
   clearError,
   getUsage,
+  getUpstreamLimits,
   getTodayStats,
   getBatchTodayStats,
   clearRateLimit,
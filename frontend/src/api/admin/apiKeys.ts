@@ -4,7 +4,7 @@
  */
 
 import { apiClient } from '../client'
-import type { ApiKey } from '@/types'
+import type { ApiKey, ApiKeyModerationMode, SystemPromptMode } from '@/types'
 
 export interface UpdateApiKeyGroupResult {
   api_key: ApiKey
@@ -26,8 +26,96 @@ export async function updateApiKeyGroup(id: number, groupId: number | null): Pro
   return data
 }
 
+/**
+ * Rotate an API key's secret, keeping its settings and usage history
+ * @param id - API Key ID
+ * @param gracePeriodMinutes - Minutes the old secret keeps working (0 = revoke immediately, max 10080)
+ * @returns API key with the new secret
+ */
+export async function rotateApiKey(id: number, gracePeriodMinutes = 0): Promise<ApiKey> {
+  const { data } = await apiClient.post<ApiKey>(`/admin/api-keys/${id}/rotate`, {
+    grace_period_minutes: gracePeriodMinutes
+  })
+  return data
+}
+
+/**
+ * Replace an API key's routing tags
+ * @param id - API Key ID
+ * @param tags - Tags matched by routing rules (empty array clears)
+ * @returns Updated API key
+ */
+export async function updateApiKeyTags(id: number, tags: string[]): Promise<ApiKey> {
+  const { data } = await apiClient.put<ApiKey>(`/admin/api-keys/${id}/tags`, { tags })
+  return data
+}
+
+/**
+ * Set the system prompt injected into every request made with an API key
+ * @param id - API Key ID
+ * @param systemPrompt - Prompt text (empty string clears)
+ * @param mode - prepend / append / replace (default prepend)
+ * @returns Updated API key
+ */
+export async function updateApiKeySystemPrompt(
+  id: number,
+  systemPrompt: string,
+  mode?: SystemPromptMode
+): Promise<ApiKey> {
+  const { data } = await apiClient.put<ApiKey>(`/admin/api-keys/${id}/system-prompt`, {
+    system_prompt: systemPrompt,
+    system_prompt_mode: mode
+  })
+  return data
+}
+
+/**
+ * Set how an API key's prompts are checked by the moderation pre-filter
+ * @param id - API Key ID
+ * @param mode - off / flag / block ('' = follow the gateway default)
+ * @returns Updated API key
+ */
+export async function updateApiKeyModeration(id: number, mode: ApiKeyModerationMode): Promise<ApiKey> {
+  const { data } = await apiClient.put<ApiKey>(`/admin/api-keys/${id}/moderation`, {
+    moderation_mode: mode
+  })
+  return data
+}
+
+/**
+ * Enable or disable full prompt/response logging for an API key
+ * @param id - API Key ID
+ * @param logContent - Whether to record request and response bodies
+ * @returns Updated API key
+ */
+export async function updateApiKeyContentLogging(id: number, logContent: boolean): Promise<ApiKey> {
+  const { data } = await apiClient.put<ApiKey>(`/admin/api-keys/${id}/content-logging`, {
+    log_content: logContent
+  })
+  return data
+}
+
+/**
+ * Enable or disable dry-run mode for an API key
+ * @param id - API Key ID
+ * @param simulate - Whether requests are answered by the built-in mock upstream instead of real accounts
+ * @returns Updated API key
+ */
+export async function updateApiKeySimulation(id: number, simulate: boolean): Promise<ApiKey> {
+  const { data } = await apiClient.put<ApiKey>(`/admin/api-keys/${id}/simulation`, {
+    simulate
+  })
+  return data
+}
+
 export const apiKeysAPI = {
-  updateApiKeyGroup
+  updateApiKeyGroup,
+  rotateApiKey,
+  updateApiKeyTags,
+  updateApiKeySystemPrompt,
+  updateApiKeyModeration,
+  updateApiKeyContentLogging,
+  updateApiKeySimulation
 }
 
 export default apiKeysAPI
@@ -0,0 +1,78 @@
+/**
+ * Admin Audit Log API endpoints
+ * Query and export the record of admin write operations
+ */
+
+import { apiClient } from '../client'
+import type { BasePaginationResponse } from '@/types'
+
+export interface AdminAuditChange {
+  before: unknown
+  after: unknown
+}
+
+export interface AdminAuditLog {
+  id: number
+  actor_user_id: number
+  actor_email?: string
+  actor_role: string
+  auth_method: string
+  method: string
+  route: string
+  path: string
+  resource_type: string
+  resource_id?: string
+  status_code: number
+  client_ip: string
+  request_id?: string
+  request_body?: string
+  changes?: Record<string, AdminAuditChange>
+  created_at: string
+}
+
+export interface AdminAuditLogFilters {
+  start_time?: string
+  end_time?: string
+  actor_user_id?: number
+  method?: string
+  resource_type?: string
+  resource_id?: string
+}
+
+/**
+ * List audit log entries (newest first)
+ * @param page - Page number
+ * @param pageSize - Items per page
+ * @param filters - Time range (RFC3339), actor and resource filters
+ * @returns Paginated audit log entries
+ */
+export async function list(
+  page: number = 1,
+  pageSize: number = 50,
+  filters?: AdminAuditLogFilters
+): Promise<BasePaginationResponse<AdminAuditLog>> {
+  const { data } = await apiClient.get<BasePaginationResponse<AdminAuditLog>>('/admin/audit-logs', {
+    params: { page, page_size: pageSize, ...filters }
+  })
+  return data
+}
+
+/**
+ * Export audit log entries matching the filters as CSV (up to 10000 rows)
+ * @param filters - Time range (RFC3339), actor and resource filters
+ * @returns CSV file blob
+ */
+export async function exportCSV(filters?: AdminAuditLogFilters): Promise<Blob> {
+  const { data } = await apiClient.get<Blob>('/admin/audit-logs', {
+    params: { ...filters, format: 'csv' },
+    responseType: 'blob'
+  })
+  return data
+}
+
+export const auditLogsAPI = {
+  list,
+  exportCSV
+}
+
+export default auditLogsAPI
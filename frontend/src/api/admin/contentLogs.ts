@@ -0,0 +1,75 @@
+/**
+ * Admin Content Log API endpoints
+ * Search the full prompts and responses recorded for API keys with log_content enabled
+ */
+
+import { apiClient } from '../client'
+import type { BasePaginationResponse } from '@/types'
+
+export interface RequestContentLog {
+  id: number
+  request_id?: string
+  client_request_id?: string
+  user_id?: number
+  user_email?: string
+  api_key_id?: number
+  group_id?: number
+  account_id?: number
+  platform: string
+  model: string
+  request_path: string
+  stream: boolean
+  status_code: number
+  duration_ms: number
+  request_body?: string
+  request_body_bytes: number
+  response_body?: string
+  response_body_bytes: number
+  created_at: string
+}
+
+export interface ContentLogFilters {
+  start_time?: string
+  end_time?: string
+  user_id?: number
+  api_key_id?: number
+  model?: string
+  request_id?: string
+  status_code?: number
+  q?: string
+}
+
+/**
+ * List content logs (newest first, without request/response bodies)
+ * @param page - Page number
+ * @param pageSize - Items per page
+ * @param filters - Time range (RFC3339), owner filters and body text search (q)
+ * @returns Paginated content logs
+ */
+export async function list(
+  page: number = 1,
+  pageSize: number = 20,
+  filters?: ContentLogFilters
+): Promise<BasePaginationResponse<RequestContentLog>> {
+  const { data } = await apiClient.get<BasePaginationResponse<RequestContentLog>>('/admin/content-logs', {
+    params: { page, page_size: pageSize, ...filters }
+  })
+  return data
+}
+
+/**
+ * Get a content log with its request and response bodies
+ * @param id - Content log ID
+ * @returns Content log detail
+ */
+export async function getById(id: number): Promise<RequestContentLog> {
+  const { data } = await apiClient.get<RequestContentLog>(`/admin/content-logs/${id}`)
+  return data
+}
+
+export const contentLogsAPI = {
+  list,
+  getById
+}
+
+export default contentLogsAPI
@@ -155,6 +155,251 @@ export async function getGroupStats(params?: GroupStatsParams): Promise<GroupSta
   return data
 }
 
+export type UsageAggregateDimension = 'api_key' | 'model' | 'account' | 'user' | 'group'
+
+export interface UsageAggregateParams {
+  start_date?: string
+  end_date?: string
+  timezone?: string
+  group_by?: string // comma-separated UsageAggregateDimension list
+  granularity?: 'hour' | 'day' | 'week' | 'month'
+  user_id?: number
+  api_key_id?: number
+  account_id?: number
+  group_id?: number
+  model?: string
+  limit?: number
+}
+
+export interface UsageAggregate {
+  bucket?: string
+  api_key_id?: number
+  api_key_name?: string
+  model?: string
+  account_id?: number
+  account_name?: string
+  user_id?: number
+  user_email?: string
+  group_id?: number
+  group_name?: string
+  requests: number
+  input_tokens: number
+  output_tokens: number
+  reasoning_tokens: number // included in output_tokens
+  cache_creation_tokens: number
+  cache_read_tokens: number
+  total_tokens: number
+  cost: number // 标准计费
+  actual_cost: number // 实际扣除
+  account_cost: number // 账号成本
+}
+
+export interface UsageAggregatesResponse {
+  items: UsageAggregate[]
+  group_by: UsageAggregateDimension[]
+  granularity: string
+  start_date: string
+  end_date: string
+}
+
+/**
+ * Get usage aggregated by any combination of key/model/account/user/group and time bucket
+ * @param params - Grouping, bucket and filter parameters
+ * @returns Aggregated usage rows
+ */
+export async function getUsageAggregates(params?: UsageAggregateParams): Promise<UsageAggregatesResponse> {
+  const { data } = await apiClient.get<UsageAggregatesResponse>('/admin/dashboard/aggregates', { params })
+  return data
+}
+
+/**
+ * Export aggregated usage as CSV (for chargeback reports)
+ * @param params - Grouping, bucket and filter parameters
+ * @returns CSV file blob
+ */
+export async function exportUsageAggregates(params?: UsageAggregateParams): Promise<Blob> {
+  const { data } = await apiClient.get<Blob>('/admin/dashboard/aggregates', {
+    params: { ...params, format: 'csv' },
+    responseType: 'blob'
+  })
+  return data
+}
+
+export interface LiveRequestEvent {
+  timestamp: string
+  request_id?: string
+  api_key_id: number
+  user_id?: number
+  account_id?: number
+  platform?: string
+  model?: string
+  status: number
+  latency_ms: number
+  first_token_ms?: number
+  stream: boolean
+  input_tokens: number
+  output_tokens: number
+  cache_creation_tokens: number
+  cache_read_tokens: number
+  actual_cost: number
+}
+
+export interface LiveEventsParams {
+  api_key_id?: number
+  account_id?: number
+  model?: string
+  errors_only?: boolean
+}
+
+export interface LiveEventsHandlers {
+  onEvent: (event: LiveRequestEvent) => void
+  /** Called on every heartbeat with the number of events dropped for this subscriber */
+  onHeartbeat?: (dropped: number) => void
+}
+
+/**
+ * Stream real-time request events (live tail) of the connected instance via SSE.
+ * Uses fetch instead of EventSource so the Authorization header can be sent.
+ * Resolves when the stream ends; abort the signal to disconnect.
+ */
+export async function streamLiveEvents(
+  params: LiveEventsParams,
+  handlers: LiveEventsHandlers,
+  signal?: AbortSignal
+): Promise<void> {
+  const query = new URLSearchParams()
+  Object.entries(params).forEach(([key, value]) => {
+    if (value !== undefined && value !== '' && value !== false) query.set(key, String(value))
+  })
+  const qs = query.toString()
+  const response = await fetch(`/api/v1/admin/dashboard/live${qs ? `?${qs}` : ''}`, {
+    headers: { Authorization: `Bearer ${localStorage.getItem('auth_token')}` },
+    signal
+  })
+  if (!response.ok) {
+    throw new Error(`HTTP error! status: ${response.status}`)
+  }
+  const reader = response.body?.getReader()
+  if (!reader) {
+    throw new Error('No response body')
+  }
+
+  const decoder = new TextDecoder()
+  let buffer = ''
+  let eventName = ''
+  while (true) {
+    const { done, value } = await reader.read()
+    if (done) break
+
+    buffer += decoder.decode(value, { stream: true })
+    const lines = buffer.split('\n')
+    buffer = lines.pop() || ''
+    for (const line of lines) {
+      if (line.startsWith('event: ')) {
+        eventName = line.slice(7).trim()
+      } else if (line.startsWith('data: ')) {
+        const payload = JSON.parse(line.slice(6))
+        if (eventName === 'request') {
+          handlers.onEvent(payload as LiveRequestEvent)
+        } else if (eventName === 'ping') {
+          handlers.onHeartbeat?.(payload.dropped ?? 0)
+        }
+      } else if (line === '') {
+        eventName = ''
+      }
+    }
+  }
+}
+
+export interface ClientTypeStat {
+  client_type: string
+  requests: number
+  errors: number
+  error_rate: number
+  request_share: number
+  input_tokens: number
+  output_tokens: number
+  cache_creation_tokens: number
+  cache_read_tokens: number
+  total_tokens: number
+  cost: number
+  actual_cost: number
+}
+
+export interface ClientStatsParams {
+  start_date?: string
+  end_date?: string
+  timezone?: string
+}
+
+export interface ClientStatsResponse {
+  clients: ClientTypeStat[]
+  start_date: string
+  end_date: string
+}
+
+/**
+ * Get usage breakdown by detected client type (Codex CLI / VSCode / API ...)
+ * @param params - Query parameters for filtering
+ * @returns Client type usage statistics
+ */
+export async function getClientStats(params?: ClientStatsParams): Promise<ClientStatsResponse> {
+  const { data } = await apiClient.get<ClientStatsResponse>('/admin/stats/clients', { params })
+  return data
+}
+
+export interface DashboardOverviewModel {
+  model: string
+  requests: number
+  total_tokens: number
+  cost: number
+  actual_cost: number
+}
+
+export interface DashboardOverviewApiKey {
+  api_key_id: number
+  api_key_name: string
+  requests: number
+  total_tokens: number
+  cost: number
+  actual_cost: number
+}
+
+export interface DashboardOverview {
+  generated_at: string
+  date: string
+  requests: number
+  errors: number
+  error_rate: number
+  input_tokens: number
+  output_tokens: number
+  cache_creation_tokens: number
+  cache_read_tokens: number
+  total_tokens: number
+  cost: number
+  actual_cost: number
+  active_accounts: number
+  total_accounts: number
+  active_users: number
+  rpm: number
+  tpm: number
+  stats_stale: boolean
+  top_models: DashboardOverviewModel[]
+  top_api_keys: DashboardOverviewApiKey[]
+}
+
+/**
+ * Get today's pre-aggregated dashboard overview in a single request
+ * @param top - Number of top models / API keys (default 5, max 20)
+ * @returns Dashboard overview
+ */
+export async function getOverview(top?: number): Promise<DashboardOverview> {
+  const { data } = await apiClient.get<DashboardOverview>('/admin/stats/overview', {
+    params: top ? { top } : undefined
+  })
+  return data
+}
+
 /**
  * Get dashboard snapshot v2 (aggregated response for heavy admin pages).
  */
@@ -268,6 +513,11 @@ export const dashboardAPI = {
   getUsageTrend,
   getModelStats,
   getGroupStats,
+  getUsageAggregates,
+  exportUsageAggregates,
+  streamLiveEvents,
+  getClientStats,
+  getOverview,
   getSnapshotV2,
   getApiKeyUsageTrend,
   getUserUsageTrend,
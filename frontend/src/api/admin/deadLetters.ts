@@ -0,0 +1,115 @@
+/**
+ * Admin Dead Letter API endpoints
+ * Browse, replay and discard gateway requests that failed after every retry
+ */
+
+import { apiClient } from '../client'
+import type { BasePaginationResponse } from '@/types'
+
+export type DeadLetterStatus = 'pending' | 'replaying' | 'succeeded' | 'failed' | 'discarded'
+
+export interface DeadLetterRequest {
+  id: number
+  request_id?: string
+  client_request_id?: string
+  user_id?: number
+  user_email?: string
+  api_key_id?: number
+  group_id?: number
+  account_id?: number
+  platform: string
+  model: string
+  request_path: string
+  stream: boolean
+  user_agent?: string
+  request_headers?: string
+  request_body?: string
+  request_body_bytes: number
+  status_code: number
+  upstream_status_code?: number
+  attempt_count: number
+  failure_reason: string
+  status: DeadLetterStatus
+  replay_count: number
+  last_replay_at?: string
+  last_replay_by_user_id?: number
+  last_replay_account_id?: number
+  last_replay_status_code?: number
+  last_replay_error?: string
+  response_body?: string
+  created_at: string
+  updated_at: string
+}
+
+export interface DeadLetterFilters {
+  start_time?: string
+  end_time?: string
+  status?: DeadLetterStatus
+  user_id?: number
+  api_key_id?: number
+  group_id?: number
+  account_id?: number
+  model?: string
+}
+
+export interface DeadLetterReplayResult {
+  accepted: number[]
+  skipped: number[]
+}
+
+/**
+ * List dead letters (newest first, without request/response bodies)
+ * @param page - Page number
+ * @param pageSize - Items per page
+ * @param filters - Time range (RFC3339), status and owner filters
+ * @returns Paginated dead letters
+ */
+export async function list(
+  page: number = 1,
+  pageSize: number = 20,
+  filters?: DeadLetterFilters
+): Promise<BasePaginationResponse<DeadLetterRequest>> {
+  const { data } = await apiClient.get<BasePaginationResponse<DeadLetterRequest>>('/admin/dead-letters', {
+    params: { page, page_size: pageSize, ...filters }
+  })
+  return data
+}
+
+/**
+ * Get a dead letter with its request body and last replay response
+ * @param id - Dead letter ID
+ * @returns Dead letter detail
+ */
+export async function getById(id: number): Promise<DeadLetterRequest> {
+  const { data } = await apiClient.get<DeadLetterRequest>(`/admin/dead-letters/${id}`)
+  return data
+}
+
+/**
+ * Replay dead letters in the background (at most 100 per call)
+ * @param ids - Dead letter IDs
+ * @returns Accepted IDs and IDs skipped because they are not replayable
+ */
+export async function replay(ids: number[]): Promise<DeadLetterReplayResult> {
+  const { data } = await apiClient.post<DeadLetterReplayResult>('/admin/dead-letters/replay', { ids })
+  return data
+}
+
+/**
+ * Discard dead letters so they are no longer replayed
+ * @param ids - Dead letter IDs
+ * @returns Number of discarded entries
+ */
+export async function discard(ids: number[]): Promise<{ discarded: number }> {
+  const { data } = await apiClient.post<{ discarded: number }>('/admin/dead-letters/discard', { ids })
+  return data
+}
+
+export const deadLettersAPI = {
+  list,
+  getById,
+  replay,
+  discard
+}
+
+export default deadLettersAPI
@@ -23,6 +23,10 @@ import errorPassthroughAPI from './errorPassthrough'
 import dataManagementAPI from './dataManagement'
 import apiKeysAPI from './apiKeys'
 import scheduledTestsAPI from './scheduledTests'
+import tenantsAPI from './tenants'
+import auditLogsAPI from './auditLogs'
+import deadLettersAPI from './deadLetters'
+import contentLogsAPI from './contentLogs'
 
 /**
  * Unified admin API object for convenient access
@@ -47,7 +51,11 @@ export const adminAPI = {
   errorPassthrough: errorPassthroughAPI,
   dataManagement: dataManagementAPI,
   apiKeys: apiKeysAPI,
-  scheduledTests: scheduledTestsAPI
+  scheduledTests: scheduledTestsAPI,
+  tenants: tenantsAPI,
+  auditLogs: auditLogsAPI,
+  deadLetters: deadLettersAPI,
+  contentLogs: contentLogsAPI
 }
 
 export {
@@ -70,7 +78,11 @@ export {
   errorPassthroughAPI,
   dataManagementAPI,
   apiKeysAPI,
-  scheduledTestsAPI
+  scheduledTestsAPI,
+  tenantsAPI,
+  auditLogsAPI,
+  deadLettersAPI,
+  contentLogsAPI
 }
 
 export default adminAPI
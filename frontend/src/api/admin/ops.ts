@@ -1,6 +1,6 @@
 /**
  * Admin Ops API endpoints (vNext)
- * - Error logs list/detail + retry (client/upstream)
+ * - Error logs list/detail + retry (client/upstream) + debug replay
  * - Dashboard overview (raw path)
  */
 
@@ -81,6 +81,50 @@ export interface OpsRetryResult {
   duration_ms: number
 }
 
+export interface OpsUpstreamExchange {
+  started_at: string
+  method: string
+  url: string
+  request_headers?: Record<string, string>
+  request_body?: string
+  request_body_bytes: number
+  request_body_truncated?: boolean
+  status_code?: number
+  response_headers?: Record<string, string>
+  response_body?: string
+  response_truncated?: boolean
+  header_latency_ms: number
+  error?: string
+}
+
+export interface OpsReplayTrace {
+  request_path: string
+  request_type: string
+  inbound_model?: string
+  mapped_model?: string
+  stream: boolean
+  user_agent?: string
+  inbound_body: string
+  inbound_body_modified: boolean
+  upstream: OpsUpstreamExchange[]
+  upstream_errors?: OpsUpstreamErrorEvent[]
+}
+
+export interface OpsReplayResult {
+  error_id: number
+  account_id: number
+  account_name: string
+  platform: string
+  status: 'succeeded' | 'failed' | string
+  http_status_code: number
+  upstream_request_id?: string
+  response_preview: string
+  response_truncated: boolean
+  error_message?: string
+  duration_ms: number
+  trace: OpsReplayTrace
+}
+
 export interface OpsDashboardOverview {
   start_time: string
   end_time: string
@@ -468,6 +512,43 @@ export async function getAccountAvailabilityStats(platform?: string, groupId?: n
   return data
 }
 
+export interface AccountLatencyStats {
+  account_id: number
+  samples: number
+  p50_ms: number // Time to first token for streams, total duration otherwise
+  p95_ms: number
+}
+
+export interface OpsAccountLatencyResponse {
+  accounts: AccountLatencyStats[]
+  timestamp?: string
+}
+
+export async function getAccountLatencyStats(): Promise<OpsAccountLatencyResponse> {
+  const { data } = await apiClient.get<OpsAccountLatencyResponse>('/admin/ops/account-latency')
+  return data
+}
+
+export interface AccountCircuitState {
+  account_id: number
+  state: 'closed' | 'open' | 'half_open'
+  requests: number // Calls in the current window
+  failures: number
+  slow_calls: number
+  opened_at?: string
+  half_open_at?: string
+}
+
+export interface OpsAccountCircuitBreakersResponse {
+  accounts: AccountCircuitState[]
+  timestamp?: string
+}
+
+export async function getAccountCircuitBreakers(): Promise<OpsAccountCircuitBreakersResponse> {
+  const { data } = await apiClient.get<OpsAccountCircuitBreakersResponse>('/admin/ops/account-circuit-breakers')
+  return data
+}
+
 export interface OpsRateSummary {
   current: number
   peak: number
@@ -1196,6 +1277,17 @@ export async function retryUpstreamError(id: number): Promise<OpsRetryResult> {
   return data
 }
 
+/**
+ * Replay a stored failed request against a chosen account (defaults to the original account)
+ * and return verbose upstream traces. Does not record a retry attempt or resolve the error.
+ */
+export async function replayErrorRequest(id: number, accountId?: number): Promise<OpsReplayResult> {
+  const { data } = await apiClient.post<OpsReplayResult>(`/admin/ops/errors/${id}/replay`, {
+    account_id: accountId ?? 0
+  })
+  return data
+}
+
 export async function updateRequestErrorResolved(errorId: number, resolved: boolean): Promise<void> {
   await apiClient.put(`/admin/ops/request-errors/${errorId}/resolve`, { resolved })
 }
@@ -1364,6 +1456,8 @@ export const opsAPI = {
   getConcurrencyStats,
   getUserConcurrencyStats,
   getAccountAvailabilityStats,
+  getAccountLatencyStats,
+  getAccountCircuitBreakers,
   getRealtimeTrafficSummary,
   subscribeQPS,
 
@@ -1371,6 +1465,7 @@ export const opsAPI = {
   listErrorLogs,
   getErrorLogDetail,
   retryErrorRequest,
+  replayErrorRequest,
   listRetryAttempts,
   updateErrorResolved,
 
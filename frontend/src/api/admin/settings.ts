@@ -81,6 +81,9 @@ export interface SystemSettings {
   min_claude_code_version: string
   max_claude_code_version: string
 
+  // Codex CLI version check
+  min_codex_cli_version: string
+
   // 分组隔离
   allow_ungrouped_key_scheduling: boolean
 }
@@ -135,6 +138,7 @@ export interface UpdateSettingsRequest {
   ops_metrics_interval_seconds?: number
   min_claude_code_version?: string
   max_claude_code_version?: string
+  min_codex_cli_version?: string
   allow_ungrouped_key_scheduling?: boolean
 }
 
@@ -275,6 +279,91 @@ export async function updateStreamTimeoutSettings(
   return data
 }
 
+// ==================== Runtime Settings ====================
+
+/**
+ * Runtime settings interface (applied without restart)
+ */
+export interface RuntimeSettings {
+  default_ttfb_timeout_seconds: number
+  default_total_timeout_seconds: number
+  stream_keepalive_interval_seconds: number
+  upstream_retry: {
+    enabled: boolean
+    max_attempts: number
+    base_delay_ms: number
+    max_delay_ms: number
+  }
+  log_level: 'debug' | 'info' | 'warn' | 'error'
+  degraded_mode_enabled: boolean
+  source?: 'baseline' | 'runtime_setting'
+  updated_at?: string
+  updated_by_user_id?: number
+}
+
+/**
+ * Get runtime settings
+ * @returns Effective runtime settings
+ */
+export async function getRuntimeSettings(): Promise<RuntimeSettings> {
+  const { data } = await apiClient.get<RuntimeSettings>('/admin/settings/runtime')
+  return data
+}
+
+/**
+ * Update runtime settings
+ * @param settings - Runtime settings to apply
+ * @returns Applied settings
+ */
+export async function updateRuntimeSettings(settings: RuntimeSettings): Promise<RuntimeSettings> {
+  const { data } = await apiClient.put<RuntimeSettings>('/admin/settings/runtime', settings)
+  return data
+}
+
+/**
+ * Reset runtime settings to the config file baseline
+ * @returns Baseline settings
+ */
+export async function resetRuntimeSettings(): Promise<RuntimeSettings> {
+  const { data } = await apiClient.post<RuntimeSettings>('/admin/settings/runtime/reset')
+  return data
+}
+
+// ==================== Maintenance Mode ====================
+
+/**
+ * Maintenance mode state
+ */
+export interface MaintenanceState {
+  enabled: boolean
+  message: string
+  retry_after_seconds: number
+  started_at?: string
+  updated_at?: string
+  updated_by_user_id?: number
+}
+
+/**
+ * Get maintenance mode state
+ * @returns Current maintenance state
+ */
+export async function getMaintenanceState(): Promise<MaintenanceState> {
+  const { data } = await apiClient.get<MaintenanceState>('/admin/settings/maintenance')
+  return data
+}
+
+/**
+ * Enable or disable maintenance mode
+ * @param state - Maintenance state to apply
+ * @returns Applied state
+ */
+export async function updateMaintenanceState(
+  state: Pick<MaintenanceState, 'enabled' | 'message' | 'retry_after_seconds'>
+): Promise<MaintenanceState> {
+  const { data } = await apiClient.put<MaintenanceState>('/admin/settings/maintenance', state)
+  return data
+}
+
 // ==================== Rectifier Settings ====================
 
 /**
@@ -317,9 +406,11 @@ export async function updateRectifierSettings(
  */
 export interface BetaPolicyRule {
   beta_token: string
-  action: 'pass' | 'filter' | 'block'
+  action: 'pass' | 'filter' | 'block' | 'inject'
   scope: 'all' | 'oauth' | 'apikey'
   error_message?: string
+  models?: string[]
+  account_ids?: number[]
 }
 
 /**
@@ -353,6 +444,370 @@ export async function updateBetaPolicySettings(
   return data
 }
 
+// ==================== Client Routing Settings ====================
+
+/**
+ * Client routing rule interface
+ */
+export interface ClientRoutingRule {
+  client_types: string[]
+  account_types: Array<'oauth' | 'setup-token' | 'apikey' | 'upstream'>
+  strict?: boolean
+}
+
+/**
+ * Client routing settings interface
+ */
+export interface ClientRoutingSettings {
+  enabled: boolean
+  rules: ClientRoutingRule[]
+}
+
+/**
+ * Get client routing settings
+ * @returns Client routing settings
+ */
+export async function getClientRoutingSettings(): Promise<ClientRoutingSettings> {
+  const { data } = await apiClient.get<ClientRoutingSettings>('/admin/settings/client-routing')
+  return data
+}
+
+/**
+ * Update client routing settings
+ * @param settings - Client routing settings to update
+ * @returns Updated settings
+ */
+export async function updateClientRoutingSettings(
+  settings: ClientRoutingSettings
+): Promise<ClientRoutingSettings> {
+  const { data } = await apiClient.put<ClientRoutingSettings>(
+    '/admin/settings/client-routing',
+    settings
+  )
+  return data
+}
+
+// ==================== Model Alias Settings ====================
+
+/**
+ * Global model alias table: requested model (trailing * wildcard allowed) -> upstream model.
+ * Group-level model_aliases take precedence.
+ */
+export interface ModelAliasSettings {
+  aliases: Record<string, string>
+}
+
+/**
+ * Get global model alias settings
+ * @returns Model alias settings
+ */
+export async function getModelAliasSettings(): Promise<ModelAliasSettings> {
+  const { data } = await apiClient.get<ModelAliasSettings>('/admin/settings/model-aliases')
+  return data
+}
+
+/**
+ * Update global model alias settings
+ * @param settings - Model alias settings to update
+ * @returns Updated settings
+ */
+export async function updateModelAliasSettings(
+  settings: ModelAliasSettings
+): Promise<ModelAliasSettings> {
+  const { data } = await apiClient.put<ModelAliasSettings>('/admin/settings/model-aliases', settings)
+  return data
+}
+
+// ==================== Pricing Override Settings ====================
+
+/**
+ * Custom model price in USD per million tokens (trailing * wildcard allowed in model).
+ * Omitted fields keep the remote/built-in price.
+ */
+export interface ModelPriceOverride {
+  model: string
+  input_per_mtok?: number
+  output_per_mtok?: number
+  cache_creation_per_mtok?: number
+  cache_creation_1h_per_mtok?: number
+  cache_read_per_mtok?: number
+}
+
+/**
+ * Admin-managed price table. Takes precedence over pricing.overrides in config.
+ */
+export interface PricingOverrideSettings {
+  overrides: ModelPriceOverride[]
+}
+
+/**
+ * Get pricing override settings
+ * @returns Pricing override settings
+ */
+export async function getPricingOverrideSettings(): Promise<PricingOverrideSettings> {
+  const { data } = await apiClient.get<PricingOverrideSettings>('/admin/settings/pricing-overrides')
+  return data
+}
+
+/**
+ * Update pricing override settings
+ * @param settings - Pricing override settings to update
+ * @returns Updated settings
+ */
+export async function updatePricingOverrideSettings(
+  settings: PricingOverrideSettings
+): Promise<PricingOverrideSettings> {
+  const { data } = await apiClient.put<PricingOverrideSettings>('/admin/settings/pricing-overrides', settings)
+  return data
+}
+
+// ==================== Routing Rule Settings ====================
+
+export interface RoutingRuleHeaderMatch {
+  name: string
+  value?: string // Case-insensitive, trailing * wildcard (empty = header present)
+}
+
+/**
+ * Routing rule: all non-empty conditions must match; the first enabled rule
+ * (ascending priority) routes the request to target_group_id.
+ */
+export interface RoutingRule {
+  name: string
+  enabled: boolean
+  priority: number
+  models?: string[]
+  client_types?: string[]
+  headers?: RoutingRuleHeaderMatch[]
+  key_tags?: string[]
+  source_group_ids?: number[]
+  min_request_bytes?: number
+  max_request_bytes?: number
+  target_group_id: number
+}
+
+export interface RoutingRuleSettings {
+  enabled: boolean
+  rules: RoutingRule[]
+}
+
+/**
+ * Get routing rule settings
+ * @returns Routing rule settings
+ */
+export async function getRoutingRuleSettings(): Promise<RoutingRuleSettings> {
+  const { data } = await apiClient.get<RoutingRuleSettings>('/admin/settings/routing-rules')
+  return data
+}
+
+/**
+ * Update routing rule settings
+ * @param settings - Routing rule settings to update
+ * @returns Updated settings (rules sorted by priority)
+ */
+export async function updateRoutingRuleSettings(
+  settings: RoutingRuleSettings
+): Promise<RoutingRuleSettings> {
+  const { data } = await apiClient.put<RoutingRuleSettings>('/admin/settings/routing-rules', settings)
+  return data
+}
+
+// ==================== Canary Split Settings ====================
+
+/**
+ * Canary split: percent% of requests matching models (optionally limited to
+ * source_group_ids) go to target_group_id and/or target_model.
+ */
+export interface CanarySplit {
+  name: string
+  enabled: boolean
+  models: string[]
+  source_group_ids?: number[]
+  percent: number // 0-100
+  target_group_id?: number
+  target_model?: string
+}
+
+export interface CanarySettings {
+  enabled: boolean
+  splits: CanarySplit[]
+}
+
+export interface CanaryArmMetrics {
+  requests: number
+  errors: number // Responses with status >= 400
+  duration_total_ms: number
+  avg_duration_ms: number
+}
+
+/**
+ * Per-split metrics of this instance, accumulated since the last settings update
+ */
+export interface CanaryMetrics {
+  since: string
+  splits: Array<{ name: string; control: CanaryArmMetrics; canary: CanaryArmMetrics }>
+}
+
+/**
+ * Get canary split settings
+ * @returns Canary split settings
+ */
+export async function getCanarySettings(): Promise<CanarySettings> {
+  const { data } = await apiClient.get<CanarySettings>('/admin/settings/canary-splits')
+  return data
+}
+
+/**
+ * Update canary split settings (resets this instance's split metrics)
+ * @param settings - Canary split settings to update
+ * @returns Updated settings
+ */
+export async function updateCanarySettings(settings: CanarySettings): Promise<CanarySettings> {
+  const { data } = await apiClient.put<CanarySettings>('/admin/settings/canary-splits', settings)
+  return data
+}
+
+/**
+ * Get per-split control/canary metrics
+ * @returns Canary metrics snapshot
+ */
+export async function getCanaryMetrics(): Promise<CanaryMetrics> {
+  const { data } = await apiClient.get<CanaryMetrics>('/admin/settings/canary-splits/metrics')
+  return data
+}
+
+// ==================== Shadow Mirror Settings ====================
+
+/**
+ * Shadow mirror rule: after the primary request completes, percent% of requests
+ * matching models are replayed against target_group_id and/or target_model.
+ * Shadow requests are not billed and never affect the client response.
+ */
+export interface ShadowMirrorRule {
+  name: string
+  enabled: boolean
+  models: string[]
+  source_group_ids?: number[]
+  percent: number // 0-100
+  target_group_id?: number
+  target_model?: string
+  store_responses: boolean // Keep truncated primary/shadow bodies for diffing
+}
+
+export interface ShadowMirrorSettings {
+  enabled: boolean
+  rules: ShadowMirrorRule[]
+}
+
+export interface ShadowMirrorSample {
+  rule: string
+  at: string
+  model: string
+  shadow_model: string
+  source_group_id: number
+  target_group_id: number
+  primary_status: number
+  shadow_status: number
+  primary_duration_ms: number
+  shadow_duration_ms: number
+  shadow_error?: string
+  primary_body?: string
+  shadow_body?: string
+}
+
+/**
+ * Per-rule metrics and recent samples (newest first) of this instance,
+ * accumulated since the last settings update
+ */
+export interface ShadowMirrorSnapshot {
+  since: string
+  rules: Array<{
+    name: string
+    mirrored: number
+    skipped: number // Shadow concurrency was full
+    status_mismatches: number
+    shadow_errors: number
+    primary_avg_duration_ms: number
+    shadow_avg_duration_ms: number
+  }>
+  samples: ShadowMirrorSample[]
+}
+
+/**
+ * Get shadow mirror settings
+ * @returns Shadow mirror settings
+ */
+export async function getShadowMirrorSettings(): Promise<ShadowMirrorSettings> {
+  const { data } = await apiClient.get<ShadowMirrorSettings>('/admin/settings/shadow-mirror')
+  return data
+}
+
+/**
+ * Update shadow mirror settings (resets this instance's metrics and samples)
+ * @param settings - Shadow mirror settings to update
+ * @returns Updated settings
+ */
+export async function updateShadowMirrorSettings(settings: ShadowMirrorSettings): Promise<ShadowMirrorSettings> {
+  const { data } = await apiClient.put<ShadowMirrorSettings>('/admin/settings/shadow-mirror', settings)
+  return data
+}
+
+/**
+ * Get shadow mirror metrics and recent comparison samples
+ * @returns Shadow mirror snapshot
+ */
+export async function getShadowMirrorSamples(): Promise<ShadowMirrorSnapshot> {
+  const { data } = await apiClient.get<ShadowMirrorSnapshot>('/admin/settings/shadow-mirror/samples')
+  return data
+}
+
+// ==================== Header Policy Settings ====================
+
+/**
+ * Header policy rule: scoped by platform / account / group / inbound path prefix
+ * (empty scope = any). Credential and hop-by-hop headers are protected, and
+ * proxy / internal headers cannot be forwarded upstream.
+ */
+export interface HeaderPolicyRule {
+  name: string
+  enabled: boolean
+  platforms?: string[]
+  account_ids?: number[]
+  group_ids?: number[]
+  paths?: string[]
+  forward_headers?: string[] // Extra client headers forwarded beyond the built-in whitelist
+  strip_headers?: string[] // Upstream request headers removed (trailing * wildcard)
+  set_headers?: Record<string, string>
+  strip_response_headers?: string[] // Client response headers removed (trailing * wildcard)
+  set_response_headers?: Record<string, string>
+}
+
+export interface HeaderPolicySettings {
+  enabled: boolean
+  rules: HeaderPolicyRule[]
+}
+
+/**
+ * Get header policy settings
+ * @returns Header policy settings
+ */
+export async function getHeaderPolicySettings(): Promise<HeaderPolicySettings> {
+  const { data } = await apiClient.get<HeaderPolicySettings>('/admin/settings/header-policy')
+  return data
+}
+
+/**
+ * Update header policy settings
+ * @param settings - Header policy settings to update
+ * @returns Updated settings
+ */
+export async function updateHeaderPolicySettings(
+  settings: HeaderPolicySettings
+): Promise<HeaderPolicySettings> {
+  const { data } = await apiClient.put<HeaderPolicySettings>('/admin/settings/header-policy', settings)
+  return data
+}
+
 // ==================== Sora S3 Settings ====================
 
 export interface SoraS3Settings {
@@ -499,10 +954,31 @@ export const settingsAPI = {
   deleteAdminApiKey,
   getStreamTimeoutSettings,
   updateStreamTimeoutSettings,
+  getRuntimeSettings,
+  updateRuntimeSettings,
+  resetRuntimeSettings,
+  getMaintenanceState,
+  updateMaintenanceState,
   getRectifierSettings,
   updateRectifierSettings,
   getBetaPolicySettings,
   updateBetaPolicySettings,
+  getClientRoutingSettings,
+  updateClientRoutingSettings,
+  getModelAliasSettings,
+  updateModelAliasSettings,
+  getPricingOverrideSettings,
+  updatePricingOverrideSettings,
+  getRoutingRuleSettings,
+  updateRoutingRuleSettings,
+  getCanarySettings,
+  updateCanarySettings,
+  getCanaryMetrics,
+  getShadowMirrorSettings,
+  updateShadowMirrorSettings,
+  getShadowMirrorSamples,
+  getHeaderPolicySettings,
+  updateHeaderPolicySettings,
   getSoraS3Settings,
   updateSoraS3Settings,
   testSoraS3Connection,
@@ -0,0 +1,89 @@
+/**
+ * Admin Tenants API endpoints
+ */
+
+import { apiClient } from '../client'
+import type {
+  BasePaginationResponse,
+  CreateTenantRequest,
+  Tenant,
+  TenantStats,
+  UpdateTenantRequest
+} from '@/types'
+
+export async function list(
+  page: number = 1,
+  pageSize: number = 20,
+  filters?: {
+    status?: string
+    search?: string
+  }
+): Promise<BasePaginationResponse<Tenant>> {
+  const { data } = await apiClient.get<BasePaginationResponse<Tenant>>('/admin/tenants', {
+    params: { page, page_size: pageSize, ...filters }
+  })
+  return data
+}
+
+export async function getById(id: number): Promise<Tenant> {
+  const { data } = await apiClient.get<Tenant>(`/admin/tenants/${id}`)
+  return data
+}
+
+export async function create(request: CreateTenantRequest): Promise<Tenant> {
+  const { data } = await apiClient.post<Tenant>('/admin/tenants', request)
+  return data
+}
+
+export async function update(id: number, request: UpdateTenantRequest): Promise<Tenant> {
+  const { data } = await apiClient.put<Tenant>(`/admin/tenants/${id}`, request)
+  return data
+}
+
+export async function deleteTenant(id: number): Promise<{ message: string }> {
+  const { data } = await apiClient.delete<{ message: string }>(`/admin/tenants/${id}`)
+  return data
+}
+
+export async function getStats(
+  id: number,
+  params?: { start_date?: string; end_date?: string; timezone?: string }
+): Promise<TenantStats> {
+  const { data } = await apiClient.get<TenantStats>(`/admin/tenants/${id}/stats`, { params })
+  return data
+}
+
+export async function assignUsers(id: number, ids: number[]): Promise<{ affected: number }> {
+  const { data } = await apiClient.post<{ affected: number }>(`/admin/tenants/${id}/users`, { ids })
+  return data
+}
+
+export async function removeUsers(id: number, ids: number[]): Promise<{ affected: number }> {
+  const { data } = await apiClient.post<{ affected: number }>(`/admin/tenants/${id}/users/remove`, { ids })
+  return data
+}
+
+export async function assignGroups(id: number, ids: number[]): Promise<{ affected: number }> {
+  const { data } = await apiClient.post<{ affected: number }>(`/admin/tenants/${id}/groups`, { ids })
+  return data
+}
+
+export async function removeGroups(id: number, ids: number[]): Promise<{ affected: number }> {
+  const { data } = await apiClient.post<{ affected: number }>(`/admin/tenants/${id}/groups/remove`, { ids })
+  return data
+}
+
+const tenantsAPI = {
+  list,
+  getById,
+  create,
+  update,
+  delete: deleteTenant,
+  getStats,
+  assignUsers,
+  removeUsers,
+  assignGroups,
+  removeGroups
+}
+
+export default tenantsAPI
@@ -189,6 +189,32 @@ export async function cancelCleanupTask(taskId: number): Promise<{ id: number; s
   return data
 }
 
+/**
+ * Export usage report for chargeback (admin only)
+ * Empty group_by and granularity export one row per request
+ * @param params - Date range, grouping and filter parameters
+ * @returns CSV or JSON file blob
+ */
+export async function exportUsage(params: {
+  from: string
+  to?: string
+  timezone?: string
+  group_by?: string
+  granularity?: 'hour' | 'day' | 'week' | 'month'
+  user_id?: number
+  api_key_id?: number
+  account_id?: number
+  group_id?: number
+  model?: string
+  format?: 'csv' | 'json'
+}): Promise<Blob> {
+  const { data } = await apiClient.get<Blob>('/admin/usage/export', {
+    params,
+    responseType: 'blob'
+  })
+  return data
+}
+
 export const adminUsageAPI = {
   list,
   getStats,
@@ -196,7 +222,8 @@ export const adminUsageAPI = {
   searchApiKeys,
   listCleanupTasks,
   createCleanupTask,
-  cancelCleanupTask
+  cancelCleanupTask,
+  exportUsage
 }
 
 export default adminUsageAPI
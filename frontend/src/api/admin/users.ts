@@ -4,7 +4,7 @@
  */
 
 import { apiClient } from '../client'
-import type { AdminUser, UpdateUserRequest, PaginatedResponse, ApiKey } from '@/types'
+import type { AdminUser, UpdateUserRequest, PaginatedResponse, ApiKey, UserRole } from '@/types'
 
 /**
  * List all users with pagination
@@ -19,7 +19,7 @@ export async function list(
   pageSize: number = 20,
   filters?: {
     status?: 'active' | 'disabled'
-    role?: 'admin' | 'user'
+    role?: UserRole
     search?: string
     attributes?: Record<number, string>  // attributeId -> value
     include_subscriptions?: boolean
@@ -90,6 +90,17 @@ export async function update(id: number, updates: UpdateUserRequest): Promise<Ad
   return data
 }
 
+/**
+ * Change user role (admin only; the admin role itself cannot be granted or revoked)
+ * @param id - User ID
+ * @param role - New role
+ * @returns Updated user
+ */
+export async function updateRole(id: number, role: Exclude<UserRole, 'admin'>): Promise<AdminUser> {
+  const { data } = await apiClient.put<AdminUser>(`/admin/users/${id}/role`, { role })
+  return data
+}
+
 /**
  * Delete user
  * @param id - User ID
@@ -228,6 +239,7 @@ export const usersAPI = {
   getById,
   create,
   update,
+  updateRole,
   delete: deleteUser,
   updateBalance,
   updateConcurrency,
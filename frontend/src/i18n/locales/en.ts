@@ -436,6 +436,10 @@ export default {
       callbackMissingToken: 'Missing login token, please try again.',
       backToLogin: 'Back to Login'
     },
+    oidc: {
+      signIn: 'Sign in with {provider}',
+      orContinue: 'or continue with email'
+    },
     oauth: {
       code: 'Code',
       state: 'State',
@@ -1905,6 +1909,9 @@ export default {
         codexCLIOnly: 'Codex official clients only',
         codexCLIOnlyDesc:
           'Only applies to OpenAI OAuth. When enabled, only Codex official client families are allowed; when disabled, the gateway bypasses this restriction and keeps existing behavior.',
+        chatgptWebUpstream: 'ChatGPT web backend',
+        chatgptWebUpstreamDesc:
+          'Only applies to OpenAI OAuth. For accounts without Codex access: requests are sent to the ChatGPT web conversation endpoint (text only, no tool calling or WebSocket Mode).',
         modelRestrictionDisabledByPassthrough: 'Automatic passthrough is enabled: model whitelist/mapping will not take effect.',
         enableSora: 'Enable Sora simultaneously',
         enableSoraHint: 'Sora uses the same OpenAI account. Enable to create Sora account simultaneously.'
@@ -3771,6 +3778,14 @@ export default {
         maxVersionHint:
           'Reject Claude Code clients above this version (semver format). Leave empty to allow any version.'
       },
+      codexCli: {
+        title: 'Codex CLI Settings',
+        description: 'Control Codex CLI client access requirements',
+        minVersion: 'Minimum Version',
+        minVersionPlaceholder: 'e.g. 0.40.0',
+        minVersionHint:
+          'Reject Codex CLI clients below this version (semver format) with an upgrade hint. The VS Code extension is not affected. Leave empty to disable version check.'
+      },
       scheduling: {
         title: 'Gateway Scheduling Settings',
         description: 'Control API Key scheduling behavior',
@@ -4015,6 +4030,14 @@ export default {
         actionPass: 'Pass (transparent)',
         actionFilter: 'Filter (remove)',
         actionBlock: 'Block (reject)',
+        actionInject: 'Inject (add upstream)',
+        models: 'Models',
+        modelsPlaceholder: 'e.g. claude-sonnet-4*, leave empty for all',
+        accountIds: 'Account IDs',
+        accountIdsPlaceholder: 'e.g. 12, 15, leave empty for all',
+        targetHint: 'The rule only applies to matching models and accounts. Inject adds the beta to upstream requests when the client did not send it.',
+        newTokenPlaceholder: 'Beta token, e.g. prompt-caching-2024-07-31',
+        addRule: 'Add rule',
         scope: 'Scope',
         scopeAll: 'All accounts',
         scopeOAuth: 'OAuth only',
@@ -435,6 +435,10 @@ export default {
       callbackMissingToken: '登录信息缺失，请返回重试。',
       backToLogin: '返回登录'
     },
+    oidc: {
+      signIn: '使用 {provider} 登录',
+      orContinue: '或使用邮箱密码继续'
+    },
     oauth: {
       code: '授权码',
       state: '状态',
@@ -2052,6 +2056,8 @@ export default {
         responsesWebsocketsV2PassthroughHint: '当前已开启自动透传：仅影响 HTTP 透传链路，不影响 WS mode。',
         codexCLIOnly: '仅允许 Codex 官方客户端',
         codexCLIOnlyDesc: '仅对 OpenAI OAuth 生效。开启后仅允许 Codex 官方客户端家族访问；关闭后完全绕过并保持原逻辑。',
+        chatgptWebUpstream: 'ChatGPT 网页后端',
+        chatgptWebUpstreamDesc: '仅对 OpenAI OAuth 生效。用于无法使用 Codex 的账号：请求改走 ChatGPT 网页端会话接口（仅支持纯文本，不支持工具调用与 WebSocket Mode）。',
         modelRestrictionDisabledByPassthrough: '已开启自动透传：模型白名单/映射不会生效。',
         enableSora: '同时启用 Sora',
         enableSoraHint: 'Sora 使用相同的 OpenAI 账号，开启后将同时创建 Sora 平台账号'
@@ -3943,6 +3949,13 @@ export default {
         maxVersionPlaceholder: '例如 2.5.0',
         maxVersionHint: '拒绝高于此版本的 Claude Code 客户端请求（semver 格式）。留空则不限制最高版本。'
       },
+      codexCli: {
+        title: 'Codex CLI 设置',
+        description: '控制 Codex CLI 客户端访问要求',
+        minVersion: '最低版本号',
+        minVersionPlaceholder: '例如 0.40.0',
+        minVersionHint: '拒绝低于此版本的 Codex CLI 客户端请求（semver 格式）并返回升级提示，VS Code 扩展不受影响。留空则不检查版本。'
+      },
       scheduling: {
         title: '网关调度设置',
         description: '控制 API Key 的调度行为',
@@ -4188,6 +4201,14 @@ export default {
         actionPass: '透传（不处理）',
         actionFilter: '过滤（移除）',
         actionBlock: '拦截（拒绝请求）',
+        actionInject: '注入（补齐到上游）',
+        models: '模型',
+        modelsPlaceholder: '如 claude-sonnet-4*，留空匹配全部',
+        accountIds: '账号 ID',
+        accountIdsPlaceholder: '如 12, 15，留空匹配全部',
+        targetHint: '规则仅对匹配的模型与账号生效。注入：客户端未携带该 beta 时在上游请求中补齐。',
+        newTokenPlaceholder: 'Beta token，如 prompt-caching-2024-07-31',
+        addRule: '添加规则',
         scope: '生效范围',
         scopeAll: '全部账号',
         scopeOAuth: '仅 OAuth 账号',
@@ -239,6 +239,18 @@ describe('useAuthStore', () => {
       const store = useAuthStore()
       expect(store.isAdmin).toBe(false)
     })
+
+    it('只读角色可访问管理后台但不是完整管理员', async () => {
+      const viewerResponse = { ...fakeAuthResponse, user: { ...fakeAdminUser, role: 'viewer' as const } }
+      mockLogin.mockResolvedValue(viewerResponse)
+      const store = useAuthStore()
+
+      await store.login({ email: 'viewer@example.com', password: '123456' })
+
+      expect(store.isAdmin).toBe(true)
+      expect(store.isReadOnlyAdmin).toBe(true)
+      expect(store.isFullAdmin).toBe(false)
+    })
   })
 
   // --- refreshUser ---
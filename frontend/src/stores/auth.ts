@@ -32,10 +32,18 @@ export const useAuthStore = defineStore('auth', () => {
     return !!token.value && !!user.value
   })
 
+  // 可访问管理后台（viewer / operator / admin）
   const isAdmin = computed(() => {
-    return user.value?.role === 'admin'
+    const role = user.value?.role
+    return role === 'admin' || role === 'operator' || role === 'viewer'
   })
 
+  // 拥有全部管理权限（系统设置、角色授予等）
+  const isFullAdmin = computed(() => user.value?.role === 'admin')
+
+  // 只读角色：前端隐藏写操作入口，后端同样会拒绝
+  const isReadOnlyAdmin = computed(() => user.value?.role === 'viewer')
+
   const isSimpleMode = computed(() => runMode.value === 'simple')
 
   // ==================== Actions ====================
@@ -394,6 +402,8 @@ export const useAuthStore = defineStore('auth', () => {
     // Computed
     isAuthenticated,
     isAdmin,
+    isFullAdmin,
+    isReadOnlyAdmin,
     isSimpleMode,
 
     // Actions
@@ -24,11 +24,14 @@ export interface FetchOptions {
 
 // ==================== User & Auth Types ====================
 
+/** viewer / operator / admin 可访问管理后台：viewer 只读，operator 可执行日常运营操作，admin 拥有全部权限 */
+export type UserRole = 'admin' | 'operator' | 'viewer' | 'user'
+
 export interface User {
   id: number
   username: string
   email: string
-  role: 'admin' | 'user' // User role for authorization
+  role: UserRole // User role for authorization
   balance: number // User balance for API usage
   concurrency: number // Allowed concurrent requests
   status: 'active' | 'disabled' // Account status
@@ -48,6 +51,8 @@ export interface AdminUser extends User {
   // Sora 存储配额（字节）
   sora_storage_quota_bytes: number
   sora_storage_used_bytes: number
+  // 所属租户（null 表示未归属任何租户）
+  tenant_id?: number | null
 }
 
 export interface LoginRequest {
@@ -105,6 +110,8 @@ export interface PublicSettings {
   purchase_subscription_url: string
   custom_menu_items: CustomMenuItem[]
   linuxdo_oauth_enabled: boolean
+  oidc_enabled: boolean
+  oidc_provider_name?: string
   sora_client_enabled: boolean
   version: string
 }
@@ -412,22 +419,85 @@ export interface AdminGroup extends Group {
   // OpenAI Messages 调度配置（仅 openai 平台使用）
   default_mapped_model?: string
 
+  // 模型别名：请求模型（支持末尾 *）-> 上游模型，优先于全局别名表
+  model_aliases?: Record<string, string> | null
+
+  // 跨平台降级链：本分组限流或上游故障时依次尝试的分组 ID
+  fallback_chain?: number[] | null
+
+  // 账号调度策略（仅 openai 平台使用，空值为综合评分）
+  scheduling_strategy?: '' | 'round_robin' | 'weighted' | 'least_in_flight' | 'least_recent_error'
+
   // 分组排序
   sort_order: number
+
+  // 所属租户（null 表示未归属任何租户）
+  tenant_id?: number | null
+}
+
+// ==================== Tenant Types ====================
+
+export type TenantStatus = 'active' | 'disabled'
+
+export interface Tenant {
+  id: number
+  name: string
+  description: string
+  status: TenantStatus
+  user_count: number
+  group_count: number
+  created_at: string
+  updated_at: string
+}
+
+export interface CreateTenantRequest {
+  name: string
+  description?: string
+  status?: TenantStatus
+}
+
+export interface UpdateTenantRequest {
+  name?: string
+  description?: string
+  status?: TenantStatus
 }
 
+export interface TenantStats {
+  tenant_id: number
+  user_count: number
+  group_count: number
+  api_key_count: number
+  usage: UsageStatsResponse
+}
+
+// Client classes a key can be restricted to, based on User-Agent / originator detection
+export type ApiKeyClientRestriction = '' | 'codex' | 'claude_code' | 'coding_agent' | 'api'
+
+export type SystemPromptMode = 'prepend' | 'append' | 'replace'
+
+// Prompt moderation pre-filter for a key ('' = follow the gateway default)
+export type ApiKeyModerationMode = '' | 'off' | 'flag' | 'block'
+
 export interface ApiKey {
   id: number
   user_id: number
   key: string
   name: string
+  description: string
   group_id: number | null
   status: 'active' | 'inactive' | 'quota_exhausted' | 'expired'
   ip_whitelist: string[]
   ip_blacklist: string[]
+  scopes: string[] | null // Allowed endpoint scopes (empty = all endpoints)
+  allowed_models: string[] | null // Allowed model patterns, trailing * wildcard (empty = all models)
+  client_restriction: ApiKeyClientRestriction // Allowed client class ('' = any client)
+  tags: string[] | null // Admin-assigned routing tags matched by routing rules
   last_used_at: string | null
   quota: number // Quota limit in USD (0 = unlimited)
   quota_used: number // Used quota amount in USD
+  image_quota: number // Max generated images (0 = unlimited)
+  image_quota_used: number // Images generated so far
+  suppress_reasoning: boolean // Drop reasoning output for clients other than Codex / Claude Code
   expires_at: string | null // Expiration time (null = never expires)
   created_at: string
   updated_at: string
@@ -444,34 +514,90 @@ export interface ApiKey {
   reset_5h_at: string | null
   reset_1d_at: string | null
   reset_7d_at: string | null
+  rpm_limit: number
+  tpm_limit: number
+  daily_request_limit: number
+  daily_token_limit: number
+  monthly_request_limit: number
+  monthly_token_limit: number
+  budget_amount: number // Spend budget in USD per period (0 = no budget)
+  budget_period: 'day' | 'week' | 'month'
+  budget_action: 'disable' | 'downgrade' // What happens once the budget is exceeded
+  budget_fallback_model: string // Model used when budget_action is downgrade
+  budget_used: number // Spend in the current budget period
+  budget_exceeded: boolean
+  budget_reset_at?: string // Start of the next budget period
+  degraded_fallback_model: string // Model served when every account for the requested model is unavailable ('' = return 503)
+  system_prompt: string // Admin-set system prompt injected into every request ('' = none)
+  system_prompt_mode: SystemPromptMode | '' // How system_prompt is applied
+  moderation_mode: ApiKeyModerationMode // Prompt moderation pre-filter (off / flag / block)
+  log_content: boolean // Full prompts and responses are stored for debugging (see admin content logs)
+  simulate: boolean // Dry-run: requests are answered by the built-in mock upstream, never real accounts
+  previous_key_expires_at?: string // Grace window end for the secret replaced by the last rotation
 }
 
 export interface CreateApiKeyRequest {
   name: string
+  description?: string
   group_id?: number | null
   custom_key?: string // Optional custom API Key
   ip_whitelist?: string[]
   ip_blacklist?: string[]
+  scopes?: string[] // Allowed endpoint scopes (empty = all endpoints)
+  allowed_models?: string[] // Allowed model patterns, trailing * wildcard (empty = all models)
+  client_restriction?: ApiKeyClientRestriction // Allowed client class ('' = any client)
   quota?: number // Quota limit in USD (0 = unlimited)
+  image_quota?: number // Max generated images (0 = unlimited)
+  suppress_reasoning?: boolean // Drop reasoning output for clients other than Codex / Claude Code
   expires_in_days?: number // Days until expiry (null = never expires)
   rate_limit_5h?: number
   rate_limit_1d?: number
   rate_limit_7d?: number
+  rpm_limit?: number // Requests per minute (0 = unlimited)
+  tpm_limit?: number // Tokens per minute (0 = unlimited)
+  daily_request_limit?: number
+  daily_token_limit?: number
+  monthly_request_limit?: number
+  monthly_token_limit?: number
+  budget_amount?: number // Spend budget in USD per period (0 = no budget)
+  budget_period?: 'day' | 'week' | 'month'
+  budget_action?: 'disable' | 'downgrade'
+  budget_fallback_model?: string
+  degraded_fallback_model?: string // Model served when every account for the requested model is unavailable
 }
 
 export interface UpdateApiKeyRequest {
   name?: string
+  description?: string
   group_id?: number | null
   status?: 'active' | 'inactive'
   ip_whitelist?: string[]
   ip_blacklist?: string[]
+  scopes?: string[] // Allowed endpoint scopes (omit = no change, [] = clear)
+  allowed_models?: string[] // Allowed model patterns (omit = no change, [] = clear)
+  client_restriction?: ApiKeyClientRestriction // Allowed client class (omit = no change, '' = clear)
   quota?: number // Quota limit in USD (null = no change, 0 = unlimited)
   expires_at?: string | null // Expiration time (null = no change)
   reset_quota?: boolean // Reset quota_used to 0
+  image_quota?: number // Max generated images (null = no change, 0 = unlimited)
+  reset_image_quota?: boolean // Reset image_quota_used to 0
+  suppress_reasoning?: boolean // Drop reasoning output for clients other than Codex / Claude Code
   rate_limit_5h?: number
   rate_limit_1d?: number
   rate_limit_7d?: number
   reset_rate_limit_usage?: boolean
+  rpm_limit?: number // Requests per minute (0 = unlimited)
+  tpm_limit?: number // Tokens per minute (0 = unlimited)
+  daily_request_limit?: number
+  daily_token_limit?: number
+  monthly_request_limit?: number
+  monthly_token_limit?: number
+  budget_amount?: number // Spend budget in USD per period (null = no change, 0 = no budget)
+  budget_period?: 'day' | 'week' | 'month'
+  budget_action?: 'disable' | 'downgrade'
+  budget_fallback_model?: string
+  reset_budget_usage?: boolean // Reset budget_used for the current period
+  degraded_fallback_model?: string // Degraded mode fallback model ('' = clear)
 }
 
 export interface CreateGroupRequest {
@@ -497,6 +623,8 @@ export interface CreateGroupRequest {
   fallback_group_id_on_invalid_request?: number | null
   mcp_xml_inject?: boolean
   supported_model_scopes?: string[]
+  model_aliases?: Record<string, string>
+  fallback_chain?: number[]
   // 从指定分组复制账号
   copy_accounts_from_group_ids?: number[]
 }
@@ -525,6 +653,10 @@ export interface UpdateGroupRequest {
   fallback_group_id_on_invalid_request?: number | null
   mcp_xml_inject?: boolean
   supported_model_scopes?: string[]
+  // 传 {} 清除分组别名
+  model_aliases?: Record<string, string>
+  // 传 [] 清除降级链
+  fallback_chain?: number[]
   copy_accounts_from_group_ids?: number[]
 }
 
@@ -934,6 +1066,7 @@ export interface UsageLog {
   api_key_id: number
   account_id: number | null
   request_id: string
+  gateway_request_id?: string
   model: string
   reasoning_effort?: string | null
 
@@ -968,6 +1101,7 @@ export interface UsageLog {
 
   // User-Agent
   user_agent: string | null
+  client_type?: string
 
   // Cache TTL Override
   cache_ttl_overridden: boolean
@@ -1176,7 +1310,7 @@ export interface UpdateUserRequest {
   password?: string
   username?: string
   notes?: string
-  role?: 'admin' | 'user'
+  role?: UserRole
   balance?: number
   concurrency?: number
   status?: 'active' | 'disabled'